@@ -59,8 +59,19 @@ const (
 	StorageAddr            = "STORAGE_ADDR"
 	AgentServiceName       = "AGENT_SERVICE_NAME"
 	MongoOplogSize         = "MONGO_OPLOG_SIZE"
+	MongoStorageEngine     = "MONGO_STORAGE_ENGINE"
+	MongoCacheSize         = "MONGO_CACHE_SIZE"
 	NumaCtlPreference      = "NUMA_CTL_PREFERENCE"
 	AllowsSecureConnection = "SECURE_STATESERVER_CONNECTION"
+	LoggingConfig          = "LOGGING_CONFIG"
+	HTTPProxy              = "HTTP_PROXY"
+	HTTPSProxy             = "HTTPS_PROXY"
+	FTPProxy               = "FTP_PROXY"
+	NoProxy                = "NO_PROXY"
+	WorkloadContainer      = "WORKLOAD_CONTAINER"
+	MaxCharmUploadSize     = "MAX_CHARM_UPLOAD_SIZE"
+	MaxToolsUploadSize     = "MAX_TOOLS_UPLOAD_SIZE"
+	MaxBackupUploadSize    = "MAX_BACKUP_UPLOAD_SIZE"
 )
 
 // The Config interface is the sole way that the agent gets access to the
@@ -170,6 +181,10 @@ type ConfigSetterOnly interface {
 	// SetAPIHostPorts sets the API host/port addresses to connect to.
 	SetAPIHostPorts(servers [][]network.HostPort)
 
+	// SetCACert sets the CA certificate used to validate
+	// the state and API servers.
+	SetCACert(caCert string)
+
 	// Migrate takes an existing agent config and applies the given
 	// parameters to change it.
 	//
@@ -494,6 +509,10 @@ func (c *configInternal) SetAPIHostPorts(servers [][]network.HostPort) {
 	c.apiDetails.addresses = addrs
 }
 
+func (c *configInternal) SetCACert(caCert string) {
+	c.caCert = caCert
+}
+
 func (c *configInternal) SetValue(key, value string) {
 	if value == "" {
 		delete(c.values, key)
@@ -17,6 +17,7 @@ import (
 	agenttools "github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/testing"
 	coretest "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/version"
 )
 
@@ -39,7 +40,7 @@ func (t *ToolsSuite) TestPackageDependencies(c *gc.C) {
 	// resulting slice has that prefix removed to keep the output short.
 	c.Assert(testing.FindJujuCoreImports(c, "github.com/juju/juju/agent/tools"),
 		gc.DeepEquals,
-		[]string{"juju/arch", "tools", "version"})
+		[]string{"juju/arch", "tools", "tools/delta", "version"})
 }
 
 const toolsFile = "downloaded-tools.txt"
@@ -149,6 +150,56 @@ func (t *ToolsSuite) TestUnpackToolsContents(c *gc.C) {
 	t.assertToolsContents(c, testTools, files)
 }
 
+func (t *ToolsSuite) TestUnpackToolsDelta(c *gc.C) {
+	oldFiles := []*testing.TarFile{
+		testing.NewTarFile("bar", agenttools.DirPerm, "bar contents"),
+		testing.NewTarFile("foo", agenttools.DirPerm, "foo contents"),
+	}
+	oldData, oldChecksum := testing.TarGz(oldFiles...)
+	oldTools := &coretest.Tools{
+		URL:     "http://foo/bar",
+		Version: version.MustParseBinary("1.2.3-quantal-amd64"),
+		Size:    int64(len(oldData)),
+		SHA256:  oldChecksum,
+	}
+	err := agenttools.UnpackTools(t.dataDir, oldTools, bytes.NewReader(oldData))
+	c.Assert(err, jc.ErrorIsNil)
+
+	newFiles := []*testing.TarFile{
+		testing.NewTarFile("bar", agenttools.DirPerm, "bar2 contents"),
+		testing.NewTarFile("foo", agenttools.DirPerm, "foo contents"),
+	}
+	newData, newChecksum := testing.TarGz(newFiles...)
+	newTools := &coretest.Tools{
+		URL:     "http://foo/baz",
+		Version: version.MustParseBinary("1.2.4-quantal-amd64"),
+		Size:    int64(len(newData)),
+		SHA256:  newChecksum,
+	}
+	d, err := delta.Compute(bytes.NewReader(oldData), bytes.NewReader(newData))
+	c.Assert(err, jc.ErrorIsNil)
+	var buf bytes.Buffer
+	err = d.Write(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = agenttools.UnpackToolsDelta(t.dataDir, oldTools.Version, newTools, &buf)
+	c.Assert(err, jc.ErrorIsNil)
+	assertDirNames(c, t.toolsDir(), []string{"1.2.3-quantal-amd64", "1.2.4-quantal-amd64"})
+	t.assertToolsContents(c, newTools, newFiles)
+}
+
+func (t *ToolsSuite) TestUnpackToolsDeltaBadData(c *gc.C) {
+	newTools := &coretest.Tools{
+		URL:     "http://foo/baz",
+		Version: version.MustParseBinary("1.2.4-quantal-amd64"),
+	}
+	from := version.MustParseBinary("1.2.3-quantal-amd64")
+	err := agenttools.UnpackToolsDelta(t.dataDir, from, newTools, bytes.NewReader([]byte("x")))
+	c.Assert(err, gc.ErrorMatches, "cannot read tools delta: .*")
+	_, err = os.Stat(t.toolsDir())
+	c.Assert(err, gc.FitsTypeOf, &os.PathError{})
+}
+
 func (t *ToolsSuite) TestReadToolsErrors(c *gc.C) {
 	vers := version.MustParseBinary("1.2.3-precise-amd64")
 	testTools, err := agenttools.ReadTools(t.dataDir, vers)
@@ -212,6 +263,35 @@ func (t *ToolsSuite) TestChangeAgentTools(c *gc.C) {
 	assertDirNames(c, agenttools.ToolsDir(t.dataDir, "testagent"), []string{"quantal", "amd64", toolsFile})
 }
 
+func (t *ToolsSuite) TestCopyTools(c *gc.C) {
+	files := []*testing.TarFile{
+		testing.NewTarFile("jujuc", agenttools.DirPerm, "juju executable"),
+		testing.NewTarFile("jujud", agenttools.DirPerm, "jujuc executable"),
+	}
+	data, checksum := testing.TarGz(files...)
+	testTools := &coretest.Tools{
+		URL:     "http://foo/bar1",
+		Version: version.MustParseBinary("1.2.3-quantal-amd64"),
+		Size:    int64(len(data)),
+		SHA256:  checksum,
+	}
+	err := agenttools.UnpackTools(t.dataDir, testTools, bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+
+	to := version.MustParseBinary("1.2.3-trusty-amd64")
+	err = agenttools.CopyTools(t.dataDir, testTools.Version, to)
+	c.Assert(err, jc.ErrorIsNil)
+	assertDirNames(c, t.toolsDir(), []string{"1.2.3-quantal-amd64", "1.2.3-trusty-amd64"})
+	copied := *testTools
+	copied.Version = to
+	t.assertToolsContents(c, &copied, files)
+
+	// Copying again leaves the tools in place.
+	err = agenttools.CopyTools(t.dataDir, testTools.Version, to)
+	c.Assert(err, jc.ErrorIsNil)
+	t.assertToolsContents(c, &copied, files)
+}
+
 func (t *ToolsSuite) TestSharedToolsDir(c *gc.C) {
 	dir := agenttools.SharedToolsDir("/var/lib/juju", version.MustParseBinary("1.2.3-precise-amd64"))
 	c.Assert(dir, gc.Equals, "/var/lib/juju/tools/1.2.3-precise-amd64")
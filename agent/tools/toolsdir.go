@@ -19,6 +19,7 @@ import (
 	"github.com/juju/utils/symlink"
 
 	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/version"
 )
 
@@ -72,14 +73,7 @@ func UnpackTools(dataDir string, tools *coretools.Tools, r io.Reader) (err error
 		return fmt.Errorf("tarball sha256 mismatch, expected %s, got %s", tools.SHA256, gzipSHA256)
 	}
 
-	// Make a temporary directory in the tools directory,
-	// first ensuring that the tools directory exists.
-	toolsDir := path.Join(dataDir, "tools")
-	err = os.MkdirAll(toolsDir, dirPerm)
-	if err != nil {
-		return err
-	}
-	dir, err := ioutil.TempDir(toolsDir, "unpacking-")
+	dir, err := unpackingDir(dataDir)
 	if err != nil {
 		return err
 	}
@@ -110,6 +104,76 @@ func UnpackTools(dataDir string, tools *coretools.Tools, r io.Reader) (err error
 			return errors.Annotatef(err, "tar extract %q failed", name)
 		}
 	}
+	return installTools(dataDir, dir, tools)
+}
+
+// UnpackToolsDelta reads a delta between the unpacked tools with
+// version from and the given tools, as served by the API server, and
+// uses it to create the given tools in the appropriate tools directory
+// within dataDir.
+func UnpackToolsDelta(dataDir string, from version.Binary, tools *coretools.Tools, r io.Reader) error {
+	d, err := delta.Read(r)
+	if err != nil {
+		return errors.Annotate(err, "cannot read tools delta")
+	}
+	dir, err := unpackingDir(dataDir)
+	if err != nil {
+		return err
+	}
+	defer removeAll(dir)
+	if err := d.Apply(SharedToolsDir(dataDir, from), dir); err != nil {
+		return errors.Annotate(err, "cannot apply tools delta")
+	}
+	return installTools(dataDir, dir, tools)
+}
+
+// CopyTools makes the unpacked tools with version from available as
+// the tools with version to, as when the series of the machine they
+// run on is upgraded. If tools with version to are already unpacked,
+// CopyTools returns without error.
+func CopyTools(dataDir string, from, to version.Binary) error {
+	if _, err := ReadTools(dataDir, to); err == nil {
+		return nil
+	}
+	tools, err := ReadTools(dataDir, from)
+	if err != nil {
+		return err
+	}
+	dir, err := unpackingDir(dataDir)
+	if err != nil {
+		return err
+	}
+	defer removeAll(dir)
+	fromDir := SharedToolsDir(dataDir, from)
+	infos, err := ioutil.ReadDir(fromDir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.Name() == toolsFile || !info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(path.Join(dir, info.Name()), path.Join(fromDir, info.Name()), info.Mode().Perm()); err != nil {
+			return errors.Annotatef(err, "cannot copy %q", info.Name())
+		}
+	}
+	tools.Version = to
+	return installTools(dataDir, dir, tools)
+}
+
+// unpackingDir makes a temporary directory in the tools directory,
+// first ensuring that the tools directory exists.
+func unpackingDir(dataDir string) (string, error) {
+	toolsDir := path.Join(dataDir, "tools")
+	if err := os.MkdirAll(toolsDir, dirPerm); err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(toolsDir, "unpacking-")
+}
+
+// installTools records the metadata of the given tools in the unpacked
+// tools in dir, and moves them into place within dataDir.
+func installTools(dataDir, dir string, tools *coretools.Tools) error {
 	toolsMetadataData, err := json.Marshal(tools)
 	if err != nil {
 		return err
@@ -156,6 +220,15 @@ func writeFile(name string, mode os.FileMode, r io.Reader) error {
 	return err
 }
 
+func copyFile(dest, source string, mode os.FileMode) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFile(dest, mode, f)
+}
+
 // ReadTools checks that the tools information for the given version exists
 // in the dataDir directory, and returns a Tools instance.
 // The tools information is json encoded in a text file, "downloaded-tools.txt".
@@ -5,9 +5,11 @@ package action
 
 import (
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
 
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 )
 
@@ -76,6 +78,17 @@ func (c *Client) ListCompleted(arg params.Entities) (params.ActionsByReceivers,
 	return results, err
 }
 
+// List returns a page of the actions queued for units in the
+// environment that match the filters in arg.
+func (c *Client) List(arg params.ListActionsArgs) (params.ListActionsResult, error) {
+	results := params.ListActionsResult{}
+	if c.facade.BestAPIVersion() < 1 {
+		return results, errors.NotImplementedf("List() (need V1+)")
+	}
+	err := c.facade.FacadeCall("List", arg, &results)
+	return results, err
+}
+
 // Cancel attempts to cancel a queued up Action from running.
 func (c *Client) Cancel(arg params.Actions) (params.ActionResults, error) {
 	results := params.ActionResults{}
@@ -83,6 +96,70 @@ func (c *Client) Cancel(arg params.Actions) (params.ActionResults, error) {
 	return results, err
 }
 
+// Schedule takes a list of actions and schedules each to be queued
+// for the designated ActionReceiver once at a future time, or
+// repeatedly according to a cron expression.
+func (c *Client) Schedule(arg params.ActionScheduleArgs) (params.ActionScheduleResults, error) {
+	results := params.ActionScheduleResults{}
+	err := c.facade.FacadeCall("Schedule", arg, &results)
+	return results, err
+}
+
+// ListSchedules returns all the action schedules in the environment.
+func (c *Client) ListSchedules() (params.ActionScheduleResults, error) {
+	results := params.ActionScheduleResults{}
+	err := c.facade.FacadeCall("ListSchedules", nil, &results)
+	return results, err
+}
+
+// CancelSchedules cancels the action schedules with the given ids.
+func (c *Client) CancelSchedules(arg params.ActionScheduleIds) (params.ErrorResults, error) {
+	results := params.ErrorResults{}
+	err := c.facade.FacadeCall("CancelSchedules", arg, &results)
+	return results, err
+}
+
+// WatchOutput returns a NotifyWatcher which notifies when the given
+// action records output or changes status.
+func (c *Client) WatchOutput(tag names.ActionTag) (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{Entities: []params.Entity{{Tag: tag.String()}}}
+	err := c.facade.FacadeCall("WatchOutput", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("%d results, expected 1", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(c.facade.RawAPICaller(), result), nil
+}
+
+// Output returns the output recorded by the given action while
+// running, starting at the chunk with sequence number from, together
+// with the action's status.
+func (c *Client) Output(tag names.ActionTag, from int) (params.ActionOutputResult, error) {
+	var results params.ActionOutputResults
+	args := params.ActionOutputQueries{
+		Queries: []params.ActionOutputQuery{{ActionTag: tag.String(), From: from}},
+	}
+	err := c.facade.FacadeCall("Output", args, &results)
+	if err != nil {
+		return params.ActionOutputResult{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.ActionOutputResult{}, errors.Errorf("%d results, expected 1", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.ActionOutputResult{}, result.Error
+	}
+	return result, nil
+}
+
 // servicesCharmActions is a batched query for the charm.Actions for a slice
 // of services by Entity.
 func (c *Client) servicesCharmActions(arg params.Entities) (params.ServicesCharmActionsResults, error) {
@@ -132,3 +132,63 @@ func patchServiceCharmActions(c *gc.C, apiCli *action.Client, patchResults []par
 		},
 	)
 }
+
+func (s *actionSuite) TestOutput(c *gc.C) {
+	tag := names.NewActionTag("feedface-0123-4567-8901-2345deadbeef")
+	expect := params.ActionOutputResult{
+		Status: params.ActionRunning,
+		Chunks: []params.ActionOutputChunk{{Seq: 3, Stream: params.ActionStdout, Data: "hello\n"}},
+	}
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(req, gc.Equals, "Output")
+			c.Assert(paramsIn, jc.DeepEquals, params.ActionOutputQueries{
+				Queries: []params.ActionOutputQuery{{ActionTag: tag.String(), From: 3}},
+			})
+			result := resp.(*params.ActionOutputResults)
+			result.Results = []params.ActionOutputResult{expect}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.Output(tag, 3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expect)
+}
+
+func (s *actionSuite) TestOutputError(c *gc.C) {
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			result := resp.(*params.ActionOutputResults)
+			result.Results = []params.ActionOutputResult{{Error: &params.Error{Message: "bad"}}}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.Output(names.NewActionTag("feedface-0123-4567-8901-2345deadbeef"), 0)
+	c.Assert(err, gc.ErrorMatches, "bad")
+}
+
+func (s *actionSuite) TestList(c *gc.C) {
+	result, err := s.client.List(params.ListActionsArgs{
+		Statuses: []string{params.ActionPending},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Actions, gc.HasLen, 0)
+	c.Assert(result.Next, gc.Equals, "")
+}
+
+func (s *actionSuite) TestListNotImplemented(c *gc.C) {
+	cleanup := action.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Fatalf("unexpected call to %s", req)
+			return nil
+		},
+	)
+	defer cleanup()
+
+	_, err := s.client.List(params.ListActionsArgs{})
+	c.Assert(err, gc.ErrorMatches, `List\(\) \(need V1\+\) not implemented`)
+}
@@ -0,0 +1,49 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the ActionScheduler API facade, used to
+// queue scheduled actions when they fall due.
+type State struct {
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	return &State{
+		facade: base.NewFacadeCaller(caller, "ActionScheduler"),
+	}
+}
+
+// WatchActionSchedules returns a NotifyWatcher which notifies when
+// action schedules are added, dispatched or cancelled.
+func (st *State) WatchActionSchedules() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	if err := st.facade.FacadeCall("WatchActionSchedules", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// DispatchDue queues the actions of all the schedules which are due.
+// The result holds the tags of the actions queued, and the time at
+// which the next schedule falls due.
+func (st *State) DispatchDue() (params.ActionScheduleDispatchResult, error) {
+	var result params.ActionScheduleDispatchResult
+	if err := st.facade.FacadeCall("DispatchDue", nil, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/actionscheduler"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type actionSchedulerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&actionSchedulerSuite{})
+
+func (s *actionSchedulerSuite) TestDispatchDue(c *gc.C) {
+	expected := params.ActionScheduleDispatchResult{
+		Actions: []params.Entity{{Tag: "action-some-uuid"}},
+		NextRun: time.Date(2015, 7, 15, 10, 0, 0, 0, time.UTC),
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ActionScheduler")
+			c.Check(request, gc.Equals, "DispatchDue")
+			c.Check(a, gc.IsNil)
+			*(response.(*params.ActionScheduleDispatchResult)) = expected
+			return nil
+		})
+	result, err := actionscheduler.NewState(apiCaller).DispatchDue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expected)
+}
+
+func (s *actionSchedulerSuite) TestWatchActionSchedulesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ActionScheduler")
+			c.Check(request, gc.Equals, "WatchActionSchedules")
+			result := response.(*params.NotifyWatchResult)
+			result.Error = &params.Error{Message: "permission denied", Code: params.CodeUnauthorized}
+			return nil
+		})
+	_, err := actionscheduler.NewState(apiCaller).WatchActionSchedules()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
+	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -64,6 +65,37 @@ func (s *servingInfoSuite) TestStateServingInfoPermission(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "permission denied")
 }
 
+func (s *servingInfoSuite) TestAgentConfigSettings(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	settings, err := st.Agent().AgentConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings.LoggingConfig, gc.Equals, "<root>=DEBUG")
+}
+
+func (s *servingInfoSuite) TestWatchAgentConfigSettings(c *gc.C) {
+	st, _ := s.OpenAPIAsNewMachine(c)
+
+	w, err := st.Agent().WatchAgentConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
 func (s *servingInfoSuite) TestIsMaster(c *gc.C) {
 	calledIsMaster := false
 	var fakeMongoIsMaster = func(session *mgo.Session, m mongo.WithAddresses) (bool, error) {
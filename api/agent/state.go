@@ -9,6 +9,7 @@ import (
 	"github.com/juju/names"
 
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state/multiwatcher"
@@ -62,6 +63,28 @@ func (st *State) IsMaster() (bool, error) {
 	return results.Master, err
 }
 
+// WatchAgentConfigSettings returns a watcher that notifies when the
+// settings returned by AgentConfigSettings may have changed.
+func (st *State) WatchAgentConfigSettings() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := st.facade.FacadeCall("WatchAgentConfigSettings", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// AgentConfigSettings returns the environment settings that the agent
+// keeps in its configuration file.
+func (st *State) AgentConfigSettings() (params.AgentConfigSettings, error) {
+	var result params.AgentConfigSettings
+	err := st.facade.FacadeCall("AgentConfigSettings", nil, &result)
+	return result, err
+}
+
 type Entity struct {
 	st  *State
 	tag names.Tag
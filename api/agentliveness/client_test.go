@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agentliveness_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/agentliveness"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type agentLivenessSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&agentLivenessSuite{})
+
+func (s *agentLivenessSuite) TestAgentLiveness(c *gc.C) {
+	lastSeen := time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+	expected := []params.AgentLivenessResult{
+		{Alive: true, LastSeen: &lastSeen},
+		{Error: &params.Error{Message: "unit not found", Code: params.CodeNotFound}},
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "AgentLiveness")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "AgentLiveness")
+			c.Check(a, jc.DeepEquals, params.Entities{Entities: []params.Entity{
+				{Tag: "machine-0"},
+				{Tag: "unit-mysql-0"},
+			}})
+			result := response.(*params.AgentLivenessResults)
+			result.Results = expected
+			return nil
+		})
+	client := agentliveness.NewClient(apiCaller)
+	results, err := client.AgentLiveness(names.NewMachineTag("0"), names.NewUnitTag("mysql/0"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, expected)
+}
+
+func (s *agentLivenessSuite) TestAgentLivenessWrongResultCount(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return nil
+		})
+	client := agentliveness.NewClient(apiCaller)
+	_, err := client.AgentLiveness(names.NewMachineTag("0"))
+	c.Assert(err, gc.ErrorMatches, "expected 1 results, got 0")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 )
 
@@ -31,14 +32,42 @@ func (c *Client) Get(tags []string) ([]params.AnnotationsGetResult, error) {
 	return annotations.Results, nil
 }
 
-// Set sets entity annotation pairs.
+// Set sets entity annotation pairs. Only the entities for which
+// setting annotations failed are reported.
 func (c *Client) Set(annotations map[string]map[string]string) ([]params.ErrorResult, error) {
 	args := params.AnnotationsSet{entitiesAnnotations(annotations)}
 	results := new(params.ErrorResults)
 	if err := c.facade.FacadeCall("Set", args, results); err != nil {
 		return nil, errors.Trace(err)
 	}
-	return results.Results, nil
+	// Version 2 of the facade returns a result for every entity.
+	failed := []params.ErrorResult{}
+	for _, result := range results.Results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed, nil
+}
+
+// Watch returns a NotifyWatcher which notifies when the annotations
+// of the given entity change.
+func (c *Client) Watch(tag string) (watcher.NotifyWatcher, error) {
+	if c.BestAPIVersion() < 2 {
+		return nil, errors.NotImplementedf("Watch() (need V2+)")
+	}
+	var results params.NotifyWatchResults
+	if err := c.facade.FacadeCall("WatchAnnotations", entitiesFromTags([]string{tag}), &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(c.facade.RawAPICaller(), result), nil
 }
 
 func entitiesFromTags(tags []string) params.Entities {
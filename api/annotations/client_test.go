@@ -4,6 +4,7 @@
 package annotations_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -91,3 +92,43 @@ func (s *annotationsMockSuite) TestGetEntitiesAnnotations(c *gc.C) {
 	c.Assert(called, jc.IsTrue)
 	c.Assert(found, gc.HasLen, 1)
 }
+
+func (s *annotationsMockSuite) TestSetReportsOnlyFailures(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(request, gc.Equals, "Set")
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{
+				{},
+				{Error: &params.Error{Message: "boom"}},
+			}
+			return nil
+		})
+	annotationsClient := annotations.NewClient(apiCaller)
+	callErrs, err := annotationsClient.Set(map[string]map[string]string{
+		"charmA":   {"annotation1": "test"},
+		"serviceB": {"annotation2": "test"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(callErrs, gc.HasLen, 1)
+	c.Assert(callErrs[0].Error, gc.ErrorMatches, "boom")
+}
+
+func (s *annotationsMockSuite) TestWatchNotImplemented(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Fatalf("unexpected API call %q", request)
+			return nil
+		})
+	annotationsClient := annotations.NewClient(apiCaller)
+	_, err := annotationsClient.Watch("machine-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
@@ -63,9 +63,10 @@ type State struct {
 	// closed is a channel that gets closed when State.Close is called.
 	closed chan struct{}
 
-	// tag and password hold the cached login credentials.
+	// tag, password and nonce hold the cached login credentials.
 	tag      string
 	password string
+	nonce    string
 
 	// serverRoot holds the cached API server address and port we used
 	// to login, with a https:// prefix.
@@ -134,12 +135,12 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 	if len(info.Addrs) == 0 {
 		return nil, fmt.Errorf("no API addresses to connect to")
 	}
-	pool := x509.NewCertPool()
-	xcert, err := cert.ParseCert(info.CACert)
+	// The CA certificate may hold more than one certificate
+	// while the state server CA is being rotated.
+	pool, err := cert.ParseCertPool(info.CACert)
 	if err != nil {
 		return nil, err
 	}
-	pool.AddCert(xcert)
 
 	var environUUID string
 	if info.EnvironTag.Id() != "" {
@@ -190,6 +191,7 @@ func Open(info *Info, opts DialOpts) (*State, error) {
 		// state structure BEFORE login ?!?
 		tag:      toString(info.Tag),
 		password: info.Password,
+		nonce:    info.Nonce,
 		certPool: pool,
 	}
 	if info.Tag != nil || info.Password != "" {
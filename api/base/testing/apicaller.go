@@ -23,3 +23,14 @@ func (APICallerFunc) EnvironTag() (names.EnvironTag, error) {
 func (APICallerFunc) Close() error {
 	return nil
 }
+
+// BestVersionCaller is an APICallerFunc that reports BestVersion as
+// the best version of every facade.
+type BestVersionCaller struct {
+	APICallerFunc
+	BestVersion int
+}
+
+func (c BestVersionCaller) BestFacadeVersion(facade string) int {
+	return c.BestVersion
+}
@@ -0,0 +1,46 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package capabilities
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the capabilities API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the capabilities API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Capabilities")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// EnvironCapabilities returns the capabilities of the environment.
+func (c *Client) EnvironCapabilities() (params.EnvironCapabilities, error) {
+	var result params.EnvironCapabilities
+	if err := c.facade.FacadeCall("EnvironCapabilities", nil, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// CheckCharmCompatibility checks the given prospective deployments of
+// charms against the environment, returning a report for each.
+func (c *Client) CheckCharmCompatibility(checks ...params.CharmCompatibilityCheck) ([]params.CharmCompatibilityReport, error) {
+	args := params.CharmCompatibilityChecks{Checks: checks}
+	var results params.CharmCompatibilityReports
+	if err := c.facade.FacadeCall("CheckCharmCompatibility", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(checks) {
+		return nil, errors.Errorf("expected %d results, got %d", len(checks), len(results.Results))
+	}
+	return results.Results, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package capabilities_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/capabilities"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type capabilitiesSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&capabilitiesSuite{})
+
+func (s *capabilitiesSuite) TestEnvironCapabilities(c *gc.C) {
+	expected := params.EnvironCapabilities{
+		Architectures: []string{"amd64"},
+		UnitPlacement: true,
+		Containers:    []string{"lxc"},
+		FirewallMode:  "instance",
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Capabilities")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "EnvironCapabilities")
+			c.Check(a, gc.IsNil)
+			*response.(*params.EnvironCapabilities) = expected
+			return nil
+		})
+	client := capabilities.NewClient(apiCaller)
+	caps, err := client.EnvironCapabilities()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caps, jc.DeepEquals, expected)
+}
+
+func (s *capabilitiesSuite) TestEnvironCapabilitiesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := capabilities.NewClient(apiCaller)
+	_, err := client.EnvironCapabilities()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *capabilitiesSuite) TestCheckCharmCompatibility(c *gc.C) {
+	check := params.CharmCompatibilityCheck{
+		CharmURL:      "cs:trusty/wordpress-1",
+		ToMachineSpec: "0",
+	}
+	expected := []params.CharmCompatibilityReport{{
+		Incompatibilities: []params.CharmIncompatibility{{
+			Kind:    "series",
+			Message: `machine 0 has series "precise", charm is for series "trusty"`,
+		}},
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Capabilities")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "CheckCharmCompatibility")
+			c.Check(a, jc.DeepEquals, params.CharmCompatibilityChecks{
+				Checks: []params.CharmCompatibilityCheck{check},
+			})
+			*response.(*params.CharmCompatibilityReports) = params.CharmCompatibilityReports{
+				Results: expected,
+			}
+			return nil
+		})
+	client := capabilities.NewClient(apiCaller)
+	reports, err := client.CheckCharmCompatibility(check)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(reports, jc.DeepEquals, expected)
+}
+
+func (s *capabilitiesSuite) TestCheckCharmCompatibilityResultCount(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return nil
+		})
+	client := capabilities.NewClient(apiCaller)
+	_, err := client.CheckCharmCompatibility(params.CharmCompatibilityCheck{})
+	c.Assert(err, gc.ErrorMatches, "expected 1 results, got 0")
+}
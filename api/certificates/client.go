@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certificates
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the certificates API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the certificates API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Certificates")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// RotateServerCertificate installs a new state server certificate and
+// key. If caCert is not empty, the certificate must be signed by it and
+// it is added to the CA certificates trusted by agents.
+func (c *Client) RotateServerCertificate(cert, key, caCert, caKey string) error {
+	args := params.RotateCertificateArgs{
+		Cert:         cert,
+		PrivateKey:   key,
+		CACert:       caCert,
+		CAPrivateKey: caKey,
+	}
+	var result params.ErrorResult
+	if err := c.facade.FacadeCall("RotateServerCertificate", args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certificates_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/certificates"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type certificatesSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&certificatesSuite{})
+
+func (s *certificatesSuite) TestRotateServerCertificate(c *gc.C) {
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Certificates")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "RotateServerCertificate")
+			c.Check(a, jc.DeepEquals, params.RotateCertificateArgs{
+				Cert:         "cert",
+				PrivateKey:   "key",
+				CACert:       "ca-cert",
+				CAPrivateKey: "ca-key",
+			})
+			_, ok := response.(*params.ErrorResult)
+			c.Assert(ok, jc.IsTrue)
+			return nil
+		})
+	client := certificates.NewClient(apiCaller)
+	err := client.RotateServerCertificate("cert", "key", "ca-cert", "ca-key")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.ErrorResult)
+			result.Error = common.ServerError(errors.New("bad cert"))
+			return nil
+		})
+	client := certificates.NewClient(apiCaller)
+	err := client.RotateServerCertificate("cert", "key", "", "")
+	c.Assert(err, gc.ErrorMatches, "bad cert")
+}
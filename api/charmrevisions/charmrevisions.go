@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmrevisions
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the CharmRevisions API facade, used to
+// follow the newer revisions of the charms of the environment's
+// services found in the charm store.
+type State struct {
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	return &State{
+		facade: base.NewFacadeCaller(caller, "CharmRevisions"),
+	}
+}
+
+// WatchCharmRevisions returns a NotifyWatcher which notifies when the
+// revision of any service's charm, or the latest revision available
+// to it in the charm store, changes, or a service is added or removed.
+func (st *State) WatchCharmRevisions() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	if err := st.facade.FacadeCall("WatchCharmRevisions", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// CharmRevisions returns the revisions of the charms of all the
+// services in the environment, and the latest revisions available to
+// them in the channels they track, ordered by service name.
+func (st *State) CharmRevisions() ([]params.ServiceCharmRevision, error) {
+	var result params.ServiceCharmRevisions
+	if err := st.facade.FacadeCall("CharmRevisions", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Revisions, nil
+}
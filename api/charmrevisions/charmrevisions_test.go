@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmrevisions_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/charmrevisions"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type charmRevisionsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&charmRevisionsSuite{})
+
+func (s *charmRevisionsSuite) TestCharmRevisions(c *gc.C) {
+	expected := []params.ServiceCharmRevision{{
+		ServiceTag:     "service-mysql",
+		Channel:        "edge",
+		CharmURL:       "cs:trusty/mysql-3",
+		LatestCharmURL: "cs:trusty/mysql-5",
+		LatestRevision: 5,
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "CharmRevisions")
+			c.Check(request, gc.Equals, "CharmRevisions")
+			c.Check(a, gc.IsNil)
+			*(response.(*params.ServiceCharmRevisions)) = params.ServiceCharmRevisions{Revisions: expected}
+			return nil
+		})
+	revisions, err := charmrevisions.NewState(apiCaller).CharmRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, jc.DeepEquals, expected)
+}
+
+func (s *charmRevisionsSuite) TestWatchCharmRevisionsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "CharmRevisions")
+			c.Check(request, gc.Equals, "WatchCharmRevisions")
+			*(response.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+				Error: &params.Error{Message: "permission denied", Code: params.CodeUnauthorized},
+			}
+			return nil
+		})
+	_, err := charmrevisions.NewState(apiCaller).WatchCharmRevisions()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnauthorized)
+}
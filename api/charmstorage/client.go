@@ -0,0 +1,33 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmstorage
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the charm storage API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the charm storage API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "CharmStorage")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Usage returns the storage used by the environment's charm archives,
+// and the environment's charm storage quota.
+func (c *Client) Usage() (params.CharmStorageUsage, error) {
+	var result params.CharmStorageUsage
+	if err := c.facade.FacadeCall("Usage", nil, &result); err != nil {
+		return params.CharmStorageUsage{}, errors.Trace(err)
+	}
+	return result, nil
+}
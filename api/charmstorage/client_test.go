@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmstorage_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/charmstorage"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type charmStorageSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&charmStorageSuite{})
+
+func (s *charmStorageSuite) TestUsage(c *gc.C) {
+	unusedSince := time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+	expected := params.CharmStorageUsage{
+		Size:  1234,
+		Quota: 1024 * 1024,
+		Charms: []params.CharmUsage{{
+			URL:      "local:quantal/wordpress-1",
+			Size:     1000,
+			RefCount: 2,
+		}, {
+			URL:         "local:quantal/mysql-1",
+			Size:        234,
+			UnusedSince: &unusedSince,
+		}},
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "CharmStorage")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Usage")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.CharmStorageUsage)
+			*result = expected
+			return nil
+		})
+	client := charmstorage.NewClient(apiCaller)
+	usage, err := client.Usage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage, jc.DeepEquals, expected)
+}
+
+func (s *charmStorageSuite) TestUsageError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := charmstorage.NewClient(apiCaller)
+	_, err := client.Usage()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
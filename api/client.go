@@ -56,6 +56,11 @@ type AgentStatus struct {
 	Version string
 	Life    string
 	Err     error
+
+	// LastSeen holds when the agent was last heard from by an API
+	// server. It is nil if no API server has recorded the agent,
+	// or if the server predates agent heartbeats.
+	LastSeen *time.Time
 }
 
 // MachineStatus holds status info about a machine.
@@ -95,11 +100,40 @@ type ServiceStatus struct {
 	CanUpgradeTo  string
 	SubordinateTo []string
 	Units         map[string]UnitStatus
+
+	// Status holds the status of the service, rolled up from the
+	// workload statuses of its units.
+	Status WorkloadStatus
+
+	// WorkloadVersion holds the version of the software run by the
+	// service's units, as reported by its lowest numbered unit that
+	// reported one.
+	WorkloadVersion string
+
+	// MeterStatus holds the worst meter status of the service's
+	// units. It is only set when that status is AMBER or RED.
+	MeterStatus *MeterStatus
+}
+
+// MeterStatus holds the meter status of a unit or service.
+type MeterStatus struct {
+	Code string
+	Info string
+}
+
+// WorkloadStatus holds status info about the software a unit runs, as
+// reported by the unit's charm.
+type WorkloadStatus struct {
+	Status params.Status
+	Info   string
+	Data   map[string]interface{}
 }
 
 // UnitStatus holds status info about a unit.
 type UnitStatus struct {
-	Agent AgentStatus
+	Agent           AgentStatus
+	Workload        WorkloadStatus
+	WorkloadVersion string
 
 	// See the comment in MachineStatus regarding these fields.
 	AgentState     params.Status
@@ -113,6 +147,10 @@ type UnitStatus struct {
 	PublicAddress string
 	Charm         string
 	Subordinates  map[string]UnitStatus
+
+	// MeterStatus holds the unit's meter status. It is only set when
+	// that status is AMBER or RED.
+	MeterStatus *MeterStatus
 }
 
 // RelationStatus holds status info about a relation.
@@ -155,14 +193,46 @@ type Status struct {
 
 // Status returns the status of the juju environment.
 func (c *Client) Status(patterns []string) (*Status, error) {
+	return c.StatusSections(patterns)
+}
+
+// StatusSections returns the given sections of the status of the juju
+// environment, or all of them if none are given. Servers that do not
+// support sections return all of them regardless.
+func (c *Client) StatusSections(patterns []string, sections ...params.StatusSection) (*Status, error) {
 	var result Status
-	p := params.StatusParams{Patterns: patterns}
+	p := params.StatusParams{
+		Patterns: patterns,
+		Sections: sections,
+	}
 	if err := c.facade.FacadeCall("FullStatus", p, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
+// ListMachines returns a page of the machines in the environment
+// that match the filters in args.
+func (c *Client) ListMachines(args params.ListMachinesArgs) (params.ListMachinesResult, error) {
+	var result params.ListMachinesResult
+	if c.facade.BestAPIVersion() < 1 {
+		return result, errors.NotImplementedf("ListMachines() (need V1+)")
+	}
+	err := c.facade.FacadeCall("ListMachines", args, &result)
+	return result, err
+}
+
+// ListUnits returns a page of the units in the environment that match
+// the filters in args.
+func (c *Client) ListUnits(args params.ListUnitsArgs) (params.ListUnitsResult, error) {
+	var result params.ListUnitsResult
+	if c.facade.BestAPIVersion() < 1 {
+		return result, errors.NotImplementedf("ListUnits() (need V1+)")
+	}
+	err := c.facade.FacadeCall("ListUnits", args, &result)
+	return result, err
+}
+
 // LegacyMachineStatus holds just the instance-id of a machine.
 type LegacyMachineStatus struct {
 	InstanceId string // Not type instance.Id just to match original api.
@@ -225,6 +295,185 @@ func (c *Client) RetryProvisioning(machines ...names.MachineTag) ([]params.Error
 	return results.Results, err
 }
 
+// RetryProvisioningWithOverrides updates the provisioning status of
+// the given machines allowing the provisioner to retry, after applying
+// any changes to the parameters used to provision them.
+func (c *Client) RetryProvisioningWithOverrides(machines ...params.RetryProvisioningArg) ([]params.ErrorResult, error) {
+	p := params.RetryProvisioningArgs{Machines: machines}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("RetryProvisioningWithOverrides", p, &results)
+	return results.Results, err
+}
+
+// RefreshMachineHardware asks the agents of the given machines to
+// re-detect their hardware characteristics.
+func (c *Client) RefreshMachineHardware(machines ...names.MachineTag) ([]params.ErrorResult, error) {
+	p := params.Entities{}
+	p.Entities = make([]params.Entity, len(machines))
+	for i, machine := range machines {
+		p.Entities[i] = params.Entity{Tag: machine.String()}
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("RefreshMachineHardware", p, &results)
+	return results.Results, err
+}
+
+// UnitHookQueue returns the hooks the agent of the given unit last
+// reported it has yet to run, and those of them it has been asked not
+// to run.
+func (c *Client) UnitHookQueue(unit string) (params.HookQueueResult, error) {
+	if !names.IsValidUnit(unit) {
+		return params.HookQueueResult{}, errors.NotValidf("unit name %q", unit)
+	}
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewUnitTag(unit).String()}},
+	}
+	var results params.HookQueueResults
+	if err := c.facade.FacadeCall("HookQueues", p, &results); err != nil {
+		return params.HookQueueResult{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.HookQueueResult{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.HookQueueResult{}, result.Error
+	}
+	return result, nil
+}
+
+// UnitHookResult returns the outcome, and the tail of the output, of
+// the hook the agent of the given unit ran most recently.
+func (c *Client) UnitHookResult(unit string) (params.HookResult, error) {
+	if !names.IsValidUnit(unit) {
+		return params.HookResult{}, errors.NotValidf("unit name %q", unit)
+	}
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewUnitTag(unit).String()}},
+	}
+	var results params.HookResultResults
+	if err := c.facade.FacadeCall("HookResults", p, &results); err != nil {
+		return params.HookResult{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.HookResult{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.HookResult{}, result.Error
+	}
+	return *result.Result, nil
+}
+
+// UnitRelationStates returns the bookkeeping the agent of the given
+// unit keeps for each of the unit's relations, recording which
+// relation hooks it has run.
+func (c *Client) UnitRelationStates(unit string) ([]params.UnitRelationState, error) {
+	if !names.IsValidUnit(unit) {
+		return nil, errors.NotValidf("unit name %q", unit)
+	}
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewUnitTag(unit).String()}},
+	}
+	var results params.UnitRelationStatesResults
+	if err := c.facade.FacadeCall("UnitRelationStates", p, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.States, nil
+}
+
+// DropQueuedHook asks the agent of the given unit not to run the
+// queued hook with the given id.
+func (c *Client) DropQueuedHook(unit, hookId string) error {
+	if !names.IsValidUnit(unit) {
+		return errors.NotValidf("unit name %q", unit)
+	}
+	p := params.DropQueuedHookArgs{
+		Args: []params.DropQueuedHookArg{{
+			Tag:    names.NewUnitTag(unit).String(),
+			HookId: hookId,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("DropQueuedHooks", p, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// AddContainerAddressPool adds an address pool for the containers
+// attached to the given bridge on the given machine. The pool's
+// gateway defaults to the first address in cidr; reserved addresses
+// and ranges, such as "10.0.3.2-10.0.3.99", are never allocated.
+func (c *Client) AddContainerAddressPool(machineId, bridge, cidr, gateway string, reserved []string) error {
+	if !names.IsValidMachine(machineId) {
+		return errors.NotValidf("machine id %q", machineId)
+	}
+	p := params.ContainerAddressPools{
+		Pools: []params.ContainerAddressPool{{
+			MachineTag: names.NewMachineTag(machineId).String(),
+			Bridge:     bridge,
+			CIDR:       cidr,
+			Gateway:    gateway,
+			Reserved:   reserved,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("AddContainerAddressPools", p, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// RemoveContainerAddressPool removes the address pool for the given
+// bridge on the given machine.
+func (c *Client) RemoveContainerAddressPool(machineId, bridge string) error {
+	if !names.IsValidMachine(machineId) {
+		return errors.NotValidf("machine id %q", machineId)
+	}
+	p := params.ContainerAddressPools{
+		Pools: []params.ContainerAddressPool{{
+			MachineTag: names.NewMachineTag(machineId).String(),
+			Bridge:     bridge,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RemoveContainerAddressPools", p, &results); err != nil {
+		return err
+	}
+	return results.OneError()
+}
+
+// ContainerAddressPools returns the address pools for the bridges on
+// the given machine, and the addresses allocated from them.
+func (c *Client) ContainerAddressPools(machineId string) ([]params.ContainerAddressPoolInfo, error) {
+	if !names.IsValidMachine(machineId) {
+		return nil, errors.NotValidf("machine id %q", machineId)
+	}
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewMachineTag(machineId).String()}},
+	}
+	var results params.ContainerAddressPoolsResults
+	if err := c.facade.FacadeCall("ContainerAddressPools", p, &results); err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Pools, nil
+}
+
 // PublicAddress returns the public address of the specified
 // machine or unit. For a machine, target is an id not a tag.
 func (c *Client) PublicAddress(target string) (string, error) {
@@ -243,6 +492,15 @@ func (c *Client) PrivateAddress(target string) (string, error) {
 	return results.PrivateAddress, err
 }
 
+// SSHHostKeys returns the public ssh host keys recorded for the
+// specified machine, or for the machine hosting the specified unit.
+func (c *Client) SSHHostKeys(target string) ([]string, error) {
+	var results params.SSHHostKeysResults
+	p := params.SSHHostKeys{Target: target}
+	err := c.facade.FacadeCall("SSHHostKeys", p, &results)
+	return results.PublicKeys, err
+}
+
 // ServiceSetYAML sets configuration options on a service
 // given options in YAML format.
 func (c *Client) ServiceSetYAML(service string, yaml string) error {
@@ -318,6 +576,45 @@ func (c *Client) ProvisioningScript(args params.ProvisioningScriptParams) (scrip
 	return result.Script, nil
 }
 
+// AddManualHost requests that the state server provision the given
+// existing host over SSH, and returns the id of the request.
+func (c *Client) AddManualHost(host string, disablePackageCommands bool) (string, error) {
+	args := params.AddManualHostArgs{
+		Hosts: []params.AddManualHostArg{{
+			Host:                   host,
+			DisablePackageCommands: disablePackageCommands,
+		}},
+	}
+	var results params.AddManualHostResults
+	if err := c.facade.FacadeCall("AddManualHosts", args, &results); err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return "", err
+	}
+	return results.Results[0].Id, nil
+}
+
+// ManualHostStatus returns the progress of the manual provisioning
+// request with the given id.
+func (c *Client) ManualHostStatus(id string) (params.ManualHostStatus, error) {
+	args := params.ManualHostIds{Ids: []string{id}}
+	var results params.ManualHostStatusResults
+	if err := c.facade.FacadeCall("ManualHostStatus", args, &results); err != nil {
+		return params.ManualHostStatus{}, err
+	}
+	if len(results.Results) != 1 {
+		return params.ManualHostStatus{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return params.ManualHostStatus{}, err
+	}
+	return results.Results[0].Status, nil
+}
+
 // DestroyMachines removes a given set of machines.
 func (c *Client) DestroyMachines(machines ...string) error {
 	params := params.DestroyMachines{MachineNames: machines}
@@ -330,10 +627,19 @@ func (c *Client) ForceDestroyMachines(machines ...string) error {
 	return c.facade.FacadeCall("DestroyMachines", params, nil)
 }
 
+// EvacuateMachines moves the units on the given machines onto other
+// machines, and destroys the machines once the units replacing them
+// are running.
+func (c *Client) EvacuateMachines(machines ...string) error {
+	params := params.DestroyMachines{Evacuate: true, MachineNames: machines}
+	return c.facade.FacadeCall("DestroyMachines", params, nil)
+}
+
 // ServiceExpose changes the juju-managed firewall to expose any ports that
-// were also explicitly marked by units as open.
-func (c *Client) ServiceExpose(service string) error {
-	params := params.ServiceExpose{ServiceName: service}
+// were also explicitly marked by units as open. If any CIDRs are given,
+// the ports are exposed only to those address ranges.
+func (c *Client) ServiceExpose(service string, cidrs ...string) error {
+	params := params.ServiceExpose{ServiceName: service, CIDRs: cidrs}
 	return c.facade.FacadeCall("ServiceExpose", params, nil)
 }
 
@@ -371,6 +677,22 @@ func (c *Client) ServiceDeployWithNetworks(
 	return c.facade.FacadeCall("ServiceDeployWithNetworks", params, nil)
 }
 
+// ServiceDeployWithWorkloadContainer works exactly like
+// ServiceDeployWithNetworks, but also allows args.WorkloadContainer to
+// specify the type of container in which the charm of each of the
+// service's units runs, on the machine the unit is assigned to.
+func (c *Client) ServiceDeployWithWorkloadContainer(args params.ServiceDeploy) error {
+	return c.facade.FacadeCall("ServiceDeployWithWorkloadContainer", args, nil)
+}
+
+// ServiceDeployWithChannel works exactly like
+// ServiceDeployWithWorkloadContainer, but also allows args.Channel to
+// specify the charm store channel the service tracks for upgrades of
+// its charm.
+func (c *Client) ServiceDeployWithChannel(args params.ServiceDeploy) error {
+	return c.facade.FacadeCall("ServiceDeployWithChannel", args, nil)
+}
+
 // ServiceDeploy obtains the charm, either locally or from the charm store,
 // and deploys it.
 func (c *Client) ServiceDeploy(charmURL string, serviceName string, numUnits int, configYAML string, cons constraints.Value, toMachineSpec string) error {
@@ -402,6 +724,19 @@ func (c *Client) ServiceSetCharm(serviceName string, charmUrl string, force bool
 	return c.facade.FacadeCall("ServiceSetCharm", args, nil)
 }
 
+// ServiceSetCharmWithChannel sets the charm for a given service, and
+// the charm store channel it tracks for upgrades of its charm from
+// then on.
+func (c *Client) ServiceSetCharmWithChannel(serviceName, charmUrl string, force bool, channel string) error {
+	args := params.ServiceSetCharm{
+		ServiceName: serviceName,
+		CharmUrl:    charmUrl,
+		Force:       force,
+		Channel:     channel,
+	}
+	return c.facade.FacadeCall("ServiceSetCharmWithChannel", args, nil)
+}
+
 // ServiceGetCharmURL returns the charm URL the given service is
 // running at present.
 func (c *Client) ServiceGetCharmURL(serviceName string) (*charm.URL, error) {
@@ -414,6 +749,17 @@ func (c *Client) ServiceGetCharmURL(serviceName string) (*charm.URL, error) {
 	return charm.ParseURL(result.Result)
 }
 
+// ServiceGetCharmChannel returns the charm store channel the given
+// service tracks for upgrades of its charm.
+func (c *Client) ServiceGetCharmChannel(serviceName string) (string, error) {
+	var result params.StringResult
+	args := params.ServiceGet{ServiceName: serviceName}
+	if err := c.facade.FacadeCall("ServiceGetCharmChannel", args, &result); err != nil {
+		return "", err
+	}
+	return result.Result, nil
+}
+
 // AddServiceUnits adds a given number of units to a service.
 func (c *Client) AddServiceUnits(service string, numUnits int, machineSpec string) ([]string, error) {
 	args := params.AddServiceUnits{
@@ -455,20 +801,28 @@ func (c *Client) GetEnvironmentConstraints() (constraints.Value, error) {
 }
 
 // SetServiceConstraints specifies the constraints for the given service.
-func (c *Client) SetServiceConstraints(service string, constraints constraints.Value) error {
-	params := params.SetConstraints{
+// It returns a warning for each constraint which is not supported by
+// the environment, and so will be ignored. Older servers never return
+// any warnings.
+func (c *Client) SetServiceConstraints(service string, constraints constraints.Value) ([]params.ConstraintsWarning, error) {
+	args := params.SetConstraints{
 		ServiceName: service,
 		Constraints: constraints,
 	}
-	return c.facade.FacadeCall("SetServiceConstraints", params, nil)
+	var result params.SetConstraintsResult
+	err := c.facade.FacadeCall("SetServiceConstraints", args, &result)
+	return result.Warnings, err
 }
 
 // SetEnvironmentConstraints specifies the constraints for the environment.
-func (c *Client) SetEnvironmentConstraints(constraints constraints.Value) error {
-	params := params.SetConstraints{
+// Unsupported constraints are reported as for SetServiceConstraints.
+func (c *Client) SetEnvironmentConstraints(constraints constraints.Value) ([]params.ConstraintsWarning, error) {
+	args := params.SetConstraints{
 		Constraints: constraints,
 	}
-	return c.facade.FacadeCall("SetEnvironmentConstraints", params, nil)
+	var result params.SetConstraintsResult
+	err := c.facade.FacadeCall("SetEnvironmentConstraints", args, &result)
+	return result.Warnings, err
 }
 
 // CharmInfo holds information about a charm.
@@ -618,13 +972,33 @@ func (c *Client) EnvironmentUnset(keys ...string) error {
 	return c.facade.FacadeCall("EnvironmentUnset", args, nil)
 }
 
+// SuspendEnvironment suspends the environment's automation, for the
+// given reason, until ResumeEnvironment is called.
+func (c *Client) SuspendEnvironment(reason string) error {
+	args := params.SuspendEnvironment{Reason: reason}
+	return c.facade.FacadeCall("SuspendEnvironment", args, nil)
+}
+
+// ResumeEnvironment resumes the environment's automation after a call
+// to SuspendEnvironment.
+func (c *Client) ResumeEnvironment() error {
+	return c.facade.FacadeCall("ResumeEnvironment", nil, nil)
+}
+
 // SetEnvironAgentVersion sets the environment agent-version setting
-// to the given value.
+// to the given value. It fails if any of the upgrade prechecks fail.
 func (c *Client) SetEnvironAgentVersion(version version.Number) error {
 	args := params.SetEnvironAgentVersion{Version: version}
 	return c.facade.FacadeCall("SetEnvironAgentVersion", args, nil)
 }
 
+// ForceSetEnvironAgentVersion sets the environment agent-version
+// setting to the given value, ignoring the upgrade prechecks.
+func (c *Client) ForceSetEnvironAgentVersion(version version.Number) error {
+	args := params.SetEnvironAgentVersion{Version: version, Force: true}
+	return c.facade.FacadeCall("SetEnvironAgentVersion", args, nil)
+}
+
 // AbortCurrentUpgrade aborts and archives the current upgrade
 // synchronisation record, if any.
 func (c *Client) AbortCurrentUpgrade() error {
@@ -666,9 +1040,25 @@ func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
 // DestroyEnvironment puts the environment into a "dying" state,
 // and removes all non-manager machine instances. DestroyEnvironment
 // will fail if there are any manually-provisioned non-manager machines
-// in state.
+// in state, or if any of the destroy-environment prechecks fail.
 func (c *Client) DestroyEnvironment() error {
-	return c.facade.FacadeCall("DestroyEnvironment", nil, nil)
+	return c.facade.FacadeCall("DestroyEnvironment", params.DestroyEnvironment{}, nil)
+}
+
+// ForceDestroyEnvironment destroys the environment as DestroyEnvironment
+// does, ignoring the destroy-environment prechecks.
+func (c *Client) ForceDestroyEnvironment() error {
+	args := params.DestroyEnvironment{Force: true}
+	return c.facade.FacadeCall("DestroyEnvironment", args, nil)
+}
+
+// Precheck runs the prechecks of the given operation against the
+// entities with the given tags, without performing the operation.
+func (c *Client) Precheck(op params.PrecheckOperation, tags ...string) (params.PrecheckReport, error) {
+	var report params.PrecheckReport
+	args := params.PrecheckArgs{Operation: op, Entities: tags}
+	err := c.facade.FacadeCall("Precheck", args, &report)
+	return report, err
 }
 
 // AddLocalCharm prepares the given charm with a local: schema in its
@@ -761,7 +1151,18 @@ func (c *Client) AddCharm(curl *charm.URL) error {
 // ResolveCharm resolves the best available charm URLs with series, for charm
 // locations without a series specified.
 func (c *Client) ResolveCharm(ref *charm.Reference) (*charm.URL, error) {
-	args := params.ResolveCharms{References: []charm.Reference{*ref}}
+	return c.ResolveCharmWithChannel(ref, "")
+}
+
+// ResolveCharmWithChannel resolves the best available charm URL with
+// series from the given charm store channel. If the reference has no
+// revision, it is resolved to the latest revision published to the
+// channel. An empty channel resolves the charm as ResolveCharm does.
+func (c *Client) ResolveCharmWithChannel(ref *charm.Reference, channel string) (*charm.URL, error) {
+	args := params.ResolveCharms{
+		References: []charm.Reference{*ref},
+		Channel:    channel,
+	}
 	result := new(params.ResolveCharmResults)
 	if err := c.facade.FacadeCall("ResolveCharms", args, result); err != nil {
 		return nil, err
@@ -832,6 +1233,50 @@ func (c *Client) UploadTools(r io.Reader, vers version.Binary, additionalSeries
 	return jsonResponse.Tools, nil
 }
 
+// UploadImage sends the container image tarball read from r to the API
+// server, where it replaces any image stored for the same kind of
+// container, series and architecture.
+func (c *Client) UploadImage(r io.Reader, kind instance.ContainerType, series, arch string) error {
+	envTag, err := c.st.EnvironTag()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	url := fmt.Sprintf(
+		"%s/environment/%s/images/%s/%s/%s",
+		c.st.serverRoot, envTag.Id(), kind, series, arch,
+	)
+	req, err := http.NewRequest("POST", url, r)
+	if err != nil {
+		return errors.Annotate(err, "cannot create upload request")
+	}
+	req.SetBasicAuth(c.st.tag, c.st.password)
+	req.Header.Set("Content-Type", "application/x-tar-gz")
+
+	// See the comment in UploadTools for why the
+	// API server's certificate is not validated.
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
+	if err != nil {
+		return errors.Annotate(err, "cannot upload image")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Annotate(err, "cannot read image upload response")
+	}
+	var jsonResponse params.ErrorResult
+	if err := json.Unmarshal(body, &jsonResponse); err != nil {
+		return errors.Errorf("image upload failed: %v (%s)", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if err := jsonResponse.Error; err != nil {
+		return errors.Annotate(err, "error uploading image")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("image upload failed: %v", resp.StatusCode)
+	}
+	return nil
+}
+
 // APIHostPorts returns a slice of network.HostPort for each API server.
 func (c *Client) APIHostPorts() ([][]network.HostPort, error) {
 	var result params.APIHostPortsResult
@@ -905,6 +1350,18 @@ type DebugLogParams struct {
 	// ExcludeModule lists logging modules to exclude from the resposne. If a
 	// module is specified, all the submodules are also excluded.
 	ExcludeModule []string
+	// IncludeModuleRegexp lists regular expressions, one of which the
+	// logging module of each line in the response must match.
+	IncludeModuleRegexp []string
+	// ExcludeModuleRegexp lists regular expressions, none of which the
+	// logging module of each line in the response may match.
+	ExcludeModuleRegexp []string
+	// IncludeMessage lists regular expressions, one of which the message
+	// of each line in the response must match.
+	IncludeMessage []string
+	// ExcludeMessage lists regular expressions, none of which the message
+	// of each line in the response may match.
+	ExcludeMessage []string
 	// Limit defines the maximum number of lines to return. Once this many
 	// have been sent, the socket is closed.  If zero, all filtered lines are
 	// sent down the connection until the client closes the connection.
@@ -913,11 +1370,24 @@ type DebugLogParams struct {
 	// starting filtering. If backlog is zero and replay is false, then there
 	// may be an initial delay until the next matching log message is written.
 	Backlog uint
+	// BacklogPerEntity tells the server to apply the backlog to each
+	// entity separately, so that every entity's recent lines are shown.
+	BacklogPerEntity bool
+	// MaxRate limits the number of lines per second sent back in the
+	// response. If zero, the server's own limit applies.
+	MaxRate uint
 	// Level specifies the minimum logging level to be sent back in the response.
 	Level loggo.Level
 	// Replay tells the server to start at the start of the log file rather
 	// than the end. If replay is true, backlog is ignored.
 	Replay bool
+	// StartTime, if set, limits the response to lines logged at or after
+	// the given time, and causes those already logged to be replayed.
+	StartTime time.Time
+	// EndTime, if set, limits the response to lines logged at or before
+	// the given time. When it is set, the connection is closed once the
+	// matching lines have been sent.
+	EndTime time.Time
 }
 
 // WatchDebugLog returns a ReadCloser that the caller can read the log
@@ -947,13 +1417,29 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	if args.Backlog > 0 {
 		attrs.Set("backlog", fmt.Sprint(args.Backlog))
 	}
+	if args.BacklogPerEntity {
+		attrs.Set("backlogPerEntity", fmt.Sprint(args.BacklogPerEntity))
+	}
+	if args.MaxRate > 0 {
+		attrs.Set("maxRate", fmt.Sprint(args.MaxRate))
+	}
 	if args.Level != loggo.UNSPECIFIED {
 		attrs.Set("level", fmt.Sprint(args.Level))
 	}
+	if !args.StartTime.IsZero() {
+		attrs.Set("startTime", args.StartTime.UTC().Format(time.RFC3339))
+	}
+	if !args.EndTime.IsZero() {
+		attrs.Set("endTime", args.EndTime.UTC().Format(time.RFC3339))
+	}
 	attrs["includeEntity"] = args.IncludeEntity
 	attrs["includeModule"] = args.IncludeModule
 	attrs["excludeEntity"] = args.ExcludeEntity
 	attrs["excludeModule"] = args.ExcludeModule
+	attrs["includeModuleRegexp"] = args.IncludeModuleRegexp
+	attrs["excludeModuleRegexp"] = args.ExcludeModuleRegexp
+	attrs["includeMessage"] = args.IncludeMessage
+	attrs["excludeMessage"] = args.ExcludeMessage
 
 	target := url.URL{
 		Scheme:   "wss",
@@ -968,13 +1454,22 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	// Read the initial error and translate to a real error.
+	if err := readInitialStreamError(connection); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+// readInitialStreamError reads the JSON encoded params.ErrorResult
+// that the API server sends as the first line of a websocket stream,
+// and translates it to a real error.
+func readInitialStreamError(conn io.Reader) error {
 	// Read up to the first new line character. We can't use bufio here as it
 	// reads too much from the reader.
 	line := make([]byte, 4096)
-	n, err := connection.Read(line)
+	n, err := conn.Read(line)
 	if err != nil {
-		return nil, errors.Annotate(err, "unable to read initial response")
+		return errors.Annotate(err, "unable to read initial response")
 	}
 	line = line[0:n]
 
@@ -982,10 +1477,10 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	var errResult params.ErrorResult
 	err = json.Unmarshal(line, &errResult)
 	if err != nil {
-		return nil, errors.Annotate(err, "unable to unmarshal initial response")
+		return errors.Annotate(err, "unable to unmarshal initial response")
 	}
 	if errResult.Error != nil {
-		return nil, errResult.Error
+		return errResult.Error
 	}
-	return connection, nil
+	return nil
 }
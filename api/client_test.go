@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
 	"github.com/juju/errors"
@@ -274,14 +275,22 @@ func (s *clientSuite) TestParamsEncoded(c *gc.C) {
 	s.PatchValue(api.WebsocketDialConfig, echoURL(c))
 
 	params := api.DebugLogParams{
-		IncludeEntity: []string{"a", "b"},
-		IncludeModule: []string{"c", "d"},
-		ExcludeEntity: []string{"e", "f"},
-		ExcludeModule: []string{"g", "h"},
-		Limit:         100,
-		Backlog:       200,
-		Level:         loggo.ERROR,
-		Replay:        true,
+		IncludeEntity:       []string{"a", "b"},
+		IncludeModule:       []string{"c", "d"},
+		ExcludeEntity:       []string{"e", "f"},
+		ExcludeModule:       []string{"g", "h"},
+		IncludeModuleRegexp: []string{"^i"},
+		ExcludeModuleRegexp: []string{"j$"},
+		IncludeMessage:      []string{"k+"},
+		ExcludeMessage:      []string{"l?"},
+		Limit:               100,
+		Backlog:             200,
+		BacklogPerEntity:    true,
+		MaxRate:             50,
+		Level:               loggo.ERROR,
+		Replay:              true,
+		StartTime:           time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+		EndTime:             time.Date(2015, 4, 1, 13, 0, 0, 0, time.UTC),
 	}
 
 	client := s.APIState.Client()
@@ -293,14 +302,22 @@ func (s *clientSuite) TestParamsEncoded(c *gc.C) {
 	c.Assert(connectURL.Path, gc.Matches, "/log")
 	values := connectURL.Query()
 	c.Assert(values, jc.DeepEquals, url.Values{
-		"includeEntity": params.IncludeEntity,
-		"includeModule": params.IncludeModule,
-		"excludeEntity": params.ExcludeEntity,
-		"excludeModule": params.ExcludeModule,
-		"maxLines":      {"100"},
-		"backlog":       {"200"},
-		"level":         {"ERROR"},
-		"replay":        {"true"},
+		"includeEntity":       params.IncludeEntity,
+		"includeModule":       params.IncludeModule,
+		"excludeEntity":       params.ExcludeEntity,
+		"excludeModule":       params.ExcludeModule,
+		"includeModuleRegexp": params.IncludeModuleRegexp,
+		"excludeModuleRegexp": params.ExcludeModuleRegexp,
+		"includeMessage":      params.IncludeMessage,
+		"excludeMessage":      params.ExcludeMessage,
+		"maxLines":            {"100"},
+		"backlog":             {"200"},
+		"backlogPerEntity":    {"true"},
+		"maxRate":             {"50"},
+		"level":               {"ERROR"},
+		"replay":              {"true"},
+		"startTime":           {"2015-04-01T12:00:00Z"},
+		"endTime":             {"2015-04-01T13:00:00Z"},
 	})
 }
 
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package clouds provides access to the Clouds API facade, used to
+// manage the clouds on which the state server may host environments,
+// and the credentials users hold for them.
+package clouds
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides methods that the Juju client command uses to manage
+// clouds.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new `Client` based on an existing authenticated API
+// connection.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Clouds")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Clouds returns the clouds on which the state server may host
+// environments.
+func (c *Client) Clouds() ([]params.Cloud, error) {
+	var result params.Clouds
+	if err := c.facade.FacadeCall("Clouds", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Clouds, nil
+}
+
+// AddCloud adds a cloud of the given provider type on which the state
+// server may host environments.
+func (c *Client) AddCloud(name, providerType string, config map[string]interface{}) error {
+	args := params.Clouds{
+		Clouds: []params.Cloud{{
+			Name:   name,
+			Type:   providerType,
+			Config: config,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("AddClouds", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// UpdateCredential adds or replaces the named credential held by the
+// owner for the cloud.
+func (c *Client) UpdateCredential(owner, cloud, name string, attrs map[string]string) error {
+	if !names.IsValidUser(owner) {
+		return fmt.Errorf("invalid owner name %q", owner)
+	}
+	args := params.CloudCredentials{
+		Credentials: []params.CloudCredential{{
+			OwnerTag:   names.NewUserTag(owner).String(),
+			Cloud:      cloud,
+			Name:       name,
+			Attributes: attrs,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("UpdateCredentials", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
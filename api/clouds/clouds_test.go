@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package clouds_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/clouds"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/juju"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type cloudsSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&cloudsSuite{})
+
+func (s *cloudsSuite) openAPI(c *gc.C) *clouds.Client {
+	conn, err := juju.NewAPIState(s.AdminUserTag(c), s.Environ, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { conn.Close() })
+	return clouds.NewClient(conn)
+}
+
+func (s *cloudsSuite) TestFeatureNotEnabled(c *gc.C) {
+	_, err := s.openAPI(c).Clouds()
+	c.Assert(err, gc.ErrorMatches, `unknown object type "Clouds"`)
+}
+
+func (s *cloudsSuite) TestAddCloudAndList(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	client := s.openAPI(c)
+	err := client.AddCloud("aws", "ec2", map[string]interface{}{"region": "us-east-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = client.AddCloud("aws", "ec2", nil)
+	c.Assert(err, gc.ErrorMatches, `cloud "aws" already exists`)
+
+	result, err := client.Clouds()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, []params.Cloud{{
+		Name:   "aws",
+		Type:   "ec2",
+		Config: map[string]interface{}{"region": "us-east-1"},
+	}})
+}
+
+func (s *cloudsSuite) TestUpdateCredential(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	client := s.openAPI(c)
+	err := client.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = client.UpdateCredential(s.AdminUserTag(c).Username(), "aws", "default", map[string]string{"access-key": "key"})
+	c.Assert(err, jc.ErrorIsNil)
+	credential, err := s.State.CloudCredential(s.AdminUserTag(c), "aws", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credential.Attributes(), jc.DeepEquals, map[string]string{"access-key": "key"})
+
+	err = client.UpdateCredential("not a user", "aws", "default", nil)
+	c.Assert(err, gc.ErrorMatches, `invalid owner name "not a user"`)
+}
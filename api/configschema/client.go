@@ -0,0 +1,33 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configschema
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the config schema API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the config schema API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "ConfigSchema")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Schema returns the configuration attributes known to the API
+// server, ordered by name.
+func (c *Client) Schema() ([]params.ConfigAttr, error) {
+	var result params.ConfigSchemaResult
+	if err := c.facade.FacadeCall("Schema", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Attrs, nil
+}
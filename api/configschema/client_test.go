@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configschema_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/configschema"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type configSchemaSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&configSchemaSuite{})
+
+func (s *configSchemaSuite) TestSchema(c *gc.C) {
+	attrs := []params.ConfigAttr{{
+		Name:        "logging-config",
+		Type:        "string",
+		Group:       "environment",
+		Description: "The logging levels of the agents.",
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "ConfigSchema")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Schema")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.ConfigSchemaResult)
+			result.Attrs = attrs
+			return nil
+		})
+	client := configschema.NewClient(apiCaller)
+	result, err := client.Schema()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, attrs)
+}
+
+func (s *configSchemaSuite) TestSchemaError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := configschema.NewClient(apiCaller)
+	_, err := client.Schema()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
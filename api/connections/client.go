@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package connections
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the connections API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the connections API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Connections")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// List returns details of the connections being served by the API
+// server the client is connected to.
+func (c *Client) List() ([]params.ConnectionDetails, error) {
+	var result params.ConnectionDetailsResults
+	if err := c.facade.FacadeCall("List", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Connections, nil
+}
+
+// Disconnect forcibly terminates the connections with the given ids.
+func (c *Client) Disconnect(ids ...int64) error {
+	args := params.DisconnectArgs{Ids: ids}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("Disconnect", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.Combine()
+}
@@ -0,0 +1,71 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package connections_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/connections"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type connectionsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&connectionsSuite{})
+
+func (s *connectionsSuite) TestList(c *gc.C) {
+	expected := []params.ConnectionDetails{{
+		Id:          1,
+		EntityTag:   "user-admin",
+		RemoteAddr:  "10.0.0.1:1234",
+		ConnectedAt: time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Connections")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "List")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.ConnectionDetailsResults)
+			result.Connections = expected
+			return nil
+		})
+	client := connections.NewClient(apiCaller)
+	conns, err := client.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conns, jc.DeepEquals, expected)
+}
+
+func (s *connectionsSuite) TestDisconnect(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Connections")
+			c.Check(request, gc.Equals, "Disconnect")
+			c.Check(a, jc.DeepEquals, params.DisconnectArgs{Ids: []int64{1, 2}})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{
+				{},
+				{Error: &params.Error{Message: "connection 2 not found"}},
+			}
+			return nil
+		})
+	client := connections.NewClient(apiCaller)
+	err := client.Disconnect(1, 2)
+	c.Assert(err, gc.ErrorMatches, "connection 2 not found")
+}
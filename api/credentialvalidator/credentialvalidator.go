@@ -0,0 +1,42 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/common"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the CredentialValidator API facade, used to
+// record whether the provider accepts the environment's credentials.
+type State struct {
+	*common.EnvironWatcher
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	facadeCaller := base.NewFacadeCaller(caller, "CredentialValidator")
+	return &State{
+		EnvironWatcher: common.NewEnvironWatcher(facadeCaller),
+		facade:         facadeCaller,
+	}
+}
+
+// SetCredentialValidity records whether the provider accepts the
+// environment's credentials, and if not, why. The environment is
+// suspended while they are rejected.
+func (st *State) SetCredentialValidity(valid bool, reason string) error {
+	args := params.CredentialValidity{
+		Valid:  valid,
+		Reason: reason,
+	}
+	if err := st.facade.FacadeCall("SetCredentialValidity", args, nil); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
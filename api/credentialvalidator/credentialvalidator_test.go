@@ -0,0 +1,56 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/credentialvalidator"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type credentialValidatorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&credentialValidatorSuite{})
+
+func (s *credentialValidatorSuite) TestSetCredentialValidity(c *gc.C) {
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "CredentialValidator")
+			c.Check(request, gc.Equals, "SetCredentialValidity")
+			c.Check(a, jc.DeepEquals, params.CredentialValidity{
+				Reason: "secret rejected",
+			})
+			called = true
+			return nil
+		})
+	err := credentialvalidator.NewState(apiCaller).SetCredentialValidity(false, "secret rejected")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *credentialValidatorSuite) TestSetCredentialValidityError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	err := credentialvalidator.NewState(apiCaller).SetCredentialValidity(true, "")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"crypto/tls"
+	"io"
+	"net/url"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// ConnectDebugHooks starts a debug-hooks session with the given unit,
+// brokered by the API server, in which the given hooks are debugged;
+// if no hooks are given, all hooks are debugged. Data written to the
+// returned connection is sent to the shells in which the unit's agent
+// runs the hooks, and their output can be read from it. The session
+// ends when the connection is closed. It returns an error that
+// satisfies errors.IsNotSupported if the API server does not provide
+// the endpoint.
+func (s *State) ConnectDebugHooks(unit string, hooks []string) (io.ReadWriteCloser, error) {
+	attrs := url.Values{}
+	attrs.Set("unit", unit)
+	for _, hook := range hooks {
+		attrs.Add("hook", hook)
+	}
+	conn, err := s.dialDebugHooks("/debughooks", attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	conn.PayloadType = websocket.BinaryFrame
+	return conn, nil
+}
+
+// DebugHooksListener is held by a unit agent waiting for a client to
+// start a debug-hooks session with its unit.
+type DebugHooksListener struct {
+	conn *websocket.Conn
+}
+
+// ListenDebugHooks connects to the API server so that clients can
+// start debug-hooks sessions with the agent's unit. It returns an
+// error that satisfies errors.IsNotSupported if the API server does
+// not provide the endpoint.
+func (s *State) ListenDebugHooks() (*DebugHooksListener, error) {
+	conn, err := s.dialDebugHooks("/debughooks/agent", nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &DebugHooksListener{conn: conn}, nil
+}
+
+// Accept waits for a client to start a session, and returns the names
+// of the hooks the client wants to debug. After it returns, data
+// written to the listener is sent to the client, and data sent by the
+// client can be read from it.
+func (l *DebugHooksListener) Accept() ([]string, error) {
+	var session params.DebugHooksSession
+	if err := websocket.JSON.Receive(l.conn, &session); err != nil {
+		return nil, errors.Annotate(err, "cannot receive debug-hooks session")
+	}
+	l.conn.PayloadType = websocket.BinaryFrame
+	return session.Hooks, nil
+}
+
+// Read implements io.Reader.
+func (l *DebugHooksListener) Read(data []byte) (int, error) {
+	return l.conn.Read(data)
+}
+
+// Write implements io.Writer.
+func (l *DebugHooksListener) Write(data []byte) (int, error) {
+	return l.conn.Write(data)
+}
+
+// Close closes the connection to the API server, ending any session.
+func (l *DebugHooksListener) Close() error {
+	return l.conn.Close()
+}
+
+// dialDebugHooks opens a connection to the given path below the API
+// server's endpoints for the current environment.
+func (s *State) dialDebugHooks(path string, attrs url.Values) (*websocket.Conn, error) {
+	envTag, err := s.EnvironTag()
+	if err != nil {
+		return nil, errors.NotSupportedf("debug-hooks sessions")
+	}
+	target := url.URL{
+		Scheme:   "wss",
+		Host:     s.addr,
+		Path:     "/environment/" + envTag.Id() + path,
+		RawQuery: attrs.Encode(),
+	}
+	cfg, err := websocket.NewConfig(target.String(), "http://localhost/")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg.Header = utils.BasicAuthHeader(s.tag, s.password)
+	if s.nonce != "" {
+		cfg.Header.Set("X-Juju-Nonce", s.nonce)
+	}
+	cfg.TlsConfig = &tls.Config{RootCAs: s.certPool, ServerName: "juju-apiserver"}
+	conn, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to debug-hooks endpoint")
+	}
+	if err := readInitialStreamError(conn); err != nil {
+		conn.Close()
+		return nil, errors.Trace(err)
+	}
+	return conn, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/juju/juju/api/deployer"
 	apitesting "github.com/juju/juju/api/testing"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -228,6 +229,27 @@ func (s *deployerSuite) TestUnitSetPassword(c *gc.C) {
 	c.Assert(s.subordinate.PasswordValid("phony-12345678901234567890"), jc.IsTrue)
 }
 
+func (s *deployerSuite) TestUnitWorkloadContainer(c *gc.C) {
+	unit, err := s.st.Unit(s.principal.Tag().(names.UnitTag))
+	c.Assert(err, jc.ErrorIsNil)
+	containerType, err := unit.WorkloadContainer()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containerType, gc.Equals, instance.NONE)
+
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err = service.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	stateUnit, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = stateUnit.AssignToMachine(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err = s.st.Unit(stateUnit.Tag().(names.UnitTag))
+	c.Assert(err, jc.ErrorIsNil)
+	containerType, err = unit.WorkloadContainer()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containerType, gc.Equals, instance.LXC)
+}
+
 func (s *deployerSuite) TestStateAddresses(c *gc.C) {
 	err := s.machine.SetAddresses(network.NewAddress("0.1.2.3", network.ScopeUnknown))
 	c.Assert(err, jc.ErrorIsNil)
@@ -4,10 +4,13 @@
 package deployer
 
 import (
+	"fmt"
+
 	"github.com/juju/names"
 
 	"github.com/juju/juju/api/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 )
 
 // Unit represents a juju unit as seen by the deployer worker.
@@ -70,3 +73,28 @@ func (u *Unit) SetPassword(password string) error {
 	}
 	return result.OneError()
 }
+
+// WorkloadContainer returns the type of container in which the unit's
+// charm runs on its machine, or instance.NONE if the charm runs
+// directly on the machine. API servers that do not support workload
+// containers run all charms directly on the machines.
+func (u *Unit) WorkloadContainer() (instance.ContainerType, error) {
+	var results params.StringResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("WorkloadContainers", args, &results)
+	if params.IsCodeNotImplemented(err) {
+		return instance.NONE, nil
+	} else if err != nil {
+		return "", err
+	}
+	if len(results.Results) != 1 {
+		return "", fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return instance.ContainerType(result.Result), nil
+}
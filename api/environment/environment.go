@@ -6,6 +6,8 @@ package environment
 import (
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/common"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
 )
 
 const apiName = "Environment"
@@ -13,6 +15,7 @@ const apiName = "Environment"
 // Facade provides access to a machine environment worker's view of the world.
 type Facade struct {
 	*common.EnvironWatcher
+	facade base.FacadeCaller
 }
 
 // NewFacade returns a new api client facade instance.
@@ -20,5 +23,26 @@ func NewFacade(caller base.APICaller) *Facade {
 	facadeCaller := base.NewFacadeCaller(caller, apiName)
 	return &Facade{
 		EnvironWatcher: common.NewEnvironWatcher(facadeCaller),
+		facade:         facadeCaller,
 	}
 }
+
+// EnvironmentSuspended returns whether the environment is suspended,
+// and if so, why.
+func (f *Facade) EnvironmentSuspended() (bool, string, error) {
+	var result params.EnvironmentSuspendedResult
+	if err := f.facade.FacadeCall("EnvironmentSuspended", nil, &result); err != nil {
+		return false, "", err
+	}
+	return result.Suspended, result.Reason, nil
+}
+
+// WatchEnvironment returns a watcher that notifies of changes to the
+// environment, including its suspension.
+func (f *Facade) WatchEnvironment() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	if err := f.facade.FacadeCall("WatchEnvironment", nil, &result); err != nil {
+		return nil, err
+	}
+	return watcher.NewNotifyWatcher(f.facade.RawAPICaller(), result), nil
+}
@@ -4,15 +4,19 @@
 package environment_test
 
 import (
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/api/environment"
 	apitesting "github.com/juju/juju/api/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
+	statetesting "github.com/juju/juju/state/testing"
 )
 
 type environmentSuite struct {
 	jujutesting.JujuConnSuite
 	*apitesting.EnvironWatcherTests
+	facade *environment.Facade
 }
 
 var _ = gc.Suite(&environmentSuite{})
@@ -24,7 +28,38 @@ func (s *environmentSuite) SetUpTest(c *gc.C) {
 
 	environmentAPI := stateAPI.Environment()
 	c.Assert(environmentAPI, gc.NotNil)
+	s.facade = environmentAPI
 
 	s.EnvironWatcherTests = apitesting.NewEnvironWatcherTests(
 		environmentAPI, s.BackingState, apitesting.NoSecrets)
 }
+
+func (s *environmentSuite) TestEnvironmentSuspended(c *gc.C) {
+	suspended, reason, err := s.facade.EnvironmentSuspended()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(suspended, jc.IsFalse)
+	c.Assert(reason, gc.Equals, "")
+
+	env, err := s.BackingState.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason, err = s.facade.EnvironmentSuspended()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "provider outage")
+}
+
+func (s *environmentSuite) TestWatchEnvironment(c *gc.C) {
+	w, err := s.facade.WatchEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+	wc.AssertOneChange()
+
+	env, err := s.BackingState.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -68,6 +68,29 @@ func (c *Client) CreateEnvironment(owner string, account, config map[string]inte
 	return result, nil
 }
 
+// CreateEnvironmentOnCloud creates a new environment, as CreateEnvironment
+// does, hosted on the named cloud rather than the state server's and using
+// the owner's named credential for the cloud.
+func (c *Client) CreateEnvironmentOnCloud(owner, cloud, credential string, account, config map[string]interface{}) (params.Environment, error) {
+	var result params.Environment
+	if !names.IsValidUser(owner) {
+		return result, fmt.Errorf("invalid owner name %q", owner)
+	}
+	createArgs := params.EnvironmentCreateArgs{
+		OwnerTag:        names.NewUserTag(owner).String(),
+		Account:         account,
+		Config:          config,
+		Cloud:           cloud,
+		CloudCredential: credential,
+	}
+	err := c.facade.FacadeCall("CreateEnvironment", createArgs, &result)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	logger.Infof("created environment %s (%s) on cloud %s", result.Name, result.UUID, cloud)
+	return result, nil
+}
+
 // ListEnvironments returns the environments that the specified user
 // has access to in the current server.  Only that state server owner
 // can list environments for any user (at this stage).  Other users
@@ -84,3 +107,48 @@ func (c *Client) ListEnvironments(user string) ([]params.Environment, error) {
 	}
 	return result.Environments, nil
 }
+
+// EnvironmentDefaults returns the config defaults set on the state
+// server for new environments.
+func (c *Client) EnvironmentDefaults() (params.EnvironmentDefaults, error) {
+	var result params.EnvironmentDefaults
+	err := c.facade.FacadeCall("EnvironmentDefaults", nil, &result)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// SetEnvironmentDefaults sets config defaults for new environments in
+// the given region, or for all new environments if region is empty.
+func (c *Client) SetEnvironmentDefaults(region string, config map[string]interface{}) error {
+	var result params.ErrorResults
+	args := params.SetEnvironmentDefaults{
+		Config: []params.EnvironmentDefaultValues{{
+			Region: region,
+			Config: config,
+		}},
+	}
+	err := c.facade.FacadeCall("SetEnvironmentDefaults", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// UnsetEnvironmentDefaults removes config defaults for new environments
+// in the given region, or for all new environments if region is empty.
+func (c *Client) UnsetEnvironmentDefaults(region string, keys ...string) error {
+	var result params.ErrorResults
+	args := params.UnsetEnvironmentDefaults{
+		Keys: []params.EnvironmentDefaultKeys{{
+			Region: region,
+			Keys:   keys,
+		}},
+	}
+	err := c.facade.FacadeCall("UnsetEnvironmentDefaults", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
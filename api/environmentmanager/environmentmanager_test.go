@@ -115,3 +115,66 @@ func (s *environmentmanagerSuite) TestListEnvironments(c *gc.C) {
 	envNames := []string{envs[0].Name, envs[1].Name}
 	c.Assert(envNames, jc.SameContents, []string{"first", "second"})
 }
+
+func (s *environmentmanagerSuite) TestEnvironmentDefaults(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	envManager := s.OpenAPI(c)
+	err := envManager.SetEnvironmentDefaults("", map[string]interface{}{
+		"default-series": "precise",
+		"logging-config": "<root>=DEBUG",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = envManager.SetEnvironmentDefaults("dummy-region", map[string]interface{}{
+		"default-series": "trusty",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = envManager.UnsetEnvironmentDefaults("", "logging-config")
+	c.Assert(err, jc.ErrorIsNil)
+
+	defaults, err := envManager.EnvironmentDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults, jc.DeepEquals, params.EnvironmentDefaults{
+		Config: map[string]interface{}{
+			"default-series": "precise",
+		},
+		Regions: map[string]map[string]interface{}{
+			"dummy-region": {"default-series": "trusty"},
+		},
+	})
+}
+
+func (s *environmentmanagerSuite) TestSetEnvironmentDefaultsInvalid(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	envManager := s.OpenAPI(c)
+	err := envManager.SetEnvironmentDefaults("", map[string]interface{}{
+		"uuid": "anything",
+	})
+	c.Assert(err, gc.ErrorMatches, "uuid cannot be set as a default")
+}
+
+func (s *environmentmanagerSuite) TestCreateEnvironmentOnCloud(c *gc.C) {
+	s.SetFeatureFlags(feature.JES)
+	user := s.Factory.MakeUser(c, nil)
+	_, err := s.State.AddCloud("other", "dummy", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateCloudCredential(user.UserTag(), "other", "default", map[string]string{
+		"secret": "pork",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	envManager := s.OpenAPI(c)
+	owner := user.UserTag().Username()
+	newEnv, err := envManager.CreateEnvironmentOnCloud(owner, "other", "default", nil, map[string]interface{}{
+		"name":            "new-env",
+		"authorized-keys": "ssh-key",
+		// dummy needs state-server
+		"state-server": false,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newEnv.Name, gc.Equals, "new-env")
+
+	env, err := s.State.GetEnvironment(names.NewEnvironTag(newEnv.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Cloud(), gc.Equals, "other")
+	c.Assert(env.CloudCredential(), gc.Equals, "default")
+}
@@ -11,43 +11,70 @@ package api
 // New facades should start at 1.
 // Facades that existed before versioning start at 0.
 var facadeVersions = map[string]int{
-	"Action":               0,
-	"Agent":                1,
+	"Action":               1,
+	"ActionScheduler":      1,
+	"Agent":                2,
+	"AgentLiveness":        1,
 	"AllWatcher":           0,
-	"Annotations":          1,
+	"Annotations":          2,
 	"Backups":              0,
 	"Block":                1,
+	"Capabilities":         1,
+	"Certificates":         1,
 	"Charms":               1,
+	"CharmRevisions":       1,
 	"CharmRevisionUpdater": 0,
-	"Client":               0,
+	"CharmStorage":         1,
+	"Client":               1,
+	"Clouds":               1,
+	"ConditionWatcher":     1,
+	"ConfigSchema":         1,
+	"Connections":          1,
+	"CredentialValidator":  1,
 	"Deployer":             0,
 	"DiskFormatter":        1,
 	"DiskManager":          1,
 	"Environment":          0,
 	"EnvironmentManager":   1,
 	"Firewaller":           1,
-	"HighAvailability":     1,
+	"HighAvailability":     2,
+	"HostFirewaller":       1,
 	"ImageManager":         1,
+	"InstanceMetadata":     1,
 	"KeyManager":           0,
 	"KeyUpdater":           0,
 	"LeadershipService":    1,
+	"LogForwarding":        1,
 	"Logger":               0,
 	"Machiner":             0,
+	"MaintenanceWindow":    1,
+	"MeterStatus":          1,
 	"MetricsManager":       0,
+	"MongoManager":         1,
 	"Networker":            0,
 	"NotifyWatcher":        0,
+	"OSUpdates":            1,
 	"Pinger":               0,
 	"Provisioner":          0,
+	"ProxyUpdater":         1,
+	"RaftLease":            1,
 	"Reboot":               1,
+	"RebootRequests":       1,
 	"RelationUnitsWatcher": 0,
 	"Rsyslog":              0,
 	"Service":              1,
-	"Storage":              1,
+	"Stability":            1,
+	"StatusHistory":        1,
+	"Storage":              2,
 	"StorageProvisioner":   1,
 	"StringsWatcher":       0,
+	"Subnets":              1,
 	"Upgrader":             0,
-	"Uniter":               2,
+	"UpgradeSeries":        1,
+	"UnitDrain":            1,
+	"Uniter":               12,
 	"UserManager":          0,
+	"WaitFor":              1,
 }
 
 // bestVersion tries to find the newest version in the version list that we can
@@ -71,3 +71,28 @@ func (c *Client) EnsureAvailability(
 	}
 	return result.Result, nil
 }
+
+// StateServersHealth returns the health of the state server machines.
+func (c *Client) StateServersHealth() (params.StateServersHealthResult, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return params.StateServersHealthResult{}, errors.NotSupportedf("reporting state server health with this version of Juju")
+	}
+	var result params.StateServersHealthResult
+	if err := c.facade.FacadeCall("StateServersHealth", nil, &result); err != nil {
+		return params.StateServersHealthResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// SingularWorkers returns, for each environment, the state server
+// running its singular workers.
+func (c *Client) SingularWorkers() (params.SingularWorkersResult, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return params.SingularWorkersResult{}, errors.NotSupportedf("reporting singular workers with this version of Juju")
+	}
+	var result params.SingularWorkersResult
+	if err := c.facade.FacadeCall("SingularWorkers", nil, &result); err != nil {
+		return params.SingularWorkersResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
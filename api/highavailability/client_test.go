@@ -6,6 +6,7 @@ package highavailability_test
 import (
 	stdtesting "testing"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -78,7 +79,29 @@ func (s *clientSuite) TestClientEnsureAvailability(c *gc.C) {
 
 func (s *clientSuite) TestClientEnsureAvailabilityVersion(c *gc.C) {
 	client := highavailability.NewClient(s.APIState)
-	c.Assert(client.BestAPIVersion(), gc.Equals, 1)
+	c.Assert(client.BestAPIVersion(), gc.Equals, 2)
+}
+
+func (s *clientSuite) TestClientStateServersHealth(c *gc.C) {
+	_, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	pinger := setAgentPresence(c, &s.JujuConnSuite, "0")
+	defer assertKill(c, pinger)
+
+	client := highavailability.NewClient(s.APIState)
+	result, err := client.StateServersHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.StateServers, gc.HasLen, 1)
+	c.Assert(result.StateServers[0].MachineTag, gc.Equals, "machine-0")
+	c.Assert(result.StateServers[0].AgentAlive, jc.IsTrue)
+	c.Assert(result.Healthy, jc.IsTrue)
+}
+
+func (s *clientSuite) TestClientSingularWorkers(c *gc.C) {
+	client := highavailability.NewClient(s.APIState)
+	result, err := client.SingularWorkers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Environments, gc.HasLen, 0)
 }
 
 type clientLegacySuite struct {
@@ -89,6 +112,7 @@ var _ = gc.Suite(&clientLegacySuite{})
 
 func (s *clientLegacySuite) SetUpTest(c *gc.C) {
 	common.Facades.Discard("HighAvailability", 1)
+	common.Facades.Discard("HighAvailability", 2)
 	s.JujuConnSuite.SetUpTest(c)
 }
 
@@ -101,3 +125,15 @@ func (s *clientLegacySuite) TestEnsureAvailabilityLegacyRejectsPlacement(c *gc.C
 	_, err := client.EnsureAvailability(3, constraints.Value{}, "", []string{"machine"})
 	c.Assert(err, gc.ErrorMatches, "placement directives not supported with this version of Juju")
 }
+
+func (s *clientLegacySuite) TestStateServersHealthLegacy(c *gc.C) {
+	client := highavailability.NewClient(s.APIState)
+	_, err := client.StateServersHealth()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *clientLegacySuite) TestSingularWorkersLegacy(c *gc.C) {
+	client := highavailability.NewClient(s.APIState)
+	_, err := client.SingularWorkers()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
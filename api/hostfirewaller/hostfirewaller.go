@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostfirewaller
+
+import (
+	"fmt"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+)
+
+const hostFirewallerFacade = "HostFirewaller"
+
+// State provides access to a hostfirewaller worker's view of the state.
+type State struct {
+	facade base.FacadeCaller
+	tag    names.MachineTag
+}
+
+// NewState creates a new client-side HostFirewaller facade.
+func NewState(caller base.APICaller, authTag names.MachineTag) *State {
+	return &State{
+		base.NewFacadeCaller(caller, hostFirewallerFacade),
+		authTag,
+	}
+}
+
+// IngressRules returns the ingress rules for the ports opened on the
+// machine identified by the authenticated machine tag.
+func (st *State) IngressRules() ([]network.IngressRule, error) {
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: st.tag.String()}},
+	}
+	var results params.IngressRulesResults
+	err := st.facade.FacadeCall("IngressRules", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	rules := make([]network.IngressRule, len(result.Rules))
+	for i, rule := range result.Rules {
+		rules[i] = rule.NetworkIngressRule()
+	}
+	return rules, nil
+}
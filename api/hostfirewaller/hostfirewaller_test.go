@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostfirewaller_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/hostfirewaller"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type hostFirewallerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&hostFirewallerSuite{})
+
+func (s *hostFirewallerSuite) TestIngressRules(c *gc.C) {
+	var called bool
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "HostFirewaller")
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, "IngressRules")
+		c.Check(arg, gc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: "machine-123"}},
+		})
+		c.Assert(result, gc.FitsTypeOf, &params.IngressRulesResults{})
+		*(result.(*params.IngressRulesResults)) = params.IngressRulesResults{
+			Results: []params.IngressRulesResult{{
+				Rules: []params.IngressRule{{
+					PortRange:   params.PortRange{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+					SourceCIDRs: []string{"10.0.0.0/8"},
+				}},
+			}},
+		}
+		called = true
+		return nil
+	})
+
+	st := hostfirewaller.NewState(apiCaller, names.NewMachineTag("123"))
+	rules, err := st.IngressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(rules, jc.DeepEquals, []network.IngressRule{
+		network.MustNewIngressRule(network.MustParsePortRange("80/tcp"), "10.0.0.0/8"),
+	})
+}
+
+func (s *hostFirewallerSuite) TestIngressRulesError(c *gc.C) {
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		*(result.(*params.IngressRulesResults)) = params.IngressRulesResults{
+			Results: []params.IngressRulesResult{{
+				Error: &params.Error{Message: "permission denied", Code: params.CodeUnauthorized},
+			}},
+		}
+		return nil
+	})
+
+	st := hostfirewaller.NewState(apiCaller, names.NewMachineTag("123"))
+	_, err := st.IngressRules()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
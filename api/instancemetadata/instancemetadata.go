@@ -0,0 +1,102 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancemetadata
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+)
+
+// Client allows clients to consult the instance types and images
+// offered by the environment's provider, as cached by the state server.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the InstanceMetadata
+// API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "InstanceMetadata")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// InstanceMetadata returns the cached instance types and images.
+func (c *Client) InstanceMetadata() (params.InstanceMetadata, error) {
+	var result params.InstanceMetadata
+	if err := c.facade.FacadeCall("InstanceMetadata", nil, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// FindInstanceSpec returns the instance type and image id which would be
+// chosen for a machine with the given series and constraints. An empty
+// series means the environment's default series. The returned error
+// satisfies params.IsCodeNotFound if no instance types and images have
+// been cached.
+func (c *Client) FindInstanceSpec(series string, cons constraints.Value) (params.InstanceType, string, error) {
+	args := params.InstanceSpecArgs{
+		Specs: []params.InstanceSpecArg{{
+			Series:      series,
+			Constraints: cons,
+		}},
+	}
+	var results params.InstanceSpecResults
+	if err := c.facade.FacadeCall("FindInstanceSpecs", args, &results); err != nil {
+		return params.InstanceType{}, "", errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.InstanceType{}, "", errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.InstanceType{}, "", result.Error
+	}
+	return *result.InstanceType, result.ImageId, nil
+}
+
+// EstimateCosts returns the estimated hourly cost of deploying each of
+// the given services. The returned error satisfies
+// params.IsCodeNotFound if no instance types and images have been
+// cached.
+func (c *Client) EstimateCosts(services []params.ServiceCostArg) ([]params.ServiceCost, error) {
+	args := params.ServiceCostArgs{Services: services}
+	var results params.ServiceCostResults
+	if err := c.facade.FacadeCall("EstimateCosts", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != len(services) {
+		return nil, errors.Errorf("expected %d results, got %d", len(services), len(results.Results))
+	}
+	return results.Results, nil
+}
+
+// State provides access to the InstanceMetadata API facade for state
+// server agents, which refresh the cached instance types and images.
+type State struct {
+	*common.EnvironWatcher
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	facadeCaller := base.NewFacadeCaller(caller, "InstanceMetadata")
+	return &State{
+		EnvironWatcher: common.NewEnvironWatcher(facadeCaller),
+		facade:         facadeCaller,
+	}
+}
+
+// UpdateInstanceMetadata replaces the cached instance types and images.
+func (st *State) UpdateInstanceMetadata(metadata params.InstanceMetadata) error {
+	if err := st.facade.FacadeCall("UpdateInstanceMetadata", metadata, nil); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
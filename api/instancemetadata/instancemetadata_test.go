@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancemetadata_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/instancemetadata"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type instanceMetadataSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&instanceMetadataSuite{})
+
+func (s *instanceMetadataSuite) TestFindInstanceSpec(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "InstanceMetadata")
+			c.Check(request, gc.Equals, "FindInstanceSpecs")
+			c.Check(a, jc.DeepEquals, params.InstanceSpecArgs{
+				Specs: []params.InstanceSpecArg{{
+					Series:      "trusty",
+					Constraints: constraints.MustParse("mem=4G"),
+				}},
+			})
+			*response.(*params.InstanceSpecResults) = params.InstanceSpecResults{
+				Results: []params.InstanceSpecResult{{
+					InstanceType: &params.InstanceType{Name: "large", Cost: 40},
+					ImageId:      "ami-123",
+				}},
+			}
+			return nil
+		})
+	client := instancemetadata.NewClient(apiCaller)
+	itype, imageId, err := client.FindInstanceSpec("trusty", constraints.MustParse("mem=4G"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(itype, jc.DeepEquals, params.InstanceType{Name: "large", Cost: 40})
+	c.Assert(imageId, gc.Equals, "ami-123")
+}
+
+func (s *instanceMetadataSuite) TestFindInstanceSpecResultError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			*response.(*params.InstanceSpecResults) = params.InstanceSpecResults{
+				Results: []params.InstanceSpecResult{{
+					Error: &params.Error{Message: "no instance types"},
+				}},
+			}
+			return nil
+		})
+	client := instancemetadata.NewClient(apiCaller)
+	_, _, err := client.FindInstanceSpec("trusty", constraints.Value{})
+	c.Assert(err, gc.ErrorMatches, "no instance types")
+}
+
+func (s *instanceMetadataSuite) TestFindInstanceSpecNotCached(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return &params.Error{Message: "instance metadata not found", Code: params.CodeNotFound}
+		})
+	client := instancemetadata.NewClient(apiCaller)
+	_, _, err := client.FindInstanceSpec("", constraints.Value{})
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *instanceMetadataSuite) TestUpdateInstanceMetadata(c *gc.C) {
+	metadata := params.InstanceMetadata{
+		InstanceTypes: []params.InstanceType{{Name: "small", Arches: []string{"amd64"}}},
+		Images:        []params.CloudImage{{Id: "ami-123", Series: "trusty", Arch: "amd64"}},
+		Updated:       time.Now(),
+	}
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "InstanceMetadata")
+			c.Check(request, gc.Equals, "UpdateInstanceMetadata")
+			c.Check(a, jc.DeepEquals, metadata)
+			called = true
+			return nil
+		})
+	err := instancemetadata.NewState(apiCaller).UpdateInstanceMetadata(metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *instanceMetadataSuite) TestUpdateInstanceMetadataError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	err := instancemetadata.NewState(apiCaller).UpdateInstanceMetadata(params.InstanceMetadata{})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *instanceMetadataSuite) TestEstimateCosts(c *gc.C) {
+	services := []params.ServiceCostArg{{
+		ServiceName: "mysql",
+		Constraints: constraints.MustParse("mem=4G"),
+		NumUnits:    2,
+	}}
+	expected := []params.ServiceCost{{
+		ServiceName:  "mysql",
+		InstanceType: "large",
+		NumUnits:     2,
+		UnitCost:     0.4,
+		HourlyCost:   0.8,
+		Currency:     "USD",
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "InstanceMetadata")
+			c.Check(request, gc.Equals, "EstimateCosts")
+			c.Check(a, jc.DeepEquals, params.ServiceCostArgs{Services: services})
+			*response.(*params.ServiceCostResults) = params.ServiceCostResults{Results: expected}
+			return nil
+		})
+	client := instancemetadata.NewClient(apiCaller)
+	costs, err := client.EstimateCosts(services)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(costs, jc.DeepEquals, expected)
+}
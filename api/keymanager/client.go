@@ -4,6 +4,8 @@
 package keymanager
 
 import (
+	"time"
+
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/utils/ssh"
@@ -41,6 +43,15 @@ func (c *Client) AddKeys(user string, keys ...string) ([]params.ErrorResult, err
 	return results.Results, err
 }
 
+// AddExpiringKeys adds the authorised ssh keys for the specified user,
+// which stop being authorised at the specified time.
+func (c *Client) AddExpiringKeys(user string, expires time.Time, keys ...string) ([]params.ErrorResult, error) {
+	p := params.ModifyUserSSHKeys{User: user, Keys: keys, Expires: &expires}
+	results := new(params.ErrorResults)
+	err := c.facade.FacadeCall("AddKeys", p, results)
+	return results.Results, err
+}
+
 // DeleteKeys deletes the authorised ssh keys for the specified user.
 func (c *Client) DeleteKeys(user string, keys ...string) ([]params.ErrorResult, error) {
 	p := params.ModifyUserSSHKeys{User: user, Keys: keys}
@@ -56,3 +67,12 @@ func (c *Client) ImportKeys(user string, keyIds ...string) ([]params.ErrorResult
 	err := c.facade.FacadeCall("ImportKeys", p, results)
 	return results.Results, err
 }
+
+// ImportExpiringKeys imports the authorised ssh keys with the specified key ids for the specified user,
+// which stop being authorised at the specified time.
+func (c *Client) ImportExpiringKeys(user string, expires time.Time, keyIds ...string) ([]params.ErrorResult, error) {
+	p := params.ModifyUserSSHKeys{User: user, Keys: keyIds, Expires: &expires}
+	results := new(params.ErrorResults)
+	err := c.facade.FacadeCall("ImportKeys", p, results)
+	return results.Results, err
+}
@@ -5,6 +5,7 @@ package keymanager_test
 
 import (
 	"strings"
+	"time"
 
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -54,7 +55,6 @@ func (s *keymanagerSuite) TestListKeys(c *gc.C) {
 }
 
 func (s *keymanagerSuite) TestListKeysErrors(c *gc.C) {
-	c.Skip("the user name isn't checked for existence yet")
 	keyResults, err := s.keymanager.ListKeys(ssh.Fingerprints, "invalid")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(len(keyResults), gc.Equals, 1)
@@ -153,6 +153,43 @@ func (s *keymanagerSuite) TestImportKeys(c *gc.C) {
 	s.assertEnvironKeys(c, []string{key1, sshtesting.ValidKeyThree.Key})
 }
 
+func (s *keymanagerSuite) TestAddExpiringKeys(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorisedKeys(c, key1)
+
+	expires := time.Now().Add(time.Hour)
+	newKey := sshtesting.ValidKeyTwo.Key + " temp@host"
+	errResults, err := s.keymanager.AddExpiringKeys(s.AdminUserTag(c).Name(), expires, newKey)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errResults, gc.DeepEquals, []params.ErrorResult{
+		{Error: nil},
+	})
+	s.assertEnvironKeys(c, []string{key1})
+
+	keys, err := s.State.SSHKeys(s.AdminUserTag(c))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	c.Assert(keys[0].Key(), gc.Equals, newKey)
+	when, ok := keys[0].Expires()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(when.Unix(), gc.Equals, expires.Unix())
+}
+
+func (s *keymanagerSuite) TestImportExpiringKeys(c *gc.C) {
+	s.PatchValue(&keymanagerserver.RunSSHImportId, keymanagertesting.FakeImport)
+
+	expires := time.Now().Add(time.Hour)
+	errResults, err := s.keymanager.ImportExpiringKeys(s.AdminUserTag(c).Name(), expires, "lp:validuser")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errResults, gc.DeepEquals, []params.ErrorResult{
+		{Error: nil},
+	})
+	keys, err := s.State.SSHKeys(s.AdminUserTag(c))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 1)
+	c.Assert(keys[0].Key(), gc.Equals, sshtesting.ValidKeyThree.Key)
+}
+
 func (s *keymanagerSuite) assertInvalidUserOperation(c *gc.C, test func(user string, keys []string) error) {
 	key1 := sshtesting.ValidKeyOne.Key + " user@host"
 	s.setAuthorisedKeys(c, key1)
@@ -167,7 +204,6 @@ func (s *keymanagerSuite) assertInvalidUserOperation(c *gc.C, test func(user str
 }
 
 func (s *keymanagerSuite) TestAddKeysInvalidUser(c *gc.C) {
-	c.Skip("no user validation done yet")
 	s.assertInvalidUserOperation(c, func(user string, keys []string) error {
 		_, err := s.keymanager.AddKeys(user, keys...)
 		return err
@@ -175,7 +211,6 @@ func (s *keymanagerSuite) TestAddKeysInvalidUser(c *gc.C) {
 }
 
 func (s *keymanagerSuite) TestDeleteKeysInvalidUser(c *gc.C) {
-	c.Skip("no user validation done yet")
 	s.assertInvalidUserOperation(c, func(user string, keys []string) error {
 		_, err := s.keymanager.DeleteKeys(user, keys...)
 		return err
@@ -183,7 +218,6 @@ func (s *keymanagerSuite) TestDeleteKeysInvalidUser(c *gc.C) {
 }
 
 func (s *keymanagerSuite) TestImportKeysInvalidUser(c *gc.C) {
-	c.Skip("no user validation done yet")
 	s.assertInvalidUserOperation(c, func(user string, keys []string) error {
 		_, err := s.keymanager.ImportKeys(user, keys...)
 		return err
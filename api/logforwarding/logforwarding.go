@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarding
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the LogForwarding API facade, used to
+// configure the forwarding of stored logs to external targets and to
+// carry out that forwarding.
+type State struct {
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	return &State{
+		facade: base.NewFacadeCaller(caller, "LogForwarding"),
+	}
+}
+
+// Targets returns the log forwarding targets which apply to the
+// environment, along with the delivery status of its logs to each.
+func (st *State) Targets() ([]params.LogForwardTarget, error) {
+	var result params.LogForwardTargetsResult
+	if err := st.facade.FacadeCall("Targets", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Targets, nil
+}
+
+// AddTarget adds a new log forwarding target.
+func (st *State) AddTarget(config params.LogForwardTargetConfig) error {
+	args := params.LogForwardTargetConfigs{
+		Targets: []params.LogForwardTargetConfig{config},
+	}
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("AddTargets", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// RemoveTarget removes the named log forwarding target.
+func (st *State) RemoveTarget(name string) error {
+	args := params.LogForwardTargetNames{Names: []string{name}}
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("RemoveTargets", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// NextLogs returns at most limit of the environment's log records
+// that have yet to be delivered to the named target.
+func (st *State) NextLogs(target string, limit int) ([]params.LogForwardRecord, error) {
+	args := params.LogForwardLogsArgs{
+		Args: []params.LogForwardLogsArg{{Target: target, Limit: limit}},
+	}
+	var results params.LogForwardLogsResults
+	if err := st.facade.FacadeCall("NextLogs", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Records, nil
+}
+
+// SetDeliveryStatus records the outcome of an attempt to deliver
+// the environment's logs to a target.
+func (st *State) SetDeliveryStatus(delivery params.LogForwardDelivery) error {
+	args := params.LogForwardDeliveries{
+		Deliveries: []params.LogForwardDelivery{delivery},
+	}
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("SetDeliveryStatus", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarding_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/logforwarding"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type logForwardingSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&logForwardingSuite{})
+
+func (s *logForwardingSuite) TestTargets(c *gc.C) {
+	expected := []params.LogForwardTarget{{
+		Config: params.LogForwardTargetConfig{Name: "syslog", Type: "syslog", Address: "a:1"},
+		Status: params.LogForwardStatus{Delivered: 42},
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "LogForwarding")
+			c.Check(request, gc.Equals, "Targets")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.LogForwardTargetsResult)
+			result.Targets = expected
+			return nil
+		})
+	targets, err := logforwarding.NewState(apiCaller).Targets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(targets, jc.DeepEquals, expected)
+}
+
+func (s *logForwardingSuite) TestAddTarget(c *gc.C) {
+	config := params.LogForwardTargetConfig{Name: "loki", Type: "loki", Address: "https://loki"}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(request, gc.Equals, "AddTargets")
+			c.Check(a, jc.DeepEquals, params.LogForwardTargetConfigs{
+				Targets: []params.LogForwardTargetConfig{config},
+			})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{Error: &params.Error{Message: "boom"}}}
+			return nil
+		})
+	err := logforwarding.NewState(apiCaller).AddTarget(config)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *logForwardingSuite) TestRemoveTarget(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(request, gc.Equals, "RemoveTargets")
+			c.Check(a, jc.DeepEquals, params.LogForwardTargetNames{Names: []string{"loki"}})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	err := logforwarding.NewState(apiCaller).RemoveTarget("loki")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *logForwardingSuite) TestNextLogs(c *gc.C) {
+	records := []params.LogForwardRecord{{
+		Id:      "5571a1f0e4b0c3a2b1d0e9f8",
+		Time:    time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Entity:  "machine-0",
+		Level:   loggo.INFO,
+		Message: "hello",
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(request, gc.Equals, "NextLogs")
+			c.Check(a, jc.DeepEquals, params.LogForwardLogsArgs{
+				Args: []params.LogForwardLogsArg{{Target: "syslog", Limit: 100}},
+			})
+			result := response.(*params.LogForwardLogsResults)
+			result.Results = []params.LogForwardLogsResult{{Records: records}}
+			return nil
+		})
+	obtained, err := logforwarding.NewState(apiCaller).NextLogs("syslog", 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, jc.DeepEquals, records)
+}
+
+func (s *logForwardingSuite) TestNextLogsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.LogForwardLogsResults)
+			result.Results = []params.LogForwardLogsResult{{
+				Error: &params.Error{Message: "not found", Code: params.CodeNotFound},
+			}}
+			return nil
+		})
+	_, err := logforwarding.NewState(apiCaller).NextLogs("syslog", 100)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *logForwardingSuite) TestSetDeliveryStatus(c *gc.C) {
+	delivery := params.LogForwardDelivery{
+		Target:     "syslog",
+		LastSentId: "5571a1f0e4b0c3a2b1d0e9f8",
+		Delivered:  10,
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(request, gc.Equals, "SetDeliveryStatus")
+			c.Check(a, jc.DeepEquals, params.LogForwardDeliveries{
+				Deliveries: []params.LogForwardDelivery{delivery},
+			})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	err := logforwarding.NewState(apiCaller).SetDeliveryStatus(delivery)
+	c.Assert(err, jc.ErrorIsNil)
+}
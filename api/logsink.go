@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"crypto/tls"
+	"net/url"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// LogSinkWriter sends log records to the API server's logsink
+// endpoint, from where they are written to the logs database.
+type LogSinkWriter struct {
+	conn *websocket.Conn
+}
+
+// OpenLogSink opens a connection to the logsink endpoint of the API
+// server, through which an agent can stream its log records. It
+// returns an error that satisfies errors.IsNotSupported if the API
+// server does not provide the endpoint.
+func (s *State) OpenLogSink() (*LogSinkWriter, error) {
+	envTag, err := s.EnvironTag()
+	if err != nil {
+		return nil, errors.NotSupportedf("log sink")
+	}
+	target := url.URL{
+		Scheme: "wss",
+		Host:   s.addr,
+		Path:   "/environment/" + envTag.Id() + "/logsink",
+	}
+	cfg, err := websocket.NewConfig(target.String(), "http://localhost/")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg.Header = utils.BasicAuthHeader(s.tag, s.password)
+	if s.nonce != "" {
+		cfg.Header.Set("X-Juju-Nonce", s.nonce)
+	}
+	cfg.TlsConfig = &tls.Config{RootCAs: s.certPool, ServerName: "juju-apiserver"}
+	conn, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to log sink")
+	}
+	if err := readInitialStreamError(conn); err != nil {
+		conn.Close()
+		return nil, errors.Trace(err)
+	}
+	return &LogSinkWriter{conn: conn}, nil
+}
+
+// WriteLog sends a single log record to the API server.
+func (w *LogSinkWriter) WriteLog(rec *params.LogRecord) error {
+	return websocket.JSON.Send(w.conn, rec)
+}
+
+// Close closes the connection to the API server.
+func (w *LogSinkWriter) Close() error {
+	return w.conn.Close()
+}
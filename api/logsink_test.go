@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type logSinkSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&logSinkSuite{})
+
+func (s *logSinkSuite) TestWriteLog(c *gc.C) {
+	st, machine := s.OpenAPIAsNewMachine(c, state.JobHostUnits)
+	writer, err := st.OpenLogSink()
+	c.Assert(err, jc.ErrorIsNil)
+	defer writer.Close()
+
+	t0 := time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+	err = writer.WriteLog(&params.LogRecord{
+		Time:     t0,
+		Module:   "juju.worker",
+		Location: "worker.go:42",
+		Level:    loggo.WARNING,
+		Message:  "look out",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	logsColl := s.State.MongoSession().DB("logs").C("logs")
+	var docs []bson.M
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := logsColl.Find(nil).All(&docs)
+		c.Assert(err, jc.ErrorIsNil)
+		if len(docs) > 0 {
+			break
+		}
+	}
+	c.Assert(docs, gc.HasLen, 1)
+	c.Assert(docs[0]["t"].(time.Time).Equal(t0), jc.IsTrue)
+	c.Assert(docs[0]["n"], gc.Equals, machine.Tag().String())
+	c.Assert(docs[0]["m"], gc.Equals, "juju.worker")
+	c.Assert(docs[0]["l"], gc.Equals, "worker.go:42")
+	c.Assert(docs[0]["v"], gc.Equals, int(loggo.WARNING))
+	c.Assert(docs[0]["x"], gc.Equals, "look out")
+}
+
+func (s *logSinkSuite) TestUserRejected(c *gc.C) {
+	_, err := s.APIState.OpenLogSink()
+	c.Assert(err, gc.ErrorMatches, "auth failed: invalid entity name or password")
+}
@@ -4,11 +4,14 @@
 package machiner
 
 import (
+	"fmt"
+
 	"github.com/juju/names"
 
 	"github.com/juju/juju/api/common"
 	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 )
 
@@ -69,6 +72,58 @@ func (m *Machine) SetMachineAddresses(addresses []network.Address) error {
 	return result.OneError()
 }
 
+// HardwareRefreshRequested returns whether the machine agent has been
+// asked to re-detect the machine's hardware characteristics.
+func (m *Machine) HardwareRefreshRequested() (bool, error) {
+	var results params.BoolResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: m.tag.String()}},
+	}
+	err := m.st.facade.FacadeCall("HardwareRefreshRequested", args, &results)
+	if err != nil {
+		return false, err
+	}
+	if len(results.Results) != 1 {
+		return false, fmt.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.Result, nil
+}
+
+// SetHardwareCharacteristics records the hardware characteristics
+// detected on the machine, and clears any hardware refresh request.
+func (m *Machine) SetHardwareCharacteristics(hc instance.HardwareCharacteristics) error {
+	var result params.ErrorResults
+	args := params.SetMachinesHardwareCharacteristics{
+		MachineCharacteristics: []params.MachineHardwareCharacteristics{
+			{Tag: m.tag.String(), Characteristics: hc},
+		},
+	}
+	err := m.st.facade.FacadeCall("SetHardwareCharacteristics", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// SetSSHHostKeys records the machine's public ssh host keys.
+func (m *Machine) SetSSHHostKeys(keys []string) error {
+	var result params.ErrorResults
+	args := params.SetMachinesSSHHostKeys{
+		MachineHostKeys: []params.MachineSSHHostKeys{
+			{Tag: m.tag.String(), PublicKeys: keys},
+		},
+	}
+	err := m.st.facade.FacadeCall("SetSSHHostKeys", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // EnsureDead sets the machine lifecycle to Dead if it is Alive or
 // Dying. It does nothing otherwise.
 func (m *Machine) EnsureDead() error {
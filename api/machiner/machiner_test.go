@@ -15,11 +15,13 @@ import (
 	"github.com/juju/juju/api/machiner"
 	apitesting "github.com/juju/juju/api/testing"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 )
 
 func TestAll(t *stdtesting.T) {
@@ -154,6 +156,43 @@ func (s *machinerSuite) TestSetMachineAddresses(c *gc.C) {
 	c.Assert(s.machine.MachineAddresses(), jc.DeepEquals, expectAddresses)
 }
 
+func (s *machinerSuite) TestHardwareRefresh(c *gc.C) {
+	machine, err := s.machiner.Machine(names.NewMachineTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	requested, err := machine.HardwareRefreshRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsFalse)
+
+	err = s.machine.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+	requested, err = machine.HardwareRefreshRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsTrue)
+
+	hc := instance.MustParseHardware("arch=amd64 mem=16G cpu-cores=8 numa-nodes=2 gpus=1")
+	err = machine.SetHardwareCharacteristics(hc)
+	c.Assert(err, jc.ErrorIsNil)
+	requested, err = machine.HardwareRefreshRequested()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requested, jc.IsFalse)
+	stored, err := s.machine.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*stored, jc.DeepEquals, hc)
+}
+
+func (s *machinerSuite) TestSetSSHHostKeys(c *gc.C) {
+	machine, err := s.machiner.Machine(names.NewMachineTag("1"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	keys := []string{sshtesting.ValidKeyOne.Key + " root@host"}
+	err = machine.SetSSHHostKeys(keys)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.SSHHostKeys(), jc.DeepEquals, keys)
+}
+
 func (s *machinerSuite) TestWatch(c *gc.C) {
 	machine, err := s.machiner.Machine(names.NewMachineTag("1"))
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,49 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maintenancewindow
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the maintenance window API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the maintenance window
+// API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "MaintenanceWindow")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Status returns the current state of the environment's maintenance
+// window.
+func (c *Client) Status() (params.MaintenanceWindowStatus, error) {
+	var result params.MaintenanceWindowStatus
+	if err := c.facade.FacadeCall("Status", nil, &result); err != nil {
+		return params.MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// SetOverride holds the environment's maintenance window open or
+// closed until the given time, regardless of its schedule.
+func (c *Client) SetOverride(open bool, until time.Time) error {
+	args := params.MaintenanceWindowOverride{Open: open, Until: until}
+	return c.facade.FacadeCall("SetOverride", args, nil)
+}
+
+// ClearOverride removes any override of the environment's maintenance
+// window.
+func (c *Client) ClearOverride() error {
+	return c.facade.FacadeCall("ClearOverride", nil, nil)
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maintenancewindow_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/maintenancewindow"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type maintenanceWindowSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&maintenanceWindowSuite{})
+
+func (s *maintenanceWindowSuite) TestStatus(c *gc.C) {
+	until := time.Date(2015, 6, 2, 2, 0, 0, 0, time.UTC)
+	expected := params.MaintenanceWindowStatus{
+		Schedule: "0 2 * * *",
+		Duration: 2 * time.Hour,
+		Until:    &until,
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MaintenanceWindow")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Status")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.MaintenanceWindowStatus)
+			*result = expected
+			return nil
+		})
+	client := maintenancewindow.NewClient(apiCaller)
+	status, err := client.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, expected)
+}
+
+func (s *maintenanceWindowSuite) TestSetOverride(c *gc.C) {
+	until := time.Date(2015, 6, 2, 2, 0, 0, 0, time.UTC)
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "MaintenanceWindow")
+			c.Check(request, gc.Equals, "SetOverride")
+			c.Check(a, jc.DeepEquals, params.MaintenanceWindowOverride{
+				Open:  true,
+				Until: until,
+			})
+			return nil
+		})
+	client := maintenancewindow.NewClient(apiCaller)
+	err := client.SetOverride(true, until)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *maintenanceWindowSuite) TestClearOverride(c *gc.C) {
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "MaintenanceWindow")
+			c.Check(request, gc.Equals, "ClearOverride")
+			c.Check(a, gc.IsNil)
+			return nil
+		})
+	client := maintenancewindow.NewClient(apiCaller)
+	err := client.ClearOverride()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
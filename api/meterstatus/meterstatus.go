@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package meterstatus
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the MeterStatus API facade, used to follow
+// the meter statuses of the environment's units and to send alerts
+// when they turn RED.
+type State struct {
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	return &State{
+		facade: base.NewFacadeCaller(caller, "MeterStatus"),
+	}
+}
+
+// WatchEnvironMeterStatuses returns a NotifyWatcher which notifies
+// when the meter status of any unit in the environment changes, or a
+// unit is added or removed.
+func (st *State) WatchEnvironMeterStatuses() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	if err := st.facade.FacadeCall("WatchEnvironMeterStatuses", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// EnvironMeterStatuses returns the meter statuses of all the units in
+// the environment, ordered by unit name.
+func (st *State) EnvironMeterStatuses() ([]params.UnitMeterStatus, error) {
+	var result params.UnitMeterStatuses
+	if err := st.facade.FacadeCall("EnvironMeterStatuses", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Statuses, nil
+}
+
+// AlertConfig returns the settings used to send alerts when the meter
+// statuses of units turn RED.
+func (st *State) AlertConfig() (params.MeterStatusAlertConfig, error) {
+	var result params.MeterStatusAlertConfig
+	if err := st.facade.FacadeCall("AlertConfig", nil, &result); err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package meterstatus_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/meterstatus"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type meterStatusSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&meterStatusSuite{})
+
+func (s *meterStatusSuite) TestEnvironMeterStatuses(c *gc.C) {
+	expected := []params.UnitMeterStatus{{
+		UnitTag: "unit-mysql-0",
+		Code:    "RED",
+		Info:    "payment overdue",
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MeterStatus")
+			c.Check(request, gc.Equals, "EnvironMeterStatuses")
+			c.Check(a, gc.IsNil)
+			*(response.(*params.UnitMeterStatuses)) = params.UnitMeterStatuses{Statuses: expected}
+			return nil
+		})
+	statuses, err := meterstatus.NewState(apiCaller).EnvironMeterStatuses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses, jc.DeepEquals, expected)
+}
+
+func (s *meterStatusSuite) TestAlertConfig(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MeterStatus")
+			c.Check(request, gc.Equals, "AlertConfig")
+			*(response.(*params.MeterStatusAlertConfig)) = params.MeterStatusAlertConfig{
+				URL: "https://alerts.example.com/juju",
+			}
+			return nil
+		})
+	result, err := meterstatus.NewState(apiCaller).AlertConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.URL, gc.Equals, "https://alerts.example.com/juju")
+}
+
+func (s *meterStatusSuite) TestWatchEnvironMeterStatusesError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MeterStatus")
+			c.Check(request, gc.Equals, "WatchEnvironMeterStatuses")
+			result := response.(*params.NotifyWatchResult)
+			result.Error = &params.Error{Message: "permission denied", Code: params.CodeUnauthorized}
+			return nil
+		})
+	_, err := meterstatus.NewState(apiCaller).WatchEnvironMeterStatuses()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -68,3 +68,27 @@ func (c *Client) SendMetrics() error {
 	}
 	return results.OneError()
 }
+
+// MetricBatchDeliveries returns the delivery state of each of the
+// environment's metric batches which is still stored in state.
+func (c *Client) MetricBatchDeliveries() ([]params.MetricBatchDelivery, error) {
+	envTag, err := c.st.EnvironTag()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	p := params.Entities{Entities: []params.Entity{
+		{envTag.String()},
+	}}
+	results := new(params.MetricBatchDeliveryResults)
+	err = c.facade.FacadeCall("MetricBatchDeliveries", p, results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, err
+	}
+	return results.Results[0].Batches, nil
+}
@@ -69,3 +69,25 @@ func (s *metricsManagerSuite) TestSendMetricsFails(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "permission denied")
 	c.Assert(called, jc.IsTrue)
 }
+
+func (s *metricsManagerSuite) TestMetricBatchDeliveries(c *gc.C) {
+	expected := []params.MetricBatchDelivery{{
+		UUID:     "batch-uuid",
+		Unit:     "metered/0",
+		State:    "failed",
+		Attempts: 2,
+		Error:    "not acknowledged by the collector",
+	}}
+	metricsmanager.PatchFacadeCall(s, s.manager, func(request string, args, response interface{}) error {
+		c.Assert(request, gc.Equals, "MetricBatchDeliveries")
+		c.Assert(args, gc.DeepEquals, params.Entities{Entities: []params.Entity{
+			{s.State.EnvironTag().String()},
+		}})
+		result := response.(*params.MetricBatchDeliveryResults)
+		result.Results = []params.MetricBatchDeliveryResult{{Batches: expected}}
+		return nil
+	})
+	batches, err := s.manager.MetricBatchDeliveries()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batches, jc.DeepEquals, expected)
+}
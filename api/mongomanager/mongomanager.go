@@ -0,0 +1,40 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongomanager
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the MongoManager API facade, used to
+// inspect and tune the mongo database deployed on the state servers.
+type State struct {
+	facade base.FacadeCaller
+}
+
+// NewState returns a new State using the given API caller.
+func NewState(caller base.APICaller) *State {
+	return &State{
+		facade: base.NewFacadeCaller(caller, "MongoManager"),
+	}
+}
+
+// MongoConfig returns the current settings of the state servers'
+// mongo database.
+func (st *State) MongoConfig() (params.MongoConfig, error) {
+	var result params.MongoConfig
+	if err := st.facade.FacadeCall("MongoConfig", nil, &result); err != nil {
+		return params.MongoConfig{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// SetMongoConfig changes the settings of the state servers' mongo
+// database.
+func (st *State) SetMongoConfig(config params.MongoConfig) error {
+	return st.facade.FacadeCall("SetMongoConfig", config, nil)
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongomanager_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/mongomanager"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type mongoManagerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&mongoManagerSuite{})
+
+func (s *mongoManagerSuite) TestMongoConfig(c *gc.C) {
+	expected := params.MongoConfig{
+		StorageEngine: "wiredTiger",
+		OplogSizeMB:   1024,
+		CacheSizeGB:   2,
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MongoManager")
+			c.Check(request, gc.Equals, "MongoConfig")
+			c.Check(a, gc.IsNil)
+			*(response.(*params.MongoConfig)) = expected
+			return nil
+		})
+	config, err := mongomanager.NewState(apiCaller).MongoConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, jc.DeepEquals, expected)
+}
+
+func (s *mongoManagerSuite) TestSetMongoConfig(c *gc.C) {
+	config := params.MongoConfig{
+		StorageEngine: "wiredTiger",
+		CacheSizeGB:   4,
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "MongoManager")
+			c.Check(request, gc.Equals, "SetMongoConfig")
+			c.Check(a, jc.DeepEquals, config)
+			return errors.New("boom")
+		})
+	err := mongomanager.NewState(apiCaller).SetMongoConfig(config)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,56 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdates
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows clients to see the security updates pending on the
+// environment's machines, and to apply them in rolling patch runs.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the OSUpdates API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "OSUpdates")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// SecurityUpdates returns the security updates pending on each machine
+// whose agent has reported them.
+func (c *Client) SecurityUpdates() ([]params.MachineOSUpdates, error) {
+	var result params.MachineOSUpdatesResults
+	if err := c.facade.FacadeCall("SecurityUpdates", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Results, nil
+}
+
+// StartPatchRun starts a rolling run applying the pending security
+// updates to every machine that has any, with no more than
+// maxUnavailable machines applying updates or rebooting at once.
+func (c *Client) StartPatchRun(maxUnavailable int) (params.PatchRun, error) {
+	args := params.StartPatchRun{MaxUnavailable: maxUnavailable}
+	var result params.PatchRun
+	if err := c.facade.FacadeCall("StartPatchRun", args, &result); err != nil {
+		return params.PatchRun{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// PatchRuns returns the progress of every patch run in the
+// environment, most recent first.
+func (c *Client) PatchRuns() ([]params.PatchRun, error) {
+	var result params.PatchRunsResult
+	if err := c.facade.FacadeCall("PatchRuns", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Runs, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdates_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/osupdates"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type osUpdatesSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&osUpdatesSuite{})
+
+var machineArgs = params.Entities{Entities: []params.Entity{{Tag: "machine-1"}}}
+
+func (s *osUpdatesSuite) TestSetSecurityUpdates(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "OSUpdates")
+			c.Check(request, gc.Equals, "SetSecurityUpdates")
+			c.Check(a, jc.DeepEquals, params.SetSecurityUpdates{
+				Machines: []params.MachineSecurityUpdates{{
+					Tag:      "machine-1",
+					Packages: []string{"openssl"},
+				}},
+			})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	st := osupdates.NewState(apiCaller, names.NewMachineTag("1"))
+	err := st.SetSecurityUpdates([]string{"openssl"})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *osUpdatesSuite) TestPatchRequest(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "OSUpdates")
+			c.Check(request, gc.Equals, "PatchRequests")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.PatchRequestResults)
+			result.Results = []params.PatchRequestResult{{
+				Result: &params.PatchRequest{Run: 3, Status: "applying"},
+			}}
+			return nil
+		})
+	st := osupdates.NewState(apiCaller, names.NewMachineTag("1"))
+	request, err := st.PatchRequest()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(request, gc.Equals, params.PatchRequest{Run: 3, Status: "applying"})
+}
+
+func (s *osUpdatesSuite) TestPatchRequestNotPatching(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.PatchRequestResults)
+			result.Results = []params.PatchRequestResult{{
+				Error: &params.Error{Message: "not found", Code: params.CodeNotFound},
+			}}
+			return nil
+		})
+	st := osupdates.NewState(apiCaller, names.NewMachineTag("1"))
+	request, err := st.PatchRequest()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(request, gc.Equals, params.PatchRequest{})
+}
+
+func (s *osUpdatesSuite) TestSetPatchStatus(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "OSUpdates")
+			c.Check(request, gc.Equals, "SetPatchStatus")
+			c.Check(a, jc.DeepEquals, params.SetPatchStatus{
+				Machines: []params.MachinePatchStatus{{
+					Tag:    "machine-1",
+					Status: "failed",
+					Info:   "boom",
+				}},
+			})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{
+				Error: &params.Error{Message: "machine 1 is not being patched"},
+			}}
+			return nil
+		})
+	st := osupdates.NewState(apiCaller, names.NewMachineTag("1"))
+	err := st.SetPatchStatus("failed", "boom")
+	c.Assert(err, gc.ErrorMatches, "machine 1 is not being patched")
+}
+
+func (s *osUpdatesSuite) TestStartPatchRun(c *gc.C) {
+	expected := params.PatchRun{
+		Id:             1,
+		MaxUnavailable: 2,
+		Status:         "running",
+		Started:        time.Date(2015, 6, 2, 2, 0, 0, 0, time.UTC),
+		Machines: []params.MachinePatchStatus{{
+			Tag:    "machine-1",
+			Status: "pending",
+		}},
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "OSUpdates")
+			c.Check(request, gc.Equals, "StartPatchRun")
+			c.Check(a, jc.DeepEquals, params.StartPatchRun{MaxUnavailable: 2})
+			result := response.(*params.PatchRun)
+			*result = expected
+			return nil
+		})
+	client := osupdates.NewClient(apiCaller)
+	run, err := client.StartPatchRun(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(run, jc.DeepEquals, expected)
+}
+
+func (s *osUpdatesSuite) TestPatchRuns(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "OSUpdates")
+			c.Check(request, gc.Equals, "PatchRuns")
+			c.Check(a, gc.IsNil)
+			result := response.(*params.PatchRunsResult)
+			result.Runs = []params.PatchRun{{Id: 2}, {Id: 1}}
+			return nil
+		})
+	client := osupdates.NewClient(apiCaller)
+	runs, err := client.PatchRuns()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(runs, jc.DeepEquals, []params.PatchRun{{Id: 2}, {Id: 1}})
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdates
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the OSUpdates API facade for a machine
+// agent, used to report the security updates pending on its machine
+// and to apply them when asked.
+type State struct {
+	machineTag names.MachineTag
+	facade     base.FacadeCaller
+}
+
+// NewState returns a new State for the given machine.
+func NewState(caller base.APICaller, machineTag names.MachineTag) *State {
+	return &State{
+		facade:     base.NewFacadeCaller(caller, "OSUpdates"),
+		machineTag: machineTag,
+	}
+}
+
+func (st *State) args() params.Entities {
+	return params.Entities{
+		Entities: []params.Entity{{Tag: st.machineTag.String()}},
+	}
+}
+
+// SetSecurityUpdates records the names of the packages with pending
+// security updates on the machine.
+func (st *State) SetSecurityUpdates(packages []string) error {
+	args := params.SetSecurityUpdates{
+		Machines: []params.MachineSecurityUpdates{{
+			Tag:      st.machineTag.String(),
+			Packages: packages,
+		}},
+	}
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("SetSecurityUpdates", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// WatchPatchRequest returns a watcher that notifies when the machine's
+// agent is asked to apply updates.
+func (st *State) WatchPatchRequest() (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	if err := st.facade.FacadeCall("WatchPatchRequests", st.args(), &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// PatchRequest returns the patch run the machine's agent has been
+// asked to apply updates for, and the machine's progress in it. The
+// run is zero if the machine is not being patched.
+func (st *State) PatchRequest() (params.PatchRequest, error) {
+	var results params.PatchRequestResults
+	if err := st.facade.FacadeCall("PatchRequests", st.args(), &results); err != nil {
+		return params.PatchRequest{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.PatchRequest{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		if params.IsCodeNotFound(result.Error) {
+			return params.PatchRequest{}, nil
+		}
+		return params.PatchRequest{}, result.Error
+	}
+	return *result.Result, nil
+}
+
+// SetPatchStatus records the machine's progress in the patch run it
+// was asked to apply updates for.
+func (st *State) SetPatchStatus(status, info string) error {
+	args := params.SetPatchStatus{
+		Machines: []params.MachinePatchStatus{{
+			Tag:    st.machineTag.String(),
+			Status: status,
+			Info:   info,
+		}},
+	}
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("SetPatchStatus", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
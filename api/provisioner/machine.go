@@ -83,6 +83,22 @@ func (m *Machine) SetStatus(status params.Status, info string, data map[string]i
 	return result.OneError()
 }
 
+// SetProvisioningStatus records progress made in provisioning the
+// machine in its provisioning status history.
+func (m *Machine) SetProvisioningStatus(status params.Status, info string, data map[string]interface{}) error {
+	var result params.ErrorResults
+	args := params.SetStatus{
+		Entities: []params.EntityStatus{
+			{Tag: m.tag.String(), Status: status, Info: info, Data: data},
+		},
+	}
+	err := m.st.facade.FacadeCall("SetProvisioningStatus", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
 // Status returns the status of the machine.
 func (m *Machine) Status() (params.Status, string, error) {
 	var results params.StatusResults
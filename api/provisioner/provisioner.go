@@ -171,8 +171,98 @@ func (st *State) PrepareContainerInterfaceInfo(containerTag names.MachineTag) ([
 	if err := result.Results[0].Error; err != nil {
 		return nil, err
 	}
-	ifaceInfo := make([]network.InterfaceInfo, len(result.Results[0].Config))
-	for i, netInfo := range result.Results[0].Config {
+	return interfaceInfoFromNetworkConfig(result.Results[0].Config), nil
+}
+
+// PreparedContainer holds the network configuration prepared for a
+// container by PrepareContainers.
+type PreparedContainer struct {
+	// InterfaceInfo holds the information needed to configure the
+	// container's network interfaces, as returned by
+	// PrepareContainerInterfaceInfo.
+	InterfaceInfo []network.InterfaceInfo
+
+	// Error holds the error preparing the container's network
+	// configuration, if any.
+	Error error
+}
+
+// PrepareContainers returns the container config, and the network
+// configuration prepared for each of the given containers, as
+// ContainerConfig and PrepareContainerInterfaceInfo do. It makes a
+// single call to API servers that support it, and one call per
+// container otherwise.
+func (st *State) PrepareContainers(containerTags []names.MachineTag) (params.ContainerConfig, []PreparedContainer, error) {
+	var result params.PrepareContainersResult
+	args := params.Entities{
+		Entities: make([]params.Entity, len(containerTags)),
+	}
+	for i, tag := range containerTags {
+		args.Entities[i].Tag = tag.String()
+	}
+	err := st.facade.FacadeCall("PrepareContainers", args, &result)
+	if params.IsCodeNotImplemented(err) {
+		return st.prepareContainersOneByOne(containerTags)
+	} else if err != nil {
+		return params.ContainerConfig{}, nil, err
+	}
+	if len(result.Results) != len(containerTags) {
+		return params.ContainerConfig{}, nil, errors.Errorf("expected %d results, got %d", len(containerTags), len(result.Results))
+	}
+	prepared := make([]PreparedContainer, len(containerTags))
+	for i, r := range result.Results {
+		if r.Error != nil {
+			prepared[i].Error = r.Error
+			continue
+		}
+		prepared[i].InterfaceInfo = interfaceInfoFromNetworkConfig(r.Config)
+	}
+	return result.Config, prepared, nil
+}
+
+// prepareContainersOneByOne implements PrepareContainers for API
+// servers that do not support batching.
+func (st *State) prepareContainersOneByOne(containerTags []names.MachineTag) (params.ContainerConfig, []PreparedContainer, error) {
+	config, err := st.ContainerConfig()
+	if err != nil {
+		return params.ContainerConfig{}, nil, err
+	}
+	prepared := make([]PreparedContainer, len(containerTags))
+	for i, tag := range containerTags {
+		prepared[i].InterfaceInfo, prepared[i].Error = st.PrepareContainerInterfaceInfo(tag)
+	}
+	return config, prepared, nil
+}
+
+// AllocateContainerPoolAddress allocates an address to the given
+// container from the address pool of the given bridge on its host
+// machine, recording the host name the container is known by at the
+// address. It returns an error satisfying params.IsCodeNotFound if the
+// bridge has no address pool.
+func (st *State) AllocateContainerPoolAddress(containerTag names.MachineTag, bridge, hostname string) ([]network.InterfaceInfo, error) {
+	var result params.MachineNetworkConfigResults
+	args := params.AllocateContainerPoolAddressArgs{
+		Args: []params.AllocateContainerPoolAddressArg{{
+			Tag:      containerTag.String(),
+			Bridge:   bridge,
+			Hostname: hostname,
+		}},
+	}
+	if err := st.facade.FacadeCall("AllocateContainerPoolAddresses", args, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(result.Results))
+	}
+	if err := result.Results[0].Error; err != nil {
+		return nil, err
+	}
+	return interfaceInfoFromNetworkConfig(result.Results[0].Config), nil
+}
+
+func interfaceInfoFromNetworkConfig(config []params.NetworkConfig) []network.InterfaceInfo {
+	ifaceInfo := make([]network.InterfaceInfo, len(config))
+	for i, netInfo := range config {
 		ifaceInfo[i] = network.InterfaceInfo{
 			DeviceIndex:      netInfo.DeviceIndex,
 			MACAddress:       netInfo.MACAddress,
@@ -191,5 +281,5 @@ func (st *State) PrepareContainerInterfaceInfo(containerTag names.MachineTag) ([
 			ExtraConfig:      netInfo.ExtraConfig,
 		}
 	}
-	return ifaceInfo, nil
+	return ifaceInfo
 }
@@ -129,6 +129,22 @@ func (s *provisionerSuite) TestGetSetStatusWithData(c *gc.C) {
 	c.Assert(data, gc.DeepEquals, map[string]interface{}{"foo": "bar"})
 }
 
+func (s *provisionerSuite) TestSetProvisioningStatus(c *gc.C) {
+	apiMachine, err := s.provisioner.Machine(s.machine.Tag().(names.MachineTag))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = apiMachine.SetProvisioningStatus(params.StatusInstanceRequested, "blah", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = apiMachine.SetProvisioningStatus(params.StatusPending, "", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set invalid provisioning status "pending"`)
+
+	history, err := s.machine.ProvisioningStatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].Status, gc.Equals, state.StatusInstanceRequested)
+	c.Assert(history[0].Info, gc.Equals, "blah")
+}
+
 func (s *provisionerSuite) TestMachinesWithTransientErrors(c *gc.C) {
 	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
 	c.Assert(err, jc.ErrorIsNil)
@@ -811,3 +827,84 @@ func (s *provisionerSuite) TestPrepareContainerInterfaceInfo(c *gc.C) {
 	expectInfo[0].Address = ifaceInfo[0].Address
 	c.Assert(ifaceInfo, jc.DeepEquals, expectInfo)
 }
+
+func (s *provisionerSuite) TestPrepareContainers(c *gc.C) {
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, s.machine.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	config, prepared, err := s.provisioner.PrepareContainers([]names.MachineTag{
+		container.MachineTag(), s.machine.MachineTag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config.ProviderType, gc.Equals, "dummy")
+	c.Assert(prepared, gc.HasLen, 2)
+	c.Assert(prepared[0].Error, jc.ErrorIsNil)
+	c.Assert(prepared[0].InterfaceInfo, gc.HasLen, 1)
+	c.Assert(prepared[0].InterfaceInfo[0].CIDR, gc.Equals, "0.10.0.0/24")
+	c.Assert(prepared[0].InterfaceInfo[0].ConfigType, gc.Equals, network.ConfigStatic)
+	c.Assert(prepared[1].Error, gc.ErrorMatches, `cannot allocate address for "machine-0": not a container`)
+}
+
+func (s *provisionerSuite) TestPrepareContainersNotImplemented(c *gc.C) {
+	var calls []string
+	provisioner.PatchFacadeCall(s, s.provisioner, func(request string, args, response interface{}) error {
+		calls = append(calls, request)
+		switch request {
+		case "PrepareContainers":
+			return &params.Error{Code: params.CodeNotImplemented}
+		case "ContainerConfig":
+			response.(*params.ContainerConfig).ProviderType = "fake"
+		case "PrepareContainerInterfaceInfo":
+			c.Assert(args, jc.DeepEquals, params.Entities{
+				Entities: []params.Entity{{Tag: "machine-0-lxc-0"}},
+			})
+			response.(*params.MachineNetworkConfigResults).Results = []params.MachineNetworkConfigResult{{
+				Config: []params.NetworkConfig{{CIDR: "0.10.0.0/24"}},
+			}}
+		default:
+			c.Fatalf("unexpected call to %s", request)
+		}
+		return nil
+	})
+
+	config, prepared, err := s.provisioner.PrepareContainers([]names.MachineTag{
+		names.NewMachineTag("0/lxc/0"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(calls, jc.DeepEquals, []string{"PrepareContainers", "ContainerConfig", "PrepareContainerInterfaceInfo"})
+	c.Assert(config.ProviderType, gc.Equals, "fake")
+	c.Assert(prepared, gc.HasLen, 1)
+	c.Assert(prepared[0].Error, jc.ErrorIsNil)
+	c.Assert(prepared[0].InterfaceInfo, gc.HasLen, 1)
+	c.Assert(prepared[0].InterfaceInfo[0].CIDR, gc.Equals, "0.10.0.0/24")
+}
+
+func (s *provisionerSuite) TestAllocateContainerPoolAddress(c *gc.C) {
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, s.machine.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.provisioner.AllocateContainerPoolAddress(container.MachineTag(), "lxcbr0", "juju-machine-0-lxc-0")
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+
+	_, err = s.State.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+		MachineId: s.machine.Id(),
+		Bridge:    "lxcbr0",
+		CIDR:      "10.0.3.0/24",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	ifaceInfo, err := s.provisioner.AllocateContainerPoolAddress(container.MachineTag(), "lxcbr0", "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ifaceInfo, gc.HasLen, 1)
+	c.Assert(ifaceInfo[0].CIDR, gc.Equals, "10.0.3.0/24")
+	c.Assert(ifaceInfo[0].ConfigType, gc.Equals, network.ConfigStatic)
+	c.Assert(ifaceInfo[0].Address, gc.Equals, network.NewAddress("10.0.3.2", network.ScopeUnknown))
+	c.Assert(ifaceInfo[0].GatewayAddress, gc.Equals, network.NewAddress("10.0.3.1", network.ScopeUnknown))
+}
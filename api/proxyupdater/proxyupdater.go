@@ -0,0 +1,73 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package proxyupdater
+
+import (
+	"github.com/juju/utils/proxy"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+const proxyUpdaterFacade = "ProxyUpdater"
+
+// ProxyConfig holds the proxy settings agents apply to their machines.
+type ProxyConfig struct {
+	// Proxy holds the proxies for general use, such as by hooks.
+	Proxy proxy.Settings
+
+	// AptProxy holds the proxies used by apt.
+	AptProxy proxy.Settings
+
+	// SnapProxy holds the proxies used by snapd.
+	SnapProxy proxy.Settings
+}
+
+// Facade provides access to the ProxyUpdater API facade.
+type Facade struct {
+	facade base.FacadeCaller
+}
+
+// NewFacade creates a new client-side ProxyUpdater facade.
+func NewFacade(caller base.APICaller) *Facade {
+	return &Facade{base.NewFacadeCaller(caller, proxyUpdaterFacade)}
+}
+
+// WatchForProxyConfigChanges returns a NotifyWatcher that notifies of
+// changes that may affect the proxy settings.
+func (f *Facade) WatchForProxyConfigChanges() (watcher.NotifyWatcher, error) {
+	var result params.NotifyWatchResult
+	err := f.facade.FacadeCall("WatchForProxyConfigChanges", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(f.facade.RawAPICaller(), result), nil
+}
+
+// ProxyConfig returns the current proxy settings of the environment.
+func (f *Facade) ProxyConfig() (ProxyConfig, error) {
+	var result params.ProxyConfigResult
+	err := f.facade.FacadeCall("ProxyConfig", nil, &result)
+	if err != nil {
+		return ProxyConfig{}, err
+	}
+	return ProxyConfig{
+		Proxy:     fromParams(result.ProxySettings),
+		AptProxy:  fromParams(result.AptProxySettings),
+		SnapProxy: fromParams(result.SnapProxySettings),
+	}, nil
+}
+
+func fromParams(settings params.ProxyConfig) proxy.Settings {
+	return proxy.Settings{
+		Http:    settings.Http,
+		Https:   settings.Https,
+		Ftp:     settings.Ftp,
+		NoProxy: settings.NoProxy,
+	}
+}
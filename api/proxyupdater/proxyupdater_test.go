@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package proxyupdater_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/proxyupdater"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type proxyUpdaterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&proxyUpdaterSuite{})
+
+func (s *proxyUpdaterSuite) TestProxyConfig(c *gc.C) {
+	var called bool
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(objType, gc.Equals, "ProxyUpdater")
+		c.Check(id, gc.Equals, "")
+		c.Check(request, gc.Equals, "ProxyConfig")
+		c.Check(arg, gc.IsNil)
+		c.Assert(result, gc.FitsTypeOf, &params.ProxyConfigResult{})
+		*(result.(*params.ProxyConfigResult)) = params.ProxyConfigResult{
+			ProxySettings: params.ProxyConfig{
+				Http:    "http://http.proxy",
+				NoProxy: "localhost",
+			},
+			AptProxySettings:  params.ProxyConfig{Http: "http://apt.proxy"},
+			SnapProxySettings: params.ProxyConfig{Https: "https://snap.proxy"},
+		}
+		called = true
+		return nil
+	})
+
+	config, err := proxyupdater.NewFacade(apiCaller).ProxyConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(config, jc.DeepEquals, proxyupdater.ProxyConfig{
+		Proxy:     proxy.Settings{Http: "http://http.proxy", NoProxy: "localhost"},
+		AptProxy:  proxy.Settings{Http: "http://apt.proxy"},
+		SnapProxy: proxy.Settings{Https: "https://snap.proxy"},
+	})
+}
+
+func (s *proxyUpdaterSuite) TestWatchForProxyConfigChangesError(c *gc.C) {
+	apiCaller := testing.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		c.Check(request, gc.Equals, "WatchForProxyConfigChanges")
+		*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+			Error: &params.Error{Message: "permission denied", Code: params.CodeUnauthorized},
+		}
+		return nil
+	})
+
+	_, err := proxyupdater.NewFacade(apiCaller).WatchForProxyConfigChanges()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package raftlease provides the client side of the API used by the
+// state servers to run the raft cluster which holds their leases.
+package raftlease
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/raft"
+)
+
+// Client provides access to another state server's RaftLease API
+// facade.
+type Client struct {
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new Client using the given API caller.
+func NewClient(caller base.APICaller) *Client {
+	return &Client{
+		facade: base.NewFacadeCaller(caller, "RaftLease"),
+	}
+}
+
+// RequestVote asks the state server's raft node for its vote.
+func (c *Client) RequestVote(args raft.RequestVoteArgs) (raft.RequestVoteResult, error) {
+	var result raft.RequestVoteResult
+	if err := c.facade.FacadeCall("RequestVote", args, &result); err != nil {
+		return raft.RequestVoteResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// AppendEntries sends log entries to the state server's raft node.
+func (c *Client) AppendEntries(args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error) {
+	var result raft.AppendEntriesResult
+	if err := c.facade.FacadeCall("AppendEntries", args, &result); err != nil {
+		return raft.AppendEntriesResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// InstallSnapshot sends a snapshot to the state server's raft node.
+func (c *Client) InstallSnapshot(args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error) {
+	var result raft.InstallSnapshotResult
+	if err := c.facade.FacadeCall("InstallSnapshot", args, &result); err != nil {
+		return raft.InstallSnapshotResult{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// Propose asks the state server, which must be the raft leader, to
+// commit the given command, and returns the result of applying it.
+func (c *Client) Propose(command []byte) ([]byte, error) {
+	var result params.RaftProposeResult
+	args := params.RaftProposeArgs{Command: command}
+	if err := c.facade.FacadeCall("Propose", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Result, nil
+}
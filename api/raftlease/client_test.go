@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/raftlease"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/raft"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type clientSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&clientSuite{})
+
+func (s *clientSuite) TestRequestVote(c *gc.C) {
+	args := raft.RequestVoteArgs{Term: 2, CandidateId: "1"}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RaftLease")
+			c.Check(request, gc.Equals, "RequestVote")
+			c.Check(a, jc.DeepEquals, args)
+			*(response.(*raft.RequestVoteResult)) = raft.RequestVoteResult{Term: 2, Granted: true}
+			return nil
+		})
+	result, err := raftlease.NewClient(apiCaller).RequestVote(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.RequestVoteResult{Term: 2, Granted: true})
+}
+
+func (s *clientSuite) TestAppendEntries(c *gc.C) {
+	args := raft.AppendEntriesArgs{
+		Term:     2,
+		LeaderId: "1",
+		Entries:  []raft.Entry{{Index: 1, Term: 2}},
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RaftLease")
+			c.Check(request, gc.Equals, "AppendEntries")
+			c.Check(a, jc.DeepEquals, args)
+			*(response.(*raft.AppendEntriesResult)) = raft.AppendEntriesResult{Term: 2, Success: true, LastIndex: 1}
+			return nil
+		})
+	result, err := raftlease.NewClient(apiCaller).AppendEntries(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.AppendEntriesResult{Term: 2, Success: true, LastIndex: 1})
+}
+
+func (s *clientSuite) TestInstallSnapshot(c *gc.C) {
+	args := raft.InstallSnapshotArgs{Term: 2, LeaderId: "1", LastIndex: 5, Data: []byte("{}")}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RaftLease")
+			c.Check(request, gc.Equals, "InstallSnapshot")
+			c.Check(a, jc.DeepEquals, args)
+			*(response.(*raft.InstallSnapshotResult)) = raft.InstallSnapshotResult{Term: 2}
+			return nil
+		})
+	result, err := raftlease.NewClient(apiCaller).InstallSnapshot(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.InstallSnapshotResult{Term: 2})
+}
+
+func (s *clientSuite) TestPropose(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RaftLease")
+			c.Check(request, gc.Equals, "Propose")
+			c.Check(a, jc.DeepEquals, params.RaftProposeArgs{Command: []byte("cmd")})
+			*(response.(*params.RaftProposeResult)) = params.RaftProposeResult{Result: []byte("ok")}
+			return nil
+		})
+	result, err := raftlease.NewClient(apiCaller).Propose([]byte("cmd"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(result), gc.Equals, "ok")
+}
+
+func (s *clientSuite) TestProposeError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("not the raft leader")
+		})
+	_, err := raftlease.NewClient(apiCaller).Propose([]byte("cmd"))
+	c.Assert(err, gc.ErrorMatches, "not the raft leader")
+}
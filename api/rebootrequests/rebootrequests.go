@@ -0,0 +1,71 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package rebootrequests
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the RebootRequests API facade, used to
+// coordinate a machine's reboot with the containers it hosts.
+type State struct {
+	machineTag names.MachineTag
+	facade     base.FacadeCaller
+}
+
+// NewState returns a new State for the given machine.
+func NewState(caller base.APICaller, machineTag names.MachineTag) *State {
+	return &State{
+		facade:     base.NewFacadeCaller(caller, "RebootRequests"),
+		machineTag: machineTag,
+	}
+}
+
+func (st *State) args() params.Entities {
+	return params.Entities{
+		Entities: []params.Entity{{Tag: st.machineTag.String()}},
+	}
+}
+
+// AcknowledgeReboot records that the calling machine, which must be a
+// container, has stopped running hooks and is ready for its host to
+// reboot.
+func (st *State) AcknowledgeReboot() error {
+	var results params.ErrorResults
+	err := st.facade.FacadeCall("AcknowledgeReboot", st.args(), &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// PendingAcknowledgements returns the tags of the calling machine's
+// containers that have not yet acknowledged its reboot request.
+func (st *State) PendingAcknowledgements() ([]names.MachineTag, error) {
+	var results params.StringsResults
+	err := st.facade.FacadeCall("PendingAcknowledgements", st.args(), &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	tags := make([]names.MachineTag, len(result.Result))
+	for i, tag := range result.Result {
+		machineTag, err := names.ParseMachineTag(tag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		tags[i] = machineTag
+	}
+	return tags, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package rebootrequests_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/rebootrequests"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type rebootRequestsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&rebootRequestsSuite{})
+
+var machineArgs = params.Entities{Entities: []params.Entity{{Tag: "machine-0-lxc-1"}}}
+
+func (s *rebootRequestsSuite) TestAcknowledgeReboot(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RebootRequests")
+			c.Check(request, gc.Equals, "AcknowledgeReboot")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	st := rebootrequests.NewState(apiCaller, names.NewMachineTag("0/lxc/1"))
+	err := st.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *rebootRequestsSuite) TestAcknowledgeRebootError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{
+				Error: &params.Error{Message: "boom", Code: params.CodeNotFound},
+			}}
+			return nil
+		})
+	st := rebootrequests.NewState(apiCaller, names.NewMachineTag("0/lxc/1"))
+	err := st.AcknowledgeReboot()
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *rebootRequestsSuite) TestPendingAcknowledgements(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "RebootRequests")
+			c.Check(request, gc.Equals, "PendingAcknowledgements")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.StringsResults)
+			result.Results = []params.StringsResult{{
+				Result: []string{"machine-0-lxc-1-lxc-0"},
+			}}
+			return nil
+		})
+	st := rebootrequests.NewState(apiCaller, names.NewMachineTag("0/lxc/1"))
+	pending, err := st.PendingAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, []names.MachineTag{names.NewMachineTag("0/lxc/1/lxc/0")})
+}
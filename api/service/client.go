@@ -8,7 +8,10 @@
 package service
 
 import (
+	"time"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/base"
@@ -41,3 +44,106 @@ func (c *Client) SetMetricCredentials(service string, credentials []byte) error
 	}
 	return errors.Trace(results.OneError())
 }
+
+// ConfigValidationError is returned by SetConfig when settings break
+// the validation rules declared by the service's charm. Errors holds
+// the reason each invalid setting was rejected, keyed on option name.
+type ConfigValidationError struct {
+	Errors map[string]string
+	err    error
+}
+
+func (e *ConfigValidationError) Error() string {
+	return e.err.Error()
+}
+
+// IsConfigValidationError returns whether err is a ConfigValidationError.
+func IsConfigValidationError(err error) bool {
+	_, ok := errors.Cause(err).(*ConfigValidationError)
+	return ok
+}
+
+// SetConfig sets config options on the service. The values are parsed
+// according to the types of the charm's config options. If any value
+// breaks the charm's validation rules, a *ConfigValidationError is
+// returned and no options are set.
+func (c *Client) SetConfig(service string, options map[string]string) error {
+	p := params.ServiceConfigs{
+		Configs: []params.ServiceConfig{{
+			ServiceName: service,
+			Options:     options,
+		}},
+	}
+	var results params.ServiceConfigResults
+	err := c.facade.FacadeCall("SetConfigs", p, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if len(result.InvalidSettings) > 0 {
+		return &ConfigValidationError{
+			Errors: result.InvalidSettings,
+			err:    result.Error,
+		}
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+// PinLeadership pins the leadership of the service to its current
+// leader for the given duration, or until it is unpinned. If duration
+// is zero, the server's default duration is used.
+func (c *Client) PinLeadership(service string, duration time.Duration) error {
+	p := params.PinLeadershipBulkParams{
+		Params: []params.PinLeadershipParams{{
+			ServiceTag:      names.NewServiceTag(service).String(),
+			DurationSeconds: duration.Seconds(),
+		}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("PinLeadership", p, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// UnpinLeadership removes this connection's pin on the leadership
+// of the service.
+func (c *Client) UnpinLeadership(service string) error {
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewServiceTag(service).String()}},
+	}
+	var results params.ErrorResults
+	err := c.facade.FacadeCall("UnpinLeadership", p, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// Leadership returns the leader of the service, and the pins on
+// its leadership.
+func (c *Client) Leadership(service string) (params.ServiceLeadershipResult, error) {
+	p := params.Entities{
+		Entities: []params.Entity{{Tag: names.NewServiceTag(service).String()}},
+	}
+	var results params.ServiceLeadershipResults
+	err := c.facade.FacadeCall("Leadership", p, &results)
+	if err != nil {
+		return params.ServiceLeadershipResult{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.ServiceLeadershipResult{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.ServiceLeadershipResult{}, result.Error
+	}
+	return result, nil
+}
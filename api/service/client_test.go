@@ -4,8 +4,11 @@
 package service_test
 
 import (
+	"time"
+
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
 
 	"github.com/juju/juju/api/service"
 	"github.com/juju/juju/apiserver/common"
@@ -70,3 +73,107 @@ func (s *serviceSuite) TestSetServiceMetricCredentialsNoMocks(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(service.MetricCredentials(), gc.DeepEquals, []byte("creds"))
 }
+
+func (s *serviceSuite) TestSetConfig(c *gc.C) {
+	var called bool
+	service.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "SetConfigs")
+		c.Assert(a, jc.DeepEquals, params.ServiceConfigs{
+			Configs: []params.ServiceConfig{{
+				ServiceName: "serviceA",
+				Options:     map[string]string{"port": "443"},
+			}},
+		})
+		result := response.(*params.ServiceConfigResults)
+		result.Results = make([]params.ServiceConfigResult, 1)
+		return nil
+	})
+	err := s.client.SetConfig("serviceA", map[string]string{"port": "443"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *serviceSuite) TestSetConfigInvalid(c *gc.C) {
+	s.AddTestingService(c, "config-rules", s.AddTestingCharm(c, "config-rules"))
+	err := s.client.SetConfig("config-rules", map[string]string{
+		"port":  "0",
+		"title": "ignored",
+	})
+	c.Assert(err, jc.Satisfies, service.IsConfigValidationError)
+	c.Assert(err.(*service.ConfigValidationError).Errors, gc.DeepEquals, map[string]string{
+		"port": "must be at least 1",
+	})
+	c.Assert(err, gc.ErrorMatches, "invalid config settings: port: must be at least 1")
+}
+
+func (s *serviceSuite) TestSetConfigNoMocks(c *gc.C) {
+	svc := s.AddTestingService(c, "config-rules", s.AddTestingCharm(c, "config-rules"))
+	err := s.client.SetConfig("config-rules", map[string]string{"port": "443"})
+	c.Assert(err, jc.ErrorIsNil)
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, charm.Settings{"port": int64(443)})
+}
+
+func (s *serviceSuite) TestPinLeadership(c *gc.C) {
+	var called bool
+	service.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "PinLeadership")
+		c.Assert(a, jc.DeepEquals, params.PinLeadershipBulkParams{
+			Params: []params.PinLeadershipParams{{
+				ServiceTag:      "service-mysql",
+				DurationSeconds: 600,
+			}},
+		})
+		result := response.(*params.ErrorResults)
+		result.Results = make([]params.ErrorResult, 1)
+		return nil
+	})
+	err := s.client.PinLeadership("mysql", 10*time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *serviceSuite) TestUnpinLeadership(c *gc.C) {
+	var called bool
+	service.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		called = true
+		c.Assert(request, gc.Equals, "UnpinLeadership")
+		c.Assert(a, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: "service-mysql"}},
+		})
+		result := response.(*params.ErrorResults)
+		result.Results = []params.ErrorResult{{
+			Error: common.ServerError(common.ErrPerm),
+		}}
+		return nil
+	})
+	err := s.client.UnpinLeadership("mysql")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *serviceSuite) TestLeadership(c *gc.C) {
+	expiry := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	expect := params.ServiceLeadershipResult{
+		Leader: "unit-mysql-0",
+		Pins: []params.LeadershipPin{{
+			Entity: "user-admin",
+			Expiry: expiry,
+		}},
+	}
+	service.PatchFacadeCall(s, s.client, func(request string, a, response interface{}) error {
+		c.Assert(request, gc.Equals, "Leadership")
+		c.Assert(a, jc.DeepEquals, params.Entities{
+			Entities: []params.Entity{{Tag: "service-mysql"}},
+		})
+		result := response.(*params.ServiceLeadershipResults)
+		result.Results = []params.ServiceLeadershipResult{expect}
+		return nil
+	})
+	result, err := s.client.Leadership("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expect)
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/url"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// ConnectSSHProxy opens a connection, relayed through the API server's
+// sshproxy endpoint, to the given port at the given address of one of
+// the environment's machines. Data written to the returned connection
+// is sent to the machine unchanged, and data sent by the machine can be
+// read from it. It returns an error that satisfies errors.IsNotSupported
+// if the API server does not provide the endpoint.
+func (s *State) ConnectSSHProxy(host string, port int) (io.ReadWriteCloser, error) {
+	envTag, err := s.EnvironTag()
+	if err != nil {
+		return nil, errors.NotSupportedf("ssh proxy")
+	}
+	attrs := url.Values{}
+	attrs.Set("host", host)
+	if port != 0 {
+		attrs.Set("port", fmt.Sprint(port))
+	}
+	target := url.URL{
+		Scheme:   "wss",
+		Host:     s.addr,
+		Path:     "/environment/" + envTag.Id() + "/sshproxy",
+		RawQuery: attrs.Encode(),
+	}
+	cfg, err := websocket.NewConfig(target.String(), "http://localhost/")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg.Header = utils.BasicAuthHeader(s.tag, s.password)
+	cfg.TlsConfig = &tls.Config{RootCAs: s.certPool, ServerName: "juju-apiserver"}
+	conn, err := websocket.DialConfig(cfg)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to ssh proxy")
+	}
+	if err := readInitialStreamError(conn); err != nil {
+		conn.Close()
+		return nil, errors.Trace(err)
+	}
+	conn.PayloadType = websocket.BinaryFrame
+	return conn, nil
+}
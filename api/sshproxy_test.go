@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api_test
+
+import (
+	"io"
+	"net"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing/factory"
+)
+
+type sshProxySuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&sshProxySuite{})
+
+func (s *sshProxySuite) TestConnectSSHProxy(c *gc.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+	}()
+	s.Factory.MakeMachine(c, &factory.MachineParams{
+		Addresses: []network.Address{network.NewAddress("127.0.0.1", network.ScopeCloudLocal)},
+	})
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	conn, err := s.APIState.ConnectSSHProxy("127.0.0.1", port)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	buf := make([]byte, len("SSH-2.0-OpenSSH\r\n"))
+	_, err = io.ReadFull(conn, buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(buf), gc.Equals, "SSH-2.0-OpenSSH\r\n")
+}
+
+func (s *sshProxySuite) TestConnectSSHProxyUnknownHost(c *gc.C) {
+	_, err := s.APIState.ConnectSSHProxy("10.9.8.7", 22)
+	c.Assert(err, gc.ErrorMatches, `"10.9.8.7" is not an address of any machine in the environment`)
+}
@@ -0,0 +1,47 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package stability
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the stability API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the stability API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Stability")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// WaitForStability waits until the environment is stable, or until
+// the given timeout has elapsed, and returns whether it is stable and,
+// if not, why. As the API server limits how long it waits for a
+// single call, the call is repeated until the timeout has elapsed.
+func (c *Client) WaitForStability(timeout time.Duration) (params.StabilityResult, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		args := params.WaitForStabilityArgs{Timeout: timeout}
+		var result params.StabilityResult
+		if err := c.facade.FacadeCall("WaitForStability", args, &result); err != nil {
+			return params.StabilityResult{}, errors.Trace(err)
+		}
+		if result.Stable {
+			return result, nil
+		}
+		timeout = deadline.Sub(time.Now())
+		if timeout <= 0 {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package stability_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/stability"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type stabilitySuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&stabilitySuite{})
+
+func (s *stabilitySuite) TestWaitForStability(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Stability")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "WaitForStability")
+			c.Check(a, jc.DeepEquals, params.WaitForStabilityArgs{Timeout: time.Minute})
+			result := response.(*params.StabilityResult)
+			result.Stable = true
+			return nil
+		})
+	client := stability.NewClient(apiCaller)
+	result, err := client.WaitForStability(time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{Stable: true})
+}
+
+func (s *stabilitySuite) TestWaitForStabilityRepeats(c *gc.C) {
+	var timeouts []time.Duration
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			timeouts = append(timeouts, a.(params.WaitForStabilityArgs).Timeout)
+			result := response.(*params.StabilityResult)
+			result.Stable = len(timeouts) == 2
+			result.Reasons = []string{"machine 0 is not provisioned"}
+			return nil
+		})
+	client := stability.NewClient(apiCaller)
+	result, err := client.WaitForStability(time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Stable, jc.IsTrue)
+	c.Assert(timeouts, gc.HasLen, 2)
+	c.Assert(timeouts[0], gc.Equals, time.Hour)
+	c.Assert(timeouts[1] < time.Hour, jc.IsTrue)
+}
+
+func (s *stabilitySuite) TestWaitForStabilityTimeout(c *gc.C) {
+	calls := 0
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			calls++
+			result := response.(*params.StabilityResult)
+			result.Reasons = []string{"machine 0 is not provisioned"}
+			return nil
+		})
+	client := stability.NewClient(apiCaller)
+	result, err := client.WaitForStability(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{
+		Reasons: []string{"machine 0 is not provisioned"},
+	})
+	c.Assert(calls, gc.Equals, 1)
+}
@@ -10,25 +10,38 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/names"
 
+	"github.com/juju/juju/api/actionscheduler"
 	"github.com/juju/juju/api/agent"
 	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/charmrevisions"
 	"github.com/juju/juju/api/charmrevisionupdater"
+	"github.com/juju/juju/api/credentialvalidator"
 	"github.com/juju/juju/api/deployer"
 	"github.com/juju/juju/api/diskformatter"
 	"github.com/juju/juju/api/diskmanager"
 	"github.com/juju/juju/api/environment"
 	"github.com/juju/juju/api/firewaller"
+	"github.com/juju/juju/api/hostfirewaller"
+	"github.com/juju/juju/api/instancemetadata"
 	"github.com/juju/juju/api/keyupdater"
 	apileadership "github.com/juju/juju/api/leadership"
+	"github.com/juju/juju/api/logforwarding"
 	apilogger "github.com/juju/juju/api/logger"
 	"github.com/juju/juju/api/machiner"
+	"github.com/juju/juju/api/meterstatus"
+	"github.com/juju/juju/api/mongomanager"
 	"github.com/juju/juju/api/networker"
+	"github.com/juju/juju/api/osupdates"
 	"github.com/juju/juju/api/provisioner"
+	"github.com/juju/juju/api/proxyupdater"
+	"github.com/juju/juju/api/raftlease"
 	"github.com/juju/juju/api/reboot"
+	"github.com/juju/juju/api/rebootrequests"
 	"github.com/juju/juju/api/rsyslog"
 	"github.com/juju/juju/api/storageprovisioner"
 	"github.com/juju/juju/api/uniter"
 	"github.com/juju/juju/api/upgrader"
+	"github.com/juju/juju/api/upgradeseries"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/leadership"
 	"github.com/juju/juju/network"
@@ -252,6 +265,16 @@ func (st *State) DiskFormatter() (*diskformatter.State, error) {
 	return diskformatter.NewState(st, machineTag), nil
 }
 
+// HostFirewaller returns a version of the state that provides
+// functionality required by the hostfirewaller worker.
+func (st *State) HostFirewaller() (*hostfirewaller.State, error) {
+	machineTag, ok := st.authTag.(names.MachineTag)
+	if !ok {
+		return nil, errors.Errorf("expected MachineTag, got %#v", st.authTag)
+	}
+	return hostfirewaller.NewState(st, machineTag), nil
+}
+
 // StorageProvisioner returns a version of the state that provides
 // functionality required by the storageprovisioner worker.
 // The scope tag defines the type of storage that is provisioned, either
@@ -289,6 +312,61 @@ func (st *State) Reboot() (*reboot.State, error) {
 	}
 }
 
+// RebootRequests returns access to the RebootRequests API
+func (st *State) RebootRequests() (*rebootrequests.State, error) {
+	switch tag := st.authTag.(type) {
+	case names.MachineTag:
+		return rebootrequests.NewState(st, tag), nil
+	default:
+		return nil, errors.Errorf("expected names.MachineTag, got %T", tag)
+	}
+}
+
+// OSUpdates returns access to the OSUpdates API
+func (st *State) OSUpdates() (*osupdates.State, error) {
+	switch tag := st.authTag.(type) {
+	case names.MachineTag:
+		return osupdates.NewState(st, tag), nil
+	default:
+		return nil, errors.Errorf("expected names.MachineTag, got %T", tag)
+	}
+}
+
+// UpgradeSeries returns access to the UpgradeSeries API
+func (st *State) UpgradeSeries() (*upgradeseries.State, error) {
+	switch tag := st.authTag.(type) {
+	case names.MachineTag:
+		return upgradeseries.NewState(st, tag), nil
+	default:
+		return nil, errors.Errorf("expected names.MachineTag, got %T", tag)
+	}
+}
+
+// ActionScheduler returns access to the ActionScheduler API
+func (st *State) ActionScheduler() *actionscheduler.State {
+	return actionscheduler.NewState(st)
+}
+
+// CharmRevisions returns access to the CharmRevisions API
+func (st *State) CharmRevisions() *charmrevisions.State {
+	return charmrevisions.NewState(st)
+}
+
+// MeterStatus returns access to the MeterStatus API
+func (st *State) MeterStatus() *meterstatus.State {
+	return meterstatus.NewState(st)
+}
+
+// CredentialValidator returns access to the CredentialValidator API
+func (st *State) CredentialValidator() *credentialvalidator.State {
+	return credentialvalidator.NewState(st)
+}
+
+// InstanceMetadata returns access to the InstanceMetadata API
+func (st *State) InstanceMetadata() *instancemetadata.State {
+	return instancemetadata.NewState(st)
+}
+
 // Deployer returns access to the Deployer API
 func (st *State) Deployer() *deployer.State {
 	return deployer.NewState(st)
@@ -299,11 +377,31 @@ func (st *State) Environment() *environment.Facade {
 	return environment.NewFacade(st)
 }
 
+// ProxyUpdater returns access to the ProxyUpdater API
+func (st *State) ProxyUpdater() *proxyupdater.Facade {
+	return proxyupdater.NewFacade(st)
+}
+
 // Logger returns access to the Logger API
 func (st *State) Logger() *apilogger.State {
 	return apilogger.NewState(st)
 }
 
+// LogForwarding returns access to the LogForwarding API
+func (st *State) LogForwarding() *logforwarding.State {
+	return logforwarding.NewState(st)
+}
+
+// MongoManager returns access to the MongoManager API
+func (st *State) MongoManager() *mongomanager.State {
+	return mongomanager.NewState(st)
+}
+
+// RaftLease returns access to the RaftLease API
+func (st *State) RaftLease() *raftlease.Client {
+	return raftlease.NewClient(st)
+}
+
 // KeyUpdater returns access to the KeyUpdater API
 func (st *State) KeyUpdater() *keyupdater.State {
 	return keyupdater.NewState(st)
@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package statushistory
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the status history API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the status history API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "StatusHistory")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// StatusHistory returns the status history of the unit or machine
+// described by arg, most recent first.
+func (c *Client) StatusHistory(arg params.StatusHistoryArg) ([]params.HistoricalStatus, error) {
+	args := params.StatusHistoryArgs{Args: []params.StatusHistoryArg{arg}}
+	var results params.StatusHistoryResults
+	if err := c.facade.FacadeCall("StatusHistory", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.History, nil
+}
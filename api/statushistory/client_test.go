@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package statushistory_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/statushistory"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type statusHistorySuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&statusHistorySuite{})
+
+func (s *statusHistorySuite) TestStatusHistory(c *gc.C) {
+	expected := []params.HistoricalStatus{{
+		Status: params.StatusRunning,
+		Info:   "ready",
+		Since:  time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	arg := params.StatusHistoryArg{
+		Tag:     "unit-mysql-0",
+		Kind:    params.KindWorkload,
+		Size:    10,
+		Changes: true,
+	}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "StatusHistory")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "StatusHistory")
+			c.Check(a, jc.DeepEquals, params.StatusHistoryArgs{
+				Args: []params.StatusHistoryArg{arg},
+			})
+			result := response.(*params.StatusHistoryResults)
+			result.Results = []params.StatusHistoryResult{{History: expected}}
+			return nil
+		})
+	client := statushistory.NewClient(apiCaller)
+	history, err := client.StatusHistory(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, jc.DeepEquals, expected)
+}
+
+func (s *statusHistorySuite) TestStatusHistoryError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.StatusHistoryResults)
+			result.Results = []params.StatusHistoryResult{{
+				Error: &params.Error{Message: "boom"},
+			}}
+			return nil
+		})
+	client := statushistory.NewClient(apiCaller)
+	_, err := client.StatusHistory(params.StatusHistoryArg{Tag: "machine-0"})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
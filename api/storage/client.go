@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/storage"
 )
 
 var logger = loggo.GetLogger("juju.api.storage")
@@ -48,3 +49,113 @@ func (c *Client) Show(tags []names.StorageTag) ([]params.StorageInstance, error)
 	}
 	return all, allErr.Combine()
 }
+
+// ListVolumes returns all of the volumes in the environment. The
+// volumes are fetched from the server a page at a time.
+func (c *Client) ListVolumes() ([]params.VolumeDetails, error) {
+	var all []params.VolumeDetails
+	var args params.StoragePageArgs
+	for {
+		var result params.ListVolumesResult
+		if err := c.facade.FacadeCall("ListVolumes", args, &result); err != nil {
+			return nil, errors.Trace(err)
+		}
+		all = append(all, result.Volumes...)
+		if result.Next == "" {
+			return all, nil
+		}
+		args.After = result.Next
+	}
+}
+
+// ListFilesystems returns all of the filesystems in the environment.
+// The filesystems are fetched from the server a page at a time.
+func (c *Client) ListFilesystems() ([]params.FilesystemDetails, error) {
+	var all []params.FilesystemDetails
+	var args params.StoragePageArgs
+	for {
+		var result params.ListFilesystemsResult
+		if err := c.facade.FacadeCall("ListFilesystems", args, &result); err != nil {
+			return nil, errors.Trace(err)
+		}
+		all = append(all, result.Filesystems...)
+		if result.Next == "" {
+			return all, nil
+		}
+		args.After = result.Next
+	}
+}
+
+// FilterVolumes returns the volumes in the environment that match
+// the filters in args, starting after args.After. The volumes are
+// fetched from the server a page at a time.
+func (c *Client) FilterVolumes(args params.StorageListArgs) ([]params.VolumeDetails, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return nil, errors.NotImplementedf("FilterVolumes() (need V2+)")
+	}
+	var all []params.VolumeDetails
+	for {
+		var result params.ListVolumesResult
+		if err := c.facade.FacadeCall("ListVolumes", args, &result); err != nil {
+			return nil, errors.Trace(err)
+		}
+		all = append(all, result.Volumes...)
+		if result.Next == "" {
+			return all, nil
+		}
+		args.After = result.Next
+	}
+}
+
+// FilterFilesystems returns the filesystems in the environment that
+// match the filters in args, starting after args.After. The
+// filesystems are fetched from the server a page at a time.
+func (c *Client) FilterFilesystems(args params.StorageListArgs) ([]params.FilesystemDetails, error) {
+	if c.facade.BestAPIVersion() < 2 {
+		return nil, errors.NotImplementedf("FilterFilesystems() (need V2+)")
+	}
+	var all []params.FilesystemDetails
+	for {
+		var result params.ListFilesystemsResult
+		if err := c.facade.FacadeCall("ListFilesystems", args, &result); err != nil {
+			return nil, errors.Trace(err)
+		}
+		all = append(all, result.Filesystems...)
+		if result.Next == "" {
+			return all, nil
+		}
+		args.After = result.Next
+	}
+}
+
+// AddStorage adds instances of the named charm storage, as described
+// by the constraints, to the unit.
+func (c *Client) AddStorage(unit names.UnitTag, storageName string, cons storage.Constraints) error {
+	args := params.StoragesAddParams{
+		Storages: []params.StorageAddParams{{
+			UnitTag:     unit.String(),
+			StorageName: storageName,
+			Constraints: cons,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("AddStorage", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// DetachStorage detaches the storage instance from the unit.
+func (c *Client) DetachStorage(storageTag names.StorageTag, unit names.UnitTag) error {
+	args := params.StorageAttachmentIds{
+		Ids: []params.StorageAttachmentId{{
+			StorageTag: storageTag.String(),
+			UnitTag:    unit.String(),
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("DetachStorage", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
@@ -12,6 +12,7 @@ import (
 	basetesting "github.com/juju/juju/api/base/testing"
 	"github.com/juju/juju/api/storage"
 	"github.com/juju/juju/apiserver/params"
+	jujustorage "github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
 )
 
@@ -67,3 +68,167 @@ func (s *storageMockSuite) TestShow(c *gc.C) {
 	c.Assert(expected.Contains(found[1].StorageTag), jc.IsTrue)
 	c.Assert(called, jc.IsTrue)
 }
+
+func (s *storageMockSuite) TestListVolumesPages(c *gc.C) {
+	var afters []string
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(request, gc.Equals, "ListVolumes")
+			args, ok := a.(params.StoragePageArgs)
+			c.Assert(ok, jc.IsTrue)
+			afters = append(afters, args.After)
+
+			results := result.(*params.ListVolumesResult)
+			switch args.After {
+			case "":
+				results.Volumes = []params.VolumeDetails{{VolumeTag: "volume-0"}, {VolumeTag: "volume-1"}}
+				results.Next = "1"
+			case "1":
+				results.Volumes = []params.VolumeDetails{{VolumeTag: "volume-2"}}
+			}
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	found, err := storageClient.ListVolumes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.DeepEquals, []params.VolumeDetails{
+		{VolumeTag: "volume-0"}, {VolumeTag: "volume-1"}, {VolumeTag: "volume-2"},
+	})
+	c.Assert(afters, jc.DeepEquals, []string{"", "1"})
+}
+
+func (s *storageMockSuite) TestListFilesystems(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(request, gc.Equals, "ListFilesystems")
+			c.Check(a, jc.DeepEquals, params.StoragePageArgs{})
+			results := result.(*params.ListFilesystemsResult)
+			results.Filesystems = []params.FilesystemDetails{{FilesystemTag: "filesystem-0"}}
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	found, err := storageClient.ListFilesystems()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.DeepEquals, []params.FilesystemDetails{{FilesystemTag: "filesystem-0"}})
+}
+
+func (s *storageMockSuite) TestFilterVolumes(c *gc.C) {
+	provisioned := true
+	var afters []string
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(version, gc.Equals, 2)
+			c.Check(request, gc.Equals, "ListVolumes")
+			args, ok := a.(params.StorageListArgs)
+			c.Assert(ok, jc.IsTrue)
+			c.Check(args.Machines, jc.DeepEquals, []string{"0"})
+			c.Check(args.Provisioned, gc.Equals, &provisioned)
+			afters = append(afters, args.After)
+
+			results := result.(*params.ListVolumesResult)
+			switch args.After {
+			case "":
+				results.Volumes = []params.VolumeDetails{{VolumeTag: "volume-0"}}
+				results.Next = "0"
+			case "0":
+				results.Volumes = []params.VolumeDetails{{VolumeTag: "volume-3"}}
+			}
+			return nil
+		},
+		BestVersion: 2,
+	}
+	storageClient := storage.NewClient(apiCaller)
+	found, err := storageClient.FilterVolumes(params.StorageListArgs{
+		Machines:    []string{"0"},
+		Provisioned: &provisioned,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.DeepEquals, []params.VolumeDetails{
+		{VolumeTag: "volume-0"}, {VolumeTag: "volume-3"},
+	})
+	c.Assert(afters, jc.DeepEquals, []string{"", "0"})
+}
+
+func (s *storageMockSuite) TestFilterNotImplemented(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Fatalf("unexpected call to %s", request)
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	_, err := storageClient.FilterVolumes(params.StorageListArgs{})
+	c.Assert(err, gc.ErrorMatches, `FilterVolumes\(\) \(need V2\+\) not implemented`)
+	_, err = storageClient.FilterFilesystems(params.StorageListArgs{})
+	c.Assert(err, gc.ErrorMatches, `FilterFilesystems\(\) \(need V2\+\) not implemented`)
+}
+
+func (s *storageMockSuite) TestAddStorage(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(request, gc.Equals, "AddStorage")
+			c.Check(a, jc.DeepEquals, params.StoragesAddParams{
+				Storages: []params.StorageAddParams{{
+					UnitTag:     "unit-mysql-0",
+					StorageName: "data",
+					Constraints: jujustorage.Constraints{Pool: "ebs", Count: 2},
+				}},
+			})
+			results := result.(*params.ErrorResults)
+			results.Results = []params.ErrorResult{{Error: &params.Error{Message: "boom"}}}
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	err := storageClient.AddStorage(names.NewUnitTag("mysql/0"), "data", jujustorage.Constraints{Pool: "ebs", Count: 2})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *storageMockSuite) TestDetachStorage(c *gc.C) {
+	var called bool
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Storage")
+			c.Check(request, gc.Equals, "DetachStorage")
+			c.Check(a, jc.DeepEquals, params.StorageAttachmentIds{
+				Ids: []params.StorageAttachmentId{{
+					StorageTag: "storage-data-0",
+					UnitTag:    "unit-mysql-0",
+				}},
+			})
+			results := result.(*params.ErrorResults)
+			results.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	storageClient := storage.NewClient(apiCaller)
+	err := storageClient.DetachStorage(names.NewStorageTag("data/0"), names.NewUnitTag("mysql/0"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
@@ -0,0 +1,35 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the subnets API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the subnets API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Subnets")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// ListSubnets returns the subnets known to the environment's provider.
+// If zone is not empty, only the subnets that can be used in that
+// availability zone are returned.
+func (c *Client) ListSubnets(zone string) ([]params.Subnet, error) {
+	args := params.SubnetsFilters{Zone: zone}
+	var result params.ListSubnetsResults
+	if err := c.facade.FacadeCall("ListSubnets", args, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Results, nil
+}
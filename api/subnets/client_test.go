@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets_test
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/subnets"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type subnetsSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&subnetsSuite{})
+
+func (s *subnetsSuite) TestListSubnets(c *gc.C) {
+	expected := []params.Subnet{{
+		CIDR:       "10.0.1.0/24",
+		ProviderId: "sub-1",
+		Zones:      []string{"zone-a"},
+	}}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "Subnets")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "ListSubnets")
+			c.Check(a, jc.DeepEquals, params.SubnetsFilters{Zone: "zone-a"})
+			*response.(*params.ListSubnetsResults) = params.ListSubnetsResults{
+				Results: expected,
+			}
+			return nil
+		})
+	client := subnets.NewClient(apiCaller)
+	result, err := client.ListSubnets("zone-a")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expected)
+}
+
+func (s *subnetsSuite) TestListSubnetsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := subnets.NewClient(apiCaller)
+	_, err := client.ListSubnets("")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
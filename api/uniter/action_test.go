@@ -4,6 +4,7 @@
 package uniter_test
 
 import (
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -122,3 +123,41 @@ func (s *actionSuite) TestActionFail(c *gc.C) {
 	c.Assert(res, gc.DeepEquals, map[string]interface{}{})
 	c.Assert(completed[0].Name(), gc.Equals, "fakeaction")
 }
+
+func (s *actionSuite) TestAppendActionOutput(c *gc.C) {
+	action, err := s.uniterSuite.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.uniter.ActionBegin(action.ActionTag())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.uniter.AppendActionOutput(action.ActionTag(), params.ActionStdout, "hello\n")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.uniter.AppendActionOutput(action.ActionTag(), params.ActionStderr, "oops\n")
+	c.Assert(err, jc.ErrorIsNil)
+
+	chunks, err := action.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 2)
+	c.Assert(chunks[0].Stream, gc.Equals, state.ActionStdout)
+	c.Assert(chunks[0].Data, gc.Equals, "hello\n")
+	c.Assert(chunks[1].Stream, gc.Equals, state.ActionStderr)
+	c.Assert(chunks[1].Data, gc.Equals, "oops\n")
+}
+
+func (s *actionSuite) TestAppendActionOutputNotRunning(c *gc.C) {
+	action, err := s.uniterSuite.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.uniter.AppendActionOutput(action.ActionTag(), params.ActionStdout, "hello\n")
+	c.Assert(err, gc.ErrorMatches, `action ".*" is not running`)
+}
+
+func (s *actionSuite) TestAppendActionOutputV2NotImplemented(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV2)
+	action, err := s.uniterSuite.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.uniter.AppendActionOutput(action.ActionTag(), params.ActionStdout, "hello\n")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "AppendActionOutput() (need V3+) not implemented")
+}
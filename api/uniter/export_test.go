@@ -12,6 +12,16 @@ var (
 	NewSettings = newSettings
 	NewStateV0  = newStateV0
 	NewStateV1  = newStateV1
+	NewStateV2  = newStateV2
+	NewStateV3  = newStateV3
+	NewStateV4  = newStateV4
+	NewStateV5  = newStateV5
+	NewStateV6  = newStateV6
+	NewStateV7  = newStateV7
+	NewStateV8  = newStateV8
+	NewStateV9  = newStateV9
+	NewStateV10 = newStateV10
+	NewStateV11 = newStateV11
 )
 
 // PatchResponses changes the internal FacadeCaller to one that lets you return
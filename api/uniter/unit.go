@@ -5,6 +5,7 @@ package uniter
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -70,6 +71,245 @@ func (u *Unit) SetUnitStatus(status params.Status, info string, data map[string]
 	return result.OneError()
 }
 
+// UnitStatus returns the status of the unit's workload, together with
+// any structured data attached to it.
+func (u *Unit) UnitStatus() (params.Status, string, map[string]interface{}, error) {
+	if u.st.facade.BestAPIVersion() < 4 {
+		// UnitStatus() was introduced in UniterAPIV4.
+		return "", "", nil, errors.NotImplementedf("UnitStatus() (need V4+)")
+	}
+	var results params.StatusResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("UnitStatus", args, &results)
+	if err != nil {
+		return "", "", nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return "", "", nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", "", nil, result.Error
+	}
+	return result.Status, result.Info, result.Data, nil
+}
+
+// NetworkInfo returns the addresses the unit is reached on and
+// connects from on the network of the given endpoint binding.
+func (u *Unit) NetworkInfo(bindingName string) (params.NetworkInfo, error) {
+	if u.st.facade.BestAPIVersion() < 5 {
+		// NetworkInfo() was introduced in UniterAPIV5.
+		return params.NetworkInfo{}, errors.NotImplementedf("NetworkInfo() (need V5+)")
+	}
+	var results params.NetworkInfoResults
+	args := params.UnitBindings{
+		Bindings: []params.UnitBinding{{UnitTag: u.tag.String(), BindingName: bindingName}},
+	}
+	err := u.st.facade.FacadeCall("NetworkInfo", args, &results)
+	if err != nil {
+		return params.NetworkInfo{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.NetworkInfo{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.NetworkInfo{}, result.Error
+	}
+	return result.Info, nil
+}
+
+// SetWorkloadVersion records the version of the software run by the
+// unit.
+func (u *Unit) SetWorkloadVersion(version string) error {
+	if u.st.facade.BestAPIVersion() < 6 {
+		// SetWorkloadVersion() was introduced in UniterAPIV6.
+		return errors.NotImplementedf("SetWorkloadVersion() (need V6+)")
+	}
+	var result params.ErrorResults
+	args := params.EntityWorkloadVersions{
+		Entities: []params.EntityWorkloadVersion{
+			{Tag: u.tag.String(), WorkloadVersion: version},
+		},
+	}
+	err := u.st.facade.FacadeCall("SetWorkloadVersion", args, &result)
+	if err != nil {
+		return err
+	}
+	return result.OneError()
+}
+
+// GoalState returns the goal state of the unit's service.
+func (u *Unit) GoalState() (params.GoalState, error) {
+	if u.st.facade.BestAPIVersion() < 7 {
+		// GoalStates() was introduced in UniterAPIV7.
+		return params.GoalState{}, errors.NotImplementedf("GoalStates() (need V7+)")
+	}
+	var results params.GoalStateResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("GoalStates", args, &results)
+	if err != nil {
+		return params.GoalState{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.GoalState{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.GoalState{}, result.Error
+	}
+	return *result.Result, nil
+}
+
+// CreateSecret creates a secret owned by the unit's service, holding
+// the given data, and returns its id. If rotateInterval is non-zero,
+// the secret-rotate hook is run on the service's leader whenever that
+// much time has passed since the secret was last rotated.
+func (u *Unit) CreateSecret(label string, data map[string]string, rotateInterval time.Duration) (string, error) {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// CreateSecrets() was introduced in UniterAPIV8.
+		return "", errors.NotImplementedf("CreateSecrets() (need V8+)")
+	}
+	var results params.StringResults
+	args := params.CreateSecretArgs{
+		Args: []params.CreateSecretArg{{
+			UnitTag:        u.tag.String(),
+			Label:          label,
+			Data:           data,
+			RotateInterval: rotateInterval,
+		}},
+	}
+	err := u.st.facade.FacadeCall("CreateSecrets", args, &results)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return "", errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result, nil
+}
+
+// UpdateSecret replaces the data held by the given secret, which must
+// be owned by the unit's service.
+func (u *Unit) UpdateSecret(id string, data map[string]string) error {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// UpdateSecrets() was introduced in UniterAPIV8.
+		return errors.NotImplementedf("UpdateSecrets() (need V8+)")
+	}
+	var result params.ErrorResults
+	args := params.UpdateSecretArgs{
+		Args: []params.UpdateSecretArg{{
+			UnitTag:  u.tag.String(),
+			SecretId: id,
+			Data:     data,
+		}},
+	}
+	err := u.st.facade.FacadeCall("UpdateSecrets", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// SecretValue returns the data held by the given secret, which must be
+// owned by or granted to the unit's service.
+func (u *Unit) SecretValue(id string) (map[string]string, error) {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// SecretValues() was introduced in UniterAPIV8.
+		return nil, errors.NotImplementedf("SecretValues() (need V8+)")
+	}
+	var results params.SecretValueResults
+	args := params.SecretArgs{
+		Args: []params.SecretArg{{UnitTag: u.tag.String(), SecretId: id}},
+	}
+	err := u.st.facade.FacadeCall("SecretValues", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Data, nil
+}
+
+// GrantSecret allows the service at the other end of the given relation
+// to read the given secret, which must be owned by the unit's service.
+func (u *Unit) GrantSecret(id string, relationTag names.RelationTag) error {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// GrantSecrets() was introduced in UniterAPIV8.
+		return errors.NotImplementedf("GrantSecrets() (need V8+)")
+	}
+	var result params.ErrorResults
+	args := params.GrantSecretArgs{
+		Args: []params.GrantSecretArg{{
+			UnitTag:     u.tag.String(),
+			SecretId:    id,
+			RelationTag: relationTag.String(),
+		}},
+	}
+	err := u.st.facade.FacadeCall("GrantSecrets", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// SecretRotations returns when each secret owned by the unit's service
+// that is rotated periodically is next due rotation.
+func (u *Unit) SecretRotations() ([]params.SecretRotation, error) {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// SecretRotations() was introduced in UniterAPIV8.
+		return nil, errors.NotImplementedf("SecretRotations() (need V8+)")
+	}
+	var results params.SecretRotationsResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("SecretRotations", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Rotations, nil
+}
+
+// SecretRotated records that the given secret's rotation has been
+// handled, so that it is not due rotation again until its rotation
+// interval has passed.
+func (u *Unit) SecretRotated(id string) error {
+	if u.st.facade.BestAPIVersion() < 8 {
+		// SecretsRotated() was introduced in UniterAPIV8.
+		return errors.NotImplementedf("SecretsRotated() (need V8+)")
+	}
+	var result params.ErrorResults
+	args := params.SecretArgs{
+		Args: []params.SecretArg{{UnitTag: u.tag.String(), SecretId: id}},
+	}
+	err := u.st.facade.FacadeCall("SecretsRotated", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
 // SetAgentStatus sets the status of the unit agent.
 func (u *Unit) SetAgentStatus(status params.Status, info string, data map[string]interface{}) error {
 	var result params.ErrorResults
@@ -676,3 +916,286 @@ func (u *Unit) WatchMeterStatus() (watcher.NotifyWatcher, error) {
 func (u *Unit) WatchStorage() (watcher.StringsWatcher, error) {
 	return u.st.WatchUnitStorageAttachments(u.tag)
 }
+
+// SetHookQueue records the hooks the unit's agent has yet to run: the
+// failed hook awaiting resolution, if any, and the hooks queued behind
+// it, in the order they will run.
+func (u *Unit) SetHookQueue(pending *params.QueuedHook, queued []params.QueuedHook) error {
+	if u.st.facade.BestAPIVersion() < 9 {
+		// SetHookQueues() was introduced in UniterAPIV9.
+		return errors.NotImplementedf("SetHookQueues() (need V9+)")
+	}
+	var result params.ErrorResults
+	args := params.SetHookQueueArgs{
+		Args: []params.SetHookQueueArg{{
+			Tag:     u.tag.String(),
+			Pending: pending,
+			Queued:  queued,
+		}},
+	}
+	err := u.st.facade.FacadeCall("SetHookQueues", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// DroppedHooks returns the queued hooks that the unit's agent has been
+// asked not to run.
+func (u *Unit) DroppedHooks() ([]params.QueuedHook, error) {
+	if u.st.facade.BestAPIVersion() < 9 {
+		// HookQueues() was introduced in UniterAPIV9.
+		return nil, errors.NotImplementedf("HookQueues() (need V9+)")
+	}
+	var results params.HookQueueResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("HookQueues", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Dropped, nil
+}
+
+// WatchHookQueue returns a watcher for observing changes to the hooks
+// recorded as queued for the unit, and to requests to drop them.
+func (u *Unit) WatchHookQueue() (watcher.NotifyWatcher, error) {
+	if u.st.facade.BestAPIVersion() < 9 {
+		// WatchHookQueues() was introduced in UniterAPIV9.
+		return nil, errors.NotImplementedf("WatchHookQueues() (need V9+)")
+	}
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("WatchHookQueues", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(u.st.facade.RawAPICaller(), result), nil
+}
+
+// UpgradeSeriesStatus returns the unit's progress through an upgrade
+// of its machine's series. The status is empty if no upgrade is in
+// progress.
+func (u *Unit) UpgradeSeriesStatus() (string, error) {
+	if u.st.facade.BestAPIVersion() < 10 {
+		// UpgradeSeriesStatuses() was introduced in UniterAPIV10.
+		return "", errors.NotImplementedf("UpgradeSeriesStatuses() (need V10+)")
+	}
+	var results params.UpgradeSeriesStatusResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("UpgradeSeriesStatuses", args, &results)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return "", errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return result.Result.Status, nil
+}
+
+// SetUpgradeSeriesStatus records the unit's progress through an
+// upgrade of its machine's series, once it has run its
+// pre-series-upgrade or post-series-upgrade hook.
+func (u *Unit) SetUpgradeSeriesStatus(status string) error {
+	if u.st.facade.BestAPIVersion() < 10 {
+		// SetUpgradeSeriesStatuses() was introduced in UniterAPIV10.
+		return errors.NotImplementedf("SetUpgradeSeriesStatuses() (need V10+)")
+	}
+	var result params.ErrorResults
+	args := params.SetUpgradeSeriesStatuses{
+		Args: []params.SetUpgradeSeriesStatus{{
+			Tag:    u.tag.String(),
+			Status: status,
+		}},
+	}
+	err := u.st.facade.FacadeCall("SetUpgradeSeriesStatuses", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// WatchUpgradeSeries returns a watcher for observing the progress of
+// upgrades of the series of the unit's machine.
+func (u *Unit) WatchUpgradeSeries() (watcher.NotifyWatcher, error) {
+	if u.st.facade.BestAPIVersion() < 10 {
+		// WatchUpgradeSeries() was introduced in UniterAPIV10.
+		return nil, errors.NotImplementedf("WatchUpgradeSeries() (need V10+)")
+	}
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("WatchUpgradeSeries", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(u.st.facade.RawAPICaller(), result), nil
+}
+
+// SetDrainStatus records the hooks the unit's agent has yet to run
+// before it stops, now that the unit is dying, and whether it has
+// finished draining.
+func (u *Unit) SetDrainStatus(pending []string, drained bool) error {
+	if u.st.drainFacade.BestAPIVersion() < 1 {
+		// SetDrainStatuses() was introduced in UnitDrainAPI.
+		return errors.NotImplementedf("SetDrainStatuses()")
+	}
+	var result params.ErrorResults
+	args := params.SetDrainStatusArgs{
+		Args: []params.SetDrainStatusArg{{
+			Tag:     u.tag.String(),
+			Pending: pending,
+			Drained: drained,
+		}},
+	}
+	err := u.st.drainFacade.FacadeCall("SetDrainStatuses", args, &result)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return result.OneError()
+}
+
+// SetHookResult records the outcome, and the tail of the output, of
+// the hook the unit's agent ran most recently.
+func (u *Unit) SetHookResult(result params.HookResult) error {
+	if u.st.facade.BestAPIVersion() < 11 {
+		// SetHookResults() was introduced in UniterAPIV11.
+		return errors.NotImplementedf("SetHookResults() (need V11+)")
+	}
+	var results params.ErrorResults
+	args := params.SetHookResultArgs{
+		Args: []params.SetHookResultArg{{
+			Tag:    u.tag.String(),
+			Result: result,
+		}},
+	}
+	err := u.st.facade.FacadeCall("SetHookResults", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// LastHookResult returns the outcome, and the tail of the output, of
+// the hook the unit's agent ran most recently.
+func (u *Unit) LastHookResult() (params.HookResult, error) {
+	if u.st.facade.BestAPIVersion() < 11 {
+		// HookResults() was introduced in UniterAPIV11.
+		return params.HookResult{}, errors.NotImplementedf("HookResults() (need V11+)")
+	}
+	var results params.HookResultResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("HookResults", args, &results)
+	if err != nil {
+		return params.HookResult{}, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return params.HookResult{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.HookResult{}, result.Error
+	}
+	return *result.Result, nil
+}
+
+// RelationStates returns the bookkeeping the unit's agent keeps for
+// each of the unit's relations, recording which relation hooks it has
+// run.
+func (u *Unit) RelationStates() ([]params.UnitRelationState, error) {
+	if u.st.facade.BestAPIVersion() < 12 {
+		// RelationStates() was introduced in UniterAPIV12.
+		return nil, errors.NotImplementedf("RelationStates() (need V12+)")
+	}
+	var results params.UnitRelationStatesResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: u.tag.String()}},
+	}
+	err := u.st.facade.FacadeCall("RelationStates", args, &results)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.States, nil
+}
+
+// SetRelationState records the bookkeeping the unit's agent keeps for
+// one of the unit's relations.
+func (u *Unit) SetRelationState(state params.UnitRelationState) error {
+	if u.st.facade.BestAPIVersion() < 12 {
+		// SetRelationStates() was introduced in UniterAPIV12.
+		return errors.NotImplementedf("SetRelationStates() (need V12+)")
+	}
+	var results params.ErrorResults
+	args := params.SetUnitRelationStateArgs{
+		Args: []params.SetUnitRelationStateArg{{
+			Tag:   u.tag.String(),
+			State: state,
+		}},
+	}
+	err := u.st.facade.FacadeCall("SetRelationStates", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// RemoveRelationState removes the bookkeeping the unit's agent keeps
+// for the relation with the given id.
+func (u *Unit) RemoveRelationState(relationId int) error {
+	if u.st.facade.BestAPIVersion() < 12 {
+		// RemoveRelationStates() was introduced in UniterAPIV12.
+		return errors.NotImplementedf("RemoveRelationStates() (need V12+)")
+	}
+	var results params.ErrorResults
+	args := params.RemoveUnitRelationStateArgs{
+		Args: []params.RemoveUnitRelationStateArg{{
+			Tag:        u.tag.String(),
+			RelationId: relationId,
+		}},
+	}
+	err := u.st.facade.FacadeCall("RemoveRelationStates", args, &results)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
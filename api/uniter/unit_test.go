@@ -122,6 +122,364 @@ func (s *unitSuite) TestSetUnitStatusOldServer(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, "SetUnitStatus not implemented")
 }
 
+func (s *unitSuite) TestUnitStatus(c *gc.C) {
+	data := map[string]interface{}{"connections": 42.0}
+	err := s.apiUnit.SetUnitStatus(params.StatusRunning, "ready", data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, info, got, err := s.apiUnit.UnitStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, params.StatusRunning)
+	c.Assert(info, gc.Equals, "ready")
+	c.Assert(got, jc.DeepEquals, data)
+}
+
+func (s *unitSuite) TestUnitStatusOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV3)
+
+	_, _, _, err := s.apiUnit.UnitStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "UnitStatus() (need V4+) not implemented")
+}
+
+func (s *unitSuite) TestNetworkInfo(c *gc.C) {
+	err := s.wordpressMachine.SetAddresses(network.NewAddress("1.2.3.4", network.ScopeCloudLocal))
+	c.Assert(err, jc.ErrorIsNil)
+
+	info, err := s.apiUnit.NetworkInfo("db")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info, jc.DeepEquals, params.NetworkInfo{
+		IngressAddresses: []string{"1.2.3.4"},
+		EgressSubnets:    []string{"1.2.3.4/32"},
+	})
+
+	_, err = s.apiUnit.NetworkInfo("nonsense")
+	c.Assert(err, gc.ErrorMatches, `binding "nonsense" not found`)
+}
+
+func (s *unitSuite) TestNetworkInfoOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV4)
+
+	_, err := s.apiUnit.NetworkInfo("db")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "NetworkInfo() (need V5+) not implemented")
+}
+
+func (s *unitSuite) TestSetWorkloadVersion(c *gc.C) {
+	err := s.apiUnit.SetWorkloadVersion("4.3.1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.wordpressUnit.WorkloadVersion(), gc.Equals, "4.3.1")
+}
+
+func (s *unitSuite) TestSetWorkloadVersionOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV5)
+
+	err := s.apiUnit.SetWorkloadVersion("4.3.1")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SetWorkloadVersion() (need V6+) not implemented")
+}
+
+func (s *unitSuite) TestGoalState(c *gc.C) {
+	err := s.wordpressUnit.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	// The related service has no units yet.
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.addRelation(c, "wordpress", "mysql")
+
+	goalState, err := s.apiUnit.GoalState()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(goalState, jc.DeepEquals, params.GoalState{
+		Units: params.UnitsGoalState{
+			"wordpress/0": {Status: "running"},
+		},
+		Relations: map[string]params.UnitsGoalState{
+			"db": {"mysql": {Status: "joined"}},
+		},
+	})
+}
+
+func (s *unitSuite) TestGoalStateOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV6)
+
+	_, err := s.apiUnit.GoalState()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "GoalStates() (need V7+) not implemented")
+}
+
+func (s *unitSuite) TestSecrets(c *gc.C) {
+	id, err := s.apiUnit.CreateSecret("admin", map[string]string{"password": "sekrit"}, time.Hour)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := s.apiUnit.SecretValue(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, map[string]string{"password": "sekrit"})
+
+	err = s.apiUnit.UpdateSecret(id, map[string]string{"password": "new"})
+	c.Assert(err, jc.ErrorIsNil)
+	data, err = s.apiUnit.SecretValue(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, map[string]string{"password": "new"})
+
+	rotations, err := s.apiUnit.SecretRotations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rotations, gc.HasLen, 1)
+	c.Assert(rotations[0].SecretId, gc.Equals, id)
+	err = s.apiUnit.SecretRotated(id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.apiUnit.SecretValue("no-such-secret")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *unitSuite) TestGrantSecret(c *gc.C) {
+	id, err := s.apiUnit.CreateSecret("", map[string]string{"password": "sekrit"}, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	rel := s.addRelation(c, "wordpress", "mysql")
+
+	err = s.apiUnit.GrantSecret(id, rel.Tag().(names.RelationTag))
+	c.Assert(err, jc.ErrorIsNil)
+
+	secret, err := s.State.Secret(id)
+	c.Assert(err, jc.ErrorIsNil)
+	canRead, err := secret.CanRead("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsTrue)
+}
+
+func (s *unitSuite) TestSecretsOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV7)
+
+	_, err := s.apiUnit.CreateSecret("", map[string]string{"password": "sekrit"}, 0)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "CreateSecrets() (need V8+) not implemented")
+	_, err = s.apiUnit.SecretValue("secret-id")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SecretValues() (need V8+) not implemented")
+	_, err = s.apiUnit.SecretRotations()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SecretRotations() (need V8+) not implemented")
+}
+
+func (s *unitSuite) TestSetHookQueue(c *gc.C) {
+	pending := params.QueuedHook{Kind: "config-changed"}
+	err := s.apiUnit.SetHookQueue(&pending, []params.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	queue, err := s.wordpressUnit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue, jc.DeepEquals, state.HookQueue{
+		Pending: &state.QueuedHook{Kind: "config-changed"},
+		Queued: []state.QueuedHook{
+			{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+		},
+	})
+}
+
+func (s *unitSuite) TestDroppedHooks(c *gc.C) {
+	dropped, err := s.apiUnit.DroppedHooks()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dropped, gc.HasLen, 0)
+
+	err = s.wordpressUnit.SetHookQueue(nil, []state.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.wordpressUnit.DropQueuedHook("relation-changed:0:mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	dropped, err = s.apiUnit.DroppedHooks()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dropped, jc.DeepEquals, []params.QueuedHook{{
+		Id:         "relation-changed:0:mysql/0",
+		Kind:       "relation-changed",
+		RelationId: 0,
+		RemoteUnit: "mysql/0",
+	}})
+}
+
+func (s *unitSuite) TestWatchHookQueue(c *gc.C) {
+	w, err := s.apiUnit.WatchHookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	err = s.wordpressUnit.SetHookQueue(nil, []state.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.wordpressUnit.DropQueuedHook("relation-changed:0:mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *unitSuite) TestHookQueueOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV8)
+
+	err := s.apiUnit.SetHookQueue(nil, nil)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SetHookQueues() (need V9+) not implemented")
+	_, err = s.apiUnit.DroppedHooks()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "HookQueues() (need V9+) not implemented")
+	_, err = s.apiUnit.WatchHookQueue()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "WatchHookQueues() (need V9+) not implemented")
+}
+
+func (s *unitSuite) TestHookResult(c *gc.C) {
+	_, err := s.apiUnit.LastHookResult()
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+
+	result := params.HookResult{
+		Hook:   "config-changed",
+		Time:   time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+		Error:  "exit status 1",
+		Output: "cannot write config\n",
+	}
+	err = s.apiUnit.SetHookResult(result)
+	c.Assert(err, jc.ErrorIsNil)
+	stored, err := s.wordpressUnit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stored.Output, gc.Equals, "cannot write config\n")
+
+	got, err := s.apiUnit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, result)
+}
+
+func (s *unitSuite) TestHookResultOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV10)
+
+	err := s.apiUnit.SetHookResult(params.HookResult{Hook: "start"})
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SetHookResults() (need V11+) not implemented")
+	_, err = s.apiUnit.LastHookResult()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "HookResults() (need V11+) not implemented")
+}
+
+func (s *unitSuite) TestRelationStates(c *gc.C) {
+	states, err := s.apiUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+
+	joined := params.UnitRelationState{
+		RelationId:     3,
+		Members:        map[string]int64{"mysql/0": 1},
+		ChangedPending: "mysql/0",
+	}
+	err = s.apiUnit.SetRelationState(joined)
+	c.Assert(err, jc.ErrorIsNil)
+	stored, err := s.wordpressUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stored, gc.HasLen, 1)
+	c.Assert(stored[0].ChangedPending, gc.Equals, "mysql/0")
+
+	states, err = s.apiUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, []params.UnitRelationState{joined})
+
+	err = s.apiUnit.RemoveRelationState(3)
+	c.Assert(err, jc.ErrorIsNil)
+	states, err = s.apiUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+}
+
+func (s *unitSuite) TestRelationStatesOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV11)
+
+	_, err := s.apiUnit.RelationStates()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "RelationStates() (need V12+) not implemented")
+	err = s.apiUnit.SetRelationState(params.UnitRelationState{RelationId: 3})
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SetRelationStates() (need V12+) not implemented")
+	err = s.apiUnit.RemoveRelationState(3)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "RemoveRelationStates() (need V12+) not implemented")
+}
+
+func (s *unitSuite) TestSetDrainStatus(c *gc.C) {
+	err := s.apiUnit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, gc.ErrorMatches, `cannot set drain status for unit "wordpress/0": unit is not dying`)
+
+	err = s.wordpressUnit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.wordpressUnit.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.apiUnit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.wordpressUnit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Phase, gc.Equals, state.UnitDraining)
+	c.Assert(status.Pending, jc.DeepEquals, []string{"stop"})
+
+	err = s.apiUnit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.wordpressUnit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Phase, gc.Equals, state.UnitDrained)
+}
+
+func (s *unitSuite) TestUpgradeSeriesStatus(c *gc.C) {
+	w, err := s.apiUnit.WatchUpgradeSeries()
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	status, err := s.apiUnit.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, "")
+
+	err = s.wordpressMachine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	status, err = s.apiUnit.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, "prepare started")
+
+	err = s.apiUnit.SetUpgradeSeriesStatus("prepare completed")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	machineStatus, _, err := s.wordpressMachine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineStatus, gc.Equals, state.UpgradeSeriesPrepareCompleted)
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *unitSuite) TestUpgradeSeriesOldServer(c *gc.C) {
+	s.patchNewState(c, uniter.NewStateV9)
+
+	_, err := s.apiUnit.UpgradeSeriesStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "UpgradeSeriesStatuses() (need V10+) not implemented")
+	err = s.apiUnit.SetUpgradeSeriesStatus("prepare completed")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "SetUpgradeSeriesStatuses() (need V10+) not implemented")
+	_, err = s.apiUnit.WatchUpgradeSeries()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	c.Assert(err.Error(), gc.Equals, "WatchUpgradeSeries() (need V10+) not implemented")
+}
+
 func (s *unitSuite) TestSetAgentStatusOldServer(c *gc.C) {
 	s.patchNewState(c, uniter.NewStateV1)
 
@@ -17,7 +17,10 @@ import (
 	"github.com/juju/juju/network"
 )
 
-const uniterFacade = "Uniter"
+const (
+	uniterFacade    = "Uniter"
+	unitDrainFacade = "UnitDrain"
+)
 
 // State provides access to the Uniter API facade.
 type State struct {
@@ -27,6 +30,8 @@ type State struct {
 
 	LeadershipSettings *LeadershipSettingsAccessor
 	facade             base.FacadeCaller
+	// drainFacade reports the unit's progress through draining.
+	drainFacade base.FacadeCaller
 	// unitTag contains the authenticated unit's tag.
 	unitTag names.UnitTag
 }
@@ -48,6 +53,7 @@ func newStateForVersion(
 		APIAddresser:    common.NewAPIAddresser(facadeCaller),
 		StorageAccessor: NewStorageAccessor(facadeCaller),
 		facade:          facadeCaller,
+		drainFacade:     base.NewFacadeCaller(caller, unitDrainFacade),
 		unitTag:         authTag,
 	}
 
@@ -80,9 +86,39 @@ var newStateV1 = newStateForVersionFn(1)
 // newStateV2 creates a new client-side Uniter facade, version 2.
 var newStateV2 = newStateForVersionFn(2)
 
+// newStateV3 creates a new client-side Uniter facade, version 3.
+var newStateV3 = newStateForVersionFn(3)
+
+// newStateV4 creates a new client-side Uniter facade, version 4.
+var newStateV4 = newStateForVersionFn(4)
+
+// newStateV5 creates a new client-side Uniter facade, version 5.
+var newStateV5 = newStateForVersionFn(5)
+
+// newStateV6 creates a new client-side Uniter facade, version 6.
+var newStateV6 = newStateForVersionFn(6)
+
+// newStateV7 creates a new client-side Uniter facade, version 7.
+var newStateV7 = newStateForVersionFn(7)
+
+// newStateV8 creates a new client-side Uniter facade, version 8.
+var newStateV8 = newStateForVersionFn(8)
+
+// newStateV9 creates a new client-side Uniter facade, version 9.
+var newStateV9 = newStateForVersionFn(9)
+
+// newStateV10 creates a new client-side Uniter facade, version 10.
+var newStateV10 = newStateForVersionFn(10)
+
+// newStateV11 creates a new client-side Uniter facade, version 11.
+var newStateV11 = newStateForVersionFn(11)
+
+// newStateV12 creates a new client-side Uniter facade, version 12.
+var newStateV12 = newStateForVersionFn(12)
+
 // NewState creates a new client-side Uniter facade.
 // Defined like this to allow patching during tests.
-var NewState = newStateV2
+var NewState = newStateV12
 
 // BestAPIVersion returns the API version that we were able to
 // determine is supported by both the client and the API Server.
@@ -279,6 +315,29 @@ func (st *State) ActionFinish(tag names.ActionTag, status string, results map[st
 	return nil
 }
 
+// AppendActionOutput records a chunk of output written by a running
+// action to the given stream, params.ActionStdout or
+// params.ActionStderr.
+func (st *State) AppendActionOutput(tag names.ActionTag, stream, data string) error {
+	if st.BestAPIVersion() < 3 {
+		// AppendActionOutput() was introduced in UniterAPIV3.
+		return errors.NotImplementedf("AppendActionOutput() (need V3+)")
+	}
+	var outcome params.ErrorResults
+	args := params.ActionOutputArgs{
+		Output: []params.ActionOutputArg{{
+			ActionTag: tag.String(),
+			Stream:    stream,
+			Data:      data,
+		}},
+	}
+	err := st.facade.FacadeCall("AppendActionOutput", args, &outcome)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return outcome.OneError()
+}
+
 // RelationById returns the existing relation with the given id.
 func (st *State) RelationById(id int) (*Relation, error) {
 	var results params.RelationResults
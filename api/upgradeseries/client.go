@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows clients to upgrade the series of the environment's
+// machines.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the UpgradeSeries API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "UpgradeSeries")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Prepare starts an upgrade of the given machine to the given series,
+// running the pre-series-upgrade hooks of the units on it.
+func (c *Client) Prepare(machineId, series string) error {
+	args := params.UpgradeSeriesPrepareArgs{
+		Args: []params.UpgradeSeriesPrepareArg{{
+			Tag:    names.NewMachineTag(machineId).String(),
+			Series: series,
+		}},
+	}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("Prepare", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// Complete records that the given machine's operating system has been
+// upgraded, so that its agent and units adjust to the new series.
+func (c *Client) Complete(machineId string) error {
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("Complete", machineArgs(machineId), &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
+
+// MachineStatus returns the progress of the given machine through an
+// upgrade of its series.
+func (c *Client) MachineStatus(machineId string) (params.UpgradeSeriesStatus, error) {
+	var results params.UpgradeSeriesStatusResults
+	if err := c.facade.FacadeCall("MachineStatus", machineArgs(machineId), &results); err != nil {
+		return params.UpgradeSeriesStatus{}, errors.Trace(err)
+	}
+	return oneStatus(results)
+}
+
+func machineArgs(machineId string) params.Entities {
+	return params.Entities{
+		Entities: []params.Entity{{Tag: names.NewMachineTag(machineId).String()}},
+	}
+}
+
+func oneStatus(results params.UpgradeSeriesStatusResults) (params.UpgradeSeriesStatus, error) {
+	if len(results.Results) != 1 {
+		return params.UpgradeSeriesStatus{}, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.UpgradeSeriesStatus{}, result.Error
+	}
+	return *result.Result, nil
+}
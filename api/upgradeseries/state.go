@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// State provides access to the UpgradeSeries API facade for a machine
+// agent, used to adjust the agent to the series its machine is being
+// upgraded to.
+type State struct {
+	machineTag names.MachineTag
+	facade     base.FacadeCaller
+}
+
+// NewState returns a new State for the given machine.
+func NewState(caller base.APICaller, machineTag names.MachineTag) *State {
+	return &State{
+		facade:     base.NewFacadeCaller(caller, "UpgradeSeries"),
+		machineTag: machineTag,
+	}
+}
+
+// WatchMachine returns a watcher that notifies of the progress of an
+// upgrade of the machine's series.
+func (st *State) WatchMachine() (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	if err := st.facade.FacadeCall("WatchMachine", machineArgs(st.machineTag.Id()), &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return watcher.NewNotifyWatcher(st.facade.RawAPICaller(), result), nil
+}
+
+// MachineStatus returns the progress of the machine through an upgrade
+// of its series.
+func (st *State) MachineStatus() (params.UpgradeSeriesStatus, error) {
+	var results params.UpgradeSeriesStatusResults
+	if err := st.facade.FacadeCall("MachineStatus", machineArgs(st.machineTag.Id()), &results); err != nil {
+		return params.UpgradeSeriesStatus{}, errors.Trace(err)
+	}
+	return oneStatus(results)
+}
+
+// SetAgentUpdated records that the machine's agent has adjusted itself
+// to the series its machine is being upgraded to.
+func (st *State) SetAgentUpdated() error {
+	var results params.ErrorResults
+	if err := st.facade.FacadeCall("SetAgentUpdated", machineArgs(st.machineTag.Id()), &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/upgradeseries"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type upgradeSeriesSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&upgradeSeriesSuite{})
+
+var machineArgs = params.Entities{Entities: []params.Entity{{Tag: "machine-1"}}}
+
+func (s *upgradeSeriesSuite) TestPrepare(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "UpgradeSeries")
+			c.Check(request, gc.Equals, "Prepare")
+			c.Check(a, jc.DeepEquals, params.UpgradeSeriesPrepareArgs{
+				Args: []params.UpgradeSeriesPrepareArg{{
+					Tag:    "machine-1",
+					Series: "trusty",
+				}},
+			})
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	client := upgradeseries.NewClient(apiCaller)
+	err := client.Prepare("1", "trusty")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *upgradeSeriesSuite) TestComplete(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "UpgradeSeries")
+			c.Check(request, gc.Equals, "Complete")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{
+				Error: &params.Error{Message: "boom"},
+			}}
+			return nil
+		})
+	client := upgradeseries.NewClient(apiCaller)
+	err := client.Complete("1")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *upgradeSeriesSuite) TestMachineStatus(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "UpgradeSeries")
+			c.Check(request, gc.Equals, "MachineStatus")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.UpgradeSeriesStatusResults)
+			result.Results = []params.UpgradeSeriesStatusResult{{
+				Result: &params.UpgradeSeriesStatus{Status: "complete started", Series: "trusty"},
+			}}
+			return nil
+		})
+	st := upgradeseries.NewState(apiCaller, names.NewMachineTag("1"))
+	status, err := st.MachineStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, params.UpgradeSeriesStatus{Status: "complete started", Series: "trusty"})
+}
+
+func (s *upgradeSeriesSuite) TestSetAgentUpdated(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			c.Check(objType, gc.Equals, "UpgradeSeries")
+			c.Check(request, gc.Equals, "SetAgentUpdated")
+			c.Check(a, jc.DeepEquals, machineArgs)
+			result := response.(*params.ErrorResults)
+			result.Results = []params.ErrorResult{{}}
+			return nil
+		})
+	st := upgradeseries.NewState(apiCaller, names.NewMachineTag("1"))
+	err := st.SetAgentUpdated()
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package waitfor
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the wait-for API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the wait-for API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "WaitFor")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// WatchCondition returns a watcher reporting the evaluation of the
+// given condition on the fields of the entity with the given tag.
+func (c *Client) WatchCondition(tag names.Tag, condition string) (*ConditionWatcher, error) {
+	args := params.EntityConditions{
+		Conditions: []params.EntityCondition{{
+			Tag:       tag.String(),
+			Condition: condition,
+		}},
+	}
+	var results params.ConditionWatchResults
+	if err := c.facade.FacadeCall("WatchConditions", args, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", len(results.Results))
+	}
+	if err := results.Results[0].Error; err != nil {
+		return nil, err
+	}
+	return &ConditionWatcher{
+		caller: c.facade.RawAPICaller(),
+		id:     results.Results[0].ConditionWatcherId,
+	}, nil
+}
+
+// ConditionWatcher reports the evaluation of an entity condition
+// whenever it changes.
+type ConditionWatcher struct {
+	caller base.APICaller
+	id     string
+}
+
+// Next returns the evaluation of the condition when it is first
+// known, and subsequently blocks until it changes.
+func (w *ConditionWatcher) Next() (params.ConditionResult, error) {
+	var result params.ConditionResult
+	err := w.caller.APICall(
+		"ConditionWatcher", w.caller.BestFacadeVersion("ConditionWatcher"),
+		w.id, "Next", nil, &result)
+	return result, err
+}
+
+// Stop stops the watcher.
+func (w *ConditionWatcher) Stop() error {
+	return w.caller.APICall(
+		"ConditionWatcher", w.caller.BestFacadeVersion("ConditionWatcher"),
+		w.id, "Stop", nil, nil)
+}
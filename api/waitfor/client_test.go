@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package waitfor_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/waitfor"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type waitForSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&waitForSuite{})
+
+func (s *waitForSuite) TestWatchCondition(c *gc.C) {
+	var calls []string
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			calls = append(calls, objType+"."+request)
+			switch objType {
+			case "WaitFor":
+				c.Check(id, gc.Equals, "")
+				c.Check(request, gc.Equals, "WatchConditions")
+				c.Check(a, jc.DeepEquals, params.EntityConditions{
+					Conditions: []params.EntityCondition{{
+						Tag:       "service-mysql",
+						Condition: "units >= 3",
+					}},
+				})
+				result := response.(*params.ConditionWatchResults)
+				result.Results = []params.ConditionWatchResult{{ConditionWatcherId: "7"}}
+			case "ConditionWatcher":
+				c.Check(id, gc.Equals, "7")
+				if request == "Next" {
+					result := response.(*params.ConditionResult)
+					result.Unsatisfied = []string{"units >= 3 (units is 1)"}
+				}
+			}
+			return nil
+		})
+	client := waitfor.NewClient(apiCaller)
+	w, err := client.WatchCondition(names.NewServiceTag("mysql"), "units >= 3")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := w.Next()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConditionResult{
+		Unsatisfied: []string{"units >= 3 (units is 1)"},
+	})
+	err = w.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(calls, jc.DeepEquals, []string{
+		"WaitFor.WatchConditions",
+		"ConditionWatcher.Next",
+		"ConditionWatcher.Stop",
+	})
+}
+
+func (s *waitForSuite) TestWatchConditionError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			result := response.(*params.ConditionWatchResults)
+			result.Results = []params.ConditionWatchResult{{
+				Error: &params.Error{Message: `clause "units" not valid`},
+			}}
+			return nil
+		})
+	client := waitfor.NewClient(apiCaller)
+	_, err := client.WatchCondition(names.NewServiceTag("mysql"), "units")
+	c.Assert(err, gc.ErrorMatches, `clause "units" not valid`)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 )
 
 var logger = loggo.GetLogger("juju.apiserver.action")
@@ -172,6 +173,114 @@ func (a *ActionAPI) Cancel(arg params.Entities) (params.ActionResults, error) {
 	return response, nil
 }
 
+// WatchOutput returns a NotifyWatcher for each given Action, which
+// notifies when the Action records output or changes status.
+func (a *ActionAPI) WatchOutput(arg params.Entities) (params.NotifyWatchResults, error) {
+	response := params.NotifyWatchResults{Results: make([]params.NotifyWatchResult, len(arg.Entities))}
+	for i, entity := range arg.Entities {
+		currentResult := &response.Results[i]
+		action, err := actionFromTag(a.state, entity.Tag)
+		if err != nil {
+			currentResult.Error = common.ServerError(err)
+			continue
+		}
+		watch := action.Watch()
+		// Consume the initial event.
+		if _, ok := <-watch.Changes(); ok {
+			currentResult.NotifyWatcherId = a.resources.Register(watch)
+		} else {
+			currentResult.Error = common.ServerError(watcher.EnsureErr(watch))
+		}
+	}
+	return response, nil
+}
+
+// Output returns the output recorded by each given Action while
+// running, starting at the requested chunk, together with the Action's
+// status. The status is read before the output, so once it reports
+// that the Action has finished, all its output has been returned.
+func (a *ActionAPI) Output(arg params.ActionOutputQueries) (params.ActionOutputResults, error) {
+	response := params.ActionOutputResults{Results: make([]params.ActionOutputResult, len(arg.Queries))}
+	for i, query := range arg.Queries {
+		currentResult := &response.Results[i]
+		action, err := actionFromTag(a.state, query.ActionTag)
+		if err != nil {
+			currentResult.Error = common.ServerError(err)
+			continue
+		}
+		chunks, err := action.Output(query.From)
+		if err != nil {
+			currentResult.Error = common.ServerError(err)
+			continue
+		}
+		currentResult.Status = string(action.Status())
+		for _, chunk := range chunks {
+			currentResult.Chunks = append(currentResult.Chunks, params.ActionOutputChunk{
+				Seq:      chunk.Seq,
+				Stream:   chunk.Stream,
+				Data:     chunk.Data,
+				Recorded: chunk.Recorded,
+			})
+		}
+	}
+	return response, nil
+}
+
+// Schedule takes a list of actions and schedules each to be queued
+// for the designated ActionReceiver once at a future time, or
+// repeatedly according to a cron expression.
+func (a *ActionAPI) Schedule(arg params.ActionScheduleArgs) (params.ActionScheduleResults, error) {
+	response := params.ActionScheduleResults{Results: make([]params.ActionScheduleResult, len(arg.Schedules))}
+	for i, sched := range arg.Schedules {
+		currentResult := &response.Results[i]
+		receiver, err := tagToActionReceiver(a.state, sched.Action.Receiver)
+		if err != nil {
+			currentResult.Error = common.ServerError(err)
+			continue
+		}
+		schedule, err := receiver.ScheduleAction(sched.Action.Name, sched.Action.Parameters, sched.At, sched.Cron)
+		if err != nil {
+			currentResult.Error = common.ServerError(err)
+			continue
+		}
+		currentResult.Schedule = makeActionSchedule(receiver.Tag(), schedule)
+	}
+	return response, nil
+}
+
+// ListSchedules returns all the action schedules in the environment,
+// ordered by the time they next run.
+func (a *ActionAPI) ListSchedules() (params.ActionScheduleResults, error) {
+	schedules, err := a.state.AllActionSchedules()
+	if err != nil {
+		return params.ActionScheduleResults{}, common.ServerError(err)
+	}
+	response := params.ActionScheduleResults{Results: make([]params.ActionScheduleResult, len(schedules))}
+	for i, schedule := range schedules {
+		receiverTag, err := names.ActionReceiverTag(schedule.Receiver())
+		if err != nil {
+			response.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		response.Results[i].Schedule = makeActionSchedule(receiverTag, schedule)
+	}
+	return response, nil
+}
+
+// CancelSchedules cancels the action schedules with the given ids, so
+// that no further actions are queued by them.
+func (a *ActionAPI) CancelSchedules(arg params.ActionScheduleIds) (params.ErrorResults, error) {
+	response := params.ErrorResults{Results: make([]params.ErrorResult, len(arg.Ids))}
+	for i, id := range arg.Ids {
+		schedule, err := a.state.ActionSchedule(id)
+		if err == nil {
+			err = schedule.Cancel()
+		}
+		response.Results[i].Error = common.ServerError(err)
+	}
+	return response, nil
+}
+
 // ServicesCharmActions returns a slice of charm Actions for a slice of
 // services.
 func (a *ActionAPI) ServicesCharmActions(args params.Entities) (params.ServicesCharmActionsResults, error) {
@@ -241,6 +350,16 @@ func tagToActionReceiver(st *state.State, tag string) (state.ActionReceiver, err
 	return receiver, nil
 }
 
+// actionFromTag takes a tag string and returns the Action it
+// identifies.
+func actionFromTag(st *state.State, tag string) (*state.Action, error) {
+	actionTag, err := names.ParseActionTag(tag)
+	if err != nil {
+		return nil, common.ErrBadId
+	}
+	return st.ActionByTag(actionTag)
+}
+
 // extractorFn is the generic signature for functions that extract
 // state.Actions from an ActionReceiver, and return them as a slice of
 // params.ActionResult.
@@ -322,3 +441,25 @@ func makeActionResult(actionReceiverTag names.Tag, action *state.Action) params.
 		Completed: action.Completed(),
 	}
 }
+
+// makeActionSchedule converts a *state.ActionSchedule to a
+// params.ActionSchedule.
+func makeActionSchedule(actionReceiverTag names.Tag, schedule *state.ActionSchedule) *params.ActionSchedule {
+	lastRun, lastAction := schedule.LastRun()
+	result := &params.ActionSchedule{
+		Id: schedule.Id(),
+		Action: params.Action{
+			Receiver:   actionReceiverTag.String(),
+			Name:       schedule.Name(),
+			Parameters: schedule.Parameters(),
+		},
+		Cron:    schedule.Cron(),
+		NextRun: schedule.NextRun(),
+		Created: schedule.Created(),
+		LastRun: lastRun,
+	}
+	if lastAction != "" {
+		result.LastAction = names.NewActionTag(lastAction).String()
+	}
+	return result
+}
@@ -6,9 +6,11 @@ package action_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v4"
 
@@ -17,7 +19,9 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/rpc/rpcreflect"
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 	coretesting "github.com/juju/juju/testing"
 	jujuFactory "github.com/juju/juju/testing/factory"
 )
@@ -574,6 +578,158 @@ func (s *actionSuite) TestCancel(c *gc.C) {
 	c.Assert(myActions[1].Status, gc.Equals, params.ActionCancelled)
 }
 
+func (s *actionSuite) TestSchedule(c *gc.C) {
+	at := time.Now().Add(time.Hour).Round(time.Second)
+	arg := params.ActionScheduleArgs{
+		Schedules: []params.ActionScheduleArg{{
+			// Good, once.
+			Action: params.Action{Receiver: s.wordpressUnit.Tag().String(), Name: "fakeaction"},
+			At:     at,
+		}, {
+			// Good, recurring.
+			Action: params.Action{
+				Receiver:   s.mysqlUnit.Tag().String(),
+				Name:       "fakeaction",
+				Parameters: map[string]interface{}{"foo": "bar"},
+			},
+			Cron: "@daily",
+		}, {
+			// Service tag instead of Unit tag.
+			Action: params.Action{Receiver: s.wordpress.Tag().String(), Name: "fakeaction"},
+			At:     at,
+		}, {
+			// Bad cron expression.
+			Action: params.Action{Receiver: s.mysqlUnit.Tag().String(), Name: "fakeaction"},
+			Cron:   "sometimes",
+		}},
+	}
+	res, err := s.action.Schedule(arg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Results, gc.HasLen, 4)
+
+	c.Assert(res.Results[0].Error, gc.IsNil)
+	once := res.Results[0].Schedule
+	c.Assert(once, gc.NotNil)
+	c.Assert(once.Action, jc.DeepEquals, params.Action{
+		Receiver: s.wordpressUnit.Tag().String(),
+		Name:     "fakeaction",
+	})
+	c.Assert(once.NextRun.Equal(at), jc.IsTrue)
+	c.Assert(once.Cron, gc.Equals, "")
+
+	c.Assert(res.Results[1].Error, gc.IsNil)
+	recurring := res.Results[1].Schedule
+	c.Assert(recurring, gc.NotNil)
+	c.Assert(recurring.Cron, gc.Equals, "@daily")
+	c.Assert(recurring.Action.Parameters, jc.DeepEquals, map[string]interface{}{"foo": "bar"})
+
+	c.Assert(res.Results[2].Error, gc.DeepEquals, &params.Error{Message: "id not found", Code: "not found"})
+	c.Assert(res.Results[3].Error, gc.ErrorMatches, `cron expression "sometimes" must have 5 fields, got 1`)
+
+	list, err := s.action.ListSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list.Results, gc.HasLen, 2)
+	ids := set.NewStrings()
+	for _, result := range list.Results {
+		c.Assert(result.Error, gc.IsNil)
+		ids.Add(result.Schedule.Id)
+	}
+	c.Assert(ids.SortedValues(), jc.SameContents, []string{once.Id, recurring.Id})
+
+	// No actions are queued until the schedules fall due.
+	actions, err := s.wordpressUnit.Actions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions, gc.HasLen, 0)
+}
+
+func (s *actionSuite) TestCancelSchedules(c *gc.C) {
+	schedule, err := s.wordpressUnit.ScheduleAction("fakeaction", nil, time.Time{}, "@hourly")
+	c.Assert(err, jc.ErrorIsNil)
+
+	res, err := s.action.CancelSchedules(params.ActionScheduleIds{
+		Ids: []string{schedule.Id(), "no-such-schedule"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Results, gc.HasLen, 2)
+	c.Assert(res.Results[0].Error, gc.IsNil)
+	c.Assert(res.Results[1].Error, gc.ErrorMatches, `action schedule "no-such-schedule" not found`)
+
+	list, err := s.action.ListSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list.Results, gc.HasLen, 0)
+}
+
+func (s *actionSuite) TestWatchOutput(c *gc.C) {
+	api, err := action.NewActionAPI(s.State, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	running, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	running, err = running.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := api.WatchOutput(params.Entities{Entities: []params.Entity{
+		{Tag: running.ActionTag().String()},
+		{Tag: s.wordpressUnit.Tag().String()},
+		{Tag: names.NewActionTag("feedface-0123-4567-8901-2345deadbeef").String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0], gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, common.ErrBadId.Error())
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `action "feedface-0123-4567-8901-2345deadbeef" not found`)
+
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = running.AppendOutput(state.ActionStdout, "hello\n")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *actionSuite) TestOutput(c *gc.C) {
+	running, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	running, err = running.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	err = running.AppendOutput(state.ActionStdout, "one\n")
+	c.Assert(err, jc.ErrorIsNil)
+	err = running.AppendOutput(state.ActionStderr, "two\n")
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.action.Output(params.ActionOutputQueries{Queries: []params.ActionOutputQuery{
+		{ActionTag: running.ActionTag().String()},
+		{ActionTag: running.ActionTag().String(), From: 1},
+		{ActionTag: pending.ActionTag().String()},
+		{ActionTag: s.wordpressUnit.Tag().String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 4)
+
+	all := results.Results[0]
+	c.Assert(all.Error, gc.IsNil)
+	c.Assert(all.Status, gc.Equals, params.ActionRunning)
+	c.Assert(all.Chunks, gc.HasLen, 2)
+	c.Assert(all.Chunks[0].Seq, gc.Equals, 0)
+	c.Assert(all.Chunks[0].Stream, gc.Equals, params.ActionStdout)
+	c.Assert(all.Chunks[0].Data, gc.Equals, "one\n")
+	c.Assert(all.Chunks[1].Seq, gc.Equals, 1)
+	c.Assert(all.Chunks[1].Stream, gc.Equals, params.ActionStderr)
+	c.Assert(all.Chunks[1].Data, gc.Equals, "two\n")
+
+	rest := results.Results[1]
+	c.Assert(rest.Error, gc.IsNil)
+	c.Assert(rest.Chunks, gc.HasLen, 1)
+	c.Assert(rest.Chunks[0].Data, gc.Equals, "two\n")
+
+	c.Assert(results.Results[2], gc.DeepEquals, params.ActionOutputResult{Status: params.ActionPending})
+	c.Assert(results.Results[3].Error, gc.ErrorMatches, common.ErrBadId.Error())
+}
+
 func (s *actionSuite) TestServicesCharmActions(c *gc.C) {
 	actionSchemas := map[string]map[string]interface{}{
 		"snapshot": {
@@ -665,6 +821,92 @@ func (s *actionSuite) TestServicesCharmActions(c *gc.C) {
 	}
 }
 
+func (s *actionSuite) TestList(c *gc.C) {
+	api, err := action.NewActionAPIV1(s.State, nil, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	wordpressPending, err := s.wordpressUnit.AddAction("fakeaction", map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	mysqlPending, err := s.mysqlUnit.AddAction("fakeaction", map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	wordpressCompleted, err := s.wordpressUnit.AddAction("fakeaction", map[string]interface{}{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = wordpressCompleted.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+
+	list := func(args params.ListActionsArgs) []string {
+		result, err := api.List(rpcreflect.Background(), args)
+		c.Assert(err, jc.ErrorIsNil)
+		tags := make([]string, len(result.Actions))
+		for i, a := range result.Actions {
+			tags[i] = a.Action.Tag
+		}
+		return tags
+	}
+	c.Check(list(params.ListActionsArgs{
+		Services: []string{"mysql"},
+	}), jc.DeepEquals, []string{mysqlPending.ActionTag().String()})
+	c.Check(list(params.ListActionsArgs{
+		Statuses: []string{params.ActionCompleted},
+	}), jc.DeepEquals, []string{wordpressCompleted.ActionTag().String()})
+	c.Check(list(params.ListActionsArgs{
+		Receivers: []string{s.wordpressUnit.Tag().String()},
+		Statuses:  []string{params.ActionPending},
+	}), jc.DeepEquals, []string{wordpressPending.ActionTag().String()})
+	c.Check(list(params.ListActionsArgs{
+		Receivers: []string{s.wordpressUnit.Tag().String()},
+		Services:  []string{"mysql"},
+	}), gc.HasLen, 0)
+
+	sorted := list(params.ListActionsArgs{
+		Page: params.ListPage{SortBy: "receiver"},
+	})
+	c.Assert(sorted, gc.HasLen, 3)
+	c.Check(sorted[0], gc.Equals, mysqlPending.ActionTag().String())
+}
+
+func (s *actionSuite) TestListPages(c *gc.C) {
+	api, err := action.NewActionAPIV1(s.State, nil, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	added := set.NewStrings()
+	for i := 0; i < 3; i++ {
+		a, err := s.wordpressUnit.AddAction("fakeaction", map[string]interface{}{})
+		c.Assert(err, jc.ErrorIsNil)
+		added.Add(a.ActionTag().String())
+	}
+
+	listed := set.NewStrings()
+	args := params.ListActionsArgs{Page: params.ListPage{Limit: 2}}
+	result, err := api.List(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Actions, gc.HasLen, 2)
+	c.Assert(result.Next, gc.Not(gc.Equals), "")
+	for _, a := range result.Actions {
+		listed.Add(a.Action.Tag)
+	}
+
+	args.Page.After = result.Next
+	result, err = api.List(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Actions, gc.HasLen, 1)
+	c.Assert(result.Next, gc.Equals, "")
+	listed.Add(result.Actions[0].Action.Tag)
+	c.Assert(listed.SortedValues(), jc.DeepEquals, added.SortedValues())
+}
+
+func (s *actionSuite) TestListErrors(c *gc.C) {
+	api, err := action.NewActionAPIV1(s.State, nil, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = api.List(rpcreflect.Background(), params.ListActionsArgs{
+		Receivers: []string{"unit-nonexistent-0"},
+	})
+	c.Assert(err, gc.Equals, common.ErrBadId)
+	_, err = api.List(rpcreflect.Background(), params.ListActionsArgs{
+		Page: params.ListPage{SortBy: "bogus"},
+	})
+	c.Assert(err, gc.ErrorMatches, `sort field "bogus" not valid`)
+}
+
 func assertReadyToTest(c *gc.C, receiver state.ActionReceiver) {
 	// make sure there are no actions on the receiver already.
 	actions, err := receiver.Actions()
@@ -0,0 +1,160 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Action", 1, NewActionAPIV1)
+}
+
+// maxListPageSize is the largest number of actions returned by a
+// single call to List.
+var maxListPageSize = 1000
+
+// ActionAPIV1 implements version 1 of the Action facade, which adds
+// paged and filtered listing of actions.
+type ActionAPIV1 struct {
+	ActionAPI
+}
+
+// NewActionAPIV1 returns an initialized ActionAPIV1.
+func NewActionAPIV1(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*ActionAPIV1, error) {
+	api, err := NewActionAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &ActionAPIV1{
+		ActionAPI: *api,
+	}, nil
+}
+
+// sortTime formats t so that times sort in order as strings.
+func sortTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000")
+}
+
+// actionSortKeys maps the fields actions may be sorted by to functions
+// returning each action's value for that field. Sorting by ID needs no
+// key, since entries are ordered by ID anyway.
+var actionSortKeys = map[string]func(params.ActionResult) string{
+	"":          func(params.ActionResult) string { return "" },
+	"id":        func(params.ActionResult) string { return "" },
+	"name":      func(a params.ActionResult) string { return a.Action.Name },
+	"receiver":  func(a params.ActionResult) string { return a.Action.Receiver },
+	"status":    func(a params.ActionResult) string { return a.Status },
+	"enqueued":  func(a params.ActionResult) string { return sortTime(a.Enqueued) },
+	"started":   func(a params.ActionResult) string { return sortTime(a.Started) },
+	"completed": func(a params.ActionResult) string { return sortTime(a.Completed) },
+}
+
+// List returns a page of the actions queued for units in the
+// environment that match the given filters.
+func (a *ActionAPIV1) List(ctx rpcreflect.Context, args params.ListActionsArgs) (params.ListActionsResult, error) {
+	sortKey, ok := actionSortKeys[args.Page.SortBy]
+	if !ok {
+		return params.ListActionsResult{}, errors.NotValidf("sort field %q", args.Page.SortBy)
+	}
+	receivers, err := a.listReceivers(args.Receivers, args.Services)
+	if err != nil {
+		return params.ListActionsResult{}, err
+	}
+	statuses := set.NewStrings(args.Statuses...)
+	var actions []params.ActionResult
+	var ids []string
+	for _, receiver := range receivers {
+		if err := ctx.Err(); err != nil {
+			return params.ListActionsResult{}, err
+		}
+		receiverActions, err := receiver.Actions()
+		if err != nil {
+			return params.ListActionsResult{}, errors.Trace(err)
+		}
+		for _, action := range receiverActions {
+			if !statuses.IsEmpty() && !statuses.Contains(string(action.Status())) {
+				continue
+			}
+			actions = append(actions, makeActionResult(receiver.Tag(), action))
+			ids = append(ids, action.Id())
+		}
+	}
+
+	entries := make([]common.PageEntry, len(actions))
+	for i, action := range actions {
+		entries[i] = common.PageEntry{Id: ids[i], SortKey: sortKey(action)}
+	}
+	page, next, err := common.Paginate(entries, args.Page, maxListPageSize)
+	if err != nil {
+		return params.ListActionsResult{}, err
+	}
+	result := params.ListActionsResult{
+		Actions: make([]params.ActionResult, len(page)),
+		Next:    next,
+	}
+	for i, index := range page {
+		result.Actions[i] = actions[index]
+	}
+	return result, nil
+}
+
+// listReceivers returns the action receivers identified by the given
+// tags, or all the units in the environment if there are none,
+// excluding units of services not named in services if it is not
+// empty.
+func (a *ActionAPIV1) listReceivers(tags, services []string) ([]state.ActionReceiver, error) {
+	serviceNames := set.NewStrings(services...)
+	inServices := func(tag names.Tag) bool {
+		if serviceNames.IsEmpty() {
+			return true
+		}
+		unitTag, ok := tag.(names.UnitTag)
+		if !ok {
+			return false
+		}
+		serviceName, err := names.UnitService(unitTag.Id())
+		return err == nil && serviceNames.Contains(serviceName)
+	}
+
+	var receivers []state.ActionReceiver
+	if len(tags) > 0 {
+		for _, tag := range tags {
+			receiver, err := tagToActionReceiver(a.state, tag)
+			if err != nil {
+				return nil, err
+			}
+			if inServices(receiver.Tag()) {
+				receivers = append(receivers, receiver)
+			}
+		}
+		return receivers, nil
+	}
+	allServices, err := a.state.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, service := range allServices {
+		if !serviceNames.IsEmpty() && !serviceNames.Contains(service.Name()) {
+			continue
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			receivers = append(receivers, unit)
+		}
+	}
+	return receivers, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package actionscheduler provides the API used by state server
+// agents to queue scheduled actions when they fall due.
+package actionscheduler
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.actionscheduler")
+
+func init() {
+	common.RegisterStandardFacade("ActionScheduler", 1, NewActionSchedulerAPI)
+}
+
+// ActionSchedulerAPI implements the ActionScheduler facade.
+type ActionSchedulerAPI struct {
+	st        *state.State
+	resources *common.Resources
+}
+
+// NewActionSchedulerAPI creates a new server-side ActionScheduler
+// facade.
+func NewActionSchedulerAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*ActionSchedulerAPI, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &ActionSchedulerAPI{
+		st:        st,
+		resources: resources,
+	}, nil
+}
+
+// WatchActionSchedules returns a NotifyWatcher which notifies when
+// action schedules are added, dispatched or cancelled.
+func (api *ActionSchedulerAPI) WatchActionSchedules() (params.NotifyWatchResult, error) {
+	result := params.NotifyWatchResult{}
+	watch := api.st.WatchActionSchedules()
+	if _, ok := <-watch.Changes(); ok {
+		result.NotifyWatcherId = api.resources.Register(watch)
+	} else {
+		return result, watcher.EnsureErr(watch)
+	}
+	return result, nil
+}
+
+// DispatchDue queues the actions of all the schedules which are due,
+// and returns the time at which the next schedule falls due. Schedules
+// whose receivers have died are cancelled.
+func (api *ActionSchedulerAPI) DispatchDue() (params.ActionScheduleDispatchResult, error) {
+	result := params.ActionScheduleDispatchResult{}
+	now := time.Now()
+	due, err := api.st.DueActionSchedules(now)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for _, schedule := range due {
+		action, err := schedule.Dispatch(now)
+		switch {
+		case err == nil:
+			logger.Debugf("queued action %q for %q from schedule %q", action.Id(), action.Receiver(), schedule.Id())
+			result.Actions = append(result.Actions, params.Entity{Tag: action.Tag().String()})
+		case err == state.ErrDead:
+			logger.Infof("cancelling action schedule %q: %q is dead", schedule.Id(), schedule.Receiver())
+			if err := schedule.Cancel(); err != nil && !errors.IsNotFound(err) {
+				return result, errors.Trace(err)
+			}
+		case errors.IsNotFound(err):
+			// Cancelled since it was found to be due.
+		default:
+			logger.Errorf("cannot dispatch action schedule %q: %v", schedule.Id(), err)
+		}
+	}
+	result.NextRun, err = api.st.NextActionScheduleRun()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	return result, nil
+}
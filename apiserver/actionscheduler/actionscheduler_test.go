@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/actionscheduler"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type actionSchedulerSuite struct {
+	jujutesting.JujuConnSuite
+
+	api       *actionscheduler.ActionSchedulerAPI
+	resources *common.Resources
+	unit      *state.Unit
+}
+
+var _ = gc.Suite(&actionSchedulerSuite{})
+
+func (s *actionSchedulerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	var err error
+	s.api, err = actionscheduler.NewActionSchedulerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	ch := s.AddTestingCharm(c, "dummy")
+	svc := s.AddTestingService(c, "dummy", ch)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetCharmURL(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *actionSchedulerSuite) TestNewAPIRefusesNonManager(c *gc.C) {
+	_, err := actionscheduler.NewActionSchedulerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = actionscheduler.NewActionSchedulerAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *actionSchedulerSuite) TestWatchActionSchedules(c *gc.C) {
+	result, err := s.api.WatchActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	w := s.resources.Get("1").(state.NotifyWatcher)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertNoChange()
+
+	_, err = s.unit.ScheduleAction("snapshot", nil, time.Time{}, "@daily")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *actionSchedulerSuite) TestDispatchDue(c *gc.C) {
+	due, err := s.unit.ScheduleAction("snapshot", nil, time.Now().Add(-time.Minute), "")
+	c.Assert(err, jc.ErrorIsNil)
+	later, err := s.unit.ScheduleAction("snapshot", nil, time.Now().Add(time.Hour), "")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.DispatchDue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.NextRun.Equal(later.NextRun()), jc.IsTrue)
+
+	pending, err := s.unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	c.Assert(result.Actions, jc.DeepEquals, []params.Entity{{Tag: pending[0].Tag().String()}})
+
+	err = due.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *actionSchedulerSuite) TestDispatchDueNoSchedules(c *gc.C) {
+	result, err := s.api.DispatchDue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Actions, gc.HasLen, 0)
+	c.Assert(result.NextRun.IsZero(), jc.IsTrue)
+}
+
+func (s *actionSchedulerSuite) TestDispatchDueCancelsDeadReceivers(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Now().Add(-time.Minute), "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.DispatchDue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Actions, gc.HasLen, 0)
+	c.Assert(result.NextRun.IsZero(), jc.IsTrue)
+
+	err = schedule.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
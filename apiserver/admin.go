@@ -106,6 +106,8 @@ func (a *admin) doLogin(req params.LoginRequest) (params.LoginResultV1, error) {
 
 	// authedApi is the API method finder we'll use after getting logged in.
 	var authedApi rpc.MethodFinder = newApiRoot(a.root.state, a.root.closeState, a.root.resources, a.root)
+	authedApi = newSuspendableRoot(authedApi, a.root.state)
+	authedApi = newBlockingRoot(authedApi, a.root.state)
 
 	// Use the login validation function, if one was specified.
 	if a.srv.validator != nil {
@@ -164,9 +166,9 @@ func (a *admin) doLogin(req params.LoginRequest) (params.LoginResultV1, error) {
 			return fail, err
 		}
 		// If we are here, then the entity will refer to a state server
-		// machine in the state server environment, and we don't need a pinger
-		// for it as we already have one running in the machine agent api
-		// worker for the state server environment.
+		// machine in the state server environment, and we don't need a
+		// heartbeat for it as its connection to the state server
+		// environment already records one.
 		agentPingerNeeded = false
 	}
 	a.root.entity = entity
@@ -180,7 +182,7 @@ func (a *admin) doLogin(req params.LoginRequest) (params.LoginResultV1, error) {
 	a.loggedIn = true
 
 	if agentPingerNeeded {
-		if err := startPingerIfAgent(a.root, entity); err != nil {
+		if err := startHeartbeatIfAgent(a.srv, a.root, a.reqNotifier, entity); err != nil {
 			return fail, err
 		}
 	}
@@ -206,6 +208,10 @@ func (a *admin) doLogin(req params.LoginRequest) (params.LoginResultV1, error) {
 	if err != nil {
 		return fail, err
 	}
+	environConfig, err := a.root.state.EnvironConfig()
+	if err != nil {
+		return fail, err
+	}
 
 	a.root.rpcConn.ServeFinder(authedApi, serverError)
 
@@ -213,7 +219,7 @@ func (a *admin) doLogin(req params.LoginRequest) (params.LoginResultV1, error) {
 		Servers:    params.FromNetworkHostsPorts(hostPorts),
 		EnvironTag: environ.Tag().String(),
 		ServerTag:  environ.ServerTag().String(),
-		Facades:    DescribeFacades(),
+		Facades:    DescribeFacades(environConfig.Features()),
 		UserInfo:   maybeUserInfo,
 	}, nil
 }
@@ -322,36 +328,26 @@ func checkForValidMachineAgent(entity state.Entity, req params.LoginRequest) err
 	return nil
 }
 
-// machinePinger wraps a presence.Pinger.
-type machinePinger struct {
-	*presence.Pinger
-}
-
-// Stop implements Pinger.Stop() as Pinger.Kill(), needed at
-// connection closing time to properly stop the wrapped pinger.
-func (p *machinePinger) Stop() error {
-	if err := p.Pinger.Stop(); err != nil {
-		return err
-	}
-	return p.Pinger.Kill()
-}
-
-func startPingerIfAgent(root *apiHandler, entity state.Entity) error {
-	// A machine or unit agent has connected, so start a pinger to
-	// announce it's now alive, and set up the API pinger
-	// so that the connection will be terminated if a sufficient
-	// interval passes between pings.
-	agentPresencer, ok := entity.(presence.Presencer)
-	if !ok {
+func startHeartbeatIfAgent(srv *Server, root *apiHandler, reqNotifier *requestNotifier, entity state.Entity) error {
+	// A machine or unit agent has connected, so record its
+	// heartbeat to announce it's now alive, and set up the API
+	// pinger so that the connection will be terminated if a
+	// sufficient interval passes between pings.
+	if _, ok := entity.(presence.Presencer); !ok {
 		return nil
 	}
 
-	pinger, err := agentPresencer.SetAgentPresence()
+	err := srv.heartbeats.add(reqNotifier, root.state.EnvironUUID(), entity.Tag())
+	if err != nil {
+		return err
+	}
+	err = root.getResources().RegisterNamed("heartbeat", &agentHeartbeat{
+		heartbeats: srv.heartbeats,
+		id:         reqNotifier.id,
+	})
 	if err != nil {
 		return err
 	}
-
-	root.getResources().Register(&machinePinger{pinger})
 	action := func() {
 		if err := root.getRpcConn().Close(); err != nil {
 			logger.Errorf("error closing the RPC connection: %v", err)
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+// AgentAPIV2 implements the version 2 of the API provided to an agent.
+type AgentAPIV2 struct {
+	*AgentAPIV1
+
+	resources *common.Resources
+}
+
+// NewAgentAPIV2 returns an object implementing version 2 of the Agent API
+// with the given authorizer representing the currently logged in client.
+// The functionality is like V1, except that it also allows agents to
+// watch and fetch the environment settings they record in their
+// configuration files.
+func NewAgentAPIV2(st *state.State, resources *common.Resources, auth common.Authorizer) (*AgentAPIV2, error) {
+	apiV1, err := NewAgentAPIV1(st, resources, auth)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &AgentAPIV2{
+		AgentAPIV1: apiV1,
+		resources:  resources,
+	}, nil
+}
+
+// WatchAgentConfigSettings returns a NotifyWatcher that notifies
+// when the settings returned by AgentConfigSettings may have changed.
+func (api *AgentAPIV2) WatchAgentConfigSettings() (params.NotifyWatchResult, error) {
+	w := api.st.WatchAgentConfigSettings()
+	// Consume the initial event. Technically, API
+	// calls to Watch 'transmit' the initial event
+	// in the Watch response. But NotifyWatchers
+	// have no state to transmit.
+	if _, ok := <-w.Changes(); ok {
+		return params.NotifyWatchResult{
+			NotifyWatcherId: api.resources.Register(w),
+		}, nil
+	}
+	return params.NotifyWatchResult{}, watcher.EnsureErr(w)
+}
+
+// AgentConfigSettings returns the current values of the environment
+// settings an agent keeps in its configuration file.
+func (api *AgentAPIV2) AgentConfigSettings() (params.AgentConfigSettings, error) {
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return params.AgentConfigSettings{}, errors.Trace(err)
+	}
+	return params.AgentConfigSettings{
+		LoggingConfig: envConfig.LoggingConfig(),
+		Proxy:         envConfig.ProxySettings(),
+	}, nil
+}
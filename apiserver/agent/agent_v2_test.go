@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agent_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/agent"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+func factoryWrapperV2(st *state.State, resources *common.Resources, auth common.Authorizer) (interface{}, error) {
+	return agent.NewAgentAPIV2(st, resources, auth)
+}
+
+type agentSuiteV2 struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&agentSuiteV2{})
+
+func (s *agentSuiteV2) TestAgentFailsWithNonAgent(c *gc.C) {
+	s.testAgentFailsWithNonAgentV0(c, factoryWrapperV2)
+}
+
+func (s *agentSuiteV2) TestAgentSucceedsWithUnitAgent(c *gc.C) {
+	s.testAgentSucceedsWithUnitAgentV0(c, factoryWrapperV2)
+}
+
+func (s *agentSuiteV2) TestGetEntities(c *gc.C) {
+	s.testGetEntitiesV0(c, s.newAPI(c))
+}
+
+func (s *agentSuiteV2) TestSetPasswords(c *gc.C) {
+	s.testSetPasswordsV0(c, s.newAPI(c))
+}
+
+func (s *agentSuiteV2) TestAgentConfigSettings(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+		"http-proxy":     "http://proxy.example.com",
+		"no-proxy":       "localhost",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	settings, err := s.newAPI(c).AgentConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, params.AgentConfigSettings{
+		LoggingConfig: "<root>=DEBUG",
+		Proxy: proxy.Settings{
+			Http:    "http://proxy.example.com",
+			NoProxy: "localhost",
+		},
+	})
+}
+
+func (s *agentSuiteV2) TestWatchAgentConfigSettings(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	result, err := s.newAPI(c).WatchAgentConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	// Verify the resource was registered and stop when done.
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *agentSuiteV2) newAPI(c *gc.C) *agent.AgentAPIV2 {
+	api, err := agent.NewAgentAPIV2(s.State, s.resources, s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
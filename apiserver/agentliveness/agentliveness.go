@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package agentliveness implements the API used to find out whether
+// machine and unit agents are connected, and when they were last
+// heard from.
+package agentliveness
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("AgentLiveness", 1, NewAPI)
+}
+
+// agent is implemented by state.Machine and state.Unit.
+type agent interface {
+	AgentPresence() (bool, error)
+	AgentLastSeen() (time.Time, error)
+}
+
+// API implements the AgentLiveness facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new AgentLiveness API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// AgentLiveness returns whether the agents of the given machines and
+// units are connected to an API server, and when they were last
+// heard from.
+func (api *API) AgentLiveness(args params.Entities) (params.AgentLivenessResults, error) {
+	results := params.AgentLivenessResults{
+		Results: make([]params.AgentLivenessResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		result, err := api.agentLiveness(entity.Tag)
+		if err != nil {
+			result.Error = common.ServerError(err)
+		}
+		results.Results[i] = result
+	}
+	return results, nil
+}
+
+func (api *API) agentLiveness(tagString string) (params.AgentLivenessResult, error) {
+	var result params.AgentLivenessResult
+	tag, err := names.ParseTag(tagString)
+	if err != nil {
+		return result, err
+	}
+	switch tag.(type) {
+	case names.MachineTag, names.UnitTag:
+	default:
+		return result, errors.NotValidf("agent tag %q", tagString)
+	}
+	entity, err := api.st.FindEntity(tag)
+	if err != nil {
+		return result, err
+	}
+	a, ok := entity.(agent)
+	if !ok {
+		return result, errors.NotValidf("agent tag %q", tagString)
+	}
+	result.Alive, err = a.AgentPresence()
+	if err != nil {
+		return result, err
+	}
+	lastSeen, err := a.AgentLastSeen()
+	if err == nil {
+		result.LastSeen = &lastSeen
+	} else if !errors.IsNotFound(err) {
+		return result, err
+	}
+	return result, nil
+}
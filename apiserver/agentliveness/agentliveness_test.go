@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agentliveness_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/agentliveness"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type agentLivenessSuite struct {
+	jujutesting.JujuConnSuite
+	api *agentliveness.API
+}
+
+var _ = gc.Suite(&agentLivenessSuite{})
+
+func (s *agentLivenessSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = agentliveness.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *agentLivenessSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := agentliveness.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *agentLivenessSuite) TestAgentLiveness(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	unit := s.Factory.MakeUnit(c, nil)
+	lastSeen := time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+	err := s.State.SetAgentHeartbeats("0:17070", []state.AgentHeartbeat{{
+		EnvUUID:   s.State.EnvironUUID(),
+		Agent:     machine.Tag(),
+		LastSeen:  lastSeen,
+		Connected: true,
+	}, {
+		EnvUUID:   s.State.EnvironUUID(),
+		Agent:     unit.Tag(),
+		LastSeen:  lastSeen,
+		Connected: false,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	other := s.Factory.MakeMachine(c, nil)
+
+	results, err := s.api.AgentLiveness(params.Entities{Entities: []params.Entity{
+		{Tag: machine.Tag().String()},
+		{Tag: unit.Tag().String()},
+		{Tag: other.Tag().String()},
+		{Tag: "machine-42"},
+		{Tag: "service-wordpress"},
+		{Tag: "foo"},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 6)
+	c.Check(results.Results[0].Error, gc.IsNil)
+	c.Check(results.Results[0].Alive, jc.IsTrue)
+	c.Assert(results.Results[0].LastSeen, gc.NotNil)
+	c.Check(results.Results[0].LastSeen.Equal(lastSeen), jc.IsTrue)
+	c.Check(results.Results[1].Error, gc.IsNil)
+	c.Check(results.Results[1].Alive, jc.IsFalse)
+	c.Assert(results.Results[1].LastSeen, gc.NotNil)
+	c.Check(results.Results[1].LastSeen.Equal(lastSeen), jc.IsTrue)
+	c.Check(results.Results[2], jc.DeepEquals, params.AgentLivenessResult{})
+	c.Check(results.Results[3].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Check(results.Results[4].Error, gc.ErrorMatches, `agent tag "service-wordpress" not valid`)
+	c.Check(results.Results[5].Error, gc.ErrorMatches, `"foo" is not a valid tag`)
+}
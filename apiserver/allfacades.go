@@ -8,33 +8,59 @@ package apiserver
 // function will get called to register it.
 import (
 	_ "github.com/juju/juju/apiserver/action"
+	_ "github.com/juju/juju/apiserver/actionscheduler"
 	_ "github.com/juju/juju/apiserver/agent"
+	_ "github.com/juju/juju/apiserver/agentliveness"
 	_ "github.com/juju/juju/apiserver/annotations"
 	_ "github.com/juju/juju/apiserver/backups"
 	_ "github.com/juju/juju/apiserver/block"
+	_ "github.com/juju/juju/apiserver/capabilities"
+	_ "github.com/juju/juju/apiserver/certificates"
+	_ "github.com/juju/juju/apiserver/charmrevisions"
 	_ "github.com/juju/juju/apiserver/charmrevisionupdater"
 	_ "github.com/juju/juju/apiserver/charms"
+	_ "github.com/juju/juju/apiserver/charmstorage"
 	_ "github.com/juju/juju/apiserver/client"
+	_ "github.com/juju/juju/apiserver/clouds"
+	_ "github.com/juju/juju/apiserver/configschema"
+	_ "github.com/juju/juju/apiserver/connections"
+	_ "github.com/juju/juju/apiserver/credentialvalidator"
 	_ "github.com/juju/juju/apiserver/deployer"
 	_ "github.com/juju/juju/apiserver/diskformatter"
 	_ "github.com/juju/juju/apiserver/diskmanager"
 	_ "github.com/juju/juju/apiserver/environment"
 	_ "github.com/juju/juju/apiserver/environmentmanager"
 	_ "github.com/juju/juju/apiserver/firewaller"
+	_ "github.com/juju/juju/apiserver/hostfirewaller"
 	_ "github.com/juju/juju/apiserver/imagemanager"
+	_ "github.com/juju/juju/apiserver/instancemetadata"
 	_ "github.com/juju/juju/apiserver/keymanager"
 	_ "github.com/juju/juju/apiserver/keyupdater"
+	_ "github.com/juju/juju/apiserver/logforwarding"
 	_ "github.com/juju/juju/apiserver/logger"
 	_ "github.com/juju/juju/apiserver/machine"
+	_ "github.com/juju/juju/apiserver/maintenancewindow"
+	_ "github.com/juju/juju/apiserver/meterstatus"
 	_ "github.com/juju/juju/apiserver/metricsmanager"
+	_ "github.com/juju/juju/apiserver/mongomanager"
 	_ "github.com/juju/juju/apiserver/networker"
+	_ "github.com/juju/juju/apiserver/osupdates"
 	_ "github.com/juju/juju/apiserver/provisioner"
+	_ "github.com/juju/juju/apiserver/proxyupdater"
+	_ "github.com/juju/juju/apiserver/raftlease"
 	_ "github.com/juju/juju/apiserver/reboot"
+	_ "github.com/juju/juju/apiserver/rebootrequests"
 	_ "github.com/juju/juju/apiserver/rsyslog"
 	_ "github.com/juju/juju/apiserver/service"
+	_ "github.com/juju/juju/apiserver/stability"
+	_ "github.com/juju/juju/apiserver/statushistory"
 	_ "github.com/juju/juju/apiserver/storage"
 	_ "github.com/juju/juju/apiserver/storageprovisioner"
+	_ "github.com/juju/juju/apiserver/subnets"
+	_ "github.com/juju/juju/apiserver/unitdrain"
 	_ "github.com/juju/juju/apiserver/uniter"
 	_ "github.com/juju/juju/apiserver/upgrader"
+	_ "github.com/juju/juju/apiserver/upgradeseries"
 	_ "github.com/juju/juju/apiserver/usermanager"
+	_ "github.com/juju/juju/apiserver/waitfor"
 )
@@ -14,6 +14,7 @@ import (
 
 func init() {
 	common.RegisterStandardFacade("Annotations", 1, NewAPI)
+	common.RegisterStandardFacade("Annotations", 2, NewAPIV2)
 }
 
 var getState = func(st *state.State) annotationAccess {
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package annotations
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+// APIV2 implements version 2 of the annotations API end point.
+type APIV2 struct {
+	*API
+
+	resources *common.Resources
+}
+
+// NewAPIV2 returns a new annotations API facade. The functionality is
+// like version 1, except that Set reports a result for every entity,
+// and that clients can watch for changes to annotations.
+func NewAPIV2(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*APIV2, error) {
+	api, err := NewAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIV2{
+		API:       api,
+		resources: resources,
+	}, nil
+}
+
+// Set stores annotations for the given entities. Unlike version 1, the
+// results correspond one to one with the entities given, so clients
+// setting annotations on many entities can tell which ones failed.
+func (api *APIV2) Set(args params.AnnotationsSet) params.ErrorResults {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Annotations)),
+	}
+	for i, entityAnnotation := range args.Annotations {
+		err := api.setEntityAnnotations(entityAnnotation.EntityTag, entityAnnotation.Annotations)
+		if err != nil {
+			results.Results[i].Error = annotateError(err, entityAnnotation.EntityTag, "setting")
+		}
+	}
+	return results
+}
+
+// WatchAnnotations returns a NotifyWatcher for each given entity,
+// which notifies when the entity's annotations change.
+func (api *APIV2) WatchAnnotations(args params.Entities) (params.NotifyWatchResults, error) {
+	results := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	for i, arg := range args.Entities {
+		id, err := api.watchEntityAnnotations(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].NotifyWatcherId = id
+	}
+	return results, nil
+}
+
+func (api *APIV2) watchEntityAnnotations(entityTag string) (string, error) {
+	tag, err := names.ParseTag(entityTag)
+	if err != nil {
+		return "", common.ErrPerm
+	}
+	entity, err := api.findEntity(tag)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	w := api.access.WatchAnnotations(entity)
+	// Consume the initial event.
+	if _, ok := <-w.Changes(); ok {
+		return api.resources.Register(w), nil
+	}
+	return "", watcher.EnsureErr(w)
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package annotations_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/annotations"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type annotationV2Suite struct {
+	jujutesting.JujuConnSuite
+
+	annotationsApi *annotations.APIV2
+	resources      *common.Resources
+}
+
+var _ = gc.Suite(&annotationV2Suite{})
+
+func (s *annotationV2Suite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	var err error
+	s.annotationsApi, err = annotations.NewAPIV2(s.State, s.resources, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *annotationV2Suite) TestSetReportsEveryEntity(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{Machine: machine})
+
+	result := s.annotationsApi.Set(params.AnnotationsSet{Annotations: []params.EntityAnnotations{{
+		EntityTag:   machine.Tag().String(),
+		Annotations: map[string]string{"gui/x": "10"},
+	}, {
+		EntityTag:   "machine-42",
+		Annotations: map[string]string{"gui/x": "10"},
+	}, {
+		EntityTag:   unit.Tag().String(),
+		Annotations: map[string]string{"gui/": "10"},
+	}, {
+		EntityTag:   unit.Tag().String(),
+		Annotations: map[string]string{"gui/y": "20"},
+	}}})
+	c.Assert(result.Results, gc.HasLen, 4)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, ".*permission denied")
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `.*invalid key "gui/": .*`)
+	c.Assert(result.Results[3].Error, gc.IsNil)
+
+	got := s.annotationsApi.Get(params.Entities{Entities: []params.Entity{
+		{Tag: machine.Tag().String()},
+		{Tag: unit.Tag().String()},
+	}})
+	c.Assert(got.Results, gc.HasLen, 2)
+	c.Assert(got.Results[0].Annotations, jc.DeepEquals, map[string]string{"gui/x": "10"})
+	c.Assert(got.Results[1].Annotations, jc.DeepEquals, map[string]string{"gui/y": "20"})
+}
+
+func (s *annotationV2Suite) TestWatchAnnotations(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	results, err := s.annotationsApi.WatchAnnotations(params.Entities{Entities: []params.Entity{
+		{Tag: machine.Tag().String()},
+		{Tag: "machine-42"},
+		{Tag: "invalid"},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{NotifyWatcherId: "1"},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Verify the resource was registered and stop when done.
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+
+	// Check that the Watch has consumed the initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.State.SetAnnotations(machine, map[string]string{"gui/x": "10"})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
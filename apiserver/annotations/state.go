@@ -13,6 +13,7 @@ type annotationAccess interface {
 	FindEntity(tag names.Tag) (state.Entity, error)
 	GetAnnotations(entity state.GlobalEntity) (map[string]string, error)
 	SetAnnotations(entity state.GlobalEntity, annotations map[string]string) error
+	WatchAnnotations(entity state.GlobalEntity) state.NotifyWatcher
 }
 
 type stateShim struct {
@@ -30,3 +31,7 @@ func (s stateShim) GetAnnotations(entity state.GlobalEntity) (map[string]string,
 func (s stateShim) SetAnnotations(entity state.GlobalEntity, annotations map[string]string) error {
 	return s.state.SetAnnotations(entity, annotations)
 }
+
+func (s stateShim) WatchAnnotations(entity state.GlobalEntity) state.NotifyWatcher {
+	return s.state.WatchAnnotations(entity)
+}
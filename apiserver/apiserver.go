@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"net"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -46,6 +47,12 @@ type Server struct {
 	limiter           utils.Limiter
 	validator         LoginValidator
 	adminApiFactories map[int]adminApiFactory
+	connections       *connectionTracker
+	heartbeats        *agentHeartbeats
+	metrics           *serverMetrics
+	statusCache       *statusCache
+	uploadLimits      UploadLimits
+	debugHooks        *debugHooksBroker
 
 	mu          sync.Mutex // protects the fields that follow
 	environUUID string
@@ -65,47 +72,45 @@ type ServerConfig struct {
 	LogDir      string
 	Validator   LoginValidator
 	CertChanged chan params.StateServingInfo
+
+	// UploadLimits holds the maximum sizes of charm, tools
+	// and backup uploads. Unset limits take their defaults.
+	UploadLimits UploadLimits
 }
 
 // changeCertListener wraps a TLS net.Listener.
-// It allows connection handshakes to be
-// blocked while the TLS certificate is updated.
+// It allows the TLS certificate served by the listener
+// to be replaced without interrupting connections that
+// have already been established.
 type changeCertListener struct {
 	net.Listener
 	tomb tomb.Tomb
 
-	// A mutex used to block accept operations.
-	m sync.Mutex
+	// A mutex used to protect the current certificate.
+	m sync.RWMutex
 
 	// A channel used to pass in new certificate information.
 	certChanged <-chan params.StateServingInfo
 
-	// The config to update with any new certificate.
-	config *tls.Config
-}
-
-// changeCertConn wraps a TLS net.Conn.
-// It allows connection handshakes to be
-// blocked while the TLS certificate is updated.
-type changeCertConn struct {
-	net.Conn
-	m *sync.Mutex
-}
-
-// Handshake runs the client or server handshake
-// protocol if it has not yet been run.
-func (c *changeCertConn) Handshake() error {
-	c.m.Lock()
-	defer c.m.Unlock()
-	return c.Conn.(*tls.Conn).Handshake()
+	// cert holds the certificate presented during
+	// TLS handshakes.
+	cert *tls.Certificate
 }
 
-func newChangeCertListener(tlsListener net.Listener, certChanged <-chan params.StateServingInfo, config *tls.Config) *changeCertListener {
+func newChangeCertListener(lis net.Listener, certChanged <-chan params.StateServingInfo, cert tls.Certificate) *changeCertListener {
 	cl := &changeCertListener{
-		Listener:    tlsListener,
 		certChanged: certChanged,
-		config:      config,
+		cert:        &cert,
 	}
+	// The certificate is looked up on every handshake, so
+	// updating it takes effect for all new connections.
+	// Client certificates are requested but not required; they
+	// are only used to authorize access to /metrics.
+	config := &tls.Config{
+		GetCertificate: cl.getCertificate,
+		ClientAuth:     tls.RequestClientCert,
+	}
+	cl.Listener = tls.NewListener(lis, config)
 	go func() {
 		defer cl.tomb.Done()
 		cl.tomb.Kill(cl.processCertChanges())
@@ -113,15 +118,12 @@ func newChangeCertListener(tlsListener net.Listener, certChanged <-chan params.S
 	return cl
 }
 
-// Accept waits for and returns the next connection to the listener.
-func (cl *changeCertListener) Accept() (c net.Conn, err error) {
-	if c, err = cl.Listener.Accept(); err != nil {
-		return c, err
-	}
-	// Create a wrapped connection so we can
-	// control the handshakes.
-	conn := changeCertConn{c, &cl.m}
-	return conn, err
+// getCertificate returns the current certificate. It is
+// used as the tls.Config GetCertificate callback.
+func (cl *changeCertListener) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cl.m.RLock()
+	defer cl.m.RUnlock()
+	return cl.cert, nil
 }
 
 // Close closes the listener.
@@ -135,7 +137,13 @@ func (cl *changeCertListener) Close() error {
 func (cl *changeCertListener) processCertChanges() error {
 	for {
 		select {
-		case info := <-cl.certChanged:
+		case info, ok := <-cl.certChanged:
+			if !ok {
+				// No more certificate changes will be
+				// sent; keep serving the current one.
+				cl.certChanged = nil
+				continue
+			}
 			if info.Cert != "" {
 				cl.updateCertificate([]byte(info.Cert), []byte(info.PrivateKey))
 			}
@@ -143,28 +151,28 @@ func (cl *changeCertListener) processCertChanges() error {
 			return tomb.ErrDying
 		}
 	}
-	return nil
 }
 
 // updateCertificate generates a new TLS certificate and assigns it
 // to the TLS listener.
 func (cl *changeCertListener) updateCertificate(cert, key []byte) {
-	cl.m.Lock()
-	defer cl.m.Unlock()
-	if tlsCert, err := tls.X509KeyPair(cert, key); err != nil {
+	tlsCert, err := tls.X509KeyPair(cert, key)
+	if err != nil {
 		logger.Errorf("cannot create new TLS certificate: %v", err)
-	} else {
-		logger.Infof("updating api server certificate")
-		x509Cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
-		if err == nil {
-			var addr []string
-			for _, ip := range x509Cert.IPAddresses {
-				addr = append(addr, ip.String())
-			}
-			logger.Infof("new certificate addresses: %v", strings.Join(addr, ", "))
+		return
+	}
+	logger.Infof("updating api server certificate")
+	x509Cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err == nil {
+		var addr []string
+		for _, ip := range x509Cert.IPAddresses {
+			addr = append(addr, ip.String())
 		}
-		cl.config.Certificates = []tls.Certificate{tlsCert}
+		logger.Infof("new certificate addresses: %v", strings.Join(addr, ", "))
 	}
+	cl.m.Lock()
+	defer cl.m.Unlock()
+	cl.cert = &tlsCert
 }
 
 // NewServer serves the given state by accepting requests on the given
@@ -192,14 +200,18 @@ func NewServer(s *state.State, lis net.Listener, cfg ServerConfig) (*Server, err
 			0: newAdminApiV0,
 			1: newAdminApiV1,
 		},
+		connections:  newConnectionTracker(),
+		metrics:      newServerMetrics(),
+		uploadLimits: cfg.UploadLimits.withDefaults(),
+		debugHooks:   newDebugHooksBroker(),
 	}
+	srv.statusCache = newStatusCache(s, srv.tomb.Dying(), &srv.wg)
+	// Each API server records the agents connected to it in its own
+	// heartbeat document.
+	srv.heartbeats = newAgentHeartbeats(s, net.JoinHostPort(cfg.Tag.Id(), listeningPort))
 	// TODO(rog) check that *srvRoot is a valid type for using
 	// as an RPC server.
-	tlsConfig := tls.Config{
-		Certificates: []tls.Certificate{tlsCert},
-	}
-	tlsListener := tls.NewListener(lis, &tlsConfig)
-	changeCertListener := newChangeCertListener(tlsListener, cfg.CertChanged, &tlsConfig)
+	changeCertListener := newChangeCertListener(lis, cfg.CertChanged, tlsCert)
 	go srv.run(changeCertListener)
 	return srv, nil
 }
@@ -230,17 +242,28 @@ type requestNotifier struct {
 	id    int64
 	start time.Time
 
-	mu   sync.Mutex
-	tag_ string
+	mu           sync.Mutex
+	tag_         string
+	remoteAddr   string
+	lastActivity time.Time
+	facadeCalls  map[string]int
+
+	// metrics, if not nil, records the requests served
+	// for reporting at /metrics.
+	metrics *serverMetrics
 }
 
 var globalCounter int64
 
-func newRequestNotifier() *requestNotifier {
+func newRequestNotifier(metrics *serverMetrics) *requestNotifier {
+	now := time.Now()
 	return &requestNotifier{
-		id:    atomic.AddInt64(&globalCounter, 1),
-		tag_:  "<unknown>",
-		start: time.Now(),
+		id:           atomic.AddInt64(&globalCounter, 1),
+		tag_:         "<unknown>",
+		start:        now,
+		lastActivity: now,
+		facadeCalls:  make(map[string]int),
+		metrics:      metrics,
 	}
 }
 
@@ -257,10 +280,50 @@ func (n *requestNotifier) tag() (tag string) {
 	return
 }
 
+// recordRequest notes that a request has been made on the
+// given facade, for reporting connection activity.
+func (n *requestNotifier) recordRequest(facade string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lastActivity = time.Now()
+	n.facadeCalls[facade]++
+}
+
+// lastActive returns the time of the most recent request made on
+// the connection, or of the connection itself if none have been
+// made.
+func (n *requestNotifier) lastActive() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lastActivity
+}
+
+// details returns a description of the connection's activity.
+func (n *requestNotifier) details() params.ConnectionDetails {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	calls := make(map[string]int, len(n.facadeCalls))
+	for facade, count := range n.facadeCalls {
+		calls[facade] = count
+	}
+	return params.ConnectionDetails{
+		Id:           n.id,
+		EntityTag:    n.tag_,
+		RemoteAddr:   n.remoteAddr,
+		ConnectedAt:  n.start,
+		LastActivity: n.lastActivity,
+		FacadeCalls:  calls,
+	}
+}
+
 func (n *requestNotifier) ServerRequest(hdr *rpc.Header, body interface{}) {
+	n.recordRequest(hdr.Request.Type)
 	if hdr.Request.Type == "Pinger" && hdr.Request.Action == "Ping" {
 		return
 	}
+	if logger.EffectiveLogLevel() > loggo.DEBUG {
+		return
+	}
 	// TODO(rog) 2013-10-11 remove secrets from some requests.
 	// Until secrets are removed, we only log the body of the requests at trace level
 	// which is below the default level of debug.
@@ -269,9 +332,15 @@ func (n *requestNotifier) ServerRequest(hdr *rpc.Header, body interface{}) {
 }
 
 func (n *requestNotifier) ServerReply(req rpc.Request, hdr *rpc.Header, body interface{}, timeSpent time.Duration) {
+	if n.metrics != nil {
+		n.metrics.recordCall(req.Type, hdr.Error != "")
+	}
 	if req.Type == "Pinger" && req.Action == "Ping" {
 		return
 	}
+	if logger.EffectiveLogLevel() > loggo.DEBUG {
+		return
+	}
 	// TODO(rog) 2013-10-11 remove secrets from some responses.
 	// Until secrets are removed, we only log the body of the requests at trace level
 	// which is below the default level of debug.
@@ -280,6 +349,9 @@ func (n *requestNotifier) ServerReply(req rpc.Request, hdr *rpc.Header, body int
 }
 
 func (n *requestNotifier) join(req *http.Request) {
+	n.mu.Lock()
+	n.remoteAddr = req.RemoteAddr
+	n.mu.Unlock()
 	logger.Infof("[%X] API connection from %s", n.id, req.RemoteAddr)
 }
 
@@ -317,20 +389,49 @@ func (srv *Server) run(lis net.Listener) {
 		srv.tomb.Kill(err)
 		srv.wg.Done()
 	}()
+	srv.wg.Add(1)
+	go func() {
+		srv.heartbeats.run(srv.tomb.Dying())
+		srv.wg.Done()
+	}()
 	// for pat based handlers, they are matched in-order of being
 	// registered, first match wins. So more specific ones have to be
 	// registered first.
 	mux := pat.New()
 	// For backwards compatibility we register all the old paths
 	handleAll(mux, "/environment/:envuuid/log",
-		&debugLogHandler{
+		&debugLogHandler{httpHandler{ssState: srv.state}},
+	)
+	handleAll(mux, "/environment/:envuuid/logsink",
+		&logSinkHandler{
 			httpHandler: httpHandler{ssState: srv.state},
-			logDir:      srv.logDir},
+			metrics:     srv.metrics,
+		},
+	)
+	handleAll(mux, "/environment/:envuuid/sshproxy",
+		&sshProxyHandler{httpHandler{ssState: srv.state}},
+	)
+	handleAll(mux, "/environment/:envuuid/debughooks/agent",
+		&debugHooksAgentHandler{
+			httpHandler: httpHandler{ssState: srv.state},
+			broker:      srv.debugHooks,
+			stop:        srv.tomb.Dying(),
+		},
+	)
+	handleAll(mux, "/environment/:envuuid/debughooks",
+		&debugHooksHandler{
+			httpHandler:   httpHandler{ssState: srv.state},
+			broker:        srv.debugHooks,
+			transcriptDir: srv.transcriptDir(),
+		},
 	)
 	handleAll(mux, "/environment/:envuuid/charms",
 		&charmsHandler{
-			httpHandler: httpHandler{ssState: srv.state},
-			dataDir:     srv.dataDir},
+			httpHandler: httpHandler{
+				ssState:       srv.state,
+				maxUploadSize: srv.uploadLimits.Charms,
+			},
+			dataDir: srv.dataDir},
 	)
 	// TODO: We can switch from handleAll to mux.Post/Get/etc for entries
 	// where we only want to support specific request methods. However, our
@@ -338,7 +439,10 @@ func (srv *Server) run(lis net.Listener) {
 	// pat only does "text/plain" responses.
 	handleAll(mux, "/environment/:envuuid/tools",
 		&toolsUploadHandler{toolsHandler{
-			httpHandler{ssState: srv.state},
+			httpHandler{
+				ssState:       srv.state,
+				maxUploadSize: srv.uploadLimits.Tools,
+			},
 		}},
 	)
 	handleAll(mux, "/environment/:envuuid/tools/:version",
@@ -351,26 +455,38 @@ func (srv *Server) run(lis net.Listener) {
 			ssState:            srv.state,
 			strictValidation:   true,
 			stateServerEnvOnly: true,
+			maxUploadSize:      srv.uploadLimits.Backups,
 		}},
 	)
 	handleAll(mux, "/environment/:envuuid/api", http.HandlerFunc(srv.apiHandler))
+	handleAll(mux, "/environment/:envuuid/images/:kind/:series/:arch",
+		&imagesUploadHandler{imagesHandler{
+			httpHandler{ssState: srv.state},
+		}},
+	)
 	handleAll(mux, "/environment/:envuuid/images/:kind/:series/:arch/:filename",
-		&imagesDownloadHandler{httpHandler{ssState: srv.state}},
+		&imagesDownloadHandler{imagesHandler{
+			httpHandler{ssState: srv.state},
+		}},
 	)
 	// For backwards compatibility we register all the old paths
 	handleAll(mux, "/log",
-		&debugLogHandler{
-			httpHandler: httpHandler{ssState: srv.state},
-			logDir:      srv.logDir},
+		&debugLogHandler{httpHandler{ssState: srv.state}},
 	)
 	handleAll(mux, "/charms",
 		&charmsHandler{
-			httpHandler: httpHandler{ssState: srv.state},
-			dataDir:     srv.dataDir},
+			httpHandler: httpHandler{
+				ssState:       srv.state,
+				maxUploadSize: srv.uploadLimits.Charms,
+			},
+			dataDir: srv.dataDir},
 	)
 	handleAll(mux, "/tools",
 		&toolsUploadHandler{toolsHandler{
-			httpHandler{ssState: srv.state},
+			httpHandler{
+				ssState:       srv.state,
+				maxUploadSize: srv.uploadLimits.Tools,
+			},
 		}},
 	)
 	handleAll(mux, "/tools/:version",
@@ -378,13 +494,23 @@ func (srv *Server) run(lis net.Listener) {
 			httpHandler{ssState: srv.state},
 		}},
 	)
+	handleAll(mux, "/metrics",
+		&metricsHandler{
+			httpHandler: httpHandler{
+				ssState:            srv.state,
+				stateServerEnvOnly: true,
+			},
+			metrics:     srv.metrics,
+			connections: srv.connections,
+		},
+	)
 	handleAll(mux, "/", http.HandlerFunc(srv.apiHandler))
 	// The error from http.Serve is not interesting.
 	http.Serve(lis, mux)
 }
 
 func (srv *Server) apiHandler(w http.ResponseWriter, req *http.Request) {
-	reqNotifier := newRequestNotifier()
+	reqNotifier := newRequestNotifier(srv.metrics)
 	reqNotifier.join(req)
 	defer reqNotifier.leave()
 	wsServer := websocket.Server{
@@ -413,24 +539,36 @@ func (srv *Server) Addr() string {
 	return srv.addr
 }
 
+// transcriptDir returns the directory in which the transcripts of
+// debug-hooks sessions are recorded, or "" if the server has no log
+// directory.
+func (srv *Server) transcriptDir() string {
+	if srv.logDir == "" {
+		return ""
+	}
+	return filepath.Join(srv.logDir, "debug-hooks")
+}
+
 func (srv *Server) serveConn(wsConn *websocket.Conn, reqNotifier *requestNotifier, envUUID string) error {
 	codec := jsoncodec.NewWebsocket(wsConn)
 	if loggo.GetLogger("juju.rpc.jsoncodec").EffectiveLogLevel() <= loggo.TRACE {
 		codec.SetLogging(true)
 	}
-	var notifier rpc.RequestNotifier
-	if logger.EffectiveLogLevel() <= loggo.DEBUG {
-		// Incur request monitoring overhead only if we
-		// know we'll need it.
-		notifier = reqNotifier
-	}
-	conn := rpc.NewConn(codec, notifier)
+	// The notifier is always installed so that connection activity
+	// can be reported; it only logs requests at debug level or below.
+	conn := rpc.NewConn(codec, reqNotifier)
+	conn.SetRequestTimeout(maxRequestDuration)
+	srv.connections.add(reqNotifier, envUUID, wsConn)
+	defer srv.connections.remove(reqNotifier.id)
 
 	var h *apiHandler
 	st, _, err := validateEnvironUUID(validateArgs{st: srv.state, envUUID: envUUID})
 	if err == nil {
 		h, err = newApiHandler(srv, st, conn, reqNotifier)
 	}
+	if err == nil {
+		srv.connections.setResources(reqNotifier.id, h.getResources())
+	}
 	if err != nil {
 		conn.Serve(&errRoot{err}, serverError)
 	} else {
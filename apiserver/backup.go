@@ -4,6 +4,7 @@
 package apiserver
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,6 +20,10 @@ import (
 	"github.com/juju/juju/state/backups"
 )
 
+// backupChecksumFormat is the format of the checksums
+// in backup metadata.
+const backupChecksumFormat = "SHA-1, base64 encoded"
+
 var newBackups = func(st *state.State) (backups.Backups, io.Closer) {
 	stor := backups.NewStorage(st)
 	return backups.NewBackups(stor), stor
@@ -60,7 +65,7 @@ func (h *backupHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		logger.Infof("handling backups upload request")
 		id, err := h.upload(backups, resp, req)
 		if err != nil {
-			h.sendError(resp, http.StatusInternalServerError, err.Error())
+			h.sendError(resp, uploadErrorStatus(err, http.StatusInternalServerError), err.Error())
 			return
 		}
 		logger.Infof("backups upload request successful for %q", id)
@@ -96,13 +101,25 @@ func (h *backupHandler) upload(backups backups.Backups, resp http.ResponseWriter
 	if err != nil {
 		return "", err
 	}
+	defer archive.Close()
 
 	if err := validateBackupMetadataResult(metaResult); err != nil {
 		return "", err
 	}
 
+	// Spool the archive to disk so that it can be checked against
+	// the metadata before being stored.
+	upload, err := spoolUpload(archive, -1, h.maxUploadSize)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer upload.Close()
+	if err := checkBackupArchive(metaResult, upload); err != nil {
+		return "", errors.Trace(err)
+	}
+
 	meta := apiserverbackups.MetadataFromResult(metaResult)
-	id, err := backups.Add(archive, meta)
+	id, err := backups.Add(upload, meta)
 	if err != nil {
 		return "", err
 	}
@@ -121,6 +138,24 @@ func validateBackupMetadataResult(metaResult params.BackupsMetadataResult) error
 	return nil
 }
 
+// checkBackupArchive checks the uploaded archive against the size
+// and checksum recorded in its metadata, where they are set.
+func checkBackupArchive(metaResult params.BackupsMetadataResult, upload *spooledUpload) error {
+	if metaResult.Size != 0 && metaResult.Size != upload.size {
+		return errors.Errorf("archive size mismatch: expected %d bytes, got %d", metaResult.Size, upload.size)
+	}
+	if metaResult.Checksum == "" {
+		return nil
+	}
+	if metaResult.ChecksumFormat != backupChecksumFormat {
+		return errors.Errorf("unsupported checksum format %q", metaResult.ChecksumFormat)
+	}
+	if checksum := base64.StdEncoding.EncodeToString(upload.sha1); checksum != metaResult.Checksum {
+		return errors.Errorf("archive checksum mismatch: expected %s, got %s", metaResult.Checksum, checksum)
+	}
+	return nil
+}
+
 func (h *backupHandler) read(req *http.Request, expectedType string) ([]byte, error) {
 	defer req.Body.Close()
 
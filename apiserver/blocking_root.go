@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// blockingRoot rejects the API calls that belong to a class of
+// operations which has been blocked in the environment. The error
+// returned carries the message given when the block was switched on.
+type blockingRoot struct {
+	rpc.MethodFinder
+	check *common.BlockChecker
+}
+
+// newBlockingRoot returns a new blockingRoot.
+func newBlockingRoot(finder rpc.MethodFinder, st *state.State) *blockingRoot {
+	return &blockingRoot{
+		MethodFinder: finder,
+		check:        common.NewBlockChecker(st),
+	}
+}
+
+// blockedMethods maps the methods enforced by blockingRoot to the
+// check which decides whether they are allowed. Methods that only
+// remove or change objects are checked by the facades themselves.
+var blockedMethods = map[string]func(*common.BlockChecker) error{
+	// Operations that may increase the cost of the environment.
+	"Client.AddMachines":                        (*common.BlockChecker).BudgetAllowed,
+	"Client.AddMachinesV2":                      (*common.BlockChecker).BudgetAllowed,
+	"Client.AddServiceUnits":                    (*common.BlockChecker).BudgetAllowed,
+	"Client.EnsureAvailability":                 (*common.BlockChecker).BudgetAllowed,
+	"Client.ServiceDeploy":                      (*common.BlockChecker).BudgetAllowed,
+	"Client.ServiceDeployWithChannel":           (*common.BlockChecker).BudgetAllowed,
+	"Client.ServiceDeployWithNetworks":          (*common.BlockChecker).BudgetAllowed,
+	"Client.ServiceDeployWithWorkloadContainer": (*common.BlockChecker).BudgetAllowed,
+	"HighAvailability.EnsureAvailability":       (*common.BlockChecker).BudgetAllowed,
+
+	// Operations that reboot machines on behalf of charms.
+	"Reboot.RequestReboot": (*common.BlockChecker).RebootAllowed,
+	"Uniter.RequestReboot": (*common.BlockChecker).RebootAllowed,
+}
+
+// FindMethod returns an error reporting the block for methods in
+// blockedMethods, if the relevant block is in place when the method
+// is called.
+func (r *blockingRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	check, ok := blockedMethods[rootName+"."+methodName]
+	if !ok {
+		return caller, nil
+	}
+	if err := check(r.check); err != nil {
+		return nil, err
+	}
+	return caller, nil
+}
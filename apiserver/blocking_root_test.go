@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type blockingRootSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&blockingRootSuite{})
+
+func (s *blockingRootSuite) switchBlockOn(c *gc.C, t state.BlockType, msg string) {
+	err := s.State.SwitchBlockOn(t, msg)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *blockingRootSuite) assertBlocked(c *gc.C, rootName string, version int, methodName, msg string) {
+	root := apiserver.TestingBlockingRoot(s.State)
+	caller, err := root.FindMethod(rootName, version, methodName)
+	c.Assert(err, gc.ErrorMatches, msg)
+	c.Assert(common.ServerError(err).Code, gc.Equals, params.CodeOperationBlocked)
+	c.Assert(caller, gc.IsNil)
+}
+
+func (s *blockingRootSuite) assertAllowed(c *gc.C, rootName string, version int, methodName string) {
+	root := apiserver.TestingBlockingRoot(s.State)
+	caller, err := root.FindMethod(rootName, version, methodName)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caller, gc.NotNil)
+}
+
+func (s *blockingRootSuite) TestMethodsAllowedWithoutBlocks(c *gc.C) {
+	s.assertAllowed(c, "Client", 0, "AddMachines")
+	s.assertAllowed(c, "Reboot", 1, "RequestReboot")
+}
+
+func (s *blockingRootSuite) TestBudgetBlock(c *gc.C) {
+	s.switchBlockOn(c, state.BudgetBlock, "over budget")
+	s.assertBlocked(c, "Client", 0, "AddMachines", "over budget")
+	s.assertBlocked(c, "Client", 0, "ServiceDeploy", "over budget")
+	s.assertBlocked(c, "Client", 0, "AddServiceUnits", "over budget")
+	s.assertAllowed(c, "Client", 0, "FullStatus")
+	s.assertAllowed(c, "Reboot", 1, "RequestReboot")
+}
+
+func (s *blockingRootSuite) TestChangeBlockBlocksBudgetMethods(c *gc.C) {
+	s.switchBlockOn(c, state.ChangeBlock, "frozen")
+	s.assertBlocked(c, "Client", 0, "AddMachines", "frozen")
+	s.assertAllowed(c, "Reboot", 1, "RequestReboot")
+}
+
+func (s *blockingRootSuite) TestRebootBlock(c *gc.C) {
+	s.switchBlockOn(c, state.RebootBlock, "no reboots during the sale")
+	s.assertBlocked(c, "Reboot", 1, "RequestReboot", "no reboots during the sale")
+	s.assertAllowed(c, "Reboot", 1, "GetRebootAction")
+	s.assertAllowed(c, "Client", 0, "AddMachines")
+}
+
+func (s *blockingRootSuite) TestFindNonExistentMethod(c *gc.C) {
+	root := apiserver.TestingBlockingRoot(s.State)
+	caller, err := root.FindMethod("Foo", 0, "Bar")
+	c.Assert(err, gc.ErrorMatches, "unknown object type \"Foo\"")
+	c.Assert(caller, gc.IsNil)
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package capabilities implements the API used by clients to discover
+// the features supported by an environment.
+package capabilities
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Capabilities", 1, NewAPI)
+}
+
+// API implements the Capabilities facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new Capabilities API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+var newEnviron = func(st *state.State) (environs.Environ, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return environs.New(cfg)
+}
+
+// EnvironCapabilities returns the capabilities of the environment.
+func (api *API) EnvironCapabilities() (params.EnvironCapabilities, error) {
+	var result params.EnvironCapabilities
+	env, err := newEnviron(api.st)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot open environment")
+	}
+	caps, err := environs.EnvironCapabilities(env)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result = params.EnvironCapabilities{
+		Architectures:      caps.Architectures,
+		UnitPlacement:      caps.UnitPlacement,
+		UnitPlacementError: caps.UnitPlacementError,
+		Networking:         caps.Networking,
+		AddressAllocation:  caps.AddressAllocation,
+		FirewallMode:       caps.FirewallMode,
+		FirewallModes:      caps.FirewallModes,
+	}
+	for _, container := range caps.Containers {
+		result.Containers = append(result.Containers, string(container))
+	}
+	for _, providerType := range caps.StorageProviders {
+		result.StorageProviders = append(result.StorageProviders, string(providerType))
+	}
+	for _, kind := range caps.StorageKinds {
+		result.StorageKinds = append(result.StorageKinds, kind.String())
+	}
+	return result, nil
+}
+
+// CheckCharmCompatibility checks each prospective deployment of a
+// charm, or upgrade of a service, against the environment, and reports
+// all of the ways in which it is incompatible.
+func (api *API) CheckCharmCompatibility(args params.CharmCompatibilityChecks) (params.CharmCompatibilityReports, error) {
+	result := params.CharmCompatibilityReports{
+		Results: make([]params.CharmCompatibilityReport, len(args.Checks)),
+	}
+	if len(args.Checks) == 0 {
+		return result, nil
+	}
+	env, err := newEnviron(api.st)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot open environment")
+	}
+	for i, check := range args.Checks {
+		problems, err := common.CheckCharmCompatibility(api.st, env, check)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Incompatibilities = problems
+	}
+	return result, nil
+}
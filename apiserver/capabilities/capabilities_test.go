@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package capabilities_test
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/capabilities"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type capabilitiesSuite struct {
+	jujutesting.JujuConnSuite
+	api *capabilities.API
+}
+
+var _ = gc.Suite(&capabilitiesSuite{})
+
+func (s *capabilitiesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = capabilities.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *capabilitiesSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := capabilities.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *capabilitiesSuite) TestEnvironCapabilities(c *gc.C) {
+	caps, err := s.api.EnvironCapabilities()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caps, jc.DeepEquals, params.EnvironCapabilities{
+		Architectures:     []string{"amd64", "i386", "ppc64el"},
+		UnitPlacement:     true,
+		Containers:        []string{"lxc", "kvm", "hyperv"},
+		Networking:        true,
+		AddressAllocation: true,
+		StorageProviders:  []string{"dummy-volume", "loop", "rootfs", "tmpfs"},
+		StorageKinds:      []string{"block", "filesystem"},
+		FirewallMode:      "instance",
+		FirewallModes:     []string{"instance", "global", "none"},
+	})
+}
+
+type restrictedEnviron struct {
+	environs.Environ
+}
+
+func (restrictedEnviron) SupportsUnitPlacement() error {
+	return errors.New("units cannot be placed")
+}
+
+func (restrictedEnviron) SupportedContainerTypes() ([]instance.ContainerType, error) {
+	return []instance.ContainerType{instance.LXC}, nil
+}
+
+func (restrictedEnviron) SupportedFirewallModes() []string {
+	return []string{"instance"}
+}
+
+func (s *capabilitiesSuite) TestEnvironCapabilitiesRestricted(c *gc.C) {
+	s.PatchValue(capabilities.NewEnviron, func(st *state.State) (environs.Environ, error) {
+		cfg, err := st.EnvironConfig()
+		c.Assert(err, jc.ErrorIsNil)
+		env, err := environs.New(cfg)
+		c.Assert(err, jc.ErrorIsNil)
+		return restrictedEnviron{env}, nil
+	})
+	caps, err := s.api.EnvironCapabilities()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caps.UnitPlacement, jc.IsFalse)
+	c.Assert(caps.UnitPlacementError, gc.Equals, "units cannot be placed")
+	c.Assert(caps.Containers, jc.DeepEquals, []string{"lxc"})
+	c.Assert(caps.FirewallModes, jc.DeepEquals, []string{"instance"})
+	// restrictedEnviron hides the networking support of the dummy
+	// environment.
+	c.Assert(caps.Networking, jc.IsFalse)
+}
+
+func (s *capabilitiesSuite) TestCheckCharmCompatibility(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	precise, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	quantal, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.api.CheckCharmCompatibility(params.CharmCompatibilityChecks{
+		Checks: []params.CharmCompatibilityCheck{{
+			CharmURL:      ch.URL().String(),
+			ToMachineSpec: quantal.Id(),
+		}, {
+			CharmURL:      ch.URL().String(),
+			ToMachineSpec: precise.Id(),
+		}, {
+			CharmURL: "cs:quantal/missing-1",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.CharmCompatibilityReports{
+		Results: []params.CharmCompatibilityReport{{}, {
+			Incompatibilities: []params.CharmIncompatibility{{
+				Kind:    "series",
+				Message: fmt.Sprintf(`machine %s has series "precise", charm is for series "quantal"`, precise.Id()),
+			}},
+		}, {
+			Error: &params.Error{
+				Message: `charm "cs:quantal/missing-1" not found`,
+				Code:    params.CodeNotFound,
+			},
+		}},
+	})
+}
+
+func (s *capabilitiesSuite) TestCheckCharmCompatibilityNetworks(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	check := params.CharmCompatibilityChecks{
+		Checks: []params.CharmCompatibilityCheck{{
+			CharmURL: ch.URL().String(),
+			Networks: []string{"network-net1"},
+		}},
+	}
+	results, err := s.api.CheckCharmCompatibility(check)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Incompatibilities, gc.HasLen, 0)
+
+	s.PatchValue(capabilities.NewEnviron, func(st *state.State) (environs.Environ, error) {
+		cfg, err := st.EnvironConfig()
+		c.Assert(err, jc.ErrorIsNil)
+		env, err := environs.New(cfg)
+		c.Assert(err, jc.ErrorIsNil)
+		return restrictedEnviron{env}, nil
+	})
+	results, err = s.api.CheckCharmCompatibility(check)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Incompatibilities, jc.DeepEquals, []params.CharmIncompatibility{{
+		Kind:    "network",
+		Message: "networks net1 requested, but the environment does not support networking",
+	}})
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package certificates implements the API used to rotate the
+// certificates presented by the state servers.
+package certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/state"
+)
+
+var logger = loggo.GetLogger("juju.apiserver.certificates")
+
+func init() {
+	common.RegisterStandardFacade("Certificates", 1, NewAPI)
+}
+
+// API implements the Certificates facade.
+type API struct {
+	st         certificatesState
+	authorizer common.Authorizer
+}
+
+// NewAPI returns a new Certificates API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	if !st.IsStateServer() {
+		return nil, errors.New("certificates can only be rotated in the state server environment")
+	}
+	return &API{
+		st:         getState(st),
+		authorizer: authorizer,
+	}, nil
+}
+
+var getState = func(st *state.State) certificatesState {
+	return stateShim{st}
+}
+
+// RotateServerCertificate installs a new state server certificate. The
+// certificate is stored in state, from where each state server picks it
+// up and starts presenting it to new connections without restarting.
+//
+// If a new CA certificate is supplied, it is added to the environment's
+// CA certificate alongside the current one, so agents continue to trust
+// the old certificate while they learn about the new one.
+func (api *API) RotateServerCertificate(args params.RotateCertificateArgs) (params.ErrorResult, error) {
+	if err := api.rotateServerCertificate(args); err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}, nil
+	}
+	return params.ErrorResult{}, nil
+}
+
+func (api *API) rotateServerCertificate(args params.RotateCertificateArgs) error {
+	if args.Cert == "" || args.PrivateKey == "" {
+		return errors.NotValidf("empty certificate or private key")
+	}
+	if _, err := tls.X509KeyPair([]byte(args.Cert), []byte(args.PrivateKey)); err != nil {
+		return errors.Annotate(err, "invalid certificate/key pair")
+	}
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	currentCACert, _ := envConfig.CACert()
+	caCert := currentCACert
+	if args.CACert != "" {
+		if args.CAPrivateKey == "" {
+			return errors.NotValidf("CA certificate without private key")
+		}
+		if _, err := tls.X509KeyPair([]byte(args.CACert), []byte(args.CAPrivateKey)); err != nil {
+			return errors.Annotate(err, "invalid CA certificate/key pair")
+		}
+		caCert = args.CACert
+	}
+	if err := verifySignedBy(args.Cert, caCert); err != nil {
+		return errors.Annotate(err, "certificate not signed by CA")
+	}
+
+	info, err := api.st.StateServingInfo()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if args.CACert != "" && args.CACert != currentCACert {
+		// Trust both CA certificates until every agent has
+		// picked up the new one. The new certificate comes
+		// first so that it pairs with the new private key.
+		attrs := map[string]interface{}{
+			"ca-cert":        args.CACert + currentCACert,
+			"ca-private-key": args.CAPrivateKey,
+		}
+		if err := api.st.UpdateEnvironConfig(attrs, nil, nil); err != nil {
+			return errors.Annotate(err, "cannot update CA certificate")
+		}
+		info.CAPrivateKey = args.CAPrivateKey
+	}
+	info.Cert = args.Cert
+	info.PrivateKey = args.PrivateKey
+	if err := api.st.SetStateServingInfo(info); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("state server certificate rotated")
+	return nil
+}
+
+// verifySignedBy checks that the given certificate chains to one of the
+// certificates in caCertPEM.
+func verifySignedBy(certPEM, caCertPEM string) error {
+	pool, err := cert.ParseCertPool(caCertPEM)
+	if err != nil {
+		return errors.Annotate(err, "cannot parse CA certificate")
+	}
+	srvCert, err := cert.ParseCert(certPEM)
+	if err != nil {
+		return errors.Annotate(err, "cannot parse certificate")
+	}
+	_, err = srvCert.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: time.Now(),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err
+}
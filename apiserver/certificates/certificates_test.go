@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certificates_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/certificates"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/cert"
+	jujutesting "github.com/juju/juju/juju/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type certificatesSuite struct {
+	jujutesting.JujuConnSuite
+	api *certificates.API
+}
+
+var _ = gc.Suite(&certificatesSuite{})
+
+func (s *certificatesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+
+	var err error
+	auth := apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	}
+	s.api, err = certificates.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *certificatesSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	}
+	_, err := certificates.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *certificatesSuite) TestRotateServerCertificate(c *gc.C) {
+	srvCert, srvKey, err := cert.NewServer(
+		coretesting.CACert, coretesting.CAKey, time.Now().AddDate(1, 0, 0), []string{"juju-apiserver"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.RotateServerCertificate(params.RotateCertificateArgs{
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Cert, gc.Equals, srvCert)
+	c.Assert(info.PrivateKey, gc.Equals, srvKey)
+	c.Assert(info.CAPrivateKey, gc.Equals, coretesting.CAKey)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateWithNewCA(c *gc.C) {
+	srvCert, srvKey, err := cert.NewServer(
+		coretesting.OtherCACert, coretesting.OtherCAKey, time.Now().AddDate(1, 0, 0), nil,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.RotateServerCertificate(params.RotateCertificateArgs{
+		Cert:         srvCert,
+		PrivateKey:   srvKey,
+		CACert:       coretesting.OtherCACert,
+		CAPrivateKey: coretesting.OtherCAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Cert, gc.Equals, srvCert)
+	c.Assert(info.CAPrivateKey, gc.Equals, coretesting.OtherCAKey)
+
+	envConfig, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	caCert, ok := envConfig.CACert()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(caCert, gc.Equals, coretesting.OtherCACert+coretesting.CACert)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateWrongCA(c *gc.C) {
+	srvCert, srvKey, err := cert.NewServer(
+		coretesting.OtherCACert, coretesting.OtherCAKey, time.Now().AddDate(1, 0, 0), nil,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.RotateServerCertificate(params.RotateCertificateArgs{
+		Cert:       srvCert,
+		PrivateKey: srvKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "certificate not signed by CA: .*")
+
+	info, err := s.State.StateServingInfo()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Cert, gc.Equals, coretesting.ServerCert)
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateMismatchedKey(c *gc.C) {
+	result, err := s.api.RotateServerCertificate(params.RotateCertificateArgs{
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "invalid certificate/key pair: .*")
+}
+
+func (s *certificatesSuite) TestRotateServerCertificateCAWithoutKey(c *gc.C) {
+	result, err := s.api.RotateServerCertificate(params.RotateCertificateArgs{
+		Cert:       coretesting.ServerCert,
+		PrivateKey: coretesting.ServerKey,
+		CACert:     coretesting.OtherCACert,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.ErrorMatches, "CA certificate without private key not valid")
+}
@@ -0,0 +1,20 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certificates
+
+import (
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+type certificatesState interface {
+	EnvironConfig() (*config.Config, error)
+	UpdateEnvironConfig(updateAttrs map[string]interface{}, removeAttrs []string, additionalValidation state.ValidateConfigFunc) error
+	StateServingInfo() (state.StateServingInfo, error)
+	SetStateServingInfo(info state.StateServingInfo) error
+}
+
+type stateShim struct {
+	*state.State
+}
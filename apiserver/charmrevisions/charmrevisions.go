@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package charmrevisions provides the API used by clients to follow
+// the newer revisions of the charms of an environment's services
+// found in the charm store. Services are not upgraded automatically.
+package charmrevisions
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("CharmRevisions", 1, NewCharmRevisionsAPI)
+}
+
+// CharmRevisionsAPI implements the CharmRevisions facade.
+type CharmRevisionsAPI struct {
+	st        *state.State
+	resources *common.Resources
+}
+
+// NewCharmRevisionsAPI creates a new server-side CharmRevisions
+// facade. It is available to clients and to state server agents.
+func NewCharmRevisionsAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*CharmRevisionsAPI, error) {
+	if !auth.AuthClient() && !auth.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &CharmRevisionsAPI{
+		st:        st,
+		resources: resources,
+	}, nil
+}
+
+// WatchCharmRevisions returns a NotifyWatcher which notifies when the
+// revision of any service's charm, or the latest revision available
+// to it in the charm store, changes, or a service is added or removed.
+func (api *CharmRevisionsAPI) WatchCharmRevisions() (params.NotifyWatchResult, error) {
+	result := params.NotifyWatchResult{}
+	watch := api.st.WatchCharmRevisions()
+	if _, ok := <-watch.Changes(); ok {
+		result.NotifyWatcherId = api.resources.Register(watch)
+	} else {
+		return result, watcher.EnsureErr(watch)
+	}
+	return result, nil
+}
+
+// CharmRevisions returns the revisions of the charms of all the
+// services in the environment, and the latest revisions available
+// to them in the channels they track, ordered by service name.
+func (api *CharmRevisionsAPI) CharmRevisions() (params.ServiceCharmRevisions, error) {
+	revisions, err := api.st.ServiceCharmRevisions()
+	if err != nil {
+		return params.ServiceCharmRevisions{}, errors.Trace(err)
+	}
+	result := params.ServiceCharmRevisions{
+		Revisions: make([]params.ServiceCharmRevision, len(revisions)),
+	}
+	for i, revision := range revisions {
+		result.Revisions[i] = params.ServiceCharmRevision{
+			ServiceTag: names.NewServiceTag(revision.Service).String(),
+			Channel:    string(revision.Channel),
+			CharmURL:   revision.CharmURL.String(),
+		}
+		if latest := revision.LatestCharmURL; latest != nil {
+			result.Revisions[i].LatestCharmURL = latest.String()
+			result.Revisions[i].LatestRevision = latest.Revision
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmrevisions_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/apiserver/charmrevisions"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type charmRevisionsSuite struct {
+	jujutesting.JujuConnSuite
+
+	resources *common.Resources
+	api       *charmrevisions.CharmRevisionsAPI
+}
+
+var _ = gc.Suite(&charmRevisionsSuite{})
+
+func (s *charmRevisionsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+	var err error
+	s.api, err = charmrevisions.NewCharmRevisionsAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	ch := s.Factory.MakeCharm(c, &factory.CharmParams{Name: "mysql", URL: "cs:quantal/mysql-3"})
+	s.Factory.MakeService(c, &factory.ServiceParams{Name: "mysql", Charm: ch})
+}
+
+func (s *charmRevisionsSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	_, err := charmrevisions.NewCharmRevisionsAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *charmRevisionsSuite) TestCharmRevisions(c *gc.C) {
+	result, err := s.api.CharmRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Revisions, jc.DeepEquals, []params.ServiceCharmRevision{{
+		ServiceTag: "service-mysql",
+		Channel:    "stable",
+		CharmURL:   "cs:quantal/mysql-3",
+	}})
+
+	err = s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.api.CharmRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Revisions, jc.DeepEquals, []params.ServiceCharmRevision{{
+		ServiceTag:     "service-mysql",
+		Channel:        "stable",
+		CharmURL:       "cs:quantal/mysql-3",
+		LatestCharmURL: "cs:quantal/mysql-5",
+		LatestRevision: 5,
+	}})
+}
+
+func (s *charmRevisionsSuite) TestWatchCharmRevisions(c *gc.C) {
+	result, err := s.api.WatchCharmRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	w := s.resources.Get("1").(state.NotifyWatcher)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertNoChange()
+
+	err = s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -72,6 +72,11 @@ func (api *CharmRevisionUpdaterAPI) UpdateLatestRevisions() (params.ErrorResult,
 			return params.ErrorResult{Error: common.ServerError(err)}, nil
 		}
 	}
+	// Services tracking other channels have the latest revisions
+	// available in them recorded against the services instead.
+	if err := updateChannelRevisions(api.state, uuid); err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}, nil
+	}
 	return params.ErrorResult{}, nil
 }
 
@@ -84,6 +89,9 @@ func fetchAllDeployedCharms(st *state.State) (map[string]*charm.URL, error) {
 		return nil, err
 	}
 	for _, s := range services {
+		if s.Channel() != state.StableChannel {
+			continue
+		}
 		url, _ := s.CharmURL()
 		// Record the basic charm information so it can be bulk processed later to
 		// get the available revision numbers from the repo.
@@ -127,3 +135,50 @@ func retrieveLatestCharmInfo(deployedCharms map[string]*charm.URL, uuid string)
 	}
 	return latestCurls, nil
 }
+
+// updateChannelRevisions looks up the charm store for the latest
+// revisions of the charms of services tracking channels other than
+// stable, in the channels they track, and records them against the
+// services.
+func updateChannelRevisions(st *state.State, uuid string) error {
+	services, err := st.AllServices()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	channelServices := make(map[state.CharmChannel][]*state.Service)
+	for _, s := range services {
+		channel := s.Channel()
+		url, _ := s.CharmURL()
+		if channel == state.StableChannel || url.Schema != "cs" {
+			continue
+		}
+		channelServices[channel] = append(channelServices[channel], s)
+	}
+	for channel, services := range channelServices {
+		curls := make([]*charm.URL, len(services))
+		for i, s := range services {
+			url, _ := s.CharmURL()
+			curls[i] = url.WithRevision(-1)
+		}
+		logger.Infof("retrieving %s channel revision information for %d charms", channel, len(curls))
+		store, err := common.CharmRepoForChannel(charm.Store, channel, "environment_uuid="+uuid)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		revInfo, err := store.Latest(curls...)
+		if err != nil {
+			return errors.Annotatef(err, "finding %s channel charm revision info", channel)
+		}
+		for i, info := range revInfo {
+			if info.Err != nil {
+				logger.Errorf("retrieving %s channel charm info for %s: %v", channel, curls[i], info.Err)
+				continue
+			}
+			latest := curls[i].WithRevision(info.Revision)
+			if err := services[i].SetLatestChannelCharmURL(latest); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
@@ -129,3 +129,31 @@ func (s *charmVersionSuite) TestEnvironmentUUIDUsed(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(s.Server.Metadata, gc.DeepEquals, []string{"environment_uuid=" + env.UUID()})
 }
+
+func (s *charmVersionSuite) TestUpdateChannelRevisions(c *gc.C) {
+	s.AddMachine(c, "0", state.JobManageEnviron)
+	s.SetupScenario(c)
+	svc, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	err = svc.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.charmrevisionupdater.UpdateLatestRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+
+	// The latest revision in the edge channel is recorded against
+	// the service rather than as a placeholder charm.
+	err = svc.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.LatestChannelCharmURL(), jc.DeepEquals, charm.MustParseURL("cs:quantal/mysql-23"))
+	_, err = s.State.LatestPlaceholderCharm(charm.MustParseURL("cs:quantal/mysql"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.Server.Metadata, jc.SameContents, []string{
+		"environment_uuid=" + env.UUID(),
+		"environment_uuid=" + env.UUID() + ",channel=edge",
+	})
+}
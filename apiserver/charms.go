@@ -60,7 +60,7 @@ func (h *charmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// Requires a "series" query specifying the series to use for the charm.
 		charmURL, err := h.processPost(r, stateWrapper.state)
 		if err != nil {
-			h.sendError(w, http.StatusBadRequest, err.Error())
+			h.sendError(w, uploadErrorStatus(err, http.StatusBadRequest), err.Error())
 			return
 		}
 		h.sendJSON(w, http.StatusOK, &params.CharmsResponse{CharmURL: charmURL.String()})
@@ -202,20 +202,19 @@ func (h *charmsHandler) processPost(r *http.Request, st *state.State) (*charm.UR
 	if contentType != "application/zip" {
 		return nil, fmt.Errorf("expected Content-Type: application/zip, got: %v", contentType)
 	}
-	tempFile, err := ioutil.TempFile("", "charm")
+	upload, err := spoolUpload(r.Body, r.ContentLength, h.maxUploadSize)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create temp file: %v", err)
+		return nil, err
 	}
-	defer tempFile.Close()
-	defer os.Remove(tempFile.Name())
-	if _, err := io.Copy(tempFile, r.Body); err != nil {
-		return nil, fmt.Errorf("error processing file upload: %v", err)
+	defer upload.Close()
+	if err := upload.checkDigest(r); err != nil {
+		return nil, err
 	}
-	err = h.processUploadedArchive(tempFile.Name())
+	err = h.processUploadedArchive(upload.Name())
 	if err != nil {
 		return nil, err
 	}
-	archive, err := charm.ReadCharmArchive(tempFile.Name())
+	archive, err := charm.ReadCharmArchive(upload.Name())
 	if err != nil {
 		return nil, fmt.Errorf("invalid charm archive: %v", err)
 	}
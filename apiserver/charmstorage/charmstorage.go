@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package charmstorage implements the API used to report the storage
+// used by an environment's charm archives.
+package charmstorage
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("CharmStorage", 1, NewAPI)
+}
+
+// API implements the CharmStorage facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new CharmStorage API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// Usage returns the storage used by the environment's charm archives,
+// and the environment's charm storage quota.
+func (api *API) Usage() (params.CharmStorageUsage, error) {
+	usage, err := api.st.CharmStorageUsage()
+	if err != nil {
+		return params.CharmStorageUsage{}, errors.Trace(err)
+	}
+	result := params.CharmStorageUsage{
+		Size:   usage.Size,
+		Quota:  usage.Quota,
+		Charms: make([]params.CharmUsage, len(usage.Charms)),
+	}
+	for i, ch := range usage.Charms {
+		result.Charms[i] = params.CharmUsage{
+			URL:      ch.URL.String(),
+			Size:     ch.Size,
+			RefCount: ch.RefCount,
+		}
+		if !ch.UnusedSince.IsZero() {
+			unusedSince := ch.UnusedSince
+			result.Charms[i].UnusedSince = &unusedSince
+		}
+	}
+	return result, nil
+}
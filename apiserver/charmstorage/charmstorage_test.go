@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmstorage_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/charmstorage"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type charmStorageSuite struct {
+	jujutesting.JujuConnSuite
+	api *charmstorage.API
+}
+
+var _ = gc.Suite(&charmStorageSuite{})
+
+func (s *charmStorageSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = charmstorage.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *charmStorageSuite) TestNewAPIRefusesUnitAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewUnitTag("wordpress/0")}
+	_, err := charmstorage.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *charmStorageSuite) TestNewAPIAcceptsEnvironManager(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	}
+	_, err := charmstorage.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *charmStorageSuite) TestUsage(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	s.AddTestingService(c, "wordpress", ch)
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"charm-storage-quota": 10,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	usage, err := s.api.Usage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage, jc.DeepEquals, params.CharmStorageUsage{
+		Size:  0,
+		Quota: 10 * 1024 * 1024,
+		Charms: []params.CharmUsage{{
+			URL:      ch.URL().String(),
+			Size:     0,
+			RefCount: 1,
+		}},
+	})
+}
@@ -210,15 +210,24 @@ var scenarioStatus = &api.Status{
 				"logging-directory": {"wordpress"},
 			},
 			SubordinateTo: []string{"wordpress"},
+			Status: api.WorkloadStatus{
+				Status: "busy",
+			},
 		},
 		"mysql": {
 			Charm:         "local:quantal/mysql-1",
 			Relations:     map[string][]string{},
 			SubordinateTo: []string{},
 			Units:         map[string]api.UnitStatus{},
+			Status: api.WorkloadStatus{
+				Status: "unknown",
+			},
 		},
 		"wordpress": {
 			Charm: "local:quantal/wordpress-3",
+			Status: api.WorkloadStatus{
+				Status: "busy",
+			},
 			Relations: map[string][]string{
 				"logging-dir": {"logging"},
 			},
@@ -230,6 +239,9 @@ var scenarioStatus = &api.Status{
 						Info:   "blam",
 						Data:   map[string]interface{}{"relation-id": "0"},
 					},
+					Workload: api.WorkloadStatus{
+						Status: "busy",
+					},
 					AgentState:     "down",
 					AgentStateInfo: "(error: blam)",
 					Machine:        "1",
@@ -239,6 +251,9 @@ var scenarioStatus = &api.Status{
 								Status: "allocating",
 								Data:   make(map[string]interface{}),
 							},
+							Workload: api.WorkloadStatus{
+								Status: "busy",
+							},
 							AgentState: "allocating",
 						},
 					},
@@ -248,6 +263,9 @@ var scenarioStatus = &api.Status{
 						Status: "allocating",
 						Data:   make(map[string]interface{}),
 					},
+					Workload: api.WorkloadStatus{
+						Status: "busy",
+					},
 					AgentState: "allocating",
 					Machine:    "2",
 					Subordinates: map[string]api.UnitStatus{
@@ -256,6 +274,9 @@ var scenarioStatus = &api.Status{
 								Status: "allocating",
 								Data:   make(map[string]interface{}),
 							},
+							Workload: api.WorkloadStatus{
+								Status: "busy",
+							},
 							AgentState: "allocating",
 						},
 					},
@@ -20,6 +20,8 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/highavailability"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/manual"
 	"github.com/juju/juju/feature"
@@ -52,6 +54,9 @@ type API struct {
 	// statusSetter provides common methods for updating an entity's provisioning status.
 	statusSetter *common.StatusSetter
 	toolsFinder  *common.ToolsFinder
+	// statusCache, if not nil, holds the results of slow
+	// FullStatus calls.
+	statusCache common.StatusCache
 }
 
 // Client serves client-specific API methods.
@@ -70,6 +75,10 @@ func NewClient(st *state.State, resources *common.Resources, authorizer common.A
 		return nil, err
 	}
 	urlGetter := common.NewToolsURLGetter(env.UUID(), st)
+	var statusCache common.StatusCache
+	if resources != nil {
+		statusCache, _ = resources.Get("statusCache").(common.StatusCache)
+	}
 	return &Client{
 		api: &API{
 			state:        st,
@@ -77,6 +86,7 @@ func NewClient(st *state.State, resources *common.Resources, authorizer common.A
 			resources:    resources,
 			statusSetter: common.NewStatusSetter(st, common.AuthAlways()),
 			toolsFinder:  common.NewToolsFinder(st, st, urlGetter),
+			statusCache:  statusCache,
 		},
 		check: common.NewBlockChecker(st)}, nil
 }
@@ -237,8 +247,35 @@ func (c *Client) PrivateAddress(p params.PrivateAddress) (results params.Private
 	return results, fmt.Errorf("unknown unit or machine %q", p.Target)
 }
 
+// SSHHostKeys returns the public ssh host keys recorded for the
+// specified machine, or for the machine hosting the specified unit.
+func (c *Client) SSHHostKeys(p params.SSHHostKeys) (results params.SSHHostKeysResults, err error) {
+	var machineId string
+	switch {
+	case names.IsValidMachine(p.Target):
+		machineId = p.Target
+	case names.IsValidUnit(p.Target):
+		unit, err := c.api.state.Unit(p.Target)
+		if err != nil {
+			return results, err
+		}
+		machineId, err = unit.AssignedMachineId()
+		if err != nil {
+			return results, err
+		}
+	default:
+		return results, fmt.Errorf("unknown unit or machine %q", p.Target)
+	}
+	machine, err := c.api.state.Machine(machineId)
+	if err != nil {
+		return results, err
+	}
+	return params.SSHHostKeysResults{PublicKeys: machine.SSHHostKeys()}, nil
+}
+
 // ServiceExpose changes the juju-managed firewall to expose any ports that
-// were also explicitly marked by units as open.
+// were also explicitly marked by units as open. If CIDRs are given, the
+// ports are exposed only to those address ranges.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceExpose(args params.ServiceExpose) error {
 	if err := c.check.ChangeAllowed(); err != nil {
@@ -248,7 +285,7 @@ func (c *Client) ServiceExpose(args params.ServiceExpose) error {
 	if err != nil {
 		return err
 	}
-	return svc.SetExposed()
+	return svc.SetExposedTo(args.CIDRs)
 }
 
 // ServiceUnexpose changes the juju-managed firewall to unexpose any ports that
@@ -368,6 +405,18 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 		}
 	}
 
+	// Refuse the deployment now, rather than part way through
+	// provisioning, if the charm cannot be deployed as requested.
+	if err := c.checkCharmCompatibility(params.CharmCompatibilityCheck{
+		CharmURL:      args.CharmUrl,
+		ToMachineSpec: args.ToMachineSpec,
+		Constraints:   args.Constraints,
+		Networks:      args.Networks,
+		Storage:       storageConstraints,
+	}); err != nil {
+		return errors.Trace(err)
+	}
+
 	var settings charm.Settings
 	if len(args.ConfigYAML) > 0 {
 		settings, err = ch.Config().ParseSettingsYAML([]byte(args.ConfigYAML), args.ServiceName)
@@ -388,14 +437,16 @@ func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
 		jjj.DeployServiceParams{
 			ServiceName: args.ServiceName,
 			// TODO(dfc) ServiceOwner should be a tag
-			ServiceOwner:   c.api.auth.GetAuthTag().String(),
-			Charm:          ch,
-			NumUnits:       args.NumUnits,
-			ConfigSettings: settings,
-			Constraints:    args.Constraints,
-			ToMachineSpec:  args.ToMachineSpec,
-			Networks:       requestedNetworks,
-			Storage:        storageConstraints,
+			ServiceOwner:      c.api.auth.GetAuthTag().String(),
+			Charm:             ch,
+			NumUnits:          args.NumUnits,
+			ConfigSettings:    settings,
+			Constraints:       args.Constraints,
+			ToMachineSpec:     args.ToMachineSpec,
+			Networks:          requestedNetworks,
+			Storage:           storageConstraints,
+			WorkloadContainer: args.WorkloadContainer,
+			Channel:           state.CharmChannel(args.Channel),
 		})
 	return err
 }
@@ -408,6 +459,21 @@ func (c *Client) ServiceDeployWithNetworks(args params.ServiceDeploy) error {
 	return c.ServiceDeploy(args)
 }
 
+// ServiceDeployWithWorkloadContainer works exactly like ServiceDeploy,
+// but allows specifying the type of container in which the charm of
+// each of the service's units runs, on the machine the unit is
+// assigned to.
+func (c *Client) ServiceDeployWithWorkloadContainer(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
+// ServiceDeployWithChannel works exactly like ServiceDeploy, but
+// allows specifying the charm store channel the service tracks for
+// upgrades of its charm.
+func (c *Client) ServiceDeployWithChannel(args params.ServiceDeploy) error {
+	return c.ServiceDeploy(args)
+}
+
 func validateCharmStorage(args params.ServiceDeploy, ch *state.Charm) error {
 	if len(args.Storage) == 0 {
 		return nil
@@ -447,6 +513,12 @@ func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 			return err
 		}
 	}
+	// Set the maximum number of units for the given service.
+	if args.MaxUnits != nil {
+		if err = service.SetMaxUnits(*args.MaxUnits); err != nil {
+			return err
+		}
+	}
 	// Set up service's settings.
 	if args.SettingsYAML != "" {
 		if err = serviceSetSettingsYAML(service, args.SettingsYAML); err != nil {
@@ -480,9 +552,37 @@ func (c *Client) serviceSetCharm(service *state.Service, url string, force bool)
 	if err != nil {
 		return err
 	}
+	if err := c.checkCharmCompatibility(params.CharmCompatibilityCheck{
+		CharmURL:    url,
+		ServiceName: service.Name(),
+	}); err != nil {
+		return errors.Trace(err)
+	}
 	return service.SetCharm(sch, force)
 }
 
+// checkCharmCompatibility returns an error listing the ways in which
+// the deployment or upgrade described by args is incompatible with the
+// environment, if there are any.
+func (c *Client) checkCharmCompatibility(args params.CharmCompatibilityCheck) error {
+	cfg, err := c.api.state.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	env, err := environs.New(cfg)
+	if err != nil {
+		return errors.Annotate(err, "cannot open environment")
+	}
+	problems, err := common.CheckCharmCompatibility(c.api.state, env, args)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(problems) > 0 {
+		return common.IncompatibleCharmError(args.CharmURL, problems)
+	}
+	return nil
+}
+
 // serviceSetCharm1dot16 sets the charm for the given service in 1.16
 // compatibility mode. Remove this when support for 1.16 is dropped.
 func (c *Client) serviceSetCharm1dot16(service *state.Service, curl *charm.URL, force bool) error {
@@ -561,11 +661,30 @@ func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
 			return errors.Trace(err)
 		}
 	}
+	channel := state.CharmChannel(args.Channel)
+	if channel != "" {
+		if err := channel.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	service, err := c.api.state.Service(args.ServiceName)
 	if err != nil {
 		return err
 	}
-	return c.serviceSetCharm(service, args.CharmUrl, args.Force)
+	if err := c.serviceSetCharm(service, args.CharmUrl, args.Force); err != nil {
+		return err
+	}
+	if channel != "" {
+		return service.SetChannel(channel)
+	}
+	return nil
+}
+
+// ServiceSetCharmWithChannel works exactly like ServiceSetCharm, but
+// also allows specifying the charm store channel the service tracks
+// for upgrades of its charm from then on.
+func (c *Client) ServiceSetCharmWithChannel(args params.ServiceSetCharm) error {
+	return c.ServiceSetCharm(args)
 }
 
 // addServiceUnits adds a given number of units to a service.
@@ -667,23 +786,60 @@ func (c *Client) GetEnvironmentConstraints() (params.GetConstraintsResults, erro
 
 // SetServiceConstraints sets the constraints for a given service.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
-func (c *Client) SetServiceConstraints(args params.SetConstraints) error {
+//
+// Any constraints which are not supported by the environment's
+// provider are still set, but are reported as warnings in the result
+// because they will be ignored when provisioning.
+func (c *Client) SetServiceConstraints(args params.SetConstraints) (params.SetConstraintsResult, error) {
 	if err := c.check.ChangeAllowed(); err != nil {
-		return errors.Trace(err)
+		return params.SetConstraintsResult{}, errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(args.ServiceName)
 	if err != nil {
-		return err
+		return params.SetConstraintsResult{}, err
 	}
-	return svc.SetConstraints(args.Constraints)
+	result, err := c.constraintsWarnings(args.Constraints)
+	if err != nil {
+		return params.SetConstraintsResult{}, err
+	}
+	if err := svc.SetConstraints(args.Constraints); err != nil {
+		return params.SetConstraintsResult{}, err
+	}
+	return result, nil
 }
 
 // SetEnvironmentConstraints sets the constraints for the environment.
-func (c *Client) SetEnvironmentConstraints(args params.SetConstraints) error {
+// Unsupported constraints are reported as for SetServiceConstraints.
+func (c *Client) SetEnvironmentConstraints(args params.SetConstraints) (params.SetConstraintsResult, error) {
 	if err := c.check.ChangeAllowed(); err != nil {
-		return errors.Trace(err)
+		return params.SetConstraintsResult{}, errors.Trace(err)
+	}
+	result, err := c.constraintsWarnings(args.Constraints)
+	if err != nil {
+		return params.SetConstraintsResult{}, err
+	}
+	if err := c.api.state.SetEnvironConstraints(args.Constraints); err != nil {
+		return params.SetConstraintsResult{}, err
+	}
+	return result, nil
+}
+
+// constraintsWarnings validates the given constraints against the
+// environment's provider, and returns a warning for each constraint
+// attribute which the provider does not support.
+func (c *Client) constraintsWarnings(cons constraints.Value) (params.SetConstraintsResult, error) {
+	unsupported, err := c.api.state.ValidateConstraints(cons)
+	if err != nil {
+		return params.SetConstraintsResult{}, err
 	}
-	return c.api.state.SetEnvironConstraints(args.Constraints)
+	var result params.SetConstraintsResult
+	for _, attr := range unsupported {
+		result.Warnings = append(result.Warnings, params.ConstraintsWarning{
+			Attribute: attr,
+			Message:   fmt.Sprintf("constraint %q is not supported by this environment and will be ignored", attr),
+		})
+	}
+	return result, nil
 }
 
 // AddRelation adds a relation between the specified endpoints and returns the relation info.
@@ -884,10 +1040,22 @@ func machineJobFromParams(job multiwatcher.MachineJob) (state.MachineJob, error)
 // provisions a machine agent on the machine executing the script.
 func (c *Client) ProvisioningScript(args params.ProvisioningScriptParams) (params.ProvisioningScriptResult, error) {
 	var result params.ProvisioningScriptResult
-	mcfg, err := MachineConfig(c.api.state, args.MachineId, args.Nonce, args.DataDir)
+	script, err := MachineProvisioningScript(c.api.state, args)
 	if err != nil {
 		return result, err
 	}
+	result.Script = script
+	return result, nil
+}
+
+// MachineProvisioningScript returns a shell script that, when run,
+// provisions the machine agent for the specified machine on an
+// existing host.
+func MachineProvisioningScript(st *state.State, args params.ProvisioningScriptParams) (string, error) {
+	mcfg, err := MachineConfig(st, args.MachineId, args.Nonce, args.DataDir)
+	if err != nil {
+		return "", err
+	}
 
 	// Until DisablePackageCommands is retired, for backwards
 	// compatibility, we must respect the client's request and
@@ -898,19 +1066,82 @@ func (c *Client) ProvisioningScript(args params.ProvisioningScriptParams) (param
 	if args.DisablePackageCommands {
 		mcfg.EnableOSRefreshUpdate = false
 		mcfg.EnableOSUpgrade = false
-	} else if cfg, err := c.api.state.EnvironConfig(); err != nil {
-		return result, err
+	} else if cfg, err := st.EnvironConfig(); err != nil {
+		return "", err
 	} else {
 		mcfg.EnableOSUpgrade = cfg.EnableOSUpgrade()
 		mcfg.EnableOSRefreshUpdate = cfg.EnableOSRefreshUpdate()
 	}
 
-	result.Script, err = manual.ProvisioningScript(mcfg)
-	return result, err
+	return manual.ProvisioningScript(mcfg)
+}
+
+// AddManualHosts requests that the state server provision each of the
+// given existing hosts over SSH, and returns the ids of the requests.
+// The progress of each request may be followed with ManualHostStatus.
+func (c *Client) AddManualHosts(args params.AddManualHostArgs) (params.AddManualHostResults, error) {
+	results := params.AddManualHostResults{
+		Results: make([]params.AddManualHostResult, len(args.Hosts)),
+	}
+	if err := c.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, arg := range args.Hosts {
+		host, err := c.api.state.AddManualHost(arg.Host, arg.DisablePackageCommands)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Id = host.Id()
+	}
+	return results, nil
+}
+
+// ManualHostStatus returns the progress of each of the given manual
+// provisioning requests.
+func (c *Client) ManualHostStatus(args params.ManualHostIds) (params.ManualHostStatusResults, error) {
+	results := params.ManualHostStatusResults{
+		Results: make([]params.ManualHostStatusResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		host, err := c.api.state.ManualHost(id)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		status, message := host.Status()
+		machineId, _ := host.Machine()
+		results.Results[i].Status = params.ManualHostStatus{
+			Id:        host.Id(),
+			Host:      host.Host(),
+			Status:    string(status),
+			Message:   message,
+			Attempts:  host.Attempts(),
+			MachineId: machineId,
+		}
+	}
+	return results, nil
 }
 
-// DestroyMachines removes a given set of machines.
+// DestroyMachines removes a given set of machines. Unless forced, the
+// destroy-machine prechecks must pass for every machine before any is
+// removed. Machines to be evacuated are destroyed only once their units
+// have been replaced elsewhere.
 func (c *Client) DestroyMachines(args params.DestroyMachines) error {
+	if args.Force && args.Evacuate {
+		return errors.New("cannot both force and evacuate machine removal")
+	}
+	if !args.Force {
+		var tags []names.Tag
+		for _, id := range args.MachineNames {
+			if names.IsValidMachine(id) {
+				tags = append(tags, names.NewMachineTag(id))
+			}
+		}
+		if err := c.precheck(params.PrecheckDestroyMachine, tags); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	var errs []string
 	for _, id := range args.MachineNames {
 		machine, err := c.api.state.Machine(id)
@@ -922,6 +1153,11 @@ func (c *Client) DestroyMachines(args params.DestroyMachines) error {
 			err = machine.ForceDestroy()
 		case machine.Life() != state.Alive:
 			continue
+		case args.Evacuate:
+			if err := c.check.RemoveAllowed(); err != nil {
+				return errors.Trace(err)
+			}
+			err = machine.Evacuate()
 		default:
 			{
 				if err := c.check.RemoveAllowed(); err != nil {
@@ -937,6 +1173,39 @@ func (c *Client) DestroyMachines(args params.DestroyMachines) error {
 	return destroyErr("machines", args.MachineNames, errs)
 }
 
+// Precheck runs the prechecks of the given operation against the given
+// entities without performing the operation, and reports any problems
+// which would prevent it going ahead unforced.
+func (c *Client) Precheck(args params.PrecheckArgs) (params.PrecheckReport, error) {
+	tags, err := c.precheckEntities(args)
+	if err != nil {
+		return params.PrecheckReport{}, errors.Trace(err)
+	}
+	return common.RunPrechecks(c.api.state, args.Operation, tags)
+}
+
+func (c *Client) precheckEntities(args params.PrecheckArgs) ([]names.Tag, error) {
+	switch args.Operation {
+	case params.PrecheckDestroyEnvironment, params.PrecheckUpgrade:
+		return []names.Tag{c.api.state.EnvironTag()}, nil
+	}
+	tags := make([]names.Tag, len(args.Entities))
+	for i, entity := range args.Entities {
+		tag, err := names.ParseTag(entity)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+// precheck returns an error if any of the prechecks of the given
+// operation fail.
+func (c *Client) precheck(op params.PrecheckOperation, tags []names.Tag) error {
+	return common.EnsurePrechecksPass(c.api.state, op, tags)
+}
+
 // CharmInfo returns information about the requested charm.
 func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
 	curl, err := charm.ParseURL(args.CharmURL)
@@ -1167,11 +1436,44 @@ func (c *Client) EnvironmentUnset(args params.EnvironmentUnset) error {
 	return c.api.state.UpdateEnvironConfig(nil, args.Keys, nil)
 }
 
-// SetEnvironAgentVersion sets the environment agent version.
+// SuspendEnvironment suspends the environment's automation, for the
+// given reason, until ResumeEnvironment is called.
+func (c *Client) SuspendEnvironment(args params.SuspendEnvironment) error {
+	if err := c.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	env, err := c.api.state.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return env.Suspend(args.Reason)
+}
+
+// ResumeEnvironment resumes the environment's automation after a call
+// to SuspendEnvironment.
+func (c *Client) ResumeEnvironment() error {
+	if err := c.check.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	env, err := c.api.state.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return env.Resume()
+}
+
+// SetEnvironAgentVersion sets the environment agent version. Unless
+// forced, the upgrade prechecks must pass first.
 func (c *Client) SetEnvironAgentVersion(args params.SetEnvironAgentVersion) error {
 	if err := c.check.ChangeAllowed(); err != nil {
 		return errors.Trace(err)
 	}
+	if !args.Force {
+		tags := []names.Tag{c.api.state.EnvironTag()}
+		if err := c.precheck(params.PrecheckUpgrade, tags); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return c.api.state.SetEnvironAgentVersion(args.Version)
 }
 
@@ -1265,8 +1567,13 @@ func (c *Client) AddCharm(args params.CharmURL) error {
 	)
 }
 
-// StoreCharmArchive stores a charm archive in environment storage.
+// StoreCharmArchive stores a charm archive in environment storage. It
+// fails if the archive would take the environment over its charm
+// storage quota.
 func StoreCharmArchive(st *state.State, curl *charm.URL, ch charm.Charm, r io.Reader, size int64, sha256 string) error {
+	if err := st.CheckCharmStorageQuota(size); err != nil {
+		return errors.Annotatef(err, "cannot store charm %q", curl)
+	}
 	storage := newStateStorage(st.EnvironUUID(), st.MongoSession())
 	storagePath, err := charmArchiveStoragePath(curl)
 	if err != nil {
@@ -1277,7 +1584,7 @@ func StoreCharmArchive(st *state.State, curl *charm.URL, ch charm.Charm, r io.Re
 	}
 
 	// Now update the charm data in state and mark it as no longer pending.
-	_, err = st.UpdateUploadedCharm(ch, curl, storagePath, sha256)
+	_, err = st.UpdateUploadedCharm(ch, curl, storagePath, sha256, size)
 	if err != nil {
 		alreadyUploaded := err == state.ErrCharmRevisionAlreadyModified ||
 			errors.Cause(err) == state.ErrCharmRevisionAlreadyModified ||
@@ -1298,6 +1605,10 @@ func StoreCharmArchive(st *state.State, curl *charm.URL, ch charm.Charm, r io.Re
 	return nil
 }
 
+// ResolveCharms resolves the series of the given charm references with
+// the charm store. If a channel is given, the references are resolved
+// from that channel, and those without a revision are resolved to the
+// latest revision published to it.
 func (c *Client) ResolveCharms(args params.ResolveCharms) (params.ResolveCharmResults, error) {
 	var results params.ResolveCharmResults
 
@@ -1306,10 +1617,20 @@ func (c *Client) ResolveCharms(args params.ResolveCharms) (params.ResolveCharmRe
 		return params.ResolveCharmResults{}, err
 	}
 	config.SpecializeCharmRepo(CharmStore, envConfig)
+	repo := CharmStore
+	if args.Channel != "" {
+		repo, err = common.CharmRepoForChannel(CharmStore, state.CharmChannel(args.Channel))
+		if err != nil {
+			return params.ResolveCharmResults{}, errors.Trace(err)
+		}
+	}
 
 	for _, ref := range args.References {
 		result := params.ResolveCharmResult{}
-		curl, err := c.resolveCharm(&ref, CharmStore)
+		curl, err := c.resolveCharm(&ref, repo)
+		if err == nil && args.Channel != "" && curl.Revision < 0 {
+			curl, err = resolveLatestRevision(repo, curl)
+		}
 		if err != nil {
 			result.Error = err.Error()
 		} else {
@@ -1329,6 +1650,16 @@ func (c *Client) resolveCharm(ref *charm.Reference, repo charm.Repository) (*cha
 	return repo.Resolve(ref)
 }
 
+// resolveLatestRevision returns the URL of the latest revision of the
+// given charm available in the repository.
+func resolveLatestRevision(repo charm.Repository, curl *charm.URL) (*charm.URL, error) {
+	revision, err := charm.Latest(repo, curl)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot find latest revision of charm %q", curl)
+	}
+	return curl.WithRevision(revision), nil
+}
+
 // charmArchiveStoragePath returns a string that is suitable as a
 // storage path, using a random UUID to avoid colliding with concurrent
 // uploads.
@@ -1354,6 +1685,292 @@ func (c *Client) RetryProvisioning(p params.Entities) (params.ErrorResults, erro
 	})
 }
 
+// RetryProvisioningWithOverrides applies any given changes to the
+// constraints, series and availability zone used to provision each
+// machine, then marks its provisioning error as transient so the
+// provisioner retries it with the new parameters.
+func (c *Client) RetryProvisioningWithOverrides(args params.RetryProvisioningArgs) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Machines)),
+	}
+	if err := c.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, arg := range args.Machines {
+		if err := c.applyProvisioningOverrides(arg); err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		retried, err := c.api.statusSetter.UpdateStatus(params.SetStatus{
+			Entities: []params.EntityStatus{{
+				Tag:  arg.Tag,
+				Data: map[string]interface{}{"transient": true},
+			}},
+		})
+		if err != nil {
+			return results, errors.Trace(err)
+		}
+		results.Results[i] = retried.Results[0]
+	}
+	return results, nil
+}
+
+// applyProvisioningOverrides changes the parameters used to provision
+// the machine as requested. Nothing is changed unless the machine
+// failed to provision.
+func (c *Client) applyProvisioningOverrides(arg params.RetryProvisioningArg) error {
+	tag, err := names.ParseMachineTag(arg.Tag)
+	if err != nil {
+		return err
+	}
+	machine, err := c.api.state.Machine(tag.Id())
+	if err != nil {
+		return err
+	}
+	if arg.Constraints == nil && arg.Series == "" && arg.Zone == "" {
+		return nil
+	}
+	status, _, _, err := machine.Status()
+	if err != nil {
+		return err
+	}
+	if status != state.StatusError {
+		return errors.Errorf("%s is not in an error state", names.ReadableString(tag))
+	}
+	if arg.Zone != "" && machine.IsContainer() {
+		return errors.Errorf("cannot select availability zone for container %s", machine.Id())
+	}
+	if arg.Constraints != nil {
+		if err := machine.SetConstraints(*arg.Constraints); err != nil {
+			return err
+		}
+	}
+	if arg.Series != "" {
+		if err := machine.SetSeries(arg.Series); err != nil {
+			return err
+		}
+	}
+	if arg.Zone != "" {
+		if err := machine.SetPlacement("zone=" + arg.Zone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshMachineHardware asks the agents of the given machines to
+// re-detect their hardware characteristics.
+func (c *Client) RefreshMachineHardware(p params.Entities) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(p.Entities)),
+	}
+	if err := c.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, entity := range p.Entities {
+		tag, err := names.ParseMachineTag(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		machine, err := c.api.state.Machine(tag.Id())
+		if err == nil {
+			err = machine.RequestHardwareRefresh()
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// HookQueues returns the hooks the agents of the given units last
+// reported they have yet to run, and those of them they have been
+// asked not to run.
+func (c *Client) HookQueues(p params.Entities) (params.HookQueueResults, error) {
+	results := params.HookQueueResults{
+		Results: make([]params.HookQueueResult, len(p.Entities)),
+	}
+	for i, entity := range p.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		unit, err := c.api.state.Unit(tag.Id())
+		if err == nil {
+			results.Results[i], err = common.UnitHookQueue(unit)
+		}
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return results, nil
+}
+
+// HookResults returns the outcome, and the tail of the output, of the
+// hook the agent of each given unit ran most recently.
+func (c *Client) HookResults(p params.Entities) (params.HookResultResults, error) {
+	results := params.HookResultResults{
+		Results: make([]params.HookResultResult, len(p.Entities)),
+	}
+	for i, entity := range p.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		unit, err := c.api.state.Unit(tag.Id())
+		if err == nil {
+			results.Results[i].Result, err = common.UnitHookResult(unit)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// UnitRelationStates returns the bookkeeping the agent of each given
+// unit keeps for each of the unit's relations, recording which relation
+// hooks it has run.
+func (c *Client) UnitRelationStates(p params.Entities) (params.UnitRelationStatesResults, error) {
+	results := params.UnitRelationStatesResults{
+		Results: make([]params.UnitRelationStatesResult, len(p.Entities)),
+	}
+	for i, entity := range p.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		unit, err := c.api.state.Unit(tag.Id())
+		if err == nil {
+			results.Results[i].States, err = common.UnitRelationStates(unit)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// DropQueuedHooks asks the agents of the given units not to run the
+// identified queued hooks.
+func (c *Client) DropQueuedHooks(args params.DropQueuedHookArgs) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	if err := c.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, arg := range args.Args {
+		tag, err := names.ParseUnitTag(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		unit, err := c.api.state.Unit(tag.Id())
+		if err == nil {
+			err = unit.DropQueuedHook(arg.HookId)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// AddContainerAddressPools adds address pools for the containers
+// attached to bridges on host machines, which are used when the
+// provider cannot allocate container addresses.
+func (c *Client) AddContainerAddressPools(args params.ContainerAddressPools) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Pools)),
+	}
+	if err := c.check.ChangeAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, pool := range args.Pools {
+		tag, err := names.ParseMachineTag(pool.MachineTag)
+		if err == nil {
+			_, err = c.api.state.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+				MachineId: tag.Id(),
+				Bridge:    pool.Bridge,
+				CIDR:      pool.CIDR,
+				Gateway:   pool.Gateway,
+				Reserved:  pool.Reserved,
+			})
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// RemoveContainerAddressPools removes the address pools for the given
+// bridges. Pools with addresses allocated from them cannot be removed.
+func (c *Client) RemoveContainerAddressPools(args params.ContainerAddressPools) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Pools)),
+	}
+	if err := c.check.RemoveAllowed(); err != nil {
+		return results, errors.Trace(err)
+	}
+	for i, pool := range args.Pools {
+		tag, err := names.ParseMachineTag(pool.MachineTag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		statePool, err := c.api.state.ContainerAddressPool(tag.Id(), pool.Bridge)
+		if err == nil {
+			err = statePool.Remove()
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// ContainerAddressPools returns the address pools for the bridges on
+// the given machines, and the addresses allocated from them.
+func (c *Client) ContainerAddressPools(p params.Entities) (params.ContainerAddressPoolsResults, error) {
+	results := params.ContainerAddressPoolsResults{
+		Results: make([]params.ContainerAddressPoolsResult, len(p.Entities)),
+	}
+	for i, entity := range p.Entities {
+		tag, err := names.ParseMachineTag(entity.Tag)
+		if err == nil {
+			results.Results[i].Pools, err = c.containerAddressPools(tag)
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+func (c *Client) containerAddressPools(tag names.MachineTag) ([]params.ContainerAddressPoolInfo, error) {
+	pools, err := c.api.state.ContainerAddressPools(tag.Id())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.ContainerAddressPoolInfo, len(pools))
+	for i, pool := range pools {
+		addresses, err := pool.Addresses()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result[i] = params.ContainerAddressPoolInfo{
+			ContainerAddressPool: params.ContainerAddressPool{
+				MachineTag: tag.String(),
+				Bridge:     pool.Bridge(),
+				CIDR:       pool.CIDR(),
+				Gateway:    pool.Gateway(),
+				Reserved:   pool.Reserved(),
+			},
+			Addresses: make([]params.ContainerPoolAddress, len(addresses)),
+		}
+		for j, address := range addresses {
+			result[i].Addresses[j] = params.ContainerPoolAddress{
+				MachineTag: names.NewMachineTag(address.MachineId()).String(),
+				Address:    address.Value(),
+				Hostname:   address.Hostname(),
+			}
+		}
+	}
+	return result, nil
+}
+
 // APIHostPorts returns the API host/port addresses stored in state.
 func (c *Client) APIHostPorts() (result params.APIHostPortsResult, err error) {
 	var servers [][]network.HostPort
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -47,6 +48,7 @@ import (
 	"github.com/juju/juju/testcharms"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/testing/factory"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 	"github.com/juju/juju/version"
 )
 
@@ -327,6 +329,34 @@ func (s *serverSuite) TestSetEnvironAgentVersion(c *gc.C) {
 	c.Assert(agentVersion, gc.Equals, "9.8.7")
 }
 
+func (s *serverSuite) TestDestroyMachinesForceAndEvacuate(c *gc.C) {
+	err := s.client.DestroyMachines(params.DestroyMachines{
+		MachineNames: []string{"1"},
+		Force:        true,
+		Evacuate:     true,
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot both force and evacuate machine removal")
+}
+
+func (s *serverSuite) TestSetEnvironAgentVersionPrecheckFailed(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.SetStatus(state.StatusError, "oops", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	args := params.SetEnvironAgentVersion{
+		Version: version.MustParse("9.8.7"),
+	}
+	err = s.client.SetEnvironAgentVersion(args)
+	c.Assert(err, jc.Satisfies, params.IsCodePrecheckFailed)
+	c.Assert(err, gc.ErrorMatches, "upgrade prechecks failed:\n.*machine "+machine.Id()+" agent is in error: oops")
+
+	args.Force = true
+	err = s.client.SetEnvironAgentVersion(args)
+	c.Assert(err, jc.ErrorIsNil)
+	envConfig, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envConfig.AllAttrs()["agent-version"], gc.Equals, "9.8.7")
+}
+
 func (s *serverSuite) assertSetEnvironAgentVersion(c *gc.C) {
 	args := params.SetEnvironAgentVersion{
 		Version: version.MustParse("9.8.7"),
@@ -1065,6 +1095,19 @@ func (s *clientSuite) TestClientServiceExpose(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestClientServiceExposeToCIDRs(c *gc.C) {
+	s.AddTestingService(c, "dummy-service", s.AddTestingCharm(c, "dummy"))
+	err := s.APIState.Client().ServiceExpose("dummy-service", "192.168.0.0/16", "10.0.0.0/8")
+	c.Assert(err, jc.ErrorIsNil)
+	service, err := s.State.Service("dummy-service")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.IsExposed(), jc.IsTrue)
+	c.Assert(service.ExposedCIDRs(), jc.DeepEquals, []string{"10.0.0.0/8", "192.168.0.0/16"})
+
+	err = s.APIState.Client().ServiceExpose("dummy-service", "invalid")
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "dummy-service": CIDR "invalid" not valid`)
+}
+
 func (s *clientSuite) setupServiceExpose(c *gc.C) {
 	charm := s.AddTestingCharm(c, "dummy")
 	serviceNames := []string{"dummy-service", "exposed-service"}
@@ -1299,6 +1342,48 @@ func (s *clientSuite) TestForceDestroyMachines(c *gc.C) {
 	s.assertForceDestroyMachines(c)
 }
 
+func (s *clientSuite) TestEvacuateMachines(c *gc.C) {
+	m0, m1, _, u := s.setupDestroyMachinesTest(c)
+	err := s.APIState.Client().EvacuateMachines("0", "1")
+	c.Assert(err, gc.ErrorMatches, `some machines were not destroyed: cannot evacuate machine 0: machine is required by the environment`)
+	assertLife(c, m0, state.Alive)
+	// The machine and its unit are left alone until the unit is
+	// replaced elsewhere.
+	assertLife(c, m1, state.Alive)
+	assertLife(c, u, state.Alive)
+	evacuating, err := m1.Evacuating()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuating, jc.IsTrue)
+}
+
+func (s *clientSuite) TestDestroyMachinesPrecheckFailed(c *gc.C) {
+	_, m1, m2, _ := s.setupDestroyMachinesTest(c)
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	_, err := s.State.AddMachineInsideMachine(template, m2.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.APIState.Client().DestroyMachines("1", "2")
+	c.Assert(err, jc.Satisfies, params.IsCodePrecheckFailed)
+	c.Assert(err, gc.ErrorMatches, "destroy-machine prechecks failed:\nmachine-2: machine hosts containers 2/lxc/0")
+	// No machine is destroyed if any fails its prechecks.
+	assertLife(c, m1, state.Alive)
+	assertLife(c, m2, state.Alive)
+
+	report, err := s.APIState.Client().Precheck(params.PrecheckDestroyMachine, "machine-1", "machine-2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report, jc.DeepEquals, params.PrecheckReport{
+		Operation: params.PrecheckDestroyMachine,
+		Problems: []params.PrecheckProblem{{
+			Check:   "containers",
+			Entity:  "machine-2",
+			Message: "machine hosts containers 2/lxc/0",
+		}},
+	})
+}
+
 func (s *clientSuite) TestDestroyPrincipalUnits(c *gc.C) {
 	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
 	units := make([]*state.Unit, 5)
@@ -1454,6 +1539,58 @@ func (s *clientSuite) TestClientServiceDeployWithNetworks(c *gc.C) {
 	c.Assert(serviceCons, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientServiceDeployWithWorkloadContainer(c *gc.C) {
+	s.makeMockCharmStore()
+	curl, bundle := addCharm(c, "dummy")
+	err := s.APIState.Client().ServiceDeployWithWorkloadContainer(params.ServiceDeploy{
+		ServiceName:       "service",
+		CharmUrl:          curl.String(),
+		NumUnits:          1,
+		WorkloadContainer: instance.LXC,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	service := s.assertPrincipalDeployed(c, "service", curl, false, bundle, constraints.Value{})
+	c.Assert(service.WorkloadContainer(), gc.Equals, instance.LXC)
+}
+
+func (s *clientSuite) TestClientServiceDeployWithUnsupportedWorkloadContainer(c *gc.C) {
+	s.makeMockCharmStore()
+	curl, _ := addCharm(c, "dummy")
+	err := s.APIState.Client().ServiceDeployWithWorkloadContainer(params.ServiceDeploy{
+		ServiceName:       "service",
+		CharmUrl:          curl.String(),
+		WorkloadContainer: instance.KVM,
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot set workload container for service "service": workload container type "kvm" not supported`)
+}
+
+func (s *clientSuite) TestClientServiceDeployWithChannel(c *gc.C) {
+	s.makeMockCharmStore()
+	curl, bundle := addCharm(c, "dummy")
+	err := s.APIState.Client().ServiceDeployWithChannel(params.ServiceDeploy{
+		ServiceName: "service",
+		CharmUrl:    curl.String(),
+		NumUnits:    1,
+		Channel:     "edge",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	service := s.assertPrincipalDeployed(c, "service", curl, false, bundle, constraints.Value{})
+	c.Assert(service.Channel(), gc.Equals, state.EdgeChannel)
+}
+
+func (s *clientSuite) TestClientServiceDeployWithInvalidChannel(c *gc.C) {
+	s.makeMockCharmStore()
+	curl, _ := addCharm(c, "dummy")
+	err := s.APIState.Client().ServiceDeployWithChannel(params.ServiceDeploy{
+		ServiceName: "service",
+		CharmUrl:    curl.String(),
+		Channel:     "beta",
+	})
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+	_, err = s.State.Service("service")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *clientSuite) TestClientServiceDeployWithStorage(c *gc.C) {
 	s.setupStoragePool(c)
 	s.testClientServiceDeployWithStorage(c, true)
@@ -1784,6 +1921,25 @@ func (s *clientSuite) TestClientServiceDeployToMachineNotFound(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `service "service-name" not found`)
 }
 
+func (s *clientSuite) TestClientServiceDeployToMachineIncompatibleSeries(c *gc.C) {
+	s.makeMockCharmStore()
+	curl, _ := addCharm(c, "dummy")
+
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.APIState.Client().ServiceDeploy(
+		curl.String(), "service-name", 1, "", constraints.Value{}, machine.Id(),
+	)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		`charm "cs:precise/dummy-1" is not compatible: series: machine %s has series "quantal", charm is for series "precise"`,
+		machine.Id(),
+	))
+	c.Assert(params.IsCodeIncompatibleCharm(err), jc.IsTrue)
+
+	_, err = s.State.Service("service-name")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *clientSuite) TestClientServiceDeployServiceOwner(c *gc.C) {
 	s.makeMockCharmStore()
 	curl, _ := addCharm(c, "dummy")
@@ -1931,6 +2087,24 @@ func (s *clientSuite) TestClientServiceUpdateSetMinUnits(c *gc.C) {
 	c.Assert(service.MinUnits(), gc.Equals, minUnits)
 }
 
+func (s *clientSuite) TestClientServiceUpdateSetMaxUnits(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	maxUnits := 1
+	args := params.ServiceUpdate{
+		ServiceName: "dummy",
+		MaxUnits:    &maxUnits,
+	}
+	err := s.APIState.Client().ServiceUpdate(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.Refresh(), gc.IsNil)
+	c.Assert(service.MaxUnits(), gc.Equals, maxUnits)
+
+	// Adding units beyond the maximum is rejected with a typed error.
+	_, err = s.APIState.Client().AddServiceUnits("dummy", 2, "")
+	c.Assert(err, jc.Satisfies, params.IsCodeUnitLimitExceeded)
+}
+
 func (s *clientSuite) TestClientServiceUpdateSetMinUnitsError(c *gc.C) {
 	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 
@@ -2093,6 +2267,35 @@ func (s *clientSuite) TestClientServiceSetCharm(c *gc.C) {
 	c.Assert(force, jc.IsFalse)
 }
 
+func (s *clientSuite) TestClientServiceSetCharmWithChannel(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	client := s.APIState.Client()
+	err := client.ServiceSetCharmWithChannel("service", "cs:precise/wordpress-3", false, "candidate")
+	c.Assert(err, jc.ErrorIsNil)
+
+	service, err := s.State.Service("service")
+	c.Assert(err, jc.ErrorIsNil)
+	curl, _ := service.CharmURL()
+	c.Assert(curl.String(), gc.Equals, "cs:precise/wordpress-3")
+	c.Assert(service.Channel(), gc.Equals, state.CandidateChannel)
+
+	channel, err := client.ServiceGetCharmChannel("service")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channel, gc.Equals, "candidate")
+}
+
+func (s *clientSuite) TestClientServiceSetCharmWithInvalidChannel(c *gc.C) {
+	s.setupServiceSetCharm(c)
+	err := s.APIState.Client().ServiceSetCharmWithChannel("service", "cs:precise/wordpress-3", false, "beta")
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+
+	// The charm is left unchanged.
+	service, err := s.State.Service("service")
+	c.Assert(err, jc.ErrorIsNil)
+	curl, _ := service.CharmURL()
+	c.Assert(curl.String(), gc.Equals, "cs:precise/dummy-1")
+}
+
 func (s *clientSuite) setupServiceSetCharm(c *gc.C) {
 	s.makeMockCharmStore()
 	curl, _ := addCharm(c, "dummy")
@@ -2291,7 +2494,7 @@ func (s *clientSuite) TestCallWithOnlyOneEndpoint(c *gc.C) {
 	s.setUpScenario(c)
 	endpoints := []string{"wordpress"}
 	_, err := s.APIState.Client().AddRelation(endpoints...)
-	c.Assert(err, gc.ErrorMatches, "no relations found")
+	c.Assert(err, gc.ErrorMatches, `no relations found: service "wordpress" has no peer relations`)
 }
 
 func (s *clientSuite) TestCallWithOneEndpointTooMany(c *gc.C) {
@@ -2363,14 +2566,14 @@ func (s *clientSuite) TestAttemptDestroyingNonExistentRelation(c *gc.C) {
 	s.AddTestingService(c, "riak", s.AddTestingCharm(c, "riak"))
 	endpoints := []string{"riak", "wordpress"}
 	err := s.APIState.Client().DestroyRelation(endpoints...)
-	c.Assert(err, gc.ErrorMatches, "no relations found")
+	c.Assert(err, gc.ErrorMatches, `no relations found: "riak" and "wordpress" only share interfaces they both provide or both require \("http"\)`)
 }
 
 func (s *clientSuite) TestAttemptDestroyingWithOnlyOneEndpoint(c *gc.C) {
 	s.setUpScenario(c)
 	endpoints := []string{"wordpress"}
 	err := s.APIState.Client().DestroyRelation(endpoints...)
-	c.Assert(err, gc.ErrorMatches, "no relations found")
+	c.Assert(err, gc.ErrorMatches, `no relations found: service "wordpress" has no peer relations`)
 }
 
 func (s *clientSuite) TestAttemptDestroyingPeerRelation(c *gc.C) {
@@ -2443,8 +2646,9 @@ func (s *clientSuite) TestClientSetServiceConstraints(c *gc.C) {
 	// Update constraints for the service.
 	cons, err := constraints.Parse("mem=4096", "cpu-cores=2")
 	c.Assert(err, jc.ErrorIsNil)
-	err = s.APIState.Client().SetServiceConstraints("dummy", cons)
+	warnings, err := s.APIState.Client().SetServiceConstraints("dummy", cons)
 	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, gc.HasLen, 0)
 
 	// Ensure the constraints have been correctly updated.
 	obtained, err := service.Constraints()
@@ -2452,6 +2656,34 @@ func (s *clientSuite) TestClientSetServiceConstraints(c *gc.C) {
 	c.Assert(obtained, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientSetServiceConstraintsUnsupported(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	// The dummy provider does not support cpu-power, so it is set
+	// but reported back as a warning.
+	cons := constraints.MustParse("mem=4096", "cpu-power=100")
+	warnings, err := s.APIState.Client().SetServiceConstraints("dummy", cons)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, jc.DeepEquals, []params.ConstraintsWarning{{
+		Attribute: "cpu-power",
+		Message:   `constraint "cpu-power" is not supported by this environment and will be ignored`,
+	}})
+	obtained, err := service.Constraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, gc.DeepEquals, cons)
+}
+
+func (s *clientSuite) TestClientSetServiceConstraintsConflicting(c *gc.C) {
+	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
+
+	cons := constraints.MustParse("mem=4096", "instance-type=foo", "cpu-power=100")
+	_, err := s.APIState.Client().SetServiceConstraints("dummy", cons)
+	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
+	obtained, err := service.Constraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(&obtained, jc.Satisfies, constraints.IsEmpty)
+}
+
 func (s *clientSuite) setupSetServiceConstraints(c *gc.C) (*state.Service, constraints.Value) {
 	service := s.AddTestingService(c, "dummy", s.AddTestingCharm(c, "dummy"))
 	// Update constraints for the service.
@@ -2461,7 +2693,7 @@ func (s *clientSuite) setupSetServiceConstraints(c *gc.C) (*state.Service, const
 }
 
 func (s *clientSuite) assertSetServiceConstraints(c *gc.C, service *state.Service, cons constraints.Value) {
-	err := s.APIState.Client().SetServiceConstraints("dummy", cons)
+	_, err := s.APIState.Client().SetServiceConstraints("dummy", cons)
 	c.Assert(err, jc.ErrorIsNil)
 	// Ensure the constraints have been correctly updated.
 	obtained, err := service.Constraints()
@@ -2470,7 +2702,7 @@ func (s *clientSuite) assertSetServiceConstraints(c *gc.C, service *state.Servic
 }
 
 func (s *clientSuite) assertSetServiceConstraintsBlocked(c *gc.C, msg string, service *state.Service, cons constraints.Value) {
-	err := s.APIState.Client().SetServiceConstraints("dummy", cons)
+	_, err := s.APIState.Client().SetServiceConstraints("dummy", cons)
 	s.AssertBlocked(c, err, msg)
 }
 
@@ -2511,8 +2743,9 @@ func (s *clientSuite) TestClientSetEnvironmentConstraints(c *gc.C) {
 	// Set constraints for the environment.
 	cons, err := constraints.Parse("mem=4096", "cpu-cores=2")
 	c.Assert(err, jc.ErrorIsNil)
-	err = s.APIState.Client().SetEnvironmentConstraints(cons)
+	warnings, err := s.APIState.Client().SetEnvironmentConstraints(cons)
 	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, gc.HasLen, 0)
 
 	// Ensure the constraints have been correctly updated.
 	obtained, err := s.State.EnvironConstraints()
@@ -2520,11 +2753,23 @@ func (s *clientSuite) TestClientSetEnvironmentConstraints(c *gc.C) {
 	c.Assert(obtained, gc.DeepEquals, cons)
 }
 
+func (s *clientSuite) TestClientSetEnvironmentConstraintsUnsupported(c *gc.C) {
+	cons := constraints.MustParse("cpu-power=100")
+	warnings, err := s.APIState.Client().SetEnvironmentConstraints(cons)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, gc.HasLen, 1)
+	c.Assert(warnings[0].Attribute, gc.Equals, "cpu-power")
+
+	obtained, err := s.State.EnvironConstraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, gc.DeepEquals, cons)
+}
+
 func (s *clientSuite) assertSetEnvironmentConstraints(c *gc.C) {
 	// Set constraints for the environment.
 	cons, err := constraints.Parse("mem=4096", "cpu-cores=2")
 	c.Assert(err, jc.ErrorIsNil)
-	err = s.APIState.Client().SetEnvironmentConstraints(cons)
+	_, err = s.APIState.Client().SetEnvironmentConstraints(cons)
 	c.Assert(err, jc.ErrorIsNil)
 	// Ensure the constraints have been correctly updated.
 	obtained, err := s.State.EnvironConstraints()
@@ -2536,7 +2781,7 @@ func (s *clientSuite) assertSetEnvironmentConstraintsBlocked(c *gc.C, msg string
 	// Set constraints for the environment.
 	cons, err := constraints.Parse("mem=4096", "cpu-cores=2")
 	c.Assert(err, jc.ErrorIsNil)
-	err = s.APIState.Client().SetEnvironmentConstraints(cons)
+	_, err = s.APIState.Client().SetEnvironmentConstraints(cons)
 	s.AssertBlocked(c, err, msg)
 }
 
@@ -2664,6 +2909,34 @@ func (s *clientSuite) TestClientPrivateAddressUnit(c *gc.C) {
 	c.Assert(addr, gc.Equals, "private")
 }
 
+func (s *clientSuite) TestClientSSHHostKeys(c *gc.C) {
+	s.setUpScenario(c)
+
+	keys, err := s.APIState.Client().SSHHostKeys("1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 0)
+
+	m1, err := s.State.Machine("1")
+	c.Assert(err, jc.ErrorIsNil)
+	hostKeys := []string{sshtesting.ValidKeyOne.Key + " root@host"}
+	err = m1.SetSSHHostKeys(hostKeys)
+	c.Assert(err, jc.ErrorIsNil)
+	keys, err = s.APIState.Client().SSHHostKeys("1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, jc.DeepEquals, hostKeys)
+	keys, err = s.APIState.Client().SSHHostKeys("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, jc.DeepEquals, hostKeys)
+}
+
+func (s *clientSuite) TestClientSSHHostKeysErrors(c *gc.C) {
+	s.setUpScenario(c)
+	_, err := s.APIState.Client().SSHHostKeys("wordpress")
+	c.Assert(err, gc.ErrorMatches, `unknown unit or machine "wordpress"`)
+	_, err = s.APIState.Client().SSHHostKeys("42")
+	c.Assert(err, gc.ErrorMatches, `machine 42 not found`)
+}
+
 func (s *serverSuite) TestClientEnvironmentGet(c *gc.C) {
 	envConfig, err := s.State.EnvironConfig()
 	c.Assert(err, jc.ErrorIsNil)
@@ -3222,6 +3495,38 @@ func (s *clientSuite) TestProvisioningScript(c *gc.C) {
 	}
 }
 
+func (s *clientSuite) TestAddManualHost(c *gc.C) {
+	id, err := s.APIState.Client().AddManualHost("example.com", true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, err := s.APIState.Client().ManualHostStatus(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, params.ManualHostStatus{
+		Id:      id,
+		Host:    "example.com",
+		Status:  params.ManualHostPending,
+		Message: "waiting to be provisioned",
+	})
+	host, err := s.State.ManualHost(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(host.DisablePackageCommands(), jc.IsTrue)
+
+	_, err = s.APIState.Client().AddManualHost("example.com", true)
+	c.Assert(err, gc.ErrorMatches, `provisioning request .* for host "example.com" already exists`)
+}
+
+func (s *clientSuite) TestManualHostStatusNotFound(c *gc.C) {
+	_, err := s.APIState.Client().ManualHostStatus("42")
+	c.Assert(err, gc.ErrorMatches, `manual host "42" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *clientSuite) TestAddManualHostBlocked(c *gc.C) {
+	s.BlockAllChanges(c, "TestAddManualHostBlocked")
+	_, err := s.APIState.Client().AddManualHost("example.com", false)
+	s.AssertBlocked(c, err, "TestAddManualHostBlocked")
+}
+
 func (s *clientSuite) TestProvisioningScriptDisablePackageCommands(c *gc.C) {
 	apiParams := params.AddMachineParams{
 		Jobs:       []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
@@ -3365,6 +3670,32 @@ func (s *clientSuite) TestAddCharm(c *gc.C) {
 	s.assertUploaded(c, storage, sch.StoragePath(), sch.BundleSha256())
 }
 
+func (s *clientSuite) TestAddCharmOverQuota(c *gc.C) {
+	s.makeMockCharmStore()
+
+	var blobs blobs
+	s.PatchValue(client.NewStateStorage, func(uuid string, session *mgo.Session) statestorage.Storage {
+		storage := statestorage.NewStorage(uuid, session)
+		return &recordingStorage{Storage: storage, blobs: &blobs}
+	})
+
+	// Fill the quota with another charm.
+	charmDir := testcharms.Repo.CharmDir("dummy")
+	ident := fmt.Sprintf("%s-%d", charmDir.Meta().Name, charmDir.Revision())
+	stored, err := s.State.PrepareStoreCharmUpload(charm.MustParseURL("cs:quantal/" + ident))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.UpdateUploadedCharm(charmDir, stored.URL(), "dummy-path", ident+"-sha256", 1024*1024)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"charm-storage-quota": 1}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	curl, _ := addCharm(c, "wordpress")
+	err = s.APIState.Client().AddCharm(curl)
+	c.Assert(err, gc.ErrorMatches, `cannot store charm ".*": charm storage quota exceeded: .*`)
+	c.Assert(err, jc.Satisfies, params.IsCodeQuotaLimitExceeded)
+	c.Assert(blobs.m, gc.HasLen, 0)
+}
+
 var resolveCharmCases = []struct {
 	schema, defaultSeries, charmName string
 	parseErr                         string
@@ -3414,6 +3745,80 @@ func (s *clientSuite) TestResolveCharm(c *gc.C) {
 	}
 }
 
+// channelCharmStore is a charm store publishing a different latest
+// revision of each charm to each channel.
+type channelCharmStore struct {
+	charm.Repository
+	channel   string
+	revisions map[string]int
+}
+
+func (s *channelCharmStore) WithJujuAttrs(attrs string) charm.Repository {
+	store := *s
+	store.channel = strings.TrimPrefix(attrs, "channel=")
+	return &store
+}
+
+func (s *channelCharmStore) Latest(curls ...*charm.URL) ([]charm.CharmRevision, error) {
+	revisions := make([]charm.CharmRevision, len(curls))
+	for i := range curls {
+		revisions[i].Revision = s.revisions[s.channel]
+	}
+	return revisions, nil
+}
+
+func (s *clientSuite) TestResolveCharmWithChannel(c *gc.C) {
+	mockStore := s.makeMockCharmStore()
+	mockStore.SetDefaultSeries("trusty")
+	s.PatchValue(&client.CharmStore, &channelCharmStore{
+		Repository: mockStore,
+		revisions:  map[string]int{"candidate": 7, "edge": 9},
+	})
+	apiClient := s.APIState.Client()
+
+	ref, err := charm.ParseReference("cs:wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	curl, err := apiClient.ResolveCharmWithChannel(ref, "edge")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-9")
+
+	curl, err = apiClient.ResolveCharmWithChannel(ref, "candidate")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-7")
+
+	// An explicit revision is kept.
+	revisionRef, err := charm.ParseReference("cs:wordpress-3")
+	c.Assert(err, jc.ErrorIsNil)
+	curl, err = apiClient.ResolveCharmWithChannel(revisionRef, "edge")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress-3")
+
+	// Without a channel, the revision is not resolved.
+	curl, err = apiClient.ResolveCharm(ref)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(curl.String(), gc.Equals, "cs:trusty/wordpress")
+
+	_, err = apiClient.ResolveCharmWithChannel(ref, "beta")
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+}
+
+func (s *clientSuite) TestClientStatusChannelUpgrade(c *gc.C) {
+	ch := s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress", URL: "cs:quantal/wordpress-3"})
+	service := s.Factory.MakeService(c, &factory.ServiceParams{Charm: ch})
+	err := service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, err := s.APIState.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Services[service.Name()].CanUpgradeTo, gc.Equals, "")
+
+	err = service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/wordpress-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.APIState.Client().Status(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Services[service.Name()].CanUpgradeTo, gc.Equals, "cs:quantal/wordpress-5")
+}
+
 type blobs struct {
 	sync.Mutex
 	m map[string]bool // maps path to added (true), or deleted (false)
@@ -3614,6 +4019,283 @@ func (s *clientSuite) TestBlockChangesRetryProvisioning(c *gc.C) {
 	s.assertRetryProvisioningBlocked(c, m, "TestBlockChangesRetryProvisioning")
 }
 
+func (s *clientSuite) TestSuspendResumeEnvironment(c *gc.C) {
+	err := s.APIState.Client().SuspendEnvironment("provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "provider outage")
+
+	err = s.APIState.Client().ResumeEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, _ = env.Suspended()
+	c.Assert(suspended, jc.IsFalse)
+}
+
+func (s *clientSuite) TestBlockChangesSuspendEnvironment(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesSuspendEnvironment")
+	err := s.APIState.Client().SuspendEnvironment("")
+	s.AssertBlocked(c, err, "TestBlockChangesSuspendEnvironment")
+}
+
+func (s *clientSuite) TestRetryProvisioningWithOverrides(c *gc.C) {
+	machine := s.setupRetryProvisioning(c)
+	pending, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cons := constraints.MustParse("mem=8G")
+	results, err := s.APIState.Client().RetryProvisioningWithOverrides(
+		params.RetryProvisioningArg{
+			Tag:         machine.Tag().String(),
+			Constraints: &cons,
+			Series:      "trusty",
+			Zone:        "a-zone",
+		},
+		params.RetryProvisioningArg{
+			Tag:    pending.Tag().String(),
+			Series: "trusty",
+		},
+		params.RetryProvisioningArg{Tag: "machine-42"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 3)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[1].Error, gc.ErrorMatches, "machine 1 is not in an error state")
+	c.Assert(results[2].Error, gc.ErrorMatches, "machine 42 not found")
+
+	err = machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, data, err := machine.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data["transient"], jc.IsTrue)
+	mcons, err := machine.Constraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mcons, gc.DeepEquals, cons)
+	c.Assert(machine.Series(), gc.Equals, "trusty")
+	c.Assert(machine.Placement(), gc.Equals, "zone=a-zone")
+
+	err = pending.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending.Series(), gc.Equals, "quantal")
+}
+
+func (s *clientSuite) TestRetryProvisioningWithOverridesContainerZone(c *gc.C) {
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideNewMachine(template, template, instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	err = container.SetStatus(state.StatusError, "error", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.APIState.Client().RetryProvisioningWithOverrides(params.RetryProvisioningArg{
+		Tag:  container.Tag().String(),
+		Zone: "a-zone",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, gc.ErrorMatches, "cannot select availability zone for container 0/lxc/0")
+}
+
+func (s *clientSuite) TestBlockChangesRetryProvisioningWithOverrides(c *gc.C) {
+	m := s.setupRetryProvisioning(c)
+	s.BlockAllChanges(c, "TestBlockChangesRetryProvisioningWithOverrides")
+	_, err := s.APIState.Client().RetryProvisioningWithOverrides(params.RetryProvisioningArg{
+		Tag:    m.Tag().String(),
+		Series: "trusty",
+	})
+	s.AssertBlocked(c, err, "TestBlockChangesRetryProvisioningWithOverrides")
+}
+
+func (s *clientSuite) TestRefreshMachineHardware(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioned("i-0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unprovisioned, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.APIState.Client().RefreshMachineHardware(
+		machine.Tag().(names.MachineTag),
+		unprovisioned.Tag().(names.MachineTag),
+		names.NewMachineTag("42"),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 3)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(results[1].Error, gc.ErrorMatches, `cannot request hardware refresh for machine 1: machine 1 not provisioned`)
+	c.Assert(results[2].Error, gc.ErrorMatches, `machine 42 not found`)
+
+	err = machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.HardwareRefreshRequested(), jc.IsTrue)
+}
+
+func (s *clientSuite) TestBlockChangesRefreshMachineHardware(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesRefreshMachineHardware")
+	_, err := s.APIState.Client().RefreshMachineHardware(names.NewMachineTag("0"))
+	s.AssertBlocked(c, err, "TestBlockChangesRefreshMachineHardware")
+}
+
+func (s *clientSuite) TestUnitHookQueue(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetHookQueue(&state.QueuedHook{Kind: "config-changed"}, []state.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/1"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.APIState.Client().DropQueuedHook("wordpress/0", "relation-changed:0:mysql/1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.APIState.Client().DropQueuedHook("wordpress/0", "config-changed")
+	c.Assert(err, gc.ErrorMatches, `cannot drop hook "config-changed" for unit "wordpress/0": queued hook "config-changed" not found`)
+
+	queue, err := s.APIState.Client().UnitHookQueue("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	dropped := params.QueuedHook{
+		Id:         "relation-changed:0:mysql/1",
+		Kind:       "relation-changed",
+		RelationId: 0,
+		RemoteUnit: "mysql/1",
+	}
+	c.Assert(queue, jc.DeepEquals, params.HookQueueResult{
+		Pending: &params.QueuedHook{Id: "config-changed", Kind: "config-changed"},
+		Queued: []params.QueuedHook{{
+			Id:         "relation-changed:0:mysql/0",
+			Kind:       "relation-changed",
+			RelationId: 0,
+			RemoteUnit: "mysql/0",
+		}, dropped},
+		Dropped: []params.QueuedHook{dropped},
+	})
+
+	_, err = s.APIState.Client().UnitHookQueue("wordpress/42")
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/42" not found`)
+}
+
+func (s *clientSuite) TestUnitHookResult(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.APIState.Client().UnitHookResult("wordpress/0")
+	c.Assert(err, gc.ErrorMatches, `hook result for unit "wordpress/0" not found`)
+	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
+
+	finished := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	err = unit.SetHookResult(state.HookResult{
+		Hook:   "install",
+		Time:   finished,
+		Error:  "exit status 1",
+		Output: "E: Unable to locate package wordpress\n",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.APIState.Client().UnitHookResult("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.HookResult{
+		Hook:   "install",
+		Time:   finished,
+		Error:  "exit status 1",
+		Output: "E: Unable to locate package wordpress\n",
+	})
+
+	_, err = s.APIState.Client().UnitHookResult("wordpress/42")
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/42" not found`)
+}
+
+func (s *clientSuite) TestUnitRelationStates(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	states, err := s.APIState.Client().UnitRelationStates("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+
+	err = unit.SetRelationState(state.UnitRelationState{
+		RelationId: 0,
+		Members:    map[string]int64{"mysql/0": 2},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	states, err = s.APIState.Client().UnitRelationStates("wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, []params.UnitRelationState{{
+		RelationId: 0,
+		Members:    map[string]int64{"mysql/0": 2},
+	}})
+
+	_, err = s.APIState.Client().UnitRelationStates("wordpress/42")
+	c.Assert(err, gc.ErrorMatches, `unit "wordpress/42" not found`)
+}
+
+func (s *clientSuite) TestBlockChangesDropQueuedHook(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesDropQueuedHook")
+	err := s.APIState.Client().DropQueuedHook("wordpress/0", "relation-changed:0:mysql/0")
+	s.AssertBlocked(c, err, "TestBlockChangesDropQueuedHook")
+}
+
+func (s *clientSuite) TestContainerAddressPools(c *gc.C) {
+	host, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, host.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := s.APIState.Client()
+	err = client.AddContainerAddressPool(host.Id(), "lxcbr0", "10.0.3.0/24", "", []string{"10.0.3.100-10.0.3.254"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = client.AddContainerAddressPool(host.Id(), "lxcbr0", "10.0.3.0/24", "", nil)
+	c.Assert(err, gc.ErrorMatches, "cannot add address pool for lxcbr0 on machine 0: pool already exists")
+
+	pool, err := s.State.ContainerAddressPool(host.Id(), "lxcbr0")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = pool.AllocateAddress(container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	pools, err := client.ContainerAddressPools(host.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pools, jc.DeepEquals, []params.ContainerAddressPoolInfo{{
+		ContainerAddressPool: params.ContainerAddressPool{
+			MachineTag: "machine-0",
+			Bridge:     "lxcbr0",
+			CIDR:       "10.0.3.0/24",
+			Gateway:    "10.0.3.1",
+			Reserved:   []string{"10.0.3.100-10.0.3.254"},
+		},
+		Addresses: []params.ContainerPoolAddress{{
+			MachineTag: "machine-0-lxc-0",
+			Address:    "10.0.3.2",
+			Hostname:   "juju-machine-0-lxc-0",
+		}},
+	}})
+
+	err = client.RemoveContainerAddressPool(host.Id(), "lxcbr0")
+	c.Assert(err, gc.ErrorMatches, `cannot remove pool "lxcbr0 on machine 0": 1 addresses allocated`)
+	err = client.RemoveContainerAddressPool(host.Id(), "lxcbr1")
+	c.Assert(err, gc.ErrorMatches, "address pool for lxcbr1 on machine 0 not found")
+}
+
+func (s *clientSuite) TestBlockChangesAddContainerAddressPool(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesAddContainerAddressPool")
+	err := s.APIState.Client().AddContainerAddressPool("0", "lxcbr0", "10.0.3.0/24", "", nil)
+	s.AssertBlocked(c, err, "TestBlockChangesAddContainerAddressPool")
+}
+
+func (s *clientSuite) TestBlockRemoveRemoveContainerAddressPool(c *gc.C) {
+	s.BlockRemoveObject(c, "TestBlockRemoveRemoveContainerAddressPool")
+	err := s.APIState.Client().RemoveContainerAddressPool("0", "lxcbr0")
+	s.AssertBlocked(c, err, "TestBlockRemoveRemoveContainerAddressPool")
+}
+
 func (s *clientSuite) TestAPIHostPorts(c *gc.C) {
 	server1Addresses := []network.Address{{
 		Value: "server-1",
@@ -0,0 +1,253 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Client", 1, NewClientV1)
+}
+
+// maxListPageSize is the largest number of entries returned by a
+// single call to ListMachines or ListUnits.
+var maxListPageSize = 1000
+
+// ClientV1 serves version 1 of the Client facade, which adds paged
+// and filtered listing of machines and units.
+type ClientV1 struct {
+	Client
+}
+
+// NewClientV1 creates a new instance of version 1 of the Client facade.
+func NewClientV1(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*ClientV1, error) {
+	client, err := NewClient(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientV1{
+		Client: *client,
+	}, nil
+}
+
+// machineSortKeys maps the fields machines may be sorted by to
+// functions returning each machine's value for that field. Sorting by
+// ID needs no key, since entries are ordered by ID anyway.
+var machineSortKeys = map[string]func(params.MachineSummary) string{
+	"":            func(params.MachineSummary) string { return "" },
+	"id":          func(params.MachineSummary) string { return "" },
+	"series":      func(m params.MachineSummary) string { return m.Series },
+	"instance-id": func(m params.MachineSummary) string { return string(m.InstanceId) },
+	"status":      func(m params.MachineSummary) string { return string(m.Status) },
+}
+
+// unitSortKeys maps the fields units may be sorted by to functions
+// returning each unit's value for that field. Sorting by name needs
+// no key, since entries are ordered by name anyway.
+var unitSortKeys = map[string]func(params.UnitSummary) string{
+	"":             func(params.UnitSummary) string { return "" },
+	"name":         func(params.UnitSummary) string { return "" },
+	"service":      func(u params.UnitSummary) string { return u.Service },
+	"machine":      func(u params.UnitSummary) string { return u.Machine },
+	"status":       func(u params.UnitSummary) string { return string(u.Status) },
+	"agent-status": func(u params.UnitSummary) string { return string(u.AgentStatus) },
+}
+
+// ListMachines returns a page of the machines in the environment
+// that match the given filters.
+func (c *ClientV1) ListMachines(ctx rpcreflect.Context, args params.ListMachinesArgs) (params.ListMachinesResult, error) {
+	sortKey, ok := machineSortKeys[args.Page.SortBy]
+	if !ok {
+		return params.ListMachinesResult{}, errors.NotValidf("sort field %q", args.Page.SortBy)
+	}
+	units, unitMachines, err := c.assignedUnits(ctx)
+	if err != nil {
+		return params.ListMachinesResult{}, errors.Trace(err)
+	}
+	machineUnits := make(map[string][]*state.Unit)
+	for _, u := range units {
+		if machineId := unitMachines[u.Name()]; machineId != "" {
+			machineUnits[machineId] = append(machineUnits[machineId], u)
+		}
+	}
+	machines, err := c.api.state.AllMachines()
+	if err != nil {
+		return params.ListMachinesResult{}, errors.Trace(err)
+	}
+	services := set.NewStrings(args.Services...)
+	var summaries []params.MachineSummary
+	for _, m := range machines {
+		if err := ctx.Err(); err != nil {
+			return params.ListMachinesResult{}, err
+		}
+		summary := params.MachineSummary{
+			Id:     m.Id(),
+			Series: m.Series(),
+		}
+		hostsService := false
+		for _, u := range machineUnits[m.Id()] {
+			summary.Units = append(summary.Units, u.Name())
+			hostsService = hostsService || services.Contains(u.ServiceName())
+		}
+		if !services.IsEmpty() && !hostsService {
+			continue
+		}
+		status, _, _, err := m.Status()
+		if err != nil {
+			return params.ListMachinesResult{}, errors.Trace(err)
+		}
+		summary.Status = params.Status(status)
+		if !statusListed(args.Statuses, summary.Status) {
+			continue
+		}
+		if instId, err := m.InstanceId(); err == nil {
+			summary.InstanceId = instId
+		} else if !errors.IsNotProvisioned(err) {
+			return params.ListMachinesResult{}, errors.Trace(err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	entries := make([]common.PageEntry, len(summaries))
+	for i, summary := range summaries {
+		entries[i] = common.PageEntry{Id: summary.Id, SortKey: sortKey(summary)}
+	}
+	page, next, err := common.Paginate(entries, args.Page, maxListPageSize)
+	if err != nil {
+		return params.ListMachinesResult{}, err
+	}
+	result := params.ListMachinesResult{
+		Machines: make([]params.MachineSummary, len(page)),
+		Next:     next,
+	}
+	for i, index := range page {
+		result.Machines[i] = summaries[index]
+	}
+	return result, nil
+}
+
+// ListUnits returns a page of the units in the environment that
+// match the given filters.
+func (c *ClientV1) ListUnits(ctx rpcreflect.Context, args params.ListUnitsArgs) (params.ListUnitsResult, error) {
+	sortKey, ok := unitSortKeys[args.Page.SortBy]
+	if !ok {
+		return params.ListUnitsResult{}, errors.NotValidf("sort field %q", args.Page.SortBy)
+	}
+	units, unitMachines, err := c.assignedUnits(ctx)
+	if err != nil {
+		return params.ListUnitsResult{}, errors.Trace(err)
+	}
+	services := set.NewStrings(args.Services...)
+	machines := set.NewStrings(args.Machines...)
+	var summaries []params.UnitSummary
+	for _, u := range units {
+		if err := ctx.Err(); err != nil {
+			return params.ListUnitsResult{}, err
+		}
+		summary := params.UnitSummary{
+			Name:    u.Name(),
+			Service: u.ServiceName(),
+			Machine: unitMachines[u.Name()],
+		}
+		if !services.IsEmpty() && !services.Contains(summary.Service) {
+			continue
+		}
+		if !machines.IsEmpty() && !machines.Contains(summary.Machine) {
+			continue
+		}
+		status, _, _, err := u.Status()
+		if err != nil {
+			return params.ListUnitsResult{}, errors.Trace(err)
+		}
+		summary.Status = params.Status(status)
+		if !statusListed(args.Statuses, summary.Status) {
+			continue
+		}
+		agentStatus, _, _, err := u.AgentStatus()
+		if err != nil {
+			return params.ListUnitsResult{}, errors.Trace(err)
+		}
+		summary.AgentStatus = params.Status(agentStatus)
+		summaries = append(summaries, summary)
+	}
+
+	entries := make([]common.PageEntry, len(summaries))
+	for i, summary := range summaries {
+		entries[i] = common.PageEntry{Id: summary.Name, SortKey: sortKey(summary)}
+	}
+	page, next, err := common.Paginate(entries, args.Page, maxListPageSize)
+	if err != nil {
+		return params.ListUnitsResult{}, err
+	}
+	result := params.ListUnitsResult{
+		Units: make([]params.UnitSummary, len(page)),
+		Next:  next,
+	}
+	for i, index := range page {
+		result.Units[i] = summaries[index]
+	}
+	return result, nil
+}
+
+// assignedUnits returns all the units in the environment, together
+// with the IDs of the machines they are assigned to, by unit name.
+// Subordinate units are assigned to their principal's machine, and
+// units not yet assigned have no entry.
+func (c *ClientV1) assignedUnits(ctx rpcreflect.Context) ([]*state.Unit, map[string]string, error) {
+	services, err := c.api.state.AllServices()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var units []*state.Unit
+	for _, service := range services {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		serviceUnits, err := service.AllUnits()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		units = append(units, serviceUnits...)
+	}
+	machines := make(map[string]string)
+	for _, u := range units {
+		if !u.IsPrincipal() {
+			continue
+		}
+		if machineId, err := u.AssignedMachineId(); err == nil {
+			machines[u.Name()] = machineId
+		} else if !errors.IsNotAssigned(err) {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	for _, u := range units {
+		if principal, ok := u.PrincipalName(); ok {
+			if machineId, ok := machines[principal]; ok {
+				machines[u.Name()] = machineId
+			}
+		}
+	}
+	return units, machines, nil
+}
+
+// statusListed reports whether status is one of statuses, or
+// statuses is empty.
+func statusListed(statuses []params.Status, status params.Status) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+type listSuite struct {
+	baseSuite
+}
+
+var _ = gc.Suite(&listSuite{})
+
+// SetUpTest adds three machines, with units of wordpress on the first
+// two and a unit of mysql on the third. Only the second machine has
+// started, and only the mysql unit is active.
+func (s *listSuite) SetUpTest(c *gc.C) {
+	s.baseSuite.SetUpTest(c)
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	for _, service := range []*state.Service{wordpress, wordpress, mysql} {
+		machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+		c.Assert(err, jc.ErrorIsNil)
+		unit, err := service.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+		err = unit.AssignToMachine(machine)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	machine, err := s.State.Machine("1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := s.State.Unit("mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *listSuite) TestListMachines(c *gc.C) {
+	result, err := s.APIState.Client().ListMachines(params.ListMachinesArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListMachinesResult{
+		Machines: []params.MachineSummary{{
+			Id:     "0",
+			Series: "quantal",
+			Status: params.StatusPending,
+			Units:  []string{"wordpress/0"},
+		}, {
+			Id:     "1",
+			Series: "quantal",
+			Status: params.StatusStarted,
+			Units:  []string{"wordpress/1"},
+		}, {
+			Id:     "2",
+			Series: "quantal",
+			Status: params.StatusPending,
+			Units:  []string{"mysql/0"},
+		}},
+	})
+}
+
+func (s *listSuite) machineIds(c *gc.C, args params.ListMachinesArgs) []string {
+	result, err := s.APIState.Client().ListMachines(args)
+	c.Assert(err, jc.ErrorIsNil)
+	ids := make([]string, len(result.Machines))
+	for i, m := range result.Machines {
+		ids[i] = m.Id
+	}
+	return ids
+}
+
+func (s *listSuite) unitNames(c *gc.C, args params.ListUnitsArgs) []string {
+	result, err := s.APIState.Client().ListUnits(args)
+	c.Assert(err, jc.ErrorIsNil)
+	names := make([]string, len(result.Units))
+	for i, u := range result.Units {
+		names[i] = u.Name
+	}
+	return names
+}
+
+func (s *listSuite) TestListMachinesFilters(c *gc.C) {
+	c.Check(s.machineIds(c, params.ListMachinesArgs{
+		Services: []string{"wordpress"},
+	}), jc.DeepEquals, []string{"0", "1"})
+	c.Check(s.machineIds(c, params.ListMachinesArgs{
+		Statuses: []params.Status{params.StatusPending},
+	}), jc.DeepEquals, []string{"0", "2"})
+	c.Check(s.machineIds(c, params.ListMachinesArgs{
+		Services: []string{"wordpress"},
+		Statuses: []params.Status{params.StatusPending},
+	}), jc.DeepEquals, []string{"0"})
+}
+
+func (s *listSuite) TestListMachinesSorted(c *gc.C) {
+	c.Check(s.machineIds(c, params.ListMachinesArgs{
+		Page: params.ListPage{SortBy: "status"},
+	}), jc.DeepEquals, []string{"0", "2", "1"})
+	c.Check(s.machineIds(c, params.ListMachinesArgs{
+		Page: params.ListPage{SortBy: "id", Descending: true},
+	}), jc.DeepEquals, []string{"2", "1", "0"})
+}
+
+func (s *listSuite) TestListUnits(c *gc.C) {
+	result, err := s.APIState.Client().ListUnits(params.ListUnitsArgs{
+		Machines: []string{"2"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListUnitsResult{
+		Units: []params.UnitSummary{{
+			Name:        "mysql/0",
+			Service:     "mysql",
+			Machine:     "2",
+			Status:      params.StatusActive,
+			AgentStatus: params.StatusAllocating,
+		}},
+	})
+}
+
+func (s *listSuite) TestListUnitsFilters(c *gc.C) {
+	c.Check(s.unitNames(c, params.ListUnitsArgs{}), jc.DeepEquals, []string{
+		"mysql/0", "wordpress/0", "wordpress/1",
+	})
+	c.Check(s.unitNames(c, params.ListUnitsArgs{
+		Services: []string{"wordpress"},
+	}), jc.DeepEquals, []string{"wordpress/0", "wordpress/1"})
+	c.Check(s.unitNames(c, params.ListUnitsArgs{
+		Statuses: []params.Status{params.StatusActive},
+	}), jc.DeepEquals, []string{"mysql/0"})
+	c.Check(s.unitNames(c, params.ListUnitsArgs{
+		Services: []string{"wordpress"},
+		Machines: []string{"1", "2"},
+	}), jc.DeepEquals, []string{"wordpress/1"})
+}
+
+func (s *listSuite) TestListUnitsSorted(c *gc.C) {
+	c.Check(s.unitNames(c, params.ListUnitsArgs{
+		Page: params.ListPage{SortBy: "machine", Descending: true},
+	}), jc.DeepEquals, []string{"mysql/0", "wordpress/1", "wordpress/0"})
+}
+
+func (s *listSuite) TestListUnitsPages(c *gc.C) {
+	client := s.APIState.Client()
+	args := params.ListUnitsArgs{
+		Page: params.ListPage{SortBy: "service", Limit: 2},
+	}
+	result, err := client.ListUnits(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Units, gc.HasLen, 2)
+	c.Check(result.Units[0].Name, gc.Equals, "mysql/0")
+	c.Check(result.Units[1].Name, gc.Equals, "wordpress/0")
+	c.Assert(result.Next, gc.Not(gc.Equals), "")
+
+	args.Page.After = result.Next
+	result, err = client.ListUnits(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Units, gc.HasLen, 1)
+	c.Check(result.Units[0].Name, gc.Equals, "wordpress/1")
+	c.Check(result.Next, gc.Equals, "")
+}
+
+func (s *listSuite) TestListUnknownSortField(c *gc.C) {
+	_, err := s.APIState.Client().ListMachines(params.ListMachinesArgs{
+		Page: params.ListPage{SortBy: "bogus"},
+	})
+	c.Assert(err, gc.ErrorMatches, `sort field "bogus" not valid`)
+	_, err = s.APIState.Client().ListUnits(params.ListUnitsArgs{
+		Page: params.ListPage{SortBy: "bogus"},
+	})
+	c.Assert(err, gc.ErrorMatches, `sort field "bogus" not valid`)
+}
@@ -5,18 +5,27 @@ package client
 
 import (
 	"github.com/juju/errors"
+	"github.com/juju/names"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 )
 
 // DestroyEnvironment destroys all services and non-manager machine
-// instances in the environment.
-func (c *Client) DestroyEnvironment() (err error) {
+// instances in the environment. Unless forced, the destroy-environment
+// prechecks must pass first.
+func (c *Client) DestroyEnvironment(args params.DestroyEnvironment) (err error) {
 	if err = c.check.DestroyAllowed(); err != nil {
 		return errors.Trace(err)
 	}
+	if !args.Force {
+		tags := []names.Tag{c.api.state.EnvironTag()}
+		if err := c.precheck(params.PrecheckDestroyEnvironment, tags); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	env, err := c.api.state.Environment()
 	if err != nil {
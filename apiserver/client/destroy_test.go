@@ -13,6 +13,7 @@ import (
 
 	"github.com/juju/juju/apiserver/client"
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
@@ -180,6 +181,19 @@ func (s *destroyEnvironmentSuite) TestBlockChangesDestroyEnvironment(c *gc.C) {
 	s.AssertBlocked(c, err, "TestBlockChangesDestroyEnvironment")
 }
 
+// failDestroyPrecheck controls whether the destroy-environment
+// prechecker registered below reports a problem.
+var failDestroyPrecheck = false
+
+func init() {
+	common.RegisterPrechecker(params.PrecheckDestroyEnvironment, "test-failure", func(*state.State, names.Tag) ([]string, error) {
+		if failDestroyPrecheck {
+			return []string{"not ready"}, nil
+		}
+		return nil, nil
+	})
+}
+
 type destroyTwoEnvironmentsSuite struct {
 	testing.JujuConnSuite
 	otherState     *state.State
@@ -217,7 +231,7 @@ func (s *destroyTwoEnvironmentsSuite) TestCleanupEnvironDocs(c *gc.C) {
 	m := otherFactory.MakeMachine(c, nil)
 	otherFactory.MakeMachineNested(c, m.Id(), nil)
 
-	err := s.otherEnvClient.DestroyEnvironment()
+	err := s.otherEnvClient.DestroyEnvironment(params.DestroyEnvironment{})
 	c.Assert(err, jc.ErrorIsNil)
 
 	_, err = s.otherState.Environment()
@@ -231,8 +245,28 @@ func (s *destroyTwoEnvironmentsSuite) TestCleanupEnvironDocs(c *gc.C) {
 func (s *destroyTwoEnvironmentsSuite) TestDestroyStateServerAfterNonStateServerIsDestroyed(c *gc.C) {
 	err := s.APIState.Client().DestroyEnvironment()
 	c.Assert(err, gc.ErrorMatches, "failed to destroy environment: state server environment cannot be destroyed before all other environments are destroyed")
-	err = s.otherEnvClient.DestroyEnvironment()
+	err = s.otherEnvClient.DestroyEnvironment(params.DestroyEnvironment{})
 	c.Assert(err, jc.ErrorIsNil)
 	err = s.APIState.Client().DestroyEnvironment()
 	c.Assert(err, jc.ErrorIsNil)
 }
+
+func (s *destroyTwoEnvironmentsSuite) TestDestroyEnvironmentPrecheckFailed(c *gc.C) {
+	s.PatchValue(&failDestroyPrecheck, true)
+	err := s.otherEnvClient.DestroyEnvironment(params.DestroyEnvironment{})
+	c.Assert(err, gc.ErrorMatches, "destroy-environment prechecks failed:\n.*: not ready")
+	c.Assert(params.IsCodePrecheckFailed(err), jc.IsTrue)
+
+	env, err := s.otherState.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Life(), gc.Equals, state.Alive)
+}
+
+func (s *destroyTwoEnvironmentsSuite) TestDestroyEnvironmentForceSkipsPrechecks(c *gc.C) {
+	s.PatchValue(&failDestroyPrecheck, true)
+	err := s.otherEnvClient.DestroyEnvironment(params.DestroyEnvironment{Force: true})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.otherState.Environment()
+	c.Assert(errors.IsNotFound(err), jc.IsTrue)
+}
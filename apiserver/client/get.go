@@ -70,3 +70,13 @@ func (c *Client) ServiceGetCharmURL(args params.ServiceGet) (params.StringResult
 	charmURL, _ := service.CharmURL()
 	return params.StringResult{Result: charmURL.String()}, nil
 }
+
+// ServiceGetCharmChannel returns the charm store channel the given
+// service tracks for upgrades of its charm.
+func (c *Client) ServiceGetCharmChannel(args params.ServiceGet) (params.StringResult, error) {
+	service, err := c.api.state.Service(args.ServiceName)
+	if err != nil {
+		return params.StringResult{}, err
+	}
+	return params.StringResult{Result: string(service.Channel())}, nil
+}
@@ -103,6 +103,10 @@ func (s *permSuite) TestOperationPerm(c *gc.C) {
 		about: "Client.ServiceDeployWithNetworks",
 		op:    opClientServiceDeployWithNetworks,
 		allow: []names.Tag{userAdmin, userOther},
+	}, {
+		about: "Client.ServiceDeployWithWorkloadContainer",
+		op:    opClientServiceDeployWithWorkloadContainer,
+		allow: []names.Tag{userAdmin, userOther},
 	}, {
 		about: "Client.ServiceUpdate",
 		op:    opClientServiceUpdate,
@@ -350,6 +354,18 @@ func opClientServiceDeployWithNetworks(c *gc.C, st *api.State, mst *state.State)
 	return func() {}, err
 }
 
+func opClientServiceDeployWithWorkloadContainer(c *gc.C, st *api.State, mst *state.State) (func(), error) {
+	err := st.Client().ServiceDeployWithWorkloadContainer(params.ServiceDeploy{
+		ServiceName: "x",
+		CharmUrl:    "mad:bad/url-1",
+		NumUnits:    1,
+	})
+	if err.Error() == `charm URL has invalid schema: "mad:bad/url-1"` {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientServiceUpdate(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	args := params.ServiceUpdate{
 		ServiceName:     "no-such-charm",
@@ -404,7 +420,7 @@ func opClientGetServiceConstraints(c *gc.C, st *api.State, mst *state.State) (fu
 
 func opClientSetServiceConstraints(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	nullConstraints := constraints.Value{}
-	err := st.Client().SetServiceConstraints("wordpress", nullConstraints)
+	_, err := st.Client().SetServiceConstraints("wordpress", nullConstraints)
 	if err != nil {
 		return func() {}, err
 	}
@@ -413,7 +429,7 @@ func opClientSetServiceConstraints(c *gc.C, st *api.State, mst *state.State) (fu
 
 func opClientSetEnvironmentConstraints(c *gc.C, st *api.State, mst *state.State) (func(), error) {
 	nullConstraints := constraints.Value{}
-	err := st.Client().SetEnvironmentConstraints(nullConstraints)
+	_, err := st.Client().SetEnvironmentConstraints(nullConstraints)
 	if err != nil {
 		return func() {}, err
 	}
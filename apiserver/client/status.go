@@ -5,7 +5,9 @@ package client
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/set"
@@ -15,34 +17,140 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/rpc/rpcreflect"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/tools"
 )
 
-// FullStatus gives the information needed for juju status over the api
-func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
+// statusCacheThreshold is the time a FullStatus call must take for
+// its result to be cached, so that only the status of large
+// environments is cached.
+var statusCacheThreshold = time.Second
+
+// FullStatus gives the information needed for juju status over the api.
+// Only the sections named in args are filled in, or all of them if none
+// are named. It gives up, between its queries, once ctx has been
+// abandoned.
+//
+// Results that are slow to compute are cached by the API server until
+// the environment changes.
+func (c *Client) FullStatus(ctx rpcreflect.Context, args params.StatusParams) (api.Status, error) {
+	sections, err := statusSections(args.Sections)
+	if err != nil {
+		return api.Status{}, errors.Trace(err)
+	}
+	cache := c.api.statusCache
+	envUUID := c.api.state.EnvironUUID()
+	key := statusCacheKey(args.Patterns, sections)
+	if cache != nil {
+		if status, ok := cache.Get(envUUID, key); ok {
+			return status.(api.Status), nil
+		}
+	}
+	start := time.Now()
+	status, err := c.fullStatus(ctx, args.Patterns, sections)
+	if err != nil {
+		return api.Status{}, err
+	}
+	if cache != nil && time.Since(start) >= statusCacheThreshold {
+		cache.Put(envUUID, key, status)
+	}
+	return status, nil
+}
+
+// statusSections returns the set of sections named, or of all sections
+// if none are.
+func statusSections(names []params.StatusSection) (map[params.StatusSection]bool, error) {
+	all := []params.StatusSection{
+		params.StatusMachines,
+		params.StatusServices,
+		params.StatusRelations,
+		params.StatusNetworks,
+	}
+	if len(names) == 0 {
+		names = all
+	}
+	sections := make(map[params.StatusSection]bool)
+	for _, name := range names {
+		known := false
+		for _, section := range all {
+			known = known || name == section
+		}
+		if !known {
+			return nil, errors.NotValidf("status section %q", name)
+		}
+		sections[name] = true
+	}
+	return sections, nil
+}
+
+// statusCacheKey returns the key under which the status for the given
+// patterns and sections is cached.
+func statusCacheKey(patterns []string, sections map[params.StatusSection]bool) string {
+	var names []string
+	for section := range sections {
+		names = append(names, string(section))
+	}
+	sort.Strings(names)
+	patterns = append([]string(nil), patterns...)
+	sort.Strings(patterns)
+	return strings.Join(names, ",") + "|" + strings.Join(patterns, " ")
+}
+
+func (c *Client) fullStatus(
+	ctx rpcreflect.Context,
+	patterns []string,
+	sections map[params.StatusSection]bool,
+) (api.Status, error) {
 	cfg, err := c.api.state.EnvironConfig()
 	if err != nil {
 		return api.Status{}, errors.Annotate(err, "could not get environ config")
 	}
+	// Filtering by pattern needs units and machines whichever
+	// sections are wanted; relations need the services they
+	// relate.
+	filtering := len(patterns) > 0
+	wantMachines := sections[params.StatusMachines]
+	wantServices := sections[params.StatusServices]
+	wantRelations := sections[params.StatusRelations]
+	wantNetworks := sections[params.StatusNetworks]
+
 	var noStatus api.Status
 	var context statusContext
-	if context.services, context.units, context.latestCharms, err =
-		fetchAllServicesAndUnits(c.api.state, len(args.Patterns) <= 0); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch services and units")
-	} else if context.machines, err = fetchMachines(c.api.state, nil); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch machines")
-	} else if context.relations, err = fetchRelations(c.api.state); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch relations")
-	} else if context.networks, err = fetchNetworks(c.api.state); err != nil {
-		return noStatus, errors.Annotate(err, "could not fetch networks")
+	if wantServices || wantRelations || filtering {
+		if context.services, context.units, context.latestCharms, err =
+			fetchAllServicesAndUnits(ctx, c.api.state, !filtering); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch services and units")
+		} else if err = ctx.Err(); err != nil {
+			return noStatus, errors.Trace(err)
+		}
+	}
+	if wantMachines || filtering {
+		if context.machines, err = fetchMachines(c.api.state, nil); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch machines")
+		}
+	}
+	if wantServices || wantRelations {
+		if context.relations, err = fetchRelations(c.api.state); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch relations")
+		}
+	}
+	if wantNetworks {
+		if context.networks, err = fetchNetworks(c.api.state); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch networks")
+		}
+	}
+	if wantServices {
+		if context.meterStatuses, err = c.api.state.AllMeterStatuses(); err != nil {
+			return noStatus, errors.Annotate(err, "could not fetch meter statuses")
+		}
 	}
 
 	logger.Debugf("Services: %v", context.services)
 
-	if len(args.Patterns) > 0 {
-		predicate := BuildPredicateFor(args.Patterns)
+	if filtering {
+		predicate := BuildPredicateFor(patterns)
 
 		// Filter units
 		unfilteredSvcs := make(set.Strings)
@@ -50,6 +158,9 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 		unitChainPredicate := UnitChainPredicateFn(predicate, context.unitByName)
 		for _, unitMap := range context.units {
 			for name, unit := range unitMap {
+				if err := ctx.Err(); err != nil {
+					return noStatus, errors.Trace(err)
+				}
 				// Always start examining at the top-level. This
 				// prevents a situation where we filter a subordinate
 				// before we discover its parent is a match.
@@ -90,6 +201,9 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 		for status, machineList := range context.machines {
 			filteredList := make([]*state.Machine, 0, len(machineList))
 			for _, m := range machineList {
+				if err := ctx.Err(); err != nil {
+					return noStatus, errors.Trace(err)
+				}
 				machineContainers, err := m.Containers()
 				if err != nil {
 					return noStatus, err
@@ -112,19 +226,35 @@ func (c *Client) FullStatus(args params.StatusParams) (api.Status, error) {
 		}
 	}
 
-	return api.Status{
+	// Processing reads the status of every entity; don't start
+	// unless someone is still waiting for the result.
+	if err := ctx.Err(); err != nil {
+		return noStatus, errors.Trace(err)
+	}
+	status := api.Status{
 		EnvironmentName: cfg.Name(),
-		Machines:        processMachines(context.machines),
-		Services:        context.processServices(),
-		Networks:        context.processNetworks(),
-		Relations:       context.processRelations(),
-	}, nil
+	}
+	if wantMachines {
+		status.Machines = processMachines(context.machines)
+	}
+	if wantServices {
+		status.Services = context.processServices()
+	}
+	if wantNetworks {
+		status.Networks = context.processNetworks()
+	}
+	if wantRelations {
+		status.Relations = context.processRelations()
+	}
+	return status, nil
 }
 
 // Status is a stub version of FullStatus that was introduced in 1.16
-func (c *Client) Status() (api.LegacyStatus, error) {
+func (c *Client) Status(ctx rpcreflect.Context) (api.LegacyStatus, error) {
 	var legacyStatus api.LegacyStatus
-	status, err := c.FullStatus(params.StatusParams{})
+	status, err := c.FullStatus(ctx, params.StatusParams{
+		Sections: []params.StatusSection{params.StatusMachines},
+	})
 	if err != nil {
 		return legacyStatus, err
 	}
@@ -148,6 +278,8 @@ type statusContext struct {
 	units        map[string]map[string]*state.Unit
 	networks     map[string]*state.Network
 	latestCharms map[charm.URL]string
+	// meterStatuses: unit name -> meter status
+	meterStatuses map[string]state.MeterStatus
 }
 
 // fetchMachines returns a map from top level machine id to machines, where machines[0] is the host
@@ -184,7 +316,9 @@ func fetchMachines(st *state.State, machineIds set.Strings) (map[string][]*state
 
 // fetchAllServicesAndUnits returns a map from service name to service,
 // a map from service name to unit name to unit, and a map from base charm URL to latest URL.
+// It stops early, returning ctx's error, if ctx is abandoned.
 func fetchAllServicesAndUnits(
+	ctx rpcreflect.Context,
 	st *state.State,
 	matchAny bool,
 ) (map[string]*state.Service, map[string]map[string]*state.Unit, map[charm.URL]string, error) {
@@ -197,6 +331,9 @@ func fetchAllServicesAndUnits(
 		return nil, nil, nil, err
 	}
 	for _, s := range services {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
 		units, err := s.AllUnits()
 		if err != nil {
 			return nil, nil, nil, err
@@ -217,6 +354,9 @@ func fetchAllServicesAndUnits(
 		}
 	}
 	for baseURL := range latestCharms {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
 		ch, err := st.LatestPlaceholderCharm(&baseURL)
 		if errors.IsNotFound(err) {
 			continue
@@ -463,6 +603,15 @@ func (context *statusContext) processService(service *state.Service) (status api
 	status.Life = processLife(service)
 
 	latestCharm, ok := context.latestCharms[*serviceCharmURL.WithRevision(-1)]
+	if service.Channel() != state.StableChannel {
+		// The latest revision in the channel the service tracks is
+		// recorded against the service, if it is for the same charm.
+		latestCharm, ok = "", false
+		if latestURL := service.LatestChannelCharmURL(); latestURL != nil {
+			ok = *latestURL.WithRevision(-1) == *serviceCharmURL.WithRevision(-1)
+			latestCharm = latestURL.String()
+		}
+	}
 	if ok && latestCharm != serviceCharmURL.String() {
 		status.CanUpgradeTo = latestCharm
 	}
@@ -499,9 +648,66 @@ func (context *statusContext) processService(service *state.Service) (status api
 	if service.IsPrincipal() {
 		status.Units = context.processUnits(context.units[service.Name()], serviceCharmURL.String())
 	}
+	status.Status = processServiceStatus(service)
+	status.WorkloadVersion = serviceWorkloadVersion(context.units[service.Name()])
+	status.MeterStatus = context.serviceMeterStatus(service)
 	return status
 }
 
+// serviceMeterStatus returns the worst meter status of the units of
+// the given service, or nil if it is not AMBER or RED.
+func (context *statusContext) serviceMeterStatus(service *state.Service) *api.MeterStatus {
+	statuses := make(map[string]state.MeterStatus)
+	for name := range context.units[service.Name()] {
+		if status, ok := context.meterStatuses[name]; ok {
+			statuses[name] = status
+		}
+	}
+	return processMeterStatus(state.CombineMeterStatuses(statuses))
+}
+
+// processMeterStatus returns the given meter status for display, or nil
+// if it does not need attention.
+func processMeterStatus(status state.MeterStatus) *api.MeterStatus {
+	switch status.Code {
+	case state.MeterAmber, state.MeterRed:
+		return &api.MeterStatus{Code: string(status.Code), Info: status.Info}
+	}
+	return nil
+}
+
+// serviceWorkloadVersion returns the workload version reported by the
+// lowest numbered of the given units of a service that reported one.
+func serviceWorkloadVersion(units map[string]*state.Unit) string {
+	var version string
+	number := -1
+	for _, unit := range units {
+		if unit.WorkloadVersion() == "" {
+			continue
+		}
+		if n := unit.UnitTag().Number(); number == -1 || n < number {
+			version, number = unit.WorkloadVersion(), n
+		}
+	}
+	return version
+}
+
+// processServiceStatus retrieves the status of the given service,
+// rolled up from the workload statuses of its units.
+func processServiceStatus(service *state.Service) (out api.WorkloadStatus) {
+	status, info, data, err := service.Status()
+	if err != nil {
+		logger.Debugf("cannot get status of service %q: %v", service.Name(), err)
+		return api.WorkloadStatus{}
+	}
+	out.Status = params.Status(status)
+	out.Info = info
+	if len(data) > 0 {
+		out.Data = data
+	}
+	return out
+}
+
 func (context *statusContext) processUnits(units map[string]*state.Unit, serviceCharm string) map[string]api.UnitStatus {
 	unitsMap := make(map[string]api.UnitStatus)
 	for _, unit := range units {
@@ -524,6 +730,9 @@ func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string)
 		status.Charm = curl.String()
 	}
 	status.Agent, status.AgentState, status.AgentStateInfo = processAgent(unit)
+	status.Workload = processWorkload(unit)
+	status.WorkloadVersion = unit.WorkloadVersion()
+	status.MeterStatus = processMeterStatus(context.meterStatuses[unit.Name()])
 
 	// Until Juju 2.0, we need to continue to display legacy status values.
 	status.Agent.Status = params.TranslateLegacyStatus(status.Agent.Status)
@@ -545,6 +754,23 @@ func (context *statusContext) processUnit(unit *state.Unit, serviceCharm string)
 	return
 }
 
+// processWorkload retrieves the status of the software run by the
+// given unit. A unit whose workload status cannot be read is reported
+// with an empty status.
+func processWorkload(unit *state.Unit) (out api.WorkloadStatus) {
+	status, info, data, err := unit.Status()
+	if err != nil {
+		logger.Debugf("cannot get workload status of unit %q: %v", unit.Name(), err)
+		return api.WorkloadStatus{}
+	}
+	out.Status = params.Status(status)
+	out.Info = info
+	if len(data) > 0 {
+		out.Data = data
+	}
+	return out
+}
+
 func (context *statusContext) unitByName(name string) *state.Unit {
 	serviceName := strings.Split(name, "/")[0]
 	return context.units[serviceName][name]
@@ -585,6 +811,7 @@ type lifer interface {
 type stateAgent interface {
 	lifer
 	AgentPresence() (bool, error)
+	AgentLastSeen() (time.Time, error)
 	AgentTools() (*tools.Tools, error)
 	Status() (state.Status, string, map[string]interface{}, error)
 }
@@ -596,6 +823,9 @@ func processAgent(entity stateAgent) (out api.AgentStatus, compatStatus params.S
 	if t, err := entity.AgentTools(); err == nil {
 		out.Version = t.Version.Number.String()
 	}
+	if lastSeen, err := entity.AgentLastSeen(); err == nil {
+		out.LastSeen = &lastSeen
+	}
 
 	// TODO(wallyworld) - this is ok for now, but status needs to support returning 3 values
 	// for unit:
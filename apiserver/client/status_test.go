@@ -8,6 +8,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/client"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
@@ -45,6 +46,30 @@ func (s *statusSuite) TestFullStatus(c *gc.C) {
 	c.Check(resultMachine.Series, gc.Equals, machine.Series())
 }
 
+func (s *statusSuite) TestFullStatusSections(c *gc.C) {
+	machine := s.addMachine(c)
+	client := s.APIState.Client()
+	status, err := client.StatusSections(nil, params.StatusMachines)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.EnvironmentName, gc.Equals, "dummyenv")
+	c.Check(status.Machines, gc.HasLen, 1)
+	c.Check(status.Machines[machine.Id()].Id, gc.Equals, machine.Id())
+	c.Check(status.Services, gc.IsNil)
+	c.Check(status.Networks, gc.IsNil)
+	c.Check(status.Relations, gc.IsNil)
+
+	status, err = client.StatusSections(nil, params.StatusServices, params.StatusNetworks)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Machines, gc.IsNil)
+	c.Check(status.Services, gc.NotNil)
+	c.Check(status.Networks, gc.NotNil)
+}
+
+func (s *statusSuite) TestFullStatusUnknownSection(c *gc.C) {
+	_, err := s.APIState.Client().StatusSections(nil, "bogus")
+	c.Assert(err, gc.ErrorMatches, `status section "bogus" not valid`)
+}
+
 func (s *statusSuite) TestLegacyStatus(c *gc.C) {
 	machine := s.addMachine(c)
 	instanceId := "i-fakeinstance"
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package clouds provides the API used to manage the clouds on which
+// the state server may host environments, and the credentials users
+// hold for them.
+package clouds
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacadeForFeature("Clouds", 1, NewCloudsAPI, feature.JES)
+}
+
+// CloudsAPI implements the Clouds facade.
+type CloudsAPI struct {
+	st   *state.State
+	auth common.Authorizer
+}
+
+// NewCloudsAPI creates a new server-side Clouds facade.
+func NewCloudsAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*CloudsAPI, error) {
+	if !auth.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &CloudsAPI{
+		st:   st,
+		auth: auth,
+	}, nil
+}
+
+// Clouds returns the clouds on which the state server may host
+// environments.
+func (api *CloudsAPI) Clouds() (params.Clouds, error) {
+	clouds, err := api.st.AllClouds()
+	if err != nil {
+		return params.Clouds{}, errors.Trace(err)
+	}
+	result := params.Clouds{
+		Clouds: make([]params.Cloud, len(clouds)),
+	}
+	for i, cloud := range clouds {
+		result.Clouds[i] = params.Cloud{
+			Name:   cloud.Name(),
+			Type:   cloud.Type(),
+			Config: cloud.Config(),
+		}
+	}
+	return result, nil
+}
+
+// AddClouds adds clouds on which the state server may host
+// environments. Only the state server owner may add clouds.
+func (api *CloudsAPI) AddClouds(args params.Clouds) (params.ErrorResults, error) {
+	adminUser, err := api.adminUser()
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	if api.auth.GetAuthTag() != adminUser {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Clouds)),
+	}
+	for i, cloud := range args.Clouds {
+		_, err := api.st.AddCloud(cloud.Name, cloud.Type, cloud.Config)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// UpdateCredentials adds or replaces credentials users hold for clouds.
+// Users may only update their own credentials, except for the state
+// server owner, who may update anyone's.
+func (api *CloudsAPI) UpdateCredentials(args params.CloudCredentials) (params.ErrorResults, error) {
+	adminUser, err := api.adminUser()
+	if err != nil {
+		return params.ErrorResults{}, errors.Trace(err)
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Credentials)),
+	}
+	for i, credential := range args.Credentials {
+		owner, err := names.ParseUserTag(credential.OwnerTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if authTag := api.auth.GetAuthTag(); authTag != owner && authTag != adminUser {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = api.st.UpdateCloudCredential(owner, credential.Cloud, credential.Name, credential.Attributes)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// adminUser returns the owner of the state server environment.
+func (api *CloudsAPI) adminUser() (names.UserTag, error) {
+	stateServerEnv, err := api.st.StateServerEnvironment()
+	if err != nil {
+		return names.UserTag{}, errors.Trace(err)
+	}
+	return stateServerEnv.Owner(), nil
+}
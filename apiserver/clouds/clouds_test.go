@@ -0,0 +1,138 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package clouds_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/clouds"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type cloudsSuite struct {
+	jujutesting.JujuConnSuite
+
+	api *clouds.CloudsAPI
+}
+
+var _ = gc.Suite(&cloudsSuite{})
+
+func (s *cloudsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.api = s.newAPI(c, s.AdminUserTag(c))
+}
+
+func (s *cloudsSuite) newAPI(c *gc.C, tag names.Tag) *clouds.CloudsAPI {
+	api, err := clouds.NewCloudsAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: tag,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *cloudsSuite) TestNewAPIRefusesNonClient(c *gc.C) {
+	_, err := clouds.NewCloudsAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *cloudsSuite) TestAddCloudsAndList(c *gc.C) {
+	results, err := s.api.AddClouds(params.Clouds{
+		Clouds: []params.Cloud{{
+			Name:   "aws",
+			Type:   "ec2",
+			Config: map[string]interface{}{"region": "us-east-1"},
+		}, {
+			Name: "Not Valid",
+			Type: "ec2",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `cloud name "Not Valid" not valid`)
+
+	// Any user may list the clouds.
+	api := s.newAPI(c, names.NewUserTag("bob@remote"))
+	result, err := api.Clouds()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.Clouds{
+		Clouds: []params.Cloud{{
+			Name:   "aws",
+			Type:   "ec2",
+			Config: map[string]interface{}{"region": "us-east-1"},
+		}},
+	})
+}
+
+func (s *cloudsSuite) TestAddCloudsNotAdmin(c *gc.C) {
+	api := s.newAPI(c, names.NewUserTag("bob@remote"))
+	_, err := api.AddClouds(params.Clouds{
+		Clouds: []params.Cloud{{Name: "aws", Type: "ec2"}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *cloudsSuite) TestUpdateCredentials(c *gc.C) {
+	_, err := s.State.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bob := names.NewUserTag("bob@remote")
+	api := s.newAPI(c, bob)
+	results, err := api.UpdateCredentials(params.CloudCredentials{
+		Credentials: []params.CloudCredential{{
+			OwnerTag:   bob.String(),
+			Cloud:      "aws",
+			Name:       "default",
+			Attributes: map[string]string{"access-key": "key"},
+		}, {
+			OwnerTag: "user-mary@remote",
+			Cloud:    "aws",
+			Name:     "default",
+		}, {
+			OwnerTag: "machine-0",
+			Cloud:    "aws",
+			Name:     "default",
+		}, {
+			OwnerTag: bob.String(),
+			Cloud:    "nowhere",
+			Name:     "default",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 4)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, jc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(results.Results[2].Error, jc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(results.Results[3].Error, gc.ErrorMatches, `cannot update credential "default" for cloud "nowhere": cloud "nowhere" not found`)
+
+	credential, err := s.State.CloudCredential(bob, "aws", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credential.Attributes(), jc.DeepEquals, map[string]string{"access-key": "key"})
+}
+
+func (s *cloudsSuite) TestAdminUpdatesOtherCredentials(c *gc.C) {
+	_, err := s.State.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	mary := names.NewUserTag("mary@remote")
+	results, err := s.api.UpdateCredentials(params.CloudCredentials{
+		Credentials: []params.CloudCredential{{
+			OwnerTag:   mary.String(),
+			Cloud:      "aws",
+			Name:       "default",
+			Attributes: map[string]string{"access-key": "key"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	_, err = s.State.CloudCredential(mary, "aws", "default")
+	c.Assert(err, jc.ErrorIsNil)
+}
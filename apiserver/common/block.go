@@ -54,6 +54,25 @@ func (c *BlockChecker) DestroyAllowed() error {
 	return c.checkBlock(state.ChangeBlock)
 }
 
+// BudgetAllowed checks if budget block is in place.
+// Budget block prevents operations that may increase the cost
+// of running the current environment, such as adding machines.
+func (c *BlockChecker) BudgetAllowed() error {
+	if err := c.checkBlock(state.BudgetBlock); err != nil {
+		return err
+	}
+	// Check if change block has been enabled
+	return c.checkBlock(state.ChangeBlock)
+}
+
+// RebootAllowed checks if reboot block is in place.
+// Reboot block prevents charms from rebooting the machines
+// in the current environment. It is not affected by the change
+// block, which applies to operations requested by users.
+func (c *BlockChecker) RebootAllowed() error {
+	return c.checkBlock(state.RebootBlock)
+}
+
 // checkBlock checks if specified operation must be blocked.
 // If it does, the method throws specific error that can be examined
 // to stop operation execution.
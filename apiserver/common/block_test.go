@@ -32,6 +32,7 @@ type blockCheckerSuite struct {
 	testing.FakeJujuHomeSuite
 	aBlock                  state.Block
 	destroy, remove, change state.Block
+	budget, reboot          state.Block
 
 	blockchecker *common.BlockChecker
 }
@@ -43,6 +44,8 @@ func (s *blockCheckerSuite) SetUpTest(c *gc.C) {
 	s.destroy = mockBlock{t: state.DestroyBlock, m: "Mock BLOCK testing: DESTROY"}
 	s.remove = mockBlock{t: state.RemoveBlock, m: "Mock BLOCK testing: REMOVE"}
 	s.change = mockBlock{t: state.ChangeBlock, m: "Mock BLOCK testing: CHANGE"}
+	s.budget = mockBlock{t: state.BudgetBlock, m: "Mock BLOCK testing: BUDGET"}
+	s.reboot = mockBlock{t: state.RebootBlock, m: "Mock BLOCK testing: REBOOT"}
 	s.blockchecker = common.NewBlockChecker(s)
 }
 
@@ -87,6 +90,31 @@ func (s *blockCheckerSuite) TestChangeBlockChecker(c *gc.C) {
 	s.assertErrorBlocked(c, true, s.blockchecker.ChangeAllowed(), s.change.Message())
 }
 
+func (s *blockCheckerSuite) TestBudgetBlockChecker(c *gc.C) {
+	s.aBlock = s.remove
+	s.assertErrorBlocked(c, false, s.blockchecker.BudgetAllowed(), s.remove.Message())
+
+	s.aBlock = s.reboot
+	s.assertErrorBlocked(c, false, s.blockchecker.BudgetAllowed(), s.reboot.Message())
+
+	s.aBlock = s.budget
+	s.assertErrorBlocked(c, true, s.blockchecker.BudgetAllowed(), s.budget.Message())
+
+	s.aBlock = s.change
+	s.assertErrorBlocked(c, true, s.blockchecker.BudgetAllowed(), s.change.Message())
+}
+
+func (s *blockCheckerSuite) TestRebootBlockChecker(c *gc.C) {
+	s.aBlock = s.change
+	s.assertErrorBlocked(c, false, s.blockchecker.RebootAllowed(), s.change.Message())
+
+	s.aBlock = s.budget
+	s.assertErrorBlocked(c, false, s.blockchecker.RebootAllowed(), s.budget.Message())
+
+	s.aBlock = s.reboot
+	s.assertErrorBlocked(c, true, s.blockchecker.RebootAllowed(), s.reboot.Message())
+}
+
 func (s *blockCheckerSuite) assertErrorBlocked(c *gc.C, blocked bool, err error, msg string) {
 	if blocked {
 		c.Assert(params.IsCodeOperationBlocked(err), jc.IsTrue)
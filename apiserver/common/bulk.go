@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"sync"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// MaxBulkConcurrency is the maximum number of entities that a bulk
+// call processes at the same time.
+var MaxBulkConcurrency = 8
+
+// BulkCall calls call once for each index in [0, n), running up to
+// MaxBulkConcurrency calls concurrently, and returns the errors they
+// return, converted with ServerError, in index order. Each call must
+// only touch the results for its own index.
+func BulkCall(n int, call func(i int) error) []*params.Error {
+	errs := make([]*params.Error, n)
+	if n == 1 || MaxBulkConcurrency <= 1 {
+		for i := 0; i < n; i++ {
+			errs[i] = ServerError(call(i))
+		}
+		return errs
+	}
+	limit := make(chan struct{}, MaxBulkConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		limit <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-limit }()
+			errs[i] = ServerError(call(i))
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// BulkErrorResults calls call for each index in [0, n) as BulkCall
+// does, and returns the errors as ErrorResults.
+func BulkErrorResults(n int, call func(i int) error) params.ErrorResults {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, n),
+	}
+	for i, err := range BulkCall(n, call) {
+		result.Results[i].Error = err
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/testing"
+)
+
+type bulkSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&bulkSuite{})
+
+func (*bulkSuite) TestBulkCallEmpty(c *gc.C) {
+	errs := common.BulkCall(0, func(i int) error {
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	c.Assert(errs, gc.HasLen, 0)
+}
+
+func (*bulkSuite) TestBulkCallResults(c *gc.C) {
+	results := make([]int, 20)
+	errs := common.BulkCall(len(results), func(i int) error {
+		results[i] = i * 2
+		switch i % 3 {
+		case 1:
+			return common.ErrPerm
+		case 2:
+			return errors.NotFoundf("entity %d", i)
+		}
+		return nil
+	})
+	c.Assert(errs, gc.HasLen, len(results))
+	for i, err := range errs {
+		c.Check(results[i], gc.Equals, i*2)
+		switch i % 3 {
+		case 0:
+			c.Check(err, gc.IsNil)
+		case 1:
+			c.Check(err, jc.DeepEquals, apiservertesting.ErrUnauthorized)
+		case 2:
+			c.Check(err, jc.DeepEquals, &params.Error{
+				Message: fmt.Sprintf("entity %d not found", i),
+				Code:    params.CodeNotFound,
+			})
+		}
+	}
+}
+
+func (s *bulkSuite) TestBulkCallConcurrencyLimit(c *gc.C) {
+	s.PatchValue(&common.MaxBulkConcurrency, 3)
+	var mu sync.Mutex
+	var running, maxRunning int
+	release := make(chan struct{})
+	go func() {
+		// Release the calls one at a time, so they
+		// overlap as much as the limit allows.
+		for i := 0; i < 10; i++ {
+			release <- struct{}{}
+		}
+	}()
+	common.BulkCall(10, func(i int) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	c.Assert(maxRunning, jc.LessThan, 4)
+	c.Assert(maxRunning, jc.GreaterThan, 0)
+}
+
+func (s *bulkSuite) TestBulkCallSequential(c *gc.C) {
+	s.PatchValue(&common.MaxBulkConcurrency, 1)
+	var calls []int
+	common.BulkCall(5, func(i int) error {
+		calls = append(calls, i)
+		return nil
+	})
+	c.Assert(calls, jc.DeepEquals, []int{0, 1, 2, 3, 4})
+}
+
+func (*bulkSuite) TestBulkErrorResults(c *gc.C) {
+	results := common.BulkErrorResults(3, func(i int) error {
+		if i == 1 {
+			return common.ErrPerm
+		}
+		return nil
+	})
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+		},
+	})
+}
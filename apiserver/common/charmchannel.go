@@ -0,0 +1,44 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/state"
+)
+
+// jujuAttrsRepository is implemented by charm repositories which pass
+// juju metadata attributes on to the charm store with each request.
+type jujuAttrsRepository interface {
+	WithJujuAttrs(attrs string) charm.Repository
+}
+
+// CharmRepoForChannel returns a repository resolving the charm
+// revisions published to the given channel of repo, which also passes
+// the given metadata attributes on to the charm store. The stable
+// channel is resolved by repo itself; other channels are requested
+// from the charm store through its metadata attributes.
+func CharmRepoForChannel(repo charm.Repository, channel state.CharmChannel, attrs ...string) (charm.Repository, error) {
+	if err := channel.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if channel != state.StableChannel {
+		attrs = append(attrs, "channel="+string(channel))
+	}
+	if len(attrs) == 0 {
+		return repo, nil
+	}
+	store, ok := repo.(jujuAttrsRepository)
+	if !ok {
+		if channel != state.StableChannel {
+			return nil, errors.NotSupportedf("charm channel %q with %T", channel, repo)
+		}
+		return repo, nil
+	}
+	return store.WithJujuAttrs(strings.Join(attrs, ",")), nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+type charmChannelSuite struct{}
+
+var _ = gc.Suite(&charmChannelSuite{})
+
+// attrsRepository records the juju metadata attributes it is
+// specialised with.
+type attrsRepository struct {
+	charm.Repository
+	attrs string
+}
+
+func (r *attrsRepository) WithJujuAttrs(attrs string) charm.Repository {
+	return &attrsRepository{attrs: attrs}
+}
+
+func (s *charmChannelSuite) TestStableChannel(c *gc.C) {
+	repo := &attrsRepository{}
+	channelRepo, err := common.CharmRepoForChannel(repo, state.StableChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channelRepo, gc.Equals, repo)
+
+	channelRepo, err = common.CharmRepoForChannel(repo, state.StableChannel, "environment_uuid=foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channelRepo.(*attrsRepository).attrs, gc.Equals, "environment_uuid=foo")
+}
+
+func (s *charmChannelSuite) TestOtherChannels(c *gc.C) {
+	repo := &attrsRepository{}
+	channelRepo, err := common.CharmRepoForChannel(repo, state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channelRepo.(*attrsRepository).attrs, gc.Equals, "channel=edge")
+
+	channelRepo, err = common.CharmRepoForChannel(repo, state.CandidateChannel, "environment_uuid=foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channelRepo.(*attrsRepository).attrs, gc.Equals, "environment_uuid=foo,channel=candidate")
+}
+
+func (s *charmChannelSuite) TestChannelsNotSupported(c *gc.C) {
+	repo := &charm.LocalRepository{Path: c.MkDir()}
+	channelRepo, err := common.CharmRepoForChannel(repo, state.StableChannel, "environment_uuid=foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(channelRepo, gc.Equals, repo)
+
+	_, err = common.CharmRepoForChannel(repo, state.EdgeChannel)
+	c.Assert(err, gc.ErrorMatches, `charm channel "edge" with \*charm.LocalRepository not supported`)
+}
+
+func (s *charmChannelSuite) TestInvalidChannel(c *gc.C) {
+	_, err := common.CharmRepoForChannel(&attrsRepository{}, "beta")
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+}
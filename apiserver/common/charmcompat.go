@@ -0,0 +1,108 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+)
+
+// CheckCharmCompatibility reports the ways in which the deployment or
+// upgrade described by args is incompatible with the environment, so
+// that it can be refused before anything is provisioned. The charm
+// must already have been added to the environment.
+func CheckCharmCompatibility(st *state.State, env environs.Environ, args params.CharmCompatibilityCheck) ([]params.CharmIncompatibility, error) {
+	curl, err := charm.ParseURL(args.CharmURL)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ch, err := st.Charm(curl)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	stateArgs := state.CharmCompatibilityParams{
+		Charm:         ch,
+		ToMachineSpec: args.ToMachineSpec,
+	}
+	if args.ServiceName != "" {
+		if stateArgs.Service, err = st.Service(args.ServiceName); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if args.Storage != nil {
+		stateArgs.Storage = make(map[string]state.StorageConstraints)
+		for name, cons := range args.Storage {
+			stateArgs.Storage[name] = state.StorageConstraints{
+				Pool:  cons.Pool,
+				Size:  cons.Size,
+				Count: cons.Count,
+			}
+		}
+	}
+	problems, err := st.CheckCharmCompatibility(stateArgs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []params.CharmIncompatibility
+	for _, problem := range problems {
+		result = append(result, params.CharmIncompatibility{
+			Kind:    string(problem.Kind),
+			Message: problem.Message,
+		})
+	}
+
+	var networks []string
+	for _, tag := range args.Networks {
+		t, err := names.ParseNetworkTag(tag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		networks = append(networks, t.Id())
+	}
+	networks = append(networks, args.Constraints.IncludeNetworks()...)
+	if len(networks) > 0 {
+		if _, ok := environs.SupportsNetworking(env); !ok {
+			result = append(result, params.CharmIncompatibility{
+				Kind: string(state.IncompatibleNetwork),
+				Message: fmt.Sprintf(
+					"networks %s requested, but the environment does not support networking",
+					strings.Join(networks, ", "),
+				),
+			})
+		}
+	}
+	return result, nil
+}
+
+type incompatibleCharmError struct {
+	curl     string
+	problems []params.CharmIncompatibility
+}
+
+func (e *incompatibleCharmError) Error() string {
+	messages := make([]string, len(e.problems))
+	for i, problem := range e.problems {
+		messages[i] = fmt.Sprintf("%s: %s", problem.Kind, problem.Message)
+	}
+	return fmt.Sprintf("charm %q is not compatible: %s", e.curl, strings.Join(messages, "; "))
+}
+
+// IncompatibleCharmError returns an error reporting that the charm
+// with the given URL is incompatible for the given reasons.
+func IncompatibleCharmError(curl string, problems []params.CharmIncompatibility) error {
+	return &incompatibleCharmError{curl: curl, problems: problems}
+}
+
+func IsIncompatibleCharmError(err error) bool {
+	_, ok := err.(*incompatibleCharmError)
+	return ok
+}
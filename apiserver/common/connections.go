@@ -0,0 +1,22 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/juju/apiserver/params"
+)
+
+// ConnectionTracker is implemented by the API server to allow facades
+// to inspect and terminate the API connections it is serving. It is
+// made available to facades as the "connections" named resource.
+type ConnectionTracker interface {
+	Resource
+
+	// Connections returns details of the connections currently
+	// being served.
+	Connections() []params.ConnectionDetails
+
+	// Disconnect terminates the connection with the given id.
+	Disconnect(id int64) error
+}
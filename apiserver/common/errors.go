@@ -12,6 +12,7 @@ import (
 	"github.com/juju/txn"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/leadership"
 	"github.com/juju/juju/state"
 )
@@ -64,6 +65,44 @@ func IsUnknownEnviromentError(err error) bool {
 	return ok
 }
 
+type environmentSuspendedError struct {
+	reason string
+}
+
+func (e *environmentSuspendedError) Error() string {
+	return fmt.Sprintf("environment suspended: %s", e.reason)
+}
+
+// EnvironmentSuspendedError returns an error reporting that the
+// environment is suspended for the given reason.
+func EnvironmentSuspendedError(reason string) error {
+	return &environmentSuspendedError{reason: reason}
+}
+
+func IsEnvironmentSuspendedError(err error) bool {
+	_, ok := err.(*environmentSuspendedError)
+	return ok
+}
+
+type wrongLifecycleError struct {
+	message string
+}
+
+func (e *wrongLifecycleError) Error() string {
+	return e.message
+}
+
+// WrongLifecycleErrorf returns an error reporting that an entity
+// cannot be operated on at its current stage of life.
+func WrongLifecycleErrorf(format string, args ...interface{}) error {
+	return &wrongLifecycleError{fmt.Sprintf(format, args...)}
+}
+
+func IsWrongLifecycleError(err error) bool {
+	_, ok := err.(*wrongLifecycleError)
+	return ok
+}
+
 var (
 	ErrBadId              = stderrors.New("id not found")
 	ErrBadCreds           = stderrors.New("invalid entity name or password")
@@ -144,8 +183,24 @@ func ServerError(err error) *params.Error {
 		code = params.CodeNotProvisioned
 	case state.IsUpgradeInProgressError(err):
 		code = params.CodeUpgradeInProgress
+	case state.IsConfigValidationError(err),
+		config.IsInvalidConfigValueError(err),
+		config.IsImmutableConfigValueError(err):
+		code = params.CodeConfigInvalid
+	case state.IsCharmStorageQuotaExceededError(err):
+		code = params.CodeQuotaLimitExceeded
+	case state.IsRelationLimitError(err):
+		code = params.CodeRelationLimitExceeded
+	case state.IsUnitLimitError(err):
+		code = params.CodeUnitLimitExceeded
 	case IsUnknownEnviromentError(err):
 		code = params.CodeNotFound
+	case IsEnvironmentSuspendedError(err):
+		code = params.CodeEnvironmentSuspended
+	case IsIncompatibleCharmError(err):
+		code = params.CodeIncompatibleCharm
+	case IsWrongLifecycleError(err):
+		code = params.CodeWrongLifecycle
 	default:
 		code = params.ErrCode(err)
 	}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/leadership"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
@@ -113,6 +114,30 @@ var errorTransformTests = []struct {
 	err:        leadership.ErrClaimDenied,
 	code:       params.CodeLeadershipClaimDenied,
 	helperFunc: params.IsCodeLeadershipClaimDenied,
+}, {
+	err:        &state.ConfigValidationError{map[string]string{"port": "must be at most 65535"}},
+	code:       params.CodeConfigInvalid,
+	helperFunc: params.IsCodeConfigInvalid,
+}, {
+	err:        &config.InvalidConfigValueError{Key: "hook-output-limit", Value: "0", Reason: stderrors.New("must be positive")},
+	code:       params.CodeConfigInvalid,
+	helperFunc: params.IsCodeConfigInvalid,
+}, {
+	err:        &config.ImmutableConfigValueError{Key: "api-port", Old: 17070, New: 42},
+	code:       params.CodeConfigInvalid,
+	helperFunc: params.IsCodeConfigInvalid,
+}, {
+	err:        &state.CharmStorageQuotaExceededError{Usage: 1024, Quota: 2048, Size: 4096},
+	code:       params.CodeQuotaLimitExceeded,
+	helperFunc: params.IsCodeQuotaLimitExceeded,
+}, {
+	err:        &state.RelationLimitError{ServiceName: "mysql", RelationName: "server", Limit: 1},
+	code:       params.CodeRelationLimitExceeded,
+	helperFunc: params.IsCodeRelationLimitExceeded,
+}, {
+	err:        &state.UnitLimitError{ServiceName: "mysql", Limit: 3},
+	code:       params.CodeUnitLimitExceeded,
+	helperFunc: params.IsCodeUnitLimitExceeded,
 }, {
 	err:        common.ErrOperationBlocked("test"),
 	code:       params.CodeOperationBlocked,
@@ -127,6 +152,14 @@ var errorTransformTests = []struct {
 	err:        common.UnknownEnvironmentError("dead-beef-123456"),
 	code:       params.CodeNotFound,
 	helperFunc: params.IsCodeNotFound,
+}, {
+	err:        common.EnvironmentSuspendedError("secret rejected"),
+	code:       params.CodeEnvironmentSuspended,
+	helperFunc: params.IsCodeEnvironmentSuspended,
+}, {
+	err:        common.WrongLifecycleErrorf("unit-mysql-0 still alive"),
+	code:       params.CodeWrongLifecycle,
+	helperFunc: params.IsCodeWrongLifecycle,
 }, {
 	err:  nil,
 	code: "",
@@ -149,6 +182,10 @@ func (s *errorsSuite) TestErrorTransform(c *gc.C) {
 			if t.helperFunc != nil {
 				c.Assert(err1, jc.Satisfies, t.helperFunc)
 			}
+			if t.code != "" {
+				_, ok := params.LookupErrorCode(t.code)
+				c.Assert(ok, jc.IsTrue, gc.Commentf("code %q not registered", t.code))
+			}
 		}
 	}
 }
@@ -3,6 +3,10 @@
 
 package common
 
+import (
+	"github.com/juju/juju/apiserver/params"
+)
+
 var (
 	ValidateNewFacade = validateNewFacade
 	WrapNewFacade     = wrapNewFacade
@@ -25,5 +29,11 @@ func SanitizeFacades(patcher Patcher) {
 type Versions versions
 
 func DescriptionFromVersions(name string, vers Versions) FacadeDescription {
-	return descriptionFromVersions(name, versions(vers))
+	return descriptionFromVersions(name, versions(vers), nil)
+}
+
+// PatchPrechecks gives the test a clean precheck registry, so that
+// it can register checkers without affecting the real ones.
+func PatchPrechecks(patcher Patcher) {
+	patcher.PatchValue(&prechecks, make(map[params.PrecheckOperation]map[string]Prechecker))
 }
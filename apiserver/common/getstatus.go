@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// StatusGetter implements a common Status method for use by
+// various facades.
+type StatusGetter struct {
+	st           state.EntityFinder
+	getCanAccess GetAuthFunc
+}
+
+// NewStatusGetter returns a new StatusGetter. The GetAuthFunc will be
+// used on each invocation of Status to determine current
+// permissions.
+func NewStatusGetter(st state.EntityFinder, getCanAccess GetAuthFunc) *StatusGetter {
+	return &StatusGetter{
+		st:           st,
+		getCanAccess: getCanAccess,
+	}
+}
+
+func (s *StatusGetter) getEntityStatus(tag names.Tag) (params.StatusResult, error) {
+	entity, err := s.st.FindEntity(tag)
+	if err != nil {
+		return params.StatusResult{}, err
+	}
+	getter, ok := entity.(state.StatusGetter)
+	if !ok {
+		return params.StatusResult{}, NotSupportedError(tag, "getting status")
+	}
+	status, info, data, err := getter.Status()
+	if err != nil {
+		return params.StatusResult{}, err
+	}
+	return params.StatusResult{
+		Id:     tag.Id(),
+		Status: params.Status(status),
+		Info:   info,
+		Data:   data,
+	}, nil
+}
+
+// Status returns the status, status info and status data of each
+// given entity.
+func (s *StatusGetter) Status(args params.Entities) (params.StatusResults, error) {
+	result := params.StatusResults{
+		Results: make([]params.StatusResult, len(args.Entities)),
+	}
+	if len(args.Entities) == 0 {
+		return result, nil
+	}
+	canAccess, err := s.getCanAccess()
+	if err != nil {
+		return params.StatusResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = ServerError(ErrPerm)
+			continue
+		}
+		err = ErrPerm
+		if canAccess(tag) {
+			result.Results[i], err = s.getEntityStatus(tag)
+		}
+		result.Results[i].Error = ServerError(err)
+	}
+	return result, nil
+}
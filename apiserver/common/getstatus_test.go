@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"fmt"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/state"
+)
+
+type statusGetterSuite struct{}
+
+var _ = gc.Suite(&statusGetterSuite{})
+
+var _ state.StatusGetter = new(fakeStatusSetter)
+
+func (*statusGetterSuite) TestStatus(c *gc.C) {
+	st := &fakeState{
+		entities: map[names.Tag]entityWithError{
+			u("x/0"): &fakeStatusSetter{status: state.StatusRunning, info: "ready", data: map[string]interface{}{
+				"connections": 42,
+			}},
+			u("x/1"): &fakeStatusSetter{status: state.StatusBlocked, info: "need db"},
+			u("x/2"): &fakeStatusSetter{fetchError: "x2 error"},
+			u("x/3"): &fakeStatusSetter{status: state.StatusRunning},
+		},
+	}
+	getCanAccess := func() (common.AuthFunc, error) {
+		x0 := u("x/0")
+		x1 := u("x/1")
+		x2 := u("x/2")
+		return func(tag names.Tag) bool {
+			return tag == x0 || tag == x1 || tag == x2
+		}, nil
+	}
+	s := common.NewStatusGetter(st, getCanAccess)
+	result, err := s.Status(params.Entities{
+		Entities: []params.Entity{
+			{"unit-x-0"}, {"unit-x-1"}, {"unit-x-2"}, {"unit-x-3"}, {"invalid"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StatusResults{
+		Results: []params.StatusResult{{
+			Id:     "x/0",
+			Status: params.StatusRunning,
+			Info:   "ready",
+			Data:   map[string]interface{}{"connections": 42},
+		}, {
+			Id:     "x/1",
+			Status: params.StatusBlocked,
+			Info:   "need db",
+		}, {
+			Error: &params.Error{Message: "x2 error"},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}},
+	})
+}
+
+func (*statusGetterSuite) TestStatusError(c *gc.C) {
+	getCanAccess := func() (common.AuthFunc, error) {
+		return nil, fmt.Errorf("pow")
+	}
+	s := common.NewStatusGetter(&fakeState{}, getCanAccess)
+	_, err := s.Status(params.Entities{Entities: []params.Entity{{"unit-x-0"}}})
+	c.Assert(err, gc.ErrorMatches, "pow")
+}
+
+func (*statusGetterSuite) TestStatusNoArgsNoError(c *gc.C) {
+	getCanAccess := func() (common.AuthFunc, error) {
+		return nil, fmt.Errorf("pow")
+	}
+	s := common.NewStatusGetter(&fakeState{}, getCanAccess)
+	result, err := s.Status(params.Entities{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 0)
+}
@@ -0,0 +1,39 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// UnitHookQueue returns the hooks the given unit's agent last reported
+// it has yet to run, and those of them it has been asked not to run.
+func UnitHookQueue(unit *state.Unit) (params.HookQueueResult, error) {
+	queue, err := unit.HookQueue()
+	if err != nil {
+		return params.HookQueueResult{}, err
+	}
+	var result params.HookQueueResult
+	if queue.Pending != nil {
+		pending := toParamsQueuedHook(*queue.Pending)
+		result.Pending = &pending
+	}
+	for _, hook := range queue.Queued {
+		result.Queued = append(result.Queued, toParamsQueuedHook(hook))
+	}
+	for _, hook := range queue.Dropped {
+		result.Dropped = append(result.Dropped, toParamsQueuedHook(hook))
+	}
+	return result, nil
+}
+
+func toParamsQueuedHook(hook state.QueuedHook) params.QueuedHook {
+	return params.QueuedHook{
+		Id:         hook.Id(),
+		Kind:       hook.Kind,
+		RelationId: hook.RelationId,
+		RemoteUnit: hook.RemoteUnit,
+	}
+}
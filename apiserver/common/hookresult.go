@@ -0,0 +1,25 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// UnitHookResult returns the outcome of the hook the given unit's
+// agent ran most recently, with the tail of its output.
+func UnitHookResult(unit *state.Unit) (*params.HookResult, error) {
+	result, err := unit.LastHookResult()
+	if err != nil {
+		return nil, err
+	}
+	return &params.HookResult{
+		Hook:      result.Hook,
+		Time:      result.Time,
+		Error:     result.Error,
+		Output:    result.Output,
+		Truncated: result.Truncated,
+	}, nil
+}
@@ -50,17 +50,16 @@ func (lg *LifeGetter) Life(args params.Entities) (params.LifeResults, error) {
 	if err != nil {
 		return params.LifeResults{}, errors.Trace(err)
 	}
-	for i, entity := range args.Entities {
-		tag, err := names.ParseTag(entity.Tag)
-		if err != nil {
-			result.Results[i].Error = ServerError(ErrPerm)
-			continue
+	errs := BulkCall(len(args.Entities), func(i int) error {
+		tag, err := names.ParseTag(args.Entities[i].Tag)
+		if err != nil || !canRead(tag) {
+			return ErrPerm
 		}
-		err = ErrPerm
-		if canRead(tag) {
-			result.Results[i].Life, err = lg.oneLife(tag)
-		}
-		result.Results[i].Error = ServerError(err)
+		result.Results[i].Life, err = lg.oneLife(tag)
+		return err
+	})
+	for i, err := range errs {
+		result.Results[i].Error = err
 	}
 	return result, nil
 }
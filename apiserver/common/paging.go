@@ -0,0 +1,157 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// PageEntry identifies an entry in a list to be paged through with
+// Paginate.
+type PageEntry struct {
+	// Id uniquely identifies the entry within the list.
+	Id string
+
+	// SortKey holds the value of the field the list is sorted by.
+	// Entries with equal sort keys are ordered by Id.
+	SortKey string
+}
+
+// Paginate sorts entries as requested by page and returns the indices
+// into entries of those on the page, in order, together with the
+// cursor to pass as After for the following page. The cursor is empty
+// if there are no more entries. Runs of digits in IDs and sort keys
+// are compared numerically, so that "machine-10" sorts after
+// "machine-9".
+//
+// The cursor records the position of the last entry returned rather
+// than its index, so entries added or removed between calls neither
+// repeat nor skip entries that were there throughout.
+func Paginate(entries []PageEntry, page params.ListPage, maxLimit int) ([]int, string, error) {
+	limit := page.Limit
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+	var after *PageEntry
+	if page.After != "" {
+		cursor, err := decodeCursor(page.After)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &cursor
+	}
+	sorted := &pageEntries{entries, make([]int, len(entries)), page.Descending}
+	for i := range sorted.order {
+		sorted.order[i] = i
+	}
+	sort.Sort(sorted)
+
+	var selected []int
+	for _, i := range sorted.order {
+		if after != nil && !sorted.before(*after, entries[i]) {
+			continue
+		}
+		if len(selected) == limit {
+			last := entries[selected[len(selected)-1]]
+			return selected, encodeCursor(last), nil
+		}
+		selected = append(selected, i)
+	}
+	return selected, "", nil
+}
+
+func encodeCursor(entry PageEntry) string {
+	return base64.URLEncoding.EncodeToString([]byte(entry.SortKey + "\x00" + entry.Id))
+}
+
+func decodeCursor(cursor string) (PageEntry, error) {
+	data, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return PageEntry{}, errors.NotValidf("page cursor %q", cursor)
+	}
+	fields := strings.SplitN(string(data), "\x00", 2)
+	if len(fields) != 2 {
+		return PageEntry{}, errors.NotValidf("page cursor %q", cursor)
+	}
+	return PageEntry{SortKey: fields[0], Id: fields[1]}, nil
+}
+
+// pageEntries sorts the indices of a list of entries.
+type pageEntries struct {
+	entries    []PageEntry
+	order      []int
+	descending bool
+}
+
+func (p *pageEntries) Len() int {
+	return len(p.order)
+}
+
+func (p *pageEntries) Swap(i, j int) {
+	p.order[i], p.order[j] = p.order[j], p.order[i]
+}
+
+func (p *pageEntries) Less(i, j int) bool {
+	return p.before(p.entries[p.order[i]], p.entries[p.order[j]])
+}
+
+// before reports whether a is listed before b.
+func (p *pageEntries) before(a, b PageEntry) bool {
+	if p.descending {
+		a, b = b, a
+	}
+	if a.SortKey != b.SortKey {
+		return naturalLess(a.SortKey, b.SortKey)
+	}
+	return naturalLess(a.Id, b.Id)
+}
+
+// naturalLess reports whether a sorts before b when runs of digits
+// are compared by numeric value.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := isDigit(a[0]), isDigit(b[0])
+		if aDigits && bDigits {
+			aRun, bRun := leadingDigits(a), leadingDigits(b)
+			aNum, bNum := strings.TrimLeft(aRun, "0"), strings.TrimLeft(bRun, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			if len(aRun) != len(bRun) {
+				// Fewer leading zeros sorts first.
+				return len(aRun) < len(bRun)
+			}
+			a, b = a[len(aRun):], b[len(bRun):]
+			continue
+		}
+		if aDigits != bDigits || a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// leadingDigits returns the longest prefix of s made up of digits.
+func leadingDigits(s string) string {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type pagingSuite struct{}
+
+var _ = gc.Suite(&pagingSuite{})
+
+// pageIds returns the IDs of the entries with the given indices.
+func pageIds(entries []common.PageEntry, indices []int) []string {
+	result := make([]string, len(indices))
+	for i, index := range indices {
+		result[i] = entries[index].Id
+	}
+	return result
+}
+
+func idEntries(ids ...string) []common.PageEntry {
+	entries := make([]common.PageEntry, len(ids))
+	for i, id := range ids {
+		entries[i] = common.PageEntry{Id: id}
+	}
+	return entries
+}
+
+func (s *pagingSuite) TestNaturalOrder(c *gc.C) {
+	entries := idEntries("10", "2", "0/lxc/10", "0/lxc/9", "0", "1")
+	page, next, err := common.Paginate(entries, params.ListPage{}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(next, gc.Equals, "")
+	c.Assert(pageIds(entries, page), jc.DeepEquals, []string{
+		"0", "0/lxc/9", "0/lxc/10", "1", "2", "10",
+	})
+}
+
+func (s *pagingSuite) TestSortKey(c *gc.C) {
+	entries := []common.PageEntry{
+		{Id: "wordpress/1", SortKey: "started"},
+		{Id: "mysql/0", SortKey: "error"},
+		{Id: "wordpress/0", SortKey: "started"},
+	}
+	page, _, err := common.Paginate(entries, params.ListPage{}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pageIds(entries, page), jc.DeepEquals, []string{
+		"mysql/0", "wordpress/0", "wordpress/1",
+	})
+
+	page, _, err = common.Paginate(entries, params.ListPage{Descending: true}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pageIds(entries, page), jc.DeepEquals, []string{
+		"wordpress/1", "wordpress/0", "mysql/0",
+	})
+}
+
+func (s *pagingSuite) TestPages(c *gc.C) {
+	entries := idEntries("0", "1", "2", "3", "4")
+	var all []string
+	var pages int
+	listPage := params.ListPage{Limit: 5}
+	for {
+		page, next, err := common.Paginate(entries, listPage, 2)
+		c.Assert(err, jc.ErrorIsNil)
+		all = append(all, pageIds(entries, page)...)
+		pages++
+		if next == "" {
+			break
+		}
+		listPage.After = next
+	}
+	c.Assert(pages, gc.Equals, 3)
+	c.Assert(all, jc.DeepEquals, []string{"0", "1", "2", "3", "4"})
+}
+
+func (s *pagingSuite) TestExactPage(c *gc.C) {
+	entries := idEntries("0", "1")
+	page, next, err := common.Paginate(entries, params.ListPage{Limit: 2}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pageIds(entries, page), jc.DeepEquals, []string{"0", "1"})
+	c.Assert(next, gc.Equals, "")
+}
+
+func (s *pagingSuite) TestCursorSurvivesRemoval(c *gc.C) {
+	entries := idEntries("0", "1", "2", "3")
+	_, next, err := common.Paginate(entries, params.ListPage{Limit: 2}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Remove the last entry of the first page, and add one
+	// before it; neither affects the second page.
+	entries = idEntries("00", "0", "2", "3")
+	page, next, err := common.Paginate(entries, params.ListPage{After: next, Limit: 2}, 100)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pageIds(entries, page), jc.DeepEquals, []string{"2", "3"})
+	c.Assert(next, gc.Equals, "")
+}
+
+func (s *pagingSuite) TestInvalidCursor(c *gc.C) {
+	for _, cursor := range []string{"!!", "bm9zZXBhcmF0b3I="} {
+		_, _, err := common.Paginate(idEntries("0"), params.ListPage{After: cursor}, 100)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, `page cursor ".*" not valid`)
+	}
+}
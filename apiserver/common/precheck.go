@@ -0,0 +1,219 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// Prechecker checks whether an operation on the given entity should
+// go ahead without being forced. It returns a message for each problem
+// found; an error is returned only if the check could not be made.
+type Prechecker func(st *state.State, entity names.Tag) ([]string, error)
+
+var prechecks = make(map[params.PrecheckOperation]map[string]Prechecker)
+
+// RegisterPrechecker registers a checker with the given name, to be
+// run before the given operation. It panics if a checker with that
+// name is already registered for the operation.
+func RegisterPrechecker(op params.PrecheckOperation, name string, check Prechecker) {
+	checks, ok := prechecks[op]
+	if !ok {
+		checks = make(map[string]Prechecker)
+		prechecks[op] = checks
+	}
+	if _, ok := checks[name]; ok {
+		panic(fmt.Sprintf("prechecker %q already registered for %s", name, op))
+	}
+	checks[name] = check
+}
+
+// RunPrechecks runs all the checkers registered for the given operation
+// against each of the given entities, and reports the problems found.
+// Checkers are run in name order.
+func RunPrechecks(st *state.State, op params.PrecheckOperation, entities []names.Tag) (params.PrecheckReport, error) {
+	report := params.PrecheckReport{Operation: op}
+	checks, ok := prechecks[op]
+	if !ok {
+		return report, errors.NotValidf("precheck operation %q", op)
+	}
+	checkNames := make([]string, 0, len(checks))
+	for name := range checks {
+		checkNames = append(checkNames, name)
+	}
+	sort.Strings(checkNames)
+	for _, entity := range entities {
+		for _, name := range checkNames {
+			messages, err := checks[name](st, entity)
+			if err != nil {
+				return report, errors.Annotatef(err, "running %s check on %s", name, entity)
+			}
+			for _, message := range messages {
+				report.Problems = append(report.Problems, params.PrecheckProblem{
+					Check:   name,
+					Entity:  entity.String(),
+					Message: message,
+				})
+			}
+		}
+	}
+	return report, nil
+}
+
+// EnsurePrechecksPass runs the prechecks for the given operation and
+// returns an error describing the problems found, if any.
+func EnsurePrechecksPass(st *state.State, op params.PrecheckOperation, entities []names.Tag) error {
+	report, err := RunPrechecks(st, op, entities)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(report.Problems) == 0 {
+		return nil
+	}
+	return ErrPrecheckFailed(report)
+}
+
+// ErrPrecheckFailed returns an error reporting the problems found by
+// the prechecks of an operation.
+func ErrPrecheckFailed(report params.PrecheckReport) *params.Error {
+	lines := make([]string, len(report.Problems))
+	for i, problem := range report.Problems {
+		lines[i] = fmt.Sprintf("%s: %s", problem.Entity, problem.Message)
+	}
+	return &params.Error{
+		Code: params.CodePrecheckFailed,
+		Message: fmt.Sprintf(
+			"%s prechecks failed:\n%s", report.Operation, strings.Join(lines, "\n"),
+		),
+	}
+}
+
+func init() {
+	RegisterPrechecker(params.PrecheckDestroyMachine, "containers", checkMachineContainers)
+	RegisterPrechecker(params.PrecheckDestroyMachine, "storage", checkMachineStorage)
+	RegisterPrechecker(params.PrecheckDestroyEnvironment, "storage", checkEnvironStorage)
+	RegisterPrechecker(params.PrecheckUpgrade, "agents", checkAgentsHealthy)
+}
+
+// checkMachineContainers reports containers hosted by a machine, which
+// would be lost along with it.
+func checkMachineContainers(st *state.State, entity names.Tag) ([]string, error) {
+	machine, err := st.Machine(entity.Id())
+	if errors.IsNotFound(err) {
+		// The operation itself will report the missing machine.
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	containers, err := machine.Containers()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(containers) == 0 {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("machine hosts containers %s", strings.Join(containers, ", "))}, nil
+}
+
+// checkMachineStorage reports volumes attached to a machine which hold
+// storage that has not been released.
+func checkMachineStorage(st *state.State, entity names.Tag) ([]string, error) {
+	attachments, err := st.MachineVolumeAttachments(names.NewMachineTag(entity.Id()))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var messages []string
+	for _, attachment := range attachments {
+		volume, err := st.Volume(attachment.Volume())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		storageTag, err := volume.StorageInstance()
+		if errors.IsNotAssigned(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		storage, err := st.StorageInstance(storageTag)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if storage.Life() == state.Alive {
+			messages = append(messages, fmt.Sprintf(
+				"volume %s holds storage %s which has not been released",
+				attachment.Volume().Id(), storageTag.Id(),
+			))
+		}
+	}
+	return messages, nil
+}
+
+// checkEnvironStorage reports unreleased storage on any machine in the
+// environment.
+func checkEnvironStorage(st *state.State, _ names.Tag) ([]string, error) {
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var messages []string
+	for _, machine := range machines {
+		machineMessages, err := checkMachineStorage(st, machine.Tag())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, message := range machineMessages {
+			messages = append(messages, fmt.Sprintf("machine %s: %s", machine.Id(), message))
+		}
+	}
+	return messages, nil
+}
+
+// checkAgentsHealthy reports machine and unit agents in an error or
+// failed state, which may not upgrade cleanly.
+func checkAgentsHealthy(st *state.State, _ names.Tag) ([]string, error) {
+	var messages []string
+	machines, err := st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, machine := range machines {
+		status, info, _, err := machine.Status()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if status == state.StatusError {
+			messages = append(messages, fmt.Sprintf("machine %s agent is in error: %s", machine.Id(), info))
+		}
+	}
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, service := range services {
+		units, err := service.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, unit := range units {
+			status, info, _, err := unit.AgentStatus()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if status == state.StatusError || status == state.StatusFailed {
+				messages = append(messages, fmt.Sprintf("unit %s agent is %s: %s", unit.Name(), status, info))
+			}
+		}
+	}
+	return messages, nil
+}
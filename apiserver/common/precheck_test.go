@@ -0,0 +1,108 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type precheckSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&precheckSuite{})
+
+func (s *precheckSuite) TestRunPrechecksRegistered(c *gc.C) {
+	common.PatchPrechecks(s)
+	var checked []string
+	check := func(name string, messages ...string) common.Prechecker {
+		return func(st *state.State, entity names.Tag) ([]string, error) {
+			checked = append(checked, name+" "+entity.String())
+			return messages, nil
+		}
+	}
+	common.RegisterPrechecker(params.PrecheckDestroyMachine, "b", check("b", "too big"))
+	common.RegisterPrechecker(params.PrecheckDestroyMachine, "a", check("a"))
+	common.RegisterPrechecker(params.PrecheckUpgrade, "c", check("c", "not run"))
+	c.Assert(func() {
+		common.RegisterPrechecker(params.PrecheckDestroyMachine, "a", check("a"))
+	}, gc.PanicMatches, `prechecker "a" already registered for destroy-machine`)
+
+	tags := []names.Tag{names.NewMachineTag("1"), names.NewMachineTag("2")}
+	report, err := common.RunPrechecks(s.State, params.PrecheckDestroyMachine, tags)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(checked, jc.DeepEquals, []string{"a machine-1", "b machine-1", "a machine-2", "b machine-2"})
+	c.Assert(report, jc.DeepEquals, params.PrecheckReport{
+		Operation: params.PrecheckDestroyMachine,
+		Problems: []params.PrecheckProblem{
+			{Check: "b", Entity: "machine-1", Message: "too big"},
+			{Check: "b", Entity: "machine-2", Message: "too big"},
+		},
+	})
+
+	err = common.EnsurePrechecksPass(s.State, params.PrecheckDestroyMachine, tags)
+	c.Assert(err, jc.Satisfies, params.IsCodePrecheckFailed)
+	c.Assert(err, gc.ErrorMatches, "destroy-machine prechecks failed:\n"+
+		"machine-1: too big\n"+
+		"machine-2: too big",
+	)
+}
+
+func (s *precheckSuite) TestRunPrechecksError(c *gc.C) {
+	common.PatchPrechecks(s)
+	common.RegisterPrechecker(params.PrecheckUpgrade, "broken", func(*state.State, names.Tag) ([]string, error) {
+		return nil, errors.New("boom")
+	})
+	_, err := common.RunPrechecks(s.State, params.PrecheckUpgrade, []names.Tag{s.State.EnvironTag()})
+	c.Assert(err, gc.ErrorMatches, "running broken check on environment-.*: boom")
+}
+
+func (s *precheckSuite) TestRunPrechecksUnknownOperation(c *gc.C) {
+	_, err := common.RunPrechecks(s.State, "explode", nil)
+	c.Assert(err, gc.ErrorMatches, `precheck operation "explode" not valid`)
+}
+
+func (s *precheckSuite) TestMachineContainersCheck(c *gc.C) {
+	host := s.Factory.MakeMachine(c, nil)
+	tags := []names.Tag{host.Tag()}
+	err := common.EnsurePrechecksPass(s.State, params.PrecheckDestroyMachine, tags)
+	c.Assert(err, jc.ErrorIsNil)
+
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, host.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	report, err := common.RunPrechecks(s.State, params.PrecheckDestroyMachine, tags)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Problems, jc.DeepEquals, []params.PrecheckProblem{{
+		Check:   "containers",
+		Entity:  host.Tag().String(),
+		Message: "machine hosts containers " + container.Id(),
+	}})
+}
+
+func (s *precheckSuite) TestAgentsCheck(c *gc.C) {
+	tags := []names.Tag{s.State.EnvironTag()}
+	machine := s.Factory.MakeMachine(c, nil)
+	err := common.EnsurePrechecksPass(s.State, params.PrecheckUpgrade, tags)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = machine.SetStatus(state.StatusError, "oops", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = common.EnsurePrechecksPass(s.State, params.PrecheckUpgrade, tags)
+	c.Assert(err, gc.ErrorMatches, "upgrade prechecks failed:\n"+
+		"environment-.*: machine "+machine.Id()+" agent is in error: oops",
+	)
+}
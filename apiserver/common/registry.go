@@ -29,6 +29,24 @@ type facadeRecord struct {
 	// If the feature is not the empty string, then this facade
 	// is only returned when that feature flag is set.
 	feature string
+	// If the environFeature is not the empty string, then this
+	// facade is only available to connections to environments
+	// that have that feature enabled in their configuration.
+	environFeature string
+}
+
+// environFeatureEnabled reports whether the record is available in an
+// environment with the given feature flags enabled.
+func (r facadeRecord) environFeatureEnabled(environFeatures []string) bool {
+	if r.environFeature == "" {
+		return true
+	}
+	for _, feature := range environFeatures {
+		if feature == r.environFeature {
+			return true
+		}
+	}
+	return false
 }
 
 // RegisterFacade updates the global facade registry with a new version of a new type.
@@ -48,6 +66,19 @@ func RegisterFacadeForFeature(name string, version int, factory FacadeFactory, f
 	}
 }
 
+// RegisterFacadeForEnvironFeature updates the global facade registry
+// with a new version of a new type, which is only available in
+// environments that have the given feature enabled in their
+// configuration. The feature is checked every time a method on
+// the facade is looked up, so it can be enabled or disabled without
+// restarting the API server.
+func RegisterFacadeForEnvironFeature(name string, version int, factory FacadeFactory, facadeType reflect.Type, feature string) {
+	err := Facades.RegisterForEnvironFeature(name, version, factory, facadeType, feature)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // validateNewFacade ensures that the facade factory we have has the right
 // input and output parameters for being used as a NewFoo function.
 func validateNewFacade(funcValue reflect.Value) error {
@@ -142,6 +173,18 @@ func RegisterStandardFacadeForFeature(name string, version int, newFunc interfac
 	RegisterFacadeForFeature(name, version, wrapped, facadeType, feature)
 }
 
+// RegisterStandardFacadeForEnvironFeature registers a factory function
+// for a normal New* style function, as RegisterStandardFacade does,
+// for a facade that is only available in environments that have
+// the given feature enabled in their configuration.
+func RegisterStandardFacadeForEnvironFeature(name string, version int, newFunc interface{}, feature string) {
+	wrapped, facadeType, err := wrapNewFacade(newFunc)
+	if err != nil {
+		panic(err)
+	}
+	RegisterFacadeForEnvironFeature(name, version, wrapped, facadeType, feature)
+}
+
 // Facades is the registry that tracks all of the Facades that will be exposed in the API.
 // It can be used to List/Get/Register facades.
 // Most implementers of a facade will probably want to use
@@ -167,13 +210,30 @@ type FacadeRegistry struct {
 // The Type information is used to define what methods will be exported in the
 // API, and it must exactly match the actual object returned by the factory.
 func (f *FacadeRegistry) Register(name string, version int, factory FacadeFactory, facadeType reflect.Type, feature string) error {
-	if f.facades == nil {
-		f.facades = make(map[string]versions, 1)
-	}
-	record := facadeRecord{
+	return f.register(name, version, facadeRecord{
 		factory:    factory,
 		facadeType: facadeType,
 		feature:    feature,
+	})
+}
+
+// RegisterForEnvironFeature adds a single named facade at a given
+// version to the registry, as Register does, which is only available
+// in environments that have the given feature enabled.
+func (f *FacadeRegistry) RegisterForEnvironFeature(name string, version int, factory FacadeFactory, facadeType reflect.Type, feature string) error {
+	if feature == "" {
+		return errors.Errorf("environment feature for %s(%d) not specified", name, version)
+	}
+	return f.register(name, version, facadeRecord{
+		factory:        factory,
+		facadeType:     facadeType,
+		environFeature: feature,
+	})
+}
+
+func (f *FacadeRegistry) register(name string, version int, record facadeRecord) error {
+	if f.facades == nil {
+		f.facades = make(map[string]versions, 1)
 	}
 	if vers, ok := f.facades[name]; ok {
 		if _, ok := vers[version]; ok {
@@ -220,6 +280,17 @@ func (f *FacadeRegistry) GetType(name string, version int) (reflect.Type, error)
 	return record.facadeType, nil
 }
 
+// GetEnvironFeature returns the environment feature flag that must be
+// enabled for the given Facade name and version to be available, or
+// the empty string if the facade is available in every environment.
+func (f *FacadeRegistry) GetEnvironFeature(name string, version int) (string, error) {
+	record, err := f.lookup(name, version)
+	if err != nil {
+		return "", err
+	}
+	return record.environFeature, nil
+}
+
 // FacadeDescription describes the name and what versions of a facade have been
 // registered.
 type FacadeDescription struct {
@@ -229,10 +300,10 @@ type FacadeDescription struct {
 
 // descriptionFromVersions aggregates the information in a versions map into a
 // more friendly form for List().
-func descriptionFromVersions(name string, vers versions) FacadeDescription {
+func descriptionFromVersions(name string, vers versions, environFeatures []string) FacadeDescription {
 	intVersions := make([]int, 0, len(vers))
 	for version, record := range vers {
-		if featureflag.Enabled(record.feature) {
+		if featureflag.Enabled(record.feature) && record.environFeatureEnabled(environFeatures) {
 			intVersions = append(intVersions, version)
 		}
 	}
@@ -244,7 +315,16 @@ func descriptionFromVersions(name string, vers versions) FacadeDescription {
 }
 
 // List returns a slice describing each of the registered Facades.
+// Facades that are only available in environments with a particular
+// feature enabled are omitted; see ListForEnviron.
 func (f *FacadeRegistry) List() []FacadeDescription {
+	return f.ListForEnviron(nil)
+}
+
+// ListForEnviron returns a slice describing each of the registered
+// Facades that are available in an environment with the given
+// feature flags enabled.
+func (f *FacadeRegistry) ListForEnviron(environFeatures []string) []FacadeDescription {
 	names := make([]string, 0, len(f.facades))
 	for name := range f.facades {
 		names = append(names, name)
@@ -253,7 +333,7 @@ func (f *FacadeRegistry) List() []FacadeDescription {
 	descriptions := make([]FacadeDescription, 0, len(f.facades))
 	for _, name := range names {
 		facades := f.facades[name]
-		description := descriptionFromVersions(name, facades)
+		description := descriptionFromVersions(name, facades, environFeatures)
 		if len(description.Versions) > 0 {
 			descriptions = append(descriptions, description)
 		}
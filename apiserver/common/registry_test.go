@@ -335,6 +335,42 @@ func (*facadeRegistrySuite) TestGetType(c *gc.C) {
 	c.Check(typ, gc.Equals, intPtrType)
 }
 
+func (*facadeRegistrySuite) TestRegisterForEnvironFeature(c *gc.C) {
+	r := &common.FacadeRegistry{}
+	c.Assert(r.Register("name", 0, validIdFactory, intPtrType, ""), gc.IsNil)
+	c.Assert(r.RegisterForEnvironFeature("name", 1, validIdFactory, intPtrType, "magic"), gc.IsNil)
+
+	feature, err := r.GetEnvironFeature("name", 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(feature, gc.Equals, "")
+	feature, err = r.GetEnvironFeature("name", 1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(feature, gc.Equals, "magic")
+	_, err = r.GetEnvironFeature("name", 2)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+
+	// The facade is found regardless of environment features;
+	// the API root checks them when looking up methods.
+	_, err = r.GetFactory("name", 1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(r.List(), jc.DeepEquals, []common.FacadeDescription{
+		{Name: "name", Versions: []int{0}},
+	})
+	c.Check(r.ListForEnviron([]string{"other"}), jc.DeepEquals, []common.FacadeDescription{
+		{Name: "name", Versions: []int{0}},
+	})
+	c.Check(r.ListForEnviron([]string{"other", "magic"}), jc.DeepEquals, []common.FacadeDescription{
+		{Name: "name", Versions: []int{0, 1}},
+	})
+}
+
+func (*facadeRegistrySuite) TestRegisterForEnvironFeatureRequiresFeature(c *gc.C) {
+	r := &common.FacadeRegistry{}
+	err := r.RegisterForEnvironFeature("name", 0, validIdFactory, intPtrType, "")
+	c.Assert(err, gc.ErrorMatches, `environment feature for name\(0\) not specified`)
+}
+
 func (*facadeRegistrySuite) TestDiscardHandlesNotPresent(c *gc.C) {
 	r := &common.FacadeRegistry{}
 	r.Discard("name", 1)
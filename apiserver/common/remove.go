@@ -4,8 +4,6 @@
 package common
 
 import (
-	"fmt"
-
 	"github.com/juju/errors"
 	"github.com/juju/names"
 
@@ -47,7 +45,7 @@ func (r *Remover) removeEntity(tag names.Tag) error {
 	}
 	// Only remove entites that are not Alive.
 	if life := remover.Life(); life == state.Alive {
-		return fmt.Errorf("cannot remove entity %q: still alive", tag.String())
+		return WrongLifecycleErrorf("cannot remove entity %q: still alive", tag.String())
 	}
 	if r.callEnsureDead {
 		if err := remover.EnsureDead(); err != nil {
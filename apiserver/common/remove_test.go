@@ -72,7 +72,10 @@ func (*removeSuite) TestRemove(c *gc.C) {
 		Results: []params.ErrorResult{
 			{&params.Error{Message: "x0 EnsureDead fails"}},
 			{&params.Error{Message: "x1 Remove fails"}},
-			{&params.Error{Message: `cannot remove entity "unit-x-2": still alive`}},
+			{&params.Error{
+				Message: `cannot remove entity "unit-x-2": still alive`,
+				Code:    params.CodeWrongLifecycle,
+			}},
 			{nil},
 			{apiservertesting.ErrUnauthorized},
 			{&params.Error{Message: "x5 error"}},
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+
+	"github.com/juju/juju/state"
 )
 
 // Resource represents any resource that should be cleaned up when an
@@ -130,6 +132,19 @@ func (rs *Resources) Count() int {
 	return len(rs.resources)
 }
 
+// CountWatchers returns the number of state watchers currently held.
+func (rs *Resources) CountWatchers() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	count := 0
+	for _, r := range rs.resources {
+		if _, ok := r.(state.Watcher); ok {
+			count++
+		}
+	}
+	return count
+}
+
 // StringResource is just a regular 'string' that matches the Resource
 // interface.
 type StringResource string
@@ -37,6 +37,16 @@ func (resourceSuite) TestRegisterGetCount(c *gc.C) {
 	c.Assert(rs.Count(), gc.Equals, 2)
 }
 
+func (resourceSuite) TestCountWatchers(c *gc.C) {
+	rs := common.NewResources()
+	rs.Register(&fakeResource{})
+	rs.Register(&fakeNotifyWatcher{})
+	err := rs.RegisterNamed("fake1", &fakeNotifyWatcher{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rs.Count(), gc.Equals, 3)
+	c.Assert(rs.CountWatchers(), gc.Equals, 2)
+}
+
 func (resourceSuite) TestRegisterNamedGetCount(c *gc.C) {
 	rs := common.NewResources()
 	defer rs.StopAll()
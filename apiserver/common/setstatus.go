@@ -45,29 +45,21 @@ func (s *StatusSetter) setEntityStatus(tag names.Tag, status params.Status, info
 
 // SetStatus sets the status of each given entity.
 func (s *StatusSetter) SetStatus(args params.SetStatus) (params.ErrorResults, error) {
-	result := params.ErrorResults{
-		Results: make([]params.ErrorResult, len(args.Entities)),
-	}
 	if len(args.Entities) == 0 {
-		return result, nil
+		return params.ErrorResults{Results: []params.ErrorResult{}}, nil
 	}
 	canModify, err := s.getCanModify()
 	if err != nil {
 		return params.ErrorResults{}, err
 	}
-	for i, arg := range args.Entities {
+	return BulkErrorResults(len(args.Entities), func(i int) error {
+		arg := args.Entities[i]
 		tag, err := names.ParseTag(arg.Tag)
-		if err != nil {
-			result.Results[i].Error = ServerError(ErrPerm)
-			continue
-		}
-		err = ErrPerm
-		if canModify(tag) {
-			err = s.setEntityStatus(tag, arg.Status, arg.Info, arg.Data)
+		if err != nil || !canModify(tag) {
+			return ErrPerm
 		}
-		result.Results[i].Error = ServerError(err)
-	}
-	return result, nil
+		return s.setEntityStatus(tag, arg.Status, arg.Info, arg.Data)
+	}), nil
 }
 
 func (s *StatusSetter) updateEntityStatusData(tag names.Tag, data map[string]interface{}) error {
@@ -103,27 +95,19 @@ func (s *StatusSetter) updateEntityStatusData(tag names.Tag, data map[string]int
 
 // UpdateStatus updates the status data of each given entity.
 func (s *StatusSetter) UpdateStatus(args params.SetStatus) (params.ErrorResults, error) {
-	result := params.ErrorResults{
-		Results: make([]params.ErrorResult, len(args.Entities)),
-	}
 	if len(args.Entities) == 0 {
-		return result, nil
+		return params.ErrorResults{Results: []params.ErrorResult{}}, nil
 	}
 	canModify, err := s.getCanModify()
 	if err != nil {
 		return params.ErrorResults{}, errors.Trace(err)
 	}
-	for i, arg := range args.Entities {
+	return BulkErrorResults(len(args.Entities), func(i int) error {
+		arg := args.Entities[i]
 		tag, err := names.ParseTag(arg.Tag)
-		if err != nil {
-			result.Results[i].Error = ServerError(ErrPerm)
-			continue
-		}
-		err = ErrPerm
-		if canModify(tag) {
-			err = s.updateEntityStatusData(tag, arg.Data)
+		if err != nil || !canModify(tag) {
+			return ErrPerm
 		}
-		result.Results[i].Error = ServerError(err)
-	}
-	return result, nil
+		return s.updateEntityStatusData(tag, arg.Data)
+	}), nil
 }
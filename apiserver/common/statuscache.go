@@ -0,0 +1,19 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+// StatusCache is implemented by the API server to let facades keep
+// expensive environment status results between calls. Results are
+// discarded when the environment changes. It is made available to
+// facades as the "statusCache" named resource.
+type StatusCache interface {
+	Resource
+
+	// Get returns the result cached for the environment under
+	// key, and whether there is one.
+	Get(envUUID, key string) (interface{}, bool)
+
+	// Put caches result for the environment under key.
+	Put(envUUID, key string, result interface{})
+}
@@ -0,0 +1,27 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package common
+
+import (
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// UnitRelationStates returns the bookkeeping the given unit's agent
+// keeps for each of the unit's relations.
+func UnitRelationStates(unit *state.Unit) ([]params.UnitRelationState, error) {
+	states, err := unit.RelationStates()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]params.UnitRelationState, len(states))
+	for i, st := range states {
+		result[i] = params.UnitRelationState{
+			RelationId:     st.RelationId,
+			Members:        st.Members,
+			ChangedPending: st.ChangedPending,
+		}
+	}
+	return result, nil
+}
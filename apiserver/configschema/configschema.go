@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package configschema implements the API used to describe the
+// configuration attributes known to juju, so that tooling can complete
+// and document them.
+package configschema
+
+import (
+	"sort"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("ConfigSchema", 1, NewAPI)
+}
+
+// API implements the ConfigSchema facade.
+type API struct{}
+
+// NewAPI returns a new ConfigSchema API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{}, nil
+}
+
+// Schema returns the configuration attributes known to juju, ordered
+// by name. Attributes defined by the environment's provider are not
+// included.
+func (api *API) Schema() (params.ConfigSchemaResult, error) {
+	schema := config.Schema()
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	attrs := make([]params.ConfigAttr, len(names))
+	for i, name := range names {
+		attr := schema[name]
+		attrs[i] = params.ConfigAttr{
+			Name:        name,
+			Type:        string(attr.Type),
+			Group:       string(attr.Group),
+			Default:     attr.Default,
+			Immutable:   attr.Immutable,
+			Deprecated:  attr.Deprecated,
+			Description: attr.Description,
+		}
+	}
+	return params.ConfigSchemaResult{Attrs: attrs}, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package configschema_test
+
+import (
+	"sort"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/configschema"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type configSchemaSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&configSchemaSuite{})
+
+func (s *configSchemaSuite) TestNewAPIRequiresClient(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := configschema.NewAPI(nil, common.NewResources(), auth)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *configSchemaSuite) TestSchema(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewUserTag("admin")}
+	api, err := configschema.NewAPI(nil, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.Schema()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Attrs, gc.HasLen, len(config.Schema()))
+
+	var names []string
+	byName := make(map[string]params.ConfigAttr)
+	for _, attr := range result.Attrs {
+		names = append(names, attr.Name)
+		byName[attr.Name] = attr
+	}
+	c.Assert(sort.StringsAreSorted(names), jc.IsTrue)
+	c.Assert(byName["api-port"], jc.DeepEquals, params.ConfigAttr{
+		Name:        "api-port",
+		Type:        "int",
+		Group:       "controller",
+		Default:     config.DefaultAPIPort,
+		Immutable:   true,
+		Description: config.Schema()["api-port"].Description,
+	})
+	c.Assert(byName["logging-config"].Group, gc.Equals, "environment")
+}
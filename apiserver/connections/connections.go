@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package connections implements the API used to inspect and
+// terminate the connections being served by an API server.
+package connections
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Connections", 1, NewAPI)
+}
+
+// API implements the Connections facade.
+type API struct {
+	tracker common.ConnectionTracker
+}
+
+// NewAPI returns a new Connections API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	if !st.IsStateServer() {
+		return nil, errors.New("connections can only be listed in the state server environment")
+	}
+	tracker, ok := resources.Get("connections").(common.ConnectionTracker)
+	if !ok {
+		return nil, errors.New("connection tracking not available")
+	}
+	return &API{tracker: tracker}, nil
+}
+
+// List returns details of the connections being served by the API
+// server the client is connected to. In a highly available
+// environment, each API server reports only its own connections.
+func (api *API) List() (params.ConnectionDetailsResults, error) {
+	return params.ConnectionDetailsResults{
+		Connections: api.tracker.Connections(),
+	}, nil
+}
+
+// Disconnect forcibly terminates the connections with the given ids.
+func (api *API) Disconnect(args params.DisconnectArgs) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		err := api.tracker.Disconnect(id)
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
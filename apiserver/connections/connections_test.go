@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package connections_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/connections"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type connectionsSuite struct {
+	jujutesting.JujuConnSuite
+	tracker   *mockTracker
+	resources *common.Resources
+	api       *connections.API
+}
+
+var _ = gc.Suite(&connectionsSuite{})
+
+type mockTracker struct {
+	conns        []params.ConnectionDetails
+	disconnected []int64
+}
+
+func (t *mockTracker) Connections() []params.ConnectionDetails {
+	return t.conns
+}
+
+func (t *mockTracker) Disconnect(id int64) error {
+	for _, conn := range t.conns {
+		if conn.Id == id {
+			t.disconnected = append(t.disconnected, id)
+			return nil
+		}
+	}
+	return errors.NotFoundf("connection %d", id)
+}
+
+func (t *mockTracker) Stop() error {
+	return nil
+}
+
+func (s *connectionsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.tracker = &mockTracker{
+		conns: []params.ConnectionDetails{{
+			Id:           1,
+			EntityTag:    "machine-0",
+			RemoteAddr:   "10.0.0.1:1234",
+			ConnectedAt:  time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+			LastActivity: time.Date(2015, 4, 1, 12, 5, 0, 0, time.UTC),
+			FacadeCalls:  map[string]int{"Machiner": 3},
+		}},
+	}
+	s.resources = common.NewResources()
+	err := s.resources.RegisterNamed("connections", s.tracker)
+	c.Assert(err, jc.ErrorIsNil)
+
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	s.api, err = connections.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *connectionsSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := connections.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *connectionsSuite) TestNewAPIWithoutTracker(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	_, err := connections.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "connection tracking not available")
+}
+
+func (s *connectionsSuite) TestList(c *gc.C) {
+	result, err := s.api.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Connections, jc.DeepEquals, s.tracker.conns)
+}
+
+func (s *connectionsSuite) TestDisconnect(c *gc.C) {
+	results, err := s.api.Disconnect(params.DisconnectArgs{Ids: []int64{1, 42}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Assert(s.tracker.disconnected, jc.DeepEquals, []int64{1})
+}
@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// connectionTracker records the connections currently being served by
+// an API server, so they can be inspected and terminated through the
+// API.
+type connectionTracker struct {
+	mu    sync.Mutex
+	conns map[int64]*trackedConn
+}
+
+type trackedConn struct {
+	notifier *requestNotifier
+	envUUID  string
+	closer   io.Closer
+
+	// resources holds the connection's resources once it
+	// is being served.
+	resources *common.Resources
+}
+
+var _ common.ConnectionTracker = (*connectionTracker)(nil)
+
+func newConnectionTracker() *connectionTracker {
+	return &connectionTracker{
+		conns: make(map[int64]*trackedConn),
+	}
+}
+
+// add records a new connection. Closing the given closer
+// terminates the connection.
+func (t *connectionTracker) add(notifier *requestNotifier, envUUID string, closer io.Closer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conns[notifier.id] = &trackedConn{
+		notifier: notifier,
+		envUUID:  envUUID,
+		closer:   closer,
+	}
+}
+
+// remove forgets about the connection with the given id.
+func (t *connectionTracker) remove(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.conns, id)
+}
+
+// setResources records the resources held by the connection with
+// the given id.
+func (t *connectionTracker) setResources(id int64, resources *common.Resources) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conn, ok := t.conns[id]; ok {
+		conn.resources = resources
+	}
+}
+
+// counts returns the number of connections, and the number of
+// watchers they hold.
+func (t *connectionTracker) counts() (connections, watchers int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, conn := range t.conns {
+		if conn.resources != nil {
+			watchers += conn.resources.CountWatchers()
+		}
+	}
+	return len(t.conns), watchers
+}
+
+// Connections implements common.ConnectionTracker.
+func (t *connectionTracker) Connections() []params.ConnectionDetails {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]params.ConnectionDetails, 0, len(t.conns))
+	for _, conn := range t.conns {
+		details := conn.notifier.details()
+		if conn.envUUID != "" {
+			details.EnvironTag = names.NewEnvironTag(conn.envUUID).String()
+		}
+		result = append(result, details)
+	}
+	sort.Sort(connectionsById(result))
+	return result
+}
+
+// Disconnect implements common.ConnectionTracker.
+func (t *connectionTracker) Disconnect(id int64) error {
+	t.mu.Lock()
+	conn, ok := t.conns[id]
+	t.mu.Unlock()
+	if !ok {
+		return errors.NotFoundf("connection %d", id)
+	}
+	logger.Infof("[%X] %s API connection forcibly closed", id, conn.notifier.tag())
+	// Closing the underlying transport causes the RPC connection
+	// to die, at which point the connection is cleaned up as
+	// usual.
+	return conn.closer.Close()
+}
+
+// Stop implements common.Resource. The tracker outlives the
+// connections that refer to it, so there is nothing to do.
+func (t *connectionTracker) Stop() error {
+	return nil
+}
+
+type connectionsById []params.ConnectionDetails
+
+func (c connectionsById) Len() int           { return len(c) }
+func (c connectionsById) Less(i, j int) bool { return c[i].Id < c[j].Id }
+func (c connectionsById) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
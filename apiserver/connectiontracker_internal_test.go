@@ -0,0 +1,69 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/testing"
+)
+
+type connectionTrackerSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&connectionTrackerSuite{})
+
+type fakeCloser struct {
+	closed bool
+}
+
+func (c *fakeCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (s *connectionTrackerSuite) TestConnections(c *gc.C) {
+	tracker := newConnectionTracker()
+	notifier := newRequestNotifier(nil)
+	notifier.join(&http.Request{RemoteAddr: "10.0.0.1:1234"})
+	notifier.login("machine-0")
+	hdr := &rpc.Header{Request: rpc.Request{Type: "Machiner", Action: "Life"}}
+	notifier.ServerRequest(hdr, nil)
+	notifier.ServerRequest(hdr, nil)
+
+	tracker.add(notifier, testing.EnvironmentTag.Id(), &fakeCloser{})
+	conns := tracker.Connections()
+	c.Assert(conns, gc.HasLen, 1)
+	c.Assert(conns[0].Id, gc.Equals, notifier.id)
+	c.Assert(conns[0].EntityTag, gc.Equals, "machine-0")
+	c.Assert(conns[0].EnvironTag, gc.Equals, testing.EnvironmentTag.String())
+	c.Assert(conns[0].RemoteAddr, gc.Equals, "10.0.0.1:1234")
+	c.Assert(conns[0].FacadeCalls, jc.DeepEquals, map[string]int{"Machiner": 2})
+	c.Assert(conns[0].LastActivity.Before(conns[0].ConnectedAt), jc.IsFalse)
+
+	tracker.remove(notifier.id)
+	c.Assert(tracker.Connections(), gc.HasLen, 0)
+}
+
+func (s *connectionTrackerSuite) TestDisconnect(c *gc.C) {
+	tracker := newConnectionTracker()
+	notifier := newRequestNotifier(nil)
+	closer := &fakeCloser{}
+	tracker.add(notifier, "", closer)
+
+	err := tracker.Disconnect(notifier.id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(closer.closed, jc.IsTrue)
+
+	err = tracker.Disconnect(notifier.id + 1)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,48 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package credentialvalidator provides the API used by state server
+// agents to record whether the provider accepts an environment's
+// credentials.
+package credentialvalidator
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("CredentialValidator", 1, NewCredentialValidatorAPI)
+}
+
+// CredentialValidatorAPI implements the CredentialValidator facade.
+type CredentialValidatorAPI struct {
+	*common.EnvironWatcher
+	st *state.State
+}
+
+// NewCredentialValidatorAPI creates a new server-side
+// CredentialValidator facade.
+func NewCredentialValidatorAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*CredentialValidatorAPI, error) {
+	if !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &CredentialValidatorAPI{
+		EnvironWatcher: common.NewEnvironWatcher(st, resources, authorizer),
+		st:             st,
+	}, nil
+}
+
+// SetCredentialValidity records whether the provider accepts the
+// environment's credentials. The environment is suspended while they
+// are rejected.
+func (api *CredentialValidatorAPI) SetCredentialValidity(args params.CredentialValidity) error {
+	env, err := api.st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return env.SetCredentialValidity(args.Valid, args.Reason)
+}
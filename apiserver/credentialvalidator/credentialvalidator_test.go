@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/credentialvalidator"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type credentialValidatorSuite struct {
+	jujutesting.JujuConnSuite
+
+	api       *credentialvalidator.CredentialValidatorAPI
+	resources *common.Resources
+}
+
+var _ = gc.Suite(&credentialValidatorSuite{})
+
+func (s *credentialValidatorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	var err error
+	s.api, err = credentialvalidator.NewCredentialValidatorAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *credentialValidatorSuite) TestNewAPIRefusesNonManager(c *gc.C) {
+	_, err := credentialvalidator.NewCredentialValidatorAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = credentialvalidator.NewCredentialValidatorAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *credentialValidatorSuite) TestEnvironConfigIncludesSecrets(c *gc.C) {
+	result, err := s.api.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Config["secret"], gc.Equals, "pork")
+}
+
+func (s *credentialValidatorSuite) TestSetCredentialValidity(c *gc.C) {
+	err := s.api.SetCredentialValidity(params.CredentialValidity{
+		Reason: "secret rejected",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "secret rejected")
+
+	err = s.api.SetCredentialValidity(params.CredentialValidity{Valid: true})
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, _ = env.Suspended()
+	c.Assert(suspended, jc.IsFalse)
+}
@@ -0,0 +1,369 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// debugHooksBroker pairs clients starting debug-hooks sessions with
+// the agents of the units they want to debug. Unit agents wait for
+// sessions by connecting to the API server, so sessions can be
+// brokered for units whose machines the client cannot reach.
+//
+// Agents are only known to the API server they are connected to, so
+// a client must connect to the same API server as the agent of the
+// unit it wants to debug.
+type debugHooksBroker struct {
+	mu     sync.Mutex
+	agents map[string]*debugHooksAgent
+}
+
+func newDebugHooksBroker() *debugHooksBroker {
+	return &debugHooksBroker{
+		agents: make(map[string]*debugHooksAgent),
+	}
+}
+
+// debugHooksAgent represents a unit agent waiting for a debug-hooks
+// session to start.
+type debugHooksAgent struct {
+	// sessions receives the session started with the agent's unit.
+	sessions chan *debugHooksSession
+
+	// replaced is closed when another connection from the
+	// agent replaces this one.
+	replaced chan struct{}
+
+	// gone is closed when the agent stops waiting.
+	gone chan struct{}
+}
+
+// debugHooksSession holds a client's request to debug a unit's hooks.
+type debugHooksSession struct {
+	hooks      []string
+	client     io.ReadWriter
+	transcript io.Writer
+
+	// done is closed when the session ends.
+	done chan struct{}
+}
+
+// listen records that the agent of the unit identified by key is
+// waiting for a session, replacing any agent already waiting for the
+// same unit.
+func (b *debugHooksBroker) listen(key string) *debugHooksAgent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if old := b.agents[key]; old != nil {
+		close(old.replaced)
+	}
+	agent := &debugHooksAgent{
+		sessions: make(chan *debugHooksSession),
+		replaced: make(chan struct{}),
+		gone:     make(chan struct{}),
+	}
+	b.agents[key] = agent
+	return agent
+}
+
+// forget records that the agent has stopped waiting for a session.
+func (b *debugHooksBroker) forget(key string, agent *debugHooksAgent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.agents[key] == agent {
+		delete(b.agents, key)
+	}
+	close(agent.gone)
+}
+
+// claim returns the agent waiting for a session with the unit
+// identified by key, so that no other client can start a session
+// with it.
+func (b *debugHooksBroker) claim(key string) (*debugHooksAgent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	agent, ok := b.agents[key]
+	if ok {
+		delete(b.agents, key)
+	}
+	return agent, ok
+}
+
+// start hands the session to the agent.
+func (agent *debugHooksAgent) start(session *debugHooksSession) error {
+	select {
+	case agent.sessions <- session:
+		return nil
+	case <-agent.gone:
+		return errors.New("unit agent disconnected")
+	}
+}
+
+func debugHooksKey(envUUID string, unitTag names.UnitTag) string {
+	return envUUID + ":" + unitTag.String()
+}
+
+// debugHooksHandler starts debug-hooks sessions on behalf of clients.
+type debugHooksHandler struct {
+	httpHandler
+	broker *debugHooksBroker
+
+	// transcriptDir holds the directory in which session
+	// transcripts are written. If it is empty, transcripts
+	// are not recorded.
+	transcriptDir string
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// The connection is upgraded to a websocket. The request names the unit
+// to debug in its "unit" query parameter, and the hooks to debug in any
+// number of "hook" query parameters; if none are given, all hooks are
+// debugged. Once the user has been authenticated and the session handed
+// to the unit's agent, the first line sent back is a JSON encoded
+// params.ErrorResult reporting whether the session started. After that,
+// data is relayed unchanged between the client and the hook shells the
+// agent runs, until either side closes its connection.
+func (h *debugHooksHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := websocket.Server{
+		Handler: func(socket *websocket.Conn) {
+			defer socket.Close()
+			stateWrapper, err := h.validateEnvironUUID(req)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			defer stateWrapper.cleanup()
+			if err := stateWrapper.authenticate(req); err != nil {
+				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
+				return
+			}
+			unitName := req.URL.Query().Get("unit")
+			if !names.IsValidUnit(unitName) {
+				h.sendError(socket, errors.Errorf("invalid unit name %q", unitName))
+				return
+			}
+			unit, err := stateWrapper.state.Unit(unitName)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			hooks := req.URL.Query()["hook"]
+			user, _, _ := parseBasicAuth(req)
+			transcript, err := h.openTranscript(unit.UnitTag(), user, hooks)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			defer transcript.Close()
+
+			key := debugHooksKey(stateWrapper.state.EnvironUUID(), unit.UnitTag())
+			agent, ok := h.broker.claim(key)
+			if !ok {
+				transcript.discard()
+				h.sendError(socket, errors.Errorf("unit %q is not waiting for debug-hooks sessions on this API server", unitName))
+				return
+			}
+			if err := h.sendError(socket, nil); err != nil {
+				logger.Errorf("could not send good debug-hooks start")
+				return
+			}
+			socket.PayloadType = websocket.BinaryFrame
+			session := &debugHooksSession{
+				hooks:      hooks,
+				client:     socket,
+				transcript: transcript,
+				done:       make(chan struct{}),
+			}
+			if err := agent.start(session); err != nil {
+				logger.Warningf("cannot start debug-hooks session with unit %q: %v", unitName, err)
+				return
+			}
+			<-session.done
+		},
+	}
+	server.ServeHTTP(w, req)
+}
+
+// openTranscript creates the file in which the session with the unit
+// is recorded.
+func (h *debugHooksHandler) openTranscript(unitTag names.UnitTag, user string, hooks []string) (*debugHooksTranscript, error) {
+	if h.transcriptDir == "" {
+		return &debugHooksTranscript{}, nil
+	}
+	if err := os.MkdirAll(h.transcriptDir, 0700); err != nil {
+		return nil, errors.Annotate(err, "cannot create debug-hooks transcript directory")
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.log", unitTag, now.Format("20060102-150405.000"))
+	file, err := os.OpenFile(filepath.Join(h.transcriptDir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create debug-hooks transcript")
+	}
+	hookNames := "all hooks"
+	if len(hooks) > 0 {
+		hookNames = strings.Join(hooks, ", ")
+	}
+	fmt.Fprintf(file, "debug-hooks session with %s started by %s at %s for %s\n",
+		unitTag, user, now.Format(time.RFC3339), hookNames,
+	)
+	return &debugHooksTranscript{file: file}, nil
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *debugHooksHandler) sendError(w io.Writer, err error) error {
+	return sendWebsocketError(w, err)
+}
+
+// debugHooksTranscript records the data relayed in both directions
+// during a debug-hooks session.
+type debugHooksTranscript struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Write implements io.Writer.
+func (t *debugHooksTranscript) Write(data []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return len(data), nil
+	}
+	if _, err := t.file.Write(data); err != nil {
+		logger.Warningf("cannot write debug-hooks transcript: %v", err)
+		t.file.Close()
+		t.file = nil
+	}
+	return len(data), nil
+}
+
+// discard removes the transcript of a session that never started.
+func (t *debugHooksTranscript) discard() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return
+	}
+	t.file.Close()
+	os.Remove(t.file.Name())
+	t.file = nil
+}
+
+// Close closes the transcript.
+func (t *debugHooksTranscript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// debugHooksAgentHandler holds the connections of unit agents waiting
+// for debug-hooks sessions.
+type debugHooksAgentHandler struct {
+	httpHandler
+	broker *debugHooksBroker
+	stop   <-chan struct{}
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// The connection is upgraded to a websocket. Once the unit agent has
+// been authenticated, the first line sent back is a JSON encoded
+// params.ErrorResult reporting whether the connection was accepted.
+// When a client starts a session with the unit, a JSON encoded
+// params.DebugHooksSession is sent, and data is then relayed unchanged
+// between the agent and the client until either side closes its
+// connection. An agent handles at most one session per connection.
+func (h *debugHooksAgentHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := websocket.Server{
+		Handler: func(socket *websocket.Conn) {
+			defer socket.Close()
+			stateWrapper, err := h.validateEnvironUUID(req)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			defer stateWrapper.cleanup()
+			entity, err := stateWrapper.authenticateAgent(req)
+			if err != nil {
+				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
+				return
+			}
+			unitTag, ok := entity.Tag().(names.UnitTag)
+			if !ok {
+				h.sendError(socket, common.ErrPerm)
+				return
+			}
+			key := debugHooksKey(stateWrapper.state.EnvironUUID(), unitTag)
+			agent := h.broker.listen(key)
+			defer h.broker.forget(key, agent)
+			if err := h.sendError(socket, nil); err != nil {
+				logger.Errorf("could not send good debug-hooks agent start")
+				return
+			}
+
+			// The agent sends nothing until a session has started,
+			// so reading from it also tells us when it goes away.
+			fromAgent, pipeWriter := io.Pipe()
+			agentGone := make(chan struct{})
+			go func() {
+				defer close(agentGone)
+				_, err := io.Copy(pipeWriter, socket)
+				pipeWriter.CloseWithError(err)
+			}()
+
+			var session *debugHooksSession
+			select {
+			case session = <-agent.sessions:
+			case <-agent.replaced:
+				return
+			case <-agentGone:
+				return
+			case <-h.stop:
+				return
+			}
+			defer close(session.done)
+			if err := websocket.JSON.Send(socket, params.DebugHooksSession{Hooks: session.hooks}); err != nil {
+				logger.Errorf("could not send debug-hooks session to %s: %v", unitTag, err)
+				return
+			}
+			socket.PayloadType = websocket.BinaryFrame
+			relay(
+				readWriter{io.TeeReader(session.client, session.transcript), session.client},
+				readWriter{io.TeeReader(fromAgent, session.transcript), socket},
+			)
+		},
+	}
+	server.ServeHTTP(w, req)
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *debugHooksAgentHandler) sendError(w io.Writer, err error) error {
+	return sendWebsocketError(w, err)
+}
+
+// readWriter combines separate halves of a connection.
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"code.google.com/p/go.net/websocket"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type debugHooksSuite struct {
+	authHttpSuite
+	unit         *state.Unit
+	unitPassword string
+}
+
+var _ = gc.Suite(&debugHooksSuite{})
+
+func (s *debugHooksSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, nil)
+	password, err := utils.RandomPassword()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+	s.unitPassword = password
+}
+
+func (s *debugHooksSuite) TestClientNoAuth(c *gc.C) {
+	reader := s.openClient(c, nil, s.unit.Name())
+	s.assertErrorResponse(c, reader, "auth failed: invalid request format")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestClientRejectsAgents(c *gc.C) {
+	reader := s.openClient(c, s.agentHeader(), s.unit.Name())
+	s.assertErrorResponse(c, reader, "auth failed: invalid entity name or password")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestClientInvalidUnit(c *gc.C) {
+	reader := s.openClient(c, s.userHeader(), "mysql")
+	s.assertErrorResponse(c, reader, `invalid unit name "mysql"`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestClientUnitNotFound(c *gc.C) {
+	reader := s.openClient(c, s.userHeader(), "mysql/99")
+	s.assertErrorResponse(c, reader, `unit "mysql/99" not found`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestClientAgentNotWaiting(c *gc.C) {
+	reader := s.openClient(c, s.userHeader(), s.unit.Name())
+	s.assertErrorResponse(c, reader, `unit ".*" is not waiting for debug-hooks sessions on this API server`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestAgentRejectsUsers(c *gc.C) {
+	conn := s.dialWebsocket(c, "/debughooks/agent", s.userHeader(), nil)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	s.assertErrorResponse(c, reader, "auth failed: invalid entity name or password")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugHooksSuite) TestSession(c *gc.C) {
+	agent := s.dialWebsocket(c, "/debughooks/agent", s.agentHeader(), nil)
+	defer agent.Close()
+	s.assertErrorResponse(c, bufio.NewReader(agent), "")
+
+	client := s.dialWebsocket(c, "/debughooks", s.userHeader(), url.Values{
+		"unit": {s.unit.Name()},
+		"hook": {"install", "start"},
+	})
+	defer client.Close()
+	s.assertErrorResponse(c, bufio.NewReader(client), "")
+
+	var session params.DebugHooksSession
+	err := websocket.JSON.Receive(agent, &session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(session.Hooks, jc.DeepEquals, []string{"install", "start"})
+
+	agent.PayloadType = websocket.BinaryFrame
+	s.assertRelayed(c, agent, client, "mysql/0:install % ")
+	s.assertRelayed(c, client, agent, "./hooks/install\n")
+
+	// When the client goes away, so does the agent's connection.
+	client.Close()
+	agent.SetReadDeadline(time.Now().Add(testing.LongWait))
+	_, err = agent.Read(make([]byte, 1))
+	c.Assert(err, gc.Equals, io.EOF)
+}
+
+func (s *debugHooksSuite) assertRelayed(c *gc.C, from, to io.ReadWriter, message string) {
+	_, err := from.Write([]byte(message))
+	c.Assert(err, jc.ErrorIsNil)
+	buf := make([]byte, len(message))
+	_, err = io.ReadFull(to, buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(buf), gc.Equals, message)
+}
+
+func (s *debugHooksSuite) userHeader() http.Header {
+	return utils.BasicAuthHeader(s.userTag.String(), s.password)
+}
+
+func (s *debugHooksSuite) agentHeader() http.Header {
+	return utils.BasicAuthHeader(s.unit.Tag().String(), s.unitPassword)
+}
+
+func (s *debugHooksSuite) openClient(c *gc.C, header http.Header, unit string) *bufio.Reader {
+	conn := s.dialWebsocket(c, "/debughooks", header, url.Values{"unit": {unit}})
+	s.AddCleanup(func(_ *gc.C) { conn.Close() })
+	return bufio.NewReader(conn)
+}
+
+func (s *debugHooksSuite) dialWebsocket(c *gc.C, path string, header http.Header, query url.Values) *websocket.Conn {
+	server := s.baseURL(c)
+	server.Scheme = "wss"
+	server.Path = "/environment/" + s.envUUID + path
+	server.RawQuery = query.Encode()
+	c.Logf("dialing %v", server)
+	config, err := websocket.NewConfig(server.String(), "http://localhost/")
+	c.Assert(err, jc.ErrorIsNil)
+	config.Header = header
+	caCerts := x509.NewCertPool()
+	c.Assert(caCerts.AppendCertsFromPEM([]byte(testing.CACert)), jc.IsTrue)
+	config.TlsConfig = &tls.Config{RootCAs: caCerts, ServerName: "anything"}
+	conn, err := websocket.DialConfig(config)
+	c.Assert(err, jc.ErrorIsNil)
+	conn.PayloadType = websocket.BinaryFrame
+	return conn
+}
+
+func (s *debugHooksSuite) assertErrorResponse(c *gc.C, reader *bufio.Reader, expected string) {
+	line, err := reader.ReadSlice('\n')
+	c.Assert(err, jc.ErrorIsNil)
+	var errResult params.ErrorResult
+	err = json.Unmarshal(line, &errResult)
+	c.Assert(err, jc.ErrorIsNil)
+	if expected == "" {
+		c.Assert(errResult.Error, gc.IsNil)
+	} else {
+		c.Assert(errResult.Error, gc.NotNil)
+		c.Assert(errResult.Error.Message, gc.Matches, expected)
+	}
+}
+
+func (s *debugHooksSuite) assertWebsocketClosed(c *gc.C, reader *bufio.Reader) {
+	_, err := reader.ReadByte()
+	c.Assert(err, gc.Equals, io.EOF)
+}
@@ -9,45 +9,66 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
-	"github.com/juju/utils/tailer"
+	"github.com/juju/ratelimit"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
 )
 
 // debugLogHandler takes requests to watch the debug log.
 type debugLogHandler struct {
 	httpHandler
-	logDir string
 }
 
 var maxLinesReached = fmt.Errorf("max lines reached")
 
+// maxDebugLogRate is the maximum number of lines per second sent to
+// a single debug-log client, so that tailing the logs of a large
+// environment doesn't overwhelm the API server or the client.
+var maxDebugLogRate uint = 1000
+
 // ServeHTTP will serve up connections as a websocket.
 // Args for the HTTP request are as follows:
 //   includeEntity -> []string - lists entity tags to include in the response
 //      - tags may finish with a '*' to match a prefix e.g.: unit-mysql-*, machine-2
+//      - machine and unit names may be used in place of tags, including
+//        with wildcards e.g.: mysql/*, 0/lxc/*
 //      - if none are set, then all lines are considered included
 //   includeModule -> []string - lists logging modules to include in the response
 //      - if none are set, then all lines are considered included
 //   excludeEntity -> []string - lists entity tags to exclude from the response
 //      - as with include, it may finish with a '*'
 //   excludeModule -> []string - lists logging modules to exclude from the response
+//   includeModuleRegexp -> []string - regular expressions, only show lines
+//      whose logging module matches one of them
+//   excludeModuleRegexp -> []string - regular expressions, do not show lines
+//      whose logging module matches any of them
+//   includeMessage -> []string - regular expressions, only show lines whose
+//      message matches one of them
+//   excludeMessage -> []string - regular expressions, do not show lines
+//      whose message matches any of them
 //   limit -> uint - show *at most* this many lines
 //   backlog -> uint
 //      - go back this many lines from the end before starting to filter
-//      - has no meaning if 'replay' is true
+//      - has no meaning if 'replay' is true or 'startTime' is set
+//   backlogPerEntity -> string - one of [true, false], if true, the backlog
+//      applies to each entity separately
 //   level -> string one of [TRACE, DEBUG, INFO, WARNING, ERROR]
 //   replay -> string - one of [true, false], if true, start the file from the start
+//   startTime -> string - RFC3339 time, replay lines logged at or after this time
+//   endTime -> string - RFC3339 time, only show lines logged at or before this time
+//      - when set, the connection is closed once the matching lines have been sent
+//   maxRate -> uint - send at most this many lines per second
+//      - the server never sends more than maxDebugLogRate lines per second
 func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	server := websocket.Server{
 		Handler: func(socket *websocket.Conn) {
@@ -62,8 +83,6 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				return
 			}
 			defer stateWrapper.cleanup()
-			// TODO (thumper): We need to work out how we are going to filter
-			// logging information based on environment.
 			if err := stateWrapper.authenticate(req); err != nil {
 				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
 				socket.Close()
@@ -75,20 +94,6 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				socket.Close()
 				return
 			}
-			// Open log file.
-			logLocation := filepath.Join(h.logDir, "all-machines.log")
-			logFile, err := os.Open(logLocation)
-			if err != nil {
-				h.sendError(socket, fmt.Errorf("cannot open log file: %v", err))
-				socket.Close()
-				return
-			}
-			defer logFile.Close()
-			if err := stream.positionLogFile(logFile); err != nil {
-				h.sendError(socket, fmt.Errorf("cannot position log file: %v", err))
-				socket.Close()
-				return
-			}
 
 			// If we get to here, no more errors to report, so we report a nil
 			// error.  This way the first line of the socket is always a json
@@ -99,7 +104,7 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				return
 			}
 
-			stream.start(logFile, socket)
+			stream.start(stateWrapper.state, socket)
 			go func() {
 				defer stream.tomb.Done()
 				defer socket.Close()
@@ -142,6 +147,26 @@ func newLogStream(queryMap url.Values) (*logStream, error) {
 		backlog = uint(num)
 	}
 
+	backlogPerEntity := false
+	if value := queryMap.Get("backlogPerEntity"); value != "" {
+		perEntity, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("backlogPerEntity value %q is not a valid boolean", value)
+		}
+		backlogPerEntity = perEntity
+	}
+
+	rate := maxDebugLogRate
+	if value := queryMap.Get("maxRate"); value != "" {
+		num, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || num == 0 {
+			return nil, fmt.Errorf("maxRate value %q is not a valid positive number", value)
+		}
+		if uint(num) < rate {
+			rate = uint(num)
+		}
+	}
+
 	level := loggo.UNSPECIFIED
 	if value := queryMap.Get("level"); value != "" {
 		var ok bool
@@ -152,221 +177,189 @@ func newLogStream(queryMap url.Values) (*logStream, error) {
 		}
 	}
 
-	return &logStream{
-		includeEntity: queryMap["includeEntity"],
-		includeModule: queryMap["includeModule"],
-		excludeEntity: queryMap["excludeEntity"],
-		excludeModule: queryMap["excludeModule"],
-		maxLines:      maxLines,
-		fromTheStart:  fromTheStart,
-		backlog:       backlog,
-		filterLevel:   level,
-	}, nil
-}
-
-// sendError sends a JSON-encoded error response.
-func (h *debugLogHandler) sendError(w io.Writer, err error) error {
-	response := &params.ErrorResult{}
-	if err != nil {
-		response.Error = &params.Error{Message: fmt.Sprint(err)}
-	}
-	message, err := json.Marshal(response)
-	if err != nil {
-		// If we are having trouble marshalling the error, we are in big trouble.
-		logger.Errorf("failure to marshal SimpleError: %v", err)
-		return err
-	}
-	message = append(message, []byte("\n")...)
-	_, err = w.Write(message)
-	return err
-}
-
-type logLine struct {
-	line      string
-	agentTag  string
-	agentName string
-	level     loggo.Level
-	module    string
-}
-
-func parseLogLine(line string) *logLine {
-	const (
-		agentTagIndex = 0
-		levelIndex    = 3
-		moduleIndex   = 4
-	)
-	fields := strings.Fields(line)
-	result := &logLine{
-		line: line,
-	}
-	if len(fields) > agentTagIndex {
-		agentTag := fields[agentTagIndex]
-		// Drop mandatory trailing colon (:).
-		// Since colon is mandatory, agentTag without it is invalid and will be empty ("").
-		if strings.HasSuffix(agentTag, ":") {
-			result.agentTag = agentTag[:len(agentTag)-1]
-		}
-		/*
-		 Drop unit suffix.
-		 In logs, unit information may be prefixed with either a unit_tag by itself or a unit_tag[nnnn].
-		 The code below caters for both scenarios.
-		*/
-		if bracketIndex := strings.Index(agentTag, "["); bracketIndex != -1 {
-			result.agentTag = agentTag[:bracketIndex]
-		}
-		// If, at this stage, result.agentTag is empty,  we could not deduce the tag. No point getting the name...
-		if result.agentTag != "" {
-			// Entity Name deduced from entity tag
-			entityTag, err := names.ParseTag(result.agentTag)
-			if err != nil {
-				/*
-				 Logging error but effectively swallowing it as there is no where to propogate.
-				 We don't expect ParseTag to fail since the tag was generated by juju in the first place.
-				*/
-				logger.Errorf("Could not deduce name from tag %q: %v\n", result.agentTag, err)
-			}
-			result.agentName = entityTag.Id()
+	var startTime, endTime time.Time
+	if value := queryMap.Get("startTime"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("startTime value %q is not a valid RFC3339 time", value)
 		}
+		startTime = t
 	}
-	if len(fields) > moduleIndex {
-		if level, valid := loggo.ParseLevel(fields[levelIndex]); valid {
-			result.level = level
-			result.module = fields[moduleIndex]
+	if value := queryMap.Get("endTime"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("endTime value %q is not a valid RFC3339 time", value)
 		}
+		endTime = t
 	}
-
-	return result
-}
-
-// logStream runs the tailer to read a log file and stream
-// it via a web socket.
-type logStream struct {
-	tomb          tomb.Tomb
-	logTailer     *tailer.Tailer
-	filterLevel   loggo.Level
-	includeEntity []string
-	includeModule []string
-	excludeEntity []string
-	excludeModule []string
-	backlog       uint
-	maxLines      uint
-	lineCount     uint
-	fromTheStart  bool
-}
-
-// positionLogFile will update the internal read position of the logFile to be
-// at the end of the file or somewhere in the middle if backlog has been specified.
-func (stream *logStream) positionLogFile(logFile io.ReadSeeker) error {
-	// Seek to the end, or lines back from the end if we need to.
-	if !stream.fromTheStart {
-		return tailer.SeekLastLines(logFile, stream.backlog, stream.filterLine)
+	if !startTime.IsZero() && !endTime.IsZero() && endTime.Before(startTime) {
+		return nil, fmt.Errorf("endTime %q is before startTime %q",
+			queryMap.Get("endTime"), queryMap.Get("startTime"))
 	}
-	return nil
-}
 
-// start the tailer listening to the logFile, and sending the matching
-// lines to the writer.
-func (stream *logStream) start(logFile io.ReadSeeker, writer io.Writer) {
-	stream.logTailer = tailer.NewTailer(logFile, writer, stream.countedFilterLine)
-}
-
-// loop starts the tailer with the log file and the web socket.
-func (stream *logStream) loop() error {
-	select {
-	case <-stream.logTailer.Dead():
-		return stream.logTailer.Err()
-	case <-stream.tomb.Dying():
-		stream.logTailer.Stop()
+	for _, key := range []string{"includeModuleRegexp", "excludeModuleRegexp", "includeMessage", "excludeMessage"} {
+		for _, value := range queryMap[key] {
+			if _, err := regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("%s value %q is not a valid regular expression", key, value)
+			}
+		}
 	}
-	return nil
-}
 
-// filterLine checks the received line for one of the configured tags.
-func (stream *logStream) filterLine(line []byte) bool {
-	log := parseLogLine(string(line))
-	return stream.checkIncludeEntity(log) &&
-		stream.checkIncludeModule(log) &&
-		!stream.exclude(log) &&
-		stream.checkLevel(log)
+	return &logStream{
+		maxLines: maxLines,
+		rate:     rate,
+		params: &state.LogTailerParams{
+			StartTime:             startTime,
+			EndTime:               endTime,
+			MinLevel:              level,
+			InitialLines:          int(backlog),
+			InitialLinesPerEntity: backlogPerEntity,
+			Replay:                fromTheStart,
+			IncludeEntity:         entityFilters(queryMap["includeEntity"]),
+			ExcludeEntity:         entityFilters(queryMap["excludeEntity"]),
+			IncludeModule:         queryMap["includeModule"],
+			ExcludeModule:         queryMap["excludeModule"],
+			IncludeModuleRegexp:   queryMap["includeModuleRegexp"],
+			ExcludeModuleRegexp:   queryMap["excludeModuleRegexp"],
+			IncludeMessage:        queryMap["includeMessage"],
+			ExcludeMessage:        queryMap["excludeMessage"],
+		},
+	}, nil
 }
 
-// countedFilterLine checks the received line for one of the configured tags,
-// and also checks to make sure the stream doesn't send more than the
-// specified number of lines.
-func (stream *logStream) countedFilterLine(line []byte) bool {
-	result := stream.filterLine(line)
-	if result && stream.maxLines > 0 {
-		stream.lineCount++
-		result = stream.lineCount <= stream.maxLines
-		if stream.lineCount == stream.maxLines {
-			stream.tomb.Kill(maxLinesReached)
+// entityFilters converts any machine or unit names in the given
+// entity filters to the corresponding tags, which is how entities
+// are recorded in the logs database. Names containing wildcards, such
+// as "mysql/*" or "0/lxc/*", are converted to the equivalent tag
+// patterns.
+func entityFilters(filters []string) []string {
+	if len(filters) == 0 {
+		return nil
+	}
+	result := make([]string, len(filters))
+	for i, filter := range filters {
+		switch {
+		case names.IsValidMachine(filter):
+			result[i] = names.NewMachineTag(filter).String()
+		case names.IsValidUnit(filter):
+			result[i] = names.NewUnitTag(filter).String()
+		case strings.Contains(filter, "*"):
+			result[i] = wildcardTagPattern(filter)
+		default:
+			result[i] = filter
 		}
 	}
 	return result
 }
 
-func (stream *logStream) checkIncludeEntity(line *logLine) bool {
-	if len(stream.includeEntity) == 0 {
-		return true
+// wildcardTagPattern converts an entity filter containing wildcards
+// from the name form to the tag form. Filters already in the tag form,
+// and filters starting with a wildcard, only need their separators
+// converted, if at all.
+func wildcardTagPattern(filter string) string {
+	if strings.HasPrefix(filter, names.MachineTagKind+"-") ||
+		strings.HasPrefix(filter, names.UnitTagKind+"-") {
+		return filter
 	}
-	for _, value := range stream.includeEntity {
-		if agentMatchesFilter(line, value) {
-			return true
-		}
+	pattern := strings.Replace(filter, "/", "-", -1)
+	switch first := filter[0]; {
+	case first == '*':
+		return pattern
+	case first >= '0' && first <= '9':
+		return names.MachineTagKind + "-" + pattern
+	default:
+		return names.UnitTagKind + "-" + pattern
 	}
-	return false
 }
 
-// agentMatchesFilter checks if agentTag tag or agentTag name match given filter
-func agentMatchesFilter(line *logLine, aFilter string) bool {
-	return hasMatch(line.agentName, aFilter) || hasMatch(line.agentTag, aFilter)
+// sendError sends a JSON-encoded error response.
+func (h *debugLogHandler) sendError(w io.Writer, err error) error {
+	return sendWebsocketError(w, err)
 }
 
-// hasMatch determines if value contains filter using regular expressions.
-// All wildcard occurrences are changed to `.*`
-// Currently, all match exceptions are logged and not propagated.
-func hasMatch(value, aFilter string) bool {
-	/* Special handling: out of 12 regexp metacharacters \^$.|?+()[*{
-	   only asterix (*) can be legally used as a wildcard in this context.
-	   Both machine and unit tag and name specifications do not allow any other metas.
-	   Consequently, if aFilter contains wildcard (*), do not escape it -
-	   transform it into a regexp "any character(s)" sequence.
-	*/
-	aFilter = strings.Replace(aFilter, "*", `.*`, -1)
-	matches, err := regexp.MatchString("^"+aFilter+"$", value)
+// sendWebsocketError sends a JSON-encoded error response as the first
+// line of a websocket stream. A nil error reports that the stream was
+// accepted.
+func sendWebsocketError(w io.Writer, err error) error {
+	response := &params.ErrorResult{}
+	if err != nil {
+		response.Error = &params.Error{Message: fmt.Sprint(err)}
+	}
+	message, err := json.Marshal(response)
 	if err != nil {
-		// logging errors here... but really should they be swallowed?
-		logger.Errorf("\nCould not match filter %q and regular expression %q\n.%v\n", value, aFilter, err)
+		// If we are having trouble marshalling the error, we are in big trouble.
+		logger.Errorf("failure to marshal SimpleError: %v", err)
+		return err
 	}
-	return matches
+	message = append(message, []byte("\n")...)
+	_, err = w.Write(message)
+	return err
 }
 
-func (stream *logStream) checkIncludeModule(line *logLine) bool {
-	if len(stream.includeModule) == 0 {
-		return true
-	}
-	for _, value := range stream.includeModule {
-		if strings.HasPrefix(line.module, value) {
-			return true
-		}
-	}
-	return false
+// logStream runs a tailer over the logs database and streams the
+// matching records via a web socket.
+type logStream struct {
+	tomb      tomb.Tomb
+	logTailer state.LogTailer
+	writer    io.Writer
+	params    *state.LogTailerParams
+	maxLines  uint
+	lineCount uint
+	rate      uint
+	bucket    *ratelimit.Bucket
 }
 
-func (stream *logStream) exclude(line *logLine) bool {
-	for _, value := range stream.excludeEntity {
-		if agentMatchesFilter(line, value) {
-			return true
-		}
+// start the tailer listening to the logs database, and sending the
+// matching records to the writer.
+func (stream *logStream) start(st *state.State, writer io.Writer) {
+	stream.writer = writer
+	if stream.rate > 0 {
+		stream.bucket = ratelimit.NewBucketWithRate(float64(stream.rate), int64(stream.rate))
 	}
-	for _, value := range stream.excludeModule {
-		if strings.HasPrefix(line.module, value) {
-			return true
+	stream.logTailer = state.NewLogTailer(st, stream.params)
+}
+
+// loop sends the records returned by the tailer down the writer
+// until the tailer finishes, the maximum number of lines has been
+// sent, or the stream is stopped. Lines are sent no faster than the
+// stream's rate allows.
+func (stream *logStream) loop() error {
+	defer stream.logTailer.Stop()
+	for {
+		select {
+		case rec, ok := <-stream.logTailer.Logs():
+			if !ok {
+				return stream.logTailer.Err()
+			}
+			if stream.bucket != nil {
+				select {
+				case <-time.After(stream.bucket.Take(1)):
+				case <-stream.tomb.Dying():
+					return nil
+				}
+			}
+			if _, err := io.WriteString(stream.writer, formatLogRecord(rec)); err != nil {
+				return err
+			}
+			stream.lineCount++
+			if stream.maxLines > 0 && stream.lineCount >= stream.maxLines {
+				return maxLinesReached
+			}
+		case <-stream.tomb.Dying():
+			return nil
 		}
 	}
-	return false
 }
 
-func (stream *logStream) checkLevel(line *logLine) bool {
-	return line.level >= stream.filterLevel
+// formatLogRecord returns the given record in the format used by
+// the consolidated log file, so that debug-log output is unchanged.
+func formatLogRecord(rec *state.LogRecord) string {
+	return fmt.Sprintf("%s: %s %s %s %s %s\n",
+		rec.Entity,
+		rec.Time.UTC().Format("2006-01-02 15:04:05"),
+		rec.Level,
+		rec.Module,
+		rec.Location,
+		rec.Message,
+	)
 }
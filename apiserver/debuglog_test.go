@@ -12,27 +12,32 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"code.google.com/p/go.net/websocket"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
 )
 
 type debugLogSuite struct {
 	authHttpSuite
-	logFile *os.File
-	last    int
+	last int
 }
 
 var _ = gc.Suite(&debugLogSuite{})
 
+func (s *debugLogSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	s.last = 0
+}
+
 func (s *debugLogSuite) TestWithHTTP(c *gc.C) {
 	uri := s.logURL(c, "http", nil).String()
 	_, err := s.sendRequest(c, "", "", "GET", uri, "", nil)
@@ -56,9 +61,15 @@ func (s *debugLogSuite) TestNoAuth(c *gc.C) {
 	s.assertWebsocketClosed(c, reader)
 }
 
-func (s *debugLogSuite) TestNoLogfile(c *gc.C) {
-	reader := s.openWebsocket(c, nil)
-	s.assertErrorResponse(c, reader, "cannot open log file: .*: "+utils.NoSuchFileErrRegexp)
+func (s *debugLogSuite) TestAgentRejected(c *gc.C) {
+	machine, password := s.Factory.MakeMachineReturningPassword(c, nil)
+	header := utils.BasicAuthHeader(machine.Tag().String(), password)
+	conn, err := s.dialWebsocketInternal(c, nil, header)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	s.assertErrorResponse(c, reader, "auth failed: invalid entity name or password")
 	s.assertWebsocketClosed(c, reader)
 }
 
@@ -70,22 +81,20 @@ func (s *debugLogSuite) TestBadParams(c *gc.C) {
 
 func (s *debugLogSuite) assertLogReader(c *gc.C, reader *bufio.Reader) {
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
+	s.writeLogRecords(c, logRecordCount)
 
-	linesRead := s.readLogLines(c, reader, logLineCount)
-	c.Assert(linesRead, jc.DeepEquals, logLines)
+	linesRead := s.readLogLines(c, reader, logRecordCount)
+	c.Assert(linesRead, jc.DeepEquals, logLines(0, logRecordCount))
 }
 
 func (s *debugLogSuite) TestServesLog(c *gc.C) {
-	s.ensureLogFile(c)
 	reader := s.openWebsocket(c, nil)
 	s.assertLogReader(c, reader)
 }
 
 func (s *debugLogSuite) TestReadFromTopLevelPath(c *gc.C) {
-	// Backwards compatibility check, that we can read the log file at
+	// Backwards compatibility check, that we can read the log at
 	// https://host:port/log
-	s.ensureLogFile(c)
 	reader := s.openWebsocketCustomPath(c, "/log")
 	s.assertLogReader(c, reader)
 }
@@ -94,223 +103,202 @@ func (s *debugLogSuite) TestReadFromEnvUUIDPath(c *gc.C) {
 	// Check that we can read the log at https://host:port/ENVUUID/log
 	environ, err := s.State.Environment()
 	c.Assert(err, jc.ErrorIsNil)
-	s.ensureLogFile(c)
 	reader := s.openWebsocketCustomPath(c, fmt.Sprintf("/environment/%s/log", environ.UUID()))
 	s.assertLogReader(c, reader)
 }
 
 func (s *debugLogSuite) TestReadRejectsWrongEnvUUIDPath(c *gc.C) {
-	// Check that we cannot upload charms to https://host:port/BADENVUUID/charms
-	s.ensureLogFile(c)
+	// Check that we cannot read the log at https://host:port/BADENVUUID/log
 	reader := s.openWebsocketCustomPath(c, "/environment/dead-beef-123456/log")
 	s.assertErrorResponse(c, reader, `unknown environment: "dead-beef-123456"`)
 	s.assertWebsocketClosed(c, reader)
 }
 
 func (s *debugLogSuite) TestReadsFromEnd(c *gc.C) {
-	s.writeLogLines(c, 10)
+	s.writeLogRecords(c, 3)
 
 	reader := s.openWebsocket(c, nil)
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
+	s.writeLogRecords(c, logRecordCount)
 
-	linesRead := s.readLogLines(c, reader, logLineCount-10)
-	c.Assert(linesRead, jc.DeepEquals, logLines[10:])
+	linesRead := s.readLogLines(c, reader, logRecordCount-3)
+	c.Assert(linesRead, jc.DeepEquals, logLines(3, logRecordCount))
 }
 
 func (s *debugLogSuite) TestReplayFromStart(c *gc.C) {
-	s.writeLogLines(c, 10)
+	s.writeLogRecords(c, 3)
 
 	reader := s.openWebsocket(c, url.Values{"replay": {"true"}})
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
+	s.writeLogRecords(c, logRecordCount)
 
-	linesRead := s.readLogLines(c, reader, logLineCount)
-	c.Assert(linesRead, jc.DeepEquals, logLines)
+	linesRead := s.readLogLines(c, reader, logRecordCount)
+	c.Assert(linesRead, jc.DeepEquals, logLines(0, logRecordCount))
 }
 
 func (s *debugLogSuite) TestBacklog(c *gc.C) {
-	s.writeLogLines(c, 10)
+	s.writeLogRecords(c, 5)
+
+	reader := s.openWebsocket(c, url.Values{"backlog": {"2"}})
+	s.assertLogFollowing(c, reader)
+	s.writeLogRecords(c, logRecordCount)
+
+	linesRead := s.readLogLines(c, reader, logRecordCount-3)
+	c.Assert(linesRead, jc.DeepEquals, logLines(3, logRecordCount))
+}
+
+func (s *debugLogSuite) TestBacklogPerEntity(c *gc.C) {
+	s.writeLogRecords(c, logRecordCount)
 
-	reader := s.openWebsocket(c, url.Values{"backlog": {"5"}})
+	reader := s.openWebsocket(c, url.Values{
+		"backlog":          {"1"},
+		"backlogPerEntity": {"true"},
+		"maxLines":         {"4"},
+	})
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
 
-	linesRead := s.readLogLines(c, reader, logLineCount-5)
-	c.Assert(linesRead, jc.DeepEquals, logLines[5:])
+	linesRead := s.readLogLines(c, reader, 4)
+	c.Assert(linesRead, jc.DeepEquals, []string{logLine(2), logLine(4), logLine(6), logLine(7)})
+	s.assertWebsocketClosed(c, reader)
 }
 
 func (s *debugLogSuite) TestMaxLines(c *gc.C) {
-	s.writeLogLines(c, 10)
+	s.writeLogRecords(c, 3)
 
-	reader := s.openWebsocket(c, url.Values{"maxLines": {"10"}})
+	reader := s.openWebsocket(c, url.Values{"maxLines": {"3"}})
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
+	s.writeLogRecords(c, logRecordCount)
 
-	linesRead := s.readLogLines(c, reader, 10)
-	c.Assert(linesRead, jc.DeepEquals, logLines[10:20])
+	linesRead := s.readLogLines(c, reader, 3)
+	c.Assert(linesRead, jc.DeepEquals, logLines(3, 6))
 	s.assertWebsocketClosed(c, reader)
 }
 
 func (s *debugLogSuite) TestBacklogWithMaxLines(c *gc.C) {
-	s.writeLogLines(c, 10)
+	s.writeLogRecords(c, 3)
+
+	reader := s.openWebsocket(c, url.Values{"backlog": {"2"}, "maxLines": {"3"}})
+	s.assertLogFollowing(c, reader)
+	s.writeLogRecords(c, logRecordCount)
+
+	linesRead := s.readLogLines(c, reader, 3)
+	c.Assert(linesRead, jc.DeepEquals, logLines(1, 4))
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *debugLogSuite) TestTimeRange(c *gc.C) {
+	s.writeLogRecords(c, logRecordCount)
 
-	reader := s.openWebsocket(c, url.Values{"backlog": {"5"}, "maxLines": {"10"}})
+	reader := s.openWebsocket(c, url.Values{
+		"startTime": {logTime(3).Format(time.RFC3339)},
+		"endTime":   {logTime(4).Format(time.RFC3339)},
+	})
 	s.assertLogFollowing(c, reader)
-	s.writeLogLines(c, logLineCount)
 
-	linesRead := s.readLogLines(c, reader, 10)
-	c.Assert(linesRead, jc.DeepEquals, logLines[5:15])
+	linesRead := s.readLogLines(c, reader, 2)
+	c.Assert(linesRead, jc.DeepEquals, logLines(3, 5))
 	s.assertWebsocketClosed(c, reader)
 }
 
-type filterTest struct {
+var filterTests = []struct {
 	about    string
 	filter   url.Values
-	filtered []string
-}
-
-var filterTests []filterTest = []filterTest{
-	{
-		about: "Filter from original test",
-		filter: url.Values{
-			"includeEntity": {"machine-0", "unit-ubuntu-0"},
-			"includeModule": {"juju.cmd"},
-			"excludeModule": {"juju.cmd.jujud"},
-		},
-		filtered: []string{logLines[0], logLines[40]},
-	}, {
-		about: "Filter from original test inverted",
-		filter: url.Values{
-			"excludeEntity": {"machine-1"},
-		},
-		filtered: []string{logLines[0], logLines[1]},
-	}, {
-		about: "Include Entity Filter with only wildcard",
-		filter: url.Values{
-			"includeEntity": {"*"},
-		},
-		filtered: []string{logLines[0], logLines[1]},
-	}, {
-		about: "Exclude Entity Filter with only wildcard",
-		filter: url.Values{
-			"excludeEntity": {"*"}, // exclude everything :-)
-		},
-		filtered: []string{},
-	}, {
-		about: "Include Entity Filter with 1 wildcard",
-		filter: url.Values{
-			"includeEntity": {"unit-*"},
-		},
-		filtered: []string{logLines[40], logLines[41]},
-	}, {
-		about: "Exclude Entity Filter with 1 wildcard",
-		filter: url.Values{
-			"excludeEntity": {"machine-*"},
-		},
-		filtered: []string{logLines[40], logLines[41]},
-	}, {
-		about: "Include Entity Filter using machine tag",
-		filter: url.Values{
-			"includeEntity": {"machine-1"},
-		},
-		filtered: []string{logLines[27], logLines[28]},
-	}, {
-		about: "Include Entity Filter using machine name",
-		filter: url.Values{
-			"includeEntity": {"1"},
-		},
-		filtered: []string{logLines[27], logLines[28]},
-	}, {
-		about: "Include Entity Filter using unit tag",
-		filter: url.Values{
-			"includeEntity": {"unit-ubuntu-0"},
-		},
-		filtered: []string{logLines[40], logLines[41]},
-	}, {
-		about: "Include Entity Filter using unit name",
-		filter: url.Values{
-			"includeEntity": {"ubuntu/0"},
-		},
-		filtered: []string{logLines[40], logLines[41]},
-	}, {
-		about: "Include Entity Filter using combination of machine tag and unit name",
-		filter: url.Values{
-			"includeEntity": {"machine-1", "ubuntu/0"},
-			"includeModule": {"juju.agent"},
-		},
-		filtered: []string{logLines[29], logLines[34], logLines[41]},
-	}, {
-		about: "Exclude Entity Filter using machine tag",
-		filter: url.Values{
-			"excludeEntity": {"machine-0"},
-		},
-		filtered: []string{logLines[27], logLines[28]},
-	}, {
-		about: "Exclude Entity Filter using machine name",
-		filter: url.Values{
-			"excludeEntity": {"0"},
-		},
-		filtered: []string{logLines[27], logLines[28]},
-	}, {
-		about: "Exclude Entity Filter using unit tag",
-		filter: url.Values{
-			"excludeEntity": {"machine-0", "machine-1", "unit-ubuntu-0"},
-		},
-		filtered: []string{logLines[54], logLines[55]},
-	}, {
-		about: "Exclude Entity Filter using unit name",
-		filter: url.Values{
-			"excludeEntity": {"machine-0", "machine-1", "ubuntu/0"},
-		},
-		filtered: []string{logLines[54], logLines[55]},
-	}, {
-		about: "Exclude Entity Filter using combination of machine tag and unit name",
-		filter: url.Values{
-			"excludeEntity": {"0", "1", "ubuntu/0"},
-		},
-		filtered: []string{logLines[54], logLines[55]},
+	filtered []int
+}{{
+	about: "Filter from original test",
+	filter: url.Values{
+		"includeEntity": {"machine-0", "unit-ubuntu-0"},
+		"includeModule": {"juju.cmd"},
+		"excludeModule": {"juju.cmd.jujud"},
 	},
-}
+	filtered: []int{0},
+}, {
+	about: "Exclude Entity Filter using machine tag",
+	filter: url.Values{
+		"excludeEntity": {"machine-1"},
+	},
+	filtered: []int{0, 1, 2, 5, 6, 7},
+}, {
+	about: "Include Entity Filter with 1 wildcard",
+	filter: url.Values{
+		"includeEntity": {"unit-*"},
+	},
+	filtered: []int{5, 6, 7},
+}, {
+	about: "Include Entity Filter using machine name",
+	filter: url.Values{
+		"includeEntity": {"1"},
+	},
+	filtered: []int{3, 4},
+}, {
+	about: "Include Entity Filter using unit name",
+	filter: url.Values{
+		"includeEntity": {"ubuntu/0"},
+	},
+	filtered: []int{5, 6},
+}, {
+	about: "Exclude Entity Filter using combination of machine and unit names",
+	filter: url.Values{
+		"excludeEntity": {"0", "1", "ubuntu/0"},
+	},
+	filtered: []int{7},
+}, {
+	about: "Level filter",
+	filter: url.Values{
+		"level": {"WARNING"},
+	},
+	filtered: []int{6, 7},
+}, {
+	about: "Include message filter",
+	filter: url.Values{
+		"includeMessage": {"^running juju"},
+	},
+	filtered: []int{0, 3},
+}, {
+	about: "Include module regexp filter",
+	filter: url.Values{
+		"includeModuleRegexp": {"uniter$"},
+	},
+	filtered: []int{5, 7},
+}, {
+	about: "Exclude module regexp and message filters",
+	filter: url.Values{
+		"excludeModuleRegexp": {`^juju\.cmd`},
+		"excludeMessage":      {"start"},
+	},
+	filtered: []int{4, 6, 7},
+}}
 
 // TestFilter tests that filters are processed correctly given specific debug-log configuration.
 func (s *debugLogSuite) TestFilter(c *gc.C) {
+	s.writeLogRecords(c, logRecordCount)
 	for i, test := range filterTests {
 		c.Logf("test %d: %v\n", i, test.about)
 
-		// ensures log file
-		path := filepath.Join(s.LogDir, "all-machines.log")
-		var err error
-		s.logFile, err = os.Create(path)
-		c.Assert(err, jc.ErrorIsNil)
-
-		// opens web socket
-		conn, err := s.dialWebsocket(c, test.filter)
+		filter := url.Values{
+			"replay":   {"true"},
+			"maxLines": {fmt.Sprint(len(test.filtered))},
+		}
+		for key, value := range test.filter {
+			filter[key] = value
+		}
+		conn, err := s.dialWebsocket(c, filter)
 		c.Assert(err, jc.ErrorIsNil)
 		reader := bufio.NewReader(conn)
 
 		s.assertLogFollowing(c, reader)
-		s.writeLogLines(c, logLineCount)
-		/*
-			This will filter and return as many lines as filtered wanted to examine.
-			 So, if specified filter can potentially return 40 lines from sample log but filtered only wanted 2,
-			 then the first 2 lines that match the filter will be returned here.
-		*/
 		linesRead := s.readLogLines(c, reader, len(test.filtered))
-		// compare retrieved lines with expected
-		c.Assert(linesRead, jc.DeepEquals, test.filtered)
-
-		// release resources
+		var expected []string
+		for _, index := range test.filtered {
+			expected = append(expected, logLine(index))
+		}
+		c.Assert(linesRead, jc.DeepEquals, expected)
+		s.assertWebsocketClosed(c, reader)
 		conn.Close()
-		s.logFile.Close()
-		s.logFile = nil
-		s.last = 0
 	}
 }
 
-// readLogLines filters and returns as many lines as filtered wanted to examine.
-// So, if specified filter can potentially return 40 lines from sample log but filtered only wanted 2,
-// then the first 2 lines that match the filter will be returned here.
+// readLogLines returns as many lines as the caller wants to examine.
 func (s *debugLogSuite) readLogLines(c *gc.C, reader *bufio.Reader, count int) (linesRead []string) {
 	for len(linesRead) < count {
 		line, err := reader.ReadString('\n')
@@ -338,25 +326,15 @@ func (s *debugLogSuite) openWebsocketCustomPath(c *gc.C, path string) *bufio.Rea
 	return bufio.NewReader(conn)
 }
 
-func (s *debugLogSuite) ensureLogFile(c *gc.C) {
-	if s.logFile != nil {
-		return
-	}
-	path := filepath.Join(s.LogDir, "all-machines.log")
-	var err error
-	s.logFile, err = os.Create(path)
-	c.Assert(err, jc.ErrorIsNil)
-	s.AddCleanup(func(c *gc.C) {
-		s.logFile.Close()
-		s.logFile = nil
-		s.last = 0
-	})
-}
-
-func (s *debugLogSuite) writeLogLines(c *gc.C, count int) {
-	s.ensureLogFile(c)
-	for i := 0; i < count && s.last < logLineCount; i++ {
-		s.logFile.WriteString(logLines[s.last] + "\n")
+// writeLogRecords writes up to count of the sample log records that
+// have not yet been written to the logs database.
+func (s *debugLogSuite) writeLogRecords(c *gc.C, count int) {
+	for s.last < count && s.last < logRecordCount {
+		rec := logRecords[s.last]
+		logger := state.NewDbLogger(s.State, rec.entity)
+		err := logger.Log(logTime(s.last), rec.module, "file.go:1", rec.level, rec.message)
+		logger.Close()
+		c.Assert(err, jc.ErrorIsNil)
 		s.last++
 	}
 }
@@ -420,67 +398,44 @@ func (s *debugLogSuite) getErrorResult(c *gc.C, reader *bufio.Reader) params.Err
 }
 
 var (
-	logLines = strings.Split(`
-machine-0: 2014-03-24 22:34:25 INFO juju.cmd supercommand.go:297 running juju-1.17.7.1-trusty-amd64 [gc]
-machine-0: 2014-03-24 22:34:25 INFO juju.cmd.jujud machine.go:127 machine agent machine-0 start (1.17.7.1-trusty-amd64 [gc])
-machine-0: 2014-03-24 22:34:25 DEBUG juju.agent agent.go:384 read agent config, format "1.18"
-machine-0: 2014-03-24 22:34:25 INFO juju.cmd.jujud machine.go:155 Starting StateWorker for machine-0
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "state"
-machine-0: 2014-03-24 22:34:25 INFO juju.state open.go:80 opening state; mongo addresses: ["localhost:37017"]; entity "machine-0"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "api"
-machine-0: 2014-03-24 22:34:25 INFO juju apiclient.go:114 api: dialing "wss://localhost:17070/"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "termination"
-machine-0: 2014-03-24 22:34:25 ERROR juju apiclient.go:119 api: websocket.Dial wss://localhost:17070/: dial tcp 127.0.0.1:17070: connection refused
-machine-0: 2014-03-24 22:34:25 ERROR juju runner.go:220 worker: exited "api": websocket.Dial wss://localhost:17070/: dial tcp 127.0.0.1:17070: connection refused
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:254 worker: restarting "api" in 3s
-machine-0: 2014-03-24 22:34:25 INFO juju.state open.go:118 connection established
-machine-0: 2014-03-24 22:34:25 DEBUG juju.utils gomaxprocs.go:24 setting GOMAXPROCS to 8
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "local-storage"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "instancepoller"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "apiserver"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "resumer"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "cleaner"
-machine-0: 2014-03-24 22:34:25 INFO juju.apiserver apiserver.go:43 listening on "[::]:17070"
-machine-0: 2014-03-24 22:34:25 INFO juju runner.go:262 worker: start "minunitsworker"
-machine-0: 2014-03-24 22:34:28 INFO juju runner.go:262 worker: start "api"
-machine-0: 2014-03-24 22:34:28 INFO juju apiclient.go:114 api: dialing "wss://localhost:17070/"
-machine-0: 2014-03-24 22:34:28 INFO juju.apiserver apiserver.go:131 [1] API connection from 127.0.0.1:36491
-machine-0: 2014-03-24 22:34:28 INFO juju apiclient.go:124 api: connection established
-machine-0: 2014-03-24 22:34:28 DEBUG juju.apiserver apiserver.go:120 <- [1] <unknown> {"RequestId":1,"Type":"Admin","Request":"Login","Params":{"AuthTag":"machine-0","Password":"ARbW7iCV4LuMugFEG+Y4e0yr","Nonce":"user-admin:bootstrap"}}
-machine-0: 2014-03-24 22:34:28 DEBUG juju.apiserver apiserver.go:127 -> [1] machine-0 10.305679ms {"RequestId":1,"Response":{}} Admin[""].Login
-machine-1: 2014-03-24 22:36:28 INFO juju.cmd supercommand.go:297 running juju-1.17.7.1-precise-amd64 [gc]
-machine-1: 2014-03-24 22:36:28 INFO juju.cmd.jujud machine.go:127 machine agent machine-1 start (1.17.7.1-precise-amd64 [gc])
-machine-1: 2014-03-24 22:36:28 DEBUG juju.agent agent.go:384 read agent config, format "1.18"
-machine-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "api"
-machine-1: 2014-03-24 22:36:28 INFO juju apiclient.go:114 api: dialing "wss://10.0.3.1:17070/"
-machine-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "termination"
-machine-1: 2014-03-24 22:36:28 INFO juju apiclient.go:124 api: connection established
-machine-1: 2014-03-24 22:36:28 DEBUG juju.agent agent.go:523 writing configuration file
-machine-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "upgrader"
-machine-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "upgrade-steps"
-machine-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "machiner"
-machine-1: 2014-03-24 22:36:28 INFO juju.cmd.jujud machine.go:458 upgrade to 1.17.7.1-precise-amd64 already completed.
-machine-1: 2014-03-24 22:36:28 INFO juju.cmd.jujud machine.go:445 upgrade to 1.17.7.1-precise-amd64 completed.
-unit-ubuntu-0[32423]: 2014-03-24 22:36:28 INFO juju.cmd supercommand.go:297 running juju-1.17.7.1-precise-amd64 [gc]
-unit-ubuntu-0[34543]: 2014-03-24 22:36:28 DEBUG juju.agent agent.go:384 read agent config, format "1.18"
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju.jujud unit.go:76 unit agent unit-ubuntu-0 start (1.17.7.1-precise-amd64 [gc])
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "api"
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju apiclient.go:114 api: dialing "wss://10.0.3.1:17070/"
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju apiclient.go:124 api: connection established
-unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.agent agent.go:523 writing configuration file
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "upgrader"
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "logger"
-unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.worker.logger logger.go:35 initial log config: "<root>=DEBUG"
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "uniter"
-unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.worker.logger logger.go:60 logger setup
-unit-ubuntu-0: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "rsyslog"
-unit-ubuntu-0: 2014-03-24 22:36:28 DEBUG juju.worker.rsyslog worker.go:76 starting rsyslog worker mode 1 for "unit-ubuntu-0" "tim-local"
-unit-ubuntu-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "logger"
-unit-ubuntu-1: 2014-03-24 22:36:28 DEBUG juju.worker.logger logger.go:35 initial log config: "<root>=DEBUG"
-unit-ubuntu-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "uniter"
-unit-ubuntu-1: 2014-03-24 22:36:28 DEBUG juju.worker.logger logger.go:60 logger setup
-unit-ubuntu-1: 2014-03-24 22:36:28 INFO juju runner.go:262 worker: start "rsyslog"
-unit-ubuntu-1: 2014-03-24 22:36:28 DEBUG juju.worker.rsyslog worker.go:76 starting rsyslog worker mode 1 for "unit-ubuntu-0" "tim-local"
-`[1:], "\n")
-	logLineCount = len(logLines)
+	logRecords = []struct {
+		entity  names.Tag
+		level   loggo.Level
+		module  string
+		message string
+	}{
+		{names.NewMachineTag("0"), loggo.INFO, "juju.cmd", "running juju-1.23-trusty-amd64 [gc]"},
+		{names.NewMachineTag("0"), loggo.INFO, "juju.cmd.jujud", "machine agent machine-0 start"},
+		{names.NewMachineTag("0"), loggo.DEBUG, "juju.worker", `worker: start "api"`},
+		{names.NewMachineTag("1"), loggo.INFO, "juju.cmd", "running juju-1.23-precise-amd64 [gc]"},
+		{names.NewMachineTag("1"), loggo.DEBUG, "juju.agent", `read agent config, format "1.18"`},
+		{names.NewUnitTag("ubuntu/0"), loggo.INFO, "juju.worker.uniter", "unit agent unit-ubuntu-0 start"},
+		{names.NewUnitTag("ubuntu/0"), loggo.WARNING, "juju.agent", "writing configuration file"},
+		{names.NewUnitTag("ubuntu/1"), loggo.ERROR, "juju.worker.uniter", `hook "install" failed`},
+	}
+	logRecordCount = len(logRecords)
 )
+
+// logTime returns the time at which the sample log record with
+// the given index was logged.
+func logTime(index int) time.Time {
+	return time.Date(2015, 4, 1, 12, index, 0, 0, time.UTC)
+}
+
+// logLine returns the line that debug-log sends for the sample log
+// record with the given index.
+func logLine(index int) string {
+	rec := logRecords[index]
+	return fmt.Sprintf("%s: %s %s %s file.go:1 %s",
+		rec.entity, logTime(index).Format("2006-01-02 15:04:05"), rec.level, rec.module, rec.message)
+}
+
+// logLines returns the lines that debug-log sends for the sample log
+// records from start up to, but not including, end.
+func logLines(start, end int) []string {
+	var lines []string
+	for i := start; i < end; i++ {
+		lines = append(lines, logLine(i))
+	}
+	return lines
+}
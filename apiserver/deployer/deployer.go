@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 )
 
@@ -26,9 +27,10 @@ type DeployerAPI struct {
 	*common.APIAddresser
 	*common.UnitsWatcher
 
-	st         *state.State
-	resources  *common.Resources
-	authorizer common.Authorizer
+	st          *state.State
+	resources   *common.Resources
+	authorizer  common.Authorizer
+	getAuthFunc common.GetAuthFunc
 }
 
 // NewDeployerAPI creates a new server-side DeployerAPI facade.
@@ -72,6 +74,7 @@ func NewDeployerAPI(
 		st:              st,
 		resources:       resources,
 		authorizer:      authorizer,
+		getAuthFunc:     getAuthFunc,
 	}, nil
 }
 
@@ -88,6 +91,45 @@ func (d *DeployerAPI) ConnectionInfo() (result params.DeployerConnectionValues,
 	return result, err
 }
 
+// WorkloadContainers returns, for each given unit, the type of
+// container in which the unit's charm runs on its machine, or "none"
+// if the charm runs directly on the machine.
+func (d *DeployerAPI) WorkloadContainers(args params.Entities) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.Entities)),
+	}
+	canAccess, err := d.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil || !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		containerType, err := d.workloadContainer(tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = string(containerType)
+	}
+	return result, nil
+}
+
+func (d *DeployerAPI) workloadContainer(tag names.UnitTag) (instance.ContainerType, error) {
+	unit, err := d.st.Unit(tag.Id())
+	if err != nil {
+		return "", err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return "", err
+	}
+	return service.WorkloadContainer(), nil
+}
+
 // getAllUnits returns a list of all principal and subordinate units
 // assigned to the given machine.
 func getAllUnits(st *state.State, tag names.Tag) ([]string, error) {
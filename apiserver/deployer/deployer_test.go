@@ -15,6 +15,7 @@ import (
 	"github.com/juju/juju/apiserver/deployer"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -242,6 +243,37 @@ func (s *deployerSuite) TestLife(c *gc.C) {
 	})
 }
 
+func (s *deployerSuite) TestWorkloadContainers(c *gc.C) {
+	service := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := service.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(s.machine1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "unit-mysql-0"},
+		{Tag: "unit-mysql-1"},
+		{Tag: "unit-logging-0"},
+		{Tag: "unit-wordpress-0"},
+		{Tag: "unit-fake-42"},
+		{Tag: "machine-1"},
+	}}
+	result, err := s.deployer.WorkloadContainers(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.StringResults{
+		Results: []params.StringResult{
+			{Result: "none"},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Result: "none"},
+			{Result: "lxc"},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
 func (s *deployerSuite) TestRemove(c *gc.C) {
 	c.Assert(s.principal0.Life(), gc.Equals, state.Alive)
 	c.Assert(s.subordinate0.Life(), gc.Equals, state.Alive)
@@ -257,9 +289,15 @@ func (s *deployerSuite) TestRemove(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, params.ErrorResults{
 		Results: []params.ErrorResult{
-			{&params.Error{Message: `cannot remove entity "unit-mysql-0": still alive`}},
+			{&params.Error{
+				Message: `cannot remove entity "unit-mysql-0": still alive`,
+				Code:    params.CodeWrongLifecycle,
+			}},
 			{apiservertesting.ErrUnauthorized},
-			{&params.Error{Message: `cannot remove entity "unit-logging-0": still alive`}},
+			{&params.Error{
+				Message: `cannot remove entity "unit-logging-0": still alive`,
+				Code:    params.CodeWrongLifecycle,
+			}},
 			{apiservertesting.ErrUnauthorized},
 		},
 	})
@@ -4,8 +4,12 @@
 package environment
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 )
 
 func init() {
@@ -15,11 +19,46 @@ func init() {
 // EnvironmentAPI implements the API used by the machine environment worker.
 type EnvironmentAPI struct {
 	*common.EnvironWatcher
+	st        *state.State
+	resources *common.Resources
 }
 
 // NewEnvironmentAPI creates a new instance of the Environment API.
 func NewEnvironmentAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*EnvironmentAPI, error) {
 	return &EnvironmentAPI{
 		EnvironWatcher: common.NewEnvironWatcher(st, resources, authorizer),
+		st:             st,
+		resources:      resources,
+	}, nil
+}
+
+// EnvironmentSuspended returns whether the environment is suspended,
+// and if so, why.
+func (api *EnvironmentAPI) EnvironmentSuspended() (params.EnvironmentSuspendedResult, error) {
+	env, err := api.st.Environment()
+	if err != nil {
+		return params.EnvironmentSuspendedResult{}, errors.Trace(err)
+	}
+	suspended, reason := env.Suspended()
+	return params.EnvironmentSuspendedResult{
+		Suspended: suspended,
+		Reason:    reason,
+	}, nil
+}
+
+// WatchEnvironment returns a NotifyWatcher that observes changes to
+// the environment, including its suspension.
+func (api *EnvironmentAPI) WatchEnvironment() (params.NotifyWatchResult, error) {
+	env, err := api.st.Environment()
+	if err != nil {
+		return params.NotifyWatchResult{}, errors.Trace(err)
+	}
+	watch := env.Watch()
+	// Consume the initial event.
+	if _, ok := <-watch.Changes(); !ok {
+		return params.NotifyWatchResult{}, watcher.EnsureErr(watch)
+	}
+	return params.NotifyWatchResult{
+		NotifyWatcherId: api.resources.Register(watch),
 	}, nil
 }
@@ -10,9 +10,11 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	commontesting "github.com/juju/juju/apiserver/common/testing"
 	"github.com/juju/juju/apiserver/environment"
+	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 )
 
 type environmentSuite struct {
@@ -50,3 +52,38 @@ func (s *environmentSuite) SetUpTest(c *gc.C) {
 	s.EnvironWatcherTest = commontesting.NewEnvironWatcherTest(
 		s.api, s.State, s.resources, commontesting.NoSecrets)
 }
+
+func (s *environmentSuite) TestEnvironmentSuspended(c *gc.C) {
+	result, err := s.api.EnvironmentSuspended()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.EnvironmentSuspendedResult{})
+
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.api.EnvironmentSuspended()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.EnvironmentSuspendedResult{
+		Suspended: true,
+		Reason:    "provider outage",
+	})
+}
+
+func (s *environmentSuite) TestWatchEnvironment(c *gc.C) {
+	result, err := s.api.WatchEnvironment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	resource := s.resources.Get(result.NotifyWatcherId)
+	defer statetesting.AssertStop(c, resource)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
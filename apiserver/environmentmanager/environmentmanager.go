@@ -32,6 +32,9 @@ type EnvironmentManager interface {
 	ConfigSkeleton(args params.EnvironmentSkeletonConfigArgs) (params.EnvironConfigResult, error)
 	CreateEnvironment(args params.EnvironmentCreateArgs) (params.Environment, error)
 	ListEnvironments(user params.Entity) (params.EnvironmentList, error)
+	EnvironmentDefaults() (params.EnvironmentDefaults, error)
+	SetEnvironmentDefaults(args params.SetEnvironmentDefaults) (params.ErrorResults, error)
+	UnsetEnvironmentDefaults(args params.UnsetEnvironmentDefaults) (params.ErrorResults, error)
 }
 
 // EnvironmentManagerAPI implements the environment manager interface and is
@@ -240,6 +243,19 @@ func (em *EnvironmentManagerAPI) newEnvironmentConfig(args params.EnvironmentCre
 			}
 		}
 	}
+	// Any defaults set on the state server, for all environments or for
+	// those in the new environment's region, are used for values that
+	// have not been specified.
+	region, _ := joint["region"].(string)
+	defaults, err := em.state.EnvironDefaultsForRegion(region)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for key, value := range defaults {
+		if _, found := joint[key]; !found {
+			joint[key] = value
+		}
+	}
 
 	cfg, err := em.validConfig(joint)
 	if err != nil {
@@ -297,14 +313,27 @@ func (em *EnvironmentManagerAPI) CreateEnvironment(args params.EnvironmentCreate
 		return result, errors.Trace(err)
 	}
 
-	newConfig, err := em.newEnvironmentConfig(args, stateServerEnv)
+	var source ConfigSource = stateServerEnv
+	if args.Cloud != "" {
+		args, source, err = em.cloudArgs(args, ownerTag, stateServerEnv)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+	}
+	newConfig, err := em.newEnvironmentConfig(args, source)
 	if err != nil {
 		return result, errors.Trace(err)
 	}
 	// NOTE: check the agent-version of the config, and if it is > the current
 	// version, it is not supported, also check existing tools, and if we don't
 	// have tools for that version, also die.
-	env, st, err := em.state.NewEnvironment(newConfig, ownerTag)
+	var env *state.Environment
+	var st *state.State
+	if args.Cloud != "" {
+		env, st, err = em.state.NewEnvironmentOnCloud(newConfig, ownerTag, args.Cloud, args.CloudCredential)
+	} else {
+		env, st, err = em.state.NewEnvironment(newConfig, ownerTag)
+	}
 	if err != nil {
 		return result, errors.Annotate(err, "failed to create new environment")
 	}
@@ -317,6 +346,53 @@ func (em *EnvironmentManagerAPI) CreateEnvironment(args params.EnvironmentCreate
 	return result, nil
 }
 
+// cloudConfigSource provides the state server's config as it would be
+// if the state server were hosted on a given cloud: the cloud's
+// provider type and config take the place of the state server's.
+type cloudConfigSource struct {
+	source ConfigSource
+	cloud  *state.Cloud
+}
+
+// Config is part of the ConfigSource interface.
+func (s cloudConfigSource) Config() (*config.Config, error) {
+	baseConfig, err := s.source.Config()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	attrs := s.cloud.Config()
+	attrs["type"] = s.cloud.Type()
+	return baseConfig.Apply(attrs)
+}
+
+// cloudArgs returns the args for creating an environment on the cloud
+// named in the given args, using the owner's named credential for it,
+// along with the source of the config values the environment must
+// share with the cloud.
+func (em *EnvironmentManagerAPI) cloudArgs(
+	args params.EnvironmentCreateArgs, owner names.UserTag, source ConfigSource,
+) (params.EnvironmentCreateArgs, ConfigSource, error) {
+	cloud, err := em.state.Cloud(args.Cloud)
+	if err != nil {
+		return args, nil, errors.Trace(err)
+	}
+	credential, err := em.state.CloudCredential(owner, args.Cloud, args.CloudCredential)
+	if err != nil {
+		return args, nil, errors.Trace(err)
+	}
+	// Account values given in the args take precedence over the
+	// credential's.
+	account := make(map[string]interface{})
+	for key, value := range credential.Attributes() {
+		account[key] = value
+	}
+	for key, value := range args.Account {
+		account[key] = value
+	}
+	args.Account = account
+	return args, cloudConfigSource{source, cloud}, nil
+}
+
 // ListEnvironments returns the environments that the specified user
 // has access to in the current server.  Only that state server owner
 // can list environments for any user (at this stage).  Other users
@@ -356,3 +432,106 @@ func (em *EnvironmentManagerAPI) ListEnvironments(user params.Entity) (params.En
 
 	return result, nil
 }
+
+// EnvironmentDefaults returns the config defaults set on the state
+// server for new environments.
+func (em *EnvironmentManagerAPI) EnvironmentDefaults() (params.EnvironmentDefaults, error) {
+	result := params.EnvironmentDefaults{}
+	defaults, err := em.state.EnvironDefaults()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Config = defaults.Values
+	result.Regions = defaults.Regions
+	return result, nil
+}
+
+// SetEnvironmentDefaults sets config defaults for new environments,
+// either for all of them or for those in a given region. Existing
+// environments are not affected. Only the state server owner may
+// change the defaults.
+func (em *EnvironmentManagerAPI) SetEnvironmentDefaults(args params.SetEnvironmentDefaults) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Config)),
+	}
+	stateServerEnv, err := em.adminCheck()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Config {
+		values, err := em.checkDefaults(arg.Config, stateServerEnv)
+		if err == nil {
+			err = em.state.UpdateEnvironDefaults(arg.Region, values, nil)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// UnsetEnvironmentDefaults removes config defaults for new
+// environments, either for all of them or for those in a given region.
+// Only the state server owner may change the defaults.
+func (em *EnvironmentManagerAPI) UnsetEnvironmentDefaults(args params.UnsetEnvironmentDefaults) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Keys)),
+	}
+	if _, err := em.adminCheck(); err != nil {
+		return result, errors.Trace(err)
+	}
+	for i, arg := range args.Keys {
+		err := em.state.UpdateEnvironDefaults(arg.Region, nil, arg.Keys)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// adminCheck returns the state server environment if the API user is
+// its owner, and an error otherwise.
+func (em *EnvironmentManagerAPI) adminCheck() (*state.Environment, error) {
+	stateServerEnv, err := em.state.StateServerEnvironment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	adminUser := stateServerEnv.Owner()
+	if err := em.authCheck(adminUser, adminUser); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stateServerEnv, nil
+}
+
+// checkDefaults returns the given config defaults as they would be held
+// in a new environment's config, or an error if any of them is invalid
+// or may not be set as a default.
+func (em *EnvironmentManagerAPI) checkDefaults(values map[string]interface{}, source ConfigSource) (map[string]interface{}, error) {
+	baseConfig, err := source.Config()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fields, err := em.restrictedProviderFields(baseConfig.Type())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fields = append(fields, "name", "uuid")
+	for _, field := range fields {
+		if _, found := values[field]; found {
+			return nil, errors.Errorf("%s cannot be set as a default", field)
+		}
+	}
+	// As when creating environments, the values are pushed through
+	// config validation, both to check them and to convert numbers
+	// serialized through JSON back into integers.
+	attrs := baseConfig.AllAttrs()
+	for key, value := range values {
+		attrs[key] = value
+	}
+	cfg, err := em.validConfig(attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	validAttrs := cfg.AllAttrs()
+	result := make(map[string]interface{})
+	for key := range values {
+		result[key] = validAttrs[key]
+	}
+	return result, nil
+}
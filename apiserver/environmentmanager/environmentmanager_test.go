@@ -332,6 +332,220 @@ func (s *envManagerSuite) TestListEnvironmentsDenied(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "permission denied")
 }
 
+func (s *envManagerSuite) TestSetEnvironmentDefaults(c *gc.C) {
+	s.setAPIUser(c, s.AdminUserTag(c))
+	result, err := s.envmanager.SetEnvironmentDefaults(params.SetEnvironmentDefaults{
+		Config: []params.EnvironmentDefaultValues{{
+			Config: map[string]interface{}{
+				"default-series": "precise",
+				"logging-config": "<root>=DEBUG",
+			},
+		}, {
+			Region: "dummy-region",
+			Config: map[string]interface{}{
+				"default-series": "trusty",
+				// Numbers arrive as float64 when serialized through JSON.
+				"bootstrap-retry-delay": float64(10),
+			},
+		}, {
+			Config: map[string]interface{}{"type": "fake"},
+		}, {
+			Config: map[string]interface{}{"name": "fake"},
+		}, {
+			Config: map[string]interface{}{"default-series": 42},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 5)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.IsNil)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, "type cannot be set as a default")
+	c.Assert(result.Results[3].Error, gc.ErrorMatches, "name cannot be set as a default")
+	c.Assert(result.Results[4].Error, gc.ErrorMatches, "creating config from values failed: default-series: expected string, got int\\(42\\)")
+
+	defaults, err := s.envmanager.EnvironmentDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults, jc.DeepEquals, params.EnvironmentDefaults{
+		Config: map[string]interface{}{
+			"default-series": "precise",
+			"logging-config": "<root>=DEBUG",
+		},
+		Regions: map[string]map[string]interface{}{
+			"dummy-region": {
+				"default-series":        "trusty",
+				"bootstrap-retry-delay": 10,
+			},
+		},
+	})
+}
+
+func (s *envManagerSuite) TestUnsetEnvironmentDefaults(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("", map[string]interface{}{
+		"default-series": "precise",
+		"logging-config": "<root>=DEBUG",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.setAPIUser(c, s.AdminUserTag(c))
+	result, err := s.envmanager.UnsetEnvironmentDefaults(params.UnsetEnvironmentDefaults{
+		Keys: []params.EnvironmentDefaultKeys{{
+			Keys: []string{"default-series"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), jc.ErrorIsNil)
+
+	defaults, err := s.envmanager.EnvironmentDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults.Config, jc.DeepEquals, map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	})
+}
+
+func (s *envManagerSuite) TestNonAdminCannotChangeEnvironmentDefaults(c *gc.C) {
+	s.setAPIUser(c, names.NewUserTag("external@remote"))
+	_, err := s.envmanager.SetEnvironmentDefaults(params.SetEnvironmentDefaults{
+		Config: []params.EnvironmentDefaultValues{{
+			Config: map[string]interface{}{"default-series": "precise"},
+		}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.envmanager.UnsetEnvironmentDefaults(params.UnsetEnvironmentDefaults{
+		Keys: []params.EnvironmentDefaultKeys{{
+			Keys: []string{"default-series"},
+		}},
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	// Anyone may see the defaults.
+	_, err = s.envmanager.EnvironmentDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *envManagerSuite) TestCreateEnvironmentInheritsDefaults(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("", map[string]interface{}{
+		"default-series": "precise",
+		"logging-config": "<root>=DEBUG",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	admin := s.AdminUserTag(c)
+	s.setAPIUser(c, admin)
+	args := s.createArgs(c, admin)
+	args.Config["logging-config"] = "<root>=WARNING"
+	env, err := s.envmanager.CreateEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := s.State.ForEnviron(names.NewEnvironTag(env.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	series, _ := cfg.DefaultSeries()
+	c.Assert(series, gc.Equals, "precise")
+	c.Assert(cfg.LoggingConfig(), gc.Equals, "<root>=WARNING")
+}
+
+func (s *envManagerSuite) TestCreateEnvironmentInheritsRegionDefaults(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("", map[string]interface{}{
+		"default-series": "precise",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateEnvironDefaults("dummy-region", map[string]interface{}{
+		"default-series": "trusty",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	admin := s.AdminUserTag(c)
+	s.setAPIUser(c, admin)
+	args := s.createArgs(c, admin)
+	args.Config["region"] = "dummy-region"
+	env, err := s.envmanager.CreateEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := s.State.ForEnviron(names.NewEnvironTag(env.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	series, _ := cfg.DefaultSeries()
+	c.Assert(series, gc.Equals, "trusty")
+}
+
+func (s *envManagerSuite) TestCreateEnvironmentOnCloud(c *gc.C) {
+	admin := s.AdminUserTag(c)
+	_, err := s.State.AddCloud("elsewhere", "fake", map[string]interface{}{
+		"region": "far-away",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateCloudCredential(admin, "elsewhere", "default", map[string]string{
+		"access-key": "key",
+		"secret-key": "sekrit",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.setAPIUser(c, admin)
+	args := s.createArgs(c, admin)
+	args.Cloud = "elsewhere"
+	args.CloudCredential = "default"
+	args.Account["secret-key"] = "other"
+	env, err := s.envmanager.CreateEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	st, err := s.State.ForEnviron(names.NewEnvironTag(env.UUID))
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	stateEnv, err := st.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stateEnv.Cloud(), gc.Equals, "elsewhere")
+	c.Assert(stateEnv.CloudCredential(), gc.Equals, "default")
+	cfg, err := st.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.Type(), gc.Equals, "fake")
+	attrs := cfg.AllAttrs()
+	c.Assert(attrs["region"], gc.Equals, "far-away")
+	c.Assert(attrs["access-key"], gc.Equals, "key")
+	c.Assert(attrs["secret-key"], gc.Equals, "other")
+	// Values not specific to the cloud are still taken from the
+	// state server.
+	c.Assert(attrs["ca-cert"], gc.Equals, coretesting.CACert)
+}
+
+func (s *envManagerSuite) TestCreateEnvironmentOnCloudMismatch(c *gc.C) {
+	admin := s.AdminUserTag(c)
+	_, err := s.State.AddCloud("elsewhere", "fake", map[string]interface{}{
+		"region": "far-away",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateCloudCredential(admin, "elsewhere", "default", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.setAPIUser(c, admin)
+	args := s.createArgs(c, admin)
+	args.Cloud = "elsewhere"
+	args.CloudCredential = "default"
+	args.Config["region"] = "near-by"
+	_, err = s.envmanager.CreateEnvironment(args)
+	c.Assert(err, gc.ErrorMatches, `specified region "near-by" does not match apiserver "far-away"`)
+}
+
+func (s *envManagerSuite) TestCreateEnvironmentOnCloudMissingCredential(c *gc.C) {
+	admin := s.AdminUserTag(c)
+	_, err := s.State.AddCloud("elsewhere", "fake", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.setAPIUser(c, admin)
+	args := s.createArgs(c, admin)
+	args.Cloud = "elsewhere"
+	args.CloudCredential = "default"
+	_, err = s.envmanager.CreateEnvironment(args)
+	c.Assert(err, gc.ErrorMatches, `credential "default" for cloud "elsewhere" not found`)
+
+	args.Cloud = "nowhere"
+	_, err = s.envmanager.CreateEnvironment(args)
+	c.Assert(err, gc.ErrorMatches, `cloud "nowhere" not found`)
+}
+
 type fakeProvider struct {
 	environs.EnvironProvider
 }
@@ -340,6 +554,10 @@ func (*fakeProvider) Validate(cfg, old *config.Config) (*config.Config, error) {
 	return cfg, nil
 }
 
+func (*fakeProvider) RestrictedConfigAttributes() []string {
+	return []string{"region"}
+}
+
 func init() {
 	environs.RegisterProvider("fake", &fakeProvider{})
 }
@@ -17,7 +17,13 @@ var getState = func(st *state.State) stateInterface {
 type stateInterface interface {
 	StateServerEnvironment() (*state.Environment, error)
 	NewEnvironment(*config.Config, names.UserTag) (*state.Environment, *state.State, error)
+	NewEnvironmentOnCloud(cfg *config.Config, owner names.UserTag, cloud, credential string) (*state.Environment, *state.State, error)
+	Cloud(name string) (*state.Cloud, error)
+	CloudCredential(owner names.UserTag, cloud, name string) (*state.CloudCredential, error)
 	EnvironmentsForUser(names.UserTag) ([]*state.Environment, error)
+	EnvironDefaults() (state.EnvironDefaults, error)
+	EnvironDefaultsForRegion(region string) (map[string]interface{}, error)
+	UpdateEnvironDefaults(region string, update map[string]interface{}, remove []string) error
 }
 
 type stateShim struct {
@@ -23,8 +23,6 @@ var (
 	MaxClientPingInterval = &maxClientPingInterval
 	MongoPingInterval     = &mongoPingInterval
 	NewBackups            = &newBackups
-	ParseLogLine          = parseLogLine
-	AgentMatchesFilter    = agentMatchesFilter
 )
 
 func ApiHandlerWithEntity(entity state.Entity) *apiHandler {
@@ -88,6 +86,20 @@ func TestingUpgradingRoot(st *state.State) rpc.MethodFinder {
 	return newUpgradingRoot(r)
 }
 
+// TestingSuspendableRoot returns a suspendableRoot containing a
+// srvRoot as returned by TestingApiRoot.
+func TestingSuspendableRoot(st *state.State) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newSuspendableRoot(r, st)
+}
+
+// TestingBlockingRoot returns a blockingRoot containing a
+// srvRoot as returned by TestingApiRoot.
+func TestingBlockingRoot(st *state.State) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newBlockingRoot(r, st)
+}
+
 type preFacadeAdminApi struct{}
 
 func newPreFacadeAdminApi(srv *Server, root *apiHandler, reqNotifier *requestNotifier) interface{} {
@@ -159,13 +171,3 @@ func TestingAboutToRestoreRoot(st *state.State) *aboutToRestoreRoot {
 	r := TestingApiRoot(st)
 	return newAboutToRestoreRoot(r)
 }
-
-// LogLineAgentTag gives tests access to an internal logLine attribute
-func (logLine *logLine) LogLineAgentTag() string {
-	return logLine.agentTag
-}
-
-// LogLineAgentName gives tests access to an internal logLine attribute
-func (logLine *logLine) LogLineAgentName() string {
-	return logLine.agentName
-}
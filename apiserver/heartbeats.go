@@ -0,0 +1,162 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+)
+
+// disconnectedHeartbeatRetention is how long an API server keeps
+// recording when an agent that disconnected from it was last seen.
+const disconnectedHeartbeatRetention = 24 * time.Hour
+
+// heartbeatAgent identifies an agent within the environments served
+// by an API server.
+type heartbeatAgent struct {
+	envUUID string
+	tag     names.Tag
+}
+
+// agentHeartbeats tracks the agents connected to an API server, and
+// records them in state every state.AgentHeartbeatInterval, so that
+// the liveness of all the agents is written with a single update
+// rather than by a presence pinger per agent. An agent's last-seen
+// time is that of the most recent request on its connection.
+type agentHeartbeats struct {
+	st       *state.State
+	serverId string
+
+	mu        sync.Mutex
+	connected map[int64]*heartbeatConn
+	gone      map[heartbeatAgent]time.Time
+
+	// writeMu serializes writes, so a later snapshot is never
+	// overwritten by an earlier one.
+	writeMu sync.Mutex
+}
+
+type heartbeatConn struct {
+	agent    heartbeatAgent
+	notifier *requestNotifier
+}
+
+func newAgentHeartbeats(st *state.State, serverId string) *agentHeartbeats {
+	return &agentHeartbeats{
+		st:        st,
+		serverId:  serverId,
+		connected: make(map[int64]*heartbeatConn),
+		gone:      make(map[heartbeatAgent]time.Time),
+	}
+}
+
+// add records that the agent with the given tag has logged in to the
+// environment with the given UUID on the connection reported by the
+// given notifier. The agent is recorded as alive before add returns,
+// so its liveness is reported as soon as it has logged in.
+func (h *agentHeartbeats) add(notifier *requestNotifier, envUUID string, tag names.Tag) error {
+	agent := heartbeatAgent{envUUID, tag}
+	h.mu.Lock()
+	h.connected[notifier.id] = &heartbeatConn{
+		agent:    agent,
+		notifier: notifier,
+	}
+	delete(h.gone, agent)
+	h.mu.Unlock()
+	return h.write()
+}
+
+// remove records that the connection with the given id has closed.
+// Unless the agent is still connected on another connection, it is
+// recorded as no longer alive before remove returns.
+func (h *agentHeartbeats) remove(id int64) error {
+	h.mu.Lock()
+	conn, ok := h.connected[id]
+	if ok {
+		delete(h.connected, id)
+		h.gone[conn.agent] = conn.notifier.lastActive()
+	}
+	h.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return h.write()
+}
+
+// snapshot returns the heartbeats of all the agents known to the
+// server, forgetting agents that disconnected too long ago.
+func (h *agentHeartbeats) snapshot() []state.AgentHeartbeat {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	lastSeen := make(map[heartbeatAgent]time.Time)
+	for _, conn := range h.connected {
+		seen := conn.notifier.lastActive()
+		if seen.After(lastSeen[conn.agent]) {
+			lastSeen[conn.agent] = seen
+		}
+	}
+	heartbeats := make([]state.AgentHeartbeat, 0, len(lastSeen)+len(h.gone))
+	for agent, seen := range lastSeen {
+		heartbeats = append(heartbeats, makeHeartbeat(agent, seen, true))
+	}
+	for agent, seen := range h.gone {
+		if _, ok := lastSeen[agent]; ok {
+			continue
+		}
+		if time.Since(seen) > disconnectedHeartbeatRetention {
+			delete(h.gone, agent)
+			continue
+		}
+		heartbeats = append(heartbeats, makeHeartbeat(agent, seen, false))
+	}
+	return heartbeats
+}
+
+func makeHeartbeat(agent heartbeatAgent, lastSeen time.Time, connected bool) state.AgentHeartbeat {
+	return state.AgentHeartbeat{
+		EnvUUID:   agent.envUUID,
+		Agent:     agent.tag,
+		LastSeen:  lastSeen,
+		Connected: connected,
+	}
+}
+
+// write records the heartbeats of all the agents known to the server.
+func (h *agentHeartbeats) write() error {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	return h.st.SetAgentHeartbeats(h.serverId, h.snapshot())
+}
+
+// run writes the heartbeats every state.AgentHeartbeatInterval
+// until the stop channel is closed.
+func (h *agentHeartbeats) run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(state.AgentHeartbeatInterval):
+		}
+		if err := h.write(); err != nil {
+			logger.Errorf("cannot record agent heartbeats: %v", err)
+		}
+	}
+}
+
+// agentHeartbeat is the resource held by an agent's connection
+// while it is recorded as alive.
+type agentHeartbeat struct {
+	heartbeats *agentHeartbeats
+	id         int64
+}
+
+// Stop implements common.Resource, recording that the connection
+// has closed.
+func (a *agentHeartbeat) Stop() error {
+	return a.heartbeats.remove(a.id)
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type heartbeatsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&heartbeatsSuite{})
+
+func (s *heartbeatsSuite) TestSnapshot(c *gc.C) {
+	h := newAgentHeartbeats(nil, "0:17070")
+	envUUID := testing.EnvironmentTag.Id()
+	machine := heartbeatAgent{envUUID, names.NewMachineTag("0")}
+	unit := heartbeatAgent{envUUID, names.NewUnitTag("mysql/0")}
+	stale := heartbeatAgent{envUUID, names.NewUnitTag("mysql/1")}
+
+	// The machine agent has two connections; it was last seen on
+	// the more recently active one.
+	older := newRequestNotifier(nil)
+	newer := newRequestNotifier(nil)
+	older.lastActivity = time.Now().Add(-time.Minute)
+	h.connected[older.id] = &heartbeatConn{machine, older}
+	h.connected[newer.id] = &heartbeatConn{machine, newer}
+
+	// The machine agent was also recorded as having disconnected,
+	// which its current connections override.
+	h.gone[machine] = time.Now().Add(-time.Hour)
+	unitSeen := time.Now().Add(-time.Hour)
+	h.gone[unit] = unitSeen
+	h.gone[stale] = time.Now().Add(-disconnectedHeartbeatRetention - time.Hour)
+
+	heartbeats := h.snapshot()
+	c.Assert(heartbeats, gc.HasLen, 2)
+	if heartbeats[0].Agent != machine.tag {
+		heartbeats[0], heartbeats[1] = heartbeats[1], heartbeats[0]
+	}
+	c.Assert(heartbeats, jc.DeepEquals, []state.AgentHeartbeat{{
+		EnvUUID:   envUUID,
+		Agent:     machine.tag,
+		LastSeen:  newer.lastActive(),
+		Connected: true,
+	}, {
+		EnvUUID:   envUUID,
+		Agent:     unit.tag,
+		LastSeen:  unitSeen,
+		Connected: false,
+	}})
+
+	// Agents that disconnected too long ago are forgotten.
+	c.Assert(h.gone, gc.HasLen, 2)
+	_, ok := h.gone[stale]
+	c.Assert(ok, jc.IsFalse)
+}
@@ -0,0 +1,116 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package highavailability
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker/singular"
+)
+
+func init() {
+	common.RegisterStandardFacade("HighAvailability", 2, NewHighAvailabilityAPIV2)
+}
+
+// HighAvailabilityAPIV2 implements version 2 of the HighAvailability
+// API, which adds reporting of the health of the state servers and of
+// the state servers running the singular workers of each environment.
+type HighAvailabilityAPIV2 struct {
+	*HighAvailabilityAPI
+}
+
+// NewHighAvailabilityAPIV2 creates a new server-side endpoint for
+// version 2 of the HighAvailability API.
+func NewHighAvailabilityAPIV2(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*HighAvailabilityAPIV2, error) {
+	api, err := NewHighAvailabilityAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &HighAvailabilityAPIV2{api}, nil
+}
+
+// StateServersHealth returns the health of the state server machines.
+func (api *HighAvailabilityAPIV2) StateServersHealth() (params.StateServersHealthResult, error) {
+	if !api.state.IsStateServer() {
+		return params.StateServersHealthResult{}, errors.New("unsupported with hosted environments")
+	}
+	health, err := api.state.StateServersHealth()
+	if err != nil {
+		return params.StateServersHealthResult{}, errors.Trace(err)
+	}
+	result := params.StateServersHealthResult{
+		StateServers: make([]params.StateServerHealth, len(health)),
+	}
+	for i, h := range health {
+		result.StateServers[i] = params.StateServerHealth{
+			MachineTag:   names.NewMachineTag(h.MachineId).String(),
+			AgentAlive:   h.AgentAlive,
+			WantsVote:    h.WantsVote,
+			HasVote:      h.HasVote,
+			MongoState:   h.MongoState,
+			MongoHealthy: h.MongoHealthy,
+			Available:    h.Available(),
+		}
+		if h.WantsVote {
+			result.Voters++
+			if h.Available() {
+				result.AvailableVoters++
+			}
+		}
+	}
+	result.Healthy = result.Voters > 0 && result.AvailableVoters == result.Voters
+	return result, nil
+}
+
+// SingularWorkers returns, for each environment, the state server
+// which holds its singular lease and so runs its singular workers.
+func (api *HighAvailabilityAPIV2) SingularWorkers() (params.SingularWorkersResult, error) {
+	if !api.state.IsStateServer() {
+		return params.SingularWorkersResult{}, errors.New("unsupported with hosted environments")
+	}
+	workers := singular.LeaseWorkers()
+	namespaces := make([]string, 0, len(workers))
+	for namespace := range workers {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	result := params.SingularWorkersResult{
+		Environments: []params.SingularWorkers{},
+	}
+	for _, namespace := range namespaces {
+		uuid, ok := singular.LeaseEnvironUUID(namespace)
+		if !ok {
+			continue
+		}
+		envTag := names.NewEnvironTag(uuid)
+		env, err := api.state.GetEnvironment(envTag)
+		if errors.IsNotFound(err) {
+			// The environment has been destroyed since its
+			// workers were started.
+			continue
+		} else if err != nil {
+			return params.SingularWorkersResult{}, errors.Trace(err)
+		}
+		info := params.SingularWorkers{
+			EnvironTag:  envTag.String(),
+			EnvironName: env.Name(),
+			Workers:     workers[namespace],
+		}
+		if token := raftlease.Manager().RetrieveLease(namespace); token.Id != "" {
+			expiry := token.Expiration
+			info.HolderTag = names.NewMachineTag(token.Id).String()
+			info.Expiry = &expiry
+		}
+		result.Environments = append(result.Environments, info)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package highavailability_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/highavailability"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/lease/raftlease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/singular"
+)
+
+func (s *clientSuite) TestStateServersHealth(c *gc.C) {
+	api, err := highavailability.NewHighAvailabilityAPIV2(s.State, s.resources, s.authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.ensureAvailability(c, 3, emptyCons, defaultSeries, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.StateServersHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.StateServers, gc.HasLen, 3)
+	c.Assert(result.Voters, gc.Equals, 3)
+	c.Assert(result.AvailableVoters, gc.Equals, 1)
+	c.Assert(result.Healthy, jc.IsFalse)
+
+	c.Assert(result.StateServers[0].MachineTag, gc.Equals, "machine-0")
+	c.Assert(result.StateServers[0].AgentAlive, jc.IsTrue)
+	c.Assert(result.StateServers[0].WantsVote, jc.IsTrue)
+	for _, server := range result.StateServers[1:] {
+		c.Check(server.AgentAlive, jc.IsFalse)
+		c.Check(server.Available, jc.IsFalse)
+	}
+}
+
+func (s *clientSuite) TestStateServersHealthAllAvailable(c *gc.C) {
+	api, err := highavailability.NewHighAvailabilityAPIV2(s.State, s.resources, s.authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.StateServersHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.StateServers, gc.HasLen, 1)
+	c.Assert(result.Voters, gc.Equals, 1)
+	c.Assert(result.AvailableVoters, gc.Equals, 1)
+	c.Assert(result.Healthy, jc.IsTrue)
+}
+
+func (s *clientSuite) TestSingularWorkers(c *gc.C) {
+	service, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(service)
+
+	namespace := singular.LeaseNamespace(s.State.EnvironUUID())
+	conn := singular.NewLeaseConn(raftlease.Manager(), namespace, "0")
+	runner, err := singular.New(worker.NewRunner(
+		func(error) bool { return true },
+		func(error, error) bool { return true },
+	), conn)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(runner)
+	for _, id := range []string{"instancepoller", "firewaller"} {
+		err := runner.StartWorker(id, func() (worker.Worker, error) {
+			return worker.NewNoOpWorker(), nil
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	api, err := highavailability.NewHighAvailabilityAPIV2(s.State, s.resources, s.authoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.SingularWorkers()
+	c.Assert(err, jc.ErrorIsNil)
+	var info *params.SingularWorkers
+	for i, env := range result.Environments {
+		if env.EnvironTag == s.State.EnvironTag().String() {
+			info = &result.Environments[i]
+		}
+	}
+	c.Assert(info, gc.NotNil)
+	c.Assert(info.EnvironName, gc.Equals, "dummyenv")
+	c.Assert(info.HolderTag, gc.Equals, "machine-0")
+	c.Assert(info.Expiry, gc.NotNil)
+	c.Assert(info.Workers, jc.DeepEquals, []string{"firewaller", "instancepoller"})
+}
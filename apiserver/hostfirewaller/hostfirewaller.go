@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package hostfirewaller implements the API used by machine agents to
+// enforce, with the host's own firewall, the address restrictions of
+// services exposed on providers that have no firewall of their own.
+package hostfirewaller
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("HostFirewaller", 1, NewHostFirewallerAPI)
+}
+
+// HostFirewallerAPI provides access to the HostFirewaller API facade.
+type HostFirewallerAPI struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewHostFirewallerAPI creates a new server-side HostFirewaller API
+// facade.
+func NewHostFirewallerAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*HostFirewallerAPI, error) {
+	if !authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &HostFirewallerAPI{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// IngressRules returns the ingress rules for the ports opened on each
+// given machine by units of exposed services.
+func (h *HostFirewallerAPI) IngressRules(args params.Entities) (params.IngressRulesResults, error) {
+	result := params.IngressRulesResults{
+		Results: make([]params.IngressRulesResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseMachineTag(entity.Tag)
+		if err != nil || !h.authorizer.AuthOwner(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		rules, err := h.machineRules(tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Rules = rules
+	}
+	return result, nil
+}
+
+func (h *HostFirewallerAPI) machineRules(tag names.MachineTag) ([]params.IngressRule, error) {
+	machine, err := h.st.Machine(tag.Id())
+	if err != nil {
+		return nil, err
+	}
+	rules, err := machine.IngressRules()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]params.IngressRule, len(rules))
+	for i, rule := range rules {
+		result[i] = params.FromNetworkIngressRule(rule)
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostfirewaller_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/hostfirewaller"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type hostFirewallerSuite struct {
+	jujutesting.JujuConnSuite
+	machine *state.Machine
+	unit    *state.Unit
+	api     *hostfirewaller.HostFirewallerAPI
+}
+
+var _ = gc.Suite(&hostFirewallerSuite{})
+
+func (s *hostFirewallerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, &factory.UnitParams{})
+	machineId, err := s.unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine, err = s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+
+	auth := apiservertesting.FakeAuthorizer{Tag: s.machine.Tag()}
+	s.api, err = hostfirewaller.NewHostFirewallerAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *hostFirewallerSuite) TestNewAPIRefusesNonMachineAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: s.unit.Tag()}
+	_, err := hostfirewaller.NewHostFirewallerAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *hostFirewallerSuite) TestIngressRules(c *gc.C) {
+	err := s.unit.OpenPort("tcp", 80)
+	c.Assert(err, jc.ErrorIsNil)
+	service, err := s.unit.Service()
+	c.Assert(err, jc.ErrorIsNil)
+	err = service.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.IngressRules(params.Entities{Entities: []params.Entity{
+		{Tag: s.machine.Tag().String()},
+		{Tag: names.NewMachineTag("42").String()},
+		{Tag: s.unit.Tag().String()},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.IngressRulesResults{
+		Results: []params.IngressRulesResult{{
+			Rules: []params.IngressRule{{
+				PortRange:   params.PortRange{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+				SourceCIDRs: []string{"10.0.0.0/8"},
+			}},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}},
+	})
+}
@@ -125,8 +125,8 @@ func ExtractRequestAttachment(req *http.Request, metaResult interface{}) (io.Rea
 	if err := checkContentType(part.Header, CTypeRaw); err != nil {
 		return nil, errors.Trace(err)
 	}
-	// We're not going to worry about verifying that the file matches the
-	// metadata (e.g. size, checksum).
+	// Verifying that the file matches the metadata (e.g. size,
+	// checksum) is left to the caller.
 	archive := part
 
 	// We are going to trust that there aren't any more attachments after
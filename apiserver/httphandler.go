@@ -30,6 +30,9 @@ type httpHandler struct {
 	strictValidation bool
 	// stateServerEnvOnly only validates the state server environment
 	stateServerEnvOnly bool
+	// maxUploadSize, if positive, is the largest request body
+	// in bytes that the handler accepts.
+	maxUploadSize int64
 }
 
 // httpStateWrapper reflects a state connection for a given http connection.
@@ -72,31 +75,66 @@ func (h *httpHandler) validateEnvironUUID(r *http.Request) (*httpStateWrapper, e
 // authenticate parses HTTP basic authentication and authorizes the
 // request by looking up the provided tag and password against state.
 func (h *httpStateWrapper) authenticate(r *http.Request) error {
+	tag, password, err := parseBasicAuth(r)
+	if err != nil {
+		return err
+	}
+	// Only allow users, not agents.
+	if _, err := names.ParseUserTag(tag); err != nil {
+		return common.ErrBadCreds
+	}
+	// Ensure the credentials are correct.
+	_, err = checkCreds(h.state, params.LoginRequest{
+		AuthTag:     tag,
+		Credentials: password,
+	})
+	return err
+}
+
+// authenticateAgent parses HTTP basic authentication and authorizes
+// the request as coming from a machine or unit agent. Machine agents
+// must also supply their provisioning nonce in the X-Juju-Nonce header.
+// It returns the agent's entity.
+func (h *httpStateWrapper) authenticateAgent(r *http.Request) (state.Entity, error) {
+	tag, password, err := parseBasicAuth(r)
+	if err != nil {
+		return nil, err
+	}
+	agentTag, err := names.ParseTag(tag)
+	if err != nil {
+		return nil, common.ErrBadCreds
+	}
+	switch agentTag.(type) {
+	case names.MachineTag, names.UnitTag:
+	default:
+		return nil, common.ErrBadCreds
+	}
+	return checkCreds(h.state, params.LoginRequest{
+		AuthTag:     tag,
+		Credentials: password,
+		Nonce:       r.Header.Get("X-Juju-Nonce"),
+	})
+}
+
+// parseBasicAuth returns the tag and password held in the request's
+// HTTP basic authentication header.
+func parseBasicAuth(r *http.Request) (tag, password string, err error) {
 	parts := strings.Fields(r.Header.Get("Authorization"))
 	if len(parts) != 2 || parts[0] != "Basic" {
 		// Invalid header format or no header provided.
-		return errors.New("invalid request format")
+		return "", "", errors.New("invalid request format")
 	}
 	// Challenge is a base64-encoded "tag:pass" string.
 	// See RFC 2617, Section 2.
 	challenge, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return errors.New("invalid request format")
+		return "", "", errors.New("invalid request format")
 	}
 	tagPass := strings.SplitN(string(challenge), ":", 2)
 	if len(tagPass) != 2 {
-		return errors.New("invalid request format")
+		return "", "", errors.New("invalid request format")
 	}
-	// Only allow users, not agents.
-	if _, err := names.ParseUserTag(tagPass[0]); err != nil {
-		return common.ErrBadCreds
-	}
-	// Ensure the credentials are correct.
-	_, err = checkCreds(h.state, params.LoginRequest{
-		AuthTag:     tagPass[0],
-		Credentials: tagPass[1],
-	})
-	return err
+	return tagPass[0], tagPass[1], nil
 }
 
 func (h *httpStateWrapper) cleanup() {
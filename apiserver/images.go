@@ -10,6 +10,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"path"
 	"strings"
 
@@ -24,9 +25,20 @@ import (
 	"github.com/juju/juju/state/imagestorage"
 )
 
+// imagesHandler is the base type for uploading and downloading
+// images over HTTPS via the API server.
+type imagesHandler struct {
+	httpHandler
+}
+
 // imagesDownloadHandler handles image download through HTTPS in the API server.
 type imagesDownloadHandler struct {
-	httpHandler
+	imagesHandler
+}
+
+// imagesUploadHandler handles image upload through HTTPS in the API server.
+type imagesUploadHandler struct {
+	imagesHandler
 }
 
 func (h *imagesDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,8 +61,36 @@ func (h *imagesDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (h *imagesUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Validate before authenticate because the authentication is dependent
+	// on the state connection that is determined during the validation.
+	stateWrapper, err := h.validateEnvironUUID(r)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer stateWrapper.cleanup()
+
+	if err := stateWrapper.authenticate(r); err != nil {
+		h.authError(w, h)
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		if err := h.processPost(r, stateWrapper.state); err != nil {
+			logger.Errorf("POST(%s) failed: %v", r.URL, err)
+			h.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.sendJSON(w, http.StatusOK, &params.ErrorResult{})
+	default:
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
+	}
+}
+
 // sendJSON sends a JSON-encoded response to the client.
-func (h *imagesDownloadHandler) sendJSON(w http.ResponseWriter, statusCode int, response *params.ErrorResult) error {
+func (h *imagesHandler) sendJSON(w http.ResponseWriter, statusCode int, response *params.ErrorResult) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	body, err := json.Marshal(response)
@@ -62,7 +102,7 @@ func (h *imagesDownloadHandler) sendJSON(w http.ResponseWriter, statusCode int,
 }
 
 // sendError sends a JSON-encoded error response.
-func (h *imagesDownloadHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+func (h *imagesHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
 	logger.Debugf("sending error: %v %v", statusCode, message)
 	err := common.ServerError(errors.New(message))
 	if err := h.sendJSON(w, statusCode, &params.ErrorResult{Error: err}); err != nil {
@@ -81,8 +121,14 @@ func (h *imagesDownloadHandler) processGet(r *http.Request, resp http.ResponseWr
 	// Get the image details from storage.
 	storage := st.ImageStorage()
 	metadata, imageReader, err := storage.Image(kind, series, arch)
-	// Not in storage, so go fetch it.
+	// Not in storage, so go fetch it, unless the environment
+	// has no access to the internet.
 	if errors.IsNotFound(err) {
+		if offline, cfgErr := isOffline(st); cfgErr != nil {
+			return errors.Trace(cfgErr)
+		} else if offline {
+			return errors.Errorf("%s image for %s/%s not uploaded and environment is offline", kind, series, arch)
+		}
 		metadata, imageReader, err = h.fetchAndCacheLxcImage(storage, envuuid, series, arch)
 		if err != nil {
 			return errors.Annotate(err, "error fetching and caching image")
@@ -184,3 +230,65 @@ func (h *imagesDownloadHandler) fetchAndCacheLxcImage(storage imagestorage.Stora
 
 	return storage.Image(string(instance.LXC), series, arch)
 }
+
+// processPost handles an image upload POST request after authentication,
+// storing the uploaded image in place of any image already stored for
+// the same kind, series and architecture.
+func (h *imagesUploadHandler) processPost(r *http.Request, st *state.State) error {
+	blockChecker := common.NewBlockChecker(st)
+	if err := blockChecker.ChangeAllowed(); err != nil {
+		return errors.Trace(err)
+	}
+	query := r.URL.Query()
+	kind, err := instance.ParseContainerType(query.Get(":kind"))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	series := query.Get(":series")
+	arch := query.Get(":arch")
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/x-tar-gz" {
+		return errors.Errorf("expected Content-Type: application/x-tar-gz, got: %v", contentType)
+	}
+
+	// Spool the image to disk, so its size and checksum
+	// are known before it is added to storage.
+	f, err := ioutil.TempFile("", "juju-image-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), r.Body)
+	if err != nil {
+		return errors.Annotate(err, "cannot read image")
+	}
+	if size == 0 {
+		return errors.New("no image uploaded")
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return errors.Trace(err)
+	}
+	metadata := &imagestorage.Metadata{
+		EnvUUID: st.EnvironUUID(),
+		Kind:    string(kind),
+		Series:  series,
+		Arch:    arch,
+		Size:    size,
+		SHA256:  fmt.Sprintf("%x", hash.Sum(nil)),
+	}
+	logger.Debugf("uploading image %+v to storage", metadata)
+	return st.ImageStorage().AddImage(f, metadata)
+}
+
+// isOffline reports whether the environment has been configured as
+// having no access to the internet.
+func isOffline(st *state.State) (bool, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return cfg.OfflineMode(), nil
+}
@@ -91,6 +91,48 @@ func (s *imageSuite) TestDownloadRejectsWrongEnvUUIDPath(c *gc.C) {
 // us to set the responses for things like image queries.
 var testRoundTripper = &jujutest.ProxyRoundTripper{}
 
+func (s *imageSuite) TestUploadRequiresAuth(c *gc.C) {
+	resp, err := s.sendRequest(c, "", "", "POST", s.uploadURL(c, "lxc", "trusty", "amd64").String(), s.archiveContentType, strings.NewReader(s.imageData))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
+
+func (s *imageSuite) TestUpload(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.uploadURL(c, "lxc", "trusty", "amd64").String(), s.archiveContentType, strings.NewReader(s.imageData))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+
+	metadata, data := s.getImageFromStorage(c, s.State, "lxc", "trusty", "amd64")
+	c.Assert(metadata.Size, gc.Equals, int64(len(s.imageData)))
+	c.Assert(metadata.SHA256, gc.Equals, s.imageChecksum)
+	c.Assert(string(data), gc.Equals, s.imageData)
+
+	response, err := s.downloadRequest(c, s.imageURL(c, "lxc", "trusty", "amd64"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.testDownload(c, response)
+}
+
+func (s *imageSuite) TestUploadWrongContentType(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.uploadURL(c, "lxc", "trusty", "amd64").String(), "application/zip", strings.NewReader(s.imageData))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected Content-Type: application/x-tar-gz, got: application/zip")
+}
+
+func (s *imageSuite) TestUploadEmpty(c *gc.C) {
+	resp, err := s.authRequest(c, "POST", s.uploadURL(c, "lxc", "trusty", "amd64").String(), s.archiveContentType, strings.NewReader(""))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "no image uploaded")
+}
+
+func (s *imageSuite) TestDownloadOfflineNotUploaded(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"offline-mode": true}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp, err := s.downloadRequest(c, s.imageURL(c, "lxc", "trusty", "amd64"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusInternalServerError, ".*lxc image for trusty/amd64 not uploaded and environment is offline")
+}
+
 func useTestImageData(files map[string]string) {
 	if files != nil {
 		testRoundTripper.Sub = jujutest.NewCannedRoundTripper(files, nil)
@@ -212,6 +254,12 @@ func (s *imageSuite) imageURL(c *gc.C, kind, series, arch string) *url.URL {
 	return uri
 }
 
+func (s *imageSuite) uploadURL(c *gc.C, kind, series, arch string) *url.URL {
+	uri := s.baseURL(c)
+	uri.Path = fmt.Sprintf("/environment/%s/images/%s/%s/%s", s.envUUID, kind, series, arch)
+	return uri
+}
+
 func (s *imageSuite) assertErrorResponse(c *gc.C, resp *http.Response, expCode int, expError string) {
 	body := assertResponse(c, resp, expCode, "application/json")
 	err := jsonImageResponse(c, body).Error
@@ -0,0 +1,287 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package instancemetadata provides the API used by clients to consult
+// the instance types and images offered by an environment's provider,
+// as cached by the state server, and by state server agents to refresh
+// them.
+package instancemetadata
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("InstanceMetadata", 1, NewInstanceMetadataAPI)
+}
+
+// InstanceMetadataAPI implements the InstanceMetadata facade.
+type InstanceMetadataAPI struct {
+	*common.EnvironWatcher
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewInstanceMetadataAPI creates a new server-side InstanceMetadata
+// facade.
+func NewInstanceMetadataAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*InstanceMetadataAPI, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &InstanceMetadataAPI{
+		EnvironWatcher: common.NewEnvironWatcher(st, resources, authorizer),
+		st:             st,
+		authorizer:     authorizer,
+	}, nil
+}
+
+// InstanceMetadata returns the cached instance types and images offered
+// by the environment's provider.
+func (api *InstanceMetadataAPI) InstanceMetadata() (params.InstanceMetadata, error) {
+	metadata, err := api.st.InstanceMetadata()
+	if err != nil {
+		return params.InstanceMetadata{}, errors.Trace(err)
+	}
+	result := params.InstanceMetadata{
+		InstanceTypes: make([]params.InstanceType, len(metadata.InstanceTypes)),
+		Images:        make([]params.CloudImage, len(metadata.Images)),
+		Updated:       metadata.Updated,
+	}
+	for i, itype := range metadata.InstanceTypes {
+		result.InstanceTypes[i] = paramsInstanceType(itype)
+	}
+	for i, image := range metadata.Images {
+		result.Images[i] = params.CloudImage{
+			Id:       image.Id,
+			Series:   image.Series,
+			Arch:     image.Arch,
+			VirtType: image.VirtType,
+		}
+	}
+	return result, nil
+}
+
+// UpdateInstanceMetadata replaces the cached instance types and images
+// offered by the environment's provider. Only state server agents may
+// update them.
+func (api *InstanceMetadataAPI) UpdateInstanceMetadata(args params.InstanceMetadata) error {
+	if !api.authorizer.AuthEnvironManager() {
+		return common.ErrPerm
+	}
+	metadata := state.InstanceMetadata{
+		InstanceTypes: make([]state.InstanceType, len(args.InstanceTypes)),
+		Images:        make([]state.CloudImage, len(args.Images)),
+		Updated:       args.Updated,
+	}
+	for i, itype := range args.InstanceTypes {
+		metadata.InstanceTypes[i] = state.InstanceType{
+			Name:     itype.Name,
+			Arches:   itype.Arches,
+			CpuCores: itype.CpuCores,
+			CpuPower: itype.CpuPower,
+			Mem:      itype.Mem,
+			RootDisk: itype.RootDisk,
+			Cost:     itype.Cost,
+			VirtType: itype.VirtType,
+			Tags:     itype.Tags,
+			Gpus:     itype.Gpus,
+			GpuType:  itype.GpuType,
+		}
+	}
+	for i, image := range args.Images {
+		metadata.Images[i] = state.CloudImage{
+			Id:       image.Id,
+			Series:   image.Series,
+			Arch:     image.Arch,
+			VirtType: image.VirtType,
+		}
+	}
+	return api.st.UpdateInstanceMetadata(metadata)
+}
+
+// FindInstanceSpecs returns the instance type and image which would be
+// chosen for machines of the given series and constraints, using the
+// cached instance types and images rather than calling the provider.
+// Machines with no series given have the environment's default series.
+func (api *InstanceMetadataAPI) FindInstanceSpecs(args params.InstanceSpecArgs) (params.InstanceSpecResults, error) {
+	results := params.InstanceSpecResults{
+		Results: make([]params.InstanceSpecResult, len(args.Specs)),
+	}
+	metadata, err := api.st.InstanceMetadata()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	itypes := make([]instances.InstanceType, len(metadata.InstanceTypes))
+	for i, itype := range metadata.InstanceTypes {
+		itypes[i] = instanceType(itype)
+	}
+	for i, arg := range args.Specs {
+		if arg.Series == "" {
+			arg.Series = config.PreferredSeries(envConfig)
+		}
+		spec, err := findInstanceSpec(metadata.Images, itypes, arg)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		itype := paramsInstanceType(stateInstanceType(spec.InstanceType))
+		results.Results[i].InstanceType = &itype
+		results.Results[i].ImageId = spec.Image.Id
+	}
+	return results, nil
+}
+
+// EstimateCosts returns the estimated hourly cost of deploying each of
+// the given services, with a new machine for each unit, using the
+// cached instance types and the provider's prices for them.
+func (api *InstanceMetadataAPI) EstimateCosts(args params.ServiceCostArgs) (params.ServiceCostResults, error) {
+	results := params.ServiceCostResults{
+		Results: make([]params.ServiceCost, len(args.Services)),
+	}
+	metadata, err := api.st.InstanceMetadata()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	envConfig, err := api.st.EnvironConfig()
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	provider, err := environs.Provider(envConfig.Type())
+	if err != nil {
+		return results, errors.Trace(err)
+	}
+	estimator, ok := provider.(environs.CostEstimator)
+	if !ok {
+		return results, errors.NotSupportedf("cost estimation for %q provider", envConfig.Type())
+	}
+	itypes := make([]instances.InstanceType, len(metadata.InstanceTypes))
+	for i, itype := range metadata.InstanceTypes {
+		itypes[i] = instanceType(itype)
+	}
+	for i, arg := range args.Services {
+		results.Results[i].ServiceName = arg.ServiceName
+		results.Results[i].NumUnits = arg.NumUnits
+		series := arg.Series
+		if series == "" {
+			series = config.PreferredSeries(envConfig)
+		}
+		spec, err := findInstanceSpec(metadata.Images, itypes, params.InstanceSpecArg{
+			Series:      series,
+			Constraints: arg.Constraints,
+		})
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		unitCost, currency := estimator.HourlyCost(spec.InstanceType)
+		results.Results[i].InstanceType = spec.InstanceType.Name
+		results.Results[i].UnitCost = unitCost
+		results.Results[i].HourlyCost = unitCost * float64(arg.NumUnits)
+		results.Results[i].Currency = currency
+	}
+	return results, nil
+}
+
+// findInstanceSpec returns the cheapest instance type, and an image for
+// it, satisfying the series and constraints given.
+func findInstanceSpec(images []state.CloudImage, itypes []instances.InstanceType, arg params.InstanceSpecArg) (*instances.InstanceSpec, error) {
+	var possibleImages []instances.Image
+	var arch string
+	if arg.Constraints.Arch != nil {
+		arch = *arg.Constraints.Arch
+	}
+	arches := set.NewStrings()
+	for _, image := range images {
+		if image.Series != arg.Series {
+			continue
+		}
+		if arch != "" && image.Arch != arch {
+			continue
+		}
+		possibleImages = append(possibleImages, instances.Image{
+			Id:       image.Id,
+			Arch:     image.Arch,
+			VirtType: image.VirtType,
+		})
+		arches.Add(image.Arch)
+	}
+	if len(possibleImages) == 0 {
+		if arch != "" {
+			return nil, errors.NotFoundf("%s images for series %q", arch, arg.Series)
+		}
+		return nil, errors.NotFoundf("images for series %q", arg.Series)
+	}
+	return instances.FindInstanceSpec(possibleImages, &instances.InstanceConstraint{
+		Series:      arg.Series,
+		Arches:      arches.SortedValues(),
+		Constraints: arg.Constraints,
+	}, itypes)
+}
+
+func instanceType(itype state.InstanceType) instances.InstanceType {
+	result := instances.InstanceType{
+		Id:       itype.Name,
+		Name:     itype.Name,
+		Arches:   itype.Arches,
+		CpuCores: itype.CpuCores,
+		CpuPower: itype.CpuPower,
+		Mem:      itype.Mem,
+		RootDisk: itype.RootDisk,
+		Cost:     itype.Cost,
+		Tags:     itype.Tags,
+		Gpus:     itype.Gpus,
+		GpuType:  itype.GpuType,
+	}
+	if itype.VirtType != "" {
+		virtType := itype.VirtType
+		result.VirtType = &virtType
+	}
+	return result
+}
+
+func stateInstanceType(itype instances.InstanceType) state.InstanceType {
+	result := state.InstanceType{
+		Name:     itype.Name,
+		Arches:   itype.Arches,
+		CpuCores: itype.CpuCores,
+		CpuPower: itype.CpuPower,
+		Mem:      itype.Mem,
+		RootDisk: itype.RootDisk,
+		Cost:     itype.Cost,
+		Tags:     itype.Tags,
+		Gpus:     itype.Gpus,
+		GpuType:  itype.GpuType,
+	}
+	if itype.VirtType != nil {
+		result.VirtType = *itype.VirtType
+	}
+	return result
+}
+
+func paramsInstanceType(itype state.InstanceType) params.InstanceType {
+	return params.InstanceType{
+		Name:     itype.Name,
+		Arches:   itype.Arches,
+		CpuCores: itype.CpuCores,
+		CpuPower: itype.CpuPower,
+		Mem:      itype.Mem,
+		RootDisk: itype.RootDisk,
+		Cost:     itype.Cost,
+		VirtType: itype.VirtType,
+		Tags:     itype.Tags,
+		Gpus:     itype.Gpus,
+		GpuType:  itype.GpuType,
+	}
+}
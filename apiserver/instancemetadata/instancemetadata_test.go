@@ -0,0 +1,213 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancemetadata_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/instancemetadata"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/constraints"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type instanceMetadataSuite struct {
+	jujutesting.JujuConnSuite
+
+	resources *common.Resources
+	updated   time.Time
+}
+
+var _ = gc.Suite(&instanceMetadataSuite{})
+
+func (s *instanceMetadataSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+	s.updated = time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *instanceMetadataSuite) newAPI(c *gc.C, tag names.Tag, manager bool) *instancemetadata.InstanceMetadataAPI {
+	api, err := instancemetadata.NewInstanceMetadataAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag:            tag,
+		EnvironManager: manager,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *instanceMetadataSuite) cacheMetadata(c *gc.C) {
+	err := s.State.UpdateInstanceMetadata(state.InstanceMetadata{
+		InstanceTypes: []state.InstanceType{{
+			Name:     "small",
+			Arches:   []string{"amd64", "i386"},
+			CpuCores: 1,
+			Mem:      1024,
+			Cost:     10,
+		}, {
+			Name:     "large",
+			Arches:   []string{"amd64"},
+			CpuCores: 4,
+			Mem:      8192,
+			Cost:     40,
+		}},
+		Images: []state.CloudImage{
+			{Id: "trusty-amd64", Series: "trusty", Arch: "amd64"},
+			{Id: "trusty-i386", Series: "trusty", Arch: "i386"},
+		},
+		Updated: s.updated,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *instanceMetadataSuite) TestNewAPIRefusesUnitAgent(c *gc.C) {
+	_, err := instancemetadata.NewInstanceMetadataAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewUnitTag("mysql/0"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *instanceMetadataSuite) TestInstanceMetadataNotCached(c *gc.C) {
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	_, err := api.InstanceMetadata()
+	c.Assert(err, gc.ErrorMatches, "instance metadata not found")
+}
+
+func (s *instanceMetadataSuite) TestUpdateInstanceMetadata(c *gc.C) {
+	api := s.newAPI(c, names.NewMachineTag("0"), true)
+	metadata := params.InstanceMetadata{
+		InstanceTypes: []params.InstanceType{{
+			Name:     "small",
+			Arches:   []string{"amd64"},
+			CpuCores: 1,
+			Mem:      1024,
+			Cost:     10,
+			VirtType: "hvm",
+		}},
+		Images: []params.CloudImage{
+			{Id: "ami-123", Series: "trusty", Arch: "amd64", VirtType: "hvm"},
+		},
+		Updated: s.updated,
+	}
+	err := api.UpdateInstanceMetadata(metadata)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.InstanceMetadata()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Updated.Equal(s.updated), jc.IsTrue)
+	c.Assert(result.InstanceTypes, jc.DeepEquals, metadata.InstanceTypes)
+	c.Assert(result.Images, jc.DeepEquals, metadata.Images)
+}
+
+func (s *instanceMetadataSuite) TestUpdateInstanceMetadataRefusesClient(c *gc.C) {
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	err := api.UpdateInstanceMetadata(params.InstanceMetadata{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.State.InstanceMetadata()
+	c.Assert(err, gc.ErrorMatches, "instance metadata not found")
+}
+
+func (s *instanceMetadataSuite) TestFindInstanceSpecsNotCached(c *gc.C) {
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	_, err := api.FindInstanceSpecs(params.InstanceSpecArgs{
+		Specs: []params.InstanceSpecArg{{Series: "trusty"}},
+	})
+	c.Assert(err, gc.ErrorMatches, "instance metadata not found")
+}
+
+func (s *instanceMetadataSuite) TestFindInstanceSpecs(c *gc.C) {
+	s.cacheMetadata(c)
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	results, err := api.FindInstanceSpecs(params.InstanceSpecArgs{
+		Specs: []params.InstanceSpecArg{
+			{Series: "trusty"},
+			{Series: "trusty", Constraints: constraints.MustParse("mem=4G")},
+			{Series: "trusty", Constraints: constraints.MustParse("arch=i386")},
+			{Series: "trusty", Constraints: constraints.MustParse("mem=64G")},
+			{Series: "precise"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 5)
+
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].InstanceType.Name, gc.Equals, "small")
+	c.Assert(results.Results[0].InstanceType.Cost, gc.Equals, uint64(10))
+	c.Assert(results.Results[0].ImageId, gc.Equals, "trusty-amd64")
+
+	c.Assert(results.Results[1].Error, gc.IsNil)
+	c.Assert(results.Results[1].InstanceType.Name, gc.Equals, "large")
+	c.Assert(results.Results[1].InstanceType.Cost, gc.Equals, uint64(40))
+
+	c.Assert(results.Results[2].Error, gc.IsNil)
+	c.Assert(results.Results[2].InstanceType.Name, gc.Equals, "small")
+	c.Assert(results.Results[2].ImageId, gc.Equals, "trusty-i386")
+
+	c.Assert(results.Results[3].Error, gc.ErrorMatches, `no instance types in .*matching constraints "mem=65536M"`)
+	c.Assert(results.Results[3].InstanceType, gc.IsNil)
+
+	c.Assert(results.Results[4].Error, gc.ErrorMatches, `images for series "precise" not found`)
+	c.Assert(results.Results[4].Error.Code, gc.Equals, params.CodeNotFound)
+}
+
+func (s *instanceMetadataSuite) TestFindInstanceSpecsDefaultSeries(c *gc.C) {
+	s.cacheMetadata(c)
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"default-series": "trusty"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	results, err := api.FindInstanceSpecs(params.InstanceSpecArgs{
+		Specs: []params.InstanceSpecArg{{}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].ImageId, gc.Equals, "trusty-amd64")
+}
+
+func (s *instanceMetadataSuite) TestEstimateCosts(c *gc.C) {
+	s.cacheMetadata(c)
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	results, err := api.EstimateCosts(params.ServiceCostArgs{
+		Services: []params.ServiceCostArg{
+			{ServiceName: "wordpress", Series: "trusty", NumUnits: 3},
+			{ServiceName: "mysql", Series: "trusty", Constraints: constraints.MustParse("mem=4G"), NumUnits: 1},
+			{ServiceName: "mongodb", Series: "precise", NumUnits: 1},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	// Dummy instance type costs are in US cents per hour.
+	smallCost := 0.1
+	c.Assert(results.Results[0], jc.DeepEquals, params.ServiceCost{
+		ServiceName:  "wordpress",
+		InstanceType: "small",
+		NumUnits:     3,
+		UnitCost:     smallCost,
+		HourlyCost:   smallCost * 3,
+		Currency:     "USD",
+	})
+	c.Assert(results.Results[1], jc.DeepEquals, params.ServiceCost{
+		ServiceName:  "mysql",
+		InstanceType: "large",
+		NumUnits:     1,
+		UnitCost:     0.4,
+		HourlyCost:   0.4,
+		Currency:     "USD",
+	})
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `images for series "precise" not found`)
+}
+
+func (s *instanceMetadataSuite) TestEstimateCostsNotCached(c *gc.C) {
+	api := s.newAPI(c, s.AdminUserTag(c), false)
+	_, err := api.EstimateCosts(params.ServiceCostArgs{
+		Services: []params.ServiceCostArg{{ServiceName: "wordpress", NumUnits: 1}},
+	})
+	c.Assert(err, gc.ErrorMatches, "instance metadata not found")
+}
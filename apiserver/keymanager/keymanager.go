@@ -5,7 +5,10 @@ package keymanager
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -40,6 +43,7 @@ type KeyManagerAPI struct {
 	state      *state.State
 	resources  *common.Resources
 	authorizer common.Authorizer
+	owner      names.UserTag
 	canRead    func(string) bool
 	canWrite   func(string) bool
 	check      *common.BlockChecker
@@ -57,41 +61,56 @@ func NewKeyManagerAPI(st *state.State, resources *common.Resources, authorizer c
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	// For gccgo interface comparisons, we need a Tag.
-	owner := names.Tag(env.Owner())
-	// TODO(wallyworld) - replace stub with real canRead function
-	// For now, only admins can read authorised ssh keys.
-	canRead := func(user string) bool {
+	owner := env.Owner()
+	// Each environment user has their own key namespace, which only
+	// they and the environment owner can read and write. Machine
+	// agents can read and write the juju-system-key.
+	canAccess := func(user string) bool {
 		// Are we a machine agent operating as the system identity?
 		if user == config.JujuSystemKey {
 			_, ismachinetag := authorizer.GetAuthTag().(names.MachineTag)
 			return ismachinetag
 		}
-		return authorizer.GetAuthTag() == owner
-	}
-	// TODO(wallyworld) - replace stub with real canWrite function
-	// For now, only admins can write authorised ssh keys for users.
-	// Machine agents can write the juju-system-key.
-	canWrite := func(user string) bool {
-		// Are we a machine agent writing the Juju system key.
-		if user == config.JujuSystemKey {
-			_, ismachinetag := authorizer.GetAuthTag().(names.MachineTag)
-			return ismachinetag
+		authTag, isusertag := authorizer.GetAuthTag().(names.UserTag)
+		if !isusertag || !names.IsValidUser(user) {
+			return false
 		}
-		// No point looking to see if the user exists as we are not
-		// yet storing keys on the user.
-		return authorizer.GetAuthTag() == owner
+		userTag := names.NewUserTag(user)
+		if _, err := st.EnvironmentUser(userTag); err != nil {
+			return false
+		}
+		return authTag.Username() == owner.Username() || authTag.Username() == userTag.Username()
 	}
 	return &KeyManagerAPI{
 		state:      st,
 		resources:  resources,
 		authorizer: authorizer,
-		canRead:    canRead,
-		canWrite:   canWrite,
+		owner:      owner,
+		canRead:    canAccess,
+		canWrite:   canAccess,
 		check:      common.NewBlockChecker(st),
 	}, nil
 }
 
+// hasEnvironKeys reports whether the keys of the given user include
+// the environment's authorised keys, held in its config: those of the
+// Juju system key and of the environment owner. The keys of all other
+// users are held only in their key namespaces.
+func (api *KeyManagerAPI) hasEnvironKeys(user string) bool {
+	if user == config.JujuSystemKey {
+		return true
+	}
+	return names.NewUserTag(user).Username() == api.owner.Username()
+}
+
+// inUserNamespace reports whether the keys added or imported with the
+// given arguments are held in the user's key namespace rather than
+// the environment config: keys that expire, and keys of users other
+// than the environment owner.
+func (api *KeyManagerAPI) inUserNamespace(arg params.ModifyUserSSHKeys) bool {
+	return arg.Expires != nil || !api.hasEnvironKeys(arg.User)
+}
+
 // ListKeys returns the authorised ssh keys for the specified users.
 func (api *KeyManagerAPI) ListKeys(arg params.ListSSHKeys) (params.StringsResults, error) {
 	if len(arg.Entities.Entities) == 0 {
@@ -99,7 +118,7 @@ func (api *KeyManagerAPI) ListKeys(arg params.ListSSHKeys) (params.StringsResult
 	}
 	results := make([]params.StringsResult, len(arg.Entities.Entities))
 
-	// For now, authorised keys are global, common to all users.
+	// The environment's authorised keys are held in its config.
 	var keyInfo []string
 	cfg, configErr := api.state.EnvironConfig()
 	if configErr == nil {
@@ -107,21 +126,51 @@ func (api *KeyManagerAPI) ListKeys(arg params.ListSSHKeys) (params.StringsResult
 		keyInfo = parseKeys(keys, arg.Mode)
 	}
 
+	now := time.Now()
 	for i, entity := range arg.Entities.Entities {
 		// NOTE: entity.Tag isn't a tag, but a username.
 		if !api.canRead(entity.Tag) {
 			results[i].Error = common.ServerError(common.ErrPerm)
 			continue
 		}
-		// All keys are global, no need to look up the user.
-		if configErr == nil {
-			results[i].Result = keyInfo
+		var userKeyInfo []string
+		if api.hasEnvironKeys(entity.Tag) {
+			if configErr != nil {
+				results[i].Error = common.ServerError(configErr)
+				continue
+			}
+			userKeyInfo = append(userKeyInfo, keyInfo...)
 		}
-		results[i].Error = common.ServerError(configErr)
+		if entity.Tag != config.JujuSystemKey {
+			userKeys, err := api.state.SSHKeys(names.NewUserTag(entity.Tag))
+			if err != nil {
+				results[i].Error = common.ServerError(err)
+				continue
+			}
+			userKeyInfo = append(userKeyInfo, parseUserKeys(userKeys, arg.Mode, now)...)
+		}
+		results[i].Result = userKeyInfo
 	}
 	return params.StringsResults{Results: results}, nil
 }
 
+// parseUserKeys is like parseKeys, for keys held in a user's key
+// namespace; in Fingerprints mode, when each key expires is shown too.
+func parseUserKeys(keys []*state.SSHKey, mode ssh.ListMode, now time.Time) (keyInfo []string) {
+	for _, key := range keys {
+		info := parseKeys([]string{key.Key()}, mode)[0]
+		if expires, ok := key.Expires(); ok && mode == ssh.Fingerprints {
+			when := "expires"
+			if key.Expired(now) {
+				when = "expired"
+			}
+			info += fmt.Sprintf(" [%s %s]", when, expires.Format(time.RFC3339))
+		}
+		keyInfo = append(keyInfo, info)
+	}
+	return keyInfo
+}
+
 func parseKeys(keys []string, mode ssh.ListMode) (keyInfo []string) {
 	for _, key := range keys {
 		fingerprint, comment, err := ssh.KeyFingerprint(key)
@@ -189,8 +238,22 @@ func (api *KeyManagerAPI) AddKeys(arg params.ModifyUserSSHKeys) (params.ErrorRes
 	if !api.canWrite(arg.User) {
 		return params.ErrorResults{}, common.ServerError(common.ErrPerm)
 	}
+	if arg.User == config.JujuSystemKey && arg.Expires != nil {
+		return params.ErrorResults{}, common.ServerError(fmt.Errorf("%s keys cannot expire", config.JujuSystemKey))
+	}
+
+	if api.inUserNamespace(arg) {
+		environFingerprints, err := api.environFingerprints(arg.User)
+		if err != nil {
+			return params.ErrorResults{}, common.ServerError(err)
+		}
+		for i, key := range arg.Keys {
+			err := api.addUserKey(arg.User, key, arg.Expires, environFingerprints)
+			result.Results[i].Error = common.ServerError(err)
+		}
+		return result, nil
+	}
 
-	// For now, authorised keys are global, common to all users.
 	sshKeys, currentFingerprints, err := api.currentKeyDataForAdd()
 	if err != nil {
 		return params.ErrorResults{}, common.ServerError(fmt.Errorf("reading current key data: %v", err))
@@ -217,6 +280,40 @@ func (api *KeyManagerAPI) AddKeys(arg params.ModifyUserSSHKeys) (params.ErrorRes
 	return result, nil
 }
 
+// environFingerprints returns the fingerprints of the environment's
+// authorised keys, if they are among the given user's keys.
+func (api *KeyManagerAPI) environFingerprints(user string) (set.Strings, error) {
+	if !api.hasEnvironKeys(user) {
+		return nil, nil
+	}
+	_, fingerprints, err := api.currentKeyDataForAdd()
+	if err != nil {
+		return nil, fmt.Errorf("reading current key data: %v", err)
+	}
+	return fingerprints, nil
+}
+
+// addUserKey adds the given key to the given user's key namespace,
+// unless it is one of the given environment keys.
+func (api *KeyManagerAPI) addUserKey(user, key string, expires *time.Time, environFingerprints set.Strings) error {
+	fingerprint, _, err := ssh.KeyFingerprint(key)
+	if err != nil {
+		return fmt.Errorf("invalid ssh key: %s", key)
+	}
+	if environFingerprints.Contains(fingerprint) {
+		return fmt.Errorf("duplicate ssh key: %s", key)
+	}
+	var when time.Time
+	if expires != nil {
+		when = *expires
+	}
+	_, err = api.state.AddSSHKey(names.NewUserTag(user), key, when)
+	if errors.IsAlreadyExists(errors.Cause(err)) {
+		return fmt.Errorf("duplicate ssh key: %s", key)
+	}
+	return err
+}
+
 type importedSSHKey struct {
 	key         string
 	fingerprint string
@@ -226,10 +323,59 @@ type importedSSHKey struct {
 //  Override for testing
 var RunSSHImportId = runSSHImportId
 
+// keyImportURLs holds, for each ssh key id prefix the API server
+// imports keys for itself, the URL of the public keys of the user the
+// rest of the id names. Other key ids are resolved by ssh-import-id.
+var keyImportURLs = map[string]string{
+	"gh": "https://github.com/%s.keys",
+}
+
 func runSSHImportId(keyId string) (string, error) {
+	if i := strings.Index(keyId, ":"); i > 0 {
+		if format, ok := keyImportURLs[keyId[:i]]; ok {
+			return importKeysFromURL(keyId, fmt.Sprintf(format, keyId[i+1:]))
+		}
+	}
 	return utils.RunCommand("ssh-import-id", "-o", "-", keyId)
 }
 
+// Override for testing
+var FetchSSHKeys = fetchSSHKeys
+
+func fetchSSHKeys(url string) (string, error) {
+	resp, err := utils.GetValidatingHTTPClient().Get(url)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("cannot get %s: %s", url, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot read %s", url)
+	}
+	return string(data), nil
+}
+
+// importKeysFromURL returns the public keys at the given URL for the
+// given key id. Keys without a comment are given the key id as one,
+// as ssh-import-id does, so they can be told apart and deleted.
+func importKeysFromURL(keyId, url string) (string, error) {
+	data, err := FetchSSHKeys(url)
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot import ssh keys for %s", keyId)
+	}
+	var keys []string
+	for _, key := range ssh.SplitAuthorisedKeys(data) {
+		if len(strings.Fields(key)) == 2 {
+			key += " " + keyId
+		}
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, "\n"), nil
+}
+
 // runSSHKeyImport uses ssh-import-id to find the ssh keys for the specified key ids.
 func runSSHKeyImport(keyIds []string) []importedSSHKey {
 	// zero-length slice to force append to overwrite from the start
@@ -279,8 +425,27 @@ func (api *KeyManagerAPI) ImportKeys(arg params.ModifyUserSSHKeys) (params.Error
 	if !api.canWrite(arg.User) {
 		return params.ErrorResults{}, common.ServerError(common.ErrPerm)
 	}
+	if arg.User == config.JujuSystemKey && arg.Expires != nil {
+		return params.ErrorResults{}, common.ServerError(fmt.Errorf("%s keys cannot expire", config.JujuSystemKey))
+	}
+
+	if api.inUserNamespace(arg) {
+		environFingerprints, err := api.environFingerprints(arg.User)
+		if err != nil {
+			return params.ErrorResults{}, common.ServerError(err)
+		}
+		importedKeyInfo := runSSHKeyImport(arg.Keys)
+		result.Results = make([]params.ErrorResult, len(importedKeyInfo))
+		for i, keyInfo := range importedKeyInfo {
+			err := keyInfo.err
+			if err == nil {
+				err = api.addUserKey(arg.User, keyInfo.key, arg.Expires, environFingerprints)
+			}
+			result.Results[i].Error = common.ServerError(err)
+		}
+		return result, nil
+	}
 
-	// For now, authorised keys are global, common to all users.
 	sshKeys, currentFingerprints, err := api.currentKeyDataForAdd()
 	if err != nil {
 		return params.ErrorResults{}, common.ServerError(fmt.Errorf("reading current key data: %v", err))
@@ -338,6 +503,29 @@ func (api *KeyManagerAPI) currentKeyDataForDelete() (
 	return keys, invalidKeys, comments, nil
 }
 
+// currentUserKeyData gathers the keys in the user's key namespace,
+// indexed by fingerprint, and their fingerprints indexed by comment.
+func (api *KeyManagerAPI) currentUserKeyData(user string) (
+	keys map[string]string, comments map[string]string, err error) {
+
+	keys = make(map[string]string)
+	comments = make(map[string]string)
+	if user == config.JujuSystemKey {
+		return keys, comments, nil
+	}
+	userKeys, err := api.state.SSHKeys(names.NewUserTag(user))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading current key data: %v", err)
+	}
+	for _, key := range userKeys {
+		keys[key.Fingerprint()] = key.Key()
+		if _, comment, err := ssh.KeyFingerprint(key.Key()); err == nil && comment != "" {
+			comments[comment] = key.Fingerprint()
+		}
+	}
+	return keys, comments, nil
+}
+
 // DeleteKeys deletes the authorised ssh keys for the specified user.
 func (api *KeyManagerAPI) DeleteKeys(arg params.ModifyUserSSHKeys) (params.ErrorResults, error) {
 	if err := api.check.ChangeAllowed(); err != nil {
@@ -354,39 +542,68 @@ func (api *KeyManagerAPI) DeleteKeys(arg params.ModifyUserSSHKeys) (params.Error
 		return params.ErrorResults{}, common.ServerError(common.ErrPerm)
 	}
 
-	sshKeys, invalidKeys, keyComments, err := api.currentKeyDataForDelete()
+	hasEnvironKeys := api.hasEnvironKeys(arg.User)
+	var sshKeys map[string]string
+	var invalidKeys []string
+	var keyComments map[string]string
+	if hasEnvironKeys {
+		var err error
+		sshKeys, invalidKeys, keyComments, err = api.currentKeyDataForDelete()
+		if err != nil {
+			return params.ErrorResults{}, common.ServerError(fmt.Errorf("reading current key data: %v", err))
+		}
+	}
+	userKeys, userKeyComments, err := api.currentUserKeyData(arg.User)
 	if err != nil {
-		return params.ErrorResults{}, common.ServerError(fmt.Errorf("reading current key data: %v", err))
+		return params.ErrorResults{}, common.ServerError(err)
 	}
 
 	// We keep all existing invalid keys.
 	keysToWrite := invalidKeys
 
-	// Find the keys corresponding to the specified key fingerprints or comments.
+	// Find the keys corresponding to the specified key fingerprints or
+	// comments, among the environment's keys and then the user's own.
+	var userKeysToRemove []string
 	for i, keyId := range arg.Keys {
-		// assume keyId may be a fingerprint
-		fingerprint := keyId
-		_, ok := sshKeys[keyId]
-		if !ok {
-			// keyId is a comment
-			fingerprint, ok = keyComments[keyId]
+		if fingerprint, ok := findKey(keyId, sshKeys, keyComments); ok {
+			// We found the key to delete so remove it from those we wish to keep.
+			delete(sshKeys, fingerprint)
+			continue
 		}
-		if !ok {
-			result.Results[i].Error = common.ServerError(fmt.Errorf("invalid ssh key: %s", keyId))
+		if fingerprint, ok := findKey(keyId, userKeys, userKeyComments); ok {
+			userKeysToRemove = append(userKeysToRemove, fingerprint)
+			continue
 		}
-		// We found the key to delete so remove it from those we wish to keep.
-		delete(sshKeys, fingerprint)
+		result.Results[i].Error = common.ServerError(fmt.Errorf("invalid ssh key: %s", keyId))
 	}
-	for _, key := range sshKeys {
-		keysToWrite = append(keysToWrite, key)
+
+	if hasEnvironKeys {
+		for _, key := range sshKeys {
+			keysToWrite = append(keysToWrite, key)
+		}
+		if len(keysToWrite) == 0 {
+			return params.ErrorResults{}, common.ServerError(fmt.Errorf("cannot delete all keys"))
+		}
+		err = api.writeSSHKeys(keysToWrite)
+		if err != nil {
+			return params.ErrorResults{}, common.ServerError(err)
+		}
 	}
-	if len(keysToWrite) == 0 {
-		return params.ErrorResults{}, common.ServerError(fmt.Errorf("cannot delete all keys"))
+	for _, fingerprint := range userKeysToRemove {
+		err := api.state.RemoveSSHKey(names.NewUserTag(arg.User), fingerprint)
+		if err != nil && !errors.IsNotFound(err) {
+			return params.ErrorResults{}, common.ServerError(err)
+		}
 	}
+	return result, nil
+}
 
-	err = api.writeSSHKeys(keysToWrite)
-	if err != nil {
-		return params.ErrorResults{}, common.ServerError(err)
+// findKey returns the fingerprint of the key identified by keyId, which
+// may be either a fingerprint in keys or a comment in comments.
+func findKey(keyId string, keys, comments map[string]string) (string, bool) {
+	if _, ok := keys[keyId]; ok {
+		return keyId, true
 	}
-	return result, nil
+	fingerprint, ok := comments[keyId]
+	return fingerprint, ok
 }
@@ -6,6 +6,7 @@ package keymanager_test
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -107,7 +108,7 @@ func (s *keyManagerSuite) TestListKeys(c *gc.C) {
 	c.Assert(results, gc.DeepEquals, params.StringsResults{
 		Results: []params.StringsResult{
 			{Result: []string{key1, key2, "Invalid key: bad key"}},
-			{Result: []string{key1, key2, "Invalid key: bad key"}},
+			{Error: apiservertesting.ErrUnauthorized},
 		},
 	})
 }
@@ -282,7 +283,6 @@ func (s *keyManagerSuite) assertInvalidUserOperation(c *gc.C, runTestLogic func(
 }
 
 func (s *keyManagerSuite) TestAddKeysInvalidUser(c *gc.C) {
-	c.Skip("no user validation done yet")
 	s.assertInvalidUserOperation(c, func(args params.ModifyUserSSHKeys) error {
 		_, err := s.keymanager.AddKeys(args)
 		return err
@@ -290,7 +290,6 @@ func (s *keyManagerSuite) TestAddKeysInvalidUser(c *gc.C) {
 }
 
 func (s *keyManagerSuite) TestDeleteKeysInvalidUser(c *gc.C) {
-	c.Skip("no user validation done yet")
 	s.assertInvalidUserOperation(c, func(args params.ModifyUserSSHKeys) error {
 		_, err := s.keymanager.DeleteKeys(args)
 		return err
@@ -358,3 +357,191 @@ func (s *keyManagerSuite) TestBlockImportKeys(c *gc.C) {
 	s.AssertBlocked(c, err, "TestBlockImportKeys")
 	s.assertEnvironKeys(c, initialKeys)
 }
+
+func (s *keyManagerSuite) TestImportKeysGitHub(c *gc.C) {
+	var fetched []string
+	s.PatchValue(&keymanager.FetchSSHKeys, func(url string) (string, error) {
+		fetched = append(fetched, url)
+		return sshtesting.ValidKeyThree.Key + "\n", nil
+	})
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorisedKeys(c, key1)
+
+	args := params.ModifyUserSSHKeys{
+		User: s.AdminUserTag(c).Name(),
+		Keys: []string{"gh:someone"},
+	}
+	results, err := s.keymanager.ImportKeys(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{Error: nil}},
+	})
+	c.Assert(fetched, jc.DeepEquals, []string{"https://github.com/someone.keys"})
+	s.assertEnvironKeys(c, []string{key1, sshtesting.ValidKeyThree.Key + " gh:someone"})
+}
+
+func (s *keyManagerSuite) TestImportKeysGitHubError(c *gc.C) {
+	s.PatchValue(&keymanager.FetchSSHKeys, func(url string) (string, error) {
+		return "", fmt.Errorf("cannot get %s: 404 Not Found", url)
+	})
+	args := params.ModifyUserSSHKeys{
+		User: s.AdminUserTag(c).Name(),
+		Keys: []string{"gh:nobody"},
+	}
+	results, err := s.keymanager.ImportKeys(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{Error: apiservertesting.ServerError(
+			"cannot import ssh keys for gh:nobody: cannot get https://github.com/nobody.keys: 404 Not Found",
+		)}},
+	})
+}
+
+func (s *keyManagerSuite) assertUserKeys(c *gc.C, user names.UserTag, expected []string) {
+	keys, err := s.State.SSHKeys(user)
+	c.Assert(err, jc.ErrorIsNil)
+	var actual []string
+	for _, key := range keys {
+		actual = append(actual, key.Key())
+	}
+	c.Assert(actual, jc.SameContents, expected)
+}
+
+func (s *keyManagerSuite) TestUserKeyNamespaces(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " admin@host"
+	s.setAuthorisedKeys(c, key1)
+	user := s.Factory.MakeEnvUser(c, nil).UserTag()
+
+	// The environment owner can add keys to another user's namespace.
+	key2 := sshtesting.ValidKeyTwo.Key + " user@host"
+	results, err := s.keymanager.AddKeys(params.ModifyUserSSHKeys{
+		User: user.Name(),
+		Keys: []string{key2},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{Error: nil}},
+	})
+	s.assertEnvironKeys(c, []string{key1})
+	s.assertUserKeys(c, user, []string{key2})
+
+	// The user can list and add to their own namespace, but not
+	// another's.
+	anAuthoriser := s.authoriser
+	anAuthoriser.Tag = user
+	api, err := keymanager.NewKeyManagerAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(err, jc.ErrorIsNil)
+	key3 := sshtesting.ValidKeyThree.Key + " user@otherhost"
+	results, err = api.AddKeys(params.ModifyUserSSHKeys{
+		User: user.Name(),
+		Keys: []string{key2, key3},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: apiservertesting.ServerError(fmt.Sprintf("duplicate ssh key: %s", key2))},
+			{Error: nil},
+		},
+	})
+	s.assertUserKeys(c, user, []string{key2, key3})
+
+	listResults, err := api.ListKeys(params.ListSSHKeys{
+		Entities: params.Entities{[]params.Entity{
+			{Tag: user.Name()},
+			{Tag: s.AdminUserTag(c).Name()},
+		}},
+		Mode: ssh.FullKeys,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(listResults.Results, gc.HasLen, 2)
+	c.Assert(listResults.Results[0].Error, gc.IsNil)
+	c.Assert(listResults.Results[0].Result, jc.SameContents, []string{key2, key3})
+	c.Assert(listResults.Results[1].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	_, err = api.AddKeys(params.ModifyUserSSHKeys{
+		User: s.AdminUserTag(c).Name(),
+		Keys: []string{key3},
+	})
+	c.Assert(err, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+
+	// Keys are deleted from the user's namespace by fingerprint or
+	// comment.
+	results, err = api.DeleteKeys(params.ModifyUserSSHKeys{
+		User: user.Name(),
+		Keys: []string{sshtesting.ValidKeyTwo.Fingerprint, "user@otherhost", sshtesting.ValidKeyOne.Fingerprint},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: nil},
+			{Error: nil},
+			{Error: apiservertesting.ServerError("invalid ssh key: " + sshtesting.ValidKeyOne.Fingerprint)},
+		},
+	})
+	s.assertUserKeys(c, user, nil)
+	s.assertEnvironKeys(c, []string{key1})
+}
+
+func (s *keyManagerSuite) TestAddExpiringKeys(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorisedKeys(c, key1)
+
+	// Expiring keys are held in the owner's key namespace, not the
+	// environment config.
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	key2 := sshtesting.ValidKeyTwo.Key + " temp@host"
+	results, err := s.keymanager.AddKeys(params.ModifyUserSSHKeys{
+		User:    s.AdminUserTag(c).Name(),
+		Keys:    []string{key1, key2},
+		Expires: &expires,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: apiservertesting.ServerError(fmt.Sprintf("duplicate ssh key: %s", key1))},
+			{Error: nil},
+		},
+	})
+	s.assertEnvironKeys(c, []string{key1})
+	s.assertUserKeys(c, s.AdminUserTag(c), []string{key2})
+
+	listResults, err := s.keymanager.ListKeys(params.ListSSHKeys{
+		Entities: params.Entities{[]params.Entity{{Tag: s.AdminUserTag(c).Name()}}},
+		Mode:     ssh.Fingerprints,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(listResults, gc.DeepEquals, params.StringsResults{
+		Results: []params.StringsResult{{Result: []string{
+			sshtesting.ValidKeyOne.Fingerprint + " (user@host)",
+			sshtesting.ValidKeyTwo.Fingerprint + " (temp@host) [expires " + expires.Format(time.RFC3339) + "]",
+		}}},
+	})
+
+	// The owner's expiring keys are deleted like any other.
+	results, err = s.keymanager.DeleteKeys(params.ModifyUserSSHKeys{
+		User: s.AdminUserTag(c).Name(),
+		Keys: []string{"temp@host"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{Error: nil}},
+	})
+	s.assertEnvironKeys(c, []string{key1})
+	s.assertUserKeys(c, s.AdminUserTag(c), nil)
+}
+
+func (s *keyManagerSuite) TestJujuSystemKeyCannotExpire(c *gc.C) {
+	anAuthoriser := s.authoriser
+	anAuthoriser.EnvironManager = true
+	anAuthoriser.Tag = names.NewMachineTag("0")
+	api, err := keymanager.NewKeyManagerAPI(s.State, s.resources, anAuthoriser)
+	c.Assert(err, jc.ErrorIsNil)
+
+	expires := time.Now().Add(time.Hour)
+	_, err = api.AddKeys(params.ModifyUserSSHKeys{
+		User:    "juju-system-key",
+		Keys:    []string{sshtesting.ValidKeyThree.Key + " juju-system-key"},
+		Expires: &expires,
+	})
+	c.Assert(err, gc.ErrorMatches, "juju-system-key keys cannot expire")
+}
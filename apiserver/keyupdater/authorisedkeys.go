@@ -4,6 +4,8 @@
 package keyupdater
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/names"
 
@@ -53,9 +55,10 @@ func NewKeyUpdaterAPI(
 }
 
 // WatchAuthorisedKeys starts a watcher to track changes to the authorised ssh keys
-// for the specified machines.
-// The current implementation relies on global authorised keys being stored in the environment config.
-// This will change as new user management and authorisation functionality is added.
+// for the specified machines: those in the environment config, and those in
+// the environment users' key namespaces.
+// Keys expiring do not trigger the watcher, so agents should also refresh
+// their keys periodically.
 func (api *KeyUpdaterAPI) WatchAuthorisedKeys(arg params.Entities) (params.NotifyWatchResults, error) {
 	results := make([]params.NotifyWatchResult, len(arg.Entities))
 
@@ -84,7 +87,7 @@ func (api *KeyUpdaterAPI) WatchAuthorisedKeys(arg params.Entities) (params.Notif
 			continue
 		}
 		// 3. Watch for changes
-		watch := api.state.WatchForEnvironConfigChanges()
+		watch := api.state.WatchAuthorisedKeys()
 		// Consume the initial event.
 		if _, ok := <-watch.Changes(); ok {
 			results[i].NotifyWatcherId = api.resources.Register(watch)
@@ -96,21 +99,17 @@ func (api *KeyUpdaterAPI) WatchAuthorisedKeys(arg params.Entities) (params.Notif
 	return params.NotifyWatchResults{Results: results}, nil
 }
 
-// AuthorisedKeys reports the authorised ssh keys for the specified machines.
-// The current implementation relies on global authorised keys being stored in the environment config.
-// This will change as new user management and authorisation functionality is added.
+// AuthorisedKeys reports the authorised ssh keys for the specified machines:
+// those in the environment config, followed by those in the environment
+// users' key namespaces which have not expired.
 func (api *KeyUpdaterAPI) AuthorisedKeys(arg params.Entities) (params.StringsResults, error) {
 	if len(arg.Entities) == 0 {
 		return params.StringsResults{}, nil
 	}
 	results := make([]params.StringsResult, len(arg.Entities))
 
-	// For now, authorised keys are global, common to all machines.
-	var keys []string
-	config, configErr := api.state.EnvironConfig()
-	if configErr == nil {
-		keys = ssh.SplitAuthorisedKeys(config.AuthorizedKeys())
-	}
+	// For now, authorised keys are common to all machines.
+	keys, keysErr := api.authorisedKeys()
 
 	canRead, err := api.getCanRead()
 	if err != nil {
@@ -137,12 +136,33 @@ func (api *KeyUpdaterAPI) AuthorisedKeys(arg params.Entities) (params.StringsRes
 			continue
 		}
 		// 3. Get keys
-		if configErr == nil {
+		if keysErr == nil {
 			results[i].Result = keys
 		} else {
-			err = configErr
+			err = keysErr
 		}
 		results[i].Error = common.ServerError(err)
 	}
 	return params.StringsResults{Results: results}, nil
 }
+
+// authorisedKeys returns the ssh keys authorised on the environment's
+// machines.
+func (api *KeyUpdaterAPI) authorisedKeys() ([]string, error) {
+	config, err := api.state.EnvironConfig()
+	if err != nil {
+		return nil, err
+	}
+	keys := ssh.SplitAuthorisedKeys(config.AuthorizedKeys())
+	userKeys, err := api.state.AllSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, key := range userKeys {
+		if !key.Expired(now) {
+			keys = append(keys, key.Key())
+		}
+	}
+	return keys, nil
+}
@@ -4,6 +4,8 @@
 package keyupdater_test
 
 import (
+	"time"
+
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -15,6 +17,7 @@ import (
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 )
 
 type authorisedKeysSuite struct {
@@ -140,3 +143,45 @@ func (s *authorisedKeysSuite) TestAuthorisedKeys(c *gc.C) {
 		},
 	})
 }
+
+func (s *authorisedKeysSuite) TestWatchAuthorisedKeysUserKeys(c *gc.C) {
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.rawMachine.Tag().String()}},
+	}
+	results, err := s.keyupdater.WatchAuthorisedKeys(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	w := s.resources.Get(results.Results[0].NotifyWatcherId).(state.NotifyWatcher)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertNoChange()
+
+	user := s.Factory.MakeEnvUser(c, nil).UserTag()
+	_, err = s.State.AddSSHKey(user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *authorisedKeysSuite) TestAuthorisedKeysUserKeys(c *gc.C) {
+	s.setAuthorizedKeys(c, "key1")
+	user := s.Factory.MakeEnvUser(c, nil).UserTag()
+	_, err := s.State.AddSSHKey(user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSSHKey(user, sshtesting.ValidKeyTwo.Key, time.Now().Add(time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSSHKey(user, sshtesting.ValidKeyThree.Key, time.Now().Add(-time.Hour))
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.rawMachine.Tag().String()}},
+	}
+	results, err := s.keyupdater.AuthorisedKeys(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	keys := results.Results[0].Result
+	c.Assert(keys, gc.HasLen, 3)
+	c.Assert(keys[0], gc.Equals, "key1")
+	c.Assert(keys[1:], jc.SameContents, []string{sshtesting.ValidKeyOne.Key, sshtesting.ValidKeyTwo.Key})
+}
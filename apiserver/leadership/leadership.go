@@ -13,7 +13,7 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/leadership"
-	"github.com/juju/juju/lease"
+	"github.com/juju/juju/lease/raftlease"
 	"github.com/juju/juju/state"
 )
 
@@ -37,7 +37,7 @@ var (
 	// Begin injection-chain so we can instantiate leadership
 	// services. Exposed as variables so we can change the
 	// implementation for testing purposes.
-	leaseMgr  = lease.Manager()
+	leaseMgr  = raftlease.Manager()
 	leaderMgr = leadership.NewLeadershipManager(leaseMgr)
 )
 
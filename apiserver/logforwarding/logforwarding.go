@@ -0,0 +1,196 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package logforwarding provides the API used to configure the
+// forwarding of stored logs to external targets, and by state server
+// agents to carry out that forwarding.
+package logforwarding
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("LogForwarding", 1, NewLogForwardingAPI)
+}
+
+// maxBatchSize is the largest number of log records returned by a
+// single call to NextLogs.
+const maxBatchSize = 1000
+
+// LogForwardingAPI implements the LogForwarding facade.
+type LogForwardingAPI struct {
+	st   *state.State
+	auth common.Authorizer
+}
+
+// NewLogForwardingAPI creates a new server-side LogForwarding facade.
+// It is available to clients, which configure targets, and to state
+// server agents, which forward logs to them.
+func NewLogForwardingAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*LogForwardingAPI, error) {
+	if !auth.AuthClient() && !auth.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &LogForwardingAPI{
+		st:   st,
+		auth: auth,
+	}, nil
+}
+
+// Targets returns the log forwarding targets which apply to the
+// environment, along with the delivery status of its logs to each.
+func (api *LogForwardingAPI) Targets() (params.LogForwardTargetsResult, error) {
+	targets, err := api.st.AllLogForwardTargets()
+	if err != nil {
+		return params.LogForwardTargetsResult{}, errors.Trace(err)
+	}
+	result := params.LogForwardTargetsResult{}
+	envUUID := api.st.EnvironUUID()
+	for _, target := range targets {
+		if !target.ForwardsEnviron(envUUID) {
+			continue
+		}
+		status, err := api.st.LogForwardStatus(target.Name())
+		if errors.IsNotFound(err) {
+			// Removed since it was listed.
+			continue
+		} else if err != nil {
+			return params.LogForwardTargetsResult{}, errors.Trace(err)
+		}
+		result.Targets = append(result.Targets, params.LogForwardTarget{
+			Config: params.LogForwardTargetConfig{
+				Name:     target.Name(),
+				Type:     string(target.Type()),
+				Address:  target.Address(),
+				CACert:   target.CACert(),
+				EnvUUIDs: target.EnvUUIDs(),
+				MinLevel: target.MinLevel(),
+			},
+			Status: params.LogForwardStatus{
+				LastSentId:   status.LastSentId,
+				LastSentTime: status.LastSentTime,
+				Delivered:    status.Delivered,
+				Error:        status.Error,
+				Updated:      status.Updated,
+			},
+		})
+	}
+	return result, nil
+}
+
+// AddTargets adds new log forwarding targets.
+func (api *LogForwardingAPI) AddTargets(args params.LogForwardTargetConfigs) (params.ErrorResults, error) {
+	if !api.auth.AuthClient() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Targets)),
+	}
+	for i, config := range args.Targets {
+		_, err := api.st.AddLogForwardTarget(state.LogForwardTargetParams{
+			Name:     config.Name,
+			Type:     state.LogForwardTargetType(config.Type),
+			Address:  config.Address,
+			CACert:   config.CACert,
+			EnvUUIDs: config.EnvUUIDs,
+			MinLevel: config.MinLevel,
+		})
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// RemoveTargets removes log forwarding targets.
+func (api *LogForwardingAPI) RemoveTargets(args params.LogForwardTargetNames) (params.ErrorResults, error) {
+	if !api.auth.AuthClient() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Names)),
+	}
+	for i, name := range args.Names {
+		err := api.st.RemoveLogForwardTarget(name)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// NextLogs returns, for each of the given targets, the next batch of
+// the environment's log records to forward to it.
+func (api *LogForwardingAPI) NextLogs(args params.LogForwardLogsArgs) (params.LogForwardLogsResults, error) {
+	if !api.auth.AuthEnvironManager() {
+		return params.LogForwardLogsResults{}, common.ErrPerm
+	}
+	result := params.LogForwardLogsResults{
+		Results: make([]params.LogForwardLogsResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		records, err := api.nextLogs(arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Records = records
+	}
+	return result, nil
+}
+
+func (api *LogForwardingAPI) nextLogs(arg params.LogForwardLogsArg) ([]params.LogForwardRecord, error) {
+	target, err := api.st.LogForwardTarget(arg.Target)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !target.ForwardsEnviron(api.st.EnvironUUID()) {
+		return nil, errors.NotFoundf("log forwarding target %q for this environment", arg.Target)
+	}
+	status, err := api.st.LogForwardStatus(arg.Target)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	limit := arg.Limit
+	if limit <= 0 || limit > maxBatchSize {
+		limit = maxBatchSize
+	}
+	records, err := api.st.LogsAfter(status.LastSentId, target.MinLevel(), limit)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.LogForwardRecord, len(records))
+	for i, rec := range records {
+		result[i] = params.LogForwardRecord{
+			Id:       rec.Id,
+			Time:     rec.Time,
+			Entity:   rec.Entity,
+			Module:   rec.Module,
+			Location: rec.Location,
+			Level:    rec.Level,
+			Message:  rec.Message,
+		}
+	}
+	return result, nil
+}
+
+// SetDeliveryStatus records the outcome of attempts to deliver the
+// environment's logs to targets.
+func (api *LogForwardingAPI) SetDeliveryStatus(args params.LogForwardDeliveries) (params.ErrorResults, error) {
+	if !api.auth.AuthEnvironManager() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Deliveries)),
+	}
+	for i, delivery := range args.Deliveries {
+		err := api.st.SetLogForwardStatus(delivery.Target, state.LogForwardProgress{
+			LastSentId:   delivery.LastSentId,
+			LastSentTime: delivery.LastSentTime,
+			Delivered:    delivery.Delivered,
+			Error:        delivery.Error,
+		})
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
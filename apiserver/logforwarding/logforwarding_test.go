@@ -0,0 +1,179 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarding_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/logforwarding"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type logForwardingSuite struct {
+	jujutesting.JujuConnSuite
+
+	clientAPI *logforwarding.LogForwardingAPI
+	agentAPI  *logforwarding.LogForwardingAPI
+}
+
+var _ = gc.Suite(&logForwardingSuite{})
+
+func (s *logForwardingSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.clientAPI, err = logforwarding.NewLogForwardingAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.agentAPI, err = logforwarding.NewLogForwardingAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *logForwardingSuite) TestNewAPIRefusesHostUnitsAgent(c *gc.C) {
+	_, err := logforwarding.NewLogForwardingAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *logForwardingSuite) addTargets(c *gc.C, configs ...params.LogForwardTargetConfig) params.ErrorResults {
+	results, err := s.clientAPI.AddTargets(params.LogForwardTargetConfigs{Targets: configs})
+	c.Assert(err, jc.ErrorIsNil)
+	return results
+}
+
+func (s *logForwardingSuite) TestAddTargetsAndList(c *gc.C) {
+	results := s.addTargets(c, params.LogForwardTargetConfig{
+		Name:     "syslog",
+		Type:     "syslog",
+		Address:  "syslog.example.com:6514",
+		MinLevel: loggo.INFO,
+	}, params.LogForwardTargetConfig{
+		Name:     "elsewhere",
+		Type:     "loki",
+		Address:  "https://loki.example.com",
+		EnvUUIDs: []string{"another-uuid"},
+	}, params.LogForwardTargetConfig{
+		Name: "bad",
+		Type: "gelf",
+	})
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Check(results.Results[0].Error, gc.IsNil)
+	c.Check(results.Results[1].Error, gc.IsNil)
+	c.Check(results.Results[2].Error, gc.ErrorMatches, `empty address for log forwarding target "bad" not valid`)
+
+	// Only the targets which forward this environment are listed.
+	targets, err := s.clientAPI.Targets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(targets.Targets, gc.HasLen, 1)
+	c.Check(targets.Targets[0].Config, jc.DeepEquals, params.LogForwardTargetConfig{
+		Name:     "syslog",
+		Type:     "syslog",
+		Address:  "syslog.example.com:6514",
+		MinLevel: loggo.INFO,
+	})
+	c.Check(targets.Targets[0].Status.Delivered, gc.Equals, int64(0))
+}
+
+func (s *logForwardingSuite) TestAgentCannotConfigureTargets(c *gc.C) {
+	_, err := s.agentAPI.AddTargets(params.LogForwardTargetConfigs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.agentAPI.RemoveTargets(params.LogForwardTargetNames{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *logForwardingSuite) TestClientCannotForward(c *gc.C) {
+	_, err := s.clientAPI.NextLogs(params.LogForwardLogsArgs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.clientAPI.SetDeliveryStatus(params.LogForwardDeliveries{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *logForwardingSuite) TestRemoveTargets(c *gc.C) {
+	s.addTargets(c, params.LogForwardTargetConfig{
+		Name:    "syslog",
+		Type:    "syslog",
+		Address: "syslog.example.com:6514",
+	})
+	results, err := s.clientAPI.RemoveTargets(params.LogForwardTargetNames{Names: []string{"syslog", "missing"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Check(results.Results[0].Error, gc.IsNil)
+	c.Check(results.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+
+	targets, err := s.clientAPI.Targets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(targets.Targets, gc.HasLen, 0)
+}
+
+func (s *logForwardingSuite) TestNextLogsAndDeliveryStatus(c *gc.C) {
+	s.addTargets(c, params.LogForwardTargetConfig{
+		Name:     "syslog",
+		Type:     "syslog",
+		Address:  "syslog.example.com:6514",
+		MinLevel: loggo.INFO,
+	})
+	// Only records logged after the target was added are forwarded,
+	// and the target's time is recorded to the second.
+	time.Sleep(time.Second)
+	logger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer logger.Close()
+	t0 := time.Now().Truncate(time.Second)
+	for _, level := range []loggo.Level{loggo.DEBUG, loggo.INFO, loggo.ERROR} {
+		err := logger.Log(t0, "juju", "file.go:1", level, "message at "+level.String())
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	args := params.LogForwardLogsArgs{Args: []params.LogForwardLogsArg{
+		{Target: "syslog", Limit: 10},
+		{Target: "missing", Limit: 10},
+	}}
+	results, err := s.agentAPI.NextLogs(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	records := results.Results[0].Records
+	c.Assert(records, gc.HasLen, 2)
+	c.Check(records[0].Message, gc.Equals, "message at INFO")
+	c.Check(records[0].Entity, gc.Equals, "machine-0")
+	c.Check(records[1].Message, gc.Equals, "message at ERROR")
+	c.Check(results.Results[1].Error, jc.Satisfies, params.IsCodeNotFound)
+
+	errResults, err := s.agentAPI.SetDeliveryStatus(params.LogForwardDeliveries{
+		Deliveries: []params.LogForwardDelivery{{
+			Target:       "syslog",
+			LastSentId:   records[0].Id,
+			LastSentTime: records[0].Time,
+			Delivered:    1,
+			Error:        "connection reset",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(errResults.OneError(), jc.ErrorIsNil)
+
+	results, err = s.agentAPI.NextLogs(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Records, gc.HasLen, 1)
+	c.Check(results.Results[0].Records[0].Message, gc.Equals, "message at ERROR")
+
+	targets, err := s.clientAPI.Targets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(targets.Targets, gc.HasLen, 1)
+	status := targets.Targets[0].Status
+	c.Check(status.LastSentId, gc.Equals, records[0].Id)
+	c.Check(status.Delivered, gc.Equals, int64(1))
+	c.Check(status.Error, gc.Equals, "connection reset")
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"code.google.com/p/go.net/websocket"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+// logSinkHandler takes log records sent by agents and writes them
+// to the logs database.
+type logSinkHandler struct {
+	httpHandler
+	metrics *serverMetrics
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// The connection is upgraded to a websocket. Once the agent has been
+// authenticated, the first line sent back is a JSON encoded
+// params.ErrorResult reporting whether the connection was accepted.
+// After that the agent sends a stream of JSON encoded
+// params.LogRecord values, one per message, until it closes the
+// connection.
+func (h *logSinkHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := websocket.Server{
+		Handler: func(socket *websocket.Conn) {
+			defer socket.Close()
+			// Validate before authenticate because the authentication is
+			// dependent on the state connection that is determined during the
+			// validation.
+			stateWrapper, err := h.validateEnvironUUID(req)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			defer stateWrapper.cleanup()
+			entity, err := stateWrapper.authenticateAgent(req)
+			if err != nil {
+				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
+				return
+			}
+
+			// If we get to here, no more errors to report, so we report a nil
+			// error. This way the first line of the socket is always a json
+			// formatted simple error.
+			if err := h.sendError(socket, nil); err != nil {
+				logger.Errorf("could not send good log sink start")
+				return
+			}
+
+			dbLogger := state.NewDbLogger(stateWrapper.state, entity.Tag())
+			defer dbLogger.Close()
+			for {
+				var rec params.LogRecord
+				if err := websocket.JSON.Receive(socket, &rec); err != nil {
+					if err != io.EOF {
+						logger.Debugf("logsink receive error for %s: %v", entity.Tag(), err)
+					}
+					return
+				}
+				if err := dbLogger.Log(rec.Time, rec.Module, rec.Location, rec.Level, rec.Message); err != nil {
+					logger.Errorf("logging to DB failed: %v", err)
+					return
+				}
+				h.metrics.recordLog()
+			}
+		}}
+	server.ServeHTTP(w, req)
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *logSinkHandler) sendError(w io.Writer, err error) error {
+	return sendWebsocketError(w, err)
+}
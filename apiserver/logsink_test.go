@@ -0,0 +1,196 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type logsinkSuite struct {
+	authHttpSuite
+	machineTag names.Tag
+	password   string
+	nonce      string
+}
+
+var _ = gc.Suite(&logsinkSuite{})
+
+func (s *logsinkSuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+	s.nonce = "nonce"
+	m, password := s.Factory.MakeMachineReturningPassword(c, &factory.MachineParams{
+		Nonce: s.nonce,
+	})
+	s.machineTag = m.Tag()
+	s.password = password
+}
+
+func (s *logsinkSuite) TestRejectsBadEnvironUUID(c *gc.C) {
+	reader := s.openWebsocketCustomPath(c, "/environment/does-not-exist/logsink", s.agentHeader())
+	s.assertErrorResponse(c, reader, `unknown environment: "does-not-exist"`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *logsinkSuite) TestNoAuth(c *gc.C) {
+	s.checkAuthFails(c, nil, "invalid request format")
+}
+
+func (s *logsinkSuite) TestRejectsUserLogins(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: "sekrit"})
+	header := utils.BasicAuthHeader(user.Tag().String(), "sekrit")
+	s.checkAuthFails(c, header, "invalid entity name or password")
+}
+
+func (s *logsinkSuite) TestRejectsBadPassword(c *gc.C) {
+	header := utils.BasicAuthHeader(s.machineTag.String(), "wrong")
+	header.Add("X-Juju-Nonce", s.nonce)
+	s.checkAuthFails(c, header, "invalid entity name or password")
+}
+
+func (s *logsinkSuite) TestRejectsIncorrectNonce(c *gc.C) {
+	header := utils.BasicAuthHeader(s.machineTag.String(), s.password)
+	header.Add("X-Juju-Nonce", "wrong")
+	s.checkAuthFails(c, header, `machine \d+ not provisioned`)
+}
+
+func (s *logsinkSuite) checkAuthFails(c *gc.C, header http.Header, message string) {
+	conn := s.dialWebsocketInternal(c, header)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	s.assertErrorResponse(c, reader, "auth failed: "+message)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *logsinkSuite) TestLogging(c *gc.C) {
+	conn := s.dialWebsocket(c)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	s.assertErrorResponse(c, reader, "")
+
+	t0 := time.Date(2015, time.June, 1, 23, 2, 1, 0, time.UTC)
+	err := websocket.JSON.Send(conn, &params.LogRecord{
+		Time:     t0,
+		Module:   "some.where",
+		Location: "foo.go:42",
+		Level:    loggo.INFO,
+		Message:  "all is well",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	t1 := time.Date(2015, time.June, 1, 23, 2, 2, 0, time.UTC)
+	err = websocket.JSON.Send(conn, &params.LogRecord{
+		Time:     t1,
+		Module:   "else.where",
+		Location: "bar.go:99",
+		Level:    loggo.ERROR,
+		Message:  "oh noes",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Wait for the log records to be written.
+	logsColl := s.State.MongoSession().DB("logs").C("logs")
+	var docs []bson.M
+	for a := testing.LongAttempt.Start(); a.Next(); {
+		err := logsColl.Find(nil).Sort("t").All(&docs)
+		c.Assert(err, jc.ErrorIsNil)
+		if len(docs) == 2 {
+			break
+		}
+	}
+	c.Assert(docs, gc.HasLen, 2)
+
+	c.Assert(docs[0]["t"].(time.Time).Equal(t0), jc.IsTrue)
+	c.Assert(docs[0]["e"], gc.Equals, s.State.EnvironUUID())
+	c.Assert(docs[0]["n"], gc.Equals, s.machineTag.String())
+	c.Assert(docs[0]["m"], gc.Equals, "some.where")
+	c.Assert(docs[0]["l"], gc.Equals, "foo.go:42")
+	c.Assert(docs[0]["v"], gc.Equals, int(loggo.INFO))
+	c.Assert(docs[0]["x"], gc.Equals, "all is well")
+
+	c.Assert(docs[1]["t"].(time.Time).Equal(t1), jc.IsTrue)
+	c.Assert(docs[1]["n"], gc.Equals, s.machineTag.String())
+	c.Assert(docs[1]["v"], gc.Equals, int(loggo.ERROR))
+	c.Assert(docs[1]["x"], gc.Equals, "oh noes")
+}
+
+func (s *logsinkSuite) agentHeader() http.Header {
+	header := utils.BasicAuthHeader(s.machineTag.String(), s.password)
+	header.Add("X-Juju-Nonce", s.nonce)
+	return header
+}
+
+func (s *logsinkSuite) dialWebsocket(c *gc.C) *websocket.Conn {
+	return s.dialWebsocketInternal(c, s.agentHeader())
+}
+
+func (s *logsinkSuite) dialWebsocketInternal(c *gc.C, header http.Header) *websocket.Conn {
+	server := s.logsinkURL(c, "wss").String()
+	return s.dialWebsocketFromURL(c, server, header)
+}
+
+func (s *logsinkSuite) openWebsocketCustomPath(c *gc.C, path string, header http.Header) *bufio.Reader {
+	server := s.logsinkURL(c, "wss")
+	server.Path = path
+	conn := s.dialWebsocketFromURL(c, server.String(), header)
+	s.AddCleanup(func(_ *gc.C) { conn.Close() })
+	return bufio.NewReader(conn)
+}
+
+func (s *logsinkSuite) dialWebsocketFromURL(c *gc.C, server string, header http.Header) *websocket.Conn {
+	c.Logf("dialing %v", server)
+	config, err := websocket.NewConfig(server, "http://localhost/")
+	c.Assert(err, jc.ErrorIsNil)
+	config.Header = header
+	caCerts := x509.NewCertPool()
+	c.Assert(caCerts.AppendCertsFromPEM([]byte(testing.CACert)), jc.IsTrue)
+	config.TlsConfig = &tls.Config{RootCAs: caCerts, ServerName: "anything"}
+	conn, err := websocket.DialConfig(config)
+	c.Assert(err, jc.ErrorIsNil)
+	return conn
+}
+
+func (s *logsinkSuite) logsinkURL(c *gc.C, scheme string) *url.URL {
+	server := s.baseURL(c)
+	server.Scheme = scheme
+	server.Path = "/environment/" + s.State.EnvironUUID() + "/logsink"
+	return server
+}
+
+func (s *logsinkSuite) assertErrorResponse(c *gc.C, reader *bufio.Reader, expected string) {
+	line, err := reader.ReadSlice('\n')
+	c.Assert(err, jc.ErrorIsNil)
+	var errResult params.ErrorResult
+	err = json.Unmarshal(line, &errResult)
+	c.Assert(err, jc.ErrorIsNil)
+	if expected == "" {
+		c.Assert(errResult.Error, gc.IsNil)
+	} else {
+		c.Assert(errResult.Error, gc.NotNil)
+		c.Assert(errResult.Error.Message, gc.Matches, expected)
+	}
+}
+
+func (s *logsinkSuite) assertWebsocketClosed(c *gc.C, reader *bufio.Reader) {
+	_, err := reader.ReadByte()
+	c.Assert(err, gc.Equals, io.EOF)
+}
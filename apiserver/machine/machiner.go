@@ -52,6 +52,7 @@ func NewMachinerAPI(st *state.State, resources *common.Resources, authorizer com
 		st:                 st,
 		auth:               authorizer,
 		getCanModify:       getCanModify,
+		getCanRead:         getCanRead,
 	}, nil
 }
 
@@ -92,3 +93,97 @@ func (api *MachinerAPI) SetMachineAddresses(args params.SetMachinesAddresses) (p
 	}
 	return results, nil
 }
+
+// HardwareRefreshRequested returns, for each given machine, whether the
+// machine agent has been asked to re-detect its hardware characteristics.
+func (api *MachinerAPI) HardwareRefreshRequested(args params.Entities) (params.BoolResults, error) {
+	results := params.BoolResults{
+		Results: make([]params.BoolResult, len(args.Entities)),
+	}
+	canRead, err := api.getCanRead()
+	if err != nil {
+		return results, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseMachineTag(entity.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canRead(tag) {
+			var m *state.Machine
+			m, err = api.getMachine(tag)
+			if err == nil {
+				results.Results[i].Result = m.HardwareRefreshRequested()
+			} else if errors.IsNotFound(err) {
+				err = common.ErrPerm
+			}
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// SetHardwareCharacteristics records the hardware characteristics
+// detected on each given machine.
+func (api *MachinerAPI) SetHardwareCharacteristics(args params.SetMachinesHardwareCharacteristics) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.MachineCharacteristics)),
+	}
+	canModify, err := api.getCanModify()
+	if err != nil {
+		return results, err
+	}
+	for i, arg := range args.MachineCharacteristics {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canModify(tag) {
+			var m *state.Machine
+			m, err = api.getMachine(tag)
+			if err == nil {
+				err = m.SetHardwareCharacteristics(arg.Characteristics)
+			} else if errors.IsNotFound(err) {
+				err = common.ErrPerm
+			}
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
+
+// SetSSHHostKeys records the public ssh host keys of each given
+// machine, against which clients connecting to the machine verify its
+// identity.
+func (api *MachinerAPI) SetSSHHostKeys(args params.SetMachinesSSHHostKeys) (params.ErrorResults, error) {
+	results := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.MachineHostKeys)),
+	}
+	canModify, err := api.getCanModify()
+	if err != nil {
+		return results, err
+	}
+	for i, arg := range args.MachineHostKeys {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canModify(tag) {
+			var m *state.Machine
+			m, err = api.getMachine(tag)
+			if err == nil {
+				err = m.SetSSHHostKeys(arg.PublicKeys)
+			} else if errors.IsNotFound(err) {
+				err = common.ErrPerm
+			}
+		}
+		results.Results[i].Error = common.ServerError(err)
+	}
+	return results, nil
+}
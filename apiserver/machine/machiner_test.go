@@ -12,9 +12,11 @@ import (
 	"github.com/juju/juju/apiserver/machine"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 )
 
 type machinerSuite struct {
@@ -189,6 +191,87 @@ func (s *machinerSuite) TestSetMachineAddresses(c *gc.C) {
 	c.Assert(s.machine0.MachineAddresses(), gc.HasLen, 0)
 }
 
+func (s *machinerSuite) TestHardwareRefreshRequested(c *gc.C) {
+	err := s.machine1.SetProvisioned("i-1", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine1.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: "machine-1"},
+		{Tag: "machine-0"},
+		{Tag: "machine-42"},
+	}}
+	result, err := s.machiner.HardwareRefreshRequested(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.BoolResults{
+		Results: []params.BoolResult{
+			{Result: true},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *machinerSuite) TestSetHardwareCharacteristics(c *gc.C) {
+	err := s.machine1.SetProvisioned("i-1", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine1.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	hc := instance.MustParseHardware("arch=amd64 mem=16G cpu-cores=8 numa-nodes=2 gpus=1")
+	args := params.SetMachinesHardwareCharacteristics{
+		MachineCharacteristics: []params.MachineHardwareCharacteristics{
+			{Tag: "machine-1", Characteristics: hc},
+			{Tag: "machine-0", Characteristics: hc},
+			{Tag: "machine-42", Characteristics: hc},
+		},
+	}
+	result, err := s.machiner.SetHardwareCharacteristics(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.machine1.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine1.HardwareRefreshRequested(), jc.IsFalse)
+	stored, err := s.machine1.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*stored, jc.DeepEquals, hc)
+}
+
+func (s *machinerSuite) TestSetSSHHostKeys(c *gc.C) {
+	keys := []string{sshtesting.ValidKeyOne.Key + " root@host"}
+	args := params.SetMachinesSSHHostKeys{
+		MachineHostKeys: []params.MachineSSHHostKeys{
+			{Tag: "machine-1", PublicKeys: keys},
+			{Tag: "machine-0", PublicKeys: keys},
+			{Tag: "machine-42", PublicKeys: keys},
+		},
+	}
+	result, err := s.machiner.SetSSHHostKeys(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.machine1.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine1.SSHHostKeys(), jc.DeepEquals, keys)
+	err = s.machine0.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine0.SSHHostKeys(), gc.HasLen, 0)
+}
+
 func (s *machinerSuite) TestWatch(c *gc.C) {
 	c.Assert(s.resources.Count(), gc.Equals, 0)
 
@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package maintenancewindow implements the API used to query and
+// override an environment's maintenance window, outside of which
+// disruptive automated operations are deferred.
+package maintenancewindow
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("MaintenanceWindow", 1, NewAPI)
+}
+
+// API implements the MaintenanceWindow facade.
+type API struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewAPI returns a new MaintenanceWindow API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthMachineAgent() && !authorizer.AuthUnitAgent() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st, authorizer: authorizer}, nil
+}
+
+// Status returns the current state of the environment's maintenance
+// window.
+func (api *API) Status() (params.MaintenanceWindowStatus, error) {
+	status, err := api.st.MaintenanceWindow(time.Now())
+	if err != nil {
+		return params.MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	result := params.MaintenanceWindowStatus{
+		Schedule: status.Schedule,
+		Duration: status.Duration,
+		Open:     status.Open,
+	}
+	if status.Override != nil {
+		result.Override = &params.MaintenanceWindowOverride{
+			Open:  status.Override.Open,
+			Until: status.Override.Until,
+		}
+	}
+	if !status.Until.IsZero() {
+		until := status.Until
+		result.Until = &until
+	}
+	return result, nil
+}
+
+// SetOverride holds the environment's maintenance window open or
+// closed until the given time, regardless of its schedule.
+func (api *API) SetOverride(args params.MaintenanceWindowOverride) error {
+	if !api.authorizer.AuthClient() {
+		return common.ErrPerm
+	}
+	return api.st.SetMaintenanceOverride(state.MaintenanceOverride{
+		Open:  args.Open,
+		Until: args.Until,
+	})
+}
+
+// ClearOverride removes any override of the environment's maintenance
+// window.
+func (api *API) ClearOverride() error {
+	if !api.authorizer.AuthClient() {
+		return common.ErrPerm
+	}
+	return api.st.ClearMaintenanceOverride()
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package maintenancewindow_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/maintenancewindow"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type maintenanceWindowSuite struct {
+	jujutesting.JujuConnSuite
+	api *maintenancewindow.API
+}
+
+var _ = gc.Suite(&maintenanceWindowSuite{})
+
+func (s *maintenanceWindowSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = maintenancewindow.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *maintenanceWindowSuite) TestAgentsCannotOverride(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewUnitTag("wordpress/0")}
+	api, err := maintenancewindow.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	err = api.SetOverride(params.MaintenanceWindowOverride{
+		Open:  true,
+		Until: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	err = api.ClearOverride()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *maintenanceWindowSuite) TestStatusNoWindow(c *gc.C) {
+	status, err := s.api.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, params.MaintenanceWindowStatus{
+		Duration: time.Hour,
+		Open:     true,
+	})
+}
+
+func (s *maintenanceWindowSuite) TestOverride(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"maintenance-window": "@yearly",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.api.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Schedule, gc.Equals, "@yearly")
+
+	until := time.Now().Add(time.Hour).UTC().Round(time.Second)
+	override := params.MaintenanceWindowOverride{Open: !status.Open, Until: until}
+	err = s.api.SetOverride(override)
+	c.Assert(err, jc.ErrorIsNil)
+	overridden, err := s.api.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(overridden.Open, gc.Equals, override.Open)
+	c.Assert(overridden.Override, jc.DeepEquals, &override)
+	c.Assert(*overridden.Until, gc.Equals, until)
+
+	err = s.api.ClearOverride()
+	c.Assert(err, jc.ErrorIsNil)
+	cleared, err := s.api.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cleared.Override, gc.IsNil)
+	c.Assert(cleared.Open, gc.Equals, status.Open)
+}
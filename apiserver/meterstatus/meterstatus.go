@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package meterstatus provides the API used by clients, such as
+// dashboards, to follow the meter statuses of an environment's units,
+// and by state server agents to send alerts when they turn RED.
+package meterstatus
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("MeterStatus", 1, NewMeterStatusAPI)
+}
+
+// MeterStatusAPI implements the MeterStatus facade.
+type MeterStatusAPI struct {
+	st        *state.State
+	resources *common.Resources
+	auth      common.Authorizer
+}
+
+// NewMeterStatusAPI creates a new server-side MeterStatus facade. It
+// is available to clients and to state server agents.
+func NewMeterStatusAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*MeterStatusAPI, error) {
+	if !auth.AuthClient() && !auth.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &MeterStatusAPI{
+		st:        st,
+		resources: resources,
+		auth:      auth,
+	}, nil
+}
+
+// WatchEnvironMeterStatuses returns a NotifyWatcher which notifies
+// when the meter status of any unit in the environment changes, or a
+// unit is added or removed.
+func (api *MeterStatusAPI) WatchEnvironMeterStatuses() (params.NotifyWatchResult, error) {
+	result := params.NotifyWatchResult{}
+	watch := api.st.WatchMeterStatuses()
+	if _, ok := <-watch.Changes(); ok {
+		result.NotifyWatcherId = api.resources.Register(watch)
+	} else {
+		return result, watcher.EnsureErr(watch)
+	}
+	return result, nil
+}
+
+// EnvironMeterStatuses returns the meter statuses of all the units in
+// the environment, ordered by unit name.
+func (api *MeterStatusAPI) EnvironMeterStatuses() (params.UnitMeterStatuses, error) {
+	statuses, err := api.st.AllMeterStatuses()
+	if err != nil {
+		return params.UnitMeterStatuses{}, errors.Trace(err)
+	}
+	unitNames := make([]string, 0, len(statuses))
+	for name := range statuses {
+		unitNames = append(unitNames, name)
+	}
+	sort.Strings(unitNames)
+	result := params.UnitMeterStatuses{
+		Statuses: make([]params.UnitMeterStatus, len(unitNames)),
+	}
+	for i, name := range unitNames {
+		result.Statuses[i] = params.UnitMeterStatus{
+			UnitTag: names.NewUnitTag(name).String(),
+			Code:    string(statuses[name].Code),
+			Info:    statuses[name].Info,
+		}
+	}
+	return result, nil
+}
+
+// AlertConfig returns the settings used to send alerts when the meter
+// statuses of units turn RED.
+func (api *MeterStatusAPI) AlertConfig() (params.MeterStatusAlertConfig, error) {
+	if !api.auth.AuthEnvironManager() {
+		return params.MeterStatusAlertConfig{}, common.ErrPerm
+	}
+	cfg, err := api.st.EnvironConfig()
+	if err != nil {
+		return params.MeterStatusAlertConfig{}, errors.Trace(err)
+	}
+	return params.MeterStatusAlertConfig{URL: cfg.MeterStatusAlertURL()}, nil
+}
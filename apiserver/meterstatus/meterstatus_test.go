@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package meterstatus_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/meterstatus"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type meterStatusSuite struct {
+	jujutesting.JujuConnSuite
+
+	resources *common.Resources
+	unit      *state.Unit
+}
+
+var _ = gc.Suite(&meterStatusSuite{})
+
+func (s *meterStatusSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+	s.unit = s.Factory.MakeUnit(c, nil)
+}
+
+func (s *meterStatusSuite) newAPI(c *gc.C, auth apiservertesting.FakeAuthorizer) *meterstatus.MeterStatusAPI {
+	api, err := meterstatus.NewMeterStatusAPI(s.State, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *meterStatusSuite) clientAPI(c *gc.C) *meterstatus.MeterStatusAPI {
+	return s.newAPI(c, apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)})
+}
+
+func (s *meterStatusSuite) managerAPI(c *gc.C) *meterstatus.MeterStatusAPI {
+	return s.newAPI(c, apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+}
+
+func (s *meterStatusSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	_, err := meterstatus.NewMeterStatusAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *meterStatusSuite) TestEnvironMeterStatuses(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	err := unit.SetMeterStatus("RED", "payment overdue")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.clientAPI(c).EnvironMeterStatuses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Statuses, jc.DeepEquals, []params.UnitMeterStatus{{
+		UnitTag: s.unit.Tag().String(),
+		Code:    "NOT SET",
+	}, {
+		UnitTag: unit.Tag().String(),
+		Code:    "RED",
+		Info:    "payment overdue",
+	}})
+}
+
+func (s *meterStatusSuite) TestWatchEnvironMeterStatuses(c *gc.C) {
+	result, err := s.clientAPI(c).WatchEnvironMeterStatuses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.NotifyWatcherId, gc.Equals, "1")
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	w := s.resources.Get("1").(state.NotifyWatcher)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertNoChange()
+
+	err = s.unit.SetMeterStatus("AMBER", "payment due")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *meterStatusSuite) TestAlertConfig(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"meter-status-alert-url": "https://alerts.example.com/juju",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.managerAPI(c).AlertConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, params.MeterStatusAlertConfig{URL: "https://alerts.example.com/juju"})
+}
+
+func (s *meterStatusSuite) TestAlertConfigRefusesClients(c *gc.C) {
+	_, err := s.clientAPI(c).AlertConfig()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"bufio"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/juju/lease"
+	"github.com/juju/juju/state"
+)
+
+// metricsContentType is the content type of the Prometheus text
+// exposition format served at /metrics.
+const metricsContentType = "text/plain; version=0.0.4"
+
+// serverMetrics records the activity of an API server that is
+// reported at /metrics.
+type serverMetrics struct {
+	mu         sync.Mutex
+	apiCalls   map[string]int64
+	apiErrors  map[string]int64
+	logRecords int64
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		apiCalls:  make(map[string]int64),
+		apiErrors: make(map[string]int64),
+	}
+}
+
+// recordCall notes that a call to the given facade has completed,
+// and whether it failed.
+func (m *serverMetrics) recordCall(facade string, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiCalls[facade]++
+	if failed {
+		m.apiErrors[facade]++
+	}
+}
+
+// recordLog notes that a log record sent by an agent has been
+// written to the logs database.
+func (m *serverMetrics) recordLog() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logRecords++
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition
+// format. Errors are recorded rather than returned, and checked
+// once all the metrics are written.
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// header writes the HELP and TYPE lines describing a metric.
+func (w *metricsWriter) header(name, kind, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// value writes a single sample of the named metric.
+func (w *metricsWriter) value(name string, value int64) {
+	w.printf("%s %d\n", name, value)
+}
+
+// facadeValues writes a sample of the named metric for each facade,
+// in order of facade name.
+func (w *metricsWriter) facadeValues(name string, values map[string]int64) {
+	facades := make([]string, 0, len(values))
+	for facade := range values {
+		facades = append(facades, facade)
+	}
+	sort.Strings(facades)
+	for _, facade := range facades {
+		w.printf("%s{facade=\"%s\"} %d\n", name, labelValueEscaper.Replace(facade), values[facade])
+	}
+}
+
+func (w *metricsWriter) printf(format string, args ...interface{}) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.w, format, args...)
+	}
+}
+
+// write writes the current metrics to out.
+func (m *serverMetrics) write(out io.Writer, connections *connectionTracker) error {
+	m.mu.Lock()
+	apiCalls := make(map[string]int64, len(m.apiCalls))
+	for facade, count := range m.apiCalls {
+		apiCalls[facade] = count
+	}
+	apiErrors := make(map[string]int64, len(m.apiErrors))
+	for facade, count := range m.apiErrors {
+		apiErrors[facade] = count
+	}
+	logRecords := m.logRecords
+	m.mu.Unlock()
+	connCount, watcherCount := connections.counts()
+	txnStats := state.CurrentTxnStats()
+	leaseStats := lease.CurrentStats()
+
+	w := &metricsWriter{w: bufio.NewWriter(out)}
+	w.header("juju_api_requests_total", "counter", "Number of API requests served, by facade.")
+	w.facadeValues("juju_api_requests_total", apiCalls)
+	w.header("juju_api_request_errors_total", "counter", "Number of API requests that failed, by facade.")
+	w.facadeValues("juju_api_request_errors_total", apiErrors)
+	w.header("juju_api_connections", "gauge", "Number of open API connections.")
+	w.value("juju_api_connections", int64(connCount))
+	w.header("juju_api_watchers", "gauge", "Number of watchers held by API connections.")
+	w.value("juju_api_watchers", int64(watcherCount))
+	w.header("juju_state_txn_attempts_total", "counter", "Number of state transactions submitted, including retries.")
+	w.value("juju_state_txn_attempts_total", txnStats.Attempts)
+	w.header("juju_state_txn_aborted_total", "counter", "Number of state transactions aborted by failed assertions.")
+	w.value("juju_state_txn_aborted_total", txnStats.Aborted)
+	w.header("juju_lease_claims_total", "counter", "Number of lease claims granted.")
+	w.value("juju_lease_claims_total", leaseStats.Claims)
+	w.header("juju_lease_claims_denied_total", "counter", "Number of lease claims denied.")
+	w.value("juju_lease_claims_denied_total", leaseStats.ClaimsDenied)
+	w.header("juju_lease_releases_total", "counter", "Number of leases released by their owners.")
+	w.value("juju_lease_releases_total", leaseStats.Releases)
+	w.header("juju_lease_expiries_total", "counter", "Number of leases expired.")
+	w.value("juju_lease_expiries_total", leaseStats.Expiries)
+	w.header("juju_logsink_records_total", "counter", "Number of log records received from agents.")
+	w.value("juju_logsink_records_total", logRecords)
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// metricsHandler serves the API server's metrics in the Prometheus
+// text exposition format. The metrics are only served when the
+// metrics-endpoint setting of the state server environment is
+// enabled, and only to users authenticated with HTTP basic
+// authentication or clients presenting a certificate signed by the
+// environment's CA.
+type metricsHandler struct {
+	httpHandler
+	metrics     *serverMetrics
+	connections *connectionTracker
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	stateWrapper, err := h.validateEnvironUUID(req)
+	if err != nil {
+		h.sendError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer stateWrapper.cleanup()
+
+	cfg, err := stateWrapper.state.EnvironConfig()
+	if err != nil {
+		h.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !cfg.MetricsEndpoint() {
+		h.sendError(w, http.StatusNotFound, "metrics endpoint not enabled")
+		return
+	}
+	caCert, _ := cfg.CACert()
+	if !verifyClientCert(req, caCert) {
+		if err := stateWrapper.authenticate(req); err != nil {
+			h.authError(w, h)
+			return
+		}
+	}
+	if req.Method != "GET" {
+		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", req.Method))
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	if err := h.metrics.write(w, h.connections); err != nil {
+		logger.Debugf("cannot write metrics: %v", err)
+	}
+}
+
+// sendError sends a plain text error response.
+func (h *metricsHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(statusCode)
+	fmt.Fprintln(w, message)
+}
+
+// verifyClientCert reports whether the request was made with a
+// client certificate signed by the given CA certificate, in PEM
+// format.
+func verifyClientCert(req *http.Request, caCert string) bool {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return false
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(caCert)) {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := req.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
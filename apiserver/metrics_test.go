@@ -0,0 +1,134 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cert"
+)
+
+type metricsSuite struct {
+	authHttpSuite
+}
+
+var _ = gc.Suite(&metricsSuite{})
+
+func (s *metricsSuite) metricsURL(c *gc.C) string {
+	uri := s.baseURL(c)
+	uri.Path = "/metrics"
+	return uri.String()
+}
+
+func (s *metricsSuite) enableMetrics(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"metrics-endpoint": true}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *metricsSuite) TestNotEnabled(c *gc.C) {
+	resp, err := s.authRequest(c, "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body := assertResponse(c, resp, http.StatusNotFound, "text/plain; charset=utf-8")
+	c.Assert(string(body), gc.Equals, "metrics endpoint not enabled\n")
+}
+
+func (s *metricsSuite) TestRequiresAuth(c *gc.C) {
+	s.enableMetrics(c)
+	resp, err := s.sendRequest(c, "", "", "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body := assertResponse(c, resp, http.StatusUnauthorized, "text/plain; charset=utf-8")
+	c.Assert(string(body), gc.Equals, "unauthorized\n")
+	c.Assert(resp.Header.Get("WWW-Authenticate"), gc.Equals, `Basic realm="juju"`)
+}
+
+func (s *metricsSuite) TestRequiresGet(c *gc.C) {
+	s.enableMetrics(c)
+	resp, err := s.authRequest(c, "POST", s.metricsURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body := assertResponse(c, resp, http.StatusMethodNotAllowed, "text/plain; charset=utf-8")
+	c.Assert(string(body), gc.Equals, "unsupported method: \"POST\"\n")
+}
+
+func (s *metricsSuite) TestBasicAuth(c *gc.C) {
+	s.enableMetrics(c)
+	_, err := s.APIState.Client().EnvironmentGet()
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp, err := s.authRequest(c, "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	body := string(assertResponse(c, resp, http.StatusOK, "text/plain; version=0.0.4"))
+	c.Check(body, gc.Matches, `(?s)# HELP juju_api_requests_total .*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_api_requests_total\{facade="Client"\} [1-9][0-9]*\n.*`)
+	c.Check(body, gc.Matches, `(?s).*\njuju_api_connections [1-9][0-9]*\n.*`)
+	for _, name := range []string{
+		"juju_api_watchers",
+		"juju_state_txn_attempts_total",
+		"juju_state_txn_aborted_total",
+		"juju_lease_claims_total",
+		"juju_lease_claims_denied_total",
+		"juju_lease_releases_total",
+		"juju_lease_expiries_total",
+		"juju_logsink_records_total",
+	} {
+		c.Check(body, gc.Matches, `(?s).*\n`+name+` [0-9]+\n.*`)
+	}
+}
+
+func (s *metricsSuite) TestAgentCredentialsRejected(c *gc.C) {
+	s.enableMetrics(c)
+	machine, password := s.Factory.MakeMachineReturningPassword(c, nil)
+	resp, err := s.sendRequest(c, machine.Tag().String(), password, "GET", s.metricsURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	assertResponse(c, resp, http.StatusUnauthorized, "text/plain; charset=utf-8")
+}
+
+func (s *metricsSuite) clientCertRequest(c *gc.C, certPEM, keyPEM string) *http.Response {
+	clientCert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	c.Assert(err, jc.ErrorIsNil)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates:       []tls.Certificate{clientCert},
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	resp, err := client.Get(s.metricsURL(c))
+	c.Assert(err, jc.ErrorIsNil)
+	return resp
+}
+
+func (s *metricsSuite) TestClientCert(c *gc.C) {
+	s.enableMetrics(c)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	caCert, _ := cfg.CACert()
+	caKey, _ := cfg.CAPrivateKey()
+	certPEM, keyPEM, err := cert.NewClient(caCert, caKey, time.Now().AddDate(0, 0, 1))
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.clientCertRequest(c, certPEM, keyPEM)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resp.StatusCode, gc.Equals, http.StatusOK)
+	c.Assert(string(body), gc.Matches, `(?s)# HELP juju_api_requests_total .*`)
+}
+
+func (s *metricsSuite) TestClientCertFromOtherCA(c *gc.C) {
+	s.enableMetrics(c)
+	caCert, caKey, err := cert.NewCA("other", time.Now().AddDate(0, 0, 1))
+	c.Assert(err, jc.ErrorIsNil)
+	certPEM, keyPEM, err := cert.NewClient(caCert, caKey, time.Now().AddDate(0, 0, 1))
+	c.Assert(err, jc.ErrorIsNil)
+
+	resp := s.clientCertRequest(c, certPEM, keyPEM)
+	assertResponse(c, resp, http.StatusUnauthorized, "text/plain; charset=utf-8")
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+	"github.com/juju/juju/state"
+)
+
+// UnitUsage holds the consumption reported by the metrics of a unit
+// which have been sent to the collector.
+type UnitUsage struct {
+	Unit     string
+	CharmURL string
+
+	// Totals holds the sum of the values of each metric, keyed by
+	// metric name. Values which are not numbers are ignored.
+	Totals map[string]float64
+}
+
+// BudgetDecision holds the actions a BudgetPolicy requires to keep
+// consumption within its allocation.
+type BudgetDecision struct {
+	// MeterStatuses holds the meter statuses to set, keyed by unit
+	// name. They take precedence over those returned by the
+	// collector.
+	MeterStatuses map[string]wireformat.UnitStatus
+
+	// SuspendProvisioning, if not empty, suspends the environment,
+	// so that no machines are provisioned for new units, giving the
+	// value as the reason. An operator must resume the environment
+	// once the allocation has been raised.
+	SuspendProvisioning string
+}
+
+// BudgetPolicy evaluates the consumption reported by metrics against
+// an allocation.
+type BudgetPolicy interface {
+	// Evaluate is called with the usage of the metrics sent in a
+	// single call to SendMetrics, and returns the actions needed to
+	// enforce the allocation of the given environment.
+	Evaluate(envUUID string, usage []UnitUsage) (BudgetDecision, error)
+}
+
+// NopBudgetPolicy is a BudgetPolicy which never enforces anything.
+type NopBudgetPolicy struct{}
+
+// Evaluate is part of the BudgetPolicy interface.
+func (NopBudgetPolicy) Evaluate(string, []UnitUsage) (BudgetDecision, error) {
+	return BudgetDecision{}, nil
+}
+
+// usageCollector sums the metrics of sent batches by unit.
+type usageCollector map[string]*UnitUsage
+
+func (u usageCollector) add(batch *state.MetricBatch) {
+	usage, ok := u[batch.Unit()]
+	if !ok {
+		usage = &UnitUsage{
+			Unit:   batch.Unit(),
+			Totals: make(map[string]float64),
+		}
+		u[batch.Unit()] = usage
+	}
+	usage.CharmURL = batch.CharmURL()
+	for _, metric := range batch.Metrics() {
+		value, err := strconv.ParseFloat(metric.Value, 64)
+		if err != nil {
+			continue
+		}
+		usage.Totals[metric.Key] += value
+	}
+}
+
+// usage returns the collected usage, ordered by unit name.
+func (u usageCollector) usage() []UnitUsage {
+	unitNames := make([]string, 0, len(u))
+	for name := range u {
+		unitNames = append(unitNames, name)
+	}
+	sort.Strings(unitNames)
+	result := make([]UnitUsage, len(unitNames))
+	for i, name := range unitNames {
+		result[i] = *u[name]
+	}
+	return result
+}
+
+// enforceBudget evaluates the given usage with policy, and carries out
+// the actions it decides on.
+func enforceBudget(st *state.State, policy BudgetPolicy, usage []UnitUsage) error {
+	decision, err := policy.Evaluate(st.EnvironUUID(), usage)
+	if err != nil {
+		return errors.Annotate(err, "cannot evaluate budget policy")
+	}
+	setMeterStatuses(st, decision.MeterStatuses)
+	if decision.SuspendProvisioning == "" {
+		return nil
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if suspended, _ := env.Suspended(); suspended {
+		return nil
+	}
+	sendLogger.Warningf("suspending environment: %s", decision.SuspendProvisioning)
+	return errors.Trace(env.Suspend(decision.SuspendProvisioning))
+}
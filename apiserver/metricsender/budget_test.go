@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package metricsender_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/metricsender"
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type BudgetPolicySuite struct {
+	jujutesting.JujuConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&BudgetPolicySuite{})
+
+var _ metricsender.BudgetPolicy = metricsender.NopBudgetPolicy{}
+
+func (s *BudgetPolicySuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	meteredCharm := s.Factory.MakeCharm(c, &factory.CharmParams{Name: "metered", URL: "cs:quantal/metered"})
+	meteredService := s.Factory.MakeService(c, &factory.ServiceParams{Charm: meteredCharm})
+	s.unit = s.Factory.MakeUnit(c, &factory.UnitParams{Service: meteredService, SetCharmURL: true})
+}
+
+func (s *BudgetPolicySuite) makeMetric(c *gc.C, sent bool, metrics ...state.Metric) {
+	now := time.Now()
+	s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: sent, Time: &now, Metrics: metrics})
+}
+
+func (s *BudgetPolicySuite) TestPolicyEvaluatesSentUsage(c *gc.C) {
+	now := time.Now()
+	s.makeMetric(c, false, state.Metric{"pings", "5", now}, state.Metric{"pongs", "not-a-number", now})
+	s.makeMetric(c, false, state.Metric{"pings", "7.5", now})
+	s.makeMetric(c, true, state.Metric{"pings", "100", now})
+
+	policy := &mockBudgetPolicy{}
+	err := metricsender.SendMetrics(s.State, &metricsender.MockSender{}, policy, 1)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(policy.calls, gc.Equals, 1)
+	c.Assert(policy.envUUID, gc.Equals, s.State.EnvironUUID())
+	c.Assert(policy.usage, jc.DeepEquals, []metricsender.UnitUsage{{
+		Unit:     s.unit.Name(),
+		CharmURL: "cs:quantal/metered",
+		Totals:   map[string]float64{"pings": 12.5},
+	}})
+}
+
+func (s *BudgetPolicySuite) TestPolicySetsMeterStatus(c *gc.C) {
+	now := time.Now()
+	s.makeMetric(c, false, state.Metric{"pings", "5", now})
+	policy := &mockBudgetPolicy{
+		decision: metricsender.BudgetDecision{
+			MeterStatuses: map[string]wireformat.UnitStatus{
+				s.unit.Name(): {Status: "RED", Info: "allocation exhausted"},
+			},
+		},
+	}
+	err := metricsender.SendMetrics(s.State, &metricsender.MockSender{}, policy, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	code, info, err := s.unit.GetMeterStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(code, gc.Equals, "RED")
+	c.Assert(info, gc.Equals, "allocation exhausted")
+}
+
+func (s *BudgetPolicySuite) TestPolicySuspendsProvisioning(c *gc.C) {
+	now := time.Now()
+	s.makeMetric(c, false, state.Metric{"pings", "5", now})
+	policy := &mockBudgetPolicy{
+		decision: metricsender.BudgetDecision{SuspendProvisioning: "budget exceeded"},
+	}
+	err := metricsender.SendMetrics(s.State, &metricsender.MockSender{}, policy, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "budget exceeded")
+}
+
+func (s *BudgetPolicySuite) TestPolicyErrorDoesNotFailSend(c *gc.C) {
+	now := time.Now()
+	s.makeMetric(c, false, state.Metric{"pings", "5", now})
+	policy := &mockBudgetPolicy{err: errors.New("allocation service unavailable")}
+	err := metricsender.SendMetrics(s.State, &metricsender.MockSender{}, policy, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	sent, err := s.State.CountofSentMetrics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sent, gc.Equals, 1)
+	code, _, err := s.unit.GetMeterStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(code, gc.Equals, "NOT SET")
+}
+
+type mockBudgetPolicy struct {
+	calls    int
+	envUUID  string
+	usage    []metricsender.UnitUsage
+	decision metricsender.BudgetDecision
+	err      error
+}
+
+func (p *mockBudgetPolicy) Evaluate(envUUID string, usage []metricsender.UnitUsage) (metricsender.BudgetDecision, error) {
+	p.calls++
+	p.envUUID = envUUID
+	p.usage = usage
+	return p.decision, p.err
+}
@@ -6,6 +6,8 @@
 package metricsender
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
@@ -21,12 +23,28 @@ type MetricSender interface {
 	Send([]*wireformat.MetricBatch) (*wireformat.Response, error)
 }
 
+// notAcknowledged is recorded as the delivery error of batches which
+// the collector did not acknowledge.
+const notAcknowledged = "not acknowledged by the collector"
+
 // SendMetrics will send any unsent metrics
 // over the MetricSender interface in batches
-// no larger than batchSize.
-func SendMetrics(st *state.State, sender MetricSender, batchSize int) error {
+// no larger than batchSize. The usage reported by
+// the metrics sent is then evaluated by policy,
+// if it is not nil.
+//
+// Batches stay queued in state until the collector
+// acknowledges them, and each attempt to send them
+// is recorded there, so a batch whose sending was
+// interrupted is sent again by whichever state server
+// sends metrics next. Each batch is sent at most once
+// per call.
+func SendMetrics(st *state.State, sender MetricSender, policy BudgetPolicy, batchSize int) error {
+	sentUsage := make(usageCollector)
+	// Attempt times are stored to the millisecond.
+	start := time.Now().UTC().Truncate(time.Millisecond)
 	for {
-		metrics, err := st.MetricsToSend(batchSize)
+		metrics, err := st.MetricBatchesToDeliver(batchSize, start)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -35,12 +53,22 @@ func SendMetrics(st *state.State, sender MetricSender, batchSize int) error {
 			break
 		}
 		wireData := make([]*wireformat.MetricBatch, len(metrics))
+		batches := make(map[string]*state.MetricBatch)
+		uuids := make([]string, len(metrics))
 		for i, m := range metrics {
 			wireData[i] = wireformat.ToWire(m)
+			batches[m.UUID()] = m
+			uuids[i] = m.UUID()
+		}
+		if err := st.SetMetricBatchesAttempted(uuids, start); err != nil {
+			return errors.Trace(err)
 		}
 		response, err := sender.Send(wireData)
 		if err != nil {
 			sendLogger.Errorf("%+v", err)
+			if err := st.SetMetricBatchesDeliveryError(uuids, err.Error()); err != nil {
+				sendLogger.Errorf("failed to record metrics delivery error: %v", err)
+			}
 			return errors.Trace(err)
 		}
 		if response != nil {
@@ -49,19 +77,31 @@ func SendMetrics(st *state.State, sender MetricSender, batchSize int) error {
 				if err != nil {
 					sendLogger.Errorf("failed to set sent on metrics %v", err)
 				}
-				for unitName, status := range envResp.UnitStatuses {
-					unit, err := st.Unit(unitName)
-					if err != nil {
-						sendLogger.Errorf("failed to retrieve unit %q: %v", unitName, err)
-					} else {
-						err = unit.SetMeterStatus(status.Status, status.Info)
-						if err != nil {
-							sendLogger.Errorf("failed to set unit %q meter status to %v: %v", unitName, status, err)
-						}
+				for _, batchUUID := range envResp.AcknowledgedBatches {
+					if batch, ok := batches[batchUUID]; ok {
+						sentUsage.add(batch)
+						delete(batches, batchUUID)
 					}
 				}
+				setMeterStatuses(st, envResp.UnitStatuses)
 			}
 		}
+		if len(batches) > 0 {
+			var unacknowledged []string
+			for batchUUID := range batches {
+				unacknowledged = append(unacknowledged, batchUUID)
+			}
+			sendLogger.Warningf("%d metric batches were not acknowledged by the collector", len(unacknowledged))
+			if err := st.SetMetricBatchesDeliveryError(unacknowledged, notAcknowledged); err != nil {
+				sendLogger.Errorf("failed to record metrics delivery error: %v", err)
+			}
+		}
+	}
+
+	if policy != nil {
+		if err := enforceBudget(st, policy, sentUsage.usage()); err != nil {
+			sendLogger.Errorf("failed to enforce budget: %v", err)
+		}
 	}
 
 	unsent, err := st.CountofUnsentMetrics()
@@ -76,3 +116,19 @@ func SendMetrics(st *state.State, sender MetricSender, batchSize int) error {
 
 	return nil
 }
+
+// setMeterStatuses sets the meter statuses of the given units, keyed
+// by unit name.
+func setMeterStatuses(st *state.State, statuses map[string]wireformat.UnitStatus) {
+	for unitName, status := range statuses {
+		unit, err := st.Unit(unitName)
+		if err != nil {
+			sendLogger.Errorf("failed to retrieve unit %q: %v", unitName, err)
+			continue
+		}
+		err = unit.SetMeterStatus(status.Status, status.Info)
+		if err != nil {
+			sendLogger.Errorf("failed to set unit %q meter status to %v: %v", unitName, status, err)
+		}
+	}
+}
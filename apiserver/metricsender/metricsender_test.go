@@ -6,10 +6,12 @@ package metricsender_test
 import (
 	"time"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/metricsender"
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
@@ -42,7 +44,7 @@ func (s *MetricSenderSuite) TestSendMetrics(c *gc.C) {
 	unsent1 := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now})
 	unsent2 := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now})
 	s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: true, Time: &now})
-	err := metricsender.SendMetrics(s.State, &sender, 10)
+	err := metricsender.SendMetrics(s.State, &sender, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(sender.Data, gc.HasLen, 1)
 	c.Assert(sender.Data[0], gc.HasLen, 2)
@@ -66,7 +68,7 @@ func (s *MetricSenderSuite) TestSendBulkMetrics(c *gc.C) {
 	for i := 0; i < 100; i++ {
 		s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now})
 	}
-	err := metricsender.SendMetrics(s.State, &sender, 10)
+	err := metricsender.SendMetrics(s.State, &sender, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 
 	c.Assert(sender.Data, gc.HasLen, 10)
@@ -82,9 +84,77 @@ func (s *MetricSenderSuite) TestDontSendWithNopSender(c *gc.C) {
 	for i := 0; i < 3; i++ {
 		s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: false, Time: &now})
 	}
-	err := metricsender.SendMetrics(s.State, metricsender.NopSender{}, 10)
+	err := metricsender.SendMetrics(s.State, metricsender.NopSender{}, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 	sent, err := s.State.CountofSentMetrics()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(sent, gc.Equals, 3)
 }
+
+// ignoringSender receives metric batches without acknowledging any
+// of them.
+type ignoringSender struct {
+	Data [][]*wireformat.MetricBatch
+}
+
+// Send implements the Send interface.
+func (s *ignoringSender) Send(d []*wireformat.MetricBatch) (*wireformat.Response, error) {
+	s.Data = append(s.Data, d)
+	return &wireformat.Response{}, nil
+}
+
+// TestUnacknowledgedMetricsStayQueued checks that batches the
+// collector does not acknowledge are attempted once per call,
+// recorded as failed, and retried by the next call.
+func (s *MetricSenderSuite) TestUnacknowledgedMetricsStayQueued(c *gc.C) {
+	var sender ignoringSender
+	now := time.Now()
+	unsent := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now})
+	err := metricsender.SendMetrics(s.State, &sender, nil, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sender.Data, gc.HasLen, 1)
+
+	batch, err := s.State.MetricBatch(unsent.UUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batch.Sent(), jc.IsFalse)
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricFailed)
+	c.Assert(batch.DeliveryError(), gc.Equals, "not acknowledged by the collector")
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 1)
+
+	// Ensure the next call starts after the recorded attempt.
+	time.Sleep(time.Millisecond)
+	var mock metricsender.MockSender
+	err = metricsender.SendMetrics(s.State, &mock, nil, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mock.Data, gc.HasLen, 1)
+
+	batch, err = s.State.MetricBatch(unsent.UUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricAcknowledged)
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 2)
+}
+
+// TestSendErrorRecordedOnBatches checks that a failure to reach
+// the collector is recorded against the batches sent.
+func (s *MetricSenderSuite) TestSendErrorRecordedOnBatches(c *gc.C) {
+	now := time.Now()
+	unsent := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now})
+	sender := erroringSender{errors.New("collector unavailable")}
+	err := metricsender.SendMetrics(s.State, sender, nil, 10)
+	c.Assert(err, gc.ErrorMatches, "collector unavailable")
+
+	batch, err := s.State.MetricBatch(unsent.UUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricFailed)
+	c.Assert(batch.DeliveryError(), gc.Equals, "collector unavailable")
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 1)
+}
+
+type erroringSender struct {
+	err error
+}
+
+// Send implements the Send interface.
+func (s erroringSender) Send([]*wireformat.MetricBatch) (*wireformat.Response, error) {
+	return nil, s.err
+}
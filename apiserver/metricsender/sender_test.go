@@ -84,7 +84,7 @@ func (s *SenderSuite) TestDefaultSender(c *gc.C) {
 		metrics[i] = s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: false, Time: &now})
 	}
 	var sender metricsender.DefaultSender
-	err := metricsender.SendMetrics(s.State, &sender, 10)
+	err := metricsender.SendMetrics(s.State, &sender, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 
 	c.Assert(receiverChan, gc.HasLen, metricCount)
@@ -166,7 +166,7 @@ func (s *SenderSuite) TestErrorCodes(c *gc.C) {
 			batches[i] = s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: false, Time: &now})
 		}
 		var sender metricsender.DefaultSender
-		err := metricsender.SendMetrics(s.State, &sender, 10)
+		err := metricsender.SendMetrics(s.State, &sender, nil, 10)
 		c.Assert(err, gc.ErrorMatches, test.expectedErr)
 		for _, batch := range batches {
 			m, err := s.State.MetricBatch(batch.UUID())
@@ -196,7 +196,7 @@ func (s *SenderSuite) TestMeterStatus(c *gc.C) {
 	c.Assert(info, gc.Equals, "")
 
 	var sender metricsender.DefaultSender
-	err = metricsender.SendMetrics(s.State, &sender, 10)
+	err = metricsender.SendMetrics(s.State, &sender, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 
 	status, info, err = s.unit.GetMeterStatus()
@@ -243,7 +243,7 @@ func (s *SenderSuite) TestMeterStatusInvalid(c *gc.C) {
 	}
 
 	var sender metricsender.DefaultSender
-	err := metricsender.SendMetrics(s.State, &sender, 10)
+	err := metricsender.SendMetrics(s.State, &sender, nil, 10)
 	c.Assert(err, jc.ErrorIsNil)
 
 	status, info, err := unit1.GetMeterStatus()
@@ -21,8 +21,19 @@ var (
 	maxBatchesPerSend = 1000
 
 	sender metricsender.MetricSender = &metricsender.NopSender{}
+
+	budgetPolicy metricsender.BudgetPolicy = metricsender.NopBudgetPolicy{}
 )
 
+// SetBudgetPolicy sets the policy which evaluates the usage reported
+// by metrics against an allocation whenever they are sent, and
+// returns the previous one.
+func SetBudgetPolicy(policy metricsender.BudgetPolicy) metricsender.BudgetPolicy {
+	previous := budgetPolicy
+	budgetPolicy = policy
+	return previous
+}
+
 func init() {
 	common.RegisterStandardFacade("MetricsManager", 0, NewMetricsManagerAPI)
 }
@@ -31,6 +42,7 @@ func init() {
 type MetricsManager interface {
 	CleanupOldMetrics(arg params.Entities) (params.ErrorResults, error)
 	SendMetrics(args params.Entities) (params.ErrorResults, error)
+	MetricBatchDeliveries(args params.Entities) (params.MetricBatchDeliveryResults, error)
 }
 
 // MetricsManagerAPI implements the metrics manager interface and is the concrete
@@ -126,7 +138,7 @@ func (api *MetricsManagerAPI) SendMetrics(args params.Entities) (params.ErrorRes
 			result.Results[i].Error = common.ServerError(common.ErrPerm)
 			continue
 		}
-		err = metricsender.SendMetrics(api.state, sender, maxBatchesPerSend)
+		err = metricsender.SendMetrics(api.state, sender, budgetPolicy, maxBatchesPerSend)
 		if err != nil {
 			err = errors.Annotate(err, "failed to send metrics")
 			result.Results[i].Error = common.ServerError(err)
@@ -134,3 +146,49 @@ func (api *MetricsManagerAPI) SendMetrics(args params.Entities) (params.ErrorRes
 	}
 	return result, nil
 }
+
+// MetricBatchDeliveries returns the delivery state of each metric
+// batch of the given environments which is still stored in state.
+func (api *MetricsManagerAPI) MetricBatchDeliveries(args params.Entities) (params.MetricBatchDeliveryResults, error) {
+	result := params.MetricBatchDeliveryResults{
+		Results: make([]params.MetricBatchDeliveryResult, len(args.Entities)),
+	}
+	if len(args.Entities) == 0 {
+		return result, nil
+	}
+	canAccess, err := api.accessEnviron()
+	if err != nil {
+		return result, err
+	}
+	for i, arg := range args.Entities {
+		tag, err := names.ParseEnvironTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		if !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		batches, err := api.state.EnvironMetricBatches()
+		if err != nil {
+			err = errors.Annotate(err, "failed to get metric batches")
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		deliveries := make([]params.MetricBatchDelivery, len(batches))
+		for j, batch := range batches {
+			deliveries[j] = params.MetricBatchDelivery{
+				UUID:        batch.UUID(),
+				Unit:        batch.Unit(),
+				Created:     batch.Created(),
+				State:       string(batch.DeliveryState()),
+				Attempts:    batch.DeliveryAttempts(),
+				LastAttempt: batch.LastDeliveryAttempt(),
+				Error:       batch.DeliveryError(),
+			}
+		}
+		result.Results[i].Batches = deliveries
+	}
+	return result, nil
+}
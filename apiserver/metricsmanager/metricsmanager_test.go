@@ -13,6 +13,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/metricsender"
+	"github.com/juju/juju/apiserver/metricsender/wireformat"
 	"github.com/juju/juju/apiserver/metricsmanager"
 	"github.com/juju/juju/apiserver/params"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
@@ -136,6 +137,84 @@ func (s *metricsManagerSuite) TestSendMetrics(c *gc.C) {
 	c.Assert(m.Sent(), jc.IsTrue)
 }
 
+func (s *metricsManagerSuite) TestSendMetricsEvaluatesBudgetPolicy(c *gc.C) {
+	var sender metricsender.MockSender
+	metricsmanager.PatchSender(&sender)
+	policy := &recordingBudgetPolicy{}
+	previous := metricsmanager.SetBudgetPolicy(policy)
+	s.AddCleanup(func(*gc.C) { metricsmanager.SetBudgetPolicy(previous) })
+	now := time.Now()
+	metric := state.Metric{"pings", "5", now}
+	s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: false, Time: &now, Metrics: []state.Metric{metric}})
+	args := params.Entities{Entities: []params.Entity{
+		{s.State.EnvironTag().String()},
+	}}
+	result, err := s.metricsmanager.SendMetrics(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results[0], gc.DeepEquals, params.ErrorResult{Error: nil})
+	c.Assert(policy.usage, jc.DeepEquals, []metricsender.UnitUsage{{
+		Unit:     s.unit.Name(),
+		CharmURL: "cs:quantal/metered",
+		Totals:   map[string]float64{"pings": 5},
+	}})
+}
+
+type recordingBudgetPolicy struct {
+	usage []metricsender.UnitUsage
+}
+
+func (p *recordingBudgetPolicy) Evaluate(envUUID string, usage []metricsender.UnitUsage) (metricsender.BudgetDecision, error) {
+	p.usage = usage
+	return metricsender.BudgetDecision{}, nil
+}
+
+func (s *metricsManagerSuite) TestMetricBatchDeliveries(c *gc.C) {
+	metricsmanager.PatchSender(&failingSender{})
+	s.AddCleanup(func(*gc.C) { metricsmanager.PatchSender(&metricsender.NopSender{}) })
+	now := time.Now().Round(time.Second)
+	metric := state.Metric{"pings", "5", now}
+	sent := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: true, Time: &now, Metrics: []state.Metric{metric}})
+	later := now.Add(time.Second)
+	unsent := s.Factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: false, Time: &later, Metrics: []state.Metric{metric}})
+	args := params.Entities{Entities: []params.Entity{
+		{s.State.EnvironTag().String()},
+	}}
+	_, err := s.metricsmanager.SendMetrics(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.metricsmanager.MetricBatchDeliveries(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	batches := result.Results[0].Batches
+	c.Assert(batches, gc.HasLen, 2)
+	c.Assert(batches[0].UUID, gc.Equals, sent.UUID())
+	c.Assert(batches[0].State, gc.Equals, "acknowledged")
+	c.Assert(batches[0].Attempts, gc.Equals, 0)
+	c.Assert(batches[1].UUID, gc.Equals, unsent.UUID())
+	c.Assert(batches[1].Unit, gc.Equals, s.unit.Name())
+	c.Assert(batches[1].State, gc.Equals, "failed")
+	c.Assert(batches[1].Attempts, gc.Equals, 1)
+	c.Assert(batches[1].LastAttempt.IsZero(), jc.IsFalse)
+	c.Assert(batches[1].Error, gc.Equals, "collector unavailable")
+}
+
+func (s *metricsManagerSuite) TestMetricBatchDeliveriesInvalidArg(c *gc.C) {
+	args := params.Entities{Entities: []params.Entity{
+		{"invalid"},
+	}}
+	result, err := s.metricsmanager.MetricBatchDeliveries(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.DeepEquals, common.ServerError(common.ErrPerm))
+}
+
+type failingSender struct{}
+
+func (failingSender) Send([]*wireformat.MetricBatch) (*wireformat.Response, error) {
+	return nil, errors.New("collector unavailable")
+}
+
 func (s *metricsManagerSuite) TestSendOldMetricsInvalidArg(c *gc.C) {
 	args := params.Entities{Entities: []params.Entity{
 		{"invalid"},
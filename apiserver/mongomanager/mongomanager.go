@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package mongomanager provides the API used to inspect and tune the
+// mongo database deployed on the state servers.
+package mongomanager
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("MongoManager", 1, NewMongoManagerAPI)
+}
+
+// MongoManagerAPI implements the MongoManager facade.
+type MongoManagerAPI struct {
+	st   *state.State
+	auth common.Authorizer
+}
+
+// NewMongoManagerAPI creates a new server-side MongoManager facade.
+// It is available to clients, which may also change the settings, and
+// to state server agents.
+func NewMongoManagerAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*MongoManagerAPI, error) {
+	if !auth.AuthClient() && !auth.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &MongoManagerAPI{
+		st:   st,
+		auth: auth,
+	}, nil
+}
+
+// MongoConfig returns the current settings of the state servers'
+// mongo database.
+func (api *MongoManagerAPI) MongoConfig() (params.MongoConfig, error) {
+	cfg, err := api.st.EnvironConfig()
+	if err != nil {
+		return params.MongoConfig{}, errors.Trace(err)
+	}
+	return params.MongoConfig{
+		StorageEngine: cfg.MongoStorageEngine(),
+		OplogSizeMB:   cfg.MongoOplogSize(),
+		CacheSizeGB:   cfg.MongoCacheSize(),
+	}, nil
+}
+
+// SetMongoConfig changes the settings of the state servers' mongo
+// database. Only the settings which can be applied to a running
+// deployment may be changed; the state servers restart their mongo
+// databases one at a time to pick up the new settings.
+func (api *MongoManagerAPI) SetMongoConfig(args params.MongoConfig) error {
+	if !api.auth.AuthClient() {
+		return common.ErrPerm
+	}
+	cfg, err := api.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	attrs := make(map[string]interface{})
+	var remove []string
+	if args.StorageEngine != cfg.MongoStorageEngine() {
+		attrs[config.MongoStorageEngineKey] = args.StorageEngine
+	}
+	if args.OplogSizeMB != cfg.MongoOplogSize() {
+		attrs[config.MongoOplogSizeKey] = args.OplogSizeMB
+	}
+	if args.CacheSizeGB == 0 {
+		remove = append(remove, config.MongoCacheSizeKey)
+	} else if args.CacheSizeGB != cfg.MongoCacheSize() {
+		attrs[config.MongoCacheSizeKey] = args.CacheSizeGB
+	}
+	return api.st.UpdateEnvironConfig(attrs, remove, nil)
+}
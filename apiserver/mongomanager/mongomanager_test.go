@@ -0,0 +1,104 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongomanager_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/mongomanager"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type mongoManagerSuite struct {
+	jujutesting.JujuConnSuite
+
+	clientAPI *mongomanager.MongoManagerAPI
+	agentAPI  *mongomanager.MongoManagerAPI
+}
+
+var _ = gc.Suite(&mongoManagerSuite{})
+
+func (s *mongoManagerSuite) SetUpTest(c *gc.C) {
+	s.DummyConfig = dummy.SampleConfig().Merge(coretesting.Attrs{
+		"mongo-storage-engine": "wiredTiger",
+	})
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.clientAPI, err = mongomanager.NewMongoManagerAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.agentAPI, err = mongomanager.NewMongoManagerAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *mongoManagerSuite) TestNewAPIRefusesHostUnitsAgent(c *gc.C) {
+	_, err := mongomanager.NewMongoManagerAPI(s.State, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *mongoManagerSuite) TestMongoConfig(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"mongo-cache-size": 2,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.agentAPI.MongoConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.MongoConfig{
+		StorageEngine: "wiredTiger",
+		CacheSizeGB:   2,
+	})
+}
+
+func (s *mongoManagerSuite) TestSetMongoConfigCacheSize(c *gc.C) {
+	err := s.clientAPI.SetMongoConfig(params.MongoConfig{
+		StorageEngine: "wiredTiger",
+		CacheSizeGB:   4,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.MongoCacheSize(), gc.Equals, 4)
+
+	err = s.clientAPI.SetMongoConfig(params.MongoConfig{
+		StorageEngine: "wiredTiger",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err = s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.MongoCacheSize(), gc.Equals, 0)
+}
+
+func (s *mongoManagerSuite) TestSetMongoConfigStorageEngineImmutable(c *gc.C) {
+	err := s.clientAPI.SetMongoConfig(params.MongoConfig{
+		StorageEngine: "mmapv1",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot change mongo-storage-engine from "wiredTiger" to "mmapv1"`)
+}
+
+func (s *mongoManagerSuite) TestSetMongoConfigNegativeCacheSize(c *gc.C) {
+	err := s.clientAPI.SetMongoConfig(params.MongoConfig{
+		StorageEngine: "wiredTiger",
+		CacheSizeGB:   -1,
+	})
+	c.Assert(err, gc.ErrorMatches, `.*size must be positive`)
+}
+
+func (s *mongoManagerSuite) TestSetMongoConfigRefusesAgent(c *gc.C) {
+	err := s.agentAPI.SetMongoConfig(params.MongoConfig{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,211 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package osupdates provides the API used by machine agents to report
+// the security updates pending on their machines and to apply them
+// when asked, and by clients to apply them across the environment in
+// rolling patch runs.
+package osupdates
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("OSUpdates", 1, NewOSUpdatesAPI)
+}
+
+// OSUpdatesAPI implements the OSUpdates facade.
+type OSUpdatesAPI struct {
+	st         *state.State
+	resources  *common.Resources
+	authorizer common.Authorizer
+}
+
+// NewOSUpdatesAPI creates a new server-side OSUpdates facade.
+func NewOSUpdatesAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*OSUpdatesAPI, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &OSUpdatesAPI{
+		st:         st,
+		resources:  resources,
+		authorizer: authorizer,
+	}, nil
+}
+
+// getMachine returns the machine with the given tag, if the
+// authenticated agent is that machine's.
+func (api *OSUpdatesAPI) getMachine(tag string) (*state.Machine, error) {
+	machineTag, err := names.ParseMachineTag(tag)
+	if err != nil || !api.authorizer.AuthMachineAgent() || !api.authorizer.AuthOwner(machineTag) {
+		return nil, common.ErrPerm
+	}
+	return api.st.Machine(machineTag.Id())
+}
+
+// SetSecurityUpdates records the names of the packages with pending
+// security updates on each of the given machines.
+func (api *OSUpdatesAPI) SetSecurityUpdates(args params.SetSecurityUpdates) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Machines)),
+	}
+	for i, arg := range args.Machines {
+		machine, err := api.getMachine(arg.Tag)
+		if err == nil {
+			err = machine.SetSecurityUpdates(arg.Packages)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchPatchRequests returns a watcher for each of the given machines
+// that notifies when the machine's agent is asked to apply updates.
+func (api *OSUpdatesAPI) WatchPatchRequests(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getMachine(entity.Tag)
+		if err == nil {
+			w := machine.WatchOSUpdates()
+			// Consume the initial event; the agent gets the
+			// current request itself.
+			if _, ok := <-w.Changes(); ok {
+				result.Results[i].NotifyWatcherId = api.resources.Register(w)
+			} else {
+				err = watcher.EnsureErr(w)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// PatchRequests returns, for each of the given machines, the patch run
+// its agent has been asked to apply updates for and its progress in
+// it. A machine that is not being patched gets a NotFound error.
+func (api *OSUpdatesAPI) PatchRequests(args params.Entities) (params.PatchRequestResults, error) {
+	result := params.PatchRequestResults{
+		Results: make([]params.PatchRequestResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getMachine(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		run, status, err := machine.PatchRequest()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = &params.PatchRequest{
+			Run:    run,
+			Status: string(status.Status),
+		}
+	}
+	return result, nil
+}
+
+// SetPatchStatus records the progress of each of the given machines in
+// the patch run its agent was asked to apply updates for.
+func (api *OSUpdatesAPI) SetPatchStatus(args params.SetPatchStatus) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Machines)),
+	}
+	for i, arg := range args.Machines {
+		machine, err := api.getMachine(arg.Tag)
+		if err == nil {
+			err = machine.SetPatchStatus(state.PatchStatus(arg.Status), arg.Info)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SecurityUpdates returns the security updates pending on each machine
+// whose agent has reported them.
+func (api *OSUpdatesAPI) SecurityUpdates() (params.MachineOSUpdatesResults, error) {
+	if !api.authorizer.AuthClient() {
+		return params.MachineOSUpdatesResults{}, common.ErrPerm
+	}
+	all, err := api.st.AllOSUpdates()
+	if err != nil {
+		return params.MachineOSUpdatesResults{}, errors.Trace(err)
+	}
+	result := params.MachineOSUpdatesResults{
+		Results: make([]params.MachineOSUpdates, len(all)),
+	}
+	for i, updates := range all {
+		result.Results[i] = params.MachineOSUpdates{
+			MachineId:       updates.MachineId,
+			SecurityUpdates: updates.SecurityUpdates,
+			Checked:         updates.Checked,
+			PatchRun:        updates.PatchRun,
+		}
+	}
+	return result, nil
+}
+
+// StartPatchRun starts a rolling run applying the pending security
+// updates to every machine that has any. Machines are patched, and
+// rebooted if the updates require it, a few at a time, while the
+// environment's maintenance window is open.
+func (api *OSUpdatesAPI) StartPatchRun(args params.StartPatchRun) (params.PatchRun, error) {
+	if !api.authorizer.AuthClient() {
+		return params.PatchRun{}, common.ErrPerm
+	}
+	run, err := api.st.StartPatchRun(args.MaxUnavailable)
+	if err != nil {
+		return params.PatchRun{}, errors.Trace(err)
+	}
+	return patchRunParams(run), nil
+}
+
+// PatchRuns returns the progress of every patch run in the
+// environment, most recent first.
+func (api *OSUpdatesAPI) PatchRuns() (params.PatchRunsResult, error) {
+	if !api.authorizer.AuthClient() {
+		return params.PatchRunsResult{}, common.ErrPerm
+	}
+	runs, err := api.st.PatchRuns()
+	if err != nil {
+		return params.PatchRunsResult{}, errors.Trace(err)
+	}
+	result := params.PatchRunsResult{
+		Runs: make([]params.PatchRun, len(runs)),
+	}
+	for i, run := range runs {
+		result.Runs[i] = patchRunParams(run)
+	}
+	return result, nil
+}
+
+func patchRunParams(run *state.PatchRun) params.PatchRun {
+	result := params.PatchRun{
+		Id:             run.Id(),
+		MaxUnavailable: run.MaxUnavailable(),
+		Status:         string(run.Status()),
+		Started:        run.Started(),
+	}
+	if finished := run.Finished(); !finished.IsZero() {
+		result.Finished = &finished
+	}
+	machines := run.Machines()
+	for _, id := range run.MachineIds() {
+		result.Machines = append(result.Machines, params.MachinePatchStatus{
+			Tag:    names.NewMachineTag(id).String(),
+			Status: string(machines[id].Status),
+			Info:   machines[id].Info,
+		})
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdates_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/osupdates"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type osUpdatesSuite struct {
+	jujutesting.JujuConnSuite
+	machine   *state.Machine
+	resources *common.Resources
+	agentAPI  *osupdates.OSUpdatesAPI
+	clientAPI *osupdates.OSUpdatesAPI
+}
+
+var _ = gc.Suite(&osUpdatesSuite{})
+
+func (s *osUpdatesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+
+	agent := apiservertesting.FakeAuthorizer{Tag: s.machine.Tag()}
+	s.agentAPI, err = osupdates.NewOSUpdatesAPI(s.State, s.resources, agent)
+	c.Assert(err, jc.ErrorIsNil)
+	client := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	s.clientAPI, err = osupdates.NewOSUpdatesAPI(s.State, s.resources, client)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *osUpdatesSuite) TestNewAPIRefusesUnitAgent(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewUnitTag("wordpress/0")}
+	_, err := osupdates.NewOSUpdatesAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *osUpdatesSuite) setSecurityUpdates(c *gc.C, packages ...string) {
+	results, err := s.agentAPI.SetSecurityUpdates(params.SetSecurityUpdates{
+		Machines: []params.MachineSecurityUpdates{{
+			Tag:      s.machine.Tag().String(),
+			Packages: packages,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+}
+
+func (s *osUpdatesSuite) TestSetSecurityUpdates(c *gc.C) {
+	s.setSecurityUpdates(c, "openssl")
+
+	result, err := s.clientAPI.SecurityUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].MachineId, gc.Equals, s.machine.Id())
+	c.Assert(result.Results[0].SecurityUpdates, jc.DeepEquals, []string{"openssl"})
+}
+
+func (s *osUpdatesSuite) TestAgentPermissions(c *gc.C) {
+	other, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	results, err := s.agentAPI.SetSecurityUpdates(params.SetSecurityUpdates{
+		Machines: []params.MachineSecurityUpdates{{Tag: other.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+
+	_, err = s.agentAPI.SecurityUpdates()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.agentAPI.StartPatchRun(params.StartPatchRun{MaxUnavailable: 1})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.agentAPI.PatchRuns()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	// Clients cannot act for machines.
+	results, err = s.clientAPI.SetSecurityUpdates(params.SetSecurityUpdates{
+		Machines: []params.MachineSecurityUpdates{{Tag: s.machine.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+}
+
+func (s *osUpdatesSuite) TestPatchRun(c *gc.C) {
+	s.setSecurityUpdates(c, "openssl")
+	args := params.Entities{Entities: []params.Entity{{Tag: s.machine.Tag().String()}}}
+
+	watchResults, err := s.agentAPI.WatchPatchRequests(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(watchResults.Results, gc.HasLen, 1)
+	c.Assert(watchResults.Results[0].Error, gc.IsNil)
+	w := s.resources.Get(watchResults.Results[0].NotifyWatcherId)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	requests, err := s.agentAPI.PatchRequests(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requests.Results[0].Error, jc.Satisfies, params.IsCodeNotFound)
+
+	run, err := s.clientAPI.StartPatchRun(params.StartPatchRun{MaxUnavailable: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(run.MaxUnavailable, gc.Equals, 2)
+	c.Assert(run.Status, gc.Equals, "running")
+	c.Assert(run.Machines, jc.DeepEquals, []params.MachinePatchStatus{{
+		Tag:    s.machine.Tag().String(),
+		Status: "pending",
+	}})
+
+	stateRun, err := s.State.PatchRun(run.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	err = stateRun.StartMachines(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	requests, err = s.agentAPI.PatchRequests(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requests.Results, jc.DeepEquals, []params.PatchRequestResult{{
+		Result: &params.PatchRequest{Run: run.Id, Status: "applying"},
+	}})
+
+	statusResults, err := s.agentAPI.SetPatchStatus(params.SetPatchStatus{
+		Machines: []params.MachinePatchStatus{{
+			Tag:    s.machine.Tag().String(),
+			Status: "failed",
+			Info:   "apt-get failed",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statusResults.OneError(), jc.ErrorIsNil)
+
+	runs, err := s.clientAPI.PatchRuns()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(runs.Runs, gc.HasLen, 1)
+	c.Assert(runs.Runs[0].Machines, jc.DeepEquals, []params.MachinePatchStatus{{
+		Tag:    s.machine.Tag().String(),
+		Status: "failed",
+		Info:   "apt-get failed",
+	}})
+}
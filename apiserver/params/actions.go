@@ -32,6 +32,14 @@ const (
 	ActionRunning string = "running"
 )
 
+const (
+	// ActionStdout identifies output written by an Action to stdout.
+	ActionStdout string = "stdout"
+
+	// ActionStderr identifies output written by an Action to stderr.
+	ActionStderr string = "stderr"
+)
+
 // Actions is a slice of Action for bulk requests.
 type Actions struct {
 	Actions []Action `json:"actions,omitempty"`
@@ -75,6 +83,33 @@ type ActionsByReceiver struct {
 	Error    *Error         `json:"error,omitempty"`
 }
 
+// ListActionsArgs holds the arguments for listing a page of actions.
+// Each non-empty filter must be matched for an action to be listed.
+type ListActionsArgs struct {
+	Page ListPage `json:"page"`
+
+	// Receivers restricts the list to actions queued for the
+	// entities with the given tags.
+	Receivers []string `json:"receivers,omitempty"`
+
+	// Services restricts the list to actions queued for units of
+	// the named services.
+	Services []string `json:"services,omitempty"`
+
+	// Statuses restricts the list to actions with one of the given
+	// statuses.
+	Statuses []string `json:"statuses,omitempty"`
+}
+
+// ListActionsResult holds a page of actions.
+type ListActionsResult struct {
+	Actions []ActionResult `json:"actions"`
+
+	// Next is the value to pass as After to list the next page,
+	// or empty if there are no more actions.
+	Next string `json:"next,omitempty"`
+}
+
 // ActionsQueryResults holds a slice of responses from the Actions
 // query.
 type ActionsQueryResults struct {
@@ -117,3 +152,103 @@ type ServiceCharmActionsResult struct {
 	Actions    *charm.Actions `json:"actions,omitempty"`
 	Error      *Error         `json:"error,omitempty"`
 }
+
+// ActionScheduleArgs holds the actions to schedule in a bulk API call.
+type ActionScheduleArgs struct {
+	Schedules []ActionScheduleArg `json:"schedules,omitempty"`
+}
+
+// ActionScheduleArg describes an action to be queued once at a future
+// time or, if Cron is not empty, repeatedly according to a cron
+// expression. The action's Tag is ignored.
+type ActionScheduleArg struct {
+	Action Action    `json:"action"`
+	At     time.Time `json:"at,omitempty"`
+	Cron   string    `json:"cron,omitempty"`
+}
+
+// ActionSchedule describes a scheduled action.
+type ActionSchedule struct {
+	Id         string    `json:"id"`
+	Action     Action    `json:"action"`
+	Cron       string    `json:"cron,omitempty"`
+	NextRun    time.Time `json:"nextrun"`
+	Created    time.Time `json:"created"`
+	LastRun    time.Time `json:"lastrun,omitempty"`
+	LastAction string    `json:"lastaction,omitempty"`
+}
+
+// ActionScheduleResults holds a slice of ActionScheduleResult for bulk
+// requests.
+type ActionScheduleResults struct {
+	Results []ActionScheduleResult `json:"results,omitempty"`
+}
+
+// ActionScheduleResult holds a scheduled action or an error.
+type ActionScheduleResult struct {
+	Schedule *ActionSchedule `json:"schedule,omitempty"`
+	Error    *Error          `json:"error,omitempty"`
+}
+
+// ActionScheduleIds holds the ids of action schedules.
+type ActionScheduleIds struct {
+	Ids []string `json:"ids,omitempty"`
+}
+
+// ActionScheduleDispatchResult holds the outcome of dispatching the
+// action schedules which are due: the tags of the actions queued, and
+// the time at which the next schedule is due, which is zero if there
+// are no schedules.
+type ActionScheduleDispatchResult struct {
+	Actions []Entity  `json:"actions,omitempty"`
+	NextRun time.Time `json:"nextrun,omitempty"`
+}
+
+// ActionOutputArgs holds chunks of output written by running actions,
+// to be recorded in a bulk API call.
+type ActionOutputArgs struct {
+	Output []ActionOutputArg `json:"output,omitempty"`
+}
+
+// ActionOutputArg holds a chunk of output written by a running action
+// to one of ActionStdout or ActionStderr.
+type ActionOutputArg struct {
+	ActionTag string `json:"actiontag"`
+	Stream    string `json:"stream"`
+	Data      string `json:"data"`
+}
+
+// ActionOutputQueries holds the actions whose output is requested in a
+// bulk API call.
+type ActionOutputQueries struct {
+	Queries []ActionOutputQuery `json:"queries,omitempty"`
+}
+
+// ActionOutputQuery requests the output of an action, starting at the
+// chunk with sequence number From.
+type ActionOutputQuery struct {
+	ActionTag string `json:"actiontag"`
+	From      int    `json:"from,omitempty"`
+}
+
+// ActionOutputChunk holds a chunk of output written by an action.
+type ActionOutputChunk struct {
+	Seq      int       `json:"seq"`
+	Stream   string    `json:"stream"`
+	Data     string    `json:"data"`
+	Recorded time.Time `json:"recorded"`
+}
+
+// ActionOutputResults holds a slice of ActionOutputResult for bulk
+// requests.
+type ActionOutputResults struct {
+	Results []ActionOutputResult `json:"results,omitempty"`
+}
+
+// ActionOutputResult holds the output written by an action and the
+// action's status at the time the output was read, or an error.
+type ActionOutputResult struct {
+	Status string              `json:"status,omitempty"`
+	Chunks []ActionOutputChunk `json:"chunks,omitempty"`
+	Error  *Error              `json:"error,omitempty"`
+}
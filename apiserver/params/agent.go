@@ -0,0 +1,32 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/utils/proxy"
+)
+
+// AgentConfigSettings holds the environment settings an agent records
+// in its configuration file, so that they are in effect as soon as the
+// agent starts.
+type AgentConfigSettings struct {
+	// LoggingConfig holds the environment's logging configuration.
+	LoggingConfig string `json:"logging-config"`
+
+	// Proxy holds the environment's proxy settings.
+	Proxy proxy.Settings `json:"proxy"`
+}
+
+// LogRecord is used to transmit a single log message from an agent
+// to the logsink API endpoint.
+type LogRecord struct {
+	Time     time.Time   `json:"t"`
+	Module   string      `json:"m"`
+	Location string      `json:"l"`
+	Level    loggo.Level `json:"v"`
+	Message  string      `json:"x"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// AgentLivenessResult holds the liveness of a machine or unit agent.
+type AgentLivenessResult struct {
+	// Alive reports whether the agent is connected to an API server.
+	Alive bool `json:"alive"`
+
+	// LastSeen holds when the agent was last heard from by an API
+	// server, or nil if that is not known.
+	LastSeen *time.Time `json:"last-seen,omitempty"`
+
+	Error *Error `json:"error,omitempty"`
+}
+
+// AgentLivenessResults holds the result of an API call to get the
+// liveness of agents.
+type AgentLivenessResults struct {
+	Results []AgentLivenessResult `json:"results"`
+}
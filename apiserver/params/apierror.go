@@ -34,6 +34,7 @@ func (e Error) GoString() string {
 }
 
 // The Code constants hold error codes for some kinds of error.
+// Every code must be registered in errorcodes.go.
 const (
 	CodeNotFound              = "not found"
 	CodeUnauthorized          = "unauthorized access"
@@ -54,6 +55,14 @@ const (
 	CodeActionNotAvailable    = "action no longer available"
 	CodeOperationBlocked      = "operation is blocked"
 	CodeLeadershipClaimDenied = "leadership claim denied"
+	CodePrecheckFailed        = "precheck failed"
+	CodeConfigInvalid         = "config invalid"
+	CodeEnvironmentSuspended  = "environment suspended"
+	CodeQuotaLimitExceeded    = "quota limit exceeded"
+	CodeRelationLimitExceeded = "relation limit exceeded"
+	CodeUnitLimitExceeded     = "unit limit exceeded"
+	CodeIncompatibleCharm     = "incompatible charm"
+	CodeWrongLifecycle        = "wrong lifecycle"
 )
 
 // ErrCode returns the error code associated with
@@ -165,6 +174,38 @@ func IsCodeOperationBlocked(err error) bool {
 	return ErrCode(err) == CodeOperationBlocked
 }
 
+func IsCodePrecheckFailed(err error) bool {
+	return ErrCode(err) == CodePrecheckFailed
+}
+
 func IsCodeLeadershipClaimDenied(err error) bool {
 	return ErrCode(err) == CodeLeadershipClaimDenied
 }
+
+func IsCodeConfigInvalid(err error) bool {
+	return ErrCode(err) == CodeConfigInvalid
+}
+
+func IsCodeEnvironmentSuspended(err error) bool {
+	return ErrCode(err) == CodeEnvironmentSuspended
+}
+
+func IsCodeQuotaLimitExceeded(err error) bool {
+	return ErrCode(err) == CodeQuotaLimitExceeded
+}
+
+func IsCodeRelationLimitExceeded(err error) bool {
+	return ErrCode(err) == CodeRelationLimitExceeded
+}
+
+func IsCodeUnitLimitExceeded(err error) bool {
+	return ErrCode(err) == CodeUnitLimitExceeded
+}
+
+func IsCodeIncompatibleCharm(err error) bool {
+	return ErrCode(err) == CodeIncompatibleCharm
+}
+
+func IsCodeWrongLifecycle(err error) bool {
+	return ErrCode(err) == CodeWrongLifecycle
+}
@@ -22,3 +22,31 @@ func (*errorSuite) TestErrCode(c *gc.C) {
 	err = errors.Trace(err)
 	c.Check(params.ErrCode(err), gc.Equals, params.CodeDead)
 }
+
+func (*errorSuite) TestErrorCodes(c *gc.C) {
+	infos := params.ErrorCodes()
+	c.Assert(infos, gc.Not(gc.HasLen), 0)
+	for i, info := range infos {
+		if i > 0 {
+			c.Check(infos[i-1].Code < info.Code, gc.Equals, true)
+		}
+		c.Check(info.Since > 0 && info.Since <= params.ErrorCodesVersion, gc.Equals, true)
+		found, ok := params.LookupErrorCode(info.Code)
+		c.Check(ok, gc.Equals, true)
+		c.Check(found, gc.Equals, info)
+	}
+	info, ok := params.LookupErrorCode(params.CodeWrongLifecycle)
+	c.Assert(ok, gc.Equals, true)
+	c.Assert(info.Since, gc.Equals, 2)
+
+	_, ok = params.LookupErrorCode("no such code")
+	c.Assert(ok, gc.Equals, false)
+}
+
+func (*errorSuite) TestIsCode(c *gc.C) {
+	err := errors.Annotate(&params.Error{Code: params.CodeNotProvisioned}, "oops")
+	c.Check(params.IsCode(err, params.CodeNotProvisioned), gc.Equals, true)
+	c.Check(params.IsCode(err, params.CodeQuotaLimitExceeded), gc.Equals, false)
+	c.Check(params.IsCode(nil, params.CodeNotProvisioned), gc.Equals, false)
+	c.Check(func() { params.IsCode(err, "no such code") }, gc.PanicMatches, `unknown error code "no such code"`)
+}
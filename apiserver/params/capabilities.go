@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/storage"
+)
+
+// EnvironCapabilities describes the features of an environment, so that
+// clients can reject operations the environment does not support
+// before attempting them.
+type EnvironCapabilities struct {
+	// Architectures holds the architectures of the images the
+	// environment can host.
+	Architectures []string `json:"architectures"`
+
+	// UnitPlacement reports whether machines may be created without
+	// units, and units placed explicitly. If not, UnitPlacementError
+	// explains why.
+	UnitPlacement      bool   `json:"unit-placement"`
+	UnitPlacementError string `json:"unit-placement-error,omitempty"`
+
+	// Containers holds the kinds of container that may be created on
+	// the environment's machines.
+	Containers []string `json:"containers"`
+
+	// Networking reports whether the environment supports querying
+	// subnets and network interfaces, and AddressAllocation whether
+	// static addresses may be allocated.
+	Networking        bool `json:"networking"`
+	AddressAllocation bool `json:"address-allocation"`
+
+	// StorageProviders holds the types of storage provider which may
+	// be used in the environment, and StorageKinds the kinds of
+	// storage ("block", "filesystem") they can provide between them.
+	StorageProviders []string `json:"storage-providers"`
+	StorageKinds     []string `json:"storage-kinds"`
+
+	// FirewallMode is the firewall mode the environment was
+	// configured with, and FirewallModes those it supports.
+	FirewallMode  string   `json:"firewall-mode"`
+	FirewallModes []string `json:"firewall-modes"`
+}
+
+// CharmCompatibilityCheck holds the details of a prospective
+// deployment of a charm, or upgrade of a service to it, to be checked
+// against the environment.
+type CharmCompatibilityCheck struct {
+	CharmURL string `json:"charm-url"`
+
+	// ServiceName, if set, names the service being upgraded.
+	ServiceName string `json:"service-name,omitempty"`
+
+	// ToMachineSpec, Constraints, Networks and Storage are as
+	// given to ServiceDeploy; Networks holds network tags.
+	ToMachineSpec string                         `json:"to-machine-spec,omitempty"`
+	Constraints   constraints.Value              `json:"constraints"`
+	Networks      []string                       `json:"networks,omitempty"`
+	Storage       map[string]storage.Constraints `json:"storage,omitempty"`
+}
+
+// CharmCompatibilityChecks holds the arguments for
+// CheckCharmCompatibility.
+type CharmCompatibilityChecks struct {
+	Checks []CharmCompatibilityCheck `json:"checks"`
+}
+
+// CharmIncompatibility describes one reason a charm cannot be
+// deployed as requested. Kind is one of "series", "storage" or
+// "network".
+type CharmIncompatibility struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// CharmCompatibilityReport holds the result of one compatibility
+// check. The charm is compatible if there is no error and no
+// incompatibilities are reported.
+type CharmCompatibilityReport struct {
+	Incompatibilities []CharmIncompatibility `json:"incompatibilities,omitempty"`
+	Error             *Error                 `json:"error,omitempty"`
+}
+
+// CharmCompatibilityReports holds the results of
+// CheckCharmCompatibility.
+type CharmCompatibilityReports struct {
+	Results []CharmCompatibilityReport `json:"results"`
+}
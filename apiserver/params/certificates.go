@@ -0,0 +1,23 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// RotateCertificateArgs holds the parameters for installing a new
+// state server certificate.
+type RotateCertificateArgs struct {
+	// Cert holds the PEM-encoded certificate the API
+	// servers will present to clients.
+	Cert string `json:"cert"`
+
+	// PrivateKey holds the PEM-encoded private key for Cert.
+	PrivateKey string `json:"private-key"`
+
+	// CACert optionally holds a PEM-encoded replacement CA
+	// certificate. If set, Cert must be signed by it, and
+	// CAPrivateKey must also be set.
+	CACert string `json:"ca-cert,omitempty"`
+
+	// CAPrivateKey holds the PEM-encoded private key for CACert.
+	CAPrivateKey string `json:"ca-private-key,omitempty"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// ServiceCharmRevision holds the revision of a service's charm and
+// the latest revision available to it in the charm store.
+type ServiceCharmRevision struct {
+	ServiceTag string `json:"service-tag"`
+	Channel    string `json:"channel"`
+	CharmURL   string `json:"charm-url"`
+
+	// LatestCharmURL holds the URL of the latest revision of the
+	// charm available in the channel the service tracks, if it is
+	// newer than CharmURL.
+	LatestCharmURL string `json:"latest-charm-url,omitempty"`
+
+	// LatestRevision holds the revision of LatestCharmURL, if set.
+	LatestRevision int `json:"latest-revision,omitempty"`
+}
+
+// ServiceCharmRevisions holds the charm revisions of the services in
+// an environment.
+type ServiceCharmRevisions struct {
+	Revisions []ServiceCharmRevision `json:"revisions"`
+}
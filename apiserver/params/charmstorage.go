@@ -0,0 +1,38 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// CharmUsage describes the storage used by one of an environment's
+// charms.
+type CharmUsage struct {
+	// URL identifies the charm.
+	URL string `json:"url"`
+
+	// Size is the size of the charm's archive in bytes.
+	Size int64 `json:"size"`
+
+	// RefCount is the number of services and units using the charm.
+	RefCount int `json:"refcount"`
+
+	// UnusedSince holds when the charm was first seen unused, if it
+	// is not in use.
+	UnusedSince *time.Time `json:"unused-since,omitempty"`
+}
+
+// CharmStorageUsage holds the result of an API call to report the
+// storage used by an environment's charm archives.
+type CharmStorageUsage struct {
+	// Size is the total size of the charm archives in bytes.
+	Size int64 `json:"size"`
+
+	// Quota is the maximum total size of the charm archives in
+	// bytes, or 0 if it is unlimited.
+	Quota int64 `json:"quota"`
+
+	Charms []CharmUsage `json:"charms"`
+}
@@ -0,0 +1,39 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// Cloud describes a cloud on which the state server may host
+// environments.
+type Cloud struct {
+	Name   string
+	Type   string
+	Config map[string]interface{}
+}
+
+// Clouds holds a list of clouds, to be added by AddClouds or as
+// returned by Clouds.
+type Clouds struct {
+	Clouds []Cloud
+}
+
+// CloudCredential holds a named credential a user holds for a cloud.
+type CloudCredential struct {
+	OwnerTag   string
+	Cloud      string
+	Name       string
+	Attributes map[string]string
+}
+
+// CloudCredentials holds the arguments for making an
+// UpdateCredentials API call.
+type CloudCredentials struct {
+	Credentials []CloudCredential
+}
+
+// CredentialValidity records whether the provider accepts an
+// environment's credentials, and if not, why.
+type CredentialValidity struct {
+	Valid  bool
+	Reason string
+}
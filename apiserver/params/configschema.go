@@ -0,0 +1,37 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// ConfigAttr describes a configuration attribute known to juju.
+type ConfigAttr struct {
+	Name string `json:"name"`
+
+	// Type holds the type of the attribute's value: "string",
+	// "bool", "int" or "uuid".
+	Type string `json:"type"`
+
+	// Group holds what the attribute configures: "environment" or
+	// "controller".
+	Group string `json:"group"`
+
+	// Default holds the value the attribute takes when it is not
+	// set, if it has one.
+	Default interface{} `json:"default,omitempty"`
+
+	// Immutable holds whether the attribute cannot be changed once
+	// the environment has been created.
+	Immutable bool `json:"immutable,omitempty"`
+
+	// Deprecated holds whether the attribute has been replaced by
+	// another.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	Description string `json:"description"`
+}
+
+// ConfigSchemaResult holds the configuration attributes known to juju,
+// ordered by name.
+type ConfigSchemaResult struct {
+	Attrs []ConfigAttr `json:"attrs"`
+}
@@ -0,0 +1,48 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// ConnectionDetails describes a connection to an API server.
+type ConnectionDetails struct {
+	// Id identifies the connection within the API server.
+	Id int64 `json:"id"`
+
+	// EntityTag holds the tag of the entity that logged in
+	// on the connection, or "<unknown>" if the connection
+	// has not yet logged in.
+	EntityTag string `json:"entity-tag"`
+
+	// EnvironTag holds the tag of the environment the
+	// connection was made to.
+	EnvironTag string `json:"environ-tag,omitempty"`
+
+	// RemoteAddr holds the address the connection came from.
+	RemoteAddr string `json:"remote-addr"`
+
+	// ConnectedAt holds the time the connection was made.
+	ConnectedAt time.Time `json:"connected-at"`
+
+	// LastActivity holds the time of the most recent request
+	// made on the connection.
+	LastActivity time.Time `json:"last-activity"`
+
+	// FacadeCalls holds the number of requests made on the
+	// connection, keyed by facade name.
+	FacadeCalls map[string]int `json:"facade-calls,omitempty"`
+}
+
+// ConnectionDetailsResults holds the result of an API call to
+// list the connections to an API server.
+type ConnectionDetailsResults struct {
+	Connections []ConnectionDetails `json:"connections"`
+}
+
+// DisconnectArgs holds the ids of connections to terminate.
+type DisconnectArgs struct {
+	Ids []int64 `json:"ids"`
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// ContainerAddressPool describes an address pool for the containers
+// attached to a bridge on a host machine.
+type ContainerAddressPool struct {
+	MachineTag string
+	Bridge     string
+	CIDR       string
+	Gateway    string
+	Reserved   []string
+}
+
+// ContainerAddressPools holds the arguments for adding or removing
+// container address pools. Only MachineTag and Bridge are used when
+// removing pools.
+type ContainerAddressPools struct {
+	Pools []ContainerAddressPool
+}
+
+// ContainerPoolAddress describes an address allocated to a container
+// from a ContainerAddressPool, and the host name the container is
+// known by at that address.
+type ContainerPoolAddress struct {
+	MachineTag string
+	Address    string
+	Hostname   string
+}
+
+// ContainerAddressPoolInfo describes a container address pool and the
+// addresses allocated from it.
+type ContainerAddressPoolInfo struct {
+	ContainerAddressPool
+	Addresses []ContainerPoolAddress
+}
+
+// ContainerAddressPoolsResult holds the container address pools for
+// the bridges on a host machine, or an error.
+type ContainerAddressPoolsResult struct {
+	Pools []ContainerAddressPoolInfo
+	Error *Error
+}
+
+// ContainerAddressPoolsResults holds the container address pools for
+// the bridges on multiple host machines.
+type ContainerAddressPoolsResults struct {
+	Results []ContainerAddressPoolsResult
+}
+
+// AllocateContainerPoolAddressArg holds the arguments for allocating
+// an address to a container from the address pool of a bridge on its
+// host machine.
+type AllocateContainerPoolAddressArg struct {
+	Tag      string
+	Bridge   string
+	Hostname string
+}
+
+// AllocateContainerPoolAddressArgs holds the arguments for allocating
+// addresses to multiple containers.
+type AllocateContainerPoolAddressArgs struct {
+	Args []AllocateContainerPoolAddressArg
+}
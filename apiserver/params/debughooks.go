@@ -0,0 +1,13 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// DebugHooksSession is sent by the API server's debughooks endpoint to
+// a waiting unit agent when a client starts a debug-hooks session with
+// its unit.
+type DebugHooksSession struct {
+	// Hooks holds the names of the hooks to debug. If it is
+	// empty, all hooks are debugged.
+	Hooks []string `json:"hooks"`
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ErrorCodesVersion is the current version of the set of error
+// codes. It is increased whenever codes are added, so clients can
+// tell which codes a server may send.
+const ErrorCodesVersion = 2
+
+// ErrorCodeInfo describes an error code that the API server
+// may set on an Error.
+type ErrorCodeInfo struct {
+	// Code holds the value of the Error's Code field.
+	Code string
+
+	// Since holds the ErrorCodesVersion in which the
+	// code was introduced.
+	Since int
+}
+
+// Is reports whether err carries the error code.
+func (info ErrorCodeInfo) Is(err error) bool {
+	return ErrCode(err) == info.Code
+}
+
+var errorCodes = make(map[string]ErrorCodeInfo)
+
+// registerErrorCodes records the given codes as introduced
+// in the given version of the error codes.
+func registerErrorCodes(since int, codes ...string) {
+	for _, code := range codes {
+		if _, ok := errorCodes[code]; ok {
+			panic(fmt.Sprintf("error code %q already registered", code))
+		}
+		errorCodes[code] = ErrorCodeInfo{Code: code, Since: since}
+	}
+}
+
+func init() {
+	registerErrorCodes(1,
+		CodeNotFound,
+		CodeUnauthorized,
+		CodeCannotEnterScope,
+		CodeCannotEnterScopeYet,
+		CodeExcessiveContention,
+		CodeUnitHasSubordinates,
+		CodeNotAssigned,
+		CodeStopped,
+		CodeDead,
+		CodeHasAssignedUnits,
+		CodeNotProvisioned,
+		CodeNoAddressSet,
+		CodeTryAgain,
+		CodeNotImplemented,
+		CodeAlreadyExists,
+		CodeUpgradeInProgress,
+		CodeActionNotAvailable,
+		CodeOperationBlocked,
+		CodeLeadershipClaimDenied,
+		CodePrecheckFailed,
+		CodeConfigInvalid,
+		CodeEnvironmentSuspended,
+		CodeQuotaLimitExceeded,
+		CodeRelationLimitExceeded,
+		CodeUnitLimitExceeded,
+		CodeIncompatibleCharm,
+	)
+	registerErrorCodes(2,
+		CodeWrongLifecycle,
+	)
+}
+
+// LookupErrorCode returns the details of the given error code,
+// and whether it is a registered code.
+func LookupErrorCode(code string) (ErrorCodeInfo, bool) {
+	info, ok := errorCodes[code]
+	return info, ok
+}
+
+// ErrorCodes returns the details of all registered error codes,
+// sorted by code.
+func ErrorCodes() []ErrorCodeInfo {
+	infos := make([]ErrorCodeInfo, 0, len(errorCodes))
+	for _, info := range errorCodes {
+		infos = append(infos, info)
+	}
+	sort.Sort(errorCodeInfos(infos))
+	return infos
+}
+
+type errorCodeInfos []ErrorCodeInfo
+
+func (s errorCodeInfos) Len() int           { return len(s) }
+func (s errorCodeInfos) Less(i, j int) bool { return s[i].Code < s[j].Code }
+func (s errorCodeInfos) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// IsCode reports whether err carries the given error code.
+// Unlike comparing against ErrCode directly, it panics if the
+// code is not registered, so a misspelt code is caught rather
+// than never matching.
+func IsCode(err error, code string) bool {
+	info, ok := errorCodes[code]
+	if !ok {
+		panic(fmt.Sprintf("unknown error code %q", code))
+	}
+	return info.Is(err)
+}
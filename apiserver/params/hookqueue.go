@@ -0,0 +1,56 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// QueuedHook describes a hook that a unit's agent has yet to run.
+// Id identifies the hook in its unit's queue; it is set by the API
+// server, and ignored when the agent reports its queue.
+type QueuedHook struct {
+	Id         string
+	Kind       string
+	RelationId int
+	RemoteUnit string
+}
+
+// SetHookQueueArg holds the hooks a unit's agent has yet to run:
+// the failed hook awaiting resolution, if any, and the hooks queued
+// behind it, in the order they will run.
+type SetHookQueueArg struct {
+	Tag     string
+	Pending *QueuedHook
+	Queued  []QueuedHook
+}
+
+// SetHookQueueArgs holds the parameters for making a SetHookQueues
+// API call.
+type SetHookQueueArgs struct {
+	Args []SetHookQueueArg
+}
+
+// HookQueueResult holds the hooks a unit's agent last reported it has
+// yet to run, and those of them it has been asked not to run, or an
+// error.
+type HookQueueResult struct {
+	Pending *QueuedHook
+	Queued  []QueuedHook
+	Dropped []QueuedHook
+	Error   *Error
+}
+
+// HookQueueResults holds the results of a HookQueues API call.
+type HookQueueResults struct {
+	Results []HookQueueResult
+}
+
+// DropQueuedHookArg identifies a hook queued for a unit.
+type DropQueuedHookArg struct {
+	Tag    string
+	HookId string
+}
+
+// DropQueuedHookArgs holds the parameters for making a
+// DropQueuedHooks API call.
+type DropQueuedHookArgs struct {
+	Args []DropQueuedHookArg
+}
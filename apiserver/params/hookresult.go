@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// HookResult describes the outcome of the hook a unit's agent ran
+// most recently, with the tail of the output it wrote.
+type HookResult struct {
+	Hook      string
+	Time      time.Time
+	Error     string
+	Output    string
+	Truncated bool
+}
+
+// SetHookResultArg holds the outcome of the hook a unit's agent ran
+// most recently.
+type SetHookResultArg struct {
+	Tag    string
+	Result HookResult
+}
+
+// SetHookResultArgs holds the parameters for making a SetHookResults
+// API call.
+type SetHookResultArgs struct {
+	Args []SetHookResultArg
+}
+
+// HookResultResult holds the outcome of the hook a unit's agent ran
+// most recently, or an error.
+type HookResultResult struct {
+	Result *HookResult
+	Error  *Error
+}
+
+// HookResultResults holds the results of a HookResults API call.
+type HookResultResults struct {
+	Results []HookResultResult
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+
+	"github.com/juju/juju/constraints"
+)
+
+// InstanceType describes an instance type offered by a provider.
+type InstanceType struct {
+	Name     string   `json:"name"`
+	Arches   []string `json:"arches"`
+	CpuCores uint64   `json:"cpu-cores"`
+	CpuPower *uint64  `json:"cpu-power,omitempty"`
+	Mem      uint64   `json:"mem"`
+	RootDisk uint64   `json:"root-disk,omitempty"`
+	Cost     uint64   `json:"cost,omitempty"`
+	VirtType string   `json:"virt-type,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Gpus     uint64   `json:"gpus,omitempty"`
+	GpuType  string   `json:"gpu-type,omitempty"`
+}
+
+// CloudImage describes an image offered by a provider.
+type CloudImage struct {
+	Id       string `json:"id"`
+	Series   string `json:"series"`
+	Arch     string `json:"arch"`
+	VirtType string `json:"virt-type,omitempty"`
+}
+
+// InstanceMetadata holds the instance types and images offered by an
+// environment's provider, and when they were last fetched from it.
+type InstanceMetadata struct {
+	InstanceTypes []InstanceType `json:"instance-types"`
+	Images        []CloudImage   `json:"images"`
+	Updated       time.Time      `json:"updated"`
+}
+
+// InstanceSpecArgs holds the arguments for making a
+// FindInstanceSpecs API call.
+type InstanceSpecArgs struct {
+	Specs []InstanceSpecArg `json:"specs"`
+}
+
+// InstanceSpecArg holds the series and constraints of a machine to
+// find the instance type and image for.
+type InstanceSpecArg struct {
+	Series      string            `json:"series"`
+	Constraints constraints.Value `json:"constraints"`
+}
+
+// InstanceSpecResult holds the instance type and image which would be
+// chosen for a machine, or an error.
+type InstanceSpecResult struct {
+	InstanceType *InstanceType `json:"instance-type,omitempty"`
+	ImageId      string        `json:"image-id,omitempty"`
+	Error        *Error        `json:"error,omitempty"`
+}
+
+// InstanceSpecResults holds the results of a FindInstanceSpecs API
+// call.
+type InstanceSpecResults struct {
+	Results []InstanceSpecResult `json:"results"`
+}
+
+// ServiceCostArgs holds the arguments for making an EstimateCosts API
+// call.
+type ServiceCostArgs struct {
+	Services []ServiceCostArg `json:"services"`
+}
+
+// ServiceCostArg holds the series, constraints and number of units of
+// a service to estimate the cost of deploying.
+type ServiceCostArg struct {
+	ServiceName string            `json:"service-name"`
+	Series      string            `json:"series"`
+	Constraints constraints.Value `json:"constraints"`
+	NumUnits    int               `json:"num-units"`
+}
+
+// ServiceCost holds the estimated hourly cost of deploying a service,
+// or an error.
+type ServiceCost struct {
+	ServiceName  string  `json:"service-name"`
+	InstanceType string  `json:"instance-type,omitempty"`
+	NumUnits     int     `json:"num-units"`
+	UnitCost     float64 `json:"unit-cost"`
+	HourlyCost   float64 `json:"hourly-cost"`
+	Currency     string  `json:"currency,omitempty"`
+	Error        *Error  `json:"error,omitempty"`
+}
+
+// ServiceCostResults holds the results of an EstimateCosts API call.
+type ServiceCostResults struct {
+	Results []ServiceCost `json:"results"`
+}
@@ -105,6 +105,14 @@ type EnvironmentCreateArgs struct {
 	// environment.  An environment UUID is allocated by the API server during
 	// the creation of the environment.
 	Config map[string]interface{}
+
+	// Cloud, if set, names the cloud the environment is hosted on in
+	// place of the state server's, and CloudCredential names the
+	// owner's credential for that cloud. The cloud's config and the
+	// credential's attributes are used for any values not specified
+	// in Config or Account.
+	Cloud           string
+	CloudCredential string
 }
 
 // Environment holds the result of an API call returning a name and UUID
@@ -121,6 +129,45 @@ type EnvironmentList struct {
 	Environments []Environment
 }
 
+// EnvironmentDefaults holds the config defaults set on the state server
+// for new environments.
+type EnvironmentDefaults struct {
+	// Config holds the defaults inherited by all new environments.
+	Config map[string]interface{}
+
+	// Regions holds the defaults inherited only by new environments in
+	// a region, keyed on region name. They take precedence over Config.
+	Regions map[string]map[string]interface{}
+}
+
+// EnvironmentDefaultValues holds config defaults to set for new
+// environments in a region, or for all new environments if Region is
+// empty.
+type EnvironmentDefaultValues struct {
+	Region string
+	Config map[string]interface{}
+}
+
+// SetEnvironmentDefaults holds the arguments for
+// environmentmanager.SetEnvironmentDefaults.
+type SetEnvironmentDefaults struct {
+	Config []EnvironmentDefaultValues
+}
+
+// EnvironmentDefaultKeys holds the names of config defaults to remove
+// for new environments in a region, or for all new environments if
+// Region is empty.
+type EnvironmentDefaultKeys struct {
+	Region string
+	Keys   []string
+}
+
+// UnsetEnvironmentDefaults holds the arguments for
+// environmentmanager.UnsetEnvironmentDefaults.
+type UnsetEnvironmentDefaults struct {
+	Keys []EnvironmentDefaultKeys
+}
+
 // ResolvedModeResult holds a resolved mode or an error.
 type ResolvedModeResult struct {
 	Error *Error
@@ -197,6 +244,22 @@ type EnvironConfigResult struct {
 	Config EnvironConfig
 }
 
+// ProxyConfig holds the settings of a set of proxies.
+type ProxyConfig struct {
+	Http    string
+	Https   string
+	Ftp     string
+	NoProxy string
+}
+
+// ProxyConfigResult holds the proxy settings agents apply to their
+// machines.
+type ProxyConfigResult struct {
+	ProxySettings     ProxyConfig
+	AptProxySettings  ProxyConfig
+	SnapProxySettings ProxyConfig
+}
+
 // RelationUnit holds a relation and a unit tag.
 type RelationUnit struct {
 	Relation string
@@ -269,6 +332,49 @@ type EntitiesCharmURL struct {
 	Entities []EntityCharmURL
 }
 
+// EntityWorkloadVersion holds a unit's tag and the version of the
+// software it runs.
+type EntityWorkloadVersion struct {
+	Tag             string
+	WorkloadVersion string
+}
+
+// EntityWorkloadVersions holds the parameters for making a
+// SetWorkloadVersion API call.
+type EntityWorkloadVersions struct {
+	Entities []EntityWorkloadVersion
+}
+
+// GoalStateStatus holds the status of a unit or relation in the goal
+// state of a service.
+type GoalStateStatus struct {
+	Status string
+}
+
+// UnitsGoalState holds the goal state statuses of units, or of
+// services and their units, keyed by name.
+type UnitsGoalState map[string]GoalStateStatus
+
+// GoalState holds the units of a service and, keyed by endpoint name,
+// the services and units it is related to, as the environment intends
+// them to be rather than as they have come to be.
+type GoalState struct {
+	Units     UnitsGoalState
+	Relations map[string]UnitsGoalState
+}
+
+// GoalStateResult holds the goal state of a single unit's service, or
+// an error.
+type GoalStateResult struct {
+	Result *GoalState
+	Error  *Error
+}
+
+// GoalStateResults holds the results of a GoalStates API call.
+type GoalStateResults struct {
+	Results []GoalStateResult
+}
+
 // BytesResult holds the result of an API call that returns a slice
 // of bytes.
 type BytesResult struct {
@@ -366,6 +472,31 @@ type SetMachinesAddresses struct {
 	MachineAddresses []MachineAddresses
 }
 
+// MachineHardwareCharacteristics holds the hardware characteristics
+// detected on a machine.
+type MachineHardwareCharacteristics struct {
+	Tag             string
+	Characteristics instance.HardwareCharacteristics
+}
+
+// SetMachinesHardwareCharacteristics holds the parameters for making a
+// SetHardwareCharacteristics call.
+type SetMachinesHardwareCharacteristics struct {
+	MachineCharacteristics []MachineHardwareCharacteristics
+}
+
+// MachineSSHHostKeys holds the public ssh host keys of a machine.
+type MachineSSHHostKeys struct {
+	Tag        string
+	PublicKeys []string
+}
+
+// SetMachinesSSHHostKeys holds the parameters for making a
+// SetSSHHostKeys call.
+type SetMachinesSSHHostKeys struct {
+	MachineHostKeys []MachineSSHHostKeys
+}
+
 // ConstraintsResult holds machine constraints or an error.
 type ConstraintsResult struct {
 	Error       *Error
@@ -565,3 +696,27 @@ type MeterStatusResult struct {
 type MeterStatusResults struct {
 	Results []MeterStatusResult
 }
+
+// MetricBatchDelivery holds the delivery state of a metric batch.
+type MetricBatchDelivery struct {
+	UUID        string
+	Unit        string
+	Created     time.Time
+	State       string
+	Attempts    int
+	LastAttempt time.Time
+	Error       string
+}
+
+// MetricBatchDeliveryResult holds the delivery states of the metric
+// batches of an environment, or an error.
+type MetricBatchDeliveryResult struct {
+	Batches []MetricBatchDelivery
+	Error   *Error
+}
+
+// MetricBatchDeliveryResults holds the delivery states of the metric
+// batches of multiple environments.
+type MetricBatchDeliveryResults struct {
+	Results []MetricBatchDeliveryResult
+}
@@ -3,6 +3,8 @@
 
 package params
 
+import "time"
+
 // ClaimLeadershipBulkParams is a collection of parameters for making
 // a bulk leadership claim.
 type ClaimLeadershipBulkParams struct {
@@ -84,3 +86,52 @@ type MergeLeadershipSettingsParam struct {
 	// Settings are the Leadership settings you wish to merge in.
 	Settings Settings
 }
+
+// PinLeadershipBulkParams is a collection of parameters for pinning
+// the leadership of services.
+type PinLeadershipBulkParams struct {
+	Params []PinLeadershipParams
+}
+
+// PinLeadershipParams are the parameters needed to pin the leadership
+// of a service to its current leader.
+type PinLeadershipParams struct {
+
+	// ServiceTag is the service whose leadership is to be pinned.
+	ServiceTag string
+
+	// DurationSeconds is the number of seconds for which the
+	// leadership is to be pinned, unless it is unpinned sooner.
+	// If zero, a default duration is used.
+	DurationSeconds float64
+}
+
+// ServiceLeadershipResults holds the results of a bulk request for
+// the leadership of services.
+type ServiceLeadershipResults struct {
+	Results []ServiceLeadershipResult
+}
+
+// ServiceLeadershipResult describes the leadership of a service.
+type ServiceLeadershipResult struct {
+
+	// Leader holds the tag of the service's leader unit, if it
+	// has one.
+	Leader string
+
+	// Pins holds the unexpired pins on the service's leadership.
+	Pins []LeadershipPin
+
+	Error *Error
+}
+
+// LeadershipPin describes a pin on a service's leadership.
+type LeadershipPin struct {
+
+	// Entity holds the tag of the entity that pinned the
+	// leadership.
+	Entity string
+
+	// Expiry holds the time at which the pin lapses.
+	Expiry time.Time
+}
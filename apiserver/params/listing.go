@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "github.com/juju/juju/instance"
+
+// ListPage selects a page of the entries returned by a list call.
+type ListPage struct {
+	// After is the Next value returned with the previous page, or
+	// empty to list from the start. It is only meaningful when
+	// passed with the same sort order as the call that returned it.
+	After string `json:"after,omitempty"`
+
+	// Limit is the maximum number of entries to return. If zero,
+	// the server's maximum page size is used.
+	Limit int `json:"limit,omitempty"`
+
+	// SortBy names the field to order entries by. If empty,
+	// entries are ordered by their ID. Entries with equal values
+	// are always ordered by ID.
+	SortBy string `json:"sortby,omitempty"`
+
+	// Descending reverses the order of the entries.
+	Descending bool `json:"descending,omitempty"`
+}
+
+// ListMachinesArgs holds the arguments for listing a page of the
+// machines in the environment. Each non-empty filter must be matched
+// for a machine to be listed.
+type ListMachinesArgs struct {
+	Page ListPage `json:"page"`
+
+	// Services restricts the list to machines hosting a unit of
+	// one of the named services.
+	Services []string `json:"services,omitempty"`
+
+	// Statuses restricts the list to machines whose agent has one
+	// of the given statuses.
+	Statuses []Status `json:"statuses,omitempty"`
+}
+
+// MachineSummary describes a machine in a list of machines.
+type MachineSummary struct {
+	Id         string      `json:"id"`
+	Series     string      `json:"series"`
+	InstanceId instance.Id `json:"instanceid,omitempty"`
+	Status     Status      `json:"status"`
+
+	// Units holds the names of the units assigned to the machine,
+	// including subordinates.
+	Units []string `json:"units,omitempty"`
+}
+
+// ListMachinesResult holds a page of machines.
+type ListMachinesResult struct {
+	Machines []MachineSummary `json:"machines"`
+
+	// Next is the value to pass as After to list the next page,
+	// or empty if there are no more machines.
+	Next string `json:"next,omitempty"`
+}
+
+// ListUnitsArgs holds the arguments for listing a page of the units
+// in the environment. Each non-empty filter must be matched for a
+// unit to be listed.
+type ListUnitsArgs struct {
+	Page ListPage `json:"page"`
+
+	// Services restricts the list to units of the named services.
+	Services []string `json:"services,omitempty"`
+
+	// Machines restricts the list to units assigned to the machines
+	// with the given IDs.
+	Machines []string `json:"machines,omitempty"`
+
+	// Statuses restricts the list to units whose workload has one
+	// of the given statuses.
+	Statuses []Status `json:"statuses,omitempty"`
+}
+
+// UnitSummary describes a unit in a list of units.
+type UnitSummary struct {
+	Name        string `json:"name"`
+	Service     string `json:"service"`
+	Machine     string `json:"machine,omitempty"`
+	Status      Status `json:"status"`
+	AgentStatus Status `json:"agentstatus"`
+}
+
+// ListUnitsResult holds a page of units.
+type ListUnitsResult struct {
+	Units []UnitSummary `json:"units"`
+
+	// Next is the value to pass as After to list the next page,
+	// or empty if there are no more units.
+	Next string `json:"next,omitempty"`
+}
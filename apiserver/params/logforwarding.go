@@ -0,0 +1,131 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// LogForwardTargetConfig holds the configuration of an external
+// target to which stored logs are forwarded.
+type LogForwardTargetConfig struct {
+	// Name uniquely identifies the target.
+	Name string `json:"name"`
+
+	// Type is the protocol used to forward logs, one of
+	// "syslog" or "loki".
+	Type string `json:"type"`
+
+	// Address is the "host:port" of a syslog target, or the
+	// base URL of a Loki target.
+	Address string `json:"address"`
+
+	// CACert holds the PEM-encoded CA certificate used to verify
+	// the target, if the system's authorities should not be used.
+	CACert string `json:"ca-cert,omitempty"`
+
+	// EnvUUIDs holds the UUIDs of the environments whose logs
+	// are forwarded. If empty, all environments are forwarded.
+	EnvUUIDs []string `json:"env-uuids,omitempty"`
+
+	// MinLevel is the lowest level of log record forwarded.
+	MinLevel loggo.Level `json:"min-level"`
+}
+
+// LogForwardTargetConfigs holds the arguments for adding log
+// forwarding targets.
+type LogForwardTargetConfigs struct {
+	Targets []LogForwardTargetConfig `json:"targets"`
+}
+
+// LogForwardTargetNames holds the names of log forwarding targets.
+type LogForwardTargetNames struct {
+	Names []string `json:"names"`
+}
+
+// LogForwardStatus describes the progress of forwarding an
+// environment's logs to a target.
+type LogForwardStatus struct {
+	LastSentId   string    `json:"last-sent-id"`
+	LastSentTime time.Time `json:"last-sent-time"`
+	Delivered    int64     `json:"delivered"`
+	Error        string    `json:"error,omitempty"`
+	Updated      time.Time `json:"updated"`
+}
+
+// LogForwardTarget describes a log forwarding target which applies
+// to an environment, along with the delivery status of the
+// environment's logs.
+type LogForwardTarget struct {
+	Config LogForwardTargetConfig `json:"config"`
+	Status LogForwardStatus       `json:"status"`
+}
+
+// LogForwardTargetsResult holds the result of an API call to list
+// log forwarding targets.
+type LogForwardTargetsResult struct {
+	Targets []LogForwardTarget `json:"targets"`
+}
+
+// LogForwardLogsArg identifies the target for which the next batch
+// of log records to forward is required.
+type LogForwardLogsArg struct {
+	Target string `json:"target"`
+	Limit  int    `json:"limit"`
+}
+
+// LogForwardLogsArgs holds the arguments for fetching batches of
+// log records to forward.
+type LogForwardLogsArgs struct {
+	Args []LogForwardLogsArg `json:"args"`
+}
+
+// LogForwardRecord holds a single stored log record to be forwarded.
+type LogForwardRecord struct {
+	Id       string      `json:"id"`
+	Time     time.Time   `json:"time"`
+	Entity   string      `json:"entity"`
+	Module   string      `json:"module"`
+	Location string      `json:"location"`
+	Level    loggo.Level `json:"level"`
+	Message  string      `json:"message"`
+}
+
+// LogForwardLogsResult holds a batch of log records to forward to
+// a target, or an error.
+type LogForwardLogsResult struct {
+	Records []LogForwardRecord `json:"records"`
+	Error   *Error             `json:"error,omitempty"`
+}
+
+// LogForwardLogsResults holds the results of fetching batches of
+// log records to forward.
+type LogForwardLogsResults struct {
+	Results []LogForwardLogsResult `json:"results"`
+}
+
+// LogForwardDelivery reports the outcome of an attempt to deliver
+// log records to a target.
+type LogForwardDelivery struct {
+	Target string `json:"target"`
+
+	// LastSentId and LastSentTime identify the last record
+	// delivered, if any were.
+	LastSentId   string    `json:"last-sent-id,omitempty"`
+	LastSentTime time.Time `json:"last-sent-time"`
+
+	// Delivered is the number of records delivered.
+	Delivered int `json:"delivered"`
+
+	// Error holds the error that stopped delivery, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// LogForwardDeliveries holds the arguments for reporting log
+// delivery outcomes.
+type LogForwardDeliveries struct {
+	Deliveries []LogForwardDelivery `json:"deliveries"`
+}
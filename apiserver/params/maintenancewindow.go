@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// MaintenanceWindowOverride holds a temporary override of an
+// environment's maintenance window.
+type MaintenanceWindowOverride struct {
+	// Open holds whether the window is held open, allowing
+	// disruptive operations, or held closed.
+	Open bool `json:"open"`
+
+	// Until holds when the override expires.
+	Until time.Time `json:"until"`
+}
+
+// MaintenanceWindowStatus holds the result of an API call to report
+// the state of an environment's maintenance window.
+type MaintenanceWindowStatus struct {
+	// Schedule holds the cron expression giving when the window
+	// opens, or is empty if disruptive operations may run at any
+	// time.
+	Schedule string `json:"schedule,omitempty"`
+
+	// Duration holds how long the window stays open each time it
+	// opens.
+	Duration time.Duration `json:"duration"`
+
+	// Override holds the override in effect, if any.
+	Override *MaintenanceWindowOverride `json:"override,omitempty"`
+
+	// Open holds whether disruptive operations may run now.
+	Open bool `json:"open"`
+
+	// Until holds when Open will next change, if it will.
+	Until *time.Time `json:"until,omitempty"`
+}
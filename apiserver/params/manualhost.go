@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// The progress of a request to manually provision a host.
+const (
+	ManualHostPending      = "pending"
+	ManualHostProvisioning = "provisioning"
+	ManualHostProvisioned  = "provisioned"
+	ManualHostFailed       = "failed"
+)
+
+// AddManualHostArg holds a request for the state server to provision
+// an existing host over SSH.
+type AddManualHostArg struct {
+	// Host is the hostname or address of the host, which must
+	// accept SSH logins as the ubuntu user with the environment's
+	// system key.
+	Host string `json:"host"`
+
+	// DisablePackageCommands, if true, prevents package updates and
+	// upgrades on the host regardless of environment settings.
+	DisablePackageCommands bool `json:"disable-package-commands,omitempty"`
+}
+
+// AddManualHostArgs holds the parameters for an AddManualHosts call.
+type AddManualHostArgs struct {
+	Hosts []AddManualHostArg `json:"hosts"`
+}
+
+// AddManualHostResult holds the id of a manual provisioning request,
+// or the error that prevented it being made.
+type AddManualHostResult struct {
+	Id    string `json:"id,omitempty"`
+	Error *Error `json:"error,omitempty"`
+}
+
+// AddManualHostResults holds the results of an AddManualHosts call.
+type AddManualHostResults struct {
+	Results []AddManualHostResult `json:"results"`
+}
+
+// ManualHostIds holds the ids of manual provisioning requests.
+type ManualHostIds struct {
+	Ids []string `json:"ids"`
+}
+
+// ManualHostStatus holds the progress of a manual provisioning request.
+type ManualHostStatus struct {
+	Id   string `json:"id"`
+	Host string `json:"host"`
+
+	// Status is one of ManualHostPending, ManualHostProvisioning,
+	// ManualHostProvisioned or ManualHostFailed.
+	Status string `json:"status"`
+
+	// Message describes the current step, or why the host could
+	// not be provisioned.
+	Message string `json:"message"`
+
+	// Attempts is the number of attempts that have failed.
+	Attempts int `json:"attempts"`
+
+	// MachineId is the id of the machine recorded for the host,
+	// if any.
+	MachineId string `json:"machine-id,omitempty"`
+}
+
+// ManualHostStatusResult holds the progress of a manual provisioning
+// request, or the error that prevented it being retrieved.
+type ManualHostStatusResult struct {
+	Status ManualHostStatus `json:"status"`
+	Error  *Error           `json:"error,omitempty"`
+}
+
+// ManualHostStatusResults holds the results of a ManualHostStatus call.
+type ManualHostStatusResults struct {
+	Results []ManualHostStatusResult `json:"results"`
+}
@@ -0,0 +1,25 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// UnitMeterStatus holds the meter status of a unit.
+type UnitMeterStatus struct {
+	UnitTag string `json:"unit-tag"`
+	Code    string `json:"code"`
+	Info    string `json:"info,omitempty"`
+}
+
+// UnitMeterStatuses holds the meter statuses of the units in an
+// environment.
+type UnitMeterStatuses struct {
+	Statuses []UnitMeterStatus `json:"statuses"`
+}
+
+// MeterStatusAlertConfig holds the settings used to send alerts when
+// units' meter statuses turn RED.
+type MeterStatusAlertConfig struct {
+	// URL is the webhook to which alerts are posted. Alerts are
+	// not sent if it is empty.
+	URL string `json:"url,omitempty"`
+}
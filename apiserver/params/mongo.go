@@ -0,0 +1,21 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// MongoConfig holds the settings of the mongo database deployed on
+// the state servers.
+type MongoConfig struct {
+	// StorageEngine is the storage engine used by mongod, either
+	// "mmapv1" or "wiredTiger". It can only be chosen at bootstrap.
+	StorageEngine string `json:"storage-engine"`
+
+	// OplogSizeMB is the size of the replica set oplog. Zero means
+	// mongod's own default is used. It can only be chosen at
+	// bootstrap.
+	OplogSizeMB int `json:"oplog-size-mb"`
+
+	// CacheSizeGB is the size of the wiredTiger cache. Zero means
+	// mongod's own default is used.
+	CacheSizeGB int `json:"cache-size-gb"`
+}
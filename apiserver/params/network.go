@@ -177,6 +177,82 @@ func (pr PortRange) NetworkPortRange() network.PortRange {
 	}
 }
 
+// IngressRule represents a range of ports opened to traffic from a
+// set of source address ranges. It is used in API requests/responses.
+// See also network.IngressRule, from/to which this is transformed.
+type IngressRule struct {
+	PortRange   PortRange `json:"PortRange"`
+	SourceCIDRs []string  `json:"SourceCIDRs"`
+}
+
+// FromNetworkIngressRule is a convenience helper to create a parameter
+// out of the network type, here for IngressRule.
+func FromNetworkIngressRule(rule network.IngressRule) IngressRule {
+	return IngressRule{
+		PortRange:   FromNetworkPortRange(rule.PortRange),
+		SourceCIDRs: rule.SourceCIDRs,
+	}
+}
+
+// NetworkIngressRule is a convenience helper to return the parameter
+// as network type, here for IngressRule.
+func (rule IngressRule) NetworkIngressRule() network.IngressRule {
+	return network.IngressRule{
+		PortRange:   rule.PortRange.NetworkPortRange(),
+		SourceCIDRs: rule.SourceCIDRs,
+	}
+}
+
+// IngressRulesResult holds the ingress rules of an entity, or the
+// error that prevented them being retrieved.
+type IngressRulesResult struct {
+	Rules []IngressRule `json:"Rules"`
+	Error *Error        `json:"Error"`
+}
+
+// IngressRulesResults holds the bulk operation result of an API call
+// that returns the ingress rules of entities.
+type IngressRulesResults struct {
+	Results []IngressRulesResult `json:"Results"`
+}
+
+// UnitBinding identifies an endpoint binding of a unit.
+type UnitBinding struct {
+	UnitTag     string `json:"UnitTag"`
+	BindingName string `json:"BindingName"`
+}
+
+// UnitBindings holds the arguments of an API call that returns the
+// network information of unit endpoint bindings.
+type UnitBindings struct {
+	Bindings []UnitBinding `json:"Bindings"`
+}
+
+// NetworkInfo describes how a unit is reached, and is seen to connect
+// from, on the network of one of its endpoint bindings.
+type NetworkInfo struct {
+	// IngressAddresses holds the addresses other units use to
+	// connect to the unit, the primary address first.
+	IngressAddresses []string `json:"IngressAddresses"`
+
+	// EgressSubnets holds the CIDRs the unit's outgoing connections
+	// come from.
+	EgressSubnets []string `json:"EgressSubnets"`
+}
+
+// NetworkInfoResult holds the network information of a unit endpoint
+// binding, or the error that prevented it being retrieved.
+type NetworkInfoResult struct {
+	Info  NetworkInfo `json:"Info"`
+	Error *Error      `json:"Error"`
+}
+
+// NetworkInfoResults holds the bulk operation result of an API call
+// that returns the network information of unit endpoint bindings.
+type NetworkInfoResults struct {
+	Results []NetworkInfoResult `json:"Results"`
+}
+
 // EntityPort holds an entity's tag, a protocol and a port.
 type EntityPort struct {
 	Tag      string `json:"Tag"`
@@ -412,3 +488,28 @@ type APIHostPortsResult struct {
 func (r APIHostPortsResult) NetworkHostsPorts() [][]network.HostPort {
 	return NetworkHostsPorts(r.Servers)
 }
+
+// SubnetsFilters holds the filters applied to the subnets returned by
+// the Subnets facade's ListSubnets method.
+type SubnetsFilters struct {
+	// Zone, if set, restricts the subnets to those that can be
+	// used in the availability zone with this name.
+	Zone string `json:"Zone,omitempty"`
+}
+
+// Subnet describes a subnet known to the provider.
+type Subnet struct {
+	CIDR       string `json:"CIDR"`
+	ProviderId string `json:"ProviderId"`
+	VLANTag    int    `json:"VLANTag"`
+
+	// Zones holds the availability zones in which the subnet can
+	// be used. It is empty if the subnet can be used in all zones.
+	Zones []string `json:"Zones"`
+}
+
+// ListSubnetsResults holds the result of the Subnets facade's
+// ListSubnets method.
+type ListSubnetsResults struct {
+	Results []Subnet `json:"Results"`
+}
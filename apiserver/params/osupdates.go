@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// MachineSecurityUpdates holds the names of the packages with pending
+// security updates on a machine.
+type MachineSecurityUpdates struct {
+	Tag      string   `json:"tag"`
+	Packages []string `json:"packages"`
+}
+
+// SetSecurityUpdates holds the arguments for recording the security
+// updates pending on machines.
+type SetSecurityUpdates struct {
+	Machines []MachineSecurityUpdates `json:"machines"`
+}
+
+// MachineOSUpdates holds the security updates pending on a machine, as
+// last reported by its agent.
+type MachineOSUpdates struct {
+	MachineId       string    `json:"machine-id"`
+	SecurityUpdates []string  `json:"security-updates"`
+	Checked         time.Time `json:"checked"`
+
+	// PatchRun holds the id of the patch run patching the machine,
+	// or zero if it is not being patched.
+	PatchRun int `json:"patch-run,omitempty"`
+}
+
+// MachineOSUpdatesResults holds the security updates pending on each
+// machine in an environment.
+type MachineOSUpdatesResults struct {
+	Results []MachineOSUpdates `json:"results"`
+}
+
+// PatchRequest holds the patch run a machine's agent has been asked
+// to apply updates for, and the machine's progress in it.
+type PatchRequest struct {
+	Run    int    `json:"run"`
+	Status string `json:"status"`
+}
+
+// PatchRequestResult holds a PatchRequest or an error.
+type PatchRequestResult struct {
+	Result *PatchRequest `json:"result,omitempty"`
+	Error  *Error        `json:"error,omitempty"`
+}
+
+// PatchRequestResults holds the results of an API call to get the
+// patch requests of machines.
+type PatchRequestResults struct {
+	Results []PatchRequestResult `json:"results"`
+}
+
+// MachinePatchStatus holds the progress of a machine in a patch run.
+type MachinePatchStatus struct {
+	Tag    string `json:"tag"`
+	Status string `json:"status"`
+	Info   string `json:"info,omitempty"`
+}
+
+// SetPatchStatus holds the arguments for recording the progress of
+// machines in the patch runs they were asked to apply updates for.
+type SetPatchStatus struct {
+	Machines []MachinePatchStatus `json:"machines"`
+}
+
+// StartPatchRun holds the arguments for starting a rolling patch run.
+type StartPatchRun struct {
+	// MaxUnavailable holds how many machines may be applying
+	// updates or rebooting at once.
+	MaxUnavailable int `json:"max-unavailable"`
+}
+
+// PatchRun describes a rolling run applying pending security updates.
+type PatchRun struct {
+	Id             int                  `json:"id"`
+	MaxUnavailable int                  `json:"max-unavailable"`
+	Status         string               `json:"status"`
+	Started        time.Time            `json:"started"`
+	Finished       *time.Time           `json:"finished,omitempty"`
+	Machines       []MachinePatchStatus `json:"machines"`
+}
+
+// PatchRunsResult holds the patch runs in an environment, most recent
+// first.
+type PatchRunsResult struct {
+	Runs []PatchRun `json:"runs"`
+}
@@ -175,10 +175,16 @@ type AddMachinesResult struct {
 	Error   *Error `json:"Error"`
 }
 
+// DestroyEnvironment holds parameters for the DestroyEnvironment call.
+type DestroyEnvironment struct {
+	Force bool
+}
+
 // DestroyMachines holds parameters for the DestroyMachines call.
 type DestroyMachines struct {
 	MachineNames []string
 	Force        bool
+	Evacuate     bool
 }
 
 // ServiceDeploy holds the parameters for making the ServiceDeploy call.
@@ -192,6 +198,12 @@ type ServiceDeploy struct {
 	ToMachineSpec string
 	Networks      []string
 	Storage       map[string]storage.Constraints
+	// WorkloadContainer, if set, is the type of container in which
+	// the charm of each unit runs on the unit's machine.
+	WorkloadContainer instance.ContainerType
+	// Channel, if set, is the charm store channel the service
+	// tracks for upgrades of its charm.
+	Channel string
 }
 
 // ServiceUpdate holds the parameters for making the ServiceUpdate call.
@@ -200,6 +212,7 @@ type ServiceUpdate struct {
 	CharmUrl        string
 	ForceCharmUrl   bool
 	MinUnits        *int
+	MaxUnits        *int
 	SettingsStrings map[string]string
 	SettingsYAML    string // Takes precedence over SettingsStrings if both are present.
 	Constraints     *constraints.Value
@@ -210,11 +223,18 @@ type ServiceSetCharm struct {
 	ServiceName string
 	CharmUrl    string
 	Force       bool
+	// Channel, if set, is the charm store channel the service
+	// tracks for upgrades of its charm from then on.
+	Channel string
 }
 
 // ServiceExpose holds the parameters for making the ServiceExpose call.
 type ServiceExpose struct {
 	ServiceName string
+
+	// CIDRs, if not empty, restricts access to the service's open
+	// ports to the given address ranges.
+	CIDRs []string `json:",omitempty"`
 }
 
 // ServiceSet holds the parameters for a ServiceSet
@@ -280,6 +300,32 @@ type ServiceMetricCredentials struct {
 	Creds []ServiceMetricCredential
 }
 
+// ServiceConfig holds config settings to set on a service. The values
+// are parsed according to the types of the charm's config options.
+type ServiceConfig struct {
+	ServiceName string
+	Options     map[string]string
+}
+
+// ServiceConfigs holds multiple ServiceConfig parameters.
+type ServiceConfigs struct {
+	Configs []ServiceConfig
+}
+
+// ServiceConfigResult holds the result of setting a service's config.
+// If any setting breaks the validation rules declared by the service's
+// charm, InvalidSettings holds the reason each such setting was
+// rejected, keyed on option name, and nothing is set.
+type ServiceConfigResult struct {
+	Error           *Error
+	InvalidSettings map[string]string
+}
+
+// ServiceConfigResults holds the results of a SetConfigs call.
+type ServiceConfigResults struct {
+	Results []ServiceConfigResult
+}
+
 // PublicAddress holds parameters for the PublicAddress call.
 type PublicAddress struct {
 	Target string
@@ -300,6 +346,45 @@ type PrivateAddressResults struct {
 	PrivateAddress string
 }
 
+// SSHHostKeys holds parameters for the SSHHostKeys call.
+type SSHHostKeys struct {
+	Target string
+}
+
+// SSHHostKeysResults holds results of the SSHHostKeys call.
+type SSHHostKeysResults struct {
+	PublicKeys []string
+}
+
+// RetryProvisioningArg holds a machine whose provisioning should be
+// retried, along with any changes to the parameters used to provision
+// it. Empty fields leave the corresponding parameters unchanged.
+type RetryProvisioningArg struct {
+	Tag         string
+	Constraints *constraints.Value `json:",omitempty"`
+	Series      string             `json:",omitempty"`
+	Zone        string             `json:",omitempty"`
+}
+
+// RetryProvisioningArgs holds the parameters for the
+// RetryProvisioningWithOverrides call.
+type RetryProvisioningArgs struct {
+	Machines []RetryProvisioningArg
+}
+
+// SuspendEnvironment holds the parameters for the SuspendEnvironment
+// call.
+type SuspendEnvironment struct {
+	Reason string `json:",omitempty"`
+}
+
+// EnvironmentSuspendedResult holds whether an environment is
+// suspended, and if so, why.
+type EnvironmentSuspendedResult struct {
+	Suspended bool
+	Reason    string `json:",omitempty"`
+}
+
 // Resolved holds parameters for the Resolved call.
 type Resolved struct {
 	UnitName string
@@ -390,9 +475,25 @@ type SetConstraints struct {
 	Constraints constraints.Value
 }
 
+// ConstraintsWarning describes a constraint attribute which was
+// accepted but will be ignored when provisioning machines.
+type ConstraintsWarning struct {
+	Attribute string
+	Message   string
+}
+
+// SetConstraintsResult holds the result of a SetServiceConstraints or
+// SetEnvironmentConstraints call.
+type SetConstraintsResult struct {
+	Warnings []ConstraintsWarning
+}
+
 // ResolveCharms stores charm references for a ResolveCharms call.
 type ResolveCharms struct {
 	References []charm.Reference
+	// Channel, if set, is the charm store channel from which the
+	// references are resolved, including their latest revision.
+	Channel string `json:",omitempty"`
 }
 
 // ResolveCharmResult holds the result of resolving a charm reference to a URL, or any error that occurred.
@@ -426,6 +527,10 @@ type ListSSHKeys struct {
 type ModifyUserSSHKeys struct {
 	User string
 	Keys []string
+
+	// Expires, if set, holds when added or imported keys stop being
+	// authorised. Such keys are held in the user's key namespace.
+	Expires *time.Time `json:",omitempty"`
 }
 
 // StateServingInfo holds information needed by a state
@@ -484,6 +589,14 @@ type ContainerConfig struct {
 	*UpdateBehavior
 }
 
+// PrepareContainersResult holds what a host needs to start several
+// containers: the container config, which is shared by them all, and
+// the network configuration prepared for each container.
+type PrepareContainersResult struct {
+	Config  ContainerConfig
+	Results []MachineNetworkConfigResult
+}
+
 // ProvisioningScriptParams contains the parameters for the
 // ProvisioningScript client API call.
 type ProvisioningScriptParams struct {
@@ -548,6 +661,7 @@ type ModifyEnvironUser struct {
 // SetEnvironAgentVersion client API call.
 type SetEnvironAgentVersion struct {
 	Version version.Number
+	Force   bool
 }
 
 // DeployerConnectionValues containers the result of deployer.ConnectionInfo
@@ -560,8 +674,23 @@ type DeployerConnectionValues struct {
 // StatusParams holds parameters for the Status call.
 type StatusParams struct {
 	Patterns []string
+
+	// Sections, if not empty, limits the status returned to the
+	// given sections; the others are left empty.
+	Sections []StatusSection
 }
 
+// StatusSection names a part of the environment status that may be
+// requested on its own.
+type StatusSection string
+
+const (
+	StatusMachines  StatusSection = "machines"
+	StatusServices  StatusSection = "services"
+	StatusRelations StatusSection = "relations"
+	StatusNetworks  StatusSection = "networks"
+)
+
 // SetRsyslogCertParams holds parameters for the SetRsyslogCert call.
 type SetRsyslogCertParams struct {
 	CACert []byte
@@ -700,6 +829,53 @@ type StateServersChanges struct {
 	Demoted    []string `json:"demoted,omitempty"`
 }
 
+// StateServerHealth describes the health of a state server machine.
+type StateServerHealth struct {
+	MachineTag   string `json:"machine-tag"`
+	AgentAlive   bool   `json:"agent-alive"`
+	WantsVote    bool   `json:"wants-vote"`
+	HasVote      bool   `json:"has-vote"`
+	MongoState   string `json:"mongo-state,omitempty"`
+	MongoHealthy bool   `json:"mongo-healthy"`
+	Available    bool   `json:"available"`
+}
+
+// StateServersHealthResult holds the result of the
+// StateServersHealth API call.
+type StateServersHealthResult struct {
+	StateServers []StateServerHealth `json:"state-servers"`
+
+	// Voters is the number of state servers which should have a
+	// vote, and AvailableVoters the number of those available.
+	Voters          int `json:"voters"`
+	AvailableVoters int `json:"available-voters"`
+
+	// Healthy holds whether every state server which should have
+	// a vote is available.
+	Healthy bool `json:"healthy"`
+}
+
+// SingularWorkers describes the singular workers of an environment,
+// which run on the state server holding the environment's singular
+// lease.
+type SingularWorkers struct {
+	EnvironTag  string `json:"environ-tag"`
+	EnvironName string `json:"environ-name"`
+
+	// HolderTag holds the tag of the state server machine which
+	// holds the lease, and is empty if no state server holds it.
+	HolderTag string     `json:"holder-tag,omitempty"`
+	Expiry    *time.Time `json:"expiry,omitempty"`
+
+	Workers []string `json:"workers"`
+}
+
+// SingularWorkersResult holds the result of the SingularWorkers API
+// call.
+type SingularWorkersResult struct {
+	Environments []SingularWorkers `json:"environments"`
+}
+
 // FindToolsParams defines parameters for the FindTools method.
 type FindToolsParams struct {
 	// Number will be used to match tools versions exactly if non-zero.
@@ -873,3 +1049,22 @@ const (
 	// been asked to offer.
 	StatusRunning Status = "running"
 )
+
+const (
+	// Status values recording the provisioning progress of a machine.
+
+	// An image has been chosen for the machine's instance.
+	StatusImageSelected Status = "image-selected"
+
+	// The provider has been asked to start the machine's instance.
+	StatusInstanceRequested Status = "instance-requested"
+
+	// The machine's instance has been started by the provider.
+	StatusInstanceRunning Status = "instance-running"
+
+	// The machine's instance is downloading the agent tools.
+	StatusToolsDownloading Status = "tools-downloading"
+
+	// The machine agent has started on the machine's instance.
+	StatusAgentStarted Status = "agent-started"
+)
@@ -67,9 +67,11 @@ var marshalTestCases = []struct {
 				"hello": "goodbye",
 				"foo":   false,
 			},
+			Status:     multiwatcher.Status("blocked"),
+			StatusInfo: "Benji/0: need database",
 		},
 	},
-	json: `["service","change",{"CharmURL": "cs:quantal/name","Name":"Benji","Exposed":true,"Life":"dying","OwnerTag":"test-owner","MinUnits":42,"Constraints":{"arch":"armhf", "mem": 1024},"Config": {"hello":"goodbye","foo":false},"Subordinate":false}]`,
+	json: `["service","change",{"CharmURL": "cs:quantal/name","Name":"Benji","Exposed":true,"Life":"dying","OwnerTag":"test-owner","MinUnits":42,"Constraints":{"arch":"armhf", "mem": 1024},"Config": {"hello":"goodbye","foo":false},"Subordinate":false,"Status":"blocked","StatusInfo":"Benji/0: need database"}]`,
 }, {
 	about: "UnitInfo Delta",
 	value: multiwatcher.Delta{
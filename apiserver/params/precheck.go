@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// PrecheckOperation identifies an operation guarded by prechecks.
+type PrecheckOperation string
+
+const (
+	// PrecheckDestroyEnvironment identifies the destruction of an
+	// environment.
+	PrecheckDestroyEnvironment PrecheckOperation = "destroy-environment"
+
+	// PrecheckDestroyMachine identifies the removal of a machine.
+	PrecheckDestroyMachine PrecheckOperation = "destroy-machine"
+
+	// PrecheckUpgrade identifies an upgrade of the environment's
+	// agents.
+	PrecheckUpgrade PrecheckOperation = "upgrade"
+)
+
+// PrecheckArgs holds the parameters for running the prechecks of
+// an operation without performing it.
+type PrecheckArgs struct {
+	// Operation identifies the operation to check.
+	Operation PrecheckOperation `json:"operation"`
+
+	// Entities holds the tags of the entities the operation would
+	// act on. Operations on the environment as a whole ignore it.
+	Entities []string `json:"entities,omitempty"`
+}
+
+// PrecheckProblem describes a single reason why an operation should
+// not go ahead without being forced.
+type PrecheckProblem struct {
+	// Check names the checker which found the problem.
+	Check string `json:"check"`
+
+	// Entity holds the tag of the entity the problem concerns.
+	Entity string `json:"entity"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// PrecheckReport holds the outcome of running the prechecks of an
+// operation. The operation may go ahead unforced only if there are
+// no problems.
+type PrecheckReport struct {
+	Operation PrecheckOperation `json:"operation"`
+	Problems  []PrecheckProblem `json:"problems,omitempty"`
+}
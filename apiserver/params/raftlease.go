@@ -0,0 +1,15 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// RaftProposeArgs holds a command to be committed by the leader of
+// the state servers' lease service.
+type RaftProposeArgs struct {
+	Command []byte `json:"command"`
+}
+
+// RaftProposeResult holds the result of committing a command.
+type RaftProposeResult struct {
+	Result []byte `json:"result"`
+}
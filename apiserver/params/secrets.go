@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// CreateSecretArg holds the arguments for creating a secret owned by
+// the service of a unit.
+type CreateSecretArg struct {
+	UnitTag        string
+	Label          string
+	Data           map[string]string
+	RotateInterval time.Duration
+}
+
+// CreateSecretArgs holds the parameters for making a CreateSecrets
+// API call.
+type CreateSecretArgs struct {
+	Args []CreateSecretArg
+}
+
+// UpdateSecretArg holds the arguments for replacing the value of a
+// secret owned by the service of a unit.
+type UpdateSecretArg struct {
+	UnitTag  string
+	SecretId string
+	Data     map[string]string
+}
+
+// UpdateSecretArgs holds the parameters for making an UpdateSecrets
+// API call.
+type UpdateSecretArgs struct {
+	Args []UpdateSecretArg
+}
+
+// SecretArg identifies a secret, and the unit on whose behalf it is
+// accessed.
+type SecretArg struct {
+	UnitTag  string
+	SecretId string
+}
+
+// SecretArgs holds the parameters for making API calls on secrets.
+type SecretArgs struct {
+	Args []SecretArg
+}
+
+// GrantSecretArg holds the arguments for granting the services at the
+// other end of a relation access to a secret owned by the service of
+// a unit.
+type GrantSecretArg struct {
+	UnitTag     string
+	SecretId    string
+	RelationTag string
+}
+
+// GrantSecretArgs holds the parameters for making a GrantSecrets API
+// call.
+type GrantSecretArgs struct {
+	Args []GrantSecretArg
+}
+
+// SecretValueResult holds the value of a secret, or an error.
+type SecretValueResult struct {
+	Data  map[string]string
+	Error *Error
+}
+
+// SecretValueResults holds the results of a SecretValues API call.
+type SecretValueResults struct {
+	Results []SecretValueResult
+}
+
+// SecretRotation holds when a secret is next due rotation.
+type SecretRotation struct {
+	SecretId       string
+	NextRotateTime time.Time
+}
+
+// SecretRotationsResult holds when the secrets owned by the service
+// of a unit are due rotation, or an error.
+type SecretRotationsResult struct {
+	Rotations []SecretRotation
+	Error     *Error
+}
+
+// SecretRotationsResults holds the results of a SecretRotations API
+// call.
+type SecretRotationsResults struct {
+	Results []SecretRotationsResult
+}
@@ -0,0 +1,28 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// WaitForStabilityArgs holds the arguments to an API call waiting for
+// an environment to become stable.
+type WaitForStabilityArgs struct {
+	// Timeout holds how long to wait for the environment to become
+	// stable. The API server may give up sooner, in which case the
+	// call should be repeated with the time remaining.
+	Timeout time.Duration `json:"timeout"`
+}
+
+// StabilityResult holds whether an environment is stable: with every
+// machine provisioned, every agent connected and idle, and no hooks
+// waiting to run.
+type StabilityResult struct {
+	Stable bool `json:"stable"`
+
+	// Reasons holds why the environment is not stable, when it is
+	// not.
+	Reasons []string `json:"reasons,omitempty"`
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import (
+	"time"
+)
+
+// HistoryKind identifies which status of an entity a status history
+// request refers to.
+type HistoryKind string
+
+const (
+	// KindWorkload identifies the workload status of a unit.
+	KindWorkload HistoryKind = "workload"
+
+	// KindAgent identifies the status of a unit or machine agent.
+	KindAgent HistoryKind = "agent"
+
+	// KindProvisioning identifies the provisioning progress of a
+	// machine.
+	KindProvisioning HistoryKind = "provisioning"
+)
+
+// StatusHistoryArg holds the parameters for a single status history
+// request.
+type StatusHistoryArg struct {
+	// Tag identifies the unit or machine whose history is wanted.
+	Tag string `json:"tag"`
+
+	// Kind selects which status of a unit or machine is wanted.
+	// Machines have a single status, so only their provisioning
+	// progress may be selected as an alternative.
+	Kind HistoryKind `json:"kind"`
+
+	// From and To, if set, restrict the history to entries
+	// recorded within the given time range, inclusive.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+
+	// Size, if positive, limits the history to the most recent
+	// Size entries.
+	Size int `json:"size,omitempty"`
+
+	// Changes, if true, returns only one entry for each change
+	// of status.
+	Changes bool `json:"changes,omitempty"`
+}
+
+// StatusHistoryArgs holds the parameters for a bulk status history
+// request.
+type StatusHistoryArgs struct {
+	Args []StatusHistoryArg `json:"args"`
+}
+
+// HistoricalStatus holds a status held by an entity at some point
+// in time.
+type HistoricalStatus struct {
+	Status Status                 `json:"status"`
+	Info   string                 `json:"info"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Since  time.Time              `json:"since"`
+}
+
+// StatusHistoryResult holds the status history of an entity, most
+// recent first, or an error.
+type StatusHistoryResult struct {
+	History []HistoricalStatus `json:"history"`
+	Error   *Error             `json:"error,omitempty"`
+}
+
+// StatusHistoryResults holds the results of a bulk status history
+// request.
+type StatusHistoryResults struct {
+	Results []StatusHistoryResult `json:"results"`
+}
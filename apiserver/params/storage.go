@@ -233,3 +233,92 @@ type StorageShowResult struct {
 type StorageShowResults struct {
 	Results []StorageShowResult `json:"results,omitempty"`
 }
+
+// StoragePageArgs holds the arguments for listing a page of volumes or
+// filesystems.
+type StoragePageArgs struct {
+	// After is the ID of the last volume or filesystem in the previous
+	// page, or empty to list from the start.
+	After string `json:"after,omitempty"`
+
+	// Limit is the maximum number of entries to return. If zero,
+	// the server's maximum page size is used.
+	Limit int `json:"limit,omitempty"`
+}
+
+// StorageListArgs holds the arguments for listing a page of volumes
+// or filesystems. Each non-empty filter must be matched for a volume
+// or filesystem to be listed.
+type StorageListArgs struct {
+	StoragePageArgs
+
+	// Machines restricts the list to volumes or filesystems
+	// attached to the machines with the given IDs.
+	Machines []string `json:"machines,omitempty"`
+
+	// Services restricts the list to volumes or filesystems backing
+	// storage owned by the named services or their units.
+	Services []string `json:"services,omitempty"`
+
+	// Provisioned, if not nil, restricts the list to volumes or
+	// filesystems that have or have not been provisioned.
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// VolumeDetails describes a volume in the environment, for display
+// to users.
+type VolumeDetails struct {
+	VolumeTag  string `json:"volumetag"`
+	StorageTag string `json:"storagetag,omitempty"`
+	VolumeId   string `json:"volumeid,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	// Size is the size of the volume in MiB.
+	Size        uint64 `json:"size"`
+	Life        Life   `json:"life"`
+	Provisioned bool   `json:"provisioned"`
+}
+
+// ListVolumesResult holds a page of volumes.
+type ListVolumesResult struct {
+	Volumes []VolumeDetails `json:"volumes"`
+
+	// Next is the value to pass as After to list the next page,
+	// or empty if there are no more volumes.
+	Next string `json:"next,omitempty"`
+}
+
+// FilesystemDetails describes a filesystem in the environment, for
+// display to users.
+type FilesystemDetails struct {
+	FilesystemTag string `json:"filesystemtag"`
+	StorageTag    string `json:"storagetag,omitempty"`
+	VolumeTag     string `json:"volumetag,omitempty"`
+	FilesystemId  string `json:"filesystemid,omitempty"`
+	// Size is the size of the filesystem in MiB.
+	Size        uint64 `json:"size"`
+	Life        Life   `json:"life"`
+	Provisioned bool   `json:"provisioned"`
+}
+
+// ListFilesystemsResult holds a page of filesystems.
+type ListFilesystemsResult struct {
+	Filesystems []FilesystemDetails `json:"filesystems"`
+
+	// Next is the value to pass as After to list the next page,
+	// or empty if there are no more filesystems.
+	Next string `json:"next,omitempty"`
+}
+
+// StorageAddParams holds the parameters for adding instances of charm
+// storage to a unit.
+type StorageAddParams struct {
+	UnitTag     string              `json:"unittag"`
+	StorageName string              `json:"storagename"`
+	Constraints storage.Constraints `json:"constraints"`
+}
+
+// StoragesAddParams holds the parameters for adding storage to
+// multiple units.
+type StoragesAddParams struct {
+	Storages []StorageAddParams `json:"storages"`
+}
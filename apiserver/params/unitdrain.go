@@ -0,0 +1,40 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// UnitDrainStatus describes the progress of a dying unit's agent
+// through running the hooks it needs to run before it stops.
+type UnitDrainStatus struct {
+	Phase    string
+	Started  time.Time
+	Deadline time.Time
+	Pending  []string
+}
+
+// SetDrainStatusArg holds the hooks a dying unit's agent has yet to
+// run before it stops, and whether it has finished draining.
+type SetDrainStatusArg struct {
+	Tag     string
+	Pending []string
+	Drained bool
+}
+
+// SetDrainStatusArgs holds the parameters for making a
+// SetDrainStatuses API call.
+type SetDrainStatusArgs struct {
+	Args []SetDrainStatusArg
+}
+
+// DrainStatusResult holds a unit's drain progress, or an error.
+type DrainStatusResult struct {
+	Status *UnitDrainStatus
+	Error  *Error
+}
+
+// DrainStatusResults holds the results of a DrainStatuses API call.
+type DrainStatusResults struct {
+	Results []DrainStatusResult
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// UnitRelationState describes the bookkeeping a unit's agent keeps
+// for one of the unit's relations, recording which relation hooks it
+// has run.
+type UnitRelationState struct {
+	RelationId     int
+	Members        map[string]int64
+	ChangedPending string
+}
+
+// UnitRelationStatesResult holds the bookkeeping a unit's agent keeps
+// for each of the unit's relations, or an error.
+type UnitRelationStatesResult struct {
+	States []UnitRelationState
+	Error  *Error
+}
+
+// UnitRelationStatesResults holds the results of a RelationStates
+// API call.
+type UnitRelationStatesResults struct {
+	Results []UnitRelationStatesResult
+}
+
+// SetUnitRelationStateArg holds the bookkeeping a unit's agent keeps
+// for one of the unit's relations.
+type SetUnitRelationStateArg struct {
+	Tag   string
+	State UnitRelationState
+}
+
+// SetUnitRelationStateArgs holds the parameters for making a
+// SetRelationStates API call.
+type SetUnitRelationStateArgs struct {
+	Args []SetUnitRelationStateArg
+}
+
+// RemoveUnitRelationStateArg identifies a relation of a unit whose
+// bookkeeping is to be removed.
+type RemoveUnitRelationStateArg struct {
+	Tag        string
+	RelationId int
+}
+
+// RemoveUnitRelationStateArgs holds the parameters for making a
+// RemoveRelationStates API call.
+type RemoveUnitRelationStateArgs struct {
+	Args []RemoveUnitRelationStateArg
+}
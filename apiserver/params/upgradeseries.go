@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// UpgradeSeriesPrepareArg holds a machine whose series is to be
+// upgraded, and the series to upgrade it to.
+type UpgradeSeriesPrepareArg struct {
+	Tag    string `json:"tag"`
+	Series string `json:"series"`
+}
+
+// UpgradeSeriesPrepareArgs holds the arguments for preparing machines
+// for an upgrade of their series.
+type UpgradeSeriesPrepareArgs struct {
+	Args []UpgradeSeriesPrepareArg `json:"args"`
+}
+
+// UpgradeSeriesStatus holds the progress of a machine or unit through
+// an upgrade of the machine's series. Status is empty if no upgrade is
+// in progress. Series is the series being upgraded to, and is only
+// reported for machines.
+type UpgradeSeriesStatus struct {
+	Status string `json:"status"`
+	Series string `json:"series,omitempty"`
+}
+
+// UpgradeSeriesStatusResult holds an UpgradeSeriesStatus or an error.
+type UpgradeSeriesStatusResult struct {
+	Result *UpgradeSeriesStatus `json:"result,omitempty"`
+	Error  *Error               `json:"error,omitempty"`
+}
+
+// UpgradeSeriesStatusResults holds the results of an API call to get
+// the series upgrade status of machines or units.
+type UpgradeSeriesStatusResults struct {
+	Results []UpgradeSeriesStatusResult `json:"results"`
+}
+
+// SetUpgradeSeriesStatus holds the progress a unit records through an
+// upgrade of its machine's series.
+type SetUpgradeSeriesStatus struct {
+	Tag    string `json:"tag"`
+	Status string `json:"status"`
+}
+
+// SetUpgradeSeriesStatuses holds the arguments for recording the
+// progress of units through upgrades of their machines' series.
+type SetUpgradeSeriesStatuses struct {
+	Args []SetUpgradeSeriesStatus `json:"args"`
+}
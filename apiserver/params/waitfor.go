@@ -0,0 +1,40 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+// EntityCondition holds a condition on the fields of a machine,
+// service or unit, such as "status == started && units >= 3".
+type EntityCondition struct {
+	Tag       string `json:"tag"`
+	Condition string `json:"condition"`
+}
+
+// EntityConditions holds the arguments to an API call watching
+// conditions on entities.
+type EntityConditions struct {
+	Conditions []EntityCondition `json:"conditions"`
+}
+
+// ConditionResult holds the evaluation of an entity condition.
+type ConditionResult struct {
+	Satisfied bool `json:"satisfied"`
+
+	// Unsatisfied describes each part of the condition that does
+	// not hold, when the condition is not satisfied.
+	Unsatisfied []string `json:"unsatisfied,omitempty"`
+}
+
+// ConditionWatchResult holds the id of a ConditionWatcher, which
+// reports the evaluation of an entity condition whenever it changes,
+// and an error (if any).
+type ConditionWatchResult struct {
+	ConditionWatcherId string `json:"condition-watcher-id"`
+	Error              *Error `json:"error,omitempty"`
+}
+
+// ConditionWatchResults holds the results of an API call watching
+// conditions on entities.
+type ConditionWatchResults struct {
+	Results []ConditionWatchResult `json:"results"`
+}
@@ -605,3 +605,102 @@ func (s *prepareSuite) TestSuccessWhenFirstSubnetNotAllocatable(c *gc.C) {
 		`assigned address ".+" to container "0/lxc/0"`,
 	}})
 }
+
+func (s *prepareSuite) TestAllocateContainerPoolAddresses(c *gc.C) {
+	container := s.newAPI(c, false, true)
+	_, err := s.State.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+		MachineId: s.machines[0].Id(),
+		Bridge:    "lxcbr0",
+		CIDR:      "10.0.3.0/24",
+		Reserved:  []string{"10.0.3.2-10.0.3.99"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.AllocateContainerPoolAddressArgs{
+		Args: []params.AllocateContainerPoolAddressArg{
+			{Tag: container.Tag().String(), Bridge: "lxcbr0", Hostname: "juju-machine-0-lxc-0"},
+			{Tag: container.Tag().String(), Bridge: "lxcbr1", Hostname: "juju-machine-0-lxc-0"},
+			{Tag: s.machines[0].Tag().String(), Bridge: "lxcbr0", Hostname: "juju-machine-0"},
+			{Tag: s.machines[1].Tag().String(), Bridge: "lxcbr0", Hostname: "juju-machine-1"},
+			{Tag: "unit-foo-0", Bridge: "lxcbr0"},
+		},
+	}
+	results, err := s.provAPI.AllocateContainerPoolAddresses(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.MachineNetworkConfigResults{
+		Results: []params.MachineNetworkConfigResult{
+			{Config: []params.NetworkConfig{{
+				DeviceIndex:    0,
+				CIDR:           "10.0.3.0/24",
+				ConfigType:     "static",
+				Address:        "10.0.3.100",
+				GatewayAddress: "10.0.3.1",
+			}}},
+			{Error: &params.Error{
+				Message: "address pool for lxcbr1 on machine 0 not found",
+				Code:    params.CodeNotFound,
+			}},
+			{Error: &params.Error{
+				Message: `cannot allocate address for "machine-0": not a container`,
+			}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Allocating again gives the container the same address.
+	results, err = s.provAPI.AllocateContainerPoolAddresses(params.AllocateContainerPoolAddressArgs{
+		Args: args.Args[:1],
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Config[0].Address, gc.Equals, "10.0.3.100")
+}
+
+func (s *prepareSuite) TestPrepareContainers(c *gc.C) {
+	container1 := s.newAPI(c, true, true)
+	container2, err := s.State.AddMachineInsideMachine(
+		state.MachineTemplate{
+			Series: "quantal",
+			Jobs:   []state.MachineJob{state.JobHostUnits},
+		},
+		s.machines[0].Id(),
+		instance.LXC,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := s.makeArgs(container1, container2, s.machines[0])
+	result, err := s.provAPI.PrepareContainers(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Config.ProviderType, gc.Equals, "dummy")
+	c.Assert(result.Config.AuthorizedKeys, gc.Equals, s.Environ.Config().AuthorizedKeys())
+	c.Assert(result.Results, gc.HasLen, 3)
+	for i := 0; i < 2; i++ {
+		c.Assert(result.Results[i].Error, gc.IsNil)
+		c.Assert(result.Results[i].Config, gc.HasLen, 1)
+		c.Assert(result.Results[i].Config[0].Address, gc.Matches, "0.10.0.[0-9]{1,3}")
+	}
+	c.Assert(result.Results[0].Config[0].Address, gc.Not(gc.Equals), result.Results[1].Config[0].Address)
+	c.Assert(result.Results[2].Error, gc.ErrorMatches, `cannot allocate address for "machine-0": not a container`)
+}
+
+func (s *prepareSuite) TestPrepareContainersNoContainers(c *gc.C) {
+	s.newAPI(c, false, false)
+	result, err := s.provAPI.PrepareContainers(params.Entities{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Config.ProviderType, gc.Equals, "dummy")
+	c.Assert(result.Results, gc.HasLen, 0)
+}
+
+func (s *prepareSuite) TestPrepareContainersWithNonProvisionedHost(c *gc.C) {
+	container := s.newAPI(c, false, true)
+	result, err := s.provAPI.PrepareContainers(s.makeArgs(container))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Config.ProviderType, gc.Equals, "dummy")
+	c.Assert(result.Results, jc.DeepEquals, []params.MachineNetworkConfigResult{{
+		Error: &params.Error{
+			Message: `cannot allocate addresses: host machine "0" not provisioned`,
+			Code:    params.CodeNotProvisioned,
+		},
+	}})
+}
@@ -75,12 +75,15 @@ func NewProvisionerAPI(st *state.State, resources *common.Resources, authorizer
 					// environment manager.
 					return isEnvironManager
 				}
-				// All containers with the authenticated machine as a
-				// parent are accessible by it.
+				// All containers hosted by the authenticated machine,
+				// at any level of nesting, are accessible by it.
 				// TODO(dfc) sometimes authEntity tag is nil, which is fine because nil is
 				// only equal to nil, but it suggests someone is passing an authorizer
 				// with a nil tag.
-				return isMachineAgent && names.NewMachineTag(parentId) == authEntityTag
+				if !isMachineAgent || authEntityTag == nil {
+					return false
+				}
+				return state.IsContainedBy(tag.Id(), authEntityTag.Id())
 			default:
 				return false
 			}
@@ -378,6 +381,12 @@ func (p *ProvisionerAPI) ProvisioningInfo(args params.Entities) (params.Provisio
 	if err != nil {
 		return result, err
 	}
+	// No machines are provisioned while the environment is suspended.
+	env, err := p.st.Environment()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	suspended, reason := env.Suspended()
 	for i, entity := range args.Entities {
 		tag, err := names.ParseMachineTag(entity.Tag)
 		if err != nil {
@@ -385,7 +394,9 @@ func (p *ProvisionerAPI) ProvisioningInfo(args params.Entities) (params.Provisio
 			continue
 		}
 		machine, err := p.getMachine(canAccess, tag)
-		if err == nil {
+		if err == nil && suspended {
+			err = common.EnvironmentSuspendedError(reason)
+		} else if err == nil {
 			result.Results[i].Result, err = p.getProvisioningInfo(machine)
 		}
 		result.Results[i].Error = common.ServerError(err)
@@ -783,6 +794,34 @@ func (p *ProvisionerAPI) SetInstanceInfo(args params.InstancesInfo) (params.Erro
 	return result, nil
 }
 
+// SetProvisioningStatus records the provisioning progress of each
+// given machine in its provisioning status history.
+func (p *ProvisionerAPI) SetProvisioningStatus(args params.SetStatus) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := p.getAuthFunc()
+	if err != nil {
+		return result, err
+	}
+	setProvisioningStatus := func(arg params.EntityStatus) error {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			return common.ErrPerm
+		}
+		machine, err := p.getMachine(canAccess, tag)
+		if err != nil {
+			return err
+		}
+		return machine.SetProvisioningStatus(state.Status(arg.Status), arg.Info, arg.Data)
+	}
+	for i, arg := range args.Entities {
+		err := setProvisioningStatus(arg)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
 // WatchMachineErrorRetry returns a NotifyWatcher that notifies when
 // the provisioner should retry provisioning machines with transient errors.
 func (p *ProvisionerAPI) WatchMachineErrorRetry() (params.NotifyWatchResult, error) {
@@ -812,15 +851,25 @@ func (p *ProvisionerAPI) PrepareContainerInterfaceInfo(args params.Entities) (pa
 	if err != nil {
 		return result, errors.Trace(err)
 	}
-	instId, err := host.InstanceId()
+	// The provider only knows about top level machines, so addresses
+	// for containers nested inside other containers are allocated on
+	// the instance of the top level machine hosting them.
+	allocationHost := host
+	if host.IsContainer() {
+		allocationHost, err = p.st.Machine(state.TopParentId(host.Id()))
+		if err != nil {
+			return result, errors.Annotate(err, "cannot allocate addresses")
+		}
+	}
+	instId, err := allocationHost.InstanceId()
 	if err != nil && errors.IsNotProvisioned(err) {
 		// If the host machine is not provisioned yet, we have nothing
 		// to do. NotProvisionedf will append " not provisioned" to
 		// the message.
-		err = errors.NotProvisionedf("cannot allocate addresses: host machine %q", host)
+		err = errors.NotProvisionedf("cannot allocate addresses: host machine %q", allocationHost)
 		return result, err
 	}
-	subnet, subnetInfo, interfaceInfo, err := p.prepareAllocationNetwork(environ, host, instId)
+	subnet, subnetInfo, interfaceInfo, err := p.prepareAllocationNetwork(environ, allocationHost, instId)
 	if err != nil {
 		return result, errors.Annotate(err, "cannot allocate addresses")
 	}
@@ -863,6 +912,12 @@ func (p *ProvisionerAPI) PrepareContainerInterfaceInfo(args params.Entities) (pa
 			result.Results[i].Error = common.ServerError(err)
 			continue
 		}
+		// Nested containers are routed through the container hosting
+		// them, rather than the top level machine.
+		gatewayAddress := interfaceInfo.Address.Value
+		if allocationHost != host {
+			gatewayAddress = network.SelectInternalAddress(host.Addresses(), false)
+		}
 		// Store it on the machine, construct and set an interface result.
 		dnsServers := make([]string, len(interfaceInfo.DNSServers))
 		for i, dns := range interfaceInfo.DNSServers {
@@ -887,7 +942,7 @@ func (p *ProvisionerAPI) PrepareContainerInterfaceInfo(args params.Entities) (pa
 				ConfigType:       string(network.ConfigStatic),
 				Address:          addr.Value(),
 				// container's gateway is the host's primary NIC's IP.
-				GatewayAddress: interfaceInfo.Address.Value,
+				GatewayAddress: gatewayAddress,
 				ExtraConfig:    interfaceInfo.ExtraConfig,
 			}},
 		}
@@ -895,6 +950,90 @@ func (p *ProvisionerAPI) PrepareContainerInterfaceInfo(args params.Entities) (pa
 	return result, nil
 }
 
+// PrepareContainers returns, in a single call, the container config
+// and the network configuration of each of the given containers, as
+// returned by ContainerConfig and PrepareContainerInterfaceInfo. It
+// lets a host starting many containers at once avoid a round trip per
+// container. If addresses cannot be allocated on the host at all, the
+// error is reported for every container.
+func (p *ProvisionerAPI) PrepareContainers(args params.Entities) (params.PrepareContainersResult, error) {
+	config, err := p.ContainerConfig()
+	if err != nil {
+		return params.PrepareContainersResult{}, errors.Trace(err)
+	}
+	result := params.PrepareContainersResult{Config: config}
+	if len(args.Entities) == 0 {
+		return result, nil
+	}
+	ifaces, err := p.PrepareContainerInterfaceInfo(args)
+	if err != nil {
+		ifaces.Results = make([]params.MachineNetworkConfigResult, len(args.Entities))
+		for i := range ifaces.Results {
+			ifaces.Results[i].Error = common.ServerError(err)
+		}
+	}
+	result.Results = ifaces.Results
+	return result, nil
+}
+
+// AllocateContainerPoolAddresses allocates addresses to the given
+// containers from the controller-managed address pools of bridges on
+// their host machines. It's used when the provider cannot allocate
+// container addresses, so that the containers are still configured
+// with deterministic addresses rather than by DHCP. The result for a
+// container whose bridge has no pool satisfies params.IsCodeNotFound.
+func (p *ProvisionerAPI) AllocateContainerPoolAddresses(args params.AllocateContainerPoolAddressArgs) (params.MachineNetworkConfigResults, error) {
+	result := params.MachineNetworkConfigResults{
+		Results: make([]params.MachineNetworkConfigResult, len(args.Args)),
+	}
+	canAccess, err := p.getAuthFunc()
+	if err != nil {
+		return result, errors.Annotate(err, "cannot authenticate request")
+	}
+	for i, arg := range args.Args {
+		tag, err := names.ParseMachineTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		config, err := p.allocateContainerPoolAddress(canAccess, tag, arg.Bridge, arg.Hostname)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Config = []params.NetworkConfig{config}
+	}
+	return result, nil
+}
+
+func (p *ProvisionerAPI) allocateContainerPoolAddress(
+	canAccess common.AuthFunc, tag names.MachineTag, bridge, hostname string,
+) (params.NetworkConfig, error) {
+	container, err := p.getMachine(canAccess, tag)
+	if err != nil {
+		return params.NetworkConfig{}, err
+	}
+	hostId, ok := container.ParentId()
+	if !ok {
+		return params.NetworkConfig{}, errors.Errorf("cannot allocate address for %q: not a container", tag)
+	}
+	pool, err := p.st.ContainerAddressPool(hostId, bridge)
+	if err != nil {
+		return params.NetworkConfig{}, errors.Trace(err)
+	}
+	address, err := pool.AllocateAddress(container.Id(), hostname)
+	if err != nil {
+		return params.NetworkConfig{}, errors.Trace(err)
+	}
+	return params.NetworkConfig{
+		DeviceIndex:    0,
+		CIDR:           pool.CIDR(),
+		ConfigType:     string(network.ConfigStatic),
+		Address:        address.Value(),
+		GatewayAddress: pool.Gateway(),
+	}, nil
+}
+
 // prepareAllocationEnvironment retrieves the environment, host machine, and access
 // for the allocations.
 func (p *ProvisionerAPI) prepareAllocationEnvironment() (environs.NetworkingEnviron, *state.Machine, common.AuthFunc, error) {
@@ -230,6 +230,44 @@ func (s *withoutStateServerSuite) TestLifeAsMachineAgent(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestLifeAsMachineAgentNestedContainers(c *gc.C) {
+	// Machine agents can access containers nested at any level
+	// inside their own machine, but not the machines hosting them.
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, s.machines[0].Id(), instance.KVM)
+	c.Assert(err, jc.ErrorIsNil)
+	nested, err := s.State.AddMachineInsideMachine(template, container.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.machines[0].Tag().String()},
+		{Tag: container.Tag().String()},
+		{Tag: nested.Tag().String()},
+	}}
+	for i, authTag := range []names.Tag{s.machines[0].Tag(), container.Tag()} {
+		c.Logf("test %d: authenticated as %s", i, authTag)
+		anAuthorizer := s.authorizer
+		anAuthorizer.EnvironManager = false
+		anAuthorizer.Tag = authTag
+		aProvisioner, err := provisioner.NewProvisionerAPI(s.State, s.resources, anAuthorizer)
+		c.Assert(err, jc.ErrorIsNil)
+
+		result, err := aProvisioner.Life(args)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(result.Results, gc.HasLen, 3)
+		if i == 0 {
+			c.Check(result.Results[0], gc.DeepEquals, params.LifeResult{Life: "alive"})
+		} else {
+			c.Check(result.Results[0], gc.DeepEquals, params.LifeResult{Error: apiservertesting.ErrUnauthorized})
+		}
+		c.Check(result.Results[1], gc.DeepEquals, params.LifeResult{Life: "alive"})
+		c.Check(result.Results[2], gc.DeepEquals, params.LifeResult{Life: "alive"})
+	}
+}
+
 func (s *withoutStateServerSuite) TestLifeAsEnvironManager(c *gc.C) {
 	err := s.machines[1].EnsureDead()
 	c.Assert(err, jc.ErrorIsNil)
@@ -298,9 +336,15 @@ func (s *withoutStateServerSuite) TestRemove(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.DeepEquals, params.ErrorResults{
 		Results: []params.ErrorResult{
-			{&params.Error{Message: `cannot remove entity "machine-0": still alive`}},
+			{&params.Error{
+				Message: `cannot remove entity "machine-0": still alive`,
+				Code:    params.CodeWrongLifecycle,
+			}},
 			{nil},
-			{&params.Error{Message: `cannot remove entity "machine-2": still alive`}},
+			{&params.Error{
+				Message: `cannot remove entity "machine-2": still alive`,
+				Code:    params.CodeWrongLifecycle,
+			}},
 			{apiservertesting.NotFoundError("machine 42")},
 			{apiservertesting.ErrUnauthorized},
 			{apiservertesting.ErrUnauthorized},
@@ -351,6 +395,37 @@ func (s *withoutStateServerSuite) TestSetStatus(c *gc.C) {
 	s.assertStatus(c, 2, state.StatusStarted, "again", map[string]interface{}{})
 }
 
+func (s *withoutStateServerSuite) TestSetProvisioningStatus(c *gc.C) {
+	args := params.SetStatus{
+		Entities: []params.EntityStatus{
+			{Tag: s.machines[0].Tag().String(), Status: params.StatusImageSelected, Info: "ami-blah",
+				Data: map[string]interface{}{"series": "quantal"}},
+			{Tag: s.machines[1].Tag().String(), Status: params.StatusStarted},
+			{Tag: "machine-42", Status: params.StatusInstanceRequested},
+			{Tag: "unit-foo-0", Status: params.StatusInstanceRequested},
+		}}
+	result, err := s.provisioner.SetProvisioningStatus(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{&params.Error{Message: `cannot set invalid provisioning status "started"`}},
+			{apiservertesting.NotFoundError("machine 42")},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	history, err := s.machines[0].ProvisioningStatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].Status, gc.Equals, state.StatusImageSelected)
+	c.Assert(history[0].Info, gc.Equals, "ami-blah")
+	c.Assert(history[0].Data, jc.DeepEquals, map[string]interface{}{"series": "quantal"})
+	history, err = s.machines[1].ProvisioningStatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+}
+
 func (s *withoutStateServerSuite) TestMachinesWithTransientErrors(c *gc.C) {
 	err := s.machines[0].SetStatus(state.StatusStarted, "blah", nil)
 	c.Assert(err, jc.ErrorIsNil)
@@ -882,6 +957,29 @@ func (s *withoutStateServerSuite) TestProvisioningInfoPermissions(c *gc.C) {
 	})
 }
 
+func (s *withoutStateServerSuite) TestProvisioningInfoEnvironmentSuspended(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValidity(false, "secret rejected")
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.machines[0].Tag().String()},
+		{Tag: "machine-42"},
+	}}
+	results, err := s.provisioner.ProvisioningInfo(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.DeepEquals, params.ProvisioningInfoResults{
+		Results: []params.ProvisioningInfoResult{
+			{Error: &params.Error{
+				Code:    params.CodeEnvironmentSuspended,
+				Message: "environment suspended: secret rejected",
+			}},
+			{Error: apiservertesting.NotFoundError("machine 42")},
+		},
+	})
+}
+
 func (s *withoutStateServerSuite) TestConstraints(c *gc.C) {
 	// Add a machine with some constraints.
 	cons := constraints.MustParse("cpu-cores=123", "mem=8G", "networks=net3,^net4")
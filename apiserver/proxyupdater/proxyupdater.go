@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package proxyupdater implements the API used by machine and unit
+// agents to keep the proxy settings of their machines and hook
+// contexts in line with the environment configuration.
+package proxyupdater
+
+import (
+	"github.com/juju/utils/proxy"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("ProxyUpdater", 1, NewProxyUpdaterAPI)
+}
+
+// ProxyUpdaterAPI provides access to the ProxyUpdater API facade.
+type ProxyUpdaterAPI struct {
+	st        state.EnvironAccessor
+	resources *common.Resources
+}
+
+// NewProxyUpdaterAPI creates a new server-side ProxyUpdater API facade.
+func NewProxyUpdaterAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*ProxyUpdaterAPI, error) {
+	if !authorizer.AuthMachineAgent() && !authorizer.AuthUnitAgent() {
+		return nil, common.ErrPerm
+	}
+	return &ProxyUpdaterAPI{
+		st:        st,
+		resources: resources,
+	}, nil
+}
+
+// WatchForProxyConfigChanges returns a NotifyWatcher that observes
+// changes to the environment configuration, which holds the proxy
+// settings.
+func (p *ProxyUpdaterAPI) WatchForProxyConfigChanges() (params.NotifyWatchResult, error) {
+	result := params.NotifyWatchResult{}
+	watch := p.st.WatchForEnvironConfigChanges()
+	// Consume the initial event; NotifyWatchers have no state to
+	// transmit in the Watch response.
+	if _, ok := <-watch.Changes(); ok {
+		result.NotifyWatcherId = p.resources.Register(watch)
+	} else {
+		return result, watcher.EnsureErr(watch)
+	}
+	return result, nil
+}
+
+// ProxyConfig returns the proxy settings of the environment: those
+// for general use, and those used by apt and by snapd.
+func (p *ProxyUpdaterAPI) ProxyConfig() (params.ProxyConfigResult, error) {
+	cfg, err := p.st.EnvironConfig()
+	if err != nil {
+		return params.ProxyConfigResult{}, err
+	}
+	return params.ProxyConfigResult{
+		ProxySettings:     toParams(cfg.ProxySettings()),
+		AptProxySettings:  toParams(cfg.AptProxySettings()),
+		SnapProxySettings: toParams(cfg.SnapProxySettings()),
+	}, nil
+}
+
+func toParams(settings proxy.Settings) params.ProxyConfig {
+	return params.ProxyConfig{
+		Http:    settings.Http,
+		Https:   settings.Https,
+		Ftp:     settings.Ftp,
+		NoProxy: settings.NoProxy,
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package proxyupdater_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/proxyupdater"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type proxyUpdaterSuite struct {
+	jujutesting.JujuConnSuite
+	unit      *state.Unit
+	resources *common.Resources
+	api       *proxyupdater.ProxyUpdaterAPI
+}
+
+var _ = gc.Suite(&proxyUpdaterSuite{})
+
+func (s *proxyUpdaterSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.unit = s.Factory.MakeUnit(c, &factory.UnitParams{})
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	auth := apiservertesting.FakeAuthorizer{Tag: s.unit.Tag()}
+	var err error
+	s.api, err = proxyupdater.NewProxyUpdaterAPI(s.State, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *proxyUpdaterSuite) TestNewAPIAcceptsMachineAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := proxyupdater.NewProxyUpdaterAPI(s.State, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *proxyUpdaterSuite) TestNewAPIRefusesClients(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	_, err := proxyupdater.NewProxyUpdaterAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *proxyUpdaterSuite) TestProxyConfig(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"http-proxy":       "http://http.proxy",
+		"https-proxy":      "https://https.proxy",
+		"no-proxy":         "localhost",
+		"apt-http-proxy":   "http://apt.http.proxy",
+		"snap-https-proxy": "https://snap.https.proxy",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.ProxyConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ProxyConfigResult{
+		ProxySettings: params.ProxyConfig{
+			Http:    "http://http.proxy",
+			Https:   "https://https.proxy",
+			NoProxy: "localhost",
+		},
+		AptProxySettings: params.ProxyConfig{
+			Http:  "http://apt.http.proxy",
+			Https: "https://https.proxy",
+		},
+		SnapProxySettings: params.ProxyConfig{
+			Http:  "http://http.proxy",
+			Https: "https://snap.https.proxy",
+		},
+	})
+}
+
+func (s *proxyUpdaterSuite) TestWatchForProxyConfigChanges(c *gc.C) {
+	result, err := s.api.WatchForProxyConfigChanges()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NotifyWatchResult{NotifyWatcherId: "1"})
+
+	resource := s.resources.Get("1")
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"http-proxy": "http://http.proxy"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package raftlease provides the API used by the state servers to
+// run the raft cluster which holds their leases.
+package raftlease
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/raft"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("RaftLease", 1, NewRaftLeaseAPI)
+}
+
+// proposeTimeout is how long a proposal forwarded by another state
+// server may take to be committed.
+var proposeTimeout = 10 * time.Second
+
+// RaftLeaseAPI implements the RaftLease facade.
+type RaftLeaseAPI struct {
+	node func() (raftlease.Node, error)
+}
+
+// NewRaftLeaseAPI creates a new server-side RaftLease facade. It is
+// only available to state server agents.
+func NewRaftLeaseAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*RaftLeaseAPI, error) {
+	if !auth.AuthEnvironManager() {
+		return nil, common.ErrPerm
+	}
+	return &RaftLeaseAPI{
+		node: raftlease.Manager().Node,
+	}, nil
+}
+
+// RequestVote passes a candidate's request for a vote to this state
+// server's raft node.
+func (api *RaftLeaseAPI) RequestVote(args raft.RequestVoteArgs) (raft.RequestVoteResult, error) {
+	node, err := api.node()
+	if err != nil {
+		return raft.RequestVoteResult{}, errors.Trace(err)
+	}
+	return node.RequestVote(args)
+}
+
+// AppendEntries passes log entries sent by the leader to this state
+// server's raft node.
+func (api *RaftLeaseAPI) AppendEntries(args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error) {
+	node, err := api.node()
+	if err != nil {
+		return raft.AppendEntriesResult{}, errors.Trace(err)
+	}
+	return node.AppendEntries(args)
+}
+
+// InstallSnapshot passes a snapshot sent by the leader to this state
+// server's raft node.
+func (api *RaftLeaseAPI) InstallSnapshot(args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error) {
+	node, err := api.node()
+	if err != nil {
+		return raft.InstallSnapshotResult{}, errors.Trace(err)
+	}
+	return node.InstallSnapshot(args)
+}
+
+// Propose commits a command on behalf of another state server. It
+// fails if this state server's raft node is not the leader.
+func (api *RaftLeaseAPI) Propose(args params.RaftProposeArgs) (params.RaftProposeResult, error) {
+	node, err := api.node()
+	if err != nil {
+		return params.RaftProposeResult{}, errors.Trace(err)
+	}
+	result, err := node.Propose(args.Command, proposeTimeout)
+	if err != nil {
+		return params.RaftProposeResult{}, errors.Trace(err)
+	}
+	return params.RaftProposeResult{Result: result}, nil
+}
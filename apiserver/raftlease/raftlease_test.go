@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/raftlease"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	leaseraftlease "github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/raft"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type raftLeaseSuite struct {
+	coretesting.BaseSuite
+
+	node *fakeNode
+	api  *raftlease.RaftLeaseAPI
+}
+
+var _ = gc.Suite(&raftLeaseSuite{})
+
+func (s *raftLeaseSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.node = &fakeNode{}
+	detach := leaseraftlease.Manager().Attach(s.node, leaseraftlease.NewFSM(nil), nil)
+	s.AddCleanup(func(*gc.C) { detach() })
+
+	var err error
+	s.api, err = raftlease.NewRaftLeaseAPI(nil, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag:            names.NewMachineTag("0"),
+		EnvironManager: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *raftLeaseSuite) TestNewAPIRefusesNonStateServer(c *gc.C) {
+	_, err := raftlease.NewRaftLeaseAPI(nil, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = raftlease.NewRaftLeaseAPI(nil, common.NewResources(), apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("admin"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *raftLeaseSuite) TestRequestVote(c *gc.C) {
+	args := raft.RequestVoteArgs{Term: 3, CandidateId: "1", LastLogIndex: 10, LastLogTerm: 2}
+	result, err := s.api.RequestVote(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.RequestVoteResult{Term: 3, Granted: true})
+	c.Assert(s.node.calls, jc.DeepEquals, []interface{}{args})
+}
+
+func (s *raftLeaseSuite) TestAppendEntries(c *gc.C) {
+	args := raft.AppendEntriesArgs{
+		Term:         3,
+		LeaderId:     "1",
+		PrevLogIndex: 10,
+		PrevLogTerm:  2,
+		Entries:      []raft.Entry{{Index: 11, Term: 3, Command: []byte("x")}},
+		LeaderCommit: 10,
+	}
+	result, err := s.api.AppendEntries(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.AppendEntriesResult{Term: 3, Success: true, LastIndex: 11})
+	c.Assert(s.node.calls, jc.DeepEquals, []interface{}{args})
+}
+
+func (s *raftLeaseSuite) TestInstallSnapshot(c *gc.C) {
+	args := raft.InstallSnapshotArgs{Term: 3, LeaderId: "1", LastIndex: 10, LastTerm: 2, Data: []byte("{}")}
+	result, err := s.api.InstallSnapshot(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, raft.InstallSnapshotResult{Term: 3})
+	c.Assert(s.node.calls, jc.DeepEquals, []interface{}{args})
+}
+
+func (s *raftLeaseSuite) TestPropose(c *gc.C) {
+	result, err := s.api.Propose(params.RaftProposeArgs{Command: []byte("cmd")})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(result.Result), gc.Equals, "applied cmd")
+}
+
+func (s *raftLeaseSuite) TestProposeError(c *gc.C) {
+	s.node.err = &raft.NotLeaderError{Leader: "1"}
+	_, err := s.api.Propose(params.RaftProposeArgs{Command: []byte("cmd")})
+	c.Assert(err, gc.ErrorMatches, `not the raft leader, leader is "1"`)
+}
+
+func (s *raftLeaseSuite) TestNotRunning(c *gc.C) {
+	node := &fakeNode{}
+	detach := leaseraftlease.Manager().Attach(node, leaseraftlease.NewFSM(nil), nil)
+	detach()
+	_, err := s.api.RequestVote(raft.RequestVoteArgs{})
+	c.Assert(err, gc.ErrorMatches, "lease service not running")
+}
+
+type fakeNode struct {
+	calls []interface{}
+	err   error
+}
+
+func (n *fakeNode) Id() string {
+	return "0"
+}
+
+func (n *fakeNode) Leader() string {
+	return "0"
+}
+
+func (n *fakeNode) Propose(command []byte, timeout time.Duration) ([]byte, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	return []byte("applied " + string(command)), nil
+}
+
+func (n *fakeNode) RequestVote(args raft.RequestVoteArgs) (raft.RequestVoteResult, error) {
+	n.calls = append(n.calls, args)
+	return raft.RequestVoteResult{Term: args.Term, Granted: true}, nil
+}
+
+func (n *fakeNode) AppendEntries(args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error) {
+	n.calls = append(n.calls, args)
+	return raft.AppendEntriesResult{
+		Term:      args.Term,
+		Success:   true,
+		LastIndex: args.PrevLogIndex + uint64(len(args.Entries)),
+	}, nil
+}
+
+func (n *fakeNode) InstallSnapshot(args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error) {
+	n.calls = append(n.calls, args)
+	return raft.InstallSnapshotResult{Term: args.Term}, nil
+}
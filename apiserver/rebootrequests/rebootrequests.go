@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package rebootrequests provides the API used by machine agents to
+// coordinate a machine's reboot with the containers it hosts.
+package rebootrequests
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("RebootRequests", 1, NewRebootRequestsAPI)
+}
+
+// RebootRequestsAPI implements the RebootRequests facade. Containers
+// use it to acknowledge that they are ready for their host to reboot,
+// and hosts use it to find out which containers they are waiting for.
+type RebootRequestsAPI struct {
+	st   state.EntityFinder
+	auth common.Authorizer
+}
+
+// NewRebootRequestsAPI creates a new server-side RebootRequests facade.
+func NewRebootRequestsAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*RebootRequestsAPI, error) {
+	if !auth.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &RebootRequestsAPI{
+		st:   st,
+		auth: auth,
+	}, nil
+}
+
+func (api *RebootRequestsAPI) getAcknowledger(tag string) (state.RebootAcknowledger, error) {
+	machineTag, err := names.ParseMachineTag(tag)
+	if err != nil || !api.auth.AuthOwner(machineTag) {
+		return nil, common.ErrPerm
+	}
+	entity, err := api.st.FindEntity(machineTag)
+	if err != nil {
+		return nil, err
+	}
+	acknowledger, ok := entity.(state.RebootAcknowledger)
+	if !ok {
+		return nil, common.NotSupportedError(machineTag, "reboot acknowledgement")
+	}
+	return acknowledger, nil
+}
+
+// AcknowledgeReboot records that each of the given machines, which
+// must be containers, has stopped running hooks and is ready for its
+// host to reboot.
+func (api *RebootRequestsAPI) AcknowledgeReboot(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		acknowledger, err := api.getAcknowledger(entity.Tag)
+		if err == nil {
+			err = acknowledger.AcknowledgeReboot()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// PendingAcknowledgements returns, for each of the given machines,
+// the tags of its containers that have not yet acknowledged its
+// reboot request.
+func (api *RebootRequestsAPI) PendingAcknowledgements(args params.Entities) (params.StringsResults, error) {
+	result := params.StringsResults{
+		Results: make([]params.StringsResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		acknowledger, err := api.getAcknowledger(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		ids, err := acknowledger.PendingRebootAcknowledgements()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		tags := make([]string, len(ids))
+		for j, id := range ids {
+			tags[j] = names.NewMachineTag(id).String()
+		}
+		result.Results[i].Result = tags
+	}
+	return result, nil
+}
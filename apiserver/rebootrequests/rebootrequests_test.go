@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package rebootrequests_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/rebootrequests"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type rebootRequestsSuite struct {
+	jujutesting.JujuConnSuite
+
+	machine   *state.Machine
+	container *state.Machine
+}
+
+var _ = gc.Suite(&rebootRequestsSuite{})
+
+func (s *rebootRequestsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.container, err = s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, s.machine.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *rebootRequestsSuite) newAPI(c *gc.C, machine *state.Machine) *rebootrequests.RebootRequestsAPI {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: machine.Tag()}
+	api, err := rebootrequests.NewRebootRequestsAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	return api
+}
+
+func (s *rebootRequestsSuite) TestNewAPIRefusesNonMachineAgent(c *gc.C) {
+	authorizer := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	_, err := rebootrequests.NewRebootRequestsAPI(s.State, common.NewResources(), authorizer)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *rebootRequestsSuite) TestAcknowledgeReboot(c *gc.C) {
+	err := s.machine.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	hostAPI := s.newAPI(c, s.machine)
+	args := params.Entities{Entities: []params.Entity{{Tag: s.machine.Tag().String()}}}
+	pending, err := hostAPI.PendingAcknowledgements(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, params.StringsResults{
+		Results: []params.StringsResult{{Result: []string{s.container.Tag().String()}}},
+	})
+
+	containerAPI := s.newAPI(c, s.container)
+	result, err := containerAPI.AcknowledgeReboot(params.Entities{
+		Entities: []params.Entity{{Tag: s.container.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}},
+	})
+
+	pending, err = hostAPI.PendingAcknowledgements(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, params.StringsResults{
+		Results: []params.StringsResult{{Result: []string{}}},
+	})
+}
+
+func (s *rebootRequestsSuite) TestAcknowledgeRebootNotRequested(c *gc.C) {
+	containerAPI := s.newAPI(c, s.container)
+	result, err := containerAPI.AcknowledgeReboot(params.Entities{
+		Entities: []params.Entity{{Tag: s.container.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *rebootRequestsSuite) TestPermissions(c *gc.C) {
+	containerAPI := s.newAPI(c, s.container)
+	args := params.Entities{Entities: []params.Entity{
+		{Tag: s.machine.Tag().String()},
+		{Tag: "unit-mysql-0"},
+		{Tag: "invalid"},
+	}}
+	result, err := containerAPI.AcknowledgeReboot(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	pending, err := containerAPI.PendingAcknowledgements(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, params.StringsResults{
+		Results: []params.StringsResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
@@ -60,6 +60,7 @@ var allowedMethodsAboutToRestore = set.NewStrings(
 	"Client.EnvironmentGet", // for "juju ssh"
 	"Client.PrivateAddress", // for "juju ssh"
 	"Client.PublicAddress",  // for "juju ssh"
+	"Client.SSHHostKeys",    // for "juju ssh"
 	"Client.WatchDebugLog",  // for "juju debug-log"
 	"Backups.Restore",       // for "juju backups restore"
 	"Backups.FinishRestore", // for "juju backups restore"
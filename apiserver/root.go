@@ -30,6 +30,12 @@ var (
 	// alive. When the ping returns an error, the server will be
 	// terminated.
 	mongoPingInterval = 10 * time.Second
+
+	// maxRequestDuration defines the time after which the context
+	// of an API request is abandoned, so that facade methods taking
+	// an rpcreflect.Context stop working on calls nobody is likely
+	// to be waiting for.
+	maxRequestDuration = 10 * time.Minute
 )
 
 type objectKey struct {
@@ -68,6 +74,12 @@ func newApiHandler(srv *Server, st *state.State, rpcConn *rpc.Conn, reqNotifier
 	if err := r.resources.RegisterNamed("logDir", common.StringResource(srv.logDir)); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if err := r.resources.RegisterNamed("connections", srv.connections); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := r.resources.RegisterNamed("statusCache", srv.statusCache); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return r, nil
 }
 
@@ -116,13 +128,14 @@ func (s *srvCaller) ResultType() reflect.Type {
 }
 
 // Call takes the object Id and an instance of ParamsType to create an object and place
-// a call on its method. It then returns an instance of ResultType.
-func (s *srvCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+// a call on its method. It then returns an instance of ResultType. The
+// call's context is passed on to methods that take one.
+func (s *srvCaller) Call(ctx rpcreflect.Context, objId string, arg reflect.Value) (reflect.Value, error) {
 	objVal, err := s.creator(objId)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	return s.objMethod.Call(objVal, arg)
+	return s.objMethod.Call(ctx, objVal, arg)
 }
 
 // apiRoot implements basic method dispatching to the facade registry.
@@ -235,6 +248,16 @@ func (r *apiRoot) lookupMethod(rootName string, version int, methodName string)
 		}
 		return nil, noMethod, err
 	}
+	enabled, err := r.environFeatureEnabled(rootName, version)
+	if err != nil {
+		return nil, noMethod, err
+	}
+	if !enabled {
+		return nil, noMethod, &rpcreflect.CallNotImplementedError{
+			RootMethod: rootName,
+			Version:    version,
+		}
+	}
 	rpcType := rpcreflect.ObjTypeOf(goType)
 	objMethod, err := rpcType.Method(methodName)
 	if err != nil {
@@ -250,6 +273,30 @@ func (r *apiRoot) lookupMethod(rootName string, version int, methodName string)
 	return goType, objMethod, nil
 }
 
+// environFeatureEnabled reports whether the environment served by the
+// root has enabled the feature, if any, that the given facade version
+// requires. The environment's configuration is read on every call, so
+// that changes to its features take effect immediately.
+func (r *apiRoot) environFeatureEnabled(rootName string, version int) (bool, error) {
+	feature, err := common.Facades.GetEnvironFeature(rootName, version)
+	if err != nil {
+		return false, err
+	}
+	if feature == "" {
+		return true, nil
+	}
+	cfg, err := r.state.EnvironConfig()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, enabled := range cfg.Features() {
+		if enabled == feature {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // AnonRoot dispatches API calls to those available to an anonymous connection
 // which has not logged in.
 type anonRoot struct {
@@ -325,8 +372,9 @@ func (r *apiHandler) GetAuthEntity() state.Entity {
 }
 
 // DescribeFacades returns the list of available Facades and their Versions
-func DescribeFacades() []params.FacadeVersions {
-	facades := common.Facades.List()
+// in an environment with the given feature flags enabled.
+func DescribeFacades(environFeatures []string) []params.FacadeVersions {
+	facades := common.Facades.ListForEnviron(environFeatures)
 	result := make([]params.FacadeVersions, len(facades))
 	for i, facade := range facades {
 		result[i].Name = facade.Name
@@ -15,6 +15,8 @@ import (
 
 	"github.com/juju/juju/apiserver"
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/rpc/rpcreflect"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
@@ -95,7 +97,7 @@ func (s *errRootSuite) TestErrorRootViaRPC(c *gc.C) {
 	val := rpcreflect.ValueOf(reflect.ValueOf(errRoot))
 	caller, err := val.FindMethod("Admin", 0, "Login")
 	c.Assert(err, jc.ErrorIsNil)
-	resp, err := caller.Call("", reflect.Value{})
+	resp, err := caller.Call(rpcreflect.Background(), "", reflect.Value{})
 	c.Check(err, gc.Equals, origErr)
 	c.Check(resp.IsValid(), jc.IsFalse)
 }
@@ -171,20 +173,20 @@ func (r *rootSuite) TestFindMethodEnsuresTypeMatch(c *gc.C) {
 	// fine
 	caller, err := srvRoot.FindMethod("my-testing-facade", 1, "Exposed")
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = caller.Call("", reflect.Value{})
+	_, err = caller.Call(rpcreflect.Background(), "", reflect.Value{})
 	c.Check(err, gc.ErrorMatches, "Exposed was bogus")
 	// However, myBadFacade returns the wrong type, so trying to access it
 	// should create an error
 	caller, err = srvRoot.FindMethod("my-testing-facade", 0, "Exposed")
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = caller.Call("", reflect.Value{})
+	_, err = caller.Call(rpcreflect.Background(), "", reflect.Value{})
 	c.Check(err, gc.ErrorMatches,
 		`internal error, my-testing-facade\(0\) claimed to return \*apiserver_test.testingType but returned \*apiserver_test.badType`)
 	// myErrFacade had the permissions change, so calling it returns an
 	// error, but that shouldn't trigger the type checking code.
 	caller, err = srvRoot.FindMethod("my-testing-facade", 2, "Exposed")
 	c.Assert(err, jc.ErrorIsNil)
-	res, err := caller.Call("", reflect.Value{})
+	res, err := caller.Call(rpcreflect.Background(), "", reflect.Value{})
 	c.Check(err, gc.ErrorMatches, `you shall not pass`)
 	c.Check(res.IsValid(), jc.IsFalse)
 }
@@ -207,7 +209,7 @@ func (ct *countingType) AltCount() stringVar {
 }
 
 func assertCallResult(c *gc.C, caller rpcreflect.MethodCaller, id string, expected string) {
-	v, err := caller.Call(id, reflect.Value{})
+	v, err := caller.Call(rpcreflect.Background(), id, reflect.Value{})
 	c.Assert(err, jc.ErrorIsNil)
 	c.Check(v.Interface(), gc.Equals, stringVar{expected})
 }
@@ -299,10 +301,10 @@ func (r *rootSuite) TestFindMethodCacheRaceSafe(c *gc.C) {
 	// This is designed to trigger the race detector
 	var wg sync.WaitGroup
 	wg.Add(4)
-	go func() { caller.Call("first", reflect.Value{}); wg.Done() }()
-	go func() { caller.Call("second", reflect.Value{}); wg.Done() }()
-	go func() { caller.Call("first", reflect.Value{}); wg.Done() }()
-	go func() { caller.Call("second", reflect.Value{}); wg.Done() }()
+	go func() { caller.Call(rpcreflect.Background(), "first", reflect.Value{}); wg.Done() }()
+	go func() { caller.Call(rpcreflect.Background(), "second", reflect.Value{}); wg.Done() }()
+	go func() { caller.Call(rpcreflect.Background(), "first", reflect.Value{}); wg.Done() }()
+	go func() { caller.Call(rpcreflect.Background(), "second", reflect.Value{}); wg.Done() }()
 	wg.Wait()
 	// Once we're done, we should have only instantiated 2 different
 	// objects. If we pass a different Id, we should be at 3 total count.
@@ -373,7 +375,7 @@ func (r *rootSuite) TestFindMethodHandlesInterfaceTypes(c *gc.C) {
 }
 
 func (r *rootSuite) TestDescribeFacades(c *gc.C) {
-	facades := apiserver.DescribeFacades()
+	facades := apiserver.DescribeFacades(nil)
 	c.Check(facades, gc.Not(gc.HasLen), 0)
 	// As a sanity check, we should see that we have a Client v0 available
 	asMap := make(map[string][]int, len(facades))
@@ -385,6 +387,49 @@ func (r *rootSuite) TestDescribeFacades(c *gc.C) {
 	c.Check(clientVersions[0], gc.Equals, 0)
 }
 
+type environFeatureRootSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&environFeatureRootSuite{})
+
+func (s *environFeatureRootSuite) TestFindMethodChecksEnvironFeature(c *gc.C) {
+	defer common.Facades.Discard("my-featured-facade", 1)
+	myFacade := func(
+		*state.State, *common.Resources, common.Authorizer,
+	) (
+		*testingType, error,
+	) {
+		return &testingType{}, nil
+	}
+	common.RegisterStandardFacadeForEnvironFeature("my-featured-facade", 1, myFacade, "magic")
+
+	root := apiserver.TestingApiRoot(s.State)
+	caller, err := root.FindMethod("my-featured-facade", 1, "Exposed")
+	c.Check(caller, gc.IsNil)
+	c.Check(err, gc.FitsTypeOf, (*rpcreflect.CallNotImplementedError)(nil))
+	c.Check(describesFacade(apiserver.DescribeFacades(nil), "my-featured-facade"), jc.IsFalse)
+
+	// The feature is checked on every lookup, so enabling it
+	// makes the facade available without a new connection.
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{"features": "magic"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	caller, err = root.FindMethod("my-featured-facade", 1, "Exposed")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = caller.Call(rpcreflect.Background(), "", reflect.Value{})
+	c.Check(err, gc.ErrorMatches, "Exposed was bogus")
+	c.Check(describesFacade(apiserver.DescribeFacades([]string{"magic"}), "my-featured-facade"), jc.IsTrue)
+}
+
+func describesFacade(facades []params.FacadeVersions, name string) bool {
+	for _, facade := range facades {
+		if facade.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 type stubStateEntity struct{ tag names.Tag }
 
 func (e *stubStateEntity) Tag() names.Tag { return e.tag }
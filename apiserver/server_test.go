@@ -236,7 +236,7 @@ func (s *serverSuite) TestMachineLoginStartsPinger(c *gc.C) {
 	// Login as the machine agent of the created machine.
 	st := s.OpenAPIAsMachine(c, machine.Tag(), password, "fake_nonce")
 
-	// Make sure the pinger has started.
+	// Make sure its heartbeat has been recorded.
 	s.assertAlive(c, machine, true)
 
 	// Now make sure it stops when connection is closed.
@@ -248,6 +248,11 @@ func (s *serverSuite) TestMachineLoginStartsPinger(c *gc.C) {
 	s.State.StartSync()
 
 	s.assertAlive(c, machine, false)
+
+	// The time it was last seen is still recorded.
+	lastSeen, err := machine.AgentLastSeen()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastSeen.IsZero(), jc.IsFalse)
 }
 
 func (s *serverSuite) TestUnitLoginStartsPinger(c *gc.C) {
@@ -266,7 +271,7 @@ func (s *serverSuite) TestUnitLoginStartsPinger(c *gc.C) {
 	// Login as the unit agent of the created unit.
 	st := s.OpenAPIAs(c, unit.Tag(), password)
 
-	// Make sure the pinger has started.
+	// Make sure its heartbeat has been recorded.
 	s.assertAlive(c, unit, true)
 
 	// Now make sure it stops when connection is closed.
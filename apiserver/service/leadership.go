@@ -0,0 +1,134 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/leadership"
+	"github.com/juju/juju/lease/raftlease"
+)
+
+const (
+	// DefaultPinDuration is how long a service's leadership is
+	// pinned for when no duration is requested.
+	DefaultPinDuration = time.Hour
+
+	// MaxPinDuration is the longest duration for which we will pin
+	// a service's leadership. Pins lapse automatically, so that a
+	// maintenance operation which fails to unpin a service's
+	// leadership does not hold it indefinitely.
+	MaxPinDuration = 24 * time.Hour
+)
+
+// leadershipManager is the part of a *leadership.Manager used by
+// the service facade.
+type leadershipManager interface {
+	leadership.LeadershipPinner
+	ServiceLeader(serviceId string) string
+}
+
+// leaderMgr is exposed as a variable so that the
+// implementation can be changed for testing purposes.
+var leaderMgr leadershipManager = leadership.NewLeadershipManager(raftlease.Manager())
+
+// PinLeadership pins the leadership of each of the given services to
+// its current leader, so that it does not change hands during
+// maintenance operations such as series upgrades and backups. The
+// pins are made on behalf of the authenticated entity, and lapse
+// after the requested duration unless they are unpinned sooner.
+func (api *API) PinLeadership(args params.PinLeadershipBulkParams) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Params)),
+	}
+	entity := api.authorizer.GetAuthTag().String()
+	for i, p := range args.Params {
+		serviceTag, err := names.ParseServiceTag(p.ServiceTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		duration := time.Duration(p.DurationSeconds * float64(time.Second))
+		if duration == 0 {
+			duration = DefaultPinDuration
+		}
+		if duration < 0 || duration > MaxPinDuration {
+			result.Results[i].Error = common.ServerError(errors.NotValidf("pin duration %v", duration))
+			continue
+		}
+		if _, err := api.state.Service(serviceTag.Id()); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		err = leaderMgr.PinLeadership(serviceTag.Id(), entity, duration)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// UnpinLeadership removes the authenticated entity's pins on the
+// leadership of the given services.
+func (api *API) UnpinLeadership(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	entity := api.authorizer.GetAuthTag().String()
+	for i, arg := range args.Entities {
+		serviceTag, err := names.ParseServiceTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		err = leaderMgr.UnpinLeadership(serviceTag.Id(), entity)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Leadership returns the leader of each of the given services, and
+// the pins on its leadership.
+func (api *API) Leadership(args params.Entities) (params.ServiceLeadershipResults, error) {
+	result := params.ServiceLeadershipResults{
+		Results: make([]params.ServiceLeadershipResult, len(args.Entities)),
+	}
+	for i, arg := range args.Entities {
+		serviceTag, err := names.ParseServiceTag(arg.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		if _, err := api.state.Service(serviceTag.Id()); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i] = serviceLeadership(serviceTag.Id())
+	}
+	return result, nil
+}
+
+func serviceLeadership(serviceId string) params.ServiceLeadershipResult {
+	var result params.ServiceLeadershipResult
+	if leader := leaderMgr.ServiceLeader(serviceId); leader != "" {
+		result.Leader = names.NewUnitTag(leader).String()
+	}
+	pins := leaderMgr.LeadershipPins(serviceId)
+	entities := make([]string, 0, len(pins))
+	for entity := range pins {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	for _, entity := range entities {
+		result.Pins = append(result.Pins, params.LeadershipPin{
+			Entity: entity,
+			Expiry: pins[entity],
+		})
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package service_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/leadership"
+	"github.com/juju/juju/lease/raftlease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
+	"github.com/juju/juju/worker"
+)
+
+func (s *serviceSuite) startLeaseService(c *gc.C) {
+	service, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) {
+		c.Check(worker.Stop(service), jc.ErrorIsNil)
+	})
+}
+
+func (s *serviceSuite) claimLeadership(c *gc.C, unitId string) {
+	err := leadership.NewLeadershipManager(raftlease.Manager()).ClaimLeadership(
+		s.service.Name(), unitId, time.Minute,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestPinLeadership(c *gc.C) {
+	s.startLeaseService(c)
+	s.claimLeadership(c, s.service.Name()+"/0")
+
+	results, err := s.serviceApi.PinLeadership(params.PinLeadershipBulkParams{
+		Params: []params.PinLeadershipParams{
+			{ServiceTag: s.service.Tag().String(), DurationSeconds: 600},
+			{ServiceTag: "service-missing"},
+			{ServiceTag: "unit-foo-0"},
+			{ServiceTag: s.service.Tag().String(), DurationSeconds: 1e9},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 4)
+	c.Check(results.Results[0].Error, gc.IsNil)
+	c.Check(results.Results[1].Error, gc.ErrorMatches, `service "missing" not found`)
+	c.Check(results.Results[2].Error, gc.ErrorMatches, `"unit-foo-0" is not a valid service tag`)
+	c.Check(results.Results[3].Error, gc.ErrorMatches, `pin duration .* not valid`)
+
+	// The leadership cannot now be claimed by another unit.
+	err = leadership.NewLeadershipManager(raftlease.Manager()).ClaimLeadership(
+		s.service.Name(), s.service.Name()+"/1", time.Minute,
+	)
+	c.Assert(err, gc.ErrorMatches, "leadership claim denied")
+
+	leadershipResults, err := s.serviceApi.Leadership(params.Entities{
+		Entities: []params.Entity{{Tag: s.service.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(leadershipResults.Results, gc.HasLen, 1)
+	result := leadershipResults.Results[0]
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.Leader, gc.Equals, "unit-"+s.service.Name()+"-0")
+	c.Assert(result.Pins, gc.HasLen, 1)
+	c.Assert(result.Pins[0].Entity, gc.Equals, s.authorizer.Tag.String())
+	c.Assert(result.Pins[0].Expiry.After(time.Now().Add(9*time.Minute)), jc.IsTrue)
+}
+
+func (s *serviceSuite) TestPinLeadershipNoLeader(c *gc.C) {
+	s.startLeaseService(c)
+	results, err := s.serviceApi.PinLeadership(params.PinLeadershipBulkParams{
+		Params: []params.PinLeadershipParams{{ServiceTag: s.service.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `leader of service ".*" not found`)
+	c.Assert(results.Results[0].Error, jc.Satisfies, params.IsCodeNotFound)
+}
+
+func (s *serviceSuite) TestUnpinLeadership(c *gc.C) {
+	s.startLeaseService(c)
+	s.claimLeadership(c, s.service.Name()+"/0")
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: s.service.Tag().String()}},
+	}
+	_, err := s.serviceApi.PinLeadership(params.PinLeadershipBulkParams{
+		Params: []params.PinLeadershipParams{{ServiceTag: s.service.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.serviceApi.UnpinLeadership(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{{}},
+	})
+
+	leadershipResults, err := s.serviceApi.Leadership(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(leadershipResults.Results, gc.HasLen, 1)
+	c.Assert(leadershipResults.Results[0].Pins, gc.HasLen, 0)
+}
+
+func (s *serviceSuite) TestLeadershipErrors(c *gc.C) {
+	results, err := s.serviceApi.Leadership(params.Entities{
+		Entities: []params.Entity{{Tag: "service-missing"}, {Tag: "machine-0"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, `service "missing" not found`)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, `"machine-0" is not a valid service tag`)
+}
@@ -5,6 +5,7 @@
 package service
 
 import (
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
 	"github.com/juju/juju/apiserver/common"
@@ -21,6 +22,10 @@ func init() {
 // Service defines the methods on the service API end point.
 type Service interface {
 	SetMetricCredentials(args params.ServiceMetricCredentials) (params.ErrorResults, error)
+	SetConfigs(args params.ServiceConfigs) (params.ServiceConfigResults, error)
+	PinLeadership(args params.PinLeadershipBulkParams) (params.ErrorResults, error)
+	UnpinLeadership(args params.Entities) (params.ErrorResults, error)
+	Leadership(args params.Entities) (params.ServiceLeadershipResults, error)
 }
 
 // API implements the service interface and is the concrete
@@ -28,6 +33,7 @@ type Service interface {
 type API struct {
 	state      *state.State
 	authorizer common.Authorizer
+	check      *common.BlockChecker
 }
 
 // NewAPI returns a new service API facade.
@@ -43,6 +49,7 @@ func NewAPI(
 	return &API{
 		state:      st,
 		authorizer: authorizer,
+		check:      common.NewBlockChecker(st),
 	}, nil
 }
 
@@ -67,3 +74,39 @@ func (api *API) SetMetricCredentials(args params.ServiceMetricCredentials) (para
 	}
 	return result, nil
 }
+
+// SetConfigs sets config settings on services. Settings that break the
+// validation rules declared by a service's charm are reported in the
+// service's result, and none of its settings are changed.
+func (api *API) SetConfigs(args params.ServiceConfigs) (params.ServiceConfigResults, error) {
+	if err := api.check.ChangeAllowed(); err != nil {
+		return params.ServiceConfigResults{}, errors.Trace(err)
+	}
+	result := params.ServiceConfigResults{
+		Results: make([]params.ServiceConfigResult, len(args.Configs)),
+	}
+	for i, arg := range args.Configs {
+		err := api.setConfig(arg)
+		if err, ok := errors.Cause(err).(*state.ConfigValidationError); ok {
+			result.Results[i].InvalidSettings = err.Errors
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (api *API) setConfig(arg params.ServiceConfig) error {
+	service, err := api.state.Service(arg.ServiceName)
+	if err != nil {
+		return err
+	}
+	ch, _, err := service.Charm()
+	if err != nil {
+		return err
+	}
+	changes, err := ch.Config().ParseSettingsStrings(arg.Options)
+	if err != nil {
+		return err
+	}
+	return service.UpdateConfigSettings(changes)
+}
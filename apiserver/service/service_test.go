@@ -6,7 +6,9 @@ package service_test
 import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
 
+	commontesting "github.com/juju/juju/apiserver/common/testing"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/service"
 	apiservertesting "github.com/juju/juju/apiserver/testing"
@@ -17,6 +19,7 @@ import (
 
 type serviceSuite struct {
 	jujutesting.JujuConnSuite
+	commontesting.BlockHelper
 
 	serviceApi *service.API
 	service    *state.Service
@@ -29,6 +32,8 @@ var _ service.Service = (*service.API)(nil)
 
 func (s *serviceSuite) SetUpTest(c *gc.C) {
 	s.JujuConnSuite.SetUpTest(c)
+	s.BlockHelper = commontesting.NewBlockHelper(s.APIState)
+	s.AddCleanup(func(*gc.C) { s.BlockHelper.Close() })
 	s.service = s.Factory.MakeService(c, nil)
 
 	s.authorizer = apiservertesting.FakeAuthorizer{
@@ -109,3 +114,52 @@ func (s *serviceSuite) TestSetMetricCredentials(c *gc.C) {
 		}
 	}
 }
+
+func (s *serviceSuite) TestSetConfigs(c *gc.C) {
+	svc := s.AddTestingService(c, "config-rules", s.AddTestingCharm(c, "config-rules"))
+	results, err := s.serviceApi.SetConfigs(params.ServiceConfigs{
+		Configs: []params.ServiceConfig{{
+			ServiceName: "config-rules",
+			Options:     map[string]string{"flavour": "chocolate", "port": "443"},
+		}, {
+			ServiceName: "config-rules",
+			Options:     map[string]string{"flavour": "strawberry", "port": "0", "title": "ignored"},
+		}, {
+			ServiceName: "config-rules",
+			Options:     map[string]string{"port": "many"},
+		}, {
+			ServiceName: "not-a-service",
+			Options:     map[string]string{"port": "443"},
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 4)
+	c.Assert(results.Results[0], gc.DeepEquals, params.ServiceConfigResult{})
+	c.Assert(results.Results[1].InvalidSettings, gc.DeepEquals, map[string]string{
+		"flavour": "must be one of [vanilla chocolate]",
+		"port":    "must be at least 1",
+	})
+	c.Assert(results.Results[1].Error, jc.Satisfies, params.IsCodeConfigInvalid)
+	c.Assert(results.Results[2].InvalidSettings, gc.IsNil)
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `option "port" expected int, got "many"`)
+	c.Assert(results.Results[3].Error, jc.Satisfies, params.IsCodeNotFound)
+
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.DeepEquals, charm.Settings{
+		"flavour": "chocolate",
+		"port":    int64(443),
+	})
+}
+
+func (s *serviceSuite) TestBlockSetConfigs(c *gc.C) {
+	s.AddTestingService(c, "config-rules", s.AddTestingCharm(c, "config-rules"))
+	s.BlockAllChanges(c, "TestBlockSetConfigs")
+	_, err := s.serviceApi.SetConfigs(params.ServiceConfigs{
+		Configs: []params.ServiceConfig{{
+			ServiceName: "config-rules",
+			Options:     map[string]string{"flavour": "chocolate"},
+		}},
+	})
+	s.AssertBlocked(c, err, "TestBlockSetConfigs")
+}
@@ -0,0 +1,126 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.google.com/p/go.net/websocket"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+)
+
+// sshProxyDialTimeout bounds how long the ssh proxy waits to connect
+// to a machine.
+const sshProxyDialTimeout = 30 * time.Second
+
+// sshProxyHandler relays connections from clients to the ssh servers
+// of the environment's machines, so that clients such as "juju ssh"
+// need only be able to reach the API server.
+type sshProxyHandler struct {
+	httpHandler
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// The connection is upgraded to a websocket. The request names the
+// address to connect to in its "host" and "port" query parameters; the
+// host must be an address of one of the environment's machines, and the
+// port defaults to 22. Once the user has been authenticated and the
+// connection to the machine made, the first line sent back is a JSON
+// encoded params.ErrorResult reporting whether the connection was
+// accepted. After that, data is relayed unchanged in both directions
+// until either side closes its connection.
+func (h *sshProxyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := websocket.Server{
+		Handler: func(socket *websocket.Conn) {
+			defer socket.Close()
+			stateWrapper, err := h.validateEnvironUUID(req)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			defer stateWrapper.cleanup()
+			if err := stateWrapper.authenticate(req); err != nil {
+				h.sendError(socket, fmt.Errorf("auth failed: %v", err))
+				return
+			}
+			addr, err := h.targetAddress(stateWrapper.state, req)
+			if err != nil {
+				h.sendError(socket, err)
+				return
+			}
+			conn, err := net.DialTimeout("tcp", addr, sshProxyDialTimeout)
+			if err != nil {
+				h.sendError(socket, errors.Annotatef(err, "cannot connect to %s", addr))
+				return
+			}
+			defer conn.Close()
+			if err := h.sendError(socket, nil); err != nil {
+				logger.Errorf("could not send good ssh proxy start")
+				return
+			}
+			socket.PayloadType = websocket.BinaryFrame
+			relay(socket, conn)
+		},
+	}
+	server.ServeHTTP(w, req)
+}
+
+// targetAddress returns the address the request asks to be connected
+// to, after checking that it is an address of one of the environment's
+// machines.
+func (h *sshProxyHandler) targetAddress(st *state.State, req *http.Request) (string, error) {
+	host := req.URL.Query().Get("host")
+	if host == "" {
+		return "", errors.New("no host specified")
+	}
+	port := 22
+	if value := req.URL.Query().Get("port"); value != "" {
+		var err error
+		port, err = strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return "", errors.Errorf("invalid port %q", value)
+		}
+	}
+	machines, err := st.AllMachines()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	for _, m := range machines {
+		for _, addr := range m.Addresses() {
+			if addr.Value == host {
+				return net.JoinHostPort(host, strconv.Itoa(port)), nil
+			}
+		}
+	}
+	return "", errors.Errorf("%q is not an address of any machine in the environment", host)
+}
+
+// relay copies data in both directions between the two connections
+// until either of them is closed.
+func relay(a, b io.ReadWriter) {
+	done := make(chan struct{}, 2)
+	copyData := func(dst io.Writer, src io.Reader) {
+		if _, err := io.Copy(dst, src); err != nil {
+			logger.Debugf("ssh proxy relay ended: %v", err)
+		}
+		done <- struct{}{}
+	}
+	go copyData(a, b)
+	go copyData(b, a)
+	// The deferred closes in the caller stop the other direction.
+	<-done
+}
+
+// sendError sends a JSON-encoded error response.
+func (h *sshProxyHandler) sendError(w io.Writer, err error) error {
+	return sendWebsocketError(w, err)
+}
@@ -0,0 +1,168 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"code.google.com/p/go.net/websocket"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type sshProxySuite struct {
+	authHttpSuite
+	listener net.Listener
+}
+
+var _ = gc.Suite(&sshProxySuite{})
+
+func (s *sshProxySuite) SetUpTest(c *gc.C) {
+	s.authHttpSuite.SetUpTest(c)
+
+	// Stand in for a machine's ssh server with an echo server.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	s.listener = listener
+	s.AddCleanup(func(*gc.C) { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	s.Factory.MakeMachine(c, &factory.MachineParams{
+		Addresses: []network.Address{network.NewAddress("127.0.0.1", network.ScopeCloudLocal)},
+	})
+}
+
+func (s *sshProxySuite) query(host string) url.Values {
+	_, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	return url.Values{"host": {host}, "port": {port}}
+}
+
+func (s *sshProxySuite) TestNoAuth(c *gc.C) {
+	reader := s.openWebsocket(c, nil, s.query("127.0.0.1"))
+	s.assertErrorResponse(c, reader, "auth failed: invalid request format")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestRejectsBadPassword(c *gc.C) {
+	header := utils.BasicAuthHeader(s.userTag.String(), "wrong")
+	reader := s.openWebsocket(c, header, s.query("127.0.0.1"))
+	s.assertErrorResponse(c, reader, "auth failed: invalid entity name or password")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestNoHost(c *gc.C) {
+	reader := s.openWebsocket(c, s.userHeader(), nil)
+	s.assertErrorResponse(c, reader, "no host specified")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestInvalidPort(c *gc.C) {
+	query := url.Values{"host": {"127.0.0.1"}, "port": {"ssh"}}
+	reader := s.openWebsocket(c, s.userHeader(), query)
+	s.assertErrorResponse(c, reader, `invalid port "ssh"`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestRejectsOtherHosts(c *gc.C) {
+	reader := s.openWebsocket(c, s.userHeader(), s.query("10.9.8.7"))
+	s.assertErrorResponse(c, reader, `"10.9.8.7" is not an address of any machine in the environment`)
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestConnectionRefused(c *gc.C) {
+	query := s.query("127.0.0.1")
+	s.listener.Close()
+	reader := s.openWebsocket(c, s.userHeader(), query)
+	s.assertErrorResponse(c, reader, "cannot connect to 127.0.0.1:[0-9]+: .*")
+	s.assertWebsocketClosed(c, reader)
+}
+
+func (s *sshProxySuite) TestRelay(c *gc.C) {
+	conn := s.dialWebsocket(c, s.userHeader(), s.query("127.0.0.1"))
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	s.assertErrorResponse(c, reader, "")
+
+	for _, message := range []string{"SSH-2.0-OpenSSH\r\n", "\x00\x01\xff binary data"} {
+		_, err := conn.Write([]byte(message))
+		c.Assert(err, jc.ErrorIsNil)
+		buf := make([]byte, len(message))
+		_, err = io.ReadFull(reader, buf)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(buf), gc.Equals, message)
+	}
+}
+
+func (s *sshProxySuite) userHeader() http.Header {
+	return utils.BasicAuthHeader(s.userTag.String(), s.password)
+}
+
+func (s *sshProxySuite) openWebsocket(c *gc.C, header http.Header, query url.Values) *bufio.Reader {
+	conn := s.dialWebsocket(c, header, query)
+	s.AddCleanup(func(_ *gc.C) { conn.Close() })
+	return bufio.NewReader(conn)
+}
+
+func (s *sshProxySuite) dialWebsocket(c *gc.C, header http.Header, query url.Values) *websocket.Conn {
+	server := s.baseURL(c)
+	server.Scheme = "wss"
+	server.Path = "/environment/" + s.envUUID + "/sshproxy"
+	server.RawQuery = query.Encode()
+	c.Logf("dialing %v", server)
+	config, err := websocket.NewConfig(server.String(), "http://localhost/")
+	c.Assert(err, jc.ErrorIsNil)
+	config.Header = header
+	caCerts := x509.NewCertPool()
+	c.Assert(caCerts.AppendCertsFromPEM([]byte(testing.CACert)), jc.IsTrue)
+	config.TlsConfig = &tls.Config{RootCAs: caCerts, ServerName: "anything"}
+	conn, err := websocket.DialConfig(config)
+	c.Assert(err, jc.ErrorIsNil)
+	conn.PayloadType = websocket.BinaryFrame
+	return conn
+}
+
+func (s *sshProxySuite) assertErrorResponse(c *gc.C, reader *bufio.Reader, expected string) {
+	line, err := reader.ReadSlice('\n')
+	c.Assert(err, jc.ErrorIsNil)
+	var errResult params.ErrorResult
+	err = json.Unmarshal(line, &errResult)
+	c.Assert(err, jc.ErrorIsNil)
+	if expected == "" {
+		c.Assert(errResult.Error, gc.IsNil)
+	} else {
+		c.Assert(errResult.Error, gc.NotNil)
+		c.Assert(errResult.Error.Message, gc.Matches, expected)
+	}
+}
+
+func (s *sshProxySuite) assertWebsocketClosed(c *gc.C, reader *bufio.Reader) {
+	_, err := reader.ReadByte()
+	c.Assert(err, gc.Equals, io.EOF)
+}
@@ -0,0 +1,178 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package stability implements the API used to wait for an
+// environment to settle, so that scripts need not poll status to find
+// out when a deployment has finished.
+package stability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Stability", 1, NewAPI)
+}
+
+// pollInterval holds how often the environment is checked while
+// waiting for it to become stable.
+var pollInterval = 5 * time.Second
+
+// API implements the Stability facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new Stability API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// WaitForStability waits until the environment is stable, or until
+// the given timeout has elapsed, and returns whether it is stable and,
+// if not, why. The environment is stable when every machine has been
+// provisioned, every machine and unit agent is connected and idle, and
+// no unit has hooks waiting to run or a failed hook to resolve.
+//
+// The wait is cut short, with an unstable result, if the call's
+// deadline passes first; clients wanting to wait longer should call
+// again with the time remaining.
+func (api *API) WaitForStability(ctx rpcreflect.Context, args params.WaitForStabilityArgs) (params.StabilityResult, error) {
+	if args.Timeout < 0 {
+		return params.StabilityResult{}, errors.NotValidf("timeout %v", args.Timeout)
+	}
+	timeout := time.After(args.Timeout)
+	for {
+		reasons, err := api.unstableReasons()
+		if err != nil {
+			return params.StabilityResult{}, errors.Trace(err)
+		}
+		if len(reasons) == 0 {
+			return params.StabilityResult{Stable: true}, nil
+		}
+		unstable := params.StabilityResult{Reasons: reasons}
+		select {
+		case <-time.After(pollInterval):
+		case <-timeout:
+			return unstable, nil
+		case <-ctx.Done():
+			if err := ctx.Err(); err != rpcreflect.ErrDeadlineExceeded {
+				return params.StabilityResult{}, err
+			}
+			return unstable, nil
+		}
+	}
+}
+
+// unstableReasons returns why the environment is not stable, or
+// nothing if it is.
+func (api *API) unstableReasons() ([]string, error) {
+	var reasons []string
+	machines, err := api.st.AllMachines()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, m := range machines {
+		machineReasons, err := machineUnstableReasons(m)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot check machine %s", m.Id())
+		}
+		reasons = append(reasons, machineReasons...)
+	}
+	services, err := api.st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, svc := range services {
+		units, err := svc.AllUnits()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, u := range units {
+			unitReasons, err := unitUnstableReasons(u)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot check unit %q", u.Name())
+			}
+			reasons = append(reasons, unitReasons...)
+		}
+	}
+	return reasons, nil
+}
+
+func machineUnstableReasons(m *state.Machine) ([]string, error) {
+	switch m.Life() {
+	case state.Dead:
+		return nil, nil
+	case state.Dying:
+		return []string{fmt.Sprintf("machine %s is dying", m.Id())}, nil
+	}
+	if _, err := m.InstanceId(); errors.IsNotProvisioned(err) {
+		return []string{fmt.Sprintf("machine %s is not provisioned", m.Id())}, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	status, _, _, err := m.Status()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if status != state.StatusStarted {
+		return []string{fmt.Sprintf("machine %s agent is %s", m.Id(), status)}, nil
+	}
+	alive, err := m.AgentPresence()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !alive {
+		return []string{fmt.Sprintf("machine %s agent is not connected", m.Id())}, nil
+	}
+	return nil, nil
+}
+
+func unitUnstableReasons(u *state.Unit) ([]string, error) {
+	switch u.Life() {
+	case state.Dead:
+		return nil, nil
+	case state.Dying:
+		return []string{fmt.Sprintf("unit %s is dying", u.Name())}, nil
+	}
+	queue, err := u.HookQueue()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if queue.Pending != nil {
+		return []string{fmt.Sprintf("unit %s has failed %q hook", u.Name(), queue.Pending.Id())}, nil
+	}
+	status, _, _, err := u.AgentStatus()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if status != state.StatusActive {
+		return []string{fmt.Sprintf("unit %s agent is %s", u.Name(), status)}, nil
+	}
+	alive, err := u.AgentPresence()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !alive {
+		return []string{fmt.Sprintf("unit %s agent is not connected", u.Name())}, nil
+	}
+	if n := len(queue.Queued); n > 0 {
+		return []string{fmt.Sprintf("unit %s has %d hook(s) queued", u.Name(), n)}, nil
+	}
+	return nil, nil
+}
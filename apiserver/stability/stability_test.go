@@ -0,0 +1,207 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package stability_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/stability"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type stabilitySuite struct {
+	jujutesting.JujuConnSuite
+	api       *stability.API
+	service   *state.Service
+	connected []names.Tag
+}
+
+var _ = gc.Suite(&stabilitySuite{})
+
+func (s *stabilitySuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(stability.PollInterval, time.Millisecond)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = stability.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+	s.service = s.Factory.MakeService(c, nil)
+	s.connected = nil
+}
+
+func (s *stabilitySuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := stability.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+// setConnected records the agents with the given tags, along with
+// those recorded earlier in the test, as connected to an API server.
+func (s *stabilitySuite) setConnected(c *gc.C, tags ...names.Tag) {
+	s.connected = append(s.connected, tags...)
+	heartbeats := make([]state.AgentHeartbeat, len(s.connected))
+	for i, tag := range s.connected {
+		heartbeats[i] = state.AgentHeartbeat{
+			EnvUUID:   s.State.EnvironUUID(),
+			Agent:     tag,
+			LastSeen:  time.Now(),
+			Connected: true,
+		}
+	}
+	err := s.State.SetAgentHeartbeats("0:17070", heartbeats)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// makeStableUnit returns a unit, on its own machine, whose agent and
+// machine agent are connected and idle.
+func (s *stabilitySuite) makeStableUnit(c *gc.C) *state.Unit {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.Factory.MakeUnit(c, &factory.UnitParams{
+		Service: s.service,
+		Machine: machine,
+	})
+	err = unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.setConnected(c, machine.Tag(), unit.Tag())
+	return unit
+}
+
+func (s *stabilitySuite) TestStable(c *gc.C) {
+	s.makeStableUnit(c)
+	result, err := s.api.WaitForStability(rpcreflect.Background(), params.WaitForStabilityArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{Stable: true})
+}
+
+func (s *stabilitySuite) TestUnstableReasons(c *gc.C) {
+	unit := s.makeStableUnit(c)
+	err := unit.SetHookQueue(nil, []state.QueuedHook{
+		{Kind: "config-changed"},
+		{Kind: "relation-changed", RelationId: 1, RemoteUnit: "mysql/0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	failed := s.makeStableUnit(c)
+	err = failed.SetHookQueue(&state.QueuedHook{Kind: "install"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	installing := s.makeStableUnit(c)
+	err = installing.SetAgentStatus(state.StatusInstalling, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	unprovisioned, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	pending := s.Factory.MakeMachine(c, nil)
+	disconnected := s.Factory.MakeMachine(c, nil)
+	err = disconnected.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.WaitForStability(rpcreflect.Background(), params.WaitForStabilityArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Stable, jc.IsFalse)
+	c.Assert(result.Reasons, jc.SameContents, []string{
+		"unit " + unit.Name() + " has 2 hook(s) queued",
+		"unit " + failed.Name() + ` has failed "install" hook`,
+		"unit " + installing.Name() + " agent is installing",
+		"machine " + unprovisioned.Id() + " is not provisioned",
+		"machine " + pending.Id() + " agent is pending",
+		"machine " + disconnected.Id() + " agent is not connected",
+	})
+}
+
+func (s *stabilitySuite) TestWaitsUntilStable(c *gc.C) {
+	unit := s.makeStableUnit(c)
+	err := unit.SetAgentStatus(state.StatusInstalling, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	go func() {
+		time.Sleep(coretesting.ShortWait)
+		err := unit.SetAgentStatus(state.StatusActive, "", nil)
+		c.Check(err, jc.ErrorIsNil)
+	}()
+	result, err := s.api.WaitForStability(rpcreflect.Background(), params.WaitForStabilityArgs{
+		Timeout: coretesting.LongWait,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{Stable: true})
+}
+
+func (s *stabilitySuite) TestTimeout(c *gc.C) {
+	unit := s.makeStableUnit(c)
+	err := unit.SetAgentStatus(state.StatusInstalling, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.api.WaitForStability(rpcreflect.Background(), params.WaitForStabilityArgs{
+		Timeout: coretesting.ShortWait,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{
+		Reasons: []string{"unit " + unit.Name() + " agent is installing"},
+	})
+}
+
+func (s *stabilitySuite) TestNegativeTimeout(c *gc.C) {
+	_, err := s.api.WaitForStability(rpcreflect.Background(), params.WaitForStabilityArgs{
+		Timeout: -time.Second,
+	})
+	c.Assert(err, gc.ErrorMatches, "timeout -1s not valid")
+}
+
+func (s *stabilitySuite) TestDeadlineExceeded(c *gc.C) {
+	s.PatchValue(stability.PollInterval, coretesting.LongWait)
+	unit := s.makeStableUnit(c)
+	err := unit.SetAgentStatus(state.StatusInstalling, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := newClosedContext(rpcreflect.ErrDeadlineExceeded)
+	result, err := s.api.WaitForStability(ctx, params.WaitForStabilityArgs{
+		Timeout: coretesting.LongWait,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StabilityResult{
+		Reasons: []string{"unit " + unit.Name() + " agent is installing"},
+	})
+}
+
+func (s *stabilitySuite) TestAbandoned(c *gc.C) {
+	s.PatchValue(stability.PollInterval, coretesting.LongWait)
+	s.Factory.MakeMachine(c, nil)
+	ctx := newClosedContext(rpcreflect.ErrCallAbandoned)
+	_, err := s.api.WaitForStability(ctx, params.WaitForStabilityArgs{
+		Timeout: coretesting.LongWait,
+	})
+	c.Assert(err, gc.Equals, rpcreflect.ErrCallAbandoned)
+}
+
+// closedContext is an rpcreflect.Context for a call that has
+// already been abandoned.
+type closedContext struct {
+	rpcreflect.Context
+	done chan struct{}
+	err  error
+}
+
+func newClosedContext(err error) closedContext {
+	done := make(chan struct{})
+	close(done)
+	return closedContext{done: done, err: err}
+}
+
+func (ctx closedContext) Done() <-chan struct{} {
+	return ctx.done
+}
+
+func (ctx closedContext) Err() error {
+	return ctx.err
+}
@@ -0,0 +1,146 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+// statusCacheTTL bounds the age of cached status results. The
+// all-watcher that invalidates them does not report agent presence,
+// so without it an agent going down could be hidden indefinitely.
+var statusCacheTTL = 30 * time.Second
+
+// statusCache holds status results for the environments served by an
+// API server. The results for an environment are discarded whenever
+// that environment's all-watcher reports a change.
+type statusCache struct {
+	st   *state.State
+	stop <-chan struct{}
+	wg   *sync.WaitGroup
+
+	mu   sync.Mutex
+	envs map[string]map[string]cachedStatus
+}
+
+type cachedStatus struct {
+	result  interface{}
+	expires time.Time
+}
+
+var _ common.StatusCache = (*statusCache)(nil)
+
+// newStatusCache returns a cache for the environments of the given
+// server state. The goroutines watching environments for changes are
+// tracked by wg and stop when stop is closed.
+func newStatusCache(st *state.State, stop <-chan struct{}, wg *sync.WaitGroup) *statusCache {
+	return &statusCache{
+		st:   st,
+		stop: stop,
+		wg:   wg,
+		envs: make(map[string]map[string]cachedStatus),
+	}
+}
+
+// Get implements common.StatusCache.
+func (c *statusCache) Get(envUUID, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.envs[envUUID][key]
+	if !ok || !time.Now().Before(cached.expires) {
+		return nil, false
+	}
+	return cached.result, true
+}
+
+// Put implements common.StatusCache. The first result cached for an
+// environment starts a watcher on it; since the watcher's first event
+// reports the whole environment, that result is always discarded, so
+// that no change made while it was computed can be missed.
+func (c *statusCache) Put(envUUID, key string, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stop:
+		return
+	default:
+	}
+	results, ok := c.envs[envUUID]
+	if !ok {
+		if err := c.watch(envUUID); err != nil {
+			logger.Warningf("not caching status for environment %s: %v", envUUID, err)
+			return
+		}
+		results = make(map[string]cachedStatus)
+		c.envs[envUUID] = results
+	}
+	results[key] = cachedStatus{
+		result:  result,
+		expires: time.Now().Add(statusCacheTTL),
+	}
+}
+
+// Stop implements common.Resource. The cache outlives the connections
+// that refer to it, so there is nothing to do.
+func (c *statusCache) Stop() error {
+	return nil
+}
+
+// watch starts a goroutine discarding the environment's results
+// whenever it changes. It is called with c.mu held.
+func (c *statusCache) watch(envUUID string) error {
+	st := c.st
+	if envUUID != st.EnvironUUID() {
+		var err error
+		st, err = st.ForEnviron(names.NewEnvironTag(envUUID))
+		if err != nil {
+			return err
+		}
+	}
+	w := st.Watch()
+	done := make(chan struct{})
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		select {
+		case <-c.stop:
+		case <-done:
+		}
+		w.Stop()
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer close(done)
+		if st != c.st {
+			defer st.Close()
+		}
+		for {
+			_, err := w.Next()
+			c.mu.Lock()
+			if err != nil {
+				delete(c.envs, envUUID)
+			} else if results, ok := c.envs[envUUID]; ok {
+				for key := range results {
+					delete(results, key)
+				}
+			}
+			c.mu.Unlock()
+			if err != nil {
+				select {
+				case <-c.stop:
+				default:
+					logger.Errorf("status cache watcher for environment %s failed: %v", envUUID, err)
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
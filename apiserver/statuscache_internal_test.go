@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type statusCacheSuite struct {
+	testing.StateSuite
+	stop  chan struct{}
+	wg    sync.WaitGroup
+	cache *statusCache
+}
+
+var _ = gc.Suite(&statusCacheSuite{})
+
+func (s *statusCacheSuite) SetUpTest(c *gc.C) {
+	s.StateSuite.SetUpTest(c)
+	s.stop = make(chan struct{})
+	s.cache = newStatusCache(s.State, s.stop, &s.wg)
+	s.AddCleanup(func(*gc.C) {
+		close(s.stop)
+		s.wg.Wait()
+	})
+}
+
+// waitForMiss waits until the cache no longer holds a result under key.
+func (s *statusCacheSuite) waitForMiss(c *gc.C, key string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		if _, ok := s.cache.Get(s.State.EnvironUUID(), key); !ok {
+			return
+		}
+	}
+	c.Fatalf("cached status %q was never discarded", key)
+}
+
+func (s *statusCacheSuite) TestGetMissing(c *gc.C) {
+	_, ok := s.cache.Get(s.State.EnvironUUID(), "key")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *statusCacheSuite) TestDiscardedOnChange(c *gc.C) {
+	envUUID := s.State.EnvironUUID()
+	s.Factory.MakeMachine(c, nil)
+
+	// The first result is discarded by the watcher's initial event.
+	s.cache.Put(envUUID, "key", "first")
+	s.waitForMiss(c, "key")
+
+	s.cache.Put(envUUID, "key", "second")
+	result, ok := s.cache.Get(envUUID, "key")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(result, gc.Equals, "second")
+	_, ok = s.cache.Get(envUUID, "other")
+	c.Assert(ok, jc.IsFalse)
+
+	s.Factory.MakeMachine(c, nil)
+	s.waitForMiss(c, "key")
+}
+
+func (s *statusCacheSuite) TestExpires(c *gc.C) {
+	s.PatchValue(&statusCacheTTL, time.Duration(0))
+	envUUID := s.State.EnvironUUID()
+	s.cache.Put(envUUID, "key", "result")
+	_, ok := s.cache.Get(envUUID, "key")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *statusCacheSuite) TestPutAfterStop(c *gc.C) {
+	cache := newStatusCache(s.State, closedChan(), &s.wg)
+	cache.Put(s.State.EnvironUUID(), "key", "result")
+	_, ok := cache.Get(s.State.EnvironUUID(), "key")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func closedChan() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
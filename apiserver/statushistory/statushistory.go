@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package statushistory implements the API used to query the status
+// history of units and machines.
+package statushistory
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("StatusHistory", 1, NewAPI)
+}
+
+// API implements the StatusHistory facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new StatusHistory API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+// StatusHistory returns the status history of each of the given
+// units or machines, most recent first.
+func (api *API) StatusHistory(args params.StatusHistoryArgs) (params.StatusHistoryResults, error) {
+	results := params.StatusHistoryResults{
+		Results: make([]params.StatusHistoryResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		history, err := api.statusHistory(arg)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].History = history
+	}
+	return results, nil
+}
+
+func (api *API) statusHistory(arg params.StatusHistoryArg) ([]params.HistoricalStatus, error) {
+	filter := state.StatusHistoryFilter{
+		Size:    arg.Size,
+		Changes: arg.Changes,
+	}
+	if arg.From != nil {
+		filter.From = *arg.From
+	}
+	if arg.To != nil {
+		filter.To = *arg.To
+	}
+	tag, err := names.ParseTag(arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	var history []state.StatusInfo
+	switch tag := tag.(type) {
+	case names.UnitTag:
+		unit, err := api.st.Unit(tag.Id())
+		if err != nil {
+			return nil, err
+		}
+		switch arg.Kind {
+		case params.KindWorkload, "":
+			history, err = unit.StatusHistory(filter)
+		case params.KindAgent:
+			history, err = unit.AgentStatusHistory(filter)
+		default:
+			return nil, errors.NotValidf("status history kind %q", arg.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case names.MachineTag:
+		machine, err := api.st.Machine(tag.Id())
+		if err != nil {
+			return nil, err
+		}
+		switch arg.Kind {
+		case params.KindProvisioning:
+			history, err = machine.ProvisioningStatusHistory(filter)
+		default:
+			history, err = machine.StatusHistory(filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.NotSupportedf("status history for %q", arg.Tag)
+	}
+	result := make([]params.HistoricalStatus, len(history))
+	for i, entry := range history {
+		result[i] = params.HistoricalStatus{
+			Status: params.Status(entry.Status),
+			Info:   entry.Info,
+			Data:   entry.Data,
+			Since:  entry.Since,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package statushistory_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/statushistory"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type statusHistorySuite struct {
+	jujutesting.JujuConnSuite
+	api *statushistory.API
+}
+
+var _ = gc.Suite(&statusHistorySuite{})
+
+func (s *statusHistorySuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = statushistory.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *statusHistorySuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := statushistory.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func historyStatuses(history []params.HistoricalStatus) []params.Status {
+	result := make([]params.Status, len(history))
+	for i, entry := range history {
+		result[i] = entry.Status
+	}
+	return result
+}
+
+func (s *statusHistorySuite) TestStatusHistory(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	for _, status := range []state.Status{state.StatusBusy, state.StatusBusy, state.StatusRunning} {
+		err := unit.SetStatus(status, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	machine := s.Factory.MakeMachine(c, nil)
+	err = machine.SetStatus(state.StatusError, "oops", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	future := time.Now().Add(time.Hour)
+	results, err := s.api.StatusHistory(params.StatusHistoryArgs{
+		Args: []params.StatusHistoryArg{
+			{Tag: unit.Tag().String()},
+			{Tag: unit.Tag().String(), Changes: true},
+			{Tag: unit.Tag().String(), Kind: params.KindAgent},
+			{Tag: unit.Tag().String(), From: &future},
+			{Tag: machine.Tag().String()},
+			{Tag: unit.Tag().String(), Kind: "bogus"},
+			{Tag: "unit-foo-42"},
+			{Tag: "service-foo"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 8)
+	for _, result := range results.Results[:5] {
+		c.Assert(result.Error, gc.IsNil)
+	}
+	c.Assert(historyStatuses(results.Results[0].History), jc.DeepEquals, []params.Status{
+		params.StatusRunning, params.StatusBusy, params.StatusBusy,
+	})
+	c.Assert(historyStatuses(results.Results[1].History), jc.DeepEquals, []params.Status{
+		params.StatusRunning, params.StatusBusy,
+	})
+	c.Assert(historyStatuses(results.Results[2].History), jc.DeepEquals, []params.Status{
+		params.StatusActive,
+	})
+	c.Assert(results.Results[3].History, gc.HasLen, 0)
+	c.Assert(results.Results[4].History, gc.HasLen, 1)
+	c.Assert(results.Results[4].History[0].Status, gc.Equals, params.StatusError)
+	c.Assert(results.Results[4].History[0].Info, gc.Equals, "oops")
+	c.Assert(results.Results[5].Error, gc.ErrorMatches, `status history kind "bogus" not valid`)
+	c.Assert(results.Results[6].Error, jc.Satisfies, params.IsCodeNotFound)
+	c.Assert(results.Results[7].Error, gc.ErrorMatches, `status history for "service-foo" not supported`)
+}
+
+func (s *statusHistorySuite) TestMachineProvisioningStatusHistory(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.SetProvisioningStatus(state.StatusInstanceRequested, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioningStatus(state.StatusInstanceRunning, "i-foo", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.api.StatusHistory(params.StatusHistoryArgs{
+		Args: []params.StatusHistoryArg{
+			{Tag: machine.Tag().String(), Kind: params.KindProvisioning},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(historyStatuses(results.Results[0].History), jc.DeepEquals, []params.Status{
+		params.StatusInstanceRunning, params.StatusInstanceRequested,
+	})
+	c.Assert(results.Results[0].History[0].Info, gc.Equals, "i-foo")
+}
@@ -0,0 +1,33 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+)
+
+var MaxPageSize = &maxPageSize
+
+// StorageAccess mirrors storageAccess, so tests can supply their own.
+type StorageAccess interface {
+	StorageInstance(names.StorageTag) (state.StorageInstance, error)
+	Volumes(after string, limit int) ([]state.Volume, error)
+	Filesystems(after string, limit int) ([]state.Filesystem, error)
+	MachineVolumeAttachments(names.MachineTag) ([]state.VolumeAttachment, error)
+	MachineFilesystemAttachments(names.MachineTag) ([]state.FilesystemAttachment, error)
+	AddStorageForUnit(names.UnitTag, string, state.StorageConstraints) error
+	DestroyStorageAttachment(names.StorageTag, names.UnitTag) error
+}
+
+type patcher interface {
+	PatchValue(dest, value interface{})
+}
+
+func PatchState(p patcher, st StorageAccess) {
+	p.PatchValue(&getState, func(*state.State) storageAccess {
+		return st
+	})
+}
@@ -0,0 +1,484 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/storage"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	jujustorage "github.com/juju/juju/storage"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type storageMockSuite struct {
+	coretesting.BaseSuite
+	state *mockState
+	api   *storage.API
+	apiV2 *storage.APIV2
+}
+
+var _ = gc.Suite(&storageMockSuite{})
+
+func (s *storageMockSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.state = &mockState{}
+	storage.PatchState(s, s.state)
+	var err error
+	authorizer := apiservertesting.FakeAuthorizer{
+		Tag: names.NewUserTag("admin"),
+	}
+	s.api, err = storage.NewAPI(nil, nil, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+	s.apiV2, err = storage.NewAPIV2(nil, nil, authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *storageMockSuite) TestListVolumes(c *gc.C) {
+	s.state.volumes = []state.Volume{
+		&mockVolume{
+			tag:     names.NewVolumeTag("0"),
+			storage: names.NewStorageTag("data/0"),
+			info:    &state.VolumeInfo{VolumeId: "vol-0", Serial: "abc", Size: 1024},
+		},
+		&mockVolume{
+			tag:    names.NewVolumeTag("1"),
+			params: &state.VolumeParams{Size: 2048},
+		},
+	}
+	result, err := s.api.ListVolumes(rpcreflect.Background(), params.StoragePageArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListVolumesResult{
+		Volumes: []params.VolumeDetails{{
+			VolumeTag:   "volume-0",
+			StorageTag:  "storage-data-0",
+			VolumeId:    "vol-0",
+			Serial:      "abc",
+			Size:        1024,
+			Life:        params.Alive,
+			Provisioned: true,
+		}, {
+			VolumeTag: "volume-1",
+			Size:      2048,
+			Life:      params.Alive,
+		}},
+	})
+}
+
+func (s *storageMockSuite) TestListVolumesPages(c *gc.C) {
+	s.PatchValue(storage.MaxPageSize, 2)
+	for _, id := range []string{"0", "1", "2"} {
+		s.state.volumes = append(s.state.volumes, &mockVolume{tag: names.NewVolumeTag(id)})
+	}
+
+	result, err := s.api.ListVolumes(rpcreflect.Background(), params.StoragePageArgs{Limit: 5})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 2)
+	c.Assert(result.Next, gc.Equals, "1")
+
+	result, err = s.api.ListVolumes(rpcreflect.Background(), params.StoragePageArgs{After: result.Next})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 1)
+	c.Assert(result.Volumes[0].VolumeTag, gc.Equals, "volume-2")
+	c.Assert(result.Next, gc.Equals, "")
+	c.Assert(s.state.calls, jc.DeepEquals, []string{"Volumes  2", "Volumes 1 2"})
+}
+
+func (s *storageMockSuite) TestListAbandoned(c *gc.C) {
+	ctx := abandonedContext{}
+	_, err := s.api.ListVolumes(ctx, params.StoragePageArgs{})
+	c.Assert(err, gc.Equals, rpcreflect.ErrCallAbandoned)
+	_, err = s.api.ListFilesystems(ctx, params.StoragePageArgs{})
+	c.Assert(err, gc.Equals, rpcreflect.ErrCallAbandoned)
+	c.Assert(s.state.calls, gc.HasLen, 0)
+}
+
+// abandonedContext is an rpcreflect.Context for a call
+// whose connection has already closed.
+type abandonedContext struct {
+	rpcreflect.Context
+}
+
+func (abandonedContext) Err() error {
+	return rpcreflect.ErrCallAbandoned
+}
+
+func (s *storageMockSuite) TestListFilesystems(c *gc.C) {
+	s.state.filesystems = []state.Filesystem{
+		&mockFilesystem{
+			tag:     names.NewFilesystemTag("0"),
+			storage: names.NewStorageTag("data/0"),
+			volume:  names.NewVolumeTag("0"),
+			info:    &state.FilesystemInfo{Size: 1024},
+		},
+		&mockFilesystem{
+			tag:    names.NewFilesystemTag("1"),
+			params: &state.FilesystemParams{Size: 2048},
+		},
+	}
+	result, err := s.api.ListFilesystems(rpcreflect.Background(), params.StoragePageArgs{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListFilesystemsResult{
+		Filesystems: []params.FilesystemDetails{{
+			FilesystemTag: "filesystem-0",
+			StorageTag:    "storage-data-0",
+			VolumeTag:     "volume-0",
+			Size:          1024,
+			Life:          params.Alive,
+			Provisioned:   true,
+		}, {
+			FilesystemTag: "filesystem-1",
+			Size:          2048,
+			Life:          params.Alive,
+		}},
+	})
+}
+
+// addFilterVolumes adds three volumes: 0 is provisioned, attached to
+// machine 0, and holds storage of unit mysql/0; 1 is attached to
+// machine 1 and holds no storage; and 2 is attached to machine 0 and
+// holds storage of service wordpress.
+func (s *storageMockSuite) addFilterVolumes() {
+	s.state.volumes = []state.Volume{
+		&mockVolume{
+			tag:     names.NewVolumeTag("0"),
+			storage: names.NewStorageTag("data/0"),
+			info:    &state.VolumeInfo{VolumeId: "vol-0"},
+		},
+		&mockVolume{tag: names.NewVolumeTag("1")},
+		&mockVolume{
+			tag:     names.NewVolumeTag("2"),
+			storage: names.NewStorageTag("shared/1"),
+		},
+	}
+	s.state.storageOwners = map[string]names.Tag{
+		"data/0":   names.NewUnitTag("mysql/0"),
+		"shared/1": names.NewServiceTag("wordpress"),
+	}
+	s.state.attached = map[string][]string{
+		"0": {"0", "2"},
+		"1": {"1"},
+	}
+}
+
+func (s *storageMockSuite) listVolumes(c *gc.C, args params.StorageListArgs) []string {
+	result, err := s.apiV2.ListVolumes(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	tags := make([]string, len(result.Volumes))
+	for i, v := range result.Volumes {
+		tags[i] = v.VolumeTag
+	}
+	return tags
+}
+
+func (s *storageMockSuite) TestListVolumesFiltered(c *gc.C) {
+	s.addFilterVolumes()
+	provisioned := true
+	unprovisioned := false
+
+	c.Check(s.listVolumes(c, params.StorageListArgs{}), jc.DeepEquals, []string{
+		"volume-0", "volume-1", "volume-2",
+	})
+	c.Check(s.listVolumes(c, params.StorageListArgs{
+		Machines: []string{"0"},
+	}), jc.DeepEquals, []string{"volume-0", "volume-2"})
+	c.Check(s.listVolumes(c, params.StorageListArgs{
+		Services: []string{"mysql"},
+	}), jc.DeepEquals, []string{"volume-0"})
+	c.Check(s.listVolumes(c, params.StorageListArgs{
+		Services: []string{"wordpress"},
+	}), jc.DeepEquals, []string{"volume-2"})
+	c.Check(s.listVolumes(c, params.StorageListArgs{
+		Provisioned: &provisioned,
+	}), jc.DeepEquals, []string{"volume-0"})
+	c.Check(s.listVolumes(c, params.StorageListArgs{
+		Machines:    []string{"0", "1"},
+		Provisioned: &unprovisioned,
+	}), jc.DeepEquals, []string{"volume-1", "volume-2"})
+}
+
+func (s *storageMockSuite) TestListVolumesFilteredPages(c *gc.C) {
+	s.PatchValue(storage.MaxPageSize, 1)
+	s.addFilterVolumes()
+
+	args := params.StorageListArgs{Machines: []string{"0"}}
+	result, err := s.apiV2.ListVolumes(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 1)
+	c.Assert(result.Volumes[0].VolumeTag, gc.Equals, "volume-0")
+	c.Assert(result.Next, gc.Equals, "0")
+
+	// Volume 1 does not match, so the server reads on to fill the page.
+	args.After = result.Next
+	result, err = s.apiV2.ListVolumes(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 1)
+	c.Assert(result.Volumes[0].VolumeTag, gc.Equals, "volume-2")
+	c.Assert(result.Next, gc.Equals, "2")
+
+	args.After = result.Next
+	result, err = s.apiV2.ListVolumes(rpcreflect.Background(), args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, gc.HasLen, 0)
+	c.Assert(result.Next, gc.Equals, "")
+	c.Assert(s.state.calls, jc.DeepEquals, []string{
+		"MachineVolumeAttachments machine-0", "Volumes  1",
+		"MachineVolumeAttachments machine-0", "Volumes 0 1", "Volumes 1 1",
+		"MachineVolumeAttachments machine-0", "Volumes 2 1",
+	})
+}
+
+func (s *storageMockSuite) TestListFilesystemsFiltered(c *gc.C) {
+	s.state.filesystems = []state.Filesystem{
+		&mockFilesystem{tag: names.NewFilesystemTag("0")},
+		&mockFilesystem{tag: names.NewFilesystemTag("1")},
+	}
+	s.state.attached = map[string][]string{"1": {"1"}}
+	result, err := s.apiV2.ListFilesystems(rpcreflect.Background(), params.StorageListArgs{
+		Machines: []string{"1"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Filesystems, gc.HasLen, 1)
+	c.Assert(result.Filesystems[0].FilesystemTag, gc.Equals, "filesystem-1")
+}
+
+func (s *storageMockSuite) TestListInvalidMachine(c *gc.C) {
+	args := params.StorageListArgs{Machines: []string{"foo"}}
+	_, err := s.apiV2.ListVolumes(rpcreflect.Background(), args)
+	c.Assert(err, gc.ErrorMatches, `machine ID "foo" not valid`)
+	_, err = s.apiV2.ListFilesystems(rpcreflect.Background(), args)
+	c.Assert(err, gc.ErrorMatches, `machine ID "foo" not valid`)
+	c.Assert(s.state.calls, gc.HasLen, 0)
+}
+
+func (s *storageMockSuite) TestAddStorage(c *gc.C) {
+	result, err := s.api.AddStorage(params.StoragesAddParams{
+		Storages: []params.StorageAddParams{{
+			UnitTag:     "unit-mysql-0",
+			StorageName: "data",
+			Constraints: jujustorage.Constraints{Count: 2},
+		}, {
+			UnitTag:     "service-mysql",
+			StorageName: "data",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(s.state.calls, jc.DeepEquals, []string{"AddStorageForUnit unit-mysql-0 data 2"})
+}
+
+func (s *storageMockSuite) TestDetachStorage(c *gc.C) {
+	s.state.err = errors.New("boom")
+	result, err := s.api.DetachStorage(params.StorageAttachmentIds{
+		Ids: []params.StorageAttachmentId{{
+			StorageTag: "storage-data-0",
+			UnitTag:    "unit-mysql-0",
+		}, {
+			StorageTag: "storage-data-0",
+			UnitTag:    "machine-0",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.ErrorMatches, "boom")
+	c.Assert(result.Results[1].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(s.state.calls, jc.DeepEquals, []string{"DestroyStorageAttachment storage-data-0 unit-mysql-0"})
+}
+
+type mockState struct {
+	storage.StorageAccess
+	volumes     []state.Volume
+	filesystems []state.Filesystem
+	calls       []string
+	err         error
+
+	// storageOwners holds the owners of storage instances by ID.
+	storageOwners map[string]names.Tag
+
+	// attached holds the IDs of the volumes or filesystems
+	// attached to each machine, by machine ID.
+	attached map[string][]string
+}
+
+func (st *mockState) StorageInstance(tag names.StorageTag) (state.StorageInstance, error) {
+	owner, ok := st.storageOwners[tag.Id()]
+	if !ok {
+		return nil, errors.NotFoundf("storage instance %q", tag.Id())
+	}
+	return &mockStorageInstance{tag: tag, owner: owner}, nil
+}
+
+func (st *mockState) MachineVolumeAttachments(machine names.MachineTag) ([]state.VolumeAttachment, error) {
+	st.calls = append(st.calls, fmt.Sprintf("MachineVolumeAttachments %s", machine))
+	var result []state.VolumeAttachment
+	for _, id := range st.attached[machine.Id()] {
+		result = append(result, &mockVolumeAttachment{volume: names.NewVolumeTag(id)})
+	}
+	return result, st.err
+}
+
+func (st *mockState) MachineFilesystemAttachments(machine names.MachineTag) ([]state.FilesystemAttachment, error) {
+	st.calls = append(st.calls, fmt.Sprintf("MachineFilesystemAttachments %s", machine))
+	var result []state.FilesystemAttachment
+	for _, id := range st.attached[machine.Id()] {
+		result = append(result, &mockFilesystemAttachment{filesystem: names.NewFilesystemTag(id)})
+	}
+	return result, st.err
+}
+
+func (st *mockState) Volumes(after string, limit int) ([]state.Volume, error) {
+	st.calls = append(st.calls, fmt.Sprintf("Volumes %s %d", after, limit))
+	var result []state.Volume
+	for _, v := range st.volumes {
+		if v.VolumeTag().Id() > after && len(result) < limit {
+			result = append(result, v)
+		}
+	}
+	return result, st.err
+}
+
+func (st *mockState) Filesystems(after string, limit int) ([]state.Filesystem, error) {
+	st.calls = append(st.calls, fmt.Sprintf("Filesystems %s %d", after, limit))
+	var result []state.Filesystem
+	for _, f := range st.filesystems {
+		if f.FilesystemTag().Id() > after && len(result) < limit {
+			result = append(result, f)
+		}
+	}
+	return result, st.err
+}
+
+func (st *mockState) AddStorageForUnit(unit names.UnitTag, name string, cons state.StorageConstraints) error {
+	st.calls = append(st.calls, fmt.Sprintf("AddStorageForUnit %s %s %d", unit, name, cons.Count))
+	return st.err
+}
+
+func (st *mockState) DestroyStorageAttachment(storage names.StorageTag, unit names.UnitTag) error {
+	st.calls = append(st.calls, fmt.Sprintf("DestroyStorageAttachment %s %s", storage, unit))
+	return st.err
+}
+
+type mockVolume struct {
+	state.Volume
+	tag     names.VolumeTag
+	storage names.StorageTag
+	info    *state.VolumeInfo
+	params  *state.VolumeParams
+}
+
+func (v *mockVolume) VolumeTag() names.VolumeTag {
+	return v.tag
+}
+
+func (v *mockVolume) Life() state.Life {
+	return state.Alive
+}
+
+func (v *mockVolume) StorageInstance() (names.StorageTag, error) {
+	if v.storage.Id() == "" {
+		return names.StorageTag{}, errors.NewNotAssigned(nil, "not assigned")
+	}
+	return v.storage, nil
+}
+
+func (v *mockVolume) Info() (state.VolumeInfo, error) {
+	if v.info == nil {
+		return state.VolumeInfo{}, errors.NotProvisionedf("volume")
+	}
+	return *v.info, nil
+}
+
+func (v *mockVolume) Params() (state.VolumeParams, bool) {
+	if v.params == nil {
+		return state.VolumeParams{}, false
+	}
+	return *v.params, true
+}
+
+type mockFilesystem struct {
+	state.Filesystem
+	tag     names.FilesystemTag
+	storage names.StorageTag
+	volume  names.VolumeTag
+	info    *state.FilesystemInfo
+	params  *state.FilesystemParams
+}
+
+func (f *mockFilesystem) FilesystemTag() names.FilesystemTag {
+	return f.tag
+}
+
+func (f *mockFilesystem) Life() state.Life {
+	return state.Alive
+}
+
+func (f *mockFilesystem) Storage() (names.StorageTag, error) {
+	if f.storage.Id() == "" {
+		return names.StorageTag{}, errors.NewNotAssigned(nil, "not assigned")
+	}
+	return f.storage, nil
+}
+
+func (f *mockFilesystem) Volume() (names.VolumeTag, error) {
+	if f.volume.Id() == "" {
+		return names.VolumeTag{}, state.ErrNoBackingVolume
+	}
+	return f.volume, nil
+}
+
+func (f *mockFilesystem) Info() (state.FilesystemInfo, error) {
+	if f.info == nil {
+		return state.FilesystemInfo{}, errors.NotProvisionedf("filesystem")
+	}
+	return *f.info, nil
+}
+
+func (f *mockFilesystem) Params() (state.FilesystemParams, bool) {
+	if f.params == nil {
+		return state.FilesystemParams{}, false
+	}
+	return *f.params, true
+}
+
+type mockStorageInstance struct {
+	state.StorageInstance
+	tag   names.StorageTag
+	owner names.Tag
+}
+
+func (i *mockStorageInstance) StorageTag() names.StorageTag {
+	return i.tag
+}
+
+func (i *mockStorageInstance) Owner() names.Tag {
+	return i.owner
+}
+
+type mockVolumeAttachment struct {
+	state.VolumeAttachment
+	volume names.VolumeTag
+}
+
+func (a *mockVolumeAttachment) Volume() names.VolumeTag {
+	return a.volume
+}
+
+type mockFilesystemAttachment struct {
+	state.FilesystemAttachment
+	filesystem names.FilesystemTag
+}
+
+func (a *mockFilesystemAttachment) Filesystem() names.FilesystemTag {
+	return a.filesystem
+}
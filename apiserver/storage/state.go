@@ -11,6 +11,12 @@ import (
 
 type storageAccess interface {
 	StorageInstance(names.StorageTag) (state.StorageInstance, error)
+	Volumes(after string, limit int) ([]state.Volume, error)
+	Filesystems(after string, limit int) ([]state.Filesystem, error)
+	MachineVolumeAttachments(names.MachineTag) ([]state.VolumeAttachment, error)
+	MachineFilesystemAttachments(names.MachineTag) ([]state.FilesystemAttachment, error)
+	AddStorageForUnit(names.UnitTag, string, state.StorageConstraints) error
+	DestroyStorageAttachment(names.StorageTag, names.UnitTag) error
 }
 
 type stateShim struct {
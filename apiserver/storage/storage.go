@@ -10,6 +10,7 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/rpc/rpcreflect"
 	"github.com/juju/juju/state"
 )
 
@@ -23,8 +24,16 @@ var getState = func(st *state.State) storageAccess {
 
 type StorageAPI interface {
 	Show(entities params.Entities) (params.StorageShowResults, error)
+	ListVolumes(ctx rpcreflect.Context, args params.StoragePageArgs) (params.ListVolumesResult, error)
+	ListFilesystems(ctx rpcreflect.Context, args params.StoragePageArgs) (params.ListFilesystemsResult, error)
+	AddStorage(args params.StoragesAddParams) (params.ErrorResults, error)
+	DetachStorage(args params.StorageAttachmentIds) (params.ErrorResults, error)
 }
 
+// maxPageSize is the largest number of volumes or filesystems
+// returned by a single call to ListVolumes or ListFilesystems.
+var maxPageSize = 1000
+
 // API implements the storage interface and is the concrete
 // implementation of the api end point.
 type API struct {
@@ -79,3 +88,143 @@ func (api *API) oneStorageInstance(tag string) (params.StorageInstance, error) {
 		Kind:       params.StorageKind(stateStorageInstance.Kind()),
 	}, nil
 }
+
+// pageLimit returns the number of entries to return for a page
+// requested with the given limit.
+func pageLimit(limit int) int {
+	if limit <= 0 || limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// ListVolumes returns a page of the volumes in the environment, in
+// order of volume ID. Nothing is read once ctx has been abandoned.
+func (api *API) ListVolumes(ctx rpcreflect.Context, args params.StoragePageArgs) (params.ListVolumesResult, error) {
+	if err := ctx.Err(); err != nil {
+		return params.ListVolumesResult{}, err
+	}
+	limit := pageLimit(args.Limit)
+	volumes, err := api.storage.Volumes(args.After, limit)
+	if err != nil {
+		return params.ListVolumesResult{}, common.ServerError(err)
+	}
+	result := params.ListVolumesResult{
+		Volumes: make([]params.VolumeDetails, len(volumes)),
+	}
+	for i, v := range volumes {
+		result.Volumes[i] = volumeDetails(v)
+	}
+	if len(volumes) == limit {
+		result.Next = volumes[len(volumes)-1].VolumeTag().Id()
+	}
+	return result, nil
+}
+
+func volumeDetails(v state.Volume) params.VolumeDetails {
+	details := params.VolumeDetails{
+		VolumeTag: v.VolumeTag().String(),
+		Life:      params.Life(v.Life().String()),
+	}
+	if storageTag, err := v.StorageInstance(); err == nil {
+		details.StorageTag = storageTag.String()
+	}
+	if info, err := v.Info(); err == nil {
+		details.VolumeId = info.VolumeId
+		details.Serial = info.Serial
+		details.Size = info.Size
+		details.Provisioned = true
+	} else if volumeParams, ok := v.Params(); ok {
+		details.Size = volumeParams.Size
+	}
+	return details
+}
+
+// ListFilesystems returns a page of the filesystems in the environment,
+// in order of filesystem ID. Nothing is read once ctx has been abandoned.
+func (api *API) ListFilesystems(ctx rpcreflect.Context, args params.StoragePageArgs) (params.ListFilesystemsResult, error) {
+	if err := ctx.Err(); err != nil {
+		return params.ListFilesystemsResult{}, err
+	}
+	limit := pageLimit(args.Limit)
+	filesystems, err := api.storage.Filesystems(args.After, limit)
+	if err != nil {
+		return params.ListFilesystemsResult{}, common.ServerError(err)
+	}
+	result := params.ListFilesystemsResult{
+		Filesystems: make([]params.FilesystemDetails, len(filesystems)),
+	}
+	for i, f := range filesystems {
+		result.Filesystems[i] = filesystemDetails(f)
+	}
+	if len(filesystems) == limit {
+		result.Next = filesystems[len(filesystems)-1].FilesystemTag().Id()
+	}
+	return result, nil
+}
+
+func filesystemDetails(f state.Filesystem) params.FilesystemDetails {
+	details := params.FilesystemDetails{
+		FilesystemTag: f.FilesystemTag().String(),
+		Life:          params.Life(f.Life().String()),
+	}
+	if storageTag, err := f.Storage(); err == nil {
+		details.StorageTag = storageTag.String()
+	}
+	if volumeTag, err := f.Volume(); err == nil {
+		details.VolumeTag = volumeTag.String()
+	}
+	if info, err := f.Info(); err == nil {
+		details.FilesystemId = info.FilesystemId
+		details.Size = info.Size
+		details.Provisioned = true
+	} else if filesystemParams, ok := f.Params(); ok {
+		details.Size = filesystemParams.Size
+	}
+	return details
+}
+
+// AddStorage adds instances of charm storage to units.
+func (api *API) AddStorage(args params.StoragesAddParams) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Storages)),
+	}
+	for i, arg := range args.Storages {
+		unitTag, err := names.ParseUnitTag(arg.UnitTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = api.storage.AddStorageForUnit(unitTag, arg.StorageName, state.StorageConstraints{
+			Pool:  arg.Constraints.Pool,
+			Size:  arg.Constraints.Size,
+			Count: arg.Constraints.Count,
+		})
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// DetachStorage detaches storage instances from units. The storage
+// attachments are destroyed, and removed once the units have finished
+// with them.
+func (api *API) DetachStorage(args params.StorageAttachmentIds) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		storageTag, err := names.ParseStorageTag(id.StorageTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		unitTag, err := names.ParseUnitTag(id.UnitTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = api.storage.DestroyStorageAttachment(storageTag, unitTag)
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacadeForFeature("Storage", 2, NewAPIV2, feature.Storage)
+}
+
+// APIV2 implements version 2 of the storage facade, which lets
+// ListVolumes and ListFilesystems filter what they list.
+type APIV2 struct {
+	API
+}
+
+// NewAPIV2 returns a new storage API facade, version 2.
+func NewAPIV2(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*APIV2, error) {
+	api, err := NewAPI(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &APIV2{
+		API: *api,
+	}, nil
+}
+
+// ListVolumes returns a page of the volumes in the environment that
+// match the given filters, in order of volume ID.
+func (api *APIV2) ListVolumes(ctx rpcreflect.Context, args params.StorageListArgs) (params.ListVolumesResult, error) {
+	filter, err := api.newStorageFilter(args)
+	if err != nil {
+		return params.ListVolumesResult{}, err
+	}
+	if filter.machines != nil {
+		filter.attached = set.NewStrings()
+		for _, machine := range filter.machines {
+			attachments, err := api.storage.MachineVolumeAttachments(machine)
+			if err != nil {
+				return params.ListVolumesResult{}, common.ServerError(err)
+			}
+			for _, att := range attachments {
+				filter.attached.Add(att.Volume().Id())
+			}
+		}
+	}
+	limit := pageLimit(args.Limit)
+	result := params.ListVolumesResult{
+		Volumes: []params.VolumeDetails{},
+	}
+	after := args.After
+	for {
+		if err := ctx.Err(); err != nil {
+			return params.ListVolumesResult{}, err
+		}
+		volumes, err := api.storage.Volumes(after, limit)
+		if err != nil {
+			return params.ListVolumesResult{}, common.ServerError(err)
+		}
+		for _, v := range volumes {
+			after = v.VolumeTag().Id()
+			details := volumeDetails(v)
+			storageTag, _ := v.StorageInstance()
+			if ok, err := filter.match(after, storageTag, details.Provisioned); err != nil {
+				return params.ListVolumesResult{}, common.ServerError(err)
+			} else if !ok {
+				continue
+			}
+			result.Volumes = append(result.Volumes, details)
+			if len(result.Volumes) == limit {
+				result.Next = after
+				return result, nil
+			}
+		}
+		if len(volumes) < limit {
+			return result, nil
+		}
+	}
+}
+
+// ListFilesystems returns a page of the filesystems in the environment
+// that match the given filters, in order of filesystem ID.
+func (api *APIV2) ListFilesystems(ctx rpcreflect.Context, args params.StorageListArgs) (params.ListFilesystemsResult, error) {
+	filter, err := api.newStorageFilter(args)
+	if err != nil {
+		return params.ListFilesystemsResult{}, err
+	}
+	if filter.machines != nil {
+		filter.attached = set.NewStrings()
+		for _, machine := range filter.machines {
+			attachments, err := api.storage.MachineFilesystemAttachments(machine)
+			if err != nil {
+				return params.ListFilesystemsResult{}, common.ServerError(err)
+			}
+			for _, att := range attachments {
+				filter.attached.Add(att.Filesystem().Id())
+			}
+		}
+	}
+	limit := pageLimit(args.Limit)
+	result := params.ListFilesystemsResult{
+		Filesystems: []params.FilesystemDetails{},
+	}
+	after := args.After
+	for {
+		if err := ctx.Err(); err != nil {
+			return params.ListFilesystemsResult{}, err
+		}
+		filesystems, err := api.storage.Filesystems(after, limit)
+		if err != nil {
+			return params.ListFilesystemsResult{}, common.ServerError(err)
+		}
+		for _, f := range filesystems {
+			after = f.FilesystemTag().Id()
+			details := filesystemDetails(f)
+			storageTag, _ := f.Storage()
+			if ok, err := filter.match(after, storageTag, details.Provisioned); err != nil {
+				return params.ListFilesystemsResult{}, common.ServerError(err)
+			} else if !ok {
+				continue
+			}
+			result.Filesystems = append(result.Filesystems, details)
+			if len(result.Filesystems) == limit {
+				result.Next = after
+				return result, nil
+			}
+		}
+		if len(filesystems) < limit {
+			return result, nil
+		}
+	}
+}
+
+// storageFilter matches volumes or filesystems against the filters
+// in a params.StorageListArgs.
+type storageFilter struct {
+	storage storageAccess
+
+	// machines holds the machines to list the attachments of, or
+	// nil if the list is not filtered by machine.
+	machines []names.MachineTag
+
+	// attached holds the IDs of the volumes or filesystems
+	// attached to machines.
+	attached set.Strings
+
+	services    set.Strings
+	provisioned *bool
+}
+
+func (api *APIV2) newStorageFilter(args params.StorageListArgs) (*storageFilter, error) {
+	filter := &storageFilter{
+		storage:     api.storage,
+		services:    set.NewStrings(args.Services...),
+		provisioned: args.Provisioned,
+	}
+	if len(args.Machines) > 0 {
+		filter.machines = make([]names.MachineTag, len(args.Machines))
+		for i, id := range args.Machines {
+			if !names.IsValidMachine(id) {
+				return nil, errors.NotValidf("machine ID %q", id)
+			}
+			filter.machines[i] = names.NewMachineTag(id)
+		}
+	}
+	return filter, nil
+}
+
+// match reports whether the volume or filesystem with the given ID,
+// assigned to the given storage instance, matches the filter. The
+// storage tag is empty if it is not assigned to a storage instance.
+func (f *storageFilter) match(id string, storageTag names.StorageTag, provisioned bool) (bool, error) {
+	if f.provisioned != nil && *f.provisioned != provisioned {
+		return false, nil
+	}
+	if f.machines != nil && !f.attached.Contains(id) {
+		return false, nil
+	}
+	if f.services.IsEmpty() {
+		return true, nil
+	}
+	if storageTag.Id() == "" {
+		return false, nil
+	}
+	instance, err := f.storage.StorageInstance(storageTag)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	var serviceName string
+	switch owner := instance.Owner().(type) {
+	case names.ServiceTag:
+		serviceName = owner.Id()
+	case names.UnitTag:
+		serviceName, err = names.UnitService(owner.Id())
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return f.services.Contains(serviceName), nil
+}
@@ -62,13 +62,10 @@ func NewStorageProvisionerAPI(st *state.State, resources *common.Resources, auth
 					// scoped to their own machine.
 					return true
 				}
-				parentId := state.ParentId(tag.Id())
-				if parentId == "" {
-					return false
-				}
-				// All containers with the authenticated
-				// machine as a parent are accessible by it.
-				return names.NewMachineTag(parentId) == authEntityTag
+				// All containers hosted by the authenticated
+				// machine, at any level of nesting, are
+				// accessible by it.
+				return authEntityTag != nil && state.IsContainedBy(tag.Id(), authEntityTag.Id())
 			default:
 				return false
 			}
@@ -124,16 +121,14 @@ func (s *StorageProvisionerAPI) WatchVolumes(args params.Entities) (params.Strin
 		}
 		return "", nil, watcher.EnsureErr(w)
 	}
-	for i, arg := range args.Entities {
-		var result params.StringsWatchResult
-		id, changes, err := one(arg)
-		if err != nil {
-			result.Error = common.ServerError(err)
-		} else {
-			result.StringsWatcherId = id
-			result.Changes = changes
-		}
-		results.Results[i] = result
+	errs := common.BulkCall(len(args.Entities), func(i int) error {
+		var err error
+		result := &results.Results[i]
+		result.StringsWatcherId, result.Changes, err = one(args.Entities[i])
+		return err
+	})
+	for i, err := range errs {
+		results.Results[i].Error = err
 	}
 	return results, nil
 }
@@ -160,15 +155,13 @@ func (s *StorageProvisionerAPI) Volumes(args params.Entities) (params.VolumeResu
 		}
 		return common.VolumeFromState(volume)
 	}
-	for i, arg := range args.Entities {
-		var result params.VolumeResult
-		volume, err := one(arg)
-		if err != nil {
-			result.Error = common.ServerError(err)
-		} else {
-			result.Result = volume
-		}
-		results.Results[i] = result
+	errs := common.BulkCall(len(args.Entities), func(i int) error {
+		var err error
+		results.Results[i].Result, err = one(args.Entities[i])
+		return err
+	})
+	for i, err := range errs {
+		results.Results[i].Error = err
 	}
 	return results, nil
 }
@@ -208,15 +201,13 @@ func (s *StorageProvisionerAPI) VolumeParams(args params.Entities) (params.Volum
 		}
 		return volumeParams, nil
 	}
-	for i, arg := range args.Entities {
-		var result params.VolumeParamsResult
-		volumeParams, err := one(arg)
-		if err != nil {
-			result.Error = common.ServerError(err)
-		} else {
-			result.Result = volumeParams
-		}
-		results.Results[i] = result
+	errs := common.BulkCall(len(args.Entities), func(i int) error {
+		var err error
+		results.Results[i].Result, err = one(args.Entities[i])
+		return err
+	})
+	for i, err := range errs {
+		results.Results[i].Error = err
 	}
 	return results, nil
 }
@@ -227,9 +218,6 @@ func (s *StorageProvisionerAPI) SetVolumeInfo(args params.Volumes) (params.Error
 	if err != nil {
 		return params.ErrorResults{}, err
 	}
-	results := params.ErrorResults{
-		Results: make([]params.ErrorResult, len(args.Volumes)),
-	}
 	one := func(arg params.Volume) error {
 		volumeTag, volumeInfo, err := common.VolumeToState(arg)
 		if err != nil {
@@ -243,9 +231,7 @@ func (s *StorageProvisionerAPI) SetVolumeInfo(args params.Volumes) (params.Error
 		}
 		return errors.Trace(err)
 	}
-	for i, arg := range args.Volumes {
-		err := one(arg)
-		results.Results[i].Error = common.ServerError(err)
-	}
-	return results, nil
+	return common.BulkErrorResults(len(args.Volumes), func(i int) error {
+		return one(args.Volumes[i])
+	}), nil
 }
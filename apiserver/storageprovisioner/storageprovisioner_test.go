@@ -92,6 +92,30 @@ func (s *provisionerSuite) TestVolumes(c *gc.C) {
 	})
 }
 
+func (s *provisionerSuite) TestWatchVolumesNestedContainers(c *gc.C) {
+	s.setupVolumes(c)
+	container := s.factory.MakeMachineNested(c, "0", nil)
+	nested := s.factory.MakeMachineNested(c, container.Id(), nil)
+	other := s.factory.MakeMachineNested(c, "1", nil)
+
+	args := params.Entities{Entities: []params.Entity{
+		{container.Tag().String()},
+		{nested.Tag().String()},
+		{other.Tag().String()},
+	}}
+	result, err := s.api.WatchVolumes(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1].Error, gc.IsNil)
+	c.Assert(result.Results[2].Error, gc.DeepEquals, apiservertesting.ErrUnauthorized)
+	c.Assert(s.resources.Count(), gc.Equals, 2)
+	for _, id := range []string{"1", "2"} {
+		w := s.resources.Get(id)
+		defer statetesting.AssertStop(c, w)
+	}
+}
+
 func (s *provisionerSuite) TestVolumesEmptyArgs(c *gc.C) {
 	results, err := s.api.Volumes(params.Entities{})
 	c.Assert(err, jc.ErrorIsNil)
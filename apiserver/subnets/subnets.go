@@ -0,0 +1,91 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package subnets implements the API used by clients to discover the
+// subnets known to the provider of an environment.
+package subnets
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Subnets", 1, NewAPI)
+}
+
+// API implements the Subnets facade.
+type API struct {
+	st *state.State
+}
+
+// NewAPI returns a new Subnets API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{st: st}, nil
+}
+
+var newEnviron = func(st *state.State) (environs.Environ, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return environs.New(cfg)
+}
+
+// ListSubnets returns the subnets the environment's provider knows
+// about, restricted to those matching the given filters. An error is
+// returned if the provider cannot list its subnets.
+func (api *API) ListSubnets(args params.SubnetsFilters) (params.ListSubnetsResults, error) {
+	var result params.ListSubnetsResults
+	env, err := newEnviron(api.st)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot open environment")
+	}
+	lister, ok := env.(environs.SubnetLister)
+	if !ok {
+		return result, errors.NotSupportedf("listing subnets of %q environments", env.Config().Type())
+	}
+	subnets, err := lister.Subnets("", nil)
+	if err != nil {
+		return result, errors.Annotate(err, "cannot list subnets")
+	}
+	result.Results = []params.Subnet{}
+	for _, subnet := range subnets {
+		if args.Zone != "" && !inZone(subnet.AvailabilityZones, args.Zone) {
+			continue
+		}
+		result.Results = append(result.Results, params.Subnet{
+			CIDR:       subnet.CIDR,
+			ProviderId: string(subnet.ProviderId),
+			VLANTag:    subnet.VLANTag,
+			Zones:      subnet.AvailabilityZones,
+		})
+	}
+	return result, nil
+}
+
+// inZone returns whether a subnet available in the given zones can be
+// used in the named zone. A subnet with no zones can be used in all of
+// them.
+func inZone(zones []string, zone string) bool {
+	if len(zones) == 0 {
+		return true
+	}
+	for _, z := range zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package subnets_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/apiserver/subnets"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type subnetsSuite struct {
+	jujutesting.JujuConnSuite
+	api *subnets.API
+}
+
+var _ = gc.Suite(&subnetsSuite{})
+
+func (s *subnetsSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = subnets.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *subnetsSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := subnets.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *subnetsSuite) TestListSubnets(c *gc.C) {
+	result, err := s.api.ListSubnets(params.SubnetsFilters{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListSubnetsResults{
+		Results: []params.Subnet{
+			{CIDR: "0.10.0.0/24", ProviderId: "dummy-private"},
+			{CIDR: "0.20.0.0/24", ProviderId: "dummy-public"},
+		},
+	})
+}
+
+type zonedSubnetsEnviron struct {
+	environs.Environ
+}
+
+func (zonedSubnetsEnviron) Subnets(instance.Id, []network.Id) ([]network.SubnetInfo, error) {
+	return []network.SubnetInfo{
+		{CIDR: "10.0.1.0/24", ProviderId: "sub-1", AvailabilityZones: []string{"zone-a", "zone-b"}},
+		{CIDR: "10.0.2.0/24", ProviderId: "sub-2", AvailabilityZones: []string{"zone-b"}},
+		{CIDR: "10.0.3.0/24", ProviderId: "sub-3"},
+	}, nil
+}
+
+func (s *subnetsSuite) patchEnviron(c *gc.C, wrap func(environs.Environ) environs.Environ) {
+	s.PatchValue(subnets.NewEnviron, func(st *state.State) (environs.Environ, error) {
+		cfg, err := st.EnvironConfig()
+		c.Assert(err, jc.ErrorIsNil)
+		env, err := environs.New(cfg)
+		c.Assert(err, jc.ErrorIsNil)
+		return wrap(env), nil
+	})
+}
+
+func (s *subnetsSuite) TestListSubnetsInZone(c *gc.C) {
+	s.patchEnviron(c, func(env environs.Environ) environs.Environ {
+		return zonedSubnetsEnviron{env}
+	})
+	result, err := s.api.ListSubnets(params.SubnetsFilters{Zone: "zone-a"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ListSubnetsResults{
+		Results: []params.Subnet{
+			{CIDR: "10.0.1.0/24", ProviderId: "sub-1", Zones: []string{"zone-a", "zone-b"}},
+			{CIDR: "10.0.3.0/24", ProviderId: "sub-3"},
+		},
+	})
+}
+
+type plainEnviron struct {
+	environs.Environ
+}
+
+func (s *subnetsSuite) TestListSubnetsNotSupported(c *gc.C) {
+	s.patchEnviron(c, func(env environs.Environ) environs.Environ {
+		return plainEnviron{env}
+	})
+	_, err := s.api.ListSubnets(params.SubnetsFilters{})
+	c.Assert(err, gc.ErrorMatches, `listing subnets of "dummy" environments not supported`)
+}
@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// suspendableRoot rejects the API calls that carry out automated
+// changes to the environment while the environment is suspended.
+// Read-only calls are unaffected, so agents continue to observe the
+// environment.
+type suspendableRoot struct {
+	rpc.MethodFinder
+	st *state.State
+}
+
+// newSuspendableRoot returns a new suspendableRoot.
+func newSuspendableRoot(finder rpc.MethodFinder, st *state.State) *suspendableRoot {
+	return &suspendableRoot{
+		MethodFinder: finder,
+		st:           st,
+	}
+}
+
+// methodsBlockedWhileSuspended holds the methods which are rejected
+// while the environment is suspended. Machine provisioning is refused
+// by Provisioner.ProvisioningInfo itself.
+var methodsBlockedWhileSuspended = set.NewStrings(
+	"CharmRevisionUpdater.UpdateLatestRevisions", // contacts the charm store
+	"Provisioner.PrepareContainerInterfaceInfo",  // allocates provider addresses
+	"Uniter.SetCharmURL",                         // starts charm installs and upgrades
+)
+
+// FindMethod returns an error reporting the suspension for methods in
+// methodsBlockedWhileSuspended, if the environment is suspended when
+// the method is called.
+func (r *suspendableRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if !methodsBlockedWhileSuspended.Contains(rootName + "." + methodName) {
+		return caller, nil
+	}
+	env, err := r.st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if suspended, reason := env.Suspended(); suspended {
+		return nil, common.EnvironmentSuspendedError(reason)
+	}
+	return caller, nil
+}
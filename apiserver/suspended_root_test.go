@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+)
+
+type suspendableRootSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&suspendableRootSuite{})
+
+func (s *suspendableRootSuite) suspend(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *suspendableRootSuite) TestBlockedMethodAllowedWhenNotSuspended(c *gc.C) {
+	root := apiserver.TestingSuspendableRoot(s.State)
+	caller, err := root.FindMethod("Uniter", 8, "SetCharmURL")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caller, gc.NotNil)
+}
+
+func (s *suspendableRootSuite) TestBlockedMethodWhenSuspended(c *gc.C) {
+	s.suspend(c)
+	root := apiserver.TestingSuspendableRoot(s.State)
+	caller, err := root.FindMethod("Uniter", 8, "SetCharmURL")
+	c.Assert(err, gc.ErrorMatches, "environment suspended: provider outage")
+	c.Assert(common.ServerError(err).Code, gc.Equals, params.CodeEnvironmentSuspended)
+	c.Assert(caller, gc.IsNil)
+}
+
+func (s *suspendableRootSuite) TestReadOnlyMethodWhenSuspended(c *gc.C) {
+	s.suspend(c)
+	root := apiserver.TestingSuspendableRoot(s.State)
+	caller, err := root.FindMethod("Uniter", 8, "CharmURL")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caller, gc.NotNil)
+}
+
+func (s *suspendableRootSuite) TestFindNonExistentMethod(c *gc.C) {
+	root := apiserver.TestingSuspendableRoot(s.State)
+	caller, err := root.FindMethod("Foo", 0, "Bar")
+	c.Assert(err, gc.ErrorMatches, "unknown object type \"Foo\"")
+	c.Assert(caller, gc.IsNil)
+}
@@ -13,9 +13,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
-	"github.com/juju/utils"
 
 	"github.com/juju/juju/apiserver/common"
 	apihttp "github.com/juju/juju/apiserver/http"
@@ -25,6 +25,7 @@ import (
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/toolstorage"
 	"github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/version"
 )
 
@@ -54,13 +55,13 @@ func (h *toolsDownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 	switch r.Method {
 	case "GET":
-		tarball, err := h.processGet(r, stateWrapper.state)
+		content, err := h.processGet(r, stateWrapper.state)
 		if err != nil {
 			logger.Errorf("GET(%s) failed: %v", r.URL, err)
 			h.sendExistingError(w, http.StatusBadRequest, err)
 			return
 		}
-		h.sendTools(w, http.StatusOK, tarball)
+		h.sendTools(w, r, content)
 	default:
 		h.sendError(w, http.StatusMethodNotAllowed, fmt.Sprintf("unsupported method: %q", r.Method))
 	}
@@ -86,7 +87,7 @@ func (h *toolsUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// Add tools to storage.
 		agentTools, err := h.processPost(r, stateWrapper.state)
 		if err != nil {
-			h.sendExistingError(w, http.StatusBadRequest, err)
+			h.sendExistingError(w, uploadErrorStatus(err, http.StatusBadRequest), err)
 			return
 		}
 		h.sendJSON(w, http.StatusOK, &params.ToolsResult{Tools: agentTools})
@@ -123,9 +124,20 @@ func (h *toolsHandler) sendExistingError(w http.ResponseWriter, statusCode int,
 	}
 }
 
-// processGet handles a tools GET request.
-func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]byte, error) {
-	version, err := version.ParseBinary(r.URL.Query().Get(":version"))
+// toolsContent holds the body of a response to a tools GET request.
+type toolsContent struct {
+	data        []byte
+	contentType string
+	sha256      string
+}
+
+// processGet handles a tools GET request. If the request names the
+// version of the tools the client is upgrading from, and those tools
+// are stored with the same major and minor version, a delta between
+// the two is returned instead of the full tarball when smaller.
+func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) (*toolsContent, error) {
+	query := r.URL.Query()
+	vers, err := version.ParseBinary(query.Get(":version"))
 	if err != nil {
 		return nil, errors.Annotate(err, "error parsing version")
 	}
@@ -134,13 +146,13 @@ func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]b
 		return nil, errors.Annotate(err, "error getting tools storage")
 	}
 	defer storage.Close()
-	_, reader, err := storage.Tools(version)
+	_, reader, err := storage.Tools(vers)
 	if errors.IsNotFound(err) {
 		// Tools could not be found in toolstorage,
 		// so look for them in simplestreams, fetch
 		// them and cache in toolstorage.
-		logger.Infof("%v tools not found locally, fetching", version)
-		reader, err = h.fetchAndCacheTools(version, storage, st)
+		logger.Infof("%v tools not found locally, fetching", vers)
+		reader, err = h.fetchAndCacheTools(vers, storage, st)
 		if err != nil {
 			err = errors.Annotate(err, "error fetching tools")
 		}
@@ -149,11 +161,67 @@ func (h *toolsDownloadHandler) processGet(r *http.Request, st *state.State) ([]b
 		return nil, err
 	}
 	defer reader.Close()
-	data, err := ioutil.ReadAll(reader)
+	data, sha256, err := readAndHash(reader)
 	if err != nil {
 		return nil, errors.Annotate(err, "failed to read tools tarball")
 	}
-	return data, nil
+	content := &toolsContent{
+		data:        data,
+		contentType: "application/x-tar-gz",
+		sha256:      sha256,
+	}
+	if fromParam := query.Get("from"); fromParam != "" {
+		from, err := version.ParseBinary(fromParam)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid tools version %q", fromParam)
+		}
+		deltaContent, err := toolsDelta(storage, from, vers, data)
+		if err != nil {
+			// The client can always fall back to the full tarball.
+			logger.Warningf("cannot compute delta from %v to %v tools: %v", from, vers, err)
+		} else if deltaContent != nil {
+			content = deltaContent
+		}
+	}
+	return content, nil
+}
+
+// toolsDelta returns a delta which constructs the tools in tarball,
+// with version to, from the unpacked tools with version from. It
+// returns nil if the tools are not for patch releases of the same
+// major and minor version, if the original tools are not stored,
+// or if the delta is no smaller than the tarball itself.
+func toolsDelta(stor toolstorage.Storage, from, to version.Binary, tarball []byte) (*toolsContent, error) {
+	if from.Major != to.Major || from.Minor != to.Minor || from.Number == to.Number {
+		return nil, nil
+	}
+	if from.Series != to.Series || from.Arch != to.Arch {
+		return nil, nil
+	}
+	_, reader, err := stor.Tools(from)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer reader.Close()
+	d, err := delta.Compute(reader, bytes.NewReader(tarball))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if buf.Len() >= len(tarball) {
+		return nil, nil
+	}
+	logger.Debugf("sending %d byte delta from %v to %v tools", buf.Len(), from, to)
+	return &toolsContent{
+		data:        buf.Bytes(),
+		contentType: delta.ContentType,
+		sha256:      fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	}, nil
 }
 
 // fetchAndCacheTools fetches tools with the specified version by searching for a URL
@@ -164,6 +232,9 @@ func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor toolsto
 	if err != nil {
 		return nil, err
 	}
+	if envcfg.OfflineMode() {
+		return nil, errors.NotFoundf("%v tools (environment is offline)", v)
+	}
 	env, err := environs.New(envcfg)
 	if err != nil {
 		return nil, err
@@ -172,31 +243,10 @@ func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor toolsto
 	if err != nil {
 		return nil, err
 	}
-
-	// No need to verify the server's identity because we verify the SHA-256 hash.
-	logger.Infof("fetching %v tools from %v", v, tools.URL)
-	resp, err := utils.GetNonValidatingHTTPClient().Get(tools.URL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("bad HTTP response: %v", resp.Status)
-		if body, err := ioutil.ReadAll(resp.Body); err == nil {
-			msg += fmt.Sprintf(" (%s)", bytes.TrimSpace(body))
-		}
-		return nil, errors.New(msg)
-	}
-	data, sha256, err := readAndHash(resp.Body)
+	data, err := envtools.FetchTools(tools)
 	if err != nil {
 		return nil, err
 	}
-	if int64(len(data)) != tools.Size {
-		return nil, errors.Errorf("size mismatch for %s", tools.URL)
-	}
-	if sha256 != tools.SHA256 {
-		return nil, errors.Errorf("hash mismatch for %s", tools.URL)
-	}
 
 	// Cache tarball in toolstorage before returning.
 	metadata := toolstorage.Metadata{
@@ -210,15 +260,12 @@ func (h *toolsDownloadHandler) fetchAndCacheTools(v version.Binary, stor toolsto
 	return ioutil.NopCloser(bytes.NewReader(data)), nil
 }
 
-// sendTools streams the tools tarball to the client.
-func (h *toolsDownloadHandler) sendTools(w http.ResponseWriter, statusCode int, tarball []byte) {
-	w.Header().Set("Content-Type", "application/x-tar-gz")
-	w.Header().Set("Content-Length", fmt.Sprint(len(tarball)))
-	w.WriteHeader(statusCode)
-	if _, err := w.Write(tarball); err != nil {
-		h.sendExistingError(w, http.StatusBadRequest, errors.Annotatef(err, "failed to write tools"))
-		return
-	}
+// sendTools streams the tools tarball or delta to the client. Range
+// requests are supported so that interrupted downloads can be resumed.
+func (h *toolsDownloadHandler) sendTools(w http.ResponseWriter, r *http.Request, content *toolsContent) {
+	w.Header().Set("Content-Type", content.contentType)
+	w.Header().Set("ETag", fmt.Sprintf("%q", content.sha256))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content.data))
 }
 
 // processPost handles a tools upload POST request after authentication.
@@ -259,7 +306,18 @@ func (h *toolsUploadHandler) processPost(r *http.Request, st *state.State) (*too
 			toolsVersions = append(toolsVersions, v)
 		}
 	}
-	return h.handleUpload(r.Body, toolsVersions, serverRoot, st)
+
+	// Spool the tools tarball to disk rather than holding
+	// it in memory, calculating the sha256 along the way.
+	upload, err := spoolUpload(r.Body, r.ContentLength, h.maxUploadSize)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer upload.Close()
+	if err := upload.checkDigest(r); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return h.handleUpload(upload, toolsVersions, serverRoot, st)
 }
 
 func (h *toolsUploadHandler) getServerRoot(r *http.Request, query url.Values, st *state.State) (string, error) {
@@ -274,8 +332,8 @@ func (h *toolsUploadHandler) getServerRoot(r *http.Request, query url.Values, st
 	return fmt.Sprintf("https://%s/environment/%s", r.Host, uuid), nil
 }
 
-// handleUpload uploads the tools data from the reader to env storage as the specified version.
-func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.Binary, serverRoot string, st *state.State) (*tools.Tools, error) {
+// handleUpload uploads the spooled tools data to env storage as the specified version.
+func (h *toolsUploadHandler) handleUpload(upload *spooledUpload, toolsVersions []version.Binary, serverRoot string, st *state.State) (*tools.Tools, error) {
 	// Check if changes are allowed and the command may proceed.
 	blockChecker := common.NewBlockChecker(st)
 	if err := blockChecker.ChangeAllowed(); err != nil {
@@ -287,12 +345,7 @@ func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.B
 	}
 	defer storage.Close()
 
-	// Read the tools tarball from the request, calculating the sha256 along the way.
-	data, sha256, err := readAndHash(r)
-	if err != nil {
-		return nil, err
-	}
-	if len(data) == 0 {
+	if upload.size == 0 {
 		return nil, errors.New("no tools uploaded")
 	}
 
@@ -302,19 +355,22 @@ func (h *toolsUploadHandler) handleUpload(r io.Reader, toolsVersions []version.B
 	for _, v := range toolsVersions {
 		metadata := toolstorage.Metadata{
 			Version: v,
-			Size:    int64(len(data)),
-			SHA256:  sha256,
+			Size:    upload.size,
+			SHA256:  upload.SHA256(),
 		}
 		logger.Debugf("uploading tools %+v to storage", metadata)
-		if err := storage.AddTools(bytes.NewReader(data), metadata); err != nil {
+		if err := upload.rewind(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := storage.AddTools(upload, metadata); err != nil {
 			return nil, err
 		}
 	}
 
 	tools := &tools.Tools{
 		Version: toolsVersions[0],
-		Size:    int64(len(data)),
-		SHA256:  sha256,
+		Size:    upload.size,
+		SHA256:  upload.SHA256(),
 		URL:     common.ToolsURL(serverRoot, toolsVersions[0]),
 	}
 	return tools, nil
@@ -4,13 +4,16 @@
 package apiserver_test
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/errors"
@@ -27,7 +30,9 @@ import (
 
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/toolstorage"
+	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/version"
 )
 
@@ -323,6 +328,92 @@ func (s *toolsSuite) TestDownloadFetchesAndVerifiesHash(c *gc.C) {
 	s.assertToolsNotStored(c, tools.Version)
 }
 
+func (s *toolsSuite) TestDownloadRange(c *gc.C) {
+	tools := s.storeFakeTools(c, s.State, "abc", toolstorage.Metadata{
+		Version: version.Current,
+		Size:    3,
+		SHA256:  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	})
+	url := s.toolsURL(c, "")
+	url.Path = fmt.Sprintf("/tools/%s", tools.Version)
+	req, err := http.NewRequest("GET", url.String(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	req.Header.Set("Range", "bytes=1-")
+	resp, err := utils.GetNonValidatingHTTPClient().Do(req)
+	c.Assert(err, jc.ErrorIsNil)
+	body := assertResponse(c, resp, http.StatusPartialContent, "application/x-tar-gz")
+	c.Assert(string(body), gc.Equals, "bc")
+	c.Assert(resp.Header.Get("Content-Range"), gc.Equals, "bytes 1-2/3")
+	c.Assert(resp.Header.Get("ETag"), gc.Equals, `"`+tools.SHA256+`"`)
+}
+
+func (s *toolsSuite) storeTarball(c *gc.C, vers version.Binary, contents string) *coretools.Tools {
+	data, checksum := coretesting.TarGz(coretesting.NewTarFile("jujud", 0755, contents))
+	return s.storeFakeTools(c, s.State, string(data), toolstorage.Metadata{
+		Version: vers,
+		Size:    int64(len(data)),
+		SHA256:  checksum,
+	})
+}
+
+func (s *toolsSuite) deltaDownloadRequest(c *gc.C, to, from version.Binary) *http.Response {
+	url := s.toolsURL(c, "from="+from.String())
+	url.Path = fmt.Sprintf("/tools/%s", to)
+	resp, err := s.sendRequest(c, "", "", "GET", url.String(), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return resp
+}
+
+func (s *toolsSuite) TestDownloadDelta(c *gc.C) {
+	random := make([]byte, 256*1024)
+	rand.New(rand.NewSource(0)).Read(random)
+	contents := string(random)
+	oldVers := version.MustParseBinary("1.25.0-trusty-amd64")
+	newVers := version.MustParseBinary("1.25.1-trusty-amd64")
+	s.storeTarball(c, oldVers, contents)
+	s.storeTarball(c, newVers, contents+"new")
+
+	resp := s.deltaDownloadRequest(c, newVers, oldVers)
+	body := assertResponse(c, resp, http.StatusOK, delta.ContentType)
+	d, err := delta.Read(bytes.NewReader(body))
+	c.Assert(err, jc.ErrorIsNil)
+
+	oldDir := c.MkDir()
+	err = ioutil.WriteFile(filepath.Join(oldDir, "jujud"), []byte(contents), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	newDir := c.MkDir()
+	err = d.Apply(oldDir, newDir)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(filepath.Join(newDir, "jujud"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data) == contents+"new", jc.IsTrue)
+}
+
+func (s *toolsSuite) TestDownloadDeltaFallsBackToTarball(c *gc.C) {
+	oldVers := version.MustParseBinary("1.24.0-trusty-amd64")
+	newVers := version.MustParseBinary("1.25.1-trusty-amd64")
+	s.storeTarball(c, oldVers, "old")
+	tools := s.storeTarball(c, newVers, "new")
+	missingVers := version.MustParseBinary("1.25.0-trusty-amd64")
+
+	// No delta is sent between minor versions, or from tools which
+	// are not stored.
+	for _, from := range []version.Binary{oldVers, missingVers} {
+		resp := s.deltaDownloadRequest(c, newVers, from)
+		body := assertResponse(c, resp, http.StatusOK, "application/x-tar-gz")
+		c.Assert(body, gc.HasLen, int(tools.Size))
+	}
+}
+
+func (s *toolsSuite) TestDownloadDeltaInvalidVersion(c *gc.C) {
+	tools := s.storeTarball(c, version.Current, "new")
+	url := s.toolsURL(c, "from=bad")
+	url.Path = fmt.Sprintf("/tools/%s", tools.Version)
+	resp, err := s.sendRequest(c, "", "", "GET", url.String(), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, `invalid tools version "bad": .*`)
+}
+
 func (s *toolsSuite) storeFakeTools(c *gc.C, st *state.State, content string, metadata toolstorage.Metadata) *coretools.Tools {
 	storage, err := st.ToolsStorage()
 	c.Assert(err, jc.ErrorIsNil)
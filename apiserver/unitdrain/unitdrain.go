@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package unitdrain provides the API used by unit agents to report
+// their progress through draining: running the hooks they need to
+// run to leave their relations and stop cleanly once their unit is
+// dying, before the unit's machine is released.
+package unitdrain
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("UnitDrain", 1, NewUnitDrainAPI)
+}
+
+// UnitDrainAPI implements the UnitDrain facade.
+type UnitDrainAPI struct {
+	st   *state.State
+	auth common.Authorizer
+}
+
+// NewUnitDrainAPI creates a new server-side UnitDrain facade. It is
+// available to unit agents, which may report and read the progress
+// of their own unit, and to clients, which may read the progress of
+// any unit.
+func NewUnitDrainAPI(st *state.State, resources *common.Resources, auth common.Authorizer) (*UnitDrainAPI, error) {
+	if !auth.AuthUnitAgent() && !auth.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &UnitDrainAPI{
+		st:   st,
+		auth: auth,
+	}, nil
+}
+
+// unit returns the unit with the given tag, if the authenticated
+// entity may access it.
+func (api *UnitDrainAPI) unit(tag string, canRead bool) (*state.Unit, error) {
+	unitTag, err := names.ParseUnitTag(tag)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	if !api.auth.AuthOwner(unitTag) && !(canRead && api.auth.AuthClient()) {
+		return nil, common.ErrPerm
+	}
+	unit, err := api.st.Unit(unitTag.Id())
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	return unit, nil
+}
+
+// SetDrainStatuses records the hooks the agents of the given dying
+// units have yet to run before they stop, and whether they have
+// finished draining.
+func (api *UnitDrainAPI) SetDrainStatuses(args params.SetDrainStatusArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		unit, err := api.unit(arg.Tag, false)
+		if err == nil {
+			err = unit.SetDrainStatus(arg.Pending, arg.Drained)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// DrainStatuses returns the progress of the given units through
+// draining.
+func (api *UnitDrainAPI) DrainStatuses(args params.Entities) (params.DrainStatusResults, error) {
+	result := params.DrainStatusResults{
+		Results: make([]params.DrainStatusResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		unit, err := api.unit(entity.Tag, true)
+		if err == nil {
+			var status state.UnitDrainStatus
+			status, err = unit.DrainStatus()
+			if err == nil {
+				result.Results[i].Status = &params.UnitDrainStatus{
+					Phase:    string(status.Phase),
+					Started:  status.Started,
+					Deadline: status.Deadline,
+					Pending:  status.Pending,
+				}
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
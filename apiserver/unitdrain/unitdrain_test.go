@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package unitdrain_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/unitdrain"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type unitDrainSuite struct {
+	jujutesting.JujuConnSuite
+
+	resources *common.Resources
+	unit      *state.Unit
+	api       *unitdrain.UnitDrainAPI
+}
+
+var _ = gc.Suite(&unitDrainSuite{})
+
+func (s *unitDrainSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(_ *gc.C) { s.resources.StopAll() })
+
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	var err error
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.api, err = unitdrain.NewUnitDrainAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.unit.Tag(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *unitDrainSuite) TestNewAPIRefusesMachineAgents(c *gc.C) {
+	_, err := unitdrain.NewUnitDrainAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("1"),
+	})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *unitDrainSuite) TestSetDrainStatuses(c *gc.C) {
+	result, err := s.api.SetDrainStatuses(params.SetDrainStatusArgs{
+		Args: []params.SetDrainStatusArg{
+			{Tag: "unit-mysql-0", Pending: []string{"stop"}},
+			{Tag: "unit-mysql-1", Drained: true},
+			{Tag: "unit-foo-0"},
+			{Tag: "service-mysql"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	status, err := s.unit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Phase, gc.Equals, state.UnitDraining)
+	c.Assert(status.Pending, jc.DeepEquals, []string{"stop"})
+}
+
+func (s *unitDrainSuite) TestSetDrainStatusesRefusesClients(c *gc.C) {
+	api, err := unitdrain.NewUnitDrainAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.SetDrainStatuses(params.SetDrainStatusArgs{
+		Args: []params.SetDrainStatusArg{{Tag: "unit-mysql-0", Drained: true}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.ErrorMatches, "permission denied")
+}
+
+func (s *unitDrainSuite) TestDrainStatuses(c *gc.C) {
+	err := s.unit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.unit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+
+	api, err := unitdrain.NewUnitDrainAPI(s.State, s.resources, apiservertesting.FakeAuthorizer{
+		Tag: s.AdminUserTag(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := api.DrainStatuses(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-mysql-0"},
+			{Tag: "unit-mysql-1"},
+			{Tag: "machine-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.DrainStatusResults{
+		Results: []params.DrainStatusResult{{
+			Status: &params.UnitDrainStatus{
+				Phase:    "draining",
+				Started:  status.Started,
+				Deadline: status.Deadline,
+				Pending:  []string{"stop"},
+			},
+		}, {
+			Error: &params.Error{
+				Message: `drain status for unit "mysql/1" not found`,
+				Code:    params.CodeNotFound,
+			},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}},
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 10.
+
+package uniter
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 10, NewUniterAPIV10)
+}
+
+// UniterAPIV10 implements the API version 10, used by the uniter worker.
+type UniterAPIV10 struct {
+	UniterAPIV9
+}
+
+// NewUniterAPIV10 creates a new instance of the Uniter API, version 10.
+func NewUniterAPIV10(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV10, error) {
+	baseAPI, err := NewUniterAPIV9(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV10{
+		UniterAPIV9: *baseAPI,
+	}, nil
+}
+
+// UpgradeSeriesStatuses returns the progress of each given unit through
+// an upgrade of its machine's series.
+func (u *UniterAPIV10) UpgradeSeriesStatuses(args params.Entities) (params.UpgradeSeriesStatusResults, error) {
+	result := params.UpgradeSeriesStatusResults{
+		Results: make([]params.UpgradeSeriesStatusResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UpgradeSeriesStatusResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		status, err := unit.UpgradeSeriesStatus()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = &params.UpgradeSeriesStatus{Status: string(status)}
+	}
+	return result, nil
+}
+
+// SetUpgradeSeriesStatuses records the progress of each given unit
+// through an upgrade of its machine's series.
+func (u *UniterAPIV10) SetUpgradeSeriesStatuses(args params.SetUpgradeSeriesStatuses) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.Tag)
+		if err == nil {
+			err = unit.SetUpgradeSeriesStatus(state.UpgradeSeriesStatus(arg.Status))
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// WatchUpgradeSeries returns a NotifyWatcher for each given unit, which
+// observes the progress of upgrades of the series of the unit's
+// machine.
+func (u *UniterAPIV10) WatchUpgradeSeries(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].NotifyWatcherId, err = u.watchUpgradeSeries(unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV10) watchUpgradeSeries(unit *state.Unit) (string, error) {
+	watch, err := unit.WatchUpgradeSeries()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := <-watch.Changes(); ok {
+		return u.resources.Register(watch), nil
+	}
+	return "", watcher.EnsureErr(watch)
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type uniterV10Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV10
+}
+
+var _ = gc.Suite(&uniterV10Suite{})
+
+func (s *uniterV10Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV10, err := uniter.NewUniterAPIV10(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV10
+}
+
+func (s *uniterV10Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV10(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV10Suite) TestUpgradeSeriesStatuses(c *gc.C) {
+	err := s.machine0.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.UpgradeSeriesStatuses(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.UpgradeSeriesStatusResults{
+		Results: []params.UpgradeSeriesStatusResult{
+			{Result: &params.UpgradeSeriesStatus{Status: "prepare started"}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV10Suite) TestSetUpgradeSeriesStatuses(c *gc.C) {
+	err := s.machine0.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.SetUpgradeSeriesStatuses(params.SetUpgradeSeriesStatuses{
+		Args: []params.SetUpgradeSeriesStatus{
+			{Tag: "unit-wordpress-0", Status: "prepare completed"},
+			{Tag: "unit-mysql-0", Status: "prepare completed"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// wordpress/0 is the only unit on its machine.
+	status, _, err := s.machine0.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.UpgradeSeriesPrepareCompleted)
+}
+
+func (s *uniterV10Suite) TestWatchUpgradeSeries(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	result, err := s.uniter.WatchUpgradeSeries(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-mysql-0"},
+			{Tag: "unit-wordpress-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{NotifyWatcherId: "1"},
+		},
+	})
+
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.machine0.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 11.
+
+package uniter
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 11, NewUniterAPIV11)
+}
+
+// UniterAPIV11 implements the API version 11, used by the uniter worker.
+type UniterAPIV11 struct {
+	UniterAPIV10
+}
+
+// NewUniterAPIV11 creates a new instance of the Uniter API, version 11.
+func NewUniterAPIV11(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV11, error) {
+	baseAPI, err := NewUniterAPIV10(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV11{
+		UniterAPIV10: *baseAPI,
+	}, nil
+}
+
+// SetHookResults records the outcome, and the tail of the output, of
+// the hook the agent of each given unit ran most recently.
+func (u *UniterAPIV11) SetHookResults(args params.SetHookResultArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.Tag)
+		if err == nil {
+			err = unit.SetHookResult(state.HookResult{
+				Hook:      arg.Result.Hook,
+				Time:      arg.Result.Time,
+				Error:     arg.Result.Error,
+				Output:    arg.Result.Output,
+				Truncated: arg.Result.Truncated,
+			})
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// HookResults returns the outcome, and the tail of the output, of the
+// hook the agent of each given unit ran most recently.
+func (u *UniterAPIV11) HookResults(args params.Entities) (params.HookResultResults, error) {
+	result := params.HookResultResults{
+		Results: make([]params.HookResultResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.HookResultResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].Result, err = common.UnitHookResult(unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV11Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV11
+}
+
+var _ = gc.Suite(&uniterV11Suite{})
+
+func (s *uniterV11Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV11, err := uniter.NewUniterAPIV11(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV11
+}
+
+func (s *uniterV11Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV11(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+var failedHookResult = params.HookResult{
+	Hook:   "config-changed",
+	Time:   time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+	Error:  "exit status 1",
+	Output: "cannot write config\n",
+}
+
+func (s *uniterV11Suite) TestSetHookResults(c *gc.C) {
+	result, err := s.uniter.SetHookResults(params.SetHookResultArgs{
+		Args: []params.SetHookResultArg{
+			{Tag: "unit-wordpress-0", Result: failedHookResult},
+			{Tag: "unit-mysql-0", Result: failedHookResult},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	hookResult, err := s.wordpressUnit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hookResult, jc.DeepEquals, state.HookResult{
+		Hook:   "config-changed",
+		Time:   failedHookResult.Time,
+		Error:  "exit status 1",
+		Output: "cannot write config\n",
+	})
+}
+
+func (s *uniterV11Suite) TestHookResults(c *gc.C) {
+	err := s.wordpressUnit.SetHookResult(state.HookResult{
+		Hook:   "config-changed",
+		Time:   failedHookResult.Time,
+		Error:  "exit status 1",
+		Output: "cannot write config\n",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.HookResults(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.HookResultResults{
+		Results: []params.HookResultResult{
+			{Result: &failedHookResult},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 12.
+
+package uniter
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 12, NewUniterAPIV12)
+}
+
+// UniterAPIV12 implements the API version 12, used by the uniter worker.
+type UniterAPIV12 struct {
+	UniterAPIV11
+}
+
+// NewUniterAPIV12 creates a new instance of the Uniter API, version 12.
+func NewUniterAPIV12(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV12, error) {
+	baseAPI, err := NewUniterAPIV11(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV12{
+		UniterAPIV11: *baseAPI,
+	}, nil
+}
+
+// RelationStates returns the bookkeeping the agent of each given unit
+// keeps for each of the unit's relations.
+func (u *UniterAPIV12) RelationStates(args params.Entities) (params.UnitRelationStatesResults, error) {
+	result := params.UnitRelationStatesResults{
+		Results: make([]params.UnitRelationStatesResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.UnitRelationStatesResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].States, err = common.UnitRelationStates(unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetRelationStates records the bookkeeping the agent of each given
+// unit keeps for one of the unit's relations.
+func (u *UniterAPIV12) SetRelationStates(args params.SetUnitRelationStateArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.Tag)
+		if err == nil {
+			err = unit.SetRelationState(state.UnitRelationState{
+				RelationId:     arg.State.RelationId,
+				Members:        arg.State.Members,
+				ChangedPending: arg.State.ChangedPending,
+			})
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// RemoveRelationStates removes the bookkeeping the agent of each given
+// unit keeps for one of the unit's relations.
+func (u *UniterAPIV12) RemoveRelationStates(args params.RemoveUnitRelationStateArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.Tag)
+		if err == nil {
+			err = unit.RemoveRelationState(arg.RelationId)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
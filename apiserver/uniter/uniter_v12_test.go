@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV12Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV12
+}
+
+var _ = gc.Suite(&uniterV12Suite{})
+
+func (s *uniterV12Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV12, err := uniter.NewUniterAPIV12(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV12
+}
+
+func (s *uniterV12Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV12(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+var joinedRelationState = params.UnitRelationState{
+	RelationId:     3,
+	Members:        map[string]int64{"mysql/0": 1},
+	ChangedPending: "mysql/0",
+}
+
+func (s *uniterV12Suite) TestSetRelationStates(c *gc.C) {
+	result, err := s.uniter.SetRelationStates(params.SetUnitRelationStateArgs{
+		Args: []params.SetUnitRelationStateArg{
+			{Tag: "unit-wordpress-0", State: joinedRelationState},
+			{Tag: "unit-mysql-0", State: joinedRelationState},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	states, err := s.wordpressUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, []state.UnitRelationState{{
+		RelationId:     3,
+		Members:        map[string]int64{"mysql/0": 1},
+		ChangedPending: "mysql/0",
+	}})
+}
+
+func (s *uniterV12Suite) TestRelationStates(c *gc.C) {
+	err := s.wordpressUnit.SetRelationState(state.UnitRelationState{
+		RelationId:     3,
+		Members:        map[string]int64{"mysql/0": 1},
+		ChangedPending: "mysql/0",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.RelationStates(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.UnitRelationStatesResults{
+		Results: []params.UnitRelationStatesResult{
+			{States: []params.UnitRelationState{joinedRelationState}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV12Suite) TestRemoveRelationStates(c *gc.C) {
+	err := s.wordpressUnit.SetRelationState(state.UnitRelationState{RelationId: 3})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.RemoveRelationStates(params.RemoveUnitRelationStateArgs{
+		Args: []params.RemoveUnitRelationStateArg{
+			{Tag: "unit-wordpress-0", RelationId: 3},
+			{Tag: "unit-mysql-0", RelationId: 3},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	states, err := s.wordpressUnit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+}
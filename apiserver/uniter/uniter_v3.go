@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 3.
+
+package uniter
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 3, NewUniterAPIV3)
+}
+
+// UniterAPIV3 implements the API version 3, used by the uniter worker.
+type UniterAPIV3 struct {
+	UniterAPIV2
+}
+
+// NewUniterAPIV3 creates a new instance of the Uniter API, version 3.
+func NewUniterAPIV3(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV3, error) {
+	baseAPI, err := NewUniterAPIV2(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV3{
+		UniterAPIV2: *baseAPI,
+	}, nil
+}
+
+// AppendActionOutput records chunks of output written by running
+// Actions, so that the output may be followed before the Actions
+// complete.
+func (u *UniterAPIV3) AppendActionOutput(args params.ActionOutputArgs) (params.ErrorResults, error) {
+	nothing := params.ErrorResults{}
+
+	actionFn, err := u.authAndActionFromTagFn()
+	if err != nil {
+		return nothing, err
+	}
+
+	results := params.ErrorResults{Results: make([]params.ErrorResult, len(args.Output))}
+
+	for i, arg := range args.Output {
+		action, err := actionFn(arg.ActionTag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		var stream string
+		switch arg.Stream {
+		case params.ActionStdout:
+			stream = state.ActionStdout
+		case params.ActionStderr:
+			stream = state.ActionStderr
+		default:
+			results.Results[i].Error = common.ServerError(errors.NotValidf("action output stream %q", arg.Stream))
+			continue
+		}
+		if err := action.AppendOutput(stream, arg.Data); err != nil {
+			results.Results[i].Error = common.ServerError(err)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV3Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV3
+}
+
+var _ = gc.Suite(&uniterV3Suite{})
+
+func (s *uniterV3Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV3, err := uniter.NewUniterAPIV3(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV3
+}
+
+func (s *uniterV3Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV3(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV3Suite) TestAppendActionOutput(c *gc.C) {
+	running, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	running, err = running.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err := s.wordpressUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	other, err := s.mysqlUnit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	other, err = other.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.AppendActionOutput(params.ActionOutputArgs{
+		Output: []params.ActionOutputArg{
+			{ActionTag: running.ActionTag().String(), Stream: params.ActionStdout, Data: "one\n"},
+			{ActionTag: running.ActionTag().String(), Stream: params.ActionStderr, Data: "two\n"},
+			{ActionTag: running.ActionTag().String(), Stream: "stdin", Data: "three\n"},
+			{ActionTag: pending.ActionTag().String(), Stream: params.ActionStdout, Data: "four\n"},
+			{ActionTag: other.ActionTag().String(), Stream: params.ActionStdout, Data: "five\n"},
+			{ActionTag: "unit-wordpress-0", Stream: params.ActionStdout, Data: "six\n"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{Error: nil},
+			{Error: nil},
+			{Error: &params.Error{Message: `action output stream "stdin" not valid`}},
+			{Error: &params.Error{Message: `action "` + pending.Id() + `" is not running`}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: &params.Error{Message: `"unit-wordpress-0" is not a valid action tag`}},
+		},
+	})
+
+	chunks, err := running.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 2)
+	c.Assert(chunks[0].Stream, gc.Equals, state.ActionStdout)
+	c.Assert(chunks[0].Data, gc.Equals, "one\n")
+	c.Assert(chunks[1].Stream, gc.Equals, state.ActionStderr)
+	c.Assert(chunks[1].Data, gc.Equals, "two\n")
+
+	chunks, err = other.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 0)
+}
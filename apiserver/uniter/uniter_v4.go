@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 4.
+
+package uniter
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 4, NewUniterAPIV4)
+}
+
+// UniterAPIV4 implements the API version 4, used by the uniter worker.
+type UniterAPIV4 struct {
+	UniterAPIV3
+	unitStatusGetter *common.StatusGetter
+}
+
+// NewUniterAPIV4 creates a new instance of the Uniter API, version 4.
+func NewUniterAPIV4(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV4, error) {
+	baseAPI, err := NewUniterAPIV3(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV4{
+		UniterAPIV3:      *baseAPI,
+		unitStatusGetter: common.NewStatusGetter(st, baseAPI.accessUnit),
+	}, nil
+}
+
+// UnitStatus returns the workload status of each given unit, including
+// any structured data the unit's charm has attached to it.
+func (u *UniterAPIV4) UnitStatus(args params.Entities) (params.StatusResults, error) {
+	return u.unitStatusGetter.Status(args)
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV4Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV4
+}
+
+var _ = gc.Suite(&uniterV4Suite{})
+
+func (s *uniterV4Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV4, err := uniter.NewUniterAPIV4(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV4
+}
+
+func (s *uniterV4Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV4(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV4Suite) TestSetUnitStatusWithData(c *gc.C) {
+	data := map[string]interface{}{"connections": 42.0, "role": "master"}
+	result, err := s.uniter.SetUnitStatus(params.SetStatus{
+		Entities: []params.EntityStatus{
+			{Tag: "unit-wordpress-0", Status: params.StatusRunning, Info: "ready", Data: data},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), jc.ErrorIsNil)
+
+	status, info, got, err := s.wordpressUnit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.StatusRunning)
+	c.Assert(info, gc.Equals, "ready")
+	c.Assert(got, jc.DeepEquals, data)
+}
+
+func (s *uniterV4Suite) TestUnitStatus(c *gc.C) {
+	err := s.wordpressUnit.SetStatus(state.StatusBlocked, "need db", map[string]interface{}{
+		"missing": "mysql",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysqlUnit.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.UnitStatus(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+			{Tag: "unit-foo-42"},
+			{Tag: "service-wordpress"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.StatusResults{
+		Results: []params.StatusResult{{
+			Id:     "wordpress/0",
+			Status: params.StatusBlocked,
+			Info:   "need db",
+			Data:   map[string]interface{}{"missing": "mysql"},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}},
+	})
+}
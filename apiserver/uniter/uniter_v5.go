@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 5.
+
+package uniter
+
+import (
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 5, NewUniterAPIV5)
+}
+
+// UniterAPIV5 implements the API version 5, used by the uniter worker.
+type UniterAPIV5 struct {
+	UniterAPIV4
+}
+
+// NewUniterAPIV5 creates a new instance of the Uniter API, version 5.
+func NewUniterAPIV5(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV5, error) {
+	baseAPI, err := NewUniterAPIV4(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV5{
+		UniterAPIV4: *baseAPI,
+	}, nil
+}
+
+// NetworkInfo returns, for each given unit endpoint binding, the
+// addresses the unit is reached on and connects from. Endpoints are
+// not yet bound to particular networks, so every binding of a unit
+// reports the addresses of the unit's machine on the cloud's local
+// network, the unit's private address first.
+func (u *UniterAPIV5) NetworkInfo(args params.UnitBindings) (params.NetworkInfoResults, error) {
+	result := params.NetworkInfoResults{
+		Results: make([]params.NetworkInfoResult, len(args.Bindings)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NetworkInfoResults{}, err
+	}
+	for i, binding := range args.Bindings {
+		tag, err := names.ParseUnitTag(binding.UnitTag)
+		if err != nil || !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		info, err := u.networkInfo(tag, binding.BindingName)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Info = info
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV5) networkInfo(tag names.UnitTag, bindingName string) (params.NetworkInfo, error) {
+	unit, err := u.getUnit(tag)
+	if err != nil {
+		return params.NetworkInfo{}, err
+	}
+	service, err := unit.Service()
+	if err != nil {
+		return params.NetworkInfo{}, err
+	}
+	if _, err := service.Endpoint(bindingName); err != nil {
+		return params.NetworkInfo{}, errors.NotFoundf("binding %q", bindingName)
+	}
+	privateAddress, ok := unit.PrivateAddress()
+	if !ok {
+		return params.NetworkInfo{}, common.NoAddressSetError(tag, "private")
+	}
+	machineId, err := unit.AssignedMachineId()
+	if err != nil {
+		return params.NetworkInfo{}, err
+	}
+	machine, err := u.uniterBaseAPI.st.Machine(machineId)
+	if err != nil {
+		return params.NetworkInfo{}, err
+	}
+	info := params.NetworkInfo{
+		IngressAddresses: []string{privateAddress},
+		EgressSubnets:    []string{hostCIDR(privateAddress)},
+	}
+	for _, addr := range machine.Addresses() {
+		if addr.Scope != network.ScopeCloudLocal || addr.Value == privateAddress {
+			continue
+		}
+		info.IngressAddresses = append(info.IngressAddresses, addr.Value)
+	}
+	return info, nil
+}
+
+// hostCIDR returns the CIDR holding only the given address, or the
+// address itself if it is not an IP address.
+func hostCIDR(address string) string {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return address
+	case ip.To4() != nil:
+		return address + "/32"
+	default:
+		return address + "/128"
+	}
+}
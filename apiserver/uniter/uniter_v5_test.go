@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+)
+
+type uniterV5Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV5
+}
+
+var _ = gc.Suite(&uniterV5Suite{})
+
+func (s *uniterV5Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV5, err := uniter.NewUniterAPIV5(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV5
+}
+
+func (s *uniterV5Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV5(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV5Suite) TestNetworkInfo(c *gc.C) {
+	err := s.machine0.SetAddresses(
+		network.NewAddress("1.2.3.4", network.ScopeCloudLocal),
+		network.NewAddress("10.0.0.4", network.ScopeCloudLocal),
+		network.NewAddress("8.8.8.8", network.ScopePublic),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.NetworkInfo(params.UnitBindings{
+		Bindings: []params.UnitBinding{
+			{UnitTag: "unit-wordpress-0", BindingName: "db"},
+			{UnitTag: "unit-wordpress-0", BindingName: "juju-info"},
+			{UnitTag: "unit-wordpress-0", BindingName: "nonsense"},
+			{UnitTag: "unit-mysql-0", BindingName: "server"},
+			{UnitTag: "unit-foo-42", BindingName: "db"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	expected := params.NetworkInfo{
+		IngressAddresses: []string{"1.2.3.4", "10.0.0.4"},
+		EgressSubnets:    []string{"1.2.3.4/32"},
+	}
+	c.Assert(result, jc.DeepEquals, params.NetworkInfoResults{
+		Results: []params.NetworkInfoResult{
+			{Info: expected},
+			{Info: expected},
+			{Error: &params.Error{Code: params.CodeNotFound, Message: `binding "nonsense" not found`}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV5Suite) TestNetworkInfoNoAddress(c *gc.C) {
+	result, err := s.uniter.NetworkInfo(params.UnitBindings{
+		Bindings: []params.UnitBinding{
+			{UnitTag: "unit-wordpress-0", BindingName: "db"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NetworkInfoResults{
+		Results: []params.NetworkInfoResult{{
+			Error: &params.Error{
+				Code:    params.CodeNoAddressSet,
+				Message: `"unit-wordpress-0" has no private address set`,
+			},
+		}},
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 6.
+
+package uniter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 6, NewUniterAPIV6)
+}
+
+// UniterAPIV6 implements the API version 6, used by the uniter worker.
+type UniterAPIV6 struct {
+	UniterAPIV5
+}
+
+// NewUniterAPIV6 creates a new instance of the Uniter API, version 6.
+func NewUniterAPIV6(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV6, error) {
+	baseAPI, err := NewUniterAPIV5(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV6{
+		UniterAPIV5: *baseAPI,
+	}, nil
+}
+
+// SetWorkloadVersion records, for each given unit, the version of the
+// software it runs.
+func (u *UniterAPIV6) SetWorkloadVersion(args params.EntityWorkloadVersions) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		err = common.ErrPerm
+		if canAccess(tag) {
+			var unit *state.Unit
+			unit, err = u.getUnit(tag)
+			if err == nil {
+				err = unit.SetWorkloadVersion(entity.WorkloadVersion)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
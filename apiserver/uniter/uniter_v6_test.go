@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV6Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV6
+}
+
+var _ = gc.Suite(&uniterV6Suite{})
+
+func (s *uniterV6Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV6, err := uniter.NewUniterAPIV6(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV6
+}
+
+func (s *uniterV6Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV6(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV6Suite) TestSetWorkloadVersion(c *gc.C) {
+	c.Assert(s.wordpressUnit.WorkloadVersion(), gc.Equals, "")
+
+	result, err := s.uniter.SetWorkloadVersion(params.EntityWorkloadVersions{
+		Entities: []params.EntityWorkloadVersion{
+			{Tag: "unit-mysql-0", WorkloadVersion: "5.7.9"},
+			{Tag: "unit-wordpress-0", WorkloadVersion: "4.3.1"},
+			{Tag: "unit-foo-42", WorkloadVersion: "1.0"},
+			{Tag: "service-wordpress", WorkloadVersion: "1.0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{apiservertesting.ErrUnauthorized},
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = s.wordpressUnit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.wordpressUnit.WorkloadVersion(), gc.Equals, "4.3.1")
+}
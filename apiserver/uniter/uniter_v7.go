@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 7.
+
+package uniter
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 7, NewUniterAPIV7)
+}
+
+// UniterAPIV7 implements the API version 7, used by the uniter worker.
+type UniterAPIV7 struct {
+	UniterAPIV6
+}
+
+// NewUniterAPIV7 creates a new instance of the Uniter API, version 7.
+func NewUniterAPIV7(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV7, error) {
+	baseAPI, err := NewUniterAPIV6(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV7{
+		UniterAPIV6: *baseAPI,
+	}, nil
+}
+
+// GoalStates returns, for each given unit, the goal state of its
+// service: all the units the service is meant to have and, for each
+// of its endpoints, the services and units it is meant to be related
+// to, whether or not they have been deployed and joined yet.
+func (u *UniterAPIV7) GoalStates(args params.Entities) (params.GoalStateResults, error) {
+	result := params.GoalStateResults{
+		Results: make([]params.GoalStateResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.GoalStateResults{}, err
+	}
+	for i, entity := range args.Entities {
+		tag, err := names.ParseUnitTag(entity.Tag)
+		if err != nil || !canAccess(tag) {
+			result.Results[i].Error = common.ServerError(common.ErrPerm)
+			continue
+		}
+		unit, err := u.getUnit(tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		goalState, err := u.goalState(unit)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = goalState
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV7) goalState(unit *state.Unit) (*params.GoalState, error) {
+	service, err := unit.Service()
+	if err != nil {
+		return nil, err
+	}
+	units, err := unitsGoalState(service)
+	if err != nil {
+		return nil, err
+	}
+	relations, err := service.Relations()
+	if err != nil {
+		return nil, err
+	}
+	goalState := &params.GoalState{
+		Units:     units,
+		Relations: make(map[string]params.UnitsGoalState),
+	}
+	for _, rel := range relations {
+		if rel.Life() == state.Dead {
+			continue
+		}
+		ep, err := rel.Endpoint(service.Name())
+		if err != nil {
+			return nil, err
+		}
+		related, err := rel.RelatedEndpoints(service.Name())
+		if err != nil {
+			return nil, err
+		}
+		states, ok := goalState.Relations[ep.Name]
+		if !ok {
+			states = make(params.UnitsGoalState)
+			goalState.Relations[ep.Name] = states
+		}
+		for _, relatedEp := range related {
+			relatedService, err := u.uniterBaseAPI.st.Service(relatedEp.ServiceName)
+			if err != nil {
+				return nil, err
+			}
+			relatedUnits, err := unitsGoalState(relatedService)
+			if err != nil {
+				return nil, err
+			}
+			for name, status := range relatedUnits {
+				states[name] = status
+			}
+			states[relatedService.Name()] = params.GoalStateStatus{Status: relationGoalStatus(rel)}
+		}
+	}
+	return goalState, nil
+}
+
+// unitsGoalState returns the goal state statuses of the units of the
+// given service that are not dead. The status of a live unit is its
+// workload status; a dying unit is reported as "dying".
+func unitsGoalState(service *state.Service) (params.UnitsGoalState, error) {
+	units, err := service.AllUnits()
+	if err != nil {
+		return nil, err
+	}
+	result := make(params.UnitsGoalState)
+	for _, unit := range units {
+		switch unit.Life() {
+		case state.Dead:
+			continue
+		case state.Dying:
+			result[unit.Name()] = params.GoalStateStatus{Status: "dying"}
+			continue
+		}
+		status, _, _, err := unit.Status()
+		if err != nil {
+			return nil, err
+		}
+		result[unit.Name()] = params.GoalStateStatus{Status: string(status)}
+	}
+	return result, nil
+}
+
+// relationGoalStatus returns the goal state status of a relation.
+func relationGoalStatus(rel *state.Relation) string {
+	if rel.Life() == state.Alive {
+		return "joined"
+	}
+	return "dying"
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+	jujuFactory "github.com/juju/juju/testing/factory"
+)
+
+type uniterV7Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV7
+}
+
+var _ = gc.Suite(&uniterV7Suite{})
+
+func (s *uniterV7Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV7, err := uniter.NewUniterAPIV7(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV7
+}
+
+func (s *uniterV7Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV7(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV7Suite) TestGoalStates(c *gc.C) {
+	err := s.wordpressUnit.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysqlUnit.SetStatus(state.StatusWaiting, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	// A unit that has not been started yet is part of the goal state.
+	s.Factory.MakeUnit(c, &jujuFactory.UnitParams{Service: s.wordpress})
+	s.addRelation(c, "wordpress", "mysql")
+
+	result, err := s.uniter.GoalStates(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+			{Tag: "service-wordpress"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.GoalStateResults{
+		Results: []params.GoalStateResult{
+			{Result: &params.GoalState{
+				Units: params.UnitsGoalState{
+					"wordpress/0": {Status: "running"},
+					"wordpress/1": {Status: "busy"},
+				},
+				Relations: map[string]params.UnitsGoalState{
+					"db": {
+						"mysql":   {Status: "joined"},
+						"mysql/0": {Status: "waiting"},
+					},
+				},
+			}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+}
+
+func (s *uniterV7Suite) TestGoalStatesDying(c *gc.C) {
+	rel := s.addRelation(c, "wordpress", "mysql")
+	relUnit, err := rel.Unit(s.mysqlUnit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = relUnit.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.GoalStates(params.Entities{
+		Entities: []params.Entity{{Tag: "unit-wordpress-0"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[0].Result.Relations, jc.DeepEquals, map[string]params.UnitsGoalState{
+		"db": {
+			"mysql":   {Status: "dying"},
+			"mysql/0": {Status: "busy"},
+		},
+	})
+}
@@ -0,0 +1,262 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 8.
+
+package uniter
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 8, NewUniterAPIV8)
+}
+
+// UniterAPIV8 implements the API version 8, used by the uniter worker.
+type UniterAPIV8 struct {
+	UniterAPIV7
+}
+
+// NewUniterAPIV8 creates a new instance of the Uniter API, version 8.
+func NewUniterAPIV8(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV8, error) {
+	baseAPI, err := NewUniterAPIV7(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV8{
+		UniterAPIV7: *baseAPI,
+	}, nil
+}
+
+// CreateSecrets creates secrets owned by the services of the given
+// units, and returns their ids.
+func (u *UniterAPIV8) CreateSecrets(args params.CreateSecretArgs) (params.StringResults, error) {
+	result := params.StringResults{
+		Results: make([]params.StringResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.StringResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.UnitTag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		secret, err := u.uniterBaseAPI.st.AddSecret(state.AddSecretParams{
+			Owner:          unit.ServiceName(),
+			Label:          arg.Label,
+			Data:           arg.Data,
+			RotateInterval: arg.RotateInterval,
+		})
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = secret.Id()
+	}
+	return result, nil
+}
+
+// UpdateSecrets replaces the values of secrets owned by the services
+// of the given units.
+func (u *UniterAPIV8) UpdateSecrets(args params.UpdateSecretArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		secret, err := u.ownedSecret(canAccess, arg.UnitTag, arg.SecretId)
+		if err == nil {
+			err = secret.Update(arg.Data)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SecretValues returns the values of the given secrets, if the
+// services of the given units may read them.
+func (u *UniterAPIV8) SecretValues(args params.SecretArgs) (params.SecretValueResults, error) {
+	result := params.SecretValueResults{
+		Results: make([]params.SecretValueResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.SecretValueResults{}, err
+	}
+	for i, arg := range args.Args {
+		data, err := u.secretValue(canAccess, arg.UnitTag, arg.SecretId)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Data = data
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV8) secretValue(canAccess common.AuthFunc, unitTag, secretId string) (map[string]string, error) {
+	unit, err := u.accessibleUnit(canAccess, unitTag)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := u.uniterBaseAPI.st.Secret(secretId)
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	canRead, err := secret.CanRead(unit.ServiceName())
+	if err != nil {
+		return nil, err
+	} else if !canRead {
+		return nil, common.ErrPerm
+	}
+	return secret.Data()
+}
+
+// GrantSecrets allows the services at the other end of the given
+// relations to read secrets owned by the services of the given units.
+func (u *UniterAPIV8) GrantSecrets(args params.GrantSecretArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		secret, err := u.ownedSecret(canAccess, arg.UnitTag, arg.SecretId)
+		if err == nil {
+			var rel *state.Relation
+			rel, err = u.ownedRelation(secret.Owner(), arg.RelationTag)
+			if err == nil {
+				err = secret.Grant(rel)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SecretRotations returns, for each given unit, when the secrets owned
+// by its service are next due rotation.
+func (u *UniterAPIV8) SecretRotations(args params.Entities) (params.SecretRotationsResults, error) {
+	result := params.SecretRotationsResults{
+		Results: make([]params.SecretRotationsResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.SecretRotationsResults{}, err
+	}
+	for i, entity := range args.Entities {
+		rotations, err := u.secretRotations(canAccess, entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Rotations = rotations
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV8) secretRotations(canAccess common.AuthFunc, unitTag string) ([]params.SecretRotation, error) {
+	unit, err := u.accessibleUnit(canAccess, unitTag)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := u.uniterBaseAPI.st.ServiceSecrets(unit.ServiceName())
+	if err != nil {
+		return nil, err
+	}
+	var rotations []params.SecretRotation
+	for _, secret := range secrets {
+		if next, ok := secret.NextRotateTime(); ok {
+			rotations = append(rotations, params.SecretRotation{
+				SecretId:       secret.Id(),
+				NextRotateTime: next,
+			})
+		}
+	}
+	return rotations, nil
+}
+
+// SecretsRotated records that the given secrets, owned by the services
+// of the given units, have been dealt with being due rotation.
+func (u *UniterAPIV8) SecretsRotated(args params.SecretArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		secret, err := u.ownedSecret(canAccess, arg.UnitTag, arg.SecretId)
+		if err == nil {
+			err = secret.Rotated()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// accessibleUnit returns the unit with the given tag, if the
+// authenticated agent may access it.
+func (u *UniterAPIV8) accessibleUnit(canAccess common.AuthFunc, unitTag string) (*state.Unit, error) {
+	tag, err := names.ParseUnitTag(unitTag)
+	if err != nil || !canAccess(tag) {
+		return nil, common.ErrPerm
+	}
+	return u.getUnit(tag)
+}
+
+// ownedSecret returns the secret with the given id, if it is owned by
+// the service of the given unit.
+func (u *UniterAPIV8) ownedSecret(canAccess common.AuthFunc, unitTag, secretId string) (*state.Secret, error) {
+	unit, err := u.accessibleUnit(canAccess, unitTag)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := u.uniterBaseAPI.st.Secret(secretId)
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	if secret.Owner() != unit.ServiceName() {
+		return nil, common.ErrPerm
+	}
+	return secret, nil
+}
+
+// ownedRelation returns the relation with the given tag, if the named
+// service takes part in it.
+func (u *UniterAPIV8) ownedRelation(serviceName, relationTag string) (*state.Relation, error) {
+	tag, err := names.ParseRelationTag(relationTag)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	rel, err := u.uniterBaseAPI.st.KeyRelation(tag.Id())
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := rel.Endpoint(serviceName); err != nil {
+		return nil, common.ErrPerm
+	}
+	return rel, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+)
+
+type uniterV8Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV8
+}
+
+var _ = gc.Suite(&uniterV8Suite{})
+
+func (s *uniterV8Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV8, err := uniter.NewUniterAPIV8(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV8
+}
+
+func (s *uniterV8Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV8(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV8Suite) addSecret(c *gc.C, owner string) *state.Secret {
+	secret, err := s.State.AddSecret(state.AddSecretParams{
+		Owner:          owner,
+		Data:           map[string]string{"password": "sekrit"},
+		RotateInterval: time.Hour,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return secret
+}
+
+func (s *uniterV8Suite) TestCreateSecrets(c *gc.C) {
+	result, err := s.uniter.CreateSecrets(params.CreateSecretArgs{
+		Args: []params.CreateSecretArg{
+			{UnitTag: "unit-wordpress-0", Label: "admin", Data: map[string]string{"password": "sekrit"}},
+			{UnitTag: "unit-mysql-0", Data: map[string]string{"password": "sekrit"}},
+			{UnitTag: "service-wordpress", Data: map[string]string{"password": "sekrit"}},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 3)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	c.Assert(result.Results[1:], jc.DeepEquals, []params.StringResult{
+		{Error: apiservertesting.ErrUnauthorized},
+		{Error: apiservertesting.ErrUnauthorized},
+	})
+
+	secret, err := s.State.Secret(result.Results[0].Result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.Owner(), gc.Equals, "wordpress")
+	c.Assert(secret.Label(), gc.Equals, "admin")
+	data, err := secret.Data()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, map[string]string{"password": "sekrit"})
+}
+
+func (s *uniterV8Suite) TestUpdateSecrets(c *gc.C) {
+	owned := s.addSecret(c, "wordpress")
+	other := s.addSecret(c, "mysql")
+
+	result, err := s.uniter.UpdateSecrets(params.UpdateSecretArgs{
+		Args: []params.UpdateSecretArg{
+			{UnitTag: "unit-wordpress-0", SecretId: owned.Id(), Data: map[string]string{"password": "new"}},
+			{UnitTag: "unit-wordpress-0", SecretId: other.Id(), Data: map[string]string{"password": "new"}},
+			{UnitTag: "unit-wordpress-0", SecretId: "no-such-secret", Data: map[string]string{"password": "new"}},
+			{UnitTag: "unit-mysql-0", SecretId: other.Id(), Data: map[string]string{"password": "new"}},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	err = owned.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owned.Revision(), gc.Equals, 2)
+	err = other.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other.Revision(), gc.Equals, 1)
+}
+
+func (s *uniterV8Suite) TestGrantAndReadSecrets(c *gc.C) {
+	owned := s.addSecret(c, "wordpress")
+	other := s.addSecret(c, "mysql")
+	rel := s.addRelation(c, "wordpress", "mysql")
+
+	args := params.SecretArgs{
+		Args: []params.SecretArg{
+			{UnitTag: "unit-wordpress-0", SecretId: owned.Id()},
+			{UnitTag: "unit-wordpress-0", SecretId: other.Id()},
+			{UnitTag: "unit-mysql-0", SecretId: other.Id()},
+		},
+	}
+	result, err := s.uniter.SecretValues(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.SecretValueResults{
+		Results: []params.SecretValueResult{
+			{Data: map[string]string{"password": "sekrit"}},
+			{Error: apiservertesting.ErrUnauthorized},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	// Only the owner can grant access to a secret.
+	err = other.Grant(rel)
+	c.Assert(err, jc.ErrorIsNil)
+	grantResult, err := s.uniter.GrantSecrets(params.GrantSecretArgs{
+		Args: []params.GrantSecretArg{
+			{UnitTag: "unit-wordpress-0", SecretId: owned.Id(), RelationTag: rel.Tag().String()},
+			{UnitTag: "unit-wordpress-0", SecretId: other.Id(), RelationTag: rel.Tag().String()},
+			{UnitTag: "unit-wordpress-0", SecretId: owned.Id(), RelationTag: "relation-foo.bar#baz.qux"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(grantResult, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	result, err = s.uniter.SecretValues(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.SecretValueResults{
+		Results: []params.SecretValueResult{
+			{Data: map[string]string{"password": "sekrit"}},
+			{Data: map[string]string{"password": "sekrit"}},
+			{Error: apiservertesting.ErrUnauthorized},
+		},
+	})
+	err = owned.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	canRead, err := owned.CanRead("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsTrue)
+}
+
+func (s *uniterV8Suite) TestSecretRotations(c *gc.C) {
+	owned := s.addSecret(c, "wordpress")
+	s.addSecret(c, "mysql")
+	_, err := s.State.AddSecret(state.AddSecretParams{
+		Owner: "wordpress",
+		Data:  map[string]string{"never": "rotated"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.SecretRotations(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 2)
+	c.Assert(result.Results[0].Error, gc.IsNil)
+	err = owned.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	next, _ := owned.NextRotateTime()
+	c.Assert(result.Results[0].Rotations, gc.HasLen, 1)
+	c.Assert(result.Results[0].Rotations[0].SecretId, gc.Equals, owned.Id())
+	c.Assert(result.Results[0].Rotations[0].NextRotateTime.Equal(next), jc.IsTrue)
+	c.Assert(result.Results[1].Error, jc.DeepEquals, apiservertesting.ErrUnauthorized)
+}
+
+func (s *uniterV8Suite) TestSecretsRotated(c *gc.C) {
+	owned := s.addSecret(c, "wordpress")
+	other := s.addSecret(c, "mysql")
+	err := owned.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	before, _ := owned.NextRotateTime()
+
+	result, err := s.uniter.SecretsRotated(params.SecretArgs{
+		Args: []params.SecretArg{
+			{UnitTag: "unit-wordpress-0", SecretId: owned.Id()},
+			{UnitTag: "unit-wordpress-0", SecretId: other.Id()},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+	err = owned.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	after, _ := owned.NextRotateTime()
+	c.Assert(after.Before(before), jc.IsFalse)
+}
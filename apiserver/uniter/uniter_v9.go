@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The uniter package implements the API interface used by the uniter
+// worker. This file contains the API facade version 9.
+
+package uniter
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("Uniter", 9, NewUniterAPIV9)
+}
+
+// UniterAPIV9 implements the API version 9, used by the uniter worker.
+type UniterAPIV9 struct {
+	UniterAPIV8
+}
+
+// NewUniterAPIV9 creates a new instance of the Uniter API, version 9.
+func NewUniterAPIV9(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UniterAPIV9, error) {
+	baseAPI, err := NewUniterAPIV8(st, resources, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &UniterAPIV9{
+		UniterAPIV8: *baseAPI,
+	}, nil
+}
+
+// SetHookQueues records the hooks the agents of the given units have
+// yet to run.
+func (u *UniterAPIV9) SetHookQueues(args params.SetHookQueueArgs) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.ErrorResults{}, err
+	}
+	for i, arg := range args.Args {
+		unit, err := u.accessibleUnit(canAccess, arg.Tag)
+		if err == nil {
+			var pending *state.QueuedHook
+			if arg.Pending != nil {
+				hook := fromParamsQueuedHook(*arg.Pending)
+				pending = &hook
+			}
+			queued := make([]state.QueuedHook, len(arg.Queued))
+			for i, hook := range arg.Queued {
+				queued[i] = fromParamsQueuedHook(hook)
+			}
+			err = unit.SetHookQueue(pending, queued)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// HookQueues returns the hooks the agents of the given units last
+// reported they have yet to run, and those of them they have been
+// asked not to run.
+func (u *UniterAPIV9) HookQueues(args params.Entities) (params.HookQueueResults, error) {
+	result := params.HookQueueResults{
+		Results: make([]params.HookQueueResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.HookQueueResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i], err = common.UnitHookQueue(unit)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+// WatchHookQueues returns a NotifyWatcher for each given unit, which
+// observes changes to the hooks recorded as queued for the unit, and
+// to requests to drop them.
+func (u *UniterAPIV9) WatchHookQueues(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	canAccess, err := u.accessUnit()
+	if err != nil {
+		return params.NotifyWatchResults{}, err
+	}
+	for i, entity := range args.Entities {
+		unit, err := u.accessibleUnit(canAccess, entity.Tag)
+		if err == nil {
+			result.Results[i].NotifyWatcherId, err = u.watchHookQueue(unit)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+func (u *UniterAPIV9) watchHookQueue(unit *state.Unit) (string, error) {
+	watch := unit.WatchHookQueue()
+	if _, ok := <-watch.Changes(); ok {
+		return u.resources.Register(watch), nil
+	}
+	return "", watcher.EnsureErr(watch)
+}
+
+func fromParamsQueuedHook(hook params.QueuedHook) state.QueuedHook {
+	return state.QueuedHook{
+		Kind:       hook.Kind,
+		RelationId: hook.RelationId,
+		RemoteUnit: hook.RemoteUnit,
+	}
+}
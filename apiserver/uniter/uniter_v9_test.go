@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/uniter"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type uniterV9Suite struct {
+	uniterBaseSuite
+	uniter *uniter.UniterAPIV9
+}
+
+var _ = gc.Suite(&uniterV9Suite{})
+
+func (s *uniterV9Suite) SetUpTest(c *gc.C) {
+	s.uniterBaseSuite.setUpTest(c)
+
+	uniterAPIV9, err := uniter.NewUniterAPIV9(
+		s.State,
+		s.resources,
+		s.authorizer,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	s.uniter = uniterAPIV9
+}
+
+func (s *uniterV9Suite) TestUniterFailsWithNonUnitAgentUser(c *gc.C) {
+	factory := func(st *state.State, res *common.Resources, auth common.Authorizer) error {
+		_, err := uniter.NewUniterAPIV9(st, res, auth)
+		return err
+	}
+	s.testUniterFailsWithNonUnitAgentUser(c, factory)
+}
+
+func (s *uniterV9Suite) TestSetHookQueues(c *gc.C) {
+	pending := params.QueuedHook{Kind: "config-changed"}
+	queued := []params.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+	}
+	result, err := s.uniter.SetHookQueues(params.SetHookQueueArgs{
+		Args: []params.SetHookQueueArg{
+			{Tag: "unit-wordpress-0", Pending: &pending, Queued: queued},
+			{Tag: "unit-mysql-0"},
+			{Tag: "unit-foo-42"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ErrorResults{
+		Results: []params.ErrorResult{
+			{nil},
+			{apiservertesting.ErrUnauthorized},
+			{apiservertesting.ErrUnauthorized},
+		},
+	})
+
+	queue, err := s.wordpressUnit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue, jc.DeepEquals, state.HookQueue{
+		Pending: &state.QueuedHook{Kind: "config-changed"},
+		Queued: []state.QueuedHook{
+			{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+		},
+	})
+}
+
+func (s *uniterV9Suite) TestHookQueues(c *gc.C) {
+	err := s.wordpressUnit.SetHookQueue(nil, []state.QueuedHook{
+		{Kind: "relation-joined", RelationId: 0, RemoteUnit: "mysql/0"},
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/1"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.wordpressUnit.DropQueuedHook("relation-changed:0:mysql/1")
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.uniter.HookQueues(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-wordpress-0"},
+			{Tag: "unit-mysql-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	dropped := params.QueuedHook{
+		Id:         "relation-changed:0:mysql/1",
+		Kind:       "relation-changed",
+		RelationId: 0,
+		RemoteUnit: "mysql/1",
+	}
+	c.Assert(result, jc.DeepEquals, params.HookQueueResults{
+		Results: []params.HookQueueResult{{
+			Queued: []params.QueuedHook{{
+				Id:         "relation-joined:0:mysql/0",
+				Kind:       "relation-joined",
+				RelationId: 0,
+				RemoteUnit: "mysql/0",
+			}, dropped},
+			Dropped: []params.QueuedHook{dropped},
+		}, {
+			Error: apiservertesting.ErrUnauthorized,
+		}},
+	})
+}
+
+func (s *uniterV9Suite) TestWatchHookQueues(c *gc.C) {
+	c.Assert(s.resources.Count(), gc.Equals, 0)
+
+	result, err := s.uniter.WatchHookQueues(params.Entities{
+		Entities: []params.Entity{
+			{Tag: "unit-mysql-0"},
+			{Tag: "unit-wordpress-0"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.NotifyWatchResults{
+		Results: []params.NotifyWatchResult{
+			{Error: apiservertesting.ErrUnauthorized},
+			{NotifyWatcherId: "1"},
+		},
+	})
+
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+	resource := s.resources.Get("1")
+	defer statetesting.AssertStop(c, resource)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, resource.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	err = s.wordpressUnit.SetHookQueue(nil, []state.QueuedHook{{Kind: "config-changed"}})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
@@ -0,0 +1,174 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package upgradeseries provides the API used by clients to upgrade
+// the series of machines, and by machine agents to adjust themselves
+// to the new series.
+package upgradeseries
+
+import (
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+)
+
+func init() {
+	common.RegisterStandardFacade("UpgradeSeries", 1, NewUpgradeSeriesAPI)
+}
+
+// UpgradeSeriesAPI implements the UpgradeSeries facade.
+type UpgradeSeriesAPI struct {
+	st         *state.State
+	resources  *common.Resources
+	authorizer common.Authorizer
+	check      *common.BlockChecker
+}
+
+// NewUpgradeSeriesAPI creates a new server-side UpgradeSeries facade.
+func NewUpgradeSeriesAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) (*UpgradeSeriesAPI, error) {
+	if !authorizer.AuthClient() && !authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return &UpgradeSeriesAPI{
+		st:         st,
+		resources:  resources,
+		authorizer: authorizer,
+		check:      common.NewBlockChecker(st),
+	}, nil
+}
+
+// getMachine returns the machine with the given tag, if the
+// authenticated entity is a client or that machine's agent.
+func (api *UpgradeSeriesAPI) getMachine(tag string) (*state.Machine, error) {
+	machineTag, err := names.ParseMachineTag(tag)
+	if err != nil {
+		return nil, common.ErrPerm
+	}
+	if !api.authorizer.AuthClient() && !api.authorizer.AuthOwner(machineTag) {
+		return nil, common.ErrPerm
+	}
+	return api.st.Machine(machineTag.Id())
+}
+
+// getAgentMachine returns the machine with the given tag, if the
+// authenticated agent is that machine's.
+func (api *UpgradeSeriesAPI) getAgentMachine(tag string) (*state.Machine, error) {
+	if !api.authorizer.AuthMachineAgent() {
+		return nil, common.ErrPerm
+	}
+	return api.getMachine(tag)
+}
+
+// Prepare starts an upgrade of each of the given machines to the
+// given series. The units on each machine run their
+// pre-series-upgrade hooks, after which the machine's operating
+// system may be upgraded.
+func (api *UpgradeSeriesAPI) Prepare(args params.UpgradeSeriesPrepareArgs) (params.ErrorResults, error) {
+	if !api.authorizer.AuthClient() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return params.ErrorResults{}, err
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Args)),
+	}
+	for i, arg := range args.Args {
+		machine, err := api.getMachine(arg.Tag)
+		if err == nil {
+			err = machine.PrepareSeriesUpgrade(arg.Series)
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// Complete records that the operating system of each of the given
+// machines has been upgraded, so that its agent adjusts itself to the
+// new series and the units on it run their post-series-upgrade hooks.
+func (api *UpgradeSeriesAPI) Complete(args params.Entities) (params.ErrorResults, error) {
+	if !api.authorizer.AuthClient() {
+		return params.ErrorResults{}, common.ErrPerm
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return params.ErrorResults{}, err
+	}
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getMachine(entity.Tag)
+		if err == nil {
+			err = machine.CompleteSeriesUpgrade()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// MachineStatus returns the progress of each of the given machines
+// through an upgrade of its series.
+func (api *UpgradeSeriesAPI) MachineStatus(args params.Entities) (params.UpgradeSeriesStatusResults, error) {
+	result := params.UpgradeSeriesStatusResults{
+		Results: make([]params.UpgradeSeriesStatusResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getMachine(entity.Tag)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		status, series, err := machine.UpgradeSeriesStatus()
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Result = &params.UpgradeSeriesStatus{
+			Status: string(status),
+			Series: series,
+		}
+	}
+	return result, nil
+}
+
+// WatchMachine returns a watcher for each of the given machines that
+// notifies of the progress of an upgrade of the machine's series.
+func (api *UpgradeSeriesAPI) WatchMachine(args params.Entities) (params.NotifyWatchResults, error) {
+	result := params.NotifyWatchResults{
+		Results: make([]params.NotifyWatchResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getAgentMachine(entity.Tag)
+		if err == nil {
+			w := machine.WatchUpgradeSeries()
+			// Consume the initial event; the agent gets the
+			// current status itself.
+			if _, ok := <-w.Changes(); ok {
+				result.Results[i].NotifyWatcherId = api.resources.Register(w)
+			} else {
+				err = watcher.EnsureErr(w)
+			}
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
+
+// SetAgentUpdated records that the agent of each of the given machines
+// has adjusted itself to the series its machine is being upgraded to.
+func (api *UpgradeSeriesAPI) SetAgentUpdated(args params.Entities) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Entities)),
+	}
+	for i, entity := range args.Entities {
+		machine, err := api.getAgentMachine(entity.Tag)
+		if err == nil {
+			err = machine.UpgradeSeriesAgentUpdated()
+		}
+		result.Results[i].Error = common.ServerError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	commontesting "github.com/juju/juju/apiserver/common/testing"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/upgradeseries"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type upgradeSeriesSuite struct {
+	jujutesting.JujuConnSuite
+	commontesting.BlockHelper
+	machine   *state.Machine
+	resources *common.Resources
+	agentAPI  *upgradeseries.UpgradeSeriesAPI
+	clientAPI *upgradeseries.UpgradeSeriesAPI
+}
+
+var _ = gc.Suite(&upgradeSeriesSuite{})
+
+func (s *upgradeSeriesSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.BlockHelper = commontesting.NewBlockHelper(s.APIState)
+	s.AddCleanup(func(*gc.C) { s.BlockHelper.Close() })
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+
+	agent := apiservertesting.FakeAuthorizer{Tag: s.machine.Tag()}
+	s.agentAPI, err = upgradeseries.NewUpgradeSeriesAPI(s.State, s.resources, agent)
+	c.Assert(err, jc.ErrorIsNil)
+	client := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	s.clientAPI, err = upgradeseries.NewUpgradeSeriesAPI(s.State, s.resources, client)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *upgradeSeriesSuite) machineArgs() params.Entities {
+	return params.Entities{Entities: []params.Entity{{Tag: s.machine.Tag().String()}}}
+}
+
+func (s *upgradeSeriesSuite) assertMachineStatus(c *gc.C, status state.UpgradeSeriesStatus, series string) {
+	results, err := s.clientAPI.MachineStatus(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, jc.DeepEquals, []params.UpgradeSeriesStatusResult{{
+		Result: &params.UpgradeSeriesStatus{Status: string(status), Series: series},
+	}})
+}
+
+func (s *upgradeSeriesSuite) TestNewAPIRefusesUnitAgent(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewUnitTag("wordpress/0")}
+	_, err := upgradeseries.NewUpgradeSeriesAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+func (s *upgradeSeriesSuite) TestPrepareAndComplete(c *gc.C) {
+	watchResults, err := s.agentAPI.WatchMachine(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(watchResults.Results, gc.HasLen, 1)
+	c.Assert(watchResults.Results[0].Error, gc.IsNil)
+	w := s.resources.Get(watchResults.Results[0].NotifyWatcherId)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w.(state.NotifyWatcher))
+	wc.AssertNoChange()
+
+	results, err := s.clientAPI.Prepare(params.UpgradeSeriesPrepareArgs{
+		Args: []params.UpgradeSeriesPrepareArg{{
+			Tag:    s.machine.Tag().String(),
+			Series: "trusty",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	wc.AssertOneChange()
+	s.assertMachineStatus(c, state.UpgradeSeriesPrepareCompleted, "trusty")
+
+	results, err = s.clientAPI.Complete(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	wc.AssertOneChange()
+	s.assertMachineStatus(c, state.UpgradeSeriesCompleteStarted, "trusty")
+
+	results, err = s.agentAPI.SetAgentUpdated(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), jc.ErrorIsNil)
+	wc.AssertOneChange()
+	s.assertMachineStatus(c, state.UpgradeSeriesNotStarted, "")
+
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Series(), gc.Equals, "trusty")
+}
+
+func (s *upgradeSeriesSuite) TestPrepareInvalidSeries(c *gc.C) {
+	results, err := s.clientAPI.Prepare(params.UpgradeSeriesPrepareArgs{
+		Args: []params.UpgradeSeriesPrepareArg{{
+			Tag:    s.machine.Tag().String(),
+			Series: "win2012r2",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, `cannot prepare series upgrade of machine 0: cannot upgrade from series "quantal" to "win2012r2"`)
+}
+
+func (s *upgradeSeriesSuite) TestPrepareBlocked(c *gc.C) {
+	s.BlockAllChanges(c, "TestPrepareBlocked")
+	_, err := s.clientAPI.Prepare(params.UpgradeSeriesPrepareArgs{
+		Args: []params.UpgradeSeriesPrepareArg{{
+			Tag:    s.machine.Tag().String(),
+			Series: "trusty",
+		}},
+	})
+	s.AssertBlocked(c, err, "TestPrepareBlocked")
+}
+
+func (s *upgradeSeriesSuite) TestPermissions(c *gc.C) {
+	other, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	otherArgs := params.Entities{Entities: []params.Entity{{Tag: other.Tag().String()}}}
+
+	_, err = s.agentAPI.Prepare(params.UpgradeSeriesPrepareArgs{})
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	_, err = s.agentAPI.Complete(s.machineArgs())
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+	results, err := s.agentAPI.SetAgentUpdated(otherArgs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+	statusResults, err := s.agentAPI.MachineStatus(otherArgs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statusResults.Results[0].Error, gc.ErrorMatches, "permission denied")
+
+	// Clients cannot act for machine agents.
+	results, err = s.clientAPI.SetAgentUpdated(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.OneError(), gc.ErrorMatches, "permission denied")
+	watchResults, err := s.clientAPI.WatchMachine(s.machineArgs())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(watchResults.Results[0].Error, gc.ErrorMatches, "permission denied")
+}
@@ -0,0 +1,165 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Default maximum sizes, in bytes, of the request bodies accepted
+// by the HTTP endpoints that receive uploads.
+const (
+	DefaultMaxCharmUploadSize  = 1 << 30
+	DefaultMaxToolsUploadSize  = 512 << 20
+	DefaultMaxBackupUploadSize = 64 << 30
+)
+
+// UploadLimits holds the maximum sizes, in bytes, of the request
+// bodies accepted by the HTTP endpoints that receive uploads. A
+// zero limit means the default limit for the endpoint.
+type UploadLimits struct {
+	Charms  int64
+	Tools   int64
+	Backups int64
+}
+
+// withDefaults returns the limits with any unset limits
+// replaced by their defaults.
+func (l UploadLimits) withDefaults() UploadLimits {
+	if l.Charms == 0 {
+		l.Charms = DefaultMaxCharmUploadSize
+	}
+	if l.Tools == 0 {
+		l.Tools = DefaultMaxToolsUploadSize
+	}
+	if l.Backups == 0 {
+		l.Backups = DefaultMaxBackupUploadSize
+	}
+	return l
+}
+
+// uploadTooLargeError is returned when an upload exceeds
+// the maximum size accepted by an endpoint.
+type uploadTooLargeError struct {
+	limit int64
+}
+
+func (e *uploadTooLargeError) Error() string {
+	return fmt.Sprintf("upload exceeds maximum size of %d bytes", e.limit)
+}
+
+func isUploadTooLarge(err error) bool {
+	_, ok := errors.Cause(err).(*uploadTooLargeError)
+	return ok
+}
+
+// uploadErrorStatus returns the HTTP status code to report err with,
+// which is http.StatusRequestEntityTooLarge for uploads that are too
+// large, and status otherwise.
+func uploadErrorStatus(err error, status int) int {
+	if isUploadTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return status
+}
+
+// spooledUpload holds the body of an upload request that has been
+// written to a temporary file, so it can be read as many times as
+// needed without being held in memory.
+type spooledUpload struct {
+	*os.File
+	size   int64
+	sha256 []byte
+	sha1   []byte
+}
+
+// spoolUpload copies the body of the request to a temporary file,
+// computing its size and hashes along the way. If limit is positive
+// and the body is larger than limit bytes, it returns an
+// uploadTooLargeError without reading any further. The returned
+// upload is positioned at its start, and must be closed.
+func spoolUpload(body io.Reader, contentLength, limit int64) (_ *spooledUpload, err error) {
+	if limit > 0 && contentLength > limit {
+		return nil, &uploadTooLargeError{limit}
+	}
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create temp file")
+	}
+	upload := &spooledUpload{File: file}
+	defer func() {
+		if err != nil {
+			upload.Close()
+		}
+	}()
+	if limit > 0 {
+		// Read one byte more than the limit, to
+		// tell whether the limit was exceeded.
+		body = io.LimitReader(body, limit+1)
+	}
+	sha256hash := sha256.New()
+	sha1hash := sha1.New()
+	size, err := io.Copy(io.MultiWriter(file, sha256hash, sha1hash), body)
+	if err != nil {
+		return nil, errors.Annotate(err, "error processing file upload")
+	}
+	if limit > 0 && size > limit {
+		return nil, &uploadTooLargeError{limit}
+	}
+	if err := upload.rewind(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	upload.size = size
+	upload.sha256 = sha256hash.Sum(nil)
+	upload.sha1 = sha1hash.Sum(nil)
+	return upload, nil
+}
+
+// rewind positions the upload at its start.
+func (u *spooledUpload) rewind() error {
+	if _, err := u.Seek(0, 0); err != nil {
+		return errors.Annotate(err, "cannot rewind upload")
+	}
+	return nil
+}
+
+// SHA256 returns the hex-encoded SHA-256 hash of the upload.
+func (u *spooledUpload) SHA256() string {
+	return fmt.Sprintf("%x", u.sha256)
+}
+
+// Close closes and removes the temporary file.
+func (u *spooledUpload) Close() error {
+	err := u.File.Close()
+	if removeErr := os.Remove(u.Name()); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// checkDigest checks the upload against the SHA-256 digest in the
+// request's Digest header, in the form "SHA-256=<base64 hash>", if
+// the client sent one.
+func (u *spooledUpload) checkDigest(r *http.Request) error {
+	for _, digest := range strings.Split(r.Header.Get("Digest"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "SHA-256") {
+			continue
+		}
+		if expect := base64.StdEncoding.EncodeToString(u.sha256); parts[1] != expect {
+			return errors.Errorf("upload digest mismatch: expected SHA-256 %s, got %s", parts[1], expect)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// This is an internal package test.
+
+package apiserver
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/testing"
+)
+
+type uploadsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&uploadsSuite{})
+
+const uploadContent = "some upload content"
+
+func (s *uploadsSuite) spool(c *gc.C) *spooledUpload {
+	upload, err := spoolUpload(strings.NewReader(uploadContent), -1, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { upload.Close() })
+	return upload
+}
+
+func (s *uploadsSuite) TestUploadLimitsDefaults(c *gc.C) {
+	limits := UploadLimits{Tools: 10}.withDefaults()
+	c.Assert(limits, jc.DeepEquals, UploadLimits{
+		Charms:  DefaultMaxCharmUploadSize,
+		Tools:   10,
+		Backups: DefaultMaxBackupUploadSize,
+	})
+}
+
+func (s *uploadsSuite) TestSpoolUpload(c *gc.C) {
+	upload := s.spool(c)
+	c.Assert(upload.size, gc.Equals, int64(len(uploadContent)))
+	c.Assert(upload.SHA256(), gc.Equals, fmt.Sprintf("%x", sha256.Sum256([]byte(uploadContent))))
+	sha1sum := sha1.Sum([]byte(uploadContent))
+	c.Assert(upload.sha1, jc.DeepEquals, sha1sum[:])
+
+	// The upload can be read from the start, as many times as needed.
+	for i := 0; i < 2; i++ {
+		data, err := ioutil.ReadAll(upload)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(data), gc.Equals, uploadContent)
+		c.Assert(upload.rewind(), jc.ErrorIsNil)
+	}
+}
+
+func (s *uploadsSuite) TestSpoolUploadCloseRemovesFile(c *gc.C) {
+	upload, err := spoolUpload(strings.NewReader(uploadContent), -1, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	name := upload.Name()
+	c.Assert(upload.Close(), jc.ErrorIsNil)
+	_, err = os.Stat(name)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *uploadsSuite) TestSpoolUploadAtLimit(c *gc.C) {
+	upload, err := spoolUpload(strings.NewReader(uploadContent), -1, int64(len(uploadContent)))
+	c.Assert(err, jc.ErrorIsNil)
+	defer upload.Close()
+	c.Assert(upload.size, gc.Equals, int64(len(uploadContent)))
+}
+
+func (s *uploadsSuite) TestSpoolUploadTooLarge(c *gc.C) {
+	limit := int64(len(uploadContent) - 1)
+	_, err := spoolUpload(strings.NewReader(uploadContent), -1, limit)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("upload exceeds maximum size of %d bytes", limit))
+	c.Assert(isUploadTooLarge(err), jc.IsTrue)
+	c.Assert(uploadErrorStatus(err, http.StatusBadRequest), gc.Equals, http.StatusRequestEntityTooLarge)
+}
+
+func (s *uploadsSuite) TestSpoolUploadContentLengthTooLarge(c *gc.C) {
+	// The declared length is rejected before the body is read.
+	_, err := spoolUpload(strings.NewReader(""), 100, 10)
+	c.Assert(isUploadTooLarge(err), jc.IsTrue)
+}
+
+func (s *uploadsSuite) TestUploadErrorStatusOther(c *gc.C) {
+	err := fmt.Errorf("boom")
+	c.Assert(uploadErrorStatus(err, http.StatusBadRequest), gc.Equals, http.StatusBadRequest)
+}
+
+func (s *uploadsSuite) TestCheckDigest(c *gc.C) {
+	upload := s.spool(c)
+	sha256sum := sha256.Sum256([]byte(uploadContent))
+	digest := base64.StdEncoding.EncodeToString(sha256sum[:])
+
+	for i, test := range []struct {
+		header string
+		err    string
+	}{{
+		header: "",
+	}, {
+		header: "SHA-256=" + digest,
+	}, {
+		header: "MD5=foo, sha-256=" + digest,
+	}, {
+		header: "SHA-256=bad",
+		err:    "upload digest mismatch: expected SHA-256 bad, got " + digest,
+	}} {
+		c.Logf("test %d: %q", i, test.header)
+		req := &http.Request{Header: http.Header{}}
+		if test.header != "" {
+			req.Header.Set("Digest", test.header)
+		}
+		err := upload.checkDigest(req)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *uploadsSuite) TestCheckBackupArchive(c *gc.C) {
+	upload := s.spool(c)
+	sha1sum := sha1.Sum([]byte(uploadContent))
+	checksum := base64.StdEncoding.EncodeToString(sha1sum[:])
+	size := int64(len(uploadContent))
+
+	for i, test := range []struct {
+		meta params.BackupsMetadataResult
+		err  string
+	}{{
+		meta: params.BackupsMetadataResult{},
+	}, {
+		meta: params.BackupsMetadataResult{
+			Size:           size,
+			Checksum:       checksum,
+			ChecksumFormat: backupChecksumFormat,
+		},
+	}, {
+		meta: params.BackupsMetadataResult{Size: size + 1},
+		err:  fmt.Sprintf("archive size mismatch: expected %d bytes, got %d", size+1, size),
+	}, {
+		meta: params.BackupsMetadataResult{
+			Checksum:       checksum,
+			ChecksumFormat: "MD5",
+		},
+		err: `unsupported checksum format "MD5"`,
+	}, {
+		meta: params.BackupsMetadataResult{
+			Checksum:       "bad",
+			ChecksumFormat: backupChecksumFormat,
+		},
+		err: "archive checksum mismatch: expected bad, got " + checksum,
+	}} {
+		c.Logf("test %d", i)
+		err := checkBackupArchive(test.meta, upload)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package waitfor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/multiwatcher"
+)
+
+// valueType identifies the type of an entity field, which determines
+// the operators that may be applied to it.
+type valueType int
+
+const (
+	stringValue valueType = iota
+	boolValue
+	intValue
+	listValue
+)
+
+// field describes a field of an entity that conditions can test.
+type field struct {
+	vtype valueType
+
+	// get returns the value of the field for the entity with the
+	// given id, which must be known to es. The value is a string,
+	// bool, int or []string, according to vtype.
+	get func(es *entities, id string) interface{}
+}
+
+// existsField is the name of the field, common to all entity kinds,
+// that reports whether the entity exists.
+const existsField = "exists"
+
+// fields holds the fields that conditions can test, by entity kind.
+var fields = map[string]map[string]field{
+	names.MachineTagKind: {
+		"life": {stringValue, func(es *entities, id string) interface{} {
+			return string(es.machines[id].Life)
+		}},
+		"status": {stringValue, func(es *entities, id string) interface{} {
+			return string(es.machines[id].Status)
+		}},
+		"instance-id": {stringValue, func(es *entities, id string) interface{} {
+			return es.machines[id].InstanceId
+		}},
+		"series": {stringValue, func(es *entities, id string) interface{} {
+			return es.machines[id].Series
+		}},
+		"addresses": {listValue, func(es *entities, id string) interface{} {
+			var values []string
+			for _, addr := range es.machines[id].Addresses {
+				values = append(values, addr.Value)
+			}
+			return values
+		}},
+		"networks": {listValue, func(es *entities, id string) interface{} {
+			var values []string
+			for _, addr := range es.machines[id].Addresses {
+				if addr.NetworkName != "" {
+					values = append(values, addr.NetworkName)
+				}
+			}
+			return values
+		}},
+	},
+	names.ServiceTagKind: {
+		"life": {stringValue, func(es *entities, id string) interface{} {
+			return string(es.services[id].Life)
+		}},
+		"status": {stringValue, func(es *entities, id string) interface{} {
+			return string(es.services[id].Status)
+		}},
+		"exposed": {boolValue, func(es *entities, id string) interface{} {
+			return es.services[id].Exposed
+		}},
+		"charm": {stringValue, func(es *entities, id string) interface{} {
+			return es.services[id].CharmURL
+		}},
+		"units": {intValue, func(es *entities, id string) interface{} {
+			return es.countUnits(id, "")
+		}},
+		"started-units": {intValue, func(es *entities, id string) interface{} {
+			return es.countUnits(id, multiwatcher.Status("started"))
+		}},
+	},
+	names.UnitTagKind: {
+		"status": {stringValue, func(es *entities, id string) interface{} {
+			return string(es.units[id].Status)
+		}},
+		"machine": {stringValue, func(es *entities, id string) interface{} {
+			return es.units[id].MachineId
+		}},
+		"public-address": {stringValue, func(es *entities, id string) interface{} {
+			return es.units[id].PublicAddress
+		}},
+		"private-address": {stringValue, func(es *entities, id string) interface{} {
+			return es.units[id].PrivateAddress
+		}},
+	},
+}
+
+// operators holds the operators that may be applied to fields of each
+// type.
+var operators = map[valueType][]string{
+	stringValue: {"==", "!="},
+	boolValue:   {"==", "!="},
+	intValue:    {"==", "!=", "<", "<=", ">", ">="},
+	listValue:   {"has"},
+}
+
+// clause is a single comparison of an entity field with a value.
+type clause struct {
+	field string
+	op    string
+	value string
+}
+
+// String returns the clause as it would be written in a condition.
+func (c clause) String() string {
+	return fmt.Sprintf("%s %s %s", c.field, c.op, c.value)
+}
+
+// holds returns whether the clause holds for the given field value.
+func (c clause) holds(value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		return c.compare(value == c.value)
+	case bool:
+		return c.compare(strconv.FormatBool(value) == c.value)
+	case int:
+		// The value was checked when the condition was parsed.
+		n, _ := strconv.Atoi(c.value)
+		switch c.op {
+		case "<":
+			return value < n
+		case "<=":
+			return value <= n
+		case ">":
+			return value > n
+		case ">=":
+			return value >= n
+		}
+		return c.compare(value == n)
+	case []string:
+		for _, v := range value {
+			if v == c.value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare returns whether an equality clause holds, given whether
+// the field is equal to the clause's value.
+func (c clause) compare(equal bool) bool {
+	if c.op == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+// condition is a parsed condition on the fields of an entity. It
+// holds when all its clauses hold.
+type condition struct {
+	tag     names.Tag
+	fields  map[string]field
+	clauses []clause
+}
+
+// parseCondition parses a condition on the fields of the entity with
+// the given tag. A condition consists of one or more clauses of the
+// form "<field> <operator> <value>", separated by "&&".
+func parseCondition(tag names.Tag, expr string) (*condition, error) {
+	kindFields, ok := fields[tag.Kind()]
+	if !ok {
+		return nil, errors.NotSupportedf("conditions on %s", tag.Kind())
+	}
+	cond := &condition{
+		tag:    tag,
+		fields: kindFields,
+	}
+	for _, part := range strings.Split(expr, "&&") {
+		words := strings.Fields(part)
+		if len(words) != 3 {
+			return nil, errors.NotValidf("clause %q", strings.TrimSpace(part))
+		}
+		c := clause{field: words[0], op: words[1], value: words[2]}
+		if err := cond.validate(c); err != nil {
+			return nil, errors.Annotatef(err, "invalid clause %q", c)
+		}
+		cond.clauses = append(cond.clauses, c)
+	}
+	return cond, nil
+}
+
+// validate returns an error if the clause cannot be evaluated for the
+// condition's entity.
+func (cond *condition) validate(c clause) error {
+	vtype := boolValue
+	if c.field != existsField {
+		f, ok := cond.fields[c.field]
+		if !ok {
+			return errors.NotValidf("%s field %q", cond.tag.Kind(), c.field)
+		}
+		vtype = f.vtype
+	}
+	validOp := false
+	for _, op := range operators[vtype] {
+		if op == c.op {
+			validOp = true
+			break
+		}
+	}
+	if !validOp {
+		return errors.NotValidf("operator %q for field %q", c.op, c.field)
+	}
+	switch vtype {
+	case boolValue:
+		if c.value != "true" && c.value != "false" {
+			return errors.Errorf("expected true or false, got %q", c.value)
+		}
+	case intValue:
+		if _, err := strconv.Atoi(c.value); err != nil {
+			return errors.Errorf("expected integer, got %q", c.value)
+		}
+	}
+	return nil
+}
+
+// evaluate returns whether the condition holds for the entities in
+// es, and if not, which of its clauses do not.
+func (cond *condition) evaluate(es *entities) params.ConditionResult {
+	id := cond.tag.Id()
+	exists := es.exists(cond.tag)
+	var unsatisfied []string
+	reportedMissing := false
+	for _, c := range cond.clauses {
+		var value interface{}
+		switch {
+		case c.field == existsField:
+			value = exists
+		case !exists:
+			if !reportedMissing {
+				unsatisfied = append(unsatisfied, fmt.Sprintf("%s %s not found", cond.tag.Kind(), id))
+				reportedMissing = true
+			}
+			continue
+		default:
+			value = cond.fields[c.field].get(es, id)
+		}
+		if !c.holds(value) {
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s (%s is %s)", c, c.field, formatValue(value)))
+		}
+	}
+	return params.ConditionResult{
+		Satisfied:   len(unsatisfied) == 0,
+		Unsatisfied: unsatisfied,
+	}
+}
+
+// formatValue returns the value of a field as shown when reporting an
+// unsatisfied clause.
+func formatValue(value interface{}) string {
+	switch value.(type) {
+	case string, []string:
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprint(value)
+}
+
+// entities holds the latest known information about the machines,
+// services and units in an environment.
+type entities struct {
+	machines map[string]*multiwatcher.MachineInfo
+	services map[string]*multiwatcher.ServiceInfo
+	units    map[string]*multiwatcher.UnitInfo
+}
+
+func newEntities() *entities {
+	return &entities{
+		machines: make(map[string]*multiwatcher.MachineInfo),
+		services: make(map[string]*multiwatcher.ServiceInfo),
+		units:    make(map[string]*multiwatcher.UnitInfo),
+	}
+}
+
+// update applies the changes reported by a multiwatcher.
+func (es *entities) update(deltas []multiwatcher.Delta) {
+	for _, delta := range deltas {
+		switch info := delta.Entity.(type) {
+		case *multiwatcher.MachineInfo:
+			if delta.Removed {
+				delete(es.machines, info.Id)
+			} else {
+				es.machines[info.Id] = info
+			}
+		case *multiwatcher.ServiceInfo:
+			if delta.Removed {
+				delete(es.services, info.Name)
+			} else {
+				es.services[info.Name] = info
+			}
+		case *multiwatcher.UnitInfo:
+			if delta.Removed {
+				delete(es.units, info.Name)
+			} else {
+				es.units[info.Name] = info
+			}
+		}
+	}
+}
+
+// exists returns whether the entity with the given tag is known.
+func (es *entities) exists(tag names.Tag) bool {
+	var ok bool
+	switch tag.Kind() {
+	case names.MachineTagKind:
+		_, ok = es.machines[tag.Id()]
+	case names.ServiceTagKind:
+		_, ok = es.services[tag.Id()]
+	case names.UnitTagKind:
+		_, ok = es.units[tag.Id()]
+	}
+	return ok
+}
+
+// countUnits returns the number of units of the given service, counting
+// only those with the given status if it is not empty.
+func (es *entities) countUnits(service string, status multiwatcher.Status) int {
+	count := 0
+	for _, u := range es.units {
+		if u.Service == service && (status == "" || u.Status == status) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,179 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package waitfor
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state/multiwatcher"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type conditionSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&conditionSuite{})
+
+var parseConditionErrorTests = []struct {
+	tag  names.Tag
+	expr string
+	err  string
+}{{
+	tag:  names.NewUserTag("admin"),
+	expr: "exists == true",
+	err:  "conditions on user not supported",
+}, {
+	tag:  names.NewMachineTag("0"),
+	expr: "",
+	err:  `clause "" not valid`,
+}, {
+	tag:  names.NewMachineTag("0"),
+	expr: "status == started && life",
+	err:  `clause "life" not valid`,
+}, {
+	tag:  names.NewMachineTag("0"),
+	expr: "units >= 3",
+	err:  `invalid clause "units >= 3": machine field "units" not valid`,
+}, {
+	tag:  names.NewServiceTag("mysql"),
+	expr: "status >= started",
+	err:  `invalid clause "status >= started": operator ">=" for field "status" not valid`,
+}, {
+	tag:  names.NewServiceTag("mysql"),
+	expr: "units > three",
+	err:  `invalid clause "units > three": expected integer, got "three"`,
+}, {
+	tag:  names.NewServiceTag("mysql"),
+	expr: "exposed == yes",
+	err:  `invalid clause "exposed == yes": expected true or false, got "yes"`,
+}, {
+	tag:  names.NewMachineTag("0"),
+	expr: "addresses == 10.0.0.1",
+	err:  `invalid clause "addresses == 10.0.0.1": operator "==" for field "addresses" not valid`,
+}}
+
+func (s *conditionSuite) TestParseConditionErrors(c *gc.C) {
+	for i, t := range parseConditionErrorTests {
+		c.Logf("test %d: %q", i, t.expr)
+		_, err := parseCondition(t.tag, t.expr)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func testEntities() *entities {
+	es := newEntities()
+	es.update([]multiwatcher.Delta{{
+		Entity: &multiwatcher.MachineInfo{
+			Id:         "0",
+			InstanceId: "i-0",
+			Status:     "started",
+			Life:       "alive",
+			Series:     "trusty",
+			Addresses: []network.Address{
+				network.NewAddress("10.0.0.1", network.ScopeCloudLocal),
+				{Value: "192.168.1.1", NetworkName: "db"},
+			},
+		},
+	}, {
+		Entity: &multiwatcher.ServiceInfo{
+			Name:     "mysql",
+			Exposed:  true,
+			CharmURL: "cs:trusty/mysql-1",
+			Life:     "alive",
+			Status:   "running",
+		},
+	}, {
+		Entity: &multiwatcher.UnitInfo{
+			Name:      "mysql/0",
+			Service:   "mysql",
+			MachineId: "0",
+			Status:    "started",
+		},
+	}, {
+		Entity: &multiwatcher.UnitInfo{
+			Name:    "mysql/1",
+			Service: "mysql",
+			Status:  "pending",
+		},
+	}, {
+		Entity: &multiwatcher.UnitInfo{
+			Name:    "wordpress/0",
+			Service: "wordpress",
+			Status:  "started",
+		},
+	}})
+	return es
+}
+
+var evaluateTests = []struct {
+	tag         names.Tag
+	expr        string
+	unsatisfied []string
+}{{
+	tag:  names.NewMachineTag("0"),
+	expr: "status == started && instance-id == i-0 && series != precise",
+}, {
+	tag:  names.NewMachineTag("0"),
+	expr: "addresses has 10.0.0.1 && networks has db && life == alive",
+}, {
+	tag:         names.NewMachineTag("0"),
+	expr:        "networks has web",
+	unsatisfied: []string{`networks has web (networks is ["db"])`},
+}, {
+	tag:  names.NewServiceTag("mysql"),
+	expr: "status == running && units == 2 && started-units >= 1 && exposed == true",
+}, {
+	tag:  names.NewServiceTag("mysql"),
+	expr: "started-units >= 2 && exposed != true && charm == cs:trusty/mysql-1",
+	unsatisfied: []string{
+		"started-units >= 2 (started-units is 1)",
+		"exposed != true (exposed is true)",
+	},
+}, {
+	tag:  names.NewUnitTag("mysql/0"),
+	expr: "status == started && machine == 0 && exists == true",
+}, {
+	tag:         names.NewUnitTag("mysql/1"),
+	expr:        "status == started",
+	unsatisfied: []string{`status == started (status is "pending")`},
+}, {
+	tag:         names.NewServiceTag("wordpress"),
+	expr:        "units >= 1 && exists == true && status == running",
+	unsatisfied: []string{"service wordpress not found", "exists == true (exists is false)"},
+}, {
+	tag:  names.NewMachineTag("1"),
+	expr: "exists == false",
+}}
+
+func (s *conditionSuite) TestEvaluate(c *gc.C) {
+	es := testEntities()
+	for i, t := range evaluateTests {
+		c.Logf("test %d: %s %q", i, t.tag, t.expr)
+		cond, err := parseCondition(t.tag, t.expr)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(cond.evaluate(es), jc.DeepEquals, params.ConditionResult{
+			Satisfied:   len(t.unsatisfied) == 0,
+			Unsatisfied: t.unsatisfied,
+		})
+	}
+}
+
+func (s *conditionSuite) TestEntitiesUpdateRemoved(c *gc.C) {
+	es := testEntities()
+	es.update([]multiwatcher.Delta{{
+		Removed: true,
+		Entity:  &multiwatcher.UnitInfo{Name: "mysql/1", Service: "mysql"},
+	}, {
+		Removed: true,
+		Entity:  &multiwatcher.MachineInfo{Id: "0"},
+	}})
+	c.Check(es.exists(names.NewUnitTag("mysql/1")), jc.IsFalse)
+	c.Check(es.exists(names.NewMachineTag("0")), jc.IsFalse)
+	c.Check(es.countUnits("mysql", ""), gc.Equals, 1)
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package waitfor implements the API used to watch conditions on the
+// fields of machines, services and units, so that clients can wait
+// for entities to reach a given state without polling status.
+package waitfor
+
+import (
+	"reflect"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("WaitFor", 1, NewAPI)
+	common.RegisterFacade(
+		"ConditionWatcher", 1, newConditionWatcherAPI,
+		reflect.TypeOf((*conditionWatcherAPI)(nil)),
+	)
+}
+
+// API implements the WaitFor facade.
+type API struct {
+	st        *state.State
+	resources *common.Resources
+}
+
+// NewAPI returns a new WaitFor API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{
+		st:        st,
+		resources: resources,
+	}, nil
+}
+
+// WatchConditions returns a ConditionWatcher for each of the given
+// conditions. Each watcher's first Next call returns the condition's
+// initial evaluation, and later calls return whenever the evaluation
+// changes.
+func (api *API) WatchConditions(args params.EntityConditions) (params.ConditionWatchResults, error) {
+	results := params.ConditionWatchResults{
+		Results: make([]params.ConditionWatchResult, len(args.Conditions)),
+	}
+	for i, arg := range args.Conditions {
+		id, err := api.watchCondition(arg)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].ConditionWatcherId = id
+	}
+	return results, nil
+}
+
+func (api *API) watchCondition(arg params.EntityCondition) (string, error) {
+	tag, err := names.ParseTag(arg.Tag)
+	if err != nil {
+		return "", err
+	}
+	cond, err := parseCondition(tag, arg.Condition)
+	if err != nil {
+		return "", err
+	}
+	w := &conditionWatcher{
+		condition: cond,
+		watcher:   api.st.Watch(),
+		entities:  newEntities(),
+	}
+	return api.resources.Register(w), nil
+}
+
+// conditionWatcher reports the evaluation of a condition as the
+// environment changes.
+type conditionWatcher struct {
+	condition *condition
+	watcher   *state.Multiwatcher
+	entities  *entities
+
+	// last holds the evaluation last returned by Next, if any.
+	last *params.ConditionResult
+}
+
+// Next returns the evaluation of the condition when it first becomes
+// known, and subsequently whenever it changes.
+func (w *conditionWatcher) Next() (params.ConditionResult, error) {
+	for {
+		deltas, err := w.watcher.Next()
+		if errors.Cause(err) == state.ErrStopped {
+			return params.ConditionResult{}, common.ErrStoppedWatcher
+		} else if err != nil {
+			return params.ConditionResult{}, errors.Trace(err)
+		}
+		w.entities.update(deltas)
+		result := w.condition.evaluate(w.entities)
+		if w.last != nil && reflect.DeepEqual(*w.last, result) {
+			continue
+		}
+		w.last = &result
+		return result, nil
+	}
+}
+
+// Stop stops the watcher.
+func (w *conditionWatcher) Stop() error {
+	return w.watcher.Stop()
+}
+
+func newConditionWatcherAPI(st *state.State, resources *common.Resources, auth common.Authorizer, id string) (interface{}, error) {
+	if !auth.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	watcher, ok := resources.Get(id).(*conditionWatcher)
+	if !ok {
+		return nil, common.ErrUnknownWatcher
+	}
+	return &conditionWatcherAPI{
+		watcher:   watcher,
+		id:        id,
+		resources: resources,
+	}, nil
+}
+
+// conditionWatcherAPI defines the API methods on a conditionWatcher.
+// Each client has its own current set of watchers, stored in
+// resources.
+type conditionWatcherAPI struct {
+	watcher   *conditionWatcher
+	id        string
+	resources *common.Resources
+}
+
+// Next returns the evaluation of the watched condition when it first
+// becomes known, and subsequently whenever it changes.
+func (w *conditionWatcherAPI) Next() (params.ConditionResult, error) {
+	return w.watcher.Next()
+}
+
+// Stop stops the watcher.
+func (w *conditionWatcherAPI) Stop() error {
+	return w.resources.Stop(w.id)
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package waitfor_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/apiserver/waitfor"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type waitForSuite struct {
+	jujutesting.JujuConnSuite
+	resources *common.Resources
+	api       *waitfor.API
+	service   *state.Service
+}
+
+var _ = gc.Suite(&waitForSuite{})
+
+func (s *waitForSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.resources = common.NewResources()
+	s.AddCleanup(func(*gc.C) { s.resources.StopAll() })
+	auth := apiservertesting.FakeAuthorizer{Tag: s.AdminUserTag(c)}
+	var err error
+	s.api, err = waitfor.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, jc.ErrorIsNil)
+	s.service = s.Factory.MakeService(c, nil)
+	s.Factory.MakeUnit(c, &factory.UnitParams{Service: s.service})
+}
+
+func (s *waitForSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{Tag: names.NewMachineTag("0")}
+	_, err := waitfor.NewAPI(s.State, s.resources, auth)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+// conditionWatcher is implemented by the watchers registered by
+// WatchConditions.
+type conditionWatcher interface {
+	Next() (params.ConditionResult, error)
+}
+
+// next returns the result of calling Next on the watcher, failing the
+// test if it does not return in time.
+func next(c *gc.C, w conditionWatcher) (params.ConditionResult, error) {
+	type nextResult struct {
+		result params.ConditionResult
+		err    error
+	}
+	done := make(chan nextResult, 1)
+	go func() {
+		result, err := w.Next()
+		done <- nextResult{result, err}
+	}()
+	select {
+	case r := <-done:
+		return r.result, r.err
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for condition")
+	}
+	panic("unreachable")
+}
+
+func (s *waitForSuite) TestWatchConditions(c *gc.C) {
+	results, err := s.api.WatchConditions(params.EntityConditions{
+		Conditions: []params.EntityCondition{
+			{Tag: s.service.Tag().String(), Condition: "units >= 2"},
+			{Tag: "machine-0", Condition: "units >= 2"},
+			{Tag: "foo", Condition: "exists == true"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, jc.DeepEquals, params.ConditionWatchResults{
+		Results: []params.ConditionWatchResult{
+			{ConditionWatcherId: "1"},
+			{Error: &params.Error{
+				Message: `invalid clause "units >= 2": machine field "units" not valid`,
+			}},
+			{Error: &params.Error{Message: `"foo" is not a valid tag`}},
+		},
+	})
+	c.Assert(s.resources.Count(), gc.Equals, 1)
+
+	w, ok := s.resources.Get("1").(conditionWatcher)
+	c.Assert(ok, jc.IsTrue)
+	result, err := next(c, w)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConditionResult{
+		Unsatisfied: []string{"units >= 2 (units is 1)"},
+	})
+
+	s.Factory.MakeUnit(c, &factory.UnitParams{Service: s.service})
+	result, err = next(c, w)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConditionResult{Satisfied: true})
+}
+
+func (s *waitForSuite) TestWatcherStopped(c *gc.C) {
+	results, err := s.api.WatchConditions(params.EntityConditions{
+		Conditions: []params.EntityCondition{
+			{Tag: s.service.Tag().String(), Condition: "exists == true"},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	id := results.Results[0].ConditionWatcherId
+	w := s.resources.Get(id).(conditionWatcher)
+	result, err := next(c, w)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ConditionResult{Satisfied: true})
+
+	err = s.resources.Stop(id)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = next(c, w)
+	c.Assert(err, gc.Equals, common.ErrStoppedWatcher)
+}
@@ -38,6 +38,36 @@ func ParseCert(certPEM string) (*x509.Certificate, error) {
 	return nil, errors.New("no certificates found")
 }
 
+// ParseCertPool parses all the PEM-formatted X509 certificates in the
+// given data and returns a pool containing them. Multiple certificates
+// are present in the data when a CA certificate is being rotated and
+// both the old and new CA certificates must be trusted.
+func ParseCertPool(certPEM string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	found := false
+	certPEMData := []byte(certPEM)
+	for len(certPEMData) > 0 {
+		var certBlock *pem.Block
+		certBlock, certPEMData = pem.Decode(certPEMData)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pool.AddCert(cert)
+		found = true
+	}
+	if !found {
+		return nil, errors.New("no certificates found")
+	}
+	return pool, nil
+}
+
 // ParseCertAndKey parses the given PEM-formatted X509 certificate
 // and RSA private key.
 func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
@@ -44,6 +44,19 @@ func (certSuite) TestParseCertificate(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "no certificates found")
 }
 
+func (certSuite) TestParseCertPool(c *gc.C) {
+	otherCertPEM, _, err := cert.NewCA("other", time.Now().AddDate(0, 0, 1))
+	c.Assert(err, jc.ErrorIsNil)
+
+	pool, err := cert.ParseCertPool(caCertPEM + otherCertPEM)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool.Subjects(), gc.HasLen, 2)
+
+	pool, err = cert.ParseCertPool(caKeyPEM)
+	c.Check(pool, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
 func (certSuite) TestParseCertAndKey(c *gc.C) {
 	xcert, key, err := cert.ParseCertAndKey(caCertPEM, caKeyPEM)
 	c.Assert(err, jc.ErrorIsNil)
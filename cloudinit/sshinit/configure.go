@@ -26,6 +26,10 @@ type ConfigureParams struct {
 	// If Client is nil, ssh.DefaultClient will be used.
 	Client ssh.Client
 
+	// Options holds the SSH options to connect with, such as
+	// the identities to authenticate with. It may be nil.
+	Options *ssh.Options
+
 	// Config is the cloudinit config to carry out.
 	Config *cloudinit.Config
 
@@ -54,7 +58,7 @@ func RunConfigureScript(script string, params ConfigureParams) error {
 	if client == nil {
 		client = ssh.DefaultClient
 	}
-	cmd := ssh.Command(params.Host, []string{"sudo", "/bin/bash"}, nil)
+	cmd := client.Command(params.Host, []string{"sudo", "/bin/bash"}, params.Options)
 	cmd.Stdin = strings.NewReader(script)
 	cmd.Stderr = params.ProgressWriter
 	return cmd.Run()
@@ -8,9 +8,11 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
 
 	"github.com/juju/juju/api/action"
+	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 )
@@ -35,7 +37,9 @@ func NewSuperCommand() cmd.Command {
 	actionCmd.Register(envcmd.Wrap(&DefinedCommand{}))
 	actionCmd.Register(envcmd.Wrap(&DoCommand{}))
 	actionCmd.Register(envcmd.Wrap(&FetchCommand{}))
+	actionCmd.Register(envcmd.Wrap(&SchedulesCommand{}))
 	actionCmd.Register(envcmd.Wrap(&StatusCommand{}))
+	actionCmd.Register(envcmd.Wrap(&UnscheduleCommand{}))
 	return actionCmd
 }
 
@@ -78,6 +82,25 @@ type APIClient interface {
 	// FindActionTagsByPrefix takes a list of string prefixes and finds
 	// corresponding ActionTags that match that prefix.
 	FindActionTagsByPrefix(params.FindTags) (params.FindTagsResults, error)
+
+	// Schedule takes a list of Actions and schedules each to be queued
+	// once at a future time, or repeatedly according to a cron
+	// expression.
+	Schedule(params.ActionScheduleArgs) (params.ActionScheduleResults, error)
+
+	// ListSchedules returns all the action schedules in the environment.
+	ListSchedules() (params.ActionScheduleResults, error)
+
+	// CancelSchedules cancels the action schedules with the given ids.
+	CancelSchedules(params.ActionScheduleIds) (params.ErrorResults, error)
+
+	// WatchOutput returns a NotifyWatcher which notifies when the given
+	// Action records output or changes status.
+	WatchOutput(names.ActionTag) (watcher.NotifyWatcher, error)
+
+	// Output returns the output recorded by the given Action while
+	// running, starting at the given chunk, and the Action's status.
+	Output(names.ActionTag, int) (params.ActionOutputResult, error)
 }
 
 // ActionCommandBase is the base type for action sub-commands.
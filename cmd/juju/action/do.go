@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -15,6 +16,7 @@ import (
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/utils/cron"
 )
 
 var keyRule = regexp.MustCompile("^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$")
@@ -27,6 +29,9 @@ type DoCommand struct {
 	actionName   string
 	paramsYAML   cmd.FileVar
 	parseStrings bool
+	schedule     string
+	scheduleAt   time.Time
+	scheduleCron string
 	out          cmd.Output
 	args         [][]string
 }
@@ -47,6 +52,12 @@ is a boolean true in YAML.
 If --params is passed, along with key.key...=value explicit arguments, the
 explicit arguments will override the parameter file.
 
+The Action may be scheduled to be queued later, rather than immediately, with
+the --schedule flag. Its value is either an RFC3339 time at which the Action is
+queued once, or a cron expression (evaluated in UTC) such as "0 3 * * *" or
+"@daily" according to which it is queued repeatedly. Scheduled Actions may be
+listed with "juju action schedules" and cancelled with "juju action unschedule".
+
 Examples:
 
 $ juju action do mysql/3 backup 
@@ -97,6 +108,10 @@ $ juju action do sleeper/0 pause time=1000
 $ juju action do sleeper/0 pause --string-args time=1000
 ...
 The value for the "time" param will be the string literal "1000".
+
+$ juju action do mysql/3 backup --schedule "30 2 * * *"
+Action scheduled with id: <schedule ID>
+Next run: 2015-07-16T02:30:00Z
 `
 
 // actionNameRule describes the format an action name must match to be valid.
@@ -107,6 +122,7 @@ func (c *DoCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
 	f.Var(&c.paramsYAML, "params", "path to yaml-formatted params file")
 	f.BoolVar(&c.parseStrings, "string-args", false, "use raw string values of CLI args")
+	f.StringVar(&c.schedule, "schedule", "", "queue the action at an RFC3339 time, or repeatedly as a cron expression dictates")
 }
 
 func (c *DoCommand) Info() *cmd.Info {
@@ -137,6 +153,9 @@ func (c *DoCommand) Init(args []string) error {
 		}
 		c.unitTag = names.NewUnitTag(unitName)
 		c.actionName = actionName
+		if err := c.parseSchedule(); err != nil {
+			return err
+		}
 		if len(args) == 2 {
 			return nil
 		}
@@ -161,6 +180,23 @@ func (c *DoCommand) Init(args []string) error {
 	}
 }
 
+// parseSchedule interprets the --schedule flag as either a time at
+// which to queue the action once, or a cron expression.
+func (c *DoCommand) parseSchedule() error {
+	if c.schedule == "" {
+		return nil
+	}
+	if at, err := time.Parse(time.RFC3339, c.schedule); err == nil {
+		c.scheduleAt = at
+		return nil
+	}
+	if _, err := cron.Parse(c.schedule); err != nil {
+		return errors.Errorf("schedule %q is neither an RFC3339 time nor a valid cron expression: %v", c.schedule, err)
+	}
+	c.scheduleCron = c.schedule
+	return nil
+}
+
 func (c *DoCommand) Run(ctx *cmd.Context) error {
 	api, err := c.NewActionAPIClient()
 	if err != nil {
@@ -221,12 +257,17 @@ func (c *DoCommand) Run(ctx *cmd.Context) error {
 		return errors.Errorf("params must be a map, got %T", typedConformantParams)
 	}
 
+	action := params.Action{
+		Receiver:   c.unitTag.String(),
+		Name:       c.actionName,
+		Parameters: actionParams,
+	}
+	if c.schedule != "" {
+		return c.scheduleAction(ctx, api, action)
+	}
+
 	actionParam := params.Actions{
-		Actions: []params.Action{{
-			Receiver:   c.unitTag.String(),
-			Name:       c.actionName,
-			Parameters: actionParams,
-		}},
+		Actions: []params.Action{action},
 	}
 
 	results, err := api.Enqueue(actionParam)
@@ -255,3 +296,33 @@ func (c *DoCommand) Run(ctx *cmd.Context) error {
 	output := map[string]string{"Action queued with id": tag.Id()}
 	return c.out.Write(ctx, output)
 }
+
+// scheduleAction schedules the action to be queued later, rather than
+// queueing it immediately.
+func (c *DoCommand) scheduleAction(ctx *cmd.Context, api APIClient, action params.Action) error {
+	results, err := api.Schedule(params.ActionScheduleArgs{
+		Schedules: []params.ActionScheduleArg{{
+			Action: action,
+			At:     c.scheduleAt,
+			Cron:   c.scheduleCron,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != 1 {
+		return errors.New("illegal number of results returned")
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.Schedule == nil {
+		return errors.New("action failed to schedule")
+	}
+	output := map[string]string{
+		"Action scheduled with id": result.Schedule.Id,
+		"Next run":                 result.Schedule.NextRun.UTC().Format(time.RFC3339),
+	}
+	return c.out.Write(ctx, output)
+}
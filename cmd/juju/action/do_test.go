@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"errors"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/juju/names"
@@ -182,6 +183,86 @@ func (s *DoSuite) TestInit(c *gc.C) {
 	}
 }
 
+func (s *DoSuite) TestInitSchedule(c *gc.C) {
+	for i, t := range []struct {
+		schedule    string
+		expectAt    time.Time
+		expectCron  string
+		expectError string
+	}{{
+		schedule: "2015-07-16T02:30:00Z",
+		expectAt: time.Date(2015, 7, 16, 2, 30, 0, 0, time.UTC),
+	}, {
+		schedule:   "30 2 * * *",
+		expectCron: "30 2 * * *",
+	}, {
+		schedule:   "@weekly",
+		expectCron: "@weekly",
+	}, {
+		schedule:    "tomorrow",
+		expectError: `schedule "tomorrow" is neither an RFC3339 time nor a valid cron expression: cron expression "tomorrow" must have 5 fields, got 1`,
+	}} {
+		c.Logf("test %d: --schedule %q", i, t.schedule)
+		s.subcommand = &action.DoCommand{}
+		err := testing.InitCommand(s.subcommand, []string{validUnitId, "valid-action-name", "--schedule", t.schedule})
+		if t.expectError != "" {
+			c.Check(err, gc.ErrorMatches, t.expectError)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(s.subcommand.ScheduleAt().Equal(t.expectAt), jc.IsTrue)
+		c.Check(s.subcommand.ScheduleCron(), gc.Equals, t.expectCron)
+	}
+}
+
+func (s *DoSuite) TestRunScheduled(c *gc.C) {
+	nextRun := time.Date(2015, 7, 16, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{Id: "some-schedule-id", NextRun: nextRun},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	s.subcommand = &action.DoCommand{}
+	ctx, err := testing.RunCommand(c, s.subcommand, validUnitId, "some-action", "out=x", "--schedule", "30 2 * * *")
+	c.Assert(err, jc.ErrorIsNil)
+
+	resultMap := make(map[string]string)
+	err = yaml.Unmarshal(ctx.Stdout.(*bytes.Buffer).Bytes(), &resultMap)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(resultMap, jc.DeepEquals, map[string]string{
+		"Action scheduled with id": "some-schedule-id",
+		"Next run":                 "2015-07-16T02:30:00Z",
+	})
+	c.Check(fakeClient.scheduledActions, jc.DeepEquals, params.ActionScheduleArgs{
+		Schedules: []params.ActionScheduleArg{{
+			Action: params.Action{
+				Receiver:   names.NewUnitTag(validUnitId).String(),
+				Name:       "some-action",
+				Parameters: map[string]interface{}{"out": "x"},
+			},
+			Cron: "30 2 * * *",
+		}},
+	})
+	c.Check(fakeClient.EnqueuedActions().Actions, gc.HasLen, 0)
+}
+
+func (s *DoSuite) TestRunScheduledError(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		scheduleResults: []params.ActionScheduleResult{{
+			Error: common.ServerError(errors.New(`action "some-action" not defined on unit "mysql/0"`)),
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	s.subcommand = &action.DoCommand{}
+	_, err := testing.RunCommand(c, s.subcommand, validUnitId, "some-action", "--schedule", "@daily")
+	c.Assert(err, gc.ErrorMatches, `action "some-action" not defined on unit "mysql/0"`)
+}
+
 func (s *DoSuite) TestRun(c *gc.C) {
 	tests := []struct {
 		should                 string
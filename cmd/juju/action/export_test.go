@@ -4,6 +4,8 @@
 package action
 
 import (
+	"time"
+
 	"github.com/juju/names"
 
 	"github.com/juju/juju/apiserver/params"
@@ -41,6 +43,14 @@ func (c *DoCommand) ParseStrings() bool {
 	return c.parseStrings
 }
 
+func (c *DoCommand) ScheduleAt() time.Time {
+	return c.scheduleAt
+}
+
+func (c *DoCommand) ScheduleCron() string {
+	return c.scheduleCron
+}
+
 func ActionResultsToMap(results []params.ActionResult) map[string]interface{} {
 	return resultsToMap(results)
 }
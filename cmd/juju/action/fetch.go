@@ -4,6 +4,7 @@
 package action
 
 import (
+	"io"
 	"regexp"
 	"time"
 
@@ -21,6 +22,7 @@ type FetchCommand struct {
 	requestedId string
 	fullSchema  bool
 	wait        string
+	watch       bool
 }
 
 const fetchDoc = `
@@ -32,12 +34,17 @@ to wait indefinitely.  If units are left off, seconds are assumed.
 The default behavior without --wait is to immediately check and return; if
 the results are "pending" then only the available information will be
 displayed.  This is also the behavior when any negative time is given.
+
+To follow a running action, use the --watch flag.  The output the action
+writes to stdout and stderr is then shown as it is written, and the results
+are displayed once the action has completed or failed.
 `
 
 // Set up the output.
 func (c *FetchCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
 	f.StringVar(&c.wait, "wait", "-1s", "wait for results")
+	f.BoolVar(&c.watch, "watch", false, "show the action's output as it runs, then its results")
 }
 
 func (c *FetchCommand) Info() *cmd.Info {
@@ -83,6 +90,12 @@ func (c *FetchCommand) Run(ctx *cmd.Context) error {
 	}
 	defer api.Close()
 
+	if c.watch {
+		if err := followOutput(ctx, api, c.requestedId); err != nil {
+			return err
+		}
+	}
+
 	// tick every two seconds, to delay the loop timer.
 	tick := time.NewTimer(2 * time.Second)
 	wait := time.NewTimer(0 * time.Second)
@@ -143,6 +156,50 @@ func timerLoop(api APIClient, requestedId string, wait, tick *time.Timer) (param
 	}
 }
 
+// followOutput copies the output written by the given action to the
+// context's stdout and stderr as it is recorded, until the action is
+// no longer running or pending.
+func followOutput(ctx *cmd.Context, api APIClient, requestedId string) error {
+	actionTag, err := getActionTagByPrefix(api, requestedId)
+	if err != nil {
+		return err
+	}
+	w, err := api.WatchOutput(actionTag)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	from := 0
+	for {
+		result, err := api.Output(actionTag, from)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range result.Chunks {
+			out := ctx.Stdout
+			if chunk.Stream == params.ActionStderr {
+				out = ctx.Stderr
+			}
+			if _, err := io.WriteString(out, chunk.Data); err != nil {
+				return err
+			}
+			from = chunk.Seq + 1
+		}
+		switch result.Status {
+		case params.ActionRunning, params.ActionPending:
+		default:
+			return nil
+		}
+		if _, ok := <-w.Changes(); !ok {
+			if err := w.Err(); err != nil {
+				return err
+			}
+			return errors.New("action output watcher stopped")
+		}
+	}
+}
+
 // fetchResult queries the given API for the given Action ID prefix, and
 // makes sure the results are acceptable, returning an error if they are not.
 func fetchResult(api APIClient, requestedId string) (params.ActionResult, error) {
@@ -321,3 +321,53 @@ func makeFakeClient(
 	}
 	return client
 }
+
+func (s *FetchSuite) TestRunWatch(c *gc.C) {
+	client := makeFakeClient(
+		0,
+		10*time.Second,
+		tagsForIdPrefix(validActionId, validActionTagString),
+		[]params.ActionResult{{Status: params.ActionCompleted}},
+		"",
+	)
+	client.outputResults = []params.ActionOutputResult{{
+		Status: params.ActionRunning,
+		Chunks: []params.ActionOutputChunk{
+			{Seq: 0, Stream: params.ActionStdout, Data: "starting\n"},
+			{Seq: 1, Stream: params.ActionStderr, Data: "warning\n"},
+		},
+	}, {
+		Status: params.ActionRunning,
+	}, {
+		Status: params.ActionCompleted,
+		Chunks: []params.ActionOutputChunk{
+			{Seq: 2, Stream: params.ActionStdout, Data: "done\n"},
+		},
+	}}
+	unpatch := s.BaseActionSuite.patchAPIClient(client)
+	defer unpatch()
+
+	ctx, err := testing.RunCommand(c, &action.FetchCommand{}, validActionId, "--watch")
+	c.Assert(err, gc.IsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, "starting\ndone\nstatus: completed\n")
+	c.Check(testing.Stderr(ctx), gc.Equals, "warning\n")
+	c.Check(client.outputQueries, gc.DeepEquals, []int{0, 2, 2})
+}
+
+func (s *FetchSuite) TestRunWatchWatcherStopped(c *gc.C) {
+	client := makeFakeClient(
+		0,
+		10*time.Second,
+		tagsForIdPrefix(validActionId, validActionTagString),
+		nil,
+		"",
+	)
+	client.outputResults = []params.ActionOutputResult{{
+		Status: params.ActionPending,
+	}}
+	unpatch := s.BaseActionSuite.patchAPIClient(client)
+	defer unpatch()
+
+	_, err := testing.RunCommand(c, &action.FetchCommand{}, validActionId, "--watch")
+	c.Assert(err, gc.ErrorMatches, "action output watcher stopped")
+}
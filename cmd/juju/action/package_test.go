@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/names"
 	jujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v4"
 
+	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/action"
@@ -136,6 +138,12 @@ type fakeAPIClient struct {
 	actionsByReceivers []params.ActionsByReceiver
 	actionTagMatches   params.FindTagsResults
 	charmActions       *charm.Actions
+	scheduleResults    []params.ActionScheduleResult
+	scheduledActions   params.ActionScheduleArgs
+	cancelledSchedules params.ActionScheduleIds
+	errorResults       []params.ErrorResult
+	outputResults      []params.ActionOutputResult
+	outputQueries      []int
 	apiErr             error
 }
 
@@ -214,3 +222,56 @@ func (c *fakeAPIClient) Actions(args params.Entities) (params.ActionResults, err
 func (c *fakeAPIClient) FindActionTagsByPrefix(arg params.FindTags) (params.FindTagsResults, error) {
 	return c.actionTagMatches, c.apiErr
 }
+
+func (c *fakeAPIClient) Schedule(args params.ActionScheduleArgs) (params.ActionScheduleResults, error) {
+	c.scheduledActions = args
+	return params.ActionScheduleResults{Results: c.scheduleResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) ListSchedules() (params.ActionScheduleResults, error) {
+	return params.ActionScheduleResults{Results: c.scheduleResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) CancelSchedules(args params.ActionScheduleIds) (params.ErrorResults, error) {
+	c.cancelledSchedules = args
+	return params.ErrorResults{Results: c.errorResults}, c.apiErr
+}
+
+func (c *fakeAPIClient) WatchOutput(tag names.ActionTag) (watcher.NotifyWatcher, error) {
+	if c.apiErr != nil {
+		return nil, c.apiErr
+	}
+	// Notify once for each output result after the first.
+	w := &fakeNotifyWatcher{changes: make(chan struct{}, len(c.outputResults))}
+	for i := 1; i < len(c.outputResults); i++ {
+		w.changes <- struct{}{}
+	}
+	close(w.changes)
+	return w, nil
+}
+
+func (c *fakeAPIClient) Output(tag names.ActionTag, from int) (params.ActionOutputResult, error) {
+	c.outputQueries = append(c.outputQueries, from)
+	if len(c.outputResults) == 0 {
+		return params.ActionOutputResult{}, errors.New("no more output")
+	}
+	result := c.outputResults[0]
+	c.outputResults = c.outputResults[1:]
+	return result, nil
+}
+
+type fakeNotifyWatcher struct {
+	changes chan struct{}
+}
+
+func (w *fakeNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *fakeNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (w *fakeNotifyWatcher) Err() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// SchedulesCommand lists the scheduled Actions in the environment.
+type SchedulesCommand struct {
+	ActionCommandBase
+	out cmd.Output
+}
+
+const schedulesDoc = `
+List the Actions scheduled with "juju action do --schedule", in the order in
+which they next run. Schedules may be cancelled with "juju action unschedule".
+`
+
+// SetFlags offers an option for YAML output.
+func (c *SchedulesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *SchedulesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "schedules",
+		Purpose: "list scheduled actions",
+		Doc:     schedulesDoc,
+	}
+}
+
+func (c *SchedulesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *SchedulesCommand) Run(ctx *cmd.Context) error {
+	api, err := c.NewActionAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	results, err := api.ListSchedules()
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, schedulesToMap(results.Results))
+}
+
+func schedulesToMap(results []params.ActionScheduleResult) map[string]interface{} {
+	items := []map[string]interface{}{}
+	for _, result := range results {
+		item := map[string]interface{}{}
+		if result.Error != nil {
+			item["error"] = result.Error.Error()
+		}
+		if sched := result.Schedule; sched != nil {
+			item["id"] = sched.Id
+			item["action"] = sched.Action.Name
+			if len(sched.Action.Parameters) > 0 {
+				item["parameters"] = sched.Action.Parameters
+			}
+			if rtag, err := names.ParseUnitTag(sched.Action.Receiver); err != nil {
+				item["unit"] = sched.Action.Receiver
+			} else {
+				item["unit"] = rtag.Id()
+			}
+			if sched.Cron != "" {
+				item["schedule"] = sched.Cron
+			} else {
+				item["schedule"] = "once"
+			}
+			item["next-run"] = sched.NextRun.UTC().Format(time.RFC3339)
+			if atag, err := names.ParseActionTag(sched.LastAction); err == nil {
+				item["last-run"] = sched.LastRun.UTC().Format(time.RFC3339)
+				item["last-action"] = atag.Id()
+			}
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{"schedules": items}
+}
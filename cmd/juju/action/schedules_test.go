@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/action"
+	"github.com/juju/juju/testing"
+)
+
+type SchedulesSuite struct {
+	BaseActionSuite
+	subcommand *action.SchedulesCommand
+}
+
+var _ = gc.Suite(&SchedulesSuite{})
+
+func (s *SchedulesSuite) SetUpTest(c *gc.C) {
+	s.BaseActionSuite.SetUpTest(c)
+	s.subcommand = &action.SchedulesCommand{}
+}
+
+func (s *SchedulesSuite) TestHelp(c *gc.C) {
+	s.checkHelp(c, s.subcommand)
+}
+
+func (s *SchedulesSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(s.subcommand, []string{"foo"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+func (s *SchedulesSuite) TestRun(c *gc.C) {
+	nextRun := time.Date(2015, 7, 16, 2, 30, 0, 0, time.UTC)
+	fakeClient := &fakeAPIClient{
+		scheduleResults: []params.ActionScheduleResult{{
+			Schedule: &params.ActionSchedule{
+				Id: "once-id",
+				Action: params.Action{
+					Receiver:   "unit-mysql-0",
+					Name:       "snapshot",
+					Parameters: map[string]interface{}{"outfile": "out.bz2"},
+				},
+				NextRun: nextRun,
+			},
+		}, {
+			Schedule: &params.ActionSchedule{
+				Id: "cron-id",
+				Action: params.Action{
+					Receiver: "unit-mysql-1",
+					Name:     "backup",
+				},
+				Cron:       "30 2 * * *",
+				NextRun:    nextRun.Add(24 * time.Hour),
+				LastRun:    nextRun.Add(-time.Minute),
+				LastAction: "action-f47ac10b-58cc-4372-a567-0e02b2c3d479",
+			},
+		}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := testing.RunCommand(c, s.subcommand, "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+schedules:
+- action: snapshot
+  id: once-id
+  next-run: "2015-07-16T02:30:00Z"
+  parameters:
+    outfile: out.bz2
+  schedule: once
+  unit: mysql/0
+- action: backup
+  id: cron-id
+  last-action: f47ac10b-58cc-4372-a567-0e02b2c3d479
+  last-run: "2015-07-16T02:29:00Z"
+  next-run: "2015-07-17T02:30:00Z"
+  schedule: 30 2 * * *
+  unit: mysql/1
+`[1:])
+}
+
+func (s *SchedulesSuite) TestRunError(c *gc.C) {
+	fakeClient := &fakeAPIClient{apiErr: errors.New("boom")}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	_, err := testing.RunCommand(c, s.subcommand)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// UnscheduleCommand cancels scheduled Actions.
+type UnscheduleCommand struct {
+	ActionCommandBase
+	ids []string
+}
+
+const unscheduleDoc = `
+Cancel the Action schedules with the given IDs, as shown by
+"juju action schedules", so that they no longer queue Actions. Actions already
+queued by the schedules are unaffected.
+`
+
+func (c *UnscheduleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "unschedule",
+		Args:    "<schedule ID> [...]",
+		Purpose: "cancel scheduled actions",
+		Doc:     unscheduleDoc,
+	}
+}
+
+func (c *UnscheduleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no schedule ID specified")
+	}
+	c.ids = args
+	return nil
+}
+
+func (c *UnscheduleCommand) Run(ctx *cmd.Context) error {
+	api, err := c.NewActionAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	results, err := api.CancelSchedules(params.ActionScheduleIds{Ids: c.ids})
+	if err != nil {
+		return err
+	}
+	if len(results.Results) != len(c.ids) {
+		return errors.New("illegal number of results returned")
+	}
+	failed := false
+	for i, result := range results.Results {
+		if result.Error != nil {
+			ctx.Infof("cannot cancel schedule %q: %v", c.ids[i], result.Error)
+			failed = true
+		}
+	}
+	if failed {
+		return cmd.ErrSilent
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package action_test
+
+import (
+	"errors"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/action"
+	"github.com/juju/juju/testing"
+)
+
+type UnscheduleSuite struct {
+	BaseActionSuite
+	subcommand *action.UnscheduleCommand
+}
+
+var _ = gc.Suite(&UnscheduleSuite{})
+
+func (s *UnscheduleSuite) SetUpTest(c *gc.C) {
+	s.BaseActionSuite.SetUpTest(c)
+	s.subcommand = &action.UnscheduleCommand{}
+}
+
+func (s *UnscheduleSuite) TestHelp(c *gc.C) {
+	s.checkHelp(c, s.subcommand)
+}
+
+func (s *UnscheduleSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(s.subcommand, nil)
+	c.Assert(err, gc.ErrorMatches, "no schedule ID specified")
+}
+
+func (s *UnscheduleSuite) TestRun(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		errorResults: []params.ErrorResult{{}, {}},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := testing.RunCommand(c, s.subcommand, "id-1", "id-2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "")
+	c.Assert(fakeClient.cancelledSchedules, jc.DeepEquals, params.ActionScheduleIds{
+		Ids: []string{"id-1", "id-2"},
+	})
+}
+
+func (s *UnscheduleSuite) TestRunPartialFailure(c *gc.C) {
+	fakeClient := &fakeAPIClient{
+		errorResults: []params.ErrorResult{
+			{Error: common.ServerError(errors.New(`action schedule "id-1" not found`))},
+			{},
+		},
+	}
+	restore := s.patchAPIClient(fakeClient)
+	defer restore()
+
+	ctx, err := testing.RunCommand(c, s.subcommand, "id-1", "id-2")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, `cannot cancel schedule "id-1": action schedule "id-1" not found`+"\n")
+}
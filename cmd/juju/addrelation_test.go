@@ -43,7 +43,7 @@ var addRelationTests = []struct {
 }{
 	{
 		args: []string{"rk", "ms"},
-		err:  "no relations found",
+		err:  `no relations found: "rk" and "ms" have no relation interfaces in common`,
 	}, {
 		err: "a relation must involve two services",
 	}, {
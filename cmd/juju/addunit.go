@@ -6,16 +6,15 @@ package main
 import (
 	"errors"
 	"fmt"
-	"regexp"
 
 	"github.com/juju/cmd"
-	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider"
 )
 
@@ -28,7 +27,7 @@ type UnitCommandBase struct {
 
 func (c *UnitCommandBase) SetFlags(f *gnuflag.FlagSet) {
 	f.IntVar(&c.NumUnits, "num-units", 1, "")
-	f.StringVar(&c.ToMachineSpec, "to", "", "the machine or container to deploy the unit in, bypasses constraints")
+	f.StringVar(&c.ToMachineSpec, "to", "", "the machine, container or placement directive to deploy the unit to, bypasses constraints")
 }
 
 func (c *UnitCommandBase) Init(args []string) error {
@@ -39,7 +38,7 @@ func (c *UnitCommandBase) Init(args []string) error {
 		if c.NumUnits > 1 {
 			return errors.New("cannot use --num-units > 1 with --to")
 		}
-		if !isMachineOrNewContainer(c.ToMachineSpec) {
+		if !isValidPlacement(c.ToMachineSpec) {
 			return fmt.Errorf("invalid --to parameter %q", c.ToMachineSpec)
 		}
 
@@ -82,13 +81,18 @@ have already been deployed via juju deploy.
 
 By default, services are deployed to newly provisioned machines.  Alternatively,
 service units can be added to a specific existing machine using the --to
-argument.
+argument. The --to argument may also be a placement directive understood by
+the environment's provider, such as an availability zone; the unit is then
+added to a newly provisioned machine chosen according to the directive.
 
 Examples:
  juju add-unit mysql -n 5          (Add 5 mysql units on 5 new machines)
  juju add-unit mysql --to 23       (Add a mysql unit to machine 23)
  juju add-unit mysql --to 24/lxc/3 (Add unit to lxc container 3 on host machine 24)
  juju add-unit mysql --to lxc:25   (Add unit to a new lxc container on host machine 25)
+ juju add-unit mysql --to lxc      (Add unit to a new lxc container on a new machine)
+ juju add-unit mysql --to zone=us-east-1a
+                                   (Add unit to a new machine in zone us-east-1a)
 `
 
 func (c *AddUnitCommand) Info() *cmd.Info {
@@ -137,19 +141,12 @@ func (c *AddUnitCommand) Run(_ *cmd.Context) error {
 	}
 
 	_, err = apiclient.AddServiceUnits(c.ServiceName, c.NumUnits, c.ToMachineSpec)
-	return block.ProcessBlockedError(err, block.BlockChange)
+	return block.ProcessBlockedError(err, block.BlockBudget)
 }
 
-const (
-	deployTarget = "^(" + names.ContainerTypeSnippet + ":)?" + names.MachineSnippet + "$"
-)
-
-var (
-	validMachineOrNewContainer = regexp.MustCompile(deployTarget)
-)
-
-// isMachineOrNewContainer returns whether spec is a valid machine id
-// or new container definition.
-func isMachineOrNewContainer(spec string) bool {
-	return validMachineOrNewContainer.MatchString(spec)
+// isValidPlacement returns whether spec is a valid machine id, new
+// container definition or provider placement directive.
+func isValidPlacement(spec string) bool {
+	_, err := instance.ParseUnitPlacement(spec)
+	return err == nil
 }
@@ -223,24 +223,28 @@ type namesSuite struct {
 
 var _ = gc.Suite(&namesSuite{})
 
-func (*namesSuite) TestNameChecks(c *gc.C) {
-	assertMachineOrNewContainer := func(s string, expect bool) {
+func (*namesSuite) TestPlacementChecks(c *gc.C) {
+	assertValidPlacement := func(s string, expect bool) {
 		c.Logf("%s -> %v", s, expect)
-		c.Assert(isMachineOrNewContainer(s), gc.Equals, expect)
+		c.Assert(isValidPlacement(s), gc.Equals, expect)
 	}
-	assertMachineOrNewContainer("0", true)
-	assertMachineOrNewContainer("00", false)
-	assertMachineOrNewContainer("1", true)
-	assertMachineOrNewContainer("0/lxc/0", true)
-	assertMachineOrNewContainer("lxc:0", true)
-	assertMachineOrNewContainer("lxc:lxc:0", false)
-	assertMachineOrNewContainer("kvm:0/lxc/1", true)
-	assertMachineOrNewContainer("lxc:", false)
-	assertMachineOrNewContainer(":lxc", false)
-	assertMachineOrNewContainer("0/lxc/", false)
-	assertMachineOrNewContainer("0/lxc", false)
-	assertMachineOrNewContainer("kvm:0/lxc", false)
-	assertMachineOrNewContainer("0/lxc/01", false)
-	assertMachineOrNewContainer("0/lxc/10", true)
-	assertMachineOrNewContainer("0/kvm/4", true)
+	assertValidPlacement("0", true)
+	assertValidPlacement("00", false)
+	assertValidPlacement("1", true)
+	assertValidPlacement("0/lxc/0", true)
+	assertValidPlacement("lxc:0", true)
+	assertValidPlacement("lxc:lxc:0", false)
+	assertValidPlacement("kvm:0/lxc/1", true)
+	assertValidPlacement("lxc:", false)
+	assertValidPlacement(":lxc", false)
+	assertValidPlacement("0/lxc/", false)
+	assertValidPlacement("0/lxc", false)
+	assertValidPlacement("kvm:0/lxc", false)
+	assertValidPlacement("0/lxc/01", false)
+	assertValidPlacement("0/lxc/10", true)
+	assertValidPlacement("0/kvm/4", true)
+	assertValidPlacement("lxc", true)
+	assertValidPlacement("zone=us-east-1a", true)
+	assertValidPlacement("env-name:node-1.maas", true)
+	assertValidPlacement("node-1.maas", false)
 }
@@ -13,7 +13,9 @@ import (
 
 var authKeysDoc = `
 "juju authorized-keys" is used to manage the ssh keys allowed to log on to
-nodes in the Juju environment.
+nodes in the Juju environment. Each user of the environment has their own
+keys; the keys of all users are authorised on every machine, and each
+machine's agent removes keys from it as soon as they are deleted or expire.
 
 `
 
@@ -6,21 +6,33 @@ package main
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/block"
 )
 
 var addKeysDoc = `
 Add new authorized ssh keys to allow the holder of those keys to log on to Juju nodes or machines.
+
+Each user's keys are kept separately; the environment owner can manage the
+keys of any user of the environment, and other users their own. Keys added
+with --expires stop being authorised, and are removed from the machines,
+once the given duration has passed.
+
+Examples:
+    # Allow a key to log on for the next day
+    $ juju authorized-keys add --expires 24h "ssh-rsa AAAA... user@host"
 `
 
 // AddKeysCommand is used to add a new authorized ssh key for a user.
 type AddKeysCommand struct {
 	AuthorizedKeysBase
 	user    string
+	expires time.Duration
 	sshKeys []string
 }
 
@@ -34,6 +46,9 @@ func (c *AddKeysCommand) Info() *cmd.Info {
 }
 
 func (c *AddKeysCommand) Init(args []string) error {
+	if c.expires < 0 {
+		return errors.New("--expires must not be negative")
+	}
 	switch len(args) {
 	case 0:
 		return errors.New("no ssh key specified")
@@ -45,6 +60,7 @@ func (c *AddKeysCommand) Init(args []string) error {
 
 func (c *AddKeysCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.user, "user", "admin", "the user for which to add the keys")
+	f.DurationVar(&c.expires, "expires", 0, "how long the keys are authorised for")
 }
 
 func (c *AddKeysCommand) Run(context *cmd.Context) error {
@@ -54,7 +70,12 @@ func (c *AddKeysCommand) Run(context *cmd.Context) error {
 	}
 	defer client.Close()
 
-	results, err := client.AddKeys(c.user, c.sshKeys...)
+	var results []params.ErrorResult
+	if c.expires > 0 {
+		results, err = client.AddExpiringKeys(c.user, time.Now().Add(c.expires), c.sshKeys...)
+	} else {
+		results, err = client.AddKeys(c.user, c.sshKeys...)
+	}
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
@@ -6,22 +6,34 @@ package main
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/juju/cmd"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/block"
 )
 
 var importKeysDoc = `
 Import new authorized ssh keys to allow the holder of those keys to log on to Juju nodes or machines.
-The keys are imported using ssh-import-id.
+The keys are imported by the Juju API server: the keys of GitHub users,
+identified as gh:<user>, are fetched from GitHub, and all others, such as
+those of Launchpad users identified as lp:<user>, using ssh-import-id.
+
+As with "juju authorized-keys add", keys imported with --expires stop
+being authorised once the given duration has passed.
+
+Examples:
+    $ juju authorized-keys import gh:someone
+    $ juju import-ssh-key --expires 8h lp:someone
 `
 
 // ImportKeysCommand is used to add new authorized ssh keys for a user.
 type ImportKeysCommand struct {
 	AuthorizedKeysBase
 	user      string
+	expires   time.Duration
 	sshKeyIds []string
 }
 
@@ -35,6 +47,9 @@ func (c *ImportKeysCommand) Info() *cmd.Info {
 }
 
 func (c *ImportKeysCommand) Init(args []string) error {
+	if c.expires < 0 {
+		return errors.New("--expires must not be negative")
+	}
 	switch len(args) {
 	case 0:
 		return errors.New("no ssh key id specified")
@@ -46,6 +61,7 @@ func (c *ImportKeysCommand) Init(args []string) error {
 
 func (c *ImportKeysCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.user, "user", "admin", "the user for which to import the keys")
+	f.DurationVar(&c.expires, "expires", 0, "how long the keys are authorised for")
 }
 
 func (c *ImportKeysCommand) Run(context *cmd.Context) error {
@@ -55,7 +71,12 @@ func (c *ImportKeysCommand) Run(context *cmd.Context) error {
 	}
 	defer client.Close()
 
-	results, err := client.ImportKeys(c.user, c.sshKeyIds...)
+	var results []params.ErrorResult
+	if c.expires > 0 {
+		results, err = client.ImportExpiringKeys(c.user, time.Now().Add(c.expires), c.sshKeyIds...)
+	} else {
+		results, err = client.ImportKeys(c.user, c.sshKeyIds...)
+	}
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
@@ -6,7 +6,9 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -115,6 +117,16 @@ func (s *keySuiteBase) assertEnvironKeys(c *gc.C, expected ...string) {
 	c.Assert(keys, gc.Equals, strings.Join(expected, "\n"))
 }
 
+func (s *keySuiteBase) assertUserKeys(c *gc.C, user string, expected ...string) {
+	sshKeys, err := s.State.SSHKeys(names.NewUserTag(user))
+	c.Assert(err, jc.ErrorIsNil)
+	var keys []string
+	for _, sshKey := range sshKeys {
+		keys = append(keys, sshKey.Key())
+	}
+	c.Assert(keys, jc.SameContents, expected)
+}
+
 type ListKeysSuite struct {
 	keySuiteBase
 }
@@ -148,14 +160,16 @@ func (s *ListKeysSuite) TestListFullKeys(c *gc.C) {
 func (s *ListKeysSuite) TestListKeysNonDefaultUser(c *gc.C) {
 	key1 := sshtesting.ValidKeyOne.Key + " user@host"
 	key2 := sshtesting.ValidKeyTwo.Key + " another@host"
-	s.setAuthorizedKeys(c, key1, key2)
-	s.Factory.MakeUser(c, &factory.UserParams{Name: "fred"})
+	s.setAuthorizedKeys(c, key1)
+	fred := s.Factory.MakeUser(c, &factory.UserParams{Name: "fred"}).UserTag()
+	_, err := s.State.AddSSHKey(fred, key2, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
 
 	context, err := coretesting.RunCommand(c, envcmd.Wrap(&ListKeysCommand{}), "--user", "fred")
 	c.Assert(err, jc.ErrorIsNil)
 	output := strings.TrimSpace(coretesting.Stdout(context))
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(output, gc.Matches, "Keys for user fred:\n.*\\(user@host\\)\n.*\\(another@host\\)")
+	c.Assert(output, gc.Matches, "Keys for user fred:\n.*\\(another@host\\)")
 }
 
 func (s *ListKeysSuite) TestTooManyArgs(c *gc.C) {
@@ -200,7 +214,33 @@ func (s *AddKeySuite) TestAddKeyNonDefaultUser(c *gc.C) {
 	context, err := coretesting.RunCommand(c, envcmd.Wrap(&AddKeysCommand{}), "--user", "fred", key2)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(coretesting.Stderr(context), gc.Equals, "")
-	s.assertEnvironKeys(c, key1, key2)
+	s.assertEnvironKeys(c, key1)
+	s.assertUserKeys(c, "fred", key2)
+}
+
+func (s *AddKeySuite) TestAddKeyExpires(c *gc.C) {
+	key1 := sshtesting.ValidKeyOne.Key + " user@host"
+	s.setAuthorizedKeys(c, key1)
+
+	key2 := sshtesting.ValidKeyTwo.Key + " another@host"
+	before := time.Now()
+	context, err := coretesting.RunCommand(c, envcmd.Wrap(&AddKeysCommand{}), "--expires", "1h", key2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stderr(context), gc.Equals, "")
+	s.assertEnvironKeys(c, key1)
+	s.assertUserKeys(c, "admin", key2)
+
+	sshKey, err := s.State.SSHKey(names.NewUserTag("admin"), sshtesting.ValidKeyTwo.Fingerprint)
+	c.Assert(err, jc.ErrorIsNil)
+	expires, ok := sshKey.Expires()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(expires.Before(before.Add(time.Hour)), jc.IsFalse)
+	c.Assert(expires.After(time.Now().Add(time.Hour)), jc.IsFalse)
+}
+
+func (s *AddKeySuite) TestAddKeyNegativeExpires(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&AddKeysCommand{}), "--expires", "-1h", sshtesting.ValidKeyTwo.Key)
+	c.Assert(err, gc.ErrorMatches, "--expires must not be negative")
 }
 
 type DeleteKeySuite struct {
@@ -236,14 +276,17 @@ func (s *DeleteKeySuite) TestBlockDeleteKeys(c *gc.C) {
 func (s *DeleteKeySuite) TestDeleteKeyNonDefaultUser(c *gc.C) {
 	key1 := sshtesting.ValidKeyOne.Key + " user@host"
 	key2 := sshtesting.ValidKeyTwo.Key + " another@host"
-	s.setAuthorizedKeys(c, key1, key2)
-	s.Factory.MakeUser(c, &factory.UserParams{Name: "fred"})
+	s.setAuthorizedKeys(c, key1)
+	fred := s.Factory.MakeUser(c, &factory.UserParams{Name: "fred"}).UserTag()
+	_, err := s.State.AddSSHKey(fred, key2, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
 
 	context, err := coretesting.RunCommand(c, envcmd.Wrap(&DeleteKeysCommand{}),
 		"--user", "fred", sshtesting.ValidKeyTwo.Fingerprint)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(coretesting.Stderr(context), gc.Equals, "")
 	s.assertEnvironKeys(c, key1)
+	s.assertUserKeys(c, "fred")
 }
 
 type ImportKeySuite struct {
@@ -285,5 +328,6 @@ func (s *ImportKeySuite) TestImportKeyNonDefaultUser(c *gc.C) {
 	context, err := coretesting.RunCommand(c, envcmd.Wrap(&ImportKeysCommand{}), "--user", "fred", "lp:validuser")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(coretesting.Stderr(context), gc.Equals, "")
-	s.assertEnvironKeys(c, key1, sshtesting.ValidKeyThree.Key)
+	s.assertEnvironKeys(c, key1)
+	s.assertUserKeys(c, "fred", sshtesting.ValidKeyThree.Key)
 }
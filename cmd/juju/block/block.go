@@ -198,3 +198,81 @@ func (c *ChangeCommand) Info() *cmd.Info {
 func (c *ChangeCommand) Run(_ *cmd.Context) error {
 	return c.internalRun(c.Info().Name)
 }
+
+// BudgetCommand blocks commands that may add to the cost of the environment.
+type BudgetCommand struct {
+	BaseBlockCommand
+}
+
+var budgetBlockDoc = `
+
+This command allows to block all operations that may add to the cost
+of running Juju environment, by provisioning machines or units.
+
+To disable the block, run unblock command - see "juju help unblock". 
+
+"juju block budget-affecting" blocks these commands:
+    add-machine
+    add-unit
+    deploy
+    ensure-availability
+
+These commands are also blocked by "juju block all-changes".
+   
+Examples:
+   To prevent the environment from growing:
+   juju block budget-affecting
+
+`
+
+// Info provides information about command.
+// Satisfying Command interface.
+func (c *BudgetCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "budget-affecting",
+		Purpose: "block operations that could add to the cost of Juju environment",
+		Doc:     budgetBlockDoc,
+	}
+}
+
+// Satisfying Command interface.
+func (c *BudgetCommand) Run(_ *cmd.Context) error {
+	return c.internalRun(c.Info().Name)
+}
+
+// RebootCommand blocks charms from rebooting machines.
+type RebootCommand struct {
+	BaseBlockCommand
+}
+
+var rebootBlockDoc = `
+
+This command allows to block charms from rebooting the machines
+of Juju environment. Reboots requested by a charm while the block
+is in place fail, and the charm hook reports the error.
+
+To disable the block, run unblock command - see "juju help unblock". 
+
+"juju block machine-reboot" blocks the juju-reboot hook tool.
+Reboots are not blocked by "juju block all-changes".
+   
+Examples:
+   To prevent charms from rebooting machines:
+   juju block machine-reboot
+
+`
+
+// Info provides information about command.
+// Satisfying Command interface.
+func (c *RebootCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "machine-reboot",
+		Purpose: "block charms from rebooting machines",
+		Doc:     rebootBlockDoc,
+	}
+}
+
+// Satisfying Command interface.
+func (c *RebootCommand) Run(_ *cmd.Context) error {
+	return c.internalRun(c.Info().Name)
+}
@@ -74,6 +74,20 @@ func (s *BlockCommandSuite) TestBlockChangeOperations(c *gc.C) {
 	s.assertBlock(c, command.Info().Name, "TestBlockChangeOperations")
 }
 
+func (s *BlockCommandSuite) TestBlockBudgetOperations(c *gc.C) {
+	command := block.BudgetCommand{}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&command), "TestBlockBudgetOperations")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertBlock(c, command.Info().Name, "TestBlockBudgetOperations")
+}
+
+func (s *BlockCommandSuite) TestBlockRebootOperations(c *gc.C) {
+	command := block.RebootCommand{}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&command), "TestBlockRebootOperations")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertBlock(c, command.Info().Name, "TestBlockRebootOperations")
+}
+
 func (s *BlockCommandSuite) processErrorTest(c *gc.C, tstError error, blockType block.Block, expectedError error, expectedWarning string) {
 	if tstError != nil {
 		c.Assert(errors.Cause(block.ProcessBlockedError(tstError, blockType)), gc.Equals, expectedError)
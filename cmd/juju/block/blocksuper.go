@@ -42,6 +42,8 @@ func NewSuperBlockCommand() cmd.Command {
 	blockcmd.Register(envcmd.Wrap(&DestroyCommand{}))
 	blockcmd.Register(envcmd.Wrap(&RemoveCommand{}))
 	blockcmd.Register(envcmd.Wrap(&ChangeCommand{}))
+	blockcmd.Register(envcmd.Wrap(&BudgetCommand{}))
+	blockcmd.Register(envcmd.Wrap(&RebootCommand{}))
 	blockcmd.Register(envcmd.Wrap(&ListCommand{}))
 	return &blockcmd
 }
@@ -35,6 +35,8 @@ func (s *listCommandSuite) TestListEmpty(c *gc.C) {
 destroy-environment  =off
 remove-object        =off
 all-changes          =off
+budget-affecting     =off
+machine-reboot       =off
 `)
 }
 
@@ -46,6 +48,8 @@ func (s *listCommandSuite) TestList(c *gc.C) {
 destroy-environment  =off
 remove-object        =on, Test this one
 all-changes          =off
+budget-affecting     =off
+machine-reboot       =off
 `)
 }
 
@@ -61,6 +65,10 @@ func (s *listCommandSuite) TestListYaml(c *gc.C) {
   message: Test this one
 - block: all-changes
   enabled: false
+- block: budget-affecting
+  enabled: false
+- block: machine-reboot
+  enabled: false
 `[1:])
 }
 
@@ -68,6 +76,6 @@ func (s *listCommandSuite) TestListJson(c *gc.C) {
 	s.mockClient.SwitchBlockOn(string(multiwatcher.BlockRemove), "Test this one")
 	ctx, err := testing.RunCommand(c, envcmd.Wrap(&block.ListCommand{}), "--format", "json")
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(testing.Stdout(ctx), gc.Equals, `[{"block":"destroy-environment","enabled":false},{"block":"remove-object","enabled":true,"message":"Test this one"},{"block":"all-changes","enabled":false}]
+	c.Assert(testing.Stdout(ctx), gc.Equals, `[{"block":"destroy-environment","enabled":false},{"block":"remove-object","enabled":true,"message":"Test this one"},{"block":"all-changes","enabled":false},{"block":"budget-affecting","enabled":false},{"block":"machine-reboot","enabled":false}]
 `)
 }
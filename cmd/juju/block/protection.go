@@ -22,7 +22,7 @@ var logger = loggo.GetLogger("juju.cmd.juju.block")
 // supplied to the command.
 // These operations do not necessarily correspond to juju commands
 // but are rather juju command groupings.
-var blockArgs = []string{"destroy-environment", "remove-object", "all-changes"}
+var blockArgs = []string{"destroy-environment", "remove-object", "all-changes", "budget-affecting", "machine-reboot"}
 
 // TypeFromOperation translates given operation string
 // such as destroy-environment, remove-object, etc to
@@ -40,6 +40,8 @@ var blockTypes = map[string]string{
 	string(multiwatcher.BlockDestroy): "destroy-environment",
 	string(multiwatcher.BlockRemove):  "remove-object",
 	string(multiwatcher.BlockChange):  "all-changes",
+	string(multiwatcher.BlockBudget):  "budget-affecting",
+	string(multiwatcher.BlockReboot):  "machine-reboot",
 }
 
 // OperationFromType translates given block type as
@@ -73,12 +75,22 @@ const (
 	// BlockChange describes the block that
 	// blocks change commands
 	BlockChange
+
+	// BlockBudget describes the block that
+	// blocks commands that may add to the cost of the environment
+	BlockBudget
+
+	// BlockReboot describes the block that
+	// blocks charms from rebooting machines
+	BlockReboot
 )
 
 var blockedMessages = map[Block]string{
 	BlockDestroy: destroyMsg,
 	BlockRemove:  removeMsg,
 	BlockChange:  changeMsg,
+	BlockBudget:  budgetMsg,
+	BlockReboot:  rebootMsg,
 }
 
 // ProcessBlockedError ensures that correct and user-friendly message is
@@ -116,3 +128,23 @@ To unblock changes, run
     juju unblock all-changes
 
 `
+var budgetMsg = `
+All operations that may add to the cost of the environment, such as adding
+machines, deploying services or adding units, have been blocked for the current
+environment. These operations are also blocked by all-changes.
+To unblock them, run
+
+    juju unblock budget-affecting
+
+or, if changes are blocked,
+
+    juju unblock all-changes
+
+`
+var rebootMsg = `
+Charms have been blocked from rebooting machines in the current environment.
+To unblock reboots, run
+
+    juju unblock machine-reboot
+
+`
@@ -75,6 +75,15 @@ all-changes includes all alteration commands
     user disable
     user enable
 
+budget-affecting includes commands that may add to the cost of the environment:
+    add-machine
+    add-unit
+    deploy
+    ensure-availability
+
+machine-reboot includes reboots requested by charms:
+    juju-reboot (hook tool)
+
 Examples:
    To allow the environment to be destroyed:
    juju unblock destroy-environment
@@ -85,6 +94,12 @@ Examples:
    To allow changes to the environment:
    juju unblock all-changes
 
+   To allow machines and units to be added to the environment:
+   juju unblock budget-affecting
+
+   To allow charms to reboot machines:
+   juju unblock machine-reboot
+
 See Also:
    juju help block
 `
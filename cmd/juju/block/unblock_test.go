@@ -69,3 +69,11 @@ func (s *UnblockCommandSuite) TestUnblockCmdValidDestroyEnvOperationUpperCase(c
 func (s *UnblockCommandSuite) TestUnblockCmdValidDestroyEnvOperation(c *gc.C) {
 	s.assertRunUnblock(c, "destroy-environment")
 }
+
+func (s *UnblockCommandSuite) TestUnblockCmdValidBudgetOperation(c *gc.C) {
+	s.assertRunUnblock(c, "budget-affecting")
+}
+
+func (s *UnblockCommandSuite) TestUnblockCmdValidRebootOperation(c *gc.C) {
+	s.assertRunUnblock(c, "machine-reboot")
+}
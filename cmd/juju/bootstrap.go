@@ -16,10 +16,12 @@ import (
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
+	"github.com/juju/juju/environs/offline"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider"
+	"github.com/juju/juju/version"
 )
 
 const bootstrapDoc = `
@@ -57,6 +59,14 @@ Juju tools to cloud storage if no outgoing Internet access is available. In this
 use the --metadata-source paramater to tell bootstrap a local directory from which to
 upload tools and/or image metadata.
 
+Environments without any Internet access at all can be bootstrapped with the
+--offline flag. The --metadata-source directory, or a gzipped tar archive of
+one, must then hold the Juju tools for the current version beneath
+tools/<stream>, and may hold container images beneath
+containers/lxc/<series>/<arch>. Bootstrap checks that the required tools are
+present before starting, and uploads the tools and images to the state server,
+from which all machines in the environment fetch them.
+
 See Also:
    juju help switch
    juju help constraints
@@ -75,6 +85,7 @@ type BootstrapCommand struct {
 	MetadataSource        string
 	Placement             string
 	KeepBrokenEnvironment bool
+	Offline               bool
 }
 
 func (c *BootstrapCommand) Info() *cmd.Info {
@@ -93,6 +104,7 @@ func (c *BootstrapCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.MetadataSource, "metadata-source", "", "local path to use as tools and/or metadata source")
 	f.StringVar(&c.Placement, "to", "", "a placement directive indicating an instance to bootstrap")
 	f.BoolVar(&c.KeepBrokenEnvironment, "keep-broken", false, "do not destroy the environment if bootstrap fails")
+	f.BoolVar(&c.Offline, "offline", false, "bootstrap without Internet access, using the tools and images in --metadata-source")
 }
 
 func (c *BootstrapCommand) Init(args []string) (err error) {
@@ -105,6 +117,12 @@ func (c *BootstrapCommand) Init(args []string) (err error) {
 	if len(c.Series) > 0 && len(c.seriesOld) > 0 {
 		return fmt.Errorf("--upload-series and --series can't be used together")
 	}
+	if c.Offline && c.MetadataSource == "" {
+		return fmt.Errorf("--offline requires --metadata-source")
+	}
+	if c.Offline && c.UploadTools {
+		return fmt.Errorf("--offline and --upload-tools can't be used together")
+	}
 
 	// Parse the placement directive. Bootstrap currently only
 	// supports provider-specific placement directives.
@@ -235,11 +253,19 @@ func (c *BootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 	if c.MetadataSource != "" {
 		metadataDir = ctx.AbsPath(c.MetadataSource)
 	}
+	if c.Offline {
+		dir, cleanupArtifacts, err := offline.Open(metadataDir)
+		if err != nil {
+			return errors.Annotate(err, "cannot open offline artifacts")
+		}
+		defer cleanupArtifacts()
+		metadataDir = dir
+	}
 
 	// TODO (wallyworld): 2013-09-20 bug 1227931
 	// We can set a custom tools data source instead of doing an
 	// unnecessary upload.
-	if environ.Config().Type() == provider.Local {
+	if environ.Config().Type() == provider.Local && !c.Offline {
 		c.UploadTools = true
 	}
 
@@ -248,11 +274,39 @@ func (c *BootstrapCommand) Run(ctx *cmd.Context) (resultErr error) {
 		Placement:   c.Placement,
 		UploadTools: c.UploadTools,
 		MetadataDir: metadataDir,
+		Offline:     c.Offline,
 	})
 	if err != nil {
 		return errors.Annotate(err, "failed to bootstrap environment")
 	}
-	return c.SetBootstrapEndpointAddress(environ)
+	if err := c.SetBootstrapEndpointAddress(environ); err != nil {
+		return err
+	}
+	if c.Offline {
+		return c.uploadOfflineArtifacts(ctx, metadataDir)
+	}
+	return nil
+}
+
+// uploadOfflineArtifacts stores the tools and container images in dir
+// on the new state server, so that machines subsequently added to the
+// environment need not fetch them from the Internet.
+func (c *BootstrapCommand) uploadOfflineArtifacts(ctx *cmd.Context, dir string) error {
+	artifacts, err := offline.Read(dir)
+	if err != nil {
+		return errors.Annotate(err, "cannot read offline artifacts")
+	}
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+	ctx.Infof("Uploading tools and container images to the state server")
+	tools := artifacts.MatchingTools(version.Current.Number, "", artifacts.Tools.AllSeries()...)
+	if err := artifacts.Upload(client, tools); err != nil {
+		return errors.Annotate(err, "cannot upload offline artifacts")
+	}
+	return nil
 }
 
 // handleBootstrapError is called to clean up if bootstrap fails.
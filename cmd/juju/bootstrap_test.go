@@ -215,6 +215,14 @@ var bootstrapTests = []bootstrapTest{{
 	info: "--upload-series with --series",
 	args: []string{"--upload-tools", "--upload-series", "foo", "--series", "bar"},
 	err:  `--upload-series and --series can't be used together`,
+}, {
+	info: "lonely --offline",
+	args: []string{"--offline"},
+	err:  `--offline requires --metadata-source`,
+}, {
+	info: "--offline with --upload-tools",
+	args: []string{"--offline", "--metadata-source", "/tmp/artifacts", "--upload-tools"},
+	err:  `--offline and --upload-tools can't be used together`,
 }, {
 	info:    "bad environment",
 	version: "1.2.3-%LTS%-amd64",
@@ -125,3 +125,32 @@ func resolveCharmURL(url string, client *api.Client, conf *config.Config) (*char
 	logger.Errorf("The series is not specified in the environment (default-series) or with the charm. Did you mean:\n\t%s", &possibleURL)
 	return nil, fmt.Errorf("cannot resolve series for charm: %q", ref)
 }
+
+// resolveCharmChannelURL returns a charm URL resolved from the given
+// charm store channel, given a charm location string. If the location
+// does not specify a revision, the latest revision published to the
+// channel is resolved by the state server.
+func resolveCharmChannelURL(url, channel string, client *api.Client, conf *config.Config) (*charm.URL, error) {
+	ref, err := charm.ParseReference(url)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Schema != "cs" {
+		return nil, fmt.Errorf("cannot use --channel with %q: only charm store charms have channels", ref)
+	}
+	// If series is not set, use configured default series
+	if ref.Series == "" {
+		if defaultSeries, ok := conf.DefaultSeries(); ok {
+			ref.Series = defaultSeries
+		}
+	}
+	curl, err := client.ResolveCharmWithChannel(ref, channel)
+	if err != nil {
+		return nil, err
+	}
+	if curl.Revision < 0 {
+		// Older state servers ignore the channel.
+		return nil, errors.New("cannot use --channel: not supported by the API server")
+	}
+	return curl, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/constraints"
@@ -36,6 +37,10 @@ commands (such as juju deploy) that provision machines for services.  Where
 environment and service constraints overlap, the service constraints take
 precedence.
 
+Constraints which are not supported by the environment's provider are still
+set, but a warning is printed for each of them because they will be ignored
+when machines are provisioned.
+
 Examples:
 
    set-constraints mem=8G                         (all new machines in the environment must have at least 8GB of RAM)
@@ -63,8 +68,8 @@ type ConstraintsAPI interface {
 	Close() error
 	GetEnvironmentConstraints() (constraints.Value, error)
 	GetServiceConstraints(string) (constraints.Value, error)
-	SetEnvironmentConstraints(constraints.Value) error
-	SetServiceConstraints(string, constraints.Value) error
+	SetEnvironmentConstraints(constraints.Value) ([]params.ConstraintsWarning, error)
+	SetServiceConstraints(string, constraints.Value) ([]params.ConstraintsWarning, error)
 }
 
 func (c *GetConstraintsCommand) getAPI() (ConstraintsAPI, error) {
@@ -161,17 +166,24 @@ func (c *SetConstraintsCommand) Init(args []string) (err error) {
 	return err
 }
 
-func (c *SetConstraintsCommand) Run(_ *cmd.Context) (err error) {
+func (c *SetConstraintsCommand) Run(ctx *cmd.Context) (err error) {
 	apiclient, err := c.getAPI()
 	if err != nil {
 		return err
 	}
 	defer apiclient.Close()
 
+	var warnings []params.ConstraintsWarning
 	if c.ServiceName == "" {
-		err = apiclient.SetEnvironmentConstraints(c.Constraints)
+		warnings, err = apiclient.SetEnvironmentConstraints(c.Constraints)
 	} else {
-		err = apiclient.SetServiceConstraints(c.ServiceName, c.Constraints)
+		warnings, err = apiclient.SetServiceConstraints(c.ServiceName, c.Constraints)
+	}
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(ctx.Stderr, "WARNING: %s\n", warning.Message)
 	}
-	return block.ProcessBlockedError(err, block.BlockChange)
+	return nil
 }
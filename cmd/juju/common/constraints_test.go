@@ -14,6 +14,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	// TODO(dimitern): Don't ever import "." unless there's a GOOD
 	// reason to do it.
@@ -31,6 +32,7 @@ var _ = gc.Suite(&ConstraintsCommandsSuite{})
 
 type fakeConstraintsClient struct {
 	err      error
+	warnings []params.ConstraintsWarning
 	envCons  constraints.Value
 	servCons map[string]constraints.Value
 }
@@ -60,31 +62,31 @@ func (f *fakeConstraintsClient) GetServiceConstraints(name string) (constraints.
 	return cons, nil
 }
 
-func (f *fakeConstraintsClient) SetEnvironmentConstraints(cons constraints.Value) error {
+func (f *fakeConstraintsClient) SetEnvironmentConstraints(cons constraints.Value) ([]params.ConstraintsWarning, error) {
 	if f.err != nil {
-		return f.err
+		return nil, f.err
 	}
 
 	f.envCons = cons
-	return nil
+	return f.warnings, nil
 }
 
-func (f *fakeConstraintsClient) SetServiceConstraints(name string, cons constraints.Value) error {
+func (f *fakeConstraintsClient) SetServiceConstraints(name string, cons constraints.Value) ([]params.ConstraintsWarning, error) {
 	if f.err != nil {
-		return f.err
+		return nil, f.err
 	}
 
 	if !names.IsValidService(name) {
-		return errors.Errorf("%q is not a valid service name", name)
+		return nil, errors.Errorf("%q is not a valid service name", name)
 	}
 
 	_, ok := f.servCons[name]
 	if !ok {
-		return errors.NotFoundf("service %q", name)
+		return nil, errors.NotFoundf("service %q", name)
 	}
 
 	f.servCons[name] = cons
-	return nil
+	return f.warnings, nil
 }
 
 func runCmdLine(c *gc.C, com cmd.Command, args ...string) (code int, stdout, stderr string) {
@@ -166,6 +168,30 @@ func (s *ConstraintsCommandsSuite) TestSetService(c *gc.C) {
 	c.Assert(&cons, jc.Satisfies, constraints.IsEmpty)
 }
 
+func (s *ConstraintsCommandsSuite) TestSetWarnings(c *gc.C) {
+	s.fake.addTestingService("svc")
+	s.fake.warnings = []params.ConstraintsWarning{{
+		Attribute: "cpu-power",
+		Message:   `constraint "cpu-power" is not supported by this environment and will be ignored`,
+	}}
+	expected := `WARNING: constraint "cpu-power" is not supported by this environment and will be ignored` + "\n"
+
+	for _, args := range [][]string{
+		{"mem=4G", "cpu-power=250"},
+		{"-s", "svc", "mem=4G", "cpu-power=250"},
+	} {
+		command := NewSetConstraintsCommand(s.fake)
+		rcode, rstdout, rstderr := runCmdLine(c, envcmd.Wrap(command), args...)
+		c.Check(rcode, gc.Equals, 0)
+		c.Check(rstdout, gc.Equals, "")
+		c.Check(rstderr, gc.Equals, expected)
+	}
+	c.Assert(s.fake.servCons["svc"], gc.DeepEquals, constraints.Value{
+		CpuPower: uint64p(250),
+		Mem:      uint64p(4096),
+	})
+}
+
 func (s *ConstraintsCommandsSuite) TestBlockSetService(c *gc.C) {
 	s.fake.addTestingService("svc")
 
@@ -6,30 +6,53 @@ package main
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"sort"
 
 	"github.com/juju/cmd"
 	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4/hooks"
+	"launchpad.net/gnuflag"
 
 	unitdebug "github.com/juju/juju/worker/uniter/runner/debug"
 )
 
-// DebugHooksCommand is responsible for launching a ssh shell on a given unit or machine.
+// DebugHooksCommand is responsible for starting a session in which
+// the hooks of a given unit are run interactively.
 type DebugHooksCommand struct {
 	SSHCommand
-	hooks []string
+	hooks  []string
+	useSSH bool
+}
+
+// SetFlags omits the --proxy-stdio flag of "juju ssh", which makes no
+// sense for debug-hooks.
+func (c *DebugHooksCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.SSHCommon.SetFlags(f)
+	f.BoolVar(&c.useSSH, "ssh", false, "debug hooks in a tmux session over ssh")
 }
 
 const debugHooksDoc = `
 Interactively debug a hook remotely on a service unit.
+
+The session is brokered by the API server, so the unit's machine need
+not be reachable from the client. When the unit is about to run one of
+the named hooks (or any hook, if none are named or "*" is given), a
+shell is started in the hook's environment instead, and its input and
+output are relayed to the client. Run the hook from the shell as many
+times as needed; the hook completes when the shell exits. A transcript
+of each session is recorded in the debug-hooks directory of the API
+server's log directory.
+
+With --ssh, the session is instead run in tmux over ssh, which requires
+the unit's machine to be reachable.
 `
 
 func (c *DebugHooksCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "debug-hooks",
 		Args:    "<unit name> [hook names]",
-		Purpose: "launch a tmux session to debug a hook",
+		Purpose: "launch a session to debug a hook",
 		Doc:     debugHooksDoc,
 	}
 }
@@ -91,9 +114,21 @@ func (c *DebugHooksCommand) validateHooks() error {
 	return nil
 }
 
-// Run ensures c.Target is a unit, and resolves its address,
-// and connects to it via SSH to execute the debug-hooks
-// script.
+// connectDebugHooks starts a debug-hooks session, brokered by the API
+// server, with the command's target unit.
+var connectDebugHooks = func(c *DebugHooksCommand) (io.ReadWriteCloser, error) {
+	st, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, err
+	}
+	// The session has its own connection to the API server.
+	defer st.Close()
+	return st.ConnectDebugHooks(c.Target, c.hooks)
+}
+
+// Run ensures c.Target is a unit, and starts a debug-hooks session
+// with it through the API server or, with --ssh, resolves its address
+// and connects to it via SSH to execute the debug-hooks script.
 func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
 	var err error
 	c.apiClient, err = c.initAPIClient()
@@ -105,6 +140,9 @@ func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	if !c.useSSH {
+		return c.runBrokered(ctx)
+	}
 	debugctx := unitdebug.NewHooksContext(c.Target)
 	script := base64.StdEncoding.EncodeToString([]byte(unitdebug.ClientScript(debugctx, c.hooks)))
 	innercmd := fmt.Sprintf(`F=$(mktemp); echo %s | base64 -d > $F; . $F`, script)
@@ -112,3 +150,29 @@ func (c *DebugHooksCommand) Run(ctx *cmd.Context) error {
 	c.Args = args
 	return c.SSHCommand.Run(ctx)
 }
+
+// runBrokered relays stdin and stdout to a debug-hooks session
+// brokered by the API server, until either the session ends or
+// stdin is closed.
+func (c *DebugHooksCommand) runBrokered(ctx *cmd.Context) error {
+	conn, err := connectDebugHooks(c)
+	if err != nil {
+		return err
+	}
+	stdinClosed := make(chan struct{})
+	go func() {
+		io.Copy(conn, ctx.Stdin)
+		close(stdinClosed)
+		conn.Close()
+	}()
+	_, err = io.Copy(ctx.Stdout, conn)
+	conn.Close()
+	select {
+	case <-stdinClosed:
+		// The session was ended by closing stdin, which
+		// also closes the connection we were reading.
+		return nil
+	default:
+		return err
+	}
+}
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"io"
 	"regexp"
 	"runtime"
+	"strings"
 
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -89,6 +91,7 @@ func (s *DebugHooksSuite) TestDebugHooksCommand(c *gc.C) {
 
 		debugHooksCmd := &DebugHooksCommand{}
 		debugHooksCmd.proxy = true
+		debugHooksCmd.useSSH = true
 		err := envcmd.Wrap(debugHooksCmd).Init(t.args)
 		if err == nil {
 			err = debugHooksCmd.Run(ctx)
@@ -100,3 +103,35 @@ func (s *DebugHooksSuite) TestDebugHooksCommand(c *gc.C) {
 		}
 	}
 }
+
+type fakeDebugHooksConn struct {
+	io.Reader
+}
+
+func (conn *fakeDebugHooksConn) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (conn *fakeDebugHooksConn) Close() error {
+	return nil
+}
+
+func (s *DebugHooksSuite) TestDebugHooksBrokered(c *gc.C) {
+	machines := s.makeMachines(1, c, true)
+	dummy := s.AddTestingCharm(c, "dummy")
+	srv := s.AddTestingService(c, "mysql", dummy)
+	s.addUnit(srv, machines[0], c)
+
+	conn := &fakeDebugHooksConn{Reader: strings.NewReader("mysql/0:install % ")}
+	var target string
+	var hooks []string
+	s.PatchValue(&connectDebugHooks, func(c *DebugHooksCommand) (io.ReadWriteCloser, error) {
+		target, hooks = c.Target, c.hooks
+		return conn, nil
+	})
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&DebugHooksCommand{}), "mysql/0", "install", "start")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "mysql/0:install % ")
+	c.Assert(target, gc.Equals, "mysql/0")
+	c.Assert(hooks, jc.DeepEquals, []string{"install", "start"})
+}
@@ -6,6 +6,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/loggo"
@@ -19,18 +21,32 @@ type DebugLogCommand struct {
 	envcmd.EnvCommandBase
 
 	level  string
+	since  string
+	until  string
 	params api.DebugLogParams
 }
 
-var DefaultLogLocation = "/var/log/juju/all-machines.log"
-
 // defaultLineCount is the default number of lines to
 // display, from the end of the consolidated log.
 const defaultLineCount = 10
 
 const debuglogDoc = `
-Stream the consolidated debug log file. This file contains the log messages
-from all nodes in the environment.
+Stream the consolidated debug log. The log messages from all agents in
+the environment are sent to the state servers, where they are stored and
+can be filtered by entity, logging module, level and time.
+
+Times given to --since and --until must be in RFC3339 format, for
+example "2015-06-01T12:00:00Z". When --since is given, all the matching
+messages logged since that time are shown before new ones.
+
+The --include-module-regexp, --exclude-module-regexp, --include-message
+and --exclude-message options take regular expressions, for example:
+
+    juju debug-log --include-message "(?i)error" --exclude-module-regexp "^juju\.apiserver"
+
+With --per-entity the number of lines given by --lines is shown for each
+matching entity. The rate at which lines are shown may be limited with
+--max-rate; the state server imposes a limit of its own.
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
@@ -49,13 +65,22 @@ func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(cmd.NewAppendStringsValue(&c.params.IncludeModule), "include-module", "only show log messages for these logging modules")
 	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeModule), "exclude-module", "do not show log messages for these logging modules")
 
+	f.Var(cmd.NewAppendStringsValue(&c.params.IncludeModuleRegexp), "include-module-regexp", "only show log messages for logging modules matching these regular expressions")
+	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeModuleRegexp), "exclude-module-regexp", "do not show log messages for logging modules matching these regular expressions")
+	f.Var(cmd.NewAppendStringsValue(&c.params.IncludeMessage), "include-message", "only show log messages matching these regular expressions")
+	f.Var(cmd.NewAppendStringsValue(&c.params.ExcludeMessage), "exclude-message", "do not show log messages matching these regular expressions")
+
 	f.StringVar(&c.level, "l", "", "log level to show, one of [TRACE, DEBUG, INFO, WARNING, ERROR]")
 	f.StringVar(&c.level, "level", "", "")
 
 	f.UintVar(&c.params.Backlog, "n", defaultLineCount, "go back this many lines from the end before starting to filter")
 	f.UintVar(&c.params.Backlog, "lines", defaultLineCount, "")
+	f.BoolVar(&c.params.BacklogPerEntity, "per-entity", false, "go back the given number of lines for each entity")
 	f.UintVar(&c.params.Limit, "limit", 0, "show at most this many lines")
+	f.UintVar(&c.params.MaxRate, "max-rate", 0, "show at most this many lines per second")
 	f.BoolVar(&c.params.Replay, "replay", false, "start filtering from the start")
+	f.StringVar(&c.since, "since", "", "only show log messages logged at or after this time")
+	f.StringVar(&c.until, "until", "", "only show log messages logged before this time")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
@@ -67,9 +92,41 @@ func (c *DebugLogCommand) Init(args []string) error {
 		}
 		c.params.Level = level
 	}
+	for _, expr := range c.regexps() {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("%q is not a valid regular expression", expr)
+		}
+	}
+	if c.since != "" {
+		t, err := time.Parse(time.RFC3339, c.since)
+		if err != nil {
+			return fmt.Errorf("since value %q is not a valid RFC3339 time", c.since)
+		}
+		c.params.StartTime = t
+	}
+	if c.until != "" {
+		t, err := time.Parse(time.RFC3339, c.until)
+		if err != nil {
+			return fmt.Errorf("until value %q is not a valid RFC3339 time", c.until)
+		}
+		c.params.EndTime = t
+	}
+	if !c.params.StartTime.IsZero() && !c.params.EndTime.IsZero() && c.params.EndTime.Before(c.params.StartTime) {
+		return fmt.Errorf("until value %q is before since value %q", c.until, c.since)
+	}
 	return cmd.CheckEmpty(args)
 }
 
+// regexps returns all the regular expressions used to filter the log.
+func (c *DebugLogCommand) regexps() []string {
+	var exprs []string
+	exprs = append(exprs, c.params.IncludeModuleRegexp...)
+	exprs = append(exprs, c.params.ExcludeModuleRegexp...)
+	exprs = append(exprs, c.params.IncludeMessage...)
+	exprs = append(exprs, c.params.ExcludeMessage...)
+	return exprs
+}
+
 type DebugLogAPI interface {
 	WatchDebugLog(params api.DebugLogParams) (io.ReadCloser, error)
 	Close() error
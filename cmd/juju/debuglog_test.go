@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/juju/loggo"
 	jc "github.com/juju/testing/checkers"
@@ -85,6 +86,46 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 				Backlog: 10,
 				Limit:   100,
 			},
+		}, {
+			args: []string{"--since", "2015-06-01T12:00:00Z", "--until", "2015-06-01T13:00:00Z"},
+			expected: api.DebugLogParams{
+				Backlog:   10,
+				StartTime: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2015, 6, 1, 13, 0, 0, 0, time.UTC),
+			},
+		}, {
+			args: []string{"--include-module-regexp", "^juju", "--exclude-module-regexp", "uniter$"},
+			expected: api.DebugLogParams{
+				IncludeModuleRegexp: []string{"^juju"},
+				ExcludeModuleRegexp: []string{"uniter$"},
+				Backlog:             10,
+			},
+		}, {
+			args: []string{"--include-message", "(?i)error", "--exclude-message", "^ignored"},
+			expected: api.DebugLogParams{
+				IncludeMessage: []string{"(?i)error"},
+				ExcludeMessage: []string{"^ignored"},
+				Backlog:        10,
+			},
+		}, {
+			args:     []string{"--include-message", "(unclosed"},
+			errMatch: `"\(unclosed" is not a valid regular expression`,
+		}, {
+			args: []string{"--per-entity", "--max-rate", "20"},
+			expected: api.DebugLogParams{
+				Backlog:          10,
+				BacklogPerEntity: true,
+				MaxRate:          20,
+			},
+		}, {
+			args:     []string{"--since", "yesterday"},
+			errMatch: `since value "yesterday" is not a valid RFC3339 time`,
+		}, {
+			args:     []string{"--until", "2015-06-01"},
+			errMatch: `until value "2015-06-01" is not a valid RFC3339 time`,
+		}, {
+			args:     []string{"--since", "2015-06-01T13:00:00Z", "--until", "2015-06-01T12:00:00Z"},
+			errMatch: `until value "2015-06-01T12:00:00Z" is before since value "2015-06-01T13:00:00Z"`,
 		},
 	} {
 		c.Logf("test %v", i)
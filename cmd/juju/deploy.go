@@ -16,12 +16,14 @@ import (
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/instancemetadata"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/storage"
 )
@@ -36,6 +38,20 @@ type DeployCommand struct {
 	Networks     string
 	BumpRevision bool   // Remove this once the 1.16 support is dropped.
 	RepoPath     string // defaults to JUJU_REPOSITORY
+	EstimateOnly bool
+
+	// WorkloadContainer is the type of container in which the charm
+	// of each unit runs on the unit's machine, if any.
+	WorkloadContainer string
+
+	// Channel is the charm store channel from which the charm is
+	// deployed, and which the service tracks for upgrades.
+	Channel string
+
+	// DevWatch causes the command to keep watching the local charm
+	// directory after deploying, upgrading the service whenever the
+	// charm changes.
+	DevWatch bool
 
 	// TODO(axw) move this to UnitCommandBase once we support --storage
 	// on add-unit too.
@@ -74,6 +90,9 @@ machines provisioned with add-unit will use the same constraints (unless changed
 by set-constraints).
 
 Charms can be deployed to a specific machine using the --to argument.
+The --to argument may also be a placement directive understood by the
+environment's provider, such as an availability zone; the charm is then
+deployed to a newly provisioned machine chosen according to the directive.
 If the destination is an LXC container the default is to use lxc-clone
 to create the container where possible. For Ubuntu deployments, lxc-clone
 is supported for the trusty OS series and later. A 'template' container is
@@ -96,15 +115,55 @@ Examples:
    juju deploy mysql --to 23       (deploy to machine 23)
    juju deploy mysql --to 24/lxc/3 (deploy to lxc container 3 on host machine 24)
    juju deploy mysql --to lxc:25   (deploy to a new lxc container on host machine 25)
+   juju deploy mysql --to lxc      (deploy to a new lxc container on a new machine)
+   juju deploy mysql --to zone=us-east-1a
+                                   (deploy to a new machine in zone us-east-1a)
 
    juju deploy mysql -n 5 --constraints mem=8G
    (deploy 5 instances of mysql with at least 8 GB of RAM each)
 
+   juju deploy mysql --workload-container lxc
+   (deploy mysql with its charm running in an lxc container on its machine)
+
+   juju deploy cs:mysql --channel edge
+   (deploy the latest revision of mysql published to the edge channel)
+
+   juju deploy mysql -n 5 --constraints mem=8G --estimate-only
+   (report the estimated hourly cost of the above, without deploying)
+
    juju deploy mysql --networks=storage,mynet --constraints networks=^logging,db
    (deploy mysql on machines with "storage", "mynet" and "db" networks,
     but not on machines with "logging" network, also configure "storage" and
     "mynet" networks)
 
+The --workload-container flag runs the charm of each of the service's
+units in a container of its own, created on the machine the unit is
+deployed to, instead of directly on the machine. The unit agent runs on
+the machine and executes the charm's hooks inside the container, which
+is destroyed when the unit is removed. The container shares the
+machine's network, so ports opened by the charm are opened on the
+machine. Only "lxc" containers are supported, and the flag cannot be
+used with subordinate charms.
+
+The --channel flag deploys a charm store charm from the given channel
+of the charm store: "stable" (the default), "candidate" or "edge". If
+the charm name does not specify a revision, the latest revision
+published to the channel is deployed. The service keeps tracking the
+channel, so later upgrade-charm commands upgrade it to the latest
+revision in that channel.
+
+The --estimate-only flag reports the estimated hourly cost of the new
+machines the service's units would be deployed to, using the instance
+types and prices cached by the state server, without deploying the
+service. Not supported on all providers.
+
+The --dev-watch flag is for use while developing a local charm. After
+deploying the service, the command keeps watching the charm directory
+and, whenever a file in it changes, uploads the charm and upgrades the
+service to it, even if units are in an error state. Hook logs from the
+service's units are written to stdout. Interrupt the command to stop
+watching; the service is left deployed.
+
 Like constraints, service-specific network requirements can be
 specified with the --networks argument, which takes a comma-delimited
 list of juju-specific network names. Networks can also be specified with
@@ -137,6 +196,10 @@ func (c *DeployCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "set service constraints")
 	f.StringVar(&c.Networks, "networks", "", "bind the service to specific networks")
 	f.StringVar(&c.RepoPath, "repository", os.Getenv(osenv.JujuRepositoryEnvKey), "local charm repository")
+	f.BoolVar(&c.EstimateOnly, "estimate-only", false, "report the estimated hourly cost without deploying")
+	f.StringVar(&c.WorkloadContainer, "workload-container", "", "run each unit's charm in a container of this type on the unit's machine")
+	f.StringVar(&c.Channel, "channel", "", "charm store channel to deploy from and track for upgrades")
+	f.BoolVar(&c.DevWatch, "dev-watch", false, "upgrade the service whenever the local charm directory changes")
 	if featureflag.Enabled(feature.Storage) {
 		// NOTE: if/when the feature flag is removed, bump the client
 		// facade and check that the ServiceDeployWithNetworks facade
@@ -163,10 +226,22 @@ func (c *DeployCommand) Init(args []string) error {
 	default:
 		return cmd.CheckEmpty(args[2:])
 	}
-	return c.UnitCommandBase.Init(args)
+	if err := c.UnitCommandBase.Init(args); err != nil {
+		return err
+	}
+	if c.EstimateOnly && c.ToMachineSpec != "" {
+		return errors.New("cannot use --estimate-only with --to")
+	}
+	if c.EstimateOnly && c.DevWatch {
+		return errors.New("cannot use --estimate-only with --dev-watch")
+	}
+	if c.WorkloadContainer != "" && instance.ContainerType(c.WorkloadContainer) != instance.LXC {
+		return fmt.Errorf("invalid workload container type %q: only %q is supported", c.WorkloadContainer, instance.LXC)
+	}
+	return nil
 }
 
-func (c *DeployCommand) Run(ctx *cmd.Context) error {
+func (c *DeployCommand) Run(ctx *cmd.Context) (err error) {
 	client, err := c.NewAPIClient()
 	if err != nil {
 		return err
@@ -182,11 +257,20 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		return err
 	}
 
-	curl, err := resolveCharmURL(c.CharmName, client, conf)
+	var curl *charm.URL
+	if c.Channel != "" {
+		curl, err = resolveCharmChannelURL(c.CharmName, c.Channel, client, conf)
+	} else {
+		curl, err = resolveCharmURL(c.CharmName, client, conf)
+	}
 	if err != nil {
 		return err
 	}
 
+	if c.EstimateOnly {
+		return c.estimateCost(ctx, curl)
+	}
+
 	repo, err := charm.InferRepository(curl.Reference(), ctx.AbsPath(c.RepoPath))
 	if err != nil {
 		return err
@@ -194,6 +278,13 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 
 	config.SpecializeCharmRepo(repo, conf)
 
+	var charmDir string
+	if c.DevWatch {
+		if charmDir, err = devCharmDir(curl, repo); err != nil {
+			return err
+		}
+	}
+
 	curl, err = addCharmViaAPI(client, ctx, curl, repo)
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
@@ -233,11 +324,23 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 		} else {
 			return errors.New("cannot use --num-units or --to with subordinate service")
 		}
+		if c.WorkloadContainer != "" {
+			return errors.New("cannot use --workload-container with subordinate service")
+		}
 	}
 	serviceName := c.ServiceName
 	if serviceName == "" {
 		serviceName = charmInfo.Meta.Name
 	}
+	if c.DevWatch {
+		// Once the service is deployed, keep upgrading it as the
+		// charm directory changes.
+		defer func() {
+			if err == nil {
+				err = devWatch(ctx, client, serviceName, curl, charmDir)
+			}
+		}()
+	}
 
 	var configYAML []byte
 	if c.Config.Path != "" {
@@ -246,6 +349,41 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 			return err
 		}
 	}
+	if c.Channel != "" {
+		err = client.ServiceDeployWithChannel(params.ServiceDeploy{
+			ServiceName:       serviceName,
+			CharmUrl:          curl.String(),
+			NumUnits:          numUnits,
+			ConfigYAML:        string(configYAML),
+			Constraints:       c.Constraints,
+			ToMachineSpec:     c.ToMachineSpec,
+			Networks:          requestedNetworks,
+			Storage:           c.Storage,
+			WorkloadContainer: instance.ContainerType(c.WorkloadContainer),
+			Channel:           c.Channel,
+		})
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --channel: not supported by the API server")
+		}
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	if c.WorkloadContainer != "" {
+		err = client.ServiceDeployWithWorkloadContainer(params.ServiceDeploy{
+			ServiceName:       serviceName,
+			CharmUrl:          curl.String(),
+			NumUnits:          numUnits,
+			ConfigYAML:        string(configYAML),
+			Constraints:       c.Constraints,
+			ToMachineSpec:     c.ToMachineSpec,
+			Networks:          requestedNetworks,
+			Storage:           c.Storage,
+			WorkloadContainer: instance.ContainerType(c.WorkloadContainer),
+		})
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --workload-container: not supported by the API server")
+		}
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
 	// TODO(axw) rename ServiceDeployWithNetworks to ServiceDeploy,
 	// and ServiceDeploy to ServiceDeployLegacy or some such.
 	err = client.ServiceDeployWithNetworks(
@@ -270,7 +408,39 @@ func (c *DeployCommand) Run(ctx *cmd.Context) error {
 			c.Constraints,
 			c.ToMachineSpec)
 	}
-	return block.ProcessBlockedError(err, block.BlockChange)
+	return block.ProcessBlockedError(err, block.BlockBudget)
+}
+
+// estimateCost reports the estimated hourly cost of the machines the
+// service's units would be deployed to.
+func (c *DeployCommand) estimateCost(ctx *cmd.Context, curl *charm.URL) error {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	client := instancemetadata.NewClient(root)
+	defer client.Close()
+
+	serviceName := c.ServiceName
+	if serviceName == "" {
+		serviceName = curl.Name
+	}
+	costs, err := client.EstimateCosts([]params.ServiceCostArg{{
+		ServiceName: serviceName,
+		Series:      curl.Series,
+		Constraints: c.Constraints,
+		NumUnits:    c.NumUnits,
+	}})
+	if err != nil {
+		return errors.Annotate(err, "cannot estimate cost")
+	}
+	cost := costs[0]
+	if cost.Error != nil {
+		return errors.Annotate(cost.Error, "cannot estimate cost")
+	}
+	fmt.Fprintf(ctx.Stdout, "estimated cost of %d %s units on %s instances: %.3f %s/hour\n",
+		cost.NumUnits, cost.ServiceName, cost.InstanceType, cost.HourlyCost, cost.Currency)
+	return nil
 }
 
 // addCharmViaAPI calls the appropriate client API calls to add the
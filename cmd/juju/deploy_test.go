@@ -75,6 +75,15 @@ var initErrorTests = []struct {
 	}, {
 		args: []string{"craziness", "burble1", "--constraints", "gibber=plop"},
 		err:  `invalid value "gibber=plop" for flag --constraints: unknown constraint "gibber"`,
+	}, {
+		args: []string{"craziness", "burble1", "--estimate-only", "--to", "123"},
+		err:  `cannot use --estimate-only with --to`,
+	}, {
+		args: []string{"craziness", "burble1", "--estimate-only", "--dev-watch"},
+		err:  `cannot use --estimate-only with --dev-watch`,
+	}, {
+		args: []string{"craziness", "burble1", "--workload-container", "kvm"},
+		err:  `invalid workload container type "kvm": only "lxc" is supported`,
 	},
 }
 
@@ -243,6 +252,35 @@ func (s *DeploySuite) TestSubordinateConstraints(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "cannot use --constraints with subordinate service")
 }
 
+func (s *DeploySuite) TestSubordinateWorkloadContainer(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "logging")
+	err := runDeploy(c, "local:logging", "--workload-container", "lxc")
+	c.Assert(err, gc.ErrorMatches, "cannot use --workload-container with subordinate service")
+}
+
+func (s *DeploySuite) TestWorkloadContainer(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--workload-container", "lxc")
+	c.Assert(err, jc.ErrorIsNil)
+	curl := charm.MustParseURL("local:trusty/dummy-1")
+	service, _ := s.AssertService(c, "dummy", curl, 1, 0)
+	c.Assert(service.WorkloadContainer(), gc.Equals, instance.LXC)
+}
+
+func (s *DeploySuite) TestChannelWithLocalCharm(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--channel", "edge")
+	c.Assert(err, gc.ErrorMatches, `cannot use --channel with "local:dummy": only charm store charms have channels`)
+}
+
+func (s *DeploySuite) TestDevWatchWithCharmArchive(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--dev-watch")
+	c.Assert(err, gc.ErrorMatches, `cannot use --dev-watch with "local:trusty/dummy": charm is not a directory`)
+	_, err = s.State.Service("dummy")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *DeploySuite) TestNumUnits(c *gc.C) {
 	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
 	err := runDeploy(c, "local:dummy", "-n", "13")
@@ -251,6 +289,40 @@ func (s *DeploySuite) TestNumUnits(c *gc.C) {
 	s.AssertService(c, "dummy", curl, 13, 0)
 }
 
+func (s *DeploySuite) TestEstimateOnly(c *gc.C) {
+	err := s.State.UpdateInstanceMetadata(state.InstanceMetadata{
+		InstanceTypes: []state.InstanceType{{
+			Name:     "small",
+			Arches:   []string{"amd64"},
+			CpuCores: 1,
+			Mem:      1024,
+			Cost:     10,
+		}, {
+			Name:     "large",
+			Arches:   []string{"amd64"},
+			CpuCores: 4,
+			Mem:      8192,
+			Cost:     40,
+		}},
+		Images: []state.CloudImage{{Id: "trusty-amd64", Series: "trusty", Arch: "amd64"}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&DeployCommand{}), "local:dummy", "-n", "3", "--constraints", "mem=4G", "--estimate-only")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "estimated cost of 3 dummy units on large instances: 1.200 USD/hour\n")
+	_, err = s.State.Service("dummy")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *DeploySuite) TestEstimateOnlyNotCached(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "--estimate-only")
+	c.Assert(err, gc.ErrorMatches, "cannot estimate cost: instance metadata not found")
+	_, err = s.State.Service("dummy")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *DeploySuite) TestNumUnitsSubordinate(c *gc.C) {
 	testcharms.Repo.CharmArchivePath(s.SeriesPath, "logging")
 	err := runDeploy(c, "--num-units", "3", "local:logging")
@@ -47,7 +47,7 @@ func (c *DestroyEnvironmentCommand) Info() *cmd.Info {
 func (c *DestroyEnvironmentCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.assumeYes, "y", false, "Do not ask for confirmation")
 	f.BoolVar(&c.assumeYes, "yes", false, "")
-	f.BoolVar(&c.force, "force", false, "Forcefully destroy the environment, directly through the environment provider")
+	f.BoolVar(&c.force, "force", false, "Forcefully destroy the environment, directly through the environment provider, or ignoring prechecks for hosted environments")
 	f.StringVar(&c.envName, "e", "", "juju environment to operate in")
 	f.StringVar(&c.envName, "environment", "", "juju environment to operate in")
 }
@@ -101,16 +101,13 @@ func (c *DestroyEnvironmentCommand) Run(ctx *cmd.Context) (result error) {
 		}
 	}
 
-	if c.force {
-		if isServer {
-			// If --force is supplied on a server environment, then don't
-			// attempt to use the API. This is necessary to destroy broken
-			// environments, where the API server is inaccessible or faulty.
-			return environs.Destroy(serverEnviron, store)
-		} else {
-			// Force only makes sense on the server environment.
-			return errors.Errorf("cannot force destroy environment without bootstrap information")
-		}
+	if c.force && isServer {
+		// If --force is supplied on a server environment, then don't
+		// attempt to use the API. This is necessary to destroy broken
+		// environments, where the API server is inaccessible or faulty.
+		// Hosted environments have no provider to fall back on, so
+		// --force there only skips the server's prechecks.
+		return environs.Destroy(serverEnviron, store)
 	}
 
 	apiclient, err := juju.NewAPIClientFromName(c.envName)
@@ -162,7 +159,7 @@ func (c *DestroyEnvironmentCommand) Run(ctx *cmd.Context) (result error) {
 	// we do not call Destroy on the provider. Destroying the environment via
 	// the API and cleaning up the jenv file is sufficient.
 	if err := c.destroyEnv(apiclient); err != nil {
-		errors.Annotate(err, "cannot destroy environment")
+		return errors.Annotate(err, "cannot destroy environment")
 	}
 	return environs.DestroyInfo(c.envName, store)
 }
@@ -171,7 +168,12 @@ func (c *DestroyEnvironmentCommand) destroyEnv(apiclient *api.Client) (result er
 	defer func() {
 		result = c.ensureUserFriendlyErrorLog(result)
 	}()
-	err := apiclient.DestroyEnvironment()
+	var err error
+	if c.force {
+		err = apiclient.ForceDestroyEnvironment()
+	} else {
+		err = apiclient.DestroyEnvironment()
+	}
 	if cmdErr := processDestroyError(err); cmdErr != nil {
 		return cmdErr
 	}
@@ -158,26 +158,16 @@ func (s *destroyEnvSuite) TestDestroyEnvironmentCommandNonStateServer(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
-func (s *destroyEnvSuite) TestForceDestroyEnvironmentCommandOnNonStateServerFails(c *gc.C) {
+func (s *destroyEnvSuite) TestForceDestroyEnvironmentCommandOnNonStateServer(c *gc.C) {
 	s.setupHostedEnviron(c, "dummy-non-state-server")
 	opc, errc := cmdtesting.RunCommand(cmdtesting.NullContext(c), new(DestroyEnvironmentCommand), "dummy-non-state-server", "--yes", "--force")
-	c.Check(<-errc, gc.ErrorMatches, "cannot force destroy environment without bootstrap information")
-	c.Check(<-opc, gc.IsNil)
-
-	serverInfo, err := s.ConfigStore.ReadInfo("dummy-non-state-server")
-	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(serverInfo, gc.Not(gc.IsNil))
-}
-
-func (s *destroyEnvSuite) TestForceDestroyEnvironmentCommandOnNonStateServerNoConfimFails(c *gc.C) {
-	s.setupHostedEnviron(c, "dummy-non-state-server")
-	opc, errc := cmdtesting.RunCommand(cmdtesting.NullContext(c), new(DestroyEnvironmentCommand), "dummy-non-state-server", "--force")
-	c.Check(<-errc, gc.ErrorMatches, "cannot force destroy environment without bootstrap information")
+	c.Check(<-errc, gc.IsNil)
+	// Force on a hosted environment still goes through the API, and
+	// never calls Destroy on the provider.
 	c.Check(<-opc, gc.IsNil)
 
-	serverInfo, err := s.ConfigStore.ReadInfo("dummy-non-state-server")
-	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(serverInfo, gc.Not(gc.IsNil))
+	_, err := s.ConfigStore.ReadInfo("dummy-non-state-server")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
 
 func (s *destroyEnvSuite) TestDestroyEnvironmentCommandTwiceOnNonStateServer(c *gc.C) {
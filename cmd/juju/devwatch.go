@@ -0,0 +1,148 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/api"
+)
+
+// devWatchInterval is how often the charm directory of a service
+// deployed with --dev-watch is checked for changes.
+var devWatchInterval = time.Second
+
+// devWatchAPI holds the client methods used to upgrade a service as
+// its charm directory changes.
+type devWatchAPI interface {
+	AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, error)
+	ServiceSetCharm(serviceName string, charmUrl string, force bool) error
+	WatchDebugLog(params api.DebugLogParams) (io.ReadCloser, error)
+}
+
+// devCharmDir returns the path of the charm directory the given local
+// charm is read from by the repository.
+func devCharmDir(curl *charm.URL, repo charm.Repository) (string, error) {
+	if curl.Schema != "local" {
+		return "", errors.Errorf("cannot use --dev-watch with %q: only local charms can be watched", curl)
+	}
+	ch, err := repo.Get(curl)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	dir, ok := ch.(*charm.CharmDir)
+	if !ok {
+		return "", errors.Errorf("cannot use --dev-watch with %q: charm is not a directory", curl)
+	}
+	return dir.Path, nil
+}
+
+// devWatch runs watchCharmDir until the command is interrupted.
+func devWatch(ctx *cmd.Context, client devWatchAPI, serviceName string, curl *charm.URL, dir string) error {
+	interrupted := make(chan os.Signal, 1)
+	ctx.InterruptNotify(interrupted)
+	defer ctx.StopInterruptNotify(interrupted)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupted:
+			close(stop)
+		case <-done:
+		}
+	}()
+	return watchCharmDir(ctx, client, serviceName, curl, dir, stop)
+}
+
+// watchCharmDir upgrades the service to the charm in dir whenever the
+// files in it change, and copies the hook logs of the service's units
+// to ctx.Stdout, until stop is closed. Units are upgraded even if they
+// are in an error state, so a fixed hook can be retried.
+func watchCharmDir(ctx *cmd.Context, client devWatchAPI, serviceName string, curl *charm.URL, dir string, stop <-chan struct{}) error {
+	digest, err := charmDirDigest(dir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logs, err := client.WatchDebugLog(api.DebugLogParams{
+		IncludeEntity: []string{fmt.Sprintf("unit-%s-*", serviceName)},
+		IncludeModule: []string{"juju.worker.uniter"},
+	})
+	if err != nil {
+		ctx.Infof("Not showing hook logs: %v", err)
+	} else {
+		defer logs.Close()
+		go io.Copy(ctx.Stdout, logs)
+	}
+	ctx.Infof("Watching %s for changes.", dir)
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(devWatchInterval):
+		}
+		latest, err := charmDirDigest(dir)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if latest == digest {
+			continue
+		}
+		digest = latest
+		ch, err := charm.ReadCharmDir(dir)
+		if err != nil {
+			// The charm may be part way through being edited;
+			// wait for it to change again.
+			ctx.Infof("Cannot read charm: %v", err)
+			continue
+		}
+		addedURL, err := client.AddLocalCharm(curl.WithRevision(ch.Revision()), ch)
+		if err != nil {
+			return errors.Annotate(err, "cannot upload charm")
+		}
+		if err := client.ServiceSetCharm(serviceName, addedURL.String(), true); err != nil {
+			return errors.Annotatef(err, "cannot upgrade service %q", serviceName)
+		}
+		ctx.Infof("Upgraded service %q to charm %q.", serviceName, addedURL)
+	}
+}
+
+// charmDirDigest returns a digest of the names, sizes, modes and
+// modification times of the files in the charm directory, which
+// changes when any of the files do. Hidden files are ignored, as
+// they are not packaged with the charm.
+func charmDirDigest(dir string) (string, error) {
+	hash := sha256.New()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s %d %v %d\n", relPath, info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", errors.Annotate(err, "cannot read charm directory")
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
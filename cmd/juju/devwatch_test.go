@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/testcharms"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type DevWatchSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&DevWatchSuite{})
+
+func (s *DevWatchSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(&devWatchInterval, 10*time.Millisecond)
+}
+
+type fakeDevWatchAPI struct {
+	upgraded chan string
+	logsErr  error
+	added    []*charm.URL
+}
+
+func (f *fakeDevWatchAPI) AddLocalCharm(curl *charm.URL, ch charm.Charm) (*charm.URL, error) {
+	added := curl.WithRevision(len(f.added) + 2)
+	f.added = append(f.added, added)
+	return added, nil
+}
+
+func (f *fakeDevWatchAPI) ServiceSetCharm(serviceName string, charmURL string, force bool) error {
+	if !force {
+		return errors.New("not forced")
+	}
+	f.upgraded <- serviceName + " " + charmURL
+	return nil
+}
+
+func (f *fakeDevWatchAPI) WatchDebugLog(params api.DebugLogParams) (io.ReadCloser, error) {
+	if f.logsErr != nil {
+		return nil, f.logsErr
+	}
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+func (s *DevWatchSuite) startWatch(c *gc.C, client devWatchAPI, dir string) (stop func() error) {
+	ctx := coretesting.Context(c)
+	stopc := make(chan struct{})
+	done := make(chan error, 1)
+	curl := charm.MustParseURL("local:quantal/dummy-1")
+	go func() {
+		done <- watchCharmDir(ctx, client, "dummy", curl, dir, stopc)
+	}()
+	return func() error {
+		close(stopc)
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for watch to stop")
+		}
+		return nil
+	}
+}
+
+func (s *DevWatchSuite) TestUpgradesOnChange(c *gc.C) {
+	dir := testcharms.Repo.ClonedDirPath(c.MkDir(), "dummy")
+	client := &fakeDevWatchAPI{upgraded: make(chan string, 1)}
+	stop := s.startWatch(c, client, dir)
+
+	// Give the watcher a chance to record the initial state.
+	time.Sleep(5 * devWatchInterval)
+	select {
+	case upgraded := <-client.upgraded:
+		c.Fatalf("unexpected upgrade %q", upgraded)
+	default:
+	}
+
+	err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("changed"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case upgraded := <-client.upgraded:
+		c.Assert(upgraded, gc.Equals, "dummy local:quantal/dummy-2")
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for upgrade")
+	}
+	c.Assert(stop(), jc.ErrorIsNil)
+}
+
+func (s *DevWatchSuite) TestHiddenFilesIgnored(c *gc.C) {
+	dir := testcharms.Repo.ClonedDirPath(c.MkDir(), "dummy")
+	before, err := charmDirDigest(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, ".swp"), []byte("editing"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	after, err := charmDirDigest(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(after, gc.Equals, before)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "README"), []byte("changed"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	after, err = charmDirDigest(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(after, gc.Not(gc.Equals), before)
+}
+
+func (s *DevWatchSuite) TestDebugLogNotSupported(c *gc.C) {
+	dir := testcharms.Repo.ClonedDirPath(c.MkDir(), "dummy")
+	client := &fakeDevWatchAPI{
+		upgraded: make(chan string, 1),
+		logsErr:  errors.New("not supported"),
+	}
+	stop := s.startWatch(c, client, dir)
+	c.Assert(stop(), jc.ErrorIsNil)
+}
+
+func (s *DevWatchSuite) TestDevCharmDirRequiresLocalCharm(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/dummy-1")
+	_, err := devCharmDir(curl, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot use --dev-watch with "cs:quantal/dummy-1": only local charms can be watched`)
+}
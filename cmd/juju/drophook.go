@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const dropHookDoc = `
+Ask a unit's agent not to run a queued relation hook. The hook is
+recorded as having run, without running it, when it reaches the front
+of the queue. Use show-unit to see the ids of the hooks queued for a
+unit.
+
+Only relation hooks can be dropped, because later relation hooks
+always reflect the latest state of the relation.
+
+Example:
+
+    juju drop-hook mysql/0 relation-changed:2:wordpress/1
+`
+
+// DropHookCommand asks a unit's agent not to run a queued hook.
+type DropHookCommand struct {
+	envcmd.EnvCommandBase
+	unitName string
+	hookId   string
+}
+
+// Info implements Command.Info.
+func (c *DropHookCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "drop-hook",
+		Args:    "<unit> <hook-id>",
+		Purpose: "drop a hook queued for a unit",
+		Doc:     dropHookDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *DropHookCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no unit specified")
+	case 1:
+		return errors.New("no hook id specified")
+	}
+	c.unitName, c.hookId, args = args[0], args[1], args[2:]
+	if !names.IsValidUnit(c.unitName) {
+		return errors.Errorf("invalid unit name %q", c.unitName)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// DropHookAPI defines the API methods used by the drop-hook command.
+type DropHookAPI interface {
+	Close() error
+	DropQueuedHook(unit, hookId string) error
+}
+
+var getDropHookAPI = func(c *DropHookCommand) (DropHookAPI, error) {
+	return c.NewAPIClient()
+}
+
+// Run implements Command.Run.
+func (c *DropHookCommand) Run(_ *cmd.Context) error {
+	api, err := getDropHookAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	err = api.DropQueuedHook(c.unitName, c.hookId)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type DropHookSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeDropHookAPI
+}
+
+var _ = gc.Suite(&DropHookSuite{})
+
+type fakeDropHookAPI struct {
+	unit   string
+	hookId string
+	err    error
+}
+
+func (f *fakeDropHookAPI) Close() error {
+	return nil
+}
+
+func (f *fakeDropHookAPI) DropQueuedHook(unit, hookId string) error {
+	f.unit = unit
+	f.hookId = hookId
+	return f.err
+}
+
+func (s *DropHookSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeDropHookAPI{}
+	s.PatchValue(&getDropHookAPI, func(*DropHookCommand) (DropHookAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *DropHookSuite) TestDropHook(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&DropHookCommand{}), "mysql/0", "relation-changed:2:wordpress/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.unit, gc.Equals, "mysql/0")
+	c.Assert(s.api.hookId, gc.Equals, "relation-changed:2:wordpress/1")
+}
+
+func (s *DropHookSuite) TestDropHookError(c *gc.C) {
+	s.api.err = errors.New("boom")
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&DropHookCommand{}), "mysql/0", "config-changed")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *DropHookSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no unit specified",
+	}, {
+		args: []string{"mysql/0"},
+		err:  "no hook id specified",
+	}, {
+		args: []string{"mysql", "config-changed"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"mysql/0", "config-changed", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&DropHookCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -204,7 +204,7 @@ func (c *EnsureAvailabilityCommand) Run(ctx *cmd.Context) error {
 		c.Placement,
 	)
 	if err != nil {
-		return block.ProcessBlockedError(err, block.BlockChange)
+		return block.ProcessBlockedError(err, block.BlockBudget)
 	}
 
 	result := availabilityInfo{
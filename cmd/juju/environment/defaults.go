@@ -0,0 +1,178 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/environmentmanager"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// DefaultsCommand shows and changes the config defaults inherited by
+// new environments created within the Juju Environment Server.
+type DefaultsCommand struct {
+	envcmd.EnvCommandBase
+	api DefaultsAPI
+	out cmd.Output
+	// These attributes are exported only for testing purposes.
+	Region string
+	Key    string
+	Values map[string]string
+	Reset  []string
+}
+
+const defaultsHelpDoc = `
+Config defaults are set on the Juju Environment Server, and are inherited
+by environments created within it afterwards. Environments already created
+are not affected. Values given when an environment is created override any
+defaults.
+
+Defaults may be set for all new environments, or with --region for those
+created in a particular region only, taking precedence over those for all
+environments.
+
+With no arguments, all the defaults are shown. If a key is given, only the
+defaults for that key are shown. If key=value pairs are given, those
+defaults are set. Keys given to --reset, separated by commas, have their
+defaults removed.
+
+Only the owner of the Juju Environment Server may change the defaults.
+
+Examples:
+
+  juju environment defaults
+  juju environment defaults default-series
+  juju environment defaults default-series=trusty logging-config="<root>=INFO"
+  juju environment defaults --region us-east-1 default-series=precise
+  juju environment defaults --reset default-series,logging-config
+`
+
+func (c *DefaultsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "defaults",
+		Args:    "[<environment key> | <environment key>=<value> ...]",
+		Purpose: "view or change the config defaults for new environments",
+		Doc:     strings.TrimSpace(defaultsHelpDoc),
+	}
+}
+
+func (c *DefaultsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+	f.StringVar(&c.Region, "region", "", "the region the defaults apply to, if not all")
+	f.Var(cmd.NewStringsValue(nil, &c.Reset), "reset", "comma separated keys whose defaults are removed")
+}
+
+func (c *DefaultsCommand) Init(args []string) error {
+	if len(args) == 1 && !strings.Contains(args[0], "=") {
+		c.Key = args[0]
+	} else if len(args) > 0 {
+		values, err := keyvalues.Parse(args, true)
+		if err != nil {
+			return err
+		}
+		c.Values = values
+	}
+	for _, key := range c.Reset {
+		if c.Key != "" {
+			return errors.New("cannot show and reset defaults at the same time")
+		}
+		if _, found := c.Values[key]; found {
+			return errors.Errorf("key %q cannot be both set and reset", key)
+		}
+	}
+	return nil
+}
+
+// DefaultsAPI defines the API methods used by the defaults command.
+type DefaultsAPI interface {
+	Close() error
+	EnvironmentDefaults() (params.EnvironmentDefaults, error)
+	SetEnvironmentDefaults(region string, config map[string]interface{}) error
+	UnsetEnvironmentDefaults(region string, keys ...string) error
+}
+
+func (c *DefaultsCommand) getAPI() (DefaultsAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, err
+	}
+	return environmentmanager.NewClient(root), nil
+}
+
+// defaultValue holds the defaults set for a single key.
+type defaultValue struct {
+	Default interface{}            `yaml:"default,omitempty" json:"default,omitempty"`
+	Regions map[string]interface{} `yaml:"regions,omitempty" json:"regions,omitempty"`
+}
+
+func (c *DefaultsCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if len(c.Values) == 0 && len(c.Reset) == 0 {
+		return c.show(ctx, client)
+	}
+	if len(c.Values) > 0 {
+		config := make(map[string]interface{})
+		for key, value := range c.Values {
+			config[key] = value
+		}
+		if err := client.SetEnvironmentDefaults(c.Region, config); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(c.Reset) > 0 {
+		if err := client.UnsetEnvironmentDefaults(c.Region, c.Reset...); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// show writes out the defaults, keyed on config key, restricted to the
+// command's key and region if they are given.
+func (c *DefaultsCommand) show(ctx *cmd.Context, client DefaultsAPI) error {
+	defaults, err := client.EnvironmentDefaults()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	values := make(map[string]defaultValue)
+	for key, value := range defaults.Config {
+		values[key] = defaultValue{Default: value}
+	}
+	for region, config := range defaults.Regions {
+		if c.Region != "" && region != c.Region {
+			continue
+		}
+		for key, value := range config {
+			entry := values[key]
+			if entry.Regions == nil {
+				entry.Regions = make(map[string]interface{})
+			}
+			entry.Regions[region] = value
+			values[key] = entry
+		}
+	}
+	if c.Key == "" {
+		return c.out.Write(ctx, values)
+	}
+	if value, found := values[c.Key]; found {
+		return c.out.Write(ctx, value)
+	}
+	return fmt.Errorf("no defaults set for key %q", c.Key)
+}
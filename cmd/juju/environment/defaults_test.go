@@ -0,0 +1,168 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type DefaultsSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeDefaultsAPI
+}
+
+var _ = gc.Suite(&DefaultsSuite{})
+
+func (s *DefaultsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeDefaultsAPI{
+		defaults: params.EnvironmentDefaults{
+			Config: map[string]interface{}{
+				"default-series": "precise",
+				"logging-config": "<root>=DEBUG",
+			},
+			Regions: map[string]map[string]interface{}{
+				"us-east-1": {"default-series": "trusty"},
+				"us-west-1": {"default-series": "utopic"},
+			},
+		},
+	}
+}
+
+func (s *DefaultsSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := environment.NewDefaultsCommand(s.fake)
+	return testing.RunCommand(c, envcmd.Wrap(command), args...)
+}
+
+func (s *DefaultsSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args   []string
+		key    string
+		values map[string]string
+		reset  []string
+		err    string
+	}{{
+		// No args shows all the defaults.
+	}, {
+		args: []string{"default-series"},
+		key:  "default-series",
+	}, {
+		args:   []string{"default-series=trusty", "logging-config=<root>=INFO"},
+		values: map[string]string{"default-series": "trusty", "logging-config": "<root>=INFO"},
+	}, {
+		args:  []string{"--reset", "default-series,logging-config"},
+		reset: []string{"default-series", "logging-config"},
+	}, {
+		args: []string{"default-series", "logging-config"},
+		err:  `expected "key=value", got "default-series"`,
+	}, {
+		args: []string{"--reset", "default-series", "default-series=trusty"},
+		err:  `key "default-series" cannot be both set and reset`,
+	}, {
+		args: []string{"--reset", "default-series", "logging-config"},
+		err:  "cannot show and reset defaults at the same time",
+	}} {
+		c.Logf("test %d", i)
+		command := &environment.DefaultsCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(command.Key, gc.Equals, test.key)
+		c.Check(command.Values, jc.DeepEquals, test.values)
+		c.Check(command.Reset, jc.DeepEquals, test.reset)
+	}
+}
+
+func (s *DefaultsSuite) TestShowAll(c *gc.C) {
+	ctx, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	output := strings.TrimSpace(testing.Stdout(ctx))
+	c.Assert(output, gc.Equals, ""+
+		"default-series:\n"+
+		"  default: precise\n"+
+		"  regions:\n"+
+		"    us-east-1: trusty\n"+
+		"    us-west-1: utopic\n"+
+		"logging-config:\n"+
+		"  default: <root>=DEBUG")
+}
+
+func (s *DefaultsSuite) TestShowKeyInRegion(c *gc.C) {
+	ctx, err := s.run(c, "--region", "us-east-1", "--format", "json", "default-series")
+	c.Assert(err, jc.ErrorIsNil)
+	output := strings.TrimSpace(testing.Stdout(ctx))
+	c.Assert(output, gc.Equals, `{"default":"precise","regions":{"us-east-1":"trusty"}}`)
+}
+
+func (s *DefaultsSuite) TestShowUnknownKey(c *gc.C) {
+	_, err := s.run(c, "no-such-key")
+	c.Assert(err, gc.ErrorMatches, `no defaults set for key "no-such-key"`)
+}
+
+func (s *DefaultsSuite) TestSet(c *gc.C) {
+	_, err := s.run(c, "--region", "us-east-1", "default-series=vivid")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.region, gc.Equals, "us-east-1")
+	c.Assert(s.fake.values, jc.DeepEquals, map[string]interface{}{
+		"default-series": "vivid",
+	})
+	c.Assert(s.fake.keys, gc.HasLen, 0)
+}
+
+func (s *DefaultsSuite) TestSetAndReset(c *gc.C) {
+	_, err := s.run(c, "--reset", "logging-config", "default-series=vivid")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.region, gc.Equals, "")
+	c.Assert(s.fake.values, jc.DeepEquals, map[string]interface{}{
+		"default-series": "vivid",
+	})
+	c.Assert(s.fake.keys, jc.DeepEquals, []string{"logging-config"})
+}
+
+func (s *DefaultsSuite) TestSetError(c *gc.C) {
+	s.fake.err = errors.New("permission denied")
+	_, err := s.run(c, "default-series=vivid")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type fakeDefaultsAPI struct {
+	defaults params.EnvironmentDefaults
+	region   string
+	values   map[string]interface{}
+	keys     []string
+	err      error
+}
+
+func (f *fakeDefaultsAPI) Close() error {
+	return nil
+}
+
+func (f *fakeDefaultsAPI) EnvironmentDefaults() (params.EnvironmentDefaults, error) {
+	return f.defaults, nil
+}
+
+func (f *fakeDefaultsAPI) SetEnvironmentDefaults(region string, config map[string]interface{}) error {
+	f.region = region
+	f.values = config
+	return f.err
+}
+
+func (f *fakeDefaultsAPI) UnsetEnvironmentDefaults(region string, keys ...string) error {
+	f.region = region
+	f.keys = keys
+	return f.err
+}
@@ -32,8 +32,12 @@ func NewSuperCommand() cmd.Command {
 	environmentCmd.Register(envcmd.Wrap(&UnsetCommand{}))
 	environmentCmd.Register(&JenvCommand{})
 	environmentCmd.Register(envcmd.Wrap(&RetryProvisioningCommand{}))
+	environmentCmd.Register(envcmd.Wrap(&SuspendCommand{}))
+	environmentCmd.Register(envcmd.Wrap(&ResumeCommand{}))
 	if featureflag.Enabled(feature.JES) {
 		environmentCmd.Register(envcmd.Wrap(&CreateCommand{}))
+		environmentCmd.Register(envcmd.Wrap(&DefaultsCommand{}))
+		environmentCmd.Register(envcmd.Wrap(&UpdateCredentialCommand{}))
 	}
 	return environmentCmd
 }
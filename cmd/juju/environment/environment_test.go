@@ -23,8 +23,10 @@ var expectedCommmandNames = []string{
 	"get",
 	"help",
 	"jenv",
+	"resume",
 	"retry-provisioning",
 	"set",
+	"suspend",
 	"unset",
 }
 
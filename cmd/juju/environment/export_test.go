@@ -37,3 +37,31 @@ func NewCreateCommand(api CreateEnvironmentAPI) *CreateCommand {
 		api: api,
 	}
 }
+
+// NewDefaultsCommand returns a DefaultsCommand with the api provided as specified.
+func NewDefaultsCommand(api DefaultsAPI) *DefaultsCommand {
+	return &DefaultsCommand{
+		api: api,
+	}
+}
+
+// NewUpdateCredentialCommand returns an UpdateCredentialCommand with the api provided as specified.
+func NewUpdateCredentialCommand(api UpdateCredentialAPI) *UpdateCredentialCommand {
+	return &UpdateCredentialCommand{
+		api: api,
+	}
+}
+
+// NewSuspendCommand returns a SuspendCommand with the api provided as specified.
+func NewSuspendCommand(api SuspendEnvironmentAPI) *SuspendCommand {
+	return &SuspendCommand{
+		api: api,
+	}
+}
+
+// NewResumeCommand returns a ResumeCommand with the api provided as specified.
+func NewResumeCommand(api SuspendEnvironmentAPI) *ResumeCommand {
+	return &ResumeCommand{
+		api: api,
+	}
+}
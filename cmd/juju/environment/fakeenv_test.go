@@ -26,9 +26,11 @@ func (s *fakeEnvSuite) SetUpTest(c *gc.C) {
 }
 
 type fakeEnvAPI struct {
-	values map[string]interface{}
-	err    error
-	keys   []string
+	values    map[string]interface{}
+	err       error
+	keys      []string
+	suspended bool
+	reason    string
 }
 
 func (f *fakeEnvAPI) Close() error {
@@ -48,3 +50,21 @@ func (f *fakeEnvAPI) EnvironmentUnset(keys ...string) error {
 	f.keys = keys
 	return f.err
 }
+
+func (f *fakeEnvAPI) SuspendEnvironment(reason string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.suspended = true
+	f.reason = reason
+	return nil
+}
+
+func (f *fakeEnvAPI) ResumeEnvironment() error {
+	if f.err != nil {
+		return f.err
+	}
+	f.suspended = false
+	f.reason = ""
+	return nil
+}
@@ -8,18 +8,25 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
+	"github.com/juju/juju/constraints"
 )
 
 // RetryProvisioningCommand updates machines' error status to tell
 // the provisoner that it should try to re-provision the machine.
 type RetryProvisioningCommand struct {
 	envcmd.EnvCommandBase
-	Machines []names.MachineTag
-	api      RetryProvisioningAPI
+	Machines    []names.MachineTag
+	Constraints *constraints.Value
+	Series      string
+	Zone        string
+	api         RetryProvisioningAPI
+
+	constraintsStr string
 }
 
 // RetryProvisioningAPI defines methods on the client API
@@ -27,20 +34,50 @@ type RetryProvisioningCommand struct {
 type RetryProvisioningAPI interface {
 	Close() error
 	RetryProvisioning(machines ...names.MachineTag) ([]params.ErrorResult, error)
+	RetryProvisioningWithOverrides(machines ...params.RetryProvisioningArg) ([]params.ErrorResult, error)
 }
 
+const retryProvisioningDoc = `
+Retries provisioning of machines which failed to provision.
+
+The constraints, series and availability zone used to provision the
+machines may be changed for the next attempt, for example when the
+previous attempt failed because the requested instance type or image
+is not available:
+
+    juju retry-provisioning 1 --constraints "instance-type=m3.large"
+    juju retry-provisioning 2 3 --series trusty --zone us-east-1c
+
+The series of a machine cannot be changed once units are assigned to
+it, and containers cannot be placed in an availability zone.
+`
+
 func (c *RetryProvisioningCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "retry-provisioning",
 		Args:    "<machine> [...]",
 		Purpose: "retries provisioning for failed machines",
+		Doc:     retryProvisioningDoc,
 	}
 }
 
+func (c *RetryProvisioningCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.constraintsStr, "constraints", "", "constraints to provision the machines with")
+	f.StringVar(&c.Series, "series", "", "series to provision the machines with")
+	f.StringVar(&c.Zone, "zone", "", "availability zone to provision the machines in")
+}
+
 func (c *RetryProvisioningCommand) Init(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("no machine specified")
 	}
+	if c.constraintsStr != "" {
+		cons, err := constraints.Parse(c.constraintsStr)
+		if err != nil {
+			return err
+		}
+		c.Constraints = &cons
+	}
 	c.Machines = make([]names.MachineTag, len(args))
 	for i, arg := range args {
 		if !names.IsValidMachine(arg) {
@@ -65,7 +102,21 @@ func (c *RetryProvisioningCommand) Run(context *cmd.Context) error {
 	}
 	defer client.Close()
 
-	results, err := client.RetryProvisioning(c.Machines...)
+	var results []params.ErrorResult
+	if c.Constraints == nil && c.Series == "" && c.Zone == "" {
+		results, err = client.RetryProvisioning(c.Machines...)
+	} else {
+		args := make([]params.RetryProvisioningArg, len(c.Machines))
+		for i, machine := range c.Machines {
+			args[i] = params.RetryProvisioningArg{
+				Tag:         machine.String(),
+				Constraints: c.Constraints,
+				Series:      c.Series,
+				Zone:        c.Zone,
+			}
+		}
+		results, err = client.RetryProvisioningWithOverrides(args...)
+	}
 	if err != nil {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
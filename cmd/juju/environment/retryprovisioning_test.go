@@ -17,6 +17,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/testing"
 )
 
@@ -31,8 +32,9 @@ var _ = gc.Suite(&retryProvisioningSuite{})
 // about machines in the environment to mock out the behavior
 // of the real RetryProvisioning command.
 type fakeRetryProvisioningClient struct {
-	m   map[string]fakeMachine
-	err error
+	m         map[string]fakeMachine
+	err       error
+	overrides []params.RetryProvisioningArg
 }
 
 type fakeMachine struct {
@@ -76,6 +78,21 @@ func (f *fakeRetryProvisioningClient) RetryProvisioning(machines ...names.Machin
 	return results, nil
 }
 
+func (f *fakeRetryProvisioningClient) RetryProvisioningWithOverrides(machines ...params.RetryProvisioningArg) (
+	[]params.ErrorResult, error) {
+
+	f.overrides = machines
+	tags := make([]names.MachineTag, len(machines))
+	for i, machine := range machines {
+		tag, err := names.ParseMachineTag(machine.Tag)
+		if err != nil {
+			return nil, err
+		}
+		tags[i] = tag
+	}
+	return f.RetryProvisioning(tags...)
+}
+
 func (s *retryProvisioningSuite) SetUpTest(c *gc.C) {
 	s.FakeJujuHomeSuite.SetUpTest(c)
 
@@ -140,6 +157,40 @@ func (s *retryProvisioningSuite) TestRetryProvisioning(c *gc.C) {
 	}
 }
 
+func (s *retryProvisioningSuite) TestRetryProvisioningWithOverrides(c *gc.C) {
+	command := environment.NewRetryProvisioningCommand(s.fake)
+	context, err := testing.RunCommand(c, envcmd.Wrap(command),
+		"0", "1", "--constraints", "mem=4G", "--series", "trusty", "--zone", "a-zone",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(context), gc.Equals, "machine 1 is not in an error state\n")
+	cons := constraints.MustParse("mem=4G")
+	c.Assert(s.fake.overrides, jc.DeepEquals, []params.RetryProvisioningArg{{
+		Tag:         "machine-0",
+		Constraints: &cons,
+		Series:      "trusty",
+		Zone:        "a-zone",
+	}, {
+		Tag:         "machine-1",
+		Constraints: &cons,
+		Series:      "trusty",
+		Zone:        "a-zone",
+	}})
+}
+
+func (s *retryProvisioningSuite) TestRetryProvisioningWithoutOverrides(c *gc.C) {
+	command := environment.NewRetryProvisioningCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(command), "0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.overrides, gc.IsNil)
+}
+
+func (s *retryProvisioningSuite) TestRetryProvisioningInvalidConstraints(c *gc.C) {
+	command := environment.NewRetryProvisioningCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(command), "0", "--constraints", "bad=wolf")
+	c.Assert(err, gc.ErrorMatches, `unknown constraint "bad"`)
+}
+
 func (s *retryProvisioningSuite) TestBlockRetryProvisioning(c *gc.C) {
 	s.fake.err = common.ErrOperationBlocked("TestBlockRetryProvisioning")
 	command := environment.NewRetryProvisioningCommand(s.fake)
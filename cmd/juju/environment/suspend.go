@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// SuspendCommand suspends the automation of an environment.
+type SuspendCommand struct {
+	envcmd.EnvCommandBase
+	api    SuspendEnvironmentAPI
+	Reason string
+}
+
+// SuspendEnvironmentAPI defines the methods on the client API that
+// the suspend and resume commands call.
+type SuspendEnvironmentAPI interface {
+	Close() error
+	SuspendEnvironment(reason string) error
+	ResumeEnvironment() error
+}
+
+const suspendDoc = `
+Suspends the automated changes Juju makes to the environment, for
+example while the provider is unavailable, until the environment is
+resumed with "juju environment resume".
+
+While the environment is suspended, no machines are provisioned or
+removed, firewall rules are not changed, and units do not install or
+upgrade charms. Agents continue to run, and the environment's status
+can still be read and changed.
+
+    juju environment suspend --reason "provider outage"
+`
+
+func (c *SuspendCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "suspend",
+		Purpose: "suspend automated changes to the environment",
+		Doc:     strings.TrimSpace(suspendDoc),
+	}
+}
+
+func (c *SuspendCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Reason, "reason", "", "reason for the suspension, reported to agents")
+}
+
+func (c *SuspendCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *SuspendCommand) getAPI() (SuspendEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+func (c *SuspendCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return block.ProcessBlockedError(client.SuspendEnvironment(c.Reason), block.BlockChange)
+}
+
+// ResumeCommand resumes the automation of an environment suspended
+// with SuspendCommand.
+type ResumeCommand struct {
+	envcmd.EnvCommandBase
+	api SuspendEnvironmentAPI
+}
+
+const resumeDoc = `
+Resumes the automated changes Juju makes to an environment suspended
+with "juju environment suspend". An environment whose credentials are
+rejected by the provider remains suspended until they are updated.
+`
+
+func (c *ResumeCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "resume",
+		Purpose: "resume automated changes to the environment",
+		Doc:     strings.TrimSpace(resumeDoc),
+	}
+}
+
+func (c *ResumeCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ResumeCommand) getAPI() (SuspendEnvironmentAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+func (c *ResumeCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return block.ProcessBlockedError(client.ResumeEnvironment(), block.BlockChange)
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type SuspendSuite struct {
+	fakeEnvSuite
+}
+
+var _ = gc.Suite(&SuspendSuite{})
+
+func (s *SuspendSuite) runSuspend(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := environment.NewSuspendCommand(s.fake)
+	return testing.RunCommand(c, envcmd.Wrap(command), args...)
+}
+
+func (s *SuspendSuite) runResume(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := environment.NewResumeCommand(s.fake)
+	return testing.RunCommand(c, envcmd.Wrap(command), args...)
+}
+
+func (s *SuspendSuite) TestInit(c *gc.C) {
+	err := testing.InitCommand(&environment.SuspendCommand{}, []string{"extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+	err = testing.InitCommand(&environment.ResumeCommand{}, []string{"extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *SuspendSuite) TestSuspendResume(c *gc.C) {
+	_, err := s.runSuspend(c, "--reason", "provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.suspended, jc.IsTrue)
+	c.Assert(s.fake.reason, gc.Equals, "provider outage")
+
+	_, err = s.runResume(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.suspended, jc.IsFalse)
+}
+
+func (s *SuspendSuite) TestBlockedError(c *gc.C) {
+	s.fake.err = common.ErrOperationBlocked("TestBlockedError")
+	_, err := s.runSuspend(c)
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	// msg is logged
+	c.Check(c.GetTestLog(), jc.Contains, "TestBlockedError")
+}
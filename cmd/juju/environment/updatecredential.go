@@ -0,0 +1,113 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/clouds"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// UpdateCredentialCommand adds or replaces a credential held for a
+// cloud on which the Juju Environment Server hosts environments.
+type UpdateCredentialCommand struct {
+	envcmd.EnvCommandBase
+	api UpdateCredentialAPI
+	// These attributes are exported only for testing purposes.
+	Owner      string
+	Cloud      string
+	Name       string
+	Attributes map[string]string
+}
+
+const updateCredentialHelpDoc = `
+Credentials are held by users for the clouds on which the Juju Environment
+Server hosts environments, and are given the environments created with
+them. Updating a credential, for instance after rotating the secret key,
+gives the new attributes to every environment created with it. Those
+environments' workers use the new attributes without being restarted, and
+environments suspended because the provider rejected the old credential
+resume once the new one is accepted.
+
+Credentials are updated for the current user, unless --owner is given.
+Only the owner of the Juju Environment Server may update the credentials
+of other users.
+
+Examples:
+
+  juju environment update-credential aws default access-key=AKIA... secret-key=...
+  juju environment update-credential --owner bob aws default secret-key=...
+`
+
+func (c *UpdateCredentialCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "update-credential",
+		Args:    "<cloud> <credential> <key>=<value> ...",
+		Purpose: "add or replace a cloud credential",
+		Doc:     strings.TrimSpace(updateCredentialHelpDoc),
+	}
+}
+
+func (c *UpdateCredentialCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Owner, "owner", "", "the user holding the credential, if not the current user")
+}
+
+func (c *UpdateCredentialCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("cloud name is required")
+	case 1:
+		return errors.New("credential name is required")
+	case 2:
+		return errors.New("credential attributes are required")
+	}
+	c.Cloud, c.Name = args[0], args[1]
+	attrs, err := keyvalues.Parse(args[2:], true)
+	if err != nil {
+		return err
+	}
+	c.Attributes = attrs
+	return nil
+}
+
+// UpdateCredentialAPI defines the API methods used by the
+// update-credential command.
+type UpdateCredentialAPI interface {
+	Close() error
+	UpdateCredential(owner, cloud, name string, attrs map[string]string) error
+}
+
+func (c *UpdateCredentialCommand) getAPI() (UpdateCredentialAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, err
+	}
+	return clouds.NewClient(root), nil
+}
+
+func (c *UpdateCredentialCommand) Run(ctx *cmd.Context) error {
+	owner := c.Owner
+	if owner == "" {
+		creds, err := c.ConnectionCredentials()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		owner = creds.User
+	}
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.UpdateCredential(owner, c.Cloud, c.Name, c.Attributes)
+}
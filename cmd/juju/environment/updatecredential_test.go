@@ -0,0 +1,105 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type UpdateCredentialSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeUpdateCredentialAPI
+}
+
+var _ = gc.Suite(&UpdateCredentialSuite{})
+
+func (s *UpdateCredentialSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeUpdateCredentialAPI{}
+}
+
+func (s *UpdateCredentialSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := environment.NewUpdateCredentialCommand(s.fake)
+	return testing.RunCommand(c, envcmd.Wrap(command), args...)
+}
+
+func (s *UpdateCredentialSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args  []string
+		cloud string
+		name  string
+		attrs map[string]string
+		err   string
+	}{{
+		err: "cloud name is required",
+	}, {
+		args: []string{"aws"},
+		err:  "credential name is required",
+	}, {
+		args: []string{"aws", "default"},
+		err:  "credential attributes are required",
+	}, {
+		args: []string{"aws", "default", "secret-key"},
+		err:  `expected "key=value", got "secret-key"`,
+	}, {
+		args:  []string{"aws", "default", "access-key=foo", "secret-key=bar"},
+		cloud: "aws",
+		name:  "default",
+		attrs: map[string]string{"access-key": "foo", "secret-key": "bar"},
+	}} {
+		c.Logf("test %d", i)
+		command := &environment.UpdateCredentialCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(command.Cloud, gc.Equals, test.cloud)
+		c.Check(command.Name, gc.Equals, test.name)
+		c.Check(command.Attributes, jc.DeepEquals, test.attrs)
+	}
+}
+
+func (s *UpdateCredentialSuite) TestRun(c *gc.C) {
+	_, err := s.run(c, "--owner", "bob", "aws", "default", "secret-key=bar")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.owner, gc.Equals, "bob")
+	c.Assert(s.fake.cloud, gc.Equals, "aws")
+	c.Assert(s.fake.name, gc.Equals, "default")
+	c.Assert(s.fake.attrs, jc.DeepEquals, map[string]string{"secret-key": "bar"})
+}
+
+func (s *UpdateCredentialSuite) TestRunError(c *gc.C) {
+	s.fake.err = errors.New("permission denied")
+	_, err := s.run(c, "--owner", "bob", "aws", "default", "secret-key=bar")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type fakeUpdateCredentialAPI struct {
+	owner string
+	cloud string
+	name  string
+	attrs map[string]string
+	err   error
+}
+
+func (f *fakeUpdateCredentialAPI) Close() error {
+	return nil
+}
+
+func (f *fakeUpdateCredentialAPI) UpdateCredential(owner, cloud, name string, attrs map[string]string) error {
+	f.owner = owner
+	f.cloud = cloud
+	f.name = name
+	f.attrs = attrs
+	return f.err
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	"github.com/juju/cmd"
+	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
@@ -16,12 +17,25 @@ import (
 type ExposeCommand struct {
 	envcmd.EnvCommandBase
 	ServiceName string
+	CIDRs       []string
 }
 
 var jujuExposeHelp = `
 Adjusts firewall rules and similar security mechanisms of the provider, to
 allow the service to be accessed on its public address.
 
+By default the service's open ports may be reached from the address ranges
+in the environment's ingress-allowed setting, or from any address if it is
+not set. With --to-cidrs, they may be reached only from the given
+comma-separated address ranges, for example:
+
+    juju expose wordpress --to-cidrs 10.0.0.0/8,192.168.1.0/24
+
+Exposing a service again replaces any previous restriction. On MAAS and
+manually provisioned machines, which have no provider firewall, the
+restriction is enforced by each machine's host firewall. Providers whose
+firewall cannot restrict access by address leave restricted ports closed.
+
 `
 
 func (c *ExposeCommand) Info() *cmd.Info {
@@ -33,6 +47,10 @@ func (c *ExposeCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *ExposeCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.Var(cmd.NewStringsValue(nil, &c.CIDRs), "to-cidrs", "expose the service only to these address ranges")
+}
+
 func (c *ExposeCommand) Init(args []string) error {
 	if len(args) == 0 {
 		return errors.New("no service name specified")
@@ -49,5 +67,5 @@ func (c *ExposeCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
-	return block.ProcessBlockedError(client.ServiceExpose(c.ServiceName), block.BlockChange)
+	return block.ProcessBlockedError(client.ServiceExpose(c.ServiceName, c.CIDRs...), block.BlockChange)
 }
@@ -55,6 +55,22 @@ func (s *ExposeSuite) TestExpose(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `service "nonexistent-service" not found`)
 }
 
+func (s *ExposeSuite) TestExposeToCIDRs(c *gc.C) {
+	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "some-service-name")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = runExpose(c, "some-service-name", "--to-cidrs", "10.0.0.0/8,192.168.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertExposed(c, "some-service-name")
+	svc, err := s.State.Service("some-service-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.ExposedCIDRs(), jc.DeepEquals, []string{"10.0.0.0/8", "192.168.1.0/24"})
+
+	err = runExpose(c, "some-service-name", "--to-cidrs", "10.0.0.0")
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "some-service-name": CIDR "10.0.0.0" not valid`)
+}
+
 func (s *ExposeSuite) TestBlockExpose(c *gc.C) {
 	testcharms.Repo.CharmArchivePath(s.SeriesPath, "dummy")
 	err := runDeploy(c, "local:dummy", "some-service-name")
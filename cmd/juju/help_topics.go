@@ -365,6 +365,13 @@ root-disk
    defaults to megabytes and may be specified in the same manner as the mem
    constraint.
 
+root-disk-source
+   Root-disk-source defines where the machine's root disk is provided from.
+   The recognized values depend on the provider; on OpenStack they are
+   "local", for the disk provided by the flavor, and "volume", to boot from
+   a Cinder volume sized by the root-disk constraint.  Root-disk-source is
+   currently only supported by the OpenStack environment.
+
 container
    Container defines that the machine must be a container of the specified type.
    A container of that type may be created by juju to fulfill the request.
@@ -379,6 +386,21 @@ cpu-power
    roughly, a single 2007-era Xeon).  Cpu-power is currently only supported by
    the Amazon EC2 environment.
 
+gpus
+   Gpus is a whole number that defines the minimum number of GPUs the machine
+   must have.  On OpenStack, flavors are matched using the PCI passthrough
+   aliases and virtual GPU resources in their extra specs.  LXC containers are
+   given NVIDIA GPUs passed through from the host.  Existing machines are
+   matched using the GPUs detected by their agents.  Gpus is currently only
+   supported by the OpenStack and local environments and by LXC containers.
+
+gpu-type
+   Gpu-type defines the type of the machine's GPUs, such as "nvidia", "amd"
+   or "intel".  On OpenStack the type of a flavor's GPUs is the part of their
+   PCI passthrough alias before any "-", so that devices aliased "nvidia-k80"
+   satisfy gpu-type=nvidia.  Only "nvidia" GPUs can be passed through to LXC
+   containers.
+
 tags
    Tags defines the list of tags that the machine must have applied to it.
    Multiple tags must be delimited by a comma. Both positive and negative
@@ -400,6 +422,13 @@ instance-type
    conflict with other constraints depending on the provider (since the instance
    type my determine things like memory size etc.)
 
+zones
+   Zones defines the list of availability zones, one of which the machine must
+   be started in.  Multiple zones must be delimited by a comma.  Juju spreads
+   machines across the given zones as it would across all zones when the
+   constraint is not set.  Zones are currently only supported by the OpenStack
+   environment.  Example: zones=az1,az2
+
 Example:
 
    juju add-machine --constraints "arch=amd64 mem=8G tags=foo,^bar"
@@ -0,0 +1,143 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const hookOutputDoc = `
+Show the result of the hook a unit's agent ran most recently, and the
+last part of the output the hook wrote to its standard output and
+standard error. When a hook fails, this shows why without searching
+debug-log.
+
+The amount of output kept for each hook is set by the
+hook-output-limit environment setting.
+
+Examples:
+
+    juju hook-output mysql/0
+    juju hook-output mysql/0 --format yaml
+`
+
+// HookOutputCommand shows the result and output of the hook a unit's
+// agent ran most recently.
+type HookOutputCommand struct {
+	envcmd.EnvCommandBase
+	out      cmd.Output
+	unitName string
+}
+
+// Info implements Command.Info.
+func (c *HookOutputCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "hook-output",
+		Args:    "<unit>",
+		Purpose: "show the output of the hook a unit ran most recently",
+		Doc:     hookOutputDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *HookOutputCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "plain", map[string]cmd.Formatter{
+		"yaml":  cmd.FormatYaml,
+		"json":  cmd.FormatJson,
+		"plain": formatHookOutputPlain,
+	})
+}
+
+// Init implements Command.Init.
+func (c *HookOutputCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit specified")
+	}
+	c.unitName, args = args[0], args[1:]
+	if !names.IsValidUnit(c.unitName) {
+		return errors.Errorf("invalid unit name %q", c.unitName)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// HookOutputAPI defines the API methods used by the hook-output
+// command.
+type HookOutputAPI interface {
+	Close() error
+	UnitHookResult(unit string) (params.HookResult, error)
+}
+
+var getHookOutputAPI = func(c *HookOutputCommand) (HookOutputAPI, error) {
+	return c.NewAPIClient()
+}
+
+// Run implements Command.Run.
+func (c *HookOutputCommand) Run(ctx *cmd.Context) error {
+	api, err := getHookOutputAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	result, err := api.UnitHookResult(c.unitName)
+	if params.IsCodeNotFound(err) {
+		return errors.Errorf("unit %q has not reported the result of any hook", c.unitName)
+	} else if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, unitHookOutput{
+		Unit:      c.unitName,
+		Hook:      result.Hook,
+		Time:      result.Time,
+		Error:     result.Error,
+		Output:    result.Output,
+		Truncated: result.Truncated,
+	})
+}
+
+// unitHookOutput defines the serialization behaviour of the result of
+// a unit's hook.
+type unitHookOutput struct {
+	Unit      string    `yaml:"unit" json:"unit"`
+	Hook      string    `yaml:"hook" json:"hook"`
+	Time      time.Time `yaml:"time" json:"time"`
+	Error     string    `yaml:"error,omitempty" json:"error,omitempty"`
+	Output    string    `yaml:"output" json:"output"`
+	Truncated bool      `yaml:"truncated,omitempty" json:"truncated,omitempty"`
+}
+
+// formatHookOutputPlain returns a line describing the result of a
+// unit's hook, followed by the hook's output.
+func formatHookOutputPlain(value interface{}) ([]byte, error) {
+	result, ok := value.(unitHookOutput)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", result, value)
+	}
+	var out bytes.Buffer
+	outcome := "succeeded"
+	if result.Error != "" {
+		outcome = "failed: " + result.Error
+	}
+	fmt.Fprintf(&out, "%s: %q hook %s at %s\n",
+		result.Unit, result.Hook, outcome, result.Time.Format(time.RFC3339),
+	)
+	if result.Output == "" {
+		fmt.Fprintln(&out, "(no output)")
+		return out.Bytes(), nil
+	}
+	if result.Truncated {
+		fmt.Fprintln(&out, "(earlier output discarded)")
+	}
+	out.WriteString(result.Output)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HookOutputSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeHookOutputAPI
+}
+
+var _ = gc.Suite(&HookOutputSuite{})
+
+type fakeHookOutputAPI struct {
+	unit   string
+	result params.HookResult
+	err    error
+}
+
+func (f *fakeHookOutputAPI) Close() error {
+	return nil
+}
+
+func (f *fakeHookOutputAPI) UnitHookResult(unit string) (params.HookResult, error) {
+	f.unit = unit
+	return f.result, f.err
+}
+
+func (s *HookOutputSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeHookOutputAPI{
+		result: params.HookResult{
+			Hook:   "config-changed",
+			Time:   time.Date(2015, 6, 1, 12, 30, 0, 0, time.UTC),
+			Output: "starting mysql\n",
+		},
+	}
+	s.PatchValue(&getHookOutputAPI, func(*HookOutputCommand) (HookOutputAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *HookOutputSuite) TestHookOutput(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&HookOutputCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		`mysql/0: "config-changed" hook succeeded at 2015-06-01T12:30:00Z`+"\n"+
+		"starting mysql\n",
+	)
+	c.Assert(s.api.unit, gc.Equals, "mysql/0")
+}
+
+func (s *HookOutputSuite) TestHookOutputFailedTruncated(c *gc.C) {
+	s.api.result.Error = "exit status 1"
+	s.api.result.Output = "cannot bind port 3306\n"
+	s.api.result.Truncated = true
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&HookOutputCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		`mysql/0: "config-changed" hook failed: exit status 1 at 2015-06-01T12:30:00Z`+"\n"+
+		"(earlier output discarded)\n"+
+		"cannot bind port 3306\n",
+	)
+}
+
+func (s *HookOutputSuite) TestHookOutputEmpty(c *gc.C) {
+	s.api.result.Output = ""
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&HookOutputCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		`mysql/0: "config-changed" hook succeeded at 2015-06-01T12:30:00Z`+"\n"+
+		"(no output)\n",
+	)
+}
+
+func (s *HookOutputSuite) TestHookOutputYaml(c *gc.C) {
+	s.api.result.Error = "exit status 1"
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&HookOutputCommand{}), "mysql/0", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+unit: mysql/0
+hook: config-changed
+time: 2015-06-01T12:30:00Z
+error: exit status 1
+output: |
+  starting mysql
+`[1:])
+}
+
+func (s *HookOutputSuite) TestHookOutputNotFound(c *gc.C) {
+	s.api.err = common.ServerError(errors.NotFoundf("hook result for unit %q", "mysql/0"))
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&HookOutputCommand{}), "mysql/0")
+	c.Assert(err, gc.ErrorMatches, `unit "mysql/0" has not reported the result of any hook`)
+}
+
+func (s *HookOutputSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no unit specified",
+	}, {
+		args: []string{"mysql"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"mysql/0", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&HookOutputCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/connections"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const listConnectionsDoc = `
+List the API connections currently being served by the state server
+the client is connected to. For each connection, the entity that logged
+in, the remote address, when the connection was made, when it was last
+used and how many calls have been made to each facade are shown.
+
+In a highly available environment only the connections to the state
+server the client is connected to are shown.
+`
+
+// ListConnectionsCommand lists the connections to an API server.
+type ListConnectionsCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+}
+
+// Info implements Command.Info.
+func (c *ListConnectionsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-connections",
+		Purpose: "list connections to the API server",
+		Doc:     listConnectionsDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ListConnectionsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatConnectionsTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *ListConnectionsCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// ListConnectionsAPI defines the API methods used by the
+// list-connections command.
+type ListConnectionsAPI interface {
+	Close() error
+	List() ([]params.ConnectionDetails, error)
+}
+
+var getListConnectionsAPI = func(c *ListConnectionsCommand) (ListConnectionsAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return connections.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *ListConnectionsCommand) Run(ctx *cmd.Context) error {
+	api, err := getListConnectionsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	conns, err := api.List()
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatConnections(conns))
+}
+
+// connectionInfo defines the serialization behaviour of
+// connection details.
+type connectionInfo struct {
+	Id           int64          `yaml:"id" json:"id"`
+	Entity       string         `yaml:"entity" json:"entity"`
+	Environment  string         `yaml:"environment,omitempty" json:"environment,omitempty"`
+	RemoteAddr   string         `yaml:"remote-address" json:"remote-address"`
+	ConnectedAt  string         `yaml:"connected-at" json:"connected-at"`
+	LastActivity string         `yaml:"last-activity" json:"last-activity"`
+	FacadeCalls  map[string]int `yaml:"facade-calls,omitempty" json:"facade-calls,omitempty"`
+}
+
+func formatConnections(conns []params.ConnectionDetails) []connectionInfo {
+	result := make([]connectionInfo, len(conns))
+	for i, conn := range conns {
+		result[i] = connectionInfo{
+			Id:           conn.Id,
+			Entity:       conn.EntityTag,
+			Environment:  conn.EnvironTag,
+			RemoteAddr:   conn.RemoteAddr,
+			ConnectedAt:  conn.ConnectedAt.Format(time.RFC3339),
+			LastActivity: conn.LastActivity.Format(time.RFC3339),
+			FacadeCalls:  conn.FacadeCalls,
+		}
+	}
+	return result
+}
+
+// formatConnectionsTabular returns a tabular summary of connections.
+func formatConnectionsTabular(value interface{}) ([]byte, error) {
+	conns, ok := value.([]connectionInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", conns, value)
+	}
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "ID\tENTITY\tREMOTE-ADDRESS\tCONNECTED\tLAST-ACTIVITY\tCALLS")
+	for _, conn := range conns {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			conn.Id,
+			conn.Entity,
+			conn.RemoteAddr,
+			conn.ConnectedAt,
+			conn.LastActivity,
+			formatFacadeCalls(conn.FacadeCalls),
+		)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
+
+// formatFacadeCalls returns the facade call counts as a sorted,
+// comma-separated list of facade:count pairs.
+func formatFacadeCalls(calls map[string]int) string {
+	facades := make([]string, 0, len(calls))
+	for facade := range calls {
+		facades = append(facades, facade)
+	}
+	sort.Strings(facades)
+	parts := make([]string, len(facades))
+	for i, facade := range facades {
+		parts[i] = fmt.Sprintf("%s:%d", facade, calls[facade])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ListConnectionsSuite struct {
+	coretesting.FakeJujuHomeSuite
+	conns []params.ConnectionDetails
+}
+
+var _ = gc.Suite(&ListConnectionsSuite{})
+
+type fakeListConnectionsAPI struct {
+	conns []params.ConnectionDetails
+}
+
+func (f *fakeListConnectionsAPI) Close() error {
+	return nil
+}
+
+func (f *fakeListConnectionsAPI) List() ([]params.ConnectionDetails, error) {
+	return f.conns, nil
+}
+
+func (s *ListConnectionsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.conns = []params.ConnectionDetails{{
+		Id:           1,
+		EntityTag:    "machine-0",
+		RemoteAddr:   "10.0.0.1:1234",
+		ConnectedAt:  time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+		LastActivity: time.Date(2015, 4, 1, 12, 5, 0, 0, time.UTC),
+		FacadeCalls:  map[string]int{"Machiner": 3, "Agent": 1},
+	}, {
+		Id:           2,
+		EntityTag:    "user-admin",
+		RemoteAddr:   "10.0.0.2:4321",
+		ConnectedAt:  time.Date(2015, 4, 1, 12, 1, 0, 0, time.UTC),
+		LastActivity: time.Date(2015, 4, 1, 12, 1, 0, 0, time.UTC),
+	}}
+	s.PatchValue(&getListConnectionsAPI, func(*ListConnectionsCommand) (ListConnectionsAPI, error) {
+		return &fakeListConnectionsAPI{s.conns}, nil
+	})
+}
+
+func (s *ListConnectionsSuite) TestListTabular(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ListConnectionsCommand{}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"ID ENTITY     REMOTE-ADDRESS CONNECTED            LAST-ACTIVITY        CALLS\n"+
+		"1  machine-0  10.0.0.1:1234  2015-04-01T12:00:00Z 2015-04-01T12:05:00Z Agent:1,Machiner:3\n"+
+		"2  user-admin 10.0.0.2:4321  2015-04-01T12:01:00Z 2015-04-01T12:01:00Z \n",
+	)
+}
+
+func (s *ListConnectionsSuite) TestListYaml(c *gc.C) {
+	s.conns = s.conns[1:]
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ListConnectionsCommand{}), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+- id: 2
+  entity: user-admin
+  remote-address: 10.0.0.2:4321
+  connected-at: 2015-04-01T12:01:00Z
+  last-activity: 2015-04-01T12:01:00Z
+`[1:])
+}
+
+func (s *ListConnectionsSuite) TestInitRejectsArgs(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&ListConnectionsCommand{}), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
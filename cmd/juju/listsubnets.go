@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/subnets"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const listSubnetsDoc = `
+List the subnets known to the environment's provider. For each subnet,
+its CIDR, provider id, VLAN tag and the availability zones it can be
+used in are shown. A subnet with no zones listed can be used in all of
+them.
+
+When --zone is given, only the subnets that can be used in that
+availability zone are listed.
+`
+
+// ListSubnetsCommand lists the subnets known to an environment's
+// provider.
+type ListSubnetsCommand struct {
+	envcmd.EnvCommandBase
+	out  cmd.Output
+	zone string
+}
+
+// Info implements Command.Info.
+func (c *ListSubnetsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "list-subnets",
+		Purpose: "list subnets known to the environment's provider",
+		Doc:     listSubnetsDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ListSubnetsCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.zone, "zone", "", "only list subnets usable in this availability zone")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatSubnetsTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *ListSubnetsCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// ListSubnetsAPI defines the API methods used by the list-subnets
+// command.
+type ListSubnetsAPI interface {
+	Close() error
+	ListSubnets(zone string) ([]params.Subnet, error)
+}
+
+var getListSubnetsAPI = func(c *ListSubnetsCommand) (ListSubnetsAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return subnets.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *ListSubnetsCommand) Run(ctx *cmd.Context) error {
+	api, err := getListSubnetsAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	subnets, err := api.ListSubnets(c.zone)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatSubnets(subnets))
+}
+
+// subnetInfo defines the serialization behaviour of subnet details.
+type subnetInfo struct {
+	CIDR       string   `yaml:"cidr" json:"cidr"`
+	ProviderId string   `yaml:"provider-id" json:"provider-id"`
+	VLANTag    int      `yaml:"vlan-tag,omitempty" json:"vlan-tag,omitempty"`
+	Zones      []string `yaml:"zones,omitempty" json:"zones,omitempty"`
+}
+
+func formatSubnets(subnets []params.Subnet) []subnetInfo {
+	result := make([]subnetInfo, len(subnets))
+	for i, subnet := range subnets {
+		result[i] = subnetInfo{
+			CIDR:       subnet.CIDR,
+			ProviderId: subnet.ProviderId,
+			VLANTag:    subnet.VLANTag,
+			Zones:      subnet.Zones,
+		}
+	}
+	return result
+}
+
+// formatSubnetsTabular returns a tabular summary of subnets.
+func formatSubnetsTabular(value interface{}) ([]byte, error) {
+	subnets, ok := value.([]subnetInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", subnets, value)
+	}
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "CIDR\tPROVIDER-ID\tVLAN\tZONES")
+	for _, subnet := range subnets {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n",
+			subnet.CIDR,
+			subnet.ProviderId,
+			subnet.VLANTag,
+			strings.Join(subnet.Zones, ","),
+		)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ListSubnetsSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeListSubnetsAPI
+}
+
+var _ = gc.Suite(&ListSubnetsSuite{})
+
+type fakeListSubnetsAPI struct {
+	zone    string
+	subnets []params.Subnet
+}
+
+func (f *fakeListSubnetsAPI) Close() error {
+	return nil
+}
+
+func (f *fakeListSubnetsAPI) ListSubnets(zone string) ([]params.Subnet, error) {
+	f.zone = zone
+	return f.subnets, nil
+}
+
+func (s *ListSubnetsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeListSubnetsAPI{
+		subnets: []params.Subnet{{
+			CIDR:       "10.0.1.0/24",
+			ProviderId: "sub-1",
+			Zones:      []string{"zone-a", "zone-b"},
+		}, {
+			CIDR:       "10.0.2.0/24",
+			ProviderId: "sub-2",
+			VLANTag:    42,
+		}},
+	}
+	s.PatchValue(&getListSubnetsAPI, func(*ListSubnetsCommand) (ListSubnetsAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ListSubnetsSuite) TestListTabular(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ListSubnetsCommand{}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"CIDR        PROVIDER-ID VLAN ZONES\n"+
+		"10.0.1.0/24 sub-1       0    zone-a,zone-b\n"+
+		"10.0.2.0/24 sub-2       42   \n",
+	)
+	c.Assert(s.api.zone, gc.Equals, "")
+}
+
+func (s *ListSubnetsSuite) TestListYaml(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ListSubnetsCommand{}), "--format", "yaml", "--zone", "zone-a")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+- cidr: 10.0.1.0/24
+  provider-id: sub-1
+  zones:
+  - zone-a
+  - zone-b
+- cidr: 10.0.2.0/24
+  provider-id: sub-2
+  vlan-tag: 42
+`[1:])
+	c.Assert(s.api.zone, gc.Equals, "zone-a")
+}
+
+func (s *ListSubnetsSuite) TestInitRejectsArgs(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&ListSubnetsCommand{}), "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
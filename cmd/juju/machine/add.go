@@ -13,6 +13,8 @@ import (
 	"github.com/juju/utils/featureflag"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api/capabilities"
+	"github.com/juju/juju/api/instancemetadata"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
@@ -20,6 +22,7 @@ import (
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/configstore"
 	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/environs/offline"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/provider"
@@ -55,7 +58,10 @@ container types are: $CONTAINER_TYPES$.
 Manual provisioning is the process of installing Juju on an existing machine
 and bringing it under Juju's management; currently this requires that the
 machine be running Ubuntu, that it be accessible via SSH, and be running on
-the same network as the API server.
+the same network as the API server. Once the ubuntu user is set up on the
+machine, the state server connects to it over SSH, inspects it, and installs
+the machine agent, retrying if an attempt fails; its progress is reported
+until the machine is provisioned.
 
 It is possible to override or augment constraints by passing provider-specific
 "placement directives" with "--to"; these give the provider additional
@@ -63,6 +69,13 @@ information about how to allocate the machine. For example, one can direct the
 MAAS provider to acquire a particular node by specifying its hostname with
 "--to". For more information on placement directives, see "juju help placement".
 
+Environments without Internet access need the tools for the new machines,
+and any container images, to be stored on the state server. Use
+"--metadata-source" to name a directory, or a gzipped tar archive of one, in
+the layout accepted by "juju bootstrap --offline"; add machine checks that it
+holds tools for the machines' series and uploads them before adding the
+machines.
+
 Examples:
    juju machine add                      (starts a new machine)
    juju machine add -n 2                 (starts 2 new machines)
@@ -105,6 +118,10 @@ type AddCommand struct {
 	NumMachines int
 	// Disks describes disks that are to be attached to the machine.
 	Disks []storage.Constraints
+	// MetadataSource, if specified, is a local directory or archive
+	// holding tools and container images to upload before adding the
+	// machines.
+	MetadataSource string
 }
 
 func (c *AddCommand) Info() *cmd.Info {
@@ -120,6 +137,7 @@ func (c *AddCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Series, "series", "", "the charm series")
 	f.IntVar(&c.NumMachines, "n", 1, "The number of machines to add")
 	f.Var(constraints.ConstraintsValue{Target: &c.Constraints}, "constraints", "additional machine constraints")
+	f.StringVar(&c.MetadataSource, "metadata-source", "", "local path to tools and container images to upload for offline environments")
 	if featureflag.Enabled(feature.Storage) {
 		// NOTE: if/when the feature flag is removed, bump the client
 		// facade and check that the AddMachines facade version supports
@@ -153,15 +171,157 @@ func (c *AddCommand) Init(args []string) error {
 type AddMachineAPI interface {
 	AddMachines([]params.AddMachineParams) ([]params.AddMachinesResult, error)
 	AddMachines1dot18([]params.AddMachineParams) ([]params.AddMachinesResult, error)
+	AddManualHost(host string, disablePackageCommands bool) (id string, err error)
 	Close() error
-	ForceDestroyMachines(machines ...string) error
 	EnvironmentGet() (map[string]interface{}, error)
 	EnvironmentUUID() string
-	ProvisioningScript(params.ProvisioningScriptParams) (script string, err error)
+	ManualHostStatus(id string) (params.ManualHostStatus, error)
 }
 
 var manualProvisioner = manual.ProvisionMachine
 
+// CapabilitiesAPI defines the API methods used to check that the
+// environment supports the machines being added.
+type CapabilitiesAPI interface {
+	Close() error
+	EnvironCapabilities() (params.EnvironCapabilities, error)
+}
+
+var getCapabilitiesAPI = func(c *AddCommand) (CapabilitiesAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return capabilities.NewClient(root), nil
+}
+
+// checkCapabilities returns an error if the environment cannot host
+// the machines being added. Servers which cannot report the
+// environment's capabilities are left to reject the machines
+// themselves.
+func (c *AddCommand) checkCapabilities() error {
+	api, err := getCapabilitiesAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+	caps, err := api.EnvironCapabilities()
+	if params.IsCodeNotImplemented(err) {
+		logger.Debugf("cannot check environment capabilities: %v", err)
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot get environment capabilities")
+	}
+	if !caps.UnitPlacement {
+		return errors.Errorf("environment does not support adding machines: %s", caps.UnitPlacementError)
+	}
+	if c.Placement == nil {
+		return nil
+	}
+	containerType, err := instance.ParseContainerType(c.Placement.Scope)
+	if err != nil {
+		// Not a container placement.
+		return nil
+	}
+	for _, supported := range caps.Containers {
+		if supported == string(containerType) {
+			return nil
+		}
+	}
+	return errors.Errorf("environment does not support %s containers", containerType)
+}
+
+// InstanceMetadataAPI defines the API methods used to check the
+// constraints of the machines being added against the instance types
+// and images cached by the state server.
+type InstanceMetadataAPI interface {
+	Close() error
+	FindInstanceSpec(series string, cons constraints.Value) (params.InstanceType, string, error)
+}
+
+var getInstanceMetadataAPI = func(c *AddCommand) (InstanceMetadataAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return instancemetadata.NewClient(root), nil
+}
+
+// checkConstraints returns an error if no instance type offered by the
+// provider satisfies the constraints of the machines being added, and
+// otherwise reports the instance type which would be chosen. The check
+// is skipped for containers, and when the state server has not cached
+// the provider's instance types or cannot report them.
+func (c *AddCommand) checkConstraints(ctx *cmd.Context) error {
+	if c.Placement != nil {
+		if _, err := instance.ParseContainerType(c.Placement.Scope); err == nil {
+			return nil
+		}
+	}
+	api, err := getInstanceMetadataAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+	itype, imageId, err := api.FindInstanceSpec(c.Series, c.Constraints)
+	if params.IsCodeNotImplemented(err) || params.IsCodeNotFound(err) {
+		logger.Debugf("cannot check constraints: %v", err)
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot satisfy constraints")
+	}
+	ctx.Verbosef("machines will use instance type %s (cost %d) with image %s", itype.Name, itype.Cost, imageId)
+	return nil
+}
+
+// UploaderAPI defines the API methods used to store the tools and
+// container images in --metadata-source on the state server.
+type UploaderAPI interface {
+	offline.Uploader
+	Close() error
+}
+
+var getUploaderAPI = func(c *AddCommand) (UploaderAPI, error) {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return client, nil
+}
+
+// uploadOfflineArtifacts checks that --metadata-source holds tools of
+// the given version for the machines being added, and stores them, and
+// any container images it holds, on the state server.
+func (c *AddCommand) uploadOfflineArtifacts(ctx *cmd.Context, cfg *config.Config, agentVersion version.Number) error {
+	dir, cleanup, err := offline.Open(ctx.AbsPath(c.MetadataSource))
+	if err != nil {
+		return errors.Annotate(err, "cannot open offline artifacts")
+	}
+	defer cleanup()
+	artifacts, err := offline.Read(dir)
+	if err != nil {
+		return errors.Annotate(err, "cannot read offline artifacts")
+	}
+	series := c.Series
+	if series == "" {
+		series = config.PreferredSeries(cfg)
+	}
+	var arch string
+	if c.Constraints.Arch != nil {
+		arch = *c.Constraints.Arch
+	}
+	if err := artifacts.Validate(agentVersion, arch, series); err != nil {
+		return errors.Trace(err)
+	}
+	api, err := getUploaderAPI(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer api.Close()
+	ctx.Infof("uploading tools and container images from %q", c.MetadataSource)
+	return artifacts.Upload(api, artifacts.MatchingTools(agentVersion, arch, series))
+}
+
 func (c *AddCommand) getAddMachineAPI() (AddMachineAPI, error) {
 	if c.api != nil {
 		return c.api, nil
@@ -214,12 +374,24 @@ func (c *AddCommand) Run(ctx *cmd.Context) error {
 		return fmt.Errorf("machine-id cannot be specified when adding machines")
 	}
 
+	if err := c.checkCapabilities(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := c.checkConstraints(ctx); err != nil {
+		return errors.Trace(err)
+	}
+
 	jobs := []multiwatcher.MachineJob{multiwatcher.JobHostUnits}
 
 	envVersion, err := envcmd.GetEnvironmentVersion(client)
 	if err != nil {
 		return err
 	}
+	if c.MetadataSource != "" {
+		if err := c.uploadOfflineArtifacts(ctx, config, envVersion); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	// Servers before 1.21-alpha2 don't have the networker so don't
 	// try to use JobManageNetworking with them.
@@ -266,7 +438,7 @@ func (c *AddCommand) Run(ctx *cmd.Context) error {
 		results, err = client.AddMachines1dot18([]params.AddMachineParams{machineParams})
 	}
 	if params.IsCodeOperationBlocked(err) {
-		return block.ProcessBlockedError(err, block.BlockChange)
+		return block.ProcessBlockedError(err, block.BlockBudget)
 	}
 	if err != nil {
 		return errors.Trace(err)
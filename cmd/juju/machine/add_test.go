@@ -4,6 +4,10 @@
 package machine_test
 
 import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -17,17 +21,23 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/manual"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
 )
 
 type AddMachineSuite struct {
 	testing.FakeJujuHomeSuite
 	fake *fakeAddMachineAPI
+	caps *fakeCapabilitiesAPI
+	spec *fakeInstanceMetadataAPI
 }
 
 var _ = gc.Suite(&AddMachineSuite{})
@@ -36,6 +46,22 @@ func (s *AddMachineSuite) SetUpTest(c *gc.C) {
 	s.FakeJujuHomeSuite.SetUpTest(c)
 	s.fake = &fakeAddMachineAPI{}
 	s.fake.agentVersion = "1.21.0"
+	s.caps = &fakeCapabilitiesAPI{
+		caps: params.EnvironCapabilities{
+			UnitPlacement: true,
+			Containers:    []string{"lxc", "kvm"},
+		},
+	}
+	s.PatchValue(machine.GetCapabilitiesAPI, func(*machine.AddCommand) (machine.CapabilitiesAPI, error) {
+		return s.caps, nil
+	})
+	s.spec = &fakeInstanceMetadataAPI{
+		itype:   params.InstanceType{Name: "small", Cost: 10},
+		imageId: "image-0",
+	}
+	s.PatchValue(machine.GetInstanceMetadataAPI, func(*machine.AddCommand) (machine.InstanceMetadataAPI, error) {
+		return s.spec, nil
+	})
 }
 
 func (s *AddMachineSuite) TestInit(c *gc.C) {
@@ -151,6 +177,69 @@ func (s *AddMachineSuite) TestSSHPlacementError(c *gc.C) {
 	c.Assert(testing.Stderr(context), gc.Equals, "")
 }
 
+func (s *AddMachineSuite) TestUnitPlacementNotSupported(c *gc.C) {
+	s.caps.caps.UnitPlacement = false
+	s.caps.caps.UnitPlacementError = "unit placement is not supported"
+	_, err := s.run(c)
+	c.Assert(err, gc.ErrorMatches, "environment does not support adding machines: unit placement is not supported")
+	c.Assert(s.fake.args, gc.HasLen, 0)
+}
+
+func (s *AddMachineSuite) TestContainerTypeNotSupported(c *gc.C) {
+	s.caps.caps.Containers = []string{"lxc"}
+	_, err := s.run(c, "kvm:1")
+	c.Assert(err, gc.ErrorMatches, "environment does not support kvm containers")
+	c.Assert(s.fake.args, gc.HasLen, 0)
+
+	_, err = s.run(c, "lxc:1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.args, gc.HasLen, 1)
+}
+
+func (s *AddMachineSuite) TestCapabilitiesNotImplemented(c *gc.C) {
+	s.caps.caps.UnitPlacement = false
+	s.caps.err = &params.Error{Code: params.CodeNotImplemented, Message: "no such request"}
+	_, err := s.run(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.args, gc.HasLen, 1)
+}
+
+func (s *AddMachineSuite) TestConstraintsChecked(c *gc.C) {
+	_, err := s.run(c, "--constraints", "mem=8G", "--series=special")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.spec.series, gc.Equals, "special")
+	c.Assert(s.spec.cons.String(), gc.Equals, "mem=8192M")
+	c.Assert(s.fake.args, gc.HasLen, 1)
+}
+
+func (s *AddMachineSuite) TestConstraintsCannotBeSatisfied(c *gc.C) {
+	s.spec.err = &params.Error{Message: `no instance types matching constraints "mem=65536M"`}
+	_, err := s.run(c, "--constraints", "mem=64G")
+	c.Assert(err, gc.ErrorMatches, `cannot satisfy constraints: no instance types matching constraints "mem=65536M"`)
+	c.Assert(s.fake.args, gc.HasLen, 0)
+}
+
+func (s *AddMachineSuite) TestConstraintsNotChecked(c *gc.C) {
+	for i, specErr := range []error{
+		&params.Error{Code: params.CodeNotFound, Message: "instance metadata not found"},
+		&params.Error{Code: params.CodeNotImplemented, Message: "no such request"},
+	} {
+		c.Logf("test %d: %v", i, specErr)
+		s.fake.args = nil
+		s.spec.err = specErr
+		_, err := s.run(c, "--constraints", "mem=8G")
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(s.fake.args, gc.HasLen, 1)
+	}
+}
+
+func (s *AddMachineSuite) TestConstraintsNotCheckedForContainers(c *gc.C) {
+	s.spec.err = errors.New("should not be called")
+	_, err := s.run(c, "lxc")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.args, gc.HasLen, 1)
+}
+
 func (s *AddMachineSuite) TestParamsPassedOn(c *gc.C) {
 	_, err := s.run(c, "--constraints", "mem=8G", "--series=special", "zone=nz")
 	c.Assert(err, jc.ErrorIsNil)
@@ -221,6 +310,44 @@ func (s *AddMachineSuite) TestAddMachineWithDisks(c *gc.C) {
 	})
 }
 
+func (s *AddMachineSuite) createOfflineArtifacts(c *gc.C, names ...string) string {
+	dir := c.MkDir()
+	toolsDir := filepath.Join(dir, "tools", "released")
+	err := os.MkdirAll(toolsDir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, name := range names {
+		err := ioutil.WriteFile(filepath.Join(toolsDir, name), []byte(name), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return dir
+}
+
+func (s *AddMachineSuite) TestMetadataSourceUploaded(c *gc.C) {
+	uploader := &fakeUploaderAPI{}
+	s.PatchValue(machine.GetUploaderAPI, func(*machine.AddCommand) (machine.UploaderAPI, error) {
+		return uploader, nil
+	})
+	dir := s.createOfflineArtifacts(c,
+		"juju-1.21.0-trusty-amd64.tgz",
+		"juju-1.21.0-precise-amd64.tgz",
+		"juju-1.20.0-trusty-amd64.tgz",
+	)
+	_, err := s.run(c, "--series=trusty", "--metadata-source", dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(uploader.tools, jc.DeepEquals, []version.Binary{version.MustParseBinary("1.21.0-trusty-amd64")})
+	c.Assert(s.fake.args, gc.HasLen, 1)
+}
+
+func (s *AddMachineSuite) TestMetadataSourceMissingTools(c *gc.C) {
+	s.PatchValue(machine.GetUploaderAPI, func(*machine.AddCommand) (machine.UploaderAPI, error) {
+		return nil, errors.New("should not be called")
+	})
+	dir := s.createOfflineArtifacts(c, "juju-1.21.0-precise-amd64.tgz")
+	_, err := s.run(c, "--series=trusty", "--metadata-source", dir)
+	c.Assert(err, gc.ErrorMatches, `no 1.21.0 tools for trusty found in .*`)
+	c.Assert(s.fake.args, gc.HasLen, 0)
+}
+
 type fakeAddMachineAPI struct {
 	successOrder []bool
 	currentOp    int
@@ -264,14 +391,64 @@ func (f *fakeAddMachineAPI) AddMachines1dot18(args []params.AddMachineParams) ([
 	return f.AddMachines(args)
 }
 
-func (f *fakeAddMachineAPI) ForceDestroyMachines(machines ...string) error {
-	return errors.NotImplementedf("ForceDestroyMachines")
+func (f *fakeAddMachineAPI) AddManualHost(host string, disablePackageCommands bool) (string, error) {
+	return "", errors.NotImplementedf("AddManualHost")
 }
 
-func (f *fakeAddMachineAPI) ProvisioningScript(params.ProvisioningScriptParams) (script string, err error) {
-	return "", errors.NotImplementedf("ProvisioningScript")
+func (f *fakeAddMachineAPI) ManualHostStatus(id string) (params.ManualHostStatus, error) {
+	return params.ManualHostStatus{}, errors.NotImplementedf("ManualHostStatus")
 }
 
 func (f *fakeAddMachineAPI) EnvironmentGet() (map[string]interface{}, error) {
 	return map[string]interface{}{"agent-version": f.agentVersion}, nil
 }
+
+type fakeCapabilitiesAPI struct {
+	caps params.EnvironCapabilities
+	err  error
+}
+
+func (f *fakeCapabilitiesAPI) Close() error {
+	return nil
+}
+
+func (f *fakeCapabilitiesAPI) EnvironCapabilities() (params.EnvironCapabilities, error) {
+	return f.caps, f.err
+}
+
+type fakeInstanceMetadataAPI struct {
+	series  string
+	cons    constraints.Value
+	itype   params.InstanceType
+	imageId string
+	err     error
+}
+
+func (f *fakeInstanceMetadataAPI) Close() error {
+	return nil
+}
+
+func (f *fakeInstanceMetadataAPI) FindInstanceSpec(series string, cons constraints.Value) (params.InstanceType, string, error) {
+	f.series, f.cons = series, cons
+	if f.err != nil {
+		return params.InstanceType{}, "", f.err
+	}
+	return f.itype, f.imageId, nil
+}
+
+type fakeUploaderAPI struct {
+	tools []version.Binary
+}
+
+func (f *fakeUploaderAPI) Close() error {
+	return nil
+}
+
+func (f *fakeUploaderAPI) UploadTools(r io.Reader, vers version.Binary, additionalSeries ...string) (*tools.Tools, error) {
+	f.tools = append(f.tools, vers)
+	return &tools.Tools{Version: vers}, nil
+}
+
+func (f *fakeUploaderAPI) UploadImage(r io.Reader, kind instance.ContainerType, series, arch string) error {
+	return errors.NotImplementedf("UploadImage")
+}
@@ -0,0 +1,273 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// AddressPoolAPI defines the API methods used by the address pool
+// commands.
+type AddressPoolAPI interface {
+	Close() error
+	AddContainerAddressPool(machineId, bridge, cidr, gateway string, reserved []string) error
+	RemoveContainerAddressPool(machineId, bridge string) error
+	ContainerAddressPools(machineId string) ([]params.ContainerAddressPoolInfo, error)
+}
+
+// addressPoolCommandBase is embedded by the address pool commands.
+type addressPoolCommandBase struct {
+	envcmd.EnvCommandBase
+	api       AddressPoolAPI
+	MachineId string
+}
+
+func (c *addressPoolCommandBase) getAPI() (AddressPoolAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+// initMachine sets MachineId from the first of args, and returns the
+// remaining args.
+func (c *addressPoolCommandBase) initMachine(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no machine specified")
+	}
+	if !names.IsValidMachine(args[0]) {
+		return nil, errors.Errorf("invalid machine id %q", args[0])
+	}
+	c.MachineId = args[0]
+	return args[1:], nil
+}
+
+// AddAddressPoolCommand adds an address pool for the containers
+// attached to a bridge on a machine.
+type AddAddressPoolCommand struct {
+	addressPoolCommandBase
+	Bridge   string
+	CIDR     string
+	Gateway  string
+	Reserved []string
+}
+
+const addAddressPoolDoc = `
+When the provider cannot allocate addresses for the LXC and KVM containers
+on a machine, they are configured by DHCP on the machine's bridge, and their
+addresses may change when they restart. The add-address-pool command makes
+the Juju controller manage the addresses of the containers attached to the
+given bridge instead: each new container is allocated the lowest free
+address in the CIDR, which it keeps until it is removed, and the machine
+resolves the container's host name to that address.
+
+The gateway defaults to the first address in the CIDR. Addresses that are
+in use outside Juju, such as a DHCP range, can be excluded with --reserve,
+as single addresses or ranges of the form <first>-<last>.
+
+Examples:
+	# Manage the addresses of containers on lxcbr0 of machine 3
+	$ juju machine add-address-pool 3 lxcbr0 10.0.3.0/24 --reserve 10.0.3.100-10.0.3.254
+`
+
+func (c *AddAddressPoolCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add-address-pool",
+		Args:    "<machine> <bridge> <cidr>",
+		Purpose: "manage the addresses of containers on a machine's bridge",
+		Doc:     addAddressPoolDoc,
+	}
+}
+
+func (c *AddAddressPoolCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Gateway, "gateway", "", "the gateway address of the containers")
+	f.Var(cmd.NewStringsValue(nil, &c.Reserved), "reserve", "comma separated addresses or address ranges not to allocate")
+}
+
+func (c *AddAddressPoolCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	switch len(args) {
+	case 0:
+		return errors.New("no bridge specified")
+	case 1:
+		return errors.New("no CIDR specified")
+	}
+	c.Bridge, c.CIDR = args[0], args[1]
+	if _, _, err := net.ParseCIDR(c.CIDR); err != nil {
+		return errors.Errorf("invalid CIDR %q", c.CIDR)
+	}
+	if c.Gateway != "" && net.ParseIP(c.Gateway) == nil {
+		return errors.Errorf("invalid gateway address %q", c.Gateway)
+	}
+	return cmd.CheckEmpty(args[2:])
+}
+
+func (c *AddAddressPoolCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	err = client.AddContainerAddressPool(c.MachineId, c.Bridge, c.CIDR, c.Gateway, c.Reserved)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
+
+// RemoveAddressPoolCommand removes the address pool for a bridge on a
+// machine.
+type RemoveAddressPoolCommand struct {
+	addressPoolCommandBase
+	Bridge string
+}
+
+const removeAddressPoolDoc = `
+The remove-address-pool command stops the Juju controller managing the
+addresses of the containers attached to the given bridge of a machine. The
+pool cannot be removed while any containers have addresses allocated from
+it.
+
+Examples:
+	$ juju machine remove-address-pool 3 lxcbr0
+`
+
+func (c *RemoveAddressPoolCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "remove-address-pool",
+		Args:    "<machine> <bridge>",
+		Purpose: "remove the address pool of a machine's bridge",
+		Doc:     removeAddressPoolDoc,
+	}
+}
+
+func (c *RemoveAddressPoolCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errors.New("no bridge specified")
+	}
+	c.Bridge = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *RemoveAddressPoolCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	err = client.RemoveContainerAddressPool(c.MachineId, c.Bridge)
+	return block.ProcessBlockedError(err, block.BlockRemove)
+}
+
+// AddressPoolsCommand shows the address pools of a machine and the
+// addresses allocated from them.
+type AddressPoolsCommand struct {
+	addressPoolCommandBase
+	out cmd.Output
+}
+
+const addressPoolsDoc = `
+The address-pools command shows the address pools for the bridges on a
+machine, and the address and host name of each container allocated an
+address from them.
+
+Examples:
+	$ juju machine address-pools 3
+`
+
+// AddressPool defines the serialization of a container address pool.
+type AddressPool struct {
+	CIDR       string                      `yaml:"cidr" json:"cidr"`
+	Gateway    string                      `yaml:"gateway" json:"gateway"`
+	Reserved   []string                    `yaml:"reserved,omitempty" json:"reserved,omitempty"`
+	Containers map[string]ContainerAddress `yaml:"containers,omitempty" json:"containers,omitempty"`
+}
+
+// ContainerAddress defines the serialization of an address allocated
+// to a container from an address pool.
+type ContainerAddress struct {
+	Address  string `yaml:"address" json:"address"`
+	Hostname string `yaml:"hostname" json:"hostname"`
+}
+
+func (c *AddressPoolsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "address-pools",
+		Args:    "<machine>",
+		Purpose: "show the address pools of a machine's bridges",
+		Doc:     addressPoolsDoc,
+	}
+}
+
+func (c *AddressPoolsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *AddressPoolsCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *AddressPoolsCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	pools, err := client.ContainerAddressPools(c.MachineId)
+	if err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		fmt.Fprintf(ctx.Stderr, "no address pools for machine %s\n", c.MachineId)
+		return nil
+	}
+	return c.out.Write(ctx, formatAddressPools(pools))
+}
+
+func formatAddressPools(pools []params.ContainerAddressPoolInfo) map[string]AddressPool {
+	result := make(map[string]AddressPool)
+	for _, pool := range pools {
+		formatted := AddressPool{
+			CIDR:     pool.CIDR,
+			Gateway:  pool.Gateway,
+			Reserved: pool.Reserved,
+		}
+		if len(pool.Addresses) > 0 {
+			formatted.Containers = make(map[string]ContainerAddress)
+		}
+		for _, address := range pool.Addresses {
+			id := address.MachineTag
+			if tag, err := names.ParseMachineTag(address.MachineTag); err == nil {
+				id = tag.Id()
+			}
+			formatted.Containers[id] = ContainerAddress{
+				Address:  address.Address,
+				Hostname: address.Hostname,
+			}
+		}
+		result[pool.Bridge] = formatted
+	}
+	return result
+}
@@ -0,0 +1,202 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/testing"
+)
+
+type AddressPoolSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeAddressPoolAPI
+}
+
+var _ = gc.Suite(&AddressPoolSuite{})
+
+func (s *AddressPoolSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeAddressPoolAPI{}
+}
+
+func (s *AddressPoolSuite) TestAddInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		bridge      string
+		cidr        string
+		gateway     string
+		reserved    []string
+		errorString string
+	}{{
+		errorString: "no machine specified",
+	}, {
+		args:        []string{"lxc"},
+		errorString: `invalid machine id "lxc"`,
+	}, {
+		args:        []string{"3"},
+		errorString: "no bridge specified",
+	}, {
+		args:        []string{"3", "lxcbr0"},
+		errorString: "no CIDR specified",
+	}, {
+		args:        []string{"3", "lxcbr0", "10.0.3.0"},
+		errorString: `invalid CIDR "10.0.3.0"`,
+	}, {
+		args:        []string{"3", "lxcbr0", "10.0.3.0/24", "--gateway", "gw"},
+		errorString: `invalid gateway address "gw"`,
+	}, {
+		args:        []string{"3", "lxcbr0", "10.0.3.0/24", "extra"},
+		errorString: `unrecognized args: \["extra"\]`,
+	}, {
+		args:   []string{"3", "lxcbr0", "10.0.3.0/24"},
+		bridge: "lxcbr0",
+		cidr:   "10.0.3.0/24",
+	}, {
+		args:     []string{"3", "lxcbr0", "10.0.3.0/24", "--gateway", "10.0.3.254", "--reserve", "10.0.3.2,10.0.3.100-10.0.3.200"},
+		bridge:   "lxcbr0",
+		cidr:     "10.0.3.0/24",
+		gateway:  "10.0.3.254",
+		reserved: []string{"10.0.3.2", "10.0.3.100-10.0.3.200"},
+	}} {
+		c.Logf("test %d", i)
+		addCmd := &machine.AddAddressPoolCommand{}
+		err := testing.InitCommand(addCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(addCmd.MachineId, gc.Equals, "3")
+			c.Check(addCmd.Bridge, gc.Equals, test.bridge)
+			c.Check(addCmd.CIDR, gc.Equals, test.cidr)
+			c.Check(addCmd.Gateway, gc.Equals, test.gateway)
+			c.Check(addCmd.Reserved, jc.DeepEquals, test.reserved)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *AddressPoolSuite) TestAdd(c *gc.C) {
+	addCmd := machine.NewAddAddressPoolCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(addCmd), "3", "lxcbr0", "10.0.3.0/24", "--reserve", "10.0.3.2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"add 3 lxcbr0 10.0.3.0/24  [10.0.3.2]"})
+}
+
+func (s *AddressPoolSuite) TestAddBlocked(c *gc.C) {
+	s.fake.err = common.ErrOperationBlocked("TestAddBlocked")
+	addCmd := machine.NewAddAddressPoolCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(addCmd), "3", "lxcbr0", "10.0.3.0/24")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	// msg is logged
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Assert(stripped, gc.Matches, ".*TestAddBlocked.*")
+}
+
+func (s *AddressPoolSuite) TestRemoveInit(c *gc.C) {
+	removeCmd := &machine.RemoveAddressPoolCommand{}
+	err := testing.InitCommand(removeCmd, []string{"3"})
+	c.Assert(err, gc.ErrorMatches, "no bridge specified")
+	err = testing.InitCommand(removeCmd, []string{"3", "lxcbr0", "extra"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *AddressPoolSuite) TestRemove(c *gc.C) {
+	removeCmd := machine.NewRemoveAddressPoolCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(removeCmd), "3", "lxcbr0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"remove 3 lxcbr0"})
+}
+
+func (s *AddressPoolSuite) TestRemoveError(c *gc.C) {
+	s.fake.err = errors.New(`cannot remove pool "lxcbr0 on machine 3": 1 addresses allocated`)
+	removeCmd := machine.NewRemoveAddressPoolCommand(s.fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(removeCmd), "3", "lxcbr0")
+	c.Assert(err, gc.ErrorMatches, `cannot remove pool "lxcbr0 on machine 3": 1 addresses allocated`)
+}
+
+func (s *AddressPoolSuite) TestAddressPools(c *gc.C) {
+	s.fake.pools = []params.ContainerAddressPoolInfo{{
+		ContainerAddressPool: params.ContainerAddressPool{
+			MachineTag: "machine-3",
+			Bridge:     "lxcbr0",
+			CIDR:       "10.0.3.0/24",
+			Gateway:    "10.0.3.1",
+			Reserved:   []string{"10.0.3.100-10.0.3.254"},
+		},
+		Addresses: []params.ContainerPoolAddress{{
+			MachineTag: "machine-3-lxc-0",
+			Address:    "10.0.3.2",
+			Hostname:   "juju-machine-3-lxc-0",
+		}},
+	}, {
+		ContainerAddressPool: params.ContainerAddressPool{
+			MachineTag: "machine-3",
+			Bridge:     "virbr0",
+			CIDR:       "192.168.122.0/24",
+			Gateway:    "192.168.122.1",
+		},
+	}}
+	poolsCmd := machine.NewAddressPoolsCommand(s.fake)
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(poolsCmd), "3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"list 3"})
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+lxcbr0:
+  cidr: 10.0.3.0/24
+  gateway: 10.0.3.1
+  reserved:
+  - 10.0.3.100-10.0.3.254
+  containers:
+    3/lxc/0:
+      address: 10.0.3.2
+      hostname: juju-machine-3-lxc-0
+virbr0:
+  cidr: 192.168.122.0/24
+  gateway: 192.168.122.1
+`[1:])
+}
+
+func (s *AddressPoolSuite) TestAddressPoolsNone(c *gc.C) {
+	poolsCmd := machine.NewAddressPoolsCommand(s.fake)
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(poolsCmd), "3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "")
+	c.Assert(testing.Stderr(ctx), gc.Equals, "no address pools for machine 3\n")
+}
+
+type fakeAddressPoolAPI struct {
+	calls []string
+	pools []params.ContainerAddressPoolInfo
+	err   error
+}
+
+func (f *fakeAddressPoolAPI) Close() error {
+	return nil
+}
+
+func (f *fakeAddressPoolAPI) AddContainerAddressPool(machineId, bridge, cidr, gateway string, reserved []string) error {
+	f.calls = append(f.calls, "add "+strings.Join([]string{
+		machineId, bridge, cidr, gateway, "[" + strings.Join(reserved, " ") + "]",
+	}, " "))
+	return f.err
+}
+
+func (f *fakeAddressPoolAPI) RemoveContainerAddressPool(machineId, bridge string) error {
+	f.calls = append(f.calls, "remove "+machineId+" "+bridge)
+	return f.err
+}
+
+func (f *fakeAddressPoolAPI) ContainerAddressPools(machineId string) ([]params.ContainerAddressPoolInfo, error) {
+	f.calls = append(f.calls, "list "+machineId)
+	return f.pools, f.err
+}
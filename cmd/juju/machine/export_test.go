@@ -6,7 +6,10 @@ package machine
 import "github.com/juju/juju/storage"
 
 var (
-	ManualProvisioner = &manualProvisioner
+	ManualProvisioner      = &manualProvisioner
+	GetCapabilitiesAPI     = &getCapabilitiesAPI
+	GetInstanceMetadataAPI = &getInstanceMetadataAPI
+	GetUploaderAPI         = &getUploaderAPI
 )
 
 // NewAddCommand returns an AddCommand with the api provided as specified.
@@ -23,6 +26,62 @@ func NewRemoveCommand(api RemoveMachineAPI) *RemoveCommand {
 	}
 }
 
+// NewRefreshHardwareCommand returns a RefreshHardwareCommand with the
+// api provided as specified.
+func NewRefreshHardwareCommand(api RefreshHardwareAPI) *RefreshHardwareCommand {
+	return &RefreshHardwareCommand{
+		api: api,
+	}
+}
+
+// NewSecurityUpdatesCommand returns a SecurityUpdatesCommand with the
+// api provided as specified.
+func NewSecurityUpdatesCommand(api OSUpdatesAPI) *SecurityUpdatesCommand {
+	return &SecurityUpdatesCommand{
+		osUpdatesCommandBase: osUpdatesCommandBase{api: api},
+	}
+}
+
+// NewPatchCommand returns a PatchCommand with the api provided as
+// specified.
+func NewPatchCommand(api OSUpdatesAPI) *PatchCommand {
+	return &PatchCommand{
+		osUpdatesCommandBase: osUpdatesCommandBase{api: api},
+	}
+}
+
+// NewPatchStatusCommand returns a PatchStatusCommand with the api
+// provided as specified.
+func NewPatchStatusCommand(api OSUpdatesAPI) *PatchStatusCommand {
+	return &PatchStatusCommand{
+		osUpdatesCommandBase: osUpdatesCommandBase{api: api},
+	}
+}
+
+// NewAddAddressPoolCommand returns an AddAddressPoolCommand with the
+// api provided as specified.
+func NewAddAddressPoolCommand(api AddressPoolAPI) *AddAddressPoolCommand {
+	return &AddAddressPoolCommand{
+		addressPoolCommandBase: addressPoolCommandBase{api: api},
+	}
+}
+
+// NewRemoveAddressPoolCommand returns a RemoveAddressPoolCommand with
+// the api provided as specified.
+func NewRemoveAddressPoolCommand(api AddressPoolAPI) *RemoveAddressPoolCommand {
+	return &RemoveAddressPoolCommand{
+		addressPoolCommandBase: addressPoolCommandBase{api: api},
+	}
+}
+
+// NewAddressPoolsCommand returns an AddressPoolsCommand with the api
+// provided as specified.
+func NewAddressPoolsCommand(api AddressPoolAPI) *AddressPoolsCommand {
+	return &AddressPoolsCommand{
+		addressPoolCommandBase: addressPoolCommandBase{api: api},
+	}
+}
+
 func NewDisksFlag(disks *[]storage.Constraints) *disksFlag {
 	return &disksFlag{disks}
 }
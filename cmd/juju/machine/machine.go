@@ -13,7 +13,9 @@ import (
 var logger = loggo.GetLogger("juju.cmd.juju.machine")
 
 const machineCommandDoc = `
-"juju machine" provides commands to add and remove machines in the Juju environment.
+"juju machine" provides commands to add, remove and refresh the hardware of
+machines in the Juju environment, to apply their security updates, and to
+manage the addresses of the containers on them.
 `
 
 const machineCommandPurpose = "manage machines"
@@ -29,5 +31,12 @@ func NewSuperCommand() cmd.Command {
 	})
 	machineCmd.Register(envcmd.Wrap(&AddCommand{}))
 	machineCmd.Register(envcmd.Wrap(&RemoveCommand{}))
+	machineCmd.Register(envcmd.Wrap(&RefreshHardwareCommand{}))
+	machineCmd.Register(envcmd.Wrap(&SecurityUpdatesCommand{}))
+	machineCmd.Register(envcmd.Wrap(&PatchCommand{}))
+	machineCmd.Register(envcmd.Wrap(&PatchStatusCommand{}))
+	machineCmd.Register(envcmd.Wrap(&AddAddressPoolCommand{}))
+	machineCmd.Register(envcmd.Wrap(&RemoveAddressPoolCommand{}))
+	machineCmd.Register(envcmd.Wrap(&AddressPoolsCommand{}))
 	return machineCmd
 }
@@ -21,8 +21,15 @@ var _ = gc.Suite(&MachineCommandSuite{})
 
 var expectedCommmandNames = []string{
 	"add",
+	"add-address-pool",
+	"address-pools",
 	"help",
+	"patch",
+	"patch-status",
+	"refresh-hardware",
 	"remove",
+	"remove-address-pool",
+	"security-updates",
 }
 
 func (s *MachineCommandSuite) TestHelp(c *gc.C) {
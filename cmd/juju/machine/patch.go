@@ -0,0 +1,290 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/osupdates"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// OSUpdatesAPI defines the API methods used by the security-updates,
+// patch and patch-status commands.
+type OSUpdatesAPI interface {
+	Close() error
+	SecurityUpdates() ([]params.MachineOSUpdates, error)
+	StartPatchRun(maxUnavailable int) (params.PatchRun, error)
+	PatchRuns() ([]params.PatchRun, error)
+}
+
+// osUpdatesCommandBase is embedded by the commands that use the
+// OSUpdates API.
+type osUpdatesCommandBase struct {
+	envcmd.EnvCommandBase
+	api OSUpdatesAPI
+}
+
+func (c *osUpdatesCommandBase) getAPI() (OSUpdatesAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return osupdates.NewClient(root), nil
+}
+
+// SecurityUpdatesCommand lists the security updates pending on the
+// environment's machines.
+type SecurityUpdatesCommand struct {
+	osUpdatesCommandBase
+	out cmd.Output
+}
+
+const securityUpdatesDoc = `
+The agent of each machine checks for pending security updates to the
+machine's packages every few hours. The security-updates command lists the
+updates found on each machine, and when they were last checked for.
+
+Security updates are applied with "juju machine patch".
+`
+
+// MachineSecurityUpdates defines the serialization of the security
+// updates pending on a machine.
+type MachineSecurityUpdates struct {
+	Packages []string `yaml:"packages,omitempty" json:"packages,omitempty"`
+	Checked  string   `yaml:"checked" json:"checked"`
+	PatchRun int      `yaml:"patch-run,omitempty" json:"patch-run,omitempty"`
+}
+
+func (c *SecurityUpdatesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "security-updates",
+		Purpose: "list the security updates pending on machines",
+		Doc:     securityUpdatesDoc,
+	}
+}
+
+func (c *SecurityUpdatesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *SecurityUpdatesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *SecurityUpdatesCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	results, err := client.SecurityUpdates()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(ctx.Stderr, "no machines have reported security updates\n")
+		return nil
+	}
+	output := make(map[string]MachineSecurityUpdates)
+	for _, result := range results {
+		output[result.MachineId] = MachineSecurityUpdates{
+			Packages: result.SecurityUpdates,
+			Checked:  result.Checked.Format(time.RFC1123),
+			PatchRun: result.PatchRun,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+// PatchCommand starts a rolling patch run applying the security
+// updates pending on the environment's machines.
+type PatchCommand struct {
+	osUpdatesCommandBase
+	MaxUnavailable int
+}
+
+const patchDoc = `
+The patch command applies the security updates pending on the environment's
+machines, as listed by "juju machine security-updates", a few machines at a
+time. Each machine's agent upgrades the affected packages and, if the updates
+require it, reboots the machine; containers on the machine are shut down
+first. No more than --max-unavailable machines are upgrading or rebooting at
+once, and machines are only started while the environment's maintenance
+window is open and the environment is not suspended.
+
+Only one patch run may be in progress at a time. Use "juju machine
+patch-status" to follow its progress.
+
+Examples:
+	# Patch machines one at a time
+	$ juju machine patch
+
+	# Patch up to three machines at a time
+	$ juju machine patch --max-unavailable 3
+`
+
+func (c *PatchCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "patch",
+		Purpose: "apply pending security updates to machines",
+		Doc:     patchDoc,
+	}
+}
+
+func (c *PatchCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.IntVar(&c.MaxUnavailable, "max-unavailable", 1, "the number of machines that may be patched at once")
+}
+
+func (c *PatchCommand) Init(args []string) error {
+	if c.MaxUnavailable < 1 {
+		return errors.Errorf("--max-unavailable must be at least 1")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *PatchCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	run, err := client.StartPatchRun(c.MaxUnavailable)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("started patch run %d on %d machines", run.Id, len(run.Machines))
+	return nil
+}
+
+// PatchStatusCommand shows the progress of a patch run.
+type PatchStatusCommand struct {
+	osUpdatesCommandBase
+	out   cmd.Output
+	RunId int
+}
+
+const patchStatusDoc = `
+The patch-status command shows the progress of a patch run started with
+"juju machine patch": the status of the run, and of each machine in it. By
+default, the most recent run is shown.
+
+Examples:
+	# Show the progress of the most recent patch run
+	$ juju machine patch-status
+
+	# Show the outcome of patch run 2
+	$ juju machine patch-status 2
+`
+
+// PatchRunStatus defines the serialization of a patch run.
+type PatchRunStatus struct {
+	Id             int                           `yaml:"id" json:"id"`
+	Status         string                        `yaml:"status" json:"status"`
+	MaxUnavailable int                           `yaml:"max-unavailable" json:"max-unavailable"`
+	Started        string                        `yaml:"started" json:"started"`
+	Finished       string                        `yaml:"finished,omitempty" json:"finished,omitempty"`
+	Machines       map[string]MachinePatchStatus `yaml:"machines" json:"machines"`
+}
+
+// MachinePatchStatus defines the serialization of the progress of a
+// machine in a patch run.
+type MachinePatchStatus struct {
+	Status string `yaml:"status" json:"status"`
+	Info   string `yaml:"info,omitempty" json:"info,omitempty"`
+}
+
+func (c *PatchStatusCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "patch-status",
+		Args:    "[<run>]",
+		Purpose: "show the progress of a patch run",
+		Doc:     patchStatusDoc,
+	}
+}
+
+func (c *PatchStatusCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *PatchStatusCommand) Init(args []string) error {
+	if len(args) > 0 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id < 1 {
+			return errors.Errorf("invalid patch run %q", args[0])
+		}
+		c.RunId = id
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *PatchStatusCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	runs, err := client.PatchRuns()
+	if err != nil {
+		return err
+	}
+	var found *params.PatchRun
+	for i, run := range runs {
+		if c.RunId == 0 || run.Id == c.RunId {
+			found = &runs[i]
+			break
+		}
+	}
+	if found == nil {
+		if c.RunId == 0 {
+			fmt.Fprintf(ctx.Stderr, "no patch runs\n")
+			return nil
+		}
+		return errors.NotFoundf("patch run %d", c.RunId)
+	}
+	return c.out.Write(ctx, formatPatchRun(*found))
+}
+
+func formatPatchRun(run params.PatchRun) PatchRunStatus {
+	result := PatchRunStatus{
+		Id:             run.Id,
+		Status:         run.Status,
+		MaxUnavailable: run.MaxUnavailable,
+		Started:        run.Started.Format(time.RFC1123),
+		Machines:       make(map[string]MachinePatchStatus),
+	}
+	if run.Finished != nil {
+		result.Finished = run.Finished.Format(time.RFC1123)
+	}
+	for _, machine := range run.Machines {
+		id := machine.Tag
+		if tag, err := names.ParseMachineTag(machine.Tag); err == nil {
+			id = tag.Id()
+		}
+		result.Machines[id] = MachinePatchStatus{
+			Status: machine.Status,
+			Info:   machine.Info,
+		}
+	}
+	return result
+}
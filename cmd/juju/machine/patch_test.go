@@ -0,0 +1,225 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/testing"
+)
+
+type PatchSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeOSUpdatesAPI
+}
+
+var _ = gc.Suite(&PatchSuite{})
+
+var (
+	patchStarted  = time.Date(2015, 6, 2, 2, 0, 0, 0, time.UTC)
+	patchFinished = time.Date(2015, 6, 2, 3, 0, 0, 0, time.UTC)
+)
+
+func (s *PatchSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeOSUpdatesAPI{
+		updates: []params.MachineOSUpdates{{
+			MachineId:       "0",
+			SecurityUpdates: []string{"libc6", "openssl"},
+			Checked:         patchStarted,
+		}, {
+			MachineId: "1",
+			Checked:   patchStarted,
+			PatchRun:  2,
+		}},
+		runs: []params.PatchRun{{
+			Id:             2,
+			MaxUnavailable: 1,
+			Status:         "running",
+			Started:        patchStarted,
+			Machines: []params.MachinePatchStatus{
+				{Tag: "machine-0", Status: "pending"},
+				{Tag: "machine-1", Status: "rebooting"},
+			},
+		}, {
+			Id:             1,
+			MaxUnavailable: 2,
+			Status:         "failed",
+			Started:        patchStarted,
+			Finished:       &patchFinished,
+			Machines: []params.MachinePatchStatus{
+				{Tag: "machine-0", Status: "failed", Info: "dpkg was interrupted"},
+			},
+		}},
+	}
+}
+
+func (s *PatchSuite) TestSecurityUpdates(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewSecurityUpdatesCommand(s.fake)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+"0":
+  packages:
+  - libc6
+  - openssl
+  checked: Tue, 02 Jun 2015 02:00:00 UTC
+"1":
+  checked: Tue, 02 Jun 2015 02:00:00 UTC
+  patch-run: 2
+`[1:])
+}
+
+func (s *PatchSuite) TestSecurityUpdatesNone(c *gc.C) {
+	s.fake.updates = nil
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewSecurityUpdatesCommand(s.fake)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "no machines have reported security updates\n")
+}
+
+func (s *PatchSuite) TestPatchInit(c *gc.C) {
+	for i, test := range []struct {
+		args           []string
+		maxUnavailable int
+		errorString    string
+	}{{
+		maxUnavailable: 1,
+	}, {
+		args:           []string{"--max-unavailable", "3"},
+		maxUnavailable: 3,
+	}, {
+		args:        []string{"--max-unavailable", "0"},
+		errorString: "--max-unavailable must be at least 1",
+	}, {
+		args:        []string{"1"},
+		errorString: `unrecognized args: \["1"\]`,
+	}} {
+		c.Logf("test %d", i)
+		patchCmd := &machine.PatchCommand{}
+		err := testing.InitCommand(patchCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(patchCmd.MaxUnavailable, gc.Equals, test.maxUnavailable)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *PatchSuite) TestPatch(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchCommand(s.fake)), "--max-unavailable", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.maxUnavailable, gc.Equals, 2)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "started patch run 2 on 2 machines\n")
+}
+
+func (s *PatchSuite) TestPatchError(c *gc.C) {
+	s.fake.err = errors.New("cannot start patch run: patch run 2 is already in progress")
+	_, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchCommand(s.fake)))
+	c.Assert(err, gc.ErrorMatches, "cannot start patch run: patch run 2 is already in progress")
+}
+
+func (s *PatchSuite) TestPatchStatusInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		runId       int
+		errorString string
+	}{{}, {
+		args:  []string{"2"},
+		runId: 2,
+	}, {
+		args:        []string{"two"},
+		errorString: `invalid patch run "two"`,
+	}, {
+		args:        []string{"1", "2"},
+		errorString: `unrecognized args: \["2"\]`,
+	}} {
+		c.Logf("test %d", i)
+		statusCmd := &machine.PatchStatusCommand{}
+		err := testing.InitCommand(statusCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(statusCmd.RunId, gc.Equals, test.runId)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *PatchSuite) TestPatchStatusLatest(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchStatusCommand(s.fake)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+id: 2
+status: running
+max-unavailable: 1
+started: Tue, 02 Jun 2015 02:00:00 UTC
+machines:
+  "0":
+    status: pending
+  "1":
+    status: rebooting
+`[1:])
+}
+
+func (s *PatchSuite) TestPatchStatusRun(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchStatusCommand(s.fake)), "1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+id: 1
+status: failed
+max-unavailable: 2
+started: Tue, 02 Jun 2015 02:00:00 UTC
+finished: Tue, 02 Jun 2015 03:00:00 UTC
+machines:
+  "0":
+    status: failed
+    info: dpkg was interrupted
+`[1:])
+}
+
+func (s *PatchSuite) TestPatchStatusNotFound(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchStatusCommand(s.fake)), "3")
+	c.Assert(err, gc.ErrorMatches, "patch run 3 not found")
+}
+
+func (s *PatchSuite) TestPatchStatusNoRuns(c *gc.C) {
+	s.fake.runs = nil
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(machine.NewPatchStatusCommand(s.fake)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "no patch runs\n")
+}
+
+type fakeOSUpdatesAPI struct {
+	updates        []params.MachineOSUpdates
+	runs           []params.PatchRun
+	maxUnavailable int
+	err            error
+}
+
+func (f *fakeOSUpdatesAPI) Close() error {
+	return nil
+}
+
+func (f *fakeOSUpdatesAPI) SecurityUpdates() ([]params.MachineOSUpdates, error) {
+	return f.updates, f.err
+}
+
+func (f *fakeOSUpdatesAPI) StartPatchRun(maxUnavailable int) (params.PatchRun, error) {
+	f.maxUnavailable = maxUnavailable
+	if f.err != nil {
+		return params.PatchRun{}, f.err
+	}
+	return f.runs[0], nil
+}
+
+func (f *fakeOSUpdatesAPI) PatchRuns() ([]params.PatchRun, error) {
+	return f.runs, f.err
+}
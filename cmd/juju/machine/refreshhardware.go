@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+// RefreshHardwareCommand asks the agents of existing machines to
+// re-detect their hardware characteristics.
+type RefreshHardwareCommand struct {
+	envcmd.EnvCommandBase
+	api      RefreshHardwareAPI
+	Machines []names.MachineTag
+}
+
+const refreshHardwareDoc = `
+The hardware characteristics of a machine (cpu cores, memory, root disk,
+NUMA nodes and GPUs) are recorded when the machine is provisioned, as
+reported by the provider. The refresh-hardware command asks the agents of
+the given machines to detect their hardware themselves and record the
+results, which are then used when matching machines against constraints.
+This is useful when the hardware has changed since the machine was
+provisioned, or the provider could not report it accurately.
+
+Examples:
+	# Re-detect the hardware of machines 3 and 4
+	$ juju machine refresh-hardware 3 4
+`
+
+func (c *RefreshHardwareCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "refresh-hardware",
+		Args:    "<machine> ...",
+		Purpose: "re-detect the hardware characteristics of machines",
+		Doc:     refreshHardwareDoc,
+	}
+}
+
+func (c *RefreshHardwareCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no machines specified")
+	}
+	c.Machines = make([]names.MachineTag, len(args))
+	for i, id := range args {
+		if !names.IsValidMachine(id) {
+			return fmt.Errorf("invalid machine id %q", id)
+		}
+		c.Machines[i] = names.NewMachineTag(id)
+	}
+	return nil
+}
+
+type RefreshHardwareAPI interface {
+	RefreshMachineHardware(machines ...names.MachineTag) ([]params.ErrorResult, error)
+	Close() error
+}
+
+func (c *RefreshHardwareCommand) getRefreshHardwareAPI() (RefreshHardwareAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	return c.NewAPIClient()
+}
+
+func (c *RefreshHardwareCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getRefreshHardwareAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	results, err := client.RefreshMachineHardware(c.Machines...)
+	if err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	errs := 0
+	for i, result := range results {
+		if result.Error != nil {
+			fmt.Fprintf(ctx.Stderr, "cannot refresh hardware of machine %s: %v\n", c.Machines[i].Id(), result.Error)
+			errs++
+		}
+	}
+	if errs > 0 {
+		return cmd.ErrSilent
+	}
+	return nil
+}
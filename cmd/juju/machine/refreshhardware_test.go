@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machine_test
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/machine"
+	"github.com/juju/juju/testing"
+)
+
+type RefreshHardwareSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeRefreshHardwareAPI
+}
+
+var _ = gc.Suite(&RefreshHardwareSuite{})
+
+func (s *RefreshHardwareSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeRefreshHardwareAPI{}
+}
+
+func (s *RefreshHardwareSuite) run(c *gc.C, args ...string) (*cmd.Context, error) {
+	refresh := machine.NewRefreshHardwareCommand(s.fake)
+	return testing.RunCommand(c, envcmd.Wrap(refresh), args...)
+}
+
+func (s *RefreshHardwareSuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		machines    []names.MachineTag
+		errorString string
+	}{{
+		errorString: "no machines specified",
+	}, {
+		args:     []string{"1", "2/lxc/1"},
+		machines: []names.MachineTag{names.NewMachineTag("1"), names.NewMachineTag("2/lxc/1")},
+	}, {
+		args:        []string{"lxc"},
+		errorString: `invalid machine id "lxc"`,
+	}} {
+		c.Logf("test %d", i)
+		refreshCmd := &machine.RefreshHardwareCommand{}
+		err := testing.InitCommand(refreshCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(refreshCmd.Machines, jc.DeepEquals, test.machines)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *RefreshHardwareSuite) TestRefresh(c *gc.C) {
+	_, err := s.run(c, "1", "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.machines, jc.DeepEquals, []names.MachineTag{
+		names.NewMachineTag("1"), names.NewMachineTag("2"),
+	})
+}
+
+func (s *RefreshHardwareSuite) TestRefreshErrors(c *gc.C) {
+	s.fake.results = []params.ErrorResult{
+		{},
+		{Error: &params.Error{Message: "machine 2 not provisioned"}},
+	}
+	ctx, err := s.run(c, "1", "2")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	c.Assert(testing.Stderr(ctx), gc.Equals, "cannot refresh hardware of machine 2: machine 2 not provisioned\n")
+}
+
+func (s *RefreshHardwareSuite) TestBlockedError(c *gc.C) {
+	s.fake.err = common.ErrOperationBlocked("TestBlockedError")
+	_, err := s.run(c, "1")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	// msg is logged
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Assert(stripped, gc.Matches, ".*TestBlockedError.*")
+}
+
+type fakeRefreshHardwareAPI struct {
+	machines []names.MachineTag
+	results  []params.ErrorResult
+	err      error
+}
+
+func (f *fakeRefreshHardwareAPI) Close() error {
+	return nil
+}
+
+func (f *fakeRefreshHardwareAPI) RefreshMachineHardware(machines ...names.MachineTag) ([]params.ErrorResult, error) {
+	f.machines = machines
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.results == nil {
+		return make([]params.ErrorResult, len(machines)), nil
+	}
+	return f.results, nil
+}
@@ -20,13 +20,19 @@ type RemoveCommand struct {
 	api        RemoveMachineAPI
 	MachineIds []string
 	Force      bool
+	Evacuate   bool
 }
 
 const destroyMachineDoc = `
 Machines that are responsible for the environment cannot be removed. Machines
-running units or containers can only be removed with the --force flag; doing
-so will also remove all those units and containers without giving them any
-opportunity to shut down cleanly.
+running units or containers, or holding storage that has not been released,
+can only be removed with the --force flag; doing so will also remove all those
+units and containers without giving them any opportunity to shut down cleanly.
+
+Machines running units can instead be removed with the --evacuate flag. Each
+unit on the machine is replaced by a new unit of the same service, placed as
+a newly added unit would be; once the replacements are running, the units on
+the machine are removed, followed by the machine itself.
 
 Examples:
 	# Remove machine number 5 which has no running units or containers
@@ -34,6 +40,9 @@ Examples:
 
 	# Remove machine 6 and any running units or containers
 	$ juju machine remove 6 --force
+
+	# Move the units on machine 7 elsewhere, then remove it
+	$ juju machine remove 7 --evacuate
 `
 
 func (c *RemoveCommand) Info() *cmd.Info {
@@ -47,12 +56,16 @@ func (c *RemoveCommand) Info() *cmd.Info {
 
 func (c *RemoveCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Force, "force", false, "completely remove machine and all dependencies")
+	f.BoolVar(&c.Evacuate, "evacuate", false, "replace the machine's units elsewhere before removing it")
 }
 
 func (c *RemoveCommand) Init(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("no machines specified")
 	}
+	if c.Force && c.Evacuate {
+		return fmt.Errorf("--force and --evacuate cannot be used together")
+	}
 	for _, id := range args {
 		if !names.IsValidMachine(id) {
 			return fmt.Errorf("invalid machine id %q", id)
@@ -65,6 +78,7 @@ func (c *RemoveCommand) Init(args []string) error {
 type RemoveMachineAPI interface {
 	DestroyMachines(machines ...string) error
 	ForceDestroyMachines(machines ...string) error
+	EvacuateMachines(machines ...string) error
 	Close() error
 }
 
@@ -81,9 +95,12 @@ func (c *RemoveCommand) Run(_ *cmd.Context) error {
 		return err
 	}
 	defer client.Close()
-	if c.Force {
+	switch {
+	case c.Force:
 		err = client.ForceDestroyMachines(c.MachineIds...)
-	} else {
+	case c.Evacuate:
+		err = client.EvacuateMachines(c.MachineIds...)
+	default:
 		err = client.DestroyMachines(c.MachineIds...)
 	}
 	return block.ProcessBlockedError(err, block.BlockRemove)
@@ -38,6 +38,7 @@ func (s *RemoveMachineSuite) TestInit(c *gc.C) {
 		args        []string
 		machines    []string
 		force       bool
+		evacuate    bool
 		errorString string
 	}{
 		{
@@ -56,6 +57,13 @@ func (s *RemoveMachineSuite) TestInit(c *gc.C) {
 			args:     []string{"--force", "1", "2"},
 			machines: []string{"1", "2"},
 			force:    true,
+		}, {
+			args:     []string{"--evacuate", "1"},
+			machines: []string{"1"},
+			evacuate: true,
+		}, {
+			args:        []string{"--evacuate", "--force", "1"},
+			errorString: "--force and --evacuate cannot be used together",
 		}, {
 			args:        []string{"lxc"},
 			errorString: `invalid machine id "lxc"`,
@@ -70,6 +78,7 @@ func (s *RemoveMachineSuite) TestInit(c *gc.C) {
 		if test.errorString == "" {
 			c.Check(err, jc.ErrorIsNil)
 			c.Check(removeCmd.Force, gc.Equals, test.force)
+			c.Check(removeCmd.Evacuate, gc.Equals, test.evacuate)
 			c.Check(removeCmd.MachineIds, jc.DeepEquals, test.machines)
 		} else {
 			c.Check(err, gc.ErrorMatches, test.errorString)
@@ -91,6 +100,14 @@ func (s *RemoveMachineSuite) TestRemoveForce(c *gc.C) {
 	c.Assert(s.fake.machines, jc.DeepEquals, []string{"1", "2/lxc/1"})
 }
 
+func (s *RemoveMachineSuite) TestRemoveEvacuate(c *gc.C) {
+	_, err := s.run(c, "--evacuate", "1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.forced, jc.IsFalse)
+	c.Assert(s.fake.evacuated, jc.IsTrue)
+	c.Assert(s.fake.machines, jc.DeepEquals, []string{"1"})
+}
+
 func (s *RemoveMachineSuite) TestBlockedError(c *gc.C) {
 	s.fake.removeError = common.ErrOperationBlocked("TestBlockedError")
 	_, err := s.run(c, "1")
@@ -113,6 +130,7 @@ func (s *RemoveMachineSuite) TestForceBlockedError(c *gc.C) {
 
 type fakeRemoveMachineAPI struct {
 	forced      bool
+	evacuated   bool
 	machines    []string
 	removeError error
 }
@@ -132,3 +150,9 @@ func (f *fakeRemoveMachineAPI) ForceDestroyMachines(machines ...string) error {
 	f.machines = machines
 	return f.removeError
 }
+
+func (f *fakeRemoveMachineAPI) EvacuateMachines(machines ...string) error {
+	f.evacuated = true
+	f.machines = machines
+	return f.removeError
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/juju/juju/cmd/juju/environment"
 	"github.com/juju/juju/cmd/juju/machine"
 	"github.com/juju/juju/cmd/juju/storage"
+	"github.com/juju/juju/cmd/juju/upgradeseries"
 	"github.com/juju/juju/cmd/juju/user"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/feature"
@@ -128,15 +129,21 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 
 	// Reporting commands.
 	r.Register(wrapEnvCommand(&StatusCommand{}))
+	r.Register(wrapEnvCommand(&ShowStatusLogCommand{}))
+	r.Register(wrapEnvCommand(&ShowUnitCommand{}))
+	r.Register(wrapEnvCommand(&ShowServiceCommand{}))
 	r.Register(&SwitchCommand{})
 	r.Register(wrapEnvCommand(&EndpointCommand{}))
 	r.Register(wrapEnvCommand(&APIInfoCommand{}))
+	r.Register(wrapEnvCommand(&WaitForCommand{}))
 
 	// Error resolution and debugging commands.
 	r.Register(wrapEnvCommand(&RunCommand{}))
 	r.Register(wrapEnvCommand(&SCPCommand{}))
 	r.Register(wrapEnvCommand(&SSHCommand{}))
 	r.Register(wrapEnvCommand(&ResolvedCommand{}))
+	r.Register(wrapEnvCommand(&DropHookCommand{}))
+	r.Register(wrapEnvCommand(&HookOutputCommand{}))
 	r.Register(wrapEnvCommand(&DebugLogCommand{}))
 	r.Register(wrapEnvCommand(&DebugHooksCommand{}))
 
@@ -166,6 +173,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 
 	// Manage authorized ssh keys.
 	r.Register(NewAuthorizedKeysCommand())
+	r.RegisterSuperAlias("import-ssh-key", "authorized-keys", "import", nil)
 
 	// Manage users and access
 	r.Register(user.NewSuperCommand())
@@ -180,6 +188,9 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.RegisterSuperAlias("destroy-machine", "machine", "remove", twoDotOhDeprecation("machine remove"))
 	r.RegisterSuperAlias("terminate-machine", "machine", "remove", twoDotOhDeprecation("machine remove"))
 
+	// Upgrade the series of machines
+	r.Register(upgradeseries.NewSuperCommand())
+
 	// Mangage environment
 	r.Register(environment.NewSuperCommand())
 	r.RegisterSuperAlias("get-environment", "environment", "get", twoDotOhDeprecation("environment get"))
@@ -189,12 +200,20 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.RegisterSuperAlias("unset-environment", "environment", "unset", twoDotOhDeprecation("environment unset"))
 	r.RegisterSuperAlias("unset-env", "environment", "unset", twoDotOhDeprecation("environment unset"))
 	r.RegisterSuperAlias("retry-provisioning", "environment", "retry-provisioning", twoDotOhDeprecation("environment retry-provisioning"))
+	r.RegisterSuperAlias("suspend-environment", "environment", "suspend", nil)
+	r.RegisterSuperAlias("resume-environment", "environment", "resume", nil)
+	if featureflag.Enabled(feature.JES) {
+		r.RegisterSuperAlias("update-credential", "environment", "update-credential", nil)
+	}
 
 	// Manage and control actions
 	r.Register(action.NewSuperCommand())
 
 	// Manage state server availability
 	r.Register(wrapEnvCommand(&EnsureAvailabilityCommand{}))
+	r.Register(wrapEnvCommand(&ShowControllerCommand{}))
+	r.Register(wrapEnvCommand(&ListConnectionsCommand{}))
+	r.Register(wrapEnvCommand(&ListSubnetsCommand{}))
 
 	// Operation protection commands
 	r.Register(block.NewSuperBlockCommand())
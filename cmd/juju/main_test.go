@@ -154,7 +154,7 @@ func (s *MainSuite) TestRunMain(c *gc.C) {
 		summary: "check unblock command registered properly",
 		args:    []string{"unblock"},
 		code:    0,
-		out:     "error: must specify one of [destroy-environment | remove-object | all-changes] to unblock\n",
+		out:     "error: must specify one of [destroy-environment | remove-object | all-changes | budget-affecting | machine-reboot] to unblock\n",
 	},
 	} {
 		c.Logf("test %d: %s", i, t.summary)
@@ -208,6 +208,7 @@ var commandNames = []string{
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"drop-hook",
 	"ensure-availability",
 	"env", // alias for switch
 	"environment",
@@ -219,7 +220,11 @@ var commandNames = []string{
 	"get-environment",
 	"help",
 	"help-tool",
+	"hook-output",
+	"import-ssh-key",
 	"init",
+	"list-connections",
+	"list-subnets",
 	"machine",
 	"publish",
 	"remove-machine",  // alias for destroy-machine
@@ -227,6 +232,7 @@ var commandNames = []string{
 	"remove-service",  // alias for destroy-service
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
+	"resume-environment",
 	"retry-provisioning",
 	"run",
 	"scp",
@@ -234,10 +240,15 @@ var commandNames = []string{
 	"set-constraints",
 	"set-env", // alias for set-environment
 	"set-environment",
+	"show-controller",
+	"show-service",
+	"show-status-log",
+	"show-unit",
 	"ssh",
 	"stat", // alias for status
 	"status",
 	"storage",
+	"suspend-environment",
 	"switch",
 	"sync-tools",
 	"terminate-machine", // alias for destroy-machine
@@ -248,8 +259,10 @@ var commandNames = []string{
 	"unset-environment",
 	"upgrade-charm",
 	"upgrade-juju",
+	"upgrade-series",
 	"user",
 	"version",
+	"wait-for",
 }
 
 func (s *MainSuite) TestHelpCommands(c *gc.C) {
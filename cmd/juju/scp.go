@@ -108,5 +108,10 @@ func (c *SCPCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	cleanup, err := c.setKnownHosts(options)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 	return ssh.Copy(args, options)
 }
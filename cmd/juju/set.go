@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"unicode/utf8"
 
@@ -15,6 +16,8 @@ import (
 	"github.com/juju/utils/keyvalues"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api/service"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 )
@@ -38,6 +41,10 @@ size of this value is 5M.
 
 Option values may be any UTF-8 encoded string. UTF-8 is accepted on the command
 line and in configuration files.
+
+Charms may restrict the values an option takes to a list of choices, a numeric
+range or a pattern. Values that break those rules are reported individually,
+and no options are set.
 `
 
 const maxValueSize = 5242880
@@ -73,11 +80,12 @@ func (c *SetCommand) Init(args []string) error {
 
 // Run updates the configuration of a service.
 func (c *SetCommand) Run(ctx *cmd.Context) error {
-	api, err := c.NewAPIClient()
+	root, err := c.NewAPIRoot()
 	if err != nil {
 		return err
 	}
-	defer api.Close()
+	defer root.Close()
+	api := root.Client()
 
 	if c.SettingsYAML.Path != "" {
 		b, err := c.SettingsYAML.Read(ctx)
@@ -130,7 +138,24 @@ func (c *SetCommand) Run(ctx *cmd.Context) error {
 		}
 	}
 
-	return block.ProcessBlockedError(api.ServiceSet(c.ServiceName, settings), block.BlockChange)
+	err = service.NewClient(root).SetConfig(c.ServiceName, settings)
+	if params.IsCodeNotImplemented(err) {
+		// Older servers do not validate settings against the
+		// charm's config rules.
+		err = api.ServiceSet(c.ServiceName, settings)
+	}
+	if err, ok := err.(*service.ConfigValidationError); ok {
+		names := make([]string, 0, len(err.Errors))
+		for name := range err.Errors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(ctx.Stderr, "%s: %s\n", name, err.Errors[name])
+		}
+		return errors.New("invalid config settings")
+	}
+	return block.ProcessBlockedError(err, block.BlockChange)
 }
 
 // readValue reads the value of an option out of the named file.
@@ -113,6 +113,23 @@ func (s *SetSuite) TestSetOptionFail(c *gc.C) {
 	}, "error: value for option \"username\" contains non-UTF-8 sequences\n")
 }
 
+func (s *SetSuite) TestSetOptionInvalid(c *gc.C) {
+	ch := s.AddTestingCharm(c, "config-rules")
+	svc := s.AddTestingService(c, "config-rules", ch)
+	ctx := coretesting.ContextForDir(c, s.dir)
+	code := cmd.Main(envcmd.Wrap(&SetCommand{}), ctx, []string{
+		"config-rules", "flavour=strawberry", "port=0", "title=ignored",
+	})
+	c.Check(code, gc.Equals, 1)
+	c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, ""+
+		"flavour: must be one of [vanilla chocolate]\n"+
+		"port: must be at least 1\n"+
+		"error: invalid config settings\n")
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, gc.HasLen, 0)
+}
+
 func (s *SetSuite) TestSetConfig(c *gc.C) {
 	assertSetFail(c, s.dir, []string{
 		"--config",
@@ -0,0 +1,157 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/highavailability"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// ShowControllerCommand shows the health of the state servers.
+type ShowControllerCommand struct {
+	envcmd.EnvCommandBase
+	out    cmd.Output
+	client ShowControllerClient
+}
+
+const showControllerDoc = `
+Show the health of the state servers that run the environment.
+
+For each state server machine, the output shows whether its agent is
+running, its vote in the mongo replica set and the state of its mongo
+database. The environment is healthy when every state server which
+should have a vote is available. Voting state servers which stay
+unavailable are replaced automatically.
+
+The output also shows, for each environment, which state server holds
+the lease to run the environment's singular workers, such as the
+instance poller and firewaller, and when that lease expires unless it
+is extended.
+
+Examples:
+ juju show-controller
+ juju show-controller --format json
+`
+
+func (c *ShowControllerCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-controller",
+		Purpose: "show the health of the state servers",
+		Doc:     showControllerDoc,
+	}
+}
+
+func (c *ShowControllerCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *ShowControllerCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// ShowControllerClient defines the methods on the high availability
+// API that the show-controller command calls.
+type ShowControllerClient interface {
+	Close() error
+	StateServersHealth() (params.StateServersHealthResult, error)
+	SingularWorkers() (params.SingularWorkersResult, error)
+}
+
+func (c *ShowControllerCommand) getClient() (ShowControllerClient, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return highavailability.NewClient(root), nil
+}
+
+type controllerHealth struct {
+	Healthy         bool                             `json:"healthy" yaml:"healthy"`
+	Voters          int                              `json:"voters" yaml:"voters"`
+	AvailableVoters int                              `json:"available-voters" yaml:"available-voters"`
+	StateServers    map[string]stateServerHealthInfo `json:"state-servers" yaml:"state-servers"`
+	SingularWorkers map[string]singularWorkersInfo   `json:"singular-workers,omitempty" yaml:"singular-workers,omitempty"`
+}
+
+type stateServerHealthInfo struct {
+	Available  bool   `json:"available" yaml:"available"`
+	AgentAlive bool   `json:"agent-alive" yaml:"agent-alive"`
+	Vote       string `json:"vote" yaml:"vote"`
+	Mongo      string `json:"mongo,omitempty" yaml:"mongo,omitempty"`
+}
+
+type singularWorkersInfo struct {
+	StateServer string   `json:"state-server,omitempty" yaml:"state-server,omitempty"`
+	Expiry      string   `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+	Workers     []string `json:"workers" yaml:"workers"`
+}
+
+// Run connects to the environment specified on the command line and
+// shows the health of its state servers.
+func (c *ShowControllerCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	health, err := client.StateServersHealth()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	result := controllerHealth{
+		Healthy:         health.Healthy,
+		Voters:          health.Voters,
+		AvailableVoters: health.AvailableVoters,
+		StateServers:    make(map[string]stateServerHealthInfo),
+	}
+	for _, server := range health.StateServers {
+		ids := machineTagsToIds(server.MachineTag)
+		if len(ids) == 0 {
+			continue
+		}
+		mongo := server.MongoState
+		if mongo != "" && !server.MongoHealthy {
+			mongo += " (unreachable)"
+		}
+		result.StateServers[ids[0]] = stateServerHealthInfo{
+			Available:  server.Available,
+			AgentAlive: server.AgentAlive,
+			Vote:       makeHAStatus(server.HasVote, server.WantsVote),
+			Mongo:      mongo,
+		}
+	}
+	singularWorkers, err := client.SingularWorkers()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, env := range singularWorkers.Environments {
+		if result.SingularWorkers == nil {
+			result.SingularWorkers = make(map[string]singularWorkersInfo)
+		}
+		info := singularWorkersInfo{
+			Workers: env.Workers,
+		}
+		if env.Expiry != nil {
+			info.Expiry = env.Expiry.Format(time.RFC3339)
+		}
+		if ids := machineTagsToIds(env.HolderTag); len(ids) > 0 {
+			info.StateServer = ids[0]
+		}
+		result.SingularWorkers[env.EnvironName] = info
+	}
+	return c.out.Write(ctx, result)
+}
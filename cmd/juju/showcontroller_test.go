@@ -0,0 +1,163 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ShowControllerSuite struct {
+	coretesting.FakeJujuHomeSuite
+	fake *fakeShowControllerClient
+}
+
+var _ = gc.Suite(&ShowControllerSuite{})
+
+func (s *ShowControllerSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeShowControllerClient{}
+}
+
+type fakeShowControllerClient struct {
+	result   params.StateServersHealthResult
+	singular params.SingularWorkersResult
+	err      error
+}
+
+func (f *fakeShowControllerClient) Close() error {
+	return nil
+}
+
+func (f *fakeShowControllerClient) StateServersHealth() (params.StateServersHealthResult, error) {
+	return f.result, f.err
+}
+
+func (f *fakeShowControllerClient) SingularWorkers() (params.SingularWorkersResult, error) {
+	return f.singular, f.err
+}
+
+func (s *ShowControllerSuite) runShowController(c *gc.C, args ...string) (*cmd.Context, error) {
+	command := &ShowControllerCommand{client: s.fake}
+	return coretesting.RunCommand(c, envcmd.Wrap(command), args...)
+}
+
+func (s *ShowControllerSuite) TestShowController(c *gc.C) {
+	s.fake.result = params.StateServersHealthResult{
+		StateServers: []params.StateServerHealth{{
+			MachineTag:   "machine-0",
+			AgentAlive:   true,
+			WantsVote:    true,
+			HasVote:      true,
+			MongoState:   "PRIMARY",
+			MongoHealthy: true,
+			Available:    true,
+		}, {
+			MachineTag: "machine-1",
+			WantsVote:  true,
+			HasVote:    true,
+			MongoState: "DOWN",
+		}, {
+			MachineTag: "machine-2",
+			AgentAlive: true,
+			WantsVote:  true,
+			Available:  true,
+		}},
+		Voters:          3,
+		AvailableVoters: 2,
+	}
+	ctx, err := s.runShowController(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+healthy: false
+voters: 3
+available-voters: 2
+state-servers:
+  "0":
+    available: true
+    agent-alive: true
+    vote: has-vote
+    mongo: PRIMARY
+  "1":
+    available: false
+    agent-alive: false
+    vote: has-vote
+    mongo: DOWN (unreachable)
+  "2":
+    available: true
+    agent-alive: true
+    vote: adding-vote
+`[1:])
+}
+
+func (s *ShowControllerSuite) TestShowControllerSingularWorkers(c *gc.C) {
+	expiry := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.fake.result = params.StateServersHealthResult{
+		StateServers: []params.StateServerHealth{{
+			MachineTag: "machine-0",
+			AgentAlive: true,
+			WantsVote:  true,
+			HasVote:    true,
+			Available:  true,
+		}},
+		Voters:          1,
+		AvailableVoters: 1,
+		Healthy:         true,
+	}
+	s.fake.singular = params.SingularWorkersResult{
+		Environments: []params.SingularWorkers{{
+			EnvironTag:  "environment-deadbeef-0bad-400d-8000-4b1d0d06f00d",
+			EnvironName: "admin",
+			HolderTag:   "machine-0",
+			Expiry:      &expiry,
+			Workers:     []string{"firewaller", "instancepoller"},
+		}, {
+			EnvironTag:  "environment-deadbeef-0bad-400d-8000-5b1d0d06f00d",
+			EnvironName: "hosted",
+			Workers:     []string{"firewaller", "instancepoller"},
+		}},
+	}
+	ctx, err := s.runShowController(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+healthy: true
+voters: 1
+available-voters: 1
+state-servers:
+  "0":
+    available: true
+    agent-alive: true
+    vote: has-vote
+singular-workers:
+  admin:
+    state-server: "0"
+    expiry: 2015-06-01T12:00:00Z
+    workers:
+    - firewaller
+    - instancepoller
+  hosted:
+    workers:
+    - firewaller
+    - instancepoller
+`[1:])
+}
+
+func (s *ShowControllerSuite) TestShowControllerError(c *gc.C) {
+	s.fake.err = errors.New("boom")
+	_, err := s.runShowController(c)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *ShowControllerSuite) TestShowControllerRejectsArgs(c *gc.C) {
+	_, err := s.runShowController(c, "foo")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["foo"\]`)
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/service"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const showServiceDoc = `
+Show a service's leader unit, and whether its leadership has been
+pinned to that unit by a maintenance operation, such as a series
+upgrade or a backup. While the leadership is pinned, it will not
+pass to another unit even if the leader's agent stops. Pins lapse
+automatically when they expire.
+
+Examples:
+
+    juju show-service mysql
+    juju show-service mysql --format yaml
+`
+
+// ShowServiceCommand shows the leadership of a service.
+type ShowServiceCommand struct {
+	envcmd.EnvCommandBase
+	out         cmd.Output
+	serviceName string
+}
+
+// Info implements Command.Info.
+func (c *ShowServiceCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-service",
+		Args:    "<service>",
+		Purpose: "show the leadership of a service",
+		Doc:     showServiceDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ShowServiceCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "summary", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"summary": formatServiceLeadershipSummary,
+	})
+}
+
+// Init implements Command.Init.
+func (c *ShowServiceCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no service specified")
+	}
+	c.serviceName, args = args[0], args[1:]
+	if !names.IsValidService(c.serviceName) {
+		return errors.Errorf("invalid service name %q", c.serviceName)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// ShowServiceAPI defines the API methods used by the show-service
+// command.
+type ShowServiceAPI interface {
+	Close() error
+	Leadership(service string) (params.ServiceLeadershipResult, error)
+}
+
+var getShowServiceAPI = func(c *ShowServiceCommand) (ShowServiceAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, err
+	}
+	return service.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *ShowServiceCommand) Run(ctx *cmd.Context) error {
+	api, err := getShowServiceAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	leadership, err := api.Leadership(c.serviceName)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatServiceLeadership(c.serviceName, leadership))
+}
+
+// serviceLeadership defines the serialization behaviour of a
+// service's leadership.
+type serviceLeadership struct {
+	Service string          `yaml:"service" json:"service"`
+	Leader  string          `yaml:"leader,omitempty" json:"leader,omitempty"`
+	Pins    []leadershipPin `yaml:"pins,omitempty" json:"pins,omitempty"`
+}
+
+// leadershipPin defines the serialization behaviour of a pin on a
+// service's leadership.
+type leadershipPin struct {
+	PinnedBy string `yaml:"pinned-by" json:"pinned-by"`
+	Until    string `yaml:"until" json:"until"`
+}
+
+func formatServiceLeadership(serviceName string, result params.ServiceLeadershipResult) serviceLeadership {
+	leadership := serviceLeadership{Service: serviceName}
+	if tag, err := names.ParseUnitTag(result.Leader); err == nil {
+		leadership.Leader = tag.Id()
+	}
+	for _, pin := range result.Pins {
+		pinnedBy := pin.Entity
+		if tag, err := names.ParseTag(pin.Entity); err == nil {
+			pinnedBy = tag.Id()
+		}
+		leadership.Pins = append(leadership.Pins, leadershipPin{
+			PinnedBy: pinnedBy,
+			Until:    pin.Expiry.Format(time.RFC3339),
+		})
+	}
+	return leadership
+}
+
+// formatServiceLeadershipSummary returns a summary of a service's
+// leadership, such as "mysql: leader mysql/0, pinned by admin until
+// 2015-06-01T12:00:00Z".
+func formatServiceLeadershipSummary(value interface{}) ([]byte, error) {
+	leadership, ok := value.(serviceLeadership)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", leadership, value)
+	}
+	var out bytes.Buffer
+	if leadership.Leader == "" {
+		fmt.Fprintf(&out, "%s: no leader", leadership.Service)
+	} else {
+		fmt.Fprintf(&out, "%s: leader %s", leadership.Service, leadership.Leader)
+	}
+	for _, pin := range leadership.Pins {
+		fmt.Fprintf(&out, ", pinned by %s until %s", pin.PinnedBy, pin.Until)
+	}
+	fmt.Fprintln(&out)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ShowServiceSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeShowServiceAPI
+}
+
+var _ = gc.Suite(&ShowServiceSuite{})
+
+type fakeShowServiceAPI struct {
+	service    string
+	leadership params.ServiceLeadershipResult
+}
+
+func (f *fakeShowServiceAPI) Close() error {
+	return nil
+}
+
+func (f *fakeShowServiceAPI) Leadership(service string) (params.ServiceLeadershipResult, error) {
+	f.service = service
+	return f.leadership, nil
+}
+
+func (s *ShowServiceSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeShowServiceAPI{
+		leadership: params.ServiceLeadershipResult{
+			Leader: "unit-mysql-0",
+			Pins: []params.LeadershipPin{{
+				Entity: "user-admin",
+				Expiry: time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+			}},
+		},
+	}
+	s.PatchValue(&getShowServiceAPI, func(*ShowServiceCommand) (ShowServiceAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ShowServiceSuite) TestShowSummary(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowServiceCommand{}), "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals,
+		"mysql: leader mysql/0, pinned by admin until 2015-06-01T12:00:00Z\n")
+	c.Assert(s.api.service, gc.Equals, "mysql")
+}
+
+func (s *ShowServiceSuite) TestShowSummaryNoLeader(c *gc.C) {
+	s.api.leadership = params.ServiceLeadershipResult{}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowServiceCommand{}), "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "mysql: no leader\n")
+}
+
+func (s *ShowServiceSuite) TestShowYaml(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowServiceCommand{}), "mysql", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Matches, `
+service: mysql
+leader: mysql/0
+pins:
+- pinned-by: admin
+  until: "?2015-06-01T12:00:00Z"?
+`[1:])
+}
+
+func (s *ShowServiceSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no service specified",
+	}, {
+		args: []string{"mysql/0"},
+		err:  `invalid service name "mysql/0"`,
+	}, {
+		args: []string{"mysql", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&ShowServiceCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
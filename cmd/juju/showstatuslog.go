@@ -0,0 +1,217 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/statushistory"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const showStatusLogDoc = `
+Show the status history of a unit or machine, most recent first.
+
+For units, the history of the workload status is shown by default;
+use --type agent to show the history of the unit agent's status
+instead. For machines, use --type provisioning to show the progress
+made in provisioning the machine, from choosing its image through
+to starting its agent, which helps to diagnose machines that never
+start.
+
+The history may be restricted to a time range with --from and --to,
+each of which accepts either an RFC3339 timestamp such as
+2015-04-01T12:00:00Z or a duration such as 2h, meaning that long ago.
+Use --changes to show only one entry for each change of status, and
+-n to limit the number of entries shown.
+
+Examples:
+
+    juju show-status-log mysql/0
+    juju show-status-log mysql/0 --type agent --changes
+    juju show-status-log 0 --from 2h -n 10
+    juju show-status-log 1 --type provisioning
+`
+
+// ShowStatusLogCommand shows the status history of a unit or machine.
+type ShowStatusLogCommand struct {
+	envcmd.EnvCommandBase
+	out     cmd.Output
+	tag     names.Tag
+	kind    string
+	from    string
+	to      string
+	size    int
+	changes bool
+
+	fromTime time.Time
+	toTime   time.Time
+}
+
+// Info implements Command.Info.
+func (c *ShowStatusLogCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-status-log",
+		Args:    "<unit|machine>",
+		Purpose: "show the status history of a unit or machine",
+		Doc:     showStatusLogDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ShowStatusLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatStatusLogTabular,
+	})
+	f.StringVar(&c.kind, "type", string(params.KindWorkload), "status to show: workload or agent for units, provisioning for machines")
+	f.StringVar(&c.from, "from", "", "show only entries recorded at or after this time")
+	f.StringVar(&c.to, "to", "", "show only entries recorded at or before this time")
+	f.IntVar(&c.size, "n", 0, "show at most this many entries")
+	f.IntVar(&c.size, "size", 0, "")
+	f.BoolVar(&c.changes, "changes", false, "show only one entry for each change of status")
+}
+
+// Init implements Command.Init.
+func (c *ShowStatusLogCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit or machine specified")
+	}
+	entity, args := args[0], args[1:]
+	switch {
+	case names.IsValidUnit(entity):
+		c.tag = names.NewUnitTag(entity)
+	case names.IsValidMachine(entity):
+		c.tag = names.NewMachineTag(entity)
+	default:
+		return errors.Errorf("%q is not a valid unit or machine", entity)
+	}
+	switch params.HistoryKind(c.kind) {
+	case params.KindWorkload, params.KindAgent:
+	case params.KindProvisioning:
+		if c.tag.Kind() != names.MachineTagKind {
+			return errors.Errorf("provisioning status is only recorded for machines")
+		}
+	default:
+		return errors.Errorf("invalid status type %q, expected workload, agent or provisioning", c.kind)
+	}
+	if c.size < 0 {
+		return errors.Errorf("invalid number of entries %d", c.size)
+	}
+	var err error
+	if c.fromTime, err = parseStatusLogTime(c.from); err != nil {
+		return errors.Annotate(err, "invalid --from value")
+	}
+	if c.toTime, err = parseStatusLogTime(c.to); err != nil {
+		return errors.Annotate(err, "invalid --to value")
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// parseStatusLogTime parses a time given either as an RFC3339
+// timestamp or as a duration before now. An empty value yields the
+// zero time.
+func parseStatusLogTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, errors.Errorf("expected RFC3339 time or duration, got %q", value)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// ShowStatusLogAPI defines the API methods used by the
+// show-status-log command.
+type ShowStatusLogAPI interface {
+	Close() error
+	StatusHistory(arg params.StatusHistoryArg) ([]params.HistoricalStatus, error)
+}
+
+var getShowStatusLogAPI = func(c *ShowStatusLogCommand) (ShowStatusLogAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return statushistory.NewClient(root), nil
+}
+
+// Run implements Command.Run.
+func (c *ShowStatusLogCommand) Run(ctx *cmd.Context) error {
+	api, err := getShowStatusLogAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	arg := params.StatusHistoryArg{
+		Tag:     c.tag.String(),
+		Kind:    params.HistoryKind(c.kind),
+		Size:    c.size,
+		Changes: c.changes,
+	}
+	if !c.fromTime.IsZero() {
+		arg.From = &c.fromTime
+	}
+	if !c.toTime.IsZero() {
+		arg.To = &c.toTime
+	}
+	history, err := api.StatusHistory(arg)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatStatusLog(history))
+}
+
+// statusLogEntry defines the serialization behaviour of a status
+// history entry.
+type statusLogEntry struct {
+	Since  string                 `yaml:"since" json:"since"`
+	Status params.Status          `yaml:"status" json:"status"`
+	Info   string                 `yaml:"info,omitempty" json:"info,omitempty"`
+	Data   map[string]interface{} `yaml:"data,omitempty" json:"data,omitempty"`
+}
+
+func formatStatusLog(history []params.HistoricalStatus) []statusLogEntry {
+	result := make([]statusLogEntry, len(history))
+	for i, entry := range history {
+		result[i] = statusLogEntry{
+			Since:  entry.Since.Format(time.RFC3339),
+			Status: entry.Status,
+			Info:   entry.Info,
+			Data:   entry.Data,
+		}
+	}
+	return result
+}
+
+// formatStatusLogTabular returns a tabular summary of status history.
+func formatStatusLogTabular(value interface{}) ([]byte, error) {
+	entries, ok := value.([]statusLogEntry)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", entries, value)
+	}
+	var out bytes.Buffer
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSTATUS\tINFO")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entry.Since, entry.Status, entry.Info)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ShowStatusLogSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeShowStatusLogAPI
+}
+
+var _ = gc.Suite(&ShowStatusLogSuite{})
+
+type fakeShowStatusLogAPI struct {
+	arg     params.StatusHistoryArg
+	history []params.HistoricalStatus
+}
+
+func (f *fakeShowStatusLogAPI) Close() error {
+	return nil
+}
+
+func (f *fakeShowStatusLogAPI) StatusHistory(arg params.StatusHistoryArg) ([]params.HistoricalStatus, error) {
+	f.arg = arg
+	return f.history, nil
+}
+
+func (s *ShowStatusLogSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeShowStatusLogAPI{
+		history: []params.HistoricalStatus{{
+			Status: params.StatusRunning,
+			Info:   "ready",
+			Since:  time.Date(2015, 4, 1, 12, 5, 0, 0, time.UTC),
+		}, {
+			Status: params.StatusBusy,
+			Since:  time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC),
+		}},
+	}
+	s.PatchValue(&getShowStatusLogAPI, func(*ShowStatusLogCommand) (ShowStatusLogAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ShowStatusLogSuite) TestShowTabular(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"TIME                 STATUS  INFO\n"+
+		"2015-04-01T12:05:00Z running ready\n"+
+		"2015-04-01T12:00:00Z busy    \n",
+	)
+	c.Assert(s.api.arg, jc.DeepEquals, params.StatusHistoryArg{
+		Tag:  "unit-mysql-0",
+		Kind: params.KindWorkload,
+	})
+}
+
+func (s *ShowStatusLogSuite) TestShowYaml(c *gc.C) {
+	s.api.history = s.api.history[:1]
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}), "0", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+- since: 2015-04-01T12:05:00Z
+  status: running
+  info: ready
+`[1:])
+	c.Assert(s.api.arg.Tag, gc.Equals, "machine-0")
+}
+
+func (s *ShowStatusLogSuite) TestShowWithOptions(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}),
+		"mysql/0", "--type", "agent", "--changes", "-n", "5",
+		"--from", "2015-04-01T12:00:00Z", "--to", "2015-04-02T12:00:00Z",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	from := time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2015, 4, 2, 12, 0, 0, 0, time.UTC)
+	c.Assert(s.api.arg.Kind, gc.Equals, params.KindAgent)
+	c.Assert(s.api.arg.Changes, jc.IsTrue)
+	c.Assert(s.api.arg.Size, gc.Equals, 5)
+	c.Assert(s.api.arg.From.Equal(from), jc.IsTrue)
+	c.Assert(s.api.arg.To.Equal(to), jc.IsTrue)
+}
+
+func (s *ShowStatusLogSuite) TestShowProvisioning(c *gc.C) {
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}), "0", "--type", "provisioning")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.arg, jc.DeepEquals, params.StatusHistoryArg{
+		Tag:  "machine-0",
+		Kind: params.KindProvisioning,
+	})
+}
+
+func (s *ShowStatusLogSuite) TestShowFromDuration(c *gc.C) {
+	before := time.Now()
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}), "mysql/0", "--from", "1h")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.arg.From, gc.NotNil)
+	c.Assert(s.api.arg.From.Before(before.Add(-time.Hour)), jc.IsFalse)
+	c.Assert(s.api.arg.To, gc.IsNil)
+}
+
+func (s *ShowStatusLogSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: nil,
+		err:  "no unit or machine specified",
+	}, {
+		args: []string{"mysql"},
+		err:  `"mysql" is not a valid unit or machine`,
+	}, {
+		args: []string{"mysql/0", "--type", "foo"},
+		err:  `invalid status type "foo", expected workload, agent or provisioning`,
+	}, {
+		args: []string{"mysql/0", "--type", "provisioning"},
+		err:  "provisioning status is only recorded for machines",
+	}, {
+		args: []string{"mysql/0", "-n", "-1"},
+		err:  "invalid number of entries -1",
+	}, {
+		args: []string{"mysql/0", "--from", "yesterday"},
+		err:  `invalid --from value: expected RFC3339 time or duration, got "yesterday"`,
+	}, {
+		args: []string{"mysql/0", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowStatusLogCommand{}), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const showUnitDoc = `
+Show the hooks a unit's agent has yet to run: the failed hook awaiting
+resolution, if any, and the hooks queued behind it, in the order they
+will run. Queued hooks are identified by the ids accepted by drop-hook;
+those that the agent has been asked not to run are marked as dropped.
+
+The queue is as last reported by the unit's agent.
+
+Examples:
+
+    juju show-unit mysql/0
+    juju show-unit mysql/0 --format yaml
+`
+
+// ShowUnitCommand shows the hooks queued for a unit.
+type ShowUnitCommand struct {
+	envcmd.EnvCommandBase
+	out      cmd.Output
+	unitName string
+}
+
+// Info implements Command.Info.
+func (c *ShowUnitCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "show-unit",
+		Args:    "<unit>",
+		Purpose: "show the hooks queued for a unit",
+		Doc:     showUnitDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *ShowUnitCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "summary", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"summary": formatUnitHookQueueSummary,
+	})
+}
+
+// Init implements Command.Init.
+func (c *ShowUnitCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit specified")
+	}
+	c.unitName, args = args[0], args[1:]
+	if !names.IsValidUnit(c.unitName) {
+		return errors.Errorf("invalid unit name %q", c.unitName)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+// ShowUnitAPI defines the API methods used by the show-unit command.
+type ShowUnitAPI interface {
+	Close() error
+	UnitHookQueue(unit string) (params.HookQueueResult, error)
+}
+
+var getShowUnitAPI = func(c *ShowUnitCommand) (ShowUnitAPI, error) {
+	return c.NewAPIClient()
+}
+
+// Run implements Command.Run.
+func (c *ShowUnitCommand) Run(ctx *cmd.Context) error {
+	api, err := getShowUnitAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	queue, err := api.UnitHookQueue(c.unitName)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, formatUnitHookQueue(c.unitName, queue))
+}
+
+// unitHookQueue defines the serialization behaviour of a unit's hook
+// queue.
+type unitHookQueue struct {
+	Unit        string            `yaml:"unit" json:"unit"`
+	FailedHook  string            `yaml:"failed-hook,omitempty" json:"failed-hook,omitempty"`
+	QueuedHooks []queuedHookEntry `yaml:"queued-hooks,omitempty" json:"queued-hooks,omitempty"`
+}
+
+// queuedHookEntry defines the serialization behaviour of a queued hook.
+type queuedHookEntry struct {
+	Id      string `yaml:"id" json:"id"`
+	Kind    string `yaml:"kind" json:"kind"`
+	Dropped bool   `yaml:"dropped,omitempty" json:"dropped,omitempty"`
+}
+
+func formatUnitHookQueue(unitName string, queue params.HookQueueResult) unitHookQueue {
+	result := unitHookQueue{Unit: unitName}
+	if queue.Pending != nil {
+		result.FailedHook = queue.Pending.Kind
+	}
+	dropped := make(map[string]bool)
+	for _, hook := range queue.Dropped {
+		dropped[hook.Id] = true
+	}
+	for _, hook := range queue.Queued {
+		result.QueuedHooks = append(result.QueuedHooks, queuedHookEntry{
+			Id:      hook.Id,
+			Kind:    hook.Kind,
+			Dropped: dropped[hook.Id],
+		})
+	}
+	return result
+}
+
+// formatUnitHookQueueSummary returns a summary of a unit's hook queue,
+// followed by a table of the queued hooks.
+func formatUnitHookQueueSummary(value interface{}) ([]byte, error) {
+	queue, ok := value.(unitHookQueue)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", queue, value)
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s: %s\n", queue.Unit, summarizeHookQueue(queue))
+	if len(queue.QueuedHooks) == 0 {
+		return out.Bytes(), nil
+	}
+	fmt.Fprintln(&out)
+	tw := tabwriter.NewWriter(&out, 0, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDROPPED")
+	for _, hook := range queue.QueuedHooks {
+		var dropped string
+		if hook.Dropped {
+			dropped = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", hook.Id, dropped)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
+
+// summarizeHookQueue describes a unit's hook queue in a single line,
+// such as "3 relation-changed hooks queued behind a failed
+// config-changed".
+func summarizeHookQueue(queue unitHookQueue) string {
+	var kinds []string
+	counts := make(map[string]int)
+	for _, hook := range queue.QueuedHooks {
+		if counts[hook.Kind] == 0 {
+			kinds = append(kinds, hook.Kind)
+		}
+		counts[hook.Kind]++
+	}
+	var parts []string
+	for _, kind := range kinds {
+		if counts[kind] == 1 {
+			parts = append(parts, fmt.Sprintf("1 %s hook", kind))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %s hooks", counts[kind], kind))
+		}
+	}
+	summary := "no hooks"
+	switch len(parts) {
+	case 0:
+	case 1:
+		summary = parts[0]
+	default:
+		last := len(parts) - 1
+		summary = strings.Join(parts[:last], ", ") + " and " + parts[last]
+	}
+	summary += " queued"
+	if queue.FailedHook != "" {
+		summary += " behind a failed " + queue.FailedHook
+	}
+	return summary
+}
@@ -0,0 +1,126 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ShowUnitSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeShowUnitAPI
+}
+
+var _ = gc.Suite(&ShowUnitSuite{})
+
+type fakeShowUnitAPI struct {
+	unit  string
+	queue params.HookQueueResult
+}
+
+func (f *fakeShowUnitAPI) Close() error {
+	return nil
+}
+
+func (f *fakeShowUnitAPI) UnitHookQueue(unit string) (params.HookQueueResult, error) {
+	f.unit = unit
+	return f.queue, nil
+}
+
+func relationChanged(id string) params.QueuedHook {
+	return params.QueuedHook{Id: id, Kind: "relation-changed"}
+}
+
+func (s *ShowUnitSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeShowUnitAPI{
+		queue: params.HookQueueResult{
+			Pending: &params.QueuedHook{Id: "config-changed", Kind: "config-changed"},
+			Queued: []params.QueuedHook{
+				relationChanged("relation-changed:0:wordpress/0"),
+				relationChanged("relation-changed:0:wordpress/1"),
+				relationChanged("relation-changed:1:nagios/0"),
+			},
+			Dropped: []params.QueuedHook{
+				relationChanged("relation-changed:0:wordpress/1"),
+			},
+		},
+	}
+	s.PatchValue(&getShowUnitAPI, func(*ShowUnitCommand) (ShowUnitAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *ShowUnitSuite) TestShowSummary(c *gc.C) {
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowUnitCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, ""+
+		"mysql/0: 3 relation-changed hooks queued behind a failed config-changed\n"+
+		"\n"+
+		"ID                             DROPPED\n"+
+		"relation-changed:0:wordpress/0 \n"+
+		"relation-changed:0:wordpress/1 yes\n"+
+		"relation-changed:1:nagios/0    \n",
+	)
+	c.Assert(s.api.unit, gc.Equals, "mysql/0")
+}
+
+func (s *ShowUnitSuite) TestShowSummaryMixed(c *gc.C) {
+	s.api.queue = params.HookQueueResult{
+		Queued: []params.QueuedHook{
+			{Id: "config-changed", Kind: "config-changed"},
+			relationChanged("relation-changed:0:wordpress/0"),
+			relationChanged("relation-changed:0:wordpress/1"),
+		},
+	}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowUnitCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Matches,
+		"mysql/0: 1 config-changed hook and 2 relation-changed hooks queued\n(.|\n)*")
+}
+
+func (s *ShowUnitSuite) TestShowSummaryEmpty(c *gc.C) {
+	s.api.queue = params.HookQueueResult{}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowUnitCommand{}), "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "mysql/0: no hooks queued\n")
+}
+
+func (s *ShowUnitSuite) TestShowYaml(c *gc.C) {
+	s.api.queue.Queued = s.api.queue.Queued[1:2]
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&ShowUnitCommand{}), "mysql/0", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, `
+unit: mysql/0
+failed-hook: config-changed
+queued-hooks:
+- id: relation-changed:0:wordpress/1
+  kind: relation-changed
+  dropped: true
+`[1:])
+}
+
+func (s *ShowUnitSuite) TestInitErrors(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no unit specified",
+	}, {
+		args: []string{"mysql"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"mysql/0", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		err := coretesting.InitCommand(&ShowUnitCommand{}, test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -5,17 +5,21 @@ package main
 
 import (
 	"fmt"
-	"net"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	"github.com/juju/utils"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/utils/ssh"
@@ -24,6 +28,8 @@ import (
 // SSHCommand is responsible for launching a ssh shell on a given unit or machine.
 type SSHCommand struct {
 	SSHCommon
+	proxyStdio bool
+	proxyPort  int
 }
 
 // SSHCommon provides common methods for SSHCommand, SCPCommand and DebugHooksCommand.
@@ -34,7 +40,12 @@ type SSHCommon struct {
 	Target    string
 	Args      []string
 	apiClient sshAPIClient
-	apiAddr   string
+
+	// knownHosts holds the known_hosts entries for the hosts of the
+	// targets resolved so far, and unverifiedHosts records whether
+	// any of those hosts have no recorded ssh host keys.
+	knownHosts      []string
+	unverifiedHosts bool
 }
 
 func (c *SSHCommon) SetFlags(f *gnuflag.FlagSet) {
@@ -42,20 +53,67 @@ func (c *SSHCommon) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.pty, "pty", true, "enable pseudo-tty allocation")
 }
 
-// setProxyCommand sets the proxy command option.
+// setProxyCommand sets the proxy command option, so that connections
+// are relayed through the API server by "juju ssh --proxy-stdio".
 func (c *SSHCommon) setProxyCommand(options *ssh.Options) error {
-	apiServerHost, _, err := net.SplitHostPort(c.apiAddr)
-	if err != nil {
-		return fmt.Errorf("failed to get proxy address: %v", err)
-	}
 	juju, err := getJujuExecutable()
 	if err != nil {
 		return fmt.Errorf("failed to get juju executable path: %v", err)
 	}
-	options.SetProxyCommand(juju, "ssh", "--proxy=false", "--pty=false", apiServerHost, "nc", "-q0", "%h", "%p")
+	command := []string{juju, "ssh"}
+	if envName := c.ConnectionName(); envName != "" {
+		command = append(command, "-e", envName)
+	}
+	command = append(command, "--proxy-stdio", "%h", "%p")
+	options.SetProxyCommand(command...)
 	return nil
 }
 
+// addKnownHost records the ssh host keys of the machine identified by
+// target, or of the machine hosting the unit it identifies, as those
+// expected of host.
+func (c *SSHCommon) addKnownHost(target, host string) error {
+	keys, err := c.apiClient.SSHHostKeys(target)
+	if params.IsCodeNotImplemented(err) {
+		// The API server predates recorded host keys.
+		keys, err = nil, nil
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot get ssh host keys for %s", target)
+	}
+	if len(keys) == 0 {
+		logger.Warningf("no ssh host keys recorded for %s; its identity will not be verified", target)
+		c.unverifiedHosts = true
+		return nil
+	}
+	for _, key := range keys {
+		c.knownHosts = append(c.knownHosts, host+" "+key)
+	}
+	return nil
+}
+
+// setKnownHosts configures options to verify the identity of each
+// host resolved from a target against the machine's recorded ssh host
+// keys, provided every host has recorded keys. It returns a function
+// that removes the temporary known hosts file it writes.
+func (c *SSHCommon) setKnownHosts(options *ssh.Options) (cleanup func(), err error) {
+	if len(c.knownHosts) == 0 || c.unverifiedHosts {
+		return func() {}, nil
+	}
+	f, err := ioutil.TempFile("", "juju-known-hosts")
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot write known hosts")
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(c.knownHosts, "\n") + "\n"); err != nil {
+		os.Remove(f.Name())
+		return nil, errors.Annotate(err, "cannot write known hosts")
+	}
+	options.SetKnownHostsFile(f.Name())
+	options.EnableStrictHostKeyChecking()
+	return func() { os.Remove(f.Name()) }, nil
+}
+
 const sshDoc = `
 Launch an ssh shell on the machine identified by the <target> parameter.
 <target> can be either a machine id  as listed by "juju status" in the
@@ -83,6 +141,12 @@ Connect to the first mysql unit and run 'ls -la /var/log/juju':
 Connect to the first jenkins unit as the user jenkins:
 
     juju ssh jenkins@jenkins/0
+
+Unless --proxy=false is given, or the environment's proxy-ssh setting is
+false, the connection is relayed through the Juju API server, so the
+machine need not be reachable from the client. Where the machine agent
+has recorded the machine's ssh host keys, the machine's identity is
+verified against them.
 `
 
 func (c *SSHCommand) Info() *cmd.Info {
@@ -94,7 +158,23 @@ func (c *SSHCommand) Info() *cmd.Info {
 	}
 }
 
+func (c *SSHCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.SSHCommon.SetFlags(f)
+	f.BoolVar(&c.proxyStdio, "proxy-stdio", false, "relay stdin and stdout to <host> <port> through the API server; used as an ssh ProxyCommand")
+}
+
 func (c *SSHCommand) Init(args []string) error {
+	if c.proxyStdio {
+		if len(args) != 2 {
+			return fmt.Errorf("--proxy-stdio requires a host and port")
+		}
+		port, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid port %q", args[1])
+		}
+		c.Target, c.proxyPort = args[0], port
+		return nil
+	}
 	if len(args) == 0 {
 		return fmt.Errorf("no target name specified")
 	}
@@ -132,6 +212,9 @@ func (c *SSHCommon) getSSHOptions(enablePty bool) (*ssh.Options, error) {
 // Run resolves c.Target to a machine, to the address of a i
 // machine or unit forks ssh passing any arguments provided.
 func (c *SSHCommand) Run(ctx *cmd.Context) error {
+	if c.proxyStdio {
+		return c.relayStdio(ctx)
+	}
 	if c.apiClient == nil {
 		// If the apClient is not already opened and it is opened
 		// by ensureAPIClient, then close it when we're done.
@@ -151,6 +234,11 @@ func (c *SSHCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return err
 	}
+	cleanup, err := c.setKnownHosts(options)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 	cmd := ssh.Command(user+"@"+host, c.Args, options)
 	cmd.Stdin = ctx.Stdin
 	cmd.Stdout = ctx.Stdout
@@ -158,6 +246,25 @@ func (c *SSHCommand) Run(ctx *cmd.Context) error {
 	return cmd.Run()
 }
 
+// relayStdio relays stdin and stdout to the ssh server at the target
+// host and port through the API server, until the server closes the
+// connection.
+func (c *SSHCommand) relayStdio(ctx *cmd.Context) error {
+	st, err := c.NewAPIRoot()
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	conn, err := st.ConnectSSHProxy(c.Target, c.proxyPort)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go io.Copy(conn, ctx.Stdin)
+	_, err = io.Copy(ctx.Stdout, conn)
+	return err
+}
+
 // proxySSH returns true iff both c.proxy and
 // the proxy-ssh environment configuration
 // are true.
@@ -195,7 +302,6 @@ func (c *SSHCommon) initAPIClient() (sshAPIClient, error) {
 		return nil, err
 	}
 	c.apiClient = st.Client()
-	c.apiAddr = st.Addr()
 	return c.apiClient, nil
 }
 
@@ -203,6 +309,7 @@ type sshAPIClient interface {
 	EnvironmentGet() (map[string]interface{}, error)
 	PublicAddress(target string) (string, error)
 	PrivateAddress(target string) (string, error)
+	SSHHostKeys(target string) ([]string, error)
 	ServiceCharmRelations(service string) ([]string, error)
 	Close() error
 }
@@ -255,6 +362,9 @@ func (c *SSHCommon) userHostFromTarget(target string) (user, host string, err er
 			addr, err = c.apiClient.PublicAddress(target)
 		}
 		if err == nil {
+			if err := c.addKnownHost(target, addr); err != nil {
+				return "", "", err
+			}
 			return user, addr, nil
 		}
 	}
@@ -4,11 +4,14 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/juju/cmd"
@@ -24,6 +27,7 @@ import (
 	"github.com/juju/juju/testcharms"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/utils/ssh"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 )
 
 var _ = gc.Suite(&SSHSuite{})
@@ -57,7 +61,7 @@ func (s *SSHCommonSuite) SetUpTest(c *gc.C) {
 
 const (
 	noProxy           = `-o StrictHostKeyChecking no -o PasswordAuthentication no -o ServerAliveInterval 30 `
-	args              = `-o StrictHostKeyChecking no -o ProxyCommand juju ssh --proxy=false --pty=false localhost nc -q0 %h %p -o PasswordAuthentication no -o ServerAliveInterval 30 `
+	args              = `-o StrictHostKeyChecking no -o ProxyCommand juju ssh -e dummyenv --proxy-stdio %h %p -o PasswordAuthentication no -o ServerAliveInterval 30 `
 	commonArgsNoProxy = noProxy + `-o UserKnownHostsFile /dev/null `
 	commonArgs        = args + `-o UserKnownHostsFile /dev/null `
 	sshArgs           = args + `-t -t -o UserKnownHostsFile /dev/null `
@@ -143,6 +147,82 @@ func (s *SSHSuite) TestSSHCommandEnvironProxySSH(c *gc.C) {
 	c.Check(strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n"), gc.Equals, sshArgsNoProxy+"ubuntu@dummyenv-0.dns")
 }
 
+func (s *SSHSuite) TestSSHCommandVerifiesHostKeys(c *gc.C) {
+	m := s.makeMachines(1, c, true)
+	hostKeys := []string{sshtesting.ValidKeyOne.Key + " root@host", sshtesting.ValidKeyTwo.Key + " root@host"}
+	err := m[0].SetSSHHostKeys(hostKeys)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx := coretesting.Context(c)
+	sshCmd := &SSHCommand{}
+	code := cmd.Main(envcmd.Wrap(sshCmd), ctx, []string{"0"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, "")
+	c.Check(strings.TrimRight(ctx.Stdout.(*bytes.Buffer).String(), "\r\n"), gc.Matches,
+		regexp.QuoteMeta(`-o StrictHostKeyChecking yes -o ProxyCommand juju ssh -e dummyenv --proxy-stdio %h %p `+
+			`-o PasswordAuthentication no -o ServerAliveInterval 30 -t -t -o UserKnownHostsFile `)+
+			`.*juju-known-hosts[0-9]+ ubuntu@dummyenv-0\.internal`)
+	c.Check(sshCmd.knownHosts, jc.DeepEquals, []string{
+		"dummyenv-0.internal " + hostKeys[0],
+		"dummyenv-0.internal " + hostKeys[1],
+	})
+}
+
+func (s *SSHSuite) TestSSHCommandProxyStdioInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		errorString string
+	}{{
+		args:        []string{"--proxy-stdio", "10.0.0.1"},
+		errorString: "--proxy-stdio requires a host and port",
+	}, {
+		args:        []string{"--proxy-stdio", "10.0.0.1", "ssh"},
+		errorString: `invalid port "ssh"`,
+	}, {
+		args: []string{"--proxy-stdio", "10.0.0.1", "22"},
+	}} {
+		c.Logf("test %d", i)
+		sshCmd := &SSHCommand{}
+		err := coretesting.InitCommand(sshCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(sshCmd.Target, gc.Equals, "10.0.0.1")
+			c.Check(sshCmd.proxyPort, gc.Equals, 22)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *SSHSuite) TestSSHCommandProxyStdio(c *gc.C) {
+	// Stand in for a machine's ssh server, which greets the client
+	// and echoes the first line it receives.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("SSH-2.0-OpenSSH\r\n"))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte(line))
+	}()
+	m := s.makeMachines(1, c, false)
+	err = m[0].SetAddresses(network.NewAddress("127.0.0.1", network.ScopeCloudLocal))
+	c.Assert(err, jc.ErrorIsNil)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	ctx := coretesting.Context(c)
+	ctx.Stdin = strings.NewReader("hello\n")
+	code := cmd.Main(envcmd.Wrap(&SSHCommand{}), ctx, []string{"--proxy-stdio", "127.0.0.1", fmt.Sprint(port)})
+	c.Check(code, gc.Equals, 0)
+	c.Check(ctx.Stderr.(*bytes.Buffer).String(), gc.Equals, "")
+	c.Check(ctx.Stdout.(*bytes.Buffer).String(), gc.Equals, "SSH-2.0-OpenSSH\r\nhello\n")
+}
+
 func (s *SSHSuite) TestSSHWillWorkInUpgrade(c *gc.C) {
 	// Check the API client interface used by "juju ssh" against what
 	// the API server will allow during upgrades. Ensure that the API
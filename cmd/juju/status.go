@@ -6,6 +6,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -139,6 +141,7 @@ type machineStatus struct {
 	AgentState     params.Status            `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
 	AgentStateInfo string                   `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
 	AgentVersion   string                   `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
+	AgentLastSeen  string                   `json:"agent-last-seen,omitempty" yaml:"agent-last-seen,omitempty"`
 	DNSName        string                   `json:"dns-name,omitempty" yaml:"dns-name,omitempty"`
 	InstanceId     instance.Id              `json:"instance-id,omitempty" yaml:"instance-id,omitempty"`
 	InstanceState  string                   `json:"instance-state,omitempty" yaml:"instance-state,omitempty"`
@@ -174,6 +177,9 @@ func (s machineStatus) GetYAML() (tag string, value interface{}) {
 
 type serviceStatus struct {
 	Err           error                 `json:"-" yaml:",omitempty"`
+	ServiceStatus *workloadStatus       `json:"service-status,omitempty" yaml:"service-status,omitempty"`
+	Version       string                `json:"version,omitempty" yaml:"version,omitempty"`
+	MeterStatus   *meterStatus          `json:"meter-status,omitempty" yaml:"meter-status,omitempty"`
 	Charm         string                `json:"charm" yaml:"charm"`
 	CanUpgradeTo  string                `json:"can-upgrade-to,omitempty" yaml:"can-upgrade-to,omitempty"`
 	Exposed       bool                  `json:"exposed" yaml:"exposed"`
@@ -203,16 +209,35 @@ func (s serviceStatus) GetYAML() (tag string, value interface{}) {
 }
 
 type unitStatus struct {
-	Err            error                 `json:"-" yaml:",omitempty"`
-	Charm          string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
-	AgentState     params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
-	AgentStateInfo string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
-	AgentVersion   string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
-	Life           string                `json:"life,omitempty" yaml:"life,omitempty"`
-	Machine        string                `json:"machine,omitempty" yaml:"machine,omitempty"`
-	OpenedPorts    []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
-	PublicAddress  string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
-	Subordinates   map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
+	Err             error                 `json:"-" yaml:",omitempty"`
+	WorkloadStatus  *workloadStatus       `json:"workload-status,omitempty" yaml:"workload-status,omitempty"`
+	WorkloadVersion string                `json:"workload-version,omitempty" yaml:"workload-version,omitempty"`
+	MeterStatus     *meterStatus          `json:"meter-status,omitempty" yaml:"meter-status,omitempty"`
+	Charm           string                `json:"upgrading-from,omitempty" yaml:"upgrading-from,omitempty"`
+	AgentState      params.Status         `json:"agent-state,omitempty" yaml:"agent-state,omitempty"`
+	AgentStateInfo  string                `json:"agent-state-info,omitempty" yaml:"agent-state-info,omitempty"`
+	AgentVersion    string                `json:"agent-version,omitempty" yaml:"agent-version,omitempty"`
+	AgentLastSeen   string                `json:"agent-last-seen,omitempty" yaml:"agent-last-seen,omitempty"`
+	Life            string                `json:"life,omitempty" yaml:"life,omitempty"`
+	Machine         string                `json:"machine,omitempty" yaml:"machine,omitempty"`
+	OpenedPorts     []string              `json:"open-ports,omitempty" yaml:"open-ports,omitempty"`
+	PublicAddress   string                `json:"public-address,omitempty" yaml:"public-address,omitempty"`
+	Subordinates    map[string]unitStatus `json:"subordinates,omitempty" yaml:"subordinates,omitempty"`
+}
+
+// workloadStatus holds the status of the software run by a unit, and
+// any structured data its charm has attached to that status.
+type workloadStatus struct {
+	Current params.Status          `json:"current" yaml:"current"`
+	Message string                 `json:"message,omitempty" yaml:"message,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// meterStatus holds the meter status of a unit or service, when it
+// is AMBER or RED.
+type meterStatus struct {
+	Color   string `json:"color" yaml:"color"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 type unitStatusNoMarshal unitStatus
@@ -324,6 +349,7 @@ func (sf *statusFormatter) formatMachine(machine api.MachineStatus) machineStatu
 			AgentState:     machine.AgentState,
 			AgentStateInfo: adjustInfoIfAgentDown(machine.AgentState, agent.Status, agent.Info),
 			AgentVersion:   agent.Version,
+			AgentLastSeen:  formatLastSeen(agent.LastSeen),
 			Life:           agent.Life,
 			Err:            agent.Err,
 			DNSName:        machine.DNSName,
@@ -360,6 +386,7 @@ func (sf *statusFormatter) formatService(name string, service api.ServiceStatus)
 		CanUpgradeTo:  service.CanUpgradeTo,
 		SubordinateTo: service.SubordinateTo,
 		Units:         make(map[string]unitStatus),
+		Version:       service.WorkloadVersion,
 	}
 	if len(service.Networks.Enabled) > 0 {
 		out.Networks["enabled"] = service.Networks.Enabled
@@ -367,25 +394,65 @@ func (sf *statusFormatter) formatService(name string, service api.ServiceStatus)
 	if len(service.Networks.Disabled) > 0 {
 		out.Networks["disabled"] = service.Networks.Disabled
 	}
+	if service.Status.Status != "" {
+		// Old servers do not report service status.
+		out.ServiceStatus = &workloadStatus{
+			Current: service.Status.Status,
+			Message: service.Status.Info,
+			Data:    service.Status.Data,
+		}
+	}
+	out.MeterStatus = formatMeterStatus(service.MeterStatus)
 	for k, m := range service.Units {
 		out.Units[k] = sf.formatUnit(m, name)
 	}
 	return out
 }
 
+// formatLastSeen returns when an agent was last seen, or the empty
+// string if that is not known.
+func formatLastSeen(lastSeen *time.Time) string {
+	if lastSeen == nil {
+		return ""
+	}
+	return lastSeen.Format(time.RFC3339)
+}
+
+func formatMeterStatus(status *api.MeterStatus) *meterStatus {
+	if status == nil {
+		// Old servers do not report meter status.
+		return nil
+	}
+	return &meterStatus{
+		Color:   strings.ToLower(status.Code),
+		Message: status.Info,
+	}
+}
+
 func (sf *statusFormatter) formatUnit(unit api.UnitStatus, serviceName string) unitStatus {
 	out := unitStatus{
-		Err:            unit.Err,
-		AgentState:     unit.AgentState,
-		AgentStateInfo: sf.getUnitStatusInfo(unit, serviceName),
-		AgentVersion:   unit.AgentVersion,
-		Life:           unit.Life,
-		Machine:        unit.Machine,
-		OpenedPorts:    unit.OpenedPorts,
-		PublicAddress:  unit.PublicAddress,
-		Charm:          unit.Charm,
-		Subordinates:   make(map[string]unitStatus),
+		Err:             unit.Err,
+		AgentState:      unit.AgentState,
+		AgentStateInfo:  sf.getUnitStatusInfo(unit, serviceName),
+		AgentVersion:    unit.AgentVersion,
+		AgentLastSeen:   formatLastSeen(unit.Agent.LastSeen),
+		Life:            unit.Life,
+		Machine:         unit.Machine,
+		OpenedPorts:     unit.OpenedPorts,
+		PublicAddress:   unit.PublicAddress,
+		Charm:           unit.Charm,
+		Subordinates:    make(map[string]unitStatus),
+		WorkloadVersion: unit.WorkloadVersion,
+	}
+	if unit.Workload.Status != "" {
+		// Old servers do not report workload status.
+		out.WorkloadStatus = &workloadStatus{
+			Current: unit.Workload.Status,
+			Message: unit.Workload.Info,
+			Data:    unit.Workload.Data,
+		}
 	}
+	out.MeterStatus = formatMeterStatus(unit.MeterStatus)
 	for k, m := range unit.Subordinates {
 		out.Subordinates[k] = sf.formatUnit(m, serviceName)
 	}
@@ -354,6 +354,9 @@ var statusTests = []testCase{
 							"enabled":  L{"net1", "net2"},
 							"disabled": L{"foo", "bar", "no", "good"},
 						},
+						"service-status": M{
+							"current": "unknown",
+						},
 					},
 					"no-networks-service": M{
 						"charm":   "cs:quantal/dummy-1",
@@ -361,6 +364,9 @@ var statusTests = []testCase{
 						"networks": M{
 							"disabled": L{"mynet"},
 						},
+						"service-status": M{
+							"current": "unknown",
+						},
 					},
 				},
 				"networks": M{
@@ -554,8 +560,14 @@ var statusTests = []testCase{
 									"2/tcp", "3/tcp", "2/udp", "10/udp",
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"dummy-service": M{
 						"charm":   "cs:quantal/dummy-1",
@@ -566,8 +578,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(started)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -628,8 +646,14 @@ var statusTests = []testCase{
 									"2/tcp", "3/tcp", "2/udp", "10/udp",
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"dummy-service": M{
 						"charm":   "cs:quantal/dummy-1",
@@ -641,8 +665,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(started)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -667,8 +697,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(started)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -694,8 +730,14 @@ var statusTests = []testCase{
 									"2/tcp", "3/tcp", "2/udp", "10/udp",
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -719,8 +761,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(started)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -746,8 +794,14 @@ var statusTests = []testCase{
 									"2/tcp", "3/tcp", "2/udp", "10/udp",
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -772,8 +826,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(started)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"exposed-service": M{
 						"charm":   "cs:quantal/dummy-1",
@@ -787,8 +847,14 @@ var statusTests = []testCase{
 									"2/tcp", "3/tcp", "2/udp", "10/udp",
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -840,8 +906,14 @@ var statusTests = []testCase{
 								"agent-state":      "error",
 								"agent-state-info": "hook failed: some-relation-changed for mysql:server",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"mysql": M{
 						"charm":   "cs:quantal/mysql-1",
@@ -854,8 +926,14 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "allocating",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -907,8 +985,14 @@ var statusTests = []testCase{
 								"agent-state":      "down",
 								"agent-state-info": "(error: hook failed: some-relation-changed for mysql:server)",
 								"public-address":   "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"mysql": M{
 						"charm":   "cs:quantal/mysql-1",
@@ -921,8 +1005,14 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "allocating",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -953,8 +1043,14 @@ var statusTests = []testCase{
 							"dummy-service/0": M{
 								"machine":     "0",
 								"agent-state": "allocating",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1030,12 +1126,18 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "started",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"db":    L{"mysql"},
 							"cache": L{"varnish"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"mysql": M{
 						"charm":   "cs:quantal/mysql-1",
@@ -1045,11 +1147,17 @@ var statusTests = []testCase{
 								"machine":        "2",
 								"agent-state":    "started",
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"server": L{"private", "project"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"varnish": M{
 						"charm":   "cs:quantal/varnish-1",
@@ -1059,11 +1167,17 @@ var statusTests = []testCase{
 								"machine":        "3",
 								"agent-state":    "allocating",
 								"public-address": "dummyenv-3.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"webcache": L{"project"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"private": M{
 						"charm":   "cs:quantal/wordpress-3",
@@ -1073,11 +1187,17 @@ var statusTests = []testCase{
 								"machine":        "4",
 								"agent-state":    "allocating",
 								"public-address": "dummyenv-4.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"db": L{"mysql"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1131,21 +1251,33 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "started",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 							"riak/1": M{
 								"machine":        "2",
 								"agent-state":    "started",
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 							"riak/2": M{
 								"machine":        "3",
 								"agent-state":    "started",
 								"public-address": "dummyenv-3.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"ring": L{"riak"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1216,15 +1348,24 @@ var statusTests = []testCase{
 									"logging/0": M{
 										"agent-state":    "started",
 										"public-address": "dummyenv-1.dns",
+										"workload-status": M{
+											"current": "busy",
+										},
 									},
 								},
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"db":          L{"mysql"},
 							"logging-dir": L{"logging"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"mysql": M{
 						"charm":   "cs:quantal/mysql-1",
@@ -1238,15 +1379,24 @@ var statusTests = []testCase{
 										"agent-state":      "error",
 										"agent-state-info": "somehow lost in all those logs",
 										"public-address":   "dummyenv-2.dns",
+										"workload-status": M{
+											"current": "busy",
+										},
 									},
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"server":    L{"wordpress"},
 							"juju-info": L{"logging"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"logging": M{
 						"charm":   "cs:quantal/logging-1",
@@ -1256,6 +1406,9 @@ var statusTests = []testCase{
 							"info":              L{"mysql"},
 						},
 						"subordinate-to": L{"mysql", "wordpress"},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1283,15 +1436,24 @@ var statusTests = []testCase{
 									"logging/0": M{
 										"agent-state":    "started",
 										"public-address": "dummyenv-1.dns",
+										"workload-status": M{
+											"current": "busy",
+										},
 									},
 								},
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"db":          L{"mysql"},
 							"logging-dir": L{"logging"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"mysql": M{
 						"charm":   "cs:quantal/mysql-1",
@@ -1305,15 +1467,24 @@ var statusTests = []testCase{
 										"agent-state":      "error",
 										"agent-state-info": "somehow lost in all those logs",
 										"public-address":   "dummyenv-2.dns",
+										"workload-status": M{
+											"current": "busy",
+										},
 									},
 								},
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"server":    L{"wordpress"},
 							"juju-info": L{"logging"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"logging": M{
 						"charm":   "cs:quantal/logging-1",
@@ -1323,6 +1494,9 @@ var statusTests = []testCase{
 							"info":              L{"mysql"},
 						},
 						"subordinate-to": L{"mysql", "wordpress"},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1349,15 +1523,24 @@ var statusTests = []testCase{
 									"logging/0": M{
 										"agent-state":    "started",
 										"public-address": "dummyenv-1.dns",
+										"workload-status": M{
+											"current": "busy",
+										},
 									},
 								},
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
 						"relations": M{
 							"db":          L{"mysql"},
 							"logging-dir": L{"logging"},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 					"logging": M{
 						"charm":   "cs:quantal/logging-1",
@@ -1367,6 +1550,9 @@ var statusTests = []testCase{
 							"info":              L{"mysql"},
 						},
 						"subordinate-to": L{"mysql", "wordpress"},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1421,13 +1607,22 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "started",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 							"mysql/1": M{
 								"machine":        "1/lxc/0",
 								"agent-state":    "started",
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1465,8 +1660,14 @@ var statusTests = []testCase{
 								"machine":        "1/lxc/0",
 								"agent-state":    "started",
 								"public-address": "dummyenv-2.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1505,8 +1706,14 @@ var statusTests = []testCase{
 								"machine":        "1",
 								"agent-state":    "allocating",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1547,8 +1754,14 @@ var statusTests = []testCase{
 								"agent-state":    "started",
 								"upgrading-from": "cs:quantal/mysql-1",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1591,8 +1804,14 @@ var statusTests = []testCase{
 								"agent-state":    "started",
 								"upgrading-from": "cs:quantal/mysql-1",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
 					},
 				},
 			},
@@ -1634,8 +1853,69 @@ var statusTests = []testCase{
 								"agent-state":    "started",
 								"upgrading-from": "cs:quantal/mysql-1",
 								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "busy",
+								},
 							},
 						},
+						"service-status": M{
+							"current": "busy",
+						},
+					},
+				},
+			},
+		},
+	), test(
+		"unit with structured workload status data",
+		addMachine{machineId: "0", job: state.JobManageEnviron},
+		setAddresses{"0", []network.Address{network.NewAddress("dummyenv-0.dns", network.ScopeUnknown)}},
+		startAliveMachine{"0"},
+		setMachineStatus{"0", state.StatusStarted, ""},
+		addMachine{machineId: "1", job: state.JobHostUnits},
+		setAddresses{"1", []network.Address{network.NewAddress("dummyenv-1.dns", network.ScopeUnknown)}},
+		startAliveMachine{"1"},
+		setMachineStatus{"1", state.StatusStarted, ""},
+		addCharm{"mysql"},
+		addService{name: "mysql", charm: "mysql"},
+		setServiceExposed{"mysql", true},
+		addAliveUnit{"mysql", "1"},
+		setUnitStatus{"mysql/0", state.StatusActive, "", nil},
+		setUnitWorkloadStatus{"mysql/0", state.StatusBlocked, "replication lagging", map[string]interface{}{
+			"role":   "slave",
+			"master": "mysql/1",
+		}},
+
+		expect{
+			"workload status data is shown",
+			M{
+				"environment": "dummyenv",
+				"machines": M{
+					"0": machine0,
+					"1": machine1,
+				},
+				"services": M{
+					"mysql": M{
+						"charm":   "cs:quantal/mysql-1",
+						"exposed": true,
+						"units": M{
+							"mysql/0": M{
+								"machine":        "1",
+								"agent-state":    "started",
+								"public-address": "dummyenv-1.dns",
+								"workload-status": M{
+									"current": "blocked",
+									"message": "replication lagging",
+									"data": M{
+										"role":   "slave",
+										"master": "mysql/1",
+									},
+								},
+							},
+						},
+						"service-status": M{
+							"current": "blocked",
+							"message": "mysql/0: replication lagging",
+						},
 					},
 				},
 			},
@@ -1916,6 +2196,45 @@ func (sus setUnitStatus) step(c *gc.C, ctx *context) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+type setUnitWorkloadVersion struct {
+	unitName string
+	version  string
+}
+
+func (swv setUnitWorkloadVersion) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(swv.unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.SetWorkloadVersion(swv.version)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+type setUnitMeterStatus struct {
+	unitName string
+	code     string
+	info     string
+}
+
+func (sms setUnitMeterStatus) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(sms.unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.SetMeterStatus(sms.code, sms.info)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+type setUnitWorkloadStatus struct {
+	unitName   string
+	status     state.Status
+	statusInfo string
+	statusData map[string]interface{}
+}
+
+func (sws setUnitWorkloadStatus) step(c *gc.C, ctx *context) {
+	u, err := ctx.st.Unit(sws.unitName)
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.SetStatus(sws.status, sws.statusInfo, sws.statusData)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 type setUnitCharmURL struct {
 	unitName string
 	charm    string
@@ -2388,6 +2707,7 @@ func (s *StatusSuite) TestStatusWithFormatTabular(c *gc.C) {
 		setMachineStatus{"2", state.StatusStarted, ""},
 		addAliveUnit{"mysql", "2"},
 		setUnitStatus{"mysql/0", state.StatusActive, "", nil},
+		setUnitWorkloadVersion{"mysql/0", "5.7.9"},
 		addService{name: "logging", charm: "logging"},
 		setServiceExposed{"logging", true},
 		relateServices{"wordpress", "mysql"},
@@ -2415,10 +2735,10 @@ func (s *StatusSuite) TestStatusWithFormatTabular(c *gc.C) {
 			"2          started         dummyenv-2.dns dummyenv-2 quantal arch=amd64 cpu-cores=1 mem=1024M root-disk=8192M \n"+
 			"\n"+
 			"[Services] \n"+
-			"NAME       EXPOSED CHARM                  \n"+
-			"logging    true    cs:quantal/logging-1   \n"+
-			"mysql      true    cs:quantal/mysql-1     \n"+
-			"wordpress  true    cs:quantal/wordpress-3 \n"+
+			"NAME       EXPOSED VERSION CHARM                  \n"+
+			"logging    true            cs:quantal/logging-1   \n"+
+			"mysql      true    5.7.9   cs:quantal/mysql-1     \n"+
+			"wordpress  true            cs:quantal/wordpress-3 \n"+
 			"\n"+
 			"[Units]     \n"+
 			"ID          STATE   VERSION MACHINE PORTS PUBLIC-ADDRESS \n"+
@@ -2678,6 +2998,49 @@ func (s *StatusSuite) TestFilterOnPorts(c *gc.C) {
 	c.Assert(string(stdout), gc.Equals, expected[1:])
 }
 
+func (s *StatusSuite) TestStatusMeterStatus(c *gc.C) {
+	ctx := s.FilteringTestSetup(c)
+	defer s.resetContext(c, ctx)
+
+	setUnitMeterStatus{"mysql/0", "GREEN", "all good"}.step(c, ctx)
+	setUnitMeterStatus{"logging/0", "AMBER", "payment due"}.step(c, ctx)
+	setUnitMeterStatus{"logging/1", "RED", "payment overdue"}.step(c, ctx)
+	code, stdout, stderr := runStatus(c, "--format", "json")
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(string(stderr), gc.Equals, "")
+
+	var out struct {
+		Services map[string]struct {
+			MeterStatus map[string]string `json:"meter-status"`
+			Units       map[string]struct {
+				MeterStatus  map[string]string `json:"meter-status"`
+				Subordinates map[string]struct {
+					MeterStatus map[string]string `json:"meter-status"`
+				} `json:"subordinates"`
+			} `json:"units"`
+		} `json:"services"`
+	}
+	err := json.Unmarshal(stdout, &out)
+	c.Assert(err, jc.ErrorIsNil)
+	// GREEN meter statuses are not shown.
+	c.Assert(out.Services["mysql"].MeterStatus, gc.IsNil)
+	c.Assert(out.Services["mysql"].Units["mysql/0"].MeterStatus, gc.IsNil)
+	c.Assert(out.Services["wordpress"].MeterStatus, gc.IsNil)
+	// The worst status of a service's units is shown for the service.
+	c.Assert(out.Services["logging"].MeterStatus, jc.DeepEquals, map[string]string{
+		"color":   "red",
+		"message": "logging/1: payment overdue",
+	})
+	c.Assert(out.Services["wordpress"].Units["wordpress/0"].Subordinates["logging/0"].MeterStatus, jc.DeepEquals, map[string]string{
+		"color":   "amber",
+		"message": "payment due",
+	})
+	c.Assert(out.Services["mysql"].Units["mysql/0"].Subordinates["logging/1"].MeterStatus, jc.DeepEquals, map[string]string{
+		"color":   "red",
+		"message": "payment overdue",
+	})
+}
+
 // Scenario: User filters out a parent, but not its subordinate
 func (s *StatusSuite) TestFilterParentButNotSubordinate(c *gc.C) {
 	ctx := s.FilteringTestSetup(c)
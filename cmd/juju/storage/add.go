@@ -0,0 +1,162 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/cmd/juju/block"
+	jujustorage "github.com/juju/juju/storage"
+)
+
+const AddCommandDoc = `
+Add instances of charm storage to a unit.
+
+The storage is named as in the unit's charm metadata, and may be
+followed by storage constraints, in the same form as for
+"juju deploy --storage". If no constraints are given, one instance of
+the storage is added.
+
+Example:
+   juju storage add mysql/0 data=2
+`
+
+// AddCommand adds charm storage to a unit.
+type AddCommand struct {
+	StorageCommandBase
+	unitTag     names.UnitTag
+	storageName string
+	cons        jujustorage.Constraints
+}
+
+// Init implements Command.Init.
+func (c *AddCommand) Init(args []string) (err error) {
+	switch len(args) {
+	case 0:
+		return errors.New("no unit specified")
+	case 1:
+		return errors.New("no storage specified")
+	case 2:
+	default:
+		return cmd.CheckEmpty(args[2:])
+	}
+	if !names.IsValidUnit(args[0]) {
+		return errors.Errorf("invalid unit name %q", args[0])
+	}
+	c.unitTag = names.NewUnitTag(args[0])
+	c.storageName = args[1]
+	c.cons = jujustorage.Constraints{Count: 1}
+	if i := strings.IndexRune(args[1], '='); i >= 0 {
+		c.storageName = args[1][:i]
+		c.cons, err = jujustorage.ParseConstraints(args[1][i+1:])
+		if err != nil {
+			return errors.Annotatef(err, "cannot parse constraints for storage %q", c.storageName)
+		}
+	}
+	if c.storageName == "" {
+		return errors.New("no storage specified")
+	}
+	return nil
+}
+
+// Info implements Command.Info.
+func (c *AddCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "add",
+		Args:    "<unit name> <storage name>[=<constraints>]",
+		Purpose: "adds charm storage to a unit",
+		Doc:     AddCommandDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *AddCommand) Run(ctx *cmd.Context) error {
+	api, err := getStorageChangeAPI(&c.StorageCommandBase)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	err = api.AddStorage(c.unitTag, c.storageName, c.cons)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
+
+const DetachCommandDoc = `
+Detach a storage instance from a unit. The unit's charm is notified
+that the storage is going away, after which the attachment is removed.
+
+Example:
+   juju storage detach data/0 mysql/0
+`
+
+// DetachCommand detaches a storage instance from a unit.
+type DetachCommand struct {
+	StorageCommandBase
+	storageTag names.StorageTag
+	unitTag    names.UnitTag
+}
+
+// Init implements Command.Init.
+func (c *DetachCommand) Init(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no storage specified")
+	case 1:
+		return errors.New("no unit specified")
+	case 2:
+	default:
+		return cmd.CheckEmpty(args[2:])
+	}
+	if !names.IsValidStorage(args[0]) {
+		return errors.Errorf("invalid storage id %q", args[0])
+	}
+	if !names.IsValidUnit(args[1]) {
+		return errors.Errorf("invalid unit name %q", args[1])
+	}
+	c.storageTag = names.NewStorageTag(args[0])
+	c.unitTag = names.NewUnitTag(args[1])
+	return nil
+}
+
+// Info implements Command.Info.
+func (c *DetachCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "detach",
+		Args:    "<storage id> <unit name>",
+		Purpose: "detaches a storage instance from a unit",
+		Doc:     DetachCommandDoc,
+	}
+}
+
+// Run implements Command.Run.
+func (c *DetachCommand) Run(ctx *cmd.Context) error {
+	api, err := getStorageChangeAPI(&c.StorageCommandBase)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	err = api.DetachStorage(c.storageTag, c.unitTag)
+	return block.ProcessBlockedError(err, block.BlockChange)
+}
+
+var (
+	getStorageChangeAPI = (*StorageCommandBase).getStorageChangeAPI
+)
+
+// StorageChangeAPI defines the API methods that the add and detach
+// commands use.
+type StorageChangeAPI interface {
+	Close() error
+	AddStorage(unit names.UnitTag, storageName string, cons jujustorage.Constraints) error
+	DetachStorage(storageTag names.StorageTag, unit names.UnitTag) error
+}
+
+func (c *StorageCommandBase) getStorageChangeAPI() (StorageChangeAPI, error) {
+	return c.NewStorageAPI()
+}
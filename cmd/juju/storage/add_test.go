@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/storage"
+	_ "github.com/juju/juju/provider/dummy"
+	jujustorage "github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type AddSuite struct {
+	SubStorageSuite
+	mockAPI *mockStorageChangeAPI
+}
+
+var _ = gc.Suite(&AddSuite{})
+
+func (s *AddSuite) SetUpTest(c *gc.C) {
+	s.SubStorageSuite.SetUpTest(c)
+
+	s.mockAPI = &mockStorageChangeAPI{}
+	s.PatchValue(storage.GetStorageChangeAPI, func(*storage.StorageCommandBase) (storage.StorageChangeAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+var addInitErrorTests = []struct {
+	args []string
+	err  string
+}{{
+	args: nil,
+	err:  "no unit specified",
+}, {
+	args: []string{"mysql/0"},
+	err:  "no storage specified",
+}, {
+	args: []string{"mysql", "data"},
+	err:  `invalid unit name "mysql"`,
+}, {
+	args: []string{"mysql/0", "=2"},
+	err:  "no storage specified",
+}, {
+	args: []string{"mysql/0", "data=-1"},
+	err:  `cannot parse constraints for storage "data": cannot parse count: count must be greater than zero, got "-1"`,
+}, {
+	args: []string{"mysql/0", "data", "extra"},
+	err:  `unrecognized args: \["extra"\]`,
+}}
+
+func (s *AddSuite) TestInitErrors(c *gc.C) {
+	for i, t := range addInitErrorTests {
+		c.Logf("test %d: %v", i, t.args)
+		_, err := testing.RunCommand(c, envcmd.Wrap(&storage.AddCommand{}), t.args...)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *AddSuite) TestAdd(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&storage.AddCommand{}), "mysql/0", "data")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, envcmd.Wrap(&storage.AddCommand{}), "mysql/0", "logs=ebs,2,10G")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.calls, jc.DeepEquals, []string{
+		"AddStorage unit-mysql-0 data {Pool: Size:0 Count:1}",
+		"AddStorage unit-mysql-0 logs {Pool:ebs Size:10240 Count:2}",
+	})
+}
+
+func (s *AddSuite) TestAddError(c *gc.C) {
+	s.mockAPI.err = errors.New("boom")
+	_, err := testing.RunCommand(c, envcmd.Wrap(&storage.AddCommand{}), "mysql/0", "data")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *AddSuite) TestDetach(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&storage.DetachCommand{}), "data/0", "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.calls, jc.DeepEquals, []string{
+		"DetachStorage storage-data-0 unit-mysql-0",
+	})
+}
+
+func (s *AddSuite) TestDetachInitErrors(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&storage.DetachCommand{}), "data/0")
+	c.Assert(err, gc.ErrorMatches, "no unit specified")
+	_, err = testing.RunCommand(c, envcmd.Wrap(&storage.DetachCommand{}), "data", "mysql/0")
+	c.Assert(err, gc.ErrorMatches, `invalid storage id "data"`)
+}
+
+type mockStorageChangeAPI struct {
+	calls []string
+	err   error
+}
+
+func (*mockStorageChangeAPI) Close() error {
+	return nil
+}
+
+func (m *mockStorageChangeAPI) AddStorage(unit names.UnitTag, storageName string, cons jujustorage.Constraints) error {
+	m.calls = append(m.calls, fmt.Sprintf("AddStorage %s %s %+v", unit, storageName, cons))
+	return m.err
+}
+
+func (m *mockStorageChangeAPI) DetachStorage(storageTag names.StorageTag, unit names.UnitTag) error {
+	m.calls = append(m.calls, fmt.Sprintf("DetachStorage %s %s", storageTag, unit))
+	return m.err
+}
@@ -4,5 +4,7 @@
 package storage
 
 var (
-	GetStorageShowAPI = &getStorageShowAPI
+	GetStorageShowAPI   = &getStorageShowAPI
+	GetStorageListAPI   = &getStorageListAPI
+	GetStorageChangeAPI = &getStorageChangeAPI
 )
@@ -0,0 +1,190 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+const VolumesCommandDoc = `
+List all of the volumes in the environment.
+
+options:
+-e, --environment (= "")
+   juju environment to operate in
+-o, --output (= "")
+   specify an output
+`
+
+// VolumesCommand lists the volumes in the environment.
+type VolumesCommand struct {
+	StorageCommandBase
+	out cmd.Output
+}
+
+// Init implements Command.Init.
+func (c *VolumesCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// Info implements Command.Info.
+func (c *VolumesCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "volumes",
+		Purpose: "lists volumes",
+		Doc:     VolumesCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *VolumesCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.StorageCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+// VolumeInfo defines the serialization behaviour of volume information.
+type VolumeInfo struct {
+	Volume      string `yaml:"volume" json:"volume"`
+	Storage     string `yaml:"storage,omitempty" json:"storage,omitempty"`
+	VolumeId    string `yaml:"volume-id,omitempty" json:"volume-id,omitempty"`
+	Serial      string `yaml:"serial,omitempty" json:"serial,omitempty"`
+	Size        uint64 `yaml:"size" json:"size"`
+	Life        string `yaml:"life" json:"life"`
+	Provisioned bool   `yaml:"provisioned" json:"provisioned"`
+}
+
+// Run implements Command.Run.
+func (c *VolumesCommand) Run(ctx *cmd.Context) (err error) {
+	api, err := getStorageListAPI(&c.StorageCommandBase)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	volumes, err := api.ListVolumes()
+	if err != nil {
+		return err
+	}
+	output := make([]VolumeInfo, len(volumes))
+	for i, v := range volumes {
+		output[i] = VolumeInfo{
+			Volume:      tagId(v.VolumeTag),
+			Storage:     tagId(v.StorageTag),
+			VolumeId:    v.VolumeId,
+			Serial:      v.Serial,
+			Size:        v.Size,
+			Life:        string(v.Life),
+			Provisioned: v.Provisioned,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+const FilesystemsCommandDoc = `
+List all of the filesystems in the environment.
+
+options:
+-e, --environment (= "")
+   juju environment to operate in
+-o, --output (= "")
+   specify an output
+`
+
+// FilesystemsCommand lists the filesystems in the environment.
+type FilesystemsCommand struct {
+	StorageCommandBase
+	out cmd.Output
+}
+
+// Init implements Command.Init.
+func (c *FilesystemsCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// Info implements Command.Info.
+func (c *FilesystemsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "filesystems",
+		Purpose: "lists filesystems",
+		Doc:     FilesystemsCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *FilesystemsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.StorageCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+// FilesystemInfo defines the serialization behaviour of filesystem
+// information.
+type FilesystemInfo struct {
+	Filesystem   string `yaml:"filesystem" json:"filesystem"`
+	Storage      string `yaml:"storage,omitempty" json:"storage,omitempty"`
+	Volume       string `yaml:"volume,omitempty" json:"volume,omitempty"`
+	FilesystemId string `yaml:"filesystem-id,omitempty" json:"filesystem-id,omitempty"`
+	Size         uint64 `yaml:"size" json:"size"`
+	Life         string `yaml:"life" json:"life"`
+	Provisioned  bool   `yaml:"provisioned" json:"provisioned"`
+}
+
+// Run implements Command.Run.
+func (c *FilesystemsCommand) Run(ctx *cmd.Context) (err error) {
+	api, err := getStorageListAPI(&c.StorageCommandBase)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	filesystems, err := api.ListFilesystems()
+	if err != nil {
+		return err
+	}
+	output := make([]FilesystemInfo, len(filesystems))
+	for i, f := range filesystems {
+		output[i] = FilesystemInfo{
+			Filesystem:   tagId(f.FilesystemTag),
+			Storage:      tagId(f.StorageTag),
+			Volume:       tagId(f.VolumeTag),
+			FilesystemId: f.FilesystemId,
+			Size:         f.Size,
+			Life:         string(f.Life),
+			Provisioned:  f.Provisioned,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+// tagId returns the ID of the entity with the given tag, or the tag
+// itself if it cannot be parsed.
+func tagId(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	t, err := names.ParseTag(tag)
+	if err != nil {
+		return tag
+	}
+	return t.Id()
+}
+
+var (
+	getStorageListAPI = (*StorageCommandBase).getStorageListAPI
+)
+
+// StorageListAPI defines the API methods that the volumes and
+// filesystems commands use.
+type StorageListAPI interface {
+	Close() error
+	ListVolumes() ([]params.VolumeDetails, error)
+	ListFilesystems() ([]params.FilesystemDetails, error)
+}
+
+func (c *StorageCommandBase) getStorageListAPI() (StorageListAPI, error) {
+	return c.NewStorageAPI()
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package storage_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/storage"
+	_ "github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/testing"
+)
+
+type ListSuite struct {
+	SubStorageSuite
+	mockAPI *mockStorageListAPI
+}
+
+var _ = gc.Suite(&ListSuite{})
+
+func (s *ListSuite) SetUpTest(c *gc.C) {
+	s.SubStorageSuite.SetUpTest(c)
+
+	s.mockAPI = &mockStorageListAPI{}
+	s.PatchValue(storage.GetStorageListAPI, func(*storage.StorageCommandBase) (storage.StorageListAPI, error) {
+		return s.mockAPI, nil
+	})
+}
+
+func (s *ListSuite) TestVolumes(c *gc.C) {
+	s.mockAPI.volumes = []params.VolumeDetails{{
+		VolumeTag:   "volume-0",
+		StorageTag:  "storage-data-0",
+		VolumeId:    "vol-abc",
+		Size:        1024,
+		Life:        params.Alive,
+		Provisioned: true,
+	}, {
+		VolumeTag: "volume-1",
+		Size:      2048,
+		Life:      params.Dying,
+	}}
+	context, err := testing.RunCommand(c, envcmd.Wrap(&storage.VolumesCommand{}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, `
+- volume: "0"
+  storage: data/0
+  volume-id: vol-abc
+  size: 1024
+  life: alive
+  provisioned: true
+- volume: "1"
+  size: 2048
+  life: dying
+  provisioned: false
+`[1:])
+}
+
+func (s *ListSuite) TestVolumesArgs(c *gc.C) {
+	_, err := testing.RunCommand(c, envcmd.Wrap(&storage.VolumesCommand{}), "extra")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["extra"\]`)
+}
+
+func (s *ListSuite) TestFilesystemsJSON(c *gc.C) {
+	s.mockAPI.filesystems = []params.FilesystemDetails{{
+		FilesystemTag: "filesystem-0",
+		StorageTag:    "storage-data-0",
+		VolumeTag:     "volume-0",
+		Size:          1024,
+		Life:          params.Alive,
+		Provisioned:   true,
+	}}
+	context, err := testing.RunCommand(c, envcmd.Wrap(&storage.FilesystemsCommand{}), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals,
+		`[{"filesystem":"0","storage":"data/0","volume":"0","size":1024,"life":"alive","provisioned":true}]`+"\n",
+	)
+}
+
+type mockStorageListAPI struct {
+	volumes     []params.VolumeDetails
+	filesystems []params.FilesystemDetails
+}
+
+func (*mockStorageListAPI) Close() error {
+	return nil
+}
+
+func (m *mockStorageListAPI) ListVolumes() ([]params.VolumeDetails, error) {
+	return m.volumes, nil
+}
+
+func (m *mockStorageListAPI) ListFilesystems() ([]params.FilesystemDetails, error) {
+	return m.filesystems, nil
+}
@@ -37,6 +37,10 @@ func NewSuperCommand() cmd.Command {
 				Purpose:     storageCmdPurpose,
 			})}
 	storagecmd.Register(envcmd.Wrap(&ShowCommand{}))
+	storagecmd.Register(envcmd.Wrap(&VolumesCommand{}))
+	storagecmd.Register(envcmd.Wrap(&FilesystemsCommand{}))
+	storagecmd.Register(envcmd.Wrap(&AddCommand{}))
+	storagecmd.Register(envcmd.Wrap(&DetachCommand{}))
 	return &storagecmd
 }
 
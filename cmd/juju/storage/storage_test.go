@@ -13,8 +13,12 @@ import (
 )
 
 var expectedSubCommmandNames = []string{
+	"add",
+	"detach",
+	"filesystems",
 	"help",
 	"show",
+	"volumes",
 }
 
 type storageSuite struct {
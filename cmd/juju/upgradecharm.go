@@ -8,10 +8,13 @@ import (
 	"os"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/environs/config"
@@ -24,7 +27,8 @@ type UpgradeCharmCommand struct {
 	Force       bool
 	RepoPath    string // defaults to JUJU_REPOSITORY
 	SwitchURL   string
-	Revision    int // defaults to -1 (latest)
+	Revision    int    // defaults to -1 (latest)
+	Channel     string // defaults to the channel the service tracks
 }
 
 const upgradeCharmDoc = `
@@ -41,6 +45,12 @@ multiple clients is not supported and may lead to confusing behaviour. Each
 local charm gets uploaded with the revision specified in the charm, if possible,
 otherwise it gets a unique revision (highest in state + 1).
 
+Charm store charms are upgraded to the latest revision published to the
+charm store channel the service tracks: "stable", unless the service
+was deployed or last upgraded with the --channel flag. The --channel
+flag upgrades to the latest revision in the given channel instead, and
+the service tracks that channel from then on.
+
 The --switch flag allows you to replace the charm with an entirely different
 one. The new charm's URL and revision are inferred as they would be when running
 a deploy command.
@@ -79,6 +89,7 @@ func (c *UpgradeCharmCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.RepoPath, "repository", os.Getenv("JUJU_REPOSITORY"), "local charm repository path")
 	f.StringVar(&c.SwitchURL, "switch", "", "crossgrade to a different charm")
 	f.IntVar(&c.Revision, "revision", -1, "explicit revision of current charm")
+	f.StringVar(&c.Channel, "channel", "", "charm store channel to upgrade from and track from then on")
 }
 
 func (c *UpgradeCharmCommand) Init(args []string) error {
@@ -96,6 +107,9 @@ func (c *UpgradeCharmCommand) Init(args []string) error {
 	if c.SwitchURL != "" && c.Revision != -1 {
 		return fmt.Errorf("--switch and --revision are mutually exclusive")
 	}
+	if c.Channel != "" && c.Revision != -1 {
+		return fmt.Errorf("--channel and --revision are mutually exclusive")
+	}
 	return nil
 }
 
@@ -131,6 +145,10 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		// No new URL specified, but revision might have been.
 		newURL = oldURL.WithRevision(c.Revision)
 	}
+	channel, err := c.upgradeChannel(client, newURL)
+	if err != nil {
+		return err
+	}
 
 	repo, err := charm.InferRepository(newURL.Reference(), ctx.AbsPath(c.RepoPath))
 	if err != nil {
@@ -143,11 +161,18 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 	explicitRevision := true
 	if newURL.Revision == -1 {
 		explicitRevision = false
-		latest, err := charm.Latest(repo, newURL)
-		if err != nil {
-			return err
+		if channel != "" {
+			newURL, err = resolveCharmChannelURL(newURL.String(), channel, client, conf)
+			if err != nil {
+				return err
+			}
+		} else {
+			latest, err := charm.Latest(repo, newURL)
+			if err != nil {
+				return err
+			}
+			newURL = newURL.WithRevision(latest)
 		}
-		newURL = newURL.WithRevision(latest)
 	}
 	if *newURL == *oldURL {
 		if explicitRevision {
@@ -165,5 +190,40 @@ func (c *UpgradeCharmCommand) Run(ctx *cmd.Context) error {
 		return block.ProcessBlockedError(err, block.BlockChange)
 	}
 
+	if c.Channel != "" {
+		err = client.ServiceSetCharmWithChannel(c.ServiceName, addedURL.String(), c.Force, c.Channel)
+		if params.IsCodeNotImplemented(err) {
+			return errors.New("cannot use --channel: not supported by the API server")
+		}
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
 	return block.ProcessBlockedError(client.ServiceSetCharm(c.ServiceName, addedURL.String(), c.Force), block.BlockChange)
 }
+
+// upgradeChannel returns the charm store channel from which the state
+// server resolves the latest revision of the given charm, or "" if it
+// is resolved with the charm repository instead. Charm store charms
+// are upgraded from the channel given with --channel, or else from the
+// channel the service tracks, unless that is the stable channel.
+func (c *UpgradeCharmCommand) upgradeChannel(client *api.Client, curl *charm.URL) (string, error) {
+	if c.Channel != "" {
+		if curl.Schema != "cs" {
+			return "", fmt.Errorf("cannot use --channel with %q: only charm store charms have channels", curl)
+		}
+		return c.Channel, nil
+	}
+	if curl.Schema != "cs" || c.SwitchURL != "" {
+		return "", nil
+	}
+	channel, err := client.ServiceGetCharmChannel(c.ServiceName)
+	if params.IsCodeNotImplemented(err) {
+		// Older state servers do not track channels.
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if channel == "stable" {
+		return "", nil
+	}
+	return channel, nil
+}
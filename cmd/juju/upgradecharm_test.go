@@ -90,6 +90,18 @@ func (s *UpgradeCharmErrorsSuite) TestSwitchAndRevisionFails(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "--switch and --revision are mutually exclusive")
 }
 
+func (s *UpgradeCharmErrorsSuite) TestChannelAndRevisionFails(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--channel=edge", "--revision=2")
+	c.Assert(err, gc.ErrorMatches, "--channel and --revision are mutually exclusive")
+}
+
+func (s *UpgradeCharmErrorsSuite) TestChannelWithLocalCharmFails(c *gc.C) {
+	s.deployService(c)
+	err := runUpgradeCharm(c, "riak", "--channel=edge")
+	c.Assert(err, gc.ErrorMatches, `cannot use --channel with "local:trusty/riak": only charm store charms have channels`)
+}
+
 func (s *UpgradeCharmErrorsSuite) TestInvalidRevision(c *gc.C) {
 	s.deployService(c)
 	err := runUpgradeCharm(c, "riak", "--revision=blah")
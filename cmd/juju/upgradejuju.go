@@ -34,6 +34,7 @@ type UpgradeJujuCommand struct {
 	DryRun        bool
 	ResetPrevious bool
 	AssumeYes     bool
+	Force         bool
 	Series        []string
 }
 
@@ -74,7 +75,11 @@ progress. It will also abort if a previous upgrade was partially
 completed - this can happen if one of the state servers in a high
 availability environment failed to upgrade. If a failed upgrade has
 been resolved, the --reset-previous-upgrade flag can be used to reset
-the environment's upgrade tracking state, allowing further upgrades.`
+the environment's upgrade tracking state, allowing further upgrades.
+
+Before starting an upgrade, the state server checks that the environment
+is fit to be upgraded; for example, it will refuse while any agents are
+in an error state. The --force flag skips these checks.`
 
 func (c *UpgradeJujuCommand) Info() *cmd.Info {
 	return &cmd.Info{
@@ -91,6 +96,7 @@ func (c *UpgradeJujuCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.ResetPrevious, "reset-previous-upgrade", false, "clear the previous (incomplete) upgrade status (use with care)")
 	f.BoolVar(&c.AssumeYes, "y", false, "answer 'yes' to confirmation prompts")
 	f.BoolVar(&c.AssumeYes, "yes", false, "")
+	f.BoolVar(&c.Force, "force", false, "upgrade even if the environment fails its upgrade prechecks")
 	f.Var(newSeriesValue(nil, &c.Series), "series", "upload tools for supplied comma-separated series list (OBSOLETE)")
 }
 
@@ -136,6 +142,7 @@ type upgradeJujuAPI interface {
 	UploadTools(r io.Reader, vers version.Binary, additionalSeries ...string) (*coretools.Tools, error)
 	AbortCurrentUpgrade() error
 	SetEnvironAgentVersion(version version.Number) error
+	ForceSetEnvironAgentVersion(version version.Number) error
 	Close() error
 }
 
@@ -200,13 +207,22 @@ func (c *UpgradeJujuCommand) Run(ctx *cmd.Context) (err error) {
 				return block.ProcessBlockedError(err, block.BlockChange)
 			}
 		}
-		if err := client.SetEnvironAgentVersion(context.chosen); err != nil {
+		setVersion := client.SetEnvironAgentVersion
+		if c.Force {
+			setVersion = client.ForceSetEnvironAgentVersion
+		}
+		if err := setVersion(context.chosen); err != nil {
 			if params.IsCodeUpgradeInProgress(err) {
 				return errors.Errorf("%s\n\n"+
 					"Please wait for the upgrade to complete or if there was a problem with\n"+
 					"the last upgrade that has been resolved, consider running the\n"+
 					"upgrade-juju command with the --reset-previous-upgrade flag.", err,
 				)
+			} else if params.IsCodePrecheckFailed(err) {
+				return errors.Errorf("%s\n\n"+
+					"Resolve the problems above, or run the upgrade-juju command\n"+
+					"with the --force flag to upgrade regardless.", err,
+				)
 			} else {
 				return block.ProcessBlockedError(err, block.BlockChange)
 			}
@@ -564,6 +564,44 @@ func (s *UpgradeJujuSuite) TestUpgradeInProgress(c *gc.C) {
 	)
 }
 
+func (s *UpgradeJujuSuite) TestUpgradePrecheckFailed(c *gc.C) {
+	fakeAPI := NewFakeUpgradeJujuAPI(c, s.State)
+	fakeAPI.setVersionErr = &params.Error{
+		Message: "upgrade prechecks failed:\nenvironment-foo: machine 1 agent is in error: oops",
+		Code:    params.CodePrecheckFailed,
+	}
+	fakeAPI.patch(s)
+	cmd := &UpgradeJujuCommand{}
+	err := coretesting.InitCommand(envcmd.Wrap(cmd), []string{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = cmd.Run(coretesting.Context(c))
+	c.Assert(err, gc.ErrorMatches, "upgrade prechecks failed:\n"+
+		"environment-foo: machine 1 agent is in error: oops\n"+
+		"\n"+
+		"Resolve the problems above, or run the upgrade-juju command\n"+
+		"with the --force flag to upgrade regardless.",
+	)
+	c.Assert(fakeAPI.setVersionForced, jc.IsFalse)
+}
+
+func (s *UpgradeJujuSuite) TestUpgradeForced(c *gc.C) {
+	fakeAPI := NewFakeUpgradeJujuAPI(c, s.State)
+	fakeAPI.setVersionErr = &params.Error{
+		Message: "upgrade prechecks failed",
+		Code:    params.CodePrecheckFailed,
+	}
+	fakeAPI.patch(s)
+	cmd := &UpgradeJujuCommand{}
+	err := coretesting.InitCommand(envcmd.Wrap(cmd), []string{"--force"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = cmd.Run(coretesting.Context(c))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fakeAPI.setVersionForced, jc.IsTrue)
+	c.Assert(fakeAPI.setVersionCalledWith, gc.Equals, fakeAPI.nextVersion.Number)
+}
+
 func (s *UpgradeJujuSuite) TestBlockUpgradeInProgress(c *gc.C) {
 	fakeAPI := NewFakeUpgradeJujuAPI(c, s.State)
 	fakeAPI.setVersionErr = common.ErrOperationBlocked("The operation has been blocked.")
@@ -653,12 +691,14 @@ type fakeUpgradeJujuAPI struct {
 	setVersionErr             error
 	abortCurrentUpgradeCalled bool
 	setVersionCalledWith      version.Number
+	setVersionForced          bool
 }
 
 func (a *fakeUpgradeJujuAPI) reset() {
 	a.setVersionErr = nil
 	a.abortCurrentUpgradeCalled = false
 	a.setVersionCalledWith = version.Number{}
+	a.setVersionForced = false
 }
 
 func (a *fakeUpgradeJujuAPI) patch(s *UpgradeJujuSuite) {
@@ -701,6 +741,12 @@ func (a *fakeUpgradeJujuAPI) SetEnvironAgentVersion(v version.Number) error {
 	return a.setVersionErr
 }
 
+func (a *fakeUpgradeJujuAPI) ForceSetEnvironAgentVersion(v version.Number) error {
+	a.setVersionCalledWith = v
+	a.setVersionForced = true
+	return nil
+}
+
 func (a *fakeUpgradeJujuAPI) Close() error {
 	return nil
 }
@@ -0,0 +1,28 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+// NewPrepareCommand returns a PrepareCommand with the api provided as
+// specified.
+func NewPrepareCommand(api UpgradeSeriesAPI) *PrepareCommand {
+	return &PrepareCommand{
+		upgradeSeriesCommandBase: upgradeSeriesCommandBase{api: api},
+	}
+}
+
+// NewCompleteCommand returns a CompleteCommand with the api provided
+// as specified.
+func NewCompleteCommand(api UpgradeSeriesAPI) *CompleteCommand {
+	return &CompleteCommand{
+		upgradeSeriesCommandBase: upgradeSeriesCommandBase{api: api},
+	}
+}
+
+// NewStatusCommand returns a StatusCommand with the api provided as
+// specified.
+func NewStatusCommand(api UpgradeSeriesAPI) *StatusCommand {
+	return &StatusCommand{
+		upgradeSeriesCommandBase: upgradeSeriesCommandBase{api: api},
+	}
+}
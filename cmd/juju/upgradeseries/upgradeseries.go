@@ -0,0 +1,254 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/upgradeseries"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/block"
+)
+
+const upgradeSeriesCommandDoc = `
+"juju upgrade-series" upgrades the operating system series of a machine in
+a controlled way, so that the charms on it and the Juju agents running it
+can adapt to the new series.
+
+An upgrade goes through the following steps:
+	1. "juju upgrade-series prepare <machine> <series>" runs the
+	   pre-series-upgrade hook of each unit on the machine.
+	2. The operator upgrades the machine's operating system, for
+	   example with do-release-upgrade.
+	3. "juju upgrade-series complete <machine>" adjusts the machine's
+	   agents to the new series, including the init system that runs
+	   them, then runs the post-series-upgrade hook of each unit on
+	   the machine. The machine's series is then changed.
+
+The progress of an upgrade is shown by "juju upgrade-series status".
+`
+
+const upgradeSeriesCommandPurpose = "upgrade the series of machines"
+
+// NewSuperCommand creates the upgrade-series supercommand and
+// registers the subcommands that it supports.
+func NewSuperCommand() cmd.Command {
+	upgradeSeriesCmd := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:        "upgrade-series",
+		Doc:         upgradeSeriesCommandDoc,
+		UsagePrefix: "juju",
+		Purpose:     upgradeSeriesCommandPurpose,
+	})
+	upgradeSeriesCmd.Register(envcmd.Wrap(&PrepareCommand{}))
+	upgradeSeriesCmd.Register(envcmd.Wrap(&CompleteCommand{}))
+	upgradeSeriesCmd.Register(envcmd.Wrap(&StatusCommand{}))
+	return upgradeSeriesCmd
+}
+
+// UpgradeSeriesAPI defines the API methods used by the upgrade-series
+// commands.
+type UpgradeSeriesAPI interface {
+	Close() error
+	Prepare(machineId, series string) error
+	Complete(machineId string) error
+	MachineStatus(machineId string) (params.UpgradeSeriesStatus, error)
+}
+
+// upgradeSeriesCommandBase is embedded by the upgrade-series commands.
+type upgradeSeriesCommandBase struct {
+	envcmd.EnvCommandBase
+	api       UpgradeSeriesAPI
+	MachineId string
+}
+
+func (c *upgradeSeriesCommandBase) getAPI() (UpgradeSeriesAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return upgradeseries.NewClient(root), nil
+}
+
+// initMachine consumes the machine argument, which must come first.
+func (c *upgradeSeriesCommandBase) initMachine(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, errors.Errorf("no machine specified")
+	}
+	if !names.IsValidMachine(args[0]) {
+		return nil, errors.Errorf("invalid machine %q", args[0])
+	}
+	c.MachineId = args[0]
+	return args[1:], nil
+}
+
+// PrepareCommand prepares a machine for an upgrade of its series.
+type PrepareCommand struct {
+	upgradeSeriesCommandBase
+	Series string
+}
+
+const prepareDoc = `
+The prepare command starts an upgrade of the machine to the given series,
+which must be a later release of the same operating system. Each unit on the
+machine runs its pre-series-upgrade hook; once every unit has done so, as
+shown by "juju upgrade-series status", the machine's operating system may be
+upgraded.
+
+Example:
+	$ juju upgrade-series prepare 2 trusty
+`
+
+func (c *PrepareCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "prepare",
+		Args:    "<machine> <series>",
+		Purpose: "prepare a machine for an upgrade of its series",
+		Doc:     prepareDoc,
+	}
+}
+
+func (c *PrepareCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errors.Errorf("no series specified")
+	}
+	c.Series, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *PrepareCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.Prepare(c.MachineId, c.Series); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("preparing machine %s for upgrade to series %q", c.MachineId, c.Series)
+	return nil
+}
+
+// CompleteCommand completes an upgrade of a machine's series, once
+// its operating system has been upgraded.
+type CompleteCommand struct {
+	upgradeSeriesCommandBase
+}
+
+const completeDoc = `
+The complete command records that the machine's operating system has been
+upgraded to the series given to "juju upgrade-series prepare". The machine's
+agents adjust to the new series, then each unit on the machine runs its
+post-series-upgrade hook. Once every unit has done so, the machine's series
+is changed.
+
+Example:
+	$ juju upgrade-series complete 2
+`
+
+func (c *CompleteCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "complete",
+		Args:    "<machine>",
+		Purpose: "complete an upgrade of a machine's series",
+		Doc:     completeDoc,
+	}
+}
+
+func (c *CompleteCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *CompleteCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.Complete(c.MachineId); err != nil {
+		return block.ProcessBlockedError(err, block.BlockChange)
+	}
+	ctx.Infof("completing series upgrade of machine %s", c.MachineId)
+	return nil
+}
+
+// StatusCommand shows the progress of an upgrade of a machine's
+// series.
+type StatusCommand struct {
+	upgradeSeriesCommandBase
+	out cmd.Output
+}
+
+const statusDoc = `
+The status command shows the progress of an upgrade of the machine's series,
+and the series it is being upgraded to.
+
+Example:
+	$ juju upgrade-series status 2
+`
+
+// UpgradeSeriesStatus defines the serialization of the progress of an
+// upgrade of a machine's series.
+type UpgradeSeriesStatus struct {
+	Status string `yaml:"status" json:"status"`
+	Series string `yaml:"series,omitempty" json:"series,omitempty"`
+}
+
+func (c *StatusCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "status",
+		Args:    "<machine>",
+		Purpose: "show the progress of an upgrade of a machine's series",
+		Doc:     statusDoc,
+	}
+}
+
+func (c *StatusCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *StatusCommand) Init(args []string) error {
+	args, err := c.initMachine(args)
+	if err != nil {
+		return err
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func (c *StatusCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	status, err := client.MachineStatus(c.MachineId)
+	if err != nil {
+		return err
+	}
+	result := UpgradeSeriesStatus{
+		Status: status.Status,
+		Series: status.Series,
+	}
+	if result.Status == "" {
+		result.Status = "not started"
+	}
+	return c.out.Write(ctx, result)
+}
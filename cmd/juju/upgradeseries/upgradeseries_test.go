@@ -0,0 +1,143 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries_test
+
+import (
+	"strings"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/upgradeseries"
+	"github.com/juju/juju/testing"
+)
+
+type UpgradeSeriesSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeUpgradeSeriesAPI
+}
+
+var _ = gc.Suite(&UpgradeSeriesSuite{})
+
+func (s *UpgradeSeriesSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeUpgradeSeriesAPI{}
+}
+
+func (s *UpgradeSeriesSuite) TestPrepareInit(c *gc.C) {
+	for i, test := range []struct {
+		args        []string
+		machineId   string
+		series      string
+		errorString string
+	}{{
+		errorString: "no machine specified",
+	}, {
+		args:        []string{"2"},
+		errorString: "no series specified",
+	}, {
+		args:        []string{"foo", "trusty"},
+		errorString: `invalid machine "foo"`,
+	}, {
+		args:      []string{"2", "trusty"},
+		machineId: "2",
+		series:    "trusty",
+	}, {
+		args:        []string{"2", "trusty", "utopic"},
+		errorString: `unrecognized args: \["utopic"\]`,
+	}} {
+		c.Logf("test %d", i)
+		prepareCmd := &upgradeseries.PrepareCommand{}
+		err := testing.InitCommand(prepareCmd, test.args)
+		if test.errorString == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(prepareCmd.MachineId, gc.Equals, test.machineId)
+			c.Check(prepareCmd.Series, gc.Equals, test.series)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errorString)
+		}
+	}
+}
+
+func (s *UpgradeSeriesSuite) TestPrepare(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewPrepareCommand(s.fake)), "2", "trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"Prepare 2 trusty"})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "preparing machine 2 for upgrade to series \"trusty\"\n")
+}
+
+func (s *UpgradeSeriesSuite) TestPrepareError(c *gc.C) {
+	s.fake.err = errors.New(`cannot prepare series upgrade of machine 2: machine is already running series "trusty"`)
+	_, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewPrepareCommand(s.fake)), "2", "trusty")
+	c.Assert(err, gc.ErrorMatches, `cannot prepare series upgrade of machine 2: machine is already running series "trusty"`)
+}
+
+func (s *UpgradeSeriesSuite) TestPrepareBlocked(c *gc.C) {
+	s.fake.err = common.ErrOperationBlocked("TestPrepareBlocked")
+	_, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewPrepareCommand(s.fake)), "2", "trusty")
+	c.Assert(err, gc.Equals, cmd.ErrSilent)
+	// msg is logged
+	stripped := strings.Replace(c.GetTestLog(), "\n", "", -1)
+	c.Check(stripped, gc.Matches, ".*TestPrepareBlocked.*")
+}
+
+func (s *UpgradeSeriesSuite) TestCompleteInit(c *gc.C) {
+	completeCmd := &upgradeseries.CompleteCommand{}
+	err := testing.InitCommand(completeCmd, nil)
+	c.Assert(err, gc.ErrorMatches, "no machine specified")
+	err = testing.InitCommand(completeCmd, []string{"2", "3"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["3"\]`)
+}
+
+func (s *UpgradeSeriesSuite) TestComplete(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewCompleteCommand(s.fake)), "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.calls, jc.DeepEquals, []string{"Complete 2"})
+	c.Assert(testing.Stderr(ctx), gc.Equals, "completing series upgrade of machine 2\n")
+}
+
+func (s *UpgradeSeriesSuite) TestStatus(c *gc.C) {
+	s.fake.status = params.UpgradeSeriesStatus{Status: "prepare started", Series: "trusty"}
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewStatusCommand(s.fake)), "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+status: prepare started
+series: trusty
+`[1:])
+}
+
+func (s *UpgradeSeriesSuite) TestStatusNotStarted(c *gc.C) {
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(upgradeseries.NewStatusCommand(s.fake)), "2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "status: not started\n")
+}
+
+type fakeUpgradeSeriesAPI struct {
+	calls  []string
+	status params.UpgradeSeriesStatus
+	err    error
+}
+
+func (f *fakeUpgradeSeriesAPI) Close() error {
+	return nil
+}
+
+func (f *fakeUpgradeSeriesAPI) Prepare(machineId, series string) error {
+	f.calls = append(f.calls, "Prepare "+machineId+" "+series)
+	return f.err
+}
+
+func (f *fakeUpgradeSeriesAPI) Complete(machineId string) error {
+	f.calls = append(f.calls, "Complete "+machineId)
+	return f.err
+}
+
+func (f *fakeUpgradeSeriesAPI) MachineStatus(machineId string) (params.UpgradeSeriesStatus, error) {
+	return f.status, f.err
+}
@@ -0,0 +1,272 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/stability"
+	"github.com/juju/juju/api/waitfor"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const waitForDoc = `
+Wait until the environment is stable, or until a condition on a unit,
+service or machine holds. If the wait does not succeed before the
+timeout elapses, the command fails, so that scripts can wait for a
+deployment to settle without polling status.
+
+The environment is stable when every machine has been provisioned,
+every machine and unit agent is connected and idle, and no unit has
+hooks waiting to run or a failed hook to resolve. If it is not stable
+in time, the reasons are shown. A timeout of 0 checks the environment
+once without waiting. "model" is accepted as a synonym for
+"environment".
+
+A condition consists of one or more clauses of the form
+"<field> <operator> <value>", separated by "&&". String and boolean
+fields may be compared with == and !=, counts also with <, <=, > and
+>=, and lists tested with "has". The clause "exists == false" waits
+for an entity to be removed. While waiting, the clauses that do not
+yet hold are shown whenever they change.
+
+Unit fields:
+    status            agent status, as shown by "juju status"
+    machine           machine the unit is assigned to
+    public-address
+    private-address
+
+Service fields ("application" is accepted as a synonym for "service"):
+    life
+    status            service status
+    exposed           true or false
+    charm             charm URL
+    units             number of units
+    started-units     number of units whose agent status is started
+
+Machine fields:
+    life
+    status            agent status
+    instance-id
+    series
+    addresses         list of addresses
+    networks          list of names of the networks of the addresses
+
+Examples:
+
+    juju wait-for environment --timeout 30m
+    juju wait-for service wordpress "status == running && started-units >= 3"
+    juju wait-for unit mysql/0 status == started
+    juju wait-for machine 1 "networks has db"
+`
+
+// defaultWaitForTimeout holds how long the wait-for command waits
+// when no timeout is given.
+const defaultWaitForTimeout = 10 * time.Minute
+
+// WaitForCommand waits for the environment to become stable, or for a
+// condition on an entity to hold.
+type WaitForCommand struct {
+	envcmd.EnvCommandBase
+	timeout time.Duration
+
+	// tag and condition are set when waiting for a condition on an
+	// entity, rather than for the environment to become stable.
+	tag       names.Tag
+	condition string
+}
+
+// Info implements Command.Info.
+func (c *WaitForCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "wait-for",
+		Args:    "environment | (unit|service|machine) <name> <condition>",
+		Purpose: "wait for the environment to become stable, or for a condition to hold",
+		Doc:     waitForDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *WaitForCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.DurationVar(&c.timeout, "timeout", defaultWaitForTimeout, "how long to wait")
+}
+
+// Init implements Command.Init.
+func (c *WaitForCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("nothing specified to wait for")
+	}
+	if c.timeout < 0 {
+		return errors.Errorf("invalid timeout %v", c.timeout)
+	}
+	kind, args := args[0], args[1:]
+	switch kind {
+	case "environment", "model":
+		return cmd.CheckEmpty(args)
+	case "unit", "service", "application", "machine":
+	default:
+		return errors.Errorf("cannot wait for %q", kind)
+	}
+	if len(args) == 0 {
+		return errors.Errorf("no %s specified", kind)
+	}
+	name := args[0]
+	switch kind {
+	case "unit":
+		if !names.IsValidUnit(name) {
+			return errors.Errorf("invalid unit name %q", name)
+		}
+		c.tag = names.NewUnitTag(name)
+	case "service", "application":
+		if !names.IsValidService(name) {
+			return errors.Errorf("invalid service name %q", name)
+		}
+		c.tag = names.NewServiceTag(name)
+	case "machine":
+		if !names.IsValidMachine(name) {
+			return errors.Errorf("invalid machine id %q", name)
+		}
+		c.tag = names.NewMachineTag(name)
+	}
+	c.condition = strings.Join(args[1:], " ")
+	if c.condition == "" {
+		return errors.New("no condition specified")
+	}
+	return nil
+}
+
+// WaitForAPI defines the API methods used by the wait-for command to
+// wait for the environment to become stable.
+type WaitForAPI interface {
+	Close() error
+	WaitForStability(timeout time.Duration) (params.StabilityResult, error)
+}
+
+var getWaitForAPI = func(c *WaitForCommand) (WaitForAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return stability.NewClient(root), nil
+}
+
+// ConditionWatcher reports the evaluation of an entity condition
+// whenever it changes.
+type ConditionWatcher interface {
+	Next() (params.ConditionResult, error)
+	Stop() error
+}
+
+// WaitForConditionAPI defines the API methods used by the wait-for
+// command to wait for a condition on an entity.
+type WaitForConditionAPI interface {
+	Close() error
+	WatchCondition(tag names.Tag, condition string) (ConditionWatcher, error)
+}
+
+var getWaitForConditionAPI = func(c *WaitForCommand) (WaitForConditionAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API connection")
+	}
+	return waitForConditionAPI{waitfor.NewClient(root)}, nil
+}
+
+// waitForConditionAPI adapts a waitfor.Client to WaitForConditionAPI.
+type waitForConditionAPI struct {
+	*waitfor.Client
+}
+
+func (api waitForConditionAPI) WatchCondition(tag names.Tag, condition string) (ConditionWatcher, error) {
+	w, err := api.Client.WatchCondition(tag, condition)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Run implements Command.Run.
+func (c *WaitForCommand) Run(ctx *cmd.Context) error {
+	if c.tag != nil {
+		return c.waitForCondition(ctx)
+	}
+	api, err := getWaitForAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	result, err := api.WaitForStability(c.timeout)
+	if err != nil {
+		return err
+	}
+	if result.Stable {
+		ctx.Infof("environment is stable")
+		return nil
+	}
+	for _, reason := range result.Reasons {
+		fmt.Fprintln(ctx.Stderr, reason)
+	}
+	return errors.Errorf("environment not stable after %v", c.timeout)
+}
+
+// waitForCondition waits until the command's condition holds, showing
+// the clauses that do not hold whenever they change.
+func (c *WaitForCommand) waitForCondition(ctx *cmd.Context) error {
+	api, err := getWaitForConditionAPI(c)
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+
+	w, err := api.WatchCondition(c.tag, c.condition)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	done := make(chan struct{})
+	defer close(done)
+	results := make(chan params.ConditionResult)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			result, err := w.Next()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case results <- result:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	entity := fmt.Sprintf("%s %s", c.tag.Kind(), c.tag.Id())
+	timeout := time.After(c.timeout)
+	for {
+		select {
+		case result := <-results:
+			if result.Satisfied {
+				ctx.Infof("%s: condition satisfied", entity)
+				return nil
+			}
+			ctx.Infof("%s: waiting for %s", entity, strings.Join(result.Unsatisfied, ", "))
+		case err := <-errs:
+			return err
+		case <-timeout:
+			return errors.Errorf("%s: condition not satisfied after %v", entity, c.timeout)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type WaitForSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeWaitForAPI
+}
+
+var _ = gc.Suite(&WaitForSuite{})
+
+type fakeWaitForAPI struct {
+	timeout time.Duration
+	result  params.StabilityResult
+	err     error
+}
+
+func (f *fakeWaitForAPI) Close() error {
+	return nil
+}
+
+func (f *fakeWaitForAPI) WaitForStability(timeout time.Duration) (params.StabilityResult, error) {
+	f.timeout = timeout
+	return f.result, f.err
+}
+
+func (s *WaitForSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeWaitForAPI{timeout: -1}
+	s.PatchValue(&getWaitForAPI, func(*WaitForCommand) (WaitForAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *WaitForSuite) TestStable(c *gc.C) {
+	s.api.result = params.StabilityResult{Stable: true}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), "environment", "--timeout", "30m")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.timeout, gc.Equals, 30*time.Minute)
+	c.Assert(coretesting.Stdout(ctx), gc.Equals, "")
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, "environment is stable\n")
+}
+
+func (s *WaitForSuite) TestDefaultTimeout(c *gc.C) {
+	s.api.result = params.StabilityResult{Stable: true}
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), "model")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.timeout, gc.Equals, 10*time.Minute)
+}
+
+func (s *WaitForSuite) TestNotStable(c *gc.C) {
+	s.api.result = params.StabilityResult{Reasons: []string{
+		"machine 1 is not provisioned",
+		`unit mysql/0 has failed "install" hook`,
+	}}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), "environment", "--timeout", "0")
+	c.Assert(err, gc.ErrorMatches, "environment not stable after 0s?")
+	c.Assert(s.api.timeout, gc.Equals, time.Duration(0))
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, ""+
+		"machine 1 is not provisioned\n"+
+		"unit mysql/0 has failed \"install\" hook\n",
+	)
+}
+
+func (s *WaitForSuite) TestAPIError(c *gc.C) {
+	s.api.err = errors.New("boom")
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), "environment")
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *WaitForSuite) TestInit(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "nothing specified to wait for",
+	}, {
+		args: []string{"relation"},
+		err:  `cannot wait for "relation"`,
+	}, {
+		args: []string{"environment", "foo"},
+		err:  `unrecognized args: \["foo"\]`,
+	}, {
+		args: []string{"environment", "--timeout", "-1m"},
+		err:  "invalid timeout -1m0s",
+	}, {
+		args: []string{"unit"},
+		err:  "no unit specified",
+	}, {
+		args: []string{"unit", "mysql"},
+		err:  `invalid unit name "mysql"`,
+	}, {
+		args: []string{"service", "mysql/0", "exists", "==", "true"},
+		err:  `invalid service name "mysql/0"`,
+	}, {
+		args: []string{"machine", "foo", "exists", "==", "true"},
+		err:  `invalid machine id "foo"`,
+	}, {
+		args: []string{"machine", "0"},
+		err:  "no condition specified",
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		_, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), t.args...)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+type fakeConditionWatcher struct {
+	results chan params.ConditionResult
+	stopped chan struct{}
+}
+
+func (w *fakeConditionWatcher) Next() (params.ConditionResult, error) {
+	select {
+	case result := <-w.results:
+		return result, nil
+	case <-w.stopped:
+		return params.ConditionResult{}, errors.New("watcher stopped")
+	}
+}
+
+func (w *fakeConditionWatcher) Stop() error {
+	close(w.stopped)
+	return nil
+}
+
+type fakeWaitForConditionAPI struct {
+	tag       names.Tag
+	condition string
+	watcher   *fakeConditionWatcher
+	err       error
+}
+
+func (f *fakeWaitForConditionAPI) Close() error {
+	return nil
+}
+
+func (f *fakeWaitForConditionAPI) WatchCondition(tag names.Tag, condition string) (ConditionWatcher, error) {
+	f.tag = tag
+	f.condition = condition
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.watcher, nil
+}
+
+type WaitForConditionSuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeWaitForConditionAPI
+}
+
+var _ = gc.Suite(&WaitForConditionSuite{})
+
+func (s *WaitForConditionSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeWaitForConditionAPI{
+		watcher: &fakeConditionWatcher{
+			results: make(chan params.ConditionResult, 10),
+			stopped: make(chan struct{}),
+		},
+	}
+	s.PatchValue(&getWaitForConditionAPI, func(*WaitForCommand) (WaitForConditionAPI, error) {
+		return s.api, nil
+	})
+}
+
+func (s *WaitForConditionSuite) TestSatisfied(c *gc.C) {
+	s.api.watcher.results <- params.ConditionResult{
+		Unsatisfied: []string{"started-units >= 3 (started-units is 1)"},
+	}
+	s.api.watcher.results <- params.ConditionResult{Satisfied: true}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}),
+		"application", "wordpress", "status", "==", "running", "&&", "started-units", ">=", "3",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.tag, gc.Equals, names.NewServiceTag("wordpress"))
+	c.Assert(s.api.condition, gc.Equals, "status == running && started-units >= 3")
+	c.Assert(coretesting.Stderr(ctx), gc.Equals, ""+
+		"service wordpress: waiting for started-units >= 3 (started-units is 1)\n"+
+		"service wordpress: condition satisfied\n",
+	)
+	select {
+	case <-s.api.watcher.stopped:
+	default:
+		c.Fatalf("watcher not stopped")
+	}
+}
+
+func (s *WaitForConditionSuite) TestTimeout(c *gc.C) {
+	s.api.watcher.results <- params.ConditionResult{
+		Unsatisfied: []string{`status == started (status is "pending")`},
+	}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}),
+		"unit", "mysql/0", "status == started", "--timeout", "100ms",
+	)
+	c.Assert(err, gc.ErrorMatches, "unit mysql/0: condition not satisfied after 100ms")
+	c.Assert(s.api.tag, gc.Equals, names.NewUnitTag("mysql/0"))
+	c.Assert(coretesting.Stderr(ctx), gc.Equals,
+		"unit mysql/0: waiting for status == started (status is \"pending\")\n",
+	)
+}
+
+func (s *WaitForConditionSuite) TestWatchError(c *gc.C) {
+	s.api.err = errors.New(`clause "networks" not valid`)
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&WaitForCommand{}), "machine", "1", "networks")
+	c.Assert(err, gc.ErrorMatches, `clause "networks" not valid`)
+	c.Assert(s.api.tag, gc.Equals, names.NewMachineTag("1"))
+}
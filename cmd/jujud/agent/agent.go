@@ -12,8 +12,10 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"github.com/juju/names"
 	"github.com/juju/utils"
+	"github.com/juju/utils/proxy"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/agent"
@@ -102,6 +104,14 @@ func (a *AgentConf) SetAPIHostPorts(servers [][]network.HostPort) error {
 	})
 }
 
+// SetCACert satisfies worker/cacertupdater/CACertSetter.
+func (a *AgentConf) SetCACert(caCert string) error {
+	return a.ChangeConfig(func(c agent.ConfigSetter) error {
+		c.SetCACert(caCert)
+		return nil
+	})
+}
+
 // SetStateServingInfo satisfies worker/certupdater/SetStateServingInfo.
 func (a *AgentConf) SetStateServingInfo(info params.StateServingInfo) error {
 	return a.ChangeConfig(func(c agent.ConfigSetter) error {
@@ -110,6 +120,48 @@ func (a *AgentConf) SetStateServingInfo(info params.StateServingInfo) error {
 	})
 }
 
+// SetAgentConfigSettings satisfies
+// worker/agentconfigupdater/AgentConfigSettingsSetter.
+func (a *AgentConf) SetAgentConfigSettings(settings params.AgentConfigSettings) error {
+	return a.ChangeConfig(agentConfigSettingsMutator(settings))
+}
+
+// agentConfigSettingsMutator returns an AgentConfigMutator that records
+// the given settings in the agent's configuration.
+func agentConfigSettingsMutator(settings params.AgentConfigSettings) AgentConfigMutator {
+	return func(c agent.ConfigSetter) error {
+		c.SetValue(agent.LoggingConfig, settings.LoggingConfig)
+		c.SetValue(agent.HTTPProxy, settings.Proxy.Http)
+		c.SetValue(agent.HTTPSProxy, settings.Proxy.Https)
+		c.SetValue(agent.FTPProxy, settings.Proxy.Ftp)
+		c.SetValue(agent.NoProxy, settings.Proxy.NoProxy)
+		return nil
+	}
+}
+
+// ApplyAgentConfigSettings reconfigures the agent from the settings
+// last recorded in its configuration by SetAgentConfigSettings. It is
+// called when the agent starts, so that the settings are in effect
+// before the agent has connected to the API. If no proxy settings have
+// been recorded, the proxy environment is left alone.
+func ApplyAgentConfigSettings(config agent.Config) error {
+	if loggingConfig := config.Value(agent.LoggingConfig); loggingConfig != "" {
+		if err := loggo.ConfigureLoggers(loggingConfig); err != nil {
+			return errors.Annotate(err, "cannot configure loggers")
+		}
+	}
+	settings := proxy.Settings{
+		Http:    config.Value(agent.HTTPProxy),
+		Https:   config.Value(agent.HTTPSProxy),
+		Ftp:     config.Value(agent.FTPProxy),
+		NoProxy: config.Value(agent.NoProxy),
+	}
+	if settings != (proxy.Settings{}) {
+		settings.SetEnvironmentValues()
+	}
+	return nil
+}
+
 type Agent interface {
 	Tag() names.Tag
 	ChangeConfig(AgentConfigMutator) error
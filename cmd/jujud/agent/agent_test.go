@@ -17,7 +17,6 @@ import (
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/api"
-	apienvironment "github.com/juju/juju/api/environment"
 	"github.com/juju/juju/apiserver/params"
 	agenttesting "github.com/juju/juju/cmd/jujud/agent/testing"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
@@ -169,7 +168,7 @@ func (s *AgentSuite) SetUpTest(c *gc.C) {
 	}}}
 	err := s.State.SetAPIHostPorts(hostPorts)
 	c.Assert(err, jc.ErrorIsNil)
-	s.PatchValue(&proxyupdater.New, func(*apienvironment.Facade, bool) worker.Worker {
+	s.PatchValue(&proxyupdater.New, func(proxyupdater.API, bool) worker.Worker {
 		return newDummyWorker()
 	})
 }
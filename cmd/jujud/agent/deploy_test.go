@@ -14,6 +14,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
 )
@@ -32,7 +33,7 @@ type fakeContext struct {
 	inited      chan struct{}
 }
 
-func (ctx *fakeContext) DeployUnit(unitName, _ string) error {
+func (ctx *fakeContext) DeployUnit(unitName, _ string, _ instance.ContainerType) error {
 	ctx.mu.Lock()
 	ctx.deployed.Add(unitName)
 	ctx.mu.Unlock()
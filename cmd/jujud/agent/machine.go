@@ -31,24 +31,29 @@ import (
 	"github.com/juju/juju/api"
 	apiagent "github.com/juju/juju/api/agent"
 	apideployer "github.com/juju/juju/api/deployer"
+	"github.com/juju/juju/api/maintenancewindow"
 	"github.com/juju/juju/api/metricsmanager"
 	"github.com/juju/juju/apiserver"
+	apiserverclient "github.com/juju/juju/apiserver/client"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/jujud/reboot"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/apitrace"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/feature"
 	"github.com/juju/juju/instance"
 	jujunames "github.com/juju/juju/juju/names"
 	"github.com/juju/juju/juju/paths"
-	"github.com/juju/juju/lease"
+	"github.com/juju/juju/lease/raftlease"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/replicaset"
 	"github.com/juju/juju/service"
 	"github.com/juju/juju/service/common"
@@ -58,33 +63,56 @@ import (
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/actionscheduler"
+	"github.com/juju/juju/worker/agentconfigupdater"
 	"github.com/juju/juju/worker/apiaddressupdater"
+	"github.com/juju/juju/worker/apicache"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/cacertupdater"
 	"github.com/juju/juju/worker/certupdater"
+	"github.com/juju/juju/worker/charmgc"
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
+	"github.com/juju/juju/worker/credentialvalidator"
+	"github.com/juju/juju/worker/dependency"
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/diskformatter"
 	"github.com/juju/juju/worker/diskmanager"
+	"github.com/juju/juju/worker/dnsregistrar"
+	"github.com/juju/juju/worker/egressfirewaller"
 	"github.com/juju/juju/worker/envworkermanager"
+	"github.com/juju/juju/worker/evacuator"
 	"github.com/juju/juju/worker/firewaller"
+	"github.com/juju/juju/worker/hahealer"
+	"github.com/juju/juju/worker/hostfirewaller"
+	"github.com/juju/juju/worker/instancemetadataupdater"
 	"github.com/juju/juju/worker/instancepoller"
 	"github.com/juju/juju/worker/localstorage"
+	"github.com/juju/juju/worker/logforwarder"
 	workerlogger "github.com/juju/juju/worker/logger"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/machiner"
+	"github.com/juju/juju/worker/manualprovisioner"
+	"github.com/juju/juju/worker/meterstatusalerter"
 	"github.com/juju/juju/worker/metricworker"
 	"github.com/juju/juju/worker/minunitsworker"
+	"github.com/juju/juju/worker/mongoconfig"
 	"github.com/juju/juju/worker/networker"
+	"github.com/juju/juju/worker/osupdater"
+	"github.com/juju/juju/worker/patchcoordinator"
 	"github.com/juju/juju/worker/peergrouper"
 	"github.com/juju/juju/worker/provisioner"
 	"github.com/juju/juju/worker/proxyupdater"
+	raftleaseworker "github.com/juju/juju/worker/raftlease"
 	rebootworker "github.com/juju/juju/worker/reboot"
 	"github.com/juju/juju/worker/resumer"
-	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/singular"
 	"github.com/juju/juju/worker/storageprovisioner"
+	"github.com/juju/juju/worker/suspendgate"
 	"github.com/juju/juju/worker/terminationworker"
+	"github.com/juju/juju/worker/toolsmirror"
 	"github.com/juju/juju/worker/upgrader"
+	"github.com/juju/juju/worker/upgradeseries"
 )
 
 const bootstrapMachineId = "0"
@@ -104,6 +132,8 @@ var (
 	newNetworker             = networker.NewNetworker
 	newFirewaller            = firewaller.NewFirewaller
 	newDiskManager           = diskmanager.NewWorker
+	newOSUpdater             = osupdater.New
+	newUpgradeSeriesWorker   = upgradeseries.New
 	newStorageWorker         = storageprovisioner.NewStorageProvisioner
 	newCertificateUpdater    = certupdater.NewCertificateUpdater
 	reportOpenedState        = func(interface{}) {}
@@ -244,14 +274,22 @@ func (a *machineAgentCmd) Info() *cmd.Info {
 func MachineAgentFactoryFn(
 	agentConfWriter AgentConfigWriter,
 	apiAddressSetter apiaddressupdater.APIAddressSetter,
+	bufferedLogs logsender.LogRecordCh,
 ) func(string) *MachineAgent {
 	return func(machineId string) *MachineAgent {
+		engine, err := dependency.NewEngine(cmdutil.NewEngineConfig())
+		if err != nil {
+			// The engine configuration is fixed, so it cannot be
+			// invalid at runtime.
+			panic(err)
+		}
 		return NewMachineAgent(
 			machineId,
 			agentConfWriter,
 			apiAddressSetter,
+			bufferedLogs,
 			NewUpgradeWorkerContext(),
-			worker.NewRunner(cmdutil.IsFatal, cmdutil.MoreImportant),
+			engine,
 		)
 	}
 }
@@ -261,17 +299,20 @@ func NewMachineAgent(
 	machineId string,
 	agentConfWriter AgentConfigWriter,
 	apiAddressSetter apiaddressupdater.APIAddressSetter,
+	bufferedLogs logsender.LogRecordCh,
 	upgradeWorkerContext *upgradeWorkerContext,
-	runner worker.Runner,
+	engine dependency.Engine,
 ) *MachineAgent {
 
 	return &MachineAgent{
 		machineId:            machineId,
 		AgentConfigWriter:    agentConfWriter,
 		apiAddressSetter:     apiAddressSetter,
+		bufferedLogs:         bufferedLogs,
 		workersStarted:       make(chan struct{}),
 		upgradeWorkerContext: upgradeWorkerContext,
-		runner:               runner,
+		engine:               engine,
+		hub:                  pubsub.NewHub(pubsub.DefaultHistorySize),
 	}
 }
 
@@ -284,13 +325,17 @@ type MachineAgent struct {
 	machineId            string
 	previousAgentVersion version.Number
 	apiAddressSetter     apiaddressupdater.APIAddressSetter
-	runner               worker.Runner
+	bufferedLogs         logsender.LogRecordCh
+	engine               dependency.Engine
 	configChangedVal     voyeur.Value
 	upgradeWorkerContext *upgradeWorkerContext
 	restoreMode          bool
 	restoring            bool
 	workersStarted       chan struct{}
 
+	// hub carries local events between the agent's workers.
+	hub *pubsub.Hub
+
 	mongoInitMutex   sync.Mutex
 	mongoInitialized bool
 }
@@ -314,10 +359,24 @@ func (a *MachineAgent) Wait() error {
 
 // Stop stops the machine agent.
 func (a *MachineAgent) Stop() error {
-	a.runner.Kill()
+	a.engine.Kill()
 	return a.tomb.Wait()
 }
 
+// RecentEvents returns the events most recently published between the
+// agent's workers, oldest first.
+func (a *MachineAgent) RecentEvents() []pubsub.Event {
+	return a.hub.RecentEvents()
+}
+
+// logRecentEvents writes the agent's recent events to the log, to aid
+// diagnosis of why the agent stopped.
+func (a *MachineAgent) logRecentEvents() {
+	for _, event := range a.hub.RecentEvents() {
+		logger.Debugf("recent event at %v: %s %+v", event.Published, event.Topic, event.Data)
+	}
+}
+
 // Dying returns the channel that can be used to see if the machine
 // agent is terminating.
 func (a *MachineAgent) Dying() <-chan struct{} {
@@ -344,18 +403,24 @@ func (a *MachineAgent) Run(*cmd.Context) error {
 	a.configChangedVal.Set(struct{}{})
 	a.previousAgentVersion = agentConfig.UpgradedToVersion()
 	network.InitializeFromConfig(agentConfig)
+	if err := ApplyAgentConfigSettings(agentConfig); err != nil {
+		logger.Warningf("cannot apply agent config settings: %v", err)
+	}
 	charm.CacheDir = filepath.Join(agentConfig.DataDir(), "charmcache")
 	if err := a.createJujuRun(agentConfig.DataDir()); err != nil {
 		return fmt.Errorf("cannot create juju run symlink: %v", err)
 	}
-	a.runner.StartWorker("api", a.APIWorker)
-	a.runner.StartWorker("statestarter", a.newStateStarterWorker)
-	a.runner.StartWorker("termination", func() (worker.Worker, error) {
-		return terminationworker.NewWorker(), nil
-	})
+	if err := dependency.Install(a.engine, a.manifolds()); err != nil {
+		a.engine.Kill()
+		if err := a.engine.Wait(); err != nil {
+			logger.Errorf("while stopping machine agent workers: %v", err)
+		}
+		return errors.Trace(err)
+	}
 	// At this point, all workers will have been configured to start
 	close(a.workersStarted)
-	err := a.runner.Wait()
+	err := a.engine.Wait()
+	a.logRecentEvents()
 	switch err {
 	case worker.ErrTerminateAgent:
 		err = a.uninstallAgent(agentConfig)
@@ -493,8 +558,43 @@ func (a *MachineAgent) restoreStateWatcher(st *state.State, stopch <-chan struct
 	}
 }
 
-// newStateStarterWorker wraps stateStarter in a simple worker for use in
-// a.runner.StartWorker.
+// The names of the machine agent's manifolds.
+const (
+	agentManifoldName         = "agent"
+	terminationManifoldName   = "termination"
+	apiWorkersManifoldName    = "api-workers"
+	stateStarterManifoldName  = "state-starter"
+	introspectionManifoldName = "introspection"
+)
+
+// manifolds returns the manifolds of the workers run directly by the
+// machine agent's dependency engine.
+func (a *MachineAgent) manifolds() dependency.Manifolds {
+	return dependency.Manifolds{
+		agentManifoldName: cmdutil.AgentManifold(a),
+		terminationManifoldName: {
+			Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+				return terminationworker.NewWorker(), nil
+			},
+		},
+		apiWorkersManifoldName: {
+			Inputs: []string{agentManifoldName},
+			Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+				return a.APIWorker()
+			},
+		},
+		stateStarterManifoldName: {
+			Inputs: []string{agentManifoldName},
+			Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+				return a.newStateStarterWorker()
+			},
+		},
+		introspectionManifoldName: cmdutil.IntrospectionManifold(agentManifoldName, a.engine),
+	}
+}
+
+// newStateStarterWorker wraps stateStarter in a simple worker for use
+// in the machine agent's dependency engine.
 func (a *MachineAgent) newStateStarterWorker() (worker.Worker, error) {
 	return worker.NewSimpleWorker(a.stateStarter), nil
 }
@@ -505,8 +605,15 @@ func (a *MachineAgent) newStateStarterWorker() (worker.Worker, error) {
 // that we need to start a state server, whether they have been cached
 // or read from the state.
 //
-// It will stop working as soon as stopch is closed.
+// The state and lease workers are run by a runner owned by the
+// stateStarter; a fatal error from either of them stops the stateStarter
+// with that error. It will stop working as soon as stopch is closed.
 func (a *MachineAgent) stateStarter(stopch <-chan struct{}) error {
+	runner := worker.NewRunner(cmdutil.IsFatal, cmdutil.MoreImportant)
+	runnerDone := make(chan error, 1)
+	go func() {
+		runnerDone <- runner.Wait()
+	}()
 	confWatch := a.configChangedVal.Watch()
 	defer confWatch.Close()
 	watchCh := make(chan struct{})
@@ -523,14 +630,19 @@ func (a *MachineAgent) stateStarter(stopch <-chan struct{}) error {
 			// N.B. StartWorker and StopWorker are idempotent.
 			_, ok := agentConfig.StateServingInfo()
 			if ok {
-				a.runner.StartWorker("state", func() (worker.Worker, error) {
+				runner.StartWorker("state", func() (worker.Worker, error) {
 					return a.StateWorker()
 				})
+				runner.StartWorker("raftlease", a.RaftLeaseWorker)
 			} else {
-				a.runner.StopWorker("state")
+				runner.StopWorker("state")
+				runner.StopWorker("raftlease")
 			}
+		case err := <-runnerDone:
+			return err
 		case <-stopch:
-			return nil
+			runner.Kill()
+			return <-runnerDone
 		}
 	}
 }
@@ -579,6 +691,7 @@ func (a *MachineAgent) APIWorker() (worker.Worker, error) {
 	runner.StartWorker("upgrader", func() (worker.Worker, error) {
 		return upgrader.NewUpgrader(
 			st.Upgrader(),
+			maintenancewindow.NewClient(st),
 			agentConfig,
 			a.previousAgentVersion,
 			a.upgradeWorkerContext.IsUpgradeRunning,
@@ -608,11 +721,6 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		}
 	}
 
-	rsyslogMode := rsyslog.RsyslogModeForwarding
-	if isEnvironManager {
-		rsyslogMode = rsyslog.RsyslogModeAccumulate
-	}
-
 	runner := newConnRunner(st)
 	// TODO(fwereade): this is *still* a hideous layering violation, but at least
 	// it's confined to jujud rather than extending into the worker itself.
@@ -620,7 +728,7 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 	// before we do anything else.
 	writeSystemFiles := shouldWriteProxyFiles(agentConfig)
 	runner.StartWorker("proxyupdater", func() (worker.Worker, error) {
-		return proxyupdater.New(st.Environment(), writeSystemFiles), nil
+		return proxyupdater.New(st.ProxyUpdater(), writeSystemFiles), nil
 	})
 
 	runner.StartWorker("machiner", func() (worker.Worker, error) {
@@ -631,22 +739,56 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		rebootRequests, err := st.RebootRequests()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		lock, err := cmdutil.HookExecutionLock(cmdutil.DataDir)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		return rebootworker.NewReboot(reboot, agentConfig, lock)
+		return rebootworker.NewReboot(reboot, rebootRequests, agentConfig, lock, a.hub)
+	})
+	if version.Current.OS == version.Ubuntu {
+		runner.StartWorker("osupdater", func() (worker.Worker, error) {
+			osUpdates, err := st.OSUpdates()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			reboot, err := st.Reboot()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return newOSUpdater(osUpdates, reboot), nil
+		})
+	}
+	runner.StartWorker("upgradeseries", func() (worker.Worker, error) {
+		upgradeSeries, err := st.UpgradeSeries()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		upgrader := upgradeseries.NewAgentUpgrader(agentConfig)
+		return newUpgradeSeriesWorker(upgradeSeries, upgrader), nil
 	})
 	runner.StartWorker("apiaddressupdater", func() (worker.Worker, error) {
 		return apiaddressupdater.NewAPIAddressUpdater(st.Machiner(), a.apiAddressSetter), nil
 	})
+	runner.StartWorker("cacertupdater", func() (worker.Worker, error) {
+		currentCACert := func() string { return a.CurrentConfig().CACert() }
+		return cacertupdater.NewCACertUpdater(st.Environment(), a, currentCACert), nil
+	})
+	runner.StartWorker("agentconfigupdater", func() (worker.Worker, error) {
+		return agentconfigupdater.NewAgentConfigUpdater(st.Agent(), a), nil
+	})
 	runner.StartWorker("logger", func() (worker.Worker, error) {
 		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
 	})
 
-	runner.StartWorker("rsyslog", func() (worker.Worker, error) {
-		return cmdutil.NewRsyslogConfigWorker(st.Rsyslog(), agentConfig, rsyslogMode)
-	})
+	if a.bufferedLogs != nil {
+		runner.StartWorker("logsender", func() (worker.Worker, error) {
+			return cmdutil.NewLogSender(a.bufferedLogs, st), nil
+		})
+	}
 	// TODO(wallyworld) - we don't want the storage workers running yet, even with feature flag.
 	// Will be enabled in a followup branch.
 	enableStorageWorkers := false
@@ -700,7 +842,7 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 	}
 	intrusiveMode = intrusiveMode && !disableNetworkManagement
 	runner.StartWorker("networker", func() (worker.Worker, error) {
-		return newNetworker(st.Networker(), agentConfig, intrusiveMode, networker.DefaultConfigBaseDir)
+		return newNetworker(st.Networker(), agentConfig, intrusiveMode, networker.DefaultConfigBaseDir, a.hub)
 	})
 
 	// If not a local provider bootstrap machine, start the worker to
@@ -712,6 +854,19 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 		})
 	}
 
+	// Providers without a firewall of their own leave every port open,
+	// so restrict access to services exposed to particular addresses
+	// with the host firewall instead.
+	if (providerType == provider.MAAS || provider.IsManual(providerType)) && !names.IsContainerMachine(a.machineId) {
+		runner.StartWorker("hostfirewaller", func() (worker.Worker, error) {
+			api, err := st.HostFirewaller()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return hostfirewaller.NewWorker(api), nil
+		})
+	}
+
 	// Perform the operations needed to set up hosting for containers.
 	if err := a.setupContainerSupport(runner, st, entity, agentConfig); err != nil {
 		cause := errors.Cause(err)
@@ -728,7 +883,20 @@ func (a *MachineAgent) postUpgradeAPIWorker(
 				context := newDeployContext(apiDeployer, agentConfig)
 				return deployer.NewDeployer(apiDeployer, context), nil
 			})
+			// State servers must stay reachable by every agent, so
+			// only restrict egress from machines that host units alone.
+			if !isEnvironManager && version.Current.OS != version.Windows {
+				runner.StartWorker("egressfirewaller", func() (worker.Worker, error) {
+					apiAddresses := func() ([]string, error) {
+						return a.CurrentConfig().APIAddresses()
+					}
+					return egressfirewaller.NewWorker(st.Environment(), apiAddresses), nil
+				})
+			}
 		case multiwatcher.JobManageEnviron:
+			// State servers make the provider API calls, so trace
+			// them to report on the provider's health.
+			apitrace.Install()
 			runner.StartWorker("identity-file-writer", func() (worker.Worker, error) {
 				inner := func(<-chan struct{}) error {
 					agentConfig := a.CurrentConfig()
@@ -769,8 +937,9 @@ var shouldWriteProxyFiles = func(conf agent.Config) bool {
 // initialises suitable infrastructure to support such containers.
 func (a *MachineAgent) setupContainerSupport(runner worker.Runner, st *api.State, entity *apiagent.Entity, agentConfig agent.Config) error {
 	var supportedContainers []instance.ContainerType
-	// LXC containers are only supported on bare metal and fully virtualized linux systems
-	// Nested LXC containers and Windows machines cannot run LXC containers
+	// LXC containers are supported on bare metal and fully virtualized linux systems,
+	// and inside LXC containers which allow nesting. Windows machines cannot run
+	// LXC containers.
 	supportsLXC, err := lxc.IsLXCSupported()
 	if err != nil {
 		logger.Warningf("no lxc containers possible: %v", err)
@@ -786,6 +955,16 @@ func (a *MachineAgent) setupContainerSupport(runner worker.Runner, st *api.State
 	if err == nil && supportsKvm {
 		supportedContainers = append(supportedContainers, instance.KVM)
 	}
+
+	// Hyper-V containers are supported on Windows releases that provide
+	// them, once the Containers feature is installed.
+	supportsHyperV, err := hyperv.IsHyperVSupported()
+	if err != nil {
+		logger.Warningf("determining hyper-v support: %v\nno hyper-v containers possible", err)
+	}
+	if err == nil && supportsHyperV {
+		supportedContainers = append(supportedContainers, instance.HYPERV)
+	}
 	return a.updateSupportedContainers(runner, st, entity.Tag(), supportedContainers, agentConfig)
 }
 
@@ -838,6 +1017,13 @@ func (a *MachineAgent) updateSupportedContainers(
 	if agentConfig.Value(agent.AllowsSecureConnection) == "true" {
 		imageURLGetter = container.NewImageURLGetter(st.Addr(), envUUID.Id(), []byte(agentConfig.CACert()))
 	}
+	// Results of the provisioner API calls made when starting
+	// containers are cached, and discarded when the environment
+	// configuration they are derived from changes.
+	apiCache := apicache.Open(filepath.Join(agentConfig.DataDir(), "api-cache.json"), apicache.DefaultMaxAge)
+	a.startWorkerAfterUpgrade(runner, "api-cache-invalidator", func() (worker.Worker, error) {
+		return apicache.NewInvalidator(apiCache, pr.WatchForEnvironConfigChanges), nil
+	})
 	params := provisioner.ContainerSetupParams{
 		Runner:              runner,
 		WorkerName:          watcherName,
@@ -847,6 +1033,7 @@ func (a *MachineAgent) updateSupportedContainers(
 		Provisioner:         pr,
 		Config:              agentConfig,
 		InitLock:            initLock,
+		APICache:            apiCache,
 	}
 	handler := provisioner.NewContainerSetupHandler(params)
 	a.startWorkerAfterUpgrade(runner, watcherName, func() (worker.Worker, error) {
@@ -873,8 +1060,9 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	stor := statestorage.NewStorage(st.EnvironUUID(), st.MongoSession())
 	registerSimplestreamsDataSource(stor)
 
-	runner := newConnRunner(st)
-	singularRunner, err := newSingularStateRunner(runner, st, m)
+	singularConn := raftlease.NewSingularConn()
+	runner := newConnRunner(st, singularConn)
+	singularRunner, err := newSingularStateRunner(runner, singularConn)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -902,13 +1090,13 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(runner, "peergrouper", func() (worker.Worker, error) {
 				return peergrouperNew(st)
 			})
+			a.startWorkerAfterUpgrade(runner, "mongoconfig", func() (worker.Worker, error) {
+				replicaSet := mongoconfig.NewReplicaSet(st.MongoSession())
+				return mongoconfig.New(st, replicaSet, m.Id(), a.CurrentConfig, a.restartMongoServer), nil
+			})
 			a.startWorkerAfterUpgrade(runner, "restore", func() (worker.Worker, error) {
 				return a.newRestoreStateWatcherWorker(st)
 			})
-			a.startWorkerAfterUpgrade(runner, "lease manager", func() (worker.Worker, error) {
-				workerLoop := lease.WorkerLoop(st)
-				return worker.NewSimpleWorker(workerLoop), nil
-			})
 			certChangedChan := make(chan params.StateServingInfo, 1)
 			runner.StartWorker("apiserver", a.apiserverWorkerStarter(st, certChangedChan))
 			var stateServingSetter certupdater.StateServingInfoSetter = func(info params.StateServingInfo) error {
@@ -922,12 +1110,23 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 			a.startWorkerAfterUpgrade(runner, "certupdater", func() (worker.Worker, error) {
 				return newCertificateUpdater(m, agentConfig, st, stateServingSetter, certChangedChan), nil
 			})
+			a.startWorkerAfterUpgrade(runner, "certrotator", func() (worker.Worker, error) {
+				// The serving info changes with every rotation, so it
+				// must be read afresh from the agent's config each time.
+				getter := certupdater.StateServingInfoGetterFunc(func() (params.StateServingInfo, bool) {
+					return a.CurrentConfig().StateServingInfo()
+				})
+				return certupdater.NewCertificateRotator(st, getter, stateServingSetter), nil
+			})
 			a.startWorkerAfterUpgrade(singularRunner, "resumer", func() (worker.Worker, error) {
 				// The action of resumer is so subtle that it is not tested,
 				// because we can't figure out how to do so without brutalising
 				// the transaction log.
 				return resumer.NewResumer(st), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "hahealer", func() (worker.Worker, error) {
+				return hahealer.New(st), nil
+			})
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
 		default:
@@ -937,6 +1136,30 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 	return cmdutil.NewCloseWorker(logger, runner, st), nil
 }
 
+// RaftLeaseWorker returns a worker which runs this state server's
+// member of the raft cluster holding leases. It runs outside the state
+// worker, so that restarting the state worker does not disturb the
+// cluster.
+func (a *MachineAgent) RaftLeaseWorker() (worker.Worker, error) {
+	agentConfig := a.CurrentConfig()
+	servingInfo, ok := agentConfig.StateServingInfo()
+	if !ok {
+		return nil, errors.New("no state serving info available")
+	}
+	st, _, err := openState(agentConfig, stateWorkerDialOpts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := raftleaseworker.New(raftleaseworker.Config{
+		State:     raftleaseworker.NewStateShim(st),
+		MachineId: a.machineId,
+		DataDir:   agentConfig.DataDir(),
+		APIPort:   servingInfo.APIPort,
+		Dial:      raftleaseworker.APIDialer(agentConfig.APIInfo()),
+	})
+	return cmdutil.NewCloseWorker(logger, w, st), nil
+}
+
 // startEnvWorkers starts state server workers that need to run per
 // environment.
 func (a *MachineAgent) startEnvWorkers(
@@ -958,8 +1181,11 @@ func (a *MachineAgent) startEnvWorkers(
 
 	// Create a runner for workers specific to this
 	// environment. Either the State or API connection failing will be
-	// considered fatal, killing the runner and all its workers.
-	runner = newConnRunner(st, apiSt)
+	// considered fatal, killing the runner and all its workers. The
+	// singular workers of the environment run on whichever state
+	// server holds its singular lease.
+	singularConn := singular.NewLeaseConn(raftlease.Manager(), singular.LeaseNamespace(envUUID), a.machineId)
+	runner = newConnRunner(st, apiSt, singularConn)
 	defer func() {
 		if err != nil && runner != nil {
 			runner.Kill()
@@ -976,11 +1202,7 @@ func (a *MachineAgent) startEnvWorkers(
 	}()
 
 	// Create a singular runner for this environment.
-	machine, err := ssSt.Machine(a.machineId)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	singularRunner, err := newSingularStateRunner(runner, ssSt, machine)
+	singularRunner, err := newSingularStateRunner(runner, singularConn)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -992,26 +1214,73 @@ func (a *MachineAgent) startEnvWorkers(
 	}()
 
 	// Start workers that depend on a *state.State.
-	runner.StartWorker("instancepoller", func() (worker.Worker, error) {
+	singularRunner.StartWorker("instancepoller", func() (worker.Worker, error) {
 		return instancepoller.NewWorker(st), nil
 	})
 	singularRunner.StartWorker("cleaner", func() (worker.Worker, error) {
 		return cleaner.NewCleaner(st), nil
 	})
+	singularRunner.StartWorker("charmgc", func() (worker.Worker, error) {
+		stor := statestorage.NewStorage(st.EnvironUUID(), st.MongoSession())
+		return charmgc.New(st, stor), nil
+	})
 	singularRunner.StartWorker("minunitsworker", func() (worker.Worker, error) {
 		return minunitsworker.NewMinUnitsWorker(st), nil
 	})
+	singularRunner.StartWorker("evacuator", func() (worker.Worker, error) {
+		return evacuator.NewEvacuator(st), nil
+	})
+	singularRunner.StartWorker("patchcoordinator", func() (worker.Worker, error) {
+		return patchcoordinator.NewCoordinator(st), nil
+	})
+	singularRunner.StartWorker("manual-provisioner", func() (worker.Worker, error) {
+		script := func(machineId, nonce string, disablePackageCommands bool) (string, error) {
+			return apiserverclient.MachineProvisioningScript(st, params.ProvisioningScriptParams{
+				MachineId:              machineId,
+				Nonce:                  nonce,
+				DisablePackageCommands: disablePackageCommands,
+			})
+		}
+		return manualprovisioner.NewProvisioner(st, agentConfig.SystemIdentityPath(), script), nil
+	})
+	singularRunner.StartWorker("toolsmirror", func() (worker.Worker, error) {
+		return toolsmirror.NewWorker(st), nil
+	})
+	singularRunner.StartWorker("dnsregistrar", func() (worker.Worker, error) {
+		return dnsregistrar.NewWorker(st), nil
+	})
 
 	// Start workers that use an API connection.
+	// Workers that change the environment's instances, ports and
+	// charms are paused while the environment is suspended.
 	singularRunner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
-		return provisioner.NewEnvironProvisioner(apiSt.Provisioner(), agentConfig), nil
+		return suspendgate.New(apiSt.Environment(), "environ-provisioner", func() (worker.Worker, error) {
+			return provisioner.NewEnvironProvisioner(apiSt.Provisioner(), agentConfig), nil
+		}), nil
 	})
 	singularRunner.StartWorker("charm-revision-updater", func() (worker.Worker, error) {
-		return charmrevisionworker.NewRevisionUpdateWorker(apiSt.CharmRevisionUpdater()), nil
+		return suspendgate.New(apiSt.Environment(), "charm-revision-updater", func() (worker.Worker, error) {
+			return charmrevisionworker.NewRevisionUpdateWorker(apiSt.CharmRevisionUpdater()), nil
+		}), nil
 	})
-	runner.StartWorker("metricmanagerworker", func() (worker.Worker, error) {
+	singularRunner.StartWorker("metricmanagerworker", func() (worker.Worker, error) {
 		return metricworker.NewMetricsManager(getMetricAPI(apiSt))
 	})
+	singularRunner.StartWorker("logforwarder", func() (worker.Worker, error) {
+		return logforwarder.New(apiSt.LogForwarding(), envUUID), nil
+	})
+	singularRunner.StartWorker("meterstatusalerter", func() (worker.Worker, error) {
+		return meterstatusalerter.New(apiSt.MeterStatus(), envUUID), nil
+	})
+	singularRunner.StartWorker("actionscheduler", func() (worker.Worker, error) {
+		return actionscheduler.New(apiSt.ActionScheduler()), nil
+	})
+	singularRunner.StartWorker("credentialvalidator", func() (worker.Worker, error) {
+		return credentialvalidator.New(apiSt.CredentialValidator()), nil
+	})
+	singularRunner.StartWorker("instancemetadataupdater", func() (worker.Worker, error) {
+		return instancemetadataupdater.New(apiSt.InstanceMetadata()), nil
+	})
 
 	// TODO(axw) 2013-09-24 bug #1229506
 	// Make another job to enable the firewaller. Not all
@@ -1023,7 +1292,9 @@ func (a *MachineAgent) startEnvWorkers(
 	}
 	if fwMode != config.FwNone {
 		singularRunner.StartWorker("firewaller", func() (worker.Worker, error) {
-			return newFirewaller(apiSt.Firewaller())
+			return suspendgate.New(apiSt.Environment(), "firewaller", func() (worker.Worker, error) {
+				return newFirewaller(apiSt.Firewaller())
+			}), nil
 		})
 	} else {
 		logger.Debugf("not starting firewaller worker - firewall-mode is %q", fwMode)
@@ -1073,22 +1344,65 @@ func (a *MachineAgent) newApiserverWorker(st *state.State, certChanged chan para
 	dataDir := agentConfig.DataDir()
 	logDir := agentConfig.LogDir()
 
+	uploadLimits, err := apiserverUploadLimits(agentConfig)
+	if err != nil {
+		return nil, &cmdutil.FatalError{err.Error()}
+	}
+
 	endpoint := net.JoinHostPort("", strconv.Itoa(info.APIPort))
 	listener, err := net.Listen("tcp", endpoint)
 	if err != nil {
 		return nil, err
 	}
 	return apiserver.NewServer(st, listener, apiserver.ServerConfig{
-		Cert:        cert,
-		Key:         key,
-		Tag:         tag,
-		DataDir:     dataDir,
-		LogDir:      logDir,
-		Validator:   a.limitLogins,
-		CertChanged: certChanged,
+		Cert:         cert,
+		Key:          key,
+		Tag:          tag,
+		DataDir:      dataDir,
+		LogDir:       logDir,
+		Validator:    a.limitLogins,
+		CertChanged:  certChanged,
+		UploadLimits: uploadLimits,
 	})
 }
 
+// apiserverUploadLimits returns the upload size limits set in the agent
+// configuration. Limits that are not set are left for the API server
+// to default.
+func apiserverUploadLimits(agentConfig agent.Config) (apiserver.UploadLimits, error) {
+	var limits apiserver.UploadLimits
+	for key, limit := range map[string]*int64{
+		agent.MaxCharmUploadSize:  &limits.Charms,
+		agent.MaxToolsUploadSize:  &limits.Tools,
+		agent.MaxBackupUploadSize: &limits.Backups,
+	} {
+		value := agentConfig.Value(key)
+		if value == "" {
+			continue
+		}
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size <= 0 {
+			return apiserver.UploadLimits{}, errors.Errorf("invalid %s: %q", key, value)
+		}
+		*limit = size
+	}
+	return limits, nil
+}
+
+// SetCACert satisfies worker/cacertupdater/CACertSetter.
+func (a *MachineAgent) SetCACert(caCert string) error {
+	return a.ChangeConfig(func(config agent.ConfigSetter) error {
+		config.SetCACert(caCert)
+		return nil
+	})
+}
+
+// SetAgentConfigSettings satisfies
+// worker/agentconfigupdater/AgentConfigSettingsSetter.
+func (a *MachineAgent) SetAgentConfigSettings(settings params.AgentConfigSettings) error {
+	return a.ChangeConfig(agentConfigSettingsMutator(settings))
+}
+
 // limitLogins is called by the API server for each login attempt.
 // it returns an error if upgrads or restore are running.
 func (a *MachineAgent) limitLogins(req params.LoginRequest) error {
@@ -1256,6 +1570,38 @@ func (a *MachineAgent) ensureMongoServer(agentConfig agent.Config) (err error) {
 	return nil
 }
 
+// restartMongoServer records the given mongo cache size in the agent
+// config and restarts mongo to apply it. The previous size is restored
+// if mongo cannot be restarted.
+func (a *MachineAgent) restartMongoServer(cacheSizeGB int) error {
+	value := ""
+	if cacheSizeGB > 0 {
+		value = fmt.Sprint(cacheSizeGB)
+	}
+	var previous string
+	if err := a.ChangeConfig(func(config agent.ConfigSetter) error {
+		previous = config.Value(agent.MongoCacheSize)
+		config.SetValue(agent.MongoCacheSize, value)
+		return nil
+	}); err != nil {
+		return errors.Trace(err)
+	}
+	ensureServerParams, err := cmdutil.NewEnsureServerParams(a.CurrentConfig())
+	if err == nil {
+		err = cmdutil.EnsureMongoServer(ensureServerParams)
+	}
+	if err != nil {
+		if err := a.ChangeConfig(func(config agent.ConfigSetter) error {
+			config.SetValue(agent.MongoCacheSize, previous)
+			return nil
+		}); err != nil {
+			logger.Errorf("cannot restore mongo cache size: %v", err)
+		}
+		return errors.Trace(err)
+	}
+	return nil
+}
+
 func (a *MachineAgent) ensureMongoAdminUser(agentConfig agent.Config) (added bool, err error) {
 	stateInfo, ok1 := agentConfig.MongoInfo()
 	servingInfo, ok2 := agentConfig.StateServingInfo()
@@ -1425,34 +1771,16 @@ func newConnRunner(conns ...cmdutil.Pinger) worker.Runner {
 	return worker.NewRunner(cmdutil.ConnectionIsFatal(logger, conns...), cmdutil.MoreImportant)
 }
 
-type MongoSessioner interface {
-	MongoSession() *mgo.Session
-}
-
-func newSingularStateRunner(runner worker.Runner, st MongoSessioner, m *state.Machine) (worker.Runner, error) {
-	singularStateConn := singularStateConn{st.MongoSession(), m}
-	singularRunner, err := newSingularRunner(runner, singularStateConn)
+// newSingularStateRunner returns a runner whose workers run only on the
+// state server leading the lease service's raft cluster.
+func newSingularStateRunner(runner worker.Runner, conn singular.Conn) (worker.Runner, error) {
+	singularRunner, err := newSingularRunner(runner, conn)
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot make singular State Runner")
 	}
 	return singularRunner, err
 }
 
-// singularStateConn implements singular.Conn on
-// top of a State connection.
-type singularStateConn struct {
-	session *mgo.Session
-	machine *state.Machine
-}
-
-func (c singularStateConn) IsMaster() (bool, error) {
-	return mongo.IsMaster(c.session, c.machine)
-}
-
-func (c singularStateConn) Ping() error {
-	return c.session.Ping()
-}
-
 func metricAPI(st *api.State) metricsmanager.MetricsManagerClient {
 	return metricsmanager.NewClient(st)
 }
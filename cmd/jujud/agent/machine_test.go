@@ -16,6 +16,7 @@ import (
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"github.com/juju/names"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -25,15 +26,14 @@ import (
 	"github.com/juju/utils/symlink"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v4"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api"
 	apideployer "github.com/juju/juju/api/deployer"
-	apienvironment "github.com/juju/juju/api/environment"
 	apifirewaller "github.com/juju/juju/api/firewaller"
 	apimetricsmanager "github.com/juju/juju/api/metricsmanager"
 	apinetworker "github.com/juju/juju/api/networker"
-	apirsyslog "github.com/juju/juju/api/rsyslog"
 	charmtesting "github.com/juju/juju/apiserver/charmrevisionupdater/testing"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cert"
@@ -50,6 +50,7 @@ import (
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/service/upstart"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/watcher"
@@ -66,10 +67,11 @@ import (
 	"github.com/juju/juju/worker/deployer"
 	"github.com/juju/juju/worker/diskmanager"
 	"github.com/juju/juju/worker/instancepoller"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/networker"
+	"github.com/juju/juju/worker/osupdater"
 	"github.com/juju/juju/worker/peergrouper"
 	"github.com/juju/juju/worker/proxyupdater"
-	"github.com/juju/juju/worker/rsyslog"
 	"github.com/juju/juju/worker/singular"
 	"github.com/juju/juju/worker/storageprovisioner"
 	"github.com/juju/juju/worker/upgrader"
@@ -133,6 +135,10 @@ func (s *commonMachineSuite) SetUpTest(c *gc.C) {
 	s.AgentSuite.PatchValue(&peergrouperNew, func(st *state.State) (worker.Worker, error) {
 		return newDummyWorker(), nil
 	})
+	// Don't touch the host's packages.
+	s.AgentSuite.PatchValue(&newOSUpdater, func(osupdater.OSUpdatesAPI, osupdater.Rebooter) worker.Worker {
+		return newDummyWorker()
+	})
 
 	s.fakeEnsureMongo = agenttesting.FakeEnsure{}
 	s.AgentSuite.PatchValue(&cmdutil.EnsureMongoServer, s.fakeEnsureMongo.FakeEnsureMongo)
@@ -212,7 +218,7 @@ func (s *commonMachineSuite) configureMachine(c *gc.C, machineId string, vers ve
 func (s *commonMachineSuite) newAgent(c *gc.C, m *state.Machine) *MachineAgent {
 	agentConf := AgentConf{DataDir: s.DataDir()}
 	agentConf.ReadConfig(names.NewMachineTag(m.Id()).String())
-	machineAgentFactory := MachineAgentFactoryFn(&agentConf, &agentConf)
+	machineAgentFactory := MachineAgentFactoryFn(&agentConf, &agentConf, nil)
 	return machineAgentFactory(m.Id())
 }
 
@@ -220,7 +226,7 @@ func (s *MachineSuite) TestParseSuccess(c *gc.C) {
 	create := func() (cmd.Command, *AgentConf) {
 		agentConf := AgentConf{DataDir: s.DataDir()}
 		a := NewMachineAgentCmd(
-			MachineAgentFactoryFn(&agentConf, &agentConf),
+			MachineAgentFactoryFn(&agentConf, &agentConf, nil),
 			&agentConf,
 			&agentConf,
 		)
@@ -238,10 +244,23 @@ type MachineSuite struct {
 }
 
 var perEnvSingularWorkers = []string{
+	"instancepoller",
 	"cleaner",
+	"charmgc",
 	"minunitsworker",
+	"evacuator",
+	"patchcoordinator",
+	"manual-provisioner",
+	"toolsmirror",
+	"dnsregistrar",
 	"environ-provisioner",
 	"charm-revision-updater",
+	"metricmanagerworker",
+	"logforwarder",
+	"meterstatusalerter",
+	"actionscheduler",
+	"credentialvalidator",
+	"instancemetadataupdater",
 	"firewaller",
 }
 
@@ -1029,14 +1048,13 @@ func (s *MachineSuite) assertProxyUpdater(c *gc.C, expectWriteSystemFiles bool)
 
 	// Patch out the actual worker func.
 	started := make(chan struct{})
-	mockNew := func(api *apienvironment.Facade, writeSystemFiles bool) worker.Worker {
+	mockNew := func(api proxyupdater.API, writeSystemFiles bool) worker.Worker {
 		// Direct check of the behaviour flag.
 		c.Check(writeSystemFiles, gc.Equals, expectWriteSystemFiles)
 		// Indirect check that we get a functional API.
-		conf, err := api.EnvironConfig()
+		conf, err := api.ProxyConfig()
 		if c.Check(err, jc.ErrorIsNil) {
-			actualSettings := conf.ProxySettings()
-			c.Check(actualSettings, jc.DeepEquals, expectSettings)
+			c.Check(conf.Proxy, jc.DeepEquals, expectSettings)
 		}
 		return worker.NewSimpleWorker(func(_ <-chan struct{}) error {
 			close(started)
@@ -1074,28 +1092,32 @@ func (s *MachineSuite) TestMachineAgentUninstall(c *gc.C) {
 	c.Assert(err, jc.Satisfies, os.IsNotExist)
 }
 
-func (s *MachineSuite) TestMachineAgentRsyslogManageEnviron(c *gc.C) {
-	s.testMachineAgentRsyslogConfigWorker(c, state.JobManageEnviron, rsyslog.RsyslogModeAccumulate)
-}
-
-func (s *MachineSuite) TestMachineAgentRsyslogHostUnits(c *gc.C) {
-	s.testMachineAgentRsyslogConfigWorker(c, state.JobHostUnits, rsyslog.RsyslogModeForwarding)
-}
+func (s *MachineSuite) TestMachineAgentRunsLogSender(c *gc.C) {
+	m, _, _ := s.primeAgent(c, version.Current, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	a.bufferedLogs = make(logsender.LogRecordCh, 1)
+	a.bufferedLogs <- &logsender.LogRecord{
+		Time:     time.Now(),
+		Module:   "juju.machine.test",
+		Location: "machine_test.go:1",
+		Level:    loggo.INFO,
+		Message:  "machine agent log record",
+	}
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
 
-func (s *MachineSuite) testMachineAgentRsyslogConfigWorker(c *gc.C, job state.MachineJob, expectedMode rsyslog.RsyslogMode) {
-	created := make(chan rsyslog.RsyslogMode, 1)
-	s.AgentSuite.PatchValue(&cmdutil.NewRsyslogConfigWorker, func(_ *apirsyslog.State, _ agent.Config, mode rsyslog.RsyslogMode) (worker.Worker, error) {
-		created <- mode
-		return newDummyWorker(), nil
-	})
-	s.assertJobWithAPI(c, job, func(conf agent.Config, st *api.State) {
-		select {
-		case <-time.After(coretesting.LongWait):
-			c.Fatalf("timeout while waiting for rsyslog worker to be created")
-		case mode := <-created:
-			c.Assert(mode, gc.Equals, expectedMode)
+	logsColl := s.State.MongoSession().DB("logs").C("logs")
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		var docs []bson.M
+		err := logsColl.Find(bson.M{"x": "machine agent log record"}).All(&docs)
+		c.Assert(err, jc.ErrorIsNil)
+		if len(docs) > 0 {
+			c.Assert(docs[0]["n"], gc.Equals, m.Tag().String())
+			c.Assert(docs[0]["m"], gc.Equals, "juju.machine.test")
+			return
 		}
-	})
+	}
+	c.Fatalf("timeout while waiting for log record to be sent")
 }
 
 func (s *MachineSuite) TestMachineAgentRunsAPIAddressUpdaterWorker(c *gc.C) {
@@ -1124,6 +1146,29 @@ func (s *MachineSuite) TestMachineAgentRunsAPIAddressUpdaterWorker(c *gc.C) {
 	c.Fatalf("timeout while waiting for agent config to change")
 }
 
+func (s *MachineSuite) TestMachineAgentRunsAgentConfigUpdaterWorker(c *gc.C) {
+	// Start the machine agent.
+	m, _, _ := s.primeAgent(c, version.Current, state.JobHostUnits)
+	a := s.newAgent(c, m)
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+
+	// Update the logging config.
+	err := s.BackingState.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=INFO;juju.worker=TRACE",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Wait for config to be updated.
+	s.BackingState.StartSync()
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		if a.CurrentConfig().Value(agent.LoggingConfig) == "<root>=INFO;juju.worker=TRACE" {
+			return
+		}
+	}
+	c.Fatalf("timeout while waiting for agent config to change")
+}
+
 func (s *MachineSuite) TestMachineAgentRunsDiskManagerWorker(c *gc.C) {
 	// The disk manager should only run with the feature flag set.
 	s.testMachineAgentRunsDiskManagerWorker(c, false, coretesting.ShortWait)
@@ -1397,12 +1442,13 @@ func (s *MachineSuite) TestMachineAgentNetworkerMode(c *gc.C) {
 			conf agent.Config,
 			intrusiveMode bool,
 			configBaseDir string,
+			publisher pubsub.Publisher,
 		) (*networker.Networker, error) {
 			select {
 			case modeCh <- intrusiveMode:
 			default:
 			}
-			return networker.NewNetworker(st, conf, intrusiveMode, configBaseDir)
+			return networker.NewNetworker(st, conf, intrusiveMode, configBaseDir, publisher)
 		})
 
 		attrs := coretesting.Attrs{"disable-network-management": !test.managedNetwork}
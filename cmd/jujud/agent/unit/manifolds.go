@@ -0,0 +1,188 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package unit defines the workers run by the unit agent, and the
+// resources each of them depends on.
+package unit
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/fslock"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/maintenancewindow"
+	cmdutil "github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/agentconfigupdater"
+	"github.com/juju/juju/worker/apiaddressupdater"
+	"github.com/juju/juju/worker/cacertupdater"
+	"github.com/juju/juju/worker/debughooks"
+	"github.com/juju/juju/worker/dependency"
+	workerlogger "github.com/juju/juju/worker/logger"
+	"github.com/juju/juju/worker/logsender"
+	"github.com/juju/juju/worker/proxyupdater"
+	"github.com/juju/juju/worker/uniter"
+	"github.com/juju/juju/worker/upgrader"
+)
+
+// Agent is the unit agent, as seen by the workers it runs.
+type Agent interface {
+	cmdutil.AgentConfigSource
+	apiaddressupdater.APIAddressSetter
+	cacertupdater.CACertSetter
+	agentconfigupdater.AgentConfigSettingsSetter
+}
+
+// ManifoldsConfig holds the dependencies and configuration needed to
+// create the unit agent's manifolds.
+type ManifoldsConfig struct {
+	// Agent is the unit agent.
+	Agent Agent
+
+	// OpenAPI opens the unit agent's API connection using the
+	// given configuration.
+	OpenAPI func(agent.Config) (*api.State, error)
+
+	// LogSource, if not nil, holds the log records to be sent to
+	// the state server.
+	LogSource logsender.LogRecordCh
+}
+
+// The names of the unit agent's manifolds.
+const (
+	AgentName              = "agent"
+	APICallerName          = "api-caller"
+	MachineLockName        = "machine-lock"
+	ProxyUpdaterName       = "proxy-updater"
+	UpgraderName           = "upgrader"
+	LoggerName             = "logger"
+	UniterName             = "uniter"
+	APIAddressUpdaterName  = "api-address-updater"
+	CACertUpdaterName      = "cacert-updater"
+	AgentConfigUpdaterName = "agent-config-updater"
+	LogSenderName          = "log-sender"
+	DebugHooksName         = "debug-hooks"
+)
+
+// Manifolds returns the manifolds of the workers run by the unit agent.
+func Manifolds(config ManifoldsConfig) dependency.Manifolds {
+	manifolds := dependency.Manifolds{
+		AgentName: cmdutil.AgentManifold(config.Agent),
+		APICallerName: cmdutil.APICallerManifold(cmdutil.APICallerManifoldConfig{
+			AgentName: AgentName,
+			Open:      config.OpenAPI,
+		}),
+		MachineLockName: cmdutil.MachineLockManifold(AgentName),
+
+		ProxyUpdaterName: apiManifold(func(st *api.State, _ agent.Config) (worker.Worker, error) {
+			return proxyupdater.New(st.ProxyUpdater(), false), nil
+		}),
+		UpgraderName: apiManifold(func(st *api.State, agentConfig agent.Config) (worker.Worker, error) {
+			return upgrader.NewUpgrader(
+				st.Upgrader(),
+				maintenancewindow.NewClient(st),
+				agentConfig,
+				agentConfig.UpgradedToVersion(),
+				func() bool { return false },
+			), nil
+		}),
+		LoggerName: apiManifold(func(st *api.State, agentConfig agent.Config) (worker.Worker, error) {
+			return workerlogger.NewLogger(st.Logger(), agentConfig), nil
+		}),
+		UniterName: uniterManifold(),
+		APIAddressUpdaterName: apiManifold(func(st *api.State, _ agent.Config) (worker.Worker, error) {
+			uniterFacade, err := st.Uniter()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return apiaddressupdater.NewAPIAddressUpdater(uniterFacade, config.Agent), nil
+		}),
+		CACertUpdaterName: apiManifold(func(st *api.State, _ agent.Config) (worker.Worker, error) {
+			currentCACert := func() string { return config.Agent.CurrentConfig().CACert() }
+			return cacertupdater.NewCACertUpdater(st.Environment(), config.Agent, currentCACert), nil
+		}),
+		AgentConfigUpdaterName: apiManifold(func(st *api.State, _ agent.Config) (worker.Worker, error) {
+			return agentconfigupdater.NewAgentConfigUpdater(st.Agent(), config.Agent), nil
+		}),
+	}
+	if config.LogSource != nil {
+		manifolds[LogSenderName] = apiManifold(func(st *api.State, _ agent.Config) (worker.Worker, error) {
+			return cmdutil.NewLogSender(config.LogSource, st), nil
+		})
+	}
+	// Debug-hooks sessions run hooks in bash, so are not
+	// supported on windows.
+	if version.Current.OS != version.Windows {
+		manifolds[DebugHooksName] = apiManifold(func(st *api.State, agentConfig agent.Config) (worker.Worker, error) {
+			unitTag, ok := agentConfig.Tag().(names.UnitTag)
+			if !ok {
+				return nil, errors.Errorf("expected a unit tag, got %q", agentConfig.Tag())
+			}
+			return debughooks.New(unitTag.Id(), func() (debughooks.Listener, error) {
+				return st.ListenDebugHooks()
+			}), nil
+		})
+	}
+	return manifolds
+}
+
+// apiManifold returns a manifold whose worker is started, by the given
+// function, with the agent's API connection and current configuration.
+func apiManifold(start func(*api.State, agent.Config) (worker.Worker, error)) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{AgentName, APICallerName},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var source cmdutil.AgentConfigSource
+			if err := getResource(AgentName, &source); err != nil {
+				return nil, err
+			}
+			var st *api.State
+			if err := getResource(APICallerName, &st); err != nil {
+				return nil, err
+			}
+			return start(st, source.CurrentConfig())
+		},
+	}
+}
+
+// uniterManifold returns a manifold whose worker runs the unit's hooks,
+// holding the machine lock while it does so.
+func uniterManifold() dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{AgentName, APICallerName, MachineLockName},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var source cmdutil.AgentConfigSource
+			if err := getResource(AgentName, &source); err != nil {
+				return nil, err
+			}
+			var st *api.State
+			if err := getResource(APICallerName, &st); err != nil {
+				return nil, err
+			}
+			var hookLock *fslock.Lock
+			if err := getResource(MachineLockName, &hookLock); err != nil {
+				return nil, err
+			}
+			agentConfig := source.CurrentConfig()
+			unitTag, ok := agentConfig.Tag().(names.UnitTag)
+			if !ok {
+				return nil, errors.Errorf("expected a unit tag, got %q", agentConfig.Tag())
+			}
+			uniterFacade, err := st.Uniter()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return uniter.NewUniter(
+				uniterFacade,
+				unitTag,
+				st.LeadershipManager(),
+				agentConfig.DataDir(),
+				agentConfig.Value(agent.WorkloadContainer),
+				hookLock,
+			), nil
+		},
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	cmdutil "github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/worker/introspection"
+)
+
+// EngineReportCommand shows the state of the workers run by an agent
+// on this machine.
+type EngineReportCommand struct {
+	cmd.CommandBase
+	out      cmd.Output
+	dataDir  string
+	tag      names.Tag
+	provider bool
+}
+
+const engineReportDoc = `
+Show the state of each worker run by the dependency engine of a running
+agent on this machine: whether it is started or stopped, the resources
+it depends on, the error it last stopped with, and how many times it has
+been started.
+
+With --provider, show instead the health of the provider APIs called by
+the agent: for each endpoint, how many calls of each kind have been made,
+how many failed, their latency, and whether the endpoint has rate limited
+the agent. Only state server agents trace their provider API calls.
+
+agent-tag is the tag of the agent:
+ i.e.  machine-0
+       unit-ubuntu-0
+`
+
+// Info returns usage information for the command.
+func (c *EngineReportCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "engine-report",
+		Args:    "<agent-tag>",
+		Purpose: "show the state of a running agent's workers",
+		Doc:     engineReportDoc,
+	}
+}
+
+func (c *EngineReportCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+	f.StringVar(&c.dataDir, "data-dir", cmdutil.DataDir, "directory for juju data")
+	f.BoolVar(&c.provider, "provider", false, "show the health of the provider APIs called by the agent")
+}
+
+func (c *EngineReportCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("missing agent-tag")
+	}
+	tag, err := names.ParseTag(args[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch tag.(type) {
+	case names.MachineTag, names.UnitTag:
+	default:
+		return errors.Errorf("%q is not a machine or unit tag", args[0])
+	}
+	c.tag = tag
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *EngineReportCommand) Run(ctx *cmd.Context) error {
+	socketPath := introspection.SocketPath(c.dataDir, c.tag)
+	if c.provider {
+		report, err := introspection.ProviderReport(socketPath)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return c.out.Write(ctx, report)
+	}
+	report, err := introspection.Report(socketPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return c.out.Write(ctx, report)
+}
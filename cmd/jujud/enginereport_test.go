@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	cmdutil "github.com/juju/juju/cmd/jujud/util"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/introspection"
+)
+
+type EngineReportSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&EngineReportSuite{})
+
+func (s *EngineReportSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(&cmdutil.DataDir, c.MkDir())
+}
+
+func (*EngineReportSuite) TestArgParsing(c *gc.C) {
+	for i, test := range []struct {
+		title    string
+		args     []string
+		errMatch string
+		tag      names.Tag
+	}{{
+		title:    "no args",
+		errMatch: "missing agent-tag",
+	}, {
+		title:    "invalid tag",
+		args:     []string{"foo"},
+		errMatch: `"foo" is not a valid tag`,
+	}, {
+		title:    "not an agent tag",
+		args:     []string{"service-foo"},
+		errMatch: `"service-foo" is not a machine or unit tag`,
+	}, {
+		title:    "too many args",
+		args:     []string{"machine-0", "unit-foo-0"},
+		errMatch: `unrecognized args: \["unit-foo-0"\]`,
+	}, {
+		title: "machine tag",
+		args:  []string{"machine-0"},
+		tag:   names.NewMachineTag("0"),
+	}, {
+		title: "unit tag",
+		args:  []string{"unit-foo-0"},
+		tag:   names.NewUnitTag("foo/0"),
+	}} {
+		c.Logf("%d: %s", i, test.title)
+		command := &EngineReportCommand{}
+		err := testing.InitCommand(command, test.args)
+		if test.errMatch == "" {
+			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(command.tag, gc.Equals, test.tag)
+		} else {
+			c.Assert(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *EngineReportSuite) TestNoAgent(c *gc.C) {
+	_, err := testing.RunCommand(c, &EngineReportCommand{}, "unit-foo-0")
+	c.Assert(err, gc.ErrorMatches, "cannot connect to agent: .*")
+}
+
+func (s *EngineReportSuite) TestReport(c *gc.C) {
+	tag := names.NewUnitTag("foo/0")
+	socketPath := introspection.SocketPath(cmdutil.DataDir, tag)
+	err := os.MkdirAll(filepath.Dir(socketPath), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	w, err := introspection.NewWorker(fakeReporter{dependency.EngineReport{
+		State: "started",
+		Manifolds: map[string]dependency.WorkerReport{
+			"uniter": {
+				State:      "started",
+				Inputs:     []string{"agent", "api-caller"},
+				StartCount: 1,
+			},
+		},
+	}}, socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	ctx, err := testing.RunCommand(c, &EngineReportCommand{}, "unit-foo-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+state: started
+manifolds:
+  uniter:
+    state: started
+    inputs:
+    - agent
+    - api-caller
+    start-count: 1
+`[1:])
+}
+
+func (s *EngineReportSuite) TestProviderReport(c *gc.C) {
+	tag := names.NewMachineTag("0")
+	socketPath := introspection.SocketPath(cmdutil.DataDir, tag)
+	err := os.MkdirAll(filepath.Dir(socketPath), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	w, err := introspection.NewWorker(fakeReporter{}, socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	// The agent has not traced any provider API calls.
+	ctx, err := testing.RunCommand(c, &EngineReportCommand{}, "machine-0", "--provider")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "{}\n")
+}
+
+type fakeReporter struct {
+	report dependency.EngineReport
+}
+
+func (r fakeReporter) Report() dependency.EngineReport {
+	return r.report
+}
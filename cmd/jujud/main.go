@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/utils/exec"
 	"github.com/juju/utils/featureflag"
@@ -24,6 +25,7 @@ import (
 	"github.com/juju/juju/juju/sockets"
 	// Import the providers.
 	_ "github.com/juju/juju/provider/all"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
 )
 
@@ -50,6 +52,10 @@ const (
 	exit_panic = 3
 )
 
+// logsBufferSize is the maximum number of log records an agent holds
+// while waiting to send them to the state server.
+const logsBufferSize = 1024 * 1024
+
 func getenv(name string) (string, error) {
 	value := os.Getenv(name)
 	if value == "" {
@@ -118,14 +124,22 @@ func jujuDMain(args []string, ctx *cmd.Context) (code int, err error) {
 	jujud.Log.Factory = &writerFactory{}
 	jujud.Register(&BootstrapCommand{})
 
+	// Log records are buffered from the start so that they can be
+	// sent to the state server once the agent has connected to the API.
+	bufferedLogs, err := logsender.InstallBufferedLogWriter(logsBufferSize)
+	if err != nil {
+		return 1, errors.Trace(err)
+	}
+
 	// TODO(katco-): AgentConf type is doing too much. The
 	// MachineAgent type has called out the seperate concerns; the
 	// AgentConf should be split up to follow suite.
 	var agentConf agentcmd.AgentConf
-	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf)
+	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf, bufferedLogs)
 	jujud.Register(agentcmd.NewMachineAgentCmd(machineAgentFactory, &agentConf, &agentConf))
 
-	jujud.Register(&UnitAgent{})
+	jujud.Register(&UnitAgent{bufferedLogs: bufferedLogs})
+	jujud.Register(&EngineReportCommand{})
 	code = cmd.Main(jujud, ctx, args[1:])
 	return code, nil
 }
@@ -3,4 +3,9 @@ package reboot
 var (
 	Timeout = &timeout
 	TmpFile = &tmpFile
+
+	PollInterval  = &pollInterval
+	SetStartOrder = &setStartOrder
+
+	RestartOrder = restartOrder
 )
@@ -4,6 +4,9 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -13,15 +16,19 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/reboot"
+	"github.com/juju/juju/api/rebootrequests"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/factory"
+	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/instance"
 )
 
 var logger = loggo.GetLogger("juju.cmd.jujud.reboot")
 var timeout = time.Duration(10 * time.Minute)
 var rebootAfter = 15
+var pollInterval = time.Second
+var setStartOrder = lxc.SetStartOrder
 
 func runCommand(args []string) error {
 	_, err := exec.Command(args[0], args[1:]...).Output()
@@ -40,12 +47,14 @@ var tmpFile = func() (*os.File, error) {
 }
 
 // Reboot implements the ExecuteReboot command which will reboot a machine
-// once all containers have shut down, or a timeout is reached
+// once all containers have acknowledged the reboot and shut down, or a
+// timeout is reached
 type Reboot struct {
 	acfg     agent.Config
 	apistate *api.State
 	tag      names.MachineTag
 	st       *reboot.State
+	requests *rebootrequests.State
 }
 
 func NewRebootWaiter(apistate *api.State, acfg agent.Config) (*Reboot, error) {
@@ -53,6 +62,10 @@ func NewRebootWaiter(apistate *api.State, acfg agent.Config) (*Reboot, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	requests, err := apistate.RebootRequests()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	tag, ok := acfg.Tag().(names.MachineTag)
 	if !ok {
 		return nil, errors.Errorf("Expected names.MachineTag, got: %T --> %v", acfg.Tag(), acfg.Tag())
@@ -60,19 +73,36 @@ func NewRebootWaiter(apistate *api.State, acfg agent.Config) (*Reboot, error) {
 	return &Reboot{
 		acfg:     acfg,
 		st:       rebootState,
+		requests: requests,
 		tag:      tag,
 		apistate: apistate,
 	}, nil
 }
 
-// ExecuteReboot will wait for all running containers to stop, and then execute
-// a shutdown or a reboot (based on the action param)
+// ExecuteReboot will wait for all containers to acknowledge the reboot
+// and for all running containers to stop, and then execute a shutdown
+// or a reboot (based on the action param). The waits share a single
+// timeout. When rebooting, the LXC containers that were running are
+// set to restart in the order they were created once the machine is
+// back up.
 func (r *Reboot) ExecuteReboot(action params.RebootAction) error {
-	err := r.waitForContainersOrTimeout()
+	running := r.runningContainersOfType(instance.LXC)
+
+	deadline := time.Now().Add(timeout)
+	err := r.waitForAcknowledgementsOrTimeout(deadline)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = r.waitForContainersOrTimeout(deadline)
 	if err != nil {
 		return errors.Trace(err)
 	}
 
+	if action == params.ShouldReboot {
+		setRestartOrder(running)
+	}
+
 	err = scheduleAction(action, rebootAfter)
 	if err != nil {
 		return errors.Trace(err)
@@ -85,33 +115,112 @@ func (r *Reboot) ExecuteReboot(action params.RebootAction) error {
 	return nil
 }
 
-func (r *Reboot) runningContainers() ([]instance.Instance, error) {
-	runningInstances := []instance.Instance{}
-
-	for _, val := range instance.ContainerTypes {
-		managerConfig := container.ManagerConfig{container.ConfigName: container.DefaultNamespace}
-		if namespace := r.acfg.Value(agent.Namespace); namespace != "" {
-			managerConfig[container.ConfigName] = namespace
-		}
-		cfg := container.ManagerConfig(managerConfig)
-		manager, err := factory.NewContainerManager(val, cfg, nil)
+// waitForAcknowledgementsOrTimeout waits until every container on the
+// machine has stopped running hooks and acknowledged the reboot, so
+// that no container is stopped in the middle of a hook.
+func (r *Reboot) waitForAcknowledgementsOrTimeout(deadline time.Time) error {
+	timedOut := time.After(deadline.Sub(time.Now()))
+	for {
+		pending, err := r.requests.PendingAcknowledgements()
 		if err != nil {
-			logger.Warningf("Failed to get manager for container type %v: %v", val, err)
-			continue
+			return errors.Trace(err)
 		}
-		if !manager.IsInitialized() {
-			continue
+		if len(pending) == 0 {
+			return nil
 		}
-		instances, err := manager.ListContainers()
-		if err != nil {
-			logger.Warningf("Failed to list containers: %v", err)
+		logger.Infof("waiting for containers to acknowledge reboot: %v", pending)
+		select {
+		case <-timedOut:
+			logger.Infof("Timeout reached waiting for containers to acknowledge reboot")
+			return nil
+		case <-time.After(pollInterval):
 		}
-		runningInstances = append(runningInstances, instances...)
+	}
+}
+
+func (r *Reboot) runningContainers() ([]instance.Instance, error) {
+	runningInstances := []instance.Instance{}
+
+	for _, val := range instance.ContainerTypes {
+		runningInstances = append(runningInstances, r.runningContainersOfType(val)...)
 	}
 	return runningInstances, nil
 }
 
-func (r *Reboot) waitForContainersOrTimeout() error {
+// runningContainersOfType returns the running containers of the given
+// type. Containers that cannot be listed are logged and ignored.
+func (r *Reboot) runningContainersOfType(containerType instance.ContainerType) []instance.Instance {
+	managerConfig := container.ManagerConfig{container.ConfigName: container.DefaultNamespace}
+	if namespace := r.acfg.Value(agent.Namespace); namespace != "" {
+		managerConfig[container.ConfigName] = namespace
+	}
+	cfg := container.ManagerConfig(managerConfig)
+	manager, err := factory.NewContainerManager(containerType, cfg, nil)
+	if err != nil {
+		logger.Warningf("Failed to get manager for container type %v: %v", containerType, err)
+		return nil
+	}
+	if !manager.IsInitialized() {
+		return nil
+	}
+	instances, err := manager.ListContainers()
+	if err != nil {
+		logger.Warningf("Failed to list containers: %v", err)
+	}
+	return instances
+}
+
+// setRestartOrder sets the given LXC containers to be started, when
+// the machine boots, in the order they were created. Failures are
+// logged rather than returned, as they should not prevent the reboot.
+func setRestartOrder(containers []instance.Instance) {
+	ids := make([]instance.Id, len(containers))
+	for i, inst := range containers {
+		ids[i] = inst.Id()
+	}
+	ids = restartOrder(ids)
+	for i, id := range ids {
+		// Containers with a higher start order are started first.
+		if err := setStartOrder(string(id), len(ids)-i); err != nil {
+			logger.Warningf("cannot set start order of container %q: %v", id, err)
+		}
+	}
+}
+
+// restartOrder returns the given container instance ids ordered by
+// container number, which is the order the containers were created.
+func restartOrder(ids []instance.Id) []instance.Id {
+	ordered := make([]instance.Id, len(ids))
+	copy(ordered, ids)
+	sort.Sort(byContainerNumber(ordered))
+	return ordered
+}
+
+type byContainerNumber []instance.Id
+
+func (ids byContainerNumber) Len() int      { return len(ids) }
+func (ids byContainerNumber) Swap(i, j int) { ids[i], ids[j] = ids[j], ids[i] }
+func (ids byContainerNumber) Less(i, j int) bool {
+	ni, nj := containerNumber(ids[i]), containerNumber(ids[j])
+	if ni != nj {
+		return ni < nj
+	}
+	return ids[i] < ids[j]
+}
+
+// containerNumber returns the number at the end of a container's
+// instance id, such as 3 for "juju-machine-1-lxc-3", or -1 if there
+// is none.
+func containerNumber(id instance.Id) int {
+	s := string(id)
+	n, err := strconv.Atoi(s[strings.LastIndex(s, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func (r *Reboot) waitForContainersOrTimeout(deadline time.Time) error {
 	c := make(chan error, 1)
 	quit := make(chan bool, 1)
 	go func() {
@@ -137,7 +246,7 @@ func (r *Reboot) waitForContainersOrTimeout() error {
 	}()
 
 	select {
-	case <-time.After(timeout):
+	case <-time.After(deadline.Sub(time.Now())):
 
 		// Containers are still up after timeout. C'est la vie
 		logger.Infof("Timeout reached waiting for containers to shutdown")
@@ -12,6 +12,7 @@ import (
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/jujud/reboot"
+	coretesting "github.com/juju/juju/testing"
 )
 
 // on linux we use the "at" command to schedule a reboot
@@ -90,6 +91,11 @@ func (s *RebootSuite) TestRebootWithContainers(c *gc.C) {
 	testing.PatchExecutable(c, s, "lxc-ls", lxcLsScript)
 	testing.PatchExecutable(c, s, "lxc-info", lxcInfoScript)
 	expectedRebootParams := s.rebootCommandParams(c)
+	startOrders := make(map[string]int)
+	s.PatchValue(reboot.SetStartOrder, func(name string, order int) error {
+		startOrders[name] = order
+		return nil
+	})
 
 	// Timeout after 5 seconds
 	s.PatchValue(reboot.Timeout, time.Duration(5*time.Second))
@@ -100,6 +106,46 @@ func (s *RebootSuite) TestRebootWithContainers(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	testing.AssertEchoArgs(c, rebootBin, expectedRebootParams...)
 	ft.File{s.rebootScriptName, expectedRebootScript, 0755}.Check(c, s.tmpDir)
+	c.Assert(startOrders, jc.DeepEquals, map[string]int{"juju-machine-1-lxc-0": 1})
+}
+
+func (s *RebootSuite) TestShutdownWithContainersKeepsStartOrder(c *gc.C) {
+	testing.PatchExecutable(c, s, "lxc-ls", lxcLsScript)
+	testing.PatchExecutable(c, s, "lxc-info", lxcInfoScript)
+	s.PatchValue(reboot.SetStartOrder, func(name string, order int) error {
+		c.Errorf("unexpected start order %d set for %q", order, name)
+		return nil
+	})
+
+	s.PatchValue(reboot.Timeout, time.Duration(5*time.Second))
+	w, err := reboot.NewRebootWaiter(s.st, s.acfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = w.ExecuteReboot(params.ShouldShutdown)
+	c.Assert(err, jc.ErrorIsNil)
+	testing.AssertEchoArgs(c, rebootBin, s.shutdownCommandParams(c)...)
+}
+
+func (s *RebootSuite) TestRebootTimeoutIsShared(c *gc.C) {
+	testing.PatchExecutable(c, s, "lxc-ls", lxcLsScript)
+	testing.PatchExecutable(c, s, "lxc-info", lxcInfoScriptMissbehave)
+	s.addContainer(c)
+	err := s.machine.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Neither the acknowledgements nor the containers arrive, so
+	// each wait would take the whole timeout were it not shared.
+	timeout := 2 * time.Second
+	s.PatchValue(reboot.Timeout, timeout)
+	s.PatchValue(reboot.PollInterval, coretesting.ShortWait/10)
+	w, err := reboot.NewRebootWaiter(s.st, s.acfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	start := time.Now()
+	err = w.ExecuteReboot(params.ShouldReboot)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(time.Since(start) < timeout*3/2, jc.IsTrue)
+	testing.AssertEchoArgs(c, rebootBin, s.rebootCommandParams(c)...)
 }
 
 func (s *RebootSuite) TestRebootWithMissbehavingContainers(c *gc.C) {
@@ -139,3 +185,36 @@ func (s *RebootSuite) TestShutdownNoContainers(c *gc.C) {
 	testing.AssertEchoArgs(c, rebootBin, expectedShutdownParams...)
 	ft.File{s.rebootScriptName, expectedShutdownScript, 0755}.Check(c, s.tmpDir)
 }
+
+func (s *RebootSuite) TestRebootWithAcknowledgedContainers(c *gc.C) {
+	container := s.addContainer(c)
+	err := s.machine.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+	err = container.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The timeout is long enough that the test would fail
+	// were the reboot to wait for it.
+	s.PatchValue(reboot.Timeout, coretesting.LongWait*10)
+	w, err := reboot.NewRebootWaiter(s.st, s.acfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = w.ExecuteReboot(params.ShouldReboot)
+	c.Assert(err, jc.ErrorIsNil)
+	testing.AssertEchoArgs(c, rebootBin, s.rebootCommandParams(c)...)
+}
+
+func (s *RebootSuite) TestRebootWithUnacknowledgedContainers(c *gc.C) {
+	s.addContainer(c)
+	err := s.machine.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.PatchValue(reboot.Timeout, coretesting.ShortWait)
+	s.PatchValue(reboot.PollInterval, coretesting.ShortWait/10)
+	w, err := reboot.NewRebootWaiter(s.st, s.acfg)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = w.ExecuteReboot(params.ShouldReboot)
+	c.Assert(err, jc.ErrorIsNil)
+	testing.AssertEchoArgs(c, rebootBin, s.rebootCommandParams(c)...)
+}
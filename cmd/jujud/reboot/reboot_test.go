@@ -14,7 +14,9 @@ import (
 	"github.com/juju/juju/api"
 	// "github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/jujud/reboot"
+	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/version"
 )
@@ -29,6 +31,7 @@ type RebootSuite struct {
 	acfg    agent.Config
 	mgoInst testing.MgoInstance
 	st      *api.State
+	machine *state.Machine
 
 	tmpDir           string
 	rebootScriptName string
@@ -48,6 +51,8 @@ func (s *RebootSuite) SetUpTest(c *gc.C) {
 		script := s.rebootScript(c)
 		return os.Create(script)
 	})
+	// Never touch the configuration of real containers.
+	s.PatchValue(reboot.SetStartOrder, func(string, int) error { return nil })
 
 	s.mgoInst.EnableAuth = true
 	err = s.mgoInst.Start(coretesting.Certs)
@@ -62,7 +67,7 @@ func (s *RebootSuite) SetUpTest(c *gc.C) {
 		Password:          "fake",
 		Environment:       s.State.EnvironTag(),
 	}
-	s.st, _ = s.OpenAPIAsNewMachine(c)
+	s.st, s.machine = s.OpenAPIAsNewMachine(c)
 
 	s.acfg, err = agent.NewAgentConfig(configParams)
 	c.Assert(err, jc.ErrorIsNil)
@@ -73,6 +78,31 @@ func (s *RebootSuite) TearDownTest(c *gc.C) {
 	s.JujuConnSuite.TearDownTest(c)
 }
 
+func (s *RebootSuite) addContainer(c *gc.C) *state.Machine {
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: coretesting.FakeDefaultSeries,
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, s.machine.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	return container
+}
+
 func (s *RebootSuite) rebootScript(c *gc.C) string {
 	return filepath.Join(s.tmpDir, s.rebootScriptName)
 }
+
+func (s *RebootSuite) TestRestartOrder(c *gc.C) {
+	ids := []instance.Id{
+		"juju-machine-1-lxc-10",
+		"juju-machine-1-lxc-2",
+		"juju-machine-1-lxc-0",
+		"other",
+	}
+	c.Assert(reboot.RestartOrder(ids), jc.DeepEquals, []instance.Id{
+		"other",
+		"juju-machine-1-lxc-0",
+		"juju-machine-1-lxc-2",
+		"juju-machine-1-lxc-10",
+	})
+	c.Assert(ids[0], gc.Equals, instance.Id("juju-machine-1-lxc-10"))
+}
@@ -18,18 +18,15 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
 	agentcmd "github.com/juju/juju/cmd/jujud/agent"
+	"github.com/juju/juju/cmd/jujud/agent/unit"
 	cmdutil "github.com/juju/juju/cmd/jujud/util"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
-	"github.com/juju/juju/worker"
-	"github.com/juju/juju/worker/apiaddressupdater"
-	workerlogger "github.com/juju/juju/worker/logger"
-	"github.com/juju/juju/worker/proxyupdater"
-	"github.com/juju/juju/worker/rsyslog"
-	"github.com/juju/juju/worker/uniter"
-	"github.com/juju/juju/worker/upgrader"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/logsender"
 )
 
 var agentLogger = loggo.GetLogger("juju.jujud")
@@ -40,9 +37,10 @@ type UnitAgent struct {
 	tomb tomb.Tomb
 	agentcmd.AgentConf
 	UnitName     string
-	runner       worker.Runner
+	engine       dependency.Engine
 	setupLogging func(agent.Config) error
 	logToStdErr  bool
+	bufferedLogs logsender.LogRecordCh
 }
 
 // Info returns usage information for the command.
@@ -70,13 +68,17 @@ func (a *UnitAgent) Init(args []string) error {
 	if err := a.AgentConf.CheckArgs(args); err != nil {
 		return err
 	}
-	a.runner = worker.NewRunner(cmdutil.IsFatal, cmdutil.MoreImportant)
+	engine, err := dependency.NewEngine(cmdutil.NewEngineConfig())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	a.engine = engine
 	return nil
 }
 
 // Stop stops the unit agent.
 func (a *UnitAgent) Stop() error {
-	a.runner.Kill()
+	a.engine.Kill()
 	return a.tomb.Wait()
 }
 
@@ -107,27 +109,43 @@ func (a *UnitAgent) Run(ctx *cmd.Context) error {
 	}
 
 	network.InitializeFromConfig(agentConfig)
-	a.runner.StartWorker("api", a.APIWorkers)
-	err := cmdutil.AgentDone(logger, a.runner.Wait())
+	if err := agentcmd.ApplyAgentConfigSettings(agentConfig); err != nil {
+		logger.Warningf("cannot apply agent config settings: %v", err)
+	}
+	manifolds := unit.Manifolds(unit.ManifoldsConfig{
+		Agent:     a,
+		OpenAPI:   a.openAPI,
+		LogSource: a.bufferedLogs,
+	})
+	manifolds[introspectionName] = cmdutil.IntrospectionManifold(unit.AgentName, a.engine)
+	if err := dependency.Install(a.engine, manifolds); err != nil {
+		a.engine.Kill()
+		if err := a.engine.Wait(); err != nil {
+			logger.Errorf("while stopping unit agent workers: %v", err)
+		}
+		return errors.Trace(err)
+	}
+	err := cmdutil.AgentDone(logger, a.engine.Wait())
 	a.tomb.Kill(err)
 	return err
 }
 
-func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
-	agentConfig := a.CurrentConfig()
-	dataDir := agentConfig.DataDir()
-	hookLock, err := cmdutil.HookExecutionLock(dataDir)
-	if err != nil {
-		return nil, err
-	}
-	st, entity, err := agentcmd.OpenAPIState(agentConfig, a)
+// introspectionName is the name of the manifold serving the report on
+// the unit agent's workers.
+const introspectionName = "introspection"
+
+// openAPI opens the unit agent's API connection, and records the
+// environment and the agent's version before any worker uses it.
+func (a *UnitAgent) openAPI(agentConfig agent.Config) (_ *api.State, err error) {
+	st, _, err := agentcmd.OpenAPIState(agentConfig, a)
 	if err != nil {
 		return nil, err
 	}
-	unitTag, err := names.ParseUnitTag(entity.Tag())
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
+	defer func() {
+		if err != nil {
+			st.Close()
+		}
+	}()
 	// Ensure that the environment uuid is stored in the agent config.
 	// Luckily the API has it recorded for us after we connect.
 	if agentConfig.Environment().Id() == "" {
@@ -153,42 +171,7 @@ func (a *UnitAgent) APIWorkers() (worker.Worker, error) {
 	if err := st.Upgrader().SetVersion(agentConfig.Tag().String(), currentTools.Version); err != nil {
 		return nil, errors.Annotate(err, "cannot set unit agent version")
 	}
-
-	runner := worker.NewRunner(cmdutil.ConnectionIsFatal(logger, st), cmdutil.MoreImportant)
-	// start proxyupdater first to ensure proxy settings are correct
-	runner.StartWorker("proxyupdater", func() (worker.Worker, error) {
-		return proxyupdater.New(st.Environment(), false), nil
-	})
-	runner.StartWorker("upgrader", func() (worker.Worker, error) {
-		return upgrader.NewUpgrader(
-			st.Upgrader(),
-			agentConfig,
-			agentConfig.UpgradedToVersion(),
-			func() bool { return false },
-		), nil
-	})
-	runner.StartWorker("logger", func() (worker.Worker, error) {
-		return workerlogger.NewLogger(st.Logger(), agentConfig), nil
-	})
-	runner.StartWorker("uniter", func() (worker.Worker, error) {
-		uniterFacade, err := st.Uniter()
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return uniter.NewUniter(uniterFacade, unitTag, st.LeadershipManager(), dataDir, hookLock), nil
-	})
-
-	runner.StartWorker("apiaddressupdater", func() (worker.Worker, error) {
-		uniterFacade, err := st.Uniter()
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		return apiaddressupdater.NewAPIAddressUpdater(uniterFacade, a), nil
-	})
-	runner.StartWorker("rsyslog", func() (worker.Worker, error) {
-		return cmdutil.NewRsyslogConfigWorker(st.Rsyslog(), agentConfig, rsyslog.RsyslogModeForwarding)
-	})
-	return cmdutil.NewCloseWorker(logger, runner, st), nil
+	return st, nil
 }
 
 func (a *UnitAgent) Tag() names.Tag {
@@ -13,26 +13,26 @@ import (
 	"time"
 
 	"github.com/juju/cmd"
+	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
-	apirsyslog "github.com/juju/juju/api/rsyslog"
 	agentcmd "github.com/juju/juju/cmd/jujud/agent"
 	agenttesting "github.com/juju/juju/cmd/jujud/agent/testing"
-	cmdutil "github.com/juju/juju/cmd/jujud/util"
 	envtesting "github.com/juju/juju/environs/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
-	"github.com/juju/juju/lease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
-	"github.com/juju/juju/worker/rsyslog"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/upgrader"
 )
 
@@ -57,9 +57,10 @@ func (s *UnitSuite) TearDownSuite(c *gc.C) {
 func (s *UnitSuite) SetUpTest(c *gc.C) {
 	s.GitSuite.SetUpTest(c)
 	s.AgentSuite.SetUpTest(c)
-	// If we don't have a lease manager running somewhere, the API calls hang.
-	workerLoop := lease.WorkerLoop(s.State)
-	s.leaseWorker = worker.NewSimpleWorker(workerLoop)
+	// If we don't have a lease service running somewhere, the API calls hang.
+	leaseWorker, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
+	s.leaseWorker = leaseWorker
 }
 
 func (s *UnitSuite) TearDownTest(c *gc.C) {
@@ -316,24 +317,32 @@ func (s *UnitSuite) TestOpenStateFails(c *gc.C) {
 	s.AssertCannotOpenState(c, conf.Tag(), conf.DataDir())
 }
 
-func (s *UnitSuite) TestRsyslogConfigWorker(c *gc.C) {
-	created := make(chan rsyslog.RsyslogMode, 1)
-	s.PatchValue(&cmdutil.NewRsyslogConfigWorker, func(_ *apirsyslog.State, _ agent.Config, mode rsyslog.RsyslogMode) (worker.Worker, error) {
-		created <- mode
-		return newDummyWorker(), nil
-	})
-
+func (s *UnitSuite) TestLogSender(c *gc.C) {
 	_, unit, _, _ := s.primeAgent(c)
 	a := s.newAgent(c, unit)
+	a.bufferedLogs = make(logsender.LogRecordCh, 1)
+	a.bufferedLogs <- &logsender.LogRecord{
+		Time:     time.Now(),
+		Module:   "juju.unit.test",
+		Location: "unit_test.go:1",
+		Level:    loggo.INFO,
+		Message:  "unit agent log record",
+	}
 	go func() { c.Check(a.Run(nil), gc.IsNil) }()
 	defer func() { c.Check(a.Stop(), gc.IsNil) }()
 
-	select {
-	case <-time.After(coretesting.LongWait):
-		c.Fatalf("timeout while waiting for rsyslog worker to be created")
-	case mode := <-created:
-		c.Assert(mode, gc.Equals, rsyslog.RsyslogModeForwarding)
+	logsColl := s.State.MongoSession().DB("logs").C("logs")
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		var docs []bson.M
+		err := logsColl.Find(bson.M{"x": "unit agent log record"}).All(&docs)
+		c.Assert(err, jc.ErrorIsNil)
+		if len(docs) > 0 {
+			c.Assert(docs[0]["n"], gc.Equals, unit.Tag().String())
+			c.Assert(docs[0]["m"], gc.Equals, "juju.unit.test")
+			return
+		}
 	}
+	c.Fatalf("timeout while waiting for log record to be sent")
 }
 
 func (s *UnitSuite) TestAgentSetsToolsVersion(c *gc.C) {
@@ -411,10 +420,3 @@ func runWithTimeout(r runner) error {
 	err := r.Stop()
 	return fmt.Errorf("timed out waiting for agent to finish; stop error: %v", err)
 }
-
-func newDummyWorker() worker.Worker {
-	return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
-		<-stop
-		return nil
-	})
-}
@@ -0,0 +1,200 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package util
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/fslock"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/introspection"
+)
+
+// NewEngineConfig returns the configuration of the dependency engines
+// run by the agents. A failed worker is first restarted after
+// worker.RestartDelay, like the workers run by a worker.Runner.
+func NewEngineConfig() dependency.EngineConfig {
+	return dependency.EngineConfig{
+		IsFatal:       IsFatal,
+		MoreImportant: MoreImportant,
+		ErrorDelay:    worker.RestartDelay,
+		MaxErrorDelay: time.Minute,
+		BounceDelay:   10 * time.Millisecond,
+	}
+}
+
+// AgentConfigSource exposes the current configuration of an agent.
+type AgentConfigSource interface {
+	CurrentConfig() agent.Config
+}
+
+// AgentManifold returns a manifold whose worker exposes the given
+// AgentConfigSource, so that other workers can declare their
+// dependency on the agent's configuration.
+func AgentManifold(source AgentConfigSource) dependency.Manifold {
+	return dependency.Manifold{
+		Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+			return &agentWorker{
+				Worker: worker.NewNoOpWorker(),
+				source: source,
+			}, nil
+		},
+		Output: func(in worker.Worker, out interface{}) error {
+			inWorker, _ := in.(*agentWorker)
+			outPointer, _ := out.(*AgentConfigSource)
+			if inWorker == nil || outPointer == nil {
+				return errors.Errorf("expected %T->%T; got %T->%T", inWorker, outPointer, in, out)
+			}
+			*outPointer = inWorker.source
+			return nil
+		},
+	}
+}
+
+type agentWorker struct {
+	worker.Worker
+	source AgentConfigSource
+}
+
+// APICallerManifoldConfig holds the information needed by the manifold
+// returned by APICallerManifold.
+type APICallerManifoldConfig struct {
+	// AgentName is the name of the manifold exposing the agent's
+	// AgentConfigSource.
+	AgentName string
+
+	// Open opens an API connection using the given agent
+	// configuration.
+	Open func(agent.Config) (*api.State, error)
+}
+
+// APICallerManifold returns a manifold whose worker holds an API
+// connection opened with the agent's current configuration, and
+// exposes it as an *api.State. The worker stops with an error when the
+// connection breaks, so that the workers using the connection are
+// restarted with a new one.
+func APICallerManifold(config APICallerManifoldConfig) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{config.AgentName},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var source AgentConfigSource
+			if err := getResource(config.AgentName, &source); err != nil {
+				return nil, err
+			}
+			conn, err := config.Open(source.CurrentConfig())
+			if err != nil {
+				return nil, err
+			}
+			return newAPIConnWorker(conn), nil
+		},
+		Output: func(in worker.Worker, out interface{}) error {
+			inWorker, _ := in.(*apiConnWorker)
+			outPointer, _ := out.(**api.State)
+			if inWorker == nil || outPointer == nil {
+				return errors.Errorf("expected %T->%T; got %T->%T", inWorker, outPointer, in, out)
+			}
+			*outPointer = inWorker.conn
+			return nil
+		},
+	}
+}
+
+// apiConnWorker closes its API connection when it stops, and stops when
+// the connection breaks.
+type apiConnWorker struct {
+	tomb tomb.Tomb
+	conn *api.State
+}
+
+func newAPIConnWorker(conn *api.State) worker.Worker {
+	w := &apiConnWorker{conn: conn}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *apiConnWorker) loop() (err error) {
+	defer func() {
+		if closeErr := w.conn.Close(); closeErr != nil && err == nil {
+			err = errors.Annotate(closeErr, "cannot close API connection")
+		}
+	}()
+	select {
+	case <-w.tomb.Dying():
+		return tomb.ErrDying
+	case <-w.conn.Broken():
+		return errors.New("API connection broken")
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *apiConnWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *apiConnWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+// MachineLockManifold returns a manifold whose worker exposes the hook
+// execution lock in the agent's data directory as an *fslock.Lock.
+func MachineLockManifold(agentName string) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{agentName},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var source AgentConfigSource
+			if err := getResource(agentName, &source); err != nil {
+				return nil, err
+			}
+			lock, err := HookExecutionLock(source.CurrentConfig().DataDir())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return &lockWorker{
+				Worker: worker.NewNoOpWorker(),
+				lock:   lock,
+			}, nil
+		},
+		Output: func(in worker.Worker, out interface{}) error {
+			inWorker, _ := in.(*lockWorker)
+			outPointer, _ := out.(**fslock.Lock)
+			if inWorker == nil || outPointer == nil {
+				return errors.Errorf("expected %T->%T; got %T->%T", inWorker, outPointer, in, out)
+			}
+			*outPointer = inWorker.lock
+			return nil
+		},
+	}
+}
+
+type lockWorker struct {
+	worker.Worker
+	lock *fslock.Lock
+}
+
+// IntrospectionManifold returns a manifold whose worker serves the
+// reporter's report on the agent's introspection socket.
+func IntrospectionManifold(agentName string, reporter introspection.Reporter) dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: []string{agentName},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var source AgentConfigSource
+			if err := getResource(agentName, &source); err != nil {
+				return nil, err
+			}
+			agentConfig := source.CurrentConfig()
+			socketPath := introspection.SocketPath(agentConfig.DataDir(), agentConfig.Tag())
+			return introspection.NewWorker(reporter, socketPath)
+		},
+	}
+}
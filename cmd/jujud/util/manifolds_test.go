@@ -0,0 +1,121 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package util
+
+import (
+	"errors"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/fslock"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+type manifoldsSuite struct {
+	coretesting.BaseSuite
+	source fakeConfigSource
+}
+
+var _ = gc.Suite(&manifoldsSuite{})
+
+func (s *manifoldsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.source = fakeConfigSource{dataDir: c.MkDir()}
+}
+
+// getResource returns a GetResourceFunc exposing the given
+// AgentConfigSource under the name "agent".
+func getResource(source AgentConfigSource) dependency.GetResourceFunc {
+	return func(name string, out interface{}) error {
+		if name != "agent" {
+			return errors.New("unexpected resource")
+		}
+		if source == nil {
+			return dependency.ErrMissing
+		}
+		*out.(*AgentConfigSource) = source
+		return nil
+	}
+}
+
+func (s *manifoldsSuite) TestAgentManifold(c *gc.C) {
+	manifold := AgentManifold(s.source)
+	c.Assert(manifold.Inputs, gc.HasLen, 0)
+	w, err := manifold.Start(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	var source AgentConfigSource
+	err = manifold.Output(w, &source)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(source, gc.Equals, s.source)
+
+	var lock *fslock.Lock
+	err = manifold.Output(w, &lock)
+	c.Assert(err, gc.ErrorMatches, `expected \*util.agentWorker->\*util.AgentConfigSource; got \*util.agentWorker->\*\*fslock.Lock`)
+}
+
+func (s *manifoldsSuite) TestAPICallerManifoldMissingAgent(c *gc.C) {
+	manifold := APICallerManifold(APICallerManifoldConfig{
+		AgentName: "agent",
+		Open: func(agent.Config) (*api.State, error) {
+			c.Fatalf("unexpected open")
+			return nil, nil
+		},
+	})
+	c.Assert(manifold.Inputs, jc.DeepEquals, []string{"agent"})
+	_, err := manifold.Start(getResource(nil))
+	c.Assert(err, gc.Equals, dependency.ErrMissing)
+}
+
+func (s *manifoldsSuite) TestAPICallerManifoldOpenError(c *gc.C) {
+	manifold := APICallerManifold(APICallerManifoldConfig{
+		AgentName: "agent",
+		Open: func(config agent.Config) (*api.State, error) {
+			c.Check(config.DataDir(), gc.Equals, s.source.dataDir)
+			return nil, errors.New("no api for you")
+		},
+	})
+	_, err := manifold.Start(getResource(s.source))
+	c.Assert(err, gc.ErrorMatches, "no api for you")
+}
+
+func (s *manifoldsSuite) TestMachineLockManifold(c *gc.C) {
+	manifold := MachineLockManifold("agent")
+	c.Assert(manifold.Inputs, jc.DeepEquals, []string{"agent"})
+	_, err := manifold.Start(getResource(nil))
+	c.Assert(err, gc.Equals, dependency.ErrMissing)
+
+	w, err := manifold.Start(getResource(s.source))
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	var lock *fslock.Lock
+	err = manifold.Output(w, &lock)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lock, gc.NotNil)
+	c.Assert(lock.IsLocked(), jc.IsFalse)
+}
+
+type fakeConfigSource struct {
+	dataDir string
+}
+
+func (s fakeConfigSource) CurrentConfig() agent.Config {
+	return fakeConfig{dataDir: s.dataDir}
+}
+
+type fakeConfig struct {
+	agent.Config
+	dataDir string
+}
+
+func (c fakeConfig) DataDir() string {
+	return c.dataDir
+}
@@ -15,14 +15,14 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/juju/juju/agent"
-	apirsyslog "github.com/juju/juju/api/rsyslog"
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/juju/paths"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
-	"github.com/juju/juju/worker/rsyslog"
+	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/upgrader"
 )
 
@@ -176,6 +176,17 @@ func NewEnsureServerParams(agentConfig agent.Config) (mongo.EnsureServerParams,
 		}
 	}
 
+	// If a cache size is specified in the agent configuration, use
+	// that. Otherwise leave the default zero value to let mongod
+	// choose the size.
+	var cacheSize int
+	if cacheSizeString := agentConfig.Value(agent.MongoCacheSize); cacheSizeString != "" {
+		var err error
+		if cacheSize, err = strconv.Atoi(cacheSizeString); err != nil {
+			return mongo.EnsureServerParams{}, fmt.Errorf("invalid mongo cache size: %q", cacheSizeString)
+		}
+	}
+
 	si, ok := agentConfig.StateServingInfo()
 	if !ok {
 		return mongo.EnsureServerParams{}, fmt.Errorf("agent config has no state serving info")
@@ -194,6 +205,8 @@ func NewEnsureServerParams(agentConfig agent.Config) (mongo.EnsureServerParams,
 		Namespace:            agentConfig.Value(agent.Namespace),
 		OplogSize:            oplogSize,
 		SetNumaControlPolicy: numaCtlPolicy,
+		StorageEngine:        mongo.StorageEngine(agentConfig.Value(agent.MongoStorageEngine)),
+		CacheSizeGB:          cacheSize,
 	}
 	return params, nil
 }
@@ -236,17 +249,12 @@ func HookExecutionLock(dataDir string) (*fslock.Lock, error) {
 	return fslock.NewLock(lockDir, "uniter-hook-execution")
 }
 
-// NewRsyslogConfigWorker creates and returns a new
-// RsyslogConfigWorker based on the specified configuration
-// parameters.
-var NewRsyslogConfigWorker = func(st *apirsyslog.State, agentConfig agent.Config, mode rsyslog.RsyslogMode) (worker.Worker, error) {
-	tag := agentConfig.Tag()
-	namespace := agentConfig.Value(agent.Namespace)
-	addrs, err := agentConfig.APIAddresses()
-	if err != nil {
-		return nil, err
-	}
-	return rsyslog.NewRsyslogConfigWorker(st, mode, tag, namespace, addrs)
+// NewLogSender creates and returns a worker which sends the records
+// written to bufferedLogs to the state server's logs database.
+var NewLogSender = func(bufferedLogs logsender.LogRecordCh, st *api.State) worker.Worker {
+	return logsender.New(bufferedLogs, func() (logsender.LogSink, error) {
+		return st.OpenLogSink()
+	})
 }
 
 // ParamsStateServingInfoToStateStateServingInfo converts a
@@ -20,15 +20,19 @@ import (
 // The following constants list the supported constraint attribute names, as defined
 // by the fields in the Value struct.
 const (
-	Arch         = "arch"
-	Container    = "container"
-	CpuCores     = "cpu-cores"
-	CpuPower     = "cpu-power"
-	Mem          = "mem"
-	RootDisk     = "root-disk"
-	Tags         = "tags"
-	InstanceType = "instance-type"
-	Networks     = "networks"
+	Arch           = "arch"
+	Container      = "container"
+	CpuCores       = "cpu-cores"
+	CpuPower       = "cpu-power"
+	Gpus           = "gpus"
+	GpuType        = "gpu-type"
+	Mem            = "mem"
+	RootDisk       = "root-disk"
+	RootDiskSource = "root-disk-source"
+	Tags           = "tags"
+	InstanceType   = "instance-type"
+	Networks       = "networks"
+	Zones          = "zones"
 )
 
 // Value describes a user's requirements of the hardware on which units
@@ -53,6 +57,14 @@ type Value struct {
 	// equivalent to 1 Amazon ECU (or, roughly, a single 2007-era Xeon).
 	CpuPower *uint64 `json:"cpu-power,omitempty" yaml:"cpu-power,omitempty"`
 
+	// Gpus, if not nil, indicates that a machine must have at least that
+	// number of GPUs available.
+	Gpus *uint64 `json:"gpus,omitempty" yaml:"gpus,omitempty"`
+
+	// GpuType, if not nil or empty, indicates that the GPUs of a machine
+	// must be of the named type, such as "nvidia".
+	GpuType *string `json:"gpu-type,omitempty" yaml:"gpu-type,omitempty"`
+
 	// Mem, if not nil, indicates that a machine must have at least that many
 	// megabytes of RAM.
 	Mem *uint64 `json:"mem,omitempty" yaml:"mem,omitempty"`
@@ -64,6 +76,11 @@ type Value struct {
 	// disk might be requested.
 	RootDisk *uint64 `json:"root-disk,omitempty" yaml:"root-disk,omitempty"`
 
+	// RootDiskSource, if not nil, indicates where the root disk of a
+	// machine should be provided from, such as the instance's local
+	// disk or a volume. The accepted values depend on the provider.
+	RootDiskSource *string `json:"root-disk-source,omitempty" yaml:"root-disk-source,omitempty"`
+
 	// Tags, if not nil, indicates tags that the machine must have applied to it.
 	// An empty list is treated the same as a nil (unspecified) list, except an
 	// empty list will override any default tags, where a nil list will not.
@@ -78,6 +95,12 @@ type Value struct {
 	// negative values are accepted, and the difference is the latter
 	// have a "^" prefix to the name.
 	Networks *[]string `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	// Zones, if not nil, holds a list of availability zones, one of
+	// which a machine must be started in. An empty list is treated the
+	// same as a nil (unspecified) list, except that it will override
+	// any default zones.
+	Zones *[]string `json:"zones,omitempty" yaml:"zones,omitempty"`
 }
 
 // fieldNames records a mapping from the constraint tag to struct field name.
@@ -151,6 +174,24 @@ func (v *Value) HaveNetworks() bool {
 	return v.Networks != nil && len(*v.Networks) > 0
 }
 
+// HaveZones returns whether any availability zone constraints were
+// specified.
+func (v *Value) HaveZones() bool {
+	return v.Zones != nil && len(*v.Zones) > 0
+}
+
+// HasRootDiskSource returns true if the constraints.Value specifies
+// the source of the root disk.
+func (v *Value) HasRootDiskSource() bool {
+	return v.RootDiskSource != nil && *v.RootDiskSource != ""
+}
+
+// HasGpus returns true if the constraints.Value requires any GPUs,
+// either by number or by type.
+func (v *Value) HasGpus() bool {
+	return (v.Gpus != nil && *v.Gpus > 0) || (v.GpuType != nil && *v.GpuType != "")
+}
+
 // String expresses a constraints.Value in the language in which it was specified.
 func (v Value) String() string {
 	var strs []string
@@ -166,6 +207,12 @@ func (v Value) String() string {
 	if v.CpuPower != nil {
 		strs = append(strs, "cpu-power="+uintStr(*v.CpuPower))
 	}
+	if v.Gpus != nil {
+		strs = append(strs, "gpus="+uintStr(*v.Gpus))
+	}
+	if v.GpuType != nil {
+		strs = append(strs, "gpu-type="+*v.GpuType)
+	}
 	if v.InstanceType != nil {
 		strs = append(strs, "instance-type="+string(*v.InstanceType))
 	}
@@ -183,6 +230,9 @@ func (v Value) String() string {
 		}
 		strs = append(strs, "root-disk="+s)
 	}
+	if v.RootDiskSource != nil {
+		strs = append(strs, "root-disk-source="+*v.RootDiskSource)
+	}
 	if v.Tags != nil {
 		s := strings.Join(*v.Tags, ",")
 		strs = append(strs, "tags="+s)
@@ -191,6 +241,10 @@ func (v Value) String() string {
 		s := strings.Join(*v.Networks, ",")
 		strs = append(strs, "networks="+s)
 	}
+	if v.Zones != nil {
+		s := strings.Join(*v.Zones, ",")
+		strs = append(strs, "zones="+s)
+	}
 	return strings.Join(strs, " ")
 }
 
@@ -320,16 +374,24 @@ func (v *Value) setRaw(raw string) error {
 		err = v.setCpuCores(str)
 	case CpuPower:
 		err = v.setCpuPower(str)
+	case Gpus:
+		err = v.setGpus(str)
+	case GpuType:
+		err = v.setGpuType(str)
 	case Mem:
 		err = v.setMem(str)
 	case RootDisk:
 		err = v.setRootDisk(str)
+	case RootDiskSource:
+		err = v.setRootDiskSource(str)
 	case Tags:
 		err = v.setTags(str)
 	case InstanceType:
 		err = v.setInstanceType(str)
 	case Networks:
 		err = v.setNetworks(str)
+	case Zones:
+		err = v.setZones(str)
 	default:
 		return fmt.Errorf("unknown constraint %q", name)
 	}
@@ -364,10 +426,16 @@ func (v *Value) SetYAML(tag string, value interface{}) bool {
 			v.CpuCores, err = parseUint64(vstr)
 		case CpuPower:
 			v.CpuPower, err = parseUint64(vstr)
+		case Gpus:
+			v.Gpus, err = parseUint64(vstr)
+		case GpuType:
+			v.GpuType = &vstr
 		case Mem:
 			v.Mem, err = parseUint64(vstr)
 		case RootDisk:
 			v.RootDisk, err = parseUint64(vstr)
+		case RootDiskSource:
+			v.RootDiskSource = &vstr
 		case Tags:
 			v.Tags, err = parseYamlStrings("tags", val)
 		case Networks:
@@ -376,6 +444,8 @@ func (v *Value) SetYAML(tag string, value interface{}) bool {
 			if err == nil {
 				err = v.validateNetworks(networks)
 			}
+		case Zones:
+			v.Zones, err = parseYamlStrings("zones", val)
 		default:
 			return false
 		}
@@ -435,6 +505,22 @@ func (v *Value) setCpuPower(str string) (err error) {
 	return
 }
 
+func (v *Value) setGpus(str string) (err error) {
+	if v.Gpus != nil {
+		return fmt.Errorf("already set")
+	}
+	v.Gpus, err = parseUint64(str)
+	return
+}
+
+func (v *Value) setGpuType(str string) error {
+	if v.GpuType != nil {
+		return fmt.Errorf("already set")
+	}
+	v.GpuType = &str
+	return nil
+}
+
 func (v *Value) setInstanceType(str string) error {
 	if v.InstanceType != nil {
 		return fmt.Errorf("already set")
@@ -459,6 +545,14 @@ func (v *Value) setRootDisk(str string) (err error) {
 	return
 }
 
+func (v *Value) setRootDiskSource(str string) error {
+	if v.RootDiskSource != nil {
+		return fmt.Errorf("already set")
+	}
+	v.RootDiskSource = &str
+	return nil
+}
+
 func (v *Value) setTags(str string) error {
 	if v.Tags != nil {
 		return fmt.Errorf("already set")
@@ -478,6 +572,20 @@ func (v *Value) setNetworks(str string) error {
 	return nil
 }
 
+func (v *Value) setZones(str string) error {
+	if v.Zones != nil {
+		return fmt.Errorf("already set")
+	}
+	zones := parseCommaDelimited(str)
+	for _, zone := range *zones {
+		if zone == "" {
+			return fmt.Errorf("empty zone name in %q", str)
+		}
+	}
+	v.Zones = zones
+	return nil
+}
+
 func (v *Value) validateNetworks(networks *[]string) error {
 	if networks == nil {
 		return nil
@@ -525,7 +633,8 @@ func parseSize(str string) (*uint64, error) {
 }
 
 // parseCommaDelimited returns the items in the value s. We expect the
-// tags to be comma delimited strings. It is used for tags and networks.
+// tags to be comma delimited strings. It is used for tags, networks and
+// zones.
 func parseCommaDelimited(s string) *[]string {
 	if s == "" {
 		return &[]string{}
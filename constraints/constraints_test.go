@@ -165,6 +165,36 @@ var parseConstraintsTests = []struct {
 		err:     `bad "cpu-power" constraint: already set`,
 	},
 
+	// "gpus" and "gpu-type" in detail.
+	{
+		summary: "set gpus empty",
+		args:    []string{"gpus="},
+	}, {
+		summary: "set gpus zero",
+		args:    []string{"gpus=0"},
+	}, {
+		summary: "set gpus",
+		args:    []string{"gpus=2"},
+	}, {
+		summary: "set nonsense gpus",
+		args:    []string{"gpus=many"},
+		err:     `bad "gpus" constraint: must be a non-negative integer`,
+	}, {
+		summary: "double set gpus",
+		args:    []string{"gpus=1", "gpus=2"},
+		err:     `bad "gpus" constraint: already set`,
+	}, {
+		summary: "set gpu-type",
+		args:    []string{"gpu-type=nvidia"},
+	}, {
+		summary: "set empty gpu-type",
+		args:    []string{"gpu-type="},
+	}, {
+		summary: "double set gpu-type",
+		args:    []string{"gpu-type=nvidia gpu-type=amd"},
+		err:     `bad "gpu-type" constraint: already set`,
+	},
+
 	// "mem" in detail.
 	{
 		summary: "set mem empty",
@@ -253,6 +283,19 @@ var parseConstraintsTests = []struct {
 		err:     `bad "root-disk" constraint: already set`,
 	},
 
+	// root-disk-source
+	{
+		summary: "set root-disk-source",
+		args:    []string{"root-disk-source=volume"},
+	}, {
+		summary: "set empty root-disk-source",
+		args:    []string{"root-disk-source="},
+	}, {
+		summary: "double set root-disk-source",
+		args:    []string{"root-disk-source=volume", "root-disk-source=local"},
+		err:     `bad "root-disk-source" constraint: already set`,
+	},
+
 	// tags
 	{
 		summary: "single tag",
@@ -280,6 +323,26 @@ var parseConstraintsTests = []struct {
 		args:    []string{"networks="},
 	},
 
+	// zones
+	{
+		summary: "single zone",
+		args:    []string{"zones=az1"},
+	}, {
+		summary: "multiple zones",
+		args:    []string{"zones=az1,az2"},
+	}, {
+		summary: "no zones",
+		args:    []string{"zones="},
+	}, {
+		summary: "empty zone name",
+		args:    []string{"zones=az1,,az2"},
+		err:     `bad "zones" constraint: empty zone name in "az1,,az2"`,
+	}, {
+		summary: "double set zones",
+		args:    []string{"zones=az1 zones=az2"},
+		err:     `bad "zones" constraint: already set`,
+	},
+
 	// instance type
 	{
 		summary: "set instance type",
@@ -294,12 +357,14 @@ var parseConstraintsTests = []struct {
 		summary: "kitchen sink together",
 		args: []string{
 			"root-disk=8G mem=2T  arch=i386  cpu-cores=4096 cpu-power=9001 container=lxc " +
-				"tags=foo,bar networks=net1,^net2 instance-type=foo"},
+				"tags=foo,bar networks=net1,^net2 instance-type=foo root-disk-source=volume zones=az1,az2 " +
+				"gpus=2 gpu-type=nvidia"},
 	}, {
 		summary: "kitchen sink separately",
 		args: []string{
 			"root-disk=8G", "mem=2T", "cpu-cores=4096", "cpu-power=9001", "arch=armhf",
-			"container=lxc", "tags=foo,bar", "networks=net1,^net2", "instance-type=foo"},
+			"container=lxc", "tags=foo,bar", "networks=net1,^net2", "instance-type=foo",
+			"root-disk-source=volume", "zones=az1,az2", "gpus=2", "gpu-type=nvidia"},
 	},
 }
 
@@ -414,6 +479,39 @@ func (s *ConstraintsSuite) TestIsEmpty(c *gc.C) {
 	c.Check(&con, gc.Not(jc.Satisfies), constraints.IsEmpty)
 	con = constraints.MustParse("instance-type=")
 	c.Check(&con, gc.Not(jc.Satisfies), constraints.IsEmpty)
+	con = constraints.MustParse("root-disk-source=")
+	c.Check(&con, gc.Not(jc.Satisfies), constraints.IsEmpty)
+	con = constraints.MustParse("zones=")
+	c.Check(&con, gc.Not(jc.Satisfies), constraints.IsEmpty)
+}
+
+func (s *ConstraintsSuite) TestHaveZones(c *gc.C) {
+	con := constraints.MustParse("zones=az1,az2")
+	c.Check(con.HaveZones(), jc.IsTrue)
+	con = constraints.MustParse("zones=")
+	c.Check(con.HaveZones(), jc.IsFalse)
+	con = constraints.MustParse("mem=4G")
+	c.Check(con.HaveZones(), jc.IsFalse)
+}
+
+func (s *ConstraintsSuite) TestHasRootDiskSource(c *gc.C) {
+	con := constraints.MustParse("root-disk-source=volume")
+	c.Check(con.HasRootDiskSource(), jc.IsTrue)
+	con = constraints.MustParse("root-disk-source=")
+	c.Check(con.HasRootDiskSource(), jc.IsFalse)
+	con = constraints.MustParse("mem=4G")
+	c.Check(con.HasRootDiskSource(), jc.IsFalse)
+}
+
+func (s *ConstraintsSuite) TestHasGpus(c *gc.C) {
+	con := constraints.MustParse("gpus=1")
+	c.Check(con.HasGpus(), jc.IsTrue)
+	con = constraints.MustParse("gpu-type=nvidia")
+	c.Check(con.HasGpus(), jc.IsTrue)
+	con = constraints.MustParse("gpus=0 gpu-type=")
+	c.Check(con.HasGpus(), jc.IsFalse)
+	con = constraints.MustParse("mem=4G")
+	c.Check(con.HasGpus(), jc.IsFalse)
 }
 
 func uint64p(i uint64) *uint64 {
@@ -447,6 +545,11 @@ var constraintsRoundtripTests = []roundTrip{
 	{"CpuPower1", constraints.Value{CpuPower: nil}},
 	{"CpuPower2", constraints.Value{CpuPower: uint64p(0)}},
 	{"CpuPower3", constraints.Value{CpuPower: uint64p(250)}},
+	{"Gpus1", constraints.Value{Gpus: nil}},
+	{"Gpus2", constraints.Value{Gpus: uint64p(0)}},
+	{"Gpus3", constraints.Value{Gpus: uint64p(4)}},
+	{"GpuType1", constraints.Value{GpuType: strp("")}},
+	{"GpuType2", constraints.Value{GpuType: strp("nvidia")}},
 	{"Mem1", constraints.Value{Mem: nil}},
 	{"Mem2", constraints.Value{Mem: uint64p(0)}},
 	{"Mem3", constraints.Value{Mem: uint64p(98765)}},
@@ -459,18 +562,27 @@ var constraintsRoundtripTests = []roundTrip{
 	{"Networks1", constraints.Value{Networks: nil}},
 	{"Networks2", constraints.Value{Networks: &[]string{}}},
 	{"Networks3", constraints.Value{Networks: &[]string{"net1", "^net2"}}},
+	{"RootDiskSource1", constraints.Value{RootDiskSource: strp("")}},
+	{"RootDiskSource2", constraints.Value{RootDiskSource: strp("volume")}},
+	{"Zones1", constraints.Value{Zones: nil}},
+	{"Zones2", constraints.Value{Zones: &[]string{}}},
+	{"Zones3", constraints.Value{Zones: &[]string{"az1", "az2"}}},
 	{"InstanceType1", constraints.Value{InstanceType: strp("")}},
 	{"InstanceType2", constraints.Value{InstanceType: strp("foo")}},
 	{"All", constraints.Value{
-		Arch:         strp("i386"),
-		Container:    ctypep("lxc"),
-		CpuCores:     uint64p(4096),
-		CpuPower:     uint64p(9001),
-		Mem:          uint64p(18000000000),
-		RootDisk:     uint64p(24000000000),
-		Tags:         &[]string{"foo", "bar"},
-		Networks:     &[]string{"net1", "^net2"},
-		InstanceType: strp("foo"),
+		Arch:           strp("i386"),
+		Container:      ctypep("lxc"),
+		CpuCores:       uint64p(4096),
+		CpuPower:       uint64p(9001),
+		Gpus:           uint64p(2),
+		GpuType:        strp("nvidia"),
+		Mem:            uint64p(18000000000),
+		RootDisk:       uint64p(24000000000),
+		RootDiskSource: strp("volume"),
+		Tags:           &[]string{"foo", "bar"},
+		Networks:       &[]string{"net1", "^net2"},
+		InstanceType:   strp("foo"),
+		Zones:          &[]string{"az1", "az2"},
 	}},
 }
 
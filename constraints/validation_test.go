@@ -105,6 +105,28 @@ var validationTests = []struct {
 		vocab: map[string][]interface{}{"tags": {"foo", "bar", "another"}},
 		err:   "invalid constraint value: tags=other\nvalid values are:.*",
 	},
+	{
+		cons:  "mem=4G zones=az1,az2",
+		vocab: map[string][]interface{}{"zones": {"az1", "az2", "az3"}},
+	},
+	{
+		cons:  "mem=4G zones=az1,az4",
+		vocab: map[string][]interface{}{"zones": {"az1", "az2", "az3"}},
+		err:   "invalid constraint value: zones=az4\nvalid values are:.*",
+	},
+	{
+		cons:  "root-disk-source=volume",
+		vocab: map[string][]interface{}{"root-disk-source": {"local", "volume"}},
+	},
+	{
+		cons:  "root-disk-source=ssd",
+		vocab: map[string][]interface{}{"root-disk-source": {"local", "volume"}},
+		err:   "invalid constraint value: root-disk-source=ssd\nvalid values are:.*",
+	},
+	{
+		cons:        "mem=4G zones=az1 root-disk-source=volume",
+		unsupported: []string{"zones", "root-disk-source"},
+	},
 	{
 		cons: "arch=i386 mem=4G instance-type=foo",
 		vocab: map[string][]interface{}{
@@ -222,6 +244,29 @@ var mergeTests = []struct {
 		cons:         "tags=",
 		consFallback: "tags=foo,bar",
 		expected:     "tags=",
+	}, {
+		desc:         "zones with ignored fallback",
+		cons:         "zones=az1,az2",
+		consFallback: "zones=az3",
+		expected:     "zones=az1,az2",
+	}, {
+		desc:         "zones from fallback",
+		consFallback: "zones=az1",
+		expected:     "zones=az1",
+	}, {
+		desc:         "zones initial empty",
+		cons:         "zones=",
+		consFallback: "zones=az1",
+		expected:     "zones=",
+	}, {
+		desc:         "root-disk-source with ignored fallback",
+		cons:         "root-disk-source=volume",
+		consFallback: "root-disk-source=local",
+		expected:     "root-disk-source=volume",
+	}, {
+		desc:         "root-disk-source from fallback",
+		consFallback: "root-disk-source=volume",
+		expected:     "root-disk-source=volume",
 	}, {
 		desc:     "mem with empty fallback",
 		cons:     "mem=4G",
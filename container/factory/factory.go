@@ -9,6 +9,7 @@ import (
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/instance"
@@ -23,6 +24,8 @@ func NewContainerManager(forType instance.ContainerType, conf container.ManagerC
 		return lxc.NewContainerManager(conf, imageURLGetter)
 	case instance.KVM:
 		return kvm.NewContainerManager(conf)
+	case instance.HYPERV:
+		return hyperv.NewContainerManager(conf)
 	}
 	return nil, errors.Errorf("unknown container type: %q", forType)
 }
@@ -29,6 +29,9 @@ func (*factorySuite) TestNewContainerManager(c *gc.C) {
 	}, {
 		containerType: instance.KVM,
 		valid:         true,
+	}, {
+		containerType: instance.HYPERV,
+		valid:         true,
 	}, {
 		containerType: instance.NONE,
 		valid:         false,
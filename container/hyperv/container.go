@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+type hypervContainer struct {
+	factory *containerFactory
+	name    string
+	// started is a three state boolean, true, false, or unknown
+	// this allows for checking when we don't know, but using a
+	// value if we already know it (like in the list situation).
+	started *bool
+}
+
+var _ Container = (*hypervContainer)(nil)
+
+func (c *hypervContainer) Name() string {
+	return c.name
+}
+
+func (c *hypervContainer) Start(params StartParams) (err error) {
+	logger.Debugf("Ensure image %s is installed", params.Image)
+	if err := ensureImage(params.Image); err != nil {
+		return err
+	}
+	logger.Debugf("Create the container %s", c.name)
+	if _, err := RunPowerShell(fmt.Sprintf(
+		"New-Container -Name %s -ContainerImageName %s -SwitchName %s -RuntimeType HyperV -MemoryStartupBytes %dMB | Out-Null",
+		quote(c.name), quote(params.Image), quote(params.SwitchName), params.Memory,
+	)); err != nil {
+		return errors.Annotatef(err, "cannot create container %q", c.name)
+	}
+	defer func() {
+		if err != nil {
+			if rerr := removeContainer(c.name); rerr != nil {
+				logger.Errorf("cannot remove container %q: %v", c.name, rerr)
+			}
+		}
+	}()
+	logger.Debugf("Start the container %s", c.name)
+	if _, err := RunPowerShell(fmt.Sprintf("Start-Container -Name %s", quote(c.name))); err != nil {
+		return errors.Annotatef(err, "cannot start container %q", c.name)
+	}
+	c.started = isRunning("Running")
+	logger.Debugf("Run the user data of %s", c.name)
+	if _, err := RunPowerShell(fmt.Sprintf(
+		"Invoke-Command -ContainerName %s -RunAsAdministrator -FilePath %s",
+		quote(c.name), quote(params.UserDataFile),
+	)); err != nil {
+		return errors.Annotatef(err, "cannot run user data in container %q", c.name)
+	}
+	return nil
+}
+
+func (c *hypervContainer) Stop() error {
+	// Make started state unknown again.
+	c.started = nil
+	logger.Debugf("Stop %s", c.name)
+	return removeContainer(c.name)
+}
+
+func (c *hypervContainer) IsRunning() bool {
+	if c.started != nil {
+		return *c.started
+	}
+	output, err := RunPowerShell(fmt.Sprintf(
+		"Get-Container -Name %s -ErrorAction SilentlyContinue | Select-Object -ExpandProperty State",
+		quote(c.name),
+	))
+	if err != nil {
+		return false
+	}
+	c.started = isRunning(strings.TrimSpace(output))
+	return *c.started
+}
+
+func (c *hypervContainer) String() string {
+	return fmt.Sprintf("<Hyper-V container %v>", *c)
+}
+
+// removeContainer turns off and removes the named container, if it
+// exists.
+func removeContainer(name string) error {
+	_, err := RunPowerShell(fmt.Sprintf(
+		"Get-Container -Name %s -ErrorAction SilentlyContinue | "+
+			"ForEach-Object { Stop-Container -Container $_ -TurnOff; Remove-Container -Container $_ -Force }",
+		quote(name),
+	))
+	return errors.Annotatef(err, "cannot remove container %q", name)
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv_test
+
+import (
+	"errors"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/container/hyperv"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ContainerSuite struct {
+	coretesting.BaseSuite
+	scripts []string
+	outputs map[string]string
+	failing string
+}
+
+var _ = gc.Suite(&ContainerSuite{})
+
+func (s *ContainerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.scripts = nil
+	s.outputs = make(map[string]string)
+	s.failing = ""
+	s.PatchValue(&hyperv.RunPowerShell, func(script string) (string, error) {
+		s.scripts = append(s.scripts, script)
+		command := strings.Fields(script)[0]
+		if command == s.failing {
+			return "", errors.New(command + " failed")
+		}
+		return s.outputs[command], nil
+	})
+}
+
+var startParams = hyperv.StartParams{
+	Image:        "WindowsServerCore",
+	SwitchName:   "juju-switch",
+	UserDataFile: `C:\Juju\lib\juju\containers\juju-machine-1-hyperv-0\userdata.ps1`,
+	Memory:       1024,
+}
+
+func (s *ContainerSuite) TestStart(c *gc.C) {
+	s.outputs["Get-ContainerImage"] = "WindowsServerCore\r\n"
+	hypervContainer := hyperv.HypervObjectFactory.New("juju-machine-1-hyperv-0")
+	err := hypervContainer.Start(startParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hypervContainer.IsRunning(), jc.IsTrue)
+	c.Assert(s.scripts, jc.DeepEquals, []string{
+		"Get-ContainerImage -Name 'WindowsServerCore' -ErrorAction SilentlyContinue | Select-Object -ExpandProperty Name",
+		"New-Container -Name 'juju-machine-1-hyperv-0' -ContainerImageName 'WindowsServerCore' -SwitchName 'juju-switch' -RuntimeType HyperV -MemoryStartupBytes 1024MB | Out-Null",
+		"Start-Container -Name 'juju-machine-1-hyperv-0'",
+		`Invoke-Command -ContainerName 'juju-machine-1-hyperv-0' -RunAsAdministrator -FilePath 'C:\Juju\lib\juju\containers\juju-machine-1-hyperv-0\userdata.ps1'`,
+	})
+}
+
+func (s *ContainerSuite) TestStartInstallsMissingImage(c *gc.C) {
+	hypervContainer := hyperv.HypervObjectFactory.New("juju-machine-1-hyperv-0")
+	err := hypervContainer.Start(startParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.scripts, gc.HasLen, 5)
+	c.Assert(s.scripts[1], gc.Equals, "Install-ContainerImage -Name 'WindowsServerCore'")
+}
+
+func (s *ContainerSuite) TestStartRemovesContainerOnFailure(c *gc.C) {
+	s.outputs["Get-ContainerImage"] = "WindowsServerCore"
+	s.failing = "Invoke-Command"
+	hypervContainer := hyperv.HypervObjectFactory.New("juju-machine-1-hyperv-0")
+	err := hypervContainer.Start(startParams)
+	c.Assert(err, gc.ErrorMatches, `cannot run user data in container "juju-machine-1-hyperv-0": Invoke-Command failed`)
+	c.Assert(s.scripts[len(s.scripts)-1], gc.Equals,
+		"Get-Container -Name 'juju-machine-1-hyperv-0' -ErrorAction SilentlyContinue | "+
+			"ForEach-Object { Stop-Container -Container $_ -TurnOff; Remove-Container -Container $_ -Force }")
+}
+
+func (s *ContainerSuite) TestIsRunning(c *gc.C) {
+	s.outputs["Get-Container"] = "Running\r\n"
+	c.Assert(hyperv.HypervObjectFactory.New("juju-machine-1-hyperv-0").IsRunning(), jc.IsTrue)
+	s.outputs["Get-Container"] = "Off\r\n"
+	c.Assert(hyperv.HypervObjectFactory.New("juju-machine-1-hyperv-0").IsRunning(), jc.IsFalse)
+}
+
+func (s *ContainerSuite) TestList(c *gc.C) {
+	s.outputs["Get-Container"] = "juju-machine-1-hyperv-0 Running\r\njuju-machine-1-hyperv-1 Off\r\n"
+	containers, err := hyperv.HypervObjectFactory.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containers, gc.HasLen, 2)
+	c.Assert(containers[0].Name(), gc.Equals, "juju-machine-1-hyperv-0")
+	c.Assert(containers[0].IsRunning(), jc.IsTrue)
+	c.Assert(containers[1].Name(), gc.Equals, "juju-machine-1-hyperv-1")
+	c.Assert(containers[1].IsRunning(), jc.IsFalse)
+	// The states reported by the listing are used.
+	c.Assert(s.scripts, gc.HasLen, 1)
+}
+
+func (s *ContainerSuite) TestInitialise(c *gc.C) {
+	s.outputs["Install-WindowsFeature"] = "No\r\n"
+	err := hyperv.NewContainerInitialiser().Initialise()
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.outputs["Install-WindowsFeature"] = "Yes\r\n"
+	err = hyperv.NewContainerInitialiser().Initialise()
+	c.Assert(err, gc.ErrorMatches, "the machine must be restarted to finish installing the Containers and Hyper-V features")
+}
@@ -0,0 +1,53 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+type containerFactory struct {
+}
+
+var _ ContainerFactory = (*containerFactory)(nil)
+
+func (factory *containerFactory) New(name string) Container {
+	return &hypervContainer{
+		factory: factory,
+		name:    name,
+	}
+}
+
+func isRunning(state string) *bool {
+	var result *bool = new(bool)
+	if state == "Running" {
+		*result = true
+	}
+	return result
+}
+
+// listScript writes the name and state of each container on its own
+// line.
+const listScript = `Get-Container | ForEach-Object { $_.Name + " " + $_.State }`
+
+func (factory *containerFactory) List() (result []Container, err error) {
+	output, err := RunPowerShell(listScript)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot list containers")
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		result = append(result, &hypervContainer{
+			factory: factory,
+			name:    fields[0],
+			started: isRunning(fields[1]),
+		})
+	}
+	return result, nil
+}
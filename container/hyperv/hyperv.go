@@ -0,0 +1,240 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	coreCloudinit "github.com/juju/juju/cloudinit"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/version"
+)
+
+var (
+	logger = loggo.GetLogger("juju.container.hyperv")
+
+	HypervObjectFactory ContainerFactory = &containerFactory{}
+
+	// DefaultHypervSwitch is the name of the virtual switch containers
+	// are attached to. When it is empty, the first external virtual
+	// switch of the host is used, so that containers share the host's
+	// physical network.
+	DefaultHypervSwitch = ""
+
+	DefaultMemory uint64 = 1024 // MB
+	MinMemory     uint64 = 512  // MB
+)
+
+// IsHyperVSupported reports whether the machine can run Hyper-V
+// containers: it must run a release of Windows that supports them, with
+// the Containers PowerShell module installed.
+// It is a variable to allow us to override behaviour in the tests.
+var IsHyperVSupported = func() (bool, error) {
+	if _, err := imageForSeries(version.Current.Series); err != nil {
+		return false, nil
+	}
+	if _, err := exec.LookPath("powershell.exe"); err != nil {
+		return false, errors.NotFoundf("powershell.exe")
+	}
+	if _, err := RunPowerShell("Get-Command New-Container"); err != nil {
+		return false, errors.Annotate(err, "the Containers module is not installed")
+	}
+	return true, nil
+}
+
+// NewContainerManager returns a manager object that can start and stop
+// Hyper-V containers. The containers that are created are namespaced by
+// the name parameter.
+func NewContainerManager(conf container.ManagerConfig) (container.Manager, error) {
+	name := conf.PopValue(container.ConfigName)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	logDir := conf.PopValue(container.ConfigLogDir)
+	if logDir == "" {
+		logDir = agent.DefaultLogDir
+	}
+	conf.WarnAboutUnused()
+	return &containerManager{name: name, logdir: logDir}, nil
+}
+
+// containerManager handles all of the business logic at the juju specific
+// level. It makes sure that the container image is installed, and that the
+// user-data is written out in the right place.
+type containerManager struct {
+	name   string
+	logdir string
+}
+
+var _ container.Manager = (*containerManager)(nil)
+
+func (manager *containerManager) CreateContainer(
+	machineConfig *cloudinit.MachineConfig,
+	series string,
+	networkConfig *container.NetworkConfig,
+) (instance.Instance, *instance.HardwareCharacteristics, error) {
+
+	name := names.NewMachineTag(machineConfig.MachineId).String()
+	if manager.name != "" {
+		name = fmt.Sprintf("%s-%s", manager.name, name)
+	}
+	image, err := imageForSeries(series)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	switchName := ""
+	if networkConfig != nil {
+		if networkConfig.NetworkType != container.BridgeNetwork {
+			return nil, nil, errors.New("non-bridge network devices not yet supported")
+		}
+		switchName = networkConfig.Device
+	}
+	if switchName == "" {
+		if switchName, err = externalSwitch(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	hypervContainer := HypervObjectFactory.New(name)
+
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed to create container directory")
+	}
+	logger.Tracef("write user data")
+	userDataFilename, err := writeUserData(machineConfig, directory)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed to write user data")
+	}
+	startParams := StartParams{
+		Image:        image,
+		SwitchName:   switchName,
+		UserDataFile: userDataFilename,
+		Memory:       memoryFromConstraints(machineConfig.Constraints),
+	}
+	hardware, err := instance.ParseHardware(
+		fmt.Sprintf("arch=%s mem=%vM", version.Current.Arch, startParams.Memory))
+	if err != nil {
+		logger.Warningf("failed to parse hardware: %v", err)
+	}
+
+	logger.Tracef("create the container, constraints: %v", machineConfig.Constraints)
+	if err := hypervContainer.Start(startParams); err != nil {
+		err = errors.Annotate(err, "hyper-v container creation failed")
+		logger.Infof(err.Error())
+		return nil, nil, err
+	}
+	logger.Tracef("hyper-v container created")
+	return &hypervInstance{hypervContainer, name}, &hardware, nil
+}
+
+func (manager *containerManager) IsInitialized() bool {
+	if _, err := exec.LookPath("powershell.exe"); err != nil {
+		return false
+	}
+	_, err := RunPowerShell("Get-Command New-Container")
+	return err == nil
+}
+
+func (manager *containerManager) DestroyContainer(id instance.Id) error {
+	name := string(id)
+	hypervContainer := HypervObjectFactory.New(name)
+	if err := hypervContainer.Stop(); err != nil {
+		logger.Errorf("failed to stop hyper-v container: %v", err)
+		return err
+	}
+	return container.RemoveDirectory(name)
+}
+
+func (manager *containerManager) ListContainers() (result []instance.Instance, err error) {
+	containers, err := HypervObjectFactory.List()
+	if err != nil {
+		logger.Errorf("failed getting all instances: %v", err)
+		return
+	}
+	managerPrefix := fmt.Sprintf("%s-", manager.name)
+	for _, container := range containers {
+		// Filter out those not starting with our name.
+		name := container.Name()
+		if !strings.HasPrefix(name, managerPrefix) {
+			continue
+		}
+		if container.IsRunning() {
+			result = append(result, &hypervInstance{container, name})
+		}
+	}
+	return
+}
+
+// externalSwitch returns the name of the host's first external virtual
+// switch.
+func externalSwitch() (string, error) {
+	output, err := RunPowerShell(
+		"Get-VMSwitch -SwitchType External | Select-Object -First 1 -ExpandProperty Name")
+	if err != nil {
+		return "", errors.Annotate(err, "cannot list virtual switches")
+	}
+	name := strings.TrimSpace(output)
+	if name == "" {
+		return "", errors.NotFoundf("external virtual switch")
+	}
+	return name, nil
+}
+
+// writeUserData renders the user data of the machine as a PowerShell
+// script in the given directory, and returns the path of the script.
+func writeUserData(machineConfig *cloudinit.MachineConfig, directory string) (string, error) {
+	cloudConfig := coreCloudinit.New()
+	udata, err := cloudinit.NewUserdataConfig(machineConfig, cloudConfig)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := udata.Configure(); err != nil {
+		return "", errors.Trace(err)
+	}
+	renderer, err := coreCloudinit.NewRenderer(machineConfig.Series)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	data, err := renderer.Render(cloudConfig)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	// Invoke-Command only runs files with the .ps1 extension.
+	userDataFilename := filepath.Join(directory, "userdata.ps1")
+	if err := ioutil.WriteFile(userDataFilename, data, 0644); err != nil {
+		return "", errors.Trace(err)
+	}
+	return userDataFilename, nil
+}
+
+// memoryFromConstraints returns the startup memory of a container with
+// the given constraints. Other constraints cause a warning to be emitted.
+func memoryFromConstraints(cons constraints.Value) uint64 {
+	memory := DefaultMemory
+	if cons.Mem != nil {
+		memory = *cons.Mem
+		if memory < MinMemory {
+			memory = MinMemory
+		}
+	}
+	if cons.CpuCores != nil {
+		logger.Infof("cpu-cores constraint of %v being ignored as not supported", *cons.CpuCores)
+	}
+	if cons.RootDisk != nil {
+		logger.Infof("root-disk constraint of %v being ignored as not supported", *cons.RootDisk)
+	}
+	return memory
+}
@@ -0,0 +1,172 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
+	hypervtesting "github.com/juju/juju/container/hyperv/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/cloudinit"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type HypervSuite struct {
+	hypervtesting.TestSuite
+	manager container.Manager
+}
+
+var _ = gc.Suite(&HypervSuite{})
+
+func (s *HypervSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	var err error
+	s.manager, err = hyperv.NewContainerManager(container.ManagerConfig{container.ConfigName: "test"})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (*HypervSuite) TestManagerNameNeeded(c *gc.C) {
+	manager, err := hyperv.NewContainerManager(container.ManagerConfig{container.ConfigName: ""})
+	c.Assert(err, gc.ErrorMatches, "name is required")
+	c.Assert(manager, gc.IsNil)
+}
+
+func (s *HypervSuite) TestListInitiallyEmpty(c *gc.C) {
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containers, gc.HasLen, 0)
+}
+
+func (s *HypervSuite) createRunningContainer(c *gc.C, name string) hyperv.Container {
+	hypervContainer := s.ContainerFactory.New(name)
+	c.Assert(hypervContainer.Start(hyperv.StartParams{
+		Image:        "WindowsServerCore",
+		SwitchName:   hypervtesting.TestSwitch,
+		UserDataFile: "userdata.ps1",
+	}), gc.IsNil)
+	return hypervContainer
+}
+
+func (s *HypervSuite) TestListMatchesManagerName(c *gc.C) {
+	s.createRunningContainer(c, "test-match1")
+	s.createRunningContainer(c, "test-match2")
+	s.createRunningContainer(c, "testNoMatch")
+	s.createRunningContainer(c, "other")
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containers, gc.HasLen, 2)
+	expectedIds := []instance.Id{"test-match1", "test-match2"}
+	ids := []instance.Id{containers[0].Id(), containers[1].Id()}
+	c.Assert(ids, jc.SameContents, expectedIds)
+}
+
+func (s *HypervSuite) TestListMatchesRunningContainers(c *gc.C) {
+	running := s.createRunningContainer(c, "test-running")
+	s.ContainerFactory.New("test-stopped")
+	containers, err := s.manager.ListContainers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containers, gc.HasLen, 1)
+	c.Assert(string(containers[0].Id()), gc.Equals, running.Name())
+}
+
+func (s *HypervSuite) createContainer(c *gc.C, machineId string, cons constraints.Value, network *container.NetworkConfig) (instance.Instance, *instance.HardwareCharacteristics) {
+	machineConfig := mockMachineConfig(c, machineId)
+	machineConfig.Constraints = cons
+	inst, hardware, err := s.manager.CreateContainer(machineConfig, "win2016", network)
+	c.Assert(err, jc.ErrorIsNil)
+	return inst, hardware
+}
+
+func (s *HypervSuite) TestCreateContainer(c *gc.C) {
+	inst, hardware := s.createContainer(c, "1/hyperv/0", constraints.Value{}, nil)
+	name := string(inst.Id())
+	c.Assert(name, gc.Equals, "test-machine-1-hyperv-0")
+	c.Assert(inst.Status(), gc.Equals, "running")
+	c.Assert(*hardware.Mem, gc.Equals, hyperv.DefaultMemory)
+
+	userDataFilename := filepath.Join(s.ContainerDir, name, "userdata.ps1")
+	data, err := ioutil.ReadFile(userDataFilename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Matches, "#ps1_sysnative\r\n(.|\r|\n)*")
+
+	c.Assert(s.ContainerFactory.StartParams(name), jc.DeepEquals, hyperv.StartParams{
+		Image:        "WindowsServerCore",
+		SwitchName:   hypervtesting.TestSwitch,
+		UserDataFile: userDataFilename,
+		Memory:       hyperv.DefaultMemory,
+	})
+}
+
+func (s *HypervSuite) TestCreateContainerWithSwitchAndMemory(c *gc.C) {
+	network := container.BridgeNetworkConfig("juju-switch", nil)
+	inst, hardware := s.createContainer(c, "1/hyperv/0", constraints.MustParse("mem=2G"), network)
+	c.Assert(*hardware.Mem, gc.Equals, uint64(2048))
+
+	params := s.ContainerFactory.StartParams(string(inst.Id()))
+	c.Assert(params.SwitchName, gc.Equals, "juju-switch")
+	c.Assert(params.Memory, gc.Equals, uint64(2048))
+}
+
+func (s *HypervSuite) TestCreateContainerUnsupportedSeries(c *gc.C) {
+	machineConfig := mockMachineConfig(c, "1/hyperv/0")
+	_, _, err := s.manager.CreateContainer(machineConfig, "win2012r2", nil)
+	c.Assert(err, gc.ErrorMatches, `Hyper-V containers of series "win2012r2" not supported`)
+}
+
+func (s *HypervSuite) TestDestroyContainer(c *gc.C) {
+	inst, _ := s.createContainer(c, "1/hyperv/0", constraints.Value{}, nil)
+
+	err := s.manager.DestroyContainer(inst.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	name := string(inst.Id())
+	c.Assert(s.ContainerFactory.New(name).IsRunning(), jc.IsFalse)
+	// Check that the container dir is no longer in the container dir
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	// but instead, in the removed container dir
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+}
+
+func mockMachineConfig(c *gc.C, machineId string) *cloudinit.MachineConfig {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig, err := environs.NewMachineConfig(machineId, "fake-nonce", imagemetadata.ReleasedStream, "win2016", true, nil, stateInfo, apiInfo)
+	c.Assert(err, jc.ErrorIsNil)
+	machineConfig.Tools = &tools.Tools{
+		Version: version.MustParseBinary("2.3.4-win2016-amd64"),
+		URL:     "http://tools.testing.invalid/2.3.4-win2016-amd64.tgz",
+	}
+	envConfig, err := config.New(config.NoDefaults, dummy.SampleConfig())
+	c.Assert(err, jc.ErrorIsNil)
+	machineConfig.Config = envConfig
+	return machineConfig
+}
+
+func (s *HypervSuite) TestIsHyperVSupportedOtherSeries(c *gc.C) {
+	s.PatchValue(&version.Current.Series, "trusty")
+	supported, err := hyperv.IsHyperVSupported()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(supported, jc.IsFalse)
+}
+
+func (s *HypervSuite) TestIsHyperVSupportedNoPowerShell(c *gc.C) {
+	s.PatchValue(&version.Current.Series, "win2016")
+	s.PatchEnvironment("PATH", "")
+	supported, err := hyperv.IsHyperVSupported()
+	c.Assert(err, gc.ErrorMatches, "powershell.exe not found")
+	c.Assert(supported, jc.IsFalse)
+}
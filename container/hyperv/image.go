@@ -0,0 +1,48 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// seriesImages maps the series of Hyper-V containers to the names of the
+// container images they are created from. A Hyper-V container runs its
+// own kernel, but the image must still be built for the host's release.
+var seriesImages = map[string]string{
+	"win2016": "WindowsServerCore",
+}
+
+// imageForSeries returns the name of the container image for containers
+// of the given series.
+func imageForSeries(series string) (string, error) {
+	image, ok := seriesImages[series]
+	if !ok {
+		return "", errors.NotSupportedf("Hyper-V containers of series %q", series)
+	}
+	return image, nil
+}
+
+// ensureImage installs the named container image from the image
+// repositories configured on the host, unless it is already installed.
+func ensureImage(name string) error {
+	output, err := RunPowerShell(fmt.Sprintf(
+		"Get-ContainerImage -Name %s -ErrorAction SilentlyContinue | Select-Object -ExpandProperty Name",
+		quote(name),
+	))
+	if err != nil {
+		return errors.Annotate(err, "cannot list container images")
+	}
+	if strings.TrimSpace(output) == name {
+		return nil
+	}
+	logger.Infof("installing container image %q", name)
+	if _, err := RunPowerShell(fmt.Sprintf("Install-ContainerImage -Name %s", quote(name))); err != nil {
+		return errors.Annotatef(err, "cannot install container image %q", name)
+	}
+	return nil
+}
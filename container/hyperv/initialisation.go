@@ -0,0 +1,39 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/container"
+)
+
+// installFeaturesScript installs the Windows features needed by Hyper-V
+// containers, and writes whether a restart is needed to finish.
+const installFeaturesScript = "Install-WindowsFeature -Name Containers,Hyper-V | Select-Object -ExpandProperty RestartNeeded"
+
+type containerInitialiser struct{}
+
+// containerInitialiser implements container.Initialiser.
+var _ container.Initialiser = (*containerInitialiser)(nil)
+
+// NewContainerInitialiser returns an instance used to perform the steps
+// required to allow a host machine to run a Hyper-V container.
+func NewContainerInitialiser() container.Initialiser {
+	return &containerInitialiser{}
+}
+
+// Initialise is specified on the container.Initialiser interface.
+func (ci *containerInitialiser) Initialise() error {
+	output, err := RunPowerShell(installFeaturesScript)
+	if err != nil {
+		return errors.Annotate(err, "cannot install the Containers and Hyper-V features")
+	}
+	if strings.TrimSpace(output) == "Yes" {
+		return errors.New("the machine must be restarted to finish installing the Containers and Hyper-V features")
+	}
+	return nil
+}
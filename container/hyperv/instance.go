@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+type hypervInstance struct {
+	container Container
+	id        string
+}
+
+var _ instance.Instance = (*hypervInstance)(nil)
+
+// Id implements instance.Instance.Id.
+func (hyperv *hypervInstance) Id() instance.Id {
+	return instance.Id(hyperv.id)
+}
+
+// Status implements instance.Instance.Status.
+func (hyperv *hypervInstance) Status() string {
+	if hyperv.container.IsRunning() {
+		return "running"
+	}
+	return "stopped"
+}
+
+func (*hypervInstance) Refresh() error {
+	return nil
+}
+
+func (hyperv *hypervInstance) Addresses() ([]network.Address, error) {
+	logger.Errorf("hypervInstance.Addresses not implemented")
+	return nil, nil
+}
+
+// OpenPorts implements instance.Instance.OpenPorts.
+func (hyperv *hypervInstance) OpenPorts(machineId string, ports []network.PortRange) error {
+	return fmt.Errorf("not implemented")
+}
+
+// ClosePorts implements instance.Instance.ClosePorts.
+func (hyperv *hypervInstance) ClosePorts(machineId string, ports []network.PortRange) error {
+	return fmt.Errorf("not implemented")
+}
+
+// Ports implements instance.Instance.Ports.
+func (hyperv *hypervInstance) Ports(machineId string) ([]network.PortRange, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Add a string representation of the id.
+func (hyperv *hypervInstance) String() string {
+	return fmt.Sprintf("hyperv:%s", hyperv.id)
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+// StartParams is a simple parameter struct for Container.Start.
+type StartParams struct {
+	// Image is the name of the container image the container is
+	// created from.
+	Image string
+
+	// SwitchName is the name of the host's virtual switch the
+	// container's network adapter is attached to.
+	SwitchName string
+
+	// UserDataFile is the path of the PowerShell script that is run
+	// inside the container once it has started.
+	UserDataFile string
+
+	Memory uint64 // MB
+}
+
+// Container represents a Hyper-V container and provides operations to
+// create, maintain and destroy the container.
+type Container interface {
+
+	// Name returns the name of the container.
+	Name() string
+
+	// Start creates and starts the container, and runs its user data.
+	Start(params StartParams) error
+
+	// Stop stops and removes the container.
+	Stop() error
+
+	// IsRunning returns whether or not the container is running.
+	IsRunning() bool
+
+	// String returns information about the container.
+	String() string
+}
+
+// ContainerFactory represents the methods used to create Containers. This
+// wraps the PowerShell cmdlets used to manage the containers.
+type ContainerFactory interface {
+	// New returns a container instance which can then be used for
+	// operations like Start() and Stop().
+	New(string) Container
+
+	// List returns all the existing containers on the system.
+	List() ([]Container, error)
+}
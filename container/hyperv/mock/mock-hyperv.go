@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mock
+
+import (
+	"fmt"
+
+	"github.com/juju/juju/container/hyperv"
+)
+
+// This file provides a mock implementation of the hyperv interfaces
+// ContainerFactory and Container.
+
+type ContainerFactory interface {
+	hyperv.ContainerFactory
+
+	// StartParams returns the parameters the named container was last
+	// started with.
+	StartParams(name string) hyperv.StartParams
+}
+
+type mockFactory struct {
+	instances map[string]*mockContainer
+}
+
+func MockFactory() ContainerFactory {
+	return &mockFactory{
+		instances: make(map[string]*mockContainer),
+	}
+}
+
+type mockContainer struct {
+	factory *mockFactory
+	name    string
+	started bool
+	params  hyperv.StartParams
+}
+
+// Name returns the name of the container.
+func (mock *mockContainer) Name() string {
+	return mock.name
+}
+
+func (mock *mockContainer) Start(params hyperv.StartParams) error {
+	if mock.started {
+		return fmt.Errorf("container is already running")
+	}
+	mock.started = true
+	mock.params = params
+	return nil
+}
+
+// Stop terminates the running container.
+func (mock *mockContainer) Stop() error {
+	if !mock.started {
+		return fmt.Errorf("container is not running")
+	}
+	mock.started = false
+	return nil
+}
+
+func (mock *mockContainer) IsRunning() bool {
+	return mock.started
+}
+
+// String returns information about the container.
+func (mock *mockContainer) String() string {
+	return fmt.Sprintf("<MockContainer %q>", mock.name)
+}
+
+func (mock *mockFactory) String() string {
+	return fmt.Sprintf("<Mock Hyper-V Factory>")
+}
+
+func (mock *mockFactory) New(name string) hyperv.Container {
+	container, ok := mock.instances[name]
+	if ok {
+		return container
+	}
+	container = &mockContainer{
+		factory: mock,
+		name:    name,
+	}
+	mock.instances[name] = container
+	return container
+}
+
+func (mock *mockFactory) List() (result []hyperv.Container, err error) {
+	for _, container := range mock.instances {
+		result = append(result, container)
+	}
+	return
+}
+
+func (mock *mockFactory) StartParams(name string) hyperv.StartParams {
+	if container, ok := mock.instances[name]; ok {
+		return container.params
+	}
+	return hyperv.StartParams{}
+}
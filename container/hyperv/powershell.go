@@ -0,0 +1,28 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hyperv
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// RunPowerShell runs the given PowerShell script and returns its output.
+// It is a variable to allow us to override behaviour in the tests.
+var RunPowerShell = func(script string) (string, error) {
+	logger.Tracef("running powershell: %s", script)
+	command := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return "", errors.Annotate(err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// quote returns s as a single-quoted PowerShell string literal.
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,45 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Functions defined in this file should *ONLY* be used for testing.  These
+// functions are exported for testing purposes only, and shouldn't be called
+// from code that isn't in a test file.
+
+package testing
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
+	"github.com/juju/juju/container/hyperv/mock"
+	"github.com/juju/juju/testing"
+)
+
+// TestSwitch is the name of the virtual switch that TestSuite reports
+// as the host's external switch.
+const TestSwitch = "juju-test-switch"
+
+// TestSuite replaces the Hyper-V factory that the manager uses with a
+// mock implementation, and PowerShell with a fake that only knows about
+// the host's external virtual switch.
+type TestSuite struct {
+	testing.BaseSuite
+	ContainerFactory mock.ContainerFactory
+	ContainerDir     string
+	RemovedDir       string
+}
+
+func (s *TestSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.ContainerDir = c.MkDir()
+	s.PatchValue(&container.ContainerDir, s.ContainerDir)
+	s.RemovedDir = c.MkDir()
+	s.PatchValue(&container.RemovedContainerDir, s.RemovedDir)
+	s.ContainerFactory = mock.MockFactory()
+	s.PatchValue(&hyperv.HypervObjectFactory, s.ContainerFactory)
+	s.PatchValue(&hyperv.RunPowerShell, func(script string) (string, error) {
+		c.Logf("powershell: %s", script)
+		return TestSwitch + "\n", nil
+	})
+}
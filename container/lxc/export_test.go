@@ -22,6 +22,8 @@ var (
 	InitProcessCgroupFile   = &initProcessCgroupFile
 	RuntimeGOOS             = &runtimeGOOS
 	ShutdownInitScript      = shutdownInitScript
+	DevDir                  = &devDir
+	InitProcessAppArmorFile = &initProcessAppArmorFile
 )
 
 func GetCreateWithCloneValue(mgr container.Manager) bool {
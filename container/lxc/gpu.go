@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/constraints"
+)
+
+const (
+	// nvidiaGpuType is the gpu-type constraint value of the GPUs that
+	// can be passed through to containers.
+	nvidiaGpuType = "nvidia"
+
+	// nvidiaDeviceMajor is the major number of the NVIDIA character
+	// devices; the minor number of /dev/nvidiaN is N.
+	nvidiaDeviceMajor = 195
+
+	// nvidiaCtlDeviceMinor is the minor number of /dev/nvidiactl.
+	nvidiaCtlDeviceMinor = 255
+)
+
+// devDir is overridden in tests.
+var devDir = "/dev"
+
+// hostGpuDevices returns the paths of the host's NVIDIA GPU devices,
+// ordered by their index.
+func hostGpuDevices() ([]string, error) {
+	var devices []string
+	for i := 0; ; i++ {
+		device := filepath.Join(devDir, fmt.Sprintf("nvidia%d", i))
+		if _, err := os.Stat(device); os.IsNotExist(err) {
+			return devices, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		devices = append(devices, device)
+	}
+}
+
+// gpuConfig returns the lxc configuration which passes the number of
+// GPUs required by the given constraints through from the host to a
+// container, along with the number of GPUs passed through.
+func gpuConfig(cons constraints.Value) (string, uint64, error) {
+	if !cons.HasGpus() {
+		return "", 0, nil
+	}
+	if cons.GpuType != nil && *cons.GpuType != "" && *cons.GpuType != nvidiaGpuType {
+		return "", 0, errors.NotSupportedf("passing %q GPUs through to lxc containers", *cons.GpuType)
+	}
+	count := uint64(1)
+	if cons.Gpus != nil && *cons.Gpus > 0 {
+		count = *cons.Gpus
+	}
+	devices, err := hostGpuDevices()
+	if err != nil {
+		return "", 0, errors.Trace(err)
+	}
+	if uint64(len(devices)) < count {
+		return "", 0, errors.Errorf("%d GPUs required, host has %d", count, len(devices))
+	}
+	var lines []string
+	mountDevice := func(device string) {
+		lines = append(lines, fmt.Sprintf(
+			"lxc.mount.entry = %s dev/%s none bind,optional,create=file 0 0",
+			device, filepath.Base(device),
+		))
+	}
+	for i := uint64(0); i < count; i++ {
+		lines = append(lines, fmt.Sprintf("lxc.cgroup.devices.allow = c %d:%d rwm", nvidiaDeviceMajor, i))
+		mountDevice(devices[i])
+	}
+	lines = append(lines, fmt.Sprintf("lxc.cgroup.devices.allow = c %d:%d rwm", nvidiaDeviceMajor, nvidiaCtlDeviceMinor))
+	mountDevice(filepath.Join(devDir, "nvidiactl"))
+	// The unified memory device has a dynamically allocated major
+	// number, so it is bound in without restricting access to it.
+	mountDevice(filepath.Join(devDir, "nvidia-uvm"))
+	return strings.Join(lines, "\n") + "\n", count, nil
+}
+
+// passThroughGpus updates the configuration of the named container so
+// that it has the GPUs required by the given constraints, and returns
+// the number of GPUs passed through.
+func passThroughGpus(name string, cons constraints.Value) (uint64, error) {
+	config, count, err := gpuConfig(cons)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	logger.Debugf("passing %d GPUs through to container %q", count, name)
+	if err := appendToContainerConfig(name, config); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return count, nil
+}
@@ -4,6 +4,7 @@
 package lxc
 
 import (
+	"github.com/juju/errors"
 	"github.com/juju/utils/apt"
 
 	"github.com/juju/juju/container"
@@ -29,6 +30,11 @@ func NewContainerInitialiser(series string) container.Initialiser {
 
 // Initialise is specified on the container.Initialiser interface.
 func (ci *containerInitialiser) Initialise() error {
+	// The lxc-net settings must be in place before the lxc package is
+	// installed, as that is when the default bridge gets created.
+	if err := ensureNestedBridgeConfig(); err != nil {
+		return errors.Annotate(err, "cannot configure nested bridge")
+	}
 	return ensureDependencies(ci.series)
 }
 
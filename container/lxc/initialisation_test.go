@@ -4,6 +4,10 @@
 package lxc
 
 import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/apt"
 	gc "gopkg.in/check.v1"
@@ -13,10 +17,23 @@ import (
 
 type InitialiserSuite struct {
 	testing.BaseSuite
+	cgroupFile string
+	netConfig  string
 }
 
 var _ = gc.Suite(&InitialiserSuite{})
 
+func (s *InitialiserSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	dir := c.MkDir()
+	s.cgroupFile = filepath.Join(dir, "cgroup")
+	s.netConfig = filepath.Join(dir, "lxc-net")
+	err := ioutil.WriteFile(s.cgroupFile, []byte("2:memory:/\n1:cpu:/\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(&initProcessCgroupFile, s.cgroupFile)
+	s.PatchValue(&lxcNetConfigFile, s.netConfig)
+}
+
 func (s *InitialiserSuite) TestLTSSeriesPackages(c *gc.C) {
 	cmdChan := s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
 	container := NewContainerInitialiser("precise")
@@ -44,3 +61,50 @@ func (s *InitialiserSuite) TestNoSeriesPackages(c *gc.C) {
 		"install", "lxc", "cloud-image-utils",
 	})
 }
+
+func (s *InitialiserSuite) TestNoBridgeConfigOnHost(c *gc.C) {
+	s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+	err := NewContainerInitialiser("").Initialise()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.netConfig, jc.DoesNotExist)
+}
+
+func (s *InitialiserSuite) TestNestedBridgeConfig(c *gc.C) {
+	err := ioutil.WriteFile(s.cgroupFile, []byte("2:memory:/lxc/juju-machine-1-lxc-0\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(&hostInterfaceAddrs, func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("10.0.3.57"), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	})
+	s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+
+	err = NewContainerInitialiser("").Initialise()
+	c.Assert(err, jc.ErrorIsNil)
+	config, err := ioutil.ReadFile(s.netConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(config), gc.Equals, `
+USE_LXC_BRIDGE="true"
+LXC_BRIDGE="lxcbr0"
+LXC_ADDR="10.0.4.1"
+LXC_NETMASK="255.255.255.0"
+LXC_NETWORK="10.0.4.0/24"
+LXC_DHCP_RANGE="10.0.4.2,10.0.4.254"
+LXC_DHCP_MAX="253"
+`[1:])
+}
+
+func (s *InitialiserSuite) TestNestedBridgeConfigExists(c *gc.C) {
+	err := ioutil.WriteFile(s.cgroupFile, []byte("2:memory:/lxc/juju-machine-1-lxc-0\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(s.netConfig, []byte("custom"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.HookCommandOutput(&apt.CommandOutput, []byte{}, nil)
+
+	err = NewContainerInitialiser("").Initialise()
+	c.Assert(err, jc.ErrorIsNil)
+	config, err := ioutil.ReadFile(s.netConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(config), gc.Equals, "custom")
+}
@@ -83,32 +83,16 @@ func IsLXCSupported() (bool, error) {
 	if runtimeGOOS != "linux" {
 		return false, nil
 	}
-
-	file, err := os.Open(initProcessCgroupFile)
+	inContainer, err := runningInContainer()
 	if err != nil {
-		return false, errors.Trace(err)
+		return false, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Split(line, ":")
-		if len(fields) != 3 {
-			return false, errors.Errorf("Malformed cgroup file")
-		}
-		if fields[2] != "/" {
-			// When running in a container the anchor point will be something
-			// other then "/". Return false here as we do not support nested LXC
-			// containers
-			return false, nil
-		}
+	if !inContainer {
+		return true, nil
 	}
-
-	if err := scanner.Err(); err != nil {
-		return false, errors.Errorf("Failed to read cgroup file")
-	}
-	return true, nil
+	// Nested LXC containers are only supported when the container we
+	// are running in allows nesting.
+	return nestingAllowed()
 }
 
 type containerManager struct {
@@ -313,6 +297,13 @@ func (manager *containerManager) CreateContainer(
 	if err := mountHostLogDir(name, manager.logdir); err != nil {
 		return nil, nil, errors.Annotate(err, "failed to mount the directory to log to")
 	}
+	if err := allowNesting(name); err != nil {
+		return nil, nil, errors.Annotate(err, "failed to allow nested containers")
+	}
+	gpus, err := passThroughGpus(name, machineConfig.Constraints)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed to pass GPUs through")
+	}
 	// Update the network settings inside the run-time config of the
 	// container (e.g. /var/lib/lxc/<name>/config) before starting it.
 	netConfig := generateNetworkConfig(networkConfig)
@@ -364,6 +355,11 @@ func (manager *containerManager) CreateContainer(
 	hardware := &instance.HardwareCharacteristics{
 		Arch: &version.Current.Arch,
 	}
+	if gpus > 0 {
+		gpuType := nvidiaGpuType
+		hardware.Gpus = &gpus
+		hardware.GpuType = &gpuType
+	}
 
 	return &lxcInstance{lxcContainer, name}, hardware, nil
 }
@@ -711,6 +707,13 @@ func autostartContainer(name string) error {
 	return nil
 }
 
+// SetStartOrder sets the order in which the named container is
+// started, relative to the host's other containers, when the host
+// boots. Containers with a higher order are started first.
+func SetStartOrder(name string, order int) error {
+	return updateContainerConfig(name, fmt.Sprintf("lxc.start.order = %d\n", order))
+}
+
 func mountHostLogDir(name, logDir string) error {
 	// Make sure that the mount dir has been created.
 	internalDir := internalLogDir(name)
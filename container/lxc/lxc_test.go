@@ -27,11 +27,13 @@ import (
 	"launchpad.net/golxc"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/container/lxc/mock"
 	lxctesting "github.com/juju/juju/container/lxc/testing"
 	containertesting "github.com/juju/juju/container/testing"
+	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	instancetest "github.com/juju/juju/instance/testing"
@@ -426,6 +428,22 @@ lxc.foo = baz
 	c.Assert(string(lxcConfContents), gc.Equals, updatedConfig)
 }
 
+func (s *LxcSuite) TestSetStartOrder(c *gc.C) {
+	manager := s.makeManager(c, "test")
+	instance := containertesting.CreateContainer(c, manager, "1/lxc/0")
+	name := string(instance.Id())
+
+	err := lxc.SetStartOrder(name, 3)
+	c.Assert(err, jc.ErrorIsNil)
+	err = lxc.SetStartOrder(name, 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename(name))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(strings.Count(string(config), "lxc.start.order"), gc.Equals, 1)
+	c.Assert(string(config), jc.Contains, "lxc.start.order = 2\n")
+}
+
 func (*LxcSuite) TestReorderNetworkConfig(c *gc.C) {
 	path := c.MkDir()
 	configFile := filepath.Join(path, "config")
@@ -659,6 +677,64 @@ func (s *LxcSuite) TestCreateContainer(c *gc.C) {
 	c.Assert(location, gc.Equals, expectedTarget)
 }
 
+func (s *LxcSuite) gpuMachineConfig(c *gc.C, cons string) *cloudinit.MachineConfig {
+	machineConfig, err := containertesting.MockMachineConfig("1/lxc/0")
+	c.Assert(err, jc.ErrorIsNil)
+	envConfig, err := config.New(config.NoDefaults, dummy.SampleConfig())
+	c.Assert(err, jc.ErrorIsNil)
+	machineConfig.Config = envConfig
+	machineConfig.Constraints = constraints.MustParse(cons)
+	return machineConfig
+}
+
+func (s *LxcSuite) TestCreateContainerWithGpus(c *gc.C) {
+	devDir := c.MkDir()
+	for _, device := range []string{"nvidia0", "nvidia1", "nvidiactl"} {
+		err := ioutil.WriteFile(filepath.Join(devDir, device), nil, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.PatchValue(lxc.DevDir, devDir)
+
+	manager := s.makeManager(c, "test")
+	machineConfig := s.gpuMachineConfig(c, "gpus=1 gpu-type=nvidia")
+	inst, hardware, err := manager.CreateContainer(
+		machineConfig, "quantal", container.BridgeNetworkConfig("nic42", nil),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*hardware.Gpus, gc.Equals, uint64(1))
+	c.Assert(*hardware.GpuType, gc.Equals, "nvidia")
+
+	config, err := ioutil.ReadFile(lxc.ContainerConfigFilename(string(inst.Id())))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(config), jc.Contains, fmt.Sprintf(`
+lxc.cgroup.devices.allow = c 195:0 rwm
+lxc.mount.entry = %[1]s/nvidia0 dev/nvidia0 none bind,optional,create=file 0 0
+lxc.cgroup.devices.allow = c 195:255 rwm
+lxc.mount.entry = %[1]s/nvidiactl dev/nvidiactl none bind,optional,create=file 0 0
+lxc.mount.entry = %[1]s/nvidia-uvm dev/nvidia-uvm none bind,optional,create=file 0 0
+`, devDir))
+	c.Assert(string(config), gc.Not(jc.Contains), "nvidia1")
+}
+
+func (s *LxcSuite) TestCreateContainerWithGpusUnavailable(c *gc.C) {
+	s.PatchValue(lxc.DevDir, c.MkDir())
+	manager := s.makeManager(c, "test")
+	machineConfig := s.gpuMachineConfig(c, "gpus=2")
+	_, _, err := manager.CreateContainer(
+		machineConfig, "quantal", container.BridgeNetworkConfig("nic42", nil),
+	)
+	c.Assert(err, gc.ErrorMatches, "failed to pass GPUs through: 2 GPUs required, host has 0")
+}
+
+func (s *LxcSuite) TestCreateContainerWithUnsupportedGpuType(c *gc.C) {
+	manager := s.makeManager(c, "test")
+	machineConfig := s.gpuMachineConfig(c, "gpu-type=amd")
+	_, _, err := manager.CreateContainer(
+		machineConfig, "quantal", container.BridgeNetworkConfig("nic42", nil),
+	)
+	c.Assert(err, gc.ErrorMatches, `failed to pass GPUs through: passing "amd" GPUs through to lxc containers not supported`)
+}
+
 func (s *LxcSuite) TestCreateContainerFailsWithInjectedError(c *gc.C) {
 	errorChannel := make(chan error, 1)
 	cleanup := mock.PatchTransientErrorInjectionChannel(errorChannel)
@@ -958,6 +1034,8 @@ lxc.network.mtu = 4321
 
 lxc.start.auto = 1
 lxc.mount.entry = %s var/log/juju none defaults,bind 0 0
+lxc.aa_profile = lxc-container-default-with-nesting
+lxc.mount.auto = cgroup
 `, s.logDir)
 	c.Assert(string(config), gc.Equals, expected)
 	c.Assert(autostartLink, jc.DoesNotExist)
@@ -1244,12 +1322,28 @@ func (s *LxcSuite) TestIsLXCSupportedOnLXCContainer(c *gc.C) {
 	ft.File{"cgroup", lxcCgroupContents, 0400}.Create(c, baseDir)
 
 	s.PatchValue(lxc.InitProcessCgroupFile, cgroup)
+	s.PatchValue(lxc.InitProcessAppArmorFile, filepath.Join(baseDir, "missing"))
 	supports, err := lxc.IsLXCSupported()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(supports, jc.IsFalse)
 
 }
 
+func (s *LxcSuite) TestIsLXCSupportedOnNestingLXCContainer(c *gc.C) {
+	baseDir := c.MkDir()
+	cgroup := filepath.Join(baseDir, "cgroup")
+	profile := filepath.Join(baseDir, "current")
+
+	ft.File{"cgroup", lxcCgroupContents, 0400}.Create(c, baseDir)
+	ft.File{"current", "lxc-container-default-with-nesting (enforce)\n", 0400}.Create(c, baseDir)
+
+	s.PatchValue(lxc.InitProcessCgroupFile, cgroup)
+	s.PatchValue(lxc.InitProcessAppArmorFile, profile)
+	supports, err := lxc.IsLXCSupported()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(supports, jc.IsTrue)
+}
+
 func (s *LxcSuite) TestIsLXCSupportedMissingCgroupFile(c *gc.C) {
 	s.PatchValue(lxc.InitProcessCgroupFile, "")
 	supports, err := lxc.IsLXCSupported()
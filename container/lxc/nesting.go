@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// nestingAppArmorProfile is the AppArmor profile shipped with the lxc
+// package which allows a container to start containers of its own.
+const nestingAppArmorProfile = "lxc-container-default-with-nesting"
+
+var (
+	initProcessAppArmorFile = "/proc/1/attr/current"
+	lxcNetConfigFile        = "/etc/default/lxc-net"
+	hostInterfaceAddrs      = net.InterfaceAddrs
+)
+
+// runningInContainer reports whether the init process of the machine
+// we're running on has been confined to a container's cgroups.
+func runningInContainer() (bool, error) {
+	file, err := os.Open(initProcessCgroupFile)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Split(line, ":")
+		if len(fields) != 3 {
+			return false, errors.Errorf("Malformed cgroup file")
+		}
+		if fields[2] != "/" {
+			// When running in a container the anchor point will be
+			// something other then "/".
+			return true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, errors.Errorf("Failed to read cgroup file")
+	}
+	return false, nil
+}
+
+// nestingAllowed reports whether the container we're running in has
+// been started with an AppArmor profile which allows nested containers.
+func nestingAllowed() (bool, error) {
+	profile, err := ioutil.ReadFile(initProcessAppArmorFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return strings.HasPrefix(string(profile), nestingAppArmorProfile), nil
+}
+
+// allowNesting configures the named container so that it can host
+// LXC containers of its own.
+func allowNesting(name string) error {
+	lines := fmt.Sprintf(
+		"lxc.aa_profile = %s\nlxc.mount.auto = cgroup\n",
+		nestingAppArmorProfile,
+	)
+	return appendToContainerConfig(name, lines)
+}
+
+// ensureNestedBridgeConfig makes sure the default LXC bridge created
+// inside a container does not reuse the subnet of the bridge the
+// container itself is connected to, as otherwise neither the nested
+// containers nor the host would be reachable. It does nothing when
+// not running in a container, or when the lxc-net settings already
+// exist.
+func ensureNestedBridgeConfig() error {
+	inContainer, err := runningInContainer()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !inContainer {
+		return nil
+	}
+	if _, err := os.Stat(lxcNetConfigFile); err == nil {
+		logger.Debugf("not overwriting existing %q", lxcNetConfigFile)
+		return nil
+	} else if !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	subnet, err := freeBridgeSubnet()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("using subnet %v for nested %s bridge", subnet, DefaultLxcBridge)
+	return utils.AtomicWriteFile(lxcNetConfigFile, []byte(lxcNetConfig(subnet)), 0644)
+}
+
+// freeBridgeSubnet returns the first of the 10.0.X.0/24 subnets, starting
+// with the one used by the lxc package by default, which does not
+// contain any of the addresses of the machine we're running on.
+func freeBridgeSubnet() (*net.IPNet, error) {
+	addrs, err := hostInterfaceAddrs()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get network interface addresses")
+	}
+	for i := 3; i < 256; i++ {
+		_, subnet, err := net.ParseCIDR(fmt.Sprintf("10.0.%d.0/24", i))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		inUse := false
+		for _, addr := range addrs {
+			ip, _, err := net.ParseCIDR(addr.String())
+			if err != nil {
+				ip = net.ParseIP(addr.String())
+			}
+			if ip != nil && subnet.Contains(ip) {
+				inUse = true
+				break
+			}
+		}
+		if !inUse {
+			return subnet, nil
+		}
+	}
+	return nil, errors.New("no free subnet available for the nested bridge")
+}
+
+// lxcNetConfig returns the lxc-net settings for a bridge using the
+// given /24 subnet.
+func lxcNetConfig(subnet *net.IPNet) string {
+	prefix := strings.TrimSuffix(subnet.IP.String(), ".0")
+	return fmt.Sprintf(`
+USE_LXC_BRIDGE="true"
+LXC_BRIDGE="%[1]s"
+LXC_ADDR="%[2]s.1"
+LXC_NETMASK="255.255.255.0"
+LXC_NETWORK="%[3]s"
+LXC_DHCP_RANGE="%[2]s.2,%[2]s.254"
+LXC_DHCP_MAX="253"
+`[1:], DefaultLxcBridge, prefix, subnet.String())
+}
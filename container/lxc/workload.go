@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/golxc"
+
+	"github.com/juju/juju/container"
+)
+
+// workloadContainerConfig is the initial configuration of workload
+// containers. They share the network of the machine, so that the ports
+// opened by the charm are opened on the machine, and so that the hook
+// tools can reach the unit agent's socket.
+const workloadContainerConfig = "lxc.network.type = none\n"
+
+// WorkloadContainerName returns the name of the container in which the
+// charm of the given unit runs, when the unit's service runs its charm
+// in workload containers.
+func WorkloadContainerName(unitName string) string {
+	return "juju-workload-" + names.NewUnitTag(unitName).String()
+}
+
+// CreateWorkloadContainer creates and starts an LXC container of the
+// given series, in which the charm of a unit can be run. The juju data
+// directory is bind mounted into the container at the same path, so
+// that the charm and the hook tools can be found there. The container
+// is started again whenever the machine restarts. Any container left
+// behind with the same name is destroyed first.
+func CreateWorkloadContainer(name, series, dataDir string) (err error) {
+	lxcContainer := LxcObjectFactory.New(name)
+	if lxcContainer.IsConstructed() {
+		logger.Infof("destroying stale workload container %q", name)
+		if err := DestroyWorkloadContainer(name); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	directory, err := container.NewDirectory(name)
+	if err != nil {
+		return errors.Annotate(err, "failed to create a directory for the container")
+	}
+	configPath := filepath.Join(directory, "lxc.conf")
+	if err := ioutil.WriteFile(configPath, []byte(workloadContainerConfig), 0644); err != nil {
+		return errors.Annotatef(err, "failed to write container config %q", configPath)
+	}
+	templateParams := []string{
+		"--debug",        // Debug errors in the cloud image
+		"--hostid", name, // Use the container name as the hostid
+		"-r", series,
+	}
+	logger.Debugf("creating workload container %q", name)
+	if err := lxcContainer.Create(configPath, defaultTemplate, nil, templateParams, nil); err != nil {
+		return errors.Annotate(err, "lxc container creation failed")
+	}
+	defer func() {
+		if err != nil {
+			if derr := DestroyWorkloadContainer(name); derr != nil {
+				logger.Errorf("failed to destroy workload container %q: %v", name, derr)
+			}
+		}
+	}()
+	if err := autostartContainer(name); err != nil {
+		return errors.Annotate(err, "failed to configure the container for autostart")
+	}
+	if err := mountDataDir(name, dataDir); err != nil {
+		return errors.Annotate(err, "failed to mount the juju data directory")
+	}
+	consoleFile := filepath.Join(directory, "console.log")
+	lxcContainer.SetLogFile(filepath.Join(directory, "container.log"), golxc.LogDebug)
+	if err := lxcContainer.Start("", consoleFile); err != nil {
+		return errors.Annotate(err, "failed to start the container")
+	}
+	logger.Infof("started workload container %q", name)
+	return nil
+}
+
+// DestroyWorkloadContainer stops and destroys the named workload
+// container, if it exists.
+func DestroyWorkloadContainer(name string) error {
+	if useRestartDir() {
+		if err := os.Remove(restartSymlink(name)); err != nil && !os.IsNotExist(err) {
+			return errors.Annotate(err, "failed to remove restart symlink")
+		}
+	}
+	lxcContainer := LxcObjectFactory.New(name)
+	if lxcContainer.IsConstructed() {
+		if err := lxcContainer.Destroy(); err != nil {
+			return errors.Annotatef(err, "failed to destroy workload container %q", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(container.ContainerDir, name)); os.IsNotExist(err) {
+		return nil
+	}
+	return container.RemoveDirectory(name)
+}
+
+// mountDataDir bind mounts the given directory at the same path inside
+// the named container.
+func mountDataDir(name, dataDir string) error {
+	internalDir := filepath.Join(LxcContainerDir, name, "rootfs", dataDir)
+	if err := os.MkdirAll(internalDir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	line := fmt.Sprintf(
+		"lxc.mount.entry = %s %s none defaults,bind 0 0\n",
+		dataDir, strings.TrimPrefix(dataDir, "/"))
+	return appendToContainerConfig(name, line)
+}
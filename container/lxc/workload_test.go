@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lxc_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/container/lxc"
+)
+
+func (s *LxcSuite) TestWorkloadContainerName(c *gc.C) {
+	c.Assert(lxc.WorkloadContainerName("mysql/0"), gc.Equals, "juju-workload-unit-mysql-0")
+}
+
+func (s *LxcSuite) TestCreateWorkloadContainer(c *gc.C) {
+	dataDir := c.MkDir()
+	name := lxc.WorkloadContainerName("mysql/0")
+	err := lxc.CreateWorkloadContainer(name, "trusty", dataDir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	lxcContainer := lxc.LxcObjectFactory.New(name)
+	c.Assert(lxcContainer.IsRunning(), jc.IsTrue)
+	config, err := ioutil.ReadFile(filepath.Join(s.LxcDir, name, "config"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(config), jc.Contains, "lxc.network.type = none\n")
+	c.Assert(string(config), jc.Contains, "lxc.mount.entry = "+dataDir+" "+dataDir[1:]+" none defaults,bind 0 0\n")
+	c.Assert(filepath.Join(s.LxcDir, name, "rootfs", dataDir), jc.IsDirectory)
+	c.Assert(lxc.RestartSymlink(name), jc.IsSymlink)
+}
+
+func (s *LxcSuite) TestCreateWorkloadContainerReplacesStale(c *gc.C) {
+	dataDir := c.MkDir()
+	name := lxc.WorkloadContainerName("mysql/0")
+	err := lxc.CreateWorkloadContainer(name, "trusty", dataDir)
+	c.Assert(err, jc.ErrorIsNil)
+	err = lxc.CreateWorkloadContainer(name, "trusty", dataDir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lxc.LxcObjectFactory.New(name).IsRunning(), jc.IsTrue)
+}
+
+func (s *LxcSuite) TestDestroyWorkloadContainer(c *gc.C) {
+	name := lxc.WorkloadContainerName("mysql/0")
+	err := lxc.CreateWorkloadContainer(name, "trusty", c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = lxc.DestroyWorkloadContainer(name)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lxc.LxcObjectFactory.New(name).IsConstructed(), jc.IsFalse)
+	c.Assert(filepath.Join(s.ContainerDir, name), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, name), jc.IsDirectory)
+	c.Assert(lxc.RestartSymlink(name), jc.SymlinkDoesNotExist)
+
+	// Destroying a container that does not exist is not an error.
+	err = lxc.DestroyWorkloadContainer(name)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -0,0 +1,325 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package apitrace records the latency and outcome of the calls made
+// to cloud provider APIs, and throttles the calls made to an endpoint
+// after it reports that its rate limit has been exceeded.
+//
+// The provider clients used by juju make their requests through
+// http.DefaultTransport, so Install wraps it to trace every call.
+package apitrace
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+var logger = loggo.GetLogger("juju.environs.apitrace")
+
+const (
+	// minBackoff is how long calls to an endpoint are held back after
+	// it first reports that its rate limit has been exceeded.
+	minBackoff = time.Second
+
+	// maxBackoff is the longest calls to an endpoint are held back,
+	// however many times in a row it reports that its rate limit has
+	// been exceeded.
+	maxBackoff = time.Minute
+
+	// maxErrorBody is the most of an error response's body that is
+	// read to find out whether it reports an exceeded rate limit.
+	maxErrorBody = 64 * 1024
+)
+
+// rateLimitCodes holds the error codes with which providers report,
+// in the body of an error response, that their rate limit has been
+// exceeded.
+var rateLimitCodes = []string{
+	"RequestLimitExceeded",
+	"Throttling",
+	"TooManyRequests",
+}
+
+// Report describes the calls made to each provider endpoint, by host.
+type Report map[string]EndpointReport
+
+// EndpointReport describes the calls made to a provider endpoint.
+type EndpointReport struct {
+	// Calls describes the calls made to the endpoint, by call name.
+	Calls map[string]CallReport `yaml:"calls" json:"calls"`
+
+	// RateLimited holds how many times the endpoint has reported that
+	// its rate limit has been exceeded.
+	RateLimited int `yaml:"rate-limited,omitempty" json:"rate-limited,omitempty"`
+
+	// ThrottledUntil holds the time, in RFC3339 format, until which
+	// calls to the endpoint are held back, if they are.
+	ThrottledUntil string `yaml:"throttled-until,omitempty" json:"throttled-until,omitempty"`
+}
+
+// CallReport describes the calls of one kind made to an endpoint.
+type CallReport struct {
+	Calls       int    `yaml:"calls" json:"calls"`
+	Errors      int    `yaml:"errors" json:"errors"`
+	MeanLatency string `yaml:"mean-latency" json:"mean-latency"`
+	MaxLatency  string `yaml:"max-latency" json:"max-latency"`
+	LastError   string `yaml:"last-error,omitempty" json:"last-error,omitempty"`
+}
+
+// callStats accumulates the statistics of the calls of one kind.
+type callStats struct {
+	calls        int
+	errors       int
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	lastError    string
+}
+
+// endpoint holds the state of the calls made to one host.
+type endpoint struct {
+	calls       map[string]*callStats
+	rateLimited int
+	backoff     time.Duration
+	until       time.Time
+}
+
+// Transport is an http.RoundTripper that traces and throttles the
+// requests made through another.
+type Transport struct {
+	base  http.RoundTripper
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mu        sync.Mutex
+	endpoints map[string]*endpoint
+}
+
+// NewTransport returns a Transport that makes its requests through
+// base.
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{
+		base:      base,
+		now:       time.Now,
+		sleep:     time.Sleep,
+		endpoints: make(map[string]*endpoint),
+	}
+}
+
+var (
+	installOnce sync.Once
+	installed   *Transport
+)
+
+// Install wraps http.DefaultTransport, so that the calls made through
+// it are traced, and returns the wrapping Transport. It only wraps it
+// the first time it is called.
+func Install() *Transport {
+	installOnce.Do(func() {
+		installed = NewTransport(http.DefaultTransport)
+		http.DefaultTransport = installed
+	})
+	return installed
+}
+
+// CurrentReport returns the report of the installed Transport, or an
+// empty report if none is installed.
+func CurrentReport() Report {
+	if installed == nil {
+		return Report{}
+	}
+	return installed.Report()
+}
+
+// RoundTrip is part of the http.RoundTripper interface. A request to
+// an endpoint that has reported that its rate limit has been exceeded
+// waits until the endpoint's backoff has passed.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if delay := t.delay(host); delay > 0 {
+		logger.Debugf("throttling call to %s for %v", host, delay)
+		t.sleep(delay)
+	}
+	start := t.now()
+	resp, err := t.base.RoundTrip(req)
+	latency := t.now().Sub(start)
+
+	var callErr string
+	limited := false
+	switch {
+	case err != nil:
+		callErr = err.Error()
+	case resp.StatusCode >= 400:
+		callErr = resp.Status
+		limited = isRateLimited(resp)
+	}
+	t.record(host, callName(req), latency, callErr, limited, retryAfter(resp))
+	return resp, err
+}
+
+// RegisterProtocol registers a new protocol with the wrapped transport,
+// as http.Transport.RegisterProtocol does. It panics if the wrapped
+// transport does not support it.
+func (t *Transport) RegisterProtocol(scheme string, rt http.RoundTripper) {
+	t.base.(interface {
+		RegisterProtocol(string, http.RoundTripper)
+	}).RegisterProtocol(scheme, rt)
+}
+
+// delay returns how long a call to the given host must wait.
+func (t *Transport) delay(host string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ep, ok := t.endpoints[host]
+	if !ok {
+		return 0
+	}
+	return ep.until.Sub(t.now())
+}
+
+// record adds the outcome of a call to the statistics of its endpoint
+// and, if the call was rate limited, backs off from the endpoint.
+func (t *Transport) record(host, name string, latency time.Duration, callErr string, limited bool, after time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ep, ok := t.endpoints[host]
+	if !ok {
+		ep = &endpoint{calls: make(map[string]*callStats)}
+		t.endpoints[host] = ep
+	}
+	stats, ok := ep.calls[name]
+	if !ok {
+		stats = &callStats{}
+		ep.calls[name] = stats
+	}
+	stats.calls++
+	stats.totalLatency += latency
+	if latency > stats.maxLatency {
+		stats.maxLatency = latency
+	}
+	if callErr != "" {
+		stats.errors++
+		stats.lastError = callErr
+	}
+	if !limited {
+		ep.backoff = 0
+		return
+	}
+	ep.rateLimited++
+	ep.backoff *= 2
+	if ep.backoff < minBackoff {
+		ep.backoff = minBackoff
+	}
+	if ep.backoff > maxBackoff {
+		ep.backoff = maxBackoff
+	}
+	backoff := ep.backoff
+	if after > backoff {
+		backoff = after
+	}
+	ep.until = t.now().Add(backoff)
+	logger.Warningf("%s call %q was rate limited; holding back calls for %v", host, name, backoff)
+}
+
+// Report returns a report of the calls made through the Transport.
+func (t *Transport) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := make(Report)
+	now := t.now()
+	for host, ep := range t.endpoints {
+		epReport := EndpointReport{
+			Calls:       make(map[string]CallReport),
+			RateLimited: ep.rateLimited,
+		}
+		if ep.until.After(now) {
+			epReport.ThrottledUntil = ep.until.UTC().Format(time.RFC3339)
+		}
+		for name, stats := range ep.calls {
+			epReport.Calls[name] = CallReport{
+				Calls:       stats.calls,
+				Errors:      stats.errors,
+				MeanLatency: (stats.totalLatency / time.Duration(stats.calls)).String(),
+				MaxLatency:  stats.maxLatency.String(),
+				LastError:   stats.lastError,
+			}
+		}
+		report[host] = epReport
+	}
+	return report
+}
+
+// isRateLimited reports whether the given error response says that
+// the endpoint's rate limit has been exceeded. The start of the body
+// of the response is read, and put back so that the body can still be
+// read in full.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == 429 {
+		return true
+	}
+	if resp.Body == nil {
+		return false
+	}
+	head, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(head), resp.Body),
+		Closer: resp.Body,
+	}
+	for _, code := range rateLimitCodes {
+		if bytes.Contains(head, []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of
+// the given response, if it has one in seconds.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// idSegment matches the path segments that hold ids rather than name
+// a kind of resource: those containing a digit that are not an API
+// version.
+var (
+	idSegment      = regexp.MustCompile(`[0-9]`)
+	versionSegment = regexp.MustCompile(`^v[0-9]+(\.[0-9]+)*$`)
+)
+
+// callName returns the name under which a request is traced. Query
+// APIs such as EC2's name the call in the Action parameter; for other
+// APIs the name is the method and path of the request, with any ids
+// in the path replaced by "*".
+func callName(req *http.Request) string {
+	if action := req.URL.Query().Get("Action"); action != "" {
+		return action
+	}
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) && !versionSegment.MatchString(segment) {
+			segments[i] = "*"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
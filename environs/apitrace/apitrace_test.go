@@ -0,0 +1,182 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apitrace_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/apitrace"
+	"github.com/juju/juju/testing"
+)
+
+type transportSuite struct {
+	testing.BaseSuite
+	now     time.Time
+	slept   []time.Duration
+	latency time.Duration
+}
+
+var _ = gc.Suite(&transportSuite{})
+
+func (s *transportSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.slept = nil
+	s.latency = 100 * time.Millisecond
+}
+
+// respond returns a RoundTripper which answers every request with a
+// response made by the given function, taking s.latency to do so.
+func (s *transportSuite) respond(f func(*http.Request) (*http.Response, error)) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		s.now = s.now.Add(s.latency)
+		return f(req)
+	})
+}
+
+func (s *transportSuite) newTransport(base http.RoundTripper) *apitrace.Transport {
+	return apitrace.NewTransportWithClock(base, func() time.Time {
+		return s.now
+	}, func(d time.Duration) {
+		s.slept = append(s.slept, d)
+		s.now = s.now.Add(d)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func get(c *gc.C, t http.RoundTripper, url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return t.RoundTrip(req)
+}
+
+func (s *transportSuite) TestReport(c *gc.C) {
+	t := s.newTransport(s.respond(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.Contains(req.URL.Path, "broken"):
+			return nil, errors.New("connection refused")
+		case strings.Contains(req.URL.Path, "missing"):
+			return response(404, "no such server"), nil
+		}
+		return response(200, ""), nil
+	}))
+	get(c, t, "https://ec2.example.com/?Action=DescribeInstances&InstanceId.1=i-1234")
+	s.latency = 300 * time.Millisecond
+	get(c, t, "https://ec2.example.com/?Action=DescribeInstances")
+	get(c, t, "https://nova.example.com/v2/3b5a1e/servers/0f3e-44a1/missing")
+	get(c, t, "https://nova.example.com/v2/3b5a1e/servers/broken")
+
+	c.Assert(t.Report(), jc.DeepEquals, apitrace.Report{
+		"ec2.example.com": {
+			Calls: map[string]apitrace.CallReport{
+				"DescribeInstances": {
+					Calls:       2,
+					MeanLatency: "200ms",
+					MaxLatency:  "300ms",
+				},
+			},
+		},
+		"nova.example.com": {
+			Calls: map[string]apitrace.CallReport{
+				"GET /v2/*/servers/*/missing": {
+					Calls:       1,
+					Errors:      1,
+					MeanLatency: "300ms",
+					MaxLatency:  "300ms",
+					LastError:   "Not Found",
+				},
+				"GET /v2/*/servers/broken": {
+					Calls:       1,
+					Errors:      1,
+					MeanLatency: "300ms",
+					MaxLatency:  "300ms",
+					LastError:   "connection refused",
+				},
+			},
+		},
+	})
+}
+
+func (s *transportSuite) TestThrottling(c *gc.C) {
+	limited := true
+	t := s.newTransport(s.respond(func(req *http.Request) (*http.Response, error) {
+		if limited {
+			return response(503, "<Code>RequestLimitExceeded</Code>"), nil
+		}
+		return response(200, ""), nil
+	}))
+	url := "https://ec2.example.com/?Action=RunInstances"
+
+	// The body of a rate limited response can still be read.
+	resp, err := get(c, t, url)
+	c.Assert(err, jc.ErrorIsNil)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(body), gc.Equals, "<Code>RequestLimitExceeded</Code>")
+	c.Assert(s.slept, gc.HasLen, 0)
+
+	report := t.Report()["ec2.example.com"]
+	c.Assert(report.RateLimited, gc.Equals, 1)
+	c.Assert(report.ThrottledUntil, gc.Equals, "2015-06-01T12:00:01Z")
+
+	// Calls are held back for longer each time the endpoint is rate
+	// limited again.
+	get(c, t, url)
+	get(c, t, url)
+	c.Assert(s.slept, jc.DeepEquals, []time.Duration{
+		time.Second,
+		2 * time.Second,
+	})
+
+	// Calls to other endpoints are not held back.
+	get(c, t, "https://other.example.com/")
+	c.Assert(s.slept, gc.HasLen, 2)
+
+	// A successful call, made once the backoff has passed, resets
+	// the backoff.
+	limited = false
+	get(c, t, url)
+	limited = true
+	get(c, t, url)
+	get(c, t, url)
+	c.Assert(s.slept, jc.DeepEquals, []time.Duration{
+		time.Second,
+		2 * time.Second,
+		3900 * time.Millisecond,
+		time.Second,
+	})
+	c.Assert(t.Report()["ec2.example.com"].RateLimited, gc.Equals, 5)
+}
+
+func (s *transportSuite) TestThrottlingRetryAfter(c *gc.C) {
+	t := s.newTransport(s.respond(func(req *http.Request) (*http.Response, error) {
+		resp := response(429, "")
+		resp.Header.Set("Retry-After", "30")
+		return resp, nil
+	}))
+	get(c, t, "https://compute.example.com/servers")
+	get(c, t, "https://compute.example.com/servers")
+	c.Assert(s.slept, jc.DeepEquals, []time.Duration{30 * time.Second})
+}
@@ -0,0 +1,18 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apitrace
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewTransportWithClock returns a Transport as NewTransport does,
+// which uses the given functions to tell the time and to wait.
+func NewTransportWithClock(base http.RoundTripper, now func() time.Time, sleep func(time.Duration)) *Transport {
+	t := NewTransport(base)
+	t.now = now
+	t.sleep = sleep
+	return t
+}
@@ -14,7 +14,9 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/environs/offline"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/environs/storage"
 	"github.com/juju/juju/environs/sync"
@@ -50,6 +52,13 @@ type BootstrapParams struct {
 	// MetadataDir is an optional path to a local directory containing
 	// tools and/or image metadata.
 	MetadataDir string
+
+	// Offline reports whether the environment is to run without
+	// access to the internet. If so, MetadataDir must hold the tools
+	// to bootstrap with, in the layout read by the offline package,
+	// and the state servers will not fetch anything from outside the
+	// environment.
+	Offline bool
 }
 
 // Bootstrap bootstraps the given environment. The supplied constraints are
@@ -90,6 +99,14 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	if err := validateConstraints(environ, args.Constraints); err != nil {
 		return err
 	}
+	var artifacts *offline.Artifacts
+	if args.Offline {
+		var err error
+		artifacts, err = readOfflineArtifacts(cfg, args)
+		if err != nil {
+			return err
+		}
+	}
 
 	_, supportsNetworking := environs.SupportsNetworking(environ)
 
@@ -97,11 +114,21 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	logger.Debugf("environment %q supports service/machine networks: %v", cfg.Name(), supportsNetworking)
 	disableNetworkManagement, _ := cfg.DisableNetworkManagement()
 	logger.Debugf("network management by juju enabled: %v", !disableNetworkManagement)
-	availableTools, err := findAvailableTools(environ, args.Constraints.Arch, args.UploadTools)
-	if errors.IsNotFound(err) {
-		return errors.New(noToolsMessage)
-	} else if err != nil {
-		return err
+	var (
+		availableTools coretools.List
+		err            error
+	)
+	if artifacts != nil {
+		availableTools = artifacts.MatchingTools(
+			version.Current.Number, offlineArch(args.Constraints), artifacts.Tools.AllSeries()...,
+		)
+	} else {
+		availableTools, err = findAvailableTools(environ, args.Constraints.Arch, args.UploadTools)
+		if errors.IsNotFound(err) {
+			return errors.New(noToolsMessage)
+		} else if err != nil {
+			return err
+		}
 	}
 
 	// If we're uploading, we must override agent-version;
@@ -109,9 +136,13 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	// agent-version set anyway, to appease FinishMachineConfig.
 	// In the latter case, setBootstrapTools will later set
 	// agent-version to the correct thing.
-	if cfg, err = cfg.Apply(map[string]interface{}{
+	attrs := map[string]interface{}{
 		"agent-version": version.Current.Number.String(),
-	}); err != nil {
+	}
+	if args.Offline {
+		attrs["offline-mode"] = true
+	}
+	if cfg, err = cfg.Apply(attrs); err != nil {
 		return err
 	}
 	if err = environ.SetConfig(cfg); err != nil {
@@ -169,6 +200,35 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	return nil
 }
 
+// readOfflineArtifacts reads the tools and container images in the
+// metadata directory, and checks that they include tools for the
+// environment's default series that the bootstrap instance can run.
+func readOfflineArtifacts(cfg *config.Config, args BootstrapParams) (*offline.Artifacts, error) {
+	if args.MetadataDir == "" {
+		return nil, errors.New("offline bootstrap requires a metadata source")
+	}
+	artifacts, err := offline.Read(args.MetadataDir)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read offline artifacts")
+	}
+	err = artifacts.Validate(
+		version.Current.Number, offlineArch(args.Constraints), config.PreferredSeries(cfg),
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return artifacts, nil
+}
+
+// offlineArch returns the architecture required by the given
+// constraints, or "" if any architecture will do.
+func offlineArch(cons constraints.Value) string {
+	if cons.Arch != nil {
+		return *cons.Arch
+	}
+	return ""
+}
+
 // setBootstrapTools returns the newest tools from the given tools list,
 // and updates the agent-version configuration attribute.
 func setBootstrapTools(environ environs.Environ, possibleTools coretools.List) (*coretools.Tools, error) {
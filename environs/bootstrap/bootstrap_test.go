@@ -5,6 +5,9 @@ package bootstrap_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
 	stdtesting "testing"
 
@@ -284,6 +287,63 @@ func (s *bootstrapSuite) TestBootstrapMetadataImagesMissing(c *gc.C) {
 	c.Assert(datasources[0].Description(), gc.Equals, "default cloud images")
 }
 
+// createOfflineArtifacts creates a directory holding fake tools, in
+// the layout read by the offline package, for each of the given series.
+func createOfflineArtifacts(c *gc.C, series ...string) string {
+	dir := c.MkDir()
+	toolsDir := filepath.Join(dir, "tools", "released")
+	err := os.MkdirAll(toolsDir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, s := range series {
+		vers := version.Current
+		vers.Series = s
+		path := filepath.Join(toolsDir, fmt.Sprintf("juju-%v.tgz", vers))
+		err := ioutil.WriteFile(path, []byte("fake tools "+vers.String()), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return dir
+}
+
+func (s *bootstrapSuite) TestBootstrapOffline(c *gc.C) {
+	env := newEnviron("foo", useDefaultKeys, nil)
+	s.setDummyStorage(c, env)
+	metadataDir := createOfflineArtifacts(c, version.Current.Series, config.PreferredSeries(env.Config()))
+
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		MetadataDir: metadataDir,
+		Offline:     true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.bootstrapCount, gc.Equals, 1)
+	c.Assert(env.Config().OfflineMode(), jc.IsTrue)
+	c.Assert(env.machineConfig, gc.NotNil)
+	c.Assert(env.machineConfig.Tools.Version, gc.Equals, version.Current)
+	expectedPath := filepath.Join(metadataDir, "tools", "released", fmt.Sprintf("juju-%v.tgz", version.Current))
+	c.Assert(env.machineConfig.Tools.URL, gc.Equals, "file://"+filepath.ToSlash(expectedPath))
+}
+
+func (s *bootstrapSuite) TestBootstrapOfflineRequiresMetadataDir(c *gc.C) {
+	env := newEnviron("foo", useDefaultKeys, nil)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		Offline: true,
+	})
+	c.Assert(err, gc.ErrorMatches, "offline bootstrap requires a metadata source")
+	c.Assert(env.bootstrapCount, gc.Equals, 0)
+}
+
+func (s *bootstrapSuite) TestBootstrapOfflineMissingTools(c *gc.C) {
+	env := newEnviron("foo", useDefaultKeys, nil)
+	metadataDir := createOfflineArtifacts(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		MetadataDir: metadataDir,
+		Offline:     true,
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		"no %v tools for %s found in .*", version.Current.Number, config.PreferredSeries(env.Config()),
+	))
+	c.Assert(env.bootstrapCount, gc.Equals, 0)
+}
+
 type bootstrapEnviron struct {
 	cfg              *config.Config
 	environs.Environ // stub out all methods we don't care about.
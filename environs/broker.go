@@ -4,6 +4,7 @@
 package environs
 
 import (
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/instance"
@@ -49,6 +50,11 @@ type StartInstanceParams struct {
 	// NetworkInfo is an optional list of network interface details,
 	// necessary to configure on the instance.
 	NetworkInfo []network.InterfaceInfo
+
+	// StatusCallback, if non-nil, is called by the InstanceBroker to
+	// report progress made in starting the instance, such as the
+	// image chosen for it.
+	StatusCallback func(status params.Status, info string, data map[string]interface{})
 }
 
 // StartInstanceResult holds the result of an
@@ -0,0 +1,137 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider/registry"
+)
+
+// ContainerCapability is implemented by environments whose machines
+// cannot host every kind of container.
+type ContainerCapability interface {
+	// SupportedContainerTypes returns the kinds of container that may
+	// be created on the environment's machines.
+	SupportedContainerTypes() ([]instance.ContainerType, error)
+}
+
+// FirewallCapability is implemented by environments which do not
+// support every firewall mode.
+type FirewallCapability interface {
+	// SupportedFirewallModes returns the firewall modes the
+	// environment supports.
+	SupportedFirewallModes() []string
+}
+
+// Capabilities describes the features of an environment, so that
+// operations it cannot carry out may be rejected before they are
+// attempted.
+type Capabilities struct {
+	// Architectures holds the architectures of the images the
+	// environment can host.
+	Architectures []string
+
+	// UnitPlacement reports whether machines may be created without
+	// units, and units placed explicitly. If not, UnitPlacementError
+	// explains why.
+	UnitPlacement      bool
+	UnitPlacementError string
+
+	// Containers holds the kinds of container that may be created on
+	// the environment's machines.
+	Containers []instance.ContainerType
+
+	// Networking reports whether the environment supports querying
+	// subnets and network interfaces.
+	Networking bool
+
+	// AddressAllocation reports whether static addresses may be
+	// allocated, for example to containers.
+	AddressAllocation bool
+
+	// StorageProviders holds the types of storage provider which may
+	// be used in the environment, and StorageKinds the kinds of
+	// storage they can provide between them.
+	StorageProviders []storage.ProviderType
+	StorageKinds     []storage.StorageKind
+
+	// FirewallMode is the firewall mode the environment was
+	// configured with, and FirewallModes those it supports.
+	FirewallMode  string
+	FirewallModes []string
+}
+
+// EnvironCapabilities returns the capabilities of the given environment.
+func EnvironCapabilities(env Environ) (Capabilities, error) {
+	var caps Capabilities
+	arches, err := env.SupportedArchitectures()
+	if err != nil {
+		return caps, errors.Annotate(err, "cannot get supported architectures")
+	}
+	caps.Architectures = arches
+
+	if err := env.SupportsUnitPlacement(); err != nil {
+		caps.UnitPlacementError = err.Error()
+	} else {
+		caps.UnitPlacement = true
+	}
+
+	caps.Containers = instance.ContainerTypes
+	if containers, ok := env.(ContainerCapability); ok {
+		if caps.Containers, err = containers.SupportedContainerTypes(); err != nil {
+			return caps, errors.Annotate(err, "cannot get supported container types")
+		}
+	}
+
+	if netEnv, ok := SupportsNetworking(env); ok {
+		caps.Networking = true
+		supported, err := netEnv.SupportsAddressAllocation(network.AnySubnet)
+		if err != nil && !errors.IsNotSupported(err) {
+			return caps, errors.Annotate(err, "cannot check address allocation support")
+		}
+		caps.AddressAllocation = supported && err == nil
+	}
+
+	cfg := env.Config()
+	caps.StorageProviders = registry.EnvironStorageProviders(cfg.Type())
+	sort.Sort(storageProviderTypes(caps.StorageProviders))
+	var providers []storage.Provider
+	for _, providerType := range caps.StorageProviders {
+		p, err := registry.StorageProvider(providerType)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return caps, errors.Trace(err)
+		}
+		providers = append(providers, p)
+	}
+	for _, kind := range []storage.StorageKind{storage.StorageKindBlock, storage.StorageKindFilesystem} {
+		for _, p := range providers {
+			if p.Supports(kind) {
+				caps.StorageKinds = append(caps.StorageKinds, kind)
+				break
+			}
+		}
+	}
+
+	caps.FirewallMode = cfg.FirewallMode()
+	caps.FirewallModes = []string{config.FwInstance, config.FwGlobal, config.FwNone}
+	if firewall, ok := env.(FirewallCapability); ok {
+		caps.FirewallModes = firewall.SupportedFirewallModes()
+	}
+	return caps, nil
+}
+
+type storageProviderTypes []storage.ProviderType
+
+func (p storageProviderTypes) Len() int           { return len(p) }
+func (p storageProviderTypes) Less(i, j int) bool { return p[i] < p[j] }
+func (p storageProviderTypes) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
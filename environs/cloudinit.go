@@ -160,6 +160,7 @@ func FinishMachineConfig(mcfg *cloudinit.MachineConfig, cfg *config.Config) (err
 	); err != nil {
 		return errors.Trace(err)
 	}
+	mcfg.Offline = cfg.OfflineMode()
 
 	if isStateMachineConfig(mcfg) {
 		// Add NUMACTL preference. Needed to work for both bootstrap and high availability
@@ -167,6 +168,18 @@ func FinishMachineConfig(mcfg *cloudinit.MachineConfig, cfg *config.Config) (err
 		logger.Debugf("Setting numa ctl preference to %v", cfg.NumaCtlPreference())
 		// Unfortunately, AgentEnvironment can only take strings as values
 		mcfg.AgentEnvironment[agent.NumaCtlPreference] = fmt.Sprintf("%v", cfg.NumaCtlPreference())
+		// The storage engine and oplog size cannot be changed once
+		// the database has been created, so every state server is
+		// configured with those the environment was bootstrapped with.
+		if engine := cfg.MongoStorageEngine(); engine != "" {
+			mcfg.AgentEnvironment[agent.MongoStorageEngine] = engine
+		}
+		if size := cfg.MongoOplogSize(); size > 0 {
+			mcfg.AgentEnvironment[agent.MongoOplogSize] = fmt.Sprint(size)
+		}
+		if size := cfg.MongoCacheSize(); size > 0 {
+			mcfg.AgentEnvironment[agent.MongoCacheSize] = fmt.Sprint(size)
+		}
 	}
 	// The following settings are only appropriate at bootstrap time. At the
 	// moment, the only state server is the bootstrap node, but this
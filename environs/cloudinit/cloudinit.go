@@ -173,6 +173,12 @@ type MachineConfig struct {
 	// machines. If enabled, the OS will perform any upgrades
 	// available as part of its provisioning.
 	EnableOSUpgrade bool
+
+	// Offline mirrors the value of the offline-mode environment setting.
+	// When set, the machine cannot be expected to reach the internet, so
+	// tools are only ever fetched from the state servers, or supplied
+	// with the cloud-init data when bootstrapping.
+	Offline bool
 }
 
 func base64yaml(m *config.Config) string {
@@ -534,6 +540,9 @@ func verifyConfig(cfg *MachineConfig) (err error) {
 		if cfg.InstanceId == "" {
 			return errors.New("missing instance-id")
 		}
+		if cfg.Offline && !strings.HasPrefix(cfg.Tools.URL, fileSchemePrefix) {
+			return errors.New("offline bootstrap requires local tools")
+		}
 	} else {
 		if len(cfg.MongoInfo.Addrs) == 0 {
 			return errors.New("missing state hosts")
@@ -957,6 +957,9 @@ var verifyTests = []struct {
 	{"missing instance-id", func(cfg *cloudinit.MachineConfig) {
 		cfg.InstanceId = ""
 	}},
+	{"offline bootstrap requires local tools", func(cfg *cloudinit.MachineConfig) {
+		cfg.Offline = true
+	}},
 	{"state serving info unexpectedly present", func(cfg *cloudinit.MachineConfig) {
 		cfg.Bootstrap = false
 		apiInfo := *cfg.APIInfo
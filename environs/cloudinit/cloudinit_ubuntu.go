@@ -229,8 +229,9 @@ func (w *ubuntuConfigure) ConfigureJuju() error {
 
 	// Add the cloud archive cloud-tools pocket to apt sources
 	// for series that need it. This gives us up-to-date LXC,
-	// MongoDB, and other infrastructure.
-	if w.conf.AptUpdate() {
+	// MongoDB, and other infrastructure. The archive cannot be
+	// reached by offline machines.
+	if w.conf.AptUpdate() && !w.mcfg.Offline {
 		MaybeAddCloudArchiveCloudTools(w.conf, w.mcfg.Tools.Version.Series)
 	}
 
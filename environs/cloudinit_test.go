@@ -176,6 +176,30 @@ func (s *CloudInitSuite) TestFinishBootstrapConfig(c *gc.C) {
 	c.Assert(err, gc.NotNil)
 }
 
+func (s *CloudInitSuite) TestFinishBootstrapConfigMongoSettings(c *gc.C) {
+	attrs := dummySampleConfig().Merge(testing.Attrs{
+		"authorized-keys":      "we-are-the-keys",
+		"admin-secret":         "lisboan-pork",
+		"agent-version":        "1.2.3",
+		"state-server":         false,
+		"mongo-storage-engine": "wiredTiger",
+		"mongo-oplog-size":     2048,
+		"mongo-cache-size":     4,
+	})
+	cfg, err := config.New(config.NoDefaults, attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	mcfg := &cloudinit.MachineConfig{
+		Bootstrap:        true,
+		AgentEnvironment: map[string]string{},
+		Jobs:             []multiwatcher.MachineJob{multiwatcher.JobManageEnviron},
+	}
+	err = environs.FinishMachineConfig(mcfg, cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(mcfg.AgentEnvironment[agent.MongoStorageEngine], gc.Equals, "wiredTiger")
+	c.Check(mcfg.AgentEnvironment[agent.MongoOplogSize], gc.Equals, "2048")
+	c.Check(mcfg.AgentEnvironment[agent.MongoCacheSize], gc.Equals, "4")
+}
+
 func (s *CloudInitSuite) TestUserData(c *gc.C) {
 	s.testUserData(c, false)
 }
@@ -6,6 +6,7 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -22,6 +23,8 @@ import (
 
 	"github.com/juju/juju/cert"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/utils/cron"
 	"github.com/juju/juju/version"
 )
 
@@ -42,6 +45,29 @@ const (
 	// instance security groups.
 	FwNone = "none"
 
+	// EgressOpen allows machines to connect to any destination.
+	EgressOpen = "open"
+
+	// EgressRestricted allows machines hosting units to connect only
+	// to the state servers and the destinations in egress-allowed.
+	EgressRestricted = "restricted"
+
+	// DNSBackendNsupdate registers machine addresses with a DNS
+	// server accepting RFC 2136 dynamic updates, using nsupdate.
+	DNSBackendNsupdate = "nsupdate"
+
+	// DNSBackendDesignate registers machine addresses with the DNS
+	// service of an OpenStack cloud.
+	DNSBackendDesignate = "designate"
+
+	// DefaultDNSTTL is the default time to live, in seconds, of the
+	// DNS records of machine addresses.
+	DefaultDNSTTL = 300
+
+	// DefaultHookOutputLimit is the default maximum number of bytes
+	// of each hook's output kept with its result.
+	DefaultHookOutputLimit = 16 * 1024
+
 	// DefaultStatePort is the default port the state server is listening on.
 	DefaultStatePort int = 37017
 
@@ -88,6 +114,14 @@ const (
 	// Only prevent all-changes from running
 	// if user specifically requests it. Otherwise, let them run.
 	DefaultPreventAllChanges = false
+
+	// DefaultCharmRetention is how long a charm which is not used by
+	// any service or unit is kept in storage before it is removed.
+	DefaultCharmRetention = 24 * time.Hour
+
+	// DefaultMaintenanceWindowDuration is how long the environment's
+	// maintenance window stays open each time it opens.
+	DefaultMaintenanceWindowDuration = time.Hour
 )
 
 // TODO(katco-): Please grow this over time.
@@ -128,12 +162,43 @@ const (
 	// NoProxyKey stores the key for this setting.
 	NoProxyKey = "no-proxy"
 
+	// SnapHttpProxyKey stores the key for this setting.
+	SnapHttpProxyKey = "snap-http-proxy"
+
+	// SnapHttpsProxyKey stores the key for this setting.
+	SnapHttpsProxyKey = "snap-https-proxy"
+
 	// LxcClone stores the value for this setting.
 	LxcClone = "lxc-clone"
 
 	// NumaControlPolicyKey stores the value for this setting
 	SetNumaControlPolicyKey = "set-numa-control-policy"
 
+	// MongoStorageEngineKey stores the key for this setting.
+	MongoStorageEngineKey = "mongo-storage-engine"
+
+	// MongoOplogSizeKey stores the key for this setting.
+	MongoOplogSizeKey = "mongo-oplog-size"
+
+	// MongoCacheSizeKey stores the key for this setting.
+	MongoCacheSizeKey = "mongo-cache-size"
+
+	// CharmStorageQuotaKey stores the key for the maximum size, in
+	// megabytes, of the charm archives stored for the environment.
+	CharmStorageQuotaKey = "charm-storage-quota"
+
+	// CharmRetentionKey stores the key for how long charms which are
+	// no longer used are kept in storage.
+	CharmRetentionKey = "charm-retention"
+
+	// MaintenanceWindowKey stores the key for the cron expression
+	// giving when the environment's maintenance window opens.
+	MaintenanceWindowKey = "maintenance-window"
+
+	// MaintenanceWindowDurationKey stores the key for how long the
+	// environment's maintenance window stays open.
+	MaintenanceWindowDurationKey = "maintenance-window-duration"
+
 	// BlockKeyPrefix is the prefix used for environment variables that block commands
 	// TODO(anastasiamac 2015-02-27) remove it and all related post 1.24 as obsolete
 	BlockKeyPrefix = "block-"
@@ -150,6 +215,68 @@ const (
 	// The default block storage source.
 	StorageDefaultBlockSourceKey = "storage-default-block-source"
 
+	// EgressModeKey stores the key for the egress mode setting, which
+	// is one of EgressOpen or EgressRestricted.
+	EgressModeKey = "egress-mode"
+
+	// EgressAllowedKey stores the key for the comma-separated list of
+	// destinations, in the form accepted by network.ParseEgressRules,
+	// that units may connect to when the egress mode is EgressRestricted.
+	EgressAllowedKey = "egress-allowed"
+
+	// IngressAllowedKey stores the key for the comma-separated list of
+	// address ranges, in CIDR notation, from which services exposed
+	// without address ranges of their own may be reached.
+	IngressAllowedKey = "ingress-allowed"
+
+	// ConcurrentHooksKey stores the key for the setting that allows
+	// hooks for different units on the same machine to run at the
+	// same time.
+	ConcurrentHooksKey = "concurrent-hooks"
+
+	// HookOutputLimitKey stores the key for the maximum number of
+	// bytes of each hook's output kept with its result. Only the
+	// tail of any longer output is kept.
+	HookOutputLimitKey = "hook-output-limit"
+
+	// DNSBackendKey stores the key for the DNS service, one of
+	// DNSBackendNsupdate or DNSBackendDesignate, in which the host
+	// names and addresses of machines are registered. No records are
+	// registered if it is not set.
+	DNSBackendKey = "dns-backend"
+
+	// DNSZoneKey stores the key for the DNS zone in which the
+	// records of machine addresses are registered.
+	DNSZoneKey = "dns-zone"
+
+	// DNSServerKey stores the key for the address, with an optional
+	// port, of the DNS server sent dynamic updates by the nsupdate
+	// backend.
+	DNSServerKey = "dns-server"
+
+	// DNSTSIGKeyKey stores the key for the TSIG key, in the form
+	// [<algorithm>:]<name>:<secret>, used to sign dynamic updates.
+	DNSTSIGKeyKey = "dns-tsig-key"
+
+	// DNSTTLKey stores the key for the time to live, in seconds, of
+	// the records of machine addresses.
+	DNSTTLKey = "dns-ttl"
+
+	// MeterStatusAlertURLKey stores the key for the URL of the
+	// webhook to which alerts are posted when the meter status of a
+	// unit turns RED.
+	MeterStatusAlertURLKey = "meter-status-alert-url"
+
+	// MetricsEndpointKey stores the key for whether the API servers
+	// of a state server environment serve their metrics at
+	// /metrics.
+	MetricsEndpointKey = "metrics-endpoint"
+
+	// FeaturesKey stores the key for the comma-separated list of
+	// feature flags enabled for the environment. Some API facades
+	// are only available in environments with their feature enabled.
+	FeaturesKey = "features"
+
 	//
 	// Deprecated Settings Attributes
 	//
@@ -220,6 +347,8 @@ var ProxyAttributes = []string{
 	AptHttpProxyKey,
 	AptHttpsProxyKey,
 	AptFtpProxyKey,
+	SnapHttpProxyKey,
+	SnapHttpsProxyKey,
 }
 
 // String returns the description of the harvesting mode.
@@ -497,6 +626,13 @@ func ProcessDeprecatedAttributes(attrs map[string]interface{}) map[string]interf
 	return processedAttrs
 }
 
+// These are the storage engines which may be chosen for the state
+// servers' mongo databases.
+const (
+	MongoEngineMMAPv1     = "mmapv1"
+	MongoEngineWiredTiger = "wiredTiger"
+)
+
 // InvalidConfigValue is an error type for a config value that failed validation.
 type InvalidConfigValueError struct {
 	// Key is the config key used to access the value.
@@ -570,6 +706,19 @@ func Validate(cfg, old *Config) error {
 		return fmt.Errorf("invalid firewall mode in environment configuration: %q", mode)
 	}
 
+	// Check egress mode and rules.
+	switch mode := cfg.EgressMode(); mode {
+	case EgressOpen, EgressRestricted:
+	default:
+		return fmt.Errorf("invalid egress mode in environment configuration: %q", mode)
+	}
+	if _, err := cfg.EgressRules(); err != nil {
+		return errors.Annotate(err, "invalid egress-allowed in environment configuration")
+	}
+	if _, err := cfg.IngressCIDRs(); err != nil {
+		return errors.Annotate(err, "invalid ingress-allowed in environment configuration")
+	}
+
 	caCert, caCertOK := cfg.CACert()
 	caKey, caKeyOK := cfg.CAPrivateKey()
 	if caCertOK || caKeyOK {
@@ -593,6 +742,44 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	if err := validateMongoSettings(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := validateCharmStorageSettings(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := validateMaintenanceWindow(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := validateDNSSettings(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if limit := cfg.HookOutputLimit(); limit <= 0 {
+		return &InvalidConfigValueError{
+			Key:    HookOutputLimitKey,
+			Value:  fmt.Sprint(limit),
+			Reason: errors.New("must be positive"),
+		}
+	}
+
+	if v := cfg.MeterStatusAlertURL(); v != "" {
+		u, err := url.Parse(v)
+		if err == nil && u.Scheme != "http" && u.Scheme != "https" {
+			err = errors.New("expected an http or https URL")
+		}
+		if err != nil {
+			return &InvalidConfigValueError{
+				Key:    MeterStatusAlertURLKey,
+				Value:  v,
+				Reason: err,
+			}
+		}
+	}
+
 	// Check the immutable config values.  These can't change
 	if old != nil {
 		for _, attr := range immutableAttributes {
@@ -606,12 +793,11 @@ func Validate(cfg, old *Config) error {
 				oldv, oldexists := old.defined[attr]
 				newv := cfg.defined[attr]
 				if oldexists && oldv != newv {
-					newv := cfg.defined[attr]
-					return fmt.Errorf("cannot change %s from %#v to %#v", attr, oldv, newv)
+					return &ImmutableConfigValueError{Key: attr, Old: oldv, New: newv}
 				}
 			default:
 				if newv, oldv := cfg.defined[attr], old.defined[attr]; newv != oldv {
-					return fmt.Errorf("cannot change %s from %#v to %#v", attr, oldv, newv)
+					return &ImmutableConfigValueError{Key: attr, Old: oldv, New: newv}
 				}
 			}
 		}
@@ -626,6 +812,129 @@ func Validate(cfg, old *Config) error {
 	return nil
 }
 
+// validateMongoSettings checks the settings which tune the state
+// servers' mongo databases.
+func validateMongoSettings(cfg *Config) error {
+	engine := cfg.MongoStorageEngine()
+	switch engine {
+	case "", MongoEngineMMAPv1, MongoEngineWiredTiger:
+	default:
+		return &InvalidConfigValueError{
+			Key:    MongoStorageEngineKey,
+			Value:  engine,
+			Reason: errors.Errorf("expected %q or %q", MongoEngineMMAPv1, MongoEngineWiredTiger),
+		}
+	}
+	if size := cfg.MongoOplogSize(); size < 0 {
+		return &InvalidConfigValueError{
+			Key:    MongoOplogSizeKey,
+			Value:  fmt.Sprint(size),
+			Reason: errors.New("size must be positive"),
+		}
+	}
+	if size := cfg.MongoCacheSize(); size < 0 {
+		return &InvalidConfigValueError{
+			Key:    MongoCacheSizeKey,
+			Value:  fmt.Sprint(size),
+			Reason: errors.New("size must be positive"),
+		}
+	} else if size > 0 && engine != MongoEngineWiredTiger {
+		return &InvalidConfigValueError{
+			Key:    MongoCacheSizeKey,
+			Value:  fmt.Sprint(size),
+			Reason: errors.Errorf("only supported by the %s storage engine", MongoEngineWiredTiger),
+		}
+	}
+	return nil
+}
+
+// validateCharmStorageSettings checks the settings which limit the
+// storage used by the environment's charms.
+func validateCharmStorageSettings(cfg *Config) error {
+	if quota := cfg.CharmStorageQuota(); quota < 0 {
+		return &InvalidConfigValueError{
+			Key:    CharmStorageQuotaKey,
+			Value:  fmt.Sprint(quota),
+			Reason: errors.New("quota must be positive"),
+		}
+	}
+	if _, err := cfg.CharmRetention(); err != nil {
+		return &InvalidConfigValueError{
+			Key:    CharmRetentionKey,
+			Value:  cfg.asString(CharmRetentionKey),
+			Reason: err,
+		}
+	}
+	return nil
+}
+
+// validateMaintenanceWindow checks the settings which define the
+// environment's maintenance window.
+func validateMaintenanceWindow(cfg *Config) error {
+	if _, err := cfg.MaintenanceWindow(); err != nil {
+		return &InvalidConfigValueError{
+			Key:    MaintenanceWindowKey,
+			Value:  cfg.asString(MaintenanceWindowKey),
+			Reason: err,
+		}
+	}
+	if _, err := cfg.MaintenanceWindowDuration(); err != nil {
+		return &InvalidConfigValueError{
+			Key:    MaintenanceWindowDurationKey,
+			Value:  cfg.asString(MaintenanceWindowDurationKey),
+			Reason: err,
+		}
+	}
+	return nil
+}
+
+// validateDNSSettings checks the settings which define where the
+// addresses of machines are registered.
+func validateDNSSettings(cfg *Config) error {
+	backend := cfg.DNSBackend()
+	switch backend {
+	case "":
+		return nil
+	case DNSBackendNsupdate:
+		if cfg.DNSServer() == "" {
+			return &InvalidConfigValueError{
+				Key:    DNSServerKey,
+				Reason: errors.Errorf("must be set for the %s backend", DNSBackendNsupdate),
+			}
+		}
+	case DNSBackendDesignate:
+	default:
+		return &InvalidConfigValueError{
+			Key:    DNSBackendKey,
+			Value:  backend,
+			Reason: errors.Errorf("expected %q or %q", DNSBackendNsupdate, DNSBackendDesignate),
+		}
+	}
+	if cfg.DNSZone() == "" {
+		return &InvalidConfigValueError{
+			Key:    DNSZoneKey,
+			Reason: errors.New("must be set when dns-backend is set"),
+		}
+	}
+	if key := cfg.DNSTSIGKey(); key != "" {
+		parts := strings.Split(key, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+			return &InvalidConfigValueError{
+				Key:    DNSTSIGKeyKey,
+				Reason: errors.New("expected [<algorithm>:]<name>:<secret>"),
+			}
+		}
+	}
+	if ttl := cfg.DNSTTL(); ttl <= 0 {
+		return &InvalidConfigValueError{
+			Key:    DNSTTLKey,
+			Value:  fmt.Sprint(ttl),
+			Reason: errors.New("must be positive"),
+		}
+	}
+	return nil
+}
+
 func isEmpty(val interface{}) bool {
 	switch val := val.(type) {
 	case nil:
@@ -775,6 +1084,83 @@ func (c *Config) NumaCtlPreference() bool {
 	return DefaultNumaControlPolicy
 }
 
+// MongoStorageEngine returns the storage engine used by the state
+// servers' mongo databases, or "" if mongod's default is used.
+func (c *Config) MongoStorageEngine() string {
+	engine, _ := c.defined[MongoStorageEngineKey].(string)
+	return engine
+}
+
+// MongoOplogSize returns the size of the state servers' mongo oplog in
+// megabytes, or 0 if it is sized according to the disk space available.
+func (c *Config) MongoOplogSize() int {
+	size, _ := c.defined[MongoOplogSizeKey].(int)
+	return size
+}
+
+// MongoCacheSize returns the size of the WiredTiger cache of the state
+// servers' mongo databases in gigabytes, or 0 if mongod's default is used.
+func (c *Config) MongoCacheSize() int {
+	size, _ := c.defined[MongoCacheSizeKey].(int)
+	return size
+}
+
+// CharmStorageQuota returns the maximum size, in megabytes, of the
+// charm archives stored for the environment, or 0 if it is unlimited.
+func (c *Config) CharmStorageQuota() int {
+	quota, _ := c.defined[CharmStorageQuotaKey].(int)
+	return quota
+}
+
+// CharmRetention returns how long a charm which is not used by any
+// service or unit is kept in storage before it is removed.
+func (c *Config) CharmRetention() (time.Duration, error) {
+	value := c.asString(CharmRetentionKey)
+	if value == "" {
+		return DefaultCharmRetention, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if retention < 0 {
+		return 0, errors.New("retention must be positive")
+	}
+	return retention, nil
+}
+
+// MaintenanceWindow returns the schedule on which the environment's
+// maintenance window opens, or nil if no window is set, in which case
+// disruptive automated operations may run at any time.
+func (c *Config) MaintenanceWindow() (*cron.Schedule, error) {
+	value := c.asString(MaintenanceWindowKey)
+	if value == "" {
+		return nil, nil
+	}
+	schedule, err := cron.Parse(value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return schedule, nil
+}
+
+// MaintenanceWindowDuration returns how long the environment's
+// maintenance window stays open each time it opens.
+func (c *Config) MaintenanceWindowDuration() (time.Duration, error) {
+	value := c.asString(MaintenanceWindowDurationKey)
+	if value == "" {
+		return DefaultMaintenanceWindowDuration, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if duration <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return duration, nil
+}
+
 // PreventDestroyEnvironment returns if destroy-environment
 // should be blocked from proceeding, thus preventing the operation.
 func (c *Config) PreventDestroyEnvironment() bool {
@@ -911,6 +1297,27 @@ func (c *Config) AptFtpProxy() string {
 	return addSchemeIfMissing("ftp", c.getWithFallback(AptFtpProxyKey, FtpProxyKey))
 }
 
+// SnapProxySettings returns the proxy settings used by snapd, the
+// http and https proxies.
+func (c *Config) SnapProxySettings() proxy.Settings {
+	return proxy.Settings{
+		Http:  c.SnapHttpProxy(),
+		Https: c.SnapHttpsProxy(),
+	}
+}
+
+// SnapHttpProxy returns the snap http proxy for the environment.
+// Falls back to the default http-proxy if not specified.
+func (c *Config) SnapHttpProxy() string {
+	return addSchemeIfMissing("http", c.getWithFallback(SnapHttpProxyKey, HttpProxyKey))
+}
+
+// SnapHttpsProxy returns the snap https proxy for the environment.
+// Falls back to the default https-proxy if not specified.
+func (c *Config) SnapHttpsProxy() string {
+	return addSchemeIfMissing("https", c.getWithFallback(SnapHttpsProxyKey, HttpsProxyKey))
+}
+
 // AptMirror sets the apt mirror for the environment.
 func (c *Config) AptMirror() string {
 	return c.asString("apt-mirror")
@@ -1110,6 +1517,14 @@ func (c *Config) LXCUseCloneAUFS() (bool, bool) {
 	return v, ok
 }
 
+// OfflineMode reports whether the environment has no access to the
+// internet, in which case tools and images are only ever served from
+// the copies held by the state servers.
+func (c *Config) OfflineMode() bool {
+	v, _ := c.defined["offline-mode"].(bool)
+	return v
+}
+
 // DisableNetworkManagement reports whether Juju is allowed to
 // configure and manage networking inside the environment.
 func (c *Config) DisableNetworkManagement() (bool, bool) {
@@ -1124,6 +1539,114 @@ func (c *Config) StorageDefaultBlockSource() (string, bool) {
 	return bs, bs != ""
 }
 
+// EgressMode returns whether machines hosting units may connect to
+// any destination (EgressOpen), or only to those allowed by
+// EgressRules (EgressRestricted).
+func (c *Config) EgressMode() string {
+	if mode := c.asString(EgressModeKey); mode != "" {
+		return mode
+	}
+	return EgressOpen
+}
+
+// ConcurrentHooks reports whether hooks for different units on the
+// same machine may run at the same time, unless their charms say
+// otherwise. Unit agents read the setting when they start.
+func (c *Config) ConcurrentHooks() bool {
+	v, _ := c.defined[ConcurrentHooksKey].(bool)
+	return v
+}
+
+// HookOutputLimit returns the maximum number of bytes of each hook's
+// output kept with its result.
+func (c *Config) HookOutputLimit() int {
+	if limit, ok := c.defined[HookOutputLimitKey].(int); ok {
+		return limit
+	}
+	return DefaultHookOutputLimit
+}
+
+// DNSBackend returns the DNS service in which the host names and
+// addresses of machines are registered, or "" if they are not.
+func (c *Config) DNSBackend() string {
+	return c.asString(DNSBackendKey)
+}
+
+// DNSZone returns the DNS zone in which the records of machine
+// addresses are registered.
+func (c *Config) DNSZone() string {
+	return c.asString(DNSZoneKey)
+}
+
+// DNSServer returns the address of the DNS server sent dynamic
+// updates by the nsupdate backend.
+func (c *Config) DNSServer() string {
+	return c.asString(DNSServerKey)
+}
+
+// DNSTSIGKey returns the TSIG key used to sign dynamic updates, or ""
+// if they are not signed.
+func (c *Config) DNSTSIGKey() string {
+	return c.asString(DNSTSIGKeyKey)
+}
+
+// DNSTTL returns the time to live, in seconds, of the records of
+// machine addresses.
+func (c *Config) DNSTTL() int {
+	if ttl, ok := c.defined[DNSTTLKey].(int); ok {
+		return ttl
+	}
+	return DefaultDNSTTL
+}
+
+// MeterStatusAlertURL returns the URL of the webhook to which alerts
+// are posted when the meter status of a unit turns RED, or "" if
+// alerts are not sent.
+func (c *Config) MeterStatusAlertURL() string {
+	return c.asString(MeterStatusAlertURLKey)
+}
+
+// MetricsEndpoint reports whether the API servers serve their
+// metrics, in the Prometheus text format, at /metrics. It is only
+// consulted in the state server environment.
+func (c *Config) MetricsEndpoint() bool {
+	v, _ := c.defined[MetricsEndpointKey].(bool)
+	return v
+}
+
+// Features returns the feature flags enabled for the environment.
+// Flags are lower-cased, as process-wide feature flags are.
+func (c *Config) Features() []string {
+	var features []string
+	for _, feature := range strings.Split(c.asString(FeaturesKey), ",") {
+		feature = strings.ToLower(strings.TrimSpace(feature))
+		if feature != "" {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// EgressRules returns the destinations that machines hosting units
+// may connect to when the egress mode is EgressRestricted.
+func (c *Config) EgressRules() ([]network.EgressRule, error) {
+	return network.ParseEgressRules(c.asString(EgressAllowedKey))
+}
+
+// IngressCIDRs returns the address ranges from which services exposed
+// without address ranges of their own may be reached, sorted and in
+// canonical form. If there are none, such services may be reached
+// from any address.
+func (c *Config) IngressCIDRs() ([]string, error) {
+	var cidrs []string
+	for _, cidr := range strings.Split(c.asString(IngressAllowedKey), ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return network.NormaliseCIDRs(cidrs)
+}
+
 // UnknownAttrs returns a copy of the raw configuration attributes
 // that are supposedly specific to the environment type. They could
 // also be wrong attributes, though. Only the specific environment
@@ -1163,65 +1686,9 @@ func (c *Config) Apply(attrs map[string]interface{}) (*Config, error) {
 	return New(NoDefaults, defined)
 }
 
-var fields = schema.Fields{
-	"type":                       schema.String(),
-	"name":                       schema.String(),
-	"uuid":                       schema.UUID(),
-	"default-series":             schema.String(),
-	AgentMetadataURLKey:          schema.String(),
-	"image-metadata-url":         schema.String(),
-	"image-stream":               schema.String(),
-	AgentStreamKey:               schema.String(),
-	"authorized-keys":            schema.String(),
-	"authorized-keys-path":       schema.String(),
-	"firewall-mode":              schema.String(),
-	"agent-version":              schema.String(),
-	"development":                schema.Bool(),
-	"admin-secret":               schema.String(),
-	"ca-cert":                    schema.String(),
-	"ca-cert-path":               schema.String(),
-	"ca-private-key":             schema.String(),
-	"ca-private-key-path":        schema.String(),
-	"ssl-hostname-verification":  schema.Bool(),
-	"state-port":                 schema.ForceInt(),
-	"api-port":                   schema.ForceInt(),
-	"syslog-port":                schema.ForceInt(),
-	"rsyslog-ca-cert":            schema.String(),
-	"rsyslog-ca-key":             schema.String(),
-	"logging-config":             schema.String(),
-	"charm-store-auth":           schema.String(),
-	ProvisionerHarvestModeKey:    schema.String(),
-	HttpProxyKey:                 schema.String(),
-	HttpsProxyKey:                schema.String(),
-	FtpProxyKey:                  schema.String(),
-	NoProxyKey:                   schema.String(),
-	AptHttpProxyKey:              schema.String(),
-	AptHttpsProxyKey:             schema.String(),
-	AptFtpProxyKey:               schema.String(),
-	"apt-mirror":                 schema.String(),
-	"bootstrap-timeout":          schema.ForceInt(),
-	"bootstrap-retry-delay":      schema.ForceInt(),
-	"bootstrap-addresses-delay":  schema.ForceInt(),
-	"test-mode":                  schema.Bool(),
-	"proxy-ssh":                  schema.Bool(),
-	LxcClone:                     schema.Bool(),
-	"lxc-clone-aufs":             schema.Bool(),
-	"prefer-ipv6":                schema.Bool(),
-	"enable-os-refresh-update":   schema.Bool(),
-	"enable-os-upgrade":          schema.Bool(),
-	"disable-network-management": schema.Bool(),
-	SetNumaControlPolicyKey:      schema.Bool(),
-	PreventDestroyEnvironmentKey: schema.Bool(),
-	PreventRemoveObjectKey:       schema.Bool(),
-	PreventAllChangesKey:         schema.Bool(),
-	StorageDefaultBlockSourceKey: schema.String(),
-
-	// Deprecated fields, retain for backwards compatibility.
-	ToolsMetadataURLKey:    schema.String(),
-	LxcUseClone:            schema.Bool(),
-	ProvisionerSafeModeKey: schema.Bool(),
-	ToolsStreamKey:         schema.String(),
-}
+// fields holds the checkers for the values of the attributes known to
+// juju. See configSchema.
+var fields = schemaFields()
 
 // alwaysOptional holds configuration defaults for attributes that may
 // be unspecified even after a configuration has been created with all
@@ -1252,16 +1719,50 @@ var alwaysOptional = schema.Defaults{
 	AptHttpProxyKey:              schema.Omit,
 	AptHttpsProxyKey:             schema.Omit,
 	AptFtpProxyKey:               schema.Omit,
+	SnapHttpProxyKey:             schema.Omit,
+	SnapHttpsProxyKey:            schema.Omit,
 	"apt-mirror":                 schema.Omit,
 	LxcClone:                     schema.Omit,
 	"disable-network-management": schema.Omit,
 	AgentStreamKey:               schema.Omit,
 	SetNumaControlPolicyKey:      DefaultNumaControlPolicy,
+	MongoStorageEngineKey:        schema.Omit,
+	MongoOplogSizeKey:            schema.Omit,
+	MongoCacheSizeKey:            schema.Omit,
+	CharmStorageQuotaKey:         schema.Omit,
+	CharmRetentionKey:            schema.Omit,
+	MaintenanceWindowKey:         schema.Omit,
+	MaintenanceWindowDurationKey: schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
 	StorageDefaultBlockSourceKey: schema.Omit,
 
+	// Egress and ingress related config.
+	EgressModeKey:     schema.Omit,
+	EgressAllowedKey:  schema.Omit,
+	IngressAllowedKey: schema.Omit,
+
+	// Hook execution related config.
+	ConcurrentHooksKey: schema.Omit,
+	HookOutputLimitKey: schema.Omit,
+
+	// DNS registration related config.
+	DNSBackendKey: schema.Omit,
+	DNSZoneKey:    schema.Omit,
+	DNSServerKey:  schema.Omit,
+	DNSTSIGKeyKey: schema.Omit,
+	DNSTTLKey:     schema.Omit,
+
+	// Meter status related config.
+	MeterStatusAlertURLKey: schema.Omit,
+
+	// API server metrics related config.
+	MetricsEndpointKey: schema.Omit,
+
+	// Environment feature flags.
+	FeaturesKey: schema.Omit,
+
 	// Deprecated fields, retain for backwards compatibility.
 	ToolsMetadataURLKey:          "",
 	LxcUseClone:                  schema.Omit,
@@ -1293,6 +1794,7 @@ var alwaysOptional = schema.Defaults{
 	// Previously image-stream could be set to an empty value
 	"image-stream":             "",
 	"test-mode":                false,
+	"offline-mode":             false,
 	"proxy-ssh":                false,
 	"lxc-clone-aufs":           false,
 	"prefer-ipv6":              false,
@@ -1355,21 +1857,7 @@ var mandatoryWithoutDefaults = []string{
 // immutableAttributes holds those attributes
 // which are not allowed to change in the lifetime
 // of an environment.
-var immutableAttributes = []string{
-	"name",
-	"type",
-	"uuid",
-	"firewall-mode",
-	"state-port",
-	"api-port",
-	"bootstrap-timeout",
-	"bootstrap-retry-delay",
-	"bootstrap-addresses-delay",
-	LxcClone,
-	"lxc-clone-aufs",
-	"syslog-port",
-	"prefer-ipv6",
-}
+var immutableAttributes = schemaImmutableAttributes()
 
 var (
 	withDefaultsChecker = schema.FieldMap(fields, defaults)
@@ -1481,3 +1969,12 @@ func AptProxyConfigMap(proxySettings proxy.Settings) map[string]interface{} {
 	addIfNotEmpty(settings, AptFtpProxyKey, proxySettings.Ftp)
 	return settings
 }
+
+// SnapProxyConfigMap returns a map suitable to be applied to a Config to
+// update snap proxy settings.
+func SnapProxyConfigMap(proxySettings proxy.Settings) map[string]interface{} {
+	settings := make(map[string]interface{})
+	addIfNotEmpty(settings, SnapHttpProxyKey, proxySettings.Http)
+	addIfNotEmpty(settings, SnapHttpsProxyKey, proxySettings.Https)
+	return settings
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/juju/juju/cert"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/juju/osenv"
+	"github.com/juju/juju/network"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/version"
 )
@@ -538,6 +539,50 @@ var configTests = []configTest{
 			"firewall-mode": "illegal",
 		},
 		err: "invalid firewall mode in environment configuration: .*",
+	}, {
+		about:       "Restricted egress mode",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":           "my-type",
+			"name":           "my-name",
+			"egress-mode":    "restricted",
+			"egress-allowed": "10.0.0.0/8,0.0.0.0/0:443",
+		},
+	}, {
+		about:       "Illegal egress mode",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"egress-mode": "illegal",
+		},
+		err: `invalid egress mode in environment configuration: "illegal"`,
+	}, {
+		about:       "Illegal egress rules",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":           "my-type",
+			"name":           "my-name",
+			"egress-allowed": "10.0.0.0/8:http",
+		},
+		err: `invalid egress-allowed in environment configuration: invalid egress rule "10.0.0.0/8:http": .*`,
+	}, {
+		about:       "Ingress address ranges",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"ingress-allowed": "10.0.0.0/8,192.168.0.0/16",
+		},
+	}, {
+		about:       "Illegal ingress address ranges",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"ingress-allowed": "10.0.0.0/8,bogus",
+		},
+		err: `invalid ingress-allowed in environment configuration: .*`,
 	}, {
 		about:       "ssl-hostname-verification off",
 		useDefaults: config.UseDefaults,
@@ -815,6 +860,238 @@ var configTests = []configTest{
 			"name":      "my-name",
 			"test-mode": true,
 		},
+	}, {
+		about:       "OfflineMode flag specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"offline-mode": true,
+		},
+	}, {
+		about:       "Invalid offline-mode flag",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"offline-mode": "invalid",
+		},
+		err: `offline-mode: expected bool, got string\("invalid"\)`,
+	}, {
+		about:       "ConcurrentHooks flag specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":             "my-type",
+			"name":             "my-name",
+			"concurrent-hooks": true,
+		},
+	}, {
+		about:       "Invalid concurrent-hooks flag",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":             "my-type",
+			"name":             "my-name",
+			"concurrent-hooks": "invalid",
+		},
+		err: `concurrent-hooks: expected bool, got string\("invalid"\)`,
+	}, {
+		about:       "DNS settings specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"dns-backend":  "nsupdate",
+			"dns-zone":     "example.com",
+			"dns-server":   "10.0.0.53",
+			"dns-tsig-key": "hmac-sha256:juju:c2VjcmV0",
+			"dns-ttl":      60,
+		},
+	}, {
+		about:       "Invalid dns-backend",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"dns-backend": "route53",
+			"dns-zone":    "example.com",
+		},
+		err: `invalid config value for dns-backend: "route53": expected "nsupdate" or "designate"`,
+	}, {
+		about:       "dns-backend without dns-zone",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"dns-backend": "designate",
+		},
+		err: `invalid config value for dns-zone: "": must be set when dns-backend is set`,
+	}, {
+		about:       "nsupdate dns-backend without dns-server",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"dns-backend": "nsupdate",
+			"dns-zone":    "example.com",
+		},
+		err: `invalid config value for dns-server: "": must be set for the nsupdate backend`,
+	}, {
+		about:       "hook-output-limit specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"hook-output-limit": 4096,
+		},
+	}, {
+		about:       "Invalid hook-output-limit",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"hook-output-limit": 0,
+		},
+		err: `invalid config value for hook-output-limit: "0": must be positive`,
+	}, {
+		about:       "Invalid dns-ttl",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"dns-backend": "designate",
+			"dns-zone":    "example.com",
+			"dns-ttl":     -1,
+		},
+		err: `invalid config value for dns-ttl: "-1": must be positive`,
+	}, {
+		about:       "Invalid dns-tsig-key",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"dns-backend":  "nsupdate",
+			"dns-zone":     "example.com",
+			"dns-server":   "10.0.0.53",
+			"dns-tsig-key": "c2VjcmV0",
+		},
+		err: `invalid config value for dns-tsig-key: "": expected \[<algorithm>:\]<name>:<secret>`,
+	}, {
+		about:       "Valid meter-status-alert-url",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"meter-status-alert-url": "https://alerts.example.com/juju",
+		},
+	}, {
+		about:       "Invalid meter-status-alert-url",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                   "my-type",
+			"name":                   "my-name",
+			"meter-status-alert-url": "ftp://alerts.example.com/juju",
+		},
+		err: `invalid config value for meter-status-alert-url: "ftp://alerts.example.com/juju": expected an http or https URL`,
+	}, {
+		about:       "Metrics endpoint enabled",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":             "my-type",
+			"name":             "my-name",
+			"metrics-endpoint": true,
+		},
+	}, {
+		about:       "Mongo settings specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"mongo-storage-engine": "wiredTiger",
+			"mongo-oplog-size":     2048,
+			"mongo-cache-size":     4,
+		},
+	}, {
+		about:       "Invalid mongo-storage-engine",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"mongo-storage-engine": "rocksdb",
+		},
+		err: `invalid config value for mongo-storage-engine: "rocksdb": expected "mmapv1" or "wiredTiger"`,
+	}, {
+		about:       "Negative mongo-oplog-size",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":             "my-type",
+			"name":             "my-name",
+			"mongo-oplog-size": -1,
+		},
+		err: `invalid config value for mongo-oplog-size: "-1": size must be positive`,
+	}, {
+		about:       "mongo-cache-size without WiredTiger",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                 "my-type",
+			"name":                 "my-name",
+			"mongo-storage-engine": "mmapv1",
+			"mongo-cache-size":     4,
+		},
+		err: `invalid config value for mongo-cache-size: "4": only supported by the wiredTiger storage engine`,
+	}, {
+		about:       "Charm storage settings specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"charm-storage-quota": 512,
+			"charm-retention":     "72h",
+		},
+	}, {
+		about:       "Negative charm-storage-quota",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"charm-storage-quota": -1,
+		},
+		err: `invalid config value for charm-storage-quota: "-1": quota must be positive`,
+	}, {
+		about:       "Invalid charm-retention",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"charm-retention": "forever",
+		},
+		err: `invalid config value for charm-retention: "forever": time: invalid duration "?forever"?`,
+	}, {
+		about:       "Maintenance window specified",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                        "my-type",
+			"name":                        "my-name",
+			"maintenance-window":          "0 2 * * sat",
+			"maintenance-window-duration": "3h",
+		},
+	}, {
+		about:       "Invalid maintenance-window",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"maintenance-window": "0 2 * *",
+		},
+		err: `invalid config value for maintenance-window: "0 2 \* \*": cron expression "0 2 \* \*" must have 5 fields, got 4`,
+	}, {
+		about:       "Invalid maintenance-window-duration",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                        "my-type",
+			"name":                        "my-name",
+			"maintenance-window-duration": "0s",
+		},
+		err: `invalid config value for maintenance-window-duration: "0s": duration must be positive`,
 	}, {
 		about:       "valid uuid",
 		useDefaults: config.UseDefaults,
@@ -1137,6 +1414,57 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	testmode, _ := test.attrs["test-mode"].(bool)
 	c.Assert(cfg.TestMode(), gc.Equals, testmode)
 
+	offline, _ := test.attrs["offline-mode"].(bool)
+	c.Assert(cfg.OfflineMode(), gc.Equals, offline)
+
+	concurrentHooks, _ := test.attrs["concurrent-hooks"].(bool)
+	c.Assert(cfg.ConcurrentHooks(), gc.Equals, concurrentHooks)
+
+	metricsEndpoint, _ := test.attrs["metrics-endpoint"].(bool)
+	c.Assert(cfg.MetricsEndpoint(), gc.Equals, metricsEndpoint)
+
+	if limit, ok := test.attrs["hook-output-limit"].(int); ok {
+		c.Assert(cfg.HookOutputLimit(), gc.Equals, limit)
+	} else {
+		c.Assert(cfg.HookOutputLimit(), gc.Equals, config.DefaultHookOutputLimit)
+	}
+
+	engine, _ := test.attrs["mongo-storage-engine"].(string)
+	c.Assert(cfg.MongoStorageEngine(), gc.Equals, engine)
+	oplogSize, _ := test.attrs["mongo-oplog-size"].(int)
+	c.Assert(cfg.MongoOplogSize(), gc.Equals, oplogSize)
+	cacheSize, _ := test.attrs["mongo-cache-size"].(int)
+	c.Assert(cfg.MongoCacheSize(), gc.Equals, cacheSize)
+
+	quota, _ := test.attrs["charm-storage-quota"].(int)
+	c.Assert(cfg.CharmStorageQuota(), gc.Equals, quota)
+	retention, err := cfg.CharmRetention()
+	c.Assert(err, jc.ErrorIsNil)
+	if value, ok := test.attrs["charm-retention"].(string); ok {
+		expect, err := time.ParseDuration(value)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(retention, gc.Equals, expect)
+	} else {
+		c.Assert(retention, gc.Equals, config.DefaultCharmRetention)
+	}
+
+	window, err := cfg.MaintenanceWindow()
+	c.Assert(err, jc.ErrorIsNil)
+	if value, ok := test.attrs["maintenance-window"].(string); ok {
+		c.Assert(window.String(), gc.Equals, value)
+	} else {
+		c.Assert(window, gc.IsNil)
+	}
+	duration, err := cfg.MaintenanceWindowDuration()
+	c.Assert(err, jc.ErrorIsNil)
+	if value, ok := test.attrs["maintenance-window-duration"].(string); ok {
+		expect, err := time.ParseDuration(value)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(duration, gc.Equals, expect)
+	} else {
+		c.Assert(duration, gc.Equals, config.DefaultMaintenanceWindowDuration)
+	}
+
 	series, _ := test.attrs["default-series"].(string)
 	if defaultSeries, ok := cfg.DefaultSeries(); ok {
 		c.Assert(defaultSeries, gc.Equals, series)
@@ -1151,6 +1479,11 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	if secret, _ := test.attrs["admin-secret"].(string); secret != "" {
 		c.Assert(cfg.AdminSecret(), gc.Equals, secret)
 	}
+	if m, _ := test.attrs["egress-mode"].(string); m != "" {
+		c.Assert(cfg.EgressMode(), gc.Equals, m)
+	} else {
+		c.Assert(cfg.EgressMode(), gc.Equals, config.EgressOpen)
+	}
 
 	if path, _ := test.attrs["authorized-keys-path"].(string); path != "" {
 		c.Assert(cfg.AuthorizedKeys(), gc.Equals, home.FileContents(c, path))
@@ -1344,6 +1677,7 @@ func (s *ConfigSuite) TestConfigAttrs(c *gc.C) {
 	attrs["proxy-ssh"] = false
 	attrs["lxc-clone-aufs"] = false
 	attrs["prefer-ipv6"] = false
+	attrs["offline-mode"] = false
 	attrs["set-numa-control-policy"] = false
 
 	// Default firewall mode is instance
@@ -1456,6 +1790,20 @@ var validationTests = []validationTest{{
 	old:   testing.Attrs{"prefer-ipv6": false},
 	new:   testing.Attrs{"prefer-ipv6": true},
 	err:   `cannot change prefer-ipv6 from false to true`,
+}, {
+	about: "Cannot change mongo-storage-engine",
+	old:   testing.Attrs{"mongo-storage-engine": "mmapv1"},
+	new:   testing.Attrs{"mongo-storage-engine": "wiredTiger"},
+	err:   `cannot change mongo-storage-engine from "mmapv1" to "wiredTiger"`,
+}, {
+	about: "Cannot change mongo-oplog-size",
+	old:   testing.Attrs{"mongo-oplog-size": 1024},
+	new:   testing.Attrs{"mongo-oplog-size": 2048},
+	err:   `cannot change mongo-oplog-size from 1024 to 2048`,
+}, {
+	about: "Can change mongo-cache-size",
+	old:   testing.Attrs{"mongo-storage-engine": "wiredTiger", "mongo-cache-size": 1},
+	new:   testing.Attrs{"mongo-storage-engine": "wiredTiger", "mongo-cache-size": 2},
 }, {
 	about: "Can change uuid from unset to set",
 	new:   testing.Attrs{"uuid": "dcfbdb4a-bca2-49ad-aa7c-f011424e0fe4"},
@@ -1481,6 +1829,8 @@ func (s *ConfigSuite) TestValidateChange(c *gc.C) {
 			c.Check(err, jc.ErrorIsNil)
 		} else {
 			c.Check(err, gc.ErrorMatches, test.err)
+			immutable := strings.HasPrefix(test.err, "cannot change ")
+			c.Check(config.IsImmutableConfigValueError(err), gc.Equals, immutable)
 		}
 	}
 }
@@ -1614,6 +1964,82 @@ func (s *ConfigSuite) TestLoggingConfigFromEnvironment(c *gc.C) {
 	c.Assert(config.LoggingConfig(), gc.Equals, "<root>=INFO;unit=DEBUG")
 }
 
+func (s *ConfigSuite) TestEgressRules(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"egress-mode":    "restricted",
+		"egress-allowed": "10.0.0.0/8, 0.0.0.0/0:443",
+	})
+	c.Assert(cfg.EgressMode(), gc.Equals, config.EgressRestricted)
+	rules, err := cfg.EgressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	https := network.MustParsePortRange("443/tcp")
+	c.Assert(rules, jc.DeepEquals, []network.EgressRule{
+		{DestinationCIDR: "10.0.0.0/8"},
+		{DestinationCIDR: "0.0.0.0/0", PortRange: &https},
+	})
+}
+
+func (s *ConfigSuite) TestIngressCIDRs(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"ingress-allowed": "192.168.0.0/16, 10.1.2.3/8,",
+	})
+	cidrs, err := cfg.IngressCIDRs()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cidrs, jc.DeepEquals, []string{"10.0.0.0/8", "192.168.0.0/16"})
+
+	cfg = newTestConfig(c, nil)
+	cidrs, err = cfg.IngressCIDRs()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cidrs, gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestFeatures(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"features": "Secrets, ,magic",
+	})
+	c.Assert(cfg.Features(), jc.DeepEquals, []string{"secrets", "magic"})
+}
+
+func (s *ConfigSuite) TestFeaturesNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.Features(), gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestEgressRulesNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.EgressMode(), gc.Equals, config.EgressOpen)
+	rules, err := cfg.EgressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, gc.HasLen, 0)
+}
+
+func (s *ConfigSuite) TestDNSSettings(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"dns-backend":  "nsupdate",
+		"dns-zone":     "example.com",
+		"dns-server":   "10.0.0.53:5353",
+		"dns-tsig-key": "juju:c2VjcmV0",
+	})
+	c.Assert(cfg.DNSBackend(), gc.Equals, config.DNSBackendNsupdate)
+	c.Assert(cfg.DNSZone(), gc.Equals, "example.com")
+	c.Assert(cfg.DNSServer(), gc.Equals, "10.0.0.53:5353")
+	c.Assert(cfg.DNSTSIGKey(), gc.Equals, "juju:c2VjcmV0")
+	c.Assert(cfg.DNSTTL(), gc.Equals, config.DefaultDNSTTL)
+}
+
+func (s *ConfigSuite) TestDNSSettingsNotSet(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, nil)
+	c.Assert(cfg.DNSBackend(), gc.Equals, "")
+	c.Assert(cfg.DNSZone(), gc.Equals, "")
+}
+
 func (s *ConfigSuite) TestProxyValuesWithFallback(c *gc.C) {
 	s.addJujuFiles(c)
 
@@ -1717,6 +2143,27 @@ func (s *ConfigSuite) TestAptProxyConfigMap(c *gc.C) {
 	c.Assert(cfg.AptProxySettings(), gc.DeepEquals, proxySettings)
 }
 
+func (s *ConfigSuite) TestSnapProxyConfigMap(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"http-proxy":  "http://user@10.0.0.1",
+		"https-proxy": "https://user@10.0.0.1",
+	})
+	// Without snap settings the default proxies are used.
+	c.Assert(cfg.SnapProxySettings(), gc.DeepEquals, proxy.Settings{
+		Http:  "http://user@10.0.0.1",
+		Https: "https://user@10.0.0.1",
+	})
+	proxySettings := proxy.Settings{
+		Http:  "http://snap.http.proxy",
+		Https: "https://snap.https.proxy",
+	}
+	cfg, err := cfg.Apply(config.SnapProxyConfigMap(proxySettings))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.SnapProxySettings(), gc.DeepEquals, proxySettings)
+	c.Assert(cfg.HttpProxy(), gc.Equals, "http://user@10.0.0.1")
+}
+
 func (s *ConfigSuite) TestGenerateStateServerCertAndKey(c *gc.C) {
 	// Add a cert.
 	s.FakeHomeSuite.Home.AddFiles(c, gitjujutesting.TestFile{".ssh/id_rsa.pub", "rsa\n"})
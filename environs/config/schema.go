@@ -0,0 +1,503 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+)
+
+// AttrType identifies the type of the value of a configuration
+// attribute.
+type AttrType string
+
+const (
+	StringType AttrType = "string"
+	BoolType   AttrType = "bool"
+	IntType    AttrType = "int"
+	UUIDType   AttrType = "uuid"
+)
+
+// AttrGroup identifies what a configuration attribute configures.
+type AttrGroup string
+
+const (
+	// EnvironGroup holds the attributes that configure an
+	// environment.
+	EnvironGroup AttrGroup = "environment"
+
+	// ControllerGroup holds the attributes that configure the state
+	// servers, and so are only meaningful in the state server
+	// environment.
+	ControllerGroup AttrGroup = "controller"
+)
+
+// Attr describes a configuration attribute known to juju.
+type Attr struct {
+	// Type holds the type of the attribute's value.
+	Type AttrType
+
+	// Group holds what the attribute configures.
+	Group AttrGroup
+
+	// Immutable holds whether the attribute cannot be changed once
+	// the environment has been created.
+	Immutable bool
+
+	// Deprecated holds whether the attribute has been replaced by
+	// another, and is only retained for backward compatibility.
+	Deprecated bool
+
+	// Default holds the value the attribute takes when it is not
+	// set, or nil if it has none.
+	Default interface{}
+
+	// Description describes the attribute.
+	Description string
+}
+
+// configSchema describes every configuration attribute known to juju,
+// apart from those defined by the environment's provider. The types of
+// the attributes, and whether they may be changed, are enforced when a
+// configuration is validated.
+var configSchema = map[string]Attr{
+	"type": {
+		Type:        StringType,
+		Immutable:   true,
+		Description: "The type of cloud provider the environment runs on.",
+	},
+	"name": {
+		Type:        StringType,
+		Immutable:   true,
+		Description: "The name of the environment.",
+	},
+	"uuid": {
+		Type:        UUIDType,
+		Immutable:   true,
+		Description: "The unique identifier of the environment.",
+	},
+	"default-series": {
+		Type:        StringType,
+		Description: "The series used for machines and charms when none is specified.",
+	},
+	AgentMetadataURLKey: {
+		Type:        StringType,
+		Description: "The URL of the simplestreams data from which agent binaries are found.",
+	},
+	"image-metadata-url": {
+		Type:        StringType,
+		Description: "The URL of the simplestreams data from which machine images are found.",
+	},
+	"image-stream": {
+		Type:        StringType,
+		Description: `The simplestreams stream from which machine images are chosen, such as "released" or "daily".`,
+	},
+	AgentStreamKey: {
+		Type:        StringType,
+		Description: `The simplestreams stream from which agent binaries are chosen, such as "released" or "devel".`,
+	},
+	"authorized-keys": {
+		Type:        StringType,
+		Description: "The SSH public keys allowed to log in to the environment's machines.",
+	},
+	"authorized-keys-path": {
+		Type:        StringType,
+		Description: "The path of a file holding the SSH public keys to use for authorized-keys. Only used when the environment is created.",
+	},
+	"firewall-mode": {
+		Type:        StringType,
+		Immutable:   true,
+		Description: `How ports are opened in the provider's firewall: "instance", "global" or "none".`,
+	},
+	"agent-version": {
+		Type:        StringType,
+		Description: "The version of the agents the environment should run. It is changed by upgrade-juju.",
+	},
+	"development": {
+		Type:        BoolType,
+		Description: "Whether the environment is in development mode.",
+	},
+	"admin-secret": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The password of the admin user, used when the environment is bootstrapped.",
+	},
+	"ca-cert": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The CA certificate that signs the state servers' certificates.",
+	},
+	"ca-cert-path": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The path of a file holding the ca-cert. Only used when the environment is created.",
+	},
+	"ca-private-key": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The private key of the ca-cert.",
+	},
+	"ca-private-key-path": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The path of a file holding the ca-private-key. Only used when the environment is created.",
+	},
+	"ssl-hostname-verification": {
+		Type:        BoolType,
+		Description: "Whether the host names of HTTPS servers are verified against their certificates.",
+	},
+	"state-port": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "The port on which the state servers' mongo databases listen.",
+	},
+	"api-port": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "The port on which the API servers listen.",
+	},
+	"syslog-port": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "The port on which the state servers receive agents' logs.",
+	},
+	"rsyslog-ca-cert": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The CA certificate that secures the forwarding of agents' logs.",
+	},
+	"rsyslog-ca-key": {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Description: "The private key of the rsyslog-ca-cert.",
+	},
+	"logging-config": {
+		Type:        StringType,
+		Description: `The logging levels of the agents, such as "<root>=WARNING;juju.worker=DEBUG".`,
+	},
+	"charm-store-auth": {
+		Type:        StringType,
+		Description: "The authentication token sent with requests to the charm store.",
+	},
+	ProvisionerHarvestModeKey: {
+		Type:        StringType,
+		Description: `Which unknown instances the provisioner stops: "all", "none", "unknown" or "destroyed".`,
+	},
+	HttpProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by machines for HTTP requests.",
+	},
+	HttpsProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by machines for HTTPS requests.",
+	},
+	FtpProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by machines for FTP requests.",
+	},
+	NoProxyKey: {
+		Type:        StringType,
+		Description: "A comma-separated list of hosts that are reached without a proxy.",
+	},
+	AptHttpProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by apt for HTTP requests. Defaults to http-proxy.",
+	},
+	AptHttpsProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by apt for HTTPS requests. Defaults to https-proxy.",
+	},
+	AptFtpProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by apt for FTP requests. Defaults to ftp-proxy.",
+	},
+	SnapHttpProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by snapd for HTTP requests. Defaults to http-proxy.",
+	},
+	SnapHttpsProxyKey: {
+		Type:        StringType,
+		Description: "The proxy used by snapd for HTTPS requests. Defaults to https-proxy.",
+	},
+	"apt-mirror": {
+		Type:        StringType,
+		Description: "The mirror from which machines install packages.",
+	},
+	"bootstrap-timeout": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "How long, in seconds, to wait for the bootstrap machine to become reachable over SSH.",
+	},
+	"bootstrap-retry-delay": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "How long, in seconds, to wait between attempts to reach the bootstrap machine over SSH.",
+	},
+	"bootstrap-addresses-delay": {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "How long, in seconds, to wait between refreshes of the bootstrap machine's addresses.",
+	},
+	"test-mode": {
+		Type:        BoolType,
+		Description: "Whether the environment is used for testing, so that its charm store requests are left out of download statistics.",
+	},
+	"proxy-ssh": {
+		Type:        BoolType,
+		Description: "Whether SSH connections to machines are proxied through the API server.",
+	},
+	LxcClone: {
+		Type:        BoolType,
+		Immutable:   true,
+		Description: "Whether LXC containers are created by cloning a template container.",
+	},
+	"lxc-clone-aufs": {
+		Type:        BoolType,
+		Immutable:   true,
+		Description: "Whether cloned LXC containers use an AUFS filesystem backed by their template.",
+	},
+	"prefer-ipv6": {
+		Type:        BoolType,
+		Immutable:   true,
+		Description: "Whether IPv6 addresses are preferred over IPv4 for API endpoints and machines.",
+	},
+	"enable-os-refresh-update": {
+		Type:        BoolType,
+		Description: "Whether new machines update their package lists when they start.",
+	},
+	"enable-os-upgrade": {
+		Type:        BoolType,
+		Description: "Whether new machines upgrade their packages when they start.",
+	},
+	"disable-network-management": {
+		Type:        BoolType,
+		Description: "Whether juju is prevented from configuring networking on machines.",
+	},
+	"offline-mode": {
+		Type:        BoolType,
+		Description: "Whether the environment has no internet access, so that agent binaries and images are only served by the state servers.",
+	},
+	SetNumaControlPolicyKey: {
+		Type:        BoolType,
+		Group:       ControllerGroup,
+		Description: "Whether the state servers' mongo databases run under numactl.",
+	},
+	MongoStorageEngineKey: {
+		Type:        StringType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: `The storage engine of the state servers' mongo databases: "mmapv1" or "wiredTiger".`,
+	},
+	MongoOplogSizeKey: {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Immutable:   true,
+		Description: "The size, in megabytes, of the state servers' mongo oplog, or 0 to size it by the disk space available.",
+	},
+	MongoCacheSizeKey: {
+		Type:        IntType,
+		Group:       ControllerGroup,
+		Description: "The size, in gigabytes, of the WiredTiger cache of the state servers' mongo databases, or 0 for mongod's default.",
+	},
+	CharmStorageQuotaKey: {
+		Type:        IntType,
+		Description: "The maximum size, in megabytes, of the charm archives stored for the environment, or 0 for no limit.",
+	},
+	CharmRetentionKey: {
+		Type:        StringType,
+		Description: `How long a charm no longer used by any service or unit is kept in storage, such as "24h".`,
+	},
+	MaintenanceWindowKey: {
+		Type:        StringType,
+		Description: "A cron expression giving when the environment's maintenance window opens. Disruptive automated operations only run inside the window.",
+	},
+	MaintenanceWindowDurationKey: {
+		Type:        StringType,
+		Description: `How long the maintenance window stays open, such as "1h".`,
+	},
+	PreventDestroyEnvironmentKey: {
+		Type:        BoolType,
+		Deprecated:  true,
+		Description: "Whether the environment may not be destroyed. Replaced by the block command.",
+	},
+	PreventRemoveObjectKey: {
+		Type:        BoolType,
+		Deprecated:  true,
+		Description: "Whether machines, services, units and relations may not be removed. Replaced by the block command.",
+	},
+	PreventAllChangesKey: {
+		Type:        BoolType,
+		Deprecated:  true,
+		Description: "Whether the environment may not be changed. Replaced by the block command.",
+	},
+	StorageDefaultBlockSourceKey: {
+		Type:        StringType,
+		Description: "The storage pool or provider used for block storage when none is specified.",
+	},
+	EgressModeKey: {
+		Type:        StringType,
+		Description: `Whether units may connect anywhere ("open") or only to the destinations in egress-allowed ("restricted").`,
+	},
+	EgressAllowedKey: {
+		Type:        StringType,
+		Description: "A comma-separated list of destinations units may connect to when egress-mode is restricted.",
+	},
+	IngressAllowedKey: {
+		Type:        StringType,
+		Description: "A comma-separated list of address ranges, in CIDR notation, from which exposed services may be reached.",
+	},
+	ConcurrentHooksKey: {
+		Type:        BoolType,
+		Description: "Whether hooks of different units on the same machine may run at the same time.",
+	},
+	HookOutputLimitKey: {
+		Type:        IntType,
+		Description: "The number of bytes of each hook's output kept with its result.",
+	},
+	DNSBackendKey: {
+		Type:        StringType,
+		Description: `The DNS service in which machine addresses are registered: "nsupdate" or "designate". No records are registered if it is not set.`,
+	},
+	DNSZoneKey: {
+		Type:        StringType,
+		Description: "The DNS zone in which machine addresses are registered.",
+	},
+	DNSServerKey: {
+		Type:        StringType,
+		Description: "The address, with an optional port, of the DNS server sent updates by the nsupdate backend.",
+	},
+	DNSTSIGKeyKey: {
+		Type:        StringType,
+		Description: "The TSIG key, in the form [<algorithm>:]<name>:<secret>, used to sign DNS updates.",
+	},
+	DNSTTLKey: {
+		Type:        IntType,
+		Description: "The time to live, in seconds, of the DNS records of machine addresses.",
+	},
+	MeterStatusAlertURLKey: {
+		Type:        StringType,
+		Description: "The URL of a webhook to which alerts are posted when the meter status of a unit turns RED.",
+	},
+	MetricsEndpointKey: {
+		Type:        BoolType,
+		Group:       ControllerGroup,
+		Description: "Whether the API servers serve their metrics at /metrics.",
+	},
+	FeaturesKey: {
+		Type:        StringType,
+		Description: "A comma-separated list of feature flags enabled for the environment.",
+	},
+	ToolsMetadataURLKey: {
+		Type:        StringType,
+		Deprecated:  true,
+		Description: "Replaced by agent-metadata-url.",
+	},
+	LxcUseClone: {
+		Type:        BoolType,
+		Deprecated:  true,
+		Description: "Replaced by lxc-clone.",
+	},
+	ProvisionerSafeModeKey: {
+		Type:        BoolType,
+		Deprecated:  true,
+		Description: "Replaced by provisioner-harvest-mode.",
+	},
+	ToolsStreamKey: {
+		Type:        StringType,
+		Deprecated:  true,
+		Description: "Replaced by agent-stream.",
+	},
+}
+
+// Schema returns descriptions of the configuration attributes known
+// to juju, keyed by attribute name. It does not include attributes
+// defined by the environment's provider.
+func Schema() map[string]Attr {
+	result := make(map[string]Attr, len(configSchema))
+	for name, attr := range configSchema {
+		if attr.Group == "" {
+			attr.Group = EnvironGroup
+		}
+		if value, ok := defaults[name]; ok && value != schema.Omit {
+			attr.Default = value
+		}
+		result[name] = attr
+	}
+	return result
+}
+
+// schemaFields returns the checkers for the values of the attributes
+// in configSchema.
+func schemaFields() schema.Fields {
+	result := make(schema.Fields, len(configSchema))
+	for name, attr := range configSchema {
+		var checker schema.Checker
+		switch attr.Type {
+		case StringType:
+			checker = schema.String()
+		case BoolType:
+			checker = schema.Bool()
+		case IntType:
+			checker = schema.ForceInt()
+		case UUIDType:
+			checker = schema.UUID()
+		default:
+			panic(fmt.Sprintf("unknown type %q of config attribute %q", attr.Type, name))
+		}
+		result[name] = checker
+	}
+	return result
+}
+
+// schemaImmutableAttributes returns the names of the attributes in
+// configSchema that cannot be changed, in alphabetical order.
+func schemaImmutableAttributes() []string {
+	var result []string
+	for name, attr := range configSchema {
+		if attr.Immutable {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ImmutableConfigValueError is returned when a configuration changes
+// the value of an attribute which cannot be changed.
+type ImmutableConfigValueError struct {
+	// Key is the name of the attribute.
+	Key string
+	// Old holds the attribute's current value.
+	Old interface{}
+	// New holds the value the attribute was to be changed to.
+	New interface{}
+}
+
+// Error returns the error string.
+func (e *ImmutableConfigValueError) Error() string {
+	return fmt.Sprintf("cannot change %s from %#v to %#v", e.Key, e.Old, e.New)
+}
+
+// IsImmutableConfigValueError returns whether err is an
+// ImmutableConfigValueError.
+func IsImmutableConfigValueError(err error) bool {
+	_, ok := errors.Cause(err).(*ImmutableConfigValueError)
+	return ok
+}
+
+// IsInvalidConfigValueError returns whether err is an
+// InvalidConfigValueError.
+func IsInvalidConfigValueError(err error) bool {
+	_, ok := errors.Cause(err).(*InvalidConfigValueError)
+	return ok
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/testing"
+)
+
+type SchemaSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&SchemaSuite{})
+
+func (*SchemaSuite) TestSchemaDescribesEveryAttribute(c *gc.C) {
+	for name, attr := range config.Schema() {
+		c.Check(attr.Description, gc.Not(gc.Equals), "", gc.Commentf("attribute %q", name))
+		c.Check(attr.Type, gc.Not(gc.Equals), config.AttrType(""), gc.Commentf("attribute %q", name))
+		c.Check(attr.Group, gc.Not(gc.Equals), config.AttrGroup(""), gc.Commentf("attribute %q", name))
+	}
+}
+
+func (*SchemaSuite) TestSchemaAttributes(c *gc.C) {
+	schema := config.Schema()
+
+	attr, ok := schema["api-port"]
+	c.Assert(ok, jc.IsTrue)
+	c.Check(attr.Type, gc.Equals, config.IntType)
+	c.Check(attr.Group, gc.Equals, config.ControllerGroup)
+	c.Check(attr.Immutable, jc.IsTrue)
+	c.Check(attr.Default, gc.Equals, config.DefaultAPIPort)
+
+	attr, ok = schema["logging-config"]
+	c.Assert(ok, jc.IsTrue)
+	c.Check(attr.Type, gc.Equals, config.StringType)
+	c.Check(attr.Group, gc.Equals, config.EnvironGroup)
+	c.Check(attr.Immutable, jc.IsFalse)
+	c.Check(attr.Default, gc.IsNil)
+
+	attr, ok = schema["uuid"]
+	c.Assert(ok, jc.IsTrue)
+	c.Check(attr.Type, gc.Equals, config.UUIDType)
+
+	attr, ok = schema[config.ToolsMetadataURLKey]
+	c.Assert(ok, jc.IsTrue)
+	c.Check(attr.Deprecated, jc.IsTrue)
+}
+
+func (*SchemaSuite) TestSchemaReturnsCopy(c *gc.C) {
+	schema := config.Schema()
+	delete(schema, "name")
+	_, ok := config.Schema()["name"]
+	c.Assert(ok, jc.IsTrue)
+}
+
+func (*SchemaSuite) TestSchemaTypesEnforced(c *gc.C) {
+	_, err := config.New(config.UseDefaults, testing.Attrs{
+		"type":      "my-type",
+		"name":      "my-name",
+		"api-port":  "not-a-number",
+		"test-mode": true,
+	})
+	c.Assert(err, gc.ErrorMatches, `api-port: expected number, got string\("not-a-number"\)`)
+}
+
+func (*SchemaSuite) TestIsInvalidConfigValueError(c *gc.C) {
+	err := &config.InvalidConfigValueError{Key: "foo", Value: "bar"}
+	c.Check(config.IsInvalidConfigValueError(err), jc.IsTrue)
+	c.Check(config.IsInvalidConfigValueError(errors.Annotate(err, "context")), jc.IsTrue)
+	c.Check(config.IsInvalidConfigValueError(errors.New("foo")), jc.IsFalse)
+}
+
+func (*SchemaSuite) TestImmutableConfigValueError(c *gc.C) {
+	err := &config.ImmutableConfigValueError{Key: "api-port", Old: 17070, New: 42}
+	c.Check(err, gc.ErrorMatches, "cannot change api-port from 17070 to 42")
+	c.Check(config.IsImmutableConfigValueError(errors.Annotate(err, "context")), jc.IsTrue)
+	c.Check(config.IsImmutableConfigValueError(errors.New("foo")), jc.IsFalse)
+}
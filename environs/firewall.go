@@ -0,0 +1,49 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/juju/network"
+)
+
+// InstanceIngressRules is implemented by instances whose firewall can
+// restrict access to the port ranges it opens to particular source
+// addresses. Instances that do not implement it can only open ports to
+// any address.
+type InstanceIngressRules interface {
+	// OpenIngressRules opens the given ingress rules on the instance,
+	// which should have been started with the given machine id.
+	OpenIngressRules(machineId string, rules []network.IngressRule) error
+
+	// CloseIngressRules closes the given ingress rules on the
+	// instance, which should have been started with the given
+	// machine id.
+	CloseIngressRules(machineId string, rules []network.IngressRule) error
+
+	// IngressRules returns the ingress rules open on the instance,
+	// which should have been started with the given machine id. The
+	// rules are sorted by network.SortIngressRules().
+	IngressRules(machineId string) ([]network.IngressRule, error)
+}
+
+// EnvironIngressRules is implemented by environments whose global
+// firewall can restrict access to the port ranges it opens to
+// particular source addresses.
+type EnvironIngressRules interface {
+	// OpenIngressRules opens the given ingress rules for the whole
+	// environment. Must only be used if the environment was set up
+	// with the FwGlobal firewall mode.
+	OpenIngressRules(rules []network.IngressRule) error
+
+	// CloseIngressRules closes the given ingress rules for the whole
+	// environment. Must only be used if the environment was set up
+	// with the FwGlobal firewall mode.
+	CloseIngressRules(rules []network.IngressRule) error
+
+	// IngressRules returns the ingress rules open for the whole
+	// environment, sorted by network.SortIngressRules(). Must only be
+	// used if the environment was set up with the FwGlobal firewall
+	// mode.
+	IngressRules() ([]network.IngressRule, error)
+}
@@ -23,6 +23,8 @@ type InstanceType struct {
 	VirtType *string // The type of virtualisation used by the hypervisor, must match the image.
 	CpuPower *uint64
 	Tags     []string
+	Gpus     uint64
+	GpuType  string
 }
 
 func CpuPower(power uint64) *uint64 {
@@ -55,6 +57,12 @@ func (itype InstanceType) match(cons constraints.Value) (InstanceType, bool) {
 	if cons.RootDisk != nil && itype.RootDisk > 0 && itype.RootDisk < *cons.RootDisk {
 		return nothing, false
 	}
+	if cons.Gpus != nil && itype.Gpus < *cons.Gpus {
+		return nothing, false
+	}
+	if cons.GpuType != nil && *cons.GpuType != "" && itype.GpuType != *cons.GpuType {
+		return nothing, false
+	}
 	if cons.Tags != nil && len(*cons.Tags) > 0 && !tagsMatch(*cons.Tags, itype.Tags) {
 		return nothing, false
 	}
@@ -166,6 +174,9 @@ func (bc byCost) Less(i, j int) bool {
 	if inst0.RootDisk != inst1.RootDisk {
 		return inst0.RootDisk < inst1.RootDisk
 	}
+	if inst0.Gpus != inst1.Gpus {
+		return inst0.Gpus < inst1.Gpus
+	}
 	// we intentionally don't compare tags, since we can't know how tags compare against each other
 	return false
 }
@@ -201,6 +201,26 @@ var getInstanceTypesTest = []struct {
 		},
 		expectedItypes: []string{"it-2"},
 	},
+	{
+		about: "gpus",
+		cons:  "gpus=2",
+		itypesToUse: []InstanceType{
+			{Id: "3", Name: "it-3", Arches: []string{"amd64"}, Mem: 4096, Gpus: 4, GpuType: "nvidia", Cost: 300},
+			{Id: "2", Name: "it-2", Arches: []string{"amd64"}, Mem: 4096, Gpus: 2, GpuType: "amd", Cost: 200},
+			{Id: "1", Name: "it-1", Arches: []string{"amd64"}, Mem: 4096, Cost: 100},
+		},
+		expectedItypes: []string{"it-2", "it-3"},
+	},
+	{
+		about: "gpu-type",
+		cons:  "gpu-type=nvidia",
+		itypesToUse: []InstanceType{
+			{Id: "3", Name: "it-3", Arches: []string{"amd64"}, Mem: 4096, Gpus: 4, GpuType: "nvidia", Cost: 300},
+			{Id: "2", Name: "it-2", Arches: []string{"amd64"}, Mem: 4096, Gpus: 2, GpuType: "amd", Cost: 200},
+			{Id: "1", Name: "it-1", Arches: []string{"amd64"}, Mem: 4096, Cost: 100},
+		},
+		expectedItypes: []string{"it-3"},
+	},
 }
 
 func (s *instanceTypeSuite) TestGetMatchingInstanceTypes(c *gc.C) {
@@ -237,6 +257,9 @@ func (s *instanceTypeSuite) TestGetMatchingInstanceTypesErrors(c *gc.C) {
 
 	_, err = MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("mem=90000M"))
 	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "mem=90000M"`)
+
+	_, err = MatchingInstanceTypes(instanceTypes, "test", constraints.MustParse("gpus=1"))
+	c.Check(err, gc.ErrorMatches, `no instance types in test matching constraints "gpus=1"`)
 }
 
 var instanceTypeMatchTests = []struct {
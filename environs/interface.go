@@ -10,6 +10,7 @@ import (
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/environs/storage"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
@@ -78,6 +79,52 @@ type ConfigGetter interface {
 	Config() *config.Config
 }
 
+// CredentialValidator is implemented by environs which can check
+// that the provider still accepts the credentials in their config.
+type CredentialValidator interface {
+	// ValidateCredential returns an error satisfying
+	// errors.IsUnauthorized if the provider rejects the environ's
+	// credentials. Any other error means the credentials could not
+	// be checked.
+	ValidateCredential() error
+}
+
+// InstanceMetadataLister is implemented by environs which can list
+// the instance types and images offered by the provider, so that the
+// state server may cache them.
+type InstanceMetadataLister interface {
+	// InstanceTypes returns the instance types offered in the
+	// environ's region, with their costs where known.
+	InstanceTypes() ([]instances.InstanceType, error)
+
+	// Images returns the images offered in the environ's region
+	// for the given series.
+	Images(series string) ([]instances.Image, error)
+}
+
+// CostEstimator is implemented by providers which can estimate the
+// price of their instance types, so that the cost of deploying
+// services may be estimated without calling the provider.
+type CostEstimator interface {
+	// HourlyCost returns the estimated cost of running an instance
+	// of the given type for an hour, and the currency it is in.
+	HourlyCost(itype instances.InstanceType) (cost float64, currency string)
+}
+
+// DNSRegistrar is implemented by environs whose provider offers a DNS
+// service in which the host names and addresses of machines can be
+// registered.
+type DNSRegistrar interface {
+	// SetDNSRecords replaces the address records of the given fully
+	// qualified host name in the given zone with ones for the given
+	// addresses, which live for ttl seconds.
+	SetDNSRecords(zone, hostname string, ttl int, addresses []network.Address) error
+
+	// RemoveDNSRecords removes the address records of the given
+	// fully qualified host name from the given zone.
+	RemoveDNSRecords(zone, hostname string) error
+}
+
 // BootstrapParams holds the parameters for bootstrapping an environment.
 type BootstrapParams struct {
 	// Constraints are used to choose the initial instance specification,
@@ -59,6 +59,12 @@ type ProxyRoundTripper struct {
 
 var _ http.RoundTripper = (*ProxyRoundTripper)(nil)
 
+// protocolRegisterer is implemented by http.Transport, and by the
+// transports that wrap it.
+type protocolRegisterer interface {
+	RegisterProtocol(scheme string, rt http.RoundTripper)
+}
+
 // RegisterForScheme registers a ProxyRoundTripper as the default roundtripper
 // for the given URL scheme.
 //
@@ -67,7 +73,7 @@ var _ http.RoundTripper = (*ProxyRoundTripper)(nil)
 // "Sub" field to delegate to a different roundtripper (or to nil if you don't
 // want to handle its requests at all any more).
 func (prt *ProxyRoundTripper) RegisterForScheme(scheme string) {
-	http.DefaultTransport.(*http.Transport).RegisterProtocol(scheme, prt)
+	http.DefaultTransport.(protocolRegisterer).RegisterProtocol(scheme, prt)
 }
 
 func (prt *ProxyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
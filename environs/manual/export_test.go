@@ -6,6 +6,7 @@ package manual
 var (
 	NetLookupHost         = &netLookupHost
 	ProvisionMachineAgent = &provisionMachineAgent
+	PollInterval          = &pollInterval
 )
 
 const (
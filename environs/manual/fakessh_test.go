@@ -7,14 +7,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"strings"
 
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
-
-	"github.com/juju/juju/environs/manual"
-	"github.com/juju/juju/service"
 )
 
 // sshscript should only print the result on the first execution,
@@ -82,77 +78,3 @@ func installFakeSSH(c *gc.C, input, output interface{}, rc int) testing.Restorer
 	c.Assert(err, jc.ErrorIsNil)
 	return testing.PatchEnvPathPrepend(fakebin)
 }
-
-// installDetectionFakeSSH installs a fake SSH command, which will respond
-// to the series/hardware detection script with the specified
-// series/arch.
-func installDetectionFakeSSH(c *gc.C, series, arch string) testing.Restorer {
-	if series == "" {
-		series = "precise"
-	}
-	if arch == "" {
-		arch = "amd64"
-	}
-	detectionoutput := strings.Join([]string{
-		series,
-		arch,
-		"MemTotal: 4096 kB",
-		"processor: 0",
-	}, "\n")
-	return installFakeSSH(c, manual.DetectionScript, detectionoutput, 0)
-}
-
-// fakeSSH wraps the invocation of InstallFakeSSH based on the parameters.
-type fakeSSH struct {
-	Series string
-	Arch   string
-
-	// Provisioned should be set to true if the fakeSSH script
-	// should respond to checkProvisioned with a non-empty result.
-	Provisioned bool
-
-	// exit code for the checkProvisioned script.
-	CheckProvisionedExitCode int
-
-	// exit code for the machine agent provisioning script.
-	ProvisionAgentExitCode int
-
-	// InitUbuntuUser should be set to true if the fakeSSH script
-	// should respond to an attempt to initialise the ubuntu user.
-	InitUbuntuUser bool
-
-	// there are conditions other than error in the above
-	// that might cause provisioning to not go ahead, such
-	// as tools being missing.
-	SkipProvisionAgent bool
-
-	// detection will be skipped if the series/hardware were
-	// detected ahead of time. This should always be set to
-	// true when testing Bootstrap.
-	SkipDetection bool
-}
-
-// install installs fake SSH commands, which will respond to
-// manual provisioning/bootstrapping commands with the specified
-// output and exit codes.
-func (r fakeSSH) install(c *gc.C) testing.Restorer {
-	var restore testing.Restorer
-	add := func(input, output interface{}, rc int) {
-		restore = restore.Add(installFakeSSH(c, input, output, rc))
-	}
-	if !r.SkipProvisionAgent {
-		add(nil, nil, r.ProvisionAgentExitCode)
-	}
-	if !r.SkipDetection {
-		restore.Add(installDetectionFakeSSH(c, r.Series, r.Arch))
-	}
-	var checkProvisionedOutput interface{}
-	if r.Provisioned {
-		checkProvisionedOutput = "/etc/init/jujud-machine-0.conf"
-	}
-	add(service.ListServicesCommand(), checkProvisionedOutput, r.CheckProvisionedExitCode)
-	if r.InitUbuntuUser {
-		add("", nil, 0)
-	}
-	return restore
-}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/arch"
-	"github.com/juju/juju/service"
 	"github.com/juju/juju/utils/ssh"
 )
 
@@ -32,30 +31,7 @@ cat /proc/cpuinfo`
 var CheckProvisioned = checkProvisioned
 
 func checkProvisioned(host string) (bool, error) {
-	logger.Infof("Checking if %s is already provisioned", host)
-
-	script := service.ListServicesCommand()
-
-	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, nil)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	cmd.Stdin = strings.NewReader(script)
-	if err := cmd.Run(); err != nil {
-		if stderr.Len() != 0 {
-			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
-		}
-		return false, err
-	}
-
-	output := strings.TrimSpace(stdout.String())
-	provisioned := strings.Contains(output, "juju")
-	if provisioned {
-		logger.Infof("%s is already provisioned [%q]", host, output)
-	} else {
-		logger.Infof("%s is not provisioned", host)
-	}
-	return provisioned, nil
+	return RemoteHost{Hostname: host}.CheckProvisioned()
 }
 
 // DetectSeriesAndHardwareCharacteristics detects the OS
@@ -64,19 +40,13 @@ func checkProvisioned(host string) (bool, error) {
 var DetectSeriesAndHardwareCharacteristics = detectSeriesAndHardwareCharacteristics
 
 func detectSeriesAndHardwareCharacteristics(host string) (hc instance.HardwareCharacteristics, series string, err error) {
-	logger.Infof("Detecting series and characteristics on %s", host)
-	cmd := ssh.Command("ubuntu@"+host, []string{"/bin/bash"}, nil)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	cmd.Stdin = bytes.NewBufferString(detectionScript)
-	if err := cmd.Run(); err != nil {
-		if stderr.Len() != 0 {
-			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
-		}
-		return hc, "", err
-	}
-	lines := strings.Split(stdout.String(), "\n")
+	return RemoteHost{Hostname: host}.DetectSeriesAndHardwareCharacteristics()
+}
+
+// parseDetectionOutput extracts the OS series and hardware
+// characteristics from the output of detectionScript.
+func parseDetectionOutput(output string) (hc instance.HardwareCharacteristics, series string, err error) {
+	lines := strings.Split(output, "\n")
 	series = strings.TrimSpace(lines[0])
 
 	arch := arch.NormaliseArch(lines[1])
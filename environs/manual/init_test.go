@@ -156,3 +156,14 @@ func (s *initialisationSuite) TestInitUbuntuUserError(c *gc.C) {
 	err := manual.InitUbuntuUser("testhost", "testuser", "", nil, nil)
 	c.Assert(err, gc.ErrorMatches, "subprocess encountered error code 123 \\(failed to create ubuntu user\\)")
 }
+
+func (s *initialisationSuite) TestDetectInitSystem(c *gc.C) {
+	defer installFakeSSH(c, service.DiscoverInitSystemScript(), "upstart", 0)()
+	initSystem, err := manual.RemoteHost{Hostname: "example.com"}.DetectInitSystem()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(initSystem, gc.Equals, "upstart")
+
+	defer installFakeSSH(c, service.DiscoverInitSystemScript(), []string{"", "unrecognised"}, 1)()
+	_, err = manual.RemoteHost{Hostname: "example.com"}.DetectInitSystem()
+	c.Assert(err, gc.ErrorMatches, "subprocess encountered error code 1 \\(unrecognised\\)")
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -19,9 +20,6 @@ import (
 	"github.com/juju/juju/cloudinit/sshinit"
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/environs/config"
-	"github.com/juju/juju/instance"
-	"github.com/juju/juju/network"
-	"github.com/juju/juju/state/multiwatcher"
 )
 
 const manualInstancePrefix = "manual:"
@@ -32,9 +30,9 @@ var logger = loggo.GetLogger("juju.environs.manual")
 // provisioning of machines.  An interface is used here to decouple the API
 // consumer from the actual API implementation type.
 type ProvisioningClientAPI interface {
-	AddMachines([]params.AddMachineParams) ([]params.AddMachinesResult, error)
-	ForceDestroyMachines(machines ...string) error
-	ProvisioningScript(params.ProvisioningScriptParams) (script string, err error)
+	EnvironmentGet() (map[string]interface{}, error)
+	AddManualHost(host string, disablePackageCommands bool) (id string, err error)
+	ManualHostStatus(id string) (params.ManualHostStatus, error)
 }
 
 type ProvisionMachineArgs struct {
@@ -65,65 +63,85 @@ type ProvisionMachineArgs struct {
 // machine has an existing machine agent.
 var ErrProvisioned = errors.New("machine is already provisioned")
 
+// pollInterval is the interval at which the progress of a manual
+// provisioning request is checked.
+var pollInterval = 2 * time.Second
+
 // ProvisionMachine provisions a machine agent to an existing host, via
 // an SSH connection to the specified host. The host may optionally be preceded
 // with a login username, as in [user@]host.
 //
+// The host is prepared so that the state server can log in to it as the
+// ubuntu user; the state server then inspects the host, records it as a
+// machine, and installs the machine agent, retrying if an attempt fails.
+// ProvisionMachine reports the progress of the state server to Stderr
+// until the host is provisioned or the state server gives up.
+//
 // On successful completion, this function will return the id of the state.Machine
 // that was entered into state.
 func ProvisionMachine(args ProvisionMachineArgs) (machineId string, err error) {
-	defer func() {
-		if machineId != "" && err != nil {
-			logger.Errorf("provisioning failed, removing machine %v: %v", machineId, err)
-			if cleanupErr := args.Client.ForceDestroyMachines(machineId); cleanupErr != nil {
-				logger.Warningf("error cleaning up machine: %s", cleanupErr)
-			}
-			machineId = ""
-		}
-	}()
-
 	// Create the "ubuntu" user and initialise passwordless sudo. We populate
 	// the ubuntu user's authorized_keys file with the public keys in the current
-	// user's ~/.ssh directory. The authenticationworker will later update the
-	// ubuntu user's authorized_keys.
+	// user's ~/.ssh directory, and the environment's authorized keys so that
+	// the state server can log in with its system identity. The
+	// authenticationworker will later update the ubuntu user's authorized_keys.
 	user, hostname := splitUserHost(args.Host)
 	authorizedKeys, err := config.ReadAuthorizedKeys("")
-	if err := InitUbuntuUser(hostname, user, authorizedKeys, args.Stdin, args.Stdout); err != nil {
-		return "", err
-	}
-
-	machineParams, err := gatherMachineParams(hostname)
 	if err != nil {
-		return "", err
+		logger.Debugf("cannot read local authorized keys: %v", err)
 	}
-
-	// Inform Juju that the machine exists.
-	machineId, err = recordMachineInState(args.Client, *machineParams)
+	attrs, err := args.Client.EnvironmentGet()
 	if err != nil {
+		return "", errors.Annotate(err, "cannot get environment config")
+	}
+	if envKeys, ok := attrs["authorized-keys"].(string); ok {
+		authorizedKeys = config.ConcatAuthKeys(authorizedKeys, envKeys)
+	}
+	if err := InitUbuntuUser(hostname, user, authorizedKeys, args.Stdin, args.Stdout); err != nil {
 		return "", err
 	}
 
-	provisioningScript, err := args.Client.ProvisioningScript(params.ProvisioningScriptParams{
-		MachineId: machineId,
-		Nonce:     machineParams.Nonce,
-		DisablePackageCommands: !args.EnableOSRefreshUpdate && !args.EnableOSUpgrade,
-	})
-
+	disablePackageCommands := args.UpdateBehavior != nil &&
+		!args.EnableOSRefreshUpdate && !args.EnableOSUpgrade
+	id, err := args.Client.AddManualHost(hostname, disablePackageCommands)
 	if err != nil {
-		logger.Errorf("cannot obtain provisioning script")
-		return "", err
+		return "", errors.Annotatef(err, "cannot provision %q", hostname)
 	}
-
-	// Finally, provision the machine agent.
-	err = runProvisionScript(provisioningScript, hostname, args.Stderr)
+	machineId, err = waitProvisioned(args.Client, id, args.Stderr)
 	if err != nil {
-		return machineId, err
+		return "", err
 	}
-
 	logger.Infof("Provisioned machine %v", machineId)
 	return machineId, nil
 }
 
+// waitProvisioned waits for the manual provisioning request with the
+// given id to complete, writing each new progress message to
+// progressWriter, and returns the id of the provisioned machine.
+func waitProvisioned(client ProvisioningClientAPI, id string, progressWriter io.Writer) (string, error) {
+	var lastMessage string
+	for {
+		status, err := client.ManualHostStatus(id)
+		if err != nil {
+			return "", errors.Annotate(err, "cannot get provisioning status")
+		}
+		if status.Message != lastMessage && progressWriter != nil {
+			fmt.Fprintln(progressWriter, status.Message)
+		}
+		lastMessage = status.Message
+		switch status.Status {
+		case params.ManualHostProvisioned:
+			return status.MachineId, nil
+		case params.ManualHostFailed:
+			if status.Message == ErrProvisioned.Error() {
+				return "", ErrProvisioned
+			}
+			return "", errors.New(status.Message)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
 func splitUserHost(host string) (string, string) {
 	if at := strings.Index(host, "@"); at != -1 {
 		return host[:at], host[at+1:]
@@ -131,77 +149,6 @@ func splitUserHost(host string) (string, string) {
 	return "", host
 }
 
-func recordMachineInState(client ProvisioningClientAPI, machineParams params.AddMachineParams) (machineId string, err error) {
-	results, err := client.AddMachines([]params.AddMachineParams{machineParams})
-	if err != nil {
-		return "", err
-	}
-	// Currently, only one machine is added, but in future there may be several added in one call.
-	machineInfo := results[0]
-	if machineInfo.Error != nil {
-		return "", machineInfo.Error
-	}
-	return machineInfo.Machine, nil
-}
-
-// gatherMachineParams collects all the information we know about the machine
-// we are about to provision. It will SSH into that machine as the ubuntu user.
-// The hostname supplied should not include a username.
-// If we can, we will reverse lookup the hostname by its IP address, and use
-// the DNS resolved name, rather than the name that was supplied
-func gatherMachineParams(hostname string) (*params.AddMachineParams, error) {
-
-	// Generate a unique nonce for the machine.
-	uuid, err := utils.NewUUID()
-	if err != nil {
-		return nil, err
-	}
-
-	var addrs []network.Address
-	if addr, err := HostAddress(hostname); err != nil {
-		logger.Warningf("failed to compute public address for %q: %v", hostname, err)
-	} else {
-		addrs = append(addrs, addr)
-	}
-
-	provisioned, err := checkProvisioned(hostname)
-	if err != nil {
-		err = fmt.Errorf("error checking if provisioned: %v", err)
-		return nil, err
-	}
-	if provisioned {
-		return nil, ErrProvisioned
-	}
-
-	hc, series, err := DetectSeriesAndHardwareCharacteristics(hostname)
-	if err != nil {
-		err = fmt.Errorf("error detecting hardware characteristics: %v", err)
-		return nil, err
-	}
-
-	// There will never be a corresponding "instance" that any provider
-	// knows about. This is fine, and works well with the provisioner
-	// task. The provisioner task will happily remove any and all dead
-	// machines from state, but will ignore the associated instance ID
-	// if it isn't one that the environment provider knows about.
-	// Also, manually provisioned machines don't have the JobManageNetworking.
-	// This ensures that the networker is running in non-intrusive mode
-	// and never touches the network configuration files.
-	// No JobManageNetworking here due to manual provisioning.
-
-	instanceId := instance.Id(manualInstancePrefix + hostname)
-	nonce := fmt.Sprintf("%s:%s", instanceId, uuid.String())
-	machineParams := &params.AddMachineParams{
-		Series:                  series,
-		HardwareCharacteristics: hc,
-		InstanceId:              instanceId,
-		Nonce:                   nonce,
-		Addrs:                   params.FromNetworkAddresses(addrs),
-		Jobs:                    []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
-	}
-	return machineParams, nil
-}
-
 var provisionMachineAgent = func(host string, mcfg *cloudinit.MachineConfig, progressWriter io.Writer) error {
 	script, err := ProvisioningScript(mcfg)
 	if err != nil {
@@ -242,9 +189,5 @@ func ProvisioningScript(mcfg *cloudinit.MachineConfig) (string, error) {
 }
 
 func runProvisionScript(script, host string, progressWriter io.Writer) error {
-	params := sshinit.ConfigureParams{
-		Host:           "ubuntu@" + host,
-		ProgressWriter: progressWriter,
-	}
-	return sshinit.RunConfigureScript(script, params)
+	return RemoteHost{Hostname: host}.RunScript(script, progressWriter)
 }
@@ -4,8 +4,9 @@
 package manual_test
 
 import (
-	"fmt"
-	"os"
+	"bytes"
+	"errors"
+	"time"
 
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/shell"
@@ -18,12 +19,10 @@ import (
 	"github.com/juju/juju/cloudinit/sshinit"
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/environs/manual"
-	envtesting "github.com/juju/juju/environs/testing"
-	envtools "github.com/juju/juju/environs/tools"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
 	coretesting "github.com/juju/juju/testing"
-	"github.com/juju/juju/version"
+	"github.com/juju/juju/testing/factory"
 )
 
 type provisionerSuite struct {
@@ -32,105 +31,121 @@ type provisionerSuite struct {
 
 var _ = gc.Suite(&provisionerSuite{})
 
-func (s *provisionerSuite) getArgs(c *gc.C) manual.ProvisionMachineArgs {
-	hostname, err := os.Hostname()
-	c.Assert(err, jc.ErrorIsNil)
-	client := s.APIState.Client()
-	s.AddCleanup(func(*gc.C) { client.Close() })
+func (s *provisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(manual.PollInterval, time.Millisecond)
+}
+
+func (s *provisionerSuite) getArgs(client manual.ProvisioningClientAPI) manual.ProvisionMachineArgs {
 	return manual.ProvisionMachineArgs{
-		Host:           hostname,
+		Host:           "ubuntu@example.com",
 		Client:         client,
+		Stderr:         &bytes.Buffer{},
 		UpdateBehavior: &params.UpdateBehavior{true, true},
 	}
 }
 
 func (s *provisionerSuite) TestProvisionMachine(c *gc.C) {
-	const series = coretesting.FakeDefaultSeries
-	const arch = "amd64"
-	const operatingSystem = version.Ubuntu
-
-	args := s.getArgs(c)
-	hostname := args.Host
-	args.Host = "ubuntu@" + args.Host
-
-	defaultToolsURL := envtools.DefaultBaseURL
-	envtools.DefaultBaseURL = ""
-
-	defer fakeSSH{
-		Series:             series,
-		Arch:               arch,
-		InitUbuntuUser:     true,
-		SkipProvisionAgent: true,
-	}.install(c).Restore()
-	// Attempt to provision a machine with no tools available, expect it to fail.
+	defer installFakeSSH(c, "", nil, 0)()
+	client := &fakeProvisioningClient{
+		statuses: []params.ManualHostStatus{
+			{Status: params.ManualHostPending, Message: "waiting to be provisioned"},
+			{Status: params.ManualHostProvisioning, Message: "inspecting host"},
+			{Status: params.ManualHostProvisioning, Message: "inspecting host"},
+			{Status: params.ManualHostPending, Message: "attempt 1 failed: connection reset"},
+			{Status: params.ManualHostProvisioning, Message: "installing agent for machine 3"},
+			{Status: params.ManualHostProvisioned, Message: "machine 3 provisioned", MachineId: "3"},
+		},
+	}
+	args := s.getArgs(client)
 	machineId, err := manual.ProvisionMachine(args)
-	c.Assert(err, jc.Satisfies, params.IsCodeNotFound)
-	c.Assert(machineId, gc.Equals, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineId, gc.Equals, "3")
+	c.Assert(client.host, gc.Equals, "example.com")
+	c.Assert(client.disablePackageCommands, jc.IsFalse)
+	c.Assert(args.Stderr.(*bytes.Buffer).String(), gc.Equals, `
+waiting to be provisioned
+inspecting host
+attempt 1 failed: connection reset
+installing agent for machine 3
+machine 3 provisioned
+`[1:])
+}
+
+func (s *provisionerSuite) TestProvisionMachineDisablePackageCommands(c *gc.C) {
+	defer installFakeSSH(c, "", nil, 0)()
+	client := &fakeProvisioningClient{
+		statuses: []params.ManualHostStatus{
+			{Status: params.ManualHostProvisioned, MachineId: "0"},
+		},
+	}
+	args := s.getArgs(client)
+	args.UpdateBehavior = &params.UpdateBehavior{false, false}
+	_, err := manual.ProvisionMachine(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(client.disablePackageCommands, jc.IsTrue)
+}
 
-	cfg := s.Environ.Config()
-	number, ok := cfg.AgentVersion()
-	c.Assert(ok, jc.IsTrue)
-	binVersion := version.Binary{number, series, arch, operatingSystem}
-	envtesting.AssertUploadFakeToolsVersions(c, s.DefaultToolsStorage, "released", "released", binVersion)
-	envtools.DefaultBaseURL = defaultToolsURL
-
-	for i, errorCode := range []int{255, 0} {
-		c.Logf("test %d: code %d", i, errorCode)
-		defer fakeSSH{
-			Series:                 series,
-			Arch:                   arch,
-			InitUbuntuUser:         true,
-			ProvisionAgentExitCode: errorCode,
-		}.install(c).Restore()
-		machineId, err = manual.ProvisionMachine(args)
-		if errorCode != 0 {
-			c.Assert(err, gc.ErrorMatches, fmt.Sprintf("subprocess encountered error code %d", errorCode))
-			c.Assert(machineId, gc.Equals, "")
-		} else {
-			c.Assert(err, jc.ErrorIsNil)
-			c.Assert(machineId, gc.Not(gc.Equals), "")
-			// machine ID will be incremented. Even though we failed and the
-			// machine is removed, the ID is not reused.
-			c.Assert(machineId, gc.Equals, fmt.Sprint(i+1))
-			m, err := s.State.Machine(machineId)
-			c.Assert(err, jc.ErrorIsNil)
-			instanceId, err := m.InstanceId()
-			c.Assert(err, jc.ErrorIsNil)
-			c.Assert(instanceId, gc.Equals, instance.Id("manual:"+hostname))
-		}
+func (s *provisionerSuite) TestProvisionMachineFailed(c *gc.C) {
+	defer installFakeSSH(c, "", nil, 0)()
+	client := &fakeProvisioningClient{
+		statuses: []params.ManualHostStatus{
+			{Status: params.ManualHostFailed, Message: "cannot install agent: connection reset"},
+		},
 	}
+	machineId, err := manual.ProvisionMachine(s.getArgs(client))
+	c.Assert(err, gc.ErrorMatches, "cannot install agent: connection reset")
+	c.Assert(machineId, gc.Equals, "")
+}
 
-	// Attempting to provision a machine twice should fail. We effect
-	// this by checking for existing juju upstart configurations.
-	defer fakeSSH{
-		Provisioned:        true,
-		InitUbuntuUser:     true,
-		SkipDetection:      true,
-		SkipProvisionAgent: true,
-	}.install(c).Restore()
-	_, err = manual.ProvisionMachine(args)
+func (s *provisionerSuite) TestProvisionMachineAlreadyProvisioned(c *gc.C) {
+	defer installFakeSSH(c, "", nil, 0)()
+	client := &fakeProvisioningClient{
+		statuses: []params.ManualHostStatus{
+			{Status: params.ManualHostFailed, Message: "machine is already provisioned"},
+		},
+	}
+	_, err := manual.ProvisionMachine(s.getArgs(client))
 	c.Assert(err, gc.Equals, manual.ErrProvisioned)
-	defer fakeSSH{
-		Provisioned:              true,
-		CheckProvisionedExitCode: 255,
-		InitUbuntuUser:           true,
-		SkipDetection:            true,
-		SkipProvisionAgent:       true,
-	}.install(c).Restore()
-	_, err = manual.ProvisionMachine(args)
-	c.Assert(err, gc.ErrorMatches, "error checking if provisioned: subprocess encountered error code 255")
 }
 
-func (s *provisionerSuite) TestFinishMachineConfig(c *gc.C) {
-	const series = coretesting.FakeDefaultSeries
-	const arch = "amd64"
-	defer fakeSSH{
-		Series:         series,
-		Arch:           arch,
-		InitUbuntuUser: true,
-	}.install(c).Restore()
-	machineId, err := manual.ProvisionMachine(s.getArgs(c))
+func (s *provisionerSuite) TestProvisionMachineAddError(c *gc.C) {
+	defer installFakeSSH(c, "", nil, 0)()
+	client := &fakeProvisioningClient{
+		addErr: errors.New(`provisioning request 0 for host "example.com" already exists`),
+	}
+	_, err := manual.ProvisionMachine(s.getArgs(client))
+	c.Assert(err, gc.ErrorMatches, `cannot provision "example.com": provisioning request 0 for host "example.com" already exists`)
+}
+
+func (s *provisionerSuite) TestProvisionMachineAPI(c *gc.C) {
+	// The request is made through the API, and left for the
+	// state server to pick up.
+	defer installFakeSSH(c, "", nil, 0)()
+	s.PatchValue(manual.PollInterval, coretesting.ShortWait)
+	client := &cancellingClient{ProvisioningClientAPI: s.APIState.Client()}
+	_, err := manual.ProvisionMachine(s.getArgs(client))
+	c.Assert(err, gc.ErrorMatches, "cannot get provisioning status: stopped waiting")
+
+	hosts, err := s.State.PendingManualHosts()
 	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hosts, gc.HasLen, 1)
+	c.Assert(hosts[0].Host(), gc.Equals, "example.com")
+	c.Assert(hosts[0].DisablePackageCommands(), jc.IsFalse)
+}
+
+func (s *provisionerSuite) makeManualMachine(c *gc.C) string {
+	arch := "amd64"
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Series:          coretesting.FakeDefaultSeries,
+		InstanceId:      instance.Id("manual:example.com"),
+		Characteristics: &instance.HardwareCharacteristics{Arch: &arch},
+	})
+	return machine.Id()
+}
+
+func (s *provisionerSuite) TestFinishMachineConfig(c *gc.C) {
+	machineId := s.makeManualMachine(c)
 
 	// Now check what we would've configured it with.
 	mcfg, err := client.MachineConfig(s.State, machineId, agent.BootstrapNonce, "/var/lib/juju")
@@ -146,18 +161,9 @@ func (s *provisionerSuite) TestFinishMachineConfig(c *gc.C) {
 }
 
 func (s *provisionerSuite) TestProvisioningScript(c *gc.C) {
-	const series = coretesting.FakeDefaultSeries
-	const arch = "amd64"
-	defer fakeSSH{
-		Series:         series,
-		Arch:           arch,
-		InitUbuntuUser: true,
-	}.install(c).Restore()
-
-	machineId, err := manual.ProvisionMachine(s.getArgs(c))
-	c.Assert(err, jc.ErrorIsNil)
+	machineId := s.makeManualMachine(c)
 
-	err = s.State.UpdateEnvironConfig(
+	err := s.State.UpdateEnvironConfig(
 		map[string]interface{}{
 			"enable-os-upgrade": false,
 		}, nil, nil)
@@ -182,3 +188,48 @@ func (s *provisionerSuite) TestProvisioningScript(c *gc.C) {
 	expectedScript := removeLogFile + shell.DumpFileOnErrorScript("/var/log/cloud-init-output.log") + sshinitScript
 	c.Assert(script, gc.Equals, expectedScript)
 }
+
+type fakeProvisioningClient struct {
+	host                   string
+	disablePackageCommands bool
+	addErr                 error
+	statuses               []params.ManualHostStatus
+}
+
+func (f *fakeProvisioningClient) EnvironmentGet() (map[string]interface{}, error) {
+	return map[string]interface{}{"authorized-keys": "juju-system-key"}, nil
+}
+
+func (f *fakeProvisioningClient) AddManualHost(host string, disablePackageCommands bool) (string, error) {
+	if f.addErr != nil {
+		return "", f.addErr
+	}
+	f.host = host
+	f.disablePackageCommands = disablePackageCommands
+	return "0", nil
+}
+
+func (f *fakeProvisioningClient) ManualHostStatus(id string) (params.ManualHostStatus, error) {
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	status.Id = id
+	status.Host = f.host
+	return status, nil
+}
+
+// cancellingClient stops ProvisionMachine waiting for a request
+// which no worker will pick up.
+type cancellingClient struct {
+	manual.ProvisioningClientAPI
+	polled bool
+}
+
+func (c *cancellingClient) ManualHostStatus(id string) (params.ManualHostStatus, error) {
+	if c.polled {
+		return params.ManualHostStatus{}, errors.New("stopped waiting")
+	}
+	c.polled = true
+	return c.ProvisioningClientAPI.ManualHostStatus(id)
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manual
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/juju/cloudinit/sshinit"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/utils/ssh"
+)
+
+// RemoteHost is an existing host reached over SSH as the ubuntu user.
+type RemoteHost struct {
+	// Hostname is the hostname or address of the host, without
+	// a login.
+	Hostname string
+
+	// Options holds the SSH options to connect with, such as the
+	// identities to authenticate with. It may be nil.
+	Options *ssh.Options
+}
+
+// InstanceId returns the instance id recorded for a machine manually
+// provisioned on the host.
+func (h RemoteHost) InstanceId() instance.Id {
+	return instance.Id(manualInstancePrefix + h.Hostname)
+}
+
+// CheckProvisioned checks if any juju init service already exists on
+// the host.
+func (h RemoteHost) CheckProvisioned() (bool, error) {
+	logger.Infof("Checking if %s is already provisioned", h.Hostname)
+	output, err := h.run(service.ListServicesCommand())
+	if err != nil {
+		return false, err
+	}
+	output = strings.TrimSpace(output)
+	provisioned := strings.Contains(output, "juju")
+	if provisioned {
+		logger.Infof("%s is already provisioned [%q]", h.Hostname, output)
+	} else {
+		logger.Infof("%s is not provisioned", h.Hostname)
+	}
+	return provisioned, nil
+}
+
+// DetectSeriesAndHardwareCharacteristics detects the OS series and
+// hardware characteristics of the host.
+func (h RemoteHost) DetectSeriesAndHardwareCharacteristics() (hc instance.HardwareCharacteristics, series string, err error) {
+	logger.Infof("Detecting series and characteristics on %s", h.Hostname)
+	output, err := h.run(detectionScript)
+	if err != nil {
+		return hc, "", err
+	}
+	return parseDetectionOutput(output)
+}
+
+// DetectInitSystem detects the init system running on the host.
+func (h RemoteHost) DetectInitSystem() (string, error) {
+	logger.Infof("Detecting init system on %s", h.Hostname)
+	output, err := h.run(service.DiscoverInitSystemScript())
+	if err != nil {
+		return "", err
+	}
+	initSystem := strings.TrimSpace(output)
+	if initSystem == "" {
+		return "", fmt.Errorf("init system not recognised")
+	}
+	return initSystem, nil
+}
+
+// RunScript runs the given script on the host as root, writing the
+// script's progress to progressWriter.
+func (h RemoteHost) RunScript(script string, progressWriter io.Writer) error {
+	params := sshinit.ConfigureParams{
+		Host:           "ubuntu@" + h.Hostname,
+		Options:        h.Options,
+		ProgressWriter: progressWriter,
+	}
+	return sshinit.RunConfigureScript(script, params)
+}
+
+// run runs the given script on the host as the ubuntu user, and
+// returns its output.
+func (h RemoteHost) run(script string) (string, error) {
+	cmd := ssh.Command("ubuntu@"+h.Hostname, []string{"/bin/bash"}, h.Options)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Stdin = strings.NewReader(script)
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() != 0 {
+			err = fmt.Errorf("%v (%v)", err, strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
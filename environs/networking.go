@@ -35,6 +35,18 @@ type Networking interface {
 	SupportsAddressAllocation(subnetId network.Id) (bool, error)
 }
 
+// SubnetLister is implemented by environments that can report the
+// subnets known to the provider. Every NetworkingEnviron implements
+// it, but environments that cannot allocate addresses may too.
+type SubnetLister interface {
+	// Subnets returns basic information about the subnets with the
+	// given ids known by the provider for the environment. If inst
+	// is not empty, only the subnets the instance is connected to
+	// are considered. If no subnet ids are given, all subnets
+	// considered are returned.
+	Subnets(inst instance.Id, subnetIds []network.Id) ([]network.SubnetInfo, error)
+}
+
 // NetworkingEnviron combines the standard Environ interface with the
 // functionality for networking.
 type NetworkingEnviron interface {
@@ -0,0 +1,365 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The offline package supports environments without access to the
+// internet. It reads the tools and container images such environments
+// need from a local directory or archive, checks that everything
+// required is present and intact, and hands them to the state servers,
+// from which all machines in the environment then fetch them.
+//
+// The directory has the same layout as the one accepted by
+// bootstrap --metadata-source, with container images added:
+//
+//	tools/<stream>/juju-<version>.tgz
+//	tools/streams/v1/...
+//	images/streams/v1/...
+//	containers/lxc/<series>/<arch>/<image>.tar.gz
+//	containers/lxc/<series>/<arch>/SHA256SUMS
+package offline
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/instance"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+var logger = loggo.GetLogger("juju.environs.offline")
+
+// ContainersPath is the path, relative to the artifacts directory,
+// beneath which container images are stored.
+const ContainersPath = "containers"
+
+// checksumsFile is the name of the optional file listing the SHA-256
+// checksums of the container images in the same directory, in the
+// format written by sha256sum.
+const checksumsFile = "SHA256SUMS"
+
+// Image describes a container image stored in an artifacts directory.
+type Image struct {
+	Kind   instance.ContainerType
+	Series string
+	Arch   string
+	Path   string
+}
+
+// Artifacts holds the tools and container images found in an
+// artifacts directory. The URL of each of the tools is a file:// URL.
+type Artifacts struct {
+	Dir    string
+	Tools  coretools.List
+	Images []Image
+}
+
+// Open returns the directory holding the artifacts at source, which
+// may be a directory or a gzipped tar archive of one. An archive is
+// extracted into a temporary directory, which is removed when the
+// returned cleanup function is called.
+func Open(source string) (dir string, cleanup func(), err error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	if info.IsDir() {
+		return source, func() {}, nil
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	defer f.Close()
+	dir, err = ioutil.TempDir("", "juju-offline-")
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warningf("cannot remove %q: %v", dir, err)
+		}
+	}
+	if err := extract(f, dir); err != nil {
+		cleanup()
+		return "", nil, errors.Annotatef(err, "cannot extract %q", source)
+	}
+	return dir, cleanup, nil
+}
+
+// extract unpacks the gzipped tar archive read from r into dir.
+func extract(r io.Reader, dir string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return errors.Errorf("bad name %q in archive", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return errors.Trace(err)
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return errors.Trace(err)
+			}
+			if err := writeFile(path, tr); err != nil {
+				return errors.Trace(err)
+			}
+		default:
+			return errors.Errorf("bad file type %c in file %q in archive", hdr.Typeflag, hdr.Name)
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Read returns the artifacts found in dir, having verified the
+// checksums of any container images for which they are recorded.
+func Read(dir string) (*Artifacts, error) {
+	artifacts := &Artifacts{Dir: dir}
+	toolsFiles, err := filepath.Glob(filepath.Join(dir, "tools", "*", "juju-*.tgz"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	seen := make(map[version.Binary]bool)
+	for _, path := range toolsFiles {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "juju-"), ".tgz")
+		vers, err := version.ParseBinary(name)
+		if err != nil {
+			logger.Warningf("ignoring unexpected tools file %q", path)
+			continue
+		}
+		if seen[vers] {
+			continue
+		}
+		seen[vers] = true
+		size, sha256, err := hashFile(path)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		artifacts.Tools = append(artifacts.Tools, &coretools.Tools{
+			Version: vers,
+			URL:     "file://" + filepath.ToSlash(path),
+			Size:    size,
+			SHA256:  sha256,
+		})
+	}
+	sort.Sort(artifacts.Tools)
+
+	imageDirs, err := filepath.Glob(filepath.Join(dir, ContainersPath, "*", "*", "*"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, imageDir := range imageDirs {
+		images, err := readImages(imageDir)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		artifacts.Images = append(artifacts.Images, images...)
+	}
+	return artifacts, nil
+}
+
+// readImages returns the images in the given
+// containers/<kind>/<series>/<arch> directory.
+func readImages(imageDir string) ([]Image, error) {
+	arch := filepath.Base(imageDir)
+	series := filepath.Base(filepath.Dir(imageDir))
+	kind, err := instance.ParseContainerType(filepath.Base(filepath.Dir(filepath.Dir(imageDir))))
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid container image directory %q", imageDir)
+	}
+	checksums, err := readChecksums(filepath.Join(imageDir, checksumsFile))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	paths, err := filepath.Glob(filepath.Join(imageDir, "*.tar.gz"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var images []Image
+	for _, path := range paths {
+		if expected, ok := checksums[filepath.Base(path)]; ok {
+			_, sha256, err := hashFile(path)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if sha256 != expected {
+				return nil, errors.Errorf("checksum mismatch for %q", path)
+			}
+		}
+		images = append(images, Image{
+			Kind:   kind,
+			Series: series,
+			Arch:   arch,
+			Path:   path,
+		})
+	}
+	return images, nil
+}
+
+// readChecksums reads a file written by sha256sum, returning the
+// checksums keyed by file name. A missing file yields no checksums.
+func readChecksums(path string) (map[string]string, error) {
+	checksums := make(map[string]string)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return checksums, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Annotatef(err, "cannot read %q", path)
+	}
+	return checksums, nil
+}
+
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", errors.Annotatef(err, "cannot read %q", path)
+	}
+	return size, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// MatchingTools returns the tools with the given version, ignoring
+// the build number, for any of the given series and architecture. An
+// empty arch matches all architectures.
+func (a *Artifacts) MatchingTools(vers version.Number, arch string, series ...string) coretools.List {
+	var matching coretools.List
+	for _, tools := range a.Tools {
+		if !sameRelease(tools.Version.Number, vers) {
+			continue
+		}
+		if arch != "" && tools.Version.Arch != arch {
+			continue
+		}
+		for _, s := range series {
+			if tools.Version.Series == s {
+				matching = append(matching, tools)
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// Validate returns an error unless the artifacts include tools with
+// the given version, ignoring the build number, for each of the given
+// series and the given architecture, or for any architecture if arch
+// is empty.
+func (a *Artifacts) Validate(vers version.Number, arch string, series ...string) error {
+	var missing []string
+	for _, s := range series {
+		if len(a.MatchingTools(vers, arch, s)) > 0 {
+			continue
+		}
+		platform := s
+		if arch != "" {
+			platform += "/" + arch
+		}
+		missing = append(missing, platform)
+	}
+	if len(missing) > 0 {
+		return errors.Errorf(
+			"no %v tools for %s found in %q",
+			vers, strings.Join(missing, ", "), a.Dir,
+		)
+	}
+	return nil
+}
+
+func sameRelease(v1, v2 version.Number) bool {
+	v1.Build = 0
+	v2.Build = 0
+	return v1.Compare(v2) == 0
+}
+
+// Uploader is implemented by API clients which can store tools and
+// container images on the state servers.
+type Uploader interface {
+	UploadTools(r io.Reader, vers version.Binary, additionalSeries ...string) (*coretools.Tools, error)
+	UploadImage(r io.Reader, kind instance.ContainerType, series, arch string) error
+}
+
+// Upload stores the given tools, which must have been read from the
+// artifacts, and all of the artifacts' container images on the state
+// servers.
+func (a *Artifacts) Upload(uploader Uploader, tools coretools.List) error {
+	for _, t := range tools {
+		logger.Infof("uploading %v tools", t.Version)
+		if err := uploadFile(strings.TrimPrefix(t.URL, "file://"), func(r io.Reader) error {
+			_, err := uploader.UploadTools(r, t.Version)
+			return err
+		}); err != nil {
+			return errors.Annotatef(err, "cannot upload %v tools", t.Version)
+		}
+	}
+	for _, image := range a.Images {
+		logger.Infof("uploading %s %s/%s image", image.Kind, image.Series, image.Arch)
+		if err := uploadFile(image.Path, func(r io.Reader) error {
+			return uploader.UploadImage(r, image.Kind, image.Series, image.Arch)
+		}); err != nil {
+			return errors.Annotatef(err, "cannot upload %s %s/%s image", image.Kind, image.Series, image.Arch)
+		}
+	}
+	return nil
+}
+
+func uploadFile(path string, upload func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	return upload(f)
+}
@@ -0,0 +1,253 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package offline_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/offline"
+	"github.com/juju/juju/instance"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type offlineSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&offlineSuite{})
+
+var testFiles = map[string]string{
+	"tools/released/juju-1.24.0-trusty-amd64.tgz": "trusty tools",
+	"tools/released/juju-1.24.0-precise-i386.tgz": "precise tools",
+	"tools/released/juju-1.23.0-trusty-amd64.tgz": "old tools",
+	"tools/released/README":                       "not tools",
+	"containers/lxc/trusty/amd64/trusty.tar.gz":   "trusty image",
+	"containers/lxc/precise/i386/precise.tar.gz":  "precise image",
+	"containers/lxc/precise/i386/SHA256SUMS":      sha256sum("precise image") + " *precise.tar.gz\n",
+}
+
+func sha256sum(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+func writeFiles(c *gc.C, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = ioutil.WriteFile(path, []byte(data), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func archive(c *gc.C, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, data := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		})
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = tw.Write([]byte(data))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(tw.Close(), jc.ErrorIsNil)
+	c.Assert(zw.Close(), jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+func (s *offlineSuite) TestOpenDirectory(c *gc.C) {
+	dir := c.MkDir()
+	opened, cleanup, err := offline.Open(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	defer cleanup()
+	c.Assert(opened, gc.Equals, dir)
+}
+
+func (s *offlineSuite) TestOpenArchive(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "artifacts.tar.gz")
+	err := ioutil.WriteFile(path, archive(c, testFiles), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	dir, cleanup, err := offline.Open(path)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "tools", "released", "juju-1.24.0-trusty-amd64.tgz"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "trusty tools")
+
+	cleanup()
+	_, err = os.Stat(dir)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *offlineSuite) TestOpenArchiveBadName(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "artifacts.tar.gz")
+	err := ioutil.WriteFile(path, archive(c, map[string]string{"../escape": "x"}), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = offline.Open(path)
+	c.Assert(err, gc.ErrorMatches, `cannot extract ".*": bad name "../escape" in archive`)
+}
+
+func (s *offlineSuite) TestRead(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+
+	artifacts, err := offline.Read(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(artifacts.Dir, gc.Equals, dir)
+
+	var versions []string
+	for _, tools := range artifacts.Tools {
+		versions = append(versions, tools.Version.String())
+		c.Assert(tools.URL, gc.Equals, "file://"+filepath.ToSlash(
+			filepath.Join(dir, "tools", "released", "juju-"+tools.Version.String()+".tgz"),
+		))
+	}
+	c.Assert(versions, jc.DeepEquals, []string{
+		"1.23.0-trusty-amd64",
+		"1.24.0-precise-i386",
+		"1.24.0-trusty-amd64",
+	})
+	trusty := artifacts.Tools[2]
+	c.Assert(trusty.Size, gc.Equals, int64(len("trusty tools")))
+	c.Assert(trusty.SHA256, gc.Equals, sha256sum("trusty tools"))
+
+	c.Assert(artifacts.Images, jc.SameContents, []offline.Image{{
+		Kind:   instance.LXC,
+		Series: "trusty",
+		Arch:   "amd64",
+		Path:   filepath.Join(dir, "containers", "lxc", "trusty", "amd64", "trusty.tar.gz"),
+	}, {
+		Kind:   instance.LXC,
+		Series: "precise",
+		Arch:   "i386",
+		Path:   filepath.Join(dir, "containers", "lxc", "precise", "i386", "precise.tar.gz"),
+	}})
+}
+
+func (s *offlineSuite) TestReadChecksumMismatch(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+	writeFiles(c, dir, map[string]string{
+		"containers/lxc/precise/i386/precise.tar.gz": "corrupted image",
+	})
+
+	_, err := offline.Read(dir)
+	c.Assert(err, gc.ErrorMatches, `checksum mismatch for ".*precise.tar.gz"`)
+}
+
+func (s *offlineSuite) TestReadInvalidContainerType(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, map[string]string{
+		"containers/bogus/trusty/amd64/trusty.tar.gz": "image",
+	})
+
+	_, err := offline.Read(dir)
+	c.Assert(err, gc.ErrorMatches, `invalid container image directory ".*": .*`)
+}
+
+func (s *offlineSuite) TestMatchingTools(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+	artifacts, err := offline.Read(dir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	vers := version.MustParse("1.24.0.1")
+	matching := artifacts.MatchingTools(vers, "", "trusty", "precise", "utopic")
+	c.Assert(matching, gc.HasLen, 2)
+	matching = artifacts.MatchingTools(vers, "amd64", "trusty", "precise")
+	c.Assert(matching, gc.HasLen, 1)
+	c.Assert(matching[0].Version, gc.Equals, version.MustParseBinary("1.24.0-trusty-amd64"))
+}
+
+func (s *offlineSuite) TestValidate(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+	artifacts, err := offline.Read(dir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	vers := version.MustParse("1.24.0")
+	err = artifacts.Validate(vers, "", "trusty", "precise")
+	c.Assert(err, jc.ErrorIsNil)
+	err = artifacts.Validate(vers, "amd64", "trusty", "precise", "utopic")
+	c.Assert(err, gc.ErrorMatches, `no 1.24.0 tools for precise/amd64, utopic/amd64 found in ".*"`)
+	err = artifacts.Validate(version.MustParse("1.25.0"), "", "trusty")
+	c.Assert(err, gc.ErrorMatches, `no 1.25.0 tools for trusty found in ".*"`)
+}
+
+func (s *offlineSuite) TestUpload(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+	artifacts, err := offline.Read(dir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	uploader := &fakeUploader{uploaded: make(map[string]string)}
+	tools := artifacts.MatchingTools(version.MustParse("1.24.0"), "", "trusty")
+	err = artifacts.Upload(uploader, tools)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(uploader.uploaded, jc.DeepEquals, map[string]string{
+		"tools 1.24.0-trusty-amd64": "trusty tools",
+		"lxc trusty/amd64":          "trusty image",
+		"lxc precise/i386":          "precise image",
+	})
+}
+
+func (s *offlineSuite) TestUploadError(c *gc.C) {
+	dir := c.MkDir()
+	writeFiles(c, dir, testFiles)
+	artifacts, err := offline.Read(dir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	uploader := &fakeUploader{err: errors.New("boom")}
+	err = artifacts.Upload(uploader, artifacts.Tools[:1])
+	c.Assert(err, gc.ErrorMatches, "cannot upload 1.23.0-trusty-amd64 tools: boom")
+}
+
+type fakeUploader struct {
+	uploaded map[string]string
+	err      error
+}
+
+func (f *fakeUploader) UploadTools(r io.Reader, vers version.Binary, additionalSeries ...string) (*coretools.Tools, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f.uploaded["tools "+vers.String()] = string(data)
+	return &coretools.Tools{Version: vers}, nil
+}
+
+func (f *fakeUploader) UploadImage(r io.Reader, kind instance.ContainerType, series, arch string) error {
+	if f.err != nil {
+		return f.err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.uploaded[fmt.Sprintf("%s %s/%s", kind, series, arch)] = string(data)
+	return nil
+}
@@ -4,10 +4,16 @@
 package tools
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils"
 
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/simplestreams"
@@ -177,6 +183,37 @@ func FindExactTools(env environs.Environ, vers version.Number, series string, ar
 	return availableTools[0], nil
 }
 
+// FetchTools downloads the tools tarball described by the given tools
+// and returns its contents, having verified their size and SHA-256 hash.
+func FetchTools(tools *coretools.Tools) ([]byte, error) {
+	// No need to verify the server's identity because we verify the SHA-256 hash.
+	logger.Infof("fetching %v tools from %v", tools.Version, tools.URL)
+	resp, err := utils.GetNonValidatingHTTPClient().Get(tools.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("bad HTTP response: %v", resp.Status)
+		if body, err := ioutil.ReadAll(resp.Body); err == nil {
+			msg += fmt.Sprintf(" (%s)", bytes.TrimSpace(body))
+		}
+		return nil, errors.New(msg)
+	}
+	hash := sha256.New()
+	data, err := ioutil.ReadAll(io.TeeReader(resp.Body, hash))
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read %s", tools.URL)
+	}
+	if int64(len(data)) != tools.Size {
+		return nil, errors.Errorf("size mismatch for %s", tools.URL)
+	}
+	if fmt.Sprintf("%x", hash.Sum(nil)) != tools.SHA256 {
+		return nil, errors.Errorf("hash mismatch for %s", tools.URL)
+	}
+	return data, nil
+}
+
 // checkToolsSeries verifies that all the given possible tools are for the
 // given OS series.
 func checkToolsSeries(toolsList coretools.List, series string) error {
@@ -34,3 +34,8 @@ const LogErrorStack = "log-error-stack"
 // discovery code (service.VersionInitSystem) should return upstart
 // instead of systemd for vivid and newer.
 const LegacyUpstart = "legacy-upstart"
+
+// OplogWatcher is the name of the feature to have the state watcher
+// tail the mongo oplog for changes rather than only polling the
+// transaction log.
+const OplogWatcher = "oplog-watcher"
@@ -9,6 +9,8 @@ import (
 
 	"github.com/juju/juju/api/annotations"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -74,3 +76,28 @@ func (s *annotationsSuite) TestSetCallGettingErrors(c *gc.C) {
 	c.Assert(firstFound.Annotations, gc.HasLen, 0)
 	c.Assert(firstFound.Error.Error, gc.IsNil)
 }
+
+func (s *annotationsSuite) TestWatch(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, &factory.MachineParams{
+		Jobs: []state.MachineJob{state.JobHostUnits},
+	})
+
+	w, err := s.annotationsClient.Watch(machine.Tag().String())
+	c.Assert(err, jc.ErrorIsNil)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.BackingState, w)
+
+	// Initial event.
+	wc.AssertOneChange()
+
+	callErrs, err := s.annotationsClient.Set(
+		map[string]map[string]string{
+			machine.Tag().String(): {"gui/x": "10"},
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(callErrs, gc.HasLen, 0)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -100,7 +100,7 @@ func (s *leadershipSuite) SetUpTest(c *gc.C) {
 
 	// Create & start a machine agent so the tests have something to call into.
 	agentConf := agentcmd.AgentConf{DataDir: s.DataDir()}
-	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf)
+	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf, nil)
 	s.machineAgent = machineAgentFactory(stateServer.Id())
 
 	// See comment in createMockJujudExecutable
@@ -339,7 +339,7 @@ func (s *uniterLeadershipSuite) SetUpTest(c *gc.C) {
 
 	// Create & start a machine agent so the tests have something to call into.
 	agentConf := agentcmd.AgentConf{DataDir: s.DataDir()}
-	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf)
+	machineAgentFactory := agentcmd.MachineAgentFactoryFn(&agentConf, &agentConf, nil)
 	s.machineAgent = machineAgentFactory(stateServer.Id())
 
 	// See comment in createMockJujudExecutable
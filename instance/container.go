@@ -13,12 +13,17 @@ const (
 	NONE = ContainerType("none")
 	LXC  = ContainerType("lxc")
 	KVM  = ContainerType("kvm")
+
+	// HYPERV containers are Windows containers run with Hyper-V
+	// isolation.
+	HYPERV = ContainerType("hyperv")
 )
 
 // ContainerTypes is used to validate add-machine arguments.
 var ContainerTypes []ContainerType = []ContainerType{
 	LXC,
 	KVM,
+	HYPERV,
 }
 
 // ParseContainerTypeOrNone converts the specified string into a supported
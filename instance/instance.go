@@ -56,6 +56,12 @@ type HardwareCharacteristics struct {
 	CpuPower *uint64   `json:",omitempty" yaml:"cpupower,omitempty"`
 	Tags     *[]string `json:",omitempty" yaml:"tags,omitempty"`
 
+	// NumaNodes and Gpus are only known once detected by the
+	// machine agent, and may change on bare metal.
+	NumaNodes *uint64 `json:",omitempty" yaml:"numanodes,omitempty"`
+	Gpus      *uint64 `json:",omitempty" yaml:"gpus,omitempty"`
+	GpuType   *string `json:",omitempty" yaml:"gputype,omitempty"`
+
 	AvailabilityZone *string `json:",omitempty" yaml:"availabilityzone,omitempty"`
 }
 
@@ -107,6 +113,15 @@ func (hc HardwareCharacteristics) String() string {
 	if hc.Tags != nil && len(*hc.Tags) > 0 {
 		strs = append(strs, fmt.Sprintf("tags=%s", strings.Join(*hc.Tags, ",")))
 	}
+	if hc.NumaNodes != nil {
+		strs = append(strs, fmt.Sprintf("numa-nodes=%d", *hc.NumaNodes))
+	}
+	if hc.Gpus != nil {
+		strs = append(strs, fmt.Sprintf("gpus=%d", *hc.Gpus))
+	}
+	if hc.GpuType != nil && *hc.GpuType != "" {
+		strs = append(strs, fmt.Sprintf("gpu-type=%s", *hc.GpuType))
+	}
 	if hc.AvailabilityZone != nil && *hc.AvailabilityZone != "" {
 		strs = append(strs, fmt.Sprintf("availability-zone=%s", *hc.AvailabilityZone))
 	}
@@ -173,6 +188,12 @@ func (hc *HardwareCharacteristics) setRaw(raw string) error {
 		err = hc.setRootDisk(str)
 	case "tags":
 		err = hc.setTags(str)
+	case "numa-nodes":
+		err = hc.setNumaNodes(str)
+	case "gpus":
+		err = hc.setGpus(str)
+	case "gpu-type":
+		err = hc.setGpuType(str)
 	case "availability-zone":
 		err = hc.setAvailabilityZone(str)
 	default:
@@ -235,6 +256,32 @@ func (hc *HardwareCharacteristics) setTags(str string) (err error) {
 	return
 }
 
+func (hc *HardwareCharacteristics) setNumaNodes(str string) (err error) {
+	if hc.NumaNodes != nil {
+		return fmt.Errorf("already set")
+	}
+	hc.NumaNodes, err = parseUint64(str)
+	return
+}
+
+func (hc *HardwareCharacteristics) setGpus(str string) (err error) {
+	if hc.Gpus != nil {
+		return fmt.Errorf("already set")
+	}
+	hc.Gpus, err = parseUint64(str)
+	return
+}
+
+func (hc *HardwareCharacteristics) setGpuType(str string) error {
+	if hc.GpuType != nil {
+		return fmt.Errorf("already set")
+	}
+	if str != "" {
+		hc.GpuType = &str
+	}
+	return nil
+}
+
 func (hc *HardwareCharacteristics) setAvailabilityZone(str string) error {
 	if hc.AvailabilityZone != nil {
 		return fmt.Errorf("already set")
@@ -261,13 +261,45 @@ var parseHardwareTests = []parseHardwareTestSpec{
 		err:     `bad "availability-zone" characteristic: already set`,
 	},
 
+	// "numa-nodes" and "gpus" in detail.
+	{
+		summary: "set numa-nodes",
+		args:    []string{"numa-nodes=2"},
+	}, {
+		summary: "set numa-nodes invalid",
+		args:    []string{"numa-nodes=two"},
+		err:     `bad "numa-nodes" characteristic: must be a non-negative integer`,
+	}, {
+		summary: "double set numa-nodes",
+		args:    []string{"numa-nodes=2 numa-nodes=4"},
+		err:     `bad "numa-nodes" characteristic: already set`,
+	}, {
+		summary: "set gpus",
+		args:    []string{"gpus=0"},
+	}, {
+		summary: "set gpus invalid",
+		args:    []string{"gpus=-1"},
+		err:     `bad "gpus" characteristic: must be a non-negative integer`,
+	}, {
+		summary: "double set gpus",
+		args:    []string{"gpus=1", "gpus=2"},
+		err:     `bad "gpus" characteristic: already set`,
+	}, {
+		summary: "set gpu-type",
+		args:    []string{"gpu-type=nvidia"},
+	}, {
+		summary: "double set gpu-type",
+		args:    []string{"gpu-type=nvidia", "gpu-type=amd"},
+		err:     `bad "gpu-type" characteristic: already set`,
+	},
+
 	// Everything at once.
 	{
 		summary: "kitchen sink together",
 		args:    []string{" root-disk=4G mem=2T  arch=i386  cpu-cores=4096 cpu-power=9001 availability-zone=a_zone"},
 	}, {
 		summary: "kitchen sink separately",
-		args:    []string{"root-disk=4G", "mem=2T", "cpu-cores=4096", "cpu-power=9001", "arch=armhf", "availability-zone=a_zone"},
+		args:    []string{"root-disk=4G", "mem=2T", "cpu-cores=4096", "cpu-power=9001", "arch=armhf", "availability-zone=a_zone", "numa-nodes=2", "gpus=4", "gpu-type=nvidia"},
 	},
 }
 
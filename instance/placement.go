@@ -74,6 +74,29 @@ func ParsePlacement(directive string) (*Placement, error) {
 	return nil, ErrPlacementScopeMissing
 }
 
+// ParseUnitPlacement parses a directive describing where to place a
+// unit, as given to deploy and add-unit. Besides the directives
+// accepted by ParsePlacement, which name an existing machine or
+// container ("3", "3/lxc/1"), or a new container on an existing or new
+// machine ("lxc:3", "lxc"), it accepts a provider-specific directive
+// of the form key=value for a new machine ("zone=us-east-1a"), which
+// is returned with an empty scope to be inferred as the environment's
+// own. Provider directives of any form may be scoped explicitly to the
+// environment, by its name or UUID ("env-name:node-1.maas").
+func ParseUnitPlacement(directive string) (*Placement, error) {
+	placement, err := ParsePlacement(directive)
+	if err == ErrPlacementScopeMissing && isProviderDirective(directive) {
+		return &Placement{Directive: directive}, nil
+	}
+	return placement, err
+}
+
+// isProviderDirective returns whether directive has the key=value form
+// of an unscoped provider placement directive.
+func isProviderDirective(directive string) bool {
+	return !strings.HasPrefix(directive, ":") && strings.Index(directive, "=") > 0
+}
+
 // MustParsePlacement attempts to parse the specified string and create
 // a corresponding Placement structure, panicking if an error occurs.
 func MustParsePlacement(directive string) *Placement {
@@ -75,3 +75,61 @@ func (s *PlacementSuite) TestParsePlacement(c *gc.C) {
 		}
 	}
 }
+
+func (s *PlacementSuite) TestParseUnitPlacement(c *gc.C) {
+	parseUnitPlacementTests := []struct {
+		arg                          string
+		expectScope, expectDirective string
+		err                          string
+	}{{
+		arg: "",
+	}, {
+		arg:             "0/lxc/0",
+		expectScope:     instance.MachineScope,
+		expectDirective: "0/lxc/0",
+	}, {
+		arg:             "lxc:1",
+		expectScope:     string(instance.LXC),
+		expectDirective: "1",
+	}, {
+		arg:         "kvm",
+		expectScope: string(instance.KVM),
+	}, {
+		arg: "lxc:x",
+		err: `invalid value "x" for "lxc" scope: expected machine-id`,
+	}, {
+		arg:             "zone=us-east-1a",
+		expectDirective: "zone=us-east-1a",
+	}, {
+		arg: "node-1.maas",
+		err: "placement scope missing",
+	}, {
+		arg:             "env-name:node-1.maas",
+		expectScope:     "env-name",
+		expectDirective: "node-1.maas",
+	}, {
+		arg: "=us-east-1a",
+		err: "placement scope missing",
+	}, {
+		arg: ":zone=us-east-1a",
+		err: "placement scope missing",
+	}}
+
+	for i, t := range parseUnitPlacementTests {
+		c.Logf("test %d: %s", i, t.arg)
+		p, err := instance.ParseUnitPlacement(t.arg)
+		if t.err != "" {
+			c.Assert(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		if t.expectScope == "" && t.expectDirective == "" {
+			c.Assert(p, gc.IsNil)
+		} else {
+			c.Assert(p, gc.DeepEquals, &instance.Placement{
+				Scope:     t.expectScope,
+				Directive: t.expectDirective,
+			})
+		}
+	}
+}
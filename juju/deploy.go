@@ -5,15 +5,14 @@ package juju
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/juju/errors"
-	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/paths"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/storage"
 )
@@ -27,14 +26,24 @@ type DeployServiceParams struct {
 	ConfigSettings charm.Settings
 	Constraints    constraints.Value
 	NumUnits       int
-	// ToMachineSpec is either:
+	// ToMachineSpec is a unit placement directive, as parsed
+	// by instance.ParseUnitPlacement. It may be:
 	// - an existing machine/container id eg "1" or "1/lxc/2"
 	// - a new container on an existing machine eg "lxc:1"
+	// - a new container on a new machine eg "lxc"
+	// - a provider directive for a new machine eg "zone=us-east-1a"
 	// Use string to avoid ambiguity around machine 0.
 	ToMachineSpec string
 	// Networks holds a list of networks to required to start on boot.
 	Networks []string
 	Storage  map[string]storage.Constraints
+	// WorkloadContainer is the type of container in which the charm
+	// of each unit runs, on the machine the unit is assigned to. If it
+	// is empty or instance.NONE, the charms run directly on the machines.
+	WorkloadContainer instance.ContainerType
+	// Channel is the charm store channel the service tracks for
+	// upgrades of its charm. If empty, the stable channel is tracked.
+	Channel state.CharmChannel
 }
 
 // DeployService takes a charm and various parameters and deploys it.
@@ -42,10 +51,18 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 	if args.NumUnits > 1 && args.ToMachineSpec != "" {
 		return nil, fmt.Errorf("cannot use --num-units with --to")
 	}
+	if args.Channel != "" {
+		if err := args.Channel.Validate(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	settings, err := args.Charm.Config().ValidateSettings(args.ConfigSettings)
 	if err != nil {
 		return nil, err
 	}
+	if err := paths.ValidateInterpreters(args.Charm.URL().Series, args.Charm.Interpreters()); err != nil {
+		return nil, errors.Annotatef(err, "cannot deploy charm %q", args.Charm.URL())
+	}
 	if args.Charm.Meta().Subordinate {
 		if args.NumUnits != 0 || args.ToMachineSpec != "" {
 			return nil, fmt.Errorf("subordinate service must be deployed without units")
@@ -53,6 +70,9 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 		if !constraints.IsEmpty(&args.Constraints) {
 			return nil, fmt.Errorf("subordinate service must be deployed without constraints")
 		}
+		if args.WorkloadContainer != "" && args.WorkloadContainer != instance.NONE {
+			return nil, fmt.Errorf("subordinate service must be deployed without a workload container")
+		}
 	}
 	if args.ServiceOwner == "" {
 		env, err := st.Environment()
@@ -91,6 +111,11 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 			return nil, err
 		}
 	}
+	if args.Channel != "" {
+		if err := service.SetChannel(args.Channel); err != nil {
+			return nil, err
+		}
+	}
 	if args.Charm.Meta().Subordinate {
 		return service, nil
 	}
@@ -99,6 +124,11 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 			return nil, err
 		}
 	}
+	if args.WorkloadContainer != "" && args.WorkloadContainer != instance.NONE {
+		if err := service.SetWorkloadContainer(args.WorkloadContainer); err != nil {
+			return nil, err
+		}
+	}
 	if args.NumUnits > 0 {
 		if _, err := AddUnits(st, service, args.NumUnits, args.ToMachineSpec); err != nil {
 			return nil, err
@@ -108,8 +138,17 @@ func DeployService(st *state.State, args DeployServiceParams) (*state.Service, e
 }
 
 // AddUnits starts n units of the given service and allocates machines
-// to them as necessary.
-func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string) ([]*state.Unit, error) {
+// to them as necessary. If placement is not empty, it is a unit
+// placement directive, as parsed by instance.ParseUnitPlacement, and
+// only one unit may be added.
+func AddUnits(st *state.State, svc *state.Service, n int, placement string) ([]*state.Unit, error) {
+	p, err := unitPlacement(st, placement)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if p != nil && n != 1 {
+		return nil, fmt.Errorf("cannot add multiple units of service %q to a single machine", svc.Name())
+	}
 	units := make([]*state.Unit, n)
 	// Hard code for now till we implement a different approach.
 	policy := state.AssignCleanEmpty
@@ -122,58 +161,14 @@ func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string)
 	for i := 0; i < n; i++ {
 		unit, err := svc.AddUnit()
 		if err != nil {
-			return nil, fmt.Errorf("cannot add unit %d/%d to service %q: %v", i+1, n, svc.Name(), err)
+			return nil, errors.Annotatef(err, "cannot add unit %d/%d to service %q", i+1, n, svc.Name())
 		}
-		if machineIdSpec != "" {
-			if n != 1 {
-				return nil, fmt.Errorf("cannot add multiple units of service %q to a single machine", svc.Name())
-			}
-			// machineIdSpec may be an existing machine or container, eg 3/lxc/2
-			// or a new container on a machine, eg lxc:3
-			mid := machineIdSpec
-			var containerType instance.ContainerType
-			specParts := strings.SplitN(machineIdSpec, ":", 2)
-			if len(specParts) > 1 {
-				firstPart := specParts[0]
-				var err error
-				if containerType, err = instance.ParseContainerType(firstPart); err == nil {
-					mid = specParts[1]
-				} else {
-					mid = machineIdSpec
+		if p != nil {
+			if err := assignUnitToPlacement(st, unit, p, networks); err != nil {
+				// Don't leave behind a unit that was never placed.
+				if err := unit.Destroy(); err != nil {
+					logger.Warningf("cannot remove unplaced unit %q: %v", unit.Name(), err)
 				}
-			}
-			if !names.IsValidMachine(mid) {
-				return nil, fmt.Errorf("invalid force machine id %q", mid)
-			}
-			var unitCons *constraints.Value
-			unitCons, err = unit.Constraints()
-			if err != nil {
-				return nil, err
-			}
-
-			var err error
-			var m *state.Machine
-			// If a container is to be used, create it.
-			if containerType != "" {
-				// Create the new machine marked as dirty so that
-				// nothing else will grab it before we assign the unit to it.
-				template := state.MachineTemplate{
-					Series:            unit.Series(),
-					Jobs:              []state.MachineJob{state.JobHostUnits},
-					Dirty:             true,
-					Constraints:       *unitCons,
-					RequestedNetworks: networks,
-				}
-				m, err = st.AddMachineInsideMachine(template, mid, containerType)
-			} else {
-				m, err = st.Machine(mid)
-			}
-			if err != nil {
-				return nil, fmt.Errorf("cannot assign unit %q to machine: %v", unit.Name(), err)
-			}
-			err = unit.AssignToMachine(m)
-
-			if err != nil {
 				return nil, err
 			}
 		} else if err := st.AssignUnit(unit, policy); err != nil {
@@ -184,6 +179,71 @@ func AddUnits(st *state.State, svc *state.Service, n int, machineIdSpec string)
 	return units, nil
 }
 
+// unitPlacement parses and validates the given unit placement
+// directive, returning nil if it is empty. The scope of a provider
+// directive is checked against the environment, and then cleared.
+func unitPlacement(st *state.State, placement string) (*instance.Placement, error) {
+	p, err := instance.ParseUnitPlacement(placement)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid placement %q", placement)
+	}
+	if p == nil || p.Scope == "" || p.Scope == instance.MachineScope {
+		return p, nil
+	}
+	if _, err := instance.ParseContainerType(p.Scope); err == nil {
+		return p, nil
+	}
+	env, err := st.Environment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if p.Scope != env.Name() && p.Scope != env.UUID() {
+		return nil, errors.Errorf("invalid placement %q: invalid environment name %q", placement, p.Scope)
+	}
+	return &instance.Placement{Directive: p.Directive}, nil
+}
+
+// assignUnitToPlacement assigns the unit to the existing machine or
+// container, new container, or new machine described by placement.
+func assignUnitToPlacement(st *state.State, unit *state.Unit, p *instance.Placement, networks []string) error {
+	if p.Scope == instance.MachineScope {
+		m, err := st.Machine(p.Directive)
+		if err != nil {
+			return fmt.Errorf("cannot assign unit %q to machine: %v", unit.Name(), err)
+		}
+		return unit.AssignToMachine(m)
+	}
+	unitCons, err := unit.Constraints()
+	if err != nil {
+		return err
+	}
+	// Create the new machine marked as dirty so that nothing
+	// else will grab it before we assign the unit to it.
+	template := state.MachineTemplate{
+		Series:            unit.Series(),
+		Jobs:              []state.MachineJob{state.JobHostUnits},
+		Dirty:             true,
+		Constraints:       *unitCons,
+		RequestedNetworks: networks,
+	}
+	var m *state.Machine
+	switch containerType := instance.ContainerType(p.Scope); {
+	case p.Scope == "":
+		// The provider validates its own directives
+		// when the machine is added.
+		template.Placement = p.Directive
+		m, err = st.AddOneMachine(template)
+	case p.Directive != "":
+		m, err = st.AddMachineInsideMachine(template, p.Directive, containerType)
+	default:
+		m, err = st.AddMachineInsideNewMachine(template, template, containerType)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot assign unit %q to machine: %v", unit.Name(), err)
+	}
+	return unit.AssignToMachine(m)
+}
+
 func stateStorageConstraints(cons map[string]storage.Constraints) map[string]state.StorageConstraints {
 	result := make(map[string]state.StorageConstraints)
 	for name, cons := range cons {
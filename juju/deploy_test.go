@@ -129,6 +129,68 @@ func (s *DeployLocalSuite) TestDeployConstraints(c *gc.C) {
 	s.assertConstraints(c, service, serviceCons)
 }
 
+func (s *DeployLocalSuite) TestDeployWorkloadContainer(c *gc.C) {
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:       "bob",
+			Charm:             s.charm,
+			NumUnits:          1,
+			WorkloadContainer: instance.LXC,
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.WorkloadContainer(), gc.Equals, instance.LXC)
+	units, err := service.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+}
+
+func (s *DeployLocalSuite) TestDeployChannel(c *gc.C) {
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName: "bob",
+			Charm:       s.charm,
+			Channel:     state.EdgeChannel,
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.Channel(), gc.Equals, state.EdgeChannel)
+}
+
+func (s *DeployLocalSuite) TestDeployInvalidChannel(c *gc.C) {
+	_, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName: "bob",
+			Charm:       s.charm,
+			Channel:     "beta",
+		})
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+}
+
+func (s *DeployLocalSuite) TestDeployInterpreters(c *gc.C) {
+	curl := charm.MustParseURL("local:quantal/interpreters")
+	ch, err := testing.PutCharm(s.State, curl, s.repo, false)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName: "bob",
+			Charm:       ch,
+		})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *DeployLocalSuite) TestDeployUnavailableInterpreter(c *gc.C) {
+	curl := charm.MustParseURL("local:win2012r2/interpreters-1")
+	ch, err := s.State.AddCharm(testcharms.Repo.CharmDir("interpreters"), curl, "dummy-path", "dummy-1-sha256")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName: "bob",
+			Charm:       ch,
+		})
+	c.Assert(err, gc.ErrorMatches, `cannot deploy charm "local:win2012r2/interpreters-1": interpreter "bash" on series "win2012r2" not supported`)
+	_, err = s.State.Service("bob")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *DeployLocalSuite) TestDeployNumUnits(c *gc.C) {
 	err := s.State.SetEnvironConstraints(constraints.MustParse("mem=2G"))
 	c.Assert(err, jc.ErrorIsNil)
@@ -213,6 +275,73 @@ func (s *DeployLocalSuite) TestDeployForceMachineIdWithContainer(c *gc.C) {
 	c.Assert(machineCons, gc.DeepEquals, *unitCons)
 }
 
+func (s *DeployLocalSuite) TestDeployForceNewContainer(c *gc.C) {
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: string(instance.LXC),
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	units, err := service.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	id, err := units[0].AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id, gc.Equals, "0/lxc/0")
+}
+
+func (s *DeployLocalSuite) TestDeployWithPlacementDirective(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: env.Name() + ":valid",
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	units, err := service.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 1)
+	id, err := units[0].AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.Machine(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Placement(), gc.Equals, "valid")
+}
+
+func (s *DeployLocalSuite) TestDeployWithInvalidPlacementDirective(c *gc.C) {
+	service, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: "zone=nowhere",
+		})
+	c.Assert(err, gc.ErrorMatches, `cannot assign unit "bob/0" to machine: cannot add a new machine: .*zone=nowhere placement is invalid`)
+
+	// The unit that could not be placed is removed.
+	service, err = s.State.Service("bob")
+	c.Assert(err, jc.ErrorIsNil)
+	units, err := service.AllUnits()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(units, gc.HasLen, 0)
+}
+
+func (s *DeployLocalSuite) TestDeployWithPlacementForOtherEnvironment(c *gc.C) {
+	_, err := juju.DeployService(s.State,
+		juju.DeployServiceParams{
+			ServiceName:   "bob",
+			Charm:         s.charm,
+			NumUnits:      1,
+			ToMachineSpec: "elsewhere:valid",
+		})
+	c.Assert(err, gc.ErrorMatches, `invalid placement "elsewhere:valid": invalid environment name "elsewhere"`)
+}
+
 func (s *DeployLocalSuite) assertCharm(c *gc.C, service *state.Service, expect *charm.URL) {
 	curl, force := service.CharmURL()
 	c.Assert(curl, gc.DeepEquals, expect)
@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package paths
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v1"
+
+	"github.com/juju/juju/version"
+)
+
+// Interpreter describes a program that charm hooks may be written for.
+type Interpreter struct {
+	// Name is the name by which charms require the interpreter, in
+	// the interpreters field of their metadata.
+	Name string
+
+	// Extensions holds the file extensions of the hooks the
+	// interpreter runs, on operating systems that choose how to run
+	// a file by its extension.
+	Extensions []string
+
+	// Command holds the command line that runs a hook, to which the
+	// path of the hook is appended. It is empty when the operating
+	// system runs such hooks itself.
+	Command []string
+}
+
+// winInterpreters holds the interpreters available to hooks on
+// Windows, in the order in which hooks are looked for.
+var winInterpreters = []Interpreter{{
+	Name:       "powershell",
+	Extensions: []string{".ps1"},
+	// PowerShell needs a few flags to allow execution
+	// (-ExecutionPolicy) and propagate error levels (-File).
+	Command: []string{"powershell.exe", "-NonInteractive", "-ExecutionPolicy", "RemoteSigned", "-File"},
+}, {
+	Name:       "cmd",
+	Extensions: []string{".cmd", ".bat"},
+}, {
+	Name:       "python",
+	Extensions: []string{".py"},
+	Command:    []string{"python.exe"},
+}}
+
+// nixInterpreters holds the interpreters available to hooks on other
+// operating systems, where hooks name their interpreter on their first
+// line.
+var nixInterpreters = []Interpreter{
+	{Name: "sh"},
+	{Name: "bash"},
+	{Name: "python"},
+	{Name: "python3"},
+	{Name: "perl"},
+}
+
+// Interpreters returns the interpreters that charm hooks may be
+// written for on the given operating system.
+func Interpreters(os version.OSType) []Interpreter {
+	if os == version.Windows {
+		return winInterpreters
+	}
+	return nixInterpreters
+}
+
+// ValidateInterpreters returns an error satisfying errors.IsNotSupported
+// if any of the named interpreters is not available to the hooks of
+// charms deployed on the given series.
+func ValidateInterpreters(series string, names []string) error {
+	os, err := version.GetOSFromSeries(series)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	available := make(map[string]bool)
+	for _, interpreter := range Interpreters(os) {
+		available[interpreter.Name] = true
+	}
+	for _, name := range names {
+		if !available[name] {
+			return errors.NotSupportedf("interpreter %q on series %q", name, series)
+		}
+	}
+	return nil
+}
+
+// ReadInterpreters returns the names of the interpreters required by
+// a charm, as declared in the interpreters field of the charm's
+// metadata read from r.
+func ReadInterpreters(r io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var meta struct {
+		Interpreters []string
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Annotate(err, "cannot parse charm interpreters")
+	}
+	for _, name := range meta.Interpreters {
+		if name == "" {
+			return nil, errors.New("empty interpreter name")
+		}
+	}
+	return meta.Interpreters, nil
+}
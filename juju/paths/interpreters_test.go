@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package paths_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/paths"
+	"github.com/juju/juju/testing"
+)
+
+var _ = gc.Suite(&interpretersSuite{})
+
+type interpretersSuite struct {
+	testing.BaseSuite
+}
+
+func (s *interpretersSuite) TestValidateInterpreters(c *gc.C) {
+	err := paths.ValidateInterpreters("win2012r2", []string{"powershell", "cmd", "python"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = paths.ValidateInterpreters("trusty", []string{"bash", "python"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = paths.ValidateInterpreters("trusty", nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *interpretersSuite) TestValidateInterpretersNotAvailable(c *gc.C) {
+	err := paths.ValidateInterpreters("trusty", []string{"bash", "powershell"})
+	c.Assert(err, gc.ErrorMatches, `interpreter "powershell" on series "trusty" not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+
+	err = paths.ValidateInterpreters("win2012r2", []string{"bash"})
+	c.Assert(err, gc.ErrorMatches, `interpreter "bash" on series "win2012r2" not supported`)
+}
+
+func (s *interpretersSuite) TestValidateInterpretersUnknownSeries(c *gc.C) {
+	err := paths.ValidateInterpreters("minix", []string{"sh"})
+	c.Assert(err, gc.ErrorMatches, `invalid series "minix"`)
+}
+
+func (s *interpretersSuite) TestReadInterpreters(c *gc.C) {
+	names, err := paths.ReadInterpreters(strings.NewReader(`
+name: wordpress
+summary: "blog engine"
+interpreters: [powershell, python]
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(names, gc.DeepEquals, []string{"powershell", "python"})
+
+	names, err = paths.ReadInterpreters(strings.NewReader("name: wordpress\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(names, gc.HasLen, 0)
+}
+
+func (s *interpretersSuite) TestReadInterpretersInvalid(c *gc.C) {
+	_, err := paths.ReadInterpreters(strings.NewReader("interpreters: [powershell, '']\n"))
+	c.Assert(err, gc.ErrorMatches, "empty interpreter name")
+
+	_, err = paths.ReadInterpreters(strings.NewReader("interpreters: [powershell\n"))
+	c.Assert(err, gc.ErrorMatches, "cannot parse charm interpreters: .*")
+}
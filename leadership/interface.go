@@ -30,6 +30,25 @@ type LeadershipManager interface {
 	BlockUntilLeadershipReleased(serviceId string) (err error)
 }
 
+// LeadershipPinner is implemented by leadership managers that can
+// pin a service's leadership to its current leader, so that it does
+// not change hands during maintenance operations such as series
+// upgrades and backups.
+type LeadershipPinner interface {
+	// PinLeadership pins the leadership of the given service on
+	// behalf of the given entity for the supplied duration, or
+	// until the entity unpins it.
+	PinLeadership(serviceId, entity string, duration time.Duration) error
+
+	// UnpinLeadership removes the given entity's pin on the
+	// leadership of the given service.
+	UnpinLeadership(serviceId, entity string) error
+
+	// LeadershipPins returns the time until which each entity has
+	// pinned the leadership of the given service.
+	LeadershipPins(serviceId string) map[string]time.Time
+}
+
 type LeadershipLeaseManager interface {
 	// Claimlease claims a lease for the given duration for the given
 	// namespace and id. If the lease is already owned, a
@@ -51,3 +70,19 @@ type LeadershipLeaseManager interface {
 	// "notificationTimeout".
 	LeaseReleasedNotifier(namespace string) (notifier <-chan struct{})
 }
+
+// LeadershipLeasePinner is implemented by lease managers whose
+// leases can be pinned.
+type LeadershipLeasePinner interface {
+	// PinLease pins the lease for namespace on behalf of entity,
+	// so that its holder keeps it for at least the given duration.
+	// The lease holder's ID is returned.
+	PinLease(namespace, entity string, forDur time.Duration) (leaseOwnerId string, err error)
+
+	// UnpinLease removes entity's pin from the lease for namespace.
+	UnpinLease(namespace, entity string) error
+
+	// LeasePins returns the time until which each entity has
+	// pinned the lease for namespace.
+	LeasePins(namespace string) map[string]time.Time
+}
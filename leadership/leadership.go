@@ -34,6 +34,12 @@ func (m *Manager) Leader(sid, uid string) bool {
 	return tok.Id == uid
 }
 
+// ServiceLeader returns the id of the unit which is currently the
+// leader of the given service, or "" if there is none.
+func (m *Manager) ServiceLeader(sid string) string {
+	return m.leaseMgr.RetrieveLease(leadershipNamespace(sid)).Id
+}
+
 // ClaimLeadership implements the LeadershipManager interface.
 func (m *Manager) ClaimLeadership(sid, uid string, duration time.Duration) error {
 
@@ -61,6 +67,52 @@ func (m *Manager) BlockUntilLeadershipReleased(serviceId string) error {
 	return nil
 }
 
+// PinLeadership implements the LeadershipPinner interface. If the
+// service has no leader, an error satisfying errors.IsNotFound is
+// returned.
+func (m *Manager) PinLeadership(sid, entity string, duration time.Duration) error {
+	pinner, err := m.pinner()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = pinner.PinLease(leadershipNamespace(sid), entity, duration)
+	if errors.IsNotFound(err) {
+		return errors.NotFoundf("leader of service %q", sid)
+	} else if err != nil {
+		return errors.Annotate(err, "unable to pin leadership")
+	}
+	return nil
+}
+
+// UnpinLeadership implements the LeadershipPinner interface.
+func (m *Manager) UnpinLeadership(sid, entity string) error {
+	pinner, err := m.pinner()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := pinner.UnpinLease(leadershipNamespace(sid), entity); err != nil {
+		return errors.Annotate(err, "unable to unpin leadership")
+	}
+	return nil
+}
+
+// LeadershipPins implements the LeadershipPinner interface.
+func (m *Manager) LeadershipPins(sid string) map[string]time.Time {
+	pinner, err := m.pinner()
+	if err != nil {
+		return nil
+	}
+	return pinner.LeasePins(leadershipNamespace(sid))
+}
+
+func (m *Manager) pinner() (LeadershipLeasePinner, error) {
+	pinner, ok := m.leaseMgr.(LeadershipLeasePinner)
+	if !ok {
+		return nil, errors.NotSupportedf("pinning leadership")
+	}
+	return pinner, nil
+}
+
 func leadershipNamespace(serviceId string) string {
 	return serviceId + leadershipNamespaceSuffix
 }
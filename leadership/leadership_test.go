@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -23,6 +24,8 @@ const (
 var (
 	_                        = gc.Suite(&leadershipSuite{})
 	_ LeadershipLeaseManager = (*leaseStub)(nil)
+	_ LeadershipLeasePinner  = (*pinningLeaseStub)(nil)
+	_ LeadershipPinner       = (*Manager)(nil)
 )
 
 type leadershipSuite struct{}
@@ -121,3 +124,119 @@ func (s *leadershipSuite) TestBlockUntilLeadershipReleasedTranslation(c *gc.C) {
 	c.Check(numStubCalls, gc.Equals, 1)
 	c.Check(err, jc.ErrorIsNil)
 }
+
+type pinningLeaseStub struct {
+	leaseStub
+	PinLeaseFn   func(string, string, time.Duration) (string, error)
+	UnpinLeaseFn func(string, string) error
+	LeasePinsFn  func(string) map[string]time.Time
+}
+
+func (s *pinningLeaseStub) PinLease(namespace, entity string, forDur time.Duration) (string, error) {
+	if s.PinLeaseFn != nil {
+		return s.PinLeaseFn(namespace, entity, forDur)
+	}
+	return "", nil
+}
+
+func (s *pinningLeaseStub) UnpinLease(namespace, entity string) error {
+	if s.UnpinLeaseFn != nil {
+		return s.UnpinLeaseFn(namespace, entity)
+	}
+	return nil
+}
+
+func (s *pinningLeaseStub) LeasePins(namespace string) map[string]time.Time {
+	if s.LeasePinsFn != nil {
+		return s.LeasePinsFn(namespace)
+	}
+	return nil
+}
+
+func (s *leadershipSuite) TestServiceLeader(c *gc.C) {
+	stub := &leaseStub{
+		RetrieveLeaseFn: func(namespace string) lease.Token {
+			c.Check(namespace, gc.Equals, leadershipNamespace(StubServiceNm))
+			return lease.Token{Namespace: namespace, Id: StubUnitNm}
+		},
+	}
+
+	leaderMgr := NewLeadershipManager(stub)
+	c.Check(leaderMgr.ServiceLeader(StubServiceNm), gc.Equals, StubUnitNm)
+}
+
+func (s *leadershipSuite) TestPinLeadershipTranslation(c *gc.C) {
+
+	numStubCalls := 0
+	stub := &pinningLeaseStub{
+		PinLeaseFn: func(namespace, entity string, forDur time.Duration) (string, error) {
+			numStubCalls++
+			c.Check(namespace, gc.Equals, leadershipNamespace(StubServiceNm))
+			c.Check(entity, gc.Equals, "user-admin")
+			c.Check(forDur, gc.Equals, time.Hour)
+			return StubUnitNm, nil
+		},
+	}
+
+	leaderMgr := NewLeadershipManager(stub)
+	err := leaderMgr.PinLeadership(StubServiceNm, "user-admin", time.Hour)
+
+	c.Check(numStubCalls, gc.Equals, 1)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *leadershipSuite) TestPinLeadershipNoLeader(c *gc.C) {
+	stub := &pinningLeaseStub{
+		PinLeaseFn: func(namespace, entity string, forDur time.Duration) (string, error) {
+			return "", errors.NotFoundf("lease for namespace %q", namespace)
+		},
+	}
+
+	leaderMgr := NewLeadershipManager(stub)
+	err := leaderMgr.PinLeadership(StubServiceNm, "user-admin", time.Hour)
+	c.Check(err, gc.ErrorMatches, `leader of service "stub-service" not found`)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *leadershipSuite) TestPinLeadershipNotSupported(c *gc.C) {
+	leaderMgr := NewLeadershipManager(&leaseStub{})
+	err := leaderMgr.PinLeadership(StubServiceNm, "user-admin", time.Hour)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	err = leaderMgr.UnpinLeadership(StubServiceNm, "user-admin")
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+	c.Check(leaderMgr.LeadershipPins(StubServiceNm), gc.HasLen, 0)
+}
+
+func (s *leadershipSuite) TestUnpinLeadershipTranslation(c *gc.C) {
+
+	numStubCalls := 0
+	stub := &pinningLeaseStub{
+		UnpinLeaseFn: func(namespace, entity string) error {
+			numStubCalls++
+			c.Check(namespace, gc.Equals, leadershipNamespace(StubServiceNm))
+			c.Check(entity, gc.Equals, "user-admin")
+			return nil
+		},
+	}
+
+	leaderMgr := NewLeadershipManager(stub)
+	err := leaderMgr.UnpinLeadership(StubServiceNm, "user-admin")
+
+	c.Check(numStubCalls, gc.Equals, 1)
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *leadershipSuite) TestLeadershipPinsTranslation(c *gc.C) {
+	expiry := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	stub := &pinningLeaseStub{
+		LeasePinsFn: func(namespace string) map[string]time.Time {
+			c.Check(namespace, gc.Equals, leadershipNamespace(StubServiceNm))
+			return map[string]time.Time{"user-admin": expiry}
+		},
+	}
+
+	leaderMgr := NewLeadershipManager(stub)
+	c.Check(leaderMgr.LeadershipPins(StubServiceNm), jc.DeepEquals, map[string]time.Time{
+		"user-admin": expiry,
+	})
+}
@@ -4,6 +4,7 @@
 package lease
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -192,17 +193,21 @@ func (m *leaseManager) workerLoop(stop <-chan struct{}) error {
 		case claim := <-m.claimLease:
 			lease := claimLease(leaseCache, claim.Token)
 			if lease.Id == claim.Token.Id {
+				atomic.AddInt64(&stats.Claims, 1)
 				// TODO(fwereade): we should *definitely* not be ignoring this error.
 				m.leasePersistor.WriteToken(lease.Namespace, lease)
 				if lease.Expiration.Before(nextExpiration) {
 					nextExpiration = lease.Expiration
 				}
+			} else {
+				atomic.AddInt64(&stats.ClaimsDenied, 1)
 			}
 			claim.Response <- lease
 		case release := <-m.releaseLease:
 			// Unwind our layers from most volatile to least.
 			err := releaseLease(leaseCache, release.Token)
 			if err == nil {
+				atomic.AddInt64(&stats.Releases, 1)
 				namespace := release.Token.Namespace
 				err = m.leasePersistor.RemoveToken(namespace)
 				// TODO(fwereade): if the above error is non-nil, we should
@@ -249,6 +254,7 @@ func (m *leaseManager) expireLeases(
 			// killing the main loop.
 			logger.Errorf("Failed to release expired lease for namespace %q: %v", token.Namespace, err)
 		} else {
+			atomic.AddInt64(&stats.Expiries, 1)
 			notifyOfRelease(subscribers[token.Namespace], token.Namespace)
 		}
 	}
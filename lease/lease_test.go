@@ -203,6 +203,27 @@ func (s *leaseSuite) TestReleaseLease(c *gc.C) {
 	c.Assert(toks, gc.HasLen, 0)
 }
 
+func (s *leaseSuite) TestStats(c *gc.C) {
+	stop := make(chan struct{})
+	go WorkerLoop(&stubLeasePersistor{})(stop)
+	defer func() { stop <- struct{}{} }()
+	mgr := Manager()
+	before := CurrentStats()
+
+	_, err := mgr.ClaimLease(testNamespace, testId, testDuration)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = mgr.ClaimLease(testNamespace, "other/0", testDuration)
+	c.Assert(err, gc.Equals, LeaseClaimDeniedErr)
+	err = mgr.ReleaseLease(testNamespace, testId)
+	c.Assert(err, jc.ErrorIsNil)
+
+	after := CurrentStats()
+	c.Check(after.Claims-before.Claims, gc.Equals, int64(1))
+	c.Check(after.ClaimsDenied-before.ClaimsDenied, gc.Equals, int64(1))
+	c.Check(after.Releases-before.Releases, gc.Equals, int64(1))
+	c.Check(after.Expiries-before.Expiries, gc.Equals, int64(0))
+}
+
 func (s *leaseSuite) TestReleaseLeaseRaces(c *gc.C) {
 	stop := make(chan struct{})
 	go WorkerLoop(&stubLeasePersistor{})(stop)
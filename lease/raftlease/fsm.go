@@ -0,0 +1,270 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/lease"
+)
+
+const (
+	opClaim   = "claim"
+	opRelease = "release"
+	opPin     = "pin"
+	opUnpin   = "unpin"
+)
+
+// command is a change to the leases, replicated by raft. Every field
+// is chosen by the proposer so that all nodes apply it identically.
+// For pin and unpin operations, Id identifies the entity pinning the
+// lease rather than the lease holder.
+type command struct {
+	Operation  string    `json:"operation"`
+	Namespace  string    `json:"namespace"`
+	Id         string    `json:"id"`
+	Expiration time.Time `json:"expiration,omitempty"`
+
+	// Now holds the proposer's clock when the command was
+	// proposed, against which existing leases are checked.
+	Now time.Time `json:"now"`
+}
+
+// commandResult is the result of applying a command.
+type commandResult struct {
+	// Owner holds the id of the lease holder after a claim.
+	Owner string `json:"owner,omitempty"`
+
+	// NotOwner is set when a release was made by an id which did
+	// not hold the lease.
+	NotOwner bool `json:"not-owner,omitempty"`
+
+	// NotHeld is set when a pin was requested for a lease
+	// which is not held.
+	NotHeld bool `json:"not-held,omitempty"`
+
+	// Pinned is set when a release was made by the lease holder
+	// while the lease was pinned, and so was not applied.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Error holds a description of a command that could not be
+	// applied at all.
+	Error string `json:"error,omitempty"`
+}
+
+// FSM holds the leases as a raft state machine.
+//
+// A lease may be pinned by any number of entities, each until a time
+// of its choosing. While a lease is pinned its holder keeps it, even
+// if the holder fails to extend it or asks to release it, so that
+// maintenance operations can rely on it not moving.
+type FSM struct {
+	released func(namespace string)
+
+	mu     sync.Mutex
+	tokens map[string]lease.Token
+
+	// pins holds, for each pinned namespace, the time until
+	// which each pinning entity has pinned it.
+	pins map[string]map[string]time.Time
+}
+
+// NewFSM returns an empty FSM which calls released, if not nil,
+// whenever a lease is released.
+func NewFSM(released func(namespace string)) *FSM {
+	return &FSM{
+		released: released,
+		tokens:   make(map[string]lease.Token),
+		pins:     make(map[string]map[string]time.Time),
+	}
+}
+
+// Apply is part of the raft.FSM interface.
+func (f *FSM) Apply(data []byte) []byte {
+	var cmd command
+	var result commandResult
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		result.Error = err.Error()
+		return marshalResult(result)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expirePins(cmd.Namespace, cmd.Now)
+	active, ok := f.tokens[cmd.Namespace]
+	if ok {
+		active = f.pinned(active)
+	}
+	switch cmd.Operation {
+	case opClaim:
+		if ok && active.Id != cmd.Id && active.Expiration.After(cmd.Now) {
+			result.Owner = active.Id
+			break
+		}
+		f.tokens[cmd.Namespace] = lease.Token{
+			Namespace:  cmd.Namespace,
+			Id:         cmd.Id,
+			Expiration: cmd.Expiration,
+		}
+		result.Owner = cmd.Id
+		if !ok || active.Id != cmd.Id {
+			logger.Infof("%q obtained lease for %q", cmd.Id, cmd.Namespace)
+		}
+	case opRelease:
+		if !ok || active.Id != cmd.Id {
+			result.NotOwner = true
+			break
+		}
+		if len(f.pins[cmd.Namespace]) > 0 {
+			result.Pinned = true
+			break
+		}
+		delete(f.tokens, cmd.Namespace)
+		logger.Infof("%q released lease for namespace %q", cmd.Id, cmd.Namespace)
+		if f.released != nil {
+			f.released(cmd.Namespace)
+		}
+	case opPin:
+		if !ok || !active.Expiration.After(cmd.Now) {
+			result.NotHeld = true
+			break
+		}
+		pins := f.pins[cmd.Namespace]
+		if pins == nil {
+			pins = make(map[string]time.Time)
+			f.pins[cmd.Namespace] = pins
+		}
+		pins[cmd.Id] = cmd.Expiration
+		result.Owner = active.Id
+		logger.Infof("%q pinned lease for namespace %q held by %q", cmd.Id, cmd.Namespace, active.Id)
+	case opUnpin:
+		if _, pinned := f.pins[cmd.Namespace][cmd.Id]; pinned {
+			delete(f.pins[cmd.Namespace], cmd.Id)
+			if len(f.pins[cmd.Namespace]) == 0 {
+				delete(f.pins, cmd.Namespace)
+			}
+			logger.Infof("%q unpinned lease for namespace %q", cmd.Id, cmd.Namespace)
+		}
+	default:
+		result.Error = "unknown lease operation " + cmd.Operation
+	}
+	return marshalResult(result)
+}
+
+// expirePins forgets the pins of the given namespace which have
+// expired by the given time. It must be called with f.mu held.
+func (f *FSM) expirePins(namespace string, now time.Time) {
+	pins := f.pins[namespace]
+	for id, expiration := range pins {
+		if !expiration.After(now) {
+			delete(pins, id)
+		}
+	}
+	if pins != nil && len(pins) == 0 {
+		delete(f.pins, namespace)
+	}
+}
+
+// pinned returns the token with its expiration extended to the
+// latest time until which it is pinned. It must be called with
+// f.mu held.
+func (f *FSM) pinned(token lease.Token) lease.Token {
+	for _, expiration := range f.pins[token.Namespace] {
+		if expiration.After(token.Expiration) {
+			token.Expiration = expiration
+		}
+	}
+	return token
+}
+
+func marshalResult(result commandResult) []byte {
+	data, err := json.Marshal(result)
+	if err != nil {
+		// A commandResult always marshals.
+		panic(err)
+	}
+	return data
+}
+
+// snapshot holds the state of an FSM as recorded in a raft snapshot.
+type snapshot struct {
+	Tokens map[string]lease.Token          `json:"tokens"`
+	Pins   map[string]map[string]time.Time `json:"pins,omitempty"`
+}
+
+// Snapshot is part of the raft.FSM interface.
+func (f *FSM) Snapshot() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, err := json.Marshal(snapshot{
+		Tokens: f.tokens,
+		Pins:   f.pins,
+	})
+	return data, errors.Trace(err)
+}
+
+// Restore is part of the raft.FSM interface.
+func (f *FSM) Restore(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return errors.Annotate(err, "cannot parse lease snapshot")
+	}
+	if snap.Tokens == nil {
+		// Snapshots taken before leases could be pinned
+		// hold just the tokens.
+		if err := json.Unmarshal(data, &snap.Tokens); err != nil {
+			return errors.Annotate(err, "cannot parse lease snapshot")
+		}
+	}
+	if snap.Pins == nil {
+		snap.Pins = make(map[string]map[string]time.Time)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = snap.Tokens
+	f.pins = snap.Pins
+	return nil
+}
+
+// Token returns the lease held for the given namespace, whether or
+// not it has expired. The expiration of a pinned lease is extended
+// to the time until which it is pinned.
+func (f *FSM) Token(namespace string) (lease.Token, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.tokens[namespace]
+	if !ok {
+		return lease.Token{}, false
+	}
+	return f.pinned(token), true
+}
+
+// Tokens returns all the leases held, whether or not they have
+// expired, with the expirations of pinned leases extended as
+// for Token.
+func (f *FSM) Tokens() []lease.Token {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens := make([]lease.Token, 0, len(f.tokens))
+	for _, token := range f.tokens {
+		tokens = append(tokens, f.pinned(token))
+	}
+	return tokens
+}
+
+// Pins returns the time until which each entity has pinned the lease
+// for the given namespace, including pins which have expired but
+// have not yet been forgotten.
+func (f *FSM) Pins(namespace string) map[string]time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pins := make(map[string]time.Time)
+	for id, expiration := range f.pins[namespace] {
+		pins[id] = expiration
+	}
+	return pins
+}
@@ -0,0 +1,258 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	"encoding/json"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/lease"
+	"github.com/juju/juju/lease/raftlease"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type fsmSuite struct {
+	coretesting.BaseSuite
+
+	now      time.Time
+	released []string
+	fsm      *raftlease.FSM
+}
+
+var _ = gc.Suite(&fsmSuite{})
+
+func (s *fsmSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.released = nil
+	s.fsm = raftlease.NewFSM(func(namespace string) {
+		s.released = append(s.released, namespace)
+	})
+}
+
+func (s *fsmSuite) apply(c *gc.C, cmd map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(cmd)
+	c.Assert(err, jc.ErrorIsNil)
+	var result map[string]interface{}
+	err = json.Unmarshal(s.fsm.Apply(data), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	return result
+}
+
+func (s *fsmSuite) claim(c *gc.C, namespace, id string, at time.Time, forDur time.Duration) map[string]interface{} {
+	return s.apply(c, map[string]interface{}{
+		"operation":  "claim",
+		"namespace":  namespace,
+		"id":         id,
+		"expiration": at.Add(forDur),
+		"now":        at,
+	})
+}
+
+func (s *fsmSuite) release(c *gc.C, namespace, id string) map[string]interface{} {
+	return s.apply(c, map[string]interface{}{
+		"operation": "release",
+		"namespace": namespace,
+		"id":        id,
+		"now":       s.now,
+	})
+}
+
+func (s *fsmSuite) pin(c *gc.C, namespace, entity string, at time.Time, forDur time.Duration) map[string]interface{} {
+	return s.apply(c, map[string]interface{}{
+		"operation":  "pin",
+		"namespace":  namespace,
+		"id":         entity,
+		"expiration": at.Add(forDur),
+		"now":        at,
+	})
+}
+
+func (s *fsmSuite) unpin(c *gc.C, namespace, entity string) map[string]interface{} {
+	return s.apply(c, map[string]interface{}{
+		"operation": "unpin",
+		"namespace": namespace,
+		"id":        entity,
+		"now":       s.now,
+	})
+}
+
+func (s *fsmSuite) TestClaim(c *gc.C) {
+	result := s.claim(c, "ns", "a", s.now, time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "a"})
+	token, ok := s.fsm.Token("ns")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(token.Id, gc.Equals, "a")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Minute)), jc.IsTrue)
+}
+
+func (s *fsmSuite) TestClaimHeldByOther(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.claim(c, "ns", "b", s.now.Add(time.Second), time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "a"})
+	token, _ := s.fsm.Token("ns")
+	c.Assert(token.Id, gc.Equals, "a")
+}
+
+func (s *fsmSuite) TestClaimExtends(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.claim(c, "ns", "a", s.now.Add(time.Second), time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "a"})
+	token, _ := s.fsm.Token("ns")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Second+time.Minute)), jc.IsTrue)
+}
+
+func (s *fsmSuite) TestClaimExpired(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.claim(c, "ns", "b", s.now.Add(time.Minute), time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "b"})
+}
+
+func (s *fsmSuite) TestRelease(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.release(c, "ns", "a")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{})
+	_, ok := s.fsm.Token("ns")
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(s.released, jc.DeepEquals, []string{"ns"})
+}
+
+func (s *fsmSuite) TestReleaseNotOwner(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.release(c, "ns", "b")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"not-owner": true})
+	result = s.release(c, "other", "a")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"not-owner": true})
+	c.Assert(s.released, gc.HasLen, 0)
+}
+
+func (s *fsmSuite) TestPin(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result := s.pin(c, "ns", "user-admin", s.now, time.Hour)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "a"})
+	token, _ := s.fsm.Token("ns")
+	c.Assert(token.Id, gc.Equals, "a")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Hour)), jc.IsTrue)
+	pins := s.fsm.Pins("ns")
+	c.Assert(pins, gc.HasLen, 1)
+	c.Assert(pins["user-admin"].Equal(s.now.Add(time.Hour)), jc.IsTrue)
+}
+
+func (s *fsmSuite) TestPinNotHeld(c *gc.C) {
+	result := s.pin(c, "ns", "user-admin", s.now, time.Hour)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"not-held": true})
+
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	result = s.pin(c, "ns", "user-admin", s.now.Add(time.Minute), time.Hour)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"not-held": true})
+	c.Assert(s.fsm.Pins("ns"), gc.HasLen, 0)
+}
+
+func (s *fsmSuite) TestPinnedClaimDenied(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	s.pin(c, "ns", "user-admin", s.now, time.Hour)
+
+	// The holder's own lease has expired, but the pin keeps it.
+	result := s.claim(c, "ns", "b", s.now.Add(2*time.Minute), time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "a"})
+
+	// Once the pin expires, the lease can be claimed again.
+	result = s.claim(c, "ns", "b", s.now.Add(time.Hour), time.Minute)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"owner": "b"})
+	c.Assert(s.fsm.Pins("ns"), gc.HasLen, 0)
+}
+
+func (s *fsmSuite) TestPinnedReleaseIgnored(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	s.pin(c, "ns", "user-admin", s.now, time.Hour)
+	result := s.release(c, "ns", "a")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"pinned": true})
+	token, ok := s.fsm.Token("ns")
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(token.Id, gc.Equals, "a")
+	c.Assert(s.released, gc.HasLen, 0)
+}
+
+func (s *fsmSuite) TestUnpin(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	s.pin(c, "ns", "user-admin", s.now, time.Hour)
+	s.pin(c, "ns", "machine-0", s.now, 2*time.Hour)
+
+	result := s.unpin(c, "ns", "machine-0")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{})
+	token, _ := s.fsm.Token("ns")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Hour)), jc.IsTrue)
+
+	s.unpin(c, "ns", "user-admin")
+	c.Assert(s.fsm.Pins("ns"), gc.HasLen, 0)
+	token, _ = s.fsm.Token("ns")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Minute)), jc.IsTrue)
+
+	// Unpinning a lease that isn't pinned is a no-op.
+	result = s.unpin(c, "ns", "user-admin")
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{})
+}
+
+func (s *fsmSuite) TestUnknownOperation(c *gc.C) {
+	result := s.apply(c, map[string]interface{}{"operation": "frob"})
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"error": "unknown lease operation frob"})
+}
+
+func (s *fsmSuite) TestSnapshotRestore(c *gc.C) {
+	s.claim(c, "ns1", "a", s.now, time.Minute)
+	s.claim(c, "ns2", "b", s.now, time.Hour)
+	snapshot, err := s.fsm.Snapshot()
+	c.Assert(err, jc.ErrorIsNil)
+
+	restored := raftlease.NewFSM(nil)
+	err = restored.Restore(snapshot)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(restored.Tokens(), jc.SameContents, []lease.Token{{
+		Namespace:  "ns1",
+		Id:         "a",
+		Expiration: s.now.Add(time.Minute),
+	}, {
+		Namespace:  "ns2",
+		Id:         "b",
+		Expiration: s.now.Add(time.Hour),
+	}})
+}
+
+func (s *fsmSuite) TestSnapshotRestorePins(c *gc.C) {
+	s.claim(c, "ns", "a", s.now, time.Minute)
+	s.pin(c, "ns", "user-admin", s.now, time.Hour)
+	snapshot, err := s.fsm.Snapshot()
+	c.Assert(err, jc.ErrorIsNil)
+
+	restored := raftlease.NewFSM(nil)
+	err = restored.Restore(snapshot)
+	c.Assert(err, jc.ErrorIsNil)
+	pins := restored.Pins("ns")
+	c.Assert(pins, gc.HasLen, 1)
+	c.Assert(pins["user-admin"].Equal(s.now.Add(time.Hour)), jc.IsTrue)
+	token, _ := restored.Token("ns")
+	c.Assert(token.Expiration.Equal(s.now.Add(time.Hour)), jc.IsTrue)
+}
+
+func (s *fsmSuite) TestRestoreUnpinnedSnapshot(c *gc.C) {
+	// Snapshots taken before leases could be pinned
+	// hold just the tokens.
+	snapshot, err := json.Marshal(map[string]lease.Token{
+		"ns": {Namespace: "ns", Id: "a", Expiration: s.now},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	restored := raftlease.NewFSM(nil)
+	err = restored.Restore(snapshot)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(restored.Tokens(), jc.DeepEquals, []lease.Token{{
+		Namespace:  "ns",
+		Id:         "a",
+		Expiration: s.now,
+	}})
+	c.Assert(restored.Pins("ns"), gc.HasLen, 0)
+}
@@ -0,0 +1,402 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The raftlease package provides a lease manager whose leases are
+// held in a raft cluster formed by the state servers, rather than in
+// the database. Claims are committed once a majority of the state
+// servers have recorded them, and every state server holds a replica
+// of the leases which it can read without any network round trip.
+package raftlease
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/lease"
+	"github.com/juju/juju/raft"
+)
+
+var (
+	logger    = loggo.GetLogger("juju.lease.raftlease")
+	singleton = newLeaseManager()
+)
+
+var (
+	// proposeTimeout is how long the manager will try to commit a
+	// change to the leases before giving up.
+	proposeTimeout = 30 * time.Second
+
+	// retryDelay is how long the manager waits before proposing a
+	// change again after failing to reach the leader.
+	retryDelay = 100 * time.Millisecond
+
+	// expiryCheckInterval is how often the leases are checked for
+	// expiry, so that subscribers can be notified.
+	expiryCheckInterval = time.Second
+
+	// notificationTimeout is how long a release notification waits
+	// to be received before it is dropped.
+	notificationTimeout = time.Minute
+)
+
+// ErrNotRunning is returned when the lease service is not running on
+// this state server.
+var ErrNotRunning = errors.New("lease service not running")
+
+// Node is the part of a *raft.Node used by the manager.
+type Node interface {
+	Id() string
+	Leader() string
+	Propose(command []byte, timeout time.Duration) ([]byte, error)
+	RequestVote(args raft.RequestVoteArgs) (raft.RequestVoteResult, error)
+	AppendEntries(args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error)
+	InstallSnapshot(args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error)
+}
+
+// Forwarder proposes commands to the leader on behalf of a node which
+// is not the leader.
+type Forwarder interface {
+	Propose(peer string, command []byte) ([]byte, error)
+}
+
+// service holds the running parts of the lease service.
+type service struct {
+	node      Node
+	fsm       *FSM
+	forwarder Forwarder
+}
+
+type leaseManager struct {
+	mu      sync.Mutex
+	service *service
+
+	// changed is closed and replaced whenever the service is
+	// attached or detached.
+	changed chan struct{}
+
+	releaseSubs map[string][]chan<- struct{}
+
+	// expired records the expiry times of the leases for which
+	// subscribers have already been notified of expiry.
+	expired map[string]time.Time
+}
+
+func newLeaseManager() *leaseManager {
+	return &leaseManager{
+		changed:     make(chan struct{}),
+		releaseSubs: make(map[string][]chan<- struct{}),
+		expired:     make(map[string]time.Time),
+	}
+}
+
+// Manager returns the lease manager of this state server. It
+// implements leadership.LeadershipLeaseManager, and blocks requests
+// until the lease service has been attached to it.
+func Manager() *leaseManager {
+	return singleton
+}
+
+// Attach makes the manager use the given raft node to serve leases,
+// replacing any previously attached. The node's FSM must be fsm,
+// which should have been created with the manager's Released
+// method as its callback. Commands proposed while the node is not the
+// leader are passed to forwarder.
+//
+// The returned function detaches the node again; it must be called
+// before the node is stopped.
+func (m *leaseManager) Attach(node Node, fsm *FSM, forwarder Forwarder) (detach func()) {
+	svc := &service{
+		node:      node,
+		fsm:       fsm,
+		forwarder: forwarder,
+	}
+	m.mu.Lock()
+	m.service = svc
+	m.expired = make(map[string]time.Time)
+	m.notifyChanged()
+	m.mu.Unlock()
+	logger.Infof("lease service started on raft node %q", node.Id())
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.expiryLoop(fsm, stop)
+	}()
+	return func() {
+		close(stop)
+		<-done
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.service == svc {
+			m.service = nil
+			m.notifyChanged()
+		}
+	}
+}
+
+// notifyChanged must be called with m.mu held.
+func (m *leaseManager) notifyChanged() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// Node returns the raft node of the lease service, or ErrNotRunning
+// if none is attached.
+func (m *leaseManager) Node() (Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.service == nil {
+		return nil, ErrNotRunning
+	}
+	return m.service.node, nil
+}
+
+// current returns the attached service, waiting until the given time
+// for one to be attached.
+func (m *leaseManager) current(deadline time.Time) (*service, error) {
+	for {
+		m.mu.Lock()
+		svc, changed := m.service, m.changed
+		m.mu.Unlock()
+		if svc != nil {
+			return svc, nil
+		}
+		select {
+		case <-changed:
+		case <-time.After(deadline.Sub(time.Now())):
+			return nil, ErrNotRunning
+		}
+	}
+}
+
+// propose commits the given command, retrying through changes of
+// leader until proposeTimeout has passed.
+func (m *leaseManager) propose(cmd command) (commandResult, error) {
+	var result commandResult
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	deadline := time.Now().Add(proposeTimeout)
+	for {
+		svc, err := m.current(deadline)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		resultData, err := svc.node.Propose(data, deadline.Sub(time.Now()))
+		if raft.IsNotLeader(err) {
+			leader := errors.Cause(err).(*raft.NotLeaderError).Leader
+			if leader != "" && svc.forwarder != nil {
+				resultData, err = svc.forwarder.Propose(leader, data)
+			}
+		}
+		if err == nil {
+			if err := json.Unmarshal(resultData, &result); err != nil {
+				return result, errors.Annotate(err, "cannot parse lease command result")
+			}
+			if result.Error != "" {
+				return result, errors.New(result.Error)
+			}
+			return result, nil
+		}
+		if time.Now().Add(retryDelay).After(deadline) {
+			return result, errors.Annotate(err, "cannot commit lease change")
+		}
+		logger.Debugf("retrying lease change: %v", err)
+		time.Sleep(retryDelay)
+	}
+}
+
+// ClaimLease claims a lease for the given duration for the given
+// namespace and id. If the lease is already owned, a
+// lease.LeaseClaimDeniedErr will be returned. Either way the current
+// lease owner's ID will be returned.
+func (m *leaseManager) ClaimLease(namespace, id string, forDur time.Duration) (leaseOwnerId string, err error) {
+	now := time.Now()
+	result, err := m.propose(command{
+		Operation:  opClaim,
+		Namespace:  namespace,
+		Id:         id,
+		Expiration: now.Add(forDur),
+		Now:        now,
+	})
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot claim lease for namespace %q", namespace)
+	}
+	if result.Owner != id {
+		return result.Owner, lease.LeaseClaimDeniedErr
+	}
+	return id, nil
+}
+
+// ReleaseLease releases the lease held for namespace by id.
+func (m *leaseManager) ReleaseLease(namespace, id string) error {
+	result, err := m.propose(command{
+		Operation: opRelease,
+		Namespace: namespace,
+		Id:        id,
+		Now:       time.Now(),
+	})
+	if err != nil {
+		return errors.Annotatef(err, "could not release lease for namespace %q, id %q", namespace, id)
+	}
+	if result.NotOwner {
+		// As with the database-backed manager, releasing a lease
+		// that isn't held is logged but is otherwise a no-op.
+		logger.Warningf("could not release lease for namespace %q, id %q: %v", namespace, id, lease.NotLeaseOwnerErr)
+	}
+	if result.Pinned {
+		logger.Infof("not releasing lease for namespace %q, id %q: lease is pinned", namespace, id)
+	}
+	return nil
+}
+
+// PinLease pins the lease for the given namespace on behalf of the
+// given entity, so that its current holder keeps it for at least the
+// given duration, or until the entity unpins it. The lease's holder
+// is returned. If the lease is not held, an error satisfying
+// errors.IsNotFound is returned.
+func (m *leaseManager) PinLease(namespace, entity string, forDur time.Duration) (leaseOwnerId string, err error) {
+	now := time.Now()
+	result, err := m.propose(command{
+		Operation:  opPin,
+		Namespace:  namespace,
+		Id:         entity,
+		Expiration: now.Add(forDur),
+		Now:        now,
+	})
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot pin lease for namespace %q", namespace)
+	}
+	if result.NotHeld {
+		return "", errors.NotFoundf("lease for namespace %q", namespace)
+	}
+	return result.Owner, nil
+}
+
+// UnpinLease removes the given entity's pin from the lease for the
+// given namespace. Unpinning a lease which the entity has not
+// pinned is a no-op.
+func (m *leaseManager) UnpinLease(namespace, entity string) error {
+	_, err := m.propose(command{
+		Operation: opUnpin,
+		Namespace: namespace,
+		Id:        entity,
+		Now:       time.Now(),
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot unpin lease for namespace %q", namespace)
+	}
+	return nil
+}
+
+// LeasePins returns the time until which each entity has pinned the
+// lease for the given namespace, as known to this state server.
+// Expired pins are not included.
+func (m *leaseManager) LeasePins(namespace string) map[string]time.Time {
+	pins := make(map[string]time.Time)
+	m.mu.Lock()
+	svc := m.service
+	m.mu.Unlock()
+	if svc == nil {
+		return pins
+	}
+	now := time.Now()
+	for entity, expiration := range svc.fsm.Pins(namespace) {
+		if expiration.After(now) {
+			pins[entity] = expiration
+		}
+	}
+	return pins
+}
+
+// RetrieveLease returns the lease token currently held for the given
+// namespace, as known to this state server. It returns the zero Token
+// if the lease is not held or has expired.
+func (m *leaseManager) RetrieveLease(namespace string) lease.Token {
+	m.mu.Lock()
+	svc := m.service
+	m.mu.Unlock()
+	if svc == nil {
+		return lease.Token{}
+	}
+	token, ok := svc.fsm.Token(namespace)
+	if !ok || !token.Expiration.After(time.Now()) {
+		return lease.Token{}
+	}
+	return token
+}
+
+// LeaseReleasedNotifier returns a channel a caller can block on to be
+// notified of when a lease is released for namespace, either
+// explicitly or by expiring. This channel is reusable, but
+// notifications are dropped if they are not received within a
+// minute.
+func (m *leaseManager) LeaseReleasedNotifier(namespace string) (notifier <-chan struct{}) {
+	watcher := make(chan struct{})
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releaseSubs[namespace] = append(m.releaseSubs[namespace], watcher)
+	return watcher
+}
+
+// Released notifies subscribers that the lease for the given
+// namespace has been released. It is intended to be passed to NewFSM.
+func (m *leaseManager) Released(namespace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.expired, namespace)
+	m.notifyReleased(namespace)
+}
+
+// notifyReleased must be called with m.mu held.
+func (m *leaseManager) notifyReleased(namespace string) {
+	logger.Infof("notifying namespace %q subscribers that its lease has been released", namespace)
+	for _, subscriber := range m.releaseSubs[namespace] {
+		// Notify in a separate goroutine so that subscribers
+		// which aren't listening don't block the manager.
+		go func(subscriber chan<- struct{}) {
+			select {
+			case subscriber <- struct{}{}:
+			case <-time.After(notificationTimeout):
+				logger.Warningf("a notification timed out after %s", notificationTimeout)
+			}
+		}(subscriber)
+	}
+}
+
+// expiryLoop notifies subscribers of leases in fsm which expire,
+// until stop is closed.
+func (m *leaseManager) expiryLoop(fsm *FSM, stop <-chan struct{}) {
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.checkExpiry(fsm.Tokens(), time.Now())
+		}
+	}
+}
+
+func (m *leaseManager) checkExpiry(tokens []lease.Token, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, token := range tokens {
+		if token.Expiration.After(now) {
+			continue
+		}
+		if notified, ok := m.expired[token.Namespace]; ok && notified.Equal(token.Expiration) {
+			continue
+		}
+		logger.Infof("lease for namespace %q has expired", token.Namespace)
+		m.expired[token.Namespace] = token.Expiration
+		m.notifyReleased(token.Namespace)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/lease"
+	"github.com/juju/juju/lease/raftlease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
+	"github.com/juju/juju/raft"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+)
+
+type managerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&managerSuite{})
+
+func (s *managerSuite) startService(c *gc.C) {
+	service, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) {
+		c.Check(worker.Stop(service), jc.ErrorIsNil)
+	})
+}
+
+func (s *managerSuite) TestClaimLease(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	owner, err := mgr.ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "a")
+
+	token := mgr.RetrieveLease("ns")
+	c.Assert(token.Namespace, gc.Equals, "ns")
+	c.Assert(token.Id, gc.Equals, "a")
+	c.Assert(token.Expiration.After(time.Now()), jc.IsTrue)
+}
+
+func (s *managerSuite) TestClaimLeaseDenied(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	_, err := mgr.ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+
+	owner, err := mgr.ClaimLease("ns", "b", time.Minute)
+	c.Assert(err, gc.Equals, lease.LeaseClaimDeniedErr)
+	c.Assert(owner, gc.Equals, "a")
+}
+
+func (s *managerSuite) TestClaimLeaseAfterExpiry(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	_, err := mgr.ClaimLease("ns", "a", time.Millisecond)
+	c.Assert(err, jc.ErrorIsNil)
+	time.Sleep(10 * time.Millisecond)
+
+	c.Assert(mgr.RetrieveLease("ns"), jc.DeepEquals, lease.Token{})
+	owner, err := mgr.ClaimLease("ns", "b", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "b")
+}
+
+func (s *managerSuite) TestReleaseLease(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	notifier := mgr.LeaseReleasedNotifier("ns")
+	_, err := mgr.ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = mgr.ReleaseLease("ns", "a")
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case <-notifier:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for release notification")
+	}
+	c.Assert(mgr.RetrieveLease("ns"), jc.DeepEquals, lease.Token{})
+}
+
+func (s *managerSuite) TestReleaseLeaseNotOwner(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	_, err := mgr.ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = mgr.ReleaseLease("ns", "b")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mgr.RetrieveLease("ns").Id, gc.Equals, "a")
+}
+
+func (s *managerSuite) TestPinLease(c *gc.C) {
+	s.startService(c)
+	mgr := raftlease.Manager()
+	_, err := mgr.ClaimLease("ns", "a", time.Millisecond)
+	c.Assert(err, jc.ErrorIsNil)
+	owner, err := mgr.PinLease("ns", "user-admin", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "a")
+	time.Sleep(10 * time.Millisecond)
+
+	c.Assert(mgr.RetrieveLease("ns").Id, gc.Equals, "a")
+	owner, err = mgr.ClaimLease("ns", "b", time.Minute)
+	c.Assert(err, gc.Equals, lease.LeaseClaimDeniedErr)
+	c.Assert(owner, gc.Equals, "a")
+	pins := mgr.LeasePins("ns")
+	c.Assert(pins, gc.HasLen, 1)
+	c.Assert(pins["user-admin"].After(time.Now()), jc.IsTrue)
+
+	err = mgr.UnpinLease("ns", "user-admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mgr.LeasePins("ns"), gc.HasLen, 0)
+	owner, err = mgr.ClaimLease("ns", "b", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "b")
+}
+
+func (s *managerSuite) TestPinLeaseNotHeld(c *gc.C) {
+	s.startService(c)
+	_, err := raftlease.Manager().PinLease("ns", "user-admin", time.Minute)
+	c.Assert(err, gc.ErrorMatches, `lease for namespace "ns" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *managerSuite) TestExpiryNotifies(c *gc.C) {
+	s.PatchValue(raftlease.ExpiryCheckInterval, 5*time.Millisecond)
+	s.startService(c)
+	mgr := raftlease.Manager()
+	notifier := mgr.LeaseReleasedNotifier("ns")
+	_, err := mgr.ClaimLease("ns", "a", 20*time.Millisecond)
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case <-notifier:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for expiry notification")
+	}
+}
+
+func (s *managerSuite) TestNotRunning(c *gc.C) {
+	s.PatchValue(raftlease.ProposeTimeout, coretesting.ShortWait)
+	mgr := raftlease.Manager()
+	_, err := mgr.ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, gc.ErrorMatches, `cannot claim lease for namespace "ns": lease service not running`)
+	c.Assert(mgr.RetrieveLease("ns"), jc.DeepEquals, lease.Token{})
+	_, err = mgr.Node()
+	c.Assert(err, gc.Equals, raftlease.ErrNotRunning)
+}
+
+func (s *managerSuite) TestForwardsToLeader(c *gc.C) {
+	fsm := raftlease.NewFSM(nil)
+	forwarder := &fakeForwarder{fsm: fsm}
+	detach := raftlease.Manager().Attach(followerNode{}, fsm, forwarder)
+	defer detach()
+
+	owner, err := raftlease.Manager().ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "a")
+	c.Assert(forwarder.peers, jc.DeepEquals, []string{"1"})
+	c.Assert(raftlease.Manager().RetrieveLease("ns").Id, gc.Equals, "a")
+}
+
+// followerNode is a raftlease.Node which follows node "1".
+type followerNode struct {
+	raftlease.Node
+}
+
+func (followerNode) Id() string {
+	return "0"
+}
+
+func (followerNode) Leader() string {
+	return "1"
+}
+
+func (followerNode) Propose(command []byte, timeout time.Duration) ([]byte, error) {
+	return nil, &raft.NotLeaderError{Leader: "1"}
+}
+
+// fakeForwarder applies forwarded commands to an FSM.
+type fakeForwarder struct {
+	fsm   *raftlease.FSM
+	peers []string
+}
+
+func (f *fakeForwarder) Propose(peer string, command []byte) ([]byte, error) {
+	f.peers = append(f.peers, peer)
+	return f.fsm.Apply(command), nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// singularWait is how long a singular connection waits for the raft
+// cluster to elect a leader.
+var singularWait = time.Minute
+
+// NewSingularConn returns a singular.Conn which treats the leader of
+// the lease service's raft cluster as the master, so that singular
+// workers run on the same state server that serves lease claims.
+// Ping fails once the leader has changed, after which a new
+// connection must be made.
+func NewSingularConn() *SingularConn {
+	return &SingularConn{manager: singleton}
+}
+
+// SingularConn implements singular.Conn on top of the lease service.
+type SingularConn struct {
+	manager *leaseManager
+	node    Node
+	leader  string
+}
+
+// IsMaster is part of the singular.Conn interface. It waits for the
+// raft cluster to have a leader.
+func (c *SingularConn) IsMaster() (bool, error) {
+	deadline := time.Now().Add(singularWait)
+	for {
+		svc, err := c.manager.current(deadline)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if leader := svc.node.Leader(); leader != "" {
+			c.node, c.leader = svc.node, leader
+			return leader == svc.node.Id(), nil
+		}
+		if time.Now().After(deadline) {
+			return false, errors.New("timed out waiting for raft leader")
+		}
+		time.Sleep(retryDelay)
+	}
+}
+
+// Ping is part of the singular.Conn interface.
+func (c *SingularConn) Ping() error {
+	node, err := c.manager.Node()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if node != c.node {
+		return errors.New("lease service restarted")
+	}
+	if leader := node.Leader(); leader != c.leader {
+		return errors.Errorf("raft leader changed from %q to %q", c.leader, leader)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/lease/raftlease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+)
+
+type singularSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&singularSuite{})
+
+func (s *singularSuite) TestLeaderIsMaster(c *gc.C) {
+	service, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(service)
+
+	conn := raftlease.NewSingularConn()
+	isMaster, err := conn.IsMaster()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isMaster, jc.IsTrue)
+	c.Assert(conn.Ping(), jc.ErrorIsNil)
+
+	c.Assert(worker.Stop(service), jc.ErrorIsNil)
+	c.Assert(conn.Ping(), gc.ErrorMatches, "lease service not running")
+}
+
+func (s *singularSuite) TestFollowerIsNotMaster(c *gc.C) {
+	detach := raftlease.Manager().Attach(followerNode{}, raftlease.NewFSM(nil), nil)
+	defer detach()
+
+	conn := raftlease.NewSingularConn()
+	isMaster, err := conn.IsMaster()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isMaster, jc.IsFalse)
+	c.Assert(conn.Ping(), jc.ErrorIsNil)
+}
+
+func (s *singularSuite) TestNotRunning(c *gc.C) {
+	s.PatchValue(raftlease.SingularWait, coretesting.ShortWait)
+	_, err := raftlease.NewSingularConn().IsMaster()
+	c.Assert(err, gc.ErrorMatches, "lease service not running")
+}
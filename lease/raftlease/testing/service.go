@@ -0,0 +1,66 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/raft"
+	"github.com/juju/juju/worker"
+)
+
+// NewLocalService starts a lease service formed by a single in-memory
+// raft node, and attaches it to the lease manager. It is intended for
+// tests which use leadership without running a state server agent.
+func NewLocalService() (worker.Worker, error) {
+	manager := raftlease.Manager()
+	fsm := raftlease.NewFSM(manager.Released)
+	node, err := raft.NewNode(raft.Config{
+		Id:                "local",
+		FSM:               fsm,
+		Transport:         noPeers{},
+		Store:             raft.NewMemoryStore(),
+		HeartbeatInterval: 5 * time.Millisecond,
+		ElectionTimeout:   10 * time.Millisecond,
+		SnapshotThreshold: 1000,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &localService{
+		Node:   node,
+		detach: manager.Attach(node, fsm, nil),
+	}, nil
+}
+
+type localService struct {
+	*raft.Node
+	detach func()
+	once   sync.Once
+}
+
+// Kill is part of the worker.Worker interface.
+func (s *localService) Kill() {
+	s.once.Do(s.detach)
+	s.Node.Kill()
+}
+
+// noPeers is a raft.Transport for a cluster with a single node.
+type noPeers struct{}
+
+func (noPeers) RequestVote(peer string, args raft.RequestVoteArgs) (raft.RequestVoteResult, error) {
+	return raft.RequestVoteResult{}, errors.NotFoundf("raft peer %q", peer)
+}
+
+func (noPeers) AppendEntries(peer string, args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error) {
+	return raft.AppendEntriesResult{}, errors.NotFoundf("raft peer %q", peer)
+}
+
+func (noPeers) InstallSnapshot(peer string, args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error) {
+	return raft.InstallSnapshotResult{}, errors.NotFoundf("raft peer %q", peer)
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package lease
+
+import (
+	"sync/atomic"
+)
+
+// Stats holds counts of the lease operations handled by the lease
+// manager since the process started.
+type Stats struct {
+	// Claims holds the number of lease claims granted.
+	Claims int64
+
+	// ClaimsDenied holds the number of lease claims denied because
+	// the lease was held by someone else.
+	ClaimsDenied int64
+
+	// Releases holds the number of leases released by their owners.
+	Releases int64
+
+	// Expiries holds the number of leases released because they
+	// expired.
+	Expiries int64
+}
+
+var stats Stats
+
+// CurrentStats returns the current lease operation counts.
+func CurrentStats() Stats {
+	return Stats{
+		Claims:       atomic.LoadInt64(&stats.Claims),
+		ClaimsDenied: atomic.LoadInt64(&stats.ClaimsDenied),
+		Releases:     atomic.LoadInt64(&stats.Releases),
+		Expiries:     atomic.LoadInt64(&stats.Expiries),
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongo
+
+import (
+	"os/exec"
+	"regexp"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/version"
+)
+
+// StorageEngine identifies the storage engine used by mongod.
+type StorageEngine string
+
+const (
+	// MMAPV1 is the original mongo storage engine, and the only one
+	// supported by the mongod 2.4 packaged for juju. It is used when
+	// no storage engine is specified.
+	MMAPV1 StorageEngine = "mmapv1"
+
+	// WiredTiger is the storage engine introduced in mongo 3.0.
+	WiredTiger StorageEngine = "wiredTiger"
+)
+
+// minWiredTigerVersion is the earliest version of mongod which
+// supports the WiredTiger storage engine.
+var minWiredTigerVersion = version.MustParse("3.0.0")
+
+// ParseStorageEngine returns the storage engine with the given name.
+// The empty string is parsed as MMAPV1.
+func ParseStorageEngine(name string) (StorageEngine, error) {
+	switch engine := StorageEngine(name); engine {
+	case "":
+		return MMAPV1, nil
+	case MMAPV1, WiredTiger:
+		return engine, nil
+	}
+	return "", errors.NotValidf("mongo storage engine %q", name)
+}
+
+var mongodVersionPattern = regexp.MustCompile(`db version v(\d+\.\d+\.\d+)`)
+
+// mongodVersion returns the version of the mongod at the given path.
+var mongodVersion = func(mongoPath string) (version.Number, error) {
+	output, err := exec.Command(mongoPath, "--version").CombinedOutput()
+	if err != nil {
+		return version.Number{}, errors.Annotatef(err, "cannot run %s --version", mongoPath)
+	}
+	match := mongodVersionPattern.FindSubmatch(output)
+	if match == nil {
+		return version.Number{}, errors.Errorf("cannot find version in %s --version output %q", mongoPath, output)
+	}
+	return version.Parse(string(match[1]))
+}
+
+// checkStorageEngine returns an error if the mongod at the given path
+// does not support the given storage engine.
+func checkStorageEngine(mongoPath string, engine StorageEngine) error {
+	if engine != WiredTiger {
+		return nil
+	}
+	vers, err := mongodVersion(mongoPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if vers.Compare(minWiredTigerVersion) < 0 {
+		return errors.Errorf(
+			"storage engine %s requires mongod %v or later, found %v",
+			engine, minWiredTigerVersion, vers,
+		)
+	}
+	return nil
+}
@@ -151,6 +151,15 @@ type EnsureServerParams struct {
 	// algorithm defined in Mongo.
 	OplogSize int
 
+	// StorageEngine is the storage engine mongod is to use. If this
+	// is empty, the MMAPV1 engine is used.
+	StorageEngine StorageEngine
+
+	// CacheSizeGB is the size of the WiredTiger cache in gigabytes.
+	// If this is zero, mongod chooses the size itself. It is ignored
+	// by other storage engines.
+	CacheSizeGB int
+
 	// SetNumaControlPolicy preference - whether the user
 	// wants to set the numa control policy when starting mongo.
 	SetNumaControlPolicy bool
@@ -176,6 +185,11 @@ func EnsureServer(args EnsureServerParams) error {
 		return fmt.Errorf("cannot create mongo database directory: %v", err)
 	}
 
+	engine, err := ParseStorageEngine(string(args.StorageEngine))
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	oplogSizeMB := args.OplogSize
 	if oplogSizeMB == 0 {
 		var err error
@@ -192,8 +206,14 @@ func EnsureServer(args EnsureServerParams) error {
 		return err
 	}
 	logVersion(mongoPath)
+	if err := checkStorageEngine(mongoPath, engine); err != nil {
+		return errors.Trace(err)
+	}
 
-	svcConf := newConf(args.DataDir, dbDir, mongoPath, args.StatePort, oplogSizeMB, args.SetNumaControlPolicy)
+	svcConf := newConf(
+		args.DataDir, dbDir, mongoPath, args.StatePort, oplogSizeMB,
+		args.SetNumaControlPolicy, engine, args.CacheSizeGB,
+	)
 	svc, err := newService(ServiceName(args.Namespace), svcConf)
 	if err != nil {
 		return err
@@ -248,11 +268,15 @@ func EnsureServer(args EnsureServerParams) error {
 	if err := svc.Stop(); err != nil {
 		return errors.Annotatef(err, "failed to stop mongo")
 	}
-	if err := makeJournalDirs(dbDir); err != nil {
-		return fmt.Errorf("error creating journal directories: %v", err)
-	}
-	if err := preallocOplog(dbDir, oplogSizeMB); err != nil {
-		return fmt.Errorf("error creating oplog files: %v", err)
+	// The journal and oplog files are only preallocated for the
+	// MMAPV1 storage engine; WiredTiger manages its own.
+	if engine == MMAPV1 {
+		if err := makeJournalDirs(dbDir); err != nil {
+			return fmt.Errorf("error creating journal directories: %v", err)
+		}
+		if err := preallocOplog(dbDir, oplogSizeMB); err != nil {
+			return fmt.Errorf("error creating oplog files: %v", err)
+		}
 	}
 	if err := service.InstallAndStart(svc); err != nil {
 		return errors.Trace(err)
@@ -252,6 +252,44 @@ func (s *MongoSuite) testEnsureServerNumaCtl(c *gc.C, setNumaPolicy bool) string
 	return dataDir
 }
 
+func (s *MongoSuite) TestEnsureServerWiredTiger(c *gc.C) {
+	err := ioutil.WriteFile(s.mongodPath, []byte("#!/bin/bash\n\nprintf %s 'db version v3.0.4'\n"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	dataDir := c.MkDir()
+	mockShellCommand(c, &s.CleanupSuite, "apt-get")
+
+	testParams := makeEnsureServerParams(dataDir, "namespace")
+	testParams.StorageEngine = mongo.WiredTiger
+	testParams.CacheSizeGB = 2
+	err = mongo.EnsureServer(testParams)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(s.data.Installed, gc.HasLen, 1)
+	execStart := s.data.Installed[0].Conf().ExecStart
+	c.Assert(execStart, gc.Matches, ".* --storageEngine wiredTiger --wiredTigerCacheSizeGB 2 .*")
+	c.Assert(execStart, gc.Not(gc.Matches), ".* --smallfiles.*")
+	// The MMAPV1 journal files are not preallocated.
+	_, err = os.Stat(filepath.Join(dataDir, "db", "journal"))
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *MongoSuite) TestEnsureServerWiredTigerRequiresMongo3(c *gc.C) {
+	mockShellCommand(c, &s.CleanupSuite, "apt-get")
+
+	testParams := makeEnsureServerParams(c.MkDir(), "namespace")
+	testParams.StorageEngine = mongo.WiredTiger
+	err := mongo.EnsureServer(testParams)
+	c.Assert(err, gc.ErrorMatches, "storage engine wiredTiger requires mongod 3.0.0 or later, found 2.4.9")
+	c.Assert(s.data.Installed, gc.HasLen, 0)
+}
+
+func (s *MongoSuite) TestEnsureServerInvalidStorageEngine(c *gc.C) {
+	testParams := makeEnsureServerParams(c.MkDir(), "namespace")
+	testParams.StorageEngine = "rocksdb"
+	err := mongo.EnsureServer(testParams)
+	c.Assert(err, gc.ErrorMatches, `mongo storage engine "rocksdb" not valid`)
+}
+
 func (s *MongoSuite) TestInstallMongod(c *gc.C) {
 	type installs struct {
 		series string
@@ -315,28 +353,28 @@ func (s *MongoSuite) TestInstallMongodServiceExists(c *gc.C) {
 func (s *MongoSuite) TestNewServiceWithReplSet(c *gc.C) {
 	dataDir := c.MkDir()
 
-	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false)
+	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false, "", 0)
 	c.Assert(strings.Contains(conf.ExecStart, "--replSet"), jc.IsTrue)
 }
 
 func (s *MongoSuite) TestNewServiceWithNumCtl(c *gc.C) {
 	dataDir := c.MkDir()
 
-	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, true)
+	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, true, "", 0)
 	c.Assert(conf.ExtraScript, gc.Not(gc.Matches), "")
 }
 
 func (s *MongoSuite) TestNewServiceIPv6(c *gc.C) {
 	dataDir := c.MkDir()
 
-	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false)
+	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false, "", 0)
 	c.Assert(strings.Contains(conf.ExecStart, "--ipv6"), jc.IsTrue)
 }
 
 func (s *MongoSuite) TestNewServiceWithJournal(c *gc.C) {
 	dataDir := c.MkDir()
 
-	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false)
+	conf := mongo.NewConf(dataDir, dataDir, mongo.JujuMongodPath, 1234, 1024, false, "", 0)
 	c.Assert(conf.ExecStart, gc.Matches, `.* --journal.*`)
 }
 
@@ -100,18 +100,35 @@ func sharedSecretPath(dataDir string) string {
 }
 
 // newConf returns the init system config for the mongo state service.
-func newConf(dataDir, dbDir, mongoPath string, port, oplogSizeMB int, wantNumaCtl bool) common.Conf {
+func newConf(
+	dataDir, dbDir, mongoPath string,
+	port, oplogSizeMB int,
+	wantNumaCtl bool,
+	engine StorageEngine,
+	cacheSizeGB int,
+) common.Conf {
 	mongoCmd := mongoPath +
 		" --auth" +
 		" --dbpath " + utils.ShQuote(dbDir) +
 		" --sslOnNormalPorts" +
 		" --sslPEMKeyFile " + utils.ShQuote(sslKeyPath(dataDir)) +
 		" --sslPEMKeyPassword ignored" +
-		" --port " + fmt.Sprint(port) +
-		" --noprealloc" +
-		" --syslog" +
-		" --smallfiles" +
-		" --journal" +
+		" --port " + fmt.Sprint(port)
+	// The options for the MMAPV1 engine are left unchanged, and the
+	// engine is not named, so that mongod 2.4 continues to accept them
+	// and existing services are not restarted on upgrade.
+	if engine == WiredTiger {
+		mongoCmd += " --storageEngine " + string(WiredTiger)
+		if cacheSizeGB > 0 {
+			mongoCmd += " --wiredTigerCacheSizeGB " + strconv.Itoa(cacheSizeGB)
+		}
+		mongoCmd += " --syslog"
+	} else {
+		mongoCmd += " --noprealloc" +
+			" --syslog" +
+			" --smallfiles"
+	}
+	mongoCmd += " --journal" +
 		" --keyFile " + utils.ShQuote(sharedSecretPath(dataDir)) +
 		" --replSet " + ReplicaSetName +
 		" --ipv6" +
@@ -26,7 +26,7 @@ func (s *serviceSuite) TestNewConf(c *gc.C) {
 	mongodPath := "/mgo/bin/mongod"
 	port := 12345
 	oplogSizeMB := 10
-	conf := mongo.NewConf(dataDir, dbDir, mongodPath, port, oplogSizeMB, false)
+	conf := mongo.NewConf(dataDir, dbDir, mongodPath, port, oplogSizeMB, false, mongo.MMAPV1, 0)
 
 	expected := common.Conf{
 		Desc: "juju state database",
@@ -54,3 +54,24 @@ func (s *serviceSuite) TestNewConf(c *gc.C) {
 	c.Check(conf, jc.DeepEquals, expected)
 	c.Check(strings.Fields(conf.ExecStart), jc.DeepEquals, strings.Fields(expected.ExecStart))
 }
+
+func (s *serviceSuite) TestNewConfWiredTiger(c *gc.C) {
+	conf := mongo.NewConf("/var/lib/juju", "/var/lib/juju/db", "/mgo/bin/mongod", 12345, 10, false, mongo.WiredTiger, 4)
+
+	expected := "/mgo/bin/mongod" +
+		" --auth" +
+		" --dbpath '/var/lib/juju/db'" +
+		" --sslOnNormalPorts" +
+		" --sslPEMKeyFile '/var/lib/juju/server.pem'" +
+		" --sslPEMKeyPassword ignored" +
+		" --port 12345" +
+		" --storageEngine wiredTiger" +
+		" --wiredTigerCacheSizeGB 4" +
+		" --syslog" +
+		" --journal" +
+		" --keyFile '/var/lib/juju/shared-secret'" +
+		" --replSet juju" +
+		" --ipv6" +
+		" --oplogSize 10"
+	c.Check(conf.ExecStart, gc.Equals, expected)
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// EgressRule represents a range of destination addresses, and
+// optionally a range of ports on them, that machines may connect to.
+type EgressRule struct {
+	// DestinationCIDR holds the IPv4 destination addresses in CIDR
+	// notation.
+	DestinationCIDR string
+
+	// PortRange, if not nil, restricts the rule to connections to
+	// the given ports.
+	PortRange *PortRange
+}
+
+// ParseEgressRule parses an egress rule, which is a destination CIDR,
+// optionally followed by a colon and a port range as accepted by
+// ParsePortRange. Example strings: "10.0.0.0/8", "0.0.0.0/0:443/tcp",
+// "192.168.1.0/24:5000-5010/udp".
+func ParseEgressRule(inRule string) (EgressRule, error) {
+	var rule EgressRule
+	cidr := strings.TrimSpace(inRule)
+	var ports string
+	if i := strings.Index(cidr, ":"); i >= 0 {
+		cidr, ports = cidr[:i], cidr[i+1:]
+	}
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil || ip.To4() == nil {
+		return rule, errors.NotValidf("egress rule %q: destination %q is not an IPv4 CIDR", inRule, cidr)
+	}
+	rule.DestinationCIDR = ipNet.String()
+	if ports != "" {
+		portRange, err := ParsePortRange(ports)
+		if err != nil {
+			return rule, errors.Annotatef(err, "invalid egress rule %q", inRule)
+		}
+		portRange.Protocol = strings.ToLower(portRange.Protocol)
+		rule.PortRange = &portRange
+	}
+	return rule, nil
+}
+
+// ParseEgressRules splits the provided string on commas and parses an
+// egress rule from each part. Whitespace and empty parts are ignored.
+func ParseEgressRules(inRules string) ([]EgressRule, error) {
+	var rules []EgressRule
+	for _, inRule := range strings.Split(inRules, ",") {
+		if strings.TrimSpace(inRule) == "" {
+			continue
+		}
+		rule, err := ParseEgressRule(inRule)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r EgressRule) String() string {
+	if r.PortRange == nil {
+		return r.DestinationCIDR
+	}
+	return fmt.Sprintf("%s:%s", r.DestinationCIDR, r.PortRange)
+}
+
+func (r EgressRule) GoString() string {
+	return r.String()
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+)
+
+type EgressRuleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&EgressRuleSuite{})
+
+func (*EgressRuleSuite) TestParseEgressRule(c *gc.C) {
+	https := network.MustParsePortRange("443/tcp")
+	dns := network.MustParsePortRange("53/udp")
+	for i, test := range []struct {
+		rule     string
+		expected network.EgressRule
+		str      string
+		err      string
+	}{{
+		rule:     "10.1.2.3/8",
+		expected: network.EgressRule{DestinationCIDR: "10.0.0.0/8"},
+		str:      "10.0.0.0/8",
+	}, {
+		rule:     " 0.0.0.0/0:443 ",
+		expected: network.EgressRule{DestinationCIDR: "0.0.0.0/0", PortRange: &https},
+		str:      "0.0.0.0/0:443/tcp",
+	}, {
+		rule:     "192.168.1.1/32:53/UDP",
+		expected: network.EgressRule{DestinationCIDR: "192.168.1.1/32", PortRange: &dns},
+		str:      "192.168.1.1/32:53/udp",
+	}, {
+		rule: "10.0.0.0",
+		err:  `egress rule "10.0.0.0": destination "10.0.0.0" is not an IPv4 CIDR not valid`,
+	}, {
+		rule: "2001:db8::/32",
+		err:  `egress rule "2001:db8::/32": destination "2001" is not an IPv4 CIDR not valid`,
+	}, {
+		rule: "10.0.0.0/8:http",
+		err:  `invalid egress rule "10.0.0.0/8:http": invalid port "http": .*`,
+	}} {
+		c.Logf("test %d: %q", i, test.rule)
+		rule, err := network.ParseEgressRule(test.rule)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(rule, jc.DeepEquals, test.expected)
+		c.Check(rule.String(), gc.Equals, test.str)
+	}
+}
+
+func (*EgressRuleSuite) TestParseEgressRules(c *gc.C) {
+	rules, err := network.ParseEgressRules("10.0.0.0/8, ,0.0.0.0/0:443")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, gc.HasLen, 2)
+	c.Assert(rules[0].String(), gc.Equals, "10.0.0.0/8")
+	c.Assert(rules[1].String(), gc.Equals, "0.0.0.0/0:443/tcp")
+
+	rules, err = network.ParseEgressRules("")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, gc.HasLen, 0)
+
+	_, err = network.ParseEgressRules("10.0.0.0/8,nonsense")
+	c.Assert(err, gc.ErrorMatches, `egress rule "nonsense": .* not valid`)
+}
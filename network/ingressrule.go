@@ -0,0 +1,146 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// AnyCIDR is the source CIDR of an ingress rule which admits traffic
+// from any address.
+const AnyCIDR = "0.0.0.0/0"
+
+// IngressRule represents a range of ports opened to traffic from a set
+// of source address ranges.
+type IngressRule struct {
+	PortRange
+
+	// SourceCIDRs holds the address ranges, in CIDR notation, that
+	// may reach the ports. It is never empty; a rule admitting any
+	// address has AnyCIDR as its only source.
+	SourceCIDRs []string
+}
+
+// NewIngressRule returns an ingress rule opening the given port range
+// to the given source CIDRs, or to any address if none are given. The
+// CIDRs are normalised and sorted; if any of them is AnyCIDR, it is
+// the rule's only source.
+func NewIngressRule(portRange PortRange, sourceCIDRs ...string) (IngressRule, error) {
+	rule := IngressRule{PortRange: portRange}
+	if err := portRange.Validate(); err != nil {
+		return rule, errors.Trace(err)
+	}
+	cidrs, err := NormaliseCIDRs(sourceCIDRs)
+	if err != nil {
+		return rule, errors.Trace(err)
+	}
+	rule.SourceCIDRs = []string{AnyCIDR}
+	for _, cidr := range cidrs {
+		if cidr == AnyCIDR {
+			return rule, nil
+		}
+	}
+	if len(cidrs) > 0 {
+		rule.SourceCIDRs = cidrs
+	}
+	return rule, nil
+}
+
+// MustNewIngressRule returns an ingress rule as NewIngressRule does,
+// panicking if the arguments are invalid.
+func MustNewIngressRule(portRange PortRange, sourceCIDRs ...string) IngressRule {
+	rule, err := NewIngressRule(portRange, sourceCIDRs...)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// NormaliseCIDRs validates the given address ranges, and returns them
+// in canonical form, sorted and without duplicates.
+func NormaliseCIDRs(cidrs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, errors.NotValidf("CIDR %q", cidr)
+		}
+		cidr = ipNet.String()
+		if !seen[cidr] {
+			seen[cidr] = true
+			result = append(result, cidr)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// IsUnrestricted reports whether the rule admits traffic from any
+// address.
+func (r IngressRule) IsUnrestricted() bool {
+	return len(r.SourceCIDRs) == 0 || r.SourceCIDRs[0] == AnyCIDR
+}
+
+func (r IngressRule) String() string {
+	if r.IsUnrestricted() {
+		return r.PortRange.String()
+	}
+	return fmt.Sprintf("%s from %s", r.PortRange, strings.Join(r.SourceCIDRs, ","))
+}
+
+func (r IngressRule) GoString() string {
+	return r.String()
+}
+
+type ingressRuleSlice []IngressRule
+
+func (r ingressRuleSlice) Len() int      { return len(r) }
+func (r ingressRuleSlice) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r ingressRuleSlice) Less(i, j int) bool {
+	ranges := portRangeSlice{r[i].PortRange, r[j].PortRange}
+	if ranges[0] != ranges[1] {
+		return ranges.Less(0, 1)
+	}
+	return r[i].String() < r[j].String()
+}
+
+// SortIngressRules sorts the given rules by port range, then by
+// source.
+func SortIngressRules(rules []IngressRule) {
+	sort.Sort(ingressRuleSlice(rules))
+}
+
+// IngressRulesFromPortRanges returns rules opening each of the given
+// port ranges to any address.
+func IngressRulesFromPortRanges(portRanges []PortRange) []IngressRule {
+	rules := make([]IngressRule, len(portRanges))
+	for i, portRange := range portRanges {
+		rules[i] = IngressRule{
+			PortRange:   portRange,
+			SourceCIDRs: []string{AnyCIDR},
+		}
+	}
+	return rules
+}
+
+// DiffIngressRules returns the rules in a that are not in b.
+func DiffIngressRules(a, b []IngressRule) []IngressRule {
+	inB := make(map[string]bool)
+	for _, rule := range b {
+		inB[rule.String()] = true
+	}
+	var missing []IngressRule
+	for _, rule := range a {
+		if !inB[rule.String()] {
+			missing = append(missing, rule)
+		}
+	}
+	return missing
+}
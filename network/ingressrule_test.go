@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package network_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+)
+
+type IngressRuleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&IngressRuleSuite{})
+
+func (*IngressRuleSuite) TestNewIngressRule(c *gc.C) {
+	portRange := network.MustParsePortRange("80/tcp")
+	for i, test := range []struct {
+		cidrs    []string
+		expected []string
+		err      string
+	}{{
+		expected: []string{"0.0.0.0/0"},
+	}, {
+		cidrs:    []string{"10.0.0.1/8", "192.168.1.0/24", "10.0.0.0/8"},
+		expected: []string{"10.0.0.0/8", "192.168.1.0/24"},
+	}, {
+		cidrs:    []string{"10.0.0.0/8", "0.0.0.0/0"},
+		expected: []string{"0.0.0.0/0"},
+	}, {
+		cidrs: []string{"10.0.0.0"},
+		err:   `CIDR "10.0.0.0" not valid`,
+	}} {
+		c.Logf("test %d: %v", i, test.cidrs)
+		rule, err := network.NewIngressRule(portRange, test.cidrs...)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(rule.PortRange, gc.Equals, portRange)
+		c.Check(rule.SourceCIDRs, jc.DeepEquals, test.expected)
+	}
+}
+
+func (*IngressRuleSuite) TestNewIngressRuleInvalidPortRange(c *gc.C) {
+	_, err := network.NewIngressRule(network.PortRange{FromPort: 80, ToPort: 79, Protocol: "tcp"})
+	c.Assert(err, gc.ErrorMatches, "invalid port range 80-79/tcp")
+}
+
+func (*IngressRuleSuite) TestString(c *gc.C) {
+	rule := network.MustNewIngressRule(network.MustParsePortRange("80-90/tcp"))
+	c.Assert(rule.IsUnrestricted(), jc.IsTrue)
+	c.Assert(rule.String(), gc.Equals, "80-90/tcp")
+
+	rule = network.MustNewIngressRule(network.MustParsePortRange("53/udp"), "192.168.0.0/16", "10.0.0.0/8")
+	c.Assert(rule.IsUnrestricted(), jc.IsFalse)
+	c.Assert(rule.String(), gc.Equals, "53/udp from 10.0.0.0/8,192.168.0.0/16")
+}
+
+func (*IngressRuleSuite) TestSortAndDiff(c *gc.C) {
+	all := network.MustNewIngressRule(network.MustParsePortRange("80/tcp"))
+	restricted := network.MustNewIngressRule(network.MustParsePortRange("80/tcp"), "10.0.0.0/8")
+	udp := network.MustNewIngressRule(network.MustParsePortRange("53/udp"))
+	rules := []network.IngressRule{udp, restricted, all}
+	network.SortIngressRules(rules)
+	c.Assert(rules, jc.DeepEquals, []network.IngressRule{all, restricted, udp})
+
+	c.Assert(network.DiffIngressRules(rules, []network.IngressRule{all}), jc.DeepEquals,
+		[]network.IngressRule{restricted, udp})
+	c.Assert(network.DiffIngressRules([]network.IngressRule{all}, rules), gc.HasLen, 0)
+}
+
+func (*IngressRuleSuite) TestIngressRulesFromPortRanges(c *gc.C) {
+	portRanges := []network.PortRange{
+		network.MustParsePortRange("80/tcp"),
+		network.MustParsePortRange("1000-2000/udp"),
+	}
+	c.Assert(network.IngressRulesFromPortRanges(portRanges), jc.DeepEquals, []network.IngressRule{
+		network.MustNewIngressRule(portRanges[0]),
+		network.MustNewIngressRule(portRanges[1]),
+	})
+}
@@ -56,6 +56,12 @@ type SubnetInfo struct {
 	// allocatable.
 	AllocatableIPLow  net.IP
 	AllocatableIPHigh net.IP
+
+	// AvailabilityZones holds the availability zones in which the
+	// subnet can be used. It is empty if the provider does not
+	// support availability zones, or the subnet can be used in all
+	// of them.
+	AvailabilityZones []string
 }
 
 // InterfaceConfigType defines valid network interface configuration
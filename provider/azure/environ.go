@@ -566,6 +566,10 @@ func (env *azureEnviron) selectInstanceTypeAndImage(constraint *instances.Instan
 var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.Tags,
+	constraints.RootDiskSource,
+	constraints.Zones,
+	constraints.Gpus,
+	constraints.GpuType,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
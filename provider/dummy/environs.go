@@ -13,6 +13,12 @@
 // of type boolean. If this is non-empty, any operation
 // after the environment has been opened will return
 // the error "broken environment", and will also log that.
+// Individual calls of an operation can be made to fail
+// with InjectErrors.
+//
+// Dummy environments support block storage through the
+// "dummy-volume" storage provider, whose volumes exist only
+// in the environment's state.
 //
 // The DNS name of instances is the same as the Id,
 // with ".dns" appended.
@@ -41,10 +47,12 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/cloudinit"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/arch"
 	"github.com/juju/juju/mongo"
@@ -52,6 +60,7 @@ import (
 	"github.com/juju/juju/provider/common"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/multiwatcher"
+	"github.com/juju/juju/storage"
 	"github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
 )
@@ -60,6 +69,13 @@ var logger = loggo.GetLogger("juju.provider.dummy")
 
 var transientErrorInjection chan error
 
+// injectedErrors holds the errors to be returned by the next calls of
+// each operation, by operation name; see InjectErrors.
+var injectedErrors = struct {
+	mu   sync.Mutex
+	errs map[string][]error
+}{errs: make(map[string][]error)}
+
 const (
 	BootstrapInstanceId = instance.Id("localhost")
 )
@@ -104,6 +120,31 @@ func PatchTransientErrorInjectionChannel(c chan error) func() {
 	return gitjujutesting.PatchValue(&transientErrorInjection, c)
 }
 
+// InjectErrors arranges for the next calls of the named operation of
+// any dummy environment to fail with the given errors, one per call,
+// after which calls succeed again; a nil error lets its call succeed.
+// Operations are named as in the "broken" setting, for example
+// "StartInstance", "AllocateAddress" or "CreateVolumes". Injected
+// errors are forgotten by Reset.
+func InjectErrors(operation string, errs ...error) {
+	injectedErrors.mu.Lock()
+	defer injectedErrors.mu.Unlock()
+	injectedErrors.errs[operation] = append(injectedErrors.errs[operation], errs...)
+}
+
+// nextInjectedError returns the next error injected for the named
+// operation, if there is one.
+func nextInjectedError(operation string) error {
+	injectedErrors.mu.Lock()
+	defer injectedErrors.mu.Unlock()
+	errs := injectedErrors.errs[operation]
+	if len(errs) == 0 {
+		return nil
+	}
+	injectedErrors.errs[operation] = errs[1:]
+	return errs[0]
+}
+
 // AdminUserTag returns the user tag used to bootstrap the dummy environment.
 // The dummy bootstrapping is handled slightly differently, and the user is
 // created as part of the bootstrap process.  This method is used to provide
@@ -199,6 +240,7 @@ type OpStartInstance struct {
 	APIInfo          *api.Info
 	Secret           string
 	AgentEnvironment map[string]string
+	Volumes          []storage.Volume
 }
 
 type OpStopInstances struct {
@@ -252,6 +294,8 @@ type environState struct {
 	maxId        int // maximum instance id allocated so far.
 	maxAddr      int // maximum allocated address last byte
 	insts        map[instance.Id]*dummyInstance
+	volumes      map[string]*dummyVolume
+	maxVolumeId  int
 	globalPorts  map[network.PortRange]bool
 	bootstrapped bool
 	storageDelay time.Duration
@@ -304,6 +348,9 @@ func Reset() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	providerInstance.ops = discardOperations
+	injectedErrors.mu.Lock()
+	injectedErrors.errs = make(map[string][]error)
+	injectedErrors.mu.Unlock()
 	for _, s := range p.state {
 		s.httpListener.Close()
 		if s.apiListener != nil {
@@ -367,6 +414,7 @@ func newState(name string, ops chan<- Operation, policy state.Policy) *environSt
 		ops:         ops,
 		statePolicy: policy,
 		insts:       make(map[instance.Id]*dummyInstance),
+		volumes:     make(map[string]*dummyVolume),
 		globalPorts: make(map[network.PortRange]bool),
 	}
 	s.storage = newStorageServer(s, "/"+name+"/private")
@@ -542,6 +590,12 @@ func (p *environProvider) Open(cfg *config.Config) (environs.Environ, error) {
 	return env, nil
 }
 
+// HourlyCost is specified in the environs.CostEstimator interface.
+// Dummy instance type costs are in US cents per hour.
+func (p *environProvider) HourlyCost(itype instances.InstanceType) (float64, string) {
+	return float64(itype.Cost) / 100, "USD"
+}
+
 // RestrictedConfigAttributes is specified in the EnvironProvider interface.
 func (p *environProvider) RestrictedConfigAttributes() []string {
 	return nil
@@ -630,9 +684,55 @@ func (e *environ) checkBroken(method string) error {
 			return fmt.Errorf("dummy.%s is broken", method)
 		}
 	}
+	return nextInjectedError(method)
+}
+
+// ValidateCredential is specified on the environs.CredentialValidator
+// interface. The credentials are rejected if the secret is "invalid".
+func (e *environ) ValidateCredential() error {
+	if err := e.checkBroken("ValidateCredential"); err != nil {
+		return err
+	}
+	if e.ecfg().secret() == "invalid" {
+		return errors.Unauthorizedf("secret rejected")
+	}
 	return nil
 }
 
+// InstanceTypes is specified on the environs.InstanceMetadataLister
+// interface.
+func (e *environ) InstanceTypes() ([]instances.InstanceType, error) {
+	if err := e.checkBroken("InstanceTypes"); err != nil {
+		return nil, err
+	}
+	return []instances.InstanceType{{
+		Id:       "1",
+		Name:     "small",
+		Arches:   []string{arch.AMD64, arch.I386},
+		CpuCores: 1,
+		Mem:      1024,
+		Cost:     10,
+	}, {
+		Id:       "2",
+		Name:     "large",
+		Arches:   []string{arch.AMD64},
+		CpuCores: 4,
+		Mem:      8192,
+		Cost:     40,
+	}}, nil
+}
+
+// Images is specified on the environs.InstanceMetadataLister interface.
+func (e *environ) Images(series string) ([]instances.Image, error) {
+	if err := e.checkBroken("Images"); err != nil {
+		return nil, err
+	}
+	return []instances.Image{
+		{Id: series + "-amd64", Arch: arch.AMD64},
+		{Id: series + "-i386", Arch: arch.I386},
+	}, nil
+}
+
 // SupportedArchitectures is specified on the EnvironCapability interface.
 func (*environ) SupportedArchitectures() ([]string, error) {
 	return []string{arch.AMD64, arch.I386, arch.PPC64EL}, nil
@@ -866,6 +966,9 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 	}
 	logger.Infof("would pick tools from %s", args.Tools)
 	series := args.Tools.OneSeries()
+	if args.StatusCallback != nil {
+		args.StatusCallback(params.StatusImageSelected, "dummy-"+series, nil)
+	}
 
 	idString := fmt.Sprintf("%s-%d", e.name, estate.maxId)
 	addrs := network.NewAddresses(idString+".dns", "127.0.0.1")
@@ -946,6 +1049,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		// TODO(dimitern) Add the rest of the network.InterfaceInfo
 		// fields when we can use them.
 	}
+	volumes, volumeAttachments := estate.createVolumes(args.Volumes, names.NewMachineTag(machineId), i.id)
 	estate.insts[i.id] = i
 	estate.maxId++
 	estate.ops <- OpStartInstance{
@@ -962,11 +1066,14 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		APIInfo:          args.MachineConfig.APIInfo,
 		AgentEnvironment: args.MachineConfig.AgentEnvironment,
 		Secret:           e.ecfg().secret(),
+		Volumes:          volumes,
 	}
 	return &environs.StartInstanceResult{
-		Instance:    i,
-		Hardware:    hc,
-		NetworkInfo: networkInfo,
+		Instance:          i,
+		Hardware:          hc,
+		NetworkInfo:       networkInfo,
+		Volumes:           volumes,
+		VolumeAttachments: volumeAttachments,
 	}, nil
 }
 
@@ -983,6 +1090,13 @@ func (e *environ) StopInstances(ids ...instance.Id) error {
 	defer estate.mu.Unlock()
 	for _, id := range ids {
 		delete(estate.insts, id)
+		for _, v := range estate.volumes {
+			for machine, instId := range v.attached {
+				if instId == id {
+					delete(v.attached, machine)
+				}
+			}
+		}
 	}
 	estate.ops <- OpStopInstances{
 		Env: e.name,
@@ -9,6 +9,8 @@ import (
 	stdtesting "testing"
 	"time"
 
+	"github.com/juju/errors"
+	"github.com/juju/names"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -22,6 +24,8 @@ import (
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider/registry"
 	"github.com/juju/juju/testing"
 )
 
@@ -403,6 +407,134 @@ func (s *suite) TestSubnets(c *gc.C) {
 	c.Assert(netInfo, gc.HasLen, 0)
 }
 
+func (s *suite) TestInjectErrors(c *gc.C) {
+	e := s.bootstrapTestEnviron(c, false)
+	defer func() {
+		err := e.Destroy()
+		c.Assert(err, jc.ErrorIsNil)
+	}()
+
+	inst, _ := jujutesting.AssertStartInstance(c, e, "0")
+	subnetId := network.Id("net1")
+	address := network.NewAddress("0.1.2.1", network.ScopeCloudLocal)
+
+	// Injected errors are returned one per call, in order, after
+	// which calls succeed again.
+	dummy.InjectErrors("AllocateAddress", errors.New("first"), nil, errors.New("second"))
+	err := e.AllocateAddress(inst.Id(), subnetId, address)
+	c.Assert(err, gc.ErrorMatches, "first")
+	err = e.AllocateAddress(inst.Id(), subnetId, address)
+	c.Assert(err, jc.ErrorIsNil)
+	err = e.AllocateAddress(inst.Id(), subnetId, address)
+	c.Assert(err, gc.ErrorMatches, "second")
+	err = e.AllocateAddress(inst.Id(), subnetId, address)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Other operations are not affected.
+	dummy.InjectErrors("StartInstance", errors.New("no capacity"))
+	_, err = e.Subnets(inst.Id(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, _, err = jujutesting.StartInstance(e, "1")
+	c.Assert(err, gc.ErrorMatches, "no capacity")
+}
+
+func (s *suite) TestStartInstanceWithVolumes(c *gc.C) {
+	e := s.bootstrapTestEnviron(c, false)
+	defer func() {
+		err := e.Destroy()
+		c.Assert(err, jc.ErrorIsNil)
+	}()
+
+	result, err := jujutesting.StartInstanceWithParams(e, "1", environs.StartInstanceParams{
+		Volumes: []storage.VolumeParams{{
+			Tag:      names.NewVolumeTag("0"),
+			Size:     1024,
+			Provider: dummy.StorageProviderType,
+		}, {
+			// Volumes of other providers are left alone.
+			Tag:      names.NewVolumeTag("1"),
+			Size:     1024,
+			Provider: "loop",
+		}},
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Volumes, jc.DeepEquals, []storage.Volume{{
+		Tag:      names.NewVolumeTag("0"),
+		VolumeId: "vol-1",
+		Serial:   "dummy-serial-1",
+		Size:     1024,
+	}})
+	c.Assert(result.VolumeAttachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:     names.NewVolumeTag("0"),
+		Machine:    names.NewMachineTag("1"),
+		DeviceName: "xvd-vol-1",
+	}})
+}
+
+func (s *suite) TestVolumeSource(c *gc.C) {
+	e := s.bootstrapTestEnviron(c, false)
+	defer func() {
+		err := e.Destroy()
+		c.Assert(err, jc.ErrorIsNil)
+	}()
+	inst, _ := jujutesting.AssertStartInstance(c, e, "0")
+
+	p, err := registry.StorageProvider(dummy.StorageProviderType)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(p.Supports(storage.StorageKindBlock), jc.IsTrue)
+	source, err := p.VolumeSource(e.Config(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	volumes, attachments, err := source.CreateVolumes([]storage.VolumeParams{{
+		Tag:  names.NewVolumeTag("0"),
+		Size: 2048,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attachments, gc.HasLen, 0)
+	c.Assert(volumes, gc.HasLen, 1)
+	volumeId := volumes[0].VolumeId
+
+	described, err := source.DescribeVolumes([]string{volumeId})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(described, jc.DeepEquals, volumes)
+
+	attachParams := []storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{
+			Machine:    names.NewMachineTag("0"),
+			InstanceId: inst.Id(),
+		},
+		Volume:   names.NewVolumeTag("0"),
+		VolumeId: volumeId,
+	}}
+	attachments, err = source.AttachVolumes(attachParams)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:     names.NewVolumeTag("0"),
+		Machine:    names.NewMachineTag("0"),
+		DeviceName: "xvd-" + volumeId,
+	}})
+
+	// Attached volumes cannot be destroyed.
+	errs := source.DestroyVolumes([]string{volumeId})
+	c.Assert(errs, gc.HasLen, 1)
+	c.Assert(errs[0], gc.ErrorMatches, `volume ".*" is attached`)
+
+	err = source.DetachVolumes(attachParams)
+	c.Assert(err, jc.ErrorIsNil)
+	errs = source.DestroyVolumes([]string{volumeId})
+	c.Assert(errs, jc.DeepEquals, []error{nil})
+	_, err = source.DescribeVolumes([]string{volumeId})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// Storage operations can be made to fail too.
+	dummy.InjectErrors("CreateVolumes", errors.New("out of space"))
+	_, _, err = source.CreateVolumes([]storage.VolumeParams{{
+		Tag:  names.NewVolumeTag("1"),
+		Size: 1024,
+	}})
+	c.Assert(err, gc.ErrorMatches, "out of space")
+}
+
 func (s *suite) TestPreferIPv6On(c *gc.C) {
 	e := s.bootstrapTestEnviron(c, true)
 	defer func() {
@@ -8,5 +8,6 @@ import (
 )
 
 func init() {
-	registry.RegisterEnvironStorageProviders("dummy")
+	registry.RegisterProvider(StorageProviderType, &storageProvider{})
+	registry.RegisterEnvironStorageProviders("dummy", StorageProviderType)
 }
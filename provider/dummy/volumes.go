@@ -0,0 +1,268 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dummy
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+)
+
+// StorageProviderType is the type of the storage provider of dummy
+// environments, which creates volumes that exist only in the dummy
+// environment's state. Volumes whose pool uses it are created with the
+// instances they are attached to, or by the storage provisioner.
+const StorageProviderType = storage.ProviderType("dummy-volume")
+
+type OpCreateVolumes struct {
+	Env     string
+	Volumes []storage.Volume
+}
+
+type OpDestroyVolumes struct {
+	Env       string
+	VolumeIds []string
+}
+
+type OpAttachVolumes struct {
+	Env         string
+	Attachments []storage.VolumeAttachment
+}
+
+type OpDetachVolumes struct {
+	Env    string
+	Params []storage.VolumeAttachmentParams
+}
+
+// dummyVolume holds the details of a volume in a dummy environment.
+type dummyVolume struct {
+	volume   storage.Volume
+	attached map[names.MachineTag]instance.Id
+}
+
+// storageProvider is the storage provider of dummy environments.
+type storageProvider struct{}
+
+var _ storage.Provider = (*storageProvider)(nil)
+
+// VolumeSource is defined on the storage.Provider interface.
+func (*storageProvider) VolumeSource(environConfig *config.Config, providerConfig *storage.Config) (storage.VolumeSource, error) {
+	env, err := providerInstance.Open(environConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &volumeSource{env.(*environ)}, nil
+}
+
+// FilesystemSource is defined on the storage.Provider interface.
+func (*storageProvider) FilesystemSource(environConfig *config.Config, providerConfig *storage.Config) (storage.FilesystemSource, error) {
+	return nil, errors.NotSupportedf("filesystems")
+}
+
+// Supports is defined on the storage.Provider interface.
+func (*storageProvider) Supports(kind storage.StorageKind) bool {
+	return kind == storage.StorageKindBlock
+}
+
+// ValidateConfig is defined on the storage.Provider interface.
+func (*storageProvider) ValidateConfig(*storage.Config) error {
+	return nil
+}
+
+// volumeSource creates volumes in a dummy environment.
+type volumeSource struct {
+	env *environ
+}
+
+var _ storage.VolumeSource = (*volumeSource)(nil)
+
+// createVolumes creates volumes for those of the given parameters that
+// use the dummy storage provider, attaching them to the given machine
+// and instance. It must be called with s.mu held.
+func (s *environState) createVolumes(params []storage.VolumeParams, machine names.MachineTag, instId instance.Id) ([]storage.Volume, []storage.VolumeAttachment) {
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, p := range params {
+		if p.Provider != StorageProviderType {
+			continue
+		}
+		v := s.newVolume(p)
+		volumes = append(volumes, v.volume)
+		v.attached[machine] = instId
+		attachments = append(attachments, volumeAttachment(v, machine))
+	}
+	return volumes, attachments
+}
+
+// newVolume records a new volume with the given parameters. It must be
+// called with s.mu held.
+func (s *environState) newVolume(p storage.VolumeParams) *dummyVolume {
+	s.maxVolumeId++
+	v := &dummyVolume{
+		volume: storage.Volume{
+			Tag:      p.Tag,
+			VolumeId: fmt.Sprintf("vol-%d", s.maxVolumeId),
+			Serial:   fmt.Sprintf("dummy-serial-%d", s.maxVolumeId),
+			Size:     p.Size,
+		},
+		attached: make(map[names.MachineTag]instance.Id),
+	}
+	s.volumes[v.volume.VolumeId] = v
+	return v
+}
+
+func volumeAttachment(v *dummyVolume, machine names.MachineTag) storage.VolumeAttachment {
+	return storage.VolumeAttachment{
+		Volume:     v.volume.Tag,
+		Machine:    machine,
+		DeviceName: "xvd-" + v.volume.VolumeId,
+	}
+}
+
+// CreateVolumes is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	defer delay()
+	if err := vs.env.checkBroken("CreateVolumes"); err != nil {
+		return nil, nil, err
+	}
+	estate, err := vs.env.state()
+	if err != nil {
+		return nil, nil, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	var volumes []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, p := range params {
+		v := estate.newVolume(p)
+		volumes = append(volumes, v.volume)
+		if p.Attachment != nil && p.Attachment.InstanceId != "" {
+			v.attached[p.Attachment.Machine] = p.Attachment.InstanceId
+			attachments = append(attachments, volumeAttachment(v, p.Attachment.Machine))
+		}
+	}
+	estate.ops <- OpCreateVolumes{Env: vs.env.name, Volumes: volumes}
+	return volumes, attachments, nil
+}
+
+// DescribeVolumes is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) DescribeVolumes(volIds []string) ([]storage.Volume, error) {
+	defer delay()
+	if err := vs.env.checkBroken("DescribeVolumes"); err != nil {
+		return nil, err
+	}
+	estate, err := vs.env.state()
+	if err != nil {
+		return nil, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	volumes := make([]storage.Volume, len(volIds))
+	for i, id := range volIds {
+		v, ok := estate.volumes[id]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", id)
+		}
+		volumes[i] = v.volume
+	}
+	return volumes, nil
+}
+
+// DestroyVolumes is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) DestroyVolumes(volIds []string) []error {
+	defer delay()
+	errs := make([]error, len(volIds))
+	if err := vs.env.checkBroken("DestroyVolumes"); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	estate, err := vs.env.state()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	for i, id := range volIds {
+		v, ok := estate.volumes[id]
+		switch {
+		case !ok:
+			errs[i] = errors.NotFoundf("volume %q", id)
+		case len(v.attached) > 0:
+			errs[i] = errors.Errorf("volume %q is attached", id)
+		default:
+			delete(estate.volumes, id)
+		}
+	}
+	estate.ops <- OpDestroyVolumes{Env: vs.env.name, VolumeIds: volIds}
+	return errs
+}
+
+// ValidateVolumeParams is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) ValidateVolumeParams(params storage.VolumeParams) error {
+	if params.Size == 0 {
+		return errors.NotValidf("volume size 0")
+	}
+	return nil
+}
+
+// AttachVolumes is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) AttachVolumes(params []storage.VolumeAttachmentParams) ([]storage.VolumeAttachment, error) {
+	defer delay()
+	if err := vs.env.checkBroken("AttachVolumes"); err != nil {
+		return nil, err
+	}
+	estate, err := vs.env.state()
+	if err != nil {
+		return nil, err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	attachments := make([]storage.VolumeAttachment, len(params))
+	for i, p := range params {
+		v, ok := estate.volumes[p.VolumeId]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", p.VolumeId)
+		}
+		if _, ok := estate.insts[p.InstanceId]; !ok {
+			return nil, errors.NotFoundf("instance %q", p.InstanceId)
+		}
+		v.attached[p.Machine] = p.InstanceId
+		attachments[i] = volumeAttachment(v, p.Machine)
+	}
+	estate.ops <- OpAttachVolumes{Env: vs.env.name, Attachments: attachments}
+	return attachments, nil
+}
+
+// DetachVolumes is defined on the storage.VolumeSource interface.
+func (vs *volumeSource) DetachVolumes(params []storage.VolumeAttachmentParams) error {
+	defer delay()
+	if err := vs.env.checkBroken("DetachVolumes"); err != nil {
+		return err
+	}
+	estate, err := vs.env.state()
+	if err != nil {
+		return err
+	}
+	estate.mu.Lock()
+	defer estate.mu.Unlock()
+	for _, p := range params {
+		v, ok := estate.volumes[p.VolumeId]
+		if !ok {
+			return errors.NotFoundf("volume %q", p.VolumeId)
+		}
+		delete(v.attached, p.Machine)
+	}
+	estate.ops <- OpDetachVolumes{Env: vs.env.name, Params: params}
+	return nil
+}
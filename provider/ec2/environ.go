@@ -16,6 +16,7 @@ import (
 	"gopkg.in/amz.v3/ec2"
 	"gopkg.in/amz.v3/s3"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
@@ -76,6 +77,7 @@ var _ environs.NetworkingEnviron = (*environ)(nil)
 var _ simplestreams.HasRegion = (*environ)(nil)
 var _ state.Prechecker = (*environ)(nil)
 var _ state.InstanceDistributor = (*environ)(nil)
+var _ environs.EnvironIngressRules = (*environ)(nil)
 
 type defaultVpc struct {
 	hasDefaultVpc bool
@@ -216,6 +218,34 @@ func (e *environ) SupportedArchitectures() ([]string, error) {
 	return e.supportedArchitectures, err
 }
 
+// InstanceTypes is specified on the environs.InstanceMetadataLister
+// interface.
+func (e *environ) InstanceTypes() ([]instances.InstanceType, error) {
+	return instanceTypesWithCosts(e.ecfg().region())
+}
+
+// Images is specified on the environs.InstanceMetadataLister interface.
+func (e *environ) Images(series string) ([]instances.Image, error) {
+	sources, err := environs.ImageMetadataSources(e)
+	if err != nil {
+		return nil, err
+	}
+	cloudSpec, err := e.Region()
+	if err != nil {
+		return nil, err
+	}
+	imageConstraint := imagemetadata.NewImageConstraint(simplestreams.LookupParams{
+		CloudSpec: cloudSpec,
+		Series:    []string{series},
+		Stream:    e.Config().ImageStream(),
+	})
+	matchingImages, _, err := imagemetadata.Fetch(sources, imageConstraint, signedImageDataOnly)
+	if err != nil {
+		return nil, err
+	}
+	return instances.ImageMetadataToImages(filterImages(matchingImages, nil)), nil
+}
+
 // SupportsAddressAllocation is specified on environs.Networking.
 func (e *environ) SupportsAddressAllocation(_ network.Id) (bool, error) {
 	_, hasDefaultVpc, err := e.defaultVpc()
@@ -227,6 +257,10 @@ func (e *environ) SupportsAddressAllocation(_ network.Id) (bool, error) {
 
 var unsupportedConstraints = []string{
 	constraints.Tags,
+	constraints.RootDiskSource,
+	constraints.Zones,
+	constraints.Gpus,
+	constraints.GpuType,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
@@ -469,6 +503,11 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 	if err != nil {
 		return nil, err
 	}
+	if args.StatusCallback != nil {
+		args.StatusCallback(params.StatusImageSelected, spec.Image.Id, map[string]interface{}{
+			"instance-type": spec.InstanceType.Name,
+		})
+	}
 	tools, err := args.Tools.Match(tools.Filter{Arch: spec.Image.Arch})
 	if err != nil {
 		return nil, errors.Errorf("chosen architecture %v not present in %v", spec.Image.Arch, arches)
@@ -962,37 +1001,40 @@ func (e *environ) Destroy() error {
 	return e.Storage().RemoveAll()
 }
 
-func portsToIPPerms(ports []network.PortRange) []ec2.IPPerm {
-	ipPerms := make([]ec2.IPPerm, len(ports))
-	for i, p := range ports {
+func rulesToIPPerms(rules []network.IngressRule) []ec2.IPPerm {
+	ipPerms := make([]ec2.IPPerm, len(rules))
+	for i, r := range rules {
 		ipPerms[i] = ec2.IPPerm{
-			Protocol:  p.Protocol,
-			FromPort:  p.FromPort,
-			ToPort:    p.ToPort,
-			SourceIPs: []string{"0.0.0.0/0"},
+			Protocol:  r.Protocol,
+			FromPort:  r.FromPort,
+			ToPort:    r.ToPort,
+			SourceIPs: r.SourceCIDRs,
+		}
+		if len(r.SourceCIDRs) == 0 {
+			ipPerms[i].SourceIPs = []string{network.AnyCIDR}
 		}
 	}
 	return ipPerms
 }
 
-func (e *environ) openPortsInGroup(name string, ports []network.PortRange) error {
-	if len(ports) == 0 {
+func (e *environ) openRulesInGroup(name string, rules []network.IngressRule) error {
+	if len(rules) == 0 {
 		return nil
 	}
-	// Give permissions for anyone to access the given ports.
+	// Give permissions for the rules' sources to access their ports.
 	g, err := e.groupByName(name)
 	if err != nil {
 		return err
 	}
-	ipPerms := portsToIPPerms(ports)
+	ipPerms := rulesToIPPerms(rules)
 	_, err = e.ec2().AuthorizeSecurityGroup(g, ipPerms)
 	if err != nil && ec2ErrCode(err) == "InvalidPermission.Duplicate" {
-		if len(ports) == 1 {
+		if len(rules) == 1 {
 			return nil
 		}
-		// If there's more than one port and we get a duplicate error,
-		// then we go through authorizing each port individually,
-		// otherwise the ports that were *not* duplicates will have
+		// If there's more than one rule and we get a duplicate error,
+		// then we go through authorizing each rule individually,
+		// otherwise the rules that were *not* duplicates will have
 		// been ignored
 		for i := range ipPerms {
 			_, err := e.ec2().AuthorizeSecurityGroup(g, ipPerms[i:i+1])
@@ -1008,74 +1050,125 @@ func (e *environ) openPortsInGroup(name string, ports []network.PortRange) error
 	return nil
 }
 
-func (e *environ) closePortsInGroup(name string, ports []network.PortRange) error {
-	if len(ports) == 0 {
+func (e *environ) closeRulesInGroup(name string, rules []network.IngressRule) error {
+	if len(rules) == 0 {
 		return nil
 	}
-	// Revoke permissions for anyone to access the given ports.
+	// Revoke permissions for the rules' sources to access their ports.
 	// Note that ec2 allows the revocation of permissions that aren't
 	// granted, so this is naturally idempotent.
 	g, err := e.groupByName(name)
 	if err != nil {
 		return err
 	}
-	_, err = e.ec2().RevokeSecurityGroup(g, portsToIPPerms(ports))
+	_, err = e.ec2().RevokeSecurityGroup(g, rulesToIPPerms(rules))
 	if err != nil {
 		return fmt.Errorf("cannot close ports: %v", err)
 	}
 	return nil
 }
 
-func (e *environ) portsInGroup(name string) (ports []network.PortRange, err error) {
+// rulesInGroup returns the ingress rules of the named group. EC2
+// merges the permissions for a port range, so a rule is returned for
+// each port range with all the sources that may access it.
+func (e *environ) rulesInGroup(name string) (rules []network.IngressRule, err error) {
 	group, err := e.groupInfoByName(name)
 	if err != nil {
 		return nil, err
 	}
 	for _, p := range group.IPPerms {
-		if len(p.SourceIPs) != 1 {
+		if len(p.SourceIPs) == 0 {
 			logger.Warningf("unexpected IP permission found: %v", p)
 			continue
 		}
-		ports = append(ports, network.PortRange{
+		portRange := network.PortRange{
 			Protocol: p.Protocol,
 			FromPort: p.FromPort,
 			ToPort:   p.ToPort,
-		})
+		}
+		rule, err := network.NewIngressRule(portRange, p.SourceIPs...)
+		if err != nil {
+			logger.Warningf("unexpected IP permission found: %v (%v)", p, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	network.SortIngressRules(rules)
+	return rules, nil
+}
+
+// portsInGroup returns the port ranges of the named group that may be
+// accessed from any address.
+func (e *environ) portsInGroup(name string) (ports []network.PortRange, err error) {
+	rules, err := e.rulesInGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.IsUnrestricted() {
+			ports = append(ports, rule.PortRange)
+		}
 	}
 	network.SortPortRanges(ports)
 	return ports, nil
 }
 
-func (e *environ) OpenPorts(ports []network.PortRange) error {
+func (e *environ) checkGlobalFirewallMode(operation string) error {
 	if e.Config().FirewallMode() != config.FwGlobal {
-		return fmt.Errorf("invalid firewall mode %q for opening ports on environment",
-			e.Config().FirewallMode())
+		return fmt.Errorf("invalid firewall mode %q for %s environment",
+			e.Config().FirewallMode(), operation)
+	}
+	return nil
+}
+
+func (e *environ) OpenPorts(ports []network.PortRange) error {
+	return e.OpenIngressRules(network.IngressRulesFromPortRanges(ports))
+}
+
+func (e *environ) ClosePorts(ports []network.PortRange) error {
+	return e.CloseIngressRules(network.IngressRulesFromPortRanges(ports))
+}
+
+func (e *environ) Ports() ([]network.PortRange, error) {
+	if err := e.checkGlobalFirewallMode("retrieving ports from"); err != nil {
+		return nil, err
+	}
+	return e.portsInGroup(e.globalGroupName())
+}
+
+// OpenIngressRules is specified in the environs.EnvironIngressRules
+// interface.
+func (e *environ) OpenIngressRules(rules []network.IngressRule) error {
+	if err := e.checkGlobalFirewallMode("opening ports on"); err != nil {
+		return err
 	}
-	if err := e.openPortsInGroup(e.globalGroupName(), ports); err != nil {
+	if err := e.openRulesInGroup(e.globalGroupName(), rules); err != nil {
 		return err
 	}
-	logger.Infof("opened ports in global group: %v", ports)
+	logger.Infof("opened ports in global group: %v", rules)
 	return nil
 }
 
-func (e *environ) ClosePorts(ports []network.PortRange) error {
-	if e.Config().FirewallMode() != config.FwGlobal {
-		return fmt.Errorf("invalid firewall mode %q for closing ports on environment",
-			e.Config().FirewallMode())
+// CloseIngressRules is specified in the environs.EnvironIngressRules
+// interface.
+func (e *environ) CloseIngressRules(rules []network.IngressRule) error {
+	if err := e.checkGlobalFirewallMode("closing ports on"); err != nil {
+		return err
 	}
-	if err := e.closePortsInGroup(e.globalGroupName(), ports); err != nil {
+	if err := e.closeRulesInGroup(e.globalGroupName(), rules); err != nil {
 		return err
 	}
-	logger.Infof("closed ports in global group: %v", ports)
+	logger.Infof("closed ports in global group: %v", rules)
 	return nil
 }
 
-func (e *environ) Ports() ([]network.PortRange, error) {
-	if e.Config().FirewallMode() != config.FwGlobal {
-		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from environment",
-			e.Config().FirewallMode())
+// IngressRules is specified in the environs.EnvironIngressRules
+// interface.
+func (e *environ) IngressRules() ([]network.IngressRule, error) {
+	if err := e.checkGlobalFirewallMode("retrieving ports from"); err != nil {
+		return nil, err
 	}
-	return e.portsInGroup(e.globalGroupName())
+	return e.rulesInGroup(e.globalGroupName())
 }
 
 func (*environ) Provider() environs.EnvironProvider {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/network"
 )
 
@@ -82,18 +83,18 @@ func pInt(i uint64) *uint64 {
 	return &i
 }
 
-func (*Suite) TestPortsToIPPerms(c *gc.C) {
+func (*Suite) TestRulesToIPPerms(c *gc.C) {
 	testCases := []struct {
 		about    string
-		ports    []network.PortRange
+		rules    []network.IngressRule
 		expected []amzec2.IPPerm
 	}{{
 		about: "single port",
-		ports: []network.PortRange{{
+		rules: network.IngressRulesFromPortRanges([]network.PortRange{{
 			FromPort: 80,
 			ToPort:   80,
 			Protocol: "tcp",
-		}},
+		}}),
 		expected: []amzec2.IPPerm{{
 			Protocol:  "tcp",
 			FromPort:  80,
@@ -102,11 +103,11 @@ func (*Suite) TestPortsToIPPerms(c *gc.C) {
 		}},
 	}, {
 		about: "multiple ports",
-		ports: []network.PortRange{{
+		rules: network.IngressRulesFromPortRanges([]network.PortRange{{
 			FromPort: 80,
 			ToPort:   82,
 			Protocol: "tcp",
-		}},
+		}}),
 		expected: []amzec2.IPPerm{{
 			Protocol:  "tcp",
 			FromPort:  80,
@@ -115,7 +116,7 @@ func (*Suite) TestPortsToIPPerms(c *gc.C) {
 		}},
 	}, {
 		about: "multiple port ranges",
-		ports: []network.PortRange{{
+		rules: network.IngressRulesFromPortRanges([]network.PortRange{{
 			FromPort: 80,
 			ToPort:   82,
 			Protocol: "tcp",
@@ -123,7 +124,7 @@ func (*Suite) TestPortsToIPPerms(c *gc.C) {
 			FromPort: 100,
 			ToPort:   120,
 			Protocol: "tcp",
-		}},
+		}}),
 		expected: []amzec2.IPPerm{{
 			Protocol:  "tcp",
 			FromPort:  80,
@@ -135,11 +136,28 @@ func (*Suite) TestPortsToIPPerms(c *gc.C) {
 			ToPort:    120,
 			SourceIPs: []string{"0.0.0.0/0"},
 		}},
+	}, {
+		about: "restricted sources",
+		rules: []network.IngressRule{
+			network.MustNewIngressRule(network.MustParsePortRange("80/tcp"), "192.168.0.0/16", "10.0.0.0/8"),
+		},
+		expected: []amzec2.IPPerm{{
+			Protocol:  "tcp",
+			FromPort:  80,
+			ToPort:    80,
+			SourceIPs: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		}},
 	}}
 
 	for i, t := range testCases {
 		c.Logf("test %d: %s", i, t.about)
-		ipperms := portsToIPPerms(t.ports)
+		ipperms := rulesToIPPerms(t.rules)
 		c.Assert(ipperms, gc.DeepEquals, t.expected)
 	}
 }
+
+func (*Suite) TestHourlyCost(c *gc.C) {
+	cost, currency := providerInstance.HourlyCost(instances.InstanceType{Name: "m3.medium", Cost: 70})
+	c.Assert(cost, gc.Equals, 0.07)
+	c.Assert(currency, gc.Equals, "USD")
+}
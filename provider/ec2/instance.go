@@ -9,6 +9,7 @@ import (
 
 	"gopkg.in/amz.v3/ec2"
 
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
@@ -26,6 +27,7 @@ func (inst *ec2Instance) String() string {
 }
 
 var _ instance.Instance = (*ec2Instance)(nil)
+var _ environs.InstanceIngressRules = (*ec2Instance)(nil)
 
 func (inst *ec2Instance) getInstance() *ec2.Instance {
 	inst.mu.Lock()
@@ -87,41 +89,67 @@ func (inst *ec2Instance) Addresses() ([]network.Address, error) {
 	return addresses, nil
 }
 
-func (inst *ec2Instance) OpenPorts(machineId string, ports []network.PortRange) error {
+func (inst *ec2Instance) checkInstanceFirewallMode(operation string) error {
 	if inst.e.Config().FirewallMode() != config.FwInstance {
-		return fmt.Errorf("invalid firewall mode %q for opening ports on instance",
-			inst.e.Config().FirewallMode())
+		return fmt.Errorf("invalid firewall mode %q for %s instance",
+			inst.e.Config().FirewallMode(), operation)
 	}
-	name := inst.e.machineGroupName(machineId)
-	if err := inst.e.openPortsInGroup(name, ports); err != nil {
-		return err
-	}
-	logger.Infof("opened ports in security group %s: %v", name, ports)
 	return nil
 }
 
+func (inst *ec2Instance) OpenPorts(machineId string, ports []network.PortRange) error {
+	return inst.OpenIngressRules(machineId, network.IngressRulesFromPortRanges(ports))
+}
+
 func (inst *ec2Instance) ClosePorts(machineId string, ports []network.PortRange) error {
-	if inst.e.Config().FirewallMode() != config.FwInstance {
-		return fmt.Errorf("invalid firewall mode %q for closing ports on instance",
-			inst.e.Config().FirewallMode())
+	return inst.CloseIngressRules(machineId, network.IngressRulesFromPortRanges(ports))
+}
+
+func (inst *ec2Instance) Ports(machineId string) ([]network.PortRange, error) {
+	if err := inst.checkInstanceFirewallMode("retrieving ports from"); err != nil {
+		return nil, err
 	}
 	name := inst.e.machineGroupName(machineId)
-	if err := inst.e.closePortsInGroup(name, ports); err != nil {
+	ranges, err := inst.e.portsInGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// OpenIngressRules is specified in the environs.InstanceIngressRules
+// interface.
+func (inst *ec2Instance) OpenIngressRules(machineId string, rules []network.IngressRule) error {
+	if err := inst.checkInstanceFirewallMode("opening ports on"); err != nil {
+		return err
+	}
+	name := inst.e.machineGroupName(machineId)
+	if err := inst.e.openRulesInGroup(name, rules); err != nil {
 		return err
 	}
-	logger.Infof("closed ports in security group %s: %v", name, ports)
+	logger.Infof("opened ports in security group %s: %v", name, rules)
 	return nil
 }
 
-func (inst *ec2Instance) Ports(machineId string) ([]network.PortRange, error) {
-	if inst.e.Config().FirewallMode() != config.FwInstance {
-		return nil, fmt.Errorf("invalid firewall mode %q for retrieving ports from instance",
-			inst.e.Config().FirewallMode())
+// CloseIngressRules is specified in the environs.InstanceIngressRules
+// interface.
+func (inst *ec2Instance) CloseIngressRules(machineId string, rules []network.IngressRule) error {
+	if err := inst.checkInstanceFirewallMode("closing ports on"); err != nil {
+		return err
 	}
 	name := inst.e.machineGroupName(machineId)
-	ranges, err := inst.e.portsInGroup(name)
-	if err != nil {
+	if err := inst.e.closeRulesInGroup(name, rules); err != nil {
+		return err
+	}
+	logger.Infof("closed ports in security group %s: %v", name, rules)
+	return nil
+}
+
+// IngressRules is specified in the environs.InstanceIngressRules
+// interface.
+func (inst *ec2Instance) IngressRules(machineId string) ([]network.IngressRule, error) {
+	if err := inst.checkInstanceFirewallMode("retrieving ports from"); err != nil {
 		return nil, err
 	}
-	return ranges, nil
+	return inst.e.rulesInGroup(inst.e.machineGroupName(machineId))
 }
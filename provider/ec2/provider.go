@@ -13,6 +13,7 @@ import (
 
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instances"
 	"github.com/juju/juju/environs/simplestreams"
 	"github.com/juju/juju/juju/arch"
 )
@@ -27,6 +28,12 @@ func (environProvider) BoilerplateConfig() string {
 	return boilerplateConfig[1:]
 }
 
+// HourlyCost is specified in the environs.CostEstimator interface.
+// Instance type costs are held in USDe-3/hour.
+func (p environProvider) HourlyCost(itype instances.InstanceType) (float64, string) {
+	return float64(itype.Cost) / 1000, "USD"
+}
+
 // RestrictedConfigAttributes is specified in the EnvironProvider interface.
 func (p environProvider) RestrictedConfigAttributes() []string {
 	return []string{"region"}
@@ -67,6 +67,10 @@ func (env *environ) lookupArchitectures() ([]string, error) {
 var unsupportedConstraints = []string{
 	constraints.Tags,
 	constraints.Networks,
+	constraints.RootDiskSource,
+	constraints.Zones,
+	constraints.Gpus,
+	constraints.GpuType,
 }
 
 // instanceTypeConstraints defines the fields defined on each of the
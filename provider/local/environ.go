@@ -51,6 +51,7 @@ const bootstrapInstanceId instance.Id = "localhost"
 
 // localEnviron implements Environ.
 var _ environs.Environ = (*localEnviron)(nil)
+var _ environs.ContainerCapability = (*localEnviron)(nil)
 
 type localEnviron struct {
 	common.SupportsUnitPlacementPolicy
@@ -70,6 +71,19 @@ func (*localEnviron) SupportedArchitectures() ([]string, error) {
 	return []string{localArch}, nil
 }
 
+// SupportedContainerTypes is specified on the environs.ContainerCapability
+// interface. Nested LXC containers cannot host containers, so machines in
+// an environment of LXC containers cannot host any; KVM machines can host
+// LXC containers.
+func (env *localEnviron) SupportedContainerTypes() ([]instance.ContainerType, error) {
+	env.localMutex.Lock()
+	defer env.localMutex.Unlock()
+	if env.config.container() == instance.KVM {
+		return []instance.ContainerType{instance.LXC}, nil
+	}
+	return nil, nil
+}
+
 func (*localEnviron) PrecheckInstance(series string, cons constraints.Value, placement string) error {
 	if placement != "" {
 		return fmt.Errorf("unknown placement directive: %s", placement)
@@ -341,6 +355,8 @@ var unsupportedConstraints = []string{
 	constraints.CpuPower,
 	constraints.InstanceType,
 	constraints.Tags,
+	constraints.RootDiskSource,
+	constraints.Zones,
 }
 
 // ConstraintsValidator is defined on the Environs interface.
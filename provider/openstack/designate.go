@@ -0,0 +1,172 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"launchpad.net/goose/client"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/network"
+)
+
+var _ environs.DNSRegistrar = (*environ)(nil)
+
+// designateService is the service type of the OpenStack DNS service,
+// Designate, in the service catalog.
+const designateService = "dns"
+
+// designateRequest sends a request to the v2 API of the DNS service.
+// The goose library has no Designate client, so requests are made
+// directly.
+var designateRequest = func(c client.AuthenticatingClient, method, apiCall string, requestData *goosehttp.RequestData) error {
+	return c.SendRequest(method, designateService, "v2/"+apiCall, requestData)
+}
+
+// designateZone describes a zone in the DNS service.
+type designateZone struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// designateRecordSet describes the records of one type for a name in
+// a zone of the DNS service.
+type designateRecordSet struct {
+	Id      string   `json:"id,omitempty"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl,omitempty"`
+	Records []string `json:"records"`
+}
+
+// SetDNSRecords is specified on the environs.DNSRegistrar interface.
+func (e *environ) SetDNSRecords(zone, hostname string, ttl int, addresses []network.Address) error {
+	c := e.authenticatingClient()
+	zoneId, err := designateZoneId(c, zone)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	records := map[string][]string{"A": nil, "AAAA": nil}
+	for _, addr := range addresses {
+		switch addr.Type {
+		case network.IPv4Address:
+			records["A"] = append(records["A"], addr.Value)
+		case network.IPv6Address:
+			records["AAAA"] = append(records["AAAA"], addr.Value)
+		}
+	}
+	for _, rrType := range []string{"A", "AAAA"} {
+		recordSet := designateRecordSet{
+			Name:    fqdn(hostname),
+			Type:    rrType,
+			TTL:     ttl,
+			Records: records[rrType],
+		}
+		if err := setDesignateRecordSet(c, zoneId, recordSet); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// RemoveDNSRecords is specified on the environs.DNSRegistrar interface.
+func (e *environ) RemoveDNSRecords(zone, hostname string) error {
+	c := e.authenticatingClient()
+	zoneId, err := designateZoneId(c, zone)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, rrType := range []string{"A", "AAAA"} {
+		recordSet := designateRecordSet{Name: fqdn(hostname), Type: rrType}
+		if err := setDesignateRecordSet(c, zoneId, recordSet); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// designateZoneId returns the id of the zone with the given name.
+func designateZoneId(c client.AuthenticatingClient, zone string) (string, error) {
+	var resp struct {
+		Zones []designateZone `json:"zones"`
+	}
+	requestData := goosehttp.RequestData{
+		Params:    &url.Values{"name": {fqdn(zone)}},
+		RespValue: &resp,
+	}
+	if err := designateRequest(c, client.GET, "zones", &requestData); err != nil {
+		return "", gooseerrors.Newf(err, "", "failed to get DNS zone %q", zone)
+	}
+	if len(resp.Zones) == 0 {
+		return "", errors.NotFoundf("DNS zone %q", zone)
+	}
+	return resp.Zones[0].Id, nil
+}
+
+// setDesignateRecordSet creates, replaces or, if it has no records,
+// deletes the record set with the name and type of the given one.
+func setDesignateRecordSet(c client.AuthenticatingClient, zoneId string, recordSet designateRecordSet) error {
+	var resp struct {
+		RecordSets []designateRecordSet `json:"recordsets"`
+	}
+	requestData := goosehttp.RequestData{
+		Params:    &url.Values{"name": {recordSet.Name}, "type": {recordSet.Type}},
+		RespValue: &resp,
+	}
+	recordSetsURL := "zones/" + zoneId + "/recordsets"
+	if err := designateRequest(c, client.GET, recordSetsURL, &requestData); err != nil {
+		return gooseerrors.Newf(err, "", "failed to get %s records of %q", recordSet.Type, recordSet.Name)
+	}
+	var existingId string
+	if len(resp.RecordSets) > 0 {
+		existingId = resp.RecordSets[0].Id
+	}
+
+	var method, apiCall string
+	switch {
+	case len(recordSet.Records) == 0 && existingId == "":
+		return nil
+	case len(recordSet.Records) == 0:
+		method, apiCall = client.DELETE, recordSetsURL+"/"+existingId
+		requestData = goosehttp.RequestData{
+			ExpectedStatus: []int{http.StatusAccepted, http.StatusNoContent},
+		}
+	case existingId == "":
+		method, apiCall = client.POST, recordSetsURL
+		requestData = goosehttp.RequestData{
+			ReqValue:       recordSet,
+			ExpectedStatus: []int{http.StatusCreated, http.StatusAccepted},
+		}
+	default:
+		// The name and type of a record set cannot be changed.
+		update := struct {
+			TTL     int      `json:"ttl,omitempty"`
+			Records []string `json:"records"`
+		}{recordSet.TTL, recordSet.Records}
+		method, apiCall = client.PUT, recordSetsURL+"/"+existingId
+		requestData = goosehttp.RequestData{
+			ReqValue:       update,
+			ExpectedStatus: []int{http.StatusOK, http.StatusAccepted},
+		}
+	}
+	if err := designateRequest(c, method, apiCall, &requestData); err != nil {
+		return gooseerrors.Newf(err, "", "failed to set %s records of %q", recordSet.Type, recordSet.Name)
+	}
+	return nil
+}
+
+// fqdn returns the given domain name with a trailing dot, as the DNS
+// service expects.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
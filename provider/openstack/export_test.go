@@ -71,6 +71,11 @@ func InstanceFloatingIP(inst instance.Instance) *nova.FloatingIP {
 var (
 	NovaListAvailabilityZones   = &novaListAvailabilityZones
 	AvailabilityZoneAllocations = &availabilityZoneAllocations
+	RunServerFromVolume         = &runServerFromVolume
+	FlavorExtraSpecs            = &flavorExtraSpecs
+	DesignateRequest            = &designateRequest
+	NeutronRequest              = &neutronRequest
+	FlavorGpus                  = flavorGpus
 )
 
 var indexData = `
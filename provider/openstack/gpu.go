@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"launchpad.net/goose/client"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+)
+
+const (
+	// pciPassthroughAliasSpec is the flavor extra spec naming the PCI
+	// devices, and how many of each, passed through to an instance,
+	// for example "nvidia-k80:2".
+	pciPassthroughAliasSpec = "pci_passthrough:alias"
+
+	// vgpuResourceSpec is the flavor extra spec giving the number of
+	// virtual GPUs allocated to an instance.
+	vgpuResourceSpec = "resources:VGPU"
+)
+
+// flavorExtraSpecs returns the extra specs of the flavor with the given
+// id. The goose nova client does not expose them, so the request is
+// made directly.
+var flavorExtraSpecs = func(c client.AuthenticatingClient, flavorId string) (map[string]string, error) {
+	var resp struct {
+		ExtraSpecs map[string]string `json:"extra_specs"`
+	}
+	requestData := goosehttp.RequestData{RespValue: &resp}
+	url := fmt.Sprintf("flavors/%s/os-extra_specs", flavorId)
+	if err := c.SendRequest(client.GET, "compute", url, &requestData); err != nil {
+		return nil, gooseerrors.Newf(err, "", "failed to get extra specs of flavor %q", flavorId)
+	}
+	return resp.ExtraSpecs, nil
+}
+
+// flavorGpus returns the number and type of the GPUs given to instances
+// of a flavor with the given extra specs. The type of passed through
+// GPUs is the part of their alias before any "-", so that devices
+// aliased "nvidia-k80" satisfy the gpu-type=nvidia constraint; the type
+// of virtual GPUs is unknown.
+func flavorGpus(extraSpecs map[string]string) (count uint64, gpuType string) {
+	if aliases, ok := extraSpecs[pciPassthroughAliasSpec]; ok {
+		for _, alias := range strings.Split(aliases, ",") {
+			name, n := strings.TrimSpace(alias), uint64(1)
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				var err error
+				if n, err = strconv.ParseUint(name[i+1:], 10, 64); err != nil {
+					logger.Warningf("ignoring invalid PCI passthrough alias %q", alias)
+					continue
+				}
+				name = name[:i]
+			}
+			count += n
+			if i := strings.Index(name, "-"); i >= 0 {
+				name = name[:i]
+			}
+			switch {
+			case gpuType == "":
+				gpuType = strings.ToLower(name)
+			case gpuType != strings.ToLower(name):
+				// Devices of mixed types cannot be matched
+				// against a single type.
+				gpuType = ""
+			}
+		}
+	}
+	if vgpus, ok := extraSpecs[vgpuResourceSpec]; ok {
+		n, err := strconv.ParseUint(vgpus, 10, 64)
+		if err != nil {
+			logger.Warningf("ignoring invalid %s %q", vgpuResourceSpec, vgpus)
+		} else if n > 0 {
+			count += n
+			gpuType = ""
+		}
+	}
+	return count, gpuType
+}
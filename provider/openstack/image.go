@@ -30,6 +30,16 @@ func findInstanceSpec(e *environ, ic *instances.InstanceConstraint) (*instances.
 			RootDisk: uint64(flavor.Disk * 1024),
 			// tags not currently supported on openstack
 		}
+		if ic.Constraints.HasGpus() {
+			// Only flavors with GPUs can satisfy the constraints,
+			// so it is worth fetching the extra specs describing
+			// them.
+			extraSpecs, err := flavorExtraSpecs(e.authenticatingClient(), flavor.Id)
+			if err != nil {
+				return nil, err
+			}
+			instanceType.Gpus, instanceType.GpuType = flavorGpus(extraSpecs)
+		}
 		allInstanceTypes = append(allInstanceTypes, instanceType)
 	}
 
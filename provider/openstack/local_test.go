@@ -5,6 +5,7 @@ package openstack_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -17,6 +18,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"launchpad.net/goose/client"
+	goosehttp "launchpad.net/goose/http"
 	"launchpad.net/goose/identity"
 	"launchpad.net/goose/nova"
 	"launchpad.net/goose/testservices/hook"
@@ -876,6 +878,26 @@ func (s *localServerSuite) TestConstraintsValidatorVocab(c *gc.C) {
 	cons = constraints.MustParse("instance-type=foo")
 	_, err = validator.Validate(cons)
 	c.Assert(err, gc.ErrorMatches, "invalid constraint value: instance-type=foo\nvalid values are:.*")
+	cons = constraints.MustParse("zones=test-unknown")
+	_, err = validator.Validate(cons)
+	c.Assert(err, gc.ErrorMatches, "invalid constraint value: zones=test-unknown\nvalid values are:.*")
+	cons = constraints.MustParse("root-disk-source=ssd")
+	_, err = validator.Validate(cons)
+	c.Assert(err, gc.ErrorMatches, "invalid constraint value: root-disk-source=ssd\nvalid values are:.*")
+	cons = constraints.MustParse("zones=test-available root-disk-source=volume")
+	_, err = validator.Validate(cons)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *localServerSuite) TestConstraintsValidatorZonesUnsupported(c *gc.C) {
+	s.srv.Service.Nova.SetAvailabilityZones() // no availability zone support
+	env := s.Open(c)
+	validator, err := env.ConstraintsValidator()
+	c.Assert(err, jc.ErrorIsNil)
+	cons := constraints.MustParse("arch=amd64 zones=az1")
+	unsupported, err := validator.Validate(cons)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unsupported, jc.SameContents, []string{"zones"})
 }
 
 func (s *localServerSuite) TestConstraintsMerge(c *gc.C) {
@@ -945,6 +967,14 @@ func (t *localServerSuite) TestPrecheckInstanceAvailZoneUnknown(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"`)
 }
 
+func (t *localServerSuite) TestPrecheckInstanceAvailZoneNotInZones(c *gc.C) {
+	env := t.Prepare(c)
+	placement := "zone=test-available"
+	cons := constraints.MustParse("zones=test-unavailable")
+	err := env.PrecheckInstance(coretesting.FakeDefaultSeries, cons, placement)
+	c.Assert(err, gc.ErrorMatches, `availability zone "test-available" does not satisfy zones constraint "test-unavailable"`)
+}
+
 func (t *localServerSuite) TestPrecheckInstanceAvailZonesUnsupported(c *gc.C) {
 	t.srv.Service.Nova.SetAvailabilityZones() // no availability zone support
 	env := t.Prepare(c)
@@ -1591,3 +1621,378 @@ func (t *localServerSuite) TestStartInstanceDistributionAZNotImplemented(c *gc.C
 	inst, _ := testing.AssertStartInstance(c, env, "1")
 	c.Assert(openstack.InstanceServerDetail(inst).AvailabilityZone, gc.Equals, "")
 }
+
+func (t *localServerSuite) TestStartInstanceZonesConstraint(c *gc.C) {
+	t.srv.Service.Nova.SetAvailabilityZones(
+		nova.AvailabilityZone{
+			Name: "az1",
+			State: nova.AvailabilityZoneState{
+				Available: true,
+			},
+		},
+		nova.AvailabilityZone{
+			Name: "az2",
+			State: nova.AvailabilityZoneState{
+				Available: true,
+			},
+		},
+	)
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	params := environs.StartInstanceParams{Constraints: constraints.MustParse("zones=az2")}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(openstack.InstanceServerDetail(result.Instance).AvailabilityZone, gc.Equals, "az2")
+	c.Assert(*result.Hardware.AvailabilityZone, gc.Equals, "az2")
+}
+
+func (t *localServerSuite) TestStartInstanceZonesConstraintUnsatisfied(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	params := environs.StartInstanceParams{Constraints: constraints.MustParse("zones=test-unavailable")}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.ErrorMatches, `no available zones satisfy zones constraint "test-unavailable"`)
+
+	params = environs.StartInstanceParams{
+		Constraints: constraints.MustParse("zones=test-unavailable"),
+		Placement:   "zone=test-available",
+	}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.ErrorMatches, `availability zone "test-available" does not satisfy zones constraint "test-unavailable"`)
+}
+
+func (t *localServerSuite) TestStartInstanceRootDiskSourceVolume(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var bootOpts nova.RunServerOpts
+	var bootSize uint64
+	t.PatchValue(openstack.RunServerFromVolume, func(
+		client client.AuthenticatingClient, opts nova.RunServerOpts, userData []byte, sizeGiB uint64,
+	) (*nova.Entity, error) {
+		bootOpts, bootSize = opts, sizeGiB
+		// The test service does not support booting from
+		// volumes, so start an ordinary server instead.
+		opts.UserData = userData
+		return nova.New(client).RunServer(opts)
+	})
+
+	params := environs.StartInstanceParams{
+		Constraints: constraints.MustParse("root-disk-source=volume root-disk=20G"),
+	}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bootOpts.ImageId, gc.Not(gc.Equals), "")
+	c.Assert(bootSize, gc.Equals, uint64(20))
+	c.Assert(*result.Hardware.RootDisk, gc.Equals, uint64(20*1024))
+}
+
+func (t *localServerSuite) TestStartInstanceRootDiskSourceLocal(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	t.PatchValue(openstack.RunServerFromVolume, func(
+		client.AuthenticatingClient, nova.RunServerOpts, []byte, uint64,
+	) (*nova.Entity, error) {
+		c.Fatalf("unexpected boot from volume")
+		return nil, nil
+	})
+	params := environs.StartInstanceParams{
+		Constraints: constraints.MustParse("root-disk-source=local"),
+	}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (t *localServerSuite) TestStartInstanceGpus(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var flavorIds []string
+	t.PatchValue(openstack.FlavorExtraSpecs, func(_ client.AuthenticatingClient, flavorId string) (map[string]string, error) {
+		flavorIds = append(flavorIds, flavorId)
+		// The test service offers no GPU flavors, so
+		// pretend that the smallest has two.
+		if flavorId == "1" {
+			return map[string]string{"pci_passthrough:alias": "nvidia-k80:2"}, nil
+		}
+		return nil, nil
+	})
+	params := environs.StartInstanceParams{
+		Constraints: constraints.MustParse("gpus=2 gpu-type=nvidia"),
+	}
+	result, err := testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(flavorIds, gc.Not(gc.HasLen), 0)
+	c.Assert(*result.Hardware.Gpus, gc.Equals, uint64(2))
+	c.Assert(*result.Hardware.GpuType, gc.Equals, "nvidia")
+}
+
+func (t *localServerSuite) TestStartInstanceGpusUnavailable(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	t.PatchValue(openstack.FlavorExtraSpecs, func(client.AuthenticatingClient, string) (map[string]string, error) {
+		return nil, nil
+	})
+	params := environs.StartInstanceParams{
+		Constraints: constraints.MustParse("gpus=1"),
+	}
+	_, err = testing.StartInstanceWithParams(env, "1", params, nil)
+	c.Assert(err, gc.ErrorMatches, `.*no instance types in some-region matching constraints "gpus=1"`)
+}
+
+func (t *localServerSuite) TestStartInstanceWithoutGpusSkipsExtraSpecs(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	t.PatchValue(openstack.FlavorExtraSpecs, func(client.AuthenticatingClient, string) (map[string]string, error) {
+		c.Fatalf("unexpected request for flavor extra specs")
+		return nil, nil
+	})
+	_, err = testing.StartInstanceWithParams(env, "1", environs.StartInstanceParams{}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// fakeDesignate records the requests made to the DNS service, and
+// holds the record sets of the "example.com." zone.
+type fakeDesignate struct {
+	c          *gc.C
+	requests   []string
+	recordSets map[string]map[string]interface{}
+}
+
+func (f *fakeDesignate) request(_ client.AuthenticatingClient, method, apiCall string, requestData *goosehttp.RequestData) error {
+	request := method + " " + apiCall
+	if requestData.Params != nil {
+		request += "?" + requestData.Params.Encode()
+	}
+	f.requests = append(f.requests, request)
+	switch {
+	case method == client.GET && apiCall == "zones":
+		zones := []map[string]string{}
+		if requestData.Params.Get("name") == "example.com." {
+			zones = append(zones, map[string]string{"id": "zone-1", "name": "example.com."})
+		}
+		return setRespValue(requestData, map[string]interface{}{"zones": zones})
+	case method == client.GET:
+		recordSets := []map[string]interface{}{}
+		key := requestData.Params.Get("name") + " " + requestData.Params.Get("type")
+		if recordSet, ok := f.recordSets[key]; ok {
+			recordSets = append(recordSets, recordSet)
+		}
+		return setRespValue(requestData, map[string]interface{}{"recordsets": recordSets})
+	case method == client.POST:
+		var recordSet map[string]interface{}
+		data, err := json.Marshal(requestData.ReqValue)
+		f.c.Assert(err, jc.ErrorIsNil)
+		f.c.Assert(json.Unmarshal(data, &recordSet), jc.ErrorIsNil)
+		key := fmt.Sprintf("%s %s", recordSet["name"], recordSet["type"])
+		recordSet["id"] = key
+		f.recordSets[key] = recordSet
+		return nil
+	case method == client.PUT:
+		var update map[string]interface{}
+		data, err := json.Marshal(requestData.ReqValue)
+		f.c.Assert(err, jc.ErrorIsNil)
+		f.c.Assert(json.Unmarshal(data, &update), jc.ErrorIsNil)
+		key := strings.TrimPrefix(apiCall, "zones/zone-1/recordsets/")
+		f.recordSets[key]["records"] = update["records"]
+		return nil
+	case method == client.DELETE:
+		delete(f.recordSets, strings.TrimPrefix(apiCall, "zones/zone-1/recordsets/"))
+		return nil
+	}
+	f.c.Fatalf("unexpected request %q", request)
+	return nil
+}
+
+func setRespValue(requestData *goosehttp.RequestData, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, requestData.RespValue)
+}
+
+func (t *localServerSuite) TestSetDNSRecords(c *gc.C) {
+	env := t.Prepare(c)
+	registrar, ok := env.(environs.DNSRegistrar)
+	c.Assert(ok, jc.IsTrue)
+	designate := &fakeDesignate{c: c, recordSets: make(map[string]map[string]interface{})}
+	t.PatchValue(openstack.DesignateRequest, designate.request)
+
+	err := registrar.SetDNSRecords("example.com", "machine-0.env.example.com.", 60, []network.Address{
+		network.NewAddress("10.0.0.2", network.ScopeCloudLocal),
+		network.NewAddress("2001:db8::2", network.ScopePublic),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(designate.recordSets, jc.DeepEquals, map[string]map[string]interface{}{
+		"machine-0.env.example.com. A": {
+			"id": "machine-0.env.example.com. A", "name": "machine-0.env.example.com.",
+			"type": "A", "ttl": float64(60), "records": []interface{}{"10.0.0.2"},
+		},
+		"machine-0.env.example.com. AAAA": {
+			"id": "machine-0.env.example.com. AAAA", "name": "machine-0.env.example.com.",
+			"type": "AAAA", "ttl": float64(60), "records": []interface{}{"2001:db8::2"},
+		},
+	})
+
+	// Changed addresses replace the existing records, and record
+	// sets left without records are deleted.
+	designate.requests = nil
+	err = registrar.SetDNSRecords("example.com", "machine-0.env.example.com.", 60, []network.Address{
+		network.NewAddress("10.0.0.9", network.ScopeCloudLocal),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(designate.requests, jc.DeepEquals, []string{
+		"GET zones?name=example.com.",
+		"GET zones/zone-1/recordsets?name=machine-0.env.example.com.&type=A",
+		"PUT zones/zone-1/recordsets/machine-0.env.example.com. A",
+		"GET zones/zone-1/recordsets?name=machine-0.env.example.com.&type=AAAA",
+		"DELETE zones/zone-1/recordsets/machine-0.env.example.com. AAAA",
+	})
+	c.Assert(designate.recordSets, gc.HasLen, 1)
+	c.Assert(designate.recordSets["machine-0.env.example.com. A"]["records"], jc.DeepEquals, []interface{}{"10.0.0.9"})
+
+	err = registrar.RemoveDNSRecords("example.com", "machine-0.env.example.com.")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(designate.recordSets, gc.HasLen, 0)
+}
+
+func (t *localServerSuite) TestSetDNSRecordsZoneNotFound(c *gc.C) {
+	env := t.Prepare(c)
+	designate := &fakeDesignate{c: c, recordSets: make(map[string]map[string]interface{})}
+	t.PatchValue(openstack.DesignateRequest, designate.request)
+
+	err := env.(environs.DNSRegistrar).SetDNSRecords("example.org", "machine-0.env.example.org.", 60, nil)
+	c.Assert(err, gc.ErrorMatches, `DNS zone "example.org" not found`)
+}
+
+// fakeNeutron answers requests to the networking service from a
+// fixed set of networks, subnets and ports.
+func fakeNeutron(c *gc.C) func(client.AuthenticatingClient, string, string, *goosehttp.RequestData) error {
+	return func(_ client.AuthenticatingClient, method, apiCall string, requestData *goosehttp.RequestData) error {
+		c.Assert(method, gc.Equals, client.GET)
+		switch apiCall {
+		case "networks":
+			return setRespValue(requestData, map[string]interface{}{
+				"networks": []map[string]interface{}{
+					{"id": "net-1", "name": "private", "availability_zones": []string{"zone-b", "zone-a"}},
+					{"id": "net-2", "name": "shared", "availability_zone_hints": []string{"zone-a"}},
+					{"id": "net-3", "name": "everywhere"},
+				},
+			})
+		case "subnets":
+			return setRespValue(requestData, map[string]interface{}{
+				"subnets": []map[string]interface{}{
+					{"id": "sub-1", "network_id": "net-1", "cidr": "10.0.1.0/24"},
+					{"id": "sub-2", "network_id": "net-2", "cidr": "10.0.2.0/24"},
+					{"id": "sub-3", "network_id": "net-3", "cidr": "2001:db8::/64"},
+				},
+			})
+		case "ports":
+			c.Assert(requestData.Params.Get("device_id"), gc.Equals, "inst-1")
+			return setRespValue(requestData, map[string]interface{}{
+				"ports": []map[string]interface{}{{
+					"id":        "port-1",
+					"fixed_ips": []map[string]string{{"subnet_id": "sub-2", "ip_address": "10.0.2.5"}},
+				}},
+			})
+		}
+		c.Fatalf("unexpected request %s %s", method, apiCall)
+		return nil
+	}
+}
+
+func (t *localServerSuite) TestSubnets(c *gc.C) {
+	env := t.Prepare(c)
+	lister, ok := env.(environs.SubnetLister)
+	c.Assert(ok, jc.IsTrue)
+	t.PatchValue(openstack.NeutronRequest, fakeNeutron(c))
+
+	subnets, err := lister.Subnets("", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, jc.DeepEquals, []network.SubnetInfo{{
+		CIDR:              "10.0.1.0/24",
+		ProviderId:        "sub-1",
+		AvailabilityZones: []string{"zone-a", "zone-b"},
+	}, {
+		CIDR:              "10.0.2.0/24",
+		ProviderId:        "sub-2",
+		AvailabilityZones: []string{"zone-a"},
+	}, {
+		CIDR:       "2001:db8::/64",
+		ProviderId: "sub-3",
+	}})
+
+	subnets, err = lister.Subnets("", []network.Id{"sub-3"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 1)
+	c.Assert(subnets[0].ProviderId, gc.Equals, network.Id("sub-3"))
+
+	_, err = lister.Subnets("", []network.Id{"sub-3", "sub-9"})
+	c.Assert(err, gc.ErrorMatches, `subnets \[sub-9\] not found`)
+}
+
+func (t *localServerSuite) TestSubnetsOfInstance(c *gc.C) {
+	env := t.Prepare(c)
+	t.PatchValue(openstack.NeutronRequest, fakeNeutron(c))
+	lister := env.(environs.SubnetLister)
+
+	subnets, err := lister.Subnets("inst-1", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 1)
+	c.Assert(subnets[0].ProviderId, gc.Equals, network.Id("sub-2"))
+
+	_, err = lister.Subnets("inst-1", []network.Id{"sub-1"})
+	c.Assert(err, gc.ErrorMatches, `subnet "sub-1" on instance "inst-1" not found`)
+}
+
+type flavorGpusSuite struct{}
+
+var _ = gc.Suite(&flavorGpusSuite{})
+
+func (*flavorGpusSuite) TestFlavorGpus(c *gc.C) {
+	for i, test := range []struct {
+		extraSpecs map[string]string
+		count      uint64
+		gpuType    string
+	}{{
+		extraSpecs: nil,
+	}, {
+		extraSpecs: map[string]string{"pci_passthrough:alias": "nvidia-k80:2"},
+		count:      2,
+		gpuType:    "nvidia",
+	}, {
+		extraSpecs: map[string]string{"pci_passthrough:alias": "nvidia-k80:1, NVIDIA-m60:2"},
+		count:      3,
+		gpuType:    "nvidia",
+	}, {
+		extraSpecs: map[string]string{"pci_passthrough:alias": "nvidia-k80:1,amd-s7150:1"},
+		count:      2,
+	}, {
+		extraSpecs: map[string]string{"pci_passthrough:alias": "amd"},
+		count:      1,
+		gpuType:    "amd",
+	}, {
+		extraSpecs: map[string]string{"pci_passthrough:alias": "nvidia:many"},
+	}, {
+		extraSpecs: map[string]string{"resources:VGPU": "1"},
+		count:      1,
+	}} {
+		c.Logf("test %d: %v", i, test.extraSpecs)
+		count, gpuType := openstack.FlavorGpus(test.extraSpecs)
+		c.Check(count, gc.Equals, test.count)
+		c.Check(gpuType, gc.Equals, test.gpuType)
+	}
+}
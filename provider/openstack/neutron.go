@@ -0,0 +1,175 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/juju/errors"
+	"launchpad.net/goose/client"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+var _ environs.SubnetLister = (*environ)(nil)
+
+// neutronService is the service type of the OpenStack networking
+// service, Neutron, in the service catalog.
+const neutronService = "network"
+
+// neutronRequest sends a request to the v2.0 API of the networking
+// service. The goose library has no Neutron client, so requests are
+// made directly.
+var neutronRequest = func(c client.AuthenticatingClient, method, apiCall string, requestData *goosehttp.RequestData) error {
+	return c.SendRequest(method, neutronService, "v2.0/"+apiCall, requestData)
+}
+
+// neutronNetwork describes a network in the networking service.
+type neutronNetwork struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+
+	// AvailabilityZones holds the zones the network is available
+	// in. It is only reported when the availability zone extension
+	// is enabled.
+	AvailabilityZones []string `json:"availability_zones"`
+
+	// AvailabilityZoneHints holds the zones the network was
+	// requested to be available in, which is used when the zones
+	// it is actually available in are not reported.
+	AvailabilityZoneHints []string `json:"availability_zone_hints"`
+}
+
+// zones returns the availability zones the network is available in,
+// or nil if it is available in all of them.
+func (n neutronNetwork) zones() []string {
+	zones := n.AvailabilityZones
+	if len(zones) == 0 {
+		zones = n.AvailabilityZoneHints
+	}
+	if len(zones) == 0 {
+		return nil
+	}
+	zones = append([]string(nil), zones...)
+	sort.Strings(zones)
+	return zones
+}
+
+// neutronSubnet describes a subnet of a network in the networking
+// service.
+type neutronSubnet struct {
+	Id        string `json:"id"`
+	NetworkId string `json:"network_id"`
+	CIDR      string `json:"cidr"`
+}
+
+// neutronPort describes a port connecting a device, such as a server,
+// to a network in the networking service.
+type neutronPort struct {
+	Id       string `json:"id"`
+	FixedIPs []struct {
+		SubnetId string `json:"subnet_id"`
+	} `json:"fixed_ips"`
+}
+
+// Subnets is specified on the environs.SubnetLister interface. The
+// availability zones of each subnet are those of its network.
+// Addresses cannot be allocated by the provider, so no part of any
+// subnet is reported as allocatable.
+func (e *environ) Subnets(inst instance.Id, subnetIds []network.Id) ([]network.SubnetInfo, error) {
+	c := e.authenticatingClient()
+	wanted := make(map[string]bool)
+	for _, id := range subnetIds {
+		wanted[string(id)] = true
+	}
+	if inst != "" {
+		connected, err := neutronServerSubnets(c, inst)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(subnetIds) == 0 {
+			wanted = connected
+		} else {
+			for id := range wanted {
+				if !connected[id] {
+					return nil, errors.NotFoundf("subnet %q on instance %q", id, inst)
+				}
+			}
+		}
+		if len(wanted) == 0 {
+			return nil, nil
+		}
+	}
+
+	var networksResp struct {
+		Networks []neutronNetwork `json:"networks"`
+	}
+	requestData := goosehttp.RequestData{RespValue: &networksResp}
+	if err := neutronRequest(c, client.GET, "networks", &requestData); err != nil {
+		return nil, gooseerrors.Newf(err, "", "failed to list networks")
+	}
+	networks := make(map[string]neutronNetwork)
+	for _, n := range networksResp.Networks {
+		networks[n.Id] = n
+	}
+
+	var subnetsResp struct {
+		Subnets []neutronSubnet `json:"subnets"`
+	}
+	requestData = goosehttp.RequestData{RespValue: &subnetsResp}
+	if err := neutronRequest(c, client.GET, "subnets", &requestData); err != nil {
+		return nil, gooseerrors.Newf(err, "", "failed to list subnets")
+	}
+	var results []network.SubnetInfo
+	found := make(map[string]bool)
+	for _, subnet := range subnetsResp.Subnets {
+		if len(wanted) > 0 && !wanted[subnet.Id] {
+			continue
+		}
+		found[subnet.Id] = true
+		results = append(results, network.SubnetInfo{
+			CIDR:              subnet.CIDR,
+			ProviderId:        network.Id(subnet.Id),
+			AvailabilityZones: networks[subnet.NetworkId].zones(),
+		})
+	}
+	var notFound []string
+	for id := range wanted {
+		if !found[id] {
+			notFound = append(notFound, id)
+		}
+	}
+	if len(notFound) > 0 {
+		sort.Strings(notFound)
+		return nil, errors.NotFoundf("subnets %v", notFound)
+	}
+	return results, nil
+}
+
+// neutronServerSubnets returns the ids of the subnets the given server
+// is connected to.
+func neutronServerSubnets(c client.AuthenticatingClient, inst instance.Id) (map[string]bool, error) {
+	var resp struct {
+		Ports []neutronPort `json:"ports"`
+	}
+	requestData := goosehttp.RequestData{
+		Params:    &url.Values{"device_id": {string(inst)}},
+		RespValue: &resp,
+	}
+	if err := neutronRequest(c, client.GET, "ports", &requestData); err != nil {
+		return nil, gooseerrors.Newf(err, "", "failed to list ports of instance %q", inst)
+	}
+	subnets := make(map[string]bool)
+	for _, port := range resp.Ports {
+		for _, ip := range port.FixedIPs {
+			subnets[ip.SubnetId] = true
+		}
+	}
+	return subnets, nil
+}
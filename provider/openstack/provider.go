@@ -24,6 +24,7 @@ import (
 	"launchpad.net/goose/nova"
 	"launchpad.net/goose/swift"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
@@ -423,6 +424,12 @@ func (inst *openstackInstance) hardwareCharacteristics() *instance.HardwareChara
 		}
 		hc.CpuCores = &inst.instType.CpuCores
 		hc.CpuPower = inst.instType.CpuPower
+		if inst.instType.Gpus > 0 {
+			hc.Gpus = &inst.instType.Gpus
+			if inst.instType.GpuType != "" {
+				hc.GpuType = &inst.instType.GpuType
+			}
+		}
 		// tags not currently supported on openstack
 	}
 	hc.AvailabilityZone = &inst.serverDetail.AvailabilityZone
@@ -543,6 +550,13 @@ func (e *environ) ecfg() *environConfig {
 	return ecfg
 }
 
+func (e *environ) authenticatingClient() client.AuthenticatingClient {
+	e.ecfgMutex.Lock()
+	c := e.client
+	e.ecfgMutex.Unlock()
+	return c
+}
+
 func (e *environ) nova() *nova.Client {
 	e.ecfgMutex.Lock()
 	nova := e.novaUnlocked
@@ -581,7 +595,23 @@ func (e *environ) ConstraintsValidator() (constraints.Validator, error) {
 	validator.RegisterConflicts(
 		[]string{constraints.InstanceType},
 		[]string{constraints.Mem, constraints.Arch, constraints.RootDisk, constraints.CpuCores})
-	validator.RegisterUnsupported(unsupportedConstraints)
+	unsupported := append([]string{}, unsupportedConstraints...)
+	zones, err := e.AvailabilityZones()
+	if errors.IsNotImplemented(err) {
+		// Without availability zones, the zones
+		// constraint cannot be honoured.
+		unsupported = append(unsupported, constraints.Zones)
+	} else if err != nil {
+		return nil, err
+	} else {
+		zoneNames := make([]string, len(zones))
+		for i, zone := range zones {
+			zoneNames[i] = zone.Name()
+		}
+		validator.RegisterVocabulary(constraints.Zones, zoneNames)
+	}
+	validator.RegisterUnsupported(unsupported)
+	validator.RegisterVocabulary(constraints.RootDiskSource, []string{rootDiskSourceLocal, rootDiskSourceVolume})
 	supportedArches, err := e.SupportedArchitectures()
 	if err != nil {
 		return nil, err
@@ -679,10 +709,38 @@ func (e *environ) parsePlacement(placement string) (*openstackPlacement, error)
 	return nil, fmt.Errorf("unknown placement directive: %v", placement)
 }
 
+// zoneAllowed reports whether the named availability zone satisfies
+// the zones constraint, if one is specified.
+func zoneAllowed(cons constraints.Value, zone string) bool {
+	if !cons.HaveZones() {
+		return true
+	}
+	for _, allowed := range *cons.Zones {
+		if allowed == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPlacementZone returns an error if the availability zone chosen
+// by placement does not satisfy the zones constraint.
+func checkPlacementZone(cons constraints.Value, zone string) error {
+	if !zoneAllowed(cons, zone) {
+		return fmt.Errorf("availability zone %q does not satisfy zones constraint %q",
+			zone, strings.Join(*cons.Zones, ","))
+	}
+	return nil
+}
+
 // PrecheckInstance is defined on the state.Prechecker interface.
 func (e *environ) PrecheckInstance(series string, cons constraints.Value, placement string) error {
 	if placement != "" {
-		if _, err := e.parsePlacement(placement); err != nil {
+		placement, err := e.parsePlacement(placement)
+		if err != nil {
+			return err
+		}
+		if err := checkPlacementZone(cons, placement.availabilityZone.Name); err != nil {
 			return err
 		}
 	}
@@ -946,12 +1004,15 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		if !placement.availabilityZone.State.Available {
 			return nil, fmt.Errorf("availability zone %q is unavailable", placement.availabilityZone.Name)
 		}
+		if err := checkPlacementZone(args.Constraints, placement.availabilityZone.Name); err != nil {
+			return nil, err
+		}
 		availabilityZones = append(availabilityZones, placement.availabilityZone.Name)
 	}
 
 	// If no availability zone is specified, then automatically spread across
-	// the known zones for optimal spread across the instance distribution
-	// group.
+	// the known zones, or those allowed by the zones constraint, for optimal
+	// spread across the instance distribution group.
 	if len(availabilityZones) == 0 {
 		var group []instance.Id
 		var err error
@@ -964,12 +1025,22 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		zoneInstances, err := availabilityZoneAllocations(e, group)
 		if errors.IsNotImplemented(err) {
 			// Availability zones are an extension, so we may get a
-			// not implemented error; ignore these.
+			// not implemented error; ignore these, unless zones
+			// were explicitly requested.
+			if args.Constraints.HaveZones() {
+				return nil, errors.Annotate(err, "cannot satisfy zones constraint")
+			}
 		} else if err != nil {
 			return nil, err
 		} else {
 			for _, zone := range zoneInstances {
-				availabilityZones = append(availabilityZones, zone.ZoneName)
+				if zoneAllowed(args.Constraints, zone.ZoneName) {
+					availabilityZones = append(availabilityZones, zone.ZoneName)
+				}
+			}
+			if len(availabilityZones) == 0 && args.Constraints.HaveZones() {
+				return nil, fmt.Errorf("no available zones satisfy zones constraint %q",
+					strings.Join(*args.Constraints.Zones, ","))
 			}
 		}
 		if len(availabilityZones) == 0 {
@@ -982,17 +1053,40 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		return nil, fmt.Errorf("starting instances with networks is not supported yet.")
 	}
 
+	bootFromVolume, err := usesRootVolume(args.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	instanceCons := args.Constraints
+	if bootFromVolume {
+		// The root disk is provided by a volume, so the
+		// flavor's disk need not satisfy the root-disk
+		// constraint.
+		instanceCons.RootDisk = nil
+	}
 	series := args.Tools.OneSeries()
 	arches := args.Tools.Arches()
 	spec, err := findInstanceSpec(e, &instances.InstanceConstraint{
 		Region:      e.ecfg().region(),
 		Series:      series,
 		Arches:      arches,
-		Constraints: args.Constraints,
+		Constraints: instanceCons,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if args.StatusCallback != nil {
+		args.StatusCallback(params.StatusImageSelected, spec.Image.Id, map[string]interface{}{
+			"instance-type": spec.InstanceType.Name,
+		})
+	}
+	instType := spec.InstanceType
+	var rootVolumeSize uint64
+	if bootFromVolume {
+		rootVolumeSize = rootVolumeSizeGiB(args.Constraints, instType)
+		instType.RootDisk = rootVolumeSize * 1024
+		logger.Debugf("booting from a %dGiB volume", rootVolumeSize)
+	}
 	tools, err := args.Tools.Match(tools.Filter{Arch: spec.Image.Arch})
 	if err != nil {
 		return nil, fmt.Errorf("chosen architecture %v not present in %v", spec.Image.Arch, arches)
@@ -1050,7 +1144,11 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 			AvailabilityZone:   availZone,
 		}
 		for a := shortAttempt.Start(); a.Next(); {
-			server, err = e.nova().RunServer(opts)
+			if bootFromVolume {
+				server, err = runServerFromVolume(e.authenticatingClient(), opts, userData, rootVolumeSize)
+			} else {
+				server, err = e.nova().RunServer(opts)
+			}
 			if err == nil || !gooseerrors.IsNotFound(err) {
 				break
 			}
@@ -1072,7 +1170,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.St
 		e:            e,
 		serverDetail: detail,
 		arch:         &spec.Image.Arch,
-		instType:     &spec.InstanceType,
+		instType:     &instType,
 	}
 	logger.Infof("started instance %q", inst.Id())
 	if withPublicIP {
@@ -0,0 +1,129 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package openstack
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"launchpad.net/goose/client"
+	gooseerrors "launchpad.net/goose/errors"
+	goosehttp "launchpad.net/goose/http"
+	"launchpad.net/goose/nova"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/instances"
+)
+
+const (
+	// rootDiskSourceLocal is the root-disk-source constraint value
+	// for an instance whose root disk is provided by its flavor.
+	rootDiskSourceLocal = "local"
+
+	// rootDiskSourceVolume is the root-disk-source constraint value
+	// for an instance booted from a volume created from its image.
+	rootDiskSourceVolume = "volume"
+
+	// defaultRootVolumeSize is the size, in GiB, of the volume an
+	// instance boots from when neither the root-disk constraint nor
+	// the flavor specify one.
+	defaultRootVolumeSize = 8
+)
+
+// usesRootVolume reports whether the constraints require an instance
+// to boot from a volume.
+func usesRootVolume(cons constraints.Value) (bool, error) {
+	if !cons.HasRootDiskSource() {
+		return false, nil
+	}
+	switch source := *cons.RootDiskSource; source {
+	case rootDiskSourceLocal:
+		return false, nil
+	case rootDiskSourceVolume:
+		return true, nil
+	default:
+		return false, fmt.Errorf("root disk source %q not supported", source)
+	}
+}
+
+// rootVolumeSizeGiB returns the size, in GiB, of the volume to boot
+// an instance of the given type from. The root-disk constraint takes
+// precedence over the flavor's disk size.
+func rootVolumeSizeGiB(cons constraints.Value, instType instances.InstanceType) uint64 {
+	sizeMiB := instType.RootDisk
+	if cons.RootDisk != nil && *cons.RootDisk > 0 {
+		sizeMiB = *cons.RootDisk
+	}
+	if sizeMiB == 0 {
+		return defaultRootVolumeSize
+	}
+	return (sizeMiB + 1023) / 1024
+}
+
+// blockDeviceMapping describes a block device to attach to a new
+// server, in the form accepted by the os-volumes_boot extension.
+type blockDeviceMapping struct {
+	BootIndex           int    `json:"boot_index"`
+	UUID                string `json:"uuid"`
+	SourceType          string `json:"source_type"`
+	DestinationType     string `json:"destination_type"`
+	VolumeSize          uint64 `json:"volume_size"`
+	DeleteOnTermination bool   `json:"delete_on_termination"`
+}
+
+// volumeBootServer holds the details of a server to be booted from a
+// new volume. The goose RunServerOpts cannot express block device
+// mappings, so the request is made directly.
+type volumeBootServer struct {
+	Name                string                   `json:"name"`
+	FlavorId            string                   `json:"flavorRef"`
+	ImageId             string                   `json:"imageRef"`
+	UserData            string                   `json:"user_data,omitempty"`
+	SecurityGroupNames  []nova.SecurityGroupName `json:"security_groups"`
+	Networks            []nova.ServerNetworks    `json:"networks"`
+	AvailabilityZone    string                   `json:"availability_zone,omitempty"`
+	BlockDeviceMappings []blockDeviceMapping     `json:"block_device_mapping_v2"`
+}
+
+// runServerFromVolume starts a server with the given options, booting
+// from a new volume of the given size in GiB created from the image in
+// opts. The volume is deleted when the server is terminated.
+var runServerFromVolume = func(
+	c client.AuthenticatingClient, opts nova.RunServerOpts, userData []byte, sizeGiB uint64,
+) (*nova.Entity, error) {
+	var req struct {
+		Server volumeBootServer `json:"server"`
+	}
+	req.Server = volumeBootServer{
+		Name:               opts.Name,
+		FlavorId:           opts.FlavorId,
+		SecurityGroupNames: opts.SecurityGroupNames,
+		Networks:           opts.Networks,
+		AvailabilityZone:   opts.AvailabilityZone,
+		BlockDeviceMappings: []blockDeviceMapping{{
+			BootIndex:           0,
+			UUID:                opts.ImageId,
+			SourceType:          "image",
+			DestinationType:     "volume",
+			VolumeSize:          sizeGiB,
+			DeleteOnTermination: true,
+		}},
+	}
+	if userData != nil {
+		req.Server.UserData = base64.StdEncoding.EncodeToString(userData)
+	}
+	var resp struct {
+		Server nova.Entity `json:"server"`
+	}
+	requestData := goosehttp.RequestData{
+		ReqValue:       req,
+		RespValue:      &resp,
+		ExpectedStatus: []int{http.StatusAccepted},
+	}
+	if err := c.SendRequest(client.POST, "compute", "os-volumes_boot", &requestData); err != nil {
+		return nil, gooseerrors.Newf(err, "", "failed to run a server from a volume with %#v", req.Server)
+	}
+	return &resp.Server, nil
+}
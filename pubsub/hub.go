@@ -0,0 +1,241 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package pubsub provides a hub that allows workers running in the
+// same agent to publish and subscribe to local events, rather than
+// each polling the API for changes they are interested in.
+package pubsub
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+var logger = loggo.GetLogger("juju.pubsub")
+
+// DefaultHistorySize is the number of recent events kept by a hub
+// created with NewHub for introspection.
+const DefaultHistorySize = 100
+
+// Topic identifies a kind of event.
+type Topic string
+
+// Event holds an event published on a hub.
+type Event struct {
+	// Topic is the topic the event was published on.
+	Topic Topic
+
+	// Published holds the time the event was published.
+	Published time.Time
+
+	// Data holds the event payload. Its type is the type
+	// registered for the topic.
+	Data interface{}
+}
+
+// Handler is called with each event published on a topic
+// that has been subscribed to.
+type Handler func(Event)
+
+// Publisher is implemented by types that events can be published on.
+type Publisher interface {
+	// Publish delivers data to all subscribers of the given topic.
+	Publish(topic Topic, data interface{}) error
+}
+
+// Subscriber is implemented by types that can be subscribed to.
+type Subscriber interface {
+	// Subscribe arranges for handler to be called with every
+	// event subsequently published on the given topic. The
+	// returned function cancels the subscription.
+	Subscribe(topic Topic, handler Handler) (unsubscribe func(), err error)
+}
+
+// Hub delivers events published on a topic to all subscribers of
+// that topic. Each subscriber receives events in the order they were
+// published, on its own goroutine, so a slow subscriber does not
+// delay publishers or other subscribers.
+type Hub struct {
+	mu          sync.Mutex
+	types       map[Topic]reflect.Type
+	subscribers map[Topic]map[int]*subscriber
+	nextId      int
+	history     []Event
+	historySize int
+}
+
+var (
+	_ Publisher  = (*Hub)(nil)
+	_ Subscriber = (*Hub)(nil)
+)
+
+// NewHub returns a new hub that knows about the standard agent topics
+// and remembers the most recent historySize events.
+func NewHub(historySize int) *Hub {
+	h := &Hub{
+		types:       make(map[Topic]reflect.Type),
+		subscribers: make(map[Topic]map[int]*subscriber),
+		historySize: historySize,
+	}
+	for topic, example := range standardTopics {
+		if err := h.RegisterTopic(topic, example); err != nil {
+			panic(err)
+		}
+	}
+	return h
+}
+
+// RegisterTopic registers a topic on which values with the same type
+// as example may be published.
+func (h *Hub) RegisterTopic(topic Topic, example interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.types[topic]; ok {
+		return errors.AlreadyExistsf("topic %q", topic)
+	}
+	h.types[topic] = reflect.TypeOf(example)
+	return nil
+}
+
+// Publish is part of the Publisher interface. It returns an error if
+// the topic is not registered, or data is not of the type registered
+// for the topic.
+func (h *Hub) Publish(topic Topic, data interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	expected, ok := h.types[topic]
+	if !ok {
+		return errors.NotFoundf("topic %q", topic)
+	}
+	if actual := reflect.TypeOf(data); actual != expected {
+		return errors.Errorf("cannot publish %v on topic %q, expected %v", actual, topic, expected)
+	}
+	event := Event{
+		Topic:     topic,
+		Published: time.Now(),
+		Data:      data,
+	}
+	logger.Tracef("publishing %q: %#v", topic, data)
+	h.record(event)
+	for _, sub := range h.subscribers[topic] {
+		sub.notify(event)
+	}
+	return nil
+}
+
+// record adds the event to the hub's history, discarding the oldest
+// event if the history is full. It must be called with h.mu held.
+func (h *Hub) record(event Event) {
+	if h.historySize <= 0 {
+		return
+	}
+	if len(h.history) >= h.historySize {
+		copy(h.history, h.history[1:])
+		h.history = h.history[:len(h.history)-1]
+	}
+	h.history = append(h.history, event)
+}
+
+// Subscribe is part of the Subscriber interface.
+func (h *Hub) Subscribe(topic Topic, handler Handler) (func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.types[topic]; !ok {
+		return nil, errors.NotFoundf("topic %q", topic)
+	}
+	id := h.nextId
+	h.nextId++
+	sub := newSubscriber(handler)
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = make(map[int]*subscriber)
+	}
+	h.subscribers[topic][id] = sub
+	unsubscribe := func() {
+		h.mu.Lock()
+		delete(h.subscribers[topic], id)
+		h.mu.Unlock()
+		sub.close()
+	}
+	return unsubscribe, nil
+}
+
+// RecentEvents returns the most recently published events, oldest
+// first, for introspection.
+func (h *Hub) RecentEvents() []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Event(nil), h.history...)
+}
+
+// subscriber queues events for a single handler, and calls the
+// handler for each of them in turn on its own goroutine.
+type subscriber struct {
+	handler Handler
+
+	mu      sync.Mutex
+	pending []Event
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+func newSubscriber(handler Handler) *subscriber {
+	s := &subscriber{
+		handler: handler,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// notify queues the event for delivery.
+func (s *subscriber) notify(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.pending = append(s.pending, event)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// close stops delivery of events and waits for any handler call in
+// progress to complete. Queued events that have not been delivered
+// are discarded.
+func (s *subscriber) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.pending = nil
+	close(s.wake)
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *subscriber) loop() {
+	defer close(s.done)
+	for range s.wake {
+		for {
+			s.mu.Lock()
+			if len(s.pending) == 0 || s.closed {
+				s.mu.Unlock()
+				break
+			}
+			event := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+			s.handler(event)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pubsub_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/pubsub"
+	coretesting "github.com/juju/juju/testing"
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type hubSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&hubSuite{})
+
+func (s *hubSuite) TestPublishSubscribe(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	events := make(chan pubsub.Event, 10)
+	unsubscribe, err := hub.Subscribe(pubsub.RebootPending, func(e pubsub.Event) {
+		events <- e
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer unsubscribe()
+
+	data := pubsub.RebootPendingEvent{MachineTag: "machine-0"}
+	err = hub.Publish(pubsub.RebootPending, data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	select {
+	case e := <-events:
+		c.Assert(e.Topic, gc.Equals, pubsub.RebootPending)
+		c.Assert(e.Data, jc.DeepEquals, data)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("event not delivered")
+	}
+}
+
+func (s *hubSuite) TestOtherTopicsNotDelivered(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	events := make(chan pubsub.Event, 10)
+	unsubscribe, err := hub.Subscribe(pubsub.RebootPending, func(e pubsub.Event) {
+		events <- e
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer unsubscribe()
+
+	err = hub.Publish(pubsub.NetworkConfigChanged, pubsub.NetworkConfigChangedEvent{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	select {
+	case e := <-events:
+		c.Fatalf("unexpected event %#v", e)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *hubSuite) TestSlowSubscriberDoesNotBlockPublisher(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	block := make(chan struct{})
+	events := make(chan pubsub.Event, 10)
+	unsubscribe, err := hub.Subscribe(pubsub.RebootPending, func(e pubsub.Event) {
+		<-block
+		events <- e
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer unsubscribe()
+
+	for i := 0; i < 3; i++ {
+		err := hub.Publish(pubsub.RebootPending, pubsub.RebootPendingEvent{Shutdown: i%2 == 0})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	close(block)
+	for i := 0; i < 3; i++ {
+		select {
+		case e := <-events:
+			c.Assert(e.Data, jc.DeepEquals, pubsub.RebootPendingEvent{Shutdown: i%2 == 0})
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("event %d not delivered", i)
+		}
+	}
+}
+
+func (s *hubSuite) TestUnsubscribe(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	events := make(chan pubsub.Event, 10)
+	unsubscribe, err := hub.Subscribe(pubsub.RebootPending, func(e pubsub.Event) {
+		events <- e
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	unsubscribe()
+	// Unsubscribing twice is harmless.
+	unsubscribe()
+
+	err = hub.Publish(pubsub.RebootPending, pubsub.RebootPendingEvent{})
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case e := <-events:
+		c.Fatalf("unexpected event %#v", e)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *hubSuite) TestPublishWrongType(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	err := hub.Publish(pubsub.RebootPending, pubsub.NetworkConfigChangedEvent{})
+	c.Assert(err, gc.ErrorMatches, `cannot publish pubsub.NetworkConfigChangedEvent on topic "reboot-pending", expected pubsub.RebootPendingEvent`)
+}
+
+func (s *hubSuite) TestUnknownTopic(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	err := hub.Publish("no-such-topic", "data")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = hub.Subscribe("no-such-topic", func(pubsub.Event) {})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *hubSuite) TestRegisterTopic(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	err := hub.RegisterTopic("custom", "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = hub.Publish("custom", "hello")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = hub.RegisterTopic(pubsub.RebootPending, "")
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *hubSuite) TestRecentEvents(c *gc.C) {
+	hub := pubsub.NewHub(2)
+	for _, tag := range []string{"machine-0", "machine-1", "machine-2"} {
+		err := hub.Publish(pubsub.RebootPending, pubsub.RebootPendingEvent{MachineTag: tag})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	events := hub.RecentEvents()
+	c.Assert(events, gc.HasLen, 2)
+	c.Assert(events[0].Data, jc.DeepEquals, pubsub.RebootPendingEvent{MachineTag: "machine-1"})
+	c.Assert(events[1].Data, jc.DeepEquals, pubsub.RebootPendingEvent{MachineTag: "machine-2"})
+}
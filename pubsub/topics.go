@@ -0,0 +1,41 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package pubsub
+
+const (
+	// NetworkConfigChanged is published by the networker when it
+	// has changed the machine's network configuration. Its payload
+	// is a NetworkConfigChangedEvent.
+	NetworkConfigChanged Topic = "network-config-changed"
+
+	// RebootPending is published by the reboot worker when the
+	// machine is about to be rebooted or shut down. Its payload is
+	// a RebootPendingEvent.
+	RebootPending Topic = "reboot-pending"
+)
+
+// NetworkConfigChangedEvent is the payload of NetworkConfigChanged
+// events.
+type NetworkConfigChangedEvent struct {
+	// Interfaces holds the names of the network interfaces
+	// whose configuration was written.
+	Interfaces []string
+}
+
+// RebootPendingEvent is the payload of RebootPending events.
+type RebootPendingEvent struct {
+	// MachineTag holds the tag of the machine being rebooted.
+	MachineTag string
+
+	// Shutdown is true if the machine is being shut down
+	// rather than rebooted.
+	Shutdown bool
+}
+
+// standardTopics holds the topics known to every hub created
+// with NewHub, along with an example of their payload.
+var standardTopics = map[Topic]interface{}{
+	NetworkConfigChanged: NetworkConfigChangedEvent{},
+	RebootPending:        RebootPendingEvent{},
+}
@@ -0,0 +1,753 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The raft package implements the Raft consensus algorithm, which
+// keeps a replicated state machine consistent across a small, fixed
+// cluster of nodes for as long as a majority of them can communicate.
+// It is used to hold state that must be agreed between the state
+// servers without a round trip to mongo.
+//
+// The implementation covers leader election, log replication and log
+// compaction by snapshots, as described in "In Search of an
+// Understandable Consensus Algorithm" by Ongaro and Ousterhout. The
+// membership of the cluster is fixed for the lifetime of a Node; to
+// change it, every node must be restarted with the new membership.
+package raft
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+)
+
+var logger = loggo.GetLogger("juju.raft")
+
+// Config holds the configuration of a Node.
+type Config struct {
+	// Id identifies the node within the cluster.
+	Id string
+
+	// Peers holds the ids of the other nodes in the cluster.
+	Peers []string
+
+	FSM       FSM
+	Transport Transport
+	Store     Store
+
+	// HeartbeatInterval is how often the leader sends entries or
+	// heartbeats to its followers.
+	HeartbeatInterval time.Duration
+
+	// ElectionTimeout is the minimum time a follower waits to hear
+	// from a leader before standing for election. The actual
+	// timeout is chosen at random from between it and twice it, so
+	// that nodes rarely stand at once. It must be several times
+	// HeartbeatInterval.
+	ElectionTimeout time.Duration
+
+	// SnapshotThreshold is the number of applied log entries after
+	// which the log is compacted into a snapshot.
+	SnapshotThreshold uint64
+}
+
+// Validate returns an error if the configuration is not valid.
+func (config Config) Validate() error {
+	if config.Id == "" {
+		return errors.NotValidf("empty Id")
+	}
+	if config.FSM == nil {
+		return errors.NotValidf("nil FSM")
+	}
+	if config.Transport == nil {
+		return errors.NotValidf("nil Transport")
+	}
+	if config.Store == nil {
+		return errors.NotValidf("nil Store")
+	}
+	if config.HeartbeatInterval <= 0 {
+		return errors.NotValidf("non-positive HeartbeatInterval")
+	}
+	if config.ElectionTimeout < 2*config.HeartbeatInterval {
+		return errors.NotValidf("ElectionTimeout less than twice HeartbeatInterval")
+	}
+	if config.SnapshotThreshold == 0 {
+		return errors.NotValidf("zero SnapshotThreshold")
+	}
+	for _, peer := range config.Peers {
+		if peer == config.Id {
+			return errors.NotValidf("node %q listed as its own peer", peer)
+		}
+	}
+	return nil
+}
+
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+func (r role) String() string {
+	switch r {
+	case follower:
+		return "follower"
+	case candidate:
+		return "candidate"
+	}
+	return "leader"
+}
+
+// proposal records a command proposed to the leader which is waiting
+// to be applied.
+type proposal struct {
+	term   uint64
+	result chan []byte
+}
+
+// Node is a member of a Raft cluster.
+type Node struct {
+	tomb   tomb.Tomb
+	config Config
+
+	// mu guards all the fields below. It is never held while
+	// communicating with other nodes.
+	mu sync.Mutex
+
+	role        role
+	leaderId    string
+	currentTerm uint64
+	votedFor    string
+
+	// log holds the entries following the last snapshot, preceded
+	// by an entry holding only the index and term of the last
+	// entry included in the snapshot.
+	log      []Entry
+	snapshot []byte
+
+	commitIndex uint64
+	lastApplied uint64
+
+	electionDeadline time.Time
+
+	// The following fields are only used by the leader.
+	nextIndex  map[string]uint64
+	matchIndex map[string]uint64
+	inflight   map[string]bool
+	proposals  map[uint64]proposal
+
+	// replicate is signalled to make the leader send new entries
+	// to its followers without waiting for the next heartbeat.
+	replicate chan struct{}
+}
+
+// NewNode starts a node with the given configuration, restoring any
+// state it has previously saved.
+func NewNode(config Config) (*Node, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	state, err := config.Store.Load()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot load raft state")
+	}
+	n := &Node{
+		config:      config,
+		currentTerm: state.Term,
+		votedFor:    state.VotedFor,
+		log: append([]Entry{{
+			Index: state.SnapshotIndex,
+			Term:  state.SnapshotTerm,
+		}}, state.Entries...),
+		snapshot:    state.Snapshot,
+		commitIndex: state.SnapshotIndex,
+		lastApplied: state.SnapshotIndex,
+		proposals:   make(map[uint64]proposal),
+		replicate:   make(chan struct{}, 1),
+	}
+	if state.Snapshot != nil {
+		if err := config.FSM.Restore(state.Snapshot); err != nil {
+			return nil, errors.Annotate(err, "cannot restore raft snapshot")
+		}
+	}
+	n.resetElectionDeadline()
+	if len(config.Peers) == 0 {
+		// A node without peers needs no votes, so it need not
+		// wait to hear from a leader before standing.
+		n.electionDeadline = time.Now()
+	}
+	go func() {
+		defer n.tomb.Done()
+		n.tomb.Kill(n.loop())
+	}()
+	return n, nil
+}
+
+// Kill is part of the worker.Worker interface.
+func (n *Node) Kill() {
+	n.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (n *Node) Wait() error {
+	return n.tomb.Wait()
+}
+
+// Id returns the id of the node.
+func (n *Node) Id() string {
+	return n.config.Id
+}
+
+// Leader returns the id of the node believed to be the leader of the
+// cluster, or the empty string if it is not known.
+func (n *Node) Leader() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leaderId
+}
+
+// Propose submits a command to be applied to the FSM of every node in
+// the cluster, and returns the result of applying it once it has been
+// committed. If the node is not the leader, a *NotLeaderError is
+// returned and the command should be proposed to the leader instead.
+// A command which times out may still be applied.
+func (n *Node) Propose(command []byte, timeout time.Duration) ([]byte, error) {
+	n.mu.Lock()
+	if n.role != leader {
+		leaderId := n.leaderId
+		n.mu.Unlock()
+		return nil, &NotLeaderError{Leader: leaderId}
+	}
+	entry := Entry{
+		Index:   n.lastIndex() + 1,
+		Term:    n.currentTerm,
+		Command: command,
+	}
+	n.log = append(n.log, entry)
+	if err := n.persist(); err != nil {
+		n.log = n.log[:len(n.log)-1]
+		n.mu.Unlock()
+		return nil, errors.Trace(err)
+	}
+	result := make(chan []byte, 1)
+	n.proposals[entry.Index] = proposal{term: entry.Term, result: result}
+	n.advanceCommitIndex()
+	n.mu.Unlock()
+	n.triggerReplication()
+
+	select {
+	case data, ok := <-result:
+		if !ok {
+			return nil, errors.New("command not committed: leadership lost")
+		}
+		return data, nil
+	case <-time.After(timeout):
+		return nil, errors.New("timed out waiting for command to be committed")
+	case <-n.tomb.Dying():
+		return nil, ErrStopped
+	}
+}
+
+func (n *Node) triggerReplication() {
+	select {
+	case n.replicate <- struct{}{}:
+	default:
+	}
+}
+
+func (n *Node) loop() error {
+	ticker := time.NewTicker(n.config.HeartbeatInterval)
+	defer ticker.Stop()
+	defer n.failProposals()
+	for {
+		select {
+		case <-n.tomb.Dying():
+			return tomb.ErrDying
+		case <-n.replicate:
+			n.mu.Lock()
+			if n.role == leader {
+				n.sendAppendEntries()
+			}
+			n.mu.Unlock()
+		case <-ticker.C:
+			n.mu.Lock()
+			if n.role == leader {
+				n.sendAppendEntries()
+			} else if time.Now().After(n.electionDeadline) {
+				if err := n.startElection(); err != nil {
+					n.mu.Unlock()
+					return errors.Trace(err)
+				}
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+// The methods below must be called with n.mu held.
+
+func (n *Node) lastIndex() uint64 {
+	return n.log[len(n.log)-1].Index
+}
+
+func (n *Node) lastTerm() uint64 {
+	return n.log[len(n.log)-1].Term
+}
+
+// snapshotIndex returns the index of the last entry included in the
+// snapshot.
+func (n *Node) snapshotIndex() uint64 {
+	return n.log[0].Index
+}
+
+// entry returns the log entry with the given index, which must be
+// between the snapshot index and the last index inclusive.
+func (n *Node) entry(index uint64) Entry {
+	return n.log[index-n.snapshotIndex()]
+}
+
+func (n *Node) persist() error {
+	entries := make([]Entry, len(n.log)-1)
+	copy(entries, n.log[1:])
+	return n.config.Store.Save(PersistentState{
+		Term:          n.currentTerm,
+		VotedFor:      n.votedFor,
+		Snapshot:      n.snapshot,
+		SnapshotIndex: n.log[0].Index,
+		SnapshotTerm:  n.log[0].Term,
+		Entries:       entries,
+	})
+}
+
+func (n *Node) resetElectionDeadline() {
+	timeout := n.config.ElectionTimeout
+	timeout += time.Duration(rand.Int63n(int64(timeout)))
+	n.electionDeadline = time.Now().Add(timeout)
+}
+
+func (n *Node) majority() int {
+	return (len(n.config.Peers)+1)/2 + 1
+}
+
+// becomeFollower makes the node a follower in the given term.
+func (n *Node) becomeFollower(term uint64, leaderId string) error {
+	if n.role != follower {
+		logger.Infof("raft node %q becoming follower in term %d", n.config.Id, term)
+	}
+	wasLeader := n.role == leader
+	n.role = follower
+	n.leaderId = leaderId
+	if term != n.currentTerm {
+		n.currentTerm = term
+		n.votedFor = ""
+		if err := n.persist(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if wasLeader {
+		n.failProposalsLocked()
+	}
+	return nil
+}
+
+func (n *Node) startElection() error {
+	n.role = candidate
+	n.leaderId = ""
+	n.currentTerm++
+	n.votedFor = n.config.Id
+	if err := n.persist(); err != nil {
+		return errors.Trace(err)
+	}
+	n.resetElectionDeadline()
+	logger.Debugf("raft node %q standing for election in term %d", n.config.Id, n.currentTerm)
+
+	args := RequestVoteArgs{
+		Term:         n.currentTerm,
+		CandidateId:  n.config.Id,
+		LastLogIndex: n.lastIndex(),
+		LastLogTerm:  n.lastTerm(),
+	}
+	votes := 1
+	if votes >= n.majority() {
+		n.becomeLeader()
+		return nil
+	}
+	for _, peer := range n.config.Peers {
+		go func(peer string) {
+			result, err := n.config.Transport.RequestVote(peer, args)
+			if err != nil {
+				logger.Debugf("cannot request vote from %q: %v", peer, err)
+				return
+			}
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			if result.Term > n.currentTerm {
+				if err := n.becomeFollower(result.Term, ""); err != nil {
+					n.tomb.Kill(err)
+				}
+				return
+			}
+			if n.role != candidate || n.currentTerm != args.Term || !result.Granted {
+				return
+			}
+			votes++
+			if votes >= n.majority() {
+				n.becomeLeader()
+			}
+		}(peer)
+	}
+	return nil
+}
+
+func (n *Node) becomeLeader() {
+	logger.Infof("raft node %q elected leader in term %d", n.config.Id, n.currentTerm)
+	n.role = leader
+	n.leaderId = n.config.Id
+	n.nextIndex = make(map[string]uint64)
+	n.matchIndex = make(map[string]uint64)
+	n.inflight = make(map[string]bool)
+	for _, peer := range n.config.Peers {
+		n.nextIndex[peer] = n.lastIndex() + 1
+	}
+	// A leader cannot know which entries from earlier terms are
+	// committed until it commits one from its own term, so it
+	// starts by appending an empty entry.
+	n.log = append(n.log, Entry{
+		Index: n.lastIndex() + 1,
+		Term:  n.currentTerm,
+	})
+	if err := n.persist(); err != nil {
+		n.tomb.Kill(errors.Trace(err))
+		return
+	}
+	n.advanceCommitIndex()
+	n.sendAppendEntries()
+}
+
+// sendAppendEntries sends any entries they lack, or a heartbeat, to
+// every follower which is not already waiting for a response.
+func (n *Node) sendAppendEntries() {
+	for _, peer := range n.config.Peers {
+		if n.inflight[peer] {
+			continue
+		}
+		n.inflight[peer] = true
+		if n.nextIndex[peer] <= n.snapshotIndex() {
+			go n.sendSnapshot(peer, InstallSnapshotArgs{
+				Term:      n.currentTerm,
+				LeaderId:  n.config.Id,
+				LastIndex: n.log[0].Index,
+				LastTerm:  n.log[0].Term,
+				Data:      n.snapshot,
+			})
+			continue
+		}
+		prevIndex := n.nextIndex[peer] - 1
+		entries := make([]Entry, n.lastIndex()-prevIndex)
+		copy(entries, n.log[prevIndex+1-n.snapshotIndex():])
+		go n.sendEntries(peer, AppendEntriesArgs{
+			Term:         n.currentTerm,
+			LeaderId:     n.config.Id,
+			PrevLogIndex: prevIndex,
+			PrevLogTerm:  n.entry(prevIndex).Term,
+			Entries:      entries,
+			LeaderCommit: n.commitIndex,
+		})
+	}
+}
+
+func (n *Node) sendEntries(peer string, args AppendEntriesArgs) {
+	result, err := n.config.Transport.AppendEntries(peer, args)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.inflight != nil {
+		n.inflight[peer] = false
+	}
+	if err != nil {
+		logger.Debugf("cannot append entries to %q: %v", peer, err)
+		return
+	}
+	if result.Term > n.currentTerm {
+		if err := n.becomeFollower(result.Term, ""); err != nil {
+			n.tomb.Kill(err)
+		}
+		return
+	}
+	if n.role != leader || n.currentTerm != args.Term {
+		return
+	}
+	if !result.Success {
+		next := result.LastIndex + 1
+		if next >= n.nextIndex[peer] {
+			next = n.nextIndex[peer] - 1
+		}
+		if next < 1 {
+			next = 1
+		}
+		n.nextIndex[peer] = next
+		n.triggerReplication()
+		return
+	}
+	match := args.PrevLogIndex + uint64(len(args.Entries))
+	if match > n.matchIndex[peer] {
+		n.matchIndex[peer] = match
+	}
+	n.nextIndex[peer] = n.matchIndex[peer] + 1
+	n.advanceCommitIndex()
+	if n.nextIndex[peer] <= n.lastIndex() {
+		n.triggerReplication()
+	}
+}
+
+func (n *Node) sendSnapshot(peer string, args InstallSnapshotArgs) {
+	result, err := n.config.Transport.InstallSnapshot(peer, args)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.inflight != nil {
+		n.inflight[peer] = false
+	}
+	if err != nil {
+		logger.Debugf("cannot install snapshot on %q: %v", peer, err)
+		return
+	}
+	if result.Term > n.currentTerm {
+		if err := n.becomeFollower(result.Term, ""); err != nil {
+			n.tomb.Kill(err)
+		}
+		return
+	}
+	if n.role != leader || n.currentTerm != args.Term {
+		return
+	}
+	if args.LastIndex > n.matchIndex[peer] {
+		n.matchIndex[peer] = args.LastIndex
+	}
+	n.nextIndex[peer] = n.matchIndex[peer] + 1
+	n.triggerReplication()
+}
+
+// advanceCommitIndex commits the entries which have been replicated
+// to a majority of the cluster.
+func (n *Node) advanceCommitIndex() {
+	for index := n.lastIndex(); index > n.commitIndex; index-- {
+		// Only entries from the current term are committed by
+		// counting replicas; earlier ones are committed with them.
+		if n.entry(index).Term != n.currentTerm {
+			break
+		}
+		count := 1
+		for _, match := range n.matchIndex {
+			if match >= index {
+				count++
+			}
+		}
+		if count >= n.majority() {
+			n.commitIndex = index
+			break
+		}
+	}
+	n.applyCommitted()
+}
+
+// applyCommitted applies the committed entries which have not yet
+// been applied, and compacts the log if it has grown too long.
+func (n *Node) applyCommitted() {
+	for n.lastApplied < n.commitIndex {
+		n.lastApplied++
+		entry := n.entry(n.lastApplied)
+		var result []byte
+		if entry.Command != nil {
+			result = n.config.FSM.Apply(entry.Command)
+		}
+		if p, ok := n.proposals[entry.Index]; ok {
+			delete(n.proposals, entry.Index)
+			if p.term == entry.Term {
+				p.result <- result
+			} else {
+				close(p.result)
+			}
+		}
+	}
+	if n.lastApplied-n.snapshotIndex() >= n.config.SnapshotThreshold {
+		if err := n.compact(); err != nil {
+			logger.Errorf("cannot compact raft log: %v", err)
+		}
+	}
+}
+
+// compact replaces the applied entries in the log with a snapshot.
+func (n *Node) compact() error {
+	snapshot, err := n.config.FSM.Snapshot()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	last := n.entry(n.lastApplied)
+	log := make([]Entry, n.lastIndex()-n.lastApplied+1)
+	copy(log, n.log[n.lastApplied-n.snapshotIndex():])
+	log[0] = Entry{Index: last.Index, Term: last.Term}
+	oldLog, oldSnapshot := n.log, n.snapshot
+	n.log, n.snapshot = log, snapshot
+	if err := n.persist(); err != nil {
+		n.log, n.snapshot = oldLog, oldSnapshot
+		return errors.Trace(err)
+	}
+	logger.Debugf("raft node %q compacted log up to index %d", n.config.Id, last.Index)
+	return nil
+}
+
+// failProposalsLocked abandons all outstanding proposals.
+func (n *Node) failProposalsLocked() {
+	for index, p := range n.proposals {
+		close(p.result)
+		delete(n.proposals, index)
+	}
+}
+
+func (n *Node) failProposals() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failProposalsLocked()
+}
+
+// RequestVote handles a request from a candidate for the node's vote.
+func (n *Node) RequestVote(args RequestVoteArgs) (RequestVoteResult, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if args.Term > n.currentTerm {
+		if err := n.becomeFollower(args.Term, ""); err != nil {
+			return RequestVoteResult{}, errors.Trace(err)
+		}
+	}
+	result := RequestVoteResult{Term: n.currentTerm}
+	if args.Term < n.currentTerm {
+		return result, nil
+	}
+	upToDate := args.LastLogTerm > n.lastTerm() ||
+		(args.LastLogTerm == n.lastTerm() && args.LastLogIndex >= n.lastIndex())
+	if !upToDate || (n.votedFor != "" && n.votedFor != args.CandidateId) {
+		return result, nil
+	}
+	if n.votedFor != args.CandidateId {
+		n.votedFor = args.CandidateId
+		if err := n.persist(); err != nil {
+			return RequestVoteResult{}, errors.Trace(err)
+		}
+	}
+	n.resetElectionDeadline()
+	result.Granted = true
+	return result, nil
+}
+
+// AppendEntries handles a request from the leader to append entries
+// to the node's log.
+func (n *Node) AppendEntries(args AppendEntriesArgs) (AppendEntriesResult, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if args.Term < n.currentTerm {
+		return AppendEntriesResult{Term: n.currentTerm}, nil
+	}
+	if args.Term > n.currentTerm || n.role != follower {
+		if err := n.becomeFollower(args.Term, args.LeaderId); err != nil {
+			return AppendEntriesResult{}, errors.Trace(err)
+		}
+	}
+	n.leaderId = args.LeaderId
+	n.resetElectionDeadline()
+	result := AppendEntriesResult{Term: n.currentTerm}
+
+	// Entries already included in the snapshot are committed, and
+	// so must match.
+	entries := args.Entries
+	prevIndex, prevTerm := args.PrevLogIndex, args.PrevLogTerm
+	if prevIndex < n.snapshotIndex() {
+		skip := n.snapshotIndex() - prevIndex
+		if skip > uint64(len(entries)) {
+			skip = uint64(len(entries))
+		}
+		entries = entries[skip:]
+		prevIndex, prevTerm = n.log[0].Index, n.log[0].Term
+	}
+	if prevIndex > n.lastIndex() {
+		result.LastIndex = n.lastIndex()
+		return result, nil
+	}
+	if n.entry(prevIndex).Term != prevTerm {
+		result.LastIndex = prevIndex - 1
+		if result.LastIndex < n.commitIndex {
+			result.LastIndex = n.commitIndex
+		}
+		return result, nil
+	}
+
+	changed := false
+	for i, e := range entries {
+		if e.Index <= n.lastIndex() {
+			if n.entry(e.Index).Term == e.Term {
+				continue
+			}
+			// Conflicting entries are never committed, so
+			// they and everything after them can be dropped.
+			n.log = n.log[:e.Index-n.snapshotIndex()]
+		}
+		n.log = append(n.log, entries[i:]...)
+		changed = true
+		break
+	}
+	if changed {
+		if err := n.persist(); err != nil {
+			return AppendEntriesResult{}, errors.Trace(err)
+		}
+	}
+	lastNew := prevIndex + uint64(len(entries))
+	if args.LeaderCommit > n.commitIndex {
+		commit := args.LeaderCommit
+		if commit > lastNew {
+			commit = lastNew
+		}
+		if commit > n.commitIndex {
+			n.commitIndex = commit
+			n.applyCommitted()
+		}
+	}
+	result.Success = true
+	result.LastIndex = lastNew
+	return result, nil
+}
+
+// InstallSnapshot handles a request from the leader to replace the
+// node's state with a snapshot.
+func (n *Node) InstallSnapshot(args InstallSnapshotArgs) (InstallSnapshotResult, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if args.Term < n.currentTerm {
+		return InstallSnapshotResult{Term: n.currentTerm}, nil
+	}
+	if args.Term > n.currentTerm || n.role != follower {
+		if err := n.becomeFollower(args.Term, args.LeaderId); err != nil {
+			return InstallSnapshotResult{}, errors.Trace(err)
+		}
+	}
+	n.leaderId = args.LeaderId
+	n.resetElectionDeadline()
+	result := InstallSnapshotResult{Term: n.currentTerm}
+	if args.LastIndex <= n.commitIndex {
+		return result, nil
+	}
+	if err := n.config.FSM.Restore(args.Data); err != nil {
+		return InstallSnapshotResult{}, errors.Annotate(err, "cannot restore snapshot")
+	}
+	n.log = []Entry{{Index: args.LastIndex, Term: args.LastTerm}}
+	n.snapshot = args.Data
+	n.commitIndex = args.LastIndex
+	n.lastApplied = args.LastIndex
+	if err := n.persist(); err != nil {
+		return InstallSnapshotResult{}, errors.Trace(err)
+	}
+	logger.Infof("raft node %q installed snapshot up to index %d", n.config.Id, args.LastIndex)
+	return result, nil
+}
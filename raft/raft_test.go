@@ -0,0 +1,351 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raft_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/raft"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type RaftSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&RaftSuite{})
+
+// listFSM is an FSM which appends each command to a list.
+type listFSM struct {
+	mu    sync.Mutex
+	items []string
+}
+
+func (f *listFSM) Apply(command []byte) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = append(f.items, string(command))
+	return []byte(fmt.Sprint(len(f.items)))
+}
+
+func (f *listFSM) Snapshot() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return []byte(strings.Join(f.items, ",")), nil
+}
+
+func (f *listFSM) Restore(snapshot []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = nil
+	if len(snapshot) > 0 {
+		f.items = strings.Split(string(snapshot), ",")
+	}
+	return nil
+}
+
+func (f *listFSM) Items() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.items...)
+}
+
+// cluster is an in-memory Raft cluster whose nodes can be
+// disconnected from one another.
+type cluster struct {
+	mu           sync.Mutex
+	nodes        map[string]*raft.Node
+	fsms         map[string]*listFSM
+	stores       map[string]raft.Store
+	disconnected map[string]bool
+}
+
+type transport struct {
+	cluster *cluster
+	from    string
+}
+
+func (t transport) node(peer string) (*raft.Node, error) {
+	t.cluster.mu.Lock()
+	defer t.cluster.mu.Unlock()
+	if t.cluster.disconnected[t.from] || t.cluster.disconnected[peer] {
+		return nil, errors.New("disconnected")
+	}
+	node := t.cluster.nodes[peer]
+	if node == nil {
+		return nil, errors.New("not running")
+	}
+	return node, nil
+}
+
+func (t transport) RequestVote(peer string, args raft.RequestVoteArgs) (raft.RequestVoteResult, error) {
+	node, err := t.node(peer)
+	if err != nil {
+		return raft.RequestVoteResult{}, err
+	}
+	return node.RequestVote(args)
+}
+
+func (t transport) AppendEntries(peer string, args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error) {
+	node, err := t.node(peer)
+	if err != nil {
+		return raft.AppendEntriesResult{}, err
+	}
+	return node.AppendEntries(args)
+}
+
+func (t transport) InstallSnapshot(peer string, args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error) {
+	node, err := t.node(peer)
+	if err != nil {
+		return raft.InstallSnapshotResult{}, err
+	}
+	return node.InstallSnapshot(args)
+}
+
+func newCluster(c *gc.C, ids ...string) *cluster {
+	cl := &cluster{
+		nodes:        make(map[string]*raft.Node),
+		fsms:         make(map[string]*listFSM),
+		stores:       make(map[string]raft.Store),
+		disconnected: make(map[string]bool),
+	}
+	for _, id := range ids {
+		cl.stores[id] = raft.NewMemoryStore()
+	}
+	for _, id := range ids {
+		cl.start(c, id)
+	}
+	return cl
+}
+
+func (cl *cluster) start(c *gc.C, id string) {
+	var peers []string
+	for peer := range cl.stores {
+		if peer != id {
+			peers = append(peers, peer)
+		}
+	}
+	fsm := &listFSM{}
+	node, err := raft.NewNode(raft.Config{
+		Id:                id,
+		Peers:             peers,
+		FSM:               fsm,
+		Transport:         transport{cl, id},
+		Store:             cl.stores[id],
+		HeartbeatInterval: 5 * time.Millisecond,
+		ElectionTimeout:   25 * time.Millisecond,
+		SnapshotThreshold: 10,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.nodes[id] = node
+	cl.fsms[id] = fsm
+}
+
+func (cl *cluster) stop(c *gc.C, id string) {
+	cl.mu.Lock()
+	node := cl.nodes[id]
+	delete(cl.nodes, id)
+	cl.mu.Unlock()
+	node.Kill()
+	c.Assert(node.Wait(), jc.ErrorIsNil)
+}
+
+func (cl *cluster) stopAll(c *gc.C) {
+	for id := range cl.stores {
+		cl.mu.Lock()
+		_, running := cl.nodes[id]
+		cl.mu.Unlock()
+		if running {
+			cl.stop(c, id)
+		}
+	}
+}
+
+func (cl *cluster) setConnected(id string, connected bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.disconnected[id] = !connected
+}
+
+// waitLeader waits until all the connected nodes agree on a leader
+// other than the one given, and returns it.
+func (cl *cluster) waitLeader(c *gc.C, not string) *raft.Node {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		cl.mu.Lock()
+		leaders := make(map[string]bool)
+		for id, node := range cl.nodes {
+			if !cl.disconnected[id] {
+				leaders[node.Leader()] = true
+			}
+		}
+		var leader *raft.Node
+		if len(leaders) == 1 {
+			for id := range leaders {
+				leader = cl.nodes[id]
+			}
+		}
+		cl.mu.Unlock()
+		if leader != nil && leader.Id() != not {
+			return leader
+		}
+	}
+	c.Fatalf("cluster did not elect a leader")
+	panic("unreachable")
+}
+
+func (cl *cluster) waitItems(c *gc.C, id string, expect []string) {
+	cl.mu.Lock()
+	fsm := cl.fsms[id]
+	cl.mu.Unlock()
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		items := fsm.Items()
+		if len(items) >= len(expect) || !a.HasNext() {
+			c.Assert(items, jc.DeepEquals, expect)
+			return
+		}
+	}
+}
+
+func (s *RaftSuite) TestConfigValidate(c *gc.C) {
+	_, err := raft.NewNode(raft.Config{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "empty Id not valid")
+}
+
+func (s *RaftSuite) TestSingleNode(c *gc.C) {
+	cl := newCluster(c, "0")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+	c.Assert(leader.Id(), gc.Equals, "0")
+
+	result, err := leader.Propose([]byte("a"), coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(result), gc.Equals, "1")
+	c.Assert(cl.fsms["0"].Items(), jc.DeepEquals, []string{"a"})
+}
+
+func (s *RaftSuite) TestReplication(c *gc.C) {
+	cl := newCluster(c, "0", "1", "2")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+
+	for i, item := range []string{"a", "b", "c"} {
+		result, err := leader.Propose([]byte(item), coretesting.LongWait)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(result), gc.Equals, fmt.Sprint(i+1))
+	}
+	for id := range cl.stores {
+		cl.waitItems(c, id, []string{"a", "b", "c"})
+	}
+}
+
+func (s *RaftSuite) TestProposeToFollower(c *gc.C) {
+	cl := newCluster(c, "0", "1", "2")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+
+	for id, node := range cl.nodes {
+		if id == leader.Id() {
+			continue
+		}
+		_, err := node.Propose([]byte("a"), coretesting.LongWait)
+		c.Assert(err, jc.Satisfies, raft.IsNotLeader)
+		c.Assert(err.(*raft.NotLeaderError).Leader, gc.Equals, leader.Id())
+	}
+}
+
+func (s *RaftSuite) TestLeaderFailover(c *gc.C) {
+	cl := newCluster(c, "0", "1", "2")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+	_, err := leader.Propose([]byte("a"), coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cl.setConnected(leader.Id(), false)
+	newLeader := cl.waitLeader(c, leader.Id())
+	_, err = newLeader.Propose([]byte("b"), coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The old leader cannot commit anything without a majority.
+	_, err = leader.Propose([]byte("lost"), 50*time.Millisecond)
+	c.Assert(err, gc.NotNil)
+
+	// Once reconnected it steps down and catches up, discarding
+	// the uncommitted entry.
+	cl.setConnected(leader.Id(), true)
+	cl.waitLeader(c, "")
+	for id := range cl.stores {
+		cl.waitItems(c, id, []string{"a", "b"})
+	}
+}
+
+func (s *RaftSuite) TestNoQuorum(c *gc.C) {
+	cl := newCluster(c, "0", "1", "2")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+	for id := range cl.stores {
+		if id != leader.Id() {
+			cl.setConnected(id, false)
+		}
+	}
+	_, err := leader.Propose([]byte("a"), 50*time.Millisecond)
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *RaftSuite) TestSnapshotCatchUp(c *gc.C) {
+	cl := newCluster(c, "0", "1", "2")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+
+	var lagging string
+	for id := range cl.stores {
+		if id != leader.Id() {
+			lagging = id
+			break
+		}
+	}
+	cl.stop(c, lagging)
+
+	// Propose enough commands for the log to be compacted past
+	// the point the stopped node has reached.
+	var expect []string
+	for i := 0; i < 25; i++ {
+		item := fmt.Sprint(i)
+		_, err := leader.Propose([]byte(item), coretesting.LongWait)
+		c.Assert(err, jc.ErrorIsNil)
+		expect = append(expect, item)
+	}
+
+	cl.start(c, lagging)
+	cl.waitItems(c, lagging, expect)
+}
+
+func (s *RaftSuite) TestRestartRestoresState(c *gc.C) {
+	cl := newCluster(c, "0")
+	defer cl.stopAll(c)
+	leader := cl.waitLeader(c, "")
+	var expect []string
+	for i := 0; i < 15; i++ {
+		item := fmt.Sprint(i)
+		_, err := leader.Propose([]byte(item), coretesting.LongWait)
+		c.Assert(err, jc.ErrorIsNil)
+		expect = append(expect, item)
+	}
+	cl.stop(c, "0")
+
+	cl.start(c, "0")
+	leader = cl.waitLeader(c, "")
+	_, err := leader.Propose([]byte("last"), coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+	cl.waitItems(c, "0", append(expect, "last"))
+}
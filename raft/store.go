@@ -0,0 +1,101 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raft
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+// PersistentState holds the state of a node which must survive
+// restarts for the cluster to remain consistent.
+type PersistentState struct {
+	Term     uint64 `json:"term"`
+	VotedFor string `json:"voted-for,omitempty"`
+
+	// Snapshot holds the state of the FSM after applying every
+	// entry up to and including SnapshotIndex, which was added in
+	// SnapshotTerm.
+	Snapshot      []byte `json:"snapshot,omitempty"`
+	SnapshotIndex uint64 `json:"snapshot-index"`
+	SnapshotTerm  uint64 `json:"snapshot-term"`
+
+	// Entries holds the log entries following the snapshot.
+	Entries []Entry `json:"entries,omitempty"`
+}
+
+// Store persists the state of a node.
+type Store interface {
+	// Load returns the saved state, or the zero state if nothing has
+	// been saved.
+	Load() (PersistentState, error)
+
+	// Save durably records the given state.
+	Save(state PersistentState) error
+}
+
+// NewFileStore returns a Store which keeps the state of a node in the
+// file at the given path.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+type fileStore struct {
+	path string
+}
+
+// Load is part of the Store interface.
+func (s *fileStore) Load() (PersistentState, error) {
+	var state PersistentState
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return state, errors.Trace(err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, errors.Annotatef(err, "cannot parse raft state %q", s.path)
+	}
+	return state, nil
+}
+
+// Save is part of the Store interface.
+func (s *fileStore) Save(state PersistentState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return utils.AtomicWriteFile(s.path, data, 0600)
+}
+
+// NewMemoryStore returns a Store which keeps the state of a node in
+// memory, for use by tests.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+type memoryStore struct {
+	mu    sync.Mutex
+	state PersistentState
+}
+
+// Load is part of the Store interface.
+func (s *memoryStore) Load() (PersistentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+// Save is part of the Store interface.
+func (s *memoryStore) Save(state PersistentState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raft
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+)
+
+// ErrStopped is returned by a node which has been stopped.
+var ErrStopped = errors.New("raft node stopped")
+
+// NotLeaderError is returned when a command is proposed to a node
+// which is not the leader of the cluster.
+type NotLeaderError struct {
+	// Leader holds the id of the node believed to be the leader,
+	// or is empty if the leader is not known.
+	Leader string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.Leader == "" {
+		return "not the raft leader, leader unknown"
+	}
+	return fmt.Sprintf("not the raft leader, leader is %q", e.Leader)
+}
+
+// IsNotLeader reports whether the cause of err is a *NotLeaderError.
+func IsNotLeader(err error) bool {
+	_, ok := errors.Cause(err).(*NotLeaderError)
+	return ok
+}
+
+// FSM is the replicated state machine to which committed commands are
+// applied. Every node applies the same commands in the same order, so
+// Apply must be deterministic: it must not consult the local clock or
+// any other state that differs between nodes.
+type FSM interface {
+	// Apply applies a committed command, returning the result
+	// passed back to the proposer.
+	Apply(command []byte) []byte
+
+	// Snapshot returns the complete state of the FSM.
+	Snapshot() ([]byte, error)
+
+	// Restore replaces the state of the FSM with one returned by
+	// Snapshot.
+	Restore(snapshot []byte) error
+}
+
+// Transport sends requests to the other nodes in the cluster.
+type Transport interface {
+	RequestVote(peer string, args RequestVoteArgs) (RequestVoteResult, error)
+	AppendEntries(peer string, args AppendEntriesArgs) (AppendEntriesResult, error)
+	InstallSnapshot(peer string, args InstallSnapshotArgs) (InstallSnapshotResult, error)
+}
+
+// Entry is a single entry in the replicated log.
+type Entry struct {
+	Index   uint64 `json:"index"`
+	Term    uint64 `json:"term"`
+	Command []byte `json:"command,omitempty"`
+}
+
+// RequestVoteArgs holds the arguments of a request made by a
+// candidate for a node's vote.
+type RequestVoteArgs struct {
+	Term         uint64 `json:"term"`
+	CandidateId  string `json:"candidate-id"`
+	LastLogIndex uint64 `json:"last-log-index"`
+	LastLogTerm  uint64 `json:"last-log-term"`
+}
+
+// RequestVoteResult holds the response to a RequestVoteArgs.
+type RequestVoteResult struct {
+	Term    uint64 `json:"term"`
+	Granted bool   `json:"granted"`
+}
+
+// AppendEntriesArgs holds the arguments of a request made by the
+// leader to replicate log entries to a follower, which also serves as
+// the leader's heartbeat.
+type AppendEntriesArgs struct {
+	Term         uint64  `json:"term"`
+	LeaderId     string  `json:"leader-id"`
+	PrevLogIndex uint64  `json:"prev-log-index"`
+	PrevLogTerm  uint64  `json:"prev-log-term"`
+	Entries      []Entry `json:"entries"`
+	LeaderCommit uint64  `json:"leader-commit"`
+}
+
+// AppendEntriesResult holds the response to an AppendEntriesArgs.
+type AppendEntriesResult struct {
+	Term    uint64 `json:"term"`
+	Success bool   `json:"success"`
+
+	// LastIndex holds the index of the follower's last log entry
+	// which is known to match the leader's, so that the leader can
+	// skip directly to it after a mismatch.
+	LastIndex uint64 `json:"last-index"`
+}
+
+// InstallSnapshotArgs holds the arguments of a request made by the
+// leader to replace the state of a follower which has fallen too far
+// behind to be sent the log entries it is missing.
+type InstallSnapshotArgs struct {
+	Term      uint64 `json:"term"`
+	LeaderId  string `json:"leader-id"`
+	LastIndex uint64 `json:"last-index"`
+	LastTerm  uint64 `json:"last-term"`
+	Data      []byte `json:"data"`
+}
+
+// InstallSnapshotResult holds the response to an InstallSnapshotArgs.
+type InstallSnapshotResult struct {
+	Term uint64 `json:"term"`
+}
@@ -334,6 +334,19 @@ func MasterHostPort(session *mgo.Session) (string, error) {
 	return results.PrimaryAddress, nil
 }
 
+// StepDownPrimary asks the primary of the replica set to step down,
+// so that another member is elected in its place, and not to seek
+// re-election for the given number of seconds. The primary closes
+// all its connections when it steps down, so a dropped connection is
+// not reported as an error.
+func StepDownPrimary(session *mgo.Session, seconds int) error {
+	err := session.Run(bson.D{{"replSetStepDown", seconds}}, nil)
+	if err == nil || isConnectionNotAvailable(err) {
+		return nil
+	}
+	return errors.Annotate(err, "cannot step down primary")
+}
+
 // CurrentMembers returns the current members of the replica set.
 func CurrentMembers(session *mgo.Session) ([]Member, error) {
 	cfg, err := CurrentConfig(session)
@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/juju/rpc/rpcreflect"
+)
+
+// callContext implements rpcreflect.Context for a single server
+// request.
+type callContext struct {
+	deadline time.Time
+	done     chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// newCallContext returns a context that is abandoned when abandoned is
+// closed or, if timeout is non-zero, once timeout has elapsed. The
+// returned function must be called when the request completes, to
+// release the goroutine watching for either.
+func newCallContext(abandoned <-chan struct{}, timeout time.Duration) (*callContext, func()) {
+	ctx := &callContext{
+		done: make(chan struct{}),
+	}
+	if timeout > 0 {
+		ctx.deadline = time.Now().Add(timeout)
+	}
+	finished := make(chan struct{})
+	go func() {
+		var expired <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+		select {
+		case <-abandoned:
+			ctx.abandon(rpcreflect.ErrCallAbandoned)
+		case <-expired:
+			ctx.abandon(rpcreflect.ErrDeadlineExceeded)
+		case <-finished:
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() { close(finished) })
+	}
+}
+
+func (ctx *callContext) abandon(err error) {
+	ctx.mu.Lock()
+	ctx.err = err
+	ctx.mu.Unlock()
+	close(ctx.done)
+}
+
+// Deadline implements rpcreflect.Context.
+func (ctx *callContext) Deadline() (time.Time, bool) {
+	return ctx.deadline, !ctx.deadline.IsZero()
+}
+
+// Done implements rpcreflect.Context.
+func (ctx *callContext) Done() <-chan struct{} {
+	return ctx.done
+}
+
+// Err implements rpcreflect.Context.
+func (ctx *callContext) Err() error {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	return ctx.err
+}
@@ -94,6 +94,27 @@ func (*reflectSuite) TestObjTypeOf(c *gc.C) {
 	c.Check(m, gc.DeepEquals, rpcreflect.ObjMethod{})
 }
 
+func (*reflectSuite) TestObjTypeOfContextMethods(c *gc.C) {
+	objType := rpcreflect.ObjTypeOf(reflect.TypeOf(&ContextMethods{}))
+	c.Check(objType.DiscardedMethods(), gc.HasLen, 0)
+	c.Assert(objType.MethodNames(), jc.DeepEquals, []string{"Echo", "Wait"})
+
+	m, err := objType.Method("Wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(m.Params, gc.IsNil)
+	c.Check(m.Result, gc.IsNil)
+
+	m, err = objType.Method("Echo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(m.Params, gc.Equals, reflect.TypeOf(stringVal{}))
+	c.Check(m.Result, gc.Equals, reflect.TypeOf(stringVal{}))
+
+	// A nil context is replaced with one that is never abandoned.
+	ret, err := m.Call(nil, reflect.ValueOf(&ContextMethods{}), reflect.ValueOf(stringVal{"foo"}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ret.Interface(), gc.Equals, stringVal{"foo"})
+}
+
 func (*reflectSuite) TestValueOf(c *gc.C) {
 	v := rpcreflect.ValueOf(reflect.ValueOf(nil))
 	c.Check(v.IsValid(), jc.IsFalse)
@@ -130,7 +151,7 @@ func (*reflectSuite) TestFindMethod(c *gc.C) {
 	c.Assert(m.ParamsType(), gc.Equals, reflect.TypeOf(stringVal{}))
 	c.Assert(m.ResultType(), gc.Equals, reflect.TypeOf(stringVal{}))
 
-	ret, err := m.Call("a99", reflect.ValueOf(stringVal{"foo"}))
+	ret, err := m.Call(rpcreflect.Background(), "a99", reflect.ValueOf(stringVal{"foo"}))
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(ret.Interface(), gc.Equals, stringVal{"Call1r1e ret"})
 }
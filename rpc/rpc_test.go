@@ -211,6 +211,36 @@ func (a *DelayedMethods) Delay() (stringVal, error) {
 	}
 }
 
+// ContextRoot serves ContextMethods, whose calls are abandoned
+// after timeout if it is non-zero.
+type ContextRoot struct {
+	timeout   time.Duration
+	started   chan struct{}
+	abandoned chan error
+}
+
+func (r *ContextRoot) ContextMethods(string) (*ContextMethods, error) {
+	return &ContextMethods{r}, nil
+}
+
+type ContextMethods struct {
+	root *ContextRoot
+}
+
+// Wait blocks until its context is abandoned, and returns the
+// context's error.
+func (m *ContextMethods) Wait(ctx rpcreflect.Context) error {
+	m.root.started <- struct{}{}
+	<-ctx.Done()
+	err := ctx.Err()
+	m.root.abandoned <- err
+	return err
+}
+
+func (m *ContextMethods) Echo(ctx rpcreflect.Context, s stringVal) (stringVal, error) {
+	return s, ctx.Err()
+}
+
 type ErrorMethods struct {
 	err error
 }
@@ -300,7 +330,7 @@ func (c customMethodCaller) ResultType() reflect.Type {
 	return c.objMethod.Result
 }
 
-func (c customMethodCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+func (c customMethodCaller) Call(ctx rpcreflect.Context, objId string, arg reflect.Value) (reflect.Value, error) {
 	sm, err := c.root.SimpleMethods(objId)
 	if err != nil {
 		return reflect.Value{}, err
@@ -310,7 +340,7 @@ func (c customMethodCaller) Call(objId string, arg reflect.Value) (reflect.Value
 		logger.Errorf("got the wrong type back, expected %s got %T", c.expectedType, obj)
 	}
 	logger.Debugf("calling: %T %v %#v", obj, obj, c.objMethod)
-	return c.objMethod.Call(obj, arg)
+	return c.objMethod.Call(ctx, obj, arg)
 }
 
 func (cc *CustomMethodFinder) FindMethod(
@@ -853,6 +883,49 @@ func (*rpcSuite) TestServerWaitsForOutstandingCalls(c *gc.C) {
 	start <- "xxx"
 }
 
+func newContextRoot(timeout time.Duration) *ContextRoot {
+	return &ContextRoot{
+		timeout:   timeout,
+		started:   make(chan struct{}, 1),
+		abandoned: make(chan error, 1),
+	}
+}
+
+func (*rpcSuite) TestContextAbandonedWhenClientCloses(c *gc.C) {
+	root := newContextRoot(0)
+	client, srvDone, _, _ := newRPCClientServer(c, root, nil, false)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Call(rpc.Request{"ContextMethods", 0, "", "Wait"}, nil, nil)
+	}()
+	chanRead(c, root.started, "ContextMethods.Wait started")
+	closeClient(c, client, srvDone)
+	err := chanReadError(c, root.abandoned, "context abandoned")
+	c.Assert(err, gc.Equals, rpcreflect.ErrCallAbandoned)
+	err = chanReadError(c, done, "call done")
+	c.Assert(err, gc.Equals, rpc.ErrShutdown)
+}
+
+func (*rpcSuite) TestContextAbandonedAfterTimeout(c *gc.C) {
+	root := newContextRoot(10 * time.Millisecond)
+	client, srvDone, _, _ := newRPCClientServer(c, root, nil, false)
+	defer closeClient(c, client, srvDone)
+	err := client.Call(rpc.Request{"ContextMethods", 0, "", "Wait"}, nil, nil)
+	c.Assert(err, gc.ErrorMatches, "call abandoned: deadline exceeded")
+	err = chanReadError(c, root.abandoned, "context abandoned")
+	c.Assert(err, gc.Equals, rpcreflect.ErrDeadlineExceeded)
+}
+
+func (*rpcSuite) TestContextWithParams(c *gc.C) {
+	root := newContextRoot(time.Minute)
+	client, srvDone, _, _ := newRPCClientServer(c, root, nil, false)
+	defer closeClient(c, client, srvDone)
+	var r stringVal
+	err := client.Call(rpc.Request{"ContextMethods", 0, "", "Echo"}, stringVal{"hello"}, &r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(r, gc.Equals, stringVal{"hello"})
+}
+
 func chanRead(c *gc.C, ch <-chan struct{}, what string) {
 	select {
 	case <-ch:
@@ -1209,6 +1282,9 @@ func newRPCClientServer(c *gc.C, root interface{}, tfErr func(error) error, bidi
 		if root, ok := root.(*Root); ok {
 			root.conn = rpcConn
 		}
+		if root, ok := root.(*ContextRoot); ok {
+			rpcConn.SetRequestTimeout(root.timeout)
+		}
 		rpcConn.Start()
 		<-rpcConn.Dead()
 		srvDone <- rpcConn.Close()
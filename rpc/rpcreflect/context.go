@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package rpcreflect
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*Context)(nil)).Elem()
+
+var (
+	// ErrCallAbandoned is returned by Context.Err when the
+	// connection a call arrived on has closed.
+	ErrCallAbandoned = errors.New("call abandoned: connection closed")
+
+	// ErrDeadlineExceeded is returned by Context.Err when a call
+	// has run past its deadline.
+	ErrDeadlineExceeded = errors.New("call abandoned: deadline exceeded")
+)
+
+// Context carries the cancellation signal for the RPC call that a
+// method is serving. RPC object methods may take a Context as their
+// first argument, and should stop work and return Err once Done is
+// closed. Its methods follow those of golang.org/x/net/context.Context.
+type Context interface {
+	// Deadline returns the time after which the call will be
+	// abandoned, and whether there is such a deadline.
+	Deadline() (deadline time.Time, ok bool)
+
+	// Done returns a channel that is closed when the call is
+	// abandoned. It may return nil if the call can never be
+	// abandoned.
+	Done() <-chan struct{}
+
+	// Err returns nil while Done is open, and ErrCallAbandoned
+	// or ErrDeadlineExceeded once it has been closed.
+	Err() error
+}
+
+// Background returns a Context that is never abandoned, for use when
+// calling RPC methods directly rather than through a connection.
+func Background() Context {
+	return background{}
+}
+
+type background struct{}
+
+func (background) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (background) Done() <-chan struct{} {
+	return nil
+}
+
+func (background) Err() error {
+	return nil
+}
@@ -213,9 +213,11 @@ type ObjMethod struct {
 	Result reflect.Type
 
 	// Call calls the method with the given argument
-	// on the given receiver value. If the method does
-	// not return a value, the returned value will not be valid.
-	Call func(rcvr, arg reflect.Value) (reflect.Value, error)
+	// on the given receiver value. If the method takes
+	// a Context, it is passed ctx, or Background if ctx
+	// is nil. If the method does not return a value,
+	// the returned value will not be valid.
+	Call func(ctx Context, rcvr, arg reflect.Value) (reflect.Value, error)
 }
 
 // ObjTypeOf returns information on all RPC methods
@@ -269,41 +271,52 @@ func newMethod(m reflect.Method, receiverKind reflect.Kind) *ObjMethod {
 		return nil
 	}
 	var p ObjMethod
-	var assemble func(arg reflect.Value) []reflect.Value
 	// N.B. The method type has the receiver as its first argument
 	// unless the receiver is an interface.
-	receiverArgCount := 1
+	firstArg := 1
 	if receiverKind == reflect.Interface {
-		receiverArgCount = 0
+		firstArg = 0
 	}
 	t := m.Type
+	// Method(Context, ...) ...
+	withContext := t.NumIn() > firstArg && t.In(firstArg) == contextType
+	if withContext {
+		firstArg++
+	}
 	switch {
-	case t.NumIn() == 0+receiverArgCount:
+	case t.NumIn() == 0+firstArg:
 		// Method() ...
-		assemble = func(arg reflect.Value) []reflect.Value {
-			return nil
-		}
-	case t.NumIn() == 1+receiverArgCount:
+	case t.NumIn() == 1+firstArg:
 		// Method(T) ...
-		p.Params = t.In(receiverArgCount)
-		assemble = func(arg reflect.Value) []reflect.Value {
-			return []reflect.Value{arg}
-		}
+		p.Params = t.In(firstArg)
 	default:
 		return nil
 	}
+	assemble := func(ctx Context, arg reflect.Value) []reflect.Value {
+		var in []reflect.Value
+		if withContext {
+			if ctx == nil {
+				ctx = Background()
+			}
+			in = append(in, reflect.ValueOf(&ctx).Elem())
+		}
+		if p.Params != nil {
+			in = append(in, arg)
+		}
+		return in
+	}
 
 	switch {
 	case t.NumOut() == 0:
 		// Method(...)
-		p.Call = func(rcvr, arg reflect.Value) (r reflect.Value, err error) {
-			rcvr.Method(m.Index).Call(assemble(arg))
+		p.Call = func(ctx Context, rcvr, arg reflect.Value) (r reflect.Value, err error) {
+			rcvr.Method(m.Index).Call(assemble(ctx, arg))
 			return
 		}
 	case t.NumOut() == 1 && t.Out(0) == errorType:
 		// Method(...) error
-		p.Call = func(rcvr, arg reflect.Value) (r reflect.Value, err error) {
-			out := rcvr.Method(m.Index).Call(assemble(arg))
+		p.Call = func(ctx Context, rcvr, arg reflect.Value) (r reflect.Value, err error) {
+			out := rcvr.Method(m.Index).Call(assemble(ctx, arg))
 			if !out[0].IsNil() {
 				err = out[0].Interface().(error)
 			}
@@ -312,15 +325,15 @@ func newMethod(m reflect.Method, receiverKind reflect.Kind) *ObjMethod {
 	case t.NumOut() == 1:
 		// Method(...) R
 		p.Result = t.Out(0)
-		p.Call = func(rcvr, arg reflect.Value) (reflect.Value, error) {
-			out := rcvr.Method(m.Index).Call(assemble(arg))
+		p.Call = func(ctx Context, rcvr, arg reflect.Value) (reflect.Value, error) {
+			out := rcvr.Method(m.Index).Call(assemble(ctx, arg))
 			return out[0], nil
 		}
 	case t.NumOut() == 2 && t.Out(1) == errorType:
 		// Method(...) (R, error)
 		p.Result = t.Out(0)
-		p.Call = func(rcvr, arg reflect.Value) (r reflect.Value, err error) {
-			out := rcvr.Method(m.Index).Call(assemble(arg))
+		p.Call = func(ctx Context, rcvr, arg reflect.Value) (r reflect.Value, err error) {
+			out := rcvr.Method(m.Index).Call(assemble(ctx, arg))
 			r = out[0]
 			if !out[1].IsNil() {
 				err = out[1].Interface().(error)
@@ -107,12 +107,12 @@ func (v Value) FindMethod(rootMethodName string, version int, objMethodName stri
 	return caller, nil
 }
 
-func (caller methodCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+func (caller methodCaller) Call(ctx Context, objId string, arg reflect.Value) (reflect.Value, error) {
 	obj, err := caller.rootMethod.Call(caller.rootValue, objId)
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	return caller.objMethod.Call(obj, arg)
+	return caller.objMethod.Call(ctx, obj, arg)
 }
 
 func (caller methodCaller) ParamsType() reflect.Type {
@@ -131,6 +131,7 @@ type MethodCaller interface {
 	ResultType() reflect.Type
 
 	// Call is actually placing a call to instantiate an given instance and
-	// call the method on that instance.
-	Call(objId string, arg reflect.Value) (reflect.Value, error)
+	// call the method on that instance. The method is passed ctx if it
+	// takes a Context.
+	Call(ctx Context, objId string, arg reflect.Value) (reflect.Value, error)
 }
@@ -145,6 +145,16 @@ type Conn struct {
 	// inputLoopError holds the error that caused the input loop to
 	// terminate prematurely.  It is set before dead is closed.
 	inputLoopError error
+
+	// abandoned is closed when the connection starts to close or
+	// the input loop terminates, abandoning the contexts of any
+	// outstanding server requests.
+	abandoned   chan struct{}
+	abandonOnce sync.Once
+
+	// requestTimeout, if non-zero, is the time after which the
+	// context of a server request is abandoned.
+	requestTimeout time.Duration
 }
 
 // RequestNotifier can be implemented to find out about requests
@@ -195,9 +205,20 @@ func NewConn(codec Codec, notifier RequestNotifier) *Conn {
 		codec:         codec,
 		clientPending: make(map[uint64]*Call),
 		notifier:      notifier,
+		abandoned:     make(chan struct{}),
 	}
 }
 
+// SetRequestTimeout sets the time after which the context passed to a
+// server method is abandoned. It affects only requests received after
+// it is called. A zero timeout, the default, means requests are only
+// abandoned when the connection closes.
+func (conn *Conn) SetRequestTimeout(timeout time.Duration) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.requestTimeout = timeout
+}
+
 // Start starts the RPC connection running.  It must be called at least
 // once for any RPC connection (client or server side) It has no effect
 // if it has already been called.  By default, a connection serves no
@@ -240,6 +261,15 @@ func (conn *Conn) Start() {
 //	Method(T) (R, error)
 //	Method(T) error
 //
+// Any of these forms may also take an rpcreflect.Context as an initial
+// argument, for example:
+//
+//	Method(ctx rpcreflect.Context, T) (R, error)
+//
+// The context is abandoned when the connection closes, or when the
+// timeout set with SetRequestTimeout has elapsed, so that long-running
+// methods can give up on calls whose caller has gone away.
+//
 // If transformErrors is non-nil, it will be called on all returned
 // non-nil errors, for example to transform the errors into ServerErrors
 // with specified codes.  There will be a panic if transformErrors
@@ -316,6 +346,7 @@ func (conn *Conn) Close() error {
 		return nil
 	}
 	conn.closing = true
+	conn.abandonRequests()
 	conn.killRequests()
 	conn.mutex.Unlock()
 
@@ -336,6 +367,14 @@ func (conn *Conn) Close() error {
 	return conn.inputLoopError
 }
 
+// abandonRequests abandons the contexts of all outstanding server
+// requests. It may be called more than once.
+func (conn *Conn) abandonRequests() {
+	conn.abandonOnce.Do(func() {
+		close(conn.abandoned)
+	})
+}
+
 // Kill server requests if appropriate. Client requests will be
 // terminated when the input loop finishes.
 func (conn *Conn) killRequests() {
@@ -397,6 +436,8 @@ func (conn *Conn) input() {
 	}
 	conn.clientPending = nil
 	conn.shutdown = true
+	// Nobody is left to read the replies to server requests.
+	conn.abandonRequests()
 	close(conn.dead)
 }
 
@@ -478,7 +519,7 @@ func (conn *Conn) handleRequest(hdr *Header) error {
 	closing := conn.closing
 	if !closing {
 		conn.srvPending.Add(1)
-		go conn.runRequest(req, arg, startTime)
+		go conn.runRequest(req, arg, startTime, conn.requestTimeout)
 	}
 	conn.mutex.Unlock()
 	if closing {
@@ -547,9 +588,11 @@ func (conn *Conn) bindRequest(hdr *Header) (boundRequest, error) {
 }
 
 // runRequest runs the given request and sends the reply.
-func (conn *Conn) runRequest(req boundRequest, arg reflect.Value, startTime time.Time) {
+func (conn *Conn) runRequest(req boundRequest, arg reflect.Value, startTime time.Time, timeout time.Duration) {
 	defer conn.srvPending.Done()
-	rv, err := req.Call(req.hdr.Request.Id, arg)
+	ctx, done := newCallContext(conn.abandoned, timeout)
+	rv, err := req.Call(ctx, req.hdr.Request.Id, arg)
+	done()
 	if err != nil {
 		err = conn.writeErrorResponse(&req.hdr, req.transformErrors(err), startTime)
 	} else {
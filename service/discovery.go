@@ -166,6 +166,15 @@ func identifyExecutable(executable string) (string, bool) {
 	}
 }
 
+// DiscoverInitSystemScript returns a shell script which prints the name
+// of the init system running on the host where it is run, and fails
+// if the init system is not recognised.
+func DiscoverInitSystemScript() string {
+	return newShellSelectCommand(func(initSystem string) (string, bool) {
+		return "echo " + initSystem, true
+	})
+}
+
 // TODO(ericsnow) Synchronize newShellSelectCommand with discoverLocalInitSystem.
 
 type initSystem struct {
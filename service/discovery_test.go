@@ -4,6 +4,7 @@
 package service_test
 
 import (
+	"fmt"
 	"os"
 	"runtime"
 
@@ -307,3 +308,17 @@ func (s *discoverySuite) TestVersionInitSystemNoLegacyUpstart(c *gc.C) {
 
 	test.checkInitSystem(c, initSystem, ok)
 }
+
+func (s *discoverySuite) TestDiscoverInitSystemScript(c *gc.C) {
+	script := service.DiscoverInitSystemScript()
+
+	line := `if [[ "$(cat /proc/1/cmdline | awk '{print $1}')" == "%s" ]]; then echo %s`
+	lines := []string{
+		fmt.Sprintf(line, "/sbin/init", "upstart"),
+		fmt.Sprintf(line, "/sbin/upstart", "upstart"),
+		fmt.Sprintf(line, "/sbin/systemd", "systemd"),
+		fmt.Sprintf(line, "/bin/systemd", "systemd"),
+		fmt.Sprintf(line, "/lib/systemd/systemd", "systemd"),
+	}
+	checkShellSwitch(c, script, lines)
+}
@@ -99,6 +99,10 @@ type actionDoc struct {
 
 	// Results are the structured results from the action.
 	Results map[string]interface{} `bson:"results"`
+
+	// OutputChunks is the number of chunks of output recorded for
+	// the action while it was running.
+	OutputChunks int `bson:"output-chunks"`
 }
 
 // Action represents an instruction to do some "action" and is expected
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -845,6 +846,9 @@ var _ state.ActionReceiver = (*mockAR)(nil)
 func (r mockAR) AddAction(name string, payload map[string]interface{}) (*state.Action, error) {
 	return nil, nil
 }
+func (r mockAR) ScheduleAction(string, map[string]interface{}, time.Time, string) (*state.ActionSchedule, error) {
+	return nil, nil
+}
 func (r mockAR) CancelAction(*state.Action) (*state.Action, error) { return nil, nil }
 func (r mockAR) WatchActionNotifications() state.StringsWatcher    { return nil }
 func (r mockAR) Actions() ([]*state.Action, error)                 { return nil, nil }
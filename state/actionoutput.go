@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+const (
+	// ActionStdout identifies output an action wrote to stdout.
+	ActionStdout = "stdout"
+
+	// ActionStderr identifies output an action wrote to stderr.
+	ActionStderr = "stderr"
+)
+
+// maxActionOutputChunk is the largest chunk of output which may be
+// recorded at once.
+const maxActionOutputChunk = 64 * 1024
+
+type actionOutputDoc struct {
+	// DocId is the key for this document; it is the id of the
+	// action followed by the sequence number of the chunk.
+	DocId string `bson:"_id"`
+
+	// EnvUUID is the environment identifier.
+	EnvUUID string `bson:"env-uuid"`
+
+	// ActionId is the id of the action which wrote the output.
+	ActionId string `bson:"action-id"`
+
+	// Seq orders the chunks of output written by an action,
+	// starting at 0.
+	Seq int `bson:"seq"`
+
+	// Stream is the stream the output was written to: ActionStdout
+	// or ActionStderr.
+	Stream string `bson:"stream"`
+
+	// Data holds the output.
+	Data string `bson:"data"`
+
+	// Recorded is the time the chunk was recorded.
+	Recorded time.Time `bson:"recorded"`
+}
+
+// ActionOutputChunk holds a piece of output written by a running
+// action.
+type ActionOutputChunk struct {
+	Seq      int
+	Stream   string
+	Data     string
+	Recorded time.Time
+}
+
+// AppendOutput records a chunk of output written to the given stream
+// by the action while it runs. Output may only be recorded for running
+// actions.
+func (a *Action) AppendOutput(stream, data string) error {
+	if stream != ActionStdout && stream != ActionStderr {
+		return errors.NotValidf("action output stream %q", stream)
+	}
+	if len(data) > maxActionOutputChunk {
+		return errors.Errorf("action output chunk of %d bytes exceeds limit of %d bytes", len(data), maxActionOutputChunk)
+	}
+	if data == "" {
+		return nil
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := a.refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if a.doc.Status != ActionRunning {
+			return nil, errors.Errorf("action %q is not running", a.Id())
+		}
+		seq := a.doc.OutputChunks
+		return []txn.Op{{
+			C:  actionsC,
+			Id: a.doc.DocId,
+			Assert: bson.D{
+				{"status", ActionRunning},
+				{"output-chunks", seq},
+			},
+			Update: bson.D{{"$inc", bson.D{{"output-chunks", 1}}}},
+		}, {
+			C:      actionOutputC,
+			Id:     a.st.docID(actionOutputId(a.Id(), seq)),
+			Assert: txn.DocMissing,
+			Insert: actionOutputDoc{
+				DocId:    a.st.docID(actionOutputId(a.Id(), seq)),
+				EnvUUID:  a.st.EnvironUUID(),
+				ActionId: a.Id(),
+				Seq:      seq,
+				Stream:   stream,
+				Data:     data,
+				Recorded: time.Now().UTC(),
+			},
+		}}, nil
+	}
+	if err := a.st.run(buildTxn); err != nil {
+		if err == jujutxn.ErrExcessiveContention {
+			return errors.Annotatef(err, "cannot record output for action %q", a.Id())
+		}
+		return err
+	}
+	a.doc.OutputChunks++
+	return nil
+}
+
+// Output returns the chunks of output recorded for the action,
+// in order, starting with the chunk with sequence number from.
+func (a *Action) Output(from int) ([]ActionOutputChunk, error) {
+	outputs, closer := a.st.getCollection(actionOutputC)
+	defer closer()
+
+	var docs []actionOutputDoc
+	sel := bson.D{{"action-id", a.Id()}, {"seq", bson.D{{"$gte", from}}}}
+	if err := outputs.Find(sel).Sort("seq").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get output for action %q", a.Id())
+	}
+	chunks := make([]ActionOutputChunk, len(docs))
+	for i, doc := range docs {
+		chunks[i] = ActionOutputChunk{
+			Seq:      doc.Seq,
+			Stream:   doc.Stream,
+			Data:     doc.Data,
+			Recorded: doc.Recorded,
+		}
+	}
+	return chunks, nil
+}
+
+// refresh reloads the action's document.
+func (a *Action) refresh() error {
+	action, err := a.st.Action(a.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	a.doc = action.doc
+	return nil
+}
+
+// actionOutputId returns the id of the chunk of output with the given
+// sequence number written by the action with the given id.
+func actionOutputId(actionId string, seq int) string {
+	return fmt.Sprintf("%s#%d", actionId, seq)
+}
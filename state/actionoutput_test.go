@@ -0,0 +1,138 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type ActionOutputSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&ActionOutputSuite{})
+
+func (s *ActionOutputSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.AddTestingCharm(c, "dummy")
+	svc := s.AddTestingService(c, "dummy", ch)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	s.unit = unit
+}
+
+func (s *ActionOutputSuite) runningAction(c *gc.C) *state.Action {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	action, err = action.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	return action
+}
+
+func (s *ActionOutputSuite) TestAppendOutput(c *gc.C) {
+	action := s.runningAction(c)
+	err := action.AppendOutput(state.ActionStdout, "one\n")
+	c.Assert(err, jc.ErrorIsNil)
+	err = action.AppendOutput(state.ActionStderr, "two\n")
+	c.Assert(err, jc.ErrorIsNil)
+	err = action.AppendOutput(state.ActionStdout, "three\n")
+	c.Assert(err, jc.ErrorIsNil)
+
+	chunks, err := action.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 3)
+	for i, expect := range []struct {
+		stream string
+		data   string
+	}{
+		{state.ActionStdout, "one\n"},
+		{state.ActionStderr, "two\n"},
+		{state.ActionStdout, "three\n"},
+	} {
+		c.Check(chunks[i].Seq, gc.Equals, i)
+		c.Check(chunks[i].Stream, gc.Equals, expect.stream)
+		c.Check(chunks[i].Data, gc.Equals, expect.data)
+		c.Check(chunks[i].Recorded.IsZero(), jc.IsFalse)
+	}
+
+	chunks, err = action.Output(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 1)
+	c.Assert(chunks[0].Data, gc.Equals, "three\n")
+
+	chunks, err = action.Output(3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 0)
+}
+
+func (s *ActionOutputSuite) TestAppendOutputStaleAction(c *gc.C) {
+	action := s.runningAction(c)
+	stale, err := s.State.Action(action.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = action.AppendOutput(state.ActionStdout, "one\n")
+	c.Assert(err, jc.ErrorIsNil)
+	err = stale.AppendOutput(state.ActionStdout, "two\n")
+	c.Assert(err, jc.ErrorIsNil)
+
+	chunks, err := action.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 2)
+	c.Assert(chunks[1].Seq, gc.Equals, 1)
+	c.Assert(chunks[1].Data, gc.Equals, "two\n")
+}
+
+func (s *ActionOutputSuite) TestAppendOutputNotRunning(c *gc.C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = action.AppendOutput(state.ActionStdout, "early\n")
+	c.Assert(err, gc.ErrorMatches, `action ".*" is not running`)
+
+	action, err = action.Begin()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = action.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	err = action.AppendOutput(state.ActionStdout, "late\n")
+	c.Assert(err, gc.ErrorMatches, `action ".*" is not running`)
+
+	chunks, err := action.Output(0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(chunks, gc.HasLen, 0)
+}
+
+func (s *ActionOutputSuite) TestAppendOutputInvalid(c *gc.C) {
+	action := s.runningAction(c)
+	err := action.AppendOutput("stdin", "foo")
+	c.Assert(err, gc.ErrorMatches, `action output stream "stdin" not valid`)
+	err = action.AppendOutput(state.ActionStdout, strings.Repeat("x", 64*1024+1))
+	c.Assert(err, gc.ErrorMatches, "action output chunk of 65537 bytes exceeds limit of 65536 bytes")
+}
+
+func (s *ActionOutputSuite) TestWatch(c *gc.C) {
+	action := s.runningAction(c)
+	w := action.Watch()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := action.AppendOutput(state.ActionStdout, "one\n")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	_, err = action.Finish(state.ActionResults{Status: state.ActionCompleted})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
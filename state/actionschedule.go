@@ -0,0 +1,374 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/utils/cron"
+)
+
+type actionScheduleDoc struct {
+	// DocId is the key for this document; it is a UUID.
+	DocId string `bson:"_id"`
+
+	// EnvUUID is the environment identifier.
+	EnvUUID string `bson:"env-uuid"`
+
+	// Receiver is the Name of the ActionReceiver for which actions
+	// are enqueued.
+	Receiver string `bson:"receiver"`
+
+	// Name identifies the action that should be run.
+	Name string `bson:"name"`
+
+	// Parameters holds the parameters given to each action enqueued.
+	Parameters map[string]interface{} `bson:"parameters"`
+
+	// Cron holds the cron expression for recurring schedules; it is
+	// empty for a schedule which runs once.
+	Cron string `bson:"cron"`
+
+	// NextRun is the time at which an action will next be enqueued.
+	NextRun time.Time `bson:"next-run"`
+
+	// Created is the time the schedule was added.
+	Created time.Time `bson:"created"`
+
+	// LastRun is the time an action was last enqueued.
+	LastRun time.Time `bson:"last-run"`
+
+	// LastAction is the id of the action last enqueued.
+	LastAction string `bson:"last-action"`
+}
+
+// ActionSchedule represents an action which will be enqueued for an
+// ActionReceiver at a future time, either once or repeatedly.
+type ActionSchedule struct {
+	st  *State
+	doc actionScheduleDoc
+}
+
+// Id returns the local id of the schedule.
+func (s *ActionSchedule) Id() string {
+	return s.st.localID(s.doc.DocId)
+}
+
+// Receiver returns the Name of the ActionReceiver for which actions
+// are enqueued.
+func (s *ActionSchedule) Receiver() string {
+	return s.doc.Receiver
+}
+
+// Name returns the name of the action, as defined in the charm.
+func (s *ActionSchedule) Name() string {
+	return s.doc.Name
+}
+
+// Parameters returns the parameters given to each action enqueued.
+func (s *ActionSchedule) Parameters() map[string]interface{} {
+	return s.doc.Parameters
+}
+
+// Cron returns the cron expression which determines when a recurring
+// schedule runs, or "" if the schedule runs only once.
+func (s *ActionSchedule) Cron() string {
+	return s.doc.Cron
+}
+
+// NextRun returns the time at which an action will next be enqueued.
+func (s *ActionSchedule) NextRun() time.Time {
+	return s.doc.NextRun
+}
+
+// Created returns the time the schedule was added.
+func (s *ActionSchedule) Created() time.Time {
+	return s.doc.Created
+}
+
+// LastRun returns the time an action was last enqueued by the
+// schedule, and the id of that action. The id is empty if the
+// schedule has not yet run.
+func (s *ActionSchedule) LastRun() (time.Time, string) {
+	return s.doc.LastRun, s.doc.LastAction
+}
+
+// Refresh refreshes the contents of the schedule from the underlying
+// state. It returns an error that satisfies errors.IsNotFound if the
+// schedule has been removed.
+func (s *ActionSchedule) Refresh() error {
+	schedules, closer := s.st.getCollection(actionSchedulesC)
+	defer closer()
+
+	err := schedules.FindId(s.doc.DocId).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("action schedule %q", s.Id())
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot refresh action schedule %q", s.Id())
+	}
+	return nil
+}
+
+// Cancel removes the schedule, so that no further actions are
+// enqueued by it.
+func (s *ActionSchedule) Cancel() error {
+	err := s.st.runTransaction([]txn.Op{{
+		C:      actionSchedulesC,
+		Id:     s.doc.DocId,
+		Assert: txn.DocExists,
+		Remove: true,
+	}})
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("action schedule %q", s.Id())
+	}
+	return errors.Annotatef(err, "cannot cancel action schedule %q", s.Id())
+}
+
+// Dispatch enqueues the scheduled action. A schedule which runs once
+// is then removed; a recurring schedule is advanced to the first time
+// it fires after now. Runs missed while no state server was available
+// to dispatch them are not made up.
+//
+// Dispatch returns ErrDead if the receiver is dead or gone, and an
+// error satisfying errors.IsNotFound if the schedule has been
+// cancelled.
+func (s *ActionSchedule) Dispatch(now time.Time) (*Action, error) {
+	receiverTag, err := names.ActionReceiverTag(s.doc.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	receiverCollectionName, receiverId, err := s.st.tagToCollectionAndId(receiverTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var nextRun time.Time
+	if s.doc.Cron != "" {
+		schedule, err := cron.Parse(s.doc.Cron)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		nextRun = schedule.Next(now.UTC())
+	}
+	doc, ndoc, err := newActionDoc(s.st, receiverTag, s.doc.Name, s.doc.Parameters)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	scheduleOp := txn.Op{
+		C:      actionSchedulesC,
+		Id:     s.doc.DocId,
+		Assert: bson.D{{"next-run", s.doc.NextRun}},
+	}
+	if nextRun.IsZero() {
+		scheduleOp.Remove = true
+	} else {
+		scheduleOp.Update = bson.D{{"$set", bson.D{
+			{"next-run", nextRun},
+			{"last-run", doc.Enqueued},
+			{"last-action", s.st.localID(doc.DocId)},
+		}}}
+	}
+	ops := []txn.Op{{
+		C:      receiverCollectionName,
+		Id:     receiverId,
+		Assert: notDeadDoc,
+	}, scheduleOp, {
+		C:      actionsC,
+		Id:     doc.DocId,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}, {
+		C:      actionNotificationsC,
+		Id:     ndoc.DocId,
+		Assert: txn.DocMissing,
+		Insert: ndoc,
+	}}
+
+	dueRun := s.doc.NextRun
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !s.doc.NextRun.Equal(dueRun) {
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		if notDead, err := isNotDead(s.st, receiverCollectionName, receiverId); err != nil {
+			return nil, errors.Trace(err)
+		} else if !notDead {
+			return nil, ErrDead
+		}
+		return ops, nil
+	}
+	if err := s.st.run(buildTxn); err == jujutxn.ErrNoOperations {
+		return nil, errors.Errorf("action schedule %q already dispatched", s.Id())
+	} else if err != nil {
+		return nil, err
+	}
+	s.doc.NextRun = nextRun
+	s.doc.LastRun = doc.Enqueued
+	s.doc.LastAction = s.st.localID(doc.DocId)
+	return newAction(s.st, doc), nil
+}
+
+// ActionScheduleParams holds the details of an action to be enqueued
+// at a future time. Exactly one of At and Cron must be set.
+type ActionScheduleParams struct {
+	// Receiver identifies the ActionReceiver for which the action
+	// will be enqueued.
+	Receiver names.Tag
+
+	// Name is the name of the action.
+	Name string
+
+	// Parameters holds the action's parameters.
+	Parameters map[string]interface{}
+
+	// At holds the time at which an action which runs once is
+	// enqueued.
+	At time.Time
+
+	// Cron holds a cron expression, evaluated in UTC, determining
+	// when a recurring action is enqueued.
+	Cron string
+}
+
+// ScheduleAction adds a schedule which enqueues the given action for
+// the receiver once at a future time, or repeatedly according to a
+// cron expression.
+func (st *State) ScheduleAction(args ActionScheduleParams) (*ActionSchedule, error) {
+	if args.Name == "" {
+		return nil, errors.New("action name required")
+	}
+	var nextRun time.Time
+	switch {
+	case args.Cron != "" && !args.At.IsZero():
+		return nil, errors.New("cannot schedule action both at a time and with a cron expression")
+	case args.Cron != "":
+		schedule, err := cron.Parse(args.Cron)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if nextRun = schedule.Next(nowToTheSecond()); nextRun.IsZero() {
+			return nil, errors.Errorf("cron expression %q never fires", args.Cron)
+		}
+	case !args.At.IsZero():
+		nextRun = args.At.Round(time.Second).UTC()
+	default:
+		return nil, errors.New("action schedule requires a time or a cron expression")
+	}
+
+	receiverCollectionName, receiverId, err := st.tagToCollectionAndId(args.Receiver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id, err := NewUUID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc := actionScheduleDoc{
+		DocId:      st.docID(id.String()),
+		EnvUUID:    st.EnvironUUID(),
+		Receiver:   args.Receiver.Id(),
+		Name:       args.Name,
+		Parameters: args.Parameters,
+		Cron:       args.Cron,
+		NextRun:    nextRun,
+		Created:    nowToTheSecond(),
+	}
+	ops := []txn.Op{{
+		C:      receiverCollectionName,
+		Id:     receiverId,
+		Assert: notDeadDoc,
+	}, {
+		C:      actionSchedulesC,
+		Id:     doc.DocId,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if notDead, err := isNotDead(st, receiverCollectionName, receiverId); err != nil {
+			return nil, errors.Trace(err)
+		} else if !notDead {
+			return nil, ErrDead
+		} else if attempt != 0 {
+			return nil, errors.Errorf("unexpected attempt number '%d'", attempt)
+		}
+		return ops, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, err
+	}
+	return &ActionSchedule{st: st, doc: doc}, nil
+}
+
+// ActionSchedule returns the action schedule with the given id.
+func (st *State) ActionSchedule(id string) (*ActionSchedule, error) {
+	schedules, closer := st.getCollection(actionSchedulesC)
+	defer closer()
+
+	var doc actionScheduleDoc
+	err := schedules.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("action schedule %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get action schedule %q", id)
+	}
+	return &ActionSchedule{st: st, doc: doc}, nil
+}
+
+// AllActionSchedules returns all the action schedules in the
+// environment, ordered by the time they next run.
+func (st *State) AllActionSchedules() ([]*ActionSchedule, error) {
+	return st.findActionSchedules(nil)
+}
+
+// DueActionSchedules returns the action schedules which are due to
+// run at the given time, ordered by the time they were due.
+func (st *State) DueActionSchedules(now time.Time) ([]*ActionSchedule, error) {
+	return st.findActionSchedules(bson.D{{"next-run", bson.D{{"$lte", now}}}})
+}
+
+// NextActionScheduleRun returns the earliest time at which any action
+// schedule in the environment runs. It returns the zero time if there
+// are no schedules.
+func (st *State) NextActionScheduleRun() (time.Time, error) {
+	schedules, closer := st.getCollection(actionSchedulesC)
+	defer closer()
+
+	var doc actionScheduleDoc
+	err := schedules.Find(nil).Sort("next-run").One(&doc)
+	if err == mgo.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Annotate(err, "cannot get next action schedule")
+	}
+	return doc.NextRun, nil
+}
+
+func (st *State) findActionSchedules(sel bson.D) ([]*ActionSchedule, error) {
+	schedules, closer := st.getCollection(actionSchedulesC)
+	defer closer()
+
+	var docs []actionScheduleDoc
+	if err := schedules.Find(sel).Sort("next-run", "_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get action schedules")
+	}
+	result := make([]*ActionSchedule, len(docs))
+	for i, doc := range docs {
+		result[i] = &ActionSchedule{st: st, doc: doc}
+	}
+	return result, nil
+}
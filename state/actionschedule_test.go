@@ -0,0 +1,266 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/utils/cron"
+)
+
+type ActionScheduleSuite struct {
+	ConnSuite
+	unit           *state.Unit
+	actionlessUnit *state.Unit
+}
+
+var _ = gc.Suite(&ActionScheduleSuite{})
+
+func (s *ActionScheduleSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.unit = s.addUnitWithCharm(c, "dummy")
+	s.actionlessUnit = s.addUnitWithCharm(c, "actionless")
+}
+
+func (s *ActionScheduleSuite) addUnitWithCharm(c *gc.C, name string) *state.Unit {
+	ch := s.AddTestingCharm(c, name)
+	svc := s.AddTestingService(c, name, ch)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	return unit
+}
+
+func (s *ActionScheduleSuite) TestScheduleActionAt(c *gc.C) {
+	at := time.Now().Add(time.Hour)
+	params := map[string]interface{}{"outfile": "out.bz2"}
+	schedule, err := s.unit.ScheduleAction("snapshot", params, at, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedule.Receiver(), gc.Equals, s.unit.Name())
+	c.Assert(schedule.Name(), gc.Equals, "snapshot")
+	c.Assert(schedule.Parameters(), jc.DeepEquals, params)
+	c.Assert(schedule.Cron(), gc.Equals, "")
+	c.Assert(schedule.NextRun().Equal(at.Round(time.Second)), jc.IsTrue)
+	lastRun, lastAction := schedule.LastRun()
+	c.Assert(lastRun.IsZero(), jc.IsTrue)
+	c.Assert(lastAction, gc.Equals, "")
+
+	stored, err := s.State.ActionSchedule(schedule.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stored.Name(), gc.Equals, "snapshot")
+	c.Assert(stored.NextRun().Equal(schedule.NextRun()), jc.IsTrue)
+	c.Assert(stored.Created().Equal(schedule.Created()), jc.IsTrue)
+}
+
+func (s *ActionScheduleSuite) TestScheduleActionCron(c *gc.C) {
+	before := state.NowToTheSecond()
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Time{}, "0 3 * * *")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedule.Cron(), gc.Equals, "0 3 * * *")
+
+	expr, err := cron.Parse("0 3 * * *")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedule.NextRun().Equal(expr.Next(before)), jc.IsTrue)
+}
+
+func (s *ActionScheduleSuite) TestScheduleActionErrors(c *gc.C) {
+	at := time.Now().Add(time.Hour)
+	for i, test := range []struct {
+		unit *state.Unit
+		name string
+		at   time.Time
+		cron string
+		err  string
+	}{{
+		unit: s.unit,
+		name: "snapshot",
+		err:  "action schedule requires a time or a cron expression",
+	}, {
+		unit: s.unit,
+		name: "snapshot",
+		at:   at,
+		cron: "@daily",
+		err:  "cannot schedule action both at a time and with a cron expression",
+	}, {
+		unit: s.unit,
+		name: "snapshot",
+		cron: "every day",
+		err:  `cron expression "every day" must have 5 fields, got 2`,
+	}, {
+		unit: s.unit,
+		name: "snapshot",
+		cron: "0 0 30 2 *",
+		err:  `cron expression "0 0 30 2 \*" never fires`,
+	}, {
+		unit: s.unit,
+		name: "backup",
+		at:   at,
+		err:  `action "backup" not defined on unit "dummy/0"`,
+	}, {
+		unit: s.actionlessUnit,
+		name: "snapshot",
+		at:   at,
+		err:  `no actions defined on charm .*`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := test.unit.ScheduleAction(test.name, nil, test.at, test.cron)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+	schedules, err := s.State.AllActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedules, gc.HasLen, 0)
+}
+
+func (s *ActionScheduleSuite) TestScheduleActionDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.ScheduleAction(state.ActionScheduleParams{
+		Receiver: s.unit.Tag(),
+		Name:     "snapshot",
+		At:       time.Now(),
+	})
+	c.Assert(err, gc.Equals, state.ErrDead)
+}
+
+func (s *ActionScheduleSuite) TestDueActionSchedules(c *gc.C) {
+	now := state.NowToTheSecond()
+	later, err := s.unit.ScheduleAction("snapshot", nil, now.Add(2*time.Hour), "")
+	c.Assert(err, jc.ErrorIsNil)
+	sooner, err := s.unit.ScheduleAction("snapshot", nil, now.Add(time.Hour), "")
+	c.Assert(err, jc.ErrorIsNil)
+
+	all, err := s.State.AllActionSchedules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 2)
+	c.Assert(all[0].Id(), gc.Equals, sooner.Id())
+	c.Assert(all[1].Id(), gc.Equals, later.Id())
+
+	due, err := s.State.DueActionSchedules(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(due, gc.HasLen, 0)
+	due, err = s.State.DueActionSchedules(now.Add(90 * time.Minute))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(due, gc.HasLen, 1)
+	c.Assert(due[0].Id(), gc.Equals, sooner.Id())
+
+	next, err := s.State.NextActionScheduleRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(next.Equal(now.Add(time.Hour)), jc.IsTrue)
+}
+
+func (s *ActionScheduleSuite) TestNextActionScheduleRunNoSchedules(c *gc.C) {
+	next, err := s.State.NextActionScheduleRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(next.IsZero(), jc.IsTrue)
+}
+
+func (s *ActionScheduleSuite) TestDispatchOnce(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", map[string]interface{}{"outfile": "out.bz2"}, time.Now(), "")
+	c.Assert(err, jc.ErrorIsNil)
+
+	action, err := schedule.Dispatch(time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(action.Name(), gc.Equals, "snapshot")
+	c.Assert(action.Receiver(), gc.Equals, s.unit.Name())
+	c.Assert(action.Parameters(), jc.DeepEquals, map[string]interface{}{"outfile": "out.bz2"})
+
+	pending, err := s.unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+	c.Assert(pending[0].Id(), gc.Equals, action.Id())
+
+	_, err = s.State.ActionSchedule(schedule.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ActionScheduleSuite) TestDispatchRecurring(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Time{}, "*/5 * * * *")
+	c.Assert(err, jc.ErrorIsNil)
+
+	now := schedule.NextRun()
+	action, err := schedule.Dispatch(now)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = schedule.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(schedule.NextRun().Equal(now.Add(5*time.Minute)), jc.IsTrue)
+	_, lastAction := schedule.LastRun()
+	c.Assert(lastAction, gc.Equals, action.Id())
+}
+
+func (s *ActionScheduleSuite) TestDispatchConcurrently(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Time{}, "@hourly")
+	c.Assert(err, jc.ErrorIsNil)
+	other, err := s.State.ActionSchedule(schedule.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = schedule.Dispatch(schedule.NextRun())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = other.Dispatch(other.NextRun())
+	c.Assert(err, gc.ErrorMatches, `action schedule ".*" already dispatched`)
+
+	pending, err := s.unit.PendingActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 1)
+}
+
+func (s *ActionScheduleSuite) TestDispatchCancelled(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Now(), "")
+	c.Assert(err, jc.ErrorIsNil)
+	other, err := s.State.ActionSchedule(schedule.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	err = other.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = schedule.Dispatch(time.Now())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ActionScheduleSuite) TestDispatchDeadReceiver(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Now(), "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = schedule.Dispatch(time.Now())
+	c.Assert(err, gc.Equals, state.ErrDead)
+}
+
+func (s *ActionScheduleSuite) TestCancel(c *gc.C) {
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Time{}, "@daily")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = schedule.Cancel()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.ActionSchedule(schedule.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = schedule.Cancel()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ActionScheduleSuite) TestWatchActionSchedules(c *gc.C) {
+	w := s.State.WatchActionSchedules()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	schedule, err := s.unit.ScheduleAction("snapshot", nil, time.Now(), "")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	_, err = schedule.Dispatch(time.Now())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -296,6 +296,9 @@ func (st *State) addMachineOps(template MachineTemplate) (*machineDoc, []txn.Op,
 				CpuCores:   template.HardwareCharacteristics.CpuCores,
 				CpuPower:   template.HardwareCharacteristics.CpuPower,
 				Tags:       template.HardwareCharacteristics.Tags,
+				NumaNodes:  template.HardwareCharacteristics.NumaNodes,
+				Gpus:       template.HardwareCharacteristics.Gpus,
+				GpuType:    template.HardwareCharacteristics.GpuType,
 				AvailZone:  template.HardwareCharacteristics.AvailabilityZone,
 			},
 		})
@@ -735,12 +738,14 @@ func (st *State) ensureAvailabilityIntentionOps(
 }
 
 // stateServerAvailable returns true if the specified state server machine is
-// available.
+// available: its agent is running and its mongo replica set member, if it
+// has one, is up.
 var stateServerAvailable = func(m *Machine) (bool, error) {
-	// TODO(axw) #1271504 2014-01-22
-	// Check the state server's associated mongo health;
-	// requires coordination with worker/peergrouper.
-	return m.AgentPresence()
+	alive, err := m.AgentPresence()
+	if err != nil || !alive {
+		return alive, err
+	}
+	return m.st.mongoMemberHealthy(m.Id()), nil
 }
 
 type ensureAvailabilityIntent struct {
@@ -5,6 +5,7 @@ package state
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/juju/errors"
@@ -24,6 +25,54 @@ type annotatorDoc struct {
 	GlobalKey   string            `bson:"globalkey"`
 	Tag         string            `bson:"tag"`
 	Annotations map[string]string `bson:"annotations"`
+	TxnRevno    int64             `bson:"txn-revno"`
+}
+
+// maxAnnotationsSize is the largest total size, in bytes, of the keys
+// and values annotating a single entity.
+const maxAnnotationsSize = 64 * 1024
+
+// validAnnotationNamespace matches the optional namespace prefix of an
+// annotation key, such as "gui" in "gui/x", which allows independent
+// clients to keep their annotations apart.
+var validAnnotationNamespace = regexp.MustCompile("^[a-z][a-z0-9-]*$")
+
+// validateAnnotationKey returns an error if the given key cannot be
+// used to annotate an entity.
+func validateAnnotationKey(key string) error {
+	if key == "" || strings.Contains(key, ".") || strings.HasPrefix(key, "$") {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	if i := strings.Index(key, "/"); i >= 0 {
+		if !validAnnotationNamespace.MatchString(key[:i]) || i == len(key)-1 {
+			return fmt.Errorf("invalid key %q: namespace must match %q and be followed by a name",
+				key, validAnnotationNamespace.String())
+		}
+	}
+	return nil
+}
+
+// checkAnnotationsSize returns an error if the annotations resulting
+// from applying the given updates and removals to current would exceed
+// maxAnnotationsSize.
+func checkAnnotationsSize(current, toInsert map[string]string, toRemove bson.M) error {
+	size := 0
+	for key, value := range current {
+		if _, ok := toInsert[key]; ok {
+			continue
+		}
+		if _, ok := toRemove["annotations."+key]; ok {
+			continue
+		}
+		size += len(key) + len(value)
+	}
+	for key, value := range toInsert {
+		size += len(key) + len(value)
+	}
+	if size > maxAnnotationsSize {
+		return fmt.Errorf("annotations of %d bytes exceed limit of %d bytes", size, maxAnnotationsSize)
+	}
+	return nil
 }
 
 // SetAnnotations adds key/value pairs to annotations in MongoDB.
@@ -37,8 +86,8 @@ func (st *State) SetAnnotations(entity GlobalEntity, annotations map[string]stri
 	toInsert := make(map[string]string)
 	toUpdate := make(bson.M)
 	for key, value := range annotations {
-		if strings.Contains(key, ".") {
-			return fmt.Errorf("invalid key %q", key)
+		if err := validateAnnotationKey(key); err != nil {
+			return err
 		}
 		if value == "" {
 			toRemove["annotations."+key] = true
@@ -56,16 +105,23 @@ func (st *State) SetAnnotations(entity GlobalEntity, annotations map[string]stri
 	buildTxn := func(attempt int) ([]txn.Op, error) {
 		annotations, closer := st.getCollection(annotationsC)
 		defer closer()
-		if count, err := annotations.FindId(entity.globalKey()).Count(); err != nil {
-			return nil, err
-		} else if count == 0 {
+		var doc annotatorDoc
+		if err := annotations.FindId(entity.globalKey()).One(&doc); err == mgo.ErrNotFound {
 			// Check that the annotator entity was not previously destroyed.
 			if attempt != 0 {
 				return nil, fmt.Errorf("%s no longer exists", entity.Tag())
 			}
+			if err := checkAnnotationsSize(nil, toInsert, nil); err != nil {
+				return nil, err
+			}
 			return insertAnnotationsOps(st, entity, toInsert)
+		} else if err != nil {
+			return nil, err
 		}
-		return updateAnnotations(st, entity, toUpdate, toRemove), nil
+		if err := checkAnnotationsSize(doc.Annotations, toInsert, toRemove); err != nil {
+			return nil, err
+		}
+		return updateAnnotations(st, entity, doc.TxnRevno, toUpdate, toRemove), nil
 	}
 	return st.run(buildTxn)
 }
@@ -138,11 +194,13 @@ func insertAnnotationsOps(st *State, entity GlobalEntity, toInsert map[string]st
 }
 
 // updateAnnotations returns the operations required to update or remove annotations in MongoDB.
-func updateAnnotations(st *State, entity GlobalEntity, toUpdate, toRemove bson.M) []txn.Op {
+// The operations assert that the document is unchanged since txnRevno was read, so that
+// the size limit checked against it still holds.
+func updateAnnotations(st *State, entity GlobalEntity, txnRevno int64, toUpdate, toRemove bson.M) []txn.Op {
 	return []txn.Op{{
 		C:      annotationsC,
 		Id:     st.docID(entity.globalKey()),
-		Assert: txn.DocExists,
+		Assert: bson.D{{"txn-revno", txnRevno}},
 		Update: setUnsetUpdate(toUpdate, toRemove),
 	}}
 }
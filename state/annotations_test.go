@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -11,6 +13,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing"
 )
 
@@ -38,6 +41,51 @@ func (s *AnnotationsSuite) TestSetAnnotationsInvalidKey(c *gc.C) {
 	c.Assert(errors.Cause(err), gc.ErrorMatches, ".*invalid key.*")
 }
 
+func (s *AnnotationsSuite) TestSetAnnotationsNamespacedKey(c *gc.C) {
+	s.assertSetAnnotation(c, "gui/x", "42")
+	assertAnnotation(c, s.State, s.testEntity, "gui/x", "42")
+
+	for _, key := range []string{"", "$set", "/x", "GUI/x", "gui/", "9gui/x"} {
+		err := s.setAnnotationResult(c, key, "value")
+		c.Check(errors.Cause(err), gc.ErrorMatches, "invalid key .*")
+	}
+}
+
+func (s *AnnotationsSuite) TestSetAnnotationsSizeLimit(c *gc.C) {
+	big := strings.Repeat("x", 40*1024)
+	s.assertSetAnnotation(c, "first", big)
+
+	err := s.setAnnotationResult(c, "second", big)
+	c.Assert(errors.Cause(err), gc.ErrorMatches, "annotations of 81931 bytes exceed limit of 65536 bytes")
+
+	// Replacing the existing value keeps within the limit.
+	s.assertSetAnnotation(c, "first", big+"y")
+	err = s.State.SetAnnotations(s.testEntity, map[string]string{"first": "", "second": big})
+	c.Assert(err, jc.ErrorIsNil)
+	assertAnnotation(c, s.State, s.testEntity, "second", big)
+}
+
+func (s *AnnotationsSuite) TestSetAnnotationsSizeLimitOnCreate(c *gc.C) {
+	err := s.setAnnotationResult(c, "key", strings.Repeat("x", 64*1024))
+	c.Assert(errors.Cause(err), gc.ErrorMatches, "annotations of 65539 bytes exceed limit of 65536 bytes")
+}
+
+func (s *AnnotationsSuite) TestWatchAnnotations(c *gc.C) {
+	w := s.State.WatchAnnotations(s.testEntity)
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	s.assertSetAnnotation(c, "key", "value")
+	wc.AssertOneChange()
+
+	s.assertSetAnnotation(c, "key", "")
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *AnnotationsSuite) TestSetAnnotationsCreate(c *gc.C) {
 	s.createTestAnnotation(c)
 }
@@ -920,6 +920,42 @@ var assignUsingConstraintsTests = []struct {
 		unitConstraints:         "root-disk=8192",
 		hardwareCharacteristics: "root-disk=8192",
 		assignOk:                true,
+	}, {
+		unitConstraints:         "zones=az1,az2",
+		hardwareCharacteristics: "availability-zone=az2",
+		assignOk:                true,
+	}, {
+		unitConstraints:         "zones=az1,az2",
+		hardwareCharacteristics: "availability-zone=az3",
+		assignOk:                false,
+	}, {
+		unitConstraints:         "zones=az1",
+		hardwareCharacteristics: "mem=4G",
+		assignOk:                false,
+	}, {
+		unitConstraints:         "gpus=2",
+		hardwareCharacteristics: "gpus=4",
+		assignOk:                true,
+	}, {
+		unitConstraints:         "gpus=2",
+		hardwareCharacteristics: "gpus=1",
+		assignOk:                false,
+	}, {
+		unitConstraints:         "gpus=1",
+		hardwareCharacteristics: "mem=4G",
+		assignOk:                false,
+	}, {
+		unitConstraints:         "gpus=1 gpu-type=nvidia",
+		hardwareCharacteristics: "gpus=1 gpu-type=nvidia",
+		assignOk:                true,
+	}, {
+		unitConstraints:         "gpu-type=nvidia",
+		hardwareCharacteristics: "gpus=1 gpu-type=amd",
+		assignOk:                false,
+	}, {
+		unitConstraints:         "zones=",
+		hardwareCharacteristics: "availability-zone=az3",
+		assignOk:                true,
 	}, {
 		unitConstraints:         "arch=amd64 mem=4G cpu-cores=2 root-disk=8192",
 		hardwareCharacteristics: "arch=amd64 mem=8G cpu-cores=2 root-disk=8192 cpu-power=50",
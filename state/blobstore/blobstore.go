@@ -0,0 +1,362 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+var logger = loggo.GetLogger("juju.state.blobstore")
+
+const (
+	// metadataDB is the name of the database holding the blob
+	// metadata collections.
+	metadataDB = "juju"
+
+	// blobsC holds a document for each distinct blob, keyed on the
+	// SHA-256 hash of its content.
+	blobsC = "blobs"
+
+	// blobpathsC maps the paths within each environment onto the
+	// hashes of the blobs stored at them.
+	blobpathsC = "blobpaths"
+
+	// blobuploadsC records the progress of resumable uploads.
+	blobuploadsC = "blobuploads"
+
+	// contentDB is the name of the GridFS database holding the blobs'
+	// content and the parts of unfinished uploads.
+	contentDB = "blobstore"
+
+	// contentPrefix is the GridFS prefix of the blobs' content.
+	contentPrefix = "content"
+
+	// uploadsPrefix is the GridFS prefix of the parts of unfinished
+	// uploads.
+	uploadsPrefix = "uploads"
+)
+
+// blobDoc describes a distinct blob, and records how many paths refer
+// to it.
+type blobDoc struct {
+	SHA256   string        `bson:"_id"`
+	FileId   bson.ObjectId `bson:"fileid"`
+	Size     int64         `bson:"size"`
+	RefCount int           `bson:"refcount"`
+}
+
+// pathDoc records the blob stored at a path within an environment.
+type pathDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+	Path    string `bson:"path"`
+	SHA256  string `bson:"sha256"`
+}
+
+type blobStorage struct {
+	envUUID string
+	session *mgo.Session
+}
+
+var _ Storage = (*blobStorage)(nil)
+
+// NewStorage returns a Storage for the environment with the given
+// UUID. Each operation uses its own copy of the given session.
+func NewStorage(envUUID string, session *mgo.Session) Storage {
+	return &blobStorage{envUUID: envUUID, session: session}
+}
+
+// dbs holds the databases used by a single operation.
+type dbs struct {
+	session  *mgo.Session
+	metadata *mgo.Database
+	content  *mgo.GridFS
+	uploads  *mgo.GridFS
+	runner   jujutxn.Runner
+}
+
+func (s *blobStorage) open() *dbs {
+	session := s.session.Copy()
+	metadata := session.DB(metadataDB)
+	return &dbs{
+		session:  session,
+		metadata: metadata,
+		content:  session.DB(contentDB).GridFS(contentPrefix),
+		uploads:  session.DB(contentDB).GridFS(uploadsPrefix),
+		runner:   txnRunner(metadata),
+	}
+}
+
+// Override for testing.
+var txnRunner = func(db *mgo.Database) jujutxn.Runner {
+	return jujutxn.NewRunner(jujutxn.RunnerParams{Database: db})
+}
+
+func (s *blobStorage) pathDocID(path string) string {
+	return s.envUUID + ":" + path
+}
+
+// Get is defined on the Storage interface.
+func (s *blobStorage) Get(path string) (io.ReadCloser, Metadata, error) {
+	db := s.open()
+	doc, blob, err := s.blob(db, path)
+	if err != nil {
+		db.session.Close()
+		return nil, Metadata{}, errors.Trace(err)
+	}
+	f, err := db.content.OpenId(blob.FileId)
+	if err != nil {
+		db.session.Close()
+		return nil, Metadata{}, errors.Annotatef(err, "cannot open blob %q", blob.SHA256)
+	}
+	metadata := Metadata{Path: doc.Path, SHA256: blob.SHA256, Size: blob.Size}
+	return &blobReadCloser{f, db.session}, metadata, nil
+}
+
+// Metadata is defined on the Storage interface.
+func (s *blobStorage) Metadata(path string) (Metadata, error) {
+	db := s.open()
+	defer db.session.Close()
+	doc, blob, err := s.blob(db, path)
+	if err != nil {
+		return Metadata{}, errors.Trace(err)
+	}
+	return Metadata{Path: doc.Path, SHA256: blob.SHA256, Size: blob.Size}, nil
+}
+
+// blob returns the documents describing the path and the blob stored
+// at it.
+func (s *blobStorage) blob(db *dbs, path string) (pathDoc, blobDoc, error) {
+	var doc pathDoc
+	err := db.metadata.C(blobpathsC).FindId(s.pathDocID(path)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return pathDoc{}, blobDoc{}, errors.NotFoundf("blob at path %q", path)
+	} else if err != nil {
+		return pathDoc{}, blobDoc{}, errors.Annotatef(err, "cannot read path %q", path)
+	}
+	var blob blobDoc
+	err = db.metadata.C(blobsC).FindId(doc.SHA256).One(&blob)
+	if err == mgo.ErrNotFound {
+		// The blob was replaced or removed since we read the path.
+		return pathDoc{}, blobDoc{}, errors.NotFoundf("blob at path %q", path)
+	} else if err != nil {
+		return pathDoc{}, blobDoc{}, errors.Annotatef(err, "cannot read blob %q", doc.SHA256)
+	}
+	return doc, blob, nil
+}
+
+// Put is defined on the Storage interface.
+func (s *blobStorage) Put(path string, r io.Reader, size int64) (Metadata, error) {
+	db := s.open()
+	defer db.session.Close()
+	fileId, hash, err := writeFile(db.content, r, size)
+	if err != nil {
+		return Metadata{}, errors.Annotatef(err, "cannot store content at path %q", path)
+	}
+	if err := s.commit(db, path, fileId, hash, size); err != nil {
+		return Metadata{}, errors.Annotatef(err, "cannot store content at path %q", path)
+	}
+	return Metadata{Path: path, SHA256: hash, Size: size}, nil
+}
+
+// writeFile streams size bytes read from r into a new file in the
+// given GridFS, and returns the file's id and the hex-encoded SHA-256
+// hash of its content.
+func writeFile(gfs *mgo.GridFS, r io.Reader, size int64) (bson.ObjectId, string, error) {
+	f, err := gfs.Create("")
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, size))
+	if err == nil && n != size {
+		err = errors.Errorf("expected %d bytes, read %d", size, n)
+	}
+	if err != nil {
+		// Closing an aborted file removes any chunks written.
+		f.Abort()
+		f.Close()
+		return "", "", errors.Trace(err)
+	}
+	if err := f.Close(); err != nil {
+		return "", "", errors.Trace(err)
+	}
+	return f.Id().(bson.ObjectId), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// commit stores the blob with the given hash, whose content has been
+// written to the content file with the given id, at path. If the blob
+// is already stored, the new content file is discarded.
+func (s *blobStorage) commit(db *dbs, path string, fileId bson.ObjectId, hash string, size int64) (err error) {
+	var unusedFiles []bson.ObjectId
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		unusedFiles = nil
+		var old pathDoc
+		pathOp := txn.Op{C: blobpathsC, Id: s.pathDocID(path)}
+		err := db.metadata.C(blobpathsC).FindId(pathOp.Id).One(&old)
+		switch {
+		case err == mgo.ErrNotFound:
+			pathOp.Assert = txn.DocMissing
+			pathOp.Insert = pathDoc{
+				DocID:   s.pathDocID(path),
+				EnvUUID: s.envUUID,
+				Path:    path,
+				SHA256:  hash,
+			}
+		case err != nil:
+			return nil, errors.Trace(err)
+		case old.SHA256 == hash:
+			unusedFiles = []bson.ObjectId{fileId}
+			return nil, jujutxn.ErrNoOperations
+		default:
+			pathOp.Assert = bson.D{{"sha256", old.SHA256}}
+			pathOp.Update = bson.D{{"$set", bson.D{{"sha256", hash}}}}
+		}
+		incOp, inserted, err := incRefOp(db, hash, fileId, size)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !inserted {
+			unusedFiles = append(unusedFiles, fileId)
+		}
+		ops := []txn.Op{incOp, pathOp}
+		if old.SHA256 != "" {
+			decOp, removedFile, err := decRefOp(db, old.SHA256)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if removedFile != "" {
+				unusedFiles = append(unusedFiles, removedFile)
+			}
+			ops = append(ops, decOp)
+		}
+		return ops, nil
+	}
+	if err := db.runner.Run(buildTxn); err != nil {
+		removeFiles(db.content, fileId)
+		return errors.Trace(err)
+	}
+	removeFiles(db.content, unusedFiles...)
+	return nil
+}
+
+// Remove is defined on the Storage interface.
+func (s *blobStorage) Remove(path string) error {
+	db := s.open()
+	defer db.session.Close()
+	var removedFile bson.ObjectId
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		removedFile = ""
+		var doc pathDoc
+		err := db.metadata.C(blobpathsC).FindId(s.pathDocID(path)).One(&doc)
+		if err == mgo.ErrNotFound {
+			return nil, errors.NotFoundf("blob at path %q", path)
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		decOp, fileId, err := decRefOp(db, doc.SHA256)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		removedFile = fileId
+		return []txn.Op{{
+			C:      blobpathsC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"sha256", doc.SHA256}},
+			Remove: true,
+		}, decOp}, nil
+	}
+	if err := db.runner.Run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot remove path %q", path)
+	}
+	if removedFile != "" {
+		removeFiles(db.content, removedFile)
+	}
+	return nil
+}
+
+// incRefOp returns an operation that adds a reference to the blob with
+// the given hash, inserting it with the given content file if it is not
+// yet stored, and whether it does so.
+func incRefOp(db *dbs, hash string, fileId bson.ObjectId, size int64) (txn.Op, bool, error) {
+	count, err := db.metadata.C(blobsC).FindId(hash).Count()
+	if err != nil {
+		return txn.Op{}, false, errors.Trace(err)
+	}
+	if count == 0 {
+		return txn.Op{
+			C:      blobsC,
+			Id:     hash,
+			Assert: txn.DocMissing,
+			Insert: blobDoc{
+				SHA256:   hash,
+				FileId:   fileId,
+				Size:     size,
+				RefCount: 1,
+			},
+		}, true, nil
+	}
+	return txn.Op{
+		C:      blobsC,
+		Id:     hash,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$inc", bson.D{{"refcount", 1}}}},
+	}, false, nil
+}
+
+// decRefOp returns an operation that removes a reference to the blob
+// with the given hash. If it is the last reference, the operation
+// removes the blob, and the id of its content file is returned so the
+// caller can remove the content once the operation has run.
+func decRefOp(db *dbs, hash string) (txn.Op, bson.ObjectId, error) {
+	var doc blobDoc
+	if err := db.metadata.C(blobsC).FindId(hash).One(&doc); err != nil {
+		return txn.Op{}, "", errors.Annotatef(err, "cannot read blob %q", hash)
+	}
+	if doc.RefCount <= 1 {
+		return txn.Op{
+			C:      blobsC,
+			Id:     hash,
+			Assert: bson.D{{"refcount", doc.RefCount}},
+			Remove: true,
+		}, doc.FileId, nil
+	}
+	return txn.Op{
+		C:      blobsC,
+		Id:     hash,
+		Assert: bson.D{{"refcount", bson.D{{"$gt", 1}}}},
+		Update: bson.D{{"$inc", bson.D{{"refcount", -1}}}},
+	}, "", nil
+}
+
+// removeFiles removes the files with the given ids from the GridFS.
+// Failures are logged rather than returned: the files are no longer
+// referred to, so at worst their space is leaked.
+func removeFiles(gfs *mgo.GridFS, fileIds ...bson.ObjectId) {
+	for _, fileId := range fileIds {
+		if err := gfs.RemoveId(fileId); err != nil {
+			logger.Errorf("cannot remove file %v: %v", fileId.Hex(), err)
+		}
+	}
+}
+
+type blobReadCloser struct {
+	io.ReadCloser
+	session *mgo.Session
+}
+
+func (r *blobReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.session.Close()
+	return err
+}
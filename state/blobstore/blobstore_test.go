@@ -0,0 +1,240 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobstore_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/blobstore"
+	"github.com/juju/juju/testing"
+)
+
+const (
+	testUUID  = "9f484882-2f18-4fd2-967d-db9663db7bea"
+	otherUUID = "7a5b1c4e-0d2e-4b6f-8c1a-5e2f3d4c6b7a"
+)
+
+type BlobStoreSuite struct {
+	gitjujutesting.MgoSuite
+	testing.BaseSuite
+	storage blobstore.Storage
+}
+
+var _ = gc.Suite(&BlobStoreSuite{})
+
+func (s *BlobStoreSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	s.MgoSuite.SetUpSuite(c)
+}
+
+func (s *BlobStoreSuite) TearDownSuite(c *gc.C) {
+	s.MgoSuite.TearDownSuite(c)
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *BlobStoreSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.MgoSuite.SetUpTest(c)
+	s.storage = blobstore.NewStorage(testUUID, s.Session)
+}
+
+func (s *BlobStoreSuite) TearDownTest(c *gc.C) {
+	s.MgoSuite.TearDownTest(c)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *BlobStoreSuite) assertContent(c *gc.C, stor blobstore.Storage, path, content string) {
+	r, metadata, err := stor.Get(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	c.Assert(metadata, jc.DeepEquals, blobstore.Metadata{
+		Path:   path,
+		SHA256: hash(content),
+		Size:   int64(len(content)),
+	})
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, content)
+}
+
+func (s *BlobStoreSuite) assertContentFiles(c *gc.C, expected int) {
+	count, err := s.Session.DB("blobstore").C("content.files").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, expected)
+}
+
+func (s *BlobStoreSuite) TestPutGet(c *gc.C) {
+	metadata, err := s.storage.Put("path", strings.NewReader("abcdef"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, jc.DeepEquals, blobstore.Metadata{
+		Path:   "path",
+		SHA256: hash("abc"),
+		Size:   3,
+	})
+	s.assertContent(c, s.storage, "path", "abc")
+
+	metadata, err = s.storage.Metadata("path")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata.SHA256, gc.Equals, hash("abc"))
+}
+
+func (s *BlobStoreSuite) TestGetNotFound(c *gc.C) {
+	_, _, err := s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `blob at path "path" not found`)
+	_, err = s.storage.Metadata("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *BlobStoreSuite) TestPutShortContent(c *gc.C) {
+	_, err := s.storage.Put("path", strings.NewReader("ab"), 3)
+	c.Assert(err, gc.ErrorMatches, `cannot store content at path "path": expected 3 bytes, read 2`)
+	_, _, err = s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.assertContentFiles(c, 0)
+}
+
+func (s *BlobStoreSuite) TestPutDeduplicates(c *gc.C) {
+	other := blobstore.NewStorage(otherUUID, s.Session)
+	_, err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.Put("another-path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = other.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContentFiles(c, 1)
+
+	s.assertContent(c, s.storage, "path", "abc")
+	s.assertContent(c, s.storage, "another-path", "abc")
+	s.assertContent(c, other, "path", "abc")
+}
+
+func (s *BlobStoreSuite) TestPutSameContentAgain(c *gc.C) {
+	_, err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContentFiles(c, 1)
+
+	// There is still only one reference to the content.
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContentFiles(c, 0)
+}
+
+func (s *BlobStoreSuite) TestPutReplaces(c *gc.C) {
+	_, err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.Put("path", strings.NewReader("defghi"), 6)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContent(c, s.storage, "path", "defghi")
+	s.assertContentFiles(c, 1)
+}
+
+func (s *BlobStoreSuite) TestRemove(c *gc.C) {
+	_, err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.assertContentFiles(c, 0)
+
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *BlobStoreSuite) TestRemoveSharedContent(c *gc.C) {
+	other := blobstore.NewStorage(otherUUID, s.Session)
+	_, err := s.storage.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = other.Put("path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	s.assertContent(c, other, "path", "abc")
+	s.assertContentFiles(c, 1)
+}
+
+func (s *BlobStoreSuite) TestResumableUpload(c *gc.C) {
+	id, err := s.storage.BeginUpload("path", 9)
+	c.Assert(err, jc.ErrorIsNil)
+	offset, err := s.storage.UploadOffset(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offset, gc.Equals, int64(0))
+
+	err = s.storage.WriteUpload(id, 0, strings.NewReader("abcd"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+	offset, err = s.storage.UploadOffset(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offset, gc.Equals, int64(4))
+
+	// A part may only be written at the upload's offset.
+	err = s.storage.WriteUpload(id, 0, strings.NewReader("abcd"), 4)
+	c.Assert(err, gc.ErrorMatches, `upload ".*" expects offset 4, not 0`)
+	err = s.storage.WriteUpload(id, 4, strings.NewReader("efghijk"), 7)
+	c.Assert(err, gc.ErrorMatches, `upload ".*" expects 9 bytes, not 11`)
+
+	_, err = s.storage.FinishUpload(id)
+	c.Assert(err, gc.ErrorMatches, `upload ".*" incomplete: received 4 of 9 bytes`)
+
+	err = s.storage.WriteUpload(id, 4, strings.NewReader("efghi"), 5)
+	c.Assert(err, jc.ErrorIsNil)
+	metadata, err := s.storage.FinishUpload(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata, jc.DeepEquals, blobstore.Metadata{
+		Path:   "path",
+		SHA256: hash("abcdefghi"),
+		Size:   9,
+	})
+	s.assertContent(c, s.storage, "path", "abcdefghi")
+
+	_, err = s.storage.UploadOffset(id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	count, err := s.Session.DB("blobstore").C("uploads.files").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+}
+
+func (s *BlobStoreSuite) TestAbortUpload(c *gc.C) {
+	id, err := s.storage.BeginUpload("path", 9)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.storage.WriteUpload(id, 0, strings.NewReader("abcd"), 4)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.AbortUpload(id)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.storage.UploadOffset(id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, _, err = s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	count, err := s.Session.DB("blobstore").C("uploads.files").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+}
+
+func (s *BlobStoreSuite) TestUploadOtherEnvironment(c *gc.C) {
+	id, err := s.storage.BeginUpload("path", 9)
+	c.Assert(err, jc.ErrorIsNil)
+	other := blobstore.NewStorage(otherUUID, s.Session)
+	_, err = other.UploadOffset(id)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
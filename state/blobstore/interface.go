@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package blobstore provides a content-addressable store for the blobs
+// held by the controller. Blobs are keyed on the SHA-256 hash of their
+// content, and each environment refers to them by path; content shared
+// by several paths, in any number of environments, is stored once and
+// removed when the last path referring to it is removed.
+//
+// Content is streamed in and out of the store, and large blobs may be
+// uploaded in several parts, resuming after a failure from the last
+// part received.
+package blobstore
+
+import (
+	"io"
+)
+
+// Metadata describes the content stored at a path.
+type Metadata struct {
+	// Path is the path of the content within its environment.
+	Path string
+
+	// SHA256 is the hex-encoded SHA-256 hash of the content.
+	SHA256 string
+
+	// Size is the size of the content in bytes.
+	Size int64
+}
+
+// Storage provides methods for storing and retrieving content by path
+// within an environment.
+type Storage interface {
+	// Get returns a reader for the content stored at path, and its
+	// metadata. If there is no such content, an error satisfying
+	// errors.IsNotFound is returned.
+	Get(path string) (io.ReadCloser, Metadata, error)
+
+	// Metadata returns the metadata of the content stored at path.
+	// If there is no such content, an error satisfying
+	// errors.IsNotFound is returned.
+	Metadata(path string) (Metadata, error)
+
+	// Put stores size bytes read from r at path, replacing any content
+	// already stored there, and returns its metadata.
+	Put(path string, r io.Reader, size int64) (Metadata, error)
+
+	// Remove removes the content stored at path. If there is no such
+	// content, an error satisfying errors.IsNotFound is returned.
+	Remove(path string) error
+
+	// BeginUpload starts an upload of size bytes to path, and returns
+	// an id with which to add to, finish or abort the upload.
+	BeginUpload(path string, size int64) (string, error)
+
+	// UploadOffset returns the number of bytes received so far by the
+	// upload with the given id, which is the offset at which the
+	// upload must be resumed.
+	UploadOffset(id string) (int64, error)
+
+	// WriteUpload adds length bytes read from r to the upload with the
+	// given id. The offset must be the upload's current offset.
+	WriteUpload(id string, offset int64, r io.Reader, length int64) error
+
+	// FinishUpload stores the content received by the upload with the
+	// given id at the upload's path, and returns its metadata. All of
+	// the upload's content must have been received.
+	FinishUpload(id string) (Metadata, error)
+
+	// AbortUpload discards the upload with the given id.
+	AbortUpload(id string) error
+}
@@ -0,0 +1,183 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package blobstore
+
+import (
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// uploadDoc records the progress of a resumable upload. Each part
+// received is held in its own file until the upload is finished.
+type uploadDoc struct {
+	Id       string          `bson:"_id"`
+	EnvUUID  string          `bson:"env-uuid"`
+	Path     string          `bson:"path"`
+	Size     int64           `bson:"size"`
+	Received int64           `bson:"received"`
+	Parts    []bson.ObjectId `bson:"parts"`
+	Started  time.Time       `bson:"started"`
+}
+
+// BeginUpload is defined on the Storage interface.
+func (s *blobStorage) BeginUpload(path string, size int64) (string, error) {
+	if size < 0 {
+		return "", errors.NotValidf("upload size %d", size)
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	db := s.open()
+	defer db.session.Close()
+	doc := uploadDoc{
+		Id:      uuid.String(),
+		EnvUUID: s.envUUID,
+		Path:    path,
+		Size:    size,
+		Started: time.Now().UTC(),
+	}
+	ops := []txn.Op{{
+		C:      blobuploadsC,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := db.runner.RunTransaction(ops); err != nil {
+		return "", errors.Annotatef(err, "cannot begin upload to path %q", path)
+	}
+	return doc.Id, nil
+}
+
+// UploadOffset is defined on the Storage interface.
+func (s *blobStorage) UploadOffset(id string) (int64, error) {
+	db := s.open()
+	defer db.session.Close()
+	doc, err := s.upload(db, id)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return doc.Received, nil
+}
+
+// WriteUpload is defined on the Storage interface.
+func (s *blobStorage) WriteUpload(id string, offset int64, r io.Reader, length int64) error {
+	db := s.open()
+	defer db.session.Close()
+	doc, err := s.upload(db, id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if offset != doc.Received {
+		return errors.Errorf("upload %q expects offset %d, not %d", id, doc.Received, offset)
+	}
+	if offset+length > doc.Size {
+		return errors.Errorf("upload %q expects %d bytes, not %d", id, doc.Size, offset+length)
+	}
+	fileId, _, err := writeFile(db.uploads, r, length)
+	if err != nil {
+		return errors.Annotatef(err, "cannot write upload %q", id)
+	}
+	ops := []txn.Op{{
+		C:      blobuploadsC,
+		Id:     id,
+		Assert: bson.D{{"received", offset}},
+		Update: bson.D{
+			{"$inc", bson.D{{"received", length}}},
+			{"$push", bson.D{{"parts", fileId}}},
+		},
+	}}
+	if err := db.runner.RunTransaction(ops); err != nil {
+		removeFiles(db.uploads, fileId)
+		if err == txn.ErrAborted {
+			return errors.Errorf("upload %q was written concurrently", id)
+		}
+		return errors.Annotatef(err, "cannot write upload %q", id)
+	}
+	return nil
+}
+
+// FinishUpload is defined on the Storage interface.
+func (s *blobStorage) FinishUpload(id string) (Metadata, error) {
+	db := s.open()
+	defer db.session.Close()
+	doc, err := s.upload(db, id)
+	if err != nil {
+		return Metadata{}, errors.Trace(err)
+	}
+	if doc.Received != doc.Size {
+		return Metadata{}, errors.Errorf(
+			"upload %q incomplete: received %d of %d bytes", id, doc.Received, doc.Size,
+		)
+	}
+
+	// Stream the parts into a single content file.
+	readers := make([]io.Reader, len(doc.Parts))
+	for i, partId := range doc.Parts {
+		f, err := db.uploads.OpenId(partId)
+		if err != nil {
+			return Metadata{}, errors.Annotatef(err, "cannot open part %d of upload %q", i, id)
+		}
+		defer f.Close()
+		readers[i] = f
+	}
+	fileId, hash, err := writeFile(db.content, io.MultiReader(readers...), doc.Size)
+	if err != nil {
+		return Metadata{}, errors.Annotatef(err, "cannot finish upload %q", id)
+	}
+	if err := s.commit(db, doc.Path, fileId, hash, doc.Size); err != nil {
+		return Metadata{}, errors.Annotatef(err, "cannot finish upload %q", id)
+	}
+	if err := s.removeUpload(db, doc); err != nil {
+		// The content is stored; the upload will be removed again
+		// if it is aborted.
+		logger.Errorf("cannot remove finished upload %q: %v", id, err)
+	}
+	return Metadata{Path: doc.Path, SHA256: hash, Size: doc.Size}, nil
+}
+
+// AbortUpload is defined on the Storage interface.
+func (s *blobStorage) AbortUpload(id string) error {
+	db := s.open()
+	defer db.session.Close()
+	doc, err := s.upload(db, id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(s.removeUpload(db, doc))
+}
+
+// upload returns the document recording the progress of the upload
+// with the given id.
+func (s *blobStorage) upload(db *dbs, id string) (uploadDoc, error) {
+	var doc uploadDoc
+	err := db.metadata.C(blobuploadsC).FindId(id).One(&doc)
+	if err == mgo.ErrNotFound || err == nil && doc.EnvUUID != s.envUUID {
+		return uploadDoc{}, errors.NotFoundf("upload %q", id)
+	} else if err != nil {
+		return uploadDoc{}, errors.Annotatef(err, "cannot read upload %q", id)
+	}
+	return doc, nil
+}
+
+// removeUpload removes the given upload's document and its parts.
+func (s *blobStorage) removeUpload(db *dbs, doc uploadDoc) error {
+	ops := []txn.Op{{
+		C:      blobuploadsC,
+		Id:     doc.Id,
+		Assert: bson.D{{"received", doc.Received}},
+		Remove: true,
+	}}
+	if err := db.runner.RunTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove upload %q", doc.Id)
+	}
+	removeFiles(db.uploads, doc.Parts...)
+	return nil
+}
@@ -45,12 +45,23 @@ const (
 	// ChangeBlock type identifies block that prevents environment changes such
 	// as additions, modifications, removals of environment entities.
 	ChangeBlock
+
+	// BudgetBlock type identifies block that prevents operations that
+	// may increase the cost of running the environment, such as adding
+	// machines, units or state servers.
+	BudgetBlock
+
+	// RebootBlock type identifies block that prevents machines from
+	// being rebooted at the request of charms.
+	RebootBlock
 )
 
 var typeNames = map[BlockType]multiwatcher.BlockType{
 	DestroyBlock: multiwatcher.BlockDestroy,
 	RemoveBlock:  multiwatcher.BlockRemove,
 	ChangeBlock:  multiwatcher.BlockChange,
+	BudgetBlock:  multiwatcher.BlockBudget,
+	RebootBlock:  multiwatcher.BlockReboot,
 }
 
 // AllTypes returns all supported block types.
@@ -59,6 +70,8 @@ func AllTypes() []BlockType {
 		DestroyBlock,
 		RemoveBlock,
 		ChangeBlock,
+		BudgetBlock,
+		RebootBlock,
 	}
 }
 
@@ -91,6 +91,8 @@ func (s *blockSuite) TestNewEnvironmentNotBlocked(c *gc.C) {
 	s.assertNoTypedBlock(c, state.DestroyBlock)
 	s.assertNoTypedBlock(c, state.RemoveBlock)
 	s.assertNoTypedBlock(c, state.ChangeBlock)
+	s.assertNoTypedBlock(c, state.BudgetBlock)
+	s.assertNoTypedBlock(c, state.RebootBlock)
 }
 
 func (s *blockSuite) TestDestroyBlocked(c *gc.C) {
@@ -105,6 +107,16 @@ func (s *blockSuite) TestChangeBlocked(c *gc.C) {
 	s.assertBlocked(c, state.ChangeBlock)
 }
 
+func (s *blockSuite) TestBudgetBlocked(c *gc.C) {
+	s.assertBlocked(c, state.BudgetBlock)
+	c.Assert(state.ParseBlockType("BlockBudget"), gc.Equals, state.BudgetBlock)
+}
+
+func (s *blockSuite) TestRebootBlocked(c *gc.C) {
+	s.assertBlocked(c, state.RebootBlock)
+	c.Assert(state.ParseBlockType("BlockReboot"), gc.Equals, state.RebootBlock)
+}
+
 func (s *blockSuite) TestNonsenseBlocked(c *gc.C) {
 	bType := state.BlockType(42)
 	// This could be useful for entity blocks...
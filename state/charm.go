@@ -6,8 +6,11 @@ package state
 import (
 	"net/url"
 
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/juju/paths"
 )
 
 // charmDoc represents the internal state of a charm in MongoDB.
@@ -20,12 +23,25 @@ type charmDoc struct {
 	Actions *charm.Actions
 	Metrics *charm.Metrics
 
+	// ConfigRules holds the validation rules declared for the
+	// charm's config options, keyed on option name.
+	ConfigRules map[string]ConfigRule `bson:"configrules,omitempty"`
+
+	// Interpreters holds the names of the interpreters the charm's
+	// hooks require.
+	Interpreters []string `bson:"interpreters,omitempty"`
+
+	// RelationLimits holds the limits declared explicitly on the
+	// charm's relations, keyed on relation name.
+	RelationLimits map[string]int `bson:"relationlimits,omitempty"`
+
 	// DEPRECATED: BundleURL is deprecated, and exists here
 	// only for migration purposes. We should remove this
 	// when migrations are no longer necessary.
 	BundleURL *url.URL `bson:"bundleurl,omitempty"`
 
 	BundleSha256  string
+	BundleSize    int64
 	StoragePath   string
 	PendingUpload bool
 	Placeholder   bool
@@ -49,6 +65,13 @@ func newCharm(st *State, cdoc *charmDoc) *Charm {
 		}
 		cdoc.Config = unescapedConfig
 	}
+	if cdoc != nil && len(cdoc.ConfigRules) > 0 {
+		unescapedRules := make(map[string]ConfigRule)
+		for optionName, rule := range cdoc.ConfigRules {
+			unescapedRules[unescapeReplacer.Replace(optionName)] = rule
+		}
+		cdoc.ConfigRules = unescapedRules
+	}
 	return &Charm{st: st, doc: *cdoc}
 }
 
@@ -96,6 +119,25 @@ func (c *Charm) Config() *charm.Config {
 	return c.doc.Config
 }
 
+// ConfigRules returns the validation rules declared for the charm's
+// config options, keyed on option name.
+func (c *Charm) ConfigRules() map[string]ConfigRule {
+	return c.doc.ConfigRules
+}
+
+// Interpreters returns the names of the interpreters the charm's hooks
+// require, as declared in the charm's metadata.
+func (c *Charm) Interpreters() []string {
+	return c.doc.Interpreters
+}
+
+// RelationLimits returns the maximum number of relations the charm
+// allows on each of its endpoints, keyed on relation name. Endpoints
+// without a declared limit are omitted.
+func (c *Charm) RelationLimits() map[string]int {
+	return c.doc.RelationLimits
+}
+
 // Metrics returns the metrics declared for the charm.
 func (c *Charm) Metrics() *charm.Metrics {
 	return c.doc.Metrics
@@ -120,6 +162,12 @@ func (c *Charm) BundleURL() *url.URL {
 	return c.doc.BundleURL
 }
 
+// BundleSize returns the size of the charm bundle in bytes, or 0 if it
+// was not recorded when the charm was stored.
+func (c *Charm) BundleSize() int64 {
+	return c.doc.BundleSize
+}
+
 // BundleSha256 returns the SHA256 digest of the charm bundle bytes.
 func (c *Charm) BundleSha256() string {
 	return c.doc.BundleSha256
@@ -136,3 +184,14 @@ func (c *Charm) IsUploaded() bool {
 func (c *Charm) IsPlaceholder() bool {
 	return c.doc.Placeholder
 }
+
+// charmInterpreters returns the names of the interpreters required by
+// the given charm, if it is read from a directory or an archive.
+func charmInterpreters(ch charm.Charm) ([]string, error) {
+	r, err := openCharmFile(ch, "metadata.yaml")
+	if err != nil || r == nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Close()
+	return paths.ReadInterpreters(r)
+}
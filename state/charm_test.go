@@ -69,6 +69,18 @@ func (s *CharmSuite) TestCharm(c *gc.C) {
 		})
 }
 
+func (s *CharmSuite) TestCharmInterpreters(c *gc.C) {
+	ch := s.AddTestingCharm(c, "interpreters")
+	c.Assert(ch.Interpreters(), gc.DeepEquals, []string{"bash", "python"})
+
+	ch, err := s.State.Charm(ch.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Interpreters(), gc.DeepEquals, []string{"bash", "python"})
+
+	ch = s.AddTestingCharm(c, "dummy")
+	c.Assert(ch.Interpreters(), gc.HasLen, 0)
+}
+
 func (s *CharmSuite) TestCharmNotFound(c *gc.C) {
 	curl := charm.MustParseURL("local:anotherseries/dummy-1")
 	_, err := s.State.Charm(curl)
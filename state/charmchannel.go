@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// CharmChannel identifies a channel of the charm store from which
+// a service's charm revisions are resolved.
+type CharmChannel string
+
+const (
+	// StableChannel holds charm revisions released for general use.
+	// Services track it unless deployed or upgraded from another
+	// channel.
+	StableChannel CharmChannel = "stable"
+
+	// CandidateChannel holds charm revisions under final testing
+	// before release to the stable channel.
+	CandidateChannel CharmChannel = "candidate"
+
+	// EdgeChannel holds the most recently published charm revisions.
+	EdgeChannel CharmChannel = "edge"
+)
+
+// Validate returns an error if the channel is not known.
+func (c CharmChannel) Validate() error {
+	switch c {
+	case StableChannel, CandidateChannel, EdgeChannel:
+		return nil
+	}
+	return errors.NotValidf("charm channel %q", string(c))
+}
+
+// Channel returns the charm store channel tracked by the service,
+// from which upgrades of its charm are resolved.
+func (s *Service) Channel() CharmChannel {
+	if s.doc.Channel == "" {
+		return StableChannel
+	}
+	return s.doc.Channel
+}
+
+// SetChannel sets the charm store channel tracked by the service.
+// Any latest charm revision previously recorded for the service's
+// channel is forgotten.
+func (s *Service) SetChannel(channel CharmChannel) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set charm channel for service %q", s)
+	if err := channel.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if channel == s.Channel() {
+		return nil
+	}
+	update := bson.D{{"$unset", bson.D{{"channel", nil}, {"channelcharmurl", nil}}}}
+	if channel != StableChannel {
+		update = bson.D{
+			{"$set", bson.D{{"channel", channel}}},
+			{"$unset", bson.D{{"channelcharmurl", nil}}},
+		}
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: isAliveDoc,
+		Update: update,
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, errNotAlive)
+	}
+	if channel == StableChannel {
+		channel = ""
+	}
+	s.doc.Channel = channel
+	s.doc.ChannelCharmURL = nil
+	return nil
+}
+
+// LatestChannelCharmURL returns the URL of the latest revision of the
+// service's charm available in the channel it tracks, as last recorded
+// by SetLatestChannelCharmURL. It returns nil if none is recorded.
+// The latest revisions of charms of services tracking the stable
+// channel are recorded as placeholder charms instead.
+func (s *Service) LatestChannelCharmURL() *charm.URL {
+	return s.doc.ChannelCharmURL
+}
+
+// SetLatestChannelCharmURL records the URL of the latest revision of
+// the service's charm available in the channel it tracks.
+func (s *Service) SetLatestChannelCharmURL(curl *charm.URL) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot record latest charm for service %q", s)
+	if curl.Schema != "cs" {
+		return errors.Errorf("expected charm URL with cs schema, got %q", curl)
+	}
+	if curl.Revision < 0 {
+		return errors.Errorf("expected charm URL with revision, got %q", curl)
+	}
+	channel := s.Channel()
+	if channel == StableChannel {
+		return errors.New("service tracks the stable channel")
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: bson.D{{"life", Alive}, {"channel", channel}},
+		Update: bson.D{{"$set", bson.D{{"channelcharmurl", curl}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return onAbort(err, errors.New("service is not alive or has changed channel"))
+	}
+	s.doc.ChannelCharmURL = curl
+	return nil
+}
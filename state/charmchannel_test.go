@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/state"
+)
+
+type CharmChannelSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&CharmChannelSuite{})
+
+func (s *CharmChannelSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *CharmChannelSuite) TestValidate(c *gc.C) {
+	for _, channel := range []state.CharmChannel{
+		state.StableChannel, state.CandidateChannel, state.EdgeChannel,
+	} {
+		c.Check(channel.Validate(), jc.ErrorIsNil)
+	}
+	err := state.CharmChannel("beta").Validate()
+	c.Assert(err, gc.ErrorMatches, `charm channel "beta" not valid`)
+}
+
+func (s *CharmChannelSuite) TestDefaultChannel(c *gc.C) {
+	c.Assert(s.service.Channel(), gc.Equals, state.StableChannel)
+	c.Assert(s.service.LatestChannelCharmURL(), gc.IsNil)
+}
+
+func (s *CharmChannelSuite) TestSetChannel(c *gc.C) {
+	err := s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.Channel(), gc.Equals, state.EdgeChannel)
+
+	service, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.Channel(), gc.Equals, state.EdgeChannel)
+
+	err = service.SetChannel(state.StableChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.Channel(), gc.Equals, state.StableChannel)
+}
+
+func (s *CharmChannelSuite) TestSetChannelInvalid(c *gc.C) {
+	err := s.service.SetChannel("beta")
+	c.Assert(err, gc.ErrorMatches, `cannot set charm channel for service "mysql": charm channel "beta" not valid`)
+}
+
+func (s *CharmChannelSuite) TestSetChannelNotAlive(c *gc.C) {
+	err := s.service.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, gc.ErrorMatches, `cannot set charm channel for service "mysql": .*not found or not alive`)
+}
+
+func (s *CharmChannelSuite) TestLatestChannelCharmURL(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/mysql-7")
+	err := s.service.SetLatestChannelCharmURL(curl)
+	c.Assert(err, gc.ErrorMatches, `cannot record latest charm for service "mysql": service tracks the stable channel`)
+
+	err = s.service.SetChannel(state.CandidateChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetLatestChannelCharmURL(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.LatestChannelCharmURL(), jc.DeepEquals, curl)
+
+	service, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.LatestChannelCharmURL(), jc.DeepEquals, curl)
+
+	// Changing channel forgets the latest charm of the old one.
+	err = service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.LatestChannelCharmURL(), gc.IsNil)
+	err = s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.LatestChannelCharmURL(), gc.IsNil)
+}
+
+func (s *CharmChannelSuite) TestSetLatestChannelCharmURLInvalid(c *gc.C) {
+	err := s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("local:quantal/mysql-7"))
+	c.Assert(err, gc.ErrorMatches, `.*expected charm URL with cs schema, got "local:quantal/mysql-7"`)
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/mysql"))
+	c.Assert(err, gc.ErrorMatches, `.*expected charm URL with revision, got "cs:quantal/mysql"`)
+}
@@ -0,0 +1,146 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/featureflag"
+
+	"github.com/juju/juju/feature"
+)
+
+// CharmIncompatibilityKind identifies the area in which a charm is
+// incompatible with the deployment requested.
+type CharmIncompatibilityKind string
+
+const (
+	// IncompatibleSeries is reported when the charm's series does not
+	// match the series of the service or machine it is destined for.
+	IncompatibleSeries CharmIncompatibilityKind = "series"
+
+	// IncompatibleStorage is reported when the charm's storage cannot
+	// be provided by the environment's storage providers.
+	IncompatibleStorage CharmIncompatibilityKind = "storage"
+
+	// IncompatibleNetwork is reported when the networks requested for
+	// the charm cannot be provided by the environment.
+	IncompatibleNetwork CharmIncompatibilityKind = "network"
+)
+
+// CharmIncompatibility describes one reason a charm cannot be deployed
+// as requested.
+type CharmIncompatibility struct {
+	Kind    CharmIncompatibilityKind
+	Message string
+}
+
+// CharmCompatibilityParams holds the details of a prospective deployment
+// of a charm, checked by CheckCharmCompatibility.
+type CharmCompatibilityParams struct {
+	// Charm is the charm to be deployed.
+	Charm *Charm
+
+	// Service, if not nil, is the service being upgraded to Charm.
+	Service *Service
+
+	// ToMachineSpec is the placement of the first unit, as given to
+	// DeployService.
+	ToMachineSpec string
+
+	// Storage holds the storage constraints requested for the charm's
+	// stores. If Service is set and Storage is nil, the service's own
+	// storage constraints are checked.
+	Storage map[string]StorageConstraints
+}
+
+// CheckCharmCompatibility reports every way in which the charm cannot be
+// deployed, or a service upgraded to it, with the given parameters.
+// Unlike the checks made while the service is changed, it does not stop
+// at the first problem, so that all of them can be reported at once.
+func (st *State) CheckCharmCompatibility(args CharmCompatibilityParams) ([]CharmIncompatibility, error) {
+	var result []CharmIncompatibility
+	add := func(kind CharmIncompatibilityKind, format string, a ...interface{}) {
+		result = append(result, CharmIncompatibility{
+			Kind:    kind,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	series := args.Charm.URL().Series
+	if args.Service != nil && args.Service.doc.Series != series {
+		add(IncompatibleSeries,
+			"service %q has series %q, charm is for series %q",
+			args.Service.Name(), args.Service.doc.Series, series,
+		)
+	}
+	if args.ToMachineSpec != "" && names.IsValidMachine(args.ToMachineSpec) {
+		m, err := st.Machine(args.ToMachineSpec)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if m.Series() != series {
+			add(IncompatibleSeries,
+				"machine %s has series %q, charm is for series %q",
+				m.Id(), m.Series(), series,
+			)
+		}
+	}
+
+	// TODO(axw) stop checking feature flag once storage has graduated.
+	if !featureflag.Enabled(feature.Storage) {
+		return result, nil
+	}
+	allCons := args.Storage
+	if allCons == nil && args.Service != nil {
+		serviceCons, err := args.Service.StorageConstraints()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		allCons = serviceCons
+	}
+	conf, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	charmMeta := args.Charm.Meta()
+	var stores []string
+	for name := range charmMeta.Storage {
+		stores = append(stores, name)
+	}
+	sort.Strings(stores)
+	for _, name := range stores {
+		charmStorage := charmMeta.Storage[name]
+		cons, ok := allCons[name]
+		if !ok && charmStorage.CountMin <= 0 {
+			continue
+		}
+		kind := storageKind(charmStorage.Type)
+		cons, err := storageConstraintsWithDefaults(conf, kind, charmStorage, cons)
+		if errors.Cause(err) == ErrNoDefaultStoragePool {
+			// Filesystems are provided by the rootfs provider when no
+			// pool is specified, which is always available.
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := validateStoragePool(st, cons.Pool, kind); err != nil {
+			add(IncompatibleStorage, "store %q: %v", name, err)
+		}
+	}
+	stores = stores[:0]
+	for name := range allCons {
+		if _, ok := charmMeta.Storage[name]; !ok {
+			stores = append(stores, name)
+		}
+	}
+	sort.Strings(stores)
+	for _, name := range stores {
+		add(IncompatibleStorage, "charm %q has no store called %q", charmMeta.Name, name)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type CharmCompatibilitySuite struct {
+	StorageStateSuiteBase
+}
+
+var _ = gc.Suite(&CharmCompatibilitySuite{})
+
+func (s *CharmCompatibilitySuite) TestCompatible(c *gc.C) {
+	ch := s.AddTestingCharm(c, "storage-block")
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := s.State.CheckCharmCompatibility(state.CharmCompatibilityParams{
+		Charm:         ch,
+		ToMachineSpec: machine.Id(),
+		Storage: map[string]state.StorageConstraints{
+			"data": makeStorageCons("loop-pool", 1024, 1),
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
+
+func (s *CharmCompatibilitySuite) TestMachineSeries(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := s.State.CheckCharmCompatibility(state.CharmCompatibilityParams{
+		Charm:         ch,
+		ToMachineSpec: machine.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, jc.DeepEquals, []state.CharmIncompatibility{{
+		Kind:    state.IncompatibleSeries,
+		Message: `machine 0 has series "precise", charm is for series "quantal"`,
+	}})
+}
+
+func (s *CharmCompatibilitySuite) TestMachineNotFound(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	_, err := s.State.CheckCharmCompatibility(state.CharmCompatibilityParams{
+		Charm:         ch,
+		ToMachineSpec: "42",
+	})
+	c.Assert(err, gc.ErrorMatches, "machine 42 not found")
+}
+
+func (s *CharmCompatibilitySuite) TestStorageReportsAllProblems(c *gc.C) {
+	ch := s.AddTestingCharm(c, "storage-block2")
+	problems, err := s.State.CheckCharmCompatibility(state.CharmCompatibilityParams{
+		Charm: ch,
+		Storage: map[string]state.StorageConstraints{
+			"multi1to10": makeStorageCons("ebs", 1024, 1),
+			"multi2up":   makeStorageCons("loop-pool", 2048, 2),
+			"unknown":    makeStorageCons("loop-pool", 1024, 1),
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, jc.DeepEquals, []state.CharmIncompatibility{{
+		Kind:    state.IncompatibleStorage,
+		Message: `store "multi1to10": pool "ebs" not found`,
+	}, {
+		Kind:    state.IncompatibleStorage,
+		Message: `charm "storage-block2" has no store called "unknown"`,
+	}})
+}
+
+func (s *CharmCompatibilitySuite) TestServiceStorage(c *gc.C) {
+	service, _, _ := s.setupSingleStorage(c, "block")
+	ch, _, err := service.Charm()
+	c.Assert(err, jc.ErrorIsNil)
+	problems, err := s.State.CheckCharmCompatibility(state.CharmCompatibilityParams{
+		Charm:   ch,
+		Service: service,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(problems, gc.HasLen, 0)
+}
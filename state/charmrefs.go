@@ -0,0 +1,317 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// charmRefsDoc holds the number of services and units using the charm
+// identified by the document's id. Like the settings ref counts, every
+// service holds a reference to its current charm, and every unit to the
+// charm it is running. Charms whose count has dropped to zero are
+// stamped with the time they were first seen unused by
+// CollectUnusedCharms, and removed once they have been unused for
+// longer than the environment's charm retention period.
+type charmRefsDoc struct {
+	DocID       string    `bson:"_id"`
+	EnvUUID     string    `bson:"env-uuid"`
+	RefCount    int       `bson:"refcount"`
+	UnusedSince time.Time `bson:"unused-since,omitempty"`
+}
+
+// charmIncRefOps returns the operations that increment the ref count
+// of the charm with the given URL, creating the ref count document if
+// it does not exist. The charm itself must exist.
+func charmIncRefOps(st *State, curl *charm.URL) ([]txn.Op, error) {
+	charmrefs, closer := st.getCollection(charmrefsC)
+	defer closer()
+
+	docID := st.docID(curl.String())
+	charmExistsOp := txn.Op{
+		C:      charmsC,
+		Id:     docID,
+		Assert: txn.DocExists,
+	}
+	if count, err := charmrefs.FindId(curl.String()).Count(); err != nil {
+		return nil, errors.Trace(err)
+	} else if count == 0 {
+		return []txn.Op{charmExistsOp, {
+			C:      charmrefsC,
+			Id:     docID,
+			Assert: txn.DocMissing,
+			Insert: charmRefsDoc{
+				DocID:    docID,
+				EnvUUID:  st.EnvironUUID(),
+				RefCount: 1,
+			},
+		}}, nil
+	}
+	return []txn.Op{charmExistsOp, {
+		C:      charmrefsC,
+		Id:     docID,
+		Assert: txn.DocExists,
+		Update: bson.D{
+			{"$inc", bson.D{{"refcount", 1}}},
+			{"$unset", bson.D{{"unused-since", nil}}},
+		},
+	}}, nil
+}
+
+// charmDecRefOp returns an operation that decrements the ref count of
+// the charm with the given URL. Charms are never removed here; once
+// unused, they are left for CollectUnusedCharms.
+func charmDecRefOp(st *State, curl *charm.URL) txn.Op {
+	return txn.Op{
+		C:      charmrefsC,
+		Id:     st.docID(curl.String()),
+		Update: bson.D{{"$inc", bson.D{{"refcount", -1}}}},
+	}
+}
+
+// CharmUsage describes the storage used by one of an environment's
+// charms.
+type CharmUsage struct {
+	// URL identifies the charm.
+	URL *charm.URL
+
+	// Size is the size of the charm's archive in bytes, or 0 if it
+	// was not recorded when the charm was stored.
+	Size int64
+
+	// RefCount is the number of services and units using the charm.
+	RefCount int
+
+	// UnusedSince is when the charm was first seen unused, or the
+	// zero time if it is in use or has not been collected yet.
+	UnusedSince time.Time
+}
+
+// CharmStorageUsage describes the storage used by the charm archives
+// of an environment.
+type CharmStorageUsage struct {
+	// Size is the total size of the environment's charm archives in
+	// bytes.
+	Size int64
+
+	// Quota is the maximum size of the environment's charm archives
+	// in bytes, or 0 if it is unlimited.
+	Quota int64
+
+	// Charms describes each of the environment's stored charms.
+	Charms []CharmUsage
+}
+
+// CharmStorageUsage returns the storage used by the charm archives
+// stored for the environment.
+func (st *State) CharmStorageUsage() (CharmStorageUsage, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return CharmStorageUsage{}, errors.Trace(err)
+	}
+	docs, err := st.storedCharmDocs()
+	if err != nil {
+		return CharmStorageUsage{}, errors.Trace(err)
+	}
+	refs, err := st.charmRefs()
+	if err != nil {
+		return CharmStorageUsage{}, errors.Trace(err)
+	}
+	usage := CharmStorageUsage{
+		Quota:  int64(cfg.CharmStorageQuota()) * megabyte,
+		Charms: make([]CharmUsage, len(docs)),
+	}
+	for i, doc := range docs {
+		ref := refs[doc.DocID]
+		usage.Size += doc.BundleSize
+		usage.Charms[i] = CharmUsage{
+			URL:         doc.URL,
+			Size:        doc.BundleSize,
+			RefCount:    ref.RefCount,
+			UnusedSince: ref.UnusedSince,
+		}
+	}
+	return usage, nil
+}
+
+// megabyte is the unit of the charm storage quota.
+const megabyte = 1024 * 1024
+
+// CharmStorageQuotaExceededError is returned when storing a charm
+// would take the environment's charm storage over its quota.
+type CharmStorageQuotaExceededError struct {
+	Usage int64
+	Quota int64
+	Size  int64
+}
+
+func (e *CharmStorageQuotaExceededError) Error() string {
+	return fmt.Sprintf(
+		"charm storage quota exceeded: %d bytes used of %d, cannot store %d more",
+		e.Usage, e.Quota, e.Size,
+	)
+}
+
+// IsCharmStorageQuotaExceededError returns whether err is a
+// CharmStorageQuotaExceededError.
+func IsCharmStorageQuotaExceededError(err error) bool {
+	_, ok := errors.Cause(err).(*CharmStorageQuotaExceededError)
+	return ok
+}
+
+// CheckCharmStorageQuota returns a CharmStorageQuotaExceededError if
+// storing a charm archive of the given size would take the
+// environment's charm storage over its quota. The check is advisory:
+// concurrent uploads may each pass it.
+func (st *State) CheckCharmStorageQuota(size int64) error {
+	usage, err := st.CharmStorageUsage()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if usage.Quota > 0 && usage.Size+size > usage.Quota {
+		return &CharmStorageQuotaExceededError{
+			Usage: usage.Size,
+			Quota: usage.Quota,
+			Size:  size,
+		}
+	}
+	return nil
+}
+
+// CollectUnusedCharms removes the environment's charms which have not
+// been used by any service or unit for longer than the environment's
+// charm retention period, and returns the storage paths of their
+// archives, which the caller is responsible for removing. Charms seen
+// unused for the first time are stamped with the current time.
+func (st *State) CollectUnusedCharms() ([]string, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	retention, err := cfg.CharmRetention()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	docs, err := st.storedCharmDocs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	refs, err := st.charmRefs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	now := time.Now().UTC()
+	cutoff := now.Add(-retention)
+	var storagePaths []string
+	for _, doc := range docs {
+		ref, ok := refs[doc.DocID]
+		if ok && ref.RefCount > 0 {
+			continue
+		}
+		if ref.UnusedSince.IsZero() {
+			if err := st.markCharmUnused(doc.DocID, ok, now); err == txn.ErrAborted {
+				// The charm has been used since we looked.
+				continue
+			} else if err != nil {
+				return nil, errors.Annotatef(err, "cannot mark charm %q unused", doc.URL)
+			}
+			ref.UnusedSince = now
+		}
+		if ref.UnusedSince.After(cutoff) {
+			continue
+		}
+		ops := []txn.Op{{
+			C:  charmrefsC,
+			Id: doc.DocID,
+			Assert: bson.D{
+				{"refcount", bson.D{{"$lte", 0}}},
+				{"unused-since", bson.D{{"$lte", cutoff}}},
+			},
+			Remove: true,
+		}, {
+			C:      charmsC,
+			Id:     doc.DocID,
+			Assert: txn.DocExists,
+			Remove: true,
+		}}
+		if err := st.runTransaction(ops); err == txn.ErrAborted {
+			continue
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "cannot remove charm %q", doc.URL)
+		}
+		logger.Infof("removed charm %q, unused since %v", doc.URL, ref.UnusedSince)
+		storagePaths = append(storagePaths, doc.StoragePath)
+	}
+	return storagePaths, nil
+}
+
+// markCharmUnused records that the charm with the given document id
+// has been unused since the given time, creating its ref count
+// document if it has none.
+func (st *State) markCharmUnused(docID string, hasRefs bool, since time.Time) error {
+	if !hasRefs {
+		return st.runTransaction([]txn.Op{{
+			C:      charmrefsC,
+			Id:     docID,
+			Assert: txn.DocMissing,
+			Insert: charmRefsDoc{
+				DocID:       docID,
+				EnvUUID:     st.EnvironUUID(),
+				UnusedSince: since,
+			},
+		}})
+	}
+	return st.runTransaction([]txn.Op{{
+		C:  charmrefsC,
+		Id: docID,
+		Assert: bson.D{
+			{"refcount", bson.D{{"$lte", 0}}},
+			{"unused-since", bson.D{{"$exists", false}}},
+		},
+		Update: bson.D{{"$set", bson.D{{"unused-since", since}}}},
+	}})
+}
+
+// storedCharmDocs returns the documents of the environment's charms
+// whose archives are stored.
+func (st *State) storedCharmDocs() ([]charmDoc, error) {
+	charms, closer := st.getCollection(charmsC)
+	defer closer()
+
+	var docs []charmDoc
+	query := bson.D{
+		{"placeholder", bson.D{{"$ne", true}}},
+		{"pendingupload", bson.D{{"$ne", true}}},
+	}
+	fields := bson.D{{"_id", 1}, {"url", 1}, {"storagepath", 1}, {"bundlesize", 1}}
+	if err := charms.Find(query).Select(fields).All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return docs, nil
+}
+
+// charmRefs returns the environment's charm ref count documents, keyed
+// on document id.
+func (st *State) charmRefs() (map[string]charmRefsDoc, error) {
+	charmrefs, closer := st.getCollection(charmrefsC)
+	defer closer()
+
+	refs := make(map[string]charmRefsDoc)
+	var doc charmRefsDoc
+	iter := charmrefs.Find(nil).Iter()
+	for iter.Next(&doc) {
+		refs[doc.DocID] = doc
+		doc = charmRefsDoc{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return refs, nil
+}
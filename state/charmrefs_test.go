@@ -0,0 +1,191 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"fmt"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testcharms"
+)
+
+type CharmRefsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&CharmRefsSuite{})
+
+// addUploadedCharm adds the named testing charm as if it had been
+// uploaded with an archive of the given size.
+func (s *CharmRefsSuite) addUploadedCharm(c *gc.C, name string, revision int, size int64) *state.Charm {
+	ch := testcharms.Repo.CharmDir(name)
+	curl, err := s.State.PrepareLocalCharmUpload(
+		charm.MustParseURL(fmt.Sprintf("local:quantal/%s-%d", name, revision)),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	storagePath := fmt.Sprintf("charms/%s-%d", name, revision)
+	sch, err := s.State.UpdateUploadedCharm(ch, curl, storagePath, "sha256", size)
+	c.Assert(err, jc.ErrorIsNil)
+	return sch
+}
+
+func (s *CharmRefsSuite) setConfig(c *gc.C, attrs map[string]interface{}) {
+	err := s.State.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CharmRefsSuite) refCounts(c *gc.C) map[string]int {
+	usage, err := s.State.CharmStorageUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	refCounts := make(map[string]int)
+	for _, ch := range usage.Charms {
+		refCounts[ch.URL.String()] = ch.RefCount
+	}
+	return refCounts
+}
+
+func (s *CharmRefsSuite) TestCharmStorageUsage(c *gc.C) {
+	s.addUploadedCharm(c, "wordpress", 1, 1000)
+	s.addUploadedCharm(c, "mysql", 1, 234)
+	_, err := s.State.PrepareLocalCharmUpload(charm.MustParseURL("local:quantal/dummy-1"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.setConfig(c, map[string]interface{}{"charm-storage-quota": 2})
+
+	usage, err := s.State.CharmStorageUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage.Size, gc.Equals, int64(1234))
+	c.Assert(usage.Quota, gc.Equals, int64(2*1024*1024))
+	c.Assert(usage.Charms, gc.HasLen, 2)
+}
+
+func (s *CharmRefsSuite) TestRefCounts(c *gc.C) {
+	oldCharm := s.addUploadedCharm(c, "wordpress", 1, 1000)
+	newCharm := s.addUploadedCharm(c, "wordpress", 2, 1000)
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-1": 0,
+		"local:quantal/wordpress-2": 0,
+	})
+
+	svc := s.AddTestingService(c, "wordpress", oldCharm)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(oldCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-1": 2,
+		"local:quantal/wordpress-2": 0,
+	})
+
+	err = svc.SetCharm(newCharm, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-1": 1,
+		"local:quantal/wordpress-2": 1,
+	})
+
+	err = unit.SetCharmURL(newCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-1": 0,
+		"local:quantal/wordpress-2": 2,
+	})
+
+	err = unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	err = svc.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-1": 0,
+		"local:quantal/wordpress-2": 0,
+	})
+}
+
+func (s *CharmRefsSuite) TestCollectUnusedCharmsRetention(c *gc.C) {
+	s.addUploadedCharm(c, "wordpress", 1, 1000)
+
+	// The charm is stamped when first seen unused, but retained.
+	paths, err := s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, gc.HasLen, 0)
+	usage, err := s.State.CharmStorageUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage.Charms, gc.HasLen, 1)
+	c.Assert(usage.Charms[0].UnusedSince.IsZero(), jc.IsFalse)
+
+	// Once the retention period has passed, it is removed.
+	s.setConfig(c, map[string]interface{}{"charm-retention": "0s"})
+	paths, err = s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, jc.DeepEquals, []string{"charms/wordpress-1"})
+	_, err = s.State.Charm(charm.MustParseURL("local:quantal/wordpress-1"))
+	c.Assert(err, gc.ErrorMatches, `charm "local:quantal/wordpress-1" not found`)
+}
+
+func (s *CharmRefsSuite) TestCollectUnusedCharmsKeepsUsedCharms(c *gc.C) {
+	s.setConfig(c, map[string]interface{}{"charm-retention": "0s"})
+	oldCharm := s.addUploadedCharm(c, "wordpress", 1, 1000)
+	newCharm := s.addUploadedCharm(c, "wordpress", 2, 1000)
+	svc := s.AddTestingService(c, "wordpress", oldCharm)
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetCharmURL(oldCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	err = svc.SetCharm(newCharm, false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The old charm is still used by the unit.
+	paths, err := s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, gc.HasLen, 0)
+
+	err = unit.SetCharmURL(newCharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	paths, err = s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, jc.DeepEquals, []string{"charms/wordpress-1"})
+	c.Assert(s.refCounts(c), jc.DeepEquals, map[string]int{
+		"local:quantal/wordpress-2": 2,
+	})
+}
+
+func (s *CharmRefsSuite) TestCollectedCharmUsedAgain(c *gc.C) {
+	ch := s.addUploadedCharm(c, "wordpress", 1, 1000)
+	paths, err := s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, gc.HasLen, 0)
+
+	// Using the charm clears its unused stamp.
+	s.AddTestingService(c, "wordpress", ch)
+	usage, err := s.State.CharmStorageUsage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(usage.Charms, gc.HasLen, 1)
+	c.Assert(usage.Charms[0].RefCount, gc.Equals, 1)
+	c.Assert(usage.Charms[0].UnusedSince.IsZero(), jc.IsTrue)
+
+	s.setConfig(c, map[string]interface{}{"charm-retention": "0s"})
+	paths, err = s.State.CollectUnusedCharms()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, gc.HasLen, 0)
+}
+
+func (s *CharmRefsSuite) TestCheckCharmStorageQuota(c *gc.C) {
+	s.addUploadedCharm(c, "wordpress", 1, 1024*1024)
+
+	// There is no quota by default.
+	err := s.State.CheckCharmStorageQuota(10 * 1024 * 1024)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.setConfig(c, map[string]interface{}{"charm-storage-quota": 2})
+	err = s.State.CheckCharmStorageQuota(1024 * 1024)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.CheckCharmStorageQuota(1024*1024 + 1)
+	c.Assert(err, jc.Satisfies, state.IsCharmStorageQuotaExceededError)
+	c.Assert(err, gc.ErrorMatches, "charm storage quota exceeded: 1048576 bytes used of 2097152, cannot store 1048577 more")
+}
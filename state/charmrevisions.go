@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+)
+
+// ServiceCharmRevision describes the revision of a service's charm
+// and the latest revision available to it in the charm store.
+type ServiceCharmRevision struct {
+	// Service is the name of the service.
+	Service string
+
+	// Channel is the charm store channel the service tracks.
+	Channel CharmChannel
+
+	// CharmURL is the URL of the service's charm.
+	CharmURL *charm.URL
+
+	// LatestCharmURL is the URL of the latest revision of the
+	// service's charm available in the channel it tracks, if that
+	// is newer than CharmURL; otherwise it is nil.
+	LatestCharmURL *charm.URL
+}
+
+// LatestCharmURL returns the URL of the latest revision of the
+// service's charm known to be available in the charm store channel the
+// service tracks, as last recorded by the charm revision updater. It
+// returns nil if no newer revision than the service's charm is known.
+func (s *Service) LatestCharmURL() (*charm.URL, error) {
+	curl := s.doc.CharmURL
+	if curl.Schema != "cs" {
+		return nil, nil
+	}
+	baseURL := curl.WithRevision(-1)
+	var latest *charm.URL
+	if s.Channel() == StableChannel {
+		ch, err := s.st.LatestPlaceholderCharm(baseURL)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		latest = ch.URL()
+	} else {
+		latest = s.LatestChannelCharmURL()
+		if latest == nil || *latest.WithRevision(-1) != *baseURL {
+			return nil, nil
+		}
+	}
+	if latest.Revision <= curl.Revision {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// ServiceCharmRevisions returns the revisions of the charms of all
+// the services in the environment, and the latest revisions available
+// to them, ordered by service name.
+func (st *State) ServiceCharmRevisions() ([]ServiceCharmRevision, error) {
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	revisions := make([]ServiceCharmRevision, len(services))
+	for i, service := range services {
+		latest, err := service.LatestCharmURL()
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot get latest charm of service %q", service)
+		}
+		curl, _ := service.CharmURL()
+		revisions[i] = ServiceCharmRevision{
+			Service:        service.Name(),
+			Channel:        service.Channel(),
+			CharmURL:       curl,
+			LatestCharmURL: latest,
+		}
+	}
+	sort.Sort(serviceCharmRevisionsByName(revisions))
+	return revisions, nil
+}
+
+type serviceCharmRevisionsByName []ServiceCharmRevision
+
+func (r serviceCharmRevisionsByName) Len() int           { return len(r) }
+func (r serviceCharmRevisionsByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r serviceCharmRevisionsByName) Less(i, j int) bool { return r[i].Service < r[j].Service }
@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type CharmRevisionsSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&CharmRevisionsSuite{})
+
+func (s *CharmRevisionsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	ch := s.factory.MakeCharm(c, &factory.CharmParams{Name: "mysql", URL: "cs:quantal/mysql-3"})
+	s.service = s.factory.MakeService(c, &factory.ServiceParams{Name: "mysql", Charm: ch})
+}
+
+func (s *CharmRevisionsSuite) assertLatestCharmURL(c *gc.C, expect string) {
+	err := s.service.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	latest, err := s.service.LatestCharmURL()
+	c.Assert(err, jc.ErrorIsNil)
+	if expect == "" {
+		c.Assert(latest, gc.IsNil)
+	} else {
+		c.Assert(latest, jc.DeepEquals, charm.MustParseURL(expect))
+	}
+}
+
+func (s *CharmRevisionsSuite) TestLatestCharmURLStable(c *gc.C) {
+	s.assertLatestCharmURL(c, "")
+
+	err := s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertLatestCharmURL(c, "cs:quantal/mysql-5")
+}
+
+func (s *CharmRevisionsSuite) TestLatestCharmURLChannel(c *gc.C) {
+	err := s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Placeholders record the latest revisions of the stable channel.
+	s.assertLatestCharmURL(c, "")
+
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/mysql-8"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertLatestCharmURL(c, "cs:quantal/mysql-8")
+}
+
+func (s *CharmRevisionsSuite) TestLatestCharmURLNotNewer(c *gc.C) {
+	err := s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/mysql-3"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertLatestCharmURL(c, "")
+
+	// The latest revision of a different charm is ignored.
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/mariadb-9"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertLatestCharmURL(c, "")
+}
+
+func (s *CharmRevisionsSuite) TestServiceCharmRevisions(c *gc.C) {
+	ch := s.factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress", URL: "cs:quantal/wordpress-1"})
+	wordpress := s.factory.MakeService(c, &factory.ServiceParams{Name: "wordpress", Charm: ch})
+	err := wordpress.SetChannel(state.CandidateChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/wordpress-2"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.factory.MakeService(c, &factory.ServiceParams{Name: "apache"})
+
+	revisions, err := s.State.ServiceCharmRevisions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(revisions, gc.HasLen, 3)
+	c.Assert(revisions[0].Service, gc.Equals, "apache")
+	c.Assert(revisions[0].Channel, gc.Equals, state.StableChannel)
+	c.Assert(revisions[0].LatestCharmURL, gc.IsNil)
+	c.Assert(revisions[1], jc.DeepEquals, state.ServiceCharmRevision{
+		Service:  "mysql",
+		Channel:  state.StableChannel,
+		CharmURL: charm.MustParseURL("cs:quantal/mysql-3"),
+	})
+	c.Assert(revisions[2], jc.DeepEquals, state.ServiceCharmRevision{
+		Service:        "wordpress",
+		Channel:        state.CandidateChannel,
+		CharmURL:       charm.MustParseURL("cs:quantal/wordpress-1"),
+		LatestCharmURL: charm.MustParseURL("cs:quantal/wordpress-2"),
+	})
+}
+
+func (s *CharmRevisionsSuite) TestWatchCharmRevisions(c *gc.C) {
+	w := s.State.WatchCharmRevisions()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.State.AddStoreCharmPlaceholder(charm.MustParseURL("cs:quantal/mysql-5"))
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// Changes to services not affecting their charm revisions do
+	// not cause a change.
+	_, err = s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	err = s.service.SetChannel(state.EdgeChannel)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.service.SetLatestChannelCharmURL(charm.MustParseURL("cs:quantal/mysql-8"))
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	s.factory.MakeService(c, &factory.ServiceParams{Name: "wordpress"})
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
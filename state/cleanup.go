@@ -24,6 +24,7 @@ const (
 	cleanupServicesForDyingEnvironment cleanupKind = "services"
 	cleanupForceDestroyedMachine       cleanupKind = "machine"
 	cleanupAttachmentsForDyingStorage  cleanupKind = "storageAttachments"
+	cleanupSecretsForRemovedService    cleanupKind = "serviceSecrets"
 )
 
 // cleanupDoc represents a potentially large set of documents that should be
@@ -88,10 +89,15 @@ func (st *State) Cleanup() error {
 			err = st.cleanupForceDestroyedMachine(doc.Prefix)
 		case cleanupAttachmentsForDyingStorage:
 			err = st.cleanupAttachmentsForDyingStorage(doc.Prefix)
+		case cleanupSecretsForRemovedService:
+			err = st.cleanupSecretsForRemovedService(doc.Prefix)
 		default:
 			err = fmt.Errorf("unknown cleanup kind %q", doc.Kind)
 		}
-		if err != nil {
+		if isDrainPending(err) {
+			logger.Infof("cleanup deferred: %v", err)
+			continue
+		} else if err != nil {
 			logger.Warningf("cleanup failed: %v", err)
 			continue
 		}
@@ -243,6 +249,11 @@ func (st *State) cleanupForceDestroyedMachine(machineId string) error {
 	if err := st.cleanupContainers(machine); err != nil {
 		return err
 	}
+	// Give the agents of the machine's units a chance to leave their
+	// relations and stop cleanly before they are obliterated.
+	if err := st.drainUnits(machine); err != nil {
+		return err
+	}
 	for _, unitName := range machine.doc.Principals {
 		if err := st.obliterateUnit(unitName); err != nil {
 			return err
@@ -0,0 +1,278 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"regexp"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+var validCloudName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type cloudDoc struct {
+	Name   string                 `bson:"_id"`
+	Type   string                 `bson:"type"`
+	Config map[string]interface{} `bson:"config,omitempty"`
+}
+
+// Cloud represents a cloud on which the state server may host
+// environments, other than the one it was bootstrapped on.
+type Cloud struct {
+	doc cloudDoc
+}
+
+// Name returns the name of the cloud.
+func (c *Cloud) Name() string {
+	return c.doc.Name
+}
+
+// Type returns the provider type of the cloud.
+func (c *Cloud) Type() string {
+	return c.doc.Type
+}
+
+// Config returns the provider-specific config attributes, such as the
+// region or endpoint, shared by all environments on the cloud.
+func (c *Cloud) Config() map[string]interface{} {
+	config := make(map[string]interface{})
+	for key, value := range c.doc.Config {
+		config[unescapeReplacer.Replace(key)] = value
+	}
+	return config
+}
+
+// AddCloud adds a cloud of the given provider type on which the state
+// server may host environments. The given config attributes are shared
+// by all environments on the cloud.
+func (st *State) AddCloud(name, providerType string, config map[string]interface{}) (*Cloud, error) {
+	if !validCloudName.MatchString(name) {
+		return nil, errors.NotValidf("cloud name %q", name)
+	}
+	if providerType == "" {
+		return nil, errors.NotValidf("empty provider type for cloud %q", name)
+	}
+	doc := cloudDoc{
+		Name: name,
+		Type: providerType,
+	}
+	if len(config) > 0 {
+		doc.Config = make(map[string]interface{})
+		for key, value := range config {
+			doc.Config[escapeReplacer.Replace(key)] = value
+		}
+	}
+	ops := []txn.Op{{
+		C:      cloudsC,
+		Id:     name,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errors.AlreadyExistsf("cloud %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot add cloud %q", name)
+	}
+	return &Cloud{doc}, nil
+}
+
+// Cloud returns the cloud with the given name.
+func (st *State) Cloud(name string) (*Cloud, error) {
+	clouds, closer := st.getCollection(cloudsC)
+	defer closer()
+
+	var doc cloudDoc
+	err := clouds.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("cloud %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get cloud %q", name)
+	}
+	return &Cloud{doc}, nil
+}
+
+// AllClouds returns all the clouds added to the state server.
+func (st *State) AllClouds() ([]*Cloud, error) {
+	clouds, closer := st.getCollection(cloudsC)
+	defer closer()
+
+	var docs []cloudDoc
+	if err := clouds.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get clouds")
+	}
+	result := make([]*Cloud, len(docs))
+	for i, doc := range docs {
+		result[i] = &Cloud{doc}
+	}
+	return result, nil
+}
+
+type cloudCredentialDoc struct {
+	DocID      string            `bson:"_id"`
+	Owner      string            `bson:"owner"`
+	Cloud      string            `bson:"cloud"`
+	Name       string            `bson:"name"`
+	Attributes map[string]string `bson:"attributes"`
+}
+
+// cloudCredentialDocID returns the document id of the named credential
+// held by the owner for the cloud.
+func cloudCredentialDocID(owner names.UserTag, cloud, name string) string {
+	return owner.Username() + "#" + cloud + "#" + name
+}
+
+// CloudCredential represents a credential a user holds for a cloud,
+// which environments they own on the cloud are created with.
+type CloudCredential struct {
+	doc cloudCredentialDoc
+}
+
+// Owner returns the user holding the credential.
+func (c *CloudCredential) Owner() names.UserTag {
+	return names.NewUserTag(c.doc.Owner)
+}
+
+// Cloud returns the name of the cloud the credential is for.
+func (c *CloudCredential) Cloud() string {
+	return c.doc.Cloud
+}
+
+// Name returns the name of the credential.
+func (c *CloudCredential) Name() string {
+	return c.doc.Name
+}
+
+// Attributes returns the provider-specific credential attributes,
+// such as access and secret keys.
+func (c *CloudCredential) Attributes() map[string]string {
+	attrs := make(map[string]string)
+	for key, value := range c.doc.Attributes {
+		attrs[unescapeReplacer.Replace(key)] = value
+	}
+	return attrs
+}
+
+// UpdateCloudCredential adds or replaces the named credential held by
+// the owner for the cloud. The new attributes are written to the config
+// of every environment created with the credential, so that workers
+// watching it pick them up without being restarted.
+func (st *State) UpdateCloudCredential(owner names.UserTag, cloud, name string, attrs map[string]string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot update credential %q for cloud %q", name, cloud)
+	if !validCloudName.MatchString(name) {
+		return errors.NotValidf("credential name %q", name)
+	}
+	if _, err := st.Cloud(cloud); err != nil {
+		return errors.Trace(err)
+	}
+	escaped := make(map[string]string)
+	for key, value := range attrs {
+		escaped[escapeReplacer.Replace(key)] = value
+	}
+	docID := cloudCredentialDocID(owner, cloud, name)
+	cloudOp := txn.Op{
+		C:      cloudsC,
+		Id:     cloud,
+		Assert: txn.DocExists,
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		_, err := st.CloudCredential(owner, cloud, name)
+		if errors.IsNotFound(err) {
+			return []txn.Op{cloudOp, {
+				C:      cloudCredentialsC,
+				Id:     docID,
+				Assert: txn.DocMissing,
+				Insert: &cloudCredentialDoc{
+					DocID:      docID,
+					Owner:      owner.Username(),
+					Cloud:      cloud,
+					Name:       name,
+					Attributes: escaped,
+				},
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{cloudOp, {
+			C:      cloudCredentialsC,
+			Id:     docID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"attributes", escaped}}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return st.propagateCloudCredential(owner, cloud, name, attrs)
+}
+
+// propagateCloudCredential updates the config of the environments
+// created with the named credential with its attributes.
+func (st *State) propagateCloudCredential(owner names.UserTag, cloud, name string, attrs map[string]string) error {
+	environments, closer := st.getCollection(environmentsC)
+	defer closer()
+
+	var docs []environmentDoc
+	query := environments.Find(bson.D{
+		{"owner", owner.Username()},
+		{"cloud", cloud},
+		{"cloud-credential", name},
+	})
+	if err := query.All(&docs); err != nil {
+		return errors.Annotate(err, "cannot get environments using credential")
+	}
+	update := make(map[string]interface{})
+	for key, value := range attrs {
+		update[key] = value
+	}
+	for _, doc := range docs {
+		envSt, err := st.ForEnviron(names.NewEnvironTag(doc.UUID))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = envSt.UpdateEnvironConfig(update, nil, nil)
+		envSt.Close()
+		if err != nil {
+			return errors.Annotatef(err, "cannot update config of environment %q", doc.Name)
+		}
+	}
+	return nil
+}
+
+// CloudCredential returns the named credential held by the owner for
+// the cloud.
+func (st *State) CloudCredential(owner names.UserTag, cloud, name string) (*CloudCredential, error) {
+	credentials, closer := st.getCollection(cloudCredentialsC)
+	defer closer()
+
+	var doc cloudCredentialDoc
+	err := credentials.FindId(cloudCredentialDocID(owner, cloud, name)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("credential %q for cloud %q", name, cloud)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get credential %q for cloud %q", name, cloud)
+	}
+	return &CloudCredential{doc}, nil
+}
+
+// CloudCredentials returns the credentials held by the owner for the
+// cloud.
+func (st *State) CloudCredentials(owner names.UserTag, cloud string) ([]*CloudCredential, error) {
+	credentials, closer := st.getCollection(cloudCredentialsC)
+	defer closer()
+
+	var docs []cloudCredentialDoc
+	query := credentials.Find(bson.D{{"owner", owner.Username()}, {"cloud", cloud}})
+	if err := query.Sort("name").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get credentials for cloud %q", cloud)
+	}
+	result := make([]*CloudCredential, len(docs))
+	for i, doc := range docs {
+		result[i] = &CloudCredential{doc}
+	}
+	return result, nil
+}
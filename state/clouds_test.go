@@ -0,0 +1,128 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type CloudsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&CloudsSuite{})
+
+func (s *CloudsSuite) TestAddCloud(c *gc.C) {
+	cloud, err := s.State.AddCloud("aws-west", "ec2", map[string]interface{}{
+		"region":   "us-west-1",
+		"some.key": "value",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cloud.Name(), gc.Equals, "aws-west")
+	c.Assert(cloud.Type(), gc.Equals, "ec2")
+
+	cloud, err = s.State.Cloud("aws-west")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cloud.Type(), gc.Equals, "ec2")
+	c.Assert(cloud.Config(), jc.DeepEquals, map[string]interface{}{
+		"region":   "us-west-1",
+		"some.key": "value",
+	})
+
+	_, err = s.State.AddCloud("aws-west", "ec2", nil)
+	c.Assert(err, gc.ErrorMatches, `cloud "aws-west" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *CloudsSuite) TestAddCloudInvalid(c *gc.C) {
+	_, err := s.State.AddCloud("Not Valid", "ec2", nil)
+	c.Assert(err, gc.ErrorMatches, `cloud name "Not Valid" not valid`)
+	_, err = s.State.AddCloud("aws", "", nil)
+	c.Assert(err, gc.ErrorMatches, `empty provider type for cloud "aws" not valid`)
+}
+
+func (s *CloudsSuite) TestCloudNotFound(c *gc.C) {
+	_, err := s.State.Cloud("nowhere")
+	c.Assert(err, gc.ErrorMatches, `cloud "nowhere" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *CloudsSuite) TestAllClouds(c *gc.C) {
+	clouds, err := s.State.AllClouds()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(clouds, gc.HasLen, 0)
+
+	_, err = s.State.AddCloud("openstack", "openstack", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	clouds, err = s.State.AllClouds()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(clouds, gc.HasLen, 2)
+	c.Assert(clouds[0].Name(), gc.Equals, "aws")
+	c.Assert(clouds[1].Name(), gc.Equals, "openstack")
+}
+
+func (s *CloudsSuite) TestUpdateCloudCredential(c *gc.C) {
+	owner := names.NewUserTag("bob@remote")
+	_, err := s.State.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.UpdateCloudCredential(owner, "aws", "default", map[string]string{
+		"access-key": "key",
+		"secret-key": "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	credential, err := s.State.CloudCredential(owner, "aws", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credential.Owner(), gc.Equals, owner)
+	c.Assert(credential.Cloud(), gc.Equals, "aws")
+	c.Assert(credential.Name(), gc.Equals, "default")
+	c.Assert(credential.Attributes(), jc.DeepEquals, map[string]string{
+		"access-key": "key",
+		"secret-key": "secret",
+	})
+
+	// Updating replaces the attributes.
+	err = s.State.UpdateCloudCredential(owner, "aws", "default", map[string]string{
+		"access-key": "other-key",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	credential, err = s.State.CloudCredential(owner, "aws", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credential.Attributes(), jc.DeepEquals, map[string]string{
+		"access-key": "other-key",
+	})
+
+	// Credentials are held per user.
+	_, err = s.State.CloudCredential(names.NewUserTag("mary@remote"), "aws", "default")
+	c.Assert(err, gc.ErrorMatches, `credential "default" for cloud "aws" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *CloudsSuite) TestUpdateCloudCredentialUnknownCloud(c *gc.C) {
+	err := s.State.UpdateCloudCredential(names.NewUserTag("bob@remote"), "aws", "default", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot update credential "default" for cloud "aws": cloud "aws" not found`)
+}
+
+func (s *CloudsSuite) TestCloudCredentials(c *gc.C) {
+	owner := names.NewUserTag("bob@remote")
+	_, err := s.State.AddCloud("aws", "ec2", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, name := range []string{"work", "home"} {
+		err = s.State.UpdateCloudCredential(owner, "aws", name, map[string]string{"access-key": name})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err = s.State.UpdateCloudCredential(names.NewUserTag("mary@remote"), "aws", "mine", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	credentials, err := s.State.CloudCredentials(owner, "aws")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(credentials, gc.HasLen, 2)
+	c.Assert(credentials[0].Name(), gc.Equals, "home")
+	c.Assert(credentials[1].Name(), gc.Equals, "work")
+}
@@ -60,39 +60,60 @@ type stateCollection interface {
 // these collections.
 var multiEnvCollections = set.NewStrings(
 	actionNotificationsC,
+	actionOutputC,
+	actionSchedulesC,
 	actionsC,
 	annotationsC,
 	blockDevicesC,
 	blocksC,
+	charmrefsC,
+	maintenanceC,
 	charmsC,
 	cleanupsC,
 	constraintsC,
+	containerAddressPoolsC,
+	containerAddressesC,
 	containerRefsC,
+	dnsRecordsC,
 	envUsersC,
+	evacuationsC,
 	filesystemsC,
 	filesystemAttachmentsC,
+	hookQueuesC,
+	hookResultsC,
 	instanceDataC,
+	instanceMetadataC,
 	ipaddressesC,
 	machinesC,
+	manualHostsC,
 	meterStatusC,
 	minUnitsC,
 	networkInterfacesC,
 	networksC,
 	openedPortsC,
+	osUpdatesC,
+	patchRunsC,
 	rebootC,
 	relationScopesC,
 	relationsC,
 	requestedNetworksC,
+	secretKeysC,
+	secretsC,
 	sequenceC,
 	servicesC,
 	settingsC,
 	settingsrefsC,
+	sshKeysC,
 	statusesC,
+	statusesHistoryC,
 	storageAttachmentsC,
 	storageConstraintsC,
 	storageInstancesC,
 	subnetsC,
+	unitDrainsC,
+	unitRelationStatesC,
 	unitsC,
+	upgradeSeriesC,
 	volumesC,
 	volumeAttachmentsC,
 )
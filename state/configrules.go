@@ -0,0 +1,258 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/yaml.v1"
+)
+
+// ConfigRule holds the validation a charm declares for one of its
+// config options, in addition to the option's type. Values set for
+// the option must be one of Enum, if given; must lie between Minimum
+// and Maximum, if given, for numeric options; and must match Pattern
+// in full, if given, for string options.
+type ConfigRule struct {
+	Enum    []interface{} `bson:"enum,omitempty" yaml:"enum,omitempty"`
+	Minimum *float64      `bson:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum *float64      `bson:"maximum,omitempty" yaml:"maximum,omitempty"`
+	Pattern string        `bson:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// ReadConfigRules reads the validation rules declared alongside the
+// options in a charm's config.yaml, and checks them against the
+// charm's config. Options without rules are omitted from the result.
+func ReadConfigRules(r io.Reader, config *charm.Config) (map[string]ConfigRule, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var raw struct {
+		Options map[string]ConfigRule
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Annotate(err, "cannot parse config rules")
+	}
+	rules := make(map[string]ConfigRule)
+	for name, rule := range raw.Options {
+		if rule.isEmpty() {
+			continue
+		}
+		option, ok := config.Options[name]
+		if !ok {
+			return nil, errors.Errorf("config rules for unknown option %q", name)
+		}
+		if err := rule.check(option.Type); err != nil {
+			return nil, errors.Annotatef(err, "invalid config rules for option %q", name)
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+func (r ConfigRule) isEmpty() bool {
+	return len(r.Enum) == 0 && r.Minimum == nil && r.Maximum == nil && r.Pattern == ""
+}
+
+// check returns an error if the rule cannot apply to an option of the
+// given type.
+func (r ConfigRule) check(optionType string) error {
+	numeric := optionType == "int" || optionType == "float"
+	if (r.Minimum != nil || r.Maximum != nil) && !numeric {
+		return errors.Errorf("range given for %s option", optionType)
+	}
+	if r.Minimum != nil && r.Maximum != nil && *r.Minimum > *r.Maximum {
+		return errors.Errorf("minimum %v exceeds maximum %v", *r.Minimum, *r.Maximum)
+	}
+	if r.Pattern != "" {
+		if optionType != "string" {
+			return errors.Errorf("pattern given for %s option", optionType)
+		}
+		if _, err := r.regexp(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// regexp returns the rule's pattern, anchored to match values in full.
+func (r ConfigRule) regexp() (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + r.Pattern + ")$")
+}
+
+// validate returns a description of why value breaks the rule, or the
+// empty string if it does not.
+func (r ConfigRule) validate(value interface{}) string {
+	if len(r.Enum) > 0 {
+		found := false
+		for _, allowed := range r.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("must be one of %v", r.Enum)
+		}
+	}
+	var number float64
+	switch value := value.(type) {
+	case int64:
+		number = float64(value)
+	case float64:
+		number = value
+	case string:
+		if r.Pattern == "" {
+			return ""
+		}
+		re, err := r.regexp()
+		if err != nil || !re.MatchString(value) {
+			return fmt.Sprintf("must match %q", r.Pattern)
+		}
+		return ""
+	default:
+		return ""
+	}
+	if r.Minimum != nil && number < *r.Minimum {
+		return fmt.Sprintf("must be at least %v", *r.Minimum)
+	}
+	if r.Maximum != nil && number > *r.Maximum {
+		return fmt.Sprintf("must be at most %v", *r.Maximum)
+	}
+	return ""
+}
+
+// ConfigValidationError is returned when service config settings break
+// the validation rules declared by the service's charm. Errors holds
+// the reason each invalid setting was rejected, keyed on option name.
+type ConfigValidationError struct {
+	Errors map[string]string
+}
+
+func (e *ConfigValidationError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %s", name, e.Errors[name])
+	}
+	return "invalid config settings: " + strings.Join(parts, "; ")
+}
+
+// IsConfigValidationError returns whether err is a ConfigValidationError.
+func IsConfigValidationError(err error) bool {
+	_, ok := errors.Cause(err).(*ConfigValidationError)
+	return ok
+}
+
+// validateConfigRules returns a ConfigValidationError if any of the
+// given settings breaks its rule. Unset values are not checked, as
+// they revert to the option's default.
+func validateConfigRules(rules map[string]ConfigRule, settings charm.Settings) error {
+	invalid := make(map[string]string)
+	for name, value := range settings {
+		rule, ok := rules[name]
+		if !ok || value == nil {
+			continue
+		}
+		if reason := rule.validate(value); reason != "" {
+			invalid[name] = reason
+		}
+	}
+	if len(invalid) > 0 {
+		return &ConfigValidationError{Errors: invalid}
+	}
+	return nil
+}
+
+// charmConfigRules returns the config validation rules declared by the
+// given charm, if it is read from a directory or an archive.
+func charmConfigRules(ch charm.Charm) (map[string]ConfigRule, error) {
+	r, err := openCharmFile(ch, "config.yaml")
+	if err != nil || r == nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Close()
+	return ReadConfigRules(r, ch.Config())
+}
+
+// openCharmFile opens the named file of the given charm, if it is read
+// from a directory or an archive. It returns a nil reader if the charm
+// has no such file, or is not read from either.
+func openCharmFile(ch charm.Charm, name string) (io.ReadCloser, error) {
+	switch ch := ch.(type) {
+	case *charm.CharmDir:
+		f, err := os.Open(filepath.Join(ch.Path, name))
+		if os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return f, nil
+	case *charm.CharmArchive:
+		if ch.Path == "" {
+			// The archive was read from memory.
+			return nil, nil
+		}
+		zipr, err := zip.OpenReader(ch.Path)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, f := range zipr.File {
+			if f.Name != name {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				zipr.Close()
+				return nil, errors.Trace(err)
+			}
+			return &zipFileReader{r, zipr}, nil
+		}
+		zipr.Close()
+	}
+	return nil, nil
+}
+
+// zipFileReader reads a file in a zip archive, and closes the archive
+// along with the file.
+type zipFileReader struct {
+	io.ReadCloser
+	zipr *zip.ReadCloser
+}
+
+func (r *zipFileReader) Close() error {
+	err := r.ReadCloser.Close()
+	if zerr := r.zipr.Close(); err == nil {
+		err = zerr
+	}
+	return err
+}
+
+// escapeConfigRules returns the rules keyed on option names escaped
+// for storage in MongoDB. See http://pad.lv/1308146.
+func escapeConfigRules(rules map[string]ConfigRule) map[string]ConfigRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	escaped := make(map[string]ConfigRule)
+	for name, rule := range rules {
+		escaped[escapeReplacer.Replace(name)] = rule
+	}
+	return escaped
+}
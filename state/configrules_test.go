@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/state"
+)
+
+type ConfigRulesSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&ConfigRulesSuite{})
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+var readConfigRulesTests = []struct {
+	about  string
+	config string
+	rules  map[string]state.ConfigRule
+	err    string
+}{{
+	about: "no rules",
+	config: `
+options:
+  title: {type: string}
+`,
+	rules: map[string]state.ConfigRule{},
+}, {
+	about: "all rules",
+	config: `
+options:
+  title: {type: string}
+  flavour: {type: string, enum: [vanilla, chocolate]}
+  hostname: {type: string, pattern: "[a-z]+"}
+  port: {type: int, minimum: 1, maximum: 65535}
+  ratio: {type: float, maximum: 0.5}
+`,
+	rules: map[string]state.ConfigRule{
+		"flavour":  {Enum: []interface{}{"vanilla", "chocolate"}},
+		"hostname": {Pattern: "[a-z]+"},
+		"port":     {Minimum: floatPtr(1), Maximum: floatPtr(65535)},
+		"ratio":    {Maximum: floatPtr(0.5)},
+	},
+}, {
+	about: "range on a string option",
+	config: `
+options:
+  title: {type: string, minimum: 1}
+`,
+	err: `invalid config rules for option "title": range given for string option`,
+}, {
+	about: "empty range",
+	config: `
+options:
+  port: {type: int, minimum: 10, maximum: 1}
+`,
+	err: `invalid config rules for option "port": minimum 10 exceeds maximum 1`,
+}, {
+	about: "pattern on a numeric option",
+	config: `
+options:
+  port: {type: int, pattern: "[0-9]+"}
+`,
+	err: `invalid config rules for option "port": pattern given for int option`,
+}, {
+	about: "invalid pattern",
+	config: `
+options:
+  title: {type: string, pattern: "[a-z"}
+`,
+	err: `invalid config rules for option "title": error parsing regexp: .*`,
+}}
+
+func (s *ConfigRulesSuite) TestReadConfigRules(c *gc.C) {
+	for i, t := range readConfigRulesTests {
+		c.Logf("test %d: %s", i, t.about)
+		config, err := charm.ReadConfig(strings.NewReader(t.config))
+		c.Assert(err, jc.ErrorIsNil)
+		rules, err := state.ReadConfigRules(strings.NewReader(t.config), config)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(rules, jc.DeepEquals, t.rules)
+	}
+}
+
+func (s *ConfigRulesSuite) TestCharmConfigRules(c *gc.C) {
+	ch := s.AddTestingCharm(c, "config-rules")
+	c.Assert(ch.ConfigRules(), jc.DeepEquals, map[string]state.ConfigRule{
+		"flavour":  {Enum: []interface{}{"vanilla", "chocolate"}},
+		"port":     {Minimum: floatPtr(1), Maximum: floatPtr(65535)},
+		"ratio":    {Minimum: floatPtr(0), Maximum: floatPtr(1)},
+		"hostname": {Pattern: "[a-z][a-z0-9-]*"},
+	})
+
+	ch = s.AddTestingCharm(c, "dummy")
+	c.Assert(ch.ConfigRules(), gc.HasLen, 0)
+}
+
+func (s *ConfigRulesSuite) TestUpdateConfigSettings(c *gc.C) {
+	svc := s.AddTestingService(c, "config-rules", s.AddTestingCharm(c, "config-rules"))
+	err := svc.UpdateConfigSettings(charm.Settings{
+		"flavour":  "chocolate",
+		"port":     int64(443),
+		"ratio":    0.25,
+		"hostname": "web-1",
+		"title":    "Anything Goes",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = svc.UpdateConfigSettings(charm.Settings{
+		"flavour":  "strawberry",
+		"port":     int64(0),
+		"ratio":    1.5,
+		"hostname": "Web_1",
+		"title":    "Still Anything",
+	})
+	c.Assert(err, jc.Satisfies, state.IsConfigValidationError)
+	c.Assert(err.(*state.ConfigValidationError).Errors, jc.DeepEquals, map[string]string{
+		"flavour":  "must be one of [vanilla chocolate]",
+		"port":     "must be at least 1",
+		"ratio":    "must be at most 1",
+		"hostname": `must match "[a-z][a-z0-9-]*"`,
+	})
+	c.Assert(err, gc.ErrorMatches, `invalid config settings: `+
+		`flavour: must be one of \[vanilla chocolate\]; `+
+		`hostname: must match "\[a-z\]\[a-z0-9-\]\*"; `+
+		`port: must be at least 1; `+
+		`ratio: must be at most 1`)
+
+	// Nothing is changed when any setting is invalid.
+	settings, err := svc.ConfigSettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings["title"], gc.Equals, "Anything Goes")
+
+	// Unsetting an option reverts it to its default, which is not checked.
+	err = svc.UpdateConfigSettings(charm.Settings{"hostname": nil})
+	c.Assert(err, jc.ErrorIsNil)
+}
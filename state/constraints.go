@@ -17,44 +17,56 @@ import (
 
 // constraintsDoc is the mongodb representation of a constraints.Value.
 type constraintsDoc struct {
-	EnvUUID      string `bson:"env-uuid"`
-	Arch         *string
-	CpuCores     *uint64
-	CpuPower     *uint64
-	Mem          *uint64
-	RootDisk     *uint64
-	InstanceType *string
-	Container    *instance.ContainerType
-	Tags         *[]string `bson:",omitempty"`
-	Networks     *[]string `bson:",omitempty"`
+	EnvUUID        string `bson:"env-uuid"`
+	Arch           *string
+	CpuCores       *uint64
+	CpuPower       *uint64
+	Gpus           *uint64 `bson:",omitempty"`
+	GpuType        *string `bson:",omitempty"`
+	Mem            *uint64
+	RootDisk       *uint64
+	RootDiskSource *string `bson:",omitempty"`
+	InstanceType   *string
+	Container      *instance.ContainerType
+	Tags           *[]string `bson:",omitempty"`
+	Networks       *[]string `bson:",omitempty"`
+	Zones          *[]string `bson:",omitempty"`
 }
 
 func (doc constraintsDoc) value() constraints.Value {
 	return constraints.Value{
-		Arch:         doc.Arch,
-		CpuCores:     doc.CpuCores,
-		CpuPower:     doc.CpuPower,
-		Mem:          doc.Mem,
-		RootDisk:     doc.RootDisk,
-		InstanceType: doc.InstanceType,
-		Container:    doc.Container,
-		Tags:         doc.Tags,
-		Networks:     doc.Networks,
+		Arch:           doc.Arch,
+		CpuCores:       doc.CpuCores,
+		CpuPower:       doc.CpuPower,
+		Gpus:           doc.Gpus,
+		GpuType:        doc.GpuType,
+		Mem:            doc.Mem,
+		RootDisk:       doc.RootDisk,
+		RootDiskSource: doc.RootDiskSource,
+		InstanceType:   doc.InstanceType,
+		Container:      doc.Container,
+		Tags:           doc.Tags,
+		Networks:       doc.Networks,
+		Zones:          doc.Zones,
 	}
 }
 
 func newConstraintsDoc(st *State, cons constraints.Value) constraintsDoc {
 	return constraintsDoc{
-		EnvUUID:      st.EnvironUUID(),
-		Arch:         cons.Arch,
-		CpuCores:     cons.CpuCores,
-		CpuPower:     cons.CpuPower,
-		Mem:          cons.Mem,
-		RootDisk:     cons.RootDisk,
-		InstanceType: cons.InstanceType,
-		Container:    cons.Container,
-		Tags:         cons.Tags,
-		Networks:     cons.Networks,
+		EnvUUID:        st.EnvironUUID(),
+		Arch:           cons.Arch,
+		CpuCores:       cons.CpuCores,
+		CpuPower:       cons.CpuPower,
+		Gpus:           cons.Gpus,
+		GpuType:        cons.GpuType,
+		Mem:            cons.Mem,
+		RootDisk:       cons.RootDisk,
+		RootDiskSource: cons.RootDiskSource,
+		InstanceType:   cons.InstanceType,
+		Container:      cons.Container,
+		Tags:           cons.Tags,
+		Networks:       cons.Networks,
+		Zones:          cons.Zones,
 	}
 }
 
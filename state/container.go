@@ -96,3 +96,15 @@ func TopParentId(machineId string) string {
 	idParts := strings.Split(machineId, "/")
 	return idParts[0]
 }
+
+// IsContainedBy returns whether machineId is the id of a container
+// hosted, directly or at any level of nesting, by the machine with
+// hostId.
+func IsContainedBy(machineId, hostId string) bool {
+	for parentId := ParentId(machineId); parentId != ""; parentId = ParentId(parentId) {
+		if parentId == hostId {
+			return true
+		}
+	}
+	return false
+}
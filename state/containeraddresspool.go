@@ -0,0 +1,467 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ContainerAddressPoolArgs holds the arguments for adding an address
+// pool for the containers attached to a bridge on a host machine.
+type ContainerAddressPoolArgs struct {
+	// MachineId is the id of the machine hosting the bridge.
+	MachineId string
+
+	// Bridge is the name of the bridge device on the host machine.
+	Bridge string
+
+	// CIDR is the IPv4 subnet of the bridge, such as 10.0.3.0/24.
+	CIDR string
+
+	// Gateway is the address of the bridge itself, which containers
+	// route through. If empty, the first address in the subnet is
+	// used.
+	Gateway string
+
+	// Reserved holds the addresses that must not be allocated to
+	// containers, such as those handed out by DHCP on the bridge. Each
+	// is a single address, or an inclusive range in the form
+	// "10.0.3.2-10.0.3.99".
+	Reserved []string
+}
+
+// ContainerAddressPool is a controller-managed pool of addresses for
+// the containers attached to a bridge on a host machine. Containers
+// are allocated addresses from the pool when the provider cannot
+// allocate them.
+type ContainerAddressPool struct {
+	st  *State
+	doc containerAddressPoolDoc
+}
+
+type containerAddressPoolDoc struct {
+	DocID     string   `bson:"_id"`
+	EnvUUID   string   `bson:"env-uuid"`
+	MachineId string   `bson:"machineid"`
+	Bridge    string   `bson:"bridge"`
+	CIDR      string   `bson:"cidr"`
+	Gateway   string   `bson:"gateway"`
+	Reserved  []string `bson:"reserved"`
+}
+
+// ContainerAddress is an address allocated to a container from a
+// ContainerAddressPool, along with the host name it is known by.
+type ContainerAddress struct {
+	doc containerAddressDoc
+}
+
+type containerAddressDoc struct {
+	DocID     string `bson:"_id"`
+	EnvUUID   string `bson:"env-uuid"`
+	PoolId    string `bson:"poolid"`
+	MachineId string `bson:"machineid"`
+	Hostname  string `bson:"hostname"`
+	Value     string `bson:"value"`
+}
+
+// Value returns the allocated address.
+func (a *ContainerAddress) Value() string {
+	return a.doc.Value
+}
+
+// MachineId returns the id of the container the address is allocated
+// to.
+func (a *ContainerAddress) MachineId() string {
+	return a.doc.MachineId
+}
+
+// Hostname returns the host name the container is known by at the
+// address.
+func (a *ContainerAddress) Hostname() string {
+	return a.doc.Hostname
+}
+
+// containerAddressPoolKey returns the key of the address pool for the
+// given bridge on the given machine.
+func containerAddressPoolKey(machineId, bridge string) string {
+	return machineId + "#" + bridge
+}
+
+// MachineId returns the id of the machine hosting the pool's bridge.
+func (p *ContainerAddressPool) MachineId() string {
+	return p.doc.MachineId
+}
+
+// Bridge returns the name of the pool's bridge.
+func (p *ContainerAddressPool) Bridge() string {
+	return p.doc.Bridge
+}
+
+// CIDR returns the subnet of the pool's bridge.
+func (p *ContainerAddressPool) CIDR() string {
+	return p.doc.CIDR
+}
+
+// Gateway returns the address of the pool's bridge.
+func (p *ContainerAddressPool) Gateway() string {
+	return p.doc.Gateway
+}
+
+// Reserved returns the addresses and address ranges that are never
+// allocated from the pool.
+func (p *ContainerAddressPool) Reserved() []string {
+	return p.doc.Reserved
+}
+
+// String implements fmt.Stringer.
+func (p *ContainerAddressPool) String() string {
+	return fmt.Sprintf("%s on machine %s", p.doc.Bridge, p.doc.MachineId)
+}
+
+func (p *ContainerAddressPool) key() string {
+	return containerAddressPoolKey(p.doc.MachineId, p.doc.Bridge)
+}
+
+// Refresh refreshes the contents of the pool from the underlying state.
+// It returns an error that satisfies errors.IsNotFound if the pool has
+// been removed.
+func (p *ContainerAddressPool) Refresh() error {
+	pool, err := p.st.ContainerAddressPool(p.doc.MachineId, p.doc.Bridge)
+	if err != nil {
+		return err
+	}
+	p.doc = pool.doc
+	return nil
+}
+
+// Addresses returns the addresses allocated from the pool.
+func (p *ContainerAddressPool) Addresses() ([]*ContainerAddress, error) {
+	addresses, closer := p.st.getCollection(containerAddressesC)
+	defer closer()
+
+	var docs []containerAddressDoc
+	err := addresses.Find(bson.D{{"poolid", p.key()}}).Sort("_id").All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get addresses allocated from pool %q", p)
+	}
+	result := make([]*ContainerAddress, len(docs))
+	for i, doc := range docs {
+		result[i] = &ContainerAddress{doc}
+	}
+	return result, nil
+}
+
+// AllocateAddress allocates an address from the pool to the given
+// container, recording the host name it is known by at that address.
+// Addresses are allocated lowest first, skipping the gateway and the
+// reserved addresses; a container that already has an address in the
+// pool is given the same one again.
+func (p *ContainerAddressPool) AllocateAddress(containerId, hostname string) (address *ContainerAddress, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot allocate address for container %q from pool %q", containerId, p)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := p.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		allocated, err := p.Addresses()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, existing := range allocated {
+			if existing.MachineId() == containerId {
+				address = existing
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		value, err := p.nextFreeAddress(allocated)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc := containerAddressDoc{
+			DocID:     p.st.docID(p.key() + "#" + value),
+			EnvUUID:   p.st.EnvironUUID(),
+			PoolId:    p.key(),
+			MachineId: containerId,
+			Hostname:  hostname,
+			Value:     value,
+		}
+		address = &ContainerAddress{doc}
+		return []txn.Op{{
+			C:      containerAddressPoolsC,
+			Id:     p.doc.DocID,
+			Assert: txn.DocExists,
+		}, {
+			C:      machinesC,
+			Id:     p.st.docID(containerId),
+			Assert: notDeadDoc,
+		}, {
+			C:      containerAddressesC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := p.st.run(buildTxn); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// nextFreeAddress returns the lowest address in the pool that is not
+// the gateway, reserved, or already allocated.
+func (p *ContainerAddressPool) nextFreeAddress(allocated []*ContainerAddress) (string, error) {
+	_, ipNet, err := net.ParseCIDR(p.doc.CIDR)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	reserved, err := parseAddressRanges(append([]string{p.doc.Gateway}, p.doc.Reserved...))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	for _, address := range allocated {
+		reserved = append(reserved, addressRange{
+			low:  ipv4ToUint32(net.ParseIP(address.Value())),
+			high: ipv4ToUint32(net.ParseIP(address.Value())),
+		})
+	}
+	first, last := hostAddressRange(ipNet)
+	for candidate := first; candidate <= last; candidate++ {
+		free := true
+		for _, r := range reserved {
+			if r.contains(candidate) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return uint32ToIPv4(candidate).String(), nil
+		}
+	}
+	return "", errors.Errorf("no free addresses")
+}
+
+// Remove removes the pool. It fails if any addresses are allocated
+// from it.
+func (p *ContainerAddressPool) Remove() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot remove pool %q", p)
+	allocated, err := p.Addresses()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(allocated) > 0 {
+		return errors.Errorf("%d addresses allocated", len(allocated))
+	}
+	ops := []txn.Op{{
+		C:      containerAddressPoolsC,
+		Id:     p.doc.DocID,
+		Remove: true,
+	}}
+	return onAbort(p.st.runTransaction(ops), nil)
+}
+
+// AddContainerAddressPool adds an address pool for the containers
+// attached to a bridge on a host machine. It returns an error
+// satisfying errors.IsAlreadyExists if the bridge already has a pool.
+func (st *State) AddContainerAddressPool(args ContainerAddressPoolArgs) (pool *ContainerAddressPool, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add address pool for %s on machine %s", args.Bridge, args.MachineId)
+	if args.Bridge == "" {
+		return nil, errors.NotValidf("empty bridge name")
+	}
+	ip, ipNet, err := net.ParseCIDR(args.CIDR)
+	if err != nil {
+		return nil, errors.NotValidf("CIDR %q", args.CIDR)
+	}
+	if ip.To4() == nil {
+		return nil, errors.NotSupportedf("IPv6 CIDR %q", args.CIDR)
+	}
+	first, last := hostAddressRange(ipNet)
+	if first > last {
+		return nil, errors.NotValidf("CIDR %q without host addresses", args.CIDR)
+	}
+	gateway := args.Gateway
+	if gateway == "" {
+		gateway = uint32ToIPv4(first).String()
+	}
+	if gatewayIP := net.ParseIP(gateway); gatewayIP == nil || gatewayIP.To4() == nil || !ipNet.Contains(gatewayIP) {
+		return nil, errors.NotValidf("gateway %q in %q", gateway, args.CIDR)
+	}
+	ranges, err := parseAddressRanges(args.Reserved)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i, r := range ranges {
+		if r.low < first || r.high > last {
+			return nil, errors.NotValidf("reserved range %q outside %q", args.Reserved[i], args.CIDR)
+		}
+	}
+	machine, err := st.Machine(args.MachineId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if machine.Life() != Alive {
+		return nil, errors.New("machine is not alive")
+	}
+	doc := containerAddressPoolDoc{
+		DocID:     st.docID(containerAddressPoolKey(args.MachineId, args.Bridge)),
+		EnvUUID:   st.EnvironUUID(),
+		MachineId: args.MachineId,
+		Bridge:    args.Bridge,
+		CIDR:      ipNet.String(),
+		Gateway:   gateway,
+		Reserved:  args.Reserved,
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     machine.doc.DocID,
+		Assert: isAliveDoc,
+	}, {
+		C:      containerAddressPoolsC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	err = st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		if _, err := st.ContainerAddressPool(args.MachineId, args.Bridge); err == nil {
+			return nil, errors.AlreadyExistsf("pool")
+		}
+		return nil, errors.New("machine is not alive")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &ContainerAddressPool{st: st, doc: doc}, nil
+}
+
+// ContainerAddressPool returns the address pool for the given bridge on
+// the given machine.
+func (st *State) ContainerAddressPool(machineId, bridge string) (*ContainerAddressPool, error) {
+	pools, closer := st.getCollection(containerAddressPoolsC)
+	defer closer()
+
+	var doc containerAddressPoolDoc
+	err := pools.FindId(containerAddressPoolKey(machineId, bridge)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("address pool for %s on machine %s", bridge, machineId)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get address pool for %s on machine %s", bridge, machineId)
+	}
+	return &ContainerAddressPool{st: st, doc: doc}, nil
+}
+
+// ContainerAddressPools returns the address pools for the bridges on
+// the given machine.
+func (st *State) ContainerAddressPools(machineId string) ([]*ContainerAddressPool, error) {
+	pools, closer := st.getCollection(containerAddressPoolsC)
+	defer closer()
+
+	var docs []containerAddressPoolDoc
+	err := pools.Find(bson.D{{"machineid", machineId}}).Sort("bridge").All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get address pools for machine %s", machineId)
+	}
+	result := make([]*ContainerAddressPool, len(docs))
+	for i, doc := range docs {
+		result[i] = &ContainerAddressPool{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// removeContainerAddressesOps returns the operations needed to release
+// the pool addresses allocated to the given machine, and to remove the
+// pools for the bridges it hosts.
+func removeContainerAddressesOps(st *State, machineId string) ([]txn.Op, error) {
+	addresses, closer := st.getCollection(containerAddressesC)
+	defer closer()
+	pools, closer := st.getCollection(containerAddressPoolsC)
+	defer closer()
+
+	var ops []txn.Op
+	var doc struct {
+		DocID string `bson:"_id"`
+	}
+	iter := addresses.Find(bson.D{{"machineid", machineId}}).Select(bson.D{{"_id", 1}}).Iter()
+	for iter.Next(&doc) {
+		ops = append(ops, txn.Op{
+			C:      containerAddressesC,
+			Id:     doc.DocID,
+			Remove: true,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotate(err, "cannot read container addresses")
+	}
+	iter = pools.Find(bson.D{{"machineid", machineId}}).Select(bson.D{{"_id", 1}}).Iter()
+	for iter.Next(&doc) {
+		ops = append(ops, txn.Op{
+			C:      containerAddressPoolsC,
+			Id:     doc.DocID,
+			Remove: true,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotate(err, "cannot read container address pools")
+	}
+	return ops, nil
+}
+
+// addressRange is an inclusive range of IPv4 addresses.
+type addressRange struct {
+	low, high uint32
+}
+
+func (r addressRange) contains(address uint32) bool {
+	return address >= r.low && address <= r.high
+}
+
+// parseAddressRanges parses IPv4 addresses and inclusive address ranges
+// in the form "10.0.3.2-10.0.3.99".
+func parseAddressRanges(specs []string) ([]addressRange, error) {
+	ranges := make([]addressRange, len(specs))
+	for i, spec := range specs {
+		parts := strings.SplitN(spec, "-", 2)
+		if len(parts) == 1 {
+			parts = append(parts, parts[0])
+		}
+		low := net.ParseIP(strings.TrimSpace(parts[0])).To4()
+		high := net.ParseIP(strings.TrimSpace(parts[1])).To4()
+		if low == nil || high == nil {
+			return nil, errors.NotValidf("address range %q", spec)
+		}
+		ranges[i] = addressRange{ipv4ToUint32(low), ipv4ToUint32(high)}
+		if ranges[i].low > ranges[i].high {
+			return nil, errors.NotValidf("address range %q", spec)
+		}
+	}
+	return ranges, nil
+}
+
+// hostAddressRange returns the first and last addresses in the given
+// IPv4 subnet that can be assigned to hosts, excluding the network and
+// broadcast addresses.
+func hostAddressRange(ipNet *net.IPNet) (first, last uint32) {
+	network := ipv4ToUint32(ipNet.IP)
+	mask := binary.BigEndian.Uint32(net.IP(ipNet.Mask).To4())
+	broadcast := network | ^mask
+	return network + 1, broadcast - 1
+}
+
+func ipv4ToUint32(ip net.IP) uint32 {
+	return binary.BigEndian.Uint32(ip.To4())
+}
+
+func uint32ToIPv4(value uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, value)
+	return ip
+}
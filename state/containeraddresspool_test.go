@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+type ContainerAddressPoolSuite struct {
+	ConnSuite
+	host      *state.Machine
+	container *state.Machine
+}
+
+var _ = gc.Suite(&ContainerAddressPoolSuite{})
+
+func (s *ContainerAddressPoolSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.host, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.container = s.addContainer(c)
+}
+
+func (s *ContainerAddressPoolSuite) addContainer(c *gc.C) *state.Machine {
+	template := state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}
+	container, err := s.State.AddMachineInsideMachine(template, s.host.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	return container
+}
+
+func (s *ContainerAddressPoolSuite) addPool(c *gc.C, reserved ...string) *state.ContainerAddressPool {
+	pool, err := s.State.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+		MachineId: s.host.Id(),
+		Bridge:    "lxcbr0",
+		CIDR:      "10.0.3.0/24",
+		Reserved:  reserved,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return pool
+}
+
+func (s *ContainerAddressPoolSuite) TestAddContainerAddressPool(c *gc.C) {
+	pool := s.addPool(c, "10.0.3.100-10.0.3.254")
+	c.Assert(pool.MachineId(), gc.Equals, s.host.Id())
+	c.Assert(pool.Bridge(), gc.Equals, "lxcbr0")
+	c.Assert(pool.CIDR(), gc.Equals, "10.0.3.0/24")
+	c.Assert(pool.Gateway(), gc.Equals, "10.0.3.1")
+	c.Assert(pool.Reserved(), jc.DeepEquals, []string{"10.0.3.100-10.0.3.254"})
+
+	got, err := s.State.ContainerAddressPool(s.host.Id(), "lxcbr0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(got, jc.DeepEquals, pool)
+
+	pools, err := s.State.ContainerAddressPools(s.host.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pools, jc.DeepEquals, []*state.ContainerAddressPool{pool})
+}
+
+func (s *ContainerAddressPoolSuite) TestAddContainerAddressPoolAlreadyExists(c *gc.C) {
+	s.addPool(c)
+	_, err := s.State.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+		MachineId: s.host.Id(),
+		Bridge:    "lxcbr0",
+		CIDR:      "10.0.4.0/24",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	c.Assert(err, gc.ErrorMatches, "cannot add address pool for lxcbr0 on machine 0: pool already exists")
+}
+
+func (s *ContainerAddressPoolSuite) TestAddContainerAddressPoolInvalid(c *gc.C) {
+	for i, test := range []struct {
+		args state.ContainerAddressPoolArgs
+		err  string
+	}{{
+		args: state.ContainerAddressPoolArgs{CIDR: "10.0.3.0/24"},
+		err:  "empty bridge name not valid",
+	}, {
+		args: state.ContainerAddressPoolArgs{Bridge: "lxcbr0", CIDR: "10.0.3.0"},
+		err:  `CIDR "10.0.3.0" not valid`,
+	}, {
+		args: state.ContainerAddressPoolArgs{Bridge: "lxcbr0", CIDR: "fc00::/64"},
+		err:  `IPv6 CIDR "fc00::/64" not supported`,
+	}, {
+		args: state.ContainerAddressPoolArgs{Bridge: "lxcbr0", CIDR: "10.0.3.0/24", Gateway: "10.0.4.1"},
+		err:  `gateway "10.0.4.1" in "10.0.3.0/24" not valid`,
+	}, {
+		args: state.ContainerAddressPoolArgs{Bridge: "lxcbr0", CIDR: "10.0.3.0/24", Reserved: []string{"10.0.3.9-10.0.3.2"}},
+		err:  `address range "10.0.3.9-10.0.3.2" not valid`,
+	}, {
+		args: state.ContainerAddressPoolArgs{Bridge: "lxcbr0", CIDR: "10.0.3.0/24", Reserved: []string{"10.0.3.200-10.0.4.10"}},
+		err:  `reserved range "10.0.3.200-10.0.4.10" outside "10.0.3.0/24" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		test.args.MachineId = s.host.Id()
+		_, err := s.State.AddContainerAddressPool(test.args)
+		c.Check(err, gc.ErrorMatches, "cannot add address pool for .*: "+test.err)
+	}
+}
+
+func (s *ContainerAddressPoolSuite) TestAddContainerAddressPoolMachineNotAlive(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddContainerAddressPool(state.ContainerAddressPoolArgs{
+		MachineId: machine.Id(),
+		Bridge:    "lxcbr0",
+		CIDR:      "10.0.3.0/24",
+	})
+	c.Assert(err, gc.ErrorMatches, "cannot add address pool for lxcbr0 on machine 1: machine is not alive")
+}
+
+func (s *ContainerAddressPoolSuite) TestContainerAddressPoolNotFound(c *gc.C) {
+	_, err := s.State.ContainerAddressPool(s.host.Id(), "lxcbr0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "address pool for lxcbr0 on machine 0 not found")
+}
+
+func (s *ContainerAddressPoolSuite) TestAllocateAddress(c *gc.C) {
+	pool := s.addPool(c, "10.0.3.2", "10.0.3.4-10.0.3.9")
+	address, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(address.Value(), gc.Equals, "10.0.3.3")
+	c.Assert(address.MachineId(), gc.Equals, s.container.Id())
+	c.Assert(address.Hostname(), gc.Equals, "juju-machine-0-lxc-0")
+
+	other := s.addContainer(c)
+	otherAddress, err := pool.AllocateAddress(other.Id(), "juju-machine-0-lxc-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(otherAddress.Value(), gc.Equals, "10.0.3.10")
+
+	addresses, err := pool.Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, jc.DeepEquals, []*state.ContainerAddress{otherAddress, address})
+}
+
+func (s *ContainerAddressPoolSuite) TestAllocateAddressTwice(c *gc.C) {
+	pool := s.addPool(c)
+	address, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+	again, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(again, jc.DeepEquals, address)
+}
+
+func (s *ContainerAddressPoolSuite) TestAllocateAddressExhausted(c *gc.C) {
+	pool := s.addPool(c, "10.0.3.2-10.0.3.254")
+	_, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, gc.ErrorMatches, `cannot allocate address for container "0/lxc/0" from pool "lxcbr0 on machine 0": no free addresses`)
+}
+
+func (s *ContainerAddressPoolSuite) TestAllocateAddressPoolRemoved(c *gc.C) {
+	pool := s.addPool(c)
+	err := pool.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *ContainerAddressPoolSuite) TestRemoveWithAllocatedAddresses(c *gc.C) {
+	pool := s.addPool(c)
+	_, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+	err = pool.Remove()
+	c.Assert(err, gc.ErrorMatches, `cannot remove pool "lxcbr0 on machine 0": 1 addresses allocated`)
+}
+
+func (s *ContainerAddressPoolSuite) TestMachineRemovalReleasesAddresses(c *gc.C) {
+	pool := s.addPool(c)
+	_, err := pool.AllocateAddress(s.container.Id(), "juju-machine-0-lxc-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.container.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.container.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	addresses, err := pool.Addresses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(addresses, gc.HasLen, 0)
+
+	err = s.host.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.host.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.ContainerAddressPool(s.host.Id(), "lxcbr0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,146 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// DNSRecord describes the host name and addresses of a machine as
+// registered in the environment's DNS backend.
+type DNSRecord struct {
+	// MachineId is the id of the machine the record is for. The
+	// record outlives the machine until it is removed from the
+	// backend.
+	MachineId string
+
+	// Hostname is the fully qualified host name of the machine.
+	Hostname string
+
+	// Addresses holds the addresses registered for the host name.
+	Addresses []string
+}
+
+// dnsRecordDoc records the registration of a machine's host name and
+// addresses, so that they can be updated when the addresses change and
+// removed from the backend once the machine has gone.
+type dnsRecordDoc struct {
+	DocID     string   `bson:"_id"`
+	EnvUUID   string   `bson:"env-uuid"`
+	MachineId string   `bson:"machineid"`
+	Hostname  string   `bson:"hostname"`
+	Addresses []string `bson:"addresses"`
+}
+
+func (doc *dnsRecordDoc) toDNSRecord() DNSRecord {
+	return DNSRecord{
+		MachineId: doc.MachineId,
+		Hostname:  doc.Hostname,
+		Addresses: doc.Addresses,
+	}
+}
+
+// DNSRecords returns the registered DNS records of the environment's
+// machines, ordered by machine id.
+func (st *State) DNSRecords() ([]DNSRecord, error) {
+	dnsRecords, closer := st.getCollection(dnsRecordsC)
+	defer closer()
+
+	var docs []dnsRecordDoc
+	if err := dnsRecords.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get DNS records")
+	}
+	result := make([]DNSRecord, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.toDNSRecord()
+	}
+	sort.Sort(dnsRecordsByMachineId(result))
+	return result, nil
+}
+
+type dnsRecordsByMachineId []DNSRecord
+
+func (s dnsRecordsByMachineId) Len() int      { return len(s) }
+func (s dnsRecordsByMachineId) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s dnsRecordsByMachineId) Less(i, j int) bool {
+	return machineIdLessThan(s[i].MachineId, s[j].MachineId)
+}
+
+// SetDNSRecord records that the given host name and addresses of a
+// machine are registered, replacing any earlier record for it.
+func (st *State) SetDNSRecord(record DNSRecord) error {
+	addresses := append([]string{}, record.Addresses...)
+	sort.Strings(addresses)
+	docID := st.docID(record.MachineId)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		dnsRecords, closer := st.getCollection(dnsRecordsC)
+		defer closer()
+
+		var doc dnsRecordDoc
+		err := dnsRecords.FindId(record.MachineId).One(&doc)
+		if err == nil {
+			if doc.Hostname == record.Hostname && stringSlicesEqual(doc.Addresses, addresses) {
+				return nil, jujutxn.ErrNoOperations
+			}
+			return []txn.Op{{
+				C:      dnsRecordsC,
+				Id:     docID,
+				Assert: txn.DocExists,
+				Update: bson.D{{"$set", bson.D{
+					{"hostname", record.Hostname},
+					{"addresses", addresses},
+				}}},
+			}}, nil
+		} else if err != mgo.ErrNotFound {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      dnsRecordsC,
+			Id:     docID,
+			Assert: txn.DocMissing,
+			Insert: &dnsRecordDoc{
+				EnvUUID:   st.EnvironUUID(),
+				MachineId: record.MachineId,
+				Hostname:  record.Hostname,
+				Addresses: addresses,
+			},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set DNS record for machine %s", record.MachineId)
+	}
+	return nil
+}
+
+// RemoveDNSRecord removes the record of the registration of the given
+// machine's host name, if any.
+func (st *State) RemoveDNSRecord(machineId string) error {
+	ops := []txn.Op{{
+		C:      dnsRecordsC,
+		Id:     st.docID(machineId),
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove DNS record for machine %s", machineId)
+	}
+	return nil
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
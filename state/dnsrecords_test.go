@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type DNSRecordsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&DNSRecordsSuite{})
+
+func (s *DNSRecordsSuite) TestDNSRecordsNone(c *gc.C) {
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *DNSRecordsSuite) TestSetDNSRecord(c *gc.C) {
+	for _, record := range []state.DNSRecord{{
+		MachineId: "10",
+		Hostname:  "machine-10.example.com",
+		Addresses: []string{"10.0.0.10"},
+	}, {
+		MachineId: "2/lxc/0",
+		Hostname:  "machine-2-lxc-0.example.com",
+		Addresses: []string{"10.0.3.2", "10.0.0.3"},
+	}, {
+		MachineId: "2",
+		Hostname:  "machine-2.example.com",
+		Addresses: []string{"10.0.0.2"},
+	}} {
+		err := s.State.SetDNSRecord(record)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.DNSRecord{{
+		MachineId: "2",
+		Hostname:  "machine-2.example.com",
+		Addresses: []string{"10.0.0.2"},
+	}, {
+		MachineId: "2/lxc/0",
+		Hostname:  "machine-2-lxc-0.example.com",
+		Addresses: []string{"10.0.0.3", "10.0.3.2"},
+	}, {
+		MachineId: "10",
+		Hostname:  "machine-10.example.com",
+		Addresses: []string{"10.0.0.10"},
+	}})
+}
+
+func (s *DNSRecordsSuite) TestSetDNSRecordReplaces(c *gc.C) {
+	err := s.State.SetDNSRecord(state.DNSRecord{
+		MachineId: "0",
+		Hostname:  "machine-0.example.com",
+		Addresses: []string{"10.0.0.2"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	replacement := state.DNSRecord{
+		MachineId: "0",
+		Hostname:  "machine-0.example.com",
+		Addresses: []string{"10.0.0.5", "2001:db8::5"},
+	}
+	err = s.State.SetDNSRecord(replacement)
+	c.Assert(err, jc.ErrorIsNil)
+	// Setting the same record again is a no-op.
+	err = s.State.SetDNSRecord(replacement)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.DNSRecord{replacement})
+}
+
+func (s *DNSRecordsSuite) TestRemoveDNSRecord(c *gc.C) {
+	err := s.State.SetDNSRecord(state.DNSRecord{
+		MachineId: "0",
+		Hostname:  "machine-0.example.com",
+		Addresses: []string{"10.0.0.2"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveDNSRecord("0")
+	c.Assert(err, jc.ErrorIsNil)
+	// Removing a missing record is not an error.
+	err = s.State.RemoveDNSRecord("0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
@@ -32,6 +32,24 @@ type environmentDoc struct {
 	Life       Life
 	Owner      string `bson:"owner"`
 	ServerUUID string `bson:"server-uuid"`
+
+	// Cloud and CloudCredential are set when the environment is
+	// hosted on a cloud other than the state server's, and name the
+	// cloud and the owner's credential for it.
+	Cloud           string `bson:"cloud,omitempty"`
+	CloudCredential string `bson:"cloud-credential,omitempty"`
+
+	// CredentialInvalid is set when the provider has rejected the
+	// environment's credentials, suspending the environment until
+	// they are updated.
+	CredentialInvalid       bool   `bson:"credential-invalid,omitempty"`
+	CredentialInvalidReason string `bson:"credential-invalid-reason,omitempty"`
+
+	// Suspended is set when an operator has suspended the
+	// environment's automation, for example during a provider
+	// outage, until it is resumed.
+	Suspended       bool   `bson:"suspended,omitempty"`
+	SuspendedReason string `bson:"suspended-reason,omitempty"`
 }
 
 // StateServerEnvironment returns the environment that was bootstrapped.
@@ -90,7 +108,25 @@ func (st *State) GetEnvironment(tag names.EnvironTag) (*Environment, error) {
 // environment document means that we have a way to represent external
 // environments, perhaps for future use around cross environment
 // relations.
-func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (_ *Environment, _ *State, err error) {
+func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (*Environment, *State, error) {
+	return st.newEnvironment(cfg, owner, "", "")
+}
+
+// NewEnvironmentOnCloud creates a new environment as NewEnvironment
+// does, hosted on the named cloud rather than the state server's, and
+// using the owner's named credential for the cloud. The config must
+// already hold the cloud's and the credential's attributes.
+func (st *State) NewEnvironmentOnCloud(cfg *config.Config, owner names.UserTag, cloud, credential string) (*Environment, *State, error) {
+	if cloud == "" {
+		return nil, nil, errors.NotValidf("empty cloud name")
+	}
+	if _, err := st.CloudCredential(owner, cloud, credential); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot create environment")
+	}
+	return st.newEnvironment(cfg, owner, cloud, credential)
+}
+
+func (st *State) newEnvironment(cfg *config.Config, owner names.UserTag, cloud, credential string) (_ *Environment, _ *State, err error) {
 	if owner.IsLocal() {
 		if _, err := st.User(owner); err != nil {
 			return nil, nil, errors.Annotate(err, "cannot create environment")
@@ -120,6 +156,19 @@ func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (_ *Env
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "failed to create new environment")
 	}
+	if cloud != "" {
+		for _, op := range ops {
+			if doc, ok := op.Insert.(*environmentDoc); ok {
+				doc.Cloud = cloud
+				doc.CloudCredential = credential
+			}
+		}
+		ops = append(ops, txn.Op{
+			C:      cloudCredentialsC,
+			Id:     cloudCredentialDocID(owner, cloud, credential),
+			Assert: txn.DocExists,
+		})
+	}
 	err = newState.runTransactionNoEnvAliveAssert(ops)
 	if err == txn.ErrAborted {
 
@@ -187,6 +236,105 @@ func (e *Environment) Name() string {
 	return e.doc.Name
 }
 
+// Cloud returns the name of the cloud the environment is hosted on,
+// or the empty string if it is hosted on the state server's cloud.
+func (e *Environment) Cloud() string {
+	return e.doc.Cloud
+}
+
+// CloudCredential returns the name of the owner's credential the
+// environment uses for its cloud, if it is not hosted on the state
+// server's cloud.
+func (e *Environment) CloudCredential() string {
+	return e.doc.CloudCredential
+}
+
+// Suspended returns whether the environment is suspended, either by an
+// operator or because the provider has rejected its credentials, and
+// if so, the reason given. While the environment is suspended, workers
+// that change the environment's instances, ports and charms are paused.
+func (e *Environment) Suspended() (bool, string) {
+	if e.doc.Suspended {
+		return true, e.doc.SuspendedReason
+	}
+	return e.doc.CredentialInvalid, e.doc.CredentialInvalidReason
+}
+
+// defaultSuspendReason is the reason recorded when an operator
+// suspends an environment without giving one.
+const defaultSuspendReason = "suspended by operator"
+
+// Suspend suspends the environment's automation, for the given reason,
+// until Resume is called.
+func (e *Environment) Suspend(reason string) error {
+	if reason == "" {
+		reason = defaultSuspendReason
+	}
+	if err := e.setSuspended(true, reason); err != nil {
+		return errors.Annotate(err, "cannot suspend environment")
+	}
+	return nil
+}
+
+// Resume resumes the environment's automation after a call to Suspend.
+// The environment remains suspended while the provider rejects its
+// credentials.
+func (e *Environment) Resume() error {
+	if err := e.setSuspended(false, ""); err != nil {
+		return errors.Annotate(err, "cannot resume environment")
+	}
+	return nil
+}
+
+func (e *Environment) setSuspended(suspended bool, reason string) error {
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     e.doc.UUID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"suspended", suspended},
+			{"suspended-reason", reason},
+		}}},
+	}}
+	if err := e.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("environment")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	e.doc.Suspended = suspended
+	e.doc.SuspendedReason = reason
+	return nil
+}
+
+// SetCredentialValidity records whether the provider accepts the
+// environment's credentials. An environment whose credentials are
+// rejected is suspended, for the given reason, until they are found
+// to be valid again.
+func (e *Environment) SetCredentialValidity(valid bool, reason string) error {
+	if valid {
+		reason = ""
+	} else if reason == "" {
+		return errors.NotValidf("empty reason for invalid credentials")
+	}
+	ops := []txn.Op{{
+		C:      environmentsC,
+		Id:     e.doc.UUID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{
+			{"credential-invalid", !valid},
+			{"credential-invalid-reason", reason},
+		}}},
+	}}
+	if err := e.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("environment")
+	} else if err != nil {
+		return errors.Annotate(err, "cannot set credential validity")
+	}
+	e.doc.CredentialInvalid = !valid
+	e.doc.CredentialInvalidReason = reason
+	return nil
+}
+
 // Life returns whether the environment is Alive, Dying or Dead.
 func (e *Environment) Life() Life {
 	return e.doc.Life
@@ -205,7 +353,7 @@ func (e *Environment) Config() (*config.Config, error) {
 	}
 	// The active environment isn't the same as the environment
 	// we are querying.
-	envState, err := e.st.ForEnviron(e.ServerTag())
+	envState, err := e.st.ForEnviron(e.EnvironTag())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -176,6 +176,149 @@ func (s *EnvironSuite) TestEnvironmentConfigDifferentEnvThanState(c *gc.C) {
 	c.Assert(uuid, gc.Not(gc.Equals), s.State.EnvironUUID())
 }
 
+func (s *EnvironSuite) TestOtherEnvironmentConfig(c *gc.C) {
+	otherState := s.factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+	env, err := s.State.GetEnvironment(otherState.EnvironTag())
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err := env.Config()
+	c.Assert(err, jc.ErrorIsNil)
+	uuid, exists := cfg.UUID()
+	c.Assert(exists, jc.IsTrue)
+	c.Assert(uuid, gc.Equals, otherState.EnvironUUID())
+}
+
+func (s *EnvironSuite) TestNewEnvironmentOnCloud(c *gc.C) {
+	owner := s.factory.MakeUser(c, nil).UserTag()
+	_, err := s.State.AddCloud("other", "dummy", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateCloudCredential(owner, "other", "default", map[string]string{"secret": "pork"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfg, _ := s.createTestEnvConfig(c)
+	env, st, err := s.State.NewEnvironmentOnCloud(cfg, owner, "other", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+	c.Assert(env.Cloud(), gc.Equals, "other")
+	c.Assert(env.CloudCredential(), gc.Equals, "default")
+
+	env, err = s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.Cloud(), gc.Equals, "")
+	c.Assert(env.CloudCredential(), gc.Equals, "")
+}
+
+func (s *EnvironSuite) TestNewEnvironmentOnCloudMissingCredential(c *gc.C) {
+	owner := s.factory.MakeUser(c, nil).UserTag()
+	_, err := s.State.AddCloud("other", "dummy", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfg, _ := s.createTestEnvConfig(c)
+	_, _, err = s.State.NewEnvironmentOnCloud(cfg, owner, "other", "default")
+	c.Assert(err, gc.ErrorMatches, `cannot create environment: credential "default" for cloud "other" not found`)
+}
+
+func (s *EnvironSuite) TestUpdateCloudCredentialUpdatesEnvironConfig(c *gc.C) {
+	owner := s.factory.MakeUser(c, nil).UserTag()
+	_, err := s.State.AddCloud("other", "dummy", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateCloudCredential(owner, "other", "default", map[string]string{"secret": "pork"})
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, _ := s.createTestEnvConfig(c)
+	env, st, err := s.State.NewEnvironmentOnCloud(cfg, owner, "other", "default")
+	c.Assert(err, jc.ErrorIsNil)
+	defer st.Close()
+
+	err = s.State.UpdateCloudCredential(owner, "other", "default", map[string]string{"secret": "bacon"})
+	c.Assert(err, jc.ErrorIsNil)
+	envCfg, err := env.Config()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envCfg.AllAttrs()["secret"], gc.Equals, "bacon")
+
+	// The state server environment does not use the credential.
+	envCfg, err = s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envCfg.AllAttrs()["secret"], gc.Not(gc.Equals), "bacon")
+}
+
+func (s *EnvironSuite) TestSetCredentialValidity(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsFalse)
+	c.Assert(reason, gc.Equals, "")
+
+	err = env.SetCredentialValidity(false, "secret rejected")
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason = env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "secret rejected")
+
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason = env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "secret rejected")
+
+	err = env.SetCredentialValidity(true, "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason = env.Suspended()
+	c.Assert(suspended, jc.IsFalse)
+	c.Assert(reason, gc.Equals, "")
+}
+
+func (s *EnvironSuite) TestSetCredentialValidityNeedsReason(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValidity(false, "")
+	c.Assert(err, gc.ErrorMatches, "empty reason for invalid credentials not valid")
+}
+
+func (s *EnvironSuite) TestSuspendResume(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("provider outage")
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "provider outage")
+
+	// Operator suspension takes precedence over invalid credentials.
+	err = env.SetCredentialValidity(false, "secret rejected")
+	c.Assert(err, jc.ErrorIsNil)
+	_, reason = env.Suspended()
+	c.Assert(reason, gc.Equals, "provider outage")
+
+	// Resuming leaves the environment suspended until its
+	// credentials are valid again.
+	err = env.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason = env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "secret rejected")
+
+	err = env.SetCredentialValidity(true, "")
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, _ = env.Suspended()
+	c.Assert(suspended, jc.IsFalse)
+}
+
+func (s *EnvironSuite) TestSuspendDefaultReason(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("")
+	c.Assert(err, jc.ErrorIsNil)
+	suspended, reason := env.Suspended()
+	c.Assert(suspended, jc.IsTrue)
+	c.Assert(reason, gc.Equals, "suspended by operator")
+}
+
 func (s *EnvironSuite) TestDestroyStateServerEnvironment(c *gc.C) {
 	env, err := s.State.Environment()
 	c.Assert(err, jc.ErrorIsNil)
@@ -0,0 +1,184 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// environDefaultsKey is the id of the state servers document holding
+// the config defaults for all new environments. Defaults for the new
+// environments in a region are held in documents with the region name
+// appended to it.
+const environDefaultsKey = "environDefaults"
+
+func environDefaultsRegionKey(region string) string {
+	if region == "" {
+		return environDefaultsKey
+	}
+	return environDefaultsKey + "#" + region
+}
+
+// environDefaultsDoc holds config defaults set on the state server,
+// either for all new environments or for those in a single region.
+type environDefaultsDoc struct {
+	DocID    string                 `bson:"_id"`
+	Region   string                 `bson:"region"`
+	Values   map[string]interface{} `bson:"values"`
+	TxnRevno int64                  `bson:"txn-revno"`
+}
+
+// EnvironDefaults holds the config defaults set on the state server.
+// Values are inherited by all new environments; the values held in
+// Regions, keyed on region name, are inherited only by new environments
+// in that region, and take precedence over Values.
+type EnvironDefaults struct {
+	Values  map[string]interface{}
+	Regions map[string]map[string]interface{}
+}
+
+// EnvironDefaults returns the config defaults set on the state server.
+func (st *State) EnvironDefaults() (EnvironDefaults, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+
+	result := EnvironDefaults{
+		Values:  make(map[string]interface{}),
+		Regions: make(map[string]map[string]interface{}),
+	}
+	var doc environDefaultsDoc
+	iter := stateServers.Find(bson.D{{"_id", bson.D{{"$regex", "^" + environDefaultsKey}}}}).Iter()
+	for iter.Next(&doc) {
+		values := unescapeEnvironDefaults(doc.Values)
+		if doc.Region == "" {
+			result.Values = values
+		} else {
+			result.Regions[doc.Region] = values
+		}
+		doc = environDefaultsDoc{}
+	}
+	if err := iter.Close(); err != nil {
+		return EnvironDefaults{}, errors.Annotate(err, "cannot read environment defaults")
+	}
+	return result, nil
+}
+
+// EnvironDefaultsForRegion returns the config defaults inherited by new
+// environments in the given region: those set for all environments,
+// overridden by those set for the region.
+func (st *State) EnvironDefaultsForRegion(region string) (map[string]interface{}, error) {
+	values, _, err := st.environDefaultValues("")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if region == "" {
+		return values, nil
+	}
+	regionValues, _, err := st.environDefaultValues(region)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for key, value := range regionValues {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// environDefaultValues returns the config defaults set for the given
+// region, or for all environments if region is empty, along with the
+// txn-revno of the document holding them. The revno is -1 if no such
+// document exists.
+func (st *State) environDefaultValues(region string) (map[string]interface{}, int64, error) {
+	stateServers, closer := st.getCollection(stateServersC)
+	defer closer()
+
+	var doc environDefaultsDoc
+	err := stateServers.FindId(environDefaultsRegionKey(region)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return make(map[string]interface{}), -1, nil
+	} else if err != nil {
+		return nil, 0, errors.Annotate(err, "cannot read environment defaults")
+	}
+	return unescapeEnvironDefaults(doc.Values), doc.TxnRevno, nil
+}
+
+// UpdateEnvironDefaults sets the given config defaults, and removes
+// those named in remove, for new environments in the given region, or
+// for all new environments if region is empty. Existing environments
+// are not affected.
+func (st *State) UpdateEnvironDefaults(region string, update map[string]interface{}, remove []string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot update environment defaults")
+	for _, key := range remove {
+		if _, ok := update[key]; ok {
+			return errors.Errorf("cannot both set and remove %q", key)
+		}
+	}
+	docID := environDefaultsRegionKey(region)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		values, txnRevno, err := st.environDefaultValues(region)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for key, value := range update {
+			values[key] = value
+		}
+		for _, key := range remove {
+			delete(values, key)
+		}
+		switch {
+		case txnRevno == -1 && len(values) == 0:
+			return nil, jujutxn.ErrNoOperations
+		case txnRevno == -1:
+			return []txn.Op{{
+				C:      stateServersC,
+				Id:     docID,
+				Assert: txn.DocMissing,
+				Insert: &environDefaultsDoc{
+					DocID:  docID,
+					Region: region,
+					Values: escapeEnvironDefaults(values),
+				},
+			}}, nil
+		case len(values) == 0:
+			return []txn.Op{{
+				C:      stateServersC,
+				Id:     docID,
+				Assert: bson.D{{"txn-revno", txnRevno}},
+				Remove: true,
+			}}, nil
+		}
+		return []txn.Op{{
+			C:      stateServersC,
+			Id:     docID,
+			Assert: bson.D{{"txn-revno", txnRevno}},
+			Update: bson.D{{"$set", bson.D{{"values", escapeEnvironDefaults(values)}}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil && err != jujutxn.ErrNoOperations {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// escapeEnvironDefaults returns the values keyed on names escaped for
+// storage in MongoDB. See http://pad.lv/1308146.
+func escapeEnvironDefaults(values map[string]interface{}) map[string]interface{} {
+	escaped := make(map[string]interface{})
+	for key, value := range values {
+		escaped[escapeReplacer.Replace(key)] = value
+	}
+	return escaped
+}
+
+func unescapeEnvironDefaults(values map[string]interface{}) map[string]interface{} {
+	unescaped := make(map[string]interface{})
+	for key, value := range values {
+		unescaped[unescapeReplacer.Replace(key)] = value
+	}
+	return unescaped
+}
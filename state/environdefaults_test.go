@@ -0,0 +1,102 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type EnvironDefaultsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&EnvironDefaultsSuite{})
+
+func (s *EnvironDefaultsSuite) TestNoDefaults(c *gc.C) {
+	defaults, err := s.State.EnvironDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults, jc.DeepEquals, state.EnvironDefaults{
+		Values:  map[string]interface{}{},
+		Regions: map[string]map[string]interface{}{},
+	})
+
+	values, err := s.State.EnvironDefaultsForRegion("us-east-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, gc.HasLen, 0)
+}
+
+func (s *EnvironDefaultsSuite) TestUpdateEnvironDefaults(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("", map[string]interface{}{
+		"default-series": "trusty",
+		"logging-config": "<root>=DEBUG",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.UpdateEnvironDefaults("us-east-1", map[string]interface{}{
+		"default-series":   "precise",
+		"image-stream.foo": "daily",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	defaults, err := s.State.EnvironDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults, jc.DeepEquals, state.EnvironDefaults{
+		Values: map[string]interface{}{
+			"default-series": "trusty",
+			"logging-config": "<root>=DEBUG",
+		},
+		Regions: map[string]map[string]interface{}{
+			"us-east-1": {
+				"default-series":   "precise",
+				"image-stream.foo": "daily",
+			},
+		},
+	})
+
+	values, err := s.State.EnvironDefaultsForRegion("us-east-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, jc.DeepEquals, map[string]interface{}{
+		"default-series":   "precise",
+		"logging-config":   "<root>=DEBUG",
+		"image-stream.foo": "daily",
+	})
+	values, err = s.State.EnvironDefaultsForRegion("us-west-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, jc.DeepEquals, map[string]interface{}{
+		"default-series": "trusty",
+		"logging-config": "<root>=DEBUG",
+	})
+}
+
+func (s *EnvironDefaultsSuite) TestRemoveEnvironDefaults(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("us-east-1", map[string]interface{}{
+		"default-series": "precise",
+		"logging-config": "<root>=DEBUG",
+	}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.UpdateEnvironDefaults("us-east-1", nil, []string{"default-series", "no-such-key"})
+	c.Assert(err, jc.ErrorIsNil)
+	values, err := s.State.EnvironDefaultsForRegion("us-east-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(values, jc.DeepEquals, map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	})
+
+	// A region without defaults is no longer listed.
+	err = s.State.UpdateEnvironDefaults("us-east-1", nil, []string{"logging-config"})
+	c.Assert(err, jc.ErrorIsNil)
+	defaults, err := s.State.EnvironDefaults()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(defaults.Regions, gc.HasLen, 0)
+}
+
+func (s *EnvironDefaultsSuite) TestUpdateEnvironDefaultsSetAndRemove(c *gc.C) {
+	err := s.State.UpdateEnvironDefaults("", map[string]interface{}{
+		"default-series": "trusty",
+	}, []string{"default-series"})
+	c.Assert(err, gc.ErrorMatches, `cannot update environment defaults: cannot both set and remove "default-series"`)
+}
@@ -163,7 +163,13 @@ func (st *State) RemoveEnvironmentUser(user names.UserTag) error {
 		Assert: txn.DocExists,
 		Remove: true,
 	}}
-	err := st.runTransaction(ops)
+	// The user's ssh keys are revoked along with their access.
+	keyOps, err := st.removeSSHKeysOps(user.Username())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ops = append(ops, keyOps...)
+	err = st.runTransaction(ops)
 	if err == txn.ErrAborted {
 		err = errors.NewNotFound(err, fmt.Sprintf("env user %q does not exist", user.Username()))
 	}
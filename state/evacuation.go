@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// evacuationDoc records that the units on a machine are to be moved
+// elsewhere before the machine is destroyed.
+type evacuationDoc struct {
+	DocID     string `bson:"_id"`
+	EnvUUID   string `bson:"env-uuid"`
+	MachineId string `bson:"machineid"`
+
+	// Replacements maps the names of the principal units being
+	// evacuated to the names of the units replacing them.
+	Replacements map[string]string `bson:"replacements,omitempty"`
+}
+
+// Evacuation represents the evacuation of the units of a machine.
+type Evacuation struct {
+	st  *State
+	doc evacuationDoc
+}
+
+// MachineId returns the id of the machine being evacuated.
+func (e *Evacuation) MachineId() string {
+	return e.doc.MachineId
+}
+
+// Replacements returns the names of the units replacing the units
+// evacuated so far, keyed by the names of the units they replace.
+func (e *Evacuation) Replacements() map[string]string {
+	result := make(map[string]string)
+	for unit, replacement := range e.doc.Replacements {
+		result[unit] = replacement
+	}
+	return result
+}
+
+// SetReplacement records that the named unit is replaced by another.
+func (e *Evacuation) SetReplacement(unitName, replacementName string) error {
+	ops := []txn.Op{{
+		C:      evacuationsC,
+		Id:     e.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"replacements." + unitName, replacementName}}}},
+	}}
+	if err := e.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("evacuation of machine %s", e.doc.MachineId)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot record replacement for unit %q", unitName)
+	}
+	if e.doc.Replacements == nil {
+		e.doc.Replacements = make(map[string]string)
+	}
+	e.doc.Replacements[unitName] = replacementName
+	return nil
+}
+
+// Remove removes the record of the evacuation. It does nothing if the
+// record has already been removed.
+func (e *Evacuation) Remove() error {
+	ops := []txn.Op{removeEvacuationOp(e.st, e.doc.MachineId)}
+	if err := e.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove evacuation of machine %s", e.doc.MachineId)
+	}
+	return nil
+}
+
+func removeEvacuationOp(st *State, machineId string) txn.Op {
+	return txn.Op{
+		C:      evacuationsC,
+		Id:     st.docID(machineId),
+		Remove: true,
+	}
+}
+
+// Evacuate marks the machine for evacuation: its principal units are
+// to be replaced by units on other machines, and the machine destroyed
+// once the replacements are running. It does nothing if the machine is
+// already being evacuated.
+func (m *Machine) Evacuate() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot evacuate machine %s", m.doc.Id)
+	if m.IsManager() {
+		return fmt.Errorf("machine is required by the environment")
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: isAliveDoc,
+	}, {
+		C:      evacuationsC,
+		Id:     m.doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &evacuationDoc{MachineId: m.doc.Id},
+	}}
+	if err := m.st.runTransaction(ops); err != txn.ErrAborted {
+		return err
+	}
+	if evacuating, err := m.Evacuating(); err != nil {
+		return err
+	} else if evacuating {
+		return nil
+	}
+	return errNotAlive
+}
+
+// Evacuating returns whether the machine is being evacuated.
+func (m *Machine) Evacuating() (bool, error) {
+	evacuations, closer := m.st.getCollection(evacuationsC)
+	defer closer()
+	n, err := evacuations.FindId(m.doc.DocID).Count()
+	if err != nil {
+		return false, errors.Annotatef(err, "cannot check evacuation of machine %s", m.doc.Id)
+	}
+	return n > 0, nil
+}
+
+// Evacuations returns all the evacuations in progress.
+func (st *State) Evacuations() ([]*Evacuation, error) {
+	evacuations, closer := st.getCollection(evacuationsC)
+	defer closer()
+
+	var docs []evacuationDoc
+	if err := evacuations.Find(nil).Sort("machineid").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get evacuations")
+	}
+	result := make([]*Evacuation, len(docs))
+	for i, doc := range docs {
+		result[i] = &Evacuation{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// Evacuation returns the evacuation of the machine with the given id.
+func (st *State) Evacuation(machineId string) (*Evacuation, error) {
+	evacuations, closer := st.getCollection(evacuationsC)
+	defer closer()
+
+	var doc evacuationDoc
+	err := evacuations.FindId(st.docID(machineId)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("evacuation of machine %s", machineId)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get evacuation of machine %s", machineId)
+	}
+	return &Evacuation{st: st, doc: doc}, nil
+}
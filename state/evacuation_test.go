@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type EvacuationSuite struct {
+	ConnSuite
+	machine *state.Machine
+}
+
+var _ = gc.Suite(&EvacuationSuite{})
+
+func (s *EvacuationSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EvacuationSuite) TestEvacuate(c *gc.C) {
+	evacuating, err := s.machine.Evacuating()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuating, jc.IsFalse)
+	_, err = s.State.Evacuation(s.machine.Id())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+	evacuating, err = s.machine.Evacuating()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuating, jc.IsTrue)
+
+	// Evacuating again is fine.
+	err = s.machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+
+	evacuations, err := s.State.Evacuations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuations, gc.HasLen, 1)
+	c.Assert(evacuations[0].MachineId(), gc.Equals, s.machine.Id())
+	c.Assert(evacuations[0].Replacements(), gc.HasLen, 0)
+}
+
+func (s *EvacuationSuite) TestEvacuateManager(c *gc.C) {
+	manager, err := s.State.AddMachine("quantal", state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	err = manager.Evacuate()
+	c.Assert(err, gc.ErrorMatches, "cannot evacuate machine 1: machine is required by the environment")
+}
+
+func (s *EvacuationSuite) TestEvacuateNotAlive(c *gc.C) {
+	err := s.machine.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Evacuate()
+	c.Assert(err, gc.ErrorMatches, "cannot evacuate machine 0: not found or not alive")
+}
+
+func (s *EvacuationSuite) TestSetReplacement(c *gc.C) {
+	err := s.machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+	evacuation, err := s.State.Evacuation(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = evacuation.SetReplacement("wordpress/0", "wordpress/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuation.Replacements(), jc.DeepEquals, map[string]string{"wordpress/0": "wordpress/1"})
+
+	evacuation, err = s.State.Evacuation(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuation.Replacements(), jc.DeepEquals, map[string]string{"wordpress/0": "wordpress/1"})
+
+	err = evacuation.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	err = evacuation.SetReplacement("wordpress/0", "wordpress/2")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = evacuation.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *EvacuationSuite) TestMachineRemoveRemovesEvacuation(c *gc.C) {
+	err := s.machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	evacuations, err := s.State.Evacuations()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(evacuations, gc.HasLen, 0)
+}
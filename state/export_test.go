@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -35,18 +36,22 @@ const (
 )
 
 var (
-	ToolstorageNewStorage  = &toolstorageNewStorage
-	ImageStorageNewStorage = &imageStorageNewStorage
-	MachineIdLessThan      = machineIdLessThan
-	StateServerAvailable   = &stateServerAvailable
-	GetOrCreatePorts       = getOrCreatePorts
-	GetPorts               = getPorts
-	PortsGlobalKey         = portsGlobalKey
-	CurrentUpgradeId       = currentUpgradeId
-	NowToTheSecond         = nowToTheSecond
-	MultiEnvCollections    = multiEnvCollections
-	PickAddress            = &pickAddress
-	AddVolumeOp            = (*State).addVolumeOp
+	ToolstorageNewStorage   = &toolstorageNewStorage
+	ImageStorageNewStorage  = &imageStorageNewStorage
+	MachineIdLessThan       = machineIdLessThan
+	MaxStatusHistoryEntries = &maxStatusHistoryEntries
+	StateServerAvailable    = &stateServerAvailable
+	GetOrCreatePorts        = getOrCreatePorts
+	GetPorts                = getPorts
+	PortsGlobalKey          = portsGlobalKey
+	CurrentUpgradeId        = currentUpgradeId
+	NowToTheSecond          = nowToTheSecond
+	UnitDrainTimeout        = &unitDrainTimeout
+	MultiEnvCollections     = multiEnvCollections
+	PickAddress             = &pickAddress
+	AddVolumeOp             = (*State).addVolumeOp
+	TailTimeout             = &tailTimeout
+	HeartbeatPollInterval   = &heartbeatPollInterval
 )
 
 type (
@@ -58,6 +63,15 @@ type (
 	BlockDevicesDoc blockDevicesDoc
 )
 
+// BackdateAgentHeartbeats makes the agent heartbeats recorded by the
+// given API server appear to have been written at the given time.
+func BackdateAgentHeartbeats(c *gc.C, st *State, serverId string, updated time.Time) {
+	coll, closer := st.getRawCollection(heartbeatsC)
+	defer closer()
+	err := coll.UpdateId(serverId, bson.D{{"$set", bson.D{{"updated", updated}}}})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func SetTestHooks(c *gc.C, st *State, hooks ...jujutxn.TestHook) txntesting.TransactionChecker {
 	return txntesting.SetTestHooks(c, newMultiEnvRunnerForHooks(st), hooks...)
 }
@@ -195,6 +209,7 @@ func GetPasswordHash(e Authenticator) string {
 
 func init() {
 	txnLogSize = txnLogSizeTests
+	logsSize = logsSizeTests
 }
 
 // TxnRevno returns the txn-revno field of the document
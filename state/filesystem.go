@@ -27,6 +27,9 @@ type Filesystem interface {
 	// FilesystemTag returns the tag for the filesystem.
 	FilesystemTag() names.FilesystemTag
 
+	// Life returns the life of the filesystem.
+	Life() Life
+
 	// Storage returns the tag of the storage instance that this
 	// filesystem is assigned to, if any. If the filesystem is not
 	// assigned to a storage instance, an error satisfying
@@ -143,6 +146,11 @@ func (f *filesystem) FilesystemTag() names.FilesystemTag {
 	return names.NewFilesystemTag(f.doc.FilesystemId)
 }
 
+// Life returns the filesystem's current lifecycle state.
+func (f *filesystem) Life() Life {
+	return f.doc.Life
+}
+
 // Storage is required to implement Filesystem.
 func (f *filesystem) Storage() (names.StorageTag, error) {
 	if f.doc.StorageId == "" {
@@ -217,6 +225,27 @@ func (st *State) Filesystem(tag names.FilesystemTag) (Filesystem, error) {
 	return &fs, nil
 }
 
+// Filesystems returns up to limit filesystems in the environment whose
+// IDs sort after the given ID, in order of ID. If limit is zero, all
+// such filesystems are returned. Callers may page through all
+// filesystems by passing the ID of the last filesystem returned as the
+// next call's after.
+func (st *State) Filesystems(after string, limit int) ([]Filesystem, error) {
+	coll, cleanup := st.getCollection(filesystemsC)
+	defer cleanup()
+
+	var docs []filesystemDoc
+	query := coll.Find(bson.D{{"filesystemid", bson.D{{"$gt", after}}}}).Sort("filesystemid")
+	if err := query.Limit(limit).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get filesystems")
+	}
+	filesystems := make([]Filesystem, len(docs))
+	for i, doc := range docs {
+		filesystems[i] = &filesystem{doc}
+	}
+	return filesystems, nil
+}
+
 // StorageInstanceFilesystem returns the Filesystem assigned to the specified
 // storage instance.
 func (st *State) StorageInstanceFilesystem(tag names.StorageTag) (Filesystem, error) {
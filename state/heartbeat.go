@@ -0,0 +1,181 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state/presence"
+)
+
+// AgentHeartbeatInterval is how often each API server records the
+// agents connected to it.
+const AgentHeartbeatInterval = 30 * time.Second
+
+// agentHeartbeatTimeout is how long an API server's record of the
+// agents connected to it is trusted. It allows for a missed write,
+// and for some clock skew between state servers.
+const agentHeartbeatTimeout = 2*AgentHeartbeatInterval + AgentHeartbeatInterval/2
+
+// heartbeatPollInterval is how often WaitAgentPresence checks the
+// recorded heartbeats.
+var heartbeatPollInterval = 250 * time.Millisecond
+
+// AgentHeartbeat records when an agent known to an API server was
+// last heard from.
+type AgentHeartbeat struct {
+	// EnvUUID identifies the environment the agent belongs to.
+	EnvUUID string
+
+	// Agent holds the tag of the machine or unit the agent runs.
+	Agent names.Tag
+
+	// LastSeen holds the time of the agent's most recent request.
+	LastSeen time.Time
+
+	// Connected reports whether the agent is connected to the API
+	// server.
+	Connected bool
+}
+
+// heartbeatDoc records the agents known to a single API server.
+// Each API server replaces its document wholesale every
+// AgentHeartbeatInterval, so however many agents are connected,
+// each server makes a single write per interval.
+type heartbeatDoc struct {
+	DocID   string              `bson:"_id"`
+	Updated time.Time           `bson:"updated"`
+	Agents  []heartbeatAgentDoc `bson:"agents"`
+}
+
+type heartbeatAgentDoc struct {
+	EnvUUID   string    `bson:"env-uuid"`
+	Agent     string    `bson:"agent"`
+	LastSeen  time.Time `bson:"last-seen"`
+	Connected bool      `bson:"connected"`
+}
+
+// SetAgentHeartbeats replaces the record of the agents known to the
+// API server with the given id.
+func (st *State) SetAgentHeartbeats(serverId string, heartbeats []AgentHeartbeat) error {
+	agents := make([]heartbeatAgentDoc, len(heartbeats))
+	for i, hb := range heartbeats {
+		agents[i] = heartbeatAgentDoc{
+			EnvUUID:   hb.EnvUUID,
+			Agent:     hb.Agent.String(),
+			LastSeen:  hb.LastSeen.UTC(),
+			Connected: hb.Connected,
+		}
+	}
+	coll, closer := st.getRawCollection(heartbeatsC)
+	defer closer()
+	_, err := coll.UpsertId(serverId, bson.D{{"$set", bson.D{
+		{"updated", time.Now().UTC()},
+		{"agents", agents},
+	}}})
+	if err != nil {
+		return errors.Annotatef(err, "cannot record agent heartbeats for API server %q", serverId)
+	}
+	return nil
+}
+
+// agentHeartbeat returns when the agent with the given tag was last
+// heard from by any API server, and whether it is connected to one.
+// It returns a NotFound error if no API server knows of the agent.
+func (st *State) agentHeartbeat(tag names.Tag) (AgentHeartbeat, error) {
+	heartbeats, closer := st.getRawCollection(heartbeatsC)
+	defer closer()
+	// Only the matching entry of each server's agents is fetched.
+	query := bson.D{{"agents", bson.D{{"$elemMatch", bson.D{
+		{"env-uuid", st.EnvironUUID()},
+		{"agent", tag.String()},
+	}}}}}
+	var docs []heartbeatDoc
+	err := heartbeats.Find(query).Select(bson.D{
+		{"updated", 1},
+		{"agents.$", 1},
+	}).All(&docs)
+	if err != nil {
+		return AgentHeartbeat{}, errors.Annotatef(err, "cannot get heartbeats for agent %q", tag)
+	}
+	result := AgentHeartbeat{
+		EnvUUID: st.EnvironUUID(),
+		Agent:   tag,
+	}
+	found := false
+	for _, doc := range docs {
+		if len(doc.Agents) == 0 {
+			continue
+		}
+		agent := doc.Agents[0]
+		found = true
+		if agent.LastSeen.After(result.LastSeen) {
+			result.LastSeen = agent.LastSeen
+		}
+		if agent.Connected && time.Since(doc.Updated) < agentHeartbeatTimeout {
+			result.Connected = true
+		}
+	}
+	if !found {
+		return AgentHeartbeat{}, errors.NotFoundf("heartbeats for agent %q", tag)
+	}
+	return result, nil
+}
+
+// agentPresence reports whether the agent with the given tag is
+// connected to an API server. Agents connected to API servers that
+// predate heartbeats are still reported by presence pingers, under
+// the given presence key.
+func (st *State) agentPresence(tag names.Tag, presenceKey string) (bool, error) {
+	hb, err := st.agentHeartbeat(tag)
+	if err == nil && hb.Connected {
+		return true, nil
+	} else if err != nil && !errors.IsNotFound(err) {
+		return false, errors.Trace(err)
+	}
+	return st.pwatcher.Alive(presenceKey)
+}
+
+// agentLastSeen returns when the agent with the given tag was last
+// heard from by an API server.
+func (st *State) agentLastSeen(tag names.Tag) (time.Time, error) {
+	hb, err := st.agentHeartbeat(tag)
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	return hb.LastSeen, nil
+}
+
+// waitAgentPresence blocks until the agent with the given tag and
+// presence key is alive, or the timeout expires.
+func (st *State) waitAgentPresence(tag names.Tag, presenceKey string, timeout time.Duration) error {
+	ch := make(chan presence.Change)
+	st.pwatcher.Watch(presenceKey, ch)
+	defer st.pwatcher.Unwatch(presenceKey, ch)
+	deadline := time.After(timeout)
+	for {
+		hb, err := st.agentHeartbeat(tag)
+		if err == nil && hb.Connected {
+			return nil
+		} else if err != nil && !errors.IsNotFound(err) {
+			return errors.Trace(err)
+		}
+		select {
+		case change := <-ch:
+			if change.Alive {
+				return nil
+			}
+		case <-time.After(heartbeatPollInterval):
+		case <-deadline:
+			return fmt.Errorf("still not alive after timeout")
+		case <-st.pwatcher.Dead():
+			return st.pwatcher.Err()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type HeartbeatSuite struct {
+	ConnSuite
+	machine *state.Machine
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&HeartbeatSuite{})
+
+func (s *HeartbeatSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine = machine
+	s.unit = unit
+}
+
+func (s *HeartbeatSuite) setHeartbeat(c *gc.C, serverId string, lastSeen time.Time, connected bool) {
+	err := s.State.SetAgentHeartbeats(serverId, []state.AgentHeartbeat{{
+		EnvUUID:   s.State.EnvironUUID(),
+		Agent:     s.machine.Tag(),
+		LastSeen:  lastSeen,
+		Connected: connected,
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *HeartbeatSuite) assertAlive(c *gc.C, expect bool) {
+	alive, err := s.machine.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alive, gc.Equals, expect)
+}
+
+func (s *HeartbeatSuite) TestNoHeartbeats(c *gc.C) {
+	s.assertAlive(c, false)
+	_, err := s.machine.AgentLastSeen()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *HeartbeatSuite) TestConnected(c *gc.C) {
+	lastSeen := time.Now().Add(-time.Minute).Round(time.Second)
+	s.setHeartbeat(c, "0", lastSeen, true)
+	s.assertAlive(c, true)
+	seen, err := s.machine.AgentLastSeen()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(seen.Equal(lastSeen), jc.IsTrue)
+
+	// Other agents are unaffected.
+	alive, err := s.unit.AgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alive, jc.IsFalse)
+	_, err = s.unit.AgentLastSeen()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *HeartbeatSuite) TestDisconnected(c *gc.C) {
+	s.setHeartbeat(c, "0", time.Now(), true)
+	s.assertAlive(c, true)
+
+	lastSeen := time.Now().Round(time.Second)
+	s.setHeartbeat(c, "0", lastSeen, false)
+	s.assertAlive(c, false)
+	seen, err := s.machine.AgentLastSeen()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(seen.Equal(lastSeen), jc.IsTrue)
+}
+
+func (s *HeartbeatSuite) TestStaleHeartbeats(c *gc.C) {
+	s.setHeartbeat(c, "0", time.Now(), true)
+	state.BackdateAgentHeartbeats(c, s.State, "0", time.Now().Add(-time.Hour))
+	s.assertAlive(c, false)
+}
+
+func (s *HeartbeatSuite) TestMultipleServers(c *gc.C) {
+	older := time.Now().Add(-time.Hour).Round(time.Second)
+	newer := time.Now().Round(time.Second)
+	s.setHeartbeat(c, "0", newer, false)
+	s.setHeartbeat(c, "1", older, true)
+	s.assertAlive(c, true)
+	seen, err := s.machine.AgentLastSeen()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(seen.Equal(newer), jc.IsTrue)
+}
+
+func (s *HeartbeatSuite) TestWaitAgentPresence(c *gc.C) {
+	s.PatchValue(state.HeartbeatPollInterval, 10*time.Millisecond)
+	err := s.machine.WaitAgentPresence(coretesting.ShortWait)
+	c.Assert(err, gc.ErrorMatches, `waiting for agent of machine 0: still not alive after timeout`)
+
+	go func() {
+		time.Sleep(coretesting.ShortWait)
+		err := s.State.SetAgentHeartbeats("0", []state.AgentHeartbeat{{
+			EnvUUID:   s.State.EnvironUUID(),
+			Agent:     s.machine.Tag(),
+			LastSeen:  time.Now(),
+			Connected: true,
+		}})
+		c.Check(err, jc.ErrorIsNil)
+	}()
+	err = s.machine.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -0,0 +1,230 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// QueuedHook describes a hook that a unit's agent has yet to run.
+type QueuedHook struct {
+	// Kind is the kind of the hook, such as "config-changed" or
+	// "relation-changed".
+	Kind string `bson:"kind"`
+
+	// RelationId identifies the relation of a relation hook.
+	RelationId int `bson:"relationid"`
+
+	// RemoteUnit is the name of the unit that triggered a relation
+	// hook, if any.
+	RemoteUnit string `bson:"remoteunit,omitempty"`
+}
+
+// IsRelation returns whether the hook is a relation hook.
+func (h QueuedHook) IsRelation() bool {
+	return strings.HasPrefix(h.Kind, "relation-")
+}
+
+// Id returns a string that identifies the hook in its unit's queue.
+func (h QueuedHook) Id() string {
+	if !h.IsRelation() {
+		return h.Kind
+	}
+	id := fmt.Sprintf("%s:%d", h.Kind, h.RelationId)
+	if h.RemoteUnit != "" {
+		id += ":" + h.RemoteUnit
+	}
+	return id
+}
+
+// HookQueue holds the hooks a unit's agent last reported it has yet
+// to run.
+type HookQueue struct {
+	// Pending holds the hook that failed, and awaits resolution
+	// before any queued hook can run, if any.
+	Pending *QueuedHook
+
+	// Queued holds the hooks waiting to run, in the order the agent
+	// will run them.
+	Queued []QueuedHook
+
+	// Dropped holds the queued hooks that the agent has been asked
+	// not to run.
+	Dropped []QueuedHook
+}
+
+// hookQueueDoc records the hooks that a unit's agent has yet to run.
+// The document is created when the agent first reports its queue.
+type hookQueueDoc struct {
+	DocID    string       `bson:"_id"`
+	EnvUUID  string       `bson:"env-uuid"`
+	Pending  *QueuedHook  `bson:"pending,omitempty"`
+	Queued   []QueuedHook `bson:"queued"`
+	Dropped  []string     `bson:"dropped"`
+	TxnRevno int64        `bson:"txn-revno"`
+}
+
+func (u *Unit) getHookQueueDoc() (*hookQueueDoc, error) {
+	hookQueues, closer := u.st.getCollection(hookQueuesC)
+	defer closer()
+	var doc hookQueueDoc
+	err := hookQueues.FindId(u.globalKey()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &doc, nil
+}
+
+// HookQueue returns the hooks the unit's agent last reported it has
+// yet to run.
+func (u *Unit) HookQueue() (HookQueue, error) {
+	doc, err := u.getHookQueueDoc()
+	if err != nil {
+		return HookQueue{}, errors.Annotatef(err, "cannot get hook queue for unit %q", u)
+	}
+	if doc == nil {
+		return HookQueue{}, nil
+	}
+	queue := HookQueue{
+		Pending: doc.Pending,
+		Queued:  doc.Queued,
+	}
+	dropped := make(map[string]bool)
+	for _, id := range doc.Dropped {
+		dropped[id] = true
+	}
+	for _, hook := range doc.Queued {
+		if dropped[hook.Id()] {
+			queue.Dropped = append(queue.Dropped, hook)
+		}
+	}
+	return queue, nil
+}
+
+// SetHookQueue records the hooks the unit's agent has yet to run.
+// Requests to drop hooks that are no longer queued are discarded.
+func (u *Unit) SetHookQueue(pending *QueuedHook, queued []QueuedHook) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set hook queue for unit %q", u)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); errors.IsNotFound(err) {
+				return nil, ErrDead
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if u.doc.Life == Dead {
+				return nil, ErrDead
+			}
+		}
+		doc, err := u.getHookQueueDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		unitOp := txn.Op{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: notDeadDoc,
+		}
+		if doc == nil {
+			return []txn.Op{unitOp, {
+				C:      hookQueuesC,
+				Id:     u.st.docID(u.globalKey()),
+				Assert: txn.DocMissing,
+				Insert: &hookQueueDoc{
+					EnvUUID: u.st.EnvironUUID(),
+					Pending: pending,
+					Queued:  queued,
+					Dropped: []string{},
+				},
+			}}, nil
+		}
+		stillQueued := make(map[string]bool)
+		for _, hook := range queued {
+			stillQueued[hook.Id()] = true
+		}
+		dropped := []string{}
+		for _, id := range doc.Dropped {
+			if stillQueued[id] {
+				dropped = append(dropped, id)
+			}
+		}
+		set := bson.D{{"queued", queued}, {"dropped", dropped}}
+		update := bson.D{{"$set", set}, {"$unset", bson.D{{"pending", nil}}}}
+		if pending != nil {
+			update = bson.D{{"$set", append(set, bson.DocElem{"pending", pending})}}
+		}
+		return []txn.Op{unitOp, {
+			C:      hookQueuesC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: update,
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// DropQueuedHook asks the unit's agent not to run the queued hook
+// with the given id. Only relation hooks can be dropped; the agent
+// records them as run without running them.
+func (u *Unit) DropQueuedHook(id string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot drop hook %q for unit %q", id, u)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := u.getHookQueueDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		var hook *QueuedHook
+		if doc != nil {
+			for i := range doc.Queued {
+				if doc.Queued[i].Id() == id {
+					hook = &doc.Queued[i]
+					break
+				}
+			}
+		}
+		if hook == nil {
+			return nil, errors.NotFoundf("queued hook %q", id)
+		}
+		if !hook.IsRelation() {
+			return nil, errors.NotSupportedf("dropping %s hooks", hook.Kind)
+		}
+		for _, dropped := range doc.Dropped {
+			if dropped == id {
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		return []txn.Op{{
+			C:      hookQueuesC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$addToSet", bson.D{{"dropped", id}}}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// WatchHookQueue returns a watcher observing changes to the hooks
+// recorded as queued for the unit, and to requests to drop them.
+func (u *Unit) WatchHookQueue() NotifyWatcher {
+	return newEntityWatcher(u.st, hookQueuesC, u.st.docID(u.globalKey()))
+}
+
+// removeHookQueueOp returns the operation needed to remove the hook
+// queue document associated with the given globalKey, if it exists.
+func removeHookQueueOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      hookQueuesC,
+		Id:     st.docID(globalKey),
+		Remove: true,
+	}
+}
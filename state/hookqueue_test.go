@@ -0,0 +1,137 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type HookQueueSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&HookQueueSuite{})
+
+func (s *HookQueueSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.unit = unit
+}
+
+var (
+	configChanged   = state.QueuedHook{Kind: "config-changed"}
+	relationChanged = state.QueuedHook{Kind: "relation-changed", RelationId: 1, RemoteUnit: "wordpress/0"}
+	relationBroken  = state.QueuedHook{Kind: "relation-broken", RelationId: 2}
+)
+
+func (s *HookQueueSuite) TestQueuedHookId(c *gc.C) {
+	c.Assert(configChanged.Id(), gc.Equals, "config-changed")
+	c.Assert(relationChanged.Id(), gc.Equals, "relation-changed:1:wordpress/0")
+	c.Assert(relationBroken.Id(), gc.Equals, "relation-broken:2")
+}
+
+func (s *HookQueueSuite) TestHookQueueNotReported(c *gc.C) {
+	queue, err := s.unit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue, jc.DeepEquals, state.HookQueue{})
+}
+
+func (s *HookQueueSuite) TestSetHookQueue(c *gc.C) {
+	err := s.unit.SetHookQueue(&configChanged, []state.QueuedHook{relationChanged, relationBroken})
+	c.Assert(err, jc.ErrorIsNil)
+	queue, err := s.unit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue, jc.DeepEquals, state.HookQueue{
+		Pending: &configChanged,
+		Queued:  []state.QueuedHook{relationChanged, relationBroken},
+	})
+
+	err = s.unit.SetHookQueue(nil, []state.QueuedHook{relationBroken})
+	c.Assert(err, jc.ErrorIsNil)
+	queue, err = s.unit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue, jc.DeepEquals, state.HookQueue{
+		Queued: []state.QueuedHook{relationBroken},
+	})
+}
+
+func (s *HookQueueSuite) TestSetHookQueueDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetHookQueue(nil, []state.QueuedHook{relationBroken})
+	c.Assert(err, gc.ErrorMatches, `cannot set hook queue for unit "mysql/0": not found or dead`)
+}
+
+func (s *HookQueueSuite) TestDropQueuedHook(c *gc.C) {
+	err := s.unit.SetHookQueue(&configChanged, []state.QueuedHook{relationChanged, relationBroken})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.unit.DropQueuedHook("relation-changed:1:wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	// Dropping a hook twice is not an error.
+	err = s.unit.DropQueuedHook("relation-changed:1:wordpress/0")
+	c.Assert(err, jc.ErrorIsNil)
+	queue, err := s.unit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue.Dropped, jc.DeepEquals, []state.QueuedHook{relationChanged})
+
+	// Once the agent reports the hook is no longer queued, the
+	// request to drop it is discarded.
+	err = s.unit.SetHookQueue(nil, []state.QueuedHook{relationBroken})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetHookQueue(nil, []state.QueuedHook{relationChanged, relationBroken})
+	c.Assert(err, jc.ErrorIsNil)
+	queue, err = s.unit.HookQueue()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(queue.Dropped, gc.HasLen, 0)
+}
+
+func (s *HookQueueSuite) TestDropQueuedHookErrors(c *gc.C) {
+	err := s.unit.DropQueuedHook("relation-changed:1:wordpress/0")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.unit.SetHookQueue(nil, []state.QueuedHook{configChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.DropQueuedHook("config-changed")
+	c.Assert(err, gc.ErrorMatches, `cannot drop hook "config-changed" for unit "mysql/0": dropping config-changed hooks not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *HookQueueSuite) TestWatchHookQueue(c *gc.C) {
+	w := s.unit.WatchHookQueue()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.unit.SetHookQueue(nil, []state.QueuedHook{relationChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.unit.DropQueuedHook(relationChanged.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *HookQueueSuite) TestRemoveUnitRemovesHookQueue(c *gc.C) {
+	err := s.unit.SetHookQueue(nil, []state.QueuedHook{relationChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+
+	queues, closer := state.GetCollection(s.State, "hookqueues")
+	defer closer()
+	count, err := queues.Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// maxHookOutputSize is the largest hook output tail recorded in
+// state, whatever the limit the unit's agent was configured with.
+const maxHookOutputSize = 1024 * 1024
+
+// HookResult describes the outcome of the hook a unit's agent ran
+// most recently.
+type HookResult struct {
+	// Hook holds the name of the hook, such as "config-changed" or
+	// "db-relation-changed".
+	Hook string
+
+	// Time holds the time at which the hook finished.
+	Time time.Time
+
+	// Error holds the reason the hook failed, or "" if it succeeded.
+	Error string
+
+	// Output holds the tail of the output the hook wrote to its
+	// standard output and standard error.
+	Output string
+
+	// Truncated holds whether earlier output was discarded.
+	Truncated bool
+}
+
+// hookResultDoc records the outcome of the hook a unit's agent ran
+// most recently. The document is created when the agent first reports
+// a hook's result.
+type hookResultDoc struct {
+	DocID     string    `bson:"_id"`
+	EnvUUID   string    `bson:"env-uuid"`
+	Hook      string    `bson:"hook"`
+	Time      time.Time `bson:"time"`
+	Error     string    `bson:"error,omitempty"`
+	Output    string    `bson:"output"`
+	Truncated bool      `bson:"truncated,omitempty"`
+}
+
+// LastHookResult returns the outcome of the hook the unit's agent
+// ran most recently. If the agent has not reported any, an error
+// satisfying errors.IsNotFound is returned.
+func (u *Unit) LastHookResult() (HookResult, error) {
+	hookResults, closer := u.st.getCollection(hookResultsC)
+	defer closer()
+	var doc hookResultDoc
+	err := hookResults.FindId(u.globalKey()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return HookResult{}, errors.NotFoundf("hook result for unit %q", u)
+	} else if err != nil {
+		return HookResult{}, errors.Annotatef(err, "cannot get hook result for unit %q", u)
+	}
+	return HookResult{
+		Hook:      doc.Hook,
+		Time:      doc.Time.UTC(),
+		Error:     doc.Error,
+		Output:    doc.Output,
+		Truncated: doc.Truncated,
+	}, nil
+}
+
+// SetHookResult records the outcome of the hook the unit's agent ran
+// most recently, replacing the outcome of the hook it ran before. Only
+// the tail of an overly long output is kept.
+func (u *Unit) SetHookResult(result HookResult) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set hook result for unit %q", u)
+	if result.Hook == "" {
+		return errors.NotValidf("empty hook name")
+	}
+	if len(result.Output) > maxHookOutputSize {
+		result.Output = result.Output[len(result.Output)-maxHookOutputSize:]
+		result.Truncated = true
+	}
+	if result.Time.IsZero() {
+		result.Time = nowToTheSecond()
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); errors.IsNotFound(err) {
+				return nil, ErrDead
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if u.doc.Life == Dead {
+				return nil, ErrDead
+			}
+		}
+		unitOp := txn.Op{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: notDeadDoc,
+		}
+		hookResults, closer := u.st.getCollection(hookResultsC)
+		defer closer()
+		count, err := hookResults.FindId(u.globalKey()).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if count == 0 {
+			return []txn.Op{unitOp, {
+				C:      hookResultsC,
+				Id:     u.st.docID(u.globalKey()),
+				Assert: txn.DocMissing,
+				Insert: &hookResultDoc{
+					EnvUUID:   u.st.EnvironUUID(),
+					Hook:      result.Hook,
+					Time:      result.Time,
+					Error:     result.Error,
+					Output:    result.Output,
+					Truncated: result.Truncated,
+				},
+			}}, nil
+		}
+		return []txn.Op{unitOp, {
+			C:      hookResultsC,
+			Id:     u.st.docID(u.globalKey()),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"hook", result.Hook},
+				{"time", result.Time},
+				{"error", result.Error},
+				{"output", result.Output},
+				{"truncated", result.Truncated},
+			}}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// removeHookResultOp returns the operation needed to remove the hook
+// result document associated with the given globalKey, if it exists.
+func removeHookResultOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      hookResultsC,
+		Id:     st.docID(globalKey),
+		Remove: true,
+	}
+}
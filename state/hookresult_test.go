@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type HookResultSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&HookResultSuite{})
+
+func (s *HookResultSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.unit = unit
+}
+
+func (s *HookResultSuite) TestLastHookResultNotReported(c *gc.C) {
+	_, err := s.unit.LastHookResult()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `hook result for unit "mysql/0" not found`)
+}
+
+func (s *HookResultSuite) TestSetHookResult(c *gc.C) {
+	finished := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	failed := state.HookResult{
+		Hook:   "config-changed",
+		Time:   finished,
+		Error:  "exit status 1",
+		Output: "cannot write config\n",
+	}
+	err := s.unit.SetHookResult(failed)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.unit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, failed)
+
+	// A later result replaces the earlier one.
+	succeeded := state.HookResult{
+		Hook:   "config-changed",
+		Time:   finished.Add(time.Minute),
+		Output: "config written\n",
+	}
+	err = s.unit.SetHookResult(succeeded)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err = s.unit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, succeeded)
+}
+
+func (s *HookResultSuite) TestSetHookResultDefaultsTime(c *gc.C) {
+	before := time.Now().Add(-time.Second)
+	err := s.unit.SetHookResult(state.HookResult{Hook: "start"})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.unit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Time.After(before), jc.IsTrue)
+}
+
+func (s *HookResultSuite) TestSetHookResultTruncatesOutput(c *gc.C) {
+	output := strings.Repeat("x", 1024*1024) + "tail"
+	err := s.unit.SetHookResult(state.HookResult{Hook: "install", Output: output})
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.unit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Output, gc.HasLen, 1024*1024)
+	c.Assert(strings.HasSuffix(result.Output, "tail"), jc.IsTrue)
+	c.Assert(result.Truncated, jc.IsTrue)
+}
+
+func (s *HookResultSuite) TestSetHookResultInvalid(c *gc.C) {
+	err := s.unit.SetHookResult(state.HookResult{})
+	c.Assert(err, gc.ErrorMatches, `cannot set hook result for unit "mysql/0": empty hook name not valid`)
+}
+
+func (s *HookResultSuite) TestSetHookResultDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetHookResult(state.HookResult{Hook: "stop"})
+	c.Assert(err, gc.ErrorMatches, `cannot set hook result for unit "mysql/0": not found or dead`)
+}
+
+func (s *HookResultSuite) TestRemoveUnitRemovesHookResult(c *gc.C) {
+	err := s.unit.SetHookResult(state.HookResult{Hook: "stop"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.unit.LastHookResult()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// instanceMetadataId is the id of the document caching the instance
+// types and images offered by an environment's provider.
+const instanceMetadataId = "instancemetadata"
+
+// InstanceType describes an instance type offered by a provider.
+type InstanceType struct {
+	Name     string   `bson:"name"`
+	Arches   []string `bson:"arches"`
+	CpuCores uint64   `bson:"cpu-cores"`
+	CpuPower *uint64  `bson:"cpu-power,omitempty"`
+	Mem      uint64   `bson:"mem"`
+	RootDisk uint64   `bson:"root-disk,omitempty"`
+	Cost     uint64   `bson:"cost,omitempty"`
+	VirtType string   `bson:"virt-type,omitempty"`
+	Tags     []string `bson:"tags,omitempty"`
+	Gpus     uint64   `bson:"gpus,omitempty"`
+	GpuType  string   `bson:"gpu-type,omitempty"`
+}
+
+// CloudImage describes an image offered by a provider.
+type CloudImage struct {
+	Id       string `bson:"id"`
+	Series   string `bson:"series"`
+	Arch     string `bson:"arch"`
+	VirtType string `bson:"virt-type,omitempty"`
+}
+
+// InstanceMetadata holds the instance types and images offered by an
+// environment's provider, as cached by the state server so they may be
+// consulted without calling the provider.
+type InstanceMetadata struct {
+	InstanceTypes []InstanceType
+	Images        []CloudImage
+	// Updated is when the provider was last asked for the instance
+	// types and images.
+	Updated time.Time
+}
+
+type instanceMetadataDoc struct {
+	DocID         string         `bson:"_id"`
+	EnvUUID       string         `bson:"env-uuid"`
+	InstanceTypes []InstanceType `bson:"instance-types"`
+	Images        []CloudImage   `bson:"images"`
+	Updated       time.Time      `bson:"updated"`
+}
+
+// InstanceMetadata returns the cached instance types and images offered
+// by the environment's provider. It returns an error satisfying
+// errors.IsNotFound if they have not yet been cached.
+func (st *State) InstanceMetadata() (InstanceMetadata, error) {
+	coll, closer := st.getCollection(instanceMetadataC)
+	defer closer()
+
+	var doc instanceMetadataDoc
+	err := coll.FindId(instanceMetadataId).One(&doc)
+	if err == mgo.ErrNotFound {
+		return InstanceMetadata{}, errors.NotFoundf("instance metadata")
+	} else if err != nil {
+		return InstanceMetadata{}, errors.Annotate(err, "cannot get instance metadata")
+	}
+	return InstanceMetadata{
+		InstanceTypes: doc.InstanceTypes,
+		Images:        doc.Images,
+		Updated:       doc.Updated,
+	}, nil
+}
+
+// UpdateInstanceMetadata replaces the cached instance types and images
+// offered by the environment's provider.
+func (st *State) UpdateInstanceMetadata(metadata InstanceMetadata) error {
+	docID := st.docID(instanceMetadataId)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		_, err := st.InstanceMetadata()
+		if errors.IsNotFound(err) {
+			return []txn.Op{{
+				C:      instanceMetadataC,
+				Id:     docID,
+				Assert: txn.DocMissing,
+				Insert: &instanceMetadataDoc{
+					DocID:         docID,
+					EnvUUID:       st.EnvironUUID(),
+					InstanceTypes: metadata.InstanceTypes,
+					Images:        metadata.Images,
+					Updated:       metadata.Updated,
+				},
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []txn.Op{{
+			C:      instanceMetadataC,
+			Id:     docID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"instance-types", metadata.InstanceTypes},
+				{"images", metadata.Images},
+				{"updated", metadata.Updated},
+			}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot update instance metadata")
+	}
+	return nil
+}
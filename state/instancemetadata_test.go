@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type InstanceMetadataSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&InstanceMetadataSuite{})
+
+func (s *InstanceMetadataSuite) TestInstanceMetadataNotCached(c *gc.C) {
+	_, err := s.State.InstanceMetadata()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, "instance metadata not found")
+}
+
+func (s *InstanceMetadataSuite) TestUpdateInstanceMetadata(c *gc.C) {
+	cpuPower := uint64(100)
+	metadata := state.InstanceMetadata{
+		InstanceTypes: []state.InstanceType{{
+			Name:     "small",
+			Arches:   []string{"amd64", "i386"},
+			CpuCores: 1,
+			CpuPower: &cpuPower,
+			Mem:      1740,
+			Cost:     44,
+			VirtType: "pv",
+		}},
+		Images: []state.CloudImage{{
+			Id:     "ami-123",
+			Series: "trusty",
+			Arch:   "amd64",
+		}},
+		Updated: time.Date(2015, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+	err := s.State.UpdateInstanceMetadata(metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertInstanceMetadata(c, metadata)
+
+	metadata.InstanceTypes = append(metadata.InstanceTypes, state.InstanceType{
+		Name:     "large",
+		Arches:   []string{"amd64"},
+		CpuCores: 4,
+		Mem:      15360,
+		Cost:     175,
+	})
+	metadata.Images = nil
+	metadata.Updated = metadata.Updated.Add(time.Hour)
+	err = s.State.UpdateInstanceMetadata(metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertInstanceMetadata(c, metadata)
+}
+
+func (s *InstanceMetadataSuite) TestInstanceMetadataPerEnvironment(c *gc.C) {
+	err := s.State.UpdateInstanceMetadata(state.InstanceMetadata{
+		InstanceTypes: []state.InstanceType{{Name: "small", Arches: []string{"amd64"}}},
+		Updated:       time.Now(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	_, err = st.InstanceMetadata()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *InstanceMetadataSuite) assertInstanceMetadata(c *gc.C, expect state.InstanceMetadata) {
+	metadata, err := s.State.InstanceMetadata()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(metadata.Updated.Equal(expect.Updated), jc.IsTrue)
+	c.Assert(metadata.InstanceTypes, jc.DeepEquals, expect.InstanceTypes)
+	c.Assert(metadata.Images, jc.DeepEquals, expect.Images)
+}
@@ -4,6 +4,8 @@
 package state
 
 import (
+	"time"
+
 	"github.com/juju/names"
 
 	"github.com/juju/juju/environs/config"
@@ -179,6 +181,11 @@ type ActionReceiver interface {
 	// ActionReceiver.
 	AddAction(name string, payload map[string]interface{}) (*Action, error)
 
+	// ScheduleAction schedules an action with the given name and
+	// payload to be queued for this ActionReceiver once at the given
+	// time, or repeatedly according to the given cron expression.
+	ScheduleAction(name string, payload map[string]interface{}, at time.Time, cronExpr string) (*ActionSchedule, error)
+
 	// CancelAction removes a pending Action from the queue for this
 	// ActionReceiver and marks it as cancelled.
 	CancelAction(action *Action) (*Action, error)
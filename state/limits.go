@@ -0,0 +1,209 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+	"gopkg.in/yaml.v1"
+)
+
+// ReadRelationLimits reads the limits declared explicitly on the
+// relations in a charm's metadata.yaml, keyed on relation name.
+// Relations without a declared limit, or with a limit of zero, are
+// unlimited and omitted from the result. The charm package gives
+// every requires and peers relation a default limit of one, which
+// is why the limits must be read from the raw metadata.
+func ReadRelationLimits(r io.Reader) (map[string]int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var raw struct {
+		Provides map[string]interface{}
+		Requires map[string]interface{}
+		Peers    map[string]interface{}
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Annotate(err, "cannot parse relation limits")
+	}
+	limits := make(map[string]int)
+	for _, relations := range []map[string]interface{}{raw.Provides, raw.Requires, raw.Peers} {
+		for name, relation := range relations {
+			// A relation given as a bare interface name has no limit.
+			attrs, ok := relation.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			value, ok := attrs["limit"]
+			if !ok || value == nil {
+				continue
+			}
+			limit, ok := value.(int)
+			if !ok || limit < 0 {
+				return nil, errors.Errorf("invalid limit %v for relation %q", value, name)
+			}
+			if limit > 0 {
+				limits[name] = limit
+			}
+		}
+	}
+	return limits, nil
+}
+
+// charmRelationLimits returns the relation limits declared by the
+// given charm, if it is read from a directory or an archive.
+func charmRelationLimits(ch charm.Charm) (map[string]int, error) {
+	r, err := openCharmFile(ch, "metadata.yaml")
+	if err != nil || r == nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Close()
+	return ReadRelationLimits(r)
+}
+
+// RelationLimitError is returned when adding a relation would give a
+// service more relations on one of its endpoints than the service's
+// charm allows.
+type RelationLimitError struct {
+	ServiceName  string
+	RelationName string
+	Limit        int
+}
+
+func (e *RelationLimitError) Error() string {
+	return fmt.Sprintf("service %q already has the maximum of %d relation(s) on %q",
+		e.ServiceName, e.Limit, e.RelationName)
+}
+
+// IsRelationLimitError returns whether err is a RelationLimitError.
+func IsRelationLimitError(err error) bool {
+	_, ok := errors.Cause(err).(*RelationLimitError)
+	return ok
+}
+
+// UnitLimitError is returned when adding a unit would give a service
+// more units than its maximum.
+type UnitLimitError struct {
+	ServiceName string
+	Limit       int
+}
+
+func (e *UnitLimitError) Error() string {
+	return fmt.Sprintf("service %q already has the maximum of %d unit(s)", e.ServiceName, e.Limit)
+}
+
+// IsUnitLimitError returns whether err is a UnitLimitError.
+func IsUnitLimitError(err error) bool {
+	_, ok := errors.Cause(err).(*UnitLimitError)
+	return ok
+}
+
+// checkRelationLimit returns a RelationLimitError if the given service
+// already has as many relations on the given endpoint as its charm
+// allows.
+func checkRelationLimit(svc *Service, ch *Charm, ep Endpoint) error {
+	limit := ch.RelationLimits()[ep.Relation.Name]
+	if limit == 0 {
+		return nil
+	}
+	relations, err := serviceRelations(svc.st, svc.doc.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	count := 0
+	for _, rel := range relations {
+		relEp, err := rel.Endpoint(svc.doc.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if relEp.Relation.Name == ep.Relation.Name {
+			count++
+		}
+	}
+	if count >= limit {
+		return &RelationLimitError{
+			ServiceName:  svc.doc.Name,
+			RelationName: ep.Relation.Name,
+			Limit:        limit,
+		}
+	}
+	return nil
+}
+
+// MaxUnits returns the maximum number of units the service may have.
+// Zero means the number of units is not limited.
+func (s *Service) MaxUnits() int {
+	return s.doc.MaxUnits
+}
+
+// SetMaxUnits changes the maximum number of units the service may
+// have. Zero removes the limit. The maximum cannot be set below the
+// number of units the service already has.
+func (s *Service) SetMaxUnits(maxUnits int) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set maximum units for service %q", s)
+	if maxUnits < 0 {
+		return errors.New("cannot set a negative maximum number of units")
+	}
+	service := &Service{st: s.st, doc: s.doc}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := service.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if service.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if maxUnits == service.doc.MaxUnits {
+			return nil, jujutxn.ErrNoOperations
+		}
+		update := bson.D{{"$unset", bson.D{{"maxunits", nil}}}}
+		assert := isAliveDoc
+		if maxUnits > 0 {
+			if service.doc.UnitCount > maxUnits {
+				return nil, errors.Errorf("service has %d units", service.doc.UnitCount)
+			}
+			update = bson.D{{"$set", bson.D{{"maxunits", maxUnits}}}}
+			assert = append(bson.D{{"unitcount", bson.D{{"$lte", maxUnits}}}}, isAliveDoc...)
+		}
+		return []txn.Op{{
+			C:      servicesC,
+			Id:     service.doc.DocID,
+			Assert: assert,
+			Update: update,
+		}}, nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return err
+	}
+	s.doc.MaxUnits = maxUnits
+	return nil
+}
+
+// unitLimitAssert returns the assertion that the service document
+// has room for another unit, or that the service's units are still
+// not limited.
+func (s *Service) unitLimitAssert() bson.D {
+	if s.doc.MaxUnits == 0 {
+		return bson.D{{"maxunits", bson.D{{"$exists", false}}}}
+	}
+	return bson.D{{"unitcount", bson.D{{"$lt", s.doc.MaxUnits}}}}
+}
+
+// checkUnitLimit returns a UnitLimitError if the service already has
+// its maximum number of units.
+func (s *Service) checkUnitLimit() error {
+	if s.doc.MaxUnits > 0 && s.doc.UnitCount >= s.doc.MaxUnits {
+		return &UnitLimitError{ServiceName: s.doc.Name, Limit: s.doc.MaxUnits}
+	}
+	return nil
+}
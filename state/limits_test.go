@@ -0,0 +1,194 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type LimitsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&LimitsSuite{})
+
+var readRelationLimitsTests = []struct {
+	about    string
+	metadata string
+	limits   map[string]int
+	err      string
+}{{
+	about: "no limits",
+	metadata: `
+name: mysql
+provides:
+  server: mysql
+`,
+	limits: map[string]int{},
+}, {
+	about: "explicit limits",
+	metadata: `
+name: wordpress
+provides:
+  url:
+    interface: http
+    limit:
+requires:
+  db:
+    interface: mysql
+    limit: 1
+  cache:
+    interface: varnish
+    limit: 0
+peers:
+  ring:
+    interface: riak
+    limit: 3
+`,
+	limits: map[string]int{"db": 1, "ring": 3},
+}, {
+	about: "negative limit",
+	metadata: `
+name: mysql
+provides:
+  server: {interface: mysql, limit: -1}
+`,
+	err: `invalid limit -1 for relation "server"`,
+}, {
+	about: "non-integer limit",
+	metadata: `
+name: mysql
+provides:
+  server: {interface: mysql, limit: lots}
+`,
+	err: `invalid limit lots for relation "server"`,
+}}
+
+func (s *LimitsSuite) TestReadRelationLimits(c *gc.C) {
+	for i, test := range readRelationLimitsTests {
+		c.Logf("test %d: %s", i, test.about)
+		limits, err := state.ReadRelationLimits(strings.NewReader(test.metadata))
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(limits, jc.DeepEquals, test.limits)
+	}
+}
+
+func (s *LimitsSuite) TestCharmRelationLimits(c *gc.C) {
+	ch := s.AddTestingCharm(c, "wordpress")
+	c.Assert(ch.RelationLimits(), jc.DeepEquals, map[string]int{"db": 1, "cache": 2})
+	ch = s.AddTestingCharm(c, "mysql")
+	c.Assert(ch.RelationLimits(), gc.HasLen, 0)
+}
+
+func (s *LimitsSuite) TestAddRelationEnforcesLimit(c *gc.C) {
+	s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	mysql := s.AddTestingCharm(c, "mysql")
+	s.AddTestingService(c, "mysql1", mysql)
+	s.AddTestingService(c, "mysql2", mysql)
+
+	eps, err := s.State.InferEndpoints("wordpress", "mysql1")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	eps, err = s.State.InferEndpoints("wordpress", "mysql2")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, gc.ErrorMatches, `cannot add relation "wordpress:db mysql2:server": `+
+		`service "wordpress" already has the maximum of 1 relation\(s\) on "db"`)
+	c.Assert(err, jc.Satisfies, state.IsRelationLimitError)
+}
+
+func (s *LimitsSuite) TestAddRelationAllowsUpToLimit(c *gc.C) {
+	s.AddTestingService(c, "ms", s.AddTestingCharm(c, "mysql-alternative"))
+	wordpress := s.AddTestingCharm(c, "wordpress")
+	for _, name := range []string{"wp1", "wp2", "wp3"} {
+		s.AddTestingService(c, name, wordpress)
+	}
+	for _, name := range []string{"wp1", "wp2"} {
+		eps, err := s.State.InferEndpoints(name+":db", "ms:dev")
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = s.State.AddRelation(eps...)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	eps, err := s.State.InferEndpoints("wp3:db", "ms:dev")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.Satisfies, state.IsRelationLimitError)
+
+	// The limit applies to each endpoint separately.
+	eps, err = s.State.InferEndpoints("wp3:db", "ms:prod")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *LimitsSuite) TestSetMaxUnits(c *gc.C) {
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(svc.MaxUnits(), gc.Equals, 0)
+
+	err := svc.SetMaxUnits(2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.MaxUnits(), gc.Equals, 2)
+	err = svc.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.MaxUnits(), gc.Equals, 2)
+
+	err = svc.SetMaxUnits(0)
+	c.Assert(err, jc.ErrorIsNil)
+	err = svc.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(svc.MaxUnits(), gc.Equals, 0)
+}
+
+func (s *LimitsSuite) TestSetMaxUnitsErrors(c *gc.C) {
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := svc.SetMaxUnits(-1)
+	c.Assert(err, gc.ErrorMatches, `cannot set maximum units for service "mysql": cannot set a negative maximum number of units`)
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err = svc.SetMaxUnits(1)
+	c.Assert(err, gc.ErrorMatches, `cannot set maximum units for service "mysql": service has 2 units`)
+	c.Assert(svc.MaxUnits(), gc.Equals, 0)
+}
+
+func (s *LimitsSuite) TestAddUnitEnforcesMaxUnits(c *gc.C) {
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := svc.SetMaxUnits(1)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = svc.AddUnit()
+	c.Assert(err, gc.ErrorMatches, `cannot add unit to service "mysql": service "mysql" already has the maximum of 1 unit\(s\)`)
+	c.Assert(err, jc.Satisfies, state.IsUnitLimitError)
+}
+
+func (s *LimitsSuite) TestAddUnitEnforcesMaxUnitsSetConcurrently(c *gc.C) {
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	_, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Limit the service through another copy of it, so that svc
+	// only learns of the limit when its transaction is aborted.
+	other, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	err = other.SetMaxUnits(1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = svc.AddUnit()
+	c.Assert(err, jc.Satisfies, state.IsUnitLimitError)
+}
@@ -0,0 +1,348 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// LogForwardTargetType identifies the protocol used to forward logs
+// to an external target.
+type LogForwardTargetType string
+
+const (
+	// LogForwardSyslog targets receive RFC5424 syslog messages
+	// over TLS.
+	LogForwardSyslog LogForwardTargetType = "syslog"
+
+	// LogForwardLoki targets receive log entries through the Loki
+	// push API.
+	LogForwardLoki LogForwardTargetType = "loki"
+)
+
+var validLogForwardTargetName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// LogForwardTargetParams holds the configuration of an external
+// log forwarding target.
+type LogForwardTargetParams struct {
+	// Name uniquely identifies the target.
+	Name string
+
+	// Type is the protocol used to forward logs to the target.
+	Type LogForwardTargetType
+
+	// Address is the "host:port" of a syslog target, or the base
+	// URL of a Loki target.
+	Address string
+
+	// CACert, if set, holds the PEM-encoded CA certificate used
+	// to verify the target's certificate. If it is not set, the
+	// system's certificate authorities are used.
+	CACert string
+
+	// EnvUUIDs holds the UUIDs of the environments whose logs are
+	// forwarded to the target. If empty, logs from all
+	// environments are forwarded.
+	EnvUUIDs []string
+
+	// MinLevel is the lowest level of log record forwarded.
+	MinLevel loggo.Level
+}
+
+// Validate returns an error if the parameters do not describe a
+// usable target.
+func (p LogForwardTargetParams) Validate() error {
+	if !validLogForwardTargetName.MatchString(p.Name) {
+		return errors.NotValidf("log forwarding target name %q", p.Name)
+	}
+	if p.Address == "" {
+		return errors.NotValidf("empty address for log forwarding target %q", p.Name)
+	}
+	switch p.Type {
+	case LogForwardSyslog:
+	case LogForwardLoki:
+		u, err := url.Parse(p.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NotValidf("loki address %q", p.Address)
+		}
+	default:
+		return errors.NotValidf("log forwarding target type %q", p.Type)
+	}
+	return nil
+}
+
+type logForwardTargetDoc struct {
+	Name     string      `bson:"_id"`
+	Type     string      `bson:"type"`
+	Address  string      `bson:"address"`
+	CACert   string      `bson:"ca-cert,omitempty"`
+	EnvUUIDs []string    `bson:"env-uuids,omitempty"`
+	MinLevel loggo.Level `bson:"min-level"`
+	Created  time.Time   `bson:"created"`
+}
+
+// LogForwardTarget represents an external target to which the logs
+// stored by the state servers are forwarded.
+type LogForwardTarget struct {
+	doc logForwardTargetDoc
+}
+
+// Name returns the name of the target.
+func (t *LogForwardTarget) Name() string {
+	return t.doc.Name
+}
+
+// Type returns the protocol used to forward logs to the target.
+func (t *LogForwardTarget) Type() LogForwardTargetType {
+	return LogForwardTargetType(t.doc.Type)
+}
+
+// Address returns the address of the target.
+func (t *LogForwardTarget) Address() string {
+	return t.doc.Address
+}
+
+// CACert returns the CA certificate used to verify the target, if
+// any.
+func (t *LogForwardTarget) CACert() string {
+	return t.doc.CACert
+}
+
+// EnvUUIDs returns the UUIDs of the environments whose logs are
+// forwarded to the target. If empty, logs from all environments
+// are forwarded.
+func (t *LogForwardTarget) EnvUUIDs() []string {
+	return t.doc.EnvUUIDs
+}
+
+// MinLevel returns the lowest level of log record forwarded to the
+// target.
+func (t *LogForwardTarget) MinLevel() loggo.Level {
+	return t.doc.MinLevel
+}
+
+// Created returns the time at which the target was added. Only logs
+// recorded after this time are forwarded.
+func (t *LogForwardTarget) Created() time.Time {
+	return t.doc.Created
+}
+
+// ForwardsEnviron returns whether logs from the environment with the
+// given UUID are forwarded to the target.
+func (t *LogForwardTarget) ForwardsEnviron(envUUID string) bool {
+	if len(t.doc.EnvUUIDs) == 0 {
+		return true
+	}
+	for _, uuid := range t.doc.EnvUUIDs {
+		if uuid == envUUID {
+			return true
+		}
+	}
+	return false
+}
+
+// AddLogForwardTarget adds a new external log forwarding target.
+func (st *State) AddLogForwardTarget(p LogForwardTargetParams) (*LogForwardTarget, error) {
+	if err := p.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc := logForwardTargetDoc{
+		Name:     p.Name,
+		Type:     string(p.Type),
+		Address:  p.Address,
+		CACert:   p.CACert,
+		EnvUUIDs: p.EnvUUIDs,
+		MinLevel: p.MinLevel,
+		Created:  nowToTheSecond(),
+	}
+	ops := []txn.Op{{
+		C:      logForwardTargetsC,
+		Id:     doc.Name,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errors.AlreadyExistsf("log forwarding target %q", p.Name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot add log forwarding target %q", p.Name)
+	}
+	return &LogForwardTarget{doc}, nil
+}
+
+// LogForwardTarget returns the log forwarding target with the given
+// name.
+func (st *State) LogForwardTarget(name string) (*LogForwardTarget, error) {
+	targets, closer := st.getCollection(logForwardTargetsC)
+	defer closer()
+
+	var doc logForwardTargetDoc
+	err := targets.FindId(name).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("log forwarding target %q", name)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get log forwarding target %q", name)
+	}
+	return &LogForwardTarget{doc}, nil
+}
+
+// AllLogForwardTargets returns all the log forwarding targets.
+func (st *State) AllLogForwardTargets() ([]*LogForwardTarget, error) {
+	targets, closer := st.getCollection(logForwardTargetsC)
+	defer closer()
+
+	var docs []logForwardTargetDoc
+	if err := targets.Find(nil).Sort("_id").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get log forwarding targets")
+	}
+	result := make([]*LogForwardTarget, len(docs))
+	for i, doc := range docs {
+		result[i] = &LogForwardTarget{doc}
+	}
+	return result, nil
+}
+
+// RemoveLogForwardTarget removes the log forwarding target with the
+// given name, along with its delivery status.
+func (st *State) RemoveLogForwardTarget(name string) error {
+	ops := []txn.Op{{
+		C:      logForwardTargetsC,
+		Id:     name,
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("log forwarding target %q", name)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot remove log forwarding target %q", name)
+	}
+	status, closer := st.getRawCollection(logForwardStatusC)
+	defer closer()
+	if _, err := status.RemoveAll(bson.D{{"target", name}}); err != nil {
+		return errors.Annotatef(err, "cannot remove status of log forwarding target %q", name)
+	}
+	return nil
+}
+
+// LogForwardStatus describes the progress of forwarding an
+// environment's logs to a target.
+type LogForwardStatus struct {
+	// LastSentId identifies the last log record delivered to the
+	// target.
+	LastSentId string
+
+	// LastSentTime is the time at which the last delivered log
+	// record was logged.
+	LastSentTime time.Time
+
+	// Delivered is the total number of log records delivered.
+	Delivered int64
+
+	// Error holds the error from the most recent delivery attempt,
+	// if it failed.
+	Error string
+
+	// Updated is the time of the most recent delivery attempt.
+	Updated time.Time
+}
+
+// LogForwardProgress reports the outcome of an attempt to deliver
+// log records to a target.
+type LogForwardProgress struct {
+	// LastSentId and LastSentTime identify the last record
+	// delivered by the attempt. They are ignored if LastSentId
+	// is empty.
+	LastSentId   string
+	LastSentTime time.Time
+
+	// Delivered is the number of records delivered by the attempt.
+	Delivered int
+
+	// Error holds the error that stopped the attempt, if any.
+	Error string
+}
+
+type logForwardStatusDoc struct {
+	DocID        string    `bson:"_id"`
+	EnvUUID      string    `bson:"env-uuid"`
+	Target       string    `bson:"target"`
+	LastSentId   string    `bson:"last-sent-id"`
+	LastSentTime time.Time `bson:"last-sent-time"`
+	Delivered    int64     `bson:"delivered"`
+	Error        string    `bson:"error"`
+	Updated      time.Time `bson:"updated"`
+}
+
+func (st *State) logForwardStatusId(target string) string {
+	return st.EnvironUUID() + ":" + target
+}
+
+// LogForwardStatus returns the progress of forwarding this
+// environment's logs to the named target.
+func (st *State) LogForwardStatus(target string) (LogForwardStatus, error) {
+	t, err := st.LogForwardTarget(target)
+	if err != nil {
+		return LogForwardStatus{}, errors.Trace(err)
+	}
+	status, closer := st.getRawCollection(logForwardStatusC)
+	defer closer()
+
+	var doc logForwardStatusDoc
+	err = status.FindId(st.logForwardStatusId(target)).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		return LogForwardStatus{}, errors.Annotatef(err, "cannot get status of log forwarding target %q", target)
+	}
+	if doc.LastSentId == "" {
+		// Nothing has been delivered yet, so forwarding
+		// starts from the time the target was added.
+		doc.LastSentId = bson.NewObjectIdWithTime(t.Created()).Hex()
+	}
+	return LogForwardStatus{
+		LastSentId:   doc.LastSentId,
+		LastSentTime: doc.LastSentTime,
+		Delivered:    doc.Delivered,
+		Error:        doc.Error,
+		Updated:      doc.Updated,
+	}, nil
+}
+
+// SetLogForwardStatus records the outcome of an attempt to forward
+// this environment's logs to the named target.
+func (st *State) SetLogForwardStatus(target string, progress LogForwardProgress) error {
+	if _, err := st.LogForwardTarget(target); err != nil {
+		return errors.Trace(err)
+	}
+	if progress.LastSentId != "" && !bson.IsObjectIdHex(progress.LastSentId) {
+		return errors.NotValidf("log record id %q", progress.LastSentId)
+	}
+	set := bson.D{
+		{"env-uuid", st.EnvironUUID()},
+		{"target", target},
+		{"error", progress.Error},
+		{"updated", nowToTheSecond()},
+	}
+	if progress.LastSentId != "" {
+		set = append(set,
+			bson.DocElem{"last-sent-id", progress.LastSentId},
+			bson.DocElem{"last-sent-time", progress.LastSentTime},
+		)
+	}
+	status, closer := st.getRawCollection(logForwardStatusC)
+	defer closer()
+	_, err := status.UpsertId(st.logForwardStatusId(target), bson.D{
+		{"$set", set},
+		{"$inc", bson.D{{"delivered", progress.Delivered}}},
+	})
+	if err != nil {
+		return errors.Annotatef(err, "cannot set status of log forwarding target %q", target)
+	}
+	return nil
+}
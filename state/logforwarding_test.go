@@ -0,0 +1,198 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+)
+
+type LogForwardingSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&LogForwardingSuite{})
+
+func (s *LogForwardingSuite) addTarget(c *gc.C, name string, envUUIDs ...string) *state.LogForwardTarget {
+	target, err := s.State.AddLogForwardTarget(state.LogForwardTargetParams{
+		Name:     name,
+		Type:     state.LogForwardSyslog,
+		Address:  "syslog.example.com:6514",
+		EnvUUIDs: envUUIDs,
+		MinLevel: loggo.INFO,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return target
+}
+
+func (s *LogForwardingSuite) TestAddLogForwardTarget(c *gc.C) {
+	target, err := s.State.AddLogForwardTarget(state.LogForwardTargetParams{
+		Name:     "loki",
+		Type:     state.LogForwardLoki,
+		Address:  "https://loki.example.com:3100",
+		CACert:   "ca-cert",
+		EnvUUIDs: []string{s.State.EnvironUUID()},
+		MinLevel: loggo.WARNING,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(target.Name(), gc.Equals, "loki")
+	c.Check(target.Type(), gc.Equals, state.LogForwardLoki)
+	c.Check(target.Address(), gc.Equals, "https://loki.example.com:3100")
+	c.Check(target.CACert(), gc.Equals, "ca-cert")
+	c.Check(target.EnvUUIDs(), jc.DeepEquals, []string{s.State.EnvironUUID()})
+	c.Check(target.MinLevel(), gc.Equals, loggo.WARNING)
+
+	target, err = s.State.LogForwardTarget("loki")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(target.Address(), gc.Equals, "https://loki.example.com:3100")
+	c.Check(target.ForwardsEnviron(s.State.EnvironUUID()), jc.IsTrue)
+	c.Check(target.ForwardsEnviron("another-uuid"), jc.IsFalse)
+}
+
+func (s *LogForwardingSuite) TestAddLogForwardTargetAlreadyExists(c *gc.C) {
+	s.addTarget(c, "syslog")
+	_, err := s.State.AddLogForwardTarget(state.LogForwardTargetParams{
+		Name:    "syslog",
+		Type:    state.LogForwardSyslog,
+		Address: "other.example.com:6514",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *LogForwardingSuite) TestAddLogForwardTargetInvalid(c *gc.C) {
+	for i, test := range []struct {
+		params state.LogForwardTargetParams
+		err    string
+	}{{
+		params: state.LogForwardTargetParams{Name: "Bad Name", Type: state.LogForwardSyslog, Address: "a:1"},
+		err:    `log forwarding target name "Bad Name" not valid`,
+	}, {
+		params: state.LogForwardTargetParams{Name: "syslog", Type: state.LogForwardSyslog},
+		err:    `empty address for log forwarding target "syslog" not valid`,
+	}, {
+		params: state.LogForwardTargetParams{Name: "loki", Type: state.LogForwardLoki, Address: "loki:3100"},
+		err:    `loki address "loki:3100" not valid`,
+	}, {
+		params: state.LogForwardTargetParams{Name: "gelf", Type: "gelf", Address: "a:1"},
+		err:    `log forwarding target type "gelf" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := s.State.AddLogForwardTarget(test.params)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *LogForwardingSuite) TestAllLogForwardTargets(c *gc.C) {
+	s.addTarget(c, "zzz")
+	s.addTarget(c, "aaa")
+	targets, err := s.State.AllLogForwardTargets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(targets, gc.HasLen, 2)
+	c.Check(targets[0].Name(), gc.Equals, "aaa")
+	c.Check(targets[1].Name(), gc.Equals, "zzz")
+	c.Check(targets[0].ForwardsEnviron("any-uuid"), jc.IsTrue)
+}
+
+func (s *LogForwardingSuite) TestRemoveLogForwardTarget(c *gc.C) {
+	s.addTarget(c, "syslog")
+	err := s.State.SetLogForwardStatus("syslog", state.LogForwardProgress{Error: "boom"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RemoveLogForwardTarget("syslog")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.LogForwardTarget("syslog")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	count, err := s.Session.DB("juju").C("logforwardstatus").Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+
+	err = s.State.RemoveLogForwardTarget("syslog")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *LogForwardingSuite) TestLogForwardStatusInitial(c *gc.C) {
+	target := s.addTarget(c, "syslog")
+	status, err := s.State.LogForwardStatus("syslog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bson.IsObjectIdHex(status.LastSentId), jc.IsTrue)
+	c.Assert(bson.ObjectIdHex(status.LastSentId).Time().Equal(target.Created()), jc.IsTrue)
+	c.Assert(status.Delivered, gc.Equals, int64(0))
+	c.Assert(status.Error, gc.Equals, "")
+}
+
+func (s *LogForwardingSuite) TestSetLogForwardStatus(c *gc.C) {
+	s.addTarget(c, "syslog")
+	t0 := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	id := bson.NewObjectId().Hex()
+	err := s.State.SetLogForwardStatus("syslog", state.LogForwardProgress{
+		LastSentId:   id,
+		LastSentTime: t0,
+		Delivered:    3,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A failed attempt keeps the position but records the error.
+	err = s.State.SetLogForwardStatus("syslog", state.LogForwardProgress{Error: "connection refused"})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.State.LogForwardStatus("syslog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.LastSentId, gc.Equals, id)
+	c.Check(status.LastSentTime.Equal(t0), jc.IsTrue)
+	c.Check(status.Delivered, gc.Equals, int64(3))
+	c.Check(status.Error, gc.Equals, "connection refused")
+
+	err = s.State.SetLogForwardStatus("syslog", state.LogForwardProgress{
+		LastSentId:   bson.NewObjectId().Hex(),
+		LastSentTime: t0.Add(time.Second),
+		Delivered:    2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.State.LogForwardStatus("syslog")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.Delivered, gc.Equals, int64(5))
+	c.Check(status.Error, gc.Equals, "")
+}
+
+func (s *LogForwardingSuite) TestSetLogForwardStatusErrors(c *gc.C) {
+	err := s.State.SetLogForwardStatus("missing", state.LogForwardProgress{})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	s.addTarget(c, "syslog")
+	err = s.State.SetLogForwardStatus("syslog", state.LogForwardProgress{LastSentId: "foo"})
+	c.Assert(err, gc.ErrorMatches, `log record id "foo" not valid`)
+}
+
+func (s *LogForwardingSuite) TestLogsAfter(c *gc.C) {
+	logger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer logger.Close()
+	start := bson.NewObjectIdWithTime(time.Now().Add(-time.Minute)).Hex()
+	t0 := time.Now().Truncate(time.Second)
+	for i, level := range []loggo.Level{loggo.INFO, loggo.DEBUG, loggo.ERROR, loggo.WARNING} {
+		err := logger.Log(t0.Add(time.Duration(i)*time.Second), "juju", "file.go:1", level, level.String())
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	records, err := s.State.LogsAfter(start, loggo.INFO, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+	c.Check(records[0].Message, gc.Equals, "INFO")
+	c.Check(records[0].Entity, gc.Equals, "machine-0")
+	c.Check(records[1].Message, gc.Equals, "ERROR")
+
+	records, err = s.State.LogsAfter(records[1].Id, loggo.INFO, 10)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Check(records[0].Message, gc.Equals, "WARNING")
+
+	_, err = s.State.LogsAfter("foo", loggo.INFO, 10)
+	c.Assert(err, gc.ErrorMatches, `log record id "foo" not valid`)
+}
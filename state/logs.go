@@ -0,0 +1,525 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"launchpad.net/tomb"
+)
+
+// Agent log records are stored in a capped collection in a database
+// of their own, so that the volume of logging traffic never competes
+// with the main juju database.
+const (
+	logsDB = "logs"
+	logsC  = "logs"
+)
+
+// The capped collection used for log records defaults to 1GB. It's
+// tweaked in export_test.go to 1MB to avoid the overhead of creating
+// and deleting the large file repeatedly in tests.
+var (
+	logsSize      = 1024 * 1024 * 1024
+	logsSizeTests = 1024 * 1024
+)
+
+// logIndexes holds the indexes used to query log records by
+// environment, time, entity and level.
+var logIndexes = [][]string{
+	{"e", "t"},
+	{"e", "n"},
+	{"e", "v"},
+}
+
+// InitDbLogs sets up the capped collection and indexes for the logs
+// database. It is safe to call more than once.
+func InitDbLogs(session *mgo.Session) error {
+	logsColl := session.DB(logsDB).C(logsC)
+	err := logsColl.Create(&mgo.CollectionInfo{Capped: true, MaxBytes: logsSize})
+	if isCollectionExistsError(err) {
+		return maybeUnauthorized(err, "cannot create logs collection")
+	}
+	for _, key := range logIndexes {
+		if err := logsColl.EnsureIndexKey(key...); err != nil {
+			return errors.Annotate(err, "cannot create index for logs collection")
+		}
+	}
+	return nil
+}
+
+// logDoc describes a single log record stored in the logs collection.
+// Field names are kept short to keep the size of each record down.
+type logDoc struct {
+	Id       bson.ObjectId `bson:"_id"`
+	Time     time.Time     `bson:"t"`
+	EnvUUID  string        `bson:"e"`
+	Entity   string        `bson:"n"` // e.g. "machine-0"
+	Module   string        `bson:"m"` // e.g. "juju.worker.firewaller"
+	Location string        `bson:"l"` // "filename:lineno"
+	Level    loggo.Level   `bson:"v"`
+	Message  string        `bson:"x"`
+}
+
+// DbLogger writes log records for a single entity to the logs
+// collection.
+type DbLogger struct {
+	logsColl *mgo.Collection
+	envUUID  string
+	entity   string
+}
+
+// NewDbLogger returns a DbLogger which records log messages
+// on behalf of the given entity. The logger holds its own copy
+// of the state's mongo session, so Close must be called when it
+// is no longer needed.
+func NewDbLogger(st *State, entity names.Tag) *DbLogger {
+	session := st.MongoSession().Copy()
+	return &DbLogger{
+		logsColl: session.DB(logsDB).C(logsC),
+		envUUID:  st.EnvironUUID(),
+		entity:   entity.String(),
+	}
+}
+
+// Log writes a log message to the database.
+func (logger *DbLogger) Log(t time.Time, module string, location string, level loggo.Level, msg string) error {
+	return logger.logsColl.Insert(&logDoc{
+		Id:       bson.NewObjectId(),
+		Time:     t,
+		EnvUUID:  logger.envUUID,
+		Entity:   logger.entity,
+		Module:   module,
+		Location: location,
+		Level:    level,
+		Message:  msg,
+	})
+}
+
+// Close cleans up resources used by the DbLogger instance.
+func (logger *DbLogger) Close() {
+	if logger.logsColl != nil {
+		logger.logsColl.Database.Session.Close()
+	}
+}
+
+// LogRecord defines a single log record returned by a LogTailer.
+type LogRecord struct {
+	Id       string // hex encoded, ordered by the time it was recorded
+	Time     time.Time
+	Entity   string
+	Module   string
+	Location string
+	Level    loggo.Level
+	Message  string
+}
+
+// LogTailerParams specifies the filtering a LogTailer should apply
+// to log records in order to decide which to return.
+type LogTailerParams struct {
+	// StartTime and EndTime, if set, limit the records returned
+	// to those logged within the given time range. When StartTime
+	// is set, all matching records logged since then are replayed.
+	// When EndTime is set, the tailer does not wait for new records
+	// to arrive.
+	StartTime time.Time
+	EndTime   time.Time
+
+	// MinLevel is the lowest level of record that will be returned.
+	MinLevel loggo.Level
+
+	// InitialLines is the number of existing records to return
+	// before waiting for new ones. It is ignored if Replay is true.
+	InitialLines int
+
+	// InitialLinesPerEntity causes InitialLines to apply to each
+	// matching entity separately rather than to all records.
+	InitialLinesPerEntity bool
+
+	// Replay causes all matching records already in the database
+	// to be returned.
+	Replay bool
+
+	// NoTail causes the tailer to stop once the existing matching
+	// records have been returned.
+	NoTail bool
+
+	// IncludeEntity and ExcludeEntity hold entity tags to include
+	// or exclude. A value may contain '*' as a wildcard.
+	IncludeEntity []string
+	ExcludeEntity []string
+
+	// IncludeModule and ExcludeModule hold logging modules to
+	// include or exclude. Submodules of a given module are
+	// matched too.
+	IncludeModule []string
+	ExcludeModule []string
+
+	// IncludeModuleRegexp and ExcludeModuleRegexp hold regular
+	// expressions which logging modules must, or must not, match.
+	IncludeModuleRegexp []string
+	ExcludeModuleRegexp []string
+
+	// IncludeMessage and ExcludeMessage hold regular expressions
+	// which log messages must, or must not, match.
+	IncludeMessage []string
+	ExcludeMessage []string
+}
+
+// LogTailer allows for retrieval of Juju's logs from MongoDB. It
+// first returns any matching already recorded logs and then waits
+// for additional matching logs as they appear.
+type LogTailer interface {
+	// Logs returns the channel through which the LogTailer returns
+	// Juju logs. It will be closed when the tailer stops.
+	Logs() <-chan *LogRecord
+
+	// Dying returns a channel which will be closed as the LogTailer
+	// stops.
+	Dying() <-chan struct{}
+
+	// Stop is used to request that the LogTailer stops. It blocks
+	// until the LogTailer has stopped.
+	Stop() error
+
+	// Err returns the error that caused the LogTailer to stopped. If
+	// it hasn't stopped or stopped without error nil will be
+	// returned.
+	Err() error
+}
+
+// tailTimeout is how long the tailer waits on a tailable cursor
+// before checking whether it has been asked to stop.
+var tailTimeout = time.Second
+
+// NewLogTailer returns a LogTailer which filters according to the
+// parameters given.
+func NewLogTailer(st *State, params *LogTailerParams) LogTailer {
+	session := st.MongoSession().Copy()
+	t := &logTailer{
+		envUUID:  st.EnvironUUID(),
+		session:  session,
+		logsColl: session.DB(logsDB).C(logsC),
+		params:   params,
+		logCh:    make(chan *LogRecord),
+	}
+	go func() {
+		defer t.tomb.Done()
+		defer close(t.logCh)
+		defer session.Close()
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+type logTailer struct {
+	tomb     tomb.Tomb
+	envUUID  string
+	session  *mgo.Session
+	logsColl *mgo.Collection
+	params   *LogTailerParams
+	logCh    chan *LogRecord
+	lastId   bson.ObjectId
+}
+
+// Logs implements the LogTailer interface.
+func (t *logTailer) Logs() <-chan *LogRecord {
+	return t.logCh
+}
+
+// Dying implements the LogTailer interface.
+func (t *logTailer) Dying() <-chan struct{} {
+	return t.tomb.Dying()
+}
+
+// Stop implements the LogTailer interface.
+func (t *logTailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Err implements the LogTailer interface.
+func (t *logTailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *logTailer) loop() error {
+	// Errors are returned untraced so that tomb.ErrDying is
+	// recognised by the tomb.
+	if err := t.processCollection(); err != nil {
+		return err
+	}
+	if t.params.NoTail || !t.params.EndTime.IsZero() {
+		return nil
+	}
+	return t.tailCollection()
+}
+
+// processCollection returns the matching records already in the
+// collection.
+func (t *logTailer) processCollection() error {
+	// Remember the most recent record so that tailing picks up from
+	// there once the existing records have been returned.
+	var latest logDoc
+	err := t.logsColl.Find(bson.M{"e": t.envUUID}).Sort("-$natural").Select(bson.M{"_id": 1}).One(&latest)
+	if err == mgo.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	query := t.query()
+	query["_id"] = bson.M{"$lte": latest.Id}
+	if t.params.Replay || !t.params.StartTime.IsZero() {
+		iter := t.logsColl.Find(query).Sort("$natural").Iter()
+		var doc logDoc
+		for iter.Next(&doc) {
+			if err := t.send(&doc); err != nil {
+				iter.Close()
+				return err
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	} else if t.params.InitialLines > 0 && t.params.InitialLinesPerEntity {
+		if err := t.processEntityBacklogs(query); err != nil {
+			return err
+		}
+	} else if t.params.InitialLines > 0 {
+		var docs []logDoc
+		err := t.logsColl.Find(query).Sort("-$natural").Limit(t.params.InitialLines).All(&docs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := len(docs) - 1; i >= 0; i-- {
+			if err := t.send(&docs[i]); err != nil {
+				return err
+			}
+		}
+	}
+	t.lastId = latest.Id
+	return nil
+}
+
+// processEntityBacklogs returns the most recent InitialLines records
+// matching the query for each entity, interleaved in time order.
+func (t *logTailer) processEntityBacklogs(query bson.M) error {
+	var entities []string
+	if err := t.logsColl.Find(query).Distinct("n", &entities); err != nil {
+		return errors.Trace(err)
+	}
+	var docs []logDoc
+	for _, entity := range entities {
+		entityQuery := bson.M{"$and": []bson.M{query, {"n": entity}}}
+		var entityDocs []logDoc
+		err := t.logsColl.Find(entityQuery).Sort("-$natural").Limit(t.params.InitialLines).All(&entityDocs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		docs = append(docs, entityDocs...)
+	}
+	sort.Sort(logDocsByTime(docs))
+	for i := range docs {
+		if err := t.send(&docs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logDocsByTime sorts log records by the time they were logged,
+// falling back to the order in which they were inserted.
+type logDocsByTime []logDoc
+
+func (d logDocsByTime) Len() int      { return len(d) }
+func (d logDocsByTime) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d logDocsByTime) Less(i, j int) bool {
+	if !d[i].Time.Equal(d[j].Time) {
+		return d[i].Time.Before(d[j].Time)
+	}
+	return d[i].Id < d[j].Id
+}
+
+// tailCollection waits for new matching records to be written to
+// the collection and returns them.
+func (t *logTailer) tailCollection() error {
+	for {
+		query := t.query()
+		if t.lastId != "" {
+			query["_id"] = bson.M{"$gt": t.lastId}
+		}
+		iter := t.logsColl.Find(query).Sort("$natural").Tail(tailTimeout)
+		var doc logDoc
+		for {
+			for iter.Next(&doc) {
+				if err := t.send(&doc); err != nil {
+					iter.Close()
+					return err
+				}
+			}
+			if iter.Err() != nil || !iter.Timeout() {
+				break
+			}
+			select {
+			case <-t.tomb.Dying():
+				iter.Close()
+				return tomb.ErrDying
+			default:
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		// The cursor was invalidated (for example because the
+		// collection was empty); wait a little before querying
+		// again from the last record seen.
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(tailTimeout):
+		}
+	}
+}
+
+func (t *logTailer) send(doc *logDoc) error {
+	rec := doc.record()
+	select {
+	case <-t.tomb.Dying():
+		return tomb.ErrDying
+	case t.logCh <- rec:
+	}
+	t.lastId = doc.Id
+	return nil
+}
+
+// record returns the LogRecord describing the document.
+func (doc *logDoc) record() *LogRecord {
+	return &LogRecord{
+		Id:       doc.Id.Hex(),
+		Time:     doc.Time,
+		Entity:   doc.Entity,
+		Module:   doc.Module,
+		Location: doc.Location,
+		Level:    doc.Level,
+		Message:  doc.Message,
+	}
+}
+
+// LogsAfter returns, in the order they were recorded, at most limit
+// of the environment's log records at or above the given level that
+// were recorded after the record with the given id.
+func (st *State) LogsAfter(afterId string, minLevel loggo.Level, limit int) ([]*LogRecord, error) {
+	if !bson.IsObjectIdHex(afterId) {
+		return nil, errors.NotValidf("log record id %q", afterId)
+	}
+	session := st.MongoSession().Copy()
+	defer session.Close()
+	query := bson.M{
+		"e":   st.EnvironUUID(),
+		"_id": bson.M{"$gt": bson.ObjectIdHex(afterId)},
+	}
+	if minLevel > loggo.UNSPECIFIED {
+		query["v"] = bson.M{"$gte": minLevel}
+	}
+	var docs []logDoc
+	err := session.DB(logsDB).C(logsC).Find(query).Sort("$natural").Limit(limit).All(&docs)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get log records")
+	}
+	records := make([]*LogRecord, len(docs))
+	for i := range docs {
+		records[i] = docs[i].record()
+	}
+	return records, nil
+}
+
+// query returns the mongo query which selects the records matching
+// the tailer's parameters.
+func (t *logTailer) query() bson.M {
+	query := bson.M{"e": t.envUUID}
+	timeRange := bson.M{}
+	if !t.params.StartTime.IsZero() {
+		timeRange["$gte"] = t.params.StartTime
+	}
+	if !t.params.EndTime.IsZero() {
+		timeRange["$lte"] = t.params.EndTime
+	}
+	if len(timeRange) > 0 {
+		query["t"] = timeRange
+	}
+	if t.params.MinLevel > loggo.UNSPECIFIED {
+		query["v"] = bson.M{"$gte": t.params.MinLevel}
+	}
+	var and []bson.M
+	if len(t.params.IncludeEntity) > 0 {
+		and = append(and, bson.M{"n": bson.RegEx{Pattern: makeEntityPattern(t.params.IncludeEntity)}})
+	}
+	if len(t.params.ExcludeEntity) > 0 {
+		and = append(and, bson.M{"n": bson.M{"$not": bson.RegEx{Pattern: makeEntityPattern(t.params.ExcludeEntity)}}})
+	}
+	if len(t.params.IncludeModule) > 0 {
+		and = append(and, bson.M{"m": bson.RegEx{Pattern: makeModulePattern(t.params.IncludeModule)}})
+	}
+	if len(t.params.ExcludeModule) > 0 {
+		and = append(and, bson.M{"m": bson.M{"$not": bson.RegEx{Pattern: makeModulePattern(t.params.ExcludeModule)}}})
+	}
+	if len(t.params.IncludeModuleRegexp) > 0 {
+		and = append(and, bson.M{"m": bson.RegEx{Pattern: makeRegexpPattern(t.params.IncludeModuleRegexp)}})
+	}
+	if len(t.params.ExcludeModuleRegexp) > 0 {
+		and = append(and, bson.M{"m": bson.M{"$not": bson.RegEx{Pattern: makeRegexpPattern(t.params.ExcludeModuleRegexp)}}})
+	}
+	if len(t.params.IncludeMessage) > 0 {
+		and = append(and, bson.M{"x": bson.RegEx{Pattern: makeRegexpPattern(t.params.IncludeMessage)}})
+	}
+	if len(t.params.ExcludeMessage) > 0 {
+		and = append(and, bson.M{"x": bson.M{"$not": bson.RegEx{Pattern: makeRegexpPattern(t.params.ExcludeMessage)}}})
+	}
+	if len(and) > 0 {
+		query["$and"] = and
+	}
+	return query
+}
+
+// makeEntityPattern returns a regular expression matching any of the
+// given entity tags, where '*' matches any sequence of characters.
+func makeEntityPattern(entities []string) string {
+	var patterns []string
+	for _, entity := range entities {
+		parts := strings.Split(entity, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		patterns = append(patterns, strings.Join(parts, ".*"))
+	}
+	return `^(` + strings.Join(patterns, "|") + `)$`
+}
+
+// makeModulePattern returns a regular expression matching any of the
+// given logging modules and their submodules.
+func makeModulePattern(modules []string) string {
+	var patterns []string
+	for _, module := range modules {
+		patterns = append(patterns, regexp.QuoteMeta(module))
+	}
+	return `^(` + strings.Join(patterns, "|") + `)(\..+)?$`
+}
+
+// makeRegexpPattern returns a regular expression matching anything
+// that matches any of the given regular expressions.
+func makeRegexpPattern(exprs []string) string {
+	patterns := make([]string, len(exprs))
+	for i, expr := range exprs {
+		patterns[i] = `(?:` + expr + `)`
+	}
+	return strings.Join(patterns, "|")
+}
@@ -0,0 +1,269 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type LogsSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&LogsSuite{})
+
+func (s *LogsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.PatchValue(state.TailTimeout, 10*time.Millisecond)
+}
+
+func (s *LogsSuite) TestIndexesCreated(c *gc.C) {
+	indexes, err := s.Session.DB("logs").C("logs").Indexes()
+	c.Assert(err, jc.ErrorIsNil)
+	var keys [][]string
+	for _, index := range indexes {
+		keys = append(keys, index.Key)
+	}
+	c.Assert(keys, jc.SameContents, [][]string{
+		{"_id"},
+		{"e", "t"},
+		{"e", "n"},
+		{"e", "v"},
+	})
+}
+
+func (s *LogsSuite) TestDbLogger(c *gc.C) {
+	logger := state.NewDbLogger(s.State, names.NewMachineTag("22"))
+	defer logger.Close()
+	t0 := time.Now().Truncate(time.Millisecond)
+	logger.Log(t0, "some.where", "foo.go:99", loggo.INFO, "all is well")
+	logger.Log(t0.Add(time.Second), "else.where", "bar.go:42", loggo.ERROR, "oh noes")
+
+	var docs []bson.M
+	err := s.Session.DB("logs").C("logs").Find(nil).Sort("t").All(&docs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 2)
+
+	c.Assert(docs[0]["t"].(time.Time).Equal(t0), jc.IsTrue)
+	c.Assert(docs[0]["e"], gc.Equals, s.State.EnvironUUID())
+	c.Assert(docs[0]["n"], gc.Equals, "machine-22")
+	c.Assert(docs[0]["m"], gc.Equals, "some.where")
+	c.Assert(docs[0]["l"], gc.Equals, "foo.go:99")
+	c.Assert(docs[0]["v"], gc.Equals, int(loggo.INFO))
+	c.Assert(docs[0]["x"], gc.Equals, "all is well")
+
+	c.Assert(docs[1]["n"], gc.Equals, "machine-22")
+	c.Assert(docs[1]["v"], gc.Equals, int(loggo.ERROR))
+	c.Assert(docs[1]["x"], gc.Equals, "oh noes")
+}
+
+type logLine struct {
+	entity  names.Tag
+	t       time.Time
+	module  string
+	level   loggo.Level
+	message string
+}
+
+func (s *LogsSuite) writeLogs(c *gc.C, lines ...logLine) {
+	for _, line := range lines {
+		logger := state.NewDbLogger(s.State, line.entity)
+		err := logger.Log(line.t, line.module, "file.go:1", line.level, line.message)
+		logger.Close()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *LogsSuite) assertTailer(c *gc.C, tailer state.LogTailer, expected ...string) {
+	timeout := time.After(coretesting.LongWait)
+	for _, message := range expected {
+		select {
+		case rec, ok := <-tailer.Logs():
+			c.Assert(ok, jc.IsTrue)
+			c.Assert(rec.Message, gc.Equals, message)
+		case <-timeout:
+			c.Fatalf("timed out waiting for %q", message)
+		}
+	}
+	select {
+	case rec, ok := <-tailer.Logs():
+		if ok {
+			c.Fatalf("unexpected log record: %q", rec.Message)
+		}
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+var (
+	machine0 = names.NewMachineTag("0")
+	machine1 = names.NewMachineTag("1")
+	unit0    = names.NewUnitTag("mysql/0")
+)
+
+func (s *LogsSuite) TestTailerInitialLines(c *gc.C) {
+	t0 := time.Now()
+	s.writeLogs(c,
+		logLine{machine0, t0, "juju", loggo.INFO, "one"},
+		logLine{machine0, t0, "juju", loggo.INFO, "two"},
+		logLine{machine0, t0, "juju", loggo.INFO, "three"},
+	)
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{InitialLines: 2})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, "two", "three")
+
+	s.writeLogs(c, logLine{machine0, t0, "juju", loggo.INFO, "four"})
+	s.assertTailer(c, tailer, "four")
+}
+
+func (s *LogsSuite) TestTailerReplay(c *gc.C) {
+	t0 := time.Now()
+	s.writeLogs(c,
+		logLine{machine0, t0, "juju", loggo.INFO, "one"},
+		logLine{machine0, t0, "juju", loggo.INFO, "two"},
+	)
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{Replay: true})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, "one", "two")
+}
+
+func (s *LogsSuite) TestTailerOnlyNewRecords(c *gc.C) {
+	t0 := time.Now()
+	s.writeLogs(c, logLine{machine0, t0, "juju", loggo.INFO, "old"})
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer)
+
+	s.writeLogs(c, logLine{machine0, t0, "juju", loggo.INFO, "new"})
+	s.assertTailer(c, tailer, "new")
+}
+
+func (s *LogsSuite) TestTailerNoTail(c *gc.C) {
+	t0 := time.Now()
+	s.writeLogs(c, logLine{machine0, t0, "juju", loggo.INFO, "one"})
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{Replay: true, NoTail: true})
+	s.assertTailer(c, tailer, "one")
+	c.Assert(tailer.Err(), jc.ErrorIsNil)
+}
+
+func (s *LogsSuite) TestTailerFiltering(c *gc.C) {
+	t0 := time.Now().Truncate(time.Second)
+	s.writeLogs(c,
+		logLine{machine0, t0, "juju.cmd", loggo.INFO, "machine0 cmd"},
+		logLine{machine0, t0, "juju.cmd.jujud", loggo.DEBUG, "machine0 jujud"},
+		logLine{machine1, t0.Add(time.Minute), "juju.worker", loggo.ERROR, "machine1 worker"},
+		logLine{unit0, t0.Add(2 * time.Minute), "juju.worker.uniter", loggo.WARNING, "unit0 uniter"},
+	)
+	for i, test := range []struct {
+		about    string
+		params   state.LogTailerParams
+		expected []string
+	}{{
+		about:    "include entity",
+		params:   state.LogTailerParams{IncludeEntity: []string{"machine-1", "unit-mysql-0"}},
+		expected: []string{"machine1 worker", "unit0 uniter"},
+	}, {
+		about:    "include entity wildcard",
+		params:   state.LogTailerParams{IncludeEntity: []string{"machine-*"}},
+		expected: []string{"machine0 cmd", "machine0 jujud", "machine1 worker"},
+	}, {
+		about:    "exclude entity",
+		params:   state.LogTailerParams{ExcludeEntity: []string{"machine-0"}},
+		expected: []string{"machine1 worker", "unit0 uniter"},
+	}, {
+		about:    "include module with submodules",
+		params:   state.LogTailerParams{IncludeModule: []string{"juju.cmd"}},
+		expected: []string{"machine0 cmd", "machine0 jujud"},
+	}, {
+		about:    "exclude module",
+		params:   state.LogTailerParams{ExcludeModule: []string{"juju.worker", "juju.cmd.jujud"}},
+		expected: []string{"machine0 cmd"},
+	}, {
+		about:    "minimum level",
+		params:   state.LogTailerParams{MinLevel: loggo.WARNING},
+		expected: []string{"machine1 worker", "unit0 uniter"},
+	}, {
+		about:    "time range",
+		params:   state.LogTailerParams{StartTime: t0.Add(time.Minute), EndTime: t0.Add(time.Minute)},
+		expected: []string{"machine1 worker"},
+	}, {
+		about:    "include module regexp",
+		params:   state.LogTailerParams{IncludeModuleRegexp: []string{`^juju\.cmd$`, `uniter$`}},
+		expected: []string{"machine0 cmd", "unit0 uniter"},
+	}, {
+		about:    "exclude module regexp",
+		params:   state.LogTailerParams{ExcludeModuleRegexp: []string{`^juju\.cmd`}},
+		expected: []string{"machine1 worker", "unit0 uniter"},
+	}, {
+		about:    "include message",
+		params:   state.LogTailerParams{IncludeMessage: []string{`^machine\d+ (cmd|worker)$`}},
+		expected: []string{"machine0 cmd", "machine1 worker"},
+	}, {
+		about:    "exclude message",
+		params:   state.LogTailerParams{ExcludeMessage: []string{"jujud", "uniter"}},
+		expected: []string{"machine0 cmd", "machine1 worker"},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		params := test.params
+		params.Replay = true
+		params.NoTail = true
+		tailer := state.NewLogTailer(s.State, &params)
+		s.assertTailer(c, tailer, test.expected...)
+		tailer.Stop()
+	}
+}
+
+func (s *LogsSuite) TestTailerInitialLinesPerEntity(c *gc.C) {
+	t0 := time.Now().Truncate(time.Second)
+	s.writeLogs(c,
+		logLine{machine0, t0, "juju", loggo.INFO, "machine0 one"},
+		logLine{machine1, t0.Add(time.Second), "juju", loggo.INFO, "machine1 one"},
+		logLine{machine0, t0.Add(2 * time.Second), "juju", loggo.INFO, "machine0 two"},
+		logLine{machine0, t0.Add(3 * time.Second), "juju", loggo.INFO, "machine0 three"},
+		logLine{unit0, t0.Add(4 * time.Second), "juju", loggo.INFO, "unit0 one"},
+	)
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{
+		InitialLines:          2,
+		InitialLinesPerEntity: true,
+		IncludeEntity:         []string{"machine-*"},
+	})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, "machine1 one", "machine0 two", "machine0 three")
+}
+
+func (s *LogsSuite) TestTailerStartTimeReplays(c *gc.C) {
+	t0 := time.Now().Truncate(time.Second)
+	s.writeLogs(c,
+		logLine{machine0, t0, "juju", loggo.INFO, "one"},
+		logLine{machine0, t0.Add(time.Minute), "juju", loggo.INFO, "two"},
+		logLine{machine0, t0.Add(2 * time.Minute), "juju", loggo.INFO, "three"},
+	)
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{StartTime: t0.Add(time.Minute)})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, "two", "three")
+
+	s.writeLogs(c, logLine{machine0, t0.Add(3 * time.Minute), "juju", loggo.INFO, "four"})
+	s.assertTailer(c, tailer, "four")
+}
+
+func (s *LogsSuite) TestTailerStop(c *gc.C) {
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{})
+	err := tailer.Stop()
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case <-tailer.Dying():
+	default:
+		c.Fatalf("tailer not dying after Stop")
+	}
+	_, ok := <-tailer.Logs()
+	c.Assert(ok, jc.IsFalse)
+}
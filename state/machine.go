@@ -25,6 +25,7 @@ import (
 	"github.com/juju/juju/state/multiwatcher"
 	"github.com/juju/juju/state/presence"
 	"github.com/juju/juju/tools"
+	"github.com/juju/juju/utils/ssh"
 	"github.com/juju/juju/version"
 )
 
@@ -122,6 +123,12 @@ type machineDoc struct {
 	// Placement is the placement directive that should be used when provisioning
 	// an instance for the machine.
 	Placement string `bson:",omitempty"`
+	// HardwareRefreshRequested is set when the machine agent should
+	// re-detect the machine's hardware characteristics.
+	HardwareRefreshRequested bool `bson:",omitempty"`
+	// SSHHostKeys holds the machine's public ssh host keys, as
+	// reported by the machine agent once the machine is provisioned.
+	SSHHostKeys []string `bson:",omitempty"`
 }
 
 func newMachine(st *State, doc *machineDoc) *Machine {
@@ -161,6 +168,12 @@ func (m *Machine) globalKey() string {
 	return machineGlobalKey(m.doc.Id)
 }
 
+// provisioningGlobalKey returns the global key under which the
+// provisioning progress of the machine is recorded.
+func (m *Machine) provisioningGlobalKey() string {
+	return machineGlobalKey(m.doc.Id) + "#provisioning"
+}
+
 // instanceData holds attributes relevant to a provisioned machine.
 type instanceData struct {
 	DocID      string      `bson:"_id"`
@@ -174,6 +187,9 @@ type instanceData struct {
 	CpuCores   *uint64     `bson:"cpucores,omitempty"`
 	CpuPower   *uint64     `bson:"cpupower,omitempty"`
 	Tags       *[]string   `bson:"tags,omitempty"`
+	NumaNodes  *uint64     `bson:"numanodes,omitempty"`
+	Gpus       *uint64     `bson:"gpus,omitempty"`
+	GpuType    *string     `bson:"gputype,omitempty"`
 	AvailZone  *string     `bson:"availzone,omitempty"`
 }
 
@@ -185,6 +201,9 @@ func hardwareCharacteristics(instData instanceData) *instance.HardwareCharacteri
 		CpuCores:         instData.CpuCores,
 		CpuPower:         instData.CpuPower,
 		Tags:             instData.Tags,
+		NumaNodes:        instData.NumaNodes,
+		Gpus:             instData.Gpus,
+		GpuType:          instData.GpuType,
 		AvailabilityZone: instData.AvailZone,
 	}
 }
@@ -198,6 +217,115 @@ func (m *Machine) HardwareCharacteristics() (*instance.HardwareCharacteristics,
 	return hardwareCharacteristics(instData), nil
 }
 
+// HardwareRefreshRequested returns whether the machine agent has been
+// asked to re-detect the machine's hardware characteristics.
+func (m *Machine) HardwareRefreshRequested() bool {
+	return m.doc.HardwareRefreshRequested
+}
+
+// RequestHardwareRefresh asks the machine agent to re-detect the
+// machine's hardware characteristics, such as after hardware has been
+// added to a bare metal machine.
+func (m *Machine) RequestHardwareRefresh() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot request hardware refresh for machine %v", m)
+	if _, err := m.InstanceId(); err != nil {
+		return err
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: isAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"hardwarerefreshrequested", true}}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return onAbort(err, errNotAlive)
+	}
+	m.doc.HardwareRefreshRequested = true
+	return nil
+}
+
+// SetHardwareCharacteristics records the hardware characteristics
+// detected by the machine agent, replacing those reported by the
+// provider when the machine was provisioned, and clears any pending
+// hardware refresh request. Characteristics which are not given, such
+// as tags and availability zone, are left unchanged.
+func (m *Machine) SetHardwareCharacteristics(hc instance.HardwareCharacteristics) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set hardware characteristics for machine %v", m)
+	if _, err := m.InstanceId(); err != nil {
+		return err
+	}
+	var set bson.D
+	for _, field := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"mem", hc.Mem},
+		{"rootdisk", hc.RootDisk},
+		{"cpucores", hc.CpuCores},
+		{"cpupower", hc.CpuPower},
+		{"numanodes", hc.NumaNodes},
+		{"gpus", hc.Gpus},
+	} {
+		if field.value != nil {
+			set = append(set, bson.DocElem{field.name, *field.value})
+		}
+	}
+	if hc.Arch != nil {
+		set = append(set, bson.DocElem{"arch", *hc.Arch})
+	}
+	if hc.GpuType != nil {
+		set = append(set, bson.DocElem{"gputype", *hc.GpuType})
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"hardwarerefreshrequested", false}}}},
+	}}
+	if len(set) > 0 {
+		ops = append(ops, txn.Op{
+			C:      instanceDataC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", set}},
+		})
+	}
+	if err := m.st.runTransaction(ops); err != nil {
+		return onAbort(err, ErrDead)
+	}
+	m.doc.HardwareRefreshRequested = false
+	return nil
+}
+
+// SSHHostKeys returns the machine's public ssh host keys, against which
+// clients verify the machine's identity. It returns nil if the machine
+// agent has not yet recorded them.
+func (m *Machine) SSHHostKeys() []string {
+	return m.doc.SSHHostKeys
+}
+
+// SetSSHHostKeys records the machine's public ssh host keys, in the
+// format used in known_hosts and authorized_keys files.
+func (m *Machine) SetSSHHostKeys(keys []string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set ssh host keys for machine %v", m)
+	for _, key := range keys {
+		if _, _, err := ssh.KeyFingerprint(key); err != nil {
+			return errors.NotValidf("ssh host key %q", key)
+		}
+	}
+	ops := []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"sshhostkeys", keys}}}},
+	}}
+	if err := m.st.runTransaction(ops); err != nil {
+		return onAbort(err, ErrDead)
+	}
+	m.doc.SSHHostKeys = keys
+	return nil
+}
+
 func getInstanceData(st *State, id string) (instanceData, error) {
 	instanceDataCollection, closer := st.getCollection(instanceDataC)
 	defer closer()
@@ -661,6 +789,9 @@ func (m *Machine) Remove() (err error) {
 		removeRequestedNetworksOp(m.st, m.globalKey()),
 		annotationRemoveOp(m.st, m.globalKey()),
 		removeRebootDocOp(m.st, m.globalKey()),
+		removeEvacuationOp(m.st, m.Id()),
+		removeOSUpdatesOp(m.st, m.Id()),
+		removeUpgradeSeriesOp(m.st, m.Id()),
 		removeMachineBlockDevicesOp(m.Id()),
 	}
 	ifacesOps, err := m.removeNetworkInterfacesOps()
@@ -671,8 +802,13 @@ func (m *Machine) Remove() (err error) {
 	if err != nil {
 		return err
 	}
+	addressesOps, err := removeContainerAddressesOps(m.st, m.Id())
+	if err != nil {
+		return err
+	}
 	ops = append(ops, ifacesOps...)
 	ops = append(ops, portsOps...)
+	ops = append(ops, addressesOps...)
 	ops = append(ops, removeContainerRefOps(m.st, m.Id())...)
 	// The only abort conditions in play indicate that the machine has already
 	// been removed.
@@ -700,33 +836,26 @@ func (m *Machine) Refresh() error {
 
 // AgentPresence returns whether the respective remote agent is alive.
 func (m *Machine) AgentPresence() (bool, error) {
-	b, err := m.st.pwatcher.Alive(m.globalKey())
-	return b, err
+	return m.st.agentPresence(m.Tag(), m.globalKey())
+}
+
+// AgentLastSeen returns when the machine's agent was last heard
+// from by an API server. It returns a NotFound error if no API
+// server has recorded the agent.
+func (m *Machine) AgentLastSeen() (time.Time, error) {
+	return m.st.agentLastSeen(m.Tag())
 }
 
 // WaitAgentPresence blocks until the respective agent is alive.
 func (m *Machine) WaitAgentPresence(timeout time.Duration) (err error) {
 	defer errors.DeferredAnnotatef(&err, "waiting for agent of machine %v", m)
-	ch := make(chan presence.Change)
-	m.st.pwatcher.Watch(m.globalKey(), ch)
-	defer m.st.pwatcher.Unwatch(m.globalKey(), ch)
-	for i := 0; i < 2; i++ {
-		select {
-		case change := <-ch:
-			if change.Alive {
-				return nil
-			}
-		case <-time.After(timeout):
-			return fmt.Errorf("still not alive after timeout")
-		case <-m.st.pwatcher.Dead():
-			return m.st.pwatcher.Err()
-		}
-	}
-	panic(fmt.Sprintf("presence reported dead status twice in a row for machine %v", m))
+	return m.st.waitAgentPresence(m.Tag(), m.globalKey(), timeout)
 }
 
 // SetAgentPresence signals that the agent for machine m is alive.
-// It returns the started pinger.
+// It returns the started pinger. API servers record the liveness of
+// the agents connected to them with heartbeats instead; pingers
+// remain for agents served by older API servers.
 func (m *Machine) SetAgentPresence() (*presence.Pinger, error) {
 	presenceCollection := m.st.getPresence()
 	p := presence.NewPinger(presenceCollection, m.st.environTag, m.globalKey())
@@ -794,6 +923,24 @@ func (m *Machine) SetInstanceStatus(status string) (err error) {
 	return errors.NotProvisionedf("machine %v", m.Id())
 }
 
+// SetProvisioningStatus records progress made in provisioning the
+// machine in its provisioning status history.
+func (m *Machine) SetProvisioningStatus(status Status, info string, data map[string]interface{}) error {
+	if !status.ValidProvisioningStatus() {
+		return errors.Errorf("cannot set invalid provisioning status %q", status)
+	}
+	if err := m.Refresh(); err != nil {
+		return errors.Annotatef(err, "cannot set provisioning status of machine %q", m)
+	}
+	recordStatusHistory(m.st, m.provisioningGlobalKey(), statusDoc{
+		EnvUUID:    m.st.EnvironUUID(),
+		Status:     status,
+		StatusInfo: info,
+		StatusData: data,
+	})
+	return nil
+}
+
 // AvailabilityZone returns the provier-specific instance availability
 // zone in which the machine was provisioned.
 func (m *Machine) AvailabilityZone() (string, error) {
@@ -865,6 +1012,9 @@ func (m *Machine) SetProvisioned(id instance.Id, nonce string, characteristics *
 		CpuCores:   characteristics.CpuCores,
 		CpuPower:   characteristics.CpuPower,
 		Tags:       characteristics.Tags,
+		NumaNodes:  characteristics.NumaNodes,
+		Gpus:       characteristics.Gpus,
+		GpuType:    characteristics.GpuType,
 		AvailZone:  characteristics.AvailabilityZone,
 	}
 
@@ -1226,12 +1376,13 @@ func (m *Machine) Constraints() (constraints.Value, error) {
 // is already provisioned.
 func (m *Machine) SetConstraints(cons constraints.Value) (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot set constraints")
-	unsupported, err := m.st.validateConstraints(cons)
+	unsupported, err := m.st.ValidateConstraints(cons)
+	if err != nil {
+		return err
+	}
 	if len(unsupported) > 0 {
 		logger.Warningf(
 			"setting constraints on machine %q: unsupported constraints: %v", m.Id(), strings.Join(unsupported, ","))
-	} else if err != nil {
-		return err
 	}
 	notSetYet := bson.D{{"nonce", ""}}
 	ops := []txn.Op{
@@ -1266,6 +1417,87 @@ func (m *Machine) SetConstraints(cons constraints.Value) (err error) {
 	return m.st.run(buildTxn)
 }
 
+// SetSeries changes the series the machine will be provisioned with.
+// It fails if the machine has already been provisioned, or if it has
+// principal units assigned, whose charms depend on the machine's
+// current series.
+func (m *Machine) SetSeries(series string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set series of machine %q", m)
+	if series == "" {
+		return errors.New("series must not be empty")
+	}
+	noPrincipals := bson.DocElem{"$or", []bson.D{
+		{{"principals", bson.D{{"$size", 0}}}},
+		{{"principals", bson.D{{"$exists", false}}}},
+	}}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if m, err = m.st.Machine(m.doc.Id); err != nil {
+				return nil, err
+			}
+		}
+		if err := m.assertProvisionable(); err != nil {
+			return nil, err
+		}
+		if len(m.doc.Principals) > 0 {
+			return nil, errors.New("machine has units assigned")
+		}
+		return []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: append(isAliveDoc, bson.DocElem{"nonce", ""}, noPrincipals),
+			Update: bson.D{{"$set", bson.D{{"series", series}}}},
+		}}, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return err
+	}
+	m.doc.Series = series
+	return nil
+}
+
+// SetPlacement changes the placement directive that will be used when
+// provisioning the machine. It fails if the machine has already been
+// provisioned.
+func (m *Machine) SetPlacement(placement string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set placement of machine %q", m)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if m, err = m.st.Machine(m.doc.Id); err != nil {
+				return nil, err
+			}
+		}
+		if err := m.assertProvisionable(); err != nil {
+			return nil, err
+		}
+		return []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: append(isAliveDoc, bson.DocElem{"nonce", ""}),
+			Update: bson.D{{"$set", bson.D{{"placement", placement}}}},
+		}}, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return err
+	}
+	m.doc.Placement = placement
+	return nil
+}
+
+// assertProvisionable returns an error if the machine is not alive
+// or has already been provisioned.
+func (m *Machine) assertProvisionable() error {
+	if m.doc.Life != Alive {
+		return errNotAlive
+	}
+	if _, err := m.InstanceId(); err == nil {
+		return errors.New("machine is already provisioned")
+	} else if !errors.IsNotProvisioned(err) {
+		return err
+	}
+	return nil
+}
+
 // Status returns the status of the machine.
 func (m *Machine) Status() (status Status, info string, data map[string]interface{}, err error) {
 	doc, err := getStatus(m.st, m.globalKey())
@@ -1298,6 +1530,15 @@ func (m *Machine) SetStatus(status Status, info string, data map[string]interfac
 	if err = m.st.runTransaction(ops); err != nil {
 		return fmt.Errorf("cannot set status of machine %q: %v", m, onAbort(err, errNotAlive))
 	}
+	recordStatusHistory(m.st, m.globalKey(), doc.statusDoc)
+	if status == StatusStarted {
+		// The machine agent reports itself started, which marks
+		// the end of provisioning the machine.
+		recordStatusHistory(m.st, m.provisioningGlobalKey(), statusDoc{
+			EnvUUID: m.st.EnvironUUID(),
+			Status:  StatusAgentStarted,
+		})
+	}
 	return nil
 }
 
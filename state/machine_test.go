@@ -27,6 +27,7 @@ import (
 	"github.com/juju/juju/storage/provider"
 	"github.com/juju/juju/storage/provider/registry"
 	coretesting "github.com/juju/juju/testing"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 	"github.com/juju/juju/version"
 )
 
@@ -900,6 +901,82 @@ func (s *MachineSuite) TestMachineSetCheckProvisioned(c *gc.C) {
 	c.Assert(s.machine.CheckProvisioned("not-really"), jc.IsFalse)
 }
 
+func (s *MachineSuite) TestRequestHardwareRefresh(c *gc.C) {
+	err := s.machine.RequestHardwareRefresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+	c.Assert(s.machine.HardwareRefreshRequested(), jc.IsFalse)
+
+	err = s.machine.SetProvisioned("umbrella/0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.HardwareRefreshRequested(), jc.IsTrue)
+
+	m, err := s.State.Machine(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.HardwareRefreshRequested(), jc.IsTrue)
+}
+
+func (s *MachineSuite) TestSetHardwareCharacteristics(c *gc.C) {
+	err := s.machine.SetHardwareCharacteristics(instance.MustParseHardware("mem=8G"))
+	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
+
+	provisioned := instance.MustParseHardware("arch=amd64 mem=4G cpu-cores=2 tags=foo availability-zone=a_zone")
+	err = s.machine.SetProvisioned("umbrella/0", "fake_nonce", &provisioned)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.SetHardwareCharacteristics(instance.MustParseHardware("arch=amd64 mem=16G cpu-cores=8 numa-nodes=2 gpus=1"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.HardwareRefreshRequested(), jc.IsFalse)
+
+	m, err := s.State.Machine(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.HardwareRefreshRequested(), jc.IsFalse)
+	hc, err := m.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*hc, jc.DeepEquals, instance.MustParseHardware(
+		"arch=amd64 mem=16G cpu-cores=8 numa-nodes=2 gpus=1 tags=foo availability-zone=a_zone",
+	))
+}
+
+func (s *MachineSuite) TestSetHardwareCharacteristicsDead(c *gc.C) {
+	err := s.machine.SetProvisioned("umbrella/0", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.RequestHardwareRefresh()
+	c.Assert(err, gc.ErrorMatches, `cannot request hardware refresh for machine 1: not found or not alive`)
+	err = s.machine.SetHardwareCharacteristics(instance.MustParseHardware("mem=8G"))
+	c.Assert(err, gc.ErrorMatches, `cannot set hardware characteristics for machine 1: not found or dead`)
+}
+
+func (s *MachineSuite) TestSetSSHHostKeys(c *gc.C) {
+	c.Assert(s.machine.SSHHostKeys(), gc.HasLen, 0)
+	keys := []string{sshtesting.ValidKeyOne.Key + " root@host", sshtesting.ValidKeyTwo.Key}
+	err := s.machine.SetSSHHostKeys(keys)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.SSHHostKeys(), jc.DeepEquals, keys)
+
+	m, err := s.State.Machine(s.machine.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(m.SSHHostKeys(), jc.DeepEquals, keys)
+}
+
+func (s *MachineSuite) TestSetSSHHostKeysInvalid(c *gc.C) {
+	err := s.machine.SetSSHHostKeys([]string{"bad key"})
+	c.Assert(err, gc.ErrorMatches, `cannot set ssh host keys for machine 1: ssh host key "bad key" not valid`)
+	c.Assert(s.machine.SSHHostKeys(), gc.HasLen, 0)
+}
+
+func (s *MachineSuite) TestSetSSHHostKeysDead(c *gc.C) {
+	err := s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetSSHHostKeys([]string{sshtesting.ValidKeyOne.Key})
+	c.Assert(err, gc.ErrorMatches, `cannot set ssh host keys for machine 1: not found or dead`)
+}
+
 func (s *MachineSuite) TestMachineSetInstanceInfoFailureDoesNotProvision(c *gc.C) {
 	assertNotProvisioned := func() {
 		c.Assert(s.machine.CheckProvisioned("fake_nonce"), jc.IsFalse)
@@ -1482,6 +1559,51 @@ func (s *MachineSuite) TestSetConstraints(c *gc.C) {
 	c.Assert(mcons, gc.DeepEquals, cons1)
 }
 
+func (s *MachineSuite) TestSetSeries(c *gc.C) {
+	err := s.machine.SetSeries("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Series(), gc.Equals, "trusty")
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Series(), gc.Equals, "trusty")
+
+	err = s.machine.SetSeries("")
+	c.Assert(err, gc.ErrorMatches, `cannot set series of machine "1": series must not be empty`)
+
+	err = s.machine.SetProvisioned("i-mstuck", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetSeries("precise")
+	c.Assert(err, gc.ErrorMatches, `cannot set series of machine "1": machine is already provisioned`)
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Series(), gc.Equals, "trusty")
+}
+
+func (s *MachineSuite) TestSetSeriesWithUnits(c *gc.C) {
+	svc := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(s.machine)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.machine.SetSeries("trusty")
+	c.Assert(err, gc.ErrorMatches, `cannot set series of machine "1": machine has units assigned`)
+}
+
+func (s *MachineSuite) TestSetPlacement(c *gc.C) {
+	err := s.machine.SetPlacement("zone=a-zone")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Placement(), gc.Equals, "zone=a-zone")
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Placement(), gc.Equals, "zone=a-zone")
+
+	err = s.machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetPlacement("zone=another-zone")
+	c.Assert(err, gc.ErrorMatches, `cannot set placement of machine "1": not found or not alive`)
+}
+
 func (s *MachineSuite) TestSetAmbiguousConstraints(c *gc.C) {
 	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
 	c.Assert(err, jc.ErrorIsNil)
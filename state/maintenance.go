@@ -0,0 +1,202 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// maintenanceOverrideKey is the id of the document holding the
+// override of an environment's maintenance window.
+const maintenanceOverrideKey = "override"
+
+// maintenanceOverrideDoc records a temporary override of an
+// environment's maintenance window.
+type maintenanceOverrideDoc struct {
+	DocID   string    `bson:"_id"`
+	EnvUUID string    `bson:"env-uuid"`
+	Open    bool      `bson:"open"`
+	Until   time.Time `bson:"until"`
+}
+
+// MaintenanceOverride describes a temporary override of an
+// environment's maintenance window.
+type MaintenanceOverride struct {
+	// Open holds whether the window is held open, allowing
+	// disruptive operations, or held closed.
+	Open bool
+
+	// Until holds when the override expires.
+	Until time.Time
+}
+
+// MaintenanceWindowStatus describes the state of an environment's
+// maintenance window at some time.
+type MaintenanceWindowStatus struct {
+	// Schedule holds the cron expression giving when the window
+	// opens, or is empty if no window is set and disruptive
+	// operations may run at any time.
+	Schedule string
+
+	// Duration holds how long the window stays open each time it
+	// opens.
+	Duration time.Duration
+
+	// Override holds the override in effect, if any.
+	Override *MaintenanceOverride
+
+	// Open holds whether disruptive operations may run.
+	Open bool
+
+	// Until holds when Open will next change, taking any override
+	// into account, or the zero time if it will not change.
+	Until time.Time
+}
+
+// MaintenanceWindow returns the state of the environment's maintenance
+// window at the given time.
+func (st *State) MaintenanceWindow(now time.Time) (MaintenanceWindowStatus, error) {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	schedule, err := cfg.MaintenanceWindow()
+	if err != nil {
+		return MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	duration, err := cfg.MaintenanceWindowDuration()
+	if err != nil {
+		return MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	override, err := st.maintenanceOverride(now)
+	if err != nil {
+		return MaintenanceWindowStatus{}, errors.Trace(err)
+	}
+	status := MaintenanceWindowStatus{
+		Duration: duration,
+		Override: override,
+		Open:     true,
+	}
+	if schedule != nil {
+		status.Schedule = schedule.String()
+		// The window is open if it opened within the last duration.
+		if opened := schedule.Next(now.Add(-duration)); !opened.IsZero() && !opened.After(now) {
+			status.Until = opened.Add(duration)
+		} else {
+			status.Open = false
+			status.Until = schedule.Next(now)
+		}
+	}
+	if override != nil {
+		status.Open = override.Open
+		status.Until = override.Until
+	}
+	return status, nil
+}
+
+// maintenanceOverride returns the override of the environment's
+// maintenance window in effect at the given time, or nil if there is
+// none.
+func (st *State) maintenanceOverride(now time.Time) (*MaintenanceOverride, error) {
+	maintenance, closer := st.getCollection(maintenanceC)
+	defer closer()
+
+	var doc maintenanceOverrideDoc
+	err := maintenance.FindId(maintenanceOverrideKey).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot read maintenance window override")
+	}
+	if !doc.Until.After(now) {
+		return nil, nil
+	}
+	return &MaintenanceOverride{Open: doc.Open, Until: doc.Until.UTC()}, nil
+}
+
+// SetMaintenanceOverride holds the environment's maintenance window
+// open or closed, regardless of its schedule, until the given time.
+// It replaces any override already set.
+func (st *State) SetMaintenanceOverride(override MaintenanceOverride) error {
+	if override.Until.IsZero() {
+		return errors.NotValidf("maintenance window override without expiry")
+	}
+	docID := st.docID(maintenanceOverrideKey)
+	until := override.Until.UTC()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		exists, err := st.maintenanceOverrideExists()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if exists {
+			return []txn.Op{{
+				C:      maintenanceC,
+				Id:     docID,
+				Assert: txn.DocExists,
+				Update: bson.D{{"$set", bson.D{
+					{"open", override.Open},
+					{"until", until},
+				}}},
+			}}, nil
+		}
+		return []txn.Op{{
+			C:      maintenanceC,
+			Id:     docID,
+			Assert: txn.DocMissing,
+			Insert: &maintenanceOverrideDoc{
+				DocID:   docID,
+				EnvUUID: st.EnvironUUID(),
+				Open:    override.Open,
+				Until:   until,
+			},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot set maintenance window override")
+	}
+	return nil
+}
+
+// ClearMaintenanceOverride removes any override of the environment's
+// maintenance window.
+func (st *State) ClearMaintenanceOverride() error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		exists, err := st.maintenanceOverrideExists()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !exists {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      maintenanceC,
+			Id:     st.docID(maintenanceOverrideKey),
+			Assert: txn.DocExists,
+			Remove: true,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot clear maintenance window override")
+	}
+	return nil
+}
+
+// maintenanceOverrideExists returns whether the environment's
+// maintenance window override document exists, expired or not.
+func (st *State) maintenanceOverrideExists() (bool, error) {
+	maintenance, closer := st.getCollection(maintenanceC)
+	defer closer()
+
+	count, err := maintenance.FindId(maintenanceOverrideKey).Count()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type MaintenanceSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&MaintenanceSuite{})
+
+// At 02:00 UTC every day, for two hours.
+var nightlyWindow = map[string]interface{}{
+	"maintenance-window":          "0 2 * * *",
+	"maintenance-window-duration": "2h",
+}
+
+func (s *MaintenanceSuite) setConfig(c *gc.C, attrs map[string]interface{}) {
+	err := s.State.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MaintenanceSuite) TestNoWindowAlwaysOpen(c *gc.C) {
+	status, err := s.State.MaintenanceWindow(time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.MaintenanceWindowStatus{
+		Duration: time.Hour,
+		Open:     true,
+	})
+}
+
+func (s *MaintenanceSuite) TestWindowClosed(c *gc.C) {
+	s.setConfig(c, nightlyWindow)
+	status, err := s.State.MaintenanceWindow(time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, jc.DeepEquals, state.MaintenanceWindowStatus{
+		Schedule: "0 2 * * *",
+		Duration: 2 * time.Hour,
+		Open:     false,
+		Until:    time.Date(2015, 6, 2, 2, 0, 0, 0, time.UTC),
+	})
+}
+
+func (s *MaintenanceSuite) TestWindowOpen(c *gc.C) {
+	s.setConfig(c, nightlyWindow)
+	for _, now := range []time.Time{
+		time.Date(2015, 6, 1, 2, 0, 0, 0, time.UTC),
+		time.Date(2015, 6, 1, 3, 59, 0, 0, time.UTC),
+	} {
+		c.Logf("at %v", now)
+		status, err := s.State.MaintenanceWindow(now)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(status.Open, jc.IsTrue)
+		c.Assert(status.Until, gc.Equals, time.Date(2015, 6, 1, 4, 0, 0, 0, time.UTC))
+	}
+	status, err := s.State.MaintenanceWindow(time.Date(2015, 6, 1, 4, 0, 0, 0, time.UTC))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Open, jc.IsFalse)
+}
+
+func (s *MaintenanceSuite) TestOverride(c *gc.C) {
+	s.setConfig(c, nightlyWindow)
+	until := time.Now().Add(time.Hour).UTC().Round(time.Second)
+	err := s.State.SetMaintenanceOverride(state.MaintenanceOverride{Open: true, Until: until})
+	c.Assert(err, jc.ErrorIsNil)
+
+	now := until.Add(-time.Minute)
+	status, err := s.State.MaintenanceWindow(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Open, jc.IsTrue)
+	c.Assert(status.Until, gc.Equals, until)
+	c.Assert(status.Override, jc.DeepEquals, &state.MaintenanceOverride{Open: true, Until: until})
+
+	// A new override replaces the old one.
+	err = s.State.SetMaintenanceOverride(state.MaintenanceOverride{Open: false, Until: until})
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.State.MaintenanceWindow(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Open, jc.IsFalse)
+
+	// Expired overrides are ignored.
+	status, err = s.State.MaintenanceWindow(until)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Override, gc.IsNil)
+
+	err = s.State.ClearMaintenanceOverride()
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = s.State.MaintenanceWindow(now)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Override, gc.IsNil)
+
+	// Clearing again is not an error.
+	err = s.State.ClearMaintenanceOverride()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MaintenanceSuite) TestOverrideNeedsExpiry(c *gc.C) {
+	err := s.State.SetMaintenanceOverride(state.MaintenanceOverride{Open: true})
+	c.Assert(err, gc.ErrorMatches, "maintenance window override without expiry not valid")
+}
@@ -0,0 +1,258 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// ManualHostStatus describes the progress of the manual provisioning
+// of a host.
+type ManualHostStatus string
+
+const (
+	// ManualHostPending indicates that the host is waiting to be
+	// provisioned, either for the first time or after a failed
+	// attempt.
+	ManualHostPending ManualHostStatus = "pending"
+
+	// ManualHostProvisioning indicates that the host is being
+	// provisioned.
+	ManualHostProvisioning ManualHostStatus = "provisioning"
+
+	// ManualHostProvisioned indicates that a machine agent has been
+	// installed on the host.
+	ManualHostProvisioned ManualHostStatus = "provisioned"
+
+	// ManualHostFailed indicates that the host could not be
+	// provisioned, and will not be tried again.
+	ManualHostFailed ManualHostStatus = "failed"
+)
+
+// manualHostDoc records a request to provision an existing host over
+// SSH, and its progress.
+type manualHostDoc struct {
+	DocID   string `bson:"_id"`
+	Id      string `bson:"id"`
+	EnvUUID string `bson:"env-uuid"`
+
+	// Host is the hostname or address of the host.
+	Host string `bson:"host"`
+
+	// DisablePackageCommands, if true, prevents package updates
+	// and upgrades on the host regardless of environment settings.
+	DisablePackageCommands bool `bson:"disablepackagecommands"`
+
+	Status   ManualHostStatus `bson:"status"`
+	Message  string           `bson:"message"`
+	Attempts int              `bson:"attempts"`
+	Updated  time.Time        `bson:"updated"`
+
+	// MachineId and Nonce identify the machine recorded for the
+	// host, once it has been inspected.
+	MachineId string `bson:"machineid,omitempty"`
+	Nonce     string `bson:"nonce,omitempty"`
+}
+
+// ManualHost represents a request to provision an existing host as a
+// machine in the environment.
+type ManualHost struct {
+	st  *State
+	doc manualHostDoc
+}
+
+// Id returns the id of the request.
+func (h *ManualHost) Id() string {
+	return h.doc.Id
+}
+
+// Host returns the hostname or address of the host.
+func (h *ManualHost) Host() string {
+	return h.doc.Host
+}
+
+// DisablePackageCommands returns whether package updates and upgrades
+// are to be skipped when provisioning the host.
+func (h *ManualHost) DisablePackageCommands() bool {
+	return h.doc.DisablePackageCommands
+}
+
+// Status returns the progress of the request, with a message
+// describing the current step or the reason for failure.
+func (h *ManualHost) Status() (ManualHostStatus, string) {
+	return h.doc.Status, h.doc.Message
+}
+
+// Attempts returns the number of attempts made to provision the host
+// that have failed.
+func (h *ManualHost) Attempts() int {
+	return h.doc.Attempts
+}
+
+// Updated returns the time the request last changed.
+func (h *ManualHost) Updated() time.Time {
+	return h.doc.Updated
+}
+
+// Machine returns the id and nonce of the machine recorded for the
+// host, or empty strings if none has been recorded.
+func (h *ManualHost) Machine() (machineId, nonce string) {
+	return h.doc.MachineId, h.doc.Nonce
+}
+
+// SetProgress records the status of the request and a message
+// describing the current step.
+func (h *ManualHost) SetProgress(status ManualHostStatus, message string) error {
+	update := bson.D{
+		{"status", status},
+		{"message", message},
+		{"updated", time.Now()},
+	}
+	if err := h.update(bson.D{{"$set", update}}); err != nil {
+		return errors.Annotatef(err, "cannot set progress of manual host %q", h.doc.Host)
+	}
+	h.doc.Status = status
+	h.doc.Message = message
+	return nil
+}
+
+// SetMachine records the machine created for the host.
+func (h *ManualHost) SetMachine(machineId, nonce string) error {
+	update := bson.D{{"$set", bson.D{
+		{"machineid", machineId},
+		{"nonce", nonce},
+	}}}
+	if err := h.update(update); err != nil {
+		return errors.Annotatef(err, "cannot set machine of manual host %q", h.doc.Host)
+	}
+	h.doc.MachineId = machineId
+	h.doc.Nonce = nonce
+	return nil
+}
+
+// AttemptFailed records a failed attempt to provision the host. The
+// request is returned to pending so that it is tried again, unless
+// retry is false, in which case it is marked as failed.
+func (h *ManualHost) AttemptFailed(reason error, retry bool) error {
+	status := ManualHostPending
+	message := fmt.Sprintf("attempt %d failed: %v", h.doc.Attempts+1, reason)
+	if !retry {
+		status = ManualHostFailed
+		message = reason.Error()
+	}
+	update := bson.D{
+		{"$set", bson.D{
+			{"status", status},
+			{"message", message},
+			{"updated", time.Now()},
+		}},
+		{"$inc", bson.D{{"attempts", 1}}},
+	}
+	if err := h.update(update); err != nil {
+		return errors.Annotatef(err, "cannot record failure of manual host %q", h.doc.Host)
+	}
+	h.doc.Status = status
+	h.doc.Message = message
+	h.doc.Attempts++
+	return nil
+}
+
+func (h *ManualHost) update(update bson.D) error {
+	ops := []txn.Op{{
+		C:      manualHostsC,
+		Id:     h.doc.DocID,
+		Assert: txn.DocExists,
+		Update: update,
+	}}
+	err := h.st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("manual host %q", h.doc.Id)
+	}
+	return err
+}
+
+// AddManualHost records a request to provision the given host, which
+// must already be reachable over SSH as the ubuntu user using the
+// environment's system key. It fails if the host is already being
+// provisioned.
+func (st *State) AddManualHost(host string, disablePackageCommands bool) (*ManualHost, error) {
+	existing, err := st.manualHostsWithStatus(ManualHostPending, ManualHostProvisioning)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, h := range existing {
+		if h.doc.Host == host {
+			return nil, errors.AlreadyExistsf("provisioning request %s for host %q", h.doc.Id, host)
+		}
+	}
+	seq, err := st.sequence("manualhost")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id := strconv.Itoa(seq)
+	doc := manualHostDoc{
+		DocID:                  st.docID(id),
+		Id:                     id,
+		EnvUUID:                st.EnvironUUID(),
+		Host:                   host,
+		DisablePackageCommands: disablePackageCommands,
+		Status:                 ManualHostPending,
+		Message:                "waiting to be provisioned",
+		Updated:                time.Now(),
+	}
+	ops := []txn.Op{{
+		C:      manualHostsC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return nil, errors.Annotatef(err, "cannot add manual host %q", host)
+	}
+	return &ManualHost{st: st, doc: doc}, nil
+}
+
+// ManualHost returns the manual provisioning request with the given id.
+func (st *State) ManualHost(id string) (*ManualHost, error) {
+	manualHosts, closer := st.getCollection(manualHostsC)
+	defer closer()
+
+	var doc manualHostDoc
+	err := manualHosts.FindId(st.docID(id)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("manual host %q", id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get manual host %q", id)
+	}
+	return &ManualHost{st: st, doc: doc}, nil
+}
+
+// PendingManualHosts returns the manual provisioning requests that are
+// waiting to be provisioned, or were interrupted while provisioning.
+func (st *State) PendingManualHosts() ([]*ManualHost, error) {
+	return st.manualHostsWithStatus(ManualHostPending, ManualHostProvisioning)
+}
+
+func (st *State) manualHostsWithStatus(statuses ...ManualHostStatus) ([]*ManualHost, error) {
+	manualHosts, closer := st.getCollection(manualHostsC)
+	defer closer()
+
+	var docs []manualHostDoc
+	query := bson.D{{"status", bson.D{{"$in", statuses}}}}
+	if err := manualHosts.Find(query).Sort("updated").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get manual hosts")
+	}
+	result := make([]*ManualHost, len(docs))
+	for i, doc := range docs {
+		result[i] = &ManualHost{st: st, doc: doc}
+	}
+	return result, nil
+}
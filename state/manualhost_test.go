@@ -0,0 +1,99 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type ManualHostSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&ManualHostSuite{})
+
+func (s *ManualHostSuite) TestAddManualHost(c *gc.C) {
+	host, err := s.State.AddManualHost("10.0.0.1", true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(host.Id(), gc.Equals, "0")
+	c.Assert(host.Host(), gc.Equals, "10.0.0.1")
+	c.Assert(host.DisablePackageCommands(), jc.IsTrue)
+	c.Assert(host.Attempts(), gc.Equals, 0)
+	status, message := host.Status()
+	c.Assert(status, gc.Equals, state.ManualHostPending)
+	c.Assert(message, gc.Equals, "waiting to be provisioned")
+
+	_, err = s.State.AddManualHost("10.0.0.1", false)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	c.Assert(err, gc.ErrorMatches, `provisioning request 0 for host "10.0.0.1" already exists`)
+
+	other, err := s.State.AddManualHost("10.0.0.2", false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other.Id(), gc.Equals, "1")
+
+	pending, err := s.State.PendingManualHosts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 2)
+}
+
+func (s *ManualHostSuite) TestProgress(c *gc.C) {
+	host, err := s.State.AddManualHost("10.0.0.1", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = host.SetProgress(state.ManualHostProvisioning, "installing tools")
+	c.Assert(err, jc.ErrorIsNil)
+	err = host.SetMachine("3", "manual:10.0.0.1:nonce")
+	c.Assert(err, jc.ErrorIsNil)
+
+	host, err = s.State.ManualHost(host.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	status, message := host.Status()
+	c.Assert(status, gc.Equals, state.ManualHostProvisioning)
+	c.Assert(message, gc.Equals, "installing tools")
+	machineId, nonce := host.Machine()
+	c.Assert(machineId, gc.Equals, "3")
+	c.Assert(nonce, gc.Equals, "manual:10.0.0.1:nonce")
+
+	err = host.SetProgress(state.ManualHostProvisioned, "machine 3 provisioned")
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err := s.State.PendingManualHosts()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
+
+	// The host may be provisioned again once the earlier request
+	// has finished.
+	_, err = s.State.AddManualHost("10.0.0.1", false)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ManualHostSuite) TestAttemptFailed(c *gc.C) {
+	host, err := s.State.AddManualHost("10.0.0.1", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = host.AttemptFailed(errors.New("connection refused"), true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(host.Attempts(), gc.Equals, 1)
+	status, message := host.Status()
+	c.Assert(status, gc.Equals, state.ManualHostPending)
+	c.Assert(message, gc.Equals, "attempt 1 failed: connection refused")
+
+	err = host.AttemptFailed(errors.New("connection refused"), false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	host, err = s.State.ManualHost(host.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(host.Attempts(), gc.Equals, 2)
+	status, message = host.Status()
+	c.Assert(status, gc.Equals, state.ManualHostFailed)
+	c.Assert(message, gc.Equals, "connection refused")
+}
+
+func (s *ManualHostSuite) TestManualHostNotFound(c *gc.C) {
+	_, err := s.State.ManualHost("42")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -243,10 +243,20 @@ func (svc *backingService) updated(st *State, store *multiwatcherStore, id inter
 		}
 		info.Constraints = c
 		needConfig = true
+		// The status is only recorded once the service has units.
+		sdoc, err := getStatus(st, serviceGlobalKey(svc.Name))
+		if err == nil {
+			info.Status = multiwatcher.Status(sdoc.Status)
+			info.StatusInfo = sdoc.StatusInfo
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
 	} else {
 		// The entry already exists, so preserve the current status.
 		oldInfo := oldInfo.(*multiwatcher.ServiceInfo)
 		info.Constraints = oldInfo.Constraints
+		info.Status = oldInfo.Status
+		info.StatusInfo = oldInfo.StatusInfo
 		if info.CharmURL == oldInfo.CharmURL {
 			// The charm URL remains the same - we can continue to
 			// use the same config settings.
@@ -424,6 +434,11 @@ func (s *backingStatus) updated(st *State, store *multiwatcherStore, id interfac
 		newInfo.StatusInfo = s.StatusInfo
 		newInfo.StatusData = s.StatusData
 		info0 = &newInfo
+	case *multiwatcher.ServiceInfo:
+		newInfo := *info
+		newInfo.Status = multiwatcher.Status(s.Status)
+		newInfo.StatusInfo = s.StatusInfo
+		info0 = &newInfo
 	default:
 		panic(fmt.Errorf("status for unexpected entity with id %q; type %T", id, info))
 	}
@@ -193,6 +193,12 @@ func (s *storeManagerStateSuite) setUpScenario(c *gc.C, st *State, units int) (e
 	err = wordpress.SetConstraints(constraints.MustParse("mem=100M"))
 	c.Assert(err, jc.ErrorIsNil)
 	setServiceConfigAttr(c, wordpress, "blog-title", "boring")
+	// The services' statuses are rolled up from those of the units
+	// added below.
+	var serviceStatus multiwatcher.Status
+	if units > 0 {
+		serviceStatus = multiwatcher.Status("busy")
+	}
 	add(&multiwatcher.ServiceInfo{
 		Name:        "wordpress",
 		Exposed:     true,
@@ -203,6 +209,7 @@ func (s *storeManagerStateSuite) setUpScenario(c *gc.C, st *State, units int) (e
 		Constraints: constraints.MustParse("mem=100M"),
 		Config:      charm.Settings{"blog-title": "boring"},
 		Subordinate: false,
+		Status:      serviceStatus,
 	})
 	pairs := map[string]string{"x": "12", "y": "99"}
 	err = st.SetAnnotations(wordpress, pairs)
@@ -220,6 +227,7 @@ func (s *storeManagerStateSuite) setUpScenario(c *gc.C, st *State, units int) (e
 		Life:        multiwatcher.Life("alive"),
 		Config:      charm.Settings{},
 		Subordinate: true,
+		Status:      serviceStatus,
 	})
 
 	eps, err := st.InferEndpoints("logging", "wordpress")
@@ -1666,6 +1674,7 @@ func (s *storeManagerStateSuite) TestStateWatcher(c *gc.C) {
 			OwnerTag: s.owner.String(),
 			Life:     "alive",
 			Config:   make(map[string]interface{}),
+			Status:   "busy",
 		},
 	}, {
 		Entity: &multiwatcher.UnitInfo{
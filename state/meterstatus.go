@@ -4,9 +4,13 @@
 package state
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 )
@@ -24,6 +28,73 @@ const (
 	MeterRed          MeterStatusCode = "RED"
 )
 
+// severity returns how bad the meter status code is, relative to the
+// other codes; higher values are worse.
+func (code MeterStatusCode) severity() int {
+	switch code {
+	case MeterRed:
+		return 4
+	case MeterAmber:
+		return 3
+	case MeterGreen:
+		return 2
+	case MeterNotAvailable:
+		return 1
+	}
+	return 0
+}
+
+// MeterStatus holds the meter status code of a unit, or of a service,
+// and its accompanying information.
+type MeterStatus struct {
+	Code MeterStatusCode
+	Info string
+}
+
+// CombineMeterStatuses returns the worst of the given unit meter
+// statuses, keyed by unit name, with the name of the unit it belongs
+// to prefixed to its information. When several units share the worst
+// code, the unit with the lowest name is reported. If there are no
+// statuses, the result has code MeterNotSet.
+func CombineMeterStatuses(statuses map[string]MeterStatus) MeterStatus {
+	worst := MeterStatus{Code: MeterNotSet}
+	var worstUnit string
+	for unitName, status := range statuses {
+		switch {
+		case worstUnit == "":
+		case status.Code.severity() > worst.Code.severity():
+		case status.Code.severity() == worst.Code.severity() && unitNameLessThan(unitName, worstUnit):
+		default:
+			continue
+		}
+		worst, worstUnit = status, unitName
+	}
+	if worstUnit != "" && worst.Info != "" {
+		worst.Info = worstUnit + ": " + worst.Info
+	}
+	return worst
+}
+
+// unitNameLessThan reports whether the unit named a sorts before the
+// unit named b, comparing unit numbers numerically.
+func unitNameLessThan(a, b string) bool {
+	aService, aNumber := splitUnitName(a)
+	bService, bNumber := splitUnitName(b)
+	if aService != bService {
+		return aService < bService
+	}
+	return aNumber < bNumber
+}
+
+func splitUnitName(name string) (string, int) {
+	i := strings.LastIndex(name, "/")
+	if i < 0 {
+		return name, 0
+	}
+	number, _ := strconv.Atoi(name[i+1:])
+	return name[:i], number
+}
+
 type meterStatusDoc struct {
 	DocID   string          `bson:"_id"`
 	EnvUUID string          `bson:"env-uuid"`
@@ -107,6 +178,48 @@ func (u *Unit) GetMeterStatus() (code, info string, err error) {
 	return string(status.Code), status.Info, nil
 }
 
+// MeterStatus returns the worst meter status of the service's units,
+// as described by CombineMeterStatuses.
+func (s *Service) MeterStatus() (MeterStatus, error) {
+	units, err := s.AllUnits()
+	if err != nil {
+		return MeterStatus{}, errors.Trace(err)
+	}
+	statuses := make(map[string]MeterStatus)
+	for _, unit := range units {
+		doc, err := unit.getMeterStatusDoc()
+		if errors.Cause(err) == mgo.ErrNotFound {
+			// The unit has been removed since it was listed.
+			continue
+		} else if err != nil {
+			return MeterStatus{}, errors.Annotatef(err, "cannot retrieve meter status for unit %s", unit.Name())
+		}
+		statuses[unit.Name()] = MeterStatus{Code: doc.Code, Info: doc.Info}
+	}
+	return CombineMeterStatuses(statuses), nil
+}
+
+// AllMeterStatuses returns the meter statuses of all the units in the
+// environment, keyed by unit name.
+func (st *State) AllMeterStatuses() (map[string]MeterStatus, error) {
+	meterStatuses, closer := st.getCollection(meterStatusC)
+	defer closer()
+	var docs []meterStatusDoc
+	if err := meterStatuses.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot retrieve meter statuses")
+	}
+	statuses := make(map[string]MeterStatus)
+	for _, doc := range docs {
+		globalKey := st.localID(doc.DocID)
+		if !strings.HasPrefix(globalKey, "u#") || !strings.HasSuffix(globalKey, "#charm") {
+			continue
+		}
+		unitName := strings.TrimSuffix(strings.TrimPrefix(globalKey, "u#"), "#charm")
+		statuses[unitName] = MeterStatus{Code: doc.Code, Info: doc.Info}
+	}
+	return statuses, nil
+}
+
 func (u *Unit) getMeterStatusDoc() (*meterStatusDoc, error) {
 	meterStatuses, closer := u.st.getCollection(meterStatusC)
 	defer closer()
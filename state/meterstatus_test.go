@@ -9,6 +9,7 @@ import (
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
 	"github.com/juju/juju/testing/factory"
 )
 
@@ -93,3 +94,82 @@ func (s *UnitSuite) TestMeterStatusRemovedWithUnit(c *gc.C) {
 	c.Assert(code, gc.Equals, "NOT AVAILABLE")
 	c.Assert(info, gc.Equals, "")
 }
+
+func (s *MeterStateSuite) TestServiceMeterStatus(c *gc.C) {
+	service, err := s.unit.Service()
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := service.MeterStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.MeterStatus{Code: state.MeterNotSet})
+
+	unit1 := s.factory.MakeUnit(c, &factory.UnitParams{Service: service})
+	unit2 := s.factory.MakeUnit(c, &factory.UnitParams{Service: service})
+	err = s.unit.SetMeterStatus("GREEN", "all good")
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit1.SetMeterStatus("AMBER", "payment due")
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit2.SetMeterStatus("GREEN", "")
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = service.MeterStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.MeterStatus{
+		Code: state.MeterAmber,
+		Info: unit1.Name() + ": payment due",
+	})
+
+	err = unit2.SetMeterStatus("RED", "payment overdue")
+	c.Assert(err, jc.ErrorIsNil)
+	status, err = service.MeterStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.MeterStatus{
+		Code: state.MeterRed,
+		Info: unit2.Name() + ": payment overdue",
+	})
+}
+
+func (s *MeterStateSuite) TestCombineMeterStatuses(c *gc.C) {
+	status := state.CombineMeterStatuses(map[string]state.MeterStatus{
+		"mysql/10": {Code: state.MeterRed, Info: "second"},
+		"mysql/2":  {Code: state.MeterRed, Info: "first"},
+		"mysql/0":  {Code: state.MeterAmber, Info: "warning"},
+		"mysql/1":  {Code: state.MeterNotSet},
+	})
+	c.Assert(status, gc.Equals, state.MeterStatus{Code: state.MeterRed, Info: "mysql/2: first"})
+
+	status = state.CombineMeterStatuses(nil)
+	c.Assert(status, gc.Equals, state.MeterStatus{Code: state.MeterNotSet})
+}
+
+func (s *MeterStateSuite) TestAllMeterStatuses(c *gc.C) {
+	unit := s.factory.MakeUnit(c, nil)
+	err := unit.SetMeterStatus("RED", "payment overdue")
+	c.Assert(err, jc.ErrorIsNil)
+	statuses, err := s.State.AllMeterStatuses()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses, jc.DeepEquals, map[string]state.MeterStatus{
+		s.unit.Name(): {Code: state.MeterNotSet},
+		unit.Name():   {Code: state.MeterRed, Info: "payment overdue"},
+	})
+}
+
+func (s *MeterStateSuite) TestWatchMeterStatuses(c *gc.C) {
+	w := s.State.WatchMeterStatuses()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.unit.SetMeterStatus("AMBER", "payment due")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// Setting the same status again does not cause a change.
+	err = s.unit.SetMeterStatus("AMBER", "payment due")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	s.factory.MakeUnit(c, nil)
+	wc.AssertOneChange()
+
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -42,8 +42,35 @@ type metricBatchDoc struct {
 	Created     time.Time `bson:"created"`
 	Metrics     []Metric  `bson:"metrics"`
 	Credentials []byte    `bson:"credentials"`
+
+	// DeliveryAttempts, LastAttempt and DeliveryError record the
+	// attempts to send the batch to the collector, so that they
+	// are known to whichever state server sends metrics next.
+	DeliveryAttempts int       `bson:"delivery-attempts,omitempty"`
+	LastAttempt      time.Time `bson:"last-attempt"`
+	DeliveryError    string    `bson:"delivery-error,omitempty"`
 }
 
+// MetricDeliveryState describes how far a metric batch has got on its
+// way to the collector.
+type MetricDeliveryState string
+
+const (
+	// MetricPending is the state of batches which are waiting to be
+	// sent, or which were sent but whose outcome is unknown, such as
+	// when the state server sending them failed.
+	MetricPending MetricDeliveryState = "pending"
+
+	// MetricFailed is the state of batches which the collector
+	// could not be sent, or did not acknowledge, at the last
+	// attempt. They are sent again at the next attempt.
+	MetricFailed MetricDeliveryState = "failed"
+
+	// MetricAcknowledged is the state of batches which the
+	// collector has acknowledged receiving.
+	MetricAcknowledged MetricDeliveryState = "acknowledged"
+)
+
 // Metric represents a single Metric.
 type Metric struct {
 	Key   string    `bson:"key"`
@@ -200,6 +227,51 @@ func (st *State) MetricsToSend(batchSize int) ([]*MetricBatch, error) {
 	return batch, nil
 }
 
+// MetricBatchesToDeliver returns up to batchSize metric batches, oldest
+// first, which have not been acknowledged by the collector and have
+// not been sent since the given time. Sending metrics in a loop that
+// records each attempt with SetMetricBatchesAttempted, passing the
+// time the loop started, therefore attempts each batch once.
+func (st *State) MetricBatchesToDeliver(batchSize int, attemptedBefore time.Time) ([]*MetricBatch, error) {
+	var docs []metricBatchDoc
+	c, closer := st.getCollection(metricsC)
+	defer closer()
+	err := c.Find(bson.D{
+		{"sent", false},
+		{"$or", []bson.D{
+			{{"last-attempt", bson.D{{"$lt", attemptedBefore}}}},
+			// Batches stored before attempts were recorded.
+			{{"last-attempt", bson.D{{"$exists", false}}}},
+		}},
+	}).Sort("created").Limit(batchSize).All(&docs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	batches := make([]*MetricBatch, len(docs))
+	for i, doc := range docs {
+		batches[i] = &MetricBatch{st: st, doc: doc}
+	}
+	return batches, nil
+}
+
+// EnvironMetricBatches returns the metric batches of the environment
+// which are stored in state, oldest first. Batches are removed some
+// time after the collector acknowledges them.
+func (st *State) EnvironMetricBatches() ([]*MetricBatch, error) {
+	var docs []metricBatchDoc
+	c, closer := st.getCollection(metricsC)
+	defer closer()
+	err := c.Find(bson.M{"env-uuid": st.EnvironUUID()}).Sort("created").All(&docs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	batches := make([]*MetricBatch, len(docs))
+	for i, doc := range docs {
+		batches[i] = &MetricBatch{st: st, doc: doc}
+	}
+	return batches, nil
+}
+
 // CountofUnsentMetrics returns the number of metrics that
 // haven't been sent to the collection service.
 func (st *State) CountofUnsentMetrics() (int, error) {
@@ -258,6 +330,36 @@ func (m *MetricBatch) Sent() bool {
 	return m.doc.Sent
 }
 
+// DeliveryState returns how far the metric batch has got on its way
+// to the collector.
+func (m *MetricBatch) DeliveryState() MetricDeliveryState {
+	switch {
+	case m.doc.Sent:
+		return MetricAcknowledged
+	case m.doc.DeliveryError != "":
+		return MetricFailed
+	}
+	return MetricPending
+}
+
+// DeliveryAttempts returns the number of times the metric batch has
+// been sent to the collector.
+func (m *MetricBatch) DeliveryAttempts() int {
+	return m.doc.DeliveryAttempts
+}
+
+// LastDeliveryAttempt returns the time the metric batch was last sent
+// to the collector, or the zero time if it has not been sent.
+func (m *MetricBatch) LastDeliveryAttempt() time.Time {
+	return m.doc.LastAttempt
+}
+
+// DeliveryError returns why the last attempt to send the metric batch
+// failed, or "" if it did not.
+func (m *MetricBatch) DeliveryError() string {
+	return m.doc.DeliveryError
+}
+
 // Metrics returns the metrics in this batch.
 func (m *MetricBatch) Metrics() []Metric {
 	result := make([]Metric, len(m.doc.Metrics))
@@ -273,6 +375,7 @@ func (m *MetricBatch) SetSent() error {
 	}
 
 	m.doc.Sent = true
+	m.doc.DeliveryError = ""
 	return nil
 }
 
@@ -288,7 +391,10 @@ func setSentOps(batchUUIDs []string) []txn.Op {
 			C:      metricsC,
 			Id:     u,
 			Assert: txn.DocExists,
-			Update: bson.M{"$set": bson.M{"sent": true}},
+			Update: bson.M{
+				"$set":   bson.M{"sent": true},
+				"$unset": bson.M{"delivery-error": nil},
+			},
 		}
 	}
 	return ops
@@ -302,3 +408,42 @@ func (st *State) SetMetricBatchesSent(batchUUIDs []string) error {
 	}
 	return nil
 }
+
+// SetMetricBatchesAttempted records that the metric batches with the
+// given uuids are about to be sent to the collector.
+func (st *State) SetMetricBatchesAttempted(batchUUIDs []string, when time.Time) error {
+	ops := make([]txn.Op, len(batchUUIDs))
+	for i, u := range batchUUIDs {
+		ops[i] = txn.Op{
+			C:      metricsC,
+			Id:     u,
+			Assert: txn.DocExists,
+			Update: bson.M{
+				"$set": bson.M{"last-attempt": when},
+				"$inc": bson.M{"delivery-attempts": 1},
+			},
+		}
+	}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot record attempt to send metrics")
+	}
+	return nil
+}
+
+// SetMetricBatchesDeliveryError records why the metric batches with the
+// given uuids could not be delivered to the collector.
+func (st *State) SetMetricBatchesDeliveryError(batchUUIDs []string, message string) error {
+	ops := make([]txn.Op, len(batchUUIDs))
+	for i, u := range batchUUIDs {
+		ops[i] = txn.Op{
+			C:      metricsC,
+			Id:     u,
+			Assert: bson.D{{"sent", false}},
+			Update: bson.M{"$set": bson.M{"delivery-error": message}},
+		}
+	}
+	if err := st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot record failure to send metrics")
+	}
+	return nil
+}
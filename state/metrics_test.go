@@ -368,3 +368,88 @@ func (s *MetricSuite) TestMetricsAcrossEnvironments(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(batches, gc.HasLen, 0)
 }
+
+func (s *MetricSuite) TestMetricBatchesToDeliver(c *gc.C) {
+	now := state.NowToTheSecond()
+	m := []state.Metric{{Key: "pings", Value: "123", Time: now}}
+	earlier := now.Add(-time.Minute)
+	second := s.factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now, Metrics: m})
+	first := s.factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &earlier, Metrics: m})
+	s.factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Sent: true, Time: &now, Metrics: m})
+
+	start := now.Add(time.Hour)
+	batches, err := s.State.MetricBatchesToDeliver(10, start)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batches, gc.HasLen, 2)
+	c.Assert(batches[0].UUID(), gc.Equals, first.UUID())
+	c.Assert(batches[1].UUID(), gc.Equals, second.UUID())
+
+	// Batches attempted since the given time are not returned.
+	err = s.State.SetMetricBatchesAttempted([]string{first.UUID()}, start)
+	c.Assert(err, jc.ErrorIsNil)
+	batches, err = s.State.MetricBatchesToDeliver(10, start)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].UUID(), gc.Equals, second.UUID())
+
+	// But are returned for later attempts.
+	batches, err = s.State.MetricBatchesToDeliver(10, start.Add(time.Second))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batches, gc.HasLen, 2)
+}
+
+func (s *MetricSuite) TestMetricBatchDeliveryState(c *gc.C) {
+	now := state.NowToTheSecond()
+	m := []state.Metric{{Key: "pings", Value: "123", Time: now}}
+	batch := s.factory.MakeMetric(c, &factory.MetricParams{Unit: s.unit, Time: &now, Metrics: m})
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricPending)
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 0)
+
+	err := s.State.SetMetricBatchesAttempted([]string{batch.UUID()}, now)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetMetricBatchesDeliveryError([]string{batch.UUID()}, "connection refused")
+	c.Assert(err, jc.ErrorIsNil)
+	batch, err = s.State.MetricBatch(batch.UUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricFailed)
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 1)
+	c.Assert(batch.LastDeliveryAttempt().Equal(now), jc.IsTrue)
+	c.Assert(batch.DeliveryError(), gc.Equals, "connection refused")
+
+	later := now.Add(time.Minute)
+	err = s.State.SetMetricBatchesAttempted([]string{batch.UUID()}, later)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetMetricBatchesSent([]string{batch.UUID()})
+	c.Assert(err, jc.ErrorIsNil)
+	batch, err = s.State.MetricBatch(batch.UUID())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batch.DeliveryState(), gc.Equals, state.MetricAcknowledged)
+	c.Assert(batch.DeliveryAttempts(), gc.Equals, 2)
+	c.Assert(batch.LastDeliveryAttempt().Equal(later), jc.IsTrue)
+	c.Assert(batch.DeliveryError(), gc.Equals, "")
+
+	// Errors are not recorded for acknowledged batches.
+	err = s.State.SetMetricBatchesDeliveryError([]string{batch.UUID()}, "connection refused")
+	c.Assert(err, gc.ErrorMatches, "cannot record failure to send metrics: .*")
+}
+
+func (s *MetricSuite) TestEnvironMetricBatches(c *gc.C) {
+	now := state.NowToTheSecond()
+	m := state.Metric{"pings", "5", now}
+	batch, err := s.unit.AddMetrics(now, []state.Metric{m})
+	c.Assert(err, jc.ErrorIsNil)
+
+	st := s.factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	f := factory.NewFactory(st)
+	meteredCharm := f.MakeCharm(c, &factory.CharmParams{Name: "metered", URL: "cs:quantal/metered"})
+	service := f.MakeService(c, &factory.ServiceParams{Charm: meteredCharm})
+	unit := f.MakeUnit(c, &factory.UnitParams{Service: service, SetCharmURL: true})
+	_, err = unit.AddMetrics(now, []state.Metric{m})
+	c.Assert(err, jc.ErrorIsNil)
+
+	batches, err := s.State.EnvironMetricBatches()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(batches, gc.HasLen, 1)
+	c.Assert(batches[0].UUID(), gc.Equals, batch.UUID())
+}
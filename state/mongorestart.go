@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// mongoRestartId is the id of the single document in mongoRestartsC.
+const mongoRestartId = "current"
+
+// mongoRestartDoc records the state server machine which has claimed
+// the right to restart its mongo database, and when the claim lapses.
+type mongoRestartDoc struct {
+	Id      string    `bson:"_id"`
+	Holder  string    `bson:"holder"`
+	Expires time.Time `bson:"expires"`
+}
+
+// ClaimMongoRestart attempts to claim, for the given duration, the
+// right for the state server machine with the given id to restart its
+// mongo database. Only one state server holds the claim at a time, so
+// that restarting members never leave the replica set without a
+// majority. A machine may extend a claim it already holds. It reports
+// whether the claim was made.
+func (st *State) ClaimMongoRestart(machineId string, duration time.Duration) (bool, error) {
+	restarts, closer := st.getCollection(mongoRestartsC)
+	defer closer()
+
+	claimed := true
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		now := time.Now()
+		newDoc := mongoRestartDoc{
+			Id:      mongoRestartId,
+			Holder:  machineId,
+			Expires: now.Add(duration),
+		}
+		var doc mongoRestartDoc
+		err := restarts.FindId(mongoRestartId).One(&doc)
+		if err == mgo.ErrNotFound {
+			return []txn.Op{{
+				C:      mongoRestartsC,
+				Id:     mongoRestartId,
+				Assert: txn.DocMissing,
+				Insert: newDoc,
+			}}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if doc.Holder != "" && doc.Holder != machineId && now.Before(doc.Expires) {
+			claimed = false
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{{
+			C:      mongoRestartsC,
+			Id:     mongoRestartId,
+			Assert: bson.D{{"holder", doc.Holder}, {"expires", doc.Expires}},
+			Update: bson.D{{"$set", bson.D{
+				{"holder", newDoc.Holder},
+				{"expires", newDoc.Expires},
+			}}},
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil && err != jujutxn.ErrNoOperations {
+		return false, errors.Annotate(err, "cannot claim mongo restart")
+	}
+	return claimed, nil
+}
+
+// ReleaseMongoRestart gives up the claim to restart mongo made by the
+// state server machine with the given id. It does nothing if the
+// machine does not hold the claim.
+func (st *State) ReleaseMongoRestart(machineId string) error {
+	ops := []txn.Op{{
+		C:      mongoRestartsC,
+		Id:     mongoRestartId,
+		Assert: bson.D{{"holder", machineId}},
+		Update: bson.D{{"$set", bson.D{{"holder", ""}}}},
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return nil
+	}
+	return errors.Annotate(err, "cannot release mongo restart")
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+)
+
+type MongoRestartSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&MongoRestartSuite{})
+
+func (s *MongoRestartSuite) TestClaimMongoRestart(c *gc.C) {
+	claimed, err := s.State.ClaimMongoRestart("0", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+
+	// Another machine cannot claim it while it is held.
+	claimed, err = s.State.ClaimMongoRestart("1", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsFalse)
+
+	// The holder can extend its claim.
+	claimed, err = s.State.ClaimMongoRestart("0", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+}
+
+func (s *MongoRestartSuite) TestReleaseMongoRestart(c *gc.C) {
+	claimed, err := s.State.ClaimMongoRestart("0", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+
+	// Releasing a claim held by another machine does nothing.
+	err = s.State.ReleaseMongoRestart("1")
+	c.Assert(err, jc.ErrorIsNil)
+	claimed, err = s.State.ClaimMongoRestart("1", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsFalse)
+
+	err = s.State.ReleaseMongoRestart("0")
+	c.Assert(err, jc.ErrorIsNil)
+	claimed, err = s.State.ClaimMongoRestart("1", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+}
+
+func (s *MongoRestartSuite) TestReleaseMongoRestartUnclaimed(c *gc.C) {
+	err := s.State.ReleaseMongoRestart("0")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MongoRestartSuite) TestClaimMongoRestartExpires(c *gc.C) {
+	claimed, err := s.State.ClaimMongoRestart("0", time.Millisecond)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+	time.Sleep(10 * time.Millisecond)
+
+	claimed, err = s.State.ClaimMongoRestart("1", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(claimed, jc.IsTrue)
+}
@@ -154,6 +154,8 @@ type ServiceInfo struct {
 	Constraints constraints.Value
 	Config      map[string]interface{}
 	Subordinate bool
+	Status      Status
+	StatusInfo  string
 }
 
 func (i *ServiceInfo) EntityId() EntityId {
@@ -294,4 +296,10 @@ const (
 
 	// BlockChange type identifies change blocks.
 	BlockChange BlockType = "BlockChange"
+
+	// BlockBudget type identifies blocks of budget-affecting operations.
+	BlockBudget BlockType = "BlockBudget"
+
+	// BlockReboot type identifies blocks of machine reboots.
+	BlockReboot BlockType = "BlockReboot"
 )
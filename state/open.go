@@ -9,11 +9,13 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"github.com/juju/utils/featureflag"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/feature"
 	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state/watcher"
 )
@@ -181,6 +183,10 @@ var indexes = []struct {
 	{storageAttachmentsC, []string{"env-uuid", "unitid"}, false, false},
 	{volumesC, []string{"env-uuid", "storageid"}, false, false},
 	{filesystemsC, []string{"env-uuid", "storageid"}, false, false},
+	{actionOutputC, []string{"env-uuid", "action-id", "seq"}, true, false},
+	{statusesHistoryC, []string{"env-uuid", "entityid", "updated"}, false, false},
+	{secretsC, []string{"env-uuid", "owner"}, false, false},
+	{unitRelationStatesC, []string{"env-uuid", "unit"}, false, false},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -246,12 +252,24 @@ func newState(session *mgo.Session, mongoInfo *mongo.MongoInfo, policy Policy) (
 		return nil, maybeUnauthorized(err, "cannot create transaction collection")
 	}
 
+	// Create the capped collection used to store agent log records.
+	if err := InitDbLogs(session); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// The replica set oplog lets the watcher learn about
+	// transactions as soon as they are applied.
+	var oplog *mgo.Collection
+	if featureflag.Enabled(feature.OplogWatcher) {
+		oplog = session.DB("local").C("oplog.rs")
+	}
+
 	// Create and set up State.
 	st := &State{
 		mongoInfo: mongoInfo,
 		policy:    policy,
 		db:        db,
-		watcher:   watcher.New(txnLog),
+		watcher:   watcher.NewWithOplog(txnLog, oplog),
 	}
 	defer func() {
 		if resultErr != nil {
@@ -0,0 +1,588 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// osUpdatesDoc records the security updates pending on a machine, as
+// last reported by its agent, and the patch run applying them, if any.
+type osUpdatesDoc struct {
+	DocID     string `bson:"_id"`
+	EnvUUID   string `bson:"env-uuid"`
+	MachineId string `bson:"machineid"`
+
+	SecurityUpdates []string  `bson:"security-updates"`
+	Checked         time.Time `bson:"checked"`
+
+	// PatchRun holds the id of the patch run the machine's agent
+	// has been asked to apply updates for, or zero if none.
+	PatchRun int `bson:"patch-run,omitempty"`
+}
+
+// MachineOSUpdates describes the security updates pending on a machine.
+type MachineOSUpdates struct {
+	MachineId string
+
+	// SecurityUpdates holds the names of the packages with pending
+	// security updates.
+	SecurityUpdates []string
+
+	// Checked holds when the machine's agent last checked for
+	// updates, or the zero time if it never has.
+	Checked time.Time
+
+	// PatchRun holds the id of the patch run the machine is being
+	// patched by, or zero if it is not being patched.
+	PatchRun int
+}
+
+func (doc *osUpdatesDoc) toMachineOSUpdates() MachineOSUpdates {
+	return MachineOSUpdates{
+		MachineId:       doc.MachineId,
+		SecurityUpdates: doc.SecurityUpdates,
+		Checked:         doc.Checked.UTC(),
+		PatchRun:        doc.PatchRun,
+	}
+}
+
+func removeOSUpdatesOp(st *State, machineId string) txn.Op {
+	return txn.Op{
+		C:      osUpdatesC,
+		Id:     st.docID(machineId),
+		Remove: true,
+	}
+}
+
+// OSUpdates returns the security updates pending on the machine, as
+// last reported by its agent.
+func (m *Machine) OSUpdates() (MachineOSUpdates, error) {
+	doc, err := m.osUpdatesDoc()
+	if errors.IsNotFound(err) {
+		return MachineOSUpdates{MachineId: m.doc.Id}, nil
+	} else if err != nil {
+		return MachineOSUpdates{}, errors.Trace(err)
+	}
+	return doc.toMachineOSUpdates(), nil
+}
+
+func (m *Machine) osUpdatesDoc() (*osUpdatesDoc, error) {
+	osUpdates, closer := m.st.getCollection(osUpdatesC)
+	defer closer()
+
+	var doc osUpdatesDoc
+	err := osUpdates.FindId(m.doc.DocID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("os updates for machine %s", m.doc.Id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get os updates for machine %s", m.doc.Id)
+	}
+	return &doc, nil
+}
+
+// SetSecurityUpdates records the names of the packages with pending
+// security updates on the machine.
+func (m *Machine) SetSecurityUpdates(packages []string) error {
+	packages = append([]string{}, packages...)
+	sort.Strings(packages)
+	checked := time.Now().UTC()
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life == Dead {
+			return nil, errNotAlive
+		}
+		ops := []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: notDeadDoc,
+		}}
+		_, err := m.osUpdatesDoc()
+		if err == nil {
+			return append(ops, txn.Op{
+				C:      osUpdatesC,
+				Id:     m.doc.DocID,
+				Assert: txn.DocExists,
+				Update: bson.D{{"$set", bson.D{
+					{"security-updates", packages},
+					{"checked", checked},
+				}}},
+			}), nil
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, txn.Op{
+			C:      osUpdatesC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &osUpdatesDoc{
+				MachineId:       m.doc.Id,
+				SecurityUpdates: packages,
+				Checked:         checked,
+			},
+		}), nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set security updates for machine %s", m.doc.Id)
+	}
+	return nil
+}
+
+// WatchOSUpdates returns a watcher that notifies of changes to the
+// machine's pending updates, including when it is asked to apply them.
+func (m *Machine) WatchOSUpdates() NotifyWatcher {
+	return newEntityWatcher(m.st, osUpdatesC, m.doc.DocID)
+}
+
+// AllOSUpdates returns the security updates pending on every machine
+// whose agent has reported them, ordered by machine id.
+func (st *State) AllOSUpdates() ([]MachineOSUpdates, error) {
+	osUpdates, closer := st.getCollection(osUpdatesC)
+	defer closer()
+
+	var docs []osUpdatesDoc
+	if err := osUpdates.Find(nil).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get os updates")
+	}
+	result := make([]MachineOSUpdates, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.toMachineOSUpdates()
+	}
+	sort.Sort(machineOSUpdatesById(result))
+	return result, nil
+}
+
+type machineOSUpdatesById []MachineOSUpdates
+
+func (s machineOSUpdatesById) Len() int      { return len(s) }
+func (s machineOSUpdatesById) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s machineOSUpdatesById) Less(i, j int) bool {
+	return machineIdLessThan(s[i].MachineId, s[j].MachineId)
+}
+
+// PatchStatus describes the progress of a machine in a patch run.
+type PatchStatus string
+
+const (
+	// PatchPending means the machine is waiting for its turn.
+	PatchPending PatchStatus = "pending"
+
+	// PatchApplying means the machine's agent is applying updates.
+	PatchApplying PatchStatus = "applying"
+
+	// PatchRebooting means the updates were applied, and the
+	// machine is rebooting to complete them.
+	PatchRebooting PatchStatus = "rebooting"
+
+	// PatchDone means the machine has been patched.
+	PatchDone PatchStatus = "done"
+
+	// PatchFailed means the machine could not be patched.
+	PatchFailed PatchStatus = "failed"
+)
+
+// unavailable returns whether a machine with the status is counted
+// against a patch run's maximum of unavailable machines.
+func (s PatchStatus) unavailable() bool {
+	return s == PatchApplying || s == PatchRebooting
+}
+
+// finished returns whether a machine with the status is finished with.
+func (s PatchStatus) finished() bool {
+	return s == PatchDone || s == PatchFailed
+}
+
+// PatchRunStatus describes the progress of a patch run.
+type PatchRunStatus string
+
+const (
+	// PatchRunRunning means the run is patching machines.
+	PatchRunRunning PatchRunStatus = "running"
+
+	// PatchRunCompleted means every machine in the run was patched.
+	PatchRunCompleted PatchRunStatus = "completed"
+
+	// PatchRunFailed means the run finished, but some machines
+	// could not be patched.
+	PatchRunFailed PatchRunStatus = "failed"
+)
+
+// patchMachineDoc records the progress of a machine in a patch run.
+type patchMachineDoc struct {
+	Status PatchStatus `bson:"status"`
+	Info   string      `bson:"info,omitempty"`
+}
+
+// patchRunDoc records a rolling run applying the pending security
+// updates to a set of machines.
+type patchRunDoc struct {
+	DocID          string                     `bson:"_id"`
+	EnvUUID        string                     `bson:"env-uuid"`
+	Id             int                        `bson:"runid"`
+	MaxUnavailable int                        `bson:"max-unavailable"`
+	Status         PatchRunStatus             `bson:"status"`
+	Machines       map[string]patchMachineDoc `bson:"machines"`
+	Started        time.Time                  `bson:"started"`
+	Finished       time.Time                  `bson:"finished,omitempty"`
+}
+
+// PatchMachineStatus describes the progress of a machine in a patch run.
+type PatchMachineStatus struct {
+	Status PatchStatus
+	Info   string
+}
+
+// PatchRun represents a rolling run applying pending security updates
+// to machines, a few at a time.
+type PatchRun struct {
+	st  *State
+	doc patchRunDoc
+}
+
+// Id returns the id of the patch run.
+func (r *PatchRun) Id() int {
+	return r.doc.Id
+}
+
+// MaxUnavailable returns how many machines may be applying updates or
+// rebooting at once.
+func (r *PatchRun) MaxUnavailable() int {
+	return r.doc.MaxUnavailable
+}
+
+// Status returns the status of the patch run.
+func (r *PatchRun) Status() PatchRunStatus {
+	return r.doc.Status
+}
+
+// Started returns when the patch run was started.
+func (r *PatchRun) Started() time.Time {
+	return r.doc.Started.UTC()
+}
+
+// Finished returns when the patch run finished, or the zero time if it
+// is still running.
+func (r *PatchRun) Finished() time.Time {
+	if r.doc.Finished.IsZero() {
+		return time.Time{}
+	}
+	return r.doc.Finished.UTC()
+}
+
+// Machines returns the progress of each machine in the patch run,
+// keyed by machine id.
+func (r *PatchRun) Machines() map[string]PatchMachineStatus {
+	result := make(map[string]PatchMachineStatus)
+	for id, doc := range r.doc.Machines {
+		result[id] = PatchMachineStatus{Status: doc.Status, Info: doc.Info}
+	}
+	return result
+}
+
+// MachineIds returns the ids of the machines in the patch run, in
+// order.
+func (r *PatchRun) MachineIds() []string {
+	ids := make([]string, 0, len(r.doc.Machines))
+	for id := range r.doc.Machines {
+		ids = append(ids, id)
+	}
+	sort.Sort(machineIdsInOrder(ids))
+	return ids
+}
+
+type machineIdsInOrder []string
+
+func (s machineIdsInOrder) Len() int           { return len(s) }
+func (s machineIdsInOrder) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s machineIdsInOrder) Less(i, j int) bool { return machineIdLessThan(s[i], s[j]) }
+
+// Refresh refreshes the contents of the patch run from the database.
+func (r *PatchRun) Refresh() error {
+	run, err := r.st.PatchRun(r.doc.Id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	r.doc = run.doc
+	return nil
+}
+
+func patchRunKey(id int) string {
+	return fmt.Sprint(id)
+}
+
+// StartPatchRun starts a rolling run applying the pending security
+// updates to every machine that has any, with no more than
+// maxUnavailable machines applying updates or rebooting at once. Only
+// one patch run may be in progress at a time.
+func (st *State) StartPatchRun(maxUnavailable int) (_ *PatchRun, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot start patch run")
+	if maxUnavailable < 1 {
+		return nil, errors.NotValidf("max-unavailable %d", maxUnavailable)
+	}
+	if active, err := st.ActivePatchRun(); err == nil {
+		return nil, errors.Errorf("patch run %d is already in progress", active.Id())
+	} else if !errors.IsNotFound(err) {
+		return nil, errors.Trace(err)
+	}
+	all, err := st.AllOSUpdates()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	machines := make(map[string]patchMachineDoc)
+	var ops []txn.Op
+	for _, updates := range all {
+		if len(updates.SecurityUpdates) == 0 {
+			continue
+		}
+		machines[updates.MachineId] = patchMachineDoc{Status: PatchPending}
+		ops = append(ops, txn.Op{
+			C:      machinesC,
+			Id:     st.docID(updates.MachineId),
+			Assert: isAliveDoc,
+		})
+	}
+	if len(machines) == 0 {
+		return nil, errors.New("no machines have pending security updates")
+	}
+	id, err := st.sequence("patchrun")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc := patchRunDoc{
+		DocID:          st.docID(patchRunKey(id)),
+		EnvUUID:        st.EnvironUUID(),
+		Id:             id,
+		MaxUnavailable: maxUnavailable,
+		Status:         PatchRunRunning,
+		Machines:       machines,
+		Started:        time.Now().UTC(),
+	}
+	ops = append(ops, txn.Op{
+		C:      patchRunsC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	})
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errors.New("machines changed while starting; try again")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &PatchRun{st: st, doc: doc}, nil
+}
+
+// PatchRun returns the patch run with the given id.
+func (st *State) PatchRun(id int) (*PatchRun, error) {
+	patchRuns, closer := st.getCollection(patchRunsC)
+	defer closer()
+
+	var doc patchRunDoc
+	err := patchRuns.FindId(patchRunKey(id)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("patch run %d", id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get patch run %d", id)
+	}
+	return &PatchRun{st: st, doc: doc}, nil
+}
+
+// ActivePatchRun returns the patch run in progress. It returns an
+// error satisfying errors.IsNotFound if there is none.
+func (st *State) ActivePatchRun() (*PatchRun, error) {
+	patchRuns, closer := st.getCollection(patchRunsC)
+	defer closer()
+
+	var doc patchRunDoc
+	err := patchRuns.Find(bson.D{{"status", PatchRunRunning}}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("active patch run")
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot get active patch run")
+	}
+	return &PatchRun{st: st, doc: doc}, nil
+}
+
+// PatchRuns returns every patch run, most recent first.
+func (st *State) PatchRuns() ([]*PatchRun, error) {
+	patchRuns, closer := st.getCollection(patchRunsC)
+	defer closer()
+
+	var docs []patchRunDoc
+	if err := patchRuns.Find(nil).Sort("-runid").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get patch runs")
+	}
+	result := make([]*PatchRun, len(docs))
+	for i, doc := range docs {
+		result[i] = &PatchRun{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// StartMachines asks the agents of the given machines, which must be
+// pending in the run, to apply their updates.
+func (r *PatchRun) StartMachines(machineIds ...string) error {
+	if len(machineIds) == 0 {
+		return nil
+	}
+	assert := bson.D{{"status", PatchRunRunning}}
+	var update bson.D
+	var ops []txn.Op
+	for _, id := range machineIds {
+		assert = append(assert, bson.DocElem{"machines." + id + ".status", PatchPending})
+		update = append(update, bson.DocElem{"machines." + id, patchMachineDoc{Status: PatchApplying}})
+		ops = append(ops, txn.Op{
+			C:      osUpdatesC,
+			Id:     r.st.docID(id),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"patch-run", r.doc.Id}}}},
+		})
+	}
+	ops = append(ops, txn.Op{
+		C:      patchRunsC,
+		Id:     r.doc.DocID,
+		Assert: assert,
+		Update: bson.D{{"$set", update}},
+	})
+	if err := r.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.Errorf("cannot start patching machines %v: patch run %d changed", machineIds, r.doc.Id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot start patching machines %v", machineIds)
+	}
+	for _, id := range machineIds {
+		r.doc.Machines[id] = patchMachineDoc{Status: PatchApplying}
+	}
+	return nil
+}
+
+// FailMachine records that the machine with the given id, which must
+// not be finished with, could not be patched, for example because it
+// was removed.
+func (r *PatchRun) FailMachine(machineId, info string) error {
+	doc := patchMachineDoc{Status: PatchFailed, Info: info}
+	ops := []txn.Op{{
+		C:  patchRunsC,
+		Id: r.doc.DocID,
+		Assert: bson.D{{"machines." + machineId + ".status", bson.D{
+			{"$in", []PatchStatus{PatchPending, PatchApplying, PatchRebooting}},
+		}}},
+		Update: bson.D{{"$set", bson.D{{"machines." + machineId, doc}}}},
+	}}
+	if err := r.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.Errorf("cannot fail machine %s: not being patched by patch run %d", machineId, r.doc.Id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot fail machine %s", machineId)
+	}
+	r.doc.Machines[machineId] = doc
+	return nil
+}
+
+// Finish records that the patch run has finished. Every machine in
+// the run must have been patched, or have failed to be.
+func (r *PatchRun) Finish() error {
+	status := PatchRunCompleted
+	assert := bson.D{{"status", PatchRunRunning}}
+	for id, machine := range r.doc.Machines {
+		if !machine.Status.finished() {
+			return errors.Errorf("cannot finish patch run %d: machine %s is %s", r.doc.Id, id, machine.Status)
+		}
+		if machine.Status == PatchFailed {
+			status = PatchRunFailed
+		}
+		assert = append(assert, bson.DocElem{"machines." + id + ".status", machine.Status})
+	}
+	finished := time.Now().UTC()
+	ops := []txn.Op{{
+		C:      patchRunsC,
+		Id:     r.doc.DocID,
+		Assert: assert,
+		Update: bson.D{{"$set", bson.D{
+			{"status", status},
+			{"finished", finished},
+		}}},
+	}}
+	if err := r.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.Errorf("cannot finish patch run %d: changed while finishing", r.doc.Id)
+	} else if err != nil {
+		return errors.Annotatef(err, "cannot finish patch run %d", r.doc.Id)
+	}
+	r.doc.Status = status
+	r.doc.Finished = finished
+	return nil
+}
+
+// SetPatchStatus records the progress of the machine's agent in
+// applying updates for the patch run it was asked to. When the status
+// is PatchDone or PatchFailed, the machine is no longer being patched.
+func (m *Machine) SetPatchStatus(status PatchStatus, info string) error {
+	switch status {
+	case PatchRebooting, PatchDone, PatchFailed:
+	default:
+		return errors.NotValidf("patch status %q", status)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		runId, current, err := m.PatchRequest()
+		if errors.IsNotFound(err) {
+			return nil, errors.Errorf("machine %s is not being patched", m.doc.Id)
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !current.Status.unavailable() {
+			return nil, errors.Errorf("machine %s is %s", m.doc.Id, current.Status)
+		}
+		ops := []txn.Op{{
+			C:      patchRunsC,
+			Id:     m.st.docID(patchRunKey(runId)),
+			Assert: bson.D{{"machines." + m.doc.Id + ".status", current.Status}},
+			Update: bson.D{{"$set", bson.D{
+				{"machines." + m.doc.Id, patchMachineDoc{Status: status, Info: info}},
+			}}},
+		}}
+		if status.finished() {
+			ops = append(ops, txn.Op{
+				C:      osUpdatesC,
+				Id:     m.doc.DocID,
+				Assert: bson.D{{"patch-run", runId}},
+				Update: bson.D{{"$unset", bson.D{{"patch-run", nil}}}},
+			})
+		}
+		return ops, nil
+	}
+	if err := m.st.run(buildTxn); err != nil {
+		return errors.Annotatef(err, "cannot set patch status of machine %s", m.doc.Id)
+	}
+	return nil
+}
+
+// PatchRequest returns the id of the patch run the machine's agent has
+// been asked to apply updates for, and the machine's progress in it.
+// It returns an error satisfying errors.IsNotFound if the machine is
+// not being patched.
+func (m *Machine) PatchRequest() (int, PatchMachineStatus, error) {
+	updates, err := m.osUpdatesDoc()
+	if errors.IsNotFound(err) || err == nil && updates.PatchRun == 0 {
+		return 0, PatchMachineStatus{}, errors.NotFoundf("patch request for machine %s", m.doc.Id)
+	} else if err != nil {
+		return 0, PatchMachineStatus{}, errors.Trace(err)
+	}
+	run, err := m.st.PatchRun(updates.PatchRun)
+	if err != nil {
+		return 0, PatchMachineStatus{}, errors.Trace(err)
+	}
+	status, ok := run.Machines()[m.doc.Id]
+	if !ok {
+		return 0, PatchMachineStatus{}, errors.NotFoundf("machine %s in patch run %d", m.doc.Id, run.Id())
+	}
+	return run.Id(), status, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+)
+
+type OSUpdatesSuite struct {
+	ConnSuite
+	machine0 *state.Machine
+	machine1 *state.Machine
+}
+
+var _ = gc.Suite(&OSUpdatesSuite{})
+
+func (s *OSUpdatesSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.machine0, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine1, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *OSUpdatesSuite) TestSetSecurityUpdates(c *gc.C) {
+	updates, err := s.machine0.OSUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(updates, jc.DeepEquals, state.MachineOSUpdates{MachineId: "0"})
+
+	err = s.machine0.SetSecurityUpdates([]string{"openssl", "libc6"})
+	c.Assert(err, jc.ErrorIsNil)
+	updates, err = s.machine0.OSUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(updates.SecurityUpdates, jc.DeepEquals, []string{"libc6", "openssl"})
+	c.Assert(updates.Checked.IsZero(), jc.IsFalse)
+
+	err = s.machine0.SetSecurityUpdates(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	updates, err = s.machine0.OSUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(updates.SecurityUpdates, gc.HasLen, 0)
+}
+
+func (s *OSUpdatesSuite) TestSetSecurityUpdatesDead(c *gc.C) {
+	err := s.machine0.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.SetSecurityUpdates([]string{"openssl"})
+	c.Assert(err, gc.ErrorMatches, "cannot set security updates for machine 0: not found or not alive")
+}
+
+func (s *OSUpdatesSuite) TestAllOSUpdates(c *gc.C) {
+	err := s.machine1.SetSecurityUpdates([]string{"openssl"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.SetSecurityUpdates(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	all, err := s.State.AllOSUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 2)
+	c.Assert(all[0].MachineId, gc.Equals, "0")
+	c.Assert(all[1].MachineId, gc.Equals, "1")
+	c.Assert(all[1].SecurityUpdates, jc.DeepEquals, []string{"openssl"})
+}
+
+func (s *OSUpdatesSuite) TestStartPatchRunNothingToDo(c *gc.C) {
+	err := s.machine0.SetSecurityUpdates(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.StartPatchRun(1)
+	c.Assert(err, gc.ErrorMatches, "cannot start patch run: no machines have pending security updates")
+}
+
+func (s *OSUpdatesSuite) TestStartPatchRunInvalid(c *gc.C) {
+	_, err := s.State.StartPatchRun(0)
+	c.Assert(err, gc.ErrorMatches, "cannot start patch run: max-unavailable 0 not valid")
+}
+
+func (s *OSUpdatesSuite) startRun(c *gc.C) *state.PatchRun {
+	err := s.machine0.SetSecurityUpdates([]string{"openssl"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine1.SetSecurityUpdates([]string{"libc6"})
+	c.Assert(err, jc.ErrorIsNil)
+	run, err := s.State.StartPatchRun(1)
+	c.Assert(err, jc.ErrorIsNil)
+	return run
+}
+
+func (s *OSUpdatesSuite) TestStartPatchRun(c *gc.C) {
+	run := s.startRun(c)
+	c.Assert(run.MaxUnavailable(), gc.Equals, 1)
+	c.Assert(run.Status(), gc.Equals, state.PatchRunRunning)
+	c.Assert(run.Finished().IsZero(), jc.IsTrue)
+	c.Assert(run.Machines(), jc.DeepEquals, map[string]state.PatchMachineStatus{
+		"0": {Status: state.PatchPending},
+		"1": {Status: state.PatchPending},
+	})
+
+	active, err := s.State.ActivePatchRun()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(active.Id(), gc.Equals, run.Id())
+
+	_, err = s.State.StartPatchRun(1)
+	c.Assert(err, gc.ErrorMatches, "cannot start patch run: patch run .* is already in progress")
+
+	// Machines are not asked to patch until started.
+	_, _, err = s.machine0.PatchRequest()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *OSUpdatesSuite) TestPatchRun(c *gc.C) {
+	run := s.startRun(c)
+	w := s.machine0.WatchOSUpdates()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := run.StartMachines("0")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	id, status, err := s.machine0.PatchRequest()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(id, gc.Equals, run.Id())
+	c.Assert(status, gc.Equals, state.PatchMachineStatus{Status: state.PatchApplying})
+
+	// A machine may only be started once.
+	err = run.StartMachines("0")
+	c.Assert(err, gc.ErrorMatches, "cannot start patching machines \\[0\\]: patch run .* changed")
+
+	err = s.machine0.SetPatchStatus(state.PatchRebooting, "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.SetPatchStatus(state.PatchDone, "")
+	c.Assert(err, jc.ErrorIsNil)
+	_, _, err = s.machine0.PatchRequest()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = s.machine0.SetPatchStatus(state.PatchDone, "")
+	c.Assert(err, gc.ErrorMatches, "cannot set patch status of machine 0: machine 0 is not being patched")
+
+	err = run.Finish()
+	c.Assert(err, gc.ErrorMatches, "cannot finish patch run .*: machine 1 is pending")
+
+	err = run.StartMachines("1")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine1.SetPatchStatus(state.PatchFailed, "apt-get failed")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = run.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(run.Machines(), jc.DeepEquals, map[string]state.PatchMachineStatus{
+		"0": {Status: state.PatchDone},
+		"1": {Status: state.PatchFailed, Info: "apt-get failed"},
+	})
+	err = run.Finish()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(run.Status(), gc.Equals, state.PatchRunFailed)
+	c.Assert(run.Finished().IsZero(), jc.IsFalse)
+
+	_, err = s.State.ActivePatchRun()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	runs, err := s.State.PatchRuns()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(runs, gc.HasLen, 1)
+	c.Assert(runs[0].Status(), gc.Equals, state.PatchRunFailed)
+}
+
+func (s *OSUpdatesSuite) TestSetPatchStatusInvalid(c *gc.C) {
+	err := s.machine0.SetPatchStatus(state.PatchApplying, "")
+	c.Assert(err, gc.ErrorMatches, `patch status "applying" not valid`)
+}
+
+func (s *OSUpdatesSuite) TestFailMachine(c *gc.C) {
+	run := s.startRun(c)
+	err := run.FailMachine("1", "machine removed")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(run.Machines()["1"], gc.Equals, state.PatchMachineStatus{
+		Status: state.PatchFailed,
+		Info:   "machine removed",
+	})
+	err = run.FailMachine("1", "machine removed")
+	c.Assert(err, gc.ErrorMatches, "cannot fail machine 1: not being patched by patch run .*")
+}
+
+func (s *OSUpdatesSuite) TestRemoveMachine(c *gc.C) {
+	err := s.machine0.SetSecurityUpdates([]string{"openssl"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine0.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	all, err := s.State.AllOSUpdates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 0)
+}
@@ -136,9 +136,11 @@ func (st *State) resolveConstraints(cons constraints.Value) (constraints.Value,
 	return validator.Merge(envCons, cons)
 }
 
-// validateConstraints returns an error if the given constraints are not valid for the
-// current environment, and also any unsupported attributes.
-func (st *State) validateConstraints(cons constraints.Value) ([]string, error) {
+// ValidateConstraints returns an error if the given constraints are not valid for the
+// current environment, and also any unsupported attributes. Unsupported
+// attributes are not an error: they are accepted but ignored when
+// provisioning.
+func (st *State) ValidateConstraints(cons constraints.Value) ([]string, error) {
 	validator, err := st.constraintsValidator()
 	if err != nil {
 		return nil, err
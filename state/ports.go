@@ -384,6 +384,59 @@ func (m *Machine) AllPorts() ([]*Ports, error) {
 	return results, nil
 }
 
+// IngressRules returns the rules admitting traffic to the port ranges
+// opened on the machine (on all networks) by units of exposed services.
+// Services exposed without address ranges of their own admit traffic
+// from the environment's ingress-allowed address ranges.
+func (m *Machine) IngressRules() ([]network.IngressRule, error) {
+	allPorts, err := m.AllPorts()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg, err := m.st.EnvironConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defaultCIDRs, err := cfg.IngressCIDRs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	services := make(map[string]*Service)
+	var rules []network.IngressRule
+	for _, ports := range allPorts {
+		for portRange, unitName := range ports.AllPortRanges() {
+			serviceName, err := names.UnitService(unitName)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			service, ok := services[serviceName]
+			if !ok {
+				service, err = m.st.Service(serviceName)
+				if errors.IsNotFound(err) {
+					continue
+				} else if err != nil {
+					return nil, errors.Trace(err)
+				}
+				services[serviceName] = service
+			}
+			if !service.IsExposed() {
+				continue
+			}
+			cidrs := service.ExposedCIDRs()
+			if len(cidrs) == 0 {
+				cidrs = defaultCIDRs
+			}
+			rule, err := network.NewIngressRule(portRange, cidrs...)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			rules = append(rules, rule)
+		}
+	}
+	network.SortIngressRules(rules)
+	return rules, nil
+}
+
 // addPortsDocOps returns the ops for adding a number of port ranges
 // to a new ports document. portsAssert allows specifying an assert
 // statement for on the openedPorts collection op.
@@ -282,6 +282,53 @@ func (s *PortsDocSuite) TestAllPortRanges(c *gc.C) {
 	c.Assert(ranges[network.PortRange{100, 200, "TCP"}], gc.Equals, s.unit1.Name())
 }
 
+func (s *PortsDocSuite) TestMachineIngressRules(c *gc.C) {
+	f := factory.NewFactory(s.State)
+	mysql := f.MakeService(c, &factory.ServiceParams{
+		Name:  "mysql",
+		Charm: f.MakeCharm(c, &factory.CharmParams{Name: "mysql"}),
+	})
+	unit3 := f.MakeUnit(c, &factory.UnitParams{Service: mysql, Machine: s.machine})
+
+	err := s.unit1.OpenPorts("tcp", 80, 80)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit2.OpenPorts("tcp", 443, 443)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit3.OpenPorts("tcp", 3306, 3306)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Ports of unexposed services are not reachable.
+	rules, err := s.machine.IngressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, gc.HasLen, 0)
+
+	err = s.service.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	err = mysql.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, jc.ErrorIsNil)
+	rules, err = s.machine.IngressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, jc.DeepEquals, []network.IngressRule{
+		network.MustNewIngressRule(network.MustParsePortRange("80/tcp")),
+		network.MustNewIngressRule(network.MustParsePortRange("443/tcp")),
+		network.MustNewIngressRule(network.MustParsePortRange("3306/tcp"), "10.0.0.0/8"),
+	})
+
+	// Services exposed without address ranges of their own are
+	// reachable from the environment's default ranges.
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ingress-allowed": "192.168.0.0/16",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	rules, err = s.machine.IngressRules()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rules, jc.DeepEquals, []network.IngressRule{
+		network.MustNewIngressRule(network.MustParsePortRange("80/tcp"), "192.168.0.0/16"),
+		network.MustNewIngressRule(network.MustParsePortRange("443/tcp"), "192.168.0.0/16"),
+		network.MustNewIngressRule(network.MustParsePortRange("3306/tcp"), "10.0.0.0/8"),
+	})
+}
+
 func (s *PortsDocSuite) TestOpenInvalidRange(c *gc.C) {
 	portRange := state.PortRange{
 		FromPort: 400,
@@ -11,6 +11,7 @@ import (
 
 var _ RebootFlagSetter = (*Machine)(nil)
 var _ RebootActionGetter = (*Machine)(nil)
+var _ RebootAcknowledger = (*Machine)(nil)
 
 // RebootAction defines the action a machine should
 // take when a hook needs to reboot
@@ -35,6 +36,11 @@ type rebootDoc struct {
 	DocID   string `bson:"_id"`
 	Id      string `bson:"machineid"`
 	EnvUUID string `bson:"env-uuid"`
+
+	// Acknowledged holds the ids of the containers that have
+	// stopped running hooks and are ready for the machine
+	// to reboot.
+	Acknowledged []string `bson:"acknowledged,omitempty"`
 }
 
 func (m *Machine) setFlag() error {
@@ -147,6 +153,84 @@ func (m *Machine) ShouldRebootOrShutdown() (RebootAction, error) {
 	return ShouldDoNothing, nil
 }
 
+// AcknowledgeReboot records that the machine, which must be a
+// container, has stopped running hooks and is ready for any of its
+// ancestors that requested a reboot to go ahead. It returns a NotFound
+// error if no ancestor has requested a reboot.
+func (m *Machine) AcknowledgeReboot() error {
+	rebootCol, closer := m.st.getCollection(rebootC)
+	defer closer()
+
+	// The first id is the machine's own, which we skip.
+	ancestors := m.machinesToCareAboutRebootsFor()[1:]
+	docs := []rebootDoc{}
+	sel := bson.D{{"machineid", bson.D{{"$in", ancestors}}}}
+	if err := rebootCol.Find(sel).All(&docs); err != nil {
+		return errors.Trace(err)
+	}
+	if len(docs) == 0 {
+		return errors.NotFoundf("reboot request for parent of machine %v", m.Id())
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      rebootC,
+			Id:     doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$addToSet", bson.D{{"acknowledged", m.Id()}}}},
+		}
+	}
+	if err := m.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("reboot request for parent of machine %v", m.Id())
+	} else if err != nil {
+		return errors.Annotate(err, "cannot acknowledge reboot")
+	}
+	return nil
+}
+
+// PendingRebootAcknowledgements returns the ids of the machine's
+// containers that have not yet acknowledged the machine's reboot
+// request. If the machine has not requested a reboot, every container
+// that is not dead is returned.
+func (m *Machine) PendingRebootAcknowledgements() ([]string, error) {
+	containers, err := m.Containers()
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	rebootCol, closer := m.st.getCollection(rebootC)
+	defer closer()
+	var doc rebootDoc
+	err = rebootCol.FindId(m.doc.DocID).One(&doc)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, errors.Annotate(err, "cannot get reboot request")
+	}
+	acknowledged := make(map[string]bool)
+	for _, id := range doc.Acknowledged {
+		acknowledged[id] = true
+	}
+
+	var pending []string
+	for _, id := range containers {
+		if acknowledged[id] {
+			continue
+		}
+		container, err := m.st.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if container.Life() == Dead {
+			continue
+		}
+		pending = append(pending, id)
+	}
+	return pending, nil
+}
+
 type RebootFlagSetter interface {
 	SetRebootFlag(flag bool) error
 }
@@ -154,3 +238,10 @@ type RebootFlagSetter interface {
 type RebootActionGetter interface {
 	ShouldRebootOrShutdown() (RebootAction, error)
 }
+
+// RebootAcknowledger is implemented by entities that take part in
+// coordinating a reboot with their containers.
+type RebootAcknowledger interface {
+	AcknowledgeReboot() error
+	PendingRebootAcknowledgements() ([]string, error)
+}
@@ -5,6 +5,7 @@
 package state_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -228,3 +229,66 @@ func (s *RebootSuite) TestWatchRebootHappensOnContainer3(c *gc.C) {
 	statetesting.AssertStop(c, s.wC3)
 	s.wcC3.AssertClosed()
 }
+
+func (s *RebootSuite) TestAcknowledgeReboot(c *gc.C) {
+	err := s.machine.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	pending, err := s.machine.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.SameContents, []string{s.c1.Id(), s.c3.Id()})
+
+	err = s.c1.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err = s.machine.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, []string{s.c3.Id()})
+
+	// Acknowledging twice is harmless.
+	err = s.c1.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.c3.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err = s.machine.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
+
+	// Clearing the reboot flag forgets the acknowledgements.
+	err = s.machine.SetRebootFlag(false)
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err = s.machine.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.SameContents, []string{s.c1.Id(), s.c3.Id()})
+}
+
+func (s *RebootSuite) TestAcknowledgeRebootNestedContainer(c *gc.C) {
+	err := s.c1.SetRebootFlag(true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	pending, err := s.c1.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, jc.DeepEquals, []string{s.c2.Id()})
+
+	err = s.c2.AcknowledgeReboot()
+	c.Assert(err, jc.ErrorIsNil)
+	pending, err = s.c1.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
+}
+
+func (s *RebootSuite) TestAcknowledgeRebootNotRequested(c *gc.C) {
+	err := s.c1.AcknowledgeReboot()
+	c.Assert(err, gc.ErrorMatches, "reboot request for parent of machine .* not found")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	// A machine that is not a container has no parent to acknowledge.
+	err = s.machine.AcknowledgeReboot()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RebootSuite) TestPendingRebootAcknowledgementsNoContainers(c *gc.C) {
+	pending, err := s.c3.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
+}
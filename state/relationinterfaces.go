@@ -0,0 +1,181 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4"
+)
+
+// RelationInterface records the charms in the environment that declare
+// a relation interface in their metadata, grouped by the role they take.
+type RelationInterface struct {
+	Name string
+
+	// Providers, Requirers and Peers hold the URLs of the charms
+	// declaring the interface in each role, sorted.
+	Providers []string
+	Requirers []string
+	Peers     []string
+}
+
+// RelationInterfaces returns the relation interfaces declared by all
+// charms stored in the environment, sorted by name. The implicit
+// juju-info interface is only included if a charm declares it.
+func (st *State) RelationInterfaces() ([]RelationInterface, error) {
+	charms, err := st.AllCharms()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read charms")
+	}
+	byName := make(map[string]*RelationInterface)
+	record := func(curl string, rels map[string]charm.Relation, role charm.RelationRole) {
+		for _, rel := range rels {
+			ri, ok := byName[rel.Interface]
+			if !ok {
+				ri = &RelationInterface{Name: rel.Interface}
+				byName[rel.Interface] = ri
+			}
+			var urls *[]string
+			switch role {
+			case charm.RoleProvider:
+				urls = &ri.Providers
+			case charm.RoleRequirer:
+				urls = &ri.Requirers
+			default:
+				urls = &ri.Peers
+			}
+			if !containsString(*urls, curl) {
+				*urls = append(*urls, curl)
+			}
+		}
+	}
+	for _, ch := range charms {
+		if ch.IsPlaceholder() || ch.Meta() == nil {
+			continue
+		}
+		curl := ch.URL().String()
+		meta := ch.Meta()
+		record(curl, meta.Provides, charm.RoleProvider)
+		record(curl, meta.Requires, charm.RoleRequirer)
+		record(curl, meta.Peers, charm.RolePeer)
+	}
+	result := make([]RelationInterface, 0, len(byName))
+	for _, ri := range byName {
+		sort.Strings(ri.Providers)
+		sort.Strings(ri.Requirers)
+		sort.Strings(ri.Peers)
+		result = append(result, *ri)
+	}
+	sort.Sort(relationInterfacesByName(result))
+	return result, nil
+}
+
+type relationInterfacesByName []RelationInterface
+
+func (s relationInterfacesByName) Len() int           { return len(s) }
+func (s relationInterfacesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s relationInterfacesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// noPeerRelationReason explains why name, as given to InferEndpoints
+// on its own, matched no peer relation.
+func noPeerRelationReason(name string) string {
+	if strings.Contains(name, ":") {
+		return fmt.Sprintf("%q is not a peer relation", name)
+	}
+	return fmt.Sprintf("service %q has no peer relations", name)
+}
+
+// noRelationReason explains why none of the endpoints in eps1 can be
+// related to any of those in eps2. Endpoints sharing an interface are
+// examined first; failing that, the interface registry is consulted to
+// point out which charms could satisfy each side's requirements.
+func (st *State) noRelationReason(names []string, eps1, eps2 []Endpoint) string {
+	for i, eps := range [][]Endpoint{eps1, eps2} {
+		if len(eps) == 0 {
+			return fmt.Sprintf("%q has no provider or requirer relations", names[i])
+		}
+	}
+	svc1, svc2 := eps1[0].ServiceName, eps2[0].ServiceName
+	var sameRole []string
+	var scopeFailed bool
+	for _, ep1 := range eps1 {
+		for _, ep2 := range eps2 {
+			if ep1.Interface != ep2.Interface {
+				continue
+			}
+			if ep1.CanRelateTo(ep2) {
+				// CanRelateTo passed, so InferEndpoints can only
+				// have rejected the pair on scope.
+				scopeFailed = true
+			} else if !ep1.IsImplicit() && !ep2.IsImplicit() && ep1.Role == ep2.Role {
+				if !containsString(sameRole, ep1.Interface) {
+					sameRole = append(sameRole, ep1.Interface)
+				}
+			}
+		}
+	}
+	if scopeFailed {
+		return fmt.Sprintf(
+			"container-scoped relations need a subordinate service, and neither %q nor %q is subordinate",
+			svc1, svc2,
+		)
+	}
+	if len(sameRole) > 0 {
+		sort.Strings(sameRole)
+		return fmt.Sprintf(
+			"%q and %q only share interfaces they both provide or both require (%s)",
+			svc1, svc2, quoteStrings(sameRole),
+		)
+	}
+	reason := fmt.Sprintf("%q and %q have no relation interfaces in common", svc1, svc2)
+	interfaces, err := st.RelationInterfaces()
+	if err != nil {
+		logger.Warningf("cannot explain missing relation: %v", err)
+		return reason
+	}
+	providers := make(map[string][]string)
+	for _, ri := range interfaces {
+		providers[ri.Name] = ri.Providers
+	}
+	var hints []string
+	for _, ep := range append(eps1, eps2...) {
+		if ep.Role != charm.RoleRequirer {
+			continue
+		}
+		hint := fmt.Sprintf("%q requires %q", ep.ServiceName, ep.Interface)
+		if urls := providers[ep.Interface]; len(urls) > 0 {
+			hint += fmt.Sprintf(" (provided by %s)", strings.Join(urls, ", "))
+		} else {
+			hint += " (not provided by any charm in the environment)"
+		}
+		if !containsString(hints, hint) {
+			hints = append(hints, hint)
+		}
+	}
+	if len(hints) > 0 {
+		reason += "; " + strings.Join(hints, "; ")
+	}
+	return reason
+}
+
+func quoteStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type RelationInterfacesSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&RelationInterfacesSuite{})
+
+func (s *RelationInterfacesSuite) TestNoCharms(c *gc.C) {
+	interfaces, err := s.State.RelationInterfaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(interfaces, gc.HasLen, 0)
+}
+
+func (s *RelationInterfacesSuite) TestRelationInterfaces(c *gc.C) {
+	s.AddTestingCharm(c, "wordpress")
+	s.AddTestingCharm(c, "mysql")
+	s.AddTestingCharm(c, "riak")
+
+	wordpress := "local:quantal/quantal-wordpress-3"
+	mysql := "local:quantal/quantal-mysql-1"
+	riak := "local:quantal/quantal-riak-7"
+	interfaces, err := s.State.RelationInterfaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(interfaces, jc.DeepEquals, []state.RelationInterface{{
+		Name:      "http",
+		Providers: []string{riak, wordpress},
+	}, {
+		Name:      "logging",
+		Providers: []string{wordpress},
+	}, {
+		Name:      "monitoring",
+		Providers: []string{wordpress},
+	}, {
+		Name:      "mysql",
+		Providers: []string{mysql},
+		Requirers: []string{wordpress},
+	}, {
+		Name:  "riak",
+		Peers: []string{riak},
+	}, {
+		Name:      "varnish",
+		Requirers: []string{wordpress},
+	}})
+}
@@ -137,15 +137,22 @@ func (ru *RelationUnit) EnterScope(settings map[string]interface{}) error {
 
 	// * If the unit should have a subordinate, and does not, create it.
 	var existingSubName string
+	var addingSub bool
 	if subOps, subName, err := ru.subordinateOps(); err != nil {
 		return err
 	} else {
 		existingSubName = subName
+		addingSub = len(subOps) > 0
 		ops = append(ops, subOps...)
 	}
 
 	// Now run the complete transaction, or figure out why we can't.
-	if err := ru.st.runTransaction(ops); err != txn.ErrAborted {
+	if err := ru.st.runTransaction(ops); err == nil {
+		if addingSub {
+			ru.refreshSubordinateStatus()
+		}
+		return nil
+	} else if err != txn.ErrAborted {
 		return err
 	}
 	if count, err := relationScopes.FindId(rsDocID).Count(); err != nil {
@@ -200,6 +207,16 @@ func (ru *RelationUnit) EnterScope(settings map[string]interface{}) error {
 	return fmt.Errorf(prefix + "inconsistent state in EnterScope")
 }
 
+// refreshSubordinateStatus updates the status of the subordinate
+// service whose unit was created on entering scope.
+func (ru *RelationUnit) refreshSubordinateStatus() {
+	related, err := ru.relation.RelatedEndpoints(ru.endpoint.ServiceName)
+	if err != nil || len(related) != 1 {
+		return
+	}
+	refreshServiceStatus(ru.st, related[0].ServiceName)
+}
+
 // subordinateOps returns any txn operations necessary to ensure sane
 // subordinate state when entering scope. If a required subordinate unit
 // exists and is Alive, its name will be returned as well; if one exists
@@ -0,0 +1,459 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// secretKeyId is the id of the document holding the key with which
+// the secrets of an environment are encrypted.
+const secretKeyId = "secrets"
+
+// secretDoc represents a secret owned by a service. The secret's
+// value is never stored in the clear.
+type secretDoc struct {
+	DocID          string        `bson:"_id"`
+	Id             string        `bson:"id"`
+	EnvUUID        string        `bson:"env-uuid"`
+	Owner          string        `bson:"owner"`
+	Label          string        `bson:"label"`
+	Revision       int           `bson:"revision"`
+	Data           []byte        `bson:"data"`
+	RotateInterval time.Duration `bson:"rotate-interval"`
+	NextRotateTime *time.Time    `bson:"next-rotate-time,omitempty"`
+	Grants         []secretGrant `bson:"grants"`
+	TxnRevno       int64         `bson:"txn-revno"`
+}
+
+// secretGrant records that a secret may be read by the units of a
+// service for as long as the relation it was granted over exists.
+type secretGrant struct {
+	RelationKey string `bson:"relation-key"`
+	Service     string `bson:"service"`
+}
+
+// secretKeyDoc holds the key used to encrypt the secrets of an
+// environment.
+type secretKeyDoc struct {
+	DocID   string `bson:"_id"`
+	EnvUUID string `bson:"env-uuid"`
+	Key     []byte `bson:"key"`
+}
+
+// Secret represents a secret value created by a service's charm.
+type Secret struct {
+	st  *State
+	doc secretDoc
+}
+
+// AddSecretParams holds the parameters for adding a secret.
+type AddSecretParams struct {
+	// Owner is the name of the service that owns the secret.
+	Owner string
+
+	// Label is an optional description of the secret.
+	Label string
+
+	// Data is the value of the secret.
+	Data map[string]string
+
+	// RotateInterval is how often the owner should rotate the
+	// secret. If zero, the secret is never due rotation.
+	RotateInterval time.Duration
+}
+
+// Id returns the unique identifier of the secret.
+func (s *Secret) Id() string {
+	return s.doc.Id
+}
+
+// Owner returns the name of the service that owns the secret.
+func (s *Secret) Owner() string {
+	return s.doc.Owner
+}
+
+// Label returns the description the secret was created with.
+func (s *Secret) Label() string {
+	return s.doc.Label
+}
+
+// Revision returns the number of times the secret's value was set.
+func (s *Secret) Revision() int {
+	return s.doc.Revision
+}
+
+// RotateInterval returns how often the secret should be rotated, or
+// zero if it is never due rotation.
+func (s *Secret) RotateInterval() time.Duration {
+	return s.doc.RotateInterval
+}
+
+// NextRotateTime returns when the secret is next due rotation, and
+// whether it ever is.
+func (s *Secret) NextRotateTime() (time.Time, bool) {
+	if s.doc.NextRotateTime == nil {
+		return time.Time{}, false
+	}
+	return *s.doc.NextRotateTime, true
+}
+
+// Refresh refreshes the contents of the secret from the underlying
+// state. It returns an error that satisfies errors.IsNotFound if the
+// secret has been removed.
+func (s *Secret) Refresh() error {
+	secrets, closer := s.st.getCollection(secretsC)
+	defer closer()
+	err := secrets.FindId(s.doc.DocID).One(&s.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("secret %q", s.doc.Id)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "cannot refresh secret %q", s.doc.Id)
+	}
+	return nil
+}
+
+// Data returns the value of the secret.
+func (s *Secret) Data() (map[string]string, error) {
+	key, err := s.st.secretKey()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	plaintext, err := decryptSecret(key, s.doc.Data)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot decrypt secret %q", s.doc.Id)
+	}
+	var data map[string]string
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, errors.Annotatef(err, "cannot decode secret %q", s.doc.Id)
+	}
+	return data, nil
+}
+
+// Update replaces the value of the secret, bumping its revision and,
+// if it is to be rotated, postponing its next rotation by the
+// rotation interval.
+func (s *Secret) Update(data map[string]string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot update secret %q", s.doc.Id)
+	encrypted, err := s.st.encryptSecretData(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		update := bson.D{
+			{"data", encrypted},
+			{"revision", s.doc.Revision + 1},
+		}
+		if next := s.nextRotateTime(); next != nil {
+			update = append(update, bson.DocElem{"next-rotate-time", next})
+		}
+		return []txn.Op{{
+			C:      secretsC,
+			Id:     s.doc.DocID,
+			Assert: bson.D{{"revision", s.doc.Revision}},
+			Update: bson.D{{"$set", update}},
+		}}, nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return s.Refresh()
+}
+
+// Rotated records that the owner of the secret has dealt with it
+// being due rotation, postponing its next rotation by the rotation
+// interval, whether or not its value was updated.
+func (s *Secret) Rotated() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot record rotation of secret %q", s.doc.Id)
+	next := s.nextRotateTime()
+	if next == nil {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      secretsC,
+		Id:     s.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$set", bson.D{{"next-rotate-time", next}}}},
+	}}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		return errors.NotFoundf("secret %q", s.doc.Id)
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	s.doc.NextRotateTime = next
+	return nil
+}
+
+func (s *Secret) nextRotateTime() *time.Time {
+	if s.doc.RotateInterval <= 0 {
+		return nil
+	}
+	next := time.Now().Add(s.doc.RotateInterval).UTC()
+	return &next
+}
+
+// Grant allows the units of the services at the other end of the
+// given relation to read the secret, for as long as the relation
+// exists. The relation must be one of the owner's.
+func (s *Secret) Grant(rel *Relation) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot grant secret %q on relation %q", s.doc.Id, rel)
+	related, err := rel.RelatedEndpoints(s.doc.Owner)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var grants []secretGrant
+	for _, ep := range related {
+		if ep.ServiceName == s.doc.Owner {
+			continue
+		}
+		grants = append(grants, secretGrant{
+			RelationKey: rel.String(),
+			Service:     ep.ServiceName,
+		})
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+	ops := []txn.Op{{
+		C:      relationsC,
+		Id:     rel.doc.DocID,
+		Assert: isAliveDoc,
+	}, {
+		C:      secretsC,
+		Id:     s.doc.DocID,
+		Assert: txn.DocExists,
+		Update: bson.D{{"$addToSet", bson.D{{"grants", bson.D{{"$each", grants}}}}}},
+	}}
+	if err := s.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := s.Refresh(); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Errorf("relation is no longer alive")
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return s.Refresh()
+}
+
+// CanRead reports whether the units of the named service may read the
+// secret: the secret's owner always can, other services only while a
+// relation the secret was granted over exists.
+func (s *Secret) CanRead(serviceName string) (bool, error) {
+	if serviceName == s.doc.Owner {
+		return true, nil
+	}
+	for _, grant := range s.doc.Grants {
+		if grant.Service != serviceName {
+			continue
+		}
+		rel, err := s.st.KeyRelation(grant.RelationKey)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, errors.Trace(err)
+		}
+		if rel.Life() == Alive {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddSecret creates a new secret owned by a service, and returns it.
+func (st *State) AddSecret(args AddSecretParams) (_ *Secret, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add secret for service %q", args.Owner)
+	if args.RotateInterval < 0 {
+		return nil, errors.NotValidf("rotate interval %v", args.RotateInterval)
+	}
+	encrypted, err := st.encryptSecretData(args.Data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	id := uuid.String()
+	doc := secretDoc{
+		DocID:          st.docID(id),
+		Id:             id,
+		EnvUUID:        st.EnvironUUID(),
+		Owner:          args.Owner,
+		Label:          args.Label,
+		Revision:       1,
+		Data:           encrypted,
+		RotateInterval: args.RotateInterval,
+	}
+	if args.RotateInterval > 0 {
+		next := time.Now().Add(args.RotateInterval).UTC()
+		doc.NextRotateTime = &next
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     st.docID(args.Owner),
+		Assert: isAliveDoc,
+	}, {
+		C:      secretsC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: &doc,
+	}}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, errors.Errorf("service is not alive")
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Secret{st: st, doc: doc}, nil
+}
+
+// Secret returns the secret with the given id.
+func (st *State) Secret(id string) (*Secret, error) {
+	secrets, closer := st.getCollection(secretsC)
+	defer closer()
+	var doc secretDoc
+	err := secrets.FindId(id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("secret %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get secret %q", id)
+	}
+	return &Secret{st: st, doc: doc}, nil
+}
+
+// ServiceSecrets returns the secrets owned by the named service.
+func (st *State) ServiceSecrets(serviceName string) ([]*Secret, error) {
+	secrets, closer := st.getCollection(secretsC)
+	defer closer()
+	var docs []secretDoc
+	err := secrets.Find(bson.D{{"owner", serviceName}}).All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get secrets of service %q", serviceName)
+	}
+	result := make([]*Secret, len(docs))
+	for i, doc := range docs {
+		result[i] = &Secret{st: st, doc: doc}
+	}
+	return result, nil
+}
+
+// cleanupSecretsForRemovedService removes the secrets owned by the
+// named service, which has been removed.
+func (st *State) cleanupSecretsForRemovedService(serviceName string) error {
+	secrets, err := st.ServiceSecrets(serviceName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, secret := range secrets {
+		ops := []txn.Op{{
+			C:      secretsC,
+			Id:     secret.doc.DocID,
+			Remove: true,
+		}}
+		if err := st.runTransaction(ops); err != nil {
+			return errors.Annotatef(err, "cannot remove secret %q", secret.doc.Id)
+		}
+	}
+	return nil
+}
+
+func (st *State) encryptSecretData(data map[string]string) ([]byte, error) {
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	key, err := st.secretKey()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return encryptSecret(key, plaintext)
+}
+
+// secretKey returns the key used to encrypt the secrets of the
+// environment, creating it if it does not yet exist.
+func (st *State) secretKey() ([]byte, error) {
+	keys, closer := st.getCollection(secretKeysC)
+	defer closer()
+	var doc secretKeyDoc
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		err := keys.FindId(secretKeyId).One(&doc)
+		if err == nil {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != mgo.ErrNotFound {
+			return nil, errors.Trace(err)
+		}
+		key := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc = secretKeyDoc{
+			DocID:   st.docID(secretKeyId),
+			EnvUUID: st.EnvironUUID(),
+			Key:     key,
+		}
+		return []txn.Op{{
+			C:      secretKeysC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &doc,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, errors.Annotate(err, "cannot get secrets key")
+	}
+	return doc.Key, nil
+}
+
+// encryptSecret seals plaintext with AES-GCM under the given key,
+// prefixing the result with the random nonce used.
+func encryptSecret(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptSecret opens ciphertext created by encryptSecret.
+func decryptSecret(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,185 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/state"
+)
+
+type SecretsSuite struct {
+	ConnSuite
+	mysql     *state.Service
+	wordpress *state.Service
+}
+
+var _ = gc.Suite(&SecretsSuite{})
+
+func (s *SecretsSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.mysql = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	s.wordpress = s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+}
+
+func (s *SecretsSuite) addSecret(c *gc.C, rotateInterval time.Duration) *state.Secret {
+	secret, err := s.State.AddSecret(state.AddSecretParams{
+		Owner:          "mysql",
+		Label:          "root password",
+		Data:           map[string]string{"password": "sekrit"},
+		RotateInterval: rotateInterval,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return secret
+}
+
+func (s *SecretsSuite) TestAddSecret(c *gc.C) {
+	secret := s.addSecret(c, 0)
+	c.Assert(secret.Id(), gc.Not(gc.Equals), "")
+	c.Assert(secret.Owner(), gc.Equals, "mysql")
+	c.Assert(secret.Label(), gc.Equals, "root password")
+	c.Assert(secret.Revision(), gc.Equals, 1)
+	_, ok := secret.NextRotateTime()
+	c.Assert(ok, jc.IsFalse)
+
+	secret, err := s.State.Secret(secret.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := secret.Data()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, map[string]string{"password": "sekrit"})
+}
+
+func (s *SecretsSuite) TestAddSecretEncryptsData(c *gc.C) {
+	secret := s.addSecret(c, 0)
+
+	secrets, closer := state.GetRawCollection(s.State, "secrets")
+	defer closer()
+	var doc bson.M
+	err := secrets.Find(bson.D{{"id", secret.Id()}}).One(&doc)
+	c.Assert(err, jc.ErrorIsNil)
+	data, ok := doc["data"].([]byte)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(strings.Contains(string(data), "sekrit"), jc.IsFalse)
+}
+
+func (s *SecretsSuite) TestAddSecretServiceNotAlive(c *gc.C) {
+	// The unit keeps the dying service from being removed.
+	_, err := s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.AddSecret(state.AddSecretParams{
+		Owner: "mysql",
+		Data:  map[string]string{"password": "sekrit"},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add secret for service "mysql": service is not alive`)
+}
+
+func (s *SecretsSuite) TestSecretNotFound(c *gc.C) {
+	_, err := s.State.Secret("no-such-secret")
+	c.Assert(err, gc.ErrorMatches, `secret "no-such-secret" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SecretsSuite) TestUpdate(c *gc.C) {
+	secret := s.addSecret(c, time.Hour)
+	err := secret.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	before, ok := secret.NextRotateTime()
+	c.Assert(ok, jc.IsTrue)
+
+	err = secret.Update(map[string]string{"password": "sekrit2"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.Revision(), gc.Equals, 2)
+	after, ok := secret.NextRotateTime()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(after.Before(before), jc.IsFalse)
+
+	data, err := secret.Data()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, map[string]string{"password": "sekrit2"})
+}
+
+func (s *SecretsSuite) TestRotated(c *gc.C) {
+	secret := s.addSecret(c, time.Hour)
+	before, _ := secret.NextRotateTime()
+
+	err := secret.Rotated()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secret.Revision(), gc.Equals, 1)
+	after, ok := secret.NextRotateTime()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(after.Before(before), jc.IsFalse)
+}
+
+func (s *SecretsSuite) TestGrant(c *gc.C) {
+	secret := s.addSecret(c, 0)
+	canRead, err := secret.CanRead("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsTrue)
+	canRead, err = secret.CanRead("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsFalse)
+
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	err = secret.Grant(rel)
+	c.Assert(err, jc.ErrorIsNil)
+	canRead, err = secret.CanRead("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsTrue)
+
+	// Access is lost with the relation.
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	canRead, err = secret.CanRead("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(canRead, jc.IsFalse)
+}
+
+func (s *SecretsSuite) TestGrantOtherRelation(c *gc.C) {
+	secret := s.addSecret(c, 0)
+	s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	eps, err := s.State.InferEndpoints("wordpress", "logging")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = secret.Grant(rel)
+	c.Assert(err, gc.ErrorMatches, `cannot grant secret ".*" on relation ".*": service "mysql" is not a member of ".*"`)
+}
+
+func (s *SecretsSuite) TestServiceSecrets(c *gc.C) {
+	secret := s.addSecret(c, 0)
+	_, err := s.State.AddSecret(state.AddSecretParams{
+		Owner: "wordpress",
+		Data:  map[string]string{"key": "value"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	secrets, err := s.State.ServiceSecrets("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(secrets, gc.HasLen, 1)
+	c.Assert(secrets[0].Id(), gc.Equals, secret.Id())
+}
+
+func (s *SecretsSuite) TestSecretsRemovedWithService(c *gc.C) {
+	secret := s.addSecret(c, 0)
+	err := s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = secret.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
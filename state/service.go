@@ -19,6 +19,8 @@ import (
 	"gopkg.in/mgo.v2/txn"
 
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
 )
 
 // Service represents the state of a service.
@@ -42,10 +44,29 @@ type serviceDoc struct {
 	UnitCount         int        `bson:"unitcount"`
 	RelationCount     int        `bson:"relationcount"`
 	Exposed           bool       `bson:"exposed"`
+	ExposedCIDRs      []string   `bson:"exposedcidrs,omitempty"`
 	MinUnits          int        `bson:"minunits"`
+	MaxUnits          int        `bson:"maxunits,omitempty"`
 	OwnerTag          string     `bson:"ownertag"`
 	TxnRevno          int64      `bson:"txn-revno"`
 	MetricCredentials []byte     `bson:"metric-credentials"`
+
+	// StatusRollup holds the rules used to derive the status of
+	// the service from the statuses of its units.
+	StatusRollup StatusRollupRules `bson:"statusrollup,omitempty"`
+
+	// WorkloadContainer holds the type of container in which the
+	// charms of the service's units run, if they do not run directly
+	// on the machines the units are assigned to.
+	WorkloadContainer instance.ContainerType `bson:"workloadcontainer,omitempty"`
+
+	// Channel holds the charm store channel tracked by the service,
+	// if it is not the stable channel.
+	Channel CharmChannel `bson:"channel,omitempty"`
+
+	// ChannelCharmURL holds the latest revision of the service's
+	// charm available in the channel it tracks.
+	ChannelCharmURL *charm.URL `bson:"channelcharmurl,omitempty"`
 }
 
 func newService(st *State, doc *serviceDoc) *Service {
@@ -224,11 +245,14 @@ func (s *Service) removeOps(asserts bson.D) []txn.Op {
 			Id:     settingsDocID,
 			Remove: true,
 		},
+		charmDecRefOp(s.st, s.doc.CharmURL),
 		removeRequestedNetworksOp(s.st, s.globalKey()),
 		removeStorageConstraintsOp(s.globalKey()),
 		removeConstraintsOp(s.st, s.globalKey()),
+		removeStatusOp(s.st, s.globalKey()),
 		annotationRemoveOp(s.st, s.globalKey()),
 		removeLeadershipSettingsOp(s.Tag().Id()),
+		s.st.newCleanupOp(cleanupSecretsForRemovedService, s.doc.Name),
 	}
 	return ops
 }
@@ -240,29 +264,113 @@ func (s *Service) IsExposed() bool {
 	return s.doc.Exposed
 }
 
-// SetExposed marks the service as exposed.
+// ExposedCIDRs returns the address ranges, in CIDR notation, from which
+// the open ports of the service may be reached while it is exposed. If
+// there are none, they may be reached from any address.
+func (s *Service) ExposedCIDRs() []string {
+	return s.doc.ExposedCIDRs
+}
+
+// SetExposed marks the service as exposed to any address.
 // See ClearExposed and IsExposed.
 func (s *Service) SetExposed() error {
-	return s.setExposed(true)
+	return s.setExposed(true, nil)
+}
+
+// SetExposedTo marks the service as exposed only to the given address
+// ranges, in CIDR notation. If none are given, the service is exposed
+// to any address. See ExposedCIDRs.
+func (s *Service) SetExposedTo(cidrs []string) error {
+	cidrs, err := network.NormaliseCIDRs(cidrs)
+	if err != nil {
+		return errors.Annotatef(err, "cannot expose service %q", s)
+	}
+	return s.setExposed(true, cidrs)
 }
 
 // ClearExposed removes the exposed flag from the service.
 // See SetExposed and IsExposed.
 func (s *Service) ClearExposed() error {
-	return s.setExposed(false)
+	return s.setExposed(false, nil)
 }
 
-func (s *Service) setExposed(exposed bool) (err error) {
+func (s *Service) setExposed(exposed bool, cidrs []string) (err error) {
+	update := bson.D{{"$set", bson.D{{"exposed", exposed}}}}
+	if len(cidrs) > 0 {
+		update = bson.D{{"$set", bson.D{{"exposed", exposed}, {"exposedcidrs", cidrs}}}}
+	} else {
+		update = append(update, bson.DocElem{"$unset", bson.D{{"exposedcidrs", nil}}})
+	}
 	ops := []txn.Op{{
 		C:      servicesC,
 		Id:     s.doc.DocID,
 		Assert: isAliveDoc,
-		Update: bson.D{{"$set", bson.D{{"exposed", exposed}}}},
+		Update: update,
 	}}
 	if err := s.st.runTransaction(ops); err != nil {
 		return fmt.Errorf("cannot set exposed flag for service %q to %v: %v", s, exposed, onAbort(err, errNotAlive))
 	}
 	s.doc.Exposed = exposed
+	s.doc.ExposedCIDRs = cidrs
+	return nil
+}
+
+// WorkloadContainer returns the type of container in which the charms
+// of the service's units run, on the machines the units are assigned
+// to. It returns instance.NONE if the charms run directly on the
+// machines.
+func (s *Service) WorkloadContainer() instance.ContainerType {
+	if s.doc.WorkloadContainer == "" {
+		return instance.NONE
+	}
+	return s.doc.WorkloadContainer
+}
+
+// SetWorkloadContainer sets the type of container in which the charms
+// of the service's units run. Each unit's charm is run in a container
+// of its own, created on the machine the unit is assigned to when the
+// unit is deployed there; instance.NONE runs the charms directly on
+// the machines. Only LXC containers are supported, and the setting can
+// only be changed while the service has no units.
+func (s *Service) SetWorkloadContainer(containerType instance.ContainerType) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set workload container for service %q", s)
+	switch containerType {
+	case instance.NONE:
+		containerType = ""
+	case instance.LXC:
+		if s.doc.Subordinate {
+			return errors.New("subordinate services cannot run in workload containers")
+		}
+	default:
+		return errors.NotSupportedf("workload container type %q", containerType)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if s.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if s.doc.UnitCount > 0 {
+			return nil, errors.New("service has units")
+		}
+		update := bson.D{{"$unset", bson.D{{"workloadcontainer", nil}}}}
+		if containerType != "" {
+			update = bson.D{{"$set", bson.D{{"workloadcontainer", containerType}}}}
+		}
+		return []txn.Op{{
+			C:      servicesC,
+			Id:     s.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"unitcount", 0}},
+			Update: update,
+		}}, nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return err
+	}
+	s.doc.WorkloadContainer = containerType
 	return nil
 }
 
@@ -409,6 +517,11 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// Add a reference to the new charm.
+	charmIncOps, err := charmIncRefOps(s.st, ch.URL())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	var decOps []txn.Op
 	// Drop the reference to the old settings doc (if they exist).
 	if oldSettings != nil {
@@ -417,6 +530,8 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 			return nil, errors.Trace(err)
 		}
 	}
+	// Drop the reference to the old charm.
+	decOps = append(decOps, charmDecRefOp(s.st, s.doc.CharmURL))
 
 	// Build the transaction.
 	var ops []txn.Op
@@ -438,6 +553,7 @@ func (s *Service) changeCharmOps(ch *Charm, force bool) ([]txn.Op, error) {
 			Update: bson.D{{"$set", bson.D{{"charmurl", ch.URL()}, {"forcecharm", force}}}},
 		},
 	}...)
+	ops = append(ops, charmIncOps...)
 	// Add any extra peer relations that need creation.
 	newPeers := s.extraPeerRelations(ch.Meta())
 	peerOps, err := s.st.addPeerRelationsOps(s.doc.Name, newPeers)
@@ -579,6 +695,9 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 	} else if !s.doc.Subordinate && principalName != "" {
 		return "", nil, fmt.Errorf("service is not a subordinate")
 	}
+	if err := s.checkUnitLimit(); err != nil {
+		return "", nil, err
+	}
 	name, err := s.newUnitName()
 	if err != nil {
 		return "", nil, err
@@ -624,7 +743,7 @@ func (s *Service) addUnitOps(principalName string, asserts bson.D) (string, []tx
 		{
 			C:      servicesC,
 			Id:     s.doc.DocID,
-			Assert: append(isAliveDoc, asserts...),
+			Assert: append(append(isAliveDoc, s.unitLimitAssert()...), asserts...),
 			Update: bson.D{{"$inc", bson.D{{"unitcount", 1}}}},
 		},
 	}
@@ -701,10 +820,17 @@ func (s *Service) AddUnit() (unit *Unit, err error) {
 		} else if !alive {
 			return nil, fmt.Errorf("service is not alive")
 		}
+		if err := s.Refresh(); err != nil {
+			return nil, err
+		}
+		if err := s.checkUnitLimit(); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("inconsistent state")
 	} else if err != nil {
 		return nil, err
 	}
+	refreshServiceStatus(s.st, s.doc.Name)
 	return s.st.Unit(name)
 }
 
@@ -723,6 +849,10 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 	if err != nil {
 		return nil, err
 	}
+	relationStateOps, err := removeUnitRelationStatesOps(s.st, u)
+	if err != nil {
+		return nil, err
+	}
 
 	observedFieldsMatch := bson.D{
 		{"charmurl", u.doc.CharmURL},
@@ -738,11 +868,15 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 		removeStatusOp(s.st, u.globalAgentKey()),
 		removeStatusOp(s.st, u.globalKey()),
 		removeMeterStatusOp(s.st, u.globalKey()),
+		removeHookQueueOp(s.st, u.globalKey()),
+		removeHookResultOp(s.st, u.globalKey()),
+		removeUnitDrainOp(s.st, u.globalKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
 		s.st.newCleanupOp(cleanupRemovedUnit, u.doc.Name),
 	)
 	ops = append(ops, portsOps...)
 	ops = append(ops, storageInstanceOps...)
+	ops = append(ops, relationStateOps...)
 	if u.doc.CharmURL != nil {
 		decOps, err := settingsDecRefOps(s.st, s.doc.Name, u.doc.CharmURL)
 		if errors.IsNotFound(err) {
@@ -751,6 +885,7 @@ func (s *Service) removeUnitOps(u *Unit, asserts bson.D) ([]txn.Op, error) {
 			return nil, err
 		}
 		ops = append(ops, decOps...)
+		ops = append(ops, charmDecRefOp(s.st, u.doc.CharmURL))
 	}
 	if s.doc.Life == Dying && s.doc.RelationCount == 0 && s.doc.UnitCount == 1 {
 		hasLastRef := bson.D{{"life", Dying}, {"relationcount", 0}, {"unitcount", 1}}
@@ -839,6 +974,9 @@ func (s *Service) UpdateConfigSettings(changes charm.Settings) error {
 	if err != nil {
 		return err
 	}
+	if err := validateConfigRules(charm.ConfigRules(), changes); err != nil {
+		return err
+	}
 	// TODO(fwereade) state.Settings is itself really problematic in just
 	// about every use case. This needs to be resolved some time; but at
 	// least the settings docs are keyed by charm url as well as service
@@ -870,12 +1008,13 @@ func (s *Service) Constraints() (constraints.Value, error) {
 
 // SetConstraints replaces the current service constraints.
 func (s *Service) SetConstraints(cons constraints.Value) (err error) {
-	unsupported, err := s.st.validateConstraints(cons)
+	unsupported, err := s.st.ValidateConstraints(cons)
+	if err != nil {
+		return err
+	}
 	if len(unsupported) > 0 {
 		logger.Warningf(
 			"setting constraints on service %q: unsupported constraints: %v", s.Name(), strings.Join(unsupported, ","))
-	} else if err != nil {
-		return err
 	}
 	if s.doc.Subordinate {
 		return ErrSubordinateConstraints
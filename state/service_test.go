@@ -19,6 +19,7 @@ import (
 
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/testing"
 )
@@ -1017,6 +1018,85 @@ func (s *ServiceSuite) TestServiceExposed(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, notAliveErr)
 }
 
+func (s *ServiceSuite) TestServiceExposedTo(c *gc.C) {
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+
+	err := s.mysql.SetExposedTo([]string{"192.168.0.0/16", "10.1.2.3/8"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.IsExposed(), jc.IsTrue)
+	c.Assert(s.mysql.ExposedCIDRs(), jc.DeepEquals, []string{"10.0.0.0/8", "192.168.0.0/16"})
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.ExposedCIDRs(), jc.DeepEquals, []string{"10.0.0.0/8", "192.168.0.0/16"})
+
+	// Exposing the service again without restrictions opens it to
+	// any address.
+	err = s.mysql.SetExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.IsExposed(), jc.IsTrue)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+
+	// Unexposing the service forgets any restrictions.
+	err = s.mysql.SetExposedTo([]string{"10.0.0.0/8"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.ClearExposed()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.IsExposed(), jc.IsFalse)
+	c.Assert(s.mysql.ExposedCIDRs(), gc.HasLen, 0)
+}
+
+func (s *ServiceSuite) TestServiceExposedToInvalidCIDR(c *gc.C) {
+	err := s.mysql.SetExposedTo([]string{"10.0.0.0"})
+	c.Assert(err, gc.ErrorMatches, `cannot expose service "mysql": CIDR "10.0.0.0" not valid`)
+	c.Assert(s.mysql.IsExposed(), jc.IsFalse)
+}
+
+func (s *ServiceSuite) TestWorkloadContainer(c *gc.C) {
+	c.Assert(s.mysql.WorkloadContainer(), gc.Equals, instance.NONE)
+
+	err := s.mysql.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.WorkloadContainer(), gc.Equals, instance.LXC)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.WorkloadContainer(), gc.Equals, instance.LXC)
+
+	err = s.mysql.SetWorkloadContainer(instance.NONE)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mysql.WorkloadContainer(), gc.Equals, instance.NONE)
+}
+
+func (s *ServiceSuite) TestSetWorkloadContainerUnsupported(c *gc.C) {
+	err := s.mysql.SetWorkloadContainer(instance.KVM)
+	c.Assert(err, gc.ErrorMatches, `cannot set workload container for service "mysql": workload container type "kvm" not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+
+	logging := s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	err = logging.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, gc.ErrorMatches, `cannot set workload container for service "logging": subordinate services cannot run in workload containers`)
+}
+
+func (s *ServiceSuite) TestSetWorkloadContainerWithUnits(c *gc.C) {
+	_, err := s.mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, gc.ErrorMatches, `cannot set workload container for service "mysql": service has units`)
+	c.Assert(s.mysql.WorkloadContainer(), gc.Equals, instance.NONE)
+}
+
+func (s *ServiceSuite) TestSetWorkloadContainerNotAlive(c *gc.C) {
+	err := s.mysql.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.mysql.SetWorkloadContainer(instance.LXC)
+	c.Assert(err, gc.ErrorMatches, `cannot set workload container for service "mysql": .*`)
+}
+
 func (s *ServiceSuite) TestAddUnit(c *gc.C) {
 	// Check that principal units can be added on their own.
 	unitZero, err := s.mysql.AddUnit()
@@ -1421,6 +1501,15 @@ func (s *ServiceSuite) TestSetInvalidConstraints(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
 }
 
+func (s *ServiceSuite) TestSetInvalidConstraintsWithUnsupported(c *gc.C) {
+	cons := constraints.MustParse("mem=4G instance-type=foo cpu-power=10")
+	err := s.mysql.SetConstraints(cons)
+	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
+	scons, err := s.mysql.Constraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(&scons, jc.Satisfies, constraints.IsEmpty)
+}
+
 func (s *ServiceSuite) TestSetUnsupportedConstraintsWarning(c *gc.C) {
 	defer loggo.ResetWriters()
 	logger := loggo.GetLogger("test")
@@ -0,0 +1,253 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// defaultStatusSeverity orders unit workload statuses from most to
+// least severe, and is used to roll up the status of a service's
+// units when the service has no rules of its own.
+var defaultStatusSeverity = []Status{
+	StatusError,
+	StatusBlocked,
+	StatusWaiting,
+	StatusBusy,
+	StatusUnknown,
+	StatusRunning,
+	StatusRemoving,
+	StatusGone,
+}
+
+// StatusRollupRules control how the workload statuses of a service's
+// units are rolled up into the status of the service. The service
+// takes the most severe status held by any of its units.
+type StatusRollupRules struct {
+	// Severity orders unit workload statuses from most to least
+	// severe. If empty, a default ordering placing error first and
+	// running last is used. Statuses not listed rank below all
+	// listed statuses.
+	Severity []Status `bson:"severity,omitempty"`
+
+	// Ignore holds unit workload statuses which do not contribute
+	// to the status of the service.
+	Ignore []Status `bson:"ignore,omitempty"`
+}
+
+// Validate returns an error if the rules refer to statuses that
+// units cannot hold.
+func (r StatusRollupRules) Validate() error {
+	seen := make(map[Status]bool)
+	for _, status := range r.Severity {
+		if !unitStatusValid(status) {
+			return errors.NotValidf("status %q in severity order", status)
+		}
+		if seen[status] {
+			return errors.NotValidf("duplicate status %q in severity order", status)
+		}
+		seen[status] = true
+	}
+	for _, status := range r.Ignore {
+		if !unitStatusValid(status) {
+			return errors.NotValidf("ignored status %q", status)
+		}
+	}
+	return nil
+}
+
+// rank returns the severity of the given status under the rules;
+// lower values are more severe.
+func (r StatusRollupRules) rank(status Status) int {
+	severity := r.Severity
+	if len(severity) == 0 {
+		severity = defaultStatusSeverity
+	}
+	for i, s := range severity {
+		if s == status {
+			return i
+		}
+	}
+	return len(severity)
+}
+
+// ignores returns whether the rules exclude the given status from
+// the rollup.
+func (r StatusRollupRules) ignores(status Status) bool {
+	for _, s := range r.Ignore {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// unitStatus pairs a unit name with its workload status document.
+type unitStatus struct {
+	name string
+	doc  statusDoc
+}
+
+// rollupStatus returns the status of a service with the given rules
+// and units. A service with no contributing units has unknown status.
+func rollupStatus(rules StatusRollupRules, units []unitStatus) statusDoc {
+	sort.Sort(unitStatusesByName(units))
+	var worst *unitStatus
+	for i, unit := range units {
+		if rules.ignores(unit.doc.Status) {
+			continue
+		}
+		if worst == nil || rules.rank(unit.doc.Status) < rules.rank(worst.doc.Status) {
+			worst = &units[i]
+		}
+	}
+	if worst == nil {
+		return statusDoc{Status: StatusUnknown}
+	}
+	// The info of the first unit holding the status, by name,
+	// explains it.
+	doc := statusDoc{Status: worst.doc.Status}
+	if worst.doc.StatusInfo != "" {
+		doc.StatusInfo = fmt.Sprintf("%s: %s", worst.name, worst.doc.StatusInfo)
+	}
+	return doc
+}
+
+type unitStatusesByName []unitStatus
+
+func (u unitStatusesByName) Len() int           { return len(u) }
+func (u unitStatusesByName) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u unitStatusesByName) Less(i, j int) bool { return u[i].name < u[j].name }
+
+// computeStatus returns the status of the service derived from the
+// workload statuses of its units.
+func (s *Service) computeStatus() (statusDoc, error) {
+	units, err := s.AllUnits()
+	if err != nil {
+		return statusDoc{}, errors.Trace(err)
+	}
+	ids := make([]string, len(units))
+	names := make(map[string]string)
+	for i, unit := range units {
+		ids[i] = s.st.docID(unit.globalKey())
+		names[ids[i]] = unit.Name()
+	}
+	statuses, closer := s.st.getCollection(statusesC)
+	defer closer()
+
+	var docs []struct {
+		DocID     string `bson:"_id"`
+		statusDoc `bson:",inline"`
+	}
+	if err := statuses.Find(bson.D{{"_id", bson.D{{"$in", ids}}}}).All(&docs); err != nil {
+		return statusDoc{}, errors.Annotatef(err, "cannot get unit statuses for service %q", s)
+	}
+	unitStatuses := make([]unitStatus, len(docs))
+	for i, doc := range docs {
+		unitStatuses[i] = unitStatus{names[doc.DocID], doc.statusDoc}
+	}
+	doc := rollupStatus(s.doc.StatusRollup, unitStatuses)
+	doc.EnvUUID = s.st.EnvironUUID()
+	return doc, nil
+}
+
+// Status returns the status of the service, rolled up from the
+// workload statuses of its units according to the service's
+// StatusRollupRules.
+func (s *Service) Status() (status Status, info string, data map[string]interface{}, err error) {
+	doc, err := getStatus(s.st, s.globalKey())
+	if errors.IsNotFound(err) {
+		// The status has not been recorded since the service was
+		// created, so work it out now.
+		doc, err = s.computeStatus()
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+	return doc.Status, doc.StatusInfo, doc.StatusData, nil
+}
+
+// StatusRollupRules returns the rules used to derive the status of the
+// service from the statuses of its units.
+func (s *Service) StatusRollupRules() StatusRollupRules {
+	return s.doc.StatusRollup
+}
+
+// SetStatusRollupRules changes the rules used to derive the status of
+// the service from the statuses of its units, and updates the status
+// of the service accordingly. Empty rules restore the default
+// behaviour.
+func (s *Service) SetStatusRollupRules(rules StatusRollupRules) error {
+	if err := rules.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	ops := []txn.Op{{
+		C:      servicesC,
+		Id:     s.doc.DocID,
+		Assert: isAliveDoc,
+		Update: bson.D{{"$set", bson.D{{"statusrollup", rules}}}},
+	}}
+	if err := s.st.runTransaction(ops); err != nil {
+		return errors.Errorf("cannot set status rollup rules for service %q: %v", s, onAbort(err, errNotAlive))
+	}
+	s.doc.StatusRollup = rules
+	return updateServiceStatus(s.st, s.doc.Name)
+}
+
+// WatchStatus returns a watcher that notifies of changes to the
+// status of the service.
+func (s *Service) WatchStatus() NotifyWatcher {
+	return newEntityWatcher(s.st, statusesC, s.st.docID(s.globalKey()))
+}
+
+// updateServiceStatus records the status of the named service, derived
+// from the current statuses of its units. It does nothing if the
+// service no longer exists.
+func updateServiceStatus(st *State, serviceName string) error {
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		svc, err := st.Service(serviceName)
+		if errors.IsNotFound(err) {
+			return nil, jujutxn.ErrNoOperations
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc, err := svc.computeStatus()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		existing, err := getStatus(st, svc.globalKey())
+		if errors.IsNotFound(err) {
+			return []txn.Op{{
+				C:      servicesC,
+				Id:     svc.doc.DocID,
+				Assert: txn.DocExists,
+			}, createStatusOp(st, svc.globalKey(), doc)}, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if existing.Status == doc.Status && existing.StatusInfo == doc.StatusInfo {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return []txn.Op{updateStatusOp(st, svc.globalKey(), doc)}, nil
+	}
+	if err := st.run(buildTxn); err != nil && err != jujutxn.ErrNoOperations {
+		return errors.Annotatef(err, "cannot update status of service %q", serviceName)
+	}
+	return nil
+}
+
+// refreshServiceStatus updates the status of the named service after a
+// change to its units. The service status is derived data, so failures
+// are logged rather than returned; the next change will correct it.
+func refreshServiceStatus(st *State, serviceName string) {
+	if err := updateServiceStatus(st, serviceName); err != nil {
+		logger.Warningf("%v", err)
+	}
+}
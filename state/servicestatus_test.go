@@ -0,0 +1,157 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/testing"
+)
+
+type ServiceStatusSuite struct {
+	ConnSuite
+	service *state.Service
+}
+
+var _ = gc.Suite(&ServiceStatusSuite{})
+
+func (s *ServiceStatusSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.service = s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+}
+
+func (s *ServiceStatusSuite) assertStatus(c *gc.C, status state.Status, info string) {
+	current, currentInfo, _, err := s.service.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, gc.Equals, status)
+	c.Assert(currentInfo, gc.Equals, info)
+}
+
+func (s *ServiceStatusSuite) TestStatusWithoutUnits(c *gc.C) {
+	s.assertStatus(c, state.StatusUnknown, "")
+}
+
+func (s *ServiceStatusSuite) TestStatusRollsUpUnits(c *gc.C) {
+	u0, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	u1, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBusy, "")
+
+	err = u0.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBusy, "")
+
+	err = u1.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusRunning, "")
+
+	err = u1.SetStatus(state.StatusBlocked, "need database", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBlocked, "mysql/1: need database")
+
+	err = u0.SetStatus(state.StatusError, "hook failed", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusError, "mysql/0: hook failed")
+
+	err = u0.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBlocked, "mysql/1: need database")
+}
+
+func (s *ServiceStatusSuite) TestStatusRollupRules(c *gc.C) {
+	u0, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = u0.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBusy, "")
+
+	rules := state.StatusRollupRules{
+		Severity: []state.Status{state.StatusRunning, state.StatusBusy},
+	}
+	err = s.service.SetStatusRollupRules(rules)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.service.StatusRollupRules(), jc.DeepEquals, rules)
+	s.assertStatus(c, state.StatusRunning, "")
+
+	err = s.service.SetStatusRollupRules(state.StatusRollupRules{
+		Ignore: []state.Status{state.StatusBusy},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusRunning, "")
+
+	err = s.service.SetStatusRollupRules(state.StatusRollupRules{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStatus(c, state.StatusBusy, "")
+
+	service, err := s.State.Service("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(service.StatusRollupRules(), jc.DeepEquals, state.StatusRollupRules{})
+}
+
+func (s *ServiceStatusSuite) TestSetStatusRollupRulesInvalid(c *gc.C) {
+	err := s.service.SetStatusRollupRules(state.StatusRollupRules{
+		Severity: []state.Status{state.StatusStarted},
+	})
+	c.Assert(err, gc.ErrorMatches, `status "started" in severity order not valid`)
+
+	err = s.service.SetStatusRollupRules(state.StatusRollupRules{
+		Severity: []state.Status{state.StatusBusy, state.StatusBusy},
+	})
+	c.Assert(err, gc.ErrorMatches, `duplicate status "busy" in severity order not valid`)
+
+	err = s.service.SetStatusRollupRules(state.StatusRollupRules{
+		Ignore: []state.Status{state.StatusPending},
+	})
+	c.Assert(err, gc.ErrorMatches, `ignored status "pending" not valid`)
+}
+
+func (s *ServiceStatusSuite) TestSubordinateStatus(c *gc.C) {
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	logging := s.AddTestingService(c, "logging", s.AddTestingCharm(c, "logging"))
+	eps, err := s.State.InferEndpoints("logging", "wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	unit, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	ru, err := rel.Unit(unit)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ru.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, _, _, err := logging.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.StatusBusy)
+}
+
+func (s *ServiceStatusSuite) TestWatchStatus(c *gc.C) {
+	w := s.service.WatchStatus()
+	defer testing.AssertStop(c, w)
+
+	// Initial event.
+	wc := testing.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	unit, err := s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// A unit change which leaves the rollup unchanged is not
+	// reported.
+	_, err = s.service.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertNoChange()
+
+	err = unit.SetStatus(state.StatusError, "oops", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
@@ -0,0 +1,203 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/utils/ssh"
+)
+
+// sshKeyDoc records an ssh key held in an environment user's key
+// namespace.
+type sshKeyDoc struct {
+	DocID       string `bson:"_id"`
+	EnvUUID     string `bson:"env-uuid"`
+	User        string `bson:"user"`
+	Fingerprint string `bson:"fingerprint"`
+	Key         string `bson:"key"`
+
+	// Expires holds when the key stops being authorised, or nil
+	// if it never does.
+	Expires *time.Time `bson:"expires,omitempty"`
+}
+
+// SSHKey is an ssh key held in an environment user's key namespace.
+type SSHKey struct {
+	doc sshKeyDoc
+}
+
+// User returns the name of the user the key belongs to.
+func (k *SSHKey) User() string {
+	return k.doc.User
+}
+
+// Fingerprint returns the key's fingerprint.
+func (k *SSHKey) Fingerprint() string {
+	return k.doc.Fingerprint
+}
+
+// Key returns the key, as written in an authorized_keys file.
+func (k *SSHKey) Key() string {
+	return k.doc.Key
+}
+
+// Expires returns when the key stops being authorised, and whether it
+// ever does.
+func (k *SSHKey) Expires() (time.Time, bool) {
+	if k.doc.Expires == nil {
+		return time.Time{}, false
+	}
+	return k.doc.Expires.UTC(), true
+}
+
+// Expired reports whether the key has stopped being authorised at the
+// given time.
+func (k *SSHKey) Expired(now time.Time) bool {
+	return k.doc.Expires != nil && !now.Before(*k.doc.Expires)
+}
+
+// sshKeyId returns the id of the key with the given fingerprint in
+// the given user's namespace.
+func sshKeyId(user, fingerprint string) string {
+	return fmt.Sprintf("%s#%s", user, fingerprint)
+}
+
+// AddSSHKey adds the given ssh key to the given environment user's key
+// namespace. If expires is not the zero time, the key stops being
+// authorised at that time.
+func (st *State) AddSSHKey(user names.UserTag, key string, expires time.Time) (*SSHKey, error) {
+	fingerprint, _, err := ssh.KeyFingerprint(key)
+	if err != nil {
+		return nil, errors.NotValidf("ssh key %q", key)
+	}
+	username := user.Username()
+	doc := sshKeyDoc{
+		DocID:       st.docID(sshKeyId(username, fingerprint)),
+		EnvUUID:     st.EnvironUUID(),
+		User:        username,
+		Fingerprint: fingerprint,
+		Key:         key,
+	}
+	if !expires.IsZero() {
+		expires = expires.UTC()
+		doc.Expires = &expires
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if _, err := st.EnvironmentUser(user); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if attempt > 0 {
+			if _, err := st.SSHKey(user, fingerprint); err == nil {
+				return nil, errors.AlreadyExistsf("ssh key %s", fingerprint)
+			} else if !errors.IsNotFound(err) {
+				return nil, errors.Trace(err)
+			}
+		}
+		return []txn.Op{{
+			C:      envUsersC,
+			Id:     st.docID(username),
+			Assert: txn.DocExists,
+		}, {
+			C:      sshKeysC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: &doc,
+		}}, nil
+	}
+	if err := st.run(buildTxn); err != nil {
+		return nil, errors.Annotatef(err, "cannot add ssh key for user %q", username)
+	}
+	return &SSHKey{doc}, nil
+}
+
+// SSHKey returns the key with the given fingerprint in the given
+// environment user's key namespace.
+func (st *State) SSHKey(user names.UserTag, fingerprint string) (*SSHKey, error) {
+	sshKeys, closer := st.getCollection(sshKeysC)
+	defer closer()
+
+	var doc sshKeyDoc
+	err := sshKeys.FindId(sshKeyId(user.Username(), fingerprint)).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("ssh key %s for user %q", fingerprint, user.Username())
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get ssh key %s for user %q", fingerprint, user.Username())
+	}
+	return &SSHKey{doc}, nil
+}
+
+// SSHKeys returns the keys in the given environment user's key
+// namespace, ordered by fingerprint. Expired keys are included.
+func (st *State) SSHKeys(user names.UserTag) ([]*SSHKey, error) {
+	return st.sshKeys(bson.D{{"user", user.Username()}})
+}
+
+// AllSSHKeys returns the keys in every environment user's key
+// namespace, ordered by user and fingerprint. Expired keys are
+// included.
+func (st *State) AllSSHKeys() ([]*SSHKey, error) {
+	return st.sshKeys(nil)
+}
+
+func (st *State) sshKeys(query bson.D) ([]*SSHKey, error) {
+	sshKeys, closer := st.getCollection(sshKeysC)
+	defer closer()
+
+	var docs []sshKeyDoc
+	if err := sshKeys.Find(query).Sort("user", "fingerprint").All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get ssh keys")
+	}
+	keys := make([]*SSHKey, len(docs))
+	for i, doc := range docs {
+		keys[i] = &SSHKey{doc}
+	}
+	return keys, nil
+}
+
+// RemoveSSHKey removes the key with the given fingerprint from the
+// given environment user's key namespace.
+func (st *State) RemoveSSHKey(user names.UserTag, fingerprint string) error {
+	ops := []txn.Op{{
+		C:      sshKeysC,
+		Id:     st.docID(sshKeyId(user.Username(), fingerprint)),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		return errors.NotFoundf("ssh key %s for user %q", fingerprint, user.Username())
+	}
+	return errors.Annotatef(err, "cannot remove ssh key %s for user %q", fingerprint, user.Username())
+}
+
+// removeSSHKeysOps returns the operations that remove every key in the
+// given user's key namespace.
+func (st *State) removeSSHKeysOps(username string) ([]txn.Op, error) {
+	sshKeys, closer := st.getCollection(sshKeysC)
+	defer closer()
+
+	var docs []struct {
+		DocID string `bson:"_id"`
+	}
+	if err := sshKeys.Find(bson.D{{"user", username}}).Select(bson.D{{"_id", 1}}).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get ssh keys for user %q", username)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      sshKeysC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
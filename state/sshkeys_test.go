@@ -0,0 +1,156 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/utils/ssh"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
+)
+
+type SSHKeysSuite struct {
+	ConnSuite
+	user names.UserTag
+}
+
+var _ = gc.Suite(&SSHKeysSuite{})
+
+func (s *SSHKeysSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.user = s.factory.MakeEnvUser(c, nil).UserTag()
+}
+
+func fingerprint(c *gc.C, key string) string {
+	fingerprint, _, err := ssh.KeyFingerprint(key)
+	c.Assert(err, jc.ErrorIsNil)
+	return fingerprint
+}
+
+func (s *SSHKeysSuite) TestAddSSHKey(c *gc.C) {
+	key := sshtesting.ValidKeyOne.Key + " user@host"
+	added, err := s.State.AddSSHKey(s.user, key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(added.User(), gc.Equals, s.user.Username())
+	c.Assert(added.Key(), gc.Equals, key)
+	c.Assert(added.Fingerprint(), gc.Equals, fingerprint(c, key))
+	_, ok := added.Expires()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(added.Expired(time.Now().Add(1000*time.Hour)), jc.IsFalse)
+
+	sshKey, err := s.State.SSHKey(s.user, added.Fingerprint())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sshKey.Key(), gc.Equals, key)
+}
+
+func (s *SSHKeysSuite) TestAddSSHKeyExpires(c *gc.C) {
+	expires := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	added, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, expires)
+	c.Assert(err, jc.ErrorIsNil)
+
+	sshKey, err := s.State.SSHKey(s.user, added.Fingerprint())
+	c.Assert(err, jc.ErrorIsNil)
+	when, ok := sshKey.Expires()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(when.Equal(expires), jc.IsTrue)
+	c.Assert(sshKey.Expired(expires.Add(-time.Second)), jc.IsFalse)
+	c.Assert(sshKey.Expired(expires), jc.IsTrue)
+}
+
+func (s *SSHKeysSuite) TestAddSSHKeyInvalid(c *gc.C) {
+	_, err := s.State.AddSSHKey(s.user, "bad key", time.Time{})
+	c.Assert(err, gc.ErrorMatches, `ssh key "bad key" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *SSHKeysSuite) TestAddSSHKeyDuplicate(c *gc.C) {
+	_, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key+" other@host", time.Time{})
+	c.Assert(err, gc.ErrorMatches, `cannot add ssh key for user ".*": ssh key .* already exists`)
+	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsAlreadyExists)
+
+	// The same key may be held by another user.
+	other := s.factory.MakeEnvUser(c, nil).UserTag()
+	_, err = s.State.AddSSHKey(other, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SSHKeysSuite) TestAddSSHKeyNotEnvironmentUser(c *gc.C) {
+	_, err := s.State.AddSSHKey(names.NewUserTag("nobody"), sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, gc.ErrorMatches, `cannot add ssh key for user "nobody@local": .*not found`)
+}
+
+func (s *SSHKeysSuite) TestSSHKeys(c *gc.C) {
+	other := s.factory.MakeEnvUser(c, nil).UserTag()
+	key1, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	key2, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyTwo.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	key3, err := s.State.AddSSHKey(other, sshtesting.ValidKeyThree.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	keys, err := s.State.SSHKeys(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 2)
+	c.Assert([]string{keys[0].Key(), keys[1].Key()}, jc.SameContents, []string{key1.Key(), key2.Key()})
+
+	keys, err = s.State.AllSSHKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 3)
+	var all []string
+	for _, key := range keys {
+		all = append(all, key.Key())
+	}
+	c.Assert(all, jc.SameContents, []string{key1.Key(), key2.Key(), key3.Key()})
+}
+
+func (s *SSHKeysSuite) TestRemoveSSHKey(c *gc.C) {
+	key, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveSSHKey(s.user, key.Fingerprint())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.SSHKey(s.user, key.Fingerprint())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RemoveSSHKey(s.user, key.Fingerprint())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SSHKeysSuite) TestRemoveEnvironmentUserRemovesKeys(c *gc.C) {
+	_, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RemoveEnvironmentUser(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	keys, err := s.State.AllSSHKeys()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 0)
+}
+
+func (s *SSHKeysSuite) TestWatchAuthorisedKeys(c *gc.C) {
+	w := s.State.WatchAuthorisedKeys()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	key, err := s.State.AddSSHKey(s.user, sshtesting.ValidKeyOne.Key, time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"authorized-keys": sshtesting.ValidKeyTwo.Key,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.State.RemoveSSHKey(s.user, key.Fingerprint())
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
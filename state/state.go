@@ -40,6 +40,8 @@ const (
 	// The following define the mongo collections used to record the Juju environment state.
 	environmentsC      = "environments"
 	charmsC            = "charms"
+	charmrefsC         = "charmrefs"
+	maintenanceC       = "maintenance"
 	machinesC          = "machines"
 	containerRefsC     = "containerRefs"
 	instanceDataC      = "instanceData"
@@ -63,6 +65,12 @@ const (
 	// actionNotificationsC are only used for notification of newly
 	// enqueued Actions.
 	actionNotificationsC = "actionnotifications"
+	// actionSchedulesC holds actions which are to be enqueued at a
+	// future time, once or repeatedly.
+	actionSchedulesC = "actionschedules"
+	// actionOutputC holds the output written by actions while they
+	// run, in chunks.
+	actionOutputC = "actionoutput"
 	// actionResultsC is deprecated and will soon be folded into
 	// actionsC.
 	actionresultsC = "actionresults"
@@ -73,11 +81,14 @@ const (
 	cleanupsC              = "cleanups"
 	annotationsC           = "annotations"
 	statusesC              = "statuses"
+	statusesHistoryC       = "statuseshistory"
 	stateServersC          = "stateServers"
 	openedPortsC           = "openedPorts"
 	metricsC               = "metrics"
 	upgradeInfoC           = "upgradeInfo"
 	rebootC                = "reboot"
+	evacuationsC           = "evacuations"
+	manualHostsC           = "manualhosts"
 	blockDevicesC          = "blockdevices"
 	storageAttachmentsC    = "storageattachments"
 	storageConstraintsC    = "storageconstraints"
@@ -96,6 +107,16 @@ const (
 	// meterStatusC is the collection used to store meter status information.
 	meterStatusC = "meterStatus"
 
+	// secretsC holds the encrypted secrets owned by services, and
+	// secretKeysC the key each environment's secrets are encrypted
+	// with.
+	secretsC    = "secrets"
+	secretKeysC = "secretkeys"
+
+	// instanceMetadataC caches the instance types and images offered
+	// by each environment's provider.
+	instanceMetadataC = "instancemetadata"
+
 	// toolsmetadataC is the collection used to store tools metadata.
 	toolsmetadataC = "toolsmetadata"
 
@@ -109,9 +130,68 @@ const (
 	// restoreInfoC is used to track restore progress
 	restoreInfoC = "restoreInfo"
 
+	// mongoRestartsC records which state server, if any, is
+	// restarting its mongo database to apply new settings.
+	mongoRestartsC = "mongorestarts"
+
 	// blocksC is used to identify collection of environment blocks.
 	blocksC = "blocks"
 
+	// logForwardTargetsC holds the external targets to which
+	// stored logs are forwarded, and logForwardStatusC records
+	// how far forwarding to each target has got in each
+	// environment.
+	logForwardTargetsC = "logforwardtargets"
+	logForwardStatusC  = "logforwardstatus"
+
+	// cloudsC holds the clouds on which the state server may host
+	// environments, and cloudCredentialsC the credentials users
+	// hold for them.
+	cloudsC           = "clouds"
+	cloudCredentialsC = "cloudcredentials"
+
+	// osUpdatesC holds the security updates pending on each machine,
+	// and patchRunsC the rolling runs that apply them.
+	osUpdatesC = "osupdates"
+	patchRunsC = "patchruns"
+
+	// sshKeysC holds the ssh keys in each environment user's key
+	// namespace.
+	sshKeysC = "sshkeys"
+
+	// hookQueuesC holds the hooks each unit's agent has yet to run.
+	hookQueuesC = "hookqueues"
+
+	// hookResultsC holds the outcome, and the tail of the output, of
+	// the hook each unit's agent ran most recently.
+	hookResultsC = "hookresults"
+
+	// containerAddressPoolsC holds the controller-managed address pools
+	// for the bridges on host machines, and containerAddressesC the
+	// addresses allocated from them to containers.
+	containerAddressPoolsC = "containeraddresspools"
+	containerAddressesC    = "containeraddresses"
+
+	// dnsRecordsC holds the host names and addresses of machines
+	// registered in the environment's DNS backend.
+	dnsRecordsC = "dnsrecords"
+
+	// upgradeSeriesC records the progress of machines whose series
+	// is being upgraded.
+	upgradeSeriesC = "upgradeseries"
+
+	// unitDrainsC records the progress of dying units through
+	// running their relation-broken and stop hooks.
+	unitDrainsC = "unitdrains"
+
+	// unitRelationStatesC records, for each relation of each unit,
+	// which relation hooks the unit's agent has run.
+	unitRelationStatesC = "unitrelationstates"
+
+	// heartbeatsC records, for each API server, the agents
+	// connected to it and when they were last heard from.
+	heartbeatsC = "heartbeats"
+
 	// The following mongo collections are used as unique key restraints. The
 	// _id field of each collection is a concatenation of multiple fields
 	// that form a compound index.
@@ -480,6 +560,9 @@ func (st *State) buildAndValidateEnvironConfig(updateAttrs map[string]interface{
 			return nil, errors.Trace(err)
 		}
 	}
+	if err := config.Validate(newConfig, oldConfig); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if err := checkEnvironConfig(newConfig); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -542,12 +625,13 @@ func (st *State) EnvironConstraints() (constraints.Value, error) {
 
 // SetEnvironConstraints replaces the current environment constraints.
 func (st *State) SetEnvironConstraints(cons constraints.Value) error {
-	unsupported, err := st.validateConstraints(cons)
+	unsupported, err := st.ValidateConstraints(cons)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	if len(unsupported) > 0 {
 		logger.Warningf(
 			"setting environment constraints: unsupported constraints: %v", strings.Join(unsupported, ","))
-	} else if err != nil {
-		return errors.Trace(err)
 	}
 	return writeConstraints(st, environGlobalKey, cons)
 }
@@ -777,16 +861,31 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, storagePath, bundleSh
 
 	err = charms.Find(bson.D{{"_id", curl.String()}, {"placeholder", true}}).One(&existing)
 	if err == mgo.ErrNotFound {
+		rules, err := charmConfigRules(ch)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot add charm %q", curl)
+		}
+		interpreters, err := charmInterpreters(ch)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot add charm %q", curl)
+		}
+		relationLimits, err := charmRelationLimits(ch)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot add charm %q", curl)
+		}
 		cdoc := &charmDoc{
-			DocID:        st.docID(curl.String()),
-			URL:          curl,
-			EnvUUID:      st.EnvironTag().Id(),
-			Meta:         ch.Meta(),
-			Config:       ch.Config(),
-			Metrics:      ch.Metrics(),
-			Actions:      ch.Actions(),
-			BundleSha256: bundleSha256,
-			StoragePath:  storagePath,
+			DocID:          st.docID(curl.String()),
+			URL:            curl,
+			EnvUUID:        st.EnvironTag().Id(),
+			Meta:           ch.Meta(),
+			Config:         ch.Config(),
+			ConfigRules:    escapeConfigRules(rules),
+			Interpreters:   interpreters,
+			RelationLimits: relationLimits,
+			Metrics:        ch.Metrics(),
+			Actions:        ch.Actions(),
+			BundleSha256:   bundleSha256,
+			StoragePath:    storagePath,
 		}
 		err = charms.Insert(cdoc)
 		if err != nil {
@@ -796,7 +895,7 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, storagePath, bundleSh
 	} else if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return st.updateCharmDoc(ch, curl, storagePath, bundleSha256, stillPlaceholder)
+	return st.updateCharmDoc(ch, curl, storagePath, bundleSha256, 0, stillPlaceholder)
 }
 
 // AllCharms returns all charms in state.
@@ -1128,8 +1227,9 @@ func IsCharmAlreadyUploadedError(err interface{}) bool {
 var ErrCharmRevisionAlreadyModified = fmt.Errorf("charm revision already modified")
 
 // UpdateUploadedCharm marks the given charm URL as uploaded and
-// updates the rest of its data, returning it as *state.Charm.
-func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string) (*Charm, error) {
+// updates the rest of its data, including the size of its archive in
+// bytes, returning it as *state.Charm.
+func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string, bundleSize int64) (*Charm, error) {
 	charms, closer := st.getCollection(charmsC)
 	defer closer()
 
@@ -1145,7 +1245,7 @@ func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, storagePat
 		return nil, errors.Trace(&ErrCharmAlreadyUploaded{curl})
 	}
 
-	return st.updateCharmDoc(ch, curl, storagePath, bundleSha256, stillPending)
+	return st.updateCharmDoc(ch, curl, storagePath, bundleSha256, bundleSize, stillPending)
 }
 
 // updateCharmDoc updates the charm with specified URL with the given
@@ -1153,7 +1253,7 @@ func (st *State) UpdateUploadedCharm(ch charm.Charm, curl *charm.URL, storagePat
 // charm is no longer a placeholder or pending (depending on preReq),
 // it returns ErrCharmRevisionAlreadyModified.
 func (st *State) updateCharmDoc(
-	ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string, preReq interface{}) (*Charm, error) {
+	ch charm.Charm, curl *charm.URL, storagePath, bundleSha256 string, bundleSize int64, preReq interface{}) (*Charm, error) {
 
 	// Make sure we escape any "$" and "." in config option names
 	// first. See http://pad.lv/1308146.
@@ -1163,13 +1263,29 @@ func (st *State) updateCharmDoc(
 		escapedName := escapeReplacer.Replace(optionName)
 		escapedConfig.Options[escapedName] = option
 	}
+	rules, err := charmConfigRules(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot update charm %q", curl)
+	}
+	interpreters, err := charmInterpreters(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot update charm %q", curl)
+	}
+	relationLimits, err := charmRelationLimits(ch)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot update charm %q", curl)
+	}
 	updateFields := bson.D{{"$set", bson.D{
 		{"meta", ch.Meta()},
 		{"config", escapedConfig},
+		{"configrules", escapeConfigRules(rules)},
+		{"interpreters", interpreters},
+		{"relationlimits", relationLimits},
 		{"actions", ch.Actions()},
 		{"metrics", ch.Metrics()},
 		{"storagepath", storagePath},
 		{"bundlesha256", bundleSha256},
+		{"bundlesize", bundleSize},
 		{"pendingupload", false},
 		{"placeholder", false},
 	}}}
@@ -1296,6 +1412,12 @@ func (st *State) AddService(
 			Insert: svcDoc,
 		},
 	}
+	// Add a reference to the service's charm.
+	charmIncOps, err := charmIncRefOps(st, ch.URL())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops = append(ops, charmIncOps...)
 	// Collect peer relation addition operations.
 	peerOps, err := st.addPeerRelationsOps(name, peers)
 	if err != nil {
@@ -1310,6 +1432,11 @@ func (st *State) AddService(
 		} else if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if _, err := st.Charm(ch.URL()); errors.IsNotFound(err) {
+			return nil, errors.Errorf("charm %q has been removed", ch.URL())
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
 		return nil, errors.Errorf("service already exists")
 	} else if err != nil {
 		return nil, errors.Trace(err)
@@ -1598,9 +1725,11 @@ func (st *State) strictLocalID(ID string) (string, error) {
 // If the supplied names uniquely specify a possible relation, or if they
 // uniquely specify a possible relation once all implicit relations have been
 // filtered, the endpoints corresponding to that relation will be returned.
+// If no relation is possible, the error explains why.
 func (st *State) InferEndpoints(names ...string) ([]Endpoint, error) {
 	// Collect all possible sane endpoint lists.
 	var candidates [][]Endpoint
+	var eps1, eps2 []Endpoint
 	switch len(names) {
 	case 1:
 		eps, err := st.endpoints(names[0], isPeer)
@@ -1611,11 +1740,12 @@ func (st *State) InferEndpoints(names ...string) ([]Endpoint, error) {
 			candidates = append(candidates, []Endpoint{ep})
 		}
 	case 2:
-		eps1, err := st.endpoints(names[0], notPeer)
+		var err error
+		eps1, err = st.endpoints(names[0], notPeer)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		eps2, err := st.endpoints(names[1], notPeer)
+		eps2, err = st.endpoints(names[1], notPeer)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -1632,7 +1762,13 @@ func (st *State) InferEndpoints(names ...string) ([]Endpoint, error) {
 	// If there's ambiguity, try discarding implicit relations.
 	switch len(candidates) {
 	case 0:
-		return nil, errors.Errorf("no relations found")
+		var reason string
+		if len(names) == 1 {
+			reason = noPeerRelationReason(names[0])
+		} else {
+			reason = st.noRelationReason(names, eps1, eps2)
+		}
+		return nil, errors.Errorf("no relations found: %s", reason)
 	case 1:
 		return candidates[0], nil
 	}
@@ -1783,10 +1919,15 @@ func (st *State) AddRelation(eps ...Endpoint) (r *Relation, err error) {
 			if !ep.ImplementedBy(ch) {
 				return nil, errors.Errorf("%q does not implement %q", ep.ServiceName, ep)
 			}
+			if err := checkRelationLimit(svc, ch, ep); err != nil {
+				return nil, errors.Trace(err)
+			}
+			// Asserting the relation count ensures that relations added
+			// concurrently are seen by the limit check on a retry.
 			ops = append(ops, txn.Op{
 				C:      servicesC,
 				Id:     st.docID(ep.ServiceName),
-				Assert: bson.D{{"life", Alive}, {"charmurl", ch.URL()}},
+				Assert: bson.D{{"life", Alive}, {"charmurl", ch.URL()}, {"relationcount", svc.doc.RelationCount}},
 				Update: bson.D{{"$inc", bson.D{{"relationcount", 1}}}},
 			})
 		}
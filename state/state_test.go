@@ -824,18 +824,18 @@ func (s *StateSuite) TestUpdateUploadedCharm(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Test with already uploaded and a missing charms.
-	sch, err := s.State.UpdateUploadedCharm(ch, curl, storagePath, bundleSHA256)
+	sch, err := s.State.UpdateUploadedCharm(ch, curl, storagePath, bundleSHA256, 1234)
 	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("charm %q already uploaded", curl))
 	c.Assert(sch, gc.IsNil)
 	missingCurl := charm.MustParseURL("local:quantal/missing-1")
-	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, storagePath, "missing")
+	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, storagePath, "missing", 0)
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 	c.Assert(sch, gc.IsNil)
 
 	// Test with with an uploaded local charm.
 	_, err = s.State.PrepareLocalCharmUpload(missingCurl)
 	c.Assert(err, jc.ErrorIsNil)
-	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, storagePath, "missing")
+	sch, err = s.State.UpdateUploadedCharm(ch, missingCurl, storagePath, "missing", 1234)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(sch.URL(), gc.DeepEquals, missingCurl)
 	c.Assert(sch.Revision(), gc.Equals, missingCurl.Revision)
@@ -845,6 +845,7 @@ func (s *StateSuite) TestUpdateUploadedCharm(c *gc.C) {
 	c.Assert(sch.Config(), gc.DeepEquals, ch.Config())
 	c.Assert(sch.StoragePath(), gc.DeepEquals, storagePath)
 	c.Assert(sch.BundleSha256(), gc.Equals, "missing")
+	c.Assert(sch.BundleSize(), gc.Equals, int64(1234))
 }
 
 func (s *StateSuite) TestUpdateUploadedCharmEscapesSpecialCharsInConfig(c *gc.C) {
@@ -878,7 +879,7 @@ options:
 
 	preparedCurl, err := s.State.PrepareLocalCharmUpload(missingCurl)
 	c.Assert(err, jc.ErrorIsNil)
-	sch, err := s.State.UpdateUploadedCharm(ch, preparedCurl, storagePath, "missing")
+	sch, err := s.State.UpdateUploadedCharm(ch, preparedCurl, storagePath, "missing", 0)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(sch.URL(), gc.DeepEquals, missingCurl)
 	c.Assert(sch.Revision(), gc.Equals, missingCurl.Revision)
@@ -1898,13 +1899,29 @@ var inferEndpointsTests = []struct {
 			{"rk1", "rk1"},
 			{"rk1", "rk2"},
 		},
-		err: `no relations found`,
+		err: `no relations found: ".*" and ".*" only share interfaces they both provide or both require \(.*\)`,
+	}, {
+		summary: "interfaces shared in the same role are reported",
+		inputs:  [][]string{{"wp", "wp"}},
+		err:     `no relations found: "wp" and "wp" only share interfaces they both provide or both require \("http", "logging", "monitoring", "mysql", "varnish"\)`,
 	}, {
 		summary: "container scoped relation not possible when there's no subordinate",
 		inputs: [][]string{
 			{"lg-p", "wp"},
 		},
-		err: `no relations found`,
+		err: `no relations found: container-scoped relations need a subordinate service, and neither "lg-p" nor "wp" is subordinate`,
+	}, {
+		summary: "no common interface points at charms providing required interfaces",
+		inputs:  [][]string{{"wp:db", "rk1"}},
+		err:     `no relations found: "wp" and "rk1" have no relation interfaces in common; "wp" requires "mysql" \(provided by local:quantal/quantal-mysql-alternative-1\)`,
+	}, {
+		summary: "no peer relations",
+		inputs:  [][]string{{"wp"}},
+		err:     `no relations found: service "wp" has no peer relations`,
+	}, {
+		summary: "named relation is not a peer relation",
+		inputs:  [][]string{{"wp:db"}},
+		err:     `no relations found: "wp:db" is not a peer relation`,
 	}, {
 		summary: "container scoped relations between 2 subordinates is ok",
 		inputs:  [][]string{{"lg:logging-directory", "lg2:logging-client"}},
@@ -2088,6 +2105,18 @@ func (s *StateSuite) TestEnvironConfig(c *gc.C) {
 	c.Assert(oldCfg, gc.DeepEquals, cfg)
 }
 
+func (s *StateSuite) TestUpdateEnvironConfigImmutable(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"api-port": 42}, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot change api-port from \d+ to 42`)
+	c.Assert(config.IsImmutableConfigValueError(err), jc.IsTrue)
+}
+
+func (s *StateSuite) TestUpdateEnvironConfigInvalidValue(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"hook-output-limit": 0}, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `invalid config value for hook-output-limit: "0": must be positive`)
+	c.Assert(config.IsInvalidConfigValueError(err), jc.IsTrue)
+}
+
 func (s *StateSuite) TestEnvironConstraints(c *gc.C) {
 	// Environ constraints start out empty (for now).
 	cons, err := s.State.EnvironConstraints()
@@ -2117,6 +2146,16 @@ func (s *StateSuite) TestSetInvalidConstraints(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
 }
 
+func (s *StateSuite) TestValidateConstraints(c *gc.C) {
+	unsupported, err := s.State.ValidateConstraints(constraints.MustParse("mem=4G cpu-power=10"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unsupported, jc.DeepEquals, []string{"cpu-power"})
+
+	unsupported, err = s.State.ValidateConstraints(constraints.MustParse("mem=4G instance-type=foo cpu-power=10"))
+	c.Assert(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
+	c.Assert(unsupported, jc.DeepEquals, []string{"cpu-power"})
+}
+
 func (s *StateSuite) TestSetUnsupportedConstraintsWarning(c *gc.C) {
 	defer loggo.ResetWriters()
 	logger := loggo.GetLogger("test")
@@ -3370,6 +3409,16 @@ func (s *StateSuite) TestTopParentId(c *gc.C) {
 	c.Assert(state.TopParentId("0/lxc/1/kvm/2"), gc.Equals, "0")
 }
 
+func (s *StateSuite) TestIsContainedBy(c *gc.C) {
+	c.Assert(state.IsContainedBy("0", "0"), jc.IsFalse)
+	c.Assert(state.IsContainedBy("0/lxc/1", "0"), jc.IsTrue)
+	c.Assert(state.IsContainedBy("0/kvm/1/lxc/2", "0"), jc.IsTrue)
+	c.Assert(state.IsContainedBy("0/kvm/1/lxc/2", "0/kvm/1"), jc.IsTrue)
+	c.Assert(state.IsContainedBy("0/kvm/1", "0/kvm/1/lxc/2"), jc.IsFalse)
+	c.Assert(state.IsContainedBy("1/lxc/0", "0"), jc.IsFalse)
+	c.Assert(state.IsContainedBy("0/lxc/1", "0/lxc/0"), jc.IsFalse)
+}
+
 func (s *StateSuite) TestParentId(c *gc.C) {
 	c.Assert(state.ParentId("0"), gc.Equals, "")
 	c.Assert(state.ParentId("0/lxc/1"), gc.Equals, "0")
@@ -4216,6 +4265,55 @@ func (s *StateSuite) TestWatchAPIHostPorts(c *gc.C) {
 	wc.AssertClosed()
 }
 
+func (s *StateSuite) TestWatchAgentConfigSettings(c *gc.C) {
+	w := s.State.WatchAgentConfigSettings()
+	defer statetesting.AssertStop(c, w)
+
+	// Initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	// Changing the environment config is reported.
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"logging-config": "<root>=DEBUG",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// Stop, check closed.
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
+func (s *StateSuite) TestWatchStateServingInfo(c *gc.C) {
+	info := state.StateServingInfo{
+		APIPort:      69,
+		StatePort:    80,
+		Cert:         "Some cert",
+		PrivateKey:   "Some key",
+		SharedSecret: "Some Keyfile",
+	}
+	err := s.State.SetStateServingInfo(info)
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.State.WatchStateServingInfo()
+	defer statetesting.AssertStop(c, w)
+
+	// Initial event.
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	info.Cert = "Another cert"
+	info.PrivateKey = "Another key"
+	err = s.State.SetStateServingInfo(info)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	// Stop, check closed.
+	statetesting.AssertStop(c, w)
+	wc.AssertClosed()
+}
+
 func (s *StateSuite) TestWatchMachineAddresses(c *gc.C) {
 	// Add a machine: reported.
 	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
@@ -0,0 +1,121 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/replicaset"
+)
+
+// replicaSetMachineIdTag is the replica set member tag holding the id
+// of the machine hosting the member, as set by the peergrouper.
+const replicaSetMachineIdTag = "juju-machine-id"
+
+// StateServerHealth describes the health of a state server machine.
+type StateServerHealth struct {
+	MachineId string
+
+	// AgentAlive holds whether the machine agent is running.
+	AgentAlive bool
+
+	// WantsVote and HasVote hold whether the machine should have,
+	// and has, a vote in the mongo replica set.
+	WantsVote bool
+	HasVote   bool
+
+	// MongoState holds the state of the machine's mongo replica
+	// set member, or is empty if the machine is not a member.
+	MongoState string
+
+	// MongoHealthy holds whether the machine's mongo replica set
+	// member is up.
+	MongoHealthy bool
+}
+
+// Available reports whether the state server is able to take part
+// in running the environment: its agent must be running and its mongo
+// database, if it has joined the replica set, must be up.
+func (h StateServerHealth) Available() bool {
+	return h.AgentAlive && (h.MongoState == "" || h.MongoHealthy)
+}
+
+// StateServersHealth returns the health of all the state server
+// machines. If the status of the mongo replica set cannot be read,
+// the machines are reported as not being members.
+func (st *State) StateServersHealth() ([]StateServerHealth, error) {
+	info, err := st.StateServerInfo()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The health of the machines' agents is still worth
+	// reporting if the replica set status cannot be read.
+	members, err := st.mongoMembersByMachine()
+	if err != nil {
+		logger.Warningf("%v", err)
+	}
+	result := make([]StateServerHealth, len(info.MachineIds))
+	for i, id := range info.MachineIds {
+		m, err := st.Machine(id)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		alive, err := m.AgentPresence()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result[i] = StateServerHealth{
+			MachineId:  id,
+			AgentAlive: alive,
+			WantsVote:  m.WantsVote(),
+			HasVote:    m.HasVote(),
+		}
+		if member, ok := members[id]; ok {
+			result[i].MongoState = member.State.String()
+			result[i].MongoHealthy = member.Healthy
+		}
+	}
+	return result, nil
+}
+
+// mongoMembersByMachine returns the status of the mongo replica set
+// members, keyed by the id of the machine hosting each.
+func (st *State) mongoMembersByMachine() (map[string]replicaset.MemberStatus, error) {
+	session := st.MongoSession()
+	status, err := replicaset.CurrentStatus(session)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get replica set status")
+	}
+	members, err := replicaset.CurrentMembers(session)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get replica set members")
+	}
+	machineIds := make(map[int]string)
+	for _, member := range members {
+		if id, ok := member.Tags[replicaSetMachineIdTag]; ok {
+			machineIds[member.Id] = id
+		}
+	}
+	result := make(map[string]replicaset.MemberStatus)
+	for _, member := range status.Members {
+		if id, ok := machineIds[member.Id]; ok {
+			result[id] = member
+		}
+	}
+	return result, nil
+}
+
+// mongoMemberHealthy reports whether the mongo replica set member
+// hosted by the machine with the given id is up. A machine which has
+// not joined the replica set is considered healthy, as is every
+// machine if the replica set status cannot be read.
+func (st *State) mongoMemberHealthy(machineId string) bool {
+	members, err := st.mongoMembersByMachine()
+	if err != nil {
+		logger.Warningf("%v", err)
+		return true
+	}
+	member, ok := members[machineId]
+	return !ok || member.Healthy
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type StateServerHealthSuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&StateServerHealthSuite{})
+
+func (s *StateServerHealthSuite) TestStateServersHealth(c *gc.C) {
+	s.PatchValue(state.StateServerAvailable, func(m *state.Machine) (bool, error) {
+		return true, nil
+	})
+	m0, err := s.State.AddMachine("quantal", state.JobHostUnits, state.JobManageEnviron)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.EnsureAvailability(3, constraints.Value{}, "quantal", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	pinger, err := m0.SetAgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	defer pinger.Stop()
+	s.State.StartSync()
+	err = m0.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+
+	health, err := s.State.StateServersHealth()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(health, gc.HasLen, 3)
+	for i, h := range health {
+		c.Check(h.AgentAlive, gc.Equals, h.MachineId == m0.Id())
+		c.Check(h.Available(), gc.Equals, h.MachineId == m0.Id())
+		c.Check(h.WantsVote, jc.IsTrue, gc.Commentf("machine %d", i))
+	}
+}
+
+func (s *StateServerHealthSuite) TestAvailable(c *gc.C) {
+	for i, test := range []struct {
+		health    state.StateServerHealth
+		available bool
+	}{{
+		health:    state.StateServerHealth{AgentAlive: true},
+		available: true,
+	}, {
+		health:    state.StateServerHealth{AgentAlive: true, MongoState: "PRIMARY", MongoHealthy: true},
+		available: true,
+	}, {
+		health:    state.StateServerHealth{AgentAlive: true, MongoState: "DOWN"},
+		available: false,
+	}, {
+		health:    state.StateServerHealth{MongoState: "SECONDARY", MongoHealthy: true},
+		available: false,
+	}} {
+		c.Logf("test %d", i)
+		c.Check(test.health.Available(), gc.Equals, test.available)
+	}
+}
@@ -4,6 +4,8 @@
 package state
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -107,6 +109,25 @@ const (
 	StatusRunning Status = "running"
 )
 
+const (
+	// Status values recording the provisioning progress of a machine.
+
+	// An image has been chosen for the machine's instance.
+	StatusImageSelected Status = "image-selected"
+
+	// The provider has been asked to start the machine's instance.
+	StatusInstanceRequested Status = "instance-requested"
+
+	// The machine's instance has been started by the provider.
+	StatusInstanceRunning Status = "instance-running"
+
+	// The machine's instance is downloading the agent tools.
+	StatusToolsDownloading Status = "tools-downloading"
+
+	// The machine agent has started on the machine's instance.
+	StatusAgentStarted Status = "agent-started"
+)
+
 // ValidAgentStatus returns true if status has a known value for an agent.
 // This is used by the status command to filter out
 // unknown status values.
@@ -129,6 +150,22 @@ func (status Status) ValidAgentStatus() bool {
 	}
 }
 
+// ValidProvisioningStatus returns true if status has a known value
+// for the provisioning progress of a machine.
+func (status Status) ValidProvisioningStatus() bool {
+	switch status {
+	case
+		StatusImageSelected,
+		StatusInstanceRequested,
+		StatusInstanceRunning,
+		StatusToolsDownloading,
+		StatusAgentStarted:
+		return true
+	default:
+		return false
+	}
+}
+
 // Matches returns true if the candidate matches status,
 // taking into account that the candidate may be a legacy
 // status value which has been deprecated.
@@ -327,8 +364,56 @@ func (doc *unitStatusDoc) validateSet() error {
 			return errors.Errorf("cannot set status %q without info", doc.Status)
 		}
 	}
-	if doc.StatusData != nil && doc.Status != StatusError {
-		return errors.Errorf("cannot set status data when status is %q", doc.Status)
+	// Unlike agents, units may attach data to any status, so
+	// that charms can report structured detail about the
+	// health of their workload.
+	return validateStatusData(doc.StatusData)
+}
+
+// maxStatusDataSize is the maximum size, in bytes, of the BSON
+// encoding of the data attached to a unit's status.
+const maxStatusDataSize = 16 * 1024
+
+// validateStatusData returns an error if the given status data
+// cannot be stored, either because a key is not acceptable to
+// MongoDB or because the data is too large.
+func validateStatusData(data map[string]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := validateStatusDataKeys(data); err != nil {
+		return errors.Trace(err)
+	}
+	encoded, err := bson.Marshal(data)
+	if err != nil {
+		return errors.Annotate(err, "invalid status data")
+	}
+	if len(encoded) > maxStatusDataSize {
+		return errors.Errorf("status data exceeds maximum size of %d bytes", maxStatusDataSize)
+	}
+	return nil
+}
+
+func validateStatusDataKeys(value interface{}) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			switch {
+			case key == "":
+				return errors.New("status data keys must not be empty")
+			case strings.HasPrefix(key, "$"), strings.Contains(key, "."):
+				return errors.Errorf("invalid status data key %q", key)
+			}
+			if err := validateStatusDataKeys(v); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, v := range value {
+			if err := validateStatusDataKeys(v); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
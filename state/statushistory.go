@@ -0,0 +1,194 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxStatusHistoryEntries is the number of status history entries
+// retained for each entity; older entries are pruned as new ones are
+// recorded.
+var maxStatusHistoryEntries = 100
+
+// historicalStatusDoc records a status previously held by an entity.
+type historicalStatusDoc struct {
+	DocId      string                 `bson:"_id"`
+	EnvUUID    string                 `bson:"env-uuid"`
+	EntityId   string                 `bson:"entityid"`
+	Status     Status                 `bson:"status"`
+	StatusInfo string                 `bson:"statusinfo"`
+	StatusData map[string]interface{} `bson:"statusdata"`
+	Updated    time.Time              `bson:"updated"`
+}
+
+// StatusInfo holds a status held by an entity at some point in time.
+type StatusInfo struct {
+	Status Status
+	Info   string
+	Data   map[string]interface{}
+	Since  time.Time
+}
+
+// StatusHistoryFilter restricts the status history returned for an
+// entity.
+type StatusHistoryFilter struct {
+	// From and To, if non-zero, restrict the history to entries
+	// recorded within the given time range, inclusive.
+	From time.Time
+	To   time.Time
+
+	// Size, if positive, limits the history to the most recent
+	// Size entries.
+	Size int
+
+	// Changes, if true, collapses consecutive entries with the
+	// same status into the earliest of them, leaving one entry
+	// per change of status.
+	Changes bool
+}
+
+// Validate returns an error if the filter is not sane.
+func (f StatusHistoryFilter) Validate() error {
+	if f.Size < 0 {
+		return errors.NotValidf("negative history size %d", f.Size)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return errors.NotValidf("time range ending before it starts")
+	}
+	return nil
+}
+
+// recordStatusHistory records the status now held by the entity with
+// the given global key. History is kept on a best effort basis, so
+// failures are logged rather than returned.
+func recordStatusHistory(st *State, globalKey string, doc statusDoc) {
+	history, closer := st.getCollection(statusesHistoryC)
+	defer closer()
+
+	err := history.Insert(&historicalStatusDoc{
+		DocId:      st.docID(bson.NewObjectId().Hex()),
+		EnvUUID:    st.EnvironUUID(),
+		EntityId:   globalKey,
+		Status:     doc.Status,
+		StatusInfo: doc.StatusInfo,
+		StatusData: doc.StatusData,
+		Updated:    time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Warningf("cannot record status history for %q: %v", globalKey, err)
+		return
+	}
+	if err := pruneStatusHistory(history, globalKey); err != nil {
+		logger.Warningf("cannot prune status history for %q: %v", globalKey, err)
+	}
+}
+
+// pruneStatusHistory removes all but the most recent
+// maxStatusHistoryEntries entries for the given global key.
+func pruneStatusHistory(history stateCollection, globalKey string) error {
+	var oldest historicalStatusDoc
+	err := history.Find(bson.D{{"entityid", globalKey}}).
+		Sort("-updated", "-_id").
+		Skip(maxStatusHistoryEntries - 1).
+		One(&oldest)
+	if err == mgo.ErrNotFound {
+		// Fewer entries than the limit.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	// Entries recorded within the same millisecond are ordered by
+	// their ids, which increase over time.
+	_, err = history.RemoveAll(bson.D{
+		{"entityid", globalKey},
+		{"$or", []bson.D{
+			{{"updated", bson.D{{"$lt", oldest.Updated}}}},
+			{{"updated", oldest.Updated}, {"_id", bson.D{{"$lt", oldest.DocId}}}},
+		}},
+	})
+	return errors.Trace(err)
+}
+
+// statusHistory returns the status history of the entity with the
+// given global key, most recent first, as restricted by filter.
+func statusHistory(st *State, globalKey string, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	history, closer := st.getCollection(statusesHistoryC)
+	defer closer()
+
+	sel := bson.D{{"entityid", globalKey}}
+	updated := bson.D{}
+	if !filter.From.IsZero() {
+		updated = append(updated, bson.DocElem{"$gte", filter.From.UTC()})
+	}
+	if !filter.To.IsZero() {
+		updated = append(updated, bson.DocElem{"$lte", filter.To.UTC()})
+	}
+	if len(updated) > 0 {
+		sel = append(sel, bson.DocElem{"updated", updated})
+	}
+	var docs []historicalStatusDoc
+	// Changes are found by walking the history oldest first.
+	if err := history.Find(sel).Sort("updated", "_id").All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get status history for %q", globalKey)
+	}
+	var results []StatusInfo
+	for _, doc := range docs {
+		if filter.Changes && len(results) > 0 && results[len(results)-1].Status == doc.Status {
+			continue
+		}
+		results = append(results, StatusInfo{
+			Status: doc.Status,
+			Info:   doc.StatusInfo,
+			Data:   doc.StatusData,
+			Since:  doc.Updated,
+		})
+	}
+	// Reverse so the most recent entries come first.
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	if filter.Size > 0 && len(results) > filter.Size {
+		results = results[:filter.Size]
+	}
+	return results, nil
+}
+
+// StatusHistory returns the history of the machine's status, most
+// recent first.
+func (m *Machine) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(m.st, m.globalKey(), filter)
+}
+
+// ProvisioningStatusHistory returns the history of the machine's
+// provisioning progress, most recent first.
+func (m *Machine) ProvisioningStatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(m.st, m.provisioningGlobalKey(), filter)
+}
+
+// StatusHistory returns the history of the unit's workload status,
+// most recent first.
+func (u *Unit) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(u.st, u.globalKey(), filter)
+}
+
+// StatusHistory returns the history of the unit agent's status, most
+// recent first.
+func (u *UnitAgent) StatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return statusHistory(u.st, u.globalKey(), filter)
+}
+
+// AgentStatusHistory returns the history of the unit's agent status,
+// most recent first.
+func (u *Unit) AgentStatusHistory(filter StatusHistoryFilter) ([]StatusInfo, error) {
+	agent := newUnitAgent(u.st, u.Tag(), u.Name())
+	return agent.StatusHistory(filter)
+}
@@ -0,0 +1,157 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type StatusHistorySuite struct {
+	ConnSuite
+}
+
+var _ = gc.Suite(&StatusHistorySuite{})
+
+func statuses(history []state.StatusInfo) []state.Status {
+	result := make([]state.Status, len(history))
+	for i, entry := range history {
+		result[i] = entry.Status
+	}
+	return result
+}
+
+func (s *StatusHistorySuite) TestMachineStatusHistory(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetStatus(state.StatusError, "oops", map[string]interface{}{"foo": "bar"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := machine.StatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Assert(history[0].Status, gc.Equals, state.StatusError)
+	c.Assert(history[0].Info, gc.Equals, "oops")
+	c.Assert(history[0].Data, jc.DeepEquals, map[string]interface{}{"foo": "bar"})
+	c.Assert(history[1].Status, gc.Equals, state.StatusStarted)
+	c.Assert(history[0].Since.Before(history[1].Since), jc.IsFalse)
+}
+
+func (s *StatusHistorySuite) TestMachineProvisioningStatusHistory(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioningStatus(state.StatusInstanceRequested, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioningStatus(state.StatusInstanceRunning, "i-blah", map[string]interface{}{"foo": "bar"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := machine.ProvisioningStatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{
+		state.StatusAgentStarted,
+		state.StatusInstanceRunning,
+		state.StatusInstanceRequested,
+	})
+	c.Assert(history[1].Info, gc.Equals, "i-blah")
+	c.Assert(history[1].Data, jc.DeepEquals, map[string]interface{}{"foo": "bar"})
+
+	// The machine's own status history is kept separately.
+	history, err = machine.StatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{state.StatusStarted})
+}
+
+func (s *StatusHistorySuite) TestSetProvisioningStatusInvalid(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetProvisioningStatus(state.StatusStarted, "", nil)
+	c.Assert(err, gc.ErrorMatches, `cannot set invalid provisioning status "started"`)
+}
+
+func (s *StatusHistorySuite) TestUnitStatusHistory(c *gc.C) {
+	unit := s.factory.MakeUnit(c, nil)
+	for _, status := range []state.Status{state.StatusBusy, state.StatusBusy, state.StatusRunning} {
+		err := unit.SetStatus(status, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := unit.StatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{
+		state.StatusRunning, state.StatusBusy, state.StatusBusy,
+	})
+
+	history, err = unit.StatusHistory(state.StatusHistoryFilter{Changes: true})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{
+		state.StatusRunning, state.StatusBusy,
+	})
+
+	history, err = unit.StatusHistory(state.StatusHistoryFilter{Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{state.StatusRunning})
+
+	history, err = unit.AgentStatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{state.StatusActive})
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryTimeRange(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	now := time.Now()
+	history, err := machine.StatusHistory(state.StatusHistoryFilter{
+		From: now.Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 0)
+
+	history, err = machine.StatusHistory(state.StatusHistoryFilter{
+		From: now.Add(-time.Hour),
+		To:   now.Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(statuses(history), jc.DeepEquals, []state.Status{state.StatusStarted})
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryInvalidFilter(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = machine.StatusHistory(state.StatusHistoryFilter{Size: -1})
+	c.Assert(err, gc.ErrorMatches, "negative history size -1 not valid")
+
+	now := time.Now()
+	_, err = machine.StatusHistory(state.StatusHistoryFilter{
+		From: now,
+		To:   now.Add(-time.Hour),
+	})
+	c.Assert(err, gc.ErrorMatches, "time range ending before it starts not valid")
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryPruned(c *gc.C) {
+	s.PatchValue(state.MaxStatusHistoryEntries, 3)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 5; i++ {
+		err = machine.SetStatus(state.StatusStarted, "", nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	history, err := machine.StatusHistory(state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+}
@@ -416,6 +416,61 @@ func (st *State) storageAttachment(storage names.StorageTag, unit names.UnitTag)
 	return &s, nil
 }
 
+// AddStorageForUnit creates cons.Count new instances of the named charm
+// storage for the unit, and attaches them to the unit. Only the count
+// is taken from the constraints; the storage instances are provisioned
+// according to the service's storage constraints. Shared storage
+// cannot be added to a unit.
+func (st *State) AddStorageForUnit(tag names.UnitTag, name string, cons StorageConstraints) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot add storage %q to unit %q", name, tag.Id())
+	if cons.Count == 0 {
+		return errors.New("storage count must be greater than zero")
+	}
+	u, err := st.Unit(tag.Id())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	svc, err := u.Service()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ch, _, err := svc.Charm()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	charmStorage, ok := ch.Meta().Storage[name]
+	if !ok {
+		return errors.NotFoundf("charm storage %q", name)
+	}
+	if charmStorage.Shared {
+		return errors.NotSupportedf("adding shared storage to a unit")
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if u.Life() != Alive {
+			return nil, unitNotAliveErr
+		}
+		ops, numStorageAttachments, err := createStorageOps(
+			st, tag, ch.Meta(), map[string]StorageConstraints{name: cons},
+		)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ops = append(ops, txn.Op{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: isAliveDoc,
+			Update: bson.D{{"$inc", bson.D{{"storageattachmentcount", numStorageAttachments}}}},
+		})
+		return ops, nil
+	}
+	return st.run(buildTxn)
+}
+
 // DestroyStorageAttachment ensures that the storage attachment will be
 // removed at some point.
 func (st *State) DestroyStorageAttachment(storage names.StorageTag, unit names.UnitTag) (err error) {
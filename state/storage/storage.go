@@ -7,14 +7,20 @@ import (
 	"io"
 
 	"github.com/juju/blobstore"
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"gopkg.in/mgo.v2"
+
+	jujublobstore "github.com/juju/juju/state/blobstore"
 )
 
+var logger = loggo.GetLogger("juju.state.storage")
+
 const (
 	// metadataDB is the name of the blobstore metadata database.
 	metadataDB = "juju"
 
-	// blobstoreDB is the name of the blobstore GridFS database.
+	// blobstoreDB is the name of the legacy blobstore GridFS database.
 	blobstoreDB = "blobstore"
 )
 
@@ -37,16 +43,27 @@ type Storage interface {
 }
 
 // Storage returns a Storage for the environment with the specified UUID.
+//
+// Content is written to the content-addressable store provided by the
+// state/blobstore package. Content written before it existed is still
+// read from, and removed from, the environment's managed storage.
 func NewStorage(envUUID string, session *mgo.Session) Storage {
-	return stateStorage{envUUID, session}
+	return stateStorage{
+		envUUID: envUUID,
+		session: session,
+		store:   jujublobstore.NewStorage(envUUID, session),
+	}
 }
 
 type stateStorage struct {
 	envUUID string
 	session *mgo.Session
+	store   jujublobstore.Storage
 }
 
-func (s stateStorage) blobstore() (*mgo.Session, blobstore.ManagedStorage) {
+// legacyBlobstore returns the managed storage used before the
+// content-addressable store.
+func (s stateStorage) legacyBlobstore() (*mgo.Session, blobstore.ManagedStorage) {
 	session := s.session.Copy()
 	rs := blobstore.NewGridFS(blobstoreDB, s.envUUID, session)
 	db := session.DB(metadataDB)
@@ -54,7 +71,13 @@ func (s stateStorage) blobstore() (*mgo.Session, blobstore.ManagedStorage) {
 }
 
 func (s stateStorage) Get(path string) (r io.ReadCloser, length int64, err error) {
-	session, ms := s.blobstore()
+	r, metadata, err := s.store.Get(path)
+	if err == nil {
+		return r, metadata.Size, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, -1, err
+	}
+	session, ms := s.legacyBlobstore()
 	r, length, err = ms.GetForEnvironment(s.envUUID, path)
 	if err != nil {
 		session.Close()
@@ -64,13 +87,31 @@ func (s stateStorage) Get(path string) (r io.ReadCloser, length int64, err error
 }
 
 func (s stateStorage) Put(path string, r io.Reader, length int64) error {
-	session, ms := s.blobstore()
-	defer session.Close()
-	return ms.PutForEnvironment(s.envUUID, path, r, length)
+	if _, err := s.store.Put(path, r, length); err != nil {
+		return err
+	}
+	// Remove any content stored at the path before the
+	// content-addressable store existed, so it is not leaked.
+	if err := s.removeLegacy(path); err != nil && !errors.IsNotFound(err) {
+		logger.Errorf("cannot remove legacy content at path %q: %v", path, err)
+	}
+	return nil
 }
 
 func (s stateStorage) Remove(path string) error {
-	session, ms := s.blobstore()
+	err := s.store.Remove(path)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	legacyErr := s.removeLegacy(path)
+	if err == nil && errors.IsNotFound(legacyErr) {
+		return nil
+	}
+	return legacyErr
+}
+
+func (s stateStorage) removeLegacy(path string) error {
+	session, ms := s.legacyBlobstore()
 	defer session.Close()
 	return ms.RemoveForEnvironment(s.envUUID, path)
 }
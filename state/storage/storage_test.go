@@ -13,6 +13,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	jujublobstore "github.com/juju/juju/state/blobstore"
 	"github.com/juju/juju/state/storage"
 	"github.com/juju/juju/testing"
 )
@@ -23,6 +24,7 @@ type StorageSuite struct {
 	gitjujutesting.MgoSuite
 	testing.BaseSuite
 	managedStorage blobstore.ManagedStorage
+	blobStorage    jujublobstore.Storage
 	storage        storage.Storage
 }
 
@@ -45,6 +47,7 @@ func (s *StorageSuite) SetUpTest(c *gc.C) {
 	rs := blobstore.NewGridFS("blobstore", testUUID, s.Session)
 	db := s.Session.DB("juju")
 	s.managedStorage = blobstore.NewManagedStorage(db, rs)
+	s.blobStorage = jujublobstore.NewStorage(testUUID, s.Session)
 	s.storage = storage.NewStorage(testUUID, s.Session)
 }
 
@@ -54,33 +57,54 @@ func (s *StorageSuite) TearDownTest(c *gc.C) {
 }
 
 func (s *StorageSuite) TestStorageGet(c *gc.C) {
+	_, err := s.blobStorage.Put("abc", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContent(c, "abc", "abc")
+}
+
+func (s *StorageSuite) TestStorageGetLegacy(c *gc.C) {
 	err := s.managedStorage.PutForEnvironment(testUUID, "abc", strings.NewReader("abc"), 3)
 	c.Assert(err, jc.ErrorIsNil)
+	s.assertContent(c, "abc", "abc")
+}
 
-	r, length, err := s.storage.Get("abc")
+func (s *StorageSuite) assertContent(c *gc.C, path, content string) {
+	r, length, err := s.storage.Get(path)
 	c.Assert(err, jc.ErrorIsNil)
 	defer r.Close()
-	c.Assert(length, gc.Equals, int64(3))
+	c.Assert(length, gc.Equals, int64(len(content)))
 
 	data, err := ioutil.ReadAll(r)
 	c.Assert(err, jc.ErrorIsNil)
-	c.Assert(string(data), gc.Equals, "abc")
+	c.Assert(string(data), gc.Equals, content)
 }
 
 func (s *StorageSuite) TestStoragePut(c *gc.C) {
 	err := s.storage.Put("path", strings.NewReader("abcdef"), 3)
 	c.Assert(err, jc.ErrorIsNil)
 
-	r, length, err := s.managedStorage.GetForEnvironment(testUUID, "path")
+	r, metadata, err := s.blobStorage.Get("path")
 	c.Assert(err, jc.ErrorIsNil)
 	defer r.Close()
 
-	c.Assert(length, gc.Equals, int64(3))
+	c.Assert(metadata.Size, gc.Equals, int64(3))
 	data, err := ioutil.ReadAll(r)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(string(data), gc.Equals, "abc")
 }
 
+func (s *StorageSuite) TestStoragePutReplacesLegacy(c *gc.C) {
+	err := s.managedStorage.PutForEnvironment(testUUID, "path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.Put("path", strings.NewReader("defghi"), 6)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertContent(c, "path", "defghi")
+
+	_, _, err = s.managedStorage.GetForEnvironment(testUUID, "path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 func (s *StorageSuite) TestStorageRemove(c *gc.C) {
 	err := s.storage.Put("path", strings.NewReader("abcdef"), 3)
 	c.Assert(err, jc.ErrorIsNil)
@@ -94,3 +118,14 @@ func (s *StorageSuite) TestStorageRemove(c *gc.C) {
 	err = s.storage.Remove("path")
 	c.Assert(err, jc.Satisfies, errors.IsNotFound)
 }
+
+func (s *StorageSuite) TestStorageRemoveLegacy(c *gc.C) {
+	err := s.managedStorage.PutForEnvironment(testUUID, "path", strings.NewReader("abc"), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.storage.Remove("path")
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = s.storage.Get("path")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
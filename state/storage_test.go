@@ -225,6 +225,42 @@ func (s *StorageStateSuite) TestAddUnit(c *gc.C) {
 	}
 }
 
+func (s *StorageStateSuite) TestAddStorageForUnit(c *gc.C) {
+	_, u, _ := s.setupSingleStorage(c, "block")
+	err := s.State.AddStorageForUnit(u.UnitTag(), "data", makeStorageCons("", 0, 2))
+	c.Assert(err, jc.ErrorIsNil)
+
+	storageAttachments, err := s.State.StorageAttachments(u.UnitTag())
+	c.Assert(err, jc.ErrorIsNil)
+	storageIds := make([]string, len(storageAttachments))
+	for i, att := range storageAttachments {
+		storageIds[i] = att.StorageInstance().Id()
+	}
+	c.Assert(storageIds, jc.SameContents, []string{"data/0", "data/1", "data/2"})
+
+	// The unit cannot be marked Dead until the new attachments
+	// are removed too.
+	err = u.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = u.EnsureDead()
+	c.Assert(err, gc.ErrorMatches, "unit has storage attachments")
+}
+
+func (s *StorageStateSuite) TestAddStorageForUnitErrors(c *gc.C) {
+	_, u, _ := s.setupSingleStorage(c, "block")
+	err := s.State.AddStorageForUnit(u.UnitTag(), "data", makeStorageCons("", 0, 0))
+	c.Assert(err, gc.ErrorMatches, `cannot add storage "data" to unit "storage-block/0": storage count must be greater than zero`)
+	err = s.State.AddStorageForUnit(u.UnitTag(), "nope", makeStorageCons("", 0, 1))
+	c.Assert(err, gc.ErrorMatches, `cannot add storage "nope" to unit "storage-block/0": charm storage "nope" not found`)
+	err = s.State.AddStorageForUnit(names.NewUnitTag("storage-block/1"), "data", makeStorageCons("", 0, 1))
+	c.Assert(err, gc.ErrorMatches, `cannot add storage "data" to unit "storage-block/1": unit "storage-block/1" not found`)
+
+	err = u.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.AddStorageForUnit(u.UnitTag(), "data", makeStorageCons("", 0, 1))
+	c.Assert(err, gc.ErrorMatches, `cannot add storage "data" to unit "storage-block/0": unit is not alive`)
+}
+
 func (s *StorageStateSuite) TestUnitEnsureDead(c *gc.C) {
 	_, u, storageTag := s.setupSingleStorage(c, "block")
 	// destroying a unit with storage attachments is fine; this is what
@@ -6,6 +6,7 @@ package state
 import (
 	"fmt"
 	"reflect"
+	"sync/atomic"
 
 	jujutxn "github.com/juju/txn"
 	"github.com/juju/utils/set"
@@ -75,6 +76,28 @@ func (st *State) ResumeTransactions() error {
 	return st.txnRunner(session).ResumeTransactions()
 }
 
+// TxnStats holds counts of the transactions run through State
+// since the process started.
+type TxnStats struct {
+	// Attempts holds the number of transactions submitted,
+	// including retries.
+	Attempts int64
+
+	// Aborted holds the number of transactions aborted because
+	// their assertions failed.
+	Aborted int64
+}
+
+var txnStats TxnStats
+
+// CurrentTxnStats returns the current transaction counts.
+func CurrentTxnStats() TxnStats {
+	return TxnStats{
+		Attempts: atomic.LoadInt64(&txnStats.Attempts),
+		Aborted:  atomic.LoadInt64(&txnStats.Aborted),
+	}
+}
+
 func newMultiEnvRunner(envUUID string, db *mgo.Database, assertEnvAlive bool) jujutxn.Runner {
 	return &multiEnvRunner{
 		rawRunner:      jujutxn.NewRunner(jujutxn.RunnerParams{Database: db}),
@@ -94,7 +117,12 @@ type multiEnvRunner struct {
 // to ensure correct interaction with these collections.
 func (r *multiEnvRunner) RunTransaction(ops []txn.Op) error {
 	ops = r.updateOps(ops)
-	return r.rawRunner.RunTransaction(ops)
+	atomic.AddInt64(&txnStats.Attempts, 1)
+	err := r.rawRunner.RunTransaction(ops)
+	if err == txn.ErrAborted {
+		atomic.AddInt64(&txnStats.Aborted, 1)
+	}
+	return err
 }
 
 // Run is part of the jujutxn.Run interface. Operations returned by
@@ -102,7 +130,14 @@ func (r *multiEnvRunner) RunTransaction(ops []txn.Op) error {
 // collections will be modified in-place to ensure correct interaction
 // with these collections.
 func (r *multiEnvRunner) Run(transactions jujutxn.TransactionSource) error {
-	return r.rawRunner.Run(func(attempt int) ([]txn.Op, error) {
+	// The source is only called again after submitting
+	// operations when they were aborted.
+	submitted := false
+	err := r.rawRunner.Run(func(attempt int) ([]txn.Op, error) {
+		if submitted {
+			atomic.AddInt64(&txnStats.Aborted, 1)
+			submitted = false
+		}
 		ops, err := transactions(attempt)
 		if err != nil {
 			// Don't use Trace here as jujutxn doens't use juju/errors
@@ -110,8 +145,14 @@ func (r *multiEnvRunner) Run(transactions jujutxn.TransactionSource) error {
 			return nil, err
 		}
 		ops = r.updateOps(ops)
+		atomic.AddInt64(&txnStats.Attempts, 1)
+		submitted = true
 		return ops, nil
 	})
+	if submitted && err == jujutxn.ErrExcessiveContention {
+		atomic.AddInt64(&txnStats.Aborted, 1)
+	}
+	return err
 }
 
 // Run is part of the jujutxn.Run interface.
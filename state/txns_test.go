@@ -368,6 +368,19 @@ func (s *MultiEnvRunnerSuite) TestResumeTransactionsWithError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "boom")
 }
 
+func (s *MultiEnvRunnerSuite) TestTxnStats(c *gc.C) {
+	before := CurrentTxnStats()
+	err := s.multiEnvRunner.RunTransaction(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.testRunner.runTransactionErr = txn.ErrAborted
+	err = s.multiEnvRunner.RunTransaction(nil)
+	c.Assert(err, gc.Equals, txn.ErrAborted)
+
+	after := CurrentTxnStats()
+	c.Check(after.Attempts-before.Attempts, gc.Equals, int64(2))
+	c.Check(after.Aborted-before.Aborted, gc.Equals, int64(1))
+}
+
 // recordingRunner is fake transaction running that implements the
 // jujutxn.Runner interface. Instead of doing anything with a database
 // it simply records the transaction operations passed to it for later
@@ -378,13 +391,14 @@ func (s *MultiEnvRunnerSuite) TestResumeTransactionsWithError(c *gc.C) {
 // fresh instance should be created for each test.
 type recordingRunner struct {
 	seenOps                  []txn.Op
+	runTransactionErr        error
 	resumeTransactionsCalled bool
 	resumeTransactionsErr    error
 }
 
 func (r *recordingRunner) RunTransaction(ops []txn.Op) error {
 	r.seenOps = ops
-	return nil
+	return r.runTransactionErr
 }
 
 func (r *recordingRunner) Run(transactions jujutxn.TransactionSource) (err error) {
@@ -98,6 +98,7 @@ type unitDoc struct {
 	Life                   Life
 	TxnRevno               int64 `bson:"txn-revno"`
 	PasswordHash           string
+	WorkloadVersion        string `bson:"workloadversion,omitempty"`
 
 	// TODO(mue) No longer actively used, only in upgrades.go.
 	// To be removed later.
@@ -221,6 +222,29 @@ func (u *Unit) SetAgentVersion(v version.Binary) (err error) {
 	return nil
 }
 
+// WorkloadVersion returns the version of the software run by the
+// unit, as last reported by its charm, or "" if none was reported.
+func (u *Unit) WorkloadVersion() string {
+	return u.doc.WorkloadVersion
+}
+
+// SetWorkloadVersion records the version of the software run by the
+// unit.
+func (u *Unit) SetWorkloadVersion(version string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set workload version for unit %q", u)
+	ops := []txn.Op{{
+		C:      unitsC,
+		Id:     u.doc.DocID,
+		Assert: notDeadDoc,
+		Update: bson.D{{"$set", bson.D{{"workloadversion", version}}}},
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return onAbort(err, ErrDead)
+	}
+	u.doc.WorkloadVersion = version
+	return nil
+}
+
 // SetPassword sets the password for the machine's agent.
 func (u *Unit) SetPassword(password string) error {
 	if len(password) < utils.MinAgentPasswordLength {
@@ -309,6 +333,8 @@ func (u *Unit) Destroy() (err error) {
 	}
 	if err = unit.st.run(buildTxn); err == nil {
 		if err = unit.Refresh(); errors.IsNotFound(err) {
+			// The unit was removed outright.
+			refreshServiceStatus(u.st, u.doc.Service)
 			return nil
 		}
 	}
@@ -619,7 +645,11 @@ func (u *Unit) Remove() (err error) {
 		}
 		return nil, jujutxn.ErrNoOperations
 	}
-	return unit.st.run(buildTxn)
+	if err := unit.st.run(buildTxn); err != nil {
+		return err
+	}
+	refreshServiceStatus(u.st, u.doc.Service)
+	return nil
 }
 
 // Resolved returns the resolved mode for the unit.
@@ -823,7 +853,8 @@ func (u *Unit) SetStatus(status Status, info string, data map[string]interface{}
 	if err != nil {
 		return fmt.Errorf("cannot set status of unit %q: %v", u, onAbort(err, ErrDead))
 	}
-
+	recordStatusHistory(u.st, u.globalKey(), doc.statusDoc)
+	refreshServiceStatus(u.st, u.doc.Service)
 	return nil
 }
 
@@ -967,6 +998,10 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		charmIncOps, err := charmIncRefOps(u.st, curl)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 
 		// Set the new charm URL.
 		differentCharm := bson.D{{"charmurl", bson.D{{"$ne", curl}}}}
@@ -978,6 +1013,7 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 				Assert: append(notDeadDoc, differentCharm...),
 				Update: bson.D{{"$set", bson.D{{"charmurl", curl}}}},
 			}}
+		ops = append(ops, charmIncOps...)
 		if u.doc.CharmURL != nil {
 			// Drop the reference to the old charm.
 			decOps, err := settingsDecRefOps(u.st, u.doc.Service, u.doc.CharmURL)
@@ -985,6 +1021,7 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 				return nil, errors.Trace(err)
 			}
 			ops = append(ops, decOps...)
+			ops = append(ops, charmDecRefOp(u.st, u.doc.CharmURL))
 		}
 		return ops, nil
 	}
@@ -997,7 +1034,14 @@ func (u *Unit) SetCharmURL(curl *charm.URL) error {
 
 // AgentPresence returns whether the respective remote agent is alive.
 func (u *Unit) AgentPresence() (bool, error) {
-	return u.st.pwatcher.Alive(u.globalAgentKey())
+	return u.st.agentPresence(u.Tag(), u.globalAgentKey())
+}
+
+// AgentLastSeen returns when the unit's agent was last heard from by
+// an API server. It returns a NotFound error if no API server has
+// recorded the agent.
+func (u *Unit) AgentLastSeen() (time.Time, error) {
+	return u.st.agentLastSeen(u.Tag())
 }
 
 // Tag returns a name identifying the unit.
@@ -1016,26 +1060,13 @@ func (u *Unit) UnitTag() names.UnitTag {
 // WaitAgentPresence blocks until the respective agent is alive.
 func (u *Unit) WaitAgentPresence(timeout time.Duration) (err error) {
 	defer errors.DeferredAnnotatef(&err, "waiting for agent of unit %q", u)
-	ch := make(chan presence.Change)
-	u.st.pwatcher.Watch(u.globalAgentKey(), ch)
-	defer u.st.pwatcher.Unwatch(u.globalAgentKey(), ch)
-	for i := 0; i < 2; i++ {
-		select {
-		case change := <-ch:
-			if change.Alive {
-				return nil
-			}
-		case <-time.After(timeout):
-			return fmt.Errorf("still not alive after timeout")
-		case <-u.st.pwatcher.Dead():
-			return u.st.pwatcher.Err()
-		}
-	}
-	panic(fmt.Sprintf("presence reported dead status twice in a row for unit %q", u))
+	return u.st.waitAgentPresence(u.Tag(), u.globalAgentKey(), timeout)
 }
 
 // SetAgentPresence signals that the agent for unit u is alive.
-// It returns the started pinger.
+// It returns the started pinger. API servers record the liveness of
+// the agents connected to them with heartbeats instead; pingers
+// remain for agents served by older API servers.
 func (u *Unit) SetAgentPresence() (*presence.Pinger, error) {
 	presenceCollection := u.st.getPresence()
 	p := presence.NewPinger(presenceCollection, u.st.EnvironTag(), u.globalAgentKey())
@@ -1606,9 +1637,18 @@ func (u *Unit) findCleanMachineQuery(requireEmpty bool, cons *constraints.Value)
 	if cons.CpuPower != nil && *cons.CpuPower > 0 {
 		suitableTerms = append(suitableTerms, bson.DocElem{"cpupower", bson.D{{"$gte", *cons.CpuPower}}})
 	}
+	if cons.Gpus != nil && *cons.Gpus > 0 {
+		suitableTerms = append(suitableTerms, bson.DocElem{"gpus", bson.D{{"$gte", *cons.Gpus}}})
+	}
+	if cons.GpuType != nil && *cons.GpuType != "" {
+		suitableTerms = append(suitableTerms, bson.DocElem{"gputype", *cons.GpuType})
+	}
 	if cons.Tags != nil && len(*cons.Tags) > 0 {
 		suitableTerms = append(suitableTerms, bson.DocElem{"tags", bson.D{{"$all", *cons.Tags}}})
 	}
+	if cons.HaveZones() {
+		suitableTerms = append(suitableTerms, bson.DocElem{"availzone", bson.D{{"$in", *cons.Zones}}})
+	}
 	if len(suitableTerms) > 0 {
 		instanceData := db.C(instanceDataC)
 		err := instanceData.Find(suitableTerms).Select(bson.M{"_id": 1}).All(&suitableInstanceData)
@@ -1767,27 +1807,49 @@ type ActionSpecsByName map[string]charm.ActionSpec
 // AddAction adds a new Action of type name and using arguments payload to
 // this Unit, and returns its ID.
 func (u *Unit) AddAction(name string, payload map[string]interface{}) (*Action, error) {
+	if err := u.prepareActionParams(name, payload); err != nil {
+		return nil, err
+	}
+	return u.st.EnqueueAction(u.Tag(), name, payload)
+}
+
+// ScheduleAction schedules an Action of type name and using arguments
+// payload to be added to this Unit once at the given time or, if
+// cronExpr is not empty, repeatedly as the cron expression dictates.
+func (u *Unit) ScheduleAction(name string, payload map[string]interface{}, at time.Time, cronExpr string) (*ActionSchedule, error) {
+	if err := u.prepareActionParams(name, payload); err != nil {
+		return nil, err
+	}
+	return u.st.ScheduleAction(ActionScheduleParams{
+		Receiver:   u.Tag(),
+		Name:       name,
+		Parameters: payload,
+		At:         at,
+		Cron:       cronExpr,
+	})
+}
+
+// prepareActionParams checks that the named action is defined by the
+// unit's charm and that payload is valid for it, and fills in any
+// default values missing from payload.
+func (u *Unit) prepareActionParams(name string, payload map[string]interface{}) error {
 	if len(name) == 0 {
-		return nil, errors.New("no action name given")
+		return errors.New("no action name given")
 	}
 	specs, err := u.ActionSpecs()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	spec, ok := specs[name]
 	if !ok {
-		return nil, errors.Errorf("action %q not defined on unit %q", name, u.Name())
+		return errors.Errorf("action %q not defined on unit %q", name, u.Name())
 	}
 	// Reject bad payloads before attempting to insert defaults.
 	err = spec.ValidateParams(payload)
 	if err != nil {
-		return nil, err
-	}
-	err = spec.InsertDefaults(payload)
-	if err != nil {
-		return nil, err
+		return err
 	}
-	return u.st.EnqueueAction(u.Tag(), name, payload)
+	return spec.InsertDefaults(payload)
 }
 
 // ActionSpecs gets the ActionSpec map for the Unit's charm.
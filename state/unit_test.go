@@ -5,6 +5,7 @@ package state_test
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -684,6 +685,54 @@ func (s *UnitSuite) TestGetSetStatusDataChange(c *gc.C) {
 	c.Assert(data, gc.HasLen, 0)
 }
 
+func (s *UnitSuite) TestGetSetStatusDataNotError(c *gc.C) {
+	data := map[string]interface{}{
+		"connections": 42,
+		"replication": map[string]interface{}{
+			"lag-seconds": 1.5,
+			"peers":       []interface{}{"mysql/1", "mysql/2"},
+		},
+	}
+	err := s.unit.SetStatus(state.StatusRunning, "ready", data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, info, got, err := s.unit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.StatusRunning)
+	c.Assert(info, gc.Equals, "ready")
+	c.Assert(got, jc.DeepEquals, data)
+}
+
+func (s *UnitSuite) TestSetStatusDataInvalid(c *gc.C) {
+	for i, test := range []struct {
+		data map[string]interface{}
+		err  string
+	}{{
+		data: map[string]interface{}{"": 1},
+		err:  "status data keys must not be empty",
+	}, {
+		data: map[string]interface{}{"$set": 1},
+		err:  `invalid status data key "\$set"`,
+	}, {
+		data: map[string]interface{}{"nested": map[string]interface{}{"a.b": 1}},
+		err:  `invalid status data key "a.b"`,
+	}, {
+		data: map[string]interface{}{"list": []interface{}{map[string]interface{}{"$x": 1}}},
+		err:  `invalid status data key "\$x"`,
+	}, {
+		data: map[string]interface{}{"big": strings.Repeat("x", 16*1024)},
+		err:  "status data exceeds maximum size of 16384 bytes",
+	}} {
+		c.Logf("test %d", i)
+		err := s.unit.SetStatus(state.StatusRunning, "", test.data)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+	status, _, data, err := s.unit.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.StatusBusy)
+	c.Assert(data, gc.HasLen, 0)
+}
+
 func (s *UnitSuite) TestSetCharmURLSuccess(c *gc.C) {
 	preventUnitDestroyRemove(c, s.unit)
 	curl, ok := s.unit.CharmURL()
@@ -1680,6 +1729,25 @@ func (s *UnitSuite) TestUnitAgentTools(c *gc.C) {
 	testAgentTools(c, s.unit, `unit "wordpress/0"`)
 }
 
+func (s *UnitSuite) TestWorkloadVersion(c *gc.C) {
+	c.Assert(s.unit.WorkloadVersion(), gc.Equals, "")
+
+	err := s.unit.SetWorkloadVersion("3.4.1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.WorkloadVersion(), gc.Equals, "3.4.1")
+
+	unit, err := s.State.Unit(s.unit.Name())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.WorkloadVersion(), gc.Equals, "3.4.1")
+}
+
+func (s *UnitSuite) TestSetWorkloadVersionDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetWorkloadVersion("3.4.1")
+	c.Assert(err, gc.ErrorMatches, `cannot set workload version for unit "wordpress/0": not found or dead`)
+}
+
 func (s *UnitSuite) TestActionSpecs(c *gc.C) {
 	basicActions := `
 snapshot:
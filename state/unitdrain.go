@@ -0,0 +1,308 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// unitDrainTimeout is how long a dying unit's agent is given to run
+// its relation-broken and stop hooks before the unit's machine is
+// released without waiting for it.
+var unitDrainTimeout = 5 * time.Minute
+
+// UnitDrainPhase describes how far a dying unit has got through
+// draining.
+type UnitDrainPhase string
+
+const (
+	// UnitDraining indicates that the unit's agent has yet to run
+	// its relation-broken and stop hooks.
+	UnitDraining UnitDrainPhase = "draining"
+
+	// UnitDrained indicates that the unit's agent has run its stop
+	// hook, and the unit can be removed.
+	UnitDrained UnitDrainPhase = "drained"
+)
+
+// UnitDrainStatus describes the progress of a dying unit through
+// draining: the window in which its agent runs the hooks needed to
+// leave its relations and stop cleanly.
+type UnitDrainStatus struct {
+	// Phase holds the phase of the drain.
+	Phase UnitDrainPhase
+
+	// Started holds the time at which the drain started.
+	Started time.Time
+
+	// Deadline holds the time after which the unit's machine may
+	// be released whether or not the drain has completed.
+	Deadline time.Time
+
+	// Pending holds the hooks the unit's agent last reported it has
+	// yet to run, in the order it will run them.
+	Pending []string
+}
+
+// Done returns whether the drain has either completed, or run out of
+// time by the given time.
+func (s UnitDrainStatus) Done(now time.Time) bool {
+	return s.Phase == UnitDrained || !now.Before(s.Deadline)
+}
+
+// unitDrainDoc records the progress of a dying unit through draining.
+// The document is created when the drain starts, either because the
+// unit's agent reports its progress or because the unit's machine is
+// waiting to be released.
+type unitDrainDoc struct {
+	DocID    string         `bson:"_id"`
+	EnvUUID  string         `bson:"env-uuid"`
+	Phase    UnitDrainPhase `bson:"phase"`
+	Started  time.Time      `bson:"started"`
+	Deadline time.Time      `bson:"deadline"`
+	Pending  []string       `bson:"pending"`
+	TxnRevno int64          `bson:"txn-revno"`
+}
+
+func (doc *unitDrainDoc) status() UnitDrainStatus {
+	return UnitDrainStatus{
+		Phase:    doc.Phase,
+		Started:  doc.Started,
+		Deadline: doc.Deadline,
+		Pending:  doc.Pending,
+	}
+}
+
+func (u *Unit) getUnitDrainDoc() (*unitDrainDoc, error) {
+	unitDrains, closer := u.st.getCollection(unitDrainsC)
+	defer closer()
+	var doc unitDrainDoc
+	err := unitDrains.FindId(u.globalKey()).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &doc, nil
+}
+
+// DrainStatus returns the progress of the unit through draining. If
+// the drain has not started, an error satisfying errors.IsNotFound is
+// returned.
+func (u *Unit) DrainStatus() (UnitDrainStatus, error) {
+	doc, err := u.getUnitDrainDoc()
+	if err != nil {
+		return UnitDrainStatus{}, errors.Annotatef(err, "cannot get drain status for unit %q", u)
+	}
+	if doc == nil {
+		return UnitDrainStatus{}, errors.NotFoundf("drain status for unit %q", u)
+	}
+	return doc.status(), nil
+}
+
+// StartDrain starts the unit's drain, giving its agent until the drain
+// timeout to run its remaining hooks, and returns its progress. If the
+// drain has already started, its deadline is left unchanged. The unit
+// must be Dying.
+func (u *Unit) StartDrain() (status UnitDrainStatus, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot start drain for unit %q", u)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := u.getUnitDrainDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if doc != nil {
+			status = doc.status()
+			return nil, jujutxn.ErrNoOperations
+		}
+		if err := u.assertDying(attempt); err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc = u.newUnitDrainDoc(UnitDraining, nil)
+		status = doc.status()
+		return []txn.Op{u.dyingOp(), {
+			C:      unitDrainsC,
+			Id:     u.st.docID(u.globalKey()),
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	if err := u.st.run(buildTxn); err != nil {
+		return UnitDrainStatus{}, err
+	}
+	return status, nil
+}
+
+// SetDrainStatus records the hooks the unit's agent has yet to run
+// before it can stop, and whether it has finished draining, starting
+// the drain if necessary. The unit must be Dying.
+func (u *Unit) SetDrainStatus(pending []string, drained bool) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set drain status for unit %q", u)
+	if pending == nil {
+		pending = []string{}
+	}
+	phase := UnitDraining
+	if drained {
+		phase = UnitDrained
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if err := u.assertDying(attempt); err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc, err := u.getUnitDrainDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if doc == nil {
+			return []txn.Op{u.dyingOp(), {
+				C:      unitDrainsC,
+				Id:     u.st.docID(u.globalKey()),
+				Assert: txn.DocMissing,
+				Insert: u.newUnitDrainDoc(phase, pending),
+			}}, nil
+		}
+		return []txn.Op{u.dyingOp(), {
+			C:      unitDrainsC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$set", bson.D{
+				{"phase", phase},
+				{"pending", pending},
+			}}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+func (u *Unit) newUnitDrainDoc(phase UnitDrainPhase, pending []string) *unitDrainDoc {
+	if pending == nil {
+		pending = []string{}
+	}
+	now := nowToTheSecond()
+	return &unitDrainDoc{
+		EnvUUID:  u.st.EnvironUUID(),
+		Phase:    phase,
+		Started:  now,
+		Deadline: now.Add(unitDrainTimeout),
+		Pending:  pending,
+	}
+}
+
+// assertDying returns an error unless the unit is Dying, refreshing
+// it first if this is not the first attempt at a transaction.
+func (u *Unit) assertDying(attempt int) error {
+	if attempt > 0 {
+		if err := u.Refresh(); errors.IsNotFound(err) {
+			return ErrDead
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	switch u.doc.Life {
+	case Alive:
+		return errors.New("unit is not dying")
+	case Dead:
+		return ErrDead
+	}
+	return nil
+}
+
+func (u *Unit) dyingOp() txn.Op {
+	return txn.Op{
+		C:      unitsC,
+		Id:     u.doc.DocID,
+		Assert: bson.D{{"life", Dying}},
+	}
+}
+
+// WatchDrainStatus returns a watcher observing changes to the unit's
+// progress through draining.
+func (u *Unit) WatchDrainStatus() NotifyWatcher {
+	return newEntityWatcher(u.st, unitDrainsC, u.st.docID(u.globalKey()))
+}
+
+// removeUnitDrainOp returns the operation needed to remove the drain
+// document associated with the given globalKey, if it exists.
+func removeUnitDrainOp(st *State, globalKey string) txn.Op {
+	return txn.Op{
+		C:      unitDrainsC,
+		Id:     st.docID(globalKey),
+		Remove: true,
+	}
+}
+
+// drainPendingError is returned by machine cleanups that are waiting
+// for units on the machine to drain.
+type drainPendingError struct {
+	machineId string
+	units     []string
+}
+
+func (e *drainPendingError) Error() string {
+	return fmt.Sprintf("machine %s is waiting for units %v to drain", e.machineId, e.units)
+}
+
+// isDrainPending returns whether err indicates that a cleanup is
+// waiting for units to drain.
+func isDrainPending(err error) bool {
+	_, ok := errors.Cause(err).(*drainPendingError)
+	return ok
+}
+
+// drainUnits destroys the principal units of the supplied machine, and
+// gives the agents of those which are running until their drain
+// deadlines to run their relation-broken and stop hooks. It returns a
+// *drainPendingError while any unit is still draining, so that the
+// machine is not released until its units have had a chance to stop
+// cleanly.
+func (st *State) drainUnits(machine *Machine) error {
+	var draining []string
+	now := time.Now()
+	for _, unitName := range machine.doc.Principals {
+		unit, err := st.Unit(unitName)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if err := unit.Destroy(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := unit.Refresh(); errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if unit.Life() != Dying {
+			continue
+		}
+		// There's no point waiting for a unit whose
+		// agent is not running to drain.
+		if alive, err := unit.AgentPresence(); err != nil {
+			return errors.Trace(err)
+		} else if !alive {
+			continue
+		}
+		status, err := unit.StartDrain()
+		if errors.Cause(err) == ErrDead {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if !status.Done(now) {
+			draining = append(draining, unitName)
+		}
+	}
+	if len(draining) > 0 {
+		return &drainPendingError{machine.Id(), draining}
+	}
+	return nil
+}
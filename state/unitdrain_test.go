@@ -0,0 +1,185 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type UnitDrainSuite struct {
+	ConnSuite
+	machine *state.Machine
+	unit    *state.Unit
+}
+
+var _ = gc.Suite(&UnitDrainSuite{})
+
+func (s *UnitDrainSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, jc.ErrorIsNil)
+	// Once the agent has set a status, the unit is not removed
+	// as soon as it is destroyed.
+	err = unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine = machine
+	s.unit = unit
+}
+
+func (s *UnitDrainSuite) destroyUnit(c *gc.C) {
+	err := s.unit.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.Life(), gc.Equals, state.Dying)
+}
+
+func (s *UnitDrainSuite) TestDrainStatusNotStarted(c *gc.C) {
+	_, err := s.unit.DrainStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UnitDrainSuite) TestDrainRequiresDying(c *gc.C) {
+	err := s.unit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, gc.ErrorMatches, `cannot set drain status for unit "mysql/0": unit is not dying`)
+	_, err = s.unit.StartDrain()
+	c.Assert(err, gc.ErrorMatches, `cannot start drain for unit "mysql/0": unit is not dying`)
+}
+
+func (s *UnitDrainSuite) TestSetDrainStatus(c *gc.C) {
+	s.destroyUnit(c)
+	before := time.Now().Add(-time.Second)
+	err := s.unit.SetDrainStatus([]string{"relation-broken:0", "stop"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	status, err := s.unit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Phase, gc.Equals, state.UnitDraining)
+	c.Assert(status.Pending, jc.DeepEquals, []string{"relation-broken:0", "stop"})
+	c.Assert(status.Started.After(before), jc.IsTrue)
+	c.Assert(status.Deadline.Sub(status.Started), gc.Equals, 5*time.Minute)
+	c.Assert(status.Done(status.Started), jc.IsFalse)
+	c.Assert(status.Done(status.Deadline), jc.IsTrue)
+
+	err = s.unit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	drained, err := s.unit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(drained.Phase, gc.Equals, state.UnitDrained)
+	c.Assert(drained.Pending, gc.HasLen, 0)
+	c.Assert(drained.Deadline, gc.DeepEquals, status.Deadline)
+	c.Assert(drained.Done(status.Started), jc.IsTrue)
+}
+
+func (s *UnitDrainSuite) TestStartDrainKeepsDeadline(c *gc.C) {
+	s.destroyUnit(c)
+	started, err := s.unit.StartDrain()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(started.Phase, gc.Equals, state.UnitDraining)
+
+	err = s.unit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	status, err := s.unit.StartDrain()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Deadline, gc.DeepEquals, started.Deadline)
+	c.Assert(status.Pending, jc.DeepEquals, []string{"stop"})
+}
+
+func (s *UnitDrainSuite) TestWatchDrainStatus(c *gc.C) {
+	s.destroyUnit(c)
+	w := s.unit.WatchDrainStatus()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.unit.SetDrainStatus([]string{"stop"}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+
+	err = s.unit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+}
+
+func (s *UnitDrainSuite) TestRemoveUnitRemovesDrainStatus(c *gc.C) {
+	s.destroyUnit(c)
+	err := s.unit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.unit.DrainStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *UnitDrainSuite) setAgentPresence(c *gc.C) {
+	pinger, err := s.unit.SetAgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(c *gc.C) { pinger.Stop() })
+	s.State.StartSync()
+	err = s.unit.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *UnitDrainSuite) TestForceDestroyedMachineWaitsForDrain(c *gc.C) {
+	s.setAgentPresence(c)
+	err := s.machine.ForceDestroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The unit is destroyed, but the machine waits for it to drain.
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.Life(), gc.Equals, state.Dying)
+	status, err := s.unit.DrainStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status.Phase, gc.Equals, state.UnitDraining)
+	assertLife(c, s.machine, state.Dying)
+
+	// Once the unit has drained, the machine is released.
+	err = s.unit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	assertRemoved(c, s.unit)
+	assertLife(c, s.machine, state.Dead)
+}
+
+func (s *UnitDrainSuite) TestForceDestroyedMachineDrainTimeout(c *gc.C) {
+	s.PatchValue(state.UnitDrainTimeout, -time.Second)
+	s.setAgentPresence(c)
+	err := s.machine.ForceDestroy()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The unit's agent is running, but it has
+	// run out of time to drain.
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	assertRemoved(c, s.unit)
+	assertLife(c, s.machine, state.Dead)
+}
+
+func (s *UnitDrainSuite) TestForceDestroyedMachineAgentNotRunning(c *gc.C) {
+	err := s.machine.ForceDestroy()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	assertRemoved(c, s.unit)
+	assertLife(c, s.machine, state.Dead)
+}
@@ -0,0 +1,192 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// UnitRelationState describes the bookkeeping a unit's agent keeps for
+// one of the unit's relations, recording which relation hooks it has
+// run.
+type UnitRelationState struct {
+	// RelationId identifies the relation.
+	RelationId int
+
+	// Members maps the name of each remote unit the agent has run a
+	// relation-joined hook for to the settings version it last ran a
+	// hook for.
+	Members map[string]int64
+
+	// ChangedPending holds the name of the remote unit the agent must
+	// run a relation-changed hook for before any other, or "".
+	ChangedPending string
+}
+
+// unitRelationStateDoc records the bookkeeping a unit's agent keeps
+// for one of the unit's relations. The document is created when the
+// agent joins the relation, and removed when it has run the
+// relation-broken hook, or when the unit is removed.
+type unitRelationStateDoc struct {
+	DocID          string           `bson:"_id"`
+	EnvUUID        string           `bson:"env-uuid"`
+	Unit           string           `bson:"unit"`
+	RelationId     int              `bson:"relation-id"`
+	Members        map[string]int64 `bson:"members"`
+	ChangedPending string           `bson:"changed-pending,omitempty"`
+}
+
+// unitRelationStateKey returns the key of the document recording the
+// state of the given relation for the unit with the given global key.
+func unitRelationStateKey(unitGlobalKey string, relationId int) string {
+	return fmt.Sprintf("%s#relation#%d", unitGlobalKey, relationId)
+}
+
+// RelationStates returns the bookkeeping the unit's agent has recorded
+// for each of the unit's relations, in order of relation id.
+func (u *Unit) RelationStates() ([]UnitRelationState, error) {
+	unitRelationStates, closer := u.st.getCollection(unitRelationStatesC)
+	defer closer()
+	var docs []unitRelationStateDoc
+	err := unitRelationStates.Find(bson.D{{"unit", u.Name()}}).All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get relation states for unit %q", u)
+	}
+	states := make([]UnitRelationState, len(docs))
+	for i, doc := range docs {
+		members := doc.Members
+		if members == nil {
+			members = make(map[string]int64)
+		}
+		states[i] = UnitRelationState{
+			RelationId:     doc.RelationId,
+			Members:        members,
+			ChangedPending: doc.ChangedPending,
+		}
+	}
+	sort.Sort(unitRelationStatesById(states))
+	return states, nil
+}
+
+type unitRelationStatesById []UnitRelationState
+
+func (s unitRelationStatesById) Len() int           { return len(s) }
+func (s unitRelationStatesById) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s unitRelationStatesById) Less(i, j int) bool { return s[i].RelationId < s[j].RelationId }
+
+// SetRelationState records the bookkeeping the unit's agent keeps for
+// one of the unit's relations, replacing any previously recorded for
+// that relation.
+func (u *Unit) SetRelationState(state UnitRelationState) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set state of relation %d for unit %q", state.RelationId, u)
+	if state.RelationId < 0 {
+		return errors.NotValidf("relation id %d", state.RelationId)
+	}
+	for member := range state.Members {
+		if !names.IsValidUnit(member) {
+			return errors.NotValidf("member unit name %q", member)
+		}
+	}
+	if state.ChangedPending != "" {
+		if _, ok := state.Members[state.ChangedPending]; !ok {
+			return errors.NotValidf("changed pending for non-member %q", state.ChangedPending)
+		}
+	}
+	members := state.Members
+	if members == nil {
+		members = make(map[string]int64)
+	}
+	key := unitRelationStateKey(u.globalKey(), state.RelationId)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := u.Refresh(); errors.IsNotFound(err) {
+				return nil, ErrDead
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if u.doc.Life == Dead {
+				return nil, ErrDead
+			}
+		}
+		unitOp := txn.Op{
+			C:      unitsC,
+			Id:     u.doc.DocID,
+			Assert: notDeadDoc,
+		}
+		unitRelationStates, closer := u.st.getCollection(unitRelationStatesC)
+		defer closer()
+		count, err := unitRelationStates.FindId(key).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if count == 0 {
+			return []txn.Op{unitOp, {
+				C:      unitRelationStatesC,
+				Id:     u.st.docID(key),
+				Assert: txn.DocMissing,
+				Insert: &unitRelationStateDoc{
+					EnvUUID:        u.st.EnvironUUID(),
+					Unit:           u.Name(),
+					RelationId:     state.RelationId,
+					Members:        members,
+					ChangedPending: state.ChangedPending,
+				},
+			}}, nil
+		}
+		return []txn.Op{unitOp, {
+			C:      unitRelationStatesC,
+			Id:     u.st.docID(key),
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{
+				{"members", members},
+				{"changed-pending", state.ChangedPending},
+			}}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
+
+// RemoveRelationState removes the bookkeeping the unit's agent keeps
+// for the relation with the given id. It does not fail if none is
+// recorded.
+func (u *Unit) RemoveRelationState(relationId int) error {
+	ops := []txn.Op{{
+		C:      unitRelationStatesC,
+		Id:     u.st.docID(unitRelationStateKey(u.globalKey(), relationId)),
+		Remove: true,
+	}}
+	if err := u.st.runTransaction(ops); err != nil {
+		return errors.Annotatef(err, "cannot remove state of relation %d for unit %q", relationId, u)
+	}
+	return nil
+}
+
+// removeUnitRelationStatesOps returns the operations needed to remove
+// the relation bookkeeping recorded for the given unit.
+func removeUnitRelationStatesOps(st *State, u *Unit) ([]txn.Op, error) {
+	unitRelationStates, closer := st.getCollection(unitRelationStatesC)
+	defer closer()
+	var docs []struct {
+		DocID string `bson:"_id"`
+	}
+	err := unitRelationStates.Find(bson.D{{"unit", u.Name()}}).Select(bson.D{{"_id", 1}}).All(&docs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      unitRelationStatesC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type UnitRelationStateSuite struct {
+	ConnSuite
+	unit *state.Unit
+}
+
+var _ = gc.Suite(&UnitRelationStateSuite{})
+
+func (s *UnitRelationStateSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	s.unit = unit
+}
+
+func (s *UnitRelationStateSuite) TestRelationStatesNoneRecorded(c *gc.C) {
+	states, err := s.unit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+}
+
+func (s *UnitRelationStateSuite) TestSetRelationState(c *gc.C) {
+	joined := state.UnitRelationState{
+		RelationId:     3,
+		Members:        map[string]int64{"wordpress/0": 1},
+		ChangedPending: "wordpress/0",
+	}
+	err := s.unit.SetRelationState(joined)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetRelationState(state.UnitRelationState{RelationId: 1})
+	c.Assert(err, jc.ErrorIsNil)
+
+	states, err := s.unit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, []state.UnitRelationState{{
+		RelationId: 1,
+		Members:    map[string]int64{},
+	}, joined})
+
+	// A later state replaces the earlier one.
+	changed := state.UnitRelationState{
+		RelationId: 3,
+		Members:    map[string]int64{"wordpress/0": 2, "wordpress/1": 0},
+	}
+	err = s.unit.SetRelationState(changed)
+	c.Assert(err, jc.ErrorIsNil)
+	states, err = s.unit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 2)
+	c.Assert(states[1], jc.DeepEquals, changed)
+}
+
+func (s *UnitRelationStateSuite) TestSetRelationStateInvalid(c *gc.C) {
+	err := s.unit.SetRelationState(state.UnitRelationState{RelationId: -1})
+	c.Assert(err, gc.ErrorMatches, `cannot set state of relation -1 for unit "mysql/0": relation id -1 not valid`)
+	err = s.unit.SetRelationState(state.UnitRelationState{
+		RelationId: 1,
+		Members:    map[string]int64{"wordpress": 1},
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot set state of relation 1 for unit "mysql/0": member unit name "wordpress" not valid`)
+	err = s.unit.SetRelationState(state.UnitRelationState{
+		RelationId:     1,
+		ChangedPending: "wordpress/0",
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot set state of relation 1 for unit "mysql/0": changed pending for non-member "wordpress/0" not valid`)
+}
+
+func (s *UnitRelationStateSuite) TestSetRelationStateDeadUnit(c *gc.C) {
+	err := s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetRelationState(state.UnitRelationState{RelationId: 1})
+	c.Assert(err, gc.ErrorMatches, `cannot set state of relation 1 for unit "mysql/0": not found or dead`)
+}
+
+func (s *UnitRelationStateSuite) TestRemoveRelationState(c *gc.C) {
+	err := s.unit.SetRelationState(state.UnitRelationState{RelationId: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.SetRelationState(state.UnitRelationState{RelationId: 2})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.unit.RemoveRelationState(1)
+	c.Assert(err, jc.ErrorIsNil)
+	states, err := s.unit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, jc.DeepEquals, []state.UnitRelationState{{
+		RelationId: 2,
+		Members:    map[string]int64{},
+	}})
+
+	// Removing an unrecorded state is not an error.
+	err = s.unit.RemoveRelationState(1)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *UnitRelationStateSuite) TestRemoveUnitRemovesRelationStates(c *gc.C) {
+	err := s.unit.SetRelationState(state.UnitRelationState{RelationId: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	states, err := s.unit.RelationStates()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 0)
+}
@@ -1107,3 +1107,63 @@ var oldIndexesv123 = map[string][][]string{
 		{"subnetid"},
 	},
 }
+
+// AddCharmRefCounts creates the ref count documents of the charms in
+// every environment, counting a reference for each service and unit
+// using the charm, so that charms in use are never collected.
+func AddCharmRefCounts(st *State) error {
+	charms, closer := st.getRawCollection(charmsC)
+	defer closer()
+	charmrefs, closer := st.getRawCollection(charmrefsC)
+	defer closer()
+
+	refCounts := make(map[string]int)
+	countRefs := func(collName string) error {
+		coll, closer := st.getRawCollection(collName)
+		defer closer()
+		var doc struct {
+			EnvUUID  string     `bson:"env-uuid"`
+			CharmURL *charm.URL `bson:"charmurl"`
+		}
+		iter := coll.Find(nil).Select(bson.D{{"env-uuid", 1}, {"charmurl", 1}}).Iter()
+		for iter.Next(&doc) {
+			if doc.CharmURL != nil {
+				refCounts[doc.EnvUUID+":"+doc.CharmURL.String()]++
+			}
+			doc.CharmURL = nil
+		}
+		return errors.Trace(iter.Close())
+	}
+	if err := countRefs(servicesC); err != nil {
+		return err
+	}
+	if err := countRefs(unitsC); err != nil {
+		return err
+	}
+
+	var ops []txn.Op
+	var doc charmDoc
+	iter := charms.Find(nil).Select(bson.D{{"_id", 1}, {"env-uuid", 1}}).Iter()
+	for iter.Next(&doc) {
+		if count, err := charmrefs.FindId(doc.DocID).Count(); err != nil {
+			return errors.Trace(err)
+		} else if count > 0 {
+			continue
+		}
+		upgradesLogger.Debugf("adding ref count %d for charm %q", refCounts[doc.DocID], doc.DocID)
+		ops = append(ops, txn.Op{
+			C:      charmrefsC,
+			Id:     doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: charmRefsDoc{
+				DocID:    doc.DocID,
+				EnvUUID:  doc.EnvUUID,
+				RefCount: refCounts[doc.DocID],
+			},
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return st.runRawTransaction(ops)
+}
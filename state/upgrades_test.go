@@ -2251,3 +2251,72 @@ func countOldIndexes(c *gc.C, coll *mgo.Collection) (foundCount, oldCount int) {
 	}
 	return
 }
+
+func (s *upgradesSuite) TestAddCharmRefCounts(c *gc.C) {
+	uuid := s.state.EnvironUUID()
+	usedURL := charm.MustParseURL("cs:quantal/wordpress-1")
+	upgradedURL := charm.MustParseURL("cs:quantal/wordpress-2")
+	unusedURL := charm.MustParseURL("cs:quantal/mysql-1")
+	countedURL := charm.MustParseURL("cs:quantal/mysql-2")
+	insert := func(collName string, docs ...bson.M) {
+		coll, closer := s.state.getRawCollection(collName)
+		defer closer()
+		for _, doc := range docs {
+			err := coll.Insert(doc)
+			c.Assert(err, jc.ErrorIsNil)
+		}
+	}
+	var charmDocs []bson.M
+	for _, curl := range []*charm.URL{usedURL, upgradedURL, unusedURL, countedURL} {
+		charmDocs = append(charmDocs, bson.M{
+			"_id":      uuid + ":" + curl.String(),
+			"env-uuid": uuid,
+			"url":      curl,
+		})
+	}
+	insert(charmsC, charmDocs...)
+	insert(servicesC, bson.M{
+		"_id":      uuid + ":wordpress",
+		"env-uuid": uuid,
+		"charmurl": upgradedURL,
+	})
+	insert(unitsC, bson.M{
+		"_id":      uuid + ":wordpress/0",
+		"env-uuid": uuid,
+		"charmurl": usedURL,
+	}, bson.M{
+		"_id":      uuid + ":wordpress/1",
+		"env-uuid": uuid,
+		"charmurl": upgradedURL,
+	}, bson.M{
+		"_id":      uuid + ":wordpress/2",
+		"env-uuid": uuid,
+	})
+	// Existing ref counts are left alone.
+	insert(charmrefsC, bson.M{
+		"_id":      uuid + ":" + countedURL.String(),
+		"env-uuid": uuid,
+		"refcount": 3,
+	})
+
+	err := AddCharmRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+	// The step is idempotent.
+	err = AddCharmRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+
+	charmrefs, closer := s.state.getCollection(charmrefsC)
+	defer closer()
+	for curl, expect := range map[*charm.URL]int{
+		usedURL:     1,
+		upgradedURL: 2,
+		unusedURL:   0,
+		countedURL:  3,
+	} {
+		var doc charmRefsDoc
+		err := charmrefs.FindId(curl.String()).One(&doc)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(doc.RefCount, gc.Equals, expect, gc.Commentf("charm %q", curl))
+		c.Check(doc.EnvUUID, gc.Equals, uuid)
+	}
+}
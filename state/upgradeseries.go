@@ -0,0 +1,345 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/version"
+)
+
+// UpgradeSeriesStatus describes the progress of a machine, or of one
+// of the units on it, through an upgrade of the machine's series.
+type UpgradeSeriesStatus string
+
+const (
+	// UpgradeSeriesNotStarted means the machine is not being upgraded.
+	UpgradeSeriesNotStarted UpgradeSeriesStatus = ""
+
+	// UpgradeSeriesPrepareStarted means the units on the machine are
+	// running their pre-series-upgrade hooks.
+	UpgradeSeriesPrepareStarted UpgradeSeriesStatus = "prepare started"
+
+	// UpgradeSeriesPrepareCompleted means every unit on the machine
+	// has run its pre-series-upgrade hook, and the operating system
+	// may be upgraded.
+	UpgradeSeriesPrepareCompleted UpgradeSeriesStatus = "prepare completed"
+
+	// UpgradeSeriesCompleteStarted means the operator has upgraded the
+	// operating system, and the machine agent is adjusting itself to
+	// the new series.
+	UpgradeSeriesCompleteStarted UpgradeSeriesStatus = "complete started"
+
+	// UpgradeSeriesCompleteRunning means the machine agent has been
+	// adjusted, and the units on the machine are running their
+	// post-series-upgrade hooks.
+	UpgradeSeriesCompleteRunning UpgradeSeriesStatus = "complete running"
+
+	// UpgradeSeriesCompleted means a unit has run its
+	// post-series-upgrade hook. Once every unit has, the machine's
+	// series is changed and the upgrade is over.
+	UpgradeSeriesCompleted UpgradeSeriesStatus = "completed"
+)
+
+// upgradeSeriesDoc records the progress of an upgrade of a machine's
+// series. It exists only while the upgrade is in progress.
+type upgradeSeriesDoc struct {
+	DocID      string `bson:"_id"`
+	EnvUUID    string `bson:"env-uuid"`
+	MachineId  string `bson:"machineid"`
+	FromSeries string `bson:"from-series"`
+	ToSeries   string `bson:"to-series"`
+
+	Status UpgradeSeriesStatus `bson:"status"`
+
+	// UnitStatuses holds the progress of each unit on the machine,
+	// keyed by unit name.
+	UnitStatuses map[string]UpgradeSeriesStatus `bson:"unit-statuses"`
+
+	TxnRevno int64 `bson:"txn-revno"`
+}
+
+func removeUpgradeSeriesOp(st *State, machineId string) txn.Op {
+	return txn.Op{
+		C:      upgradeSeriesC,
+		Id:     st.docID(machineId),
+		Remove: true,
+	}
+}
+
+func (m *Machine) upgradeSeriesDoc() (*upgradeSeriesDoc, error) {
+	upgrades, closer := m.st.getCollection(upgradeSeriesC)
+	defer closer()
+
+	var doc upgradeSeriesDoc
+	err := upgrades.FindId(m.doc.DocID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("series upgrade for machine %s", m.doc.Id)
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot get series upgrade for machine %s", m.doc.Id)
+	}
+	return &doc, nil
+}
+
+// PrepareSeriesUpgrade starts an upgrade of the machine to the given
+// series of the same operating system. The units on the machine are
+// asked to run their pre-series-upgrade hooks; once they all have,
+// the machine's operating system may be upgraded.
+func (m *Machine) PrepareSeriesUpgrade(toSeries string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot prepare series upgrade of machine %s", m.doc.Id)
+	toOS, err := version.GetOSFromSeries(toSeries)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		if m.doc.Life != Alive {
+			return nil, errNotAlive
+		}
+		if m.doc.Series == toSeries {
+			return nil, errors.Errorf("machine is already running series %q", toSeries)
+		}
+		if fromOS, err := version.GetOSFromSeries(m.doc.Series); err != nil || fromOS != toOS {
+			return nil, errors.Errorf("cannot upgrade from series %q to %q", m.doc.Series, toSeries)
+		}
+		if _, err := m.upgradeSeriesDoc(); err == nil {
+			return nil, errors.AlreadyExistsf("series upgrade for machine %s", m.doc.Id)
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		units, err := m.Units()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		doc := &upgradeSeriesDoc{
+			MachineId:    m.doc.Id,
+			FromSeries:   m.doc.Series,
+			ToSeries:     toSeries,
+			Status:       UpgradeSeriesPrepareStarted,
+			UnitStatuses: make(map[string]UpgradeSeriesStatus),
+		}
+		for _, unit := range units {
+			doc.UnitStatuses[unit.Name()] = UpgradeSeriesPrepareStarted
+		}
+		if len(units) == 0 {
+			doc.Status = UpgradeSeriesPrepareCompleted
+		}
+		return []txn.Op{{
+			C:      machinesC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"life", Alive}, {"series", m.doc.Series}},
+		}, {
+			C:      upgradeSeriesC,
+			Id:     m.doc.DocID,
+			Assert: txn.DocMissing,
+			Insert: doc,
+		}}, nil
+	}
+	return m.st.run(buildTxn)
+}
+
+// CompleteSeriesUpgrade records that the machine's operating system has
+// been upgraded, so that the machine agent adjusts itself to the new
+// series and the units on the machine run their post-series-upgrade
+// hooks.
+func (m *Machine) CompleteSeriesUpgrade() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot complete series upgrade of machine %s", m.doc.Id)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := m.upgradeSeriesDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if doc.Status != UpgradeSeriesPrepareCompleted {
+			return nil, errors.Errorf("series upgrade is %s, not %s", doc.Status, UpgradeSeriesPrepareCompleted)
+		}
+		return []txn.Op{{
+			C:      upgradeSeriesC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"status", doc.Status}},
+			Update: bson.D{{"$set", bson.D{{"status", UpgradeSeriesCompleteStarted}}}},
+		}}, nil
+	}
+	return m.st.run(buildTxn)
+}
+
+// UpgradeSeriesStatus returns the progress of the machine through an
+// upgrade of its series, and the series it is being upgraded to. The
+// status is UpgradeSeriesNotStarted if no upgrade is in progress.
+func (m *Machine) UpgradeSeriesStatus() (UpgradeSeriesStatus, string, error) {
+	doc, err := m.upgradeSeriesDoc()
+	if errors.IsNotFound(err) {
+		return UpgradeSeriesNotStarted, "", nil
+	} else if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	return doc.Status, doc.ToSeries, nil
+}
+
+// UpgradeSeriesAgentUpdated records that the machine agent has adjusted
+// itself to the series the machine is being upgraded to, so that the
+// units on the machine run their post-series-upgrade hooks.
+func (m *Machine) UpgradeSeriesAgentUpdated() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot update series upgrade of machine %s", m.doc.Id)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := m.upgradeSeriesDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if doc.Status != UpgradeSeriesCompleteStarted {
+			return nil, errors.Errorf("series upgrade is %s, not %s", doc.Status, UpgradeSeriesCompleteStarted)
+		}
+		if len(doc.UnitStatuses) == 0 {
+			return m.finishSeriesUpgradeOps(doc), nil
+		}
+		set := bson.D{{"status", UpgradeSeriesCompleteRunning}}
+		for unitName := range doc.UnitStatuses {
+			set = append(set, bson.DocElem{"unit-statuses." + unitName, UpgradeSeriesCompleteRunning})
+		}
+		return []txn.Op{{
+			C:      upgradeSeriesC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$set", set}},
+		}}, nil
+	}
+	return m.st.run(buildTxn)
+}
+
+// finishSeriesUpgradeOps returns the operations that change the
+// machine's series to the one it has been upgraded to, and remove the
+// record of the upgrade.
+func (m *Machine) finishSeriesUpgradeOps(doc *upgradeSeriesDoc) []txn.Op {
+	return []txn.Op{{
+		C:      machinesC,
+		Id:     m.doc.DocID,
+		Assert: bson.D{{"series", doc.FromSeries}},
+		Update: bson.D{{"$set", bson.D{{"series", doc.ToSeries}}}},
+	}, {
+		C:      upgradeSeriesC,
+		Id:     m.doc.DocID,
+		Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+		Remove: true,
+	}}
+}
+
+// WatchUpgradeSeries returns a watcher that notifies of changes to the
+// progress of an upgrade of the machine's series.
+func (m *Machine) WatchUpgradeSeries() NotifyWatcher {
+	return newEntityWatcher(m.st, upgradeSeriesC, m.doc.DocID)
+}
+
+func (u *Unit) upgradeSeriesMachine() (*Machine, error) {
+	machineId, err := u.AssignedMachineId()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return u.st.Machine(machineId)
+}
+
+// UpgradeSeriesStatus returns the progress of the unit through an
+// upgrade of its machine's series. The status is
+// UpgradeSeriesNotStarted if no upgrade is in progress, or the unit
+// was not on the machine when it started.
+func (u *Unit) UpgradeSeriesStatus() (UpgradeSeriesStatus, error) {
+	m, err := u.upgradeSeriesMachine()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	doc, err := m.upgradeSeriesDoc()
+	if errors.IsNotFound(err) {
+		return UpgradeSeriesNotStarted, nil
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	return doc.UnitStatuses[u.doc.Name], nil
+}
+
+// WatchUpgradeSeries returns a watcher that notifies of changes to the
+// progress of an upgrade of the series of the unit's machine.
+func (u *Unit) WatchUpgradeSeries() (NotifyWatcher, error) {
+	m, err := u.upgradeSeriesMachine()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return m.WatchUpgradeSeries(), nil
+}
+
+// upgradeSeriesUnitTransitions maps the statuses a unit may record to
+// the status the unit must have beforehand.
+var upgradeSeriesUnitTransitions = map[UpgradeSeriesStatus]UpgradeSeriesStatus{
+	UpgradeSeriesPrepareCompleted: UpgradeSeriesPrepareStarted,
+	UpgradeSeriesCompleted:        UpgradeSeriesCompleteRunning,
+}
+
+// SetUpgradeSeriesStatus records the unit's progress through an upgrade
+// of its machine's series, once it has run its pre-series-upgrade or
+// post-series-upgrade hook. When the last unit on the machine does so,
+// the machine moves on to the next phase of the upgrade.
+func (u *Unit) SetUpgradeSeriesStatus(status UpgradeSeriesStatus) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set series upgrade status of unit %q", u)
+	from, ok := upgradeSeriesUnitTransitions[status]
+	if !ok {
+		return errors.NotValidf("series upgrade status %q", status)
+	}
+	m, err := u.upgradeSeriesMachine()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := m.upgradeSeriesDoc()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		current, ok := doc.UnitStatuses[u.doc.Name]
+		if !ok {
+			return nil, errors.NotFoundf("unit in series upgrade")
+		}
+		if current == status {
+			return nil, jujutxn.ErrNoOperations
+		}
+		if current != from {
+			return nil, errors.Errorf("unit is %s, not %s", current, from)
+		}
+		done := true
+		for unitName, unitStatus := range doc.UnitStatuses {
+			if unitName == u.doc.Name || unitStatus == status {
+				continue
+			}
+			// Units removed from the machine since the upgrade
+			// started do not hold it up.
+			if _, err := u.st.Unit(unitName); errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			done = false
+			break
+		}
+		if done && status == UpgradeSeriesCompleted {
+			if err := m.Refresh(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return m.finishSeriesUpgradeOps(doc), nil
+		}
+		set := bson.D{{"unit-statuses." + u.doc.Name, status}}
+		if done {
+			set = append(set, bson.DocElem{"status", UpgradeSeriesPrepareCompleted})
+		}
+		return []txn.Op{{
+			C:      upgradeSeriesC,
+			Id:     m.doc.DocID,
+			Assert: bson.D{{"txn-revno", doc.TxnRevno}},
+			Update: bson.D{{"$set", set}},
+		}}, nil
+	}
+	return u.st.run(buildTxn)
+}
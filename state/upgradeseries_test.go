@@ -0,0 +1,160 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/testing/factory"
+)
+
+type UpgradeSeriesSuite struct {
+	ConnSuite
+	machine *state.Machine
+	unit0   *state.Unit
+	unit1   *state.Unit
+}
+
+var _ = gc.Suite(&UpgradeSeriesSuite{})
+
+func (s *UpgradeSeriesSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.machine, err = s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	s.unit0 = s.factory.MakeUnit(c, &factory.UnitParams{Machine: s.machine})
+	s.unit1 = s.factory.MakeUnit(c, &factory.UnitParams{Machine: s.machine})
+}
+
+func (s *UpgradeSeriesSuite) assertMachineStatus(c *gc.C, expect state.UpgradeSeriesStatus) {
+	status, _, err := s.machine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, expect)
+}
+
+func (s *UpgradeSeriesSuite) assertUnitStatus(c *gc.C, unit *state.Unit, expect state.UpgradeSeriesStatus) {
+	status, err := unit.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, expect)
+}
+
+func (s *UpgradeSeriesSuite) TestNotStarted(c *gc.C) {
+	status, toSeries, err := s.machine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.UpgradeSeriesNotStarted)
+	c.Assert(toSeries, gc.Equals, "")
+	s.assertUnitStatus(c, s.unit0, state.UpgradeSeriesNotStarted)
+}
+
+func (s *UpgradeSeriesSuite) TestPrepareValidation(c *gc.C) {
+	err := s.machine.PrepareSeriesUpgrade("quantal")
+	c.Assert(err, gc.ErrorMatches, `cannot prepare series upgrade of machine 0: machine is already running series "quantal"`)
+	err = s.machine.PrepareSeriesUpgrade("win2012r2")
+	c.Assert(err, gc.ErrorMatches, `cannot prepare series upgrade of machine 0: cannot upgrade from series "quantal" to "win2012r2"`)
+	err = s.machine.PrepareSeriesUpgrade("nonsense")
+	c.Assert(err, gc.ErrorMatches, `cannot prepare series upgrade of machine 0: invalid series "nonsense"`)
+
+	err = s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, gc.ErrorMatches, `cannot prepare series upgrade of machine 0: series upgrade for machine 0 already exists`)
+}
+
+func (s *UpgradeSeriesSuite) TestCompleteBeforePrepared(c *gc.C) {
+	err := s.machine.CompleteSeriesUpgrade()
+	c.Assert(err, gc.ErrorMatches, `cannot complete series upgrade of machine 0: series upgrade for machine 0 not found`)
+
+	err = s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.CompleteSeriesUpgrade()
+	c.Assert(err, gc.ErrorMatches, `cannot complete series upgrade of machine 0: series upgrade is prepare started, not prepare completed`)
+}
+
+func (s *UpgradeSeriesSuite) TestUnitStatusValidation(c *gc.C) {
+	err := s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit0.SetUpgradeSeriesStatus(state.UpgradeSeriesCompleteStarted)
+	c.Assert(err, gc.ErrorMatches, `cannot set series upgrade status of unit "[^"]*": series upgrade status "complete started" not valid`)
+	err = s.unit0.SetUpgradeSeriesStatus(state.UpgradeSeriesCompleted)
+	c.Assert(err, gc.ErrorMatches, `cannot set series upgrade status of unit "[^"]*": unit is prepare started, not complete running`)
+}
+
+func (s *UpgradeSeriesSuite) TestUpgradeSeries(c *gc.C) {
+	w := s.machine.WatchUpgradeSeries()
+	defer statetesting.AssertStop(c, w)
+	wc := statetesting.NewNotifyWatcherC(c, s.State, w)
+	wc.AssertOneChange()
+
+	err := s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	wc.AssertOneChange()
+	status, toSeries, err := s.machine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.UpgradeSeriesPrepareStarted)
+	c.Assert(toSeries, gc.Equals, "trusty")
+	s.assertUnitStatus(c, s.unit0, state.UpgradeSeriesPrepareStarted)
+
+	// The machine is prepared once every unit has run its hook.
+	err = s.unit0.SetUpgradeSeriesStatus(state.UpgradeSeriesPrepareCompleted)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesPrepareStarted)
+	wc.AssertOneChange()
+	err = s.unit1.SetUpgradeSeriesStatus(state.UpgradeSeriesPrepareCompleted)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesPrepareCompleted)
+	wc.AssertOneChange()
+
+	err = s.machine.CompleteSeriesUpgrade()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesCompleteStarted)
+	s.assertUnitStatus(c, s.unit0, state.UpgradeSeriesPrepareCompleted)
+	wc.AssertOneChange()
+
+	err = s.machine.UpgradeSeriesAgentUpdated()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesCompleteRunning)
+	s.assertUnitStatus(c, s.unit0, state.UpgradeSeriesCompleteRunning)
+	s.assertUnitStatus(c, s.unit1, state.UpgradeSeriesCompleteRunning)
+	wc.AssertOneChange()
+
+	err = s.unit0.SetUpgradeSeriesStatus(state.UpgradeSeriesCompleted)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesCompleteRunning)
+	wc.AssertOneChange()
+
+	// Once the last unit has run its hook, the machine's series is
+	// changed and the upgrade is over.
+	err = s.unit1.SetUpgradeSeriesStatus(state.UpgradeSeriesCompleted)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMachineStatus(c, state.UpgradeSeriesNotStarted)
+	s.assertUnitStatus(c, s.unit1, state.UpgradeSeriesNotStarted)
+	err = s.machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machine.Series(), gc.Equals, "trusty")
+	wc.AssertOneChange()
+}
+
+func (s *UpgradeSeriesSuite) TestUpgradeSeriesNoUnits(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	status, _, err := machine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.UpgradeSeriesPrepareCompleted)
+
+	err = machine.CompleteSeriesUpgrade()
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.UpgradeSeriesAgentUpdated()
+	c.Assert(err, jc.ErrorIsNil)
+	status, _, err = machine.UpgradeSeriesStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(status, gc.Equals, state.UpgradeSeriesNotStarted)
+	err = machine.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Series(), gc.Equals, "trusty")
+}
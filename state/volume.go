@@ -210,6 +210,26 @@ func (st *State) Volume(tag names.VolumeTag) (Volume, error) {
 	return &v, nil
 }
 
+// Volumes returns up to limit volumes in the environment whose names
+// sort after the given name, in order of name. If limit is zero, all
+// such volumes are returned. Callers may page through all volumes by
+// passing the name of the last volume returned as the next call's after.
+func (st *State) Volumes(after string, limit int) ([]Volume, error) {
+	coll, cleanup := st.getCollection(volumesC)
+	defer cleanup()
+
+	var docs []volumeDoc
+	query := coll.Find(bson.D{{"name", bson.D{{"$gt", after}}}}).Sort("name")
+	if err := query.Limit(limit).All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get volumes")
+	}
+	volumes := make([]Volume, len(docs))
+	for i, doc := range docs {
+		volumes[i] = &volume{doc}
+	}
+	return volumes, nil
+}
+
 // StorageInstanceVolume returns the Volume assigned to the specified
 // storage instance.
 func (st *State) StorageInstanceVolume(tag names.StorageTag) (Volume, error) {
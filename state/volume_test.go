@@ -63,6 +63,41 @@ func (s *VolumeStateSuite) TestAddMachine(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *VolumeStateSuite) TestVolumes(c *gc.C) {
+	service, unit, _ := s.setupSingleStorage(c, "block")
+	units := []*state.Unit{unit}
+	for i := 0; i < 2; i++ {
+		u, err := service.AddUnit()
+		c.Assert(err, jc.ErrorIsNil)
+		units = append(units, u)
+	}
+	for _, u := range units {
+		err := s.State.AssignUnit(u, state.AssignCleanEmpty)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	volumeNames := func(volumes []state.Volume) []string {
+		names := make([]string, len(volumes))
+		for i, v := range volumes {
+			names[i] = v.VolumeTag().Id()
+		}
+		return names
+	}
+
+	volumes, err := s.State.Volumes("", 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeNames(volumes), jc.DeepEquals, []string{"0", "1", "2"})
+
+	volumes, err = s.State.Volumes("", 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeNames(volumes), jc.DeepEquals, []string{"0", "1"})
+	volumes, err = s.State.Volumes("1", 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumeNames(volumes), jc.DeepEquals, []string{"2"})
+	volumes, err = s.State.Volumes("2", 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumes, gc.HasLen, 0)
+}
+
 func (s *VolumeStateSuite) TestAddServiceInvalidPool(c *gc.C) {
 	ch := s.AddTestingCharm(c, "storage-block")
 	storage := map[string]state.StorageConstraints{
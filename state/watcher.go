@@ -1265,6 +1265,13 @@ func (st *State) WatchStateServerInfo() NotifyWatcher {
 	return newEntityWatcher(st, stateServersC, environGlobalKey)
 }
 
+// WatchStateServingInfo returns a NotifyWatcher that notifies when
+// the state serving information, including the state server
+// certificate, changes.
+func (st *State) WatchStateServingInfo() NotifyWatcher {
+	return newEntityWatcher(st, stateServersC, stateServingInfoKey)
+}
+
 // Watch returns a watcher for observing changes to a machine.
 func (m *Machine) Watch() NotifyWatcher {
 	return newEntityWatcher(m.st, machinesC, m.doc.DocID)
@@ -1280,6 +1287,12 @@ func (u *Unit) Watch() NotifyWatcher {
 	return newEntityWatcher(u.st, unitsC, u.doc.DocID)
 }
 
+// Watch returns a watcher for observing changes to an action,
+// including the recording of its output while it runs.
+func (a *Action) Watch() NotifyWatcher {
+	return newEntityWatcher(a.st, actionsC, a.doc.DocId)
+}
+
 // Watch returns a watcher for observing changes to an environment.
 func (e *Environment) Watch() NotifyWatcher {
 	return newEntityWatcher(e.st, environmentsC, e.doc.UUID)
@@ -1310,6 +1323,13 @@ func (st *State) WatchAPIHostPorts() NotifyWatcher {
 	return newEntityWatcher(st, stateServersC, apiHostPortsKey)
 }
 
+// WatchAgentConfigSettings returns a NotifyWatcher that notifies when
+// the environment settings that agents record in their configuration
+// might have changed.
+func (st *State) WatchAgentConfigSettings() NotifyWatcher {
+	return st.WatchForEnvironConfigChanges()
+}
+
 // WatchVolumeAttachment returns a watcher for observing changes
 // to a volume attachment.
 func (st *State) WatchVolumeAttachment(m names.MachineTag, v names.VolumeTag) NotifyWatcher {
@@ -1324,6 +1344,12 @@ func (st *State) WatchFilesystemAttachment(m names.MachineTag, f names.Filesyste
 	return newEntityWatcher(st, filesystemAttachmentsC, st.docID(id))
 }
 
+// WatchAnnotations returns a watcher for observing changes to the
+// annotations of the given entity.
+func (st *State) WatchAnnotations(entity GlobalEntity) NotifyWatcher {
+	return newEntityWatcher(st, annotationsC, st.docID(entity.globalKey()))
+}
+
 // WatchConfigSettings returns a watcher for observing changes to the
 // unit's service configuration settings. The unit must have a charm URL
 // set before this method is called, and the returned watcher will be
@@ -1690,6 +1716,261 @@ func (w *cleanupWatcher) loop() (err error) {
 	}
 }
 
+// actionSchedulesWatcher notifies of changes in the action schedules
+// collection.
+type actionSchedulesWatcher struct {
+	commonWatcher
+	out chan struct{}
+}
+
+var _ Watcher = (*actionSchedulesWatcher)(nil)
+
+// WatchActionSchedules returns a NotifyWatcher which notifies when
+// action schedules are added, dispatched or cancelled.
+func (st *State) WatchActionSchedules() NotifyWatcher {
+	return newActionSchedulesWatcher(st)
+}
+
+func newActionSchedulesWatcher(st *State) NotifyWatcher {
+	w := &actionSchedulesWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *actionSchedulesWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *actionSchedulesWatcher) loop() (err error) {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollectionWithFilter(actionSchedulesC, in, w.st.isForStateEnv)
+	defer w.st.watcher.UnwatchCollection(actionSchedulesC, in)
+
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// meterStatusesWatcher notifies of changes to the meter status of any
+// unit in the environment.
+type meterStatusesWatcher struct {
+	commonWatcher
+	out chan struct{}
+}
+
+var _ Watcher = (*meterStatusesWatcher)(nil)
+
+// WatchMeterStatuses returns a NotifyWatcher which notifies when the
+// meter status of any unit in the environment changes, or a unit is
+// added or removed.
+func (st *State) WatchMeterStatuses() NotifyWatcher {
+	return newMeterStatusesWatcher(st)
+}
+
+func newMeterStatusesWatcher(st *State) NotifyWatcher {
+	w := &meterStatusesWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *meterStatusesWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *meterStatusesWatcher) loop() (err error) {
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollectionWithFilter(meterStatusC, in, w.st.isForStateEnv)
+	defer w.st.watcher.UnwatchCollection(meterStatusC, in)
+
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// charmRevisionsWatcher notifies of changes to the revisions of the
+// charms of the environment's services, or the latest revisions
+// available to them.
+type charmRevisionsWatcher struct {
+	commonWatcher
+	out chan struct{}
+}
+
+var _ Watcher = (*charmRevisionsWatcher)(nil)
+
+// WatchCharmRevisions returns a NotifyWatcher which notifies when the
+// revision of any service's charm, or the latest revision available
+// to it in the charm store, changes, or a service is added or removed.
+func (st *State) WatchCharmRevisions() NotifyWatcher {
+	return newCharmRevisionsWatcher(st)
+}
+
+func newCharmRevisionsWatcher(st *State) NotifyWatcher {
+	w := &charmRevisionsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *charmRevisionsWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *charmRevisionsWatcher) loop() (err error) {
+	// Placeholder charms record the latest revisions available to
+	// services tracking the stable channel; other services record
+	// them on their own documents.
+	in := make(chan watcher.Change)
+	w.st.watcher.WatchCollectionWithFilter(charmsC, in, w.st.isForStateEnv)
+	defer w.st.watcher.UnwatchCollection(charmsC, in)
+	w.st.watcher.WatchCollectionWithFilter(servicesC, in, w.st.isForStateEnv)
+	defer w.st.watcher.UnwatchCollection(servicesC, in)
+
+	revisions, err := w.st.ServiceCharmRevisions()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			latest, err := w.st.ServiceCharmRevisions()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !reflect.DeepEqual(latest, revisions) {
+				revisions = latest
+				out = w.out
+			}
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
+// authorisedKeysWatcher notifies of changes to the ssh keys authorised
+// on the environment's machines: those in the environment config, and
+// those in its users' key namespaces.
+type authorisedKeysWatcher struct {
+	commonWatcher
+	out chan struct{}
+}
+
+var _ Watcher = (*authorisedKeysWatcher)(nil)
+
+// WatchAuthorisedKeys returns a NotifyWatcher which notifies when the
+// environment's authorised keys change, or a key is added to or
+// removed from any user's key namespace. Keys expiring do not cause
+// an event.
+func (st *State) WatchAuthorisedKeys() NotifyWatcher {
+	return newAuthorisedKeysWatcher(st)
+}
+
+func newAuthorisedKeysWatcher(st *State) NotifyWatcher {
+	w := &authorisedKeysWatcher{
+		commonWatcher: commonWatcher{st: st},
+		out:           make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+// Changes returns the event channel for w.
+func (w *authorisedKeysWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+func (w *authorisedKeysWatcher) loop() (err error) {
+	settings, closer := w.st.getCollection(settingsC)
+	settingsKey := w.st.docID(environGlobalKey)
+	txnRevno, err := getTxnRevno(settings, settingsKey)
+	closer()
+	if err != nil {
+		return err
+	}
+	in := make(chan watcher.Change)
+	w.st.watcher.Watch(settingsC, settingsKey, txnRevno, in)
+	defer w.st.watcher.Unwatch(settingsC, settingsKey, in)
+	w.st.watcher.WatchCollectionWithFilter(sshKeysC, in, w.st.isForStateEnv)
+	defer w.st.watcher.UnwatchCollection(sshKeysC, in)
+
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.st.watcher.Dead():
+			return stateWatcherDeadError(w.st.watcher.Err())
+		case ch := <-in:
+			if _, ok := collect(ch, in, w.tomb.Dying()); !ok {
+				return tomb.ErrDying
+			}
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+		}
+	}
+}
+
 // actionStatusWatcher is a StringsWatcher that filters notifications
 // to Action Id's that match the ActionReceiver and ActionStatus set
 // provided.
@@ -0,0 +1,179 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package watcher
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"launchpad.net/tomb"
+)
+
+// OplogPeriod is the delay between each sync while the oplog is
+// being tailed. Syncs are normally triggered by oplog entries, so
+// the periodic sync is only a safety net and may be infrequent.
+// It must not be changed when any watchers are active.
+var OplogPeriod time.Duration = 30 * time.Second
+
+// tailTimeout is how long a tailable cursor waits for new entries
+// before giving the tailer a chance to check whether it is dying.
+const tailTimeout = time.Second
+
+// tailRetryDelay is how long the tailer waits before querying
+// again when its cursor has been invalidated by the server.
+const tailRetryDelay = time.Second
+
+// oplogEntry holds the fields of a mongo oplog entry that the
+// tailer needs to follow the log.
+type oplogEntry struct {
+	Timestamp bson.MongoTimestamp `bson:"ts"`
+	Operation string              `bson:"op"`
+}
+
+// oplogTailer follows a mongo oplog and reports whenever a document
+// is inserted into the namespace of the transaction changelog, so
+// the watcher can sync as soon as a transaction is applied rather
+// than waiting for its next poll.
+type oplogTailer struct {
+	tomb    tomb.Tomb
+	oplog   *mgo.Collection
+	ns      string
+	changes chan struct{}
+}
+
+// newOplogTailer returns a new oplogTailer reporting insertions
+// into the namespace ns recorded by the given oplog. The tailer
+// uses its own copy of the oplog's session, so the long-lived
+// cursor does not hold up other queries.
+func newOplogTailer(oplog *mgo.Collection, ns string) *oplogTailer {
+	t := &oplogTailer{
+		oplog:   oplog,
+		ns:      ns,
+		changes: make(chan struct{}, 1),
+	}
+	go func() {
+		defer t.tomb.Done()
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+// Changes returns a channel that receives a value when new
+// changelog entries have been observed. Notifications are
+// coalesced, so a single value may stand for many entries.
+func (t *oplogTailer) Changes() <-chan struct{} {
+	return t.changes
+}
+
+// Stop stops the tailer.
+func (t *oplogTailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Dead returns a channel that is closed when the tailer has stopped.
+func (t *oplogTailer) Dead() <-chan struct{} {
+	return t.tomb.Dead()
+}
+
+// Err returns the error with which the tailer stopped.
+func (t *oplogTailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *oplogTailer) loop() error {
+	session := t.oplog.Database.Session.Copy()
+	defer session.Close()
+	oplog := t.oplog.With(session)
+
+	lastTimestamp, err := t.initLastTimestamp(oplog)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		lastTimestamp, err = t.tail(oplog, lastTimestamp)
+		if err != nil {
+			return err
+		}
+		// The cursor has been invalidated, which happens when
+		// the oplog rolls over faster than we can follow it or
+		// when there were no matching entries to start from.
+		// Pause briefly and start again from where we left off;
+		// anything missed is picked up by the watcher's
+		// periodic sync.
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case <-time.After(tailRetryDelay):
+		}
+	}
+}
+
+// initLastTimestamp checks that the oplog exists and returns the
+// timestamp of its most recent entry, so that only entries made
+// after the tailer started are reported.
+func (t *oplogTailer) initLastTimestamp(oplog *mgo.Collection) (bson.MongoTimestamp, error) {
+	names, err := oplog.Database.CollectionNames()
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot list collections")
+	}
+	found := false
+	for _, name := range names {
+		if name == oplog.Name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, errors.NotFoundf("oplog %q", oplog.FullName)
+	}
+	var entry oplogEntry
+	err = oplog.Find(nil).Sort("-$natural").One(&entry)
+	if err != nil && err != mgo.ErrNotFound {
+		return 0, errors.Annotate(err, "cannot read oplog")
+	}
+	return entry.Timestamp, nil
+}
+
+// tail follows the oplog from the given timestamp until the cursor
+// is invalidated, and returns the timestamp of the last entry seen.
+func (t *oplogTailer) tail(oplog *mgo.Collection, lastTimestamp bson.MongoTimestamp) (bson.MongoTimestamp, error) {
+	query := bson.D{
+		{"ts", bson.D{{"$gt", lastTimestamp}}},
+		{"ns", t.ns},
+	}
+	iter := oplog.Find(query).LogReplay().Tail(tailTimeout)
+	defer iter.Close()
+	var entry oplogEntry
+	for {
+		for iter.Next(&entry) {
+			lastTimestamp = entry.Timestamp
+			if entry.Operation == "i" {
+				t.notify()
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return lastTimestamp, errors.Annotate(err, "cannot tail oplog")
+		}
+		if !iter.Timeout() {
+			return lastTimestamp, nil
+		}
+		select {
+		case <-t.tomb.Dying():
+			return lastTimestamp, tomb.ErrDying
+		default:
+		}
+	}
+}
+
+// notify queues a notification on the changes channel unless
+// one is already pending.
+func (t *oplogTailer) notify() {
+	select {
+	case t.changes <- struct{}{}:
+	default:
+	}
+}
@@ -24,6 +24,11 @@ type Watcher struct {
 	tomb tomb.Tomb
 	log  *mgo.Collection
 
+	// oplog holds the mongo oplog tailed to learn about new
+	// changelog entries as they are written. When it is nil, or
+	// cannot be tailed, the watcher falls back to polling.
+	oplog *mgo.Collection
+
 	// watches holds the observers managed by Watch/Unwatch.
 	watches map[watchKey][]watchInfo
 
@@ -105,8 +110,18 @@ type event struct {
 // New returns a new Watcher observing the changelog collection,
 // which must be a capped collection maintained by mgo/txn.
 func New(changelog *mgo.Collection) *Watcher {
+	return NewWithOplog(changelog, nil)
+}
+
+// NewWithOplog returns a new Watcher observing the changelog
+// collection like New, but which tails the given mongo oplog to
+// sync as soon as new changelog entries are written, instead of
+// waiting for the next poll. If the oplog is nil or cannot be
+// tailed, the watcher polls every Period as New's does.
+func NewWithOplog(changelog, oplog *mgo.Collection) *Watcher {
 	w := &Watcher{
 		log:     changelog,
+		oplog:   oplog,
 		watches: make(map[watchKey][]watchInfo),
 		current: make(map[watchKey]int64),
 		request: make(chan interface{}),
@@ -215,7 +230,18 @@ var Period time.Duration = 5 * time.Second
 
 // loop implements the main watcher loop.
 func (w *Watcher) loop() error {
-	next := time.After(Period)
+	period := Period
+	var tailer *oplogTailer
+	var tailChanges, tailDead <-chan struct{}
+	if w.oplog != nil {
+		// Any error the tailer stops with is logged
+		// when we fall back to polling below.
+		tailer = newOplogTailer(w.oplog, w.log.FullName)
+		defer tailer.Stop()
+		tailChanges, tailDead = tailer.Changes(), tailer.Dead()
+		period = OplogPeriod
+	}
+	next := time.After(period)
 	w.needSync = true
 	if err := w.initLastId(); err != nil {
 		return errors.Trace(err)
@@ -226,13 +252,20 @@ func (w *Watcher) loop() error {
 				return errors.Trace(err)
 			}
 			w.flush()
-			next = time.After(Period)
+			next = time.After(period)
 		}
 		select {
 		case <-w.tomb.Dying():
 			return errors.Trace(tomb.ErrDying)
+		case <-tailChanges:
+			w.needSync = true
+		case <-tailDead:
+			logger.Infof("cannot tail oplog, polling for changes instead: %v", tailer.Err())
+			tailChanges, tailDead = nil, nil
+			period = Period
+			w.needSync = true
 		case <-next:
-			next = time.After(Period)
+			next = time.After(period)
 			w.needSync = true
 		case req := <-w.request:
 			w.handle(req)
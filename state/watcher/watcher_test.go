@@ -11,6 +11,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 	"launchpad.net/tomb"
 
@@ -56,6 +57,12 @@ type watcherSuite struct {
 	w         *watcher.Watcher
 	ch        chan watcher.Change
 	oldPeriod time.Duration
+
+	// oplog, when set, is a fake replica set oplog that
+	// records the changelog insertions made by run.
+	oplog          *mgo.Collection
+	oplogTs        bson.MongoTimestamp
+	oldOplogPeriod time.Duration
 }
 
 // FastPeriodSuite implements tests that should
@@ -75,12 +82,14 @@ func (s *watcherSuite) SetUpSuite(c *gc.C) {
 	s.BaseSuite.SetUpSuite(c)
 	s.MgoSuite.SetUpSuite(c)
 	s.oldPeriod = watcher.Period
+	s.oldOplogPeriod = watcher.OplogPeriod
 }
 
 func (s *watcherSuite) TearDownSuite(c *gc.C) {
 	s.MgoSuite.TearDownSuite(c)
 	s.BaseSuite.TearDownSuite(c)
 	watcher.Period = s.oldPeriod
+	watcher.OplogPeriod = s.oldOplogPeriod
 }
 
 func (s *watcherSuite) SetUpTest(c *gc.C) {
@@ -107,6 +116,46 @@ func (s *watcherSuite) TearDownTest(c *gc.C) {
 	s.BaseSuite.TearDownTest(c)
 }
 
+// setUpOplog creates a fake oplog and restarts the watcher
+// so that it tails it.
+func (s *watcherSuite) setUpOplog(c *gc.C) {
+	c.Assert(s.w.Stop(), gc.IsNil)
+	s.oplog = s.log.Database.C("oplog")
+	err := s.oplog.Create(&mgo.CollectionInfo{
+		Capped:   true,
+		MaxBytes: 1000000,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// Tailing needs an entry to start from.
+	s.addOplogEntry(c, "juju.other")
+	s.w = watcher.NewWithOplog(s.log, s.oplog)
+}
+
+// addOplogEntry records an insertion into the namespace
+// ns in the fake oplog.
+func (s *watcherSuite) addOplogEntry(c *gc.C, ns string) {
+	s.oplogTs++
+	err := s.oplog.Insert(bson.D{
+		{"ts", s.oplogTs},
+		{"op", "i"},
+		{"ns", ns},
+		{"o", bson.D{}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+// run runs the given transaction, recording the resulting
+// changelog entry in the fake oplog if there is one.
+func (s *watcherSuite) run(c *gc.C, ops []txn.Op) {
+	err := s.runner.Run(ops, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	if s.oplog != nil {
+		s.addOplogEntry(c, s.log.FullName)
+	}
+}
+
 type M map[string]interface{}
 
 func assertChange(c *gc.C, watch <-chan watcher.Change, want watcher.Change) {
@@ -151,10 +200,7 @@ func (s *watcherSuite) revno(c string, id interface{}) (revno int64) {
 
 func (s *watcherSuite) insert(c *gc.C, coll string, id interface{}) (revno int64) {
 	ops := []txn.Op{{C: coll, Id: id, Insert: M{"n": 1}}}
-	err := s.runner.Run(ops, "", nil)
-	if err != nil {
-		panic(err)
-	}
+	s.run(c, ops)
 	revno = s.revno(coll, id)
 	c.Logf("insert(%#v, %#v) => revno %d", coll, id, revno)
 	return revno
@@ -165,10 +211,7 @@ func (s *watcherSuite) insertAll(c *gc.C, coll string, ids ...interface{}) (revn
 	for _, id := range ids {
 		ops = append(ops, txn.Op{C: coll, Id: id, Insert: M{"n": 1}})
 	}
-	err := s.runner.Run(ops, "", nil)
-	if err != nil {
-		panic(err)
-	}
+	s.run(c, ops)
 	for _, id := range ids {
 		revnos = append(revnos, s.revno(coll, id))
 	}
@@ -178,10 +221,7 @@ func (s *watcherSuite) insertAll(c *gc.C, coll string, ids ...interface{}) (revn
 
 func (s *watcherSuite) update(c *gc.C, coll string, id interface{}) (revno int64) {
 	ops := []txn.Op{{C: coll, Id: id, Update: M{"$inc": M{"n": 1}}}}
-	err := s.runner.Run(ops, "", nil)
-	if err != nil {
-		panic(err)
-	}
+	s.run(c, ops)
 	revno = s.revno(coll, id)
 	c.Logf("update(%#v, %#v) => revno %d", coll, id, revno)
 	return revno
@@ -189,10 +229,7 @@ func (s *watcherSuite) update(c *gc.C, coll string, id interface{}) (revno int64
 
 func (s *watcherSuite) remove(c *gc.C, coll string, id interface{}) (revno int64) {
 	ops := []txn.Op{{C: coll, Id: id, Remove: true}}
-	err := s.runner.Run(ops, "", nil)
-	if err != nil {
-		panic(err)
-	}
+	s.run(c, ops)
 	c.Logf("remove(%#v, %#v) => revno -1", coll, id)
 	return -1
 }
@@ -369,8 +406,7 @@ func (s *FastPeriodSuite) TestScale(c *gc.C) {
 		for j := 0; j < T && i*T+j < N; j++ {
 			ops = append(ops, txn.Op{C: "test", Id: i*T + j, Insert: M{"n": 1}})
 		}
-		err := s.runner.Run(ops, "", nil)
-		c.Assert(err, jc.ErrorIsNil)
+		s.run(c, ops)
 	}
 
 	c.Logf("Watching all documents...")
@@ -704,3 +740,75 @@ func (s *SlowPeriodSuite) TestStartSyncStartsImmediately(c *gc.C) {
 	case <-time.After(justLongEnough):
 	}
 }
+
+// OplogCompatSuite runs the FastPeriodSuite tests against
+// a watcher tailing an oplog, to check that it behaves
+// just as the polling watcher does.
+type OplogCompatSuite struct {
+	FastPeriodSuite
+}
+
+var _ = gc.Suite(&OplogCompatSuite{})
+
+func (s *OplogCompatSuite) SetUpSuite(c *gc.C) {
+	s.FastPeriodSuite.SetUpSuite(c)
+	watcher.OplogPeriod = fastPeriod
+}
+
+func (s *OplogCompatSuite) SetUpTest(c *gc.C) {
+	s.FastPeriodSuite.SetUpTest(c)
+	s.setUpOplog(c)
+}
+
+// OplogSuite implements tests specific to
+// a watcher tailing an oplog.
+type OplogSuite struct {
+	watcherSuite
+}
+
+var _ = gc.Suite(&OplogSuite{})
+
+func (s *OplogSuite) SetUpSuite(c *gc.C) {
+	s.watcherSuite.SetUpSuite(c)
+	watcher.Period = fastPeriod
+	watcher.OplogPeriod = worstCase * 10
+}
+
+func (s *OplogSuite) TestChangeWithoutSync(c *gc.C) {
+	s.setUpOplog(c)
+	revno1 := s.insert(c, "test", "a")
+	s.w.Watch("test", "a", -1, s.ch)
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno1})
+
+	// The update is seen without a sync, long before
+	// the watcher would next poll the changelog.
+	revno2 := s.update(c, "test", "a")
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno2})
+	assertOrder(c, -1, revno1, revno2)
+}
+
+func (s *OplogSuite) TestIgnoresOtherNamespaces(c *gc.C) {
+	s.setUpOplog(c)
+	s.w.Watch("test", "a", -1, s.ch)
+	// Run the transaction without recording it, so the watcher
+	// only finds out about it if it syncs for other reasons.
+	oplog := s.oplog
+	s.oplog = nil
+	revno := s.insert(c, "test", "a")
+	s.oplog = oplog
+
+	s.addOplogEntry(c, "juju.other")
+	assertNoChange(c, s.ch)
+
+	s.w.StartSync()
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno})
+}
+
+func (s *OplogSuite) TestFallbackToPolling(c *gc.C) {
+	c.Assert(s.w.Stop(), gc.IsNil)
+	s.w = watcher.NewWithOplog(s.log, s.log.Database.C("no-such-oplog"))
+	revno := s.insert(c, "test", "a")
+	s.w.Watch("test", "a", -1, s.ch)
+	// Without an oplog, the watcher polls every Period.
+	assertChange(c, s.ch, watcher.Change{"test", "a", revno})
+}
@@ -80,3 +80,12 @@ func IsProviderSupported(envType string, providerType storage.ProviderType) bool
 	}
 	return false
 }
+
+// EnvironStorageProviders returns the storage provider types which are
+// valid for the given environment type.
+func EnvironStorageProviders(envType string) []storage.ProviderType {
+	providerTypes := supportedEnvironProviders[envType]
+	result := make([]storage.ProviderType, len(providerTypes))
+	copy(result, providerTypes)
+	return result
+}
@@ -78,3 +78,12 @@ func (s *providerRegistrySuite) TestRegisterEnvironProvidersMultipleCalls(c *gc.
 	c.Assert(registry.IsProviderSupported("ec2", ptypeFoo), jc.IsTrue)
 	c.Assert(registry.IsProviderSupported("ec2", ptypeBar), jc.IsTrue)
 }
+
+func (s *providerRegistrySuite) TestEnvironStorageProviders(c *gc.C) {
+	ptypeFoo := storage.ProviderType("foo")
+	registry.RegisterEnvironStorageProviders("someenv", ptypeFoo)
+	providerTypes := registry.EnvironStorageProviders("someenv")
+	c.Assert(providerTypes, gc.HasLen, len(provider.CommonProviders())+1)
+	c.Assert(providerTypes[0], gc.Equals, ptypeFoo)
+	c.Assert(registry.EnvironStorageProviders("noenv"), gc.HasLen, 0)
+}
@@ -0,0 +1,235 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package fakeprovider registers a programmable "fake" environ
+// provider for use in tests.
+//
+// Fake environs are backed by the dummy provider, so they can be
+// bootstrapped and used by feature tests in exactly the same way as
+// dummy environs. On top of that, tests may script the behaviour of
+// individual methods: calls can be made to block for a while, to fail
+// with a given error, or to be handled by a function supplied by the
+// test. The provider also registers a "fake" storage provider whose
+// volume sources keep their volumes in memory and can be scripted in
+// the same way.
+//
+// The scripted behaviour is global, and is cleared by Reset.
+package fakeprovider
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/storage/provider/registry"
+	"github.com/juju/juju/testing"
+)
+
+// ProviderType is the environ type of fake environs.
+const ProviderType = "fake"
+
+func init() {
+	dummyProvider, err := environs.Provider("dummy")
+	if err != nil {
+		panic(err)
+	}
+	providerInstance = &environProvider{dummyProvider}
+	environs.RegisterProvider(ProviderType, providerInstance)
+	registry.RegisterProvider(StorageProviderType, &storageProvider{})
+	registry.RegisterEnvironStorageProviders(ProviderType, StorageProviderType)
+	Reset()
+}
+
+// SampleConfig returns an environment configuration with all required
+// attributes set for a fake environ.
+func SampleConfig() testing.Attrs {
+	return dummy.SampleConfig().Merge(testing.Attrs{
+		"type": ProviderType,
+	})
+}
+
+// Method identifies a method of fake environs or volume sources whose
+// behaviour may be scripted.
+type Method string
+
+const (
+	StartInstance  Method = "StartInstance"
+	StopInstances  Method = "StopInstances"
+	CreateVolumes  Method = "CreateVolumes"
+	DestroyVolumes Method = "DestroyVolumes"
+	AttachVolumes  Method = "AttachVolumes"
+	DetachVolumes  Method = "DetachVolumes"
+)
+
+// Call describes the behaviour of a single call to a scripted method.
+type Call struct {
+	// Delay is how long the call blocks before doing anything else.
+	Delay time.Duration
+
+	// Err, if not nil, is returned by the call, which then has no
+	// other effect.
+	Err error
+}
+
+// StartInstanceFunc handles a call to StartInstance on a fake environ.
+// The dummy environ the fake environ is backed by is passed in, so the
+// function may fall back to it.
+type StartInstanceFunc func(env environs.Environ, args environs.StartInstanceParams) (*environs.StartInstanceResult, error)
+
+// StopInstancesFunc handles a call to StopInstances on a fake environ.
+// The dummy environ the fake environ is backed by is passed in, so the
+// function may fall back to it.
+type StopInstancesFunc func(env environs.Environ, ids ...instance.Id) error
+
+// script holds the scripted behaviour of all fake environs and volume
+// sources.
+var script struct {
+	mu            sync.Mutex
+	calls         map[Method][]Call
+	latency       map[Method]time.Duration
+	startInstance StartInstanceFunc
+	stopInstances StopInstancesFunc
+}
+
+// Reset clears all scripted behaviour, and forgets all volumes created
+// by fake volume sources. It does not reset the dummy environs backing
+// fake environs; use dummy.Reset for that.
+func Reset() {
+	script.mu.Lock()
+	script.calls = make(map[Method][]Call)
+	script.latency = make(map[Method]time.Duration)
+	script.startInstance = nil
+	script.stopInstances = nil
+	script.mu.Unlock()
+	resetVolumes()
+}
+
+// Script queues the given calls for the method. Each call to the
+// method takes the next call from the queue and behaves as described
+// by it; once the queue is empty, the method behaves normally again.
+func Script(method Method, calls ...Call) {
+	script.mu.Lock()
+	defer script.mu.Unlock()
+	script.calls[method] = append(script.calls[method], calls...)
+}
+
+// SetLatency causes every call to the method that has not been
+// scripted with Script to block for the given duration.
+func SetLatency(method Method, latency time.Duration) {
+	script.mu.Lock()
+	defer script.mu.Unlock()
+	script.latency[method] = latency
+}
+
+// SetStartInstance causes calls to StartInstance on fake environs to
+// be handled by f rather than the dummy environ, after any scripted
+// delay or error. Passing nil restores the default behaviour.
+func SetStartInstance(f StartInstanceFunc) {
+	script.mu.Lock()
+	defer script.mu.Unlock()
+	script.startInstance = f
+}
+
+// SetStopInstances causes calls to StopInstances on fake environs to
+// be handled by f rather than the dummy environ, after any scripted
+// delay or error. Passing nil restores the default behaviour.
+func SetStopInstances(f StopInstancesFunc) {
+	script.mu.Lock()
+	defer script.mu.Unlock()
+	script.stopInstances = f
+}
+
+// call applies the scripted behaviour for the next call to the method,
+// returning the error, if any, the call should fail with.
+func call(method Method) error {
+	script.mu.Lock()
+	next := Call{Delay: script.latency[method]}
+	if queued := script.calls[method]; len(queued) > 0 {
+		next = queued[0]
+		script.calls[method] = queued[1:]
+	}
+	script.mu.Unlock()
+	if next.Delay > 0 {
+		time.Sleep(next.Delay)
+	}
+	return next.Err
+}
+
+var providerInstance *environProvider
+
+// environProvider is the provider of fake environs. It defers to the
+// dummy provider for everything but opening environs.
+type environProvider struct {
+	environs.EnvironProvider
+}
+
+// PrepareForBootstrap is specified in the EnvironProvider interface.
+func (p *environProvider) PrepareForBootstrap(ctx environs.BootstrapContext, cfg *config.Config) (environs.Environ, error) {
+	env, err := p.EnvironProvider.PrepareForBootstrap(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &environ{env}, nil
+}
+
+// Open is specified in the EnvironProvider interface.
+func (p *environProvider) Open(cfg *config.Config) (environs.Environ, error) {
+	env, err := p.EnvironProvider.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &environ{env}, nil
+}
+
+// BoilerplateConfig is specified in the EnvironProvider interface.
+func (p *environProvider) BoilerplateConfig() string {
+	return `
+# Fake configuration for fake provider.
+fake:
+    type: fake
+
+`[1:]
+}
+
+// environ is a fake environ, backed by a dummy environ.
+type environ struct {
+	environs.Environ
+}
+
+var _ environs.Environ = (*environ)(nil)
+
+// StartInstance is specified in the InstanceBroker interface.
+func (e *environ) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+	if err := call(StartInstance); err != nil {
+		return nil, err
+	}
+	script.mu.Lock()
+	f := script.startInstance
+	script.mu.Unlock()
+	if f != nil {
+		return f(e.Environ, args)
+	}
+	return e.Environ.StartInstance(args)
+}
+
+// StopInstances is specified in the InstanceBroker interface.
+func (e *environ) StopInstances(ids ...instance.Id) error {
+	if err := call(StopInstances); err != nil {
+		return err
+	}
+	script.mu.Lock()
+	f := script.stopInstances
+	script.mu.Unlock()
+	if f != nil {
+		return f(e.Environ, ids...)
+	}
+	return e.Environ.StopInstances(ids...)
+}
+
+// Provider is specified in the Environ interface.
+func (e *environ) Provider() environs.EnvironProvider {
+	return providerInstance
+}
@@ -0,0 +1,160 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package fakeprovider_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/configstore"
+	envtesting "github.com/juju/juju/environs/testing"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider/registry"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/fakeprovider"
+)
+
+type fakeProviderSuite struct {
+	testing.FakeJujuHomeSuite
+	env environs.Environ
+}
+
+var _ = gc.Suite(&fakeProviderSuite{})
+
+func (s *fakeProviderSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.AddCleanup(func(*gc.C) {
+		fakeprovider.Reset()
+		dummy.Reset()
+	})
+	attrs := fakeprovider.SampleConfig().Merge(testing.Attrs{"state-server": false})
+	cfg, err := config.New(config.NoDefaults, attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	s.env, err = environs.Prepare(cfg, envtesting.BootstrapContext(c), configstore.NewMem())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *fakeProviderSuite) TestProvider(c *gc.C) {
+	p, err := environs.Provider(fakeprovider.ProviderType)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.env.Provider(), gc.Equals, p)
+	c.Assert(s.env.Config().Type(), gc.Equals, fakeprovider.ProviderType)
+	c.Assert(registry.IsProviderSupported(fakeprovider.ProviderType, fakeprovider.StorageProviderType), jc.IsTrue)
+}
+
+func (s *fakeProviderSuite) TestScriptedErrors(c *gc.C) {
+	fakeprovider.Script(fakeprovider.StartInstance,
+		fakeprovider.Call{Err: errors.New("first")},
+		fakeprovider.Call{Err: errors.New("second")},
+	)
+	_, err := s.env.StartInstance(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, "first")
+	_, err = s.env.StartInstance(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, "second")
+}
+
+func (s *fakeProviderSuite) TestScriptedDelay(c *gc.C) {
+	fakeprovider.Script(fakeprovider.StopInstances, fakeprovider.Call{
+		Delay: 50 * time.Millisecond,
+		Err:   errors.New("slow failure"),
+	})
+	start := time.Now()
+	err := s.env.StopInstances("inst-0")
+	c.Assert(err, gc.ErrorMatches, "slow failure")
+	c.Assert(time.Since(start) >= 50*time.Millisecond, jc.IsTrue)
+}
+
+func (s *fakeProviderSuite) TestSetStartInstance(c *gc.C) {
+	var called bool
+	fakeprovider.SetStartInstance(func(env environs.Environ, args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+		called = true
+		c.Assert(env.Config().Type(), gc.Equals, fakeprovider.ProviderType)
+		return nil, errors.New("no capacity")
+	})
+	_, err := s.env.StartInstance(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, "no capacity")
+	c.Assert(called, jc.IsTrue)
+
+	fakeprovider.Reset()
+	fakeprovider.Script(fakeprovider.StartInstance, fakeprovider.Call{Err: errors.New("scripted")})
+	_, err = s.env.StartInstance(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, "scripted")
+}
+
+func (s *fakeProviderSuite) TestSetStopInstances(c *gc.C) {
+	var stopped []instance.Id
+	fakeprovider.SetStopInstances(func(env environs.Environ, ids ...instance.Id) error {
+		stopped = append(stopped, ids...)
+		return nil
+	})
+	err := s.env.StopInstances("inst-0", "inst-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stopped, jc.DeepEquals, []instance.Id{"inst-0", "inst-1"})
+}
+
+func (s *fakeProviderSuite) volumeSource(c *gc.C) storage.VolumeSource {
+	p, err := registry.StorageProvider(fakeprovider.StorageProviderType)
+	c.Assert(err, jc.ErrorIsNil)
+	source, err := p.VolumeSource(s.env.Config(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return source
+}
+
+func (s *fakeProviderSuite) TestVolumes(c *gc.C) {
+	source := s.volumeSource(c)
+	volumes, attachments, err := source.CreateVolumes([]storage.VolumeParams{{
+		Tag:  names.NewVolumeTag("0"),
+		Size: 1024,
+		Attachment: &storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Machine: names.NewMachineTag("1"),
+			},
+		},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumes, jc.DeepEquals, []storage.Volume{{
+		Tag:      names.NewVolumeTag("0"),
+		VolumeId: "fake-1",
+		Size:     1024,
+	}})
+	c.Assert(attachments, jc.DeepEquals, []storage.VolumeAttachment{{
+		Volume:     names.NewVolumeTag("0"),
+		Machine:    names.NewMachineTag("1"),
+		DeviceName: "fake-1",
+	}})
+	c.Assert(fakeprovider.Volumes(), gc.HasLen, 1)
+
+	described, err := source.DescribeVolumes([]string{"fake-1"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(described, jc.DeepEquals, volumes)
+
+	errs := source.DestroyVolumes([]string{"fake-1", "fake-2"})
+	c.Assert(errs[0], jc.ErrorIsNil)
+	c.Assert(errs[1], jc.Satisfies, errors.IsNotFound)
+	c.Assert(fakeprovider.Volumes(), gc.HasLen, 0)
+}
+
+func (s *fakeProviderSuite) TestScriptedVolumeErrors(c *gc.C) {
+	source := s.volumeSource(c)
+	fakeprovider.Script(fakeprovider.CreateVolumes, fakeprovider.Call{Err: errors.New("quota exceeded")})
+	fakeprovider.Script(fakeprovider.DestroyVolumes, fakeprovider.Call{Err: errors.New("busy")})
+
+	_, _, err := source.CreateVolumes([]storage.VolumeParams{{Tag: names.NewVolumeTag("0")}})
+	c.Assert(err, gc.ErrorMatches, "quota exceeded")
+	c.Assert(fakeprovider.Volumes(), gc.HasLen, 0)
+
+	volumes, _, err := source.CreateVolumes([]storage.VolumeParams{{Tag: names.NewVolumeTag("0")}})
+	c.Assert(err, jc.ErrorIsNil)
+	errs := source.DestroyVolumes([]string{volumes[0].VolumeId})
+	c.Assert(errs[0], gc.ErrorMatches, "busy")
+	c.Assert(fakeprovider.Volumes(), gc.HasLen, 1)
+}
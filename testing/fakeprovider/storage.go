@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package fakeprovider
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/storage"
+)
+
+// StorageProviderType is the type of the storage provider registered
+// for fake environs.
+const StorageProviderType = storage.ProviderType("fake")
+
+// volumes holds the volumes created by all fake volume sources.
+var volumes struct {
+	mu     sync.Mutex
+	nextId int
+	byId   map[string]storage.Volume
+}
+
+func resetVolumes() {
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	volumes.nextId = 0
+	volumes.byId = make(map[string]storage.Volume)
+}
+
+// Volumes returns the volumes that currently exist in fake volume
+// sources.
+func Volumes() []storage.Volume {
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	result := make([]storage.Volume, 0, len(volumes.byId))
+	for _, v := range volumes.byId {
+		result = append(result, v)
+	}
+	return result
+}
+
+// storageProvider creates fake volume sources.
+type storageProvider struct{}
+
+var _ storage.Provider = (*storageProvider)(nil)
+
+// ValidateConfig is defined on the Provider interface.
+func (*storageProvider) ValidateConfig(cfg *storage.Config) error {
+	return nil
+}
+
+// VolumeSource is defined on the Provider interface.
+func (*storageProvider) VolumeSource(environConfig *config.Config, sourceConfig *storage.Config) (storage.VolumeSource, error) {
+	return volumeSource{}, nil
+}
+
+// FilesystemSource is defined on the Provider interface.
+func (*storageProvider) FilesystemSource(environConfig *config.Config, providerConfig *storage.Config) (storage.FilesystemSource, error) {
+	return nil, errors.NotSupportedf("filesystems")
+}
+
+// Supports is defined on the Provider interface.
+func (*storageProvider) Supports(k storage.StorageKind) bool {
+	return k == storage.StorageKindBlock
+}
+
+// volumeSource is a fake volume source, whose volumes are kept in
+// memory.
+type volumeSource struct{}
+
+var _ storage.VolumeSource = volumeSource{}
+
+// CreateVolumes is defined on the VolumeSource interface.
+func (volumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.Volume, []storage.VolumeAttachment, error) {
+	if err := call(CreateVolumes); err != nil {
+		return nil, nil, err
+	}
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	var created []storage.Volume
+	var attachments []storage.VolumeAttachment
+	for _, p := range params {
+		volumes.nextId++
+		v := storage.Volume{
+			Tag:      p.Tag,
+			VolumeId: fmt.Sprintf("fake-%d", volumes.nextId),
+			Size:     p.Size,
+		}
+		volumes.byId[v.VolumeId] = v
+		created = append(created, v)
+		if p.Attachment != nil {
+			attachments = append(attachments, attachment(p.Attachment.Machine, v))
+		}
+	}
+	return created, attachments, nil
+}
+
+// DescribeVolumes is defined on the VolumeSource interface.
+func (volumeSource) DescribeVolumes(volIds []string) ([]storage.Volume, error) {
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	result := make([]storage.Volume, len(volIds))
+	for i, id := range volIds {
+		v, ok := volumes.byId[id]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", id)
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// DestroyVolumes is defined on the VolumeSource interface.
+func (volumeSource) DestroyVolumes(volIds []string) []error {
+	errs := make([]error, len(volIds))
+	if err := call(DestroyVolumes); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	for i, id := range volIds {
+		if _, ok := volumes.byId[id]; !ok {
+			errs[i] = errors.NotFoundf("volume %q", id)
+			continue
+		}
+		delete(volumes.byId, id)
+	}
+	return errs
+}
+
+// ValidateVolumeParams is defined on the VolumeSource interface.
+func (volumeSource) ValidateVolumeParams(params storage.VolumeParams) error {
+	return nil
+}
+
+// AttachVolumes is defined on the VolumeSource interface.
+func (volumeSource) AttachVolumes(params []storage.VolumeAttachmentParams) ([]storage.VolumeAttachment, error) {
+	if err := call(AttachVolumes); err != nil {
+		return nil, err
+	}
+	volumes.mu.Lock()
+	defer volumes.mu.Unlock()
+	result := make([]storage.VolumeAttachment, len(params))
+	for i, p := range params {
+		v, ok := volumes.byId[p.VolumeId]
+		if !ok {
+			return nil, errors.NotFoundf("volume %q", p.VolumeId)
+		}
+		result[i] = attachment(p.Machine, v)
+	}
+	return result, nil
+}
+
+// DetachVolumes is defined on the VolumeSource interface.
+func (volumeSource) DetachVolumes(params []storage.VolumeAttachmentParams) error {
+	return call(DetachVolumes)
+}
+
+func attachment(machine names.MachineTag, v storage.Volume) storage.VolumeAttachment {
+	return storage.VolumeAttachment{
+		Volume:     v.Tag,
+		Machine:    machine,
+		DeviceName: v.VolumeId,
+	}
+}
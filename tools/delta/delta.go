@@ -0,0 +1,264 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The delta package computes and applies binary deltas between the
+// contents of two tools tarballs, so that an agent upgrading between
+// patch releases need only download the parts of the new binaries
+// which differ from the ones it is already running.
+package delta
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ContentType is the MIME type used when serving a tools delta.
+const ContentType = "application/x-juju-tools-delta"
+
+// blockSize is the size of the blocks of the old files which are
+// matched against the contents of the new ones.
+const blockSize = 4096
+
+// Delta describes how to construct the files in a tools tarball
+// from the unpacked files of another version of the tools.
+type Delta struct {
+	Files []File
+}
+
+// File describes how to construct a single file.
+type File struct {
+	Name   string
+	Mode   int64
+	SHA256 string
+	Ops    []Op
+}
+
+// Op is a single step in constructing a file. If Data is nil, the op
+// copies Length bytes starting at Offset from the old version of the
+// file; otherwise Data is written verbatim.
+type Op struct {
+	Offset int64
+	Length int64
+	Data   []byte
+}
+
+type tarFile struct {
+	name string
+	mode int64
+	data []byte
+}
+
+// Compute returns the delta which, when applied to the unpacked
+// contents of the oldTarball, produces the contents of newTarball.
+// Both tarballs must be gzipped tar archives in the format used for
+// tools.
+func Compute(oldTarball, newTarball io.Reader) (*Delta, error) {
+	oldFiles, err := readTarball(oldTarball)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read old tools")
+	}
+	newFiles, err := readTarball(newTarball)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read new tools")
+	}
+	old := make(map[string][]byte)
+	for _, f := range oldFiles {
+		old[f.name] = f.data
+	}
+	d := &Delta{}
+	for _, f := range newFiles {
+		d.Files = append(d.Files, File{
+			Name:   f.name,
+			Mode:   f.mode,
+			SHA256: hashOf(f.data),
+			Ops:    diff(old[f.name], f.data),
+		})
+	}
+	return d, nil
+}
+
+// Write writes the delta to w in the format expected by Read.
+func (d *Delta) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := gob.NewEncoder(zw).Encode(d); err != nil {
+		return errors.Trace(err)
+	}
+	return zw.Close()
+}
+
+// Read reads a delta written by Write.
+func Read(r io.Reader) (*Delta, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer zr.Close()
+	var d Delta
+	if err := gob.NewDecoder(zr).Decode(&d); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &d, nil
+}
+
+// Apply creates the files described by the delta in newDir, using
+// the files in oldDir as the source of any unchanged data.
+func (d *Delta) Apply(oldDir, newDir string) error {
+	for _, f := range d.Files {
+		if f.Name == "" || strings.ContainsAny(f.Name, "/\\") {
+			return errors.Errorf("bad name %q in tools delta", f.Name)
+		}
+		old, err := ioutil.ReadFile(filepath.Join(oldDir, f.Name))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		data, err := patch(old, f.Ops)
+		if err != nil {
+			return errors.Annotatef(err, "cannot construct %q", f.Name)
+		}
+		if sha256 := hashOf(data); sha256 != f.SHA256 {
+			return errors.Errorf("%q sha256 mismatch, expected %s, got %s", f.Name, f.SHA256, sha256)
+		}
+		name := filepath.Join(newDir, f.Name)
+		if err := ioutil.WriteFile(name, data, os.FileMode(f.Mode&0777)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// readTarball returns the files in the given gzipped tar archive.
+func readTarball(r io.Reader) ([]tarFile, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer zr.Close()
+	var files []tarFile
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if strings.ContainsAny(hdr.Name, "/\\") {
+			return nil, errors.Errorf("bad name %q in tools archive", hdr.Name)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, errors.Errorf("bad file type %c in file %q in tools archive", hdr.Typeflag, hdr.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		files = append(files, tarFile{hdr.Name, hdr.Mode, data})
+	}
+	return files, nil
+}
+
+func hashOf(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// weakSum returns the rolling checksum of the given block.
+func weakSum(block []byte) (a, b uint32) {
+	for i, c := range block {
+		a += uint32(c)
+		b += uint32(len(block)-i) * uint32(c)
+	}
+	return a & 0xffff, b & 0xffff
+}
+
+// diff returns the ops which construct new from old. Every
+// blockSize-aligned block of old is indexed by its rolling checksum,
+// and new is scanned one byte at a time for matching blocks, in the
+// manner of rsync.
+func diff(old, new []byte) []Op {
+	index := make(map[uint32][]int64)
+	for off := 0; off+blockSize <= len(old); off += blockSize {
+		a, b := weakSum(old[off : off+blockSize])
+		key := a | b<<16
+		index[key] = append(index[key], int64(off))
+	}
+	var ops []Op
+	addLiteral := func(data []byte) {
+		if len(data) > 0 {
+			ops = append(ops, Op{Length: int64(len(data)), Data: data})
+		}
+	}
+	addCopy := func(offset int64) {
+		if n := len(ops); n > 0 && ops[n-1].Data == nil && ops[n-1].Offset+ops[n-1].Length == offset {
+			ops[n-1].Length += blockSize
+			return
+		}
+		ops = append(ops, Op{Offset: offset, Length: blockSize})
+	}
+	if len(index) == 0 || len(new) < blockSize {
+		addLiteral(new)
+		return ops
+	}
+
+	start := 0
+	a, b := weakSum(new[:blockSize])
+	for i := 0; i+blockSize <= len(new); {
+		block := new[i : i+blockSize]
+		if offset, ok := findBlock(old, index[a|b<<16], block); ok {
+			addLiteral(new[start:i])
+			addCopy(offset)
+			i += blockSize
+			start = i
+			if i+blockSize <= len(new) {
+				a, b = weakSum(new[i : i+blockSize])
+			}
+			continue
+		}
+		if i+blockSize < len(new) {
+			out, in := uint32(new[i]), uint32(new[i+blockSize])
+			a = (a - out + in) & 0xffff
+			b = (b - blockSize*out + a) & 0xffff
+		}
+		i++
+	}
+	addLiteral(new[start:])
+	return ops
+}
+
+// findBlock returns the offset of the first of the candidate blocks
+// of old which matches the given block.
+func findBlock(old []byte, candidates []int64, block []byte) (int64, bool) {
+	for _, offset := range candidates {
+		if bytes.Equal(old[offset:offset+blockSize], block) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// patch applies the given ops to old.
+func patch(old []byte, ops []Op) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, op := range ops {
+		if op.Data != nil {
+			buf.Write(op.Data)
+			continue
+		}
+		if op.Offset < 0 || op.Length < 0 || op.Offset+op.Length > int64(len(old)) {
+			return nil, errors.Errorf("copy of %d bytes at offset %d out of range", op.Length, op.Offset)
+		}
+		buf.Write(old[op.Offset : op.Offset+op.Length])
+	}
+	return buf.Bytes(), nil
+}
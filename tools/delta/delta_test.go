@@ -0,0 +1,123 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package delta_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/tools/delta"
+)
+
+type deltaSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&deltaSuite{})
+
+func randomContents(n int) string {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(0)).Read(data)
+	return string(data)
+}
+
+func (s *deltaSuite) writeFiles(c *gc.C, files ...*testing.TarFile) string {
+	dir := c.MkDir()
+	for _, f := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, f.Header.Name), []byte(f.Contents), os.FileMode(f.Header.Mode))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return dir
+}
+
+func (s *deltaSuite) roundTrip(c *gc.C, oldFiles, newFiles []*testing.TarFile) (string, int) {
+	oldTarball, _ := testing.TarGz(oldFiles...)
+	newTarball, _ := testing.TarGz(newFiles...)
+	d, err := delta.Compute(bytes.NewReader(oldTarball), bytes.NewReader(newTarball))
+	c.Assert(err, jc.ErrorIsNil)
+	var buf bytes.Buffer
+	err = d.Write(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+	size := buf.Len()
+
+	d, err = delta.Read(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+	newDir := c.MkDir()
+	err = d.Apply(s.writeFiles(c, oldFiles...), newDir)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, f := range newFiles {
+		data, err := ioutil.ReadFile(filepath.Join(newDir, f.Header.Name))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(data) == f.Contents, jc.IsTrue)
+		info, err := os.Stat(filepath.Join(newDir, f.Header.Name))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(info.Mode().Perm(), gc.Equals, os.FileMode(f.Header.Mode))
+	}
+	return newDir, size
+}
+
+func (s *deltaSuite) TestDeltaSmallerThanChangedBinary(c *gc.C) {
+	contents := randomContents(1 << 20)
+	changed := contents[:300000] + "some new code" + contents[300000:700000] + contents[710000:]
+	_, size := s.roundTrip(c,
+		[]*testing.TarFile{testing.NewTarFile("jujud", 0755, contents)},
+		[]*testing.TarFile{testing.NewTarFile("jujud", 0755, changed)},
+	)
+	c.Assert(size < 64*1024, jc.IsTrue, gc.Commentf("delta is %d bytes", size))
+}
+
+func (s *deltaSuite) TestDeltaWithAddedFile(c *gc.C) {
+	contents := randomContents(10000)
+	newDir, _ := s.roundTrip(c,
+		[]*testing.TarFile{testing.NewTarFile("jujud", 0755, contents)},
+		[]*testing.TarFile{
+			testing.NewTarFile("jujud", 0755, contents),
+			testing.NewTarFile("FORCE-VERSION", 0644, "1.25.1"),
+		},
+	)
+	data, err := ioutil.ReadFile(filepath.Join(newDir, "FORCE-VERSION"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "1.25.1")
+}
+
+func (s *deltaSuite) TestDeltaWithSmallFiles(c *gc.C) {
+	s.roundTrip(c,
+		[]*testing.TarFile{testing.NewTarFile("jujud", 0755, "old")},
+		[]*testing.TarFile{testing.NewTarFile("jujud", 0755, "new")},
+	)
+}
+
+func (s *deltaSuite) TestApplyVerifiesHash(c *gc.C) {
+	contents := randomContents(10000)
+	oldTarball, _ := testing.TarGz(testing.NewTarFile("jujud", 0755, contents))
+	newTarball, _ := testing.TarGz(testing.NewTarFile("jujud", 0755, contents+"more"))
+	d, err := delta.Compute(bytes.NewReader(oldTarball), bytes.NewReader(newTarball))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Apply the delta to files which differ from the ones it was
+	// computed against.
+	oldDir := s.writeFiles(c, testing.NewTarFile("jujud", 0755, strings.Repeat("x", 5000)+contents[5000:]))
+	err = d.Apply(oldDir, c.MkDir())
+	c.Assert(err, gc.ErrorMatches, `"jujud" sha256 mismatch, expected .*, got .*`)
+}
+
+func (s *deltaSuite) TestApplyRejectsBadNames(c *gc.C) {
+	d := &delta.Delta{Files: []delta.File{{Name: "../jujud"}}}
+	err := d.Apply(c.MkDir(), c.MkDir())
+	c.Assert(err, gc.ErrorMatches, `bad name "../jujud" in tools delta`)
+}
+
+func (s *deltaSuite) TestComputeRejectsBadTarball(c *gc.C) {
+	newTarball, _ := testing.TarGz(testing.NewTarFile("jujud", 0755, "new"))
+	_, err := delta.Compute(bytes.NewReader([]byte("nonsense")), bytes.NewReader(newTarball))
+	c.Assert(err, gc.ErrorMatches, "cannot read old tools: .*")
+}
@@ -57,6 +57,12 @@ func stateStepsFor123() []Step {
 			run: func(context Context) error {
 				return state.AddNameFieldLowerCaseIdOfUsers(context.State())
 			},
+		}, &upgradeStep{
+			description: "add charm ref counts",
+			targets:     []Target{DatabaseMaster},
+			run: func(context Context) error {
+				return state.AddCharmRefCounts(context.State())
+			},
 		},
 	)
 	return steps
@@ -29,6 +29,7 @@ func (s *steps123Suite) TestStateStepsFor123(c *gc.C) {
 		"move blocks from environment to state",
 		"insert userenvnameC doc for each environment",
 		"add name field to users and lowercase _id field",
+		"add charm ref counts",
 	}
 	assertStateSteps(c, version.MustParse("1.23.0"), expected)
 }
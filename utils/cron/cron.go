@@ -0,0 +1,216 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package cron parses cron-style schedule expressions and computes
+// the times at which they fire.
+//
+// An expression has five space-separated fields: minute (0-59), hour
+// (0-23), day of month (1-31), month (1-12 or jan-dec) and day of
+// week (0-6 or sun-sat, with 7 also meaning Sunday). Each field may
+// be "*", a value, a range "a-b", or a comma-separated list of those,
+// and any "*" or range may be followed by "/n" to select every nth
+// value. As with cron(8), when both the day of month and the day of
+// week are restricted, a time matches if either of them does.
+//
+// The descriptors @yearly (or @annually), @monthly, @weekly, @daily
+// (or @midnight) and @hourly are also accepted.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// searchYears bounds the search for the next matching time, so that
+// expressions which can never match (such as "0 0 30 2 *") terminate.
+const searchYears = 5
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	dowField    = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// bits holds the set of values matched by a field.
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr    string
+	minute  bits
+	hour    bits
+	dom     bits
+	month   bits
+	dow     bits
+	domStar bool
+	dowStar bool
+}
+
+// Parse parses the given cron expression.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if spec, ok = descriptors[strings.ToLower(spec)]; !ok {
+			return nil, errors.NotValidf("cron descriptor %q", expr)
+		}
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+	s := &Schedule{
+		expr:    expr,
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, target := range []struct {
+		bits  *bits
+		field field
+	}{
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	} {
+		if *target.bits, err = parseField(fields[i], target.field); err != nil {
+			return nil, errors.Annotatef(err, "cannot parse cron expression %q", expr)
+		}
+	}
+	// Sunday may be written as either 0 or 7.
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the earliest time, strictly after t and to the minute,
+// at which the schedule fires, in t's location. It returns the zero
+// time if the schedule does not fire within the next few years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.Year() + searchYears
+	for t.Year() <= limit {
+		switch {
+		case !s.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !s.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !s.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom.has(t.Day())
+	dowMatch := s.dow.has(int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField returns the set of values matched by a single field.
+func parseField(text string, f field) (bits, error) {
+	var result bits
+	for _, part := range strings.Split(text, ",") {
+		rangeText, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, errors.Errorf("invalid step %q in %s field", part[i+1:], f.name)
+			}
+			rangeText, step = part[:i], n
+		}
+		var lo, hi int
+		switch {
+		case rangeText == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rangeText, "-"):
+			bounds := strings.SplitN(rangeText, "-", 2)
+			var err error
+			if lo, err = f.parseValue(bounds[0]); err != nil {
+				return 0, errors.Trace(err)
+			}
+			if hi, err = f.parseValue(bounds[1]); err != nil {
+				return 0, errors.Trace(err)
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range %q in %s field", rangeText, f.name)
+			}
+		default:
+			if step != 1 {
+				return 0, errors.Errorf("step given without range in %s field", f.name)
+			}
+			v, err := f.parseValue(rangeText)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			lo, hi = v, v
+		}
+		for v := lo; v <= hi; v += step {
+			result |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+func (f field) parseValue(text string) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid value %q in %s field", text, f.name)
+	}
+	return v, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cron_test
+
+import (
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/utils/cron"
+)
+
+type cronSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&cronSuite{})
+
+// from is a Wednesday.
+var from = time.Date(2015, 7, 15, 10, 30, 45, 0, time.UTC)
+
+var nextTests = []struct {
+	expr string
+	next time.Time
+}{{
+	expr: "* * * * *",
+	next: time.Date(2015, 7, 15, 10, 31, 0, 0, time.UTC),
+}, {
+	expr: "30 * * * *",
+	next: time.Date(2015, 7, 15, 11, 30, 0, 0, time.UTC),
+}, {
+	expr: "*/15 * * * *",
+	next: time.Date(2015, 7, 15, 10, 45, 0, 0, time.UTC),
+}, {
+	expr: "0 9-17/4 * * *",
+	next: time.Date(2015, 7, 15, 13, 0, 0, 0, time.UTC),
+}, {
+	expr: "0,20 8 * * *",
+	next: time.Date(2015, 7, 16, 8, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 1 * *",
+	next: time.Date(2015, 8, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 * * sun",
+	next: time.Date(2015, 7, 19, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 * * 7",
+	next: time.Date(2015, 7, 19, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 * jan mon-fri",
+	next: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	// Either the day of month or the day of week may match.
+	expr: "0 0 20 * fri",
+	next: time.Date(2015, 7, 17, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 29 2 *",
+	next: time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "@daily",
+	next: time.Date(2015, 7, 16, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "@hourly",
+	next: time.Date(2015, 7, 15, 11, 0, 0, 0, time.UTC),
+}, {
+	expr: "@yearly",
+	next: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+}, {
+	expr: "0 0 30 2 *",
+}}
+
+func (s *cronSuite) TestNext(c *gc.C) {
+	for i, test := range nextTests {
+		c.Logf("test %d: %q", i, test.expr)
+		sched, err := cron.Parse(test.expr)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(sched.String(), gc.Equals, test.expr)
+		c.Check(sched.Next(from), gc.DeepEquals, test.next)
+	}
+}
+
+func (s *cronSuite) TestNextIsStrictlyAfter(c *gc.C) {
+	sched, err := cron.Parse("30 10 * * *")
+	c.Assert(err, jc.ErrorIsNil)
+	at := time.Date(2015, 7, 15, 10, 30, 0, 0, time.UTC)
+	c.Assert(sched.Next(at), gc.DeepEquals, at.AddDate(0, 0, 1))
+}
+
+var parseErrorTests = []struct {
+	expr string
+	err  string
+}{{
+	expr: "* * * *",
+	err:  `cron expression "\* \* \* \*" must have 5 fields, got 4`,
+}, {
+	expr: "@fortnightly",
+	err:  `cron descriptor "@fortnightly" not valid`,
+}, {
+	expr: "60 * * * *",
+	err:  `cannot parse cron expression "60 \* \* \* \*": invalid value "60" in minute field`,
+}, {
+	expr: "* * 0 * *",
+	err:  `.*invalid value "0" in day of month field`,
+}, {
+	expr: "* * * foo *",
+	err:  `.*invalid value "foo" in month field`,
+}, {
+	expr: "* 5-1 * * *",
+	err:  `.*invalid range "5-1" in hour field`,
+}, {
+	expr: "*/0 * * * *",
+	err:  `.*invalid step "0" in minute field`,
+}, {
+	expr: "5/2 * * * *",
+	err:  `.*step given without range in minute field`,
+}}
+
+func (s *cronSuite) TestParseErrors(c *gc.C) {
+	for i, test := range parseErrorTests {
+		c.Logf("test %d: %q", i, test.expr)
+		_, err := cron.Parse(test.expr)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -36,6 +36,8 @@ type Options struct {
 	// knownHostsFile is a path to a file in which to save the host's
 	// fingerprint.
 	knownHostsFile string
+	// strictHostKeyChecking requires the host's key to be known.
+	strictHostKeyChecking bool
 }
 
 // SetProxyCommand sets a command to execute to proxy traffic through.
@@ -63,6 +65,15 @@ func (o *Options) SetKnownHostsFile(file string) {
 	o.knownHostsFile = file
 }
 
+// EnableStrictHostKeyChecking requires the host's key to be listed in
+// the known hosts file, refusing to connect to hosts with unknown or
+// changed keys.
+//
+// Host keys are not checked by default.
+func (o *Options) EnableStrictHostKeyChecking() {
+	o.strictHostKeyChecking = true
+}
+
 // AllowPasswordAuthentication allows the SSH
 // client to prompt the user for a password.
 //
@@ -64,10 +64,13 @@ func NewOpenSSHClient() (*OpenSSHClient, error) {
 }
 
 func opensshOptions(options *Options, commandKind opensshCommandKind) []string {
-	args := append([]string{}, opensshCommonOptions...)
 	if options == nil {
 		options = &Options{}
 	}
+	args := append([]string{}, opensshCommonOptions...)
+	if options.strictHostKeyChecking {
+		args = []string{"-o", "StrictHostKeyChecking yes"}
+	}
 	if len(options.proxyCommand) > 0 {
 		args = append(args, "-o", "ProxyCommand "+utils.CommandString(options.proxyCommand...))
 	}
@@ -29,7 +29,7 @@ func (*CurrentSuite) TestCurrentSeries(c *gc.C) {
 		case "darwin":
 			c.Check(s, gc.Matches, `mavericks|mountainlion|lion|snowleopard`)
 		case "windows":
-			c.Check(s, gc.Matches, `win2012hvr2|win2012hv|win2012|win2012r2|win8|win81|win7`)
+			c.Check(s, gc.Matches, `win2016|win2012hvr2|win2012hv|win2012|win2012r2|win8|win81|win7`)
 		default:
 			c.Assert(s, gc.Equals, "n/a")
 		}
@@ -49,6 +49,7 @@ var seriesVersions = map[string]string{
 	"trusty":      "14.04",
 	"utopic":      "14.10",
 	"vivid":       "15.04",
+	"win2016":     "win2016",
 	"win2012hvr2": "win2012hvr2",
 	"win2012hv":   "win2012hv",
 	"win2012r2":   "win2012r2",
@@ -82,6 +83,7 @@ var ubuntuSeries = map[string]string{
 // TODO: Replace this with actuall full names once we compile a complete
 // list with al flavors
 var windowsVersions = map[string]string{
+	"Windows Server 2016":            "win2016",
 	"Hyper-V Server 2012 R2":         "win2012hvr2",
 	"Hyper-V Server 2012":            "win2012hv",
 	"Windows Server 2012 R2":         "win2012r2",
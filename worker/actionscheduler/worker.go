@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package actionscheduler provides a worker which queues scheduled
+// actions when they fall due.
+package actionscheduler
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.actionscheduler")
+
+// retryDelay is how long the worker waits before dispatching again
+// when schedules remain due after being dispatched.
+var retryDelay = 10 * time.Second
+
+// ActionSchedulerAPI dispatches action schedules which are due, and
+// notifies of changes to them.
+type ActionSchedulerAPI interface {
+	WatchActionSchedules() (apiwatcher.NotifyWatcher, error)
+	DispatchDue() (params.ActionScheduleDispatchResult, error)
+}
+
+// New returns a worker which dispatches action schedules as they fall
+// due, and whenever they change.
+func New(api ActionSchedulerAPI) worker.Worker {
+	s := &scheduler{api: api}
+	go func() {
+		defer s.tomb.Done()
+		s.tomb.Kill(s.loop())
+	}()
+	return s
+}
+
+type scheduler struct {
+	tomb tomb.Tomb
+	api  ActionSchedulerAPI
+}
+
+// Kill is part of the worker.Worker interface.
+func (s *scheduler) Kill() {
+	s.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (s *scheduler) Wait() error {
+	return s.tomb.Wait()
+}
+
+func (s *scheduler) loop() error {
+	w, err := s.api.WatchActionSchedules()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch action schedules")
+	}
+	defer watcher.Stop(w, &s.tomb)
+
+	var due <-chan time.Time
+	for {
+		select {
+		case <-s.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		case <-due:
+		}
+		result, err := s.api.DispatchDue()
+		if err != nil {
+			return errors.Annotate(err, "cannot dispatch scheduled actions")
+		}
+		for _, action := range result.Actions {
+			logger.Debugf("queued scheduled action %q", action.Tag)
+		}
+		due = nil
+		if !result.NextRun.IsZero() {
+			delay := result.NextRun.Sub(time.Now())
+			if delay <= 0 {
+				// Schedules which failed to dispatch are still due.
+				delay = retryDelay
+			}
+			due = time.After(delay)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package actionscheduler_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/actionscheduler"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+	api *mockAPI
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+func (s *workerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		changes:    make(chan struct{}, 1),
+		dispatched: make(chan struct{}, 10),
+	}
+}
+
+func (s *workerSuite) startWorker(c *gc.C) worker.Worker {
+	w := actionscheduler.New(s.api)
+	s.AddCleanup(func(c *gc.C) {
+		w.Kill()
+		c.Check(w.Wait(), jc.ErrorIsNil)
+	})
+	return w
+}
+
+func (s *workerSuite) assertDispatched(c *gc.C) {
+	select {
+	case <-s.api.dispatched:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for dispatch")
+	}
+}
+
+func (s *workerSuite) assertNotDispatched(c *gc.C) {
+	select {
+	case <-s.api.dispatched:
+		c.Fatalf("unexpected dispatch")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *workerSuite) TestDispatchesOnChange(c *gc.C) {
+	s.startWorker(c)
+	s.assertNotDispatched(c)
+
+	s.api.changes <- struct{}{}
+	s.assertDispatched(c)
+	s.assertNotDispatched(c)
+
+	s.api.changes <- struct{}{}
+	s.assertDispatched(c)
+}
+
+func (s *workerSuite) TestDispatchesWhenDue(c *gc.C) {
+	s.api.setNextRun(time.Now().Add(100 * time.Millisecond))
+	s.startWorker(c)
+
+	s.api.changes <- struct{}{}
+	s.assertDispatched(c)
+	s.api.setNextRun(time.Time{})
+	s.assertDispatched(c)
+	s.assertNotDispatched(c)
+}
+
+func (s *workerSuite) TestRetriesSchedulesStillDue(c *gc.C) {
+	s.PatchValue(actionscheduler.RetryDelay, 100*time.Millisecond)
+	s.api.setNextRun(time.Now().Add(-time.Minute))
+	s.startWorker(c)
+
+	s.api.changes <- struct{}{}
+	s.assertDispatched(c)
+	s.api.setNextRun(time.Time{})
+	s.assertDispatched(c)
+	s.assertNotDispatched(c)
+}
+
+func (s *workerSuite) TestDispatchError(c *gc.C) {
+	s.api.dispatchErr = errors.New("boom")
+	w := actionscheduler.New(s.api)
+	s.api.changes <- struct{}{}
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot dispatch scheduled actions: boom")
+}
+
+func (s *workerSuite) TestWatchError(c *gc.C) {
+	s.api.watchErr = errors.New("boom")
+	w := actionscheduler.New(s.api)
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot watch action schedules: boom")
+}
+
+type mockAPI struct {
+	mu          sync.Mutex
+	changes     chan struct{}
+	watchErr    error
+	nextRun     time.Time
+	dispatchErr error
+	dispatched  chan struct{}
+}
+
+func (api *mockAPI) setNextRun(t time.Time) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.nextRun = t
+}
+
+func (api *mockAPI) WatchActionSchedules() (apiwatcher.NotifyWatcher, error) {
+	if api.watchErr != nil {
+		return nil, api.watchErr
+	}
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) DispatchDue() (params.ActionScheduleDispatchResult, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.dispatchErr != nil {
+		return params.ActionScheduleDispatchResult{}, api.dispatchErr
+	}
+	api.dispatched <- struct{}{}
+	return params.ActionScheduleDispatchResult{NextRun: api.nextRun}, nil
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agentconfigupdater
+
+import (
+	"reflect"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.agentconfigupdater")
+
+// AgentConfigUpdater is responsible for keeping the environment settings
+// recorded in an agent's config file up to date.
+//
+// In practice, AgentConfigUpdater is used by agents to watch the logging
+// config and proxy settings, and to record any changes in the agent's
+// config file so they are applied when the agent next starts. Running
+// agents are reconfigured by the logger and proxyupdater workers.
+type AgentConfigUpdater struct {
+	getter AgentConfigSettingsGetter
+	setter AgentConfigSettingsSetter
+	last   *params.AgentConfigSettings
+}
+
+// AgentConfigSettingsGetter is an interface that is provided to
+// NewAgentConfigUpdater which can be used to watch for changes to
+// the agent config settings.
+type AgentConfigSettingsGetter interface {
+	WatchAgentConfigSettings() (watcher.NotifyWatcher, error)
+	AgentConfigSettings() (params.AgentConfigSettings, error)
+}
+
+// AgentConfigSettingsSetter is an interface that is provided to
+// NewAgentConfigUpdater whose SetAgentConfigSettings method will be
+// invoked whenever the settings change.
+type AgentConfigSettingsSetter interface {
+	SetAgentConfigSettings(settings params.AgentConfigSettings) error
+}
+
+// NewAgentConfigUpdater returns a worker.Worker that watches for
+// changes to the agent config settings and sets them on the
+// AgentConfigSettingsSetter.
+func NewAgentConfigUpdater(getter AgentConfigSettingsGetter, setter AgentConfigSettingsSetter) worker.Worker {
+	return worker.NewNotifyWorker(&AgentConfigUpdater{
+		getter: getter,
+		setter: setter,
+	})
+}
+
+// SetUp is defined on the NotifyWatchHandler interface.
+func (u *AgentConfigUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return u.getter.WatchAgentConfigSettings()
+}
+
+// Handle is defined on the NotifyWatchHandler interface.
+func (u *AgentConfigUpdater) Handle() error {
+	settings, err := u.getter.AgentConfigSettings()
+	if err != nil {
+		return errors.Annotate(err, "cannot read agent config settings")
+	}
+	if u.last != nil && reflect.DeepEqual(*u.last, settings) {
+		return nil
+	}
+	if err := u.setter.SetAgentConfigSettings(settings); err != nil {
+		return errors.Annotate(err, "cannot set agent config settings")
+	}
+	u.last = &settings
+	logger.Infof("agent config settings updated")
+	return nil
+}
+
+// TearDown is defined on the NotifyWatchHandler interface.
+func (u *AgentConfigUpdater) TearDown() error {
+	return nil
+}
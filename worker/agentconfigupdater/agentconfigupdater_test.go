@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package agentconfigupdater_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/agentconfigupdater"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type AgentConfigUpdaterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&AgentConfigUpdaterSuite{})
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
+
+type mockSettingsGetter struct {
+	changes  chan struct{}
+	settings params.AgentConfigSettings
+}
+
+func (g *mockSettingsGetter) WatchAgentConfigSettings() (watcher.NotifyWatcher, error) {
+	return &mockNotifyWatcher{g.changes}, nil
+}
+
+func (g *mockSettingsGetter) AgentConfigSettings() (params.AgentConfigSettings, error) {
+	return g.settings, nil
+}
+
+type mockSettingsSetter struct {
+	settings chan params.AgentConfigSettings
+}
+
+func (s *mockSettingsSetter) SetAgentConfigSettings(settings params.AgentConfigSettings) error {
+	s.settings <- settings
+	return nil
+}
+
+func (s *AgentConfigUpdaterSuite) TestSettingsChange(c *gc.C) {
+	getter := &mockSettingsGetter{
+		changes:  make(chan struct{}),
+		settings: params.AgentConfigSettings{LoggingConfig: "<root>=DEBUG"},
+	}
+	setter := &mockSettingsSetter{make(chan params.AgentConfigSettings, 1)}
+	worker := agentconfigupdater.NewAgentConfigUpdater(getter, setter)
+	defer func() { c.Assert(worker.Wait(), jc.ErrorIsNil) }()
+	defer worker.Kill()
+
+	getter.changes <- struct{}{}
+	select {
+	case settings := <-setter.settings:
+		c.Assert(settings, jc.DeepEquals, getter.settings)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for settings to be set")
+	}
+}
+
+func (s *AgentConfigUpdaterSuite) TestSettingsUnchanged(c *gc.C) {
+	getter := &mockSettingsGetter{
+		changes:  make(chan struct{}),
+		settings: params.AgentConfigSettings{LoggingConfig: "<root>=DEBUG"},
+	}
+	setter := &mockSettingsSetter{make(chan params.AgentConfigSettings, 1)}
+	worker := agentconfigupdater.NewAgentConfigUpdater(getter, setter)
+	defer func() { c.Assert(worker.Wait(), jc.ErrorIsNil) }()
+	defer worker.Kill()
+
+	getter.changes <- struct{}{}
+	select {
+	case <-setter.settings:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for settings to be set")
+	}
+
+	// Another event with the same settings is ignored.
+	getter.changes <- struct{}{}
+	select {
+	case <-setter.settings:
+		c.Fatalf("settings unexpectedly set")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package apicache provides a small persistent cache for API results
+// that rarely change, so that an agent need not ask the API server for
+// the same information every time it is needed or the agent restarts.
+package apicache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+)
+
+var logger = loggo.GetLogger("juju.worker.apicache")
+
+// DefaultMaxAge is how long a cached result is used before it is
+// fetched again. It bounds how long a change made while the agent
+// was not watching for it can go unnoticed.
+const DefaultMaxAge = 10 * time.Minute
+
+// Cache holds API results by key, both in memory and in a file, so
+// that they survive agent restarts. Results older than the cache's
+// maximum age are fetched again.
+type Cache struct {
+	path   string
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry holds a single cached result.
+type cacheEntry struct {
+	Time  time.Time       `json:"time"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Open returns a cache backed by the file at the given path, holding
+// any results recorded there that are younger than maxAge. A missing
+// or unreadable file results in an empty cache.
+func Open(path string, maxAge time.Duration) *Cache {
+	c := &Cache{
+		path:    path,
+		maxAge:  maxAge,
+		entries: make(map[string]cacheEntry),
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warningf("cannot read API cache: %v", err)
+		}
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		logger.Warningf("discarding invalid API cache: %v", err)
+		c.entries = make(map[string]cacheEntry)
+	}
+	return c
+}
+
+// Get fills result with the result cached under key. If no current
+// result is cached, fetch is called to fill result from the API, and
+// the result is cached if fetch succeeds.
+func (c *Cache) Get(key string, result interface{}, fetch func(result interface{}) error) error {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.Time) < c.maxAge {
+		err := json.Unmarshal(entry.Value, result)
+		if err == nil {
+			return nil
+		}
+		logger.Warningf("discarding invalid API cache entry %q: %v", key, err)
+	}
+	if err := fetch(result); err != nil {
+		return err
+	}
+	value, err := json.Marshal(result)
+	if err != nil {
+		return errors.Annotatef(err, "cannot cache %q", key)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{Time: time.Now(), Value: value}
+	if err := c.save(); err != nil {
+		logger.Warningf("cannot save API cache: %v", err)
+	}
+	return nil
+}
+
+// Invalidate discards all cached results.
+func (c *Cache) Invalidate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+	return errors.Annotate(c.save(), "cannot save API cache")
+}
+
+// save writes the cached results to the cache's file. It must be
+// called with c.mu held.
+func (c *Cache) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return utils.AtomicWriteFile(c.path, data, 0600)
+}
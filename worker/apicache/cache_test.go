@@ -0,0 +1,98 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apicache_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/apicache"
+)
+
+type cacheSuite struct {
+	coretesting.BaseSuite
+	path    string
+	fetches int
+}
+
+var _ = gc.Suite(&cacheSuite{})
+
+func (s *cacheSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.path = filepath.Join(c.MkDir(), "api-cache.json")
+	s.fetches = 0
+}
+
+func (s *cacheSuite) fetch(result interface{}) error {
+	s.fetches++
+	*result.(*params.ContainerConfig) = params.ContainerConfig{
+		ProviderType:   "dummy",
+		AuthorizedKeys: "ssh-rsa key",
+	}
+	return nil
+}
+
+func (s *cacheSuite) assertGet(c *gc.C, cache *apicache.Cache, expectFetches int) {
+	var result params.ContainerConfig
+	err := cache.Get("container-config", &result, s.fetch)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.ContainerConfig{
+		ProviderType:   "dummy",
+		AuthorizedKeys: "ssh-rsa key",
+	})
+	c.Assert(s.fetches, gc.Equals, expectFetches)
+}
+
+func (s *cacheSuite) TestGetCaches(c *gc.C) {
+	cache := apicache.Open(s.path, time.Hour)
+	s.assertGet(c, cache, 1)
+	s.assertGet(c, cache, 1)
+}
+
+func (s *cacheSuite) TestGetPersists(c *gc.C) {
+	s.assertGet(c, apicache.Open(s.path, time.Hour), 1)
+	s.assertGet(c, apicache.Open(s.path, time.Hour), 1)
+}
+
+func (s *cacheSuite) TestGetRefetchesOldResults(c *gc.C) {
+	cache := apicache.Open(s.path, -time.Second)
+	s.assertGet(c, cache, 1)
+	s.assertGet(c, cache, 2)
+}
+
+func (s *cacheSuite) TestGetFetchError(c *gc.C) {
+	cache := apicache.Open(s.path, time.Hour)
+	var result params.ContainerConfig
+	err := cache.Get("container-config", &result, func(interface{}) error {
+		return errors.New("boom")
+	})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	s.assertGet(c, cache, 1)
+}
+
+func (s *cacheSuite) TestInvalidate(c *gc.C) {
+	cache := apicache.Open(s.path, time.Hour)
+	s.assertGet(c, cache, 1)
+	err := cache.Invalidate()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertGet(c, cache, 2)
+
+	// The invalidation is persisted too.
+	err = cache.Invalidate()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertGet(c, apicache.Open(s.path, time.Hour), 3)
+}
+
+func (s *cacheSuite) TestOpenInvalidFile(c *gc.C) {
+	err := ioutil.WriteFile(s.path, []byte("not json"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertGet(c, apicache.Open(s.path, time.Hour), 1)
+}
@@ -0,0 +1,48 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apicache
+
+import (
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/worker"
+)
+
+// NewInvalidator returns a worker that discards the cache's results
+// whenever the watcher started by watch reports a change after its
+// initial event.
+func NewInvalidator(cache *Cache, watch func() (apiwatcher.NotifyWatcher, error)) worker.Worker {
+	return worker.NewNotifyWorker(&invalidator{
+		cache: cache,
+		watch: watch,
+	})
+}
+
+type invalidator struct {
+	cache   *Cache
+	watch   func() (apiwatcher.NotifyWatcher, error)
+	started bool
+}
+
+// SetUp is defined on the worker.NotifyWatchHandler interface.
+func (inv *invalidator) SetUp() (apiwatcher.NotifyWatcher, error) {
+	return inv.watch()
+}
+
+// Handle is defined on the worker.NotifyWatchHandler interface.
+func (inv *invalidator) Handle() error {
+	if !inv.started {
+		// The initial event reports no change; results cached
+		// before the agent started are bounded by the cache's
+		// maximum age instead.
+		inv.started = true
+		return nil
+	}
+	logger.Debugf("invalidating API cache")
+	return inv.cache.Invalidate()
+}
+
+// TearDown is defined on the worker.NotifyWatchHandler interface.
+func (inv *invalidator) TearDown() error {
+	return nil
+}
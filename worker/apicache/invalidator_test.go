@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apicache_test
+
+import (
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/apicache"
+)
+
+type invalidatorSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&invalidatorSuite{})
+
+type mockNotifyWatcher struct {
+	tomb    tomb.Tomb
+	changes chan struct{}
+}
+
+func newMockNotifyWatcher() *mockNotifyWatcher {
+	w := &mockNotifyWatcher{changes: make(chan struct{})}
+	go func() {
+		defer w.tomb.Done()
+		<-w.tomb.Dying()
+	}()
+	return w
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (w *mockNotifyWatcher) Stop() error {
+	w.tomb.Kill(nil)
+	return w.tomb.Wait()
+}
+
+func (w *mockNotifyWatcher) Err() error {
+	return w.tomb.Err()
+}
+
+func (s *invalidatorSuite) TestInvalidatesAfterInitialEvent(c *gc.C) {
+	cache := apicache.Open(filepath.Join(c.MkDir(), "api-cache.json"), time.Hour)
+	fetches := 0
+	get := func() {
+		var result string
+		err := cache.Get("key", &result, func(result interface{}) error {
+			fetches++
+			*result.(*string) = "value"
+			return nil
+		})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	get()
+
+	watcher := newMockNotifyWatcher()
+	w := apicache.NewInvalidator(cache, func() (apiwatcher.NotifyWatcher, error) {
+		return watcher, nil
+	})
+	defer func() {
+		w.Kill()
+		c.Assert(w.Wait(), jc.ErrorIsNil)
+	}()
+
+	// The initial event leaves the cache alone.
+	s.sendChange(c, watcher)
+	get()
+	c.Assert(fetches, gc.Equals, 1)
+
+	// Sending a second change can only complete once the
+	// first has been handled, so send two more.
+	s.sendChange(c, watcher)
+	s.sendChange(c, watcher)
+	get()
+	c.Assert(fetches, gc.Equals, 2)
+}
+
+func (s *invalidatorSuite) sendChange(c *gc.C, watcher *mockNotifyWatcher) {
+	select {
+	case watcher.changes <- struct{}{}:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out sending change")
+	}
+}
@@ -5,6 +5,7 @@ package authenticationworker
 
 import (
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -14,7 +15,7 @@ import (
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/api/keyupdater"
-	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/utils/ssh"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
@@ -24,21 +25,21 @@ import (
 // Override for testing.
 var SSHUser = "ubuntu"
 
+// reconcileInterval is how often the worker checks the authorised keys
+// even when it has not been told they have changed, so that keys which
+// have expired, and Juju keys edited into or out of the file by hand,
+// are dealt with.
+// Override for testing.
+var reconcileInterval = 5 * time.Minute
+
 var logger = loggo.GetLogger("juju.worker.authenticationworker")
 
 type keyupdaterWorker struct {
 	st   *keyupdater.State
 	tomb tomb.Tomb
 	tag  names.MachineTag
-	// jujuKeys are the most recently retrieved keys from state.
-	jujuKeys set.Strings
-	// nonJujuKeys are those added externally to auth keys file
-	// such keys do not have comments with the Juju: prefix.
-	nonJujuKeys []string
 }
 
-var _ worker.NotifyWatchHandler = (*keyupdaterWorker)(nil)
-
 // NewWorker returns a worker that keeps track of
 // the machine's authorised ssh keys and ensures the
 // ~/.ssh/authorized_keys file is up to date.
@@ -47,92 +48,104 @@ func NewWorker(st *keyupdater.State, agentConfig agent.Config) worker.Worker {
 		return worker.NewNoOpWorker()
 	}
 	kw := &keyupdaterWorker{st: st, tag: agentConfig.Tag().(names.MachineTag)}
-	return worker.NewNotifyWorker(kw)
+	go func() {
+		defer kw.tomb.Done()
+		kw.tomb.Kill(kw.loop())
+	}()
+	return kw
 }
 
-// SetUp is defined on the worker.NotifyWatchHandler interface.
-func (kw *keyupdaterWorker) SetUp() (watcher.NotifyWatcher, error) {
-	// Record the keys Juju knows about.
-	jujuKeys, err := kw.st.AuthorisedKeys(kw.tag)
-	if err != nil {
-		err = errors.Annotatef(err, "reading Juju ssh keys for %q", kw.tag)
-		logger.Infof(err.Error())
-		return nil, err
-	}
-	kw.jujuKeys = set.NewStrings(jujuKeys...)
+// Kill is part of the worker.Worker interface.
+func (kw *keyupdaterWorker) Kill() {
+	kw.tomb.Kill(nil)
+}
 
-	// Read the keys currently in ~/.ssh/authorised_keys.
-	sshKeys, err := ssh.ListKeys(SSHUser, ssh.FullKeys)
-	if err != nil {
-		err = errors.Annotatef(err, "reading ssh authorized keys for %q", kw.tag)
-		logger.Infof(err.Error())
-		return nil, err
-	}
-	// Record any keys not added by Juju.
-	for _, key := range sshKeys {
-		_, comment, err := ssh.KeyFingerprint(key)
-		// Also record keys which we cannot parse.
-		if err != nil || !strings.HasPrefix(comment, ssh.JujuCommentPrefix) {
-			kw.nonJujuKeys = append(kw.nonJujuKeys, key)
-		}
-	}
+// Wait is part of the worker.Worker interface.
+func (kw *keyupdaterWorker) Wait() error {
+	return kw.tomb.Wait()
+}
+
+func (kw *keyupdaterWorker) loop() error {
 	// Write out the ssh authorised keys file to match the current state of the world.
-	if err := kw.writeSSHKeys(jujuKeys); err != nil {
+	if err := kw.reconcile(); err != nil {
 		err = errors.Annotate(err, "adding current Juju keys to ssh authorised keys")
 		logger.Infof(err.Error())
-		return nil, err
+		return err
 	}
-
 	w, err := kw.st.WatchAuthorisedKeys(kw.tag)
 	if err != nil {
 		err = errors.Annotate(err, "starting key updater worker")
 		logger.Infof(err.Error())
-		return nil, err
+		return err
 	}
+	defer watcher.Stop(w, &kw.tomb)
 	logger.Infof("%q key updater worker started", kw.tag)
-	return w, nil
-}
 
-// writeSSHKeys writes out a new ~/.ssh/authorised_keys file, retaining any non Juju keys
-// and adding the specified set of Juju keys.
-func (kw *keyupdaterWorker) writeSSHKeys(jujuKeys []string) error {
-	allKeys := kw.nonJujuKeys
-	// Ensure any Juju keys have the required prefix in their comment.
-	for i, key := range jujuKeys {
-		jujuKeys[i] = ssh.EnsureJujuComment(key)
+	for {
+		select {
+		case <-kw.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		case <-time.After(reconcileInterval):
+		}
+		if err := kw.reconcile(); err != nil {
+			err = errors.Annotate(err, "updating ssh keys")
+			logger.Infof(err.Error())
+			return err
+		}
 	}
-	allKeys = append(allKeys, jujuKeys...)
-	return ssh.ReplaceKeys(SSHUser, allKeys...)
 }
 
-// Handle is defined on the worker.NotifyWatchHandler interface.
-func (kw *keyupdaterWorker) Handle() error {
+// reconcile brings the ~/.ssh/authorized_keys file in line with the keys
+// Juju has: any Juju keys in the file that Juju no longer has, because they
+// were deleted, revoked or have expired, are removed, and any Juju keys not
+// in the file are added. Keys not added by Juju, which do not have comments
+// with the Juju: prefix, are retained.
+func (kw *keyupdaterWorker) reconcile() error {
 	// Read the keys that Juju has.
 	newKeys, err := kw.st.AuthorisedKeys(kw.tag)
 	if err != nil {
-		err = errors.Annotatef(err, "reading Juju ssh keys for %q", kw.tag)
-		logger.Infof(err.Error())
-		return err
+		return errors.Annotatef(err, "reading Juju ssh keys for %q", kw.tag)
 	}
-	// Figure out if any keys have been added or deleted.
-	newJujuKeys := set.NewStrings(newKeys...)
-	deleted := kw.jujuKeys.Difference(newJujuKeys)
-	added := newJujuKeys.Difference(kw.jujuKeys)
-	if added.Size() > 0 || deleted.Size() > 0 {
-		logger.Debugf("adding ssh keys to authorised keys: %v", added)
-		logger.Debugf("deleting ssh keys from authorised keys: %v", deleted)
-		if err = kw.writeSSHKeys(newKeys); err != nil {
-			err = errors.Annotate(err, "updating ssh keys")
-			logger.Infof(err.Error())
-			return err
+	// Ensure any Juju keys have the required prefix in their comment,
+	// and are written once even if several users hold them.
+	jujuKeys := make([]string, 0, len(newKeys))
+	newJujuKeys := make(set.Strings)
+	for _, key := range newKeys {
+		key = ssh.EnsureJujuComment(key)
+		if !newJujuKeys.Contains(key) {
+			newJujuKeys.Add(key)
+			jujuKeys = append(jujuKeys, key)
 		}
 	}
-	kw.jujuKeys = newJujuKeys
-	return nil
-}
 
-// TearDown is defined on the worker.NotifyWatchHandler interface.
-func (kw *keyupdaterWorker) TearDown() error {
-	// Nothing to do here.
-	return nil
+	// Read the keys currently in ~/.ssh/authorised_keys.
+	sshKeys, err := ssh.ListKeys(SSHUser, ssh.FullKeys)
+	if err != nil {
+		return errors.Annotatef(err, "reading ssh authorized keys for %q", kw.tag)
+	}
+	var nonJujuKeys, existingJujuKeys []string
+	for _, key := range sshKeys {
+		_, comment, err := ssh.KeyFingerprint(key)
+		// Also retain keys which we cannot parse.
+		if err != nil || !strings.HasPrefix(comment, ssh.JujuCommentPrefix) {
+			nonJujuKeys = append(nonJujuKeys, key)
+		} else {
+			existingJujuKeys = append(existingJujuKeys, key)
+		}
+	}
+
+	// Figure out if any keys have been added or deleted.
+	currentJujuKeys := set.NewStrings(existingJujuKeys...)
+	deleted := currentJujuKeys.Difference(newJujuKeys)
+	added := newJujuKeys.Difference(currentJujuKeys)
+	if added.Size() == 0 && deleted.Size() == 0 && len(existingJujuKeys) == currentJujuKeys.Size() {
+		return nil
+	}
+	logger.Debugf("adding ssh keys to authorised keys: %v", added)
+	logger.Debugf("deleting ssh keys from authorised keys: %v", deleted)
+	return ssh.ReplaceKeys(SSHUser, append(nonJujuKeys, jujuKeys...)...)
 }
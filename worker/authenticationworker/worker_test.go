@@ -187,3 +187,30 @@ func (s *workerSuite) TestWorkerRestart(c *gc.C) {
 	yetAnotherKeyWithCommentPrefix := sshtesting.ValidKeyThree.Key + " Juju:yetanother@host"
 	s.waitSSHKeys(c, append(s.existingKeys, yetAnotherKeyWithCommentPrefix))
 }
+
+func (s *workerSuite) TestUserKeys(c *gc.C) {
+	authWorker := authenticationworker.NewWorker(s.keyupdaterApi, agentConfig(c, s.machine.Tag().(names.MachineTag)))
+	defer stop(c, authWorker)
+	s.waitSSHKeys(c, append(s.existingKeys, s.existingEnvKey))
+
+	user := s.Factory.MakeEnvUser(c, nil).UserTag()
+	_, err := s.BackingState.AddSSHKey(user, sshtesting.ValidKeyThree.Key+" user@host", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.BackingState.StartSync()
+	userKeyWithCommentPrefix := sshtesting.ValidKeyThree.Key + " Juju:user@host"
+	s.waitSSHKeys(c, append(s.existingKeys, s.existingEnvKey, userKeyWithCommentPrefix))
+}
+
+func (s *workerSuite) TestReconcilesRevokedKeys(c *gc.C) {
+	s.PatchValue(authenticationworker.ReconcileInterval, coretesting.ShortWait)
+	authWorker := authenticationworker.NewWorker(s.keyupdaterApi, agentConfig(c, s.machine.Tag().(names.MachineTag)))
+	defer stop(c, authWorker)
+	s.waitSSHKeys(c, append(s.existingKeys, s.existingEnvKey))
+
+	// A Juju key Juju does not have is removed, even though no
+	// change to the keys is notified.
+	revokedKey := sshtesting.ValidKeyThree.Key + " Juju:revoked@host"
+	err := ssh.AddKeys(authenticationworker.SSHUser, revokedKey)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSSHKeys(c, append(s.existingKeys, s.existingEnvKey))
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cacertupdater
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.cacertupdater")
+
+// CACertUpdater is responsible for propagating the environment's CA
+// certificate.
+//
+// In practice, CACertUpdater is used by agents to watch the environment
+// config and write any new CA certificate to the agent's config file, so
+// the agent keeps trusting the state servers while their certificate is
+// rotated.
+type CACertUpdater struct {
+	getter     EnvironConfigGetter
+	setter     CACertSetter
+	getCurrent func() string
+}
+
+// EnvironConfigGetter is an interface that is provided to
+// NewCACertUpdater which can be used to watch for environment config
+// changes.
+type EnvironConfigGetter interface {
+	WatchForEnvironConfigChanges() (watcher.NotifyWatcher, error)
+	EnvironConfig() (*config.Config, error)
+}
+
+// CACertSetter is an interface that is provided to NewCACertUpdater
+// whose SetCACert method will be invoked whenever the CA certificate
+// changes.
+type CACertSetter interface {
+	SetCACert(caCert string) error
+}
+
+// NewCACertUpdater returns a worker.Worker that watches for changes to
+// the environment's CA certificate and sets them on the CACertSetter.
+// The current function returns the CA certificate the agent is
+// currently using.
+func NewCACertUpdater(getter EnvironConfigGetter, setter CACertSetter, current func() string) worker.Worker {
+	return worker.NewNotifyWorker(&CACertUpdater{
+		getter:     getter,
+		setter:     setter,
+		getCurrent: current,
+	})
+}
+
+// SetUp is defined on the NotifyWatchHandler interface.
+func (u *CACertUpdater) SetUp() (watcher.NotifyWatcher, error) {
+	return u.getter.WatchForEnvironConfigChanges()
+}
+
+// Handle is defined on the NotifyWatchHandler interface.
+func (u *CACertUpdater) Handle() error {
+	envConfig, err := u.getter.EnvironConfig()
+	if err != nil {
+		return errors.Annotate(err, "cannot read environment config")
+	}
+	caCert, ok := envConfig.CACert()
+	if !ok || caCert == "" || caCert == u.getCurrent() {
+		return nil
+	}
+	if err := u.setter.SetCACert(caCert); err != nil {
+		return errors.Annotate(err, "cannot set CA certificate")
+	}
+	logger.Infof("CA certificate updated")
+	return nil
+}
+
+// TearDown is defined on the NotifyWatchHandler interface.
+func (u *CACertUpdater) TearDown() error {
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cacertupdater_test
+
+import (
+	stdtesting "testing"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/cacertupdater"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type CACertUpdaterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&CACertUpdaterSuite{})
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
+
+type mockEnvironConfigGetter struct {
+	changes chan struct{}
+	caCert  string
+	caKey   string
+}
+
+func (g *mockEnvironConfigGetter) WatchForEnvironConfigChanges() (watcher.NotifyWatcher, error) {
+	return &mockNotifyWatcher{g.changes}, nil
+}
+
+func (g *mockEnvironConfigGetter) EnvironConfig() (*config.Config, error) {
+	return config.New(config.NoDefaults, coretesting.FakeConfig().Merge(coretesting.Attrs{
+		"ca-cert":        g.caCert,
+		"ca-private-key": g.caKey,
+	}))
+}
+
+type mockCACertSetter struct {
+	caCerts chan string
+}
+
+func (s *mockCACertSetter) SetCACert(caCert string) error {
+	s.caCerts <- caCert
+	return nil
+}
+
+func (s *CACertUpdaterSuite) TestCACertChange(c *gc.C) {
+	getter := &mockEnvironConfigGetter{
+		changes: make(chan struct{}),
+		caCert:  coretesting.OtherCACert + coretesting.CACert,
+		caKey:   coretesting.OtherCAKey,
+	}
+	setter := &mockCACertSetter{make(chan string, 1)}
+	current := func() string { return coretesting.CACert }
+	worker := cacertupdater.NewCACertUpdater(getter, setter, current)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	getter.changes <- struct{}{}
+	select {
+	case caCert := <-setter.caCerts:
+		c.Assert(caCert, gc.Equals, coretesting.OtherCACert+coretesting.CACert)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for CA certificate to be set")
+	}
+}
+
+func (s *CACertUpdaterSuite) TestCACertUnchanged(c *gc.C) {
+	getter := &mockEnvironConfigGetter{
+		changes: make(chan struct{}),
+		caCert:  coretesting.CACert,
+		caKey:   coretesting.CAKey,
+	}
+	setter := &mockCACertSetter{make(chan string, 1)}
+	current := func() string { return coretesting.CACert }
+	worker := cacertupdater.NewCACertUpdater(getter, setter, current)
+	defer func() { c.Assert(worker.Wait(), jc.ErrorIsNil) }()
+	defer worker.Kill()
+
+	getter.changes <- struct{}{}
+	select {
+	case <-setter.caCerts:
+		c.Fatalf("CA certificate unexpectedly set")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
@@ -49,6 +49,15 @@ type StateServingInfoGetter interface {
 	StateServingInfo() (params.StateServingInfo, bool)
 }
 
+// StateServingInfoGetterFunc adapts a function to the
+// StateServingInfoGetter interface.
+type StateServingInfoGetterFunc func() (params.StateServingInfo, bool)
+
+// StateServingInfo is part of the StateServingInfoGetter interface.
+func (f StateServingInfoGetterFunc) StateServingInfo() (params.StateServingInfo, bool) {
+	return f()
+}
+
 // StateServingInfoSetter defines a function that is called to set a
 // StateServingInfo value with a newly generated certificate.
 type StateServingInfoSetter func(info params.StateServingInfo) error
@@ -173,3 +173,88 @@ func (s *CertUpdaterSuite) TestAddressChangeNoCAKey(c *gc.C) {
 		c.Fatalf("set state serving info unexpectedly called")
 	}
 }
+
+type mockServingInfoWatcher struct {
+	changes chan struct{}
+	info    state.StateServingInfo
+}
+
+func (m *mockServingInfoWatcher) WatchStateServingInfo() state.NotifyWatcher {
+	return newMockNotifyWatcher(m.changes)
+}
+
+func (m *mockServingInfoWatcher) StateServingInfo() (state.StateServingInfo, error) {
+	return m.info, nil
+}
+
+func (s *CertUpdaterSuite) TestRotatedCertificateInstalled(c *gc.C) {
+	installed := make(chan params.StateServingInfo, 1)
+	setter := func(info params.StateServingInfo) error {
+		installed <- info
+		return nil
+	}
+	changes := make(chan struct{})
+	servingInfo := &mockServingInfoWatcher{
+		changes: changes,
+		info: state.StateServingInfo{
+			Cert:         "rotated cert",
+			PrivateKey:   "rotated key",
+			CAPrivateKey: coretesting.CAKey,
+		},
+	}
+	worker := certupdater.NewCertificateRotator(servingInfo, &mockStateServingGetter{}, setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	changes <- struct{}{}
+	select {
+	case info := <-installed:
+		c.Assert(info.Cert, gc.Equals, "rotated cert")
+		c.Assert(info.PrivateKey, gc.Equals, "rotated key")
+		// Other values are taken from the agent's config.
+		c.Assert(info.StatePort, gc.Equals, 123)
+		c.Assert(info.APIPort, gc.Equals, 456)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for certificate to be installed")
+	}
+}
+
+func (s *CertUpdaterSuite) TestUnchangedCertificateNotInstalled(c *gc.C) {
+	installed := make(chan struct{})
+	setter := func(info params.StateServingInfo) error {
+		close(installed)
+		return nil
+	}
+	changes := make(chan struct{})
+	servingInfo := &mockServingInfoWatcher{
+		changes: changes,
+		info: state.StateServingInfo{
+			Cert:         coretesting.ServerCert,
+			PrivateKey:   coretesting.ServerKey,
+			CAPrivateKey: coretesting.CAKey,
+		},
+	}
+	worker := certupdater.NewCertificateRotator(servingInfo, &mockStateServingGetter{}, setter)
+	defer func() { c.Assert(worker.Wait(), gc.IsNil) }()
+	defer worker.Kill()
+
+	changes <- struct{}{}
+	select {
+	case <-time.After(coretesting.ShortWait):
+	case <-installed:
+		c.Fatalf("set state serving info unexpectedly called")
+	}
+}
+
+func (s *CertUpdaterSuite) TestStateServingInfoGetterFunc(c *gc.C) {
+	calls := 0
+	getter := certupdater.StateServingInfoGetterFunc(func() (params.StateServingInfo, bool) {
+		calls++
+		return params.StateServingInfo{APIPort: calls}, true
+	})
+	for i := 1; i <= 2; i++ {
+		info, ok := getter.StateServingInfo()
+		c.Assert(ok, jc.IsTrue)
+		c.Assert(info.APIPort, gc.Equals, i)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package certupdater
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+// CertificateRotator is responsible for installing state server
+// certificates that have been rotated through the API.
+//
+// In practice, CertificateRotator is used by a state server's machine
+// agent to watch the state serving info in state, and write any new
+// certificate to the agent's config file, from where it is passed to
+// the running API server.
+type CertificateRotator struct {
+	servingInfo ServingInfoWatcher
+	getter      StateServingInfoGetter
+	setter      StateServingInfoSetter
+}
+
+// ServingInfoWatcher is an interface that is provided to
+// NewCertificateRotator which can be used to watch for changes to the
+// state serving info held in state.
+type ServingInfoWatcher interface {
+	WatchStateServingInfo() state.NotifyWatcher
+	StateServingInfo() (state.StateServingInfo, error)
+}
+
+// NewCertificateRotator returns a worker.Worker that watches for changes
+// to the state serving info in state, and passes any new certificate to
+// the StateServingInfoSetter.
+func NewCertificateRotator(servingInfo ServingInfoWatcher, getter StateServingInfoGetter,
+	setter StateServingInfoSetter,
+) worker.Worker {
+	return worker.NewNotifyWorker(&CertificateRotator{
+		servingInfo: servingInfo,
+		getter:      getter,
+		setter:      setter,
+	})
+}
+
+// SetUp is defined on the NotifyWatchHandler interface.
+func (c *CertificateRotator) SetUp() (watcher.NotifyWatcher, error) {
+	return c.servingInfo.WatchStateServingInfo(), nil
+}
+
+// Handle is defined on the NotifyWatchHandler interface.
+func (c *CertificateRotator) Handle() error {
+	stateInfo, ok := c.getter.StateServingInfo()
+	if !ok {
+		logger.Warningf("no state serving info, cannot install rotated certificate")
+		return nil
+	}
+	info, err := c.servingInfo.StateServingInfo()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot read state serving info")
+	}
+	if info.Cert == stateInfo.Cert &&
+		info.PrivateKey == stateInfo.PrivateKey &&
+		info.CAPrivateKey == stateInfo.CAPrivateKey {
+		return nil
+	}
+	stateInfo.Cert = info.Cert
+	stateInfo.PrivateKey = info.PrivateKey
+	stateInfo.CAPrivateKey = info.CAPrivateKey
+	if err := c.setter(stateInfo); err != nil {
+		return errors.Annotate(err, "cannot write agent config")
+	}
+	logger.Infof("installed rotated state server certificate")
+	return nil
+}
+
+// TearDown is defined on the NotifyWatchHandler interface.
+func (c *CertificateRotator) TearDown() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package charmgc implements a worker that removes the archives of
+// charms no longer used by any service or unit in an environment.
+package charmgc
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.charmgc")
+
+// collectPeriod is how often unused charms are collected.
+var collectPeriod = time.Hour
+
+// State defines the state methods used by the charm collector.
+type State interface {
+	// CollectUnusedCharms removes the charms that have been unused
+	// for longer than the environment's retention period, and
+	// returns the storage paths of their archives.
+	CollectUnusedCharms() ([]string, error)
+}
+
+// Storage defines the storage methods used by the charm collector.
+type Storage interface {
+	Remove(path string) error
+}
+
+// New returns a worker that periodically removes unused charms from
+// the given state, and their archives from the given storage.
+func New(st State, stor Storage) worker.Worker {
+	return worker.NewPeriodicWorker(func(stop <-chan struct{}) error {
+		return collect(st, stor)
+	}, collectPeriod)
+}
+
+func collect(st State, stor Storage) error {
+	paths, err := st.CollectUnusedCharms()
+	if err != nil {
+		return errors.Annotate(err, "cannot collect unused charms")
+	}
+	for _, path := range paths {
+		// The charm has already gone from state, so there is no
+		// point in retrying; a failure here just leaks the blob.
+		if err := stor.Remove(path); err != nil && !errors.IsNotFound(err) {
+			logger.Errorf("cannot remove charm archive %q: %v", path, err)
+			continue
+		}
+		logger.Debugf("removed charm archive %q", path)
+	}
+	return nil
+}
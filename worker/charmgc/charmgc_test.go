@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmgc_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/charmgc"
+)
+
+type charmGCSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&charmGCSuite{})
+
+type mockState struct {
+	paths [][]string
+	err   error
+}
+
+func (st *mockState) CollectUnusedCharms() ([]string, error) {
+	if st.err != nil {
+		return nil, st.err
+	}
+	if len(st.paths) == 0 {
+		return nil, nil
+	}
+	paths := st.paths[0]
+	st.paths = st.paths[1:]
+	return paths, nil
+}
+
+type mockStorage struct {
+	removed chan string
+	fail    map[string]bool
+}
+
+func (stor *mockStorage) Remove(path string) error {
+	stor.removed <- path
+	if stor.fail[path] {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (s *charmGCSuite) assertRemoved(c *gc.C, stor *mockStorage, expected ...string) {
+	for _, path := range expected {
+		select {
+		case removed := <-stor.removed:
+			c.Assert(removed, gc.Equals, path)
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for %q to be removed", path)
+		}
+	}
+}
+
+func (s *charmGCSuite) TestRemovesCollectedArchives(c *gc.C) {
+	defer charmgc.PatchCollectPeriod(time.Millisecond)()
+	st := &mockState{paths: [][]string{{"charms/a", "charms/b"}, {"charms/c"}}}
+	stor := &mockStorage{removed: make(chan string)}
+	w := charmgc.New(st, stor)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.assertRemoved(c, stor, "charms/a", "charms/b", "charms/c")
+}
+
+func (s *charmGCSuite) TestRemoveFailureDoesNotStopWorker(c *gc.C) {
+	defer charmgc.PatchCollectPeriod(time.Millisecond)()
+	st := &mockState{paths: [][]string{{"charms/a", "charms/b"}, {"charms/c"}}}
+	stor := &mockStorage{
+		removed: make(chan string),
+		fail:    map[string]bool{"charms/a": true},
+	}
+	w := charmgc.New(st, stor)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.assertRemoved(c, stor, "charms/a", "charms/b", "charms/c")
+}
+
+func (s *charmGCSuite) TestCollectError(c *gc.C) {
+	st := &mockState{err: errors.New("boom")}
+	stor := &mockStorage{removed: make(chan string)}
+	w := charmgc.New(st, stor)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "cannot collect unused charms: boom")
+}
@@ -0,0 +1,15 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charmgc
+
+import (
+	"time"
+
+	"github.com/juju/testing"
+)
+
+// PatchCollectPeriod sets how often the worker collects unused charms.
+func PatchCollectPeriod(period time.Duration) func() {
+	return testing.PatchValue(&collectPeriod, period)
+}
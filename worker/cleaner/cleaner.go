@@ -4,15 +4,25 @@
 package cleaner
 
 import (
+	"time"
+
 	"github.com/juju/loggo"
+	"launchpad.net/tomb"
 
-	"github.com/juju/juju/api/watcher"
+	apiwatcher "github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/worker"
 )
 
 var logger = loggo.GetLogger("juju.worker.cleaner")
 
+// retryInterval is how long the cleaner waits after running
+// state.Cleanup() before running it again, so that cleanups that
+// were deferred, such as those waiting for units to drain, are
+// retried even if nothing else changes.
+var retryInterval = 30 * time.Second
+
 // Cleaner is responsible for cleaning up the state.
 type Cleaner struct {
 	st *state.State
@@ -24,8 +34,8 @@ func NewCleaner(st *state.State) worker.Worker {
 	return worker.NewNotifyWorker(&Cleaner{st: st})
 }
 
-func (c *Cleaner) SetUp() (watcher.NotifyWatcher, error) {
-	return c.st.WatchCleanups(), nil
+func (c *Cleaner) SetUp() (apiwatcher.NotifyWatcher, error) {
+	return newRetryWatcher(c.st.WatchCleanups()), nil
 }
 
 func (c *Cleaner) Handle() error {
@@ -41,3 +51,63 @@ func (c *Cleaner) TearDown() error {
 	// Nothing to cleanup, only state is the watcher
 	return nil
 }
+
+// retryWatcher passes on the changes of the cleanup watcher it wraps,
+// and sends a further change every retryInterval after the last one
+// was received.
+type retryWatcher struct {
+	tomb   tomb.Tomb
+	source state.NotifyWatcher
+	out    chan struct{}
+}
+
+func newRetryWatcher(source state.NotifyWatcher) *retryWatcher {
+	w := &retryWatcher{
+		source: source,
+		out:    make(chan struct{}),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		defer watcher.Stop(source, &w.tomb)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *retryWatcher) loop() error {
+	var out chan struct{}
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.source.Changes():
+			if !ok {
+				return watcher.EnsureErr(w.source)
+			}
+			out = w.out
+		case <-retry:
+			out = w.out
+		case out <- struct{}{}:
+			out = nil
+			retry = time.After(retryInterval)
+		}
+	}
+}
+
+// Changes is part of the apiwatcher.NotifyWatcher interface.
+func (w *retryWatcher) Changes() <-chan struct{} {
+	return w.out
+}
+
+// Stop is part of the apiwatcher.NotifyWatcher interface.
+func (w *retryWatcher) Stop() error {
+	w.tomb.Kill(nil)
+	return w.tomb.Wait()
+}
+
+// Err is part of the apiwatcher.NotifyWatcher interface.
+func (w *retryWatcher) Err() error {
+	return w.tomb.Err()
+}
@@ -11,6 +11,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/cleaner"
@@ -72,3 +73,53 @@ func (s *CleanerSuite) TestCleaner(c *gc.C) {
 		break
 	}
 }
+
+func (s *CleanerSuite) TestCleanerRetriesDeferredCleanups(c *gc.C) {
+	s.PatchValue(cleaner.RetryInterval, coretesting.ShortWait)
+	svc := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := svc.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	pinger, err := unit.SetAgentPresence()
+	c.Assert(err, jc.ErrorIsNil)
+	defer pinger.Stop()
+	s.State.StartSync()
+	err = unit.WaitAgentPresence(coretesting.LongWait)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cr := cleaner.NewCleaner(s.State)
+	defer func() { c.Assert(worker.Stop(cr), gc.IsNil) }()
+
+	// The machine's cleanup is deferred until its unit has drained.
+	err = machine.ForceDestroy()
+	c.Assert(err, jc.ErrorIsNil)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.State.StartSync()
+		if _, err := unit.DrainStatus(); err == nil {
+			break
+		} else if !a.HasNext() {
+			c.Fatalf("timed out waiting for unit to start draining")
+		}
+	}
+	err = unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Nothing else changes once the unit has drained, so the cleanup
+	// only completes if the cleaner retries it.
+	err = unit.SetDrainStatus(nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := machine.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		if machine.Life() == state.Dead {
+			break
+		} else if !a.HasNext() {
+			c.Fatalf("timed out waiting for machine to be released")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package credentialvalidator provides a worker which periodically
+// checks that the provider accepts the environment's credentials,
+// suspending the environment while it does not.
+package credentialvalidator
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.credentialvalidator")
+
+// checkInterval is how long the worker waits between checks of the
+// credentials when the environment config does not change.
+var checkInterval = 10 * time.Minute
+
+// newEnviron is overridden in tests.
+var newEnviron = environs.New
+
+// CredentialValidatorAPI provides the environment config, and records
+// whether the provider accepts the credentials in it.
+type CredentialValidatorAPI interface {
+	WatchForEnvironConfigChanges() (apiwatcher.NotifyWatcher, error)
+	EnvironConfig() (*config.Config, error)
+	SetCredentialValidity(valid bool, reason string) error
+}
+
+// New returns a worker which checks the environment's credentials
+// periodically, and whenever the environment config changes, such as
+// when the credentials are updated.
+func New(api CredentialValidatorAPI) worker.Worker {
+	v := &validator{api: api}
+	go func() {
+		defer v.tomb.Done()
+		v.tomb.Kill(v.loop())
+	}()
+	return v
+}
+
+type validator struct {
+	tomb tomb.Tomb
+	api  CredentialValidatorAPI
+}
+
+// Kill is part of the worker.Worker interface.
+func (v *validator) Kill() {
+	v.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (v *validator) Wait() error {
+	return v.tomb.Wait()
+}
+
+func (v *validator) loop() error {
+	w, err := v.api.WatchForEnvironConfigChanges()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch environment config")
+	}
+	defer watcher.Stop(w, &v.tomb)
+
+	// The validity is always recorded after the first check, so that
+	// a suspension made before the worker started is lifted if the
+	// credentials have since become valid.
+	var reported, lastValid bool
+	var next <-chan time.Time
+	for {
+		select {
+		case <-v.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		case <-next:
+		}
+		next = time.After(checkInterval)
+		valid, reason, err := v.check()
+		if err != nil {
+			// The provider may be unreachable; the credentials are
+			// not presumed invalid.
+			logger.Warningf("cannot check environment credentials: %v", err)
+			continue
+		}
+		if reported && valid == lastValid {
+			continue
+		}
+		if valid {
+			logger.Infof("environment credentials are valid")
+		} else {
+			logger.Errorf("environment credentials are invalid: %s", reason)
+		}
+		if err := v.api.SetCredentialValidity(valid, reason); err != nil {
+			return errors.Annotate(err, "cannot set credential validity")
+		}
+		reported, lastValid = true, valid
+	}
+}
+
+// check returns whether the provider accepts the credentials in the
+// current environment config, and if not, why. Environs which cannot
+// check their credentials are assumed to have valid ones.
+func (v *validator) check() (bool, string, error) {
+	cfg, err := v.api.EnvironConfig()
+	if err != nil {
+		return false, "", errors.Trace(err)
+	}
+	env, err := newEnviron(cfg)
+	if err != nil {
+		return false, "", errors.Trace(err)
+	}
+	credentialValidator, ok := env.(environs.CredentialValidator)
+	if !ok {
+		return true, "", nil
+	}
+	err = credentialValidator.ValidateCredential()
+	if errors.IsUnauthorized(err) {
+		return false, err.Error(), nil
+	} else if err != nil {
+		return false, "", errors.Trace(err)
+	}
+	return true, "", nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package credentialvalidator_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/credentialvalidator"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+	api *mockAPI
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+func (s *workerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		config:   coretesting.EnvironConfig(c),
+		changes:  make(chan struct{}, 1),
+		reported: make(chan validity, 10),
+	}
+	s.PatchValue(credentialvalidator.NewEnviron, func(cfg *config.Config) (environs.Environ, error) {
+		return &mockEnviron{api: s.api}, nil
+	})
+}
+
+func (s *workerSuite) startWorker(c *gc.C) worker.Worker {
+	w := credentialvalidator.New(s.api)
+	s.AddCleanup(func(c *gc.C) {
+		w.Kill()
+		c.Check(w.Wait(), jc.ErrorIsNil)
+	})
+	return w
+}
+
+func (s *workerSuite) assertReported(c *gc.C, expect validity) {
+	select {
+	case v := <-s.api.reported:
+		c.Assert(v, gc.Equals, expect)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for credential validity")
+	}
+}
+
+func (s *workerSuite) assertNotReported(c *gc.C) {
+	select {
+	case v := <-s.api.reported:
+		c.Fatalf("unexpected credential validity %#v", v)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *workerSuite) TestReportsValidOnStart(c *gc.C) {
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertReported(c, validity{valid: true})
+	s.assertNotReported(c)
+}
+
+func (s *workerSuite) TestReportsOnlyChanges(c *gc.C) {
+	s.api.setCredentialErr(errors.Unauthorizedf("secret rejected"))
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertReported(c, validity{reason: "secret rejected"})
+
+	s.api.changes <- struct{}{}
+	s.assertNotReported(c)
+
+	// The credentials are updated, changing the environment config.
+	s.api.setCredentialErr(nil)
+	s.api.changes <- struct{}{}
+	s.assertReported(c, validity{valid: true})
+}
+
+func (s *workerSuite) TestChecksPeriodically(c *gc.C) {
+	s.PatchValue(credentialvalidator.CheckInterval, 10*time.Millisecond)
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertReported(c, validity{valid: true})
+
+	s.api.setCredentialErr(errors.Unauthorizedf("secret rejected"))
+	s.assertReported(c, validity{reason: "secret rejected"})
+}
+
+func (s *workerSuite) TestCheckErrorNotReported(c *gc.C) {
+	s.api.setCredentialErr(errors.New("connection refused"))
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNotReported(c)
+}
+
+func (s *workerSuite) TestSetCredentialValidityError(c *gc.C) {
+	s.api.setErr = errors.New("boom")
+	w := credentialvalidator.New(s.api)
+	s.api.changes <- struct{}{}
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot set credential validity: boom")
+}
+
+func (s *workerSuite) TestWatchError(c *gc.C) {
+	s.api.watchErr = errors.New("boom")
+	w := credentialvalidator.New(s.api)
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot watch environment config: boom")
+}
+
+type validity struct {
+	valid  bool
+	reason string
+}
+
+type mockAPI struct {
+	mu            sync.Mutex
+	config        *config.Config
+	changes       chan struct{}
+	watchErr      error
+	credentialErr error
+	setErr        error
+	reported      chan validity
+}
+
+func (api *mockAPI) setCredentialErr(err error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.credentialErr = err
+}
+
+func (api *mockAPI) WatchForEnvironConfigChanges() (apiwatcher.NotifyWatcher, error) {
+	if api.watchErr != nil {
+		return nil, api.watchErr
+	}
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) EnvironConfig() (*config.Config, error) {
+	return api.config, nil
+}
+
+func (api *mockAPI) SetCredentialValidity(valid bool, reason string) error {
+	if api.setErr != nil {
+		return api.setErr
+	}
+	api.reported <- validity{valid, reason}
+	return nil
+}
+
+type mockEnviron struct {
+	environs.Environ
+	api *mockAPI
+}
+
+func (e *mockEnviron) ValidateCredential() error {
+	e.api.mu.Lock()
+	defer e.api.mu.Unlock()
+	return e.api.credentialErr
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
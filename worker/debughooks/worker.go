@@ -0,0 +1,78 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package debughooks provides a worker through which clients start
+// debug-hooks sessions with a unit via the API server.
+package debughooks
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/uniter/runner/debug"
+)
+
+var logger = loggo.GetLogger("juju.worker.debughooks")
+
+// Listener is implemented by connections to the API server through
+// which clients start debug-hooks sessions.
+type Listener interface {
+	io.ReadWriteCloser
+
+	// Accept waits for a client to start a session, and returns
+	// the names of the hooks to debug.
+	Accept() ([]string, error)
+}
+
+// ListenFunc connects to the API server to wait for a debug-hooks
+// session.
+type ListenFunc func() (Listener, error)
+
+type acceptResult struct {
+	hooks []string
+	err   error
+}
+
+// New starts a worker which waits for clients to start debug-hooks
+// sessions with the given unit through the API server, and makes each
+// session available to the unit's hook runner while it lasts.
+func New(unitName string, listen ListenFunc) worker.Worker {
+	debugctx := debug.NewHooksContext(unitName)
+	loop := func(stop <-chan struct{}) error {
+		for {
+			listener, err := listen()
+			if err != nil {
+				return errors.Annotate(err, "cannot wait for debug-hooks sessions")
+			}
+			accepted := make(chan acceptResult, 1)
+			go func() {
+				hooks, err := listener.Accept()
+				accepted <- acceptResult{hooks, err}
+			}()
+			var result acceptResult
+			select {
+			case result = <-accepted:
+			case <-stop:
+				listener.Close()
+				return nil
+			}
+			if result.err != nil {
+				listener.Close()
+				return errors.Trace(result.err)
+			}
+			logger.Infof("debug-hooks session started for hooks %v", result.hooks)
+			session := debugctx.StartBrokeredSession(result.hooks, listener)
+			select {
+			case <-session.Done():
+				logger.Infof("debug-hooks session ended")
+			case <-stop:
+				session.Close()
+				return nil
+			}
+		}
+	}
+	return worker.NewSimpleWorker(loop)
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debughooks_test
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/debughooks"
+	"github.com/juju/juju/worker/uniter/runner/debug"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+// mockListener stands in for a connection to the API server, through
+// which the test plays the part of the client.
+type mockListener struct {
+	io.Reader
+	io.Writer
+	client io.ReadWriteCloser
+	hooks  chan []string
+	closed chan struct{}
+}
+
+func newMockListener() *mockListener {
+	fromClient, toAgent := io.Pipe()
+	fromAgent, toClient := io.Pipe()
+	return &mockListener{
+		Reader: fromClient,
+		Writer: toClient,
+		client: struct {
+			io.Reader
+			io.WriteCloser
+		}{fromAgent, toAgent},
+		hooks:  make(chan []string, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *mockListener) Accept() ([]string, error) {
+	select {
+	case hooks := <-l.hooks:
+		return hooks, nil
+	case <-l.closed:
+		return nil, errors.New("listener closed")
+	}
+}
+
+func (l *mockListener) Close() error {
+	select {
+	case <-l.closed:
+	default:
+		close(l.closed)
+		l.Reader.(*io.PipeReader).Close()
+		l.Writer.(*io.PipeWriter).Close()
+	}
+	return nil
+}
+
+func (s *workerSuite) TestSessions(c *gc.C) {
+	listeners := make(chan *mockListener, 2)
+	w := debughooks.New("mysql/0", func() (debughooks.Listener, error) {
+		l := newMockListener()
+		listeners <- l
+		return l, nil
+	})
+	defer func() {
+		w.Kill()
+		c.Assert(w.Wait(), jc.ErrorIsNil)
+	}()
+	debugctx := debug.NewHooksContext("mysql/0")
+
+	l := s.nextListener(c, listeners)
+	c.Assert(debugctx.FindBrokeredSession(), gc.IsNil)
+	l.hooks <- []string{"install"}
+	buf := make([]byte, 1024)
+	n, err := l.client.Read(buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(buf[:n]), gc.Equals, "Connected to mysql/0. Waiting for a hook to debug...\n")
+	session := debugctx.FindBrokeredSession()
+	c.Assert(session, gc.NotNil)
+	c.Assert(session.MatchHook("install"), jc.IsTrue)
+	c.Assert(session.MatchHook("start"), jc.IsFalse)
+
+	// When the client disconnects, the session ends
+	// and the worker waits for another.
+	l.client.Close()
+	select {
+	case <-session.Done():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for session to end")
+	}
+	c.Assert(debugctx.FindBrokeredSession(), gc.IsNil)
+	s.nextListener(c, listeners)
+}
+
+func (s *workerSuite) TestStopClosesListener(c *gc.C) {
+	listeners := make(chan *mockListener, 1)
+	w := debughooks.New("mysql/0", func() (debughooks.Listener, error) {
+		l := newMockListener()
+		listeners <- l
+		return l, nil
+	})
+	l := s.nextListener(c, listeners)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	select {
+	case <-l.closed:
+	default:
+		c.Fatalf("listener not closed")
+	}
+}
+
+func (s *workerSuite) TestListenError(c *gc.C) {
+	w := debughooks.New("mysql/0", func() (debughooks.Listener, error) {
+		return nil, errors.New("boom")
+	})
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot wait for debug-hooks sessions: boom")
+}
+
+func (s *workerSuite) nextListener(c *gc.C, listeners <-chan *mockListener) *mockListener {
+	select {
+	case l := <-listeners:
+		return l
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for listener")
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,488 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dependency
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.dependency")
+
+// errAborted is returned by a worker's start goroutine when the engine
+// stops before the worker is started.
+var errAborted = errors.New("aborted while delaying")
+
+// EngineConfig defines the parameters needed to create a new engine.
+type EngineConfig struct {
+	// IsFatal returns whether an error returned by a worker should
+	// stop the engine and all its workers.
+	IsFatal func(err error) bool
+
+	// MoreImportant returns whether err0 is more important than
+	// err1; the engine stops with the most important fatal error.
+	MoreImportant func(err0, err1 error) bool
+
+	// ErrorDelay is the time to wait before restarting a worker
+	// which failed; the delay is doubled each time the worker fails
+	// again, up to MaxErrorDelay. A worker which ran for at least
+	// MaxErrorDelay before failing is restarted after ErrorDelay.
+	ErrorDelay    time.Duration
+	MaxErrorDelay time.Duration
+
+	// BounceDelay is the time to wait before restarting a worker
+	// which stopped without error.
+	BounceDelay time.Duration
+}
+
+// Validate returns an error if the config cannot be used to create an
+// engine.
+func (config *EngineConfig) Validate() error {
+	if config.IsFatal == nil {
+		return errors.NotValidf("nil IsFatal")
+	}
+	if config.MoreImportant == nil {
+		return errors.NotValidf("nil MoreImportant")
+	}
+	if config.ErrorDelay < 0 {
+		return errors.NotValidf("negative ErrorDelay")
+	}
+	if config.MaxErrorDelay < config.ErrorDelay {
+		return errors.NotValidf("MaxErrorDelay shorter than ErrorDelay")
+	}
+	if config.BounceDelay < 0 {
+		return errors.NotValidf("negative BounceDelay")
+	}
+	return nil
+}
+
+// NewEngine returns an Engine that runs the workers of the manifolds
+// installed in it, as configured.
+func NewEngine(config EngineConfig) (Engine, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	engine := &engine{
+		config:     config,
+		manifolds:  make(Manifolds),
+		dependents: make(map[string][]string),
+		current:    make(map[string]workerInfo),
+		install:    make(chan installTicket),
+		report:     make(chan chan EngineReport),
+		started:    make(chan startedTicket),
+		stopped:    make(chan stoppedTicket),
+	}
+	go func() {
+		defer engine.tomb.Done()
+		engine.tomb.Kill(engine.loop())
+	}()
+	return engine, nil
+}
+
+// engine implements Engine. All its fields other than tomb and config
+// are only accessed by the loop goroutine.
+type engine struct {
+	tomb   tomb.Tomb
+	config EngineConfig
+
+	// worstError holds the most important fatal error returned by
+	// a worker.
+	worstError error
+
+	// manifolds holds the installed manifolds, dependents the names
+	// of the manifolds which have each manifold as an input, and
+	// current the state of each manifold's worker.
+	manifolds  Manifolds
+	dependents map[string][]string
+	current    map[string]workerInfo
+
+	install chan installTicket
+	report  chan chan EngineReport
+	started chan startedTicket
+	stopped chan stoppedTicket
+}
+
+// Install is part of the Engine interface.
+func (engine *engine) Install(name string, manifold Manifold) error {
+	result := make(chan error)
+	select {
+	case <-engine.tomb.Dying():
+		return errors.New("engine is shutting down")
+	case engine.install <- installTicket{name, manifold, result}:
+		return <-result
+	}
+}
+
+// Report is part of the Engine interface.
+func (engine *engine) Report() EngineReport {
+	result := make(chan EngineReport)
+	select {
+	case <-engine.tomb.Dead():
+		report := EngineReport{State: "stopped"}
+		if err := engine.tomb.Err(); err != nil {
+			report.Error = err.Error()
+		}
+		return report
+	case engine.report <- result:
+		return <-result
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (engine *engine) Kill() {
+	engine.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (engine *engine) Wait() error {
+	return engine.tomb.Wait()
+}
+
+func (engine *engine) loop() error {
+	dying := engine.tomb.Dying()
+	for {
+		select {
+		case <-dying:
+			dying = nil
+			for name := range engine.current {
+				engine.requestStop(name)
+			}
+		case ticket := <-engine.install:
+			ticket.result <- engine.gotInstall(ticket.name, ticket.manifold)
+		case result := <-engine.report:
+			result <- engine.liveReport()
+		case ticket := <-engine.started:
+			engine.gotStarted(ticket.name, ticket.worker)
+		case ticket := <-engine.stopped:
+			engine.gotStopped(ticket.name, ticket.err, ticket.ran)
+		}
+		if dying == nil && engine.allStopped() {
+			return engine.worstError
+		}
+	}
+}
+
+// isDying returns whether the engine is shutting down.
+func (engine *engine) isDying() bool {
+	select {
+	case <-engine.tomb.Dying():
+		return true
+	default:
+		return false
+	}
+}
+
+// allStopped returns whether no worker is starting or running.
+func (engine *engine) allStopped() bool {
+	for _, info := range engine.current {
+		if !info.stopped() {
+			return false
+		}
+	}
+	return true
+}
+
+// gotInstall records the manifold and starts its worker.
+func (engine *engine) gotInstall(name string, manifold Manifold) error {
+	logger.Debugf("installing %q manifold", name)
+	if engine.isDying() {
+		return errors.New("engine is shutting down")
+	}
+	if _, found := engine.manifolds[name]; found {
+		return errors.Errorf("%q manifold already installed", name)
+	}
+	if err := engine.checkAcyclic(name, manifold); err != nil {
+		return errors.Trace(err)
+	}
+	engine.manifolds[name] = manifold
+	for _, input := range manifold.Inputs {
+		engine.dependents[input] = append(engine.dependents[input], name)
+	}
+	engine.current[name] = workerInfo{}
+	engine.requestStart(name, 0)
+	return nil
+}
+
+// checkAcyclic returns an error if installing the manifold under the
+// given name would create a dependency cycle.
+func (engine *engine) checkAcyclic(name string, manifold Manifold) error {
+	checked := make(map[string]bool)
+	var check func(inputs []string) error
+	check = func(inputs []string) error {
+		for _, input := range inputs {
+			if input == name {
+				return errors.Errorf("cannot install %q manifold: dependency cycle", name)
+			}
+			if checked[input] {
+				continue
+			}
+			checked[input] = true
+			if err := check(engine.manifolds[input].Inputs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(manifold.Inputs)
+}
+
+// requestStart starts the named worker after the given delay, unless
+// it is already starting or running.
+func (engine *engine) requestStart(name string, delay time.Duration) {
+	info := engine.current[name]
+	if !info.stopped() || engine.isDying() {
+		return
+	}
+	info.starting = true
+	engine.current[name] = info
+	manifold := engine.manifolds[name]
+	getResource := engine.getResourceFunc(manifold.Inputs)
+	go engine.runWorker(name, delay, manifold.Start, getResource)
+}
+
+// requestStop stops the named worker, if it is starting or running.
+// A worker which is stopped by request is restarted immediately unless
+// the engine is shutting down.
+func (engine *engine) requestStop(name string) {
+	info := engine.current[name]
+	if info.stopped() || info.stopping {
+		return
+	}
+	info.stopping = true
+	engine.current[name] = info
+	if info.worker != nil {
+		info.worker.Kill()
+	}
+}
+
+// getResourceFunc returns a GetResourceFunc which exposes the
+// resources of the given inputs that are currently running.
+func (engine *engine) getResourceFunc(inputs []string) GetResourceFunc {
+	declared := make(map[string]bool)
+	workers := make(map[string]worker.Worker)
+	outputs := make(map[string]OutputFunc)
+	for _, input := range inputs {
+		declared[input] = true
+		info := engine.current[input]
+		if info.worker == nil || info.stopping {
+			continue
+		}
+		workers[input] = info.worker
+		outputs[input] = engine.manifolds[input].Output
+	}
+	return func(name string, out interface{}) error {
+		if !declared[name] {
+			return errors.Errorf("%q not declared as an input", name)
+		}
+		w, found := workers[name]
+		if !found {
+			return ErrMissing
+		}
+		if out == nil {
+			return nil
+		}
+		output := outputs[name]
+		if output == nil {
+			return errors.Errorf("%q exposes no resources", name)
+		}
+		return output(w, out)
+	}
+}
+
+// runWorker starts the named worker after the given delay, and waits
+// for it to stop, reporting both to the loop goroutine.
+func (engine *engine) runWorker(name string, delay time.Duration, start StartFunc, getResource GetResourceFunc) {
+	w, err := func() (worker.Worker, error) {
+		select {
+		case <-time.After(delay):
+		case <-engine.tomb.Dying():
+			return nil, errAborted
+		}
+		logger.Debugf("starting %q manifold worker", name)
+		return start(getResource)
+	}()
+	if err != nil {
+		engine.stopped <- stoppedTicket{name: name, err: err}
+		return
+	}
+	engine.started <- startedTicket{name: name, worker: w}
+	startTime := time.Now()
+	err = w.Wait()
+	engine.stopped <- stoppedTicket{name: name, err: err, ran: time.Since(startTime)}
+}
+
+// gotStarted records the worker started for the named manifold, and
+// restarts the workers which depend on it.
+func (engine *engine) gotStarted(name string, w worker.Worker) {
+	logger.Debugf("%q manifold worker started", name)
+	info := engine.current[name]
+	info.starting = false
+	info.worker = w
+	info.err = nil
+	info.startCount++
+	if engine.isDying() || info.stopping {
+		info.stopping = true
+		engine.current[name] = info
+		w.Kill()
+		return
+	}
+	engine.current[name] = info
+	engine.bounceDependents(name)
+}
+
+// gotStopped records that the named manifold's worker has stopped, or
+// failed to start, and arranges for it to be restarted.
+func (engine *engine) gotStopped(name string, err error, ran time.Duration) {
+	logger.Debugf("%q manifold worker stopped: %v", name, err)
+	info := engine.current[name]
+	wasRunning := info.worker != nil
+	wasStopping := info.stopping
+	info.starting = false
+	info.stopping = false
+	info.worker = nil
+	info.err = err
+	engine.current[name] = info
+
+	if err != nil && engine.config.IsFatal(err) {
+		if engine.worstError == nil || engine.config.MoreImportant(err, engine.worstError) {
+			engine.worstError = err
+		}
+		engine.tomb.Kill(nil)
+		return
+	}
+	if engine.isDying() {
+		return
+	}
+	if wasRunning {
+		engine.bounceDependents(name)
+	}
+
+	var delay time.Duration
+	switch {
+	case wasStopping:
+		// The worker was stopped because its inputs changed.
+	case errors.Cause(err) == ErrMissing:
+		// The worker will be started when an input starts.
+		return
+	case err == nil:
+		info.failures = 0
+		delay = engine.config.BounceDelay
+	default:
+		if ran >= engine.config.MaxErrorDelay {
+			info.failures = 0
+		}
+		info.failures++
+		delay = engine.errorDelay(info.failures)
+		logger.Errorf("%q manifold worker failed: %v; restarting in %v", name, err, delay)
+	}
+	engine.current[name] = info
+	engine.requestStart(name, delay)
+}
+
+// errorDelay returns the time to wait before restarting a worker which
+// has failed the given number of times in a row.
+func (engine *engine) errorDelay(failures int) time.Duration {
+	delay := engine.config.ErrorDelay
+	for i := 1; i < failures && delay < engine.config.MaxErrorDelay; i++ {
+		delay *= 2
+	}
+	if delay > engine.config.MaxErrorDelay {
+		delay = engine.config.MaxErrorDelay
+	}
+	return delay
+}
+
+// bounceDependents restarts the workers which depend on the named
+// manifold, so that they see its current resources.
+func (engine *engine) bounceDependents(name string) {
+	running := engine.current[name].worker != nil
+	for _, dependent := range engine.dependents[name] {
+		if _, installed := engine.manifolds[dependent]; !installed {
+			continue
+		}
+		if engine.current[dependent].stopped() {
+			if running {
+				engine.requestStart(dependent, 0)
+			}
+			continue
+		}
+		engine.requestStop(dependent)
+	}
+}
+
+// liveReport returns a report on the running engine.
+func (engine *engine) liveReport() EngineReport {
+	report := EngineReport{
+		State:     "started",
+		Manifolds: make(map[string]WorkerReport),
+	}
+	if engine.isDying() {
+		report.State = "stopping"
+	}
+	if engine.worstError != nil {
+		report.Error = engine.worstError.Error()
+	}
+	for name, info := range engine.current {
+		workerReport := WorkerReport{
+			State:      info.state(),
+			Inputs:     engine.manifolds[name].Inputs,
+			StartCount: info.startCount,
+		}
+		if info.err != nil {
+			workerReport.Error = info.err.Error()
+		}
+		report.Manifolds[name] = workerReport
+	}
+	return report
+}
+
+// workerInfo holds the state of a manifold's worker.
+type workerInfo struct {
+	starting   bool
+	stopping   bool
+	worker     worker.Worker
+	err        error
+	startCount int
+	failures   int
+}
+
+// stopped returns whether the worker is neither starting nor running.
+func (info workerInfo) stopped() bool {
+	return !info.starting && info.worker == nil
+}
+
+// state returns the state of the worker as shown in a WorkerReport.
+func (info workerInfo) state() string {
+	switch {
+	case info.stopping:
+		return "stopping"
+	case info.starting:
+		return "starting"
+	case info.worker != nil:
+		return "started"
+	}
+	return "stopped"
+}
+
+type installTicket struct {
+	name     string
+	manifold Manifold
+	result   chan<- error
+}
+
+type startedTicket struct {
+	name   string
+	worker worker.Worker
+}
+
+type stoppedTicket struct {
+	name string
+	err  error
+	ran  time.Duration
+}
@@ -0,0 +1,354 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dependency_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"launchpad.net/tomb"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+type EngineSuite struct {
+	coretesting.BaseSuite
+	engine dependency.Engine
+}
+
+var _ = gc.Suite(&EngineSuite{})
+
+var errFatal = errors.New("fatal")
+
+func (s *EngineSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.engine = s.newEngine(c)
+}
+
+func (s *EngineSuite) TearDownTest(c *gc.C) {
+	worker.Stop(s.engine)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *EngineSuite) newEngine(c *gc.C) dependency.Engine {
+	engine, err := dependency.NewEngine(dependency.EngineConfig{
+		IsFatal: func(err error) bool {
+			return err == errFatal
+		},
+		MoreImportant: func(err0, err1 error) bool {
+			return true
+		},
+		ErrorDelay:    time.Millisecond,
+		MaxErrorDelay: 10 * time.Millisecond,
+		BounceDelay:   time.Millisecond,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return engine
+}
+
+func (s *EngineSuite) TestNewEngineInvalid(c *gc.C) {
+	_, err := dependency.NewEngine(dependency.EngineConfig{})
+	c.Assert(err, gc.ErrorMatches, "nil IsFatal not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	_, err = dependency.NewEngine(dependency.EngineConfig{
+		IsFatal:       func(error) bool { return false },
+		MoreImportant: func(error, error) bool { return false },
+		ErrorDelay:    time.Second,
+	})
+	c.Assert(err, gc.ErrorMatches, "MaxErrorDelay shorter than ErrorDelay not valid")
+}
+
+func (s *EngineSuite) TestInstallStartsWorker(c *gc.C) {
+	mh := newManifoldHarness()
+	err := s.engine.Install("a", mh.Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	mh.AssertOneStart(c)
+}
+
+func (s *EngineSuite) TestInstallAlreadyInstalled(c *gc.C) {
+	mh := newManifoldHarness()
+	err := s.engine.Install("a", mh.Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.engine.Install("a", mh.Manifold())
+	c.Assert(err, gc.ErrorMatches, `"a" manifold already installed`)
+}
+
+func (s *EngineSuite) TestInstallCycle(c *gc.C) {
+	err := s.engine.Install("a", newManifoldHarness("b").Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.engine.Install("b", newManifoldHarness("a").Manifold())
+	c.Assert(err, gc.ErrorMatches, `cannot install "b" manifold: dependency cycle`)
+	err = s.engine.Install("c", newManifoldHarness("c").Manifold())
+	c.Assert(err, gc.ErrorMatches, `cannot install "c" manifold: dependency cycle`)
+}
+
+func (s *EngineSuite) TestInstallAfterKill(c *gc.C) {
+	c.Assert(worker.Stop(s.engine), jc.ErrorIsNil)
+	err := s.engine.Install("a", newManifoldHarness().Manifold())
+	c.Assert(err, gc.ErrorMatches, "engine is shutting down")
+}
+
+func (s *EngineSuite) TestInstallAll(c *gc.C) {
+	mh1 := newManifoldHarness()
+	mh2 := newManifoldHarness("a")
+	err := dependency.Install(s.engine, dependency.Manifolds{
+		"a": mh1.Manifold(),
+		"b": mh2.Manifold(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	mh1.AssertOneStart(c)
+	mh2.AssertOneStart(c)
+
+	err = dependency.Install(s.engine, dependency.Manifolds{
+		"a": mh1.Manifold(),
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot install "a" manifold: "a" manifold already installed`)
+}
+
+func (s *EngineSuite) TestMissingInputWaits(c *gc.C) {
+	mh2 := newManifoldHarness("a")
+	err := s.engine.Install("b", mh2.Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	mh2.AssertNoStart(c)
+
+	mh1 := newManifoldHarness()
+	err = s.engine.Install("a", mh1.Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	mh1.AssertOneStart(c)
+	mh2.AssertOneStart(c)
+}
+
+func (s *EngineSuite) TestOutput(c *gc.C) {
+	err := s.engine.Install("a", dependency.Manifold{
+		Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+			return newTestWorker(), nil
+		},
+		Output: func(in worker.Worker, out interface{}) error {
+			outPtr, ok := out.(*string)
+			if !ok {
+				return errors.Errorf("expected *string, got %T", out)
+			}
+			*outPtr = "hello"
+			return nil
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results := make(chan []interface{}, 1)
+	err = s.engine.Install("b", dependency.Manifold{
+		Inputs: []string{"a"},
+		Start: func(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+			var value string
+			err := getResource("a", &value)
+			var wrongType int
+			wrongTypeErr := getResource("a", &wrongType)
+			undeclaredErr := getResource("c", nil)
+			results <- []interface{}{err, value, wrongTypeErr, undeclaredErr}
+			if err != nil {
+				return nil, err
+			}
+			return newTestWorker(), nil
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for {
+		select {
+		case result := <-results:
+			if result[0] == dependency.ErrMissing {
+				// "b" was started before "a"; it will be
+				// started again once "a" is running.
+				continue
+			}
+			c.Assert(result[0], gc.IsNil)
+			c.Assert(result[1], gc.Equals, "hello")
+			c.Assert(result[2], gc.ErrorMatches, `expected \*string, got \*int`)
+			c.Assert(result[3], gc.ErrorMatches, `"c" not declared as an input`)
+			return
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("dependent never started")
+		}
+	}
+}
+
+func (s *EngineSuite) TestErrorRestartsWorker(c *gc.C) {
+	mh := newManifoldHarness()
+	err := s.engine.Install("a", mh.Manifold())
+	c.Assert(err, jc.ErrorIsNil)
+	w := mh.AssertOneStart(c)
+
+	w.stop(errors.New("splat"))
+	mh.AssertOneStart(c)
+	report := s.engine.Report()
+	c.Assert(report.Manifolds["a"].StartCount, gc.Equals, 2)
+}
+
+func (s *EngineSuite) TestStartErrorRetries(c *gc.C) {
+	starts := make(chan struct{}, 10)
+	err := s.engine.Install("a", dependency.Manifold{
+		Start: func(dependency.GetResourceFunc) (worker.Worker, error) {
+			starts <- struct{}{}
+			return nil, errors.New("cannot start")
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-starts:
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("start not retried")
+		}
+	}
+	report := s.engine.Report()
+	c.Assert(report.Manifolds["a"].StartCount, gc.Equals, 0)
+	c.Assert(report.Manifolds["a"].Error, gc.Equals, "cannot start")
+}
+
+func (s *EngineSuite) TestInputRestartBouncesDependents(c *gc.C) {
+	mh1 := newManifoldHarness()
+	mh2 := newManifoldHarness("a")
+	mh3 := newManifoldHarness()
+	err := dependency.Install(s.engine, dependency.Manifolds{
+		"a": mh1.Manifold(),
+		"b": mh2.Manifold(),
+		"c": mh3.Manifold(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	w1 := mh1.AssertOneStart(c)
+	w2 := mh2.AssertOneStart(c)
+	mh3.AssertOneStart(c)
+
+	w1.stop(errors.New("splat"))
+	c.Assert(w2.Wait(), jc.ErrorIsNil)
+	mh1.AssertOneStart(c)
+	mh2.AssertOneStart(c)
+	mh3.AssertNoStart(c)
+}
+
+func (s *EngineSuite) TestFatalErrorStopsEngine(c *gc.C) {
+	mh1 := newManifoldHarness()
+	mh2 := newManifoldHarness()
+	err := dependency.Install(s.engine, dependency.Manifolds{
+		"a": mh1.Manifold(),
+		"b": mh2.Manifold(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	w1 := mh1.AssertOneStart(c)
+	w2 := mh2.AssertOneStart(c)
+
+	w1.stop(errFatal)
+	c.Assert(s.engine.Wait(), gc.Equals, errFatal)
+	c.Assert(w2.Wait(), jc.ErrorIsNil)
+	c.Assert(s.engine.Report(), jc.DeepEquals, dependency.EngineReport{
+		State: "stopped",
+		Error: "fatal",
+	})
+}
+
+func (s *EngineSuite) TestReport(c *gc.C) {
+	mh1 := newManifoldHarness()
+	mh2 := newManifoldHarness("a", "x")
+	err := dependency.Install(s.engine, dependency.Manifolds{
+		"a": mh1.Manifold(),
+		"b": mh2.Manifold(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	mh1.AssertOneStart(c)
+	mh2.AssertNoStart(c)
+
+	c.Assert(s.engine.Report(), jc.DeepEquals, dependency.EngineReport{
+		State: "started",
+		Manifolds: map[string]dependency.WorkerReport{
+			"a": {
+				State:      "started",
+				StartCount: 1,
+			},
+			"b": {
+				State:  "stopped",
+				Inputs: []string{"a", "x"},
+				Error:  "dependency not available",
+			},
+		},
+	})
+}
+
+// manifoldHarness implements a manifold whose workers require all
+// its inputs to be running, and records the workers it starts.
+type manifoldHarness struct {
+	inputs []string
+	starts chan *testWorker
+}
+
+func newManifoldHarness(inputs ...string) *manifoldHarness {
+	return &manifoldHarness{
+		inputs: inputs,
+		starts: make(chan *testWorker, 100),
+	}
+}
+
+func (mh *manifoldHarness) Manifold() dependency.Manifold {
+	return dependency.Manifold{
+		Inputs: mh.inputs,
+		Start:  mh.start,
+	}
+}
+
+func (mh *manifoldHarness) start(getResource dependency.GetResourceFunc) (worker.Worker, error) {
+	for _, input := range mh.inputs {
+		if err := getResource(input, nil); err != nil {
+			return nil, err
+		}
+	}
+	w := newTestWorker()
+	mh.starts <- w
+	return w, nil
+}
+
+func (mh *manifoldHarness) AssertOneStart(c *gc.C) *testWorker {
+	select {
+	case w := <-mh.starts:
+		return w
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("worker never started")
+	}
+	panic("unreachable")
+}
+
+func (mh *manifoldHarness) AssertNoStart(c *gc.C) {
+	select {
+	case <-mh.starts:
+		c.Fatalf("unexpected worker start")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+type testWorker struct {
+	tomb tomb.Tomb
+}
+
+func newTestWorker() *testWorker {
+	w := &testWorker{}
+	go func() {
+		defer w.tomb.Done()
+		<-w.tomb.Dying()
+	}()
+	return w
+}
+
+func (w *testWorker) stop(err error) {
+	w.tomb.Kill(err)
+}
+
+func (w *testWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+func (w *testWorker) Wait() error {
+	return w.tomb.Wait()
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dependency
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/worker"
+)
+
+// Engine is a mechanism for running workers which depend on the
+// resources provided by other workers.
+//
+// Each worker is installed in the engine as a named Manifold, which
+// declares the names of the manifolds it depends on. A worker is only
+// started when the resources it asks for are available; whenever one
+// of its inputs is started or stopped, the worker is stopped and started
+// again, so that it always sees the current resources. A worker which
+// stops is restarted independently of the others, after a delay which
+// grows while it keeps failing.
+type Engine interface {
+	// Install causes the engine to run the manifold's worker, under
+	// the given name, as soon as its inputs are available.
+	Install(name string, manifold Manifold) error
+
+	// Report returns the current state of the engine and of each of
+	// its installed manifolds.
+	Report() EngineReport
+
+	// Engine is a worker: it stops when killed, or when any of its
+	// workers fails with an error that is fatal to the engine.
+	worker.Worker
+}
+
+// Manifold defines the behaviour of a worker run by an Engine.
+type Manifold struct {
+	// Inputs lists the names of the manifolds whose resources the
+	// worker may ask for when it starts.
+	Inputs []string
+
+	// Start starts the worker, which may use getResource to
+	// retrieve the resources of its inputs; getResource returns
+	// ErrMissing for an input which is not running, and Start should
+	// usually return that error unchanged.
+	Start StartFunc
+
+	// Output, if set, exposes the worker's resources to the workers
+	// which depend on it.
+	Output OutputFunc
+}
+
+// Manifolds conveniently holds a set of named manifolds.
+type Manifolds map[string]Manifold
+
+// StartFunc starts a worker, given access to the resources of the
+// manifold's inputs.
+type StartFunc func(getResource GetResourceFunc) (worker.Worker, error)
+
+// GetResourceFunc sets the value pointed to by out to the resource
+// exposed by the named input. If out is nil, it only reports whether
+// the input is running.
+type GetResourceFunc func(name string, out interface{}) error
+
+// OutputFunc sets the value pointed to by out to a resource exposed by
+// the given worker, which was started by the same manifold. It should
+// return an error if out is not a pointer to a supported type.
+type OutputFunc func(in worker.Worker, out interface{}) error
+
+// ErrMissing is returned by a GetResourceFunc when the requested input
+// is not running. A worker whose StartFunc returns ErrMissing is not
+// restarted until one of its inputs starts.
+var ErrMissing = errors.New("dependency not available")
+
+// Install installs each of the manifolds in the engine, stopping at the
+// first error.
+func Install(engine Engine, manifolds Manifolds) error {
+	names := make([]string, 0, len(manifolds))
+	for name := range manifolds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := engine.Install(name, manifolds[name]); err != nil {
+			return errors.Annotatef(err, "cannot install %q manifold", name)
+		}
+	}
+	return nil
+}
+
+// EngineReport describes the state of an Engine.
+type EngineReport struct {
+	// State is one of "started", "stopping" and "stopped".
+	State string `json:"state" yaml:"state"`
+
+	// Error holds the error the engine stopped with, if any.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// Manifolds describes each installed manifold, keyed by name.
+	Manifolds map[string]WorkerReport `json:"manifolds,omitempty" yaml:"manifolds,omitempty"`
+}
+
+// WorkerReport describes the state of the worker run for a manifold.
+type WorkerReport struct {
+	// State is one of "starting", "started", "stopping" and
+	// "stopped".
+	State string `json:"state" yaml:"state"`
+
+	// Inputs holds the names of the manifold's inputs.
+	Inputs []string `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+
+	// Error holds the error returned when the worker last stopped,
+	// or failed to start.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// StartCount holds the number of times the worker has been
+	// started.
+	StartCount int `json:"start-count" yaml:"start-count"`
+}
@@ -16,6 +16,7 @@ import (
 	apideployer "github.com/juju/juju/api/deployer"
 	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/worker"
 )
 
@@ -35,9 +36,11 @@ type Deployer struct {
 // is responsible for how to deploy.
 type Context interface {
 	// DeployUnit causes the agent for the specified unit to be started and run
-	// continuously until further notice without further intervention. It will
-	// return an error if the agent is already deployed.
-	DeployUnit(unitName, initialPassword string) error
+	// continuously until further notice without further intervention. If
+	// workloadContainer is not instance.NONE, the unit's charm is run in a
+	// container of that type, which lives as long as the unit is deployed.
+	// It will return an error if the agent is already deployed.
+	DeployUnit(unitName, initialPassword string, workloadContainer instance.ContainerType) error
 
 	// RecallUnit causes the agent for the specified unit to be stopped, and
 	// the agent's data to be destroyed. It will return an error if the agent
@@ -153,7 +156,11 @@ func (d *Deployer) deploy(unit *apideployer.Unit) error {
 	if err := unit.SetPassword(initialPassword); err != nil {
 		return fmt.Errorf("cannot set password for unit %q: %v", unitName, err)
 	}
-	if err := d.ctx.DeployUnit(unitName, initialPassword); err != nil {
+	workloadContainer, err := unit.WorkloadContainer()
+	if err != nil {
+		return fmt.Errorf("cannot get workload container for unit %q: %v", unitName, err)
+	}
+	if err := d.ctx.DeployUnit(unitName, initialPassword, workloadContainer); err != nil {
 		return err
 	}
 	d.deployed.Add(unitName)
@@ -4,6 +4,8 @@
 package deployer
 
 import (
+	"github.com/juju/errors"
+
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/service"
@@ -31,5 +33,22 @@ func NewTestSimpleContext(agentConfig agent.Config, logDir string, data *service
 		listServices: func() ([]string, error) {
 			return data.InstalledNames.Values(), nil
 		},
+		createWorkloadContainer: func(name, series, dataDir string) error {
+			return errors.Errorf("unexpected workload container %q", name)
+		},
+		destroyWorkloadContainer: func(name string) error {
+			return errors.Errorf("unexpected workload container %q", name)
+		},
 	}
 }
+
+// PatchWorkloadContainers replaces the functions ctx uses to create
+// and destroy workload containers.
+func PatchWorkloadContainers(
+	ctx *SimpleContext,
+	create func(name, series, dataDir string) error,
+	destroy func(name string) error,
+) {
+	ctx.createWorkloadContainer = create
+	ctx.destroyWorkloadContainer = destroy
+}
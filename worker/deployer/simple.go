@@ -15,6 +15,8 @@ import (
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/container/lxc"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/service"
 	"github.com/juju/juju/service/common"
 	"github.com/juju/juju/version"
@@ -43,6 +45,14 @@ type SimpleContext struct {
 
 	// listServices is a surrogate for service.ListServices.
 	listServices func() ([]string, error)
+
+	// createWorkloadContainer is a surrogate for
+	// lxc.CreateWorkloadContainer.
+	createWorkloadContainer func(name, series, dataDir string) error
+
+	// destroyWorkloadContainer is a surrogate for
+	// lxc.DestroyWorkloadContainer.
+	destroyWorkloadContainer func(name string) error
 }
 
 var _ Context = (*SimpleContext)(nil)
@@ -80,6 +90,8 @@ func NewSimpleContext(agentConfig agent.Config, api APICalls) *SimpleContext {
 		listServices: func() ([]string, error) {
 			return service.ListServices()
 		},
+		createWorkloadContainer:  lxc.CreateWorkloadContainer,
+		destroyWorkloadContainer: lxc.DestroyWorkloadContainer,
 	}
 }
 
@@ -87,7 +99,7 @@ func (ctx *SimpleContext) AgentConfig() agent.Config {
 	return ctx.agentConfig
 }
 
-func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err error) {
+func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string, workloadContainer instance.ContainerType) (err error) {
 	// Check sanity.
 	svc := ctx.service(unitName)
 	installed, err := svc.Installed()
@@ -97,6 +109,11 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 	if installed {
 		return fmt.Errorf("unit %q is already deployed", unitName)
 	}
+	switch workloadContainer {
+	case "", instance.NONE, instance.LXC:
+	default:
+		return errors.NotSupportedf("workload container type %q", workloadContainer)
+	}
 
 	// Link the current tools for use by the new agent.
 	tag := names.NewUnitTag(unitName)
@@ -116,6 +133,27 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 	logger.Debugf("API addresses: %q", result.APIAddresses)
 	containerType := ctx.agentConfig.Value(agent.ContainerType)
 	namespace := ctx.agentConfig.Value(agent.Namespace)
+	values := map[string]string{
+		agent.ContainerType: containerType,
+		agent.Namespace:     namespace,
+	}
+
+	// Create the container the unit's charm runs in, if it has one.
+	if workloadContainer == instance.LXC {
+		name := lxc.WorkloadContainerName(unitName)
+		logger.Infof("creating workload container %q for unit %q", name, unitName)
+		if err := ctx.createWorkloadContainer(name, version.Current.Series, dataDir); err != nil {
+			return errors.Annotatef(err, "cannot create workload container for unit %q", unitName)
+		}
+		defer func() {
+			if err != nil {
+				if err := ctx.destroyWorkloadContainer(name); err != nil {
+					logger.Warningf("installer: cannot destroy workload container %q: %v", name, err)
+				}
+			}
+		}()
+		values[agent.WorkloadContainer] = name
+	}
 	conf, err := agent.NewAgentConfig(
 		agent.AgentConfigParams{
 			DataDir:           dataDir,
@@ -129,10 +167,7 @@ func (ctx *SimpleContext) DeployUnit(unitName, initialPassword string) (err erro
 			StateAddresses: result.StateAddresses,
 			APIAddresses:   result.APIAddresses,
 			CACert:         ctx.agentConfig.CACert(),
-			Values: map[string]string{
-				agent.ContainerType: containerType,
-				agent.Namespace:     namespace,
-			},
+			Values:         values,
 		})
 	if err != nil {
 		return err
@@ -200,6 +235,15 @@ func (ctx *SimpleContext) RecallUnit(unitName string) error {
 	}
 	tag := names.NewUnitTag(unitName)
 	dataDir := ctx.agentConfig.DataDir()
+	// Destroy the container the unit's charm ran in, if it had one.
+	if conf, err := agent.ReadConfig(agent.ConfigPath(dataDir, tag)); err != nil {
+		logger.Warningf("cannot read agent config of unit %q: %v", unitName, err)
+	} else if name := conf.Value(agent.WorkloadContainer); name != "" {
+		logger.Infof("destroying workload container %q of unit %q", name, unitName)
+		if err := ctx.destroyWorkloadContainer(name); err != nil {
+			return errors.Annotatef(err, "cannot destroy workload container of unit %q", unitName)
+		}
+	}
 	agentDir := agent.Dir(dataDir, tag)
 	// Recursivley change mode to 777 on windows to avoid
 	// Operation not permitted errors when deleting the agentDir
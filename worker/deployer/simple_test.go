@@ -11,12 +11,15 @@ import (
 	"regexp"
 	"sort"
 
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/instance"
 	"github.com/juju/juju/service"
 	"github.com/juju/juju/service/common"
 	"github.com/juju/juju/service/upstart"
@@ -48,7 +51,7 @@ func (s *SimpleContextSuite) TestDeployRecall(c *gc.C) {
 	c.Assert(units, gc.HasLen, 0)
 	s.assertUpstartCount(c, 0)
 
-	err = mgr0.DeployUnit("foo/123", "some-password")
+	err = mgr0.DeployUnit("foo/123", "some-password", instance.NONE)
 	c.Assert(err, jc.ErrorIsNil)
 	units, err = mgr0.DeployedUnits()
 	c.Assert(err, jc.ErrorIsNil)
@@ -100,11 +103,11 @@ func (s *SimpleContextSuite) TestOldDeployedUnitsCanBeRecalled(c *gc.C) {
 	c.Assert(units, gc.DeepEquals, []string{"mysql/0", "nrpe/0"})
 
 	// Deploy some units.
-	err = manager.DeployUnit("principal/1", "some-password")
+	err = manager.DeployUnit("principal/1", "some-password", instance.NONE)
 	c.Assert(err, jc.ErrorIsNil)
 	s.checkUnitInstalled(c, "principal/1", "some-password")
 	s.assertUpstartCount(c, 3)
-	err = manager.DeployUnit("subordinate/2", "fake-password")
+	err = manager.DeployUnit("subordinate/2", "fake-password", instance.NONE)
 	c.Assert(err, jc.ErrorIsNil)
 	s.checkUnitInstalled(c, "subordinate/2", "fake-password")
 	s.assertUpstartCount(c, 4)
@@ -132,6 +135,49 @@ func (s *SimpleContextSuite) TestOldDeployedUnitsCanBeRecalled(c *gc.C) {
 	c.Assert(units, gc.HasLen, 0)
 }
 
+func (s *SimpleContextSuite) TestDeployRecallWorkloadContainer(c *gc.C) {
+	ctx := s.getContext(c)
+	containers := make(set.Strings)
+	deployer.PatchWorkloadContainers(ctx, func(name, series, dataDir string) error {
+		c.Check(series, gc.Equals, version.Current.Series)
+		c.Check(dataDir, gc.Equals, s.dataDir)
+		containers.Add(name)
+		return nil
+	}, func(name string) error {
+		containers.Remove(name)
+		return nil
+	})
+
+	err := ctx.DeployUnit("foo/123", "some-password", instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkUnitInstalled(c, "foo/123", "some-password")
+	c.Assert(containers.SortedValues(), jc.DeepEquals, []string{"juju-workload-unit-foo-123"})
+	conf, err := agent.ReadConfig(agent.ConfigPath(s.dataDir, names.NewUnitTag("foo/123")))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conf.Value(agent.WorkloadContainer), gc.Equals, "juju-workload-unit-foo-123")
+
+	err = ctx.RecallUnit("foo/123")
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkUnitRemoved(c, "foo/123")
+	c.Assert(containers, gc.HasLen, 0)
+}
+
+func (s *SimpleContextSuite) TestDeployWorkloadContainerError(c *gc.C) {
+	ctx := s.getContext(c)
+	deployer.PatchWorkloadContainers(ctx, func(name, series, dataDir string) error {
+		return errors.New("no lxc here")
+	}, nil)
+	err := ctx.DeployUnit("foo/123", "some-password", instance.LXC)
+	c.Assert(err, gc.ErrorMatches, `cannot create workload container for unit "foo/123": no lxc here`)
+	s.assertUpstartCount(c, 0)
+}
+
+func (s *SimpleContextSuite) TestDeployUnsupportedWorkloadContainer(c *gc.C) {
+	err := s.getContext(c).DeployUnit("foo/123", "some-password", instance.KVM)
+	c.Assert(err, gc.ErrorMatches, `workload container type "kvm" not supported`)
+	s.assertUpstartCount(c, 0)
+}
+
 type SimpleToolsFixture struct {
 	dataDir  string
 	logDir   string
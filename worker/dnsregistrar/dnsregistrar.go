@@ -0,0 +1,238 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The dnsregistrar package implements a worker which registers the
+// host names and addresses of the environment's machines and
+// containers in a DNS backend, keeps the records up to date as the
+// addresses change, and removes them when the machines are removed.
+package dnsregistrar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.dnsregistrar")
+
+// interval is how often the worker checks the addresses of machines.
+var interval = time.Minute
+
+// Backend registers the address records of host names in a DNS
+// service.
+type Backend interface {
+	// SetRecords replaces the address records of the given fully
+	// qualified host name with ones for the given addresses.
+	SetRecords(hostname string, addresses []network.Address) error
+
+	// RemoveRecords removes the address records of the given fully
+	// qualified host name.
+	RemoveRecords(hostname string) error
+}
+
+// openBackend returns the backend set by the dns-backend setting of
+// the given environment configuration, or nil if none is set.
+var openBackend = func(cfg *config.Config) (Backend, error) {
+	switch cfg.DNSBackend() {
+	case "":
+		return nil, nil
+	case config.DNSBackendNsupdate:
+		return newNsupdateBackend(cfg), nil
+	case config.DNSBackendDesignate:
+		env, err := environs.New(cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		registrar, ok := env.(environs.DNSRegistrar)
+		if !ok {
+			return nil, errors.NotSupportedf("%s backend with %q provider", cfg.DNSBackend(), cfg.Type())
+		}
+		return &environBackend{
+			registrar: registrar,
+			zone:      cfg.DNSZone(),
+			ttl:       cfg.DNSTTL(),
+		}, nil
+	}
+	return nil, errors.NotValidf("DNS backend %q", cfg.DNSBackend())
+}
+
+// NewWorker returns a worker which periodically registers the host
+// names and addresses of the machines in the given state in the DNS
+// backend set in the environment's configuration. It does nothing
+// while no backend is set.
+func NewWorker(st *state.State) worker.Worker {
+	return worker.NewPeriodicWorker(func(stop <-chan struct{}) error {
+		if err := registerMachines(st, stop); err != nil {
+			logger.Errorf("cannot register machine addresses: %v", err)
+		}
+		return nil
+	}, interval)
+}
+
+// registerMachines brings the records in the DNS backend up to date
+// with the addresses of the environment's machines. Records which
+// cannot be updated are retried next time.
+func registerMachines(st *state.State, stop <-chan struct{}) error {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	backend, err := openBackend(cfg)
+	if err != nil {
+		return errors.Annotate(err, "cannot open DNS backend")
+	}
+	if backend == nil {
+		return nil
+	}
+	records, err := st.DNSRecords()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	registered := make(map[string]state.DNSRecord)
+	for _, record := range records {
+		registered[record.MachineId] = record
+	}
+	machines, err := st.AllMachines()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	current := make(map[string]bool)
+	for _, machine := range machines {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		if machine.Life() == state.Dead {
+			continue
+		}
+		addresses := registrableAddresses(machine.Addresses())
+		if len(addresses) == 0 {
+			continue
+		}
+		current[machine.Id()] = true
+		record := state.DNSRecord{
+			MachineId: machine.Id(),
+			Hostname:  machineHostname(cfg, machine.Id()),
+			Addresses: addressValues(addresses),
+		}
+		existing, ok := registered[machine.Id()]
+		if ok && sameRecord(existing, record) {
+			continue
+		}
+		if ok && existing.Hostname != record.Hostname {
+			if err := backend.RemoveRecords(existing.Hostname); err != nil {
+				logger.Warningf("cannot remove DNS records of %s: %v", existing.Hostname, err)
+				continue
+			}
+		}
+		if err := backend.SetRecords(record.Hostname, addresses); err != nil {
+			logger.Warningf("cannot register %s in DNS: %v", record.Hostname, err)
+			continue
+		}
+		if err := st.SetDNSRecord(record); err != nil {
+			return errors.Trace(err)
+		}
+		logger.Infof("registered %s at %s", record.Hostname, strings.Join(record.Addresses, ", "))
+	}
+
+	for _, record := range records {
+		if current[record.MachineId] {
+			continue
+		}
+		if err := backend.RemoveRecords(record.Hostname); err != nil {
+			logger.Warningf("cannot remove DNS records of %s: %v", record.Hostname, err)
+			continue
+		}
+		if err := st.RemoveDNSRecord(record.MachineId); err != nil {
+			return errors.Trace(err)
+		}
+		logger.Infof("removed %s from DNS", record.Hostname)
+	}
+	return nil
+}
+
+// machineHostname returns the fully qualified host name of the machine
+// with the given id, such as "machine-0-lxc-1.myenv.example.com.".
+func machineHostname(cfg *config.Config, machineId string) string {
+	zone := strings.TrimSuffix(cfg.DNSZone(), ".")
+	return fmt.Sprintf("%s.%s.%s.", names.NewMachineTag(machineId), cfg.Name(), zone)
+}
+
+// registrableAddresses returns the IP addresses, sorted by value, that
+// can be reached from outside the machine.
+func registrableAddresses(addresses []network.Address) []network.Address {
+	var result []network.Address
+	seen := make(map[string]bool)
+	for _, addr := range addresses {
+		if addr.Type != network.IPv4Address && addr.Type != network.IPv6Address {
+			continue
+		}
+		if addr.Scope == network.ScopeMachineLocal || addr.Scope == network.ScopeLinkLocal {
+			continue
+		}
+		if seen[addr.Value] {
+			continue
+		}
+		seen[addr.Value] = true
+		result = append(result, addr)
+	}
+	sort.Sort(addressesByValue(result))
+	return result
+}
+
+type addressesByValue []network.Address
+
+func (a addressesByValue) Len() int           { return len(a) }
+func (a addressesByValue) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a addressesByValue) Less(i, j int) bool { return a[i].Value < a[j].Value }
+
+func addressValues(addresses []network.Address) []string {
+	values := make([]string, len(addresses))
+	for i, addr := range addresses {
+		values[i] = addr.Value
+	}
+	return values
+}
+
+func sameRecord(a, b state.DNSRecord) bool {
+	if a.Hostname != b.Hostname || len(a.Addresses) != len(b.Addresses) {
+		return false
+	}
+	for i := range a.Addresses {
+		if a.Addresses[i] != b.Addresses[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// environBackend registers records with the DNS service of the
+// environment's provider.
+type environBackend struct {
+	registrar environs.DNSRegistrar
+	zone      string
+	ttl       int
+}
+
+// SetRecords is part of the Backend interface.
+func (b *environBackend) SetRecords(hostname string, addresses []network.Address) error {
+	return b.registrar.SetDNSRecords(b.zone, hostname, b.ttl, addresses)
+}
+
+// RemoveRecords is part of the Backend interface.
+func (b *environBackend) RemoveRecords(hostname string) error {
+	return b.registrar.RemoveDNSRecords(b.zone, hostname)
+}
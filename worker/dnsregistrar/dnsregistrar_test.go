@@ -0,0 +1,216 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dnsregistrar_test
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker/dnsregistrar"
+)
+
+type registrarSuite struct {
+	jujutesting.JujuConnSuite
+	backend *fakeBackend
+}
+
+var _ = gc.Suite(&registrarSuite{})
+
+func (s *registrarSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.backend = &fakeBackend{records: make(map[string][]string)}
+	s.PatchValue(dnsregistrar.OpenBackend, func(cfg *config.Config) (dnsregistrar.Backend, error) {
+		if cfg.DNSBackend() == "" {
+			return nil, nil
+		}
+		return s.backend, nil
+	})
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"dns-backend": "nsupdate",
+		"dns-zone":    "example.com",
+		"dns-server":  "10.0.0.53",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *registrarSuite) hostname(c *gc.C, tag string) string {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	return fmt.Sprintf("%s.%s.example.com.", tag, cfg.Name())
+}
+
+func (s *registrarSuite) addMachine(c *gc.C, addresses ...network.Address) *state.Machine {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.SetAddresses(addresses...)
+	c.Assert(err, jc.ErrorIsNil)
+	return machine
+}
+
+func (s *registrarSuite) register(c *gc.C) {
+	err := dnsregistrar.RegisterMachines(s.State, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *registrarSuite) TestNoBackend(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(nil, []string{"dns-backend"}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.addMachine(c, network.NewAddress("10.0.0.2", network.ScopeCloudLocal))
+	s.register(c)
+	c.Assert(s.backend.records, gc.HasLen, 0)
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *registrarSuite) TestRegistersMachinesAndContainers(c *gc.C) {
+	host := s.addMachine(c,
+		network.NewAddress("10.0.0.2", network.ScopeCloudLocal),
+		network.NewAddress("127.0.0.1", network.ScopeMachineLocal),
+		network.NewAddress("fe80::1", network.ScopeLinkLocal),
+		network.NewAddress("2001:db8::2", network.ScopePublic),
+		network.NewAddress("host.example.org", network.ScopePublic),
+	)
+	container, err := s.State.AddMachineInsideMachine(state.MachineTemplate{
+		Series: "quantal",
+		Jobs:   []state.MachineJob{state.JobHostUnits},
+	}, host.Id(), instance.LXC)
+	c.Assert(err, jc.ErrorIsNil)
+	err = container.SetAddresses(network.NewAddress("10.0.3.2", network.ScopeCloudLocal))
+	c.Assert(err, jc.ErrorIsNil)
+	// Machines without addresses are not registered.
+	s.addMachine(c)
+
+	s.register(c)
+	c.Assert(s.backend.records, jc.DeepEquals, map[string][]string{
+		s.hostname(c, "machine-0"):       {"10.0.0.2", "2001:db8::2"},
+		s.hostname(c, "machine-0-lxc-0"): {"10.0.3.2"},
+	})
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.DNSRecord{{
+		MachineId: "0",
+		Hostname:  s.hostname(c, "machine-0"),
+		Addresses: []string{"10.0.0.2", "2001:db8::2"},
+	}, {
+		MachineId: "0/lxc/0",
+		Hostname:  s.hostname(c, "machine-0-lxc-0"),
+		Addresses: []string{"10.0.3.2"},
+	}})
+
+	// Registering again does not update unchanged records.
+	s.backend.calls = nil
+	s.register(c)
+	c.Assert(s.backend.calls, gc.HasLen, 0)
+}
+
+func (s *registrarSuite) TestUpdatesChangedAddresses(c *gc.C) {
+	machine := s.addMachine(c, network.NewAddress("10.0.0.2", network.ScopeCloudLocal))
+	s.register(c)
+
+	err := machine.SetAddresses(network.NewAddress("10.0.0.9", network.ScopeCloudLocal))
+	c.Assert(err, jc.ErrorIsNil)
+	s.backend.calls = nil
+	s.register(c)
+	hostname := s.hostname(c, "machine-0")
+	c.Assert(s.backend.calls, jc.DeepEquals, []string{"set " + hostname})
+	c.Assert(s.backend.records, jc.DeepEquals, map[string][]string{
+		hostname: {"10.0.0.9"},
+	})
+}
+
+func (s *registrarSuite) TestZoneChangeReplacesRecords(c *gc.C) {
+	s.addMachine(c, network.NewAddress("10.0.0.2", network.ScopeCloudLocal))
+	s.register(c)
+
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"dns-zone": "example.net"}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.register(c)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.backend.records, jc.DeepEquals, map[string][]string{
+		fmt.Sprintf("machine-0.%s.example.net.", cfg.Name()): {"10.0.0.2"},
+	})
+}
+
+func (s *registrarSuite) TestRemovesRecordsOfRemovedMachines(c *gc.C) {
+	machine := s.addMachine(c, network.NewAddress("10.0.0.2", network.ScopeCloudLocal))
+	s.register(c)
+
+	err := machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	s.register(c)
+	c.Assert(s.backend.records, gc.HasLen, 0)
+
+	err = machine.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.backend.calls = nil
+	s.register(c)
+	c.Assert(s.backend.calls, gc.HasLen, 0)
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *registrarSuite) TestBackendErrorRetried(c *gc.C) {
+	s.addMachine(c, network.NewAddress("10.0.0.2", network.ScopeCloudLocal))
+	s.backend.err = errors.New("SERVFAIL")
+	s.register(c)
+	records, err := s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+
+	s.backend.err = nil
+	s.register(c)
+	c.Assert(s.backend.records, jc.DeepEquals, map[string][]string{
+		s.hostname(c, "machine-0"): {"10.0.0.2"},
+	})
+	records, err = s.State.DNSRecords()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+}
+
+func (s *registrarSuite) TestOpenBackendError(c *gc.C) {
+	s.PatchValue(dnsregistrar.OpenBackend, func(*config.Config) (dnsregistrar.Backend, error) {
+		return nil, errors.NotSupportedf(`designate backend with "dummy" provider`)
+	})
+	err := dnsregistrar.RegisterMachines(s.State, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot open DNS backend: designate backend with "dummy" provider not supported`)
+}
+
+type fakeBackend struct {
+	records map[string][]string
+	calls   []string
+	err     error
+}
+
+func (b *fakeBackend) SetRecords(hostname string, addresses []network.Address) error {
+	b.calls = append(b.calls, "set "+hostname)
+	if b.err != nil {
+		return b.err
+	}
+	values := make([]string, len(addresses))
+	for i, addr := range addresses {
+		values[i] = addr.Value
+	}
+	b.records[hostname] = values
+	return nil
+}
+
+func (b *fakeBackend) RemoveRecords(hostname string) error {
+	b.calls = append(b.calls, "remove "+hostname)
+	if b.err != nil {
+		return b.err
+	}
+	delete(b.records, hostname)
+	return nil
+}
@@ -0,0 +1,17 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dnsregistrar
+
+import "github.com/juju/juju/environs/config"
+
+var (
+	OpenBackend      = &openBackend
+	RunNsupdate      = &runNsupdate
+	RegisterMachines = registerMachines
+)
+
+// NewNsupdateBackend returns the nsupdate backend configured by cfg.
+func NewNsupdateBackend(cfg *config.Config) Backend {
+	return newNsupdateBackend(cfg)
+}
@@ -0,0 +1,86 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dnsregistrar
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+)
+
+// runNsupdate runs nsupdate, feeding it the given commands.
+var runNsupdate = func(commands string) error {
+	cmd := exec.Command("nsupdate")
+	cmd.Stdin = strings.NewReader(commands)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Annotatef(err, "nsupdate failed: %s", bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// nsupdateBackend registers records by sending RFC 2136 dynamic
+// updates to a DNS server with nsupdate.
+type nsupdateBackend struct {
+	server  string
+	zone    string
+	tsigKey string
+	ttl     int
+}
+
+func newNsupdateBackend(cfg *config.Config) *nsupdateBackend {
+	return &nsupdateBackend{
+		server:  cfg.DNSServer(),
+		zone:    cfg.DNSZone(),
+		tsigKey: cfg.DNSTSIGKey(),
+		ttl:     cfg.DNSTTL(),
+	}
+}
+
+// SetRecords is part of the Backend interface.
+func (b *nsupdateBackend) SetRecords(hostname string, addresses []network.Address) error {
+	return runNsupdate(b.commands(hostname, addresses))
+}
+
+// RemoveRecords is part of the Backend interface.
+func (b *nsupdateBackend) RemoveRecords(hostname string) error {
+	return runNsupdate(b.commands(hostname, nil))
+}
+
+// commands returns the nsupdate commands which replace the address
+// records of hostname with ones for the given addresses. The TSIG key
+// is passed in the commands, rather than as an argument, so that it
+// is not visible to other processes.
+func (b *nsupdateBackend) commands(hostname string, addresses []network.Address) string {
+	var buf bytes.Buffer
+	if host, port, err := net.SplitHostPort(b.server); err == nil {
+		fmt.Fprintf(&buf, "server %s %s\n", host, port)
+	} else {
+		fmt.Fprintf(&buf, "server %s\n", b.server)
+	}
+	fmt.Fprintf(&buf, "zone %s\n", b.zone)
+	if b.tsigKey != "" {
+		// The key is [<algorithm>:]<name>:<secret>, and nsupdate
+		// expects [<algorithm>:]<name> <secret>.
+		i := strings.LastIndex(b.tsigKey, ":")
+		fmt.Fprintf(&buf, "key %s %s\n", b.tsigKey[:i], b.tsigKey[i+1:])
+	}
+	fmt.Fprintf(&buf, "update delete %s A\n", hostname)
+	fmt.Fprintf(&buf, "update delete %s AAAA\n", hostname)
+	for _, addr := range addresses {
+		rrType := "A"
+		if addr.Type == network.IPv6Address {
+			rrType = "AAAA"
+		}
+		fmt.Fprintf(&buf, "update add %s %d %s %s\n", hostname, b.ttl, rrType, addr.Value)
+	}
+	buf.WriteString("send\n")
+	return buf.String()
+}
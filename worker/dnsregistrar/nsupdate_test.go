@@ -0,0 +1,73 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package dnsregistrar_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/dnsregistrar"
+)
+
+type nsupdateSuite struct {
+	testing.BaseSuite
+	commands []string
+}
+
+var _ = gc.Suite(&nsupdateSuite{})
+
+func (s *nsupdateSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.commands = nil
+	s.PatchValue(dnsregistrar.RunNsupdate, func(commands string) error {
+		s.commands = append(s.commands, commands)
+		return nil
+	})
+}
+
+func (s *nsupdateSuite) backend(c *gc.C, attrs testing.Attrs) dnsregistrar.Backend {
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{
+		"dns-backend": "nsupdate",
+		"dns-zone":    "example.com",
+	}.Merge(attrs))
+	return dnsregistrar.NewNsupdateBackend(cfg)
+}
+
+func (s *nsupdateSuite) TestSetRecords(c *gc.C) {
+	backend := s.backend(c, testing.Attrs{
+		"dns-server":   "10.0.0.53:5353",
+		"dns-tsig-key": "hmac-sha256:juju:c2VjcmV0",
+		"dns-ttl":      60,
+	})
+	err := backend.SetRecords("machine-0.env.example.com.", []network.Address{
+		network.NewAddress("10.0.0.2", network.ScopeCloudLocal),
+		network.NewAddress("2001:db8::2", network.ScopePublic),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.commands, jc.DeepEquals, []string{`
+server 10.0.0.53 5353
+zone example.com
+key hmac-sha256:juju c2VjcmV0
+update delete machine-0.env.example.com. A
+update delete machine-0.env.example.com. AAAA
+update add machine-0.env.example.com. 60 A 10.0.0.2
+update add machine-0.env.example.com. 60 AAAA 2001:db8::2
+send
+`[1:]})
+}
+
+func (s *nsupdateSuite) TestRemoveRecords(c *gc.C) {
+	backend := s.backend(c, testing.Attrs{"dns-server": "ns1.example.com"})
+	err := backend.RemoveRecords("machine-0.env.example.com.")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.commands, jc.DeepEquals, []string{`
+server ns1.example.com
+zone example.com
+update delete machine-0.env.example.com. A
+update delete machine-0.env.example.com. AAAA
+send
+`[1:]})
+}
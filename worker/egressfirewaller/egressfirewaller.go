@@ -0,0 +1,230 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package egressfirewaller implements a worker that restricts the
+// destinations a machine hosting units may connect to, using the
+// host's own firewall, when the environment's egress-mode is
+// "restricted".
+package egressfirewaller
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.egressfirewaller")
+
+// chain is the iptables chain holding the rules managed by the worker.
+const chain = "juju-egress"
+
+// EnvironConfigWatcher is the interface of the Environment facade used
+// by the worker.
+type EnvironConfigWatcher interface {
+	EnvironConfig() (*config.Config, error)
+	WatchForEnvironConfigChanges() (watcher.NotifyWatcher, error)
+}
+
+// APIAddressesFunc returns the addresses, in host:port form, of the
+// API servers the machine agent connects to.
+type APIAddressesFunc func() ([]string, error)
+
+// runIptables runs iptables with the given arguments.
+var runIptables = func(args ...string) error {
+	output, err := exec.Command("iptables", args...).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("iptables %s: %v (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// lookupIP resolves API server host names to addresses.
+var lookupIP = net.LookupIP
+
+type egressFirewaller struct {
+	api          EnvironConfigWatcher
+	apiAddresses APIAddressesFunc
+	applied      []string
+	synced       bool
+}
+
+var _ worker.NotifyWatchHandler = (*egressFirewaller)(nil)
+
+// NewWorker returns a worker that keeps the host firewall in line with
+// the environment's egress settings. In restricted mode, outgoing
+// connections are rejected unless they are to the API servers or to a
+// destination allowed by egress-allowed; in open mode, the host's
+// existing rules are left to decide.
+func NewWorker(api EnvironConfigWatcher, apiAddresses APIAddressesFunc) worker.Worker {
+	return worker.NewNotifyWorker(&egressFirewaller{
+		api:          api,
+		apiAddresses: apiAddresses,
+	})
+}
+
+// SetUp is defined on the worker.NotifyWatchHandler interface.
+func (w *egressFirewaller) SetUp() (watcher.NotifyWatcher, error) {
+	// The NotifyWorker consumes the watcher's initial event, so
+	// update the firewall here first.
+	if err := w.update(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return w.api.WatchForEnvironConfigChanges()
+}
+
+// Handle is defined on the worker.NotifyWatchHandler interface.
+func (w *egressFirewaller) Handle() error {
+	return w.update()
+}
+
+// TearDown is defined on the worker.NotifyWatchHandler interface.
+func (w *egressFirewaller) TearDown() error {
+	// Nothing to cleanup, only state is the watcher.
+	return nil
+}
+
+func (w *egressFirewaller) update() error {
+	cfg, err := w.api.EnvironConfig()
+	if err != nil {
+		return errors.Annotate(err, "cannot get environment config")
+	}
+	var commands [][]string
+	if cfg.EgressMode() == config.EgressRestricted {
+		rules, err := cfg.EgressRules()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		apiRules, err := w.apiServerRules()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		commands = chainCommands(append(apiRules, rules...))
+	}
+	keys := make([]string, len(commands))
+	for i, args := range commands {
+		keys[i] = strings.Join(args, " ")
+	}
+	if w.synced && stringsEqual(keys, w.applied) {
+		return nil
+	}
+	if len(commands) == 0 {
+		// Egress is open: empty the chain, if it was ever created.
+		if err := runIptables("-n", "-L", chain); err == nil {
+			if err := runIptables("-F", chain); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		logger.Infof("egress is open")
+	} else {
+		if err := ensureChain(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := runIptables("-F", chain); err != nil {
+			return errors.Trace(err)
+		}
+		for _, args := range commands {
+			if err := runIptables(args...); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		logger.Infof("egress restricted to %v", keys)
+	}
+	w.applied, w.synced = keys, true
+	return nil
+}
+
+// apiServerRules returns egress rules that allow the machine agent to
+// keep talking to the API servers.
+func (w *egressFirewaller) apiServerRules() ([]network.EgressRule, error) {
+	addrs, err := w.apiAddresses()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot get API server addresses")
+	}
+	var rules []network.EgressRule
+	for _, addr := range addrs {
+		host, portString, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		port, err := strconv.Atoi(portString)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid API server address %q", addr)
+		}
+		ips := []net.IP{net.ParseIP(host)}
+		if ips[0] == nil {
+			if ips, err = lookupIP(host); err != nil {
+				return nil, errors.Annotatef(err, "cannot resolve API server address %q", addr)
+			}
+		}
+		for _, ip := range ips {
+			if ip.To4() == nil {
+				continue
+			}
+			rules = append(rules, network.EgressRule{
+				DestinationCIDR: ip.String() + "/32",
+				PortRange:       &network.PortRange{FromPort: port, ToPort: port, Protocol: "tcp"},
+			})
+		}
+	}
+	return rules, nil
+}
+
+// ensureChain creates the chain, and makes outgoing traffic pass
+// through it, if it is not already in place.
+func ensureChain() error {
+	if err := runIptables("-n", "-L", chain); err != nil {
+		if err := runIptables("-N", chain); err != nil {
+			return errors.Annotatef(err, "cannot create chain %q", chain)
+		}
+	}
+	if err := runIptables("-C", "OUTPUT", "-j", chain); err != nil {
+		if err := runIptables("-I", "OUTPUT", "-j", chain); err != nil {
+			return errors.Annotatef(err, "cannot add chain %q to OUTPUT", chain)
+		}
+	}
+	return nil
+}
+
+// chainCommands returns the arguments of the iptables commands that
+// append to the chain the rules allowing only the given destinations.
+func chainCommands(rules []network.EgressRule) [][]string {
+	commands := [][]string{
+		{"-A", chain, "-o", "lo", "-j", "RETURN"},
+		{"-A", chain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "RETURN"},
+	}
+	for _, rule := range rules {
+		args := []string{"-A", chain, "-d", rule.DestinationCIDR}
+		if rule.PortRange != nil {
+			args = append(args,
+				"-p", strings.ToLower(rule.PortRange.Protocol),
+				"--dport", fmt.Sprintf("%d:%d", rule.PortRange.FromPort, rule.PortRange.ToPort),
+			)
+		}
+		commands = append(commands, append(args, "-j", "RETURN"))
+	}
+	return append(commands, []string{"-A", chain, "-j", "REJECT"})
+}
+
+// stringsEqual reports whether a and b hold the same strings in the
+// same order.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package egressfirewaller_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/config"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/egressfirewaller"
+)
+
+type egressFirewallerSuite struct {
+	coretesting.BaseSuite
+
+	mu       sync.Mutex
+	cfg      *config.Config
+	changes  chan struct{}
+	commands []string
+	missing  map[string]bool
+}
+
+var _ = gc.Suite(&egressFirewallerSuite{})
+
+func (s *egressFirewallerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.cfg = coretesting.EnvironConfig(c)
+	s.changes = make(chan struct{})
+	s.commands = nil
+	s.missing = map[string]bool{
+		"-n -L juju-egress":        true,
+		"-C OUTPUT -j juju-egress": true,
+	}
+	s.PatchValue(egressfirewaller.RunIptables, func(args ...string) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		command := strings.Join(args, " ")
+		s.commands = append(s.commands, command)
+		if s.missing[command] {
+			return errors.New("no such chain")
+		}
+		return nil
+	})
+	s.PatchValue(egressfirewaller.LookupIP, func(host string) ([]net.IP, error) {
+		c.Check(host, gc.Equals, "api.example.com")
+		return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("10.0.0.2")}, nil
+	})
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
+
+func (s *egressFirewallerSuite) EnvironConfig() (*config.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg, nil
+}
+
+func (s *egressFirewallerSuite) WatchForEnvironConfigChanges() (watcher.NotifyWatcher, error) {
+	return &mockNotifyWatcher{s.changes}, nil
+}
+
+func (s *egressFirewallerSuite) apiAddresses() ([]string, error) {
+	return []string{"10.0.0.1:17070", "api.example.com:17070"}, nil
+}
+
+func (s *egressFirewallerSuite) setConfig(c *gc.C, attrs coretesting.Attrs) {
+	s.mu.Lock()
+	cfg, err := s.cfg.Apply(attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	s.cfg = cfg
+	s.mu.Unlock()
+	select {
+	case s.changes <- struct{}{}:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("worker did not consume config change")
+	}
+}
+
+func (s *egressFirewallerSuite) waitForCommands(c *gc.C, expected []string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.mu.Lock()
+		commands := s.commands
+		s.mu.Unlock()
+		if len(commands) >= len(expected) {
+			c.Assert(commands, jc.DeepEquals, expected)
+			return
+		}
+	}
+	c.Fatalf("iptables never ran %v", expected)
+}
+
+func (s *egressFirewallerSuite) TestOpen(c *gc.C) {
+	w := egressfirewaller.NewWorker(s, s.apiAddresses)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	// The chain was never created, so there is nothing to flush.
+	s.waitForCommands(c, []string{
+		"-n -L juju-egress",
+	})
+}
+
+func (s *egressFirewallerSuite) TestRestricted(c *gc.C) {
+	var err error
+	s.cfg, err = s.cfg.Apply(map[string]interface{}{
+		"egress-mode":    "restricted",
+		"egress-allowed": "192.168.0.0/16,0.0.0.0/0:53/udp",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	w := egressfirewaller.NewWorker(s, s.apiAddresses)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.waitForCommands(c, []string{
+		"-n -L juju-egress",
+		"-N juju-egress",
+		"-C OUTPUT -j juju-egress",
+		"-I OUTPUT -j juju-egress",
+		"-F juju-egress",
+		"-A juju-egress -o lo -j RETURN",
+		"-A juju-egress -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-A juju-egress -d 10.0.0.1/32 -p tcp --dport 17070:17070 -j RETURN",
+		"-A juju-egress -d 10.0.0.2/32 -p tcp --dport 17070:17070 -j RETURN",
+		"-A juju-egress -d 192.168.0.0/16 -j RETURN",
+		"-A juju-egress -d 0.0.0.0/0 -p udp --dport 53:53 -j RETURN",
+		"-A juju-egress -j REJECT",
+	})
+}
+
+func (s *egressFirewallerSuite) TestUpdatesOnlyOnChange(c *gc.C) {
+	s.missing = nil
+	w := egressfirewaller.NewWorker(s, s.apiAddresses)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	opened := []string{
+		"-n -L juju-egress",
+		"-F juju-egress",
+	}
+	s.waitForCommands(c, opened)
+
+	// A change to unrelated settings leaves the firewall alone.
+	s.setConfig(c, coretesting.Attrs{"logging-config": "<root>=DEBUG"})
+	s.setConfig(c, coretesting.Attrs{
+		"egress-mode":    "restricted",
+		"egress-allowed": "192.168.0.0/16",
+	})
+	restricted := append(opened,
+		"-n -L juju-egress",
+		"-C OUTPUT -j juju-egress",
+		"-F juju-egress",
+		"-A juju-egress -o lo -j RETURN",
+		"-A juju-egress -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-A juju-egress -d 10.0.0.1/32 -p tcp --dport 17070:17070 -j RETURN",
+		"-A juju-egress -d 10.0.0.2/32 -p tcp --dport 17070:17070 -j RETURN",
+		"-A juju-egress -d 192.168.0.0/16 -j RETURN",
+		"-A juju-egress -j REJECT",
+	)
+	s.waitForCommands(c, restricted)
+
+	// Opening egress again empties the chain.
+	s.setConfig(c, coretesting.Attrs{"egress-mode": "open"})
+	s.waitForCommands(c, append(restricted,
+		"-n -L juju-egress",
+		"-F juju-egress",
+	))
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package evacuator implements a worker that moves the units off
+// machines being evacuated, and destroys those machines once the units
+// replacing them are running.
+package evacuator
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.evacuator")
+
+// period is the interval at which evacuations are progressed.
+var period = 10 * time.Second
+
+// Evacuator progresses the evacuation of machines.
+type Evacuator struct {
+	st *state.State
+}
+
+// NewEvacuator returns a worker that progresses each evacuation in
+// turn: it adds a replacement for each principal unit on the machine,
+// assigned as a newly deployed unit would be; waits for the
+// replacements to be running; destroys the evacuated units; and
+// finally destroys the machine, releasing its instance.
+func NewEvacuator(st *state.State) worker.Worker {
+	e := &Evacuator{st: st}
+	return worker.NewPeriodicWorker(e.evacuateAll, period)
+}
+
+func (e *Evacuator) evacuateAll(stop <-chan struct{}) error {
+	evacuations, err := e.st.Evacuations()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, evacuation := range evacuations {
+		select {
+		case <-stop:
+			return worker.ErrKilled
+		default:
+		}
+		if err := e.evacuate(evacuation); err != nil {
+			// One machine failing to evacuate should not hold
+			// up the others; try again next time around.
+			logger.Errorf("cannot evacuate machine %s: %v", evacuation.MachineId(), err)
+		}
+	}
+	return nil
+}
+
+func (e *Evacuator) evacuate(evacuation *state.Evacuation) error {
+	machine, err := e.st.Machine(evacuation.MachineId())
+	if errors.IsNotFound(err) {
+		return evacuation.Remove()
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if machine.Life() == state.Dead {
+		return evacuation.Remove()
+	}
+	units, err := machine.Units()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	replacements := evacuation.Replacements()
+	ready := true
+	var principals []*state.Unit
+	for _, unit := range units {
+		if !unit.IsPrincipal() || unit.Life() != state.Alive {
+			continue
+		}
+		principals = append(principals, unit)
+		replacement, err := e.ensureReplacement(evacuation, unit, replacements[unit.Name()])
+		if err != nil {
+			return errors.Annotatef(err, "cannot replace unit %q", unit.Name())
+		}
+		status, _, _, err := replacement.Status()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if status != state.StatusRunning {
+			logger.Debugf("waiting for unit %q to replace %q", replacement.Name(), unit.Name())
+			ready = false
+		}
+	}
+	if !ready {
+		return nil
+	}
+	for _, unit := range principals {
+		logger.Infof("destroying unit %q evacuated from machine %s", unit.Name(), machine.Id())
+		if err := unit.Destroy(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	err = machine.Destroy()
+	if state.IsHasAssignedUnitsError(err) {
+		// The evacuated units are still shutting down.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("machine %s evacuated", machine.Id())
+	return nil
+}
+
+// ensureReplacement returns the unit replacing the given unit, adding
+// it if it does not yet exist and assigning it if it is not yet
+// assigned.
+func (e *Evacuator) ensureReplacement(evacuation *state.Evacuation, unit *state.Unit, replacementName string) (*state.Unit, error) {
+	var replacement *state.Unit
+	if replacementName != "" {
+		var err error
+		replacement, err = e.st.Unit(replacementName)
+		if errors.IsNotFound(err) || err == nil && replacement.Life() != state.Alive {
+			logger.Warningf("unit %q replacing %q has gone away; replacing again", replacementName, unit.Name())
+			replacement = nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if replacement == nil {
+		service, err := unit.Service()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		replacement, err = service.AddUnit()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// Record the replacement before assigning it, so that a
+		// failure to assign is retried rather than leaving an
+		// unknown unit behind.
+		if err := evacuation.SetReplacement(unit.Name(), replacement.Name()); err != nil {
+			return nil, errors.Trace(err)
+		}
+		logger.Infof("added unit %q to replace %q", replacement.Name(), unit.Name())
+	}
+	if _, err := replacement.AssignedMachineId(); errors.IsNotAssigned(err) {
+		if err := e.st.AssignUnit(replacement, state.AssignCleanEmpty); err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return replacement, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package evacuator_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/evacuator"
+)
+
+type evacuatorSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = gc.Suite(&evacuatorSuite{})
+
+func (s *evacuatorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(evacuator.Period, 10*time.Millisecond)
+}
+
+func (s *evacuatorSuite) waitForReplacement(c *gc.C, machineId, unitName string) *state.Unit {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		evacuation, err := s.State.Evacuation(machineId)
+		c.Assert(err, jc.ErrorIsNil)
+		if name, ok := evacuation.Replacements()[unitName]; ok {
+			unit, err := s.State.Unit(name)
+			c.Assert(err, jc.ErrorIsNil)
+			if _, err := unit.AssignedMachineId(); err == nil {
+				return unit
+			}
+		}
+	}
+	c.Fatalf("unit %q not replaced", unitName)
+	return nil
+}
+
+func (s *evacuatorSuite) waitForLife(c *gc.C, entity state.Living, life state.Life) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := entity.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		if entity.Life() == life {
+			return
+		}
+	}
+	c.Fatalf("%v never became %v", entity, life)
+}
+
+func (s *evacuatorSuite) TestEvacuate(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	unit, err := wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.AssignToMachine(machine)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := evacuator.NewEvacuator(s.State)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	replacement := s.waitForReplacement(c, machine.Id(), unit.Name())
+	machineId, err := replacement.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machineId, gc.Not(gc.Equals), machine.Id())
+
+	// Nothing is destroyed until the replacement is running.
+	err = unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(unit.Life(), gc.Equals, state.Alive)
+
+	err = replacement.SetStatus(state.StatusRunning, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForLife(c, unit, state.Dying)
+
+	// Once the evacuated unit has gone, the machine is destroyed.
+	err = unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForLife(c, machine, state.Dying)
+}
+
+func (s *evacuatorSuite) TestEvacuateRemovedMachine(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.Evacuate()
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := evacuator.NewEvacuator(s.State)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		_, err := s.State.Evacuation(machine.Id())
+		if errors.IsNotFound(err) {
+			return
+		}
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Fatalf("evacuation of dead machine not removed")
+}
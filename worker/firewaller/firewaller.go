@@ -21,10 +21,10 @@ import (
 	"github.com/juju/juju/worker"
 )
 
-type machineRanges map[network.PortRange]bool
-
 // Firewaller watches the state for port ranges opened or closed on
 // machines and reflects those changes onto the backing environment.
+// Where the environment supports it, access to the ports of services
+// exposed only to particular address ranges is restricted accordingly.
 // Uses Firewaller API V1.
 type Firewaller struct {
 	tomb            tomb.Tomb
@@ -39,8 +39,11 @@ type Firewaller struct {
 	serviceds       map[names.ServiceTag]*serviceData
 	exposedChange   chan *exposedChange
 	globalMode      bool
-	globalPortRef   map[network.PortRange]int
-	machinePorts    map[names.MachineTag]machineRanges
+	globalRuleRef   map[string]int
+
+	// ingressCIDRs holds the address ranges from which services
+	// exposed without address ranges of their own may be reached.
+	ingressCIDRs []string
 }
 
 // NewFirewaller returns a new Firewaller or a new FirewallerV0,
@@ -53,7 +56,6 @@ func NewFirewaller(st *apifirewaller.State) (_ worker.Worker, err error) {
 		unitds:        make(map[names.UnitTag]*unitData),
 		serviceds:     make(map[names.ServiceTag]*serviceData),
 		exposedChange: make(chan *exposedChange),
-		machinePorts:  make(map[names.MachineTag]machineRanges),
 	}
 	defer func() {
 		if err != nil {
@@ -85,10 +87,15 @@ func NewFirewaller(st *apifirewaller.State) (_ worker.Worker, err error) {
 		return nil, err
 	}
 
+	fw.ingressCIDRs, err = fw.environ.Config().IngressCIDRs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	switch fw.environ.Config().FirewallMode() {
 	case config.FwGlobal:
 		fw.globalMode = true
-		fw.globalPortRef = make(map[network.PortRange]int)
+		fw.globalRuleRef = make(map[string]int)
 	case config.FwNone:
 		logger.Warningf("stopping firewaller - firewall-mode is %q", config.FwNone)
 		return nil, errors.Errorf("firewaller is disabled when firewall-mode is %q", config.FwNone)
@@ -125,6 +132,9 @@ func (fw *Firewaller) loop() error {
 			if err := fw.environ.SetConfig(config); err != nil {
 				logger.Errorf("loaded invalid environment configuration: %v", err)
 			}
+			if err := fw.ingressCIDRsChanged(config); err != nil {
+				return errors.Annotate(err, "cannot change firewall ports")
+			}
 		case change, ok := <-fw.machinesWatcher.Changes():
 			if !ok {
 				return watcher.EnsureErr(fw.machinesWatcher)
@@ -163,6 +173,7 @@ func (fw *Firewaller) loop() error {
 			}
 		case change := <-fw.exposedChange:
 			change.serviced.exposed = change.exposed
+			change.serviced.cidrs = change.cidrs
 			unitds := []*unitData{}
 			for _, unitd := range change.serviced.unitds {
 				unitds = append(unitds, unitd)
@@ -174,6 +185,31 @@ func (fw *Firewaller) loop() error {
 	}
 }
 
+// ingressCIDRsChanged updates the firewall of the units of services
+// exposed without address ranges of their own, if the environment's
+// default address ranges have changed.
+func (fw *Firewaller) ingressCIDRsChanged(cfg *config.Config) error {
+	cidrs, err := cfg.IngressCIDRs()
+	if err != nil {
+		logger.Errorf("invalid ingress-allowed in environment configuration: %v", err)
+		return nil
+	}
+	if stringsEqual(cidrs, fw.ingressCIDRs) {
+		return nil
+	}
+	fw.ingressCIDRs = cidrs
+	unitds := []*unitData{}
+	for _, serviced := range fw.serviceds {
+		if len(serviced.cidrs) > 0 {
+			continue
+		}
+		for _, unitd := range serviced.unitds {
+			unitds = append(unitds, unitd)
+		}
+	}
+	return fw.flushUnits(unitds)
+}
+
 // startMachine creates a new data value for tracking details of the
 // machine and starts watching the machine for units added or removed.
 func (fw *Firewaller) startMachine(tag names.MachineTag) error {
@@ -181,7 +217,7 @@ func (fw *Firewaller) startMachine(tag names.MachineTag) error {
 		fw:           fw,
 		tag:          tag,
 		unitds:       make(map[names.UnitTag]*unitData),
-		openedPorts:  make([]network.PortRange, 0),
+		openedRules:  make([]network.IngressRule, 0),
 		definedPorts: make(map[network.PortRange]names.UnitTag),
 	}
 	m, err := machined.machine()
@@ -272,14 +308,19 @@ func (fw *Firewaller) startService(service *apifirewaller.Service) error {
 	if err != nil {
 		return err
 	}
+	cidrs, err := service.ExposedCIDRs()
+	if err != nil {
+		return err
+	}
 	serviced := &serviceData{
 		fw:      fw,
 		service: service,
 		exposed: exposed,
+		cidrs:   cidrs,
 		unitds:  make(map[names.UnitTag]*unitData),
 	}
 	fw.serviceds[service.Tag()] = serviced
-	go serviced.watchLoop(serviced.exposed)
+	go serviced.watchLoop(serviced.exposed, serviced.cidrs)
 	return nil
 }
 
@@ -287,43 +328,36 @@ func (fw *Firewaller) startService(service *apifirewaller.Service) error {
 // units and services with the opened and closed ports globally and
 // opens and closes the appropriate ports for the whole environment.
 func (fw *Firewaller) reconcileGlobal() error {
-	initialPortRanges, err := fw.environ.Ports()
+	initialRules, err := fw.environRules()
 	if err != nil {
 		return err
 	}
-	collector := make(map[network.PortRange]bool)
+	collector := make(map[string]network.IngressRule)
 	for _, machined := range fw.machineds {
-		for portRange, unitTag := range machined.definedPorts {
-			unitd, known := machined.unitds[unitTag]
-			if !known {
-				delete(machined.unitds, unitTag)
-				continue
-			}
-			if unitd.serviced.exposed {
-				collector[portRange] = true
-			}
+		for _, rule := range machined.wantedRules() {
+			collector[rule.String()] = rule
 		}
 	}
-	wantedPorts := []network.PortRange{}
-	for port := range collector {
-		wantedPorts = append(wantedPorts, port)
+	wantedRules := []network.IngressRule{}
+	for _, rule := range collector {
+		wantedRules = append(wantedRules, rule)
 	}
-	// Check which ports to open or to close.
-	toOpen := diffRanges(wantedPorts, initialPortRanges)
-	toClose := diffRanges(initialPortRanges, wantedPorts)
+	// Check which rules to open or to close.
+	toOpen := network.DiffIngressRules(wantedRules, initialRules)
+	toClose := network.DiffIngressRules(initialRules, wantedRules)
 	if len(toOpen) > 0 {
+		network.SortIngressRules(toOpen)
 		logger.Infof("opening global ports %v", toOpen)
-		if err := fw.environ.OpenPorts(toOpen); err != nil {
+		if err := fw.openEnvironRules(toOpen); err != nil {
 			return err
 		}
-		network.SortPortRanges(toOpen)
 	}
 	if len(toClose) > 0 {
+		network.SortIngressRules(toClose)
 		logger.Infof("closing global ports %v", toClose)
-		if err := fw.environ.ClosePorts(toClose); err != nil {
+		if err := fw.closeEnvironRules(toClose); err != nil {
 			return err
 		}
-		network.SortPortRanges(toClose)
 	}
 	return nil
 }
@@ -353,31 +387,31 @@ func (fw *Firewaller) reconcileInstances() error {
 			return err
 		}
 		machineId := machined.tag.Id()
-		initialPortRanges, err := instances[0].Ports(machineId)
+		initialRules, err := instanceRules(instances[0], machineId)
 		if err != nil {
 			return err
 		}
 
-		// Check which ports to open or to close.
-		toOpen := diffRanges(machined.openedPorts, initialPortRanges)
-		toClose := diffRanges(initialPortRanges, machined.openedPorts)
+		// Check which rules to open or to close.
+		toOpen := network.DiffIngressRules(machined.openedRules, initialRules)
+		toClose := network.DiffIngressRules(initialRules, machined.openedRules)
 		if len(toOpen) > 0 {
+			network.SortIngressRules(toOpen)
 			logger.Infof("opening instance port ranges %v for %q",
 				toOpen, machined.tag)
-			if err := instances[0].OpenPorts(machineId, toOpen); err != nil {
+			if err := openInstanceRules(instances[0], machineId, toOpen); err != nil {
 				// TODO(mue) Add local retry logic.
 				return err
 			}
-			network.SortPortRanges(toOpen)
 		}
 		if len(toClose) > 0 {
+			network.SortIngressRules(toClose)
 			logger.Infof("closing instance port ranges %v for %q",
 				toClose, machined.tag)
-			if err := instances[0].ClosePorts(machineId, toClose); err != nil {
+			if err := closeInstanceRules(instances[0], machineId, toClose); err != nil {
 				// TODO(mue) Add local retry logic.
 				return err
 			}
-			network.SortPortRanges(toClose)
 		}
 	}
 	return nil
@@ -498,68 +532,60 @@ func (fw *Firewaller) flushUnits(unitds []*unitData) error {
 
 // flushMachine opens and closes ports for the passed machine.
 func (fw *Firewaller) flushMachine(machined *machineData) error {
-	// Gather ports to open and close.
-	want := []network.PortRange{}
-	for portRange, unitTag := range machined.definedPorts {
-		unitd, known := machined.unitds[unitTag]
-		if !known {
-			delete(machined.unitds, unitTag)
-			continue
-		}
-		if unitd.serviced.exposed {
-			want = append(want, portRange)
-		}
-	}
-	toOpen := diffRanges(want, machined.openedPorts)
-	toClose := diffRanges(machined.openedPorts, want)
-	machined.openedPorts = want
+	// Gather rules to open and close.
+	want := machined.wantedRules()
+	toOpen := network.DiffIngressRules(want, machined.openedRules)
+	toClose := network.DiffIngressRules(machined.openedRules, want)
+	machined.openedRules = want
 	if fw.globalMode {
-		return fw.flushGlobalPorts(toOpen, toClose)
+		return fw.flushGlobalRules(toOpen, toClose)
 	}
-	return fw.flushInstancePorts(machined, toOpen, toClose)
+	return fw.flushInstanceRules(machined, toOpen, toClose)
 }
 
-// flushGlobalPorts opens and closes global ports in the environment.
-// It keeps a reference count for ports so that only 0-to-1 and 1-to-0 events
-// modify the environment.
-func (fw *Firewaller) flushGlobalPorts(rawOpen, rawClose []network.PortRange) error {
-	// Filter which ports are really to open or close.
-	var toOpen, toClose []network.PortRange
-	for _, portRange := range rawOpen {
-		if fw.globalPortRef[portRange] == 0 {
-			toOpen = append(toOpen, portRange)
+// flushGlobalRules opens and closes global ports in the environment.
+// It keeps a reference count for rules so that only 0-to-1 and 1-to-0
+// events modify the environment.
+func (fw *Firewaller) flushGlobalRules(rawOpen, rawClose []network.IngressRule) error {
+	// Filter which rules are really to open or close.
+	var toOpen, toClose []network.IngressRule
+	for _, rule := range rawOpen {
+		key := rule.String()
+		if fw.globalRuleRef[key] == 0 {
+			toOpen = append(toOpen, rule)
 		}
-		fw.globalPortRef[portRange]++
+		fw.globalRuleRef[key]++
 	}
-	for _, portRange := range rawClose {
-		fw.globalPortRef[portRange]--
-		if fw.globalPortRef[portRange] == 0 {
-			toClose = append(toClose, portRange)
-			delete(fw.globalPortRef, portRange)
+	for _, rule := range rawClose {
+		key := rule.String()
+		fw.globalRuleRef[key]--
+		if fw.globalRuleRef[key] == 0 {
+			toClose = append(toClose, rule)
+			delete(fw.globalRuleRef, key)
 		}
 	}
-	// Open and close the ports.
+	// Open and close the rules.
 	if len(toOpen) > 0 {
-		if err := fw.environ.OpenPorts(toOpen); err != nil {
+		if err := fw.openEnvironRules(toOpen); err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPortRanges(toOpen)
+		network.SortIngressRules(toOpen)
 		logger.Infof("opened port ranges %v in environment", toOpen)
 	}
 	if len(toClose) > 0 {
-		if err := fw.environ.ClosePorts(toClose); err != nil {
+		if err := fw.closeEnvironRules(toClose); err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPortRanges(toClose)
+		network.SortIngressRules(toClose)
 		logger.Infof("closed port ranges %v in environment", toClose)
 	}
 	return nil
 }
 
-// flushInstancePorts opens and closes ports global on the machine.
-func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose []network.PortRange) error {
+// flushInstanceRules opens and closes ports global on the machine.
+func (fw *Firewaller) flushInstanceRules(machined *machineData, toOpen, toClose []network.IngressRule) error {
 	// If there's nothing to do, do nothing.
 	// This is important because when a machine is first created,
 	// it will have no instance id but also no open ports -
@@ -583,21 +609,21 @@ func (fw *Firewaller) flushInstancePorts(machined *machineData, toOpen, toClose
 	if err != nil {
 		return err
 	}
-	// Open and close the ports.
+	// Open and close the rules.
 	if len(toOpen) > 0 {
-		if err := instances[0].OpenPorts(machineId, toOpen); err != nil {
+		if err := openInstanceRules(instances[0], machineId, toOpen); err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPortRanges(toOpen)
+		network.SortIngressRules(toOpen)
 		logger.Infof("opened port ranges %v on %q", toOpen, machined.tag)
 	}
 	if len(toClose) > 0 {
-		if err := instances[0].ClosePorts(machineId, toClose); err != nil {
+		if err := closeInstanceRules(instances[0], machineId, toClose); err != nil {
 			// TODO(mue) Add local retry logic.
 			return err
 		}
-		network.SortPortRanges(toClose)
+		network.SortIngressRules(toClose)
 		logger.Infof("closed port ranges %v on %q", toClose, machined.tag)
 	}
 	return nil
@@ -712,7 +738,7 @@ type machineData struct {
 	fw          *Firewaller
 	tag         names.MachineTag
 	unitds      map[names.UnitTag]*unitData
-	openedPorts []network.PortRange
+	openedRules []network.IngressRule
 	// ports defined by units on this machine
 	definedPorts map[network.PortRange]names.UnitTag
 }
@@ -721,6 +747,35 @@ func (md *machineData) machine() (*apifirewaller.Machine, error) {
 	return md.fw.st.Machine(md.tag)
 }
 
+// wantedRules returns the rules which should be open on the machine:
+// those for the ports defined by units of exposed services, restricted
+// to the address ranges the services are exposed to, or else to the
+// environment's default address ranges.
+func (md *machineData) wantedRules() []network.IngressRule {
+	want := []network.IngressRule{}
+	for portRange, unitTag := range md.definedPorts {
+		unitd, known := md.unitds[unitTag]
+		if !known {
+			delete(md.unitds, unitTag)
+			continue
+		}
+		if !unitd.serviced.exposed {
+			continue
+		}
+		cidrs := unitd.serviced.cidrs
+		if len(cidrs) == 0 {
+			cidrs = md.fw.ingressCIDRs
+		}
+		rule, err := network.NewIngressRule(portRange, cidrs...)
+		if err != nil {
+			logger.Errorf("cannot open port range %v for %q: %v", portRange, unitTag, err)
+			continue
+		}
+		want = append(want, rule)
+	}
+	return want
+}
+
 // watchLoop watches the machine for units added or removed.
 func (md *machineData) watchLoop(unitw apiwatcher.StringsWatcher) {
 	defer md.tomb.Done()
@@ -762,10 +817,12 @@ type unitData struct {
 	machined *machineData
 }
 
-// exposedChange contains the changed exposed flag for one specific service.
+// exposedChange contains the changed exposed flag and address ranges
+// for one specific service.
 type exposedChange struct {
 	serviced *serviceData
 	exposed  bool
+	cidrs    []string
 }
 
 // serviceData holds service details and watches exposure changes.
@@ -774,11 +831,13 @@ type serviceData struct {
 	fw      *Firewaller
 	service *apifirewaller.Service
 	exposed bool
+	cidrs   []string
 	unitds  map[names.UnitTag]*unitData
 }
 
-// watchLoop watches the service's exposed flag for changes.
-func (sd *serviceData) watchLoop(exposed bool) {
+// watchLoop watches the service's exposed flag and address ranges for
+// changes.
+func (sd *serviceData) watchLoop(exposed bool, cidrs []string) {
 	defer sd.tomb.Done()
 	w, err := sd.service.Watch()
 	if err != nil {
@@ -806,12 +865,17 @@ func (sd *serviceData) watchLoop(exposed bool) {
 				sd.fw.tomb.Kill(err)
 				return
 			}
-			if change == exposed {
+			changeCIDRs, err := sd.service.ExposedCIDRs()
+			if err != nil {
+				sd.fw.tomb.Kill(err)
+				return
+			}
+			if change == exposed && stringsEqual(changeCIDRs, cidrs) {
 				continue
 			}
-			exposed = change
+			exposed, cidrs = change, changeCIDRs
 			select {
-			case sd.fw.exposedChange <- &exposedChange{sd, change}:
+			case sd.fw.exposedChange <- &exposedChange{sd, change, changeCIDRs}:
 			case <-sd.tomb.Dying():
 				return
 			}
@@ -825,18 +889,18 @@ func (sd *serviceData) Stop() error {
 	return sd.tomb.Wait()
 }
 
-// diffRanges returns all the port rangess that exist in A but not B.
-func diffRanges(A, B []network.PortRange) (missing []network.PortRange) {
-next:
-	for _, a := range A {
-		for _, b := range B {
-			if a == b {
-				continue next
-			}
+// stringsEqual reports whether a and b hold the same strings in the
+// same order.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
-		missing = append(missing, a)
 	}
-	return
+	return true
 }
 
 // parsePortsKey parses a ports document global key coming from the
@@ -0,0 +1,103 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package firewaller
+
+import (
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+// environRules returns the ingress rules open for the whole
+// environment.
+func (fw *Firewaller) environRules() ([]network.IngressRule, error) {
+	if env, ok := fw.environ.(environs.EnvironIngressRules); ok {
+		return env.IngressRules()
+	}
+	ports, err := fw.environ.Ports()
+	if err != nil {
+		return nil, err
+	}
+	return network.IngressRulesFromPortRanges(ports), nil
+}
+
+// openEnvironRules opens the given ingress rules for the whole
+// environment.
+func (fw *Firewaller) openEnvironRules(rules []network.IngressRule) error {
+	if env, ok := fw.environ.(environs.EnvironIngressRules); ok {
+		return env.OpenIngressRules(rules)
+	}
+	ports := unrestrictedPorts(rules)
+	if len(ports) == 0 {
+		return nil
+	}
+	return fw.environ.OpenPorts(ports)
+}
+
+// closeEnvironRules closes the given ingress rules for the whole
+// environment.
+func (fw *Firewaller) closeEnvironRules(rules []network.IngressRule) error {
+	if env, ok := fw.environ.(environs.EnvironIngressRules); ok {
+		return env.CloseIngressRules(rules)
+	}
+	ports := unrestrictedPorts(rules)
+	if len(ports) == 0 {
+		return nil
+	}
+	return fw.environ.ClosePorts(ports)
+}
+
+// instanceRules returns the ingress rules open on the given instance.
+func instanceRules(inst instance.Instance, machineId string) ([]network.IngressRule, error) {
+	if inst, ok := inst.(environs.InstanceIngressRules); ok {
+		return inst.IngressRules(machineId)
+	}
+	ports, err := inst.Ports(machineId)
+	if err != nil {
+		return nil, err
+	}
+	return network.IngressRulesFromPortRanges(ports), nil
+}
+
+// openInstanceRules opens the given ingress rules on the instance.
+func openInstanceRules(inst instance.Instance, machineId string, rules []network.IngressRule) error {
+	if inst, ok := inst.(environs.InstanceIngressRules); ok {
+		return inst.OpenIngressRules(machineId, rules)
+	}
+	ports := unrestrictedPorts(rules)
+	if len(ports) == 0 {
+		return nil
+	}
+	return inst.OpenPorts(machineId, ports)
+}
+
+// closeInstanceRules closes the given ingress rules on the instance.
+func closeInstanceRules(inst instance.Instance, machineId string, rules []network.IngressRule) error {
+	if inst, ok := inst.(environs.InstanceIngressRules); ok {
+		return inst.CloseIngressRules(machineId, rules)
+	}
+	ports := unrestrictedPorts(rules)
+	if len(ports) == 0 {
+		return nil
+	}
+	return inst.ClosePorts(machineId, ports)
+}
+
+// unrestrictedPorts returns the port ranges of the given rules, for
+// providers which can only open ports to any address. Rules that
+// restrict access to particular addresses cannot be enforced by such
+// providers, so their ports are left closed rather than being opened
+// to everyone; machines that run a host firewall enforce them there.
+func unrestrictedPorts(rules []network.IngressRule) []network.PortRange {
+	var ports []network.PortRange
+	for _, rule := range rules {
+		if !rule.IsUnrestricted() {
+			logger.Warningf("provider cannot restrict access to port range %v; leaving it closed", rule)
+			continue
+		}
+		ports = append(ports, rule.PortRange)
+	}
+	network.SortPortRanges(ports)
+	return ports
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package hahealer provides a worker which keeps a highly available
+// environment healthy. It watches the state server machines, and when
+// a voting state server has been unavailable for long enough it
+// restores the requested number of voters: the dead voter is demoted,
+// so that the peergrouper removes it from the mongo replica set, and
+// an available standby is promoted or a replacement machine requested
+// in its place.
+package hahealer
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.hahealer")
+
+var (
+	// checkInterval is how often the health of the state servers
+	// is checked.
+	checkInterval = time.Minute
+
+	// gracePeriod is how long a voting state server must have been
+	// unavailable before it is replaced, so that restarts and
+	// upgrades do not cause needless churn.
+	gracePeriod = 5 * time.Minute
+)
+
+// State provides the parts of state used by the worker.
+type State interface {
+	StateServersHealth() ([]state.StateServerHealth, error)
+	EnsureAvailability(numStateServers int, cons constraints.Value, series string, placement []string) (state.StateServersChanges, error)
+	MaintenanceWindow(now time.Time) (state.MaintenanceWindowStatus, error)
+}
+
+// New returns a worker which replaces voting state servers that
+// have been unavailable for longer than the grace period. It does
+// nothing unless the environment has more than one voting state
+// server, and defers replacement while the environment's maintenance
+// window is closed.
+func New(st State) worker.Worker {
+	h := &healer{
+		st:          st,
+		unavailable: make(map[string]time.Time),
+	}
+	return worker.NewSimpleWorker(h.loop)
+}
+
+type healer struct {
+	st State
+
+	// unavailable records when each unavailable voting state
+	// server was first seen to be so.
+	unavailable map[string]time.Time
+}
+
+func (h *healer) loop(stop <-chan struct{}) error {
+	for {
+		if err := h.check(time.Now()); err != nil {
+			return errors.Trace(err)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(checkInterval):
+		}
+	}
+}
+
+// check replaces any voting state servers which have been unavailable
+// for longer than the grace period.
+func (h *healer) check(now time.Time) error {
+	health, err := h.st.StateServersHealth()
+	if err != nil {
+		return errors.Annotate(err, "cannot get state server health")
+	}
+	voters := 0
+	var dead []string
+	seen := make(map[string]bool)
+	for _, server := range health {
+		if !server.WantsVote {
+			continue
+		}
+		voters++
+		if server.Available() {
+			continue
+		}
+		seen[server.MachineId] = true
+		since, ok := h.unavailable[server.MachineId]
+		if !ok {
+			logger.Warningf("state server machine %s is unavailable", server.MachineId)
+			h.unavailable[server.MachineId] = now
+			since = now
+		}
+		if now.Sub(since) >= gracePeriod {
+			dead = append(dead, server.MachineId)
+		}
+	}
+	for id := range h.unavailable {
+		if !seen[id] {
+			delete(h.unavailable, id)
+		}
+	}
+	if len(dead) == 0 || voters <= 1 {
+		return nil
+	}
+	window, err := h.st.MaintenanceWindow(now)
+	if err != nil {
+		return errors.Annotate(err, "cannot get maintenance window")
+	}
+	if !window.Open {
+		logger.Infof("deferring replacement of state server machines %v until maintenance window opens at %v", dead, window.Until)
+		return nil
+	}
+	logger.Warningf("replacing unavailable state server machines %v", dead)
+	changes, err := h.st.EnsureAvailability(0, constraints.Value{}, "", nil)
+	if err != nil {
+		return errors.Annotate(err, "cannot replace unavailable state servers")
+	}
+	logger.Infof(
+		"state servers healed: demoted %v, promoted %v, added %v, removed %v",
+		changes.Demoted, changes.Promoted, changes.Added, changes.Removed,
+	)
+	for _, id := range changes.Demoted {
+		delete(h.unavailable, id)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hahealer_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/hahealer"
+)
+
+type HealerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&HealerSuite{})
+
+func (s *HealerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(hahealer.CheckInterval, time.Millisecond)
+	s.PatchValue(hahealer.GracePeriod, time.Duration(0))
+}
+
+func voter(id string, available bool) state.StateServerHealth {
+	return state.StateServerHealth{
+		MachineId:    id,
+		AgentAlive:   available,
+		WantsVote:    true,
+		HasVote:      true,
+		MongoState:   "SECONDARY",
+		MongoHealthy: available,
+	}
+}
+
+func (s *HealerSuite) TestReplacesDeadVoter(c *gc.C) {
+	st := newFakeState(voter("0", true), voter("1", false), voter("2", true))
+	w := hahealer.New(st)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	select {
+	case n := <-st.ensured:
+		c.Assert(n, gc.Equals, 0)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for state servers to be replaced")
+	}
+}
+
+func (s *HealerSuite) TestWaitsForGracePeriod(c *gc.C) {
+	s.PatchValue(hahealer.GracePeriod, time.Hour)
+	st := newFakeState(voter("0", true), voter("1", false), voter("2", true))
+	w := hahealer.New(st)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	st.assertNotEnsured(c)
+}
+
+func (s *HealerSuite) TestWaitsForMaintenanceWindow(c *gc.C) {
+	st := newFakeState(voter("0", true), voter("1", false), voter("2", true))
+	st.setWindowOpen(false)
+	w := hahealer.New(st)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	st.assertNotEnsured(c)
+	st.setWindowOpen(true)
+	select {
+	case <-st.ensured:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for state servers to be replaced")
+	}
+}
+
+func (s *HealerSuite) TestIgnoresSingleStateServer(c *gc.C) {
+	st := newFakeState(voter("0", false))
+	w := hahealer.New(st)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	st.assertNotEnsured(c)
+}
+
+func (s *HealerSuite) TestIgnoresNonVoters(c *gc.C) {
+	standby := voter("3", false)
+	standby.WantsVote = false
+	standby.HasVote = false
+	st := newFakeState(voter("0", true), voter("1", true), voter("2", true), standby)
+	w := hahealer.New(st)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	st.assertNotEnsured(c)
+}
+
+func (s *HealerSuite) TestHealthError(c *gc.C) {
+	st := newFakeState()
+	st.err = errors.New("boom")
+	w := hahealer.New(st)
+
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "cannot get state server health: boom")
+}
+
+type fakeState struct {
+	mu         sync.Mutex
+	health     []state.StateServerHealth
+	err        error
+	windowOpen bool
+	ensured    chan int
+}
+
+func newFakeState(health ...state.StateServerHealth) *fakeState {
+	return &fakeState{
+		health:     health,
+		windowOpen: true,
+		ensured:    make(chan int, 100),
+	}
+}
+
+func (st *fakeState) setWindowOpen(open bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.windowOpen = open
+}
+
+func (st *fakeState) StateServersHealth() ([]state.StateServerHealth, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.health, st.err
+}
+
+func (st *fakeState) EnsureAvailability(numStateServers int, cons constraints.Value, series string, placement []string) (state.StateServersChanges, error) {
+	st.ensured <- numStateServers
+	return state.StateServersChanges{}, nil
+}
+
+func (st *fakeState) MaintenanceWindow(now time.Time) (state.MaintenanceWindowStatus, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return state.MaintenanceWindowStatus{Open: st.windowOpen}, nil
+}
+
+func (st *fakeState) assertNotEnsured(c *gc.C) {
+	select {
+	case <-st.ensured:
+		c.Fatalf("unexpected attempt to replace state servers")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package hostfirewaller implements a worker that restricts access to
+// the ports of services exposed only to particular address ranges,
+// using the host's own firewall. It runs on machines whose provider has
+// no firewall of its own, such as manually provisioned and MAAS
+// machines, where ports are otherwise reachable from any address.
+package hostfirewaller
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.hostfirewaller")
+
+// period is the interval at which the machine's ingress rules are
+// checked for changes.
+var period = 10 * time.Second
+
+// chain is the iptables chain holding the rules managed by the worker.
+const chain = "juju-ingress"
+
+// IngressRulesGetter is the interface of the HostFirewaller facade
+// used by the worker.
+type IngressRulesGetter interface {
+	// IngressRules returns the ingress rules for the ports opened on
+	// the machine.
+	IngressRules() ([]network.IngressRule, error)
+}
+
+// runIptables runs iptables with the given arguments.
+var runIptables = func(args ...string) error {
+	output, err := exec.Command("iptables", args...).CombinedOutput()
+	if err != nil {
+		return errors.Errorf("iptables %s: %v (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type hostFirewaller struct {
+	getter  IngressRulesGetter
+	applied []string
+	synced  bool
+}
+
+// NewWorker returns a worker that keeps the host firewall in line with
+// the ingress rules of the machine. Traffic to a port range whose rule
+// restricts its sources is dropped unless it comes from one of them;
+// all other traffic is left to the host's existing rules.
+func NewWorker(getter IngressRulesGetter) worker.Worker {
+	h := &hostFirewaller{getter: getter}
+	return worker.NewPeriodicWorker(h.update, period)
+}
+
+func (h *hostFirewaller) update(stop <-chan struct{}) error {
+	rules, err := h.getter.IngressRules()
+	if err != nil {
+		return errors.Annotate(err, "cannot get ingress rules")
+	}
+	commands := chainCommands(rules)
+	keys := make([]string, len(commands))
+	for i, args := range commands {
+		keys[i] = strings.Join(args, " ")
+	}
+	if h.synced && stringsEqual(keys, h.applied) {
+		return nil
+	}
+	if err := ensureChain(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := runIptables("-F", chain); err != nil {
+		return errors.Trace(err)
+	}
+	for _, args := range commands {
+		if err := runIptables(args...); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	logger.Infof("host firewall updated for ingress rules %v", rules)
+	h.applied, h.synced = keys, true
+	return nil
+}
+
+// ensureChain creates the chain, and makes incoming traffic pass
+// through it, if it is not already in place.
+func ensureChain() error {
+	if err := runIptables("-n", "-L", chain); err != nil {
+		if err := runIptables("-N", chain); err != nil {
+			return errors.Annotatef(err, "cannot create chain %q", chain)
+		}
+	}
+	if err := runIptables("-C", "INPUT", "-j", chain); err != nil {
+		if err := runIptables("-I", "INPUT", "-j", chain); err != nil {
+			return errors.Annotatef(err, "cannot add chain %q to INPUT", chain)
+		}
+	}
+	return nil
+}
+
+// chainCommands returns the arguments of the iptables commands that
+// append the rules enforcing the given ingress rules to the chain.
+// Rules that admit any address need no enforcement.
+func chainCommands(rules []network.IngressRule) [][]string {
+	var restricted []network.IngressRule
+	for _, rule := range rules {
+		if !rule.IsUnrestricted() {
+			restricted = append(restricted, rule)
+		}
+	}
+	if len(restricted) == 0 {
+		return nil
+	}
+	commands := [][]string{
+		{"-A", chain, "-i", "lo", "-j", "RETURN"},
+		{"-A", chain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "RETURN"},
+	}
+	for _, rule := range restricted {
+		protocol := strings.ToLower(rule.Protocol)
+		ports := fmt.Sprintf("%d:%d", rule.FromPort, rule.ToPort)
+		for _, cidr := range rule.SourceCIDRs {
+			commands = append(commands, []string{
+				"-A", chain, "-p", protocol, "-s", cidr, "--dport", ports, "-j", "RETURN",
+			})
+		}
+		commands = append(commands, []string{
+			"-A", chain, "-p", protocol, "--dport", ports, "-j", "DROP",
+		})
+	}
+	return commands
+}
+
+// stringsEqual reports whether a and b hold the same strings in the
+// same order.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
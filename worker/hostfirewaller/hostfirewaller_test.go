@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package hostfirewaller_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/hostfirewaller"
+)
+
+type hostFirewallerSuite struct {
+	coretesting.BaseSuite
+
+	mu       sync.Mutex
+	rules    []network.IngressRule
+	commands []string
+	missing  map[string]bool
+}
+
+var _ = gc.Suite(&hostFirewallerSuite{})
+
+func (s *hostFirewallerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.rules = nil
+	s.commands = nil
+	s.missing = map[string]bool{
+		"-n -L juju-ingress":       true,
+		"-C INPUT -j juju-ingress": true,
+	}
+	s.PatchValue(hostfirewaller.Period, 10*time.Millisecond)
+	s.PatchValue(hostfirewaller.RunIptables, func(args ...string) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		command := strings.Join(args, " ")
+		s.commands = append(s.commands, command)
+		if s.missing[command] {
+			return errors.New("no such chain")
+		}
+		return nil
+	})
+}
+
+func (s *hostFirewallerSuite) IngressRules() ([]network.IngressRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules, nil
+}
+
+func (s *hostFirewallerSuite) setRules(rules ...network.IngressRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+}
+
+func (s *hostFirewallerSuite) waitForCommands(c *gc.C, expected []string) {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		s.mu.Lock()
+		commands := s.commands
+		s.mu.Unlock()
+		if len(commands) >= len(expected) {
+			c.Assert(commands, jc.DeepEquals, expected)
+			return
+		}
+	}
+	c.Fatalf("iptables never ran %v", expected)
+}
+
+func (s *hostFirewallerSuite) TestRestrictedRules(c *gc.C) {
+	s.setRules(
+		network.MustNewIngressRule(network.MustParsePortRange("80/tcp")),
+		network.MustNewIngressRule(network.MustParsePortRange("5000-5010/udp"), "10.0.0.0/8", "192.168.0.0/16"),
+	)
+	w := hostfirewaller.NewWorker(s)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.waitForCommands(c, []string{
+		"-n -L juju-ingress",
+		"-N juju-ingress",
+		"-C INPUT -j juju-ingress",
+		"-I INPUT -j juju-ingress",
+		"-F juju-ingress",
+		"-A juju-ingress -i lo -j RETURN",
+		"-A juju-ingress -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-A juju-ingress -p udp -s 10.0.0.0/8 --dport 5000:5010 -j RETURN",
+		"-A juju-ingress -p udp -s 192.168.0.0/16 --dport 5000:5010 -j RETURN",
+		"-A juju-ingress -p udp --dport 5000:5010 -j DROP",
+	})
+}
+
+func (s *hostFirewallerSuite) TestUpdatesOnlyOnChange(c *gc.C) {
+	s.missing = nil
+	w := hostfirewaller.NewWorker(s)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	// With no restricted rules the chain is emptied once, and left
+	// alone until the rules change.
+	s.waitForCommands(c, []string{
+		"-n -L juju-ingress",
+		"-C INPUT -j juju-ingress",
+		"-F juju-ingress",
+	})
+	s.setRules(network.MustNewIngressRule(network.MustParsePortRange("443/tcp"), "10.0.0.0/8"))
+	s.waitForCommands(c, []string{
+		"-n -L juju-ingress",
+		"-C INPUT -j juju-ingress",
+		"-F juju-ingress",
+		"-n -L juju-ingress",
+		"-C INPUT -j juju-ingress",
+		"-F juju-ingress",
+		"-A juju-ingress -i lo -j RETURN",
+		"-A juju-ingress -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		"-A juju-ingress -p tcp -s 10.0.0.0/8 --dport 443:443 -j RETURN",
+		"-A juju-ingress -p tcp --dport 443:443 -j DROP",
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package instancemetadataupdater provides a worker which periodically
+// caches the instance types and images offered by the environment's
+// provider in state, so that constraints may be checked against them
+// without calling the provider.
+package instancemetadataupdater
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.instancemetadataupdater")
+
+// refreshInterval is how long the worker waits between refreshes of
+// the cache when the environment config does not change.
+var refreshInterval = time.Hour
+
+// newEnviron is overridden in tests.
+var newEnviron = environs.New
+
+// InstanceMetadataAPI provides the environment config, and replaces
+// the cached instance types and images.
+type InstanceMetadataAPI interface {
+	WatchForEnvironConfigChanges() (apiwatcher.NotifyWatcher, error)
+	EnvironConfig() (*config.Config, error)
+	UpdateInstanceMetadata(metadata params.InstanceMetadata) error
+}
+
+// New returns a worker which refreshes the cached instance types and
+// images periodically, and whenever the environment config changes.
+func New(api InstanceMetadataAPI) worker.Worker {
+	u := &updater{api: api}
+	go func() {
+		defer u.tomb.Done()
+		u.tomb.Kill(u.loop())
+	}()
+	return u
+}
+
+type updater struct {
+	tomb tomb.Tomb
+	api  InstanceMetadataAPI
+}
+
+// Kill is part of the worker.Worker interface.
+func (u *updater) Kill() {
+	u.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (u *updater) Wait() error {
+	return u.tomb.Wait()
+}
+
+func (u *updater) loop() error {
+	w, err := u.api.WatchForEnvironConfigChanges()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch environment config")
+	}
+	defer watcher.Stop(w, &u.tomb)
+
+	var next <-chan time.Time
+	for {
+		select {
+		case <-u.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		case <-next:
+		}
+		next = time.After(refreshInterval)
+		metadata, err := u.fetch()
+		if errors.IsNotSupported(err) {
+			continue
+		} else if err != nil {
+			// The provider may be unreachable; the existing cache
+			// is kept until it can be refreshed.
+			logger.Warningf("cannot fetch instance metadata: %v", err)
+			continue
+		}
+		if err := u.api.UpdateInstanceMetadata(metadata); err != nil {
+			return errors.Annotate(err, "cannot update instance metadata")
+		}
+		logger.Debugf("cached %d instance types and %d images", len(metadata.InstanceTypes), len(metadata.Images))
+	}
+}
+
+// fetch returns the instance types offered by the provider, and its
+// images for the environment's default series. It returns an error
+// satisfying errors.IsNotSupported if the environ cannot list them.
+func (u *updater) fetch() (params.InstanceMetadata, error) {
+	cfg, err := u.api.EnvironConfig()
+	if err != nil {
+		return params.InstanceMetadata{}, errors.Trace(err)
+	}
+	env, err := newEnviron(cfg)
+	if err != nil {
+		return params.InstanceMetadata{}, errors.Trace(err)
+	}
+	lister, ok := env.(environs.InstanceMetadataLister)
+	if !ok {
+		return params.InstanceMetadata{}, errors.NotSupportedf("listing instance metadata")
+	}
+	itypes, err := lister.InstanceTypes()
+	if err != nil {
+		return params.InstanceMetadata{}, errors.Annotate(err, "cannot list instance types")
+	}
+	series := config.PreferredSeries(cfg)
+	images, err := lister.Images(series)
+	if err != nil {
+		return params.InstanceMetadata{}, errors.Annotate(err, "cannot list images")
+	}
+	metadata := params.InstanceMetadata{
+		InstanceTypes: make([]params.InstanceType, len(itypes)),
+		Images:        make([]params.CloudImage, len(images)),
+		Updated:       time.Now(),
+	}
+	for i, itype := range itypes {
+		metadata.InstanceTypes[i] = params.InstanceType{
+			Name:     itype.Name,
+			Arches:   itype.Arches,
+			CpuCores: itype.CpuCores,
+			CpuPower: itype.CpuPower,
+			Mem:      itype.Mem,
+			RootDisk: itype.RootDisk,
+			Cost:     itype.Cost,
+			Tags:     itype.Tags,
+			Gpus:     itype.Gpus,
+			GpuType:  itype.GpuType,
+		}
+		if itype.VirtType != nil {
+			metadata.InstanceTypes[i].VirtType = *itype.VirtType
+		}
+	}
+	for i, image := range images {
+		metadata.Images[i] = params.CloudImage{
+			Id:       image.Id,
+			Series:   series,
+			Arch:     image.Arch,
+			VirtType: image.VirtType,
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package instancemetadataupdater_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/instances"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/instancemetadataupdater"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+	api     *mockAPI
+	environ environs.Environ
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+func (s *workerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		config:  coretesting.EnvironConfig(c),
+		changes: make(chan struct{}, 1),
+		updated: make(chan params.InstanceMetadata, 10),
+	}
+	s.environ = &mockEnviron{api: s.api}
+	s.PatchValue(instancemetadataupdater.NewEnviron, func(cfg *config.Config) (environs.Environ, error) {
+		return s.environ, nil
+	})
+}
+
+func (s *workerSuite) startWorker(c *gc.C) worker.Worker {
+	w := instancemetadataupdater.New(s.api)
+	s.AddCleanup(func(c *gc.C) {
+		w.Kill()
+		c.Check(w.Wait(), jc.ErrorIsNil)
+	})
+	return w
+}
+
+func (s *workerSuite) assertUpdated(c *gc.C) params.InstanceMetadata {
+	select {
+	case metadata := <-s.api.updated:
+		return metadata
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for instance metadata update")
+	}
+	panic("unreachable")
+}
+
+func (s *workerSuite) assertNotUpdated(c *gc.C) {
+	select {
+	case metadata := <-s.api.updated:
+		c.Fatalf("unexpected instance metadata update %#v", metadata)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *workerSuite) TestUpdatesOnConfigChange(c *gc.C) {
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	metadata := s.assertUpdated(c)
+
+	series := config.PreferredSeries(s.api.config)
+	c.Assert(metadata.InstanceTypes, jc.DeepEquals, []params.InstanceType{{
+		Name:     "small",
+		Arches:   []string{"amd64"},
+		CpuCores: 1,
+		Mem:      1024,
+		Cost:     10,
+		VirtType: "hvm",
+	}})
+	c.Assert(metadata.Images, jc.DeepEquals, []params.CloudImage{{
+		Id:       "image-" + series,
+		Series:   series,
+		Arch:     "amd64",
+		VirtType: "hvm",
+	}})
+	c.Assert(metadata.Updated.IsZero(), jc.IsFalse)
+	s.assertNotUpdated(c)
+}
+
+func (s *workerSuite) TestUpdatesPeriodically(c *gc.C) {
+	s.PatchValue(instancemetadataupdater.RefreshInterval, 10*time.Millisecond)
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertUpdated(c)
+	s.assertUpdated(c)
+}
+
+func (s *workerSuite) TestProviderErrorNotFatal(c *gc.C) {
+	s.PatchValue(instancemetadataupdater.RefreshInterval, 10*time.Millisecond)
+	s.api.setListErr(errors.New("connection refused"))
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNotUpdated(c)
+
+	s.api.setListErr(nil)
+	s.assertUpdated(c)
+}
+
+func (s *workerSuite) TestEnvironCannotList(c *gc.C) {
+	s.environ = &struct{ environs.Environ }{}
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNotUpdated(c)
+}
+
+func (s *workerSuite) TestUpdateInstanceMetadataError(c *gc.C) {
+	s.api.updateErr = errors.New("boom")
+	w := instancemetadataupdater.New(s.api)
+	s.api.changes <- struct{}{}
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot update instance metadata: boom")
+}
+
+func (s *workerSuite) TestWatchError(c *gc.C) {
+	s.api.watchErr = errors.New("boom")
+	w := instancemetadataupdater.New(s.api)
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot watch environment config: boom")
+}
+
+type mockAPI struct {
+	mu        sync.Mutex
+	config    *config.Config
+	changes   chan struct{}
+	watchErr  error
+	listErr   error
+	updateErr error
+	updated   chan params.InstanceMetadata
+}
+
+func (api *mockAPI) setListErr(err error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.listErr = err
+}
+
+func (api *mockAPI) WatchForEnvironConfigChanges() (apiwatcher.NotifyWatcher, error) {
+	if api.watchErr != nil {
+		return nil, api.watchErr
+	}
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) EnvironConfig() (*config.Config, error) {
+	return api.config, nil
+}
+
+func (api *mockAPI) UpdateInstanceMetadata(metadata params.InstanceMetadata) error {
+	if api.updateErr != nil {
+		return api.updateErr
+	}
+	api.updated <- metadata
+	return nil
+}
+
+type mockEnviron struct {
+	environs.Environ
+	api *mockAPI
+}
+
+func (e *mockEnviron) InstanceTypes() ([]instances.InstanceType, error) {
+	e.api.mu.Lock()
+	defer e.api.mu.Unlock()
+	if e.api.listErr != nil {
+		return nil, e.api.listErr
+	}
+	virtType := "hvm"
+	return []instances.InstanceType{{
+		Id:       "small",
+		Name:     "small",
+		Arches:   []string{"amd64"},
+		CpuCores: 1,
+		Mem:      1024,
+		Cost:     10,
+		VirtType: &virtType,
+	}}, nil
+}
+
+func (e *mockEnviron) Images(series string) ([]instances.Image, error) {
+	return []instances.Image{{
+		Id:       "image-" + series,
+		Arch:     "amd64",
+		VirtType: "hvm",
+	}}, nil
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package introspection serves a report on the workers run by an
+// agent's dependency engine, so that an operator can see the state of
+// each worker without stopping the agent. It also serves a report on
+// the health of the provider APIs the agent calls.
+package introspection
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/environs/apitrace"
+	"github.com/juju/juju/juju/sockets"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+)
+
+var logger = loggo.GetLogger("juju.worker.introspection")
+
+const (
+	// reportEndpoint is the RPC method which returns the report.
+	reportEndpoint = "Introspection.Report"
+
+	// providerReportEndpoint is the RPC method which returns the
+	// provider API report.
+	providerReportEndpoint = "Introspection.ProviderReport"
+)
+
+// currentProviderReport returns the report on the provider API calls
+// made by the agent.
+var currentProviderReport = apitrace.CurrentReport
+
+// Reporter provides the report served by the introspection worker.
+type Reporter interface {
+	Report() dependency.EngineReport
+}
+
+// SocketPath returns the path of the socket or named pipe on which the
+// agent with the given tag serves its report.
+func SocketPath(dataDir string, tag names.Tag) string {
+	if version.Current.OS == version.Windows {
+		return fmt.Sprintf(`\\.\pipe\%s-engine`, tag)
+	}
+	return filepath.Join(agent.Dir(dataDir, tag), "engine.socket")
+}
+
+// Introspection holds the methods served over the socket.
+type Introspection struct {
+	reporter Reporter
+}
+
+// Report returns the reporter's report.
+func (i *Introspection) Report(_ struct{}, result *dependency.EngineReport) error {
+	*result = i.reporter.Report()
+	return nil
+}
+
+// ProviderReport returns the report on the calls the agent has made
+// to provider APIs, which is empty unless the agent traces them.
+func (i *Introspection) ProviderReport(_ struct{}, result *apitrace.Report) error {
+	*result = currentProviderReport()
+	return nil
+}
+
+// NewWorker returns a worker which serves the reporter's report on the
+// given socket path until it is stopped.
+func NewWorker(reporter Reporter, socketPath string) (worker.Worker, error) {
+	server := rpc.NewServer()
+	if err := server.Register(&Introspection{reporter}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	listener, err := sockets.Listen(socketPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	w := &socketWorker{
+		server:   server,
+		listener: listener,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w, nil
+}
+
+type socketWorker struct {
+	tomb     tomb.Tomb
+	server   *rpc.Server
+	listener net.Listener
+}
+
+func (w *socketWorker) loop() error {
+	served := make(chan error, 1)
+	go func() {
+		served <- w.serve()
+	}()
+	select {
+	case <-w.tomb.Dying():
+		if err := w.listener.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		<-served
+		return tomb.ErrDying
+	case err := <-served:
+		w.listener.Close()
+		return errors.Annotate(err, "cannot accept introspection connection")
+	}
+}
+
+// serve serves connections until the listener fails or is closed.
+func (w *socketWorker) serve() error {
+	for {
+		conn, err := w.listener.Accept()
+		if err != nil {
+			return err
+		}
+		logger.Debugf("serving introspection connection")
+		go w.server.ServeConn(conn)
+	}
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *socketWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *socketWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+// Report returns the report served on the given socket path.
+func Report(socketPath string) (dependency.EngineReport, error) {
+	client, err := sockets.Dial(socketPath)
+	if err != nil {
+		return dependency.EngineReport{}, errors.Annotate(err, "cannot connect to agent")
+	}
+	defer client.Close()
+	var report dependency.EngineReport
+	if err := client.Call(reportEndpoint, struct{}{}, &report); err != nil {
+		return dependency.EngineReport{}, errors.Trace(err)
+	}
+	return report, nil
+}
+
+// ProviderReport returns the provider API report served on the given
+// socket path.
+func ProviderReport(socketPath string) (apitrace.Report, error) {
+	client, err := sockets.Dial(socketPath)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to agent")
+	}
+	defer client.Close()
+	var report apitrace.Report
+	if err := client.Call(providerReportEndpoint, struct{}{}, &report); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return report, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package introspection_test
+
+import (
+	"path/filepath"
+	"runtime"
+	stdtesting "testing"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/apitrace"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/dependency"
+	"github.com/juju/juju/worker/introspection"
+)
+
+func TestPackage(t *stdtesting.T) {
+	gc.TestingT(t)
+}
+
+type SocketSuite struct {
+	testing.BaseSuite
+	socketPath string
+}
+
+var _ = gc.Suite(&SocketSuite{})
+
+func (s *SocketSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.socketPath = filepath.Join(c.MkDir(), "engine.socket")
+	if runtime.GOOS == "windows" {
+		s.socketPath = `\\.\pipe` + s.socketPath[2:]
+	}
+}
+
+func (s *SocketSuite) TestReport(c *gc.C) {
+	expect := dependency.EngineReport{
+		State: "started",
+		Manifolds: map[string]dependency.WorkerReport{
+			"api-caller": {
+				State:      "started",
+				Inputs:     []string{"agent"},
+				StartCount: 2,
+			},
+			"uniter": {
+				State:  "stopped",
+				Inputs: []string{"api-caller"},
+				Error:  "dependency not available",
+			},
+		},
+	}
+	w, err := introspection.NewWorker(fakeReporter{expect}, s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	report, err := introspection.Report(s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report, jc.DeepEquals, expect)
+}
+
+func (s *SocketSuite) TestProviderReport(c *gc.C) {
+	expect := apitrace.Report{
+		"ec2.example.com": {
+			Calls: map[string]apitrace.CallReport{
+				"DescribeInstances": {
+					Calls:       3,
+					Errors:      1,
+					MeanLatency: "200ms",
+					MaxLatency:  "400ms",
+					LastError:   "Service Unavailable",
+				},
+			},
+			RateLimited: 1,
+		},
+	}
+	s.PatchValue(introspection.CurrentProviderReport, func() apitrace.Report {
+		return expect
+	})
+	w, err := introspection.NewWorker(fakeReporter{}, s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	report, err := introspection.ProviderReport(s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report, jc.DeepEquals, expect)
+}
+
+func (s *SocketSuite) TestStop(c *gc.C) {
+	w, err := introspection.NewWorker(fakeReporter{}, s.socketPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+
+	_, err = introspection.Report(s.socketPath)
+	c.Assert(err, gc.ErrorMatches, "cannot connect to agent: .*")
+}
+
+type fakeReporter struct {
+	report dependency.EngineReport
+}
+
+func (r fakeReporter) Report() dependency.EngineReport {
+	return r.report
+}
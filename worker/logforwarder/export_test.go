@@ -0,0 +1,10 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder
+
+var (
+	PollInterval  = &pollInterval
+	BatchSize     = &batchSize
+	FormatRFC5424 = formatRFC5424
+)
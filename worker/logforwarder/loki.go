@@ -0,0 +1,109 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// lokiPushPath is the path of the Loki push API, relative to the
+// target's address.
+const lokiPushPath = "/loki/api/v1/push"
+
+type lokiSender struct {
+	client    *http.Client
+	transport *http.Transport
+	url       string
+	envUUID   string
+}
+
+// NewLokiSender returns a Sender which delivers log records through
+// the Loki push API.
+func NewLokiSender(config params.LogForwardTargetConfig, envUUID string) (Sender, error) {
+	tlsConf, err := tlsConfig(config.CACert)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConf}
+	return &lokiSender{
+		client:    &http.Client{Transport: transport, Timeout: sendTimeout},
+		transport: transport,
+		url:       strings.TrimSuffix(config.Address, "/") + lokiPushPath,
+		envUUID:   envUUID,
+	}, nil
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Send is part of the Sender interface.
+func (s *lokiSender) Send(records []params.LogForwardRecord) error {
+	body, err := json.Marshal(s.pushRequest(records))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Annotate(err, "cannot push logs to loki")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+		return errors.Errorf("cannot push logs to loki: %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// pushRequest groups the records into streams labelled by the entity
+// that logged them and their level, keeping them in order within each
+// stream.
+func (s *lokiSender) pushRequest(records []params.LogForwardRecord) *lokiPushRequest {
+	req := &lokiPushRequest{}
+	streams := make(map[string]*lokiStream)
+	for _, rec := range records {
+		level := strings.ToLower(rec.Level.String())
+		key := rec.Entity + " " + level
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{
+				Stream: map[string]string{
+					"juju_env":    s.envUUID,
+					"juju_entity": rec.Entity,
+					"level":       level,
+				},
+			}
+			streams[key] = stream
+			req.Streams = append(req.Streams, stream)
+		}
+		line := fmt.Sprintf("%s %s %s", rec.Module, rec.Location, rec.Message)
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(rec.Time.UnixNano(), 10),
+			line,
+		})
+	}
+	return req
+}
+
+// Close is part of the Sender interface.
+func (s *lokiSender) Close() error {
+	s.transport.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logforwarder"
+)
+
+type lokiSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&lokiSuite{})
+
+type pushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]string        `json:"values"`
+	} `json:"streams"`
+}
+
+func (s *lokiSuite) TestSend(c *gc.C) {
+	var pushed pushRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.Check(req.Method, gc.Equals, "POST")
+		c.Check(req.URL.Path, gc.Equals, "/loki/api/v1/push")
+		c.Check(req.Header.Get("Content-Type"), gc.Equals, "application/json")
+		c.Check(json.NewDecoder(req.Body).Decode(&pushed), jc.ErrorIsNil)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender, err := logforwarder.NewLokiSender(params.LogForwardTargetConfig{
+		Name:    "loki",
+		Type:    "loki",
+		Address: server.URL + "/",
+	}, "some-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sender.Close()
+
+	second := sampleRecord
+	second.Message = "second"
+	other := sampleRecord
+	other.Entity = "machine-0"
+	other.Level = loggo.INFO
+	err = sender.Send([]params.LogForwardRecord{sampleRecord, other, second})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(pushed.Streams, gc.HasLen, 2)
+	c.Check(pushed.Streams[0].Stream, jc.DeepEquals, map[string]string{
+		"juju_env":    "some-uuid",
+		"juju_entity": "unit-mysql-0",
+		"level":       "error",
+	})
+	c.Check(pushed.Streams[0].Values, jc.DeepEquals, [][]string{
+		{"1433161845500000000", `juju.worker.uniter uniter.go:42 hook "install" failed`},
+		{"1433161845500000000", "juju.worker.uniter uniter.go:42 second"},
+	})
+	c.Check(pushed.Streams[1].Stream["juju_entity"], gc.Equals, "machine-0")
+	c.Check(pushed.Streams[1].Stream["level"], gc.Equals, "info")
+	c.Check(pushed.Streams[1].Values, gc.HasLen, 1)
+}
+
+func (s *lokiSuite) TestSendError(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender, err := logforwarder.NewLokiSender(params.LogForwardTargetConfig{
+		Name:    "loki",
+		Type:    "loki",
+		Address: server.URL,
+	}, "some-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sender.Close()
+
+	err = sender.Send([]params.LogForwardRecord{sampleRecord})
+	c.Assert(err, gc.ErrorMatches, "cannot push logs to loki: 400 Bad Request: entry out of order")
+}
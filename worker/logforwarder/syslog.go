@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+const (
+	// syslogFacility is the "user-level messages" facility.
+	syslogFacility = 1
+
+	// canonicalPEN is Canonical's IANA private enterprise number,
+	// which qualifies the structured data juju sends.
+	canonicalPEN = 28978
+)
+
+type syslogSender struct {
+	conn    net.Conn
+	envUUID string
+}
+
+// NewSyslogSender returns a Sender which delivers log records as
+// RFC5424 syslog messages over TLS, framed as described by RFC5425.
+func NewSyslogSender(config params.LogForwardTargetConfig, envUUID string) (Sender, error) {
+	tlsConf, err := tlsConfig(config.CACert)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dialer := &net.Dialer{Timeout: sendTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", config.Address, tlsConf)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot connect to syslog target %q", config.Name)
+	}
+	return &syslogSender{conn: conn, envUUID: envUUID}, nil
+}
+
+// Send is part of the Sender interface.
+func (s *syslogSender) Send(records []params.LogForwardRecord) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return errors.Trace(err)
+	}
+	w := bufio.NewWriter(s.conn)
+	for _, rec := range records {
+		msg := formatRFC5424(s.envUUID, rec)
+		if _, err := fmt.Fprintf(w, "%d %s", len(msg), msg); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(w.Flush())
+}
+
+// Close is part of the Sender interface.
+func (s *syslogSender) Close() error {
+	return s.conn.Close()
+}
+
+// formatRFC5424 returns the record formatted as an RFC5424 syslog
+// message from the environment with the given UUID.
+func formatRFC5424(envUUID string, rec params.LogForwardRecord) string {
+	hostname := rec.Entity
+	if hostname == "" {
+		hostname = "-"
+	}
+	return fmt.Sprintf(`<%d>1 %s %s juju - - [juju@%d env="%s" module="%s" location="%s"] %s`,
+		syslogFacility*8+syslogSeverity(rec.Level),
+		rec.Time.UTC().Format(time.RFC3339Nano),
+		hostname,
+		canonicalPEN,
+		escapeParamValue(envUUID),
+		escapeParamValue(rec.Module),
+		escapeParamValue(rec.Location),
+		rec.Message,
+	)
+}
+
+// syslogSeverity returns the syslog severity corresponding to the
+// given logging level.
+func syslogSeverity(level loggo.Level) int {
+	switch {
+	case level >= loggo.CRITICAL:
+		return 2
+	case level >= loggo.ERROR:
+		return 3
+	case level >= loggo.WARNING:
+		return 4
+	case level >= loggo.INFO:
+		return 6
+	}
+	return 7
+}
+
+var paramValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// escapeParamValue escapes the characters which may not appear
+// unescaped in a structured data parameter value.
+func escapeParamValue(value string) string {
+	return paramValueEscaper.Replace(value)
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder_test
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/cert"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logforwarder"
+)
+
+type syslogSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&syslogSuite{})
+
+var sampleRecord = params.LogForwardRecord{
+	Id:       "5571a1f0e4b0c3a2b1d0e9f8",
+	Time:     time.Date(2015, 6, 1, 12, 30, 45, 500000000, time.UTC),
+	Entity:   "unit-mysql-0",
+	Module:   "juju.worker.uniter",
+	Location: "uniter.go:42",
+	Level:    loggo.ERROR,
+	Message:  `hook "install" failed`,
+}
+
+func (s *syslogSuite) TestFormatRFC5424(c *gc.C) {
+	msg := logforwarder.FormatRFC5424("some-uuid", sampleRecord)
+	c.Assert(msg, gc.Equals, `<11>1 2015-06-01T12:30:45.5Z unit-mysql-0 juju - - `+
+		`[juju@28978 env="some-uuid" module="juju.worker.uniter" location="uniter.go:42"] hook "install" failed`)
+}
+
+func (s *syslogSuite) TestFormatRFC5424Severity(c *gc.C) {
+	for level, pri := range map[loggo.Level]int{
+		loggo.CRITICAL: 10,
+		loggo.ERROR:    11,
+		loggo.WARNING:  12,
+		loggo.INFO:     14,
+		loggo.DEBUG:    15,
+		loggo.TRACE:    15,
+	} {
+		rec := sampleRecord
+		rec.Level = level
+		msg := logforwarder.FormatRFC5424("some-uuid", rec)
+		c.Check(msg, jc.HasPrefix, fmt.Sprintf("<%d>1 ", pri))
+	}
+}
+
+func (s *syslogSuite) TestFormatRFC5424Escaping(c *gc.C) {
+	rec := sampleRecord
+	rec.Entity = ""
+	rec.Module = `odd"module]\`
+	msg := logforwarder.FormatRFC5424("some-uuid", rec)
+	c.Assert(msg, gc.Matches, `<11>1 \S+ - juju - - \[juju@28978 env="some-uuid" module="odd\\"module\\]\\\\" .*`)
+}
+
+func (s *syslogSuite) TestSend(c *gc.C) {
+	srvCert, srvKey, err := cert.NewServer(coretesting.CACert, coretesting.CAKey, time.Now().AddDate(1, 0, 0), []string{"127.0.0.1"})
+	c.Assert(err, jc.ErrorIsNil)
+	keyPair, err := tls.X509KeyPair(srvCert, srvKey)
+	c.Assert(err, jc.ErrorIsNil)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{keyPair}})
+	c.Assert(err, jc.ErrorIsNil)
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			var length int
+			if _, err := fmt.Fscanf(r, "%d ", &length); err != nil {
+				return
+			}
+			msg := make([]byte, length)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	sender, err := logforwarder.NewSyslogSender(params.LogForwardTargetConfig{
+		Name:    "syslog",
+		Type:    "syslog",
+		Address: listener.Addr().String(),
+		CACert:  coretesting.CACert,
+	}, "some-uuid")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sender.Close()
+
+	second := sampleRecord
+	second.Message = "second"
+	err = sender.Send([]params.LogForwardRecord{sampleRecord, second})
+	c.Assert(err, jc.ErrorIsNil)
+	for _, expected := range []params.LogForwardRecord{sampleRecord, second} {
+		select {
+		case msg := <-received:
+			c.Assert(msg, gc.Equals, logforwarder.FormatRFC5424("some-uuid", expected))
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for syslog message")
+		}
+	}
+}
+
+func (s *syslogSuite) TestBadCACert(c *gc.C) {
+	_, err := logforwarder.NewSyslogSender(params.LogForwardTargetConfig{
+		Name:    "syslog",
+		Type:    "syslog",
+		Address: "127.0.0.1:6514",
+		CACert:  "not a cert",
+	}, "some-uuid")
+	c.Assert(err, gc.ErrorMatches, "CA certificate not valid")
+}
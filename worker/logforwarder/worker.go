@@ -0,0 +1,214 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package logforwarder provides a worker which forwards the logs
+// stored by the state servers to external targets.
+package logforwarder
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"reflect"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.logforwarder")
+
+var (
+	// pollInterval is how long the worker waits between checks
+	// for new log records to forward.
+	pollInterval = 5 * time.Second
+
+	// batchSize is the maximum number of log records sent to a
+	// target at once.
+	batchSize = 500
+
+	// sendTimeout bounds the time taken to connect to a target
+	// and to send a batch of records to it.
+	sendTimeout = 30 * time.Second
+)
+
+// LogForwardingAPI provides the log records to forward, and records
+// the outcome of forwarding them.
+type LogForwardingAPI interface {
+	Targets() ([]params.LogForwardTarget, error)
+	NextLogs(target string, limit int) ([]params.LogForwardRecord, error)
+	SetDeliveryStatus(delivery params.LogForwardDelivery) error
+}
+
+// Sender delivers log records to an external target.
+type Sender interface {
+	// Send delivers the given records, in order, to the target.
+	Send(records []params.LogForwardRecord) error
+
+	// Close closes the connection to the target.
+	Close() error
+}
+
+// OpenSender connects to the target with the given configuration,
+// which will be sent records from the environment with the given
+// UUID.
+var OpenSender = func(config params.LogForwardTargetConfig, envUUID string) (Sender, error) {
+	switch config.Type {
+	case "syslog":
+		return NewSyslogSender(config, envUUID)
+	case "loki":
+		return NewLokiSender(config, envUUID)
+	}
+	return nil, errors.NotValidf("log forwarding target type %q", config.Type)
+}
+
+// New returns a worker which forwards the logs of the environment
+// with the given UUID to the targets that apply to it. Records that
+// cannot be delivered remain stored, and delivery is retried when the
+// worker next polls for new records.
+func New(api LogForwardingAPI, envUUID string) worker.Worker {
+	f := &forwarder{
+		api:     api,
+		envUUID: envUUID,
+		senders: make(map[string]*targetSender),
+	}
+	return worker.NewSimpleWorker(f.loop)
+}
+
+type forwarder struct {
+	api     LogForwardingAPI
+	envUUID string
+	senders map[string]*targetSender
+}
+
+// targetSender holds an open Sender along with the configuration
+// it was opened with, so that it can be reopened if that changes.
+type targetSender struct {
+	config params.LogForwardTargetConfig
+	sender Sender
+}
+
+func (f *forwarder) loop(stop <-chan struct{}) error {
+	defer f.closeSenders(nil)
+	for {
+		if err := f.forwardAll(stop); err != nil {
+			return errors.Trace(err)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// forwardAll forwards any new records to each of the targets.
+func (f *forwarder) forwardAll(stop <-chan struct{}) error {
+	targets, err := f.api.Targets()
+	if err != nil {
+		return errors.Annotate(err, "cannot get log forwarding targets")
+	}
+	current := make(map[string]bool)
+	for _, target := range targets {
+		current[target.Config.Name] = true
+		f.forward(target.Config, stop)
+	}
+	f.closeSenders(current)
+	return nil
+}
+
+// forward sends batches of records to the target until it has caught
+// up, delivery fails, or the worker is stopped. The outcome of each
+// batch is reported as the target's delivery status.
+func (f *forwarder) forward(config params.LogForwardTargetConfig, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		records, err := f.api.NextLogs(config.Name, batchSize)
+		if err != nil {
+			logger.Warningf("cannot get logs to forward to %q: %v", config.Name, err)
+			return
+		}
+		if len(records) == 0 {
+			return
+		}
+		delivery := params.LogForwardDelivery{Target: config.Name}
+		sendErr := f.send(config, records)
+		if sendErr != nil {
+			logger.Warningf("cannot forward logs to %q: %v", config.Name, sendErr)
+			delivery.Error = sendErr.Error()
+			f.closeSender(config.Name)
+		} else {
+			last := records[len(records)-1]
+			delivery.LastSentId = last.Id
+			delivery.LastSentTime = last.Time
+			delivery.Delivered = len(records)
+		}
+		if err := f.api.SetDeliveryStatus(delivery); err != nil {
+			logger.Warningf("cannot record delivery status of %q: %v", config.Name, err)
+			return
+		}
+		if sendErr != nil || len(records) < batchSize {
+			return
+		}
+	}
+}
+
+// send delivers the records to the target, opening a new connection
+// to it if necessary.
+func (f *forwarder) send(config params.LogForwardTargetConfig, records []params.LogForwardRecord) error {
+	ts, ok := f.senders[config.Name]
+	if ok && !reflect.DeepEqual(ts.config, config) {
+		f.closeSender(config.Name)
+		ok = false
+	}
+	if !ok {
+		sender, err := OpenSender(config, f.envUUID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ts = &targetSender{config: config, sender: sender}
+		f.senders[config.Name] = ts
+	}
+	return ts.sender.Send(records)
+}
+
+func (f *forwarder) closeSender(name string) {
+	ts, ok := f.senders[name]
+	if !ok {
+		return
+	}
+	if err := ts.sender.Close(); err != nil {
+		logger.Debugf("error closing connection to %q: %v", name, err)
+	}
+	delete(f.senders, name)
+}
+
+// closeSenders closes the connections to all targets not in keep.
+func (f *forwarder) closeSenders(keep map[string]bool) {
+	for name := range f.senders {
+		if !keep[name] {
+			f.closeSender(name)
+		}
+	}
+}
+
+// tlsConfig returns the TLS configuration used to connect to a target
+// whose certificate is signed by the given CA certificate, or by one
+// of the system's authorities if caCert is empty.
+func tlsConfig(caCert string) (*tls.Config, error) {
+	config := &tls.Config{}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, errors.NotValidf("CA certificate")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
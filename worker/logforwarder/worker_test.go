@@ -0,0 +1,241 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/logforwarder"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+	api    *mockAPI
+	sender *mockSender
+	opened chan params.LogForwardTargetConfig
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+var syslogTarget = params.LogForwardTarget{
+	Config: params.LogForwardTargetConfig{
+		Name:    "syslog",
+		Type:    "syslog",
+		Address: "syslog.example.com:6514",
+	},
+}
+
+func (s *workerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		targets:    []params.LogForwardTarget{syslogTarget},
+		deliveries: make(chan params.LogForwardDelivery, 10),
+	}
+	s.sender = &mockSender{}
+	s.opened = make(chan params.LogForwardTargetConfig, 10)
+	s.PatchValue(&logforwarder.OpenSender, func(config params.LogForwardTargetConfig, envUUID string) (logforwarder.Sender, error) {
+		c.Check(envUUID, gc.Equals, coretesting.EnvironmentTag.Id())
+		s.opened <- config
+		return s.sender, nil
+	})
+	s.PatchValue(logforwarder.PollInterval, 10*time.Millisecond)
+}
+
+func (s *workerSuite) startWorker(c *gc.C) worker.Worker {
+	w := logforwarder.New(s.api, coretesting.EnvironmentTag.Id())
+	s.AddCleanup(func(c *gc.C) {
+		w.Kill()
+		c.Check(w.Wait(), jc.ErrorIsNil)
+	})
+	return w
+}
+
+func (s *workerSuite) nextDelivery(c *gc.C) params.LogForwardDelivery {
+	select {
+	case delivery := <-s.api.deliveries:
+		return delivery
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for delivery status")
+	}
+	panic("unreachable")
+}
+
+func makeRecords(ids ...string) []params.LogForwardRecord {
+	records := make([]params.LogForwardRecord, len(ids))
+	for i, id := range ids {
+		records[i] = params.LogForwardRecord{
+			Id:      id,
+			Time:    time.Date(2015, 6, 1, 12, 0, i, 0, time.UTC),
+			Entity:  "machine-0",
+			Level:   loggo.INFO,
+			Message: "message " + id,
+		}
+	}
+	return records
+}
+
+func (s *workerSuite) TestForwardsLogs(c *gc.C) {
+	records := makeRecords("a", "b")
+	s.api.addBatches(records)
+	s.startWorker(c)
+
+	delivery := s.nextDelivery(c)
+	c.Assert(delivery, jc.DeepEquals, params.LogForwardDelivery{
+		Target:       "syslog",
+		LastSentId:   "b",
+		LastSentTime: records[1].Time,
+		Delivered:    2,
+	})
+	c.Assert(s.sender.sent(), jc.DeepEquals, records)
+	c.Assert(<-s.opened, jc.DeepEquals, syslogTarget.Config)
+}
+
+func (s *workerSuite) TestForwardsInBatches(c *gc.C) {
+	s.PatchValue(logforwarder.BatchSize, 2)
+	s.api.addBatches(makeRecords("a", "b"), makeRecords("c", "d"), makeRecords("e"))
+	s.startWorker(c)
+
+	c.Assert(s.nextDelivery(c).LastSentId, gc.Equals, "b")
+	c.Assert(s.nextDelivery(c).LastSentId, gc.Equals, "d")
+	c.Assert(s.nextDelivery(c).LastSentId, gc.Equals, "e")
+	c.Assert(s.sender.sent(), gc.HasLen, 5)
+	// The connection is reused for each batch.
+	c.Assert(s.opened, gc.HasLen, 1)
+}
+
+func (s *workerSuite) TestSendFailureReported(c *gc.C) {
+	s.sender.sendErr = errors.New("connection reset")
+	s.api.addBatches(makeRecords("a"))
+	s.startWorker(c)
+
+	delivery := s.nextDelivery(c)
+	c.Assert(delivery, jc.DeepEquals, params.LogForwardDelivery{
+		Target: "syslog",
+		Error:  "connection reset",
+	})
+	c.Assert(s.sender.isClosed(), jc.IsTrue)
+}
+
+func (s *workerSuite) TestOpenFailureReported(c *gc.C) {
+	s.PatchValue(&logforwarder.OpenSender, func(params.LogForwardTargetConfig, string) (logforwarder.Sender, error) {
+		return nil, errors.New("no route to host")
+	})
+	s.api.addBatches(makeRecords("a"))
+	s.startWorker(c)
+
+	delivery := s.nextDelivery(c)
+	c.Assert(delivery.Error, gc.Equals, "no route to host")
+	c.Assert(delivery.Delivered, gc.Equals, 0)
+}
+
+func (s *workerSuite) TestRemovedTargetClosed(c *gc.C) {
+	s.api.addBatches(makeRecords("a"))
+	s.startWorker(c)
+	s.nextDelivery(c)
+	c.Assert(s.sender.isClosed(), jc.IsFalse)
+
+	s.api.setTargets(nil)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if s.sender.isClosed() {
+			return
+		}
+	}
+	c.Fatalf("connection to removed target not closed")
+}
+
+func (s *workerSuite) TestTargetsError(c *gc.C) {
+	s.api.targetsErr = errors.New("boom")
+	w := logforwarder.New(s.api, coretesting.EnvironmentTag.Id())
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot get log forwarding targets: boom")
+}
+
+type mockAPI struct {
+	mu         sync.Mutex
+	targets    []params.LogForwardTarget
+	targetsErr error
+	batches    [][]params.LogForwardRecord
+	deliveries chan params.LogForwardDelivery
+}
+
+func (api *mockAPI) addBatches(batches ...[]params.LogForwardRecord) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.batches = append(api.batches, batches...)
+}
+
+func (api *mockAPI) setTargets(targets []params.LogForwardTarget) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.targets = targets
+}
+
+func (api *mockAPI) Targets() ([]params.LogForwardTarget, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.targets, api.targetsErr
+}
+
+func (api *mockAPI) NextLogs(target string, limit int) ([]params.LogForwardRecord, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.batches) == 0 {
+		return nil, nil
+	}
+	batch := api.batches[0]
+	if len(batch) > limit {
+		panic("batch larger than limit")
+	}
+	api.batches = api.batches[1:]
+	return batch, nil
+}
+
+func (api *mockAPI) SetDeliveryStatus(delivery params.LogForwardDelivery) error {
+	api.deliveries <- delivery
+	return nil
+}
+
+type mockSender struct {
+	mu      sync.Mutex
+	records []params.LogForwardRecord
+	sendErr error
+	closed  bool
+}
+
+func (s *mockSender) Send(records []params.LogForwardRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *mockSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *mockSender) sent() []params.LogForwardRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records
+}
+
+func (s *mockSender) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
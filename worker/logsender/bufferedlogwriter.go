@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+)
+
+const writerName = "buffered-logs"
+
+// LogRecord represents a log message in an agent which is to be
+// transmitted to the state server.
+type LogRecord struct {
+	Time     time.Time
+	Module   string
+	Location string // e.g. "foo.go:42"
+	Level    loggo.Level
+	Message  string
+}
+
+// LogRecordCh defines the channel type used to send log message
+// structs within the unit and machine agents.
+type LogRecordCh chan *LogRecord
+
+// InstallBufferedLogWriter creates a new BufferedLogWriter, registers
+// it with Loggo and returns its output channel. At most maxLen records
+// are held while waiting to be sent; further records are dropped.
+func InstallBufferedLogWriter(maxLen int) (LogRecordCh, error) {
+	writer := NewBufferedLogWriter(maxLen)
+	err := loggo.RegisterWriter(writerName, writer, loggo.TRACE)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to set up log buffering")
+	}
+	return writer.Logs(), nil
+}
+
+// UninstallBufferedLogWriter removes the BufferedLogWriter previously
+// installed by InstallBufferedLogWriter.
+func UninstallBufferedLogWriter() error {
+	_, _, err := loggo.RemoveWriter(writerName)
+	return errors.Annotate(err, "failed to remove log buffering")
+}
+
+// BufferedLogWriter is a loggo.Writer which holds log records on a
+// buffered channel until they are read from it.
+type BufferedLogWriter struct {
+	out LogRecordCh
+}
+
+// NewBufferedLogWriter returns a new BufferedLogWriter which will
+// hold at most maxLen log records.
+func NewBufferedLogWriter(maxLen int) *BufferedLogWriter {
+	return &BufferedLogWriter{
+		out: make(LogRecordCh, maxLen),
+	}
+}
+
+// Write sends a new log message to the writer. This implements the
+// loggo.Writer interface. If the buffer is full, the message is
+// dropped rather than blocking the caller.
+func (w *BufferedLogWriter) Write(level loggo.Level, module, filename string, line int, ts time.Time, message string) {
+	rec := &LogRecord{
+		Time:     ts,
+		Module:   module,
+		Location: fmt.Sprintf("%s:%d", filepath.Base(filename), line),
+		Level:    level,
+		Message:  message,
+	}
+	select {
+	case w.out <- rec:
+	default:
+	}
+}
+
+// Logs returns a channel which emits log messages that have been
+// sent to the BufferedLogWriter instance.
+func (w *BufferedLogWriter) Logs() LogRecordCh {
+	return w.out
+}
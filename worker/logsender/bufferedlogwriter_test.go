@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender_test
+
+import (
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logsender"
+)
+
+type bufferedLogWriterSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&bufferedLogWriterSuite{})
+
+func (s *bufferedLogWriterSuite) TestWrite(c *gc.C) {
+	writer := logsender.NewBufferedLogWriter(5)
+	now := time.Now()
+	writer.Write(loggo.INFO, "some.where", "/path/to/foo.go", 42, now, "all is well")
+
+	select {
+	case rec := <-writer.Logs():
+		c.Assert(rec, jc.DeepEquals, &logsender.LogRecord{
+			Time:     now,
+			Module:   "some.where",
+			Location: "foo.go:42",
+			Level:    loggo.INFO,
+			Message:  "all is well",
+		})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log record")
+	}
+}
+
+func (s *bufferedLogWriterSuite) TestDropsWhenFull(c *gc.C) {
+	writer := logsender.NewBufferedLogWriter(2)
+	now := time.Now()
+	for _, message := range []string{"one", "two", "three"} {
+		writer.Write(loggo.INFO, "module", "file.go", 1, now, message)
+	}
+	c.Assert((<-writer.Logs()).Message, gc.Equals, "one")
+	c.Assert((<-writer.Logs()).Message, gc.Equals, "two")
+	select {
+	case rec := <-writer.Logs():
+		c.Fatalf("unexpected log record: %q", rec.Message)
+	default:
+	}
+}
+
+func (s *bufferedLogWriterSuite) TestInstall(c *gc.C) {
+	logs, err := logsender.InstallBufferedLogWriter(10)
+	c.Assert(err, jc.ErrorIsNil)
+	defer logsender.UninstallBufferedLogWriter()
+
+	logger := loggo.GetLogger("logsender-test")
+	logger.SetLogLevel(loggo.INFO)
+	logger.Infof("hello")
+
+	select {
+	case rec := <-logs:
+		c.Assert(rec.Module, gc.Equals, "logsender-test")
+		c.Assert(rec.Level, gc.Equals, loggo.INFO)
+		c.Assert(rec.Message, gc.Equals, "hello")
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log record")
+	}
+
+	_, err = logsender.InstallBufferedLogWriter(10)
+	c.Assert(err, gc.ErrorMatches, "failed to set up log buffering: .*")
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker"
+)
+
+// LogSink is implemented by connections to the API server's logsink
+// endpoint.
+type LogSink interface {
+	WriteLog(rec *params.LogRecord) error
+	Close() error
+}
+
+// OpenLogSinkFunc opens a connection to the API server's logsink
+// endpoint.
+type OpenLogSinkFunc func() (LogSink, error)
+
+// New starts a logsender worker which reads log message structs from
+// a channel and sends them to the state server via the logsink API.
+func New(logs LogRecordCh, openSink OpenLogSinkFunc) worker.Worker {
+	loop := func(stop <-chan struct{}) error {
+		sink, err := openSink()
+		if err != nil {
+			return errors.Annotate(err, "logsender dial failed")
+		}
+		defer sink.Close()
+		for {
+			select {
+			case rec := <-logs:
+				err := sink.WriteLog(&params.LogRecord{
+					Time:     rec.Time,
+					Module:   rec.Module,
+					Location: rec.Location,
+					Level:    rec.Level,
+					Message:  rec.Message,
+				})
+				if err != nil {
+					return errors.Annotate(err, "logsender send failed")
+				}
+			case <-stop:
+				return nil
+			}
+		}
+	}
+	return worker.NewSimpleWorker(loop)
+}
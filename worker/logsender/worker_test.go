@@ -0,0 +1,93 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logsender_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logsender"
+)
+
+type workerSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+type mockLogSink struct {
+	records  chan *params.LogRecord
+	writeErr error
+	closed   bool
+}
+
+func (s *mockLogSink) WriteLog(rec *params.LogRecord) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	s.records <- rec
+	return nil
+}
+
+func (s *mockLogSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *workerSuite) TestSendsLogs(c *gc.C) {
+	sink := &mockLogSink{records: make(chan *params.LogRecord, 1)}
+	logs := make(logsender.LogRecordCh)
+	w := logsender.New(logs, func() (logsender.LogSink, error) {
+		return sink, nil
+	})
+
+	now := time.Now()
+	logs <- &logsender.LogRecord{
+		Time:     now,
+		Module:   "some.where",
+		Location: "foo.go:42",
+		Level:    loggo.INFO,
+		Message:  "all is well",
+	}
+	select {
+	case rec := <-sink.records:
+		c.Assert(rec, jc.DeepEquals, &params.LogRecord{
+			Time:     now,
+			Module:   "some.where",
+			Location: "foo.go:42",
+			Level:    loggo.INFO,
+			Message:  "all is well",
+		})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for log record")
+	}
+
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(sink.closed, jc.IsTrue)
+}
+
+func (s *workerSuite) TestOpenError(c *gc.C) {
+	w := logsender.New(make(logsender.LogRecordCh), func() (logsender.LogSink, error) {
+		return nil, errors.New("boom")
+	})
+	c.Assert(w.Wait(), gc.ErrorMatches, "logsender dial failed: boom")
+}
+
+func (s *workerSuite) TestSendError(c *gc.C) {
+	sink := &mockLogSink{writeErr: errors.New("boom")}
+	logs := make(logsender.LogRecordCh, 1)
+	logs <- &logsender.LogRecord{Message: "lost"}
+	w := logsender.New(logs, func() (logsender.LogSink, error) {
+		return sink, nil
+	})
+	c.Assert(w.Wait(), gc.ErrorMatches, "logsender send failed: boom")
+	c.Assert(sink.closed, jc.IsTrue)
+}
@@ -3,4 +3,10 @@
 
 package machiner
 
-var InterfaceAddrs = &interfaceAddrs
+var (
+	InterfaceAddrs  = &interfaceAddrs
+	DetectHardware  = &detectHardware
+	ProcDir         = &procDir
+	SysDir          = &sysDir
+	SSHHostKeysGlob = &sshHostKeysGlob
+)
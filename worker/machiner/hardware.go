@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machiner
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/arch"
+)
+
+// procDir and sysDir are overridden in tests.
+var (
+	procDir = "/proc"
+	sysDir  = "/sys"
+)
+
+var detectHardware = hostHardware
+
+// hostHardware returns the hardware characteristics of the host, as
+// far as they can be detected.
+func hostHardware() (instance.HardwareCharacteristics, error) {
+	var hc instance.HardwareCharacteristics
+	hostArch := arch.HostArch()
+	hc.Arch = &hostArch
+
+	mem, err := memTotal()
+	if err != nil {
+		return hc, errors.Annotate(err, "cannot detect memory")
+	}
+	hc.Mem = &mem
+
+	cores, err := cpuCores()
+	if err != nil {
+		return hc, errors.Annotate(err, "cannot detect cpu cores")
+	}
+	hc.CpuCores = &cores
+
+	if hc.RootDisk, err = rootDiskSize(); err != nil {
+		return hc, errors.Annotate(err, "cannot detect root disk")
+	}
+
+	numaNodes, err := countEntries(filepath.Join(sysDir, "devices", "system", "node", "node[0-9]*"))
+	if err != nil {
+		return hc, errors.Annotate(err, "cannot detect NUMA nodes")
+	}
+	if numaNodes > 0 {
+		hc.NumaNodes = &numaNodes
+	}
+
+	gpus, gpuType, err := hostGpus()
+	if err != nil {
+		return hc, errors.Annotate(err, "cannot detect GPUs")
+	}
+	hc.Gpus = &gpus
+	if gpuType != "" {
+		hc.GpuType = &gpuType
+	}
+	return hc, nil
+}
+
+// memTotal returns the host's memory in megabytes.
+func memTotal() (uint64, error) {
+	f, err := os.Open(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "MemTotal:       16318472 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		memkB, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		return memkB / 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return 0, errors.New("MemTotal not found in meminfo")
+}
+
+// cpuCores returns the number of physical cpu cores on the host, not
+// counting the additional logical cores due to hyperthreading.
+func cpuCores() (uint64, error) {
+	f, err := os.Open(filepath.Join(procDir, "cpuinfo"))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer f.Close()
+	recorded := make(map[string]bool)
+	var physicalId string
+	var cores uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(line, "physical id") {
+			physicalId = value
+		} else if strings.HasPrefix(line, "cpu cores") {
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if !recorded[physicalId] {
+				cores += n
+				recorded[physicalId] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Trace(err)
+	}
+	if cores == 0 {
+		// In the case of a single-core, non-HT CPU, we'll see no
+		// "physical id" or "cpu cores" lines.
+		cores = 1
+	}
+	return cores, nil
+}
+
+// gpuTypes maps the PCI vendor ids of GPUs to the names used by the
+// gpu-type constraint.
+var gpuTypes = map[string]string{
+	"0x10de": "nvidia",
+	"0x1002": "amd",
+	"0x8086": "intel",
+}
+
+// hostGpus returns the number of GPUs on the host and, if they are all
+// of the same known type, their type.
+func hostGpus() (uint64, string, error) {
+	// Connectors (such as card0-HDMI-A-1) are listed alongside the
+	// cards themselves.
+	var cards []string
+	for _, pattern := range []string{"card[0-9]", "card[0-9][0-9]"} {
+		matches, err := filepath.Glob(filepath.Join(sysDir, "class", "drm", pattern))
+		if err != nil {
+			return 0, "", errors.Trace(err)
+		}
+		cards = append(cards, matches...)
+	}
+	types := make(map[string]bool)
+	for _, card := range cards {
+		vendor, err := ioutil.ReadFile(filepath.Join(card, "device", "vendor"))
+		if os.IsNotExist(err) {
+			types[""] = true
+			continue
+		} else if err != nil {
+			return 0, "", errors.Trace(err)
+		}
+		types[gpuTypes[strings.TrimSpace(string(vendor))]] = true
+	}
+	var gpuType string
+	if len(types) == 1 {
+		for t := range types {
+			gpuType = t
+		}
+	}
+	return uint64(len(cards)), gpuType, nil
+}
+
+func countEntries(pattern string) (uint64, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return uint64(len(matches)), nil
+}
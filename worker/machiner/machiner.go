@@ -4,7 +4,10 @@ package machiner
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
+	"path/filepath"
+	"strings"
 
 	"github.com/juju/loggo"
 	"github.com/juju/names"
@@ -50,6 +53,12 @@ func (mr *Machiner) SetUp() (watcher.NotifyWatcher, error) {
 		return nil, err
 	}
 
+	// Record the host's ssh host keys, so clients connecting to the
+	// machine can verify its identity.
+	if err := setSSHHostKeys(m); err != nil {
+		logger.Warningf("cannot record ssh host keys for %q: %v", mr.tag, err)
+	}
+
 	// Mark the machine as started and log it.
 	if err := m.SetStatus(params.StatusStarted, "", nil); err != nil {
 		return nil, fmt.Errorf("%s failed to set status started: %v", mr.tag, err)
@@ -95,6 +104,34 @@ func setMachineAddresses(m *machiner.Machine) error {
 	return m.SetMachineAddresses(hostAddresses)
 }
 
+// sshHostKeysGlob matches the files holding the host's public ssh
+// host keys.
+var sshHostKeysGlob = "/etc/ssh/ssh_host_*_key.pub"
+
+// setSSHHostKeys records the host's public ssh host keys for this
+// machine.
+func setSSHHostKeys(m *machiner.Machine) error {
+	paths, err := filepath.Glob(sshHostKeysGlob)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if key := strings.TrimSpace(string(data)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	logger.Infof("setting ssh host keys for %v", m.Tag())
+	return m.SetSSHHostKeys(keys)
+}
+
 func (mr *Machiner) Handle() error {
 	if err := mr.machine.Refresh(); params.IsCodeNotFoundOrCodeUnauthorized(err) {
 		return worker.ErrTerminateAgent
@@ -102,7 +139,7 @@ func (mr *Machiner) Handle() error {
 		return err
 	}
 	if mr.machine.Life() == params.Alive {
-		return nil
+		return mr.refreshHardware()
 	}
 	logger.Debugf("%q is now %s", mr.tag, mr.machine.Life())
 	if err := mr.machine.SetStatus(params.StatusStopped, "", nil); err != nil {
@@ -117,6 +154,29 @@ func (mr *Machiner) Handle() error {
 	return worker.ErrTerminateAgent
 }
 
+// refreshHardware re-detects and records the host's hardware
+// characteristics, if a refresh has been requested.
+func (mr *Machiner) refreshHardware() error {
+	requested, err := mr.machine.HardwareRefreshRequested()
+	if err != nil {
+		return fmt.Errorf("%s failed to check for hardware refresh: %v", mr.tag, err)
+	}
+	if !requested {
+		return nil
+	}
+	hc, err := detectHardware()
+	if err != nil {
+		// Record whatever could be detected, which also
+		// acknowledges the request.
+		logger.Warningf("cannot detect all hardware characteristics of %q: %v", mr.tag, err)
+	}
+	logger.Infof("setting hardware characteristics for %q to %v", mr.tag, hc)
+	if err := mr.machine.SetHardwareCharacteristics(hc); err != nil {
+		return fmt.Errorf("%s failed to set hardware characteristics: %v", mr.tag, err)
+	}
+	return nil
+}
+
 func (mr *Machiner) TearDown() error {
 	// Nothing to do here.
 	return nil
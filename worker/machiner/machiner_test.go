@@ -6,6 +6,7 @@ package machiner_test
 import (
 	"io/ioutil"
 	"net"
+	"os"
 	"path/filepath"
 	stdtesting "testing"
 	"time"
@@ -18,10 +19,13 @@ import (
 	"github.com/juju/juju/api"
 	apimachiner "github.com/juju/juju/api/machiner"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/arch"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
+	sshtesting "github.com/juju/juju/utils/ssh/testing"
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/machiner"
 )
@@ -68,7 +72,7 @@ func (s *MachinerSuite) SetUpTest(c *gc.C) {
 		return nil, nil
 	})
 	s.PatchValue(&network.LXCNetDefaultConfig, "")
-
+	s.PatchValue(machiner.SSHHostKeysGlob, filepath.Join(c.MkDir(), "*.pub"))
 }
 
 func (s *MachinerSuite) waitMachineStatus(c *gc.C, m *state.Machine, expectStatus state.Status) {
@@ -196,3 +200,114 @@ LXC_BRIDGE="ignored"`[1:])
 		network.NewAddress("127.0.0.1", network.ScopeMachineLocal),
 	})
 }
+
+func (s *MachinerSuite) TestSSHHostKeys(c *gc.C) {
+	dir := c.MkDir()
+	rsaKey := sshtesting.ValidKeyOne.Key + " root@host"
+	dsaKey := sshtesting.ValidKeyTwo.Key + " root@host"
+	err := ioutil.WriteFile(filepath.Join(dir, "ssh_host_rsa_key.pub"), []byte(rsaKey+"\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "ssh_host_dsa_key.pub"), []byte(dsaKey+"\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(machiner.SSHHostKeysGlob, filepath.Join(dir, "ssh_host_*_key.pub"))
+
+	mr := s.makeMachiner()
+	defer worker.Stop(mr)
+	s.waitMachineStatus(c, s.machine, state.StatusStarted)
+	c.Assert(s.machine.Refresh(), gc.IsNil)
+	c.Assert(s.machine.SSHHostKeys(), jc.DeepEquals, []string{dsaKey, rsaKey})
+}
+
+func (s *MachinerSuite) TestRefreshHardware(c *gc.C) {
+	hc := instance.MustParseHardware("mem=8G cpu-cores=4 numa-nodes=2 gpus=1")
+	s.PatchValue(machiner.DetectHardware, func() (instance.HardwareCharacteristics, error) {
+		return hc, nil
+	})
+	mr := s.makeMachiner()
+	defer worker.Stop(mr)
+	s.waitMachineStatus(c, s.machine, state.StatusStarted)
+
+	err := s.machine.RequestHardwareRefresh()
+	c.Assert(err, jc.ErrorIsNil)
+	s.State.StartSync()
+	timeout := time.After(worstCase)
+	for {
+		select {
+		case <-timeout:
+			c.Fatalf("timeout while waiting for hardware refresh")
+		case <-time.After(10 * time.Millisecond):
+			err := s.machine.Refresh()
+			c.Assert(err, jc.ErrorIsNil)
+			if s.machine.HardwareRefreshRequested() {
+				continue
+			}
+			result, err := s.machine.HardwareCharacteristics()
+			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(*result.Mem, gc.Equals, uint64(8192))
+			c.Assert(*result.CpuCores, gc.Equals, uint64(4))
+			c.Assert(*result.NumaNodes, gc.Equals, uint64(2))
+			c.Assert(*result.Gpus, gc.Equals, uint64(1))
+			return
+		}
+	}
+}
+
+type HardwareSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&HardwareSuite{})
+
+const cpuinfo = `processor	: 0
+physical id	: 0
+cpu cores	: 2
+
+processor	: 1
+physical id	: 0
+cpu cores	: 2
+
+processor	: 2
+physical id	: 1
+cpu cores	: 2
+`
+
+func (s *HardwareSuite) TestDetectHardware(c *gc.C) {
+	procDir := c.MkDir()
+	sysDir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(procDir, "meminfo"), []byte("MemTotal:        2097152 kB\nMemFree:  1024 kB\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(procDir, "cpuinfo"), []byte(cpuinfo), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, dir := range []string{
+		"devices/system/node/node0",
+		"devices/system/node/node1",
+		"class/drm/card0/device",
+		"class/drm/card0-HDMI-A-1",
+		"class/drm/card1/device",
+	} {
+		err := os.MkdirAll(filepath.Join(sysDir, dir), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	for _, card := range []string{"card0", "card1"} {
+		err := ioutil.WriteFile(filepath.Join(sysDir, "class/drm", card, "device/vendor"), []byte("0x10de\n"), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.PatchValue(machiner.ProcDir, procDir)
+	s.PatchValue(machiner.SysDir, sysDir)
+	s.PatchValue(&arch.HostArch, func() string { return arch.AMD64 })
+
+	hc, err := (*machiner.DetectHardware)()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*hc.Arch, gc.Equals, arch.AMD64)
+	c.Assert(*hc.Mem, gc.Equals, uint64(2048))
+	c.Assert(*hc.CpuCores, gc.Equals, uint64(4))
+	c.Assert(*hc.NumaNodes, gc.Equals, uint64(2))
+	c.Assert(*hc.Gpus, gc.Equals, uint64(2))
+	c.Assert(*hc.GpuType, gc.Equals, "nvidia")
+}
+
+func (s *HardwareSuite) TestDetectHardwareMissingMeminfo(c *gc.C) {
+	s.PatchValue(machiner.ProcDir, c.MkDir())
+	_, err := (*machiner.DetectHardware)()
+	c.Assert(err, gc.ErrorMatches, "cannot detect memory: .*")
+}
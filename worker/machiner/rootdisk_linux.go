@@ -0,0 +1,21 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package machiner
+
+import (
+	"syscall"
+
+	"github.com/juju/errors"
+)
+
+// rootDiskSize returns the size of the host's root filesystem in
+// megabytes.
+func rootDiskSize() (*uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs("/", &stat); err != nil {
+		return nil, errors.Trace(err)
+	}
+	size := stat.Blocks * uint64(stat.Bsize) / (1024 * 1024)
+	return &size, nil
+}
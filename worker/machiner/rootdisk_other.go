@@ -0,0 +1,12 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// +build !linux
+
+package machiner
+
+// rootDiskSize returns nil, leaving the root disk size reported by the
+// provider unchanged.
+func rootDiskSize() (*uint64, error) {
+	return nil, nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manualprovisioner
+
+var Period = &period
+
+type Patcher interface {
+	PatchValue(dest, value interface{})
+}
+
+type RemoteHost interface {
+	remoteHost
+}
+
+// PatchNewRemoteHost arranges for the worker to connect to the hosts
+// returned by newHost.
+func PatchNewRemoteHost(patcher Patcher, newHost func(hostname, identityFile string) RemoteHost) {
+	patcher.PatchValue(&newRemoteHost, func(hostname, identityFile string) remoteHost {
+		return newHost(hostname, identityFile)
+	})
+}
@@ -0,0 +1,245 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package manualprovisioner implements a worker that provisions
+// existing hosts over SSH, as requested with "juju add-machine ssh:host".
+package manualprovisioner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/utils/ssh"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.manualprovisioner")
+
+// period is the interval at which pending requests are picked up.
+var period = 5 * time.Second
+
+// maxAttempts is the number of times provisioning a host is attempted
+// before the request is marked as failed.
+const maxAttempts = 3
+
+// ScriptFunc returns the script which installs the agent of the given
+// machine on a host.
+type ScriptFunc func(machineId, nonce string, disablePackageCommands bool) (string, error)
+
+// remoteHost is the interface of manual.RemoteHost used by the worker.
+type remoteHost interface {
+	InstanceId() instance.Id
+	CheckProvisioned() (bool, error)
+	DetectSeriesAndHardwareCharacteristics() (instance.HardwareCharacteristics, string, error)
+	DetectInitSystem() (string, error)
+	RunScript(script string, progressWriter io.Writer) error
+}
+
+var newRemoteHost = func(hostname, identityFile string) remoteHost {
+	var options ssh.Options
+	options.SetIdentities(identityFile)
+	return manual.RemoteHost{Hostname: hostname, Options: &options}
+}
+
+// errNotRetryable wraps errors which will recur however many times
+// provisioning is attempted.
+type errNotRetryable struct {
+	error
+}
+
+// Provisioner provisions manual hosts.
+type Provisioner struct {
+	st           *state.State
+	identityFile string
+	script       ScriptFunc
+}
+
+// NewProvisioner returns a worker that provisions each pending manual
+// host in turn. It logs in to the host as the ubuntu user with the
+// given identity file; inspects the host and records a machine for
+// it; and runs the script returned by script to install the machine
+// agent. Failed attempts are retried a limited number of times.
+func NewProvisioner(st *state.State, identityFile string, script ScriptFunc) worker.Worker {
+	p := &Provisioner{
+		st:           st,
+		identityFile: identityFile,
+		script:       script,
+	}
+	return worker.NewPeriodicWorker(p.provisionAll, period)
+}
+
+func (p *Provisioner) provisionAll(stop <-chan struct{}) error {
+	hosts, err := p.st.PendingManualHosts()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, host := range hosts {
+		select {
+		case <-stop:
+			return worker.ErrKilled
+		default:
+		}
+		if err := p.provisionHost(host); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// provisionHost makes an attempt to provision the given host. Errors
+// from the attempt itself are recorded against the host; only errors
+// recording progress are returned.
+func (p *Provisioner) provisionHost(host *state.ManualHost) error {
+	remote := newRemoteHost(host.Host(), p.identityFile)
+	err := p.attempt(host, remote)
+	if err == nil {
+		return nil
+	}
+	logger.Errorf("cannot provision %q: %v", host.Host(), err)
+	retry := host.Attempts()+1 < maxAttempts
+	if cause, ok := errors.Cause(err).(errNotRetryable); ok {
+		err = cause.error
+		retry = false
+	}
+	if !retry {
+		if err := p.removeMachine(host); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return host.AttemptFailed(err, retry)
+}
+
+func (p *Provisioner) attempt(host *state.ManualHost, remote remoteHost) error {
+	machineId, nonce := host.Machine()
+	if machineId == "" {
+		if err := host.SetProgress(state.ManualHostProvisioning, "inspecting host"); err != nil {
+			return errors.Trace(err)
+		}
+		template, err := p.inspect(host, remote)
+		if err != nil {
+			return err
+		}
+		machine, err := p.st.AddOneMachine(template)
+		if err != nil {
+			return errors.Annotate(err, "cannot record machine")
+		}
+		machineId, nonce = machine.Id(), template.Nonce
+		if err := host.SetMachine(machineId, nonce); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	message := fmt.Sprintf("installing agent for machine %s", machineId)
+	if err := host.SetProgress(state.ManualHostProvisioning, message); err != nil {
+		return errors.Trace(err)
+	}
+	script, err := p.script(machineId, nonce, host.DisablePackageCommands())
+	if err != nil {
+		return errors.Annotate(err, "cannot generate provisioning script")
+	}
+	if err := remote.RunScript(script, ioutil.Discard); err != nil {
+		return errors.Annotate(err, "cannot install agent")
+	}
+	message = fmt.Sprintf("machine %s provisioned", machineId)
+	if err := host.SetProgress(state.ManualHostProvisioned, message); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("provisioned %q as machine %s", host.Host(), machineId)
+	return nil
+}
+
+// inspect connects to the host and returns the template of the machine
+// to record for it.
+func (p *Provisioner) inspect(host *state.ManualHost, remote remoteHost) (state.MachineTemplate, error) {
+	var template state.MachineTemplate
+	provisioned, err := remote.CheckProvisioned()
+	if err != nil {
+		return template, errors.Annotate(err, "error checking if provisioned")
+	}
+	if provisioned {
+		return template, errNotRetryable{manual.ErrProvisioned}
+	}
+	hc, series, err := remote.DetectSeriesAndHardwareCharacteristics()
+	if err != nil {
+		return template, errors.Annotate(err, "error detecting hardware characteristics")
+	}
+	initSystem, err := remote.DetectInitSystem()
+	if err != nil {
+		return template, errors.Annotate(err, "error detecting init system")
+	}
+	if err := checkInitSystem(series, initSystem); err != nil {
+		return template, errNotRetryable{err}
+	}
+
+	// Generate a unique nonce for the machine.
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return template, errors.Trace(err)
+	}
+	var addrs []network.Address
+	if addr, err := manual.HostAddress(host.Host()); err != nil {
+		logger.Warningf("failed to compute public address for %q: %v", host.Host(), err)
+	} else {
+		addrs = append(addrs, addr)
+	}
+
+	// There will never be a corresponding "instance" that any provider
+	// knows about; the provisioner task ignores instance ids it does
+	// not recognise. Manually provisioned machines don't have
+	// JobManageNetworking, so the networker runs in non-intrusive mode
+	// and never touches the network configuration files.
+	instanceId := remote.InstanceId()
+	return state.MachineTemplate{
+		Series:                  series,
+		HardwareCharacteristics: hc,
+		InstanceId:              instanceId,
+		Nonce:                   fmt.Sprintf("%s:%s", instanceId, uuid.String()),
+		Addresses:               addrs,
+		Jobs:                    []state.MachineJob{state.JobHostUnits},
+	}, nil
+}
+
+// checkInitSystem returns an error if the init system running on a host
+// is not the one the machine agent will be installed for.
+func checkInitSystem(series, initSystem string) error {
+	os, err := version.GetOSFromSeries(series)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	expected, ok := service.VersionInitSystem(version.Binary{Series: series, OS: os})
+	if !ok {
+		return errors.NotSupportedf("series %q", series)
+	}
+	if initSystem != expected {
+		return errors.Errorf("host runs %s, but %s is expected on %s", initSystem, expected, series)
+	}
+	return nil
+}
+
+// removeMachine removes the machine recorded for a host which will not
+// be provisioned.
+func (p *Provisioner) removeMachine(host *state.ManualHost) error {
+	machineId, _ := host.Machine()
+	if machineId == "" {
+		return nil
+	}
+	machine, err := p.st.Machine(machineId)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("removing machine %s recorded for %q", machineId, host.Host())
+	return machine.ForceDestroy()
+}
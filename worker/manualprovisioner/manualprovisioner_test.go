@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package manualprovisioner_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/manualprovisioner"
+)
+
+type manualProvisionerSuite struct {
+	testing.JujuConnSuite
+	remote *fakeRemoteHost
+}
+
+var _ = gc.Suite(&manualProvisionerSuite{})
+
+func (s *manualProvisionerSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(manualprovisioner.Period, 10*time.Millisecond)
+	s.remote = &fakeRemoteHost{
+		series:     "quantal",
+		initSystem: "upstart",
+	}
+	manualprovisioner.PatchNewRemoteHost(s, func(hostname, identityFile string) manualprovisioner.RemoteHost {
+		c.Check(identityFile, gc.Equals, "/path/to/identity")
+		s.remote.hostname = hostname
+		return s.remote
+	})
+}
+
+func (s *manualProvisionerSuite) startProvisioner(c *gc.C) worker.Worker {
+	script := func(machineId, nonce string, disablePackageCommands bool) (string, error) {
+		return fmt.Sprintf("install machine %s (%v)", machineId, disablePackageCommands), nil
+	}
+	return manualprovisioner.NewProvisioner(s.State, "/path/to/identity", script)
+}
+
+func (s *manualProvisionerSuite) waitForStatus(c *gc.C, id string, status state.ManualHostStatus) *state.ManualHost {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		host, err := s.State.ManualHost(id)
+		c.Assert(err, jc.ErrorIsNil)
+		if current, _ := host.Status(); current == status {
+			return host
+		}
+	}
+	c.Fatalf("manual host %s never became %s", id, status)
+	return nil
+}
+
+func (s *manualProvisionerSuite) TestProvision(c *gc.C) {
+	host, err := s.State.AddManualHost("example.com", true)
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.startProvisioner(c)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	host = s.waitForStatus(c, host.Id(), state.ManualHostProvisioned)
+	machineId, nonce := host.Machine()
+	_, message := host.Status()
+	c.Assert(message, gc.Equals, fmt.Sprintf("machine %s provisioned", machineId))
+	c.Assert(s.remote.scripts(), jc.DeepEquals, []string{
+		fmt.Sprintf("install machine %s (true)", machineId),
+	})
+
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Series(), gc.Equals, "quantal")
+	c.Assert(machine.CheckProvisioned(nonce), jc.IsTrue)
+	instanceId, err := machine.InstanceId()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instanceId, gc.Equals, instance.Id("manual:example.com"))
+	hc, err := machine.HardwareCharacteristics()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*hc.Arch, gc.Equals, "amd64")
+	c.Assert(machine.Jobs(), jc.DeepEquals, []state.MachineJob{state.JobHostUnits})
+}
+
+func (s *manualProvisionerSuite) TestAlreadyProvisioned(c *gc.C) {
+	s.remote.provisioned = true
+	host, err := s.State.AddManualHost("example.com", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.startProvisioner(c)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	host = s.waitForStatus(c, host.Id(), state.ManualHostFailed)
+	_, message := host.Status()
+	c.Assert(message, gc.Equals, "machine is already provisioned")
+	c.Assert(host.Attempts(), gc.Equals, 1)
+	machineId, _ := host.Machine()
+	c.Assert(machineId, gc.Equals, "")
+}
+
+func (s *manualProvisionerSuite) TestInitSystemMismatch(c *gc.C) {
+	s.remote.initSystem = "systemd"
+	host, err := s.State.AddManualHost("example.com", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.startProvisioner(c)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	host = s.waitForStatus(c, host.Id(), state.ManualHostFailed)
+	_, message := host.Status()
+	c.Assert(message, gc.Equals, "host runs systemd, but upstart is expected on quantal")
+}
+
+func (s *manualProvisionerSuite) TestRetryAndGiveUp(c *gc.C) {
+	s.remote.runErr = errors.New("connection reset")
+	host, err := s.State.AddManualHost("example.com", false)
+	c.Assert(err, jc.ErrorIsNil)
+
+	w := s.startProvisioner(c)
+	defer func() { c.Assert(worker.Stop(w), jc.ErrorIsNil) }()
+
+	host = s.waitForStatus(c, host.Id(), state.ManualHostFailed)
+	_, message := host.Status()
+	c.Assert(message, gc.Equals, "cannot install agent: connection reset")
+	c.Assert(host.Attempts(), gc.Equals, 3)
+
+	// The machine is recorded once, reused for each attempt, and
+	// removed when the provisioner gives up.
+	machineId, _ := host.Machine()
+	scripts := s.remote.scripts()
+	c.Assert(scripts, gc.HasLen, 3)
+	for _, script := range scripts {
+		c.Assert(script, gc.Equals, fmt.Sprintf("install machine %s (false)", machineId))
+	}
+	machine, err := s.State.Machine(machineId)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(machine.Life(), gc.Not(gc.Equals), state.Alive)
+}
+
+type fakeRemoteHost struct {
+	hostname    string
+	series      string
+	initSystem  string
+	provisioned bool
+	runErr      error
+
+	mu  sync.Mutex
+	ran []string
+}
+
+func (h *fakeRemoteHost) InstanceId() instance.Id {
+	return instance.Id("manual:" + h.hostname)
+}
+
+func (h *fakeRemoteHost) CheckProvisioned() (bool, error) {
+	return h.provisioned, nil
+}
+
+func (h *fakeRemoteHost) DetectSeriesAndHardwareCharacteristics() (instance.HardwareCharacteristics, string, error) {
+	arch := "amd64"
+	return instance.HardwareCharacteristics{Arch: &arch}, h.series, nil
+}
+
+func (h *fakeRemoteHost) DetectInitSystem() (string, error) {
+	return h.initSystem, nil
+}
+
+func (h *fakeRemoteHost) RunScript(script string, progressWriter io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ran = append(h.ran, script)
+	return h.runErr
+}
+
+func (h *fakeRemoteHost) scripts() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.ran...)
+}
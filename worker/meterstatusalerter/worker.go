@@ -0,0 +1,185 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package meterstatusalerter provides a worker which posts alerts to
+// a webhook when the meter status of a unit turns RED.
+package meterstatusalerter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.meterstatusalerter")
+
+// retryDelay is how long the worker waits before posting alerts
+// again after failing to post them.
+var retryDelay = time.Minute
+
+// meterRed is the meter status code for which alerts are sent.
+const meterRed = "RED"
+
+// MeterStatusAPI reports the meter statuses of the environment's
+// units, and notifies of changes to them.
+type MeterStatusAPI interface {
+	WatchEnvironMeterStatuses() (apiwatcher.NotifyWatcher, error)
+	EnvironMeterStatuses() ([]params.UnitMeterStatus, error)
+	AlertConfig() (params.MeterStatusAlertConfig, error)
+}
+
+// Alert is the document posted, as JSON, to the webhook when the
+// meter status of a unit turns RED.
+type Alert struct {
+	EnvironUUID string    `json:"environment-uuid"`
+	Unit        string    `json:"unit"`
+	Code        string    `json:"code"`
+	Info        string    `json:"info,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// postAlert posts the given alert to the webhook at url.
+var postAlert = func(url string, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// New returns a worker which posts an alert to the webhook set by the
+// environment's meter-status-alert-url setting whenever the meter
+// status of one of its units turns RED. Units which are already RED
+// when the worker starts are alerted too, so the webhook may see an
+// alert more than once when the worker restarts.
+func New(api MeterStatusAPI, envUUID string) worker.Worker {
+	a := &alerter{
+		api:     api,
+		envUUID: envUUID,
+		alerted: make(map[string]bool),
+	}
+	go func() {
+		defer a.tomb.Done()
+		a.tomb.Kill(a.loop())
+	}()
+	return a
+}
+
+type alerter struct {
+	tomb    tomb.Tomb
+	api     MeterStatusAPI
+	envUUID string
+
+	// alerted holds the tags of the RED units which have been
+	// alerted. Units are forgotten when they stop being RED, so
+	// they are alerted again if they return to it.
+	alerted map[string]bool
+}
+
+// Kill is part of the worker.Worker interface.
+func (a *alerter) Kill() {
+	a.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (a *alerter) Wait() error {
+	return a.tomb.Wait()
+}
+
+func (a *alerter) loop() error {
+	w, err := a.api.WatchEnvironMeterStatuses()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch meter statuses")
+	}
+	defer watcher.Stop(w, &a.tomb)
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-a.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		case <-retry:
+		}
+		complete, err := a.sendAlerts()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		retry = nil
+		if !complete {
+			retry = time.After(retryDelay)
+		}
+	}
+}
+
+// sendAlerts posts alerts for the units which have turned RED since
+// they were last checked. It reports whether every alert was posted.
+func (a *alerter) sendAlerts() (bool, error) {
+	statuses, err := a.api.EnvironMeterStatuses()
+	if err != nil {
+		return false, errors.Annotate(err, "cannot get meter statuses")
+	}
+	config, err := a.api.AlertConfig()
+	if err != nil {
+		return false, errors.Annotate(err, "cannot get alert config")
+	}
+	complete := true
+	red := make(map[string]bool)
+	for _, status := range statuses {
+		if status.Code != meterRed {
+			continue
+		}
+		red[status.UnitTag] = true
+		if a.alerted[status.UnitTag] || config.URL == "" {
+			continue
+		}
+		tag, err := names.ParseUnitTag(status.UnitTag)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		alert := Alert{
+			EnvironUUID: a.envUUID,
+			Unit:        tag.Id(),
+			Code:        status.Code,
+			Info:        status.Info,
+			Time:        time.Now().UTC(),
+		}
+		if err := postAlert(config.URL, alert); err != nil {
+			logger.Errorf("cannot post meter status alert for %s: %v", tag.Id(), err)
+			complete = false
+			continue
+		}
+		logger.Infof("posted meter status alert for %s", tag.Id())
+		a.alerted[status.UnitTag] = true
+	}
+	for unitTag := range a.alerted {
+		if !red[unitTag] {
+			delete(a.alerted, unitTag)
+		}
+	}
+	return complete, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package meterstatusalerter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/meterstatusalerter"
+)
+
+const alertURL = "https://alerts.example.com/juju"
+
+type workerSuite struct {
+	coretesting.BaseSuite
+	api    *mockAPI
+	alerts chan meterstatusalerter.Alert
+
+	mu      sync.Mutex
+	postErr error
+}
+
+var _ = gc.Suite(&workerSuite{})
+
+func (s *workerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		changes: make(chan struct{}, 1),
+		url:     alertURL,
+	}
+	s.alerts = make(chan meterstatusalerter.Alert, 10)
+	s.postErr = nil
+	s.PatchValue(meterstatusalerter.PostAlert, func(url string, alert meterstatusalerter.Alert) error {
+		c.Check(url, gc.Equals, alertURL)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.postErr != nil {
+			return s.postErr
+		}
+		s.alerts <- alert
+		return nil
+	})
+}
+
+func (s *workerSuite) setPostErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.postErr = err
+}
+
+func (s *workerSuite) startWorker(c *gc.C) worker.Worker {
+	w := meterstatusalerter.New(s.api, coretesting.EnvironmentTag.Id())
+	s.AddCleanup(func(c *gc.C) {
+		w.Kill()
+		c.Check(w.Wait(), jc.ErrorIsNil)
+	})
+	return w
+}
+
+func (s *workerSuite) assertAlert(c *gc.C, unit, info string) {
+	select {
+	case alert := <-s.alerts:
+		c.Assert(alert.EnvironUUID, gc.Equals, coretesting.EnvironmentTag.Id())
+		c.Assert(alert.Unit, gc.Equals, unit)
+		c.Assert(alert.Code, gc.Equals, "RED")
+		c.Assert(alert.Info, gc.Equals, info)
+		c.Assert(alert.Time.IsZero(), jc.IsFalse)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for alert")
+	}
+}
+
+func (s *workerSuite) assertNoAlert(c *gc.C) {
+	select {
+	case alert := <-s.alerts:
+		c.Fatalf("unexpected alert %#v", alert)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *workerSuite) TestAlertsWhenUnitTurnsRed(c *gc.C) {
+	s.api.setStatuses(
+		params.UnitMeterStatus{UnitTag: "unit-mysql-0", Code: "GREEN"},
+		params.UnitMeterStatus{UnitTag: "unit-mysql-1", Code: "AMBER", Info: "payment due"},
+	)
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNoAlert(c)
+
+	s.api.setStatuses(
+		params.UnitMeterStatus{UnitTag: "unit-mysql-0", Code: "GREEN"},
+		params.UnitMeterStatus{UnitTag: "unit-mysql-1", Code: "RED", Info: "payment overdue"},
+	)
+	s.api.changes <- struct{}{}
+	s.assertAlert(c, "mysql/1", "payment overdue")
+
+	// Units which stay RED are not alerted again.
+	s.api.changes <- struct{}{}
+	s.assertNoAlert(c)
+
+	// Units which return to RED are.
+	s.api.setStatuses(params.UnitMeterStatus{UnitTag: "unit-mysql-1", Code: "GREEN"})
+	s.api.changes <- struct{}{}
+	s.assertNoAlert(c)
+	s.api.setStatuses(params.UnitMeterStatus{UnitTag: "unit-mysql-1", Code: "RED", Info: "again"})
+	s.api.changes <- struct{}{}
+	s.assertAlert(c, "mysql/1", "again")
+}
+
+func (s *workerSuite) TestNoAlertsWithoutURL(c *gc.C) {
+	s.api.url = ""
+	s.api.setStatuses(params.UnitMeterStatus{UnitTag: "unit-mysql-0", Code: "RED"})
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNoAlert(c)
+}
+
+func (s *workerSuite) TestRetriesFailedAlerts(c *gc.C) {
+	s.PatchValue(meterstatusalerter.RetryDelay, 100*time.Millisecond)
+	s.setPostErr(errors.New("connection refused"))
+	s.api.setStatuses(params.UnitMeterStatus{UnitTag: "unit-mysql-0", Code: "RED", Info: "payment overdue"})
+	s.startWorker(c)
+	s.api.changes <- struct{}{}
+	s.assertNoAlert(c)
+
+	s.setPostErr(nil)
+	s.assertAlert(c, "mysql/0", "payment overdue")
+	s.assertNoAlert(c)
+}
+
+func (s *workerSuite) TestStatusesError(c *gc.C) {
+	s.api.statusesErr = errors.New("boom")
+	w := meterstatusalerter.New(s.api, coretesting.EnvironmentTag.Id())
+	s.api.changes <- struct{}{}
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot get meter statuses: boom")
+}
+
+func (s *workerSuite) TestWatchError(c *gc.C) {
+	s.api.watchErr = errors.New("boom")
+	w := meterstatusalerter.New(s.api, coretesting.EnvironmentTag.Id())
+	c.Assert(w.Wait(), gc.ErrorMatches, "cannot watch meter statuses: boom")
+}
+
+type postAlertSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&postAlertSuite{})
+
+func (s *postAlertSuite) TestPostAlert(c *gc.C) {
+	var received meterstatusalerter.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, gc.Equals, "POST")
+		c.Check(r.Header.Get("Content-Type"), gc.Equals, "application/json")
+		c.Check(json.NewDecoder(r.Body).Decode(&received), jc.ErrorIsNil)
+	}))
+	defer server.Close()
+
+	alert := meterstatusalerter.Alert{
+		EnvironUUID: coretesting.EnvironmentTag.Id(),
+		Unit:        "mysql/0",
+		Code:        "RED",
+		Info:        "payment overdue",
+		Time:        time.Date(2015, 7, 15, 10, 0, 0, 0, time.UTC),
+	}
+	err := (*meterstatusalerter.PostAlert)(server.URL, alert)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(received, jc.DeepEquals, alert)
+}
+
+func (s *postAlertSuite) TestPostAlertErrorStatus(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := (*meterstatusalerter.PostAlert)(server.URL, meterstatusalerter.Alert{})
+	c.Assert(err, gc.ErrorMatches, "webhook returned 503 Service Unavailable")
+}
+
+type mockAPI struct {
+	mu          sync.Mutex
+	changes     chan struct{}
+	watchErr    error
+	statuses    []params.UnitMeterStatus
+	statusesErr error
+	url         string
+}
+
+func (api *mockAPI) setStatuses(statuses ...params.UnitMeterStatus) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.statuses = statuses
+}
+
+func (api *mockAPI) WatchEnvironMeterStatuses() (apiwatcher.NotifyWatcher, error) {
+	if api.watchErr != nil {
+		return nil, api.watchErr
+	}
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) EnvironMeterStatuses() ([]params.UnitMeterStatus, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.statusesErr != nil {
+		return nil, api.statusesErr
+	}
+	return api.statuses, nil
+}
+
+func (api *mockAPI) AlertConfig() (params.MeterStatusAlertConfig, error) {
+	return params.MeterStatusAlertConfig{URL: api.url}, nil
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
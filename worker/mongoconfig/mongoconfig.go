@@ -0,0 +1,187 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package mongoconfig provides a worker which applies changes to the
+// mongo settings in the environment configuration to the mongo
+// database running on a state server.
+//
+// Applying a change means restarting mongo, so the state servers take
+// turns: each claims the right to restart in state, waits for the
+// replica set to be healthy, hands over the primary role if it holds
+// it, restarts its mongo, and waits for the replica set to recover
+// before releasing the claim.
+package mongoconfig
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.mongoconfig")
+
+var (
+	// claimDuration is how long a claim to restart mongo lasts. It
+	// bounds the time for which a state server which died while
+	// restarting mongo holds up the others.
+	claimDuration = 15 * time.Minute
+
+	// claimRetryDelay is how often a state server waiting for its
+	// turn to restart mongo tries to claim it.
+	claimRetryDelay = 10 * time.Second
+
+	// readyTimeout is how long to wait for the replica set to be
+	// healthy before and after restarting mongo.
+	readyTimeout = 10 * time.Minute
+
+	// stepDownSeconds is how long a primary which steps down before
+	// restarting is prevented from being re-elected.
+	stepDownSeconds = 60
+)
+
+// State provides the parts of state used by the worker.
+type State interface {
+	WatchForEnvironConfigChanges() state.NotifyWatcher
+	EnvironConfig() (*config.Config, error)
+	ClaimMongoRestart(machineId string, duration time.Duration) (bool, error)
+	ReleaseMongoRestart(machineId string) error
+}
+
+// ReplicaSet provides access to the replica set formed by the state
+// servers' mongo databases.
+type ReplicaSet interface {
+	// WaitUntilReady waits until a majority of the members of the
+	// replica set are healthy.
+	WaitUntilReady(timeout time.Duration) error
+
+	// Primary returns the id of the machine hosting the primary
+	// member of the replica set, and the number of members.
+	Primary() (machineId string, members int, err error)
+
+	// StepDownPrimary asks the primary member to step down, and not
+	// to seek re-election for the given number of seconds.
+	StepDownPrimary(seconds int) error
+}
+
+// RestartFunc records the given mongo cache size, in gigabytes, in the
+// agent's configuration, and restarts mongo with it. A size of zero
+// means that mongo's default is used.
+type RestartFunc func(cacheSizeGB int) error
+
+type reconfigurer struct {
+	st          State
+	replicaSet  ReplicaSet
+	machineId   string
+	agentConfig func() agent.Config
+	restart     RestartFunc
+}
+
+// New returns a worker which restarts mongo on the state server with
+// the given machine id when the mongo settings in the environment
+// configuration differ from those in the agent's configuration.
+func New(st State, replicaSet ReplicaSet, machineId string, agentConfig func() agent.Config, restart RestartFunc) worker.Worker {
+	return worker.NewNotifyWorker(&reconfigurer{
+		st:          st,
+		replicaSet:  replicaSet,
+		machineId:   machineId,
+		agentConfig: agentConfig,
+		restart:     restart,
+	})
+}
+
+// SetUp is defined on the NotifyWatchHandler interface.
+func (r *reconfigurer) SetUp() (watcher.NotifyWatcher, error) {
+	return r.st.WatchForEnvironConfigChanges(), nil
+}
+
+// Handle is defined on the NotifyWatchHandler interface.
+func (r *reconfigurer) Handle() error {
+	cfg, err := r.st.EnvironConfig()
+	if err != nil {
+		return errors.Annotate(err, "cannot read environment config")
+	}
+	current, err := currentCacheSize(r.agentConfig())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	wanted := cfg.MongoCacheSize()
+	if wanted == current {
+		return nil
+	}
+	logger.Infof("mongo cache size changed from %dGB to %dGB, waiting to restart mongo", current, wanted)
+	if err := r.claim(); err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if err := r.st.ReleaseMongoRestart(r.machineId); err != nil {
+			logger.Warningf("%v", err)
+		}
+	}()
+	if err := r.replicaSet.WaitUntilReady(readyTimeout); err != nil {
+		return errors.Annotate(err, "replica set not ready for restart")
+	}
+	primary, members, err := r.replicaSet.Primary()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if primary == r.machineId && members > 1 {
+		logger.Infof("stepping down as mongo primary")
+		if err := r.replicaSet.StepDownPrimary(stepDownSeconds); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err := r.restart(wanted); err != nil {
+		return errors.Annotate(err, "cannot restart mongo")
+	}
+	if err := r.replicaSet.WaitUntilReady(readyTimeout); err != nil {
+		return errors.Annotate(err, "replica set not ready after restart")
+	}
+	logger.Infof("restarted mongo with cache size %dGB", wanted)
+	return nil
+}
+
+// claim waits until this machine is allowed to restart mongo.
+func (r *reconfigurer) claim() error {
+	attempts := utils.AttemptStrategy{
+		Delay: claimRetryDelay,
+		Total: claimDuration,
+	}
+	for a := attempts.Start(); a.Next(); {
+		claimed, err := r.st.ClaimMongoRestart(r.machineId, claimDuration)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if claimed {
+			return nil
+		}
+	}
+	return errors.Errorf("timed out waiting for another state server to restart mongo")
+}
+
+// TearDown is defined on the NotifyWatchHandler interface.
+func (r *reconfigurer) TearDown() error {
+	return nil
+}
+
+// currentCacheSize returns the mongo cache size recorded in the
+// agent's configuration.
+func currentCacheSize(agentConfig agent.Config) (int, error) {
+	value := agentConfig.Value(agent.MongoCacheSize)
+	if value == "" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid mongo cache size: %q", value)
+	}
+	return size, nil
+}
@@ -0,0 +1,279 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongoconfig_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/mongoconfig"
+)
+
+type MongoConfigSuite struct {
+	coretesting.BaseSuite
+
+	mu         sync.Mutex
+	calls      []string
+	cacheSize  string
+	claims     []bool
+	primary    string
+	members    int
+	restartErr error
+	restarted  chan int
+	st         *fakeState
+}
+
+var _ = gc.Suite(&MongoConfigSuite{})
+
+func (s *MongoConfigSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(mongoconfig.ClaimRetryDelay, time.Millisecond)
+	s.calls = nil
+	s.cacheSize = ""
+	s.claims = nil
+	s.primary = "1"
+	s.members = 3
+	s.restartErr = nil
+	s.restarted = make(chan int, 1)
+	s.st = &fakeState{
+		suite:   s,
+		changes: make(chan struct{}, 1),
+		config: coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+			"mongo-storage-engine": "wiredTiger",
+			"mongo-cache-size":     2,
+		}),
+	}
+	s.st.changes <- struct{}{}
+}
+
+func (s *MongoConfigSuite) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *MongoConfigSuite) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *MongoConfigSuite) startWorker(c *gc.C) worker.Worker {
+	agentConfig := func() agent.Config {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return fakeAgentConfig{cacheSize: s.cacheSize}
+	}
+	restart := func(cacheSizeGB int) error {
+		s.record(fmt.Sprintf("restart %d", cacheSizeGB))
+		if s.restartErr != nil {
+			return s.restartErr
+		}
+		s.mu.Lock()
+		s.cacheSize = fmt.Sprint(cacheSizeGB)
+		s.mu.Unlock()
+		s.restarted <- cacheSizeGB
+		return nil
+	}
+	return mongoconfig.New(s.st, &fakeReplicaSet{s}, "0", agentConfig, restart)
+}
+
+func (s *MongoConfigSuite) waitRestart(c *gc.C) int {
+	select {
+	case size := <-s.restarted:
+		return size
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for mongo restart")
+	}
+	panic("unreachable")
+}
+
+func (s *MongoConfigSuite) TestRestartsWhenCacheSizeChanges(c *gc.C) {
+	w := s.startWorker(c)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	c.Assert(s.waitRestart(c), gc.Equals, 2)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(s.recorded(), jc.DeepEquals, []string{
+		"claim 0",
+		"wait",
+		"primary",
+		"restart 2",
+		"wait",
+		"release 0",
+	})
+}
+
+func (s *MongoConfigSuite) TestNoRestartWhenCacheSizeUnchanged(c *gc.C) {
+	s.cacheSize = "2"
+	w := s.startWorker(c)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	select {
+	case <-s.restarted:
+		c.Fatalf("unexpected restart")
+	case <-time.After(coretesting.ShortWait):
+	}
+	c.Assert(s.recorded(), gc.HasLen, 0)
+}
+
+func (s *MongoConfigSuite) TestPrimaryStepsDown(c *gc.C) {
+	s.primary = "0"
+	w := s.startWorker(c)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.waitRestart(c)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(s.recorded(), jc.DeepEquals, []string{
+		"claim 0",
+		"wait",
+		"primary",
+		"step down 60",
+		"restart 2",
+		"wait",
+		"release 0",
+	})
+}
+
+func (s *MongoConfigSuite) TestSingleMemberDoesNotStepDown(c *gc.C) {
+	s.primary = "0"
+	s.members = 1
+	w := s.startWorker(c)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.waitRestart(c)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	for _, call := range s.recorded() {
+		c.Check(call, gc.Not(gc.Equals), "step down 60")
+	}
+}
+
+func (s *MongoConfigSuite) TestWaitsForClaim(c *gc.C) {
+	s.claims = []bool{false, false, true}
+	w := s.startWorker(c)
+	defer func() { c.Check(worker.Stop(w), jc.ErrorIsNil) }()
+
+	s.waitRestart(c)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(s.recorded()[:4], jc.DeepEquals, []string{
+		"claim 0",
+		"claim 0",
+		"claim 0",
+		"wait",
+	})
+}
+
+func (s *MongoConfigSuite) TestRestartFailureReleasesClaim(c *gc.C) {
+	s.restartErr = errors.New("boom")
+	w := s.startWorker(c)
+
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "cannot restart mongo: boom")
+	c.Assert(s.recorded(), jc.DeepEquals, []string{
+		"claim 0",
+		"wait",
+		"primary",
+		"restart 2",
+		"release 0",
+	})
+}
+
+type fakeState struct {
+	suite   *MongoConfigSuite
+	changes chan struct{}
+	config  *config.Config
+}
+
+func (st *fakeState) WatchForEnvironConfigChanges() state.NotifyWatcher {
+	return &fakeWatcher{changes: st.changes}
+}
+
+func (st *fakeState) EnvironConfig() (*config.Config, error) {
+	return st.config, nil
+}
+
+func (st *fakeState) ClaimMongoRestart(machineId string, duration time.Duration) (bool, error) {
+	s := st.suite
+	s.record("claim " + machineId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.claims) == 0 {
+		return true, nil
+	}
+	claimed := s.claims[0]
+	s.claims = s.claims[1:]
+	return claimed, nil
+}
+
+func (st *fakeState) ReleaseMongoRestart(machineId string) error {
+	st.suite.record("release " + machineId)
+	return nil
+}
+
+type fakeReplicaSet struct {
+	suite *MongoConfigSuite
+}
+
+func (rs *fakeReplicaSet) WaitUntilReady(timeout time.Duration) error {
+	rs.suite.record("wait")
+	return nil
+}
+
+func (rs *fakeReplicaSet) Primary() (string, int, error) {
+	rs.suite.record("primary")
+	return rs.suite.primary, rs.suite.members, nil
+}
+
+func (rs *fakeReplicaSet) StepDownPrimary(seconds int) error {
+	rs.suite.record(fmt.Sprintf("step down %d", seconds))
+	return nil
+}
+
+type fakeWatcher struct {
+	changes chan struct{}
+}
+
+func (w *fakeWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*fakeWatcher) Stop() error {
+	return nil
+}
+
+func (*fakeWatcher) Kill() {}
+
+func (*fakeWatcher) Wait() error {
+	return nil
+}
+
+func (*fakeWatcher) Err() error {
+	return nil
+}
+
+type fakeAgentConfig struct {
+	agent.Config
+	cacheSize string
+}
+
+func (c fakeAgentConfig) Value(key string) string {
+	if key == agent.MongoCacheSize {
+		return c.cacheSize
+	}
+	return ""
+}
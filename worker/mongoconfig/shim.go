@@ -0,0 +1,56 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package mongoconfig
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+
+	"github.com/juju/juju/replicaset"
+)
+
+// jujuMachineKey is the key for the replica set member tag holding
+// the member's juju machine id, as set by the peergrouper.
+const jujuMachineKey = "juju-machine-id"
+
+type replicaSetShim struct {
+	session *mgo.Session
+}
+
+// NewReplicaSet returns a ReplicaSet which uses the given session.
+func NewReplicaSet(session *mgo.Session) ReplicaSet {
+	return replicaSetShim{session}
+}
+
+func (s replicaSetShim) WaitUntilReady(timeout time.Duration) error {
+	return replicaset.WaitUntilReady(s.session, int(timeout/time.Second))
+}
+
+func (s replicaSetShim) Primary() (string, int, error) {
+	status, err := replicaset.CurrentStatus(s.session)
+	if err != nil {
+		return "", 0, errors.Trace(err)
+	}
+	members, err := replicaset.CurrentMembers(s.session)
+	if err != nil {
+		return "", 0, errors.Trace(err)
+	}
+	for _, memberStatus := range status.Members {
+		if memberStatus.State != replicaset.PrimaryState {
+			continue
+		}
+		for _, member := range members {
+			if member.Id == memberStatus.Id {
+				return member.Tags[jujuMachineKey], len(members), nil
+			}
+		}
+	}
+	return "", len(members), nil
+}
+
+func (s replicaSetShim) StepDownPrimary(seconds int) error {
+	return replicaset.StepDownPrimary(s.session, seconds)
+}
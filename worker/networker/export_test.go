@@ -30,3 +30,14 @@ func IsRunningInLXC(machineId string) bool {
 func (nw *Networker) IsVLANModuleLoaded() bool {
 	return nw.isVLANSupportInstalled
 }
+
+// BackupConfig is a helper for testing the rollback of network config
+// changes. It backs up the given files and the state of the interfaces
+// the given commands change, and returns a function restoring them.
+func BackupConfig(fileNames, commands []string) (func() error, error) {
+	backup, err := backupConfig(fileNames, commands)
+	if err != nil {
+		return nil, err
+	}
+	return backup.restore, nil
+}
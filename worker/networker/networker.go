@@ -20,6 +20,7 @@ import (
 	apinetworker "github.com/juju/juju/api/networker"
 	apiwatcher "github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/worker"
 )
@@ -65,6 +66,11 @@ type Networker struct {
 	// discovered via the API, using the interface name as key.
 	interfaceInfo map[string]network.InterfaceInfo
 
+	// publisher, if not nil, is notified with a
+	// pubsub.NetworkConfigChanged event after any changes to the
+	// network config are applied.
+	publisher pubsub.Publisher
+
 	// interfaces holds all known network interfaces on the machine,
 	// using their name as key.
 	interfaces map[string]net.Interface
@@ -78,12 +84,14 @@ var _ worker.Worker = (*Networker)(nil)
 
 // NewNetworker returns a Worker that handles machine networking
 // configuration. If there is no <configBasePath>/interfaces file, an
-// error is returned.
+// error is returned. If publisher is not nil, it is used to announce
+// changes to the network configuration to other workers.
 func NewNetworker(
 	st apinetworker.State,
 	agentConfig agent.Config,
 	intrusiveMode bool,
 	configBaseDir string,
+	publisher pubsub.Publisher,
 ) (*Networker, error) {
 	tag, ok := agentConfig.Tag().(names.MachineTag)
 	if !ok {
@@ -96,6 +104,7 @@ func NewNetworker(
 		tag:           tag,
 		intrusiveMode: intrusiveMode,
 		configBaseDir: configBaseDir,
+		publisher:     publisher,
 		configFiles:   make(map[string]*configFile),
 		interfaceInfo: make(map[string]network.InterfaceInfo),
 		interfaces:    make(map[string]net.Interface),
@@ -365,18 +374,86 @@ func (nw *Networker) fetchInterfaceInfo() error {
 // all accumulated pending commands, and if all commands succeed,
 // resets the commands slice. If the networker is running in "safe
 // mode" nothing is changed.
+//
+// The changes are applied as a whole: if any of them fails, or the API
+// server cannot be reached after interfaces were brought up or down,
+// the config files are restored and the interfaces brought back to the
+// state they were in, and an error is returned. The worker then exits,
+// so that it starts afresh from the restored config.
 func (nw *Networker) applyAndExecute() error {
 	if !nw.IntrusiveMode() {
 		logger.Warningf("running in non-intrusive mode - no changes made")
 		return nil
 	}
 
+	backup, err := backupConfig(nw.configFileNames(), nw.commands)
+	if err != nil {
+		return err
+	}
+	ranCommands := len(nw.commands) > 0
+	changed, err := nw.apply()
+	if err == nil && ranCommands {
+		err = nw.checkConnectivity()
+	}
+	if err != nil {
+		logger.Errorf("rolling back network config changes: %v", err)
+		if restoreErr := backup.restore(); restoreErr != nil {
+			return fmt.Errorf("%v (rollback failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+	if len(changed) > 0 {
+		nw.publishConfigChanged(changed)
+	}
+	return nil
+}
+
+// configFileNames returns the full paths of all config files changes
+// may be applied to: those loaded, and any others in the config
+// subdir, which are removed.
+func (nw *Networker) configFileNames() []string {
+	var fileNames []string
+	for fileName := range nw.configFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	files, err := ioutil.ReadDir(nw.ConfigSubDir())
+	if err != nil {
+		// The subdir may not exist yet, and any other error is
+		// reported when changes are applied.
+		return fileNames
+	}
+	for _, info := range files {
+		fullPath := filepath.Join(nw.ConfigSubDir(), info.Name())
+		if _, ok := nw.configFiles[fullPath]; !ok && info.Mode().IsRegular() {
+			fileNames = append(fileNames, fullPath)
+		}
+	}
+	return fileNames
+}
+
+// checkConnectivity waits until the API server can be reached, in case
+// bringing interfaces up or down cut the machine off from it.
+func (nw *Networker) checkConnectivity() error {
+	var err error
+	for a := connectivityAttempt.Start(); a.Next(); {
+		if _, err = nw.st.MachineNetworkConfig(nw.tag); err == nil {
+			return nil
+		}
+		logger.Debugf("API server not reachable after network changes: %v", err)
+	}
+	return fmt.Errorf("lost connectivity to the API server after network changes: %v", err)
+}
+
+// apply updates or removes config files as needed, and runs all
+// accumulated pending commands. It returns the names of the interfaces
+// whose config files were changed.
+func (nw *Networker) apply() ([]string, error) {
 	// Create the config subdir, if needed.
 	configSubDir := nw.ConfigSubDir()
 	if _, err := os.Stat(configSubDir); err != nil {
 		if err := os.Mkdir(configSubDir, 0755); err != nil {
 			logger.Errorf("failed to create directory %q: %v", configSubDir, err)
-			return err
+			return nil, err
 		}
 	}
 
@@ -384,7 +461,7 @@ func (nw *Networker) applyAndExecute() error {
 	files, err := ioutil.ReadDir(configSubDir)
 	if err != nil {
 		logger.Errorf("failed to read directory %q: %v", configSubDir, err)
-		return err
+		return nil, err
 	}
 	for _, info := range files {
 		if !info.Mode().IsRegular() {
@@ -395,26 +472,46 @@ func (nw *Networker) applyAndExecute() error {
 		if _, ok := nw.configFiles[fullPath]; !ok {
 			if err := os.Remove(fullPath); err != nil {
 				logger.Errorf("failed to remove non-managed config %q: %v", fullPath, err)
-				return err
+				return nil, err
 			}
 		}
 	}
 
 	// Apply all changes needed for each config file.
 	logger.Debugf("applying changes to config files as needed")
+	var changed []string
 	for _, cfgFile := range nw.configFiles {
+		if cfgFile.NeedsUpdating() || cfgFile.IsPendingRemoval() {
+			changed = append(changed, cfgFile.InterfaceName())
+		}
 		if err := cfgFile.Apply(); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if len(nw.commands) > 0 {
 		logger.Debugf("executing commands %v", nw.commands)
 		if err := ExecuteCommands(nw.commands); err != nil {
-			return err
+			return nil, err
 		}
 		nw.commands = []string{}
 	}
-	return nil
+	return changed, nil
+}
+
+// publishConfigChanged announces that the config for the given
+// interfaces has been written. An empty interface name refers to the
+// main network config file.
+func (nw *Networker) publishConfigChanged(interfaces []string) {
+	if nw.publisher == nil {
+		return
+	}
+	sort.Strings(interfaces)
+	err := nw.publisher.Publish(pubsub.NetworkConfigChanged, pubsub.NetworkConfigChangedEvent{
+		Interfaces: interfaces,
+	})
+	if err != nil {
+		logger.Warningf("cannot publish network config change: %v", err)
+	}
 }
 
 // isRunningInLXC returns whether the worker is running inside a LXC
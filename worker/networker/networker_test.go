@@ -21,6 +21,7 @@ import (
 	apinetworker "github.com/juju/juju/api/networker"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
@@ -39,6 +40,7 @@ type networkerSuite struct {
 	machineInterfaces     []net.Interface
 	vlanModuleLoaded      bool
 	lastCommands          chan []string
+	hub                   *pubsub.Hub
 
 	apiState  *api.State
 	apiFacade apinetworker.State
@@ -128,6 +130,10 @@ func (s *networkerSuite) TestNormalNetworkerCanWriteConfigAndLoadsVLANModule(c *
 	c.Assert(nw.IsPrimaryInterfaceOrLoopback("lo"), jc.IsTrue)
 	c.Assert(nw.IsPrimaryInterfaceOrLoopback("eth0"), jc.IsTrue)
 	s.assertHaveConfig(c, nw, "", "eth0", "eth1", "eth1.42", "eth0.69")
+
+	events := s.hub.RecentEvents()
+	c.Assert(events, gc.Not(gc.HasLen), 0)
+	c.Assert(events[0].Topic, gc.Equals, pubsub.NetworkConfigChanged)
 }
 
 func (s *networkerSuite) TestPrimaryOrLoopbackInterfacesAreSkipped(c *gc.C) {
@@ -422,9 +428,10 @@ func (s *networkerSuite) newCustomNetworker(
 	}
 	s.lastCommands = make(chan []string)
 	s.vlanModuleLoaded = false
+	s.hub = pubsub.NewHub(pubsub.DefaultHistorySize)
 	configDir := c.MkDir()
 
-	nw, err := networker.NewNetworker(facade, agentConfig(machineId), intrusiveMode, configDir)
+	nw, err := networker.NewNetworker(facade, agentConfig(machineId), intrusiveMode, configDir, s.hub)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(nw, gc.NotNil)
 
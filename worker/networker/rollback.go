@@ -0,0 +1,139 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package networker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/utils"
+)
+
+// connectivityAttempt defines how long to wait for the API server to
+// become reachable again after interfaces have been brought up or
+// down, before the changes are rolled back.
+var connectivityAttempt = utils.AttemptStrategy{
+	Total: time.Minute,
+	Delay: 5 * time.Second,
+}
+
+// configBackup holds the contents of the network config files as they
+// were before any changes were applied, so that the changes can be
+// rolled back.
+type configBackup struct {
+	// files holds the contents of each backed up file, using the full
+	// file path as key. A nil value means the file did not exist.
+	files map[string][]byte
+
+	// wasUp records whether each interface brought up or down by the
+	// changes was up beforehand.
+	wasUp map[string]bool
+}
+
+// backupConfig records the current contents of the given files, and
+// whether each interface the given commands bring up or down is up.
+func backupConfig(fileNames []string, commands []string) (*configBackup, error) {
+	backup := &configBackup{
+		files: make(map[string][]byte),
+		wasUp: make(map[string]bool),
+	}
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(fileName)
+		if os.IsNotExist(err) {
+			backup.files[fileName] = nil
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot back up network config %q: %v", fileName, err)
+		}
+		backup.files[fileName] = data
+	}
+	for _, command := range commands {
+		if name, ok := interfaceCommand(command); ok {
+			backup.wasUp[name] = InterfaceIsUp(name)
+		}
+	}
+	return backup, nil
+}
+
+// interfaceCommand returns the name of the interface the given command
+// brings up or down, if it does so.
+func interfaceCommand(command string) (string, bool) {
+	fields := strings.Fields(command)
+	if len(fields) != 2 || (fields[0] != "ifup" && fields[0] != "ifdown") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// restore writes back the backed up config files, removing those that
+// did not exist, and brings the interfaces that were changed back to
+// the state they were in. It carries on after any failure, so as much
+// as possible is restored, and returns the first error encountered.
+func (b *configBackup) restore() error {
+	var firstErr error
+	setErr := func(err error) {
+		logger.Errorf("%v", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	fileNames := make([]string, 0, len(b.files))
+	for fileName := range b.files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		data := b.files[fileName]
+		if data == nil {
+			if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+				setErr(fmt.Errorf("cannot remove network config %q: %v", fileName, err))
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			setErr(fmt.Errorf("cannot restore network config %q: %v", fileName, err))
+			continue
+		}
+		if err := utils.AtomicWriteFile(fileName, data, 0644); err != nil {
+			setErr(fmt.Errorf("cannot restore network config %q: %v", fileName, err))
+			continue
+		}
+		logger.Debugf("restored network config %q", fileName)
+	}
+	if err := ExecuteCommands(b.restoreCommands()); err != nil {
+		setErr(fmt.Errorf("cannot restore network interfaces: %v", err))
+	}
+	return firstErr
+}
+
+// restoreCommands returns the commands that bring each changed
+// interface back up or down, as it was before the changes. Raw
+// interfaces are brought down after, and up before, their virtual
+// dependents (i.e. VLANs).
+func (b *configBackup) restoreCommands() []string {
+	var bringUp, bringDown []string
+	for name, wasUp := range b.wasUp {
+		isUp := InterfaceIsUp(name)
+		if wasUp && !isUp {
+			bringUp = append(bringUp, name)
+		} else if !wasUp && isUp {
+			bringDown = append(bringDown, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(bringDown)))
+	sort.Strings(bringUp)
+	var commands []string
+	for _, name := range bringDown {
+		commands = append(commands, "ifdown "+name)
+	}
+	for _, name := range bringUp {
+		commands = append(commands, "ifup "+name)
+	}
+	return commands
+}
@@ -0,0 +1,120 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package networker_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/set"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/networker"
+)
+
+type rollbackSuite struct {
+	testing.BaseSuite
+
+	upInterfaces set.Strings
+	executed     []string
+}
+
+var _ = gc.Suite(&rollbackSuite{})
+
+func (s *rollbackSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.upInterfaces = set.NewStrings("eth0", "eth1")
+	s.executed = nil
+	s.PatchValue(&networker.InterfaceIsUp, func(name string) bool {
+		return s.upInterfaces.Contains(name)
+	})
+	s.PatchValue(&networker.ExecuteCommands, func(commands []string) error {
+		s.executed = append(s.executed, commands...)
+		return nil
+	})
+}
+
+func (s *rollbackSuite) TestRestore(c *gc.C) {
+	dir := c.MkDir()
+	mainConfig := filepath.Join(dir, "interfaces")
+	changedConfig := filepath.Join(dir, "interfaces.d", "eth1.cfg")
+	newConfig := filepath.Join(dir, "interfaces.d", "eth2.cfg")
+	err := ioutil.WriteFile(mainConfig, []byte("original main\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Mkdir(filepath.Join(dir, "interfaces.d"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(changedConfig, []byte("original eth1\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	restore, err := networker.BackupConfig(
+		[]string{mainConfig, changedConfig, newConfig},
+		[]string{"ifdown eth1", "ifup eth2", "ifup eth2.42", "modprobe 8021q"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Apply some changes.
+	err = ioutil.WriteFile(mainConfig, []byte("new main\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Remove(changedConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(newConfig, []byte("new eth2\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.upInterfaces = set.NewStrings("eth0", "eth2", "eth2.42")
+
+	err = restore()
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadFile(mainConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "original main\n")
+	data, err = ioutil.ReadFile(changedConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "original eth1\n")
+	_, err = os.Stat(newConfig)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+	c.Assert(s.executed, jc.DeepEquals, []string{
+		"ifdown eth2.42", "ifdown eth2", "ifup eth1",
+	})
+}
+
+func (s *rollbackSuite) TestRestoreUnchanged(c *gc.C) {
+	restore, err := networker.BackupConfig(nil, []string{"ifup eth1"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = restore()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.executed, gc.HasLen, 0)
+}
+
+func (s *rollbackSuite) TestRestoreCarriesOnAfterFailure(c *gc.C) {
+	s.PatchValue(&networker.ExecuteCommands, func(commands []string) error {
+		return errors.New("ifup failed")
+	})
+	mainConfig := filepath.Join(c.MkDir(), "interfaces")
+	err := ioutil.WriteFile(mainConfig, []byte("original main\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	restore, err := networker.BackupConfig([]string{mainConfig}, []string{"ifdown eth1"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(mainConfig, []byte("new main\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.upInterfaces.Remove("eth1")
+
+	err = restore()
+	c.Assert(err, gc.ErrorMatches, "cannot restore network interfaces: ifup failed")
+	data, err := ioutil.ReadFile(mainConfig)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "original main\n")
+}
+
+func (s *rollbackSuite) TestBackupError(c *gc.C) {
+	// A file whose parent is not a directory cannot be read.
+	blocker := filepath.Join(c.MkDir(), "blocker")
+	err := ioutil.WriteFile(blocker, nil, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = networker.BackupConfig([]string{filepath.Join(blocker, "eth1.cfg")}, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot back up network config ".*": .*`)
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdater
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// rebootRequiredFile is created by packages whose updates only take
+// effect once the machine reboots, and removed when it does.
+const rebootRequiredFile = "/var/run/reboot-required"
+
+// listSecurityUpdates returns the names of the packages with pending
+// security updates.
+var listSecurityUpdates = func() ([]string, error) {
+	if _, err := runAptGet("update"); err != nil {
+		return nil, errors.Trace(err)
+	}
+	output, err := runAptGet("--simulate", "dist-upgrade")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return parseSecurityUpdates(output), nil
+}
+
+// applyUpdates upgrades the given packages.
+var applyUpdates = func(packages []string) error {
+	if len(packages) == 0 {
+		return nil
+	}
+	args := []string{
+		"--option=Dpkg::Options::=--force-confold",
+		"--assume-yes",
+		"install",
+		"--only-upgrade",
+	}
+	_, err := runAptGet(append(args, packages...)...)
+	return errors.Trace(err)
+}
+
+// rebootRequired returns whether the machine must be rebooted for the
+// updates applied to it to take effect.
+var rebootRequired = func() bool {
+	_, err := os.Stat(rebootRequiredFile)
+	return err == nil
+}
+
+func runAptGet(args ...string) ([]byte, error) {
+	cmd := exec.Command("apt-get", append([]string{"--quiet"}, args...)...)
+	cmd.Env = append(os.Environ(), "DEBIAN_FRONTEND=noninteractive")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.Errorf("apt-get %s: %v (%s)", strings.Join(args, " "), err, bytes.TrimSpace(output))
+	}
+	return output, nil
+}
+
+// parseSecurityUpdates returns the names of the packages that the
+// output of a simulated apt-get upgrade would install from a security
+// pocket, such as
+//
+//	Inst openssl [1.0.1f-1ubuntu2.15] (1.0.1f-1ubuntu2.16 Ubuntu:14.04/trusty-security [amd64])
+func parseSecurityUpdates(output []byte) []string {
+	var packages []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "Inst" {
+			continue
+		}
+		if strings.Contains(scanner.Text(), "-security") {
+			packages = append(packages, fields[1])
+		}
+	}
+	return packages
+}
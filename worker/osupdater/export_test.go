@@ -0,0 +1,12 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdater
+
+var (
+	CheckInterval        = &checkInterval
+	ListSecurityUpdates  = &listSecurityUpdates
+	ApplyUpdates         = &applyUpdates
+	RebootRequired       = &rebootRequired
+	ParseSecurityUpdates = parseSecurityUpdates
+)
@@ -0,0 +1,181 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package osupdater implements a worker that reports the security
+// updates pending on a machine, and applies them when the machine is
+// patched by a patch run, rebooting it through the reboot worker if
+// the updates require it.
+package osupdater
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.osupdater")
+
+// checkInterval is how long the worker waits between checks for
+// pending security updates.
+var checkInterval = 6 * time.Hour
+
+// The statuses reported by the worker for a machine in a patch run.
+const (
+	patchApplying  = "applying"
+	patchRebooting = "rebooting"
+	patchDone      = "done"
+	patchFailed    = "failed"
+)
+
+// OSUpdatesAPI records the security updates pending on the machine,
+// and tells the worker when to apply them.
+type OSUpdatesAPI interface {
+	SetSecurityUpdates(packages []string) error
+	WatchPatchRequest() (apiwatcher.NotifyWatcher, error)
+	PatchRequest() (params.PatchRequest, error)
+	SetPatchStatus(status, info string) error
+}
+
+// Rebooter requests that the machine be rebooted.
+type Rebooter interface {
+	RequestReboot() error
+}
+
+// New returns a worker which reports the security updates pending on
+// the machine periodically, and applies them when asked. If the
+// updates require it, the machine is rebooted by setting its reboot
+// flag, so that the reboot is coordinated with its containers as any
+// other is; the patch is complete once the machine is back.
+func New(api OSUpdatesAPI, rebooter Rebooter) worker.Worker {
+	u := &updater{
+		api:      api,
+		rebooter: rebooter,
+	}
+	go func() {
+		defer u.tomb.Done()
+		u.tomb.Kill(u.loop())
+	}()
+	return u
+}
+
+type updater struct {
+	tomb     tomb.Tomb
+	api      OSUpdatesAPI
+	rebooter Rebooter
+}
+
+// Kill is part of the worker.Worker interface.
+func (u *updater) Kill() {
+	u.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (u *updater) Wait() error {
+	return u.tomb.Wait()
+}
+
+func (u *updater) loop() error {
+	w, err := u.api.WatchPatchRequest()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch patch requests")
+	}
+	defer watcher.Stop(w, &u.tomb)
+
+	// The watcher's initial event is consumed by the API server, so
+	// handle any request made before the worker started, such as
+	// one the machine rebooted for, first.
+	if err := u.handleRequest(); err != nil {
+		return errors.Trace(err)
+	}
+	check := time.After(0)
+	for {
+		select {
+		case <-u.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+			if err := u.handleRequest(); err != nil {
+				return errors.Trace(err)
+			}
+		case <-check:
+			check = time.After(checkInterval)
+			if err := u.report(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// report records the security updates pending on the machine. A
+// failure to list them, for example because the package archive is
+// unreachable, is logged and retried at the next check.
+func (u *updater) report() error {
+	packages, err := listSecurityUpdates()
+	if err != nil {
+		logger.Warningf("cannot list security updates: %v", err)
+		return nil
+	}
+	logger.Debugf("%d security updates pending", len(packages))
+	if err := u.api.SetSecurityUpdates(packages); err != nil {
+		return errors.Annotate(err, "cannot record security updates")
+	}
+	return nil
+}
+
+// handleRequest applies the pending security updates if the machine's
+// patch run has asked for them, or completes the patch if the machine
+// has rebooted since applying them.
+func (u *updater) handleRequest() error {
+	request, err := u.api.PatchRequest()
+	if err != nil {
+		return errors.Annotate(err, "cannot get patch request")
+	}
+	switch request.Status {
+	case patchApplying:
+		return u.apply(request.Run)
+	case patchRebooting:
+		if rebootRequired() {
+			// The agent was restarted before the machine
+			// rebooted; make sure the reboot still happens.
+			return errors.Trace(u.rebooter.RequestReboot())
+		}
+		logger.Infof("machine rebooted for patch run %d", request.Run)
+		if err := u.report(); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(u.api.SetPatchStatus(patchDone, ""))
+	}
+	return nil
+}
+
+func (u *updater) apply(run int) error {
+	packages, err := listSecurityUpdates()
+	if err == nil {
+		logger.Infof("applying %d security updates for patch run %d", len(packages), run)
+		err = applyUpdates(packages)
+	}
+	if err != nil {
+		logger.Errorf("cannot apply security updates for patch run %d: %v", run, err)
+		return errors.Trace(u.api.SetPatchStatus(patchFailed, err.Error()))
+	}
+	if err := u.report(); err != nil {
+		return errors.Trace(err)
+	}
+	if !rebootRequired() {
+		return errors.Trace(u.api.SetPatchStatus(patchDone, ""))
+	}
+	logger.Infof("rebooting to complete security updates for patch run %d", run)
+	if err := u.api.SetPatchStatus(patchRebooting, ""); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(u.rebooter.RequestReboot())
+}
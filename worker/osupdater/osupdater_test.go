@@ -0,0 +1,240 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package osupdater_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/osupdater"
+)
+
+type osUpdaterSuite struct {
+	coretesting.BaseSuite
+	api            *mockAPI
+	rebooter       *mockRebooter
+	pending        []string
+	applied        chan []string
+	applyErr       error
+	rebootRequired bool
+}
+
+var _ = gc.Suite(&osUpdaterSuite{})
+
+func (s *osUpdaterSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		changes:  make(chan struct{}, 1),
+		reported: make(chan []string, 10),
+		statuses: make(chan string, 10),
+	}
+	s.rebooter = &mockRebooter{requested: make(chan struct{}, 10)}
+	s.pending = []string{"openssl"}
+	s.applied = make(chan []string, 10)
+	s.applyErr = nil
+	s.rebootRequired = false
+	s.PatchValue(osupdater.ListSecurityUpdates, func() ([]string, error) {
+		return s.pending, nil
+	})
+	s.PatchValue(osupdater.ApplyUpdates, func(packages []string) error {
+		s.applied <- packages
+		if s.applyErr != nil {
+			return s.applyErr
+		}
+		s.pending = nil
+		return nil
+	})
+	s.PatchValue(osupdater.RebootRequired, func() bool {
+		return s.rebootRequired
+	})
+}
+
+func (s *osUpdaterSuite) start(c *gc.C) worker.Worker {
+	w := osupdater.New(s.api, s.rebooter)
+	s.AddCleanup(func(c *gc.C) { c.Check(worker.Stop(w), jc.ErrorIsNil) })
+	return w
+}
+
+func (s *osUpdaterSuite) assertReported(c *gc.C, expected []string) {
+	select {
+	case packages := <-s.api.reported:
+		c.Assert(packages, jc.DeepEquals, expected)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for updates to be reported")
+	}
+}
+
+func (s *osUpdaterSuite) assertStatus(c *gc.C, expected string) {
+	select {
+	case status := <-s.api.statuses:
+		c.Assert(status, gc.Equals, expected)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for patch status %q", expected)
+	}
+}
+
+func (s *osUpdaterSuite) assertRebootRequested(c *gc.C) {
+	select {
+	case <-s.rebooter.requested:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for reboot request")
+	}
+}
+
+func (s *osUpdaterSuite) TestReportsUpdates(c *gc.C) {
+	s.PatchValue(osupdater.CheckInterval, 10*time.Millisecond)
+	s.start(c)
+	s.assertReported(c, []string{"openssl"})
+	s.assertReported(c, []string{"openssl"})
+}
+
+func (s *osUpdaterSuite) TestAppliesUpdates(c *gc.C) {
+	s.start(c)
+	s.assertReported(c, []string{"openssl"})
+
+	s.api.setRequest(params.PatchRequest{Run: 1, Status: "applying"})
+	s.api.changes <- struct{}{}
+	c.Assert(<-s.applied, jc.DeepEquals, []string{"openssl"})
+	s.assertReported(c, nil)
+	s.assertStatus(c, "done")
+	select {
+	case <-s.rebooter.requested:
+		c.Fatalf("unexpected reboot request")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *osUpdaterSuite) TestApplyFailure(c *gc.C) {
+	s.applyErr = errors.New("dpkg was interrupted")
+	s.api.setRequest(params.PatchRequest{Run: 1, Status: "applying"})
+	s.start(c)
+	c.Assert(<-s.applied, jc.DeepEquals, []string{"openssl"})
+	s.assertStatus(c, "failed")
+	c.Assert(s.api.info(), gc.Equals, "dpkg was interrupted")
+}
+
+func (s *osUpdaterSuite) TestRebootsWhenRequired(c *gc.C) {
+	s.rebootRequired = true
+	s.api.setRequest(params.PatchRequest{Run: 1, Status: "applying"})
+	s.start(c)
+	c.Assert(<-s.applied, jc.DeepEquals, []string{"openssl"})
+	s.assertStatus(c, "rebooting")
+	s.assertRebootRequested(c)
+}
+
+func (s *osUpdaterSuite) TestCompletesAfterReboot(c *gc.C) {
+	s.pending = nil
+	s.api.setRequest(params.PatchRequest{Run: 1, Status: "rebooting"})
+	s.start(c)
+	s.assertReported(c, nil)
+	s.assertStatus(c, "done")
+}
+
+func (s *osUpdaterSuite) TestRequestsRebootAgainIfNotRebooted(c *gc.C) {
+	s.rebootRequired = true
+	s.api.setRequest(params.PatchRequest{Run: 1, Status: "rebooting"})
+	s.start(c)
+	s.assertRebootRequested(c)
+	select {
+	case status := <-s.api.statuses:
+		c.Fatalf("unexpected patch status %q", status)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *osUpdaterSuite) TestParseSecurityUpdates(c *gc.C) {
+	output := `
+Reading package lists...
+Calculating upgrade...
+The following packages will be upgraded:
+  openssl tzdata
+Inst openssl [1.0.1f-1ubuntu2.15] (1.0.1f-1ubuntu2.16 Ubuntu:14.04/trusty-security [amd64])
+Inst tzdata [2015d-0ubuntu0.14.04] (2015e-0ubuntu0.14.04 Ubuntu:14.04/trusty-updates [all])
+Conf openssl (1.0.1f-1ubuntu2.16 Ubuntu:14.04/trusty-security [amd64])
+`[1:]
+	packages := osupdater.ParseSecurityUpdates([]byte(output))
+	c.Assert(packages, jc.DeepEquals, []string{"openssl"})
+}
+
+type mockAPI struct {
+	mu       sync.Mutex
+	request  params.PatchRequest
+	lastInfo string
+	changes  chan struct{}
+	reported chan []string
+	statuses chan string
+}
+
+func (api *mockAPI) setRequest(request params.PatchRequest) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.request = request
+}
+
+func (api *mockAPI) info() string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.lastInfo
+}
+
+func (api *mockAPI) SetSecurityUpdates(packages []string) error {
+	api.reported <- packages
+	return nil
+}
+
+func (api *mockAPI) WatchPatchRequest() (apiwatcher.NotifyWatcher, error) {
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) PatchRequest() (params.PatchRequest, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.request, nil
+}
+
+func (api *mockAPI) SetPatchStatus(status, info string) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.lastInfo = info
+	if status == "done" || status == "failed" {
+		api.request = params.PatchRequest{}
+	} else {
+		api.request.Status = status
+	}
+	api.statuses <- status
+	return nil
+}
+
+type mockRebooter struct {
+	requested chan struct{}
+}
+
+func (r *mockRebooter) RequestReboot() error {
+	r.requested <- struct{}{}
+	return nil
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
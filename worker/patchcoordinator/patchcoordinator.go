@@ -0,0 +1,126 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package patchcoordinator implements a worker that progresses the
+// environment's patch run, asking the agents of a few machines at a
+// time to apply their pending security updates.
+package patchcoordinator
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.patchcoordinator")
+
+// period is the interval at which the patch run is progressed.
+var period = 30 * time.Second
+
+// Coordinator progresses the environment's patch run.
+type Coordinator struct {
+	st *state.State
+}
+
+// NewCoordinator returns a worker that progresses the active patch
+// run: it fails machines that have gone away, starts patching pending
+// machines while no more than the run's maximum are unavailable, and
+// finishes the run once every machine is done with. Machines are only
+// started while the environment's maintenance window is open and the
+// environment is not suspended; machines already started are left to
+// finish.
+func NewCoordinator(st *state.State) worker.Worker {
+	c := &Coordinator{st: st}
+	return worker.NewPeriodicWorker(c.progress, period)
+}
+
+func (c *Coordinator) progress(stop <-chan struct{}) error {
+	run, err := c.st.ActivePatchRun()
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	statuses := run.Machines()
+	var pending []string
+	unavailable := 0
+	for _, id := range run.MachineIds() {
+		status := statuses[id].Status
+		if status == state.PatchDone || status == state.PatchFailed {
+			continue
+		}
+		if gone, err := c.machineGone(id); err != nil {
+			return errors.Trace(err)
+		} else if gone {
+			logger.Infof("machine %s removed during patch run %d", id, run.Id())
+			if err := run.FailMachine(id, "machine removed"); err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
+		if status == state.PatchPending {
+			pending = append(pending, id)
+		} else {
+			unavailable++
+		}
+	}
+	if len(pending) == 0 {
+		if unavailable == 0 {
+			if err := run.Finish(); err != nil {
+				return errors.Trace(err)
+			}
+			logger.Infof("patch run %d %s", run.Id(), run.Status())
+		}
+		return nil
+	}
+	free := run.MaxUnavailable() - unavailable
+	if free <= 0 {
+		return nil
+	}
+	if ok, err := c.canStart(); err != nil || !ok {
+		return errors.Trace(err)
+	}
+	if len(pending) > free {
+		pending = pending[:free]
+	}
+	logger.Infof("patch run %d: patching machines %v", run.Id(), pending)
+	return errors.Trace(run.StartMachines(pending...))
+}
+
+// canStart returns whether machines may be taken out of service to
+// apply updates now.
+func (c *Coordinator) canStart() (bool, error) {
+	env, err := c.st.Environment()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if suspended, reason := env.Suspended(); suspended {
+		logger.Debugf("environment suspended (%s): not patching more machines", reason)
+		return false, nil
+	}
+	window, err := c.st.MaintenanceWindow(time.Now())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !window.Open {
+		logger.Debugf("maintenance window closed until %v: not patching more machines", window.Until)
+		return false, nil
+	}
+	return true, nil
+}
+
+// machineGone returns whether the machine with the given id has been
+// removed, or is on its way out.
+func (c *Coordinator) machineGone(id string) (bool, error) {
+	machine, err := c.st.Machine(id)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	return machine.Life() == state.Dead, nil
+}
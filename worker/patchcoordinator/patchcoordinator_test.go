@@ -0,0 +1,176 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package patchcoordinator_test
+
+import (
+	"reflect"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/patchcoordinator"
+)
+
+type coordinatorSuite struct {
+	testing.JujuConnSuite
+	machines []*state.Machine
+}
+
+var _ = gc.Suite(&coordinatorSuite{})
+
+func (s *coordinatorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.PatchValue(patchcoordinator.Period, 10*time.Millisecond)
+	s.machines = nil
+	for i := 0; i < 3; i++ {
+		machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+		c.Assert(err, jc.ErrorIsNil)
+		err = machine.SetSecurityUpdates([]string{"openssl"})
+		c.Assert(err, jc.ErrorIsNil)
+		s.machines = append(s.machines, machine)
+	}
+}
+
+func (s *coordinatorSuite) startCoordinator(c *gc.C) {
+	w := patchcoordinator.NewCoordinator(s.State)
+	s.AddCleanup(func(c *gc.C) { c.Check(worker.Stop(w), jc.ErrorIsNil) })
+}
+
+// waitForStatuses waits until the machines in the run have the given
+// statuses.
+func (s *coordinatorSuite) waitForStatuses(c *gc.C, run *state.PatchRun, expected map[string]state.PatchStatus) {
+	var actual map[string]state.PatchStatus
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := run.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		actual = make(map[string]state.PatchStatus)
+		for id, status := range run.Machines() {
+			actual[id] = status.Status
+		}
+		if reflect.DeepEqual(actual, expected) {
+			return
+		}
+	}
+	c.Fatalf("machine statuses %v, expected %v", actual, expected)
+}
+
+func (s *coordinatorSuite) assertStatusesStay(c *gc.C, run *state.PatchRun, expected map[string]state.PatchStatus) {
+	time.Sleep(coretesting.ShortWait)
+	s.waitForStatuses(c, run, expected)
+}
+
+func (s *coordinatorSuite) TestRollingPatch(c *gc.C) {
+	run, err := s.State.StartPatchRun(2)
+	c.Assert(err, jc.ErrorIsNil)
+	s.startCoordinator(c)
+	s.waitForStatuses(c, run, map[string]state.PatchStatus{
+		"0": state.PatchApplying,
+		"1": state.PatchApplying,
+		"2": state.PatchPending,
+	})
+	s.assertStatusesStay(c, run, map[string]state.PatchStatus{
+		"0": state.PatchApplying,
+		"1": state.PatchApplying,
+		"2": state.PatchPending,
+	})
+
+	err = s.machines[0].SetPatchStatus(state.PatchRebooting, "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machines[1].SetPatchStatus(state.PatchFailed, "boom")
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForStatuses(c, run, map[string]state.PatchStatus{
+		"0": state.PatchRebooting,
+		"1": state.PatchFailed,
+		"2": state.PatchApplying,
+	})
+
+	err = s.machines[0].SetPatchStatus(state.PatchDone, "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machines[2].SetPatchStatus(state.PatchDone, "")
+	c.Assert(err, jc.ErrorIsNil)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := run.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		if run.Status() != state.PatchRunRunning {
+			break
+		}
+	}
+	c.Assert(run.Status(), gc.Equals, state.PatchRunFailed)
+}
+
+func (s *coordinatorSuite) TestWaitsForMaintenanceWindow(c *gc.C) {
+	err := s.State.SetMaintenanceOverride(state.MaintenanceOverride{
+		Open:  false,
+		Until: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	run, err := s.State.StartPatchRun(1)
+	c.Assert(err, jc.ErrorIsNil)
+	s.startCoordinator(c)
+	s.assertStatusesStay(c, run, map[string]state.PatchStatus{
+		"0": state.PatchPending,
+		"1": state.PatchPending,
+		"2": state.PatchPending,
+	})
+
+	err = s.State.ClearMaintenanceOverride()
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForStatuses(c, run, map[string]state.PatchStatus{
+		"0": state.PatchApplying,
+		"1": state.PatchPending,
+		"2": state.PatchPending,
+	})
+}
+
+func (s *coordinatorSuite) TestWaitsWhileSuspended(c *gc.C) {
+	env, err := s.State.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.Suspend("")
+	c.Assert(err, jc.ErrorIsNil)
+	run, err := s.State.StartPatchRun(1)
+	c.Assert(err, jc.ErrorIsNil)
+	s.startCoordinator(c)
+	s.assertStatusesStay(c, run, map[string]state.PatchStatus{
+		"0": state.PatchPending,
+		"1": state.PatchPending,
+		"2": state.PatchPending,
+	})
+
+	err = env.Resume()
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitForStatuses(c, run, map[string]state.PatchStatus{
+		"0": state.PatchApplying,
+		"1": state.PatchPending,
+		"2": state.PatchPending,
+	})
+}
+
+func (s *coordinatorSuite) TestFailsRemovedMachines(c *gc.C) {
+	run, err := s.State.StartPatchRun(3)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, machine := range s.machines {
+		err := machine.EnsureDead()
+		c.Assert(err, jc.ErrorIsNil)
+		err = machine.Remove()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.startCoordinator(c)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		err := run.Refresh()
+		c.Assert(err, jc.ErrorIsNil)
+		if run.Status() != state.PatchRunRunning {
+			break
+		}
+	}
+	c.Assert(run.Status(), gc.Equals, state.PatchRunFailed)
+	c.Assert(run.Machines()["0"], gc.Equals, state.PatchMachineStatus{
+		Status: state.PatchFailed,
+		Info:   "machine removed",
+	})
+}
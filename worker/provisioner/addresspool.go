@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/network"
+)
+
+// hostsFile is the file in which the host name records of containers
+// allocated addresses from an address pool are kept, so that the host,
+// and any DNS server on the bridge reading the file, resolves them.
+var hostsFile = "/etc/hosts"
+
+// hostsFileMarker marks the records in hostsFile managed by the
+// provisioner.
+const hostsFileMarker = "# juju container address pool"
+
+// containerHostname returns the host name of the container for the
+// given machine, which is also its instance id.
+func containerHostname(namespace, machineId string) string {
+	name := names.NewMachineTag(machineId).String()
+	if namespace != "" {
+		name = fmt.Sprintf("%s-%s", namespace, name)
+	}
+	return name
+}
+
+// allocatePoolAddress allocates a static address for the given
+// container from the controller-managed address pool of the given
+// bridge, and records the container's host name at that address in
+// hostsFile. It's used when the provider cannot allocate container
+// addresses; an error satisfying params.IsCodeNotFound is returned
+// when the bridge has no address pool.
+func allocatePoolAddress(
+	containerId, bridgeDevice, hostname string,
+	apiFacade APICalls,
+) ([]network.InterfaceInfo, error) {
+	ifaceInfo, err := apiFacade.AllocateContainerPoolAddress(
+		names.NewMachineTag(containerId), bridgeDevice, hostname,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(ifaceInfo) == 0 {
+		return nil, errors.Errorf("no address allocated from pool for %s", bridgeDevice)
+	}
+	dnsServers, err := localDNSServers()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i := range ifaceInfo {
+		ifaceInfo[i].InterfaceName = fmt.Sprintf("eth%d", ifaceInfo[i].DeviceIndex)
+		ifaceInfo[i].DNSServers = dnsServers
+	}
+	if err := setHostsRecord(hostsFile, hostname, ifaceInfo[0].Address.Value); err != nil {
+		return nil, errors.Annotate(err, "cannot record container host name")
+	}
+	logger.Infof(
+		"allocated address %q from pool for %s to container %q",
+		ifaceInfo[0].Address.Value, bridgeDevice, containerId,
+	)
+	return ifaceInfo, nil
+}
+
+// setHostsRecord replaces any record of the given host name in the
+// hosts file at path that was added by the provisioner with one for the
+// given address. Passing an empty address just removes the record.
+func setHostsRecord(path, hostname, address string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasSuffix(line, hostsFileMarker) {
+			fields := strings.Fields(line)
+			if len(fields) > 1 && fields[1] == hostname {
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if address != "" {
+		lines = append(lines, fmt.Sprintf("%s %s %s", address, hostname, hostsFileMarker))
+	}
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if content == string(data) {
+		return nil
+	}
+	return utils.AtomicWriteFile(path, []byte(content), 0644)
+}
+
+// removeHostsRecords removes the records of the given containers from
+// hostsFile, logging any failure.
+func removeHostsRecords(hostnames ...string) {
+	for _, hostname := range hostnames {
+		if err := setHostsRecord(hostsFile, hostname, ""); err != nil {
+			logger.Warningf("cannot remove host name record for %q: %v", hostname, err)
+		}
+	}
+}
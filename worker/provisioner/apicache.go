@@ -0,0 +1,65 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"fmt"
+
+	apiprovisioner "github.com/juju/juju/api/provisioner"
+	"github.com/juju/juju/apiserver/params"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/apicache"
+)
+
+// cachedProvisionerAPI answers the provisioner API calls made when
+// setting up and starting containers whose results rarely change from
+// a cache, so that starting many containers in quick succession, or
+// restarting the agent, does not repeat identical calls.
+type cachedProvisionerAPI struct {
+	*apiprovisioner.State
+	cache *apicache.Cache
+}
+
+var (
+	_ APICalls                     = cachedProvisionerAPI{}
+	_ ToolsFinder                  = cachedProvisionerAPI{}
+	_ containerManagerConfigGetter = cachedProvisionerAPI{}
+)
+
+// ContainerConfig is part of the APICalls interface.
+func (api cachedProvisionerAPI) ContainerConfig() (params.ContainerConfig, error) {
+	var result params.ContainerConfig
+	err := api.cache.Get("container-config", &result, func(result interface{}) (err error) {
+		*result.(*params.ContainerConfig), err = api.State.ContainerConfig()
+		return err
+	})
+	return result, err
+}
+
+// ContainerManagerConfig is part of the containerManagerConfigGetter
+// interface.
+func (api cachedProvisionerAPI) ContainerManagerConfig(args params.ContainerManagerConfigParams) (params.ContainerManagerConfig, error) {
+	var result params.ContainerManagerConfig
+	key := fmt.Sprintf("container-manager-config:%s", args.Type)
+	err := api.cache.Get(key, &result, func(result interface{}) (err error) {
+		*result.(*params.ContainerManagerConfig), err = api.State.ContainerManagerConfig(args)
+		return err
+	})
+	return result, err
+}
+
+// FindTools is part of the ToolsFinder interface.
+func (api cachedProvisionerAPI) FindTools(v version.Number, series string, arch *string) (coretools.List, error) {
+	var result coretools.List
+	key := fmt.Sprintf("tools:%s:%s", v, series)
+	if arch != nil {
+		key += ":" + *arch
+	}
+	err := api.cache.Get(key, &result, func(result interface{}) (err error) {
+		*result.(*coretools.List), err = api.State.FindTools(v, series, arch)
+		return err
+	})
+	return result, err
+}
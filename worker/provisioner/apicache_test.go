@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"path/filepath"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/worker/apicache"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type cachedAPISuite struct {
+	CommonProvisionerSuite
+	cachePath string
+}
+
+var _ = gc.Suite(&cachedAPISuite{})
+
+func (s *cachedAPISuite) SetUpTest(c *gc.C) {
+	s.CommonProvisionerSuite.SetUpTest(c)
+	s.cachePath = filepath.Join(c.MkDir(), "api-cache.json")
+}
+
+// assertCached checks that a result is cached under the given key, by
+// reading it from a newly opened cache.
+func (s *cachedAPISuite) assertCached(c *gc.C, key string, result, expect interface{}) {
+	cache := apicache.Open(s.cachePath, time.Hour)
+	err := cache.Get(key, result, func(interface{}) error {
+		c.Fatalf("%q not cached", key)
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, expect)
+}
+
+func (s *cachedAPISuite) TestContainerConfig(c *gc.C) {
+	api := provisioner.NewCachedProvisionerAPI(s.provisioner, apicache.Open(s.cachePath, time.Hour))
+	config, err := api.ContainerConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	expect, err := s.provisioner.ContainerConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, jc.DeepEquals, expect)
+	s.assertCached(c, "container-config", &params.ContainerConfig{}, &expect)
+}
+
+func (s *cachedAPISuite) TestContainerManagerConfig(c *gc.C) {
+	api := provisioner.NewCachedProvisionerAPI(s.provisioner, apicache.Open(s.cachePath, time.Hour))
+	args := params.ContainerManagerConfigParams{Type: instance.LXC}
+	config, err := api.ContainerManagerConfig(args)
+	c.Assert(err, jc.ErrorIsNil)
+	expect, err := s.provisioner.ContainerManagerConfig(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config, jc.DeepEquals, expect)
+	s.assertCached(c, "container-manager-config:lxc", &params.ContainerManagerConfig{}, &expect)
+}
@@ -16,12 +16,14 @@ import (
 	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/container/lxc"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/apicache"
 )
 
 // ContainerSetup is a StringsWatchHandler that is notified when containers
@@ -35,6 +37,7 @@ type ContainerSetup struct {
 	machine             *apiprovisioner.Machine
 	config              agent.Config
 	initLock            *fslock.Lock
+	apiCache            *apicache.Cache
 
 	// Save the workerName so the worker thread can be stopped.
 	workerName string
@@ -56,6 +59,11 @@ type ContainerSetupParams struct {
 	Provisioner         *apiprovisioner.State
 	Config              agent.Config
 	InitLock            *fslock.Lock
+
+	// APICache, if not nil, holds the results of the provisioner
+	// API calls made when starting containers whose results rarely
+	// change.
+	APICache *apicache.Cache
 }
 
 // NewContainerSetupHandler returns a StringsWatchHandler which is notified when
@@ -70,6 +78,7 @@ func NewContainerSetupHandler(params ContainerSetupParams) worker.StringsWatchHa
 		config:              params.Config,
 		workerName:          params.WorkerName,
 		initLock:            params.InitLock,
+		apiCache:            params.APICache,
 	}
 }
 
@@ -137,7 +146,12 @@ func (cs *ContainerSetup) initialiseAndStartProvisioner(containerType instance.C
 	}()
 
 	logger.Debugf("setup and start provisioner for %s containers", containerType)
-	toolsFinder := getToolsFinder(cs.provisioner)
+	var toolsFinder ToolsFinder
+	if cs.apiCache != nil {
+		toolsFinder = cs.cachedAPI()
+	} else {
+		toolsFinder = getToolsFinder(cs.provisioner)
+	}
 	initialiser, broker, toolsFinder, err := cs.getContainerArtifacts(containerType, toolsFinder)
 	if err != nil {
 		return errors.Annotate(err, "initialising container infrastructure on host machine")
@@ -148,6 +162,28 @@ func (cs *ContainerSetup) initialiseAndStartProvisioner(containerType instance.C
 	return StartProvisioner(cs.runner, containerType, cs.provisioner, cs.config, broker, toolsFinder)
 }
 
+// cachedAPI returns the provisioner API, answering the calls made when
+// starting containers from the setup's API cache.
+func (cs *ContainerSetup) cachedAPI() cachedProvisionerAPI {
+	return cachedProvisionerAPI{cs.provisioner, cs.apiCache}
+}
+
+// containerProvisionerAPI holds the provisioner API calls made when
+// setting up and starting containers.
+type containerProvisionerAPI interface {
+	APICalls
+	containerManagerConfigGetter
+}
+
+// containerAPI returns the API used when setting up and starting
+// containers.
+func (cs *ContainerSetup) containerAPI() containerProvisionerAPI {
+	if cs.apiCache != nil {
+		return cs.cachedAPI()
+	}
+	return cs.provisioner
+}
+
 // runInitialiser runs the container initialiser with the initialisation hook held.
 func (cs *ContainerSetup) runInitialiser(containerType instance.ContainerType, initialiser container.Initialiser) error {
 	logger.Debugf("running initialiser for %s containers", containerType)
@@ -180,7 +216,8 @@ func (cs *ContainerSetup) getContainerArtifacts(
 	var initialiser container.Initialiser
 	var broker environs.InstanceBroker
 
-	managerConfig, err := containerManagerConfig(containerType, cs.provisioner, cs.config)
+	api := cs.containerAPI()
+	managerConfig, err := containerManagerConfig(containerType, api, cs.config)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -193,7 +230,7 @@ func (cs *ContainerSetup) getContainerArtifacts(
 		}
 
 		initialiser = lxc.NewContainerInitialiser(series)
-		broker, err = NewLxcBroker(cs.provisioner, cs.config, managerConfig, cs.imageURLGetter)
+		broker, err = NewLxcBroker(api, cs.config, managerConfig, cs.imageURLGetter)
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -206,11 +243,23 @@ func (cs *ContainerSetup) getContainerArtifacts(
 
 	case instance.KVM:
 		initialiser = kvm.NewContainerInitialiser()
-		broker, err = NewKvmBroker(cs.provisioner, cs.config, managerConfig)
+		broker, err = NewKvmBroker(api, cs.config, managerConfig)
 		if err != nil {
 			logger.Errorf("failed to create new kvm broker")
 			return nil, nil, nil, err
 		}
+
+	case instance.HYPERV:
+		initialiser = hyperv.NewContainerInitialiser()
+		broker, err = NewHypervBroker(api, cs.config, managerConfig)
+		if err != nil {
+			logger.Errorf("failed to create new hyper-v broker")
+			return nil, nil, nil, err
+		}
+
+		// Hyper-V containers run the host's release of Windows, and
+		// must have its architecture.
+		toolsFinder = hostArchToolsFinder{toolsFinder}
 	default:
 		return nil, nil, nil, fmt.Errorf("unknown container type: %v", containerType)
 	}
@@ -218,9 +267,14 @@ func (cs *ContainerSetup) getContainerArtifacts(
 	return initialiser, broker, toolsFinder, nil
 }
 
+// containerManagerConfigGetter is implemented by the provisioner API.
+type containerManagerConfigGetter interface {
+	ContainerManagerConfig(params.ContainerManagerConfigParams) (params.ContainerManagerConfig, error)
+}
+
 func containerManagerConfig(
 	containerType instance.ContainerType,
-	provisioner *apiprovisioner.State,
+	provisioner containerManagerConfigGetter,
 	agentConfig agent.Config,
 ) (container.ManagerConfig, error) {
 	// Ask the provisioner for the container manager configuration.
@@ -21,6 +21,7 @@ import (
 	"github.com/juju/juju/agent"
 	apiprovisioner "github.com/juju/juju/api/provisioner"
 	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
 	containertesting "github.com/juju/juju/container/testing"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/instance"
@@ -153,14 +154,20 @@ func (s *ContainerSetupSuite) assertContainerProvisionerStarted(
 	s.PatchValue(&provisioner.StartProvisioner, startProvisionerWorker)
 
 	s.createContainer(c, host, ctype)
-	// Consume the apt command used to initialise the container.
-	<-s.aptCmdChan
+	if ctype != instance.HYPERV {
+		// Consume the apt command used to initialise the container.
+		<-s.aptCmdChan
+	}
 
 	// the container worker should have created the provisioner
 	c.Assert(provisionerStarted, jc.IsTrue)
 }
 
 func (s *ContainerSetupSuite) TestContainerProvisionerStarted(c *gc.C) {
+	// Hyper-V hosts are initialised with PowerShell rather than apt.
+	s.PatchValue(&hyperv.RunPowerShell, func(string) (string, error) {
+		return "No", nil
+	})
 	for _, ctype := range instance.ContainerTypes {
 		// create a machine to host the container.
 		m, err := s.BackingState.AddOneMachine(state.MachineTemplate{
@@ -6,9 +6,12 @@ package provisioner
 import (
 	"reflect"
 
+	apiprovisioner "github.com/juju/juju/api/provisioner"
 	"github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/worker/apicache"
 )
 
 func SetObserver(p Provisioner, observer chan<- *config.Config) {
@@ -22,6 +25,14 @@ func GetRetryWatcher(p Provisioner) (watcher.NotifyWatcher, error) {
 	return p.getRetryWatcher()
 }
 
+func PrepareContainers(broker environs.InstanceBroker, machineIds []string) error {
+	return broker.(containerPreparer).PrepareContainers(machineIds)
+}
+
+func NewCachedProvisionerAPI(st *apiprovisioner.State, cache *apicache.Cache) cachedProvisionerAPI {
+	return cachedProvisionerAPI{st, cache}
+}
+
 var (
 	ContainerManagerConfig = containerManagerConfig
 	GetToolsFinder         = &getToolsFinder
@@ -36,6 +47,8 @@ var (
 	InterfaceAddrs         = &interfaceAddrs
 	DiscoverPrimaryNIC     = discoverPrimaryNIC
 	MaybeAllocateStaticIP  = maybeAllocateStaticIP
+	HostsFile              = &hostsFile
+	SetHostsRecord         = setHostsRecord
 )
 
 const (
@@ -0,0 +1,108 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/container"
+	"github.com/juju/juju/container/hyperv"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+)
+
+var hypervLogger = loggo.GetLogger("juju.provisioner.hyperv")
+
+var _ environs.InstanceBroker = (*hypervBroker)(nil)
+
+func NewHypervBroker(
+	api APICalls,
+	agentConfig agent.Config,
+	managerConfig container.ManagerConfig,
+) (environs.InstanceBroker, error) {
+	manager, err := hyperv.NewContainerManager(managerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &hypervBroker{
+		manager:     manager,
+		api:         api,
+		agentConfig: agentConfig,
+	}, nil
+}
+
+type hypervBroker struct {
+	manager     container.Manager
+	api         APICalls
+	agentConfig agent.Config
+}
+
+// StartInstance is specified in the Broker interface.
+func (broker *hypervBroker) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+	if args.MachineConfig.HasNetworks() {
+		return nil, errors.New("starting hyper-v containers with networks is not supported yet")
+	}
+	machineId := args.MachineConfig.MachineId
+	hypervLogger.Infof("starting hyper-v container for machineId: %s", machineId)
+
+	// Hyper-V containers are attached to the host's virtual switch, so
+	// that they share the network the host is on.
+	network := container.BridgeNetworkConfig(hyperv.DefaultHypervSwitch, args.NetworkInfo)
+
+	series := args.Tools.OneSeries()
+	args.MachineConfig.MachineContainerType = instance.HYPERV
+	args.MachineConfig.Tools = args.Tools[0]
+
+	config, err := broker.api.ContainerConfig()
+	if err != nil {
+		hypervLogger.Errorf("failed to get container config: %v", err)
+		return nil, err
+	}
+
+	if err := environs.PopulateMachineConfig(
+		args.MachineConfig,
+		config.ProviderType,
+		config.AuthorizedKeys,
+		config.SSLHostnameVerification,
+		config.Proxy,
+		config.AptProxy,
+		config.AptMirror,
+		config.PreferIPv6,
+		config.EnableOSRefreshUpdate,
+		config.EnableOSUpgrade,
+	); err != nil {
+		hypervLogger.Errorf("failed to populate machine config: %v", err)
+		return nil, err
+	}
+
+	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
+	if err != nil {
+		hypervLogger.Errorf("failed to start container: %v", err)
+		return nil, err
+	}
+	hypervLogger.Infof("started hyper-v container for machineId: %s, %s, %s", machineId, inst.Id(), hardware.String())
+	return &environs.StartInstanceResult{
+		Instance: inst,
+		Hardware: hardware,
+	}, nil
+}
+
+// StopInstances shuts down the given instances.
+func (broker *hypervBroker) StopInstances(ids ...instance.Id) error {
+	for _, id := range ids {
+		hypervLogger.Infof("stopping hyper-v container for instance: %s", id)
+		if err := broker.manager.DestroyContainer(id); err != nil {
+			hypervLogger.Errorf("container did not stop: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AllInstances only returns running containers.
+func (broker *hypervBroker) AllInstances() (result []instance.Instance, err error) {
+	return broker.manager.ListContainers()
+}
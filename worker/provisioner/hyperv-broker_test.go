@@ -0,0 +1,111 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner_test
+
+import (
+	"path/filepath"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/container"
+	hypervtesting "github.com/juju/juju/container/hyperv/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	instancetest "github.com/juju/juju/instance/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/provisioner"
+)
+
+type hypervBrokerSuite struct {
+	hypervtesting.TestSuite
+	broker environs.InstanceBroker
+}
+
+var _ = gc.Suite(&hypervBrokerSuite{})
+
+func (s *hypervBrokerSuite) SetUpTest(c *gc.C) {
+	s.TestSuite.SetUpTest(c)
+	agentConfig, err := agent.NewAgentConfig(
+		agent.AgentConfigParams{
+			DataDir:           "/not/used/here",
+			Tag:               names.NewMachineTag("1"),
+			UpgradedToVersion: version.Current.Number,
+			Password:          "dummy-secret",
+			Nonce:             "nonce",
+			APIAddresses:      []string{"10.0.0.1:1234"},
+			CACert:            coretesting.CACert,
+			Environment:       coretesting.EnvironmentTag,
+		})
+	c.Assert(err, jc.ErrorIsNil)
+	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
+	s.broker, err = provisioner.NewHypervBroker(&fakeAPI{}, agentConfig, managerConfig)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *hypervBrokerSuite) startInstance(c *gc.C, machineId string) instance.Instance {
+	stateInfo := jujutesting.FakeStateInfo(machineId)
+	apiInfo := jujutesting.FakeAPIInfo(machineId)
+	machineConfig, err := environs.NewMachineConfig(machineId, "fake-nonce", "released", "win2016", true, nil, stateInfo, apiInfo)
+	c.Assert(err, jc.ErrorIsNil)
+	possibleTools := coretools.List{&coretools.Tools{
+		Version: version.MustParseBinary("2.3.4-win2016-amd64"),
+		URL:     "http://tools.testing.invalid/2.3.4-win2016-amd64.tgz",
+	}}
+	result, err := s.broker.StartInstance(environs.StartInstanceParams{
+		Constraints:   constraints.Value{},
+		Tools:         possibleTools,
+		MachineConfig: machineConfig,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return result.Instance
+}
+
+func (s *hypervBrokerSuite) TestStartInstance(c *gc.C) {
+	inst := s.startInstance(c, "1/hyperv/0")
+	c.Assert(inst.Id(), gc.Equals, instance.Id("juju-machine-1-hyperv-0"))
+	params := s.ContainerFactory.StartParams(string(inst.Id()))
+	c.Assert(params.Image, gc.Equals, "WindowsServerCore")
+	c.Assert(params.SwitchName, gc.Equals, hypervtesting.TestSwitch)
+	c.Assert(params.UserDataFile, gc.Equals, filepath.Join(s.ContainerDir, string(inst.Id()), "userdata.ps1"))
+}
+
+func (s *hypervBrokerSuite) TestStopInstance(c *gc.C) {
+	hyperv0 := s.startInstance(c, "1/hyperv/0")
+	hyperv1 := s.startInstance(c, "1/hyperv/1")
+	hyperv2 := s.startInstance(c, "1/hyperv/2")
+
+	err := s.broker.StopInstances(hyperv0.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertInstances(c, hyperv1, hyperv2)
+	c.Assert(filepath.Join(s.ContainerDir, string(hyperv0.Id())), jc.DoesNotExist)
+	c.Assert(filepath.Join(s.RemovedDir, string(hyperv0.Id())), jc.IsDirectory)
+
+	err = s.broker.StopInstances(hyperv1.Id(), hyperv2.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertInstances(c)
+}
+
+func (s *hypervBrokerSuite) TestAllInstances(c *gc.C) {
+	hyperv0 := s.startInstance(c, "1/hyperv/0")
+	hyperv1 := s.startInstance(c, "1/hyperv/1")
+	s.assertInstances(c, hyperv0, hyperv1)
+
+	err := s.broker.StopInstances(hyperv1.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	hyperv2 := s.startInstance(c, "1/hyperv/2")
+	s.assertInstances(c, hyperv0, hyperv2)
+}
+
+func (s *hypervBrokerSuite) assertInstances(c *gc.C, inst ...instance.Instance) {
+	results, err := s.broker.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	instancetest.MatchInstances(c, results, inst...)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/juju/loggo"
 
 	"github.com/juju/juju/agent"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/container"
 	"github.com/juju/juju/container/kvm"
 	"github.com/juju/juju/environs"
@@ -16,30 +17,43 @@ import (
 
 var kvmLogger = loggo.GetLogger("juju.provisioner.kvm")
 
-var _ environs.InstanceBroker = (*kvmBroker)(nil)
+var (
+	_ environs.InstanceBroker = (*kvmBroker)(nil)
+	_ containerPreparer       = (*kvmBroker)(nil)
+)
 
 func NewKvmBroker(
 	api APICalls,
 	agentConfig agent.Config,
 	managerConfig container.ManagerConfig,
 ) (environs.InstanceBroker, error) {
+	namespace := managerConfig[container.ConfigName]
 	manager, err := kvm.NewContainerManager(managerConfig)
 	if err != nil {
 		return nil, err
 	}
 	return &kvmBroker{
 		manager:     manager,
-		api:         api,
+		namespace:   namespace,
+		api:         newPreparedAPI(api),
 		agentConfig: agentConfig,
 	}, nil
 }
 
 type kvmBroker struct {
 	manager     container.Manager
-	api         APICalls
+	namespace   string
+	api         *preparedAPI
 	agentConfig agent.Config
 }
 
+// PrepareContainers is part of the containerPreparer interface. The
+// provider does not allocate addresses for kvm containers, so only
+// the container config is fetched.
+func (broker *kvmBroker) PrepareContainers(machineIds []string) error {
+	return broker.api.prepare(machineIds, false)
+}
+
 // StartInstance is specified in the Broker interface.
 func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
 	if args.MachineConfig.HasNetworks() {
@@ -56,13 +70,26 @@ func (broker *kvmBroker) StartInstance(args environs.StartInstanceParams) (*envi
 	if bridgeDevice == "" {
 		bridgeDevice = kvm.DefaultKvmBridge
 	}
+	// The provider does not allocate addresses for kvm containers, so
+	// they use the bridge's address pool if it has one, and DHCP
+	// otherwise.
+	if len(args.NetworkInfo) == 0 {
+		allocatedInfo, err := allocatePoolAddress(
+			machineId, bridgeDevice, containerHostname(broker.namespace, machineId), broker.api,
+		)
+		if err == nil {
+			args.NetworkInfo = allocatedInfo
+		} else if !params.IsCodeNotFound(err) {
+			kvmLogger.Warningf("not allocating static IP for container %q: %v", machineId, err)
+		}
+	}
 	network := container.BridgeNetworkConfig(bridgeDevice, args.NetworkInfo)
 
 	series := args.Tools.OneSeries()
 	args.MachineConfig.MachineContainerType = instance.KVM
 	args.MachineConfig.Tools = args.Tools[0]
 
-	config, err := broker.api.ContainerConfig()
+	config, err := broker.api.containerConfig(machineId)
 	if err != nil {
 		kvmLogger.Errorf("failed to get container config: %v", err)
 		return nil, err
@@ -105,6 +132,7 @@ func (broker *kvmBroker) StopInstances(ids ...instance.Id) error {
 			kvmLogger.Errorf("container did not stop: %v", err)
 			return err
 		}
+		removeHostsRecords(string(id))
 	}
 	return nil
 }
@@ -40,6 +40,7 @@ type kvmBrokerSuite struct {
 	kvmSuite
 	broker      environs.InstanceBroker
 	agentConfig agent.Config
+	api         *fakeAPI
 }
 
 var _ = gc.Suite(&kvmBrokerSuite{})
@@ -49,6 +50,7 @@ func (s *kvmSuite) SetUpTest(c *gc.C) {
 		c.Skip("Skipping kvm tests on windows")
 	}
 	s.TestSuite.SetUpTest(c)
+	s.PatchValue(provisioner.HostsFile, filepath.Join(c.MkDir(), "hosts"))
 	s.events = make(chan mock.Event)
 	s.eventsDone = make(chan struct{})
 	go func() {
@@ -85,7 +87,8 @@ func (s *kvmBrokerSuite) SetUpTest(c *gc.C) {
 		})
 	c.Assert(err, jc.ErrorIsNil)
 	managerConfig := container.ManagerConfig{container.ConfigName: "juju"}
-	s.broker, err = provisioner.NewKvmBroker(&fakeAPI{}, s.agentConfig, managerConfig)
+	s.api = &fakeAPI{}
+	s.broker, err = provisioner.NewKvmBroker(s.api, s.agentConfig, managerConfig)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
@@ -109,6 +112,20 @@ func (s *kvmBrokerSuite) startInstance(c *gc.C, machineId string) instance.Insta
 	return result.Instance
 }
 
+func (s *kvmBrokerSuite) TestStartInstancePrepared(c *gc.C) {
+	err := provisioner.PrepareContainers(s.broker, []string{"1/kvm/0", "1/kvm/1"})
+	c.Assert(err, jc.ErrorIsNil)
+	kvm0 := s.startInstance(c, "1/kvm/0")
+	kvm1 := s.startInstance(c, "1/kvm/1")
+	s.assertInstances(c, kvm0, kvm1)
+	c.Assert(s.api.calls, jc.DeepEquals, []string{"PrepareContainers"})
+
+	// Containers outside the batch fetch the container config
+	// as they start.
+	s.startInstance(c, "1/kvm/2")
+	c.Assert(s.api.calls, jc.DeepEquals, []string{"PrepareContainers", "ContainerConfig"})
+}
+
 func (s *kvmBrokerSuite) TestStopInstance(c *gc.C) {
 	kvm0 := s.startInstance(c, "1/kvm/0")
 	kvm1 := s.startInstance(c, "1/kvm/1")
@@ -31,11 +31,16 @@ import (
 
 var lxcLogger = loggo.GetLogger("juju.provisioner.lxc")
 
-var _ environs.InstanceBroker = (*lxcBroker)(nil)
+var (
+	_ environs.InstanceBroker = (*lxcBroker)(nil)
+	_ containerPreparer       = (*lxcBroker)(nil)
+)
 
 type APICalls interface {
 	ContainerConfig() (params.ContainerConfig, error)
 	PrepareContainerInterfaceInfo(names.MachineTag) ([]network.InterfaceInfo, error)
+	AllocateContainerPoolAddress(tag names.MachineTag, bridge, hostname string) ([]network.InterfaceInfo, error)
+	PrepareContainers([]names.MachineTag) (params.ContainerConfig, []apiprovisioner.PreparedContainer, error)
 }
 
 var _ APICalls = (*apiprovisioner.State)(nil)
@@ -47,23 +52,33 @@ func newLxcBroker(
 	api APICalls, agentConfig agent.Config, managerConfig container.ManagerConfig,
 	imageURLGetter container.ImageURLGetter,
 ) (environs.InstanceBroker, error) {
+	namespace := managerConfig[container.ConfigName]
 	manager, err := lxc.NewContainerManager(managerConfig, imageURLGetter)
 	if err != nil {
 		return nil, err
 	}
 	return &lxcBroker{
 		manager:     manager,
-		api:         api,
+		namespace:   namespace,
+		api:         newPreparedAPI(api),
 		agentConfig: agentConfig,
 	}, nil
 }
 
 type lxcBroker struct {
 	manager     container.Manager
-	api         APICalls
+	namespace   string
+	api         *preparedAPI
 	agentConfig agent.Config
 }
 
+// PrepareContainers is part of the containerPreparer interface. It
+// fetches the container config, and allocates the addresses of the
+// given containers, in a single API call.
+func (broker *lxcBroker) PrepareContainers(machineIds []string) error {
+	return broker.api.prepare(machineIds, true)
+}
+
 // StartInstance is specified in the Broker interface.
 func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
 	if args.MachineConfig.HasNetworks() {
@@ -79,7 +94,8 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (*envi
 		bridgeDevice = lxc.DefaultLxcBridge
 	}
 	allocatedInfo, err := maybeAllocateStaticIP(
-		machineId, bridgeDevice, broker.api, args.NetworkInfo,
+		machineId, bridgeDevice, containerHostname(broker.namespace, machineId),
+		broker.api, args.NetworkInfo,
 	)
 	if err != nil {
 		// It's fine, just ignore it. The effect will be that the
@@ -109,7 +125,7 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (*envi
 	args.MachineConfig.MachineContainerType = instance.LXC
 	args.MachineConfig.Tools = archTools[0]
 
-	config, err := broker.api.ContainerConfig()
+	config, err := broker.api.containerConfig(machineId)
 	if err != nil {
 		lxcLogger.Errorf("failed to get container config: %v", err)
 		return nil, err
@@ -130,6 +146,10 @@ func (broker *lxcBroker) StartInstance(args environs.StartInstanceParams) (*envi
 		return nil, err
 	}
 
+	// The container manager uses the constraints to decide which host
+	// devices, such as GPUs, to pass through to the container.
+	args.MachineConfig.Constraints = args.Constraints
+
 	inst, hardware, err := broker.manager.CreateContainer(args.MachineConfig, series, network)
 	if err != nil {
 		lxcLogger.Errorf("failed to start container: %v", err)
@@ -151,6 +171,7 @@ func (broker *lxcBroker) StopInstances(ids ...instance.Id) error {
 			lxcLogger.Errorf("container did not stop: %v", err)
 			return err
 		}
+		removeHostsRecords(string(id))
 	}
 	return nil
 }
@@ -387,11 +408,12 @@ func discoverPrimaryNIC() (string, network.Address, error) {
 }
 
 // maybeAllocateStaticIP tries to allocate a static IP address for the
-// given containerId using the provisioner API. If it fails, it's not
-// critical - just a warning, and it won't cause StartInstance to
-// fail.
+// given containerId using the provisioner API. When the provider cannot
+// allocate one, the address is allocated from the address pool of the
+// bridge, if it has one. If it fails, it's not critical - just a
+// warning, and it won't cause StartInstance to fail.
 func maybeAllocateStaticIP(
-	containerId, bridgeDevice string,
+	containerId, bridgeDevice, hostname string,
 	apiFacade APICalls,
 	ifaceInfo []network.InterfaceInfo,
 ) (finalIfaceInfo []network.InterfaceInfo, err error) {
@@ -410,18 +432,24 @@ func maybeAllocateStaticIP(
 	}
 	logger.Debugf("trying to allocate a static IP for container %q", containerId)
 
-	var primaryNIC string
-	var primaryAddr network.Address
-	primaryNIC, primaryAddr, err = discoverPrimaryNIC()
+	finalIfaceInfo, err = apiFacade.PrepareContainerInterfaceInfo(names.NewMachineTag(containerId))
 	if err != nil {
+		poolIfaceInfo, poolErr := allocatePoolAddress(containerId, bridgeDevice, hostname, apiFacade)
+		if poolErr == nil {
+			return poolIfaceInfo, nil
+		} else if !params.IsCodeNotFound(poolErr) {
+			logger.Warningf("cannot allocate address from pool for %s: %v", bridgeDevice, poolErr)
+		}
 		return nil, errors.Trace(err)
 	}
+	logger.Debugf("PrepareContainerInterfaceInfo returned %#v", finalIfaceInfo)
 
-	finalIfaceInfo, err = apiFacade.PrepareContainerInterfaceInfo(names.NewMachineTag(containerId))
+	var primaryNIC string
+	var primaryAddr network.Address
+	primaryNIC, primaryAddr, err = discoverPrimaryNIC()
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	logger.Debugf("PrepareContainerInterfaceInfo returned %#v", finalIfaceInfo)
 
 	// Populate ConfigType and DNSServers as needed.
 	var dnsServers []network.Address
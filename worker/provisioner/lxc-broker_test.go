@@ -21,6 +21,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/agent"
+	apiprovisioner "github.com/juju/juju/api/provisioner"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/container"
@@ -51,6 +52,7 @@ type lxcBrokerSuite struct {
 	lxcSuite
 	broker      environs.InstanceBroker
 	agentConfig agent.ConfigSetterWriter
+	api         *fakeAPI
 }
 
 var _ = gc.Suite(&lxcBrokerSuite{})
@@ -60,6 +62,7 @@ func (s *lxcSuite) SetUpTest(c *gc.C) {
 	if runtime.GOOS == "windows" {
 		c.Skip("Skipping lxc tests on windows")
 	}
+	s.PatchValue(provisioner.HostsFile, filepath.Join(c.MkDir(), "hosts"))
 	s.events = make(chan mock.Event)
 	s.eventsDone = make(chan struct{})
 	go func() {
@@ -100,7 +103,8 @@ func (s *lxcBrokerSuite) SetUpTest(c *gc.C) {
 		"log-dir":            c.MkDir(),
 		"use-clone":          "false",
 	}
-	s.broker, err = provisioner.NewLxcBroker(&fakeAPI{}, s.agentConfig, managerConfig, nil)
+	s.api = &fakeAPI{}
+	s.broker, err = provisioner.NewLxcBroker(s.api, s.agentConfig, managerConfig, nil)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
@@ -144,6 +148,21 @@ func (s *lxcBrokerSuite) TestStartInstance(c *gc.C) {
 	c.Assert(string(lxcConfContents), jc.Contains, "lxc.network.link = lxcbr0")
 }
 
+func (s *lxcBrokerSuite) TestStartInstancePrepared(c *gc.C) {
+	err := provisioner.PrepareContainers(s.broker, []string{"1/lxc/0", "1/lxc/1"})
+	c.Assert(err, jc.ErrorIsNil)
+	lxc0 := s.startInstance(c, "1/lxc/0")
+	lxc1 := s.startInstance(c, "1/lxc/1")
+	s.assertInstances(c, lxc0, lxc1)
+	c.Assert(s.api.calls, jc.DeepEquals, []string{"PrepareContainers"})
+
+	// Containers outside the batch are prepared as they start.
+	s.startInstance(c, "1/lxc/2")
+	c.Assert(s.api.calls, jc.DeepEquals, []string{
+		"PrepareContainers", "PrepareContainerInterfaceInfo", "ContainerConfig",
+	})
+}
+
 func (s *lxcBrokerSuite) TestStartInstanceHostArch(c *gc.C) {
 	machineConfig := s.machineConfig(c, "1/lxc/0")
 
@@ -687,13 +706,13 @@ func (s *lxcBrokerSuite) TestMaybeAllocateStaticIP(c *gc.C) {
 	// When ifaceInfo is not empty it shouldn't do anything and both
 	// the error and the result are nil.
 	ifaceInfo := []network.InterfaceInfo{{DeviceIndex: 0}}
-	result, err := provisioner.MaybeAllocateStaticIP("42", "bridge", &fakeAPI{c}, ifaceInfo)
+	result, err := provisioner.MaybeAllocateStaticIP("42", "bridge", "juju-machine-42", &fakeAPI{c: c}, ifaceInfo)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, gc.IsNil)
 
 	// When it's not empty, result should be populated as expected.
 	ifaceInfo = []network.InterfaceInfo{}
-	result, err = provisioner.MaybeAllocateStaticIP("42", "bridge", &fakeAPI{c}, ifaceInfo)
+	result, err = provisioner.MaybeAllocateStaticIP("42", "bridge", "juju-machine-42", &fakeAPI{c: c}, ifaceInfo)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(result, jc.DeepEquals, []network.InterfaceInfo{{
 		DeviceIndex:    0,
@@ -706,6 +725,64 @@ func (s *lxcBrokerSuite) TestMaybeAllocateStaticIP(c *gc.C) {
 	}})
 }
 
+func (s *lxcBrokerSuite) TestMaybeAllocateStaticIPFromPool(c *gc.C) {
+	fakeResolvConf := filepath.Join(c.MkDir(), "resolv.conf")
+	err := ioutil.WriteFile(fakeResolvConf, []byte("nameserver ns1.dummy\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(provisioner.ResolvConf, fakeResolvConf)
+
+	// When the provider cannot allocate an address, and the bridge has
+	// no address pool, the original error is returned.
+	api := &fakeAPI{c: c, prepareErr: errors.New("environment networking not supported")}
+	_, err = provisioner.MaybeAllocateStaticIP("42", "bridge", "juju-machine-42", api, nil)
+	c.Assert(err, gc.ErrorMatches, "environment networking not supported")
+
+	// Otherwise the address is allocated from the pool, and the
+	// container's host name recorded.
+	api.poolAddress = "10.0.3.2"
+	result, err := provisioner.MaybeAllocateStaticIP("42", "bridge", "juju-machine-42", api, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, []network.InterfaceInfo{{
+		DeviceIndex:    0,
+		CIDR:           "10.0.3.0/24",
+		ConfigType:     network.ConfigStatic,
+		InterfaceName:  "eth0",
+		DNSServers:     network.NewAddresses("ns1.dummy"),
+		Address:        network.NewAddress("10.0.3.2", network.ScopeUnknown),
+		GatewayAddress: network.NewAddress("10.0.3.1", network.ScopeUnknown),
+	}})
+	data, err := ioutil.ReadFile(*provisioner.HostsFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "10.0.3.2 juju-machine-42 # juju container address pool\n")
+}
+
+func (s *lxcBrokerSuite) TestSetHostsRecord(c *gc.C) {
+	hostsFile := filepath.Join(c.MkDir(), "hosts")
+	original := "127.0.0.1 localhost\n10.0.3.9 juju-machine-1-lxc-0\n"
+	err := ioutil.WriteFile(hostsFile, []byte(original), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	assertHosts := func(expect string) {
+		data, err := ioutil.ReadFile(hostsFile)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(data), gc.Equals, expect)
+	}
+	err = provisioner.SetHostsRecord(hostsFile, "juju-machine-1-lxc-0", "10.0.3.2")
+	c.Assert(err, jc.ErrorIsNil)
+	err = provisioner.SetHostsRecord(hostsFile, "juju-machine-1-lxc-1", "10.0.3.3")
+	c.Assert(err, jc.ErrorIsNil)
+	assertHosts(original +
+		"10.0.3.2 juju-machine-1-lxc-0 # juju container address pool\n" +
+		"10.0.3.3 juju-machine-1-lxc-1 # juju container address pool\n")
+
+	// Records added by hand are left alone.
+	err = provisioner.SetHostsRecord(hostsFile, "juju-machine-1-lxc-0", "10.0.3.4")
+	c.Assert(err, jc.ErrorIsNil)
+	err = provisioner.SetHostsRecord(hostsFile, "juju-machine-1-lxc-1", "")
+	c.Assert(err, jc.ErrorIsNil)
+	assertHosts(original + "10.0.3.4 juju-machine-1-lxc-0 # juju container address pool\n")
+}
+
 type lxcProvisionerSuite struct {
 	CommonProvisionerSuite
 	lxcSuite
@@ -847,22 +924,45 @@ func (s *lxcProvisionerSuite) TestContainerStartedAndStopped(c *gc.C) {
 
 type fakeAPI struct {
 	c *gc.C
+
+	// prepareErr, if set, is returned by PrepareContainerInterfaceInfo.
+	prepareErr error
+
+	// poolAddress, if set, is the address AllocateContainerPoolAddress
+	// allocates; otherwise the bridge has no address pool.
+	poolAddress string
+
+	// calls records the names of the methods called.
+	calls []string
 }
 
 var _ provisioner.APICalls = (*fakeAPI)(nil)
 
-func (*fakeAPI) ContainerConfig() (params.ContainerConfig, error) {
+func (f *fakeAPI) ContainerConfig() (params.ContainerConfig, error) {
+	f.calls = append(f.calls, "ContainerConfig")
+	return f.containerConfig(), nil
+}
+
+func (*fakeAPI) containerConfig() params.ContainerConfig {
 	return params.ContainerConfig{
 		UpdateBehavior:          &params.UpdateBehavior{true, true},
 		ProviderType:            "fake",
 		AuthorizedKeys:          coretesting.FakeAuthKeys,
-		SSLHostnameVerification: true}, nil
+		SSLHostnameVerification: true}
 }
 
 func (f *fakeAPI) PrepareContainerInterfaceInfo(tag names.MachineTag) ([]network.InterfaceInfo, error) {
+	f.calls = append(f.calls, "PrepareContainerInterfaceInfo")
+	return f.interfaceInfo(tag)
+}
+
+func (f *fakeAPI) interfaceInfo(tag names.MachineTag) ([]network.InterfaceInfo, error) {
 	if f.c != nil {
 		f.c.Assert(tag.String(), gc.Equals, "machine-42")
 	}
+	if f.prepareErr != nil {
+		return nil, f.prepareErr
+	}
 	return []network.InterfaceInfo{{
 		DeviceIndex:    0,
 		CIDR:           "0.1.2.0/24",
@@ -871,3 +971,33 @@ func (f *fakeAPI) PrepareContainerInterfaceInfo(tag names.MachineTag) ([]network
 		GatewayAddress: network.NewAddress("0.1.2.1", network.ScopeUnknown),
 	}}, nil
 }
+
+func (f *fakeAPI) PrepareContainers(tags []names.MachineTag) (params.ContainerConfig, []apiprovisioner.PreparedContainer, error) {
+	f.calls = append(f.calls, "PrepareContainers")
+	prepared := make([]apiprovisioner.PreparedContainer, len(tags))
+	for i, tag := range tags {
+		prepared[i].InterfaceInfo, prepared[i].Error = f.interfaceInfo(tag)
+	}
+	return f.containerConfig(), prepared, nil
+}
+
+func (f *fakeAPI) AllocateContainerPoolAddress(tag names.MachineTag, bridge, hostname string) ([]network.InterfaceInfo, error) {
+	if f.poolAddress == "" {
+		return nil, &params.Error{
+			Message: fmt.Sprintf("address pool for %s not found", bridge),
+			Code:    params.CodeNotFound,
+		}
+	}
+	if f.c != nil {
+		f.c.Assert(tag.String(), gc.Equals, "machine-42")
+		f.c.Assert(bridge, gc.Equals, "bridge")
+		f.c.Assert(hostname, gc.Equals, "juju-machine-42")
+	}
+	return []network.InterfaceInfo{{
+		DeviceIndex:    0,
+		CIDR:           "10.0.3.0/24",
+		ConfigType:     network.ConfigStatic,
+		Address:        network.NewAddress(f.poolAddress, network.ScopeUnknown),
+		GatewayAddress: network.NewAddress("10.0.3.1", network.ScopeUnknown),
+	}}, nil
+}
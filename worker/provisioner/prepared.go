@@ -0,0 +1,110 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package provisioner
+
+import (
+	"sync"
+
+	"github.com/juju/names"
+
+	apiprovisioner "github.com/juju/juju/api/provisioner"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/network"
+)
+
+// containerPreparer is implemented by container brokers that can fetch
+// what they need to start several containers with a single API call,
+// before the containers are started one by one.
+type containerPreparer interface {
+	// PrepareContainers fetches what is needed to start the
+	// containers for the given machines.
+	PrepareContainers(machineIds []string) error
+}
+
+// preparedAPI answers the API calls a container broker makes when
+// starting a container from the results of an earlier batched call,
+// where it has them. Each prepared result is used at most once, and
+// those left unused are discarded when the next batch is prepared, so
+// that they cannot go stale.
+type preparedAPI struct {
+	APICalls
+
+	mu sync.Mutex
+
+	// config holds the container config fetched for the machines
+	// in pending.
+	config  params.ContainerConfig
+	pending map[string]bool
+
+	// interfaces holds the network configuration prepared for
+	// each machine, by machine id.
+	interfaces map[string]apiprovisioner.PreparedContainer
+}
+
+var _ APICalls = (*preparedAPI)(nil)
+
+func newPreparedAPI(api APICalls) *preparedAPI {
+	return &preparedAPI{APICalls: api}
+}
+
+// prepare fetches the container config for the given machines and, if
+// withInterfaces is true, allocates addresses and prepares the network
+// configuration of each of them.
+func (api *preparedAPI) prepare(machineIds []string, withInterfaces bool) error {
+	api.mu.Lock()
+	api.pending = nil
+	api.interfaces = nil
+	api.mu.Unlock()
+
+	var tags []names.MachineTag
+	if withInterfaces {
+		tags = make([]names.MachineTag, len(machineIds))
+		for i, id := range machineIds {
+			tags[i] = names.NewMachineTag(id)
+		}
+	}
+	config, prepared, err := api.APICalls.PrepareContainers(tags)
+	if err != nil {
+		return err
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.config = config
+	api.pending = make(map[string]bool, len(machineIds))
+	for _, id := range machineIds {
+		api.pending[id] = true
+	}
+	api.interfaces = make(map[string]apiprovisioner.PreparedContainer)
+	for i, tag := range tags {
+		api.interfaces[tag.Id()] = prepared[i]
+	}
+	return nil
+}
+
+// containerConfig returns the container config to start the container
+// for the given machine with.
+func (api *preparedAPI) containerConfig(machineId string) (params.ContainerConfig, error) {
+	api.mu.Lock()
+	if api.pending[machineId] {
+		delete(api.pending, machineId)
+		config := api.config
+		api.mu.Unlock()
+		return config, nil
+	}
+	api.mu.Unlock()
+	return api.APICalls.ContainerConfig()
+}
+
+// PrepareContainerInterfaceInfo is part of the APICalls interface.
+func (api *preparedAPI) PrepareContainerInterfaceInfo(tag names.MachineTag) ([]network.InterfaceInfo, error) {
+	api.mu.Lock()
+	prepared, ok := api.interfaces[tag.Id()]
+	if ok {
+		delete(api.interfaces, tag.Id())
+		api.mu.Unlock()
+		return prepared.InterfaceInfo, prepared.Error
+	}
+	api.mu.Unlock()
+	return api.APICalls.PrepareContainerInterfaceInfo(tag)
+}
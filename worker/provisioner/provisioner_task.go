@@ -520,10 +520,21 @@ func constructStartInstanceParams(
 }
 
 func (task *provisionerTask) startMachines(machines []*apiprovisioner.Machine) error {
+	task.prepareContainers(machines)
 	for _, m := range machines {
 
 		pInfo, err := task.blockUntilProvisioned(m.ProvisioningInfo)
-		if err != nil {
+		if params.IsCodeEnvironmentSuspended(err) {
+			// Provisioning is retried along with machines whose
+			// errors are transient, once the environment is no
+			// longer suspended.
+			logger.Infof("cannot provision machine %q: %v", m, err)
+			transient := map[string]interface{}{"transient": true}
+			if err := m.SetStatus(params.StatusError, err.Error(), transient); err != nil {
+				return errors.Annotatef(err, "cannot set error status for machine %q", m)
+			}
+			continue
+		} else if err != nil {
 			return err
 		}
 
@@ -560,6 +571,24 @@ func (task *provisionerTask) startMachines(machines []*apiprovisioner.Machine) e
 	return nil
 }
 
+// prepareContainers lets brokers that can fetch what they need to start
+// several containers in a single API call do so before the machines
+// are started one by one. If that fails, each container is prepared
+// as it is started instead.
+func (task *provisionerTask) prepareContainers(machines []*apiprovisioner.Machine) {
+	preparer, ok := task.broker.(containerPreparer)
+	if !ok || len(machines) == 0 {
+		return
+	}
+	machineIds := make([]string, len(machines))
+	for i, m := range machines {
+		machineIds[i] = m.Id()
+	}
+	if err := preparer.PrepareContainers(machineIds); err != nil {
+		logger.Warningf("cannot prepare containers %v: %v", machineIds, err)
+	}
+}
+
 func (task *provisionerTask) setErrorStatus(message string, machine *apiprovisioner.Machine, err error) error {
 	logger.Errorf(message, machine, err)
 	if err1 := machine.SetStatus(params.StatusError, err.Error(), nil); err1 != nil {
@@ -606,6 +635,10 @@ func (task *provisionerTask) startMachine(
 	startInstanceParams environs.StartInstanceParams,
 ) error {
 
+	startInstanceParams.StatusCallback = func(status params.Status, info string, data map[string]interface{}) {
+		task.setProvisioningStatus(machine, status, info, data)
+	}
+	task.setProvisioningStatus(machine, params.StatusInstanceRequested, "", nil)
 	result, err := task.broker.StartInstance(startInstanceParams)
 	if err != nil {
 		// If this is a retryable error, we retry once
@@ -646,6 +679,14 @@ func (task *provisionerTask) startMachine(
 			"started machine %s as instance %s with hardware %q, networks %v, interfaces %v, volumes %v, volume attachments %v",
 			machine, inst.Id(), hardware, networks, ifaces, volumes, volumeAttachments,
 		)
+		task.setProvisioningStatus(machine, params.StatusInstanceRunning, string(inst.Id()), nil)
+		// The instance goes on to download the tools chosen for it
+		// by the broker before starting the machine agent.
+		if tools := startInstanceParams.MachineConfig.Tools; tools != nil {
+			task.setProvisioningStatus(machine, params.StatusToolsDownloading, tools.URL, map[string]interface{}{
+				"version": tools.Version.String(),
+			})
+		}
 		return nil
 	}
 	// We need to stop the instance right away here, set error status and go on.
@@ -657,6 +698,20 @@ func (task *provisionerTask) startMachine(
 	return nil
 }
 
+// setProvisioningStatus records progress made in provisioning the
+// machine. The history is only kept to help diagnose provisioning
+// failures, so errors are logged rather than returned.
+func (task *provisionerTask) setProvisioningStatus(
+	machine *apiprovisioner.Machine,
+	status params.Status,
+	info string,
+	data map[string]interface{},
+) {
+	if err := machine.SetProvisioningStatus(status, info, data); err != nil {
+		logger.Warningf("cannot record provisioning status %q for machine %q: %v", status, machine, err)
+	}
+}
+
 type provisioningInfo struct {
 	Constraints   constraints.Value
 	Series        string
@@ -440,6 +440,35 @@ func (s *ProvisionerSuite) TestSimple(c *gc.C) {
 	s.waitRemoved(c, m)
 }
 
+func (s *ProvisionerSuite) TestProvisioningStatusHistory(c *gc.C) {
+	p := s.newEnvironProvisioner(c)
+	defer stop(c, p)
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	inst := s.checkStartInstanceNoSecureConnection(c, m)
+
+	expected := []state.Status{
+		state.StatusInstanceRunning,
+		state.StatusImageSelected,
+		state.StatusInstanceRequested,
+	}
+	var history []state.StatusInfo
+	for attempt := coretesting.LongAttempt.Start(); attempt.Next(); {
+		history, err = m.ProvisioningStatusHistory(state.StatusHistoryFilter{})
+		c.Assert(err, jc.ErrorIsNil)
+		if len(history) == len(expected) {
+			break
+		}
+	}
+	c.Assert(history, gc.HasLen, len(expected))
+	for i, entry := range history {
+		c.Check(entry.Status, gc.Equals, expected[i])
+	}
+	c.Check(history[0].Info, gc.Equals, string(inst.Id()))
+	c.Check(history[1].Info, gc.Equals, "dummy-"+coretesting.FakeDefaultSeries)
+}
+
 func (s *ProvisionerSuite) TestConstraints(c *gc.C) {
 	// Create a machine with non-standard constraints.
 	m, err := s.addMachine()
@@ -1267,6 +1296,40 @@ func (s *ProvisionerSuite) TestProvisionerRetriesTransientErrors(c *gc.C) {
 	c.Assert(err, jc.Satisfies, errors.IsNotProvisioned)
 }
 
+func (s *ProvisionerSuite) TestProvisionerWaitsWhileEnvironmentSuspended(c *gc.C) {
+	s.PatchValue(&apiserverprovisioner.ErrorRetryWaitDelay, 5*time.Millisecond)
+	env, err := s.BackingState.Environment()
+	c.Assert(err, jc.ErrorIsNil)
+	err = env.SetCredentialValidity(false, "secret rejected")
+	c.Assert(err, jc.ErrorIsNil)
+
+	task := s.newProvisionerTask(c, config.HarvestAll, s.Environ, s.provisioner, mockToolsFinder{})
+	defer stop(c, task)
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkNoOperations(c)
+
+	t0 := time.Now()
+	for time.Since(t0) < coretesting.LongWait {
+		status, info, data, err := m.Status()
+		c.Assert(err, jc.ErrorIsNil)
+		if status == state.StatusPending {
+			time.Sleep(coretesting.ShortWait)
+			continue
+		}
+		c.Assert(status, gc.Equals, state.StatusError)
+		c.Assert(info, gc.Equals, "environment suspended: secret rejected")
+		c.Assert(data, jc.DeepEquals, map[string]interface{}{"transient": true})
+		break
+	}
+
+	// The machine is provisioned once the environment is resumed.
+	err = env.SetCredentialValidity(true, "")
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkStartInstance(c, m)
+}
+
 func (s *ProvisionerSuite) TestProvisionerObservesMachineJobs(c *gc.C) {
 	s.PatchValue(&apiserverprovisioner.ErrorRetryWaitDelay, 5*time.Millisecond)
 	broker := &mockBroker{Environ: s.Environ, retryCount: make(map[string]int)}
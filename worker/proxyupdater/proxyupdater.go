@@ -6,6 +6,7 @@ package proxyupdater
 import (
 	"fmt"
 	"io/ioutil"
+	osexec "os/exec"
 	"path"
 
 	"github.com/juju/loggo"
@@ -14,7 +15,7 @@ import (
 	"github.com/juju/utils/exec"
 	proxyutils "github.com/juju/utils/proxy"
 
-	"github.com/juju/juju/api/environment"
+	apiproxyupdater "github.com/juju/juju/api/proxyupdater"
 	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker"
@@ -36,17 +37,40 @@ var (
 
 	// Started is a function that is called when the worker has started.
 	Started = func() {}
+
+	// setSnapProxy configures snapd to use the given proxies.
+	setSnapProxy = func(settings proxyutils.Settings) error {
+		if _, err := osexec.LookPath("snap"); err != nil {
+			logger.Debugf("snap not installed, not setting snap proxy")
+			return nil
+		}
+		output, err := osexec.Command("snap", "set", "core",
+			"proxy.http="+settings.Http,
+			"proxy.https="+settings.Https,
+		).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("snap set core: %v (%s)", err, output)
+		}
+		return nil
+	}
 )
 
+// API is the interface of the ProxyUpdater facade used by the worker.
+type API interface {
+	ProxyConfig() (apiproxyupdater.ProxyConfig, error)
+	WatchForProxyConfigChanges() (watcher.NotifyWatcher, error)
+}
+
 // proxyWorker is responsible for monitoring the juju environment
 // configuration and making changes on the physical (or virtual) machine as
 // necessary to match the environment changes.  Examples of these types of
-// changes are apt proxy configuration and the juju proxies stored in the juju
-// proxy file.
+// changes are apt and snap proxy configuration and the juju proxies stored in
+// the juju proxy file.
 type proxyWorker struct {
-	api      *environment.Facade
-	aptProxy proxyutils.Settings
-	proxy    proxyutils.Settings
+	api       API
+	aptProxy  proxyutils.Settings
+	snapProxy proxyutils.Settings
+	proxy     proxyutils.Settings
 
 	writeSystemFiles bool
 	// The whole point of the first value is to make sure that the the files
@@ -64,7 +88,7 @@ var _ worker.NotifyWatchHandler = (*proxyWorker)(nil)
 
 // New returns a worker.Worker that updates proxy environment variables for the
 // process; and, if writeSystemFiles is true, for the whole machine.
-var New = func(api *environment.Facade, writeSystemFiles bool) worker.Worker {
+var New = func(api API, writeSystemFiles bool) worker.Worker {
 	logger.Debugf("write system files: %v", writeSystemFiles)
 	envWorker := &proxyWorker{
 		api:              api,
@@ -172,13 +196,25 @@ func (w *proxyWorker) handleAptProxyValues(aptSettings proxyutils.Settings) {
 	}
 }
 
+func (w *proxyWorker) handleSnapProxyValues(snapSettings proxyutils.Settings) {
+	if w.writeSystemFiles && (snapSettings != w.snapProxy || w.first) {
+		logger.Debugf("new snap proxy settings %#v", snapSettings)
+		w.snapProxy = snapSettings
+		if err := setSnapProxy(w.snapProxy); err != nil {
+			// It isn't really fatal, but we should record it.
+			logger.Errorf("error setting snap proxy: %v", err)
+		}
+	}
+}
+
 func (w *proxyWorker) onChange() error {
-	env, err := w.api.EnvironConfig()
+	config, err := w.api.ProxyConfig()
 	if err != nil {
 		return err
 	}
-	w.handleProxyValues(env.ProxySettings())
-	w.handleAptProxyValues(env.AptProxySettings())
+	w.handleProxyValues(config.Proxy)
+	w.handleAptProxyValues(config.AptProxy)
+	w.handleSnapProxyValues(config.SnapProxy)
 	return nil
 }
 
@@ -192,7 +228,7 @@ func (w *proxyWorker) SetUp() (watcher.NotifyWatcher, error) {
 	}
 	w.first = false
 	Started()
-	return w.api.WatchForEnvironConfigChanges()
+	return w.api.WatchForProxyConfigChanges()
 }
 
 // Handle is defined on the worker.NotifyWatchHandler interface.
@@ -17,7 +17,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/api"
-	"github.com/juju/juju/api/environment"
+	apiproxyupdater "github.com/juju/juju/api/proxyupdater"
 	"github.com/juju/juju/environs/config"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
@@ -29,12 +29,13 @@ import (
 type ProxyUpdaterSuite struct {
 	jujutesting.JujuConnSuite
 
-	apiRoot        *api.State
-	environmentAPI *environment.Facade
-	machine        *state.Machine
+	apiRoot  *api.State
+	proxyAPI *apiproxyupdater.Facade
+	machine  *state.Machine
 
 	proxyFile string
 	started   chan struct{}
+	snapProxy chan proxy.Settings
 }
 
 var _ = gc.Suite(&ProxyUpdaterSuite{})
@@ -50,9 +51,9 @@ func (s *ProxyUpdaterSuite) setStarted() {
 func (s *ProxyUpdaterSuite) SetUpTest(c *gc.C) {
 	s.JujuConnSuite.SetUpTest(c)
 	s.apiRoot, s.machine = s.OpenAPIAsNewMachine(c)
-	// Create the environment API facade.
-	s.environmentAPI = s.apiRoot.Environment()
-	c.Assert(s.environmentAPI, gc.NotNil)
+	// Create the proxy updater API facade.
+	s.proxyAPI = s.apiRoot.ProxyUpdater()
+	c.Assert(s.proxyAPI, gc.NotNil)
 
 	proxyDir := c.MkDir()
 	s.PatchValue(&proxyupdater.ProxyDirectory, proxyDir)
@@ -60,6 +61,11 @@ func (s *ProxyUpdaterSuite) SetUpTest(c *gc.C) {
 	s.PatchValue(&proxyupdater.Started, s.setStarted)
 	s.PatchValue(&apt.ConfFile, path.Join(proxyDir, "juju-apt-proxy"))
 	s.proxyFile = path.Join(proxyDir, proxyupdater.ProxyFile)
+	s.snapProxy = make(chan proxy.Settings, 10)
+	s.PatchValue(proxyupdater.SetSnapProxy, func(settings proxy.Settings) error {
+		s.snapProxy <- settings
+		return nil
+	})
 }
 
 func (s *ProxyUpdaterSuite) waitForPostSetup(c *gc.C) {
@@ -111,7 +117,7 @@ func (s *ProxyUpdaterSuite) waitForFile(c *gc.C, filename, expected string) {
 }
 
 func (s *ProxyUpdaterSuite) TestRunStop(c *gc.C) {
-	updater := proxyupdater.New(s.environmentAPI, false)
+	updater := proxyupdater.New(s.proxyAPI, false)
 	c.Assert(worker.Stop(updater), gc.IsNil)
 }
 
@@ -147,10 +153,25 @@ func (s *ProxyUpdaterSuite) updateConfig(c *gc.C) (proxy.Settings, proxy.Setting
 	return proxySettings, aptProxySettings
 }
 
+func (s *ProxyUpdaterSuite) waitSnapProxySettings(c *gc.C, expected proxy.Settings) {
+	for {
+		select {
+		case <-time.After(testing.LongWait):
+			c.Fatalf("timeout while waiting for snap proxy settings")
+		case obtained := <-s.snapProxy:
+			if obtained != expected {
+				c.Logf("snap proxy settings are %#v, still waiting", obtained)
+				continue
+			}
+			return
+		}
+	}
+}
+
 func (s *ProxyUpdaterSuite) TestInitialState(c *gc.C) {
 	proxySettings, aptProxySettings := s.updateConfig(c)
 
-	updater := proxyupdater.New(s.environmentAPI, true)
+	updater := proxyupdater.New(s.proxyAPI, true)
 	defer worker.Stop(updater)
 
 	s.waitProxySettings(c, proxySettings)
@@ -161,7 +182,7 @@ func (s *ProxyUpdaterSuite) TestInitialState(c *gc.C) {
 func (s *ProxyUpdaterSuite) TestWriteSystemFiles(c *gc.C) {
 	proxySettings, aptProxySettings := s.updateConfig(c)
 
-	updater := proxyupdater.New(s.environmentAPI, true)
+	updater := proxyupdater.New(s.proxyAPI, true)
 	defer worker.Stop(updater)
 	s.waitForPostSetup(c)
 
@@ -182,7 +203,7 @@ func (s *ProxyUpdaterSuite) TestEnvironmentVariables(c *gc.C) {
 
 	proxySettings, _ := s.updateConfig(c)
 
-	updater := proxyupdater.New(s.environmentAPI, true)
+	updater := proxyupdater.New(s.proxyAPI, true)
 	defer worker.Stop(updater)
 	s.waitForPostSetup(c)
 	s.waitProxySettings(c, proxySettings)
@@ -200,11 +221,37 @@ func (s *ProxyUpdaterSuite) TestEnvironmentVariables(c *gc.C) {
 func (s *ProxyUpdaterSuite) TestDontWriteSystemFiles(c *gc.C) {
 	proxySettings, _ := s.updateConfig(c)
 
-	updater := proxyupdater.New(s.environmentAPI, false)
+	updater := proxyupdater.New(s.proxyAPI, false)
 	defer worker.Stop(updater)
 	s.waitForPostSetup(c)
 
 	s.waitProxySettings(c, proxySettings)
 	c.Assert(apt.ConfFile, jc.DoesNotExist)
 	c.Assert(s.proxyFile, jc.DoesNotExist)
+	select {
+	case settings := <-s.snapProxy:
+		c.Fatalf("unexpected snap proxy settings %#v", settings)
+	default:
+	}
+}
+
+func (s *ProxyUpdaterSuite) TestSnapProxy(c *gc.C) {
+	updater := proxyupdater.New(s.proxyAPI, true)
+	defer worker.Stop(updater)
+	s.waitForPostSetup(c)
+
+	// Snap proxies fall back to the general proxy settings.
+	proxySettings, _ := s.updateConfig(c)
+	s.waitSnapProxySettings(c, proxy.Settings{
+		Http:  "http://" + proxySettings.Http,
+		Https: "https://" + proxySettings.Https,
+	})
+
+	snapProxySettings := proxy.Settings{
+		Http:  "http://snap.http.proxy",
+		Https: "https://snap.https.proxy",
+	}
+	err := s.State.UpdateEnvironConfig(config.SnapProxyConfigMap(snapProxySettings), nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapProxySettings(c, snapProxySettings)
 }
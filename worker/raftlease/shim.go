@@ -0,0 +1,61 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api"
+	apiraftlease "github.com/juju/juju/api/raftlease"
+	"github.com/juju/juju/state"
+)
+
+// This file holds code that translates from State and the API to the
+// interfaces expected internally by the worker.
+
+// dialTimeout is how long to wait when connecting to another state
+// server.
+var dialTimeout = 5 * time.Second
+
+// NewStateShim returns a State which uses the given *state.State.
+func NewStateShim(st *state.State) State {
+	return stateShim{st}
+}
+
+type stateShim struct {
+	*state.State
+}
+
+func (s stateShim) Machine(id string) (Machine, error) {
+	return s.State.Machine(id)
+}
+
+// APIDialer returns a DialFunc which connects to the other state
+// servers using the given API information, which should be that of
+// this state server's agent.
+func APIDialer(info *api.Info) DialFunc {
+	return func(addr string) (Peer, error) {
+		peerInfo := *info
+		peerInfo.Addrs = []string{addr}
+		st, err := api.Open(&peerInfo, api.DialOpts{Timeout: dialTimeout})
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot connect to state server at %q", addr)
+		}
+		return apiPeer{
+			Client: st.RaftLease(),
+			st:     st,
+		}, nil
+	}
+}
+
+type apiPeer struct {
+	*apiraftlease.Client
+	st *api.State
+}
+
+func (p apiPeer) Close() error {
+	return p.st.Close()
+}
@@ -0,0 +1,158 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/raft"
+)
+
+// Peer is a connection to another state server's RaftLease facade.
+type Peer interface {
+	RequestVote(args raft.RequestVoteArgs) (raft.RequestVoteResult, error)
+	AppendEntries(args raft.AppendEntriesArgs) (raft.AppendEntriesResult, error)
+	InstallSnapshot(args raft.InstallSnapshotArgs) (raft.InstallSnapshotResult, error)
+	Propose(command []byte) ([]byte, error)
+	Close() error
+}
+
+// DialFunc connects to the state server at the given API address.
+type DialFunc func(addr string) (Peer, error)
+
+// transport implements raft.Transport and raftlease.Forwarder by
+// calling the RaftLease facades of the other state servers. It keeps a
+// connection open to each, redialling after any error.
+type transport struct {
+	dial DialFunc
+
+	mu        sync.Mutex
+	addresses map[string]string
+	peers     map[string]*peerConn
+}
+
+// peerConn holds a connection to a peer, shared by the goroutines
+// making calls on it.
+type peerConn struct {
+	addr string
+	once sync.Once
+	peer Peer
+	err  error
+}
+
+func newTransport(dial DialFunc) *transport {
+	return &transport{
+		dial:      dial,
+		addresses: make(map[string]string),
+		peers:     make(map[string]*peerConn),
+	}
+}
+
+// setAddresses records the API address of each peer, dropping
+// connections to peers whose address has changed.
+func (t *transport) setAddresses(addresses map[string]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.addresses = addresses
+	for id, conn := range t.peers {
+		if addresses[id] != conn.addr {
+			t.dropLocked(id, conn)
+		}
+	}
+}
+
+// close closes all the connections.
+func (t *transport) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, conn := range t.peers {
+		t.dropLocked(id, conn)
+	}
+}
+
+// dropLocked must be called with t.mu held.
+func (t *transport) dropLocked(id string, conn *peerConn) {
+	if t.peers[id] == conn {
+		delete(t.peers, id)
+	}
+	go func() {
+		conn.once.Do(func() {
+			conn.err = errors.New("connection dropped")
+		})
+		if conn.peer != nil {
+			if err := conn.peer.Close(); err != nil {
+				logger.Debugf("cannot close connection to raft peer %q: %v", id, err)
+			}
+		}
+	}()
+}
+
+// call calls f with a connection to the given peer, dropping the
+// connection if it fails.
+func (t *transport) call(id string, f func(Peer) error) error {
+	t.mu.Lock()
+	conn, ok := t.peers[id]
+	if !ok {
+		addr, ok := t.addresses[id]
+		if !ok || addr == "" {
+			t.mu.Unlock()
+			return errors.Errorf("no address for raft peer %q", id)
+		}
+		conn = &peerConn{addr: addr}
+		t.peers[id] = conn
+	}
+	t.mu.Unlock()
+
+	conn.once.Do(func() {
+		conn.peer, conn.err = t.dial(conn.addr)
+	})
+	err := conn.err
+	if err == nil {
+		err = f(conn.peer)
+	}
+	if err != nil {
+		t.mu.Lock()
+		t.dropLocked(id, conn)
+		t.mu.Unlock()
+	}
+	return errors.Trace(err)
+}
+
+// RequestVote is part of the raft.Transport interface.
+func (t *transport) RequestVote(id string, args raft.RequestVoteArgs) (result raft.RequestVoteResult, err error) {
+	err = t.call(id, func(peer Peer) (err error) {
+		result, err = peer.RequestVote(args)
+		return err
+	})
+	return result, err
+}
+
+// AppendEntries is part of the raft.Transport interface.
+func (t *transport) AppendEntries(id string, args raft.AppendEntriesArgs) (result raft.AppendEntriesResult, err error) {
+	err = t.call(id, func(peer Peer) (err error) {
+		result, err = peer.AppendEntries(args)
+		return err
+	})
+	return result, err
+}
+
+// InstallSnapshot is part of the raft.Transport interface.
+func (t *transport) InstallSnapshot(id string, args raft.InstallSnapshotArgs) (result raft.InstallSnapshotResult, err error) {
+	err = t.call(id, func(peer Peer) (err error) {
+		result, err = peer.InstallSnapshot(args)
+		return err
+	})
+	return result, err
+}
+
+// Propose is part of the raftlease.Forwarder interface.
+func (t *transport) Propose(id string, command []byte) (result []byte, err error) {
+	err = t.call(id, func(peer Peer) (err error) {
+		result, err = peer.Propose(command)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,220 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package raftlease provides a worker which runs this state server's
+// member of the raft cluster holding the leases, and attaches it to
+// the lease manager.
+package raftlease
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/raft"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.raftlease")
+
+var (
+	// heartbeatInterval is how often the leader contacts the
+	// other state servers.
+	heartbeatInterval = 250 * time.Millisecond
+
+	// electionTimeout is how long a state server waits to hear
+	// from the leader before standing for election.
+	electionTimeout = 2 * time.Second
+
+	// snapshotThreshold is the number of lease changes after
+	// which the raft log is compacted.
+	snapshotThreshold uint64 = 1000
+)
+
+// State is the part of *state.State used by the worker.
+type State interface {
+	StateServerInfo() (*state.StateServerInfo, error)
+	WatchStateServerInfo() state.NotifyWatcher
+	Machine(id string) (Machine, error)
+}
+
+// Machine is the part of *state.Machine used by the worker.
+type Machine interface {
+	Addresses() []network.Address
+}
+
+// Config holds the configuration of the worker.
+type Config struct {
+	State     State
+	MachineId string
+
+	// DataDir is the directory in which the raft state is kept.
+	DataDir string
+
+	// APIPort is the port on which the state servers serve the API.
+	APIPort int
+
+	// Dial connects to the API server at the given address.
+	Dial DialFunc
+}
+
+// New returns a worker which runs a raft node for this state server,
+// with the other state servers as its peers. The node is restarted
+// whenever the set of state servers changes.
+func New(config Config) worker.Worker {
+	w := &raftLeaseWorker{
+		config:    config,
+		transport: newTransport(config.Dial),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer w.transport.close()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+type raftLeaseWorker struct {
+	tomb      tomb.Tomb
+	config    Config
+	transport *transport
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *raftLeaseWorker) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *raftLeaseWorker) Wait() error {
+	return w.tomb.Wait()
+}
+
+func (w *raftLeaseWorker) loop() error {
+	sw := w.config.State.WatchStateServerInfo()
+	defer watcher.Stop(sw, &w.tomb)
+
+	var node *runningNode
+	defer func() {
+		if node != nil {
+			if err := node.stop(); err != nil {
+				w.tomb.Kill(err)
+			}
+		}
+	}()
+	var nodeDead <-chan error
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case err := <-nodeDead:
+			node.detach()
+			node = nil
+			return errors.Annotate(err, "raft node died")
+		case _, ok := <-sw.Changes():
+			if !ok {
+				return watcher.EnsureErr(sw)
+			}
+		}
+		peers, err := w.peerAddresses()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		w.transport.setAddresses(peers)
+		ids := make([]string, 0, len(peers))
+		for id := range peers {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if node != nil && reflect.DeepEqual(node.peers, ids) {
+			continue
+		}
+		if node != nil {
+			logger.Infof("state servers changed; restarting raft node")
+			if err := node.stop(); err != nil {
+				return errors.Trace(err)
+			}
+			node = nil
+		}
+		node, err = w.startNode(ids)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		nodeDead = node.dead
+	}
+}
+
+// peerAddresses returns the API addresses of the other state servers,
+// keyed by machine id.
+func (w *raftLeaseWorker) peerAddresses() (map[string]string, error) {
+	info, err := w.config.State.StateServerInfo()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	peers := make(map[string]string)
+	for _, id := range info.MachineIds {
+		if id == w.config.MachineId {
+			continue
+		}
+		m, err := w.config.State.Machine(id)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		hostPorts := network.AddressesWithPort(m.Addresses(), w.config.APIPort)
+		peers[id] = network.SelectInternalHostPort(hostPorts, false)
+	}
+	return peers, nil
+}
+
+// runningNode holds a raft node attached to the lease manager.
+type runningNode struct {
+	node   *raft.Node
+	peers  []string
+	detach func()
+	dead   chan error
+}
+
+func (w *raftLeaseWorker) startNode(peers []string) (*runningNode, error) {
+	manager := raftlease.Manager()
+	fsm := raftlease.NewFSM(manager.Released)
+	node, err := raft.NewNode(raft.Config{
+		Id:                w.config.MachineId,
+		Peers:             peers,
+		FSM:               fsm,
+		Transport:         w.transport,
+		Store:             raft.NewFileStore(filepath.Join(w.config.DataDir, "raft-lease.json")),
+		HeartbeatInterval: heartbeatInterval,
+		ElectionTimeout:   electionTimeout,
+		SnapshotThreshold: snapshotThreshold,
+	})
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot start raft node")
+	}
+	logger.Infof("started raft node %q with peers %v", w.config.MachineId, peers)
+	rn := &runningNode{
+		node:   node,
+		peers:  peers,
+		detach: manager.Attach(node, fsm, w.transport),
+		dead:   make(chan error, 1),
+	}
+	go func() {
+		rn.dead <- node.Wait()
+	}()
+	return rn, nil
+}
+
+func (rn *runningNode) stop() error {
+	rn.detach()
+	return worker.Stop(rn.node)
+}
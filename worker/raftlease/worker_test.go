@@ -0,0 +1,201 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package raftlease_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/lease/raftlease"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	raftleaseworker "github.com/juju/juju/worker/raftlease"
+)
+
+type WorkerSuite struct {
+	coretesting.BaseSuite
+
+	st     *fakeState
+	dialer *fakeDialer
+}
+
+var _ = gc.Suite(&WorkerSuite{})
+
+func (s *WorkerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(raftleaseworker.HeartbeatInterval, 5*time.Millisecond)
+	s.PatchValue(raftleaseworker.ElectionTimeout, 20*time.Millisecond)
+	s.st = &fakeState{
+		changes:    make(chan struct{}, 1),
+		machineIds: []string{"0"},
+		addresses: map[string][]network.Address{
+			"0": network.NewAddresses("10.0.0.10"),
+			"1": network.NewAddresses("10.0.0.11"),
+		},
+	}
+	s.dialer = &fakeDialer{}
+}
+
+func (s *WorkerSuite) startWorker(c *gc.C) worker.Worker {
+	w := raftleaseworker.New(raftleaseworker.Config{
+		State:     s.st,
+		MachineId: "0",
+		DataDir:   c.MkDir(),
+		APIPort:   17070,
+		Dial:      s.dialer.dial,
+	})
+	s.AddCleanup(func(c *gc.C) {
+		worker.Stop(w)
+	})
+	return w
+}
+
+// waitNode waits for a node other than the given one to be attached to
+// the lease manager, and returns it.
+func waitNode(c *gc.C, not raftlease.Node) raftlease.Node {
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		node, err := raftlease.Manager().Node()
+		if err == nil && node != not {
+			return node
+		}
+	}
+	c.Fatalf("timed out waiting for raft node")
+	panic("unreachable")
+}
+
+func (s *WorkerSuite) TestSingleStateServer(c *gc.C) {
+	s.st.changes <- struct{}{}
+	w := s.startWorker(c)
+	waitNode(c, nil)
+
+	owner, err := raftlease.Manager().ClaimLease("ns", "a", time.Minute)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(owner, gc.Equals, "a")
+
+	c.Assert(worker.Stop(w), jc.ErrorIsNil)
+	_, err = raftlease.Manager().Node()
+	c.Assert(err, gc.Equals, raftlease.ErrNotRunning)
+}
+
+func (s *WorkerSuite) TestStateServersChanged(c *gc.C) {
+	s.st.changes <- struct{}{}
+	s.startWorker(c)
+	node := waitNode(c, nil)
+
+	// A change which doesn't affect the peers leaves the node running.
+	s.st.changes <- struct{}{}
+	time.Sleep(coretesting.ShortWait)
+	current, err := raftlease.Manager().Node()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(current, gc.Equals, node)
+
+	// Adding a state server restarts the node with it as a peer,
+	// which the node then contacts.
+	s.st.setMachineIds("0", "1")
+	s.st.changes <- struct{}{}
+	waitNode(c, node)
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if addrs := s.dialer.addresses(); len(addrs) > 0 {
+			c.Assert(addrs[0], gc.Equals, "10.0.0.11:17070")
+			return
+		}
+	}
+	c.Fatalf("raft peer never dialled")
+}
+
+func (s *WorkerSuite) TestWatcherError(c *gc.C) {
+	s.st.watchErr = errors.New("watcher failed")
+	w := s.startWorker(c)
+	close(s.st.changes)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, "watcher failed")
+}
+
+type fakeState struct {
+	changes  chan struct{}
+	watchErr error
+
+	mu         sync.Mutex
+	machineIds []string
+	addresses  map[string][]network.Address
+}
+
+func (st *fakeState) setMachineIds(ids ...string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.machineIds = ids
+}
+
+func (st *fakeState) StateServerInfo() (*state.StateServerInfo, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return &state.StateServerInfo{
+		MachineIds:       st.machineIds,
+		VotingMachineIds: st.machineIds,
+	}, nil
+}
+
+func (st *fakeState) WatchStateServerInfo() state.NotifyWatcher {
+	return &mockNotifyWatcher{st.changes, st.watchErr}
+}
+
+func (st *fakeState) Machine(id string) (raftleaseworker.Machine, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return fakeMachine(st.addresses[id]), nil
+}
+
+type fakeMachine []network.Address
+
+func (m fakeMachine) Addresses() []network.Address {
+	return m
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+	err     error
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Kill() {}
+
+func (*mockNotifyWatcher) Wait() error {
+	return nil
+}
+
+func (w *mockNotifyWatcher) Err() error {
+	return w.err
+}
+
+// fakeDialer records the addresses dialled, and fails to connect.
+type fakeDialer struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (d *fakeDialer) dial(addr string) (raftleaseworker.Peer, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addrs = append(d.addrs, addr)
+	return nil, errors.New("no route to host")
+}
+
+func (d *fakeDialer) addresses() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.addrs...)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/juju/juju/api/reboot"
 	"github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/worker"
 )
 
@@ -20,27 +21,42 @@ const RebootMessage = "preparing for reboot"
 
 var _ worker.NotifyWatchHandler = (*Reboot)(nil)
 
+// RebootAcknowledger is used by a container to let its host know that
+// it has stopped running hooks and is ready for the host to reboot.
+type RebootAcknowledger interface {
+	AcknowledgeReboot() error
+}
+
 // The reboot worker listens for changes to the reboot flag and
 // exists with worker.ErrRebootMachine if the machine should reboot or
 // with worker.ErrShutdownMachine if it should shutdown. This will be picked
 // up by the machine agent as a fatal error and will do the
-// right thing (reboot or shutdown)
+// right thing (reboot or shutdown). Before exiting, a pubsub.RebootPending
+// event is published so that other workers in the agent can prepare.
+//
+// When shutting down because its host is about to reboot, the worker
+// first takes the hook execution lock, so no more hooks can run, and
+// then acknowledges the reboot so that the host need not wait for it.
 type Reboot struct {
-	tomb        tomb.Tomb
-	st          *reboot.State
-	tag         names.MachineTag
-	machineLock *fslock.Lock
+	tomb         tomb.Tomb
+	st           *reboot.State
+	acknowledger RebootAcknowledger
+	tag          names.MachineTag
+	machineLock  *fslock.Lock
+	publisher    pubsub.Publisher
 }
 
-func NewReboot(st *reboot.State, agentConfig agent.Config, machineLock *fslock.Lock) (worker.Worker, error) {
+func NewReboot(st *reboot.State, acknowledger RebootAcknowledger, agentConfig agent.Config, machineLock *fslock.Lock, publisher pubsub.Publisher) (worker.Worker, error) {
 	tag, ok := agentConfig.Tag().(names.MachineTag)
 	if !ok {
 		return nil, errors.Errorf("Expected names.MachineTag, got %T: %v", agentConfig.Tag(), agentConfig.Tag())
 	}
 	r := &Reboot{
-		st:          st,
-		tag:         tag,
-		machineLock: machineLock,
+		st:           st,
+		acknowledger: acknowledger,
+		tag:          tag,
+		machineLock:  machineLock,
+		publisher:    publisher,
 	}
 	return worker.NewNotifyWorker(r), nil
 }
@@ -83,14 +99,45 @@ func (r *Reboot) Handle() error {
 	switch rAction {
 	case params.ShouldReboot:
 		r.machineLock.Lock(RebootMessage)
+		r.publishRebootPending(false)
 		return worker.ErrRebootMachine
 	case params.ShouldShutdown:
 		r.machineLock.Lock(RebootMessage)
+		r.acknowledgeReboot()
+		r.publishRebootPending(true)
 		return worker.ErrShutdownMachine
 	}
 	return nil
 }
 
+// acknowledgeReboot lets the host know that this machine is no longer
+// running hooks. Failure is not fatal: the host will stop waiting for
+// the acknowledgement after a timeout.
+func (r *Reboot) acknowledgeReboot() {
+	if r.acknowledger == nil {
+		return
+	}
+	if err := r.acknowledger.AcknowledgeReboot(); err != nil {
+		logger.Warningf("cannot acknowledge reboot: %v", err)
+	}
+}
+
+// publishRebootPending lets other workers in the agent know that the
+// machine is about to be rebooted or shut down. Failure to publish is
+// not fatal, as the reboot must go ahead regardless.
+func (r *Reboot) publishRebootPending(shutdown bool) {
+	if r.publisher == nil {
+		return
+	}
+	err := r.publisher.Publish(pubsub.RebootPending, pubsub.RebootPendingEvent{
+		MachineTag: r.tag.String(),
+		Shutdown:   shutdown,
+	})
+	if err != nil {
+		logger.Warningf("cannot publish reboot pending event: %v", err)
+	}
+}
+
 func (r *Reboot) TearDown() error {
 	// nothing to teardown.
 	return nil
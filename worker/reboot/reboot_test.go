@@ -2,6 +2,7 @@ package reboot_test
 
 import (
 	stdtesting "testing"
+	"time"
 
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
@@ -10,8 +11,10 @@ import (
 
 	"github.com/juju/juju/api"
 	apireboot "github.com/juju/juju/api/reboot"
+	apirebootrequests "github.com/juju/juju/api/rebootrequests"
 	"github.com/juju/juju/instance"
 	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/pubsub"
 	"github.com/juju/juju/state"
 	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/worker"
@@ -35,8 +38,9 @@ type rebootSuite struct {
 	stateAPI    *api.State
 	rebootState *apireboot.State
 
-	ct            *state.Machine
-	ctRebootState *apireboot.State
+	ct                    *state.Machine
+	ctRebootState         *apireboot.State
+	ctRebootRequestsState *apirebootrequests.State
 
 	lock       *fslock.Lock
 	lockReboot *fslock.Lock
@@ -74,6 +78,8 @@ func (s *rebootSuite) SetUpTest(c *gc.C) {
 	s.ctRebootState, err = ctState.Reboot()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(s.ctRebootState, gc.NotNil)
+	s.ctRebootRequestsState, err = ctState.RebootRequests()
+	c.Assert(err, jc.ErrorIsNil)
 
 	lock, err := fslock.NewLock(c.MkDir(), "fake")
 	c.Assert(err, jc.ErrorIsNil)
@@ -85,32 +91,62 @@ func (s *rebootSuite) TearDownTest(c *gc.C) {
 }
 
 func (s *rebootSuite) TestStartStop(c *gc.C) {
-	worker, err := reboot.NewReboot(s.rebootState, s.AgentConfigForTag(c, s.machine.Tag()), s.lock)
+	worker, err := reboot.NewReboot(s.rebootState, nil, s.AgentConfigForTag(c, s.machine.Tag()), s.lock, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	worker.Kill()
 	c.Assert(worker.Wait(), gc.IsNil)
 }
 
 func (s *rebootSuite) TestWorkerCatchesRebootEvent(c *gc.C) {
-	wrk, err := reboot.NewReboot(s.rebootState, s.AgentConfigForTag(c, s.machine.Tag()), s.lock)
+	wrk, err := reboot.NewReboot(s.rebootState, nil, s.AgentConfigForTag(c, s.machine.Tag()), s.lock, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	err = s.rebootState.RequestReboot()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(wrk.Wait(), gc.Equals, worker.ErrRebootMachine)
 }
 
+func (s *rebootSuite) TestWorkerPublishesRebootPending(c *gc.C) {
+	hub := pubsub.NewHub(pubsub.DefaultHistorySize)
+	events := make(chan pubsub.Event, 1)
+	unsubscribe, err := hub.Subscribe(pubsub.RebootPending, func(e pubsub.Event) {
+		events <- e
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer unsubscribe()
+
+	wrk, err := reboot.NewReboot(s.rebootState, nil, s.AgentConfigForTag(c, s.machine.Tag()), s.lock, hub)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.rebootState.RequestReboot()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(wrk.Wait(), gc.Equals, worker.ErrRebootMachine)
+
+	select {
+	case e := <-events:
+		c.Assert(e.Data, jc.DeepEquals, pubsub.RebootPendingEvent{
+			MachineTag: s.machine.Tag().String(),
+		})
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("reboot pending event not published")
+	}
+}
+
 func (s *rebootSuite) TestContainerCatchesParentFlag(c *gc.C) {
-	wrk, err := reboot.NewReboot(s.ctRebootState, s.AgentConfigForTag(c, s.ct.Tag()), s.lock)
+	wrk, err := reboot.NewReboot(s.ctRebootState, s.ctRebootRequestsState, s.AgentConfigForTag(c, s.ct.Tag()), s.lock, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	err = s.rebootState.RequestReboot()
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(wrk.Wait(), gc.Equals, worker.ErrShutdownMachine)
+
+	// The container acknowledged the reboot before shutting down.
+	pending, err := s.machine.PendingRebootAcknowledgements()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pending, gc.HasLen, 0)
 }
 
 func (s *rebootSuite) TestCleanupIsDoneOnBoot(c *gc.C) {
 	s.lock.Lock(reboot.RebootMessage)
 
-	wrk, err := reboot.NewReboot(s.rebootState, s.AgentConfigForTag(c, s.machine.Tag()), s.lock)
+	wrk, err := reboot.NewReboot(s.rebootState, nil, s.AgentConfigForTag(c, s.machine.Tag()), s.lock, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	wrk.Kill()
 	c.Assert(wrk.Wait(), gc.IsNil)
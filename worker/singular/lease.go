@@ -0,0 +1,150 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package singular
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/lease"
+)
+
+// LeaseDuration is the length of the lease claimed by the master of a
+// LeaseConn. The lease is extended on every ping, so it must be
+// comfortably longer than PingInterval.
+var LeaseDuration = time.Minute
+
+// leasePrefix prefixes the lease namespaces used by environment
+// singular runners.
+const leasePrefix = "singular#"
+
+// LeaseNamespace returns the lease namespace used to choose the state
+// server which runs the singular workers of the given environment.
+func LeaseNamespace(envUUID string) string {
+	return leasePrefix + envUUID
+}
+
+// LeaseEnvironUUID returns the UUID of the environment whose singular
+// workers are coordinated by the given lease namespace, and whether the
+// namespace is one returned by LeaseNamespace.
+func LeaseEnvironUUID(namespace string) (string, bool) {
+	if !strings.HasPrefix(namespace, leasePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(namespace, leasePrefix), true
+}
+
+// LeaseManager holds the methods of the lease manager used by
+// a LeaseConn.
+type LeaseManager interface {
+	ClaimLease(namespace, id string, forDur time.Duration) (string, error)
+	RetrieveLease(namespace string) lease.Token
+}
+
+// LeaseConn implements Conn by claiming a lease. The holder which
+// successfully claims the lease is master until it fails to extend it;
+// other holders are on standby until the lease passes to another
+// holder.
+type LeaseConn struct {
+	manager   LeaseManager
+	namespace string
+	holder    string
+
+	mu       sync.Mutex
+	isMaster bool
+	owner    string
+}
+
+// NewLeaseConn returns a LeaseConn which competes for the lease in the
+// given namespace on behalf of the given holder.
+func NewLeaseConn(manager LeaseManager, namespace, holder string) *LeaseConn {
+	return &LeaseConn{
+		manager:   manager,
+		namespace: namespace,
+		holder:    holder,
+	}
+}
+
+// Namespace returns the namespace of the lease claimed by the
+// connection.
+func (c *LeaseConn) Namespace() string {
+	return c.namespace
+}
+
+// IsMaster implements Conn.IsMaster by trying to claim the lease.
+func (c *LeaseConn) IsMaster() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	owner, err := c.manager.ClaimLease(c.namespace, c.holder, LeaseDuration)
+	switch {
+	case err == nil:
+		c.isMaster = true
+	case errors.Cause(err) == lease.LeaseClaimDeniedErr:
+		c.isMaster = false
+	default:
+		return false, errors.Trace(err)
+	}
+	c.owner = owner
+	return c.isMaster, nil
+}
+
+// Ping implements Conn.Ping. The master extends its lease, and fails if
+// it cannot; a standby fails when the lease has passed to a holder other
+// than the one it saw when IsMaster was called, including when the
+// lease has expired.
+func (c *LeaseConn) Ping() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isMaster {
+		if _, err := c.manager.ClaimLease(c.namespace, c.holder, LeaseDuration); err != nil {
+			return errors.Annotatef(err, "cannot extend lease %q", c.namespace)
+		}
+		return nil
+	}
+	if owner := c.manager.RetrieveLease(c.namespace).Id; owner != c.owner {
+		return errors.Errorf("lease %q passed from %q to %q", c.namespace, c.owner, owner)
+	}
+	return nil
+}
+
+var leaseWorkers = struct {
+	mu      sync.Mutex
+	workers map[string]map[string]bool
+}{
+	workers: make(map[string]map[string]bool),
+}
+
+// registerWorker records that the worker with the given id is run
+// singularly under the lease in the given namespace.
+func registerWorker(namespace, id string) {
+	leaseWorkers.mu.Lock()
+	defer leaseWorkers.mu.Unlock()
+	ids := leaseWorkers.workers[namespace]
+	if ids == nil {
+		ids = make(map[string]bool)
+		leaseWorkers.workers[namespace] = ids
+	}
+	ids[id] = true
+}
+
+// LeaseWorkers returns the ids of the workers started in this process on
+// runners using a LeaseConn, keyed by lease namespace. Every state
+// server starts the same workers, so this describes the workers run by
+// whichever state server holds each lease.
+func LeaseWorkers() map[string][]string {
+	leaseWorkers.mu.Lock()
+	defer leaseWorkers.mu.Unlock()
+	result := make(map[string][]string)
+	for namespace, ids := range leaseWorkers.workers {
+		for id := range ids {
+			result[namespace] = append(result[namespace], id)
+		}
+		sort.Strings(result[namespace])
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package singular_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/lease"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/singular"
+)
+
+type leaseSuite struct {
+	testing.BaseSuite
+	manager *fakeLeaseManager
+}
+
+var _ = gc.Suite(&leaseSuite{})
+
+func (s *leaseSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.manager = &fakeLeaseManager{owners: make(map[string]string)}
+}
+
+func (s *leaseSuite) TestLeaseNamespace(c *gc.C) {
+	namespace := singular.LeaseNamespace("deadbeef")
+	c.Assert(namespace, gc.Equals, "singular#deadbeef")
+	uuid, ok := singular.LeaseEnvironUUID(namespace)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(uuid, gc.Equals, "deadbeef")
+	_, ok = singular.LeaseEnvironUUID("service-leadership")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *leaseSuite) TestMaster(c *gc.C) {
+	conn := singular.NewLeaseConn(s.manager, "ns", "0")
+	isMaster, err := conn.IsMaster()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isMaster, jc.IsTrue)
+	c.Assert(conn.Ping(), jc.ErrorIsNil)
+	c.Assert(s.manager.claims, gc.Equals, 2)
+
+	// A master which loses its lease fails to ping.
+	s.manager.setOwner("ns", "1")
+	c.Assert(conn.Ping(), gc.ErrorMatches, `cannot extend lease "ns": lease claim denied`)
+}
+
+func (s *leaseSuite) TestStandby(c *gc.C) {
+	s.manager.setOwner("ns", "1")
+	conn := singular.NewLeaseConn(s.manager, "ns", "0")
+	isMaster, err := conn.IsMaster()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(isMaster, jc.IsFalse)
+	c.Assert(conn.Ping(), jc.ErrorIsNil)
+
+	// A standby fails to ping once the lease has expired.
+	s.manager.setOwner("ns", "")
+	c.Assert(conn.Ping(), gc.ErrorMatches, `lease "ns" passed from "1" to ""`)
+}
+
+func (s *leaseSuite) TestClaimError(c *gc.C) {
+	s.manager.err = errors.New("lease service not running")
+	conn := singular.NewLeaseConn(s.manager, "ns", "0")
+	_, err := conn.IsMaster()
+	c.Assert(err, gc.ErrorMatches, "lease service not running")
+}
+
+func (s *leaseSuite) TestLeaseWorkers(c *gc.C) {
+	for _, holder := range []string{"0", "1"} {
+		conn := singular.NewLeaseConn(s.manager, "lease-workers", holder)
+		r, err := singular.New(newRunner(), conn)
+		c.Assert(err, jc.ErrorIsNil)
+		for _, id := range []string{"b", "a"} {
+			err := r.StartWorker(id, func() (worker.Worker, error) {
+				return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+					<-stop
+					return nil
+				}), nil
+			})
+			c.Assert(err, jc.ErrorIsNil)
+		}
+		c.Assert(worker.Stop(r), jc.ErrorIsNil)
+	}
+	c.Assert(singular.LeaseWorkers()["lease-workers"], jc.DeepEquals, []string{"a", "b"})
+}
+
+type fakeLeaseManager struct {
+	mu     sync.Mutex
+	owners map[string]string
+	claims int
+	err    error
+}
+
+func (m *fakeLeaseManager) setOwner(namespace, owner string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.owners[namespace] = owner
+}
+
+func (m *fakeLeaseManager) ClaimLease(namespace, id string, forDur time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return "", m.err
+	}
+	m.claims++
+	if owner := m.owners[namespace]; owner != "" && owner != id {
+		return owner, lease.LeaseClaimDeniedErr
+	}
+	m.owners[namespace] = id
+	return id, nil
+}
+
+func (m *fakeLeaseManager) RetrieveLease(namespace string) lease.Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return lease.Token{Namespace: namespace, Id: m.owners[namespace]}
+}
@@ -43,7 +43,8 @@ type Conn interface {
 // run the workers or not.
 //
 // If conn.IsMaster returns true, any workers started will be started on the
-// underlying runner.
+// underlying runner, and will exit with the ping error if a ping of the
+// connection fails.
 //
 // If conn.IsMaster returns false, any workers started will actually
 // start do-nothing placeholder workers on the underlying runner
@@ -93,20 +94,31 @@ func (r *runner) pinger() {
 }
 
 func (r *runner) StartWorker(id string, startFunc func() (worker.Worker, error)) error {
+	// Whether or not we are master, start a pinger so that we know
+	// when the connection master changes.
+	r.startPingerOnce.Do(func() {
+		go r.pinger()
+	})
+	if conn, ok := r.conn.(*LeaseConn); ok {
+		registerWorker(conn.Namespace(), id)
+	}
 	if r.isMaster {
-		// We are master; the started workers should
-		// encounter an error as they do what they're supposed
-		// to do - we can just start the worker in the
-		// underlying runner.
+		// We are master; start the worker in the underlying
+		// runner. The connection may stop being master
+		// without the worker noticing, so it is also stopped
+		// when the pinger dies.
 		logger.Infof("starting %q", id)
-		return r.Runner.StartWorker(id, startFunc)
+		return r.Runner.StartWorker(id, func() (worker.Worker, error) {
+			w, err := startFunc()
+			if err != nil {
+				return nil, err
+			}
+			return r.newPingedWorker(w), nil
+		})
 	}
 	logger.Infof("standby %q", id)
-	// We're not master, so don't start the worker, but start a pinger so
-	// that we know when the connection master changes.
-	r.startPingerOnce.Do(func() {
-		go r.pinger()
-	})
+	// We're not master, so don't start the worker, but wait for the
+	// pinger to die.
 	return r.Runner.StartWorker(id, func() (worker.Worker, error) {
 		return worker.NewSimpleWorker(r.waitPinger), nil
 	})
@@ -120,3 +132,41 @@ func (r *runner) waitPinger(stop <-chan struct{}) error {
 		return r.pingErr
 	}
 }
+
+// pingedWorker wraps a worker started by the master so that it is
+// stopped when the pinger dies.
+type pingedWorker struct {
+	worker.Worker
+	done chan struct{}
+	err  error
+}
+
+func (r *runner) newPingedWorker(w worker.Worker) worker.Worker {
+	pw := &pingedWorker{
+		Worker: w,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(pw.done)
+		waitErr := make(chan error, 1)
+		go func() {
+			waitErr <- w.Wait()
+		}()
+		select {
+		case pw.err = <-waitErr:
+		case <-r.pingerDied:
+			w.Kill()
+			if err := <-waitErr; err != nil {
+				logger.Debugf("worker stopped after ping failure: %v", err)
+			}
+			pw.err = r.pingErr
+		}
+	}()
+	return pw
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *pingedWorker) Wait() error {
+	<-w.done
+	return w.err
+}
@@ -66,6 +66,40 @@ func (s *singularSuite) TestWithIsMasterTrue(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *singularSuite) TestWithIsMasterTruePingFailure(c *gc.C) {
+	// When IsMaster returns true, the started workers are stopped
+	// with the ping error once a ping fails.
+	s.PatchValue(&singular.PingInterval, testing.ShortWait/10)
+	underlyingRunner := newRunner()
+	conn := &fakeConn{
+		isMaster: true,
+		pinged:   make(chan struct{}, 1),
+	}
+	r, err := singular.New(underlyingRunner, conn)
+	c.Assert(err, jc.ErrorIsNil)
+
+	started := make(chan struct{}, 1)
+	err = r.StartWorker("worker", func() (worker.Worker, error) {
+		return worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+			started <- struct{}{}
+			<-stop
+			return nil
+		}), nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case <-started:
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for worker to start")
+	}
+
+	conn.setPingErr(errFatal)
+	runWithTimeout(c, "wait for underlying runner", func() {
+		err = underlyingRunner.Wait()
+	})
+	c.Assert(err, gc.Equals, errFatal)
+}
+
 var errFatal = fmt.Errorf("fatal error")
 
 func (s *singularSuite) TestWithIsMasterFalse(c *gc.C) {
@@ -0,0 +1,131 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package suspendgate provides a worker which runs another worker only
+// while the environment is not suspended, so that workers which change
+// the environment's instances, ports or charms pause during a
+// suspension, for example while the provider is unavailable.
+package suspendgate
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.suspendgate")
+
+// API reports whether the environment is suspended, and watches for
+// changes to it.
+type API interface {
+	WatchEnvironment() (apiwatcher.NotifyWatcher, error)
+	EnvironmentSuspended() (bool, string, error)
+}
+
+// New returns a worker which starts the named worker using start
+// whenever the environment is not suspended, and stops it while the
+// environment is suspended. If the named worker fails, so does the
+// returned worker.
+func New(api API, name string, start func() (worker.Worker, error)) worker.Worker {
+	g := &gate{
+		api:   api,
+		name:  name,
+		start: start,
+	}
+	go func() {
+		defer g.tomb.Done()
+		g.tomb.Kill(g.loop())
+	}()
+	return g
+}
+
+type gate struct {
+	tomb  tomb.Tomb
+	api   API
+	name  string
+	start func() (worker.Worker, error)
+
+	// inner holds the gated worker while it is running.
+	inner worker.Worker
+}
+
+// Kill is part of the worker.Worker interface.
+func (g *gate) Kill() {
+	g.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (g *gate) Wait() error {
+	return g.tomb.Wait()
+}
+
+func (g *gate) loop() (err error) {
+	w, err := g.api.WatchEnvironment()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch environment")
+	}
+	defer watcher.Stop(w, &g.tomb)
+	defer func() {
+		if stopErr := g.stopInner(); stopErr != nil && (err == nil || err == tomb.ErrDying) {
+			err = stopErr
+		}
+	}()
+
+	// innerDone receives the result of the gated worker once it exits.
+	var innerDone chan error
+	for {
+		select {
+		case <-g.tomb.Dying():
+			return tomb.ErrDying
+		case err := <-innerDone:
+			g.inner = nil
+			if err == nil {
+				err = errors.Errorf("%s worker stopped", g.name)
+			}
+			return errors.Trace(err)
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+		}
+		suspended, reason, err := g.api.EnvironmentSuspended()
+		if err != nil {
+			return errors.Annotate(err, "cannot get environment suspension")
+		}
+		switch {
+		case suspended && g.inner != nil:
+			logger.Infof("environment suspended (%s): stopping %s worker", reason, g.name)
+			innerDone = nil
+			if err := g.stopInner(); err != nil {
+				return errors.Trace(err)
+			}
+		case suspended:
+			logger.Infof("environment suspended (%s): not starting %s worker", reason, g.name)
+		case g.inner == nil:
+			logger.Infof("starting %s worker", g.name)
+			inner, err := g.start()
+			if err != nil {
+				return errors.Annotatef(err, "cannot start %s worker", g.name)
+			}
+			g.inner = inner
+			innerDone = make(chan error, 1)
+			go func(done chan<- error) {
+				done <- inner.Wait()
+			}(innerDone)
+		}
+	}
+}
+
+// stopInner stops the gated worker, if it is running.
+func (g *gate) stopInner() error {
+	if g.inner == nil {
+		return nil
+	}
+	err := worker.Stop(g.inner)
+	g.inner = nil
+	return errors.Annotatef(err, "%s worker failed", g.name)
+}
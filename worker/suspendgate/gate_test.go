@@ -0,0 +1,187 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package suspendgate_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/suspendgate"
+)
+
+type gateSuite struct {
+	coretesting.BaseSuite
+	api     *mockAPI
+	started chan *innerWorker
+}
+
+var _ = gc.Suite(&gateSuite{})
+
+func (s *gateSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{changes: make(chan struct{}, 1)}
+	s.started = make(chan *innerWorker, 10)
+}
+
+func (s *gateSuite) newGate(c *gc.C) worker.Worker {
+	return suspendgate.New(s.api, "test", func() (worker.Worker, error) {
+		w := newInnerWorker()
+		s.started <- w
+		return w, nil
+	})
+}
+
+func (s *gateSuite) assertStarted(c *gc.C) *innerWorker {
+	select {
+	case w := <-s.started:
+		return w
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for worker to start")
+	}
+	panic("unreachable")
+}
+
+func (s *gateSuite) assertNotStarted(c *gc.C) {
+	select {
+	case <-s.started:
+		c.Fatalf("unexpected worker start")
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func assertStopped(c *gc.C, w *innerWorker) {
+	select {
+	case <-w.stopped:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for worker to stop")
+	}
+}
+
+func (s *gateSuite) TestStartsWorkerWhenNotSuspended(c *gc.C) {
+	g := s.newGate(c)
+	s.api.changes <- struct{}{}
+	inner := s.assertStarted(c)
+
+	c.Assert(worker.Stop(g), jc.ErrorIsNil)
+	assertStopped(c, inner)
+}
+
+func (s *gateSuite) TestStopsWorkerWhileSuspended(c *gc.C) {
+	g := s.newGate(c)
+	defer func() { c.Check(worker.Stop(g), jc.ErrorIsNil) }()
+	s.api.changes <- struct{}{}
+	inner := s.assertStarted(c)
+
+	s.api.setSuspended(true)
+	s.api.changes <- struct{}{}
+	assertStopped(c, inner)
+	s.api.changes <- struct{}{}
+	s.assertNotStarted(c)
+
+	s.api.setSuspended(false)
+	s.api.changes <- struct{}{}
+	s.assertStarted(c)
+}
+
+func (s *gateSuite) TestDoesNotStartWhileSuspended(c *gc.C) {
+	s.api.setSuspended(true)
+	g := s.newGate(c)
+	defer func() { c.Check(worker.Stop(g), jc.ErrorIsNil) }()
+	s.api.changes <- struct{}{}
+	s.assertNotStarted(c)
+}
+
+func (s *gateSuite) TestWorkerFailure(c *gc.C) {
+	g := s.newGate(c)
+	s.api.changes <- struct{}{}
+	inner := s.assertStarted(c)
+
+	inner.fail <- errors.New("boom")
+	err := g.Wait()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *gateSuite) TestWatchError(c *gc.C) {
+	s.api.watchErr = errors.New("boom")
+	g := s.newGate(c)
+	err := g.Wait()
+	c.Assert(err, gc.ErrorMatches, "cannot watch environment: boom")
+}
+
+type mockAPI struct {
+	mu        sync.Mutex
+	suspended bool
+	changes   chan struct{}
+	watchErr  error
+}
+
+func (api *mockAPI) setSuspended(suspended bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.suspended = suspended
+}
+
+func (api *mockAPI) WatchEnvironment() (apiwatcher.NotifyWatcher, error) {
+	if api.watchErr != nil {
+		return nil, api.watchErr
+	}
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) EnvironmentSuspended() (bool, string, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.suspended {
+		return true, "provider outage", nil
+	}
+	return false, "", nil
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}
+
+// innerWorker is a worker which runs until it is killed, or until an
+// error is sent on its fail channel.
+type innerWorker struct {
+	worker.Worker
+	fail    chan error
+	stopped chan struct{}
+}
+
+func newInnerWorker() *innerWorker {
+	w := &innerWorker{
+		fail:    make(chan error, 1),
+		stopped: make(chan struct{}),
+	}
+	w.Worker = worker.NewSimpleWorker(func(stop <-chan struct{}) error {
+		defer close(w.stopped)
+		select {
+		case <-stop:
+			return nil
+		case err := <-w.fail:
+			return err
+		}
+	})
+	return w
+}
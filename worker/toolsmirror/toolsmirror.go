@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The toolsmirror package implements a worker which copies the tools
+// for newer patch releases into the environment's tools storage ahead
+// of any upgrade, so that agents can be upgraded without the state
+// servers needing to fetch the tools from simplestreams on demand.
+package toolsmirror
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs"
+	envtools "github.com/juju/juju/environs/tools"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/toolstorage"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.toolsmirror")
+
+// interval is how often the worker looks for new tools to mirror.
+var interval = 6 * time.Hour
+
+var (
+	findTools  = envtools.FindTools
+	fetchTools = envtools.FetchTools
+)
+
+// NewWorker returns a worker which periodically finds the tools in
+// simplestreams with the same major and minor version as, but newer
+// than, the environment's agent version, and stores them in the tools
+// storage of the given state. Only tools for series and architectures
+// for which tools of the current agent version are stored are mirrored.
+func NewWorker(st *state.State) worker.Worker {
+	return worker.NewPeriodicWorker(func(stop <-chan struct{}) error {
+		if err := mirrorTools(st, stop); err != nil {
+			logger.Errorf("cannot mirror tools: %v", err)
+		}
+		return nil
+	}, interval)
+}
+
+type platform struct {
+	series string
+	arch   string
+}
+
+func mirrorTools(st *state.State, stop <-chan struct{}) error {
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	agentVersion, ok := cfg.AgentVersion()
+	if !ok {
+		return errors.New("no agent version set in environment configuration")
+	}
+	if cfg.OfflineMode() {
+		logger.Debugf("environment is offline, not mirroring tools")
+		return nil
+	}
+	stor, err := st.ToolsStorage()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer stor.Close()
+	allMetadata, err := stor.AllMetadata()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	stored := make(map[version.Binary]bool)
+	platforms := make(map[platform]bool)
+	for _, m := range allMetadata {
+		stored[m.Version] = true
+		if m.Version.Number == agentVersion {
+			platforms[platform{m.Version.Series, m.Version.Arch}] = true
+		}
+	}
+	if len(platforms) == 0 {
+		logger.Debugf("no %v tools stored, nothing to mirror", agentVersion)
+		return nil
+	}
+
+	env, err := environs.New(cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	available, err := findTools(env, agentVersion.Major, agentVersion.Minor, coretools.Filter{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot find tools")
+	}
+	for _, tools := range available {
+		if tools.Version.Number.Compare(agentVersion) <= 0 || stored[tools.Version] {
+			continue
+		}
+		if !platforms[platform{tools.Version.Series, tools.Version.Arch}] {
+			continue
+		}
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		data, err := fetchTools(tools)
+		if err != nil {
+			return errors.Annotatef(err, "cannot fetch %v tools", tools.Version)
+		}
+		metadata := toolstorage.Metadata{
+			Version: tools.Version,
+			Size:    tools.Size,
+			SHA256:  tools.SHA256,
+		}
+		if err := stor.AddTools(bytes.NewReader(data), metadata); err != nil {
+			return errors.Annotatef(err, "cannot store %v tools", tools.Version)
+		}
+		logger.Infof("mirrored %v tools", tools.Version)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package toolsmirror_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state/toolstorage"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/toolsmirror"
+)
+
+type mirrorSuite struct {
+	jujutesting.JujuConnSuite
+
+	fetched []version.Binary
+}
+
+var _ = gc.Suite(&mirrorSuite{})
+
+func (s *mirrorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.fetched = nil
+	s.PatchValue(toolsmirror.FetchTools, func(tools *coretools.Tools) ([]byte, error) {
+		s.fetched = append(s.fetched, tools.Version)
+		return []byte(tools.Version.String()), nil
+	})
+}
+
+func (s *mirrorSuite) agentVersion(c *gc.C) version.Number {
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	agentVersion, ok := cfg.AgentVersion()
+	c.Assert(ok, jc.IsTrue)
+	return agentVersion
+}
+
+func (s *mirrorSuite) storeTools(c *gc.C, vers version.Binary) {
+	stor, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer stor.Close()
+	err = stor.AddTools(strings.NewReader(vers.String()), metadata(vers))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *mirrorSuite) assertStored(c *gc.C, vers version.Binary) {
+	stor, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer stor.Close()
+	_, r, err := stor.Tools(vers)
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, vers.String())
+}
+
+func (s *mirrorSuite) assertNotStored(c *gc.C, vers version.Binary) {
+	stor, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer stor.Close()
+	_, err = stor.Metadata(vers)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func metadata(vers version.Binary) toolstorage.Metadata {
+	return toolstorage.Metadata{
+		Version: vers,
+		Size:    int64(len(vers.String())),
+		SHA256:  fmt.Sprintf("%x", sha256.Sum256([]byte(vers.String()))),
+	}
+}
+
+func binary(number version.Number, series, arch string) version.Binary {
+	return version.Binary{Number: number, Series: series, Arch: arch}
+}
+
+func toolsList(versions ...version.Binary) coretools.List {
+	var list coretools.List
+	for _, v := range versions {
+		m := metadata(v)
+		list = append(list, &coretools.Tools{
+			Version: v,
+			URL:     "http://example.com/" + v.String(),
+			Size:    m.Size,
+			SHA256:  m.SHA256,
+		})
+	}
+	return list
+}
+
+func (s *mirrorSuite) TestMirrorsNewerPatchVersions(c *gc.C) {
+	current := s.agentVersion(c)
+	older, newer := current, current
+	older.Minor--
+	newer.Patch++
+	s.storeTools(c, binary(current, "trusty", "amd64"))
+
+	available := toolsList(
+		binary(older, "trusty", "amd64"),
+		binary(current, "trusty", "amd64"),
+		binary(newer, "trusty", "amd64"),
+		binary(newer, "precise", "amd64"),
+		binary(newer, "trusty", "arm64"),
+	)
+	s.PatchValue(toolsmirror.FindTools, func(env environs.Environ, major, minor int, filter coretools.Filter) (coretools.List, error) {
+		c.Check(major, gc.Equals, current.Major)
+		c.Check(minor, gc.Equals, current.Minor)
+		return available, nil
+	})
+
+	err := toolsmirror.MirrorTools(s.State, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fetched, jc.DeepEquals, []version.Binary{binary(newer, "trusty", "amd64")})
+	s.assertStored(c, binary(newer, "trusty", "amd64"))
+	s.assertNotStored(c, binary(newer, "precise", "amd64"))
+	s.assertNotStored(c, binary(newer, "trusty", "arm64"))
+
+	// Mirroring again does not fetch the tools again.
+	s.fetched = nil
+	err = toolsmirror.MirrorTools(s.State, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fetched, gc.HasLen, 0)
+}
+
+func (s *mirrorSuite) TestNothingToMirrorWithoutCurrentTools(c *gc.C) {
+	s.PatchValue(toolsmirror.FindTools, func(environs.Environ, int, int, coretools.Filter) (coretools.List, error) {
+		c.Fatalf("unexpected call to FindTools")
+		return nil, nil
+	})
+	err := toolsmirror.MirrorTools(s.State, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *mirrorSuite) TestNoToolsFound(c *gc.C) {
+	s.storeTools(c, binary(s.agentVersion(c), "trusty", "amd64"))
+	s.PatchValue(toolsmirror.FindTools, func(environs.Environ, int, int, coretools.Filter) (coretools.List, error) {
+		return nil, errors.NotFoundf("tools")
+	})
+	err := toolsmirror.MirrorTools(s.State, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fetched, gc.HasLen, 0)
+}
+
+func (s *mirrorSuite) TestFetchError(c *gc.C) {
+	current := s.agentVersion(c)
+	newer := current
+	newer.Patch++
+	s.storeTools(c, binary(current, "trusty", "amd64"))
+	s.PatchValue(toolsmirror.FindTools, func(environs.Environ, int, int, coretools.Filter) (coretools.List, error) {
+		return toolsList(binary(newer, "trusty", "amd64")), nil
+	})
+	s.PatchValue(toolsmirror.FetchTools, func(*coretools.Tools) ([]byte, error) {
+		return nil, errors.New("hash mismatch")
+	})
+	err := toolsmirror.MirrorTools(s.State, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot fetch .* tools: hash mismatch`)
+	s.assertNotStored(c, binary(newer, "trusty", "amd64"))
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// reportDrain records in state the hooks the dying unit has yet to
+// run before it stops: the relation hooks queued for its departing
+// relations, followed by the stop hook. The unit's machine is not
+// released until the unit has drained, or run out of time to. A drain
+// that has not changed since it was last recorded is not recorded
+// again.
+func (u *Uniter) reportDrain() error {
+	var pending []string
+	for _, hookInfo := range u.relations.QueuedHooks() {
+		pending = append(pending, drainHookName(hookInfo))
+	}
+	pending = append(pending, string(hooks.Stop))
+	if u.reportedDrain != nil && reflect.DeepEqual(u.reportedDrain, pending) {
+		return nil
+	}
+	if err := u.setDrainStatus(pending, false); err != nil {
+		return errors.Trace(err)
+	}
+	u.reportedDrain = pending
+	return nil
+}
+
+// reportDrained records in state that the dying unit has run its stop
+// hook, so its machine need not wait for it any longer.
+func (u *Uniter) reportDrained() error {
+	return u.setDrainStatus(nil, true)
+}
+
+func (u *Uniter) setDrainStatus(pending []string, drained bool) error {
+	err := u.unit.SetDrainStatus(pending, drained)
+	if errors.IsNotImplemented(err) {
+		// The state server does not wait for units to drain.
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot report drain status")
+	}
+	return nil
+}
+
+func drainHookName(hookInfo hook.Info) string {
+	if !hookInfo.Kind.IsRelation() {
+		return string(hookInfo.Kind)
+	}
+	if hookInfo.RemoteUnit == "" {
+		return fmt.Sprintf("%s:%d", hookInfo.Kind, hookInfo.RelationId)
+	}
+	return fmt.Sprintf("%s:%d:%s", hookInfo.Kind, hookInfo.RelationId, hookInfo.RemoteUnit)
+}
@@ -6,6 +6,8 @@ package uniter
 import (
 	"fmt"
 	"time"
+
+	"github.com/juju/utils/fslock"
 )
 
 func SetUniterObserver(u *Uniter, observer UniterExecutionObserver) {
@@ -42,3 +44,26 @@ func NewManualTicker() *ManualTicker {
 		c: make(chan time.Time, 1),
 	}
 }
+
+// HookLocker exposes the locking of a unit's hooks for testing.
+type HookLocker struct {
+	locker *hookLocker
+}
+
+func NewHookLocker(machineLock *fslock.Lock, lockDir, unitName, charmDir string, concurrent bool) HookLocker {
+	return HookLocker{&hookLocker{
+		machineLock: machineLock,
+		lockDir:     lockDir,
+		unitName:    unitName,
+		charmDir:    charmDir,
+		concurrent:  concurrent,
+	}}
+}
+
+func (l HookLocker) Acquire(message string, abort func() error) (func(), error) {
+	return l.locker.acquire(message, abort)
+}
+
+func (l HookLocker) BreakStaleLocks() error {
+	return l.locker.breakStaleLocks()
+}
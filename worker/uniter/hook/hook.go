@@ -14,6 +14,22 @@ import (
 	"github.com/juju/juju/feature"
 )
 
+// SecretRotate is the kind of the hook run on a service's leader when
+// one of the service's secrets is due rotation. It is not defined by the
+// charm package, which knows nothing of secrets.
+const SecretRotate hooks.Kind = "secret-rotate"
+
+const (
+	// PreSeriesUpgrade is the kind of the hook run on each unit of a
+	// machine before the machine's series is upgraded, so that the
+	// charm can prepare for the operating system to change.
+	PreSeriesUpgrade hooks.Kind = "pre-series-upgrade"
+
+	// PostSeriesUpgrade is the kind of the hook run on each unit of a
+	// machine once the machine's operating system has been upgraded.
+	PostSeriesUpgrade hooks.Kind = "post-series-upgrade"
+)
+
 // Info holds details required to execute a hook. Not all fields are
 // relevant to all Kind values.
 type Info struct {
@@ -33,6 +49,10 @@ type Info struct {
 
 	// StorageId is the ID of the storage instance relevant to the hook.
 	StorageId string `yaml:"storage-id,omitempty"`
+
+	// SecretId is the ID of the secret relevant to the hook. It is only
+	// set when Kind is SecretRotate.
+	SecretId string `yaml:"secret-id,omitempty"`
 }
 
 // Validate returns an error if the info is not valid.
@@ -45,6 +65,13 @@ func (hi Info) Validate() error {
 		fallthrough
 	case hooks.Install, hooks.Start, hooks.ConfigChanged, hooks.UpgradeCharm, hooks.Stop, hooks.RelationBroken, hooks.CollectMetrics, hooks.MeterStatusChanged:
 		return nil
+	case PreSeriesUpgrade, PostSeriesUpgrade:
+		return nil
+	case SecretRotate:
+		if hi.SecretId == "" {
+			return fmt.Errorf("%q hook requires a secret ID", hi.Kind)
+		}
+		return nil
 	case hooks.Action:
 		return fmt.Errorf("hooks.Kind Action is deprecated")
 	case hooks.StorageAttached, hooks.StorageDetached:
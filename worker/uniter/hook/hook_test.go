@@ -53,6 +53,10 @@ var validateTests = []struct {
 	{hook.Info{Kind: hooks.StorageAttached}, `invalid storage ID ""`},
 	{hook.Info{Kind: hooks.StorageAttached, StorageId: "data/0"}, ""},
 	{hook.Info{Kind: hooks.StorageDetached, StorageId: "data/0"}, ""},
+	{hook.Info{Kind: hook.SecretRotate}, `"secret-rotate" hook requires a secret ID`},
+	{hook.Info{Kind: hook.SecretRotate, SecretId: "deadbeef"}, ""},
+	{hook.Info{Kind: hook.PreSeriesUpgrade}, ""},
+	{hook.Info{Kind: hook.PostSeriesUpgrade}, ""},
 }
 
 func (s *InfoSuite) TestValidate(c *gc.C) {
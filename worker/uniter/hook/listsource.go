@@ -29,6 +29,11 @@ func (q *listSource) Pop() {
 	q.hooks = q.hooks[1:]
 }
 
+// Queued is defined in Lister.
+func (q *listSource) Queued() []Info {
+	return append([]Info(nil), q.hooks...)
+}
+
 // NewListSource returns a Source that generates only the supplied hooks, in
 // order; and which cannot be updated.
 func NewListSource(list []Info) Source {
@@ -45,3 +45,15 @@ func (s *ListSourceSuite) TestQueue(c *gc.C) {
 		c.Check(source.Pop, gc.PanicMatches, "Source is empty")
 	}
 }
+
+func (s *ListSourceSuite) TestQueued(c *gc.C) {
+	list := hooktesting.HookList(hooks.Install, hooks.ConfigChanged, hooks.Start)
+	source := hook.NewListSource(list)
+	lister := source.(hook.Lister)
+	c.Check(lister.Queued(), jc.DeepEquals, list)
+	source.Pop()
+	c.Check(lister.Queued(), jc.DeepEquals, list[1:])
+	source.Pop()
+	source.Pop()
+	c.Check(lister.Queued(), gc.HasLen, 0)
+}
@@ -4,6 +4,8 @@
 package hook
 
 import (
+	"sync"
+
 	"github.com/juju/errors"
 	"launchpad.net/tomb"
 
@@ -13,6 +15,11 @@ import (
 // Sender maintains a Source and delivers its hooks via a channel.
 type Sender interface {
 	Stop() error
+
+	// Queued returns the hooks the source had scheduled, but which had
+	// not yet been sent, when it was last changed. It returns nil if
+	// the source does not implement Lister.
+	Queued() []Info
 }
 
 // NewSender starts sending hooks from source onto the out channel, and will
@@ -35,6 +42,9 @@ func NewSender(out chan<- Info, source Source) Sender {
 type hookSender struct {
 	tomb tomb.Tomb
 	out  chan<- Info
+
+	mu     sync.Mutex
+	queued []Info
 }
 
 // Stop stops the Sender and returns any errors encountered during
@@ -44,12 +54,33 @@ func (sender *hookSender) Stop() error {
 	return sender.tomb.Wait()
 }
 
+// Queued is part of the Sender interface.
+func (sender *hookSender) Queued() []Info {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	return append([]Info(nil), sender.queued...)
+}
+
+// record takes a copy of the hooks scheduled by source, if it can
+// report them, for the benefit of Queued.
+func (sender *hookSender) record(source Source) {
+	lister, ok := source.(Lister)
+	if !ok {
+		return
+	}
+	queued := lister.Queued()
+	sender.mu.Lock()
+	sender.queued = queued
+	sender.mu.Unlock()
+}
+
 // loop synchronously delivers the source's change events to its update method,
 // and, whenever the source is nonempty, repeatedly sends its first scheduled
 // event on the out chan (and pops it from the source).
 func (sender *hookSender) loop(source Source) error {
 	var next Info
 	var out chan<- Info
+	sender.record(source)
 	for {
 		if source.Empty() {
 			out = nil
@@ -62,6 +93,7 @@ func (sender *hookSender) loop(source Source) error {
 			return tomb.ErrDying
 		case out <- next:
 			source.Pop()
+			sender.record(source)
 		case change, ok := <-source.Changes():
 			if !ok {
 				return errors.New("hook source stopped providing updates")
@@ -69,6 +101,7 @@ func (sender *hookSender) loop(source Source) error {
 			if err := change.Apply(); err != nil {
 				return errors.Trace(err)
 			}
+			sender.record(source)
 		}
 	}
 }
@@ -67,6 +67,37 @@ func (s *HookSenderSuite) TestStopsHooks(c *gc.C) {
 	c.Assert(source.Next(), gc.Equals, expect[2])
 }
 
+func (s *HookSenderSuite) TestQueued(c *gc.C) {
+	expect := hooktesting.HookList(hooks.Install, hooks.ConfigChanged, hooks.Start)
+	source := hook.NewListSource(expect)
+	out := make(chan hook.Info)
+	sender := hook.NewSender(out, source)
+	defer statetesting.AssertStop(c, sender)
+
+	assertNext(c, out, expect[0])
+	// The sender records the remaining hooks after popping the one
+	// it sent, which may not have happened yet.
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if len(sender.Queued()) == 2 || !a.HasNext() {
+			c.Assert(sender.Queued(), jc.DeepEquals, expect[1:])
+			break
+		}
+	}
+	statetesting.AssertStop(c, sender)
+	// The queued hooks remain available once the sender is stopped.
+	c.Assert(sender.Queued(), jc.DeepEquals, expect[1:])
+}
+
+func (s *HookSenderSuite) TestQueuedNotLister(c *gc.C) {
+	source := hooktesting.NewFullUnbufferedSource()
+	defer statetesting.AssertStop(c, source)
+
+	out := make(chan hook.Info)
+	sender := hook.NewSender(out, source)
+	defer statetesting.AssertStop(c, sender)
+	c.Assert(sender.Queued(), gc.IsNil)
+}
+
 func (s *HookSenderSuite) TestHandlesUpdatesFullQueue(c *gc.C) {
 	source := hooktesting.NewFullUnbufferedSource()
 	defer statetesting.AssertStop(c, source)
@@ -35,6 +35,16 @@ type Source interface {
 	Pop()
 }
 
+// Lister is implemented by Sources that can report every hook they
+// currently have scheduled, rather than just the first.
+type Lister interface {
+
+	// Queued returns the scheduled hooks, in the order they would be
+	// sent. Like Next, its result is only valid until the next call to
+	// Pop() or Update().
+	Queued() []Info
+}
+
 // SourceChange is the type of functions returned via Source.Changes().
 type SourceChange func() error
 
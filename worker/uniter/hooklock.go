@@ -0,0 +1,236 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/fslock"
+	"gopkg.in/yaml.v1"
+)
+
+const (
+	// unitHookLockPrefix prefixes the names of the locks held by
+	// units running hooks concurrently.
+	unitHookLockPrefix = "uniter-hook-execution-"
+
+	// resourceHookLockPrefix prefixes the names of the locks held
+	// for the host resources declared by charms running hooks
+	// concurrently.
+	resourceHookLockPrefix = "uniter-hook-resource-"
+)
+
+// concurrentHookPollDelay is how long to wait between checks for
+// concurrently running hooks to finish.
+var concurrentHookPollDelay = time.Second
+
+var validHostResource = regexp.MustCompile(`^[a-z]+[a-z0-9.-]*$`)
+
+// hookConcurrency holds a charm's declarations about running its hooks
+// alongside those of other units on the same machine.
+type hookConcurrency struct {
+	// Concurrent, if set, overrides the environment's
+	// concurrent-hooks setting.
+	Concurrent *bool `yaml:"concurrent-hooks"`
+
+	// HostResources names the host resources, such as "apt", that
+	// the charm's hooks change. Hooks of units declaring the same
+	// resource never run at the same time.
+	HostResources []string `yaml:"host-resources"`
+}
+
+// readHookConcurrency returns the hook concurrency declared by the
+// charm in the given directory.
+func readHookConcurrency(charmDir string) (hookConcurrency, error) {
+	var hc hookConcurrency
+	data, err := ioutil.ReadFile(filepath.Join(charmDir, "metadata.yaml"))
+	if os.IsNotExist(err) {
+		return hc, nil
+	} else if err != nil {
+		return hc, errors.Trace(err)
+	}
+	if err := yaml.Unmarshal(data, &hc); err != nil {
+		return hc, errors.Annotate(err, "cannot parse charm hook concurrency")
+	}
+	for _, name := range hc.HostResources {
+		if !validHostResource.MatchString(name) {
+			return hc, errors.NotValidf("host resource name %q", name)
+		}
+	}
+	return hc, nil
+}
+
+// hookLocker acquires the locks that keep a unit's hooks from running
+// at the same time as conflicting hooks of other units on the machine.
+//
+// By default a hook holds the machine's execution lock while it runs.
+// When concurrent hooks are enabled, a hook instead holds a lock for
+// its unit and one for each host resource declared by its charm; it
+// holds the machine lock only while acquiring those, so that it never
+// starts while the machine lock is held. A hook that holds the machine
+// lock waits for any concurrent hooks to finish before starting. Other
+// holders of the machine lock, such as juju-run outside a unit's
+// context, do not wait for them.
+type hookLocker struct {
+	machineLock *fslock.Lock
+	lockDir     string
+	unitName    string
+	charmDir    string
+
+	// concurrent holds the environment's concurrent-hooks setting,
+	// which the charm may override.
+	concurrent bool
+}
+
+// acquire acquires the locks the unit's hooks must hold, recording the
+// given message, and returns a func that releases them. It gives up
+// when abort returns an error.
+func (l *hookLocker) acquire(message string, abort func() error) (func(), error) {
+	hc, err := readHookConcurrency(l.charmDir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	concurrent := l.concurrent
+	if hc.Concurrent != nil {
+		concurrent = *hc.Concurrent
+	}
+	if err := l.machineLock.LockWithFunc(message, abort); err != nil {
+		return nil, err
+	}
+	if !concurrent {
+		if err := l.waitConcurrentHooks(abort); err != nil {
+			l.machineLock.Unlock()
+			return nil, err
+		}
+		return func() { l.machineLock.Unlock() }, nil
+	}
+	defer l.machineLock.Unlock()
+
+	locks, err := l.concurrentLocks(hc.HostResources)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var held []*fslock.Lock
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+	}
+	for _, lock := range locks {
+		if err := lock.LockWithFunc(message, abort); err != nil {
+			release()
+			return nil, err
+		}
+		held = append(held, lock)
+	}
+	return release, nil
+}
+
+// concurrentLocks returns the locks held by the unit's concurrent
+// hooks, in the order they must be acquired: those for the given
+// host resources, sorted by name, followed by the unit's own.
+func (l *hookLocker) concurrentLocks(resources []string) ([]*fslock.Lock, error) {
+	names := make([]string, 0, len(resources))
+	seen := make(map[string]bool)
+	for _, resource := range resources {
+		if !seen[resource] {
+			seen[resource] = true
+			names = append(names, resourceHookLockPrefix+resource)
+		}
+	}
+	sort.Strings(names)
+	names = append(names, unitHookLockName(l.unitName))
+	locks := make([]*fslock.Lock, len(names))
+	for i, name := range names {
+		lock, err := fslock.NewLock(l.lockDir, name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		locks[i] = lock
+	}
+	return locks, nil
+}
+
+// waitConcurrentHooks waits until no unit on the machine is running a
+// hook concurrently. It must be called with the machine lock held, so
+// that no further concurrent hooks start.
+func (l *hookLocker) waitConcurrentHooks(abort func() error) error {
+	locks, err := l.existingLocks(unitHookLockPrefix)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, lock := range locks {
+		for lock.IsLocked() {
+			if err := abort(); err != nil {
+				return err
+			}
+			time.Sleep(concurrentHookPollDelay)
+		}
+	}
+	return nil
+}
+
+// breakStaleLocks breaks any unit or host resource locks left held by
+// the unit, which can only have happened if its agent died while
+// running a hook.
+func (l *hookLocker) breakStaleLocks() error {
+	var locks []*fslock.Lock
+	for _, prefix := range []string{unitHookLockPrefix, resourceHookLockPrefix} {
+		existing, err := l.existingLocks(prefix)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		locks = append(locks, existing...)
+	}
+	for _, lock := range locks {
+		if heldByUnit(lock, l.unitName) {
+			if err := lock.BreakLock(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// existingLocks returns the locks in the lock directory whose names
+// start with the given prefix.
+func (l *hookLocker) existingLocks(prefix string) ([]*fslock.Lock, error) {
+	matches, err := filepath.Glob(filepath.Join(l.lockDir, prefix+"*"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var locks []*fslock.Lock
+	for _, match := range matches {
+		lock, err := fslock.NewLock(l.lockDir, filepath.Base(match))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// heldByUnit returns whether the lock is held with a message recorded
+// by the named unit.
+func heldByUnit(lock *fslock.Lock, unitName string) bool {
+	message := lock.Message()
+	if !lock.IsLocked() || message == "" {
+		return false
+	}
+	parts := strings.SplitN(message, ":", 2)
+	return len(parts) > 1 && parts[0] == unitName
+}
+
+// unitHookLockName returns the name of the lock held by the named unit
+// while running hooks concurrently.
+func unitHookLockName(unitName string) string {
+	return unitHookLockPrefix + strings.Replace(unitName, "/", "-", -1)
+}
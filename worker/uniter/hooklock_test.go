@@ -0,0 +1,198 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/fslock"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/worker/uniter"
+)
+
+type HookLockerSuite struct {
+	testing.IsolationSuite
+	lockDir     string
+	machineLock *fslock.Lock
+}
+
+var _ = gc.Suite(&HookLockerSuite{})
+
+func (s *HookLockerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.lockDir = c.MkDir()
+	s.machineLock = s.newLock(c, "uniter-hook-execution")
+}
+
+func (s *HookLockerSuite) newLock(c *gc.C, name string) *fslock.Lock {
+	lock, err := fslock.NewLock(s.lockDir, name)
+	c.Assert(err, jc.ErrorIsNil)
+	return lock
+}
+
+func (s *HookLockerSuite) newLocker(c *gc.C, unitName, metadata string, concurrent bool) uniter.HookLocker {
+	charmDir := c.MkDir()
+	if metadata != "" {
+		err := ioutil.WriteFile(filepath.Join(charmDir, "metadata.yaml"), []byte(metadata), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return uniter.NewHookLocker(s.machineLock, s.lockDir, unitName, charmDir, concurrent)
+}
+
+func noAbort() error {
+	return nil
+}
+
+func abort() error {
+	return errors.New("aborted")
+}
+
+func (s *HookLockerSuite) TestMachineLock(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "host-resources: [apt]", false)
+	unlock, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machineLock.IsLocked(), jc.IsTrue)
+	c.Assert(s.machineLock.Message(), gc.Equals, "mysql/0: running hook")
+	c.Assert(s.newLock(c, "uniter-hook-execution-mysql-0").IsLocked(), jc.IsFalse)
+	c.Assert(s.newLock(c, "uniter-hook-resource-apt").IsLocked(), jc.IsFalse)
+	unlock()
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestMachineLockNoCharm(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "", false)
+	unlock, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machineLock.IsLocked(), jc.IsTrue)
+	unlock()
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestConcurrent(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "host-resources: [apt, sysctl, apt]", true)
+	unlock, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+	for _, name := range []string{
+		"uniter-hook-execution-mysql-0",
+		"uniter-hook-resource-apt",
+		"uniter-hook-resource-sysctl",
+	} {
+		lock := s.newLock(c, name)
+		c.Check(lock.IsLocked(), jc.IsTrue)
+		c.Check(lock.Message(), gc.Equals, "mysql/0: running hook")
+	}
+	unlock()
+	for _, name := range []string{
+		"uniter-hook-execution-mysql-0",
+		"uniter-hook-resource-apt",
+		"uniter-hook-resource-sysctl",
+	} {
+		c.Check(s.newLock(c, name).IsLocked(), jc.IsFalse)
+	}
+}
+
+func (s *HookLockerSuite) TestCharmEnablesConcurrent(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "concurrent-hooks: true", false)
+	unlock, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	defer unlock()
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+	c.Assert(s.newLock(c, "uniter-hook-execution-mysql-0").IsLocked(), jc.IsTrue)
+}
+
+func (s *HookLockerSuite) TestCharmDisablesConcurrent(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "concurrent-hooks: false", true)
+	unlock, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	defer unlock()
+	c.Assert(s.machineLock.IsLocked(), jc.IsTrue)
+	c.Assert(s.newLock(c, "uniter-hook-execution-mysql-0").IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestConcurrentUnits(c *gc.C) {
+	mysql := s.newLocker(c, "mysql/0", "host-resources: [apt]", true)
+	unlock, err := mysql.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	defer unlock()
+
+	wordpress := s.newLocker(c, "wordpress/0", "host-resources: [sysctl]", true)
+	unlock, err = wordpress.Acquire("wordpress/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	unlock()
+}
+
+func (s *HookLockerSuite) TestConcurrentUnitsSharedResource(c *gc.C) {
+	mysql := s.newLocker(c, "mysql/0", "host-resources: [apt]", true)
+	unlock, err := mysql.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+	defer unlock()
+
+	wordpress := s.newLocker(c, "wordpress/0", "host-resources: [apt, sysctl]", true)
+	_, err = wordpress.Acquire("wordpress/0: running hook", abort)
+	c.Assert(err, gc.ErrorMatches, "aborted")
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+	c.Assert(s.newLock(c, "uniter-hook-resource-sysctl").IsLocked(), jc.IsFalse)
+	c.Assert(s.newLock(c, "uniter-hook-execution-wordpress-0").IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestConcurrentWaitsForMachineLock(c *gc.C) {
+	err := s.machineLock.Lock("reboot")
+	c.Assert(err, jc.ErrorIsNil)
+	defer s.machineLock.Unlock()
+
+	locker := s.newLocker(c, "mysql/0", "", true)
+	_, err = locker.Acquire("mysql/0: running hook", abort)
+	c.Assert(err, gc.ErrorMatches, "aborted")
+	c.Assert(s.newLock(c, "uniter-hook-execution-mysql-0").IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestMachineLockWaitsForConcurrent(c *gc.C) {
+	mysql := s.newLocker(c, "mysql/0", "", true)
+	unlock, err := mysql.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, jc.ErrorIsNil)
+
+	wordpress := s.newLocker(c, "wordpress/0", "", false)
+	_, err = wordpress.Acquire("wordpress/0: running hook", abort)
+	c.Assert(err, gc.ErrorMatches, "aborted")
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+
+	unlock()
+	unlock, err = wordpress.Acquire("wordpress/0: running hook", abort)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.machineLock.IsLocked(), jc.IsTrue)
+	unlock()
+}
+
+func (s *HookLockerSuite) TestInvalidHostResource(c *gc.C) {
+	locker := s.newLocker(c, "mysql/0", "host-resources: [Apt]", true)
+	_, err := locker.Acquire("mysql/0: running hook", noAbort)
+	c.Assert(err, gc.ErrorMatches, `host resource name "Apt" not valid`)
+	c.Assert(s.machineLock.IsLocked(), jc.IsFalse)
+}
+
+func (s *HookLockerSuite) TestBreakStaleLocks(c *gc.C) {
+	for name, message := range map[string]string{
+		"uniter-hook-execution-mysql-0":     "mysql/0: running hook",
+		"uniter-hook-resource-apt":          "mysql/0: running hook",
+		"uniter-hook-execution-wordpress-0": "wordpress/0: running hook",
+		"uniter-hook-resource-sysctl":       "wordpress/0: running hook",
+	} {
+		err := s.newLock(c, name).Lock(message)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	locker := s.newLocker(c, "mysql/0", "", true)
+	err := locker.BreakStaleLocks()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.newLock(c, "uniter-hook-execution-mysql-0").IsLocked(), jc.IsFalse)
+	c.Check(s.newLock(c, "uniter-hook-resource-apt").IsLocked(), jc.IsFalse)
+	c.Check(s.newLock(c, "uniter-hook-execution-wordpress-0").IsLocked(), jc.IsTrue)
+	c.Check(s.newLock(c, "uniter-hook-resource-sysctl").IsLocked(), jc.IsTrue)
+}
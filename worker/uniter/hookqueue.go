@@ -0,0 +1,95 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"reflect"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// hookQueueReport holds the hook queue most recently recorded in state.
+type hookQueueReport struct {
+	pending *params.QueuedHook
+	queued  []params.QueuedHook
+}
+
+// reportHookQueue records in state the hooks the unit has yet to run:
+// the failed hook awaiting resolution, if any, followed by any
+// config-changed or meter-status-changed hook called for by the remote
+// state, and by the relation hooks queued behind them. A queue that has
+// not changed since it was last recorded is not recorded again.
+func (u *Uniter) reportHookQueue(pending *hook.Info) error {
+	var report hookQueueReport
+	if pending != nil {
+		hookInfo := toQueuedHook(*pending)
+		report.pending = &hookInfo
+	}
+	remoteState := u.remoteState.Snapshot()
+	if remoteState.ConfigVersion != u.localState.ConfigVersion {
+		report.queued = append(report.queued, params.QueuedHook{
+			Kind: string(hooks.ConfigChanged),
+		})
+	}
+	if remoteState.Life == params.Alive && remoteState.MeterStatusVersion != u.localState.MeterStatusVersion {
+		report.queued = append(report.queued, params.QueuedHook{
+			Kind: string(hooks.MeterStatusChanged),
+		})
+	}
+	for _, hookInfo := range u.relations.QueuedHooks() {
+		report.queued = append(report.queued, toQueuedHook(hookInfo))
+	}
+	if u.reportedHookQueue != nil && reflect.DeepEqual(*u.reportedHookQueue, report) {
+		return nil
+	}
+	err := u.unit.SetHookQueue(report.pending, report.queued)
+	if errors.IsNotImplemented(err) {
+		// The state server cannot record the hook queue.
+		return nil
+	} else if err != nil {
+		return errors.Annotate(err, "cannot report hook queue")
+	}
+	u.reportedHookQueue = &report
+	return nil
+}
+
+// hookDropped returns whether the unit has been asked not to run the
+// supplied relation hook.
+func (u *Uniter) hookDropped(hookInfo hook.Info) bool {
+	if !hookInfo.Kind.IsRelation() {
+		return false
+	}
+	for _, dropped := range u.remoteState.Snapshot().DroppedHooks {
+		if dropped.Kind == string(hookInfo.Kind) &&
+			dropped.RelationId == hookInfo.RelationId &&
+			dropped.RemoteUnit == hookInfo.RemoteUnit {
+			return true
+		}
+	}
+	return false
+}
+
+// newRelationHookOp returns a creator for an operation that runs the
+// supplied relation hook, or that commits it without running it if the
+// unit has been asked to drop it.
+func (u *Uniter) newRelationHookOp(hookInfo hook.Info) creator {
+	if u.hookDropped(hookInfo) {
+		logger.Infof("skipping dropped %q hook", hookInfo.Kind)
+		return newSkipHookOp(hookInfo)
+	}
+	return newRunHookOp(hookInfo)
+}
+
+func toQueuedHook(hookInfo hook.Info) params.QueuedHook {
+	queued := params.QueuedHook{Kind: string(hookInfo.Kind)}
+	if hookInfo.Kind.IsRelation() {
+		queued.RelationId = hookInfo.RelationId
+		queued.RemoteUnit = hookInfo.RemoteUnit
+	}
+	return queued
+}
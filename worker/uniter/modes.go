@@ -13,9 +13,10 @@ import (
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/apiserver/params"
-	"github.com/juju/juju/state/watcher"
 	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/uniter/hook"
 	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/resolver"
 )
 
 // Mode defines the signature of the functions that implement the possible
@@ -112,32 +113,20 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 	if err = u.unit.SetAgentStatus(params.StatusStopping, "", nil); err != nil {
 		return nil, errors.Trace(err)
 	}
-	w, err := u.unit.Watch()
-	if err != nil {
+	if err := u.reportDrained(); err != nil {
 		return nil, errors.Trace(err)
 	}
-	defer watcher.Stop(w, &u.tomb)
 	for {
-		select {
-		case <-u.tomb.Dying():
-			return nil, tomb.ErrDying
-		case actionId := <-u.f.ActionEvents():
-			creator := newActionOp(actionId)
-			if err := u.runOperation(creator); err != nil {
-				return nil, errors.Trace(err)
-			}
-		case _, ok := <-w.Changes():
-			if !ok {
-				return nil, watcher.EnsureErr(w)
-			}
-			if err := u.unit.Refresh(); err != nil {
-				return nil, errors.Trace(err)
-			}
-			if hasSubs, err := u.unit.HasSubordinates(); err != nil {
-				return nil, errors.Trace(err)
-			} else if hasSubs {
-				continue
-			}
+		// The stop hook has run, so only actions remain to be resolved.
+		if err := u.resolveRemoteState(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := u.unit.Refresh(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if hasSubs, err := u.unit.HasSubordinates(); err != nil {
+			return nil, errors.Trace(err)
+		} else if !hasSubs {
 			// The unit is known to be Dying; so if it didn't have subordinates
 			// just above, it can't acquire new ones before this call.
 			if err := u.unit.EnsureDead(); err != nil {
@@ -145,13 +134,20 @@ func ModeTerminating(u *Uniter) (next Mode, err error) {
 			}
 			return nil, worker.ErrTerminateAgent
 		}
+		select {
+		case <-u.tomb.Dying():
+			return nil, tomb.ErrDying
+		case <-u.remoteState.RemoteStateChanged():
+		}
 	}
 }
 
-// ModeAbide is the Uniter's usual steady state. It watches for and responds to:
-// * service configuration changes
+// ModeAbide is the Uniter's usual steady state. It runs the operations chosen
+// by the uniter's resolver in response to remote state changes, and responds to:
 // * charm upgrade requests
-// * relation changes
+// * relation and storage hooks
+// * collect-metrics and secret-rotate timers
+// * upgrades of the machine's series
 // * unit death
 func ModeAbide(u *Uniter) (next Mode, err error) {
 	defer modeContext("ModeAbide", &err)()
@@ -171,7 +167,6 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 	if err = u.unit.SetAgentStatus(params.StatusActive, "", nil); err != nil {
 		return nil, errors.Trace(err)
 	}
-	u.f.WantUpgradeEvent(false)
 	u.relations.StartHooks()
 	defer func() {
 		if e := u.relations.StopHooks(); e != nil {
@@ -183,10 +178,8 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 		}
 	}()
 
-	select {
-	case <-u.f.UnitDying():
+	if u.remoteState.Snapshot().Life == params.Dying {
 		return modeAbideDyingLoop(u)
-	default:
 	}
 	return modeAbideAliveLoop(u)
 }
@@ -195,32 +188,52 @@ func ModeAbide(u *Uniter) (next Mode, err error) {
 // is in an Alive state.
 func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 	for {
+		remoteState := u.remoteState.Snapshot()
+		if remoteState.Life == params.Dying {
+			return modeAbideDyingLoop(u)
+		}
+		if resolver.UpgradeAvailable(u.localState, remoteState, false) {
+			return ModeUpgrading(remoteState.CharmURL), nil
+		}
+		if err := u.resolveRemoteState(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := u.reportHookQueue(nil); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if creator := u.upgradeSeriesHook(remoteState); creator != nil {
+			if err := u.runOperation(creator); err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
 		lastCollectMetrics := time.Unix(u.operationState().CollectMetricsTime, 0)
 		collectMetricsSignal := u.collectMetricsAt(
 			time.Now(), lastCollectMetrics, metricsPollInterval,
 		)
+		rotateSecretId, rotateSecretSignal, err := u.secretRotationSignal(time.Now())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		var creator creator
 		select {
 		case <-u.tomb.Dying():
 			return nil, tomb.ErrDying
-		case <-u.f.UnitDying():
-			return modeAbideDyingLoop(u)
-		case curl := <-u.f.UpgradeEvents():
-			return ModeUpgrading(curl), nil
-		case ids := <-u.f.RelationsEvents():
-			creator = newUpdateRelationsOp(ids)
-		case actionId := <-u.f.ActionEvents():
-			creator = newActionOp(actionId)
-		case tags := <-u.f.StorageEvents():
-			creator = newUpdateStorageOp(tags)
-		case <-u.f.ConfigEvents():
-			creator = newSimpleRunHookOp(hooks.ConfigChanged)
-		case <-u.f.MeterStatusEvents():
-			creator = newSimpleRunHookOp(hooks.MeterStatusChanged)
+		case <-u.remoteState.RemoteStateChanged():
+			continue
 		case <-collectMetricsSignal:
 			creator = newSimpleRunHookOp(hooks.CollectMetrics)
+		case <-rotateSecretSignal:
+			if rotateSecretId == "" {
+				// Time to check again.
+				continue
+			}
+			creator = newRunHookOp(hook.Info{
+				Kind:     hook.SecretRotate,
+				SecretId: rotateSecretId,
+			})
 		case hookInfo := <-u.relations.Hooks():
-			creator = newRunHookOp(hookInfo)
+			creator = u.newRelationHookOp(hookInfo)
 		case hookInfo := <-u.storage.Hooks():
 			creator = newRunHookOp(hookInfo)
 		}
@@ -246,16 +259,25 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 		if len(u.relations.GetInfo()) == 0 {
 			return continueAfter(u, newSimpleRunHookOp(hooks.Stop))
 		}
+		// Only actions and config changes are resolved once the unit
+		// is dying.
+		if err := u.resolveRemoteState(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := u.reportHookQueue(nil); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := u.reportDrain(); err != nil {
+			return nil, errors.Trace(err)
+		}
 		var creator creator
 		select {
 		case <-u.tomb.Dying():
 			return nil, tomb.ErrDying
-		case actionId := <-u.f.ActionEvents():
-			creator = newActionOp(actionId)
-		case <-u.f.ConfigEvents():
-			creator = newSimpleRunHookOp(hooks.ConfigChanged)
+		case <-u.remoteState.RemoteStateChanged():
+			continue
 		case hookInfo := <-u.relations.Hooks():
-			creator = newRunHookOp(hookInfo)
+			creator = u.newRelationHookOp(hookInfo)
 		}
 		if err := u.runOperation(creator); err != nil {
 			return nil, errors.Trace(err)
@@ -289,20 +311,26 @@ func ModeHookError(u *Uniter) (next Mode, err error) {
 	}
 	statusData["hook"] = hookName
 	statusMessage := fmt.Sprintf("hook failed: %q", hookName)
-	u.f.WantResolvedEvent()
-	u.f.WantUpgradeEvent(true)
 	for {
 		if err = u.unit.SetAgentStatus(params.StatusError, statusMessage, statusData); err != nil {
 			return nil, errors.Trace(err)
 		}
-		select {
-		case <-u.tomb.Dying():
-			return nil, tomb.ErrDying
-		case curl := <-u.f.UpgradeEvents():
-			return ModeUpgrading(curl), nil
-		case rm := <-u.f.ResolvedEvents():
-			var creator creator
-			switch rm {
+		var creator creator
+		for creator == nil {
+			if err := u.reportHookQueue(&hookInfo); err != nil {
+				return nil, errors.Trace(err)
+			}
+			remoteState := u.remoteState.Snapshot()
+			if resolver.UpgradeAvailable(u.localState, remoteState, true) {
+				return ModeUpgrading(remoteState.CharmURL), nil
+			}
+			switch rm := remoteState.ResolvedMode; rm {
+			case params.ResolvedNone:
+				select {
+				case <-u.tomb.Dying():
+					return nil, tomb.ErrDying
+				case <-u.remoteState.RemoteStateChanged():
+				}
 			case params.ResolvedRetryHooks:
 				creator = newRetryHookOp(hookInfo)
 			case params.ResolvedNoHooks:
@@ -310,14 +338,14 @@ func ModeHookError(u *Uniter) (next Mode, err error) {
 			default:
 				return nil, errors.Errorf("unknown resolved mode %q", rm)
 			}
-			err := u.runOperation(creator)
-			if errors.Cause(err) == operation.ErrHookFailed {
-				continue
-			} else if err != nil {
-				return nil, errors.Trace(err)
-			}
-			return ModeContinue, nil
 		}
+		err := u.runOperation(creator)
+		if errors.Cause(err) == operation.ErrHookFailed {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return ModeContinue, nil
 	}
 }
 
@@ -331,16 +359,20 @@ func ModeConflicted(curl *charm.URL) Mode {
 		if err = u.unit.SetAgentStatus(params.StatusError, "upgrade failed", nil); err != nil {
 			return nil, errors.Trace(err)
 		}
-		u.f.WantResolvedEvent()
-		u.f.WantUpgradeEvent(true)
 		var creator creator
-		select {
-		case <-u.tomb.Dying():
-			return nil, tomb.ErrDying
-		case curl = <-u.f.UpgradeEvents():
-			creator = newRevertUpgradeOp(curl)
-		case <-u.f.ResolvedEvents():
-			creator = newResolvedUpgradeOp(curl)
+		for creator == nil {
+			remoteState := u.remoteState.Snapshot()
+			if resolver.UpgradeAvailable(u.localState, remoteState, true) {
+				creator = newRevertUpgradeOp(remoteState.CharmURL)
+			} else if remoteState.ResolvedMode != params.ResolvedNone {
+				creator = newResolvedUpgradeOp(curl)
+			} else {
+				select {
+				case <-u.tomb.Dying():
+					return nil, tomb.ErrDying
+				case <-u.remoteState.RemoteStateChanged():
+				}
+			}
 		}
 		return continueAfter(u, creator)
 	}
@@ -38,10 +38,7 @@ func (opc *operationCallbacks) AcquireExecutionLock(message string) (func(), err
 		return nil
 	}
 	message = fmt.Sprintf("%s: %s", opc.u.unit.Name(), message)
-	if err := opc.u.hookLock.LockWithFunc(message, checkTomb); err != nil {
-		return nil, err
-	}
-	return func() { opc.u.hookLock.Unlock() }, nil
+	return opc.u.hookLocker.acquire(message, checkTomb)
 }
 
 // PrepareHook is part of the operation.Callbacks interface.
@@ -69,9 +66,6 @@ func (opc *operationCallbacks) PrepareHook(hi hook.Info) (string, error) {
 		// set the status to "preparing storage".
 	case hi.Kind == hooks.Stop:
 		status = params.StatusStopping
-	case hi.Kind == hooks.ConfigChanged:
-		opc.u.f.DiscardConfigEvent()
-		fallthrough
 	default:
 		if !opc.u.operationState().Started {
 			status = params.StatusInstalling
@@ -93,6 +87,12 @@ func (opc *operationCallbacks) CommitHook(hi hook.Info) error {
 		return opc.u.storage.CommitHook(hi)
 	case hi.Kind == hooks.ConfigChanged:
 		opc.u.ranConfigChanged = true
+	case hi.Kind == hook.SecretRotate:
+		// Whether or not the charm set new values, the secret is not
+		// due rotation again until its interval has passed.
+		return opc.u.unit.SecretRotated(hi.SecretId)
+	case hi.Kind == hook.PreSeriesUpgrade, hi.Kind == hook.PostSeriesUpgrade:
+		return opc.u.commitUpgradeSeriesHook(hi)
 	}
 	return nil
 }
@@ -151,12 +151,12 @@ func (opc *operationCallbacks) GetArchiveInfo(charmURL *corecharm.URL) (charm.Bu
 
 // SetCurrentCharm is part of the operation.Callbacks interface.
 func (opc *operationCallbacks) SetCurrentCharm(charmURL *corecharm.URL) error {
-	return opc.u.f.SetCharm(charmURL)
+	return opc.u.remoteState.SetCharm(charmURL)
 }
 
 // ClearResolvedFlag is part of the operation.Callbacks interface.
 func (opc *operationCallbacks) ClearResolvedFlag() error {
-	return opc.u.f.ClearResolved()
+	return opc.u.remoteState.ClearResolved()
 }
 
 // InitializeMetricsCollector is part of the operation.Callbacks interface.
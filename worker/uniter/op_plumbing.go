@@ -4,7 +4,6 @@
 package uniter
 
 import (
-	"github.com/juju/names"
 	"gopkg.in/juju/charm.v4"
 	"gopkg.in/juju/charm.v4/hooks"
 
@@ -75,21 +74,3 @@ func newCommandsOp(args operation.CommandArgs, sendResponse operation.CommandRes
 		return factory.NewCommands(args, sendResponse)
 	}
 }
-
-func newActionOp(actionId string) creator {
-	return func(factory operation.Factory) (operation.Operation, error) {
-		return factory.NewAction(actionId)
-	}
-}
-
-func newUpdateRelationsOp(ids []int) creator {
-	return func(factory operation.Factory) (operation.Operation, error) {
-		return factory.NewUpdateRelations(ids)
-	}
-}
-
-func newUpdateStorageOp(tags []names.StorageTag) creator {
-	return func(factory operation.Factory) (operation.Operation, error) {
-		return factory.NewUpdateStorage(tags)
-	}
-}
@@ -178,11 +178,13 @@ type StorageUpdater interface {
 }
 
 // ExecutionLocker is an interface that provides a means of acquiring and
-// releasing a machine-level lock. When acquiring the lock, the caller provides
-// a message which will be recorded to aid in debugging.
+// releasing the locks that serialize execution of external code on the
+// machine. When acquiring the locks, the caller provides a message which
+// will be recorded to aid in debugging.
 type ExecutionLocker interface {
-	// AcquireExecutionLock acquires the machine-level execution lock, and
-	// returns a func that must be called to unlock it. It's used by all the
-	// operations that execute external code.
+	// AcquireExecutionLock acquires the execution locks, and returns a func
+	// that must be called to unlock them. This is the machine-level lock
+	// unless the unit's hooks may run concurrently with those of other
+	// units. It's used by all the operations that execute external code.
 	AcquireExecutionLock(message string) (unlock func(), err error)
 }
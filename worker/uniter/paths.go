@@ -31,6 +31,12 @@ type Paths struct {
 	// State represents the set of paths that hold persistent local state for
 	// the uniter.
 	State StatePaths
+
+	// WorkloadContainer is the name of the container in which the charm's
+	// hooks and actions are executed, or "" if they are executed directly
+	// on the machine. The container shares the machine's network and has
+	// the juju data directory mounted at the same path.
+	WorkloadContainer string
 }
 
 // GetToolsDir exists to satisfy the context.Paths interface.
@@ -48,6 +54,11 @@ func (paths Paths) GetJujucSocket() string {
 	return paths.Runtime.JujucServerSocket
 }
 
+// GetWorkloadContainer exists to satisfy the context.Paths interface.
+func (paths Paths) GetWorkloadContainer() string {
+	return paths.WorkloadContainer
+}
+
 // RuntimePaths represents the set of paths that are relevant at runtime.
 type RuntimePaths struct {
 
@@ -72,7 +83,9 @@ type StatePaths struct {
 	OperationsFile string
 
 	// RelationsDir holds relation-specific information about what the
-	// uniter is doing and/or has done.
+	// uniter is doing and/or has done, when the API server cannot record
+	// it. Information left there by earlier versions of the uniter is
+	// moved to the API server when the uniter starts.
 	RelationsDir string
 
 	// BundlesDir holds downloaded charms.
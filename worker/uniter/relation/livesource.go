@@ -204,6 +204,36 @@ func (q *liveSource) Pop() {
 	}
 }
 
+// Queued returns every hook.Info value the queue would send, in order,
+// were it not to change further.
+func (q *liveSource) Queued() []hook.Info {
+	if q.Empty() {
+		return nil
+	}
+	var queued []hook.Info
+	if q.changedPending != "" {
+		queued = append(queued, hook.Info{
+			Kind:          hooks.RelationChanged,
+			RelationId:    q.relationId,
+			RemoteUnit:    q.changedPending,
+			ChangeVersion: q.info[q.changedPending].version,
+		})
+	}
+	for info := q.head; info != nil; info = info.next {
+		if info.unit == q.changedPending && info.hookKind == hooks.RelationChanged {
+			// Already reported above; Pop will unqueue it.
+			continue
+		}
+		queued = append(queued, hook.Info{
+			Kind:          info.hookKind,
+			RelationId:    q.relationId,
+			RemoteUnit:    info.unit,
+			ChangeVersion: info.version,
+		})
+	}
+	return queued
+}
+
 func (q *liveSource) update(change multiwatcher.RelationUnitsChange) {
 	// Enforce consistent addition order, mainly for testing purposes.
 	changedUnits := []string{}
@@ -6,8 +6,10 @@ package relation_test
 import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4/hooks"
 
 	"github.com/juju/juju/state/multiwatcher"
+	"github.com/juju/juju/worker/uniter/hook"
 	"github.com/juju/juju/worker/uniter/relation"
 )
 
@@ -29,3 +31,26 @@ func (s *LiveSourceSuite) TestLiveHookSource(c *gc.C) {
 		c.Assert(ruw.stopped, jc.IsTrue)
 	}
 }
+
+func (s *LiveSourceSuite) TestQueued(c *gc.C) {
+	ruw := &RUW{make(chan multiwatcher.RelationUnitsChange), false}
+	q := relation.NewLiveHookSource(&relation.State{21345, msi{"u/1": 0}, ""}, ruw)
+	defer q.Stop()
+	lister := q.(hook.Lister)
+	c.Assert(lister.Queued(), gc.HasLen, 0)
+
+	send{msi{"u/0": 0, "u/1": 3}, nil}.checkDirect(c, q)
+	c.Assert(lister.Queued(), jc.DeepEquals, []hook.Info{
+		expect{hooks.RelationJoined, "u/0", 0}.info(),
+		expect{hooks.RelationChanged, "u/1", 3}.info(),
+	})
+
+	// Popping a joined schedules the matching changed first.
+	q.Pop()
+	send{msi{"u/0": 4}, nil}.checkDirect(c, q)
+	c.Assert(lister.Queued(), jc.DeepEquals, []hook.Info{
+		expect{hooks.RelationChanged, "u/0", 4}.info(),
+		expect{hooks.RelationChanged, "u/1", 3}.info(),
+	})
+	c.Assert(lister.Queued()[0], jc.DeepEquals, q.Next())
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relation
+
+import (
+	"os"
+
+	"github.com/juju/errors"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+)
+
+// StateKeeper persists the state of a relation as the hooks sent for
+// it are committed. A StateDir keeps the state on local disk, and a
+// StoredState keeps it in state via the API server.
+type StateKeeper interface {
+	// State returns the current state of the relation.
+	State() *State
+
+	// Ensure records the relation, so that it is reported when
+	// relation states are next read, even if no hooks have been
+	// committed for it.
+	Ensure() error
+
+	// Write records the relation state change in hi. It must be
+	// called after the respective hook was executed successfully.
+	Write(hi hook.Info) error
+
+	// Remove removes the record of the relation.
+	Remove() error
+}
+
+var (
+	_ StateKeeper = (*StateDir)(nil)
+	_ StateKeeper = (*StoredState)(nil)
+)
+
+// StateStore records the state of a unit's relations. It is
+// implemented by *uniter.Unit.
+type StateStore interface {
+	RelationStates() ([]params.UnitRelationState, error)
+	SetRelationState(state params.UnitRelationState) error
+	RemoveRelationState(relationId int) error
+}
+
+// StoredState is a StateStore-backed representation of the state of a
+// relation. Concurrent modifications to the stored state will have
+// undefined consequences.
+type StoredState struct {
+	store StateStore
+
+	// recorded holds whether the state has been stored.
+	recorded bool
+
+	// state is the cached state of the relation, which is guaranteed
+	// to be synchronized with the stored state so long as no
+	// concurrent changes are made to it.
+	state State
+}
+
+// NewStoredState returns a StoredState for the relation with the given
+// id, which has no state recorded in the store.
+func NewStoredState(store StateStore, relationId int) *StoredState {
+	return &StoredState{
+		store: store,
+		state: State{relationId, map[string]int64{}, ""},
+	}
+}
+
+// ReadAllStoredStates loads and returns the state of every relation
+// recorded in the store.
+func ReadAllStoredStates(store StateStore) (map[int]*StoredState, error) {
+	recorded, err := store.RelationStates()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot load relations state")
+	}
+	states := make(map[int]*StoredState)
+	for _, rs := range recorded {
+		members := rs.Members
+		if members == nil {
+			members = map[string]int64{}
+		}
+		states[rs.RelationId] = &StoredState{
+			store:    store,
+			recorded: true,
+			state:    State{rs.RelationId, members, rs.ChangedPending},
+		}
+	}
+	return states, nil
+}
+
+// State returns the current state of the relation.
+func (s *StoredState) State() *State {
+	return s.state.copy()
+}
+
+// Ensure records the relation's state if it is not already recorded.
+func (s *StoredState) Ensure() error {
+	if s.recorded {
+		return nil
+	}
+	if err := s.store.SetRelationState(s.state.params()); err != nil {
+		return errors.Annotatef(err, "cannot record relation %d", s.state.RelationId)
+	}
+	s.recorded = true
+	return nil
+}
+
+// Write records the relation state change in hi. It must be called
+// after the respective hook was executed successfully. Write doesn't
+// validate hi but guarantees that successive writes of the same hi
+// are idempotent.
+func (s *StoredState) Write(hi hook.Info) (err error) {
+	defer errors.DeferredAnnotatef(&err, "failed to write %q hook info for %q on relation state", hi.Kind, hi.RemoteUnit)
+	if hi.Kind == hooks.RelationBroken {
+		return s.Remove()
+	}
+	state := s.state.copy()
+	if hi.Kind == hooks.RelationDeparted {
+		delete(state.Members, hi.RemoteUnit)
+		if state.ChangedPending == hi.RemoteUnit {
+			state.ChangedPending = ""
+		}
+	} else {
+		state.Members[hi.RemoteUnit] = hi.ChangeVersion
+		if hi.Kind == hooks.RelationJoined {
+			state.ChangedPending = hi.RemoteUnit
+		} else {
+			state.ChangedPending = ""
+		}
+	}
+	if err := s.store.SetRelationState(state.params()); err != nil {
+		return err
+	}
+	// If the write was successful, update own state.
+	s.state = *state
+	s.recorded = true
+	return nil
+}
+
+// Remove removes the relation's recorded state.
+func (s *StoredState) Remove() error {
+	if err := s.store.RemoveRelationState(s.state.RelationId); err != nil {
+		return errors.Annotatef(err, "cannot remove relation %d", s.state.RelationId)
+	}
+	// If the removal succeeded, update own state.
+	s.state.Members = nil
+	s.recorded = false
+	return nil
+}
+
+// params returns the state as recorded by a StateStore.
+func (s *State) params() params.UnitRelationState {
+	return params.UnitRelationState{
+		RelationId:     s.RelationId,
+		Members:        s.Members,
+		ChangedPending: s.ChangedPending,
+	}
+}
+
+// MigrateStateDirs moves the relation state persisted inside dirPath,
+// by versions of the unit agent that kept it on local disk, into the
+// store, and then removes dirPath. States already recorded in the
+// store are left unchanged. If dirPath does not exist, no error is
+// returned.
+func MigrateStateDirs(dirPath string, store StateStore) error {
+	dirs, err := ReadAllStateDirs(dirPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if dirs == nil {
+		return nil
+	}
+	recorded, err := store.RelationStates()
+	if err != nil {
+		return errors.Annotate(err, "cannot load relations state")
+	}
+	known := make(map[int]bool)
+	for _, rs := range recorded {
+		known[rs.RelationId] = true
+	}
+	for id, dir := range dirs {
+		if known[id] {
+			continue
+		}
+		if err := store.SetRelationState(dir.state.params()); err != nil {
+			return errors.Annotatef(err, "cannot migrate relation %d", id)
+		}
+	}
+	if err := os.RemoveAll(dirPath); err != nil {
+		return errors.Annotatef(err, "cannot remove migrated relations state")
+	}
+	return nil
+}
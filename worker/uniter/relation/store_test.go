@@ -0,0 +1,220 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package relation_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/relation"
+)
+
+// fakeStore is a relation.StateStore that keeps relation states in
+// memory.
+type fakeStore struct {
+	states map[int]params.UnitRelationState
+	err    error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{states: make(map[int]params.UnitRelationState)}
+}
+
+func (s *fakeStore) RelationStates() ([]params.UnitRelationState, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var states []params.UnitRelationState
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (s *fakeStore) SetRelationState(state params.UnitRelationState) error {
+	if s.err != nil {
+		return s.err
+	}
+	members := make(map[string]int64)
+	for unit, version := range state.Members {
+		members[unit] = version
+	}
+	state.Members = members
+	s.states[state.RelationId] = state
+	return nil
+}
+
+func (s *fakeStore) RemoveRelationState(relationId int) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.states, relationId)
+	return nil
+}
+
+type StoredStateSuite struct{}
+
+var _ = gc.Suite(&StoredStateSuite{})
+
+func (s *StoredStateSuite) TestNewStoredState(c *gc.C) {
+	store := newFakeStore()
+	stored := relation.NewStoredState(store, 123)
+	c.Assert(stored.State(), gc.DeepEquals, &relation.State{
+		RelationId: 123,
+		Members:    map[string]int64{},
+	})
+	c.Assert(store.states, gc.HasLen, 0)
+
+	err := stored.Ensure()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(store.states, jc.DeepEquals, map[int]params.UnitRelationState{
+		123: {RelationId: 123, Members: map[string]int64{}},
+	})
+}
+
+func (s *StoredStateSuite) TestReadAllStoredStates(c *gc.C) {
+	store := newFakeStore()
+	store.states[1] = params.UnitRelationState{
+		RelationId:     1,
+		Members:        map[string]int64{"foo/1": 3},
+		ChangedPending: "foo/1",
+	}
+	store.states[2] = params.UnitRelationState{RelationId: 2}
+
+	states, err := relation.ReadAllStoredStates(store)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(states, gc.HasLen, 2)
+	c.Assert(states[1].State(), gc.DeepEquals, &relation.State{
+		RelationId:     1,
+		Members:        map[string]int64{"foo/1": 3},
+		ChangedPending: "foo/1",
+	})
+	c.Assert(states[2].State(), gc.DeepEquals, &relation.State{
+		RelationId: 2,
+		Members:    map[string]int64{},
+	})
+}
+
+func (s *StoredStateSuite) TestReadAllStoredStatesError(c *gc.C) {
+	store := newFakeStore()
+	store.err = errors.NotImplementedf("RelationStates()")
+	_, err := relation.ReadAllStoredStates(store)
+	c.Assert(err, gc.ErrorMatches, `cannot load relations state: RelationStates\(\) not implemented`)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *StoredStateSuite) TestWrite(c *gc.C) {
+	for i, t := range writeTests {
+		c.Logf("test %d", i)
+		store := newFakeStore()
+		store.states[123] = params.UnitRelationState{
+			RelationId: 123,
+			Members:    map[string]int64(defaultMembers),
+		}
+		states, err := relation.ReadAllStoredStates(store)
+		c.Assert(err, jc.ErrorIsNil)
+		stored := states[123]
+		for i, hi := range t.hooks {
+			c.Logf("  hook %d", i)
+			if i == len(t.hooks)-1 && t.err != "" {
+				err = stored.State().Validate(hi)
+				expect := fmt.Sprintf(`inappropriate %q for %q: %s`, hi.Kind, hi.RemoteUnit, t.err)
+				c.Assert(err, gc.ErrorMatches, expect)
+			} else {
+				err = stored.State().Validate(hi)
+				c.Assert(err, jc.ErrorIsNil)
+				err = stored.Write(hi)
+				c.Assert(err, jc.ErrorIsNil)
+				// Check that writing the same change again is OK.
+				err = stored.Write(hi)
+				c.Assert(err, jc.ErrorIsNil)
+			}
+		}
+		members := t.members
+		if members == nil && !t.deleted {
+			members = defaultMembers
+		}
+		expect := &relation.State{
+			RelationId:     123,
+			Members:        map[string]int64(members),
+			ChangedPending: t.pending,
+		}
+		c.Assert(stored.State(), gc.DeepEquals, expect)
+		if t.deleted {
+			c.Assert(store.states, gc.HasLen, 0)
+			continue
+		}
+		fresh, err := relation.ReadAllStoredStates(store)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(fresh[123].State(), gc.DeepEquals, expect)
+	}
+}
+
+func (s *StoredStateSuite) TestWriteFailureLeavesState(c *gc.C) {
+	store := newFakeStore()
+	stored := relation.NewStoredState(store, 123)
+	store.err = errors.New("boom")
+	err := stored.Ensure()
+	c.Assert(err, gc.ErrorMatches, "cannot record relation 123: boom")
+	c.Assert(stored.State().Members, gc.HasLen, 0)
+}
+
+type MigrateStateDirsSuite struct{}
+
+var _ = gc.Suite(&MigrateStateDirsSuite{})
+
+func (s *MigrateStateDirsSuite) TestNoDir(c *gc.C) {
+	store := newFakeStore()
+	store.err = errors.New("store should not be used")
+	err := relation.MigrateStateDirs(filepath.Join(c.MkDir(), "relations"), store)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MigrateStateDirsSuite) TestMigrate(c *gc.C) {
+	relsdir := c.MkDir()
+	setUpDir(c, relsdir, "1", map[string]string{
+		"foo-1": "change-version: 3\nchanged-pending: true\n",
+	})
+	setUpDir(c, relsdir, "2", map[string]string{
+		"foo-1": "change-version: 1\n",
+	})
+	store := newFakeStore()
+	recorded := params.UnitRelationState{
+		RelationId: 2,
+		Members:    map[string]int64{"foo/1": 5},
+	}
+	store.states[2] = recorded
+
+	err := relation.MigrateStateDirs(relsdir, store)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(store.states, jc.DeepEquals, map[int]params.UnitRelationState{
+		1: {
+			RelationId:     1,
+			Members:        map[string]int64{"foo/1": 3},
+			ChangedPending: "foo/1",
+		},
+		// Recorded states are not replaced.
+		2: recorded,
+	})
+	_, err = os.Stat(relsdir)
+	c.Assert(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *MigrateStateDirsSuite) TestMigrateFailureKeepsDir(c *gc.C) {
+	relsdir := c.MkDir()
+	setUpDir(c, relsdir, "1", nil)
+	store := newFakeStore()
+	store.err = errors.NotImplementedf("RelationStates()")
+
+	err := relation.MigrateStateDirs(relsdir, store)
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	_, err = os.Stat(filepath.Join(relsdir, "1"))
+	c.Assert(err, jc.ErrorIsNil)
+}
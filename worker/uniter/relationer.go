@@ -17,25 +17,29 @@ import (
 // Relationer manages a unit's presence in a relation.
 type Relationer struct {
 	ru    *apiuniter.RelationUnit
-	dir   *relation.StateDir
+	state relation.StateKeeper
 	queue relation.HookQueue
 	hooks chan<- hook.Info
 	dying bool
+
+	// queued holds the hooks that had not been sent when hooks
+	// were last stopped.
+	queued []hook.Info
 }
 
 // NewRelationer creates a new Relationer. The unit will not join the
 // relation until explicitly requested.
-func NewRelationer(ru *apiuniter.RelationUnit, dir *relation.StateDir, hooks chan<- hook.Info) *Relationer {
+func NewRelationer(ru *apiuniter.RelationUnit, state relation.StateKeeper, hooks chan<- hook.Info) *Relationer {
 	return &Relationer{
 		ru:    ru,
-		dir:   dir,
+		state: state,
 		hooks: hooks,
 	}
 }
 
 // ContextInfo returns a represention of r's current state.
 func (r *Relationer) ContextInfo() *runner.RelationInfo {
-	members := r.dir.State().Members
+	members := r.state.State().Members
 	memberNames := make([]string, 0, len(members))
 	for memberName := range members {
 		memberNames = append(memberNames, memberName)
@@ -51,15 +55,15 @@ func (r *Relationer) IsImplicit() bool {
 
 // Join initializes local state and causes the unit to enter its relation
 // scope, allowing its counterpart units to detect its presence and settings
-// changes. Local state directory is not created until needed.
+// changes. The relation's state is not recorded until needed.
 func (r *Relationer) Join() error {
 	if r.dying {
 		panic("dying relationer must not join!")
 	}
-	// We need to make sure the state directory exists before we join the
-	// relation, lest a subsequent ReadAllStateDirs report local state that
-	// doesn't include relations recorded in remote state.
-	if err := r.dir.Ensure(); err != nil {
+	// We need to make sure the relation's state is recorded before we
+	// join the relation, lest a subsequent read of the relation states
+	// not include relations recorded in remote state.
+	if err := r.state.Ensure(); err != nil {
 		return err
 	}
 	// uniter.RelationUnit.EnterScope() sets the unit's private address
@@ -86,12 +90,12 @@ func (r *Relationer) SetDying() error {
 }
 
 // die is run when the relationer has no further responsibilities; it leaves
-// relation scope, and removes the local relation state directory.
+// relation scope, and removes the recorded relation state.
 func (r *Relationer) die() error {
 	if err := r.ru.LeaveScope(); err != nil {
 		return err
 	}
-	return r.dir.Remove()
+	return r.state.Remove()
 }
 
 // StartHooks starts watching the relation, and sending hook.Info events on the
@@ -104,14 +108,15 @@ func (r *Relationer) StartHooks() error {
 	if r.queue != nil {
 		panic("hooks already started!")
 	}
+	r.queued = nil
 	if r.dying {
-		r.queue = relation.NewDyingHookQueue(r.dir.State(), r.hooks)
+		r.queue = relation.NewDyingHookQueue(r.state.State(), r.hooks)
 	} else {
 		w, err := r.ru.Watch()
 		if err != nil {
 			return err
 		}
-		r.queue = relation.NewAliveHookQueue(r.dir.State(), r.hooks, w)
+		r.queue = relation.NewAliveHookQueue(r.state.State(), r.hooks, w)
 	}
 	return nil
 }
@@ -124,7 +129,18 @@ func (r *Relationer) StopHooks() error {
 	}
 	queue := r.queue
 	r.queue = nil
-	return queue.Stop()
+	err := queue.Stop()
+	r.queued = queue.Queued()
+	return err
+}
+
+// QueuedHooks returns the hooks the relationer has yet to send, or, if
+// hooks are stopped, those it had yet to send when they were stopped.
+func (r *Relationer) QueuedHooks() []hook.Info {
+	if r.queue != nil {
+		return r.queue.Queued()
+	}
+	return append([]hook.Info(nil), r.queued...)
 }
 
 // PrepareHook checks that the relation is in a state such that it makes
@@ -135,7 +151,7 @@ func (r *Relationer) PrepareHook(hi hook.Info) (hookName string, err error) {
 	if r.IsImplicit() {
 		panic("implicit relations must not run hooks")
 	}
-	if err = r.dir.State().Validate(hi); err != nil {
+	if err = r.state.State().Validate(hi); err != nil {
 		return
 	}
 	name := r.ru.Endpoint().Name
@@ -150,5 +166,5 @@ func (r *Relationer) CommitHook(hi hook.Info) error {
 	if hi.Kind == hooks.RelationBroken {
 		return r.die()
 	}
-	return r.dir.Write(hi)
+	return r.state.Write(hi)
 }
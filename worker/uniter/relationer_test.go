@@ -249,6 +249,43 @@ func (s *RelationerSuite) TestStartStopHooks(c *gc.C) {
 	s.assertNoHook(c)
 }
 
+func (s *RelationerSuite) TestQueuedHooks(c *gc.C) {
+	ru1, _ := s.AddRelationUnit(c, "u/1")
+	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
+	err := r.Join()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(r.QueuedHooks(), gc.HasLen, 0)
+
+	r.StartHooks()
+	defer stopHooks(c, r)
+	err = ru1.EnterScope(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.BackingState.StartSync()
+	for a := coretesting.LongAttempt.Start(); a.Next(); {
+		if len(r.QueuedHooks()) > 0 || !a.HasNext() {
+			break
+		}
+	}
+	joined := hook.Info{
+		Kind:       hooks.RelationJoined,
+		RelationId: s.rel.Id(),
+		RemoteUnit: "u/1",
+	}
+	queued := r.QueuedHooks()
+	c.Assert(queued, gc.HasLen, 1)
+	joined.ChangeVersion = queued[0].ChangeVersion
+	c.Assert(queued, jc.DeepEquals, []hook.Info{joined})
+
+	// The queued hooks are remembered while hooks are stopped...
+	err = r.StopHooks()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(r.QueuedHooks(), jc.DeepEquals, []hook.Info{joined})
+
+	// ...and sent once they are started again.
+	r.StartHooks()
+	s.assertHook(c, joined)
+}
+
 func (s *RelationerSuite) TestPrepareCommitHooks(c *gc.C) {
 	r := uniter.NewRelationer(s.apiRelUnit, s.dir, s.hooks)
 	err := r.Join()
@@ -4,6 +4,8 @@
 package uniter
 
 import (
+	"sort"
+
 	"github.com/juju/errors"
 	"github.com/juju/names"
 	corecharm "gopkg.in/juju/charm.v4"
@@ -45,6 +47,11 @@ type Relations interface {
 	// current relation state.
 	CommitHook(hookInfo hook.Info) error
 
+	// QueuedHooks returns the relation hooks that have yet to be run,
+	// ordered by relation id, whether or not hooks are currently being
+	// sent.
+	QueuedHooks() []hook.Info
+
 	// GetInfo returns information about current relation state.
 	GetInfo() map[int]*runner.RelationInfo
 
@@ -68,6 +75,10 @@ type relations struct {
 	relationers   map[int]*Relationer
 	relationHooks chan hook.Info
 	abort         <-chan struct{}
+
+	// onDisk holds whether relation state is kept in relationsDir,
+	// because the API server cannot record it.
+	onDisk bool
 }
 
 func newRelations(st *uniter.State, tag names.UnitTag, paths Paths, abort <-chan struct{}) (*relations, error) {
@@ -90,7 +101,7 @@ func newRelations(st *uniter.State, tag names.UnitTag, paths Paths, abort <-chan
 	return r, nil
 }
 
-// init reconciles the local relation state dirs with the remote state of
+// init reconciles the recorded relation states with the remote state of
 // the corresponding relations. It's only expected to be called while a
 // *relations is being created.
 func (r *relations) init() error {
@@ -106,34 +117,74 @@ func (r *relations) init() error {
 		}
 		joinedRelations[relation.Id()] = relation
 	}
-	knownDirs, err := relation.ReadAllStateDirs(r.relationsDir)
+	knownStates, err := r.readStates()
 	if err != nil {
 		return errors.Trace(err)
 	}
-	for id, dir := range knownDirs {
+	for id, state := range knownStates {
 		if rel, ok := joinedRelations[id]; ok {
-			if err := r.add(rel, dir); err != nil {
+			if err := r.add(rel, state); err != nil {
 				return errors.Trace(err)
 			}
-		} else if err := dir.Remove(); err != nil {
+		} else if err := state.Remove(); err != nil {
 			return errors.Trace(err)
 		}
 	}
 	for id, rel := range joinedRelations {
-		if _, ok := knownDirs[id]; ok {
+		if _, ok := knownStates[id]; ok {
 			continue
 		}
-		dir, err := relation.ReadStateDir(r.relationsDir, id)
+		state, err := r.newState(id)
 		if err != nil {
 			return errors.Trace(err)
 		}
-		if err := r.add(rel, dir); err != nil {
+		if err := r.add(rel, state); err != nil {
 			return errors.Trace(err)
 		}
 	}
 	return nil
 }
 
+// readStates returns the recorded state of every relation known to the
+// unit. The states are recorded by the API server; any left on disk by
+// an earlier version of the uniter are moved there first. If the API
+// server cannot record relation states, they are kept on disk.
+func (r *relations) readStates() (map[int]relation.StateKeeper, error) {
+	err := relation.MigrateStateDirs(r.relationsDir, r.unit)
+	var stored map[int]*relation.StoredState
+	if err == nil {
+		stored, err = relation.ReadAllStoredStates(r.unit)
+	}
+	if errors.IsNotImplemented(err) {
+		logger.Debugf("keeping relation state on disk: %v", err)
+		r.onDisk = true
+		dirs, err := relation.ReadAllStateDirs(r.relationsDir)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		states := make(map[int]relation.StateKeeper)
+		for id, dir := range dirs {
+			states[id] = dir
+		}
+		return states, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	states := make(map[int]relation.StateKeeper)
+	for id, state := range stored {
+		states[id] = state
+	}
+	return states, nil
+}
+
+// newState returns the state of a relation the unit has not recorded.
+func (r *relations) newState(id int) (relation.StateKeeper, error) {
+	if r.onDisk {
+		return relation.ReadStateDir(r.relationsDir, id)
+	}
+	return relation.NewStoredState(r.unit, id), nil
+}
+
 // Name is part of the Relations interface.
 func (r *relations) Name(id int) (string, error) {
 	relationer, found := r.relationers[id]
@@ -196,6 +247,20 @@ func (r *relations) CommitHook(hookInfo hook.Info) error {
 	return relationer.CommitHook(hookInfo)
 }
 
+// QueuedHooks is part of the Relations interface.
+func (r *relations) QueuedHooks() []hook.Info {
+	ids := make([]int, 0, len(r.relationers))
+	for id := range r.relationers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var queued []hook.Info
+	for _, id := range ids {
+		queued = append(queued, r.relationers[id].QueuedHooks()...)
+	}
+	return queued
+}
+
 // GetInfo is part of the Relations interface.
 func (r *relations) GetInfo() map[int]*runner.RelationInfo {
 	relationInfos := map[int]*runner.RelationInfo{}
@@ -243,16 +308,16 @@ func (r *relations) Update(ids []int) error {
 			logger.Warningf("skipping relation with unknown endpoint %q", ep.Name)
 			continue
 		}
-		dir, err := relation.ReadStateDir(r.relationsDir, id)
+		state, err := r.newState(id)
 		if err != nil {
 			return errors.Trace(err)
 		}
-		err = r.add(rel, dir)
+		err = r.add(rel, state)
 		if err == nil {
 			r.relationers[id].StartHooks()
 			continue
 		}
-		e := dir.Remove()
+		e := state.Remove()
 		if !params.IsCodeCannotEnterScope(err) {
 			return errors.Trace(err)
 		}
@@ -288,14 +353,14 @@ func (r *relations) SetDying() error {
 }
 
 // add causes the unit agent to join the supplied relation, and to
-// store persistent state in the supplied dir.
-func (r *relations) add(rel *uniter.Relation, dir *relation.StateDir) (err error) {
+// record its state in the supplied state.
+func (r *relations) add(rel *uniter.Relation, state relation.StateKeeper) (err error) {
 	logger.Infof("joining relation %q", rel)
 	ru, err := rel.Unit(r.unit)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	relationer := NewRelationer(ru, dir, r.relationHooks)
+	relationer := NewRelationer(ru, state, r.relationHooks)
 	w, err := r.unit.Watch()
 	if err != nil {
 		return errors.Trace(err)
@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remotestate
+
+import (
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/worker"
+)
+
+// Watcher watches the state relevant to a unit agent, and presents it as a
+// single Snapshot that the uniter can compare with its local state.
+type Watcher interface {
+	worker.Worker
+
+	// RemoteStateChanged returns a channel which receives a value
+	// whenever the remote state may have changed. Several changes may be
+	// reported by a single value, so clients should always compare the
+	// latest Snapshot with their local state.
+	RemoteStateChanged() <-chan struct{}
+
+	// Snapshot returns a copy of the current remote state.
+	Snapshot() Snapshot
+
+	// SetCharm notifies the watcher that the unit is running a new
+	// charm. It causes the unit's charm URL to be set in state, and
+	// restarts the watch on the service's configuration, which is
+	// specific to the charm, so that ConfigVersion is incremented once
+	// the new configuration is known.
+	//
+	// SetCharm blocks until the charm URL is set in state, returning
+	// any error that occurred.
+	SetCharm(curl *charm.URL) error
+
+	// ClearResolved clears the unit's resolved flag in state, and
+	// ensures that subsequent snapshots do not report it.
+	ClearResolved() error
+
+	// ActionCompleted removes the action with the supplied id from the
+	// pending actions reported by subsequent snapshots.
+	ActionCompleted(actionId string)
+}
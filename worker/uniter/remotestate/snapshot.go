@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remotestate
+
+import (
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Snapshot is a snapshot of the remote state of the unit.
+type Snapshot struct {
+	// Life is the lifecycle state of the unit.
+	Life params.Life
+
+	// Relations holds the lifecycle state of each of the service's
+	// relations, keyed by relation id.
+	Relations map[int]params.Life
+
+	// Storage holds a version for each of the unit's storage
+	// attachments, which is incremented whenever the attachment
+	// changes.
+	Storage map[names.StorageTag]int
+
+	// CharmURL is the charm URL that the unit is expected to run.
+	CharmURL *charm.URL
+
+	// ForceCharmUpgrade indicates that the unit should upgrade to
+	// CharmURL even if it is in an error state.
+	ForceCharmUpgrade bool
+
+	// ResolvedMode reports the method of resolving hook execution
+	// errors requested for the unit.
+	ResolvedMode params.ResolvedMode
+
+	// ConfigVersion is incremented whenever the service's configuration
+	// or the unit's addresses change, once both are known.
+	ConfigVersion int
+
+	// MeterStatusVersion is incremented whenever the unit's meter status
+	// changes.
+	MeterStatusVersion int
+
+	// Actions holds the ids of the actions pending for the unit, in the
+	// order they were reported.
+	Actions []string
+
+	// DroppedHooks holds the queued hooks that the unit has been
+	// asked not to run.
+	DroppedHooks []params.QueuedHook
+
+	// UpgradeSeriesStatus is the unit's progress through an upgrade of
+	// its machine's series. It is empty if no upgrade is in progress.
+	UpgradeSeriesStatus string
+}
+
+// copy returns a deep copy of the snapshot.
+func (s Snapshot) copy() Snapshot {
+	result := s
+	if s.Relations != nil {
+		result.Relations = make(map[int]params.Life, len(s.Relations))
+		for id, life := range s.Relations {
+			result.Relations[id] = life
+		}
+	}
+	if s.Storage != nil {
+		result.Storage = make(map[names.StorageTag]int, len(s.Storage))
+		for tag, version := range s.Storage {
+			result.Storage[tag] = version
+		}
+	}
+	if s.Actions != nil {
+		result.Actions = append([]string(nil), s.Actions...)
+	}
+	if s.DroppedHooks != nil {
+		result.DroppedHooks = append([]params.QueuedHook(nil), s.DroppedHooks...)
+	}
+	return result
+}
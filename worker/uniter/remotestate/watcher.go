@@ -0,0 +1,548 @@
+// Copyright 2012-2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package remotestate provides a single watcher for all the state that
+// drives a unit agent's behaviour.
+package remotestate
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/api/uniter"
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.uniter.remotestate")
+
+// RemoteStateWatcher collects unit, service, configuration, relation,
+// storage, meter status, action, hook queue and series upgrade
+// information from separate API watchers, and maintains a single
+// Snapshot of it.
+type RemoteStateWatcher struct {
+	st      *uniter.State
+	unit    *uniter.Unit
+	service *uniter.Service
+	tomb    tomb.Tomb
+
+	// out receives a value whenever the snapshot may have changed.
+	out chan struct{}
+
+	mu      sync.Mutex
+	current Snapshot
+
+	// setCharm is used to request that the unit's charm URL be set to
+	// a new value. This must be done in the watcher's goroutine, so
+	// that the config watch can be stopped and restarted pointing to
+	// the new charm URL. If we don't stop the watch before the
+	// (potentially) last reference to that settings document is
+	// removed, we'll see spurious errors (and even in the best case,
+	// we risk getting notifications for the wrong settings version).
+	setCharm chan *charm.URL
+
+	// didSetCharm is used to report back after setting a charm URL.
+	didSetCharm chan struct{}
+
+	// clearResolved is used to request that the unit's resolved flag
+	// be cleared. This must be done on the watcher's goroutine so that
+	// the snapshot is updated before ClearResolved returns, and thus
+	// never reports a stale value.
+	clearResolved chan struct{}
+
+	// didClearResolved is used to report back after clearing the
+	// resolved flag.
+	didClearResolved chan struct{}
+
+	// The following fields are only accessed by the watcher's
+	// goroutine.
+	meterStatusCode string
+	meterStatusInfo string
+	relationIds     map[string]int
+}
+
+// NewWatcher returns a RemoteStateWatcher that handles state changes
+// pertaining to the supplied unit. The unit's and service's state are
+// read before NewWatcher returns, so the first Snapshot is complete in
+// that respect; other state is reported as it is received.
+func NewWatcher(st *uniter.State, unitTag names.UnitTag) (_ *RemoteStateWatcher, err error) {
+	defer func() {
+		if params.IsCodeNotFoundOrCodeUnauthorized(err) {
+			err = worker.ErrTerminateAgent
+		}
+	}()
+	w := &RemoteStateWatcher{
+		st:               st,
+		out:              make(chan struct{}, 1),
+		setCharm:         make(chan *charm.URL),
+		didSetCharm:      make(chan struct{}),
+		clearResolved:    make(chan struct{}),
+		didClearResolved: make(chan struct{}),
+		relationIds:      make(map[string]int),
+		current: Snapshot{
+			Relations: make(map[int]params.Life),
+			Storage:   make(map[names.StorageTag]int),
+		},
+	}
+	if w.unit, err = st.Unit(unitTag); err != nil {
+		return nil, err
+	}
+	if w.service, err = w.unit.Service(); err != nil {
+		return nil, err
+	}
+	if err := w.unitChanged(); err != nil {
+		return nil, err
+	}
+	if err := w.serviceChanged(); err != nil {
+		return nil, err
+	}
+	if w.meterStatusCode, w.meterStatusInfo, err = w.unit.MeterStatus(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w.notify()
+	go func() {
+		defer w.tomb.Done()
+		err := w.loop()
+		logger.Errorf("%v", err)
+		w.tomb.Kill(err)
+	}()
+	return w, nil
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *RemoteStateWatcher) Kill() {
+	w.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *RemoteStateWatcher) Wait() error {
+	return w.tomb.Wait()
+}
+
+// RemoteStateChanged is part of the Watcher interface.
+func (w *RemoteStateWatcher) RemoteStateChanged() <-chan struct{} {
+	return w.out
+}
+
+// Snapshot is part of the Watcher interface.
+func (w *RemoteStateWatcher) Snapshot() Snapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current.copy()
+}
+
+// SetCharm is part of the Watcher interface.
+func (w *RemoteStateWatcher) SetCharm(curl *charm.URL) error {
+	select {
+	case <-w.tomb.Dying():
+		return tomb.ErrDying
+	case w.setCharm <- curl:
+	}
+	select {
+	case <-w.tomb.Dying():
+		return tomb.ErrDying
+	case <-w.didSetCharm:
+		return nil
+	}
+}
+
+// ClearResolved is part of the Watcher interface.
+func (w *RemoteStateWatcher) ClearResolved() error {
+	select {
+	case <-w.tomb.Dying():
+		return tomb.ErrDying
+	case w.clearResolved <- struct{}{}:
+	}
+	select {
+	case <-w.tomb.Dying():
+		return tomb.ErrDying
+	case <-w.didClearResolved:
+		logger.Debugf("resolved clear completed")
+		return nil
+	}
+}
+
+// ActionCompleted is part of the Watcher interface.
+func (w *RemoteStateWatcher) ActionCompleted(actionId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, id := range w.current.Actions {
+		if id == actionId {
+			w.current.Actions = append(w.current.Actions[:i], w.current.Actions[i+1:]...)
+			break
+		}
+	}
+}
+
+// notify signals that the snapshot may have changed, without blocking.
+func (w *RemoteStateWatcher) notify() {
+	select {
+	case w.out <- struct{}{}:
+	default:
+	}
+}
+
+// update applies the supplied change to the snapshot, and signals it.
+func (w *RemoteStateWatcher) update(change func(*Snapshot)) {
+	w.mu.Lock()
+	change(&w.current)
+	w.mu.Unlock()
+	w.notify()
+}
+
+func (w *RemoteStateWatcher) maybeStopWatcher(wr watcher.Stopper) {
+	if wr != nil {
+		watcher.Stop(wr, &w.tomb)
+	}
+}
+
+func (w *RemoteStateWatcher) loop() (err error) {
+	defer func() {
+		if params.IsCodeNotFoundOrCodeUnauthorized(err) {
+			err = worker.ErrTerminateAgent
+		}
+	}()
+	unitw, err := w.unit.Watch()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(unitw)
+	servicew, err := w.service.Watch()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(servicew)
+	// configw can get restarted, so we need to use its eventual value in
+	// the deferred call.
+	var configw apiwatcher.NotifyWatcher
+	var configChanges <-chan struct{}
+	if _, err := w.unit.CharmURL(); err == nil {
+		configw, err = w.unit.WatchConfigSettings()
+		if err != nil {
+			return err
+		}
+		configChanges = configw.Changes()
+	} else if err != uniter.ErrNoCharmURLSet {
+		logger.Errorf("unit charm: %v", err)
+		return err
+	}
+	defer func() { w.maybeStopWatcher(configw) }()
+	addressesw, err := w.unit.WatchAddresses()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(addressesw)
+	meterStatusw, err := w.unit.WatchMeterStatus()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(meterStatusw)
+	actionsw, err := w.unit.WatchActionNotifications()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(actionsw)
+	relationsw, err := w.service.WatchRelations()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(relationsw)
+	storagew, err := w.unit.WatchStorage()
+	if err != nil {
+		return err
+	}
+	defer w.maybeStopWatcher(storagew)
+	// Servers that cannot record the unit's hook queue cannot be asked
+	// to drop hooks from it either, so there's nothing to watch.
+	var hookQueuew apiwatcher.NotifyWatcher
+	var hookQueueChanges <-chan struct{}
+	if hookQueuew, err = w.unit.WatchHookQueue(); err == nil {
+		hookQueueChanges = hookQueuew.Changes()
+		defer w.maybeStopWatcher(hookQueuew)
+	} else if !errors.IsNotImplemented(err) {
+		return err
+	}
+	// Likewise, servers that cannot track series upgrades never ask
+	// the unit to take part in one.
+	var upgradeSeriesw apiwatcher.NotifyWatcher
+	var upgradeSeriesChanges <-chan struct{}
+	if upgradeSeriesw, err = w.unit.WatchUpgradeSeries(); err == nil {
+		upgradeSeriesChanges = upgradeSeriesw.Changes()
+		defer w.maybeStopWatcher(upgradeSeriesw)
+	} else if !errors.IsNotImplemented(err) {
+		return err
+	}
+
+	// The initial events of the config and address watchers report
+	// state the uniter handles by running config-changed whenever it
+	// starts, or whenever it sets a new charm; only subsequent events
+	// are changes.
+	var seenConfigChange, seenAddressChange bool
+	configChanged := func(seen *bool) {
+		if !*seen {
+			*seen = true
+			return
+		}
+		logger.Debugf("config version incremented")
+		w.update(func(s *Snapshot) { s.ConfigVersion++ })
+	}
+
+	for {
+		var ok bool
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+
+		// Handle watcher changes.
+		case _, ok = <-unitw.Changes():
+			logger.Debugf("got unit change")
+			if !ok {
+				return watcher.EnsureErr(unitw)
+			}
+			if err := w.unitChanged(); err != nil {
+				return err
+			}
+			// Changes to the unit that are not recorded in the
+			// snapshot, such as its subordinates, may still be
+			// significant to the uniter.
+			w.notify()
+		case _, ok = <-servicew.Changes():
+			logger.Debugf("got service change")
+			if !ok {
+				return watcher.EnsureErr(servicew)
+			}
+			if err := w.serviceChanged(); err != nil {
+				return err
+			}
+		case _, ok = <-configChanges:
+			logger.Debugf("got config change")
+			if !ok {
+				return watcher.EnsureErr(configw)
+			}
+			configChanged(&seenConfigChange)
+		case _, ok = <-addressesw.Changes():
+			logger.Debugf("got address change")
+			if !ok {
+				return watcher.EnsureErr(addressesw)
+			}
+			configChanged(&seenAddressChange)
+		case _, ok = <-meterStatusw.Changes():
+			logger.Debugf("got meter status change")
+			if !ok {
+				return watcher.EnsureErr(meterStatusw)
+			}
+			if err := w.meterStatusChanged(); err != nil {
+				return errors.Trace(err)
+			}
+		case ids, ok := <-actionsw.Changes():
+			logger.Debugf("got %d actions", len(ids))
+			if !ok {
+				return watcher.EnsureErr(actionsw)
+			}
+			w.update(func(s *Snapshot) {
+				s.Actions = append(s.Actions, ids...)
+			})
+		case keys, ok := <-relationsw.Changes():
+			logger.Debugf("got relations change")
+			if !ok {
+				return watcher.EnsureErr(relationsw)
+			}
+			if err := w.relationsChanged(keys); err != nil {
+				return err
+			}
+		case ids, ok := <-storagew.Changes():
+			logger.Debugf("got storage change")
+			if !ok {
+				return watcher.EnsureErr(storagew)
+			}
+			w.update(func(s *Snapshot) {
+				for _, id := range ids {
+					s.Storage[names.NewStorageTag(id)]++
+				}
+			})
+		case _, ok = <-hookQueueChanges:
+			logger.Debugf("got hook queue change")
+			if !ok {
+				return watcher.EnsureErr(hookQueuew)
+			}
+			if err := w.hookQueueChanged(); err != nil {
+				return errors.Trace(err)
+			}
+		case _, ok = <-upgradeSeriesChanges:
+			logger.Debugf("got series upgrade change")
+			if !ok {
+				return watcher.EnsureErr(upgradeSeriesw)
+			}
+			if err := w.upgradeSeriesChanged(); err != nil {
+				return errors.Trace(err)
+			}
+
+		// Handle explicit requests.
+		case curl := <-w.setCharm:
+			logger.Debugf("changing charm to %q", curl)
+			// We need to restart the config watcher after setting the
+			// charm, because service config settings are distinct for
+			// different service charms.
+			if configw != nil {
+				if err := configw.Stop(); err != nil {
+					return err
+				}
+				configw, configChanges = nil, nil
+			}
+			if err := w.unit.SetCharmURL(curl); err != nil {
+				logger.Debugf("failed setting charm url %q: %v", curl, err)
+				return err
+			}
+			// The settings for the new charm must be handled,
+			// whether or not they differ from the old ones.
+			w.update(func(s *Snapshot) { s.ConfigVersion++ })
+			select {
+			case <-w.tomb.Dying():
+				return tomb.ErrDying
+			case w.didSetCharm <- struct{}{}:
+			}
+			configw, err = w.unit.WatchConfigSettings()
+			if err != nil {
+				return err
+			}
+			configChanges = configw.Changes()
+			seenConfigChange = false
+		case <-w.clearResolved:
+			logger.Debugf("resolved event handled")
+			if err := w.unit.ClearResolved(); err != nil {
+				return err
+			}
+			if err := w.unitChanged(); err != nil {
+				return err
+			}
+			select {
+			case <-w.tomb.Dying():
+				return tomb.ErrDying
+			case w.didClearResolved <- struct{}{}:
+			}
+		}
+	}
+}
+
+// unitChanged responds to changes in the unit.
+func (w *RemoteStateWatcher) unitChanged() error {
+	if err := w.unit.Refresh(); err != nil {
+		return err
+	}
+	life := w.unit.Life()
+	switch life {
+	case params.Dying:
+		logger.Infof("unit is dying")
+	case params.Dead:
+		logger.Infof("unit is dead")
+		return worker.ErrTerminateAgent
+	}
+	resolved, err := w.unit.Resolved()
+	if err != nil {
+		return err
+	}
+	w.update(func(s *Snapshot) {
+		s.Life = life
+		s.ResolvedMode = resolved
+	})
+	return nil
+}
+
+// serviceChanged responds to changes in the service.
+func (w *RemoteStateWatcher) serviceChanged() error {
+	if err := w.service.Refresh(); err != nil {
+		return err
+	}
+	url, force, err := w.service.CharmURL()
+	if err != nil {
+		return err
+	}
+	switch w.service.Life() {
+	case params.Dying:
+		if err := w.unit.Destroy(); err != nil {
+			return err
+		}
+	case params.Dead:
+		logger.Infof("service is dead")
+		return worker.ErrTerminateAgent
+	}
+	w.update(func(s *Snapshot) {
+		s.CharmURL = url
+		s.ForceCharmUpgrade = force
+	})
+	return nil
+}
+
+// meterStatusChanged responds to changes in the unit's meter status.
+func (w *RemoteStateWatcher) meterStatusChanged() error {
+	code, info, err := w.unit.MeterStatus()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if w.meterStatusCode != code || w.meterStatusInfo != info {
+		w.meterStatusCode = code
+		w.meterStatusInfo = info
+		w.update(func(s *Snapshot) { s.MeterStatusVersion++ })
+	}
+	return nil
+}
+
+// hookQueueChanged responds to changes in the unit's hook queue.
+func (w *RemoteStateWatcher) hookQueueChanged() error {
+	dropped, err := w.unit.DroppedHooks()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	w.update(func(s *Snapshot) { s.DroppedHooks = dropped })
+	return nil
+}
+
+// upgradeSeriesChanged responds to changes in the progress of an
+// upgrade of the series of the unit's machine.
+func (w *RemoteStateWatcher) upgradeSeriesChanged() error {
+	status, err := w.unit.UpgradeSeriesStatus()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	w.update(func(s *Snapshot) { s.UpgradeSeriesStatus = status })
+	return nil
+}
+
+// relationsChanged responds to changes in the service's relations,
+// identified by their keys.
+func (w *RemoteStateWatcher) relationsChanged(keys []string) error {
+	changed := make(map[int]params.Life)
+	var removed []int
+	for _, key := range keys {
+		rel, err := w.st.Relation(names.NewRelationTag(key))
+		if params.IsCodeNotFoundOrCodeUnauthorized(err) {
+			// If it's actually gone, this unit cannot have entered
+			// scope, and therefore never needs to know about it.
+			if id, ok := w.relationIds[key]; ok {
+				delete(w.relationIds, key)
+				removed = append(removed, id)
+			}
+		} else if err != nil {
+			return err
+		} else {
+			w.relationIds[key] = rel.Id()
+			changed[rel.Id()] = rel.Life()
+		}
+	}
+	w.update(func(s *Snapshot) {
+		for id, life := range changed {
+			s.Relations[id] = life
+		}
+		for _, id := range removed {
+			delete(s.Relations, id)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,366 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package remotestate_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/api"
+	apiuniter "github.com/juju/juju/api/uniter"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/state"
+	statetesting "github.com/juju/juju/state/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+type WatcherSuite struct {
+	jujutesting.JujuConnSuite
+	wordpress *state.Service
+	unit      *state.Unit
+	wpcharm   *state.Charm
+	machine   *state.Machine
+
+	st     *api.State
+	uniter *apiuniter.State
+}
+
+var _ = gc.Suite(&WatcherSuite{})
+
+func (s *WatcherSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.wpcharm = s.AddTestingCharm(c, "wordpress")
+	s.wordpress = s.AddTestingService(c, "wordpress", s.wpcharm)
+	var err error
+	s.unit, err = s.wordpress.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.AssignToNewMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	mid, err := s.unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	s.machine, err = s.State.Machine(mid)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.machine.SetProvisioned("i-exist", "fake_nonce", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.APILogin(c, s.unit)
+}
+
+func (s *WatcherSuite) APILogin(c *gc.C, unit *state.Unit) {
+	password, err := utils.RandomPassword()
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetPassword(password)
+	c.Assert(err, jc.ErrorIsNil)
+	s.st = s.OpenAPIAs(c, unit.Tag(), password)
+	s.uniter, err = s.st.Uniter()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.uniter, gc.NotNil)
+}
+
+func (s *WatcherSuite) newWatcher(c *gc.C) *remotestate.RemoteStateWatcher {
+	w, err := remotestate.NewWatcher(s.uniter, s.unit.Tag().(names.UnitTag))
+	c.Assert(err, jc.ErrorIsNil)
+	return w
+}
+
+// waitSnapshot waits until the watcher's snapshot satisfies the supplied
+// condition, and returns that snapshot.
+func (s *WatcherSuite) waitSnapshot(c *gc.C, w remotestate.Watcher, cond func(remotestate.Snapshot) bool) remotestate.Snapshot {
+	timeout := time.After(coretesting.LongWait)
+	for {
+		snapshot := w.Snapshot()
+		if cond(snapshot) {
+			return snapshot
+		}
+		s.BackingState.StartSync()
+		select {
+		case <-w.RemoteStateChanged():
+		case <-time.After(coretesting.ShortWait):
+		case <-timeout:
+			c.Fatalf("timed out waiting for remote state; last saw %+v", snapshot)
+		}
+	}
+}
+
+// assertSnapshotStable checks that the supplied aspect of the watcher's
+// snapshot does not change once the backing state has been synced.
+func (s *WatcherSuite) assertSnapshotStable(c *gc.C, w remotestate.Watcher, aspect func(remotestate.Snapshot) interface{}) {
+	expect := aspect(w.Snapshot())
+	s.BackingState.StartSync()
+	time.Sleep(coretesting.ShortWait)
+	c.Assert(aspect(w.Snapshot()), jc.DeepEquals, expect)
+}
+
+func (s *WatcherSuite) assertAgentTerminates(c *gc.C, w remotestate.Watcher) {
+	done := make(chan error)
+	go func() {
+		done <- w.Wait()
+	}()
+	timeout := time.After(coretesting.LongWait)
+	for {
+		s.BackingState.StartSync()
+		select {
+		case err := <-done:
+			c.Assert(err, gc.Equals, worker.ErrTerminateAgent)
+			return
+		case <-time.After(coretesting.ShortWait):
+		case <-timeout:
+			c.Fatalf("watcher did not stop")
+		}
+	}
+}
+
+func (s *WatcherSuite) TestInitialSnapshot(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	select {
+	case <-w.RemoteStateChanged():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("initial change not sent")
+	}
+	snapshot := w.Snapshot()
+	c.Assert(snapshot.Life, gc.Equals, params.Alive)
+	c.Assert(snapshot.CharmURL, jc.DeepEquals, s.wpcharm.URL())
+	c.Assert(snapshot.ResolvedMode, gc.Equals, params.ResolvedNone)
+	c.Assert(snapshot.ConfigVersion, gc.Equals, 0)
+}
+
+func (s *WatcherSuite) TestUnitDeath(c *gc.C) {
+	w := s.newWatcher(c)
+	defer worker.Stop(w)
+
+	err := s.unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.Life == params.Dying
+	})
+
+	err = s.unit.EnsureDead()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertAgentTerminates(c, w)
+}
+
+func (s *WatcherSuite) TestUnitRemoval(c *gc.C) {
+	w := s.newWatcher(c)
+	defer worker.Stop(w)
+
+	// short-circuit to remove because no status set.
+	err := s.unit.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertAgentTerminates(c, w)
+}
+
+func (s *WatcherSuite) TestServiceDeath(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	err := s.unit.SetAgentStatus(state.StatusActive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.wordpress.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.Life == params.Dying
+	})
+	err = s.unit.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.unit.Life(), gc.Equals, state.Dying)
+}
+
+func (s *WatcherSuite) TestResolved(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	err := s.unit.SetResolved(state.ResolvedRetryHooks)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.ResolvedMode == params.ResolvedRetryHooks
+	})
+
+	// Clearing the resolved flag via the watcher is reflected
+	// immediately.
+	err = w.ClearResolved()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Snapshot().ResolvedMode, gc.Equals, params.ResolvedNone)
+
+	err = s.unit.SetResolved(state.ResolvedNoHooks)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.ResolvedMode == params.ResolvedNoHooks
+	})
+}
+
+func (s *WatcherSuite) TestCharmURL(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	newCharm := s.AddTestingCharm(c, "upgrade2")
+	err := s.wordpress.SetCharm(newCharm, false)
+	c.Assert(err, jc.ErrorIsNil)
+	snapshot := s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return *snapshot.CharmURL == *newCharm.URL()
+	})
+	c.Assert(snapshot.ForceCharmUpgrade, jc.IsFalse)
+
+	err = s.wordpress.SetCharm(s.wpcharm, true)
+	c.Assert(err, jc.ErrorIsNil)
+	snapshot = s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return *snapshot.CharmURL == *s.wpcharm.URL()
+	})
+	c.Assert(snapshot.ForceCharmUpgrade, jc.IsTrue)
+}
+
+func (s *WatcherSuite) TestConfigVersion(c *gc.C) {
+	err := s.machine.SetAddresses(network.NewAddress("0.1.2.3", network.ScopeUnknown))
+	c.Assert(err, jc.ErrorIsNil)
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+	configVersion := func(snapshot remotestate.Snapshot) interface{} {
+		return snapshot.ConfigVersion
+	}
+
+	// Existing addresses are not a change, and nothing is watched
+	// until the charm URL is set.
+	s.assertSnapshotStable(c, w, configVersion)
+	c.Assert(w.Snapshot().ConfigVersion, gc.Equals, 0)
+
+	// Setting the charm URL always requires config to be handled, but
+	// the initial settings are not a further change.
+	err = w.SetCharm(s.wpcharm.URL())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Snapshot().ConfigVersion, gc.Equals, 1)
+	s.assertSnapshotStable(c, w, configVersion)
+
+	err = s.wordpress.UpdateConfigSettings(charm.Settings{
+		"blog-title": "20,000 leagues in the cloud",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.ConfigVersion == 2
+	})
+
+	// Address changes also require config to be handled.
+	err = s.machine.SetAddresses(network.NewAddress("0.1.2.4", network.ScopeUnknown))
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.ConfigVersion == 3
+	})
+}
+
+func (s *WatcherSuite) TestMeterStatus(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+	meterStatusVersion := func(snapshot remotestate.Snapshot) interface{} {
+		return snapshot.MeterStatusVersion
+	}
+	s.assertSnapshotStable(c, w, meterStatusVersion)
+	c.Assert(w.Snapshot().MeterStatusVersion, gc.Equals, 0)
+
+	err := s.unit.SetMeterStatus("GREEN", "Operating normally.")
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.MeterStatusVersion == 1
+	})
+
+	// Setting the same status again is not a change.
+	err = s.unit.SetMeterStatus("GREEN", "Operating normally.")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSnapshotStable(c, w, meterStatusVersion)
+	c.Assert(w.Snapshot().MeterStatusVersion, gc.Equals, 1)
+}
+
+func (s *WatcherSuite) TestActions(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	action, err := s.unit.AddAction("fakeaction", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return len(snapshot.Actions) == 1
+	})
+	c.Assert(w.Snapshot().Actions, jc.DeepEquals, []string{action.Id()})
+
+	w.ActionCompleted(action.Id())
+	c.Assert(w.Snapshot().Actions, gc.HasLen, 0)
+}
+
+func (s *WatcherSuite) TestRelations(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+
+	s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	eps, err := s.State.InferEndpoints("wordpress", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	rel, err := s.State.AddRelation(eps...)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.Relations[rel.Id()] == params.Alive
+	})
+
+	err = rel.Destroy()
+	c.Assert(err, jc.ErrorIsNil)
+	snapshot := s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		_, ok := snapshot.Relations[rel.Id()]
+		return !ok
+	})
+	c.Assert(snapshot.Relations, gc.HasLen, 0)
+}
+
+func (s *WatcherSuite) TestUpgradeSeriesStatus(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+	c.Assert(w.Snapshot().UpgradeSeriesStatus, gc.Equals, "")
+
+	err := s.machine.PrepareSeriesUpgrade("trusty")
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.UpgradeSeriesStatus == "prepare started"
+	})
+
+	err = s.unit.SetUpgradeSeriesStatus(state.UpgradeSeriesPrepareCompleted)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return snapshot.UpgradeSeriesStatus == "prepare completed"
+	})
+}
+
+func (s *WatcherSuite) TestDroppedHooks(c *gc.C) {
+	w := s.newWatcher(c)
+	defer statetesting.AssertKillAndWait(c, w)
+	c.Assert(w.Snapshot().DroppedHooks, gc.HasLen, 0)
+
+	err := s.unit.SetHookQueue(nil, []state.QueuedHook{
+		{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.unit.DropQueuedHook("relation-changed:0:mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	snapshot := s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return len(snapshot.DroppedHooks) == 1
+	})
+	c.Assert(snapshot.DroppedHooks, jc.DeepEquals, []params.QueuedHook{{
+		Id:         "relation-changed:0:mysql/0",
+		Kind:       "relation-changed",
+		RelationId: 0,
+		RemoteUnit: "mysql/0",
+	}})
+
+	// Once the agent no longer reports the hook as queued, the
+	// request to drop it is discarded.
+	err = s.unit.SetHookQueue(nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.waitSnapshot(c, w, func(snapshot remotestate.Snapshot) bool {
+		return len(snapshot.DroppedHooks) == 0
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package resolver decides which operation a uniter should run next, by
+// comparing its local state with a snapshot of the remote state.
+package resolver
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+// ErrNoOperation is returned by a Resolver when there is no operation to
+// run until the remote state changes.
+var ErrNoOperation = errors.New("no operations")
+
+// Resolver chooses the next operation a uniter should run, given its
+// local state and a snapshot of the remote state.
+type Resolver interface {
+	// NextOp returns the next operation to run, created using the
+	// supplied factory, or ErrNoOperation if there is nothing to do.
+	NextOp(LocalState, remotestate.Snapshot, operation.Factory) (operation.Operation, error)
+}
+
+// LocalState is the uniter's record of how much of the remote state it
+// has already responded to.
+type LocalState struct {
+	// State is the uniter's current operation state.
+	operation.State
+
+	// CharmURL is the URL of the charm the unit is running, or nil if
+	// it has not yet been deployed.
+	CharmURL *charm.URL
+
+	// ConfigVersion is the remote ConfigVersion most recently handled
+	// by a config-changed hook.
+	ConfigVersion int
+
+	// MeterStatusVersion is the remote MeterStatusVersion most
+	// recently handled by a meter-status-changed hook.
+	MeterStatusVersion int
+
+	// Relations holds the lifecycle state most recently recorded for
+	// each of the service's relations, keyed by relation id.
+	Relations map[int]params.Life
+
+	// Storage holds the version most recently recorded for each of the
+	// unit's storage attachments.
+	Storage map[names.StorageTag]int
+}
@@ -0,0 +1,172 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resolver
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+// NewOpFactory returns an operation.Factory which creates operations
+// with the supplied factory, and records in localState the remote state
+// each operation responds to as it is run. Deploy operations record the
+// charm URL once they have set it in state, and cause every relation to
+// be updated for the new charm. Config-changed and meter-status-changed
+// hooks record the current remote version when they are prepared, so
+// that changes seen before then do not cause them to run again. Relation
+// and storage updates record the remote state they were created for, and
+// actions are removed from the watcher's pending actions, once they are
+// committed.
+func NewOpFactory(factory operation.Factory, watcher remotestate.Watcher, localState *LocalState) operation.Factory {
+	return &opFactory{
+		Factory:    factory,
+		watcher:    watcher,
+		localState: localState,
+	}
+}
+
+type opFactory struct {
+	operation.Factory
+	watcher    remotestate.Watcher
+	localState *LocalState
+}
+
+// NewInstall is part of the operation.Factory interface.
+func (f *opFactory) NewInstall(charmURL *charm.URL) (operation.Operation, error) {
+	return f.wrapDeploy(charmURL)(f.Factory.NewInstall(charmURL))
+}
+
+// NewUpgrade is part of the operation.Factory interface.
+func (f *opFactory) NewUpgrade(charmURL *charm.URL) (operation.Operation, error) {
+	return f.wrapDeploy(charmURL)(f.Factory.NewUpgrade(charmURL))
+}
+
+// NewRevertUpgrade is part of the operation.Factory interface.
+func (f *opFactory) NewRevertUpgrade(charmURL *charm.URL) (operation.Operation, error) {
+	return f.wrapDeploy(charmURL)(f.Factory.NewRevertUpgrade(charmURL))
+}
+
+// NewResolvedUpgrade is part of the operation.Factory interface.
+func (f *opFactory) NewResolvedUpgrade(charmURL *charm.URL) (operation.Operation, error) {
+	return f.wrapDeploy(charmURL)(f.Factory.NewResolvedUpgrade(charmURL))
+}
+
+func (f *opFactory) wrapDeploy(charmURL *charm.URL) func(operation.Operation, error) (operation.Operation, error) {
+	return func(op operation.Operation, err error) (operation.Operation, error) {
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &onPrepareOp{op, func() {
+			f.localState.CharmURL = charmURL
+			f.localState.Relations = nil
+		}}, nil
+	}
+}
+
+// NewRunHook is part of the operation.Factory interface.
+func (f *opFactory) NewRunHook(hookInfo hook.Info) (operation.Operation, error) {
+	op, err := f.Factory.NewRunHook(hookInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch hookInfo.Kind {
+	case hooks.ConfigChanged:
+		return &onPrepareOp{op, func() {
+			f.localState.ConfigVersion = f.watcher.Snapshot().ConfigVersion
+		}}, nil
+	case hooks.MeterStatusChanged:
+		return &onPrepareOp{op, func() {
+			f.localState.MeterStatusVersion = f.watcher.Snapshot().MeterStatusVersion
+		}}, nil
+	}
+	return op, nil
+}
+
+// NewAction is part of the operation.Factory interface.
+func (f *opFactory) NewAction(actionId string) (operation.Operation, error) {
+	op, err := f.Factory.NewAction(actionId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &onCommitOp{op, func() {
+		f.watcher.ActionCompleted(actionId)
+	}}, nil
+}
+
+// NewUpdateRelations is part of the operation.Factory interface.
+func (f *opFactory) NewUpdateRelations(ids []int) (operation.Operation, error) {
+	op, err := f.Factory.NewUpdateRelations(ids)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	remoteRelations := f.watcher.Snapshot().Relations
+	return &onCommitOp{op, func() {
+		if f.localState.Relations == nil {
+			f.localState.Relations = make(map[int]params.Life)
+		}
+		for _, id := range ids {
+			if life, ok := remoteRelations[id]; ok {
+				f.localState.Relations[id] = life
+			}
+		}
+	}}, nil
+}
+
+// NewUpdateStorage is part of the operation.Factory interface.
+func (f *opFactory) NewUpdateStorage(tags []names.StorageTag) (operation.Operation, error) {
+	op, err := f.Factory.NewUpdateStorage(tags)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	remoteStorage := f.watcher.Snapshot().Storage
+	return &onCommitOp{op, func() {
+		if f.localState.Storage == nil {
+			f.localState.Storage = make(map[names.StorageTag]int)
+		}
+		for _, tag := range tags {
+			if version, ok := remoteStorage[tag]; ok {
+				f.localState.Storage[tag] = version
+			}
+		}
+	}}, nil
+}
+
+// onPrepareOp calls onPrepare once the wrapped operation has been
+// successfully prepared.
+type onPrepareOp struct {
+	operation.Operation
+	onPrepare func()
+}
+
+// Prepare is part of the operation.Operation interface.
+func (op *onPrepareOp) Prepare(state operation.State) (*operation.State, error) {
+	st, err := op.Operation.Prepare(state)
+	if err == nil || errors.Cause(err) == operation.ErrSkipExecute {
+		op.onPrepare()
+	}
+	return st, err
+}
+
+// onCommitOp calls onCommit once the wrapped operation has been
+// successfully committed.
+type onCommitOp struct {
+	operation.Operation
+	onCommit func()
+}
+
+// Commit is part of the operation.Operation interface.
+func (op *onCommitOp) Commit(state operation.State) (*operation.State, error) {
+	st, err := op.Operation.Commit(state)
+	if err == nil {
+		op.onCommit()
+	}
+	return st, err
+}
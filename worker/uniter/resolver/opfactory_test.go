@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resolver_test
+
+import (
+	"errors"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+	"github.com/juju/juju/worker/uniter/resolver"
+)
+
+type OpFactorySuite struct {
+	watcher    *mockWatcher
+	localState resolver.LocalState
+	factory    operation.Factory
+}
+
+var _ = gc.Suite(&OpFactorySuite{})
+
+func (s *OpFactorySuite) SetUpTest(c *gc.C) {
+	s.watcher = &mockWatcher{}
+	s.localState = resolver.LocalState{}
+	s.factory = resolver.NewOpFactory(&mockFactory{}, s.watcher, &s.localState)
+}
+
+func (s *OpFactorySuite) TestConfigChangedRecordedOnPrepare(c *gc.C) {
+	op, err := s.factory.NewRunHook(hook.Info{Kind: hooks.ConfigChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(op.String(), gc.Equals, "run config-changed hook")
+
+	// Changes seen before the hook is prepared are handled by it.
+	s.watcher.snapshot.ConfigVersion = 3
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.ConfigVersion, gc.Equals, 3)
+}
+
+func (s *OpFactorySuite) TestMeterStatusChangedRecordedOnPrepare(c *gc.C) {
+	op, err := s.factory.NewRunHook(hook.Info{Kind: hooks.MeterStatusChanged})
+	c.Assert(err, jc.ErrorIsNil)
+	s.watcher.snapshot.MeterStatusVersion = 2
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.MeterStatusVersion, gc.Equals, 2)
+}
+
+func (s *OpFactorySuite) TestPrepareFailureNotRecorded(c *gc.C) {
+	failing := &mockOp{prepareErr: errors.New("blam")}
+	factory := resolver.NewOpFactory(&failingFactory{op: failing}, s.watcher, &s.localState)
+	op, err := factory.NewRunHook(hook.Info{Kind: hooks.ConfigChanged})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.watcher.snapshot.ConfigVersion = 1
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, gc.ErrorMatches, "blam")
+	c.Assert(s.localState.ConfigVersion, gc.Equals, 0)
+}
+
+func (s *OpFactorySuite) TestDeployRecordsCharmURL(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/wordpress-2")
+	s.localState.Relations = map[int]params.Life{0: params.Alive}
+	op, err := s.factory.NewUpgrade(curl)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.CharmURL, gc.IsNil)
+
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.CharmURL, gc.Equals, curl)
+	c.Assert(s.localState.Relations, gc.IsNil)
+}
+
+func (s *OpFactorySuite) TestActionCompletedOnCommit(c *gc.C) {
+	op, err := s.factory.NewAction("action-1")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = op.Prepare(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.watcher.completedActions, gc.HasLen, 0)
+
+	_, err = op.Commit(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.watcher.completedActions, jc.DeepEquals, []string{"action-1"})
+}
+
+func (s *OpFactorySuite) TestRelationsRecordedOnCommit(c *gc.C) {
+	s.watcher.snapshot = remotestate.Snapshot{
+		Relations: map[int]params.Life{0: params.Dying, 1: params.Alive},
+	}
+	op, err := s.factory.NewUpdateRelations([]int{0})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Changes after the operation was created are not recorded.
+	s.watcher.snapshot = remotestate.Snapshot{
+		Relations: map[int]params.Life{0: params.Dead, 1: params.Alive},
+	}
+	_, err = op.Commit(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.Relations, jc.DeepEquals, map[int]params.Life{0: params.Dying})
+}
+
+func (s *OpFactorySuite) TestStorageRecordedOnCommit(c *gc.C) {
+	tag := names.NewStorageTag("data/0")
+	s.watcher.snapshot = remotestate.Snapshot{
+		Storage: map[names.StorageTag]int{tag: 2},
+	}
+	op, err := s.factory.NewUpdateStorage([]names.StorageTag{tag})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.Storage, gc.HasLen, 0)
+
+	_, err = op.Commit(operation.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.localState.Storage, jc.DeepEquals, map[names.StorageTag]int{tag: 2})
+}
+
+// failingFactory returns the supplied operation for any hook.
+type failingFactory struct {
+	mockFactory
+	op operation.Operation
+}
+
+func (f *failingFactory) NewRunHook(hookInfo hook.Info) (operation.Operation, error) {
+	return f.op, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resolver
+
+import (
+	"sort"
+
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+// NewResolver returns a Resolver for a unit whose charm is installed and
+// started. While the unit is alive, it chooses updates to relations and
+// storage, actions, and config-changed and meter-status-changed hooks;
+// once the unit is dying, only actions and config-changed hooks; and once
+// the stop hook has run, only actions. Charm upgrades, which the uniter
+// must run outside its steady state, are reported by UpgradeAvailable.
+func NewResolver() Resolver {
+	return uniterResolver{}
+}
+
+type uniterResolver struct{}
+
+// NextOp is part of the Resolver interface.
+func (uniterResolver) NextOp(
+	localState LocalState,
+	remoteState remotestate.Snapshot,
+	opFactory operation.Factory,
+) (operation.Operation, error) {
+	if localState.Kind != operation.Continue {
+		// Interrupted operations are resumed by the uniter itself.
+		return nil, ErrNoOperation
+	}
+	if localState.Hook != nil && localState.Hook.Kind == hooks.Stop {
+		return nextAction(remoteState, opFactory)
+	}
+	if remoteState.Life == params.Alive {
+		if ids := changedRelations(localState, remoteState); len(ids) > 0 {
+			return opFactory.NewUpdateRelations(ids)
+		}
+		if tags := changedStorage(localState, remoteState); len(tags) > 0 {
+			return opFactory.NewUpdateStorage(tags)
+		}
+	}
+	if op, err := nextAction(remoteState, opFactory); err != ErrNoOperation {
+		return op, err
+	}
+	if remoteState.ConfigVersion != localState.ConfigVersion {
+		return opFactory.NewRunHook(hook.Info{Kind: hooks.ConfigChanged})
+	}
+	if remoteState.Life == params.Alive && remoteState.MeterStatusVersion != localState.MeterStatusVersion {
+		return opFactory.NewRunHook(hook.Info{Kind: hooks.MeterStatusChanged})
+	}
+	return nil, ErrNoOperation
+}
+
+// UpgradeAvailable returns whether the unit should upgrade its charm to
+// the remote charm URL. If mustForce is true, as it is while a hook or
+// upgrade error awaits resolution, only forced upgrades are reported.
+func UpgradeAvailable(localState LocalState, remoteState remotestate.Snapshot, mustForce bool) bool {
+	if remoteState.Life != params.Alive {
+		return false
+	}
+	if localState.CharmURL == nil || remoteState.CharmURL == nil {
+		return false
+	}
+	if *localState.CharmURL == *remoteState.CharmURL {
+		return false
+	}
+	return remoteState.ForceCharmUpgrade || !mustForce
+}
+
+// nextAction returns an operation to run the first pending action.
+func nextAction(remoteState remotestate.Snapshot, opFactory operation.Factory) (operation.Operation, error) {
+	if len(remoteState.Actions) == 0 {
+		return nil, ErrNoOperation
+	}
+	return opFactory.NewAction(remoteState.Actions[0])
+}
+
+// changedRelations returns the sorted ids of the relations whose remote
+// lifecycle state differs from the one recorded locally.
+func changedRelations(localState LocalState, remoteState remotestate.Snapshot) []int {
+	var ids []int
+	for id, life := range remoteState.Relations {
+		if known, ok := localState.Relations[id]; !ok || known != life {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// changedStorage returns the sorted tags of the storage attachments whose
+// remote version differs from the one recorded locally.
+func changedStorage(localState LocalState, remoteState remotestate.Snapshot) []names.StorageTag {
+	var tags []names.StorageTag
+	for tag, version := range remoteState.Storage {
+		if known, ok := localState.Storage[tag]; !ok || known != version {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Sort(storageTags(tags))
+	return tags
+}
+
+type storageTags []names.StorageTag
+
+func (t storageTags) Len() int           { return len(t) }
+func (t storageTags) Less(i, j int) bool { return t[i].Id() < t[j].Id() }
+func (t storageTags) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
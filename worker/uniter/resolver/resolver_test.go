@@ -0,0 +1,188 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resolver_test
+
+import (
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/charm.v4"
+	"gopkg.in/juju/charm.v4/hooks"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+	"github.com/juju/juju/worker/uniter/resolver"
+)
+
+type ResolverSuite struct {
+	localState  resolver.LocalState
+	remoteState remotestate.Snapshot
+}
+
+var _ = gc.Suite(&ResolverSuite{})
+
+func (s *ResolverSuite) SetUpTest(c *gc.C) {
+	curl := charm.MustParseURL("cs:quantal/wordpress-1")
+	s.localState = resolver.LocalState{
+		State: operation.State{
+			Kind:    operation.Continue,
+			Started: true,
+		},
+		CharmURL: curl,
+	}
+	s.remoteState = remotestate.Snapshot{
+		Life:     params.Alive,
+		CharmURL: curl,
+	}
+}
+
+func (s *ResolverSuite) nextOp(c *gc.C) (string, error) {
+	op, err := resolver.NewResolver().NextOp(s.localState, s.remoteState, &mockFactory{})
+	if err != nil {
+		return "", err
+	}
+	return op.String(), nil
+}
+
+func (s *ResolverSuite) assertNextOp(c *gc.C, expect string) {
+	op, err := s.nextOp(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(op, gc.Equals, expect)
+}
+
+func (s *ResolverSuite) assertNoOperation(c *gc.C) {
+	_, err := s.nextOp(c)
+	c.Assert(err, gc.Equals, resolver.ErrNoOperation)
+}
+
+func (s *ResolverSuite) TestNoChanges(c *gc.C) {
+	s.assertNoOperation(c)
+}
+
+func (s *ResolverSuite) TestOperationInProgress(c *gc.C) {
+	s.localState.Kind = operation.RunHook
+	s.localState.Step = operation.Queued
+	s.remoteState.ConfigVersion = 1
+	s.assertNoOperation(c)
+}
+
+func (s *ResolverSuite) TestConfigChanged(c *gc.C) {
+	s.remoteState.ConfigVersion = 2
+	s.localState.ConfigVersion = 1
+	s.assertNextOp(c, "run config-changed hook")
+}
+
+func (s *ResolverSuite) TestMeterStatusChanged(c *gc.C) {
+	s.remoteState.MeterStatusVersion = 1
+	s.assertNextOp(c, "run meter-status-changed hook")
+}
+
+func (s *ResolverSuite) TestActions(c *gc.C) {
+	s.remoteState.Actions = []string{"action-1", "action-2"}
+	s.remoteState.ConfigVersion = 1
+	s.assertNextOp(c, "run action action-1")
+}
+
+func (s *ResolverSuite) TestRelations(c *gc.C) {
+	s.remoteState.Relations = map[int]params.Life{
+		0: params.Alive,
+		1: params.Dying,
+		2: params.Alive,
+	}
+	s.localState.Relations = map[int]params.Life{
+		0: params.Alive,
+		1: params.Alive,
+	}
+	s.remoteState.Actions = []string{"action-1"}
+	s.assertNextOp(c, "update relations [1 2]")
+}
+
+func (s *ResolverSuite) TestStorage(c *gc.C) {
+	s.remoteState.Storage = map[names.StorageTag]int{
+		names.NewStorageTag("data/0"): 1,
+		names.NewStorageTag("data/1"): 2,
+	}
+	s.localState.Storage = map[names.StorageTag]int{
+		names.NewStorageTag("data/0"): 1,
+		names.NewStorageTag("data/1"): 1,
+	}
+	s.assertNextOp(c, "update storage [storage-data-1]")
+}
+
+func (s *ResolverSuite) TestDying(c *gc.C) {
+	s.remoteState.Life = params.Dying
+	s.remoteState.Relations = map[int]params.Life{0: params.Dying}
+	s.remoteState.MeterStatusVersion = 1
+	s.assertNoOperation(c)
+
+	s.remoteState.ConfigVersion = 1
+	s.assertNextOp(c, "run config-changed hook")
+
+	s.remoteState.Actions = []string{"action-1"}
+	s.assertNextOp(c, "run action action-1")
+}
+
+func (s *ResolverSuite) TestStopped(c *gc.C) {
+	s.localState.Hook = &hook.Info{Kind: hooks.Stop}
+	s.remoteState.Life = params.Dying
+	s.remoteState.ConfigVersion = 1
+	s.assertNoOperation(c)
+
+	s.remoteState.Actions = []string{"action-1"}
+	s.assertNextOp(c, "run action action-1")
+}
+
+func (s *ResolverSuite) TestUpgradeAvailable(c *gc.C) {
+	newURL := charm.MustParseURL("cs:quantal/wordpress-2")
+	for i, test := range []struct {
+		about     string
+		localURL  *charm.URL
+		noCharm   bool
+		life      params.Life
+		force     bool
+		mustForce bool
+		expect    bool
+	}{{
+		about:  "unforced upgrade",
+		expect: true,
+	}, {
+		about:     "unforced upgrade, must force",
+		mustForce: true,
+	}, {
+		about:     "forced upgrade, must force",
+		force:     true,
+		mustForce: true,
+		expect:    true,
+	}, {
+		about: "unit dying",
+		life:  params.Dying,
+		force: true,
+	}, {
+		about:   "charm not deployed",
+		noCharm: true,
+	}, {
+		about:    "same charm",
+		localURL: newURL,
+		force:    true,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		localState := s.localState
+		if test.localURL != nil {
+			localState.CharmURL = test.localURL
+		}
+		if test.noCharm {
+			localState.CharmURL = nil
+		}
+		remoteState := s.remoteState
+		remoteState.CharmURL = newURL
+		remoteState.ForceCharmUpgrade = test.force
+		if test.life != "" {
+			remoteState.Life = test.life
+		}
+		available := resolver.UpgradeAvailable(localState, remoteState, test.mustForce)
+		c.Check(available, gc.Equals, test.expect)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package resolver_test
+
+import (
+	"fmt"
+
+	"github.com/juju/names"
+	"gopkg.in/juju/charm.v4"
+
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+// mockFactory creates mockOps whose String methods describe the calls
+// that created them.
+type mockFactory struct {
+	operation.Factory
+}
+
+func (f *mockFactory) NewInstall(charmURL *charm.URL) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("install %s", charmURL)}, nil
+}
+
+func (f *mockFactory) NewUpgrade(charmURL *charm.URL) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("upgrade to %s", charmURL)}, nil
+}
+
+func (f *mockFactory) NewRunHook(hookInfo hook.Info) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("run %s hook", hookInfo.Kind)}, nil
+}
+
+func (f *mockFactory) NewAction(actionId string) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("run action %s", actionId)}, nil
+}
+
+func (f *mockFactory) NewUpdateRelations(ids []int) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("update relations %v", ids)}, nil
+}
+
+func (f *mockFactory) NewUpdateStorage(tags []names.StorageTag) (operation.Operation, error) {
+	return &mockOp{name: fmt.Sprintf("update storage %v", tags)}, nil
+}
+
+// mockOp records the steps run, and returns the configured errors.
+type mockOp struct {
+	name       string
+	prepareErr error
+	commitErr  error
+	steps      []string
+}
+
+func (op *mockOp) String() string {
+	return op.name
+}
+
+func (op *mockOp) Prepare(state operation.State) (*operation.State, error) {
+	op.steps = append(op.steps, "prepare")
+	return nil, op.prepareErr
+}
+
+func (op *mockOp) Execute(state operation.State) (*operation.State, error) {
+	op.steps = append(op.steps, "execute")
+	return nil, nil
+}
+
+func (op *mockOp) Commit(state operation.State) (*operation.State, error) {
+	op.steps = append(op.steps, "commit")
+	return nil, op.commitErr
+}
+
+// mockWatcher returns a fixed snapshot, and records completed actions.
+type mockWatcher struct {
+	remotestate.Watcher
+	snapshot         remotestate.Snapshot
+	completedActions []string
+}
+
+func (w *mockWatcher) Snapshot() remotestate.Snapshot {
+	return w.snapshot
+}
+
+func (w *mockWatcher) ActionCompleted(actionId string) {
+	w.completedActions = append(w.completedActions, actionId)
+}
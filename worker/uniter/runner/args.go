@@ -8,18 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/juju/paths"
 	"github.com/juju/juju/version"
 )
 
-var windowsSuffixOrder = []string{
-	".ps1",
-	".cmd",
-	".bat",
-	".exe",
-}
-
 func lookPath(hook string) (string, error) {
 	hookFile, err := exec.LookPath(hook)
 	if err != nil {
@@ -31,10 +26,11 @@ func lookPath(hook string) (string, error) {
 	return hookFile, nil
 }
 
-// searchHook will search, in order, hooks suffixed with extensions
-// in windowsSuffixOrder. As windows cares about extensions to determine
-// how to execute a file, we will allow several suffixes, with powershell
-// being default.
+// searchHook will search, in order, hooks suffixed with the extensions
+// of the interpreters available on windows. As windows cares about
+// extensions to determine how to execute a file, we will allow several
+// suffixes, with powershell being default. If the charm declares the
+// interpreters its hooks require, only their extensions are searched.
 func searchHook(charmDir, hook string) (string, error) {
 	hookFile := filepath.Join(charmDir, hook)
 	if version.Current.OS != version.Windows {
@@ -42,7 +38,11 @@ func searchHook(charmDir, hook string) (string, error) {
 		// there is no need to look for suffixed hooks
 		return lookPath(hookFile)
 	}
-	for _, suffix := range windowsSuffixOrder {
+	suffixes, err := hookSuffixes(charmDir)
+	if err != nil {
+		return "", err
+	}
+	for _, suffix := range suffixes {
 		file := fmt.Sprintf("%s%s", hookFile, suffix)
 		foundHook, err := lookPath(file)
 		if err != nil {
@@ -57,27 +57,76 @@ func searchHook(charmDir, hook string) (string, error) {
 	return "", &missingHookError{hook}
 }
 
+// hookSuffixes returns the suffixes of the hooks of the charm in the
+// given directory, in the order they should be searched for. Executables
+// are always searched for last.
+func hookSuffixes(charmDir string) ([]string, error) {
+	interpreters := paths.Interpreters(version.Windows)
+	required, err := charmInterpreters(charmDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(required) > 0 {
+		byName := make(map[string]paths.Interpreter)
+		for _, interpreter := range interpreters {
+			byName[interpreter.Name] = interpreter
+		}
+		interpreters = nil
+		for _, name := range required {
+			interpreter, ok := byName[name]
+			if !ok {
+				return nil, errors.NotSupportedf("interpreter %q", name)
+			}
+			interpreters = append(interpreters, interpreter)
+		}
+	}
+	var suffixes []string
+	for _, interpreter := range interpreters {
+		suffixes = append(suffixes, interpreter.Extensions...)
+	}
+	return append(suffixes, ".exe"), nil
+}
+
+// charmInterpreters returns the names of the interpreters required by
+// the charm in the given directory.
+func charmInterpreters(charmDir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(charmDir, "metadata.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	return paths.ReadInterpreters(f)
+}
+
 // hookCommand constructs an appropriate command to be passed to
 // exec.Command(). The exec package uses cmd.exe as default on windows.
-// cmd.exe does not know how to execute ps1 files by default, and
-// powershell needs a few flags to allow execution (-ExecutionPolicy)
-// and propagate error levels (-File). .cmd and .bat files can be run
-// directly.
+// cmd.exe does not know how to execute ps1 or py files by default, so
+// they are passed to the interpreter for their extension. .cmd, .bat
+// and .exe files can be run directly.
 func hookCommand(hook string) []string {
 	if version.Current.OS != version.Windows {
 		// we are not running on windows,
 		// just return the hook name
 		return []string{hook}
 	}
-	if strings.HasSuffix(hook, ".ps1") {
-		return []string{
-			"powershell.exe",
-			"-NonInteractive",
-			"-ExecutionPolicy",
-			"RemoteSigned",
-			"-File",
-			hook,
+	ext := filepath.Ext(hook)
+	for _, interpreter := range paths.Interpreters(version.Windows) {
+		for _, interpreterExt := range interpreter.Extensions {
+			if ext == interpreterExt && len(interpreter.Command) > 0 {
+				command := append([]string{}, interpreter.Command...)
+				return append(command, hook)
+			}
 		}
 	}
 	return []string{hook}
 }
+
+// workloadContainerCommand returns a command that executes the given
+// command in the named workload container. The command keeps its
+// environment and working directory, which the container shares with
+// the machine.
+func workloadContainerCommand(container string, command []string) []string {
+	return append([]string{"lxc-attach", "--name", container, "--keep-env", "--"}, command...)
+}
@@ -4,6 +4,7 @@
 package runner_test
 
 import (
+	"io/ioutil"
 	"path/filepath"
 	"runtime"
 
@@ -99,3 +100,63 @@ func (s *WindowsHookSuite) TestSearchHookWindowsError(c *gc.C) {
 	c.Assert(err.Error(), gc.Equals, filepath.FromSlash("hooks/something-happened does not exist"))
 	c.Assert(obtained, gc.Equals, "")
 }
+
+func (s *WindowsHookSuite) TestHookCommandPythonScript(c *gc.C) {
+	restorer := envtesting.PatchValue(&version.Current.OS, version.Windows)
+	defer restorer()
+
+	c.Assert(runner.HookCommand("somehook.py"), gc.DeepEquals, []string{"python.exe", "somehook.py"})
+	c.Assert(runner.HookCommand("somehook.exe"), gc.DeepEquals, []string{"somehook.exe"})
+}
+
+func (s *WindowsHookSuite) TestSearchHookWindowsPython(c *gc.C) {
+	restorer := envtesting.PatchValue(&version.Current.OS, version.Windows)
+	defer restorer()
+
+	charmDir := c.MkDir()
+	makeCharm(c, hookSpec{
+		dir:  "hooks",
+		name: "something-happened.py",
+		perm: 0755,
+	}, charmDir)
+
+	obtained, err := runner.SearchHook(charmDir, filepath.Join("hooks", "something-happened"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, gc.Equals, filepath.Join(charmDir, "hooks", "something-happened.py"))
+}
+
+func (s *WindowsHookSuite) TestSearchHookWindowsDeclaredInterpreters(c *gc.C) {
+	restorer := envtesting.PatchValue(&version.Current.OS, version.Windows)
+	defer restorer()
+
+	charmDir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(charmDir, "metadata.yaml"), []byte("interpreters: [python]\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	makeCharm(c, hookSpec{
+		dir:  "hooks",
+		name: "something-happened.ps1",
+		perm: 0755,
+	}, charmDir)
+	makeCharm(c, hookSpec{
+		name: filepath.Join("hooks", "something-happened.py"),
+		perm: 0755,
+	}, charmDir)
+
+	// Only the hooks of the declared interpreters are run, even though
+	// powershell hooks are otherwise preferred.
+	obtained, err := runner.SearchHook(charmDir, filepath.Join("hooks", "something-happened"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(obtained, gc.Equals, filepath.Join(charmDir, "hooks", "something-happened.py"))
+}
+
+func (s *WindowsHookSuite) TestSearchHookWindowsUnknownInterpreter(c *gc.C) {
+	restorer := envtesting.PatchValue(&version.Current.OS, version.Windows)
+	defer restorer()
+
+	charmDir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(charmDir, "metadata.yaml"), []byte("interpreters: [bash]\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = runner.SearchHook(charmDir, filepath.Join("hooks", "something-happened"))
+	c.Assert(err, gc.ErrorMatches, `interpreter "bash" not supported`)
+}
@@ -136,6 +136,24 @@ type HookContext struct {
 
 	// storageId is the tag of the storage instance associated with the running hook.
 	storageTag names.StorageTag
+
+	// secretId is the ID of the secret associated with the running hook.
+	secretId string
+
+	// hookOutputLimit is the maximum number of bytes of the hook's
+	// output kept with its result.
+	hookOutputLimit int
+
+	// hookOutput holds the tail of the output written by the hook, if
+	// it was run, to be recorded with its result when the context is
+	// flushed.
+	hookOutput *hookOutput
+}
+
+// hookOutput holds the tail of the output written by a hook.
+type hookOutput struct {
+	output    string
+	truncated bool
 }
 
 func (ctx *HookContext) RequestReboot(priority jujuc.RebootPriority) error {
@@ -181,6 +199,18 @@ func (ctx *HookContext) Id() string {
 	return ctx.id
 }
 
+// HookOutputLimit returns the maximum number of bytes of the hook's
+// output kept with its result.
+func (ctx *HookContext) HookOutputLimit() int {
+	return ctx.hookOutputLimit
+}
+
+// SetHookOutput records the tail of the output written by the hook, so
+// that it is kept with the hook's result when the context is flushed.
+func (ctx *HookContext) SetHookOutput(output string, truncated bool) {
+	ctx.hookOutput = &hookOutput{output, truncated}
+}
+
 func (ctx *HookContext) UnitName() string {
 	return ctx.unitName
 }
@@ -197,6 +227,38 @@ func (ctx *HookContext) AvailabilityZone() (string, bool) {
 	return ctx.availabilityzone, ctx.availabilityzone != ""
 }
 
+func (ctx *HookContext) NetworkInfo(bindingName string) (params.NetworkInfo, error) {
+	return ctx.unit.NetworkInfo(bindingName)
+}
+
+func (ctx *HookContext) GoalState() (params.GoalState, error) {
+	return ctx.unit.GoalState()
+}
+
+func (ctx *HookContext) SetUnitWorkloadVersion(version string) error {
+	return ctx.unit.SetWorkloadVersion(version)
+}
+
+func (ctx *HookContext) CreateSecret(label string, data map[string]string, rotateInterval time.Duration) (string, error) {
+	return ctx.unit.CreateSecret(label, data, rotateInterval)
+}
+
+func (ctx *HookContext) UpdateSecret(id string, data map[string]string) error {
+	return ctx.unit.UpdateSecret(id, data)
+}
+
+func (ctx *HookContext) SecretValue(id string) (map[string]string, error) {
+	return ctx.unit.SecretValue(id)
+}
+
+func (ctx *HookContext) GrantSecret(id string, relationId int) error {
+	r, found := ctx.relations[relationId]
+	if !found {
+		return errors.Errorf("unknown relation id: %v", relationId)
+	}
+	return ctx.unit.GrantSecret(id, r.ru.Relation().Tag())
+}
+
 func (ctx *HookContext) HookStorage() (jujuc.ContextStorage, bool) {
 	return ctx.Storage(ctx.storageTag)
 }
@@ -331,6 +393,16 @@ func (ctx *HookContext) AddMetric(key, value string, created time.Time) error {
 	return nil
 }
 
+// AppendActionOutput sends a chunk of output written by the running
+// action to the given stream to the state server, so that it can be
+// followed before the action completes.
+func (ctx *HookContext) AppendActionOutput(stream, data string) error {
+	if ctx.actionData == nil {
+		return errors.New("not running an action")
+	}
+	return ctx.state.AppendActionOutput(ctx.actionData.ActionTag, stream, data)
+}
+
 // ActionData returns the context's internal action data. It's meant to be
 // transitory; it exists to allow uniter and runner code to keep working as
 // it did; it should be considered deprecated, and not used by new clients.
@@ -367,6 +439,9 @@ func (context *HookContext) HookVars(paths Paths) []string {
 			"JUJU_REMOTE_UNIT="+context.remoteUnitName,
 		)
 	}
+	if context.secretId != "" {
+		vars = append(vars, "JUJU_SECRET_ID="+context.secretId)
+	}
 	if context.actionData != nil {
 		vars = append(vars,
 			"JUJU_ACTION_NAME="+context.actionData.ActionName,
@@ -401,6 +476,14 @@ func (ctx *HookContext) handleReboot(err *error) {
 func (ctx *HookContext) FlushContext(process string, ctxErr error) (err error) {
 	writeChanges := ctxErr == nil
 
+	// Record the result of a hook that ran once any errors in
+	// writing its changes are known.
+	if ctx.actionData == nil && ctx.hookOutput != nil {
+		defer func() {
+			ctx.recordHookResult(process, err)
+		}()
+	}
+
 	// In the case of Actions, handle any errors using finalizeAction.
 	if ctx.actionData != nil {
 		// If we had an error in err at this point, it's part of the
@@ -486,6 +569,29 @@ func (ctx *HookContext) FlushContext(process string, ctxErr error) (err error) {
 	return ctxErr
 }
 
+// recordHookResult records the outcome, and the tail of the output,
+// of the hook that ran in the context. Failing to record it is logged,
+// but does not fail the hook.
+func (ctx *HookContext) recordHookResult(hookName string, hookErr error) {
+	result := params.HookResult{
+		Hook:      hookName,
+		Time:      time.Now().UTC(),
+		Output:    ctx.hookOutput.output,
+		Truncated: ctx.hookOutput.truncated,
+	}
+	switch errors.Cause(hookErr) {
+	case nil, ErrReboot, ErrRequeueAndReboot:
+	default:
+		result.Error = hookErr.Error()
+	}
+	err := ctx.unit.SetHookResult(result)
+	if errors.IsNotImplemented(err) {
+		logger.Debugf("not recording hook result: %v", err)
+	} else if err != nil {
+		logger.Warningf("cannot record result of %q hook: %v", hookName, err)
+	}
+}
+
 // finalizeAction passes back the final status of an Action hook to state.
 // It wraps any errors which occurred in normal behavior of the Action run;
 // only errors passed in unhandledErr will be returned.
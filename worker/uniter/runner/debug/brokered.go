@@ -0,0 +1,173 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/juju/utils/set"
+)
+
+// BrokeredSession represents a "juju debug-hooks" session started
+// through the API server. Rather than running in tmux on the unit's
+// machine, each hook being debugged is run in an interactive shell
+// whose input and output are relayed to the client.
+type BrokeredSession struct {
+	unit  string
+	hooks set.Strings
+	conn  io.ReadWriteCloser
+
+	// input receives the data sent by the client.
+	input chan []byte
+
+	// done is closed when the client disconnects or
+	// the session is closed.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// brokeredSessions holds the brokered sessions in progress, by unit.
+var brokeredSessions = struct {
+	mu       sync.Mutex
+	sessions map[string]*BrokeredSession
+}{sessions: make(map[string]*BrokeredSession)}
+
+// StartBrokeredSession starts a session, relayed over conn, in which
+// the given hooks of the context's unit are debugged; if no hooks are
+// given, all hooks are debugged. Any session already in progress for
+// the unit is closed. The session lasts until the client disconnects
+// or the session is closed.
+func (c *HooksContext) StartBrokeredSession(hooks []string, conn io.ReadWriteCloser) *BrokeredSession {
+	s := &BrokeredSession{
+		unit:  c.Unit,
+		hooks: set.NewStrings(hooks...),
+		conn:  conn,
+		input: make(chan []byte),
+		done:  make(chan struct{}),
+	}
+	brokeredSessions.mu.Lock()
+	old := brokeredSessions.sessions[c.Unit]
+	brokeredSessions.sessions[c.Unit] = s
+	brokeredSessions.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	go s.readInput()
+	fmt.Fprintf(conn, "Connected to %s. Waiting for a hook to debug...\n", c.Unit)
+	return s
+}
+
+// FindBrokeredSession returns the brokered session in progress for
+// the context's unit, or nil if there is none.
+func (c *HooksContext) FindBrokeredSession() *BrokeredSession {
+	brokeredSessions.mu.Lock()
+	defer brokeredSessions.mu.Unlock()
+	return brokeredSessions.sessions[c.Unit]
+}
+
+// readInput reads the data sent by the client until it disconnects,
+// so that it can be passed to whichever hook shell is running.
+func (s *BrokeredSession) readInput() {
+	defer s.Close()
+	for {
+		buf := make([]byte, 4096)
+		n, err := s.conn.Read(buf)
+		if n > 0 {
+			select {
+			case s.input <- buf[:n]:
+			case <-s.done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Done returns a channel that is closed when the session ends.
+func (s *BrokeredSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the session, closing its connection to the client.
+func (s *BrokeredSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		brokeredSessions.mu.Lock()
+		if brokeredSessions.sessions[s.unit] == s {
+			delete(brokeredSessions.sessions, s.unit)
+		}
+		brokeredSessions.mu.Unlock()
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// MatchHook returns true if the specified hook name matches
+// the hook specified by the debug-hooks client.
+func (s *BrokeredSession) MatchHook(hookName string) bool {
+	return s.hooks.IsEmpty() || s.hooks.Contains(hookName)
+}
+
+// RunHook "runs" the hook with the specified name by starting a shell,
+// with the hook's environment, whose input and output are relayed to
+// the client. The hook completes when the shell exits, or when the
+// client disconnects.
+func (s *BrokeredSession) RunHook(hookName, charmDir string, env []string) error {
+	env = append(env,
+		"JUJU_HOOK_NAME="+hookName,
+		fmt.Sprintf("PS1=%s:%s %% ", s.unit, hookName),
+	)
+	cmd := exec.Command("/bin/bash", "--noprofile", "--norc", "-i")
+	cmd.Env = env
+	cmd.Dir = charmDir
+	cmd.Stdout = s.conn
+	cmd.Stderr = s.conn
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(s.conn, brokeredWelcome, hookName, hookName)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	go func() {
+		defer stdin.Close()
+		for {
+			select {
+			case data := <-s.input:
+				if _, err := stdin.Write(data); err != nil {
+					return
+				}
+			case <-s.done:
+				// The client has gone, so kill the hook
+				// shell in case closing its input does
+				// not make it exit.
+				cmd.Process.Kill()
+				return
+			case <-exited:
+				return
+			}
+		}
+	}()
+	err = cmd.Wait()
+	close(exited)
+	fmt.Fprintf(s.conn, "Hook %q finished. Waiting for a hook to debug...\n", hookName)
+	return err
+}
+
+const brokeredWelcome = `
+This is a Juju debug-hooks session for the %q hook. Remember:
+1. You need to execute the hook manually if you want it to run,
+   for example with ./hooks/%s.
+2. When you are finished, run 'exit' to allow Juju to continue.
+   The hook's result is the exit status of this shell.
+
+`
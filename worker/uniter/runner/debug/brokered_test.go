@@ -0,0 +1,118 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package debug
+
+import (
+	"bufio"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+)
+
+type BrokeredSessionSuite struct {
+	testing.BaseSuite
+	ctx    *HooksContext
+	client io.ReadWriteCloser
+	conn   io.ReadWriteCloser
+}
+
+var _ = gc.Suite(&BrokeredSessionSuite{})
+
+type pipeConn struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (c pipeConn) Close() error {
+	c.PipeReader.Close()
+	return c.PipeWriter.Close()
+}
+
+func (s *BrokeredSessionSuite) SetUpTest(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("bug 1403084: Currently debug does not work on windows")
+	}
+	s.BaseSuite.SetUpTest(c)
+	s.ctx = NewHooksContext("foo/8")
+	fromClient, toAgent := io.Pipe()
+	fromAgent, toClient := io.Pipe()
+	s.client = pipeConn{fromAgent, toAgent}
+	s.conn = pipeConn{fromClient, toClient}
+}
+
+func (s *BrokeredSessionSuite) start(c *gc.C, hooks ...string) (*BrokeredSession, *bufio.Reader) {
+	started := make(chan *BrokeredSession, 1)
+	go func() {
+		started <- s.ctx.StartBrokeredSession(hooks, s.conn)
+	}()
+	output := bufio.NewReader(s.client)
+	line, err := output.ReadString('\n')
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(line, gc.Equals, "Connected to foo/8. Waiting for a hook to debug...\n")
+	session := <-started
+	s.AddCleanup(func(*gc.C) { session.Close() })
+	return session, output
+}
+
+func (s *BrokeredSessionSuite) TestFindBrokeredSession(c *gc.C) {
+	c.Assert(s.ctx.FindBrokeredSession(), gc.IsNil)
+	session, _ := s.start(c, "install")
+	c.Assert(s.ctx.FindBrokeredSession(), gc.Equals, session)
+	c.Assert(session.MatchHook("install"), jc.IsTrue)
+	c.Assert(session.MatchHook("start"), jc.IsFalse)
+
+	c.Assert(session.Close(), jc.ErrorIsNil)
+	c.Assert(s.ctx.FindBrokeredSession(), gc.IsNil)
+}
+
+func (s *BrokeredSessionSuite) TestMatchAllHooks(c *gc.C) {
+	session, _ := s.start(c)
+	c.Assert(session.MatchHook("install"), jc.IsTrue)
+	c.Assert(session.MatchHook("start"), jc.IsTrue)
+}
+
+func (s *BrokeredSessionSuite) TestClientDisconnectEndsSession(c *gc.C) {
+	session, _ := s.start(c)
+	s.client.Close()
+	select {
+	case <-session.Done():
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for session to end")
+	}
+	c.Assert(s.ctx.FindBrokeredSession(), gc.IsNil)
+}
+
+func (s *BrokeredSessionSuite) TestRunHook(c *gc.C) {
+	session, output := s.start(c)
+	charmDir := c.MkDir()
+	result := make(chan error, 1)
+	go func() {
+		result <- session.RunHook("install", charmDir, []string{"PATH=/bin:/usr/bin"})
+	}()
+	_, err := io.WriteString(s.client, "echo hook is $JUJU_HOOK_NAME in $PWD; exit 3\n")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var lines []string
+	for {
+		line, err := output.ReadString('\n')
+		c.Assert(err, jc.ErrorIsNil)
+		lines = append(lines, line)
+		if strings.HasPrefix(line, `Hook "install" finished.`) {
+			break
+		}
+	}
+	c.Assert(strings.Join(lines, ""), jc.Contains, "hook is install in "+charmDir+"\n")
+	select {
+	case err := <-result:
+		c.Assert(err, gc.ErrorMatches, "exit status 3")
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for hook")
+	}
+}
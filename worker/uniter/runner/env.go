@@ -45,14 +45,16 @@ func centosEnv(paths Paths) []string {
 
 // windowsEnv adds windows specific environment variables. PSModulePath
 // helps hooks use normal imports instead of dot sourcing modules
-// its a convenience variable. The PATH variable delimiter is
-// a semicolon instead of a colon
+// its a convenience variable; PYTHONPATH does the same for python
+// hooks. The PATH variable delimiter is a semicolon instead of a colon
 func windowsEnv(paths Paths) []string {
 	charmDir := paths.GetCharmDir()
 	charmModules := filepath.Join(charmDir, "lib", "Modules")
+	charmLib := filepath.Join(charmDir, "lib")
 	return []string{
 		"Path=" + paths.GetToolsDir() + ";" + os.Getenv("Path"),
 		"PSModulePath=" + os.Getenv("PSModulePath") + ";" + charmModules,
+		"PYTHONPATH=" + os.Getenv("PYTHONPATH") + ";" + charmLib,
 	}
 }
 
@@ -124,9 +124,11 @@ func (s *EnvSuite) TestEnvWindows(c *gc.C) {
 	s.PatchValue(&version.Current.OS, version.Windows)
 	os.Setenv("Path", "foo;bar")
 	os.Setenv("PSModulePath", "ping;pong")
+	os.Setenv("PYTHONPATH", "spam")
 	windowsVars := []string{
 		"Path=path-to-tools;foo;bar",
 		"PSModulePath=ping;pong;" + filepath.FromSlash("path-to-charm/lib/Modules"),
+		"PYTHONPATH=spam;" + filepath.FromSlash("path-to-charm/lib"),
 	}
 
 	ctx, contextVars := s.getContext()
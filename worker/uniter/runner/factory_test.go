@@ -278,6 +278,19 @@ func (s *FactorySuite) TestNewHookRunner(c *gc.C) {
 	s.AssertNotStorageContext(c, ctx)
 }
 
+func (s *FactorySuite) TestNewHookRunnerWithSecret(c *gc.C) {
+	rnr, err := s.factory.NewHookRunner(hook.Info{Kind: hook.SecretRotate, SecretId: "deadbeef"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.AssertPaths(c, rnr)
+	ctx := rnr.Context()
+	s.AssertCoreContext(c, ctx)
+	s.AssertNotActionContext(c, ctx)
+	s.AssertNotRelationContext(c, ctx)
+	s.AssertNotStorageContext(c, ctx)
+	vars := strings.Join(ctx.HookVars(s.paths), "\n")
+	c.Assert(vars, jc.Contains, "\nJUJU_SECRET_ID=deadbeef")
+}
+
 func (s *FactorySuite) TestNewHookRunnerWithBadHook(c *gc.C) {
 	rnr, err := s.factory.NewHookRunner(hook.Info{})
 	c.Assert(rnr, gc.IsNil)
@@ -241,3 +241,32 @@ func (s *FlushContextSuite) TestRunHookOpensAndClosesPendingPorts(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(unitRanges, jc.DeepEquals, expectUnitRanges)
 }
+
+func (s *FlushContextSuite) TestFlushRecordsHookResult(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := s.getHookContext(c, uuid.String(), -1, "", noProxies)
+
+	ctx.SetHookOutput("cannot write config\n", true)
+	err = ctx.FlushContext("config-changed", errors.New("exit status 1"))
+	c.Assert(err, gc.ErrorMatches, "exit status 1")
+
+	result, err := s.unit.LastHookResult()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Hook, gc.Equals, "config-changed")
+	c.Assert(result.Error, gc.Equals, "exit status 1")
+	c.Assert(result.Output, gc.Equals, "cannot write config\n")
+	c.Assert(result.Truncated, jc.IsTrue)
+}
+
+func (s *FlushContextSuite) TestFlushWithoutHookOutputRecordsNoResult(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := s.getHookContext(c, uuid.String(), -1, "", noProxies)
+
+	err = ctx.FlushContext("run commands", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.unit.LastHookResult()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
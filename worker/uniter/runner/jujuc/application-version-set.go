@@ -0,0 +1,51 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// ApplicationVersionSetCommand implements the application-version-set
+// command.
+type ApplicationVersionSetCommand struct {
+	cmd.CommandBase
+	ctx     Context
+	version string
+}
+
+func NewApplicationVersionSetCommand(ctx Context) cmd.Command {
+	return &ApplicationVersionSetCommand{ctx: ctx}
+}
+
+func (c *ApplicationVersionSetCommand) Info() *cmd.Info {
+	doc := `
+application-version-set records the version of the software the unit
+runs, such as the version of the package the charm installed. The
+version is shown for the unit's service by juju status.
+`
+	return &cmd.Info{
+		Name:    "application-version-set",
+		Args:    "<new-version>",
+		Purpose: "specify which version of the application is deployed",
+		Doc:     doc,
+	}
+}
+
+func (c *ApplicationVersionSetCommand) SetFlags(f *gnuflag.FlagSet) {
+}
+
+func (c *ApplicationVersionSetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no version specified")
+	}
+	c.version = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ApplicationVersionSetCommand) Run(ctx *cmd.Context) error {
+	return errors.Trace(c.ctx.SetUnitWorkloadVersion(c.version))
+}
@@ -0,0 +1,59 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type ApplicationVersionSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&ApplicationVersionSetSuite{})
+
+func (s *ApplicationVersionSetSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("application-version-set"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *ApplicationVersionSetSuite) TestSetVersion(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"5.7.9"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Check(bufferString(ctx.Stdout), gc.Equals, "")
+	c.Check(hctx.version, gc.Equals, "5.7.9")
+}
+
+func (s *ApplicationVersionSetSuite) TestSetVersionError(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.shouldError = true
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"5.7.9"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot set workload version\n")
+	c.Check(hctx.version, gc.Equals, "")
+}
+
+func (s *ApplicationVersionSetSuite) TestNoVersion(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	err := testing.InitCommand(com, nil)
+	c.Assert(err, gc.ErrorMatches, "no version specified")
+}
+
+func (s *ApplicationVersionSetSuite) TestUnknownArg(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	err := testing.InitCommand(com, []string{"5.7.9", "blah"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["blah"\]`)
+}
@@ -45,6 +45,38 @@ type Context interface {
 	// AvailabilityZone returns the executing unit's availablilty zone.
 	AvailabilityZone() (string, bool)
 
+	// NetworkInfo returns the addresses the executing unit is reached
+	// on and connects from for the given endpoint binding.
+	NetworkInfo(bindingName string) (params.NetworkInfo, error)
+
+	// GoalState returns the units the executing unit's service is
+	// meant to have, and the services and units it is meant to be
+	// related to.
+	GoalState() (params.GoalState, error)
+
+	// SetUnitWorkloadVersion records the version of the software run
+	// by the executing unit.
+	SetUnitWorkloadVersion(version string) error
+
+	// CreateSecret stores the supplied data as a new secret owned by
+	// the executing unit's service, and returns the secret's id. A
+	// non-zero rotateInterval causes the secret-rotate hook to run on
+	// the service's leader whenever the secret is due rotation.
+	CreateSecret(label string, data map[string]string, rotateInterval time.Duration) (string, error)
+
+	// UpdateSecret replaces the data held by a secret owned by the
+	// executing unit's service.
+	UpdateSecret(id string, data map[string]string) error
+
+	// SecretValue returns the data held by a secret owned by, or
+	// granted to, the executing unit's service.
+	SecretValue(id string) (map[string]string, error)
+
+	// GrantSecret allows the service at the other end of the relation
+	// with the supplied id to read a secret owned by the executing
+	// unit's service.
+	GrantSecret(id string, relationId int) error
+
 	// OpenPorts marks the supplied port range for opening when the
 	// executing unit's service is exposed.
 	OpenPorts(protocol string, fromPort, toPort int) error
@@ -0,0 +1,80 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// GoalStateCommand implements the goal-state command.
+type GoalStateCommand struct {
+	cmd.CommandBase
+	ctx Context
+	out cmd.Output
+}
+
+func NewGoalStateCommand(ctx Context) cmd.Command {
+	return &GoalStateCommand{ctx: ctx}
+}
+
+func (c *GoalStateCommand) Info() *cmd.Info {
+	doc := `
+goal-state prints the units the unit's service is meant to have and,
+for each of the service's relations, the services and units it is meant
+to be related to. Units are listed as soon as they are added to the
+environment, before they are deployed or join any relation, so a charm
+can tell whether it should wait for more peers or related units.
+`
+	return &cmd.Info{
+		Name:    "goal-state",
+		Purpose: "print the planned units and relations of the service",
+		Doc:     doc,
+	}
+}
+
+func (c *GoalStateCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "yaml", cmd.DefaultFormatters)
+}
+
+func (c *GoalStateCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+type goalStateStatus struct {
+	Status string `json:"status" yaml:"status"`
+}
+
+type unitsGoalState map[string]goalStateStatus
+
+type formattedGoalState struct {
+	Units     unitsGoalState            `json:"units" yaml:"units"`
+	Relations map[string]unitsGoalState `json:"relations" yaml:"relations"`
+}
+
+func (c *GoalStateCommand) Run(ctx *cmd.Context) error {
+	goalState, err := c.ctx.GoalState()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	out := formattedGoalState{
+		Units:     formatUnitsGoalState(goalState.Units),
+		Relations: make(map[string]unitsGoalState),
+	}
+	for name, units := range goalState.Relations {
+		out.Relations[name] = formatUnitsGoalState(units)
+	}
+	return c.out.Write(ctx, out)
+}
+
+func formatUnitsGoalState(in params.UnitsGoalState) unitsGoalState {
+	out := make(unitsGoalState)
+	for name, status := range in {
+		out[name] = goalStateStatus{Status: status.Status}
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type GoalStateSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&GoalStateSuite{})
+
+var goalStateTests = []struct {
+	args []string
+	out  string
+}{{
+	nil,
+	`
+units:
+  u/0:
+    status: running
+  u/1:
+    status: busy
+relations:
+  db:
+    mysql:
+      status: joined
+    mysql/0:
+      status: waiting
+`[1:],
+}, {
+	[]string{"--format", "json"},
+	`{"units":{"u/0":{"status":"running"},"u/1":{"status":"busy"}},` +
+		`"relations":{"db":{"mysql":{"status":"joined"},"mysql/0":{"status":"waiting"}}}}` + "\n",
+}}
+
+func (s *GoalStateSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("goal-state"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *GoalStateSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range goalStateTests {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *GoalStateSuite) TestError(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.shouldError = true
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, nil)
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot get goal state\n")
+}
+
+func (s *GoalStateSuite) TestUnknownArg(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	err := testing.InitCommand(com, []string{"blah"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["blah"\]`)
+}
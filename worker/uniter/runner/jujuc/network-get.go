@@ -0,0 +1,71 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// NetworkGetCommand implements the network-get command.
+type NetworkGetCommand struct {
+	cmd.CommandBase
+	ctx            Context
+	bindingName    string
+	primaryAddress bool
+	out            cmd.Output
+}
+
+func NewNetworkGetCommand(ctx Context) cmd.Command {
+	return &NetworkGetCommand{ctx: ctx}
+}
+
+func (c *NetworkGetCommand) Info() *cmd.Info {
+	doc := `
+network-get returns the network information of the unit for the given
+binding, which is the name of one of the charm's relations.
+
+The ingress addresses are those other units use to connect to this
+unit; the egress subnets hold the addresses this unit's connections
+come from. With --primary-address, only the address other units
+should use to connect to this unit is printed.
+`
+	return &cmd.Info{
+		Name:    "network-get",
+		Args:    "<binding-name>",
+		Purpose: "get network information for a binding",
+		Doc:     doc,
+	}
+}
+
+func (c *NetworkGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.BoolVar(&c.primaryAddress, "primary-address", false, "print only the primary address")
+}
+
+func (c *NetworkGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no binding name specified")
+	}
+	c.bindingName = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *NetworkGetCommand) Run(ctx *cmd.Context) error {
+	info, err := c.ctx.NetworkInfo(c.bindingName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if c.primaryAddress {
+		if len(info.IngressAddresses) == 0 {
+			return errors.Errorf("no addresses for binding %q", c.bindingName)
+		}
+		return c.out.Write(ctx, info.IngressAddresses[0])
+	}
+	return c.out.Write(ctx, map[string]interface{}{
+		"ingress-addresses": info.IngressAddresses,
+		"egress-subnets":    info.EgressSubnets,
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type NetworkGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&NetworkGetSuite{})
+
+var networkGetTests = []struct {
+	args []string
+	out  string
+}{{
+	[]string{"db"},
+	"egress-subnets:\n- 192.168.0.99/32\ningress-addresses:\n- 192.168.0.99\n- 10.0.0.99\n",
+}, {
+	[]string{"db", "--format", "json"},
+	`{"egress-subnets":["192.168.0.99/32"],"ingress-addresses":["192.168.0.99","10.0.0.99"]}` + "\n",
+}, {
+	[]string{"db", "--primary-address"},
+	"192.168.0.99\n",
+}, {
+	[]string{"--primary-address", "db", "--format", "json"},
+	`"192.168.0.99"` + "\n",
+}}
+
+func (s *NetworkGetSuite) createCommand(c *gc.C) cmd.Command {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, cmdString("network-get"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *NetworkGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range networkGetTests {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *NetworkGetSuite) TestUnknownBinding(c *gc.C) {
+	com := s.createCommand(c)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"nonsense"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: binding \"nonsense\" not found\n")
+}
+
+func (s *NetworkGetSuite) TestNoBinding(c *gc.C) {
+	com := s.createCommand(c)
+	err := testing.InitCommand(com, nil)
+	c.Assert(err, gc.ErrorMatches, "no binding name specified")
+}
+
+func (s *NetworkGetSuite) TestUnknownArg(c *gc.C) {
+	com := s.createCommand(c)
+	err := testing.InitCommand(com, []string{"db", "blah"})
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["blah"\]`)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+)
+
+// SecretAddCommand implements the secret-add command.
+type SecretAddCommand struct {
+	cmd.CommandBase
+	ctx            Context
+	label          string
+	rotateInterval time.Duration
+	data           map[string]string
+	out            cmd.Output
+}
+
+func NewSecretAddCommand(ctx Context) cmd.Command {
+	return &SecretAddCommand{ctx: ctx}
+}
+
+func (c *SecretAddCommand) Info() *cmd.Info {
+	doc := `
+secret-add stores the supplied key/value pairs as a new secret owned by
+the unit's service, and prints the id by which the secret is referred
+to. Secret values are encrypted by the state server, and may only be
+read by the owning service and by services the secret is granted to
+with secret-grant.
+
+With --rotate, the secret-rotate hook is run on the service's leader
+each time the given interval has passed since the secret was last
+rotated; the hook should set new values with secret-set.
+`
+	return &cmd.Info{
+		Name:    "secret-add",
+		Args:    "<key>=<value> [...]",
+		Purpose: "add a new secret",
+		Doc:     doc,
+	}
+}
+
+func (c *SecretAddCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+	f.StringVar(&c.label, "label", "", "a label describing the secret")
+	f.DurationVar(&c.rotateInterval, "rotate", 0, "how often the secret should be rotated")
+}
+
+func (c *SecretAddCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no secret data specified")
+	}
+	if c.rotateInterval < 0 {
+		return errors.New("rotate interval must not be negative")
+	}
+	c.data, err = keyvalues.Parse(args, false)
+	return err
+}
+
+func (c *SecretAddCommand) Run(ctx *cmd.Context) error {
+	id, err := c.ctx.CreateSecret(c.label, c.data, c.rotateInterval)
+	if err != nil {
+		return errors.Annotate(err, "cannot add secret")
+	}
+	return c.out.Write(ctx, id)
+}
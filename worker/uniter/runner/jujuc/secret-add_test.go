@@ -0,0 +1,72 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type SecretAddSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&SecretAddSuite{})
+
+func (s *SecretAddSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("secret-add"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *SecretAddSuite) TestAddSecret(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"--label", "admin", "--rotate", "24h", "user=admin", "password=sekrit"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Check(bufferString(ctx.Stdout), gc.Equals, "secret-0\n")
+	c.Check(hctx.secrets, jc.DeepEquals, map[string]*fakeSecret{
+		"secret-0": {
+			label:          "admin",
+			data:           map[string]string{"user": "admin", "password": "sekrit"},
+			rotateInterval: 24 * time.Hour,
+		},
+	})
+}
+
+func (s *SecretAddSuite) TestAddSecretError(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.shouldError = true
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"password=sekrit"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot add secret: service is not alive\n")
+}
+
+func (s *SecretAddSuite) TestInitErrors(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		nil, "no secret data specified",
+	}, {
+		[]string{"password"}, `expected "key=value", got "password"`,
+	}, {
+		[]string{"--rotate", "-1h", "password=sekrit"}, "rotate interval must not be negative",
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+		err := testing.InitCommand(com, t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
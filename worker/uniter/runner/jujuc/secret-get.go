@@ -0,0 +1,67 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// SecretGetCommand implements the secret-get command.
+type SecretGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+	id  string
+	key string
+	out cmd.Output
+}
+
+func NewSecretGetCommand(ctx Context) cmd.Command {
+	return &SecretGetCommand{ctx: ctx}
+}
+
+func (c *SecretGetCommand) Info() *cmd.Info {
+	doc := `
+secret-get prints the values held by a secret owned by the unit's
+service, or granted to it by another service over a relation. If a key
+is given, only the value for that key is printed.
+`
+	return &cmd.Info{
+		Name:    "secret-get",
+		Args:    "<id> [<key>]",
+		Purpose: "print the values of a secret",
+		Doc:     doc,
+	}
+}
+
+func (c *SecretGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *SecretGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no secret id specified")
+	}
+	c.id = args[0]
+	if len(args) > 1 {
+		c.key = args[1]
+		args = args[1:]
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *SecretGetCommand) Run(ctx *cmd.Context) error {
+	data, err := c.ctx.SecretValue(c.id)
+	if err != nil {
+		return errors.Annotatef(err, "cannot read secret %q", c.id)
+	}
+	if c.key == "" {
+		return c.out.Write(ctx, data)
+	}
+	if value, ok := data[c.key]; ok {
+		return c.out.Write(ctx, value)
+	}
+	return c.out.Write(ctx, nil)
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type SecretGetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&SecretGetSuite{})
+
+var secretGetTests = []struct {
+	args []string
+	out  string
+}{{
+	[]string{"secret-0"},
+	"password: sekrit\nuser: admin\n",
+}, {
+	[]string{"secret-0", "password"},
+	"sekrit\n",
+}, {
+	[]string{"secret-0", "missing"},
+	"",
+}, {
+	[]string{"--format", "json", "secret-0"},
+	`{"password":"sekrit","user":"admin"}` + "\n",
+}}
+
+func (s *SecretGetSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("secret-get"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *SecretGetSuite) TestOutputFormat(c *gc.C) {
+	for i, t := range secretGetTests {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetHookContext(c, -1, "")
+		_, err := hctx.CreateSecret("", map[string]string{"user": "admin", "password": "sekrit"}, 0)
+		c.Assert(err, jc.ErrorIsNil)
+		com := s.createCommand(c, hctx)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(bufferString(ctx.Stdout), gc.Equals, t.out)
+	}
+}
+
+func (s *SecretGetSuite) TestGetSecretError(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"secret-9"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot read secret \"secret-9\": permission denied\n")
+}
+
+func (s *SecretGetSuite) TestInitErrors(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	err := testing.InitCommand(com, nil)
+	c.Check(err, gc.ErrorMatches, "no secret id specified")
+
+	com = s.createCommand(c, s.GetHookContext(c, -1, ""))
+	err = testing.InitCommand(com, []string{"secret-0", "password", "blah"})
+	c.Check(err, gc.ErrorMatches, `unrecognized args: \["blah"\]`)
+}
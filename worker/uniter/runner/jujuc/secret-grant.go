@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+)
+
+// SecretGrantCommand implements the secret-grant command.
+type SecretGrantCommand struct {
+	cmd.CommandBase
+	ctx        Context
+	id         string
+	RelationId int
+}
+
+func NewSecretGrantCommand(ctx Context) cmd.Command {
+	return &SecretGrantCommand{ctx: ctx}
+}
+
+func (c *SecretGrantCommand) Info() *cmd.Info {
+	doc := `
+secret-grant allows the service at the other end of a relation to read
+a secret owned by the unit's service. The grant lasts as long as the
+relation does. The id of the secret may then be passed to the related
+units, for instance with relation-set, for them to use with secret-get.
+`
+	return &cmd.Info{
+		Name:    "secret-grant",
+		Args:    "<id>",
+		Purpose: "grant access to a secret over a relation",
+		Doc:     doc,
+	}
+}
+
+func (c *SecretGrantCommand) SetFlags(f *gnuflag.FlagSet) {
+	rV := newRelationIdValue(c.ctx, &c.RelationId)
+
+	f.Var(rV, "r", "specify a relation by id")
+	f.Var(rV, "relation", "")
+}
+
+func (c *SecretGrantCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no secret id specified")
+	}
+	c.id = args[0]
+	if c.RelationId == -1 {
+		return errors.New("no relation id specified")
+	}
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *SecretGrantCommand) Run(ctx *cmd.Context) error {
+	err := c.ctx.GrantSecret(c.id, c.RelationId)
+	return errors.Annotatef(err, "cannot grant secret %q", c.id)
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type SecretGrantSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&SecretGrantSuite{})
+
+func (s *SecretGrantSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("secret-grant"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *SecretGrantSuite) TestGrantSecret(c *gc.C) {
+	for i, t := range []struct {
+		relid int
+		args  []string
+		grant int
+	}{{
+		relid: 0,
+		args:  []string{"secret-0"},
+		grant: 0,
+	}, {
+		relid: 0,
+		args:  []string{"secret-0", "-r", "peer1:1"},
+		grant: 1,
+	}, {
+		relid: -1,
+		args:  []string{"secret-0", "--relation", "1"},
+		grant: 1,
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		hctx := s.GetHookContext(c, t.relid, "")
+		_, err := hctx.CreateSecret("", map[string]string{"password": "sekrit"}, 0)
+		c.Assert(err, jc.ErrorIsNil)
+		com := s.createCommand(c, hctx)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Check(code, gc.Equals, 0)
+		c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+		c.Check(hctx.secrets["secret-0"].grants, jc.DeepEquals, []int{t.grant})
+	}
+}
+
+func (s *SecretGrantSuite) TestGrantSecretError(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, 0, ""))
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"secret-9"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot grant secret \"secret-9\": permission denied\n")
+}
+
+func (s *SecretGrantSuite) TestInitErrors(c *gc.C) {
+	for i, t := range []struct {
+		relid int
+		args  []string
+		err   string
+	}{{
+		0, nil, "no secret id specified",
+	}, {
+		-1, []string{"secret-0"}, "no relation id specified",
+	}, {
+		-1, []string{"secret-0", "-r", "peer9:9"}, `invalid value "peer9:9" for flag -r: unknown relation id`,
+	}, {
+		0, []string{"secret-0", "blah"}, `unrecognized args: \["blah"\]`,
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c, s.GetHookContext(c, t.relid, ""))
+		err := testing.InitCommand(com, t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
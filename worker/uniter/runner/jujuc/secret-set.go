@@ -0,0 +1,57 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/utils/keyvalues"
+	"launchpad.net/gnuflag"
+)
+
+// SecretSetCommand implements the secret-set command.
+type SecretSetCommand struct {
+	cmd.CommandBase
+	ctx  Context
+	id   string
+	data map[string]string
+}
+
+func NewSecretSetCommand(ctx Context) cmd.Command {
+	return &SecretSetCommand{ctx: ctx}
+}
+
+func (c *SecretSetCommand) Info() *cmd.Info {
+	doc := `
+secret-set replaces the values held by a secret owned by the unit's
+service with the supplied key/value pairs. It is typically used to
+rotate a secret from within the secret-rotate hook.
+`
+	return &cmd.Info{
+		Name:    "secret-set",
+		Args:    "<id> <key>=<value> [...]",
+		Purpose: "replace the values of a secret",
+		Doc:     doc,
+	}
+}
+
+func (c *SecretSetCommand) SetFlags(f *gnuflag.FlagSet) {
+}
+
+func (c *SecretSetCommand) Init(args []string) (err error) {
+	if len(args) == 0 {
+		return errors.New("no secret id specified")
+	}
+	c.id = args[0]
+	if len(args) == 1 {
+		return errors.New("no secret data specified")
+	}
+	c.data, err = keyvalues.Parse(args[1:], false)
+	return err
+}
+
+func (c *SecretSetCommand) Run(ctx *cmd.Context) error {
+	err := c.ctx.UpdateSecret(c.id, c.data)
+	return errors.Annotatef(err, "cannot update secret %q", c.id)
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	"github.com/juju/cmd"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/uniter/runner/jujuc"
+)
+
+type SecretSetSuite struct {
+	ContextSuite
+}
+
+var _ = gc.Suite(&SecretSetSuite{})
+
+func (s *SecretSetSuite) createCommand(c *gc.C, hctx *Context) cmd.Command {
+	com, err := jujuc.NewCommand(hctx, cmdString("secret-set"))
+	c.Assert(err, jc.ErrorIsNil)
+	return com
+}
+
+func (s *SecretSetSuite) TestSetSecret(c *gc.C) {
+	hctx := s.GetHookContext(c, -1, "")
+	id, err := hctx.CreateSecret("", map[string]string{"password": "sekrit"}, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	com := s.createCommand(c, hctx)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{id, "password=new"})
+	c.Check(code, gc.Equals, 0)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "")
+	c.Check(hctx.secrets[id].data, jc.DeepEquals, map[string]string{"password": "new"})
+}
+
+func (s *SecretSetSuite) TestSetSecretError(c *gc.C) {
+	com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"secret-9", "password=new"})
+	c.Check(code, gc.Equals, 1)
+	c.Check(bufferString(ctx.Stderr), gc.Equals, "error: cannot update secret \"secret-9\": permission denied\n")
+}
+
+func (s *SecretSetSuite) TestInitErrors(c *gc.C) {
+	for i, t := range []struct {
+		args []string
+		err  string
+	}{{
+		nil, "no secret id specified",
+	}, {
+		[]string{"secret-0"}, "no secret data specified",
+	}, {
+		[]string{"secret-0", "password"}, `expected "key=value", got "password"`,
+	}} {
+		c.Logf("test %d: %v", i, t.args)
+		com := s.createCommand(c, s.GetHookContext(c, -1, ""))
+		err := testing.InitCommand(com, t.args)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
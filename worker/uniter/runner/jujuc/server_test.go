@@ -207,15 +207,22 @@ var newCommandTests = []struct {
 	name string
 	err  string
 }{
+	{"application-version-set", ""},
 	{"close-port", ""},
 	{"config-get", ""},
+	{"goal-state", ""},
 	{"juju-log", ""},
+	{"network-get", ""},
 	{"open-port", ""},
 	{"opened-ports", ""},
 	{"relation-get", ""},
 	{"relation-ids", ""},
 	{"relation-list", ""},
 	{"relation-set", ""},
+	{"secret-add", ""},
+	{"secret-get", ""},
+	{"secret-grant", ""},
+	{"secret-set", ""},
 	{"unit-get", ""},
 	{"storage-get", ""},
 	// The error message contains .exe on Windows
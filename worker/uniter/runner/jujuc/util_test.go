@@ -11,6 +11,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -116,6 +117,15 @@ type Context struct {
 	shouldError    bool
 	storageTag     names.StorageTag
 	storage        map[names.StorageTag]*ContextStorage
+	version        string
+	secrets        map[string]*fakeSecret
+}
+
+type fakeSecret struct {
+	label          string
+	data           map[string]string
+	rotateInterval time.Duration
+	grants         []int
 }
 
 func (c *Context) AddMetric(key, value string, created time.Time) error {
@@ -142,6 +152,92 @@ func (c *Context) AvailabilityZone() (string, bool) {
 	return "us-east-1a", true
 }
 
+func (c *Context) NetworkInfo(bindingName string) (params.NetworkInfo, error) {
+	if bindingName != "db" {
+		return params.NetworkInfo{}, errors.NotFoundf("binding %q", bindingName)
+	}
+	return params.NetworkInfo{
+		IngressAddresses: []string{"192.168.0.99", "10.0.0.99"},
+		EgressSubnets:    []string{"192.168.0.99/32"},
+	}, nil
+}
+
+func (c *Context) GoalState() (params.GoalState, error) {
+	if c.shouldError {
+		return params.GoalState{}, fmt.Errorf("cannot get goal state")
+	}
+	return params.GoalState{
+		Units: params.UnitsGoalState{
+			"u/0": {Status: "running"},
+			"u/1": {Status: "busy"},
+		},
+		Relations: map[string]params.UnitsGoalState{
+			"db": {
+				"mysql":   {Status: "joined"},
+				"mysql/0": {Status: "waiting"},
+			},
+		},
+	}, nil
+}
+
+func (c *Context) SetUnitWorkloadVersion(version string) error {
+	if c.shouldError {
+		return fmt.Errorf("cannot set workload version")
+	}
+	c.version = version
+	return nil
+}
+
+func (c *Context) CreateSecret(label string, data map[string]string, rotateInterval time.Duration) (string, error) {
+	if c.shouldError {
+		return "", fmt.Errorf("service is not alive")
+	}
+	if c.secrets == nil {
+		c.secrets = make(map[string]*fakeSecret)
+	}
+	id := fmt.Sprintf("secret-%d", len(c.secrets))
+	c.secrets[id] = &fakeSecret{
+		label:          label,
+		data:           data,
+		rotateInterval: rotateInterval,
+	}
+	return id, nil
+}
+
+func (c *Context) secret(id string) (*fakeSecret, error) {
+	secret, ok := c.secrets[id]
+	if !ok || c.shouldError {
+		return nil, fmt.Errorf("permission denied")
+	}
+	return secret, nil
+}
+
+func (c *Context) UpdateSecret(id string, data map[string]string) error {
+	secret, err := c.secret(id)
+	if err != nil {
+		return err
+	}
+	secret.data = data
+	return nil
+}
+
+func (c *Context) SecretValue(id string) (map[string]string, error) {
+	secret, err := c.secret(id)
+	if err != nil {
+		return nil, err
+	}
+	return secret.data, nil
+}
+
+func (c *Context) GrantSecret(id string, relationId int) error {
+	secret, err := c.secret(id)
+	if err != nil {
+		return err
+	}
+	secret.grants = append(secret.grants, relationId)
+	return nil
+}
+
 func (c *Context) Storage(tag names.StorageTag) (jujuc.ContextStorage, bool) {
 	storage, ok := c.storage[tag]
 	return storage, ok
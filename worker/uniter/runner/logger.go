@@ -18,6 +18,11 @@ type hookLogger struct {
 	mu      sync.Mutex
 	stopped bool
 	logger  loggo.Logger
+
+	// output, if not nil, is also given each line read. When a line
+	// is too long for the buffer, isPrefix is true for all but its
+	// last part.
+	output func(line []byte, isPrefix bool)
 }
 
 func (l *hookLogger) run() {
@@ -25,7 +30,7 @@ func (l *hookLogger) run() {
 	defer l.r.Close()
 	br := bufio.NewReaderSize(l.r, 4096)
 	for {
-		line, _, err := br.ReadLine()
+		line, isPrefix, err := br.ReadLine()
 		if err != nil {
 			if err != io.EOF {
 				logger.Errorf("cannot read hook output: %v", err)
@@ -38,6 +43,9 @@ func (l *hookLogger) run() {
 			return
 		}
 		l.logger.Infof("%s", line)
+		if l.output != nil {
+			l.output(line, isPrefix)
+		}
 		l.mu.Unlock()
 	}
 }
@@ -0,0 +1,133 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// outputFlushInterval is how often output written by a running action
+// is sent to the state server.
+var outputFlushInterval = time.Second
+
+const (
+	// maxOutputChunk is the largest chunk of action output sent to
+	// the state server at once.
+	maxOutputChunk = 32 * 1024
+
+	// maxPendingOutput limits the action output held while waiting to
+	// be sent; output written beyond it is only logged.
+	maxPendingOutput = 1024 * 1024
+)
+
+type outputChunk struct {
+	stream string
+	data   string
+}
+
+// actionOutputStreamer collects the output written by a running
+// action and periodically sends it to the state server, merging
+// consecutive writes to the same stream into chunks.
+type actionOutputStreamer struct {
+	send func(stream, data string) error
+
+	mu      sync.Mutex
+	pending []outputChunk
+	size    int
+	failed  bool
+
+	stopc chan struct{}
+	done  chan struct{}
+}
+
+// newActionOutputStreamer returns a running actionOutputStreamer which
+// sends output with the given function.
+func newActionOutputStreamer(send func(stream, data string) error) *actionOutputStreamer {
+	s := &actionOutputStreamer{
+		send:  send,
+		stopc: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// writer returns a function suitable for hookLogger.output, which
+// queues the lines it is given as output written to the given stream.
+func (s *actionOutputStreamer) writer(stream string) func(line []byte, isPrefix bool) {
+	return func(line []byte, isPrefix bool) {
+		data := string(line)
+		if !isPrefix {
+			data += "\n"
+		}
+		s.write(stream, data)
+	}
+}
+
+func (s *actionOutputStreamer) write(stream, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed {
+		return
+	}
+	if s.size+len(data) > maxPendingOutput {
+		logger.Warningf("discarding action output: too much output pending")
+		return
+	}
+	s.size += len(data)
+	if n := len(s.pending); n > 0 {
+		last := &s.pending[n-1]
+		if last.stream == stream && len(last.data)+len(data) <= maxOutputChunk {
+			last.data += data
+			return
+		}
+	}
+	s.pending = append(s.pending, outputChunk{stream, data})
+}
+
+func (s *actionOutputStreamer) loop() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stopc:
+			s.flush()
+			return
+		case <-time.After(outputFlushInterval):
+			s.flush()
+		}
+	}
+}
+
+// flush sends all pending output. If output cannot be sent, no more
+// is collected.
+func (s *actionOutputStreamer) flush() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.size = 0
+	s.mu.Unlock()
+	for _, chunk := range pending {
+		if err := s.send(chunk.stream, chunk.data); err != nil {
+			if errors.IsNotImplemented(err) {
+				logger.Debugf("not sending action output: %v", err)
+			} else {
+				logger.Warningf("cannot send action output: %v", err)
+			}
+			s.mu.Lock()
+			s.failed = true
+			s.pending = nil
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// stop sends any output not yet sent, and stops the streamer.
+func (s *actionOutputStreamer) stop() {
+	close(s.stopc)
+	<-s.done
+}
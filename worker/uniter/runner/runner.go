@@ -14,6 +14,7 @@ import (
 	"github.com/juju/loggo"
 	utilexec "github.com/juju/utils/exec"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/version"
 	"github.com/juju/juju/worker/uniter/runner/debug"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
@@ -41,6 +42,9 @@ type Context interface {
 	Id() string
 	HookVars(paths Paths) []string
 	ActionData() (*ActionData, error)
+	AppendActionOutput(stream, data string) error
+	HookOutputLimit() int
+	SetHookOutput(output string, truncated bool)
 	SetProcess(process *os.Process)
 	FlushContext(badge string, failure error) error
 }
@@ -60,6 +64,11 @@ type Paths interface {
 	// to communicate back to the executing uniter process. It might be a
 	// filesystem path, or it might be abstract.
 	GetJujucSocket() string
+
+	// GetWorkloadContainer returns the name of the container in which
+	// hooks and actions are executed, or "" if they are executed
+	// directly on the machine.
+	GetWorkloadContainer() string
 }
 
 // NewRunner returns a Runner backed by the supplied context and paths.
@@ -131,7 +140,10 @@ func (runner *runner) runCharmHookWithLocation(hookName, charmLocation string) e
 	}
 
 	debugctx := debug.NewHooksContext(runner.context.UnitName())
-	if session, _ := debugctx.FindSession(); session != nil && session.MatchHook(hookName) {
+	if session := debugctx.FindBrokeredSession(); session != nil && session.MatchHook(hookName) {
+		logger.Infof("executing %s via brokered debug-hooks", hookName)
+		err = session.RunHook(hookName, runner.paths.GetCharmDir(), env)
+	} else if session, _ := debugctx.FindSession(); session != nil && session.MatchHook(hookName) {
 		logger.Infof("executing %s via debug-hooks", hookName)
 		err = session.RunHook(hookName, runner.paths.GetCharmDir(), env)
 	} else {
@@ -151,6 +163,10 @@ func (runner *runner) runCharmHook(hookName string, env []string, charmLocation
 		return err
 	}
 	hookCmd := hookCommand(hook)
+	if container := runner.paths.GetWorkloadContainer(); container != "" {
+		logger.Debugf("executing %s in workload container %q", hookName, container)
+		hookCmd = workloadContainerCommand(container, hookCmd)
+	}
 	ps := exec.Command(hookCmd[0], hookCmd[1:]...)
 	ps.Env = env
 	ps.Dir = charmDir
@@ -160,21 +176,59 @@ func (runner *runner) runCharmHook(hookName string, env []string, charmLocation
 	}
 	ps.Stdout = outWriter
 	ps.Stderr = outWriter
-	hookLogger := &hookLogger{
+	hookLoggers := []*hookLogger{{
 		r:      outReader,
 		done:   make(chan struct{}),
 		logger: runner.getLogger(hookName),
+	}}
+	writers := []*os.File{outWriter}
+	var tail *outputTail
+	if charmLocation == "hooks" {
+		// The tail of a hook's output is kept with its result, so
+		// that the reason it failed can be seen without its logs.
+		tail = newOutputTail(runner.context.HookOutputLimit())
+		hookLoggers[0].output = tail.writeLine
+	} else if charmLocation == "actions" {
+		// Action output is kept apart by stream, and sent to the
+		// state server as it is written so that it can be followed
+		// while the action runs.
+		errReader, errWriter, err := os.Pipe()
+		if err != nil {
+			outReader.Close()
+			outWriter.Close()
+			return errors.Errorf("cannot make logging pipe: %v", err)
+		}
+		ps.Stderr = errWriter
+		streamer := newActionOutputStreamer(runner.context.AppendActionOutput)
+		defer streamer.stop()
+		hookLoggers[0].output = streamer.writer(params.ActionStdout)
+		hookLoggers = append(hookLoggers, &hookLogger{
+			r:      errReader,
+			done:   make(chan struct{}),
+			logger: hookLoggers[0].logger,
+			output: streamer.writer(params.ActionStderr),
+		})
+		writers = append(writers, errWriter)
+	}
+	for _, hookLogger := range hookLoggers {
+		go hookLogger.run()
 	}
-	go hookLogger.run()
 	err = ps.Start()
-	outWriter.Close()
+	for _, writer := range writers {
+		writer.Close()
+	}
 	if err == nil {
 		// Record the *os.Process of the hook
 		runner.context.SetProcess(ps.Process)
 		// Block until execution finishes
 		err = ps.Wait()
 	}
-	hookLogger.stop()
+	for _, hookLogger := range hookLoggers {
+		hookLogger.stop()
+	}
+	if tail != nil {
+		runner.context.SetHookOutput(tail.String(), tail.Truncated())
+	}
 	return errors.Trace(err)
 }
 
@@ -18,6 +18,7 @@ import (
 	"github.com/juju/utils"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/worker/uniter/runner"
 )
 
@@ -151,6 +152,12 @@ type MockContext struct {
 	flushBadge   string
 	flushFailure error
 	flushResult  error
+	output       map[string]string
+	outputErr    error
+
+	hookOutputLimit     int
+	hookOutput          *string
+	hookOutputTruncated bool
 }
 
 func (ctx *MockContext) UnitName() string {
@@ -168,6 +175,26 @@ func (ctx *MockContext) ActionData() (*runner.ActionData, error) {
 	return ctx.actionData, nil
 }
 
+func (ctx *MockContext) AppendActionOutput(stream, data string) error {
+	if ctx.outputErr != nil {
+		return ctx.outputErr
+	}
+	if ctx.output == nil {
+		ctx.output = make(map[string]string)
+	}
+	ctx.output[stream] += data
+	return nil
+}
+
+func (ctx *MockContext) HookOutputLimit() int {
+	return ctx.hookOutputLimit
+}
+
+func (ctx *MockContext) SetHookOutput(output string, truncated bool) {
+	ctx.hookOutput = &output
+	ctx.hookOutputTruncated = truncated
+}
+
 func (ctx *MockContext) SetProcess(process *os.Process) {
 	ctx.expectPid = process.Pid
 }
@@ -233,6 +260,37 @@ func (s *RunMockContextSuite) TestRunHookFlushFailure(c *gc.C) {
 	s.assertRecordedPid(c, ctx.expectPid)
 }
 
+func (s *RunMockContextSuite) TestRunHookInWorkloadContainer(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("workload containers are not supported on windows")
+	}
+	// Stand in for lxc-attach with a script that records its arguments
+	// and runs the command it was given.
+	binDir := c.MkDir()
+	argsFile := filepath.Join(binDir, "args")
+	script := "#!/bin/bash\necho \"$@\" > " + argsFile + "\nwhile [ \"$1\" != -- ]; do shift; done\nshift\nexec \"$@\"\n"
+	err := ioutil.WriteFile(filepath.Join(binDir, "lxc-attach"), []byte(script), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchEnvironment("PATH", binDir+":"+os.Getenv("PATH"))
+
+	s.paths.container = "juju-workload-unit-some-unit-999"
+	ctx := &MockContext{}
+	makeCharm(c, hookSpec{
+		dir:  "hooks",
+		name: hookName,
+		perm: 0700,
+		code: 42,
+	}, s.paths.charm)
+	err = runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.ErrorMatches, "exit status 42")
+	hook := filepath.Join(s.paths.charm, "hooks", hookName)
+	args, err := ioutil.ReadFile(argsFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(args), gc.Equals, "--name juju-workload-unit-some-unit-999 --keep-env -- "+hook+"\n")
+	s.assertRecordedPid(c, ctx.expectPid)
+}
+
 func (s *RunMockContextSuite) TestRunActionFlushSuccess(c *gc.C) {
 	expectErr := errors.New("pew pew pew")
 	ctx := &MockContext{
@@ -270,6 +328,113 @@ func (s *RunMockContextSuite) TestRunActionFlushFailure(c *gc.C) {
 	s.assertRecordedPid(c, ctx.expectPid)
 }
 
+func (s *RunMockContextSuite) TestRunActionStreamsOutput(c *gc.C) {
+	ctx := &MockContext{
+		actionData: &runner.ActionData{},
+	}
+	makeCharm(c, hookSpec{
+		dir:    "actions",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+		stderr: "oops",
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunAction("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.output, jc.DeepEquals, map[string]string{
+		params.ActionStdout: "hello\n",
+		params.ActionStderr: "oops\n",
+	})
+}
+
+func (s *RunMockContextSuite) TestRunActionOutputFailure(c *gc.C) {
+	ctx := &MockContext{
+		actionData: &runner.ActionData{},
+		outputErr:  errors.New("pew pew pew"),
+	}
+	makeCharm(c, hookSpec{
+		dir:    "actions",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunAction("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.IsNil)
+	c.Assert(ctx.output, gc.HasLen, 0)
+}
+
+func (s *RunMockContextSuite) TestRunHookDoesNotStreamOutput(c *gc.C) {
+	ctx := &MockContext{}
+	makeCharm(c, hookSpec{
+		dir:    "hooks",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.output, gc.HasLen, 0)
+}
+
+func (s *RunMockContextSuite) TestRunHookKeepsOutput(c *gc.C) {
+	ctx := &MockContext{hookOutputLimit: 64}
+	makeCharm(c, hookSpec{
+		dir:    "hooks",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+		stderr: "oops",
+		code:   1,
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.flushFailure, gc.ErrorMatches, "exit status 1")
+	c.Assert(ctx.hookOutput, gc.NotNil)
+	c.Assert(*ctx.hookOutput, gc.Equals, "hello\noops\n")
+	c.Assert(ctx.hookOutputTruncated, jc.IsFalse)
+}
+
+func (s *RunMockContextSuite) TestRunHookKeepsOutputTail(c *gc.C) {
+	ctx := &MockContext{hookOutputLimit: 7}
+	makeCharm(c, hookSpec{
+		dir:    "hooks",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+		stderr: "oops",
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.hookOutput, gc.NotNil)
+	c.Assert(*ctx.hookOutput, gc.Equals, "o\noops\n")
+	c.Assert(ctx.hookOutputTruncated, jc.IsTrue)
+}
+
+func (s *RunMockContextSuite) TestRunMissingHookKeepsNoOutput(c *gc.C) {
+	ctx := &MockContext{hookOutputLimit: 64}
+	err := runner.NewRunner(ctx, s.paths).RunHook("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(runner.IsMissingHookError(ctx.flushFailure), jc.IsTrue)
+	c.Assert(ctx.hookOutput, gc.IsNil)
+}
+
+func (s *RunMockContextSuite) TestRunActionKeepsNoHookOutput(c *gc.C) {
+	ctx := &MockContext{
+		actionData:      &runner.ActionData{},
+		hookOutputLimit: 64,
+	}
+	makeCharm(c, hookSpec{
+		dir:    "actions",
+		name:   hookName,
+		perm:   0700,
+		stdout: "hello",
+	}, s.paths.charm)
+	err := runner.NewRunner(ctx, s.paths).RunAction("something-happened")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ctx.hookOutput, gc.IsNil)
+}
+
 func (s *RunMockContextSuite) TestRunCommandsFlushSuccess(c *gc.C) {
 	expectErr := errors.New("pew pew pew")
 	ctx := &MockContext{
@@ -0,0 +1,74 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package runner
+
+import "sync"
+
+// outputTail keeps the last bytes written to it, up to a limit, in a
+// ring buffer, so that the output of a hook can be kept with its result
+// however much the hook writes.
+type outputTail struct {
+	mu      sync.Mutex
+	buf     []byte
+	pos     int
+	full    bool
+	written int64
+}
+
+// newOutputTail returns an outputTail which keeps the last limit bytes
+// written to it.
+func newOutputTail(limit int) *outputTail {
+	return &outputTail{buf: make([]byte, limit)}
+}
+
+// Write is part of the io.Writer interface.
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(p)
+	t.written += int64(n)
+	limit := len(t.buf)
+	if limit == 0 {
+		return n, nil
+	}
+	if len(p) > limit {
+		p = p[len(p)-limit:]
+	}
+	for len(p) > 0 {
+		copied := copy(t.buf[t.pos:], p)
+		p = p[copied:]
+		t.pos += copied
+		if t.pos == limit {
+			t.pos = 0
+			t.full = true
+		}
+	}
+	return n, nil
+}
+
+// writeLine writes a line read by a hookLogger, restoring the newline
+// the hookLogger stripped from it; it is suitable for hookLogger.output.
+func (t *outputTail) writeLine(line []byte, isPrefix bool) {
+	t.Write(line)
+	if !isPrefix {
+		t.Write([]byte("\n"))
+	}
+}
+
+// String returns the bytes kept, in the order they were written.
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return string(t.buf[:t.pos])
+	}
+	return string(t.buf[t.pos:]) + string(t.buf[:t.pos])
+}
+
+// Truncated returns whether more bytes were written than were kept.
+func (t *outputTail) Truncated() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.written > int64(len(t.buf))
+}
@@ -48,11 +48,16 @@ func (MockEnvPaths) GetJujucSocket() string {
 	return "path-to-jujuc.socket"
 }
 
+func (MockEnvPaths) GetWorkloadContainer() string {
+	return ""
+}
+
 // RealPaths implements Paths for tests that do touch the filesystem.
 type RealPaths struct {
-	tools  string
-	charm  string
-	socket string
+	tools     string
+	charm     string
+	socket    string
+	container string
 }
 
 func osDependentSockPath(c *gc.C) string {
@@ -83,6 +88,10 @@ func (p RealPaths) GetJujucSocket() string {
 	return p.socket
 }
 
+func (p RealPaths) GetWorkloadContainer() string {
+	return p.container
+}
+
 // HookContextSuite contains shared setup for various other test suites. Test
 // methods should not be added to this type, because they'll get run repeatedly.
 type HookContextSuite struct {
@@ -0,0 +1,50 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const (
+	// interval at which the unit checks whether its service's secrets
+	// are due rotation
+	secretRotationPollInterval = 5 * time.Minute
+)
+
+// secretRotationSignal returns the id of the secret the secret-rotate
+// hook should next run for, and a channel that will signal when it is
+// due. If no secret is due before the next poll, the id is empty and
+// the channel signals when the unit should check again. Only the
+// service's leader rotates secrets.
+func (u *Uniter) secretRotationSignal(now time.Time) (string, <-chan time.Time, error) {
+	rotations, err := u.unit.SecretRotations()
+	if errors.IsNotImplemented(err) {
+		// The state server does not support secrets.
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	poll := time.After(secretRotationPollInterval)
+	if len(rotations) == 0 {
+		return "", poll, nil
+	}
+	if !u.leadershipTracker.ClaimLeader().Wait() {
+		return "", poll, nil
+	}
+	next := rotations[0]
+	for _, rotation := range rotations[1:] {
+		if rotation.NextRotateTime.Before(next.NextRotateTime) {
+			next = rotation
+		}
+	}
+	waitDuration := next.NextRotateTime.Sub(now)
+	if waitDuration > secretRotationPollInterval {
+		return "", poll, nil
+	}
+	logger.Debugf("secret %q due rotation in %v", next.SecretId, waitDuration)
+	return next.SecretId, time.After(waitDuration), nil
+}
@@ -6,7 +6,7 @@ package uniter
 import (
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/juju/errors"
@@ -24,8 +24,9 @@ import (
 	"github.com/juju/juju/worker"
 	"github.com/juju/juju/worker/leadership"
 	"github.com/juju/juju/worker/uniter/charm"
-	"github.com/juju/juju/worker/uniter/filter"
 	"github.com/juju/juju/worker/uniter/operation"
+	"github.com/juju/juju/worker/uniter/remotestate"
+	"github.com/juju/juju/worker/uniter/resolver"
 	"github.com/juju/juju/worker/uniter/runner"
 	"github.com/juju/juju/worker/uniter/runner/jujuc"
 	"github.com/juju/juju/worker/uniter/storage"
@@ -50,14 +51,20 @@ type UniterExecutionObserver interface {
 // delegated to Mode values, which are expected to react to events and direct
 // the uniter's responses to them.
 type Uniter struct {
-	tomb      tomb.Tomb
-	st        *uniter.State
-	paths     Paths
-	f         filter.Filter
-	unit      *uniter.Unit
-	relations Relations
-	cleanups  []cleanup
-	storage   *storage.Attachments
+	tomb        tomb.Tomb
+	st          *uniter.State
+	paths       Paths
+	remoteState remotestate.Watcher
+	unit        *uniter.Unit
+	relations   Relations
+	cleanups    []cleanup
+	storage     *storage.Attachments
+
+	// localState records how much of the remote state the uniter has
+	// responded to; resolver compares the two to choose the operations
+	// run in response to remote state changes.
+	localState resolver.LocalState
+	resolver   resolver.Resolver
 
 	deployer          *deployerProxy
 	operationFactory  operation.Factory
@@ -67,10 +74,23 @@ type Uniter struct {
 	leadershipTracker leadership.Tracker
 
 	hookLock    *fslock.Lock
+	hookLocker  *hookLocker
+	lockDir     string
 	runListener *RunListener
 
 	ranConfigChanged bool
 
+	// reportedHookQueue holds the hook queue last recorded in state.
+	reportedHookQueue *hookQueueReport
+
+	// reportedDrain holds the hooks the uniter last recorded in state
+	// that it has yet to run before it stops.
+	reportedDrain []string
+
+	// upgradeSeriesStatus holds the series upgrade status the uniter
+	// last recorded in state.
+	upgradeSeriesStatus string
+
 	// The execution observer is only used in tests at this stage. Should this
 	// need to be extended, perhaps a list of observers would be needed.
 	observer UniterExecutionObserver
@@ -82,18 +102,24 @@ type Uniter struct {
 
 // NewUniter creates a new Uniter which will install, run, and upgrade
 // a charm on behalf of the unit with the given unitTag, by executing
-// hooks and operations provoked by changes in st.
+// hooks and operations provoked by changes in st. If workloadContainer
+// is not empty, hooks and actions are executed in the container with
+// that name.
 func NewUniter(
 	st *uniter.State,
 	unitTag names.UnitTag,
 	leadershipManager coreleadership.LeadershipManager,
 	dataDir string,
+	workloadContainer string,
 	hookLock *fslock.Lock,
 ) *Uniter {
+	paths := NewPaths(dataDir, unitTag)
+	paths.WorkloadContainer = workloadContainer
 	u := &Uniter{
 		st:                st,
-		paths:             NewPaths(dataDir, unitTag),
+		paths:             paths,
 		hookLock:          hookLock,
+		lockDir:           filepath.Join(dataDir, "locks"),
 		leadershipManager: leadershipManager,
 		collectMetricsAt:  inactiveMetricsTimer,
 	}
@@ -139,16 +165,9 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 	}
 	logger.Infof("unit %q started", u.unit)
 
-	// Start filtering state change events for consumption by modes.
-	u.f, err = filter.NewFilter(u.st, unitTag)
-	if err != nil {
-		return err
-	}
-	u.addCleanup(u.f.Stop)
-
 	// Stop the uniter if either of these components fails.
 	go func() { u.tomb.Kill(leadershipTracker.Wait()) }()
-	go func() { u.tomb.Kill(u.f.Wait()) }()
+	go func() { u.tomb.Kill(u.remoteState.Wait()) }()
 
 	// Run modes until we encounter an error.
 	mode := ModeContinue
@@ -160,7 +179,7 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 			mode, err = mode(u)
 			switch cause := errors.Cause(err); cause {
 			case operation.ErrNeedsReboot:
-				err = worker.ErrRebootMachine
+				err = u.rebootMachine()
 			case tomb.ErrDying, worker.ErrTerminateAgent:
 				err = cause
 			case operation.ErrHookFailed:
@@ -178,18 +197,28 @@ func (u *Uniter) loop(unitTag names.UnitTag) (err error) {
 }
 
 func (u *Uniter) setupLocks() (err error) {
-	if message := u.hookLock.Message(); u.hookLock.IsLocked() && message != "" {
-		// Look to see if it was us that held the lock before.  If it was, we
-		// should be safe enough to break it, as it is likely that we died
-		// before unlocking, and have been restarted by the init system.
-		parts := strings.SplitN(message, ":", 2)
-		if len(parts) > 1 && parts[0] == u.unit.Name() {
-			if err := u.hookLock.BreakLock(); err != nil {
-				return err
-			}
+	// Look to see if it was us that held the lock before.  If it was, we
+	// should be safe enough to break it, as it is likely that we died
+	// before unlocking, and have been restarted by the init system.
+	if heldByUnit(u.hookLock, u.unit.Name()) {
+		if err := u.hookLock.BreakLock(); err != nil {
+			return err
 		}
 	}
-	return nil
+	// Whether hooks run concurrently is decided when the uniter starts,
+	// so changes to the environment setting take effect on restart.
+	environConfig, err := u.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	u.hookLocker = &hookLocker{
+		machineLock: u.hookLock,
+		lockDir:     u.lockDir,
+		unitName:    u.unit.Name(),
+		charmDir:    u.paths.State.CharmDir,
+		concurrent:  environConfig.ConcurrentHooks(),
+	}
+	return u.hookLocker.breakStaleLocks()
 }
 
 func (u *Uniter) init(unitTag names.UnitTag) (err error) {
@@ -207,12 +236,25 @@ func (u *Uniter) init(unitTag names.UnitTag) (err error) {
 	if err = u.setupLocks(); err != nil {
 		return err
 	}
-	if err := jujuc.EnsureSymlinks(u.paths.ToolsDir); err != nil {
+	// Start watching the remote state for consumption by modes.
+	remoteState, err := remotestate.NewWatcher(u.st, unitTag)
+	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(u.paths.State.RelationsDir, 0755); err != nil {
+	u.remoteState = remoteState
+	u.addCleanup(func() error {
+		return worker.Stop(remoteState)
+	})
+	charmURL, err := u.unit.CharmURL()
+	if err == nil {
+		u.localState.CharmURL = charmURL
+	} else if err != uniter.ErrNoCharmURLSet {
 		return errors.Trace(err)
 	}
+	u.resolver = resolver.NewResolver()
+	if err := jujuc.EnsureSymlinks(u.paths.ToolsDir); err != nil {
+		return err
+	}
 	relations, err := newRelations(u.st, unitTag, u.paths, u.tomb.Dying())
 	if err != nil {
 		return errors.Annotatef(err, "cannot create relations")
@@ -242,12 +284,16 @@ func (u *Uniter) init(unitTag names.UnitTag) (err error) {
 	if err != nil {
 		return err
 	}
-	u.operationFactory = operation.NewFactory(
-		u.deployer,
-		runnerFactory,
-		&operationCallbacks{u},
-		u.storage,
-		u.tomb.Dying(),
+	u.operationFactory = resolver.NewOpFactory(
+		operation.NewFactory(
+			u.deployer,
+			runnerFactory,
+			&operationCallbacks{u},
+			u.storage,
+			u.tomb.Dying(),
+		),
+		u.remoteState,
+		&u.localState,
 	)
 
 	operationExecutor, err := operation.NewExecutor(
@@ -306,6 +352,28 @@ func (u *Uniter) operationState() operation.State {
 	return u.operationExecutor.State()
 }
 
+// rebootMachine records in the agent status that the unit is waiting for
+// the machine to reboot, unless a hook error is awaiting resolution, and
+// returns the error that stops the uniter so the reboot can go ahead. The reboot itself was requested from state by
+// the hook context; any hook interrupted by "juju-reboot --now" remains
+// queued, and is run again when the uniter restarts.
+func (u *Uniter) rebootMachine() error {
+	opState := u.operationState()
+	if opState.Kind == operation.RunHook && opState.Step == operation.Pending {
+		// Leave the hook error visible; it will still need to be
+		// resolved after the reboot.
+		return worker.ErrRebootMachine
+	}
+	status := params.StatusActive
+	if !opState.Started {
+		status = params.StatusInstalling
+	}
+	if err := u.unit.SetAgentStatus(status, "rebooting machine", nil); err != nil {
+		return errors.Annotate(err, "cannot set agent status")
+	}
+	return worker.ErrRebootMachine
+}
+
 // initializeMetricsCollector enables the periodic collect-metrics hook
 // for charms that declare metrics.
 func (u *Uniter) initializeMetricsCollector() error {
@@ -354,7 +422,7 @@ func (u *Uniter) RunCommands(args RunCommandsArgs) (results *exec.ExecResponse,
 		}
 	}
 	if errors.Cause(err) == operation.ErrNeedsReboot {
-		u.tomb.Kill(worker.ErrRebootMachine)
+		u.tomb.Kill(u.rebootMachine())
 		err = nil
 	}
 	if err != nil {
@@ -386,3 +454,21 @@ func (u *Uniter) runOperation(creator creator) error {
 	}
 	return u.operationExecutor.Run(op)
 }
+
+// resolveRemoteState runs the operations chosen by the uniter's resolver
+// in response to the current remote state, until there are none left to
+// run, or one of them leaves an operation to be resumed by ModeContinue.
+func (u *Uniter) resolveRemoteState() error {
+	for {
+		u.localState.State = u.operationState()
+		op, err := u.resolver.NextOp(u.localState, u.remoteState.Snapshot(), u.operationFactory)
+		if errors.Cause(err) == resolver.ErrNoOperation {
+			return nil
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot create operation")
+		}
+		if err := u.operationExecutor.Run(op); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
@@ -1051,7 +1051,7 @@ func (s *UniterSuite) TestUniterRelations(c *gc.C) {
 			// --force` to cause the unit to leave any relation scopes it may be
 			// in -- but it's worth noting here all the same.
 		), ut(
-			"unknown local relation dir is removed",
+			"local relation dirs are migrated, and unknown relations removed",
 			quickStartRelation{},
 			stopUniter{},
 			custom{func(c *gc.C, ctx *context) {
@@ -1060,10 +1060,14 @@ func (s *UniterSuite) TestUniterRelations(c *gc.C) {
 			startUniter{},
 			waitHooks{"config-changed"},
 			custom{func(c *gc.C, ctx *context) {
-				ft.Removed{"state/relations/90210"}.Check(c, ctx.path)
+				ft.Removed{"state/relations"}.Check(c, ctx.path)
+				states, err := ctx.unit.RelationStates()
+				c.Assert(err, jc.ErrorIsNil)
+				c.Assert(states, gc.HasLen, 1)
+				c.Assert(states[0].RelationId, gc.Equals, ctx.relation.Id())
 			}},
 		), ut(
-			"all relations are available to config-changed on bounce, even if state dir is missing",
+			"all relations are available to config-changed on bounce, even if relation state is missing",
 			createCharm{
 				customize: func(c *gc.C, ctx *context, path string) {
 					script := uniterRelationsCustomizeScript
@@ -1079,10 +1083,13 @@ func (s *UniterSuite) TestUniterRelations(c *gc.C) {
 			addRelation{waitJoin: true},
 			stopUniter{},
 			custom{func(c *gc.C, ctx *context) {
-				// Check the state dir was created, and remove it.
-				path := fmt.Sprintf("state/relations/%d", ctx.relation.Id())
-				ft.Dir{path, 0755}.Check(c, ctx.path)
-				ft.Removed{path}.Create(c, ctx.path)
+				// Check the relation state was recorded, and remove it.
+				states, err := ctx.unit.RelationStates()
+				c.Assert(err, jc.ErrorIsNil)
+				c.Assert(states, gc.HasLen, 1)
+				c.Assert(states[0].RelationId, gc.Equals, ctx.relation.Id())
+				err = ctx.unit.RemoveRelationState(ctx.relation.Id())
+				c.Assert(err, jc.ErrorIsNil)
 
 				// Check that config-changed didn't record any relations, because
 				// they shouldn't been available until after the start hook.
@@ -1091,9 +1098,11 @@ func (s *UniterSuite) TestUniterRelations(c *gc.C) {
 			startUniter{},
 			waitHooks{"config-changed"},
 			custom{func(c *gc.C, ctx *context) {
-				// Check the state dir was recreated.
-				path := fmt.Sprintf("state/relations/%d", ctx.relation.Id())
-				ft.Dir{path, 0755}.Check(c, ctx.path)
+				// Check the relation state was recorded again.
+				states, err := ctx.unit.RelationStates()
+				c.Assert(err, jc.ErrorIsNil)
+				c.Assert(states, gc.HasLen, 1)
+				c.Assert(states[0].RelationId, gc.Equals, ctx.relation.Id())
 
 				// Check that config-changed did record the joined relations.
 				data := fmt.Sprintf("db:%d\n", ctx.relation.Id())
@@ -1161,6 +1170,75 @@ func (s *UniterSuite) TestUniterRelationErrors(c *gc.C) {
 	})
 }
 
+func (s *UniterSuite) TestUniterHookQueue(c *gc.C) {
+	joined := state.QueuedHook{Kind: "relation-joined", RelationId: 0, RemoteUnit: "mysql/0"}
+	changed := state.QueuedHook{Kind: "relation-changed", RelationId: 0, RemoteUnit: "mysql/0"}
+	s.runUniterTests(c, []uniterTest{
+		ut(
+			"hooks queued behind a failed hook are reported",
+			startupRelationError{"db-relation-joined"},
+			waitUnit{
+				status: params.StatusError,
+				info:   `hook failed: "db-relation-joined"`,
+			},
+			waitHookQueue{pending: &joined, queued: []state.QueuedHook{changed}},
+			changeConfig{"blog-title": "Goodness Gracious Me"},
+			waitHookQueue{pending: &joined, queued: []state.QueuedHook{
+				{Kind: "config-changed"}, changed,
+			}},
+			fixHook{"db-relation-joined"},
+			resolveError{state.ResolvedRetryHooks},
+			waitUnit{status: params.StatusActive},
+			waitHooks{"db-relation-joined mysql/0 db:0", "config-changed", "db-relation-changed mysql/0 db:0"},
+			waitHookQueue{},
+		), ut(
+			"dropped relation hooks are not run",
+			startupRelationError{"db-relation-joined"},
+			waitUnit{
+				status: params.StatusError,
+				info:   `hook failed: "db-relation-joined"`,
+			},
+			waitHookQueue{pending: &joined, queued: []state.QueuedHook{changed}},
+			dropQueuedHook{"relation-changed:0:mysql/0"},
+			fixHook{"db-relation-joined"},
+			resolveError{state.ResolvedRetryHooks},
+			waitUnit{status: params.StatusActive},
+			waitHooks{"db-relation-joined mysql/0 db:0"},
+			waitHooks{},
+			waitHookQueue{},
+		),
+	})
+}
+
+func (s *UniterSuite) TestUniterDrain(c *gc.C) {
+	s.runUniterTests(c, []uniterTest{
+		ut(
+			"dying unit reports it has drained once stopped",
+			quickStart{},
+			unitDying,
+			waitHooks{"stop"},
+			waitUniterDead{},
+			waitDrainPhase{state.UnitDrained},
+		),
+	})
+}
+
+func (s *UniterSuite) TestUniterUpgradeSeries(c *gc.C) {
+	s.runUniterTests(c, []uniterTest{
+		ut(
+			"series upgrade hooks run as the machine is upgraded",
+			quickStart{},
+			upgradeSeries{prepare: true},
+			waitHooks{"pre-series-upgrade"},
+			waitUpgradeSeries{status: state.UpgradeSeriesPrepareCompleted, series: "quantal"},
+			upgradeSeries{},
+			waitHooks{"post-series-upgrade"},
+			waitUpgradeSeries{status: state.UpgradeSeriesNotStarted, series: "trusty"},
+			waitHooks{},
+		),
+	})
+}
+
 func (s *UniterSuite) TestUniterMeterStatusChanged(c *gc.C) {
 	s.runUniterTests(c, []uniterTest{
 		ut(
@@ -1606,6 +1684,10 @@ func (s *UniterSuite) TestReboot(c *gc.C) {
 			waitAddresses{},
 			waitUniterDead{"machine needs to reboot"},
 			waitHooks{"install"},
+			waitUnit{
+				status: params.StatusInstalling,
+				info:   "rebooting machine",
+			},
 			startUniter{},
 			waitUnit{
 				status: params.StatusActive,
@@ -1626,6 +1708,10 @@ func (s *UniterSuite) TestReboot(c *gc.C) {
 			waitAddresses{},
 			waitUniterDead{"machine needs to reboot"},
 			waitHooks{"install"},
+			waitUnit{
+				status: params.StatusInstalling,
+				info:   "rebooting machine",
+			},
 			startUniter{},
 			waitUnit{
 				status: params.StatusActive,
@@ -1663,6 +1749,10 @@ func (s *UniterSuite) TestRebootFromJujuRun(c *gc.C) {
 			quickStart{},
 			runCommands{"juju-reboot"},
 			waitUniterDead{"machine needs to reboot"},
+			waitUnit{
+				status: params.StatusActive,
+				info:   "rebooting machine",
+			},
 			startUniter{},
 			waitHooks{"config-changed"},
 		), ut(
@@ -1670,6 +1760,13 @@ func (s *UniterSuite) TestRebootFromJujuRun(c *gc.C) {
 			startupError{"install"},
 			runCommands{"juju-reboot"},
 			waitUniterDead{"machine needs to reboot"},
+			waitUnit{
+				status: params.StatusError,
+				info:   `hook failed: "install"`,
+				data: map[string]interface{}{
+					"hook": "install",
+				},
+			},
 			startUniter{},
 			waitHooks{},
 		), ut(
@@ -0,0 +1,50 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package uniter
+
+import (
+	"github.com/juju/juju/worker/uniter/hook"
+	"github.com/juju/juju/worker/uniter/remotestate"
+)
+
+// Series upgrade statuses, as reported by the state server, that the
+// uniter responds to or records.
+const (
+	upgradeSeriesPrepareStarted   = "prepare started"
+	upgradeSeriesPrepareCompleted = "prepare completed"
+	upgradeSeriesCompleteRunning  = "complete running"
+	upgradeSeriesCompleted        = "completed"
+)
+
+// upgradeSeriesHook returns a creator for the pre-series-upgrade or
+// post-series-upgrade hook if the unit is due to run one, or nil. The
+// hook the uniter last committed is remembered, so that a snapshot
+// that does not yet reflect it does not cause the hook to run twice.
+func (u *Uniter) upgradeSeriesHook(remoteState remotestate.Snapshot) creator {
+	switch remoteState.UpgradeSeriesStatus {
+	case upgradeSeriesPrepareStarted:
+		if u.upgradeSeriesStatus != upgradeSeriesPrepareCompleted {
+			return newSimpleRunHookOp(hook.PreSeriesUpgrade)
+		}
+	case upgradeSeriesCompleteRunning:
+		if u.upgradeSeriesStatus != upgradeSeriesCompleted {
+			return newSimpleRunHookOp(hook.PostSeriesUpgrade)
+		}
+	}
+	return nil
+}
+
+// commitUpgradeSeriesHook records that the unit has run its
+// pre-series-upgrade or post-series-upgrade hook.
+func (u *Uniter) commitUpgradeSeriesHook(hi hook.Info) error {
+	status := upgradeSeriesPrepareCompleted
+	if hi.Kind == hook.PostSeriesUpgrade {
+		status = upgradeSeriesCompleted
+	}
+	if err := u.unit.SetUpgradeSeriesStatus(status); err != nil {
+		return err
+	}
+	u.upgradeSeriesStatus = status
+	return nil
+}
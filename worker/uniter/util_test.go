@@ -35,7 +35,7 @@ import (
 	"github.com/juju/juju/juju/sockets"
 	"github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/leadership"
-	"github.com/juju/juju/lease"
+	leasetesting "github.com/juju/juju/lease/raftlease/testing"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/state/storage"
@@ -115,8 +115,8 @@ func (ctx *context) HookFailed(hookName string) {
 
 func (ctx *context) run(c *gc.C, steps []stepper) {
 	// We need this lest leadership calls block forever.
-	workerLoop := lease.WorkerLoop(ctx.st)
-	leaseWorker := worker.NewSimpleWorker(workerLoop)
+	leaseWorker, err := leasetesting.NewLocalService()
+	c.Assert(err, jc.ErrorIsNil)
 	defer func() {
 		c.Assert(worker.Stop(leaseWorker), jc.ErrorIsNil)
 	}()
@@ -291,6 +291,7 @@ var charmHooks = []string{
 	"install", "start", "config-changed", "upgrade-charm", "stop",
 	"db-relation-joined", "db-relation-changed", "db-relation-departed",
 	"db-relation-broken", "meter-status-changed", "collect-metrics",
+	"pre-series-upgrade", "post-series-upgrade",
 }
 
 func (s createCharm) step(c *gc.C, ctx *context) {
@@ -425,7 +426,7 @@ func (s startUniter) step(c *gc.C, ctx *context) {
 	locksDir := filepath.Join(ctx.dataDir, "locks")
 	lock, err := fslock.NewLock(locksDir, "uniter-hook-execution")
 	c.Assert(err, jc.ErrorIsNil)
-	ctx.uniter = uniter.NewUniter(ctx.api, tag, ctx.leader, ctx.dataDir, lock)
+	ctx.uniter = uniter.NewUniter(ctx.api, tag, ctx.leader, ctx.dataDir, "", lock)
 	uniter.SetUniterObserver(ctx.uniter, ctx)
 }
 
@@ -605,6 +606,123 @@ func (s resolveError) step(c *gc.C, ctx *context) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+type waitHookQueue struct {
+	pending *state.QueuedHook
+	queued  []state.QueuedHook
+}
+
+func (s waitHookQueue) step(c *gc.C, ctx *context) {
+	expect := state.HookQueue{Pending: s.pending, Queued: s.queued}
+	timeout := time.After(worstCase)
+	for {
+		ctx.s.BackingState.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			queue, err := ctx.unit.HookQueue()
+			c.Assert(err, jc.ErrorIsNil)
+			queue.Dropped = nil
+			if len(queue.Queued) == 0 {
+				queue.Queued = nil
+			}
+			if !reflect.DeepEqual(queue, expect) {
+				c.Logf("want hook queue %+v, got %+v; still waiting", expect, queue)
+				continue
+			}
+			return
+		case <-timeout:
+			c.Fatalf("never reported expected hook queue")
+		}
+	}
+}
+
+type waitDrainPhase struct {
+	phase state.UnitDrainPhase
+}
+
+func (s waitDrainPhase) step(c *gc.C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		ctx.s.BackingState.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			status, err := ctx.unit.DrainStatus()
+			if errors.IsNotFound(err) {
+				c.Logf("drain not started; still waiting")
+				continue
+			}
+			c.Assert(err, jc.ErrorIsNil)
+			if status.Phase != s.phase {
+				c.Logf("want drain phase %q, got %q; still waiting", s.phase, status.Phase)
+				continue
+			}
+			return
+		case <-timeout:
+			c.Fatalf("never reported expected drain phase")
+		}
+	}
+}
+
+type dropQueuedHook struct {
+	id string
+}
+
+func (s dropQueuedHook) step(c *gc.C, ctx *context) {
+	err := ctx.unit.DropQueuedHook(s.id)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+type upgradeSeries struct {
+	prepare bool
+}
+
+// step starts or completes an upgrade of the series of the unit's
+// machine, standing in for the machine agent in the latter case.
+func (s upgradeSeries) step(c *gc.C, ctx *context) {
+	mid, err := ctx.unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := ctx.st.Machine(mid)
+	c.Assert(err, jc.ErrorIsNil)
+	if s.prepare {
+		err = machine.PrepareSeriesUpgrade("trusty")
+		c.Assert(err, jc.ErrorIsNil)
+		return
+	}
+	err = machine.CompleteSeriesUpgrade()
+	c.Assert(err, jc.ErrorIsNil)
+	err = machine.UpgradeSeriesAgentUpdated()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+type waitUpgradeSeries struct {
+	status state.UpgradeSeriesStatus
+	series string
+}
+
+func (s waitUpgradeSeries) step(c *gc.C, ctx *context) {
+	mid, err := ctx.unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := ctx.st.Machine(mid)
+	c.Assert(err, jc.ErrorIsNil)
+	timeout := time.After(worstCase)
+	for {
+		ctx.s.BackingState.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			status, _, err := machine.UpgradeSeriesStatus()
+			c.Assert(err, jc.ErrorIsNil)
+			err = machine.Refresh()
+			c.Assert(err, jc.ErrorIsNil)
+			if status != s.status || machine.Series() != s.series {
+				c.Logf("want series upgrade %q on %q, got %q on %q; still waiting", s.status, s.series, status, machine.Series())
+				continue
+			}
+			return
+		case <-timeout:
+			c.Fatalf("never reached desired series upgrade status")
+		}
+	}
+}
+
 type waitUnit struct {
 	status   params.Status
 	info     string
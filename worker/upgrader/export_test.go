@@ -4,6 +4,7 @@
 package upgrader
 
 var (
-	RetryAfter           = &retryAfter
-	AllowedTargetVersion = allowedTargetVersion
+	RetryAfter            = &retryAfter
+	MaintenanceRetryAfter = &maintenanceRetryAfter
+	AllowedTargetVersion  = allowedTargetVersion
 )
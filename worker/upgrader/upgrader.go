@@ -6,6 +6,7 @@ package upgrader
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/juju/loggo"
@@ -16,8 +17,10 @@ import (
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/api/upgrader"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state/watcher"
 	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/tools/delta"
 	"github.com/juju/juju/version"
 )
 
@@ -27,13 +30,32 @@ var retryAfter = func() <-chan time.Time {
 	return time.After(5 * time.Second)
 }
 
+// maintenanceRetryAfter returns a channel that receives a value when
+// an upgrade deferred by a closed maintenance window should be
+// reconsidered.
+var maintenanceRetryAfter = func(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// maintenanceRecheckInterval bounds how long a deferred upgrade waits
+// before the maintenance window is checked again, so that changes to
+// the window and overrides are noticed.
+const maintenanceRecheckInterval = time.Minute
+
 var logger = loggo.GetLogger("juju.worker.upgrader")
 
+// MaintenanceWindow reports the state of the environment's
+// maintenance window.
+type MaintenanceWindow interface {
+	Status() (params.MaintenanceWindowStatus, error)
+}
+
 // Upgrader represents a worker that watches the state for upgrade
 // requests.
 type Upgrader struct {
 	tomb             tomb.Tomb
 	st               *upgrader.State
+	window           MaintenanceWindow
 	dataDir          string
 	tag              names.Tag
 	origAgentVersion version.Number
@@ -45,15 +67,19 @@ type Upgrader struct {
 // download the tools for any new version into the given data directory.  If
 // an upgrade is needed, the worker will exit with an UpgradeReadyError
 // holding details of the requested upgrade. The tools will have been
-// downloaded and unpacked.
+// downloaded and unpacked. The worker does not exit while the
+// environment's maintenance window is closed, unless an upgrade is
+// already running.
 func NewUpgrader(
 	st *upgrader.State,
+	window MaintenanceWindow,
 	agentConfig agent.Config,
 	origAgentVersion version.Number,
 	isUpgradeRunning func() bool,
 ) *Upgrader {
 	u := &Upgrader{
 		st:               st,
+		window:           window,
 		dataDir:          agentConfig.DataDir(),
 		tag:              agentConfig.Tag(),
 		origAgentVersion: origAgentVersion,
@@ -154,6 +180,9 @@ func (u *Upgrader) loop() error {
 		// Check if tools have already been downloaded.
 		wantVersionBinary := toBinaryVersion(wantVersion)
 		if u.toolsAlreadyDownloaded(wantVersionBinary) {
+			if retry = u.deferUpgrade(); retry != nil {
+				continue
+			}
 			return u.newUpgradeReadyError(wantVersionBinary)
 		}
 
@@ -170,6 +199,9 @@ func (u *Upgrader) loop() error {
 		// upgrade the agent.
 		err := u.ensureTools(wantTools)
 		if err == nil {
+			if retry = u.deferUpgrade(); retry != nil {
+				continue
+			}
 			return u.newUpgradeReadyError(wantTools.Version)
 		}
 		logger.Errorf("failed to fetch tools from %q: %v", wantTools.URL, err)
@@ -177,6 +209,37 @@ func (u *Upgrader) loop() error {
 	}
 }
 
+// deferUpgrade returns a channel on which to reconsider the upgrade if
+// the environment's maintenance window is closed, or nil if the agent
+// may upgrade now. Upgrades requested while an upgrade is running are
+// never deferred, nor are upgrades when the API server does not know
+// about maintenance windows.
+func (u *Upgrader) deferUpgrade() <-chan time.Time {
+	if u.isUpgradeRunning() {
+		return nil
+	}
+	status, err := u.window.Status()
+	if params.IsCodeNotImplemented(err) {
+		return nil
+	} else if err != nil {
+		logger.Errorf("cannot get maintenance window, deferring upgrade: %v", err)
+		return maintenanceRetryAfter(maintenanceRecheckInterval)
+	}
+	if status.Open {
+		return nil
+	}
+	wait := maintenanceRecheckInterval
+	if status.Until != nil {
+		logger.Infof("deferring upgrade until maintenance window opens at %v", status.Until)
+		if d := status.Until.Sub(time.Now()); d > 0 && d < wait {
+			wait = d
+		}
+	} else {
+		logger.Infof("deferring upgrade until maintenance window opens")
+	}
+	return maintenanceRetryAfter(wait)
+}
+
 func toBinaryVersion(vers version.Number) version.Binary {
 	outVers := version.Current
 	outVers.Number = vers
@@ -197,11 +260,38 @@ func (u *Upgrader) newUpgradeReadyError(newVersion version.Binary) *UpgradeReady
 	}
 }
 
+// ensureTools downloads and unpacks the given tools. When the tools
+// of the running agent are available, it first asks for a delta from
+// those, falling back to fetching the full tarball if that fails.
 func (u *Upgrader) ensureTools(agentTools *coretools.Tools) error {
-	logger.Infof("fetching tools from %q", agentTools.URL)
+	if _, err := agenttools.ReadTools(u.dataDir, version.Current); err == nil {
+		err := u.fetchTools(agentTools, deltaURL(agentTools.URL, version.Current))
+		if err == nil {
+			return nil
+		}
+		logger.Warningf("cannot upgrade using tools delta: %v", err)
+	}
+	return u.fetchTools(agentTools, agentTools.URL)
+}
+
+// deltaURL returns the URL from which a delta between the tools with
+// the given version and the tools at toolsURL can be fetched.
+func deltaURL(toolsURL string, from version.Binary) string {
+	u, err := url.Parse(toolsURL)
+	if err != nil {
+		return toolsURL
+	}
+	query := u.Query()
+	query.Set("from", from.String())
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func (u *Upgrader) fetchTools(agentTools *coretools.Tools, toolsURL string) error {
+	logger.Infof("fetching tools from %q", toolsURL)
 	// The reader MUST verify the tools' hash, so there is no
 	// need to validate the peer. We cannot anyway: see http://pad.lv/1261780.
-	resp, err := utils.GetNonValidatingHTTPClient().Get(agentTools.URL)
+	resp, err := utils.GetNonValidatingHTTPClient().Get(toolsURL)
 	if err != nil {
 		return err
 	}
@@ -209,7 +299,11 @@ func (u *Upgrader) ensureTools(agentTools *coretools.Tools) error {
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("bad HTTP response: %v", resp.Status)
 	}
-	err = agenttools.UnpackTools(u.dataDir, agentTools, resp.Body)
+	if resp.Header.Get("Content-Type") == delta.ContentType {
+		err = agenttools.UnpackToolsDelta(u.dataDir, version.Current, agentTools, resp.Body)
+	} else {
+		err = agenttools.UnpackTools(u.dataDir, agentTools, resp.Body)
+	}
 	if err != nil {
 		return fmt.Errorf("cannot unpack tools: %v", err)
 	}
@@ -4,7 +4,10 @@
 package upgrader_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
 	stdtesting "testing"
@@ -20,12 +23,14 @@ import (
 	"github.com/juju/juju/agent"
 	agenttools "github.com/juju/juju/agent/tools"
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/api/maintenancewindow"
 	envtesting "github.com/juju/juju/environs/testing"
 	envtools "github.com/juju/juju/environs/tools"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/provider/dummy"
 	"github.com/juju/juju/state"
 	statetesting "github.com/juju/juju/state/testing"
+	"github.com/juju/juju/state/toolstorage"
 	coretesting "github.com/juju/juju/testing"
 	coretools "github.com/juju/juju/tools"
 	"github.com/juju/juju/version"
@@ -62,6 +67,10 @@ func (s *UpgraderSuite) SetUpTest(c *gc.C) {
 	s.AddCleanup(func(*gc.C) {
 		*upgrader.RetryAfter = oldRetryAfter
 	})
+	oldMaintenanceRetryAfter := *upgrader.MaintenanceRetryAfter
+	s.AddCleanup(func(*gc.C) {
+		*upgrader.MaintenanceRetryAfter = oldMaintenanceRetryAfter
+	})
 }
 
 type mockConfig struct {
@@ -91,6 +100,7 @@ func (s *UpgraderSuite) makeUpgrader(c *gc.C) *upgrader.Upgrader {
 	c.Assert(err, jc.ErrorIsNil)
 	return upgrader.NewUpgrader(
 		s.state.Upgrader(),
+		maintenancewindow.NewClient(s.state),
 		agentConfig(s.machine.Tag(), s.DataDir()),
 		s.confVersion,
 		func() bool { return s.upgradeRunning },
@@ -165,6 +175,54 @@ func (s *UpgraderSuite) TestUpgraderUpgradesImmediately(c *gc.C) {
 	envtesting.CheckTools(c, foundTools, newTools)
 }
 
+func (s *UpgraderSuite) storeTools(c *gc.C, vers version.Binary, contents string) (*coretools.Tools, []byte) {
+	data, checksum := coretesting.TarGz(coretesting.NewTarFile("jujud", 0755, contents))
+	stor, err := s.State.ToolsStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	defer stor.Close()
+	metadata := toolstorage.Metadata{
+		Version: vers,
+		Size:    int64(len(data)),
+		SHA256:  checksum,
+	}
+	err = stor.AddTools(bytes.NewReader(data), metadata)
+	c.Assert(err, jc.ErrorIsNil)
+	return &coretools.Tools{
+		Version: vers,
+		URL:     "http://foo/bar",
+		Size:    metadata.Size,
+		SHA256:  metadata.SHA256,
+	}, data
+}
+
+func (s *UpgraderSuite) TestUpgraderUpgradesUsingDelta(c *gc.C) {
+	random := make([]byte, 64*1024)
+	rand.New(rand.NewSource(0)).Read(random)
+	oldTools, oldData := s.storeTools(c, version.MustParseBinary("5.4.3-precise-amd64"), string(random))
+	newTools, _ := s.storeTools(c, version.MustParseBinary("5.4.5-precise-amd64"), string(random)+"new")
+	err := agenttools.UnpackTools(s.DataDir(), oldTools, bytes.NewReader(oldData))
+	c.Assert(err, jc.ErrorIsNil)
+	s.PatchValue(&version.Current, oldTools.Version)
+	err = statetesting.SetAgentVersion(s.State, newTools.Version.Number)
+	c.Assert(err, jc.ErrorIsNil)
+
+	u := s.makeUpgrader(c)
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldTools.Version,
+		NewTools:  newTools.Version,
+		DataDir:   s.DataDir(),
+	})
+	c.Assert(c.GetTestLog(), jc.Contains, "byte delta from 5.4.3-precise-amd64 to 5.4.5-precise-amd64 tools")
+	jujud, err := ioutil.ReadFile(filepath.Join(agenttools.SharedToolsDir(s.DataDir(), newTools.Version), "jujud"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(jujud) == string(random)+"new", jc.IsTrue)
+	foundTools, err := agenttools.ReadTools(s.DataDir(), newTools.Version)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(foundTools.SHA256, gc.Equals, newTools.SHA256)
+}
+
 func (s *UpgraderSuite) TestUpgraderRetryAndChanged(c *gc.C) {
 	stor := s.DefaultToolsStorage
 	oldTools := envtesting.PrimeTools(c, stor, s.DataDir(), s.Environ.Config().AgentStream(), version.MustParseBinary("5.4.3-precise-amd64"))
@@ -267,6 +325,57 @@ func (s *UpgraderSuite) TestUsesAlreadyDownloadedToolsIfAvailable(c *gc.C) {
 	})
 }
 
+func (s *UpgraderSuite) TestUpgraderWaitsForMaintenanceWindow(c *gc.C) {
+	oldVersion := version.MustParseBinary("1.2.3-quantal-amd64")
+	s.PatchValue(&version.Current, oldVersion)
+	newVersion := version.MustParseBinary("5.4.3-quantal-amd64")
+	err := statetesting.SetAgentVersion(s.State, newVersion.Number)
+	c.Assert(err, jc.ErrorIsNil)
+	envtesting.InstallFakeDownloadedTools(c, s.DataDir(), newVersion)
+
+	err = s.State.SetMaintenanceOverride(state.MaintenanceOverride{
+		Open:  false,
+		Until: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	retry := make(chan time.Time)
+	*upgrader.MaintenanceRetryAfter = func(time.Duration) <-chan time.Time {
+		return retry
+	}
+
+	u := s.makeUpgrader(c)
+	select {
+	case <-waitErr(u):
+		c.Fatalf("upgrader did not wait for the maintenance window")
+	case <-time.After(coretesting.ShortWait):
+	}
+
+	err = s.State.ClearMaintenanceOverride()
+	c.Assert(err, jc.ErrorIsNil)
+	select {
+	case retry <- time.Now():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("upgrader did not retry")
+	}
+	err = u.Stop()
+	envtesting.CheckUpgraderReadyError(c, err, &upgrader.UpgradeReadyError{
+		AgentName: s.machine.Tag().String(),
+		OldTools:  oldVersion,
+		NewTools:  newVersion,
+		DataDir:   s.DataDir(),
+	})
+}
+
+// waitErr returns a channel which receives the error with which the
+// upgrader exits.
+func waitErr(u *upgrader.Upgrader) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- u.Wait()
+	}()
+	return done
+}
+
 func (s *UpgraderSuite) TestUpgraderRefusesToDowngradeMinorVersions(c *gc.C) {
 	stor := s.DefaultToolsStorage
 	origTools := envtesting.PrimeTools(c, stor, s.DataDir(), s.Environ.Config().AgentStream(), version.MustParseBinary("5.4.3-precise-amd64"))
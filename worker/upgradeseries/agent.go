@@ -0,0 +1,115 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+import (
+	"io/ioutil"
+	"path"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/common"
+	"github.com/juju/juju/version"
+)
+
+// newService is overridden in tests.
+var newService = service.NewService
+
+// agentUpgrader adjusts the agents on a machine to a new series.
+type agentUpgrader struct {
+	dataDir       string
+	logDir        string
+	containerType string
+	current       version.Binary
+}
+
+// NewAgentUpgrader returns an Upgrader that adjusts the agents whose
+// tools are in the data directory of the given agent configuration.
+func NewAgentUpgrader(config agent.Config) Upgrader {
+	return &agentUpgrader{
+		dataDir:       config.DataDir(),
+		logDir:        config.LogDir(),
+		containerType: config.Value(agent.ContainerType),
+		current:       version.Current,
+	}
+}
+
+// UpgradeTo is part of the Upgrader interface. The current tools are
+// made available for the new series, and every agent is pointed at
+// them. If the new series uses a different init system, a service is
+// installed for each agent in it; the services of the old init
+// system, which no longer runs them, are left in place.
+func (u *agentUpgrader) UpgradeTo(series string) error {
+	to := u.current
+	to.Series = series
+	toInitSystem, ok := service.VersionInitSystem(to)
+	if !ok {
+		return errors.NotFoundf("init system for series %q", series)
+	}
+	if err := tools.CopyTools(u.dataDir, u.current, to); err != nil {
+		return errors.Annotate(err, "cannot copy tools")
+	}
+	agents, err := u.agentTags()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, tag := range agents {
+		if _, err := tools.ChangeAgentTools(u.dataDir, tag.String(), to); err != nil {
+			return errors.Annotatef(err, "cannot change tools for %s", tag)
+		}
+	}
+	fromInitSystem, _ := service.VersionInitSystem(u.current)
+	if fromInitSystem == toInitSystem {
+		return nil
+	}
+	for _, tag := range agents {
+		if err := u.installService(tag, toInitSystem); err != nil {
+			return errors.Annotatef(err, "cannot install service for %s", tag)
+		}
+	}
+	return nil
+}
+
+// agentTags returns the tags of the agents with tools in the data
+// directory.
+func (u *agentUpgrader) agentTags() ([]names.Tag, error) {
+	infos, err := ioutil.ReadDir(path.Join(u.dataDir, "tools"))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var tags []names.Tag
+	for _, info := range infos {
+		tag, err := names.ParseTag(info.Name())
+		if err != nil {
+			// Not an agent's tools, but a version's.
+			continue
+		}
+		switch tag.(type) {
+		case names.MachineTag, names.UnitTag:
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// installService installs a service running the agent with the given
+// tag in the given init system.
+func (u *agentUpgrader) installService(tag names.Tag, initSystem string) error {
+	var conf common.Conf
+	switch tag := tag.(type) {
+	case names.MachineTag:
+		conf, _ = service.MachineAgentConf(tag.Id(), u.dataDir, u.logDir, "")
+	case names.UnitTag:
+		conf, _ = service.UnitAgentConf(tag.Id(), u.dataDir, u.logDir, "", u.containerType)
+	}
+	svc, err := newService("jujud-"+tag.String(), conf, initSystem)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return svc.Install()
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries_test
+
+import (
+	"bytes"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	agenttools "github.com/juju/juju/agent/tools"
+	"github.com/juju/juju/service"
+	"github.com/juju/juju/service/common"
+	coretesting "github.com/juju/juju/testing"
+	coretools "github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+	"github.com/juju/juju/worker/upgradeseries"
+)
+
+type agentUpgraderSuite struct {
+	coretesting.BaseSuite
+	dataDir  string
+	services *service.FakeServiceData
+	inits    map[string]string
+}
+
+var _ = gc.Suite(&agentUpgraderSuite{})
+
+var currentVersion = version.MustParseBinary("1.25.0-trusty-amd64")
+
+func (s *agentUpgraderSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.dataDir = c.MkDir()
+	s.services = service.NewFakeServiceData()
+	s.inits = make(map[string]string)
+	s.PatchValue(upgradeseries.NewService, func(name string, conf common.Conf, initSystem string) (service.Service, error) {
+		s.inits[name] = initSystem
+		svc := service.NewFakeService(name, conf)
+		svc.FakeServiceData = s.services
+		return svc, nil
+	})
+
+	data, checksum := coretesting.TarGz(
+		coretesting.NewTarFile("jujud", 0755, "jujud executable"),
+	)
+	tools := &coretools.Tools{
+		Version: currentVersion,
+		Size:    int64(len(data)),
+		SHA256:  checksum,
+	}
+	err := agenttools.UnpackTools(s.dataDir, tools, bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	for _, agent := range []string{"machine-0", "unit-wordpress-0"} {
+		_, err := agenttools.ChangeAgentTools(s.dataDir, agent, currentVersion)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *agentUpgraderSuite) assertAgentTools(c *gc.C, vers version.Binary) {
+	for _, agent := range []string{"machine-0", "unit-wordpress-0"} {
+		link, err := filepath.EvalSymlinks(agenttools.ToolsDir(s.dataDir, agent))
+		c.Assert(err, jc.ErrorIsNil)
+		expect, err := filepath.EvalSymlinks(agenttools.SharedToolsDir(s.dataDir, vers))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(link, gc.Equals, expect)
+	}
+}
+
+func (s *agentUpgraderSuite) TestUpgradeSameInitSystem(c *gc.C) {
+	upgrader := upgradeseries.NewAgentUpgraderForVersion(s.dataDir, c.MkDir(), currentVersion)
+	err := upgrader.UpgradeTo("utopic")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertAgentTools(c, version.MustParseBinary("1.25.0-utopic-amd64"))
+	c.Assert(s.services.InstalledNames.Values(), gc.HasLen, 0)
+}
+
+func (s *agentUpgraderSuite) TestUpgradeNewInitSystem(c *gc.C) {
+	upgrader := upgradeseries.NewAgentUpgraderForVersion(s.dataDir, c.MkDir(), currentVersion)
+	err := upgrader.UpgradeTo("vivid")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertAgentTools(c, version.MustParseBinary("1.25.0-vivid-amd64"))
+	c.Assert(s.services.InstalledNames.SortedValues(), jc.DeepEquals, []string{
+		"jujud-machine-0", "jujud-unit-wordpress-0",
+	})
+	c.Assert(s.inits, jc.DeepEquals, map[string]string{
+		"jujud-machine-0":        service.InitSystemSystemd,
+		"jujud-unit-wordpress-0": service.InitSystemSystemd,
+	})
+}
+
+func (s *agentUpgraderSuite) TestUpgradeUnknownSeries(c *gc.C) {
+	upgrader := upgradeseries.NewAgentUpgraderForVersion(s.dataDir, c.MkDir(), currentVersion)
+	err := upgrader.UpgradeTo("nonsense")
+	c.Assert(err, gc.ErrorMatches, `init system for series "nonsense" not found`)
+}
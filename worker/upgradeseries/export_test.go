@@ -0,0 +1,20 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries
+
+import (
+	"github.com/juju/juju/version"
+)
+
+var NewService = &newService
+
+// NewAgentUpgraderForVersion returns an Upgrader for the agents in
+// dataDir, as if they ran the tools with the given version.
+func NewAgentUpgraderForVersion(dataDir, logDir string, current version.Binary) Upgrader {
+	return &agentUpgrader{
+		dataDir: dataDir,
+		logDir:  logDir,
+		current: current,
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package upgradeseries implements a worker that adjusts a machine's
+// agents to the series the machine is being upgraded to, once its
+// operating system has been upgraded.
+package upgradeseries
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.upgradeseries")
+
+// upgradeSeriesCompleteStarted is the series upgrade status in which
+// the machine's operating system has been upgraded, and the machine
+// agent is to adjust itself to the new series.
+const upgradeSeriesCompleteStarted = "complete started"
+
+// UpgradeSeriesAPI reports the progress of an upgrade of the machine's
+// series, and records when the machine agent has adjusted to it.
+type UpgradeSeriesAPI interface {
+	WatchMachine() (apiwatcher.NotifyWatcher, error)
+	MachineStatus() (params.UpgradeSeriesStatus, error)
+	SetAgentUpdated() error
+}
+
+// Upgrader adjusts the agents on the machine to a new series.
+type Upgrader interface {
+	UpgradeTo(series string) error
+}
+
+// New returns a worker which waits for the operating system of the
+// machine to be upgraded as part of an upgrade of its series, then
+// adjusts the agents on it to the new series using upgrader.
+func New(api UpgradeSeriesAPI, upgrader Upgrader) worker.Worker {
+	u := &seriesUpgrader{
+		api:      api,
+		upgrader: upgrader,
+	}
+	go func() {
+		defer u.tomb.Done()
+		u.tomb.Kill(u.loop())
+	}()
+	return u
+}
+
+type seriesUpgrader struct {
+	tomb     tomb.Tomb
+	api      UpgradeSeriesAPI
+	upgrader Upgrader
+}
+
+// Kill is part of the worker.Worker interface.
+func (u *seriesUpgrader) Kill() {
+	u.tomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (u *seriesUpgrader) Wait() error {
+	return u.tomb.Wait()
+}
+
+func (u *seriesUpgrader) loop() error {
+	w, err := u.api.WatchMachine()
+	if err != nil {
+		return errors.Annotate(err, "cannot watch series upgrades")
+	}
+	defer watcher.Stop(w, &u.tomb)
+
+	// The watcher's initial event is consumed by the API server, so
+	// handle any upgrade completed before the worker started first.
+	if err := u.handleStatus(); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		select {
+		case <-u.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-w.Changes():
+			if !ok {
+				return watcher.EnsureErr(w)
+			}
+			if err := u.handleStatus(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// handleStatus adjusts the agents on the machine to the new series if
+// the machine's operating system has been upgraded.
+func (u *seriesUpgrader) handleStatus() error {
+	status, err := u.api.MachineStatus()
+	if err != nil {
+		return errors.Annotate(err, "cannot get series upgrade status")
+	}
+	if status.Status != upgradeSeriesCompleteStarted {
+		return nil
+	}
+	logger.Infof("adjusting agents to series %q", status.Series)
+	if err := u.upgrader.UpgradeTo(status.Series); err != nil {
+		return errors.Annotatef(err, "cannot adjust agents to series %q", status.Series)
+	}
+	if err := u.api.SetAgentUpdated(); err != nil {
+		return errors.Annotate(err, "cannot record agents adjusted")
+	}
+	return nil
+}
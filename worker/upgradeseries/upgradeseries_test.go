@@ -0,0 +1,150 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package upgradeseries_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/upgradeseries"
+)
+
+type upgradeSeriesSuite struct {
+	coretesting.BaseSuite
+	api      *mockAPI
+	upgrader *mockUpgrader
+}
+
+var _ = gc.Suite(&upgradeSeriesSuite{})
+
+func (s *upgradeSeriesSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = &mockAPI{
+		changes: make(chan struct{}, 1),
+		updated: make(chan struct{}, 10),
+	}
+	s.upgrader = &mockUpgrader{upgraded: make(chan string, 10)}
+}
+
+func (s *upgradeSeriesSuite) start(c *gc.C) worker.Worker {
+	w := upgradeseries.New(s.api, s.upgrader)
+	s.AddCleanup(func(c *gc.C) { worker.Stop(w) })
+	return w
+}
+
+func (s *upgradeSeriesSuite) assertUpgraded(c *gc.C, series string) {
+	select {
+	case upgraded := <-s.upgrader.upgraded:
+		c.Assert(upgraded, gc.Equals, series)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for agents to be adjusted")
+	}
+	select {
+	case <-s.api.updated:
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for agents to be recorded adjusted")
+	}
+}
+
+func (s *upgradeSeriesSuite) assertNotUpgraded(c *gc.C) {
+	select {
+	case series := <-s.upgrader.upgraded:
+		c.Fatalf("unexpected adjustment to series %q", series)
+	case <-time.After(coretesting.ShortWait):
+	}
+}
+
+func (s *upgradeSeriesSuite) TestUpgradesWhenCompleteStarted(c *gc.C) {
+	s.start(c)
+	s.assertNotUpgraded(c)
+
+	s.api.setStatus(params.UpgradeSeriesStatus{Status: "prepare completed", Series: "trusty"})
+	s.api.changes <- struct{}{}
+	s.assertNotUpgraded(c)
+
+	s.api.setStatus(params.UpgradeSeriesStatus{Status: "complete started", Series: "trusty"})
+	s.api.changes <- struct{}{}
+	s.assertUpgraded(c, "trusty")
+}
+
+func (s *upgradeSeriesSuite) TestUpgradesOnStart(c *gc.C) {
+	s.api.setStatus(params.UpgradeSeriesStatus{Status: "complete started", Series: "trusty"})
+	s.start(c)
+	s.assertUpgraded(c, "trusty")
+}
+
+func (s *upgradeSeriesSuite) TestUpgradeFailure(c *gc.C) {
+	s.upgrader.err = errors.New("disk full")
+	s.api.setStatus(params.UpgradeSeriesStatus{Status: "complete started", Series: "trusty"})
+	w := s.start(c)
+	err := w.Wait()
+	c.Assert(err, gc.ErrorMatches, `cannot adjust agents to series "trusty": disk full`)
+	select {
+	case <-s.api.updated:
+		c.Fatalf("unexpected record of agents adjusted")
+	default:
+	}
+}
+
+type mockAPI struct {
+	mu      sync.Mutex
+	status  params.UpgradeSeriesStatus
+	changes chan struct{}
+	updated chan struct{}
+}
+
+func (api *mockAPI) setStatus(status params.UpgradeSeriesStatus) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.status = status
+}
+
+func (api *mockAPI) WatchMachine() (apiwatcher.NotifyWatcher, error) {
+	return &mockNotifyWatcher{changes: api.changes}, nil
+}
+
+func (api *mockAPI) MachineStatus() (params.UpgradeSeriesStatus, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.status, nil
+}
+
+func (api *mockAPI) SetAgentUpdated() error {
+	api.updated <- struct{}{}
+	return nil
+}
+
+type mockUpgrader struct {
+	upgraded chan string
+	err      error
+}
+
+func (u *mockUpgrader) UpgradeTo(series string) error {
+	u.upgraded <- series
+	return u.err
+}
+
+type mockNotifyWatcher struct {
+	changes <-chan struct{}
+}
+
+func (w *mockNotifyWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+func (*mockNotifyWatcher) Stop() error {
+	return nil
+}
+
+func (*mockNotifyWatcher) Err() error {
+	return nil
+}